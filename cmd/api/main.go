@@ -5,16 +5,30 @@ import (
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
 	"github.com/joho/godotenv"
 	"github.com/wolfman30/medspa-ai-platform/internal/api/router"
+	"github.com/wolfman30/medspa-ai-platform/internal/apikeys"
 	appbootstrap "github.com/wolfman30/medspa-ai-platform/internal/app/bootstrap"
+	"github.com/wolfman30/medspa-ai-platform/internal/availhealth"
 	"github.com/wolfman30/medspa-ai-platform/internal/bootstrap"
+	"github.com/wolfman30/medspa-ai-platform/internal/broadcasts"
+	"github.com/wolfman30/medspa-ai-platform/internal/clinicdata"
 	appconfig "github.com/wolfman30/medspa-ai-platform/internal/config"
 	"github.com/wolfman30/medspa-ai-platform/internal/conversation"
+	"github.com/wolfman30/medspa-ai-platform/internal/convstream"
+	"github.com/wolfman30/medspa-ai-platform/internal/costs"
+	"github.com/wolfman30/medspa-ai-platform/internal/crmhooks"
+	"github.com/wolfman30/medspa-ai-platform/internal/demo"
+	moxieclient "github.com/wolfman30/medspa-ai-platform/internal/emr/moxie"
 	"github.com/wolfman30/medspa-ai-platform/internal/events"
 	"github.com/wolfman30/medspa-ai-platform/internal/http/handlers"
+	"github.com/wolfman30/medspa-ai-platform/internal/inboundhealth"
 	"github.com/wolfman30/medspa-ai-platform/internal/leads"
+	"github.com/wolfman30/medspa-ai-platform/internal/messaging"
+	"github.com/wolfman30/medspa-ai-platform/internal/messaging/compliance"
+	"github.com/wolfman30/medspa-ai-platform/internal/notify"
 	"github.com/wolfman30/medspa-ai-platform/internal/payments"
 	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
 )
@@ -73,12 +87,29 @@ func main() {
 	clinicStore := clinicBoot.ClinicStore
 	smsTranscript := clinicBoot.SMSTranscript
 
+	// Live conversation updates for the operator portal. Messages publish
+	// from the transcript store; status and delivery changes from here.
+	conversationStore.SetUpdatePublisher(convstream.NewPublisher(redisClient), logger)
+	var adminConversationStreamHandler *handlers.AdminConversationStreamHandler
+	if hub := convstream.NewHub(redisClient, logger); hub != nil {
+		if err := hub.Start(appCtx); err != nil {
+			logger.Warn("conversation stream disabled", "error", err)
+		} else {
+			adminConversationStreamHandler = handlers.NewAdminConversationStreamHandler(hub, cfg.ConversationStreamMaxPerOrg, cfg.ConversationStreamHeartbeat, logger)
+		}
+	}
+
 	// Initialize handlers
 	leadsHandler := leads.NewHandler(leadsRepo, logger)
+	if clinicStore != nil {
+		leadsHandler.SetClinicStore(clinicStore)
+	}
+	inboundTracker := inboundhealth.NewTracker(redisClient, logger)
 	messagingBoot := bootstrap.BootstrapMessaging(bootstrap.MessagingDeps{
 		Cfg: cfg, Logger: logger, ConversationPublisher: conversationPublisher, LeadsRepo: leadsRepo,
 		MessageStore: msgStore, AuditService: auditSvc, ConversationStore: conversationStore,
-		SMSTranscriptStore: smsTranscript, ClinicStore: clinicStore,
+		SMSTranscriptStore: smsTranscript, ClinicStore: clinicStore, MessagingMetrics: messagingMetrics,
+		DBPool: dbPool, InboundHealth: inboundTracker,
 	})
 	resolver := messagingBoot.Resolver
 	webhookMessenger := messagingBoot.WebhookMessenger
@@ -88,20 +119,173 @@ func main() {
 	telnyxClient := bootstrap.SetupTelnyxClient(cfg, logger)
 	adminMessagingHandler := bootstrap.BuildAdminMessagingHandler(bootstrap.AdminMessagingDeps{
 		Cfg: cfg, Logger: logger, MessageStore: msgStore, TelnyxClient: telnyxClient, MessagingMetrics: messagingMetrics,
+		LeadsRepo: leadsRepo,
 	})
 	var paymentsRepo *payments.Repository
 	var outboxStore *events.OutboxStore
 	var processedStore *events.ProcessedStore
+	var webhookLog *events.WebhookLog
 	if dbPool != nil {
 		paymentsRepo = payments.NewRepository(dbPool, redisClient)
 		outboxStore = events.NewOutboxStore(dbPool)
 		processedStore = events.NewProcessedStore(dbPool)
+		messagingHandler.SetProcessedTracker(processedStore)
+		webhookLog = events.NewWebhookLog(dbPool)
+		retention := time.Duration(cfg.WebhookEventRetentionDays) * 24 * time.Hour
+		go events.NewWebhookLogCleaner(webhookLog, retention, logger).Start(appCtx)
+	}
+
+	var adminNumberRoutesHandler *handlers.AdminNumberRoutesHandler
+	if messagingBoot.NumberRoutes != nil {
+		adminNumberRoutesHandler = handlers.NewAdminNumberRoutesHandler(messagingBoot.NumberRoutes, logger)
+		if telnyxClient != nil && msgStore != nil {
+			adminNumberRoutesHandler.SetProvisioning(msgStore.Provisioning(), telnyxClient, cfg.TelnyxMessagingProfileID)
+		}
+	}
+
+	var coldStore *clinicdata.ColdStore
+	if dbPool != nil {
+		coldStore = bootstrap.BuildColdStore(appCtx, cfg, logger)
+	}
+	if coldStore != nil && cfg.ColdStorageEnabled {
+		go clinicdata.NewColdStorageJob(clinicdata.ColdStorageJobConfig{
+			DB:        dbPool,
+			Store:     coldStore,
+			MaxAge:    cfg.ColdStorageMaxAge,
+			BatchSize: cfg.ColdStorageBatchSize,
+			Interval:  cfg.ColdStorageInterval,
+			Logger:    logger,
+		}).Start(appCtx)
+		logger.Info("conversation cold storage export enabled", "max_age", cfg.ColdStorageMaxAge.String(), "interval", cfg.ColdStorageInterval.String())
+	}
+
+	var adminRetentionHandler *handlers.AdminRetentionHandler
+	if dbPool != nil {
+		leadPurger := clinicdata.NewPurgerWithConfig(clinicdata.PurgerConfig{
+			DB:        dbPool,
+			Redis:     redisClient,
+			Logger:    logger,
+			ColdStore: coldStore,
+		})
+		adminRetentionHandler = handlers.NewAdminRetentionHandler(leadPurger, logger)
+		if cfg.DataRetentionPurgeEnabled {
+			var policies clinicdata.RetentionPolicySource
+			if clinicStore != nil {
+				policies = clinicStore
+			}
+			go clinicdata.NewRetentionJob(clinicdata.RetentionJobConfig{
+				Purger:    leadPurger,
+				Policies:  policies,
+				BatchSize: cfg.DataRetentionBatchSize,
+				Interval:  cfg.DataRetentionInterval,
+				DryRun:    cfg.DataRetentionDryRun,
+				Logger:    logger,
+			}).Start(appCtx)
+			logger.Info("data retention purge enabled", "dry_run", cfg.DataRetentionDryRun, "interval", cfg.DataRetentionInterval.String())
+		}
+	}
+
+	var demoCatalogHandler *demo.CatalogHandler
+	if clinicStore != nil {
+		demoCatalogHandler = demo.NewCatalogHandler(clinicStore, logger)
+	}
+	var adminPromptPreviewHandler *handlers.AdminPromptPreviewHandler
+	var adminTemplatesHandler *handlers.AdminTemplatesHandler
+	if clinicStore != nil {
+		adminPromptPreviewHandler = handlers.NewAdminPromptPreviewHandler(clinicStore, logger)
+		adminTemplatesHandler = handlers.NewAdminTemplatesHandler(clinicStore,
+			messaging.ComplianceAckOverrides(cfg.TelnyxStopReply, cfg.TelnyxHelpReply, cfg.TelnyxStartReply), logger)
+	}
+
+	var adminAvailabilityHealthHandler *handlers.AdminAvailabilityHealthHandler
+	if dbPool != nil {
+		probeStore := availhealth.NewStore(dbPool)
+		adminAvailabilityHealthHandler = handlers.NewAdminAvailabilityHealthHandler(probeStore, logger)
+		if cfg.AvailabilityHealthEnabled && clinicStore != nil {
+			go availhealth.NewMonitor(availhealth.MonitorConfig{
+				Store:    probeStore,
+				Clinics:  clinicStore,
+				Probers:  []availhealth.Prober{availhealth.NewMoxieProber(moxieclient.NewClient(logger))},
+				Alerter:  availhealth.NewWebhookAlerter(notify.NewWebhookNotifier(nil, logger), cfg.AvailabilityHealthAlertWebhook),
+				Interval: cfg.AvailabilityHealthInterval,
+				ProbeGap: cfg.AvailabilityHealthProbeGap,
+				Logger:   logger,
+			}).Start(appCtx)
+			logger.Info("availability health monitor enabled", "interval", cfg.AvailabilityHealthInterval.String())
+		}
+	}
+
+	// Clinic diagnostics need the inbound tracker; the startup report is
+	// served either way.
+	var diagnosticsClinics handlers.DiagnosticsClinicSource
+	var diagnosticsInbound handlers.InboundLiveness
+	if inboundTracker != nil && clinicStore != nil {
+		diagnosticsClinics, diagnosticsInbound = clinicStore, inboundTracker
+	}
+	adminDiagnosticsHandler := handlers.NewAdminDiagnosticsHandler(diagnosticsClinics, diagnosticsInbound, logger)
+	if diagnosticsInbound != nil {
+		if cfg.InboundHealthEnabled {
+			// A nil alerter must not reach the interface as a typed nil.
+			if alerter := inboundhealth.NewWebhookAlerter(notify.NewWebhookNotifier(nil, logger), cfg.InboundHealthAlertWebhook); alerter != nil {
+				go inboundhealth.NewChecker(inboundhealth.CheckerConfig{
+					Tracker:  inboundTracker,
+					Clinics:  clinicStore,
+					Alerter:  alerter,
+					Interval: cfg.InboundHealthInterval,
+					Logger:   logger,
+				}).Start(appCtx)
+				logger.Info("inbound health checker enabled", "interval", cfg.InboundHealthInterval.String())
+			} else {
+				logger.Error("inbound health checker not started: INBOUND_HEALTH_ENABLED is set but INBOUND_HEALTH_ALERT_WEBHOOK is empty")
+			}
+		}
+	}
+
+	var adminCRMWebhooksHandler *handlers.AdminCRMWebhooksHandler
+	var crmEvents conversation.CRMEventPublisher
+	if dbPool != nil && clinicStore != nil {
+		crmStore := crmhooks.NewStore(dbPool)
+		adminCRMWebhooksHandler = handlers.NewAdminCRMWebhooksHandler(crmStore, logger)
+		crmEvents = crmhooks.NewPublisher(crmStore, clinicStore, logger)
+		go crmhooks.NewDeliverer(crmStore, clinicStore, logger).
+			WithInterval(cfg.CRMWebhookPollInterval).
+			WithRetry(cfg.CRMWebhookMaxAttempts, cfg.CRMWebhookRetryBackoff).
+			Start(appCtx)
+	}
+
+	var adminCostsHandler *handlers.AdminCostsHandler
+	if dbPool != nil {
+		costStore := costs.NewStore(dbPool)
+		adminCostsHandler = handlers.NewAdminCostsHandler(costStore, logger)
+		go costs.NewRollupJob(costs.RollupJobConfig{
+			Store:          costStore,
+			SegmentRateUSD: cfg.SMSCostPerSegmentUSD,
+			Interval:       cfg.CostRollupInterval,
+			Logger:         logger,
+		}).Start(appCtx)
+	}
+
+	var adminBroadcastsHandler *handlers.AdminBroadcastsHandler
+	var broadcastStore *broadcasts.Store
+	if dbPool != nil && msgStore != nil {
+		broadcastStore = broadcasts.NewStore(dbPool)
+		broadcastStore.SetCipher(db.Cipher)
+		consent, _ := leadsRepo.(compliance.MarketingConsentChecker)
+		adminBroadcastsHandler = handlers.NewAdminBroadcastsHandler(broadcastStore, msgStore, consent, logger)
+		if clinicStore != nil {
+			adminBroadcastsHandler.SetClinicStore(clinicStore)
+		}
+	}
+
+	var adminJobsHandler *handlers.AdminJobsHandler
+	if lister, ok := jobRecorder.(conversation.StaleJobLister); ok {
+		adminJobsHandler = handlers.NewAdminJobsHandler(lister, cfg.ConversationJobStaleAfter, logger)
 	}
 
 	clinicHandler, clinicStatsHandler, clinicDashboardHandler := bootstrap.BuildClinicHandlers(logger, clinicStore, dbPool)
 
 	adminClinicDataHandler := bootstrap.BuildAdminClinicDataHandler(bootstrap.AdminClinicDataDeps{
-		AppCtx: appCtx, Cfg: cfg, Logger: logger, DBPool: dbPool, RedisClient: redisClient,
+		AppCtx: appCtx, Cfg: cfg, Logger: logger, DBPool: dbPool, RedisClient: redisClient, ColdStore: coldStore,
 	})
 
 	var adminOnboardingHandler *handlers.AdminOnboardingHandler
@@ -116,12 +300,26 @@ func main() {
 		clientRegistrationHandler = handlers.NewClientRegistrationHandler(sqlDB, redisClient, logger)
 	}
 
+	var apiKeysHandler *apikeys.Handler
+	var apiKeyAuth apikeys.Authenticator
+	if sqlDB != nil {
+		apiKeyRepo := apikeys.NewRepository(sqlDB)
+		apiKeysHandler = apikeys.NewHandler(apiKeyRepo, logger)
+		apiKeyAuth = apiKeyRepo
+	}
+
 	var knowledgeRepo conversation.KnowledgeRepository
 	if redisClient != nil {
 		knowledgeRepo = conversation.NewRedisKnowledgeRepository(redisClient)
 	}
 	conversationHandler := conversation.NewHandler(conversationPublisher, jobRecorder, knowledgeRepo, nil, logger)
 	conversationHandler.SetSMSTranscriptStore(smsTranscript)
+	if dbPool != nil {
+		conversationHandler.SetCallbackTaskStore(conversation.NewPGCallbackTaskStore(dbPool))
+	}
+	if conversationStore != nil && redisClient != nil {
+		conversationHandler.SetAvailabilityRefresh(conversationStore, redisClient)
+	}
 
 	supervisor, err := appbootstrap.BuildSupervisor(appCtx, cfg, logger)
 	if err != nil {
@@ -129,7 +327,7 @@ func main() {
 		os.Exit(1)
 	}
 
-	inlineWorker, conversationService := bootstrap.SetupInlineWorker(bootstrap.InlineWorkerDeps{
+	inlineWorker, conversationService, startupReport := bootstrap.SetupInlineWorker(bootstrap.InlineWorkerDeps{
 		Ctx:           appCtx,
 		Cfg:           cfg,
 		Logger:        logger,
@@ -137,12 +335,14 @@ func main() {
 		MessengerNote: webhookMessengerReason,
 		JobUpdater:    jobUpdater,
 		MemoryQueue:   memoryQueue,
+		Publisher:     conversationPublisher,
 		DBPool:        dbPool,
 		SQLDB:         sqlDB,
 		Audit:         auditSvc,
 		OutboxStore:   outboxStore,
 		Resolver:      resolver,
 		OptOutChecker: msgStore,
+		Replies:       msgStore,
 		Supervisor:    supervisor,
 		RedisClient:   redisClient,
 		SMSTranscript: smsTranscript,
@@ -150,6 +350,10 @@ func main() {
 	if conversationService != nil {
 		conversationHandler.SetService(conversationService)
 	}
+	adminDiagnosticsHandler.SetStartupReport(startupReport)
+	if redisClient != nil {
+		adminDiagnosticsHandler.SetPublishedStartupReports(appbootstrap.NewStartupReportStore(redisClient))
+	}
 
 	voiceBoot := bootstrap.BootstrapVoice(bootstrap.VoiceDeps{
 		Cfg:                   cfg,
@@ -177,6 +381,7 @@ func main() {
 		RedisClient:           redisClient,
 		OutboxStore:           outboxStore,
 		ProcessedStore:        processedStore,
+		WebhookLog:            webhookLog,
 		Resolver:              resolver,
 		PaymentsRepo:          paymentsRepo,
 		ClinicStore:           clinicStore,
@@ -191,11 +396,27 @@ func main() {
 
 	telnyxWebhookHandler := bootstrap.BuildTelnyxWebhookHandler(bootstrap.TelnyxWebhookDeps{
 		Cfg: cfg, Logger: logger, MsgStore: msgStore, TelnyxClient: telnyxClient,
-		ProcessedStore: processedStore, ConversationPub: conversationPublisher,
+		ProcessedStore: processedStore, WebhookLog: webhookLog, ConversationPub: conversationPublisher,
 		LeadsRepo: leadsRepo, SMSTranscript: smsTranscript,
 		ConversationStore: conversationStore, ClinicStore: clinicStore,
-		MessagingMetrics: messagingMetrics,
+		MessagingMetrics: messagingMetrics, Redis: redisClient,
+		NumberRoutes: messagingBoot.Router, Broadcasts: broadcastStore,
+		Audit: auditSvc, InboundHealth: inboundTracker,
 	})
+	if telnyxWebhookHandler != nil {
+		go telnyxWebhookHandler.RunConcatFlusher(appCtx)
+	}
+
+	var adminWebhooksHandler *handlers.AdminWebhooksHandler
+	if webhookLog != nil {
+		adminWebhooksHandler = handlers.NewAdminWebhooksHandler(webhookLog, logger)
+		if squareWebhookHandler != nil {
+			adminWebhooksHandler.RegisterReplayer("square", squareWebhookHandler)
+		}
+		if telnyxWebhookHandler != nil {
+			adminWebhooksHandler.RegisterReplayer("telnyx", telnyxWebhookHandler)
+		}
+	}
 
 	// Wire missed-call text-back into call control handler
 	if callControlHandler != nil && telnyxWebhookHandler != nil {
@@ -212,57 +433,95 @@ func main() {
 
 	evidenceS3 := bootstrap.BuildEvidenceS3(appCtx, cfg, logger)
 
+	var adminExperimentsHandler *handlers.AdminExperimentsHandler
+	if redisClient != nil {
+		if experiments, err := conversation.ParsePromptExperiments(cfg.PromptExperiments); err != nil {
+			logger.Warn("ignoring invalid prompt experiments", "error", err)
+		} else if len(experiments) > 0 {
+			adminExperimentsHandler = handlers.NewAdminExperimentsHandler(conversation.NewExperimentTracker(redisClient, experiments), logger)
+		}
+	}
+
+	var shortLinks *payments.ShortLinkStore
+	if dbPool != nil {
+		shortLinks = payments.NewShortLinkStore(dbPool)
+	}
+	paymentRedirect := payments.NewRedirectHandler(shortLinks, paymentsRepo, logger)
+	paymentRedirect.SetClinicStore(clinicStore)
+
 	// Notifications bootstrap
 	githubWebhookHandler := bootstrap.BootstrapNotifications(cfg, logger)
 
 	// Setup router
 	routerCfg := &router.Config{
-		Logger:                 logger,
-		LeadsHandler:           leadsHandler,
-		MessagingHandler:       messagingHandler,
-		ConversationHandler:    conversationHandler,
-		PaymentsHandler:        checkoutHandler,
-		FakePayments:           fakePaymentsHandler,
-		SquareWebhook:          squareWebhookHandler,
-		SquareOAuth:            squareOAuthHandler,
-		StripeWebhook:          stripeWebhookHandler,
-		StripeConnect:          stripeConnectHandler,
-		AdminMessaging:         adminMessagingHandler,
-		AdminClinicData:        adminClinicDataHandler,
-		TelnyxWebhooks:         telnyxWebhookHandler,
-		GitHubWebhook:          githubWebhookHandler,
-		ClinicHandler:          clinicHandler,
-		ClinicStatsHandler:     clinicStatsHandler,
-		ClinicDashboard:        clinicDashboardHandler,
-		AdminOnboarding:        adminOnboardingHandler,
-		OnboardingToken:        cfg.OnboardingToken,
-		ClientRegistration:     clientRegistrationHandler,
-		AdminAuthSecret:        cfg.AdminJWTSecret,
-		CognitoUserPoolID:      cfg.CognitoUserPoolID,
-		CognitoClientID:        cfg.CognitoClientID,
-		CognitoRegion:          cfg.CognitoRegion,
-		DB:                     sqlDB,
-		TranscriptStore:        smsTranscript,
-		ClinicStore:            clinicStore,
-		KnowledgeRepo:          knowledgeRepo,
-		AuditService:           auditSvc,
-		MetricsHandler:         metricsHandler,
-		CORSAllowedOrigins:     cfg.CORSAllowedOrigins,
-		BookingCallbackHandler: bookingCallbackHandler,
-		RedisClient:            redisClient,
-		HasSMSProvider:         len(cfg.SMSProviderIssues()) == 0,
-		PaymentRedirect:        payments.NewRedirectHandler(paymentsRepo, logger),
-		AdminBriefs:            bootstrap.NewBriefsHandler(dbPool, logger),
-		AdminFinance:           bootstrap.NewFinanceHandler(appCtx, cfg, logger),
-		AdminResearch:          bootstrap.NewResearchHandler(appCtx, cfg, logger),
-		ProspectsHandler:       bootstrap.NewProspectsHandler(sqlDB),
-		StoriesHandler:         bootstrap.NewStoriesHandler(sqlDB),
-		EvidenceS3Client:       evidenceS3,
-		EvidenceS3Bucket:       cfg.S3TrainingBucket,
-		EvidenceS3Region:       cfg.AWSRegion,
-		VoiceAIHandler:         voiceAIHandler,
-		VoiceWSHandler:         voiceWSHandler,
-		CallControlHandler:     callControlHandler,
+		Logger:                  logger,
+		LeadsHandler:            leadsHandler,
+		MessagingHandler:        messagingHandler,
+		ConversationHandler:     conversationHandler,
+		PaymentsHandler:         checkoutHandler,
+		FakePayments:            fakePaymentsHandler,
+		DemoCatalog:             demoCatalogHandler,
+		SquareWebhook:           squareWebhookHandler,
+		SquareOAuth:             squareOAuthHandler,
+		PaymentReconciliation:   paymentBoot.ReconciliationHandler,
+		StripeWebhook:           stripeWebhookHandler,
+		StripeConnect:           stripeConnectHandler,
+		AdminMessaging:          adminMessagingHandler,
+		AdminWebhooks:           adminWebhooksHandler,
+		AdminClinicData:         adminClinicDataHandler,
+		TelnyxWebhooks:          telnyxWebhookHandler,
+		GitHubWebhook:           githubWebhookHandler,
+		ClinicHandler:           clinicHandler,
+		ClinicStatsHandler:      clinicStatsHandler,
+		ClinicDashboard:         clinicDashboardHandler,
+		AdminOnboarding:         adminOnboardingHandler,
+		OnboardingToken:         cfg.OnboardingToken,
+		ClientRegistration:      clientRegistrationHandler,
+		AdminAuthSecret:         cfg.AdminJWTSecret,
+		AdminAuthIssuer:         cfg.AdminJWTIssuer,
+		AdminAuthAudience:       cfg.AdminJWTAudience,
+		CognitoUserPoolID:       cfg.CognitoUserPoolID,
+		CognitoClientID:         cfg.CognitoClientID,
+		CognitoRegion:           cfg.CognitoRegion,
+		DB:                      sqlDB,
+		TranscriptStore:         smsTranscript,
+		ColdStore:               coldStore,
+		ClinicStore:             clinicStore,
+		KnowledgeRepo:           knowledgeRepo,
+		AuditService:            auditSvc,
+		MetricsHandler:          metricsHandler,
+		CORSAllowedOrigins:      cfg.CORSAllowedOrigins,
+		BookingCallbackHandler:  bookingCallbackHandler,
+		RedisClient:             redisClient,
+		HasSMSProvider:          len(cfg.SMSProviderIssues()) == 0,
+		PaymentRedirect:         paymentRedirect,
+		PayLinkHost:             cfg.PayLinkHost(),
+		AdminBriefs:             bootstrap.NewBriefsHandler(dbPool, logger),
+		AdminFinance:            bootstrap.NewFinanceHandler(appCtx, cfg, logger),
+		AdminResearch:           bootstrap.NewResearchHandler(appCtx, cfg, logger),
+		AdminExperiments:        adminExperimentsHandler,
+		AdminRetention:          adminRetentionHandler,
+		AdminAvailabilityHealth: adminAvailabilityHealthHandler,
+		AdminDiagnostics:        adminDiagnosticsHandler,
+		AdminCRMWebhooks:        adminCRMWebhooksHandler,
+		CRMEvents:               crmEvents,
+		AdminCosts:              adminCostsHandler,
+		AdminBroadcasts:         adminBroadcastsHandler,
+		AdminConversationStream: adminConversationStreamHandler,
+		AdminJobs:               adminJobsHandler,
+		AdminPromptPreview:      adminPromptPreviewHandler,
+		AdminTemplates:          adminTemplatesHandler,
+		AdminNumberRoutes:       adminNumberRoutesHandler,
+		ProspectsHandler:        bootstrap.NewProspectsHandler(sqlDB),
+		StoriesHandler:          bootstrap.NewStoriesHandler(sqlDB),
+		APIKeysHandler:          apiKeysHandler,
+		APIKeyAuth:              apiKeyAuth,
+		EvidenceS3Client:        evidenceS3,
+		EvidenceS3Bucket:        cfg.S3TrainingBucket,
+		EvidenceS3Region:        cfg.AWSRegion,
+		VoiceAIHandler:          voiceAIHandler,
+		VoiceWSHandler:          voiceWSHandler,
+		CallControlHandler:      callControlHandler,
 		StructuredKnowledgeHandler: handlers.NewStructuredKnowledgeHandler(
 			conversation.NewStructuredKnowledgeStore(redisClient),
 			clinicStore,
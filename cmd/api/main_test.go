@@ -73,7 +73,7 @@ func TestSetupInlineWorkerDisabled(t *testing.T) {
 	logger := logging.New("error")
 	cfg := &appconfig.Config{UseMemoryQueue: false}
 
-	worker, _ := bootstrap.SetupInlineWorker(bootstrap.InlineWorkerDeps{
+	worker, _, _ := bootstrap.SetupInlineWorker(bootstrap.InlineWorkerDeps{
 		Ctx:        context.Background(),
 		Cfg:        cfg,
 		Logger:     logger,
@@ -95,7 +95,7 @@ func TestSetupInlineWorkerStartsAndStops(t *testing.T) {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	worker, _ := bootstrap.SetupInlineWorker(bootstrap.InlineWorkerDeps{
+	worker, _, _ := bootstrap.SetupInlineWorker(bootstrap.InlineWorkerDeps{
 		Ctx:           ctx,
 		Cfg:           cfg,
 		Logger:        logger,
@@ -0,0 +1,77 @@
+// Command encrypt-backfill encrypts lead and message PII written before
+// PII_ENCRYPTION_KEYS was set, and re-seals values under the active key after
+// a rotation. Progress is checkpointed per batch, so it can be stopped and
+// rerun safely.
+//
+// Usage: encrypt-backfill [-table leads|messages] [-batch 500] [-restart]
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	appbootstrap "github.com/wolfman30/medspa-ai-platform/internal/app/bootstrap"
+	appconfig "github.com/wolfman30/medspa-ai-platform/internal/config"
+	"github.com/wolfman30/medspa-ai-platform/internal/pii"
+	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
+)
+
+func main() {
+	tableName := flag.String("table", "", "table to backfill (leads or messages); all when empty")
+	batchSize := flag.Int("batch", 500, "rows per batch")
+	restart := flag.Bool("restart", false, "ignore saved progress and start from the first row")
+	flag.Parse()
+
+	cfg := appconfig.Load()
+	logger := logging.New(cfg.LogLevel)
+	if strings.TrimSpace(cfg.DatabaseURL) == "" {
+		log.Fatal("DATABASE_URL is required")
+	}
+	cipher, err := appbootstrap.BuildPIICipher(cfg, logger)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if cipher == nil {
+		log.Fatal("PII_ENCRYPTION_KEYS is required")
+	}
+
+	tables := pii.BackfillTables
+	if *tableName != "" {
+		table, ok := pii.BackfillTableByName(*tableName)
+		if !ok {
+			log.Fatalf("unknown table %q", *tableName)
+		}
+		tables = []pii.BackfillTable{table}
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	pool, err := pgxpool.New(ctx, cfg.DatabaseURL)
+	if err != nil {
+		log.Fatalf("connect postgres: %v", err)
+	}
+	defer pool.Close()
+
+	backfiller := pii.NewBackfiller(pool, cipher, logger)
+	backfiller.SetBatchSize(*batchSize)
+	for _, table := range tables {
+		if *restart {
+			if err := backfiller.Reset(ctx, table); err != nil {
+				log.Fatal(err)
+			}
+		}
+		result, err := backfiller.Run(ctx, table)
+		if err != nil {
+			log.Fatalf("backfill %s stopped after %d rows (rerun to resume): %v", table.Name, result.Scanned, err)
+		}
+		log.Printf("%s: scanned %d, encrypted %d, skipped %d", table.Name, result.Scanned, result.Encrypted, result.Skipped)
+	}
+}
@@ -8,8 +8,13 @@ import (
 	"time"
 
 	"github.com/jackc/pgx/v5/pgxpool"
+	appbootstrap "github.com/wolfman30/medspa-ai-platform/internal/app/bootstrap"
+	"github.com/wolfman30/medspa-ai-platform/internal/broadcasts"
+	"github.com/wolfman30/medspa-ai-platform/internal/clinic"
 	"github.com/wolfman30/medspa-ai-platform/internal/config"
+	"github.com/wolfman30/medspa-ai-platform/internal/leads"
 	"github.com/wolfman30/medspa-ai-platform/internal/messaging"
+	"github.com/wolfman30/medspa-ai-platform/internal/messaging/compliance"
 	"github.com/wolfman30/medspa-ai-platform/internal/messaging/telnyxclient"
 	"github.com/wolfman30/medspa-ai-platform/internal/worker/messaging"
 	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
@@ -44,7 +49,13 @@ func main() {
 		os.Exit(1)
 	}
 
+	cipher, err := appbootstrap.BuildPIICipher(cfg, logger)
+	if err != nil {
+		logger.Error("failed to configure pii encryption", "error", err)
+		os.Exit(1)
+	}
 	store := messaging.NewStore(pool)
+	store.SetCipher(cipher)
 
 	retryInterval := cfg.TelnyxRetryBaseDelay / 2
 	if retryInterval <= 0 {
@@ -58,8 +69,33 @@ func main() {
 	hosted := messagingworker.NewHostedPoller(store, telnyxClient, logger).
 		WithInterval(cfg.TelnyxHostedPollInterval)
 
+	leadsRepo := leads.NewPostgresRepository(pool)
+	leadsRepo.SetCipher(cipher)
+	broadcastStore := broadcasts.NewStore(pool)
+	broadcastStore.SetCipher(cipher)
+	broadcaster := messagingworker.NewBroadcastSender(broadcastStore, store, telnyxClient, logger).
+		WithRatePerMinute(cfg.BroadcastRatePerMinute).
+		WithConsent(leadsRepo).
+		WithCampaigns(store.Provisioning()).
+		WithMessagingProfile(cfg.TelnyxMessagingProfileID)
+	quietStart, quietEnd := cfg.QuietHoursStart, cfg.QuietHoursEnd
+	if quietStart != "" && quietEnd != "" {
+		if _, err := compliance.ParseQuietHours(quietStart, quietEnd, ""); err != nil {
+			logger.Warn("invalid quiet hours config; broadcasts use the default 21:00-08:00 window", "error", err)
+			quietStart, quietEnd = "", ""
+		}
+	}
+	broadcaster.WithQuietHours(quietStart, quietEnd, cfg.QuietHoursTimezone)
+	if redisClient := appbootstrap.BuildRedisClient(ctx, cfg, logger, true); redisClient != nil {
+		defer redisClient.Close()
+		broadcaster.WithClinics(clinic.NewStore(redisClient))
+	} else {
+		logger.Warn("redis unavailable; broadcast quiet hours use QUIET_HOURS_TZ for every clinic")
+	}
+
 	go retry.Run(ctx)
 	go hosted.Run(ctx)
+	go broadcaster.Run(ctx)
 
 	stop := make(chan os.Signal, 1)
 	signal.Notify(stop, syscall.SIGINT, syscall.SIGTERM)
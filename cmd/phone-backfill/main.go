@@ -0,0 +1,60 @@
+// Command phone-backfill rewrites lead phones into E.164 and merges leads
+// that turn out to share a number once normalized, moving their
+// conversations, bookings, payments and tasks onto the newest lead. Merges
+// are recorded in lead_phone_merges. Each merge commits on its own, so it can
+// be stopped and rerun safely.
+//
+// Usage: phone-backfill
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	appbootstrap "github.com/wolfman30/medspa-ai-platform/internal/app/bootstrap"
+	appconfig "github.com/wolfman30/medspa-ai-platform/internal/config"
+	"github.com/wolfman30/medspa-ai-platform/internal/leads"
+	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
+)
+
+func main() {
+	cfg := appconfig.Load()
+	logger := logging.New(cfg.LogLevel)
+	if strings.TrimSpace(cfg.DatabaseURL) == "" {
+		log.Fatal("DATABASE_URL is required")
+	}
+	cipher, err := appbootstrap.BuildPIICipher(cfg, logger)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	pool, err := pgxpool.New(ctx, cfg.DatabaseURL)
+	if err != nil {
+		log.Fatalf("connect postgres: %v", err)
+	}
+	defer pool.Close()
+
+	backfiller := leads.NewPhoneBackfiller(pool, cipher, logger)
+	// Clinic configs say which region numbers without a country code are in;
+	// without Redis every clinic is read as US.
+	if clinicStore := appbootstrap.BuildClinicStore(appbootstrap.BuildRedisClient(ctx, cfg, logger, true)); clinicStore != nil {
+		backfiller.SetClinicStore(clinicStore)
+	} else {
+		logger.Warn("redis unavailable; reading all lead phones as US numbers")
+	}
+
+	result, err := backfiller.Run(ctx)
+	if err != nil {
+		log.Fatalf("phone backfill stopped (rerun to resume): %v", err)
+	}
+	log.Printf("leads: scanned %d, normalized %d, merged %d, invalid %d", result.Scanned, result.Normalized, result.Merged, result.Invalid)
+}
@@ -1,12 +1,10 @@
 package main
 
 import (
-	"bytes"
 	"context"
 	"encoding/base64"
 	"errors"
 	"fmt"
-	"io"
 	"net/http"
 	"os"
 	"strings"
@@ -14,11 +12,18 @@ import (
 
 	"github.com/aws/aws-lambda-go/events"
 	"github.com/aws/aws-lambda-go/lambda"
+
+	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
 )
 
+var logger = logging.New(os.Getenv("LOG_LEVEL"))
+
 type config struct {
 	upstreamBaseURL string
 	upstreamTimeout time.Duration
+	// webhookBudget caps the total time spent on upstream attempts.
+	webhookBudget time.Duration
+	retryBackoff  time.Duration
 }
 
 func loadConfig() (config, error) {
@@ -36,9 +41,20 @@ func loadConfig() (config, error) {
 		timeout = parsed
 	}
 
+	budget := defaultWebhookBudget
+	if raw := strings.TrimSpace(os.Getenv("WEBHOOK_BUDGET")); raw != "" {
+		parsed, err := time.ParseDuration(raw)
+		if err != nil {
+			return config{}, fmt.Errorf("invalid WEBHOOK_BUDGET: %w", err)
+		}
+		budget = parsed
+	}
+
 	return config{
 		upstreamBaseURL: strings.TrimRight(baseURL, "/"),
 		upstreamTimeout: timeout,
+		webhookBudget:   budget,
+		retryBackoff:    defaultRetryBackoff,
 	}, nil
 }
 
@@ -70,7 +86,10 @@ func handle(ctx context.Context, cfg config, client *http.Client, evt events.API
 	}
 
 	switch path {
-	case "/webhooks/twilio/voice", "/webhooks/telnyx/voice":
+	case "/webhooks/twilio/voice", "/webhooks/telnyx/voice",
+		"/webhooks/twilio/voice/gather", "/webhooks/telnyx/voice/gather",
+		"/webhooks/twilio/voice/voicemail", "/webhooks/telnyx/voice/voicemail",
+		"/webhooks/twilio/voice/recording", "/webhooks/telnyx/voice/recording":
 	default:
 		return events.APIGatewayV2HTTPResponse{StatusCode: http.StatusNotFound}, nil
 	}
@@ -85,22 +104,15 @@ func handle(ctx context.Context, cfg config, client *http.Client, evt events.API
 		upstreamURL += "?" + qs
 	}
 
-	reqCtx, cancel := context.WithTimeout(ctx, cfg.upstreamTimeout)
-	defer cancel()
-
-	req, err := http.NewRequestWithContext(reqCtx, http.MethodPost, upstreamURL, bytes.NewReader(body))
-	if err != nil {
-		return events.APIGatewayV2HTTPResponse{StatusCode: http.StatusInternalServerError}, nil
-	}
-
+	header := http.Header{}
 	if ct := headerValue(evt.Headers, "content-type"); ct != "" {
-		req.Header.Set("Content-Type", ct)
+		header.Set("Content-Type", ct)
 	}
 
 	// Preserve provider signature headers so the upstream API can validate them.
-	copyHeader(req.Header, evt.Headers, "x-twilio-signature")
-	copyHeader(req.Header, evt.Headers, "telnyx-timestamp")
-	copyHeader(req.Header, evt.Headers, "telnyx-signature")
+	copyHeader(header, evt.Headers, "x-twilio-signature")
+	copyHeader(header, evt.Headers, "telnyx-timestamp")
+	copyHeader(header, evt.Headers, "telnyx-signature")
 
 	// Preserve the original public URL host/proto for Twilio signature validation.
 	originalHost := strings.TrimSpace(evt.RequestContext.DomainName)
@@ -112,26 +124,38 @@ func handle(ctx context.Context, cfg config, client *http.Client, evt events.API
 		originalProto = "https"
 	}
 	if originalHost != "" {
-		req.Header.Set("X-Forwarded-Host", originalHost)
+		header.Set("X-Forwarded-Host", originalHost)
 	}
 	if originalProto != "" {
-		req.Header.Set("X-Forwarded-Proto", originalProto)
+		header.Set("X-Forwarded-Proto", originalProto)
 	}
 
-	resp, err := client.Do(req)
-	if err != nil {
-		return events.APIGatewayV2HTTPResponse{StatusCode: http.StatusBadGateway, Body: "upstream error"}, nil
+	start := time.Now()
+	res := forward(ctx, cfg, client, upstreamURL, body, header)
+	failed := res.err != nil || res.status >= http.StatusInternalServerError
+	logArgs := []any{
+		"path", path,
+		"provider", providerForPath(path),
+		"request_id", evt.RequestContext.RequestID,
+		"upstream_status", res.status,
+		"latency_ms", time.Since(start).Milliseconds(),
+		"attempts", res.attempts,
+		"error_class", res.errClass,
+		"fallback", failed,
+	}
+	if failed {
+		logger.Error("voice webhook upstream failed", append(logArgs, "error", errString(res.err))...)
+		return fallbackResponse(path), nil
 	}
-	defer resp.Body.Close()
+	logger.Info("voice webhook proxied", logArgs...)
 
-	respBody, _ := io.ReadAll(resp.Body)
 	out := events.APIGatewayV2HTTPResponse{
-		StatusCode: resp.StatusCode,
-		Body:       string(respBody),
+		StatusCode: res.status,
+		Body:       string(res.body),
 		Headers:    map[string]string{},
 	}
-	if ct := resp.Header.Get("Content-Type"); ct != "" {
-		out.Headers["content-type"] = ct
+	if res.contentType != "" {
+		out.Headers["content-type"] = res.contentType
 	}
 	return out, nil
 }
@@ -224,3 +224,190 @@ func TestDecodeBodyBase64(t *testing.T) {
 		t.Fatalf("expected decoded body, got %q", string(decoded))
 	}
 }
+
+func TestHandleForwardsGatherCallbacks(t *testing.T) {
+	for _, path := range []string{"/webhooks/twilio/voice/gather", "/webhooks/telnyx/voice/gather"} {
+		t.Run(path, func(t *testing.T) {
+			var gotPath string
+			upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				gotPath = r.URL.Path
+				w.Header().Set("Content-Type", "application/xml")
+				_, _ = w.Write([]byte("<Response/>"))
+			}))
+			defer upstream.Close()
+
+			cfg := config{upstreamBaseURL: upstream.URL, upstreamTimeout: time.Second}
+			evt := events.APIGatewayV2HTTPRequest{
+				RawPath: path,
+				Body:    "Digits=1",
+				Headers: map[string]string{"content-type": "application/x-www-form-urlencoded"},
+				RequestContext: events.APIGatewayV2HTTPRequestContext{
+					HTTP: events.APIGatewayV2HTTPRequestContextHTTPDescription{Method: http.MethodPost, Path: path},
+				},
+			}
+
+			resp, err := handle(context.Background(), cfg, upstream.Client(), evt)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if resp.StatusCode != http.StatusOK || gotPath != path {
+				t.Fatalf("expected gather callback forwarded to %s, got status %d path %q", path, resp.StatusCode, gotPath)
+			}
+			if resp.Headers["content-type"] != "application/xml" {
+				t.Fatalf("expected xml content type, got %q", resp.Headers["content-type"])
+			}
+		})
+	}
+}
+
+func TestAttemptTimeoutBudget(t *testing.T) {
+	tests := []struct {
+		name       string
+		attempt    int
+		remaining  time.Duration
+		perAttempt time.Duration
+		backoff    time.Duration
+		want       time.Duration
+	}{
+		{"first attempt capped by per-attempt timeout", 0, 10 * time.Second, 3 * time.Second, 250 * time.Millisecond, 3 * time.Second},
+		{"first attempt leaves room for a retry", 0, 10 * time.Second, 8 * time.Second, 250 * time.Millisecond, 4875 * time.Millisecond},
+		{"retry gets what is left", 1, 4 * time.Second, 8 * time.Second, 250 * time.Millisecond, 4 * time.Second},
+		{"retry still capped", 1, 4 * time.Second, time.Second, 250 * time.Millisecond, time.Second},
+		{"budget spent", 1, -time.Second, time.Second, 0, 0},
+	}
+	for _, tt := range tests {
+		if got := attemptTimeout(tt.attempt, tt.remaining, tt.perAttempt, tt.backoff); got != tt.want {
+			t.Errorf("%s: attemptTimeout = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+
+	// Both attempts plus the backoff always fit inside the budget.
+	budget, perAttempt, backoff := 10*time.Second, 8*time.Second, 250*time.Millisecond
+	first := attemptTimeout(0, budget, perAttempt, backoff)
+	remaining := budget - first
+	if !canRetry(remaining, backoff) {
+		t.Fatalf("expected room to retry after %v", first)
+	}
+	second := attemptTimeout(1, remaining-backoff, perAttempt, backoff)
+	if total := first + backoff + second; total > budget {
+		t.Fatalf("attempts take %v, over the %v budget", total, budget)
+	}
+	if canRetry(600*time.Millisecond, backoff) {
+		t.Fatal("expected no retry when less than the minimum attempt remains")
+	}
+}
+
+func TestRetryable(t *testing.T) {
+	tests := []struct {
+		errClass string
+		status   int
+		want     bool
+	}{
+		{errClassConnection, 0, true},
+		{errClassUpstream5x, http.StatusBadGateway, true},
+		{errClassUpstream5x, http.StatusServiceUnavailable, true},
+		{errClassUpstream5x, http.StatusInternalServerError, false},
+		{errClassUpstream4x, http.StatusForbidden, false},
+		{errClassTimeout, 0, false},
+		{errClassRead, http.StatusOK, false},
+	}
+	for _, tt := range tests {
+		if got := retryable(tt.errClass, tt.status); got != tt.want {
+			t.Errorf("retryable(%q, %d) = %v, want %v", tt.errClass, tt.status, got, tt.want)
+		}
+	}
+	if got := classifyError(context.DeadlineExceeded); got != errClassTimeout {
+		t.Fatalf("classifyError(deadline) = %q, want timeout", got)
+	}
+}
+
+func voiceEvent(path string) events.APIGatewayV2HTTPRequest {
+	return events.APIGatewayV2HTTPRequest{
+		RawPath: path,
+		Body:    "CallSid=CA123",
+		Headers: map[string]string{"content-type": "application/x-www-form-urlencoded"},
+		RequestContext: events.APIGatewayV2HTTPRequestContext{
+			HTTP: events.APIGatewayV2HTTPRequestContextHTTPDescription{Method: http.MethodPost, Path: path},
+		},
+	}
+}
+
+func TestHandleRetriesTransientUpstreamFailure(t *testing.T) {
+	calls := 0
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		body, _ := io.ReadAll(r.Body)
+		if string(body) != "CallSid=CA123" {
+			t.Errorf("attempt %d: expected the body replayed, got %q", calls, body)
+		}
+		if calls == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "application/xml")
+		_, _ = w.Write([]byte("<Response/>"))
+	}))
+	defer upstream.Close()
+
+	cfg := config{upstreamBaseURL: upstream.URL, upstreamTimeout: time.Second, webhookBudget: 5 * time.Second, retryBackoff: time.Millisecond}
+	resp, err := handle(context.Background(), cfg, upstream.Client(), voiceEvent("/webhooks/twilio/voice"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if calls != 2 || resp.StatusCode != http.StatusOK || resp.Body != "<Response/>" {
+		t.Fatalf("expected the retry to succeed, got %d calls, status %d, body %q", calls, resp.StatusCode, resp.Body)
+	}
+}
+
+func TestHandleDoesNotRetryClientErrors(t *testing.T) {
+	calls := 0
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer upstream.Close()
+
+	cfg := config{upstreamBaseURL: upstream.URL, upstreamTimeout: time.Second, retryBackoff: time.Millisecond}
+	resp, err := handle(context.Background(), cfg, upstream.Client(), voiceEvent("/webhooks/telnyx/voice"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if calls != 1 || resp.StatusCode != http.StatusForbidden {
+		t.Fatalf("expected a single attempt passed through, got %d calls, status %d", calls, resp.StatusCode)
+	}
+}
+
+func TestHandleFallbackResponses(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer upstream.Close()
+
+	want := map[string]string{
+		"/webhooks/twilio/voice":        callbackFallbackXML,
+		"/webhooks/telnyx/voice":        callbackFallbackXML,
+		"/webhooks/twilio/voice/gather": gatherFallbackXML,
+		"/webhooks/telnyx/voice/gather": gatherFallbackXML,
+	}
+	for path, body := range want {
+		t.Run(path, func(t *testing.T) {
+			cfg := config{upstreamBaseURL: upstream.URL, upstreamTimeout: time.Second, retryBackoff: time.Millisecond}
+			resp, err := handle(context.Background(), cfg, upstream.Client(), voiceEvent(path))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if resp.StatusCode != http.StatusOK || resp.Body != body || resp.Headers["content-type"] != "application/xml" {
+				t.Fatalf("expected fallback TwiML, got %d %q %v", resp.StatusCode, resp.Body, resp.Headers)
+			}
+		})
+	}
+
+	// Connection refused: retried, then the fallback.
+	closed := httptest.NewServer(http.NotFoundHandler())
+	closed.Close()
+	cfg := config{upstreamBaseURL: closed.URL, upstreamTimeout: time.Second, retryBackoff: time.Millisecond}
+	resp, _ := handle(context.Background(), cfg, &http.Client{}, voiceEvent("/webhooks/twilio/voice"))
+	if resp.Body != callbackFallbackXML {
+		t.Fatalf("expected fallback on connection error, got %d %q", resp.StatusCode, resp.Body)
+	}
+}
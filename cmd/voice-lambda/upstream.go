@@ -0,0 +1,211 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+const (
+	// defaultWebhookBudget keeps the whole proxy call, retry included, under
+	// the voice providers' webhook timeouts.
+	defaultWebhookBudget = 10 * time.Second
+	defaultRetryBackoff  = 250 * time.Millisecond
+	// minAttemptTimeout is the least time worth giving a retry.
+	minAttemptTimeout = 500 * time.Millisecond
+	// deadlineMargin leaves the Lambda time to return the fallback before its
+	// own deadline.
+	deadlineMargin = 200 * time.Millisecond
+)
+
+// Error classes reported in logs.
+const (
+	errClassNone       = ""
+	errClassTimeout    = "timeout"
+	errClassConnection = "connection"
+	errClassRead       = "read"
+	errClassRequest    = "request"
+	errClassUpstream5x = "upstream_5xx"
+	errClassUpstream4x = "upstream_4xx"
+)
+
+const (
+	callbackFallbackXML = `<?xml version="1.0" encoding="UTF-8"?><Response><Say>Sorry, we can't take your call right now. Please call back in a few minutes.</Say><Hangup/></Response>`
+	gatherFallbackXML   = `<?xml version="1.0" encoding="UTF-8"?><Response><Say>Sorry, we couldn't process that. Please call back in a few minutes.</Say><Hangup/></Response>`
+)
+
+// fallbackResponse is the TwiML/TeXML returned when the upstream can't be
+// reached, so callers hear a message instead of the provider's error tone.
+func fallbackResponse(path string) events.APIGatewayV2HTTPResponse {
+	body := callbackFallbackXML
+	if strings.HasSuffix(path, "/gather") {
+		body = gatherFallbackXML
+	}
+	return events.APIGatewayV2HTTPResponse{
+		StatusCode: http.StatusOK,
+		Body:       body,
+		Headers:    map[string]string{"content-type": "application/xml"},
+	}
+}
+
+// providerForPath returns the voice provider a webhook path belongs to.
+func providerForPath(path string) string {
+	switch {
+	case strings.HasPrefix(path, "/webhooks/twilio/"):
+		return "twilio"
+	case strings.HasPrefix(path, "/webhooks/telnyx/"):
+		return "telnyx"
+	default:
+		return "unknown"
+	}
+}
+
+// attemptTimeout returns how long an attempt may run given the time left in
+// the webhook budget. The first attempt leaves room for the backoff and an
+// equal-length retry; each attempt is also capped at perAttempt.
+func attemptTimeout(attempt int, remaining, perAttempt, backoff time.Duration) time.Duration {
+	limit := remaining
+	if attempt == 0 {
+		limit = (remaining - backoff) / 2
+	}
+	if perAttempt > 0 && perAttempt < limit {
+		limit = perAttempt
+	}
+	if limit < 0 {
+		return 0
+	}
+	return limit
+}
+
+// canRetry reports whether enough budget remains for a retry after backoff.
+func canRetry(remaining, backoff time.Duration) bool {
+	return remaining-backoff >= minAttemptTimeout
+}
+
+// classifyError sorts a transport error into a log class. Timeouts are not
+// retried because the upstream may still be handling the call.
+func classifyError(err error) string {
+	if err == nil {
+		return errClassNone
+	}
+	var netErr net.Error
+	if errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout()) {
+		return errClassTimeout
+	}
+	return errClassConnection
+}
+
+// classifyStatus sorts an upstream status code into a log class.
+func classifyStatus(status int) string {
+	switch {
+	case status >= 500:
+		return errClassUpstream5x
+	case status >= 400:
+		return errClassUpstream4x
+	default:
+		return errClassNone
+	}
+}
+
+// retryable reports whether a failed attempt is safe to repeat: connection
+// errors and 502/503, never 4xx.
+func retryable(errClass string, status int) bool {
+	if errClass == errClassConnection {
+		return true
+	}
+	return status == http.StatusBadGateway || status == http.StatusServiceUnavailable
+}
+
+// upstreamResult is the outcome of forwarding one webhook.
+type upstreamResult struct {
+	status      int
+	body        []byte
+	contentType string
+	attempts    int
+	errClass    string
+	err         error
+}
+
+// forward posts the webhook upstream, retrying once within the budget.
+func forward(ctx context.Context, cfg config, client *http.Client, url string, body []byte, header http.Header) upstreamResult {
+	budget := cfg.webhookBudget
+	if budget <= 0 {
+		budget = defaultWebhookBudget
+	}
+	deadline := time.Now().Add(budget)
+	if d, ok := ctx.Deadline(); ok && d.Add(-deadlineMargin).Before(deadline) {
+		deadline = d.Add(-deadlineMargin)
+	}
+
+	var res upstreamResult
+	for attempt := 0; attempt < 2; attempt++ {
+		timeout := attemptTimeout(attempt, time.Until(deadline), cfg.upstreamTimeout, cfg.retryBackoff)
+		if timeout <= 0 {
+			break
+		}
+		res = doAttempt(ctx, timeout, client, url, body, header)
+		res.attempts = attempt + 1
+		if res.errClass == errClassNone || !retryable(res.errClass, res.status) {
+			return res
+		}
+
+		logger.Warn("voice upstream attempt failed",
+			"attempt", res.attempts,
+			"upstream_status", res.status,
+			"error_class", res.errClass,
+			"error", errString(res.err),
+		)
+		if attempt > 0 || !canRetry(time.Until(deadline), cfg.retryBackoff) {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			return res
+		case <-time.After(cfg.retryBackoff):
+		}
+	}
+	return res
+}
+
+func doAttempt(ctx context.Context, timeout time.Duration, client *http.Client, url string, body []byte, header http.Header) upstreamResult {
+	reqCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(reqCtx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return upstreamResult{errClass: errClassRequest, err: err}
+	}
+	req.Header = header.Clone()
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return upstreamResult{errClass: classifyError(err), err: err}
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		// The upstream already handled the call, so this is never retried.
+		return upstreamResult{status: resp.StatusCode, errClass: errClassRead, err: err}
+	}
+	return upstreamResult{
+		status:      resp.StatusCode,
+		body:        respBody,
+		contentType: resp.Header.Get("Content-Type"),
+		errClass:    classifyStatus(resp.StatusCode),
+	}
+}
+
+func errString(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
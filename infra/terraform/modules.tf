@@ -79,6 +79,7 @@ module "ecs_fargate" {
     COGNITO_CLIENT_ID            = var.cognito_client_id
     COGNITO_REGION               = var.cognito_region
     PUBLIC_BASE_URL              = var.api_public_base_url
+    PAY_LINK_BASE_URL            = var.pay_link_base_url
     CORS_ALLOWED_ORIGINS         = var.environment == "production" ? "https://aiwolfsolutions.com,https://www.aiwolfsolutions.com,https://portal.aiwolfsolutions.com,https://wolfman30.github.io" : "http://localhost:8000,https://aiwolfsolutions.com,https://www.aiwolfsolutions.com,https://portal-dev.aiwolfsolutions.com,https://wolfman30.github.io"
     ALLOW_FAKE_PAYMENTS          = var.environment != "production" && var.api_public_base_url != "" ? "true" : "false"
     REDIS_ADDR                   = "${module.redis.primary_endpoint_address}:${module.redis.port}"
@@ -137,6 +137,12 @@ variable "api_public_base_url" {
   default     = ""
 }
 
+variable "pay_link_base_url" {
+  description = "Optional branded domain for short payment links (e.g. https://pay.aiwolfsolutions.com); must route to the API. Empty uses {api_public_base_url}/pay"
+  type        = string
+  default     = ""
+}
+
 variable "voice_upstream_base_url" {
   description = "Optional override for voice-lambda upstream base URL (default uses the ALB DNS over HTTP)"
   type        = string
@@ -0,0 +1,78 @@
+package router
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/apikeys"
+	"github.com/wolfman30/medspa-ai-platform/internal/tenancy"
+	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
+)
+
+// requireAPIKey authenticates "Authorization: Bearer <key>" against org API
+// keys, checks the key belongs to the {orgID} in the path, and scopes the
+// request to that org.
+func requireAPIKey(auth apikeys.Authenticator, logger *logging.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			token, ok := bearerToken(r)
+			if !ok {
+				w.Header().Set("WWW-Authenticate", "Bearer")
+				http.Error(w, `{"error":"missing api key"}`, http.StatusUnauthorized)
+				return
+			}
+
+			key, err := auth.Authenticate(r.Context(), token)
+			if err != nil {
+				switch {
+				case errors.Is(err, apikeys.ErrRevokedKey):
+					http.Error(w, `{"error":"api key revoked"}`, http.StatusUnauthorized)
+				case errors.Is(err, apikeys.ErrInvalidKey):
+					http.Error(w, `{"error":"invalid api key"}`, http.StatusUnauthorized)
+				default:
+					if logger != nil {
+						logger.Error("api key authentication failed", "error", err)
+					}
+					http.Error(w, `{"error":"internal error"}`, http.StatusInternalServerError)
+				}
+				return
+			}
+
+			orgID := strings.TrimSpace(chi.URLParam(r, "orgID"))
+			if orgID == "" || orgID != key.OrgID {
+				http.Error(w, `{"error":"forbidden"}`, http.StatusForbidden)
+				return
+			}
+
+			ctx := tenancy.WithOrgID(r.Context(), key.OrgID)
+			ctx = apikeys.WithKey(ctx, key)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// requireScope rejects API key requests whose key lacks scope.
+func requireScope(scope string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key, ok := apikeys.FromContext(r.Context())
+			if !ok || !key.HasScope(scope) {
+				http.Error(w, `{"error":"insufficient scope"}`, http.StatusForbidden)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func bearerToken(r *http.Request) (string, bool) {
+	scheme, token, ok := strings.Cut(strings.TrimSpace(r.Header.Get("Authorization")), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+	token = strings.TrimSpace(token)
+	return token, token != ""
+}
@@ -0,0 +1,170 @@
+package router
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/apikeys"
+	"github.com/wolfman30/medspa-ai-platform/internal/clinic"
+	"github.com/wolfman30/medspa-ai-platform/internal/leads"
+	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
+)
+
+type fakeAPIKeyAuth struct {
+	keys map[string]*apikeys.Key
+	err  map[string]error
+}
+
+func (f *fakeAPIKeyAuth) Authenticate(_ context.Context, rawKey string) (*apikeys.Key, error) {
+	if err, ok := f.err[rawKey]; ok {
+		return nil, err
+	}
+	if key, ok := f.keys[rawKey]; ok {
+		return key, nil
+	}
+	return nil, apikeys.ErrInvalidKey
+}
+
+type zeroStatsDB struct{}
+
+func (zeroStatsDB) QueryRow(context.Context, string, ...any) pgx.Row { return zeroRow{} }
+
+func (zeroStatsDB) Query(context.Context, string, ...any) (pgx.Rows, error) { return emptyRows{}, nil }
+
+// emptyRows is a result set with no rows.
+type emptyRows struct{ pgx.Rows }
+
+func (emptyRows) Next() bool { return false }
+func (emptyRows) Close()     {}
+func (emptyRows) Err() error { return nil }
+
+type zeroRow struct{}
+
+func (zeroRow) Scan(dest ...any) error {
+	for _, d := range dest {
+		if p, ok := d.(*int64); ok {
+			*p = 0
+		}
+	}
+	return nil
+}
+
+const testAdminSecret = "admin-secret"
+
+func newPartnerTestRouter(t *testing.T) http.Handler {
+	t.Helper()
+	logger := logging.Default()
+	auth := &fakeAPIKeyAuth{
+		keys: map[string]*apikeys.Key{
+			"key-leads": {ID: "k1", OrgID: "org-a", Scopes: []string{apikeys.ScopeLeadsWrite}},
+			"key-stats": {ID: "k2", OrgID: "org-a", Scopes: []string{apikeys.ScopeStatsRead}},
+		},
+		err: map[string]error{
+			"key-revoked": apikeys.ErrRevokedKey,
+		},
+	}
+	return New(&Config{
+		Logger:             logger,
+		LeadsHandler:       leads.NewHandler(leads.NewInMemoryRepository(), logger),
+		ClinicStatsHandler: clinic.NewStatsHandler(clinic.NewStatsRepositoryWithDB(zeroStatsDB{}), logger),
+		AdminAuthSecret:    testAdminSecret,
+		APIKeyAuth:         auth,
+	})
+}
+
+func partnerRequest(method, path, bearer string, body []byte) *http.Request {
+	req := httptest.NewRequest(method, path, bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	if bearer != "" {
+		req.Header.Set("Authorization", "Bearer "+bearer)
+	}
+	return req
+}
+
+func TestPartnerRoutesEnforceScopes(t *testing.T) {
+	router := newPartnerTestRouter(t)
+	leadBody, _ := json.Marshal(leads.CreateLeadRequest{Name: "Agency Lead", Phone: "+15550001111", Source: "agency"})
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		key    string
+		body   []byte
+		want   int
+	}{
+		{"leads key creates lead", http.MethodPost, "/v1/orgs/org-a/leads", "key-leads", leadBody, http.StatusCreated},
+		{"stats key cannot create lead", http.MethodPost, "/v1/orgs/org-a/leads", "key-stats", leadBody, http.StatusForbidden},
+		{"stats key reads funnel", http.MethodGet, "/v1/orgs/org-a/stats/funnel", "key-stats", nil, http.StatusOK},
+		{"leads key cannot read funnel", http.MethodGet, "/v1/orgs/org-a/stats/funnel", "key-leads", nil, http.StatusForbidden},
+		{"key for another org is forbidden", http.MethodGet, "/v1/orgs/org-b/stats/funnel", "key-stats", nil, http.StatusForbidden},
+		{"missing key", http.MethodGet, "/v1/orgs/org-a/stats/funnel", "", nil, http.StatusUnauthorized},
+		{"unknown key", http.MethodGet, "/v1/orgs/org-a/stats/funnel", "nope", nil, http.StatusUnauthorized},
+		{"revoked key", http.MethodGet, "/v1/orgs/org-a/stats/funnel", "key-revoked", nil, http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, partnerRequest(tt.method, tt.path, tt.key, tt.body))
+			if rr.Code != tt.want {
+				t.Fatalf("expected %d, got %d: %s", tt.want, rr.Code, rr.Body.String())
+			}
+		})
+	}
+}
+
+func TestPartnerLeadScopedToKeyOrg(t *testing.T) {
+	router := newPartnerTestRouter(t)
+	body, _ := json.Marshal(leads.CreateLeadRequest{Name: "Agency Lead", Phone: "+15550001111", OrgID: "org-b"})
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, partnerRequest(http.MethodPost, "/v1/orgs/org-a/leads", "key-leads", body))
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", rr.Code, rr.Body.String())
+	}
+	var created leads.Lead
+	if err := json.NewDecoder(rr.Body).Decode(&created); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if created.OrgID != "org-a" {
+		t.Fatalf("expected lead in key's org, got %q", created.OrgID)
+	}
+}
+
+func TestAdminRoutesUnaffectedByAPIKeys(t *testing.T) {
+	router := newPartnerTestRouter(t)
+	claims := jwt.RegisteredClaims{Subject: "admin", ExpiresAt: jwt.NewNumericDate(time.Now().Add(5 * time.Minute))}
+	adminToken, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(testAdminSecret))
+	if err != nil {
+		t.Fatalf("sign token: %v", err)
+	}
+
+	// Admin JWT still works on admin routes.
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, partnerRequest(http.MethodGet, "/admin/clinics/org-a/stats", adminToken, nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("admin JWT: expected 200, got %d", rr.Code)
+	}
+
+	// An API key is not an admin credential.
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, partnerRequest(http.MethodGet, "/admin/clinics/org-a/stats", "key-stats", nil))
+	if rr.Code != http.StatusUnauthorized {
+		t.Fatalf("api key on admin route: expected 401, got %d", rr.Code)
+	}
+
+	// And the admin JWT is not an API key.
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, partnerRequest(http.MethodGet, "/v1/orgs/org-a/stats/funnel", adminToken, nil))
+	if rr.Code != http.StatusUnauthorized {
+		t.Fatalf("admin JWT on partner route: expected 401, got %d", rr.Code)
+	}
+}
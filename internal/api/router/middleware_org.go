@@ -4,6 +4,8 @@ import (
 	"net/http"
 	"strings"
 
+	"github.com/go-chi/chi/v5"
+
 	"github.com/wolfman30/medspa-ai-platform/internal/tenancy"
 )
 
@@ -22,6 +24,18 @@ func requireOrgID(next http.Handler) http.Handler {
 	})
 }
 
+// scopeRouteOrg sets the request's tenancy org from the {orgID} route param,
+// after auth has cleared the caller for it. Routes without the param stay
+// unscoped; handlers that mean every clinic must say tenancy.AllOrgs.
+func scopeRouteOrg(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if orgID := strings.TrimSpace(chi.URLParam(r, "orgID")); orgID != "" {
+			r = r.WithContext(tenancy.WithOrgID(r.Context(), orgID))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
 // orgIDFromRequest exposes the org id for local handlers.
 func orgIDFromRequest(r *http.Request) (string, bool) {
 	return tenancy.OrgIDFromContext(r.Context())
@@ -4,6 +4,10 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/tenancy"
 )
 
 func TestRequireOrgIDPassesThrough(t *testing.T) {
@@ -39,3 +43,27 @@ func TestRequireOrgIDMissingHeader(t *testing.T) {
 		t.Fatalf("expected 400 for missing org, got %d", rr.Code)
 	}
 }
+
+func TestScopeRouteOrgSetsScopeFromRoute(t *testing.T) {
+	var got tenancy.OrgScope
+	r := chi.NewRouter()
+	r.Group(func(g chi.Router) {
+		g.Use(scopeRouteOrg)
+		g.Get("/orgs/{orgID}/leads", func(w http.ResponseWriter, r *http.Request) {
+			got = tenancy.ScopeFromContext(r.Context())
+		})
+		g.Get("/orgs", func(w http.ResponseWriter, r *http.Request) {
+			got = tenancy.ScopeFromContext(r.Context())
+		})
+	})
+
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/orgs/org-a/leads", nil))
+	if got.OrgID() != "org-a" || got.IsAll() {
+		t.Fatalf("expected org-a scope, got %s", got)
+	}
+
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/orgs", nil))
+	if got.Valid() {
+		t.Fatalf("expected route without orgID to stay unscoped, got %s", got)
+	}
+}
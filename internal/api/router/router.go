@@ -8,10 +8,14 @@ import (
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/redis/go-redis/v9"
+	"github.com/wolfman30/medspa-ai-platform/internal/apikeys"
 	"github.com/wolfman30/medspa-ai-platform/internal/channels/instagram"
 	"github.com/wolfman30/medspa-ai-platform/internal/clinic"
+	"github.com/wolfman30/medspa-ai-platform/internal/clinicdata"
 	"github.com/wolfman30/medspa-ai-platform/internal/compliance"
 	"github.com/wolfman30/medspa-ai-platform/internal/conversation"
+	"github.com/wolfman30/medspa-ai-platform/internal/demo"
+	"github.com/wolfman30/medspa-ai-platform/internal/http/apierror"
 	"github.com/wolfman30/medspa-ai-platform/internal/http/handlers"
 	httpmiddleware "github.com/wolfman30/medspa-ai-platform/internal/http/middleware"
 	"github.com/wolfman30/medspa-ai-platform/internal/leads"
@@ -32,9 +36,11 @@ type Config struct {
 	ConversationHandler *conversation.Handler
 	PaymentsHandler     *payments.CheckoutHandler
 	FakePayments        *payments.FakePaymentsHandler
+	DemoCatalog         *demo.CatalogHandler
 	SquareWebhook       *payments.SquareWebhookHandler
 	SquareOAuth         *payments.OAuthHandler
 	AdminMessaging      *handlers.AdminMessagingHandler
+	AdminWebhooks       *handlers.AdminWebhooksHandler
 	AdminClinicData     *handlers.AdminClinicDataHandler
 	TelnyxWebhooks      *handlers.TelnyxWebhookHandler
 	ClinicHandler       *clinic.Handler
@@ -43,6 +49,8 @@ type Config struct {
 	AdminOnboarding     *handlers.AdminOnboardingHandler
 	OnboardingToken     string
 	AdminAuthSecret     string
+	AdminAuthIssuer     string
+	AdminAuthAudience   string
 	MetricsHandler      http.Handler
 	CORSAllowedOrigins  []string
 
@@ -55,6 +63,7 @@ type Config struct {
 	DB              *sql.DB
 	TranscriptStore *conversation.SMSTranscriptStore
 	ClinicStore     *clinic.Store
+	ColdStore       *clinicdata.ColdStore // optional: rehydrates archived conversations
 	KnowledgeRepo   conversation.KnowledgeRepository
 	AuditService    *compliance.AuditService
 
@@ -75,8 +84,13 @@ type Config struct {
 	// Booking callback handler
 	BookingCallbackHandler *conversation.BookingCallbackHandler
 
-	// Short payment URL redirect handler
+	// Short payment URL redirect handler. PayLinkHost, when set, is a branded
+	// pay domain whose root paths (/{code}) are short links.
 	PaymentRedirect *payments.RedirectHandler
+	PayLinkHost     string
+
+	// Square payment reconciliation reports
+	PaymentReconciliation *payments.ReconciliationHandler
 
 	// Morning briefs handler
 	AdminBriefs *handlers.AdminBriefsHandler
@@ -87,12 +101,54 @@ type Config struct {
 	// Research intelligence
 	AdminResearch *handlers.AdminResearchHandler
 
+	// Prompt experiment funnels
+	AdminExperiments *handlers.AdminExperimentsHandler
+
+	// Right-to-delete lead purge
+	AdminRetention *handlers.AdminRetentionHandler
+
+	// Availability probe history
+	AdminAvailabilityHealth *handlers.AdminAvailabilityHealthHandler
+
+	// Per-clinic health checks, e.g. inbound webhook liveness
+	AdminDiagnostics *handlers.AdminDiagnosticsHandler
+
+	// CRM webhook dead letters
+	AdminCRMWebhooks *handlers.AdminCRMWebhooksHandler
+	// CRMEvents publishes booking.cancelled when staff cancel a booking
+	CRMEvents conversation.CRMEventPublisher
+
+	// Per-clinic SMS and LLM spend
+	AdminCosts *handlers.AdminCostsHandler
+
+	// Segment broadcasts
+	AdminBroadcasts *handlers.AdminBroadcastsHandler
+
+	// Live conversation updates (server-sent events)
+	AdminConversationStream *handlers.AdminConversationStreamHandler
+
+	// Conversation jobs a worker never finished
+	AdminJobs *handlers.AdminJobsHandler
+
+	// Assembled system prompt preview
+	AdminPromptPreview *handlers.AdminPromptPreviewHandler
+
+	// Registered outbound SMS templates for compliance review
+	AdminTemplates *handlers.AdminTemplatesHandler
+
+	// Number pool routing (per-number default service / campaign)
+	AdminNumberRoutes *handlers.AdminNumberRoutesHandler
+
 	// Prospect tracker
 	ProspectsHandler *prospects.Handler
 
 	// Story board / Kanban
 	StoriesHandler *stories.Handler
 
+	// Org-scoped partner API keys: admin management and /v1 authentication
+	APIKeysHandler *apikeys.Handler
+	APIKeyAuth     apikeys.Authenticator
+
 	// Voice AI handler (Telnyx AI Assistant webhook)
 	VoiceAIHandler *handlers.VoiceAIHandler
 
@@ -127,7 +183,7 @@ type Config struct {
 
 // New creates a new Chi router with all routes configured. Route registration
 // is delegated to domain-specific helpers in routes_public.go, routes_admin.go,
-// routes_portal.go, and routes_tenant.go.
+// routes_portal.go, routes_tenant.go, and routes_partner.go.
 func New(cfg *Config) http.Handler {
 	r := chi.NewRouter()
 
@@ -135,7 +191,7 @@ func New(cfg *Config) http.Handler {
 	r.Use(middleware.RequestID)
 	r.Use(middleware.RealIP)
 	r.Use(middleware.Logger)
-	r.Use(middleware.Recoverer)
+	r.Use(apierror.Recoverer(cfg.Logger))
 	r.Use(middleware.Compress(5))
 	if cfg.Env == "production" || cfg.Env == "staging" {
 		r.Use(httpmiddleware.HTTPSRedirect)
@@ -148,11 +204,15 @@ func New(cfg *Config) http.Handler {
 	if cfg.Logger != nil {
 		r.Use(httpmiddleware.RequestLogger(cfg.Logger))
 	}
+	if cfg.PaymentRedirect != nil && cfg.PayLinkHost != "" {
+		r.Use(payLinkHost(cfg.PayLinkHost, cfg.PaymentRedirect))
+	}
 
 	registerPublicRoutes(r, cfg)
 	registerAdminRoutes(r, cfg)
 	registerPortalRoutes(r, cfg)
 	registerTenantRoutes(r, cfg)
+	registerPartnerRoutes(r, cfg)
 
 	return r
 }
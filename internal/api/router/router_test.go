@@ -9,11 +9,15 @@ import (
 	"net/url"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/wolfman30/medspa-ai-platform/internal/clinic"
 	"github.com/wolfman30/medspa-ai-platform/internal/conversation"
 	"github.com/wolfman30/medspa-ai-platform/internal/http/handlers"
 	"github.com/wolfman30/medspa-ai-platform/internal/leads"
 	"github.com/wolfman30/medspa-ai-platform/internal/messaging"
+	"github.com/wolfman30/medspa-ai-platform/internal/payments"
+	"github.com/wolfman30/medspa-ai-platform/pkg/adminauth"
 	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
 )
 
@@ -151,6 +155,7 @@ func TestRouterTelnyxVoiceWebhookRegistered(t *testing.T) {
 		"/webhooks/telnyx/voice",
 		"/webhooks/telnyx/messages",
 		"/webhooks/telnyx/hosted",
+		"/webhooks/telnyx/numbers",
 	} {
 		req := httptest.NewRequest(http.MethodPost, route, strings.NewReader("{}"))
 		rr := httptest.NewRecorder()
@@ -190,3 +195,81 @@ func (noopPublisher) EnqueueStart(ctx context.Context, jobID string, req convers
 func (noopPublisher) EnqueueMessage(ctx context.Context, jobID string, req conversation.MessageRequest, opts ...conversation.PublishOption) error {
 	return nil
 }
+
+func TestPayLinkHostServesRootCodes(t *testing.T) {
+	logger := logging.Default()
+	leadRepo := leads.NewInMemoryRepository()
+	r := New(&Config{
+		Logger:           logger,
+		LeadsHandler:     leads.NewHandler(leadRepo, logger),
+		MessagingHandler: messaging.NewHandler("", &noopPublisher{}, messaging.NewStaticOrgResolver(nil), nil, leadRepo, logger),
+		PaymentRedirect:  payments.NewRedirectHandler(nil, nil, logger),
+		PayLinkHost:      "pay.example.com",
+	})
+
+	// On the pay domain a bare code is a short link (unknown here, so 404).
+	req := httptest.NewRequest(http.MethodGet, "http://pay.example.com/x7Yt2Qa", nil)
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	if rec.Code != http.StatusNotFound || rec.Header().Get("Cache-Control") != "no-store" {
+		t.Fatalf("expected the pay redirect handler to answer, got %d", rec.Code)
+	}
+
+	// Other hosts and multi-segment paths route normally.
+	for _, target := range []string{"http://api.example.com/health", "http://pay.example.com/health/extra"} {
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+		if rec.Header().Get("Cache-Control") == "no-store" {
+			t.Fatalf("expected %s not to hit the pay redirect", target)
+		}
+	}
+}
+
+func TestAdminPurgeRoutesAcceptPurgeScope(t *testing.T) {
+	logger := logging.Default()
+	router := New(&Config{
+		Logger:             logger,
+		ClinicStatsHandler: clinic.NewStatsHandler(clinic.NewStatsRepositoryWithDB(zeroStatsDB{}), logger),
+		AdminClinicData:    handlers.NewAdminClinicDataHandler(handlers.AdminClinicDataConfig{Logger: logger}),
+		AdminAuthSecret:    "k2:new-secret,k1:" + testAdminSecret,
+		AdminAuthIssuer:    adminauth.DefaultIssuer,
+		AdminAuthAudience:  adminauth.DefaultAudience,
+	})
+	mint := func(spec string, opts adminauth.MintOptions) string {
+		t.Helper()
+		token, err := adminauth.Mint(spec, opts)
+		if err != nil {
+			t.Fatalf("mint: %v", err)
+		}
+		return token
+	}
+	purgeToken := mint("k2:new-secret", adminauth.MintOptions{Scope: adminauth.ScopePurge, TTL: 5 * time.Minute})
+	fullToken := mint("k1:"+testAdminSecret, adminauth.MintOptions{})
+	otherAudience := mint("k2:new-secret", adminauth.MintOptions{Audience: "medspa-portal"})
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		token  string
+		want   int
+	}{
+		// The handler has no database, so 503 means auth let the request through.
+		{"purge token on purge route", http.MethodDelete, "/admin/clinics/org-a/phones/5550001111", purgeToken, http.StatusServiceUnavailable},
+		{"full token on purge route", http.MethodDelete, "/admin/clinics/org-a/phones/5550001111", fullToken, http.StatusServiceUnavailable},
+		{"purge token elsewhere", http.MethodGet, "/admin/clinics/org-a/stats", purgeToken, http.StatusForbidden},
+		{"full token elsewhere", http.MethodGet, "/admin/clinics/org-a/stats", fullToken, http.StatusOK},
+		{"audience mismatch", http.MethodGet, "/admin/clinics/org-a/stats", otherAudience, http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			req.Header.Set("Authorization", "Bearer "+tt.token)
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+			if rr.Code != tt.want {
+				t.Fatalf("expected %d, got %d: %s", tt.want, rr.Code, rr.Body.String())
+			}
+		})
+	}
+}
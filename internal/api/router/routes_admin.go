@@ -4,22 +4,32 @@ import (
 	"net/http"
 
 	"github.com/go-chi/chi/v5"
+	"github.com/wolfman30/medspa-ai-platform/internal/conversation"
 	"github.com/wolfman30/medspa-ai-platform/internal/http/handlers"
 	httpmiddleware "github.com/wolfman30/medspa-ai-platform/internal/http/middleware"
+	"github.com/wolfman30/medspa-ai-platform/pkg/adminauth"
 )
 
-// adminAuthMiddleware returns the shared JWT auth middleware used by all admin routes.
-func adminAuthMiddleware(cfg *Config) func(http.Handler) http.Handler {
+// adminAuthMiddleware returns the shared JWT auth middleware used by all admin
+// routes. Scoped admin tokens are accepted only for the listed scopes.
+func adminAuthMiddleware(cfg *Config, scopes ...string) func(http.Handler) http.Handler {
 	return httpmiddleware.CognitoOrAdminJWT(
 		httpmiddleware.CognitoConfig{
 			Region:     cfg.CognitoRegion,
 			UserPoolID: cfg.CognitoUserPoolID,
 			ClientID:   cfg.CognitoClientID,
 		},
-		cfg.AdminAuthSecret,
+		adminVerifier(cfg),
+		scopes...,
 	)
 }
 
+// adminVerifier checks legacy HMAC admin tokens against the configured keys,
+// issuer and audience.
+func adminVerifier(cfg *Config) *adminauth.Verifier {
+	return adminauth.NewVerifier(adminauth.ParseKeys(cfg.AdminAuthSecret), cfg.AdminAuthIssuer, cfg.AdminAuthAudience)
+}
+
 // registerAdminRoutes mounts all admin-only endpoints behind JWT authentication.
 // Supports both legacy HMAC and Cognito RS256 tokens.
 func registerAdminRoutes(r chi.Router, cfg *Config) {
@@ -35,31 +45,94 @@ func registerAdminRoutes(r chi.Router, cfg *Config) {
 		r.With(authMW).Get("/api/dashboard/revenue", revenueHandler.GetRevenueDashboard)
 	}
 
-	r.Route("/admin", func(admin chi.Router) {
-		admin.Use(authMW)
-		if cfg.ConversationHandler != nil {
-		}
-		if cfg.AdminMessaging != nil {
-			admin.Post("/hosted/orders", cfg.AdminMessaging.StartHostedOrder)
-			admin.Post("/hosted/activate", cfg.AdminMessaging.ActivateHostedNumber)
-			admin.Post("/hosted/deactivate", cfg.AdminMessaging.DeactivateHostedNumber)
-			admin.Post("/10dlc/brands", cfg.AdminMessaging.CreateBrand)
-			admin.Post("/10dlc/campaigns", cfg.AdminMessaging.CreateCampaign)
-			admin.Post("/messages:send", cfg.AdminMessaging.SendMessage)
-		}
-		// Agent team status
-		admin.Get("/agents/status", handlers.HandleAgentsStatus)
-
-		registerAdminBriefsRoutes(admin, cfg)
-		registerAdminFinanceRoutes(admin, cfg)
-		registerAdminProspectsRoutes(admin, cfg)
-		registerAdminStoriesRoutes(admin, cfg)
-		registerAdminOnboardingRoutes(admin, cfg)
-		registerAdminClinicRoutes(admin, cfg)
-		registerAdminDashboardRoutes(admin, cfg)
+	// EventSource can't send headers, so this stream also takes the admin JWT
+	// as ?access_token=.
+	if cfg.AdminConversationStream != nil {
+		r.With(httpmiddleware.BearerFromQuery("access_token"), authMW).
+			Get("/admin/orgs/{orgID}/conversations/{conversationID}/stream", cfg.AdminConversationStream.Stream)
+	}
+
+	r.Route("/admin", func(root chi.Router) {
+		// Purge endpoints also take short-lived purge-scoped tokens, so a
+		// script that only deletes data doesn't need a full admin token.
+		root.Group(func(purge chi.Router) {
+			purge.Use(adminAuthMiddleware(cfg, adminauth.ScopePurge))
+			registerAdminPurgeRoutes(purge, cfg)
+		})
+		root.Group(func(admin chi.Router) {
+			admin.Use(authMW, scopeRouteOrg)
+			if cfg.ConversationHandler != nil {
+				admin.Post("/orgs/{orgID}/conversations/{conversationID}/refresh-availability", cfg.ConversationHandler.RefreshAvailability)
+				admin.Post("/orgs/{orgID}/conversations/{conversationID}/offer-slot", cfg.ConversationHandler.OfferSlot)
+				admin.Post("/orgs/{orgID}/bookings/assisted", cfg.ConversationHandler.AssistedBooking)
+				admin.Get("/orgs/{orgID}/bookings/assisted/{jobID}", cfg.ConversationHandler.AssistedBookingStatus)
+			}
+			if cfg.AdminMessaging != nil {
+				admin.Post("/hosted/orders", cfg.AdminMessaging.StartHostedOrder)
+				admin.Post("/hosted/activate", cfg.AdminMessaging.ActivateHostedNumber)
+				admin.Post("/hosted/deactivate", cfg.AdminMessaging.DeactivateHostedNumber)
+				admin.Post("/10dlc/brands", cfg.AdminMessaging.CreateBrand)
+				admin.Post("/10dlc/campaigns", cfg.AdminMessaging.CreateCampaign)
+				admin.Post("/messages:send", cfg.AdminMessaging.SendMessage)
+			}
+			if cfg.AdminWebhooks != nil {
+				admin.Get("/webhooks/{id}", cfg.AdminWebhooks.GetEvent)
+				admin.Post("/webhooks/{id}/replay", cfg.AdminWebhooks.Replay)
+			}
+			if cfg.AdminExperiments != nil {
+				admin.Get("/experiments", cfg.AdminExperiments.ListExperiments)
+			}
+			if cfg.AdminDiagnostics != nil {
+				admin.Get("/diagnostics/startup", cfg.AdminDiagnostics.GetStartup)
+			}
+			if cfg.ClinicStatsHandler != nil {
+				admin.Get("/orgs/{orgID}/stats", cfg.ClinicStatsHandler.GetSLAStats)
+			}
+			if cfg.PaymentReconciliation != nil {
+				admin.Get("/orgs/{orgID}/payments/reconciliation", cfg.PaymentReconciliation.GetReport)
+			}
+			if cfg.AdminCosts != nil {
+				admin.Get("/orgs/{orgID}/costs", cfg.AdminCosts.Get)
+			}
+			if cfg.AdminBroadcasts != nil {
+				admin.Post("/orgs/{orgID}/broadcasts", cfg.AdminBroadcasts.Create)
+				admin.Get("/orgs/{orgID}/broadcasts/{broadcastID}", cfg.AdminBroadcasts.Get)
+			}
+			if cfg.LeadsHandler != nil {
+				admin.Post("/orgs/{orgID}/leads/import", cfg.LeadsHandler.ImportLeads)
+			}
+			if cfg.AdminJobs != nil {
+				admin.Get("/jobs/stuck", cfg.AdminJobs.ListStuck)
+			}
+			if cfg.AdminTemplates != nil {
+				admin.Get("/templates", cfg.AdminTemplates.List)
+			}
+			// Agent team status
+			admin.Get("/agents/status", handlers.HandleAgentsStatus)
+
+			registerAdminBriefsRoutes(admin, cfg)
+			registerAdminFinanceRoutes(admin, cfg)
+			registerAdminProspectsRoutes(admin, cfg)
+			registerAdminStoriesRoutes(admin, cfg)
+			registerAdminOnboardingRoutes(admin, cfg)
+			registerAdminClinicRoutes(admin, cfg)
+			registerAdminDashboardRoutes(admin, cfg)
+		})
 	})
 }
 
+// registerAdminPurgeRoutes mounts the per-clinic data purge endpoints, which
+// accept full admin tokens and tokens scoped to adminauth.ScopePurge.
+func registerAdminPurgeRoutes(purge chi.Router, cfg *Config) {
+	if cfg.AdminClinicData != nil {
+		purge.Delete("/clinics/{orgID}/phones/{phone}", cfg.AdminClinicData.PurgePhone)
+		purge.Delete("/clinics/{orgID}/data", cfg.AdminClinicData.PurgeOrg)
+	}
+	if cfg.AdminRetention != nil {
+		purge.Post("/clinics/{orgID}/leads/{leadID}/purge", cfg.AdminRetention.PurgeLead)
+	}
+}
+
 // registerAdminBriefsRoutes mounts the morning briefs CRUD endpoints.
 func registerAdminBriefsRoutes(admin chi.Router, cfg *Config) {
 	if cfg.AdminBriefs == nil {
@@ -135,6 +208,16 @@ func registerAdminClinicRoutes(admin chi.Router, cfg *Config) {
 			clinicRoutes.Get("/config", cfg.ClinicHandler.GetConfig)
 			clinicRoutes.Put("/config", cfg.ClinicHandler.UpdateConfig)
 			clinicRoutes.Post("/config", cfg.ClinicHandler.UpdateConfig)
+			clinicRoutes.Put("/booking-url", cfg.ClinicHandler.UpdateBookingURL)
+			clinicRoutes.Get("/blackouts", cfg.ClinicHandler.ListBlackouts)
+			clinicRoutes.Post("/blackouts", cfg.ClinicHandler.CreateBlackout)
+			clinicRoutes.Delete("/blackouts/{blackoutID}", cfg.ClinicHandler.DeleteBlackout)
+			clinicRoutes.Get("/flags", cfg.ClinicHandler.ListFlags)
+			clinicRoutes.Get("/flags/{flag}", cfg.ClinicHandler.GetFlag)
+			clinicRoutes.Put("/flags/{flag}", cfg.ClinicHandler.SetFlag)
+			clinicRoutes.Delete("/flags/{flag}", cfg.ClinicHandler.ResetFlag)
+			clinicRoutes.Post("/deactivate", cfg.ClinicHandler.Deactivate)
+			clinicRoutes.Post("/reactivate", cfg.ClinicHandler.Reactivate)
 		}
 		if cfg.KnowledgeRepo != nil {
 			knowledgeHandler := handlers.NewPortalKnowledgeHandler(cfg.KnowledgeRepo, cfg.AuditService, cfg.Logger)
@@ -158,10 +241,28 @@ func registerAdminClinicRoutes(admin chi.Router, cfg *Config) {
 		if cfg.ConversationHandler != nil {
 			clinicRoutes.Get("/conversations/{phone}", cfg.ConversationHandler.GetTranscript)
 			clinicRoutes.Get("/sms/{phone}", cfg.ConversationHandler.GetSMSTranscript)
+			clinicRoutes.Get("/callback-tasks", cfg.ConversationHandler.ListCallbackTasks)
+			clinicRoutes.Post("/callback-tasks/{taskID}/done", cfg.ConversationHandler.CompleteCallbackTask)
+		}
+		if cfg.AdminAvailabilityHealth != nil {
+			clinicRoutes.Get("/availability-health", cfg.AdminAvailabilityHealth.Get)
 		}
-		if cfg.AdminClinicData != nil {
-			clinicRoutes.Delete("/phones/{phone}", cfg.AdminClinicData.PurgePhone)
-			clinicRoutes.Delete("/data", cfg.AdminClinicData.PurgeOrg)
+		if cfg.AdminDiagnostics != nil {
+			clinicRoutes.Get("/diagnostics", cfg.AdminDiagnostics.Get)
+		}
+		if cfg.AdminCRMWebhooks != nil {
+			clinicRoutes.Get("/crm-webhooks/dead-letters", cfg.AdminCRMWebhooks.ListDeadLetters)
+			clinicRoutes.Post("/crm-webhooks/dead-letters/{deliveryID}/retry", cfg.AdminCRMWebhooks.RetryDeadLetter)
+		}
+		if cfg.AdminPromptPreview != nil {
+			clinicRoutes.Get("/prompt-preview", cfg.AdminPromptPreview.Get)
+		}
+		if cfg.AdminNumberRoutes != nil {
+			clinicRoutes.Get("/numbers", cfg.AdminNumberRoutes.List)
+			clinicRoutes.Post("/numbers", cfg.AdminNumberRoutes.Purchase)
+			clinicRoutes.Get("/numbers/available", cfg.AdminNumberRoutes.SearchAvailable)
+			clinicRoutes.Put("/numbers/{number}", cfg.AdminNumberRoutes.Put)
+			clinicRoutes.Delete("/numbers/{number}", cfg.AdminNumberRoutes.Delete)
 		}
 		if cfg.SquareOAuth != nil {
 			clinicRoutes.Get("/square/connect", cfg.SquareOAuth.HandleConnect)
@@ -175,6 +276,11 @@ func registerAdminClinicRoutes(admin chi.Router, cfg *Config) {
 			clinicRoutes.Get("/stripe/connect", cfg.StripeConnect.HandleAuthorize)
 			clinicRoutes.Get("/stripe/status", cfg.StripeConnect.HandleStatus)
 		}
+		if cfg.APIKeysHandler != nil {
+			clinicRoutes.Get("/api-keys", cfg.APIKeysHandler.List)
+			clinicRoutes.Post("/api-keys", cfg.APIKeysHandler.Create)
+			clinicRoutes.Delete("/api-keys/{keyID}", cfg.APIKeysHandler.Revoke)
+		}
 	})
 }
 
@@ -184,7 +290,7 @@ func registerAdminDashboardRoutes(admin chi.Router, cfg *Config) {
 	if cfg.DB == nil {
 		return
 	}
-	handlers.RegisterAdminRoutes(admin, cfg.DB, cfg.TranscriptStore, cfg.ClinicStore, cfg.Logger)
+	handlers.RegisterAdminRoutes(admin, cfg.DB, cfg.TranscriptStore, conversation.NewTimeSelectionReader(cfg.RedisClient), cfg.ClinicStore, cfg.ColdStore, cfg.AuditService, cfg.CRMEvents, cfg.Logger)
 
 	testingHandler := handlers.NewAdminTestingHandler(cfg.DB, cfg.Logger, cfg.EvidenceS3Client, cfg.EvidenceS3Bucket, cfg.EvidenceS3Region)
 	admin.Get("/testing", testingHandler.ListTestResults)
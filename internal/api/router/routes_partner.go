@@ -0,0 +1,27 @@
+package router
+
+import (
+	"github.com/go-chi/chi/v5"
+	"github.com/wolfman30/medspa-ai-platform/internal/apikeys"
+	httpmiddleware "github.com/wolfman30/medspa-ai-platform/internal/http/middleware"
+)
+
+// registerPartnerRoutes mounts the /v1/orgs/{orgID} API for clinic partners.
+// Requests authenticate with an org API key instead of the admin JWT, and
+// each endpoint requires a specific key scope.
+func registerPartnerRoutes(r chi.Router, cfg *Config) {
+	if cfg.APIKeyAuth == nil {
+		return
+	}
+	r.Route("/v1/orgs/{orgID}", func(org chi.Router) {
+		org.Use(httpmiddleware.RateLimit(50, 100))
+		org.Use(requireAPIKey(cfg.APIKeyAuth, cfg.Logger))
+
+		if cfg.LeadsHandler != nil {
+			org.With(requireScope(apikeys.ScopeLeadsWrite)).Post("/leads", cfg.LeadsHandler.CreateWebLead)
+		}
+		if cfg.ClinicStatsHandler != nil {
+			org.With(requireScope(apikeys.ScopeStatsRead)).Get("/stats/funnel", cfg.ClinicStatsHandler.GetStats)
+		}
+	})
+}
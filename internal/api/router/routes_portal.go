@@ -2,13 +2,15 @@ package router
 
 import (
 	"github.com/go-chi/chi/v5"
+	"github.com/wolfman30/medspa-ai-platform/internal/conversation"
 	"github.com/wolfman30/medspa-ai-platform/internal/http/handlers"
 	httpmiddleware "github.com/wolfman30/medspa-ai-platform/internal/http/middleware"
 )
 
-// registerPortalRoutes mounts customer portal routes. These are read-only
-// endpoints scoped to the org owner, used by clinic operators to view their
-// own dashboards, conversations, deposits, and knowledge base.
+// registerPortalRoutes mounts customer portal routes. These are mostly
+// read-only endpoints scoped to the org owner, used by clinic operators to
+// view their own dashboards, conversations, deposits, and knowledge base, and
+// to mark deposits applied at checkout.
 func registerPortalRoutes(r chi.Router, cfg *Config) {
 	if cfg.DB == nil || (cfg.AdminAuthSecret == "" && cfg.CognitoUserPoolID == "") {
 		return
@@ -20,10 +22,16 @@ func registerPortalRoutes(r chi.Router, cfg *Config) {
 			UserPoolID: cfg.CognitoUserPoolID,
 			ClientID:   cfg.CognitoClientID,
 		}
-		portal.Use(httpmiddleware.CognitoOrAdminJWT(cognitoCfg, cfg.AdminAuthSecret))
+		portal.Use(httpmiddleware.CognitoOrAdminJWT(cognitoCfg, adminVerifier(cfg)))
 
 		dashboardHandler := handlers.NewPortalDashboardHandler(cfg.DB, cfg.Logger)
 		conversationsHandler := handlers.NewAdminConversationsHandler(cfg.DB, cfg.TranscriptStore, cfg.Logger)
+		if cfg.ClinicStore != nil {
+			conversationsHandler.SetQualificationSources(cfg.ClinicStore, conversation.NewTimeSelectionReader(cfg.RedisClient))
+		}
+		if cfg.ColdStore != nil {
+			conversationsHandler.SetColdStore(cfg.ColdStore)
+		}
 		depositsHandler := handlers.NewAdminDepositsHandler(cfg.DB, cfg.Logger)
 		var knowledgeHandler *handlers.PortalKnowledgeHandler
 		if cfg.KnowledgeRepo != nil {
@@ -31,7 +39,7 @@ func registerPortalRoutes(r chi.Router, cfg *Config) {
 		}
 
 		portal.Route("/orgs/{orgID}", func(r chi.Router) {
-			r.Use(requirePortalOrgOwner(cfg.DB, cfg.Logger))
+			r.Use(requirePortalOrgOwner(cfg.DB, cfg.Logger), scopeRouteOrg)
 			r.Get("/", dashboardHandler.IndexPage)
 			r.Get("/dashboard", dashboardHandler.GetDashboard)
 			r.Get("/conversations", conversationsHandler.ListConversations)
@@ -39,6 +47,7 @@ func registerPortalRoutes(r chi.Router, cfg *Config) {
 			r.Get("/deposits", depositsHandler.ListDeposits)
 			r.Get("/deposits/stats", depositsHandler.GetDepositStats)
 			r.Get("/deposits/{depositID}", depositsHandler.GetDeposit)
+			r.Post("/deposits/{depositID}/apply", depositsHandler.ApplyDeposit)
 			if cfg.SquareOAuth != nil {
 				r.Get("/square/status", cfg.SquareOAuth.HandleStatus)
 				r.Get("/square/connect", cfg.SquareOAuth.HandleConnect)
@@ -1,10 +1,13 @@
 package router
 
 import (
+	"net"
 	"net/http"
+	"strings"
 
 	"github.com/go-chi/chi/v5"
 	httpmiddleware "github.com/wolfman30/medspa-ai-platform/internal/http/middleware"
+	"github.com/wolfman30/medspa-ai-platform/internal/payments"
 )
 
 // registerPublicRoutes mounts all unauthenticated endpoints: webhooks, health
@@ -20,6 +23,9 @@ func registerPublicRoutes(r chi.Router, cfg *Config) {
 		public.Route("/webhooks/twilio", func(r chi.Router) {
 			r.Use(httpmiddleware.RateLimit(100, 200))
 			r.Post("/voice", cfg.MessagingHandler.TwilioVoiceWebhook)
+			r.Post("/voice/gather", cfg.MessagingHandler.TwilioVoiceGather)
+			r.Post("/voice/voicemail", cfg.MessagingHandler.TwilioVoicemailDone)
+			r.Post("/voice/recording", cfg.MessagingHandler.TwilioVoiceRecording)
 		})
 		// Static voice greeting audio files (pre-recorded Lauren ElevenLabs voice)
 		public.Get("/static/greetings/{file}", func(w http.ResponseWriter, r *http.Request) {
@@ -53,10 +59,17 @@ func registerPublicRoutes(r chi.Router, cfg *Config) {
 		if cfg.FakePayments != nil {
 			public.Mount("/demo", cfg.FakePayments.Routes())
 		}
+		if cfg.DemoCatalog != nil {
+			public.Get("/demo/catalog/{orgID}", cfg.DemoCatalog.HandleCatalog)
+		}
 		if cfg.TelnyxWebhooks != nil {
 			public.Post("/webhooks/telnyx/messages", cfg.TelnyxWebhooks.HandleMessages)
 			public.Post("/webhooks/telnyx/hosted", cfg.TelnyxWebhooks.HandleHosted)
+			public.Post("/webhooks/telnyx/numbers", cfg.TelnyxWebhooks.HandleNumbers)
 			public.Post("/webhooks/telnyx/voice", cfg.TelnyxWebhooks.HandleVoice)
+			public.Post("/webhooks/telnyx/voice/gather", cfg.TelnyxWebhooks.HandleVoiceGather)
+			public.Post("/webhooks/telnyx/voice/voicemail", cfg.TelnyxWebhooks.HandleVoicemailDone)
+			public.Post("/webhooks/telnyx/voice/recording", cfg.TelnyxWebhooks.HandleVoiceRecording)
 		}
 		if cfg.VoiceAIHandler != nil {
 			public.Post("/webhooks/telnyx/voice-ai", cfg.VoiceAIHandler.HandleVoiceAI)
@@ -140,3 +153,22 @@ func registerPublicOnboardingRoutes(public chi.Router, cfg *Config) {
 		})
 	})
 }
+
+// payLinkHost serves short payment links at the root of a branded pay
+// domain (pay.example.com/{code}); other requests pass through.
+func payLinkHost(host string, redirect *payments.RedirectHandler) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			reqHost := r.Host
+			if h, _, err := net.SplitHostPort(reqHost); err == nil {
+				reqHost = h
+			}
+			code := strings.TrimPrefix(r.URL.Path, "/")
+			if r.Method == http.MethodGet && strings.EqualFold(reqHost, host) && code != "" && !strings.Contains(code, "/") {
+				redirect.ServeCode(w, r, code)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
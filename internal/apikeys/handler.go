@@ -0,0 +1,115 @@
+package apikeys
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
+)
+
+const internalServerErrorMessage = "internal server error"
+
+type keyStore interface {
+	Create(ctx context.Context, orgID, name string, scopes []string) (*Key, string, error)
+	List(ctx context.Context, orgID string) ([]Key, error)
+	Revoke(ctx context.Context, orgID, id string) error
+}
+
+// Handler serves admin endpoints for issuing and revoking org API keys.
+type Handler struct {
+	store  keyStore
+	logger *logging.Logger
+}
+
+// NewHandler creates an admin API key handler.
+func NewHandler(repo *Repository, logger *logging.Logger) *Handler {
+	if logger == nil {
+		logger = logging.Default()
+	}
+	return &Handler{store: repo, logger: logger}
+}
+
+// CreateKeyRequest is the body for POST /admin/clinics/{orgID}/api-keys.
+type CreateKeyRequest struct {
+	Name   string   `json:"name"`
+	Scopes []string `json:"scopes"`
+}
+
+// CreateKeyResponse returns the plaintext key exactly once.
+type CreateKeyResponse struct {
+	Key    string `json:"key"`
+	APIKey *Key   `json:"api_key"`
+}
+
+// Create issues a new key.
+// POST /admin/clinics/{orgID}/api-keys
+func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
+	orgID := strings.TrimSpace(chi.URLParam(r, "orgID"))
+	if orgID == "" {
+		http.Error(w, "missing orgID", http.StatusBadRequest)
+		return
+	}
+	var req CreateKeyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	if err := ValidateScopes(req.Scopes); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	key, rawKey, err := h.store.Create(r.Context(), orgID, strings.TrimSpace(req.Name), req.Scopes)
+	if err != nil {
+		h.logger.Error("failed to create api key", "org_id", orgID, "error", err)
+		http.Error(w, internalServerErrorMessage, http.StatusInternalServerError)
+		return
+	}
+	h.logger.Info("api key created", "org_id", orgID, "prefix", key.Prefix, "scopes", key.Scopes)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(CreateKeyResponse{Key: rawKey, APIKey: key})
+}
+
+// List returns key metadata for an org. Secrets are never included.
+// GET /admin/clinics/{orgID}/api-keys
+func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
+	orgID := strings.TrimSpace(chi.URLParam(r, "orgID"))
+	keys, err := h.store.List(r.Context(), orgID)
+	if err != nil {
+		h.logger.Error("failed to list api keys", "org_id", orgID, "error", err)
+		http.Error(w, internalServerErrorMessage, http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"api_keys": keys})
+}
+
+// Revoke disables a key immediately.
+// DELETE /admin/clinics/{orgID}/api-keys/{keyID}
+func (h *Handler) Revoke(w http.ResponseWriter, r *http.Request) {
+	orgID := strings.TrimSpace(chi.URLParam(r, "orgID"))
+	keyID := strings.TrimSpace(chi.URLParam(r, "keyID"))
+	if _, err := uuid.Parse(keyID); err != nil {
+		http.Error(w, "api key not found", http.StatusNotFound)
+		return
+	}
+	if err := h.store.Revoke(r.Context(), orgID, keyID); err != nil {
+		if errors.Is(err, ErrNotFound) {
+			http.Error(w, "api key not found", http.StatusNotFound)
+			return
+		}
+		h.logger.Error("failed to revoke api key", "org_id", orgID, "key_id", keyID, "error", err)
+		http.Error(w, internalServerErrorMessage, http.StatusInternalServerError)
+		return
+	}
+	h.logger.Info("api key revoked", "org_id", orgID, "key_id", keyID)
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,194 @@
+package apikeys
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-chi/chi/v5"
+)
+
+func newTestRepo(t *testing.T) (*Repository, sqlmock.Sqlmock) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+	return NewRepository(db), mock
+}
+
+func withChiParams(req *http.Request, kv ...string) *http.Request {
+	rctx := chi.NewRouteContext()
+	for i := 0; i+1 < len(kv); i += 2 {
+		rctx.URLParams.Add(kv[i], kv[i+1])
+	}
+	return req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+}
+
+// captureArg matches any value and records it.
+type captureArg struct{ value *string }
+
+func (c captureArg) Match(v driver.Value) bool {
+	s, ok := v.(string)
+	*c.value = s
+	return ok
+}
+
+func TestHandler_CreateReturnsKeyOnceAndStoresHash(t *testing.T) {
+	repo, mock := newTestRepo(t)
+	h := NewHandler(repo, nil)
+
+	var storedPrefix, storedHash string
+	mock.ExpectQuery("INSERT INTO org_api_keys").
+		WithArgs("org-a", "Agency", captureArg{&storedPrefix}, captureArg{&storedHash}, sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow("11111111-1111-1111-1111-111111111111", time.Now()))
+
+	req := httptest.NewRequest(http.MethodPost, "/admin/clinics/org-a/api-keys",
+		strings.NewReader(`{"name":"Agency","scopes":["leads:write","stats:read"]}`))
+	rr := httptest.NewRecorder()
+	h.Create(rr, withChiParams(req, "orgID", "org-a"))
+
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d body=%s", rr.Code, rr.Body.String())
+	}
+	var resp CreateKeyResponse
+	if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if !strings.HasPrefix(resp.Key, resp.APIKey.Prefix+"_") || resp.APIKey.Prefix != storedPrefix {
+		t.Fatalf("key %q does not start with stored prefix %q", resp.Key, storedPrefix)
+	}
+	if storedHash == "" || storedHash == resp.Key || storedHash != hashKey(resp.Key) {
+		t.Fatalf("expected only the key hash to be stored, got %q", storedHash)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestHandler_CreateRejectsUnknownScope(t *testing.T) {
+	repo, _ := newTestRepo(t)
+	h := NewHandler(repo, nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/admin/clinics/org-a/api-keys", strings.NewReader(`{"scopes":["admin:all"]}`))
+	rr := httptest.NewRecorder()
+	h.Create(rr, withChiParams(req, "orgID", "org-a"))
+
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", rr.Code)
+	}
+}
+
+func TestHandler_ListNeverExposesSecrets(t *testing.T) {
+	repo, mock := newTestRepo(t)
+	h := NewHandler(repo, nil)
+
+	mock.ExpectQuery("SELECT id, org_id, name, prefix, scopes").WithArgs("org-a").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "org_id", "name", "prefix", "scopes", "created_at", "last_used_at", "revoked_at"}).
+			AddRow("k1", "org-a", "Agency", "msk_0123456789ab", "{stats:read}", time.Now(), nil, nil))
+
+	req := httptest.NewRequest(http.MethodGet, "/admin/clinics/org-a/api-keys", nil)
+	rr := httptest.NewRecorder()
+	h.List(rr, withChiParams(req, "orgID", "org-a"))
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rr.Code)
+	}
+	body := rr.Body.String()
+	if !strings.Contains(body, "msk_0123456789ab") {
+		t.Fatalf("expected prefix in listing: %s", body)
+	}
+	if strings.Contains(body, "secret") || strings.Contains(body, `"key"`) {
+		t.Fatalf("listing leaked secret material: %s", body)
+	}
+}
+
+func TestHandler_Revoke(t *testing.T) {
+	const keyID = "11111111-1111-1111-1111-111111111111"
+
+	t.Run("revokes", func(t *testing.T) {
+		repo, mock := newTestRepo(t)
+		h := NewHandler(repo, nil)
+		mock.ExpectExec("UPDATE org_api_keys SET revoked_at").WithArgs(keyID, "org-a").WillReturnResult(sqlmock.NewResult(0, 1))
+
+		rr := httptest.NewRecorder()
+		h.Revoke(rr, withChiParams(httptest.NewRequest(http.MethodDelete, "/", nil), "orgID", "org-a", "keyID", keyID))
+		if rr.Code != http.StatusNoContent {
+			t.Fatalf("expected 204, got %d", rr.Code)
+		}
+	})
+
+	t.Run("other org's key is not found", func(t *testing.T) {
+		repo, mock := newTestRepo(t)
+		h := NewHandler(repo, nil)
+		mock.ExpectExec("UPDATE org_api_keys SET revoked_at").WithArgs(keyID, "org-b").WillReturnResult(sqlmock.NewResult(0, 0))
+
+		rr := httptest.NewRecorder()
+		h.Revoke(rr, withChiParams(httptest.NewRequest(http.MethodDelete, "/", nil), "orgID", "org-b", "keyID", keyID))
+		if rr.Code != http.StatusNotFound {
+			t.Fatalf("expected 404, got %d", rr.Code)
+		}
+	})
+}
+
+func TestRepository_Authenticate(t *testing.T) {
+	rawKey, prefix, err := generateKey()
+	if err != nil {
+		t.Fatalf("generateKey: %v", err)
+	}
+	columns := []string{"id", "org_id", "name", "prefix", "secret_hash", "scopes", "created_at", "last_used_at", "revoked_at"}
+
+	t.Run("valid key", func(t *testing.T) {
+		repo, mock := newTestRepo(t)
+		mock.ExpectQuery("FROM org_api_keys WHERE prefix").WithArgs(prefix).
+			WillReturnRows(sqlmock.NewRows(columns).AddRow("k1", "org-a", "", prefix, hashKey(rawKey), "{leads:write}", time.Now(), nil, nil))
+		mock.ExpectExec("UPDATE org_api_keys SET last_used_at").WithArgs("k1").WillReturnResult(sqlmock.NewResult(0, 1))
+
+		key, err := repo.Authenticate(context.Background(), rawKey)
+		if err != nil {
+			t.Fatalf("authenticate: %v", err)
+		}
+		if key.OrgID != "org-a" || !key.HasScope(ScopeLeadsWrite) || key.HasScope(ScopeStatsRead) {
+			t.Fatalf("unexpected key: %+v", key)
+		}
+	})
+
+	t.Run("revoked key", func(t *testing.T) {
+		repo, mock := newTestRepo(t)
+		revokedAt := time.Now()
+		mock.ExpectQuery("FROM org_api_keys WHERE prefix").WithArgs(prefix).
+			WillReturnRows(sqlmock.NewRows(columns).AddRow("k1", "org-a", "", prefix, hashKey(rawKey), "{leads:write}", time.Now(), nil, revokedAt))
+
+		if _, err := repo.Authenticate(context.Background(), rawKey); !errors.Is(err, ErrRevokedKey) {
+			t.Fatalf("expected ErrRevokedKey, got %v", err)
+		}
+	})
+
+	t.Run("wrong secret", func(t *testing.T) {
+		repo, mock := newTestRepo(t)
+		mock.ExpectQuery("FROM org_api_keys WHERE prefix").WithArgs(prefix).
+			WillReturnRows(sqlmock.NewRows(columns).AddRow("k1", "org-a", "", prefix, hashKey("something else"), "{leads:write}", time.Now(), nil, nil))
+
+		if _, err := repo.Authenticate(context.Background(), rawKey); !errors.Is(err, ErrInvalidKey) {
+			t.Fatalf("expected ErrInvalidKey, got %v", err)
+		}
+	})
+
+	t.Run("malformed key skips lookup", func(t *testing.T) {
+		repo, mock := newTestRepo(t)
+		if _, err := repo.Authenticate(context.Background(), "Bearer-ish"); !errors.Is(err, ErrInvalidKey) {
+			t.Fatalf("expected ErrInvalidKey, got %v", err)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Fatalf("unexpected query: %v", err)
+		}
+	})
+}
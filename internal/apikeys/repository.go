@@ -0,0 +1,110 @@
+package apikeys
+
+import (
+	"context"
+	"crypto/subtle"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/lib/pq"
+)
+
+// Repository persists API keys in Postgres.
+type Repository struct {
+	db *sql.DB
+}
+
+// NewRepository creates a Postgres-backed key repository.
+func NewRepository(db *sql.DB) *Repository {
+	return &Repository{db: db}
+}
+
+var _ Authenticator = (*Repository)(nil)
+
+// Create issues a new key for orgID and returns its metadata together with
+// the plaintext key. The plaintext is not stored and cannot be retrieved later.
+func (r *Repository) Create(ctx context.Context, orgID, name string, scopes []string) (*Key, string, error) {
+	rawKey, prefix, err := generateKey()
+	if err != nil {
+		return nil, "", err
+	}
+	key := &Key{OrgID: orgID, Name: name, Prefix: prefix, Scopes: scopes}
+	err = r.db.QueryRowContext(ctx, `
+		INSERT INTO org_api_keys (org_id, name, prefix, secret_hash, scopes)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at`,
+		orgID, name, prefix, hashKey(rawKey), pq.Array(scopes),
+	).Scan(&key.ID, &key.CreatedAt)
+	if err != nil {
+		return nil, "", fmt.Errorf("creating api key: %w", err)
+	}
+	return key, rawKey, nil
+}
+
+// List returns all keys for orgID, newest first, including revoked ones.
+func (r *Repository) List(ctx context.Context, orgID string) ([]Key, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, org_id, name, prefix, scopes, created_at, last_used_at, revoked_at
+		FROM org_api_keys WHERE org_id = $1
+		ORDER BY created_at DESC`, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("listing api keys: %w", err)
+	}
+	defer rows.Close()
+
+	out := []Key{}
+	for rows.Next() {
+		var k Key
+		if err := rows.Scan(&k.ID, &k.OrgID, &k.Name, &k.Prefix, pq.Array(&k.Scopes),
+			&k.CreatedAt, &k.LastUsedAt, &k.RevokedAt); err != nil {
+			return nil, fmt.Errorf("scanning api key: %w", err)
+		}
+		out = append(out, k)
+	}
+	return out, rows.Err()
+}
+
+// Revoke marks a key as revoked. Revoking an already revoked key is a no-op.
+func (r *Repository) Revoke(ctx context.Context, orgID, id string) error {
+	res, err := r.db.ExecContext(ctx, `
+		UPDATE org_api_keys SET revoked_at = COALESCE(revoked_at, NOW())
+		WHERE id = $1 AND org_id = $2`, id, orgID)
+	if err != nil {
+		return fmt.Errorf("revoking api key %s: %w", id, err)
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// Authenticate looks up a presented key by prefix and verifies its secret.
+func (r *Repository) Authenticate(ctx context.Context, rawKey string) (*Key, error) {
+	prefix, ok := parsePrefix(rawKey)
+	if !ok {
+		return nil, ErrInvalidKey
+	}
+	var k Key
+	var secretHash string
+	err := r.db.QueryRowContext(ctx, `
+		SELECT id, org_id, name, prefix, secret_hash, scopes, created_at, last_used_at, revoked_at
+		FROM org_api_keys WHERE prefix = $1`, prefix,
+	).Scan(&k.ID, &k.OrgID, &k.Name, &k.Prefix, &secretHash, pq.Array(&k.Scopes),
+		&k.CreatedAt, &k.LastUsedAt, &k.RevokedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrInvalidKey
+	}
+	if err != nil {
+		return nil, fmt.Errorf("looking up api key: %w", err)
+	}
+	if subtle.ConstantTimeCompare([]byte(secretHash), []byte(hashKey(rawKey))) != 1 {
+		return nil, ErrInvalidKey
+	}
+	if k.RevokedAt != nil {
+		return nil, ErrRevokedKey
+	}
+	// Best effort: a failed usage stamp should not block the request.
+	_, _ = r.db.ExecContext(ctx, `UPDATE org_api_keys SET last_used_at = NOW() WHERE id = $1`, k.ID)
+	return &k, nil
+}
@@ -0,0 +1,185 @@
+package bootstrap
+
+import (
+	"context"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/availhealth"
+	appconfig "github.com/wolfman30/medspa-ai-platform/internal/config"
+	"github.com/wolfman30/medspa-ai-platform/internal/conversation"
+	"github.com/wolfman30/medspa-ai-platform/internal/emr/aesthetic"
+	boulevard "github.com/wolfman30/medspa-ai-platform/internal/emr/boulevard"
+	moxie "github.com/wolfman30/medspa-ai-platform/internal/emr/moxie"
+	"github.com/wolfman30/medspa-ai-platform/internal/emr/nextech"
+	"github.com/wolfman30/medspa-ai-platform/internal/notify"
+	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
+)
+
+// AvailabilityStack is how the conversation service finds and books slots:
+// the EMR adapter, the Moxie API client, the availability router with its
+// prefetcher, and the Boulevard booking adapter.
+type AvailabilityStack struct {
+	EMR        *conversation.EMRAdapter
+	Moxie      *moxie.Client
+	Router     *conversation.AvailabilityRouter
+	Prefetcher *conversation.AvailabilityPrefetcher
+	Boulevard  *boulevard.BoulevardAdapter
+	Options    []conversation.LLMOption
+}
+
+// BuildAvailabilityStack builds the availability and booking components.
+// The Moxie client and router are always on; the EMR adapter, booking page
+// alerts and booking callbacks depend on config.
+func BuildAvailabilityStack(ctx context.Context, deps ConversationDeps, redisClient *redis.Client) (AvailabilityStack, []Capability) {
+	cfg, logger := deps.Cfg, deps.Logger
+	var stack AvailabilityStack
+	var caps []Capability
+
+	// Configure EMR integration if credentials are provided
+	var emr Capability
+	stack.EMR, emr = buildEMRAdapter(ctx, cfg, logger)
+	if stack.EMR != nil {
+		stack.Options = append(stack.Options, conversation.WithEMR(stack.EMR))
+		logger.Info("EMR integration enabled")
+	}
+	caps = append(caps, emr)
+
+	// Configure direct Moxie GraphQL API client for availability queries
+	stack.Moxie = moxie.NewClient(logger)
+	stack.Options = append(stack.Options, conversation.WithMoxieClient(stack.Moxie))
+	logger.Info("Moxie direct API client enabled for availability queries")
+
+	// Configure Boulevard booking adapter (dry-run by default).
+	// BOULEVARD_DRY_RUN=false opts out; any other value (including unset) keeps dry-run on.
+	blvdDryRun := !strings.EqualFold(os.Getenv("BOULEVARD_DRY_RUN"), "false")
+	stack.Boulevard = boulevard.NewBoulevardAdapter(nil, blvdDryRun, logger)
+	stack.Options = append(stack.Options, conversation.WithBoulevardAdapter(stack.Boulevard))
+	logger.Info("Boulevard booking adapter enabled", "dry_run", stack.Boulevard.IsDryRun())
+	if stack.Boulevard.IsDryRun() {
+		caps = append(caps, Enabled("boulevard_booking", "dry run; set BOULEVARD_DRY_RUN=false to book"))
+	} else {
+		caps = append(caps, Enabled("boulevard_booking", "live"))
+	}
+
+	// Availability router: tries the healthiest source first and falls back
+	// between sources. Prefetches share its per-clinic health and the
+	// Redis-backed cap on concurrent fetches per clinic.
+	stack.Router = conversation.NewAvailabilityRouter(logger, conversation.NewMoxieAPISource(stack.Moxie))
+	stack.Router.SetLimiter(conversation.NewRedisAvailabilityLimiter(redisClient, cfg.AvailabilityFetchConcurrency))
+	// An empty or failing lookup probes the clinic's booking page; a dead
+	// page alerts engineering and the clinic and degrades its availability.
+	stack.Router.SetBookingPageGuard(availhealth.NewBookingPageProber(nil),
+		availhealth.NewWebhookAlerter(notify.NewWebhookNotifier(nil, logger), cfg.AvailabilityHealthAlertWebhook))
+	stack.Options = append(stack.Options, conversation.WithAvailabilityRouter(stack.Router))
+	if strings.TrimSpace(cfg.AvailabilityHealthAlertWebhook) != "" {
+		caps = append(caps, Enabled("booking_page_alerts", "engineering webhook configured"))
+	} else {
+		caps = append(caps, Disabled("booking_page_alerts", "AVAILABILITY_HEALTH_ALERT_WEBHOOK not set; only clinic webhooks are alerted"))
+	}
+
+	// Availability pre-fetcher: starts background API calls as soon as
+	// a service is identified, before qualifications are complete.
+	stack.Prefetcher = conversation.NewAvailabilityPrefetcher(stack.Moxie, redisClient, logger)
+	stack.Prefetcher.SetAvailabilityRouter(stack.Router)
+	// Recently prefetched slots back up the live API when it fails.
+	stack.Router.AddSource(conversation.NewPrefetchCacheSource(stack.Prefetcher))
+	stack.Options = append(stack.Options, conversation.WithAvailabilityPrefetcher(stack.Prefetcher))
+	logger.Info("availability pre-fetcher enabled")
+
+	// Wire in public base URL for callback URL construction
+	if cfg.PublicBaseURL != "" {
+		stack.Options = append(stack.Options, conversation.WithAPIBaseURL(cfg.PublicBaseURL))
+		logger.Info("API base URL wired for booking callbacks", "url", cfg.PublicBaseURL)
+		caps = append(caps, Enabled("booking_callbacks", cfg.PublicBaseURL))
+	} else {
+		caps = append(caps, Disabled("booking_callbacks", "PUBLIC_BASE_URL not set"))
+	}
+	return stack, caps
+}
+
+// buildEMRAdapter creates an EMR adapter based on configured provider
+// credentials and reports which one, or why there is none.
+func buildEMRAdapter(ctx context.Context, cfg *appconfig.Config, logger *logging.Logger) (*conversation.EMRAdapter, Capability) {
+	if cfg.NextechBaseURL == "" || cfg.NextechClientID == "" || cfg.NextechClientSecret == "" {
+		logger.Info("nextech EMR not configured; skipping EMR integration")
+	} else {
+		client, err := nextech.New(nextech.Config{
+			BaseURL:      cfg.NextechBaseURL,
+			ClientID:     cfg.NextechClientID,
+			ClientSecret: cfg.NextechClientSecret,
+			Timeout:      30 * time.Second,
+		})
+		if err != nil {
+			logger.Error("failed to create nextech client", "error", err)
+			return nil, Disabled("emr", "failed to create nextech client: "+err.Error())
+		}
+
+		logger.Info("EMR integration enabled", "provider", "nextech")
+		return conversation.NewEMRAdapter(client, ""), Enabled("emr", "nextech")
+	}
+
+	if strings.TrimSpace(cfg.AestheticRecordClinicID) == "" {
+		logger.Info("aesthetic record shadow scheduler not configured; skipping EMR integration")
+		return nil, Disabled("emr", "neither NEXTECH_* credentials nor AESTHETIC_RECORD_CLINIC_ID set")
+	}
+
+	var upstream aesthetic.AvailabilitySource
+	if strings.TrimSpace(cfg.AestheticRecordSelectBaseURL) != "" {
+		selectClient, err := aesthetic.NewSelectAPIClient(aesthetic.SelectAPIConfig{
+			BaseURL:     cfg.AestheticRecordSelectBaseURL,
+			BearerToken: cfg.AestheticRecordSelectBearerToken,
+		})
+		if err != nil {
+			logger.Error("failed to create aesthetic record select api client", "error", err)
+			return nil, Disabled("emr", "failed to create aesthetic record select api client: "+err.Error())
+		}
+		upstream = selectClient
+	} else {
+		logger.Warn("aesthetic record upstream not configured; shadow schedule requires manual slot seeding until upstream is available")
+	}
+
+	shadowClient, err := aesthetic.New(aesthetic.Config{
+		ClinicID: cfg.AestheticRecordClinicID,
+		Upstream: upstream,
+	})
+	if err != nil {
+		logger.Error("failed to create aesthetic record shadow scheduler client", "error", err)
+		return nil, Disabled("emr", "failed to create aesthetic record shadow scheduler client: "+err.Error())
+	}
+
+	if cfg.AestheticRecordShadowSyncEnabled {
+		if upstream == nil {
+			logger.Warn("aesthetic record shadow sync enabled but upstream is nil; sync will not run")
+		} else {
+			targets := []aesthetic.SyncTarget{{
+				ClinicID:    cfg.AestheticRecordClinicID,
+				ProviderID:  strings.TrimSpace(cfg.AestheticRecordProviderID),
+				ServiceType: "",
+			}}
+			svc, err := aesthetic.NewSyncService(aesthetic.SyncServiceConfig{
+				Client:       shadowClient,
+				Targets:      targets,
+				Interval:     cfg.AestheticRecordSyncInterval,
+				WindowDays:   cfg.AestheticRecordSyncWindowDays,
+				DurationMins: cfg.AestheticRecordSyncDurationMins,
+			})
+			if err != nil {
+				logger.Error("failed to create aesthetic record shadow sync service", "error", err)
+			} else {
+				go svc.Start(ctx)
+				logger.Info("aesthetic record shadow scheduler sync started",
+					"clinic_id", cfg.AestheticRecordClinicID,
+					"provider_id", cfg.AestheticRecordProviderID,
+					"interval", cfg.AestheticRecordSyncInterval.String(),
+				)
+			}
+		}
+	}
+
+	logger.Info("EMR integration enabled", "provider", "aesthetic_record_shadow")
+	return conversation.NewEMRAdapter(shadowClient, cfg.AestheticRecordClinicID), Enabled("emr", "aesthetic_record_shadow")
+}
@@ -0,0 +1,160 @@
+package bootstrap
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime"
+	"github.com/redis/go-redis/v9"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/conversation"
+)
+
+// LLMStack is the model client, knowledge retrieval and model options the
+// conversation service talks to. Client is nil when no model is configured.
+type LLMStack struct {
+	Client  conversation.LLMClient
+	ModelID string
+	RAG     conversation.RAGRetriever
+	Options []conversation.LLMOption
+}
+
+// BuildLLMStack builds the primary LLM client with its optional fallback,
+// RAG, voice model, escalation router and prompt experiments. It fails only
+// when the configured primary provider can't be used.
+func BuildLLMStack(ctx context.Context, deps ConversationDeps, redisClient *redis.Client) (LLMStack, []Capability, error) {
+	cfg, logger := deps.Cfg, deps.Logger
+	if strings.TrimSpace(cfg.BedrockModelID) == "" {
+		logger.Warn("no Bedrock model configured; using stub conversation service")
+		return LLMStack{}, []Capability{Disabled("llm", "BEDROCK_MODEL_ID not set; replies come from the stub conversation service")}, nil
+	}
+
+	awsCfg, err := awsconfig.LoadDefaultConfig(ctx, awsconfig.WithRegion(cfg.AWSRegion))
+	if err != nil {
+		return LLMStack{}, []Capability{Disabled("llm", "failed to load AWS config: "+err.Error())}, fmt.Errorf("bootstrap: load aws config: %w", err)
+	}
+	bedrockClient := bedrockruntime.NewFromConfig(awsCfg)
+
+	var stack LLMStack
+	var caps []Capability
+
+	// Build primary LLM client based on provider configuration
+	var primaryClient conversation.LLMClient
+	switch cfg.LLMProvider {
+	case "gemini":
+		if cfg.GeminiAPIKey == "" {
+			return LLMStack{}, []Capability{Disabled("llm", "LLM_PROVIDER=gemini but GEMINI_API_KEY not set")}, fmt.Errorf("bootstrap: GEMINI_API_KEY required when LLM_PROVIDER=gemini")
+		}
+		geminiClient, err := conversation.NewGeminiLLMClient(ctx, cfg.GeminiAPIKey, cfg.GeminiModelID)
+		if err != nil {
+			return LLMStack{}, []Capability{Disabled("llm", "failed to create gemini client: "+err.Error())}, fmt.Errorf("bootstrap: create gemini client: %w", err)
+		}
+		primaryClient = geminiClient
+		stack.ModelID = cfg.GeminiModelID
+		logger.Info("using Gemini as primary LLM provider", "model", stack.ModelID)
+		caps = append(caps, Enabled("llm", "gemini model "+stack.ModelID))
+	default: // "bedrock" or empty
+		primaryClient = conversation.NewBedrockLLMClient(bedrockClient)
+		stack.ModelID = cfg.BedrockModelID
+		logger.Info("using Bedrock as primary LLM provider", "model", stack.ModelID)
+		caps = append(caps, Enabled("llm", "bedrock model "+stack.ModelID))
+	}
+
+	// Build fallback client if enabled
+	stack.Client = primaryClient
+	fallbackClient, fallback := buildFallbackLLMClient(ctx, deps, bedrockClient)
+	if fallbackClient != nil {
+		stack.Client = conversation.NewFallbackLLMClient(primaryClient, fallbackClient, logger.Logger)
+	}
+	caps = append(caps, fallback)
+
+	knowledgeRepo := conversation.NewRedisKnowledgeRepository(redisClient)
+	if err := ensureDefaultKnowledge(ctx, knowledgeRepo); err != nil {
+		logger.Warn("failed to seed default knowledge", "error", err)
+	}
+	if cfg.BedrockEmbeddingModelID != "" {
+		embedder := conversation.NewBedrockEmbeddingClient(bedrockClient)
+		ragStore := conversation.NewMemoryRAGStore(embedder, cfg.BedrockEmbeddingModelID, logger)
+		if err := hydrateRAGFromRedis(ctx, knowledgeRepo, ragStore, logger); err != nil {
+			logger.Warn("failed to hydrate RAG store", "error", err)
+		}
+		stack.RAG = conversation.NewHydratingRAGRetriever(ctx, knowledgeRepo, ragStore, logger)
+		caps = append(caps, Enabled("rag", "embedding model "+cfg.BedrockEmbeddingModelID))
+	} else {
+		caps = append(caps, Disabled("rag", "BEDROCK_EMBEDDING_MODEL_ID not set"))
+	}
+
+	if cfg.MaxInboundMessageChars > 0 {
+		stack.Options = append(stack.Options, conversation.WithMaxInboundChars(cfg.MaxInboundMessageChars))
+	}
+	if cfg.ContextTokenBudget > 0 {
+		stack.Options = append(stack.Options, conversation.WithContextTokenBudget(cfg.ContextTokenBudget))
+	}
+	stack.Options = append(stack.Options, conversation.WithHistoryHeader(cfg.HistoryWriteHeader))
+
+	if experiments, err := conversation.ParsePromptExperiments(cfg.PromptExperiments); err != nil {
+		logger.Warn("ignoring invalid prompt experiments", "error", err)
+		caps = append(caps, Disabled("prompt_experiments", "invalid PROMPT_EXPERIMENTS: "+err.Error()))
+	} else if len(experiments) > 0 {
+		stack.Options = append(stack.Options, conversation.WithPromptExperiments(conversation.NewExperimentTracker(redisClient, experiments)))
+		logger.Info("prompt experiments enabled", "count", len(experiments))
+		caps = append(caps, Enabled("prompt_experiments", fmt.Sprintf("%d experiments", len(experiments))))
+	} else {
+		caps = append(caps, Disabled("prompt_experiments", "PROMPT_EXPERIMENTS not set"))
+	}
+
+	if cfg.BedrockVoiceModelID != "" {
+		stack.Options = append(stack.Options, conversation.WithVoiceModel(cfg.BedrockVoiceModelID))
+		logger.Info("voice model configured", "voice_model", cfg.BedrockVoiceModelID)
+		caps = append(caps, Enabled("voice_model", cfg.BedrockVoiceModelID))
+	} else {
+		caps = append(caps, Disabled("voice_model", "BEDROCK_VOICE_MODEL_ID not set; voice uses the primary model"))
+	}
+
+	if router := conversation.NewModelRouter(redisClient, cfg.LLMEscalationModelID, cfg.LLMEscalationMonthlyBudget); router != nil {
+		stack.Options = append(stack.Options, conversation.WithModelRouter(router))
+		logger.Info("LLM model escalation enabled", "escalation_model", cfg.LLMEscalationModelID, "monthly_budget", cfg.LLMEscalationMonthlyBudget)
+		caps = append(caps, Enabled("model_escalation", "escalation model "+cfg.LLMEscalationModelID))
+	} else {
+		caps = append(caps, Disabled("model_escalation", "LLM_ESCALATION_MODEL_ID not set"))
+	}
+
+	if usage := BuildLLMUsageRecorder(deps.DBPool); usage != nil {
+		stack.Options = append(stack.Options, conversation.WithLLMUsageRecorder(usage))
+		caps = append(caps, Enabled("llm_cost_tracking", "token usage recorded in Postgres"))
+	} else {
+		caps = append(caps, Disabled("llm_cost_tracking", "no database"))
+	}
+	return stack, caps, nil
+}
+
+// buildFallbackLLMClient returns the client tried when the primary fails,
+// or nil with the reason it is off.
+func buildFallbackLLMClient(ctx context.Context, deps ConversationDeps, bedrockClient *bedrockruntime.Client) (conversation.LLMClient, Capability) {
+	cfg, logger := deps.Cfg, deps.Logger
+	if !cfg.LLMFallbackEnabled {
+		return nil, Disabled("llm_fallback", "LLM_FALLBACK_ENABLED=false")
+	}
+	switch cfg.LLMFallbackProvider {
+	case "gemini":
+		if cfg.GeminiAPIKey == "" {
+			logger.Warn("LLM fallback enabled but GEMINI_API_KEY not set")
+			return nil, Disabled("llm_fallback", "LLM_FALLBACK_PROVIDER=gemini but GEMINI_API_KEY not set")
+		}
+		geminiClient, err := conversation.NewGeminiLLMClient(ctx, cfg.GeminiAPIKey, cfg.GeminiModelID)
+		if err != nil {
+			logger.Warn("failed to create gemini fallback client", "error", err)
+			return nil, Disabled("llm_fallback", "failed to create gemini client: "+err.Error())
+		}
+		logger.Info("Gemini fallback LLM enabled", "model", cfg.GeminiModelID)
+		return geminiClient, Enabled("llm_fallback", "gemini model "+cfg.GeminiModelID)
+	case "bedrock":
+		logger.Info("Bedrock fallback LLM enabled", "model", cfg.BedrockModelID)
+		return conversation.NewBedrockLLMClient(bedrockClient), Enabled("llm_fallback", "bedrock model "+cfg.BedrockModelID)
+	default:
+		logger.Warn("unknown fallback provider", "provider", cfg.LLMFallbackProvider)
+		return nil, Disabled("llm_fallback", "unknown LLM_FALLBACK_PROVIDER "+cfg.LLMFallbackProvider)
+	}
+}
@@ -0,0 +1,64 @@
+package bootstrap
+
+import (
+	"github.com/wolfman30/medspa-ai-platform/internal/compliance"
+	appconfig "github.com/wolfman30/medspa-ai-platform/internal/config"
+	"github.com/wolfman30/medspa-ai-platform/internal/conversation"
+)
+
+// NotificationStack is who hears about conversation events: the compliance
+// audit log and CRM webhooks.
+type NotificationStack struct {
+	Audit     *compliance.AuditService
+	CRMEvents conversation.CRMEventPublisher
+	Options   []conversation.LLMOption
+}
+
+// BuildNotificationStack wires the audit log and CRM webhooks into the
+// conversation service and reports which operator alert channels are
+// configured; the worker builds the notifier itself.
+func BuildNotificationStack(deps ConversationDeps) (NotificationStack, []Capability) {
+	stack := NotificationStack{Audit: deps.Audit, CRMEvents: deps.CRMEvents}
+	var caps []Capability
+
+	if deps.Audit != nil {
+		stack.Options = append(stack.Options, conversation.WithAuditService(deps.Audit))
+		caps = append(caps, Enabled("audit_log", "compliance events recorded in Postgres"))
+	} else {
+		caps = append(caps, Disabled("audit_log", "no database; compliance events are not recorded"))
+	}
+
+	if deps.CRMEvents != nil {
+		stack.Options = append(stack.Options, conversation.WithCRMEvents(deps.CRMEvents))
+		caps = append(caps, Enabled("crm_webhooks", "clinic CRM webhooks"))
+	} else {
+		caps = append(caps, Disabled("crm_webhooks", "needs Postgres and Redis"))
+	}
+
+	caps = append(caps, OperatorEmailCapability(deps.Cfg), OperatorSMSCapability(deps.Cfg))
+	return stack, caps
+}
+
+// OperatorEmailCapability reports which sender clinic operator emails go
+// through, in the order the worker picks them.
+func OperatorEmailCapability(cfg *appconfig.Config) Capability {
+	switch {
+	case cfg.SESFromEmail != "":
+		return Enabled("operator_email", "ses from "+cfg.SESFromEmail)
+	case cfg.SendGridAPIKey != "" && cfg.SendGridFromEmail != "":
+		return Enabled("operator_email", "sendgrid from "+cfg.SendGridFromEmail)
+	}
+	return Disabled("operator_email", "SES_FROM_EMAIL or SENDGRID_API_KEY and SENDGRID_FROM_EMAIL not set")
+}
+
+// OperatorSMSCapability reports the number clinic operator texts are sent
+// from. The worker also needs an outbound messenger to send them.
+func OperatorSMSCapability(cfg *appconfig.Config) Capability {
+	switch {
+	case cfg.TelnyxFromNumber != "":
+		return Enabled("operator_sms", "telnyx from "+cfg.TelnyxFromNumber)
+	case cfg.TwilioFromNumber != "":
+		return Enabled("operator_sms", "twilio from "+cfg.TwilioFromNumber)
+	}
+	return Disabled("operator_sms", "TELNYX_FROM_NUMBER or TWILIO_FROM_NUMBER not set")
+}
@@ -0,0 +1,83 @@
+package bootstrap
+
+import (
+	"strings"
+
+	appconfig "github.com/wolfman30/medspa-ai-platform/internal/config"
+	"github.com/wolfman30/medspa-ai-platform/internal/conversation"
+)
+
+// PaymentsStack is what the conversation service knows about deposits and
+// appointments: the deposit amounts and redirect URLs, payment status, how
+// deposits were applied, and booked appointments.
+type PaymentsStack struct {
+	Checker      conversation.PaymentStatusChecker
+	Applications conversation.DepositApplicationLookup
+	Options      []conversation.LLMOption
+}
+
+// BuildPaymentsStack wires the payment lookups the conversation service
+// reads. Each needs Postgres. It also reports whether a deposit checkout
+// provider is configured; the worker builds the sender itself.
+func BuildPaymentsStack(deps ConversationDeps) (PaymentsStack, []Capability) {
+	cfg, logger := deps.Cfg, deps.Logger
+	stack := PaymentsStack{
+		Options: []conversation.LLMOption{
+			conversation.WithDepositConfig(conversation.DepositConfig{
+				DefaultAmountCents: int32(cfg.DepositAmountCents),
+				SuccessURL:         cfg.SquareSuccessURL,
+				CancelURL:          cfg.SquareCancelURL,
+			}),
+		},
+	}
+	caps := []Capability{DepositCheckoutCapability(cfg, deps.DBPool != nil)}
+
+	// Wire in payment checker for deposit status awareness
+	if deps.Payments != nil {
+		stack.Checker = deps.Payments
+		stack.Options = append(stack.Options, conversation.WithPaymentChecker(stack.Checker))
+		logger.Info("payment checker wired into conversation service")
+		caps = append(caps, Enabled("payment_status", "Postgres payments"))
+	} else {
+		caps = append(caps, Disabled("payment_status", "no database; the assistant can't see deposit status"))
+	}
+
+	if applications := BuildDepositApplicationLookup(deps.DBPool); applications != nil {
+		stack.Applications = applications
+		stack.Options = append(stack.Options, conversation.WithDepositApplications(applications))
+		caps = append(caps, Enabled("deposit_applications", "Postgres deposit applications"))
+	} else {
+		caps = append(caps, Disabled("deposit_applications", "no database; STATUS replies omit the deposit line"))
+	}
+
+	if deps.Bookings.Service != nil {
+		stack.Options = append(stack.Options, conversation.WithAppointmentLookup(deps.Bookings))
+		caps = append(caps, Enabled("appointment_lookup", "Postgres bookings"))
+	} else {
+		caps = append(caps, Disabled("appointment_lookup", "no database; the assistant can't look up booked appointments"))
+	}
+	return stack, caps
+}
+
+// DepositCheckoutCapability reports which provider deposit links are created
+// with, in the order the worker picks them: Square, fake payments, then
+// Stripe.
+func DepositCheckoutCapability(cfg *appconfig.Config, hasDB bool) Capability {
+	if !hasDB {
+		return Disabled("deposit_checkout", "no database; deposits need Postgres")
+	}
+	hasSquare := strings.TrimSpace(cfg.SquareAccessToken) != "" ||
+		(cfg.SquareClientID != "" && cfg.SquareClientSecret != "" && cfg.SquareOAuthRedirectURI != "")
+	hasStripe := cfg.StripeSecretKey != ""
+	switch {
+	case hasSquare && hasStripe:
+		return Enabled("deposit_checkout", "square with stripe multi-checkout")
+	case hasSquare:
+		return Enabled("deposit_checkout", "square")
+	case cfg.AllowFakePayments:
+		return Enabled("deposit_checkout", "fake payments (ALLOW_FAKE_PAYMENTS)")
+	case hasStripe:
+		return Enabled("deposit_checkout", "stripe")
+	}
+	return Disabled("deposit_checkout", "no payment provider: set SQUARE_ACCESS_TOKEN, Square OAuth or STRIPE_SECRET_KEY")
+}
@@ -2,31 +2,249 @@ package bootstrap
 
 import (
 	"context"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/compliance"
 	appconfig "github.com/wolfman30/medspa-ai-platform/internal/config"
 	"github.com/wolfman30/medspa-ai-platform/internal/conversation"
 	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
 )
 
-func TestBuildConversationServiceRequiresConfig(t *testing.T) {
-	if _, err := BuildConversationService(context.Background(), nil, nil, nil, nil, nil); err == nil {
+// capabilityMap indexes caps by name.
+func capabilityMap(caps []Capability) map[string]Capability {
+	out := make(map[string]Capability, len(caps))
+	for _, c := range caps {
+		out[c.Name] = c
+	}
+	return out
+}
+
+func expectCapability(t *testing.T, caps map[string]Capability, name string, enabled bool, reason string) {
+	t.Helper()
+	c, ok := caps[name]
+	if !ok {
+		t.Fatalf("capability %q not reported", name)
+	}
+	if c.Enabled != enabled || !strings.Contains(c.Reason, reason) {
+		t.Fatalf("capability %q = %+v, want enabled=%v with reason containing %q", name, c, enabled, reason)
+	}
+}
+
+func testDeps(cfg *appconfig.Config) ConversationDeps {
+	return NewConversationDeps(cfg, nil, nil, nil, nil, logging.New("error"))
+}
+
+// modelConfig is a config with a Bedrock model and Redis on miniredis.
+func modelConfig(t *testing.T) (*appconfig.Config, *redis.Client) {
+	t.Helper()
+	t.Setenv("AWS_EC2_METADATA_DISABLED", "true")
+	mr := miniredis.RunT(t)
+	cfg := &appconfig.Config{
+		BedrockModelID: "anthropic.claude-test",
+		AWSRegion:      "us-east-1",
+		RedisAddr:      mr.Addr(),
+		LLMProvider:    "bedrock",
+	}
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+	return cfg, client
+}
+
+func TestAssembleConversationRequiresConfig(t *testing.T) {
+	if _, err := AssembleConversation(context.Background(), "api", ConversationDeps{}); err == nil {
 		t.Fatalf("expected error for nil config")
 	}
 }
 
-func TestBuildConversationServiceNoModelReturnsStub(t *testing.T) {
-	cfg := &appconfig.Config{}
+func TestAssembleConversationNoModelReturnsStub(t *testing.T) {
+	asm, err := AssembleConversation(nil, "api", testDeps(&appconfig.Config{}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := asm.Processor.(*conversation.StubService); !ok {
+		t.Fatalf("expected StubService, got %T", asm.Processor)
+	}
+	llm, ok := asm.Report.Lookup("llm")
+	if !ok || llm.Enabled || !strings.Contains(llm.Reason, "BEDROCK_MODEL_ID") || llm.Stack != StackLLM {
+		t.Fatalf("expected the llm reported disabled for BEDROCK_MODEL_ID, got %+v", llm)
+	}
+}
 
-	svc, err := BuildConversationService(nil, cfg, nil, nil, nil, nil)
+func TestBuildLLMStackMissingConfig(t *testing.T) {
+	stack, caps, err := BuildLLMStack(context.Background(), testDeps(&appconfig.Config{}), nil)
+	if err != nil || stack.Client != nil {
+		t.Fatalf("expected no client and no error without a model, got %v, %v", stack.Client, err)
+	}
+	expectCapability(t, capabilityMap(caps), "llm", false, "BEDROCK_MODEL_ID not set")
+
+	cfg, client := modelConfig(t)
+	stack, caps, err = BuildLLMStack(context.Background(), testDeps(cfg), client)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
-	if svc == nil {
-		t.Fatalf("expected service")
+	if stack.Client == nil || stack.ModelID != cfg.BedrockModelID {
+		t.Fatalf("expected the bedrock client, got %+v", stack)
+	}
+	got := capabilityMap(caps)
+	expectCapability(t, got, "llm", true, "bedrock model anthropic.claude-test")
+	expectCapability(t, got, "llm_fallback", false, "LLM_FALLBACK_ENABLED=false")
+	expectCapability(t, got, "rag", false, "BEDROCK_EMBEDDING_MODEL_ID not set")
+	expectCapability(t, got, "prompt_experiments", false, "PROMPT_EXPERIMENTS not set")
+	expectCapability(t, got, "voice_model", false, "BEDROCK_VOICE_MODEL_ID not set")
+	expectCapability(t, got, "model_escalation", false, "LLM_ESCALATION_MODEL_ID not set")
+	expectCapability(t, got, "llm_cost_tracking", false, "no database")
+
+	cfg.LLMFallbackEnabled, cfg.LLMFallbackProvider = true, "gemini"
+	_, caps, _ = BuildLLMStack(context.Background(), testDeps(cfg), client)
+	expectCapability(t, capabilityMap(caps), "llm_fallback", false, "GEMINI_API_KEY not set")
+
+	cfg.LLMProvider = "gemini"
+	_, caps, err = BuildLLMStack(context.Background(), testDeps(cfg), client)
+	if err == nil {
+		t.Fatal("expected an error for gemini without an API key")
+	}
+	expectCapability(t, capabilityMap(caps), "llm", false, "GEMINI_API_KEY not set")
+}
+
+func TestBuildAvailabilityStackMissingConfig(t *testing.T) {
+	cfg, client := modelConfig(t)
+	stack, caps := BuildAvailabilityStack(context.Background(), testDeps(cfg), client)
+	if stack.EMR != nil || stack.Moxie == nil || stack.Router == nil || stack.Prefetcher == nil {
+		t.Fatalf("expected moxie availability without an EMR, got %+v", stack)
+	}
+	got := capabilityMap(caps)
+	expectCapability(t, got, "emr", false, "AESTHETIC_RECORD_CLINIC_ID")
+	expectCapability(t, got, "boulevard_booking", true, "dry run")
+	expectCapability(t, got, "booking_page_alerts", false, "AVAILABILITY_HEALTH_ALERT_WEBHOOK not set")
+	expectCapability(t, got, "booking_callbacks", false, "PUBLIC_BASE_URL not set")
+
+	cfg.PublicBaseURL = "https://api.example.com"
+	cfg.AvailabilityHealthAlertWebhook = "https://hooks.example.com/eng"
+	_, caps = BuildAvailabilityStack(context.Background(), testDeps(cfg), client)
+	got = capabilityMap(caps)
+	expectCapability(t, got, "booking_callbacks", true, "https://api.example.com")
+	expectCapability(t, got, "booking_page_alerts", true, "webhook")
+}
+
+func TestBuildPaymentsStackWithoutDatabase(t *testing.T) {
+	stack, caps := BuildPaymentsStack(testDeps(&appconfig.Config{SquareAccessToken: "sq"}))
+	if stack.Checker != nil || stack.Applications != nil {
+		t.Fatalf("expected no payment lookups without a database, got %+v", stack)
+	}
+	got := capabilityMap(caps)
+	expectCapability(t, got, "deposit_checkout", false, "no database")
+	expectCapability(t, got, "payment_status", false, "no database")
+	expectCapability(t, got, "deposit_applications", false, "no database")
+	expectCapability(t, got, "appointment_lookup", false, "no database")
+}
+
+func TestDepositCheckoutCapability(t *testing.T) {
+	tests := []struct {
+		cfg     appconfig.Config
+		enabled bool
+		reason  string
+	}{
+		{appconfig.Config{}, false, "no payment provider"},
+		{appconfig.Config{SquareAccessToken: "sq"}, true, "square"},
+		{appconfig.Config{SquareClientID: "id", SquareClientSecret: "secret", SquareOAuthRedirectURI: "https://x"}, true, "square"},
+		{appconfig.Config{SquareAccessToken: "sq", StripeSecretKey: "sk"}, true, "square with stripe"},
+		{appconfig.Config{StripeSecretKey: "sk", AllowFakePayments: true}, true, "fake payments"},
+		{appconfig.Config{StripeSecretKey: "sk"}, true, "stripe"},
+	}
+	for _, tt := range tests {
+		got := DepositCheckoutCapability(&tt.cfg, true)
+		if got.Enabled != tt.enabled || !strings.Contains(got.Reason, tt.reason) {
+			t.Errorf("DepositCheckoutCapability(%+v) = %+v, want enabled=%v reason %q", tt.cfg, got, tt.enabled, tt.reason)
+		}
+	}
+}
+
+func TestBuildNotificationStackMissingConfig(t *testing.T) {
+	stack, caps := BuildNotificationStack(testDeps(&appconfig.Config{}))
+	if len(stack.Options) != 0 {
+		t.Fatalf("expected no notification options, got %d", len(stack.Options))
 	}
-	if _, ok := svc.(*conversation.StubService); !ok {
-		t.Fatalf("expected StubService, got %T", svc)
+	got := capabilityMap(caps)
+	expectCapability(t, got, "audit_log", false, "no database")
+	expectCapability(t, got, "crm_webhooks", false, "needs Postgres and Redis")
+	expectCapability(t, got, "operator_email", false, "SES_FROM_EMAIL")
+	expectCapability(t, got, "operator_sms", false, "TELNYX_FROM_NUMBER")
+
+	deps := testDeps(&appconfig.Config{SendGridAPIKey: "key", SendGridFromEmail: "ops@example.com", TwilioFromNumber: "+15550000000"})
+	deps.Audit = compliance.NewAuditService(nil)
+	stack, caps = BuildNotificationStack(deps)
+	if len(stack.Options) != 1 {
+		t.Fatalf("expected the audit option, got %d options", len(stack.Options))
+	}
+	got = capabilityMap(caps)
+	expectCapability(t, got, "audit_log", true, "")
+	expectCapability(t, got, "operator_email", true, "sendgrid from ops@example.com")
+	expectCapability(t, got, "operator_sms", true, "twilio from +15550000000")
+}
+
+func TestAssembleConversationSameForAPIAndWorker(t *testing.T) {
+	cfg, client := modelConfig(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	logger := logging.New("error")
+	api, err := AssembleConversation(ctx, "api", NewConversationDeps(cfg, nil, client, nil, nil, logger))
+	if err != nil {
+		t.Fatalf("api assembly: %v", err)
+	}
+	worker, err := AssembleConversation(ctx, "worker", NewConversationDeps(cfg, nil, client, nil, nil, logger))
+	if err != nil {
+		t.Fatalf("worker assembly: %v", err)
+	}
+	if _, ok := api.Processor.(*conversation.LLMService); !ok {
+		t.Fatalf("expected the LLM service, got %T", api.Processor)
+	}
+	if reflect.TypeOf(api.Processor) != reflect.TypeOf(worker.Processor) {
+		t.Fatalf("processor types differ: %T vs %T", api.Processor, worker.Processor)
+	}
+	apiReport, workerReport := api.Report.Snapshot(), worker.Report.Snapshot()
+	if apiReport.Service != "api" || workerReport.Service != "worker" {
+		t.Fatalf("unexpected services %q and %q", apiReport.Service, workerReport.Service)
+	}
+	if !reflect.DeepEqual(apiReport.Capabilities, workerReport.Capabilities) {
+		t.Fatalf("capabilities differ:\napi    %+v\nworker %+v", apiReport.Capabilities, workerReport.Capabilities)
+	}
+	stacks := map[string]bool{}
+	for _, c := range apiReport.Capabilities {
+		stacks[c.Stack] = true
+	}
+	for _, stack := range []string{StackLLM, StackAvailability, StackPayments, StackNotifications} {
+		if !stacks[stack] {
+			t.Fatalf("no capabilities reported for the %s stack", stack)
+		}
+	}
+}
+
+func TestStartupReportAddReplacesByName(t *testing.T) {
+	report := NewStartupReport("api", time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC))
+	report.Add(StackPayments, DepositCheckoutCapability(&appconfig.Config{SquareAccessToken: "sq"}, true))
+	report.Add(StackPayments, Disabled("deposit_sender", "missing outbox or payments store"))
+	report.Add(StackPayments, Enabled("deposit_sender", "square"))
+
+	snap := report.Snapshot()
+	if len(snap.Capabilities) != 2 {
+		t.Fatalf("expected 2 capabilities, got %+v", snap.Capabilities)
+	}
+	sender, _ := report.Lookup("deposit_sender")
+	if !sender.Enabled || sender.Stack != StackPayments {
+		t.Fatalf("expected the later deposit_sender entry to win, got %+v", sender)
+	}
+
+	var nilReport *StartupReport
+	nilReport.Add(StackLLM, Enabled("llm", "x"))
+	if got := nilReport.Snapshot(); got.Capabilities == nil || len(got.Capabilities) != 0 {
+		t.Fatalf("nil report should snapshot empty, got %+v", got)
 	}
 }
 
@@ -34,8 +252,11 @@ func TestBuildEMRAdapterMissingConfigReturnsNil(t *testing.T) {
 	cfg := &appconfig.Config{}
 	logger := logging.New("error")
 
-	adapter := buildEMRAdapter(context.Background(), cfg, logger)
+	adapter, capability := buildEMRAdapter(context.Background(), cfg, logger)
 	if adapter != nil {
 		t.Fatalf("expected nil adapter when EMR is not configured")
 	}
+	if capability.Enabled || capability.Name != "emr" {
+		t.Fatalf("expected emr reported disabled, got %+v", capability)
+	}
 }
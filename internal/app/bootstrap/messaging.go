@@ -1,6 +1,9 @@
 package bootstrap
 
 import (
+	"encoding/json"
+	"strings"
+
 	auditcompliance "github.com/wolfman30/medspa-ai-platform/internal/compliance"
 	appconfig "github.com/wolfman30/medspa-ai-platform/internal/config"
 	"github.com/wolfman30/medspa-ai-platform/internal/conversation"
@@ -8,6 +11,27 @@ import (
 	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
 )
 
+// ProviderSelection returns the SMS provider settings from cfg, including
+// each org's failover number on the secondary provider.
+func ProviderSelection(cfg *appconfig.Config, logger *logging.Logger) messaging.ProviderSelectionConfig {
+	failoverNumbers := map[string]string{}
+	if raw := strings.TrimSpace(cfg.SMSFailoverNumbersJSON); raw != "" {
+		if err := json.Unmarshal([]byte(raw), &failoverNumbers); err != nil {
+			logger.Warn("failed to parse SMS_FAILOVER_NUMBERS_JSON", "error", err)
+		}
+	}
+	return messaging.ProviderSelectionConfig{
+		Preference:           cfg.SMSProvider,
+		TelnyxAPIKey:         cfg.TelnyxAPIKey,
+		TelnyxProfileID:      cfg.TelnyxMessagingProfileID,
+		TwilioAccountSID:     cfg.TwilioAccountSID,
+		TwilioAuthToken:      cfg.TwilioAuthToken,
+		TwilioFromNumber:     cfg.TwilioFromNumber,
+		FailoverNumbers:      failoverNumbers,
+		TelnyxSegmentRateUSD: cfg.SMSCostPerSegmentUSD,
+	}
+}
+
 // BuildOutboundMessenger creates a reply messenger and applies standard wrappers.
 func BuildOutboundMessenger(
 	cfg *appconfig.Config,
@@ -20,20 +44,26 @@ func BuildOutboundMessenger(
 	if cfg == nil {
 		return nil, "", "missing config"
 	}
-
-	messengerCfg := messaging.ProviderSelectionConfig{
-		Preference:       cfg.SMSProvider,
-		TelnyxAPIKey:     cfg.TelnyxAPIKey,
-		TelnyxProfileID:  cfg.TelnyxMessagingProfileID,
-		TwilioAccountSID: cfg.TwilioAccountSID,
-		TwilioAuthToken:  cfg.TwilioAuthToken,
-		TwilioFromNumber: cfg.TwilioFromNumber,
+	if logger == nil {
+		logger = logging.Default()
 	}
+
+	messengerCfg := ProviderSelection(cfg, logger)
 	messenger, provider, reason := messaging.BuildReplyMessenger(messengerCfg, logger)
 	if messenger == nil {
 		return nil, provider, reason
 	}
 
+	// Gate on the sending number's 10DLC campaign before anything else so a
+	// blocked send never splits into parts or gets persisted as sent.
+	messenger = messaging.WrapWithCampaignGate(messenger, store.Provisioning(), logger)
+	// Split innermost so demo and disclaimer text count toward the segment budget.
+	messenger = messaging.WrapWithSegmentSplitting(messenger, messaging.SegmentSplitConfig{
+		MaxSegments: cfg.SMSMaxSegments,
+		MaxMessages: cfg.SMSMaxMessagesPerReply,
+		Spacing:     cfg.SMSSplitSpacing,
+		Logger:      logger,
+	})
 	messenger = messaging.WrapWithDemoMode(messenger, messaging.DemoModeConfig{
 		Enabled: cfg.DemoMode,
 		Prefix:  cfg.DemoModePrefix,
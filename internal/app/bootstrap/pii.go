@@ -0,0 +1,36 @@
+package bootstrap
+
+import (
+	"fmt"
+
+	appconfig "github.com/wolfman30/medspa-ai-platform/internal/config"
+	"github.com/wolfman30/medspa-ai-platform/internal/pii"
+	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
+)
+
+// BuildPIICipher returns the cipher for encrypting lead and message PII, or
+// nil when PII_ENCRYPTION_KEYS is unset. It also becomes the process-wide
+// default used by handlers that read PII columns directly.
+func BuildPIICipher(cfg *appconfig.Config, logger *logging.Logger) (*pii.Cipher, error) {
+	if cfg == nil {
+		return nil, nil
+	}
+	if logger == nil {
+		logger = logging.Default()
+	}
+	piiCfg, err := pii.ParseConfig(cfg.PIIEncryptionKeys, cfg.PIIEncryptionActiveKey, cfg.PIIHMACKey)
+	if err != nil {
+		return nil, fmt.Errorf("bootstrap: pii encryption config: %w", err)
+	}
+	if piiCfg == nil {
+		logger.Warn("PII_ENCRYPTION_KEYS not set; lead and message PII stored unencrypted")
+		return nil, nil
+	}
+	cipher, err := pii.NewCipher(*piiCfg)
+	if err != nil {
+		return nil, fmt.Errorf("bootstrap: pii encryption config: %w", err)
+	}
+	pii.SetDefault(cipher)
+	logger.Info("pii encryption enabled", "active_key", cipher.ActiveKeyID(), "keys", cipher.KeyIDs())
+	return cipher, nil
+}
@@ -0,0 +1,130 @@
+package bootstrap
+
+import (
+	"sync"
+	"time"
+
+	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
+)
+
+// Stacks group capabilities in the startup report.
+const (
+	StackLLM           = "llm"
+	StackAvailability  = "availability"
+	StackPayments      = "payments"
+	StackNotifications = "notifications"
+	StackWorker        = "worker"
+)
+
+// Capability is an optional feature and whether startup enabled it. Reason
+// says which config turned it on or what is missing.
+type Capability struct {
+	Stack   string `json:"stack"`
+	Name    string `json:"name"`
+	Enabled bool   `json:"enabled"`
+	Reason  string `json:"reason"`
+}
+
+// Enabled reports a capability startup turned on.
+func Enabled(name, reason string) Capability {
+	return Capability{Name: name, Enabled: true, Reason: reason}
+}
+
+// Disabled reports a capability startup left off and why.
+func Disabled(name, reason string) Capability {
+	return Capability{Name: name, Reason: reason}
+}
+
+// StartupReport lists the capabilities a process started with, so a missing
+// deposit sender or notifier shows up in one place instead of as a runtime
+// nil. It is safe for concurrent use.
+type StartupReport struct {
+	mu           sync.RWMutex
+	service      string
+	startedAt    time.Time
+	capabilities []Capability
+}
+
+// StartupReportSnapshot is the JSON form of a StartupReport.
+type StartupReportSnapshot struct {
+	Service      string       `json:"service"`
+	StartedAt    time.Time    `json:"started_at"`
+	Capabilities []Capability `json:"capabilities"`
+}
+
+// NewStartupReport starts an empty report for service ("api" or "worker").
+func NewStartupReport(service string, startedAt time.Time) *StartupReport {
+	return &StartupReport{service: service, startedAt: startedAt.UTC()}
+}
+
+// Add records caps under stack. A capability already reported under the same
+// name is replaced, so a worker can record the outcome of a component it
+// built after the assembler reported its config.
+func (r *StartupReport) Add(stack string, caps ...Capability) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, c := range caps {
+		c.Stack = stack
+		replaced := false
+		for i := range r.capabilities {
+			if r.capabilities[i].Name == c.Name {
+				r.capabilities[i], replaced = c, true
+				break
+			}
+		}
+		if !replaced {
+			r.capabilities = append(r.capabilities, c)
+		}
+	}
+}
+
+// Lookup returns the named capability.
+func (r *StartupReport) Lookup(name string) (Capability, bool) {
+	if r == nil {
+		return Capability{}, false
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, c := range r.capabilities {
+		if c.Name == name {
+			return c, true
+		}
+	}
+	return Capability{}, false
+}
+
+// Snapshot copies the report for encoding.
+func (r *StartupReport) Snapshot() StartupReportSnapshot {
+	if r == nil {
+		return StartupReportSnapshot{Capabilities: []Capability{}}
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return StartupReportSnapshot{
+		Service:      r.service,
+		StartedAt:    r.startedAt,
+		Capabilities: append([]Capability{}, r.capabilities...),
+	}
+}
+
+// Log writes one line per capability: enabled ones at info, disabled ones at
+// warn so they stand out.
+func (r *StartupReport) Log(logger *logging.Logger) {
+	if logger == nil {
+		logger = logging.Default()
+	}
+	snap := r.Snapshot()
+	disabled := 0
+	for _, c := range snap.Capabilities {
+		if c.Enabled {
+			logger.Info("startup capability enabled", "stack", c.Stack, "capability", c.Name, "reason", c.Reason)
+			continue
+		}
+		disabled++
+		logger.Warn("startup capability disabled", "stack", c.Stack, "capability", c.Name, "reason", c.Reason)
+	}
+	logger.Info("startup report", "service", snap.Service, "capabilities", len(snap.Capabilities), "disabled", disabled)
+}
@@ -0,0 +1,67 @@
+package bootstrap
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// startupReportsKey hashes service name to its latest published
+// StartupReportSnapshot JSON.
+const startupReportsKey = "startup_reports"
+
+// StartupReportStore shares startup reports between processes through
+// Redis, so the API can serve the report of a worker deployed on its own.
+// Replicas of one service overwrite each other; they share a config.
+type StartupReportStore struct {
+	redis *redis.Client
+}
+
+// NewStartupReportStore creates a report store. Returns nil without a Redis
+// client; a nil store publishes and lists nothing.
+func NewStartupReportStore(client *redis.Client) *StartupReportStore {
+	if client == nil {
+		return nil
+	}
+	return &StartupReportStore{redis: client}
+}
+
+// Publish stores report as its service's latest.
+func (s *StartupReportStore) Publish(ctx context.Context, report *StartupReport) error {
+	if s == nil || report == nil {
+		return nil
+	}
+	snap := report.Snapshot()
+	body, err := json.Marshal(snap)
+	if err != nil {
+		return fmt.Errorf("bootstrap: encode startup report: %w", err)
+	}
+	if err := s.redis.HSet(ctx, startupReportsKey, snap.Service, body).Err(); err != nil {
+		return fmt.Errorf("bootstrap: publish startup report: %w", err)
+	}
+	return nil
+}
+
+// List returns every published report, ordered by service.
+func (s *StartupReportStore) List(ctx context.Context) ([]StartupReportSnapshot, error) {
+	if s == nil {
+		return nil, nil
+	}
+	raw, err := s.redis.HGetAll(ctx, startupReportsKey).Result()
+	if err != nil {
+		return nil, fmt.Errorf("bootstrap: load startup reports: %w", err)
+	}
+	out := make([]StartupReportSnapshot, 0, len(raw))
+	for _, v := range raw {
+		var snap StartupReportSnapshot
+		if err := json.Unmarshal([]byte(v), &snap); err != nil {
+			continue
+		}
+		out = append(out, snap)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Service < out[j].Service })
+	return out, nil
+}
@@ -6,11 +6,17 @@ import (
 	"database/sql"
 	"strings"
 
+	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/redis/go-redis/v9"
 
 	"github.com/wolfman30/medspa-ai-platform/internal/clinic"
 	appconfig "github.com/wolfman30/medspa-ai-platform/internal/config"
 	"github.com/wolfman30/medspa-ai-platform/internal/conversation"
+	"github.com/wolfman30/medspa-ai-platform/internal/costs"
+	"github.com/wolfman30/medspa-ai-platform/internal/crmhooks"
+	"github.com/wolfman30/medspa-ai-platform/internal/events"
+	"github.com/wolfman30/medspa-ai-platform/internal/leads"
+	"github.com/wolfman30/medspa-ai-platform/internal/payments"
 	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
 )
 
@@ -75,11 +81,99 @@ func BuildClinicStore(redisClient *redis.Client) *clinic.Store {
 	return clinic.NewStore(redisClient)
 }
 
+// BuildCRMEventPublisher returns the CRM webhook publisher, or nil when
+// Postgres or Redis is unavailable.
+func BuildCRMEventPublisher(dbPool *pgxpool.Pool, redisClient *redis.Client, logger *logging.Logger) conversation.CRMEventPublisher {
+	if dbPool == nil || redisClient == nil {
+		return nil
+	}
+	return crmhooks.NewPublisher(crmhooks.NewStore(dbPool), clinic.NewStore(redisClient), logger)
+}
+
+// BuildLLMUsageRecorder returns the store that records per-conversation LLM
+// token usage for cost reporting, or nil when Postgres is unavailable.
+func BuildLLMUsageRecorder(dbPool *pgxpool.Pool) conversation.LLMUsageRecorder {
+	if dbPool == nil {
+		return nil
+	}
+	return costs.NewStore(dbPool)
+}
+
+// BuildDepositApplicationLookup returns the lookup behind the deposit line in
+// the STATUS reply, or nil without Postgres.
+func BuildDepositApplicationLookup(dbPool *pgxpool.Pool) conversation.DepositApplicationLookup {
+	if dbPool == nil {
+		return nil
+	}
+	return payments.NewDepositApplicationStore(dbPool)
+}
+
+// StartBookingOutcomePoller runs the booking outcome poller in the background.
+// It does nothing without Postgres or a clinic store. Booking outcomes run
+// when BookingOutcomePollInterval is set; the top-leads digest runs when
+// TopLeadsDigestEnabled is, leadsRepo stores scores and notifier is set,
+// on its own when outcome polling is off. notifier may be nil to skip the
+// daily digest.
+func StartBookingOutcomePoller(ctx context.Context, cfg *appconfig.Config, dbPool *pgxpool.Pool, clinicStore *clinic.Store, leadsRepo leads.Repository, notifier payments.DailyDigestNotifier, logger *logging.Logger) {
+	if dbPool == nil || clinicStore == nil {
+		return
+	}
+	scores, ok := leadsRepo.(leads.ScoreRepository)
+	topLeads := ok && notifier != nil && cfg.TopLeadsDigestEnabled && cfg.DigestTopLeads > 0
+	if cfg.BookingOutcomePollInterval <= 0 && !topLeads {
+		return
+	}
+	poller := payments.NewBookingOutcomePoller(payments.NewDepositApplicationStore(dbPool), clinicStore, notifier, events.NewProcessedStore(dbPool), logger)
+	if cfg.BookingOutcomePollInterval > 0 {
+		poller.WithInterval(cfg.BookingOutcomePollInterval)
+	} else {
+		poller.WithLeadsDigestOnly()
+	}
+	if topLeads {
+		poller.WithTopLeads(scores, cfg.DigestTopLeads)
+	}
+	go poller.Start(ctx)
+}
+
+// BuildPaymentClaimChecker returns the checker that confirms a deposit with
+// Square when the patient says they paid, or nil without Postgres or Square.
+// Confirmed payments go through the Square webhook handler so they're
+// recorded once however they're first seen.
+func BuildPaymentClaimChecker(dbPool *pgxpool.Pool, paymentRepo *payments.Repository, leadsRepo leads.Repository, square *payments.SquareCheckoutService, numbers payments.OrgNumberResolver, logger *logging.Logger) conversation.PaymentClaimChecker {
+	if dbPool == nil || paymentRepo == nil || leadsRepo == nil || square == nil {
+		return nil
+	}
+	webhooks := payments.NewSquareWebhookHandler("", paymentRepo, leadsRepo, events.NewProcessedStore(dbPool), events.NewOutboxStore(dbPool), numbers, nil, logger)
+	return payments.NewClaimedPaymentChecker(paymentRepo, square, webhooks, logger)
+}
+
 // BuildSMSTranscriptStore returns the Redis-backed SMS transcript store.
 func BuildSMSTranscriptStore(redisClient *redis.Client) *conversation.SMSTranscriptStore {
 	return conversation.NewSMSTranscriptStore(redisClient)
 }
 
+// BuildFrustrationTracker returns the conversation frustration tracker, or
+// nil when Redis is unavailable or FRUSTRATION_THRESHOLD is 0. With
+// FRUSTRATION_LLM_CLASSIFIER set, processors that expose a classifier also
+// rate each text.
+func BuildFrustrationTracker(cfg *appconfig.Config, redisClient *redis.Client, processor conversation.Service, logger *logging.Logger) *conversation.FrustrationTracker {
+	if cfg == nil {
+		return nil
+	}
+	tracker := conversation.NewFrustrationTracker(redisClient, cfg.FrustrationThreshold)
+	if tracker == nil || !cfg.FrustrationLLMClassifier {
+		return tracker
+	}
+	if source, ok := processor.(interface {
+		FrustrationClassifier() conversation.FrustrationClassifier
+	}); ok {
+		tracker.WithClassifier(source.FrustrationClassifier())
+	} else if logger != nil {
+		logger.Warn("frustration LLM classifier requested but the conversation service has none")
+	}
+	return tracker
+}
+
 func parseConversationExclusions(raw string) []string {
 	raw = strings.TrimSpace(raw)
 	if raw == "" {
@@ -0,0 +1,105 @@
+package availhealth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/clinic"
+	"github.com/wolfman30/medspa-ai-platform/internal/notify"
+)
+
+// Alert describes a probe that looks like booking platform breakage.
+type Alert struct {
+	OrgID      string
+	ClinicName string
+	Kind       string
+	Probe      ProbeResult
+	Assessment Assessment
+}
+
+// Alerter delivers availability alerts.
+type Alerter interface {
+	AvailabilityAlert(ctx context.Context, cfg *clinic.Config, alert Alert) error
+}
+
+// WebhookAlerter posts alerts to engineering's chat webhook and, for clinics
+// that opted in, to the clinic's own chat webhooks.
+type WebhookAlerter struct {
+	notifier    *notify.WebhookNotifier
+	engineering []clinic.ChatWebhook
+}
+
+// NewWebhookAlerter creates an alerter posting to engineeringURL. Returns
+// nil when there is nowhere to post.
+func NewWebhookAlerter(notifier *notify.WebhookNotifier, engineeringURL string) *WebhookAlerter {
+	if notifier == nil {
+		return nil
+	}
+	a := &WebhookAlerter{notifier: notifier}
+	if u := strings.TrimSpace(engineeringURL); u != "" {
+		a.engineering = []clinic.ChatWebhook{{Name: "engineering", URL: u}}
+	}
+	return a
+}
+
+// AvailabilityAlert implements Alerter.
+func (a *WebhookAlerter) AvailabilityAlert(ctx context.Context, cfg *clinic.Config, alert Alert) error {
+	return a.publish(ctx, cfg, notify.WebhookEvent{
+		Type:       notify.EventAvailabilityAlert,
+		ClinicName: alert.ClinicName,
+		Details:    alertDetails(alert),
+	}, cfg.NotifiesClinicOfAvailabilityAlerts())
+}
+
+// BookingPageDown alerts that cfg's booking page looks dead. Only the clinic
+// can fix its booking URL, so the clinic is told whether or not it opted in
+// to probe alerts.
+func (a *WebhookAlerter) BookingPageDown(ctx context.Context, cfg *clinic.Config, cause error) error {
+	if cfg == nil {
+		return nil
+	}
+	return a.publish(ctx, cfg, notify.WebhookEvent{
+		Type:       notify.EventAvailabilityAlert,
+		ClinicName: cfg.Name,
+		Details: []string{
+			"Booking page looks dead: " + BookingPageURL(cfg),
+			"Problem: " + cause.Error(),
+			"Patients are asked for their preferred times and told the team will text them options until the booking URL is fixed.",
+			"Org: " + cfg.OrgID,
+		},
+	}, true)
+}
+
+func (a *WebhookAlerter) publish(ctx context.Context, cfg *clinic.Config, evt notify.WebhookEvent, toClinic bool) error {
+	var errs []error
+	if err := a.notifier.Publish(ctx, a.engineering, evt); err != nil {
+		errs = append(errs, err)
+	}
+	if toClinic && cfg != nil {
+		if err := a.notifier.Publish(ctx, cfg.Notifications.ChatWebhooks, evt); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		return fmt.Errorf("availhealth: send alert: %w", err)
+	}
+	return nil
+}
+
+// alertDetails renders the detail lines shown under the alert header.
+func alertDetails(alert Alert) []string {
+	p, b := alert.Probe, alert.Assessment.Baseline
+	details := []string{fmt.Sprintf("Service: %s (%s)", p.Service, p.Platform)}
+	switch alert.Kind {
+	case AlertZeroSlots:
+		details = append(details, fmt.Sprintf("No open slots in the next %d days (baseline average: %.1f)", probeDays, b.AvgSlots))
+	case AlertErrorSpike:
+		details = append(details,
+			fmt.Sprintf("Recent error rate: %.0f%% (baseline: %.0f%%)", alert.Assessment.RecentErrorRate*100, b.ErrorRate*100),
+			"Last error: "+p.Error,
+		)
+	}
+	return append(details, "Org: "+alert.OrgID)
+}
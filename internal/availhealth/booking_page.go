@@ -0,0 +1,121 @@
+package availhealth
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/clinic"
+)
+
+const (
+	// moxieBookingPagePrefix is where Moxie hosts each clinic's booking page.
+	moxieBookingPagePrefix = "https://app.joinmoxie.com/booking/"
+	// bookingPageReadLimit caps how much of a page is scanned for markers.
+	bookingPageReadLimit = 1 << 20
+	// bookingPageTimeout bounds a single page probe.
+	bookingPageTimeout = 10 * time.Second
+)
+
+// ErrBookingPageDead means the booking page answered but is not a live
+// booking page, e.g. a 404 after the clinic changed its Moxie slug.
+var ErrBookingPageDead = errors.New("availhealth: booking page looks dead")
+
+// Moxie booking pages are Next.js apps: a live page embeds __NEXT_DATA__,
+// and an unknown slug renders the 404 or error page with a 200 status.
+var (
+	moxiePageMarker     = []byte("__NEXT_DATA__")
+	moxieNotFoundMarker = [][]byte{[]byte(`"page":"/404"`), []byte(`"page":"/_error"`)}
+)
+
+// BookingPageProber checks that a clinic's public booking page still loads.
+// It is a single GET, cheap enough to run whenever availability comes back
+// suspiciously empty.
+type BookingPageProber struct {
+	client *http.Client
+}
+
+// NewBookingPageProber creates a prober. A nil client uses one with a short
+// timeout.
+func NewBookingPageProber(client *http.Client) *BookingPageProber {
+	if client == nil {
+		client = &http.Client{Timeout: bookingPageTimeout}
+	}
+	return &BookingPageProber{client: client}
+}
+
+// BookingPageURL returns the page to probe for a clinic: its booking URL, or
+// its Moxie page when only the slug is configured.
+func BookingPageURL(cfg *clinic.Config) string {
+	if cfg == nil {
+		return ""
+	}
+	if u := strings.TrimSpace(cfg.BookingURL); u != "" && u != clinic.DefaultBookingURL {
+		return u
+	}
+	if cfg.MoxieConfig != nil && strings.TrimSpace(cfg.MoxieConfig.MedspaSlug) != "" {
+		return moxieBookingPagePrefix + strings.TrimSpace(cfg.MoxieConfig.MedspaSlug)
+	}
+	return ""
+}
+
+// ProbeBookingPage reports ErrBookingPageDead when the clinic's booking page
+// looks dead. A page that can't be reached at all (timeout, 5xx) says more
+// about the network than the URL, so it isn't reported.
+func (p *BookingPageProber) ProbeBookingPage(ctx context.Context, cfg *clinic.Config) error {
+	pageURL := BookingPageURL(cfg)
+	if pageURL == "" {
+		return nil
+	}
+	if err := p.ValidateBookingURL(ctx, pageURL, cfg.BookingPlatform); errors.Is(err, ErrBookingPageDead) {
+		return err
+	}
+	return nil
+}
+
+// ValidateBookingURL fetches rawURL and checks that it serves a booking page.
+// Moxie pages must also carry the booking app's markers.
+func (p *BookingPageProber) ValidateBookingURL(ctx context.Context, rawURL, platform string) error {
+	u, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil || u.Host == "" || (u.Scheme != "https" && u.Scheme != "http") {
+		return fmt.Errorf("%w: %q is not an http(s) URL", ErrBookingPageDead, rawURL)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return fmt.Errorf("availhealth: build booking page request: %w", err)
+	}
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("availhealth: fetch booking page: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode >= 500:
+		return fmt.Errorf("availhealth: booking page returned %d", resp.StatusCode)
+	case resp.StatusCode >= 400:
+		return fmt.Errorf("%w: %s returned %d", ErrBookingPageDead, u.String(), resp.StatusCode)
+	}
+	if !strings.EqualFold(platform, "moxie") && !strings.HasSuffix(u.Hostname(), "joinmoxie.com") {
+		return nil
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, bookingPageReadLimit))
+	if err != nil {
+		return fmt.Errorf("availhealth: read booking page: %w", err)
+	}
+	if !bytes.Contains(body, moxiePageMarker) {
+		return fmt.Errorf("%w: %s is not a Moxie booking page", ErrBookingPageDead, u.String())
+	}
+	for _, marker := range moxieNotFoundMarker {
+		if bytes.Contains(body, marker) {
+			return fmt.Errorf("%w: %s renders Moxie's not-found page", ErrBookingPageDead, u.String())
+		}
+	}
+	return nil
+}
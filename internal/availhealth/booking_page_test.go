@@ -0,0 +1,64 @@
+package availhealth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/clinic"
+)
+
+func TestBookingPageProber(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/booking/glow":
+			_, _ = w.Write([]byte(`<script id="__NEXT_DATA__">{"page":"/booking/[slug]"}</script>`))
+		case "/booking/renamed":
+			_, _ = w.Write([]byte(`<script id="__NEXT_DATA__">{"page":"/404"}</script>`))
+		case "/booking/gone":
+			http.NotFound(w, r)
+		case "/booking/parked":
+			_, _ = w.Write([]byte(`<html>This domain is for sale</html>`))
+		default:
+			w.WriteHeader(http.StatusBadGateway)
+		}
+	}))
+	defer srv.Close()
+	prober := NewBookingPageProber(srv.Client())
+
+	tests := []struct {
+		path string
+		dead bool
+	}{
+		{"/booking/glow", false},
+		{"/booking/renamed", true},
+		{"/booking/gone", true},
+		{"/booking/parked", true},
+		{"/booking/outage", false}, // a 5xx isn't the URL's fault
+	}
+	for _, tc := range tests {
+		t.Run(tc.path, func(t *testing.T) {
+			cfg := &clinic.Config{OrgID: "org-1", BookingPlatform: "moxie", BookingURL: srv.URL + tc.path}
+			err := prober.ProbeBookingPage(context.Background(), cfg)
+			if got := errors.Is(err, ErrBookingPageDead); got != tc.dead {
+				t.Fatalf("dead = %v (err %v), want %v", got, err, tc.dead)
+			}
+		})
+	}
+
+	// Validation before saving a URL rejects outages too.
+	if err := prober.ValidateBookingURL(context.Background(), srv.URL+"/booking/outage", "moxie"); err == nil {
+		t.Fatalf("expected an unreachable page to fail validation")
+	}
+}
+
+func TestBookingPageURL(t *testing.T) {
+	if got := BookingPageURL(&clinic.Config{MoxieConfig: &clinic.MoxieConfig{MedspaSlug: "glow"}}); got != "https://app.joinmoxie.com/booking/glow" {
+		t.Fatalf("slug URL = %q", got)
+	}
+	if got := BookingPageURL(&clinic.Config{BookingURL: clinic.DefaultBookingURL}); got != "" {
+		t.Fatalf("expected the demo booking page skipped, got %q", got)
+	}
+}
@@ -0,0 +1,216 @@
+// Package availhealth probes each clinic's booking platform on a schedule so
+// availability breakage (e.g. a Moxie schema change) is caught by engineering
+// before patients stop being offered slots.
+//
+// Probes call the booking platform directly and are stored only in the
+// availability_probes table. They never create leads, conversations or
+// availability cache entries, so they don't show up in funnel metrics.
+package availhealth
+
+import (
+	"sort"
+	"time"
+)
+
+// Alert kinds recorded on the probe that raised them.
+const (
+	AlertZeroSlots  = "zero_slots"
+	AlertErrorSpike = "error_spike"
+)
+
+// Service statuses reported by Summarize.
+const (
+	StatusOK        = "ok"
+	StatusZeroSlots = "zero_slots"
+	StatusFailing   = "failing"
+)
+
+// ProbeResult is one availability probe of a clinic service.
+type ProbeResult struct {
+	ID        int64     `json:"id,omitempty"`
+	OrgID     string    `json:"org_id"`
+	Service   string    `json:"service"`
+	Platform  string    `json:"platform"`
+	Success   bool      `json:"success"`
+	LatencyMS int64     `json:"latency_ms"`
+	SlotCount int       `json:"slot_count"`
+	Error     string    `json:"error,omitempty"`
+	Alert     string    `json:"alert,omitempty"` // alert kind sent for this probe
+	ProbedAt  time.Time `json:"probed_at"`
+}
+
+// Baseline summarizes a service's probes over a trailing window.
+type Baseline struct {
+	Samples      int     `json:"samples"`
+	Successes    int     `json:"successes"`
+	AvgSlots     float64 `json:"avg_slots"` // over successful probes
+	AvgLatencyMS float64 `json:"avg_latency_ms"`
+	ErrorRate    float64 `json:"error_rate"`
+}
+
+// Thresholds control when a probe raises an alert.
+type Thresholds struct {
+	// BaselineWindow is the trailing window probes are compared against.
+	BaselineWindow time.Duration
+	// RecentWindow is the window, ending now, whose error rate is checked
+	// for a spike. It is excluded from the baseline.
+	RecentWindow time.Duration
+	// ErrorRate is the recent error rate that counts as a spike.
+	ErrorRate float64
+	// ErrorRateIncrease is how far above the baseline error rate it must be.
+	ErrorRateIncrease float64
+	// MinRecentSamples is how many recent probes are needed to judge a spike.
+	MinRecentSamples int
+	// AlertCooldown suppresses repeat alerts of the same kind for a service.
+	AlertCooldown time.Duration
+}
+
+// DefaultThresholds compares against the trailing week and flags a spike
+// when at least half of the last day's probes failed.
+func DefaultThresholds() Thresholds {
+	return Thresholds{
+		BaselineWindow:    7 * 24 * time.Hour,
+		RecentWindow:      24 * time.Hour,
+		ErrorRate:         0.5,
+		ErrorRateIncrease: 0.25,
+		MinRecentSamples:  3,
+		AlertCooldown:     24 * time.Hour,
+	}
+}
+
+func (t Thresholds) withDefaults() Thresholds {
+	d := DefaultThresholds()
+	if t.BaselineWindow <= 0 {
+		t.BaselineWindow = d.BaselineWindow
+	}
+	if t.RecentWindow <= 0 {
+		t.RecentWindow = d.RecentWindow
+	}
+	if t.ErrorRate <= 0 {
+		t.ErrorRate = d.ErrorRate
+	}
+	if t.ErrorRateIncrease <= 0 {
+		t.ErrorRateIncrease = d.ErrorRateIncrease
+	}
+	if t.MinRecentSamples <= 0 {
+		t.MinRecentSamples = d.MinRecentSamples
+	}
+	if t.AlertCooldown <= 0 {
+		t.AlertCooldown = d.AlertCooldown
+	}
+	return t
+}
+
+// ComputeBaseline summarizes the service's probes in [from, to).
+func ComputeBaseline(history []ProbeResult, service string, from, to time.Time) Baseline {
+	var b Baseline
+	var slots, latency int64
+	for _, p := range history {
+		if p.Service != service || p.ProbedAt.Before(from) || !p.ProbedAt.Before(to) {
+			continue
+		}
+		b.Samples++
+		latency += p.LatencyMS
+		if p.Success {
+			b.Successes++
+			slots += int64(p.SlotCount)
+		}
+	}
+	if b.Samples == 0 {
+		return b
+	}
+	b.AvgLatencyMS = float64(latency) / float64(b.Samples)
+	b.ErrorRate = float64(b.Samples-b.Successes) / float64(b.Samples)
+	if b.Successes > 0 {
+		b.AvgSlots = float64(slots) / float64(b.Successes)
+	}
+	return b
+}
+
+// Assessment is the outcome of comparing a probe to the service's history.
+type Assessment struct {
+	Alert           string // empty when the probe looks healthy
+	Suppressed      bool   // an alert of the same kind was sent within the cooldown
+	Baseline        Baseline
+	RecentErrorRate float64
+}
+
+// Assess compares current against the service's earlier probes in history.
+//
+// A zero slot count alerts unless the service has had no slots all week
+// (a fully booked clinic, already reported). Errors alert once the recent
+// error rate crosses t.ErrorRate and sits well above the baseline rate.
+func Assess(current ProbeResult, history []ProbeResult, now time.Time, t Thresholds) Assessment {
+	t = t.withDefaults()
+	recentFrom := now.Add(-t.RecentWindow)
+	a := Assessment{
+		Baseline: ComputeBaseline(history, current.Service, now.Add(-t.BaselineWindow), recentFrom),
+	}
+
+	recent := ComputeBaseline(append([]ProbeResult{current}, history...), current.Service, recentFrom, now.Add(time.Nanosecond))
+	a.RecentErrorRate = recent.ErrorRate
+
+	switch {
+	case !current.Success:
+		if recent.Samples >= t.MinRecentSamples &&
+			recent.ErrorRate >= t.ErrorRate &&
+			recent.ErrorRate-a.Baseline.ErrorRate >= t.ErrorRateIncrease {
+			a.Alert = AlertErrorSpike
+		}
+	case current.SlotCount == 0:
+		if a.Baseline.Successes == 0 || a.Baseline.AvgSlots > 0 {
+			a.Alert = AlertZeroSlots
+		}
+	}
+	if a.Alert == "" {
+		return a
+	}
+	cooldownFrom := now.Add(-t.AlertCooldown)
+	for _, p := range history {
+		if p.Service == current.Service && p.Alert == a.Alert && !p.ProbedAt.Before(cooldownFrom) {
+			a.Suppressed = true
+			break
+		}
+	}
+	return a
+}
+
+// ServiceHealth is the current health of one probed service.
+type ServiceHealth struct {
+	Service  string       `json:"service"`
+	Status   string       `json:"status"`
+	Latest   *ProbeResult `json:"latest,omitempty"`
+	Baseline Baseline     `json:"baseline"`
+}
+
+// Summarize reports each service's latest probe alongside its baseline over
+// the trailing window.
+func Summarize(history []ProbeResult, now time.Time, t Thresholds) []ServiceHealth {
+	t = t.withDefaults()
+	latest := map[string]*ProbeResult{}
+	for i := range history {
+		p := &history[i]
+		if cur, ok := latest[p.Service]; !ok || p.ProbedAt.After(cur.ProbedAt) {
+			latest[p.Service] = p
+		}
+	}
+	out := make([]ServiceHealth, 0, len(latest))
+	for service, p := range latest {
+		h := ServiceHealth{
+			Service:  service,
+			Latest:   p,
+			Baseline: ComputeBaseline(history, service, now.Add(-t.BaselineWindow), now.Add(time.Nanosecond)),
+		}
+		switch {
+		case !p.Success:
+			h.Status = StatusFailing
+		case p.SlotCount == 0:
+			h.Status = StatusZeroSlots
+		default:
+			h.Status = StatusOK
+		}
+		out = append(out, h)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Service < out[j].Service })
+	return out
+}
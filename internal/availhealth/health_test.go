@@ -0,0 +1,147 @@
+package availhealth
+
+import (
+	"testing"
+	"time"
+)
+
+func probeAt(service string, at time.Time, success bool, slots int) ProbeResult {
+	p := ProbeResult{OrgID: "org-1", Service: service, Platform: "moxie", Success: success, SlotCount: slots, LatencyMS: 100, ProbedAt: at}
+	if !success {
+		p.Error = "moxie API returned 500"
+	}
+	return p
+}
+
+func TestComputeBaseline(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	history := []ProbeResult{
+		probeAt("botox", now.Add(-2*time.Hour), true, 12),
+		probeAt("botox", now.Add(-26*time.Hour), true, 8),
+		probeAt("botox", now.Add(-50*time.Hour), false, 0),
+		probeAt("botox", now.Add(-8*24*time.Hour), true, 100), // outside the window
+		probeAt("filler", now.Add(-3*time.Hour), true, 50),    // other service
+	}
+
+	b := ComputeBaseline(history, "botox", now.Add(-7*24*time.Hour), now)
+	if b.Samples != 3 || b.Successes != 2 {
+		t.Fatalf("expected 3 samples / 2 successes, got %+v", b)
+	}
+	if b.AvgSlots != 10 {
+		t.Fatalf("expected avg slots over successes = 10, got %v", b.AvgSlots)
+	}
+	if b.ErrorRate < 0.33 || b.ErrorRate > 0.34 {
+		t.Fatalf("expected error rate 1/3, got %v", b.ErrorRate)
+	}
+
+	if empty := ComputeBaseline(nil, "botox", now.Add(-time.Hour), now); empty != (Baseline{}) {
+		t.Fatalf("expected zero baseline without history, got %+v", empty)
+	}
+}
+
+func TestAssess_ZeroSlots(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	healthyWeek := []ProbeResult{
+		probeAt("botox", now.Add(-30*time.Hour), true, 9),
+		probeAt("botox", now.Add(-54*time.Hour), true, 11),
+	}
+
+	a := Assess(probeAt("botox", now, true, 0), healthyWeek, now, DefaultThresholds())
+	if a.Alert != AlertZeroSlots || a.Suppressed {
+		t.Fatalf("expected zero-slot alert against a healthy baseline, got %+v", a)
+	}
+	if a.Baseline.AvgSlots != 10 {
+		t.Fatalf("expected baseline avg 10, got %v", a.Baseline.AvgSlots)
+	}
+
+	if a := Assess(probeAt("botox", now, true, 4), healthyWeek, now, DefaultThresholds()); a.Alert != "" {
+		t.Fatalf("expected no alert when slots are returned, got %q", a.Alert)
+	}
+
+	// A clinic that has been fully booked all week was already reported.
+	bookedOut := []ProbeResult{
+		probeAt("botox", now.Add(-30*time.Hour), true, 0),
+		probeAt("botox", now.Add(-54*time.Hour), true, 0),
+	}
+	if a := Assess(probeAt("botox", now, true, 0), bookedOut, now, DefaultThresholds()); a.Alert != "" {
+		t.Fatalf("expected no alert for a chronically empty calendar, got %q", a.Alert)
+	}
+
+	// With no history a zero count still alerts: likely a broken setup.
+	if a := Assess(probeAt("botox", now, true, 0), nil, now, DefaultThresholds()); a.Alert != AlertZeroSlots {
+		t.Fatalf("expected zero-slot alert without history, got %q", a.Alert)
+	}
+}
+
+func TestAssess_ErrorSpike(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	baseline := []ProbeResult{
+		probeAt("botox", now.Add(-30*time.Hour), true, 10),
+		probeAt("botox", now.Add(-36*time.Hour), true, 10),
+		probeAt("botox", now.Add(-42*time.Hour), true, 10),
+	}
+
+	// One failure among too few recent samples isn't a spike.
+	if a := Assess(probeAt("botox", now, false, 0), baseline, now, DefaultThresholds()); a.Alert != "" {
+		t.Fatalf("expected no alert for a single failure, got %+v", a)
+	}
+
+	history := append([]ProbeResult{
+		probeAt("botox", now.Add(-6*time.Hour), false, 0),
+		probeAt("botox", now.Add(-12*time.Hour), true, 10),
+	}, baseline...)
+	a := Assess(probeAt("botox", now, false, 0), history, now, DefaultThresholds())
+	if a.Alert != AlertErrorSpike {
+		t.Fatalf("expected error spike alert, got %+v", a)
+	}
+	if a.RecentErrorRate < 0.66 || a.RecentErrorRate > 0.67 || a.Baseline.ErrorRate != 0 {
+		t.Fatalf("unexpected rates: recent=%v baseline=%v", a.RecentErrorRate, a.Baseline.ErrorRate)
+	}
+
+	// A platform that always fails half the time isn't a spike.
+	flaky := append([]ProbeResult{
+		probeAt("botox", now.Add(-6*time.Hour), false, 0),
+		probeAt("botox", now.Add(-12*time.Hour), true, 10),
+	}, probeAt("botox", now.Add(-30*time.Hour), false, 0), probeAt("botox", now.Add(-36*time.Hour), true, 10))
+	th := DefaultThresholds()
+	if a := Assess(probeAt("botox", now, false, 0), flaky, now, th); a.Alert != "" {
+		t.Fatalf("expected no alert when recent rate is near baseline, got %+v", a)
+	}
+}
+
+func TestAssess_CooldownSuppressesRepeatAlerts(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	alerted := probeAt("botox", now.Add(-6*time.Hour), true, 0)
+	alerted.Alert = AlertZeroSlots
+	history := []ProbeResult{alerted, probeAt("botox", now.Add(-48*time.Hour), true, 10)}
+
+	a := Assess(probeAt("botox", now, true, 0), history, now, DefaultThresholds())
+	if a.Alert != AlertZeroSlots || !a.Suppressed {
+		t.Fatalf("expected suppressed repeat alert, got %+v", a)
+	}
+
+	a = Assess(probeAt("botox", now.Add(20*time.Hour), true, 0), history, now.Add(20*time.Hour), DefaultThresholds())
+	if a.Alert != AlertZeroSlots || a.Suppressed {
+		t.Fatalf("expected alert once the cooldown passed, got %+v", a)
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	history := []ProbeResult{
+		probeAt("filler", now.Add(-time.Hour), false, 0),
+		probeAt("botox", now.Add(-time.Hour), true, 6),
+		probeAt("botox", now.Add(-7*time.Hour), true, 0),
+		probeAt("filler", now.Add(-7*time.Hour), true, 3),
+	}
+	got := Summarize(history, now, DefaultThresholds())
+	if len(got) != 2 || got[0].Service != "botox" || got[1].Service != "filler" {
+		t.Fatalf("expected services sorted by name, got %+v", got)
+	}
+	if got[0].Status != StatusOK || got[0].Latest.SlotCount != 6 || got[0].Baseline.Samples != 2 {
+		t.Fatalf("unexpected botox summary: %+v", got[0])
+	}
+	if got[1].Status != StatusFailing {
+		t.Fatalf("expected filler to be failing, got %+v", got[1])
+	}
+}
@@ -0,0 +1,251 @@
+package availhealth
+
+import (
+	"context"
+	"time"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/clinic"
+	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
+)
+
+const (
+	defaultMonitorInterval = 6 * time.Hour
+	defaultProbeGap        = 2 * time.Second
+	defaultProbeTimeout    = 30 * time.Second
+	// probeRetention is how long probe history is kept.
+	probeRetention = 30 * 24 * time.Hour
+)
+
+// ProbeStore persists probe results.
+type ProbeStore interface {
+	OrgIDs(ctx context.Context) ([]string, error)
+	Record(ctx context.Context, r ProbeResult) error
+	History(ctx context.Context, orgID string, since time.Time) ([]ProbeResult, error)
+	Prune(ctx context.Context, before time.Time) (int64, error)
+}
+
+// ClinicSource loads clinic configs.
+type ClinicSource interface {
+	Get(ctx context.Context, orgID string) (*clinic.Config, error)
+}
+
+// MonitorConfig configures the availability health monitor.
+type MonitorConfig struct {
+	Store      ProbeStore
+	Clinics    ClinicSource
+	Probers    []Prober
+	Alerter    Alerter // optional; alerts are only logged without one
+	Interval   time.Duration
+	ProbeGap   time.Duration // minimum spacing between platform calls
+	Thresholds Thresholds
+	Logger     *logging.Logger
+}
+
+// Monitor probes every clinic's availability on an interval, records the
+// results and alerts when slots vanish or errors spike.
+type Monitor struct {
+	store      ProbeStore
+	clinics    ClinicSource
+	probers    []Prober
+	alerter    Alerter
+	interval   time.Duration
+	probeGap   time.Duration
+	thresholds Thresholds
+	logger     *logging.Logger
+	now        func() time.Time
+	sleep      func(ctx context.Context, d time.Duration) error
+}
+
+// RunResult summarizes one pass of the monitor.
+type RunResult struct {
+	Orgs     int
+	Probes   int
+	Failures int
+	Alerts   int
+}
+
+// NewMonitor creates an availability health monitor.
+func NewMonitor(cfg MonitorConfig) *Monitor {
+	if cfg.Logger == nil {
+		cfg.Logger = logging.Default()
+	}
+	if cfg.Interval <= 0 {
+		cfg.Interval = defaultMonitorInterval
+	}
+	if cfg.ProbeGap <= 0 {
+		cfg.ProbeGap = defaultProbeGap
+	}
+	var probers []Prober
+	for _, p := range cfg.Probers {
+		if p != nil {
+			probers = append(probers, p)
+		}
+	}
+	return &Monitor{
+		store:      cfg.Store,
+		clinics:    cfg.Clinics,
+		probers:    probers,
+		alerter:    cfg.Alerter,
+		interval:   cfg.Interval,
+		probeGap:   cfg.ProbeGap,
+		thresholds: cfg.Thresholds.withDefaults(),
+		logger:     cfg.Logger,
+		now:        time.Now,
+		sleep:      sleepContext,
+	}
+}
+
+// Start runs the monitor on its interval. Blocks until ctx is cancelled.
+func (m *Monitor) Start(ctx context.Context) {
+	if m == nil || m.store == nil || m.clinics == nil || len(m.probers) == 0 {
+		return
+	}
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+
+	m.run(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			m.run(ctx)
+		}
+	}
+}
+
+func (m *Monitor) run(ctx context.Context) {
+	res, err := m.RunOnce(ctx)
+	if err != nil {
+		m.logger.Error("availability health run failed", "error", err)
+		return
+	}
+	m.logger.Info("availability health run completed",
+		"orgs", res.Orgs,
+		"probes", res.Probes,
+		"failures", res.Failures,
+		"alerts", res.Alerts,
+	)
+	if n, err := m.store.Prune(ctx, m.now().Add(-probeRetention)); err != nil {
+		m.logger.Warn("availability health: prune failed", "error", err)
+	} else if n > 0 {
+		m.logger.Info("availability health: pruned old probes", "deleted", n)
+	}
+}
+
+// RunOnce probes every supported clinic once.
+func (m *Monitor) RunOnce(ctx context.Context) (RunResult, error) {
+	var res RunResult
+	orgIDs, err := m.store.OrgIDs(ctx)
+	if err != nil {
+		return res, err
+	}
+	probed := false
+	for _, orgID := range orgIDs {
+		if ctx.Err() != nil {
+			return res, ctx.Err()
+		}
+		cfg, err := m.clinics.Get(ctx, orgID)
+		if err != nil {
+			m.logger.Warn("availability health: failed to load clinic config, skipping org", "error", err, "org_id", orgID)
+			continue
+		}
+		prober := m.proberFor(cfg)
+		services := cfg.AvailabilityProbeServices()
+		if prober == nil || len(services) == 0 {
+			continue
+		}
+		history, err := m.store.History(ctx, orgID, m.now().Add(-m.thresholds.BaselineWindow))
+		if err != nil {
+			return res, err
+		}
+		// Another instance (or a restart) probed this org recently; skip it
+		// rather than double the traffic to the platform.
+		if len(history) > 0 && m.now().Sub(history[0].ProbedAt) < m.interval/2 {
+			continue
+		}
+		res.Orgs++
+		for _, service := range services {
+			if probed {
+				if err := m.sleep(ctx, m.probeGap); err != nil {
+					return res, err
+				}
+			}
+			probed = true
+			m.probeService(ctx, orgID, cfg, prober, service, history, &res)
+		}
+	}
+	return res, nil
+}
+
+// probeService probes one service, alerts if needed and records the result.
+func (m *Monitor) probeService(ctx context.Context, orgID string, cfg *clinic.Config, prober Prober, service string, history []ProbeResult, res *RunResult) {
+	probeCtx, cancel := context.WithTimeout(ctx, defaultProbeTimeout)
+	start := m.now()
+	slots, err := prober.Probe(probeCtx, cfg, service)
+	cancel()
+
+	result := ProbeResult{
+		OrgID:     orgID,
+		Service:   service,
+		Platform:  prober.Platform(),
+		Success:   err == nil,
+		LatencyMS: m.now().Sub(start).Milliseconds(),
+		SlotCount: slots,
+		ProbedAt:  start,
+	}
+	res.Probes++
+	if err != nil {
+		result.Error = err.Error()
+		result.SlotCount = 0
+		res.Failures++
+	}
+
+	assessment := Assess(result, history, start, m.thresholds)
+	if assessment.Alert != "" {
+		m.logger.Warn("availability health: probe alert",
+			"org_id", orgID,
+			"service", service,
+			"alert", assessment.Alert,
+			"suppressed", assessment.Suppressed,
+			"slot_count", result.SlotCount,
+			"recent_error_rate", assessment.RecentErrorRate,
+			"baseline_avg_slots", assessment.Baseline.AvgSlots,
+			"error", result.Error,
+		)
+	}
+	if assessment.Alert != "" && !assessment.Suppressed && m.alerter != nil {
+		alert := Alert{OrgID: orgID, ClinicName: cfg.Name, Kind: assessment.Alert, Probe: result, Assessment: assessment}
+		if err := m.alerter.AvailabilityAlert(ctx, cfg, alert); err != nil {
+			m.logger.Error("availability health: alert failed", "error", err, "org_id", orgID, "service", service)
+		} else {
+			// Only a delivered alert starts the cooldown.
+			result.Alert = assessment.Alert
+			res.Alerts++
+		}
+	}
+
+	if err := m.store.Record(ctx, result); err != nil {
+		m.logger.Error("availability health: failed to record probe", "error", err, "org_id", orgID, "service", service)
+	}
+}
+
+func (m *Monitor) proberFor(cfg *clinic.Config) Prober {
+	for _, p := range m.probers {
+		if p.Supports(cfg) {
+			return p
+		}
+	}
+	return nil
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
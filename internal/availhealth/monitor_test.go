@@ -0,0 +1,154 @@
+package availhealth
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/clinic"
+	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
+)
+
+type memProbeStore struct {
+	orgs     []string
+	recorded []ProbeResult
+	history  map[string][]ProbeResult
+}
+
+func (s *memProbeStore) OrgIDs(ctx context.Context) ([]string, error) { return s.orgs, nil }
+
+func (s *memProbeStore) Record(ctx context.Context, r ProbeResult) error {
+	s.recorded = append(s.recorded, r)
+	return nil
+}
+
+func (s *memProbeStore) History(ctx context.Context, orgID string, since time.Time) ([]ProbeResult, error) {
+	return s.history[orgID], nil
+}
+
+func (s *memProbeStore) Prune(ctx context.Context, before time.Time) (int64, error) { return 0, nil }
+
+type memClinics map[string]*clinic.Config
+
+func (m memClinics) Get(ctx context.Context, orgID string) (*clinic.Config, error) {
+	return m[orgID], nil
+}
+
+type stubProber struct {
+	slots map[string]int
+	err   error
+	calls []string
+}
+
+func (p *stubProber) Platform() string { return "moxie" }
+
+func (p *stubProber) Supports(cfg *clinic.Config) bool { return cfg.UsesMoxieBooking() }
+
+func (p *stubProber) Probe(ctx context.Context, cfg *clinic.Config, service string) (int, error) {
+	p.calls = append(p.calls, cfg.OrgID+"/"+service)
+	return p.slots[service], p.err
+}
+
+type recordingAlerter struct {
+	alerts []Alert
+}
+
+func (a *recordingAlerter) AvailabilityAlert(ctx context.Context, cfg *clinic.Config, alert Alert) error {
+	a.alerts = append(a.alerts, alert)
+	return nil
+}
+
+func moxieClinic(orgID string, services ...string) *clinic.Config {
+	return &clinic.Config{
+		OrgID:              orgID,
+		Name:               "Glow " + orgID,
+		BookingPlatform:    "moxie",
+		AvailabilityHealth: &clinic.AvailabilityHealthConfig{Services: services},
+	}
+}
+
+func TestMonitorRunOnce_RecordsProbesAndAlerts(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	store := &memProbeStore{
+		orgs: []string{"org-1", "org-2", "org-3"},
+		history: map[string][]ProbeResult{
+			"org-1": {probeAt("botox", now.Add(-30*time.Hour), true, 10)},
+		},
+	}
+	clinics := memClinics{
+		"org-1": moxieClinic("org-1", "botox", "filler"),
+		"org-2": {OrgID: "org-2", BookingPlatform: "square"}, // no prober
+		"org-3": moxieClinic("org-3"),                        // nothing to probe
+	}
+	prober := &stubProber{slots: map[string]int{"filler": 7}}
+	alerter := &recordingAlerter{}
+	m := NewMonitor(MonitorConfig{Store: store, Clinics: clinics, Probers: []Prober{prober}, Alerter: alerter, Logger: logging.Default()})
+	m.now = func() time.Time { return now }
+	var gaps []time.Duration
+	m.sleep = func(ctx context.Context, d time.Duration) error {
+		gaps = append(gaps, d)
+		return nil
+	}
+
+	res, err := m.RunOnce(context.Background())
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if res.Orgs != 1 || res.Probes != 2 || res.Failures != 0 || res.Alerts != 1 {
+		t.Fatalf("unexpected result: %+v", res)
+	}
+	if len(prober.calls) != 2 || prober.calls[0] != "org-1/botox" || prober.calls[1] != "org-1/filler" {
+		t.Fatalf("unexpected probe calls: %v", prober.calls)
+	}
+	if len(gaps) != 1 || gaps[0] != defaultProbeGap {
+		t.Fatalf("expected one rate-limit gap between probes, got %v", gaps)
+	}
+	if len(alerter.alerts) != 1 || alerter.alerts[0].Kind != AlertZeroSlots || alerter.alerts[0].Probe.Service != "botox" {
+		t.Fatalf("expected a zero-slot alert for botox, got %+v", alerter.alerts)
+	}
+	if len(store.recorded) != 2 || store.recorded[0].Alert != AlertZeroSlots || store.recorded[1].Alert != "" {
+		t.Fatalf("expected the alerting probe to be marked, got %+v", store.recorded)
+	}
+	if store.recorded[1].SlotCount != 7 || !store.recorded[1].Success || store.recorded[1].Platform != "moxie" {
+		t.Fatalf("unexpected filler probe: %+v", store.recorded[1])
+	}
+}
+
+func TestMonitorRunOnce_RecordsFailures(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	store := &memProbeStore{orgs: []string{"org-1"}}
+	prober := &stubProber{err: errors.New("moxie API error: Cannot query field \"availableTimeSlots\"")}
+	m := NewMonitor(MonitorConfig{Store: store, Clinics: memClinics{"org-1": moxieClinic("org-1", "botox")}, Probers: []Prober{prober}})
+	m.now = func() time.Time { return now }
+
+	res, err := m.RunOnce(context.Background())
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if res.Failures != 1 || res.Alerts != 0 {
+		t.Fatalf("expected one failure and no alert without an alerter, got %+v", res)
+	}
+	if got := store.recorded[0]; got.Success || got.Error == "" {
+		t.Fatalf("expected failed probe with error, got %+v", got)
+	}
+}
+
+func TestMonitorRunOnce_SkipsRecentlyProbedOrg(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	store := &memProbeStore{
+		orgs:    []string{"org-1"},
+		history: map[string][]ProbeResult{"org-1": {probeAt("botox", now.Add(-time.Hour), true, 10)}},
+	}
+	prober := &stubProber{}
+	m := NewMonitor(MonitorConfig{Store: store, Clinics: memClinics{"org-1": moxieClinic("org-1", "botox")}, Probers: []Prober{prober}})
+	m.now = func() time.Time { return now }
+
+	res, err := m.RunOnce(context.Background())
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if res.Orgs != 0 || len(prober.calls) != 0 {
+		t.Fatalf("expected org probed an hour ago to be skipped, got %+v calls=%v", res, prober.calls)
+	}
+}
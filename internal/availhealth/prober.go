@@ -0,0 +1,82 @@
+package availhealth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/clinic"
+	moxieclient "github.com/wolfman30/medspa-ai-platform/internal/emr/moxie"
+)
+
+// probeDays is how far ahead a probe looks for slots.
+const probeDays = 7
+
+// Prober checks one service's availability on a clinic's booking platform.
+type Prober interface {
+	// Platform names the booking platform, e.g. "moxie".
+	Platform() string
+	// Supports reports whether the clinic books through this platform.
+	Supports(cfg *clinic.Config) bool
+	// Probe returns how many slots the platform offers for service.
+	Probe(ctx context.Context, cfg *clinic.Config, service string) (int, error)
+}
+
+// MoxieProber probes availability through Moxie's GraphQL API, the same
+// query patients' availability lookups use.
+type MoxieProber struct {
+	client *moxieclient.Client
+	now    func() time.Time
+}
+
+// NewMoxieProber creates a Moxie prober. Returns nil without a client.
+func NewMoxieProber(client *moxieclient.Client) *MoxieProber {
+	if client == nil {
+		return nil
+	}
+	return &MoxieProber{client: client, now: time.Now}
+}
+
+// Platform implements Prober.
+func (p *MoxieProber) Platform() string { return "moxie" }
+
+// Supports implements Prober.
+func (p *MoxieProber) Supports(cfg *clinic.Config) bool {
+	return cfg.UsesMoxieBooking() && cfg.MoxieConfig != nil && cfg.MoxieConfig.MedspaID != ""
+}
+
+// Probe implements Prober with a single availability query for the next
+// week. It queries one provider where possible because Moxie's no-preference
+// mode returns nothing for many clinics, which would read as an outage.
+func (p *MoxieProber) Probe(ctx context.Context, cfg *clinic.Config, service string) (int, error) {
+	if cfg == nil || cfg.MoxieConfig == nil {
+		return 0, errors.New("availhealth: clinic has no moxie config")
+	}
+	mc := cfg.MoxieConfig
+	itemID := cfg.ServiceMenuItemID(service)
+	if itemID == "" {
+		return 0, fmt.Errorf("availhealth: no moxie service menu item for %q", service)
+	}
+	providerID := mc.DefaultProviderID
+	if providerID == "" && len(mc.ServiceProviders[itemID]) > 0 {
+		providerID = mc.ServiceProviders[itemID][0]
+	}
+
+	loc, err := time.LoadLocation(cfg.Timezone)
+	if err != nil {
+		loc = time.UTC
+	}
+	today := p.now().In(loc)
+	result, err := p.client.GetAvailableSlots(ctx, mc.MedspaID,
+		today.Format("2006-01-02"), today.AddDate(0, 0, probeDays).Format("2006-01-02"),
+		itemID, providerID == "", providerID)
+	if err != nil {
+		return 0, fmt.Errorf("availhealth: moxie probe: %w", err)
+	}
+	count := 0
+	for _, d := range result.Dates {
+		count += len(d.Slots)
+	}
+	return count, nil
+}
@@ -0,0 +1,103 @@
+package availhealth
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// historyLimit caps the probes loaded for one org. At the default interval a
+// clinic with three services records well under this in a month.
+const historyLimit = 2000
+
+// Querier is the subset of pgxpool.Pool used by Store.
+type Querier interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+// Store persists probe results in Postgres.
+type Store struct {
+	pool Querier
+}
+
+// NewStore constructs a probe store. Returns nil without a pool.
+func NewStore(pool Querier) *Store {
+	if pool == nil {
+		return nil
+	}
+	return &Store{pool: pool}
+}
+
+// OrgIDs returns every organization to probe.
+func (s *Store) OrgIDs(ctx context.Context) ([]string, error) {
+	rows, err := s.pool.Query(ctx, `SELECT id::text FROM organizations ORDER BY id`)
+	if err != nil {
+		return nil, fmt.Errorf("availhealth: list orgs: %w", err)
+	}
+	defer rows.Close()
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("availhealth: scan org: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("availhealth: list orgs: %w", err)
+	}
+	return ids, nil
+}
+
+// Record saves a probe result.
+func (s *Store) Record(ctx context.Context, r ProbeResult) error {
+	_, err := s.pool.Exec(ctx, `
+		INSERT INTO availability_probes (org_id, service, platform, success, latency_ms, slot_count, error, alert, probed_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`, r.OrgID, r.Service, r.Platform, r.Success, r.LatencyMS, r.SlotCount, r.Error, r.Alert, r.ProbedAt)
+	if err != nil {
+		return fmt.Errorf("availhealth: record probe: %w", err)
+	}
+	return nil
+}
+
+// History returns the org's probes since the given time, newest first.
+func (s *Store) History(ctx context.Context, orgID string, since time.Time) ([]ProbeResult, error) {
+	rows, err := s.pool.Query(ctx, `
+		SELECT id, org_id, service, platform, success, latency_ms, slot_count, error, alert, probed_at
+		FROM availability_probes
+		WHERE org_id = $1 AND probed_at >= $2
+		ORDER BY probed_at DESC
+		LIMIT $3
+	`, orgID, since, historyLimit)
+	if err != nil {
+		return nil, fmt.Errorf("availhealth: load history: %w", err)
+	}
+	defer rows.Close()
+	out := []ProbeResult{}
+	for rows.Next() {
+		var r ProbeResult
+		if err := rows.Scan(&r.ID, &r.OrgID, &r.Service, &r.Platform, &r.Success, &r.LatencyMS, &r.SlotCount, &r.Error, &r.Alert, &r.ProbedAt); err != nil {
+			return nil, fmt.Errorf("availhealth: scan probe: %w", err)
+		}
+		out = append(out, r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("availhealth: load history: %w", err)
+	}
+	return out, nil
+}
+
+// Prune deletes probes older than before and returns how many were removed.
+func (s *Store) Prune(ctx context.Context, before time.Time) (int64, error) {
+	tag, err := s.pool.Exec(ctx, `DELETE FROM availability_probes WHERE probed_at < $1`, before)
+	if err != nil {
+		return 0, fmt.Errorf("availhealth: prune probes: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
@@ -7,6 +7,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/wolfman30/medspa-ai-platform/internal/messaging/templates"
 	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
 )
 
@@ -130,10 +131,7 @@ func (a *ManualHandoffAdapter) GetHandoffMessage(clinicName string) string {
 	if clinicName == "" {
 		clinicName = "the clinic"
 	}
-	return fmt.Sprintf(
-		"Thank you! I've shared your information with %s and they'll reach out to confirm your appointment shortly.",
-		clinicName,
-	)
+	return templates.Render(templates.BookingManualHandoff, templates.Params{"clinic_name": clinicName}, nil).Body
 }
 
 // FormatLeadSummary generates a plain-text qualified lead summary.
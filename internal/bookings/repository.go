@@ -2,14 +2,17 @@ package bookings
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/jackc/pgx/v5/pgxpool"
 
 	bookingsql "github.com/wolfman30/medspa-ai-platform/internal/bookings/sqlc"
+	"github.com/wolfman30/medspa-ai-platform/internal/tenancy"
 )
 
 // Repository provides persistence helpers for bookings.
@@ -30,15 +33,17 @@ func NewRepositoryWithQuerier(q bookingsql.Querier) *Repository {
 	return &Repository{queries: q}
 }
 
-// CreateConfirmed inserts a confirmed booking row.
-func (r *Repository) CreateConfirmed(ctx context.Context, orgID uuid.UUID, leadID uuid.UUID, scheduledFor *time.Time) (*bookingsql.Booking, error) {
+// CreateConfirmed inserts a confirmed booking row. A durationMinutes of zero
+// leaves the duration unknown.
+func (r *Repository) CreateConfirmed(ctx context.Context, orgID uuid.UUID, leadID uuid.UUID, scheduledFor *time.Time, durationMinutes int) (*bookingsql.Booking, error) {
 	arg := bookingsql.InsertBookingParams{
-		ID:           toPGUUID(uuid.New()),
-		OrgID:        orgID.String(),
-		LeadID:       toPGUUID(leadID),
-		Status:       "confirmed",
-		ConfirmedAt:  toPGTime(time.Now().UTC()),
-		ScheduledFor: toPGNullableTime(scheduledFor),
+		ID:              toPGUUID(uuid.New()),
+		OrgID:           orgID.String(),
+		LeadID:          toPGUUID(leadID),
+		Status:          "confirmed",
+		ConfirmedAt:     toPGTime(time.Now().UTC()),
+		ScheduledFor:    toPGNullableTime(scheduledFor),
+		DurationMinutes: toPGNullableMinutes(durationMinutes),
 	}
 	row, err := r.queries.InsertBooking(ctx, arg)
 	if err != nil {
@@ -48,10 +53,14 @@ func (r *Repository) CreateConfirmed(ctx context.Context, orgID uuid.UUID, leadI
 }
 
 // GetForOrg returns a booking scoped to the org.
-func (r *Repository) GetForOrg(ctx context.Context, orgID uuid.UUID, bookingID uuid.UUID) (*bookingsql.Booking, error) {
+func (r *Repository) GetForOrg(ctx context.Context, scope tenancy.OrgScope, bookingID uuid.UUID) (*bookingsql.Booking, error) {
+	orgID, err := scope.SingleOrg()
+	if err != nil {
+		return nil, fmt.Errorf("bookings: load for org: %w", err)
+	}
 	row, err := r.queries.GetBookingForOrg(ctx, bookingsql.GetBookingForOrgParams{
 		ID:    toPGUUID(bookingID),
-		OrgID: orgID.String(),
+		OrgID: orgID,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("bookings: load for org: %w", err)
@@ -59,6 +68,117 @@ func (r *Repository) GetForOrg(ctx context.Context, orgID uuid.UUID, bookingID u
 	return &row, nil
 }
 
+// NextUpcomingForLead returns the lead's earliest non-cancelled booking
+// scheduled after the given time, or nil when there is none.
+func (r *Repository) NextUpcomingForLead(ctx context.Context, scope tenancy.OrgScope, leadID uuid.UUID, after time.Time) (*bookingsql.Booking, error) {
+	orgID, err := scope.SingleOrg()
+	if err != nil {
+		return nil, fmt.Errorf("bookings: load next upcoming: %w", err)
+	}
+	row, err := r.queries.GetNextUpcomingBookingForLead(ctx, bookingsql.GetNextUpcomingBookingForLeadParams{
+		OrgID:        orgID,
+		LeadID:       toPGUUID(leadID),
+		ScheduledFor: toPGTime(after),
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("bookings: load next upcoming: %w", err)
+	}
+	return &row, nil
+}
+
+// UpcomingForLead returns the lead's non-cancelled bookings scheduled after
+// the given time, earliest first.
+func (r *Repository) UpcomingForLead(ctx context.Context, scope tenancy.OrgScope, leadID uuid.UUID, after time.Time) ([]bookingsql.Booking, error) {
+	orgID, err := scope.SingleOrg()
+	if err != nil {
+		return nil, fmt.Errorf("bookings: list upcoming: %w", err)
+	}
+	rows, err := r.queries.ListUpcomingBookingsForLead(ctx, bookingsql.ListUpcomingBookingsForLeadParams{
+		OrgID:        orgID,
+		LeadID:       toPGUUID(leadID),
+		ScheduledFor: toPGTime(after),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("bookings: list upcoming: %w", err)
+	}
+	return rows, nil
+}
+
+// FlagDisputed marks the lead's bookings as disputed so operators review them
+// before the appointment. Returns the number of bookings newly flagged.
+func (r *Repository) FlagDisputed(ctx context.Context, scope tenancy.OrgScope, leadID uuid.UUID) (int64, error) {
+	orgID, err := scope.SingleOrg()
+	if err != nil {
+		return 0, fmt.Errorf("bookings: flag disputed: %w", err)
+	}
+	n, err := r.queries.FlagBookingsDisputedForLead(ctx, bookingsql.FlagBookingsDisputedForLeadParams{
+		OrgID:  orgID,
+		LeadID: toPGUUID(leadID),
+	})
+	if err != nil {
+		return 0, fmt.Errorf("bookings: flag disputed: %w", err)
+	}
+	return n, nil
+}
+
+// SetLatestProvider records the provider on the lead's most recent booking.
+func (r *Repository) SetLatestProvider(ctx context.Context, scope tenancy.OrgScope, leadID uuid.UUID, providerID string) error {
+	orgID, err := scope.SingleOrg()
+	if err != nil {
+		return fmt.Errorf("bookings: set provider: %w", err)
+	}
+	_, err = r.queries.SetLatestBookingProviderForLead(ctx, bookingsql.SetLatestBookingProviderForLeadParams{
+		OrgID:      orgID,
+		LeadID:     toPGUUID(leadID),
+		ProviderID: pgtype.Text{String: providerID, Valid: providerID != ""},
+	})
+	if err != nil {
+		return fmt.Errorf("bookings: set provider: %w", err)
+	}
+	return nil
+}
+
+// MarkLatestManuallyOffered flags the lead's most recent booking as a time
+// clinic staff offered outside the booking platform's availability.
+func (r *Repository) MarkLatestManuallyOffered(ctx context.Context, scope tenancy.OrgScope, leadID uuid.UUID) error {
+	orgID, err := scope.SingleOrg()
+	if err != nil {
+		return fmt.Errorf("bookings: mark manually offered: %w", err)
+	}
+	_, err = r.queries.MarkLatestBookingManuallyOfferedForLead(ctx, bookingsql.MarkLatestBookingManuallyOfferedForLeadParams{
+		OrgID:  orgID,
+		LeadID: toPGUUID(leadID),
+	})
+	if err != nil {
+		return fmt.Errorf("bookings: mark manually offered: %w", err)
+	}
+	return nil
+}
+
+// CountByProviderSince returns the org's bookings per provider created since
+// the given time. Bookings without a recorded provider are left out.
+func (r *Repository) CountByProviderSince(ctx context.Context, scope tenancy.OrgScope, since time.Time) (map[string]int64, error) {
+	orgID, err := scope.SingleOrg()
+	if err != nil {
+		return nil, fmt.Errorf("bookings: count by provider: %w", err)
+	}
+	rows, err := r.queries.CountBookingsByProviderSince(ctx, bookingsql.CountBookingsByProviderSinceParams{
+		OrgID:     orgID,
+		CreatedAt: toPGTime(since),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("bookings: count by provider: %w", err)
+	}
+	counts := make(map[string]int64, len(rows))
+	for _, row := range rows {
+		counts[row.ProviderID] = row.Bookings
+	}
+	return counts, nil
+}
+
 func toPGUUID(id uuid.UUID) pgtype.UUID {
 	if id == uuid.Nil {
 		return pgtype.UUID{}
@@ -76,6 +196,16 @@ func toPGTime(t time.Time) pgtype.Timestamptz {
 	}
 }
 
+func toPGNullableMinutes(minutes int) pgtype.Int4 {
+	if minutes <= 0 {
+		return pgtype.Int4{}
+	}
+	return pgtype.Int4{
+		Int32: int32(minutes),
+		Valid: true,
+	}
+}
+
 func toPGNullableTime(t *time.Time) pgtype.Timestamptz {
 	if t == nil {
 		return pgtype.Timestamptz{}
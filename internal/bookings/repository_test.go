@@ -6,8 +6,11 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
 
 	bookingsql "github.com/wolfman30/medspa-ai-platform/internal/bookings/sqlc"
+	"github.com/wolfman30/medspa-ai-platform/internal/tenancy"
 )
 
 func TestCreateConfirmedPersistsScheduledFor(t *testing.T) {
@@ -18,7 +21,7 @@ func TestCreateConfirmedPersistsScheduledFor(t *testing.T) {
 	orgID := uuid.New()
 	leadID := uuid.New()
 
-	if _, err := repo.CreateConfirmed(context.Background(), orgID, leadID, &scheduled); err != nil {
+	if _, err := repo.CreateConfirmed(context.Background(), orgID, leadID, &scheduled, 0); err != nil {
 		t.Fatalf("CreateConfirmed returned error: %v", err)
 	}
 
@@ -33,15 +36,180 @@ func TestCreateConfirmedPersistsScheduledFor(t *testing.T) {
 	}
 }
 
+func TestCreateConfirmedPersistsDuration(t *testing.T) {
+	querier := &stubBookingQuerier{}
+	repo := NewRepositoryWithQuerier(querier)
+
+	row, err := repo.CreateConfirmed(context.Background(), uuid.New(), uuid.New(), nil, 75)
+	if err != nil {
+		t.Fatalf("CreateConfirmed returned error: %v", err)
+	}
+	if !querier.lastInsert.DurationMinutes.Valid || querier.lastInsert.DurationMinutes.Int32 != 75 {
+		t.Fatalf("expected duration_minutes 75 on insert, got %#v", querier.lastInsert.DurationMinutes)
+	}
+	if row.DurationMinutes.Int32 != 75 {
+		t.Fatalf("expected duration on returned row, got %#v", row.DurationMinutes)
+	}
+
+	if _, err := repo.CreateConfirmed(context.Background(), uuid.New(), uuid.New(), nil, 0); err != nil {
+		t.Fatalf("CreateConfirmed returned error: %v", err)
+	}
+	if querier.lastInsert.DurationMinutes.Valid {
+		t.Fatalf("expected an unknown duration to be stored as NULL")
+	}
+}
+
+func TestFlagDisputedScopesToOrgAndLead(t *testing.T) {
+	querier := &stubBookingQuerier{}
+	repo := NewRepositoryWithQuerier(querier)
+	orgID := uuid.New()
+	leadID := uuid.New()
+
+	n, err := repo.FlagDisputed(context.Background(), tenancy.ForOrg(orgID.String()), leadID)
+	if err != nil {
+		t.Fatalf("FlagDisputed returned error: %v", err)
+	}
+	if n != 1 {
+		t.Fatalf("expected 1 booking flagged, got %d", n)
+	}
+	if querier.lastFlag == nil || querier.lastFlag.OrgID != orgID.String() || uuid.UUID(querier.lastFlag.LeadID.Bytes) != leadID {
+		t.Fatalf("unexpected flag params: %#v", querier.lastFlag)
+	}
+}
+
+func TestNextUpcomingForLead(t *testing.T) {
+	querier := &stubBookingQuerier{}
+	repo := NewRepositoryWithQuerier(querier)
+	orgID, leadID := uuid.New(), uuid.New()
+	now := time.Now().UTC()
+
+	row, err := repo.NextUpcomingForLead(context.Background(), tenancy.ForOrg(orgID.String()), leadID, now)
+	if err != nil || row != nil {
+		t.Fatalf("expected no booking without error, got %#v, %v", row, err)
+	}
+	got := querier.lastUpcoming
+	if got == nil || got.OrgID != orgID.String() || uuid.UUID(got.LeadID.Bytes) != leadID || !got.ScheduledFor.Time.Equal(now) {
+		t.Fatalf("unexpected upcoming params: %#v", got)
+	}
+
+	scheduled := now.Add(26 * time.Hour)
+	querier.upcoming = &bookingsql.Booking{ScheduledFor: pgtype.Timestamptz{Time: scheduled, Valid: true}}
+	row, err = repo.NextUpcomingForLead(context.Background(), tenancy.ForOrg(orgID.String()), leadID, now)
+	if err != nil || row == nil || !row.ScheduledFor.Time.Equal(scheduled) {
+		t.Fatalf("expected the upcoming booking, got %#v, %v", row, err)
+	}
+}
+
+func TestUpcomingForLead(t *testing.T) {
+	scheduled := time.Now().UTC().Add(26 * time.Hour)
+	querier := &stubBookingQuerier{upcoming: &bookingsql.Booking{
+		ScheduledFor:    pgtype.Timestamptz{Time: scheduled, Valid: true},
+		DurationMinutes: pgtype.Int4{Int32: 45, Valid: true},
+	}}
+	repo := NewRepositoryWithQuerier(querier)
+	orgID, leadID := uuid.New(), uuid.New()
+	now := time.Now().UTC()
+
+	rows, err := repo.UpcomingForLead(context.Background(), tenancy.ForOrg(orgID.String()), leadID, now)
+	if err != nil || len(rows) != 1 || rows[0].DurationMinutes.Int32 != 45 {
+		t.Fatalf("expected the upcoming booking, got %#v, %v", rows, err)
+	}
+	got := querier.lastList
+	if got == nil || got.OrgID != orgID.String() || uuid.UUID(got.LeadID.Bytes) != leadID || !got.ScheduledFor.Time.Equal(now) {
+		t.Fatalf("unexpected list params: %#v", got)
+	}
+}
+
+func TestProviderCountsAndRecording(t *testing.T) {
+	querier := &stubBookingQuerier{providerRows: []bookingsql.CountBookingsByProviderSinceRow{
+		{ProviderID: "prov-a", Bookings: 7},
+		{ProviderID: "prov-b", Bookings: 2},
+	}}
+	repo := NewRepositoryWithQuerier(querier)
+	orgID, leadID := uuid.New(), uuid.New()
+
+	counts, err := repo.CountByProviderSince(context.Background(), tenancy.ForOrg(orgID.String()), time.Now().Add(-time.Hour))
+	if err != nil {
+		t.Fatalf("CountByProviderSince returned error: %v", err)
+	}
+	if counts["prov-a"] != 7 || counts["prov-b"] != 2 || len(counts) != 2 {
+		t.Fatalf("unexpected counts: %v", counts)
+	}
+
+	if err := repo.SetLatestProvider(context.Background(), tenancy.ForOrg(orgID.String()), leadID, "prov-b"); err != nil {
+		t.Fatalf("SetLatestProvider returned error: %v", err)
+	}
+	got := querier.lastProvider
+	if got == nil || got.OrgID != orgID.String() || uuid.UUID(got.LeadID.Bytes) != leadID || got.ProviderID.String != "prov-b" || !got.ProviderID.Valid {
+		t.Fatalf("unexpected provider params: %#v", got)
+	}
+}
+
+func TestMarkLatestManuallyOfferedScopesToOrgAndLead(t *testing.T) {
+	querier := &stubBookingQuerier{}
+	repo := NewRepositoryWithQuerier(querier)
+	orgID, leadID := uuid.New(), uuid.New()
+
+	if err := repo.MarkLatestManuallyOffered(context.Background(), tenancy.ForOrg(orgID.String()), leadID); err != nil {
+		t.Fatalf("MarkLatestManuallyOffered returned error: %v", err)
+	}
+	got := querier.lastManual
+	if got == nil || got.OrgID != orgID.String() || uuid.UUID(got.LeadID.Bytes) != leadID {
+		t.Fatalf("unexpected manual offer params: %#v", got)
+	}
+}
+
 type stubBookingQuerier struct {
-	lastInsert *bookingsql.InsertBookingParams
+	lastInsert   *bookingsql.InsertBookingParams
+	lastFlag     *bookingsql.FlagBookingsDisputedForLeadParams
+	lastProvider *bookingsql.SetLatestBookingProviderForLeadParams
+	lastManual   *bookingsql.MarkLatestBookingManuallyOfferedForLeadParams
+	providerRows []bookingsql.CountBookingsByProviderSinceRow
+	lastUpcoming *bookingsql.GetNextUpcomingBookingForLeadParams
+	upcoming     *bookingsql.Booking
+	lastList     *bookingsql.ListUpcomingBookingsForLeadParams
 }
 
 func (s *stubBookingQuerier) InsertBooking(ctx context.Context, arg bookingsql.InsertBookingParams) (bookingsql.Booking, error) {
 	s.lastInsert = &arg
-	return bookingsql.Booking{ScheduledFor: arg.ScheduledFor}, nil
+	return bookingsql.Booking{ScheduledFor: arg.ScheduledFor, DurationMinutes: arg.DurationMinutes}, nil
+}
+
+func (s *stubBookingQuerier) FlagBookingsDisputedForLead(ctx context.Context, arg bookingsql.FlagBookingsDisputedForLeadParams) (int64, error) {
+	s.lastFlag = &arg
+	return 1, nil
 }
 
 func (*stubBookingQuerier) GetBookingForOrg(ctx context.Context, arg bookingsql.GetBookingForOrgParams) (bookingsql.Booking, error) {
 	return bookingsql.Booking{}, nil
 }
+
+func (s *stubBookingQuerier) GetNextUpcomingBookingForLead(ctx context.Context, arg bookingsql.GetNextUpcomingBookingForLeadParams) (bookingsql.Booking, error) {
+	s.lastUpcoming = &arg
+	if s.upcoming == nil {
+		return bookingsql.Booking{}, pgx.ErrNoRows
+	}
+	return *s.upcoming, nil
+}
+
+func (s *stubBookingQuerier) ListUpcomingBookingsForLead(ctx context.Context, arg bookingsql.ListUpcomingBookingsForLeadParams) ([]bookingsql.Booking, error) {
+	s.lastList = &arg
+	if s.upcoming == nil {
+		return nil, nil
+	}
+	return []bookingsql.Booking{*s.upcoming}, nil
+}
+
+func (s *stubBookingQuerier) SetLatestBookingProviderForLead(ctx context.Context, arg bookingsql.SetLatestBookingProviderForLeadParams) (int64, error) {
+	s.lastProvider = &arg
+	return 1, nil
+}
+
+func (s *stubBookingQuerier) MarkLatestBookingManuallyOfferedForLead(ctx context.Context, arg bookingsql.MarkLatestBookingManuallyOfferedForLeadParams) (int64, error) {
+	s.lastManual = &arg
+	return 1, nil
+}
+
+func (s *stubBookingQuerier) CountBookingsByProviderSince(ctx context.Context, arg bookingsql.CountBookingsByProviderSinceParams) ([]bookingsql.CountBookingsByProviderSinceRow, error) {
+	return s.providerRows, nil
+}
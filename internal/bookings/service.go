@@ -9,6 +9,7 @@ import (
 	"go.opentelemetry.io/otel/attribute"
 
 	bookingsql "github.com/wolfman30/medspa-ai-platform/internal/bookings/sqlc"
+	"github.com/wolfman30/medspa-ai-platform/internal/tenancy"
 	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
 )
 
@@ -32,7 +33,8 @@ func NewService(repo *Repository, logger *logging.Logger) *Service {
 }
 
 // ConfirmBooking creates a confirmed booking row scoped to the org & lead.
-func (s *Service) ConfirmBooking(ctx context.Context, orgID uuid.UUID, leadID uuid.UUID, scheduledFor *time.Time) (*bookingsql.Booking, error) {
+// durationMinutes is the appointment length, or zero when unknown.
+func (s *Service) ConfirmBooking(ctx context.Context, orgID uuid.UUID, leadID uuid.UUID, scheduledFor *time.Time, durationMinutes int) (*bookingsql.Booking, error) {
 	ctx, span := bookingsTracer.Start(ctx, "bookings.confirm")
 	defer span.End()
 	span.SetAttributes(
@@ -40,7 +42,7 @@ func (s *Service) ConfirmBooking(ctx context.Context, orgID uuid.UUID, leadID uu
 		attribute.String("medspa.lead_id", leadID.String()),
 	)
 
-	row, err := s.repo.CreateConfirmed(ctx, orgID, leadID, scheduledFor)
+	row, err := s.repo.CreateConfirmed(ctx, orgID, leadID, scheduledFor, durationMinutes)
 	if err != nil {
 		span.RecordError(err)
 		return nil, err
@@ -49,6 +51,52 @@ func (s *Service) ConfirmBooking(ctx context.Context, orgID uuid.UUID, leadID uu
 	if row.ID.Valid {
 		bookingID = uuid.UUID(row.ID.Bytes).String()
 	}
-	s.logger.Info("booking confirmed", "org_id", orgID, "lead_id", leadID, "booking_id", bookingID)
+	s.logger.Info("booking confirmed", "org_id", orgID, "lead_id", leadID, "booking_id", bookingID, "duration_minutes", durationMinutes)
 	return row, nil
 }
+
+// FlagDisputed flags the lead's bookings after their deposit is disputed.
+func (s *Service) FlagDisputed(ctx context.Context, orgID uuid.UUID, leadID uuid.UUID) (int64, error) {
+	ctx, span := bookingsTracer.Start(ctx, "bookings.flag_disputed")
+	defer span.End()
+	span.SetAttributes(
+		attribute.String("medspa.org_id", orgID.String()),
+		attribute.String("medspa.lead_id", leadID.String()),
+	)
+
+	n, err := s.repo.FlagDisputed(ctx, tenancy.ForOrg(orgID.String()), leadID)
+	if err != nil {
+		span.RecordError(err)
+		return 0, err
+	}
+	s.logger.Warn("bookings flagged for disputed deposit", "org_id", orgID, "lead_id", leadID, "bookings", n)
+	return n, nil
+}
+
+// RecordProvider stores which provider the lead's latest booking went to.
+func (s *Service) RecordProvider(ctx context.Context, orgID uuid.UUID, leadID uuid.UUID, providerID string) error {
+	return s.repo.SetLatestProvider(ctx, tenancy.ForOrg(orgID.String()), leadID, providerID)
+}
+
+// MarkManuallyOffered flags the lead's latest booking as offered by clinic
+// staff, so its failed platform write-back isn't treated as an incident.
+func (s *Service) MarkManuallyOffered(ctx context.Context, orgID uuid.UUID, leadID uuid.UUID) error {
+	return s.repo.MarkLatestManuallyOffered(ctx, tenancy.ForOrg(orgID.String()), leadID)
+}
+
+// ProviderCounts returns the org's bookings per provider since the given time.
+func (s *Service) ProviderCounts(ctx context.Context, orgID uuid.UUID, since time.Time) (map[string]int64, error) {
+	return s.repo.CountByProviderSince(ctx, tenancy.ForOrg(orgID.String()), since)
+}
+
+// NextUpcoming returns the lead's next scheduled booking after the given
+// time, or nil when there is none.
+func (s *Service) NextUpcoming(ctx context.Context, orgID uuid.UUID, leadID uuid.UUID, after time.Time) (*bookingsql.Booking, error) {
+	return s.repo.NextUpcomingForLead(ctx, tenancy.ForOrg(orgID.String()), leadID, after)
+}
+
+// Upcoming returns the lead's non-cancelled bookings scheduled after the
+// given time.
+func (s *Service) Upcoming(ctx context.Context, orgID uuid.UUID, leadID uuid.UUID, after time.Time) ([]bookingsql.Booking, error) {
+	return s.repo.UpcomingForLead(ctx, tenancy.ForOrg(orgID.String()), leadID, after)
+}
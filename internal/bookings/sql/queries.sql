@@ -5,12 +5,67 @@ INSERT INTO bookings (
     lead_id,
     status,
     confirmed_at,
-    scheduled_for
+    scheduled_for,
+    duration_minutes
 )
-VALUES ($1, $2, $3, $4, $5, $6)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
 RETURNING *;
 
 -- name: GetBookingForOrg :one
 SELECT * FROM bookings
 WHERE id = $1
   AND org_id = $2;
+
+-- name: GetNextUpcomingBookingForLead :one
+SELECT * FROM bookings
+WHERE org_id = $1
+  AND lead_id = $2
+  AND status <> 'cancelled'
+  AND scheduled_for > $3
+ORDER BY scheduled_for
+LIMIT 1;
+
+-- name: ListUpcomingBookingsForLead :many
+SELECT * FROM bookings
+WHERE org_id = $1
+  AND lead_id = $2
+  AND status <> 'cancelled'
+  AND scheduled_for > $3
+ORDER BY scheduled_for;
+
+-- name: FlagBookingsDisputedForLead :execrows
+UPDATE bookings
+SET disputed_at = now()
+WHERE org_id = $1
+  AND lead_id = $2
+  AND disputed_at IS NULL;
+
+-- name: SetLatestBookingProviderForLead :execrows
+UPDATE bookings
+SET provider_id = $3
+WHERE id = (
+    SELECT b.id FROM bookings b
+    WHERE b.org_id = $1
+      AND b.lead_id = $2
+    ORDER BY b.created_at DESC
+    LIMIT 1
+);
+
+-- name: CountBookingsByProviderSince :many
+SELECT provider_id::text AS provider_id, COUNT(*) AS bookings
+FROM bookings
+WHERE org_id = $1
+  AND provider_id IS NOT NULL
+  AND created_at >= $2
+GROUP BY provider_id;
+
+-- name: MarkLatestBookingManuallyOfferedForLead :execrows
+UPDATE bookings
+SET manually_offered = true
+WHERE id = (
+    SELECT b.id FROM bookings b
+    WHERE b.org_id = $1
+      AND b.lead_id = $2
+    ORDER BY b.created_at DESC
+    LIMIT 1
+);
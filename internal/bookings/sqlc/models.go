@@ -9,13 +9,17 @@ import (
 )
 
 type Booking struct {
-	ID           pgtype.UUID
-	OrgID        string
-	LeadID       pgtype.UUID
-	Status       string
-	ConfirmedAt  pgtype.Timestamptz
-	CreatedAt    pgtype.Timestamptz
-	ScheduledFor pgtype.Timestamptz
+	ID              pgtype.UUID
+	OrgID           string
+	LeadID          pgtype.UUID
+	Status          string
+	ConfirmedAt     pgtype.Timestamptz
+	CreatedAt       pgtype.Timestamptz
+	ScheduledFor    pgtype.Timestamptz
+	DisputedAt      pgtype.Timestamptz
+	DurationMinutes pgtype.Int4
+	ProviderID      pgtype.Text
+	ManuallyOffered bool
 }
 
 type Lead struct {
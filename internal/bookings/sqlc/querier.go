@@ -9,8 +9,14 @@ import (
 )
 
 type Querier interface {
+	CountBookingsByProviderSince(ctx context.Context, arg CountBookingsByProviderSinceParams) ([]CountBookingsByProviderSinceRow, error)
+	FlagBookingsDisputedForLead(ctx context.Context, arg FlagBookingsDisputedForLeadParams) (int64, error)
 	GetBookingForOrg(ctx context.Context, arg GetBookingForOrgParams) (Booking, error)
+	GetNextUpcomingBookingForLead(ctx context.Context, arg GetNextUpcomingBookingForLeadParams) (Booking, error)
 	InsertBooking(ctx context.Context, arg InsertBookingParams) (Booking, error)
+	ListUpcomingBookingsForLead(ctx context.Context, arg ListUpcomingBookingsForLeadParams) ([]Booking, error)
+	MarkLatestBookingManuallyOfferedForLead(ctx context.Context, arg MarkLatestBookingManuallyOfferedForLeadParams) (int64, error)
+	SetLatestBookingProviderForLead(ctx context.Context, arg SetLatestBookingProviderForLeadParams) (int64, error)
 }
 
 var _ Querier = (*Queries)(nil)
@@ -11,8 +11,68 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const countBookingsByProviderSince = `-- name: CountBookingsByProviderSince :many
+SELECT provider_id::text AS provider_id, COUNT(*) AS bookings
+FROM bookings
+WHERE org_id = $1
+  AND provider_id IS NOT NULL
+  AND created_at >= $2
+GROUP BY provider_id
+`
+
+type CountBookingsByProviderSinceParams struct {
+	OrgID     string
+	CreatedAt pgtype.Timestamptz
+}
+
+type CountBookingsByProviderSinceRow struct {
+	ProviderID string
+	Bookings   int64
+}
+
+func (q *Queries) CountBookingsByProviderSince(ctx context.Context, arg CountBookingsByProviderSinceParams) ([]CountBookingsByProviderSinceRow, error) {
+	rows, err := q.db.Query(ctx, countBookingsByProviderSince, arg.OrgID, arg.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []CountBookingsByProviderSinceRow
+	for rows.Next() {
+		var i CountBookingsByProviderSinceRow
+		if err := rows.Scan(&i.ProviderID, &i.Bookings); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const flagBookingsDisputedForLead = `-- name: FlagBookingsDisputedForLead :execrows
+UPDATE bookings
+SET disputed_at = now()
+WHERE org_id = $1
+  AND lead_id = $2
+  AND disputed_at IS NULL
+`
+
+type FlagBookingsDisputedForLeadParams struct {
+	OrgID  string
+	LeadID pgtype.UUID
+}
+
+func (q *Queries) FlagBookingsDisputedForLead(ctx context.Context, arg FlagBookingsDisputedForLeadParams) (int64, error) {
+	result, err := q.db.Exec(ctx, flagBookingsDisputedForLead, arg.OrgID, arg.LeadID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
 const getBookingForOrg = `-- name: GetBookingForOrg :one
-SELECT id, org_id, lead_id, status, confirmed_at, created_at, scheduled_for FROM bookings
+SELECT id, org_id, lead_id, status, confirmed_at, created_at, scheduled_for, disputed_at, duration_minutes, provider_id, manually_offered FROM bookings
 WHERE id = $1
   AND org_id = $2
 `
@@ -33,6 +93,45 @@ func (q *Queries) GetBookingForOrg(ctx context.Context, arg GetBookingForOrgPara
 		&i.ConfirmedAt,
 		&i.CreatedAt,
 		&i.ScheduledFor,
+		&i.DisputedAt,
+		&i.DurationMinutes,
+		&i.ProviderID,
+		&i.ManuallyOffered,
+	)
+	return i, err
+}
+
+const getNextUpcomingBookingForLead = `-- name: GetNextUpcomingBookingForLead :one
+SELECT id, org_id, lead_id, status, confirmed_at, created_at, scheduled_for, disputed_at, duration_minutes, provider_id, manually_offered FROM bookings
+WHERE org_id = $1
+  AND lead_id = $2
+  AND status <> 'cancelled'
+  AND scheduled_for > $3
+ORDER BY scheduled_for
+LIMIT 1
+`
+
+type GetNextUpcomingBookingForLeadParams struct {
+	OrgID        string
+	LeadID       pgtype.UUID
+	ScheduledFor pgtype.Timestamptz
+}
+
+func (q *Queries) GetNextUpcomingBookingForLead(ctx context.Context, arg GetNextUpcomingBookingForLeadParams) (Booking, error) {
+	row := q.db.QueryRow(ctx, getNextUpcomingBookingForLead, arg.OrgID, arg.LeadID, arg.ScheduledFor)
+	var i Booking
+	err := row.Scan(
+		&i.ID,
+		&i.OrgID,
+		&i.LeadID,
+		&i.Status,
+		&i.ConfirmedAt,
+		&i.CreatedAt,
+		&i.ScheduledFor,
+		&i.DisputedAt,
+		&i.DurationMinutes,
+		&i.ProviderID,
+		&i.ManuallyOffered,
 	)
 	return i, err
 }
@@ -44,19 +143,21 @@ INSERT INTO bookings (
     lead_id,
     status,
     confirmed_at,
-    scheduled_for
+    scheduled_for,
+    duration_minutes
 )
-VALUES ($1, $2, $3, $4, $5, $6)
-RETURNING id, org_id, lead_id, status, confirmed_at, created_at, scheduled_for
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING id, org_id, lead_id, status, confirmed_at, created_at, scheduled_for, disputed_at, duration_minutes, provider_id, manually_offered
 `
 
 type InsertBookingParams struct {
-	ID           pgtype.UUID
-	OrgID        string
-	LeadID       pgtype.UUID
-	Status       string
-	ConfirmedAt  pgtype.Timestamptz
-	ScheduledFor pgtype.Timestamptz
+	ID              pgtype.UUID
+	OrgID           string
+	LeadID          pgtype.UUID
+	Status          string
+	ConfirmedAt     pgtype.Timestamptz
+	ScheduledFor    pgtype.Timestamptz
+	DurationMinutes pgtype.Int4
 }
 
 func (q *Queries) InsertBooking(ctx context.Context, arg InsertBookingParams) (Booking, error) {
@@ -67,6 +168,7 @@ func (q *Queries) InsertBooking(ctx context.Context, arg InsertBookingParams) (B
 		arg.Status,
 		arg.ConfirmedAt,
 		arg.ScheduledFor,
+		arg.DurationMinutes,
 	)
 	var i Booking
 	err := row.Scan(
@@ -77,6 +179,108 @@ func (q *Queries) InsertBooking(ctx context.Context, arg InsertBookingParams) (B
 		&i.ConfirmedAt,
 		&i.CreatedAt,
 		&i.ScheduledFor,
+		&i.DisputedAt,
+		&i.DurationMinutes,
+		&i.ProviderID,
+		&i.ManuallyOffered,
 	)
 	return i, err
 }
+
+const listUpcomingBookingsForLead = `-- name: ListUpcomingBookingsForLead :many
+SELECT id, org_id, lead_id, status, confirmed_at, created_at, scheduled_for, disputed_at, duration_minutes, provider_id, manually_offered FROM bookings
+WHERE org_id = $1
+  AND lead_id = $2
+  AND status <> 'cancelled'
+  AND scheduled_for > $3
+ORDER BY scheduled_for
+`
+
+type ListUpcomingBookingsForLeadParams struct {
+	OrgID        string
+	LeadID       pgtype.UUID
+	ScheduledFor pgtype.Timestamptz
+}
+
+func (q *Queries) ListUpcomingBookingsForLead(ctx context.Context, arg ListUpcomingBookingsForLeadParams) ([]Booking, error) {
+	rows, err := q.db.Query(ctx, listUpcomingBookingsForLead, arg.OrgID, arg.LeadID, arg.ScheduledFor)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Booking
+	for rows.Next() {
+		var i Booking
+		if err := rows.Scan(
+			&i.ID,
+			&i.OrgID,
+			&i.LeadID,
+			&i.Status,
+			&i.ConfirmedAt,
+			&i.CreatedAt,
+			&i.ScheduledFor,
+			&i.DisputedAt,
+			&i.DurationMinutes,
+			&i.ProviderID,
+			&i.ManuallyOffered,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const markLatestBookingManuallyOfferedForLead = `-- name: MarkLatestBookingManuallyOfferedForLead :execrows
+UPDATE bookings
+SET manually_offered = true
+WHERE id = (
+    SELECT b.id FROM bookings b
+    WHERE b.org_id = $1
+      AND b.lead_id = $2
+    ORDER BY b.created_at DESC
+    LIMIT 1
+)
+`
+
+type MarkLatestBookingManuallyOfferedForLeadParams struct {
+	OrgID  string
+	LeadID pgtype.UUID
+}
+
+func (q *Queries) MarkLatestBookingManuallyOfferedForLead(ctx context.Context, arg MarkLatestBookingManuallyOfferedForLeadParams) (int64, error) {
+	result, err := q.db.Exec(ctx, markLatestBookingManuallyOfferedForLead, arg.OrgID, arg.LeadID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const setLatestBookingProviderForLead = `-- name: SetLatestBookingProviderForLead :execrows
+UPDATE bookings
+SET provider_id = $3
+WHERE id = (
+    SELECT b.id FROM bookings b
+    WHERE b.org_id = $1
+      AND b.lead_id = $2
+    ORDER BY b.created_at DESC
+    LIMIT 1
+)
+`
+
+type SetLatestBookingProviderForLeadParams struct {
+	OrgID      string
+	LeadID     pgtype.UUID
+	ProviderID pgtype.Text
+}
+
+func (q *Queries) SetLatestBookingProviderForLead(ctx context.Context, arg SetLatestBookingProviderForLeadParams) (int64, error) {
+	result, err := q.db.Exec(ctx, setLatestBookingProviderForLead, arg.OrgID, arg.LeadID, arg.ProviderID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
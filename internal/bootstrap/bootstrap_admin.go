@@ -10,6 +10,7 @@ import (
 	"github.com/redis/go-redis/v9"
 	"github.com/wolfman30/medspa-ai-platform/cmd/mainconfig"
 	"github.com/wolfman30/medspa-ai-platform/internal/archive"
+	"github.com/wolfman30/medspa-ai-platform/internal/availhealth"
 	"github.com/wolfman30/medspa-ai-platform/internal/clinic"
 	"github.com/wolfman30/medspa-ai-platform/internal/clinicdata"
 	appconfig "github.com/wolfman30/medspa-ai-platform/internal/config"
@@ -24,6 +25,7 @@ type AdminClinicDataDeps struct {
 	Logger      *logging.Logger
 	DBPool      *pgxpool.Pool
 	RedisClient *redis.Client
+	ColdStore   *clinicdata.ColdStore // optional: purges also delete cold-storage archives
 }
 
 // AdminHandlerAssembler groups shared dependencies for admin handler assembly.
@@ -33,6 +35,7 @@ type AdminHandlerAssembler struct {
 	logger      *logging.Logger
 	dbPool      *pgxpool.Pool
 	redisClient *redis.Client
+	coldStore   *clinicdata.ColdStore
 }
 
 // NewAdminHandlerAssembler creates an assembler from the given dependencies,
@@ -44,6 +47,7 @@ func NewAdminHandlerAssembler(deps AdminClinicDataDeps) *AdminHandlerAssembler {
 		logger:      deps.Logger,
 		dbPool:      deps.DBPool,
 		redisClient: deps.RedisClient,
+		coldStore:   deps.ColdStore,
 	}
 }
 
@@ -59,9 +63,10 @@ func (a *AdminHandlerAssembler) buildAdminClinicDataHandler() *handlers.AdminCli
 	}
 
 	adminCfg := handlers.AdminClinicDataConfig{
-		DB:     a.dbPool,
-		Redis:  a.redisClient,
-		Logger: a.logger,
+		DB:        a.dbPool,
+		Redis:     a.redisClient,
+		Logger:    a.logger,
+		ColdStore: a.coldStore,
 	}
 
 	if a.cfg.S3ArchiveBucket != "" {
@@ -92,6 +97,27 @@ func (a *AdminHandlerAssembler) buildS3Archiver() *clinicdata.Archiver {
 	})
 }
 
+// BuildColdStore creates the S3-backed conversation cold store, or returns
+// nil when no cold-storage bucket is configured.
+func BuildColdStore(ctx context.Context, cfg *appconfig.Config, logger *logging.Logger) *clinicdata.ColdStore {
+	if cfg.ColdStorageBucket == "" {
+		return nil
+	}
+	awsCfg, err := mainconfig.LoadAWSConfig(ctx, cfg)
+	if err != nil {
+		logger.Warn("failed to load AWS config for cold storage, cold storage disabled", "error", err)
+		return nil
+	}
+	logger.Info("conversation cold storage enabled", "bucket", cfg.ColdStorageBucket)
+	return clinicdata.NewColdStore(clinicdata.ColdStoreConfig{
+		S3:       s3.NewFromConfig(awsCfg),
+		Bucket:   cfg.ColdStorageBucket,
+		KMSKeyID: cfg.S3ArchiveKMSKey,
+		CacheTTL: cfg.ColdStorageCacheTTL,
+		Logger:   logger,
+	})
+}
+
 // buildTrainingArchiver creates the training data archiver with LLM classifier.
 func (a *AdminHandlerAssembler) buildTrainingArchiver() *archive.TrainingArchiver {
 	awsCfg, err := mainconfig.LoadAWSConfig(a.appCtx, a.cfg)
@@ -115,9 +141,13 @@ func BuildClinicHandlers(logger *logging.Logger, clinicStore *clinic.Store, dbPo
 
 	if clinicStore != nil {
 		ch = clinic.NewHandler(clinicStore, logger)
+		ch.SetBookingURLValidator(availhealth.NewBookingPageProber(nil))
 	}
 	if dbPool != nil {
 		stats = clinic.NewStatsHandler(clinic.NewStatsRepository(dbPool), logger)
+		if clinicStore != nil {
+			stats.SetConfigStore(clinicStore)
+		}
 		dashboard = clinic.NewDashboardHandler(clinic.NewDashboardRepository(dbPool), prometheus.DefaultGatherer, logger)
 	}
 	return ch, stats, dashboard
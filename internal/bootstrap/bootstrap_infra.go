@@ -28,6 +28,7 @@ import (
 	"github.com/wolfman30/medspa-ai-platform/internal/messaging"
 	"github.com/wolfman30/medspa-ai-platform/internal/messaging/telnyxclient"
 	observemetrics "github.com/wolfman30/medspa-ai-platform/internal/observability/metrics"
+	"github.com/wolfman30/medspa-ai-platform/internal/pii"
 	"github.com/wolfman30/medspa-ai-platform/internal/prospects"
 	"github.com/wolfman30/medspa-ai-platform/internal/stories"
 	"github.com/wolfman30/medspa-ai-platform/migrations"
@@ -46,6 +47,7 @@ type DatabaseServices struct {
 	AuditSvc          *auditcompliance.AuditService
 	LeadsRepo         leads.Repository
 	MsgStore          *messaging.Store
+	Cipher            *pii.Cipher
 }
 
 // BootstrapDB connects to postgres, runs migrations, and initializes core DB-dependent services.
@@ -60,13 +62,23 @@ func BootstrapDB(ctx context.Context, cfg *appconfig.Config, logger *logging.Log
 	if sqlDB != nil {
 		audit = auditcompliance.NewAuditService(sqlDB)
 	}
+	cipher, err := appbootstrap.BuildPIICipher(cfg, logger)
+	if err != nil {
+		logger.Error("failed to configure pii encryption", "error", err)
+		os.Exit(1)
+	}
+	msgStore := messaging.NewStore(pool)
+	if msgStore != nil {
+		msgStore.SetCipher(cipher)
+	}
 	return DatabaseServices{
 		Pool:              pool,
 		SQLDB:             sqlDB,
 		ConversationStore: convStore,
 		AuditSvc:          audit,
-		LeadsRepo:         initializeLeadsRepository(pool),
-		MsgStore:          messaging.NewStore(pool),
+		LeadsRepo:         initializeLeadsRepository(pool, cipher),
+		MsgStore:          msgStore,
+		Cipher:            cipher,
 	}
 }
 
@@ -156,9 +168,11 @@ func SetupTelnyxClient(cfg *appconfig.Config, logger *logging.Logger) *telnyxcli
 	return client
 }
 
-func initializeLeadsRepository(dbPool *pgxpool.Pool) leads.Repository {
+func initializeLeadsRepository(dbPool *pgxpool.Pool, cipher *pii.Cipher) leads.Repository {
 	if dbPool != nil {
-		return leads.NewPostgresRepository(dbPool)
+		repo := leads.NewPostgresRepository(dbPool)
+		repo.SetCipher(cipher)
+		return repo
 	}
 	return leads.NewInMemoryRepository()
 }
@@ -174,7 +188,8 @@ type ConversationSetupDeps struct {
 
 // SetupConversation initializes the conversation infrastructure. When
 // USE_MEMORY_QUEUE is true, it uses an in-process queue with PostgreSQL job
-// persistence; otherwise it uses SQS + DynamoDB.
+// persistence (jobs that overflow the buffer are spilled to Postgres and
+// drained back in the background); otherwise it uses SQS + DynamoDB.
 func SetupConversation(deps ConversationSetupDeps) (*conversation.Publisher, conversation.JobRecorder, conversation.JobUpdater, *conversation.MemoryQueue) {
 	ctx := deps.Ctx
 	cfg := deps.Cfg
@@ -185,9 +200,14 @@ func SetupConversation(deps ConversationSetupDeps) (*conversation.Publisher, con
 			logger.Error("USE_MEMORY_QUEUE requires DATABASE_URL for job persistence")
 			os.Exit(1)
 		}
-		memoryQueue := conversation.NewMemoryQueue(1024)
 		pgStore := conversation.NewPGJobStore(dbPool)
+		memoryQueue := conversation.NewMemoryQueue(1024,
+			conversation.WithOverflowStore(pgStore),
+			conversation.WithMemoryQueueLogger(logger),
+		)
+		go memoryQueue.RunOverflowDrainer(ctx)
 		publisher := conversation.NewPublisher(memoryQueue, pgStore, logger)
+		publisher.SetScheduledJobStore(conversation.NewPGScheduledJobStore(dbPool))
 		return publisher, pgStore, pgStore, memoryQueue
 	}
 
@@ -201,16 +221,20 @@ func SetupConversation(deps ConversationSetupDeps) (*conversation.Publisher, con
 	dynamoClient := dynamodb.NewFromConfig(awsCfg)
 	store := conversation.NewJobStore(dynamoClient, cfg.ConversationJobsTable, logger)
 	publisher := conversation.NewPublisher(sqsQueue, store, logger)
+	if dbPool != nil {
+		publisher.SetScheduledJobStore(conversation.NewPGScheduledJobStore(dbPool))
+	}
 	return publisher, store, store, nil
 }
 
-// WaitForInlineWorker blocks until the inline conversation worker finishes or
-// a 30-second shutdown timeout elapses. No-op if inlineWorker is nil.
+// WaitForInlineWorker blocks until the inline conversation worker finishes
+// draining, allowing its drain timeout plus a few seconds to persist
+// unfinished jobs. No-op if inlineWorker is nil.
 func WaitForInlineWorker(inlineWorker *conversation.Worker, logger *logging.Logger) {
 	if inlineWorker == nil {
 		return
 	}
-	waitCtx, waitCancel := context.WithTimeout(context.Background(), 30*time.Second)
+	waitCtx, waitCancel := context.WithTimeout(context.Background(), inlineWorker.DrainTimeout()+5*time.Second)
 	defer waitCancel()
 
 	done := make(chan struct{})
@@ -4,13 +4,17 @@ import (
 	"encoding/json"
 	"strings"
 
+	"github.com/jackc/pgx/v5/pgxpool"
+
 	appbootstrap "github.com/wolfman30/medspa-ai-platform/internal/app/bootstrap"
 	"github.com/wolfman30/medspa-ai-platform/internal/clinic"
 	auditcompliance "github.com/wolfman30/medspa-ai-platform/internal/compliance"
 	appconfig "github.com/wolfman30/medspa-ai-platform/internal/config"
 	"github.com/wolfman30/medspa-ai-platform/internal/conversation"
+	"github.com/wolfman30/medspa-ai-platform/internal/inboundhealth"
 	"github.com/wolfman30/medspa-ai-platform/internal/leads"
 	"github.com/wolfman30/medspa-ai-platform/internal/messaging"
+	observemetrics "github.com/wolfman30/medspa-ai-platform/internal/observability/metrics"
 	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
 )
 
@@ -26,6 +30,12 @@ type MessagingDeps struct {
 	ConversationStore     *conversation.ConversationStore
 	SMSTranscriptStore    *conversation.SMSTranscriptStore
 	ClinicStore           *clinic.Store
+	MessagingMetrics      *observemetrics.MessagingMetrics
+	// DBPool backs per-number routes; nil routes from TWILIO_ORG_MAP_JSON only.
+	DBPool *pgxpool.Pool
+	// InboundHealth records inbound arrivals for the liveness check; nil
+	// skips recording.
+	InboundHealth *inboundhealth.Tracker
 }
 
 // MessagingBootstrap holds the assembled messaging handler, org resolver,
@@ -33,6 +43,9 @@ type MessagingDeps struct {
 type MessagingBootstrap struct {
 	MessagingHandler *messaging.Handler
 	Resolver         *messaging.StaticOrgResolver
+	// Router resolves numbers from phone_number_routes, falling back to Resolver.
+	Router           *messaging.RoutingOrgResolver
+	NumberRoutes     *messaging.NumberRouteStore
 	WebhookMessenger conversation.ReplyMessenger
 	MessengerReason  string
 }
@@ -55,10 +68,15 @@ func BootstrapMessaging(deps MessagingDeps) MessagingBootstrap {
 			logger.Warn("failed to parse TWILIO_ORG_MAP_JSON", "error", err)
 		}
 	}
-	if len(orgRouting) == 0 {
+	if len(orgRouting) == 0 && deps.DBPool == nil {
 		logger.Warn("TWILIO_ORG_MAP_JSON empty; SMS webhooks will be rejected unless numbers are configured")
 	}
 	resolver := messaging.NewStaticOrgResolver(orgRouting)
+	var numberRoutes *messaging.NumberRouteStore
+	if deps.DBPool != nil {
+		numberRoutes = messaging.NewNumberRouteStore(deps.DBPool)
+	}
+	router := messaging.NewRoutingOrgResolver(numberRoutes, resolver)
 	twilioWebhookSecret := cfg.TwilioWebhookSecret
 	if twilioWebhookSecret == "" {
 		twilioWebhookSecret = cfg.TwilioAuthToken
@@ -82,15 +100,26 @@ func BootstrapMessaging(deps MessagingDeps) MessagingBootstrap {
 			"reason", webhookMessengerReason,
 		)
 	}
-	messagingHandler := messaging.NewHandler(twilioWebhookSecret, conversationPublisher, resolver, webhookMessenger, leadsRepo, logger)
+	messagingHandler := messaging.NewHandler(twilioWebhookSecret, conversationPublisher, router, webhookMessenger, leadsRepo, logger)
 	messagingHandler.SetConversationStore(conversationStore)
 	messagingHandler.SetClinicStore(clinicStore)
 	messagingHandler.SetPublicBaseURL(cfg.PublicBaseURL)
 	messagingHandler.SetSkipSignature(cfg.TwilioSkipSignature)
+	messagingHandler.SetMessageStore(msgStore)
+	messagingHandler.SetMetrics(deps.MessagingMetrics)
+	// Twilio shares the Telnyx compliance copy and job tracking so both providers behave the same.
+	messagingHandler.SetComplianceAcks(cfg.TelnyxStopReply, cfg.TelnyxHelpReply, cfg.TelnyxStartReply)
+	messagingHandler.SetTrackJobs(cfg.TelnyxTrackJobs)
+	if auditSvc != nil {
+		messagingHandler.SetSensitiveDataAuditor(auditSvc)
+	}
+	if deps.InboundHealth != nil {
+		messagingHandler.SetInboundRecorder(deps.InboundHealth)
+	}
 
 	if cfg.TwilioSkipSignature && (cfg.Env == "production" || cfg.Env == "staging") {
 		logger.Error("SECURITY WARNING: TWILIO_SKIP_SIGNATURE is enabled in production/staging - this is a security risk!")
 	}
 
-	return MessagingBootstrap{MessagingHandler: messagingHandler, Resolver: resolver, WebhookMessenger: webhookMessenger, MessengerReason: webhookMessengerReason}
+	return MessagingBootstrap{MessagingHandler: messagingHandler, Resolver: resolver, Router: router, NumberRoutes: numberRoutes, WebhookMessenger: webhookMessenger, MessengerReason: webhookMessengerReason}
 }
@@ -24,6 +24,9 @@ type PaymentsBootstrap struct {
 	FakePaymentsHandler  *payments.FakePaymentsHandler
 	StripeWebhookHandler *payments.StripeWebhookHandler
 	StripeConnectHandler *payments.StripeConnectHandler
+	// ReconciliationHandler serves Square payment reconciliation reports; nil
+	// unless Square OAuth and Redis are configured.
+	ReconciliationHandler *payments.ReconciliationHandler
 }
 
 // PaymentsDeps holds dependencies for initializing payment processing
@@ -37,6 +40,7 @@ type PaymentsDeps struct {
 	RedisClient           *redis.Client
 	OutboxStore           *events.OutboxStore
 	ProcessedStore        *events.ProcessedStore
+	WebhookLog            *events.WebhookLog
 	Resolver              payments.OrgNumberResolver
 	PaymentsRepo          *payments.Repository
 	ClinicStore           *clinic.Store
@@ -63,6 +67,7 @@ func BootstrapPayments(deps PaymentsDeps) PaymentsBootstrap {
 	var squareWebhookHandler *payments.SquareWebhookHandler
 	var squareOAuthHandler *payments.OAuthHandler
 	var fakePaymentsHandler *payments.FakePaymentsHandler
+	var reconciliationHandler *payments.ReconciliationHandler
 	if paymentsRepo != nil && processedStore != nil && outboxStore != nil {
 		var oauthSvc *payments.SquareOAuthService
 		var numberResolver payments.OrgNumberResolver = resolver
@@ -121,6 +126,23 @@ func BootstrapPayments(deps PaymentsDeps) PaymentsBootstrap {
 		}
 
 		squareWebhookHandler = payments.NewSquareWebhookHandler(cfg.SquareWebhookKey, paymentsRepo, leadsRepo, processedStore, outboxStore, numberResolver, orderClient, logger)
+		if deps.WebhookLog != nil {
+			squareWebhookHandler.SetEventLog(deps.WebhookLog)
+		}
+		if oauthSvc != nil && redisClient != nil {
+			reports := payments.NewReconciliationStore(redisClient)
+			reconciliationHandler = payments.NewReconciliationHandler(reports, logger)
+			if cfg.PaymentReconcileInterval > 0 {
+				squareBaseURL := strings.TrimSpace(cfg.SquareBaseURL)
+				if squareBaseURL == "" && cfg.SquareSandbox {
+					squareBaseURL = "https://connect.squareupsandbox.com"
+				}
+				reconciler := payments.NewReconciler(oauthSvc, payments.NewSquarePaymentsClient(squareBaseURL, logger), paymentsRepo, squareWebhookHandler, reports, logger).
+					WithInterval(cfg.PaymentReconcileInterval).
+					WithLookback(cfg.PaymentReconcileLookback)
+				go reconciler.Start(appCtx)
+			}
+		}
 		dispatcher := conversation.NewOutboxDispatcher(conversationPublisher)
 		deliverer := events.NewDeliverer(outboxStore, dispatcher, logger)
 		go deliverer.Start(appCtx)
@@ -159,5 +181,7 @@ func BootstrapPayments(deps PaymentsDeps) PaymentsBootstrap {
 		FakePaymentsHandler:  fakePaymentsHandler,
 		StripeWebhookHandler: stripeWebhookHandler,
 		StripeConnectHandler: stripeConnectHandler,
+
+		ReconciliationHandler: reconciliationHandler,
 	}
 }
@@ -1,11 +1,17 @@
 package bootstrap
 
 import (
+	"github.com/redis/go-redis/v9"
+
+	appbootstrap "github.com/wolfman30/medspa-ai-platform/internal/app/bootstrap"
+	"github.com/wolfman30/medspa-ai-platform/internal/broadcasts"
 	"github.com/wolfman30/medspa-ai-platform/internal/clinic"
+	auditcompliance "github.com/wolfman30/medspa-ai-platform/internal/compliance"
 	appconfig "github.com/wolfman30/medspa-ai-platform/internal/config"
 	"github.com/wolfman30/medspa-ai-platform/internal/conversation"
 	"github.com/wolfman30/medspa-ai-platform/internal/events"
 	"github.com/wolfman30/medspa-ai-platform/internal/http/handlers"
+	"github.com/wolfman30/medspa-ai-platform/internal/inboundhealth"
 	"github.com/wolfman30/medspa-ai-platform/internal/leads"
 	"github.com/wolfman30/medspa-ai-platform/internal/messaging"
 	"github.com/wolfman30/medspa-ai-platform/internal/messaging/compliance"
@@ -20,6 +26,7 @@ type AdminMessagingDeps struct {
 	MessageStore     *messaging.Store
 	TelnyxClient     *telnyxclient.Client
 	MessagingMetrics *observemetrics.MessagingMetrics
+	LeadsRepo        leads.Repository // optional; checks marketing consent
 }
 
 // BuildAdminMessagingHandler creates the admin messaging handler with quiet hours.
@@ -39,6 +46,11 @@ func BuildAdminMessagingHandler(deps AdminMessagingDeps) *handlers.AdminMessagin
 		}
 	}
 
+	var consent compliance.MarketingConsentChecker
+	if checker, ok := deps.LeadsRepo.(compliance.MarketingConsentChecker); ok {
+		consent = checker
+	}
+
 	return handlers.NewAdminMessagingHandler(handlers.AdminMessagingConfig{
 		Store:             deps.MessageStore,
 		Logger:            deps.Logger,
@@ -50,6 +62,8 @@ func BuildAdminMessagingHandler(deps AdminMessagingDeps) *handlers.AdminMessagin
 		HelpAck:           deps.Cfg.TelnyxHelpReply,
 		RetryBaseDelay:    deps.Cfg.TelnyxRetryBaseDelay,
 		Metrics:           deps.MessagingMetrics,
+		Consent:           consent,
+		Provisioning:      deps.MessageStore.Provisioning(),
 	})
 }
 
@@ -60,12 +74,24 @@ type TelnyxWebhookDeps struct {
 	MsgStore          *messaging.Store
 	TelnyxClient      *telnyxclient.Client
 	ProcessedStore    *events.ProcessedStore
+	WebhookLog        *events.WebhookLog
 	ConversationPub   *conversation.Publisher
 	LeadsRepo         leads.Repository
 	SMSTranscript     *conversation.SMSTranscriptStore
 	ConversationStore *conversation.ConversationStore
 	ClinicStore       *clinic.Store
 	MessagingMetrics  *observemetrics.MessagingMetrics
+	// Redis backs the multi-part message buffer; nil dispatches parts as-is.
+	Redis *redis.Client
+	// NumberRoutes attaches per-number routing metadata to conversation jobs.
+	NumberRoutes messaging.OrgResolver
+	// Broadcasts tags replies to a recent broadcast; nil skips tagging.
+	Broadcasts *broadcasts.Store
+	// Audit records card numbers and SSNs redacted from inbound messages.
+	Audit *auditcompliance.AuditService
+	// InboundHealth records inbound arrivals for the liveness check; nil
+	// skips recording.
+	InboundHealth *inboundhealth.Tracker
 }
 
 // BuildTelnyxWebhookHandler creates the Telnyx inbound webhook handler.
@@ -75,6 +101,33 @@ func BuildTelnyxWebhookHandler(deps TelnyxWebhookDeps) *handlers.TelnyxWebhookHa
 		return nil
 	}
 
+	var eventLog events.WebhookRecorder
+	if deps.WebhookLog != nil {
+		eventLog = deps.WebhookLog
+	}
+	var concat *handlers.InboundConcatBuffer
+	if deps.Redis != nil && deps.Cfg.TelnyxConcatWindow > 0 {
+		concat = handlers.NewInboundConcatBuffer(deps.Redis, deps.Cfg.TelnyxConcatWindow, deps.MessagingMetrics, deps.Logger)
+	}
+	var broadcastReplies handlers.BroadcastReplySource
+	if deps.Broadcasts != nil {
+		broadcastReplies = deps.Broadcasts
+	}
+	var audit messaging.SensitiveDataAuditor
+	if deps.Audit != nil {
+		audit = deps.Audit
+	}
+	var liveness messaging.InboundRecorder
+	if deps.InboundHealth != nil {
+		liveness = deps.InboundHealth
+	}
+	// Carrier blocks only show up on delivery receipts, so the webhook
+	// re-sends those messages through the secondary provider.
+	var failover handlers.DeliveryFailover
+	replies, _, _ := messaging.BuildReplyMessenger(appbootstrap.ProviderSelection(deps.Cfg, deps.Logger), deps.Logger)
+	if f := messaging.NewDeliveryFailover(replies, deps.MsgStore, deps.Logger); f != nil {
+		failover = f
+	}
 	h := handlers.NewTelnyxWebhookHandler(handlers.TelnyxWebhookConfig{
 		Store:             deps.MsgStore,
 		Processed:         deps.ProcessedStore,
@@ -94,6 +147,14 @@ func BuildTelnyxWebhookHandler(deps TelnyxWebhookDeps) *handlers.TelnyxWebhookHa
 		DemoMode:          deps.Cfg.DemoMode,
 		TrackJobs:         deps.Cfg.TelnyxTrackJobs,
 		Metrics:           deps.MessagingMetrics,
+		EventLog:          eventLog,
+		ConcatBuffer:      concat,
+		NumberRoutes:      deps.NumberRoutes,
+		Provisioning:      deps.MsgStore.Provisioning(),
+		Broadcasts:        broadcastReplies,
+		Audit:             audit,
+		Liveness:          liveness,
+		Failover:          failover,
 	})
 	deps.Logger.Info("telnyx webhook handler initialized", "profile_id", deps.Cfg.TelnyxMessagingProfileID)
 	return h
@@ -3,15 +3,16 @@ package bootstrap
 import (
 	"context"
 	"database/sql"
+	"fmt"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/service/sesv2"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/redis/go-redis/v9"
 	"github.com/wolfman30/medspa-ai-platform/cmd/mainconfig"
 	appbootstrap "github.com/wolfman30/medspa-ai-platform/internal/app/bootstrap"
-	"github.com/wolfman30/medspa-ai-platform/internal/bookings"
 	"github.com/wolfman30/medspa-ai-platform/internal/clinic"
 	"github.com/wolfman30/medspa-ai-platform/internal/clinicdata"
 	auditcompliance "github.com/wolfman30/medspa-ai-platform/internal/compliance"
@@ -22,6 +23,7 @@ import (
 	"github.com/wolfman30/medspa-ai-platform/internal/leads"
 	"github.com/wolfman30/medspa-ai-platform/internal/notify"
 	"github.com/wolfman30/medspa-ai-platform/internal/payments"
+	"github.com/wolfman30/medspa-ai-platform/internal/pii"
 	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
 )
 
@@ -29,6 +31,7 @@ import (
 type DepositPipeline struct {
 	Sender    conversation.DepositSender
 	Preloader *conversation.DepositPreloader
+	Claims    conversation.PaymentClaimChecker
 }
 
 // ConversationWorkerAssembler groups shared dependencies for inline worker assembly.
@@ -49,6 +52,7 @@ type ConversationWorkerAssembler struct {
 	paymentRepo   *payments.Repository
 	clinicStore   *clinic.Store
 	convStore     *conversation.ConversationStore
+	report        *appbootstrap.StartupReport
 }
 
 // ConversationWorkerAssemblerDeps holds the dependencies needed to create
@@ -59,6 +63,8 @@ type ConversationWorkerAssemblerDeps struct {
 	PaymentRepo       *payments.Repository
 	ClinicStore       *clinic.Store
 	ConversationStore *conversation.ConversationStore
+	// Report receives the deposit sender and notifier the assembler builds.
+	Report *appbootstrap.StartupReport
 }
 
 // NewConversationWorkerAssembler creates an assembler that lazily builds
@@ -82,12 +88,13 @@ func NewConversationWorkerAssembler(deps ConversationWorkerAssemblerDeps) *Conve
 		paymentRepo:   deps.PaymentRepo,
 		clinicStore:   deps.ClinicStore,
 		convStore:     deps.ConversationStore,
+		report:        deps.Report,
 	}
 }
 
 // buildDepositSender selects the correct payment provider (Fake, Stripe-only,
 // Square, or Multi) and wires the deposit dispatcher.
-func (a *ConversationWorkerAssembler) buildDepositSender() DepositPipeline {
+func (a *ConversationWorkerAssembler) buildDepositSender(notifier conversation.PaymentNotifier) DepositPipeline {
 	if a.dbPool == nil || a.outboxStore == nil || a.paymentRepo == nil {
 		a.logger.Warn("deposit sender NOT initialized — missing prerequisites")
 		return DepositPipeline{}
@@ -103,7 +110,7 @@ func (a *ConversationWorkerAssembler) buildDepositSender() DepositPipeline {
 		fakeSvc := payments.NewFakeCheckoutService(a.cfg.PublicBaseURL, a.logger)
 		a.logger.Warn("deposit sender initialized in fake payments mode")
 		return DepositPipeline{
-			Sender: conversation.NewDepositDispatcher(a.paymentRepo, fakeSvc, a.outboxStore, a.messenger, numberResolver, a.leadsRepo, a.smsTranscript, a.convStore, a.logger, conversation.WithShortURLs(a.paymentRepo, a.cfg.PublicBaseURL)),
+			Sender: conversation.NewDepositDispatcher(a.paymentRepo, fakeSvc, a.outboxStore, a.messenger, numberResolver, a.leadsRepo, a.smsTranscript, a.convStore, a.logger, a.depositOptions(notifier)...),
 		}
 	}
 
@@ -112,7 +119,7 @@ func (a *ConversationWorkerAssembler) buildDepositSender() DepositPipeline {
 		stripeSvc := payments.NewStripeCheckoutService(a.cfg.StripeSecretKey, a.cfg.StripeSuccessURL, a.cfg.StripeCancelURL, a.logger)
 		a.logger.Info("deposit sender initialized (stripe only)")
 		return DepositPipeline{
-			Sender: conversation.NewDepositDispatcher(a.paymentRepo, stripeSvc, a.outboxStore, a.messenger, numberResolver, a.leadsRepo, a.smsTranscript, a.convStore, a.logger, conversation.WithShortURLs(a.paymentRepo, a.cfg.PublicBaseURL)),
+			Sender: conversation.NewDepositDispatcher(a.paymentRepo, stripeSvc, a.outboxStore, a.messenger, numberResolver, a.leadsRepo, a.smsTranscript, a.convStore, a.logger, a.depositOptions(notifier)...),
 		}
 	}
 
@@ -123,11 +130,23 @@ func (a *ConversationWorkerAssembler) buildDepositSender() DepositPipeline {
 	}
 
 	// Square (possibly with Stripe multi-checkout)
-	return a.buildSquareDepositSender(numberResolver)
+	return a.buildSquareDepositSender(numberResolver, notifier)
+}
+
+// depositOptions returns the dispatcher options shared by every payment
+// provider. Operators hear about no-deposit bookings through notifier.
+func (a *ConversationWorkerAssembler) depositOptions(notifier conversation.PaymentNotifier) []conversation.DepositOption {
+	opts := []conversation.DepositOption{conversation.WithShortURLs(payments.NewShortLinkStore(a.dbPool), a.cfg.PaymentLinkBaseURL())}
+	if a.clinicStore != nil {
+		opts = append(opts, conversation.WithDepositPolicies(a.clinicStore))
+	}
+	requests, _ := notifier.(conversation.BookingHandoffNotifier)
+	opts = append(opts, conversation.WithBookingRequests(events.NewProcessedStore(a.dbPool), requests))
+	return opts
 }
 
 // buildSquareDepositSender handles the Square + optional Stripe multi-checkout path.
-func (a *ConversationWorkerAssembler) buildSquareDepositSender(numberResolver payments.OrgNumberResolver) DepositPipeline {
+func (a *ConversationWorkerAssembler) buildSquareDepositSender(numberResolver payments.OrgNumberResolver, notifier conversation.PaymentNotifier) DepositPipeline {
 	usePaymentLinks := payments.UsePaymentLinks(a.cfg.SquareCheckoutMode, a.cfg.SquareSandbox)
 	squareSvc := payments.NewSquareCheckoutService(a.cfg.SquareAccessToken, a.cfg.SquareLocationID, a.cfg.SquareSuccessURL, a.cfg.SquareCancelURL, a.logger).
 		WithBaseURL(a.cfg.SquareBaseURL).
@@ -161,8 +180,9 @@ func (a *ConversationWorkerAssembler) buildSquareDepositSender(numberResolver pa
 	a.logger.Info("deposit sender initialized", "square_location_id", a.cfg.SquareLocationID)
 
 	return DepositPipeline{
-		Sender:    conversation.NewDepositDispatcher(a.paymentRepo, checkoutSvc, a.outboxStore, a.messenger, numberResolver, a.leadsRepo, a.smsTranscript, a.convStore, a.logger, conversation.WithShortURLs(a.paymentRepo, a.cfg.PublicBaseURL)),
+		Sender:    conversation.NewDepositDispatcher(a.paymentRepo, checkoutSvc, a.outboxStore, a.messenger, numberResolver, a.leadsRepo, a.smsTranscript, a.convStore, a.logger, a.depositOptions(notifier)...),
 		Preloader: preloader,
+		Claims:    appbootstrap.BuildPaymentClaimChecker(a.dbPool, a.paymentRepo, a.leadsRepo, squareSvc, numberResolver, a.logger),
 	}
 }
 
@@ -177,6 +197,11 @@ func (a *ConversationWorkerAssembler) buildNotificationService() conversation.Pa
 	smsSender := a.buildSMSSender()
 
 	notifier := notify.NewService(emailSender, smsSender, a.clinicStore, a.leadsRepo, a.logger)
+	var transcripts notify.TranscriptSource
+	if a.smsTranscript != nil {
+		transcripts = a.smsTranscript
+	}
+	notifier.SetWebhooks(notify.NewWebhookNotifier(nil, a.logger), transcripts)
 	a.logger.Info("notification service initialized for inline workers")
 	return notifier
 }
@@ -252,10 +277,32 @@ func (a *ConversationWorkerAssembler) buildAutoPurger() conversation.SandboxAuto
 	}, a.logger)
 }
 
+// reportWorkerComponents records whether the deposit sender and operator
+// notifier were built, so a nil one is visible in the startup report.
+func (a *ConversationWorkerAssembler) reportWorkerComponents(deposit DepositPipeline, notifier conversation.PaymentNotifier) {
+	checkout := appbootstrap.DepositCheckoutCapability(a.cfg, a.dbPool != nil)
+	switch {
+	case deposit.Sender != nil:
+		a.report.Add(appbootstrap.StackPayments, appbootstrap.Enabled("deposit_sender", checkout.Reason))
+	case !checkout.Enabled:
+		a.report.Add(appbootstrap.StackPayments, appbootstrap.Disabled("deposit_sender", checkout.Reason))
+	default:
+		a.report.Add(appbootstrap.StackPayments, appbootstrap.Disabled("deposit_sender", "missing outbox or payments store"))
+	}
+	if notifier != nil {
+		a.report.Add(appbootstrap.StackNotifications, appbootstrap.Enabled("operator_notifications", "email and sms service"))
+	} else {
+		a.report.Add(appbootstrap.StackNotifications, appbootstrap.Disabled("operator_notifications", "redis not configured"))
+	}
+}
+
 // buildConversationWorkerOptions assembles the worker option list.
 func (a *ConversationWorkerAssembler) buildConversationWorkerOptions() []conversation.WorkerOption {
-	deposit := a.buildDepositSender()
 	notifier := a.buildNotificationService()
+	deposit := a.buildDepositSender(notifier)
+	a.reportWorkerComponents(deposit, notifier)
+	digests, _ := notifier.(payments.DailyDigestNotifier)
+	appbootstrap.StartBookingOutcomePoller(a.ctx, a.cfg, a.dbPool, a.clinicStore, a.leadsRepo, digests, a.logger)
 	autoPurger := a.buildAutoPurger()
 	var processedStore *events.ProcessedStore
 	if a.dbPool != nil {
@@ -267,6 +314,15 @@ func (a *ConversationWorkerAssembler) buildConversationWorkerOptions() []convers
 			msgChecker = checker
 		}
 	}
+	var callbackTasks conversation.CallbackTaskStore
+	var writebacks conversation.WritebackAttemptStore
+	var turnTimings conversation.TurnTimingStore
+	if a.dbPool != nil {
+		callbackTasks = conversation.NewPGCallbackTaskStore(a.dbPool)
+		writebacks = conversation.NewPGWritebackAttemptStore(a.dbPool)
+		turnTimings = conversation.NewPGTurnTimingStore(a.dbPool)
+	}
+	callbackNotifier, _ := notifier.(conversation.CallbackNotifier)
 	moxieDryRun := os.Getenv("MOXIE_DRY_RUN") == "true"
 	moxieAPIClient := moxieclient.NewClient(a.logger, moxieclient.WithDryRun(moxieDryRun))
 	if moxieDryRun {
@@ -275,8 +331,13 @@ func (a *ConversationWorkerAssembler) buildConversationWorkerOptions() []convers
 
 	return []conversation.WorkerOption{
 		conversation.WithWorkerCount(a.cfg.WorkerCount),
+		conversation.WithOrgConcurrency(a.cfg.WorkerOrgConcurrency, 0),
+		conversation.WithDrainTimeout(a.cfg.WorkerDrainTimeout),
+		conversation.WithInboundBatcher(conversation.NewInboundBatcher(a.redisClient, a.cfg.InboundBatchWindow)),
+		conversation.WithConversationLocker(conversation.NewConversationLocker(a.redisClient, a.cfg.ConversationLockTTL, a.cfg.ConversationLockWait)),
 		conversation.WithDepositSender(deposit.Sender),
 		conversation.WithDepositPreloader(deposit.Preloader),
+		conversation.WithPaymentClaimChecker(deposit.Claims),
 		conversation.WithPaymentNotifier(notifier),
 		conversation.WithSandboxAutoPurger(autoPurger),
 		conversation.WithProcessedEventsStore(processedStore),
@@ -289,6 +350,12 @@ func (a *ConversationWorkerAssembler) buildConversationWorkerOptions() []convers
 		conversation.WithSupervisorMode(conversation.ParseSupervisorMode(a.cfg.SupervisorMode)),
 		conversation.WithWorkerLeadsRepo(a.leadsRepo),
 		conversation.WithWorkerMoxieClient(moxieAPIClient),
+		conversation.WithEMRWritebackAttempts(writebacks),
+		conversation.WithCallbackTasks(callbackTasks, callbackNotifier),
+		conversation.WithReengagementQuietHours(a.cfg.QuietHoursStart, a.cfg.QuietHoursEnd),
+		conversation.WithReplyLatencyBudget(a.cfg.ReplyLatencyBudget),
+		conversation.WithProgressGating(a.cfg.ProgressInitialDelay, a.cfg.ProgressMinInterval),
+		conversation.WithTurnTimings(turnTimings),
 	}
 }
 
@@ -301,48 +368,44 @@ type InlineWorkerDeps struct {
 	MessengerNote string
 	JobUpdater    conversation.JobUpdater
 	MemoryQueue   *conversation.MemoryQueue
+	Publisher     *conversation.Publisher
 	DBPool        *pgxpool.Pool
 	SQLDB         *sql.DB
 	Audit         *auditcompliance.AuditService
 	OutboxStore   *events.OutboxStore
 	Resolver      payments.OrgNumberResolver
 	OptOutChecker conversation.OptOutChecker
+	Replies       conversation.ReplyLookup
 	Supervisor    conversation.Supervisor
 	RedisClient   *redis.Client
 	SMSTranscript *conversation.SMSTranscriptStore
 }
 
 // SetupInlineWorker builds and starts the in-process conversation worker.
-func SetupInlineWorker(deps InlineWorkerDeps) (*conversation.Worker, conversation.Service) {
+// The startup report lists what it enabled; without USE_MEMORY_QUEUE it
+// only notes that conversations run in the worker service.
+func SetupInlineWorker(deps InlineWorkerDeps) (*conversation.Worker, conversation.Service, *appbootstrap.StartupReport) {
 	cfg := deps.Cfg
 	logger := deps.Logger
 
 	if !cfg.UseMemoryQueue || deps.MemoryQueue == nil {
-		return nil, nil
-	}
-
-	leadsRepo := initializeLeadsRepository(deps.DBPool)
-	var paymentChecker *payments.Repository
-	if deps.DBPool != nil {
-		paymentChecker = payments.NewRepository(deps.DBPool, deps.RedisClient)
+		report := appbootstrap.NewStartupReport("api", time.Now())
+		report.Add(appbootstrap.StackWorker, appbootstrap.Disabled("inline_worker", "USE_MEMORY_QUEUE=false; conversations are processed by the worker service"))
+		return nil, nil, report
 	}
 
-	processor, err := appbootstrap.BuildConversationService(deps.Ctx, cfg, leadsRepo, paymentChecker, deps.Audit, logger)
+	convDeps := appbootstrap.NewConversationDeps(cfg, deps.DBPool, deps.RedisClient, pii.Default(), deps.Audit, logger)
+	leadsRepo, paymentChecker, bookingBridge, crmEvents := convDeps.LeadsRepo, convDeps.Payments, convDeps.Bookings, convDeps.CRMEvents
+	assembly, err := appbootstrap.AssembleConversation(deps.Ctx, "api", convDeps)
 	if err != nil {
 		logger.Error("failed to configure inline conversation service", "error", err)
 		os.Exit(1)
 	}
+	processor, report := assembly.Processor, assembly.Report
 
 	if deps.Messenger == nil {
 		logger.Warn("SMS replies disabled for inline workers", "reason", deps.MessengerNote)
-	}
-
-	var bookingBridge conversation.BookingServiceAdapter
-	if deps.DBPool != nil {
-		repo := bookings.NewRepository(deps.DBPool)
-		bookingBridge = conversation.BookingServiceAdapter{
-			Service: bookings.NewService(repo, logger),
-		}
+		report.Add(appbootstrap.StackNotifications, appbootstrap.Disabled("operator_sms", "no outbound messenger: "+deps.MessengerNote))
 	}
 
 	var clinicStore *clinic.Store
@@ -361,12 +424,32 @@ func SetupInlineWorker(deps InlineWorkerDeps) (*conversation.Worker, conversatio
 		PaymentRepo:       paymentChecker,
 		ClinicStore:       clinicStore,
 		ConversationStore: convStore,
+		Report:            report,
 	})
 
 	workerOpts := assembler.buildConversationWorkerOptions()
+	var frustrationAuditor conversation.FrustrationAuditor
+	if deps.Audit != nil {
+		frustrationAuditor = deps.Audit
+	}
+	workerOpts = append(workerOpts, conversation.WithFrustrationTracking(
+		appbootstrap.BuildFrustrationTracker(cfg, deps.RedisClient, processor, logger), frustrationAuditor),
+		conversation.WithWorkerCRMEvents(crmEvents))
+	if deps.DBPool != nil && deps.Publisher != nil {
+		scheduler := conversation.NewScheduler(conversation.NewPGScheduledJobStore(deps.DBPool), deps.Publisher, logger)
+		workerOpts = append(workerOpts, conversation.WithScheduler(scheduler))
+		if lister, ok := deps.JobUpdater.(conversation.StaleJobLister); ok {
+			reaper := conversation.NewJobReaper(lister, deps.JobUpdater, deps.Publisher, logger)
+			reaper.SetReplyLookup(deps.Replies)
+			reaper.SetStaleAfter(cfg.ConversationJobStaleAfter)
+			workerOpts = append(workerOpts, conversation.WithJobReaper(reaper))
+		}
+	}
 
 	worker := conversation.NewWorker(processor, deps.MemoryQueue, deps.JobUpdater, deps.Messenger, bookingBridge, logger, workerOpts...)
 	worker.Start(deps.Ctx)
 	logger.Info("inline conversation workers started", "count", cfg.WorkerCount)
-	return worker, processor
+	report.Add(appbootstrap.StackWorker, appbootstrap.Enabled("inline_worker", fmt.Sprintf("%d workers", cfg.WorkerCount)))
+	report.Log(logger)
+	return worker, processor, report
 }
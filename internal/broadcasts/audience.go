@@ -0,0 +1,107 @@
+package broadcasts
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/messaging"
+	"github.com/wolfman30/medspa-ai-platform/internal/messaging/compliance"
+	"github.com/wolfman30/medspa-ai-platform/internal/messaging/templates"
+)
+
+// optOutFooter is appended to a broadcast that doesn't tell the recipient
+// how to opt out.
+const optOutFooter = "Reply STOP to opt out."
+
+// CandidateSource lists the leads matching a segment.
+type CandidateSource interface {
+	Candidates(ctx context.Context, orgID string, seg Segment) ([]Candidate, error)
+}
+
+// OptOutChecker reports whether a recipient unsubscribed from a clinic.
+type OptOutChecker interface {
+	IsUnsubscribed(ctx context.Context, clinicID uuid.UUID, recipient string) (bool, error)
+}
+
+// Audience is the segment's leads that can receive a broadcast, with counts
+// of those excluded by reason.
+type Audience struct {
+	Recipients []Candidate
+	Excluded   map[string]int
+}
+
+// ResolveAudience queries the segment and drops opted-out recipients, those
+// without marketing consent, and repeat phones. Dry runs and executions both
+// use it, so a dry-run count is what an execution would queue.
+func ResolveAudience(ctx context.Context, source CandidateSource, optOut OptOutChecker, consent compliance.MarketingConsentChecker, orgID string, seg Segment) (Audience, error) {
+	clinicID, err := uuid.Parse(orgID)
+	if err != nil {
+		return Audience{}, fmt.Errorf("broadcasts: invalid org id: %w", err)
+	}
+	candidates, err := source.Candidates(ctx, orgID, seg)
+	if err != nil {
+		return Audience{}, err
+	}
+	audience := Audience{Recipients: []Candidate{}, Excluded: map[string]int{}}
+	seen := make(map[string]bool, len(candidates))
+	for _, c := range candidates {
+		c.Phone = messaging.NormalizeE164(c.Phone)
+		if seen[c.Phone] {
+			audience.Excluded[ReasonDuplicatePhone]++
+			continue
+		}
+		seen[c.Phone] = true
+		if optOut != nil {
+			unsub, err := optOut.IsUnsubscribed(ctx, clinicID, c.Phone)
+			if err != nil {
+				return Audience{}, fmt.Errorf("broadcasts: check opt-out: %w", err)
+			}
+			if unsub {
+				audience.Excluded[ReasonOptOut]++
+				continue
+			}
+		}
+		err := compliance.RequireMarketingConsent(ctx, consent, compliance.PurposeMarketing, orgID, c.Phone)
+		if errors.Is(err, compliance.ErrNoMarketingConsent) {
+			audience.Excluded[ReasonNoMarketingConsent]++
+			continue
+		}
+		if err != nil {
+			return Audience{}, fmt.Errorf("broadcasts: %w", err)
+		}
+		audience.Recipients = append(audience.Recipients, c)
+	}
+	return audience, nil
+}
+
+// RenderMessage renders a broadcast template for one recipient. Templates
+// may use {{.first_name}}; an opt-out line is added when the template has
+// none.
+func RenderMessage(tmpl string, c Candidate) (string, error) {
+	body, err := templates.Renderer{}.Render("broadcast", tmpl, map[string]string{"first_name": c.FirstName()})
+	if err != nil {
+		return "", fmt.Errorf("broadcasts: render message: %w", err)
+	}
+	body = strings.TrimSpace(body)
+	if !strings.Contains(strings.ToUpper(body), "STOP") {
+		body += "\n\n" + optOutFooter
+	}
+	return body, nil
+}
+
+// Render renders the template for each of the audience's recipients.
+func (a Audience) Render(tmpl string) ([]NewRecipient, error) {
+	recipients := make([]NewRecipient, 0, len(a.Recipients))
+	for _, c := range a.Recipients {
+		body, err := RenderMessage(tmpl, c)
+		if err != nil {
+			return nil, err
+		}
+		recipients = append(recipients, NewRecipient{LeadID: c.LeadID, Phone: c.Phone, Body: body})
+	}
+	return recipients, nil
+}
@@ -0,0 +1,84 @@
+package broadcasts
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/tenancy"
+)
+
+type stubCandidates struct {
+	candidates []Candidate
+}
+
+func (s stubCandidates) Candidates(ctx context.Context, orgID string, seg Segment) ([]Candidate, error) {
+	return s.candidates, nil
+}
+
+type stubOptOut map[string]bool
+
+func (s stubOptOut) IsUnsubscribed(ctx context.Context, clinicID uuid.UUID, recipient string) (bool, error) {
+	return s[recipient], nil
+}
+
+type stubConsent map[string]bool
+
+func (s stubConsent) HasMarketingConsent(ctx context.Context, scope tenancy.OrgScope, phone string) (bool, error) {
+	return s[phone], nil
+}
+
+func TestResolveAudienceExcludesOptOutsAndNonConsenting(t *testing.T) {
+	source := stubCandidates{candidates: []Candidate{
+		{LeadID: "1", Phone: "+15550000001", Name: "Ana"},
+		{LeadID: "2", Phone: "+15550000002", Name: "Ben"},
+		{LeadID: "3", Phone: "+15550000003", Name: "Cy"},
+		{LeadID: "4", Phone: "15550000001", Name: "Ana"},
+	}}
+	optOut := stubOptOut{"+15550000002": true}
+	consent := stubConsent{"+15550000001": true, "+15550000002": true}
+
+	audience, err := ResolveAudience(context.Background(), source, optOut, consent, uuid.NewString(), Segment{})
+	if err != nil {
+		t.Fatalf("ResolveAudience: %v", err)
+	}
+	if len(audience.Recipients) != 1 || audience.Recipients[0].LeadID != "1" {
+		t.Fatalf("recipients = %+v, want only lead 1", audience.Recipients)
+	}
+	want := map[string]int{ReasonOptOut: 1, ReasonNoMarketingConsent: 1, ReasonDuplicatePhone: 1}
+	for reason, n := range want {
+		if audience.Excluded[reason] != n {
+			t.Fatalf("excluded = %v, want %v", audience.Excluded, want)
+		}
+	}
+}
+
+func TestResolveAudienceWithoutConsentCheckerExcludesEveryone(t *testing.T) {
+	source := stubCandidates{candidates: []Candidate{{LeadID: "1", Phone: "+15550000001"}}}
+	audience, err := ResolveAudience(context.Background(), source, nil, nil, uuid.NewString(), Segment{})
+	if err != nil {
+		t.Fatalf("ResolveAudience: %v", err)
+	}
+	if len(audience.Recipients) != 0 || audience.Excluded[ReasonNoMarketingConsent] != 1 {
+		t.Fatalf("expected marketing to fail closed, got %+v", audience)
+	}
+}
+
+func TestRenderMessage(t *testing.T) {
+	body, err := RenderMessage("Hi {{.first_name}}, we have 3 openings Friday!", Candidate{Name: "Jane Doe"})
+	if err != nil {
+		t.Fatalf("RenderMessage: %v", err)
+	}
+	if !strings.HasPrefix(body, "Hi Jane, we have") || !strings.HasSuffix(body, optOutFooter) {
+		t.Fatalf("body = %q", body)
+	}
+	body, err = RenderMessage("Openings Friday. Reply STOP to end.", Candidate{})
+	if err != nil || strings.Contains(body, optOutFooter) {
+		t.Fatalf("expected existing opt-out line kept as-is, got %q, %v", body, err)
+	}
+	if _, err := RenderMessage("Hi {{.name}}", Candidate{}); err == nil {
+		t.Fatal("expected unknown template field to fail")
+	}
+}
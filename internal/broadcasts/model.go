@@ -0,0 +1,144 @@
+// Package broadcasts sends admin-triggered messages to a filtered segment of
+// a clinic's leads, e.g. "we have 3 openings Friday" to Botox leads from the
+// last 60 days who never booked. Recipients are resolved when a broadcast is
+// created; the messaging worker sends them within compliance limits.
+package broadcasts
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Booking status filters for a segment.
+const (
+	BookingStatusAny       = ""
+	BookingStatusBooked    = "booked"
+	BookingStatusNotBooked = "not_booked"
+)
+
+// Broadcast statuses.
+const (
+	StatusSending   = "sending"
+	StatusCompleted = "completed"
+)
+
+// Recipient statuses. A recipient is pending until the worker claims it,
+// then sent, suppressed (compliance blocked it at send time) or failed.
+const (
+	RecipientPending    = "pending"
+	RecipientSending    = "sending"
+	RecipientSent       = "sent"
+	RecipientSuppressed = "suppressed"
+	RecipientFailed     = "failed"
+)
+
+// Exclusion and suppression reasons.
+const (
+	ReasonOptOut              = "opt_out"
+	ReasonNoMarketingConsent  = "no_marketing_consent"
+	ReasonCampaignNotApproved = "campaign_not_approved"
+	ReasonDuplicatePhone      = "duplicate_phone"
+)
+
+var (
+	// ErrNotFound is returned when a broadcast doesn't exist for the org.
+	ErrNotFound = errors.New("broadcasts: broadcast not found")
+	// ErrInvalidSegment is returned for a segment filter that can't match.
+	ErrInvalidSegment = errors.New("broadcasts: invalid segment")
+)
+
+// Segment filters the leads a broadcast goes to. Empty fields don't filter.
+type Segment struct {
+	// ServiceInterest matches leads whose service interest contains it,
+	// case-insensitively.
+	ServiceInterest string `json:"service_interest,omitempty"`
+	// ActiveAfter and ActiveBefore bound the lead's last activity: its most
+	// recent conversation message, or when it was created.
+	ActiveAfter  *time.Time `json:"active_after,omitempty"`
+	ActiveBefore *time.Time `json:"active_before,omitempty"`
+	// BookingStatus is "booked", "not_booked" or "" for either.
+	BookingStatus string `json:"booking_status,omitempty"`
+}
+
+// Validate reports whether the segment can be queried.
+func (s Segment) Validate() error {
+	switch s.BookingStatus {
+	case BookingStatusAny, BookingStatusBooked, BookingStatusNotBooked:
+	default:
+		return fmt.Errorf("%w: booking_status must be %q, %q or empty", ErrInvalidSegment, BookingStatusBooked, BookingStatusNotBooked)
+	}
+	if s.ActiveAfter != nil && s.ActiveBefore != nil && !s.ActiveAfter.Before(*s.ActiveBefore) {
+		return fmt.Errorf("%w: active_after must be before active_before", ErrInvalidSegment)
+	}
+	return nil
+}
+
+// Candidate is a lead matched by a segment, before compliance exclusions.
+type Candidate struct {
+	LeadID string
+	Phone  string
+	Name   string
+}
+
+// FirstName returns the candidate's first name, or "" when unknown.
+func (c Candidate) FirstName() string {
+	first, _, _ := strings.Cut(strings.TrimSpace(c.Name), " ")
+	return first
+}
+
+// Broadcast is a message sent to a segment.
+type Broadcast struct {
+	ID            uuid.UUID  `json:"id"`
+	OrgID         string     `json:"org_id"`
+	Segment       Segment    `json:"segment"`
+	Template      string     `json:"template"`
+	FromNumber    string     `json:"from_number"`
+	Status        string     `json:"status"`
+	AudienceCount int        `json:"audience_count"`
+	CreatedBy     string     `json:"created_by,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+	CompletedAt   *time.Time `json:"completed_at,omitempty"`
+}
+
+// Recipient is one lead a broadcast is sent to. DeliveryStatus is the
+// provider's latest status for the sent message.
+type Recipient struct {
+	ID                uuid.UUID  `json:"id"`
+	BroadcastID       uuid.UUID  `json:"broadcast_id"`
+	OrgID             string     `json:"org_id"`
+	LeadID            string     `json:"lead_id,omitempty"`
+	Phone             string     `json:"phone"`
+	Body              string     `json:"-"`
+	FromNumber        string     `json:"-"`
+	Status            string     `json:"status"`
+	SuppressedReason  string     `json:"suppressed_reason,omitempty"`
+	ProviderMessageID string     `json:"provider_message_id,omitempty"`
+	DeliveryStatus    string     `json:"delivery_status,omitempty"`
+	Error             string     `json:"error,omitempty"`
+	SentAt            *time.Time `json:"sent_at,omitempty"`
+}
+
+// RecipientUpdate is the outcome of a send attempt.
+type RecipientUpdate struct {
+	Status            string
+	SuppressedReason  string
+	ProviderMessageID string
+	Error             string
+}
+
+// NewRecipient is a recipient to insert with a new broadcast.
+type NewRecipient struct {
+	LeadID string
+	Phone  string
+	Body   string
+}
+
+// ReplySource identifies the broadcast an inbound reply answers.
+type ReplySource struct {
+	BroadcastID uuid.UUID
+	Message     string
+}
@@ -0,0 +1,327 @@
+package broadcasts
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/pii"
+)
+
+// staleClaimAfter is how long a claimed recipient may sit unsent before
+// another worker reclaims it; maxSendAttempts caps reclaims.
+const (
+	staleClaimAfter = 10 * time.Minute
+	maxSendAttempts = 3
+)
+
+// Querier is the subset of pgxpool.Pool used by Store.
+type Querier interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+// Store persists broadcasts and queries lead segments in Postgres.
+type Store struct {
+	pool   Querier
+	cipher *pii.Cipher
+}
+
+// NewStore constructs a broadcast store. Returns nil without a pool.
+func NewStore(pool Querier) *Store {
+	if pool == nil {
+		return nil
+	}
+	return &Store{pool: pool}
+}
+
+// SetCipher decrypts lead PII and encrypts recipient phones and bodies. Nil
+// stores plaintext.
+func (s *Store) SetCipher(c *pii.Cipher) {
+	s.cipher = c
+}
+
+// segmentQuery selects the latest lead per phone matching a segment. A
+// lead's last activity is its latest conversation message, or its creation.
+const segmentQuery = `
+	SELECT DISTINCT ON (COALESCE(NULLIF(l.phone_hash, ''), l.phone))
+		l.id::text, l.phone, COALESCE(l.name, '')
+	FROM leads l
+	WHERE l.org_id = $1
+		AND COALESCE(l.phone, '') <> ''
+		AND l.pii_purged_at IS NULL
+		AND ($2 = '' OR l.service_interest ILIKE '%' || $2 || '%')
+		AND ($3::timestamptz IS NULL OR COALESCE((SELECT MAX(c.last_message_at) FROM conversations c WHERE c.lead_id = l.id), l.created_at) >= $3)
+		AND ($4::timestamptz IS NULL OR COALESCE((SELECT MAX(c.last_message_at) FROM conversations c WHERE c.lead_id = l.id), l.created_at) < $4)
+		AND ($5 = '' OR ($5 = 'booked') = (
+			COALESCE(l.deposit_status, '') = 'paid'
+			OR COALESCE(l.booking_outcome, '') = 'success'
+			OR EXISTS (SELECT 1 FROM bookings b WHERE b.lead_id = l.id AND b.status <> 'cancelled')
+		))
+	ORDER BY COALESCE(NULLIF(l.phone_hash, ''), l.phone), l.created_at DESC`
+
+// Candidates returns the leads matching seg, one per phone, before consent
+// and opt-out exclusions.
+func (s *Store) Candidates(ctx context.Context, orgID string, seg Segment) ([]Candidate, error) {
+	if err := seg.Validate(); err != nil {
+		return nil, err
+	}
+	rows, err := s.pool.Query(ctx, segmentQuery, orgID, strings.TrimSpace(seg.ServiceInterest), seg.ActiveAfter, seg.ActiveBefore, seg.BookingStatus)
+	if err != nil {
+		return nil, fmt.Errorf("broadcasts: query segment: %w", err)
+	}
+	defer rows.Close()
+	var candidates []Candidate
+	for rows.Next() {
+		var c Candidate
+		if err := rows.Scan(&c.LeadID, &c.Phone, &c.Name); err != nil {
+			return nil, fmt.Errorf("broadcasts: scan segment: %w", err)
+		}
+		if c.Phone, err = s.cipher.Decrypt(c.Phone); err != nil {
+			return nil, fmt.Errorf("broadcasts: decrypt lead phone: %w", err)
+		}
+		if c.Name, err = s.cipher.Decrypt(c.Name); err != nil {
+			return nil, fmt.Errorf("broadcasts: decrypt lead name: %w", err)
+		}
+		candidates = append(candidates, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("broadcasts: query segment: %w", err)
+	}
+	return candidates, nil
+}
+
+// Create inserts the broadcast and its recipients in one statement, so the
+// worker never sees a broadcast without its audience.
+func (s *Store) Create(ctx context.Context, b Broadcast, recipients []NewRecipient) (Broadcast, error) {
+	if b.ID == uuid.Nil {
+		b.ID = uuid.New()
+	}
+	b.Status = StatusSending
+	b.AudienceCount = len(recipients)
+	segment, err := json.Marshal(b.Segment)
+	if err != nil {
+		return Broadcast{}, fmt.Errorf("broadcasts: encode segment: %w", err)
+	}
+	leadIDs := make([]*uuid.UUID, len(recipients))
+	phones := make([]string, len(recipients))
+	hashes := make([]string, len(recipients))
+	bodies := make([]string, len(recipients))
+	for i, r := range recipients {
+		if id, err := uuid.Parse(r.LeadID); err == nil {
+			leadIDs[i] = &id
+		}
+		if phones[i], err = s.cipher.Encrypt(r.Phone); err != nil {
+			return Broadcast{}, fmt.Errorf("broadcasts: encrypt recipient phone: %w", err)
+		}
+		if bodies[i], err = s.cipher.Encrypt(r.Body); err != nil {
+			return Broadcast{}, fmt.Errorf("broadcasts: encrypt recipient body: %w", err)
+		}
+		hashes[i] = s.cipher.HashPhone(r.Phone)
+	}
+	err = s.pool.QueryRow(ctx, `
+		WITH inserted AS (
+			INSERT INTO broadcasts (id, org_id, segment, template, from_number, status, audience_count, created_by)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+			RETURNING id, created_at
+		), queued AS (
+			INSERT INTO broadcast_recipients (broadcast_id, org_id, lead_id, phone, phone_hash, body)
+			SELECT $1, $2, r.lead_id, r.phone, r.phone_hash, r.body
+			FROM unnest($9::uuid[], $10::text[], $11::text[], $12::text[]) AS r(lead_id, phone, phone_hash, body)
+		)
+		SELECT created_at FROM inserted`,
+		b.ID, b.OrgID, segment, b.Template, b.FromNumber, b.Status, b.AudienceCount, b.CreatedBy,
+		leadIDs, phones, hashes, bodies,
+	).Scan(&b.CreatedAt)
+	if err != nil {
+		return Broadcast{}, fmt.Errorf("broadcasts: create: %w", err)
+	}
+	return b, nil
+}
+
+// Get returns the org's broadcast.
+func (s *Store) Get(ctx context.Context, orgID string, id uuid.UUID) (Broadcast, error) {
+	var b Broadcast
+	var segment []byte
+	err := s.pool.QueryRow(ctx, `
+		SELECT id, org_id, segment, template, from_number, status, audience_count, created_by, created_at, completed_at
+		FROM broadcasts
+		WHERE org_id = $1 AND id = $2`, orgID, id,
+	).Scan(&b.ID, &b.OrgID, &segment, &b.Template, &b.FromNumber, &b.Status, &b.AudienceCount, &b.CreatedBy, &b.CreatedAt, &b.CompletedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return Broadcast{}, ErrNotFound
+	}
+	if err != nil {
+		return Broadcast{}, fmt.Errorf("broadcasts: get: %w", err)
+	}
+	if err := json.Unmarshal(segment, &b.Segment); err != nil {
+		return Broadcast{}, fmt.Errorf("broadcasts: decode segment: %w", err)
+	}
+	return b, nil
+}
+
+// Recipients returns the broadcast's recipients with the provider's latest
+// delivery status for each sent message.
+func (s *Store) Recipients(ctx context.Context, orgID string, id uuid.UUID) ([]Recipient, error) {
+	rows, err := s.pool.Query(ctx, `
+		SELECT r.id, r.broadcast_id, r.org_id, COALESCE(r.lead_id::text, ''), r.phone, r.status,
+			r.suppressed_reason, r.provider_message_id, COALESCE(m.provider_status, ''), r.error, r.sent_at
+		FROM broadcast_recipients r
+		LEFT JOIN messages m ON r.provider_message_id <> '' AND m.provider_message_id = r.provider_message_id
+		WHERE r.org_id = $1 AND r.broadcast_id = $2
+		ORDER BY r.created_at, r.id`, orgID, id)
+	if err != nil {
+		return nil, fmt.Errorf("broadcasts: list recipients: %w", err)
+	}
+	defer rows.Close()
+	recipients := []Recipient{}
+	for rows.Next() {
+		var r Recipient
+		if err := rows.Scan(&r.ID, &r.BroadcastID, &r.OrgID, &r.LeadID, &r.Phone, &r.Status,
+			&r.SuppressedReason, &r.ProviderMessageID, &r.DeliveryStatus, &r.Error, &r.SentAt); err != nil {
+			return nil, fmt.Errorf("broadcasts: scan recipient: %w", err)
+		}
+		if r.Phone, err = s.cipher.Decrypt(r.Phone); err != nil {
+			return nil, fmt.Errorf("broadcasts: decrypt recipient phone: %w", err)
+		}
+		recipients = append(recipients, r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("broadcasts: list recipients: %w", err)
+	}
+	return recipients, nil
+}
+
+// PendingOrgs lists the orgs that still have unsent recipients.
+func (s *Store) PendingOrgs(ctx context.Context) ([]string, error) {
+	rows, err := s.pool.Query(ctx, `
+		SELECT DISTINCT org_id FROM broadcast_recipients
+		WHERE status IN ('pending', 'sending')`)
+	if err != nil {
+		return nil, fmt.Errorf("broadcasts: list pending orgs: %w", err)
+	}
+	defer rows.Close()
+	var orgs []string
+	for rows.Next() {
+		var orgID string
+		if err := rows.Scan(&orgID); err != nil {
+			return nil, fmt.Errorf("broadcasts: scan pending org: %w", err)
+		}
+		orgs = append(orgs, orgID)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("broadcasts: list pending orgs: %w", err)
+	}
+	return orgs, nil
+}
+
+// ClaimDue claims up to limit unsent recipients with FOR UPDATE SKIP LOCKED,
+// oldest first, so concurrent workers split them. Recipients of heldOrgs,
+// e.g. clinics in quiet hours, are left pending.
+func (s *Store) ClaimDue(ctx context.Context, limit int, heldOrgs []string) ([]Recipient, error) {
+	if limit <= 0 {
+		return nil, nil
+	}
+	if heldOrgs == nil {
+		heldOrgs = []string{}
+	}
+	rows, err := s.pool.Query(ctx, `
+		UPDATE broadcast_recipients r
+		SET status = 'sending', attempts = r.attempts + 1, updated_at = now()
+		FROM broadcasts b
+		WHERE b.id = r.broadcast_id AND r.id IN (
+			SELECT id FROM broadcast_recipients
+			WHERE (status = 'pending'
+			   OR (status = 'sending' AND updated_at <= now() - make_interval(secs => $2) AND attempts < $3))
+			  AND org_id <> ALL($4::text[])
+			ORDER BY created_at, id
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING r.id, r.broadcast_id, r.org_id, COALESCE(r.lead_id::text, ''), r.phone, r.body, b.from_number`,
+		limit, staleClaimAfter.Seconds(), maxSendAttempts, heldOrgs)
+	if err != nil {
+		return nil, fmt.Errorf("broadcasts: claim recipients: %w", err)
+	}
+	defer rows.Close()
+	var claimed []Recipient
+	for rows.Next() {
+		r := Recipient{Status: RecipientSending}
+		if err := rows.Scan(&r.ID, &r.BroadcastID, &r.OrgID, &r.LeadID, &r.Phone, &r.Body, &r.FromNumber); err != nil {
+			return nil, fmt.Errorf("broadcasts: scan claimed recipient: %w", err)
+		}
+		if r.Phone, err = s.cipher.Decrypt(r.Phone); err != nil {
+			return nil, fmt.Errorf("broadcasts: decrypt recipient phone: %w", err)
+		}
+		if r.Body, err = s.cipher.Decrypt(r.Body); err != nil {
+			return nil, fmt.Errorf("broadcasts: decrypt recipient body: %w", err)
+		}
+		claimed = append(claimed, r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("broadcasts: claim recipients: %w", err)
+	}
+	return claimed, nil
+}
+
+// MarkRecipient records the outcome of a send attempt.
+func (s *Store) MarkRecipient(ctx context.Context, id uuid.UUID, u RecipientUpdate) error {
+	_, err := s.pool.Exec(ctx, `
+		UPDATE broadcast_recipients
+		SET status = $2, suppressed_reason = $3, provider_message_id = $4, error = $5,
+			sent_at = CASE WHEN $2 = 'sent' THEN now() ELSE sent_at END,
+			updated_at = now()
+		WHERE id = $1`, id, u.Status, u.SuppressedReason, u.ProviderMessageID, u.Error)
+	if err != nil {
+		return fmt.Errorf("broadcasts: mark recipient: %w", err)
+	}
+	return nil
+}
+
+// CompleteFinished marks broadcasts with no unsent recipients completed and
+// returns how many finished.
+func (s *Store) CompleteFinished(ctx context.Context) (int64, error) {
+	tag, err := s.pool.Exec(ctx, `
+		UPDATE broadcasts b
+		SET status = 'completed', completed_at = now()
+		WHERE b.status = 'sending' AND NOT EXISTS (
+			SELECT 1 FROM broadcast_recipients r
+			WHERE r.broadcast_id = b.id AND r.status IN ('pending', 'sending')
+		)`)
+	if err != nil {
+		return 0, fmt.Errorf("broadcasts: complete finished: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
+
+// ReplySourceFor returns the latest broadcast sent to phone since the given
+// time, so a reply can be tagged with it. Returns false when there is none.
+func (s *Store) ReplySourceFor(ctx context.Context, orgID, phone string, since time.Time) (ReplySource, bool, error) {
+	var src ReplySource
+	err := s.pool.QueryRow(ctx, `
+		SELECT r.broadcast_id, r.body
+		FROM broadcast_recipients r
+		WHERE r.org_id = $1 AND r.status = 'sent' AND r.sent_at >= $4
+			AND (r.phone = $2 OR ($3 <> '' AND r.phone_hash = $3))
+		ORDER BY r.sent_at DESC
+		LIMIT 1`, orgID, phone, s.cipher.HashPhone(phone), since,
+	).Scan(&src.BroadcastID, &src.Message)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ReplySource{}, false, nil
+	}
+	if err != nil {
+		return ReplySource{}, false, fmt.Errorf("broadcasts: find reply source: %w", err)
+	}
+	if src.Message, err = s.cipher.Decrypt(src.Message); err != nil {
+		return ReplySource{}, false, fmt.Errorf("broadcasts: decrypt broadcast body: %w", err)
+	}
+	return src, true, nil
+}
@@ -0,0 +1,108 @@
+package broadcasts
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	pgxmock "github.com/pashagolub/pgxmock/v4"
+)
+
+func TestStoreCandidatesPassesSegmentFilters(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("pgxmock: %v", err)
+	}
+	defer mock.Close()
+
+	orgID := uuid.NewString()
+	after := time.Date(2026, 8, 18, 0, 0, 0, 0, time.UTC)
+	seg := Segment{ServiceInterest: " Botox ", ActiveAfter: &after, BookingStatus: BookingStatusNotBooked}
+	mock.ExpectQuery("SELECT DISTINCT ON").
+		WithArgs(orgID, "Botox", &after, (*time.Time)(nil), BookingStatusNotBooked).
+		WillReturnRows(pgxmock.NewRows([]string{"id", "phone", "name"}).
+			AddRow("lead-1", "+15550000001", "Jane Doe").
+			AddRow("lead-2", "+15550000002", ""))
+
+	got, err := NewStore(mock).Candidates(context.Background(), orgID, seg)
+	if err != nil {
+		t.Fatalf("Candidates: %v", err)
+	}
+	if len(got) != 2 || got[0].FirstName() != "Jane" || got[1].Phone != "+15550000002" {
+		t.Fatalf("unexpected candidates: %+v", got)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestStoreCandidatesRejectsInvalidSegment(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("pgxmock: %v", err)
+	}
+	defer mock.Close()
+
+	if _, err := NewStore(mock).Candidates(context.Background(), uuid.NewString(), Segment{BookingStatus: "maybe"}); err == nil {
+		t.Fatal("expected invalid booking status to be rejected")
+	}
+}
+
+func TestStoreClaimDueRespectsLimit(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("pgxmock: %v", err)
+	}
+	defer mock.Close()
+
+	id, broadcastID := uuid.New(), uuid.New()
+	mock.ExpectQuery("UPDATE broadcast_recipients r").
+		WithArgs(5, staleClaimAfter.Seconds(), maxSendAttempts, []string{"org-2"}).
+		WillReturnRows(pgxmock.NewRows([]string{"id", "broadcast_id", "org_id", "lead_id", "phone", "body", "from_number"}).
+			AddRow(id, broadcastID, "org-1", "lead-1", "+15550000001", "Openings Friday!", "+15559990000"))
+
+	store := NewStore(mock)
+	if claimed, err := store.ClaimDue(context.Background(), 0, nil); err != nil || claimed != nil {
+		t.Fatalf("expected no claim for zero limit, got %v, %v", claimed, err)
+	}
+	claimed, err := store.ClaimDue(context.Background(), 5, []string{"org-2"})
+	if err != nil {
+		t.Fatalf("ClaimDue: %v", err)
+	}
+	if len(claimed) != 1 || claimed[0].FromNumber != "+15559990000" || claimed[0].Status != RecipientSending {
+		t.Fatalf("unexpected claim: %+v", claimed)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestStoreReplySourceFor(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("pgxmock: %v", err)
+	}
+	defer mock.Close()
+
+	since := time.Now().Add(-time.Hour)
+	broadcastID := uuid.New()
+	mock.ExpectQuery("FROM broadcast_recipients r").
+		WithArgs("org-1", "+15550000001", "", since).
+		WillReturnRows(pgxmock.NewRows([]string{"broadcast_id", "body"}).AddRow(broadcastID, "3 openings Friday!"))
+	mock.ExpectQuery("FROM broadcast_recipients r").
+		WithArgs("org-1", "+15550000002", "", since).
+		WillReturnRows(pgxmock.NewRows([]string{"broadcast_id", "body"}))
+
+	store := NewStore(mock)
+	src, ok, err := store.ReplySourceFor(context.Background(), "org-1", "+15550000001", since)
+	if err != nil || !ok || src.BroadcastID != broadcastID || src.Message != "3 openings Friday!" {
+		t.Fatalf("ReplySourceFor = %+v, %v, %v", src, ok, err)
+	}
+	if _, ok, err := store.ReplySourceFor(context.Background(), "org-1", "+15550000002", since); err != nil || ok {
+		t.Fatalf("expected no reply source, got %v, %v", ok, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
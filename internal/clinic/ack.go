@@ -0,0 +1,26 @@
+package clinic
+
+import (
+	"strings"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/messaging/templates"
+)
+
+// AckMessage returns the instant ack for the inbound message identified by
+// key, rotating through the clinic's AckTemplates or the default set.
+// Returns "" when the clinic has disabled acks. A nil config gets a default.
+func (c *Config) AckMessage(key string) string {
+	return c.RenderAck(key).Body
+}
+
+// RenderAck is AckMessage along with the template it was rendered from. It
+// returns a zero Rendered when the clinic has disabled acks.
+func (c *Config) RenderAck(key string) templates.Rendered {
+	if c == nil {
+		return templates.RenderVariant(templates.AckFirst, key, nil, nil)
+	}
+	if c.DisableAcks {
+		return templates.Rendered{}
+	}
+	return c.RenderTemplateVariant(templates.AckFirst, key, templates.Params{"clinic_name": strings.TrimSpace(c.Name)})
+}
@@ -0,0 +1,49 @@
+package clinic
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/conversation/ackmsgs"
+)
+
+func TestAckMessageRotatesClinicTemplates(t *testing.T) {
+	cfg := &Config{
+		Name:         "Glow Med Spa",
+		AckTemplates: []string{"Thanks for texting {{clinic_name}}!", "  ", "One moment - {{clinic_name}} is on it."},
+	}
+	want := map[string]bool{
+		"Thanks for texting Glow Med Spa!":    false,
+		"One moment - Glow Med Spa is on it.": false,
+	}
+	for i := 0; i < 50; i++ {
+		key := fmt.Sprintf("SM%d", i)
+		got := cfg.AckMessage(key)
+		if _, ok := want[got]; !ok {
+			t.Fatalf("AckMessage(%q) = %q", key, got)
+		}
+		if again := cfg.AckMessage(key); again != got {
+			t.Fatalf("AckMessage(%q) not stable: %q then %q", key, got, again)
+		}
+		want[got] = true
+	}
+	for tmpl, used := range want {
+		if !used {
+			t.Errorf("template %q never picked", tmpl)
+		}
+	}
+}
+
+func TestAckMessageDefaultsAndDisable(t *testing.T) {
+	var nilCfg *Config
+	if got := nilCfg.AckMessage("SM1"); !ackmsgs.Is(got) {
+		t.Fatalf("nil config ack = %q, want a default", got)
+	}
+	if got := (&Config{Name: "Glow"}).AckMessage("SM1"); !ackmsgs.Is(got) {
+		t.Fatalf("no templates ack = %q, want a default", got)
+	}
+	disabled := &Config{DisableAcks: true, AckTemplates: []string{"Hi!"}}
+	if got := disabled.AckMessage("SM1"); got != "" {
+		t.Fatalf("disabled ack = %q, want none", got)
+	}
+}
@@ -0,0 +1,90 @@
+package clinic
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// MMS attachment kinds, classified from the attachment's content type.
+const (
+	AttachmentImage   = "image"
+	AttachmentVCard   = "vcard"
+	AttachmentPDF     = "pdf"
+	AttachmentUnknown = "unknown"
+)
+
+// Attachment actions.
+const (
+	// AttachmentActionForward sends the media link to the clinic's team,
+	// e.g. for a gift card or insurance card photo.
+	AttachmentActionForward = "forward"
+	// AttachmentActionDecline tells the patient the attachment can't be read.
+	AttachmentActionDecline = "decline"
+	// AttachmentActionContact reads a contact card into the lead.
+	AttachmentActionContact = "contact"
+)
+
+// AttachmentRule is how the assistant handles one kind of attachment. Reply
+// is sent when the patient texted only attachments; empty uses the action's
+// default reply.
+type AttachmentRule struct {
+	Action string `json:"action"`
+	Reply  string `json:"reply,omitempty"`
+}
+
+var defaultAttachmentRules = map[string]AttachmentRule{
+	AttachmentImage: {Action: AttachmentActionForward},
+	AttachmentVCard: {Action: AttachmentActionContact},
+	AttachmentPDF: {
+		Action: AttachmentActionDecline,
+		Reply:  "Thanks for sending that! I'm not able to open PDFs over text — could you tell me in a few words what it's about?",
+	},
+	AttachmentUnknown: {Action: AttachmentActionDecline},
+}
+
+var defaultAttachmentReplies = map[string]string{
+	AttachmentActionForward: "Thanks for sending this! I'll pass it to our team and they'll follow up with you.",
+	AttachmentActionDecline: "Thanks for sending that! I'm not able to open that attachment over text — could you tell me in a few words what it's about?",
+	AttachmentActionContact: "Thanks — I've saved those contact details!",
+}
+
+// AttachmentRuleFor returns the clinic's rule for an attachment kind, or the
+// default rule. Unknown kinds are handled as AttachmentUnknown.
+func (c *Config) AttachmentRuleFor(kind string) AttachmentRule {
+	if _, ok := defaultAttachmentRules[kind]; !ok {
+		kind = AttachmentUnknown
+	}
+	rule := defaultAttachmentRules[kind]
+	if c != nil {
+		if custom, ok := c.AttachmentRules[kind]; ok {
+			rule = custom
+		}
+	}
+	if strings.TrimSpace(rule.Reply) == "" {
+		rule.Reply = defaultAttachmentReplies[rule.Action]
+	}
+	return rule
+}
+
+// ValidateAttachmentRules checks attachment rules before they are saved.
+func ValidateAttachmentRules(rules map[string]AttachmentRule) error {
+	kinds := make([]string, 0, len(rules))
+	for kind := range rules {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+	for _, kind := range kinds {
+		if _, ok := defaultAttachmentRules[kind]; !ok {
+			return fmt.Errorf("attachment_rules: unknown attachment kind %q", kind)
+		}
+		action := rules[kind].Action
+		if _, ok := defaultAttachmentReplies[action]; !ok {
+			return fmt.Errorf("attachment_rules[%s]: unknown action %q", kind, action)
+		}
+		if action == AttachmentActionContact && kind != AttachmentVCard {
+			return fmt.Errorf("attachment_rules[%s]: only contact cards can use the %q action", kind, action)
+		}
+	}
+	return nil
+}
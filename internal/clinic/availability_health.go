@@ -0,0 +1,59 @@
+package clinic
+
+import (
+	"sort"
+	"strings"
+)
+
+// MaxAvailabilityProbeServices caps how many services the availability
+// health probe checks per clinic, keeping probe traffic light.
+const MaxAvailabilityProbeServices = 3
+
+// defaultAvailabilityProbeServices is how many Moxie services are probed when
+// the clinic hasn't picked representative ones.
+const defaultAvailabilityProbeServices = 2
+
+// AvailabilityHealthConfig configures the availability health probe.
+type AvailabilityHealthConfig struct {
+	// Services are the representative services probed each run. Empty
+	// falls back to the first Moxie service menu items.
+	Services []string `json:"services,omitempty"`
+	// NotifyClinic also posts probe alerts to the clinic's chat webhooks.
+	// Engineering is always alerted.
+	NotifyClinic bool `json:"notify_clinic,omitempty"`
+}
+
+// AvailabilityProbeServices returns the services the availability health
+// probe should check for this clinic. Inactive clinics are not probed.
+func (c *Config) AvailabilityProbeServices() []string {
+	if !c.IsActive() {
+		return nil
+	}
+	var services []string
+	if c.AvailabilityHealth != nil {
+		for _, svc := range c.AvailabilityHealth.Services {
+			if svc = strings.TrimSpace(svc); svc != "" {
+				services = append(services, svc)
+			}
+		}
+	}
+	if len(services) == 0 && c.MoxieConfig != nil {
+		for svc := range c.MoxieConfig.ServiceMenuItems {
+			services = append(services, svc)
+		}
+		sort.Strings(services)
+		if len(services) > defaultAvailabilityProbeServices {
+			services = services[:defaultAvailabilityProbeServices]
+		}
+	}
+	if len(services) > MaxAvailabilityProbeServices {
+		services = services[:MaxAvailabilityProbeServices]
+	}
+	return services
+}
+
+// NotifiesClinicOfAvailabilityAlerts reports whether availability probe
+// alerts should also go to the clinic.
+func (c *Config) NotifiesClinicOfAvailabilityAlerts() bool {
+	return c != nil && c.AvailabilityHealth != nil && c.AvailabilityHealth.NotifyClinic
+}
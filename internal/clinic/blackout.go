@@ -0,0 +1,133 @@
+package clinic
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+const blackoutDateLayout = "2006-01-02"
+
+// BlackoutDate marks a date range when the clinic (or a single provider) is
+// unavailable even if the booking platform still reports open slots — e.g. a
+// provider on vacation whose calendar hasn't been updated yet.
+type BlackoutDate struct {
+	ID string `json:"id"`
+	// ProviderID limits the blackout to one provider (Moxie userMedspaId or a
+	// ProviderNames key). Empty means the whole clinic is closed.
+	ProviderID string `json:"provider_id,omitempty"`
+	// StartDate and EndDate are inclusive YYYY-MM-DD dates in the clinic's timezone.
+	StartDate string `json:"start_date"`
+	EndDate   string `json:"end_date"`
+	Reason    string `json:"reason,omitempty"`
+}
+
+// Validate checks that the range is well-formed.
+func (b BlackoutDate) Validate() error {
+	start, err := time.Parse(blackoutDateLayout, strings.TrimSpace(b.StartDate))
+	if err != nil {
+		return fmt.Errorf("start_date must be YYYY-MM-DD")
+	}
+	end, err := time.Parse(blackoutDateLayout, strings.TrimSpace(b.EndDate))
+	if err != nil {
+		return fmt.Errorf("end_date must be YYYY-MM-DD")
+	}
+	if end.Before(start) {
+		return fmt.Errorf("end_date must not be before start_date")
+	}
+	return nil
+}
+
+// Expired reports whether the blackout ended before the given day.
+func (b BlackoutDate) Expired(today time.Time) bool {
+	return b.EndDate < today.Format(blackoutDateLayout)
+}
+
+// covers reports whether the blackout applies to a slot on day (YYYY-MM-DD)
+// for the given provider. Clinic-wide blackouts apply to every provider.
+func (b BlackoutDate) covers(day, providerID string) bool {
+	if b.ProviderID != "" && b.ProviderID != providerID {
+		return false
+	}
+	return day >= b.StartDate && day <= b.EndDate
+}
+
+// IsBlackedOut reports whether a slot starting at slotTime with the given
+// provider falls inside a blackout. The slot's calendar day is taken in the
+// clinic's timezone. An empty providerID only matches clinic-wide blackouts.
+func (c *Config) IsBlackedOut(slotTime time.Time, providerID string) bool {
+	if c == nil || len(c.BlackoutDates) == 0 {
+		return false
+	}
+	day := slotTime.In(c.location()).Format(blackoutDateLayout)
+	for _, b := range c.BlackoutDates {
+		if b.covers(day, providerID) {
+			return true
+		}
+	}
+	return false
+}
+
+// HasProviderBlackouts reports whether any provider-specific blackout is still
+// active. Availability that isn't attributed to a provider can't be checked
+// against these, so callers should query per provider instead.
+func (c *Config) HasProviderBlackouts(now time.Time) bool {
+	if c == nil {
+		return false
+	}
+	today := now.In(c.location())
+	for _, b := range c.BlackoutDates {
+		if b.ProviderID != "" && !b.Expired(today) {
+			return true
+		}
+	}
+	return false
+}
+
+// ActiveBlackouts returns the blackouts that haven't ended yet.
+func (c *Config) ActiveBlackouts(now time.Time) []BlackoutDate {
+	if c == nil {
+		return nil
+	}
+	today := now.In(c.location())
+	var out []BlackoutDate
+	for _, b := range c.BlackoutDates {
+		if !b.Expired(today) {
+			out = append(out, b)
+		}
+	}
+	return out
+}
+
+// ProviderIDForName resolves a patient-facing provider name to the ID used
+// by blackouts, checking Moxie providers first and then ProviderNames.
+func (c *Config) ProviderIDForName(name string) string {
+	if c == nil {
+		return ""
+	}
+	if id := c.ResolveProviderID(name); id != "" {
+		return id
+	}
+	lower := strings.ToLower(strings.TrimSpace(name))
+	if lower == "" {
+		return ""
+	}
+	for id, n := range c.ProviderNames {
+		n = strings.ToLower(n)
+		if n == lower {
+			return id
+		}
+		if parts := strings.Fields(n); len(parts) > 0 && parts[0] == lower {
+			return id
+		}
+	}
+	return ""
+}
+
+func (c *Config) location() *time.Location {
+	loc, err := time.LoadLocation(c.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
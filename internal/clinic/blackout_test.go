@@ -0,0 +1,167 @@
+package clinic
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
+)
+
+func TestBlackoutDate_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		b       BlackoutDate
+		wantErr bool
+	}{
+		{"single day", BlackoutDate{StartDate: "2026-03-09", EndDate: "2026-03-09"}, false},
+		{"week", BlackoutDate{StartDate: "2026-03-09", EndDate: "2026-03-15"}, false},
+		{"end before start", BlackoutDate{StartDate: "2026-03-15", EndDate: "2026-03-09"}, true},
+		{"bad start", BlackoutDate{StartDate: "03/09/2026", EndDate: "2026-03-15"}, true},
+		{"missing end", BlackoutDate{StartDate: "2026-03-09"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.b.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestConfig_IsBlackedOut(t *testing.T) {
+	loc, _ := time.LoadLocation("America/New_York")
+	cfg := &Config{
+		Timezone: "America/New_York",
+		BlackoutDates: []BlackoutDate{
+			{ID: "clinic", StartDate: "2026-03-09", EndDate: "2026-03-15", Reason: "spring break"},
+			{ID: "brandi", ProviderID: "33150", StartDate: "2026-04-01", EndDate: "2026-04-03"},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		slot     time.Time
+		provider string
+		want     bool
+	}{
+		{"clinic-wide first day", time.Date(2026, 3, 9, 9, 0, 0, 0, loc), "", true},
+		{"clinic-wide applies to any provider", time.Date(2026, 3, 12, 14, 0, 0, 0, loc), "33151", true},
+		{"clinic-wide last day evening", time.Date(2026, 3, 15, 20, 0, 0, 0, loc), "", true},
+		{"day after clinic-wide", time.Date(2026, 3, 16, 9, 0, 0, 0, loc), "", false},
+		// 01:00 UTC on Mar 9 is still Mar 8 in New York.
+		{"uses clinic timezone", time.Date(2026, 3, 9, 1, 0, 0, 0, time.UTC), "", false},
+		{"provider blackout hits provider", time.Date(2026, 4, 2, 10, 0, 0, 0, loc), "33150", true},
+		{"provider blackout skips other provider", time.Date(2026, 4, 2, 10, 0, 0, 0, loc), "33151", false},
+		{"provider blackout skips unattributed slot", time.Date(2026, 4, 2, 10, 0, 0, 0, loc), "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := cfg.IsBlackedOut(tt.slot, tt.provider); got != tt.want {
+				t.Errorf("IsBlackedOut(%v, %q) = %v, want %v", tt.slot, tt.provider, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfig_ExpiredBlackoutsIgnored(t *testing.T) {
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	cfg := &Config{
+		Timezone: "America/New_York",
+		BlackoutDates: []BlackoutDate{
+			{ID: "old", ProviderID: "33150", StartDate: "2026-04-01", EndDate: "2026-04-03"},
+			{ID: "upcoming", StartDate: "2026-05-20", EndDate: "2026-05-22"},
+		},
+	}
+
+	if cfg.HasProviderBlackouts(now) {
+		t.Error("expired provider blackout should not count as active")
+	}
+	active := cfg.ActiveBlackouts(now)
+	if len(active) != 1 || active[0].ID != "upcoming" {
+		t.Errorf("ActiveBlackouts = %+v, want only upcoming", active)
+	}
+}
+
+func TestConfig_ProviderIDForName(t *testing.T) {
+	cfg := &Config{
+		MoxieConfig:   &MoxieConfig{ProviderNames: map[string]string{"33150": "Brandi Sesock"}},
+		ProviderNames: map[string]string{"staff-1": "Gale Smith"},
+	}
+	if got := cfg.ProviderIDForName("Brandi"); got != "33150" {
+		t.Errorf("Moxie provider = %q, want 33150", got)
+	}
+	if got := cfg.ProviderIDForName("gale smith"); got != "staff-1" {
+		t.Errorf("Boulevard provider = %q, want staff-1", got)
+	}
+	if got := cfg.ProviderIDForName("no preference"); got != "" {
+		t.Errorf("no preference = %q, want empty", got)
+	}
+}
+
+func newBlackoutTestHandler(t *testing.T) (*Handler, *Store) {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	store := NewStore(redis.NewClient(&redis.Options{Addr: mr.Addr()}))
+	return NewHandler(store, logging.Default()), store
+}
+
+func TestHandler_Blackouts(t *testing.T) {
+	h, store := newBlackoutTestHandler(t)
+	routes := h.Routes()
+
+	body, _ := json.Marshal(BlackoutDate{ProviderID: "33150", StartDate: "2026-06-01", EndDate: "2026-06-07", Reason: "vacation"})
+	rr := httptest.NewRecorder()
+	routes.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/org-1/blackouts", bytes.NewReader(body)))
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("create status = %d, body = %s", rr.Code, rr.Body.String())
+	}
+	var created BlackoutDate
+	if err := json.Unmarshal(rr.Body.Bytes(), &created); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if created.ID == "" || created.Reason != "vacation" {
+		t.Fatalf("unexpected blackout: %+v", created)
+	}
+
+	cfg, _ := store.Get(context.Background(), "org-1")
+	if len(cfg.BlackoutDates) != 1 || cfg.BlackoutDates[0].ID != created.ID {
+		t.Fatalf("stored blackouts = %+v", cfg.BlackoutDates)
+	}
+
+	rr = httptest.NewRecorder()
+	routes.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/org-1/blackouts", nil))
+	var list struct {
+		BlackoutDates []BlackoutDate `json:"blackout_dates"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &list); err != nil || len(list.BlackoutDates) != 1 {
+		t.Fatalf("list = %s (err %v)", rr.Body.String(), err)
+	}
+
+	rr = httptest.NewRecorder()
+	routes.ServeHTTP(rr, httptest.NewRequest(http.MethodDelete, "/org-1/blackouts/"+created.ID, nil))
+	if rr.Code != http.StatusNoContent {
+		t.Fatalf("delete status = %d", rr.Code)
+	}
+	rr = httptest.NewRecorder()
+	routes.ServeHTTP(rr, httptest.NewRequest(http.MethodDelete, "/org-1/blackouts/"+created.ID, nil))
+	if rr.Code != http.StatusNotFound {
+		t.Fatalf("second delete status = %d, want 404", rr.Code)
+	}
+}
+
+func TestHandler_CreateBlackoutRejectsBadRange(t *testing.T) {
+	h, _ := newBlackoutTestHandler(t)
+	body := `{"start_date": "2026-06-07", "end_date": "2026-06-01"}`
+	rr := httptest.NewRecorder()
+	h.Routes().ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/org-1/blackouts", bytes.NewBufferString(body)))
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want 400", rr.Code)
+	}
+}
@@ -74,6 +74,79 @@ func (c *Config) IsOpenAt(t time.Time) bool {
 	return currentMinutes >= openMinutes && currentMinutes < closeMinutes
 }
 
+// EndsBeforeClose reports whether an appointment starting at start and
+// lasting duration finishes by closing time that day. Days without
+// configured hours, unparseable hours, and unknown durations never exclude a
+// slot; whether the clinic is open at start is checked separately.
+func (c *Config) EndsBeforeClose(start time.Time, duration time.Duration) bool {
+	if duration <= 0 {
+		return true
+	}
+	local := start.In(c.location())
+	hours := c.BusinessHours.GetHoursForDay(local.Weekday())
+	if hours == nil {
+		return true
+	}
+	closeTime, err := time.Parse("15:04", hours.Close)
+	if err != nil {
+		return true
+	}
+	closesAt := time.Date(local.Year(), local.Month(), local.Day(), closeTime.Hour(), closeTime.Minute(), 0, 0, local.Location())
+	return !local.Add(duration).After(closesAt)
+}
+
+// OpenDuration returns how long the clinic is open between from and to, in
+// its timezone. Like IsOpenAt, a clinic without configured hours counts as
+// open 7 AM - 9 PM every day.
+func (c *Config) OpenDuration(from, to time.Time) time.Duration {
+	if !to.After(from) {
+		return 0
+	}
+	loc := c.location()
+	start, end := from.In(loc), to.In(loc)
+	var open time.Duration
+	for day := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, loc); day.Before(end); day = day.AddDate(0, 0, 1) {
+		opensAt, closesAt, ok := c.openWindow(day)
+		if !ok {
+			continue
+		}
+		if opensAt.Before(start) {
+			opensAt = start
+		}
+		if closesAt.After(end) {
+			closesAt = end
+		}
+		if closesAt.After(opensAt) {
+			open += closesAt.Sub(opensAt)
+		}
+	}
+	return open
+}
+
+// openWindow returns when the clinic opens and closes on day, a local
+// midnight, or false when it is closed that day.
+func (c *Config) openWindow(day time.Time) (time.Time, time.Time, bool) {
+	at := func(hour, minute int) time.Time {
+		return time.Date(day.Year(), day.Month(), day.Day(), hour, minute, 0, 0, day.Location())
+	}
+	if !c.BusinessHours.HasAnyHours() {
+		return at(7, 0), at(21, 0), true
+	}
+	hours := c.BusinessHours.GetHoursForDay(day.Weekday())
+	if hours == nil {
+		return time.Time{}, time.Time{}, false
+	}
+	openTime, err := time.Parse("15:04", hours.Open)
+	if err != nil {
+		return time.Time{}, time.Time{}, false
+	}
+	closeTime, err := time.Parse("15:04", hours.Close)
+	if err != nil {
+		return time.Time{}, time.Time{}, false
+	}
+	return at(openTime.Hour(), openTime.Minute()), at(closeTime.Hour(), closeTime.Minute()), true
+}
+
 // NextOpenTime returns when the clinic next opens.
 // Returns the current time if already open.
 func (c *Config) NextOpenTime(t time.Time) time.Time {
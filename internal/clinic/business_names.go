@@ -0,0 +1,45 @@
+package clinic
+
+import "strings"
+
+// BusinessNames returns the names patients may know the clinic by: its
+// display name, legal name and NameAliases, without blanks or duplicates.
+func (c *Config) BusinessNames() []string {
+	if c == nil {
+		return nil
+	}
+	return uniqueNames(append([]string{c.Name, c.LegalName}, c.NameAliases...))
+}
+
+// ProviderDisplayNames returns the names of the clinic's providers from the
+// AI persona, the Moxie config and ProviderNames.
+func (c *Config) ProviderDisplayNames() []string {
+	if c == nil {
+		return nil
+	}
+	names := []string{c.AIPersona.ProviderName}
+	if c.MoxieConfig != nil {
+		for _, n := range c.MoxieConfig.ProviderNames {
+			names = append(names, n)
+		}
+	}
+	for _, n := range c.ProviderNames {
+		names = append(names, n)
+	}
+	return uniqueNames(names)
+}
+
+func uniqueNames(names []string) []string {
+	seen := make(map[string]bool, len(names))
+	out := make([]string, 0, len(names))
+	for _, n := range names {
+		n = strings.TrimSpace(n)
+		key := strings.ToLower(n)
+		if n == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		out = append(out, n)
+	}
+	return out
+}
@@ -0,0 +1,44 @@
+package clinic
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// ChatWebhook is an incoming-webhook destination for operator notifications.
+// The URL embeds the channel's secret token, so it must never be logged; use
+// Redacted instead.
+type ChatWebhook struct {
+	Name string `json:"name,omitempty"` // e.g. "#front-desk"
+	URL  string `json:"url"`
+	// Events limits which events are posted (lead_created, deposit_paid,
+	// booking_confirmed, escalation, deposits_unapplied, top_leads). Empty
+	// means all events.
+	Events []string `json:"events,omitempty"`
+}
+
+// Wants reports whether the webhook is subscribed to event.
+func (w ChatWebhook) Wants(event string) bool {
+	if len(w.Events) == 0 {
+		return true
+	}
+	for _, e := range w.Events {
+		if strings.EqualFold(strings.TrimSpace(e), event) {
+			return true
+		}
+	}
+	return false
+}
+
+// Redacted identifies the webhook for logs without exposing its token.
+func (w ChatWebhook) Redacted() string {
+	host := ""
+	if u, err := url.Parse(w.URL); err == nil {
+		host = u.Host
+	}
+	if w.Name != "" {
+		return fmt.Sprintf("%s (%s)", w.Name, host)
+	}
+	return host
+}
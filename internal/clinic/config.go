@@ -1,6 +1,8 @@
 // Package clinic provides clinic-specific configuration and business logic.
 package clinic
 
+import "time"
+
 // DayHours represents the opening hours for a single day.
 // Nil means the clinic is closed that day.
 type DayHours struct {
@@ -19,6 +21,13 @@ type BusinessHours struct {
 	Sunday    *DayHours `json:"sunday,omitempty"`
 }
 
+// ServiceDepositPolicy configures the deposit requirement for one service.
+type ServiceDepositPolicy struct {
+	RequireDeposit bool `json:"require_deposit"`
+	// AmountCents overrides the deposit amount when set and a deposit is required.
+	AmountCents int `json:"amount_cents,omitempty"`
+}
+
 // NotificationPrefs holds notification preferences for a clinic.
 type NotificationPrefs struct {
 	// Email notifications
@@ -33,6 +42,10 @@ type NotificationPrefs struct {
 	// What to notify about
 	NotifyOnPayment bool `json:"notify_on_payment"`  // When deposit is paid
 	NotifyOnNewLead bool `json:"notify_on_new_lead"` // When new lead comes in
+
+	// ChatWebhooks post human-readable event summaries to Slack-compatible
+	// incoming webhooks (Slack, Teams, Mattermost).
+	ChatWebhooks []ChatWebhook `json:"chat_webhooks,omitempty"`
 }
 
 // GetSMSRecipients returns all configured SMS recipients, merging legacy single
@@ -85,6 +98,9 @@ type Config struct {
 	// LegalName is the business name exactly as it appears on IRS filings.
 	// Required for 10DLC brand registration. May differ from the DBA/display Name.
 	LegalName string `json:"legal_name,omitempty"`
+	// NameAliases are other names patients use for the clinic (old names,
+	// short forms), so a patient asking for one isn't taken for a wrong number.
+	NameAliases []string `json:"name_aliases,omitempty"`
 	// EIN is the Employer Identification Number for 10DLC registration.
 	EIN        string `json:"ein,omitempty"`
 	Email      string `json:"email,omitempty"`
@@ -94,6 +110,9 @@ type Config struct {
 	State      string `json:"state,omitempty"`
 	ZipCode    string `json:"zip_code,omitempty"`
 	WebsiteURL string `json:"website_url,omitempty"`
+	// Country is the clinic's ISO 3166 region ("US", "CA"). Patient phone
+	// numbers entered without a country code are read in it; empty means US.
+	Country string `json:"country,omitempty"`
 	// SMSPhoneNumber is the clinic's phone number used for SMS (may differ from main phone).
 	SMSPhoneNumber string `json:"sms_phone_number,omitempty"`
 	// SMSPhoneType is "landline", "voip", or "cell" — determines LOA eligibility.
@@ -114,6 +133,16 @@ type Config struct {
 	DepositAmountCents     int           `json:"deposit_amount_cents"` // e.g., 5000
 	// ServiceDepositAmountCents overrides the default deposit per service (keyed by normalized service name).
 	ServiceDepositAmountCents map[string]int `json:"service_deposit_amount_cents,omitempty"`
+	// ServiceDepositPolicies controls whether a service requires a deposit at all
+	// (keyed by normalized service name). Services without a policy require one.
+	ServiceDepositPolicies map[string]ServiceDepositPolicy `json:"service_deposit_policies,omitempty"`
+	// ServiceDurationMinutes is how long each service's appointment runs (keyed by
+	// normalized service name). Slots that would run past closing are not offered.
+	ServiceDurationMinutes map[string]int `json:"service_duration_minutes,omitempty"`
+	// ServiceCategories groups services into menu categories, e.g.
+	// {"tox": "Injectables", "hydrafacial": "Facials"} (keyed by normalized
+	// service name). Services without a category are listed under "Other".
+	ServiceCategories map[string]string `json:"service_categories,omitempty"`
 	// ServicePriceText provides a human-readable price string per service (keyed by normalized service name).
 	ServicePriceText map[string]string `json:"service_price_text,omitempty"`
 	Services         []string          `json:"services,omitempty"` // e.g., ["Botox", "Fillers"]
@@ -147,11 +176,38 @@ type Config struct {
 	// Example: {"weight loss": ["Weight Loss Consultation - In Person", "Weight Loss Consultation - Virtual"]}
 	ServiceVariants map[string][]string `json:"service_variants,omitempty"`
 
+	// ExtraQualifications maps a service to one more answer the AI collects,
+	// after the standard qualifications, before fetching availability — e.g.
+	// the body area for laser hair removal. Keys are normalized (lowercased).
+	ExtraQualifications map[string]ExtraQualification `json:"extra_qualifications,omitempty"`
+
+	// NotOffered lists services patients ask for that the clinic doesn't
+	// provide. The AI declines them with a fixed reply that suggests a related
+	// service instead of starting qualification.
+	NotOffered []NotOfferedService `json:"not_offered,omitempty"`
+
+	// ConsultRequired lists services the clinic books only after a
+	// consultation. The AI explains the policy and books the mapped
+	// consultation instead, noting the service the patient asked about.
+	ConsultRequired []ConsultRequiredService `json:"consult_required,omitempty"`
+
+	// AutoApplyDeposits marks a paid deposit as applied to the service a day
+	// after its appointment ended, unless staff marked the booking a no-show.
+	// Off by default: staff mark deposits applied at checkout in the portal,
+	// and a daily digest lists the ones they missed.
+	AutoApplyDeposits bool `json:"auto_apply_deposits,omitempty"`
+
 	// BookingPolicies are shown to the patient BEFORE the payment link so they
 	// give informed consent (e.g., 24-hour cancellation, no-show fee).
 	// Each string is sent as a separate line in the pre-payment SMS.
+	// Ignored once PaymentDisclosure is set.
 	BookingPolicies []string `json:"booking_policies,omitempty"`
 
+	// PaymentDisclosure is the clinic's cancellation and refund policy sent
+	// before the payment link. Nil sends the standard 24-hour forfeiture
+	// notice with BookingPolicies.
+	PaymentDisclosure *PaymentDisclosure `json:"payment_disclosure,omitempty"`
+
 	// MoxieConfig holds Moxie-specific IDs needed for direct GraphQL API booking.
 	// Only used when BookingPlatform == "moxie".
 	MoxieConfig *MoxieConfig `json:"moxie_config,omitempty"`
@@ -172,6 +228,10 @@ type Config struct {
 	// ProviderNames maps provider IDs/slugs to display names for non-Moxie clinics (e.g. Boulevard).
 	ProviderNames map[string]string `json:"provider_names,omitempty"`
 
+	// BlackoutDates are clinic-wide or per-provider date ranges whose slots are
+	// never presented, even when the booking platform reports them as open.
+	BlackoutDates []BlackoutDate `json:"blackout_dates,omitempty"`
+
 	// VoiceAIEnabled controls whether inbound voice calls use Telnyx Voice AI.
 	// When false (default), calls fall through to voicemail → SMS text-back flow.
 	VoiceAIEnabled bool `json:"voice_ai_enabled"`
@@ -179,6 +239,126 @@ type Config struct {
 	TelnyxAssistantID string `json:"telnyx_assistant_id,omitempty"`
 	// VoiceAIConfig holds voice-specific settings for Telnyx AI Assistant integration.
 	VoiceAIConfig *VoiceAIConfig `json:"voice_ai_config,omitempty"`
+
+	// VoiceIVREnabled answers inbound calls with a short greeting offering
+	// "press 1 to get a text" before falling through to voicemail.
+	VoiceIVREnabled bool `json:"voice_ivr_enabled,omitempty"`
+	// VoiceIVRGreeting overrides the spoken IVR greeting. Defaults to a
+	// greeting naming the clinic.
+	VoiceIVRGreeting string `json:"voice_ivr_greeting,omitempty"`
+
+	// FirstContactTemplate is texted, once, in reply to a new patient's first
+	// inbound message, ahead of the AI reply. Use it for brand voice and the
+	// carrier-required compliance footer. Supports the {{clinic_name}} and
+	// {{service}} placeholders. Empty keeps the generic first-contact ack.
+	FirstContactTemplate string `json:"first_contact_template,omitempty"`
+
+	// AckTemplates are the instant acks ("Got it - give me a moment") texted
+	// while the AI reply is prepared, one picked per message. Supports the
+	// {{clinic_name}} placeholder. Empty uses the default set.
+	AckTemplates []string `json:"ack_templates,omitempty"`
+	// DisableAcks stops the instant ack and progress texts, so the patient
+	// hears nothing until the AI reply. A FirstContactTemplate is still sent.
+	DisableAcks bool `json:"disable_acks,omitempty"`
+
+	// DataRetentionMonths is how long patient conversations and PII are kept
+	// after the last activity before the retention job purges them.
+	// Zero means DefaultDataRetentionMonths.
+	DataRetentionMonths int `json:"data_retention_months,omitempty"`
+
+	// TestPhones are numbers staff use to try out the assistant. Their
+	// conversations are left out of the portal's response-time and
+	// conversion stats.
+	TestPhones []string `json:"test_phones,omitempty"`
+
+	// AvailabilityHealth configures the scheduled availability probe that
+	// catches booking platform breakage before patients stop seeing slots.
+	AvailabilityHealth *AvailabilityHealthConfig `json:"availability_health,omitempty"`
+
+	// InboundHealth configures the inbound liveness check that catches a
+	// clinic's number silently losing its inbound webhooks.
+	InboundHealth *InboundHealthConfig `json:"inbound_health,omitempty"`
+
+	// MarketingConsentPrompt asks the patient once, after their first confirmed
+	// booking, whether they want promotional texts. Off by default.
+	//
+	// Deprecated: read through FlagMarketingConsentPrompt, which falls back to
+	// this field until the flag is set.
+	MarketingConsentPrompt bool `json:"marketing_consent_prompt,omitempty"`
+
+	// PromptOverrides customizes sections of the SMS system prompt without a
+	// deploy. See PromptSections for the section IDs.
+	PromptOverrides []PromptOverride `json:"prompt_overrides,omitempty"`
+
+	// MessageTemplates replaces the copy of registered outbound templates,
+	// keyed by template ID (see templates.Default). For templates sent in
+	// rotation the text becomes the only variant. Entries win over the
+	// older per-message fields such as AckTemplates and FarewellMessage.
+	MessageTemplates map[string]string `json:"message_templates,omitempty"`
+
+	// LLMOverrides replaces the conversation model, temperature or max tokens
+	// for this clinic. Nil uses the service defaults.
+	LLMOverrides *LLMOverrides `json:"llm_overrides,omitempty"`
+
+	// PaymentMethods lists the payment methods, financing providers and
+	// insurance stance patients are told about. Nil means unknown: the
+	// assistant says the team will confirm rather than guessing.
+	PaymentMethods *PaymentMethods `json:"payment_methods,omitempty"`
+
+	// ContextTokenBudget caps the estimated tokens of per-turn context (deposit
+	// state, lead preferences, clinic details, knowledge snippets, EMR
+	// availability) added to the prompt. Zero uses the service default.
+	ContextTokenBudget int `json:"context_token_budget,omitempty"`
+
+	// ContextPriority reorders the context blocks kept when the budget runs
+	// out; see ContextBlocks for the IDs. Deposit state is always kept first.
+	ContextPriority []string `json:"context_priority,omitempty"`
+
+	// AttachmentRules overrides how texted attachments are handled, keyed by
+	// attachment kind ("image", "vcard", "pdf", "unknown"). Kinds without a
+	// rule use the defaults: images go to the team, contact cards fill in the
+	// lead, and anything else gets a polite can't-open reply.
+	AttachmentRules map[string]AttachmentRule `json:"attachment_rules,omitempty"`
+
+	// AvailabilitySource pins availability lookups to one source
+	// ("moxie_api"). Empty lets the router pick the healthiest source and
+	// fall back between them.
+	AvailabilitySource string `json:"availability_source,omitempty"`
+
+	// Reengagement customizes the nudges texted to leads who stop replying
+	// mid-qualification. Nil uses the default timing and wording.
+	Reengagement *ReengagementConfig `json:"reengagement,omitempty"`
+
+	// CRMWebhooks receive signed lead lifecycle events for the clinic's CRM.
+	CRMWebhooks []CRMWebhook `json:"crm_webhooks,omitempty"`
+
+	// FeatureFlags overrides registered per-clinic behaviors. See
+	// KnownFlags for the names and defaults.
+	FeatureFlags FeatureFlags `json:"feature_flags,omitempty"`
+
+	// Inactive marks an offboarded clinic. Its config is kept, but inbound
+	// texts only get FarewellMessage and no conversation is started.
+	Inactive bool `json:"inactive,omitempty"`
+	// DeactivatedAt is when the clinic was last marked inactive.
+	DeactivatedAt *time.Time `json:"deactivated_at,omitempty"`
+	// FarewellMessage is texted to patients who message an inactive clinic.
+	// Supports the {{clinic_name}} and {{clinic_phone}} placeholders. Empty
+	// uses DefaultFarewellMessage.
+	FarewellMessage string `json:"farewell_message,omitempty"`
+}
+
+// AvailabilitySourceMoxieAPI is the availability source a clinic can be
+// pinned to. Only sources the availability router registers belong here.
+const AvailabilitySourceMoxieAPI = "moxie_api"
+
+// ValidAvailabilitySource reports whether source is empty (automatic routing)
+// or a known availability source.
+func ValidAvailabilitySource(source string) bool {
+	switch source {
+	case "", AvailabilitySourceMoxieAPI:
+		return true
+	}
+	return false
 }
 
 // VoiceAIConfig holds voice AI configuration for a clinic.
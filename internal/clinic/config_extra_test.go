@@ -165,6 +165,42 @@ func TestGetServiceVariants(t *testing.T) {
 	}
 }
 
+func TestExtraQualificationFor(t *testing.T) {
+	cfg := &Config{
+		ExtraQualifications: map[string]ExtraQualification{
+			"laser":              {Key: "skin_type", Question: "What's your skin type?"},
+			"laser hair removal": {Key: "area", Question: "Which area would you like treated?"},
+			"iv therapy":         {Key: "drip"},
+		},
+	}
+
+	tests := []struct {
+		name    string
+		cfg     *Config
+		service string
+		wantKey string
+	}{
+		{"nil config", nil, "laser hair removal", ""},
+		{"exact match", cfg, "Laser Hair Removal", "area"},
+		{"longest key wins", cfg, "laser hair removal - full body", "area"},
+		{"shorter key", cfg, "laser facial", "skin_type"},
+		{"missing question ignored", cfg, "iv therapy", ""},
+		{"no match", cfg, "botox", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.cfg.ExtraQualificationFor(tt.service)
+			gotKey := ""
+			if got != nil {
+				gotKey = got.Key
+			}
+			if gotKey != tt.wantKey {
+				t.Errorf("ExtraQualificationFor(%q) key = %q, want %q", tt.service, gotKey, tt.wantKey)
+			}
+		})
+	}
+}
+
 func TestUsesVagaroBooking(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -226,6 +262,15 @@ func TestDepositAmountForService(t *testing.T) {
 		}, "fillers", 5000},
 		{"empty service name", &Config{DepositAmountCents: 5000}, "", 5000},
 		{"zero default", &Config{}, "botox", 0},
+		{"policy override wins", &Config{
+			DepositAmountCents:        5000,
+			ServiceDepositAmountCents: map[string]int{"botox": 7500},
+			ServiceDepositPolicies:    map[string]ServiceDepositPolicy{"botox": {RequireDeposit: true, AmountCents: 10000}},
+		}, "Botox", 10000},
+		{"exempt service", &Config{
+			DepositAmountCents:     5000,
+			ServiceDepositPolicies: map[string]ServiceDepositPolicy{"consultation": {RequireDeposit: false}},
+		}, "Consultation", 0},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -236,6 +281,35 @@ func TestDepositAmountForService(t *testing.T) {
 	}
 }
 
+func TestDepositRequiredForService(t *testing.T) {
+	cfg := &Config{
+		ServiceAliases: map[string]string{"consult": "Consultation"},
+		ServiceDepositPolicies: map[string]ServiceDepositPolicy{
+			"consultation": {RequireDeposit: false},
+			"botox":        {RequireDeposit: true, AmountCents: 7500},
+		},
+	}
+	tests := []struct {
+		service string
+		want    bool
+	}{
+		{"Consultation", false},
+		{"consult", false},
+		{"Botox", true},
+		{"fillers", true},
+		{"", true},
+	}
+	for _, tt := range tests {
+		if got := cfg.DepositRequiredForService(tt.service); got != tt.want {
+			t.Errorf("DepositRequiredForService(%q) = %v, want %v", tt.service, got, tt.want)
+		}
+	}
+	var nilCfg *Config
+	if !nilCfg.DepositRequiredForService("botox") {
+		t.Error("nil config should require a deposit")
+	}
+}
+
 func TestPriceTextForService(t *testing.T) {
 	cfg := &Config{
 		ServicePriceText: map[string]string{
@@ -267,6 +341,57 @@ func TestPriceTextForService(t *testing.T) {
 	}
 }
 
+func TestDurationForService(t *testing.T) {
+	cfg := &Config{
+		ServiceDurationMinutes: map[string]int{"microneedling": 75, "tox": 30},
+		ServiceAliases:         map[string]string{"botox": "Tox"},
+	}
+	tests := []struct {
+		cfg     *Config
+		service string
+		want    time.Duration
+	}{
+		{nil, "tox", 0},
+		{cfg, "Microneedling", 75 * time.Minute},
+		{cfg, "botox", 30 * time.Minute},
+		{cfg, "laser", 0},
+		{cfg, "", 0},
+	}
+	for _, tt := range tests {
+		if got := tt.cfg.DurationForService(tt.service); got != tt.want {
+			t.Errorf("DurationForService(%q) = %v, want %v", tt.service, got, tt.want)
+		}
+	}
+}
+
+func TestEndsBeforeClose(t *testing.T) {
+	cfg := &Config{
+		Timezone:      "America/New_York",
+		BusinessHours: BusinessHours{Thursday: &DayHours{Open: "10:00", Close: "21:00"}},
+	}
+	loc, _ := time.LoadLocation("America/New_York")
+	at := func(h, m int) time.Time { return time.Date(2026, 2, 26, h, m, 0, 0, loc) }
+
+	tests := []struct {
+		name     string
+		start    time.Time
+		duration time.Duration
+		want     bool
+	}{
+		{"ends exactly at close", at(19, 45), 75 * time.Minute, true},
+		{"runs past close", at(20, 0), 75 * time.Minute, false},
+		{"short service late", at(20, 30), 30 * time.Minute, true},
+		{"unknown duration", at(20, 45), 0, true},
+		{"no hours that day", at(20, 45).AddDate(0, 0, 1), 75 * time.Minute, true},
+		{"utc input converted", at(20, 0).UTC(), 75 * time.Minute, false},
+	}
+	for _, tt := range tests {
+		if got := cfg.EndsBeforeClose(tt.start, tt.duration); got != tt.want {
+			t.Errorf("%s: EndsBeforeClose = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
 func TestAIPersonaContext(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -367,6 +492,44 @@ func TestResolveServiceName_PluralAndFuzzy(t *testing.T) {
 	}
 }
 
+func TestServiceMenuItemID_NormalizedNames(t *testing.T) {
+	cfg := &Config{
+		Services: []string{"Chemical Peel", "Microneedling", "Botox"},
+		MoxieConfig: &MoxieConfig{
+			ServiceMenuItems: map[string]string{
+				"chemical peel": "30010",
+				"microneedling": "30011",
+				"botox":         "30012",
+			},
+		},
+	}
+
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"chemical peels", "30010"},
+		{"Micro Needling", "30011"},
+		{"micro-needling", "30011"},
+		{"botx", "30012"},
+		{"lip flip", "30012"},
+		{"peel", ""},
+		{"tattoo removal", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			if got := cfg.ServiceMenuItemID(tt.input); got != tt.want {
+				t.Errorf("ServiceMenuItemID(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+
+	var nilCfg *Config
+	if got := nilCfg.ServiceMenuItemID("botox"); got != "" {
+		t.Errorf("nil config returned %q", got)
+	}
+}
+
 func TestIsOpenAt_NoBusinessHours(t *testing.T) {
 	// Clinic with no business hours configured (appointment-only)
 	cfg := &Config{
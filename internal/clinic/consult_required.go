@@ -0,0 +1,134 @@
+package clinic
+
+import (
+	"errors"
+	"strings"
+)
+
+// ConsultRequiredService is a service the clinic won't book directly: the
+// patient books a consultation first, e.g. Radiesse or Kybella.
+type ConsultRequiredService struct {
+	// Service is the patient-facing name, e.g. "Radiesse".
+	Service string `json:"service"`
+	// Aliases are other words patients use for it, e.g. ["radiesse filler"].
+	Aliases []string `json:"aliases,omitempty"`
+	// ConsultService is the service booked instead, e.g. "Filler Consultation".
+	ConsultService string `json:"consult_service"`
+	// ConsultServiceID is the booking platform's ID for ConsultService, e.g.
+	// a Moxie serviceMenuItemId. Empty looks ConsultService up like any other
+	// service.
+	ConsultServiceID string `json:"consult_service_id,omitempty"`
+}
+
+// ValidateConsultRequired checks that every entry names a service and the
+// consultation booked in its place.
+func ValidateConsultRequired(entries []ConsultRequiredService) error {
+	for _, entry := range entries {
+		if strings.TrimSpace(entry.Service) == "" || strings.TrimSpace(entry.ConsultService) == "" {
+			return errors.New("consult_required entries need a service and a consult_service")
+		}
+	}
+	return nil
+}
+
+// ConsultRequiredServiceIn returns the consult-first service the text asks
+// for, if any. As with NotOfferedServiceIn, a longer offered name that isn't
+// consult-first wins.
+func (c *Config) ConsultRequiredServiceIn(text string) (ConsultRequiredService, bool) {
+	if c == nil || len(c.ConsultRequired) == 0 {
+		return ConsultRequiredService{}, false
+	}
+	text = strings.ToLower(text)
+	best, bestLen := -1, 0
+	for i, entry := range c.ConsultRequired {
+		for _, term := range c.consultRequiredTerms(entry) {
+			if len(term) > bestLen && containsTerm(text, term) {
+				best, bestLen = i, len(term)
+			}
+		}
+	}
+	if best < 0 {
+		return ConsultRequiredService{}, false
+	}
+	for _, offered := range c.offeredTerms() {
+		if len(offered) > bestLen && containsTerm(text, offered) {
+			if _, ok := c.ConsultRequiredFor(offered); !ok {
+				return ConsultRequiredService{}, false
+			}
+		}
+	}
+	return c.ConsultRequired[best], true
+}
+
+// ConsultRequiredFor returns the consult-first entry for a service name, or
+// for the service its alias resolves to.
+func (c *Config) ConsultRequiredFor(service string) (ConsultRequiredService, bool) {
+	if c == nil || len(c.ConsultRequired) == 0 {
+		return ConsultRequiredService{}, false
+	}
+	key := normalizeServiceKey(service)
+	if key == "" {
+		return ConsultRequiredService{}, false
+	}
+	resolved := normalizeServiceKey(c.ResolveServiceName(service))
+	for _, entry := range c.ConsultRequired {
+		for _, term := range c.consultRequiredTerms(entry) {
+			if key == term || resolved == term {
+				return entry, true
+			}
+		}
+	}
+	return ConsultRequiredService{}, false
+}
+
+// BookingServiceFor returns the service a patient's interest is booked as:
+// the consultation for a consult-first service, otherwise the service itself.
+func (c *Config) BookingServiceFor(service string) string {
+	if entry, ok := c.ConsultRequiredFor(service); ok {
+		return strings.TrimSpace(entry.ConsultService)
+	}
+	return service
+}
+
+// ConsultServiceID returns the configured booking platform ID when service,
+// or the booking platform name it resolves to, is a consultation booked in
+// place of a consult-first service.
+func (c *Config) ConsultServiceID(service string) string {
+	if c == nil {
+		return ""
+	}
+	key := normalizeServiceKey(service)
+	if key == "" {
+		return ""
+	}
+	for _, entry := range c.ConsultRequired {
+		if entry.ConsultServiceID == "" {
+			continue
+		}
+		if consult := normalizeServiceKey(entry.ConsultService); consult == key || normalizeServiceKey(c.ResolveServiceName(entry.ConsultService)) == key {
+			return entry.ConsultServiceID
+		}
+	}
+	return ""
+}
+
+// consultRequiredTerms returns the entry's name, its aliases, and any clinic
+// service alias that resolves to it, normalized.
+func (c *Config) consultRequiredTerms(entry ConsultRequiredService) []string {
+	name := normalizeServiceKey(entry.Service)
+	if name == "" {
+		return nil
+	}
+	terms := []string{name}
+	for _, alias := range entry.Aliases {
+		if alias = normalizeServiceKey(alias); alias != "" {
+			terms = append(terms, alias)
+		}
+	}
+	for alias, target := range c.ServiceAliases {
+		if normalizeServiceKey(target) == name {
+			terms = append(terms, normalizeServiceKey(alias))
+		}
+	}
+	return terms
+}
@@ -0,0 +1,70 @@
+package clinic
+
+import "fmt"
+
+// Context block IDs: the kinds of per-turn context added to the LLM prompt,
+// in default priority order.
+const (
+	// ContextBlockPayment is the patient's deposit state. It is always
+	// included, whatever the budget or priority.
+	ContextBlockPayment     = "payment"
+	ContextBlockPreferences = "preferences"
+	// ContextBlockClinic is business hours, the deposit amount, the persona,
+	// and service highlights and prices.
+	ContextBlockClinic = "clinic"
+	ContextBlockRAG    = "rag"
+	ContextBlockEMR    = "emr"
+)
+
+// ContextBlocks lists every context block ID in default priority order.
+var ContextBlocks = []string{
+	ContextBlockPayment,
+	ContextBlockPreferences,
+	ContextBlockClinic,
+	ContextBlockRAG,
+	ContextBlockEMR,
+}
+
+// ContextPriorityOrder returns the order context blocks are included in:
+// the clinic's ContextPriority, then any blocks it omits in default order.
+// Payment always comes first.
+func (c *Config) ContextPriorityOrder() []string {
+	order := []string{ContextBlockPayment}
+	seen := map[string]bool{ContextBlockPayment: true}
+	var custom []string
+	if c != nil {
+		custom = c.ContextPriority
+	}
+	for _, id := range append(append([]string{}, custom...), ContextBlocks...) {
+		if !seen[id] && knownContextBlock(id) {
+			seen[id] = true
+			order = append(order, id)
+		}
+	}
+	return order
+}
+
+func knownContextBlock(id string) bool {
+	for _, known := range ContextBlocks {
+		if known == id {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateContextPriority checks a context priority order before it is
+// saved.
+func ValidateContextPriority(order []string) error {
+	seen := make(map[string]bool)
+	for i, id := range order {
+		if !knownContextBlock(id) {
+			return fmt.Errorf("context_priority[%d]: unknown block %q", i, id)
+		}
+		if seen[id] {
+			return fmt.Errorf("context_priority[%d]: block %q listed more than once", i, id)
+		}
+		seen[id] = true
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+package clinic
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// minCRMWebhookSecretLen keeps signing secrets long enough to resist guessing.
+const minCRMWebhookSecretLen = 16
+
+// CRMWebhook is an endpoint in the clinic's CRM (HighLevel, HubSpot, ...)
+// that receives signed JSON lead lifecycle events. Name identifies the
+// endpoint on queued deliveries, so renaming one drops its pending retries.
+type CRMWebhook struct {
+	Name string `json:"name"`
+	URL  string `json:"url"`
+	// Secret signs each delivery with HMAC-SHA256. Never log it.
+	Secret string `json:"secret"`
+	// Events limits which events are delivered (lead.created, lead.qualified,
+	// deposit.paid, booking.confirmed, booking.cancelled). Empty means all.
+	Events []string `json:"events,omitempty"`
+}
+
+// crmWebhookEvents are the events an endpoint can subscribe to: the ones
+// crmhooks publishes.
+var crmWebhookEvents = map[string]bool{
+	"lead.created":      true,
+	"lead.qualified":    true,
+	"deposit.paid":      true,
+	"booking.confirmed": true,
+	"booking.cancelled": true,
+}
+
+// Wants reports whether the endpoint is subscribed to event.
+func (w CRMWebhook) Wants(event string) bool {
+	if len(w.Events) == 0 {
+		return true
+	}
+	for _, e := range w.Events {
+		if strings.EqualFold(strings.TrimSpace(e), event) {
+			return true
+		}
+	}
+	return false
+}
+
+// Redacted identifies the endpoint for logs without its path or query,
+// which some CRMs use to carry an API token.
+func (w CRMWebhook) Redacted() string {
+	host := ""
+	if u, err := url.Parse(w.URL); err == nil {
+		host = u.Host
+	}
+	return fmt.Sprintf("%s (%s)", w.Name, host)
+}
+
+// ValidateCRMWebhooks checks that every endpoint has a unique name, an https
+// URL, a signing secret and only subscribes to events that are published.
+func ValidateCRMWebhooks(hooks []CRMWebhook) error {
+	seen := make(map[string]bool, len(hooks))
+	for _, hook := range hooks {
+		name := strings.TrimSpace(hook.Name)
+		if name == "" {
+			return fmt.Errorf("crm webhook name is required")
+		}
+		if seen[strings.ToLower(name)] {
+			return fmt.Errorf("crm webhook name %q is used more than once", name)
+		}
+		seen[strings.ToLower(name)] = true
+		if u, err := url.Parse(hook.URL); err != nil || u.Scheme != "https" || u.Host == "" {
+			return fmt.Errorf("crm webhook %q url must be an https URL", name)
+		}
+		if len(hook.Secret) < minCRMWebhookSecretLen {
+			return fmt.Errorf("crm webhook %q secret must be at least %d characters", name, minCRMWebhookSecretLen)
+		}
+		for _, event := range hook.Events {
+			if !crmWebhookEvents[strings.ToLower(strings.TrimSpace(event))] {
+				return fmt.Errorf("crm webhook %q subscribes to unknown event %q", name, event)
+			}
+		}
+	}
+	return nil
+}
+
+// CRMWebhook returns the endpoint with the given name.
+func (c *Config) CRMWebhook(name string) (CRMWebhook, bool) {
+	if c == nil {
+		return CRMWebhook{}, false
+	}
+	for _, hook := range c.CRMWebhooks {
+		if strings.EqualFold(strings.TrimSpace(hook.Name), strings.TrimSpace(name)) {
+			return hook, true
+		}
+	}
+	return CRMWebhook{}, false
+}
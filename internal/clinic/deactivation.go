@@ -0,0 +1,73 @@
+package clinic
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/messaging/templates"
+)
+
+// Farewell template placeholders. {{clinic_name}} is shared with the
+// first-contact template.
+const PlaceholderClinicPhone = "{{clinic_phone}}"
+
+// DefaultFarewellMessage is texted to patients who message an inactive
+// clinic that has no FarewellMessage of its own.
+var DefaultFarewellMessage = templates.Text(templates.ComplianceFarewell)
+
+// farewellNameFallback fills {{clinic_name}} when the clinic has no name,
+// or no config at all.
+const farewellNameFallback = "the clinic"
+
+var (
+	// ErrInactive is returned for a clinic that has been deactivated.
+	ErrInactive = errors.New("clinic: inactive")
+	// ErrNotConfigured is returned for an org with no stored config.
+	ErrNotConfigured = errors.New("clinic: not configured")
+)
+
+// IsActive reports whether the clinic is still served. A nil config is not.
+func (c *Config) IsActive() bool {
+	return c != nil && !c.Inactive
+}
+
+// Farewell renders the text sent to patients who message an inactive or
+// unconfigured clinic. It is safe to call on a nil config.
+func (c *Config) Farewell() string {
+	return c.RenderFarewell().Body
+}
+
+// RenderFarewell is Farewell along with the template it was rendered from.
+func (c *Config) RenderFarewell() templates.Rendered {
+	var name, phone string
+	if c != nil {
+		name = strings.TrimSpace(c.Name)
+		phone = strings.TrimSpace(c.Phone)
+	}
+	if name == "" {
+		name = farewellNameFallback
+	}
+	id := templates.ComplianceFarewell
+	if phone == "" {
+		id = templates.ComplianceFarewellNoPhone
+	}
+	return c.RenderTemplate(id, templates.Params{"clinic_name": name, "clinic_phone": phone})
+}
+
+// GetActive retrieves the config of a clinic that is still served. It
+// returns ErrNotConfigured when no config is stored, and ErrInactive along
+// with the config when the clinic has been deactivated.
+func (s *Store) GetActive(ctx context.Context, orgID string) (*Config, error) {
+	cfg, found, err := s.Lookup(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, ErrNotConfigured
+	}
+	if !cfg.IsActive() {
+		return cfg, ErrInactive
+	}
+	return cfg, nil
+}
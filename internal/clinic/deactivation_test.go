@@ -0,0 +1,115 @@
+package clinic
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
+)
+
+func TestFarewell(t *testing.T) {
+	var missing *Config
+	if missing.IsActive() {
+		t.Fatal("a nil config is not active")
+	}
+	if got := missing.Farewell(); got != "This number is no longer monitored - please contact the clinic directly." {
+		t.Fatalf("nil config farewell = %q", got)
+	}
+
+	cfg := &Config{Name: "Glow", Phone: "555-0100"}
+	if got := cfg.Farewell(); got != "This number is no longer monitored - please call Glow at 555-0100." {
+		t.Fatalf("default farewell = %q", got)
+	}
+	cfg.FarewellMessage = "{{clinic_name}} has moved! Call {{clinic_phone}}."
+	if got := cfg.Farewell(); got != "Glow has moved! Call 555-0100." {
+		t.Fatalf("custom farewell = %q", got)
+	}
+}
+
+func TestStoreGetActive(t *testing.T) {
+	mr := miniredis.RunT(t)
+	store := NewStore(redis.NewClient(&redis.Options{Addr: mr.Addr()}))
+	ctx := context.Background()
+
+	if _, err := store.GetActive(ctx, "org-1"); !errors.Is(err, ErrNotConfigured) {
+		t.Fatalf("expected ErrNotConfigured, got %v", err)
+	}
+	if cfg, err := store.Get(ctx, "org-1"); err != nil || cfg == nil {
+		t.Fatalf("Get should still fall back to the default config: %v", err)
+	}
+
+	cfg := DefaultConfig("org-1")
+	cfg.Inactive = true
+	if err := store.Set(ctx, cfg); err != nil {
+		t.Fatalf("set: %v", err)
+	}
+	got, err := store.GetActive(ctx, "org-1")
+	if !errors.Is(err, ErrInactive) || got == nil || got.OrgID != "org-1" {
+		t.Fatalf("expected ErrInactive with the config, got %+v, %v", got, err)
+	}
+
+	cfg.Inactive = false
+	if err := store.Set(ctx, cfg); err != nil {
+		t.Fatalf("set: %v", err)
+	}
+	if _, err := store.GetActive(ctx, "org-1"); err != nil {
+		t.Fatalf("expected an active clinic, got %v", err)
+	}
+}
+
+func TestHandler_DeactivateReactivate(t *testing.T) {
+	mr := miniredis.RunT(t)
+	store := NewStore(redis.NewClient(&redis.Options{Addr: mr.Addr()}))
+	routes := NewHandler(store, logging.Default()).Routes()
+	do := func(path, body string) (*httptest.ResponseRecorder, ActivationState) {
+		rr := httptest.NewRecorder()
+		routes.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, path, strings.NewReader(body)))
+		var state ActivationState
+		if rr.Code == http.StatusOK {
+			if err := json.Unmarshal(rr.Body.Bytes(), &state); err != nil {
+				t.Fatalf("decode: %v", err)
+			}
+		}
+		return rr, state
+	}
+
+	if rr, _ := do("/org-1/reactivate", ""); rr.Code != http.StatusNotFound {
+		t.Fatalf("reactivating an unconfigured org = %d, want 404", rr.Code)
+	}
+
+	cfg := DefaultConfig("org-1")
+	cfg.Name = "Glow"
+	if err := store.Set(context.Background(), cfg); err != nil {
+		t.Fatalf("set: %v", err)
+	}
+	rr, state := do("/org-1/deactivate", `{"farewell_message": "We've closed. Thanks for choosing {{clinic_name}}!"}`)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("deactivate = %d: %s", rr.Code, rr.Body.String())
+	}
+	if state.Active || state.DeactivatedAt == nil || state.Farewell != "We've closed. Thanks for choosing Glow!" {
+		t.Fatalf("unexpected state: %+v", state)
+	}
+	stored, _, _ := store.Lookup(context.Background(), "org-1")
+	if !stored.Inactive || stored.Name != "Glow" {
+		t.Fatalf("expected the config kept and marked inactive: %+v", stored)
+	}
+
+	if rr, _ := do("/org-1/deactivate", `{nope`); rr.Code != http.StatusBadRequest {
+		t.Fatalf("bad body = %d, want 400", rr.Code)
+	}
+
+	rr, state = do("/org-1/reactivate", "")
+	if rr.Code != http.StatusOK || !state.Active || state.DeactivatedAt != nil {
+		t.Fatalf("reactivate = %d, %+v", rr.Code, state)
+	}
+	if stored, _ := store.GetActive(context.Background(), "org-1"); stored == nil || stored.FarewellMessage == "" {
+		t.Fatal("expected the farewell kept for the next deactivation")
+	}
+}
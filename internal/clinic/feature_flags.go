@@ -0,0 +1,221 @@
+package clinic
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
+)
+
+// Known feature flags.
+const (
+	// FlagMoxieAPIAvailability lets availability lookups use Moxie's GraphQL
+	// API. Off routes them to the remaining sources.
+	FlagMoxieAPIAvailability = "moxie_api_availability"
+	// FlagMoxieAPIBooking books Moxie appointments through the API once the
+	// deposit is paid. Off leaves booking to staff after the callback.
+	FlagMoxieAPIBooking = "moxie_api_booking"
+	// FlagMarketingConsentPrompt asks for promotional text consent after the
+	// first confirmed booking.
+	FlagMarketingConsentPrompt = "marketing_consent_prompt"
+	// FlagReengagementNudges texts leads who stop replying mid-qualification
+	// a follow-up, then a final nudge.
+	FlagReengagementNudges = "reengagement_nudges"
+)
+
+// DefaultFlagCacheTTL is how long a FlagCache trusts a clinic's flags before
+// reloading them.
+const DefaultFlagCacheTTL = 30 * time.Second
+
+// ErrUnknownFlag is returned for a flag name missing from the registry.
+var ErrUnknownFlag = errors.New("clinic: unknown feature flag")
+
+// FeatureFlags holds a clinic's flag overrides by name. Flags without an
+// entry use their registry default.
+type FeatureFlags map[string]bool
+
+// FlagDefinition describes a known flag.
+type FlagDefinition struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Default     bool   `json:"default"`
+	// legacy reads the config field the flag replaced, if any.
+	legacy func(*Config) bool
+}
+
+var flagRegistry = map[string]FlagDefinition{
+	FlagMoxieAPIAvailability: {
+		Name:        FlagMoxieAPIAvailability,
+		Description: "Fetch availability from Moxie's API for clinics with a Moxie config.",
+		Default:     true,
+	},
+	FlagMoxieAPIBooking: {
+		Name:        FlagMoxieAPIBooking,
+		Description: "Book Moxie appointments through the API after the deposit is paid.",
+		Default:     true,
+	},
+	FlagMarketingConsentPrompt: {
+		Name:        FlagMarketingConsentPrompt,
+		Description: "Ask for marketing text consent after the first confirmed booking.",
+		Default:     false,
+		legacy:      func(c *Config) bool { return c.MarketingConsentPrompt },
+	},
+	FlagReengagementNudges: {
+		Name:        FlagReengagementNudges,
+		Description: "Nudge leads who go quiet mid-qualification after 4 hours and again after 2 days.",
+		Default:     true,
+	},
+}
+
+// LookupFlag returns the registry definition for name.
+func LookupFlag(name string) (FlagDefinition, bool) {
+	def, ok := flagRegistry[name]
+	return def, ok
+}
+
+// KnownFlags returns every registered flag, sorted by name.
+func KnownFlags() []FlagDefinition {
+	out := make([]FlagDefinition, 0, len(flagRegistry))
+	for _, def := range flagRegistry {
+		out = append(out, def)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+// Flag returns the clinic's override for name, or def when it has none.
+func (c *Config) Flag(name string, def bool) bool {
+	if c == nil {
+		return def
+	}
+	if v, ok := c.FeatureFlags[name]; ok {
+		return v
+	}
+	return def
+}
+
+// FlagEnabled resolves a registered flag: the clinic's override, then the
+// config field it replaced, then the registry default. Unknown flags are off.
+func (c *Config) FlagEnabled(name string) bool {
+	def, ok := flagRegistry[name]
+	if !ok {
+		return false
+	}
+	if c != nil && def.legacy != nil {
+		if _, set := c.FeatureFlags[name]; !set {
+			return def.legacy(c)
+		}
+	}
+	return c.Flag(name, def.Default)
+}
+
+// FlagOverridden reports whether the clinic sets name explicitly.
+func (c *Config) FlagOverridden(name string) bool {
+	if c == nil {
+		return false
+	}
+	_, ok := c.FeatureFlags[name]
+	return ok
+}
+
+// SetFlag overrides a registered flag for the clinic.
+func (c *Config) SetFlag(name string, enabled bool) error {
+	if _, ok := flagRegistry[name]; !ok {
+		return fmt.Errorf("%w: %q", ErrUnknownFlag, name)
+	}
+	if c.FeatureFlags == nil {
+		c.FeatureFlags = FeatureFlags{}
+	}
+	c.FeatureFlags[name] = enabled
+	return nil
+}
+
+// ClearFlag drops the clinic's override so the flag falls back to its
+// default.
+func (c *Config) ClearFlag(name string) error {
+	if _, ok := flagRegistry[name]; !ok {
+		return fmt.Errorf("%w: %q", ErrUnknownFlag, name)
+	}
+	delete(c.FeatureFlags, name)
+	return nil
+}
+
+// ConfigGetter loads a clinic config. *Store implements it.
+type ConfigGetter interface {
+	Get(ctx context.Context, orgID string) (*Config, error)
+}
+
+type flagCacheEntry struct {
+	cfg     *Config
+	fetched time.Time
+}
+
+// FlagCache resolves flags from the clinic store, reloading each clinic's
+// config at most once per TTL so toggles apply without a restart. When the
+// store can't be reached it keeps serving the last flags it loaded, or the
+// registry defaults if it never loaded any.
+type FlagCache struct {
+	source ConfigGetter
+	ttl    time.Duration
+	now    func() time.Time
+	logger *logging.Logger
+
+	mu      sync.Mutex
+	entries map[string]flagCacheEntry
+}
+
+// NewFlagCache creates a cache over source. A non-positive ttl uses
+// DefaultFlagCacheTTL.
+func NewFlagCache(source ConfigGetter, ttl time.Duration, logger *logging.Logger) *FlagCache {
+	if ttl <= 0 {
+		ttl = DefaultFlagCacheTTL
+	}
+	if logger == nil {
+		logger = logging.Default()
+	}
+	return &FlagCache{
+		source:  source,
+		ttl:     ttl,
+		now:     time.Now,
+		logger:  logger,
+		entries: make(map[string]flagCacheEntry),
+	}
+}
+
+// Enabled resolves a registered flag for orgID.
+func (c *FlagCache) Enabled(ctx context.Context, orgID, name string) bool {
+	return c.config(ctx, orgID).FlagEnabled(name)
+}
+
+// config returns orgID's cached config, reloading it once the TTL passes.
+// It returns nil when nothing could ever be loaded.
+func (c *FlagCache) config(ctx context.Context, orgID string) *Config {
+	c.mu.Lock()
+	entry, ok := c.entries[orgID]
+	now := c.now()
+	c.mu.Unlock()
+	if ok && now.Sub(entry.fetched) < c.ttl {
+		return entry.cfg
+	}
+
+	cfg, err := c.source.Get(ctx, orgID)
+	if err != nil || cfg == nil {
+		if err != nil {
+			c.logger.Warn("feature flags: failed to load clinic config", "org_id", orgID, "error", err, "cached", ok)
+		}
+		if !ok {
+			return nil
+		}
+		// Keep the stale flags and wait another TTL before retrying.
+		cfg = entry.cfg
+	}
+
+	c.mu.Lock()
+	c.entries[orgID] = flagCacheEntry{cfg: cfg, fetched: now}
+	c.mu.Unlock()
+	return cfg
+}
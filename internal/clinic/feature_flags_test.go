@@ -0,0 +1,169 @@
+package clinic
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
+)
+
+func TestConfig_FlagResolution(t *testing.T) {
+	cfg := DefaultConfig("org-1")
+	if !cfg.FlagEnabled(FlagMoxieAPIAvailability) || cfg.FlagEnabled(FlagMarketingConsentPrompt) {
+		t.Fatal("expected registry defaults without overrides")
+	}
+	if cfg.FlagEnabled("no_such_flag") || !cfg.Flag("no_such_flag", true) {
+		t.Fatal("unknown flags are off unless the caller supplies a default")
+	}
+
+	// The legacy field still drives the flag until an override is set.
+	cfg.MarketingConsentPrompt = true
+	if !cfg.FlagEnabled(FlagMarketingConsentPrompt) {
+		t.Fatal("expected the legacy marketing consent field to apply")
+	}
+	if err := cfg.SetFlag(FlagMarketingConsentPrompt, false); err != nil {
+		t.Fatalf("SetFlag: %v", err)
+	}
+	if cfg.FlagEnabled(FlagMarketingConsentPrompt) {
+		t.Fatal("expected the override to win over the legacy field")
+	}
+
+	if err := cfg.SetFlag("no_such_flag", true); !errors.Is(err, ErrUnknownFlag) {
+		t.Fatalf("SetFlag unknown = %v, want ErrUnknownFlag", err)
+	}
+	if _, ok := cfg.FeatureFlags["no_such_flag"]; ok {
+		t.Fatal("unknown flag was stored")
+	}
+}
+
+type flakyConfigSource struct {
+	cfg   *Config
+	err   error
+	calls int
+}
+
+func (s *flakyConfigSource) Get(ctx context.Context, orgID string) (*Config, error) {
+	s.calls++
+	if s.err != nil {
+		return nil, s.err
+	}
+	clone := *s.cfg
+	clone.FeatureFlags = FeatureFlags{}
+	for k, v := range s.cfg.FeatureFlags {
+		clone.FeatureFlags[k] = v
+	}
+	return &clone, nil
+}
+
+func TestFlagCache_RefreshesAfterTTL(t *testing.T) {
+	ctx := context.Background()
+	source := &flakyConfigSource{cfg: DefaultConfig("org-1")}
+	cache := NewFlagCache(source, time.Minute, logging.Default())
+	now := time.Date(2030, 1, 1, 9, 0, 0, 0, time.UTC)
+	cache.now = func() time.Time { return now }
+
+	if !cache.Enabled(ctx, "org-1", FlagMoxieAPIBooking) {
+		t.Fatal("expected the default on first load")
+	}
+	_ = source.cfg.SetFlag(FlagMoxieAPIBooking, false)
+
+	now = now.Add(30 * time.Second)
+	if !cache.Enabled(ctx, "org-1", FlagMoxieAPIBooking) || source.calls != 1 {
+		t.Fatalf("expected the cached value within the TTL (calls=%d)", source.calls)
+	}
+
+	now = now.Add(31 * time.Second)
+	if cache.Enabled(ctx, "org-1", FlagMoxieAPIBooking) || source.calls != 2 {
+		t.Fatalf("expected the toggle to apply after the TTL (calls=%d)", source.calls)
+	}
+}
+
+func TestFlagCache_StoreUnreachable(t *testing.T) {
+	ctx := context.Background()
+	down := errors.New("redis: connection refused")
+
+	// Never loaded: registry defaults.
+	cold := NewFlagCache(&flakyConfigSource{err: down}, time.Minute, logging.Default())
+	if !cold.Enabled(ctx, "org-1", FlagMoxieAPIAvailability) || cold.Enabled(ctx, "org-1", FlagMarketingConsentPrompt) {
+		t.Fatal("expected registry defaults when the store is unreachable")
+	}
+
+	// Loaded once: keep the last known flags.
+	cfg := DefaultConfig("org-1")
+	_ = cfg.SetFlag(FlagMoxieAPIAvailability, false)
+	source := &flakyConfigSource{cfg: cfg}
+	warm := NewFlagCache(source, time.Minute, logging.Default())
+	now := time.Date(2030, 1, 1, 9, 0, 0, 0, time.UTC)
+	warm.now = func() time.Time { return now }
+	if warm.Enabled(ctx, "org-1", FlagMoxieAPIAvailability) {
+		t.Fatal("expected the stored override")
+	}
+	source.err = down
+	now = now.Add(2 * time.Minute)
+	if warm.Enabled(ctx, "org-1", FlagMoxieAPIAvailability) {
+		t.Fatal("expected the stale override while the store is down")
+	}
+}
+
+func TestHandler_Flags(t *testing.T) {
+	mr := miniredis.RunT(t)
+	store := NewStore(redis.NewClient(&redis.Options{Addr: mr.Addr()}))
+	routes := NewHandler(store, logging.Default()).Routes()
+
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		routes.ServeHTTP(rr, httptest.NewRequest(method, path, strings.NewReader(body)))
+		return rr
+	}
+
+	rr := do(http.MethodPut, "/org-1/flags/"+FlagMoxieAPIAvailability, `{"enabled": false}`)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("set status = %d, body = %s", rr.Code, rr.Body.String())
+	}
+	var state FlagState
+	if err := json.Unmarshal(rr.Body.Bytes(), &state); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if state.Enabled || !state.Overridden || !state.Default {
+		t.Fatalf("unexpected flag state: %+v", state)
+	}
+	cfg, _ := store.Get(context.Background(), "org-1")
+	if cfg.FlagEnabled(FlagMoxieAPIAvailability) {
+		t.Fatal("expected the override saved to the store")
+	}
+
+	rr = do(http.MethodGet, "/org-1/flags", "")
+	var list struct {
+		Flags []FlagState `json:"flags"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &list); err != nil || len(list.Flags) != len(KnownFlags()) {
+		t.Fatalf("list = %s (err %v)", rr.Body.String(), err)
+	}
+
+	rr = do(http.MethodDelete, "/org-1/flags/"+FlagMoxieAPIAvailability, "")
+	if err := json.Unmarshal(rr.Body.Bytes(), &state); err != nil || !state.Enabled || state.Overridden {
+		t.Fatalf("reset = %s (err %v)", rr.Body.String(), err)
+	}
+
+	if rr := do(http.MethodPut, "/org-1/flags/calendar_magic", `{"enabled": true}`); rr.Code != http.StatusBadRequest {
+		t.Fatalf("unknown flag set status = %d, want 400", rr.Code)
+	}
+	if rr := do(http.MethodGet, "/org-1/flags/calendar_magic", ""); rr.Code != http.StatusNotFound {
+		t.Fatalf("unknown flag get status = %d, want 404", rr.Code)
+	}
+	if rr := do(http.MethodPut, "/org-1/flags/"+FlagMoxieAPIBooking, `{}`); rr.Code != http.StatusBadRequest {
+		t.Fatalf("missing enabled status = %d, want 400", rr.Code)
+	}
+	cfg, _ = store.Get(context.Background(), "org-1")
+	if _, ok := cfg.FeatureFlags["calendar_magic"]; ok {
+		t.Fatal("unknown flag was stored")
+	}
+}
@@ -0,0 +1,39 @@
+package clinic
+
+import (
+	"strings"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/messaging/templates"
+)
+
+// First-contact template placeholders.
+const (
+	PlaceholderClinicName = "{{clinic_name}}"
+	PlaceholderService    = "{{service}}"
+)
+
+// firstContactServiceFallback fills {{service}} when the patient hasn't
+// named a service.
+const firstContactServiceFallback = "our services"
+
+// FirstContactMessage renders the clinic's first-contact template for a
+// patient asking about service. Returns "" when no template is configured.
+func (c *Config) FirstContactMessage(service string) string {
+	return c.RenderFirstContact(service).Body
+}
+
+// RenderFirstContact is FirstContactMessage along with the template it was
+// rendered from.
+func (c *Config) RenderFirstContact(service string) templates.Rendered {
+	if c == nil {
+		return templates.Rendered{}
+	}
+	service = strings.TrimSpace(service)
+	if service == "" {
+		service = firstContactServiceFallback
+	}
+	return c.RenderTemplate(templates.AckFirstContact, templates.Params{
+		"clinic_name": strings.TrimSpace(c.Name),
+		"service":     service,
+	})
+}
@@ -5,6 +5,8 @@ import (
 	"encoding/json"
 	"io"
 	"net/http"
+	"net/url"
+	"strings"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
@@ -12,8 +14,9 @@ import (
 
 // Handler provides HTTP endpoints for clinic configuration management.
 type Handler struct {
-	store  *Store
-	logger *logging.Logger
+	store       *Store
+	logger      *logging.Logger
+	bookingURLs BookingURLValidator
 }
 
 // NewHandler creates a new clinic config HTTP handler.
@@ -33,6 +36,16 @@ func (h *Handler) Routes() chi.Router {
 	r.Get("/{orgID}/config", h.GetConfig)
 	r.Put("/{orgID}/config", h.UpdateConfig)
 	r.Post("/{orgID}/config", h.UpdateConfig) // Allow POST as well
+	r.Put("/{orgID}/booking-url", h.UpdateBookingURL)
+	r.Get("/{orgID}/blackouts", h.ListBlackouts)
+	r.Post("/{orgID}/blackouts", h.CreateBlackout)
+	r.Delete("/{orgID}/blackouts/{blackoutID}", h.DeleteBlackout)
+	r.Get("/{orgID}/flags", h.ListFlags)
+	r.Get("/{orgID}/flags/{flag}", h.GetFlag)
+	r.Put("/{orgID}/flags/{flag}", h.SetFlag)
+	r.Delete("/{orgID}/flags/{flag}", h.ResetFlag)
+	r.Post("/{orgID}/deactivate", h.Deactivate)
+	r.Post("/{orgID}/reactivate", h.Reactivate)
 	return r
 }
 
@@ -60,42 +73,69 @@ func (h *Handler) GetConfig(w http.ResponseWriter, r *http.Request) {
 
 // UpdateConfigRequest is the request body for updating clinic config.
 type UpdateConfigRequest struct {
-	Name                      string              `json:"name,omitempty"`
-	Email                     string              `json:"email,omitempty"`
-	Phone                     string              `json:"phone,omitempty"`
-	Address                   string              `json:"address,omitempty"`
-	City                      string              `json:"city,omitempty"`
-	State                     string              `json:"state,omitempty"`
-	ZipCode                   string              `json:"zip_code,omitempty"`
-	WebsiteURL                string              `json:"website_url,omitempty"`
-	Timezone                  string              `json:"timezone,omitempty"`
-	ClinicInfoConfirmed       *bool               `json:"clinic_info_confirmed,omitempty"`
-	BusinessHoursConfirmed    *bool               `json:"business_hours_confirmed,omitempty"`
-	ServicesConfirmed         *bool               `json:"services_confirmed,omitempty"`
-	ContactInfoConfirmed      *bool               `json:"contact_info_confirmed,omitempty"`
-	BusinessHours             *BusinessHours      `json:"business_hours,omitempty"`
-	CallbackSLAHours          *int                `json:"callback_sla_hours,omitempty"`
-	DepositAmountCents        *int                `json:"deposit_amount_cents,omitempty"`
-	Services                  []string            `json:"services,omitempty"`
-	BookingURL                string              `json:"booking_url,omitempty"`
-	BookingPlatform           string              `json:"booking_platform,omitempty"`
-	VagaroBusinessAlias       string              `json:"vagaro_business_alias,omitempty"`
-	Notifications             *NotificationPrefs  `json:"notifications,omitempty"`
-	AIPersona                 *AIPersona          `json:"ai_persona,omitempty"`
-	ServiceAliases            map[string]string   `json:"service_aliases,omitempty"`
-	MoxieConfig               *MoxieConfig        `json:"moxie_config,omitempty"`
-	PaymentProvider           string              `json:"payment_provider,omitempty"`
-	StripeAccountID           string              `json:"stripe_account_id,omitempty"`
-	BookingPolicies           []string            `json:"booking_policies,omitempty"`
-	ServicePriceText          map[string]string   `json:"service_price_text,omitempty"`
-	ServiceDepositAmountCents map[string]int      `json:"service_deposit_amount_cents,omitempty"`
-	ServiceVariants           map[string][]string `json:"service_variants,omitempty"`
-	VoiceAIEnabled            *bool               `json:"voice_ai_enabled,omitempty"`
-	TelnyxAssistantID         string              `json:"telnyx_assistant_id,omitempty"`
-	SMSPhoneNumber            string              `json:"sms_phone_number,omitempty"`
-	BoulevardBusinessID       string              `json:"boulevard_business_id,omitempty"`
-	BoulevardLocationID       string              `json:"boulevard_location_id,omitempty"`
-	ProviderNames             map[string]string   `json:"provider_names,omitempty"`
+	Name                      string                          `json:"name,omitempty"`
+	NameAliases               []string                        `json:"name_aliases,omitempty"`
+	Email                     string                          `json:"email,omitempty"`
+	Phone                     string                          `json:"phone,omitempty"`
+	Address                   string                          `json:"address,omitempty"`
+	City                      string                          `json:"city,omitempty"`
+	State                     string                          `json:"state,omitempty"`
+	ZipCode                   string                          `json:"zip_code,omitempty"`
+	WebsiteURL                string                          `json:"website_url,omitempty"`
+	Timezone                  string                          `json:"timezone,omitempty"`
+	ClinicInfoConfirmed       *bool                           `json:"clinic_info_confirmed,omitempty"`
+	BusinessHoursConfirmed    *bool                           `json:"business_hours_confirmed,omitempty"`
+	ServicesConfirmed         *bool                           `json:"services_confirmed,omitempty"`
+	ContactInfoConfirmed      *bool                           `json:"contact_info_confirmed,omitempty"`
+	BusinessHours             *BusinessHours                  `json:"business_hours,omitempty"`
+	CallbackSLAHours          *int                            `json:"callback_sla_hours,omitempty"`
+	DepositAmountCents        *int                            `json:"deposit_amount_cents,omitempty"`
+	Services                  []string                        `json:"services,omitempty"`
+	BookingURL                string                          `json:"booking_url,omitempty"`
+	BookingPlatform           string                          `json:"booking_platform,omitempty"`
+	VagaroBusinessAlias       string                          `json:"vagaro_business_alias,omitempty"`
+	Notifications             *NotificationPrefs              `json:"notifications,omitempty"`
+	AIPersona                 *AIPersona                      `json:"ai_persona,omitempty"`
+	ServiceAliases            map[string]string               `json:"service_aliases,omitempty"`
+	MoxieConfig               *MoxieConfig                    `json:"moxie_config,omitempty"`
+	PaymentProvider           string                          `json:"payment_provider,omitempty"`
+	StripeAccountID           string                          `json:"stripe_account_id,omitempty"`
+	BookingPolicies           []string                        `json:"booking_policies,omitempty"`
+	PaymentDisclosure         *PaymentDisclosure              `json:"payment_disclosure,omitempty"`
+	ServicePriceText          map[string]string               `json:"service_price_text,omitempty"`
+	ServiceDepositAmountCents map[string]int                  `json:"service_deposit_amount_cents,omitempty"`
+	ServiceDepositPolicies    map[string]ServiceDepositPolicy `json:"service_deposit_policies,omitempty"`
+	ServiceDurationMinutes    map[string]int                  `json:"service_duration_minutes,omitempty"`
+	ServiceCategories         map[string]string               `json:"service_categories,omitempty"`
+	ServiceVariants           map[string][]string             `json:"service_variants,omitempty"`
+	ExtraQualifications       map[string]ExtraQualification   `json:"extra_qualifications,omitempty"`
+	NotOffered                []NotOfferedService             `json:"not_offered,omitempty"`
+	ConsultRequired           []ConsultRequiredService        `json:"consult_required,omitempty"`
+	VoiceAIEnabled            *bool                           `json:"voice_ai_enabled,omitempty"`
+	TelnyxAssistantID         string                          `json:"telnyx_assistant_id,omitempty"`
+	VoiceIVREnabled           *bool                           `json:"voice_ivr_enabled,omitempty"`
+	VoiceIVRGreeting          *string                         `json:"voice_ivr_greeting,omitempty"`
+	FirstContactTemplate      *string                         `json:"first_contact_template,omitempty"`
+	AckTemplates              []string                        `json:"ack_templates,omitempty"`
+	DisableAcks               *bool                           `json:"disable_acks,omitempty"`
+	DataRetentionMonths       *int                            `json:"data_retention_months,omitempty"`
+	TestPhones                []string                        `json:"test_phones,omitempty"`
+	AvailabilityHealth        *AvailabilityHealthConfig       `json:"availability_health,omitempty"`
+	InboundHealth             *InboundHealthConfig            `json:"inbound_health,omitempty"`
+	MarketingConsentPrompt    *bool                           `json:"marketing_consent_prompt,omitempty"`
+	PromptOverrides           []PromptOverride                `json:"prompt_overrides,omitempty"`
+	MessageTemplates          map[string]string               `json:"message_templates,omitempty"`
+	LLMOverrides              *LLMOverrides                   `json:"llm_overrides,omitempty"`
+	PaymentMethods            *PaymentMethods                 `json:"payment_methods,omitempty"`
+	ContextTokenBudget        *int                            `json:"context_token_budget,omitempty"`
+	ContextPriority           []string                        `json:"context_priority,omitempty"`
+	AttachmentRules           map[string]AttachmentRule       `json:"attachment_rules,omitempty"`
+	AvailabilitySource        *string                         `json:"availability_source,omitempty"`
+	CRMWebhooks               []CRMWebhook                    `json:"crm_webhooks,omitempty"`
+	SMSPhoneNumber            string                          `json:"sms_phone_number,omitempty"`
+	BoulevardBusinessID       string                          `json:"boulevard_business_id,omitempty"`
+	BoulevardLocationID       string                          `json:"boulevard_location_id,omitempty"`
+	ProviderNames             map[string]string               `json:"provider_names,omitempty"`
 }
 
 // UpdateConfig creates or updates the clinic configuration for an org.
@@ -114,11 +154,12 @@ func (h *Handler) UpdateConfig(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, `{"error": "failed to read body"}`, http.StatusBadRequest)
 		return
 	}
-	h.logger.Info("received config update request", "org_id", orgID, "body", string(bodyBytes))
+	// The body can carry chat webhook URLs (secrets), so only its size is logged.
+	h.logger.Info("received config update request", "org_id", orgID, "bytes", len(bodyBytes))
 
 	var req UpdateConfigRequest
 	if err := json.NewDecoder(bytes.NewReader(bodyBytes)).Decode(&req); err != nil {
-		h.logger.Error("JSON decode failed", "org_id", orgID, "error", err, "body", string(bodyBytes))
+		h.logger.Error("JSON decode failed", "org_id", orgID, "error", err, "bytes", len(bodyBytes))
 		http.Error(w, `{"error": "invalid JSON body"}`, http.StatusBadRequest)
 		return
 	}
@@ -135,6 +176,9 @@ func (h *Handler) UpdateConfig(w http.ResponseWriter, r *http.Request) {
 	if req.Name != "" {
 		cfg.Name = req.Name
 	}
+	if req.NameAliases != nil {
+		cfg.NameAliases = req.NameAliases
+	}
 	if req.Email != "" {
 		cfg.Email = req.Email
 	}
@@ -193,6 +237,12 @@ func (h *Handler) UpdateConfig(w http.ResponseWriter, r *http.Request) {
 		cfg.VagaroBusinessAlias = req.VagaroBusinessAlias
 	}
 	if req.Notifications != nil {
+		for _, hook := range req.Notifications.ChatWebhooks {
+			if u, err := url.Parse(hook.URL); err != nil || u.Scheme != "https" || u.Host == "" {
+				http.Error(w, `{"error": "chat webhook url must be an https URL"}`, http.StatusBadRequest)
+				return
+			}
+		}
 		cfg.Notifications = *req.Notifications
 	}
 	if req.AIPersona != nil {
@@ -239,21 +289,242 @@ func (h *Handler) UpdateConfig(w http.ResponseWriter, r *http.Request) {
 	if len(req.BookingPolicies) > 0 {
 		cfg.BookingPolicies = req.BookingPolicies
 	}
+	if req.PaymentDisclosure != nil {
+		if err := ValidatePaymentDisclosure(req.PaymentDisclosure); err != nil {
+			body, _ := json.Marshal(map[string]string{"error": "payment_disclosure: " + err.Error()})
+			http.Error(w, string(body), http.StatusBadRequest)
+			return
+		}
+		cfg.PaymentDisclosure = req.PaymentDisclosure
+	}
 	if len(req.ServicePriceText) > 0 {
-		cfg.ServicePriceText = req.ServicePriceText
+		if err := ValidateServicePriceText(req.ServicePriceText); err != nil {
+			body, _ := json.Marshal(map[string]string{"error": "service_price_text: " + err.Error()})
+			http.Error(w, string(body), http.StatusBadRequest)
+			return
+		}
+		cfg.ServicePriceText = NormalizeServicePriceText(req.ServicePriceText)
 	}
 	if len(req.ServiceDepositAmountCents) > 0 {
 		cfg.ServiceDepositAmountCents = req.ServiceDepositAmountCents
 	}
+	if len(req.ServiceDepositPolicies) > 0 {
+		policies := make(map[string]ServiceDepositPolicy, len(req.ServiceDepositPolicies))
+		for service, policy := range req.ServiceDepositPolicies {
+			if key := normalizeServiceKey(service); key != "" {
+				policies[key] = policy
+			}
+		}
+		cfg.ServiceDepositPolicies = policies
+	}
+	if len(req.ServiceDurationMinutes) > 0 {
+		durations := make(map[string]int, len(req.ServiceDurationMinutes))
+		for service, minutes := range req.ServiceDurationMinutes {
+			if minutes < 0 {
+				http.Error(w, `{"error": "service_duration_minutes must not be negative"}`, http.StatusBadRequest)
+				return
+			}
+			if key := normalizeServiceKey(service); key != "" {
+				durations[key] = minutes
+			}
+		}
+		cfg.ServiceDurationMinutes = durations
+	}
+	if len(req.ServiceCategories) > 0 {
+		categories := make(map[string]string, len(req.ServiceCategories))
+		for service, category := range req.ServiceCategories {
+			key, category := normalizeServiceKey(service), strings.TrimSpace(category)
+			if key != "" && category != "" {
+				categories[key] = category
+			}
+		}
+		cfg.ServiceCategories = categories
+	}
 	if len(req.ServiceVariants) > 0 {
 		cfg.ServiceVariants = req.ServiceVariants
 	}
+	if req.ExtraQualifications != nil {
+		quals := make(map[string]ExtraQualification, len(req.ExtraQualifications))
+		for service, q := range req.ExtraQualifications {
+			q.Key = strings.TrimSpace(q.Key)
+			q.Question = strings.TrimSpace(q.Question)
+			if q.Key == "" || q.Question == "" {
+				http.Error(w, `{"error": "extra_qualifications entries need a key and a question"}`, http.StatusBadRequest)
+				return
+			}
+			if key := normalizeServiceKey(service); key != "" {
+				quals[key] = q
+			}
+		}
+		cfg.ExtraQualifications = quals
+	}
+	if req.NotOffered != nil {
+		entries := make([]NotOfferedService, 0, len(req.NotOffered))
+		for _, entry := range req.NotOffered {
+			entry.Service = strings.TrimSpace(entry.Service)
+			entry.Referral = strings.TrimSpace(entry.Referral)
+			if entry.Service == "" {
+				http.Error(w, `{"error": "not_offered entries need a service"}`, http.StatusBadRequest)
+				return
+			}
+			entries = append(entries, entry)
+		}
+		cfg.NotOffered = entries
+	}
+	if req.ConsultRequired != nil {
+		entries := make([]ConsultRequiredService, 0, len(req.ConsultRequired))
+		for _, entry := range req.ConsultRequired {
+			entry.Service = strings.TrimSpace(entry.Service)
+			entry.ConsultService = strings.TrimSpace(entry.ConsultService)
+			entry.ConsultServiceID = strings.TrimSpace(entry.ConsultServiceID)
+			entries = append(entries, entry)
+		}
+		if err := ValidateConsultRequired(entries); err != nil {
+			body, _ := json.Marshal(map[string]string{"error": err.Error()})
+			http.Error(w, string(body), http.StatusBadRequest)
+			return
+		}
+		cfg.ConsultRequired = entries
+	}
 	if req.VoiceAIEnabled != nil {
 		cfg.VoiceAIEnabled = *req.VoiceAIEnabled
 	}
 	if req.TelnyxAssistantID != "" {
 		cfg.TelnyxAssistantID = req.TelnyxAssistantID
 	}
+	if req.VoiceIVREnabled != nil {
+		cfg.VoiceIVREnabled = *req.VoiceIVREnabled
+	}
+	if req.VoiceIVRGreeting != nil {
+		cfg.VoiceIVRGreeting = *req.VoiceIVRGreeting
+	}
+	if req.FirstContactTemplate != nil {
+		cfg.FirstContactTemplate = strings.TrimSpace(*req.FirstContactTemplate)
+	}
+	if req.AckTemplates != nil {
+		templates := make([]string, 0, len(req.AckTemplates))
+		for _, tmpl := range req.AckTemplates {
+			if tmpl = strings.TrimSpace(tmpl); tmpl != "" {
+				templates = append(templates, tmpl)
+			}
+		}
+		cfg.AckTemplates = templates
+	}
+	if req.DisableAcks != nil {
+		cfg.DisableAcks = *req.DisableAcks
+	}
+	if req.DataRetentionMonths != nil {
+		if *req.DataRetentionMonths < 0 {
+			http.Error(w, `{"error": "data_retention_months must not be negative"}`, http.StatusBadRequest)
+			return
+		}
+		cfg.DataRetentionMonths = *req.DataRetentionMonths
+	}
+	if req.TestPhones != nil {
+		cfg.TestPhones = req.TestPhones
+	}
+	if req.AvailabilityHealth != nil {
+		if len(req.AvailabilityHealth.Services) > MaxAvailabilityProbeServices {
+			http.Error(w, `{"error": "availability_health.services allows at most 3 services"}`, http.StatusBadRequest)
+			return
+		}
+		cfg.AvailabilityHealth = req.AvailabilityHealth
+	}
+	if req.InboundHealth != nil {
+		if err := ValidateInboundHealth(req.InboundHealth); err != nil {
+			body, _ := json.Marshal(map[string]string{"error": err.Error()})
+			http.Error(w, string(body), http.StatusBadRequest)
+			return
+		}
+		cfg.InboundHealth = req.InboundHealth
+	}
+	if req.MarketingConsentPrompt != nil {
+		cfg.MarketingConsentPrompt = *req.MarketingConsentPrompt
+		_ = cfg.SetFlag(FlagMarketingConsentPrompt, *req.MarketingConsentPrompt)
+	}
+	if req.PromptOverrides != nil {
+		if err := ValidatePromptOverrides(req.PromptOverrides); err != nil {
+			body, _ := json.Marshal(map[string]string{"error": err.Error()})
+			http.Error(w, string(body), http.StatusBadRequest)
+			return
+		}
+		cfg.PromptOverrides = req.PromptOverrides
+	}
+	if req.MessageTemplates != nil {
+		if err := ValidateMessageTemplates(req.MessageTemplates); err != nil {
+			body, _ := json.Marshal(map[string]string{"error": err.Error()})
+			http.Error(w, string(body), http.StatusBadRequest)
+			return
+		}
+		cfg.MessageTemplates = req.MessageTemplates
+	}
+	if req.LLMOverrides != nil {
+		if err := ValidateLLMOverrides(req.LLMOverrides); err != nil {
+			body, _ := json.Marshal(map[string]string{"error": err.Error()})
+			http.Error(w, string(body), http.StatusBadRequest)
+			return
+		}
+		// An empty object clears the overrides.
+		cfg.LLMOverrides = nil
+		if !req.LLMOverrides.IsZero() {
+			overrides := *req.LLMOverrides
+			overrides.Model = strings.TrimSpace(overrides.Model)
+			cfg.LLMOverrides = &overrides
+		}
+	}
+	if req.PaymentMethods != nil {
+		if err := ValidatePaymentMethods(req.PaymentMethods); err != nil {
+			body, _ := json.Marshal(map[string]string{"error": err.Error()})
+			http.Error(w, string(body), http.StatusBadRequest)
+			return
+		}
+		// An empty object clears the payment methods.
+		cfg.PaymentMethods = nil
+		if !req.PaymentMethods.IsZero() {
+			methods := *req.PaymentMethods
+			methods.Insurance = strings.TrimSpace(methods.Insurance)
+			cfg.PaymentMethods = &methods
+		}
+	}
+	if req.ContextTokenBudget != nil {
+		if *req.ContextTokenBudget < 0 {
+			http.Error(w, `{"error": "context_token_budget must not be negative"}`, http.StatusBadRequest)
+			return
+		}
+		cfg.ContextTokenBudget = *req.ContextTokenBudget
+	}
+	if req.ContextPriority != nil {
+		if err := ValidateContextPriority(req.ContextPriority); err != nil {
+			body, _ := json.Marshal(map[string]string{"error": err.Error()})
+			http.Error(w, string(body), http.StatusBadRequest)
+			return
+		}
+		cfg.ContextPriority = req.ContextPriority
+	}
+	if req.AttachmentRules != nil {
+		if err := ValidateAttachmentRules(req.AttachmentRules); err != nil {
+			body, _ := json.Marshal(map[string]string{"error": err.Error()})
+			http.Error(w, string(body), http.StatusBadRequest)
+			return
+		}
+		cfg.AttachmentRules = req.AttachmentRules
+	}
+	if req.AvailabilitySource != nil {
+		source := strings.ToLower(strings.TrimSpace(*req.AvailabilitySource))
+		if !ValidAvailabilitySource(source) {
+			http.Error(w, `{"error": "availability_source must be moxie_api or empty"}`, http.StatusBadRequest)
+			return
+		}
+		cfg.AvailabilitySource = source
+	}
+	if req.CRMWebhooks != nil {
+		if err := ValidateCRMWebhooks(req.CRMWebhooks); err != nil {
+			body, _ := json.Marshal(map[string]string{"error": err.Error()})
+			http.Error(w, string(body), http.StatusBadRequest)
+			return
+		}
+		cfg.CRMWebhooks = req.CRMWebhooks
+	}
 	if req.SMSPhoneNumber != "" {
 		cfg.SMSPhoneNumber = req.SMSPhoneNumber
 	}
@@ -0,0 +1,124 @@
+package clinic
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// ListBlackouts returns the clinic's blackout dates.
+// GET /admin/clinics/{orgID}/blackouts
+func (h *Handler) ListBlackouts(w http.ResponseWriter, r *http.Request) {
+	orgID := chi.URLParam(r, "orgID")
+	if orgID == "" {
+		http.Error(w, `{"error": "org_id required"}`, http.StatusBadRequest)
+		return
+	}
+
+	cfg, err := h.store.Get(r.Context(), orgID)
+	if err != nil {
+		h.logger.Error("failed to get clinic config", "org_id", orgID, "error", err)
+		http.Error(w, `{"error": "internal server error"}`, http.StatusInternalServerError)
+		return
+	}
+
+	blackouts := cfg.BlackoutDates
+	if blackouts == nil {
+		blackouts = []BlackoutDate{}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(map[string]any{"blackout_dates": blackouts}); err != nil {
+		h.logger.Error("failed to encode blackout dates", "org_id", orgID, "error", err)
+	}
+}
+
+// CreateBlackout adds a clinic-wide or per-provider blackout range.
+// POST /admin/clinics/{orgID}/blackouts
+func (h *Handler) CreateBlackout(w http.ResponseWriter, r *http.Request) {
+	orgID := chi.URLParam(r, "orgID")
+	if orgID == "" {
+		http.Error(w, `{"error": "org_id required"}`, http.StatusBadRequest)
+		return
+	}
+
+	var req BlackoutDate
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, `{"error": "invalid JSON body"}`, http.StatusBadRequest)
+		return
+	}
+	req.StartDate = strings.TrimSpace(req.StartDate)
+	req.EndDate = strings.TrimSpace(req.EndDate)
+	req.ProviderID = strings.TrimSpace(req.ProviderID)
+	if err := req.Validate(); err != nil {
+		http.Error(w, fmt.Sprintf(`{"error": %q}`, err.Error()), http.StatusBadRequest)
+		return
+	}
+	req.ID = uuid.NewString()
+
+	cfg, err := h.store.Get(r.Context(), orgID)
+	if err != nil {
+		h.logger.Error("failed to get clinic config", "org_id", orgID, "error", err)
+		http.Error(w, `{"error": "internal server error"}`, http.StatusInternalServerError)
+		return
+	}
+	cfg.BlackoutDates = append(cfg.BlackoutDates, req)
+	if err := h.store.Set(r.Context(), cfg); err != nil {
+		h.logger.Error("failed to save clinic config", "org_id", orgID, "error", err)
+		http.Error(w, `{"error": "failed to save config"}`, http.StatusInternalServerError)
+		return
+	}
+
+	h.logger.Info("blackout added", "org_id", orgID, "blackout_id", req.ID,
+		"provider_id", req.ProviderID, "start", req.StartDate, "end", req.EndDate)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(req); err != nil {
+		h.logger.Error("failed to encode blackout", "org_id", orgID, "error", err)
+	}
+}
+
+// DeleteBlackout removes a blackout range.
+// DELETE /admin/clinics/{orgID}/blackouts/{blackoutID}
+func (h *Handler) DeleteBlackout(w http.ResponseWriter, r *http.Request) {
+	orgID := chi.URLParam(r, "orgID")
+	blackoutID := chi.URLParam(r, "blackoutID")
+	if orgID == "" || blackoutID == "" {
+		http.Error(w, `{"error": "org_id and blackout_id required"}`, http.StatusBadRequest)
+		return
+	}
+
+	cfg, err := h.store.Get(r.Context(), orgID)
+	if err != nil {
+		h.logger.Error("failed to get clinic config", "org_id", orgID, "error", err)
+		http.Error(w, `{"error": "internal server error"}`, http.StatusInternalServerError)
+		return
+	}
+
+	kept := cfg.BlackoutDates[:0]
+	found := false
+	for _, b := range cfg.BlackoutDates {
+		if b.ID == blackoutID {
+			found = true
+			continue
+		}
+		kept = append(kept, b)
+	}
+	if !found {
+		http.Error(w, `{"error": "blackout not found"}`, http.StatusNotFound)
+		return
+	}
+	cfg.BlackoutDates = kept
+	if err := h.store.Set(r.Context(), cfg); err != nil {
+		h.logger.Error("failed to save clinic config", "org_id", orgID, "error", err)
+		http.Error(w, `{"error": "failed to save config"}`, http.StatusInternalServerError)
+		return
+	}
+
+	h.logger.Info("blackout removed", "org_id", orgID, "blackout_id", blackoutID)
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,78 @@
+package clinic
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// BookingURLValidator checks that a URL serves a live booking page for the
+// given booking platform.
+type BookingURLValidator interface {
+	ValidateBookingURL(ctx context.Context, rawURL, platform string) error
+}
+
+// SetBookingURLValidator makes UpdateBookingURL fetch a new booking URL and
+// refuse it unless it serves a booking page.
+func (h *Handler) SetBookingURLValidator(v BookingURLValidator) {
+	h.bookingURLs = v
+}
+
+// UpdateBookingURLRequest is the request body for changing the booking URL.
+type UpdateBookingURLRequest struct {
+	BookingURL string `json:"booking_url"`
+}
+
+// UpdateBookingURL replaces the clinic's booking page URL, e.g. after the
+// clinic changes its Moxie slug. The new page is fetched before saving.
+// PUT /admin/clinics/{orgID}/booking-url
+func (h *Handler) UpdateBookingURL(w http.ResponseWriter, r *http.Request) {
+	orgID := chi.URLParam(r, "orgID")
+	if orgID == "" {
+		http.Error(w, `{"error": "org_id required"}`, http.StatusBadRequest)
+		return
+	}
+
+	var req UpdateBookingURLRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, `{"error": "invalid JSON body"}`, http.StatusBadRequest)
+		return
+	}
+	bookingURL := strings.TrimSpace(req.BookingURL)
+	if u, err := url.Parse(bookingURL); err != nil || u.Scheme != "https" || u.Host == "" {
+		http.Error(w, `{"error": "booking_url must be an https URL"}`, http.StatusBadRequest)
+		return
+	}
+
+	cfg, err := h.store.Get(r.Context(), orgID)
+	if err != nil {
+		h.logger.Error("failed to get clinic config", "org_id", orgID, "error", err)
+		http.Error(w, `{"error": "internal server error"}`, http.StatusInternalServerError)
+		return
+	}
+	if h.bookingURLs != nil {
+		if err := h.bookingURLs.ValidateBookingURL(r.Context(), bookingURL, cfg.BookingPlatform); err != nil {
+			h.logger.Warn("rejected booking URL", "org_id", orgID, "booking_url", bookingURL, "error", err)
+			http.Error(w, fmt.Sprintf(`{"error": %q}`, "booking page check failed: "+err.Error()), http.StatusUnprocessableEntity)
+			return
+		}
+	}
+
+	cfg.BookingURL = bookingURL
+	if err := h.store.Set(r.Context(), cfg); err != nil {
+		h.logger.Error("failed to save clinic config", "org_id", orgID, "error", err)
+		http.Error(w, `{"error": "failed to save config"}`, http.StatusInternalServerError)
+		return
+	}
+
+	h.logger.Info("booking URL updated", "org_id", orgID, "booking_url", bookingURL)
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(map[string]string{"booking_url": cfg.BookingURL}); err != nil {
+		h.logger.Error("failed to encode booking URL", "org_id", orgID, "error", err)
+	}
+}
@@ -0,0 +1,115 @@
+package clinic
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// ActivationState is a clinic's deactivation status.
+type ActivationState struct {
+	OrgID         string     `json:"org_id"`
+	Active        bool       `json:"active"`
+	DeactivatedAt *time.Time `json:"deactivated_at,omitempty"`
+	// Farewell is the rendered text inbound patients get while inactive.
+	Farewell string `json:"farewell"`
+}
+
+func activationState(cfg *Config) ActivationState {
+	return ActivationState{
+		OrgID:         cfg.OrgID,
+		Active:        cfg.IsActive(),
+		DeactivatedAt: cfg.DeactivatedAt,
+		Farewell:      cfg.Farewell(),
+	}
+}
+
+// DeactivateRequest is the optional request body for deactivating a clinic.
+type DeactivateRequest struct {
+	// FarewellMessage replaces the clinic's farewell text when set.
+	FarewellMessage *string `json:"farewell_message,omitempty"`
+}
+
+// Deactivate marks the clinic inactive. Its config is kept; inbound texts
+// get the farewell message and no conversation is started. An org whose
+// config is already gone gets a stub config so the farewell can be set.
+// POST /admin/clinics/{orgID}/deactivate
+func (h *Handler) Deactivate(w http.ResponseWriter, r *http.Request) {
+	orgID := chi.URLParam(r, "orgID")
+	if orgID == "" {
+		http.Error(w, `{"error": "org_id required"}`, http.StatusBadRequest)
+		return
+	}
+
+	var req DeactivateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		http.Error(w, `{"error": "invalid JSON body"}`, http.StatusBadRequest)
+		return
+	}
+
+	cfg, err := h.store.Get(r.Context(), orgID)
+	if err != nil {
+		h.logger.Error("failed to get clinic config", "org_id", orgID, "error", err)
+		http.Error(w, `{"error": "internal server error"}`, http.StatusInternalServerError)
+		return
+	}
+	if !cfg.Inactive {
+		now := time.Now().UTC()
+		cfg.Inactive = true
+		cfg.DeactivatedAt = &now
+	}
+	if req.FarewellMessage != nil {
+		cfg.FarewellMessage = strings.TrimSpace(*req.FarewellMessage)
+	}
+	if err := h.store.Set(r.Context(), cfg); err != nil {
+		h.logger.Error("failed to save clinic config", "org_id", orgID, "error", err)
+		http.Error(w, `{"error": "failed to save config"}`, http.StatusInternalServerError)
+		return
+	}
+
+	h.logger.Info("clinic deactivated", "org_id", orgID)
+	h.writeActivation(w, cfg)
+}
+
+// Reactivate restores normal handling for a deactivated clinic.
+// POST /admin/clinics/{orgID}/reactivate
+func (h *Handler) Reactivate(w http.ResponseWriter, r *http.Request) {
+	orgID := chi.URLParam(r, "orgID")
+	if orgID == "" {
+		http.Error(w, `{"error": "org_id required"}`, http.StatusBadRequest)
+		return
+	}
+
+	cfg, found, err := h.store.Lookup(r.Context(), orgID)
+	if err != nil {
+		h.logger.Error("failed to get clinic config", "org_id", orgID, "error", err)
+		http.Error(w, `{"error": "internal server error"}`, http.StatusInternalServerError)
+		return
+	}
+	if !found {
+		http.Error(w, `{"error": "clinic not configured"}`, http.StatusNotFound)
+		return
+	}
+	cfg.Inactive = false
+	cfg.DeactivatedAt = nil
+	if err := h.store.Set(r.Context(), cfg); err != nil {
+		h.logger.Error("failed to save clinic config", "org_id", orgID, "error", err)
+		http.Error(w, `{"error": "failed to save config"}`, http.StatusInternalServerError)
+		return
+	}
+
+	h.logger.Info("clinic reactivated", "org_id", orgID)
+	h.writeActivation(w, cfg)
+}
+
+func (h *Handler) writeActivation(w http.ResponseWriter, cfg *Config) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(activationState(cfg)); err != nil {
+		h.logger.Error("failed to encode clinic activation", "org_id", cfg.OrgID, "error", err)
+	}
+}
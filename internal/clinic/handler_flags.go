@@ -0,0 +1,172 @@
+package clinic
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// FlagState is a flag's resolved value for one clinic.
+type FlagState struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Default     bool   `json:"default"`
+	Enabled     bool   `json:"enabled"`
+	Overridden  bool   `json:"overridden"`
+}
+
+func flagState(cfg *Config, def FlagDefinition) FlagState {
+	return FlagState{
+		Name:        def.Name,
+		Description: def.Description,
+		Default:     def.Default,
+		Enabled:     cfg.FlagEnabled(def.Name),
+		Overridden:  cfg.FlagOverridden(def.Name),
+	}
+}
+
+// SetFlagRequest is the request body for overriding a flag.
+type SetFlagRequest struct {
+	Enabled *bool `json:"enabled"`
+}
+
+// ListFlags returns every known flag and its value for the clinic.
+// GET /admin/clinics/{orgID}/flags
+func (h *Handler) ListFlags(w http.ResponseWriter, r *http.Request) {
+	orgID := chi.URLParam(r, "orgID")
+	if orgID == "" {
+		http.Error(w, `{"error": "org_id required"}`, http.StatusBadRequest)
+		return
+	}
+
+	cfg, err := h.store.Get(r.Context(), orgID)
+	if err != nil {
+		h.logger.Error("failed to get clinic config", "org_id", orgID, "error", err)
+		http.Error(w, `{"error": "internal server error"}`, http.StatusInternalServerError)
+		return
+	}
+
+	known := KnownFlags()
+	flags := make([]FlagState, 0, len(known))
+	for _, def := range known {
+		flags = append(flags, flagState(cfg, def))
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(map[string]any{"flags": flags}); err != nil {
+		h.logger.Error("failed to encode feature flags", "org_id", orgID, "error", err)
+	}
+}
+
+// GetFlag returns one flag's value for the clinic.
+// GET /admin/clinics/{orgID}/flags/{flag}
+func (h *Handler) GetFlag(w http.ResponseWriter, r *http.Request) {
+	orgID := chi.URLParam(r, "orgID")
+	name := chi.URLParam(r, "flag")
+	if orgID == "" || name == "" {
+		http.Error(w, `{"error": "org_id and flag required"}`, http.StatusBadRequest)
+		return
+	}
+	def, ok := LookupFlag(name)
+	if !ok {
+		http.Error(w, fmt.Sprintf(`{"error": "unknown flag %q"}`, name), http.StatusNotFound)
+		return
+	}
+
+	cfg, err := h.store.Get(r.Context(), orgID)
+	if err != nil {
+		h.logger.Error("failed to get clinic config", "org_id", orgID, "error", err)
+		http.Error(w, `{"error": "internal server error"}`, http.StatusInternalServerError)
+		return
+	}
+
+	h.writeFlag(w, orgID, flagState(cfg, def))
+}
+
+// SetFlag overrides one flag for the clinic.
+// PUT /admin/clinics/{orgID}/flags/{flag}
+func (h *Handler) SetFlag(w http.ResponseWriter, r *http.Request) {
+	orgID := chi.URLParam(r, "orgID")
+	name := chi.URLParam(r, "flag")
+	if orgID == "" || name == "" {
+		http.Error(w, `{"error": "org_id and flag required"}`, http.StatusBadRequest)
+		return
+	}
+	def, ok := LookupFlag(name)
+	if !ok {
+		http.Error(w, fmt.Sprintf(`{"error": "unknown flag %q"}`, name), http.StatusBadRequest)
+		return
+	}
+
+	var req SetFlagRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, `{"error": "invalid JSON body"}`, http.StatusBadRequest)
+		return
+	}
+	if req.Enabled == nil {
+		http.Error(w, `{"error": "enabled required"}`, http.StatusBadRequest)
+		return
+	}
+
+	cfg, err := h.store.Get(r.Context(), orgID)
+	if err != nil {
+		h.logger.Error("failed to get clinic config", "org_id", orgID, "error", err)
+		http.Error(w, `{"error": "internal server error"}`, http.StatusInternalServerError)
+		return
+	}
+	if err := cfg.SetFlag(name, *req.Enabled); err != nil {
+		http.Error(w, fmt.Sprintf(`{"error": %q}`, err.Error()), http.StatusBadRequest)
+		return
+	}
+	if err := h.store.Set(r.Context(), cfg); err != nil {
+		h.logger.Error("failed to save clinic config", "org_id", orgID, "error", err)
+		http.Error(w, `{"error": "failed to save config"}`, http.StatusInternalServerError)
+		return
+	}
+
+	h.logger.Info("feature flag set", "org_id", orgID, "flag", name, "enabled", *req.Enabled)
+	h.writeFlag(w, orgID, flagState(cfg, def))
+}
+
+// ResetFlag drops the clinic's override so the flag uses its default.
+// DELETE /admin/clinics/{orgID}/flags/{flag}
+func (h *Handler) ResetFlag(w http.ResponseWriter, r *http.Request) {
+	orgID := chi.URLParam(r, "orgID")
+	name := chi.URLParam(r, "flag")
+	if orgID == "" || name == "" {
+		http.Error(w, `{"error": "org_id and flag required"}`, http.StatusBadRequest)
+		return
+	}
+	def, ok := LookupFlag(name)
+	if !ok {
+		http.Error(w, fmt.Sprintf(`{"error": "unknown flag %q"}`, name), http.StatusBadRequest)
+		return
+	}
+
+	cfg, err := h.store.Get(r.Context(), orgID)
+	if err != nil {
+		h.logger.Error("failed to get clinic config", "org_id", orgID, "error", err)
+		http.Error(w, `{"error": "internal server error"}`, http.StatusInternalServerError)
+		return
+	}
+	if err := cfg.ClearFlag(name); err != nil {
+		http.Error(w, fmt.Sprintf(`{"error": %q}`, err.Error()), http.StatusBadRequest)
+		return
+	}
+	if err := h.store.Set(r.Context(), cfg); err != nil {
+		h.logger.Error("failed to save clinic config", "org_id", orgID, "error", err)
+		http.Error(w, `{"error": "failed to save config"}`, http.StatusInternalServerError)
+		return
+	}
+
+	h.logger.Info("feature flag reset", "org_id", orgID, "flag", name)
+	h.writeFlag(w, orgID, flagState(cfg, def))
+}
+
+func (h *Handler) writeFlag(w http.ResponseWriter, orgID string, state FlagState) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(state); err != nil {
+		h.logger.Error("failed to encode feature flag", "org_id", orgID, "flag", state.Name, "error", err)
+	}
+}
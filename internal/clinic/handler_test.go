@@ -4,11 +4,15 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
+	"github.com/alicebob/miniredis/v2"
 	"github.com/go-chi/chi/v5"
+	"github.com/redis/go-redis/v9"
 	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
 )
 
@@ -201,3 +205,240 @@ func TestUpdateConfigWithBusinessHours(t *testing.T) {
 		t.Errorf("expected Saturday open 10:00, got %s", cfg.BusinessHours.Saturday.Open)
 	}
 }
+
+func TestUpdateConfigRejectsInsecureChatWebhook(t *testing.T) {
+	mr := miniredis.RunT(t)
+	h := NewHandler(NewStore(redis.NewClient(&redis.Options{Addr: mr.Addr()})), logging.Default())
+
+	r := chi.NewRouter()
+	r.Put("/clinics/{orgID}/config", h.UpdateConfig)
+
+	body := `{"notifications": {"chat_webhooks": [{"url": "http://hooks.example.com/abc"}]}}`
+	req := httptest.NewRequest("PUT", "/clinics/test-org-789/config", bytes.NewBufferString(body))
+	w := httptest.NewRecorder()
+
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestUpdateConfigRejectsBannedPriceTerms(t *testing.T) {
+	mr := miniredis.RunT(t)
+	h := NewHandler(NewStore(redis.NewClient(&redis.Options{Addr: mr.Addr()})), logging.Default())
+
+	r := chi.NewRouter()
+	r.Put("/clinics/{orgID}/config", h.UpdateConfig)
+
+	body := `{"service_price_text": {"Weight Loss": "Semaglutide from $299/mo"}}`
+	req := httptest.NewRequest("PUT", "/clinics/test-org-789/config", bytes.NewBufferString(body))
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), "Semaglutide") {
+		t.Fatalf("expected 400 naming the banned term, got %d: %s", w.Code, w.Body.String())
+	}
+
+	body = `{"service_price_text": {" Botox ": "$12/unit"}}`
+	req = httptest.NewRequest("PUT", "/clinics/test-org-789/config", bytes.NewBufferString(body))
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var cfg Config
+	if err := json.Unmarshal(w.Body.Bytes(), &cfg); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if cfg.ServicePriceText["botox"] != "$12/unit" {
+		t.Fatalf("expected prices keyed by normalized service, got %v", cfg.ServicePriceText)
+	}
+}
+
+func TestUpdateConfigAvailabilitySource(t *testing.T) {
+	mr := miniredis.RunT(t)
+	store := NewStore(redis.NewClient(&redis.Options{Addr: mr.Addr()}))
+	h := NewHandler(store, logging.Default())
+
+	r := chi.NewRouter()
+	r.Put("/clinics/{orgID}/config", h.UpdateConfig)
+
+	put := func(body string) int {
+		req := httptest.NewRequest("PUT", "/clinics/test-org-src/config", bytes.NewBufferString(body))
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	if code := put(`{"availability_source": "scraper"}`); code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for an unknown source, got %d", code)
+	}
+	// No browser source is registered, so it can't be pinned.
+	if code := put(`{"availability_source": "browser"}`); code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for the browser source, got %d", code)
+	}
+	if code := put(`{"availability_source": "Moxie_API"}`); code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", code)
+	}
+	cfg, err := store.Get(context.Background(), "test-org-src")
+	if err != nil {
+		t.Fatalf("get config: %v", err)
+	}
+	if cfg.AvailabilitySource != AvailabilitySourceMoxieAPI {
+		t.Fatalf("expected moxie_api pin, got %q", cfg.AvailabilitySource)
+	}
+	if code := put(`{"availability_source": ""}`); code != http.StatusOK {
+		t.Fatalf("expected 200 when clearing the pin, got %d", code)
+	}
+	if cfg, _ = store.Get(context.Background(), "test-org-src"); cfg.AvailabilitySource != "" {
+		t.Fatalf("expected the pin cleared, got %q", cfg.AvailabilitySource)
+	}
+}
+
+func TestUpdateConfigRejectsDisclosureWithoutRefundTerms(t *testing.T) {
+	mr := miniredis.RunT(t)
+	h := NewHandler(NewStore(redis.NewClient(&redis.Options{Addr: mr.Addr()})), logging.Default())
+
+	r := chi.NewRouter()
+	r.Put("/clinics/{orgID}/config", h.UpdateConfig)
+
+	body := `{"payment_disclosure": {"cancellation_window_hours": 48, "policies": ["Must be 18+."]}}`
+	req := httptest.NewRequest("PUT", "/clinics/test-org-789/config", bytes.NewBufferString(body))
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), "refund_terms") {
+		t.Fatalf("expected 400 naming refund_terms, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+type stubBookingURLValidator struct {
+	dead map[string]bool
+}
+
+func (v stubBookingURLValidator) ValidateBookingURL(ctx context.Context, rawURL, platform string) error {
+	if v.dead[rawURL] {
+		return errors.New("booking page returned 404")
+	}
+	return nil
+}
+
+func TestUpdateBookingURLRevalidates(t *testing.T) {
+	mr := miniredis.RunT(t)
+	store := NewStore(redis.NewClient(&redis.Options{Addr: mr.Addr()}))
+	h := NewHandler(store, logging.Default())
+	h.SetBookingURLValidator(stubBookingURLValidator{dead: map[string]bool{
+		"https://app.joinmoxie.com/booking/old-slug": true,
+	}})
+
+	r := chi.NewRouter()
+	r.Put("/clinics/{orgID}/booking-url", h.UpdateBookingURL)
+	put := func(body string) int {
+		req := httptest.NewRequest("PUT", "/clinics/test-org-url/booking-url", bytes.NewBufferString(body))
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	if code := put(`{"booking_url": "http://app.joinmoxie.com/booking/new-slug"}`); code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for a non-https URL, got %d", code)
+	}
+	if code := put(`{"booking_url": "https://app.joinmoxie.com/booking/old-slug"}`); code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected 422 for a dead page, got %d", code)
+	}
+	if cfg, _ := store.Get(context.Background(), "test-org-url"); cfg.BookingURL != DefaultBookingURL {
+		t.Fatalf("expected a dead URL not to be saved, got %q", cfg.BookingURL)
+	}
+	if code := put(`{"booking_url": "https://app.joinmoxie.com/booking/new-slug"}`); code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", code)
+	}
+	if cfg, _ := store.Get(context.Background(), "test-org-url"); cfg.BookingURL != "https://app.joinmoxie.com/booking/new-slug" {
+		t.Fatalf("expected the new URL saved, got %q", cfg.BookingURL)
+	}
+}
+
+func TestUpdateConfigLLMOverrides(t *testing.T) {
+	mr := miniredis.RunT(t)
+	h := NewHandler(NewStore(redis.NewClient(&redis.Options{Addr: mr.Addr()})), logging.Default())
+
+	r := chi.NewRouter()
+	r.Put("/clinics/{orgID}/config", h.UpdateConfig)
+	put := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("PUT", "/clinics/test-org-789/config", bytes.NewBufferString(body))
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	for _, body := range []string{
+		`{"llm_overrides": {"temperature": 1.5}}`,
+		`{"llm_overrides": {"temperature": -0.1}}`,
+		`{"llm_overrides": {"max_tokens": 20}}`,
+		`{"llm_overrides": {"max_tokens": 4096}}`,
+		`{"llm_overrides": {"model": "claude haiku"}}`,
+	} {
+		if w := put(body); w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), "llm_overrides") {
+			t.Fatalf("%s: expected 400, got %d: %s", body, w.Code, w.Body.String())
+		}
+	}
+
+	w := put(`{"llm_overrides": {"model": " strict-model ", "temperature": 0, "max_tokens": 200}}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var cfg Config
+	if err := json.Unmarshal(w.Body.Bytes(), &cfg); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	o := cfg.LLMOverrides
+	if o == nil || o.Model != "strict-model" || o.Temperature == nil || *o.Temperature != 0 || o.MaxTokens != 200 {
+		t.Fatalf("unexpected overrides %+v", o)
+	}
+
+	w = put(`{"llm_overrides": {}}`)
+	cfg = Config{}
+	if err := json.Unmarshal(w.Body.Bytes(), &cfg); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if cfg.LLMOverrides != nil {
+		t.Fatalf("expected an empty object to clear the overrides, got %+v", cfg.LLMOverrides)
+	}
+}
+
+func TestUpdateConfigPaymentMethods(t *testing.T) {
+	mr := miniredis.RunT(t)
+	h := NewHandler(NewStore(redis.NewClient(&redis.Options{Addr: mr.Addr()})), logging.Default())
+
+	r := chi.NewRouter()
+	r.Put("/clinics/{orgID}/config", h.UpdateConfig)
+	put := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("PUT", "/clinics/test-org-pay/config", bytes.NewBufferString(body))
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := put(`{"payment_methods": {"accepted": ["cash"], "insurance": "maybe"}}`); w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), "payment_methods") {
+		t.Fatalf("expected 400 for an unknown insurance stance, got %d: %s", w.Code, w.Body.String())
+	}
+
+	w := put(`{"payment_methods": {"accepted": ["cash"], "financing": ["Cherry"], "insurance": " not_accepted "}}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var cfg Config
+	if err := json.Unmarshal(w.Body.Bytes(), &cfg); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if p := cfg.PaymentMethods; p == nil || p.Insurance != InsuranceNotAccepted || !p.OffersFinancing("Cherry") {
+		t.Fatalf("unexpected payment methods %+v", p)
+	}
+
+	w = put(`{"payment_methods": {}}`)
+	cfg = Config{}
+	if err := json.Unmarshal(w.Body.Bytes(), &cfg); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if cfg.PaymentMethods != nil {
+		t.Fatalf("expected an empty object to clear the payment methods, got %+v", cfg.PaymentMethods)
+	}
+}
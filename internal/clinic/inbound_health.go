@@ -0,0 +1,57 @@
+package clinic
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	// DefaultInboundQuietHours is how many open business hours may pass
+	// without an inbound message before a clinic's inbound webhooks are
+	// treated as broken.
+	DefaultInboundQuietHours = 12
+	// maxInboundQuietHours is a week of round-the-clock hours.
+	maxInboundQuietHours = 168
+)
+
+// InboundHealthConfig configures the inbound liveness check, which alerts
+// engineering when a clinic's number stops receiving texts.
+type InboundHealthConfig struct {
+	// QuietHours is how many business hours without an inbound message
+	// mark the clinic degraded. Zero uses DefaultInboundQuietHours; raise
+	// it for clinics that get few texts.
+	QuietHours int `json:"quiet_hours,omitempty"`
+	// Disabled turns the check off for the clinic.
+	Disabled bool `json:"disabled,omitempty"`
+}
+
+// ValidateInboundHealth checks the inbound liveness config before it is
+// saved.
+func ValidateInboundHealth(h *InboundHealthConfig) error {
+	if h == nil {
+		return nil
+	}
+	if h.QuietHours < 0 || h.QuietHours > maxInboundQuietHours {
+		return fmt.Errorf("inbound_health.quiet_hours must be between 0 and %d", maxInboundQuietHours)
+	}
+	return nil
+}
+
+// InboundQuietThreshold returns how much open business time may pass without
+// an inbound message before the clinic is marked degraded, or zero when the
+// check is off: inactive clinics and clinics that disabled it.
+func (c *Config) InboundQuietThreshold() time.Duration {
+	if c == nil || !c.IsActive() {
+		return 0
+	}
+	hours := DefaultInboundQuietHours
+	if h := c.InboundHealth; h != nil {
+		if h.Disabled {
+			return 0
+		}
+		if h.QuietHours > 0 {
+			hours = h.QuietHours
+		}
+	}
+	return time.Duration(hours) * time.Hour
+}
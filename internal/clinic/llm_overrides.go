@@ -0,0 +1,53 @@
+package clinic
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Bounds for LLMOverrides. Replies longer than MaxLLMMaxTokens don't fit a
+// few SMS segments, and MinLLMMaxTokens leaves room for the deposit
+// classifier's JSON.
+const (
+	MinLLMTemperature = 0.0
+	MaxLLMTemperature = 1.0
+	MinLLMMaxTokens   = 100
+	MaxLLMMaxTokens   = 1024
+	maxLLMModelChars  = 128
+)
+
+// LLMOverrides replaces the conversation model's parameters for one clinic,
+// e.g. a stricter model, a lower temperature and shorter replies for a
+// medically conservative practice. Unset fields use the service defaults.
+type LLMOverrides struct {
+	// Model pins the model ID for text conversations, including turns the
+	// model router would escalate. Voice calls keep the low-latency voice
+	// model.
+	Model       string   `json:"model,omitempty"`
+	Temperature *float64 `json:"temperature,omitempty"`
+	MaxTokens   int      `json:"max_tokens,omitempty"`
+}
+
+// IsZero reports whether o overrides nothing.
+func (o *LLMOverrides) IsZero() bool {
+	return o == nil || (strings.TrimSpace(o.Model) == "" && o.Temperature == nil && o.MaxTokens == 0)
+}
+
+// ValidateLLMOverrides checks LLM overrides against their bounds before they
+// are saved.
+func ValidateLLMOverrides(o *LLMOverrides) error {
+	if o == nil {
+		return nil
+	}
+	model := strings.TrimSpace(o.Model)
+	if len(model) > maxLLMModelChars || strings.ContainsAny(model, " \t\r\n") {
+		return fmt.Errorf("llm_overrides.model must be a model ID of at most %d characters", maxLLMModelChars)
+	}
+	if o.Temperature != nil && (*o.Temperature < MinLLMTemperature || *o.Temperature > MaxLLMTemperature) {
+		return fmt.Errorf("llm_overrides.temperature must be between %.1f and %.1f", MinLLMTemperature, MaxLLMTemperature)
+	}
+	if o.MaxTokens != 0 && (o.MaxTokens < MinLLMMaxTokens || o.MaxTokens > MaxLLMMaxTokens) {
+		return fmt.Errorf("llm_overrides.max_tokens must be between %d and %d", MinLLMMaxTokens, MaxLLMMaxTokens)
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+package clinic
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/messaging/templates"
+)
+
+// MaxMessageTemplateChars caps a MessageTemplates entry at ten SMS segments.
+const MaxMessageTemplateChars = 1600
+
+// templatePlaceholderRE finds {{name}} placeholders in MessageTemplates text.
+var templatePlaceholderRE = regexp.MustCompile(`\{\{\s*([^{}]*?)\s*\}\}`)
+
+// ValidateMessageTemplates rejects entries for unregistered templates, empty
+// or oversized text, and placeholders the template doesn't fill.
+func ValidateMessageTemplates(overrides map[string]string) error {
+	ids := make([]string, 0, len(overrides))
+	for id := range overrides {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		t, ok := templates.Default.Get(id)
+		if !ok {
+			return fmt.Errorf("message_templates: unknown template %q", id)
+		}
+		text := overrides[id]
+		if strings.TrimSpace(text) == "" {
+			return fmt.Errorf("message_templates[%s]: text is required; remove the entry to use the default", id)
+		}
+		if len(text) > MaxMessageTemplateChars {
+			return fmt.Errorf("message_templates[%s]: text exceeds %d characters", id, MaxMessageTemplateChars)
+		}
+		for _, m := range templatePlaceholderRE.FindAllStringSubmatch(text, -1) {
+			if !slices.Contains(t.Params, m[1]) {
+				return fmt.Errorf("message_templates[%s]: unknown placeholder %s", id, m[0])
+			}
+		}
+	}
+	return nil
+}
+
+// TemplateOverrides returns the clinic's copy for registered templates. The
+// older per-message fields are folded in first, so a clinic that set
+// AckTemplates or FarewellMessage keeps sending it; MessageTemplates entries
+// win over them.
+func (c *Config) TemplateOverrides() templates.Overrides {
+	if c == nil {
+		return nil
+	}
+	out := make(templates.Overrides)
+	setText := func(text string, ids ...string) {
+		if text = strings.TrimSpace(text); text == "" {
+			return
+		}
+		for _, id := range ids {
+			out[id] = templates.Override{Text: text}
+		}
+	}
+	setText(c.FirstContactTemplate, templates.AckFirstContact)
+	setText(c.FarewellMessage, templates.ComplianceFarewell, templates.ComplianceFarewellNoPhone)
+	if c.Reengagement != nil {
+		setText(c.Reengagement.FirstTemplate, templates.NudgeReengagementFirst, templates.NudgeReengagementFirstGeneric)
+		setText(c.Reengagement.FinalTemplate, templates.NudgeReengagementFinal, templates.NudgeReengagementFinalGeneric)
+	}
+	if len(c.AckTemplates) > 0 {
+		out[templates.AckFirst] = templates.Override{Variants: c.AckTemplates}
+	}
+	for id, text := range c.MessageTemplates {
+		t, ok := templates.Default.Get(id)
+		if !ok || strings.TrimSpace(text) == "" {
+			continue
+		}
+		if len(t.Variants) > 0 {
+			out[id] = templates.Override{Variants: []string{text}}
+			continue
+		}
+		out[id] = templates.Override{Text: text}
+	}
+	if len(out) == 0 {
+		return nil
+	}
+	return out
+}
+
+// RenderTemplate renders a registered template with the clinic's copy. It is
+// safe to call on a nil config, which renders the default.
+func (c *Config) RenderTemplate(id string, params templates.Params) templates.Rendered {
+	return c.RenderTemplateVariant(id, "", params)
+}
+
+// RenderTemplateVariant renders a rotating template for the message
+// identified by key; see templates.Registry.RenderVariant.
+func (c *Config) RenderTemplateVariant(id, key string, params templates.Params) templates.Rendered {
+	out := templates.RenderVariant(id, key, params, c.TemplateOverrides())
+	out.Body = strings.TrimSpace(out.Body)
+	return out
+}
@@ -0,0 +1,73 @@
+package clinic
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/messaging/templates"
+)
+
+func TestValidateMessageTemplates(t *testing.T) {
+	if err := ValidateMessageTemplates(map[string]string{
+		templates.PaymentFailed:             "Card declined - reply and we'll send a new link.",
+		templates.BookingManualHandoff:      "{{clinic_name}} will call you shortly!",
+		templates.ComplianceFarewellNoPhone: "{{ clinic_name }} is no longer texting.",
+	}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	cases := map[string]map[string]string{
+		"unknown template":     {"payment.bogus": "hi"},
+		"empty text":           {templates.PaymentFailed: "   "},
+		"too long":             {templates.PaymentFailed: strings.Repeat("x", MaxMessageTemplateChars+1)},
+		"unknown placeholder":  {templates.BookingManualHandoff: "{{clinic}} will call you."},
+		"param of other entry": {templates.PaymentFailed: "Your {{service}} deposit failed."},
+	}
+	for name, overrides := range cases {
+		if err := ValidateMessageTemplates(overrides); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
+
+func TestTemplateOverridesFoldsLegacyFields(t *testing.T) {
+	cfg := &Config{
+		Name:                 "Glow",
+		FirstContactTemplate: "Welcome to Glow!",
+		FarewellMessage:      "Glow has closed.",
+		AckTemplates:         []string{"On it!"},
+		Reengagement:         &ReengagementConfig{FirstTemplate: "Still there?"},
+		MessageTemplates: map[string]string{
+			templates.ComplianceFarewell: "Glow has moved to a new number.",
+			templates.AckFollowUp:        "One sec!",
+		},
+	}
+	overrides := cfg.TemplateOverrides()
+
+	if got := overrides[templates.AckFirstContact].Text; got != "Welcome to Glow!" {
+		t.Fatalf("first contact override = %q", got)
+	}
+	if got := overrides[templates.ComplianceFarewellNoPhone].Text; got != "Glow has closed." {
+		t.Fatalf("legacy farewell should cover the no-phone variant, got %q", got)
+	}
+	if got := overrides[templates.ComplianceFarewell].Text; got != "Glow has moved to a new number." {
+		t.Fatalf("MessageTemplates should win over FarewellMessage, got %q", got)
+	}
+	if got := overrides[templates.NudgeReengagementFirstGeneric].Text; got != "Still there?" {
+		t.Fatalf("reengagement override = %q", got)
+	}
+	if got := overrides[templates.AckFollowUp].Variants; len(got) != 1 || got[0] != "One sec!" {
+		t.Fatalf("rotating template override = %v", got)
+	}
+
+	rendered := cfg.RenderTemplate(templates.ComplianceFarewell, templates.Params{"clinic_name": "Glow"})
+	if rendered.Body != "Glow has moved to a new number." || !strings.HasPrefix(rendered.Version, "1-") {
+		t.Fatalf("unexpected rendered override %+v", rendered)
+	}
+	if got := (*Config)(nil).RenderTemplate(templates.PaymentFailed, nil); got.Body != templates.Text(templates.PaymentFailed) || got.Version != "1" {
+		t.Fatalf("nil config should render the default, got %+v", got)
+	}
+	if (&Config{}).TemplateOverrides() != nil {
+		t.Fatal("a config with no copy should have no overrides")
+	}
+}
@@ -0,0 +1,194 @@
+package clinic
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// maxNotOfferedAlternatives caps how many offered services a decline suggests.
+const maxNotOfferedAlternatives = 2
+
+// NotOfferedService is a service patients ask for that the clinic doesn't
+// provide, e.g. tattoo removal at a clinic without a Q-switched laser.
+type NotOfferedService struct {
+	// Service is the patient-facing name used in the reply, e.g. "tattoo removal".
+	Service string `json:"service"`
+	// Aliases are other words patients use for it, e.g. ["tattoo", "ink removal"].
+	Aliases []string `json:"aliases,omitempty"`
+	// Alternatives are offered services to suggest instead. Empty falls back
+	// to the clinic's services in the same category.
+	Alternatives []string `json:"alternatives,omitempty"`
+	// Referral optionally points the patient elsewhere and is sent as written,
+	// e.g. "Ink Away on Main St. does great work."
+	Referral string `json:"referral,omitempty"`
+}
+
+// serviceCategories groups services by keyword so a declined service can
+// pivot to something related. Earlier categories win, so "laser facial" is
+// a laser service.
+var serviceCategories = []struct {
+	name     string
+	keywords []string
+}{
+	{"laser", []string{"laser", "ipl", "photofacial", "tattoo", "hair removal", "resurfacing", "tixel", "vascular", "spider vein", "bbl"}},
+	{"injectable", []string{"botox", "dysport", "xeomin", "jeuveau", "daxxify", "tox", "wrinkle relaxer", "filler", "lip", "kybella", "sculptra", "radiesse", "thread"}},
+	{"skin", []string{"facial", "peel", "microneedling", "dermaplan", "prp", "skin"}},
+	{"body", []string{"coolsculpt", "body contour", "cellulite", "emsculpt", "sculpt"}},
+	{"wellness", []string{"weight", "semaglutide", "tirzepatide", "vitamin", "hormone", "iv therapy", "b12"}},
+}
+
+// ServiceCategory returns the broad category of a service ("laser",
+// "injectable", "skin", "body", "wellness"), or "" when unknown.
+func ServiceCategory(service string) string {
+	key := normalizeServiceKey(service)
+	if key == "" {
+		return ""
+	}
+	for _, cat := range serviceCategories {
+		for _, kw := range cat.keywords {
+			if strings.Contains(key, kw) {
+				return cat.name
+			}
+		}
+	}
+	return ""
+}
+
+// NotOfferedServiceIn returns the not-offered service the text asks for, if
+// any. Service aliases that resolve to a not-offered service count, and a
+// longer offered name wins, so "tattoo" doesn't match "tattoo touch-up
+// consult" when that is on the menu.
+func (c *Config) NotOfferedServiceIn(text string) (NotOfferedService, bool) {
+	if c == nil || len(c.NotOffered) == 0 {
+		return NotOfferedService{}, false
+	}
+	text = strings.ToLower(text)
+	best, bestLen := -1, 0
+	for i, entry := range c.NotOffered {
+		for _, term := range c.notOfferedTerms(entry) {
+			if len(term) > bestLen && containsTerm(text, term) {
+				best, bestLen = i, len(term)
+			}
+		}
+	}
+	if best < 0 {
+		return NotOfferedService{}, false
+	}
+	for _, offered := range c.offeredTerms() {
+		if len(offered) > bestLen && containsTerm(text, offered) && !c.IsNotOffered(offered) {
+			return NotOfferedService{}, false
+		}
+	}
+	return c.NotOffered[best], true
+}
+
+// IsNotOffered reports whether a service name, or the service its alias
+// resolves to, is on the not-offered list.
+func (c *Config) IsNotOffered(service string) bool {
+	if c == nil || len(c.NotOffered) == 0 {
+		return false
+	}
+	key := normalizeServiceKey(service)
+	if key == "" {
+		return false
+	}
+	resolved := normalizeServiceKey(c.ResolveServiceName(service))
+	for _, entry := range c.NotOffered {
+		for _, term := range c.notOfferedTerms(entry) {
+			if key == term || resolved == term {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// NotOfferedTerms returns every phrase that names a not-offered service,
+// longest first.
+func (c *Config) NotOfferedTerms() []string {
+	if c == nil {
+		return nil
+	}
+	var terms []string
+	for _, entry := range c.NotOffered {
+		terms = append(terms, c.notOfferedTerms(entry)...)
+	}
+	sort.SliceStable(terms, func(i, j int) bool { return len(terms[i]) > len(terms[j]) })
+	return terms
+}
+
+// NotOfferedAlternatives returns the offered services to suggest in place of
+// entry: its configured alternatives, or else the clinic's services in the
+// same category.
+func (c *Config) NotOfferedAlternatives(entry NotOfferedService) []string {
+	var out []string
+	for _, alt := range entry.Alternatives {
+		if alt = strings.TrimSpace(alt); alt != "" {
+			out = append(out, alt)
+		}
+	}
+	if len(out) > 0 || c == nil {
+		return out
+	}
+	category := ServiceCategory(entry.Service)
+	if category == "" {
+		return nil
+	}
+	for _, svc := range c.Services {
+		if ServiceCategory(svc) != category || c.IsNotOffered(svc) {
+			continue
+		}
+		out = append(out, svc)
+		if len(out) == maxNotOfferedAlternatives {
+			break
+		}
+	}
+	return out
+}
+
+// notOfferedTerms returns the entry's name, its aliases, and any clinic
+// service alias that resolves to it, normalized.
+func (c *Config) notOfferedTerms(entry NotOfferedService) []string {
+	name := normalizeServiceKey(entry.Service)
+	if name == "" {
+		return nil
+	}
+	terms := []string{name}
+	for _, alias := range entry.Aliases {
+		if alias = normalizeServiceKey(alias); alias != "" {
+			terms = append(terms, alias)
+		}
+	}
+	for alias, target := range c.ServiceAliases {
+		if normalizeServiceKey(target) == name {
+			terms = append(terms, normalizeServiceKey(alias))
+		}
+	}
+	return terms
+}
+
+// offeredTerms returns the clinic's service names and alias keys.
+func (c *Config) offeredTerms() []string {
+	terms := make([]string, 0, len(c.Services)+len(c.ServiceAliases))
+	for _, svc := range c.Services {
+		if key := normalizeServiceKey(svc); key != "" {
+			terms = append(terms, key)
+		}
+	}
+	for alias := range c.ServiceAliases {
+		if key := normalizeServiceKey(alias); key != "" {
+			terms = append(terms, key)
+		}
+	}
+	return terms
+}
+
+// containsTerm reports whether term appears in text as whole words.
+func containsTerm(text, term string) bool {
+	re, err := regexp.Compile(`\b` + regexp.QuoteMeta(term) + `s?\b`)
+	if err != nil {
+		return strings.Contains(text, term)
+	}
+	return re.MatchString(text)
+}
@@ -0,0 +1,127 @@
+package clinic
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/messaging/templates"
+)
+
+// Payment disclosure placeholders, alongside PlaceholderClinicName.
+const (
+	PlaceholderDepositAmount     = "{{deposit_amount}}"
+	PlaceholderCancellationHours = "{{cancellation_hours}}"
+)
+
+// placeholderRE finds {{...}} placeholders in disclosure text.
+var placeholderRE = regexp.MustCompile(`\{\{\s*[^{}]*\}\}`)
+
+// PaymentDisclosure is the cancellation, no-show and refund policy sent to
+// the patient before the deposit link. RefundTerms and Policies may use
+// {{deposit_amount}}, {{clinic_name}} and {{cancellation_hours}}.
+type PaymentDisclosure struct {
+	// CancellationWindowHours is how long before the appointment a patient
+	// must cancel to keep their deposit, e.g. 48.
+	CancellationWindowHours int `json:"cancellation_window_hours"`
+	// RefundTerms says what happens to the deposit on a late cancellation or
+	// no-show.
+	RefundTerms string `json:"refund_terms"`
+	// Policies are further bullets, e.g. an age requirement.
+	Policies []string `json:"policies,omitempty"`
+}
+
+// ValidatePaymentDisclosure rejects a disclosure missing a required legal
+// element (the cancellation window or the refund terms) or using an unknown
+// placeholder.
+func ValidatePaymentDisclosure(d *PaymentDisclosure) error {
+	if d == nil {
+		return nil
+	}
+	if d.CancellationWindowHours <= 0 {
+		return fmt.Errorf("cancellation_window_hours must be positive")
+	}
+	if strings.TrimSpace(d.RefundTerms) == "" {
+		return fmt.Errorf("refund_terms is required")
+	}
+	for _, text := range append([]string{d.RefundTerms}, d.Policies...) {
+		for _, placeholder := range placeholderRE.FindAllString(text, -1) {
+			switch placeholder {
+			case PlaceholderDepositAmount, PlaceholderClinicName, PlaceholderCancellationHours:
+			default:
+				return fmt.Errorf("unknown placeholder %s", placeholder)
+			}
+		}
+	}
+	return nil
+}
+
+// PaymentDisclosureText renders the clinic's pre-payment disclosure for a
+// deposit of amountCents. Clinics without a PaymentDisclosure get the
+// standard forfeiture notice followed by their BookingPolicies.
+func (c *Config) PaymentDisclosureText(amountCents int) string {
+	if c == nil {
+		return RenderPaymentDisclosure(nil, "", amountCents, nil)
+	}
+	return renderPaymentDisclosure(c.PaymentDisclosure, c.Name, amountCents, c.BookingPolicies, c.TemplateOverrides())
+}
+
+// RenderPaymentDisclosure renders d for a deposit of amountCents. A nil d
+// renders the default disclosure with bookingPolicies as its bullets.
+func RenderPaymentDisclosure(d *PaymentDisclosure, clinicName string, amountCents int, bookingPolicies []string) string {
+	return renderPaymentDisclosure(d, clinicName, amountCents, bookingPolicies, nil)
+}
+
+func renderPaymentDisclosure(d *PaymentDisclosure, clinicName string, amountCents int, bookingPolicies []string, overrides templates.Overrides) string {
+	amount := fmt.Sprintf("$%.2f", float64(amountCents)/100)
+	hours := cancellationHours(d)
+
+	var terms string
+	var bullets []string
+	if d == nil {
+		terms = "\n\n" + templates.Render(templates.PolicyNoShowTerms, nil, overrides).Body
+		bullets = bookingPolicies
+	} else {
+		bullets = append(bullets, templates.Render(templates.PolicyCancellationWindow, templates.Params{"cancellation_hours": hours}, overrides).Body)
+		bullets = append(bullets, d.RefundTerms)
+		bullets = append(bullets, d.Policies...)
+	}
+
+	name := strings.TrimSpace(clinicName)
+	if name == "" {
+		name = "the clinic"
+	}
+	replacer := strings.NewReplacer(
+		PlaceholderDepositAmount, amount,
+		PlaceholderClinicName, name,
+		PlaceholderCancellationHours, hours,
+	)
+	var policies strings.Builder
+	for _, bullet := range bullets {
+		bullet = strings.TrimSpace(replacer.Replace(bullet))
+		if bullet == "" {
+			continue
+		}
+		if policies.Len() == 0 {
+			policies.WriteString("\n\n")
+			policies.WriteString(templates.Render(templates.PolicyBookingPoliciesTitle, nil, overrides).Body)
+		}
+		policies.WriteString("\n  ✅ ")
+		policies.WriteString(bullet)
+	}
+	return templates.Render(templates.PolicyDepositDisclosure, templates.Params{
+		"deposit_amount": amount,
+		"terms":          terms,
+		"policies":       policies.String(),
+	}, overrides).Body
+}
+
+// cancellationHours fills {{cancellation_hours}}; the default disclosure's
+// window is the standard 24 hours.
+func cancellationHours(d *PaymentDisclosure) string {
+	if d == nil || d.CancellationWindowHours <= 0 {
+		return "24"
+	}
+	return strconv.Itoa(d.CancellationWindowHours)
+}
@@ -0,0 +1,73 @@
+package clinic
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPaymentDisclosureText_ConfiguredWindow(t *testing.T) {
+	cfg := DefaultConfig("org-2")
+	cfg.Name = "Glow Aesthetics"
+	cfg.BookingPolicies = []string{"ignored once a disclosure is configured"}
+	cfg.PaymentDisclosure = &PaymentDisclosure{
+		CancellationWindowHours: 48,
+		RefundTerms:             "Cancellations inside {{cancellation_hours}} hours or no-shows forfeit the {{deposit_amount}} deposit; earlier cancellations are refunded in full.",
+		Policies:                []string{"Patients must be 18 or older to be treated at {{clinic_name}}."},
+	}
+
+	got := cfg.PaymentDisclosureText(7500)
+	want := "💳 $75.00 deposit — applies toward your treatment cost and secures your spot.\n\n" +
+		"📋 Booking policies:\n" +
+		"  ✅ 48-hour cancellation policy — please cancel or reschedule at least 48 hours before your appointment.\n" +
+		"  ✅ Cancellations inside 48 hours or no-shows forfeit the $75.00 deposit; earlier cancellations are refunded in full.\n" +
+		"  ✅ Patients must be 18 or older to be treated at Glow Aesthetics."
+	if got != want {
+		t.Fatalf("disclosure =\n%s\nwant\n%s", got, want)
+	}
+	if cfg.PaymentDisclosureText(7500) != got {
+		t.Fatal("rendering should be deterministic")
+	}
+}
+
+func TestPaymentDisclosureText_DefaultFallback(t *testing.T) {
+	cfg := DefaultConfig("org-1")
+	if got, want := cfg.PaymentDisclosureText(5000),
+		"💳 $50.00 deposit — applies toward your treatment cost and secures your spot.\n\n"+
+			"⚠️ Deposits are forfeited for no-shows or late cancellations."; got != want {
+		t.Fatalf("disclosure = %q, want %q", got, want)
+	}
+
+	cfg.BookingPolicies = []string{"24-hour cancellation policy.", "Patients must be 18 or older."}
+	got := cfg.PaymentDisclosureText(5000)
+	if !strings.HasSuffix(got, "\n\n📋 Booking policies:\n  ✅ 24-hour cancellation policy.\n  ✅ Patients must be 18 or older.") {
+		t.Fatalf("expected the booking policies as bullets, got %q", got)
+	}
+}
+
+func TestValidatePaymentDisclosure(t *testing.T) {
+	tests := []struct {
+		name    string
+		d       *PaymentDisclosure
+		wantErr string
+	}{
+		{"unset", nil, ""},
+		{"complete", &PaymentDisclosure{CancellationWindowHours: 48, RefundTerms: "Late cancellations forfeit the {{deposit_amount}} deposit."}, ""},
+		{"missing refund terms", &PaymentDisclosure{CancellationWindowHours: 48, Policies: []string{"Must be 18+."}}, "refund_terms"},
+		{"missing cancellation window", &PaymentDisclosure{RefundTerms: "Non-refundable."}, "cancellation_window_hours"},
+		{"unknown placeholder", &PaymentDisclosure{CancellationWindowHours: 24, RefundTerms: "Forfeit {{deposit}}."}, "{{deposit}}"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidatePaymentDisclosure(tt.d)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("error = %v, want one mentioning %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,184 @@
+package clinic
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/messaging/templates"
+)
+
+// Insurance stances for PaymentMethods.Insurance.
+const (
+	InsuranceNotAccepted = "not_accepted"
+	InsuranceAccepted    = "accepted"
+)
+
+// maxPaymentMethodsNoteChars keeps PaymentMethods.Note to about one SMS
+// segment.
+const maxPaymentMethodsNoteChars = 160
+
+// PaymentMethods is how patients can pay the clinic. Payment-method
+// questions ("do you take CareCredit?") are answered from it, never by the
+// LLM's guess.
+type PaymentMethods struct {
+	// Accepted are the ways to pay at the visit, e.g. "cash", "all major
+	// credit cards", "HSA/FSA cards".
+	Accepted []string `json:"accepted,omitempty"`
+	// Financing are the patient financing providers the clinic works with,
+	// e.g. "Cherry", "CareCredit".
+	Financing []string `json:"financing,omitempty"`
+	// Insurance is InsuranceNotAccepted, InsuranceAccepted, or empty when
+	// the clinic hasn't said; patients asking then hear the team will
+	// confirm.
+	Insurance string `json:"insurance,omitempty"`
+	// Note is optional copy added to the answer, e.g. "Cherry offers 0%
+	// APR plans for qualified patients."
+	Note string `json:"note,omitempty"`
+}
+
+// IsZero reports whether p configures nothing.
+func (p *PaymentMethods) IsZero() bool {
+	return p == nil || (len(nonBlank(p.Accepted)) == 0 && len(nonBlank(p.Financing)) == 0 &&
+		strings.TrimSpace(p.Insurance) == "" && strings.TrimSpace(p.Note) == "")
+}
+
+// OffersFinancing reports whether the clinic works with the named
+// financing provider, ignoring case and spacing ("Care Credit").
+func (p *PaymentMethods) OffersFinancing(provider string) bool {
+	if p == nil {
+		return false
+	}
+	want := strings.ReplaceAll(strings.ToLower(provider), " ", "")
+	for _, f := range p.Financing {
+		if strings.ReplaceAll(strings.ToLower(f), " ", "") == want {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidatePaymentMethods checks payment methods before they are saved. The
+// answer lists what patients can pay with, so at least one accepted method
+// or financing provider is required.
+func ValidatePaymentMethods(p *PaymentMethods) error {
+	if p.IsZero() {
+		return nil
+	}
+	if len(nonBlank(p.Accepted)) == 0 && len(nonBlank(p.Financing)) == 0 {
+		return fmt.Errorf("payment_methods needs at least one accepted method or financing provider")
+	}
+	switch strings.TrimSpace(p.Insurance) {
+	case "", InsuranceNotAccepted, InsuranceAccepted:
+	default:
+		return fmt.Errorf("payment_methods.insurance must be %q or %q", InsuranceNotAccepted, InsuranceAccepted)
+	}
+	if len(strings.TrimSpace(p.Note)) > maxPaymentMethodsNoteChars {
+		return fmt.Errorf("payment_methods.note must be at most %d characters", maxPaymentMethodsNoteChars)
+	}
+	return nil
+}
+
+// PaymentMethodsAnswer renders the reply to a payment-method question.
+// asksInsurance adds the team-will-confirm line when the clinic hasn't
+// stated an insurance stance. Clinics without PaymentMethods get the
+// unconfigured reply, which promises a follow-up instead of guessing.
+func (c *Config) PaymentMethodsAnswer(asksInsurance bool) templates.Rendered {
+	var p *PaymentMethods
+	if c != nil {
+		p = c.PaymentMethods
+	}
+	if p.IsZero() {
+		name := "clinic"
+		if c != nil && strings.TrimSpace(c.Name) != "" {
+			name = strings.TrimSpace(c.Name)
+		}
+		return c.RenderTemplate(templates.PaymentMethodsUnconfigured, templates.Params{"clinic_name": name})
+	}
+
+	overrides := c.TemplateOverrides()
+	accepted := nonBlank(p.Accepted)
+	if financing := nonBlank(p.Financing); len(financing) > 0 {
+		accepted = append(accepted, templates.Render(templates.PaymentMethodsFinancing, templates.Params{"providers": joinWithAnd(financing)}, overrides).Body)
+	}
+	var insurance string
+	switch strings.TrimSpace(p.Insurance) {
+	case InsuranceNotAccepted:
+		insurance = templates.Render(templates.PaymentMethodsInsuranceNo, nil, overrides).Body
+	case InsuranceAccepted:
+		insurance = templates.Render(templates.PaymentMethodsInsuranceYes, nil, overrides).Body
+	default:
+		if asksInsurance {
+			insurance = templates.Render(templates.PaymentMethodsInsuranceAsk, nil, overrides).Body
+		}
+	}
+	return c.RenderTemplate(templates.PaymentMethodsAnswer, templates.Params{
+		"accepted":  joinWithAnd(accepted),
+		"insurance": leadingSpace(insurance),
+		"note":      leadingSpace(p.Note),
+	})
+}
+
+// PaymentMethodsContext tells the LLM what the clinic accepts, for
+// payment follow-ups it answers itself.
+func (c *Config) PaymentMethodsContext() string {
+	var p *PaymentMethods
+	if c != nil {
+		p = c.PaymentMethods
+	}
+	if p.IsZero() {
+		return "PAYMENT METHODS: None are configured for this clinic. Do NOT guess which payment methods, financing or insurance the clinic accepts; say the team will confirm."
+	}
+	var b strings.Builder
+	b.WriteString("PAYMENT METHODS: ")
+	if accepted := nonBlank(p.Accepted); len(accepted) > 0 {
+		fmt.Fprintf(&b, "Accepted: %s. ", strings.Join(accepted, ", "))
+	}
+	if financing := nonBlank(p.Financing); len(financing) > 0 {
+		fmt.Fprintf(&b, "Financing: %s. ", strings.Join(financing, ", "))
+	} else {
+		b.WriteString("No financing providers. ")
+	}
+	switch strings.TrimSpace(p.Insurance) {
+	case InsuranceNotAccepted:
+		b.WriteString("Insurance is NOT accepted; never say or imply it is. ")
+	case InsuranceAccepted:
+		b.WriteString("Insurance is accepted for eligible treatments; the team verifies coverage. ")
+	default:
+		b.WriteString("Insurance stance unknown; say the team will confirm. ")
+	}
+	if note := strings.TrimSpace(p.Note); note != "" {
+		fmt.Fprintf(&b, "Clinic note: %s ", note)
+	}
+	b.WriteString("Only name these; never invent other payment methods, providers or financing terms.")
+	return b.String()
+}
+
+func nonBlank(values []string) []string {
+	var out []string
+	for _, v := range values {
+		if v = strings.TrimSpace(v); v != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+// joinWithAnd joins items as "a", "a and b" or "a, b, and c".
+func joinWithAnd(items []string) string {
+	switch len(items) {
+	case 0:
+		return ""
+	case 1:
+		return items[0]
+	case 2:
+		return items[0] + " and " + items[1]
+	}
+	return strings.Join(items[:len(items)-1], ", ") + ", and " + items[len(items)-1]
+}
+
+func leadingSpace(s string) string {
+	if s = strings.TrimSpace(s); s == "" {
+		return ""
+	}
+	return " " + s
+}
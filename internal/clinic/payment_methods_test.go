@@ -0,0 +1,66 @@
+package clinic
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidatePaymentMethods(t *testing.T) {
+	valid := []*PaymentMethods{
+		nil,
+		{},
+		{Accepted: []string{"cash"}},
+		{Financing: []string{"Cherry"}, Insurance: InsuranceNotAccepted},
+		{Accepted: []string{"cash"}, Insurance: InsuranceAccepted, Note: "Ask us about HSA/FSA."},
+	}
+	for _, p := range valid {
+		if err := ValidatePaymentMethods(p); err != nil {
+			t.Errorf("ValidatePaymentMethods(%+v) = %v, want nil", p, err)
+		}
+	}
+	invalid := []*PaymentMethods{
+		{Insurance: InsuranceNotAccepted},
+		{Accepted: []string{" "}, Note: "cash only"},
+		{Accepted: []string{"cash"}, Insurance: "sometimes"},
+		{Accepted: []string{"cash"}, Note: strings.Repeat("x", maxPaymentMethodsNoteChars+1)},
+	}
+	for _, p := range invalid {
+		if err := ValidatePaymentMethods(p); err == nil {
+			t.Errorf("ValidatePaymentMethods(%+v) = nil, want an error", p)
+		}
+	}
+}
+
+func TestPaymentMethodsAnswer(t *testing.T) {
+	cfg := DefaultConfig("org-1")
+	cfg.Name = "Glow Aesthetics"
+	if got := cfg.PaymentMethodsAnswer(false).Body; !strings.Contains(got, "the Glow Aesthetics team confirm") {
+		t.Fatalf("unconfigured clinic should promise a follow-up, got %q", got)
+	}
+
+	cfg.PaymentMethods = &PaymentMethods{Accepted: []string{"cash"}, Financing: []string{"Cherry"}, Note: "Cherry offers 0% APR plans."}
+	if got, want := cfg.PaymentMethodsAnswer(false).Body,
+		"Great question! We accept cash and financing through Cherry. Cherry offers 0% APR plans."; got != want {
+		t.Fatalf("answer = %q, want %q", got, want)
+	}
+	if got := cfg.PaymentMethodsAnswer(true).Body; !strings.Contains(got, "I'll have our team confirm whether insurance") {
+		t.Fatalf("unknown insurance stance should promise a follow-up, got %q", got)
+	}
+
+	cfg.PaymentMethods.Insurance = InsuranceNotAccepted
+	got := cfg.PaymentMethodsAnswer(true).Body
+	if !strings.Contains(got, "We don't accept insurance") || strings.Contains(got, "confirm whether insurance") {
+		t.Fatalf("answer should state insurance isn't accepted, got %q", got)
+	}
+	if !strings.Contains(cfg.PaymentMethodsContext(), "Insurance is NOT accepted") {
+		t.Fatalf("LLM context should forbid claiming insurance, got %q", cfg.PaymentMethodsContext())
+	}
+
+	if !cfg.PaymentMethods.OffersFinancing("cherry") || cfg.PaymentMethods.OffersFinancing("Care Credit") {
+		t.Fatal("OffersFinancing should match configured providers only")
+	}
+	cfg.PaymentMethods.Financing = []string{"CareCredit"}
+	if !cfg.PaymentMethods.OffersFinancing("Care Credit") {
+		t.Fatal("OffersFinancing should ignore spacing")
+	}
+}
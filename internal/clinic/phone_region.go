@@ -0,0 +1,21 @@
+package clinic
+
+import (
+	"strings"
+
+	"github.com/wolfman30/medspa-ai-platform/pkg/phone"
+)
+
+// PhoneRegion returns the region patient phone numbers without a country
+// code are read in.
+func (c *Config) PhoneRegion() string {
+	if c == nil || strings.TrimSpace(c.Country) == "" {
+		return phone.DefaultRegion
+	}
+	return strings.ToUpper(strings.TrimSpace(c.Country))
+}
+
+// NormalizePhone returns raw in E.164 form, read in the clinic's region.
+func (c *Config) NormalizePhone(raw string) (string, error) {
+	return phone.Normalize(raw, c.PhoneRegion())
+}
@@ -0,0 +1,72 @@
+package clinic
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// bannedPriceTermRE matches weight-loss drug names that carriers filter as
+// spam. Price text is quoted to patients verbatim, so it must never carry them.
+var bannedPriceTermRE = regexp.MustCompile(`(?i)\b(semaglutide|tirzepatide|ozempic|wegovy|mounjaro|zepbound|glp-?1)\b`)
+
+// dollarAmountRE matches the first dollar amount in price text, e.g. "$1,200"
+// in "From $1,200 per syringe".
+var dollarAmountRE = regexp.MustCompile(`\$\s*([0-9][0-9,]*(?:\.[0-9]{1,2})?)`)
+
+// ValidateServicePriceText rejects price strings that would get SMS replies
+// blocked by carriers.
+func ValidateServicePriceText(prices map[string]string) error {
+	services := make([]string, 0, len(prices))
+	for service := range prices {
+		services = append(services, service)
+	}
+	sort.Strings(services)
+	for _, service := range services {
+		if term := bannedPriceTermRE.FindString(prices[service]); term != "" {
+			return fmt.Errorf("price for %q mentions %q; weight-loss drug names are blocked by carriers", service, term)
+		}
+	}
+	return nil
+}
+
+// NormalizeServicePriceText keys prices by normalized service name and drops
+// blank entries.
+func NormalizeServicePriceText(prices map[string]string) map[string]string {
+	out := make(map[string]string, len(prices))
+	for service, price := range prices {
+		key := normalizeServiceKey(service)
+		price = strings.TrimSpace(price)
+		if key == "" || price == "" {
+			continue
+		}
+		out[key] = price
+	}
+	return out
+}
+
+// ServicePriceCents returns the first dollar amount in a service's price
+// text, trying the resolved alias when the name itself has none. 0 means no
+// usable price.
+func (c *Config) ServicePriceCents(service string) int {
+	price, ok := c.PriceTextForService(service)
+	if !ok {
+		if resolved := c.ResolveServiceName(service); resolved != service {
+			price, ok = c.PriceTextForService(resolved)
+		}
+	}
+	if !ok {
+		return 0
+	}
+	m := dollarAmountRE.FindStringSubmatch(price)
+	if m == nil {
+		return 0
+	}
+	dollars, err := strconv.ParseFloat(strings.ReplaceAll(m[1], ",", ""), 64)
+	if err != nil {
+		return 0
+	}
+	return int(dollars*100 + 0.5)
+}
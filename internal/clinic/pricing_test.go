@@ -0,0 +1,49 @@
+package clinic
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestValidateServicePriceText(t *testing.T) {
+	ok := map[string]string{
+		"botox":               "$12/unit",
+		"weight loss program": "starting at $299/month",
+		// Service names are the clinic's own; only the quoted text is checked.
+		"semaglutide": "starting at $299/month",
+	}
+	if err := ValidateServicePriceText(ok); err != nil {
+		t.Fatalf("expected clean prices to pass, got %v", err)
+	}
+
+	for _, price := range []string{"$299/mo Semaglutide", "Ozempic-style shots $350", "GLP-1 from $199", "glp1 $199", "Tirzepatide $450"} {
+		err := ValidateServicePriceText(map[string]string{"weight loss": price})
+		if err == nil || !strings.Contains(err.Error(), `"weight loss"`) {
+			t.Fatalf("expected %q to be rejected, got %v", price, err)
+		}
+	}
+}
+
+func TestNormalizeServicePriceText(t *testing.T) {
+	got := NormalizeServicePriceText(map[string]string{" Botox ": " $12/unit ", "Filler": "", "": "$5"})
+	if want := map[string]string{"botox": "$12/unit"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("NormalizeServicePriceText = %v, want %v", got, want)
+	}
+}
+
+func TestServicePriceCents(t *testing.T) {
+	cfg := &Config{
+		ServicePriceText: map[string]string{"botox": "$12/unit", "lip filler": "From $1,200 per syringe", "consult": "Free"},
+		ServiceAliases:   map[string]string{"Lip Flip": "Botox"},
+	}
+	for service, want := range map[string]int{"Botox": 1200, "lip filler": 120000, "Lip Flip": 1200, "consult": 0, "Facial": 0} {
+		if got := cfg.ServicePriceCents(service); got != want {
+			t.Errorf("ServicePriceCents(%q) = %d, want %d", service, got, want)
+		}
+	}
+	var nilCfg *Config
+	if got := nilCfg.ServicePriceCents("Botox"); got != 0 {
+		t.Errorf("nil config price = %d, want 0", got)
+	}
+}
@@ -0,0 +1,95 @@
+package clinic
+
+import "fmt"
+
+// System prompt section IDs, in assembly order.
+const (
+	PromptSectionPersona          = "persona"
+	PromptSectionGuardrails       = "guardrails"
+	PromptSectionQualification    = "qualification"
+	PromptSectionPolicies         = "policies"
+	PromptSectionServiceKnowledge = "service_knowledge"
+	PromptSectionStyle            = "style"
+	PromptSectionSafety           = "safety"
+	PromptSectionExamples         = "examples"
+	// PromptSectionClinic is empty by default; clinics append their own
+	// policies or services they don't offer here.
+	PromptSectionClinic = "clinic"
+)
+
+// PromptSections lists every section ID in assembly order.
+var PromptSections = []string{
+	PromptSectionPersona,
+	PromptSectionGuardrails,
+	PromptSectionQualification,
+	PromptSectionPolicies,
+	PromptSectionServiceKnowledge,
+	PromptSectionStyle,
+	PromptSectionSafety,
+	PromptSectionExamples,
+	PromptSectionClinic,
+}
+
+// Prompt override modes.
+const (
+	PromptOverrideReplace = "replace"
+	PromptOverrideAppend  = "append"
+)
+
+// MaxPromptOverrideChars caps the text of a single override.
+const MaxPromptOverrideChars = 4000
+
+// PromptOverride changes one section of the assistant's SMS system prompt.
+// Replace swaps the default text (empty text drops the section); append adds
+// to it.
+type PromptOverride struct {
+	Section string `json:"section"`
+	Mode    string `json:"mode"`
+	Text    string `json:"text"`
+}
+
+// PromptSectionLocked reports whether a section may only be appended to.
+// The safety section (emergencies, medical deferral) and the guardrails
+// against prompt injection can never be replaced or removed.
+func PromptSectionLocked(section string) bool {
+	return section == PromptSectionSafety || section == PromptSectionGuardrails
+}
+
+func knownPromptSection(section string) bool {
+	for _, id := range PromptSections {
+		if id == section {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidatePromptOverrides checks overrides before they are saved.
+func ValidatePromptOverrides(overrides []PromptOverride) error {
+	replaced := make(map[string]bool)
+	for i, o := range overrides {
+		if !knownPromptSection(o.Section) {
+			return fmt.Errorf("prompt_overrides[%d]: unknown section %q", i, o.Section)
+		}
+		if len(o.Text) > MaxPromptOverrideChars {
+			return fmt.Errorf("prompt_overrides[%d]: text exceeds %d characters", i, MaxPromptOverrideChars)
+		}
+		switch o.Mode {
+		case PromptOverrideAppend:
+			if o.Text == "" {
+				return fmt.Errorf("prompt_overrides[%d]: append requires text", i)
+			}
+		case PromptOverrideReplace:
+			if PromptSectionLocked(o.Section) {
+				return fmt.Errorf("prompt_overrides[%d]: section %q can only be appended to", i, o.Section)
+			}
+			if replaced[o.Section] {
+				return fmt.Errorf("prompt_overrides[%d]: section %q replaced more than once", i, o.Section)
+			}
+			replaced[o.Section] = true
+		default:
+			return fmt.Errorf("prompt_overrides[%d]: mode must be %q or %q", i, PromptOverrideReplace, PromptOverrideAppend)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,40 @@
+package clinic
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidatePromptOverrides(t *testing.T) {
+	valid := []PromptOverride{
+		{Section: PromptSectionStyle, Mode: PromptOverrideReplace, Text: "Be concise."},
+		{Section: PromptSectionExamples, Mode: PromptOverrideReplace, Text: ""},
+		{Section: PromptSectionSafety, Mode: PromptOverrideAppend, Text: "Never discuss off-label use."},
+		{Section: PromptSectionClinic, Mode: PromptOverrideAppend, Text: "We don't offer CoolSculpting."},
+	}
+	if err := ValidatePromptOverrides(valid); err != nil {
+		t.Fatalf("expected valid overrides, got %v", err)
+	}
+
+	cases := map[string]PromptOverride{
+		"unknown section":  {Section: "tone", Mode: PromptOverrideAppend, Text: "x"},
+		"bad mode":         {Section: PromptSectionStyle, Mode: "prepend", Text: "x"},
+		"empty append":     {Section: PromptSectionClinic, Mode: PromptOverrideAppend},
+		"replace safety":   {Section: PromptSectionSafety, Mode: PromptOverrideReplace, Text: "x"},
+		"drop guardrails":  {Section: PromptSectionGuardrails, Mode: PromptOverrideReplace},
+		"oversized append": {Section: PromptSectionClinic, Mode: PromptOverrideAppend, Text: strings.Repeat("x", MaxPromptOverrideChars+1)},
+	}
+	for name, o := range cases {
+		if err := ValidatePromptOverrides([]PromptOverride{o}); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+
+	twice := []PromptOverride{
+		{Section: PromptSectionStyle, Mode: PromptOverrideReplace, Text: "a"},
+		{Section: PromptSectionStyle, Mode: PromptOverrideReplace, Text: "b"},
+	}
+	if err := ValidatePromptOverrides(twice); err == nil {
+		t.Fatalf("expected error for a section replaced twice")
+	}
+}
@@ -0,0 +1,80 @@
+package clinic
+
+import (
+	"strings"
+	"time"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/messaging/templates"
+)
+
+// Default re-engagement timing, measured from the unanswered question.
+const (
+	DefaultReengagementFirstDelay = 4 * time.Hour
+	DefaultReengagementFinalDelay = 48 * time.Hour
+)
+
+// ReengagementConfig tunes the two nudges sent to a lead who leaves an
+// assistant question unanswered.
+type ReengagementConfig struct {
+	// FirstDelayMinutes is how long after the unanswered question the first
+	// nudge goes out. Zero means DefaultReengagementFirstDelay.
+	FirstDelayMinutes int `json:"first_delay_minutes,omitempty"`
+	// FinalDelayMinutes is how long after the unanswered question the final
+	// nudge goes out. Zero means DefaultReengagementFinalDelay.
+	FinalDelayMinutes int `json:"final_delay_minutes,omitempty"`
+	// FirstTemplate and FinalTemplate override the nudge wording. Both support
+	// the {{clinic_name}} and {{service}} placeholders.
+	FirstTemplate string `json:"first_template,omitempty"`
+	FinalTemplate string `json:"final_template,omitempty"`
+}
+
+// ReengagementDelays returns how long after an unanswered question the first
+// and final nudges are due.
+func (c *Config) ReengagementDelays() (first, final time.Duration) {
+	first, final = DefaultReengagementFirstDelay, DefaultReengagementFinalDelay
+	if c == nil || c.Reengagement == nil {
+		return first, final
+	}
+	if c.Reengagement.FirstDelayMinutes > 0 {
+		first = time.Duration(c.Reengagement.FirstDelayMinutes) * time.Minute
+	}
+	if c.Reengagement.FinalDelayMinutes > 0 {
+		final = time.Duration(c.Reengagement.FinalDelayMinutes) * time.Minute
+	}
+	if final <= first {
+		final = first + DefaultReengagementFirstDelay
+	}
+	return first, final
+}
+
+// ReengagementMessage renders the first nudge, or the final one when final
+// is set, for a lead asking about service. service may be empty.
+func (c *Config) ReengagementMessage(final bool, service string) string {
+	return c.RenderReengagement(final, service).Body
+}
+
+// RenderReengagement is ReengagementMessage along with the template it was
+// rendered from.
+func (c *Config) RenderReengagement(final bool, service string) templates.Rendered {
+	service = strings.TrimSpace(service)
+	var id string
+	switch {
+	case service != "" && final:
+		id = templates.NudgeReengagementFinal
+	case service != "":
+		id = templates.NudgeReengagementFirst
+	case final:
+		id = templates.NudgeReengagementFinalGeneric
+	default:
+		id = templates.NudgeReengagementFirstGeneric
+	}
+	// The generic defaults don't mention the service, but clinic copy may.
+	if _, _, custom := templates.Default.Effective(id, c.TemplateOverrides()); custom && service == "" {
+		service = firstContactServiceFallback
+	}
+	name := "us"
+	if c != nil && strings.TrimSpace(c.Name) != "" {
+		name = strings.TrimSpace(c.Name)
+	}
+	return c.RenderTemplate(id, templates.Params{"clinic_name": name, "service": service})
+}
@@ -0,0 +1,21 @@
+package clinic
+
+import "time"
+
+// DefaultDataRetentionMonths is the retention window for clinics that have
+// not configured one.
+const DefaultDataRetentionMonths = 18
+
+// RetentionMonths returns the clinic's data retention window in months.
+func (c *Config) RetentionMonths() int {
+	if c == nil || c.DataRetentionMonths <= 0 {
+		return DefaultDataRetentionMonths
+	}
+	return c.DataRetentionMonths
+}
+
+// RetentionCutoff returns the time before which inactive patient data is
+// purged.
+func (c *Config) RetentionCutoff(now time.Time) time.Time {
+	return now.AddDate(0, -c.RetentionMonths(), 0)
+}
@@ -3,6 +3,9 @@ package clinic
 import (
 	"sort"
 	"strings"
+	"time"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/services"
 )
 
 // normalizeServiceKey lowercases and trims whitespace from a service name for map lookups.
@@ -11,41 +14,31 @@ func normalizeServiceKey(service string) string {
 }
 
 // ResolveServiceName translates a patient-facing service name (e.g. "Botox") into the
-// booking-platform search term using the clinic's ServiceAliases map. If no alias is
-// configured the original name is returned unchanged.
+// booking-platform search term using the clinic's ServiceAliases map. Keys match
+// regardless of case, punctuation, plurals or spacing ("lip fillers", "micro
+// needling"), then by the longest key the name contains. If no alias applies the
+// original name is returned unchanged.
 func (c *Config) ResolveServiceName(service string) string {
 	if c == nil || len(c.ServiceAliases) == 0 {
 		return service
 	}
-	key := normalizeServiceKey(service)
-	if alias, ok := c.ServiceAliases[key]; ok && alias != "" {
+	if alias := services.LookupAlias(c.ServiceAliases, service); alias != "" {
 		return alias
 	}
-	// Try without trailing 's' (handle plurals like "lip fillers" → "lip filler")
-	if strings.HasSuffix(key, "s") {
-		if alias, ok := c.ServiceAliases[strings.TrimSuffix(key, "s")]; ok && alias != "" {
-			return alias
-		}
-	}
-	// Fuzzy match: check if the service contains an alias key or vice versa.
-	// Prefer the longest matching key to avoid "filler" matching before "lip filler".
-	bestAlias := ""
-	bestKeyLen := 0
-	for aliasKey, alias := range c.ServiceAliases {
-		if alias == "" {
-			continue
-		}
-		if strings.Contains(key, aliasKey) || strings.Contains(aliasKey, key) {
-			if len(aliasKey) > bestKeyLen {
-				bestAlias = alias
-				bestKeyLen = len(aliasKey)
-			}
-		}
+	return service
+}
+
+// ServiceCatalog describes the clinic's bookable services for the services
+// resolver.
+func (c *Config) ServiceCatalog() services.Catalog {
+	if c == nil {
+		return services.Catalog{}
 	}
-	if bestAlias != "" {
-		return bestAlias
+	catalog := services.Catalog{Services: c.Services, Aliases: c.ServiceAliases}
+	if c.MoxieConfig != nil {
+		catalog.MenuItems = c.MoxieConfig.ServiceMenuItems
 	}
-	return service
+	return catalog
 }
 
 // GetServiceVariants returns the delivery variants for a service, if any.
@@ -79,6 +72,54 @@ func (c *Config) GetServiceVariants(service string) []string {
 	return nil
 }
 
+// ExtraQualification is a service-specific question the AI asks once the
+// standard qualifications are in, before fetching availability.
+type ExtraQualification struct {
+	// Key names the answer on the lead's preferences, e.g. "area".
+	Key string `json:"key"`
+	// Question is sent to the patient as written.
+	Question string `json:"question"`
+	// AllowedAnswers limits the answer to these values. Empty accepts any
+	// reply to the question.
+	AllowedAnswers []string `json:"allowed_answers,omitempty"`
+	// Hints maps an allowed answer to other words patients use for it,
+	// e.g. {"Underarms": ["armpits", "pits"]}.
+	Hints map[string][]string `json:"hints,omitempty"`
+}
+
+// ExtraQualificationFor returns the extra qualification configured for a
+// service, or nil. Like GetServiceVariants, "laser hair removal - full body"
+// falls back to a "laser hair removal" entry.
+func (c *Config) ExtraQualificationFor(service string) *ExtraQualification {
+	if c == nil || len(c.ExtraQualifications) == 0 {
+		return nil
+	}
+	key := normalizeServiceKey(service)
+	if key == "" {
+		return nil
+	}
+	usable := func(q ExtraQualification) bool {
+		return strings.TrimSpace(q.Key) != "" && strings.TrimSpace(q.Question) != ""
+	}
+	if q, ok := c.ExtraQualifications[key]; ok && usable(q) {
+		return &q
+	}
+	// Prefer the longest matching key so "laser hair removal" beats "laser".
+	var best *ExtraQualification
+	bestKeyLen := 0
+	for qualKey, q := range c.ExtraQualifications {
+		if !usable(q) || len(qualKey) <= bestKeyLen {
+			continue
+		}
+		if strings.Contains(key, qualKey) || strings.Contains(qualKey, key) {
+			q := q
+			best = &q
+			bestKeyLen = len(qualKey)
+		}
+	}
+	return best
+}
+
 // ResolveProviderID returns the Moxie userMedspaId for a provider name (case-insensitive partial match).
 // Returns "" if no match found.
 func (c *Config) ResolveProviderID(providerName string) string {
@@ -124,15 +165,24 @@ func (c *Config) ServiceNeedsProviderPreference(serviceName string) bool {
 
 // ServiceMenuItemID resolves a patient-facing service name to a Moxie serviceMenuItemId.
 func (c *Config) ServiceMenuItemID(serviceName string) string {
-	if c.MoxieConfig == nil || c.MoxieConfig.ServiceMenuItems == nil {
+	if id := c.ConsultServiceID(serviceName); id != "" {
+		return id
+	}
+	if c == nil || c.MoxieConfig == nil || c.MoxieConfig.ServiceMenuItems == nil {
 		return ""
 	}
 	resolved := c.ResolveServiceName(serviceName)
-	itemID := c.MoxieConfig.ServiceMenuItems[strings.ToLower(resolved)]
-	if itemID == "" {
-		itemID = c.MoxieConfig.ServiceMenuItems[strings.ToLower(serviceName)]
+	if itemID := c.MoxieConfig.ServiceMenuItems[strings.ToLower(resolved)]; itemID != "" {
+		return itemID
+	}
+	if itemID := c.MoxieConfig.ServiceMenuItems[strings.ToLower(serviceName)]; itemID != "" {
+		return itemID
+	}
+	// Plurals, spacing and typos ("chemical peels", "micro needling").
+	if _, itemID, confidence := services.ResolveService(c, resolved); confidence >= services.ConfidenceThreshold {
+		return itemID
 	}
-	return itemID
+	return ""
 }
 
 // ProviderNamesForService returns deterministic provider display names for a service.
@@ -262,12 +312,64 @@ func (c *Config) UsesSquarePayment() bool {
 	return platform == "" || platform == "square"
 }
 
+// DepositPolicyForService returns the deposit policy configured for a service,
+// trying the resolved alias when the name itself has no policy.
+func (c *Config) DepositPolicyForService(service string) (ServiceDepositPolicy, bool) {
+	if c == nil || len(c.ServiceDepositPolicies) == 0 {
+		return ServiceDepositPolicy{}, false
+	}
+	key := normalizeServiceKey(service)
+	if key == "" {
+		return ServiceDepositPolicy{}, false
+	}
+	if policy, ok := c.ServiceDepositPolicies[key]; ok {
+		return policy, true
+	}
+	if resolved := normalizeServiceKey(c.ResolveServiceName(service)); resolved != "" && resolved != key {
+		if policy, ok := c.ServiceDepositPolicies[resolved]; ok {
+			return policy, true
+		}
+	}
+	return ServiceDepositPolicy{}, false
+}
+
+// DepositRequiredForService reports whether booking a service needs a deposit.
+// Services without a policy require one.
+func (c *Config) DepositRequiredForService(service string) bool {
+	policy, ok := c.DepositPolicyForService(service)
+	return !ok || policy.RequireDeposit
+}
+
+// DepositExemptServices returns the services whose policy waives the deposit, sorted.
+func (c *Config) DepositExemptServices() []string {
+	if c == nil {
+		return nil
+	}
+	var exempt []string
+	for service, policy := range c.ServiceDepositPolicies {
+		if !policy.RequireDeposit {
+			exempt = append(exempt, service)
+		}
+	}
+	sort.Strings(exempt)
+	return exempt
+}
+
 // DepositAmountForService returns the configured deposit amount (in cents) for a service,
-// falling back to the clinic default when no override is present.
+// falling back to the clinic default when no override is present. Returns 0 for
+// services whose policy exempts them from a deposit.
 func (c *Config) DepositAmountForService(service string) int {
 	if c == nil {
 		return 0
 	}
+	if policy, ok := c.DepositPolicyForService(service); ok {
+		if !policy.RequireDeposit {
+			return 0
+		}
+		if policy.AmountCents > 0 {
+			return policy.AmountCents
+		}
+	}
 	key := normalizeServiceKey(service)
 	if key != "" && c.ServiceDepositAmountCents != nil {
 		if amount, ok := c.ServiceDepositAmountCents[key]; ok && amount > 0 {
@@ -280,6 +382,48 @@ func (c *Config) DepositAmountForService(service string) int {
 	return 0
 }
 
+// DurationForService returns the configured appointment length for a service,
+// trying the resolved alias when the name itself has none. Zero means unknown.
+func (c *Config) DurationForService(service string) time.Duration {
+	if c == nil || len(c.ServiceDurationMinutes) == 0 {
+		return 0
+	}
+	key := normalizeServiceKey(service)
+	if key == "" {
+		return 0
+	}
+	minutes := c.ServiceDurationMinutes[key]
+	if minutes <= 0 {
+		if resolved := normalizeServiceKey(c.ResolveServiceName(service)); resolved != "" && resolved != key {
+			minutes = c.ServiceDurationMinutes[resolved]
+		}
+	}
+	if minutes <= 0 {
+		return 0
+	}
+	return time.Duration(minutes) * time.Minute
+}
+
+// CategoryForService returns the menu category configured for a service,
+// trying the resolved alias when the name itself has none. Empty means
+// uncategorized.
+func (c *Config) CategoryForService(service string) string {
+	if c == nil || len(c.ServiceCategories) == 0 {
+		return ""
+	}
+	key := normalizeServiceKey(service)
+	if key == "" {
+		return ""
+	}
+	category := c.ServiceCategories[key]
+	if category == "" {
+		if resolved := normalizeServiceKey(c.ResolveServiceName(service)); resolved != "" && resolved != key {
+			category = c.ServiceCategories[resolved]
+		}
+	}
+	return strings.TrimSpace(category)
+}
+
 // PriceTextForService returns a configured price string for a service when available.
 func (c *Config) PriceTextForService(service string) (string, bool) {
 	if c == nil || c.ServicePriceText == nil {
@@ -0,0 +1,321 @@
+package clinic
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/wolfman30/medspa-ai-platform/internal/http/apierror"
+)
+
+// slaPeriods are the windows the portal's SLA stats can be requested for.
+var slaPeriods = map[string]time.Duration{
+	"7d":  7 * 24 * time.Hour,
+	"30d": 30 * 24 * time.Hour,
+}
+
+// ackMessageKinds are outbound messages sent automatically ahead of the real
+// answer. Response time runs to the first reply that isn't one of these.
+var ackMessageKinds = []string{
+	"ack",
+	"first_contact",
+	"first_contact_ack",
+	"progress",
+	"stop_ack",
+	"help_ack",
+	"start_ack",
+	"voice_ack",
+	"voice_callback_ack",
+}
+
+// SLAStats is a clinic's response-time and conversion performance over a
+// period, as shown in the clinic portal.
+type SLAStats struct {
+	OrgID        string            `json:"org_id"`
+	Period       string            `json:"period"`
+	PeriodStart  string            `json:"period_start"`
+	PeriodEnd    string            `json:"period_end"`
+	ResponseTime ResponseTimeStats `json:"response_time"`
+	Funnel       ConversionFunnel  `json:"funnel"`
+}
+
+// ResponseTimeStats summarizes the time from a patient's text to the first
+// substantive reply. Texts the clinic deferred until it reopened are counted
+// in AfterHoursExcluded instead of the percentiles.
+type ResponseTimeStats struct {
+	Samples            int     `json:"samples"`
+	MedianSeconds      float64 `json:"median_seconds"`
+	P95Seconds         float64 `json:"p95_seconds"`
+	AfterHoursExcluded int     `json:"after_hours_excluded"`
+}
+
+// ConversionFunnel follows the conversations started in a period through to
+// a confirmed booking. Each rate is a percentage of the stage before it.
+type ConversionFunnel struct {
+	Conversations       int64   `json:"conversations"`
+	SlotsPresented      int64   `json:"slots_presented"`
+	DepositsPaid        int64   `json:"deposits_paid"`
+	BookingsConfirmed   int64   `json:"bookings_confirmed"`
+	SlotsPresentedPct   float64 `json:"slots_presented_pct"`
+	DepositPaidPct      float64 `json:"deposit_paid_pct"`
+	BookingConfirmedPct float64 `json:"booking_confirmed_pct"`
+}
+
+// ResponseTurn is a patient's text and the first substantive reply after it.
+type ResponseTurn struct {
+	ConversationID string
+	InboundAt      time.Time
+	RepliedAt      time.Time
+}
+
+// ResponseTurns returns each patient text received in [start, end) that got
+// a substantive reply, excluding conversations with the given test phones.
+func (r *StatsRepository) ResponseTurns(ctx context.Context, orgID string, start, end time.Time, testPhones []string) ([]ResponseTurn, error) {
+	query := `
+		SELECT m.conversation_id, m.created_at, reply.created_at
+		FROM conversation_messages m
+		JOIN conversations c ON c.conversation_id = m.conversation_id
+		JOIN LATERAL (
+			SELECT a.created_at
+			FROM conversation_messages a
+			WHERE a.conversation_id = m.conversation_id
+			  AND a.role = 'assistant'
+			  AND a.created_at >= m.created_at
+			  AND NOT (COALESCE(a.kind, '') = ANY($5))
+			ORDER BY a.created_at
+			LIMIT 1
+		) reply ON TRUE
+		WHERE c.org_id = $1
+		  AND m.role = 'user'
+		  AND m.created_at >= $2
+		  AND m.created_at < $3
+		  AND NOT (right(regexp_replace(c.phone, '\D', '', 'g'), 10) = ANY($4))
+		ORDER BY m.created_at
+	`
+	rows, err := r.db.Query(ctx, query, orgID, start, end, phoneMatchKeys(testPhones), ackMessageKinds)
+	if err != nil {
+		return nil, fmt.Errorf("clinic stats: query response turns: %w", err)
+	}
+	defer rows.Close()
+
+	var turns []ResponseTurn
+	for rows.Next() {
+		var t ResponseTurn
+		if err := rows.Scan(&t.ConversationID, &t.InboundAt, &t.RepliedAt); err != nil {
+			return nil, fmt.Errorf("clinic stats: scan response turn: %w", err)
+		}
+		turns = append(turns, t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("clinic stats: iterate response turns: %w", err)
+	}
+	return turns, nil
+}
+
+// ConversionFunnel counts the conversations started in [start, end) at each
+// stage, excluding conversations with the given test phones. Deposits and
+// bookings are matched through the conversation's lead, whenever they
+// happened. A paid deposit implies the patient was offered a time, even when
+// the times went out in an AI reply rather than a time-selection message.
+func (r *StatsRepository) ConversionFunnel(ctx context.Context, orgID string, start, end time.Time, testPhones []string) (ConversionFunnel, error) {
+	query := `
+		SELECT COUNT(*),
+		       COUNT(*) FILTER (WHERE presented OR paid),
+		       COUNT(*) FILTER (WHERE paid),
+		       COUNT(*) FILTER (WHERE paid AND booked)
+		FROM (
+			SELECT EXISTS (
+			         SELECT 1 FROM conversation_messages m
+			         WHERE m.conversation_id = c.conversation_id AND m.kind = 'time_selection'
+			       ) AS presented,
+			       EXISTS (
+			         SELECT 1 FROM payments p
+			         WHERE p.org_id = c.org_id AND p.lead_id = c.lead_id AND p.status = 'succeeded'
+			       ) AS paid,
+			       EXISTS (
+			         SELECT 1 FROM bookings b
+			         WHERE b.org_id = c.org_id AND b.lead_id = c.lead_id AND b.confirmed_at IS NOT NULL
+			       ) AS booked
+			FROM conversations c
+			WHERE c.org_id = $1
+			  AND c.started_at >= $2
+			  AND c.started_at < $3
+			  AND NOT (right(regexp_replace(c.phone, '\D', '', 'g'), 10) = ANY($4))
+			  AND NOT EXISTS (
+			        SELECT 1 FROM leads l
+			        WHERE l.id = c.lead_id AND l.probable_wrong_number
+			      )
+		) stages
+	`
+	var f ConversionFunnel
+	if err := r.db.QueryRow(ctx, query, orgID, start, end, phoneMatchKeys(testPhones)).
+		Scan(&f.Conversations, &f.SlotsPresented, &f.DepositsPaid, &f.BookingsConfirmed); err != nil {
+		return ConversionFunnel{}, fmt.Errorf("clinic stats: count conversion funnel: %w", err)
+	}
+	f.SlotsPresentedPct = percentOf(f.SlotsPresented, f.Conversations)
+	f.DepositPaidPct = percentOf(f.DepositsPaid, f.SlotsPresented)
+	f.BookingConfirmedPct = percentOf(f.BookingsConfirmed, f.DepositsPaid)
+	return f, nil
+}
+
+// GetSLAStats aggregates response time and conversion for [start, end).
+// cfg supplies the clinic's test phones and business hours; nil applies
+// neither exclusion.
+func (r *StatsRepository) GetSLAStats(ctx context.Context, orgID string, cfg *Config, start, end time.Time) (*SLAStats, error) {
+	var testPhones []string
+	if cfg != nil {
+		testPhones = cfg.TestPhones
+	}
+	turns, err := r.ResponseTurns(ctx, orgID, start, end, testPhones)
+	if err != nil {
+		return nil, err
+	}
+	funnel, err := r.ConversionFunnel(ctx, orgID, start, end, testPhones)
+	if err != nil {
+		return nil, err
+	}
+	return &SLAStats{
+		OrgID:        orgID,
+		PeriodStart:  start.UTC().Format(time.RFC3339),
+		PeriodEnd:    end.UTC().Format(time.RFC3339),
+		ResponseTime: summarizeResponseTimes(turns, cfg),
+		Funnel:       funnel,
+	}, nil
+}
+
+// summarizeResponseTimes computes the response-time percentiles. Several
+// texts answered by one reply are one turn, timed from the first text.
+func summarizeResponseTimes(turns []ResponseTurn, cfg *Config) ResponseTimeStats {
+	type replyKey struct {
+		conversationID string
+		repliedAt      int64
+	}
+	first := make(map[replyKey]ResponseTurn, len(turns))
+	for _, t := range turns {
+		key := replyKey{t.ConversationID, t.RepliedAt.UnixNano()}
+		if existing, ok := first[key]; !ok || t.InboundAt.Before(existing.InboundAt) {
+			first[key] = t
+		}
+	}
+
+	var stats ResponseTimeStats
+	seconds := make([]float64, 0, len(first))
+	for _, t := range first {
+		if isAfterHoursDeferral(cfg, t) {
+			stats.AfterHoursExcluded++
+			continue
+		}
+		seconds = append(seconds, t.RepliedAt.Sub(t.InboundAt).Seconds())
+	}
+	sort.Float64s(seconds)
+	stats.Samples = len(seconds)
+	stats.MedianSeconds = percentile(seconds, 0.5)
+	stats.P95Seconds = percentile(seconds, 0.95)
+	return stats
+}
+
+// isAfterHoursDeferral reports whether a text arrived while the clinic was
+// closed and wasn't answered until it reopened. Those waits measure the
+// clinic's hours, not its responsiveness.
+func isAfterHoursDeferral(cfg *Config, t ResponseTurn) bool {
+	if cfg == nil || cfg.IsOpenAt(t.InboundAt) {
+		return false
+	}
+	return !t.RepliedAt.Before(cfg.NextOpenTime(t.InboundAt))
+}
+
+// percentile returns the p-th percentile of sorted values, interpolating
+// linearly between the nearest ranks.
+func percentile(sorted []float64, p float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := p * float64(len(sorted)-1)
+	lo := int(math.Floor(rank))
+	hi := int(math.Ceil(rank))
+	return sorted[lo] + (sorted[hi]-sorted[lo])*(rank-float64(lo))
+}
+
+func percentOf(part, whole int64) float64 {
+	if whole == 0 {
+		return 0
+	}
+	return float64(part) / float64(whole) * 100.0
+}
+
+// phoneMatchKeys reduces phones to their last ten digits, the form the stats
+// queries compare conversation phones in. It never returns nil: a NULL array
+// would make the NOT ANY filter drop every row.
+func phoneMatchKeys(phones []string) []string {
+	keys := []string{}
+	for _, phone := range phones {
+		var b strings.Builder
+		for _, r := range phone {
+			if r >= '0' && r <= '9' {
+				b.WriteRune(r)
+			}
+		}
+		digits := b.String()
+		if digits == "" {
+			continue
+		}
+		if len(digits) > 10 {
+			digits = digits[len(digits)-10:]
+		}
+		keys = append(keys, digits)
+	}
+	return keys
+}
+
+// GetSLAStats returns response-time and conversion SLAs for a clinic.
+// GET /admin/orgs/{orgID}/stats
+// Query params:
+//   - period: "7d" (default) or "30d", ending now
+func (h *StatsHandler) GetSLAStats(w http.ResponseWriter, r *http.Request) {
+	orgID := chi.URLParam(r, "orgID")
+	if orgID == "" {
+		apierror.Write(w, r, apierror.CodeValidationFailed, "org_id required")
+		return
+	}
+
+	period := strings.TrimSpace(r.URL.Query().Get("period"))
+	if period == "" {
+		period = "7d"
+	}
+	window, ok := slaPeriods[period]
+	if !ok {
+		apierror.WriteDetails(w, r, apierror.CodeValidationFailed, "period must be 7d or 30d", map[string]any{"period": period})
+		return
+	}
+
+	var cfg *Config
+	if h.configs != nil {
+		loaded, err := h.configs.Get(r.Context(), orgID)
+		if err != nil {
+			h.logger.Error("failed to load clinic config for sla stats", "org_id", orgID, "error", err)
+			apierror.WriteError(w, r, err, apierror.CodeInternal, "failed to load clinic config")
+			return
+		}
+		cfg = loaded
+	}
+
+	end := h.now().UTC()
+	stats, err := h.repo.GetSLAStats(r.Context(), orgID, cfg, end.Add(-window), end)
+	if err != nil {
+		h.logger.Error("failed to get clinic sla stats", "org_id", orgID, "error", err)
+		apierror.WriteError(w, r, err, apierror.CodeInternal, "failed to get clinic stats")
+		return
+	}
+	stats.Period = period
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(stats); err != nil {
+		h.logger.Error("failed to encode clinic sla stats", "org_id", orgID, "error", err)
+	}
+}
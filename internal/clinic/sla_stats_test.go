@@ -0,0 +1,188 @@
+package clinic
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/pashagolub/pgxmock/v4"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/http/apierror"
+	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
+)
+
+type stubStatsConfigs struct {
+	cfg *Config
+}
+
+func (s stubStatsConfigs) Get(ctx context.Context, orgID string) (*Config, error) {
+	return s.cfg, nil
+}
+
+func weekdayHoursConfig() *Config {
+	day := &DayHours{Open: "09:00", Close: "17:00"}
+	return &Config{
+		OrgID:    "org-123",
+		Timezone: "America/New_York",
+		BusinessHours: BusinessHours{
+			Monday: day, Tuesday: day, Wednesday: day, Thursday: day, Friday: day,
+		},
+		TestPhones: []string{"+1 (555) 000-1111"},
+	}
+}
+
+func TestSummarizeResponseTimes_Percentiles(t *testing.T) {
+	loc, _ := time.LoadLocation("America/New_York")
+	// Tuesday mid-morning, while the clinic is open.
+	base := time.Date(2026, 3, 10, 10, 0, 0, 0, loc)
+
+	var turns []ResponseTurn
+	for i := 1; i <= 10; i++ {
+		inbound := base.Add(time.Duration(i) * time.Minute)
+		turns = append(turns, ResponseTurn{
+			ConversationID: fmt.Sprintf("sms:org-123:155500000%02d", i),
+			InboundAt:      inbound,
+			RepliedAt:      inbound.Add(time.Duration(i*10) * time.Second),
+		})
+	}
+	// A patient sends two texts 5s apart; the single reply to both is one
+	// turn timed from the first text (10s), not a second 5s sample.
+	burstReply := base.Add(time.Hour + 10*time.Second)
+	turns = append(turns,
+		ResponseTurn{ConversationID: "sms:org-123:15550002222", InboundAt: base.Add(time.Hour), RepliedAt: burstReply},
+		ResponseTurn{ConversationID: "sms:org-123:15550002222", InboundAt: base.Add(time.Hour + 5*time.Second), RepliedAt: burstReply},
+	)
+
+	stats := summarizeResponseTimes(turns, weekdayHoursConfig())
+	if stats.Samples != 11 {
+		t.Fatalf("Samples = %d, want 11", stats.Samples)
+	}
+	// Sorted: 10, 10, 20, ..., 100. Median is the 6th value; p95 sits at
+	// rank 9.5, halfway between 90 and 100.
+	if stats.MedianSeconds != 50 {
+		t.Errorf("MedianSeconds = %v, want 50", stats.MedianSeconds)
+	}
+	if stats.P95Seconds != 95 {
+		t.Errorf("P95Seconds = %v, want 95", stats.P95Seconds)
+	}
+
+	if got := summarizeResponseTimes(nil, nil); got != (ResponseTimeStats{}) {
+		t.Errorf("empty stats = %+v", got)
+	}
+}
+
+func TestSummarizeResponseTimes_ExcludesAfterHoursDeferrals(t *testing.T) {
+	loc, _ := time.LoadLocation("America/New_York")
+	tuesdayNight := time.Date(2026, 3, 10, 22, 0, 0, 0, loc)
+	wednesdayOpen := time.Date(2026, 3, 11, 9, 5, 0, 0, loc)
+	turns := []ResponseTurn{
+		// Open hours: counted.
+		{ConversationID: "a", InboundAt: time.Date(2026, 3, 10, 11, 0, 0, 0, loc), RepliedAt: time.Date(2026, 3, 10, 11, 0, 20, 0, loc)},
+		// Closed, and only answered after opening: deferred, excluded.
+		{ConversationID: "b", InboundAt: tuesdayNight, RepliedAt: wednesdayOpen},
+		// Closed but answered right away: still counts.
+		{ConversationID: "c", InboundAt: tuesdayNight, RepliedAt: tuesdayNight.Add(40 * time.Second)},
+	}
+
+	stats := summarizeResponseTimes(turns, weekdayHoursConfig())
+	if stats.Samples != 2 || stats.AfterHoursExcluded != 1 {
+		t.Fatalf("samples=%d excluded=%d, want 2 and 1", stats.Samples, stats.AfterHoursExcluded)
+	}
+	if stats.MedianSeconds != 30 {
+		t.Errorf("MedianSeconds = %v, want 30", stats.MedianSeconds)
+	}
+
+	// Without a clinic config nothing is treated as deferred.
+	if stats := summarizeResponseTimes(turns, nil); stats.Samples != 3 || stats.AfterHoursExcluded != 0 {
+		t.Errorf("without config: %+v", stats)
+	}
+}
+
+func TestPhoneMatchKeys(t *testing.T) {
+	got := phoneMatchKeys([]string{"+1 (555) 000-1111", "5550002222", "  "})
+	if len(got) != 2 || got[0] != "5550001111" || got[1] != "5550002222" {
+		t.Fatalf("phoneMatchKeys = %v", got)
+	}
+	if keys := phoneMatchKeys(nil); keys == nil {
+		t.Fatal("expected an empty, non-nil slice")
+	}
+}
+
+func TestStatsHandler_GetSLAStats(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("failed to create mock pool: %v", err)
+	}
+	defer mock.Close()
+
+	now := time.Date(2026, 3, 31, 12, 0, 0, 0, time.UTC)
+	start := now.Add(-30 * 24 * time.Hour)
+	testPhones := []string{"5550001111"}
+
+	mock.ExpectQuery(`FROM conversation_messages m\s+JOIN conversations c`).
+		WithArgs("org-123", start, now, testPhones, ackMessageKinds).
+		WillReturnRows(pgxmock.NewRows([]string{"conversation_id", "inbound_at", "replied_at"}).
+			AddRow("sms:org-123:15550003333", now.Add(-48*time.Hour), now.Add(-48*time.Hour+30*time.Second)).
+			AddRow("sms:org-123:15550004444", now.Add(-24*time.Hour), now.Add(-24*time.Hour+90*time.Second)))
+	mock.ExpectQuery(`(?s)COUNT\(\*\) FILTER \(WHERE presented OR paid\).*l\.probable_wrong_number`).
+		WithArgs("org-123", start, now, testPhones).
+		WillReturnRows(pgxmock.NewRows([]string{"conversations", "presented", "paid", "booked"}).
+			AddRow(int64(40), int64(20), int64(10), int64(8)))
+
+	handler := NewStatsHandler(NewStatsRepositoryWithDB(mock), logging.Default())
+	handler.SetConfigStore(stubStatsConfigs{cfg: &Config{OrgID: "org-123", TestPhones: []string{"+15550001111"}}})
+	handler.now = func() time.Time { return now }
+
+	r := chi.NewRouter()
+	r.Get("/admin/orgs/{orgID}/stats", handler.GetSLAStats)
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/admin/orgs/org-123/stats?period=30d", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, body %s", rec.Code, rec.Body.String())
+	}
+	var stats SLAStats
+	if err := json.NewDecoder(rec.Body).Decode(&stats); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if stats.Period != "30d" || stats.PeriodStart != start.Format(time.RFC3339) {
+		t.Errorf("period = %q from %q", stats.Period, stats.PeriodStart)
+	}
+	if stats.ResponseTime.Samples != 2 || stats.ResponseTime.MedianSeconds != 60 {
+		t.Errorf("ResponseTime = %+v, want 2 samples with a 60s median", stats.ResponseTime)
+	}
+	want := ConversionFunnel{
+		Conversations: 40, SlotsPresented: 20, DepositsPaid: 10, BookingsConfirmed: 8,
+		SlotsPresentedPct: 50, DepositPaidPct: 50, BookingConfirmedPct: 80,
+	}
+	if stats.Funnel != want {
+		t.Errorf("Funnel = %+v, want %+v", stats.Funnel, want)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
+func TestStatsHandler_GetSLAStats_InvalidPeriod(t *testing.T) {
+	handler := NewStatsHandler(NewStatsRepositoryWithDB(nil), logging.Default())
+	r := chi.NewRouter()
+	r.Get("/admin/orgs/{orgID}/stats", handler.GetSLAStats)
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/admin/orgs/org-123/stats?period=90d", nil))
+
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d", rec.Code)
+	}
+	var env apierror.Envelope
+	if err := json.NewDecoder(rec.Body).Decode(&env); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if env.Error.Code != apierror.CodeValidationFailed || env.Error.Details["period"] != "90d" {
+		t.Fatalf("unexpected error body: %+v", env.Error)
+	}
+}
@@ -22,11 +22,21 @@ type Stats struct {
 	DepositAmountTotal   int64  `json:"deposit_amount_total_cents"`
 	PeriodStart          string `json:"period_start"`
 	PeriodEnd            string `json:"period_end"`
+	// ProviderBookings counts bookings per Moxie provider so clinics can check
+	// that "no preference" patients are spread evenly.
+	ProviderBookings []ProviderBookingCount `json:"provider_bookings"`
+}
+
+// ProviderBookingCount is one provider's booking total for the period.
+type ProviderBookingCount struct {
+	ProviderID string `json:"provider_id"`
+	Bookings   int64  `json:"bookings"`
 }
 
 // statsDB defines the database interface needed by StatsRepository
 type statsDB interface {
 	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
 }
 
 // StatsRepository queries clinic metrics from the database.
@@ -68,8 +78,8 @@ func (r *StatsRepository) GetStats(ctx context.Context, orgID string, start, end
 		stats.PeriodEnd = "now"
 	}
 
-	// Count leads (conversations_started)
-	leadsQuery := `SELECT COUNT(*) FROM leads WHERE org_id = $1` + timeFilter
+	// Count leads (conversations_started), leaving out wrong numbers
+	leadsQuery := `SELECT COUNT(*) FROM leads WHERE org_id = $1 AND NOT probable_wrong_number` + timeFilter
 	if err := r.db.QueryRow(ctx, leadsQuery, args...).Scan(&stats.ConversationsStarted); err != nil {
 		return nil, fmt.Errorf("clinic stats: count leads: %w", err)
 	}
@@ -92,13 +102,40 @@ func (r *StatsRepository) GetStats(ctx context.Context, orgID string, start, end
 		return nil, fmt.Errorf("clinic stats: sum amount: %w", err)
 	}
 
+	// Count bookings per provider (provider_bookings)
+	providerQuery := `SELECT provider_id, COUNT(*) FROM bookings WHERE org_id = $1 AND provider_id IS NOT NULL` + timeFilter + ` GROUP BY provider_id ORDER BY provider_id`
+	rows, err := r.db.Query(ctx, providerQuery, args...)
+	if err != nil {
+		return nil, fmt.Errorf("clinic stats: count provider bookings: %w", err)
+	}
+	defer rows.Close()
+	stats.ProviderBookings = []ProviderBookingCount{}
+	for rows.Next() {
+		var c ProviderBookingCount
+		if err := rows.Scan(&c.ProviderID, &c.Bookings); err != nil {
+			return nil, fmt.Errorf("clinic stats: scan provider bookings: %w", err)
+		}
+		stats.ProviderBookings = append(stats.ProviderBookings, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("clinic stats: count provider bookings: %w", err)
+	}
+
 	return stats, nil
 }
 
+// statsConfigSource loads the clinic config used to exclude test
+// conversations and after-hours deferrals from SLA stats.
+type statsConfigSource interface {
+	Get(ctx context.Context, orgID string) (*Config, error)
+}
+
 // StatsHandler provides HTTP endpoints for clinic statistics.
 type StatsHandler struct {
-	repo   *StatsRepository
-	logger *logging.Logger
+	repo    *StatsRepository
+	configs statsConfigSource
+	logger  *logging.Logger
+	now     func() time.Time
 }
 
 // NewStatsHandler creates a new stats HTTP handler.
@@ -109,9 +146,16 @@ func NewStatsHandler(repo *StatsRepository, logger *logging.Logger) *StatsHandle
 	return &StatsHandler{
 		repo:   repo,
 		logger: logger,
+		now:    time.Now,
 	}
 }
 
+// SetConfigStore enables the clinic-config exclusions (test phones, business
+// hours) in SLA stats.
+func (h *StatsHandler) SetConfigStore(store statsConfigSource) {
+	h.configs = store
+}
+
 // GetStats returns aggregated metrics for a clinic.
 // GET /admin/clinics/{orgID}/stats
 // Query params:
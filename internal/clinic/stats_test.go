@@ -22,8 +22,8 @@ func TestStatsRepository_GetStats_AllTime(t *testing.T) {
 
 	orgID := "org-123"
 
-	// Expect leads count query
-	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM leads WHERE org_id = \$1`).
+	// Expect leads count query, without wrong numbers
+	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM leads WHERE org_id = \$1 AND NOT probable_wrong_number`).
 		WithArgs(orgID).
 		WillReturnRows(pgxmock.NewRows([]string{"count"}).AddRow(int64(42)))
 
@@ -42,6 +42,11 @@ func TestStatsRepository_GetStats_AllTime(t *testing.T) {
 		WithArgs(orgID).
 		WillReturnRows(pgxmock.NewRows([]string{"sum"}).AddRow(int64(500000)))
 
+	// Expect per-provider booking counts
+	mock.ExpectQuery(`SELECT provider_id, COUNT\(\*\) FROM bookings WHERE org_id = \$1 AND provider_id IS NOT NULL GROUP BY provider_id`).
+		WithArgs(orgID).
+		WillReturnRows(pgxmock.NewRows([]string{"provider_id", "count"}).AddRow("prov-a", int64(6)).AddRow("prov-b", int64(4)))
+
 	repo := NewStatsRepositoryWithDB(mock)
 	stats, err := repo.GetStats(context.Background(), orgID, nil, nil)
 	if err != nil {
@@ -63,6 +68,9 @@ func TestStatsRepository_GetStats_AllTime(t *testing.T) {
 	if stats.DepositAmountTotal != 500000 {
 		t.Errorf("DepositAmountTotal = %d, want 500000", stats.DepositAmountTotal)
 	}
+	if len(stats.ProviderBookings) != 2 || stats.ProviderBookings[0] != (ProviderBookingCount{ProviderID: "prov-a", Bookings: 6}) {
+		t.Errorf("ProviderBookings = %+v, want prov-a=6 and prov-b=4", stats.ProviderBookings)
+	}
 	if stats.PeriodStart != "all-time" {
 		t.Errorf("PeriodStart = %q, want 'all-time'", stats.PeriodStart)
 	}
@@ -84,7 +92,7 @@ func TestStatsRepository_GetStats_WithTimeRange(t *testing.T) {
 	end := time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)
 
 	// Expect leads count query with time filter
-	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM leads WHERE org_id = \$1 AND created_at >= \$2 AND created_at < \$3`).
+	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM leads WHERE org_id = \$1 AND NOT probable_wrong_number AND created_at >= \$2 AND created_at < \$3`).
 		WithArgs(orgID, start, end).
 		WillReturnRows(pgxmock.NewRows([]string{"count"}).AddRow(int64(20)))
 
@@ -103,6 +111,11 @@ func TestStatsRepository_GetStats_WithTimeRange(t *testing.T) {
 		WithArgs(orgID, start, end).
 		WillReturnRows(pgxmock.NewRows([]string{"sum"}).AddRow(int64(250000)))
 
+	// Expect per-provider booking counts with time filter
+	mock.ExpectQuery(`SELECT provider_id, COUNT\(\*\) FROM bookings WHERE org_id = \$1 AND provider_id IS NOT NULL AND created_at >= \$2 AND created_at < \$3 GROUP BY provider_id`).
+		WithArgs(orgID, start, end).
+		WillReturnRows(pgxmock.NewRows([]string{"provider_id", "count"}))
+
 	repo := NewStatsRepositoryWithDB(mock)
 	stats, err := repo.GetStats(context.Background(), orgID, &start, &end)
 	if err != nil {
@@ -149,6 +162,9 @@ func TestStatsHandler_GetStats(t *testing.T) {
 	mock.ExpectQuery(`SELECT COALESCE`).
 		WithArgs(orgID).
 		WillReturnRows(pgxmock.NewRows([]string{"sum"}).AddRow(int64(1250000)))
+	mock.ExpectQuery(`SELECT provider_id`).
+		WithArgs(orgID).
+		WillReturnRows(pgxmock.NewRows([]string{"provider_id", "count"}).AddRow("prov-a", int64(3)))
 
 	repo := NewStatsRepositoryWithDB(mock)
 	handler := NewStatsHandler(repo, logging.Default())
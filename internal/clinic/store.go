@@ -4,6 +4,8 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"sort"
+	"strings"
 
 	"github.com/redis/go-redis/v9"
 )
@@ -22,22 +24,50 @@ func (s *Store) key(orgID string) string {
 	return fmt.Sprintf("clinic:config:%s", orgID)
 }
 
+// OrgIDs returns every org with a stored config.
+func (s *Store) OrgIDs(ctx context.Context) ([]string, error) {
+	prefix := s.key("")
+	var ids []string
+	iter := s.redis.Scan(ctx, 0, prefix+"*", 100).Iterator()
+	for iter.Next(ctx) {
+		ids = append(ids, strings.TrimPrefix(iter.Val(), prefix))
+	}
+	if err := iter.Err(); err != nil {
+		return nil, fmt.Errorf("clinic: list configs: %w", err)
+	}
+	sort.Strings(ids)
+	return ids, nil
+}
+
 // Get retrieves clinic config, returning default if not found.
 func (s *Store) Get(ctx context.Context, orgID string) (*Config, error) {
+	cfg, found, err := s.Lookup(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return DefaultConfig(orgID), nil
+	}
+	return cfg, nil
+}
+
+// Lookup retrieves clinic config and reports whether one is stored. Unlike
+// Get, a missing config is not replaced by the default.
+func (s *Store) Lookup(ctx context.Context, orgID string) (*Config, bool, error) {
 	data, err := s.redis.Get(ctx, s.key(orgID)).Bytes()
 	if err == redis.Nil {
-		return DefaultConfig(orgID), nil
+		return nil, false, nil
 	}
 	if err != nil {
-		return nil, fmt.Errorf("clinic: get config: %w", err)
+		return nil, false, fmt.Errorf("clinic: get config: %w", err)
 	}
 
 	var cfg Config
 	if err := json.Unmarshal(data, &cfg); err != nil {
-		return nil, fmt.Errorf("clinic: unmarshal config: %w", err)
+		return nil, false, fmt.Errorf("clinic: unmarshal config: %w", err)
 	}
 
-	return &cfg, nil
+	return &cfg, true, nil
 }
 
 // GetStripeAccountID retrieves the Stripe account ID for a clinic.
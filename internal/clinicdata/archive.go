@@ -12,6 +12,9 @@ import (
 	"github.com/aws/aws-sdk-go-v2/service/s3"
 	"github.com/aws/aws-sdk-go-v2/service/s3/types"
 	"github.com/jackc/pgx/v5"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/conversation/msgschema"
+	"github.com/wolfman30/medspa-ai-platform/internal/pii"
 )
 
 // ArchiveOrg archives all conversations for an organization before purge.
@@ -259,18 +262,20 @@ func (a *Archiver) fetchConversationTx(ctx context.Context, tx pgx.Tx, orgID, or
 			SELECT 1 FROM payments p WHERE p.lead_id = l.id AND p.status = 'completed'
 		)
 		FROM leads l
-		WHERE l.org_id = $1 AND regexp_replace(l.phone, '\D', '', 'g') = $2
+		WHERE l.org_id = $1 AND (regexp_replace(l.phone, '\D', '', 'g') = $2 OR l.phone_hash = $3)
 		LIMIT 1
-	`, orgID, digits).Scan(&leadName, &depositCollected)
+	`, orgID, digits, pii.PhoneHash(digits)).Scan(&leadName, &depositCollected)
 	if err != nil && err != pgx.ErrNoRows {
 		a.logger.Warn("clinicdata: failed to fetch lead info", "error", err)
 	}
+	leadName = pii.Reveal(leadName)
 
 	knownNames := extractNames(leadName)
 
 	// Fetch messages with delivery status for training data
 	msgRows, err := tx.Query(ctx, `
-		SELECT role, content, created_at, COALESCE(status, ''), COALESCE(error_reason, '')
+		SELECT role, COALESCE(direction, ''), COALESCE(author_type, ''), COALESCE(kind, ''),
+			content, created_at, COALESCE(status, ''), COALESCE(error_reason, '')
 		FROM conversation_messages
 		WHERE conversation_id = $1
 		ORDER BY created_at ASC
@@ -286,17 +291,20 @@ func (a *Archiver) fetchConversationTx(ctx context.Context, tx pgx.Tx, orgID, or
 
 	for msgRows.Next() {
 		var msg ArchivedMessage
-		var rawContent string
-		if err := msgRows.Scan(&msg.Role, &rawContent, &msg.Timestamp, &msg.Status, &msg.ErrorReason); err != nil {
+		var rawContent, direction, kind string
+		if err := msgRows.Scan(&msg.Role, &direction, &msg.AuthorType, &kind, &rawContent, &msg.Timestamp, &msg.Status, &msg.ErrorReason); err != nil {
 			return nil, fmt.Errorf("fetchConversationTx: scan message: %w", err)
 		}
+		_, author := msgschema.Message{Direction: direction, AuthorType: msg.AuthorType, Role: msg.Role, Kind: kind}.Resolve()
+		msg.AuthorType = string(author)
 
 		msg.Content = redactPII(rawContent, knownNames)
 		messages = append(messages, msg)
 
-		if msg.Role == "user" {
+		switch {
+		case author == msgschema.Patient:
 			userCount++
-		} else if msg.Role == "assistant" {
+		case author != msgschema.Staff && msg.Role == "assistant":
 			aiCount++
 		}
 
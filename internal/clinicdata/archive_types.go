@@ -64,7 +64,8 @@ type ArchivedConversation struct {
 
 // ArchivedMessage represents a single message in the conversation.
 type ArchivedMessage struct {
-	Role        string    `json:"role"` // "user" or "assistant"
+	Role        string    `json:"role"`        // "user" or "assistant"
+	AuthorType  string    `json:"author_type"` // "patient", "ai", "staff" or "system"
 	Content     string    `json:"content"`
 	Timestamp   time.Time `json:"timestamp"`
 	Status      string    `json:"status,omitempty"`       // e.g., "delivered", "sent", "failed"
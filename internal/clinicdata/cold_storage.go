@@ -0,0 +1,329 @@
+package clinicdata
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/google/uuid"
+
+	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
+)
+
+// ColdFormatVersion is the version written into every cold-storage batch.
+// Bump it when the batch layout changes and keep decoding older versions.
+const ColdFormatVersion = 1
+
+const defaultColdCacheTTL = 5 * time.Minute
+
+// ErrColdConversationNotFound is returned when an archive batch no longer
+// holds the requested conversation (or the batch itself is gone).
+var ErrColdConversationNotFound = errors.New("clinicdata: conversation not found in cold storage")
+
+// ColdS3Client is the subset of S3 the cold store needs (allows fakes in tests).
+type ColdS3Client interface {
+	PutObject(ctx context.Context, params *s3.PutObjectInput, optFns ...func(*s3.Options)) (*s3.PutObjectOutput, error)
+	GetObject(ctx context.Context, params *s3.GetObjectInput, optFns ...func(*s3.Options)) (*s3.GetObjectOutput, error)
+	DeleteObject(ctx context.Context, params *s3.DeleteObjectInput, optFns ...func(*s3.Options)) (*s3.DeleteObjectOutput, error)
+}
+
+// ColdBatch is one gzipped JSON object in cold storage. A batch only holds
+// conversations from a single org so an org purge can drop whole objects.
+type ColdBatch struct {
+	FormatVersion int                `json:"format_version"`
+	OrgID         string             `json:"org_id"`
+	ExportedAt    time.Time          `json:"exported_at"`
+	Conversations []ColdConversation `json:"conversations"`
+}
+
+// ColdConversation is a conversation's metadata and messages as they were
+// stored in the hot tables. Unlike the training archive nothing is redacted:
+// rehydration must reproduce the transcript exactly.
+type ColdConversation struct {
+	ConversationID       string        `json:"conversation_id"`
+	OrgID                string        `json:"org_id"`
+	LeadID               string        `json:"lead_id,omitempty"`
+	Phone                string        `json:"phone"`
+	Channel              string        `json:"channel"`
+	Status               string        `json:"status"`
+	MessageCount         int           `json:"message_count"`
+	CustomerMessageCount int           `json:"customer_message_count"`
+	AIMessageCount       int           `json:"ai_message_count"`
+	StartedAt            time.Time     `json:"started_at"`
+	LastMessageAt        *time.Time    `json:"last_message_at,omitempty"`
+	Messages             []ColdMessage `json:"messages"`
+}
+
+// ColdMessage is one archived conversation_messages row.
+type ColdMessage struct {
+	ID                string    `json:"id"`
+	Role              string    `json:"role"`
+	Direction         string    `json:"direction,omitempty"`
+	AuthorType        string    `json:"author_type,omitempty"`
+	Content           string    `json:"content"`
+	FromPhone         string    `json:"from_phone,omitempty"`
+	ToPhone           string    `json:"to_phone,omitempty"`
+	ProviderMessageID string    `json:"provider_message_id,omitempty"`
+	Status            string    `json:"status,omitempty"`
+	ErrorReason       string    `json:"error_reason,omitempty"`
+	Kind              string    `json:"kind,omitempty"`
+	CreatedAt         time.Time `json:"created_at"`
+}
+
+// ColdStore writes conversation batches to S3 and reads them back on demand.
+// Rehydrated conversations are cached in memory for CacheTTL so a dashboard
+// reload doesn't hit S3 again.
+type ColdStore struct {
+	s3       ColdS3Client
+	bucket   string
+	kmsKeyID string
+	cacheTTL time.Duration
+	logger   *logging.Logger
+	now      func() time.Time
+
+	mu    sync.Mutex
+	cache map[string]coldCacheEntry // by conversation ID
+}
+
+// ColdStoreConfig holds configuration for the ColdStore.
+type ColdStoreConfig struct {
+	S3       ColdS3Client
+	Bucket   string
+	KMSKeyID string        // Optional: KMS key ID for server-side encryption (SSE-KMS)
+	CacheTTL time.Duration // How long rehydrated conversations stay cached (default: 5m)
+	Logger   *logging.Logger
+}
+
+type coldCacheEntry struct {
+	archiveKey string
+	conv       ColdConversation
+	expires    time.Time
+}
+
+// NewColdStore creates a ColdStore.
+func NewColdStore(cfg ColdStoreConfig) *ColdStore {
+	if cfg.Logger == nil {
+		cfg.Logger = logging.Default()
+	}
+	if cfg.CacheTTL <= 0 {
+		cfg.CacheTTL = defaultColdCacheTTL
+	}
+	return &ColdStore{
+		s3:       cfg.S3,
+		bucket:   cfg.Bucket,
+		kmsKeyID: cfg.KMSKeyID,
+		cacheTTL: cfg.CacheTTL,
+		logger:   cfg.Logger,
+		now:      time.Now,
+		cache:    make(map[string]coldCacheEntry),
+	}
+}
+
+// Put writes a new batch and returns its key.
+func (c *ColdStore) Put(ctx context.Context, batch ColdBatch) (string, error) {
+	if c == nil || c.s3 == nil || c.bucket == "" {
+		return "", fmt.Errorf("clinicdata: cold store not configured")
+	}
+	if batch.OrgID == "" {
+		return "", fmt.Errorf("clinicdata: cold batch missing orgID")
+	}
+	if batch.ExportedAt.IsZero() {
+		batch.ExportedAt = c.now().UTC()
+	}
+	key := fmt.Sprintf("conversations/cold/v%d/%s/%s/%s_%s.json.gz",
+		ColdFormatVersion, batch.OrgID, batch.ExportedAt.Format("2006/01/02"),
+		batch.ExportedAt.Format("20060102T150405Z"), uuid.NewString()[:8])
+	if err := c.write(ctx, key, batch); err != nil {
+		return "", err
+	}
+	return key, nil
+}
+
+// Load reads and decodes a batch.
+func (c *ColdStore) Load(ctx context.Context, key string) (*ColdBatch, error) {
+	if c == nil || c.s3 == nil || c.bucket == "" {
+		return nil, fmt.Errorf("clinicdata: cold store not configured")
+	}
+	out, err := c.s3.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(c.bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		var noKey *types.NoSuchKey
+		if errors.As(err, &noKey) {
+			return nil, ErrColdConversationNotFound
+		}
+		return nil, fmt.Errorf("clinicdata: cold storage get %s: %w", key, err)
+	}
+	defer out.Body.Close()
+	return decodeColdBatch(out.Body)
+}
+
+// Rehydrate returns an archived conversation. cached reports whether it was
+// served from memory rather than S3.
+func (c *ColdStore) Rehydrate(ctx context.Context, archiveKey, conversationID string) (conv *ColdConversation, cached bool, err error) {
+	if c == nil {
+		return nil, false, fmt.Errorf("clinicdata: cold store not configured")
+	}
+	now := c.now()
+	c.mu.Lock()
+	entry, ok := c.cache[conversationID]
+	c.mu.Unlock()
+	if ok && entry.archiveKey == archiveKey && now.Before(entry.expires) {
+		found := entry.conv
+		return &found, true, nil
+	}
+
+	batch, err := c.Load(ctx, archiveKey)
+	if err != nil {
+		return nil, false, err
+	}
+	for _, candidate := range batch.Conversations {
+		if candidate.ConversationID != conversationID {
+			continue
+		}
+		c.mu.Lock()
+		c.evictExpired(now)
+		c.cache[conversationID] = coldCacheEntry{archiveKey: archiveKey, conv: candidate, expires: now.Add(c.cacheTTL)}
+		c.mu.Unlock()
+		return &candidate, false, nil
+	}
+	return nil, false, ErrColdConversationNotFound
+}
+
+// DeleteConversations removes conversations from a batch, rewriting it in
+// place, or deleting the object once nothing is left. It returns how many
+// conversations were removed.
+func (c *ColdStore) DeleteConversations(ctx context.Context, archiveKey string, conversationIDs []string) (int, error) {
+	c.forget(conversationIDs...)
+	batch, err := c.Load(ctx, archiveKey)
+	if errors.Is(err, ErrColdConversationNotFound) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	drop := make(map[string]bool, len(conversationIDs))
+	for _, id := range conversationIDs {
+		drop[id] = true
+	}
+	kept := batch.Conversations[:0]
+	for _, conv := range batch.Conversations {
+		if !drop[conv.ConversationID] {
+			kept = append(kept, conv)
+		}
+	}
+	removed := len(batch.Conversations) - len(kept)
+	if removed == 0 {
+		return 0, nil
+	}
+	if len(kept) == 0 {
+		return removed, c.DeleteObject(ctx, archiveKey)
+	}
+	batch.Conversations = kept
+	if err := c.write(ctx, archiveKey, *batch); err != nil {
+		return 0, err
+	}
+	return removed, nil
+}
+
+// DeleteObject removes a whole batch.
+func (c *ColdStore) DeleteObject(ctx context.Context, archiveKey string) error {
+	if c == nil || c.s3 == nil || c.bucket == "" {
+		return fmt.Errorf("clinicdata: cold store not configured")
+	}
+	c.mu.Lock()
+	for id, entry := range c.cache {
+		if entry.archiveKey == archiveKey {
+			delete(c.cache, id)
+		}
+	}
+	c.mu.Unlock()
+	if _, err := c.s3.DeleteObject(ctx, &s3.DeleteObjectInput{
+		Bucket: aws.String(c.bucket),
+		Key:    aws.String(archiveKey),
+	}); err != nil {
+		return fmt.Errorf("clinicdata: cold storage delete %s: %w", archiveKey, err)
+	}
+	return nil
+}
+
+func (c *ColdStore) write(ctx context.Context, key string, batch ColdBatch) error {
+	batch.FormatVersion = ColdFormatVersion
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if err := json.NewEncoder(zw).Encode(batch); err != nil {
+		return fmt.Errorf("clinicdata: encode cold batch: %w", err)
+	}
+	if err := zw.Close(); err != nil {
+		return fmt.Errorf("clinicdata: compress cold batch: %w", err)
+	}
+
+	putInput := &s3.PutObjectInput{
+		Bucket:          aws.String(c.bucket),
+		Key:             aws.String(key),
+		Body:            bytes.NewReader(buf.Bytes()),
+		ContentType:     aws.String("application/json"),
+		ContentEncoding: aws.String("gzip"),
+		Metadata: map[string]string{
+			"format_version":     fmt.Sprintf("%d", ColdFormatVersion),
+			"org_id":             batch.OrgID,
+			"conversation_count": fmt.Sprintf("%d", len(batch.Conversations)),
+		},
+	}
+	if c.kmsKeyID != "" {
+		putInput.ServerSideEncryption = types.ServerSideEncryptionAwsKms
+		putInput.SSEKMSKeyId = aws.String(c.kmsKeyID)
+	}
+	if _, err := c.s3.PutObject(ctx, putInput); err != nil {
+		return fmt.Errorf("clinicdata: cold storage put %s: %w", key, err)
+	}
+	return nil
+}
+
+func (c *ColdStore) forget(conversationIDs ...string) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	for _, id := range conversationIDs {
+		delete(c.cache, id)
+	}
+	c.mu.Unlock()
+}
+
+// evictExpired drops stale cache entries. Callers hold c.mu.
+func (c *ColdStore) evictExpired(now time.Time) {
+	for id, entry := range c.cache {
+		if !now.Before(entry.expires) {
+			delete(c.cache, id)
+		}
+	}
+}
+
+// decodeColdBatch reads a gzipped batch, rejecting versions this build
+// doesn't know how to read.
+func decodeColdBatch(r io.Reader) (*ColdBatch, error) {
+	zr, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("clinicdata: decompress cold batch: %w", err)
+	}
+	defer zr.Close()
+	var batch ColdBatch
+	if err := json.NewDecoder(zr).Decode(&batch); err != nil {
+		return nil, fmt.Errorf("clinicdata: decode cold batch: %w", err)
+	}
+	if batch.FormatVersion < 1 || batch.FormatVersion > ColdFormatVersion {
+		return nil, fmt.Errorf("clinicdata: unsupported cold batch format version %d", batch.FormatVersion)
+	}
+	return &batch, nil
+}
@@ -0,0 +1,325 @@
+package clinicdata
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
+)
+
+const (
+	defaultColdStorageMaxAge    = 90 * 24 * time.Hour
+	defaultColdStorageBatchSize = 100
+	defaultColdStorageInterval  = 24 * time.Hour
+)
+
+// ColdStorageJobConfig configures the scheduled cold-storage export.
+type ColdStorageJobConfig struct {
+	DB        db
+	Store     *ColdStore
+	MaxAge    time.Duration // conversations idle longer than this are archived (default: 90 days)
+	BatchSize int           // conversations per S3 object (default: 100)
+	Interval  time.Duration
+	Logger    *logging.Logger
+}
+
+// ColdStorageJob moves the messages of idle conversations to S3. The
+// conversation row stays behind as a stub (archive_key, archived_at) so
+// lists, stats and purges keep working and reads can rehydrate on demand.
+// Conversations already archived are not revisited; messages that arrive
+// after archiving stay hot and are merged with the archive on read.
+type ColdStorageJob struct {
+	db        db
+	store     *ColdStore
+	maxAge    time.Duration
+	batchSize int
+	interval  time.Duration
+	logger    *logging.Logger
+	now       func() time.Time
+}
+
+// ColdStorageRunResult summarizes one pass of the cold-storage job.
+type ColdStorageRunResult struct {
+	Orgs          int
+	Batches       int
+	Conversations int
+	Messages      int
+	Failed        int // batches that could not be exported or stubbed
+}
+
+// NewColdStorageJob creates a cold-storage job.
+func NewColdStorageJob(cfg ColdStorageJobConfig) *ColdStorageJob {
+	if cfg.Logger == nil {
+		cfg.Logger = logging.Default()
+	}
+	if cfg.MaxAge <= 0 {
+		cfg.MaxAge = defaultColdStorageMaxAge
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = defaultColdStorageBatchSize
+	}
+	if cfg.Interval <= 0 {
+		cfg.Interval = defaultColdStorageInterval
+	}
+	return &ColdStorageJob{
+		db:        cfg.DB,
+		store:     cfg.Store,
+		maxAge:    cfg.MaxAge,
+		batchSize: cfg.BatchSize,
+		interval:  cfg.Interval,
+		logger:    cfg.Logger,
+		now:       time.Now,
+	}
+}
+
+// Start runs the cold-storage job on its interval. Blocks until ctx is cancelled.
+func (j *ColdStorageJob) Start(ctx context.Context) {
+	if j == nil || j.db == nil || j.store == nil {
+		return
+	}
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+
+	j.run(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			j.run(ctx)
+		}
+	}
+}
+
+func (j *ColdStorageJob) run(ctx context.Context) {
+	res, err := j.RunOnce(ctx)
+	if err != nil {
+		j.logger.Error("cold storage export failed", "error", err)
+		return
+	}
+	j.logger.Info("cold storage export completed",
+		"orgs", res.Orgs,
+		"batches", res.Batches,
+		"conversations", res.Conversations,
+		"messages", res.Messages,
+		"failed", res.Failed,
+	)
+}
+
+// RunOnce archives every conversation idle since before the cutoff.
+func (j *ColdStorageJob) RunOnce(ctx context.Context) (ColdStorageRunResult, error) {
+	var res ColdStorageRunResult
+	cutoff := j.now().UTC().Add(-j.maxAge)
+	orgIDs, err := j.coldStorageOrgs(ctx, cutoff)
+	if err != nil {
+		return res, err
+	}
+	for _, orgID := range orgIDs {
+		if ctx.Err() != nil {
+			return res, ctx.Err()
+		}
+		res.Orgs++
+		if err := j.archiveOrg(ctx, orgID, cutoff, &res); err != nil {
+			return res, err
+		}
+	}
+	return res, nil
+}
+
+// archiveOrg walks the org's idle conversations in id order, one S3 object
+// per batch.
+func (j *ColdStorageJob) archiveOrg(ctx context.Context, orgID string, cutoff time.Time, res *ColdStorageRunResult) error {
+	afterID := ""
+	for {
+		convs, err := j.coldStorageCandidates(ctx, orgID, cutoff, afterID)
+		if err != nil {
+			return err
+		}
+		if len(convs) == 0 {
+			return nil
+		}
+		afterID = convs[len(convs)-1].ConversationID
+
+		messages, err := j.exportBatch(ctx, orgID, convs)
+		if err != nil {
+			j.logger.Warn("cold storage: batch export failed", "error", err, "org_id", orgID, "after_conversation_id", afterID)
+			res.Failed++
+		} else {
+			res.Batches++
+			res.Conversations += len(convs)
+			res.Messages += messages
+		}
+		if len(convs) < j.batchSize {
+			return nil
+		}
+	}
+}
+
+// exportBatch uploads the batch, then deletes exactly the exported messages
+// and stubs the conversations in one transaction. Messages written after the
+// export was read stay hot. If stubbing fails the uploaded object is removed.
+func (j *ColdStorageJob) exportBatch(ctx context.Context, orgID string, convs []ColdConversation) (int, error) {
+	ids := make([]string, len(convs))
+	for i, conv := range convs {
+		ids[i] = conv.ConversationID
+	}
+	if err := j.loadMessages(ctx, convs, ids); err != nil {
+		return 0, err
+	}
+	var messageIDs []string
+	for _, conv := range convs {
+		for _, msg := range conv.Messages {
+			messageIDs = append(messageIDs, msg.ID)
+		}
+	}
+
+	now := j.now().UTC()
+	key, err := j.store.Put(ctx, ColdBatch{OrgID: orgID, ExportedAt: now, Conversations: convs})
+	if err != nil {
+		return 0, err
+	}
+	if err := j.stubConversations(ctx, ids, messageIDs, key, now); err != nil {
+		if delErr := j.store.DeleteObject(ctx, key); delErr != nil {
+			j.logger.Error("cold storage: failed to remove orphaned batch", "error", delErr, "org_id", orgID, "archive_key", key)
+		}
+		return 0, err
+	}
+	j.logger.Info("cold storage: batch archived",
+		"org_id", orgID,
+		"archive_key", key,
+		"conversations", len(convs),
+		"messages", len(messageIDs),
+	)
+	return len(messageIDs), nil
+}
+
+func (j *ColdStorageJob) stubConversations(ctx context.Context, conversationIDs, messageIDs []string, key string, archivedAt time.Time) error {
+	tx, err := j.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("clinicdata: begin tx: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if len(messageIDs) > 0 {
+		if _, err := tx.Exec(ctx, `
+			DELETE FROM conversation_messages WHERE id = ANY($1::uuid[])
+		`, messageIDs); err != nil {
+			return fmt.Errorf("clinicdata: delete archived messages: %w", err)
+		}
+	}
+	if _, err := tx.Exec(ctx, `
+		UPDATE conversations
+		SET archive_key = $2, archived_at = $3, updated_at = NOW()
+		WHERE conversation_id = ANY($1) AND archive_key IS NULL
+	`, conversationIDs, key, archivedAt); err != nil {
+		return fmt.Errorf("clinicdata: stub archived conversations: %w", err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("clinicdata: commit cold storage batch: %w", err)
+	}
+	return nil
+}
+
+func (j *ColdStorageJob) coldStorageOrgs(ctx context.Context, cutoff time.Time) ([]string, error) {
+	tx, err := j.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("clinicdata: begin tx: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	rows, err := tx.Query(ctx, `
+		SELECT DISTINCT org_id FROM conversations
+		WHERE archive_key IS NULL AND COALESCE(last_message_at, started_at) < $1
+		ORDER BY org_id
+	`, cutoff)
+	if err != nil {
+		return nil, fmt.Errorf("clinicdata: list cold storage orgs: %w", err)
+	}
+	defer rows.Close()
+	var orgIDs []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("clinicdata: scan cold storage org: %w", err)
+		}
+		orgIDs = append(orgIDs, id)
+	}
+	return orgIDs, rows.Err()
+}
+
+// coldStorageCandidates returns the next batch of the org's hot
+// conversations with no activity since the cutoff.
+func (j *ColdStorageJob) coldStorageCandidates(ctx context.Context, orgID string, cutoff time.Time, afterID string) ([]ColdConversation, error) {
+	tx, err := j.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("clinicdata: begin tx: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	rows, err := tx.Query(ctx, `
+		SELECT conversation_id, org_id, COALESCE(lead_id::text, ''), phone, channel, status,
+			message_count, customer_message_count, ai_message_count, started_at, last_message_at
+		FROM conversations
+		WHERE org_id = $1
+		  AND archive_key IS NULL
+		  AND COALESCE(last_message_at, started_at) < $2
+		  AND conversation_id > $3
+		ORDER BY conversation_id
+		LIMIT $4
+	`, orgID, cutoff, afterID, j.batchSize)
+	if err != nil {
+		return nil, fmt.Errorf("clinicdata: list cold storage candidates: %w", err)
+	}
+	defer rows.Close()
+	var out []ColdConversation
+	for rows.Next() {
+		var c ColdConversation
+		if err := rows.Scan(&c.ConversationID, &c.OrgID, &c.LeadID, &c.Phone, &c.Channel, &c.Status,
+			&c.MessageCount, &c.CustomerMessageCount, &c.AIMessageCount, &c.StartedAt, &c.LastMessageAt); err != nil {
+			return nil, fmt.Errorf("clinicdata: scan cold storage candidate: %w", err)
+		}
+		c.Messages = []ColdMessage{}
+		out = append(out, c)
+	}
+	return out, rows.Err()
+}
+
+// loadMessages fills in each conversation's messages in send order.
+func (j *ColdStorageJob) loadMessages(ctx context.Context, convs []ColdConversation, conversationIDs []string) error {
+	tx, err := j.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("clinicdata: begin tx: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	rows, err := tx.Query(ctx, `
+		SELECT id::text, conversation_id, role, COALESCE(direction, ''), COALESCE(author_type, ''), content,
+			COALESCE(from_phone, ''), COALESCE(to_phone, ''), COALESCE(provider_message_id, ''),
+			COALESCE(status, ''), COALESCE(error_reason, ''), COALESCE(kind, ''), created_at
+		FROM conversation_messages
+		WHERE conversation_id = ANY($1)
+		ORDER BY conversation_id, created_at
+	`, conversationIDs)
+	if err != nil {
+		return fmt.Errorf("clinicdata: load messages to archive: %w", err)
+	}
+	defer rows.Close()
+
+	byID := make(map[string]*ColdConversation, len(convs))
+	for i := range convs {
+		byID[convs[i].ConversationID] = &convs[i]
+	}
+	for rows.Next() {
+		var convID string
+		var m ColdMessage
+		if err := rows.Scan(&m.ID, &convID, &m.Role, &m.Direction, &m.AuthorType, &m.Content, &m.FromPhone, &m.ToPhone,
+			&m.ProviderMessageID, &m.Status, &m.ErrorReason, &m.Kind, &m.CreatedAt); err != nil {
+			return fmt.Errorf("clinicdata: scan message to archive: %w", err)
+		}
+		if conv := byID[convID]; conv != nil {
+			conv.Messages = append(conv.Messages, m)
+		}
+	}
+	return rows.Err()
+}
@@ -0,0 +1,348 @@
+package clinicdata
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/google/uuid"
+	pgxmock "github.com/pashagolub/pgxmock/v4"
+
+	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
+)
+
+// fakeColdS3 is an in-memory bucket.
+type fakeColdS3 struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+	gets    int
+}
+
+func newFakeColdS3() *fakeColdS3 {
+	return &fakeColdS3{objects: make(map[string][]byte)}
+}
+
+func (f *fakeColdS3) PutObject(_ context.Context, in *s3.PutObjectInput, _ ...func(*s3.Options)) (*s3.PutObjectOutput, error) {
+	body, err := io.ReadAll(in.Body)
+	if err != nil {
+		return nil, err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.objects[*in.Key] = body
+	return &s3.PutObjectOutput{}, nil
+}
+
+func (f *fakeColdS3) GetObject(_ context.Context, in *s3.GetObjectInput, _ ...func(*s3.Options)) (*s3.GetObjectOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.gets++
+	body, ok := f.objects[*in.Key]
+	if !ok {
+		return nil, &types.NoSuchKey{}
+	}
+	return &s3.GetObjectOutput{Body: io.NopCloser(bytes.NewReader(body))}, nil
+}
+
+func (f *fakeColdS3) DeleteObject(_ context.Context, in *s3.DeleteObjectInput, _ ...func(*s3.Options)) (*s3.DeleteObjectOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.objects, *in.Key)
+	return &s3.DeleteObjectOutput{}, nil
+}
+
+func (f *fakeColdS3) keys() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var keys []string
+	for key := range f.objects {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+func newTestColdStore(fake *fakeColdS3) *ColdStore {
+	return NewColdStore(ColdStoreConfig{S3: fake, Bucket: "cold", Logger: logging.Default()})
+}
+
+func TestColdStorageJob_ExportStubRehydrateRoundTrip(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("pgxmock: %v", err)
+	}
+	defer mock.Close()
+
+	orgID := uuid.New().String()
+	convID := "sms:" + orgID + ":15551234567"
+	now := time.Date(2026, 9, 1, 12, 0, 0, 0, time.UTC)
+	cutoff := now.Add(-90 * 24 * time.Hour)
+	started := time.Date(2026, 3, 2, 14, 0, 0, 0, time.UTC)
+	last := started.Add(3 * time.Minute)
+	msgIDs := []string{uuid.NewString(), uuid.NewString()}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT DISTINCT org_id FROM conversations").WithArgs(cutoff).
+		WillReturnRows(pgxmock.NewRows([]string{"org_id"}).AddRow(orgID))
+	mock.ExpectRollback()
+	mock.ExpectBegin()
+	mock.ExpectQuery("FROM conversations").WithArgs(orgID, cutoff, "", 100).
+		WillReturnRows(pgxmock.NewRows([]string{"conversation_id", "org_id", "lead_id", "phone", "channel", "status",
+			"message_count", "customer_message_count", "ai_message_count", "started_at", "last_message_at"}).
+			AddRow(convID, orgID, "", "+15551234567", "sms", "closed", 2, 1, 1, started, &last))
+	mock.ExpectRollback()
+	mock.ExpectBegin()
+	mock.ExpectQuery("FROM conversation_messages").WithArgs([]string{convID}).
+		WillReturnRows(pgxmock.NewRows([]string{"id", "conversation_id", "role", "direction", "author_type", "content", "from_phone", "to_phone",
+			"provider_message_id", "status", "error_reason", "kind", "created_at"}).
+			AddRow(msgIDs[0], convID, "user", "inbound", "patient", "Do you have Botox openings?", "+15551234567", "+15550000000", "pm-1", "received", "", "", started).
+			AddRow(msgIDs[1], convID, "assistant", "outbound", "ai", "We do! What day works?", "+15550000000", "+15551234567", "pm-2", "delivered", "", "reply", last))
+	mock.ExpectRollback()
+	mock.ExpectBegin()
+	mock.ExpectExec("DELETE FROM conversation_messages").WithArgs(msgIDs).WillReturnResult(pgxmock.NewResult("DELETE", 2))
+	mock.ExpectExec("UPDATE conversations").WithArgs([]string{convID}, pgxmock.AnyArg(), now).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	mock.ExpectCommit()
+
+	fake := newFakeColdS3()
+	store := newTestColdStore(fake)
+	job := NewColdStorageJob(ColdStorageJobConfig{DB: mock, Store: store, Logger: logging.Default()})
+	job.now = func() time.Time { return now }
+
+	res, err := job.RunOnce(context.Background())
+	if err != nil {
+		t.Fatalf("RunOnce: %v", err)
+	}
+	if res != (ColdStorageRunResult{Orgs: 1, Batches: 1, Conversations: 1, Messages: 2}) {
+		t.Fatalf("unexpected result: %+v", res)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+
+	keys := fake.keys()
+	if len(keys) != 1 || !strings.HasPrefix(keys[0], "conversations/cold/v1/"+orgID+"/2026/09/01/") || !strings.HasSuffix(keys[0], ".json.gz") {
+		t.Fatalf("unexpected objects: %v", keys)
+	}
+	zr, err := gzip.NewReader(bytes.NewReader(fake.objects[keys[0]]))
+	if err != nil {
+		t.Fatalf("object is not gzipped: %v", err)
+	}
+	var raw map[string]any
+	if err := json.NewDecoder(zr).Decode(&raw); err != nil {
+		t.Fatalf("decode object: %v", err)
+	}
+	if raw["format_version"] != float64(ColdFormatVersion) {
+		t.Fatalf("format_version = %v", raw["format_version"])
+	}
+
+	conv, cached, err := store.Rehydrate(context.Background(), keys[0], convID)
+	if err != nil {
+		t.Fatalf("Rehydrate: %v", err)
+	}
+	if cached || conv.Status != "closed" || conv.MessageCount != 2 || !conv.StartedAt.Equal(started) {
+		t.Fatalf("unexpected conversation: cached=%v %+v", cached, conv)
+	}
+	want := []ColdMessage{
+		{ID: msgIDs[0], Role: "user", Direction: "inbound", AuthorType: "patient", Content: "Do you have Botox openings?", FromPhone: "+15551234567", ToPhone: "+15550000000", ProviderMessageID: "pm-1", Status: "received", CreatedAt: started},
+		{ID: msgIDs[1], Role: "assistant", Direction: "outbound", AuthorType: "ai", Content: "We do! What day works?", FromPhone: "+15550000000", ToPhone: "+15551234567", ProviderMessageID: "pm-2", Status: "delivered", Kind: "reply", CreatedAt: last},
+	}
+	if !reflect.DeepEqual(conv.Messages, want) {
+		t.Fatalf("messages = %+v", conv.Messages)
+	}
+
+	gets := fake.gets
+	if _, cached, err := store.Rehydrate(context.Background(), keys[0], convID); err != nil || !cached || fake.gets != gets {
+		t.Fatalf("second rehydrate should be cached: cached=%v err=%v gets=%d", cached, err, fake.gets-gets)
+	}
+	store.now = func() time.Time { return time.Now().Add(defaultColdCacheTTL + time.Second) }
+	if _, cached, err := store.Rehydrate(context.Background(), keys[0], convID); err != nil || cached {
+		t.Fatalf("expired entry should reload: cached=%v err=%v", cached, err)
+	}
+}
+
+func TestColdStorageJob_RemovesBatchWhenStubbingFails(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("pgxmock: %v", err)
+	}
+	defer mock.Close()
+
+	orgID := uuid.New().String()
+	convID := "sms:" + orgID + ":15551234567"
+	started := time.Now().Add(-200 * 24 * time.Hour)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT DISTINCT org_id").WithArgs(pgxmock.AnyArg()).WillReturnRows(pgxmock.NewRows([]string{"org_id"}).AddRow(orgID))
+	mock.ExpectRollback()
+	mock.ExpectBegin()
+	mock.ExpectQuery("FROM conversations").WithArgs(orgID, pgxmock.AnyArg(), "", 100).
+		WillReturnRows(pgxmock.NewRows([]string{"conversation_id", "org_id", "lead_id", "phone", "channel", "status",
+			"message_count", "customer_message_count", "ai_message_count", "started_at", "last_message_at"}).
+			AddRow(convID, orgID, "", "+15551234567", "sms", "active", 1, 1, 0, started, nil))
+	mock.ExpectRollback()
+	mock.ExpectBegin()
+	mock.ExpectQuery("FROM conversation_messages").WithArgs([]string{convID}).
+		WillReturnRows(pgxmock.NewRows([]string{"id", "conversation_id", "role", "direction", "author_type", "content", "from_phone", "to_phone",
+			"provider_message_id", "status", "error_reason", "kind", "created_at"}).
+			AddRow(uuid.NewString(), convID, "user", "", "", "hi", "", "", "", "", "", "", started))
+	mock.ExpectRollback()
+	mock.ExpectBegin()
+	mock.ExpectExec("DELETE FROM conversation_messages").WithArgs(pgxmock.AnyArg()).WillReturnError(errors.New("connection reset"))
+	mock.ExpectRollback()
+
+	fake := newFakeColdS3()
+	job := NewColdStorageJob(ColdStorageJobConfig{DB: mock, Store: newTestColdStore(fake), Logger: logging.Default()})
+	res, err := job.RunOnce(context.Background())
+	if err != nil {
+		t.Fatalf("RunOnce: %v", err)
+	}
+	if res.Failed != 1 || res.Batches != 0 {
+		t.Fatalf("unexpected result: %+v", res)
+	}
+	if keys := fake.keys(); len(keys) != 0 {
+		t.Fatalf("orphaned batch left behind: %v", keys)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestColdStore_RejectsUnknownFormatVersion(t *testing.T) {
+	fake := newFakeColdS3()
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	json.NewEncoder(zw).Encode(map[string]any{"format_version": ColdFormatVersion + 1, "org_id": "org"})
+	zw.Close()
+	fake.objects["future.json.gz"] = buf.Bytes()
+
+	_, err := newTestColdStore(fake).Load(context.Background(), "future.json.gz")
+	if err == nil || !strings.Contains(err.Error(), "unsupported cold batch format version") {
+		t.Fatalf("expected version error, got %v", err)
+	}
+}
+
+// expectColdLeadPurge queues PurgeLead for a lead with one archived
+// conversation and no other rows of note.
+func expectColdLeadPurge(mock pgxmock.PgxPoolIface, orgID string, leadUUID uuid.UUID, archiveKey, convID string, commit bool) {
+	mock.ExpectBegin()
+	mock.ExpectQuery("FROM leads l").WithArgs(orgID, leadUUID).
+		WillReturnRows(pgxmock.NewRows([]string{"name", "phone", "has_financials"}).AddRow("Jane Doe", "", false))
+	mock.ExpectQuery("SELECT conversation_id").WithArgs(orgID, leadUUID, "").
+		WillReturnRows(pgxmock.NewRows([]string{"conversation_id"}).AddRow(convID))
+	mock.ExpectQuery("SELECT archive_key, conversation_id").WithArgs([]string{convID}).
+		WillReturnRows(pgxmock.NewRows([]string{"archive_key", "conversation_id"}).AddRow(archiveKey, convID))
+	for _, table := range []string{"conversation_jobs", "conversation_messages", "conversations", "messages",
+		"compliance_audit_events", "callback_tasks", "callback_promises", "escalations", "rebook_reminders", "leads"} {
+		mock.ExpectExec("DELETE FROM " + table).WithArgs(anyArgs(table)...).WillReturnResult(pgxmock.NewResult("DELETE", 0))
+	}
+	if commit {
+		mock.ExpectCommit()
+	} else {
+		mock.ExpectRollback()
+	}
+}
+
+func anyArgs(table string) []any {
+	n := map[string]int{"conversation_jobs": 1, "conversation_messages": 1, "conversations": 1, "messages": 2, "leads": 2}[table]
+	if n == 0 {
+		n = 3
+	}
+	args := make([]any, n)
+	for i := range args {
+		args[i] = pgxmock.AnyArg()
+	}
+	return args
+}
+
+func TestPurgeLead_DeletesColdStorageArchives(t *testing.T) {
+	ctx := context.Background()
+	orgID := uuid.New().String()
+	leadConv := "web:" + orgID + ":lead"
+	otherConv := "web:" + orgID + ":other"
+
+	for name, tc := range map[string]struct {
+		batch      []string
+		dryRun     bool
+		wantObject bool
+		wantKept   []string
+	}{
+		"shared batch is rewritten": {batch: []string{leadConv, otherConv}, wantObject: true, wantKept: []string{otherConv}},
+		"emptied batch is deleted":  {batch: []string{leadConv}},
+		"dry run keeps archive":     {batch: []string{leadConv, otherConv}, dryRun: true, wantObject: true, wantKept: []string{leadConv, otherConv}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			mock, err := pgxmock.NewPool()
+			if err != nil {
+				t.Fatalf("pgxmock: %v", err)
+			}
+			defer mock.Close()
+
+			fake := newFakeColdS3()
+			store := newTestColdStore(fake)
+			batch := ColdBatch{OrgID: orgID}
+			for _, id := range tc.batch {
+				batch.Conversations = append(batch.Conversations, ColdConversation{ConversationID: id, OrgID: orgID,
+					Messages: []ColdMessage{{ID: uuid.NewString(), Role: "user", Content: "hello"}}})
+			}
+			key, err := store.Put(ctx, batch)
+			if err != nil {
+				t.Fatalf("Put: %v", err)
+			}
+			// Warm the cache so the purge has to evict it.
+			if _, _, err := store.Rehydrate(ctx, key, leadConv); err != nil {
+				t.Fatalf("Rehydrate: %v", err)
+			}
+
+			leadUUID := uuid.New()
+			expectColdLeadPurge(mock, orgID, leadUUID, key, leadConv, !tc.dryRun)
+			purger := NewPurgerWithConfig(PurgerConfig{DB: mock, Logger: logging.Default(), ColdStore: store})
+			res, err := purger.PurgeLead(ctx, orgID, leadUUID.String(), tc.dryRun)
+			if err != nil {
+				t.Fatalf("PurgeLead: %v", err)
+			}
+			if res.Purged.ArchivedConversations != 1 {
+				t.Fatalf("archived conversations = %d", res.Purged.ArchivedConversations)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Fatalf("expectations: %v", err)
+			}
+
+			_, stillThere := fake.objects[key]
+			if stillThere != tc.wantObject {
+				t.Fatalf("object present = %v, want %v", stillThere, tc.wantObject)
+			}
+			if !tc.wantObject {
+				return
+			}
+			loaded, err := store.Load(ctx, key)
+			if err != nil {
+				t.Fatalf("Load: %v", err)
+			}
+			var kept []string
+			for _, conv := range loaded.Conversations {
+				kept = append(kept, conv.ConversationID)
+			}
+			if !reflect.DeepEqual(kept, tc.wantKept) {
+				t.Fatalf("kept = %v, want %v", kept, tc.wantKept)
+			}
+			_, cached, err := store.Rehydrate(ctx, key, leadConv)
+			if tc.dryRun {
+				if err != nil || !cached {
+					t.Fatalf("dry run should leave the cache alone: cached=%v err=%v", cached, err)
+				}
+			} else if !errors.Is(err, ErrColdConversationNotFound) {
+				t.Fatalf("purged conversation still rehydrates: %v", err)
+			}
+		})
+	}
+}
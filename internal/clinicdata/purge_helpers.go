@@ -2,11 +2,14 @@ package clinicdata
 
 import (
 	"context"
+	"fmt"
+	"sort"
 	"strings"
 
 	"github.com/jackc/pgx/v5"
 
 	"github.com/wolfman30/medspa-ai-platform/internal/archive"
+	"github.com/wolfman30/medspa-ai-platform/internal/pii"
 )
 
 // execRowsAffected executes a SQL statement within a transaction and returns
@@ -19,6 +22,58 @@ func execRowsAffected(ctx context.Context, tx pgx.Tx, query string, args ...any)
 	return tag.RowsAffected(), nil
 }
 
+// coldArchives lists the cold-storage batches holding the conversations a
+// purge matches, as archive key to conversation IDs. query must select
+// archive_key and conversation_id.
+func coldArchives(ctx context.Context, tx pgx.Tx, query string, args ...any) (map[string][]string, error) {
+	rows, err := tx.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("clinicdata: list archived conversations: %w", err)
+	}
+	defer rows.Close()
+	archives := make(map[string][]string)
+	for rows.Next() {
+		var key, convID string
+		if err := rows.Scan(&key, &convID); err != nil {
+			return nil, fmt.Errorf("clinicdata: scan archived conversation: %w", err)
+		}
+		archives[key] = append(archives[key], convID)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("clinicdata: list archived conversations: %w", err)
+	}
+	return archives, nil
+}
+
+// countColdConversations totals the conversations across archives.
+func countColdConversations(archives map[string][]string) int64 {
+	var n int64
+	for _, ids := range archives {
+		n += int64(len(ids))
+	}
+	return n
+}
+
+// deleteColdArchives removes conversations from their cold-storage batches.
+// Purges call it before committing so a failure leaves the stubs in place
+// and the purge can be retried.
+func (p *Purger) deleteColdArchives(ctx context.Context, archives map[string][]string) (int64, error) {
+	keys := make([]string, 0, len(archives))
+	for key := range archives {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	var deleted int64
+	for _, key := range keys {
+		n, err := p.coldStore.DeleteConversations(ctx, key, archives[key])
+		if err != nil {
+			return deleted, fmt.Errorf("clinicdata: delete cold storage archive: %w", err)
+		}
+		deleted += int64(n)
+	}
+	return deleted, nil
+}
+
 // sanitizeDigits strips all non-digit characters from a string.
 func sanitizeDigits(value string) string {
 	value = strings.TrimSpace(value)
@@ -122,8 +177,8 @@ func (p *Purger) runTrainingArchive(ctx context.Context, orgID, phone, conversat
 	err = tx.QueryRow(ctx, `
 		SELECT EXISTS(SELECT 1 FROM payments pa
 			JOIN leads l ON l.id = pa.lead_id
-			WHERE l.org_id = $1 AND regexp_replace(l.phone, '\D', '', 'g') = $2 AND pa.status = 'completed')
-	`, orgID, strings.TrimPrefix(phone, "+")).Scan(&depositPaid)
+			WHERE l.org_id = $1 AND (regexp_replace(l.phone, '\D', '', 'g') = $2 OR l.phone_hash = $3) AND pa.status = 'completed')
+	`, orgID, strings.TrimPrefix(phone, "+"), pii.PhoneHash(phone)).Scan(&depositPaid)
 	if err != nil {
 		depositPaid = false
 	}
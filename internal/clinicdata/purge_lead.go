@@ -0,0 +1,284 @@
+package clinicdata
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/pii"
+)
+
+// ErrLeadNotFound is returned when a lead purge targets a lead that does not
+// exist in the org.
+var ErrLeadNotFound = errors.New("clinicdata: lead not found")
+
+// LeadPurgeCounts tracks rows removed (or scrubbed) for a single lead.
+type LeadPurgeCounts struct {
+	ConversationJobs      int64 `json:"conversation_jobs"`
+	ConversationMessages  int64 `json:"conversation_messages"`
+	Conversations         int64 `json:"conversations"`
+	Messages              int64 `json:"messages"`
+	ComplianceAuditEvents int64 `json:"compliance_audit_events"`
+	CallbackTasks         int64 `json:"callback_tasks"`
+	CallbackPromises      int64 `json:"callback_promises"`
+	Escalations           int64 `json:"escalations"`
+	RebookReminders       int64 `json:"rebook_reminders"`
+	LeadsDeleted          int64 `json:"leads_deleted"`
+	LeadsScrubbed         int64 `json:"leads_scrubbed"`
+	ArchivedConversations int64 `json:"archived_conversations"` // removed from cold storage
+}
+
+// LeadPurgeResult contains the outcome of a lead purge. In dry-run mode the
+// counts are what would have been removed; nothing is changed.
+type LeadPurgeResult struct {
+	OrgID           string          `json:"org_id"`
+	LeadID          string          `json:"lead_id"`
+	ConversationIDs []string        `json:"conversation_ids"`
+	DryRun          bool            `json:"dry_run"`
+	Purged          LeadPurgeCounts `json:"purged"`
+	RedisDeleted    int64           `json:"redis_deleted"`
+}
+
+// PurgeLead removes a lead's PHI across conversations, message bodies, Redis
+// history, audit events and operator notifications. A lead referenced by
+// bookings or payments is kept with its PII scrubbed (name to initials, phone
+// to last four digits) so financial records stay intact; otherwise the lead
+// row is deleted. Conversations moved to cold storage are removed from their
+// S3 batches too. With dryRun the work runs in a rolled-back transaction.
+func (p *Purger) PurgeLead(ctx context.Context, orgID, leadID string, dryRun bool) (LeadPurgeResult, error) {
+	orgID = strings.TrimSpace(orgID)
+	leadID = strings.TrimSpace(leadID)
+	if p == nil || p.db == nil {
+		return LeadPurgeResult{}, fmt.Errorf("clinicdata: database not configured")
+	}
+	if orgID == "" || leadID == "" {
+		return LeadPurgeResult{}, fmt.Errorf("clinicdata: missing orgID or leadID")
+	}
+	orgUUID, err := uuid.Parse(orgID)
+	if err != nil {
+		return LeadPurgeResult{}, fmt.Errorf("clinicdata: orgID must be a UUID: %w", err)
+	}
+	leadUUID, err := uuid.Parse(leadID)
+	if err != nil {
+		return LeadPurgeResult{}, fmt.Errorf("clinicdata: leadID must be a UUID: %w", err)
+	}
+
+	tx, err := p.db.Begin(ctx)
+	if err != nil {
+		return LeadPurgeResult{}, fmt.Errorf("clinicdata: begin tx: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var name, phone string
+	var hasFinancials bool
+	err = tx.QueryRow(ctx, `
+		SELECT COALESCE(name, ''), COALESCE(phone, ''),
+			EXISTS(SELECT 1 FROM bookings WHERE lead_id = l.id)
+			OR EXISTS(SELECT 1 FROM payments WHERE lead_id = l.id)
+		FROM leads l
+		WHERE org_id = $1 AND id = $2
+	`, orgID, leadUUID).Scan(&name, &phone, &hasFinancials)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return LeadPurgeResult{}, ErrLeadNotFound
+	}
+	if err != nil {
+		return LeadPurgeResult{}, fmt.Errorf("clinicdata: load lead: %w", err)
+	}
+	name, phone = pii.Reveal(name), pii.Reveal(phone)
+
+	digits := normalizeUSDigits(sanitizeDigits(phone))
+	e164 := ""
+	if digits != "" {
+		e164 = "+" + digits
+	}
+
+	convIDs, err := leadConversationIDs(ctx, tx, orgID, leadUUID, digits)
+	if err != nil {
+		return LeadPurgeResult{}, err
+	}
+
+	res := LeadPurgeResult{OrgID: orgID, LeadID: leadID, ConversationIDs: convIDs, DryRun: dryRun}
+	var archives map[string][]string
+	if p.coldStore != nil {
+		archives, err = coldArchives(ctx, tx, `
+			SELECT archive_key, conversation_id FROM conversations
+			WHERE conversation_id = ANY($1) AND archive_key IS NOT NULL
+		`, convIDs)
+		if err != nil {
+			return LeadPurgeResult{}, err
+		}
+		res.Purged.ArchivedConversations = countColdConversations(archives)
+	}
+	steps := []struct {
+		name  string
+		count *int64
+		query string
+		args  []any
+	}{
+		{"conversation jobs", &res.Purged.ConversationJobs, `
+			DELETE FROM conversation_jobs WHERE conversation_id = ANY($1)
+		`, []any{convIDs}},
+		{"conversation messages", &res.Purged.ConversationMessages, `
+			DELETE FROM conversation_messages WHERE conversation_id = ANY($1)
+		`, []any{convIDs}},
+		{"conversations", &res.Purged.Conversations, `
+			DELETE FROM conversations WHERE conversation_id = ANY($1)
+		`, []any{convIDs}},
+		{"messages", &res.Purged.Messages, `
+			DELETE FROM messages
+			WHERE clinic_id = $1 AND $2 <> '' AND (from_e164 = $2 OR to_e164 = $2)
+		`, []any{orgUUID, e164}},
+		{"compliance events", &res.Purged.ComplianceAuditEvents, `
+			DELETE FROM compliance_audit_events
+			WHERE org_id = $1 AND (lead_id = $2 OR conversation_id = ANY($3))
+		`, []any{orgUUID, leadUUID, convIDs}},
+		{"callback tasks", &res.Purged.CallbackTasks, `
+			DELETE FROM callback_tasks
+			WHERE org_id = $1 AND (lead_id = $2 OR conversation_id = ANY($3))
+		`, []any{orgID, leadID, convIDs}},
+		{"callback promises", &res.Purged.CallbackPromises, `
+			DELETE FROM callback_promises
+			WHERE org_id = $1
+			  AND (lead_id = $2 OR ($3 <> '' AND regexp_replace(customer_phone, '\D', '', 'g') = $3))
+		`, []any{orgUUID, leadUUID, digits}},
+		{"escalations", &res.Purged.Escalations, `
+			DELETE FROM escalations
+			WHERE org_id = $1
+			  AND (lead_id = $2 OR ($3 <> '' AND regexp_replace(customer_phone, '\D', '', 'g') = $3))
+		`, []any{orgUUID, leadUUID, digits}},
+		{"rebook reminders", &res.Purged.RebookReminders, `
+			DELETE FROM rebook_reminders
+			WHERE org_id = $1
+			  AND (patient_id = $2 OR ($3 <> '' AND regexp_replace(phone, '\D', '', 'g') = $3))
+		`, []any{orgID, leadUUID, digits}},
+	}
+	for _, step := range steps {
+		n, err := execRowsAffected(ctx, tx, step.query, step.args...)
+		if err != nil {
+			return LeadPurgeResult{}, fmt.Errorf("clinicdata: purge %s: %w", step.name, err)
+		}
+		*step.count = n
+	}
+
+	if hasFinancials {
+		res.Purged.LeadsScrubbed, err = execRowsAffected(ctx, tx, `
+			UPDATE leads
+			SET name = $3, phone = $4, phone_hash = NULL, email = NULL, message = NULL, scheduling_notes = NULL, pii_purged_at = NOW()
+			WHERE org_id = $1 AND id = $2
+		`, orgID, leadUUID, initials(name), lastFourDigits(digits))
+		if err != nil {
+			return LeadPurgeResult{}, fmt.Errorf("clinicdata: scrub lead: %w", err)
+		}
+	} else {
+		res.Purged.LeadsDeleted, err = execRowsAffected(ctx, tx, `
+			DELETE FROM leads WHERE org_id = $1 AND id = $2
+		`, orgID, leadUUID)
+		if err != nil {
+			return LeadPurgeResult{}, fmt.Errorf("clinicdata: delete lead: %w", err)
+		}
+	}
+
+	keys := conversationRedisKeys(convIDs)
+	if dryRun {
+		if p.redis != nil && len(keys) > 0 {
+			res.RedisDeleted, _ = p.redis.Exists(ctx, keys...).Result()
+		}
+		return res, nil
+	}
+
+	if len(archives) > 0 {
+		if res.Purged.ArchivedConversations, err = p.deleteColdArchives(ctx, archives); err != nil {
+			return LeadPurgeResult{}, err
+		}
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return LeadPurgeResult{}, fmt.Errorf("clinicdata: commit lead purge: %w", err)
+	}
+	if p.redis != nil && len(keys) > 0 {
+		n, err := p.redis.Del(ctx, keys...).Result()
+		if err != nil {
+			p.logger.Warn("clinicdata lead purge: redis DEL failed", "error", err, "org_id", orgID, "lead_id", leadID)
+		} else {
+			res.RedisDeleted = n
+		}
+	}
+	return res, nil
+}
+
+// leadConversationIDs returns the lead's conversations, including the
+// canonical SMS conversation IDs for its phone.
+func leadConversationIDs(ctx context.Context, tx pgx.Tx, orgID string, leadID uuid.UUID, digits string) ([]string, error) {
+	rows, err := tx.Query(ctx, `
+		SELECT conversation_id
+		FROM conversations
+		WHERE org_id = $1
+		  AND (lead_id = $2 OR ($3 <> '' AND regexp_replace(phone, '\D', '', 'g') = $3))
+	`, orgID, leadID, digits)
+	if err != nil {
+		return nil, fmt.Errorf("clinicdata: list lead conversations: %w", err)
+	}
+	defer rows.Close()
+
+	seen := make(map[string]bool)
+	var ids []string
+	add := func(id string) {
+		if id != "" && !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	if digits != "" {
+		add(fmt.Sprintf("sms:%s:%s", orgID, digits))
+		add(fmt.Sprintf("sms:%s:+%s", orgID, digits))
+	}
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("clinicdata: scan lead conversation: %w", err)
+		}
+		add(id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("clinicdata: list lead conversations: %w", err)
+	}
+	if ids == nil {
+		ids = []string{}
+	}
+	return ids, nil
+}
+
+// conversationRedisKeys lists the Redis history keys held for conversations.
+func conversationRedisKeys(conversationIDs []string) []string {
+	keys := make([]string, 0, len(conversationIDs)*3)
+	for _, id := range conversationIDs {
+		keys = append(keys,
+			fmt.Sprintf("conversation:%s", id),
+			fmt.Sprintf("sms_transcript:%s", id),
+			fmt.Sprintf("time_selection:%s", id),
+		)
+	}
+	return keys
+}
+
+// initials reduces a name to its initials ("Jane Doe" becomes "J.D.").
+func initials(name string) string {
+	var b strings.Builder
+	for _, part := range strings.Fields(name) {
+		r := []rune(part)
+		b.WriteString(strings.ToUpper(string(r[0])))
+		b.WriteString(".")
+	}
+	return b.String()
+}
+
+// lastFourDigits keeps only the last four digits of a phone number.
+func lastFourDigits(digits string) string {
+	if len(digits) <= 4 {
+		return digits
+	}
+	return digits[len(digits)-4:]
+}
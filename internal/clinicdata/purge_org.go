@@ -10,6 +10,7 @@ import (
 
 // PurgeOrg deletes ALL data for an organization. Use with caution!
 // If an archiver is configured, data is archived to S3 first.
+// Cold-storage archives of the deleted conversations are removed too.
 func (p *Purger) PurgeOrg(ctx context.Context, orgID string, opts ...PurgeOrgOptions) (PurgeResult, error) {
 	orgID = strings.TrimSpace(orgID)
 	if p == nil || p.db == nil {
@@ -81,6 +82,17 @@ func (p *Purger) PurgeOrg(ctx context.Context, orgID string, opts ...PurgeOrgOpt
 		return PurgeResult{}, fmt.Errorf("clinicdata: delete conversation messages: %w", err)
 	}
 
+	var archives map[string][]string
+	if p.coldStore != nil {
+		archives, err = coldArchives(ctx, tx, `
+			SELECT archive_key, conversation_id FROM conversations
+			WHERE org_id = $1 AND archive_key IS NOT NULL
+		`, orgID)
+		if err != nil {
+			return PurgeResult{}, err
+		}
+	}
+
 	// Delete ALL conversations for this org
 	resp.Deleted.Conversations, err = execRowsAffected(ctx, tx, `
 		DELETE FROM conversations WHERE org_id = $1
@@ -161,6 +173,13 @@ func (p *Purger) PurgeOrg(ctx context.Context, orgID string, opts ...PurgeOrgOpt
 		return PurgeResult{}, fmt.Errorf("clinicdata: delete unsubscribes: %w", err)
 	}
 
+	// Cold-storage batches hold a single org, so this drops whole objects
+	if len(archives) > 0 {
+		if resp.Deleted.ArchivedConversations, err = p.deleteColdArchives(ctx, archives); err != nil {
+			return PurgeResult{}, err
+		}
+	}
+
 	if err := tx.Commit(ctx); err != nil {
 		return PurgeResult{}, fmt.Errorf("clinicdata: commit purge: %w", err)
 	}
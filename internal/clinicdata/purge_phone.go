@@ -7,6 +7,8 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/pii"
 )
 
 type db interface {
@@ -15,6 +17,7 @@ type db interface {
 
 // PurgePhone deletes all data for a specific phone number within an org.
 // If an archiver is configured, data is archived to S3 before deletion.
+// Cold-storage archives of the deleted conversations are removed too.
 func (p *Purger) PurgePhone(ctx context.Context, orgID string, phone string, opts ...PurgePhoneOptions) (PurgeResult, error) {
 	orgID = strings.TrimSpace(orgID)
 	phone = strings.TrimSpace(phone)
@@ -36,6 +39,8 @@ func (p *Purger) PurgePhone(ctx context.Context, orgID string, phone string, opt
 	}
 	digits = normalizeUSDigits(digits)
 	e164 := "+" + digits
+	// Leads with an encrypted phone are matched by its hash instead.
+	phoneHash := pii.PhoneHash(digits)
 
 	// Extract options
 	var orgName string
@@ -114,6 +119,19 @@ func (p *Purger) PurgePhone(ctx context.Context, orgID string, phone string, opt
 		return PurgeResult{}, fmt.Errorf("clinicdata: delete conversation messages: %w", err)
 	}
 
+	var archives map[string][]string
+	if p.coldStore != nil {
+		archives, err = coldArchives(ctx, tx, `
+			SELECT archive_key, conversation_id FROM conversations
+			WHERE org_id = $1
+			  AND (conversation_id = $2 OR conversation_id = $3 OR regexp_replace(phone, '\D', '', 'g') = $4)
+			  AND archive_key IS NOT NULL
+		`, orgID, conversationIDDigits, conversationIDE164, digits)
+		if err != nil {
+			return PurgeResult{}, err
+		}
+	}
+
 	resp.Deleted.Conversations, err = execRowsAffected(ctx, tx, `
 		DELETE FROM conversations
 		WHERE org_id = $1
@@ -130,9 +148,9 @@ func (p *Purger) PurgePhone(ctx context.Context, orgID string, phone string, opt
 			SELECT id::text
 			FROM leads
 			WHERE org_id = $1
-			  AND regexp_replace(phone, '\D', '', 'g') = $2
+			  AND (regexp_replace(phone, '\D', '', 'g') = $2 OR phone_hash = $3)
 		  )
-	`, orgID, digits)
+	`, orgID, digits, phoneHash)
 	if err != nil {
 		return PurgeResult{}, fmt.Errorf("clinicdata: delete outbox: %w", err)
 	}
@@ -144,9 +162,9 @@ func (p *Purger) PurgePhone(ctx context.Context, orgID string, phone string, opt
 			SELECT id
 			FROM leads
 			WHERE org_id = $1
-			  AND regexp_replace(phone, '\D', '', 'g') = $2
+			  AND (regexp_replace(phone, '\D', '', 'g') = $2 OR phone_hash = $3)
 		  )
-	`, orgID, digits)
+	`, orgID, digits, phoneHash)
 	if err != nil {
 		return PurgeResult{}, fmt.Errorf("clinicdata: delete payments: %w", err)
 	}
@@ -158,9 +176,9 @@ func (p *Purger) PurgePhone(ctx context.Context, orgID string, phone string, opt
 			SELECT id
 			FROM leads
 			WHERE org_id = $1
-			  AND regexp_replace(phone, '\D', '', 'g') = $2
+			  AND (regexp_replace(phone, '\D', '', 'g') = $2 OR phone_hash = $3)
 		  )
-	`, orgID, digits)
+	`, orgID, digits, phoneHash)
 	if err != nil {
 		return PurgeResult{}, fmt.Errorf("clinicdata: delete bookings: %w", err)
 	}
@@ -174,10 +192,10 @@ func (p *Purger) PurgePhone(ctx context.Context, orgID string, phone string, opt
 				SELECT id
 				FROM leads
 				WHERE org_id = $3
-				  AND regexp_replace(phone, '\D', '', 'g') = $2
+				  AND (regexp_replace(phone, '\D', '', 'g') = $2 OR phone_hash = $4)
 			)
 		  )
-	`, orgUUID, digits, orgID)
+	`, orgUUID, digits, orgID, phoneHash)
 	if err != nil {
 		return PurgeResult{}, fmt.Errorf("clinicdata: delete callback promises: %w", err)
 	}
@@ -191,10 +209,10 @@ func (p *Purger) PurgePhone(ctx context.Context, orgID string, phone string, opt
 				SELECT id
 				FROM leads
 				WHERE org_id = $3
-				  AND regexp_replace(phone, '\D', '', 'g') = $2
+				  AND (regexp_replace(phone, '\D', '', 'g') = $2 OR phone_hash = $4)
 			)
 		  )
-	`, orgUUID, digits, orgID)
+	`, orgUUID, digits, orgID, phoneHash)
 	if err != nil {
 		return PurgeResult{}, fmt.Errorf("clinicdata: delete escalations: %w", err)
 	}
@@ -209,10 +227,10 @@ func (p *Purger) PurgePhone(ctx context.Context, orgID string, phone string, opt
 				SELECT id
 				FROM leads
 				WHERE org_id = $4
-				  AND regexp_replace(phone, '\D', '', 'g') = $5
+				  AND (regexp_replace(phone, '\D', '', 'g') = $5 OR phone_hash = $6)
 			)
 		  )
-	`, orgUUID, conversationIDDigits, conversationIDE164, orgID, digits)
+	`, orgUUID, conversationIDDigits, conversationIDE164, orgID, digits, phoneHash)
 	if err != nil {
 		return PurgeResult{}, fmt.Errorf("clinicdata: delete compliance events: %w", err)
 	}
@@ -220,8 +238,8 @@ func (p *Purger) PurgePhone(ctx context.Context, orgID string, phone string, opt
 	resp.Deleted.Leads, err = execRowsAffected(ctx, tx, `
 		DELETE FROM leads
 		WHERE org_id = $1
-		  AND regexp_replace(phone, '\D', '', 'g') = $2
-	`, orgID, digits)
+		  AND (regexp_replace(phone, '\D', '', 'g') = $2 OR phone_hash = $3)
+	`, orgID, digits, phoneHash)
 	if err != nil {
 		return PurgeResult{}, fmt.Errorf("clinicdata: delete leads: %w", err)
 	}
@@ -244,6 +262,12 @@ func (p *Purger) PurgePhone(ctx context.Context, orgID string, phone string, opt
 		return PurgeResult{}, fmt.Errorf("clinicdata: delete unsubscribes: %w", err)
 	}
 
+	if len(archives) > 0 {
+		if resp.Deleted.ArchivedConversations, err = p.deleteColdArchives(ctx, archives); err != nil {
+			return PurgeResult{}, err
+		}
+	}
+
 	if err := tx.Commit(ctx); err != nil {
 		return PurgeResult{}, fmt.Errorf("clinicdata: commit purge: %w", err)
 	}
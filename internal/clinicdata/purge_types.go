@@ -16,6 +16,7 @@ type Purger struct {
 	logger           *logging.Logger
 	archiver         *Archiver
 	trainingArchiver *archive.TrainingArchiver
+	coldStore        *ColdStore
 }
 
 // PurgerConfig holds configuration for creating a Purger.
@@ -25,6 +26,7 @@ type PurgerConfig struct {
 	Logger           *logging.Logger
 	Archiver         *Archiver                 // Optional: if set, archives data before purging
 	TrainingArchiver *archive.TrainingArchiver // Optional: if set, archives classified data for LLM training
+	ColdStore        *ColdStore                // Optional: if set, purges also delete cold-storage archives
 }
 
 // NewPurger creates a Purger with minimal configuration (db + redis + logger).
@@ -50,6 +52,7 @@ func NewPurgerWithConfig(cfg PurgerConfig) *Purger { //nolint:revive
 		logger:           cfg.Logger,
 		archiver:         cfg.Archiver,
 		trainingArchiver: cfg.TrainingArchiver,
+		coldStore:        cfg.ColdStore,
 	}
 }
 
@@ -67,6 +70,7 @@ type PurgeCounts struct {
 	Leads                 int64
 	Messages              int64
 	Unsubscribes          int64
+	ArchivedConversations int64 // removed from cold storage
 }
 
 // PurgeResult contains the outcome of a purge operation.
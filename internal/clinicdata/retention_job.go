@@ -0,0 +1,252 @@
+package clinicdata
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/clinic"
+	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
+)
+
+const (
+	defaultRetentionBatchSize = 100
+	defaultRetentionInterval  = 24 * time.Hour
+)
+
+// RetentionPolicySource loads a clinic's config for its retention window.
+type RetentionPolicySource interface {
+	Get(ctx context.Context, orgID string) (*clinic.Config, error)
+}
+
+// RetentionJobConfig configures the scheduled PHI purge.
+type RetentionJobConfig struct {
+	Purger    *Purger
+	Policies  RetentionPolicySource
+	BatchSize int
+	Interval  time.Duration
+	DryRun    bool // log what would be purged without changing anything
+	Logger    *logging.Logger
+}
+
+// RetentionJob purges leads that have been inactive longer than their
+// clinic's retention window. Leads with an upcoming appointment are skipped.
+type RetentionJob struct {
+	purger    *Purger
+	policies  RetentionPolicySource
+	batchSize int
+	interval  time.Duration
+	dryRun    bool
+	logger    *logging.Logger
+	now       func() time.Time
+}
+
+// RetentionRunResult summarizes one pass of the retention job.
+type RetentionRunResult struct {
+	Orgs    int
+	Purged  int
+	Skipped int // leads with a future appointment
+	Failed  int
+}
+
+// NewRetentionJob creates a retention job.
+func NewRetentionJob(cfg RetentionJobConfig) *RetentionJob {
+	if cfg.Logger == nil {
+		cfg.Logger = logging.Default()
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = defaultRetentionBatchSize
+	}
+	if cfg.Interval <= 0 {
+		cfg.Interval = defaultRetentionInterval
+	}
+	return &RetentionJob{
+		purger:    cfg.Purger,
+		policies:  cfg.Policies,
+		batchSize: cfg.BatchSize,
+		interval:  cfg.Interval,
+		dryRun:    cfg.DryRun,
+		logger:    cfg.Logger,
+		now:       time.Now,
+	}
+}
+
+// Start runs the retention job on its interval. Blocks until ctx is cancelled.
+func (j *RetentionJob) Start(ctx context.Context) {
+	if j == nil || j.purger == nil {
+		return
+	}
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+
+	j.run(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			j.run(ctx)
+		}
+	}
+}
+
+func (j *RetentionJob) run(ctx context.Context) {
+	res, err := j.RunOnce(ctx)
+	if err != nil {
+		j.logger.Error("retention purge failed", "error", err)
+		return
+	}
+	j.logger.Info("retention purge completed",
+		"dry_run", j.dryRun,
+		"orgs", res.Orgs,
+		"purged", res.Purged,
+		"skipped_future_appointments", res.Skipped,
+		"failed", res.Failed,
+	)
+}
+
+// RunOnce performs a single pass over every org with unpurged leads.
+func (j *RetentionJob) RunOnce(ctx context.Context) (RetentionRunResult, error) {
+	var res RetentionRunResult
+	orgIDs, err := j.retentionOrgs(ctx)
+	if err != nil {
+		return res, err
+	}
+	now := j.now().UTC()
+	for _, orgID := range orgIDs {
+		if ctx.Err() != nil {
+			return res, ctx.Err()
+		}
+		res.Orgs++
+		var cfg *clinic.Config
+		if j.policies != nil {
+			cfg, err = j.policies.Get(ctx, orgID)
+			if err != nil {
+				j.logger.Warn("retention: failed to load clinic config, skipping org", "error", err, "org_id", orgID)
+				continue
+			}
+		}
+		if err := j.purgeOrg(ctx, orgID, cfg.RetentionCutoff(now), now, &res); err != nil {
+			return res, err
+		}
+	}
+	return res, nil
+}
+
+// purgeOrg walks the org's expired leads in id order, one batch at a time.
+func (j *RetentionJob) purgeOrg(ctx context.Context, orgID string, cutoff, now time.Time, res *RetentionRunResult) error {
+	afterID := ""
+	for batch := 1; ; batch++ {
+		candidates, err := j.retentionCandidates(ctx, orgID, cutoff, afterID)
+		if err != nil {
+			return err
+		}
+		if len(candidates) == 0 {
+			return nil
+		}
+		var purged, skipped, failed int
+		for _, c := range candidates {
+			afterID = c.leadID
+			if c.hasAppointmentAfter(now) {
+				skipped++
+				continue
+			}
+			if _, err := j.purger.PurgeLead(ctx, orgID, c.leadID, j.dryRun); err != nil {
+				j.logger.Warn("retention: lead purge failed", "error", err, "org_id", orgID, "lead_id", c.leadID)
+				failed++
+				continue
+			}
+			purged++
+		}
+		res.Purged += purged
+		res.Skipped += skipped
+		res.Failed += failed
+		j.logger.Info("retention: batch processed",
+			"org_id", orgID,
+			"batch", batch,
+			"cutoff", cutoff.Format(time.RFC3339),
+			"dry_run", j.dryRun,
+			"purged", purged,
+			"skipped_future_appointments", skipped,
+			"failed", failed,
+		)
+		if len(candidates) < j.batchSize {
+			return nil
+		}
+	}
+}
+
+// retentionCandidate is a lead with no activity since the cutoff.
+type retentionCandidate struct {
+	leadID      string
+	bookedFor   *time.Time
+	selectedFor *time.Time
+}
+
+func (c retentionCandidate) hasAppointmentAfter(now time.Time) bool {
+	return (c.bookedFor != nil && c.bookedFor.After(now)) ||
+		(c.selectedFor != nil && c.selectedFor.After(now))
+}
+
+func (j *RetentionJob) retentionOrgs(ctx context.Context) ([]string, error) {
+	tx, err := j.purger.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("clinicdata: begin tx: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	rows, err := tx.Query(ctx, `SELECT DISTINCT org_id FROM leads WHERE pii_purged_at IS NULL ORDER BY org_id`)
+	if err != nil {
+		return nil, fmt.Errorf("clinicdata: list retention orgs: %w", err)
+	}
+	defer rows.Close()
+	var orgIDs []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("clinicdata: scan retention org: %w", err)
+		}
+		orgIDs = append(orgIDs, id)
+	}
+	return orgIDs, rows.Err()
+}
+
+// retentionCandidates returns the next batch of the org's leads created
+// before the cutoff with no conversation activity since.
+func (j *RetentionJob) retentionCandidates(ctx context.Context, orgID string, cutoff time.Time, afterID string) ([]retentionCandidate, error) {
+	tx, err := j.purger.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("clinicdata: begin tx: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	rows, err := tx.Query(ctx, `
+		SELECT l.id::text,
+			(SELECT MAX(b.scheduled_for) FROM bookings b WHERE b.lead_id = l.id),
+			l.selected_datetime
+		FROM leads l
+		WHERE l.org_id = $1
+		  AND l.pii_purged_at IS NULL
+		  AND l.created_at < $2
+		  AND NOT EXISTS (
+			SELECT 1 FROM conversations c
+			WHERE c.lead_id = l.id AND COALESCE(c.last_message_at, c.started_at) >= $2
+		  )
+		  AND l.id::text > $3
+		ORDER BY l.id::text
+		LIMIT $4
+	`, orgID, cutoff, afterID, j.batchSize)
+	if err != nil {
+		return nil, fmt.Errorf("clinicdata: list retention candidates: %w", err)
+	}
+	defer rows.Close()
+	var out []retentionCandidate
+	for rows.Next() {
+		var c retentionCandidate
+		if err := rows.Scan(&c.leadID, &c.bookedFor, &c.selectedFor); err != nil {
+			return nil, fmt.Errorf("clinicdata: scan retention candidate: %w", err)
+		}
+		out = append(out, c)
+	}
+	return out, rows.Err()
+}
@@ -0,0 +1,242 @@
+package clinicdata
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	miniredis "github.com/alicebob/miniredis/v2"
+	"github.com/google/uuid"
+	pgxmock "github.com/pashagolub/pgxmock/v4"
+	"github.com/redis/go-redis/v9"
+	"github.com/wolfman30/medspa-ai-platform/internal/clinic"
+	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
+)
+
+// expectLeadPurge queues the cascade PurgeLead runs for a lead with no
+// bookings or payments and a single stored conversation.
+func expectLeadPurge(mock pgxmock.PgxPoolIface, orgID string, leadUUID uuid.UUID, digits, storedConvID string, commit bool) []string {
+	orgUUID := uuid.MustParse(orgID)
+	convIDs := []string{"sms:" + orgID + ":" + digits, "sms:" + orgID + ":+" + digits, storedConvID}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("FROM leads l").WithArgs(orgID, leadUUID).
+		WillReturnRows(pgxmock.NewRows([]string{"name", "phone", "has_financials"}).AddRow("Jane Doe", "+"+digits, false))
+	mock.ExpectQuery("SELECT conversation_id").WithArgs(orgID, leadUUID, digits).
+		WillReturnRows(pgxmock.NewRows([]string{"conversation_id"}).AddRow(storedConvID))
+	mock.ExpectExec("DELETE FROM conversation_jobs").WithArgs(convIDs).WillReturnResult(pgxmock.NewResult("DELETE", 1))
+	mock.ExpectExec("DELETE FROM conversation_messages").WithArgs(convIDs).WillReturnResult(pgxmock.NewResult("DELETE", 4))
+	mock.ExpectExec("DELETE FROM conversations").WithArgs(convIDs).WillReturnResult(pgxmock.NewResult("DELETE", 1))
+	mock.ExpectExec("DELETE FROM messages").WithArgs(orgUUID, "+"+digits).WillReturnResult(pgxmock.NewResult("DELETE", 6))
+	mock.ExpectExec("DELETE FROM compliance_audit_events").WithArgs(orgUUID, leadUUID, convIDs).WillReturnResult(pgxmock.NewResult("DELETE", 2))
+	mock.ExpectExec("DELETE FROM callback_tasks").WithArgs(orgID, leadUUID.String(), convIDs).WillReturnResult(pgxmock.NewResult("DELETE", 1))
+	mock.ExpectExec("DELETE FROM callback_promises").WithArgs(orgUUID, leadUUID, digits).WillReturnResult(pgxmock.NewResult("DELETE", 0))
+	mock.ExpectExec("DELETE FROM escalations").WithArgs(orgUUID, leadUUID, digits).WillReturnResult(pgxmock.NewResult("DELETE", 1))
+	mock.ExpectExec("DELETE FROM rebook_reminders").WithArgs(orgID, leadUUID, digits).WillReturnResult(pgxmock.NewResult("DELETE", 0))
+	mock.ExpectExec("DELETE FROM leads").WithArgs(orgID, leadUUID).WillReturnResult(pgxmock.NewResult("DELETE", 1))
+	if commit {
+		mock.ExpectCommit()
+	} else {
+		mock.ExpectRollback()
+	}
+	return convIDs
+}
+
+func TestPurgeLead_CascadesAcrossTables(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("pgxmock: %v", err)
+	}
+	defer mock.Close()
+
+	mr := miniredis.RunT(t)
+	redisClient := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+
+	orgID := uuid.New().String()
+	leadUUID := uuid.New()
+	digits := "15551234567"
+	convIDs := expectLeadPurge(mock, orgID, leadUUID, digits, "web:"+orgID+":abc", true)
+	for _, key := range conversationRedisKeys(convIDs) {
+		mr.Set(key, "[]")
+	}
+	mr.Set("conversation:sms:"+orgID+":15559999999", "[]")
+
+	res, err := NewPurger(mock, redisClient, logging.Default()).PurgeLead(context.Background(), orgID, leadUUID.String(), false)
+	if err != nil {
+		t.Fatalf("PurgeLead: %v", err)
+	}
+	if res.Purged.LeadsDeleted != 1 || res.Purged.Messages != 6 || res.Purged.ComplianceAuditEvents != 2 || res.Purged.Escalations != 1 {
+		t.Fatalf("unexpected counts: %+v", res.Purged)
+	}
+	if res.RedisDeleted != int64(len(convIDs)*3) {
+		t.Fatalf("redis deleted = %d", res.RedisDeleted)
+	}
+	for _, key := range conversationRedisKeys(convIDs) {
+		if mr.Exists(key) {
+			t.Fatalf("expected %s deleted", key)
+		}
+	}
+	if !mr.Exists("conversation:sms:" + orgID + ":15559999999") {
+		t.Fatal("other patients' history must be kept")
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestPurgeLead_DryRunRollsBack(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("pgxmock: %v", err)
+	}
+	defer mock.Close()
+
+	mr := miniredis.RunT(t)
+	redisClient := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+
+	orgID := uuid.New().String()
+	leadUUID := uuid.New()
+	convIDs := expectLeadPurge(mock, orgID, leadUUID, "15551234567", "web:"+orgID+":abc", false)
+	mr.Set("conversation:"+convIDs[0], "[]")
+
+	res, err := NewPurger(mock, redisClient, logging.Default()).PurgeLead(context.Background(), orgID, leadUUID.String(), true)
+	if err != nil {
+		t.Fatalf("PurgeLead: %v", err)
+	}
+	if !res.DryRun || res.RedisDeleted != 1 {
+		t.Fatalf("unexpected dry run result: %+v", res)
+	}
+	if !mr.Exists("conversation:" + convIDs[0]) {
+		t.Fatal("dry run must not delete redis history")
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestPurgeLead_ScrubsLeadWithFinancials(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("pgxmock: %v", err)
+	}
+	defer mock.Close()
+
+	orgID := uuid.New().String()
+	leadUUID := uuid.New()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("FROM leads l").WithArgs(orgID, leadUUID).
+		WillReturnRows(pgxmock.NewRows([]string{"name", "phone", "has_financials"}).AddRow("jane van doe", "(555) 123-4567", true))
+	mock.ExpectQuery("SELECT conversation_id").WithArgs(orgID, leadUUID, "15551234567").
+		WillReturnRows(pgxmock.NewRows([]string{"conversation_id"}))
+	any1, any2, any3 := []any{pgxmock.AnyArg()}, []any{pgxmock.AnyArg(), pgxmock.AnyArg()}, []any{pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg()}
+	for _, step := range []struct {
+		table string
+		args  []any
+	}{
+		{"conversation_jobs", any1}, {"conversation_messages", any1}, {"conversations", any1},
+		{"messages", any2}, {"compliance_audit_events", any3}, {"callback_tasks", any3},
+		{"callback_promises", any3}, {"escalations", any3}, {"rebook_reminders", any3},
+	} {
+		mock.ExpectExec("DELETE FROM " + step.table).WithArgs(step.args...).WillReturnResult(pgxmock.NewResult("DELETE", 0))
+	}
+	mock.ExpectExec("UPDATE leads").WithArgs(orgID, leadUUID, "J.V.D.", "4567").WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	mock.ExpectCommit()
+
+	res, err := NewPurger(mock, nil, logging.Default()).PurgeLead(context.Background(), orgID, leadUUID.String(), false)
+	if err != nil {
+		t.Fatalf("PurgeLead: %v", err)
+	}
+	if res.Purged.LeadsScrubbed != 1 || res.Purged.LeadsDeleted != 0 {
+		t.Fatalf("expected lead scrubbed, got %+v", res.Purged)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestPurgeLead_NotFound(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("pgxmock: %v", err)
+	}
+	defer mock.Close()
+
+	orgID := uuid.New().String()
+	leadUUID := uuid.New()
+	mock.ExpectBegin()
+	mock.ExpectQuery("FROM leads l").WithArgs(orgID, leadUUID).
+		WillReturnRows(pgxmock.NewRows([]string{"name", "phone", "has_financials"}))
+	mock.ExpectRollback()
+
+	_, err = NewPurger(mock, nil, logging.Default()).PurgeLead(context.Background(), orgID, leadUUID.String(), false)
+	if !errors.Is(err, ErrLeadNotFound) {
+		t.Fatalf("expected ErrLeadNotFound, got %v", err)
+	}
+}
+
+type stubPolicies map[string]*clinic.Config
+
+func (s stubPolicies) Get(ctx context.Context, orgID string) (*clinic.Config, error) {
+	return s[orgID], nil
+}
+
+func TestRetentionJob_HonorsClinicWindowAndSkipsFutureAppointments(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("pgxmock: %v", err)
+	}
+	defer mock.Close()
+
+	now := time.Date(2026, 6, 15, 12, 0, 0, 0, time.UTC)
+	shortOrg := uuid.New().String()
+	defaultOrg := uuid.New().String()
+	expiredLead := uuid.New()
+	bookedLead := uuid.New()
+	future := now.Add(72 * time.Hour)
+	candidateCols := []string{"id", "booked_for", "selected_datetime"}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT DISTINCT org_id FROM leads").
+		WillReturnRows(pgxmock.NewRows([]string{"org_id"}).AddRow(shortOrg).AddRow(defaultOrg))
+	mock.ExpectRollback()
+
+	// Clinic with a 6-month window: one lead expired, one still has an upcoming appointment.
+	mock.ExpectBegin()
+	mock.ExpectQuery("FROM leads l").WithArgs(shortOrg, time.Date(2025, 12, 15, 12, 0, 0, 0, time.UTC), "", 2).
+		WillReturnRows(pgxmock.NewRows(candidateCols).
+			AddRow(bookedLead.String(), &future, (*time.Time)(nil)).
+			AddRow(expiredLead.String(), (*time.Time)(nil), (*time.Time)(nil)))
+	mock.ExpectRollback()
+	expectLeadPurge(mock, shortOrg, expiredLead, "15551234567", "web:"+shortOrg+":abc", true)
+	mock.ExpectBegin()
+	mock.ExpectQuery("FROM leads l").WithArgs(shortOrg, time.Date(2025, 12, 15, 12, 0, 0, 0, time.UTC), expiredLead.String(), 2).
+		WillReturnRows(pgxmock.NewRows(candidateCols))
+	mock.ExpectRollback()
+
+	// Clinic without a policy falls back to the 18-month default.
+	mock.ExpectBegin()
+	mock.ExpectQuery("FROM leads l").WithArgs(defaultOrg, time.Date(2024, 12, 15, 12, 0, 0, 0, time.UTC), "", 2).
+		WillReturnRows(pgxmock.NewRows(candidateCols))
+	mock.ExpectRollback()
+
+	job := NewRetentionJob(RetentionJobConfig{
+		Purger:    NewPurger(mock, nil, logging.Default()),
+		Policies:  stubPolicies{shortOrg: {DataRetentionMonths: 6}},
+		BatchSize: 2,
+		Logger:    logging.Default(),
+	})
+	job.now = func() time.Time { return now }
+
+	res, err := job.RunOnce(context.Background())
+	if err != nil {
+		t.Fatalf("RunOnce: %v", err)
+	}
+	if res.Orgs != 2 || res.Purged != 1 || res.Skipped != 1 || res.Failed != 0 {
+		t.Fatalf("unexpected result: %+v", res)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
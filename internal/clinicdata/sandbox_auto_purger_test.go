@@ -44,13 +44,13 @@ func TestSandboxAutoPurger_PurgesWhenPhoneMatches(t *testing.T) {
 	mock.ExpectExec("DELETE FROM conversation_jobs").WithArgs(conversationID, conversationIDE164).WillReturnResult(pgxmock.NewResult("DELETE", 1))
 	mock.ExpectExec("DELETE FROM conversation_messages").WithArgs(orgID, conversationID, conversationIDE164, digits).WillReturnResult(pgxmock.NewResult("DELETE", 0))
 	mock.ExpectExec("DELETE FROM conversations").WithArgs(orgID, conversationID, conversationIDE164, digits).WillReturnResult(pgxmock.NewResult("DELETE", 0))
-	mock.ExpectExec("DELETE FROM outbox").WithArgs(orgID, digits).WillReturnResult(pgxmock.NewResult("DELETE", 0))
-	mock.ExpectExec("DELETE FROM payments").WithArgs(orgID, digits).WillReturnResult(pgxmock.NewResult("DELETE", 1))
-	mock.ExpectExec("DELETE FROM bookings").WithArgs(orgID, digits).WillReturnResult(pgxmock.NewResult("DELETE", 0))
-	mock.ExpectExec("DELETE FROM callback_promises").WithArgs(orgUUID, digits, orgID).WillReturnResult(pgxmock.NewResult("DELETE", 0))
-	mock.ExpectExec("DELETE FROM escalations").WithArgs(orgUUID, digits, orgID).WillReturnResult(pgxmock.NewResult("DELETE", 0))
-	mock.ExpectExec("DELETE FROM compliance_audit_events").WithArgs(orgUUID, conversationID, conversationIDE164, orgID, digits).WillReturnResult(pgxmock.NewResult("DELETE", 0))
-	mock.ExpectExec("DELETE FROM leads").WithArgs(orgID, digits).WillReturnResult(pgxmock.NewResult("DELETE", 1))
+	mock.ExpectExec("DELETE FROM outbox").WithArgs(orgID, digits, "").WillReturnResult(pgxmock.NewResult("DELETE", 0))
+	mock.ExpectExec("DELETE FROM payments").WithArgs(orgID, digits, "").WillReturnResult(pgxmock.NewResult("DELETE", 1))
+	mock.ExpectExec("DELETE FROM bookings").WithArgs(orgID, digits, "").WillReturnResult(pgxmock.NewResult("DELETE", 0))
+	mock.ExpectExec("DELETE FROM callback_promises").WithArgs(orgUUID, digits, orgID, "").WillReturnResult(pgxmock.NewResult("DELETE", 0))
+	mock.ExpectExec("DELETE FROM escalations").WithArgs(orgUUID, digits, orgID, "").WillReturnResult(pgxmock.NewResult("DELETE", 0))
+	mock.ExpectExec("DELETE FROM compliance_audit_events").WithArgs(orgUUID, conversationID, conversationIDE164, orgID, digits, "").WillReturnResult(pgxmock.NewResult("DELETE", 0))
+	mock.ExpectExec("DELETE FROM leads").WithArgs(orgID, digits, "").WillReturnResult(pgxmock.NewResult("DELETE", 1))
 	mock.ExpectExec("DELETE FROM messages").WithArgs(orgUUID, e164).WillReturnResult(pgxmock.NewResult("DELETE", 0))
 	mock.ExpectExec("DELETE FROM unsubscribes").WithArgs(orgUUID, e164).WillReturnResult(pgxmock.NewResult("DELETE", 0))
 	mock.ExpectCommit()
@@ -29,8 +29,22 @@ const (
 	EventKnowledgeRead AuditEventType = "compliance.knowledge_read"
 	// EventKnowledgeUpdated is logged when clinic knowledge is updated.
 	EventKnowledgeUpdated AuditEventType = "compliance.knowledge_updated"
+	// EventTranscriptUnmasked is logged when a conversation transcript is
+	// exported with the lead's name and phone revealed.
+	EventTranscriptUnmasked AuditEventType = "compliance.transcript_unmasked"
+	// EventSensitiveDataRedacted is logged when card numbers, security codes
+	// or SSNs are stripped from an inbound message.
+	EventSensitiveDataRedacted AuditEventType = "compliance.sensitive_data_redacted"
 	// EventPromptInjection is logged when a prompt injection attempt is detected.
 	EventPromptInjection AuditEventType = "security.prompt_injection"
+	// EventFrustrationEscalated is logged when a frustrated patient is handed to staff.
+	EventFrustrationEscalated AuditEventType = "conversation.frustration_escalated"
+	// EventModelEscalation is logged when a turn's escalation triggers fire,
+	// whether the stronger model answered or the budget was exhausted.
+	EventModelEscalation AuditEventType = "conversation.model_escalation"
+	// EventLLMOverrideApplied is logged when a clinic's LLM overrides set the
+	// parameters of a completion.
+	EventLLMOverrideApplied AuditEventType = "conversation.llm_override_applied"
 )
 
 // AuditEvent represents an immutable compliance audit record.
@@ -56,6 +70,9 @@ type AuditDetails struct {
 	PHIType     string `json:"phi_type,omitempty"`
 	PHIRedacted bool   `json:"phi_redacted,omitempty"`
 
+	// For sensitive data redacted
+	RedactedKinds []string `json:"redacted_kinds,omitempty"`
+
 	// For disclaimer sent
 	DisclaimerLevel string `json:"disclaimer_level,omitempty"`
 	DisclaimerText  string `json:"disclaimer_text,omitempty"`
@@ -68,6 +85,21 @@ type AuditDetails struct {
 	OriginalResponse   string `json:"original_response,omitempty"`
 	ModifiedResponse   string `json:"modified_response,omitempty"`
 	ModificationReason string `json:"modification_reason,omitempty"`
+
+	// For frustration escalation
+	FrustrationScore   float64  `json:"frustration_score,omitempty"`
+	FrustrationSignals []string `json:"frustration_signals,omitempty"`
+
+	// For model escalation
+	Model         string   `json:"model,omitempty"`
+	ModelRoute    string   `json:"model_route,omitempty"`
+	ModelTriggers []string `json:"model_triggers,omitempty"`
+	LatencyMs     int64    `json:"latency_ms,omitempty"`
+
+	// For LLM overrides (Model holds the effective model)
+	LLMPurpose     string   `json:"llm_purpose,omitempty"`
+	LLMTemperature *float64 `json:"llm_temperature,omitempty"`
+	LLMMaxTokens   int      `json:"llm_max_tokens,omitempty"`
 }
 
 // AuditService handles compliance audit logging.
@@ -150,6 +182,25 @@ func (s *AuditService) LogPHIDetected(ctx context.Context, orgID, conversationID
 	})
 }
 
+// LogSensitiveDataRedacted logs which kinds of sensitive number ("card",
+// "cvv", "ssn") were redacted from an inbound message. The message itself is
+// never stored.
+func (s *AuditService) LogSensitiveDataRedacted(ctx context.Context, orgID, conversationID, leadID string, kinds []string) error {
+	details := AuditDetails{
+		RedactedKinds: kinds,
+	}
+	detailsJSON, _ := json.Marshal(details)
+
+	return s.LogEvent(ctx, AuditEvent{
+		EventType:      EventSensitiveDataRedacted,
+		OrgID:          orgID,
+		ConversationID: conversationID,
+		LeadID:         leadID,
+		UserMessage:    "[REDACTED]",
+		Details:        detailsJSON,
+	})
+}
+
 // LogPromptInjection logs when a prompt injection attempt is detected and blocked.
 func (s *AuditService) LogPromptInjection(ctx context.Context, orgID, conversationID, leadID string, reasons []string) error {
 	details := AuditDetails{
@@ -204,6 +255,64 @@ func (s *AuditService) LogSupervisorReview(ctx context.Context, orgID, conversat
 	})
 }
 
+// LogFrustrationEscalated logs when a conversation's frustration score crosses
+// the handoff threshold and AI replies are paused for staff.
+func (s *AuditService) LogFrustrationEscalated(ctx context.Context, orgID, conversationID, leadID string, score float64, signals []string) error {
+	details := AuditDetails{
+		FrustrationScore:   score,
+		FrustrationSignals: signals,
+	}
+	detailsJSON, _ := json.Marshal(details)
+
+	return s.LogEvent(ctx, AuditEvent{
+		EventType:      EventFrustrationEscalated,
+		OrgID:          orgID,
+		ConversationID: conversationID,
+		LeadID:         leadID,
+		Details:        detailsJSON,
+	})
+}
+
+// LogModelEscalation logs the model routing decision for a turn that hit
+// escalation triggers.
+func (s *AuditService) LogModelEscalation(ctx context.Context, orgID, conversationID, leadID, route, model string, triggers []string, latency time.Duration) error {
+	details := AuditDetails{
+		Model:         model,
+		ModelRoute:    route,
+		ModelTriggers: triggers,
+		LatencyMs:     latency.Milliseconds(),
+	}
+	detailsJSON, _ := json.Marshal(details)
+
+	return s.LogEvent(ctx, AuditEvent{
+		EventType:      EventModelEscalation,
+		OrgID:          orgID,
+		ConversationID: conversationID,
+		LeadID:         leadID,
+		Details:        detailsJSON,
+	})
+}
+
+// LogLLMOverride logs the effective parameters of a completion run under a
+// clinic's LLM overrides. purpose names the call, e.g. "reply".
+func (s *AuditService) LogLLMOverride(ctx context.Context, orgID, conversationID, leadID, purpose, model string, temperature float64, maxTokens int) error {
+	details := AuditDetails{
+		Model:          model,
+		LLMPurpose:     purpose,
+		LLMTemperature: &temperature,
+		LLMMaxTokens:   maxTokens,
+	}
+	detailsJSON, _ := json.Marshal(details)
+
+	return s.LogEvent(ctx, AuditEvent{
+		EventType:      EventLLMOverrideApplied,
+		OrgID:          orgID,
+		ConversationID: conversationID,
+		LeadID:         leadID,
+		Details:        detailsJSON,
+	})
+}
+
 // QueryEvents retrieves audit events with filters.
 func (s *AuditService) QueryEvents(ctx context.Context, filter AuditFilter) ([]AuditEvent, error) {
 	query := `
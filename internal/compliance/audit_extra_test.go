@@ -47,3 +47,18 @@ func TestAuditService_LogSupervisorReview(t *testing.T) {
 	err = service.LogSupervisorReview(context.Background(), "org-1", "conv-1", "lead-1", "block", "original response", "modified response", "unsafe content")
 	assert.NoError(t, err)
 }
+
+func TestAuditService_LogSensitiveDataRedacted(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectExec("INSERT INTO compliance_audit_events").
+		WithArgs(sqlmock.AnyArg(), EventSensitiveDataRedacted, "org-1", "conv-1", "lead-1", "[REDACTED]", sqlmock.AnyArg(), []byte(`{"redacted_kinds":["card","cvv"]}`), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	service := NewAuditService(db)
+	err = service.LogSensitiveDataRedacted(context.Background(), "org-1", "conv-1", "lead-1", []string{"card", "cvv"})
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"fmt"
 	"strings"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/messaging/templates"
 )
 
 // DisclaimerLevel represents the verbosity of the disclaimer.
@@ -18,13 +20,13 @@ const (
 	DisclaimerFull DisclaimerLevel = "full"
 )
 
-// Disclaimer templates
-const (
-	disclaimerShortText = "Auto-assistant. Not medical advice."
+// Disclaimer templates, registered in the outbound template catalog.
+var (
+	disclaimerShortText = templates.Text(templates.PolicyDisclaimerShort)
 
-	disclaimerMediumText = "This is an automated assistant. For medical advice, please consult your provider."
+	disclaimerMediumText = templates.Text(templates.PolicyDisclaimerMedium)
 
-	disclaimerFullText = "This is an automated scheduling assistant. The information provided is general in nature and not a substitute for professional medical advice. Please consult with a licensed healthcare provider for medical guidance."
+	disclaimerFullText = templates.Text(templates.PolicyDisclaimerFull)
 )
 
 // DisclaimerConfig configures the disclaimer service.
@@ -2,6 +2,7 @@ package config
 
 import (
 	"log"
+	"net/url"
 	"os"
 	"strconv"
 	"strings"
@@ -13,14 +14,34 @@ type Config struct {
 	Port                            string
 	Env                             string
 	PublicBaseURL                   string
+	PayLinkBaseURL                  string // branded short payment link domain, e.g. https://pay.aiwolfsolutions.com
 	LogLevel                        string
 	CORSAllowedOrigins              []string
 	UseMemoryQueue                  bool
 	WorkerCount                     int
+	WorkerOrgConcurrency            int           // max jobs per org running at once in one worker process
+	AvailabilityFetchConcurrency    int           // max concurrent availability fetches per clinic across workers
+	WorkerDrainTimeout              time.Duration // how long in-flight jobs may finish after SIGTERM before being requeued
+	WorkerMetricsPort               string        // port the conversation worker serves /metrics and /healthz on; empty disables
+	InboundBatchWindow              time.Duration // quiet period that rapid-fire texts are coalesced over before the LLM call; 0 disables
+	ConversationLockTTL             time.Duration // expiry of the per-conversation processing lock, extended while a job runs; 0 disables
+	ConversationLockWait            time.Duration // how long a job waits for a busy conversation before being requeued
+	ReplyLatencyBudget              time.Duration // inbound-to-reply time over which a reply counts as over budget
+	ProgressInitialDelay            time.Duration // how long the first "still searching" text is held; dropped if the reply is ready first
+	ProgressMinInterval             time.Duration // minimum gap between "still searching" texts
+	FrustrationThreshold            float64       // accumulated frustration score that hands a conversation to staff; 0 disables
+	FrustrationLLMClassifier        bool          // also rate inbound texts for frustration with the LLM
 	DatabaseURL                     string
 	PersistConversationHistory      bool
 	ConversationPersistExcludePhone string
 	SMSProvider                     string
+	SMSFailoverNumbersJSON          string
+	SMSMaxSegments                  int           // segments one outbound message may use before the reply is split; 0 disables splitting
+	SMSMaxMessagesPerReply          int           // messages one reply may be split into before it is truncated
+	SMSSplitSpacing                 time.Duration // pause between the messages of a split reply
+	PIIEncryptionKeys               string        // comma-separated <keyID>:<base64 32-byte key>; unset disables encryption
+	PIIEncryptionActiveKey          string        // key ID new values are sealed under; defaults to the first key
+	PIIHMACKey                      string        // base64 key for the phone lookup hash
 	TelnyxAPIKey                    string
 	TelnyxMessagingProfileID        string
 	TelnyxWebhookSecret             string
@@ -34,6 +55,12 @@ type Config struct {
 	TelnyxRetryMaxAttempts          int
 	TelnyxRetryBaseDelay            time.Duration
 	TelnyxHostedPollInterval        time.Duration
+	BroadcastRatePerMinute          int
+	TelnyxConcatWindow              time.Duration
+	MaxInboundMessageChars          int
+	ContextTokenBudget              int
+	HistoryWriteHeader              bool
+	PromptExperiments               string
 	TwilioAccountSID                string
 	TwilioAuthToken                 string
 	TwilioWebhookSecret             string
@@ -54,6 +81,11 @@ type Config struct {
 	SquareSandbox                   bool
 	SquareCheckoutMode              string
 	SquareCheckoutAllowFallback     bool
+	PaymentReconcileInterval        time.Duration // how often Square payments are reconciled against ours; 0 disables
+	PaymentReconcileLookback        time.Duration // how far back each reconciliation run looks
+	BookingOutcomePollInterval      time.Duration // how often ended bookings are completed and deposit digests checked; 0 disables
+	DigestTopLeads                  int           // how many unconverted leads the daily digest lists for a call; 0 leaves them out
+	TopLeadsDigestEnabled           bool          // send the top-leads digest even when booking outcome polling is off (default: true)
 	StripeSecretKey                 string
 	StripeWebhookSecret             string
 	StripeConnectClientID           string
@@ -66,6 +98,8 @@ type Config struct {
 	SandboxAutoPurgePhones          string
 	SandboxAutoPurgeDelay           time.Duration
 	AdminJWTSecret                  string
+	AdminJWTIssuer                  string
+	AdminJWTAudience                string
 	OnboardingToken                 string
 	QuietHoursStart                 string
 	QuietHoursEnd                   string
@@ -76,8 +110,11 @@ type Config struct {
 	AWSEndpointOverride             string
 	ConversationQueueURL            string
 	ConversationJobsTable           string
+	ConversationJobStaleAfter       time.Duration // how long a job may stay pending before the job reaper resolves it
 	BedrockModelID                  string
 	BedrockVoiceModelID             string
+	LLMEscalationModelID            string // stronger model for hard SMS turns; empty disables routing
+	LLMEscalationMonthlyBudget      int    // escalated turns allowed per org per month; 0 means no cap
 	BedrockEmbeddingModelID         string
 
 	// Gemini fallback provider configuration
@@ -139,6 +176,47 @@ type Config struct {
 	DisclaimerFirstOnly bool   // Only add disclaimer to first message in conversation
 	AuditRetentionDays  int    // How long to retain audit logs (default: 2555 = 7 years)
 
+	// WebhookEventRetentionDays is how long raw webhook payloads are kept for replay (0 disables cleanup).
+	WebhookEventRetentionDays int
+
+	// Data retention: purge lead PHI older than each clinic's retention window.
+	DataRetentionPurgeEnabled bool          // Run the scheduled purge job (default: false)
+	DataRetentionDryRun       bool          // Log what would be purged without changing anything
+	DataRetentionInterval     time.Duration // How often the purge job runs (default: 24h)
+	DataRetentionBatchSize    int           // Leads purged per batch (default: 100)
+
+	// Cold storage: move messages of idle conversations to S3, leaving stubs.
+	ColdStorageBucket    string        // S3 bucket for archived messages (empty = disabled)
+	ColdStorageEnabled   bool          // Run the scheduled export job (default: false)
+	ColdStorageMaxAge    time.Duration // Idle time before a conversation is archived (default: 90 days)
+	ColdStorageInterval  time.Duration // How often the export job runs (default: 24h)
+	ColdStorageBatchSize int           // Conversations per S3 object (default: 100)
+	ColdStorageCacheTTL  time.Duration // How long rehydrated conversations stay cached (default: 5m)
+
+	// Availability health: probe each clinic's booking platform for slots.
+	AvailabilityHealthEnabled      bool          // Run the scheduled probe (default: false)
+	AvailabilityHealthInterval     time.Duration // How often clinics are probed (default: 6h)
+	AvailabilityHealthProbeGap     time.Duration // Minimum spacing between platform calls (default: 2s)
+	AvailabilityHealthAlertWebhook string        // Engineering chat webhook for probe alerts
+
+	// Inbound health: alert when an active clinic stops receiving texts.
+	InboundHealthEnabled      bool          // Run the liveness checker (default: false)
+	InboundHealthInterval     time.Duration // How often clinics are checked (default: 15m)
+	InboundHealthAlertWebhook string        // Engineering chat webhook for silence alerts (required to run the checker)
+
+	// CRM webhooks: signed lead lifecycle events pushed to clinic endpoints.
+	CRMWebhookMaxAttempts  int           // Attempts before a delivery is dead-lettered (default: 8)
+	CRMWebhookRetryBackoff time.Duration // Base delay between attempts, doubled each retry (default: 30s)
+	CRMWebhookPollInterval time.Duration // How often the deliverer checks for due deliveries (default: 5s)
+
+	// Messaging costs: per-conversation SMS and LLM spend.
+	SMSCostPerSegmentUSD float64       // Estimate for sends the provider didn't price (default: 0.004)
+	CostRollupInterval   time.Duration // How often today's and yesterday's costs are rolled up (default: 1h)
+
+	// Live conversation stream for the operator portal (SSE).
+	ConversationStreamMaxPerOrg int           // Open streams allowed per clinic (default: 20)
+	ConversationStreamHeartbeat time.Duration // Keep-alive comment interval (default: 15s)
+
 	// S3 Archive Configuration (for archiving conversation data before purge)
 	S3ArchiveBucket string // S3 bucket for conversation archives (e.g., "medspa-conversation-archives")
 	S3ArchiveKMSKey string // Optional KMS key ID for SSE-KMS encryption
@@ -197,6 +275,33 @@ func (c *Config) SMSProviderIssues() []string {
 	return issues
 }
 
+// PaymentLinkBaseURL returns the base short payment links are built on: the
+// branded pay domain when configured, otherwise the API's /pay path. It is
+// empty when neither URL is set.
+func (c *Config) PaymentLinkBaseURL() string {
+	if base := strings.TrimRight(strings.TrimSpace(c.PayLinkBaseURL), "/"); base != "" {
+		return base
+	}
+	if base := strings.TrimRight(strings.TrimSpace(c.PublicBaseURL), "/"); base != "" {
+		return base + "/pay"
+	}
+	return ""
+}
+
+// PayLinkHost returns the branded pay domain's host, which serves short
+// links at its root, or "" when links go through the API's /pay path.
+func (c *Config) PayLinkHost() string {
+	raw := strings.TrimSpace(c.PayLinkBaseURL)
+	if raw == "" {
+		return ""
+	}
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" || strings.Trim(u.Path, "/") != "" {
+		return ""
+	}
+	return u.Hostname()
+}
+
 // Load reads configuration from environment variables
 func Load() *Config {
 	corsAllowedOrigins := []string{}
@@ -240,14 +345,34 @@ func Load() *Config {
 		Port:                            getEnv("PORT", "8080"),
 		Env:                             getEnv("ENV", "development"),
 		PublicBaseURL:                   getEnv("PUBLIC_BASE_URL", ""),
+		PayLinkBaseURL:                  getEnv("PAY_LINK_BASE_URL", ""),
 		LogLevel:                        getEnv("LOG_LEVEL", "info"),
 		CORSAllowedOrigins:              corsAllowedOrigins,
 		UseMemoryQueue:                  getEnvAsBool("USE_MEMORY_QUEUE", false),
 		WorkerCount:                     getEnvAsInt("WORKER_COUNT", 2),
+		WorkerOrgConcurrency:            getEnvAsInt("WORKER_ORG_CONCURRENCY", 3),
+		AvailabilityFetchConcurrency:    getEnvAsInt("AVAILABILITY_FETCH_CONCURRENCY", 2),
+		WorkerDrainTimeout:              getEnvAsDuration("WORKER_DRAIN_TIMEOUT", 25*time.Second),
+		WorkerMetricsPort:               strings.TrimSpace(getEnv("WORKER_METRICS_PORT", "9090")),
+		InboundBatchWindow:              getEnvAsDuration("INBOUND_BATCH_WINDOW", 8*time.Second),
+		ConversationLockTTL:             getEnvAsDuration("CONVERSATION_LOCK_TTL", 30*time.Second),
+		ConversationLockWait:            getEnvAsDuration("CONVERSATION_LOCK_WAIT", 5*time.Second),
+		ReplyLatencyBudget:              getEnvAsDuration("REPLY_LATENCY_BUDGET", 20*time.Second),
+		ProgressInitialDelay:            getEnvAsDuration("PROGRESS_INITIAL_DELAY", 3*time.Second),
+		ProgressMinInterval:             getEnvAsDuration("PROGRESS_MIN_INTERVAL", 10*time.Second),
+		FrustrationThreshold:            getEnvAsFloat("FRUSTRATION_THRESHOLD", 4),
+		FrustrationLLMClassifier:        getEnvAsBool("FRUSTRATION_LLM_CLASSIFIER", false),
 		DatabaseURL:                     getEnv("DATABASE_URL", ""),
 		PersistConversationHistory:      getEnvAsBool("PERSIST_CONVERSATION_HISTORY", false),
 		ConversationPersistExcludePhone: getEnv("CONVERSATION_PERSIST_EXCLUDE_PHONE", ""),
 		SMSProvider:                     strings.ToLower(strings.TrimSpace(getEnv("SMS_PROVIDER", "auto"))),
+		SMSFailoverNumbersJSON:          getEnv("SMS_FAILOVER_NUMBERS_JSON", ""),
+		SMSMaxSegments:                  getEnvAsInt("SMS_MAX_SEGMENTS", 3),
+		SMSMaxMessagesPerReply:          getEnvAsInt("SMS_MAX_MESSAGES_PER_REPLY", 3),
+		SMSSplitSpacing:                 getEnvAsDuration("SMS_SPLIT_SPACING", 750*time.Millisecond),
+		PIIEncryptionKeys:               getEnv("PII_ENCRYPTION_KEYS", ""),
+		PIIEncryptionActiveKey:          getEnv("PII_ENCRYPTION_ACTIVE_KEY", ""),
+		PIIHMACKey:                      getEnv("PII_HMAC_KEY", ""),
 		TelnyxAPIKey:                    getEnv("TELNYX_API_KEY", ""),
 		TelnyxMessagingProfileID:        getEnv("TELNYX_MESSAGING_PROFILE_ID", ""),
 		TelnyxWebhookSecret:             getEnv("TELNYX_WEBHOOK_SECRET", ""),
@@ -261,6 +386,12 @@ func Load() *Config {
 		TelnyxRetryMaxAttempts:          getEnvAsInt("TELNYX_RETRY_MAX_ATTEMPTS", 5),
 		TelnyxRetryBaseDelay:            getEnvAsDuration("TELNYX_RETRY_BASE_DELAY", 5*time.Minute),
 		TelnyxHostedPollInterval:        getEnvAsDuration("TELNYX_HOSTED_POLL_INTERVAL", 15*time.Minute),
+		BroadcastRatePerMinute:          getEnvAsInt("BROADCAST_RATE_PER_MINUTE", 60),
+		TelnyxConcatWindow:              getEnvAsDuration("TELNYX_CONCAT_WINDOW", 7*time.Second),
+		MaxInboundMessageChars:          getEnvAsInt("MAX_INBOUND_MESSAGE_CHARS", 1600),
+		ContextTokenBudget:              getEnvAsInt("CONTEXT_TOKEN_BUDGET", 2000),
+		HistoryWriteHeader:              getEnvAsBool("HISTORY_WRITE_HEADER", false),
+		PromptExperiments:               getEnv("PROMPT_EXPERIMENTS", ""),
 		TwilioAccountSID:                getEnv("TWILIO_ACCOUNT_SID", ""),
 		TwilioAuthToken:                 getEnv("TWILIO_AUTH_TOKEN", ""),
 		TwilioWebhookSecret:             getEnv("TWILIO_WEBHOOK_SECRET", ""),
@@ -281,6 +412,11 @@ func Load() *Config {
 		SquareSandbox:                   getEnvAsBool("SQUARE_SANDBOX", true),
 		SquareCheckoutMode:              getEnv("SQUARE_CHECKOUT_MODE", "auto"),
 		SquareCheckoutAllowFallback:     getEnvAsBool("SQUARE_CHECKOUT_ALLOW_FALLBACK", true),
+		PaymentReconcileInterval:        getEnvAsDuration("PAYMENT_RECONCILE_INTERVAL", 6*time.Hour),
+		PaymentReconcileLookback:        getEnvAsDuration("PAYMENT_RECONCILE_LOOKBACK", 72*time.Hour),
+		BookingOutcomePollInterval:      getEnvAsDuration("BOOKING_OUTCOME_POLL_INTERVAL", 15*time.Minute),
+		DigestTopLeads:                  getEnvAsInt("DIGEST_TOP_LEADS", 5),
+		TopLeadsDigestEnabled:           getEnvAsBool("TOP_LEADS_DIGEST_ENABLED", true),
 		StripeSecretKey:                 getEnv("STRIPE_SECRET_KEY", ""),
 		StripeWebhookSecret:             getEnv("STRIPE_WEBHOOK_SECRET", ""),
 		StripeConnectClientID:           getEnv("STRIPE_CONNECT_CLIENT_ID", ""),
@@ -293,6 +429,8 @@ func Load() *Config {
 		SandboxAutoPurgePhones:          getEnv("SANDBOX_AUTO_PURGE_PHONE_DIGITS", ""),
 		SandboxAutoPurgeDelay:           getEnvAsDuration("SANDBOX_AUTO_PURGE_DELAY", 0),
 		AdminJWTSecret:                  getEnv("ADMIN_JWT_SECRET", ""),
+		AdminJWTIssuer:                  getEnv("ADMIN_JWT_ISSUER", ""),
+		AdminJWTAudience:                getEnv("ADMIN_JWT_AUDIENCE", ""),
 		OnboardingToken:                 getEnv("ONBOARDING_TOKEN", ""),
 		QuietHoursStart:                 getEnv("QUIET_HOURS_START", ""),
 		QuietHoursEnd:                   getEnv("QUIET_HOURS_END", ""),
@@ -303,8 +441,11 @@ func Load() *Config {
 		AWSEndpointOverride:             getEnv("AWS_ENDPOINT_OVERRIDE", ""),
 		ConversationQueueURL:            getEnv("CONVERSATION_QUEUE_URL", ""),
 		ConversationJobsTable:           getEnv("CONVERSATION_JOBS_TABLE", "conversation_jobs"),
+		ConversationJobStaleAfter:       getEnvAsDuration("CONVERSATION_JOB_STALE_AFTER", time.Hour),
 		BedrockModelID:                  bedrockModel,
 		BedrockVoiceModelID:             getEnv("BEDROCK_VOICE_MODEL_ID", ""),
+		LLMEscalationModelID:            getEnv("LLM_ESCALATION_MODEL_ID", ""),
+		LLMEscalationMonthlyBudget:      getEnvAsInt("LLM_ESCALATION_MONTHLY_BUDGET", 500),
 		BedrockEmbeddingModelID:         getEnv("BEDROCK_EMBEDDING_MODEL_ID", ""),
 
 		// Gemini fallback configuration
@@ -366,6 +507,39 @@ func Load() *Config {
 		DisclaimerFirstOnly: getEnvAsBool("DISCLAIMER_FIRST_ONLY", true),
 		AuditRetentionDays:  getEnvAsInt("AUDIT_RETENTION_DAYS", 2555), // 7 years for HIPAA
 
+		WebhookEventRetentionDays: getEnvAsInt("WEBHOOK_EVENT_RETENTION_DAYS", 30),
+
+		DataRetentionPurgeEnabled: getEnvAsBool("DATA_RETENTION_PURGE_ENABLED", false),
+		DataRetentionDryRun:       getEnvAsBool("DATA_RETENTION_DRY_RUN", false),
+		DataRetentionInterval:     getEnvAsDuration("DATA_RETENTION_INTERVAL", 24*time.Hour),
+		DataRetentionBatchSize:    getEnvAsInt("DATA_RETENTION_BATCH_SIZE", 100),
+
+		ColdStorageBucket:    getEnv("COLD_STORAGE_BUCKET", ""),
+		ColdStorageEnabled:   getEnvAsBool("COLD_STORAGE_ENABLED", false),
+		ColdStorageMaxAge:    getEnvAsDuration("COLD_STORAGE_MAX_AGE", 90*24*time.Hour),
+		ColdStorageInterval:  getEnvAsDuration("COLD_STORAGE_INTERVAL", 24*time.Hour),
+		ColdStorageBatchSize: getEnvAsInt("COLD_STORAGE_BATCH_SIZE", 100),
+		ColdStorageCacheTTL:  getEnvAsDuration("COLD_STORAGE_CACHE_TTL", 5*time.Minute),
+
+		AvailabilityHealthEnabled:      getEnvAsBool("AVAILABILITY_HEALTH_ENABLED", false),
+		AvailabilityHealthInterval:     getEnvAsDuration("AVAILABILITY_HEALTH_INTERVAL", 6*time.Hour),
+		AvailabilityHealthProbeGap:     getEnvAsDuration("AVAILABILITY_HEALTH_PROBE_GAP", 2*time.Second),
+		AvailabilityHealthAlertWebhook: getEnv("AVAILABILITY_HEALTH_ALERT_WEBHOOK", ""),
+
+		InboundHealthEnabled:      getEnvAsBool("INBOUND_HEALTH_ENABLED", false),
+		InboundHealthInterval:     getEnvAsDuration("INBOUND_HEALTH_INTERVAL", 15*time.Minute),
+		InboundHealthAlertWebhook: getEnv("INBOUND_HEALTH_ALERT_WEBHOOK", ""),
+
+		CRMWebhookMaxAttempts:  getEnvAsInt("CRM_WEBHOOK_MAX_ATTEMPTS", 8),
+		CRMWebhookRetryBackoff: getEnvAsDuration("CRM_WEBHOOK_RETRY_BACKOFF", 30*time.Second),
+		CRMWebhookPollInterval: getEnvAsDuration("CRM_WEBHOOK_POLL_INTERVAL", 5*time.Second),
+
+		SMSCostPerSegmentUSD: getEnvAsFloat("SMS_COST_PER_SEGMENT_USD", 0.004),
+		CostRollupInterval:   getEnvAsDuration("COST_ROLLUP_INTERVAL", time.Hour),
+
+		ConversationStreamMaxPerOrg: getEnvAsInt("CONVERSATION_STREAM_MAX_PER_ORG", 20),
+		ConversationStreamHeartbeat: getEnvAsDuration("CONVERSATION_STREAM_HEARTBEAT", 15*time.Second),
+
 		// S3 Archive Configuration
 		S3ArchiveBucket: getEnv("S3_ARCHIVE_BUCKET", ""),
 		S3ArchiveKMSKey: getEnv("S3_ARCHIVE_KMS_KEY", ""),
@@ -78,3 +78,25 @@ func TestSMSProviderIssues_BothProviders(t *testing.T) {
 		t.Fatalf("expected no issues with both providers, got: %v", issues)
 	}
 }
+
+func TestPaymentLinkBaseURL(t *testing.T) {
+	tests := []struct {
+		cfg      Config
+		wantBase string
+		wantHost string
+	}{
+		{Config{}, "", ""},
+		{Config{PublicBaseURL: "https://api.example.com/"}, "https://api.example.com/pay", ""},
+		{Config{PublicBaseURL: "https://api.example.com", PayLinkBaseURL: "https://pay.example.com/"}, "https://pay.example.com", "pay.example.com"},
+		// A branded base with a path is used as-is but isn't served at the host root.
+		{Config{PayLinkBaseURL: "https://links.example.com/pay"}, "https://links.example.com/pay", ""},
+	}
+	for _, tt := range tests {
+		if got := tt.cfg.PaymentLinkBaseURL(); got != tt.wantBase {
+			t.Errorf("PaymentLinkBaseURL(%+v) = %q, want %q", tt.cfg, got, tt.wantBase)
+		}
+		if got := tt.cfg.PayLinkHost(); got != tt.wantHost {
+			t.Errorf("PayLinkHost(%+v) = %q, want %q", tt.cfg, got, tt.wantHost)
+		}
+	}
+}
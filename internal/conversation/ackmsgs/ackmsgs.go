@@ -0,0 +1,82 @@
+// Package ackmsgs holds the canonical instant-ack texts, the short "got it"
+// replies sent while the AI reply is prepared. The senders pick from these
+// lists and the E2E scripts detect acks with them, so the two can't drift.
+package ackmsgs
+
+import (
+	"hash/fnv"
+	"math/rand"
+	"strings"
+)
+
+// First are the default acks for a patient's first inbound message.
+// The medical advice disclaimer was dropped from these: it was off-putting
+// on booking requests like "I want Botox", and the LLM handles medical
+// deflection when needed.
+var First = []string{
+	"Got it - give me a moment to help you.",
+	"Thanks for reaching out - one moment while I check.",
+	"Thanks! Give me a second to look that up.",
+	"Got it! Let me check on that.",
+}
+
+// FollowUp are the default acks for later messages, varied to feel human.
+var FollowUp = []string{
+	"Thanks - one moment...",
+	"Got it. One sec.",
+	"On it - just a moment.",
+	"Checking now...",
+	"Give me a second...",
+}
+
+// All returns every default ack.
+func All() []string {
+	out := make([]string, 0, len(First)+len(FollowUp))
+	out = append(out, First...)
+	return append(out, FollowUp...)
+}
+
+// Pick chooses one of variants for the message identified by key. The same
+// key always gets the same variant, so a redelivered webhook doesn't text a
+// different ack; an empty key picks at random. Returns "" for no variants.
+func Pick(variants []string, key string) string {
+	if len(variants) == 0 {
+		return ""
+	}
+	if key == "" {
+		return variants[rand.Intn(len(variants))]
+	}
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(key))
+	return variants[int(h.Sum32()%uint32(len(variants)))]
+}
+
+// Is reports whether message is exactly one of the default acks.
+func Is(message string) bool {
+	if message == "" {
+		return false
+	}
+	for _, ack := range All() {
+		if message == ack {
+			return true
+		}
+	}
+	return false
+}
+
+// LooksLike reports whether a transcript message starts like a default ack,
+// ignoring case and trailing punctuation. Used by the E2E checks, whose
+// transcripts may carry acks with text appended.
+func LooksLike(content string) bool {
+	lower := strings.ToLower(strings.TrimSpace(content))
+	if lower == "" {
+		return false
+	}
+	for _, ack := range All() {
+		prefix := strings.TrimRight(strings.ToLower(ack), ".!")
+		if strings.HasPrefix(lower, prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,53 @@
+package ackmsgs
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestPickIsStablePerKey(t *testing.T) {
+	for i := 0; i < 20; i++ {
+		key := fmt.Sprintf("SM%d", i)
+		if Pick(First, key) != Pick(First, key) {
+			t.Fatalf("Pick(%q) changed between calls", key)
+		}
+	}
+	if Pick(nil, "SM1") != "" {
+		t.Fatal("expected no ack without variants")
+	}
+}
+
+func TestPickRotatesAcrossMessages(t *testing.T) {
+	seen := map[string]bool{}
+	for i := 0; i < 200; i++ {
+		ack := Pick(First, fmt.Sprintf("msg-%d", i))
+		if !Is(ack) {
+			t.Fatalf("Pick returned %q, not a default ack", ack)
+		}
+		seen[ack] = true
+	}
+	if len(seen) != len(First) {
+		t.Fatalf("expected every variant used across messages, got %d of %d", len(seen), len(First))
+	}
+	if !Is(Pick(FollowUp, "")) {
+		t.Fatal("random pick must come from the variants")
+	}
+}
+
+// The E2E scripts detect acks with LooksLike, so every ack the senders can
+// pick must match it, with or without text appended.
+func TestLooksLikeMatchesEveryDefaultAck(t *testing.T) {
+	for _, ack := range All() {
+		if !Is(ack) || !LooksLike(ack) {
+			t.Errorf("%q not recognized", ack)
+		}
+		if !LooksLike("  " + ack + " I'll be right with you.") {
+			t.Errorf("%q with appended text not recognized", ack)
+		}
+	}
+	for _, msg := range []string{"", "Botox is $12/unit. Want to book?", "Thanks for booking with us!"} {
+		if LooksLike(msg) || Is(msg) {
+			t.Errorf("%q mistaken for an ack", msg)
+		}
+	}
+}
@@ -0,0 +1,141 @@
+package conversation
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	miniredis "github.com/alicebob/miniredis/v2"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/clinic"
+	moxieclient "github.com/wolfman30/medspa-ai-platform/internal/emr/moxie"
+	"github.com/wolfman30/medspa-ai-platform/internal/events"
+	"github.com/wolfman30/medspa-ai-platform/internal/leads"
+	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
+)
+
+func lateThursdayClinic() *clinic.Config {
+	return &clinic.Config{
+		OrgID:                  "org-1",
+		Name:                   "Glow Med Spa",
+		Timezone:               "America/New_York",
+		BusinessHours:          clinic.BusinessHours{Thursday: &clinic.DayHours{Open: "10:00", Close: "21:00"}},
+		ServiceDurationMinutes: map[string]int{"microneedling": 75},
+	}
+}
+
+func TestPresentedMoxieSlots_FiltersSlotsRunningPastClose(t *testing.T) {
+	cfg := lateThursdayClinic()
+	result := &moxieclient.AvailabilityResult{Dates: []moxieclient.DateSlots{{
+		Date: "2026-02-26",
+		Slots: []moxieclient.TimeSlot{
+			{Start: "2026-02-26T18:00:00-05:00", End: "2026-02-26T18:30:00-05:00"},
+			{Start: "2026-02-26T19:45:00-05:00", End: "2026-02-26T20:15:00-05:00"},
+			{Start: "2026-02-26T20:00:00-05:00", End: "2026-02-26T20:30:00-05:00"},
+			{Start: "2026-02-26T20:30:00-05:00", End: "2026-02-26T21:00:00-05:00"},
+		},
+	}}}
+
+	slots := presentedMoxieSlots(cfg, result, "Microneedling", TimePreferences{})
+	if len(slots) != 2 {
+		t.Fatalf("expected the 8:00 and 8:30 PM slots to be dropped, got %+v", slots)
+	}
+	last := slots[1]
+	if last.DateTime.Format("15:04") != "19:45" || last.EndDateTime.Format("15:04") != "21:00" {
+		t.Fatalf("expected the 7:45 PM slot to end at 9:00 PM, got %s-%s", last.DateTime.Format("15:04"), last.EndDateTime.Format("15:04"))
+	}
+
+	// Without a configured duration, Moxie's own 30-minute slots all fit.
+	if slots := presentedMoxieSlots(cfg, result, "Tox", TimePreferences{}); len(slots) != 4 {
+		t.Fatalf("expected all 30-minute slots to be kept, got %d", len(slots))
+	}
+}
+
+func TestFormatAppointmentWindow(t *testing.T) {
+	loc, _ := time.LoadLocation("America/New_York")
+	tests := []struct {
+		start, end time.Time
+		want       string
+	}{
+		{time.Date(2026, 2, 26, 19, 45, 0, 0, loc), time.Date(2026, 2, 26, 21, 0, 0, 0, loc), "Thu Feb 26, 7:45–9:00 PM"},
+		{time.Date(2026, 2, 26, 11, 30, 0, 0, loc), time.Date(2026, 2, 26, 12, 45, 0, 0, loc), "Thu Feb 26, 11:30 AM–12:45 PM"},
+		{time.Date(2026, 2, 26, 10, 0, 0, 0, loc), time.Time{}, "Thu Feb 26, 10:00 AM"},
+	}
+	for _, tt := range tests {
+		if got := FormatAppointmentWindow(tt.start, tt.end); got != tt.want {
+			t.Errorf("FormatAppointmentWindow(%s, %s) = %q, want %q", tt.start, tt.end, got, tt.want)
+		}
+	}
+
+	start := time.Date(2026, 2, 26, 19, 45, 0, 0, loc)
+	msg := FormatAppointmentConfirmationWindow("Microneedling", start, start.Add(75*time.Minute), "Glow Med Spa")
+	if !strings.Contains(msg, "📅 Thu Feb 26, 7:45–9:00 PM EST") {
+		t.Fatalf("expected the end time in the confirmation, got:\n%s", msg)
+	}
+}
+
+type durationBookingConfirmer struct {
+	mu       sync.Mutex
+	duration int
+	calls    int
+}
+
+func (d *durationBookingConfirmer) ConfirmBooking(ctx context.Context, orgID uuid.UUID, leadID uuid.UUID, scheduledFor *time.Time) error {
+	return d.ConfirmBookingWithDuration(ctx, orgID, leadID, scheduledFor, 0)
+}
+
+func (d *durationBookingConfirmer) ConfirmBookingWithDuration(ctx context.Context, orgID uuid.UUID, leadID uuid.UUID, scheduledFor *time.Time, durationMinutes int) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.calls++
+	d.duration = durationMinutes
+	return nil
+}
+
+func TestWorkerPaymentEvent_RecordsBookingDuration(t *testing.T) {
+	ctx := context.Background()
+	mr := miniredis.RunT(t)
+	clinicStore := clinic.NewStore(redis.NewClient(&redis.Options{Addr: mr.Addr()}))
+	cfg := lateThursdayClinic()
+	cfg.OrgID = uuid.NewString()
+	if err := clinicStore.Set(ctx, cfg); err != nil {
+		t.Fatalf("save clinic config: %v", err)
+	}
+	repo := leads.NewInMemoryRepository()
+	lead, err := repo.Create(ctx, &leads.CreateLeadRequest{OrgID: cfg.OrgID, Phone: "+19998887777", Source: "sms"})
+	if err != nil {
+		t.Fatalf("create lead: %v", err)
+	}
+
+	bookings := &durationBookingConfirmer{}
+	messenger := &stubMessenger{}
+	worker := NewWorker(&recordingService{}, newScriptedQueue(), &stubJobUpdater{}, messenger, bookings, logging.Default(),
+		WithClinicConfigStore(clinicStore), WithWorkerLeadsRepo(repo))
+
+	loc, _ := time.LoadLocation(cfg.Timezone)
+	scheduled := time.Date(2026, 2, 26, 19, 45, 0, 0, loc).UTC()
+	evt := events.PaymentSucceededV1{
+		EventID:      "evt-duration",
+		OrgID:        cfg.OrgID,
+		LeadID:       lead.ID,
+		ProviderRef:  "pay-duration",
+		LeadPhone:    "+19998887777",
+		FromNumber:   "+15550000000",
+		AmountCents:  5000,
+		ScheduledFor: &scheduled,
+		ServiceName:  "Microneedling",
+	}
+	if err := worker.handlePaymentEvent(ctx, &evt); err != nil {
+		t.Fatalf("handlePaymentEvent: %v", err)
+	}
+	if bookings.calls != 1 || bookings.duration != 75 {
+		t.Fatalf("expected one booking with a 75 minute duration, got calls=%d duration=%d", bookings.calls, bookings.duration)
+	}
+	if got := messenger.lastReply().Body; !strings.Contains(got, "Thu Feb 26, 7:45–9:00 PM EST") {
+		t.Fatalf("expected the confirmation to include the end time, got %q", got)
+	}
+}
@@ -0,0 +1,96 @@
+package conversation
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// areaCodeTimezones maps US area codes outside the Eastern zone, plus the
+// Eastern codes most common among our clinics, to an IANA timezone. Codes
+// not listed are treated as unknown.
+var areaCodeTimezones = func() map[string]string {
+	zones := map[string][]string{
+		"America/New_York": {
+			"201", "202", "203", "207", "212", "215", "216", "239", "267", "301", "302", "305", "321", "330",
+			"347", "352", "404", "407", "410", "412", "443", "516", "518", "561", "585", "607", "610", "614",
+			"617", "631", "646", "678", "703", "704", "716", "718", "727", "732", "754", "757", "770", "772",
+			"781", "786", "804", "813", "845", "856", "860", "904", "908", "914", "917", "919", "954", "973",
+		},
+		"America/Chicago": {
+			"205", "210", "214", "217", "224", "225", "251", "254", "262", "281", "309", "312", "314", "316",
+			"318", "319", "320", "334", "346", "361", "402", "405", "409", "414", "430", "432", "469", "479",
+			"501", "504", "507", "512", "515", "563", "573", "601", "608", "612", "615", "618", "630", "636",
+			"641", "651", "662", "682", "708", "713", "715", "731", "737", "763", "773", "815", "816", "817",
+			"830", "832", "847", "901", "903", "913", "918", "920", "936", "940", "952", "956", "972", "979",
+		},
+		"America/Denver": {
+			"303", "307", "385", "406", "435", "505", "575", "719", "720", "801", "970", "986",
+		},
+		"America/Phoenix": {
+			"480", "520", "602", "623", "928",
+		},
+		"America/Los_Angeles": {
+			"206", "209", "213", "253", "310", "323", "360", "408", "415", "424", "425", "442", "503", "509",
+			"510", "530", "541", "559", "562", "619", "626", "628", "650", "657", "661", "669", "702", "707",
+			"714", "725", "747", "760", "775", "805", "818", "831", "858", "909", "916", "925", "949", "971",
+		},
+		"America/Anchorage": {"907"},
+		"Pacific/Honolulu":  {"808"},
+	}
+	out := make(map[string]string)
+	for zone, codes := range zones {
+		for _, code := range codes {
+			out[code] = zone
+		}
+	}
+	return out
+}()
+
+// timezoneForPhone infers a lead's timezone from a US phone number's area
+// code. Returns "" when the number isn't a recognised US number.
+func timezoneForPhone(phone string) string {
+	var digits strings.Builder
+	for _, r := range phone {
+		if r >= '0' && r <= '9' {
+			digits.WriteRune(r)
+		}
+	}
+	d := digits.String()
+	if len(d) == 11 && d[0] == '1' {
+		d = d[1:]
+	}
+	if len(d) != 10 {
+		return ""
+	}
+	return areaCodeTimezones[d[:3]]
+}
+
+// withTimezoneNote adds an explicit timezone line to a slot list when the
+// lead's area code suggests they're in a different zone than the clinic.
+// Slots must already be in clinic-local time.
+func withTimezoneNote(msg string, slots []PresentedSlot, leadPhone string) string {
+	if len(slots) == 0 {
+		return msg
+	}
+	leadTZ := timezoneForPhone(leadPhone)
+	if leadTZ == "" {
+		return msg
+	}
+	leadLoc, err := time.LoadLocation(leadTZ)
+	if err != nil {
+		return msg
+	}
+	at := slots[0].DateTime
+	_, clinicOffset := at.Zone()
+	_, leadOffset := at.In(leadLoc).Zone()
+	if clinicOffset == leadOffset {
+		return msg
+	}
+	note := fmt.Sprintf("🕒 All times are %s (the clinic's local time).", at.Format("MST"))
+	const replyPrompt = "\nJust reply with the number that works best!"
+	if strings.HasSuffix(msg, replyPrompt) {
+		return strings.TrimSuffix(msg, replyPrompt) + "\n" + note + "\n" + replyPrompt
+	}
+	return msg + "\n\n" + note
+}
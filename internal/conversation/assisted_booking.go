@@ -0,0 +1,94 @@
+package conversation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/clinic"
+	"github.com/wolfman30/medspa-ai-platform/internal/leads"
+	"github.com/wolfman30/medspa-ai-platform/internal/messaging/templates"
+)
+
+// assistedBookingLeadSource tags leads created by clinic staff over the phone.
+const assistedBookingLeadSource = "staff_assisted"
+
+// AssistedBookingRequest is a booking clinic staff enter on a patient's
+// behalf. Without a Slot the job only searches availability; with one it
+// books that time through the same deposit and Moxie path as SMS bookings.
+type AssistedBookingRequest struct {
+	OrgID          string        `json:"org_id"`
+	ConversationID string        `json:"conversation_id"`
+	Phone          string        `json:"phone"` // patient phone, E.164
+	Name           string        `json:"name,omitempty"`
+	Email          string        `json:"email,omitempty"`
+	Service        string        `json:"service"`
+	Provider       string        `json:"provider,omitempty"`
+	PreferredDays  string        `json:"preferred_days,omitempty"`
+	PreferredTimes string        `json:"preferred_times,omitempty"`
+	Slot           *AssistedSlot `json:"slot,omitempty"`
+	RequestedBy    string        `json:"requested_by,omitempty"`
+}
+
+// AssistedSlot is the appointment time staff picked.
+type AssistedSlot struct {
+	Start time.Time  `json:"start"`
+	End   *time.Time `json:"end,omitempty"`
+}
+
+// AssistedAvailabilitySearcher runs the SMS flow's availability search for a
+// staff-entered service and preferences.
+type AssistedAvailabilitySearcher interface {
+	SearchAssistedAvailability(ctx context.Context, req AssistedBookingRequest) (*TimeSelectionResponse, error)
+}
+
+// SearchAssistedAvailability fetches slots for a staff-assisted booking. Only
+// staff see them, so no time selection state is saved: the patient's next
+// numeric reply must not book a slot they were never sent.
+func (s *LLMService) SearchAssistedAvailability(ctx context.Context, req AssistedBookingRequest) (*TimeSelectionResponse, error) {
+	if s.clinicStore == nil {
+		return nil, errors.New("conversation: assisted booking: clinic store not configured")
+	}
+	cfg, err := s.clinicStore.Get(ctx, req.OrgID)
+	if err != nil {
+		return nil, fmt.Errorf("conversation: assisted booking: load clinic config: %w", err)
+	}
+	if cfg == nil || cfg.BookingURL == "" {
+		return nil, errors.New("conversation: assisted booking: clinic has no booking url")
+	}
+
+	prefs := leads.SchedulingPreferences{
+		Name:               req.Name,
+		ServiceInterest:    req.Service,
+		PreferredDays:      req.PreferredDays,
+		PreferredTimes:     req.PreferredTimes,
+		ProviderPreference: req.Provider,
+	}
+	tsr := s.fetchAvailability(ctx, &prefs, cfg, cfg.BookingURL, req.ConversationID, req.OrgID, "", req.Phone, nil)
+	if tsr == nil {
+		return nil, errors.New("conversation: assisted booking: no availability response")
+	}
+	return tsr, nil
+}
+
+// assistedBookingIntro returns the patient-facing text that opens a
+// staff-started booking, so the patient knows why the deposit link that
+// follows arrived.
+func assistedBookingIntro(cfg *clinic.Config, name, service string, start time.Time) templates.Rendered {
+	first, _ := splitName(name)
+	if first == "" {
+		first = "there"
+	}
+	clinicName := "the clinic"
+	if cfg != nil && strings.TrimSpace(cfg.Name) != "" {
+		clinicName = cfg.Name
+	}
+	return cfg.RenderTemplate(templates.BookingAssistedIntro, templates.Params{
+		"first_name":  first,
+		"clinic_name": clinicName,
+		"service":     service,
+		"time":        start.Format("Mon Jan 2 at 3:04 PM"),
+	})
+}
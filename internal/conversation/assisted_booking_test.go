@@ -0,0 +1,273 @@
+package conversation
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-chi/chi/v5"
+	"github.com/redis/go-redis/v9"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/clinic"
+	"github.com/wolfman30/medspa-ai-platform/internal/leads"
+	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
+)
+
+const assistedConvID = "sms:org-1:15551234567"
+
+type assistedEnqueuer struct {
+	stubEnqueuer
+	jobIDs   []string
+	requests []AssistedBookingRequest
+}
+
+func (s *assistedEnqueuer) EnqueueAssistedBooking(ctx context.Context, jobID string, req AssistedBookingRequest) error {
+	s.jobIDs = append(s.jobIDs, jobID)
+	s.requests = append(s.requests, req)
+	return nil
+}
+
+// assistedSearchService is a conversation service with a stubbed assisted
+// availability search.
+type assistedSearchService struct {
+	replyService
+	tsr   *TimeSelectionResponse
+	calls []AssistedBookingRequest
+}
+
+func (s *assistedSearchService) SearchAssistedAvailability(ctx context.Context, req AssistedBookingRequest) (*TimeSelectionResponse, error) {
+	s.calls = append(s.calls, req)
+	return s.tsr, nil
+}
+
+func assistedRouter(h *Handler) http.Handler {
+	r := chi.NewRouter()
+	r.Post("/admin/orgs/{orgID}/bookings/assisted", h.AssistedBooking)
+	r.Get("/admin/orgs/{orgID}/bookings/assisted/{jobID}", h.AssistedBookingStatus)
+	return r
+}
+
+func postAssisted(t *testing.T, h *Handler, body map[string]any) *httptest.ResponseRecorder {
+	t.Helper()
+	raw, err := json.Marshal(body)
+	if err != nil {
+		t.Fatalf("marshal body: %v", err)
+	}
+	rec := httptest.NewRecorder()
+	assistedRouter(h).ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/admin/orgs/org-1/bookings/assisted", bytes.NewReader(raw)))
+	return rec
+}
+
+func newAssistedWorker(t *testing.T, cfg *clinic.Config, service Service) (*Worker, *leads.InMemoryRepository, *stubMessenger, *stubDepositSender) {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	clinicStore := clinic.NewStore(redis.NewClient(&redis.Options{Addr: mr.Addr()}))
+	if err := clinicStore.Set(context.Background(), cfg); err != nil {
+		t.Fatalf("save clinic config: %v", err)
+	}
+	repo := leads.NewInMemoryRepository()
+	messenger := &stubMessenger{}
+	deposits := &stubDepositSender{}
+	worker := NewWorker(service, newScriptedQueue(), &stubJobUpdater{}, messenger, nil, logging.Default(),
+		WithClinicConfigStore(clinicStore), WithWorkerLeadsRepo(repo), WithDepositSender(deposits))
+	return worker, repo, messenger, deposits
+}
+
+func assistedClinic() *clinic.Config {
+	cfg := clinic.DefaultConfig("org-1")
+	cfg.Name = "Glow Spa"
+	cfg.Timezone = "America/New_York"
+	cfg.SMSPhoneNumber = "+15556667777"
+	cfg.DepositAmountCents = 5000
+	return cfg
+}
+
+func TestAssistedBooking_ValidatesRequest(t *testing.T) {
+	enqueuer := &assistedEnqueuer{}
+	h := NewHandler(enqueuer, &stubJobStore{}, nil, nil, logging.Default())
+
+	for name, body := range map[string]map[string]any{
+		"missing phone":   {"service": "Botox"},
+		"missing service": {"phone": "555-123-4567"},
+		"past slot":       {"phone": "555-123-4567", "service": "Botox", "slot": map[string]any{"start": time.Now().Add(-time.Hour)}},
+	} {
+		if rec := postAssisted(t, h, body); rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected 400, got %d", name, rec.Code)
+		}
+	}
+	if len(enqueuer.requests) != 0 {
+		t.Fatalf("expected nothing enqueued, got %d", len(enqueuer.requests))
+	}
+
+	noAssist := NewHandler(&stubEnqueuer{}, &stubJobStore{}, nil, nil, logging.Default())
+	if rec := postAssisted(t, noAssist, map[string]any{"phone": "5551234567", "service": "Botox"}); rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503 without an assisted booking enqueuer, got %d", rec.Code)
+	}
+}
+
+func TestAssistedBooking_SearchThenBook(t *testing.T) {
+	ctx := context.Background()
+	enqueuer := &assistedEnqueuer{}
+	jobs := &stubJobStore{}
+	h := NewHandler(enqueuer, jobs, nil, nil, logging.Default())
+
+	// Step 1: staff search with the patient's preferences.
+	rec := postAssisted(t, h, map[string]any{
+		"phone": "(555) 123-4567", "name": "Jane Doe", "email": "jane@example.com",
+		"service": "Botox", "preferred_days": "tuesday", "preferred_times": "afternoon",
+	})
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("search: expected 202, got %d: %s", rec.Code, rec.Body.String())
+	}
+	search := enqueuer.requests[0]
+	if search.ConversationID != assistedConvID || search.Phone != "+15551234567" || search.Slot != nil || search.PreferredDays != "tuesday" {
+		t.Fatalf("unexpected search request: %+v", search)
+	}
+
+	loc, _ := time.LoadLocation("America/New_York")
+	slotStart := time.Now().In(loc).AddDate(0, 0, 7).Truncate(time.Hour)
+	service := &assistedSearchService{tsr: &TimeSelectionResponse{
+		Slots: []PresentedSlot{{
+			Index: 1, DateTime: slotStart, EndDateTime: slotStart.Add(30 * time.Minute),
+			TimeStr: slotStart.Format("Mon Jan 2 at 3:04 PM"), Service: "Botox", Available: true,
+		}},
+		Service: "Botox",
+	}}
+	worker, repo, messenger, deposits := newAssistedWorker(t, assistedClinic(), service)
+
+	resp, err := worker.handleAssistedBooking(ctx, &search)
+	if err != nil {
+		t.Fatalf("search job: %v", err)
+	}
+	if len(service.calls) != 1 || messenger.wasCalled() || deposits.called {
+		t.Fatal("a search should only fetch slots, never text the patient")
+	}
+
+	// Staff poll the search job and read back the slots.
+	jobs.getJob = &JobRecord{JobID: enqueuer.jobIDs[0], Status: JobStatusCompleted, RequestType: jobTypeAssistedBooking, ConversationID: assistedConvID, Response: resp}
+	statusRec := httptest.NewRecorder()
+	assistedRouter(h).ServeHTTP(statusRec, httptest.NewRequest(http.MethodGet, "/admin/orgs/org-1/bookings/assisted/"+enqueuer.jobIDs[0], nil))
+	if statusRec.Code != http.StatusOK {
+		t.Fatalf("status: expected 200, got %d", statusRec.Code)
+	}
+	var status assistedBookingStatus
+	if err := json.Unmarshal(statusRec.Body.Bytes(), &status); err != nil {
+		t.Fatalf("decode status: %v", err)
+	}
+	if len(status.Slots) != 1 || !status.Slots[0].Start.Equal(slotStart) {
+		t.Fatalf("expected the found slot, got %+v", status)
+	}
+	otherOrg := httptest.NewRecorder()
+	assistedRouter(h).ServeHTTP(otherOrg, httptest.NewRequest(http.MethodGet, "/admin/orgs/org-2/bookings/assisted/"+enqueuer.jobIDs[0], nil))
+	if otherOrg.Code != http.StatusNotFound {
+		t.Fatalf("expected another org's job to be hidden, got %d", otherOrg.Code)
+	}
+
+	// Step 2: staff book the slot they picked.
+	rec = postAssisted(t, h, map[string]any{
+		"phone": "+15551234567", "service": "Botox",
+		"slot": map[string]any{"start": status.Slots[0].Start, "end": status.Slots[0].End},
+	})
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("book: expected 202, got %d: %s", rec.Code, rec.Body.String())
+	}
+	book := enqueuer.requests[1]
+	if _, err := worker.handleAssistedBooking(ctx, &book); err != nil {
+		t.Fatalf("book job: %v", err)
+	}
+
+	lead, err := repo.GetOrCreateByPhone(ctx, "org-1", "+15551234567", "sms", "")
+	if err != nil {
+		t.Fatalf("load lead: %v", err)
+	}
+	if lead.Name != "Jane Doe" || lead.Email != "jane@example.com" || lead.Source != assistedBookingLeadSource {
+		t.Fatalf("expected the lead staff entered, got %+v", lead)
+	}
+	if lead.SelectedDateTime == nil || !lead.SelectedDateTime.Equal(slotStart) || lead.SelectedService != "Botox" {
+		t.Fatalf("expected the slot saved on the lead, got %v %q", lead.SelectedDateTime, lead.SelectedService)
+	}
+	intro := messenger.lastReply()
+	if !strings.Contains(intro.Body, "Hi Jane! The team at Glow Spa started your booking for Botox") || intro.From != "+15556667777" || intro.To != "+15551234567" {
+		t.Fatalf("unexpected intro sms: %+v", intro)
+	}
+	if !deposits.called || deposits.lastMsg.LeadID != lead.ID || deposits.lastMsg.ConversationID != assistedConvID {
+		t.Fatalf("expected the deposit sent through the deposit sender, got %+v", deposits.lastMsg)
+	}
+	intent := deposits.lastRes.DepositIntent
+	if intent.AmountCents != 5000 || intent.Service != "Botox" || intent.ScheduledFor == nil || !intent.ScheduledFor.Equal(slotStart) {
+		t.Fatalf("unexpected deposit intent: %+v", intent)
+	}
+}
+
+func TestAssistedBooking_DirectSlotForMoxieClinic(t *testing.T) {
+	ctx := context.Background()
+	cfg := assistedClinic()
+	cfg.BookingPlatform = "moxie"
+	cfg.PaymentProvider = "stripe"
+	cfg.BookingURL = "https://app.joinmoxie.com/booking/glow"
+	worker, repo, messenger, deposits := newAssistedWorker(t, cfg, &replyService{})
+
+	loc, _ := time.LoadLocation(cfg.Timezone)
+	start := time.Date(2030, 3, 12, 14, 30, 0, 0, loc)
+	_, err := worker.handleAssistedBooking(ctx, &AssistedBookingRequest{
+		OrgID:          "org-1",
+		ConversationID: assistedConvID,
+		Phone:          "+15551234567",
+		Name:           "Jane Doe",
+		Service:        "Botox",
+		Slot:           &AssistedSlot{Start: start.UTC()},
+	})
+	if err != nil {
+		t.Fatalf("book job: %v", err)
+	}
+
+	lead, _ := repo.GetOrCreateByPhone(ctx, "org-1", "+15551234567", "sms", "")
+	if lead.SelectedDateTime == nil || !lead.SelectedDateTime.Equal(start) {
+		t.Fatalf("expected the slot on the lead for Moxie writeback, got %v", lead.SelectedDateTime)
+	}
+	if !messenger.wasCalled() {
+		t.Fatal("expected the intro sms")
+	}
+	if !deposits.called {
+		t.Fatal("expected the Stripe deposit path")
+	}
+	intent := deposits.lastRes.DepositIntent
+	if intent.ScheduledFor == nil || !intent.ScheduledFor.Equal(start) || intent.Description != "Botox - Tue Mar 12 at 2:30 PM" {
+		t.Fatalf("unexpected deposit intent: %+v", intent)
+	}
+}
+
+func TestSearchAssistedAvailabilityDoesNotSaveTimeSelection(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	clinicStore := clinic.NewStore(client)
+	cfg := &clinic.Config{OrgID: "org-1", Name: "Glow Spa", Timezone: "America/New_York", BookingURL: "https://app.joinmoxie.com/booking/glow"}
+	if err := clinicStore.Set(context.Background(), cfg); err != nil {
+		t.Fatalf("save clinic config: %v", err)
+	}
+	router := NewAvailabilityRouter(logging.Default(), &stubAvailabilitySource{name: clinic.AvailabilitySourceMoxieAPI, slots: 3})
+	svc := NewLLMService(&stubLLMClient{}, client, nil, "model", logging.Default(), WithClinicStore(clinicStore), WithAvailabilityRouter(router))
+
+	tsr, err := svc.SearchAssistedAvailability(context.Background(), AssistedBookingRequest{
+		OrgID: "org-1", ConversationID: assistedConvID, Phone: "+15551234567", Name: "Jane Doe", Service: "Botox",
+	})
+	if err != nil {
+		t.Fatalf("search: %v", err)
+	}
+	if len(tsr.Slots) != 3 {
+		t.Fatalf("expected 3 slots for staff, got %d", len(tsr.Slots))
+	}
+	state, err := svc.history.LoadTimeSelectionState(context.Background(), assistedConvID)
+	if err != nil {
+		t.Fatalf("load time selection state: %v", err)
+	}
+	if state != nil {
+		t.Fatalf("expected no time selection state for unsent slots, got %+v", state)
+	}
+}
@@ -0,0 +1,170 @@
+package conversation
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"mime"
+	"path"
+	"strings"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/clinic"
+	"github.com/wolfman30/medspa-ai-platform/pkg/phone"
+)
+
+// MetadataAttachments carries the inbound message's attachments, JSON
+// encoded, on the conversation job.
+const MetadataAttachments = "attachments"
+
+// Attachment is one MMS attachment on an inbound message.
+type Attachment struct {
+	URL         string `json:"url"`
+	ContentType string `json:"content_type,omitempty"`
+	// Kind is one of clinic.AttachmentImage, AttachmentVCard, AttachmentPDF
+	// or AttachmentUnknown.
+	Kind string `json:"kind"`
+}
+
+// attachmentExtensions classifies attachments the carrier sent without a
+// content type.
+var attachmentExtensions = map[string]string{
+	".jpg":  clinic.AttachmentImage,
+	".jpeg": clinic.AttachmentImage,
+	".png":  clinic.AttachmentImage,
+	".gif":  clinic.AttachmentImage,
+	".heic": clinic.AttachmentImage,
+	".webp": clinic.AttachmentImage,
+	".vcf":  clinic.AttachmentVCard,
+	".pdf":  clinic.AttachmentPDF,
+}
+
+// ClassifyAttachment returns the attachment kind for a content type, falling
+// back to the URL's file extension when the content type is missing.
+func ClassifyAttachment(contentType, url string) string {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		mediaType = strings.ToLower(strings.TrimSpace(contentType))
+	}
+	switch {
+	case strings.HasPrefix(mediaType, "image/"):
+		return clinic.AttachmentImage
+	case mediaType == "text/vcard", mediaType == "text/x-vcard", mediaType == "text/directory":
+		return clinic.AttachmentVCard
+	case mediaType == "application/pdf":
+		return clinic.AttachmentPDF
+	case mediaType == "" || mediaType == "application/octet-stream":
+		if u, _, _ := strings.Cut(url, "?"); u != "" {
+			if kind, ok := attachmentExtensions[strings.ToLower(path.Ext(u))]; ok {
+				return kind
+			}
+		}
+	}
+	return clinic.AttachmentUnknown
+}
+
+// AttachmentKinds lists the kind of each attachment, in order.
+func AttachmentKinds(attachments []Attachment) []string {
+	kinds := make([]string, 0, len(attachments))
+	for _, a := range attachments {
+		kinds = append(kinds, a.Kind)
+	}
+	return kinds
+}
+
+// EncodeAttachments renders attachments for MetadataAttachments.
+func EncodeAttachments(attachments []Attachment) string {
+	if len(attachments) == 0 {
+		return ""
+	}
+	data, err := json.Marshal(attachments)
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
+
+// DecodeAttachments reads MetadataAttachments; malformed values yield none.
+func DecodeAttachments(value string) []Attachment {
+	if strings.TrimSpace(value) == "" {
+		return nil
+	}
+	var attachments []Attachment
+	if err := json.Unmarshal([]byte(value), &attachments); err != nil {
+		return nil
+	}
+	return attachments
+}
+
+// ContactCard is the contact details read from a vCard.
+type ContactCard struct {
+	Name  string
+	Phone string
+	Email string
+}
+
+// ParseVCard reads the name, first phone number and first email address from
+// a vCard (versions 2.1 to 4.0). Folded lines are joined; properties may
+// carry parameters and group prefixes ("item1.TEL;TYPE=CELL:...").
+func ParseVCard(data []byte) ContactCard {
+	var card ContactCard
+	var structuredName string
+	for _, line := range unfoldVCard(data) {
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		name, _, _ := strings.Cut(key, ";")
+		if i := strings.LastIndex(name, "."); i >= 0 {
+			name = name[i+1:]
+		}
+		value = strings.TrimSpace(unescapeVCard(value))
+		switch strings.ToUpper(name) {
+		case "FN":
+			if card.Name == "" {
+				card.Name = value
+			}
+		case "N":
+			if structuredName == "" {
+				parts := strings.Split(value, ";")
+				var given, family string
+				family = strings.TrimSpace(parts[0])
+				if len(parts) > 1 {
+					given = strings.TrimSpace(parts[1])
+				}
+				structuredName = strings.TrimSpace(given + " " + family)
+			}
+		case "TEL":
+			if card.Phone == "" {
+				card.Phone = phone.E164(strings.TrimPrefix(strings.ToLower(value), "tel:"))
+			}
+		case "EMAIL":
+			if card.Email == "" && strings.Contains(value, "@") {
+				card.Email = strings.TrimPrefix(value, "mailto:")
+			}
+		}
+	}
+	if card.Name == "" {
+		card.Name = structuredName
+	}
+	return card
+}
+
+// unfoldVCard splits a vCard into logical lines, joining continuation lines
+// (those starting with a space or tab) onto the previous line.
+func unfoldVCard(data []byte) []string {
+	var lines []string
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+func unescapeVCard(value string) string {
+	return strings.NewReplacer(`\,`, ",", `\;`, ";", `\n`, " ", `\N`, " ", `\\`, `\`).Replace(value)
+}
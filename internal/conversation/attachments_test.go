@@ -0,0 +1,206 @@
+package conversation
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/clinic"
+	"github.com/wolfman30/medspa-ai-platform/internal/events"
+	"github.com/wolfman30/medspa-ai-platform/internal/leads"
+	"github.com/wolfman30/medspa-ai-platform/internal/notify"
+	"github.com/wolfman30/medspa-ai-platform/internal/tenancy"
+	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
+)
+
+func TestClassifyAttachment(t *testing.T) {
+	tests := []struct {
+		contentType string
+		url         string
+		want        string
+	}{
+		{"image/jpeg", "https://media.example.com/a", clinic.AttachmentImage},
+		{"image/heic", "", clinic.AttachmentImage},
+		{"text/vcard; charset=utf-8", "", clinic.AttachmentVCard},
+		{"text/x-vcard", "", clinic.AttachmentVCard},
+		{"application/pdf", "", clinic.AttachmentPDF},
+		{"video/mp4", "https://media.example.com/clip.mp4", clinic.AttachmentUnknown},
+		{"", "https://media.example.com/card.VCF?sig=abc", clinic.AttachmentVCard},
+		{"application/octet-stream", "https://media.example.com/quote.pdf", clinic.AttachmentPDF},
+		{"", "https://media.example.com/blob", clinic.AttachmentUnknown},
+	}
+	for _, tt := range tests {
+		if got := ClassifyAttachment(tt.contentType, tt.url); got != tt.want {
+			t.Errorf("ClassifyAttachment(%q, %q) = %q, want %q", tt.contentType, tt.url, got, tt.want)
+		}
+	}
+}
+
+const testVCard = "BEGIN:VCARD\r\n" +
+	"VERSION:3.0\r\n" +
+	"N:Doe;Jane;;;\r\n" +
+	"FN:Jane Doe\r\n" +
+	"item1.TEL;TYPE=CELL:(555) 010-2030\r\n" +
+	"EMAIL;TYPE=INTERNET:jane.doe@\r\n" +
+	" example.com\r\n" +
+	"END:VCARD\r\n"
+
+func TestParseVCard(t *testing.T) {
+	card := ParseVCard([]byte(testVCard))
+	if card.Name != "Jane Doe" || card.Phone != "+15550102030" || card.Email != "jane.doe@example.com" {
+		t.Fatalf("card = %+v", card)
+	}
+
+	card = ParseVCard([]byte("BEGIN:VCARD\nVERSION:2.1\nN:Smith;John\nEND:VCARD\n"))
+	if card.Name != "John Smith" {
+		t.Fatalf("name from N = %q, want John Smith", card.Name)
+	}
+}
+
+type stubMediaFetcher struct {
+	data map[string][]byte
+}
+
+func (s *stubMediaFetcher) FetchMedia(ctx context.Context, url string) ([]byte, error) {
+	return s.data[url], nil
+}
+
+type recordingAttachmentNotifier struct {
+	received []notify.AttachmentReceived
+}
+
+func (n *recordingAttachmentNotifier) NotifyPaymentSuccess(ctx context.Context, evt events.PaymentSucceededV1) error {
+	return nil
+}
+
+func (n *recordingAttachmentNotifier) NotifyAttachmentReceived(ctx context.Context, orgID string, att notify.AttachmentReceived) error {
+	n.received = append(n.received, att)
+	return nil
+}
+
+type attachmentFixture struct {
+	worker    *Worker
+	notifier  *recordingAttachmentNotifier
+	processor *recordingService
+	leads     *leads.InMemoryRepository
+	lead      *leads.Lead
+}
+
+func newAttachmentFixture(t *testing.T, media map[string][]byte) *attachmentFixture {
+	t.Helper()
+	repo := leads.NewInMemoryRepository()
+	lead, err := repo.GetOrCreateByPhone(context.Background(), "org-1", "+15005550002", "telnyx_sms", "")
+	if err != nil {
+		t.Fatalf("create lead: %v", err)
+	}
+	notifier := &recordingAttachmentNotifier{}
+	processor := &recordingService{}
+	worker := NewWorker(processor, newScriptedQueue(), &stubJobUpdater{}, &recordingMessenger{}, nil, logging.Default(),
+		WithPaymentNotifier(notifier),
+		WithWorkerLeadsRepo(repo),
+		WithMediaFetcher(&stubMediaFetcher{data: media}))
+	return &attachmentFixture{worker: worker, notifier: notifier, processor: processor, leads: repo, lead: lead}
+}
+
+func (f *attachmentFixture) dispatch(t *testing.T, text string, attachments ...Attachment) *Response {
+	t.Helper()
+	payload := queuePayload{ID: "job-1", Kind: jobTypeMessage, Message: MessageRequest{
+		OrgID:          "org-1",
+		LeadID:         f.lead.ID,
+		ConversationID: "sms:org-1:15005550002",
+		From:           "+15005550002",
+		To:             "+15005550100",
+		Channel:        ChannelSMS,
+		Message:        text,
+		Metadata:       map[string]string{MetadataAttachments: EncodeAttachments(attachments)},
+	}}
+	resp, err := f.worker.dispatchMessage(context.Background(), &payload)
+	if err != nil {
+		t.Fatalf("dispatch: %v", err)
+	}
+	return resp
+}
+
+func TestHandleAttachments_ImageForwardedToOperators(t *testing.T) {
+	f := newAttachmentFixture(t, nil)
+	url := "https://media.example.com/gift-card.jpg"
+
+	resp := f.dispatch(t, MediaPlaceholder, Attachment{URL: url, ContentType: "image/jpeg", Kind: clinic.AttachmentImage})
+	if resp == nil || !strings.Contains(resp.Message, "pass it to our team") {
+		t.Fatalf("expected the forward reply, got %+v", resp)
+	}
+	if f.processor.messageCalls != 0 {
+		t.Fatal("expected the LLM to be skipped")
+	}
+	if len(f.notifier.received) != 1 || f.notifier.received[0].MediaURL != url || f.notifier.received[0].Kind != clinic.AttachmentImage {
+		t.Fatalf("expected operators notified with the media URL, got %+v", f.notifier.received)
+	}
+}
+
+func TestHandleAttachments_PDFDeclined(t *testing.T) {
+	f := newAttachmentFixture(t, nil)
+
+	resp := f.dispatch(t, MediaPlaceholder, Attachment{URL: "https://media.example.com/quote.pdf", Kind: clinic.AttachmentPDF})
+	if resp == nil || !strings.Contains(resp.Message, "not able to open PDFs") {
+		t.Fatalf("expected the PDF notice, got %+v", resp)
+	}
+	if len(f.notifier.received) != 0 {
+		t.Fatalf("expected no operator notification, got %+v", f.notifier.received)
+	}
+}
+
+func TestHandleAttachments_UnknownDeclined(t *testing.T) {
+	f := newAttachmentFixture(t, nil)
+
+	resp := f.dispatch(t, MediaPlaceholder, Attachment{URL: "https://media.example.com/clip.mp4", ContentType: "video/mp4", Kind: clinic.AttachmentUnknown})
+	if resp == nil || !strings.Contains(resp.Message, "not able to open that attachment") {
+		t.Fatalf("expected the unsupported notice, got %+v", resp)
+	}
+}
+
+func TestHandleAttachments_VCardEnrichesLead(t *testing.T) {
+	url := "https://media.example.com/jane.vcf"
+	f := newAttachmentFixture(t, map[string][]byte{url: []byte(testVCard)})
+
+	resp := f.dispatch(t, MediaPlaceholder, Attachment{URL: url, ContentType: "text/vcard", Kind: clinic.AttachmentVCard})
+	if resp == nil || !strings.Contains(resp.Message, "saved those contact details") {
+		t.Fatalf("expected the contact reply, got %+v", resp)
+	}
+	lead, err := f.leads.GetByID(context.Background(), tenancy.ForOrg("org-1"), f.lead.ID)
+	if err != nil {
+		t.Fatalf("get lead: %v", err)
+	}
+	if lead.Name != "Jane Doe" || lead.Email != "jane.doe@example.com" {
+		t.Fatalf("lead = name %q email %q, want the card's details", lead.Name, lead.Email)
+	}
+	if !strings.Contains(lead.SchedulingNotes, "+15550102030") {
+		t.Fatalf("expected the card's other phone in the notes, got %q", lead.SchedulingNotes)
+	}
+}
+
+func TestHandleAttachments_TextStillGoesToLLM(t *testing.T) {
+	f := newAttachmentFixture(t, nil)
+
+	f.dispatch(t, "Can I use this gift card for Botox?", Attachment{URL: "https://media.example.com/card.png", Kind: clinic.AttachmentImage})
+	if f.processor.messageCalls != 1 {
+		t.Fatalf("expected the LLM to answer the text, got %d calls", f.processor.messageCalls)
+	}
+	if len(f.notifier.received) != 1 {
+		t.Fatalf("expected the image still forwarded, got %+v", f.notifier.received)
+	}
+}
+
+func TestAttachmentRuleFor_ClinicOverride(t *testing.T) {
+	cfg := &clinic.Config{AttachmentRules: map[string]clinic.AttachmentRule{
+		clinic.AttachmentImage: {Action: clinic.AttachmentActionDecline, Reply: "Please describe the photo in words."},
+	}}
+	if rule := cfg.AttachmentRuleFor(clinic.AttachmentImage); rule.Action != clinic.AttachmentActionDecline || rule.Reply != "Please describe the photo in words." {
+		t.Fatalf("rule = %+v", rule)
+	}
+	if rule := cfg.AttachmentRuleFor("video"); rule.Action != clinic.AttachmentActionDecline {
+		t.Fatalf("unknown kind rule = %+v", rule)
+	}
+	if err := clinic.ValidateAttachmentRules(map[string]clinic.AttachmentRule{clinic.AttachmentPDF: {Action: clinic.AttachmentActionContact}}); err == nil {
+		t.Fatal("expected contact action rejected for PDFs")
+	}
+}
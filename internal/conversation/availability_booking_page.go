@@ -0,0 +1,133 @@
+package conversation
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/wolfman30/medspa-ai-platform/internal/clinic"
+)
+
+const (
+	// bookingPageProbeInterval limits page probes to one per clinic per
+	// interval, however many lookups come back empty.
+	bookingPageProbeInterval = 10 * time.Minute
+	// availabilityDegradedFor is how long a dead booking page short-circuits
+	// a clinic's lookups before its sources are tried (and the page probed)
+	// again.
+	availabilityDegradedFor = 30 * time.Minute
+)
+
+// ErrAvailabilityDegraded means a clinic's availability can't be trusted
+// right now, e.g. its booking page moved, so an empty answer must not be
+// presented to the patient as "no availability".
+var ErrAvailabilityDegraded = errors.New("conversation: clinic availability degraded")
+
+var bookingPageDownTotal = prometheus.NewCounter(
+	prometheus.CounterOpts{
+		Namespace: "medspa",
+		Subsystem: "conversation",
+		Name:      "booking_page_down_total",
+		Help:      "Availability lookups that found the clinic's booking page dead",
+	},
+)
+
+func init() {
+	prometheus.MustRegister(bookingPageDownTotal)
+}
+
+// BookingPageProbe checks that a clinic's public booking page still loads.
+// It returns an error only when the page looks dead; a probe that couldn't
+// reach the page at all should return nil.
+type BookingPageProbe interface {
+	ProbeBookingPage(ctx context.Context, cfg *clinic.Config) error
+}
+
+// BookingPageAlerter tells engineering and the clinic that a booking page
+// looks dead.
+type BookingPageAlerter interface {
+	BookingPageDown(ctx context.Context, cfg *clinic.Config, cause error) error
+}
+
+// bookingPageGuard holds the router's per-clinic booking page state. It is
+// guarded by the router's mutex.
+type bookingPageGuard struct {
+	probe    BookingPageProbe
+	alerter  BookingPageAlerter
+	probed   map[string]time.Time // last probe per org
+	degraded map[string]time.Time // org -> when lookups resume
+	down     map[string]bool      // orgs already alerted on
+}
+
+func newBookingPageGuard() bookingPageGuard {
+	return bookingPageGuard{
+		probed:   make(map[string]time.Time),
+		degraded: make(map[string]time.Time),
+		down:     make(map[string]bool),
+	}
+}
+
+// SetBookingPageGuard makes the router probe a clinic's booking page when a
+// lookup comes back empty or every source fails. A dead page marks the
+// clinic's sources unhealthy, degrades its availability for a while, and
+// raises one alert until the page loads again.
+func (r *AvailabilityRouter) SetBookingPageGuard(probe BookingPageProbe, alerter BookingPageAlerter) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.pages.probe = probe
+	r.pages.alerter = alerter
+}
+
+// Degraded reports whether orgID's availability is currently degraded
+// because its booking page looked dead.
+func (r *AvailabilityRouter) Degraded(orgID string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	until, ok := r.pages.degraded[orgID]
+	return ok && r.now().Before(until)
+}
+
+// bookingPageDead probes cfg's booking page, at most once per
+// bookingPageProbeInterval, and degrades the clinic when the page is dead.
+func (r *AvailabilityRouter) bookingPageDead(ctx context.Context, orgID string, cfg *clinic.Config, sources []AvailabilitySource) bool {
+	r.mu.Lock()
+	probe, alerter := r.pages.probe, r.pages.alerter
+	now := r.now()
+	if probe == nil || cfg == nil || orgID == "" || now.Sub(r.pages.probed[orgID]) < bookingPageProbeInterval {
+		r.mu.Unlock()
+		return false
+	}
+	r.pages.probed[orgID] = now
+	r.mu.Unlock()
+
+	cause := probe.ProbeBookingPage(ctx, cfg)
+	if cause == nil {
+		r.mu.Lock()
+		delete(r.pages.down, orgID)
+		r.mu.Unlock()
+		return false
+	}
+	if ctx.Err() != nil {
+		return false
+	}
+
+	r.mu.Lock()
+	alerted := r.pages.down[orgID]
+	r.pages.down[orgID] = true
+	r.pages.degraded[orgID] = now.Add(availabilityDegradedFor)
+	r.mu.Unlock()
+	for _, src := range sources {
+		r.record(orgID, src.Name(), false)
+	}
+	bookingPageDownTotal.Inc()
+
+	log := r.logger.WithContext(ctx)
+	log.Error("clinic booking page looks dead; availability degraded", "org_id", orgID, "error", cause)
+	if !alerted && alerter != nil {
+		if err := alerter.BookingPageDown(ctx, cfg, cause); err != nil {
+			log.Warn("failed to send booking page alert", "org_id", orgID, "error", err)
+		}
+	}
+	return true
+}
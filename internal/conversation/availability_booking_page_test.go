@@ -0,0 +1,132 @@
+package conversation
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/clinic"
+	"github.com/wolfman30/medspa-ai-platform/internal/leads"
+	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
+)
+
+type stubBookingPageProbe struct {
+	err   error
+	calls int
+}
+
+func (p *stubBookingPageProbe) ProbeBookingPage(ctx context.Context, cfg *clinic.Config) error {
+	p.calls++
+	return p.err
+}
+
+type recordingBookingPageAlerter struct {
+	alerts []string
+}
+
+func (a *recordingBookingPageAlerter) BookingPageDown(ctx context.Context, cfg *clinic.Config, cause error) error {
+	a.alerts = append(a.alerts, cfg.OrgID)
+	return nil
+}
+
+func TestAvailabilityRouter_DeadBookingPageDegradesClinic(t *testing.T) {
+	router, moxie, browser, now := newTestAvailabilityRouter()
+	moxie.slots, browser.slots = 0, 0
+	probe := &stubBookingPageProbe{err: errors.New("booking page returned 404")}
+	alerter := &recordingBookingPageAlerter{}
+	router.SetBookingPageGuard(probe, alerter)
+	cfg := &clinic.Config{OrgID: "org-1"}
+
+	_, err := router.Fetch(context.Background(), routerRequest(cfg))
+	if !errors.Is(err, ErrAvailabilityDegraded) {
+		t.Fatalf("expected ErrAvailabilityDegraded, got %v", err)
+	}
+	if !router.Degraded("org-1") || router.Degraded("org-2") {
+		t.Fatalf("expected only org-1 degraded")
+	}
+	if len(alerter.alerts) != 1 || alerter.alerts[0] != "org-1" {
+		t.Fatalf("alerts = %v, want one for org-1", alerter.alerts)
+	}
+	if score := router.HealthScore("org-1", clinic.AvailabilitySourceMoxieAPI); score >= availabilityHealthyScore {
+		t.Fatalf("expected moxie marked unhealthy, got %.2f", score)
+	}
+
+	// While degraded, lookups skip the sources entirely.
+	if _, err := router.Fetch(context.Background(), routerRequest(cfg)); !errors.Is(err, ErrAvailabilityDegraded) {
+		t.Fatalf("expected degraded lookup, got %v", err)
+	}
+	if moxie.calls != 1 || browser.calls != 1 || probe.calls != 1 {
+		t.Fatalf("expected no new fetches or probes, got moxie=%d browser=%d probe=%d", moxie.calls, browser.calls, probe.calls)
+	}
+
+	// Once the window passes the page is probed again; still dead, but the
+	// clinic was already alerted.
+	*now = now.Add(availabilityDegradedFor)
+	if _, err := router.Fetch(context.Background(), routerRequest(cfg)); !errors.Is(err, ErrAvailabilityDegraded) {
+		t.Fatalf("expected degraded lookup, got %v", err)
+	}
+	if probe.calls != 2 || len(alerter.alerts) != 1 {
+		t.Fatalf("expected a re-probe without a repeat alert, got probe=%d alerts=%d", probe.calls, len(alerter.alerts))
+	}
+
+	// Fixed URL: the empty result is reported as-is and the next outage
+	// alerts again.
+	*now = now.Add(availabilityDegradedFor)
+	probe.err = nil
+	result, err := router.Fetch(context.Background(), routerRequest(cfg))
+	if err != nil || len(result.Slots) != 0 {
+		t.Fatalf("expected an empty result once the page loads, got %+v err=%v", result, err)
+	}
+	*now = now.Add(bookingPageProbeInterval)
+	probe.err = errors.New("booking page returned 404")
+	if _, err := router.Fetch(context.Background(), routerRequest(cfg)); !errors.Is(err, ErrAvailabilityDegraded) {
+		t.Fatalf("expected degraded lookup, got %v", err)
+	}
+	if len(alerter.alerts) != 2 {
+		t.Fatalf("expected a second alert after recovery, got %d", len(alerter.alerts))
+	}
+}
+
+func TestAvailabilityRouter_LiveBookingPageKeepsEmptyResult(t *testing.T) {
+	router, moxie, browser, _ := newTestAvailabilityRouter()
+	moxie.slots, browser.slots = 0, 0
+	probe := &stubBookingPageProbe{}
+	router.SetBookingPageGuard(probe, nil)
+	cfg := &clinic.Config{OrgID: "org-1"}
+
+	req := routerRequest(cfg)
+	req.Prefs = TimePreferences{DaysOfWeek: []int{int(time.Saturday)}}
+	for i := 0; i < 2; i++ {
+		result, err := router.Fetch(context.Background(), req)
+		if err != nil || len(result.Slots) != 0 {
+			t.Fatalf("fetch %d: expected a plain empty result, got %+v err=%v", i, result, err)
+		}
+	}
+	if probe.calls != 1 || router.Degraded("org-1") {
+		t.Fatalf("expected one throttled probe and no degradation, got %d probes", probe.calls)
+	}
+}
+
+func TestFetchAndPresentAvailability_DeadBookingPageCapturesPreferences(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	router := NewAvailabilityRouter(logging.Default(), &stubAvailabilitySource{name: clinic.AvailabilitySourceMoxieAPI})
+	router.SetBookingPageGuard(&stubBookingPageProbe{err: errors.New("booking page returned 404")}, &recordingBookingPageAlerter{})
+	svc := NewLLMService(&stubLLMClient{}, client, nil, "model", logging.Default(), WithAvailabilityRouter(router))
+
+	cfg := &clinic.Config{OrgID: "org-1", Timezone: "America/New_York"}
+	prefs := leads.SchedulingPreferences{Name: "Sarah Johnson", ServiceInterest: "Botox", PreferredDays: "weekdays", PreferredTimes: "afternoons"}
+	resp := svc.fetchAndPresentAvailability(context.Background(), &prefs, cfg, "", "sms:org-1:15550001111", "org-1", "", "+15550001111", nil)
+
+	want := "Thanks! I've noted your preference for weekdays afternoons for Botox. Our team will text you available times shortly."
+	if resp.SMSMessage != want {
+		t.Fatalf("reply = %q, want %q", resp.SMSMessage, want)
+	}
+	if len(resp.Slots) != 0 {
+		t.Fatalf("expected no slots, got %d", len(resp.Slots))
+	}
+}
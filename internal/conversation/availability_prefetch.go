@@ -3,6 +3,7 @@ package conversation
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"strings"
 	"sync"
@@ -20,9 +21,9 @@ const prefetchTTL = 5 * time.Minute
 // service is identified, before all qualifications are collected. Results are
 // cached in Redis and consumed by fetchAndPresentAvailability when ready.
 type AvailabilityPrefetcher struct {
-	moxieClient *moxieclient.Client
-	redis       *redis.Client
-	logger      *logging.Logger
+	router *AvailabilityRouter
+	redis  *redis.Client
+	logger *logging.Logger
 
 	// Track in-flight fetches to avoid duplicate work.
 	mu       sync.Mutex
@@ -35,12 +36,21 @@ func NewAvailabilityPrefetcher(
 	rdb *redis.Client,
 	logger *logging.Logger,
 ) *AvailabilityPrefetcher {
-	return &AvailabilityPrefetcher{
-		moxieClient: moxieClient,
-		redis:       rdb,
-		logger:      logger,
-		inflight:    make(map[string]bool),
+	p := &AvailabilityPrefetcher{
+		redis:    rdb,
+		logger:   logger,
+		inflight: make(map[string]bool),
 	}
+	if moxieClient != nil {
+		p.router = NewAvailabilityRouter(logger, NewMoxieAPISource(moxieClient))
+	}
+	return p
+}
+
+// SetAvailabilityRouter makes the prefetcher share a router (and its source
+// health) with the conversation service.
+func (p *AvailabilityPrefetcher) SetAvailabilityRouter(r *AvailabilityRouter) {
+	p.router = r
 }
 
 // prefetchCacheKey returns the Redis key for cached availability results.
@@ -67,14 +77,14 @@ func (p *AvailabilityPrefetcher) StartPrefetch(
 	if cfg == nil || !cfg.UsesMoxieBooking() || cfg.BookingURL == "" {
 		return
 	}
-	if p.moxieClient == nil {
+	if p.router == nil {
 		return
 	}
 	if p.redis == nil {
 		return
 	}
 
-	resolvedService := cfg.ResolveServiceName(serviceInterest)
+	resolvedService := cfg.ResolveServiceName(cfg.BookingServiceFor(serviceInterest))
 	cacheKey := prefetchCacheKey(orgID, resolvedService)
 
 	// Deduplicate in-flight fetches.
@@ -106,11 +116,15 @@ func (p *AvailabilityPrefetcher) StartPrefetch(
 		var result *AvailabilityResult
 		var err error
 
-		if p.moxieClient != nil && cfg.MoxieConfig != nil {
-			result, err = FetchAvailableTimesFromMoxieAPIWithProvider(
-				fetchCtx, p.moxieClient, cfg, resolvedService,
-				providerPreference, timePrefs, nil, serviceInterest,
-			)
+		if p.router != nil && p.router.Supports(cfg) {
+			result, err = p.router.Fetch(fetchCtx, AvailabilityRequest{
+				OrgID:              orgID,
+				Config:             cfg,
+				Service:            resolvedService,
+				DisplayService:     serviceInterest,
+				ProviderPreference: providerPreference,
+				Prefs:              timePrefs,
+			})
 		}
 
 		if err != nil {
@@ -121,6 +135,10 @@ func (p *AvailabilityPrefetcher) StartPrefetch(
 			p.logger.Info("availability prefetch: no slots", "service", resolvedService)
 			return
 		}
+		if result.Source == AvailabilitySourcePrefetchCache {
+			// Re-caching the cache's own slots would keep them alive forever.
+			return
+		}
 
 		cached := &CachedAvailability{
 			Result:    result,
@@ -153,10 +171,13 @@ func (p *AvailabilityPrefetcher) GetCached(
 
 	resolvedService := service
 	if cfg != nil {
-		resolvedService = cfg.ResolveServiceName(service)
+		resolvedService = cfg.ResolveServiceName(cfg.BookingServiceFor(service))
 	}
-	cacheKey := prefetchCacheKey(orgID, resolvedService)
+	return p.cached(ctx, prefetchCacheKey(orgID, resolvedService))
+}
 
+// cached reads and decodes a fresh cache entry, or returns nil.
+func (p *AvailabilityPrefetcher) cached(ctx context.Context, cacheKey string) *CachedAvailability {
 	data, err := p.redis.Get(ctx, cacheKey).Result()
 	if err != nil || data == "" {
 		return nil
@@ -173,3 +194,60 @@ func (p *AvailabilityPrefetcher) GetCached(
 
 	return &cached
 }
+
+// AvailabilitySourcePrefetchCache names PrefetchCacheSource in the router's
+// logs and metrics.
+const AvailabilitySourcePrefetchCache = "prefetch_cache"
+
+// errPrefetchCacheMiss means the cache has nothing usable for a lookup, so
+// the router moves on rather than believing an empty result.
+var errPrefetchCacheMiss = errors.New("conversation: no usable prefetched availability")
+
+// PrefetchCacheSource serves the prefetcher's recent results as a fallback
+// when live lookups fail. Register it after the live sources; slots it serves
+// are at most prefetchTTL old and are re-verified before booking.
+type PrefetchCacheSource struct {
+	prefetcher *AvailabilityPrefetcher
+}
+
+// NewPrefetchCacheSource wraps a prefetcher's cache as an AvailabilitySource.
+func NewPrefetchCacheSource(prefetcher *AvailabilityPrefetcher) *PrefetchCacheSource {
+	return &PrefetchCacheSource{prefetcher: prefetcher}
+}
+
+// Name implements AvailabilitySource.
+func (c *PrefetchCacheSource) Name() string { return AvailabilitySourcePrefetchCache }
+
+// Supports implements AvailabilitySource. Only clinics served by the Moxie
+// API have their lookups cached.
+func (c *PrefetchCacheSource) Supports(cfg *clinic.Config) bool {
+	return c.prefetcher != nil && c.prefetcher.redis != nil &&
+		cfg != nil && cfg.MoxieConfig != nil && cfg.FlagEnabled(clinic.FlagMoxieAPIAvailability)
+}
+
+// FetchAvailability implements AvailabilitySource. The cache ignores provider
+// preference, so provider-specific lookups always miss.
+func (c *PrefetchCacheSource) FetchAvailability(ctx context.Context, req AvailabilityRequest) (*AvailabilityResult, error) {
+	if req.ProviderPreference != "" {
+		return nil, errPrefetchCacheMiss
+	}
+	cached := c.prefetcher.cached(ctx, prefetchCacheKey(req.OrgID, req.Service))
+	if cached == nil || cached.Result == nil {
+		return nil, errPrefetchCacheMiss
+	}
+	filtered := filterSlotsByTimePrefs(cached.Result.Slots, &req.Prefs)
+	if len(filtered) == 0 {
+		return nil, errPrefetchCacheMiss
+	}
+	slots := make([]PresentedSlot, len(filtered))
+	for i, slot := range filtered {
+		slot.Index = i + 1
+		slots[i] = slot
+	}
+	return &AvailabilityResult{
+		Slots:      slots,
+		ExactMatch: cached.Result.ExactMatch,
+		Message:    cached.Result.Message,
+		Template:   cached.Result.Template,
+	}, nil
+}
@@ -0,0 +1,129 @@
+package conversation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/wolfman30/medspa-ai-platform/internal/clinic"
+	"github.com/wolfman30/medspa-ai-platform/internal/leads"
+	"github.com/wolfman30/medspa-ai-platform/internal/tenancy"
+)
+
+// availabilityRefreshLockTTL is how long a support-triggered refresh holds
+// its per-conversation lock, so a double-click doesn't text the patient twice.
+const availabilityRefreshLockTTL = 60 * time.Second
+
+var (
+	// ErrAvailabilityRefreshNotAllowed is returned when the conversation has
+	// already reached a deposit or booking and must not get new slots.
+	ErrAvailabilityRefreshNotAllowed = errors.New("conversation: availability refresh not allowed after deposit or booking")
+	// ErrAvailabilityRefreshInProgress is returned while another refresh for
+	// the same conversation holds the lock.
+	ErrAvailabilityRefreshInProgress = errors.New("conversation: availability refresh already in progress")
+)
+
+// RefreshAvailabilityRequest asks the worker to re-run the availability
+// search for a conversation and text the patient the fresh slots.
+type RefreshAvailabilityRequest struct {
+	OrgID          string `json:"org_id"`
+	LeadID         string `json:"lead_id,omitempty"`
+	ConversationID string `json:"conversation_id"`
+	Phone          string `json:"phone"` // patient phone
+}
+
+// AvailabilityProvider re-runs the availability pipeline for a lead's saved
+// preferences and records the presented slots on the conversation.
+type AvailabilityProvider interface {
+	RefreshAvailability(ctx context.Context, req RefreshAvailabilityRequest) (*TimeSelectionResponse, error)
+}
+
+// ConversationLookup loads a persisted conversation record.
+type ConversationLookup interface {
+	GetConversation(ctx context.Context, scope tenancy.OrgScope, conversationID string) (*ConversationRecord, error)
+}
+
+// availabilityRefreshBlocked reports whether a conversation is past the point
+// where presenting new slots makes sense.
+func availabilityRefreshBlocked(status string) bool {
+	switch status {
+	case StatusDepositPaid, StatusBooked:
+		return true
+	}
+	return false
+}
+
+// acquireAvailabilityRefreshLock takes the per-conversation refresh lock.
+// The lock expires on its own; it is never released early.
+func acquireAvailabilityRefreshLock(ctx context.Context, client *redis.Client, conversationID string) error {
+	ok, err := client.SetNX(ctx, availabilityRefreshLockKey(conversationID), time.Now().UTC().Format(time.RFC3339), availabilityRefreshLockTTL).Result()
+	if err != nil {
+		return fmt.Errorf("conversation: acquire availability refresh lock: %w", err)
+	}
+	if !ok {
+		return ErrAvailabilityRefreshInProgress
+	}
+	return nil
+}
+
+func availabilityRefreshLockKey(conversationID string) string {
+	return fmt.Sprintf("availability_refresh_lock:%s", conversationID)
+}
+
+// RefreshAvailability loads the lead's saved scheduling preferences and runs
+// the same Moxie/Boulevard availability fetch used mid-conversation. The
+// presented slots are saved as the conversation's time selection state.
+func (s *LLMService) RefreshAvailability(ctx context.Context, req RefreshAvailabilityRequest) (*TimeSelectionResponse, error) {
+	if s.leadsRepo == nil {
+		return nil, errors.New("conversation: refresh availability: leads repository not configured")
+	}
+	if s.clinicStore == nil {
+		return nil, errors.New("conversation: refresh availability: clinic store not configured")
+	}
+
+	var lead *leads.Lead
+	var err error
+	if strings.TrimSpace(req.LeadID) != "" {
+		lead, err = s.leadsRepo.GetByID(ctx, tenancy.ForOrg(req.OrgID), req.LeadID)
+	} else {
+		lead, err = s.leadsRepo.GetOrCreateByPhone(ctx, req.OrgID, req.Phone, "sms", "")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("conversation: refresh availability: load lead: %w", err)
+	}
+	if strings.EqualFold(lead.DepositStatus, "paid") {
+		return nil, ErrAvailabilityRefreshNotAllowed
+	}
+	if strings.TrimSpace(lead.ServiceInterest) == "" {
+		return nil, errors.New("conversation: refresh availability: lead has no saved service preference")
+	}
+
+	cfg, err := s.clinicStore.Get(ctx, req.OrgID)
+	if err != nil {
+		return nil, fmt.Errorf("conversation: refresh availability: load clinic config: %w", err)
+	}
+	if !cfg.IsActive() {
+		return nil, fmt.Errorf("conversation: refresh availability: %w", clinic.ErrInactive)
+	}
+	if cfg.BookingURL == "" {
+		return nil, errors.New("conversation: refresh availability: clinic has no booking url")
+	}
+
+	prefs := leads.SchedulingPreferences{
+		Name:            lead.Name,
+		ServiceInterest: lead.ServiceInterest,
+		PatientType:     lead.PatientType,
+		PastServices:    lead.PastServices,
+		PreferredDays:   lead.PreferredDays,
+		PreferredTimes:  lead.PreferredTimes,
+		Notes:           lead.SchedulingNotes,
+	}
+	tsr := s.fetchAndPresentAvailability(ctx, &prefs, cfg, cfg.BookingURL, req.ConversationID, req.OrgID, req.LeadID, req.Phone, nil)
+	if tsr == nil {
+		return nil, errors.New("conversation: refresh availability: no availability response")
+	}
+	return tsr, nil
+}
@@ -0,0 +1,212 @@
+package conversation
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/clinic"
+	"github.com/wolfman30/medspa-ai-platform/internal/leads"
+	"github.com/wolfman30/medspa-ai-platform/internal/tenancy"
+	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
+)
+
+const refreshConvID = "sms:org-1:15551234567"
+
+type refreshEnqueuer struct {
+	stubEnqueuer
+	refreshes []RefreshAvailabilityRequest
+}
+
+func (s *refreshEnqueuer) EnqueueRefreshAvailability(ctx context.Context, jobID string, req RefreshAvailabilityRequest) error {
+	s.refreshes = append(s.refreshes, req)
+	return nil
+}
+
+type stubConversationLookup struct {
+	conv *ConversationRecord
+}
+
+func (s stubConversationLookup) GetConversation(ctx context.Context, scope tenancy.OrgScope, conversationID string) (*ConversationRecord, error) {
+	return s.conv, nil
+}
+
+// availabilityService is a conversation service with a stubbed availability
+// provider.
+type availabilityService struct {
+	replyService
+	tsr   *TimeSelectionResponse
+	err   error
+	calls []RefreshAvailabilityRequest
+}
+
+func (s *availabilityService) RefreshAvailability(ctx context.Context, req RefreshAvailabilityRequest) (*TimeSelectionResponse, error) {
+	s.calls = append(s.calls, req)
+	return s.tsr, s.err
+}
+
+func newRefreshHandler(t *testing.T, status string) (*Handler, *refreshEnqueuer) {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	enqueuer := &refreshEnqueuer{}
+	leadID := uuid.New()
+	h := NewHandler(enqueuer, &stubJobStore{}, nil, nil, logging.Default())
+	h.SetAvailabilityRefresh(stubConversationLookup{conv: &ConversationRecord{
+		ConversationID: refreshConvID,
+		OrgID:          "org-1",
+		LeadID:         &leadID,
+		Status:         status,
+	}}, rdb)
+	return h, enqueuer
+}
+
+func postRefresh(h *Handler) *httptest.ResponseRecorder {
+	r := chi.NewRouter()
+	r.Post("/admin/orgs/{orgID}/conversations/{conversationID}/refresh-availability", h.RefreshAvailability)
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, orgRequest("org-1", http.MethodPost, "/admin/orgs/org-1/conversations/sms%3Aorg-1%3A15551234567/refresh-availability", nil))
+	return rec
+}
+
+// orgRequest builds a request scoped to orgID, as the router's org
+// middleware would.
+func orgRequest(orgID, method, target string, body io.Reader) *http.Request {
+	req := httptest.NewRequest(method, target, body)
+	return req.WithContext(tenancy.WithOrgID(req.Context(), orgID))
+}
+
+func TestRefreshAvailability_Enqueues(t *testing.T) {
+	h, enqueuer := newRefreshHandler(t, StatusAwaitingTimeSelection)
+
+	rec := postRefresh(h)
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("expected 202, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if len(enqueuer.refreshes) != 1 {
+		t.Fatalf("expected 1 refresh job, got %d", len(enqueuer.refreshes))
+	}
+	got := enqueuer.refreshes[0]
+	if got.OrgID != "org-1" || got.ConversationID != refreshConvID || got.Phone != "15551234567" || got.LeadID == "" {
+		t.Fatalf("unexpected refresh request: %+v", got)
+	}
+}
+
+func TestRefreshAvailability_RefusesPaidConversation(t *testing.T) {
+	for _, status := range []string{StatusDepositPaid, StatusBooked} {
+		t.Run(status, func(t *testing.T) {
+			h, enqueuer := newRefreshHandler(t, status)
+
+			if rec := postRefresh(h); rec.Code != http.StatusConflict {
+				t.Fatalf("expected 409, got %d", rec.Code)
+			}
+			if len(enqueuer.refreshes) != 0 {
+				t.Fatal("refresh should not be enqueued")
+			}
+		})
+	}
+}
+
+func TestRefreshAvailability_LocksPerConversation(t *testing.T) {
+	h, enqueuer := newRefreshHandler(t, StatusActive)
+
+	if rec := postRefresh(h); rec.Code != http.StatusAccepted {
+		t.Fatalf("first click: expected 202, got %d", rec.Code)
+	}
+	if rec := postRefresh(h); rec.Code != http.StatusConflict {
+		t.Fatalf("double click: expected 409, got %d", rec.Code)
+	}
+	if len(enqueuer.refreshes) != 1 {
+		t.Fatalf("expected 1 refresh job, got %d", len(enqueuer.refreshes))
+	}
+	if ttl := h.refreshLocks.TTL(context.Background(), availabilityRefreshLockKey(refreshConvID)).Val(); ttl <= 0 || ttl > availabilityRefreshLockTTL {
+		t.Fatalf("unexpected lock ttl %s", ttl)
+	}
+}
+
+func TestWorkerRefreshAvailability_SendsSlots(t *testing.T) {
+	mr := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	transcript := NewSMSTranscriptStore(rdb)
+	if err := transcript.Append(context.Background(), refreshConvID, SMSTranscriptMessage{
+		Role: "user", From: "+15551234567", To: "+15556667777", Body: "any time tuesday", Timestamp: time.Now(),
+	}); err != nil {
+		t.Fatalf("seed transcript: %v", err)
+	}
+
+	slot := time.Date(2026, 3, 10, 14, 0, 0, 0, time.UTC)
+	service := &availabilityService{tsr: &TimeSelectionResponse{
+		Slots:      []PresentedSlot{{Index: 1, DateTime: slot, TimeStr: "Tue Mar 10 at 2:00 PM", Service: "Botox", Available: true}},
+		Service:    "Botox",
+		ExactMatch: true,
+		SMSMessage: "Here are the next openings for Botox:\n1. Tue Mar 10 at 2:00 PM",
+	}}
+	messenger := &stubMessenger{}
+	worker := NewWorker(service, newScriptedQueue(), &stubJobUpdater{}, messenger, nil, logging.Default(), WithSMSTranscriptStore(transcript))
+
+	resp, err := worker.refreshAvailability(context.Background(), &RefreshAvailabilityRequest{
+		OrgID: "org-1", LeadID: "lead-1", ConversationID: refreshConvID, Phone: "+15551234567",
+	})
+	if err != nil {
+		t.Fatalf("refreshAvailability: %v", err)
+	}
+	if resp.TimeSelectionResponse != service.tsr || len(service.calls) != 1 {
+		t.Fatalf("expected provider result to be returned, got %+v", resp)
+	}
+
+	reply := messenger.lastReply()
+	if reply.Body != service.tsr.SMSMessage {
+		t.Fatalf("sent body = %q", reply.Body)
+	}
+	if reply.To != "+15551234567" || reply.From != "+15556667777" {
+		t.Fatalf("unexpected to/from: %+v", reply)
+	}
+	msgs, _ := transcript.List(context.Background(), refreshConvID, 0)
+	if last := msgs[len(msgs)-1]; last.Role != "assistant" || last.Body != service.tsr.SMSMessage {
+		t.Fatalf("slots not recorded in transcript: %+v", last)
+	}
+}
+
+func TestWorkerRefreshAvailability_ProviderRefusal(t *testing.T) {
+	service := &availabilityService{err: ErrAvailabilityRefreshNotAllowed}
+	messenger := &stubMessenger{}
+	worker := NewWorker(service, newScriptedQueue(), &stubJobUpdater{}, messenger, nil, logging.Default())
+
+	_, err := worker.refreshAvailability(context.Background(), &RefreshAvailabilityRequest{
+		OrgID: "org-1", ConversationID: refreshConvID, Phone: "+15551234567",
+	})
+	if !errors.Is(err, ErrAvailabilityRefreshNotAllowed) {
+		t.Fatalf("expected refusal, got %v", err)
+	}
+	if messenger.wasCalled() {
+		t.Fatal("no SMS should be sent")
+	}
+}
+
+func TestLLMServiceRefreshAvailability_RefusesPaidLead(t *testing.T) {
+	ts := setupService(t, withLeads(), withClinicConfig("org-1", func(cfg *clinic.Config) {
+		cfg.BookingURL = "https://app.joinmoxie.com/booking/glow"
+	}))
+	lead, err := ts.leadsRepo.Create(context.Background(), &leads.CreateLeadRequest{OrgID: "org-1", Name: "Jane", Phone: "+15551234567", Source: "sms"})
+	if err != nil {
+		t.Fatalf("create lead: %v", err)
+	}
+	_ = ts.leadsRepo.UpdateSchedulingPreferences(context.Background(), tenancy.ForOrg(lead.OrgID), lead.ID, leads.SchedulingPreferences{ServiceInterest: "Botox"})
+	_ = ts.leadsRepo.UpdateDepositStatus(context.Background(), tenancy.ForOrg(lead.OrgID), lead.ID, "paid", "priority")
+
+	_, err = ts.svc.RefreshAvailability(context.Background(), RefreshAvailabilityRequest{
+		OrgID: "org-1", LeadID: lead.ID, ConversationID: refreshConvID, Phone: "+15551234567",
+	})
+	if !errors.Is(err, ErrAvailabilityRefreshNotAllowed) {
+		t.Fatalf("expected refusal for paid lead, got %v", err)
+	}
+}
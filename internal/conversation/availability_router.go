@@ -0,0 +1,346 @@
+package conversation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/wolfman30/medspa-ai-platform/internal/clinic"
+	moxieclient "github.com/wolfman30/medspa-ai-platform/internal/emr/moxie"
+	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
+)
+
+const (
+	// availabilityHealthHalfLife is how fast a failing source is forgiven: the
+	// gap between its score and fully healthy halves every half-life.
+	availabilityHealthHalfLife = 5 * time.Minute
+	// availabilityHealthWeight is how far one outcome moves the score.
+	availabilityHealthWeight = 0.5
+	// availabilityHealthyScore is the score below which a source stops being
+	// tried first. A single failure leaves a healthy source at the threshold.
+	availabilityHealthyScore = 0.5
+)
+
+var availabilitySourceTotal = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Namespace: "medspa",
+		Subsystem: "conversation",
+		Name:      "availability_source_total",
+		Help:      "Availability lookups by source and outcome",
+	},
+	[]string{"source", "outcome"}, // outcome: success, error, empty, unsupported
+)
+
+func init() {
+	prometheus.MustRegister(availabilitySourceTotal)
+}
+
+// AvailabilityRequest describes a single availability lookup.
+type AvailabilityRequest struct {
+	OrgID              string
+	Config             *clinic.Config
+	Service            string // service name as the booking platform knows it
+	DisplayService     string // patient-facing service name for progress messages
+	ProviderPreference string
+	Prefs              TimePreferences
+	OnProgress         func(ctx context.Context, msg string)
+}
+
+// constrained reports whether the patient narrowed the search, in which case
+// an empty result is a plausible answer rather than a sign of breakage.
+func (r AvailabilityRequest) constrained() bool {
+	p := r.Prefs
+	return len(p.DaysOfWeek) > 0 || p.AfterTime != "" || p.BeforeTime != "" ||
+		p.HasDateRange() || r.ProviderPreference != ""
+}
+
+// AvailabilitySource fetches open slots from one backend, e.g. the Moxie
+// GraphQL API.
+type AvailabilitySource interface {
+	Name() string
+	// Supports reports whether the source can serve the clinic at all.
+	Supports(cfg *clinic.Config) bool
+	FetchAvailability(ctx context.Context, req AvailabilityRequest) (*AvailabilityResult, error)
+}
+
+// MoxieAPISource serves availability from Moxie's GraphQL API.
+type MoxieAPISource struct {
+	client *moxieclient.Client
+}
+
+// NewMoxieAPISource wraps a Moxie API client as an AvailabilitySource.
+func NewMoxieAPISource(client *moxieclient.Client) *MoxieAPISource {
+	return &MoxieAPISource{client: client}
+}
+
+// Name implements AvailabilitySource.
+func (m *MoxieAPISource) Name() string { return clinic.AvailabilitySourceMoxieAPI }
+
+// Supports implements AvailabilitySource.
+func (m *MoxieAPISource) Supports(cfg *clinic.Config) bool {
+	return m.client != nil && cfg != nil && cfg.MoxieConfig != nil && cfg.FlagEnabled(clinic.FlagMoxieAPIAvailability)
+}
+
+// FetchAvailability implements AvailabilitySource.
+func (m *MoxieAPISource) FetchAvailability(ctx context.Context, req AvailabilityRequest) (*AvailabilityResult, error) {
+	return FetchAvailableTimesFromMoxieAPIWithProvider(ctx, m.client, req.Config, req.Service,
+		req.ProviderPreference, req.Prefs, req.OnProgress, req.DisplayService)
+}
+
+// sourceHealth is a per-clinic, per-source health score in [0, 1].
+type sourceHealth struct {
+	score   float64
+	updated time.Time
+}
+
+// at returns the score at now, decayed back toward healthy since the last
+// recorded outcome.
+func (h sourceHealth) at(now time.Time, halfLife time.Duration) float64 {
+	if h.updated.IsZero() {
+		return 1
+	}
+	decay := math.Pow(0.5, float64(now.Sub(h.updated))/float64(halfLife))
+	return 1 - (1-h.score)*decay
+}
+
+// AvailabilityRouter picks which availability source serves a lookup. It
+// tries the healthiest source first (in registration order on a tie) and
+// falls back to the next on an error or an unexpectedly empty result. A
+// clinic pinned via clinic.Config.AvailabilitySource only uses that source.
+type AvailabilityRouter struct {
+	logger   *logging.Logger
+	halfLife time.Duration
+	now      func() time.Time
+	limiter  AvailabilityLimiter
+
+	mu      sync.Mutex
+	sources []AvailabilitySource
+	health  map[string]sourceHealth // keyed by org ID + source name
+	pages   bookingPageGuard
+}
+
+// NewAvailabilityRouter creates a router over sources, listed in order of
+// preference.
+func NewAvailabilityRouter(logger *logging.Logger, sources ...AvailabilitySource) *AvailabilityRouter {
+	if logger == nil {
+		logger = logging.Default()
+	}
+	r := &AvailabilityRouter{
+		logger:   logger,
+		halfLife: availabilityHealthHalfLife,
+		now:      time.Now,
+		health:   make(map[string]sourceHealth),
+		pages:    newBookingPageGuard(),
+	}
+	for _, src := range sources {
+		r.AddSource(src)
+	}
+	return r
+}
+
+// AddSource registers another source after the existing ones. Nil sources
+// are ignored.
+func (r *AvailabilityRouter) AddSource(src AvailabilitySource) {
+	if src == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.sources = append(r.sources, src)
+}
+
+// SetLimiter caps concurrent fetches per clinic across every caller sharing
+// the router.
+func (r *AvailabilityRouter) SetLimiter(limiter AvailabilityLimiter) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.limiter = limiter
+}
+
+// Supports reports whether any source can serve the clinic.
+func (r *AvailabilityRouter) Supports(cfg *clinic.Config) bool {
+	return len(r.eligible(cfg)) > 0
+}
+
+// HealthScore returns a source's current health score for an org.
+func (r *AvailabilityRouter) HealthScore(orgID, source string) float64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.health[healthKey(orgID, source)].at(r.now(), r.halfLife)
+}
+
+// Fetch serves req from the best available source, annotating the result
+// with the source that served it.
+func (r *AvailabilityRouter) Fetch(ctx context.Context, req AvailabilityRequest) (*AvailabilityResult, error) {
+	orgID := req.OrgID
+	if orgID == "" && req.Config != nil {
+		orgID = req.Config.OrgID
+	}
+	log := r.logger.WithContext(ctx)
+	candidates := r.ordered(orgID, r.eligible(req.Config))
+	if len(candidates) == 0 {
+		return nil, errors.New("conversation: no availability source configured")
+	}
+	if r.Degraded(orgID) {
+		return nil, fmt.Errorf("%w: booking page unavailable", ErrAvailabilityDegraded)
+	}
+	release, err := r.acquire(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	var (
+		empty  *AvailabilityResult
+		errs   []error
+		failed bool
+	)
+	for i, src := range candidates {
+		name := src.Name()
+		fetchStart := time.Now()
+		result, err := src.FetchAvailability(ctx, req)
+		addTurnStage(ctx, TurnStageAvailability, time.Since(fetchStart))
+		fetched := time.Since(fetchStart).Seconds()
+		switch {
+		case errors.Is(err, errNoServiceMenuItem):
+			// A config gap says nothing about the source's health.
+			availabilitySourceTotal.WithLabelValues(name, "unsupported").Inc()
+			availabilityFetchDuration.WithLabelValues(name, "unsupported").Observe(fetched)
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+			continue
+		case err != nil:
+			r.record(orgID, name, false)
+			availabilitySourceTotal.WithLabelValues(name, "error").Inc()
+			availabilityFetchDuration.WithLabelValues(name, "error").Observe(fetched)
+			log.Warn("availability source failed", "source", name, "error", err)
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+			failed = true
+			if ctx.Err() != nil {
+				return nil, errors.Join(errs...)
+			}
+			continue
+		case result == nil:
+			result = &AvailabilityResult{}
+		}
+		result.Source = name
+
+		if len(result.Slots) == 0 && !req.constrained() {
+			// An unfiltered search coming back empty usually means the
+			// source is broken, so try the next one before believing it.
+			r.record(orgID, name, false)
+			availabilitySourceTotal.WithLabelValues(name, "empty").Inc()
+			availabilityFetchDuration.WithLabelValues(name, "empty").Observe(fetched)
+			log.Warn("availability source returned no slots for an unfiltered search", "source", name)
+			if empty == nil {
+				empty = result
+			}
+			continue
+		}
+
+		if len(result.Slots) == 0 && r.bookingPageDead(ctx, orgID, req.Config, candidates) {
+			return nil, fmt.Errorf("%w: booking page unavailable", ErrAvailabilityDegraded)
+		}
+		r.record(orgID, name, true)
+		availabilitySourceTotal.WithLabelValues(name, "success").Inc()
+		availabilityFetchDuration.WithLabelValues(name, "success").Observe(fetched)
+		log.Info("availability served", "source", name, "fallback", i > 0, "slots", len(result.Slots))
+		return result, nil
+	}
+	if (empty != nil || failed) && r.bookingPageDead(ctx, orgID, req.Config, candidates) {
+		return nil, fmt.Errorf("%w: booking page unavailable", ErrAvailabilityDegraded)
+	}
+	if empty != nil {
+		return empty, nil
+	}
+	return nil, errors.Join(errs...)
+}
+
+// acquire waits for a fetch slot for orgID. A limiter that fails for any
+// reason other than ctx ending is skipped rather than blocking the patient.
+func (r *AvailabilityRouter) acquire(ctx context.Context, orgID string) (func(), error) {
+	r.mu.Lock()
+	limiter := r.limiter
+	r.mu.Unlock()
+	if limiter == nil || orgID == "" {
+		return func() {}, nil
+	}
+	start := time.Now()
+	release, err := limiter.Acquire(ctx, orgID)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("conversation: waiting for availability slot: %w", ctx.Err())
+		}
+		r.logger.WithContext(ctx).Warn("availability limiter unavailable; fetching anyway", "error", err)
+		return func() {}, nil
+	}
+	if waited := time.Since(start); waited >= time.Millisecond {
+		concurrencyLimitedTotal.WithLabelValues("availability").Inc()
+		concurrencyLimitDelay.WithLabelValues("availability").Observe(waited.Seconds())
+	}
+	return release, nil
+}
+
+// eligible returns the sources that can serve cfg, honoring a pinned source.
+func (r *AvailabilityRouter) eligible(cfg *clinic.Config) []AvailabilitySource {
+	if cfg == nil {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var out []AvailabilitySource
+	for _, src := range r.sources {
+		if cfg.AvailabilitySource != "" && src.Name() != cfg.AvailabilitySource {
+			continue
+		}
+		if src.Supports(cfg) {
+			out = append(out, src)
+		}
+	}
+	return out
+}
+
+// ordered sorts healthy sources ahead of unhealthy ones, keeping preference
+// order within each group and trying the least unhealthy first.
+func (r *AvailabilityRouter) ordered(orgID string, sources []AvailabilitySource) []AvailabilitySource {
+	r.mu.Lock()
+	now := r.now()
+	scores := make(map[string]float64, len(sources))
+	for _, src := range sources {
+		scores[src.Name()] = r.health[healthKey(orgID, src.Name())].at(now, r.halfLife)
+	}
+	r.mu.Unlock()
+
+	sort.SliceStable(sources, func(i, j int) bool {
+		si, sj := scores[sources[i].Name()], scores[sources[j].Name()]
+		hi, hj := si >= availabilityHealthyScore, sj >= availabilityHealthyScore
+		if hi != hj {
+			return hi
+		}
+		return !hi && si > sj
+	})
+	return sources
+}
+
+// record folds one outcome into a source's health score.
+func (r *AvailabilityRouter) record(orgID, source string, ok bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	key := healthKey(orgID, source)
+	now := r.now()
+	score := r.health[key].at(now, r.halfLife)
+	if ok {
+		score += (1 - score) * availabilityHealthWeight
+	} else {
+		score *= 1 - availabilityHealthWeight
+	}
+	r.health[key] = sourceHealth{score: score, updated: now}
+}
+
+func healthKey(orgID, source string) string {
+	return orgID + "|" + source
+}
@@ -0,0 +1,225 @@
+package conversation
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/clinic"
+	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
+)
+
+// testBrowserSource names a second live source so routing and fallback can
+// be exercised; production registers the Moxie API and the prefetch cache.
+const testBrowserSource = "browser"
+
+type stubAvailabilitySource struct {
+	name  string
+	err   error
+	slots int
+	calls int
+}
+
+func (s *stubAvailabilitySource) Name() string                 { return s.name }
+func (s *stubAvailabilitySource) Supports(*clinic.Config) bool { return true }
+
+func (s *stubAvailabilitySource) FetchAvailability(ctx context.Context, req AvailabilityRequest) (*AvailabilityResult, error) {
+	s.calls++
+	if s.err != nil {
+		return nil, s.err
+	}
+	result := &AvailabilityResult{ExactMatch: s.slots > 0}
+	for i := 0; i < s.slots; i++ {
+		result.Slots = append(result.Slots, PresentedSlot{Index: i + 1, Available: true})
+	}
+	return result, nil
+}
+
+func newTestAvailabilityRouter() (*AvailabilityRouter, *stubAvailabilitySource, *stubAvailabilitySource, *time.Time) {
+	moxie := &stubAvailabilitySource{name: clinic.AvailabilitySourceMoxieAPI, slots: 3}
+	browser := &stubAvailabilitySource{name: testBrowserSource, slots: 2}
+	router := NewAvailabilityRouter(logging.Default(), moxie, browser)
+	now := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	router.now = func() time.Time { return now }
+	return router, moxie, browser, &now
+}
+
+func routerRequest(cfg *clinic.Config) AvailabilityRequest {
+	return AvailabilityRequest{OrgID: cfg.OrgID, Config: cfg, Service: "Tox"}
+}
+
+func TestAvailabilityRouter_MoxieErrorsFallBackToBrowser(t *testing.T) {
+	router, moxie, browser, _ := newTestAvailabilityRouter()
+	cfg := &clinic.Config{OrgID: "org-1"}
+	moxie.err = fmt.Errorf("moxie API returned status 500")
+
+	for i := 0; i < 2; i++ {
+		result, err := router.Fetch(context.Background(), routerRequest(cfg))
+		if err != nil {
+			t.Fatalf("fetch %d: %v", i, err)
+		}
+		if result.Source != testBrowserSource || len(result.Slots) != 2 {
+			t.Fatalf("fetch %d: expected browser slots, got %+v", i, result)
+		}
+	}
+	if moxie.calls != 2 {
+		t.Fatalf("expected moxie tried first while still healthy, got %d calls", moxie.calls)
+	}
+
+	// Two failures mark Moxie unhealthy: the browser now goes first.
+	if _, err := router.Fetch(context.Background(), routerRequest(cfg)); err != nil {
+		t.Fatalf("fetch: %v", err)
+	}
+	if moxie.calls != 2 || browser.calls != 3 {
+		t.Fatalf("expected browser to be tried first, got moxie=%d browser=%d", moxie.calls, browser.calls)
+	}
+
+	// Health is tracked per clinic.
+	other := &clinic.Config{OrgID: "org-2"}
+	result, err := router.Fetch(context.Background(), routerRequest(other))
+	if err != nil || result.Source != testBrowserSource || moxie.calls != 3 {
+		t.Fatalf("expected another clinic to still try moxie first, got %+v err=%v calls=%d", result, err, moxie.calls)
+	}
+}
+
+func TestAvailabilityRouter_RecoveryShiftsBackToMoxie(t *testing.T) {
+	router, moxie, _, now := newTestAvailabilityRouter()
+	cfg := &clinic.Config{OrgID: "org-1"}
+	moxie.err = errors.New("moxie API returned status 500")
+	for i := 0; i < 3; i++ {
+		if _, err := router.Fetch(context.Background(), routerRequest(cfg)); err != nil {
+			t.Fatalf("fetch: %v", err)
+		}
+	}
+	if score := router.HealthScore("org-1", clinic.AvailabilitySourceMoxieAPI); score >= availabilityHealthyScore {
+		t.Fatalf("expected moxie unhealthy, score %.2f", score)
+	}
+
+	moxie.err = nil
+	*now = now.Add(availabilityHealthHalfLife)
+	result, err := router.Fetch(context.Background(), routerRequest(cfg))
+	if err != nil {
+		t.Fatalf("fetch: %v", err)
+	}
+	if result.Source != clinic.AvailabilitySourceMoxieAPI {
+		t.Fatalf("expected moxie to serve again after recovering, got %q", result.Source)
+	}
+	if score := router.HealthScore("org-1", clinic.AvailabilitySourceMoxieAPI); score <= availabilityHealthyScore {
+		t.Fatalf("expected success to raise moxie's score, got %.2f", score)
+	}
+}
+
+func TestAvailabilityRouter_PinnedSourceOverridesRouting(t *testing.T) {
+	router, moxie, browser, _ := newTestAvailabilityRouter()
+	cfg := &clinic.Config{OrgID: "org-1", AvailabilitySource: clinic.AvailabilitySourceMoxieAPI}
+	moxie.err = errors.New("moxie API returned status 500")
+
+	for i := 0; i < 3; i++ {
+		if _, err := router.Fetch(context.Background(), routerRequest(cfg)); err == nil || !strings.Contains(err.Error(), "status 500") {
+			t.Fatalf("expected the pinned source's error, got %v", err)
+		}
+	}
+	if browser.calls != 0 {
+		t.Fatalf("expected the browser never to be used, got %d calls", browser.calls)
+	}
+
+	cfg.AvailabilitySource = testBrowserSource
+	result, err := router.Fetch(context.Background(), routerRequest(cfg))
+	if err != nil || result.Source != testBrowserSource || moxie.calls != 3 {
+		t.Fatalf("expected browser pin to skip moxie, got %+v err=%v moxie calls=%d", result, err, moxie.calls)
+	}
+
+	cfg.AvailabilitySource = "scraper"
+	if router.Supports(cfg) {
+		t.Fatal("expected an unknown pinned source to leave the clinic unsupported")
+	}
+}
+
+func TestAvailabilityRouter_EmptyResults(t *testing.T) {
+	router, moxie, browser, _ := newTestAvailabilityRouter()
+	cfg := &clinic.Config{OrgID: "org-1"}
+	moxie.slots = 0
+
+	result, err := router.Fetch(context.Background(), routerRequest(cfg))
+	if err != nil || result.Source != testBrowserSource {
+		t.Fatalf("expected an unfiltered empty result to fall back, got %+v err=%v", result, err)
+	}
+
+	// A narrowed search may legitimately find nothing.
+	req := routerRequest(cfg)
+	req.Prefs = TimePreferences{AfterTime: "19:00"}
+	result, err = router.Fetch(context.Background(), req)
+	if err != nil || result.Source != clinic.AvailabilitySourceMoxieAPI || len(result.Slots) != 0 {
+		t.Fatalf("expected moxie's empty filtered result, got %+v err=%v", result, err)
+	}
+
+	// When every source is empty, the first empty answer is returned.
+	browser.slots = 0
+	result, err = router.Fetch(context.Background(), routerRequest(cfg))
+	if err != nil || result == nil || len(result.Slots) != 0 {
+		t.Fatalf("expected an empty result, got %+v err=%v", result, err)
+	}
+}
+
+func TestAvailabilityRouter_ServiceNotOfferedKeepsHealth(t *testing.T) {
+	router, moxie, browser, _ := newTestAvailabilityRouter()
+	cfg := &clinic.Config{OrgID: "org-1"}
+	moxie.err = fmt.Errorf("%w for service %q", errNoServiceMenuItem, "Tox")
+	browser.err = errors.New("sidecar unavailable")
+
+	_, err := router.Fetch(context.Background(), routerRequest(cfg))
+	if !errors.Is(err, errNoServiceMenuItem) {
+		t.Fatalf("expected the service-not-offered error to survive fallback, got %v", err)
+	}
+	if score := router.HealthScore("org-1", clinic.AvailabilitySourceMoxieAPI); score != 1 {
+		t.Fatalf("expected a config gap not to count against moxie, got %.2f", score)
+	}
+}
+
+func TestAvailabilityRouter_MoxieErrorsFallBackToPrefetchCache(t *testing.T) {
+	mr := miniredis.RunT(t)
+	prefetcher := NewAvailabilityPrefetcher(nil, redis.NewClient(&redis.Options{Addr: mr.Addr()}), logging.Default())
+	moxie := &stubAvailabilitySource{name: clinic.AvailabilitySourceMoxieAPI, err: fmt.Errorf("moxie API returned status 500")}
+	router := NewAvailabilityRouter(logging.Default(), moxie, NewPrefetchCacheSource(prefetcher))
+	cfg := &clinic.Config{OrgID: "org-1", MoxieConfig: &clinic.MoxieConfig{MedspaID: "999"}}
+
+	if _, err := router.Fetch(context.Background(), routerRequest(cfg)); !errors.Is(err, errPrefetchCacheMiss) {
+		t.Fatalf("expected a cache miss with nothing prefetched, got %v", err)
+	}
+
+	mon := time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)
+	tue := mon.AddDate(0, 0, 1)
+	cached, _ := json.Marshal(CachedAvailability{
+		Result: &AvailabilityResult{ExactMatch: true, Slots: []PresentedSlot{
+			{Index: 1, DateTime: mon, Available: true},
+			{Index: 2, DateTime: tue, Available: true},
+		}},
+		FetchedAt: time.Now(),
+	})
+	mr.Set(prefetchCacheKey("org-1", "Tox"), string(cached))
+
+	req := routerRequest(cfg)
+	req.Prefs = TimePreferences{DaysOfWeek: []int{int(time.Tuesday)}}
+	result, err := router.Fetch(context.Background(), req)
+	if err != nil {
+		t.Fatalf("fetch: %v", err)
+	}
+	if result.Source != AvailabilitySourcePrefetchCache {
+		t.Fatalf("expected the prefetch cache to serve, got %q", result.Source)
+	}
+	if len(result.Slots) != 1 || !result.Slots[0].DateTime.Equal(tue) || result.Slots[0].Index != 1 {
+		t.Fatalf("expected the Tuesday slot renumbered from 1, got %+v", result.Slots)
+	}
+
+	req.ProviderPreference = "Dr. Lee"
+	if _, err := router.Fetch(context.Background(), req); !errors.Is(err, errPrefetchCacheMiss) {
+		t.Fatalf("expected provider-specific lookups to miss the cache, got %v", err)
+	}
+}
@@ -17,12 +17,104 @@ type BookingServiceAdapter struct {
 
 // ConfirmBooking proxies booking confirmations if the service is configured.
 func (a BookingServiceAdapter) ConfirmBooking(ctx context.Context, orgID uuid.UUID, leadID uuid.UUID, scheduledFor *time.Time) error {
+	return a.ConfirmBookingWithDuration(ctx, orgID, leadID, scheduledFor, 0)
+}
+
+// ConfirmBookingWithDuration confirms a booking and records its length.
+func (a BookingServiceAdapter) ConfirmBookingWithDuration(ctx context.Context, orgID uuid.UUID, leadID uuid.UUID, scheduledFor *time.Time, durationMinutes int) error {
 	if a.Service == nil {
 		return nil
 	}
-	_, err := a.Service.ConfirmBooking(ctx, orgID, leadID, scheduledFor)
+	_, err := a.Service.ConfirmBooking(ctx, orgID, leadID, scheduledFor, durationMinutes)
 	if err != nil {
 		return fmt.Errorf("conversation: ConfirmBooking: %w", err)
 	}
 	return nil
 }
+
+// FlagDisputed proxies dispute flagging if the service is configured.
+func (a BookingServiceAdapter) FlagDisputed(ctx context.Context, orgID uuid.UUID, leadID uuid.UUID) (int64, error) {
+	if a.Service == nil {
+		return 0, nil
+	}
+	n, err := a.Service.FlagDisputed(ctx, orgID, leadID)
+	if err != nil {
+		return 0, fmt.Errorf("conversation: FlagDisputed: %w", err)
+	}
+	return n, nil
+}
+
+// RecordBookingProvider proxies provider recording if the service is configured.
+func (a BookingServiceAdapter) RecordBookingProvider(ctx context.Context, orgID uuid.UUID, leadID uuid.UUID, providerID string) error {
+	if a.Service == nil {
+		return nil
+	}
+	if err := a.Service.RecordProvider(ctx, orgID, leadID, providerID); err != nil {
+		return fmt.Errorf("conversation: RecordBookingProvider: %w", err)
+	}
+	return nil
+}
+
+// MarkBookingManuallyOffered proxies manual-offer flagging if the service is configured.
+func (a BookingServiceAdapter) MarkBookingManuallyOffered(ctx context.Context, orgID uuid.UUID, leadID uuid.UUID) error {
+	if a.Service == nil {
+		return nil
+	}
+	if err := a.Service.MarkManuallyOffered(ctx, orgID, leadID); err != nil {
+		return fmt.Errorf("conversation: MarkBookingManuallyOffered: %w", err)
+	}
+	return nil
+}
+
+// ProviderBookingCounts proxies per-provider booking counts if the service is configured.
+func (a BookingServiceAdapter) ProviderBookingCounts(ctx context.Context, orgID uuid.UUID, since time.Time) (map[string]int64, error) {
+	if a.Service == nil {
+		return nil, nil
+	}
+	counts, err := a.Service.ProviderCounts(ctx, orgID, since)
+	if err != nil {
+		return nil, fmt.Errorf("conversation: ProviderBookingCounts: %w", err)
+	}
+	return counts, nil
+}
+
+// UpcomingAppointment returns when the lead's next booking is scheduled, or
+// nil when none is on file.
+func (a BookingServiceAdapter) UpcomingAppointment(ctx context.Context, orgID uuid.UUID, leadID uuid.UUID) (*time.Time, error) {
+	if a.Service == nil {
+		return nil, nil
+	}
+	row, err := a.Service.NextUpcoming(ctx, orgID, leadID, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("conversation: UpcomingAppointment: %w", err)
+	}
+	if row == nil || !row.ScheduledFor.Valid {
+		return nil, nil
+	}
+	scheduled := row.ScheduledFor.Time
+	return &scheduled, nil
+}
+
+// LeadBookings returns the times of the lead's upcoming bookings. Bookings
+// without a recorded length are assumed to take defaultBookedDuration.
+func (a BookingServiceAdapter) LeadBookings(ctx context.Context, orgID uuid.UUID, leadID uuid.UUID) ([]BookedAppointment, error) {
+	if a.Service == nil {
+		return nil, nil
+	}
+	rows, err := a.Service.Upcoming(ctx, orgID, leadID, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("conversation: LeadBookings: %w", err)
+	}
+	booked := make([]BookedAppointment, 0, len(rows))
+	for _, row := range rows {
+		if !row.ScheduledFor.Valid {
+			continue
+		}
+		duration := defaultBookedDuration
+		if row.DurationMinutes.Valid && row.DurationMinutes.Int32 > 0 {
+			duration = time.Duration(row.DurationMinutes.Int32) * time.Minute
+		}
+		booked = append(booked, BookedAppointment{Start: row.ScheduledFor.Time, End: row.ScheduledFor.Time.Add(duration)})
+	}
+	return booked, nil
+}
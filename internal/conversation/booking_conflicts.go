@@ -0,0 +1,104 @@
+package conversation
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/clinic"
+)
+
+// defaultBookedDuration is assumed for a booking or slot whose length isn't
+// known, so a conflict check still blocks the hour it starts in.
+const defaultBookedDuration = time.Hour
+
+// BookedAppointment is the time a lead's existing booking occupies.
+type BookedAppointment struct {
+	Start time.Time
+	End   time.Time
+}
+
+// LeadBookingLookup lists a lead's upcoming non-cancelled bookings. An
+// AppointmentLookup that also implements it keeps availability from offering
+// times the patient is already booked for.
+type LeadBookingLookup interface {
+	LeadBookings(ctx context.Context, orgID uuid.UUID, leadID uuid.UUID) ([]BookedAppointment, error)
+}
+
+// withoutBookingConflicts drops slots that overlap one of the lead's
+// bookings and re-indexes the rest. A slot that starts as a booking ends (or
+// ends as it starts) is kept only when backToBack is set; otherwise the
+// patient isn't offered a time that runs straight into another treatment.
+// Slots without an end use slotDuration.
+func withoutBookingConflicts(slots []PresentedSlot, booked []BookedAppointment, slotDuration time.Duration, backToBack bool) []PresentedSlot {
+	if len(slots) == 0 || len(booked) == 0 {
+		return slots
+	}
+	if slotDuration <= 0 {
+		slotDuration = defaultBookedDuration
+	}
+	kept := make([]PresentedSlot, 0, len(slots))
+	for _, slot := range slots {
+		end := slot.EndDateTime
+		if !end.After(slot.DateTime) {
+			end = slot.DateTime.Add(slotDuration)
+		}
+		conflict := false
+		for _, b := range booked {
+			if backToBack {
+				conflict = slot.DateTime.Before(b.End) && b.Start.Before(end)
+			} else {
+				conflict = !slot.DateTime.After(b.End) && !b.Start.After(end)
+			}
+			if conflict {
+				break
+			}
+		}
+		if !conflict {
+			slot.Index = len(kept) + 1
+			kept = append(kept, slot)
+		}
+	}
+	return kept
+}
+
+// leadBookings returns the lead's upcoming bookings, or nil when none are on
+// file or they can't be looked up.
+func (s *LLMService) leadBookings(ctx context.Context, orgID, leadID string) []BookedAppointment {
+	lookup, ok := s.appointments.(LeadBookingLookup)
+	if !ok {
+		return nil
+	}
+	orgUUID, orgErr := uuid.Parse(strings.TrimSpace(orgID))
+	leadUUID, leadErr := uuid.Parse(strings.TrimSpace(leadID))
+	if orgErr != nil || leadErr != nil {
+		return nil
+	}
+	booked, err := lookup.LeadBookings(ctx, orgUUID, leadUUID)
+	if err != nil {
+		s.log(ctx).Warn("failed to look up lead bookings; availability not checked for conflicts", "org_id", orgID, "lead_id", leadID, "error", err)
+		return nil
+	}
+	return booked
+}
+
+// filterLeadBookingConflicts removes slots that clash with the lead's own
+// bookings. It runs on every availability source's slots so a returning
+// patient booking a second service isn't offered the time of the first.
+func (s *LLMService) filterLeadBookingConflicts(ctx context.Context, cfg *clinic.Config, orgID, leadID, service string, backToBack bool, slots []PresentedSlot) []PresentedSlot {
+	if len(slots) == 0 {
+		return slots
+	}
+	booked := s.leadBookings(ctx, orgID, leadID)
+	if len(booked) == 0 {
+		return slots
+	}
+	kept := withoutBookingConflicts(slots, booked, cfg.DurationForService(service), backToBack)
+	if len(kept) < len(slots) {
+		s.log(ctx).Info("removed slots that conflict with the lead's bookings",
+			"lead_id", leadID, "removed", len(slots)-len(kept), "back_to_back", backToBack)
+	}
+	return kept
+}
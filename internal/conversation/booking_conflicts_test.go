@@ -0,0 +1,114 @@
+package conversation
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/clinic"
+	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
+)
+
+type stubLeadBookings struct {
+	stubAppointmentLookup
+	booked []BookedAppointment
+}
+
+func (s *stubLeadBookings) LeadBookings(ctx context.Context, orgID uuid.UUID, leadID uuid.UUID) ([]BookedAppointment, error) {
+	return s.booked, nil
+}
+
+func conflictTestSlots(day time.Time, hours ...int) []PresentedSlot {
+	slots := make([]PresentedSlot, 0, len(hours))
+	for i, h := range hours {
+		start := day.Add(time.Duration(h) * time.Hour)
+		slots = append(slots, PresentedSlot{Index: i + 1, DateTime: start, Available: true})
+	}
+	return slots
+}
+
+func slotHours(slots []PresentedSlot) []int {
+	hours := make([]int, 0, len(slots))
+	for _, s := range slots {
+		hours = append(hours, s.DateTime.Hour())
+	}
+	return hours
+}
+
+func TestWithoutBookingConflicts(t *testing.T) {
+	day := time.Date(2026, 10, 22, 0, 0, 0, 0, time.UTC)
+	botox := BookedAppointment{Start: day.Add(14 * time.Hour), End: day.Add(14*time.Hour + 30*time.Minute)}
+
+	cases := []struct {
+		name       string
+		booked     []BookedAppointment
+		backToBack bool
+		want       []int
+	}{
+		{"overlapping slot removed", []BookedAppointment{botox}, false, []int{11, 16}},
+		{"adjacent slot kept for back to back", []BookedAppointment{botox}, true, []int{11, 13, 16}},
+		{"no bookings", nil, false, []int{11, 13, 14, 16}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			// 1-hour slots: 1 PM ends as the 2 PM booking starts; 2 PM overlaps it.
+			got := withoutBookingConflicts(conflictTestSlots(day, 11, 13, 14, 16), tc.booked, time.Hour, tc.backToBack)
+			hours := slotHours(got)
+			if len(hours) != len(tc.want) {
+				t.Fatalf("slots = %v, want %v", hours, tc.want)
+			}
+			for i := range hours {
+				if hours[i] != tc.want[i] || got[i].Index != i+1 {
+					t.Fatalf("slots = %v (index %d), want %v", hours, got[i].Index, tc.want)
+				}
+			}
+		})
+	}
+}
+
+func TestWithoutBookingConflictsUsesSlotEnd(t *testing.T) {
+	day := time.Date(2026, 10, 22, 0, 0, 0, 0, time.UTC)
+	booked := []BookedAppointment{{Start: day.Add(14 * time.Hour), End: day.Add(15 * time.Hour)}}
+	// A 90-minute facial at 1 PM runs into the 2 PM booking even though the
+	// default slot length wouldn't.
+	slot := PresentedSlot{Index: 1, DateTime: day.Add(13 * time.Hour), EndDateTime: day.Add(14*time.Hour + 30*time.Minute)}
+	if got := withoutBookingConflicts([]PresentedSlot{slot}, booked, 30*time.Minute, true); len(got) != 0 {
+		t.Fatalf("expected the long slot to conflict, got %v", slotHours(got))
+	}
+}
+
+func TestFilterLeadBookingConflicts(t *testing.T) {
+	day := time.Date(2026, 10, 22, 0, 0, 0, 0, time.UTC)
+	cfg := &clinic.Config{ServiceDurationMinutes: map[string]int{"facial": 60}}
+	orgID, leadID := uuid.NewString(), uuid.NewString()
+	lookup := &stubLeadBookings{booked: []BookedAppointment{{Start: day.Add(14 * time.Hour), End: day.Add(15 * time.Hour)}}}
+	svc := &LLMService{logger: logging.Default(), appointments: lookup}
+	slots := conflictTestSlots(day, 11, 14, 16)
+
+	if got := slotHours(svc.filterLeadBookingConflicts(context.Background(), cfg, orgID, leadID, "Facial", false, slots)); len(got) != 2 || got[0] != 11 || got[1] != 16 {
+		t.Fatalf("slots = %v, want [11 16]", got)
+	}
+	// Without a lead, or with a lookup that can't list bookings, slots pass through.
+	if got := svc.filterLeadBookingConflicts(context.Background(), cfg, orgID, "", "Facial", false, slots); len(got) != 3 {
+		t.Fatalf("expected slots unchanged without a lead, got %v", slotHours(got))
+	}
+	svc.appointments = &stubAppointmentLookup{}
+	if got := svc.filterLeadBookingConflicts(context.Background(), cfg, orgID, leadID, "Facial", false, slots); len(got) != 3 {
+		t.Fatalf("expected slots unchanged without booking lookup, got %v", slotHours(got))
+	}
+}
+
+func TestExtractPreferencesBackToBack(t *testing.T) {
+	for msg, want := range map[string]bool{
+		"Can I do a facial back-to-back with my botox?": true,
+		"I'd like it right after my botox appointment":  true,
+		"I'd like a facial on Thursday afternoon":       false,
+	} {
+		prefs, _ := extractPreferences([]ChatMessage{{Role: ChatRoleUser, Content: msg}}, nil)
+		if prefs.BackToBack != want {
+			t.Errorf("%q: BackToBack = %v, want %v", msg, prefs.BackToBack, want)
+		}
+	}
+}
@@ -11,7 +11,7 @@ var callbackPatterns = []*regexp.Regexp{
 	regexp.MustCompile(`(?i)\bcallback\b`),
 	regexp.MustCompile(`(?i)\bprefer\s*(a\s*)?call\b`),
 	regexp.MustCompile(`(?i)\brather\s*(talk|speak|call)\b`),
-	regexp.MustCompile(`(?i)\bcan\s*(you|someone)\s*call\s*(me)?\b`),
+	regexp.MustCompile(`(?i)\bcan\s*(you|someone)\s*(just\s*)?call\s*(me)?\b`),
 	regexp.MustCompile(`(?i)\bwant\s*(a\s*)?call\b`),
 	regexp.MustCompile(`(?i)\bjust\s*call\b`),
 	regexp.MustCompile(`(?i)\bphone\s*call\b`),
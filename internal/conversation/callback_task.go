@@ -0,0 +1,254 @@
+package conversation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/tenancy"
+)
+
+// Callback task lifecycle states stored on callback_tasks.status.
+const (
+	CallbackTaskStatusOpen = "open"
+	// CallbackTaskStatusDone means an operator completed the call.
+	CallbackTaskStatusDone = "done"
+	// CallbackTaskStatusResumed means the patient went back to texting booking
+	// details before anyone called, so the AI picked the conversation back up.
+	CallbackTaskStatusResumed = "resumed"
+)
+
+// Reasons a callback task was opened, stored on callback_tasks.reason.
+const (
+	// CallbackTaskReasonRequested means the patient asked to be called.
+	CallbackTaskReasonRequested = "callback_request"
+	// CallbackTaskReasonFrustration means the AI handed a frustrated patient
+	// to staff. These tasks stay open until an operator resolves them.
+	CallbackTaskReasonFrustration = "frustration"
+)
+
+// ErrCallbackTaskNotFound is returned when no open callback task matches.
+var ErrCallbackTaskNotFound = errors.New("conversation: callback task not found")
+
+// CallbackTask is an operator follow-up created when a patient asks to be
+// called instead of continuing over SMS. While a task is open the AI stops
+// sending qualification prompts on that conversation.
+type CallbackTask struct {
+	ID              uuid.UUID  `json:"id"`
+	OrgID           string     `json:"org_id"`
+	ConversationID  string     `json:"conversation_id"`
+	LeadID          string     `json:"lead_id,omitempty"`
+	Phone           string     `json:"phone"`
+	RequestedAt     time.Time  `json:"requested_at"`
+	PreferredWindow string     `json:"preferred_window,omitempty"`
+	PatientMessage  string     `json:"patient_message,omitempty"`
+	Reason          string     `json:"reason"`
+	Status          string     `json:"status"`
+	ResolvedAt      *time.Time `json:"resolved_at,omitempty"`
+}
+
+// CallbackTaskStore persists operator callback tasks.
+type CallbackTaskStore interface {
+	Create(ctx context.Context, task *CallbackTask) error
+	// OpenForConversation returns the open task for a conversation, or nil.
+	OpenForConversation(ctx context.Context, conversationID string) (*CallbackTask, error)
+	// Resolve closes an open task with the given status.
+	Resolve(ctx context.Context, scope tenancy.OrgScope, id uuid.UUID, status string) (*CallbackTask, error)
+	ListOpen(ctx context.Context, scope tenancy.OrgScope) ([]CallbackTask, error)
+}
+
+type callbackTaskDB interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+// PGCallbackTaskStore stores callback tasks in PostgreSQL.
+type PGCallbackTaskStore struct {
+	db callbackTaskDB
+}
+
+// NewPGCallbackTaskStore builds a Postgres-backed CallbackTaskStore.
+func NewPGCallbackTaskStore(db *pgxpool.Pool) *PGCallbackTaskStore {
+	if db == nil {
+		panic("conversation: pgx pool cannot be nil")
+	}
+	return &PGCallbackTaskStore{db: db}
+}
+
+var _ CallbackTaskStore = (*PGCallbackTaskStore)(nil)
+
+const callbackTaskColumns = `id, org_id, conversation_id, COALESCE(lead_id, ''), phone, requested_at,
+	COALESCE(preferred_window, ''), COALESCE(patient_message, ''), reason, status, resolved_at`
+
+// Create inserts an open task, filling in ID, RequestedAt, and Reason when
+// unset.
+func (s *PGCallbackTaskStore) Create(ctx context.Context, task *CallbackTask) error {
+	if task == nil {
+		return errors.New("conversation: callback task cannot be nil")
+	}
+	if strings.TrimSpace(task.OrgID) == "" || strings.TrimSpace(task.ConversationID) == "" {
+		return errors.New("conversation: callback task requires org and conversation")
+	}
+	if task.ID == uuid.Nil {
+		task.ID = uuid.New()
+	}
+	if task.RequestedAt.IsZero() {
+		task.RequestedAt = time.Now().UTC()
+	}
+	if task.Reason == "" {
+		task.Reason = CallbackTaskReasonRequested
+	}
+	task.Status = CallbackTaskStatusOpen
+	query := `
+		INSERT INTO callback_tasks (id, org_id, conversation_id, lead_id, phone, requested_at, preferred_window, patient_message, reason, status)
+		VALUES ($1, $2, $3, NULLIF($4, ''), $5, $6, NULLIF($7, ''), NULLIF($8, ''), $9, $10)
+	`
+	if _, err := s.db.Exec(ctx, query, task.ID, task.OrgID, task.ConversationID, task.LeadID, task.Phone,
+		task.RequestedAt, task.PreferredWindow, task.PatientMessage, task.Reason, task.Status); err != nil {
+		return fmt.Errorf("conversation: create callback task: %w", err)
+	}
+	return nil
+}
+
+// OpenForConversation returns the most recent open task, or nil when the
+// conversation isn't waiting on a callback.
+func (s *PGCallbackTaskStore) OpenForConversation(ctx context.Context, conversationID string) (*CallbackTask, error) {
+	query := `SELECT ` + callbackTaskColumns + `
+		FROM callback_tasks
+		WHERE conversation_id = $1 AND status = 'open'
+		ORDER BY requested_at DESC
+		LIMIT 1`
+	task, err := scanCallbackTask(s.db.QueryRow(ctx, query, conversationID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("conversation: open callback task: %w", err)
+	}
+	return task, nil
+}
+
+// Resolve closes an open task. Returns ErrCallbackTaskNotFound if the task
+// doesn't exist in scope or was already resolved.
+func (s *PGCallbackTaskStore) Resolve(ctx context.Context, scope tenancy.OrgScope, id uuid.UUID, status string) (*CallbackTask, error) {
+	if err := scope.Err(); err != nil {
+		return nil, err
+	}
+	query := `
+		UPDATE callback_tasks SET status = $2, resolved_at = NOW()
+		WHERE id = $1 AND status = 'open'`
+	args := []any{id, status}
+	if cond, condArgs := scope.Where("org_id", 3); cond != "" {
+		query += " AND " + cond
+		args = append(args, condArgs...)
+	}
+	query += " RETURNING " + callbackTaskColumns
+	task, err := scanCallbackTask(s.db.QueryRow(ctx, query, args...))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrCallbackTaskNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("conversation: resolve callback task: %w", err)
+	}
+	return task, nil
+}
+
+// ListOpen returns the open tasks in scope, oldest first.
+func (s *PGCallbackTaskStore) ListOpen(ctx context.Context, scope tenancy.OrgScope) ([]CallbackTask, error) {
+	if err := scope.Err(); err != nil {
+		return nil, err
+	}
+	query := `SELECT ` + callbackTaskColumns + `
+		FROM callback_tasks
+		WHERE status = 'open'`
+	var args []any
+	if cond, condArgs := scope.Where("org_id", 1); cond != "" {
+		query += " AND " + cond
+		args = condArgs
+	}
+	query += " ORDER BY requested_at ASC"
+	rows, err := s.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("conversation: list callback tasks: %w", err)
+	}
+	defer rows.Close()
+
+	tasks := []CallbackTask{}
+	for rows.Next() {
+		task, err := scanCallbackTask(rows)
+		if err != nil {
+			return nil, fmt.Errorf("conversation: scan callback task: %w", err)
+		}
+		tasks = append(tasks, *task)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("conversation: list callback tasks: %w", err)
+	}
+	return tasks, nil
+}
+
+func scanCallbackTask(row pgx.Row) (*CallbackTask, error) {
+	var task CallbackTask
+	if err := row.Scan(&task.ID, &task.OrgID, &task.ConversationID, &task.LeadID, &task.Phone, &task.RequestedAt,
+		&task.PreferredWindow, &task.PatientMessage, &task.Reason, &task.Status, &task.ResolvedAt); err != nil {
+		return nil, err
+	}
+	return &task, nil
+}
+
+// callbackWindowPattern picks out when a patient would like to be called,
+// e.g. "tomorrow after 5pm", "this afternoon", "between 2 and 4".
+var callbackWindowPattern = regexp.MustCompile(`(?i)\b(` +
+	`between\s+\d{1,2}(:\d{2})?\s*(am|pm)?\s*(and|-|to)\s*\d{1,2}(:\d{2})?\s*(am|pm)?` +
+	`|(after|before|around|at|by)\s+(\d{1,2}(:\d{2})?\s*(am|pm)?|noon|lunch|work)` +
+	`|this\s+(morning|afternoon|evening)` +
+	`|(in\s+the\s+)?(morning|afternoon|evening)` +
+	`|today|tonight|tomorrow` +
+	`|(mon|tues|wednes|thurs|fri|satur|sun)day` +
+	`)\b`)
+
+// ParseCallbackWindow extracts the patient's preferred callback time from
+// their message, or "" when none is given.
+func ParseCallbackWindow(message string) string {
+	matches := callbackWindowPattern.FindAllString(message, -1)
+	if len(matches) == 0 {
+		return ""
+	}
+	for i, m := range matches {
+		matches[i] = strings.ToLower(strings.Join(strings.Fields(m), " "))
+	}
+	return strings.Join(matches, " ")
+}
+
+// bookingIntentPattern matches messages that move the booking flow forward.
+var bookingIntentPattern = regexp.MustCompile(`(?i)\b(book|booking|appointment|appt|schedule|available|availability|openings?)\b`)
+
+var callMentionPattern = regexp.MustCompile(`(?i)\bcall`)
+
+// resumesBookingFlow reports whether a message from a patient waiting on a
+// callback carries booking details (a service, a day or time, or explicit
+// booking intent), meaning they'd rather keep going by text.
+func resumesBookingFlow(message string, serviceAliases map[string]string) bool {
+	message = strings.TrimSpace(message)
+	// Anything still about the call ("call me after 5 instead") keeps waiting.
+	if message == "" || IsCallbackRequest(message) || callMentionPattern.MatchString(message) {
+		return false
+	}
+	if bookingIntentPattern.MatchString(message) {
+		return true
+	}
+	if matchService(strings.ToLower(message), serviceAliases) != "" {
+		return true
+	}
+	prefs := ExtractTimePreferences(message)
+	return len(prefs.DaysOfWeek) > 0 || prefs.AfterTime != "" || prefs.BeforeTime != "" || prefs.HasDateRange()
+}
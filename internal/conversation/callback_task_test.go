@@ -0,0 +1,295 @@
+package conversation
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/pashagolub/pgxmock/v4"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/clinic"
+	"github.com/wolfman30/medspa-ai-platform/internal/notify"
+	"github.com/wolfman30/medspa-ai-platform/internal/tenancy"
+	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
+)
+
+func TestIsCallbackRequest_CallMeVsCallYou(t *testing.T) {
+	cases := map[string]bool{
+		"just call me":                  true,
+		"can someone just call me?":     true,
+		"Can you call me after 5pm?":    true,
+		"can I call you":                false,
+		"Can I call you tomorrow?":      false,
+		"what number can I call you at": false,
+	}
+	for msg, want := range cases {
+		if got := IsCallbackRequest(msg); got != want {
+			t.Errorf("IsCallbackRequest(%q) = %v, want %v", msg, got, want)
+		}
+	}
+}
+
+func TestParseCallbackWindow(t *testing.T) {
+	cases := map[string]string{
+		"can someone just call me?":            "",
+		"call me tomorrow after 5pm":           "tomorrow after 5pm",
+		"please call me this afternoon":        "this afternoon",
+		"Call me between 2 and 4":              "between 2 and 4",
+		"just call me Friday morning":          "friday morning",
+		"call me at 555-1234":                  "",
+		"I'd rather talk, anytime after lunch": "after lunch",
+	}
+	for msg, want := range cases {
+		if got := ParseCallbackWindow(msg); got != want {
+			t.Errorf("ParseCallbackWindow(%q) = %q, want %q", msg, got, want)
+		}
+	}
+}
+
+func TestResumesBookingFlow(t *testing.T) {
+	cases := map[string]bool{
+		"actually I'd like to book Botox": true,
+		"Thursdays after 4pm work for me": true,
+		"lip filler":                      true,
+		"ok thanks":                       false,
+		"still waiting for that call":     false,
+		"call me tomorrow after 5":        false,
+	}
+	for msg, want := range cases {
+		if got := resumesBookingFlow(msg, nil); got != want {
+			t.Errorf("resumesBookingFlow(%q) = %v, want %v", msg, got, want)
+		}
+	}
+}
+
+type memoryCallbackTasks struct {
+	mu    sync.Mutex
+	tasks []*CallbackTask
+}
+
+func (m *memoryCallbackTasks) Create(ctx context.Context, task *CallbackTask) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	task.ID = uuid.New()
+	task.Status = CallbackTaskStatusOpen
+	m.tasks = append(m.tasks, task)
+	return nil
+}
+
+func (m *memoryCallbackTasks) OpenForConversation(ctx context.Context, conversationID string) (*CallbackTask, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, task := range m.tasks {
+		if task.ConversationID == conversationID && task.Status == CallbackTaskStatusOpen {
+			return task, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *memoryCallbackTasks) Resolve(ctx context.Context, scope tenancy.OrgScope, id uuid.UUID, status string) (*CallbackTask, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, task := range m.tasks {
+		if task.ID == id && scope.Allows(task.OrgID) && task.Status == CallbackTaskStatusOpen {
+			task.Status = status
+			return task, nil
+		}
+	}
+	return nil, ErrCallbackTaskNotFound
+}
+
+func (m *memoryCallbackTasks) ListOpen(ctx context.Context, scope tenancy.OrgScope) ([]CallbackTask, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var open []CallbackTask
+	for _, task := range m.tasks {
+		if scope.Allows(task.OrgID) && task.Status == CallbackTaskStatusOpen {
+			open = append(open, *task)
+		}
+	}
+	return open, nil
+}
+
+type recordingCallbackNotifier struct {
+	requests []notify.CallbackRequest
+}
+
+func (r *recordingCallbackNotifier) NotifyCallbackRequested(ctx context.Context, orgID string, req notify.CallbackRequest) error {
+	r.requests = append(r.requests, req)
+	return nil
+}
+
+func sendWorkerSMS(t *testing.T, worker *Worker, jobID, text string) {
+	t.Helper()
+	body, _ := json.Marshal(queuePayload{
+		ID:   jobID,
+		Kind: jobTypeMessage,
+		Message: MessageRequest{
+			ConversationID: "sms:org-1:12223334444",
+			OrgID:          "org-1",
+			LeadID:         "lead-1",
+			Message:        text,
+			Channel:        ChannelSMS,
+			From:           "+12223334444",
+			To:             "+15556667777",
+		},
+	})
+	worker.handleMessage(context.Background(), queueMessage{ID: jobID, Body: string(body), ReceiptHandle: "rh-" + jobID})
+}
+
+func TestWorkerCallbackTask_CreatesTaskAndPausesUntilDone(t *testing.T) {
+	service := &recordingService{}
+	messenger := &recordingMessenger{}
+	tasks := &memoryCallbackTasks{}
+	notifier := &recordingCallbackNotifier{}
+	worker := NewWorker(service, newScriptedQueue(), &stubJobUpdater{}, messenger, nil, logging.Default(),
+		WithCallbackTasks(tasks, notifier))
+
+	sendWorkerSMS(t, worker, "job-1", "can someone just call me tomorrow after 5pm?")
+
+	if service.messageCount() != 0 {
+		t.Fatalf("expected LLM to be skipped, got %d calls", service.messageCount())
+	}
+	if len(tasks.tasks) != 1 {
+		t.Fatalf("expected 1 callback task, got %d", len(tasks.tasks))
+	}
+	task := tasks.tasks[0]
+	if task.LeadID != "lead-1" || task.Phone != "+12223334444" || task.PreferredWindow != "tomorrow after 5pm" {
+		t.Fatalf("unexpected task: %+v", task)
+	}
+	if task.RequestedAt.IsZero() {
+		t.Fatal("expected requested_at to be set")
+	}
+	if len(notifier.requests) != 1 || notifier.requests[0].PreferredWindow != "tomorrow after 5pm" {
+		t.Fatalf("expected operator notification, got %+v", notifier.requests)
+	}
+	replies := messenger.allReplies()
+	if len(replies) != 1 || !strings.Contains(replies[0].Body, "call you shortly") || !strings.Contains(replies[0].Body, "tomorrow after 5pm") {
+		t.Fatalf("unexpected confirmation: %+v", replies)
+	}
+
+	// Small talk while waiting doesn't wake the AI.
+	sendWorkerSMS(t, worker, "job-2", "ok thanks")
+	if service.messageCount() != 0 || len(messenger.allReplies()) != 1 {
+		t.Fatalf("expected conversation to stay paused")
+	}
+
+	if _, err := tasks.Resolve(context.Background(), tenancy.ForOrg("org-1"), task.ID, CallbackTaskStatusDone); err != nil {
+		t.Fatalf("resolve: %v", err)
+	}
+	sendWorkerSMS(t, worker, "job-3", "ok thanks")
+	if service.messageCount() != 1 {
+		t.Fatalf("expected LLM after task done, got %d calls", service.messageCount())
+	}
+}
+
+func TestWorkerCallbackTask_BookingInfoResumes(t *testing.T) {
+	service := &recordingService{}
+	tasks := &memoryCallbackTasks{}
+	worker := NewWorker(service, newScriptedQueue(), &stubJobUpdater{}, &recordingMessenger{}, nil, logging.Default(),
+		WithCallbackTasks(tasks, nil))
+
+	sendWorkerSMS(t, worker, "job-1", "just call me")
+	sendWorkerSMS(t, worker, "job-2", "actually can I book Botox for Thursday after 4pm?")
+
+	if service.messageCount() != 1 {
+		t.Fatalf("expected LLM to resume, got %d calls", service.messageCount())
+	}
+	if got := tasks.tasks[0].Status; got != CallbackTaskStatusResumed {
+		t.Fatalf("task status = %q, want %q", got, CallbackTaskStatusResumed)
+	}
+}
+
+func TestCallbackConfirmation_UsesBusinessHours(t *testing.T) {
+	// Saturday 10pm UTC; clinic only opens weekdays 9-5.
+	now := time.Date(2026, 3, 7, 22, 0, 0, 0, time.UTC)
+	weekday := &clinic.DayHours{Open: "09:00", Close: "17:00"}
+	cfg := &clinic.Config{Timezone: "UTC", BusinessHours: clinic.BusinessHours{
+		Monday: weekday, Tuesday: weekday, Wednesday: weekday, Thursday: weekday, Friday: weekday,
+	}}
+	got := callbackConfirmation(cfg, "", now)
+	if !strings.Contains(got, "on Monday around 9 AM") {
+		t.Fatalf("confirmation = %q", got)
+	}
+}
+
+func TestPGCallbackTaskStore(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("pgxmock: %v", err)
+	}
+	defer mock.Close()
+	store := &PGCallbackTaskStore{db: mock}
+	ctx := context.Background()
+
+	task := &CallbackTask{OrgID: "org-1", ConversationID: "conv-1", Phone: "+12223334444", PreferredWindow: "after 5pm"}
+	mock.ExpectExec("INSERT INTO callback_tasks").
+		WithArgs(pgxmock.AnyArg(), "org-1", "conv-1", "", "+12223334444", pgxmock.AnyArg(), "after 5pm", "", CallbackTaskReasonRequested, CallbackTaskStatusOpen).
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+	if err := store.Create(ctx, task); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if task.ID == uuid.Nil || task.Status != CallbackTaskStatusOpen {
+		t.Fatalf("unexpected task after create: %+v", task)
+	}
+
+	mock.ExpectQuery("FROM callback_tasks").WithArgs("conv-2").
+		WillReturnRows(pgxmock.NewRows([]string{"id", "org_id", "conversation_id", "lead_id", "phone", "requested_at",
+			"preferred_window", "patient_message", "reason", "status", "resolved_at"}))
+	open, err := store.OpenForConversation(ctx, "conv-2")
+	if err != nil || open != nil {
+		t.Fatalf("expected no open task, got %+v, %v", open, err)
+	}
+
+	mock.ExpectQuery("UPDATE callback_tasks").WithArgs(task.ID, CallbackTaskStatusDone, "org-1").
+		WillReturnRows(pgxmock.NewRows([]string{"id", "org_id", "conversation_id", "lead_id", "phone", "requested_at",
+			"preferred_window", "patient_message", "reason", "status", "resolved_at"}))
+	if _, err := store.Resolve(ctx, tenancy.ForOrg("org-1"), task.ID, CallbackTaskStatusDone); !errors.Is(err, ErrCallbackTaskNotFound) {
+		t.Fatalf("expected ErrCallbackTaskNotFound, got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestHandler_CompleteCallbackTask(t *testing.T) {
+	tasks := &memoryCallbackTasks{}
+	task := &CallbackTask{OrgID: "org-1", ConversationID: "conv-1", Phone: "+12223334444"}
+	_ = tasks.Create(context.Background(), task)
+
+	h := NewHandler(nil, nil, nil, nil, logging.Default())
+	h.SetCallbackTaskStore(tasks)
+	r := chi.NewRouter()
+	r.Get("/admin/clinics/{orgID}/callback-tasks", h.ListCallbackTasks)
+	r.Post("/admin/clinics/{orgID}/callback-tasks/{taskID}/done", h.CompleteCallbackTask)
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, orgRequest("org-1", http.MethodGet, "/admin/clinics/org-1/callback-tasks", nil))
+	var list CallbackTasksResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &list); err != nil || len(list.CallbackTasks) != 1 {
+		t.Fatalf("list: status=%d body=%s", rec.Code, rec.Body.String())
+	}
+
+	donePath := "/admin/clinics/org-1/callback-tasks/" + task.ID.String() + "/done"
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, orgRequest("org-1", http.MethodPost, donePath, nil))
+	if rec.Code != http.StatusOK || task.Status != CallbackTaskStatusDone {
+		t.Fatalf("done: status=%d task=%+v", rec.Code, task)
+	}
+
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, orgRequest("org-1", http.MethodPost, donePath, nil))
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("second done: expected 404, got %d", rec.Code)
+	}
+}
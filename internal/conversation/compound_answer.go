@@ -0,0 +1,105 @@
+package conversation
+
+import (
+	"regexp"
+	"strings"
+)
+
+// ---------- compound answers ----------
+
+// When the assistant bundles questions ("Are you a new patient, and what days
+// work for you?") the patient answers them in one text ("New, weekday
+// mornings"). Each extractor checks the clauses of that reply on its own so
+// one answer doesn't hide the others.
+
+// answerClauseSepRE splits a reply at commas, sentence ends, dashes and "and"/"also".
+var answerClauseSepRE = regexp.MustCompile(`(?i)\s*(?:[,;!?\n]|\.(?:\s|$)|\s[-–—]\s|\band\b|\balso\b)\s*`)
+
+// splitCompoundAnswer returns the non-empty clauses of a reply in order.
+func splitCompoundAnswer(reply string) []string {
+	var clauses []string
+	for _, part := range answerClauseSepRE.Split(reply, -1) {
+		if part = strings.TrimSpace(part); part != "" {
+			clauses = append(clauses, part)
+		}
+	}
+	return clauses
+}
+
+// bareYesNo reports whether a clause is only "yes" or "no". Such a clause
+// answers whichever yes/no question came first, so extractors only credit it
+// when it leads the reply.
+func bareYesNo(clause string) bool {
+	switch strings.Trim(strings.ToLower(strings.TrimSpace(clause)), ".,!?") {
+	case "yes", "yeah", "yep", "yup", "sure", "no", "nope", "and yes", "and no":
+		return true
+	}
+	return false
+}
+
+// clauseAnswersSchedule reports whether a clause names days or times.
+func clauseAnswersSchedule(clause string) bool {
+	lower := strings.ToLower(clause)
+	for _, kw := range []string{"weekday", "weekend", "morning", "afternoon", "evening", "noon", "anytime", "any time", "any day", "whenever", "flexible", "tomorrow", "today", "next week", "this week"} {
+		if strings.Contains(lower, kw) {
+			return true
+		}
+	}
+	if dayAbbrevRE.MatchString(lower) {
+		return true
+	}
+	prefs := ExtractTimePreferences(lower)
+	return prefs.AfterTime != "" || prefs.BeforeTime != "" || prefs.HasDateRange()
+}
+
+// patientTypeFromAnswer reads the patient type from a reply to a patient-type
+// question, including when it is one clause of a compound answer.
+func patientTypeFromAnswer(reply string) string {
+	if pt := normalizePatientTypeReply(reply); pt != "" {
+		return pt
+	}
+	for i, clause := range splitCompoundAnswer(reply) {
+		if i > 0 && bareYesNo(clause) {
+			continue
+		}
+		if pt := normalizePatientTypeReply(clause); pt != "" {
+			return pt
+		}
+	}
+	return ""
+}
+
+// providerFromAnswer reads the provider preference from a reply to a provider
+// question. Clauses answering the patient-type or schedule question bundled
+// with it are skipped rather than taken for a provider name.
+func providerFromAnswer(reply string) string {
+	if providerNoPreference(reply) {
+		return "no preference"
+	}
+	for _, clause := range splitCompoundAnswer(reply) {
+		if providerNoPreference(clause) {
+			return "no preference"
+		}
+		if bareYesNo(clause) || normalizePatientTypeReply(clause) != "" || clauseAnswersSchedule(clause) {
+			continue
+		}
+		if len(clause) > 1 && len(clause) < 50 {
+			return clause
+		}
+	}
+	return ""
+}
+
+// providerNoPreference reports whether text declines to pick a provider.
+func providerNoPreference(text string) bool {
+	lower := strings.Trim(strings.ToLower(strings.TrimSpace(text)), ".,!?")
+	if lower == "no" || lower == "nope" {
+		return true
+	}
+	for _, pat := range []string{"no preference", "doesn't matter", "don't care", "either", "anyone", "whoever"} {
+		if strings.Contains(lower, pat) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,151 @@
+package conversation
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/clinic"
+)
+
+func TestSplitCompoundAnswer(t *testing.T) {
+	tests := []struct {
+		reply string
+		want  []string
+	}{
+		{"New, weekday mornings", []string{"New", "weekday mornings"}},
+		{"new and weekday mornings", []string{"new", "weekday mornings"}},
+		{"nope - tuesdays after 3", []string{"nope", "tuesdays after 3"}},
+		{"First time! Any weekday morning works.", []string{"First time", "Any weekday morning works"}},
+		{"after 3 p.m. works", []string{"after 3 p.m", "works"}},
+		{"Gale", []string{"Gale"}},
+		{"", nil},
+	}
+	for _, tt := range tests {
+		if got := splitCompoundAnswer(tt.reply); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("splitCompoundAnswer(%q) = %q, want %q", tt.reply, got, tt.want)
+		}
+	}
+}
+
+// Fragments from the E2E multi-turn scenario, with the assistant bundling
+// the patient-type and schedule questions into one message.
+func multiTurnBundled(question, answer string) []ChatMessage {
+	return []ChatMessage{
+		{Role: ChatRoleUser, Content: "Hi, I'd like to schedule something. My name is Jane Smith."},
+		{Role: ChatRoleAssistant, Content: "Nice to meet you, Jane! What treatment are you interested in?"},
+		{Role: ChatRoleUser, Content: "I want microneedling"},
+		{Role: ChatRoleAssistant, Content: question},
+		{Role: ChatRoleUser, Content: answer},
+	}
+}
+
+func TestExtractPreferences_CompoundAnswerPatientTypeAndSchedule(t *testing.T) {
+	const askNewAndDays = "Great choice! Are you a new patient, and what days and times work best for you?"
+	const askVisitedAndDays = "Have you visited us before? And what days work best for you?"
+	tests := []struct {
+		question    string
+		answer      string
+		patientType string
+		days        string
+		times       string
+	}{
+		{askNewAndDays, "New, weekday mornings", "new", "weekdays", "morning"},
+		{askNewAndDays, "new and any weekday morning works", "new", "weekdays", "morning"},
+		{askNewAndDays, "First time! Any weekday morning works", "new", "weekdays", "morning"},
+		{askNewAndDays, "Returning, flexible", "existing", "any", "flexible"},
+		{askVisitedAndDays, "No, tuesdays after 3", "new", "tuesday", "after 3pm"},
+		{askVisitedAndDays, "nope - mondays after 3p", "new", "monday", "after 3pm"},
+		{askVisitedAndDays, "yep! weekends", "existing", "weekends", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.answer, func(t *testing.T) {
+			history := multiTurnBundled(tt.question, tt.answer)
+			prefs, _ := extractPreferences(history, nil)
+			if prefs.Name != "Jane Smith" || prefs.ServiceInterest != "microneedling" {
+				t.Errorf("lost earlier answers: name=%q service=%q", prefs.Name, prefs.ServiceInterest)
+			}
+			if prefs.PatientType != tt.patientType {
+				t.Errorf("PatientType = %q, want %q", prefs.PatientType, tt.patientType)
+			}
+			if prefs.PreferredDays != tt.days || prefs.PreferredTimes != tt.times {
+				t.Errorf("schedule = %q/%q, want %q/%q", prefs.PreferredDays, prefs.PreferredTimes, tt.days, tt.times)
+			}
+			if !ShouldFetchAvailability(history, nil) {
+				t.Error("every qualification was answered; availability should be fetched without re-asking")
+			}
+		})
+	}
+}
+
+// A trailing "no" answers the provider question, not the patient type.
+func TestExtractPreferences_TrailingNoAnswersLaterQuestion(t *testing.T) {
+	history := multiTurnBundled("Are you a new patient, and do you have a provider preference?", "returning, no")
+	prefs, _ := extractPreferences(history, nil)
+	if prefs.PatientType != "existing" || prefs.ProviderPreference != "no preference" {
+		t.Errorf("patientType/provider = %q/%q, want existing/no preference", prefs.PatientType, prefs.ProviderPreference)
+	}
+}
+
+// Fragments from the E2E provider-preference scenario (Botox has two
+// providers), with the provider question bundled with others.
+func TestShouldFetchAvailability_CompoundAnswerWithProvider(t *testing.T) {
+	cfg := &clinic.Config{
+		BookingPlatform: "boulevard",
+		ProviderNames:   map[string]string{"p1": "Gale Tesar", "p2": "Brandi Sesock"},
+	}
+	opening := ChatMessage{Role: ChatRoleUser, Content: "I'm Tom Baker, new patient. I want Botox."}
+	tests := []struct {
+		name     string
+		question string
+		answer   string
+		provider string
+		days     string
+		times    string
+		ready    bool
+	}{
+		{"named provider and schedule", "Thanks Tom! Do you have a provider preference (Brandi or Gale), and what days and times work best for you?",
+			"Gale, weekday afternoons", "Gale", "weekdays", "afternoon", true},
+		{"no preference and schedule", "Thanks Tom! Do you have a provider preference (Brandi or Gale), and what days and times work best for you?",
+			"No preference, weekday afternoons", "no preference", "weekdays", "afternoon", true},
+		{"either and schedule", "Thanks Tom! Do you have a provider preference, and what days work for you?",
+			"either is fine. Mondays after 3p", "no preference", "monday", "after 3pm", true},
+		{"schedule only leaves provider open", "Thanks Tom! Do you have a provider preference (Brandi or Gale), and what days and times work best for you?",
+			"weekday afternoons", "", "weekdays", "afternoon", false},
+		{"yes then name", "Do you have a provider preference? And what days work for you?",
+			"yes, Brandi - thursdays", "Brandi", "thursday", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			history := []ChatMessage{opening, {Role: ChatRoleAssistant, Content: tt.question}, {Role: ChatRoleUser, Content: tt.answer}}
+			prefs, gaps := qualificationGaps(history, cfg)
+			if prefs.Name != "Tom Baker" || prefs.PatientType != "new" {
+				t.Errorf("lost earlier answers: name=%q patientType=%q", prefs.Name, prefs.PatientType)
+			}
+			if prefs.ProviderPreference != tt.provider {
+				t.Errorf("ProviderPreference = %q, want %q", prefs.ProviderPreference, tt.provider)
+			}
+			if prefs.PreferredDays != tt.days || prefs.PreferredTimes != tt.times {
+				t.Errorf("schedule = %q/%q, want %q/%q", prefs.PreferredDays, prefs.PreferredTimes, tt.days, tt.times)
+			}
+			if gaps.Any() == tt.ready {
+				t.Errorf("gaps = %+v, ready want %v", gaps, tt.ready)
+			}
+		})
+	}
+}
+
+func TestExtractPreferences_CompoundAnswerNameAndPatientType(t *testing.T) {
+	history := []ChatMessage{
+		{Role: ChatRoleUser, Content: "I want botox"},
+		{Role: ChatRoleAssistant, Content: "Happy to help! May I have your full name, and are you a new or returning patient?"},
+		{Role: ChatRoleUser, Content: "Sarah Johnson, new"},
+		{Role: ChatRoleAssistant, Content: "Thanks Sarah! Do you have a provider preference, and what days work for you?"},
+		{Role: ChatRoleUser, Content: "no, weekday mornings"},
+	}
+	prefs, _ := extractPreferences(history, nil)
+	want := [5]string{"Sarah Johnson", "new", "no preference", "weekdays", "morning"}
+	got := [5]string{prefs.Name, prefs.PatientType, prefs.ProviderPreference, prefs.PreferredDays, prefs.PreferredTimes}
+	if got != want {
+		t.Errorf("name/patientType/provider/days/times = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,217 @@
+package conversation
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// defaultOrgConcurrency caps how many jobs one org may run at once in a
+	// worker process, so a burst from one clinic can't take every slot.
+	defaultOrgConcurrency = 3
+	// defaultOrgRequeueDelay is how long a job over its org's limit waits
+	// before going back on the queue.
+	defaultOrgRequeueDelay = 2 * time.Second
+
+	// DefaultAvailabilityConcurrency caps concurrent availability fetches per
+	// clinic; Moxie rate-limits bursts of searches for one medspa.
+	DefaultAvailabilityConcurrency = 2
+	// availabilityLeaseTTL bounds how long a crashed holder can keep a Redis
+	// availability slot.
+	availabilityLeaseTTL = 30 * time.Second
+	// availabilityPollInterval is how often a waiter retries a full Redis
+	// availability limiter.
+	availabilityPollInterval  = 100 * time.Millisecond
+	availabilityLimiterPrefix = "conversation:availability_slots:"
+)
+
+var concurrencyLimitedTotal = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Namespace: "medspa",
+		Subsystem: "conversation",
+		Name:      "concurrency_limited_total",
+		Help:      "Jobs or fetches held back by a concurrency limit",
+	},
+	[]string{"limiter"}, // limiter: org, availability, conversation_lock
+)
+
+var concurrencyLimitDelay = prometheus.NewHistogramVec(
+	prometheus.HistogramOpts{
+		Namespace: "medspa",
+		Subsystem: "conversation",
+		Name:      "concurrency_limit_delay_seconds",
+		Help:      "Extra delay caused by concurrency limits before work started",
+		Buckets:   []float64{0.05, 0.1, 0.25, 0.5, 1, 2, 5, 10, 20, 30, 60, 120},
+	},
+	[]string{"limiter"},
+)
+
+func init() {
+	prometheus.MustRegister(concurrencyLimitedTotal, concurrencyLimitDelay)
+}
+
+// orgLimiter caps concurrent jobs per org within one worker process.
+// A non-positive limit disables it.
+type orgLimiter struct {
+	mu     sync.Mutex
+	limit  int
+	active map[string]int
+}
+
+func newOrgLimiter(limit int) *orgLimiter {
+	return &orgLimiter{limit: limit, active: make(map[string]int)}
+}
+
+// tryAcquire takes a slot for orgID, reporting false when the org is at its
+// limit. Jobs without an org are never limited.
+func (l *orgLimiter) tryAcquire(orgID string) bool {
+	if l == nil || l.limit <= 0 || orgID == "" {
+		return true
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.active[orgID] >= l.limit {
+		return false
+	}
+	l.active[orgID]++
+	return true
+}
+
+func (l *orgLimiter) release(orgID string) {
+	if l == nil || l.limit <= 0 || orgID == "" {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.active[orgID] <= 1 {
+		delete(l.active, orgID)
+		return
+	}
+	l.active[orgID]--
+}
+
+// AvailabilityLimiter caps concurrent availability fetches per clinic.
+// Acquire blocks until a slot frees up or ctx ends; callers must call the
+// returned release func exactly once.
+type AvailabilityLimiter interface {
+	Acquire(ctx context.Context, orgID string) (release func(), err error)
+}
+
+// LocalAvailabilityLimiter is an in-process AvailabilityLimiter, for
+// deployments running a single instance.
+type LocalAvailabilityLimiter struct {
+	limit int
+
+	mu    sync.Mutex
+	slots map[string]chan struct{}
+}
+
+// NewLocalAvailabilityLimiter allows limit concurrent fetches per clinic.
+func NewLocalAvailabilityLimiter(limit int) *LocalAvailabilityLimiter {
+	if limit <= 0 {
+		limit = DefaultAvailabilityConcurrency
+	}
+	return &LocalAvailabilityLimiter{limit: limit, slots: make(map[string]chan struct{})}
+}
+
+// Acquire implements AvailabilityLimiter.
+func (l *LocalAvailabilityLimiter) Acquire(ctx context.Context, orgID string) (func(), error) {
+	l.mu.Lock()
+	sem, ok := l.slots[orgID]
+	if !ok {
+		sem = make(chan struct{}, l.limit)
+		l.slots[orgID] = sem
+	}
+	l.mu.Unlock()
+
+	select {
+	case sem <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	var once sync.Once
+	return func() { once.Do(func() { <-sem }) }, nil
+}
+
+// acquireAvailabilitySlotScript admits a holder when fewer than limit
+// unexpired leases exist. Leases are scored by their expiry, so holders that
+// crash without releasing age out after the lease TTL.
+var acquireAvailabilitySlotScript = redis.NewScript(`
+redis.call('ZREMRANGEBYSCORE', KEYS[1], '-inf', ARGV[1])
+if redis.call('ZCARD', KEYS[1]) < tonumber(ARGV[3]) then
+	redis.call('ZADD', KEYS[1], tonumber(ARGV[1]) + tonumber(ARGV[2]), ARGV[4])
+	redis.call('PEXPIRE', KEYS[1], ARGV[2])
+	return 1
+end
+return 0
+`)
+
+// RedisAvailabilityLimiter is an AvailabilityLimiter shared by every worker
+// instance through Redis.
+type RedisAvailabilityLimiter struct {
+	client redis.Cmdable
+	limit  int
+	lease  time.Duration
+	poll   time.Duration
+	now    func() time.Time
+}
+
+// NewRedisAvailabilityLimiter allows limit concurrent fetches per clinic
+// across all instances sharing client.
+func NewRedisAvailabilityLimiter(client redis.Cmdable, limit int) *RedisAvailabilityLimiter {
+	if client == nil {
+		panic("conversation: redis client cannot be nil")
+	}
+	if limit <= 0 {
+		limit = DefaultAvailabilityConcurrency
+	}
+	return &RedisAvailabilityLimiter{
+		client: client,
+		limit:  limit,
+		lease:  availabilityLeaseTTL,
+		poll:   availabilityPollInterval,
+		now:    time.Now,
+	}
+}
+
+// Acquire implements AvailabilityLimiter.
+func (l *RedisAvailabilityLimiter) Acquire(ctx context.Context, orgID string) (func(), error) {
+	key := availabilityLimiterPrefix + orgID
+	token := uuid.NewString()
+	for {
+		now := l.now().UnixMilli()
+		ok, err := acquireAvailabilitySlotScript.Run(ctx, l.client, []string{key},
+			strconv.FormatInt(now, 10), strconv.FormatInt(l.lease.Milliseconds(), 10), l.limit, token).Int()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			return nil, fmt.Errorf("conversation: acquire availability slot: %w", err)
+		}
+		if ok == 1 {
+			var once sync.Once
+			return func() {
+				once.Do(func() {
+					releaseCtx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+					defer cancel()
+					_ = l.client.ZRem(releaseCtx, key, token).Err()
+				})
+			}, nil
+		}
+
+		timer := time.NewTimer(l.poll)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
@@ -0,0 +1,235 @@
+package conversation
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"testing"
+	"time"
+
+	miniredis "github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/clinic"
+	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
+)
+
+// gatedService blocks every StartConversation until gate closes, tracking how
+// many jobs per org run at once.
+type gatedService struct {
+	gate chan struct{}
+
+	mu      sync.Mutex
+	active  map[string]int
+	maxSeen map[string]int
+	started map[string]int
+}
+
+func newGatedService() *gatedService {
+	return &gatedService{
+		gate:    make(chan struct{}),
+		active:  map[string]int{},
+		maxSeen: map[string]int{},
+		started: map[string]int{},
+	}
+}
+
+func (s *gatedService) StartConversation(ctx context.Context, req StartRequest) (*Response, error) {
+	s.mu.Lock()
+	s.active[req.OrgID]++
+	s.started[req.OrgID]++
+	if s.active[req.OrgID] > s.maxSeen[req.OrgID] {
+		s.maxSeen[req.OrgID] = s.active[req.OrgID]
+	}
+	s.mu.Unlock()
+
+	select {
+	case <-s.gate:
+	case <-ctx.Done():
+	}
+
+	s.mu.Lock()
+	s.active[req.OrgID]--
+	s.mu.Unlock()
+	return &Response{}, nil
+}
+
+func (s *gatedService) ProcessMessage(ctx context.Context, req MessageRequest) (*Response, error) {
+	return &Response{}, nil
+}
+
+func (s *gatedService) GetHistory(ctx context.Context, conversationID string) ([]Message, error) {
+	return nil, nil
+}
+
+func (s *gatedService) snapshot(orgID string) (active, maxSeen, started int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.active[orgID], s.maxSeen[orgID], s.started[orgID]
+}
+
+func TestWorkerOrgConcurrencyKeepsOtherOrgsMoving(t *testing.T) {
+	queue := NewMemoryQueue(16)
+	service := newGatedService()
+	worker := NewWorker(service, queue, &stubJobUpdater{}, nil, nil, logging.Default(),
+		WithWorkerCount(4), WithReceiveBatchSize(1), WithReceiveWaitSeconds(0),
+		WithOrgConcurrency(2, 20*time.Millisecond))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	send := func(orgID string, n int) {
+		for i := 0; i < n; i++ {
+			body, _ := json.Marshal(queuePayload{ID: logging.NewID(), Kind: jobTypeStart, Start: StartRequest{OrgID: orgID, LeadID: "lead"}})
+			if err := queue.Send(ctx, string(body)); err != nil {
+				t.Fatalf("send: %v", err)
+			}
+		}
+	}
+	send("org-a", 5)
+	send("org-b", 1)
+	worker.Start(ctx)
+
+	// org-a's burst fills its two slots and the rest are requeued, leaving
+	// the remaining workers free for org-b.
+	waitFor(func() bool {
+		activeA, _, _ := service.snapshot("org-a")
+		activeB, _, _ := service.snapshot("org-b")
+		return activeA == 2 && activeB == 1
+	}, 2*time.Second, t)
+
+	close(service.gate)
+	waitFor(func() bool {
+		_, _, startedA := service.snapshot("org-a")
+		return startedA == 5
+	}, 2*time.Second, t)
+	cancel()
+	worker.Wait()
+
+	if _, maxA, _ := service.snapshot("org-a"); maxA != 2 {
+		t.Fatalf("expected org-a to peak at 2 concurrent jobs, got %d", maxA)
+	}
+	if _, _, startedB := service.snapshot("org-b"); startedB != 1 {
+		t.Fatalf("expected org-b's job to run once, got %d", startedB)
+	}
+}
+
+func TestOrgLimiter(t *testing.T) {
+	l := newOrgLimiter(1)
+	if !l.tryAcquire("org-1") || l.tryAcquire("org-1") {
+		t.Fatal("expected a single slot for org-1")
+	}
+	if !l.tryAcquire("org-2") || !l.tryAcquire("") {
+		t.Fatal("expected other orgs and org-less jobs to be unaffected")
+	}
+	l.release("org-1")
+	if !l.tryAcquire("org-1") {
+		t.Fatal("expected the released slot to be reusable")
+	}
+
+	var disabled *orgLimiter
+	if !disabled.tryAcquire("org-1") || !newOrgLimiter(0).tryAcquire("org-1") {
+		t.Fatal("expected nil and zero limiters to admit everything")
+	}
+}
+
+// countingSource tracks concurrent fetches per org.
+type countingSource struct {
+	delay time.Duration
+
+	mu      sync.Mutex
+	active  map[string]int
+	maxSeen map[string]int
+}
+
+func (s *countingSource) Name() string                 { return clinic.AvailabilitySourceMoxieAPI }
+func (s *countingSource) Supports(*clinic.Config) bool { return true }
+
+func (s *countingSource) FetchAvailability(ctx context.Context, req AvailabilityRequest) (*AvailabilityResult, error) {
+	s.mu.Lock()
+	s.active[req.OrgID]++
+	if s.active[req.OrgID] > s.maxSeen[req.OrgID] {
+		s.maxSeen[req.OrgID] = s.active[req.OrgID]
+	}
+	s.mu.Unlock()
+	time.Sleep(s.delay)
+	s.mu.Lock()
+	s.active[req.OrgID]--
+	s.mu.Unlock()
+	return &AvailabilityResult{Slots: []PresentedSlot{{Index: 1, Available: true}}, ExactMatch: true}, nil
+}
+
+func TestAvailabilityRouter_LimiterCapsFetchesPerClinic(t *testing.T) {
+	mr := miniredis.RunT(t)
+	redisLimiter := NewRedisAvailabilityLimiter(redis.NewClient(&redis.Options{Addr: mr.Addr()}), 2)
+	redisLimiter.poll = 5 * time.Millisecond
+
+	limiters := map[string]AvailabilityLimiter{
+		"local": NewLocalAvailabilityLimiter(2),
+		"redis": redisLimiter,
+	}
+	for name, limiter := range limiters {
+		t.Run(name, func(t *testing.T) {
+			source := &countingSource{delay: 20 * time.Millisecond, active: map[string]int{}, maxSeen: map[string]int{}}
+			router := NewAvailabilityRouter(logging.Default(), source)
+			router.SetLimiter(limiter)
+
+			var wg sync.WaitGroup
+			errs := make(chan error, 10)
+			for i := 0; i < 10; i++ {
+				orgID := "org-1"
+				if i%5 == 0 {
+					orgID = "org-2"
+				}
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					_, err := router.Fetch(context.Background(), routerRequest(&clinic.Config{OrgID: orgID}))
+					errs <- err
+				}()
+			}
+			wg.Wait()
+			close(errs)
+			for err := range errs {
+				if err != nil {
+					t.Fatalf("fetch: %v", err)
+				}
+			}
+			if source.maxSeen["org-1"] != 2 {
+				t.Fatalf("expected org-1 to peak at 2 concurrent fetches, got %d", source.maxSeen["org-1"])
+			}
+			if source.maxSeen["org-2"] > 2 {
+				t.Fatalf("expected org-2 within its own cap, got %d", source.maxSeen["org-2"])
+			}
+		})
+	}
+}
+
+func TestRedisAvailabilityLimiter_ExpiredLeasesFreeSlots(t *testing.T) {
+	mr := miniredis.RunT(t)
+	limiter := NewRedisAvailabilityLimiter(redis.NewClient(&redis.Options{Addr: mr.Addr()}), 1)
+	limiter.poll = 5 * time.Millisecond
+	now := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	limiter.now = func() time.Time { return now }
+
+	if _, err := limiter.Acquire(context.Background(), "org-1"); err != nil {
+		t.Fatalf("acquire: %v", err)
+	}
+	// The holder never releases, as if its worker crashed.
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Millisecond)
+	defer cancel()
+	if _, err := limiter.Acquire(ctx, "org-1"); err == nil {
+		t.Fatal("expected the second acquire to wait for the held slot")
+	}
+
+	now = now.Add(availabilityLeaseTTL + time.Second)
+	release, err := limiter.Acquire(context.Background(), "org-1")
+	if err != nil {
+		t.Fatalf("expected the expired lease to free its slot: %v", err)
+	}
+	release()
+	release()
+	if n, _ := limiter.client.ZCard(context.Background(), availabilityLimiterPrefix+"org-1").Result(); n != 0 {
+		t.Fatalf("expected no leases after release, got %d", n)
+	}
+}
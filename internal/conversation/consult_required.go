@@ -0,0 +1,68 @@
+package conversation
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/clinic"
+	"github.com/wolfman30/medspa-ai-platform/internal/messaging/templates"
+)
+
+const consultRequiredLeadNote = "tag:consult_required"
+
+// handleConsultRequiredService explains the consult-first policy the first
+// time a patient asks for a service the clinic won't book directly, and
+// offers the consultation instead. Later turns go to the LLM, which books the
+// consultation through the usual qualification flow.
+func (s *LLMService) handleConsultRequiredService(ctx context.Context, pc *processContext) *Response {
+	entry, ok := pc.cfg.ConsultRequiredServiceIn(pc.rawMessage)
+	if !ok {
+		return nil
+	}
+	reply := consultRequiredReply(pc.cfg, entry)
+	for _, msg := range pc.history {
+		if msg.Role == ChatRoleAssistant && msg.Content == reply.Body {
+			return nil
+		}
+	}
+	s.log(ctx).Info("ProcessMessage: offered consultation for consult-first service",
+		"conversation_id", pc.req.ConversationID,
+		"service", entry.Service,
+		"consult_service", entry.ConsultService,
+	)
+	s.appendLeadNote(ctx, pc.req.OrgID, pc.req.LeadID, consultRequiredLeadNote)
+	resp := s.saveAndReturn(ctx, pc, reply.Body, "consult_required_service")
+	resp.Template = reply.Ref
+	return resp
+}
+
+// consultRequiredReply tells the patient a service starts with a
+// consultation and offers to book it.
+func consultRequiredReply(cfg *clinic.Config, entry clinic.ConsultRequiredService) templates.Rendered {
+	return cfg.RenderTemplate(templates.ReplyConsultRequired, templates.Params{
+		"service":         strings.TrimSpace(entry.Service),
+		"consult_service": strings.TrimSpace(entry.ConsultService),
+	})
+}
+
+// consultBookingNote tells the clinic which service a consultation booking
+// is for, or returns "" when service isn't consult-first.
+func consultBookingNote(cfg *clinic.Config, service string) string {
+	entry, ok := cfg.ConsultRequiredFor(service)
+	if !ok {
+		return ""
+	}
+	return fmt.Sprintf("Consultation for %s (consult required before booking)", strings.TrimSpace(entry.Service))
+}
+
+// joinNotes joins non-empty booking notes with "; ".
+func joinNotes(notes ...string) string {
+	parts := make([]string, 0, len(notes))
+	for _, note := range notes {
+		if note = strings.TrimSpace(note); note != "" {
+			parts = append(parts, note)
+		}
+	}
+	return strings.Join(parts, "; ")
+}
@@ -0,0 +1,181 @@
+package conversation
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/wolfman30/medspa-ai-platform/internal/clinic"
+	"github.com/wolfman30/medspa-ai-platform/internal/leads"
+	"github.com/wolfman30/medspa-ai-platform/internal/tenancy"
+	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
+)
+
+func consultFirstClinic(orgID string) *clinic.Config {
+	cfg := clinic.DefaultConfig(orgID)
+	cfg.Services = []string{"Botox", "Radiesse", "Filler Consultation"}
+	cfg.BookingURL = "https://app.joinmoxie.com/booking/glow"
+	cfg.MoxieConfig = &clinic.MoxieConfig{ServiceMenuItems: map[string]string{"botox": "menu-botox"}}
+	cfg.ConsultRequired = []clinic.ConsultRequiredService{{
+		Service:          "Radiesse",
+		ConsultService:   "Filler Consultation",
+		ConsultServiceID: "menu-filler-consult",
+	}}
+	return cfg
+}
+
+func TestLLMService_ConsultRequiredServiceOffersConsultation(t *testing.T) {
+	mr := miniredis.RunT(t)
+	ctx := context.Background()
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	clinicStore := clinic.NewStore(client)
+	cfg := consultFirstClinic("org-1")
+	if err := clinicStore.Set(ctx, cfg); err != nil {
+		t.Fatalf("set clinic config: %v", err)
+	}
+	leadsRepo := leads.NewInMemoryRepository()
+	lead, err := leadsRepo.Create(ctx, &leads.CreateLeadRequest{OrgID: "org-1", Phone: "+15550000000", Source: "sms"})
+	if err != nil {
+		t.Fatalf("create lead: %v", err)
+	}
+
+	mockLLM := &stubLLMClient{response: LLMResponse{Text: "Great! May I have your full name?"}}
+	service := NewLLMService(mockLLM, client, nil, "anthropic.claude-3-haiku-20240307-v1:0", logging.Default(),
+		WithClinicStore(clinicStore), WithLeadsRepo(leadsRepo))
+
+	req := MessageRequest{
+		ConversationID: "sms:org-1:15550000000",
+		LeadID:         lead.ID,
+		OrgID:          "org-1",
+		Message:        "Can I book Radiesse for my cheeks?",
+		Channel:        ChannelSMS,
+	}
+	resp, err := service.ProcessMessage(ctx, req)
+	if err != nil {
+		t.Fatalf("process failed: %v", err)
+	}
+	want := "Radiesse starts with a consultation so our provider can make sure it's the right fit, so we'd book you a Filler Consultation first. Would you like me to find a time for that?"
+	if resp.Message != want {
+		t.Fatalf("reply = %q, want %q", resp.Message, want)
+	}
+	if len(mockLLM.requests) != 0 {
+		t.Fatalf("expected the LLM not to be called, got %d calls", len(mockLLM.requests))
+	}
+
+	saved, err := leadsRepo.GetByID(ctx, tenancy.ForOrg("org-1"), lead.ID)
+	if err != nil {
+		t.Fatalf("get lead: %v", err)
+	}
+	if !strings.EqualFold(saved.ServiceInterest, "Radiesse") || !strings.Contains(saved.SchedulingNotes, consultRequiredLeadNote) {
+		t.Fatalf("expected Radiesse kept as the interest and the tag saved, got interest %q notes %q", saved.ServiceInterest, saved.SchedulingNotes)
+	}
+
+	// Once the policy is explained the LLM takes over qualification.
+	req.Message = "Yes please, Radiesse consult sounds good"
+	resp, err = service.ProcessMessage(ctx, req)
+	if err != nil {
+		t.Fatalf("process failed: %v", err)
+	}
+	if resp.Message != "Great! May I have your full name?" {
+		t.Fatalf("expected the follow-up answered by the LLM, got %q", resp.Message)
+	}
+}
+
+func TestFetchAndPresentAvailability_ConsultRequiredBooksConsultation(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	slot := time.Now().Add(48 * time.Hour).Truncate(time.Hour)
+	source := &slotSource{slots: []PresentedSlot{{Index: 1, DateTime: slot, TimeStr: slot.Format("Mon Jan 2 at 3:04 PM"), Available: true}}}
+	svc := NewLLMService(&stubLLMClient{}, client, nil, "model", logging.Default(),
+		WithAvailabilityRouter(NewAvailabilityRouter(logging.Default(), source)))
+
+	cfg := consultFirstClinic("org-1")
+	prefs := leads.SchedulingPreferences{Name: "Sarah Johnson", ServiceInterest: "Radiesse", PatientType: "new"}
+	resp := svc.fetchAndPresentAvailability(context.Background(), &prefs, cfg, cfg.BookingURL, "sms:org-1:15550001111", "org-1", "", "+15550001111", nil)
+
+	if len(source.reqs) != 1 || source.reqs[0].Service != "Filler Consultation" {
+		t.Fatalf("expected availability for the consultation, got %+v", source.reqs)
+	}
+	if resp == nil || resp.Service != "Filler Consultation" || !strings.Contains(resp.SMSMessage, "Filler Consultation") {
+		t.Fatalf("expected consultation slots presented, got %+v", resp)
+	}
+	if prefs.ServiceInterest != "Radiesse" || !strings.Contains(prefs.Notes, "Consultation for Radiesse") {
+		t.Fatalf("expected Radiesse kept as the interest and noted, got %+v", prefs)
+	}
+	state, err := svc.history.LoadTimeSelectionState(context.Background(), "sms:org-1:15550001111")
+	if err != nil || state == nil || state.Service != "Filler Consultation" {
+		t.Fatalf("expected the consultation saved for booking, got %+v (err %v)", state, err)
+	}
+	if id := moxieServiceMenuItemID(cfg, state.Service); id != "menu-filler-consult" {
+		t.Fatalf("writeback menu item = %q, want the consult service ID", id)
+	}
+}
+
+func TestAssembleBookingRequest_ConsultNotesOriginalService(t *testing.T) {
+	ctx := context.Background()
+	leadsRepo := leads.NewInMemoryRepository()
+	lead, err := leadsRepo.Create(ctx, &leads.CreateLeadRequest{OrgID: "org-1", Name: "Sarah Johnson", Phone: "+15550000000", Source: "sms"})
+	if err != nil {
+		t.Fatalf("create lead: %v", err)
+	}
+	if err := leadsRepo.MergePreferences(ctx, lead.ID, leads.SchedulingPreferences{ServiceInterest: "Radiesse"}); err != nil {
+		t.Fatalf("save interest: %v", err)
+	}
+	svc := &LLMService{logger: logging.Default(), leadsRepo: leadsRepo}
+	cfg := consultFirstClinic("org-1")
+
+	slot := time.Date(2026, 11, 3, 14, 0, 0, 0, time.UTC)
+	pc := &processContext{
+		req:                MessageRequest{OrgID: "org-1", LeadID: lead.ID, From: "+15550000000"},
+		selectedSlot:       &PresentedSlot{DateTime: slot},
+		timeSelectionState: &TimeSelectionState{Service: "Filler Consultation"},
+	}
+	svc.assembleBookingRequest(ctx, pc, cfg, true)
+
+	if pc.bookingRequest == nil {
+		t.Fatal("expected a booking request")
+	}
+	if pc.bookingRequest.Service != "Filler Consultation" || !strings.Contains(pc.bookingRequest.Notes, "Consultation for Radiesse") {
+		t.Fatalf("booking = service %q notes %q, want the consultation noting Radiesse", pc.bookingRequest.Service, pc.bookingRequest.Notes)
+	}
+}
+
+func TestConsultRequired_OtherServicesUnaffected(t *testing.T) {
+	cfg := consultFirstClinic("org-1")
+
+	if _, ok := cfg.ConsultRequiredServiceIn("Can I book Botox on Friday?"); ok {
+		t.Fatal("expected Botox booked directly")
+	}
+	if got := cfg.BookingServiceFor("Botox"); got != "Botox" {
+		t.Fatalf("BookingServiceFor(Botox) = %q", got)
+	}
+	if note := consultBookingNote(cfg, "Botox"); note != "" {
+		t.Fatalf("unexpected booking note %q", note)
+	}
+	if got := cfg.ServiceMenuItemID("Botox"); got != "menu-botox" {
+		t.Fatalf("ServiceMenuItemID(Botox) = %q", got)
+	}
+	if got := cfg.BookingServiceFor("radiesse"); got != "Filler Consultation" {
+		t.Fatalf("BookingServiceFor(radiesse) = %q", got)
+	}
+	if err := clinic.ValidateConsultRequired([]clinic.ConsultRequiredService{{Service: "Kybella"}}); err == nil {
+		t.Fatal("expected an entry without a consult service rejected")
+	}
+}
+
+// slotSource serves fixed availability and records each request.
+type slotSource struct {
+	slots []PresentedSlot
+	reqs  []AvailabilityRequest
+}
+
+func (s *slotSource) Name() string                 { return clinic.AvailabilitySourceMoxieAPI }
+func (s *slotSource) Supports(*clinic.Config) bool { return true }
+
+func (s *slotSource) FetchAvailability(ctx context.Context, req AvailabilityRequest) (*AvailabilityResult, error) {
+	s.reqs = append(s.reqs, req)
+	return &AvailabilityResult{Slots: s.slots, ExactMatch: true}, nil
+}
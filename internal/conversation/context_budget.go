@@ -0,0 +1,135 @@
+package conversation
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/clinic"
+)
+
+// DefaultContextTokenBudget caps the estimated tokens of per-turn context
+// when neither the service nor the clinic sets a budget.
+const DefaultContextTokenBudget = 2000
+
+// minRAGSnippetTokens is the smallest shortened snippet worth sending.
+const minRAGSnippetTokens = 32
+
+// ragContextHeader opens the knowledge snippet block.
+const ragContextHeader = "Relevant clinic context:\n"
+
+var contextBlocksTrimmedTotal = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Namespace: "medspa",
+		Subsystem: "conversation",
+		Name:      "context_blocks_trimmed_total",
+		Help:      "Prompt context blocks cut to fit the token budget",
+	},
+	[]string{"block", "action"}, // action: dropped, truncated
+)
+
+func init() {
+	prometheus.MustRegister(contextBlocksTrimmedTotal)
+}
+
+// contextBlock is the context one source adds to a turn's prompt.
+type contextBlock struct {
+	kind     string
+	messages []ChatMessage
+	// snippets, when set, are the knowledge snippets messages was built
+	// from; the block is shortened by dropping and cutting snippets rather
+	// than dropped whole.
+	snippets []string
+}
+
+// contextTrim records a block cut to fit the budget.
+type contextTrim struct {
+	kind   string
+	action string // "dropped" or "truncated"
+}
+
+// estimateTokens approximates the token count of text at four characters
+// per token, which is close for English prose.
+func estimateTokens(text string) int {
+	return (utf8.RuneCountInString(text) + 3) / 4
+}
+
+func messagesTokens(messages []ChatMessage) int {
+	total := 0
+	for _, msg := range messages {
+		total += estimateTokens(msg.Content)
+	}
+	return total
+}
+
+// budgetContext assembles blocks in priority order within budget estimated
+// tokens. Payment state is always kept, even over budget. A knowledge block
+// that doesn't fit is shortened to its leading snippets; any other block
+// that doesn't fit is dropped, and lower-priority blocks may still fill the
+// space left. A budget of zero or less keeps everything.
+func budgetContext(blocks []contextBlock, priority []string, budget int) ([]ChatMessage, []contextTrim) {
+	byKind := make(map[string][]contextBlock, len(blocks))
+	for _, b := range blocks {
+		if len(b.messages) > 0 {
+			byKind[b.kind] = append(byKind[b.kind], b)
+		}
+	}
+
+	var kept []ChatMessage
+	var trims []contextTrim
+	remaining := budget
+	for _, kind := range priority {
+		for _, b := range byKind[kind] {
+			cost := messagesTokens(b.messages)
+			switch {
+			case budget <= 0 || kind == clinic.ContextBlockPayment || cost <= remaining:
+				kept = append(kept, b.messages...)
+				remaining -= cost
+			case len(b.snippets) > 0:
+				if msg, ok := fitRAGContext(b.snippets, remaining); ok {
+					kept = append(kept, msg)
+					remaining -= estimateTokens(msg.Content)
+					trims = append(trims, contextTrim{kind: kind, action: "truncated"})
+				} else {
+					trims = append(trims, contextTrim{kind: kind, action: "dropped"})
+				}
+			default:
+				trims = append(trims, contextTrim{kind: kind, action: "dropped"})
+			}
+		}
+	}
+	return kept, trims
+}
+
+// formatRAGContext renders knowledge snippets as one system message.
+func formatRAGContext(snippets []string) ChatMessage {
+	builder := strings.Builder{}
+	builder.WriteString(ragContextHeader)
+	for i, snippet := range snippets {
+		builder.WriteString(fmt.Sprintf("%d. %s\n", i+1, snippet))
+	}
+	return ChatMessage{Role: ChatRoleSystem, Content: builder.String()}
+}
+
+// fitRAGContext renders as many leading snippets as fit in budget tokens.
+// When not even the first fits whole, it is cut short if enough of it fits
+// to be useful.
+func fitRAGContext(snippets []string, budget int) (ChatMessage, bool) {
+	for n := len(snippets) - 1; n > 0; n-- {
+		if msg := formatRAGContext(snippets[:n]); estimateTokens(msg.Content) <= budget {
+			return msg, true
+		}
+	}
+	// Leave room for the header, numbering, newline and ellipsis.
+	room := budget - estimateTokens(ragContextHeader+"1. …\n")
+	if room < minRAGSnippetTokens {
+		return ChatMessage{}, false
+	}
+	runes := []rune(snippets[0])
+	if limit := room * 4; len(runes) > limit {
+		runes = append(runes[:limit], '…')
+	}
+	return formatRAGContext([]string{string(runes)}), true
+}
@@ -0,0 +1,97 @@
+package conversation
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/clinic"
+)
+
+func sysMsg(content string) ChatMessage {
+	return ChatMessage{Role: ChatRoleSystem, Content: content}
+}
+
+// oversizedContext returns one block of each kind: payment and preferences
+// ~50 tokens each, clinic ~100, RAG three ~100-token snippets, EMR ~150.
+func oversizedContext() []contextBlock {
+	snippets := []string{strings.Repeat("a", 400), strings.Repeat("b", 400), strings.Repeat("c", 400)}
+	return []contextBlock{
+		{kind: clinic.ContextBlockPayment, messages: []ChatMessage{sysMsg("PAYMENT " + strings.Repeat("p", 192))}},
+		{kind: clinic.ContextBlockPreferences, messages: []ChatMessage{sysMsg("PREFS " + strings.Repeat("l", 194))}},
+		{kind: clinic.ContextBlockClinic, messages: []ChatMessage{sysMsg("HOURS " + strings.Repeat("h", 394))}},
+		{kind: clinic.ContextBlockRAG, messages: []ChatMessage{formatRAGContext(snippets)}, snippets: snippets},
+		{kind: clinic.ContextBlockEMR, messages: []ChatMessage{sysMsg("SLOTS " + strings.Repeat("s", 594))}},
+	}
+}
+
+func contextLabels(messages []ChatMessage) []string {
+	var labels []string
+	for _, msg := range messages {
+		label, _, _ := strings.Cut(msg.Content, " ")
+		labels = append(labels, strings.TrimSuffix(label, "\n"))
+	}
+	return labels
+}
+
+func TestBudgetContext_IncludesByPriority(t *testing.T) {
+	kept, trims := budgetContext(oversizedContext(), (*clinic.Config)(nil).ContextPriorityOrder(), 320)
+
+	if got, want := contextLabels(kept), []string{"PAYMENT", "PREFS", "HOURS", "Relevant"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("kept %v, want %v", got, want)
+	}
+	rag := kept[3].Content
+	if !strings.Contains(rag, "1. aaaa") || strings.Contains(rag, "2. ") {
+		t.Fatalf("expected RAG cut to its first snippet, got %q", rag)
+	}
+	if messagesTokens(kept) > 320 {
+		t.Fatalf("kept %d tokens, over the 320 budget", messagesTokens(kept))
+	}
+	want := []contextTrim{{clinic.ContextBlockRAG, "truncated"}, {clinic.ContextBlockEMR, "dropped"}}
+	if !reflect.DeepEqual(trims, want) {
+		t.Fatalf("trims = %+v, want %+v", trims, want)
+	}
+}
+
+func TestBudgetContext_ShortensSingleSnippet(t *testing.T) {
+	kept, _ := budgetContext(oversizedContext(), (*clinic.Config)(nil).ContextPriorityOrder(), 250)
+
+	rag := kept[len(kept)-1].Content
+	if !strings.HasPrefix(rag, ragContextHeader+"1. aaa") || !strings.HasSuffix(rag, "…\n") {
+		t.Fatalf("expected a shortened first snippet, got %q", rag)
+	}
+	if messagesTokens(kept) > 250 {
+		t.Fatalf("kept %d tokens, over the 250 budget", messagesTokens(kept))
+	}
+}
+
+func TestBudgetContext_NeverDropsPaymentState(t *testing.T) {
+	kept, trims := budgetContext(oversizedContext(), (*clinic.Config)(nil).ContextPriorityOrder(), 10)
+
+	if got := contextLabels(kept); !reflect.DeepEqual(got, []string{"PAYMENT"}) {
+		t.Fatalf("kept %v, want only the payment state", got)
+	}
+	if len(trims) != 4 {
+		t.Fatalf("expected the other four blocks dropped, got %+v", trims)
+	}
+}
+
+func TestBudgetContext_ClinicPriorityOverride(t *testing.T) {
+	cfg := &clinic.Config{ContextPriority: []string{clinic.ContextBlockEMR, clinic.ContextBlockPayment, clinic.ContextBlockClinic}}
+	order := cfg.ContextPriorityOrder()
+	if want := []string{"payment", "emr", "clinic", "preferences", "rag"}; !reflect.DeepEqual(order, want) {
+		t.Fatalf("order = %v, want %v", order, want)
+	}
+
+	kept, _ := budgetContext(oversizedContext(), order, 300)
+	if got, want := contextLabels(kept), []string{"PAYMENT", "SLOTS", "HOURS"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("kept %v, want %v", got, want)
+	}
+}
+
+func TestBudgetContext_NoBudgetKeepsAll(t *testing.T) {
+	kept, trims := budgetContext(oversizedContext(), (*clinic.Config)(nil).ContextPriorityOrder(), 0)
+	if len(kept) != 5 || len(trims) != 0 {
+		t.Fatalf("kept %d blocks with trims %+v, want all five", len(kept), trims)
+	}
+}
@@ -0,0 +1,225 @@
+package conversation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// conversationLockPollInterval is how often a job retries a held
+	// conversation lock while waiting for it.
+	conversationLockPollInterval = 100 * time.Millisecond
+	// conversationLockRequeueDelay is how long a job that gave up waiting for
+	// a conversation lock, or lost it mid-job, sits before going back on the
+	// queue.
+	conversationLockRequeueDelay = 2 * time.Second
+)
+
+// ErrConversationLockLost is returned when a worker's conversation lock
+// expired and was taken by another worker, fencing off its writes.
+var ErrConversationLockLost = errors.New("conversation: conversation lock lost")
+
+// ConversationLocker serializes job processing per conversation across every
+// worker replica. In-process limits can't stop an SQS redelivery on one pod
+// and a fresh text on another from interleaving history writes and sending
+// two replies.
+type ConversationLocker struct {
+	client redis.Cmdable
+	ttl    time.Duration
+	wait   time.Duration
+	poll   time.Duration
+}
+
+// NewConversationLocker returns a locker whose locks expire after ttl unless
+// extended, waiting up to wait for a held lock. It returns nil (locking
+// disabled) without Redis or a positive ttl.
+func NewConversationLocker(client *redis.Client, ttl, wait time.Duration) *ConversationLocker {
+	if client == nil || ttl <= 0 {
+		return nil
+	}
+	return &ConversationLocker{client: client, ttl: ttl, wait: wait, poll: conversationLockPollInterval}
+}
+
+// ConversationLock is a held per-conversation lock. Its token fences writes:
+// once the lock expires and another worker takes it, this holder's history
+// saves are rejected.
+type ConversationLock struct {
+	client         redis.Cmdable
+	conversationID string
+	key            string
+	token          string
+	ttl            time.Duration
+	lost           atomic.Bool
+}
+
+func conversationLockKey(conversationID string) string {
+	return fmt.Sprintf("conversation_lock:%s", conversationID)
+}
+
+// Acquire takes the conversation's lock, retrying until the locker's wait
+// runs out. It returns nil and no error when the lock is still held by
+// someone else at the deadline.
+func (l *ConversationLocker) Acquire(ctx context.Context, conversationID string) (*ConversationLock, error) {
+	lock := &ConversationLock{
+		client:         l.client,
+		conversationID: conversationID,
+		key:            conversationLockKey(conversationID),
+		token:          uuid.NewString(),
+		ttl:            l.ttl,
+	}
+	start := time.Now()
+	deadline := start.Add(l.wait)
+	contended := false
+	for {
+		ok, err := l.client.SetNX(ctx, lock.key, lock.token, l.ttl).Result()
+		if err != nil {
+			return nil, fmt.Errorf("conversation: acquire conversation lock: %w", err)
+		}
+		if ok {
+			if contended {
+				concurrencyLimitDelay.WithLabelValues("conversation_lock").Observe(time.Since(start).Seconds())
+			}
+			return lock, nil
+		}
+		if !contended {
+			contended = true
+			concurrencyLimitedTotal.WithLabelValues("conversation_lock").Inc()
+		}
+		if !time.Now().Add(l.poll).Before(deadline) {
+			return nil, nil
+		}
+		timer := time.NewTimer(l.poll)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// releaseConversationLockScript deletes the lock only while this holder
+// still owns it, so a holder whose lock expired can't free its successor's.
+var releaseConversationLockScript = redis.NewScript(`
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('DEL', KEYS[1])
+end
+return 0
+`)
+
+// extendConversationLockScript pushes out the lock's expiry while this
+// holder still owns it.
+var extendConversationLockScript = redis.NewScript(`
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('PEXPIRE', KEYS[1], ARGV[2])
+end
+return 0
+`)
+
+// Release frees the lock if this holder still owns it.
+func (l *ConversationLock) Release() {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	_ = releaseConversationLockScript.Run(ctx, l.client, []string{l.key}, l.token).Err()
+}
+
+// Extend resets the lock's TTL, returning ErrConversationLockLost when
+// another worker has taken it.
+func (l *ConversationLock) Extend(ctx context.Context) error {
+	n, err := extendConversationLockScript.Run(ctx, l.client, []string{l.key}, l.token, l.ttl.Milliseconds()).Int()
+	if err != nil {
+		return fmt.Errorf("conversation: extend conversation lock: %w", err)
+	}
+	if n == 0 {
+		l.lost.Store(true)
+		return ErrConversationLockLost
+	}
+	return nil
+}
+
+// Lost reports whether the lock was found taken by another worker.
+func (l *ConversationLock) Lost() bool {
+	return l.lost.Load()
+}
+
+// heartbeat extends the lock every third of its TTL until stopped, so long
+// LLM calls and availability searches keep it. It stops on its own once the
+// lock is lost.
+func (l *ConversationLock) heartbeat(ctx context.Context, onError func(error)) (stop func()) {
+	interval := l.ttl / 3
+	if interval <= 0 {
+		interval = time.Second
+	}
+	done := make(chan struct{})
+	finished := make(chan struct{})
+	go func() {
+		defer close(finished)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				extendCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), interval)
+				err := l.Extend(extendCtx)
+				cancel()
+				if err != nil {
+					onError(err)
+					if errors.Is(err, ErrConversationLockLost) {
+						return
+					}
+				}
+			}
+		}
+	}()
+	return func() {
+		close(done)
+		<-finished
+	}
+}
+
+type conversationLockKeyType struct{}
+
+// withConversationLock makes lock fence the history writes made with ctx.
+func withConversationLock(ctx context.Context, lock *ConversationLock) context.Context {
+	return context.WithValue(ctx, conversationLockKeyType{}, lock)
+}
+
+// conversationLockFor returns the lock carried by ctx if it guards
+// conversationID.
+func conversationLockFor(ctx context.Context, conversationID string) *ConversationLock {
+	lock, _ := ctx.Value(conversationLockKeyType{}).(*ConversationLock)
+	if lock == nil || lock.conversationID != conversationID {
+		return nil
+	}
+	return lock
+}
+
+// lockConversation takes the job's conversation lock. It returns false when
+// the job should not run now: the lock stayed held past the wait and the job
+// has been requeued. A nil lock with true means locking doesn't apply.
+func (w *Worker) lockConversation(ctx context.Context, msg queueMessage, payload queuePayload, conversationID string) (*ConversationLock, bool) {
+	conversationID = strings.TrimSpace(conversationID)
+	if w.convLocker == nil || conversationID == "" {
+		return nil, true
+	}
+	lock, err := w.convLocker.Acquire(ctx, conversationID)
+	if err != nil {
+		w.log(ctx).Warn("conversation lock unavailable; processing unlocked", "error", err)
+		return nil, true
+	}
+	if lock == nil {
+		w.log(ctx).Info("conversation busy on another worker; requeueing job")
+		w.requeueAfter(ctx, msg, payload, conversationLockRequeueDelay)
+		return nil, false
+	}
+	return lock, true
+}
@@ -0,0 +1,195 @@
+package conversation
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	miniredis "github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+
+	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
+)
+
+// overlapService tracks how many jobs are inside ProcessMessage at once.
+type overlapService struct {
+	countingService
+	mu      sync.Mutex
+	active  int
+	maxSeen int
+}
+
+func (s *overlapService) ProcessMessage(ctx context.Context, req MessageRequest) (*Response, error) {
+	s.mu.Lock()
+	s.active++
+	if s.active > s.maxSeen {
+		s.maxSeen = s.active
+	}
+	s.mu.Unlock()
+
+	time.Sleep(100 * time.Millisecond)
+
+	s.mu.Lock()
+	s.active--
+	s.mu.Unlock()
+	return s.countingService.ProcessMessage(ctx, req)
+}
+
+func TestWorkersSerializeOneConversationAcrossReplicas(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	service := &overlapService{}
+
+	// Two replicas with their own queues share only Redis.
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var workers []*Worker
+	var queues []*MemoryQueue
+	for i := 0; i < 2; i++ {
+		queue := NewMemoryQueue(4)
+		worker := NewWorker(service, queue, &stubJobUpdater{}, &callbackMessenger{}, nil, logging.Default(),
+			WithWorkerCount(1), WithReceiveBatchSize(1), WithReceiveWaitSeconds(0),
+			WithConversationLocker(NewConversationLocker(client, 5*time.Second, 2*time.Second)))
+		worker.Start(ctx)
+		workers = append(workers, worker)
+		queues = append(queues, queue)
+	}
+
+	// An SQS redelivery lands on one replica while the patient's next text
+	// lands on the other.
+	for i, text := range []string{"redelivered", "fresh"} {
+		body, _ := json.Marshal(queuePayload{ID: logging.NewID(), Kind: jobTypeMessage, Message: MessageRequest{
+			OrgID: "org-1", LeadID: "lead-1", ConversationID: "sms:org-1:15550001111", Message: text,
+			Channel: ChannelSMS, From: "+15550001111", To: "+15559990000",
+		}})
+		if err := queues[i].Send(ctx, string(body)); err != nil {
+			t.Fatalf("send: %v", err)
+		}
+	}
+
+	waitFor(func() bool { return len(service.calls()) == 2 }, 3*time.Second, t)
+	cancel()
+	for _, w := range workers {
+		w.Wait()
+	}
+
+	service.mu.Lock()
+	defer service.mu.Unlock()
+	if service.maxSeen != 1 {
+		t.Fatalf("expected serialized processing, saw %d jobs at once", service.maxSeen)
+	}
+	if mr.Exists(conversationLockKey("sms:org-1:15550001111")) {
+		t.Fatal("expected the lock released after processing")
+	}
+}
+
+// lockStealingService loses its conversation lock to another worker during
+// the first job it processes.
+type lockStealingService struct {
+	countingService
+	mr     *miniredis.Miniredis
+	stolen bool
+}
+
+func (s *lockStealingService) ProcessMessage(ctx context.Context, req MessageRequest) (*Response, error) {
+	s.mu.Lock()
+	steal := !s.stolen
+	s.stolen = true
+	s.mu.Unlock()
+	if steal {
+		key := conversationLockKey(req.ConversationID)
+		_ = s.mr.Set(key, "another-worker")
+		_ = conversationLockFor(ctx, req.ConversationID).Extend(ctx)
+		s.mr.Del(key)
+	}
+	return s.countingService.ProcessMessage(ctx, req)
+}
+
+func TestWorkerRequeuesJobWhenLockLost(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	service := &lockStealingService{mr: mr}
+	messenger := &callbackMessenger{}
+	queue := NewMemoryQueue(4)
+	worker := NewWorker(service, queue, &stubJobUpdater{}, messenger, nil, logging.Default(),
+		WithWorkerCount(1), WithReceiveBatchSize(1), WithReceiveWaitSeconds(0),
+		WithConversationLocker(NewConversationLocker(client, 5*time.Second, time.Second)))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	worker.Start(ctx)
+
+	body, _ := json.Marshal(queuePayload{ID: logging.NewID(), Kind: jobTypeMessage, Message: MessageRequest{
+		OrgID: "org-1", LeadID: "lead-1", ConversationID: "sms:org-1:15550001111", Message: "Do you have Botox openings?",
+		Channel: ChannelSMS, From: "+15550001111", To: "+15559990000",
+	}})
+	if err := queue.Send(ctx, string(body)); err != nil {
+		t.Fatalf("send: %v", err)
+	}
+
+	waitFor(func() bool { return len(service.calls()) == 2 }, 5*time.Second, t)
+	waitFor(func() bool {
+		messenger.mu.Lock()
+		defer messenger.mu.Unlock()
+		return len(messenger.replies) == 1
+	}, time.Second, t)
+	cancel()
+	worker.Wait()
+
+	messenger.mu.Lock()
+	defer messenger.mu.Unlock()
+	if len(messenger.replies) != 1 {
+		t.Fatalf("expected only the requeued job to reply, got %d replies", len(messenger.replies))
+	}
+}
+
+func TestConversationLockFencesExpiredHolder(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	locker := NewConversationLocker(client, time.Second, 0)
+	store := newHistoryStore(client, nil)
+	ctx := context.Background()
+	convID := "sms:org-1:15550001111"
+
+	stale, err := locker.Acquire(ctx, convID)
+	if err != nil || stale == nil {
+		t.Fatalf("acquire: lock=%v err=%v", stale, err)
+	}
+	if busy, _ := locker.Acquire(ctx, convID); busy != nil {
+		t.Fatal("expected the held lock to block a second holder")
+	}
+
+	// The first holder stalls mid-LLM-call past its TTL; another worker
+	// takes over the conversation.
+	mr.FastForward(2 * time.Second)
+	fresh, err := locker.Acquire(ctx, convID)
+	if err != nil || fresh == nil {
+		t.Fatalf("acquire after expiry: lock=%v err=%v", fresh, err)
+	}
+	if err := store.Save(withConversationLock(ctx, fresh), convID, []ChatMessage{{Role: ChatRoleUser, Content: "fresh"}}); err != nil {
+		t.Fatalf("save under fresh lock: %v", err)
+	}
+
+	err = store.Save(withConversationLock(ctx, stale), convID, []ChatMessage{{Role: ChatRoleUser, Content: "stale"}})
+	if !errors.Is(err, ErrConversationLockLost) || !stale.Lost() {
+		t.Fatalf("expected the stale write fenced off, got %v", err)
+	}
+	history, err := store.Load(ctx, convID)
+	if err != nil || len(history) != 1 || history[0].Content != "fresh" {
+		t.Fatalf("history = %+v (err %v), want the fresh holder's", history, err)
+	}
+	if err := stale.Extend(ctx); !errors.Is(err, ErrConversationLockLost) {
+		t.Fatalf("extend stale lock: %v", err)
+	}
+
+	stale.Release()
+	if !mr.Exists(conversationLockKey(convID)) {
+		t.Fatal("stale release must not free the fresh holder's lock")
+	}
+	fresh.Release()
+	if mr.Exists(conversationLockKey(convID)) {
+		t.Fatal("expected the fresh holder's release to free the lock")
+	}
+}
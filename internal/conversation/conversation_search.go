@@ -0,0 +1,336 @@
+package conversation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/google/uuid"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/pii"
+	"github.com/wolfman30/medspa-ai-platform/internal/tenancy"
+)
+
+const (
+	// searchCandidateLimit caps how many matching messages are ranked per
+	// search; the most recent matches win when a query is very broad.
+	searchCandidateLimit = 500
+	// searchRecencyHalfLife is how fast a match loses its recency boost.
+	searchRecencyHalfLife = 30 * 24 * time.Hour
+
+	searchSnippetsPerConversation = 3
+	searchSnippetRadius           = 60 // characters either side of the first hit
+
+	// SearchHighlightStart and SearchHighlightEnd wrap matched terms in snippets.
+	SearchHighlightStart = "<mark>"
+	SearchHighlightEnd   = "</mark>"
+)
+
+// ErrEmptySearchQuery is returned when a search has no terms.
+var ErrEmptySearchQuery = errors.New("conversation: search query is empty")
+
+// DateRange bounds a search by message time. Zero values leave that end open;
+// To is exclusive.
+type DateRange struct {
+	From time.Time
+	To   time.Time
+}
+
+// MessageSnippet is a highlighted excerpt of one matching message.
+type MessageSnippet struct {
+	MessageID uuid.UUID
+	Role      string
+	Snippet   string
+	CreatedAt time.Time
+}
+
+// ConversationSearchResult is a conversation with messages matching a search.
+type ConversationSearchResult struct {
+	ConversationID string
+	OrgID          string
+	Phone          string
+	CustomerName   string
+	Status         string
+	MatchCount     int
+	LastMatchAt    time.Time
+	Score          float64
+	Snippets       []MessageSnippet
+}
+
+// searchMatch is one matching message as returned by the database.
+type searchMatch struct {
+	MessageID      uuid.UUID
+	ConversationID string
+	OrgID          string
+	Phone          string
+	CustomerName   string
+	Status         string
+	Role           string
+	Content        string
+	CreatedAt      time.Time
+	Rank           float64
+	MatchCount     int
+}
+
+// SearchMessages finds an org's conversations whose messages match query,
+// which accepts web-search syntax: words are ANDed, "quoted phrases" match
+// in order, OR and -excluded words work as usual. Conversations are ranked
+// by their best-matching message, boosted by how recently it was sent.
+func (s *ConversationStore) SearchMessages(ctx context.Context, scope tenancy.OrgScope, query string, dateRange DateRange, limit int) ([]ConversationSearchResult, error) {
+	if s == nil || s.db == nil {
+		return nil, nil
+	}
+	orgID, err := scope.SingleOrg()
+	if err != nil {
+		return nil, fmt.Errorf("conversation: search messages: %w", err)
+	}
+	query = strings.TrimSpace(query)
+	if len(searchTerms(query)) == 0 {
+		return nil, ErrEmptySearchQuery
+	}
+
+	sqlQuery := `
+		SELECT m.id, m.conversation_id, c.org_id, c.phone,
+			   COALESCE(NULLIF(l.name, ''), c.customer_name, ''), c.status,
+			   m.role, m.content, m.created_at,
+			   ts_rank(m.content_tsv, q.query),
+			   COUNT(*) OVER (PARTITION BY m.conversation_id)
+		FROM conversation_messages m
+		JOIN conversations c ON c.conversation_id = m.conversation_id
+		LEFT JOIN leads l ON l.id = c.lead_id
+		CROSS JOIN websearch_to_tsquery('english', $2) AS q(query)
+		WHERE c.org_id = $1 AND m.content_tsv @@ q.query
+	`
+	args := []any{orgID, query}
+	if !dateRange.From.IsZero() {
+		args = append(args, dateRange.From)
+		sqlQuery += " AND m.created_at >= $" + strconv.Itoa(len(args))
+	}
+	if !dateRange.To.IsZero() {
+		args = append(args, dateRange.To)
+		sqlQuery += " AND m.created_at < $" + strconv.Itoa(len(args))
+	}
+	args = append(args, searchCandidateLimit)
+	sqlQuery += " ORDER BY m.created_at DESC LIMIT $" + strconv.Itoa(len(args))
+
+	rows, err := s.db.QueryContext(ctx, sqlQuery, args...)
+	if err != nil {
+		return nil, fmt.Errorf("conversation: search messages: %w", err)
+	}
+	defer rows.Close()
+
+	var matches []searchMatch
+	for rows.Next() {
+		var m searchMatch
+		if err := rows.Scan(
+			&m.MessageID, &m.ConversationID, &m.OrgID, &m.Phone,
+			&m.CustomerName, &m.Status,
+			&m.Role, &m.Content, &m.CreatedAt,
+			&m.Rank, &m.MatchCount,
+		); err != nil {
+			return nil, fmt.Errorf("conversation: scan search match: %w", err)
+		}
+		m.CustomerName = pii.Reveal(m.CustomerName)
+		// Belt and braces: a row from another org must never leak out.
+		if m.OrgID != orgID {
+			continue
+		}
+		matches = append(matches, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("conversation: search messages: %w", err)
+	}
+	return rankSearchMatches(matches, query, time.Now(), limit), nil
+}
+
+// rankSearchMatches groups matches by conversation, scores each by its best
+// match, and returns the top limit with highlighted snippets.
+func rankSearchMatches(matches []searchMatch, query string, now time.Time, limit int) []ConversationSearchResult {
+	terms := searchTerms(query)
+	byConversation := make(map[string]*ConversationSearchResult)
+	var order []string
+	for _, m := range matches {
+		res, ok := byConversation[m.ConversationID]
+		if !ok {
+			res = &ConversationSearchResult{
+				ConversationID: m.ConversationID,
+				OrgID:          m.OrgID,
+				Phone:          m.Phone,
+				CustomerName:   m.CustomerName,
+				Status:         m.Status,
+				MatchCount:     m.MatchCount,
+			}
+			byConversation[m.ConversationID] = res
+			order = append(order, m.ConversationID)
+		}
+		if m.CreatedAt.After(res.LastMatchAt) {
+			res.LastMatchAt = m.CreatedAt
+		}
+		if score := searchScore(m.Rank, now.Sub(m.CreatedAt)); score > res.Score {
+			res.Score = score
+		}
+		if len(res.Snippets) < searchSnippetsPerConversation {
+			res.Snippets = append(res.Snippets, MessageSnippet{
+				MessageID: m.MessageID,
+				Role:      m.Role,
+				Snippet:   highlightSnippet(m.Content, terms),
+				CreatedAt: m.CreatedAt,
+			})
+		}
+	}
+
+	results := make([]ConversationSearchResult, 0, len(order))
+	for _, id := range order {
+		results = append(results, *byConversation[id])
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return results[i].LastMatchAt.After(results[j].LastMatchAt)
+	})
+	if limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
+	return results
+}
+
+// searchScore weights a text rank by recency: a fresh match keeps its full
+// rank, and the boost halves every half-life down to a floor of half.
+func searchScore(rank float64, age time.Duration) float64 {
+	if age < 0 {
+		age = 0
+	}
+	recency := math.Pow(0.5, float64(age)/float64(searchRecencyHalfLife))
+	return rank * (0.5 + 0.5*recency)
+}
+
+// searchTerms extracts the words and quoted phrases worth highlighting from
+// a web-search style query, skipping OR and excluded (-word) terms.
+func searchTerms(query string) []string {
+	var terms []string
+	for len(query) > 0 {
+		query = strings.TrimLeftFunc(query, unicode.IsSpace)
+		if query == "" {
+			break
+		}
+		var term string
+		excluded := false
+		if query[0] == '-' {
+			excluded = true
+			query = query[1:]
+		}
+		if strings.HasPrefix(query, `"`) {
+			end := strings.Index(query[1:], `"`)
+			if end < 0 {
+				term, query = query[1:], ""
+			} else {
+				term, query = query[1:end+1], query[end+2:]
+			}
+		} else {
+			end := strings.IndexFunc(query, unicode.IsSpace)
+			if end < 0 {
+				end = len(query)
+			}
+			term, query = query[:end], query[end:]
+			if strings.EqualFold(term, "or") {
+				continue
+			}
+		}
+		term = strings.Join(strings.FieldsFunc(term, func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '\''
+		}), " ")
+		if term != "" && !excluded {
+			terms = append(terms, term)
+		}
+	}
+	return terms
+}
+
+// highlightSnippet returns an excerpt of content around the first term hit,
+// with every hit wrapped in highlight markers. Terms match case-insensitively
+// at the start of a word, so "groupon" also marks "Groupon's". The text is
+// HTML-escaped, so only the markers are markup.
+func highlightSnippet(content string, terms []string) string {
+	type hit struct{ start, end int }
+	lower := strings.ToLower(content)
+	if len(lower) != len(content) {
+		// Case folding changed byte offsets; fall back to exact matching.
+		lower = content
+	}
+	var hits []hit
+	for _, term := range terms {
+		needle := strings.ToLower(term)
+		for from := 0; from < len(lower); {
+			i := strings.Index(lower[from:], needle)
+			if i < 0 {
+				break
+			}
+			start := from + i
+			end := start + len(needle)
+			if start == 0 || !isWordByte(lower[start-1]) {
+				// Extend to the end of the word so stems highlight whole words.
+				for end < len(lower) && isWordByte(lower[end]) {
+					end++
+				}
+				hits = append(hits, hit{start, end})
+			}
+			from = end
+		}
+	}
+	sort.Slice(hits, func(i, j int) bool { return hits[i].start < hits[j].start })
+
+	// Window the excerpt around the first hit, snapped to word boundaries.
+	winStart, winEnd := 0, len(content)
+	if len(hits) > 0 {
+		winStart = hits[0].start - searchSnippetRadius
+		winEnd = hits[0].end + searchSnippetRadius
+	} else {
+		winEnd = 2 * searchSnippetRadius
+	}
+	if winStart <= 0 {
+		winStart = 0
+	} else if i := strings.IndexByte(content[winStart:], ' '); i >= 0 && winStart+i < hits[0].start {
+		winStart += i + 1
+	}
+	if winEnd >= len(content) {
+		winEnd = len(content)
+	} else if i := strings.LastIndexByte(content[:winEnd], ' '); i > winStart {
+		winEnd = i
+	}
+	if len(hits) > 0 && winEnd < hits[0].end {
+		winEnd = hits[0].end
+	}
+
+	var b strings.Builder
+	if winStart > 0 {
+		b.WriteString("…")
+	}
+	pos := winStart
+	for _, h := range hits {
+		if h.start < pos || h.end > winEnd {
+			continue
+		}
+		b.WriteString(html.EscapeString(content[pos:h.start]))
+		b.WriteString(SearchHighlightStart)
+		b.WriteString(html.EscapeString(content[h.start:h.end]))
+		b.WriteString(SearchHighlightEnd)
+		pos = h.end
+	}
+	b.WriteString(html.EscapeString(content[pos:winEnd]))
+	if winEnd < len(content) {
+		b.WriteString("…")
+	}
+	return b.String()
+}
+
+func isWordByte(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= '0' && c <= '9' || c == '\'' || c >= 0x80
+}
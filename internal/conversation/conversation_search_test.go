@@ -0,0 +1,157 @@
+package conversation
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/tenancy"
+)
+
+var searchColumns = []string{
+	"id", "conversation_id", "org_id", "phone", "customer_name", "status",
+	"role", "content", "created_at", "rank", "match_count",
+}
+
+func TestSearchTerms(t *testing.T) {
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"groupon", []string{"groupon"}},
+		{`  Groupon   "Dr. Smith" `, []string{"Groupon", "Dr Smith"}},
+		{"botox or filler -lips", []string{"botox", "filler"}},
+		{`"unterminated phrase`, []string{"unterminated phrase"}},
+		{"-- OR", nil},
+	}
+	for _, tt := range tests {
+		if got := searchTerms(tt.query); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("searchTerms(%q) = %#v, want %#v", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestHighlightSnippet(t *testing.T) {
+	got := highlightSnippet("I have a Groupon's voucher from groupon.com", []string{"groupon"})
+	want := "I have a <mark>Groupon&#39;s</mark> voucher from <mark>groupon</mark>.com"
+	if got != want {
+		t.Fatalf("highlightSnippet = %q, want %q", got, want)
+	}
+
+	// Phrases highlight as a unit, extended to the end of the word like stems.
+	got = highlightSnippet("Can Dr Smith see me? Not Dr Smithson.", []string{"dr smith"})
+	if want := "Can <mark>Dr Smith</mark> see me? Not <mark>Dr Smithson</mark>."; got != want {
+		t.Fatalf("highlightSnippet phrase = %q, want %q", got, want)
+	}
+	// Text in the middle of a word doesn't match.
+	if got := highlightSnippet("Unpromoted price", []string{"promo"}); got != "Unpromoted price" {
+		t.Fatalf("expected mid-word text not to be highlighted, got %q", got)
+	}
+
+	// Patient text is escaped so it can't inject markup around the markers.
+	got = highlightSnippet(`<img src=x onerror="alert(1)"> groupon & <b>deal</b>`, []string{"groupon"})
+	if want := `&lt;img src=x onerror=&#34;alert(1)&#34;&gt; <mark>groupon</mark> &amp; &lt;b&gt;deal&lt;/b&gt;`; got != want {
+		t.Fatalf("highlightSnippet escaping = %q, want %q", got, want)
+	}
+
+	long := "We are open late on Thursdays and most weekends, and our injectors are all licensed nurses. " +
+		"Yes, we honor the Groupon deal for first-time patients as long as it was purchased this year. " +
+		"Let me know which day works best for you and I can hold a spot."
+	got = highlightSnippet(long, []string{"groupon"})
+	if got[:len("…")] != "…" || got[len(got)-len("…"):] != "…" {
+		t.Fatalf("expected an ellipsized excerpt, got %q", got)
+	}
+	if !regexp.MustCompile(`\bthe <mark>Groupon</mark> deal\b`).MatchString(got) {
+		t.Fatalf("expected the excerpt around the hit, got %q", got)
+	}
+}
+
+func TestRankSearchMatches_OrdersByRankAndRecency(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	match := func(convID string, rank float64, age time.Duration, count int) searchMatch {
+		return searchMatch{
+			MessageID:      uuid.New(),
+			ConversationID: convID,
+			OrgID:          "org-1",
+			Content:        "used a groupon",
+			CreatedAt:      now.Add(-age),
+			Rank:           rank,
+			MatchCount:     count,
+		}
+	}
+	day := 24 * time.Hour
+	matches := []searchMatch{
+		match("sms:org-1:recent-weak", 0.05, day, 1),
+		match("sms:org-1:strong", 0.09, 2*day, 2),
+		match("sms:org-1:strong", 0.03, 3*day, 2),
+		match("sms:org-1:old-strong", 0.09, 120*day, 1),
+		match("sms:org-1:recent-equal", 0.05, day, 1),
+	}
+
+	results := rankSearchMatches(matches, "groupon", now, 0)
+	var got []string
+	for _, r := range results {
+		got = append(got, r.ConversationID)
+	}
+	want := []string{"sms:org-1:strong", "sms:org-1:recent-weak", "sms:org-1:recent-equal", "sms:org-1:old-strong"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("order = %v, want %v", got, want)
+	}
+
+	strong := results[0]
+	if strong.MatchCount != 2 || len(strong.Snippets) != 2 || !strong.LastMatchAt.Equal(now.Add(-2*day)) {
+		t.Fatalf("unexpected grouping: %+v", strong)
+	}
+	if strong.Snippets[0].Snippet != "used a <mark>groupon</mark>" {
+		t.Fatalf("expected a highlighted snippet, got %q", strong.Snippets[0].Snippet)
+	}
+
+	if limited := rankSearchMatches(matches, "groupon", now, 2); len(limited) != 2 || limited[0].ConversationID != "sms:org-1:strong" {
+		t.Fatalf("expected the top 2 results, got %+v", limited)
+	}
+}
+
+func TestSearchMessages_ScopesToOrg(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock: %v", err)
+	}
+	defer db.Close()
+	store := NewConversationStore(db)
+
+	from := time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	created := time.Date(2026, 2, 20, 15, 0, 0, 0, time.UTC)
+	rows := sqlmock.NewRows(searchColumns).
+		AddRow(uuid.New(), "sms:org-1:+15550001111", "org-1", "+15550001111", "Jane", "active",
+			"user", `Do you take "Groupon"?`, created, 0.08, 1).
+		AddRow(uuid.New(), "sms:org-2:+15550002222", "org-2", "+15550002222", "Mallory", "active",
+			"user", "groupon please", created, 0.1, 1)
+	mock.ExpectQuery(`WHERE c\.org_id = \$1 AND m\.content_tsv @@ q\.query AND m\.created_at >= \$3 AND m\.created_at < \$4 ORDER BY m\.created_at DESC LIMIT \$5`).
+		WithArgs("org-1", `groupon "dr smith"`, from, to, searchCandidateLimit).
+		WillReturnRows(rows)
+
+	results, err := store.SearchMessages(context.Background(), tenancy.ForOrg("org-1"), ` groupon "dr smith" `, DateRange{From: from, To: to}, 10)
+	if err != nil {
+		t.Fatalf("SearchMessages: %v", err)
+	}
+	if len(results) != 1 || results[0].OrgID != "org-1" || results[0].CustomerName != "Jane" {
+		t.Fatalf("expected only org-1's conversation, got %+v", results)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+
+	if _, err := store.SearchMessages(context.Background(), tenancy.ForOrg("org-1"), ` "" -x `, DateRange{}, 10); !errors.Is(err, ErrEmptySearchQuery) {
+		t.Fatalf("expected ErrEmptySearchQuery, got %v", err)
+	}
+	if _, err := store.SearchMessages(context.Background(), tenancy.OrgScope{}, "groupon", DateRange{}, 10); err == nil {
+		t.Fatal("expected an error without an org")
+	}
+}
@@ -4,16 +4,25 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/google/uuid"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/conversation/msgschema"
+	"github.com/wolfman30/medspa-ai-platform/internal/convstream"
+	"github.com/wolfman30/medspa-ai-platform/internal/tenancy"
+	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
+	"github.com/wolfman30/medspa-ai-platform/pkg/phone"
 )
 
 // ConversationStore persists conversations and messages to PostgreSQL for long-term history.
 type ConversationStore struct {
 	db             *sql.DB
 	excludedPhones map[string]struct{}
+	updates        *convstream.Publisher
+	logger         *logging.Logger
 }
 
 // NewConversationStore creates a new conversation store.
@@ -31,7 +40,7 @@ func NewConversationStoreWithExclusions(db *sql.DB, excludePhones []string) *Con
 	}
 	excluded := make(map[string]struct{})
 	for _, phone := range excludePhones {
-		digits := normalizePhoneDigits(phone)
+		digits := canonicalPhoneDigits(phone)
 		if digits != "" {
 			excluded[digits] = struct{}{}
 		}
@@ -39,19 +48,57 @@ func NewConversationStoreWithExclusions(db *sql.DB, excludePhones []string) *Con
 	return &ConversationStore{db: db, excludedPhones: excluded}
 }
 
-// normalizePhoneDigits strips non-digits and normalizes 10-digit US numbers to 11-digit format.
-func normalizePhoneDigits(phone string) string {
-	var digits strings.Builder
-	for _, r := range phone {
-		if r >= '0' && r <= '9' {
-			digits.WriteRune(r)
-		}
+// SetUpdatePublisher makes status and delivery changes show up on the
+// operator portal's live conversation stream.
+func (s *ConversationStore) SetUpdatePublisher(p *convstream.Publisher, logger *logging.Logger) {
+	if s == nil {
+		return
+	}
+	if logger == nil {
+		logger = logging.Default()
+	}
+	s.updates = p
+	s.logger = logger
+}
+
+// publish sends a live update; failures are logged and never fail the write.
+func (s *ConversationStore) publish(ctx context.Context, conversationID, eventType string, data any) {
+	if s.updates == nil {
+		return
+	}
+	if _, err := s.updates.Publish(ctx, conversationID, eventType, data); err != nil {
+		s.logger.Warn("failed to publish conversation update", "error", err, "conversation_id", conversationID, "type", eventType)
+	}
+}
+
+// canonicalPhoneDigits returns a phone's E.164 digits, the form conversation
+// IDs are built from.
+func canonicalPhoneDigits(raw string) string {
+	return phone.Digits(raw)
+}
+
+// conversationIDAliases returns the other IDs a conversation may have been
+// stored under before conversation IDs were built from canonical phone
+// digits: the bare ten-digit US number and the "+"-prefixed form. The
+// canonical ID comes first.
+func conversationIDAliases(conversationID string) []string {
+	parts := strings.Split(conversationID, ":")
+	if len(parts) != 3 || parts[2] == "" {
+		return nil
 	}
-	d := digits.String()
-	if len(d) == 10 {
-		return "1" + d
+	prefix := parts[0] + ":" + parts[1] + ":"
+	digits := phone.Digits(parts[2])
+	if digits == "" {
+		return nil
+	}
+	candidates := append([]string{digits, "+" + digits}, phone.LegacyDigits(parts[2])...)
+	aliases := make([]string, 0, len(candidates))
+	for _, candidate := range candidates {
+		if id := prefix + candidate; id != conversationID {
+			aliases = append(aliases, id)
+		}
 	}
-	return d
+	return aliases
 }
 
 // ConversationRecord represents a conversation in the database.
@@ -76,6 +123,8 @@ type MessageRecord struct {
 	ID                uuid.UUID
 	ConversationID    string
 	Role              string
+	Direction         string
+	AuthorType        string
 	Content           string
 	FromPhone         string
 	ToPhone           string
@@ -103,7 +152,7 @@ func (s *ConversationStore) isPhoneExcluded(phone string) bool {
 	if s == nil || len(s.excludedPhones) == 0 {
 		return false
 	}
-	digits := normalizePhoneDigits(phone)
+	digits := canonicalPhoneDigits(phone)
 	_, excluded := s.excludedPhones[digits]
 	return excluded
 }
@@ -201,13 +250,16 @@ func (s *ConversationStore) AppendMessage(ctx context.Context, conversationID st
 		timestamp = time.Now()
 	}
 
+	msg = msg.withSchema()
 	result, err := s.db.ExecContext(ctx, `
 		INSERT INTO conversation_messages (
 			id, conversation_id, role, content, from_phone, to_phone,
-			provider_message_id, status, error_reason, created_at
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, COALESCE(NULLIF($8, ''), 'delivered'), NULLIF($9, ''), $10)
+			provider_message_id, status, error_reason, kind, created_at,
+			direction, author_type
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, COALESCE(NULLIF($8, ''), 'delivered'), NULLIF($9, ''), NULLIF($10, ''), $11, $12, $13)
 		ON CONFLICT (id) DO NOTHING
-	`, msgID, conversationID, msg.Role, msg.Body, msg.From, msg.To, msg.ProviderMessageID, msg.Status, msg.ErrorReason, timestamp)
+	`, msgID, conversationID, msg.Role, msg.Body, msg.From, msg.To, msg.ProviderMessageID, msg.Status, msg.ErrorReason, msg.Kind, timestamp,
+		msg.Direction, msg.AuthorType)
 
 	if err != nil {
 		return fmt.Errorf("conversation: failed to insert message: %w", err)
@@ -221,11 +273,13 @@ func (s *ConversationStore) AppendMessage(ctx context.Context, conversationID st
 		return nil
 	}
 
-	// Update conversation counters
+	// Update conversation counters. Staff texts count toward neither the
+	// customer nor the AI total.
 	counterColumn := "message_count"
-	if msg.Role == "user" {
+	switch author := msgschema.Author(msg.AuthorType); {
+	case author == msgschema.Patient:
 		counterColumn = "customer_message_count"
-	} else if msg.Role == "assistant" {
+	case author != msgschema.Staff && msg.Role == "assistant":
 		counterColumn = "ai_message_count"
 	}
 
@@ -264,23 +318,44 @@ func (s *ConversationStore) EndConversation(ctx context.Context, conversationID
 	return nil
 }
 
-// GetConversation retrieves a conversation by its ID.
-func (s *ConversationStore) GetConversation(ctx context.Context, conversationID string) (*ConversationRecord, error) {
+// GetConversation retrieves a conversation by its ID. Conversations stored
+// under an older phone format (see conversationIDAliases) are found too.
+func (s *ConversationStore) GetConversation(ctx context.Context, scope tenancy.OrgScope, conversationID string) (*ConversationRecord, error) {
 	if s == nil || s.db == nil {
 		return nil, nil
 	}
+	if err := scope.Err(); err != nil {
+		return nil, err
+	}
+	conv, err := s.getConversation(ctx, scope, conversationID)
+	if conv != nil || err != nil {
+		return conv, err
+	}
+	for _, alias := range conversationIDAliases(conversationID) {
+		if conv, err = s.getConversation(ctx, scope, alias); conv != nil || err != nil {
+			return conv, err
+		}
+	}
+	return nil, nil
+}
 
+func (s *ConversationStore) getConversation(ctx context.Context, scope tenancy.OrgScope, conversationID string) (*ConversationRecord, error) {
 	var conv ConversationRecord
 	var leadID sql.NullString
 	var lastMessageAt, endedAt sql.NullTime
 
-	err := s.db.QueryRowContext(ctx, `
+	query := `
 		SELECT id, conversation_id, org_id, lead_id, phone, status, channel,
 			   message_count, customer_message_count, ai_message_count,
 			   started_at, last_message_at, ended_at
 		FROM conversations
-		WHERE conversation_id = $1
-	`, conversationID).Scan(
+		WHERE conversation_id = $1`
+	args := []any{conversationID}
+	if cond, condArgs := scope.Where("org_id", 2); cond != "" {
+		query += " AND " + cond
+		args = append(args, condArgs...)
+	}
+	err := s.db.QueryRowContext(ctx, query, args...).Scan(
 		&conv.ID, &conv.ConversationID, &conv.OrgID, &leadID, &conv.Phone,
 		&conv.Status, &conv.Channel, &conv.MessageCount, &conv.CustomerMessageCount,
 		&conv.AIMessageCount, &conv.StartedAt, &lastMessageAt, &endedAt,
@@ -309,24 +384,31 @@ func (s *ConversationStore) GetConversation(ctx context.Context, conversationID
 }
 
 // GetMessages retrieves messages for a conversation.
-func (s *ConversationStore) GetMessages(ctx context.Context, conversationID string, limit int) ([]MessageRecord, error) {
+func (s *ConversationStore) GetMessages(ctx context.Context, scope tenancy.OrgScope, conversationID string, limit int) ([]MessageRecord, error) {
 	if s == nil || s.db == nil {
 		return nil, nil
 	}
+	if err := scope.Err(); err != nil {
+		return nil, err
+	}
 
 	query := `
-		SELECT id, conversation_id, role, content, from_phone, to_phone,
+		SELECT id, conversation_id, role, COALESCE(direction, ''), COALESCE(author_type, ''),
+			   content, from_phone, to_phone,
 			   COALESCE(provider_message_id, ''), COALESCE(status, 'delivered'),
 			   COALESCE(error_reason, ''), created_at
 		FROM conversation_messages
-		WHERE conversation_id = $1
-		ORDER BY created_at ASC
-	`
+		WHERE conversation_id = $1`
 	args := []any{conversationID}
+	if cond, condArgs := scope.Where("org_id", 2); cond != "" {
+		query += " AND conversation_id IN (SELECT conversation_id FROM conversations WHERE " + cond + ")"
+		args = append(args, condArgs...)
+	}
+	query += " ORDER BY created_at ASC"
 
 	if limit > 0 {
-		query += " LIMIT $2"
 		args = append(args, limit)
+		query += " LIMIT $" + strconv.Itoa(len(args))
 	}
 
 	rows, err := s.db.QueryContext(ctx, query, args...)
@@ -339,7 +421,7 @@ func (s *ConversationStore) GetMessages(ctx context.Context, conversationID stri
 	for rows.Next() {
 		var msg MessageRecord
 		err := rows.Scan(
-			&msg.ID, &msg.ConversationID, &msg.Role, &msg.Content,
+			&msg.ID, &msg.ConversationID, &msg.Role, &msg.Direction, &msg.AuthorType, &msg.Content,
 			&msg.FromPhone, &msg.ToPhone, &msg.ProviderMessageID,
 			&msg.Status, &msg.ErrorReason, &msg.CreatedAt,
 		)
@@ -360,7 +442,8 @@ func (s *ConversationStore) LinkLead(ctx context.Context, conversationID string,
 
 	now := time.Now()
 
-	// Update lead_id and also sync customer_name from leads table
+	// Update lead_id and also sync customer_name from leads table. The name is
+	// copied as stored, so it stays encrypted when lead PII is.
 	_, err := s.db.ExecContext(ctx, `
 		UPDATE conversations SET
 			lead_id = $1,
@@ -375,30 +458,47 @@ func (s *ConversationStore) LinkLead(ctx context.Context, conversationID string,
 }
 
 // UpdateStatus updates the status of a conversation.
-func (s *ConversationStore) UpdateStatus(ctx context.Context, conversationID, status string) error {
+func (s *ConversationStore) UpdateStatus(ctx context.Context, scope tenancy.OrgScope, conversationID, status string) error {
 	if s == nil || s.db == nil {
 		return nil
 	}
+	if err := scope.Err(); err != nil {
+		return err
+	}
 
-	_, err := s.db.ExecContext(ctx, `
+	query := `
 		UPDATE conversations SET status = $1, updated_at = $2
-		WHERE conversation_id = $3
-	`, status, time.Now(), conversationID)
+		WHERE conversation_id = $3`
+	args := []any{status, time.Now(), conversationID}
+	if cond, condArgs := scope.Where("org_id", 4); cond != "" {
+		query += " AND " + cond
+		args = append(args, condArgs...)
+	}
+	_, err := s.db.ExecContext(ctx, query, args...)
 	if err != nil {
 		return fmt.Errorf("UpdateStatus %s: %w", conversationID, err)
 	}
+	s.publish(ctx, conversationID, convstream.TypeStatus, convstream.StatusUpdate{Status: status})
 	return nil
 }
 
-// UpdateStatusByPhone updates conversation status by org_id and phone number.
+// UpdateStatusByPhone updates conversation status by org and phone number.
 // This is useful when we have lead phone but not the full conversation_id.
-func (s *ConversationStore) UpdateStatusByPhone(ctx context.Context, orgID, phone, status string) error {
+func (s *ConversationStore) UpdateStatusByPhone(ctx context.Context, scope tenancy.OrgScope, phone, status string) error {
 	if s == nil || s.db == nil {
 		return nil
 	}
+	orgID, err := scope.SingleOrg()
+	if err != nil {
+		return fmt.Errorf("UpdateStatusByPhone: %w", err)
+	}
 
-	conversationID := fmt.Sprintf("sms:%s:%s", orgID, phone)
-	return s.UpdateStatus(ctx, conversationID, status)
+	conversationID := smsConversationID(orgID, phone)
+	// Update the row where it actually lives if it predates canonical IDs.
+	if conv, err := s.GetConversation(ctx, scope, conversationID); err == nil && conv != nil {
+		conversationID = conv.ConversationID
+	}
+	return s.UpdateStatus(ctx, scope, conversationID, status)
 }
 
 // UpdateMessageStatusByProviderID updates a message's status by provider message ID.
@@ -410,15 +510,32 @@ func (s *ConversationStore) UpdateMessageStatusByProviderID(ctx context.Context,
 	if providerMessageID == "" {
 		return nil
 	}
-	_, err := s.db.ExecContext(ctx, `
+	rows, err := s.db.QueryContext(ctx, `
 		UPDATE conversation_messages
 		SET status = $2,
 			error_reason = COALESCE(NULLIF($3, ''), error_reason)
 		WHERE provider_message_id = $1
+		RETURNING conversation_id
 	`, providerMessageID, status, errorReason)
 	if err != nil {
 		return fmt.Errorf("conversation: update message status by provider id: %w", err)
 	}
+	defer rows.Close()
+	var conversationIDs []string
+	for rows.Next() {
+		var conversationID string
+		if err := rows.Scan(&conversationID); err != nil {
+			return fmt.Errorf("conversation: scan updated message: %w", err)
+		}
+		conversationIDs = append(conversationIDs, conversationID)
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("conversation: update message status by provider id: %w", err)
+	}
+	update := convstream.DeliveryUpdate{ProviderMessageID: providerMessageID, Status: status, ErrorReason: errorReason}
+	for _, conversationID := range conversationIDs {
+		s.publish(ctx, conversationID, convstream.TypeDelivery, update)
+	}
 	return nil
 }
 
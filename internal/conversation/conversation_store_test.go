@@ -0,0 +1,137 @@
+package conversation
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/convstream"
+	"github.com/wolfman30/medspa-ai-platform/internal/tenancy"
+)
+
+var conversationColumns = []string{
+	"id", "conversation_id", "org_id", "lead_id", "phone", "status", "channel",
+	"message_count", "customer_message_count", "ai_message_count",
+	"started_at", "last_message_at", "ended_at",
+}
+
+func TestSMSConversationIDIsCanonical(t *testing.T) {
+	for _, phone := range []string{"+15005550002", "5005550002", "(500) 555-0002", "1-500-555-0002"} {
+		if got := smsConversationID("org-1", phone); got != "sms:org-1:15005550002" {
+			t.Errorf("smsConversationID(%q) = %q", phone, got)
+		}
+	}
+}
+
+func TestConversationIDAliases(t *testing.T) {
+	tests := []struct {
+		id   string
+		want []string
+	}{
+		{"sms:org-1:15005550002", []string{"sms:org-1:+15005550002", "sms:org-1:5005550002"}},
+		{"sms:org-1:5005550002", []string{"sms:org-1:15005550002", "sms:org-1:+15005550002"}},
+		{"voice:org-1:+15005550002", []string{"voice:org-1:15005550002", "voice:org-1:5005550002"}},
+		{"not-a-conversation", nil},
+	}
+	for _, tt := range tests {
+		if got := conversationIDAliases(tt.id); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("conversationIDAliases(%q) = %v, want %v", tt.id, got, tt.want)
+		}
+	}
+}
+
+func TestGetConversationResolvesLegacyID(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	// Stored before IDs were canonical, under the bare ten-digit number.
+	legacyID := "sms:org-1:5005550002"
+	mock.ExpectQuery("FROM conversations").WithArgs("sms:org-1:15005550002", "org-1").WillReturnError(sql.ErrNoRows)
+	mock.ExpectQuery("FROM conversations").WithArgs("sms:org-1:+15005550002", "org-1").WillReturnError(sql.ErrNoRows)
+	mock.ExpectQuery("FROM conversations").WithArgs(legacyID, "org-1").WillReturnRows(sqlmock.NewRows(conversationColumns).
+		AddRow(uuid.New(), legacyID, "org-1", nil, "5005550002", "active", "sms", 3, 2, 1, time.Now(), nil, nil))
+
+	store := NewConversationStore(db)
+	conv, err := store.GetConversation(context.Background(), tenancy.ForOrg("org-1"), smsConversationID("org-1", "+1 500 555 0002"))
+	if err != nil {
+		t.Fatalf("GetConversation: %v", err)
+	}
+	if conv == nil || conv.ConversationID != legacyID {
+		t.Fatalf("expected legacy conversation, got %+v", conv)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestGetConversationMissingEverywhere(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	for i := 0; i < 3; i++ {
+		mock.ExpectQuery("FROM conversations").WillReturnError(sql.ErrNoRows)
+	}
+	conv, err := NewConversationStore(db).GetConversation(context.Background(), tenancy.ForOrg("org-1"), "sms:org-1:15005550002")
+	if err != nil || conv != nil {
+		t.Fatalf("expected no conversation, got %+v, %v", conv, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestConversationStorePublishesStatusAndDelivery(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock: %v", err)
+	}
+	defer db.Close()
+	mr := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	store := NewConversationStore(db)
+	store.SetUpdatePublisher(convstream.NewPublisher(rdb), nil)
+
+	mock.ExpectExec("UPDATE conversations SET status").
+		WithArgs(StatusBooked, sqlmock.AnyArg(), "sms:org-1:15005550002", "org-1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery("UPDATE conversation_messages").
+		WithArgs("msg-1", "failed", "30007").
+		WillReturnRows(sqlmock.NewRows([]string{"conversation_id"}).AddRow("sms:org-1:15005550002"))
+
+	ctx := context.Background()
+	if err := store.UpdateStatus(ctx, tenancy.ForOrg("org-1"), "sms:org-1:15005550002", StatusBooked); err != nil {
+		t.Fatalf("update status: %v", err)
+	}
+	if err := store.UpdateMessageStatusByProviderID(ctx, "msg-1", "failed", "30007"); err != nil {
+		t.Fatalf("update delivery: %v", err)
+	}
+
+	events, err := convstream.NewHub(rdb, nil).Replay(ctx, "sms:org-1:15005550002", "0-0")
+	if err != nil {
+		t.Fatalf("replay: %v", err)
+	}
+	if len(events) != 2 || events[0].Type != convstream.TypeStatus || events[1].Type != convstream.TypeDelivery {
+		t.Fatalf("unexpected events: %+v", events)
+	}
+	var delivery convstream.DeliveryUpdate
+	if err := json.Unmarshal(events[1].Data, &delivery); err != nil || delivery.ProviderMessageID != "msg-1" || delivery.ErrorReason != "30007" {
+		t.Fatalf("unexpected delivery payload %+v (%v)", delivery, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
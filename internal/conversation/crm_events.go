@@ -0,0 +1,102 @@
+package conversation
+
+import (
+	"context"
+	"time"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/crmhooks"
+	"github.com/wolfman30/medspa-ai-platform/internal/events"
+	"github.com/wolfman30/medspa-ai-platform/internal/leads"
+)
+
+// CRMEventPublisher queues lead lifecycle events for the clinic's CRM
+// webhooks. Event IDs are derived from the underlying fact, so publishing
+// the same fact twice delivers it once.
+type CRMEventPublisher interface {
+	Publish(ctx context.Context, evt crmhooks.Event) error
+}
+
+// publishCRMEvent queues evt without failing the caller's flow.
+func (w *Worker) publishCRMEvent(ctx context.Context, evt crmhooks.Event) {
+	if w.crmEvents == nil {
+		return
+	}
+	if err := w.crmEvents.Publish(ctx, evt); err != nil {
+		w.log(ctx).Warn("failed to publish CRM event", "error", err, "org_id", evt.OrgID, "event", evt.Type, "lead_id", evt.Data.LeadID)
+	}
+}
+
+// publishLeadQualified announces that the lead answered every qualification
+// question. It runs on each availability fetch; the stable event ID keeps
+// repeats from reaching the CRM.
+func (s *LLMService) publishLeadQualified(ctx context.Context, prefs *leads.SchedulingPreferences, conversationID, orgID, leadID, leadPhone string) {
+	if s.crmEvents == nil || leadID == "" {
+		return
+	}
+	evt := crmhooks.NewEvent(crmhooks.EventLeadQualified, orgID, leadID, time.Now(), crmhooks.EventData{
+		LeadID:         leadID,
+		ConversationID: conversationID,
+		Service:        prefs.ServiceInterest,
+		PatientType:    prefs.PatientType,
+		Contact:        &crmhooks.Contact{Name: prefs.Name, Phone: leadPhone},
+	})
+	if err := s.crmEvents.Publish(ctx, evt); err != nil {
+		s.log(ctx).Warn("failed to publish CRM event", "error", err, "org_id", orgID, "event", evt.Type, "lead_id", leadID)
+	}
+}
+
+// leadCreatedCRMEvent describes a newly started conversation's lead.
+func leadCreatedCRMEvent(req StartRequest, lead *leads.Lead) crmhooks.Event {
+	key := req.LeadID
+	if key == "" {
+		key = req.ConversationID
+	}
+	occurredAt := time.Now()
+	if !lead.CreatedAt.IsZero() {
+		occurredAt = lead.CreatedAt
+	}
+	return crmhooks.NewEvent(crmhooks.EventLeadCreated, req.OrgID, key, occurredAt, crmhooks.EventData{
+		LeadID:         req.LeadID,
+		ConversationID: req.ConversationID,
+		Source:         lead.Source,
+		Service:        lead.ServiceInterest,
+		PatientType:    lead.PatientType,
+		Contact:        &crmhooks.Contact{Name: lead.Name, Phone: lead.Phone, Email: lead.Email},
+	})
+}
+
+// depositPaidCRMEvent describes a captured deposit.
+func depositPaidCRMEvent(evt events.PaymentSucceededV1) crmhooks.Event {
+	key := evt.ProviderRef
+	if key == "" {
+		key = evt.EventID
+	}
+	return crmhooks.NewEvent(crmhooks.EventDepositPaid, evt.OrgID, key, evt.OccurredAt, crmhooks.EventData{
+		LeadID:          evt.LeadID,
+		BookingIntentID: evt.BookingIntentID,
+		PaymentProvider: evt.Provider,
+		PaymentRef:      evt.ProviderRef,
+		AmountCents:     evt.AmountCents,
+		Service:         evt.ServiceName,
+		ScheduledFor:    evt.ScheduledFor,
+	})
+}
+
+// bookingConfirmedCRMEvent describes an appointment booked on the clinic's
+// platform. Without a platform appointment ID the lead and start time
+// identify the booking.
+func bookingConfirmedCRMEvent(orgID, leadID, appointmentID, service string, scheduledFor *time.Time) crmhooks.Event {
+	key := appointmentID
+	if key == "" {
+		key = leadID
+		if scheduledFor != nil {
+			key += "|" + scheduledFor.UTC().Format(time.RFC3339)
+		}
+	}
+	return crmhooks.NewEvent(crmhooks.EventBookingConfirmed, orgID, key, time.Now(), crmhooks.EventData{
+		LeadID:       leadID,
+		BookingID:    appointmentID,
+		Service:      service,
+		ScheduledFor: scheduledFor,
+	})
+}
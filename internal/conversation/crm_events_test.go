@@ -0,0 +1,110 @@
+package conversation
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/crmhooks"
+	"github.com/wolfman30/medspa-ai-platform/internal/events"
+	"github.com/wolfman30/medspa-ai-platform/internal/leads"
+	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
+)
+
+type recordingCRMEvents struct {
+	events []crmhooks.Event
+}
+
+func (r *recordingCRMEvents) Publish(_ context.Context, evt crmhooks.Event) error {
+	r.events = append(r.events, evt)
+	return nil
+}
+
+func TestWorker_PublishesLeadCreatedWithoutOperatorNotifier(t *testing.T) {
+	crm := &recordingCRMEvents{}
+	w := &Worker{crmEvents: crm, logger: logging.Default()}
+
+	w.notifyLeadCreated(context.Background(), StartRequest{
+		OrgID: "org-1", LeadID: "lead-1", ConversationID: "sms:org-1:15550001111",
+		From: "+15550001111", Source: "missed_call", Intro: "I'd like botox",
+	})
+
+	if len(crm.events) != 1 {
+		t.Fatalf("published %d events, want 1", len(crm.events))
+	}
+	evt := crm.events[0]
+	if evt.Type != crmhooks.EventLeadCreated || evt.Data.LeadID != "lead-1" || evt.Data.Source != "missed_call" {
+		t.Errorf("event = %+v", evt)
+	}
+	if evt.Data.Contact == nil || evt.Data.Contact.Phone != "+15550001111" {
+		t.Errorf("contact = %+v", evt.Data.Contact)
+	}
+}
+
+func TestWorker_NotifiesLeadCreatedOncePerLead(t *testing.T) {
+	crm := &recordingCRMEvents{}
+	w := &Worker{crmEvents: crm, processed: &stubProcessedStore{seen: map[string]bool{}}, logger: logging.Default()}
+	start := StartRequest{OrgID: "org-1", LeadID: "lead-1", From: "+15550001111", Source: "voice"}
+
+	// A returning patient starts a new conversation from another channel.
+	w.notifyLeadCreated(context.Background(), start)
+	start.Source = "webchat"
+	w.notifyLeadCreated(context.Background(), start)
+	w.notifyLeadCreated(context.Background(), StartRequest{OrgID: "org-1", LeadID: "lead-2", From: "+15550002222"})
+
+	if len(crm.events) != 2 {
+		t.Fatalf("published %d lead.created events, want one per lead", len(crm.events))
+	}
+	if crm.events[0].Data.LeadID != "lead-1" || crm.events[1].Data.LeadID != "lead-2" {
+		t.Errorf("events = %+v", crm.events)
+	}
+}
+
+func TestWorker_PublishesBookingConfirmed(t *testing.T) {
+	crm := &recordingCRMEvents{}
+	w := &Worker{crmEvents: crm, logger: logging.Default()}
+
+	w.notifyBookingConfirmed(context.Background(), "org-1", "lead-1", "+15550001111", "Botox", "2026-03-05T15:00:00-05:00", "appt-9")
+	w.notifyBookingConfirmed(context.Background(), "org-1", "lead-1", "+15550001111", "Botox", "2026-03-05T15:00:00-05:00", "appt-9")
+
+	if len(crm.events) != 2 || crm.events[0].ID != crm.events[1].ID {
+		t.Fatalf("repeat confirmations should share an event ID: %+v", crm.events)
+	}
+	evt := crm.events[0]
+	want := time.Date(2026, 3, 5, 20, 0, 0, 0, time.UTC)
+	if evt.Type != crmhooks.EventBookingConfirmed || evt.Data.BookingID != "appt-9" || evt.Data.ScheduledFor == nil || !evt.Data.ScheduledFor.Equal(want) {
+		t.Errorf("event = %+v", evt)
+	}
+	if evt.Data.ScheduledFor.Location() != time.UTC {
+		t.Errorf("scheduled_for not normalized to UTC: %v", evt.Data.ScheduledFor)
+	}
+}
+
+func TestDepositPaidCRMEvent_OmitsPatientDetails(t *testing.T) {
+	evt := depositPaidCRMEvent(events.PaymentSucceededV1{
+		EventID: "evt-1", OrgID: "org-1", LeadID: "lead-1", Provider: "square", ProviderRef: "pay_123",
+		AmountCents: 5000, OccurredAt: time.Date(2026, 3, 2, 15, 0, 0, 0, time.UTC),
+		LeadPhone: "+15550001111", LeadName: "Jane Smith",
+	})
+	if evt.Type != crmhooks.EventDepositPaid || evt.Data.PaymentRef != "pay_123" || evt.Data.AmountCents != 5000 {
+		t.Errorf("event = %+v", evt)
+	}
+	if evt.Data.Contact != nil {
+		t.Errorf("deposit.paid should reference the lead by ID only, got contact %+v", evt.Data.Contact)
+	}
+}
+
+func TestPublishLeadQualified_SkipsWithoutLead(t *testing.T) {
+	crm := &recordingCRMEvents{}
+	s := &LLMService{crmEvents: crm, logger: logging.Default()}
+	prefs := &leads.SchedulingPreferences{Name: "Jane Smith", ServiceInterest: "Botox", PatientType: "new"}
+
+	s.publishLeadQualified(context.Background(), prefs, "conv-1", "org-1", "", "+15550001111")
+	if len(crm.events) != 0 {
+		t.Fatalf("staff-assisted lookups have no lead and should not publish: %+v", crm.events)
+	}
+	s.publishLeadQualified(context.Background(), prefs, "conv-1", "org-1", "lead-1", "+15550001111")
+	if len(crm.events) != 1 || crm.events[0].Type != crmhooks.EventLeadQualified || crm.events[0].Data.PatientType != "new" {
+		t.Fatalf("events = %+v", crm.events)
+	}
+}
@@ -0,0 +1,463 @@
+package conversation
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// monthsByName maps month names and abbreviations to time.Month.
+var monthsByName = map[string]time.Month{
+	"jan": time.January, "january": time.January,
+	"feb": time.February, "february": time.February,
+	"mar": time.March, "march": time.March,
+	"apr": time.April, "april": time.April,
+	"may": time.May,
+	"jun": time.June, "june": time.June,
+	"jul": time.July, "july": time.July,
+	"aug": time.August, "august": time.August,
+	"sep": time.September, "september": time.September,
+	"oct": time.October, "october": time.October,
+	"nov": time.November, "november": time.November,
+	"dec": time.December, "december": time.December,
+}
+
+// monthPattern matches any key of monthsByName as a capture group.
+const monthPattern = `(jan(?:uary)?|feb(?:ruary)?|mar(?:ch)?|apr(?:il)?|may|jun(?:e)?|jul(?:y)?|aug(?:ust)?|sep(?:tember)?|oct(?:ober)?|nov(?:ember)?|dec(?:ember)?)`
+
+// dayPattern matches a day of month with an optional ordinal suffix.
+const dayPattern = `(\d{1,2})(?:st|nd|rd|th)?\b`
+
+// monthDayRE matches "Mar 2", "march 10th", etc.
+var monthDayRE = regexp.MustCompile(`(?i)` + monthPattern + `\s+(\d{1,2})`)
+
+// nextOccurrence returns month/day in today's year, or next year if that date
+// has already passed. ok is false for dates like Feb 30.
+func nextOccurrence(mon time.Month, day int, today time.Time) (time.Time, bool) {
+	d, ok := calendarDate(today.Year(), mon, day, today.Location())
+	if !ok {
+		return time.Time{}, false
+	}
+	if d.Before(today) {
+		d, ok = calendarDate(today.Year()+1, mon, day, today.Location())
+	}
+	return d, ok
+}
+
+// calendarDate returns midnight on the given date, rejecting day overflow.
+func calendarDate(year int, mon time.Month, day int, loc *time.Location) (time.Time, bool) {
+	if day < 1 || day > 31 {
+		return time.Time{}, false
+	}
+	d := time.Date(year, mon, day, 0, 0, 0, 0, loc)
+	return d, d.Day() == day
+}
+
+// lastDayOfMonth returns the final day number of the month.
+func lastDayOfMonth(year int, mon time.Month, loc *time.Location) int {
+	return time.Date(year, mon+1, 0, 0, 0, 0, 0, loc).Day()
+}
+
+// dateRange is an inclusive calendar-day range parsed from a patient message.
+// Either bound may be nil for open-ended phrases like "after the 20th".
+type dateRange struct {
+	From   *time.Time
+	To     *time.Time
+	Phrase string // the matched text, e.g. "week of march 10"
+	start  int
+	end    int
+}
+
+// dateRangeRule recognizes one family of date-range phrases. build receives
+// the regex submatches and a midnight "today"; ok is false when the phrase
+// names an impossible date.
+type dateRangeRule struct {
+	re    *regexp.Regexp
+	build func(m []string, today time.Time) (from, to *time.Time, ok bool)
+}
+
+// dateRangeRules are tried in order; more specific phrases come first.
+var dateRangeRules = []dateRangeRule{
+	{
+		// "between march 10 and march 14", "between march 10 and 14th"
+		re:    regexp.MustCompile(`\bbetween\s+` + monthPattern + `\s+` + dayPattern + `\s+and\s+(?:` + monthPattern + `\s+)?` + dayPattern),
+		build: buildExplicitSpan,
+	},
+	{
+		// "march 10-14", "march 10 through march 14", "dec 28 to jan 3"
+		re:    regexp.MustCompile(`\b(?:from\s+)?` + monthPattern + `\s+` + dayPattern + `\s*(?:-|–|—|\bto\b|\bthrough\b|\bthru\b|\buntil\b)\s*(?:` + monthPattern + `\s+)?` + dayPattern),
+		build: buildExplicitSpan,
+	},
+	{
+		// "week of march 10", "the week of march 10th"
+		re:    regexp.MustCompile(`\bweek\s+of\s+(?:the\s+)?` + monthPattern + `\s+` + dayPattern),
+		build: buildWeekOf,
+	},
+	{
+		// "first week of april", "last week in may"
+		re:    regexp.MustCompile(`\b(first|1st|second|2nd|third|3rd|fourth|4th|last)\s+week\s+(?:of|in)\s+` + monthPattern + `\b`),
+		build: buildNthWeek,
+	},
+	{
+		// "early april", "mid-march", "end of june"
+		re:    regexp.MustCompile(`\b(early|mid|late|beginning\s+of|start\s+of|middle\s+of|end\s+of)[\s-]+(?:the\s+month\s+of\s+)?` + monthPattern + `\b`),
+		build: buildPartOfMonth,
+	},
+	{
+		// "after march 20", "before april 3rd"
+		re:    regexp.MustCompile(`\b(after|before)\s+(?:the\s+)?` + monthPattern + `\s+` + dayPattern),
+		build: buildRelativeToDate,
+	},
+	{
+		// "after the 20th", "before the 3rd" (current or next month)
+		re:    regexp.MustCompile(`\b(after|before)\s+the\s+(\d{1,2})(?:st|nd|rd|th)\b`),
+		build: buildRelativeToDay,
+	},
+	{
+		// "in april", "sometime during may"
+		re:    regexp.MustCompile(`\b(?:in|during)\s+(?:the\s+month\s+of\s+)?` + monthPattern + `\b`),
+		build: buildWholeMonth,
+	},
+	{
+		// "day after tomorrow"; before "tomorrow" so it isn't read as tomorrow
+		re:    regexp.MustCompile(`\b(?:the\s+)?day\s+after\s+tomorrow\b`),
+		build: buildDaysFromToday(2),
+	},
+	{
+		// "tomorrow afternoon", "tmrw"
+		re:    regexp.MustCompile(`\b(?:tomorrow|tmrw|tmr)\b`),
+		build: buildDaysFromToday(1),
+	},
+	{
+		// "today", "later today"
+		re:    regexp.MustCompile(`\btoday\b`),
+		build: buildDaysFromToday(0),
+	},
+	{
+		// "this weekend", "next weekend"
+		re:    regexp.MustCompile(`\b(this|next)\s+weekend\b`),
+		build: buildWeekend,
+	},
+	{
+		// "next week", "this week", "later this week"
+		re:    regexp.MustCompile(`\b(this|next)\s+week\b`),
+		build: buildRelativeWeek,
+	},
+	{
+		// "next friday", "this tuesday"
+		re:    regexp.MustCompile(`\b(this|next)\s+` + weekdayPattern),
+		build: buildRelativeWeekday,
+	},
+	{
+		// "in 3 days", "in two weeks", "in a week"
+		re:    regexp.MustCompile(`\bin\s+(a|an|\d{1,2}|one|two|three|four|five|six|seven|eight|nine|ten|eleven|twelve)\s+(day|week)s?\b`),
+		build: buildInPeriod,
+	},
+}
+
+// weekdayPattern matches a weekday name or abbreviation as a capture group.
+const weekdayPattern = `(sun(?:day)?|mon(?:day)?|tue(?:s(?:day)?)?|wed(?:nesday)?|thu(?:rs(?:day)?)?|fri(?:day)?|sat(?:urday)?)\b`
+
+// weekdaysByPrefix maps the first three letters of a weekday name to time.Weekday.
+var weekdaysByPrefix = map[string]time.Weekday{
+	"sun": time.Sunday, "mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday,
+	"thu": time.Thursday, "fri": time.Friday, "sat": time.Saturday,
+}
+
+// periodCounts maps the counts accepted by "in N days/weeks" to numbers.
+var periodCounts = map[string]int{
+	"a": 1, "an": 1, "one": 1, "two": 2, "three": 3, "four": 4, "five": 5, "six": 6,
+	"seven": 7, "eight": 8, "nine": 9, "ten": 10, "eleven": 11, "twelve": 12,
+}
+
+// extractDateRange finds the first date-range phrase in lowercase text.
+// Ranges that have already ended roll forward to next year, matching
+// extractSpecificDates. Relative phrases ("tomorrow", "next week") resolve
+// against now's calendar date, so now should be in the clinic's timezone.
+// Vague phrases like "sometime soon" or "in a few weeks" set no range.
+func extractDateRange(text string, now time.Time) (dateRange, bool) {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	for _, rule := range dateRangeRules {
+		loc := rule.re.FindStringSubmatchIndex(text)
+		if loc == nil {
+			continue
+		}
+		m := make([]string, len(loc)/2)
+		for i := range m {
+			if loc[2*i] >= 0 {
+				m[i] = text[loc[2*i]:loc[2*i+1]]
+			}
+		}
+		from, to, ok := rule.build(m, today)
+		if !ok {
+			continue
+		}
+		return dateRange{From: from, To: to, Phrase: strings.TrimSpace(m[0]), start: loc[0], end: loc[1]}, true
+	}
+	return dateRange{}, false
+}
+
+// stripDateRange removes the matched phrase so words like "early" and "late"
+// are not also read as time-of-day preferences.
+func stripDateRange(text string, r dateRange) string {
+	return text[:r.start] + " " + text[r.end:]
+}
+
+// rollRange builds the range for today's year and moves it to next year when
+// it ends before today. Open-ended ranges roll on their only bound.
+func rollRange(today time.Time, build func(year int) (from, to *time.Time, ok bool)) (*time.Time, *time.Time, bool) {
+	from, to, ok := build(today.Year())
+	if !ok {
+		return nil, nil, false
+	}
+	end := to
+	if end == nil {
+		end = from
+	}
+	if end.Before(today) {
+		return build(today.Year() + 1)
+	}
+	return from, to, true
+}
+
+func buildExplicitSpan(m []string, today time.Time) (*time.Time, *time.Time, bool) {
+	fromMonth := monthsByName[m[1]]
+	fromDay, _ := strconv.Atoi(m[2])
+	toMonth := fromMonth
+	if m[3] != "" {
+		toMonth = monthsByName[m[3]]
+	}
+	toDay, _ := strconv.Atoi(m[4])
+	return rollRange(today, func(year int) (*time.Time, *time.Time, bool) {
+		from, ok := calendarDate(year, fromMonth, fromDay, today.Location())
+		if !ok {
+			return nil, nil, false
+		}
+		to, ok := calendarDate(year, toMonth, toDay, today.Location())
+		if !ok {
+			return nil, nil, false
+		}
+		if to.Before(from) {
+			// "dec 28 to jan 3" crosses the year boundary.
+			if to, ok = calendarDate(year+1, toMonth, toDay, today.Location()); !ok {
+				return nil, nil, false
+			}
+		}
+		return &from, &to, true
+	})
+}
+
+// buildWeekOf returns the Monday-Sunday week containing the named date. A
+// Sunday anchor starts the week on that Sunday, since that is how patients
+// usually mean it.
+func buildWeekOf(m []string, today time.Time) (*time.Time, *time.Time, bool) {
+	mon := monthsByName[m[1]]
+	day, _ := strconv.Atoi(m[2])
+	return rollRange(today, func(year int) (*time.Time, *time.Time, bool) {
+		anchor, ok := calendarDate(year, mon, day, today.Location())
+		if !ok {
+			return nil, nil, false
+		}
+		from := anchor
+		if anchor.Weekday() != time.Sunday {
+			from = anchor.AddDate(0, 0, -(int(anchor.Weekday()) - int(time.Monday)))
+		}
+		to := from.AddDate(0, 0, 6)
+		return &from, &to, true
+	})
+}
+
+// buildNthWeek treats "first week" as days 1-7, "second" as 8-14, and so on;
+// "last week" is the final seven days of the month.
+func buildNthWeek(m []string, today time.Time) (*time.Time, *time.Time, bool) {
+	mon := monthsByName[m[2]]
+	return rollRange(today, func(year int) (*time.Time, *time.Time, bool) {
+		var startDay int
+		switch m[1] {
+		case "first", "1st":
+			startDay = 1
+		case "second", "2nd":
+			startDay = 8
+		case "third", "3rd":
+			startDay = 15
+		case "fourth", "4th":
+			startDay = 22
+		case "last":
+			startDay = lastDayOfMonth(year, mon, today.Location()) - 6
+		}
+		from := time.Date(year, mon, startDay, 0, 0, 0, 0, today.Location())
+		to := from.AddDate(0, 0, 6)
+		return &from, &to, true
+	})
+}
+
+// buildPartOfMonth splits a month into early (1-10), mid (11-20), and late
+// (21-end) thirds.
+func buildPartOfMonth(m []string, today time.Time) (*time.Time, *time.Time, bool) {
+	part := strings.Join(strings.Fields(m[1]), " ")
+	mon := monthsByName[m[2]]
+	return rollRange(today, func(year int) (*time.Time, *time.Time, bool) {
+		var fromDay, toDay int
+		switch part {
+		case "early", "beginning of", "start of":
+			fromDay, toDay = 1, 10
+		case "mid", "middle of":
+			fromDay, toDay = 11, 20
+		default:
+			fromDay, toDay = 21, lastDayOfMonth(year, mon, today.Location())
+		}
+		from := time.Date(year, mon, fromDay, 0, 0, 0, 0, today.Location())
+		to := time.Date(year, mon, toDay, 0, 0, 0, 0, today.Location())
+		return &from, &to, true
+	})
+}
+
+// buildRelativeToDate handles "after march 20" (from the 21st) and
+// "before march 20" (through the 19th).
+func buildRelativeToDate(m []string, today time.Time) (*time.Time, *time.Time, bool) {
+	mon := monthsByName[m[2]]
+	day, _ := strconv.Atoi(m[3])
+	return rollRange(today, func(year int) (*time.Time, *time.Time, bool) {
+		anchor, ok := calendarDate(year, mon, day, today.Location())
+		if !ok {
+			return nil, nil, false
+		}
+		return relativeBounds(m[1], anchor)
+	})
+}
+
+// buildRelativeToDay handles "after the 20th" and "before the 3rd". The day
+// refers to the current month unless it has already passed, in which case
+// it refers to next month.
+func buildRelativeToDay(m []string, today time.Time) (*time.Time, *time.Time, bool) {
+	day, _ := strconv.Atoi(m[2])
+	year, mon := today.Year(), today.Month()
+	anchor, ok := calendarDate(year, mon, day, today.Location())
+	if !ok || anchor.Before(today) {
+		next := time.Date(year, mon+1, 1, 0, 0, 0, 0, today.Location())
+		if anchor, ok = calendarDate(next.Year(), next.Month(), day, today.Location()); !ok {
+			return nil, nil, false
+		}
+	}
+	return relativeBounds(m[1], anchor)
+}
+
+func relativeBounds(direction string, anchor time.Time) (*time.Time, *time.Time, bool) {
+	if direction == "after" {
+		from := anchor.AddDate(0, 0, 1)
+		return &from, nil, true
+	}
+	to := anchor.AddDate(0, 0, -1)
+	return nil, &to, true
+}
+
+func buildWholeMonth(m []string, today time.Time) (*time.Time, *time.Time, bool) {
+	mon := monthsByName[m[1]]
+	return rollRange(today, func(year int) (*time.Time, *time.Time, bool) {
+		from := time.Date(year, mon, 1, 0, 0, 0, 0, today.Location())
+		to := time.Date(year, mon, lastDayOfMonth(year, mon, today.Location()), 0, 0, 0, 0, today.Location())
+		return &from, &to, true
+	})
+}
+
+// buildDaysFromToday returns a builder for the single day n days after today.
+func buildDaysFromToday(n int) func(m []string, today time.Time) (*time.Time, *time.Time, bool) {
+	return func(_ []string, today time.Time) (*time.Time, *time.Time, bool) {
+		day := today.AddDate(0, 0, n)
+		return singleDay(day)
+	}
+}
+
+// buildWeekend returns the coming Saturday-Sunday ("this weekend"), or the
+// one after it ("next weekend"). On a Sunday, this weekend is just today.
+func buildWeekend(m []string, today time.Time) (*time.Time, *time.Time, bool) {
+	from := today.AddDate(0, 0, (int(time.Saturday)-int(today.Weekday())+7)%7)
+	to := from.AddDate(0, 0, 1)
+	if today.Weekday() == time.Sunday {
+		from, to = today, today
+	}
+	if m[1] == "next" {
+		from = to.AddDate(0, 0, 6)
+		to = from.AddDate(0, 0, 1)
+	}
+	return &from, &to, true
+}
+
+// buildRelativeWeek returns the rest of the current Monday-Sunday week
+// ("this week") or all of the following one ("next week").
+func buildRelativeWeek(m []string, today time.Time) (*time.Time, *time.Time, bool) {
+	monday := startOfWeek(today)
+	if m[1] == "next" {
+		from := monday.AddDate(0, 0, 7)
+		to := from.AddDate(0, 0, 6)
+		return &from, &to, true
+	}
+	from := today
+	to := monday.AddDate(0, 0, 6)
+	return &from, &to, true
+}
+
+// buildRelativeWeekday resolves "this friday" to the coming Friday (today
+// included) and "next friday" to the first Friday after today.
+func buildRelativeWeekday(m []string, today time.Time) (*time.Time, *time.Time, bool) {
+	want, ok := weekdaysByPrefix[m[2][:3]]
+	if !ok {
+		return nil, nil, false
+	}
+	ahead := (int(want) - int(today.Weekday()) + 7) % 7
+	if ahead == 0 && m[1] == "next" {
+		ahead = 7
+	}
+	return singleDay(today.AddDate(0, 0, ahead))
+}
+
+// buildInPeriod resolves "in 3 days" to that day and "in 2 weeks" to the
+// Monday-Sunday week containing the day two weeks out.
+func buildInPeriod(m []string, today time.Time) (*time.Time, *time.Time, bool) {
+	n, ok := periodCounts[m[1]]
+	if !ok {
+		n, _ = strconv.Atoi(m[1])
+	}
+	if n < 1 {
+		return nil, nil, false
+	}
+	if m[2] == "day" {
+		return singleDay(today.AddDate(0, 0, n))
+	}
+	from := startOfWeek(today.AddDate(0, 0, 7*n))
+	to := from.AddDate(0, 0, 6)
+	return &from, &to, true
+}
+
+// startOfWeek returns the Monday on or before day.
+func startOfWeek(day time.Time) time.Time {
+	return day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+}
+
+func singleDay(day time.Time) (*time.Time, *time.Time, bool) {
+	from, to := day, day
+	return &from, &to, true
+}
+
+// rangeIncludesWeekday reports whether any day in [from, to] falls on one of
+// the given weekdays. Open-ended ranges always do.
+func rangeIncludesWeekday(from, to *time.Time, days []int) bool {
+	if from == nil || to == nil || to.Sub(*from) >= 6*24*time.Hour {
+		return true
+	}
+	for d := *from; !d.After(*to); d = d.AddDate(0, 0, 1) {
+		for _, want := range days {
+			if int(d.Weekday()) == want {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// dateKey returns a sortable YYYYMMDD key for t's calendar date in its own
+// location, so slot times and range bounds compare by day.
+func dateKey(t time.Time) int {
+	return t.Year()*10000 + int(t.Month())*100 + t.Day()
+}
@@ -0,0 +1,358 @@
+package conversation
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/clinic"
+	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
+)
+
+func ymd(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format("2006-01-02")
+}
+
+func TestExtractDateRange(t *testing.T) {
+	// Tuesday, Feb 10 2026
+	now := time.Date(2026, time.February, 10, 15, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		input    string
+		wantFrom string
+		wantTo   string
+	}{
+		{"week of date (Tuesday anchor)", "the week of march 10", "2026-03-09", "2026-03-15"},
+		{"week of date with ordinal", "week of march 9th", "2026-03-09", "2026-03-15"},
+		{"week of Sunday anchor", "week of march 8", "2026-03-08", "2026-03-14"},
+		{"first week of month", "first week of april", "2026-04-01", "2026-04-07"},
+		{"last week of month", "last week of february", "2026-02-22", "2026-02-28"},
+		{"early month", "sometime in early april", "2026-04-01", "2026-04-10"},
+		{"mid month hyphenated", "mid-march works", "2026-03-11", "2026-03-20"},
+		{"end of month", "end of may", "2026-05-21", "2026-05-31"},
+		{"after the Nth this month", "after the 20th", "2026-02-21", ""},
+		{"after the Nth already passed", "after the 5th", "2026-03-06", ""},
+		{"before a date", "before march 3rd", "", "2026-03-02"},
+		{"explicit span", "march 10-14", "2026-03-10", "2026-03-14"},
+		{"between span", "between march 10 and 14th", "2026-03-10", "2026-03-14"},
+		{"span across year end", "dec 28 to jan 3", "2026-12-28", "2027-01-03"},
+		{"whole month", "anytime in june", "2026-06-01", "2026-06-30"},
+		{"past range rolls to next year", "week of january 5", "2027-01-04", "2027-01-10"},
+		{"past first week rolls", "first week of february", "2027-02-01", "2027-02-07"},
+		{"range containing today stays", "week of feb 9", "2026-02-09", "2026-02-15"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, ok := extractDateRange(tt.input, now)
+			if !ok {
+				t.Fatalf("extractDateRange(%q) found no range", tt.input)
+			}
+			if got := ymd(r.From); got != tt.wantFrom {
+				t.Errorf("From = %q, want %q", got, tt.wantFrom)
+			}
+			if got := ymd(r.To); got != tt.wantTo {
+				t.Errorf("To = %q, want %q", got, tt.wantTo)
+			}
+		})
+	}
+}
+
+func TestExtractDateRange_Relative(t *testing.T) {
+	// Tuesday, Feb 10 2026
+	tuesday := time.Date(2026, time.February, 10, 15, 0, 0, 0, time.UTC)
+	// Sunday, Feb 15 2026
+	sunday := time.Date(2026, time.February, 15, 9, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		input    string
+		now      time.Time
+		wantFrom string
+		wantTo   string
+	}{
+		{"can i come in tomorrow afternoon?", tuesday, "2026-02-11", "2026-02-11"},
+		{"tmrw works", tuesday, "2026-02-11", "2026-02-11"},
+		{"today if possible", tuesday, "2026-02-10", "2026-02-10"},
+		{"later today after 5pm", tuesday, "2026-02-10", "2026-02-10"},
+		{"day after tomorrow", tuesday, "2026-02-12", "2026-02-12"},
+		{"the day after tomorrow in the morning", tuesday, "2026-02-12", "2026-02-12"},
+		{"this weekend", tuesday, "2026-02-14", "2026-02-15"},
+		{"next weekend", tuesday, "2026-02-21", "2026-02-22"},
+		{"this weekend", sunday, "2026-02-15", "2026-02-15"},
+		{"next weekend", sunday, "2026-02-21", "2026-02-22"},
+		{"next week", tuesday, "2026-02-16", "2026-02-22"},
+		{"sometime next week, mornings", tuesday, "2026-02-16", "2026-02-22"},
+		{"next week", sunday, "2026-02-16", "2026-02-22"},
+		{"later this week", tuesday, "2026-02-10", "2026-02-15"},
+		{"this week", sunday, "2026-02-15", "2026-02-15"},
+		{"next friday", tuesday, "2026-02-13", "2026-02-13"},
+		{"next tuesday", tuesday, "2026-02-17", "2026-02-17"},
+		{"next mon", tuesday, "2026-02-16", "2026-02-16"},
+		{"this thursday", tuesday, "2026-02-12", "2026-02-12"},
+		{"this tuesday", tuesday, "2026-02-10", "2026-02-10"},
+		{"in 3 days", tuesday, "2026-02-13", "2026-02-13"},
+		{"in 10 days", tuesday, "2026-02-20", "2026-02-20"},
+		{"in a week", tuesday, "2026-02-16", "2026-02-22"},
+		{"in 2 weeks", tuesday, "2026-02-23", "2026-03-01"},
+		{"in two weeks", tuesday, "2026-02-23", "2026-03-01"},
+		{"tomorrow", time.Date(2026, time.December, 31, 20, 0, 0, 0, time.UTC), "2027-01-01", "2027-01-01"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.now.Weekday().String()+"/"+tt.input, func(t *testing.T) {
+			r, ok := extractDateRange(tt.input, tt.now)
+			if !ok {
+				t.Fatalf("extractDateRange(%q) found no range", tt.input)
+			}
+			if got := ymd(r.From); got != tt.wantFrom {
+				t.Errorf("From = %q, want %q", got, tt.wantFrom)
+			}
+			if got := ymd(r.To); got != tt.wantTo {
+				t.Errorf("To = %q, want %q", got, tt.wantTo)
+			}
+		})
+	}
+}
+
+func TestExtractDateRange_RelativeUsesClinicDate(t *testing.T) {
+	// 11pm Tuesday in Los Angeles is already Wednesday in UTC.
+	loc, err := time.LoadLocation("America/Los_Angeles")
+	if err != nil {
+		t.Skip("tzdata unavailable")
+	}
+	now := time.Date(2026, time.February, 10, 23, 0, 0, 0, loc)
+	r, ok := extractDateRange("tomorrow", now)
+	if !ok || ymd(r.From) != "2026-02-11" {
+		t.Fatalf("clinic-local tomorrow = %q (ok=%v), want 2026-02-11", ymd(r.From), ok)
+	}
+	r, _ = extractDateRange("tomorrow", now.UTC())
+	if ymd(r.From) != "2026-02-12" {
+		t.Fatalf("UTC tomorrow = %q, want 2026-02-12", ymd(r.From))
+	}
+}
+
+func TestExtractDateRange_NoMatch(t *testing.T) {
+	now := time.Date(2026, time.February, 10, 15, 0, 0, 0, time.UTC)
+	for _, input := range []string{
+		"mondays after 4pm",
+		"any later times on mar 2 and 4th?",
+		"february 30 to march 2",
+		"i may be free in the morning",
+		"sometime soon",
+		"in a few weeks",
+		"in a few days",
+		"a couple weeks from now",
+		"weekends are best",
+	} {
+		if r, ok := extractDateRange(input, now); ok {
+			t.Errorf("extractDateRange(%q) = %q, want no match", input, r.Phrase)
+		}
+	}
+}
+
+func TestExtractTimePreferencesAt_DateRange(t *testing.T) {
+	now := time.Date(2026, time.February, 10, 15, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		input      string
+		wantFrom   string
+		wantTo     string
+		wantDays   []int
+		wantAfter  string
+		wantBefore string
+	}{
+		{
+			name:      "week of date with time",
+			input:     "Week of March 10 after 4pm",
+			wantFrom:  "2026-03-09",
+			wantTo:    "2026-03-15",
+			wantAfter: "16:00",
+		},
+		{
+			name:     "range and weekdays combine",
+			input:    "Tuesdays or Thursdays, first week of April",
+			wantFrom: "2026-04-01",
+			wantTo:   "2026-04-07",
+			wantDays: []int{2, 4},
+		},
+		{
+			name:     "weekday outside short range is dropped",
+			input:    "Fridays, march 10-11",
+			wantFrom: "2026-03-10",
+			wantTo:   "2026-03-11",
+		},
+		{
+			name:     "weekday inside short range is kept",
+			input:    "Wednesday, march 10-11",
+			wantFrom: "2026-03-10",
+			wantTo:   "2026-03-11",
+			wantDays: []int{3},
+		},
+		{
+			name:     "early/late in a date phrase are not times",
+			input:    "sometime in early april",
+			wantFrom: "2026-04-01",
+			wantTo:   "2026-04-10",
+		},
+		{
+			name:      "tomorrow afternoon",
+			input:     "Can I come in tomorrow afternoon?",
+			wantFrom:  "2026-02-11",
+			wantTo:    "2026-02-11",
+			wantAfter: "12:00",
+		},
+		{
+			name:     "next week on given weekdays",
+			input:    "next week on tuesday or thursday",
+			wantFrom: "2026-02-16",
+			wantTo:   "2026-02-22",
+			wantDays: []int{2, 4},
+		},
+		{
+			name:     "weekday that tomorrow isn't is dropped",
+			input:    "tomorrow, mondays",
+			wantFrom: "2026-02-11",
+			wantTo:   "2026-02-11",
+		},
+		{
+			name:      "next weekday with time",
+			input:     "next friday after five",
+			wantFrom:  "2026-02-13",
+			wantTo:    "2026-02-13",
+			wantAfter: "17:00",
+		},
+		{
+			name:       "ambiguous phrase sets no range",
+			input:      "sometime soon, mornings",
+			wantBefore: "12:00",
+		},
+		{
+			name:       "after the 20th keeps time of day",
+			input:      "after the 20th, mornings",
+			wantFrom:   "2026-02-21",
+			wantBefore: "12:00",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := extractTimePreferencesAt(tt.input, now)
+			if ymd(got.DateFrom) != tt.wantFrom || ymd(got.DateTo) != tt.wantTo {
+				t.Errorf("range = %q..%q, want %q..%q", ymd(got.DateFrom), ymd(got.DateTo), tt.wantFrom, tt.wantTo)
+			}
+			if len(got.DaysOfWeek) != len(tt.wantDays) {
+				t.Fatalf("DaysOfWeek = %v, want %v", got.DaysOfWeek, tt.wantDays)
+			}
+			for i := range tt.wantDays {
+				if got.DaysOfWeek[i] != tt.wantDays[i] {
+					t.Fatalf("DaysOfWeek = %v, want %v", got.DaysOfWeek, tt.wantDays)
+				}
+			}
+			if got.AfterTime != tt.wantAfter {
+				t.Errorf("AfterTime = %q, want %q", got.AfterTime, tt.wantAfter)
+			}
+			if got.BeforeTime != tt.wantBefore {
+				t.Errorf("BeforeTime = %q, want %q", got.BeforeTime, tt.wantBefore)
+			}
+		})
+	}
+}
+
+func TestMatchesTimePreferences_DateRange(t *testing.T) {
+	loc, _ := time.LoadLocation("America/New_York")
+	from := time.Date(2026, time.March, 9, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2026, time.March, 15, 0, 0, 0, 0, time.UTC)
+	prefs := TimePreferences{DateFrom: &from, DateTo: &to}
+
+	tests := []struct {
+		name string
+		slot time.Time
+		want bool
+	}{
+		{"first day of range", time.Date(2026, time.March, 9, 10, 0, 0, 0, loc), true},
+		{"late evening on last day uses local date", time.Date(2026, time.March, 15, 22, 0, 0, 0, loc), true},
+		{"day after range", time.Date(2026, time.March, 16, 10, 0, 0, 0, loc), false},
+		{"day before range", time.Date(2026, time.March, 8, 23, 0, 0, 0, loc), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := matchesTimePreferences(tt.slot, prefs); got != tt.want {
+				t.Errorf("matchesTimePreferences(%v) = %v, want %v", tt.slot, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMoxieSearchWindow(t *testing.T) {
+	today := time.Date(2026, time.February, 10, 9, 0, 0, 0, time.UTC)
+	from := time.Date(2026, time.March, 9, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2026, time.March, 15, 0, 0, 0, 0, time.UTC)
+	past := time.Date(2026, time.February, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		prefs     TimePreferences
+		wantStart string
+		wantEnd   string
+	}{
+		{"no range", TimePreferences{}, "2026-02-10", "2026-05-10"},
+		{"bounded range", TimePreferences{DateFrom: &from, DateTo: &to}, "2026-03-09", "2026-03-15"},
+		{"open-ended from", TimePreferences{DateFrom: &from}, "2026-03-09", "2026-06-09"},
+		{"start clamps to today", TimePreferences{DateFrom: &past, DateTo: &to}, "2026-02-10", "2026-03-15"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start, end := moxieSearchWindow(today, tt.prefs)
+			if start != tt.wantStart || end != tt.wantEnd {
+				t.Errorf("window = %s..%s, want %s..%s", start, end, tt.wantStart, tt.wantEnd)
+			}
+		})
+	}
+}
+
+// prefsCapturingSource records the request the worker sends to the booking
+// platform and reports no openings.
+type prefsCapturingSource struct {
+	reqs []AvailabilityRequest
+}
+
+func (s *prefsCapturingSource) Name() string                 { return clinic.AvailabilitySourceMoxieAPI }
+func (s *prefsCapturingSource) Supports(*clinic.Config) bool { return true }
+
+func (s *prefsCapturingSource) FetchAvailability(ctx context.Context, req AvailabilityRequest) (*AvailabilityResult, error) {
+	s.reqs = append(s.reqs, req)
+	return &AvailabilityResult{Message: "none"}, nil
+}
+
+func TestFetchAndPresentAvailability_NarrowsToRelativeDate(t *testing.T) {
+	history := []ChatMessage{
+		{Role: ChatRoleUser, Content: "Hi, can I come in tomorrow afternoon for botox?"},
+	}
+	prefs, _ := extractPreferences(history, nil)
+	if prefs.PreferredDays != "tomorrow" || prefs.PreferredTimes != "afternoon" {
+		t.Fatalf("preferences = days %q times %q", prefs.PreferredDays, prefs.PreferredTimes)
+	}
+
+	cfg := &clinic.Config{OrgID: "org-1", Timezone: "America/Chicago"}
+	source := &prefsCapturingSource{}
+	svc := &LLMService{logger: logging.Default(), availability: NewAvailabilityRouter(logging.Default(), source)}
+	svc.fetchAndPresentAvailability(context.Background(), &prefs, cfg, "", "sms:org-1:15550001111", "org-1", "", "+15550001111", nil)
+
+	if len(source.reqs) != 1 {
+		t.Fatalf("expected 1 availability fetch, got %d", len(source.reqs))
+	}
+	today := clinicNow(cfg)
+	tomorrow := today.AddDate(0, 0, 1).Format("2006-01-02")
+	got := source.reqs[0].Prefs
+	if ymd(got.DateFrom) != tomorrow || ymd(got.DateTo) != tomorrow {
+		t.Fatalf("range = %q..%q, want %s", ymd(got.DateFrom), ymd(got.DateTo), tomorrow)
+	}
+	if start, end := moxieSearchWindow(today, got); start != tomorrow || end != tomorrow {
+		t.Fatalf("moxie window = %s..%s, want %s", start, end, tomorrow)
+	}
+}
@@ -24,6 +24,7 @@ var (
 type PreGeneratedCheckout struct {
 	URL          string
 	ProviderID   string
+	OrderID      string
 	PrePaymentID uuid.UUID
 	AmountCents  int32
 	GeneratedAt  time.Time
@@ -129,6 +130,7 @@ func (p *DepositPreloader) StartPreload(ctx context.Context, conversationID, org
 		} else {
 			result.URL = link.URL
 			result.ProviderID = link.ProviderID
+			result.OrderID = link.OrderID
 			p.logger.Info("deposit preloader: checkout link ready",
 				"conversation_id", conversationID,
 				"pre_payment_id", prePaymentID,
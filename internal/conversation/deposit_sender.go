@@ -8,16 +8,27 @@ import (
 
 	"github.com/google/uuid"
 
+	"github.com/wolfman30/medspa-ai-platform/internal/clinic"
 	"github.com/wolfman30/medspa-ai-platform/internal/events"
 	"github.com/wolfman30/medspa-ai-platform/internal/leads"
+	"github.com/wolfman30/medspa-ai-platform/internal/messaging/templates"
+	"github.com/wolfman30/medspa-ai-platform/internal/notify"
 	"github.com/wolfman30/medspa-ai-platform/internal/payments"
 	paymentsql "github.com/wolfman30/medspa-ai-platform/internal/payments/sqlc"
+	"github.com/wolfman30/medspa-ai-platform/internal/tenancy"
 	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
 )
 
-// shortURLSaver stores a checkout URL and returns a short code for redirect.
-type shortURLSaver interface {
-	SaveCheckoutURL(paymentID uuid.UUID, checkoutURL string) string
+// DepositStatusNotRequired marks a lead whose booking needs no deposit and is
+// waiting for the clinic to confirm it.
+const DepositStatusNotRequired = "not_required"
+
+// noDepositHandoffReason tells staff why a no-deposit booking was handed to them.
+const noDepositHandoffReason = "No deposit is required for this service. Confirm the requested time with the patient."
+
+// shortLinkCreator issues an org-scoped short code that redirects to a checkout URL.
+type shortLinkCreator interface {
+	CreateShortLink(ctx context.Context, orgID string, paymentID uuid.UUID, targetURL string) (string, error)
 }
 
 // depositDispatcher creates a payment intent, generates a checkout link, emits an event, and sends an SMS.
@@ -31,8 +42,16 @@ type depositDispatcher struct {
 	transcript *SMSTranscriptStore
 	convStore  conversationWriter
 	logger     *logging.Logger
-	apiBaseURL string // Public API base URL for short payment URLs
-	shortURLs  shortURLSaver
+	payBaseURL string // Base short payment links are built on, e.g. https://pay.example.com
+	shortLinks shortLinkCreator
+	clinics    depositPolicySource
+	processed  processedEventStore
+	requests   BookingHandoffNotifier
+}
+
+// depositPolicySource loads clinic config so per-service deposit policies apply.
+type depositPolicySource interface {
+	Get(ctx context.Context, orgID string) (*clinic.Config, error)
 }
 
 type outboxWriter interface {
@@ -47,8 +66,14 @@ type paymentLinkCreator interface {
 	CreatePaymentLink(ctx context.Context, params payments.CheckoutParams) (*payments.CheckoutResponse, error)
 }
 
+// checkoutOrderRecorder stores the provider order behind a deposit's link so
+// the deposit can be checked with the provider when the patient says they paid.
+type checkoutOrderRecorder interface {
+	SetCheckoutOrder(ctx context.Context, paymentID uuid.UUID, orderID string) error
+}
+
 type paymentIntentChecker interface {
-	HasOpenDeposit(ctx context.Context, orgID uuid.UUID, leadID uuid.UUID) (bool, error)
+	HasOpenDeposit(ctx context.Context, scope tenancy.OrgScope, leadID uuid.UUID) (bool, error)
 }
 
 type conversationWriter interface {
@@ -58,11 +83,31 @@ type conversationWriter interface {
 // DepositOption configures optional depositDispatcher fields.
 type DepositOption func(*depositDispatcher)
 
-// WithShortURLs enables short payment URL generation.
-func WithShortURLs(saver shortURLSaver, apiBaseURL string) DepositOption {
+// WithShortURLs sends deposit SMS with short links ({payBaseURL}/{code})
+// instead of raw provider checkout URLs. payBaseURL may be a branded pay
+// domain or the API's /pay path.
+func WithShortURLs(links shortLinkCreator, payBaseURL string) DepositOption {
 	return func(d *depositDispatcher) {
-		d.shortURLs = saver
-		d.apiBaseURL = apiBaseURL
+		d.shortLinks = links
+		d.payBaseURL = payBaseURL
+	}
+}
+
+// WithDepositPolicies applies each clinic's per-service deposit policy: exempt
+// services skip the payment link, and override amounts replace the intent amount.
+func WithDepositPolicies(clinics depositPolicySource) DepositOption {
+	return func(d *depositDispatcher) {
+		d.clinics = clinics
+	}
+}
+
+// WithBookingRequests hands bookings for services that need no deposit to
+// the clinic's operators through notifier. processed dedupes redeliveries of
+// the same job so the patient and operators hear about a request once.
+func WithBookingRequests(processed processedEventStore, notifier BookingHandoffNotifier) DepositOption {
+	return func(d *depositDispatcher) {
+		d.processed = processed
+		d.requests = notifier
 	}
 }
 
@@ -88,6 +133,11 @@ func NewDepositDispatcher(paymentsRepo paymentIntentCreator, checkout paymentLin
 	return d
 }
 
+// log returns the dispatcher logger tagged with the correlation fields on ctx.
+func (d *depositDispatcher) log(ctx context.Context) *logging.Logger {
+	return d.logger.WithContext(ctx)
+}
+
 // SendDeposit orchestrates the full deposit flow: validates inputs, checks for
 // duplicates, creates a payment intent, generates a checkout link, sends the
 // deposit SMS, and emits an outbox event.
@@ -101,6 +151,18 @@ func (d *depositDispatcher) SendDeposit(ctx context.Context, msg MessageRequest,
 			intent.ScheduledFor = scheduled
 		}
 	}
+	if cfg := d.clinicConfig(ctx, msg.OrgID); cfg != nil {
+		if strings.TrimSpace(intent.Service) != "" {
+			if !cfg.DepositRequiredForService(intent.Service) {
+				d.sendNoDepositConfirmation(ctx, msg, resp, intent, cfg)
+				return nil
+			}
+			if amount := cfg.DepositAmountForService(intent.Service); amount > 0 {
+				intent.AmountCents = int32(amount)
+			}
+		}
+		intent.Disclosure = cfg.PaymentDisclosureText(int(intent.AmountCents))
+	}
 	if d.payments == nil || d.checkout == nil {
 		return fmt.Errorf("SendDeposit: missing payments or checkout dependency")
 	}
@@ -110,7 +172,7 @@ func (d *depositDispatcher) SendDeposit(ctx context.Context, msg MessageRequest,
 		return err
 	}
 
-	isDuplicate, err := d.checkDuplicateDeposit(ctx, orgUUID, leadUUID, msg)
+	isDuplicate, err := d.checkDuplicateDeposit(ctx, leadUUID, msg)
 	if err != nil {
 		return err
 	}
@@ -130,6 +192,8 @@ func (d *depositDispatcher) SendDeposit(ctx context.Context, msg MessageRequest,
 		return err
 	}
 
+	d.recordCheckoutOrder(ctx, paymentID, link.OrderID)
+
 	if link.URL != "" {
 		d.sendDepositSMS(ctx, msg, resp, intent, paymentID, fromNumber, link.URL)
 	}
@@ -139,6 +203,153 @@ func (d *depositDispatcher) SendDeposit(ctx context.Context, msg MessageRequest,
 	return nil
 }
 
+// clinicConfig returns the clinic config used for deposit policies, or nil when
+// policies aren't configured or the lookup fails.
+func (d *depositDispatcher) clinicConfig(ctx context.Context, orgID string) *clinic.Config {
+	if d.clinics == nil || strings.TrimSpace(orgID) == "" {
+		return nil
+	}
+	cfg, err := d.clinics.Get(ctx, orgID)
+	if err != nil {
+		d.log(ctx).Warn("SendDeposit: failed to load clinic config for deposit policy", "error", err, "org_id", orgID)
+		return nil
+	}
+	return cfg
+}
+
+// sendNoDepositConfirmation records the booking as a request on the lead,
+// notifies the clinic's operators, and tells the patient it went to the clinic
+// for confirmation. No payment intent or link is created.
+func (d *depositDispatcher) sendNoDepositConfirmation(ctx context.Context, msg MessageRequest, resp *Response, intent *DepositIntent, cfg *clinic.Config) {
+	conversationID := strings.TrimSpace(resp.ConversationID)
+	if conversationID == "" {
+		conversationID = strings.TrimSpace(msg.ConversationID)
+	}
+	if !d.reserveBookingRequest(ctx, msg, conversationID, intent) {
+		return
+	}
+
+	rendered := buildNoDepositConfirmationBody(intent, cfg)
+	fromNumber := d.resolveFromNumber(msg)
+
+	d.log(ctx).Info("SendDeposit: service exempt from deposit; booking sent for clinic confirmation",
+		"org_id", msg.OrgID,
+		"lead_id", msg.LeadID,
+		"service", intent.Service,
+	)
+	d.recordBookingRequest(ctx, msg, intent)
+
+	if d.sms != nil {
+		sendCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		defer cancel()
+		reply := OutboundReply{
+			OrgID:          msg.OrgID,
+			LeadID:         msg.LeadID,
+			ConversationID: conversationID,
+			To:             msg.From,
+			From:           fromNumber,
+			Body:           rendered.Body,
+			Metadata:       rendered.Stamp(nil),
+		}
+		if err := d.sms.SendReply(sendCtx, reply); err != nil {
+			d.log(ctx).Error("SendDeposit: failed to send no-deposit confirmation", "error", err, "org_id", msg.OrgID, "lead_id", msg.LeadID)
+		}
+	} else {
+		d.log(ctx).Warn("SendDeposit: sms messenger nil; no-deposit confirmation not sent", "org_id", msg.OrgID, "lead_id", msg.LeadID)
+	}
+
+	d.appendTranscript(context.Background(), conversationID, SMSTranscriptMessage{
+		Role: "assistant",
+		From: fromNumber,
+		To:   msg.From,
+		Body: rendered.Body,
+		Kind: "booking_confirmation",
+		Metadata: rendered.Stamp(map[string]string{
+			"lead_id": msg.LeadID,
+			"service": intent.Service,
+		}),
+	})
+}
+
+// reserveBookingRequest claims the no-deposit booking request for the job on
+// ctx, falling back to the conversation, service and time when there is no
+// job. It returns false when the request was already handled; a failed check
+// lets the request through rather than lose it.
+func (d *depositDispatcher) reserveBookingRequest(ctx context.Context, msg MessageRequest, conversationID string, intent *DepositIntent) bool {
+	if d.processed == nil {
+		return true
+	}
+	key := logging.FieldsFromContext(ctx).JobID
+	if key == "" {
+		key = conversationID + ":" + strings.ToLower(strings.TrimSpace(intent.Service))
+		if intent.ScheduledFor != nil {
+			key += ":" + intent.ScheduledFor.UTC().Format(time.RFC3339)
+		}
+	}
+	first, err := d.processed.MarkProcessed(ctx, "conversation.no_deposit_request", key)
+	if err != nil {
+		d.log(ctx).Warn("SendDeposit: failed to reserve no-deposit booking request", "error", err, "org_id", msg.OrgID, "lead_id", msg.LeadID)
+		return true
+	}
+	if !first {
+		d.log(ctx).Info("SendDeposit: no-deposit booking request already sent; skipping", "org_id", msg.OrgID, "lead_id", msg.LeadID)
+	}
+	return first
+}
+
+// recordBookingRequest saves the requested slot on the lead, flags it for
+// the clinic, and notifies operators so someone confirms the appointment.
+func (d *depositDispatcher) recordBookingRequest(ctx context.Context, msg MessageRequest, intent *DepositIntent) {
+	if d.leads != nil && msg.LeadID != "" {
+		scope := tenancy.ForOrg(msg.OrgID)
+		if intent.ScheduledFor != nil {
+			if err := d.leads.UpdateSelectedAppointment(ctx, scope, msg.LeadID, leads.SelectedAppointment{DateTime: intent.ScheduledFor, Service: intent.Service}); err != nil {
+				d.log(ctx).Warn("SendDeposit: failed to record requested appointment", "error", err, "org_id", msg.OrgID, "lead_id", msg.LeadID)
+			}
+		}
+		if err := d.leads.UpdateDepositStatus(ctx, scope, msg.LeadID, DepositStatusNotRequired, "priority"); err != nil {
+			d.log(ctx).Warn("SendDeposit: failed to flag no-deposit booking request", "error", err, "org_id", msg.OrgID, "lead_id", msg.LeadID)
+		}
+	}
+	if d.requests == nil {
+		d.log(ctx).Warn("SendDeposit: no booking request notifier configured; operators not alerted", "org_id", msg.OrgID, "lead_id", msg.LeadID)
+		return
+	}
+	if err := d.requests.NotifyBookingHandoff(ctx, msg.OrgID, notify.BookingHandoff{
+		LeadID:       msg.LeadID,
+		Phone:        msg.From,
+		Service:      intent.Service,
+		ScheduledFor: intent.ScheduledFor,
+		Reason:       noDepositHandoffReason,
+	}); err != nil {
+		d.log(ctx).Error("SendDeposit: failed to notify operators of booking request", "error", err, "org_id", msg.OrgID, "lead_id", msg.LeadID)
+	}
+}
+
+// buildNoDepositConfirmationBody constructs the SMS sent when a service doesn't
+// require a deposit.
+func buildNoDepositConfirmationBody(intent *DepositIntent, cfg *clinic.Config) templates.Rendered {
+	name := "the clinic"
+	if cfg != nil && strings.TrimSpace(cfg.Name) != "" {
+		name = cfg.Name
+	}
+	var policies strings.Builder
+	if len(intent.BookingPolicies) > 0 {
+		policies.WriteString("\n\n")
+		policies.WriteString(cfg.RenderTemplate(templates.PolicyBookingPoliciesTitle, nil).Body)
+		for _, policy := range intent.BookingPolicies {
+			policies.WriteString("\n  ✅ ")
+			policies.WriteString(policy)
+		}
+	}
+	params := templates.Params{"service": intent.Service, "clinic_name": name, "policies": policies.String()}
+	if intent.ScheduledFor == nil {
+		return cfg.RenderTemplate(templates.BookingNoDepositUntimed, params)
+	}
+	params["time"] = intent.ScheduledFor.Format("Monday, January 2 at 3:04 PM")
+	return cfg.RenderTemplate(templates.BookingNoDeposit, params)
+}
+
 // parseDepositIDs validates and parses org and lead IDs from the message request.
 func (d *depositDispatcher) parseDepositIDs(msg MessageRequest) (uuid.UUID, uuid.UUID, error) {
 	orgUUID, err := uuid.Parse(msg.OrgID)
@@ -154,19 +365,19 @@ func (d *depositDispatcher) parseDepositIDs(msg MessageRequest) (uuid.UUID, uuid
 
 // checkDuplicateDeposit verifies no pending/succeeded deposit already exists for this lead.
 // Returns (true, nil) if a duplicate exists and the caller should stop.
-func (d *depositDispatcher) checkDuplicateDeposit(ctx context.Context, orgUUID, leadUUID uuid.UUID, msg MessageRequest) (bool, error) {
+func (d *depositDispatcher) checkDuplicateDeposit(ctx context.Context, leadUUID uuid.UUID, msg MessageRequest) (bool, error) {
 	checker, ok := d.payments.(paymentIntentChecker)
 	if !ok {
-		d.logger.Warn("SendDeposit: payments repo does not support HasOpenDeposit check, skipping to avoid duplicate", "org_id", msg.OrgID, "lead_id", msg.LeadID)
+		d.log(ctx).Warn("SendDeposit: payments repo does not support HasOpenDeposit check, skipping to avoid duplicate", "org_id", msg.OrgID, "lead_id", msg.LeadID)
 		return false, fmt.Errorf("SendDeposit: cannot verify existing deposit - payments repo missing HasOpenDeposit")
 	}
-	has, err := checker.HasOpenDeposit(ctx, orgUUID, leadUUID)
+	has, err := checker.HasOpenDeposit(ctx, tenancy.ForOrg(msg.OrgID), leadUUID)
 	if err != nil {
-		d.logger.Error("SendDeposit: could not check for existing deposit, skipping to avoid duplicate", "error", err, "org_id", msg.OrgID, "lead_id", msg.LeadID)
+		d.log(ctx).Error("SendDeposit: could not check for existing deposit, skipping to avoid duplicate", "error", err, "org_id", msg.OrgID, "lead_id", msg.LeadID)
 		return false, fmt.Errorf("SendDeposit: unable to verify existing deposit status: %w", err)
 	}
 	if has {
-		d.logger.Info("SendDeposit: existing deposit intent found; skipping new link", "org_id", msg.OrgID, "lead_id", msg.LeadID)
+		d.log(ctx).Info("SendDeposit: existing deposit intent found; skipping new link", "org_id", msg.OrgID, "lead_id", msg.LeadID)
 		return true, nil
 	}
 	return false, nil
@@ -187,8 +398,8 @@ func (d *depositDispatcher) createPaymentIntent(ctx context.Context, orgUUID, le
 	}
 
 	if d.leads != nil {
-		if err := d.leads.UpdateDepositStatus(ctx, msg.LeadID, "pending", "normal"); err != nil {
-			d.logger.Warn("SendDeposit: failed to update lead deposit status", "error", err, "org_id", msg.OrgID, "lead_id", msg.LeadID)
+		if err := d.leads.UpdateDepositStatus(ctx, tenancy.ForOrg(msg.OrgID), msg.LeadID, "pending", "normal"); err != nil {
+			d.log(ctx).Warn("SendDeposit: failed to update lead deposit status", "error", err, "org_id", msg.OrgID, "lead_id", msg.LeadID)
 		}
 	}
 
@@ -216,12 +427,12 @@ func (d *depositDispatcher) resolveFromNumber(msg MessageRequest) string {
 // creates a new one through the payment provider.
 func (d *depositDispatcher) resolveCheckoutLink(ctx context.Context, intent *DepositIntent, msg MessageRequest, paymentID uuid.UUID, fromNumber string) (*payments.CheckoutResponse, error) {
 	if intent.PreloadedURL != "" {
-		d.logger.Info("SendDeposit: using preloaded checkout link (saved ~1.7s)",
+		d.log(ctx).Info("SendDeposit: using preloaded checkout link (saved ~1.7s)",
 			"org_id", msg.OrgID,
 			"lead_id", msg.LeadID,
 			"payment_id", paymentID,
 		)
-		return &payments.CheckoutResponse{URL: intent.PreloadedURL}, nil
+		return &payments.CheckoutResponse{URL: intent.PreloadedURL, OrderID: intent.PreloadedOrderID}, nil
 	}
 
 	link, err := d.checkout.CreatePaymentLink(ctx, payments.CheckoutParams{
@@ -238,7 +449,7 @@ func (d *depositDispatcher) resolveCheckoutLink(ctx context.Context, intent *Dep
 	if err != nil {
 		return nil, fmt.Errorf("SendDeposit: create checkout link: %w", err)
 	}
-	d.logger.Info("SendDeposit: link created",
+	d.log(ctx).Info("SendDeposit: link created",
 		"org_id", msg.OrgID,
 		"lead_id", msg.LeadID,
 		"amount_cents", intent.AmountCents,
@@ -248,35 +459,47 @@ func (d *depositDispatcher) resolveCheckoutLink(ctx context.Context, intent *Dep
 	return link, nil
 }
 
-// buildDepositSMSBody constructs the deposit SMS text including amount, policies, and checkout URL.
-func buildDepositSMSBody(intent *DepositIntent, checkoutURL string) string {
-	amount := fmt.Sprintf("$%.2f", float64(intent.AmountCents)/100)
-	explainer := fmt.Sprintf("💳 %s deposit — applies toward your treatment cost and secures your spot.\n\n⚠️ Deposits are forfeited for no-shows or late cancellations.", amount)
+// recordCheckoutOrder saves the link's provider order when the payments store
+// supports it. Failure only costs the early payment check, so it is logged.
+func (d *depositDispatcher) recordCheckoutOrder(ctx context.Context, paymentID uuid.UUID, orderID string) {
+	recorder, ok := d.payments.(checkoutOrderRecorder)
+	if !ok || orderID == "" || paymentID == uuid.Nil {
+		return
+	}
+	if err := recorder.SetCheckoutOrder(ctx, paymentID, orderID); err != nil {
+		d.log(ctx).Warn("SendDeposit: failed to record checkout order", "error", err, "payment_id", paymentID, "order_id", orderID)
+	}
+}
+
+// buildDepositSMSBody constructs the deposit SMS text: the pre-payment
+// disclosure (amount and policies) followed by the checkout URL.
+func buildDepositSMSBody(intent *DepositIntent, checkoutURL string) templates.Rendered {
+	disclosure := intent.Disclosure
+	if disclosure == "" {
+		disclosure = clinic.RenderPaymentDisclosure(nil, "", int(intent.AmountCents), intent.BookingPolicies)
+	}
+	return templates.Render(templates.PaymentDepositLink, templates.Params{"disclosure": disclosure, "checkout_url": checkoutURL}, nil)
+}
 
-	if len(intent.BookingPolicies) > 0 {
-		var sb strings.Builder
-		sb.WriteString(explainer)
-		sb.WriteString("\n\n📋 Booking policies:\n")
-		for _, policy := range intent.BookingPolicies {
-			sb.WriteString("  ✅ ")
-			sb.WriteString(policy)
-			sb.WriteString("\n")
-		}
-		sb.WriteString(fmt.Sprintf("\n→ Complete your deposit here:\n%s", checkoutURL))
-		return sb.String()
+// shortCheckoutURL swaps the provider checkout URL for a short link. If the
+// link can't be issued the raw URL is sent, since a long link beats none.
+func (d *depositDispatcher) shortCheckoutURL(ctx context.Context, orgID string, paymentID uuid.UUID, rawURL string) string {
+	if d.shortLinks == nil || d.payBaseURL == "" {
+		return rawURL
 	}
-	return fmt.Sprintf("%s\n\n→ Complete your deposit here:\n%s", explainer, checkoutURL)
+	code, err := d.shortLinks.CreateShortLink(ctx, orgID, paymentID, rawURL)
+	if err != nil {
+		d.log(ctx).Warn("SendDeposit: short link failed; sending raw checkout url", "error", err, "payment_id", paymentID)
+		return rawURL
+	}
+	return strings.TrimRight(d.payBaseURL, "/") + "/" + code
 }
 
 // sendDepositSMS builds the deposit message, sends it via SMS, and records the transcript.
 func (d *depositDispatcher) sendDepositSMS(ctx context.Context, msg MessageRequest, resp *Response, intent *DepositIntent, paymentID uuid.UUID, fromNumber, rawURL string) {
-	checkoutURL := rawURL
-	if d.shortURLs != nil && d.apiBaseURL != "" {
-		code := d.shortURLs.SaveCheckoutURL(paymentID, rawURL)
-		checkoutURL = fmt.Sprintf("%s/pay/%s", strings.TrimRight(d.apiBaseURL, "/"), code)
-	}
+	checkoutURL := d.shortCheckoutURL(ctx, msg.OrgID, paymentID, rawURL)
 
-	body := buildDepositSMSBody(intent, checkoutURL)
+	rendered := buildDepositSMSBody(intent, checkoutURL)
 
 	conversationID := strings.TrimSpace(resp.ConversationID)
 	if conversationID == "" {
@@ -286,7 +509,7 @@ func (d *depositDispatcher) sendDepositSMS(ctx context.Context, msg MessageReque
 	sendCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
 
-	d.logger.Info("SendDeposit: sending sms with checkout link",
+	d.log(ctx).Info("SendDeposit: sending sms with checkout link",
 		"to", msg.From,
 		"from", fromNumber,
 		"payment_id", paymentID,
@@ -299,31 +522,31 @@ func (d *depositDispatcher) sendDepositSMS(ctx context.Context, msg MessageReque
 			ConversationID: resp.ConversationID,
 			To:             msg.From,
 			From:           fromNumber,
-			Body:           body,
-			Metadata: map[string]string{
+			Body:           rendered.Body,
+			Metadata: rendered.Stamp(map[string]string{
 				"provider":   "square",
 				"payment_id": paymentID.String(),
-			},
+			}),
 		}
 		if err := d.sms.SendReply(sendCtx, reply); err != nil {
-			d.logger.Error("SendDeposit: failed to send sms", "error", err, "org_id", msg.OrgID, "lead_id", msg.LeadID)
+			d.log(ctx).Error("SendDeposit: failed to send sms", "error", err, "org_id", msg.OrgID, "lead_id", msg.LeadID)
 		} else {
-			d.logger.Info("SendDeposit: sms sent", "to", msg.From, "payment_id", paymentID)
+			d.log(ctx).Info("SendDeposit: sms sent", "to", msg.From, "payment_id", paymentID)
 		}
 	} else {
-		d.logger.Warn("SendDeposit: sms messenger nil; link not sent", "org_id", msg.OrgID, "lead_id", msg.LeadID)
+		d.log(ctx).Warn("SendDeposit: sms messenger nil; link not sent", "org_id", msg.OrgID, "lead_id", msg.LeadID)
 	}
 
 	d.appendTranscript(context.Background(), conversationID, SMSTranscriptMessage{
 		Role: "assistant",
 		From: fromNumber,
 		To:   msg.From,
-		Body: body,
+		Body: rendered.Body,
 		Kind: "deposit_link",
-		Metadata: map[string]string{
+		Metadata: rendered.Stamp(map[string]string{
 			"payment_id": paymentID.String(),
 			"lead_id":    msg.LeadID,
-		},
+		}),
 	})
 }
 
@@ -343,7 +566,7 @@ func (d *depositDispatcher) emitDepositEvent(ctx context.Context, msg MessageReq
 		Provider:        "square",
 	}
 	if _, err := d.outbox.Insert(ctx, msg.OrgID, "payments.deposit.requested.v1", event); err != nil {
-		d.logger.Warn("SendDeposit: failed to enqueue outbox event", "error", err, "org_id", msg.OrgID, "lead_id", msg.LeadID)
+		d.log(ctx).Warn("SendDeposit: failed to enqueue outbox event", "error", err, "org_id", msg.OrgID, "lead_id", msg.LeadID)
 	}
 }
 
@@ -363,12 +586,12 @@ func (d *depositDispatcher) appendTranscript(ctx context.Context, conversationID
 	}
 	if d.transcript != nil {
 		if err := d.transcript.Append(ctx, conversationID, msg); err != nil {
-			d.logger.Warn("SendDeposit: failed to append sms transcript", "error", err, "conversation_id", conversationID)
+			d.log(ctx).Warn("SendDeposit: failed to append sms transcript", "error", err, "conversation_id", conversationID)
 		}
 	}
 	if d.convStore != nil {
 		if err := d.convStore.AppendMessage(ctx, conversationID, msg); err != nil {
-			d.logger.Warn("SendDeposit: failed to persist transcript", "error", err, "conversation_id", conversationID)
+			d.log(ctx).Warn("SendDeposit: failed to persist transcript", "error", err, "conversation_id", conversationID)
 		}
 	}
 }
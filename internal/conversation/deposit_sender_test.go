@@ -10,9 +10,12 @@ import (
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5/pgtype"
 
+	"github.com/wolfman30/medspa-ai-platform/internal/clinic"
 	"github.com/wolfman30/medspa-ai-platform/internal/leads"
+	"github.com/wolfman30/medspa-ai-platform/internal/notify"
 	"github.com/wolfman30/medspa-ai-platform/internal/payments"
 	paymentsql "github.com/wolfman30/medspa-ai-platform/internal/payments/sqlc"
+	"github.com/wolfman30/medspa-ai-platform/internal/tenancy"
 	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
 )
 
@@ -203,7 +206,199 @@ func TestDepositDispatcherPersistsToConversationStore(t *testing.T) {
 	}
 }
 
+func depositPolicyClinic() *stubDepositPolicySource {
+	return &stubDepositPolicySource{cfg: &clinic.Config{
+		Name:               "Glow Med Spa",
+		DepositAmountCents: 5000,
+		ServiceDepositPolicies: map[string]clinic.ServiceDepositPolicy{
+			"consultation": {RequireDeposit: false},
+			"lip filler":   {RequireDeposit: true, AmountCents: 10000},
+		},
+	}}
+}
+
+func TestDepositDispatcherExemptServiceSkipsPayment(t *testing.T) {
+	payRepo := &stubPaymentRepo{}
+	checkout := &stubCheckout{resp: &payments.CheckoutResponse{URL: "http://pay", ProviderID: "sq_123"}}
+	outbox := &stubOutbox{}
+	sms := &stubReplyMessenger{}
+	convStore := &stubConversationWriter{}
+	dispatcher := NewDepositDispatcher(payRepo, checkout, outbox, sms, nil, nil, nil, convStore, logging.Default(),
+		WithDepositPolicies(depositPolicyClinic()))
+
+	when := time.Date(2026, 3, 2, 14, 0, 0, 0, time.UTC)
+	msg := MessageRequest{OrgID: uuid.New().String(), LeadID: uuid.New().String(), From: "+1", To: "+2"}
+	resp := &Response{ConversationID: "conv-1", DepositIntent: &DepositIntent{Service: "Consultation", ScheduledFor: &when}}
+
+	if err := dispatcher.SendDeposit(context.Background(), msg, resp); err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if payRepo.called || checkout.called || outbox.called {
+		t.Fatalf("expected no payment row, link, or event for exempt service")
+	}
+	if !sms.called {
+		t.Fatalf("expected confirmation sms")
+	}
+	for _, want := range []string{"no deposit is needed for Consultation", "Monday, March 2 at 2:00 PM", "Glow Med Spa", "confirm your appointment"} {
+		if !strings.Contains(sms.last.Body, want) {
+			t.Fatalf("expected sms body to contain %q, got %q", want, sms.last.Body)
+		}
+	}
+	if convStore.lastMsg.Kind != "booking_confirmation" {
+		t.Fatalf("expected booking_confirmation kind, got %s", convStore.lastMsg.Kind)
+	}
+}
+
+type stubBookingHandoffNotifier struct {
+	handoffs []notify.BookingHandoff
+}
+
+func (s *stubBookingHandoffNotifier) NotifyBookingHandoff(ctx context.Context, orgID string, handoff notify.BookingHandoff) error {
+	s.handoffs = append(s.handoffs, handoff)
+	return nil
+}
+
+func TestDepositDispatcherExemptServiceRecordsRequestOnce(t *testing.T) {
+	sms := &stubReplyMessenger{}
+	leadsRepo := leads.NewInMemoryRepository()
+	orgID := uuid.New().String()
+	lead, err := leadsRepo.Create(context.Background(), &leads.CreateLeadRequest{OrgID: orgID, Name: "Ana", Phone: "+15550001111", Source: "sms"})
+	if err != nil {
+		t.Fatalf("create lead: %v", err)
+	}
+	notifier := &stubBookingHandoffNotifier{}
+	dispatcher := NewDepositDispatcher(&stubPaymentRepo{}, &stubCheckout{}, &stubOutbox{}, sms, nil, leadsRepo, nil, nil, logging.Default(),
+		WithDepositPolicies(depositPolicyClinic()),
+		WithBookingRequests(&stubProcessedStore{seen: map[string]bool{}}, notifier))
+
+	when := time.Date(2026, 3, 2, 14, 0, 0, 0, time.UTC)
+	msg := MessageRequest{OrgID: orgID, LeadID: lead.ID, From: lead.Phone, To: "+2"}
+	ctx := logging.WithFields(context.Background(), logging.Fields{JobID: "job-1"})
+	for i := 0; i < 2; i++ {
+		sms.called = false
+		resp := &Response{ConversationID: "conv-1", DepositIntent: &DepositIntent{Service: "Consultation", ScheduledFor: &when}}
+		if err := dispatcher.SendDeposit(ctx, msg, resp); err != nil {
+			t.Fatalf("send %d: %v", i+1, err)
+		}
+		if sent := sms.called; sent != (i == 0) {
+			t.Fatalf("send %d: confirmation sent = %v", i+1, sent)
+		}
+	}
+
+	if len(notifier.handoffs) != 1 {
+		t.Fatalf("expected one operator notification, got %d", len(notifier.handoffs))
+	}
+	if h := notifier.handoffs[0]; h.LeadID != lead.ID || h.Service != "Consultation" || h.ScheduledFor == nil || !h.ScheduledFor.Equal(when) {
+		t.Fatalf("unexpected handoff %+v", h)
+	}
+	got, err := leadsRepo.GetByID(context.Background(), tenancy.ForOrg(orgID), lead.ID)
+	if err != nil {
+		t.Fatalf("get lead: %v", err)
+	}
+	if got.DepositStatus != DepositStatusNotRequired || got.SelectedDateTime == nil || !got.SelectedDateTime.Equal(when) || got.SelectedService != "Consultation" {
+		t.Fatalf("expected request recorded on lead, got status=%q selected=%v service=%q", got.DepositStatus, got.SelectedDateTime, got.SelectedService)
+	}
+}
+
+func TestDepositDispatcherNonExemptServiceUnchanged(t *testing.T) {
+	payRepo := &stubPaymentRepo{}
+	checkout := &stubCheckout{resp: &payments.CheckoutResponse{URL: "http://pay", ProviderID: "sq_123"}}
+	sms := &stubReplyMessenger{}
+	dispatcher := NewDepositDispatcher(payRepo, checkout, &stubOutbox{}, sms, nil, nil, nil, nil, logging.Default(),
+		WithDepositPolicies(depositPolicyClinic()))
+
+	msg := MessageRequest{OrgID: uuid.New().String(), LeadID: uuid.New().String(), From: "+1", To: "+2"}
+	resp := &Response{ConversationID: "conv-1", DepositIntent: &DepositIntent{AmountCents: 5000, Service: "Botox"}}
+
+	if err := dispatcher.SendDeposit(context.Background(), msg, resp); err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if !payRepo.called || !checkout.called {
+		t.Fatalf("expected payment intent and checkout link for non-exempt service")
+	}
+	if checkout.params.AmountCents != 5000 {
+		t.Fatalf("expected amount 5000, got %d", checkout.params.AmountCents)
+	}
+	if !strings.Contains(sms.last.Body, "http://pay") {
+		t.Fatalf("expected checkout link in sms, got %q", sms.last.Body)
+	}
+}
+
+func TestDepositDispatcherPolicyOverrideAmount(t *testing.T) {
+	checkout := &stubCheckout{resp: &payments.CheckoutResponse{URL: "http://pay", ProviderID: "sq_123"}}
+	sms := &stubReplyMessenger{}
+	dispatcher := NewDepositDispatcher(&stubPaymentRepo{}, checkout, &stubOutbox{}, sms, nil, nil, nil, nil, logging.Default(),
+		WithDepositPolicies(depositPolicyClinic()))
+
+	msg := MessageRequest{OrgID: uuid.New().String(), LeadID: uuid.New().String(), From: "+1", To: "+2"}
+	resp := &Response{ConversationID: "conv-1", DepositIntent: &DepositIntent{AmountCents: 5000, Service: "Lip Filler"}}
+
+	if err := dispatcher.SendDeposit(context.Background(), msg, resp); err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if checkout.params.AmountCents != 10000 {
+		t.Fatalf("expected override amount 10000 in checkout params, got %d", checkout.params.AmountCents)
+	}
+	if !strings.Contains(sms.last.Body, "$100.00") {
+		t.Fatalf("expected sms to state override amount, got %q", sms.last.Body)
+	}
+}
+
 // stubs
+func TestDepositDispatcherSendsShortLink(t *testing.T) {
+	rawURL := "https://square.link/u/AbCdEf?src=sms&payment=1234567890abcdef"
+	checkout := &stubCheckout{resp: &payments.CheckoutResponse{URL: rawURL, ProviderID: "sq_123"}}
+	links := &stubShortLinks{code: "x7Yt2Qa"}
+	sms := &stubReplyMessenger{}
+	dispatcher := NewDepositDispatcher(&stubPaymentRepo{}, checkout, &stubOutbox{}, sms, nil, nil, nil, nil, logging.Default(),
+		WithShortURLs(links, "https://pay.aiwolfsolutions.com/"))
+	msg := MessageRequest{OrgID: uuid.New().String(), LeadID: uuid.New().String(), From: "+1", To: "+2"}
+	resp := &Response{ConversationID: "conv-1", DepositIntent: &DepositIntent{AmountCents: 5000, Description: "Test"}}
+
+	if err := dispatcher.SendDeposit(context.Background(), msg, resp); err != nil {
+		t.Fatalf("SendDeposit: %v", err)
+	}
+	if !strings.Contains(sms.last.Body, "https://pay.aiwolfsolutions.com/x7Yt2Qa") || strings.Contains(sms.last.Body, rawURL) {
+		t.Fatalf("expected the short link instead of the raw checkout url, got %q", sms.last.Body)
+	}
+	if links.orgID != msg.OrgID || links.target != rawURL || links.paymentID == uuid.Nil {
+		t.Fatalf("expected an org-scoped link to the checkout url, got %+v", links)
+	}
+
+	// If a link can't be issued, the patient still gets the raw URL.
+	links.err = errors.New("db down")
+	if err := dispatcher.SendDeposit(context.Background(), msg, resp); err != nil {
+		t.Fatalf("SendDeposit: %v", err)
+	}
+	if !strings.Contains(sms.last.Body, rawURL) {
+		t.Fatalf("expected the raw checkout url as a fallback, got %q", sms.last.Body)
+	}
+}
+
+type stubShortLinks struct {
+	code      string
+	err       error
+	orgID     string
+	paymentID uuid.UUID
+	target    string
+}
+
+func (s *stubShortLinks) CreateShortLink(ctx context.Context, orgID string, paymentID uuid.UUID, targetURL string) (string, error) {
+	if s.err != nil {
+		return "", s.err
+	}
+	s.orgID, s.paymentID, s.target = orgID, paymentID, targetURL
+	return s.code, nil
+}
+
+type stubDepositPolicySource struct {
+	cfg *clinic.Config
+}
+
+func (s *stubDepositPolicySource) Get(ctx context.Context, orgID string) (*clinic.Config, error) {
+	return s.cfg, nil
+}
+
 type stubPaymentRepo struct {
 	called     bool
 	hasDeposit bool
@@ -220,7 +415,7 @@ func (s *stubPaymentRepo) CreateIntent(ctx context.Context, orgID uuid.UUID, lea
 	}, nil
 }
 
-func (s *stubPaymentRepo) HasOpenDeposit(ctx context.Context, orgID uuid.UUID, leadID uuid.UUID) (bool, error) {
+func (s *stubPaymentRepo) HasOpenDeposit(ctx context.Context, scope tenancy.OrgScope, leadID uuid.UUID) (bool, error) {
 	return s.hasDeposit, nil
 }
 
@@ -292,7 +487,7 @@ func (s *stubLeadsRepo) Create(context.Context, *leads.CreateLeadRequest) (*lead
 	return nil, nil
 }
 
-func (s *stubLeadsRepo) GetByID(context.Context, string, string) (*leads.Lead, error) {
+func (s *stubLeadsRepo) GetByID(context.Context, tenancy.OrgScope, string) (*leads.Lead, error) {
 	return nil, nil
 }
 
@@ -300,11 +495,15 @@ func (s *stubLeadsRepo) GetOrCreateByPhone(context.Context, string, string, stri
 	return nil, nil
 }
 
-func (s *stubLeadsRepo) UpdateSchedulingPreferences(context.Context, string, leads.SchedulingPreferences) error {
+func (s *stubLeadsRepo) UpdateSchedulingPreferences(context.Context, tenancy.OrgScope, string, leads.SchedulingPreferences) error {
+	return nil
+}
+
+func (s *stubLeadsRepo) MergePreferences(context.Context, string, leads.SchedulingPreferences) error {
 	return nil
 }
 
-func (s *stubLeadsRepo) UpdateDepositStatus(ctx context.Context, leadID string, status string, priority string) error {
+func (s *stubLeadsRepo) UpdateDepositStatus(ctx context.Context, _ tenancy.OrgScope, leadID string, status string, priority string) error {
 	s.called = true
 	s.leadID = leadID
 	s.status = status
@@ -312,15 +511,15 @@ func (s *stubLeadsRepo) UpdateDepositStatus(ctx context.Context, leadID string,
 	return nil
 }
 
-func (s *stubLeadsRepo) ListByOrg(context.Context, string, leads.ListLeadsFilter) ([]*leads.Lead, error) {
+func (s *stubLeadsRepo) ListByOrg(context.Context, tenancy.OrgScope, leads.ListLeadsFilter) ([]*leads.Lead, error) {
 	return nil, nil
 }
 
-func (s *stubLeadsRepo) UpdateSelectedAppointment(context.Context, string, leads.SelectedAppointment) error {
+func (s *stubLeadsRepo) UpdateSelectedAppointment(context.Context, tenancy.OrgScope, string, leads.SelectedAppointment) error {
 	return nil
 }
 
-func (s *stubLeadsRepo) UpdateBookingSession(context.Context, string, leads.BookingSessionUpdate) error {
+func (s *stubLeadsRepo) UpdateBookingSession(context.Context, tenancy.OrgScope, string, leads.BookingSessionUpdate) error {
 	return nil
 }
 
@@ -328,7 +527,7 @@ func (s *stubLeadsRepo) GetByBookingSessionID(context.Context, string) (*leads.L
 	return nil, leads.ErrLeadNotFound
 }
 
-func (s *stubLeadsRepo) UpdateEmail(context.Context, string, string) error {
+func (s *stubLeadsRepo) UpdateEmail(context.Context, tenancy.OrgScope, string, string) error {
 	return nil
 }
 
@@ -342,3 +541,25 @@ var _ = time.Now
 func ptrTime(t time.Time) *time.Time {
 	return &t
 }
+
+func TestHandleDepositFlowTagsExemptService(t *testing.T) {
+	svc := &LLMService{logger: logging.Default(), deposit: depositConfig{DefaultAmountCents: 5000}}
+	history := []ChatMessage{
+		{Role: ChatRoleUser, Content: "I'd like to book a consultation"},
+		{Role: ChatRoleAssistant, Content: "Great! Would you like to secure your spot with a deposit?"},
+		{Role: ChatRoleUser, Content: "yes"},
+	}
+	cfg := depositPolicyClinic().cfg
+	cfg.Services = []string{"Consultation", "Botox"}
+
+	intent := svc.handleDepositFlow(context.Background(), history, cfg)
+	if intent == nil {
+		t.Fatal("expected intent")
+	}
+	if !strings.EqualFold(intent.Service, "consultation") {
+		t.Fatalf("expected consultation service, got %q", intent.Service)
+	}
+	if intent.AmountCents != 0 {
+		t.Fatalf("expected no amount for exempt service, got %d", intent.AmountCents)
+	}
+}
@@ -0,0 +1,160 @@
+package conversation
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/clinic"
+	"github.com/wolfman30/medspa-ai-platform/internal/leads"
+)
+
+// Outcomes recorded on duplicateQuestionGuardTotal.
+const (
+	// duplicateGuardRegenerated means a regenerated reply replaced the duplicate.
+	duplicateGuardRegenerated = "regenerated"
+	// duplicateGuardTemplate means the next-question template replaced it.
+	duplicateGuardTemplate = "template"
+	// duplicateGuardUnresolved means neither worked and the reply went out as is.
+	duplicateGuardUnresolved = "unresolved"
+)
+
+var duplicateQuestionGuardTotal = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Namespace: "medspa",
+		Subsystem: "conversation",
+		Name:      "duplicate_question_guard_total",
+		Help:      "Generated replies that re-asked a qualification question, by intent and how they were fixed",
+	},
+	[]string{"intent", "outcome"}, // outcome: regenerated, template, unresolved
+)
+
+func init() {
+	prometheus.MustRegister(duplicateQuestionGuardTotal)
+}
+
+// questionIntentSubjects names what each intent asks for, for the
+// regeneration nudge.
+var questionIntentSubjects = map[QuestionIntent]string{
+	QuestionIntentName:               "for their name",
+	QuestionIntentPatientType:        "whether they are a new or returning patient",
+	QuestionIntentSchedule:           "which days and times work for them",
+	QuestionIntentProvider:           "about their provider preference",
+	QuestionIntentEmail:              "for their email address",
+	QuestionIntentVariant:            "whether they want an in-person or virtual visit",
+	QuestionIntentExtraQualification: "which option or area they want",
+}
+
+// duplicateQuestion returns the intent reply re-asks, or "" when it isn't a
+// duplicate. A question is a duplicate when the previous assistant question
+// had the same intent and either the patient hasn't replied since or the
+// answer is already known. A re-ask after a reply that didn't answer is
+// allowed.
+func duplicateQuestion(history []ChatMessage, reply string, cfg *clinic.Config) QuestionIntent {
+	intent := DetectQuestionIntent(reply)
+	if intent == "" {
+		return ""
+	}
+	last, replied := lastQuestionIntent(history)
+	if last != intent {
+		return ""
+	}
+	if !replied || questionAnswered(intent, history, cfg) {
+		return intent
+	}
+	return ""
+}
+
+// knownPreferences returns the qualification answers collected so far,
+// including those saved on the lead and injected as context.
+func knownPreferences(history []ChatMessage, cfg *clinic.Config) leads.SchedulingPreferences {
+	prefs, _ := extractClinicPreferences(history, cfg)
+	mergeLeadContextIntoPrefs(&prefs, history)
+	if prefs.ProviderPreference == "" {
+		prefs.ProviderPreference = matchProviderFromConfig(history, cfg)
+	}
+	return prefs
+}
+
+// questionAnswered reports whether the conversation already holds the
+// answer intent asks for. Intents without an extracted answer (email,
+// variant) are never considered answered.
+func questionAnswered(intent QuestionIntent, history []ChatMessage, cfg *clinic.Config) bool {
+	prefs := knownPreferences(history, cfg)
+	switch intent {
+	case QuestionIntentName:
+		return prefs.Name != ""
+	case QuestionIntentPatientType:
+		return prefs.PatientType != ""
+	case QuestionIntentSchedule:
+		return prefs.PreferredDays != "" || prefs.PreferredTimes != ""
+	case QuestionIntentProvider:
+		return prefs.ProviderPreference != ""
+	case QuestionIntentExtraQualification:
+		answer, _ := resolveExtraQualification(history, cfg, prefs.ServiceInterest)
+		return answer != ""
+	}
+	return false
+}
+
+// nextQuestionTemplate returns a fixed question for the first qualification
+// still missing, in the order ShouldFetchAvailability checks them, and its
+// intent. It returns "" once everything is collected.
+func nextQuestionTemplate(history []ChatMessage, cfg *clinic.Config) (string, QuestionIntent) {
+	prefs := knownPreferences(history, cfg)
+	switch {
+	case prefs.Name == "":
+		return "May I have your full name?", QuestionIntentName
+	case prefs.ServiceInterest == "":
+		return "Which treatment are you interested in?", ""
+	case prefs.PatientType == "":
+		return "Have you visited us before, or would this be your first time?", QuestionIntentPatientType
+	case prefs.PreferredDays == "" && prefs.PreferredTimes == "":
+		return "What days and times work best for you?", QuestionIntentSchedule
+	}
+	if cfg != nil && prefs.ProviderPreference == "" && len(cfg.GetServiceVariants(prefs.ServiceInterest)) == 0 &&
+		cfg.ServiceNeedsProviderPreference(cfg.ResolveServiceName(prefs.ServiceInterest)) {
+		return "Do you have a provider preference, or would you like the first available appointment?", QuestionIntentProvider
+	}
+	if _, question := resolveExtraQualification(history, cfg, prefs.ServiceInterest); question != "" {
+		return question, QuestionIntentExtraQualification
+	}
+	return "", ""
+}
+
+// guardDuplicateQuestion keeps a reply from re-asking the question the
+// assistant just asked. A duplicate is regenerated once with a nudge not to
+// re-ask; if the new reply still repeats it, the next missing
+// qualification's template is sent instead. history must not yet include
+// reply.
+func (s *LLMService) guardDuplicateQuestion(ctx context.Context, history []ChatMessage, cfg *clinic.Config, conversationID, reply string) string {
+	intent := duplicateQuestion(history, reply, cfg)
+	if intent == "" {
+		return reply
+	}
+	log := s.log(ctx)
+	log.Warn("reply re-asked a qualification question", "conversation_id", conversationID, "intent", intent)
+
+	nudge := ChatMessage{
+		Role: ChatRoleSystem,
+		Content: fmt.Sprintf("[SYSTEM GUARDRAIL] You already asked the patient %s. Do NOT ask it again. "+
+			"Respond to what the patient said and move on to the next missing detail.", questionIntentSubjects[intent]),
+	}
+	regenerated, err := s.generateResponse(ctx, append(slices.Clip(history), nudge))
+	if err != nil {
+		log.Warn("duplicate question regeneration failed", "conversation_id", conversationID, "error", err)
+	} else if regenerated = sanitizeSMSResponse(regenerated); strings.TrimSpace(regenerated) != "" && duplicateQuestion(history, regenerated, cfg) == "" {
+		duplicateQuestionGuardTotal.WithLabelValues(string(intent), duplicateGuardRegenerated).Inc()
+		return regenerated
+	}
+
+	if template, next := nextQuestionTemplate(history, cfg); template != "" && next != intent {
+		duplicateQuestionGuardTotal.WithLabelValues(string(intent), duplicateGuardTemplate).Inc()
+		return template
+	}
+	duplicateQuestionGuardTotal.WithLabelValues(string(intent), duplicateGuardUnresolved).Inc()
+	return reply
+}
@@ -0,0 +1,94 @@
+package conversation
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestDuplicateQuestion(t *testing.T) {
+	askName := ChatMessage{Role: ChatRoleAssistant, Content: "Great choice! May I have your full name?"}
+	cases := []struct {
+		name    string
+		history []ChatMessage
+		reply   string
+		want    QuestionIntent
+	}{
+		{"asked twice in a row", []ChatMessage{askName}, "Could I get your full name?", QuestionIntentName},
+		{"re-asked after the name was given", []ChatMessage{askName, {Role: ChatRoleUser, Content: "My name is Sarah Johnson"}}, "Thanks! What's your full name?", QuestionIntentName},
+		{"re-asked after an unrelated reply", []ChatMessage{askName, {Role: ChatRoleUser, Content: "how much is it?"}}, "It's $12 a unit. May I have your full name?", ""},
+		{"next question", []ChatMessage{askName, {Role: ChatRoleUser, Content: "My name is Sarah Johnson"}}, "Thanks Sarah! Have you visited us before?", ""},
+		{"no question", []ChatMessage{askName}, "Thanks!", ""},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := duplicateQuestion(tc.history, tc.reply, nil); got != tc.want {
+				t.Fatalf("duplicateQuestion = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestFindDuplicateQuestions(t *testing.T) {
+	transcript := []ChatMessage{
+		{Role: ChatRoleAssistant, Content: "May I have your full name?"},
+		{Role: ChatRoleSystem, Content: "context"},
+		{Role: ChatRoleAssistant, Content: "Sorry, what's your name?"},
+		{Role: ChatRoleUser, Content: "Sarah"},
+		{Role: ChatRoleAssistant, Content: "Have you visited us before?"},
+	}
+	dupes := FindDuplicateQuestions(transcript)
+	if len(dupes) != 1 || dupes[0].Intent != QuestionIntentName || dupes[0].Repeat != "Sorry, what's your name?" {
+		t.Fatalf("unexpected duplicates: %+v", dupes)
+	}
+}
+
+func TestProcessMessage_DuplicateQuestionGuard(t *testing.T) {
+	const nameAgain = "Thanks! May I have your full name?"
+	cases := []struct {
+		name      string
+		responses []string
+		want      string
+		outcome   string
+	}{
+		{"regenerated", []string{nameAgain, "Thanks, Sarah! Have you visited us before?"}, "Thanks, Sarah! Have you visited us before?", duplicateGuardRegenerated},
+		{"template", []string{nameAgain, "Sorry, could I get your full name?"}, "Have you visited us before, or would this be your first time?", duplicateGuardTemplate},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			ts := setupService(t, withLLMResponses(append([]string{"Great choice! May I have your full name?"}, tc.responses...)...))
+			convID := "sms:org-dup:1555000" + tc.name
+			startConv(t, ts, convID, "org-dup", "Hi, I'm interested in Botox")
+			fired := func() float64 {
+				return testutil.ToFloat64(duplicateQuestionGuardTotal.WithLabelValues(string(QuestionIntentName), tc.outcome))
+			}
+			before := fired()
+
+			resp, err := ts.svc.ProcessMessage(context.Background(), MessageRequest{
+				ConversationID: convID,
+				OrgID:          "org-dup",
+				Message:        "My name is Sarah Johnson",
+				Channel:        ChannelSMS,
+			})
+			if err != nil {
+				t.Fatalf("ProcessMessage: %v", err)
+			}
+			if resp.Message != tc.want {
+				t.Fatalf("reply = %q, want %q", resp.Message, tc.want)
+			}
+			if got := fired() - before; got != 1 {
+				t.Fatalf("guard metric delta = %v, want 1", got)
+			}
+			if !strings.Contains(fmt.Sprint(ts.llm.lastReq), "You already asked the patient for their name") {
+				t.Fatalf("expected the regeneration to carry the nudge")
+			}
+			history := getHistory(t, ts.mr, convID)
+			if last := history[len(history)-1]; last.Content != tc.want {
+				t.Fatalf("saved reply = %q, want %q", last.Content, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,235 @@
+package conversation
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/tenancy"
+)
+
+// emailCandidatePattern finds anything a patient likely meant as an email
+// address: text on both sides of an @, tolerating a stray space around the @
+// and commas typed for dots in the domain.
+var emailCandidatePattern = regexp.MustCompile(`[A-Za-z0-9._%+\-]+ ?@ ?[A-Za-z0-9.,\-]*`)
+
+// spelledEmailPattern finds an address with "at" and "dot" written out, such
+// as "jane at gmail dot com".
+var spelledEmailPattern = regexp.MustCompile(`(?i)\b[a-z0-9._%+\-]+\s*(\(at\)|\[at\]|\bat\b)\s*[a-z0-9\-]+\s*(\(dot\)|\[dot\]|\bdot\b)\s*[a-z]{2,}\b`)
+
+var (
+	emailLocalPattern = regexp.MustCompile(`^[a-z0-9._%+\-]+$`)
+	emailLabelPattern = regexp.MustCompile(`^[a-z0-9]([a-z0-9\-]*[a-z0-9])?$`)
+	emailTLDPattern   = regexp.MustCompile(`^[a-z]{2,}$`)
+)
+
+// emailDomainTypos maps common misspellings of the big mailbox providers to
+// the domain the patient almost certainly meant.
+var emailDomainTypos = map[string]string{
+	"gmial.com": "gmail.com", "gmai.com": "gmail.com", "gmal.com": "gmail.com",
+	"gnail.com": "gmail.com", "gamil.com": "gmail.com", "gmaill.com": "gmail.com",
+	"gmail.co": "gmail.com", "gmail.cm": "gmail.com", "gmail.om": "gmail.com",
+	"yaho.com": "yahoo.com", "yahooo.com": "yahoo.com", "yhoo.com": "yahoo.com",
+	"yahoo.co": "yahoo.com", "yahoo.cm": "yahoo.com",
+	"hotmial.com": "hotmail.com", "hotmal.com": "hotmail.com", "hotmai.com": "hotmail.com",
+	"hotmail.co": "hotmail.com", "hotmail.cm": "hotmail.com",
+	"outlok.com": "outlook.com", "outloo.com": "outlook.com", "outlook.co": "outlook.com",
+	"iclod.com": "icloud.com", "icoud.com": "icloud.com", "icloud.co": "icloud.com",
+}
+
+// emailTLDTypos maps mistyped top-level domains that are never real to ".com".
+var emailTLDTypos = map[string]string{
+	"con": "com", "cmo": "com", "comm": "com", "ocm": "com", "vom": "com", "xom": "com",
+}
+
+// emailProviderRoots lets "gmailcom" (a dropped dot) be recognized.
+var emailProviderRoots = []string{"gmail", "yahoo", "hotmail", "outlook", "icloud", "aol"}
+
+// ValidateEmail reports whether addr is a usable email address: a plain local
+// part, a dotted domain of valid labels, and an alphabetic TLD.
+func ValidateEmail(addr string) bool {
+	addr = strings.ToLower(strings.TrimSpace(addr))
+	if len(addr) > 254 || strings.Count(addr, "@") != 1 {
+		return false
+	}
+	local, domain, _ := strings.Cut(addr, "@")
+	if local == "" || len(local) > 64 || !emailLocalPattern.MatchString(local) ||
+		strings.HasPrefix(local, ".") || strings.HasSuffix(local, ".") || strings.Contains(local, "..") {
+		return false
+	}
+	labels := strings.Split(domain, ".")
+	if len(labels) < 2 {
+		return false
+	}
+	for _, label := range labels {
+		if len(label) > 63 || !emailLabelPattern.MatchString(label) {
+			return false
+		}
+	}
+	return emailTLDPattern.MatchString(labels[len(labels)-1])
+}
+
+// SuggestEmailCorrection returns the address the patient most likely meant
+// when addr has a recognizable typo (gmial.com, a ".con" TLD, a comma for a
+// dot). It returns "" when addr looks intended or no fix is obvious.
+func SuggestEmailCorrection(addr string) string {
+	addr = normalizeEmailCandidate(addr)
+	local, domain, ok := strings.Cut(addr, "@")
+	if !ok || local == "" || domain == "" {
+		return ""
+	}
+	fixed := strings.ReplaceAll(domain, ",", ".")
+	if !strings.Contains(fixed, ".") {
+		for _, root := range emailProviderRoots {
+			if fixed == root+"com" {
+				fixed = root + ".com"
+				break
+			}
+		}
+	}
+	if i := strings.LastIndex(fixed, "."); i >= 0 {
+		if tld, ok := emailTLDTypos[fixed[i+1:]]; ok {
+			fixed = fixed[:i+1] + tld
+		}
+	}
+	if d, ok := emailDomainTypos[fixed]; ok {
+		fixed = d
+	}
+	suggestion := local + "@" + fixed
+	if suggestion == addr || !ValidateEmail(suggestion) {
+		return ""
+	}
+	return suggestion
+}
+
+// normalizeEmailCandidate lowercases a candidate and drops the spaces and
+// trailing punctuation a patient types around it.
+func normalizeEmailCandidate(s string) string {
+	s = strings.ToLower(strings.ReplaceAll(strings.TrimSpace(s), " ", ""))
+	return strings.TrimRight(s, ".,-")
+}
+
+// emailCheck is the outcome of reading a message for an email address.
+type emailCheck struct {
+	Email      string // valid address, safe to store
+	Suggestion string // corrected address awaiting the patient's confirmation
+	Invalid    bool   // looked like an address but can't be used or fixed
+}
+
+func (c emailCheck) found() bool {
+	return c.Email != "" || c.Suggestion != "" || c.Invalid
+}
+
+// checkEmailInput reads msg for an email address. When expectingEmail is set
+// (the last reply asked for one), an address with "at" and "dot" spelled out
+// also counts as a failed attempt; other replies such as "Mon-Fri" don't.
+func checkEmailInput(msg string, expectingEmail bool) emailCheck {
+	if candidate := emailCandidatePattern.FindString(msg); candidate != "" {
+		addr := normalizeEmailCandidate(candidate)
+		if suggestion := SuggestEmailCorrection(addr); suggestion != "" {
+			return emailCheck{Suggestion: suggestion}
+		}
+		if ValidateEmail(addr) {
+			return emailCheck{Email: addr}
+		}
+		// "see you @ 3" isn't an address; only read a spaced-out @ as one
+		// when an email was asked for.
+		if !strings.Contains(candidate, " ") || expectingEmail {
+			return emailCheck{Invalid: true}
+		}
+		return emailCheck{}
+	}
+	if expectingEmail && spelledEmailPattern.MatchString(msg) {
+		return emailCheck{Invalid: true}
+	}
+	return emailCheck{}
+}
+
+const (
+	emailConfirmLead   = "Just to double-check, did you mean"
+	emailInvalidPrompt = "Hmm, that email address doesn't look quite right. Could you send it again? (e.g. name@example.com)"
+	emailAskAgainReply = "No problem! What's the correct email address?"
+)
+
+func emailConfirmPrompt(suggestion string) string {
+	return fmt.Sprintf("%s %s? Reply YES to use it, or send the correct email.", emailConfirmLead, suggestion)
+}
+
+// pendingEmailSuggestion returns the address offered in a typo confirmation
+// within an assistant reply, if any.
+func pendingEmailSuggestion(reply string) string {
+	i := strings.Index(reply, emailConfirmLead)
+	if i < 0 {
+		return ""
+	}
+	return ExtractEmail(reply[i:])
+}
+
+// lastAssistantBeforeUser returns the assistant reply preceding the newest
+// user turn.
+func lastAssistantBeforeUser(history []ChatMessage) string {
+	for i := len(history) - 2; i >= 0; i-- {
+		if history[i].Role == ChatRoleAssistant {
+			return history[i].Content
+		}
+	}
+	return ""
+}
+
+// handleEmailCapture reads the patient's email address deterministically
+// instead of trusting the LLM to judge it. A valid address is stored and the
+// turn continues; a likely typo is offered back for confirmation (never
+// corrected silently); an unusable address is asked for again, once.
+func (s *LLMService) handleEmailCapture(ctx context.Context, pc *processContext) *Response {
+	if s.leadsRepo == nil || pc.req.LeadID == "" {
+		return nil
+	}
+	last := lastAssistantBeforeUser(pc.history)
+	check := checkEmailInput(pc.rawMessage, strings.Contains(strings.ToLower(last), "email"))
+
+	if pending := pendingEmailSuggestion(last); pending != "" && !check.found() {
+		switch parseShortYesNo(pc.rawMessage) {
+		case MarketingConsentYes:
+			s.saveLeadEmail(ctx, tenancy.ForOrg(pc.req.OrgID), pc.req.LeadID, pending)
+		case MarketingConsentNo:
+			return s.saveAndReturn(ctx, pc, emailAskAgainReply, "email typo declined")
+		}
+		return nil
+	}
+
+	switch {
+	case check.Email != "":
+		s.saveLeadEmail(ctx, tenancy.ForOrg(pc.req.OrgID), pc.req.LeadID, check.Email)
+	case check.Suggestion != "":
+		return s.saveAndReturn(ctx, pc, emailConfirmPrompt(check.Suggestion), "email typo suggestion")
+	case check.Invalid && !strings.Contains(last, emailInvalidPrompt):
+		return s.saveAndReturn(ctx, pc, emailInvalidPrompt, "invalid email")
+	}
+	return nil
+}
+
+// captureIntroEmail stores a valid address volunteered in the first message
+// and returns a follow-up to append to the reply when it needs confirming or
+// re-sending.
+func (s *LLMService) captureIntroEmail(ctx context.Context, scope tenancy.OrgScope, leadID, intro string) string {
+	if s.leadsRepo == nil || leadID == "" {
+		return ""
+	}
+	check := checkEmailInput(intro, false)
+	switch {
+	case check.Email != "":
+		s.saveLeadEmail(ctx, scope, leadID, check.Email)
+	case check.Suggestion != "":
+		return emailConfirmPrompt(check.Suggestion)
+	case check.Invalid:
+		return emailInvalidPrompt
+	}
+	return ""
+}
+
+func (s *LLMService) saveLeadEmail(ctx context.Context, scope tenancy.OrgScope, leadID, email string) {
+	if err := s.leadsRepo.UpdateEmail(ctx, scope, leadID, email); err != nil {
+		s.log(ctx).Warn("failed to save email", "lead_id", leadID, "error", err)
+	}
+}
@@ -0,0 +1,211 @@
+package conversation
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	miniredis "github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/wolfman30/medspa-ai-platform/internal/leads"
+	"github.com/wolfman30/medspa-ai-platform/internal/tenancy"
+	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
+)
+
+func TestValidateEmail(t *testing.T) {
+	cases := map[string]bool{
+		"jane@example.com":        true,
+		"jane.doe+spa@mail.co.uk": true,
+		"J.Doe@Example.COM":       true,
+		"not-an-email":            false,
+		"jane@":                   false,
+		"@example.com":            false,
+		"jane@example":            false,
+		"jane@@example.com":       false,
+		"jane..doe@example.com":   false,
+		".jane@example.com":       false,
+		"jane@-example.com":       false,
+		"jane@example.c0m":        false,
+		"jane@gmail,com":          false,
+		"jane doe@example.com":    false,
+		"jane@example..com":       false,
+		"jane@" + strings.Repeat("a", 64) + ".com": false,
+	}
+	for addr, want := range cases {
+		if got := ValidateEmail(addr); got != want {
+			t.Errorf("ValidateEmail(%q) = %v, want %v", addr, got, want)
+		}
+	}
+}
+
+func TestSuggestEmailCorrection(t *testing.T) {
+	cases := map[string]string{
+		"jane@gmial.com":     "jane@gmail.com",
+		"jane@gmail.con":     "jane@gmail.com",
+		"jane@gmail,com":     "jane@gmail.com",
+		"jane@gmailcom":      "jane@gmail.com",
+		"Jane@Hotmial.com":   "jane@hotmail.com",
+		"jane@yaho.com.":     "jane@yahoo.com",
+		"jane@clinic.con":    "jane@clinic.com",
+		"jane@gmail.com":     "",
+		"jane@company.co":    "",
+		"jane@example":       "",
+		"not-an-email":       "",
+		"jane@":              "",
+		"jane@outlook.co.uk": "",
+	}
+	for addr, want := range cases {
+		if got := SuggestEmailCorrection(addr); got != want {
+			t.Errorf("SuggestEmailCorrection(%q) = %q, want %q", addr, got, want)
+		}
+	}
+}
+
+func TestCheckEmailInput(t *testing.T) {
+	tests := []struct {
+		msg       string
+		expecting bool
+		want      emailCheck
+	}{
+		{msg: "it's Jane.Doe@Example.com, thanks!", want: emailCheck{Email: "jane.doe@example.com"}},
+		{msg: "jane@gmial.com", want: emailCheck{Suggestion: "jane@gmail.com"}},
+		{msg: "jane @ gmail.com", want: emailCheck{Email: "jane@gmail.com"}},
+		{msg: "jane@example", want: emailCheck{Invalid: true}},
+		{msg: "see you @ 3", want: emailCheck{}},
+		{msg: "see you @ 3", expecting: true, want: emailCheck{Invalid: true}},
+		{msg: "not-an-email", want: emailCheck{}},
+		{msg: "not-an-email", expecting: true, want: emailCheck{}},
+		{msg: "Mon-Fri", expecting: true, want: emailCheck{}},
+		{msg: "2-3pm", expecting: true, want: emailCheck{}},
+		{msg: "jane at gmail dot com", expecting: true, want: emailCheck{Invalid: true}},
+		{msg: "jane(at)gmail(dot)com", expecting: true, want: emailCheck{Invalid: true}},
+		{msg: "jane at gmail dot com", want: emailCheck{}},
+		{msg: "I'm free at noon", expecting: true, want: emailCheck{}},
+		{msg: "I'd rather not", expecting: true, want: emailCheck{}},
+		{msg: "yes", expecting: true, want: emailCheck{}},
+	}
+	for _, tt := range tests {
+		if got := checkEmailInput(tt.msg, tt.expecting); got != tt.want {
+			t.Errorf("checkEmailInput(%q, %v) = %+v, want %+v", tt.msg, tt.expecting, got, tt.want)
+		}
+	}
+}
+
+func newEmailCaptureService(t *testing.T, responses ...string) (*LLMService, *leads.InMemoryRepository, *leads.Lead) {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	repo := leads.NewInMemoryRepository()
+	lead, err := repo.Create(context.Background(), &leads.CreateLeadRequest{OrgID: "org-1", Phone: "+15550000000", Source: "sms"})
+	if err != nil {
+		t.Fatalf("create lead: %v", err)
+	}
+	llm := &stubLLMClient{}
+	for _, r := range responses {
+		llm.responses = append(llm.responses, LLMResponse{Text: r})
+	}
+	service := NewLLMService(llm, client, nil, "anthropic.claude-3-haiku-20240307-v1:0", logging.Default(), WithLeadsRepo(repo))
+	return service, repo, lead
+}
+
+func TestHandleEmailCapture(t *testing.T) {
+	tests := []struct {
+		name      string
+		messages  []string
+		wantReply string
+		wantEmail string
+	}{
+		{
+			name:      "valid email stored",
+			messages:  []string{"jane.doe@example.com"},
+			wantReply: "Thanks!",
+			wantEmail: "jane.doe@example.com",
+		},
+		{
+			name:      "invalid email asked again",
+			messages:  []string{"jane at gmail dot com"},
+			wantReply: emailInvalidPrompt,
+		},
+		{
+			name:      "invalid email asked only once",
+			messages:  []string{"jane at gmail dot com", "jane at gmail dot com"},
+			wantReply: "Thanks!",
+		},
+		{
+			name:      "typo suggestion confirmed",
+			messages:  []string{"jane@gmial.com", "yes"},
+			wantReply: "Thanks!",
+			wantEmail: "jane@gmail.com",
+		},
+		{
+			name:      "typo suggestion denied",
+			messages:  []string{"jane@gmial.com", "no"},
+			wantReply: emailAskAgainReply,
+		},
+		{
+			name:      "typo corrected by patient",
+			messages:  []string{"jane@gmial.com", "jane@gmx.com"},
+			wantReply: "Thanks!",
+			wantEmail: "jane@gmx.com",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			service, repo, lead := newEmailCaptureService(t, "What's the best email for your booking confirmation?", "Thanks!")
+			start, err := service.StartConversation(ctx, StartRequest{ConversationID: "conv-email", LeadID: lead.ID, OrgID: "org-1", Intro: "I'd like to book botox", Channel: ChannelSMS})
+			if err != nil {
+				t.Fatalf("start failed: %v", err)
+			}
+
+			var resp *Response
+			for _, msg := range tt.messages {
+				resp, err = service.ProcessMessage(ctx, MessageRequest{ConversationID: start.ConversationID, LeadID: lead.ID, OrgID: "org-1", Message: msg, Channel: ChannelSMS})
+				if err != nil {
+					t.Fatalf("process %q failed: %v", msg, err)
+				}
+			}
+			if resp.Message != tt.wantReply {
+				t.Fatalf("expected reply %q, got %q", tt.wantReply, resp.Message)
+			}
+			updated, _ := repo.GetByID(ctx, tenancy.ForOrg("org-1"), lead.ID)
+			if updated.Email != tt.wantEmail {
+				t.Fatalf("expected stored email %q, got %q", tt.wantEmail, updated.Email)
+			}
+		})
+	}
+}
+
+func TestHandleEmailCapture_TypoAsksForConfirmation(t *testing.T) {
+	ctx := context.Background()
+	service, repo, lead := newEmailCaptureService(t, "Hi! What's your email?")
+	start, err := service.StartConversation(ctx, StartRequest{ConversationID: "conv-email", LeadID: lead.ID, OrgID: "org-1", Intro: "I'd like to book botox", Channel: ChannelSMS})
+	if err != nil {
+		t.Fatalf("start failed: %v", err)
+	}
+	resp, err := service.ProcessMessage(ctx, MessageRequest{ConversationID: start.ConversationID, LeadID: lead.ID, OrgID: "org-1", Message: "it's jane@gmail.con", Channel: ChannelSMS})
+	if err != nil {
+		t.Fatalf("process failed: %v", err)
+	}
+	if resp.Message != emailConfirmPrompt("jane@gmail.com") {
+		t.Fatalf("unexpected reply %q", resp.Message)
+	}
+	if updated, _ := repo.GetByID(ctx, tenancy.ForOrg("org-1"), lead.ID); updated.Email != "" {
+		t.Fatalf("expected the typo not to be stored, got %q", updated.Email)
+	}
+}
+
+func TestStartConversation_CapturesIntroEmail(t *testing.T) {
+	ctx := context.Background()
+	service, repo, lead := newEmailCaptureService(t, "Hi Jane! Happy to help with botox.")
+	resp, err := service.StartConversation(ctx, StartRequest{ConversationID: "conv-email", LeadID: lead.ID, OrgID: "org-1", Intro: "Hi, I'm Jane (jane@example.com) and I want botox", Channel: ChannelSMS})
+	if err != nil {
+		t.Fatalf("start failed: %v", err)
+	}
+	if resp.Message != "Hi Jane! Happy to help with botox." {
+		t.Fatalf("expected no email follow-up, got %q", resp.Message)
+	}
+	if updated, _ := repo.GetByID(ctx, tenancy.ForOrg("org-1"), lead.ID); updated.Email != "jane@example.com" {
+		t.Fatalf("expected intro email to be stored, got %q", updated.Email)
+	}
+}
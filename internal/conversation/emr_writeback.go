@@ -0,0 +1,283 @@
+package conversation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	moxieclient "github.com/wolfman30/medspa-ai-platform/internal/emr/moxie"
+)
+
+// EMR writeback attempt outcomes stored on emr_writeback_attempts.status.
+const (
+	WritebackStatusPending   = "pending"
+	WritebackStatusSucceeded = "succeeded"
+	// WritebackStatusFailed means the EMR refused the request, so nothing was
+	// created.
+	WritebackStatusFailed = "failed"
+	// WritebackStatusUnknown means the request failed in transit (e.g. timed
+	// out) and may still have created the appointment.
+	WritebackStatusUnknown = "unknown"
+	// WritebackStatusMatchedExisting means a pre-check found the appointment
+	// already on the EMR, so none was created.
+	WritebackStatusMatchedExisting = "matched_existing"
+	// WritebackStatusHandedOff means an earlier attempt may have reached the
+	// EMR but the pre-check couldn't tell, so the booking was left to clinic
+	// staff.
+	WritebackStatusHandedOff = "handed_off"
+)
+
+// errWritebackHandedOff is returned when a booking was handed to clinic staff
+// instead of risking a duplicate appointment.
+var errWritebackHandedOff = errors.New("conversation: booking handed off to clinic")
+
+// maxWritebackTries bounds create calls per writeback when the first one's
+// outcome is unknown.
+const maxWritebackTries = 2
+
+// writebackNamespace scopes booking idempotency keys.
+var writebackNamespace = uuid.MustParse("6f0c2a4e-5b1d-4c39-9a57-2d8e1f6b3c90")
+
+// bookingIdempotencyKey is the stable ID of one booking, however many times
+// it is retried or its payment event redelivered. It is the booking's own
+// UUID when there is one (the deposit's booking intent); bookings made
+// without a deposit have none, so their key is derived from the lead,
+// service and start time.
+func bookingIdempotencyKey(bookingID, orgID, leadID, serviceMenuItemID, startTime string) uuid.UUID {
+	if id, err := uuid.Parse(strings.TrimSpace(bookingID)); err == nil && id != uuid.Nil {
+		return id
+	}
+	return uuid.NewSHA1(writebackNamespace, []byte(strings.Join([]string{orgID, leadID, serviceMenuItemID, startTime}, "|")))
+}
+
+// WritebackAttempt is one attempt to create a booking's appointment on the
+// clinic's EMR.
+type WritebackAttempt struct {
+	ID             uuid.UUID  `json:"id"`
+	IdempotencyKey uuid.UUID  `json:"idempotency_key"`
+	OrgID          string     `json:"org_id"`
+	LeadID         string     `json:"lead_id,omitempty"`
+	Platform       string     `json:"platform"`
+	Status         string     `json:"status"`
+	AppointmentID  string     `json:"appointment_id,omitempty"`
+	Error          string     `json:"error,omitempty"`
+	StartedAt      time.Time  `json:"started_at"`
+	FinishedAt     *time.Time `json:"finished_at,omitempty"`
+}
+
+// WritebackAttemptStore persists EMR writeback attempts.
+type WritebackAttemptStore interface {
+	// Start records a pending attempt, filling in ID and StartedAt.
+	Start(ctx context.Context, attempt *WritebackAttempt) error
+	// Finish records an attempt's outcome.
+	Finish(ctx context.Context, id uuid.UUID, status, appointmentID, errMsg string) error
+	// ForKey returns the attempts made under an idempotency key, oldest first.
+	ForKey(ctx context.Context, key uuid.UUID) ([]WritebackAttempt, error)
+}
+
+// PGWritebackAttemptStore stores writeback attempts in PostgreSQL.
+type PGWritebackAttemptStore struct {
+	db callbackTaskDB
+}
+
+// NewPGWritebackAttemptStore builds a Postgres-backed WritebackAttemptStore.
+func NewPGWritebackAttemptStore(db *pgxpool.Pool) *PGWritebackAttemptStore {
+	if db == nil {
+		panic("conversation: pgx pool cannot be nil")
+	}
+	return &PGWritebackAttemptStore{db: db}
+}
+
+var _ WritebackAttemptStore = (*PGWritebackAttemptStore)(nil)
+
+// Start inserts a pending attempt.
+func (s *PGWritebackAttemptStore) Start(ctx context.Context, attempt *WritebackAttempt) error {
+	if attempt == nil {
+		return errors.New("conversation: writeback attempt cannot be nil")
+	}
+	if attempt.ID == uuid.Nil {
+		attempt.ID = uuid.New()
+	}
+	if attempt.StartedAt.IsZero() {
+		attempt.StartedAt = time.Now().UTC()
+	}
+	attempt.Status = WritebackStatusPending
+	query := `
+		INSERT INTO emr_writeback_attempts (id, idempotency_key, org_id, lead_id, platform, status, started_at)
+		VALUES ($1, $2, $3, NULLIF($4, '')::uuid, $5, $6, $7)
+	`
+	if _, err := s.db.Exec(ctx, query, attempt.ID, attempt.IdempotencyKey, attempt.OrgID, attempt.LeadID,
+		attempt.Platform, attempt.Status, attempt.StartedAt); err != nil {
+		return fmt.Errorf("conversation: start writeback attempt: %w", err)
+	}
+	return nil
+}
+
+// Finish records the attempt's outcome.
+func (s *PGWritebackAttemptStore) Finish(ctx context.Context, id uuid.UUID, status, appointmentID, errMsg string) error {
+	query := `
+		UPDATE emr_writeback_attempts
+		SET status = $2, appointment_id = NULLIF($3, ''), error = NULLIF($4, ''), finished_at = NOW()
+		WHERE id = $1
+	`
+	if _, err := s.db.Exec(ctx, query, id, status, appointmentID, errMsg); err != nil {
+		return fmt.Errorf("conversation: finish writeback attempt: %w", err)
+	}
+	return nil
+}
+
+// ForKey returns the attempts made under key, oldest first.
+func (s *PGWritebackAttemptStore) ForKey(ctx context.Context, key uuid.UUID) ([]WritebackAttempt, error) {
+	query := `
+		SELECT id, idempotency_key, org_id, COALESCE(lead_id::text, ''), platform, status,
+			COALESCE(appointment_id, ''), COALESCE(error, ''), started_at, finished_at
+		FROM emr_writeback_attempts
+		WHERE idempotency_key = $1
+		ORDER BY started_at ASC
+	`
+	rows, err := s.db.Query(ctx, query, key)
+	if err != nil {
+		return nil, fmt.Errorf("conversation: list writeback attempts: %w", err)
+	}
+	defer rows.Close()
+
+	var attempts []WritebackAttempt
+	for rows.Next() {
+		var a WritebackAttempt
+		if err := rows.Scan(&a.ID, &a.IdempotencyKey, &a.OrgID, &a.LeadID, &a.Platform, &a.Status,
+			&a.AppointmentID, &a.Error, &a.StartedAt, &a.FinishedAt); err != nil {
+			return nil, fmt.Errorf("conversation: scan writeback attempt: %w", err)
+		}
+		attempts = append(attempts, a)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("conversation: list writeback attempts: %w", err)
+	}
+	return attempts, nil
+}
+
+// writeBackMoxieAppointment creates the booking's appointment on Moxie at
+// most once per idempotency key. Moxie's booking mutation takes no
+// idempotency key, so the attempts table decides: a recorded success is
+// reused, and an earlier attempt that may have reached Moxie (it was left
+// pending by a crash, or its outcome is unknown) is looked for on the
+// client's calendar before creating another. That lookup is best-effort; when
+// it fails the booking is recorded as handed off and errWritebackHandedOff is
+// returned so the caller passes it to clinic staff. A create that fails in
+// transit is retried the same way.
+func (w *Worker) writeBackMoxieAppointment(ctx context.Context, key uuid.UUID, orgID, leadID string, req moxieclient.CreateAppointmentRequest) (*moxieclient.AppointmentResult, error) {
+	precheck := false
+	if w.writebacks != nil {
+		prior, err := w.writebacks.ForKey(ctx, key)
+		if err != nil {
+			w.log(ctx).Warn("failed to load writeback attempts", "error", err, "idempotency_key", key, "org_id", orgID, "lead_id", leadID)
+		}
+		for _, attempt := range prior {
+			switch attempt.Status {
+			case WritebackStatusSucceeded, WritebackStatusMatchedExisting:
+				w.log(ctx).Info("moxie appointment already created for booking; skipping create",
+					"idempotency_key", key, "appointment_id", attempt.AppointmentID, "org_id", orgID, "lead_id", leadID)
+				return &moxieclient.AppointmentResult{OK: true, AppointmentID: attempt.AppointmentID}, nil
+			case WritebackStatusHandedOff:
+				// Staff already have the booking; creating it now could
+				// duplicate theirs.
+				return nil, fmt.Errorf("%w: by an earlier attempt", errWritebackHandedOff)
+			case WritebackStatusPending, WritebackStatusUnknown:
+				precheck = true
+			}
+		}
+	}
+
+	for try := 1; ; try++ {
+		if precheck {
+			appointmentID, err := w.moxieClient.FindAppointment(ctx, findAppointmentRequest(req))
+			if err != nil {
+				// Without the check a create could duplicate the appointment;
+				// leave the booking to the clinic instead.
+				w.recordWriteback(ctx, key, orgID, leadID, WritebackStatusHandedOff, "", err.Error())
+				w.log(ctx).Warn("could not check moxie for an earlier attempt's appointment; handing booking to clinic",
+					"error", err, "idempotency_key", key, "org_id", orgID, "lead_id", leadID)
+				return nil, fmt.Errorf("%w: check for existing appointment: %v", errWritebackHandedOff, err)
+			}
+			if appointmentID != "" {
+				w.recordWriteback(ctx, key, orgID, leadID, WritebackStatusMatchedExisting, appointmentID, "")
+				w.log(ctx).Info("found the booking's appointment already on moxie",
+					"idempotency_key", key, "appointment_id", appointmentID, "org_id", orgID, "lead_id", leadID)
+				return &moxieclient.AppointmentResult{OK: true, AppointmentID: appointmentID}, nil
+			}
+		}
+
+		attemptID := w.startWriteback(ctx, key, orgID, leadID)
+		result, err := w.moxieClient.CreateAppointment(ctx, req)
+		switch {
+		case err == nil && result.OK:
+			w.finishWriteback(ctx, attemptID, WritebackStatusSucceeded, result.AppointmentID, "")
+			return result, nil
+		case err == nil:
+			w.finishWriteback(ctx, attemptID, WritebackStatusFailed, "", result.Message)
+			return result, nil
+		case !writebackOutcomeUnknown(err):
+			w.finishWriteback(ctx, attemptID, WritebackStatusFailed, "", err.Error())
+			return nil, err
+		}
+		w.finishWriteback(ctx, attemptID, WritebackStatusUnknown, "", err.Error())
+		if try >= maxWritebackTries || ctx.Err() != nil {
+			return nil, err
+		}
+		w.log(ctx).Warn("moxie create appointment outcome unknown; checking before retrying",
+			"error", err, "idempotency_key", key, "org_id", orgID, "lead_id", leadID)
+		precheck = true
+	}
+}
+
+// writebackOutcomeUnknown reports whether a failed create may still have
+// reached Moxie. Only errors Moxie itself returned are known to have had no
+// effect.
+func writebackOutcomeUnknown(err error) bool {
+	var apiErr *moxieclient.APIError
+	return !errors.As(err, &apiErr)
+}
+
+func findAppointmentRequest(req moxieclient.CreateAppointmentRequest) moxieclient.FindAppointmentRequest {
+	find := moxieclient.FindAppointmentRequest{MedspaID: req.MedspaID, Phone: req.Phone, Email: req.Email}
+	if len(req.Services) > 0 {
+		find.ServiceMenuItemID = req.Services[0].ServiceMenuItemID
+		find.StartTime = req.Services[0].StartTime
+	}
+	return find
+}
+
+// startWriteback records a pending attempt and returns its ID, or uuid.Nil
+// when attempts aren't tracked.
+func (w *Worker) startWriteback(ctx context.Context, key uuid.UUID, orgID, leadID string) uuid.UUID {
+	if w.writebacks == nil {
+		return uuid.Nil
+	}
+	attempt := &WritebackAttempt{IdempotencyKey: key, OrgID: orgID, LeadID: leadID, Platform: "moxie"}
+	if err := w.writebacks.Start(ctx, attempt); err != nil {
+		w.log(ctx).Warn("failed to record writeback attempt", "error", err, "idempotency_key", key, "org_id", orgID, "lead_id", leadID)
+		return uuid.Nil
+	}
+	return attempt.ID
+}
+
+func (w *Worker) finishWriteback(ctx context.Context, attemptID uuid.UUID, status, appointmentID, errMsg string) {
+	if w.writebacks == nil || attemptID == uuid.Nil {
+		return
+	}
+	// Record the outcome even if the booking's context has been cancelled;
+	// a pending attempt forces the next retry through the pre-check.
+	if err := w.writebacks.Finish(context.WithoutCancel(ctx), attemptID, status, appointmentID, errMsg); err != nil {
+		w.log(ctx).Warn("failed to record writeback outcome", "error", err, "attempt_id", attemptID, "status", status)
+	}
+}
+
+// recordWriteback records an attempt that finished without calling create.
+func (w *Worker) recordWriteback(ctx context.Context, key uuid.UUID, orgID, leadID, status, appointmentID, errMsg string) {
+	w.finishWriteback(ctx, w.startWriteback(ctx, key, orgID, leadID), status, appointmentID, errMsg)
+}
@@ -0,0 +1,257 @@
+package conversation
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	moxieclient "github.com/wolfman30/medspa-ai-platform/internal/emr/moxie"
+	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
+)
+
+type memoryWritebacks struct {
+	mu       sync.Mutex
+	attempts []WritebackAttempt
+}
+
+func (m *memoryWritebacks) Start(ctx context.Context, attempt *WritebackAttempt) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	attempt.ID = uuid.New()
+	attempt.Status = WritebackStatusPending
+	attempt.StartedAt = time.Now()
+	m.attempts = append(m.attempts, *attempt)
+	return nil
+}
+
+func (m *memoryWritebacks) Finish(ctx context.Context, id uuid.UUID, status, appointmentID, errMsg string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i := range m.attempts {
+		if m.attempts[i].ID == id {
+			m.attempts[i].Status = status
+			m.attempts[i].AppointmentID = appointmentID
+			m.attempts[i].Error = errMsg
+		}
+	}
+	return nil
+}
+
+func (m *memoryWritebacks) ForKey(ctx context.Context, key uuid.UUID) ([]WritebackAttempt, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var out []WritebackAttempt
+	for _, a := range m.attempts {
+		if a.IdempotencyKey == key {
+			out = append(out, a)
+		}
+	}
+	return out, nil
+}
+
+func (m *memoryWritebacks) statuses() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var out []string
+	for _, a := range m.attempts {
+		out = append(out, a.Status)
+	}
+	return out
+}
+
+// fakeMoxie books appointments into memory. With dropFirstCreate set, the
+// first create books the appointment and then drops the connection, as if
+// the response was lost to a timeout.
+type fakeMoxie struct {
+	mu              sync.Mutex
+	dropFirstCreate bool
+	creates         int
+	lookups         int
+	booked          map[string]string // appointment ID -> start time
+	serviceID       string
+	// lookupFails rejects the appointment lookup, as Moxie would if it
+	// doesn't offer the query.
+	lookupFails bool
+}
+
+func (f *fakeMoxie) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		OperationName string `json:"operationName"`
+	}
+	_ = json.NewDecoder(r.Body).Decode(&body)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	switch body.OperationName {
+	case "createAppointmentByClient":
+		f.creates++
+		id := "appt-1"
+		f.booked[id] = "2026-03-10T15:00:00Z"
+		if f.dropFirstCreate && f.creates == 1 {
+			conn, _, _ := w.(http.Hijacker).Hijack()
+			conn.Close()
+			return
+		}
+		_, _ = w.Write([]byte(`{"data":{"createAppointmentByClient":{"ok":true,"scheduledAppointment":{"id":"` + id + `"}}}}`))
+	case "clientScheduledAppointments":
+		f.lookups++
+		if f.lookupFails {
+			_, _ = w.Write([]byte(`{"errors":[{"message":"Cannot query field \"clientScheduledAppointments\" on type \"Query\"."}]}`))
+			return
+		}
+		type service struct {
+			ServiceMenuItemID string `json:"serviceMenuItemId"`
+			StartTime         string `json:"startTime"`
+		}
+		type appointment struct {
+			ID       string    `json:"id"`
+			Services []service `json:"services"`
+		}
+		appts := []appointment{}
+		for id, start := range f.booked {
+			appts = append(appts, appointment{ID: id, Services: []service{{ServiceMenuItemID: f.serviceID, StartTime: start}}})
+		}
+		resp := map[string]any{"data": map[string]any{"clientScheduledAppointments": appts}}
+		_ = json.NewEncoder(w).Encode(resp)
+	default:
+		http.Error(w, "unexpected operation", http.StatusBadRequest)
+	}
+}
+
+func writebackRequest() moxieclient.CreateAppointmentRequest {
+	return moxieclient.CreateAppointmentRequest{
+		MedspaID: "1264",
+		Phone:    "+15550001111",
+		Services: []moxieclient.ServiceInput{{
+			ServiceMenuItemID: "20424",
+			StartTime:         "2026-03-10T15:00:00Z",
+			EndTime:           "2026-03-10T15:30:00Z",
+		}},
+	}
+}
+
+func newWritebackWorker(t *testing.T, moxie *fakeMoxie, store WritebackAttemptStore) *Worker {
+	t.Helper()
+	srv := httptest.NewServer(moxie)
+	t.Cleanup(srv.Close)
+	client := moxieclient.NewClient(logging.Default(), moxieclient.WithEndpoint(srv.URL))
+	return &Worker{moxieClient: client, writebacks: store, logger: logging.Default()}
+}
+
+func TestWriteBackMoxieAppointment_TimeoutThenRetryCreatesOnce(t *testing.T) {
+	moxie := &fakeMoxie{dropFirstCreate: true, booked: map[string]string{}, serviceID: "20424"}
+	store := &memoryWritebacks{}
+	w := newWritebackWorker(t, moxie, store)
+	key := bookingIdempotencyKey("", "org-1", "lead-1", "20424", "2026-03-10T15:00:00Z")
+
+	result, err := w.writeBackMoxieAppointment(context.Background(), key, "org-1", "", writebackRequest())
+	if err != nil {
+		t.Fatalf("writeback: %v", err)
+	}
+	if !result.OK || result.AppointmentID != "appt-1" {
+		t.Fatalf("result = %+v, want the appointment created by the lost request", result)
+	}
+	if moxie.creates != 1 {
+		t.Fatalf("create called %d times, want 1", moxie.creates)
+	}
+	got := store.statuses()
+	if len(got) != 2 || got[0] != WritebackStatusUnknown || got[1] != WritebackStatusMatchedExisting {
+		t.Fatalf("attempts = %v, want [unknown matched_existing]", got)
+	}
+
+	// A redelivered payment event reuses the recorded appointment.
+	if _, err := w.writeBackMoxieAppointment(context.Background(), key, "org-1", "", writebackRequest()); err != nil {
+		t.Fatalf("second writeback: %v", err)
+	}
+	if moxie.creates != 1 || moxie.lookups != 1 {
+		t.Fatalf("redelivery called moxie: %d creates, %d lookups", moxie.creates, moxie.lookups)
+	}
+}
+
+func TestWriteBackMoxieAppointment_PendingAttemptChecksFirst(t *testing.T) {
+	moxie := &fakeMoxie{booked: map[string]string{"appt-7": "2026-03-10T15:00:00Z"}, serviceID: "20424"}
+	store := &memoryWritebacks{}
+	key := bookingIdempotencyKey("", "org-1", "lead-1", "20424", "2026-03-10T15:00:00Z")
+	// A worker crashed mid-create, leaving the attempt pending.
+	if err := store.Start(context.Background(), &WritebackAttempt{IdempotencyKey: key, OrgID: "org-1", Platform: "moxie"}); err != nil {
+		t.Fatal(err)
+	}
+	w := newWritebackWorker(t, moxie, store)
+
+	result, err := w.writeBackMoxieAppointment(context.Background(), key, "org-1", "", writebackRequest())
+	if err != nil {
+		t.Fatalf("writeback: %v", err)
+	}
+	if result.AppointmentID != "appt-7" || moxie.creates != 0 {
+		t.Fatalf("result = %+v after %d creates, want the existing appointment and no create", result, moxie.creates)
+	}
+	if got := store.statuses(); got[len(got)-1] != WritebackStatusMatchedExisting {
+		t.Fatalf("attempts = %v, want a matched_existing record", got)
+	}
+}
+
+func TestWriteBackMoxieAppointment_APIErrorIsNotRetried(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"errors":[{"message":"slot unavailable"}]}`))
+	}))
+	defer srv.Close()
+	store := &memoryWritebacks{}
+	w := &Worker{
+		moxieClient: moxieclient.NewClient(logging.Default(), moxieclient.WithEndpoint(srv.URL)),
+		writebacks:  store,
+		logger:      logging.Default(),
+	}
+	key := bookingIdempotencyKey("", "org-1", "lead-1", "20424", "2026-03-10T15:00:00Z")
+
+	if _, err := w.writeBackMoxieAppointment(context.Background(), key, "org-1", "", writebackRequest()); err == nil {
+		t.Fatal("expected the API error")
+	}
+	if got := store.statuses(); len(got) != 1 || got[0] != WritebackStatusFailed {
+		t.Fatalf("attempts = %v, want one failed attempt", got)
+	}
+}
+
+func TestWriteBackMoxieAppointment_FailedPrecheckHandsOff(t *testing.T) {
+	moxie := &fakeMoxie{dropFirstCreate: true, lookupFails: true, booked: map[string]string{}, serviceID: "20424"}
+	store := &memoryWritebacks{}
+	w := newWritebackWorker(t, moxie, store)
+	key := bookingIdempotencyKey("", "org-1", "lead-1", "20424", "2026-03-10T15:00:00Z")
+
+	_, err := w.writeBackMoxieAppointment(context.Background(), key, "org-1", "", writebackRequest())
+	if !errors.Is(err, errWritebackHandedOff) {
+		t.Fatalf("expected a handoff, got %v", err)
+	}
+	if moxie.creates != 1 {
+		t.Fatalf("create called %d times, want no retry without the check", moxie.creates)
+	}
+	got := store.statuses()
+	if len(got) != 2 || got[0] != WritebackStatusUnknown || got[1] != WritebackStatusHandedOff {
+		t.Fatalf("attempts = %v, want [unknown handed_off]", got)
+	}
+
+	// A redelivery leaves the booking with staff.
+	if _, err := w.writeBackMoxieAppointment(context.Background(), key, "org-1", "", writebackRequest()); !errors.Is(err, errWritebackHandedOff) {
+		t.Fatalf("expected the redelivery handed off, got %v", err)
+	}
+	if moxie.creates != 1 || moxie.lookups != 1 {
+		t.Fatalf("redelivery called moxie: %d creates, %d lookups", moxie.creates, moxie.lookups)
+	}
+}
+
+func TestBookingIdempotencyKey_UsesBookingID(t *testing.T) {
+	bookingID := uuid.New()
+	if got := bookingIdempotencyKey(bookingID.String(), "org-1", "lead-1", "20424", "2026-03-10T15:00:00Z"); got != bookingID {
+		t.Fatalf("key = %s, want the booking ID %s", got, bookingID)
+	}
+	derived := bookingIdempotencyKey("", "org-1", "lead-1", "20424", "2026-03-10T15:00:00Z")
+	if derived == uuid.Nil || derived != bookingIdempotencyKey("not-a-uuid", "org-1", "lead-1", "20424", "2026-03-10T15:00:00Z") {
+		t.Fatalf("expected a stable derived key without a booking ID, got %s", derived)
+	}
+}
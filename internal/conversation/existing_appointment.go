@@ -0,0 +1,142 @@
+package conversation
+
+import (
+	"context"
+	"regexp"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/clinic"
+)
+
+// Existing patients who booked directly with the clinic text in with
+// questions about that appointment ("can I wear makeup after my peel
+// tomorrow?"). Running them through the booking checklist is infuriating, so
+// once a patient mentions an appointment they already have, qualification is
+// suppressed until they ask to book something new.
+
+var existingAppointmentPatterns = []*regexp.Regexp{
+	// "I already have an appointment", "I have an appt tomorrow"
+	regexp.MustCompile(`(?i)\b(i|we)\s+(already\s+)?(have|had|got)\s+(an?\s+|my\s+)?(appointment|appt|booking|consult(ation)?)\b`),
+	// "I'm booked for Thursday", "I am already scheduled on Friday"
+	regexp.MustCompile(`(?i)\b(i'?m|i\s+am)\s+(already\s+)?(booked|scheduled)\s+(for|in|on|with)\b`),
+	// "my appointment", "my upcoming appt", "my consultation"
+	regexp.MustCompile(`(?i)\bmy\s+(upcoming\s+|next\s+|scheduled\s+)?(appointment|appt|consult(ation)?)\b`),
+	// "before my botox", "after my peel"
+	regexp.MustCompile(`(?i)\b(before|after)\s+my\s+(botox|dysport|xeomin|filler|fillers|peel|facial|hydrafacial|laser|microneedling|treatment|procedure|session|injections?)\b`),
+	// "my peel tomorrow", "my botox on friday"
+	regexp.MustCompile(`(?i)\bmy\s+[a-z]+\s+(tomorrow|today|tonight|this\s+(morning|afternoon|evening|week)|next\s+week|on\s+(mon|tues?|wed(nes)?|thu(rs)?|fri|sat(ur)?|sun)(day)?)\b`),
+}
+
+var newBookingIntentPatterns = []*regexp.Regexp{
+	// "book an appointment", "schedule another appt", "set up a new consult"
+	regexp.MustCompile(`(?i)\b(book|schedule|make|set\s+up)\s+(an?\s+|another\s+)?(new\s+|second\s+)?(appointment|appt|consult(ation)?)\b`),
+	// "can I also book botox", "could I schedule"
+	regexp.MustCompile(`(?i)\b(can|could|may)\s+i\s+(also\s+)?(book|schedule)\b`),
+	// "I'd like to book", "I want to also schedule"
+	regexp.MustCompile(`(?i)\b(i'?d|i\s+would|i)\s+(like|want|wanna|need)\s+(to\s+)?(also\s+)?(book|schedule)\b`),
+	// "also book", "book me", "book another"
+	regexp.MustCompile(`(?i)\b(also\s+book|book\s+(me|another|a\s+second))\b`),
+}
+
+// mentionsExistingAppointment reports whether msg refers to an appointment the
+// patient already has.
+func mentionsExistingAppointment(msg string) bool {
+	for _, re := range existingAppointmentPatterns {
+		if re.MatchString(msg) {
+			return true
+		}
+	}
+	return false
+}
+
+// mentionsNewBooking reports whether msg asks to book a new appointment.
+func mentionsNewBooking(msg string) bool {
+	for _, re := range newBookingIntentPatterns {
+		if re.MatchString(msg) {
+			return true
+		}
+	}
+	return false
+}
+
+// existingAppointmentMode reports whether the conversation is about an
+// appointment the patient already has. A patient message mentioning one turns
+// the mode on; a later (or the same) message asking to book something new
+// turns it back off so qualification resumes.
+func existingAppointmentMode(history []ChatMessage) bool {
+	active := false
+	for _, msg := range history {
+		if !msg.FromPatient() {
+			continue
+		}
+		switch {
+		case mentionsNewBooking(msg.Content):
+			active = false
+		case mentionsExistingAppointment(msg.Content):
+			active = true
+		}
+	}
+	return active
+}
+
+// existingAppointmentEnded reports whether the latest patient message asked
+// to book something new after the conversation had been about an existing
+// appointment. The earlier guardrail is still in history, so the caller has to
+// lift it explicitly.
+func existingAppointmentEnded(history []ChatMessage) bool {
+	for i := len(history) - 1; i >= 0; i-- {
+		if history[i].FromPatient() {
+			return !existingAppointmentMode(history) && existingAppointmentMode(history[:i])
+		}
+	}
+	return false
+}
+
+const existingAppointmentEndedGuardrail = "[SYSTEM GUARDRAIL] The patient now wants to book a new appointment. " +
+	"The earlier instruction not to run the booking checklist no longer applies; follow the booking checklist for this new request."
+
+const existingAppointmentGuardrail = "[SYSTEM GUARDRAIL] The patient already has an appointment and is asking a question about it, not booking a new one. " +
+	"Do NOT start the booking checklist: do NOT ask for their name, patient type, preferred days/times, provider preference, or email, and do NOT offer times or a deposit. " +
+	"Answer their question briefly using the clinic information you have. For anything specific to their treatment (aftercare, medications, whether an activity or product is OK for them), " +
+	"give general guidance only and tell them to confirm with their provider or call the clinic. If they ask to book something new, help them book it."
+
+// existingAppointmentContext returns the guardrail for a patient asking about
+// an appointment they already have, including when it is if our bookings
+// show one for the lead.
+func (s *LLMService) existingAppointmentContext(ctx context.Context, cfg *clinic.Config, orgID, leadID string) ChatMessage {
+	content := existingAppointmentGuardrail
+	if when := s.upcomingAppointmentText(ctx, cfg, orgID, leadID); when != "" {
+		content += " Our records show their upcoming appointment is " + when + ". Mention it if it helps answer their question."
+	} else {
+		content += " Their appointment isn't in our records (it may have been booked directly with the clinic), so do NOT state or guess its date, time, or details."
+	}
+	return ChatMessage{Role: ChatRoleSystem, Content: content}
+}
+
+// upcomingAppointmentText formats the lead's next booked appointment in the
+// clinic's timezone, or returns "" when there is none or it can't be looked up.
+func (s *LLMService) upcomingAppointmentText(ctx context.Context, cfg *clinic.Config, orgID, leadID string) string {
+	if s.appointments == nil {
+		return ""
+	}
+	orgUUID, orgErr := uuid.Parse(strings.TrimSpace(orgID))
+	leadUUID, leadErr := uuid.Parse(strings.TrimSpace(leadID))
+	if orgErr != nil || leadErr != nil {
+		return ""
+	}
+	scheduled, err := s.appointments.UpcomingAppointment(ctx, orgUUID, leadUUID)
+	if err != nil {
+		s.log(ctx).Warn("failed to look up upcoming appointment", "org_id", orgID, "lead_id", leadID, "error", err)
+		return ""
+	}
+	if scheduled == nil {
+		return ""
+	}
+	when := *scheduled
+	if cfg != nil {
+		when = when.In(ClinicLocation(cfg.Timezone))
+	}
+	return when.Format("Monday, January 2 at 3:04 PM")
+}
@@ -0,0 +1,161 @@
+package conversation
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/clinic"
+)
+
+func TestExistingAppointmentMode(t *testing.T) {
+	tests := []struct {
+		name     string
+		messages []string
+		want     bool
+	}{
+		{"makeup after peel", []string{"Can I wear makeup after my peel tomorrow?"}, true},
+		{"already booked", []string{"I already have an appointment, just have a question"}, true},
+		{"before appointment", []string{"What should I avoid before my appointment Thursday?"}, true},
+		{"booked for friday", []string{"I'm booked for Friday, do I need to shave?"}, true},
+		{"new booking", []string{"Hi, I'd like to book Botox"}, false},
+		{"book an appointment", []string{"Can I make an appointment for filler?"}, false},
+		{"price question", []string{"How much is a hydrafacial?"}, false},
+		{"re-enabled by new booking", []string{"Can I work out after my botox tomorrow?", "ok thanks", "actually can I also book botox"}, false},
+		{"both in one message", []string{"I have an appointment Tuesday but I'd like to book filler too"}, false},
+		{"question after new booking", []string{"I want to book a facial", "wait, can I drink before my appointment Thursday?"}, true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			history := []ChatMessage{{Role: ChatRoleSystem, Content: "before my appointment"}}
+			for _, msg := range tc.messages {
+				history = append(history,
+					ChatMessage{Role: ChatRoleUser, Content: msg},
+					ChatMessage{Role: ChatRoleAssistant, Content: "Happy to help with your appointment!"},
+				)
+			}
+			if got := existingAppointmentMode(history); got != tc.want {
+				t.Fatalf("existingAppointmentMode(%q) = %v, want %v", tc.messages, got, tc.want)
+			}
+		})
+	}
+}
+
+type stubAppointmentLookup struct {
+	scheduled *time.Time
+	calls     int
+}
+
+func (s *stubAppointmentLookup) UpcomingAppointment(ctx context.Context, orgID uuid.UUID, leadID uuid.UUID) (*time.Time, error) {
+	s.calls++
+	return s.scheduled, nil
+}
+
+func lastLLMContext(ts *testSetup) string {
+	lastReq := ts.llm.requests[len(ts.llm.requests)-1]
+	var b strings.Builder
+	for _, s := range lastReq.System {
+		b.WriteString(s + "\n")
+	}
+	for _, msg := range lastReq.Messages {
+		b.WriteString(msg.Content + "\n")
+	}
+	return b.String()
+}
+
+func withMoxieBotoxClinic(orgID string) func(*testSetup) {
+	return withClinicConfig(orgID, func(cfg *clinic.Config) {
+		cfg.BookingPlatform = "moxie"
+		cfg.MoxieConfig = &clinic.MoxieConfig{MedspaID: "1"}
+		cfg.Services = []string{"Botox"}
+		cfg.ServiceAliases = map[string]string{"botox": "Botox"}
+		cfg.Timezone = "America/New_York"
+	})
+}
+
+func TestProcessMessage_ExistingAppointmentSuppressesQualification(t *testing.T) {
+	ts := setupService(t, withLLMResponses("Hello!", "Yes, wait 4 hours before lying down."), withMoxieBotoxClinic("org-moxie"))
+	startConv(t, ts, "conv-existing", "org-moxie", "Hi")
+
+	_, err := ts.svc.ProcessMessage(context.Background(), MessageRequest{
+		ConversationID: "conv-existing",
+		OrgID:          "org-moxie",
+		Message:        "Can I lie down after my botox tomorrow?",
+		Channel:        ChannelSMS,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	got := lastLLMContext(ts)
+	if strings.Contains(got, "NAME is #1") || strings.Contains(got, "MUST ask for their full name") {
+		t.Fatalf("qualification guardrail injected for an existing appointment:\n%s", got)
+	}
+	if !strings.Contains(got, "already has an appointment") || !strings.Contains(got, "isn't in our records") {
+		t.Fatalf("expected existing-appointment guardrail without a date:\n%s", got)
+	}
+}
+
+func TestProcessMessage_ExistingAppointmentIncludesBookedTime(t *testing.T) {
+	orgID, leadID := uuid.NewString(), uuid.NewString()
+	ts := setupService(t, withLLMResponses("Hello!", "See you Thursday!"), withMoxieBotoxClinic(orgID))
+	scheduled := time.Date(2026, 10, 22, 18, 0, 0, 0, time.UTC)
+	lookup := &stubAppointmentLookup{scheduled: &scheduled}
+	ts.svc.appointments = lookup
+	startConv(t, ts, "conv-existing-booked", orgID, "Hi")
+
+	_, err := ts.svc.ProcessMessage(context.Background(), MessageRequest{
+		ConversationID: "conv-existing-booked",
+		OrgID:          orgID,
+		LeadID:         leadID,
+		Message:        "Should I stop retinol before my appointment?",
+		Channel:        ChannelSMS,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if lookup.calls != 1 {
+		t.Fatalf("expected one appointment lookup, got %d", lookup.calls)
+	}
+	if got := lastLLMContext(ts); !strings.Contains(got, "upcoming appointment is Thursday, October 22 at 2:00 PM") {
+		t.Fatalf("expected the booked time in clinic time:\n%s", got)
+	}
+}
+
+func TestProcessMessage_NewBookingReenablesQualification(t *testing.T) {
+	ts := setupService(t, withLLMResponses("Hello!", "Yes, that's fine.", "May I have your full name?"), withMoxieBotoxClinic("org-moxie"))
+	startConv(t, ts, "conv-existing-rebook", "org-moxie", "I already have an appointment, just have a question")
+
+	for _, msg := range []string{"Can I wear makeup after my peel tomorrow?", "actually can I also book botox"} {
+		if _, err := ts.svc.ProcessMessage(context.Background(), MessageRequest{
+			ConversationID: "conv-existing-rebook",
+			OrgID:          "org-moxie",
+			Message:        msg,
+			Channel:        ChannelSMS,
+		}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	got := lastLLMContext(ts)
+	if !strings.Contains(got, "MUST ask for their full name") {
+		t.Fatalf("expected qualification to resume on new booking intent:\n%s", got)
+	}
+	if !strings.Contains(got, "no longer applies") {
+		t.Fatalf("expected the existing-appointment guardrail lifted:\n%s", got)
+	}
+}
+
+func TestStartConversation_ExistingAppointmentSkipsChecklist(t *testing.T) {
+	ts := setupService(t, withLLMResponses("Happy to help - what's your question?"), withMoxieBotoxClinic("org-moxie"))
+	startConv(t, ts, "conv-existing-start", "org-moxie", "Hi, can I work out after my botox today?")
+
+	got := lastLLMContext(ts)
+	if strings.Contains(got, "MUST ask for their full name") {
+		t.Fatalf("qualification guardrail injected on first message:\n%s", got)
+	}
+	if !strings.Contains(got, "already has an appointment") {
+		t.Fatalf("expected existing-appointment guardrail on first message:\n%s", got)
+	}
+}
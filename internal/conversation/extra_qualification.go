@@ -0,0 +1,265 @@
+package conversation
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/clinic"
+	"github.com/wolfman30/medspa-ai-platform/internal/leads"
+)
+
+// extraQualificationReaskPrefix opens the single repeat of an extra
+// qualification question, sent when the first answer matched no allowed value.
+const extraQualificationReaskPrefix = "Sorry, I didn't quite catch that. "
+
+// resolveExtraQualification works out the answer to the clinic's extra
+// qualification for service from the conversation.
+//
+// Returns:
+//   - answer, "" — the patient answered (or volunteered it earlier)
+//   - "", question — the question to send next
+//   - "", "" — no extra qualification is configured, or it was already
+//     asked twice without a usable reply and booking should go ahead
+//
+// An answer that matches no allowed value is re-asked once, listing the
+// options; the reply to the re-ask is taken as written.
+func resolveExtraQualification(history []ChatMessage, cfg *clinic.Config, service string) (answer, question string) {
+	q := cfg.ExtraQualificationFor(service)
+	if q == nil {
+		return "", ""
+	}
+
+	asked := 0
+	replying := false
+	for _, msg := range history {
+		switch {
+		case msg.Role == ChatRoleAssistant:
+			replying = strings.Contains(msg.Content, q.Question)
+			if replying {
+				asked++
+			}
+		case msg.FromPatient():
+			if matched := matchExtraQualificationAnswer(q, msg.Content, replying); matched != "" {
+				answer = matched
+			} else if replying && asked >= 2 && answer == "" {
+				answer = strings.TrimSpace(msg.Content)
+			}
+			replying = false
+		}
+	}
+
+	switch {
+	case answer != "":
+		return answer, ""
+	case asked == 0:
+		return "", q.Question
+	case asked == 1:
+		reask := extraQualificationReaskPrefix + q.Question
+		if len(q.AllowedAnswers) > 0 {
+			reask += " Options: " + strings.Join(q.AllowedAnswers, ", ") + "."
+		}
+		return "", reask
+	default:
+		return "", ""
+	}
+}
+
+// pendingExtraQualificationQuestion returns the extra qualification question
+// to ask when it is the only thing keeping the conversation from fetching
+// availability, or "" otherwise.
+func pendingExtraQualificationQuestion(history []ChatMessage, cfg *clinic.Config) string {
+	prefs, ok := standardQualificationsMet(history, cfg)
+	if !ok {
+		return ""
+	}
+	_, question := resolveExtraQualification(history, cfg, prefs.ServiceInterest)
+	return question
+}
+
+// matchExtraQualificationAnswer returns the allowed answers text mentions,
+// joined with ", " when it names several ("face and underarms"). replying is
+// set for the patient's reply to the question itself: only then are
+// misspellings tolerated, and any reply is accepted when the question has
+// no allowed answers.
+func matchExtraQualificationAnswer(q *clinic.ExtraQualification, text string, replying bool) string {
+	if len(q.AllowedAnswers) == 0 {
+		if replying {
+			return strings.TrimSpace(text)
+		}
+		return ""
+	}
+
+	words := answerWords(text)
+	var matched []string
+	for _, allowed := range q.AllowedAnswers {
+		candidates := append([]string{allowed}, extraQualificationHints(q, allowed)...)
+		for _, candidate := range candidates {
+			if containsPhrase(words, answerWords(candidate), replying) {
+				matched = append(matched, allowed)
+				break
+			}
+		}
+	}
+	return strings.Join(matched, ", ")
+}
+
+// extraQualificationHints returns the configured alternate words for an
+// allowed answer, matching the hint key case-insensitively.
+func extraQualificationHints(q *clinic.ExtraQualification, allowed string) []string {
+	if hints, ok := q.Hints[allowed]; ok {
+		return hints
+	}
+	for key, hints := range q.Hints {
+		if strings.EqualFold(strings.TrimSpace(key), strings.TrimSpace(allowed)) {
+			return hints
+		}
+	}
+	return nil
+}
+
+// answerWords lowercases text into words, dropping a plural "s" so "legs"
+// matches "leg".
+func answerWords(text string) []string {
+	fields := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for i, w := range fields {
+		if len(w) > 3 && strings.HasSuffix(w, "s") && !strings.HasSuffix(w, "ss") {
+			fields[i] = strings.TrimSuffix(w, "s")
+		}
+	}
+	return fields
+}
+
+// containsPhrase reports whether phrase appears as consecutive words in
+// words. With fuzzy set, words of four or more letters may differ by one
+// edit ("undrarms").
+func containsPhrase(words, phrase []string, fuzzy bool) bool {
+	if len(phrase) == 0 || len(phrase) > len(words) {
+		return false
+	}
+	for start := 0; start+len(phrase) <= len(words); start++ {
+		match := true
+		for i, want := range phrase {
+			got := words[start+i]
+			if got == want {
+				continue
+			}
+			if fuzzy && len(want) >= 4 && len(got) >= 4 && withinOneEdit(got, want) {
+				continue
+			}
+			match = false
+			break
+		}
+		if match {
+			return true
+		}
+	}
+	return false
+}
+
+// withinOneEdit reports whether a and b differ by at most one insertion,
+// deletion, or substitution.
+func withinOneEdit(a, b string) bool {
+	if len(a) > len(b) {
+		a, b = b, a
+	}
+	if len(b)-len(a) > 1 {
+		return false
+	}
+	i, j, edits := 0, 0, 0
+	for i < len(a) && j < len(b) {
+		if a[i] == b[j] {
+			i++
+			j++
+			continue
+		}
+		edits++
+		if edits > 1 {
+			return false
+		}
+		if len(a) == len(b) {
+			i++
+		}
+		j++
+	}
+	return edits+(len(b)-j)+(len(a)-i) <= 1
+}
+
+// extraQualificationNote formats a lead's extra qualification answers for
+// the booking's appointment note, e.g. "Area: Underarms".
+func extraQualificationNote(answers map[string]string) string {
+	keys := make([]string, 0, len(answers))
+	for key, answer := range answers {
+		if strings.TrimSpace(key) != "" && strings.TrimSpace(answer) != "" {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	parts := make([]string, 0, len(keys))
+	for _, key := range keys {
+		label := strings.ReplaceAll(strings.TrimSpace(key), "_", " ")
+		label = strings.ToUpper(label[:1]) + label[1:]
+		parts = append(parts, fmt.Sprintf("%s: %s", label, strings.TrimSpace(answers[key])))
+	}
+	return strings.Join(parts, "; ")
+}
+
+// handleExtraQualification asks the service's extra qualification question
+// when it is still unanswered, returning the *Response to send. Once answered,
+// the answer is stored on prefs and merged into the lead, and nil is returned.
+func (s *LLMService) handleExtraQualification(
+	ctx context.Context,
+	cfg *clinic.Config,
+	prefs *leads.SchedulingPreferences,
+	history []ChatMessage,
+	conversationID, leadID string,
+) *Response {
+	q := cfg.ExtraQualificationFor(prefs.ServiceInterest)
+	if q == nil {
+		return nil
+	}
+	answer, question := resolveExtraQualification(history, cfg, prefs.ServiceInterest)
+	if question != "" {
+		s.log(ctx).Info("extra qualification needed",
+			"conversation_id", conversationID,
+			"service", prefs.ServiceInterest,
+			"key", q.Key,
+			"reask", strings.HasPrefix(question, extraQualificationReaskPrefix),
+		)
+		// Replace the last assistant message with the question.
+		for i := len(history) - 1; i >= 0; i-- {
+			if history[i].Role == ChatRoleAssistant {
+				history[i].Content = question
+				break
+			}
+		}
+		if err := s.history.Save(ctx, conversationID, history); err != nil {
+			s.log(ctx).Warn("failed to save history after extra qualification question", "error", err)
+		}
+		return &Response{
+			ConversationID: conversationID,
+			Message:        question,
+			Timestamp:      time.Now().UTC(),
+		}
+	}
+	if answer == "" {
+		return nil
+	}
+
+	if prefs.ExtraQualifications == nil {
+		prefs.ExtraQualifications = make(map[string]string)
+	}
+	prefs.ExtraQualifications[q.Key] = answer
+	if leadID != "" && s.leadsRepo != nil {
+		patch := leads.SchedulingPreferences{ExtraQualifications: map[string]string{q.Key: answer}}
+		if err := s.leadsRepo.MergePreferences(ctx, leadID, patch); err != nil {
+			s.log(ctx).Warn("failed to save extra qualification", "lead_id", leadID, "key", q.Key, "error", err)
+		}
+	}
+	return nil
+}
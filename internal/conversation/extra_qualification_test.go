@@ -0,0 +1,155 @@
+package conversation
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/clinic"
+	"github.com/wolfman30/medspa-ai-platform/internal/leads"
+	"github.com/wolfman30/medspa-ai-platform/internal/tenancy"
+	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
+)
+
+const laserAreaQuestion = "Which area would you like treated?"
+
+func laserAreaConfig() *clinic.Config {
+	return &clinic.Config{
+		ExtraQualifications: map[string]clinic.ExtraQualification{
+			"laser hair removal": {
+				Key:            "area",
+				Question:       laserAreaQuestion,
+				AllowedAnswers: []string{"Face", "Underarms", "Legs", "Bikini"},
+				Hints:          map[string][]string{"Underarms": {"armpits", "pits"}},
+			},
+		},
+	}
+}
+
+// qualifiedHistory is a conversation with the four standard qualifications in.
+func qualifiedHistory(service string) []ChatMessage {
+	return []ChatMessage{
+		{Role: ChatRoleUser, Content: "Hi, I'm interested in " + service},
+		{Role: ChatRoleAssistant, Content: "Great choice! May I have your full name?"},
+		{Role: ChatRoleUser, Content: "Sarah Johnson"},
+		{Role: ChatRoleAssistant, Content: "Thanks Sarah! Have you visited us before, or would this be your first time?"},
+		{Role: ChatRoleUser, Content: "I'm a new patient"},
+		{Role: ChatRoleAssistant, Content: "What days and times work best for you?"},
+		{Role: ChatRoleUser, Content: "Weekday mornings"},
+	}
+}
+
+func withTurns(history []ChatMessage, turns ...string) []ChatMessage {
+	out := append([]ChatMessage(nil), history...)
+	for i, content := range turns {
+		role := ChatRoleAssistant
+		if i%2 == 1 {
+			role = ChatRoleUser
+		}
+		out = append(out, ChatMessage{Role: role, Content: content})
+	}
+	return out
+}
+
+func TestResolveExtraQualification(t *testing.T) {
+	cfg := laserAreaConfig()
+	base := qualifiedHistory("laser hair removal")
+
+	t.Run("asks once the standard qualifications are in", func(t *testing.T) {
+		answer, question := resolveExtraQualification(base, cfg, "laser hair removal")
+		if answer != "" || question != laserAreaQuestion {
+			t.Fatalf("got answer %q question %q", answer, question)
+		}
+		if ShouldFetchAvailabilityWithConfig(base, nil, cfg) {
+			t.Fatal("availability must wait for the area")
+		}
+		if got := pendingExtraQualificationQuestion(base, cfg); got != laserAreaQuestion {
+			t.Fatalf("pending question = %q", got)
+		}
+	})
+
+	for name, tc := range map[string]struct {
+		reply string
+		want  string
+	}{
+		"allowed answer":  {"My underarms please", "Underarms"},
+		"misspelled":      {"undrarms", "Underarms"},
+		"hint":            {"just the armpits", "Underarms"},
+		"plural and case": {"LEG", "Legs"},
+		"several areas":   {"face and legs", "Face, Legs"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			history := withTurns(base, laserAreaQuestion, tc.reply)
+			answer, question := resolveExtraQualification(history, cfg, "laser hair removal")
+			if answer != tc.want || question != "" {
+				t.Fatalf("got answer %q question %q, want %q", answer, question, tc.want)
+			}
+			if !ShouldFetchAvailabilityWithConfig(history, nil, cfg) {
+				t.Fatal("expected availability once the area is known")
+			}
+		})
+	}
+
+	t.Run("unmatched answer is re-asked once", func(t *testing.T) {
+		history := withTurns(base, laserAreaQuestion, "not sure yet")
+		answer, question := resolveExtraQualification(history, cfg, "laser hair removal")
+		if answer != "" || !strings.HasPrefix(question, extraQualificationReaskPrefix) ||
+			!strings.Contains(question, laserAreaQuestion) || !strings.Contains(question, "Face, Underarms, Legs, Bikini") {
+			t.Fatalf("got answer %q question %q", answer, question)
+		}
+
+		history = withTurns(history, question, "whatever is most popular")
+		answer, question = resolveExtraQualification(history, cfg, "laser hair removal")
+		if answer != "whatever is most popular" || question != "" {
+			t.Fatalf("after the re-ask got answer %q question %q", answer, question)
+		}
+	})
+
+	t.Run("volunteered up front", func(t *testing.T) {
+		history := qualifiedHistory("laser hair removal on my legs")
+		answer, question := resolveExtraQualification(history, cfg, "laser hair removal")
+		if answer != "Legs" || question != "" {
+			t.Fatalf("got answer %q question %q", answer, question)
+		}
+	})
+
+	t.Run("services without extra qualifications are unaffected", func(t *testing.T) {
+		history := qualifiedHistory("Botox")
+		answer, question := resolveExtraQualification(history, cfg, "Botox")
+		if answer != "" || question != "" {
+			t.Fatalf("got answer %q question %q", answer, question)
+		}
+		if !ShouldFetchAvailabilityWithConfig(history, nil, cfg) {
+			t.Fatal("Botox should fetch availability without an extra question")
+		}
+		if got := pendingExtraQualificationQuestion(history, cfg); got != "" {
+			t.Fatalf("pending question = %q", got)
+		}
+	})
+}
+
+func TestHandleExtraQualification_SavesAnswerForBookingNote(t *testing.T) {
+	ctx := context.Background()
+	repo := leads.NewInMemoryRepository()
+	lead, err := repo.Create(ctx, &leads.CreateLeadRequest{OrgID: "org-1", Name: "Sarah Johnson", Phone: "+15550001111", Source: "sms"})
+	if err != nil {
+		t.Fatalf("create lead: %v", err)
+	}
+	svc := &LLMService{leadsRepo: repo, logger: logging.Default()}
+
+	history := withTurns(qualifiedHistory("laser hair removal"), laserAreaQuestion, "underarms", "Perfect, let me check.")
+	prefs := leads.SchedulingPreferences{ServiceInterest: "laser hair removal"}
+	if resp := svc.handleExtraQualification(ctx, laserAreaConfig(), &prefs, history, "conv-1", lead.ID); resp != nil {
+		t.Fatalf("unexpected question %q", resp.Message)
+	}
+	if prefs.ExtraQualifications["area"] != "Underarms" {
+		t.Fatalf("prefs = %+v", prefs.ExtraQualifications)
+	}
+	saved, err := repo.GetByID(ctx, tenancy.ForOrg("org-1"), lead.ID)
+	if err != nil {
+		t.Fatalf("get lead: %v", err)
+	}
+	if got := extraQualificationNote(saved.ExtraQualifications); got != "Area: Underarms" {
+		t.Fatalf("booking note = %q", got)
+	}
+}
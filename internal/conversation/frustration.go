@@ -0,0 +1,327 @@
+package conversation
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Frustration signals recorded on a scored message.
+const (
+	FrustrationSignalRepeatedInfo = "repeated_info"
+	FrustrationSignalProfanity    = "profanity"
+	FrustrationSignalAllCaps      = "all_caps"
+	FrustrationSignalComplaint    = "complaint"
+	FrustrationSignalClassifier   = "llm_classifier"
+)
+
+// Signal weights. With the default threshold a patient has to restate an
+// answer twice, or combine a complaint with another signal, before the
+// conversation is handed to staff.
+const (
+	frustrationWeightRepeatedInfo = 2.0
+	frustrationWeightProfanity    = 1.5
+	frustrationWeightAllCaps      = 1.0
+	frustrationWeightComplaint    = 2.0
+	frustrationWeightClassifier   = 2.0
+
+	// DefaultFrustrationThreshold is the accumulated score that escalates a
+	// conversation.
+	DefaultFrustrationThreshold = 4.0
+
+	frustrationClassifierTimeout = 3 * time.Second
+	frustrationHistoryLimit      = 20
+)
+
+// frustrationRepeatPattern matches a patient pointing out they already gave
+// an answer.
+var frustrationRepeatPattern = regexp.MustCompile(`(?i)\b(` +
+	`i\s+(already|just)\s+(told|said|gave|answered|sent|mentioned)` +
+	`|as\s+i\s+(said|mentioned)` +
+	`|(told|asked)\s+you\s+(that\s+)?(already|twice|before)` +
+	`|(second|third|2nd|3rd)\s+time` +
+	`|how\s+many\s+times` +
+	`)\b`)
+
+var frustrationProfanityPattern = regexp.MustCompile(`(?i)\b(` +
+	`f+u+c+k\w*|sh[i1]t\w*|bullshit|damn\w*|crap\w*|wtf|stfu|omfg|pissed|ass(hole)?|hell` +
+	`)\b`)
+
+var frustrationComplaintPattern = regexp.MustCompile(`(?i)\b(` +
+	`ridiculous|frustrat(ed|ing)|annoy(ed|ing)|useless|unbelievable` +
+	`|waste\s+of\s+(my\s+)?time` +
+	`|(not|isn'?t)\s+(helping|helpful|listening)` +
+	`|makes\s+no\s+sense` +
+	`|(stupid|dumb)\s+(bot|robot|ai|machine)` +
+	`|(talk|speak)\s+(to|with)\s+(a|an)\s+(real\s+|actual\s+)?(person|human)` +
+	`|(a\s+)?real\s+person` +
+	`|are\s+you\s+(a\s+)?(bot|robot|machine)` +
+	`)\b`)
+
+// frustrationStopwords are dropped before comparing a message with earlier
+// answers so only the facts in it are compared.
+var frustrationStopwords = map[string]bool{
+	"the": true, "and": true, "you": true, "your": true, "for": true, "that": true, "this": true,
+	"with": true, "have": true, "was": true, "are": true, "not": true, "but": true, "what": true,
+	"yes": true, "just": true, "its": true, "it's": true, "i'm": true, "said": true, "told": true,
+	"already": true, "again": true, "like": true, "would": true, "please": true, "thanks": true,
+	"okay": true, "name": true,
+}
+
+// FrustrationScore is the rule-based score for one inbound message.
+type FrustrationScore struct {
+	Score   float64  `json:"score"`
+	Signals []string `json:"signals,omitempty"`
+}
+
+func (s *FrustrationScore) add(signal string, weight float64) {
+	s.Score += weight
+	s.Signals = append(s.Signals, signal)
+}
+
+// ScoreFrustration scores an inbound message for frustration signals:
+// restating something already answered, profanity, shouting in all caps,
+// and explicit complaints. history is the conversation transcript, oldest
+// first; the current message may already be its last entry.
+func ScoreFrustration(message string, history []SMSTranscriptMessage) FrustrationScore {
+	var score FrustrationScore
+	message = strings.TrimSpace(message)
+	if message == "" {
+		return score
+	}
+	if frustrationRepeatPattern.MatchString(message) || repeatsEarlierAnswer(message, history) {
+		score.add(FrustrationSignalRepeatedInfo, frustrationWeightRepeatedInfo)
+	}
+	if frustrationProfanityPattern.MatchString(message) {
+		score.add(FrustrationSignalProfanity, frustrationWeightProfanity)
+	}
+	if isShouting(message) {
+		score.add(FrustrationSignalAllCaps, frustrationWeightAllCaps)
+	}
+	if frustrationComplaintPattern.MatchString(message) {
+		score.add(FrustrationSignalComplaint, frustrationWeightComplaint)
+	}
+	return score
+}
+
+// repeatsEarlierAnswer reports whether the AI's latest message asked a
+// question and the patient answered with facts they had already given
+// before it.
+func repeatsEarlierAnswer(message string, history []SMSTranscriptMessage) bool {
+	lastAssistant := -1
+	for i := len(history) - 1; i >= 0; i-- {
+		if history[i].Role == ChatRoleAssistant {
+			lastAssistant = i
+			break
+		}
+	}
+	if lastAssistant < 0 || !strings.Contains(history[lastAssistant].Body, "?") {
+		return false
+	}
+
+	current := frustrationFacts(message)
+	if len(current) == 0 {
+		return false
+	}
+	for _, earlier := range history[:lastAssistant] {
+		if !earlier.FromPatient() {
+			continue
+		}
+		facts := frustrationFacts(earlier.Body)
+		shared, digits := 0, false
+		for fact := range current {
+			if facts[fact] {
+				shared++
+				digits = digits || hasDigits(fact, 4)
+			}
+		}
+		// Two or more shared words, or a single number such as a phone or
+		// birth year, counts as the same answer.
+		if (shared >= 2 || digits) && float64(shared)/float64(len(current)) >= 0.6 {
+			return true
+		}
+	}
+	return false
+}
+
+// frustrationFacts returns the lowercased content words of a message.
+func frustrationFacts(message string) map[string]bool {
+	facts := map[string]bool{}
+	for _, word := range strings.FieldsFunc(strings.ToLower(message), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '@' && r != '.' && r != '\''
+	}) {
+		word = strings.Trim(word, ".'")
+		if word == "" || frustrationStopwords[word] {
+			continue
+		}
+		if len(word) < 3 && !hasDigits(word, 1) {
+			continue
+		}
+		facts[word] = true
+	}
+	return facts
+}
+
+func hasDigits(s string, min int) bool {
+	n := 0
+	for _, r := range s {
+		if unicode.IsDigit(r) {
+			n++
+		}
+	}
+	return n >= min
+}
+
+// isShouting reports whether a message is mostly capital letters and long
+// enough that it isn't just an acronym or "OK".
+func isShouting(message string) bool {
+	letters, upper := 0, 0
+	for _, r := range message {
+		if !unicode.IsLetter(r) {
+			continue
+		}
+		letters++
+		if unicode.IsUpper(r) {
+			upper++
+		}
+	}
+	return letters >= 8 && float64(upper)/float64(letters) >= 0.8
+}
+
+// FrustrationClassifier rates how frustrated a patient sounds, from 0 (calm)
+// to 1 (clearly frustrated).
+type FrustrationClassifier interface {
+	ClassifyFrustration(ctx context.Context, message string) (float64, error)
+}
+
+const frustrationClassifierPrompt = `A patient is texting a medspa's AI booking assistant. Rate how frustrated or upset the patient sounds in this message, from 0.0 (calm or happy) to 1.0 (clearly frustrated, angry, or fed up). Respond with JSON only.
+
+Message: %s
+
+Respond with: {"frustration": <number>}`
+
+// LLMFrustrationClassifier rates frustration with a small LLM prompt.
+type LLMFrustrationClassifier struct {
+	client LLMClient
+	model  string
+}
+
+// NewLLMFrustrationClassifier creates an LLM-backed frustration classifier.
+func NewLLMFrustrationClassifier(client LLMClient, model string) *LLMFrustrationClassifier {
+	return &LLMFrustrationClassifier{client: client, model: model}
+}
+
+// ClassifyFrustration returns the model's frustration rating for message.
+func (c *LLMFrustrationClassifier) ClassifyFrustration(ctx context.Context, message string) (float64, error) {
+	message = strings.TrimSpace(message)
+	if message == "" {
+		return 0, nil
+	}
+	resp, err := c.client.Complete(ctx, LLMRequest{
+		Model:       c.model,
+		Messages:    []ChatMessage{{Role: ChatRoleUser, Content: strings.Replace(frustrationClassifierPrompt, "%s", message, 1)}},
+		MaxTokens:   20,
+		Temperature: 0,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("conversation: classify frustration: %w", err)
+	}
+
+	content := strings.TrimSpace(resp.Text)
+	if start, end := strings.Index(content, "{"), strings.LastIndex(content, "}"); start >= 0 && end > start {
+		content = content[start : end+1]
+	}
+	var result struct {
+		Frustration float64 `json:"frustration"`
+	}
+	if err := json.Unmarshal([]byte(content), &result); err != nil {
+		return 0, fmt.Errorf("conversation: parse frustration rating: %w", err)
+	}
+	return min(max(result.Frustration, 0), 1), nil
+}
+
+// FrustrationResult is the outcome of observing one inbound message.
+type FrustrationResult struct {
+	// Message is this message's score.
+	Message FrustrationScore
+	// Total is the conversation's accumulated score.
+	Total float64
+	// Escalate is true only for the message that first pushes Total over
+	// the threshold.
+	Escalate bool
+}
+
+// FrustrationTracker accumulates frustration scores per conversation in
+// Redis and decides when to hand the conversation to staff. Escalation is
+// claimed atomically so it fires once even across worker replicas.
+type FrustrationTracker struct {
+	client     redis.Cmdable
+	threshold  float64
+	ttl        time.Duration
+	classifier FrustrationClassifier
+}
+
+// NewFrustrationTracker returns a tracker that escalates once a
+// conversation's score reaches threshold. It returns nil (tracking
+// disabled) without Redis or a positive threshold.
+func NewFrustrationTracker(client *redis.Client, threshold float64) *FrustrationTracker {
+	if client == nil || threshold <= 0 {
+		return nil
+	}
+	return &FrustrationTracker{client: client, threshold: threshold, ttl: conversationTTL}
+}
+
+// WithClassifier adds an LLM rating on top of the rule-based signals.
+func (t *FrustrationTracker) WithClassifier(classifier FrustrationClassifier) *FrustrationTracker {
+	if t != nil {
+		t.classifier = classifier
+	}
+	return t
+}
+
+// Observe scores an inbound message and adds it to the conversation's total.
+func (t *FrustrationTracker) Observe(ctx context.Context, conversationID, message string, history []SMSTranscriptMessage) (FrustrationResult, error) {
+	result := FrustrationResult{Message: ScoreFrustration(message, history)}
+	if t.classifier != nil {
+		classifyCtx, cancel := context.WithTimeout(ctx, frustrationClassifierTimeout)
+		rating, err := t.classifier.ClassifyFrustration(classifyCtx, message)
+		cancel()
+		// A calm-to-neutral rating adds nothing; classifier errors fall back
+		// to the rule-based score alone.
+		if err == nil && rating >= 0.5 {
+			result.Message.add(FrustrationSignalClassifier, rating*frustrationWeightClassifier)
+		}
+	}
+	if result.Message.Score <= 0 {
+		return result, nil
+	}
+
+	key := frustrationKey(conversationID)
+	pipe := t.client.TxPipeline()
+	total := pipe.HIncrByFloat(ctx, key, "score", result.Message.Score)
+	pipe.Expire(ctx, key, t.ttl)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return result, fmt.Errorf("conversation: record frustration score: %w", err)
+	}
+	result.Total = total.Val()
+	if result.Total < t.threshold {
+		return result, nil
+	}
+
+	claimed, err := t.client.HSetNX(ctx, key, "escalated", time.Now().UTC().Format(time.RFC3339)).Result()
+	if err != nil {
+		return result, fmt.Errorf("conversation: claim frustration escalation: %w", err)
+	}
+	result.Escalate = claimed
+	return result, nil
+}
+
+func frustrationKey(conversationID string) string {
+	return "frustration:" + conversationID
+}
@@ -0,0 +1,187 @@
+package conversation
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/notify"
+	"github.com/wolfman30/medspa-ai-platform/internal/tenancy"
+	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
+)
+
+func TestScoreFrustration_RepeatedInfo(t *testing.T) {
+	history := []SMSTranscriptMessage{
+		{Role: ChatRoleUser, Body: "Hi, I'm Sarah Jones and I'd like Botox"},
+		{Role: ChatRoleAssistant, Body: "Great! Are you a new patient?"},
+		{Role: ChatRoleUser, Body: "Yes"},
+		{Role: ChatRoleAssistant, Body: "Thanks! Could I get your full name?"},
+		// The inbound text is usually already on the transcript.
+		{Role: ChatRoleUser, Body: "Sarah Jones"},
+	}
+	score := ScoreFrustration("Sarah Jones", history)
+	if !slices.Contains(score.Signals, FrustrationSignalRepeatedInfo) {
+		t.Fatalf("expected repeated_info, got %+v", score)
+	}
+
+	// Answering a question for the first time isn't a repeat.
+	first := []SMSTranscriptMessage{
+		{Role: ChatRoleUser, Body: "I'd like Botox"},
+		{Role: ChatRoleAssistant, Body: "Great! Could I get your full name?"},
+	}
+	if got := ScoreFrustration("Sarah Jones", first); got.Score != 0 {
+		t.Fatalf("expected no frustration on a first answer, got %+v", got)
+	}
+
+	// Repeating yourself unprompted isn't either.
+	statement := []SMSTranscriptMessage{
+		{Role: ChatRoleUser, Body: "Sarah Jones"},
+		{Role: ChatRoleAssistant, Body: "Thanks Sarah, checking the schedule now."},
+	}
+	if got := ScoreFrustration("Sarah Jones", statement); got.Score != 0 {
+		t.Fatalf("expected no frustration without a question, got %+v", got)
+	}
+}
+
+func TestScoreFrustration_Signals(t *testing.T) {
+	cases := []struct {
+		message string
+		want    []string
+	}{
+		{"I already told you that", []string{FrustrationSignalRepeatedInfo}},
+		{"what the hell", []string{FrustrationSignalProfanity}},
+		{"WHY IS THIS SO HARD", []string{FrustrationSignalAllCaps}},
+		{"This is ridiculous, can I talk to a real person", []string{FrustrationSignalComplaint}},
+		{"Tuesday at 3pm works for me", nil},
+		{"Hello! Can I book Botox?", nil},
+		{"OK", nil},
+		{"Thanks so much, see you then!", nil},
+	}
+	for _, tc := range cases {
+		got := ScoreFrustration(tc.message, nil)
+		if !slices.Equal(got.Signals, tc.want) {
+			t.Errorf("ScoreFrustration(%q) signals = %v, want %v", tc.message, got.Signals, tc.want)
+		}
+	}
+}
+
+type stubFrustrationClassifier float64
+
+func (s stubFrustrationClassifier) ClassifyFrustration(ctx context.Context, message string) (float64, error) {
+	return float64(s), nil
+}
+
+func TestFrustrationTracker_Classifier(t *testing.T) {
+	mr := miniredis.RunT(t)
+	tracker := NewFrustrationTracker(redis.NewClient(&redis.Options{Addr: mr.Addr()}), DefaultFrustrationThreshold).
+		WithClassifier(stubFrustrationClassifier(0.9))
+
+	result, err := tracker.Observe(context.Background(), "conv-1", "fine whatever", nil)
+	if err != nil {
+		t.Fatalf("Observe: %v", err)
+	}
+	if !slices.Equal(result.Message.Signals, []string{FrustrationSignalClassifier}) || result.Total != 1.8 {
+		t.Fatalf("unexpected classifier result: %+v", result)
+	}
+}
+
+type recordingFrustrationAuditor struct {
+	scores []float64
+}
+
+func (r *recordingFrustrationAuditor) LogFrustrationEscalated(ctx context.Context, orgID, conversationID, leadID string, score float64, signals []string) error {
+	r.scores = append(r.scores, score)
+	return nil
+}
+
+func newFrustrationWorker(t *testing.T, service Service, messenger ReplyMessenger, tasks CallbackTaskStore, notifier CallbackNotifier, auditor FrustrationAuditor) (*Worker, *miniredis.Miniredis) {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	tracker := NewFrustrationTracker(redis.NewClient(&redis.Options{Addr: mr.Addr()}), DefaultFrustrationThreshold)
+	worker := NewWorker(service, newScriptedQueue(), &stubJobUpdater{}, messenger, nil, logging.Default(),
+		WithCallbackTasks(tasks, notifier),
+		WithFrustrationTracking(tracker, auditor))
+	return worker, mr
+}
+
+func TestWorkerFrustration_EscalatesOnce(t *testing.T) {
+	service := &recordingService{}
+	messenger := &recordingMessenger{}
+	tasks := &memoryCallbackTasks{}
+	notifier := &recordingCallbackNotifier{}
+	auditor := &recordingFrustrationAuditor{}
+	worker, _ := newFrustrationWorker(t, service, messenger, tasks, notifier, auditor)
+
+	// Caps plus a complaint scores 3, under the threshold of 4.
+	sendWorkerSMS(t, worker, "job-1", "THIS IS RIDICULOUS")
+	if service.messageCount() != 1 || len(tasks.tasks) != 0 {
+		t.Fatalf("expected the AI to keep going below the threshold")
+	}
+
+	sendWorkerSMS(t, worker, "job-2", "I already told you, this is so frustrating")
+	if service.messageCount() != 1 {
+		t.Fatalf("expected the LLM to be skipped on escalation, got %d calls", service.messageCount())
+	}
+	if len(tasks.tasks) != 1 || tasks.tasks[0].Reason != CallbackTaskReasonFrustration {
+		t.Fatalf("expected one frustration task, got %+v", tasks.tasks)
+	}
+	if len(notifier.requests) != 1 || notifier.requests[0].Reason != notify.CallbackReasonFrustration {
+		t.Fatalf("expected a frustration notification, got %+v", notifier.requests)
+	}
+	if len(auditor.scores) != 1 || auditor.scores[0] != 7 {
+		t.Fatalf("expected one audit record with score 7, got %v", auditor.scores)
+	}
+	replies := messenger.allReplies()
+	if len(replies) != 1 || !strings.Contains(replies[0].Body, "sorry this has been frustrating") {
+		t.Fatalf("expected one handoff reply, got %+v", replies)
+	}
+
+	// The handoff stays paused even when the patient sends booking details.
+	sendWorkerSMS(t, worker, "job-3", "can I book Botox Thursday after 4pm?")
+	if service.messageCount() != 1 || len(messenger.allReplies()) != 1 {
+		t.Fatalf("expected the conversation to stay paused for staff")
+	}
+
+	// Once staff close the task, more frustration doesn't hand off again.
+	if _, err := tasks.Resolve(context.Background(), tenancy.ForOrg("org-1"), tasks.tasks[0].ID, CallbackTaskStatusDone); err != nil {
+		t.Fatalf("resolve: %v", err)
+	}
+	sendWorkerSMS(t, worker, "job-4", "ugh this is useless")
+	if service.messageCount() != 2 {
+		t.Fatalf("expected the AI to answer after the task is done, got %d calls", service.messageCount())
+	}
+	if len(tasks.tasks) != 1 || len(notifier.requests) != 1 || len(auditor.scores) != 1 {
+		t.Fatalf("expected escalation to fire exactly once")
+	}
+}
+
+func TestWorkerFrustration_CalmConversationUnaffected(t *testing.T) {
+	service := &recordingService{}
+	messenger := &recordingMessenger{}
+	tasks := &memoryCallbackTasks{}
+	notifier := &recordingCallbackNotifier{}
+	worker, mr := newFrustrationWorker(t, service, messenger, tasks, notifier, nil)
+
+	for i, text := range []string{
+		"Hi! I'd like to book Botox",
+		"Sarah Jones",
+		"Yes, I'm a new patient",
+		"Tuesday afternoons work best",
+		"Great, thanks so much!",
+	} {
+		sendWorkerSMS(t, worker, "job-"+string(rune('a'+i)), text)
+	}
+	if service.messageCount() != 5 {
+		t.Fatalf("expected every message to reach the AI, got %d", service.messageCount())
+	}
+	if len(tasks.tasks) != 0 || len(notifier.requests) != 0 {
+		t.Fatalf("expected no handoff for a calm conversation")
+	}
+	if mr.Exists(frustrationKey("sms:org-1:12223334444")) {
+		t.Fatalf("expected no frustration score to be recorded")
+	}
+}
@@ -10,6 +10,8 @@ import (
 
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/wolfman30/medspa-ai-platform/internal/http/apierror"
 	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
 )
 
@@ -27,7 +29,11 @@ type Handler struct {
 	rag       RAGIngestor
 	service   Service
 	sms       *SMSTranscriptStore
+	callbacks CallbackTaskStore
 	logger    *logging.Logger
+
+	conversations ConversationLookup
+	refreshLocks  *redis.Client
 }
 
 // NewHandler creates a conversation handler.
@@ -51,6 +57,11 @@ func (h *Handler) SetSMSTranscriptStore(store *SMSTranscriptStore) {
 	h.sms = store
 }
 
+// SetCallbackTaskStore attaches the store behind the operator callback task endpoints.
+func (h *Handler) SetCallbackTaskStore(store CallbackTaskStore) {
+	h.callbacks = store
+}
+
 // Start handles POST /conversations/start.
 func (h *Handler) Start(w http.ResponseWriter, r *http.Request) {
 	var payload struct {
@@ -59,14 +70,14 @@ func (h *Handler) Start(w http.ResponseWriter, r *http.Request) {
 	}
 	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
 		h.logger.Error("failed to decode start request", "error", err)
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		apierror.Write(w, r, apierror.CodeValidationFailed, "Invalid request body")
 		return
 	}
 	req := payload.StartRequest
 	if strings.TrimSpace(payload.ScheduledFor) != "" {
 		when, err := time.Parse(time.RFC3339, payload.ScheduledFor)
 		if err != nil {
-			http.Error(w, "invalid scheduled_for format", http.StatusBadRequest)
+			apierror.Write(w, r, apierror.CodeValidationFailed, "invalid scheduled_for format")
 			return
 		}
 		if req.Metadata == nil {
@@ -80,7 +91,7 @@ func (h *Handler) Start(w http.ResponseWriter, r *http.Request) {
 
 	if err := h.enqueuer.EnqueueStart(r.Context(), jobID, req); err != nil {
 		h.logger.Error("failed to enqueue start conversation", "error", err)
-		http.Error(w, "Failed to schedule conversation start", http.StatusInternalServerError)
+		apierror.WriteError(w, r, err, apierror.CodeInternal, "Failed to schedule conversation start")
 		return
 	}
 
@@ -95,14 +106,14 @@ func (h *Handler) Message(w http.ResponseWriter, r *http.Request) {
 	}
 	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
 		h.logger.Error("failed to decode message request", "error", err)
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		apierror.Write(w, r, apierror.CodeValidationFailed, "Invalid request body")
 		return
 	}
 	req := payload.MessageRequest
 	if strings.TrimSpace(payload.ScheduledFor) != "" {
 		when, err := time.Parse(time.RFC3339, payload.ScheduledFor)
 		if err != nil {
-			http.Error(w, "invalid scheduled_for format", http.StatusBadRequest)
+			apierror.Write(w, r, apierror.CodeValidationFailed, "invalid scheduled_for format")
 			return
 		}
 		if req.Metadata == nil {
@@ -116,7 +127,7 @@ func (h *Handler) Message(w http.ResponseWriter, r *http.Request) {
 
 	if err := h.enqueuer.EnqueueMessage(r.Context(), jobID, req); err != nil {
 		h.logger.Error("failed to enqueue message", "error", err)
-		http.Error(w, "Failed to schedule message", http.StatusInternalServerError)
+		apierror.WriteError(w, r, err, apierror.CodeInternal, "Failed to schedule message")
 		return
 	}
 
@@ -127,18 +138,18 @@ func (h *Handler) Message(w http.ResponseWriter, r *http.Request) {
 func (h *Handler) JobStatus(w http.ResponseWriter, r *http.Request) {
 	jobID := chi.URLParam(r, "jobID")
 	if jobID == "" {
-		http.Error(w, "jobID is required", http.StatusBadRequest)
+		apierror.Write(w, r, apierror.CodeValidationFailed, "jobID is required")
 		return
 	}
 
 	job, err := h.jobs.GetJob(r.Context(), jobID)
 	if err != nil {
 		if errors.Is(err, ErrJobNotFound) {
-			http.Error(w, "job not found", http.StatusNotFound)
+			apierror.Write(w, r, apierror.CodeNotFound, "job not found")
 			return
 		}
 		h.logger.Error("failed to load job", "error", err, "job_id", jobID)
-		http.Error(w, "Failed to load job", http.StatusInternalServerError)
+		apierror.Write(w, r, apierror.CodeInternal, "Failed to load job")
 		return
 	}
 
@@ -0,0 +1,179 @@
+package conversation
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/http/apierror"
+	httpmiddleware "github.com/wolfman30/medspa-ai-platform/internal/http/middleware"
+)
+
+// assistedBookingEnqueuer is implemented by enqueuers that can schedule
+// staff-assisted booking jobs (the queue-backed Publisher).
+type assistedBookingEnqueuer interface {
+	EnqueueAssistedBooking(ctx context.Context, jobID string, req AssistedBookingRequest) error
+}
+
+// assistedBookingBody is the JSON body staff send. Omitting slot runs a
+// search; including one books it.
+type assistedBookingBody struct {
+	Phone          string        `json:"phone"`
+	Name           string        `json:"name"`
+	Email          string        `json:"email"`
+	Service        string        `json:"service"`
+	Provider       string        `json:"provider"`
+	PreferredDays  string        `json:"preferred_days"`
+	PreferredTimes string        `json:"preferred_times"`
+	Slot           *AssistedSlot `json:"slot"`
+}
+
+// assistedBookingStatus is the job view staff poll after either call.
+type assistedBookingStatus struct {
+	JobID   string             `json:"job_id"`
+	Status  JobStatus          `json:"status"`
+	Slots   []assistedSlotView `json:"slots,omitempty"`
+	Message string             `json:"message,omitempty"`
+	Error   string             `json:"error,omitempty"`
+}
+
+// assistedSlotView is a found slot; start and end can be sent back as the
+// booking's slot unchanged.
+type assistedSlotView struct {
+	Index int        `json:"index"`
+	Start time.Time  `json:"start"`
+	End   *time.Time `json:"end,omitempty"`
+	Label string     `json:"label"`
+}
+
+// AssistedBooking handles POST /admin/orgs/{orgID}/bookings/assisted.
+// Front-desk staff use it to book for a patient on the phone: a call without
+// a slot queues an availability search, and a call with one queues the
+// booking, which texts the patient the deposit link like an SMS booking.
+func (h *Handler) AssistedBooking(w http.ResponseWriter, r *http.Request) {
+	orgID := chi.URLParam(r, "orgID")
+	if orgID == "" {
+		apierror.Write(w, r, apierror.CodeValidationFailed, "orgID is required")
+		return
+	}
+	enqueuer, ok := h.enqueuer.(assistedBookingEnqueuer)
+	if !ok {
+		apierror.Write(w, r, apierror.CodeDependencyUnavailable, "assisted booking not configured")
+		return
+	}
+
+	var body assistedBookingBody
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		apierror.Write(w, r, apierror.CodeValidationFailed, "invalid JSON")
+		return
+	}
+	digits := canonicalPhoneDigits(body.Phone)
+	if len(digits) < 11 {
+		apierror.Write(w, r, apierror.CodeValidationFailed, "a valid phone is required")
+		return
+	}
+	service := strings.TrimSpace(body.Service)
+	if service == "" {
+		apierror.Write(w, r, apierror.CodeValidationFailed, "service is required")
+		return
+	}
+	if body.Slot != nil {
+		if !body.Slot.Start.After(time.Now()) {
+			apierror.Write(w, r, apierror.CodeValidationFailed, "slot.start must be in the future")
+			return
+		}
+		if body.Slot.End != nil && !body.Slot.End.After(body.Slot.Start) {
+			apierror.Write(w, r, apierror.CodeValidationFailed, "slot.end must be after slot.start")
+			return
+		}
+	}
+
+	req := AssistedBookingRequest{
+		OrgID:          orgID,
+		ConversationID: smsConversationID(orgID, digits),
+		Phone:          "+" + digits,
+		Name:           strings.TrimSpace(body.Name),
+		Email:          strings.TrimSpace(body.Email),
+		Service:        service,
+		Provider:       strings.TrimSpace(body.Provider),
+		PreferredDays:  strings.TrimSpace(body.PreferredDays),
+		PreferredTimes: strings.TrimSpace(body.PreferredTimes),
+		Slot:           body.Slot,
+		RequestedBy:    assistedBookingActor(r),
+	}
+	jobID := uuid.NewString()
+	if err := enqueuer.EnqueueAssistedBooking(r.Context(), jobID, req); err != nil {
+		h.logger.Error("failed to enqueue assisted booking", "error", err, "org_id", orgID)
+		apierror.WriteError(w, r, err, apierror.CodeInternal, "failed to schedule assisted booking")
+		return
+	}
+
+	h.logger.Info("assisted booking queued", "org_id", orgID, "job_id", jobID, "booking", req.Slot != nil, "requested_by", req.RequestedBy)
+	h.writeAccepted(w, jobID)
+}
+
+// AssistedBookingStatus handles GET /admin/orgs/{orgID}/bookings/assisted/{jobID}.
+// It returns the found slots for a search job, or the outcome of a booking.
+func (h *Handler) AssistedBookingStatus(w http.ResponseWriter, r *http.Request) {
+	orgID := chi.URLParam(r, "orgID")
+	jobID := chi.URLParam(r, "jobID")
+	if orgID == "" || jobID == "" {
+		apierror.Write(w, r, apierror.CodeValidationFailed, "orgID and jobID are required")
+		return
+	}
+
+	job, err := h.jobs.GetJob(r.Context(), jobID)
+	if err != nil {
+		if errors.Is(err, ErrJobNotFound) {
+			apierror.Write(w, r, apierror.CodeNotFound, "job not found")
+			return
+		}
+		h.logger.Error("failed to load assisted booking job", "error", err, "job_id", jobID)
+		apierror.Write(w, r, apierror.CodeInternal, "failed to load job")
+		return
+	}
+	if jobOrg, _, ok := parseConversationID(job.ConversationID); job.RequestType != jobTypeAssistedBooking || !ok || jobOrg != orgID {
+		apierror.Write(w, r, apierror.CodeNotFound, "job not found")
+		return
+	}
+
+	status := assistedBookingStatus{
+		JobID:  job.JobID,
+		Status: job.Status,
+		Error:  job.ErrorMessage,
+	}
+	if resp := job.Response; resp != nil {
+		status.Message = resp.Message
+		if tsr := resp.TimeSelectionResponse; tsr != nil {
+			for _, slot := range tsr.Slots {
+				view := assistedSlotView{Index: slot.Index, Start: slot.DateTime, Label: slot.TimeStr}
+				if !slot.EndDateTime.IsZero() {
+					end := slot.EndDateTime
+					view.End = &end
+				}
+				status.Slots = append(status.Slots, view)
+			}
+			if len(tsr.Slots) == 0 {
+				status.Message = tsr.SMSMessage
+			}
+		}
+	}
+	h.writeJSON(w, http.StatusOK, status)
+}
+
+// assistedBookingActor identifies the staff member making the request.
+func assistedBookingActor(r *http.Request) string {
+	if claims, ok := httpmiddleware.CognitoClaimsFromContext(r.Context()); ok && claims != nil {
+		return claims.Email
+	}
+	if claims, ok := httpmiddleware.AdminClaimsFromContext(r.Context()); ok {
+		return claims.Subject
+	}
+	return ""
+}
@@ -0,0 +1,95 @@
+package conversation
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/http/apierror"
+	"github.com/wolfman30/medspa-ai-platform/internal/tenancy"
+)
+
+// availabilityRefreshEnqueuer is implemented by enqueuers that can schedule
+// availability refresh jobs (the queue-backed Publisher).
+type availabilityRefreshEnqueuer interface {
+	EnqueueRefreshAvailability(ctx context.Context, jobID string, req RefreshAvailabilityRequest) error
+}
+
+// SetAvailabilityRefresh enables the support endpoint that re-sends
+// availability for a stuck conversation. locks holds the per-conversation
+// double-click guard.
+func (h *Handler) SetAvailabilityRefresh(conversations ConversationLookup, locks *redis.Client) {
+	h.conversations = conversations
+	h.refreshLocks = locks
+}
+
+// RefreshAvailability handles POST /admin/orgs/{orgID}/conversations/{conversationID}/refresh-availability.
+// It queues a job that re-runs the availability search with the lead's saved
+// preferences and texts the patient the fresh slots.
+func (h *Handler) RefreshAvailability(w http.ResponseWriter, r *http.Request) {
+	scope := tenancy.ScopeFromContext(r.Context())
+	orgID := scope.OrgID()
+	conversationID := chi.URLParam(r, "conversationID")
+	if decoded, err := url.PathUnescape(conversationID); err == nil {
+		conversationID = decoded
+	}
+	parsedOrgID, phone, ok := parseConversationID(conversationID)
+	if orgID == "" || !ok || parsedOrgID != orgID {
+		apierror.Write(w, r, apierror.CodeValidationFailed, "invalid conversation id")
+		return
+	}
+	enqueuer, ok := h.enqueuer.(availabilityRefreshEnqueuer)
+	if !ok || h.conversations == nil || h.refreshLocks == nil {
+		apierror.Write(w, r, apierror.CodeDependencyUnavailable, "availability refresh not configured")
+		return
+	}
+
+	ctx := r.Context()
+	conv, err := h.conversations.GetConversation(ctx, scope, conversationID)
+	if err != nil {
+		h.logger.Error("failed to load conversation for availability refresh", "error", err, "conversation_id", conversationID)
+		apierror.WriteError(w, r, err, apierror.CodeInternal, "failed to load conversation")
+		return
+	}
+	if conv == nil {
+		apierror.Write(w, r, apierror.CodeNotFound, "conversation not found")
+		return
+	}
+	if availabilityRefreshBlocked(conv.Status) {
+		apierror.Write(w, r, apierror.CodeConflict, "conversation already has a deposit or booking")
+		return
+	}
+
+	if err := acquireAvailabilityRefreshLock(ctx, h.refreshLocks, conversationID); err != nil {
+		if errors.Is(err, ErrAvailabilityRefreshInProgress) {
+			apierror.Write(w, r, apierror.CodeConflict, "availability refresh already in progress")
+			return
+		}
+		h.logger.Error("failed to lock availability refresh", "error", err, "conversation_id", conversationID)
+		apierror.Write(w, r, apierror.CodeInternal, "failed to refresh availability")
+		return
+	}
+
+	req := RefreshAvailabilityRequest{
+		OrgID:          orgID,
+		ConversationID: conversationID,
+		Phone:          phone,
+	}
+	if conv.LeadID != nil {
+		req.LeadID = conv.LeadID.String()
+	}
+	jobID := uuid.NewString()
+	if err := enqueuer.EnqueueRefreshAvailability(ctx, jobID, req); err != nil {
+		h.logger.Error("failed to enqueue availability refresh", "error", err, "conversation_id", conversationID)
+		apierror.WriteError(w, r, err, apierror.CodeInternal, "failed to schedule availability refresh")
+		return
+	}
+
+	h.logger.Info("availability refresh queued", "org_id", orgID, "conversation_id", conversationID, "job_id", jobID)
+	h.writeAccepted(w, jobID)
+}
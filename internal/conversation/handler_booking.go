@@ -7,7 +7,10 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/wolfman30/medspa-ai-platform/internal/http/apierror"
 	"github.com/wolfman30/medspa-ai-platform/internal/leads"
+	"github.com/wolfman30/medspa-ai-platform/internal/messaging/templates"
+	"github.com/wolfman30/medspa-ai-platform/internal/tenancy"
 	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
 )
 
@@ -54,11 +57,11 @@ func (h *BookingCallbackHandler) Handle(w http.ResponseWriter, r *http.Request)
 
 	var payload bookingCallbackPayload
 	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
-		http.Error(w, "invalid JSON", http.StatusBadRequest)
+		apierror.Write(w, r, apierror.CodeValidationFailed, "invalid JSON")
 		return
 	}
 	if payload.SessionID == "" {
-		http.Error(w, "sessionId is required", http.StatusBadRequest)
+		apierror.Write(w, r, apierror.CodeValidationFailed, "sessionId is required")
 		return
 	}
 
@@ -73,11 +76,11 @@ func (h *BookingCallbackHandler) Handle(w http.ResponseWriter, r *http.Request)
 	lead, err := h.leadsRepo.GetByBookingSessionID(r.Context(), payload.SessionID)
 	if err != nil {
 		if errors.Is(err, leads.ErrLeadNotFound) {
-			http.Error(w, "unknown session", http.StatusNotFound)
+			apierror.Write(w, r, apierror.CodeNotFound, "unknown session")
 			return
 		}
 		h.logger.Error("failed to look up lead by booking session", "error", err, "session_id", payload.SessionID)
-		http.Error(w, "internal error", http.StatusInternalServerError)
+		apierror.Write(w, r, apierror.CodeInternal, "internal error")
 		return
 	}
 
@@ -90,19 +93,20 @@ func (h *BookingCallbackHandler) Handle(w http.ResponseWriter, r *http.Request)
 	if payload.ConfirmationDetails != nil {
 		update.ConfirmationNumber = payload.ConfirmationDetails.ConfirmationNumber
 	}
-	if err := h.leadsRepo.UpdateBookingSession(r.Context(), lead.ID, update); err != nil {
+	if err := h.leadsRepo.UpdateBookingSession(r.Context(), tenancy.ForOrg(lead.OrgID), lead.ID, update); err != nil {
 		h.logger.Error("failed to update lead booking outcome", "error", err, "lead_id", lead.ID, "outcome", payload.Outcome)
 	}
 
 	// Build and send outcome SMS
-	smsBody := bookingOutcomeSMS(payload.Outcome, payload.ConfirmationDetails)
-	if smsBody != "" && h.messenger != nil && lead.Phone != "" && fromNumber != "" {
+	sms := bookingOutcomeSMS(payload.Outcome, payload.ConfirmationDetails)
+	if sms.Body != "" && h.messenger != nil && lead.Phone != "" && fromNumber != "" {
 		reply := OutboundReply{
-			OrgID:  orgID,
-			LeadID: lead.ID,
-			To:     lead.Phone,
-			From:   fromNumber,
-			Body:   smsBody,
+			OrgID:    orgID,
+			LeadID:   lead.ID,
+			To:       lead.Phone,
+			From:     fromNumber,
+			Body:     sms.Body,
+			Metadata: sms.Stamp(nil),
 		}
 		if err := h.messenger.SendReply(r.Context(), reply); err != nil {
 			h.logger.Error("failed to send booking outcome SMS", "error", err,
@@ -114,28 +118,28 @@ func (h *BookingCallbackHandler) Handle(w http.ResponseWriter, r *http.Request)
 	_ = json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
 }
 
-// bookingOutcomeSMS returns the SMS body for a given booking outcome.
-func bookingOutcomeSMS(outcome string, details *bookingCallbackConfirmation) string {
+// bookingOutcomeSMS returns the SMS for a given booking outcome, or a zero
+// Rendered for outcomes that send nothing.
+func bookingOutcomeSMS(outcome string, details *bookingCallbackConfirmation) templates.Rendered {
 	switch outcome {
 	case "success":
-		msg := "Your appointment is confirmed!"
+		var confirmation, scheduled string
 		if details != nil && details.ConfirmationNumber != "" {
-			msg += fmt.Sprintf(" Confirmation #%s.", details.ConfirmationNumber)
+			confirmation = fmt.Sprintf(" Confirmation #%s.", details.ConfirmationNumber)
 		}
 		if details != nil && details.AppointmentTime != "" {
-			msg += fmt.Sprintf(" Scheduled for %s.", details.AppointmentTime)
+			scheduled = fmt.Sprintf(" Scheduled for %s.", details.AppointmentTime)
 		}
-		msg += " We look forward to seeing you!"
-		return msg
+		return templates.Render(templates.BookingOutcomeSuccess, templates.Params{"confirmation": confirmation, "scheduled": scheduled}, nil)
 	case "payment_failed":
-		return "Your payment didn't go through. Reply YES to try again, or call the clinic directly for help."
+		return templates.Render(templates.BookingOutcomePaymentFailed, nil, nil)
 	case "slot_unavailable":
-		return "That time slot is no longer available. Want me to check other times for you?"
+		return templates.Render(templates.BookingOutcomeSlotUnavailable, nil, nil)
 	case "timeout":
-		return "Your booking session expired. Would you like to try again?"
+		return templates.Render(templates.BookingOutcomeTimeout, nil, nil)
 	case "error":
-		return "Something went wrong with your booking. Please try again or call the clinic directly."
+		return templates.Render(templates.BookingOutcomeError, nil, nil)
 	default:
-		return ""
+		return templates.Rendered{}
 	}
 }
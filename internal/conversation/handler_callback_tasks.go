@@ -0,0 +1,74 @@
+package conversation
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/http/apierror"
+	"github.com/wolfman30/medspa-ai-platform/internal/tenancy"
+)
+
+// CallbackTasksResponse is the response for GET /admin/clinics/{orgID}/callback-tasks.
+type CallbackTasksResponse struct {
+	CallbackTasks []CallbackTask `json:"callback_tasks"`
+}
+
+// ListCallbackTasks handles GET /admin/clinics/{orgID}/callback-tasks.
+// Returns patients waiting on an operator call, oldest first.
+func (h *Handler) ListCallbackTasks(w http.ResponseWriter, r *http.Request) {
+	scope := tenancy.ScopeFromContext(r.Context())
+	orgID := scope.OrgID()
+	if orgID == "" {
+		apierror.Write(w, r, apierror.CodeValidationFailed, "missing org_id")
+		return
+	}
+	if h.callbacks == nil {
+		apierror.Write(w, r, apierror.CodeDependencyUnavailable, "callback tasks not configured")
+		return
+	}
+
+	tasks, err := h.callbacks.ListOpen(r.Context(), scope)
+	if err != nil {
+		h.logger.Error("failed to list callback tasks", "error", err, "org_id", orgID)
+		apierror.WriteError(w, r, err, apierror.CodeInternal, "failed to list callback tasks")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(CallbackTasksResponse{CallbackTasks: tasks})
+}
+
+// CompleteCallbackTask handles POST /admin/clinics/{orgID}/callback-tasks/{taskID}/done.
+// Marks the call as made, which lets the AI reply on the conversation again.
+func (h *Handler) CompleteCallbackTask(w http.ResponseWriter, r *http.Request) {
+	scope := tenancy.ScopeFromContext(r.Context())
+	orgID := scope.OrgID()
+	taskID, err := uuid.Parse(chi.URLParam(r, "taskID"))
+	if orgID == "" || err != nil {
+		apierror.Write(w, r, apierror.CodeValidationFailed, "missing org_id or invalid task id")
+		return
+	}
+	if h.callbacks == nil {
+		apierror.Write(w, r, apierror.CodeDependencyUnavailable, "callback tasks not configured")
+		return
+	}
+
+	task, err := h.callbacks.Resolve(r.Context(), scope, taskID, CallbackTaskStatusDone)
+	if err != nil {
+		if errors.Is(err, ErrCallbackTaskNotFound) {
+			apierror.Write(w, r, apierror.CodeNotFound, "open callback task not found")
+			return
+		}
+		h.logger.Error("failed to complete callback task", "error", err, "org_id", orgID, "task_id", taskID)
+		apierror.Write(w, r, apierror.CodeInternal, "failed to complete callback task")
+		return
+	}
+
+	h.logger.Info("callback task completed", "org_id", orgID, "task_id", taskID, "conversation_id", task.ConversationID)
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(task)
+}
@@ -6,22 +6,23 @@ import (
 	"strings"
 
 	"github.com/go-chi/chi/v5"
+	"github.com/wolfman30/medspa-ai-platform/internal/http/apierror"
 	"github.com/wolfman30/medspa-ai-platform/internal/tenancy"
 )
 
 // AddKnowledge handles POST /knowledge/{clinicID}.
 func (h *Handler) AddKnowledge(w http.ResponseWriter, r *http.Request) {
 	if h.knowledge == nil {
-		http.Error(w, "knowledge ingestion not configured", http.StatusServiceUnavailable)
+		apierror.Write(w, r, apierror.CodeDependencyUnavailable, "knowledge ingestion not configured")
 		return
 	}
 	clinicID := chi.URLParam(r, "clinicID")
 	if strings.TrimSpace(clinicID) == "" {
-		http.Error(w, "clinicID required", http.StatusBadRequest)
+		apierror.Write(w, r, apierror.CodeValidationFailed, "clinicID required")
 		return
 	}
 	if orgID, ok := tenancy.OrgIDFromContext(r.Context()); ok && orgID != "" && orgID != clinicID {
-		http.Error(w, "clinicID does not match org", http.StatusForbidden)
+		apierror.Write(w, r, apierror.CodeForbidden, "clinicID does not match org")
 		return
 	}
 
@@ -29,22 +30,22 @@ func (h *Handler) AddKnowledge(w http.ResponseWriter, r *http.Request) {
 		Documents json.RawMessage `json:"documents"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
-		http.Error(w, "invalid JSON body", http.StatusBadRequest)
+		apierror.Write(w, r, apierror.CodeValidationFailed, "invalid JSON body")
 		return
 	}
 	documents, err := ParseKnowledgePayload(payload.Documents)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		apierror.Write(w, r, apierror.CodeValidationFailed, err.Error())
 		return
 	}
 	if err := ValidateKnowledgeDocuments(documents); err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		apierror.Write(w, r, apierror.CodeValidationFailed, err.Error())
 		return
 	}
 
-	if err := h.knowledge.AppendDocuments(r.Context(), clinicID, documents); err != nil {
+	if err := h.knowledge.AppendDocuments(r.Context(), tenancy.ForOrg(clinicID), documents); err != nil {
 		h.logger.Error("failed to append knowledge", "error", err)
-		http.Error(w, "failed to persist documents", http.StatusInternalServerError)
+		apierror.WriteError(w, r, err, apierror.CodeInternal, "failed to persist documents")
 		return
 	}
 
@@ -52,7 +53,7 @@ func (h *Handler) AddKnowledge(w http.ResponseWriter, r *http.Request) {
 	if h.rag != nil {
 		if err := h.rag.AddDocuments(r.Context(), clinicID, documents); err != nil {
 			h.logger.Error("failed to embed knowledge", "error", err)
-			http.Error(w, "failed to embed documents", http.StatusInternalServerError)
+			apierror.WriteError(w, r, err, apierror.CodeInternal, "failed to embed documents")
 			return
 		}
 		embedded = true
@@ -69,7 +70,7 @@ func (h *Handler) AddKnowledge(w http.ResponseWriter, r *http.Request) {
 // KnowledgeForm serves a responsive HTML form for uploading clinic knowledge.
 func (h *Handler) KnowledgeForm(w http.ResponseWriter, r *http.Request) {
 	if h.knowledge == nil {
-		http.Error(w, "knowledge ingestion not configured", http.StatusServiceUnavailable)
+		apierror.Write(w, r, apierror.CodeDependencyUnavailable, "knowledge ingestion not configured")
 		return
 	}
 	w.Header().Set("Content-Type", "text/html; charset=utf-8")
@@ -0,0 +1,123 @@
+package conversation
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/http/apierror"
+	"github.com/wolfman30/medspa-ai-platform/internal/tenancy"
+)
+
+// manualSlotOfferEnqueuer is implemented by enqueuers that can schedule
+// manual slot offer jobs (the queue-backed Publisher).
+type manualSlotOfferEnqueuer interface {
+	EnqueueOfferSlot(ctx context.Context, jobID string, req ManualSlotOfferRequest) error
+}
+
+// offerSlotBody is the JSON body staff send to offer a specific time.
+type offerSlotBody struct {
+	Start    time.Time  `json:"start"`
+	End      *time.Time `json:"end"`
+	Service  string     `json:"service"`
+	Provider string     `json:"provider"`
+}
+
+// OfferSlot handles POST /admin/orgs/{orgID}/conversations/{conversationID}/offer-slot.
+// Clinic staff use it when they can fit a patient in at a time the booking
+// platform doesn't show as open. It queues a job that texts the patient that
+// single time; their reply books it through the usual deposit path.
+func (h *Handler) OfferSlot(w http.ResponseWriter, r *http.Request) {
+	scope := tenancy.ScopeFromContext(r.Context())
+	orgID := scope.OrgID()
+	conversationID := chi.URLParam(r, "conversationID")
+	if decoded, err := url.PathUnescape(conversationID); err == nil {
+		conversationID = decoded
+	}
+	parsedOrgID, phone, ok := parseConversationID(conversationID)
+	if orgID == "" || !ok || parsedOrgID != orgID {
+		apierror.Write(w, r, apierror.CodeValidationFailed, "invalid conversation id")
+		return
+	}
+	enqueuer, ok := h.enqueuer.(manualSlotOfferEnqueuer)
+	if !ok || h.conversations == nil || h.refreshLocks == nil {
+		apierror.Write(w, r, apierror.CodeDependencyUnavailable, "slot offers not configured")
+		return
+	}
+
+	var body offerSlotBody
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		apierror.Write(w, r, apierror.CodeValidationFailed, "invalid JSON")
+		return
+	}
+	service := strings.TrimSpace(body.Service)
+	if service == "" {
+		apierror.Write(w, r, apierror.CodeValidationFailed, "service is required")
+		return
+	}
+	if !body.Start.After(time.Now()) {
+		apierror.Write(w, r, apierror.CodeValidationFailed, "start must be in the future")
+		return
+	}
+	if body.End != nil && !body.End.After(body.Start) {
+		apierror.Write(w, r, apierror.CodeValidationFailed, "end must be after start")
+		return
+	}
+
+	ctx := r.Context()
+	conv, err := h.conversations.GetConversation(ctx, scope, conversationID)
+	if err != nil {
+		h.logger.Error("failed to load conversation for slot offer", "error", err, "conversation_id", conversationID)
+		apierror.WriteError(w, r, err, apierror.CodeInternal, "failed to load conversation")
+		return
+	}
+	if conv == nil {
+		apierror.Write(w, r, apierror.CodeNotFound, "conversation not found")
+		return
+	}
+	if availabilityRefreshBlocked(conv.Status) {
+		apierror.Write(w, r, apierror.CodeConflict, "conversation already has a deposit or booking")
+		return
+	}
+
+	// Offers and refreshes share the lock: either one texts the patient new times.
+	if err := acquireAvailabilityRefreshLock(ctx, h.refreshLocks, conversationID); err != nil {
+		if errors.Is(err, ErrAvailabilityRefreshInProgress) {
+			apierror.Write(w, r, apierror.CodeConflict, "times were just sent to this patient")
+			return
+		}
+		h.logger.Error("failed to lock slot offer", "error", err, "conversation_id", conversationID)
+		apierror.Write(w, r, apierror.CodeInternal, "failed to offer slot")
+		return
+	}
+
+	req := ManualSlotOfferRequest{
+		OrgID:          orgID,
+		ConversationID: conversationID,
+		Phone:          phone,
+		Start:          body.Start,
+		End:            body.End,
+		Service:        service,
+		Provider:       strings.TrimSpace(body.Provider),
+		RequestedBy:    assistedBookingActor(r),
+	}
+	if conv.LeadID != nil {
+		req.LeadID = conv.LeadID.String()
+	}
+	jobID := uuid.NewString()
+	if err := enqueuer.EnqueueOfferSlot(ctx, jobID, req); err != nil {
+		h.logger.Error("failed to enqueue slot offer", "error", err, "conversation_id", conversationID)
+		apierror.WriteError(w, r, err, apierror.CodeInternal, "failed to schedule slot offer")
+		return
+	}
+
+	h.logger.Info("manual slot offer queued", "org_id", orgID, "conversation_id", conversationID, "job_id", jobID, "requested_by", req.RequestedBy)
+	h.writeAccepted(w, jobID)
+}
@@ -13,6 +13,7 @@ import (
 	"testing"
 
 	"github.com/go-chi/chi/v5"
+	"github.com/wolfman30/medspa-ai-platform/internal/http/apierror"
 	"github.com/wolfman30/medspa-ai-platform/internal/leads"
 	"github.com/wolfman30/medspa-ai-platform/internal/tenancy"
 	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
@@ -254,6 +255,13 @@ func TestHandler_JobStatus_NotFound(t *testing.T) {
 	if w.Code != http.StatusNotFound {
 		t.Fatalf("expected status %d, got %d", http.StatusNotFound, w.Code)
 	}
+	var env apierror.Envelope
+	if err := json.NewDecoder(w.Body).Decode(&env); err != nil {
+		t.Fatalf("decode error envelope: %v", err)
+	}
+	if env.Error.Code != apierror.CodeNotFound || env.Error.Message != "job not found" {
+		t.Fatalf("unexpected error body: %+v", env.Error)
+	}
 }
 
 func routeWithJobID(req *http.Request, jobID string) *http.Request {
@@ -416,19 +424,23 @@ type stubKnowledgeRepo struct {
 	err      error
 }
 
-func (s *stubKnowledgeRepo) AppendDocuments(ctx context.Context, clinicID string, docs []string) error {
+func (s *stubKnowledgeRepo) AppendDocuments(ctx context.Context, scope tenancy.OrgScope, docs []string) error {
 	if s.err != nil {
 		return s.err
 	}
 	if s.appended == nil {
 		s.appended = make(map[string][]string)
 	}
-	s.appended[clinicID] = append(s.appended[clinicID], docs...)
+	s.appended[scope.OrgID()] = append(s.appended[scope.OrgID()], docs...)
 	return nil
 }
 
-func (s *stubKnowledgeRepo) GetDocuments(ctx context.Context, clinicID string) ([]string, error) {
-	return s.appended[clinicID], nil
+func (s *stubKnowledgeRepo) GetDocuments(ctx context.Context, scope tenancy.OrgScope) ([]string, error) {
+	return s.appended[scope.OrgID()], nil
+}
+
+func (s *stubKnowledgeRepo) GetSharedDocuments(ctx context.Context) ([]string, error) {
+	return s.appended[""], nil
 }
 
 func (s *stubKnowledgeRepo) LoadAll(ctx context.Context) (map[string][]string, error) {
@@ -472,7 +484,7 @@ func setupBookingCallbackTest(t *testing.T) (*BookingCallbackHandler, *leads.InM
 		t.Fatalf("create lead: %v", err)
 	}
 	// Set booking session ID on lead
-	if err := repo.UpdateBookingSession(context.Background(), lead.ID, leads.BookingSessionUpdate{
+	if err := repo.UpdateBookingSession(context.Background(), tenancy.ForOrg(lead.OrgID), lead.ID, leads.BookingSessionUpdate{
 		SessionID: "session-abc",
 		Platform:  "moxie",
 	}); err != nil {
@@ -499,7 +511,7 @@ func TestBookingCallback_Success(t *testing.T) {
 	}
 
 	// Verify lead updated
-	updated, _ := repo.GetByID(context.Background(), "org-1", lead.ID)
+	updated, _ := repo.GetByID(context.Background(), tenancy.ForOrg("org-1"), lead.ID)
 	if updated.BookingOutcome != "success" {
 		t.Errorf("outcome = %q, want success", updated.BookingOutcome)
 	}
@@ -545,7 +557,7 @@ func TestBookingCallback_PaymentFailed(t *testing.T) {
 		t.Fatalf("expected 200, got %d", rr.Code)
 	}
 
-	updated, _ := repo.GetByID(context.Background(), "org-1", lead.ID)
+	updated, _ := repo.GetByID(context.Background(), tenancy.ForOrg("org-1"), lead.ID)
 	if updated.BookingOutcome != "payment_failed" {
 		t.Errorf("outcome = %q, want payment_failed", updated.BookingOutcome)
 	}
@@ -9,6 +9,8 @@ import (
 	"strings"
 
 	"github.com/go-chi/chi/v5"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/http/apierror"
 )
 
 // TranscriptResponse is the response for GET /admin/clinics/{orgID}/conversations/{phone}.
@@ -24,38 +26,37 @@ func (h *Handler) GetTranscript(w http.ResponseWriter, r *http.Request) {
 	phoneParam := chi.URLParam(r, "phone")
 	phone, err := url.PathUnescape(phoneParam)
 	if err != nil {
-		http.Error(w, "invalid phone encoding", http.StatusBadRequest)
+		apierror.Write(w, r, apierror.CodeValidationFailed, "invalid phone encoding")
 		return
 	}
 	phone = strings.TrimSpace(phone)
 
 	if orgID == "" || phone == "" {
-		http.Error(w, "missing org_id or phone", http.StatusBadRequest)
+		apierror.Write(w, r, apierror.CodeValidationFailed, "missing org_id or phone")
 		return
 	}
 
 	if h.service == nil {
-		http.Error(w, "transcript service not configured", http.StatusInternalServerError)
+		apierror.Write(w, r, apierror.CodeInternal, "transcript service not configured")
 		return
 	}
 
-	digits := sanitizeDigits(phone)
+	digits := canonicalPhoneDigits(phone)
 	if digits == "" {
-		http.Error(w, "invalid phone", http.StatusBadRequest)
+		apierror.Write(w, r, apierror.CodeValidationFailed, "invalid phone")
 		return
 	}
-	digits = normalizeUSDigits(digits)
 	conversationID := fmt.Sprintf("sms:%s:%s", orgID, digits)
 
 	messages, err := h.service.GetHistory(r.Context(), conversationID)
 	if err != nil {
 		// Check if it's a "not found" error
 		if strings.Contains(err.Error(), "unknown conversation") {
-			http.Error(w, "conversation not found", http.StatusNotFound)
+			apierror.Write(w, r, apierror.CodeNotFound, "conversation not found")
 			return
 		}
 		h.logger.Error("failed to get transcript", "error", err, "conversation_id", conversationID)
-		http.Error(w, "failed to retrieve transcript", http.StatusInternalServerError)
+		apierror.Write(w, r, apierror.CodeInternal, "failed to retrieve transcript")
 		return
 	}
 
@@ -81,33 +82,32 @@ func (h *Handler) GetSMSTranscript(w http.ResponseWriter, r *http.Request) {
 	phoneParam := chi.URLParam(r, "phone")
 	phone, err := url.PathUnescape(phoneParam)
 	if err != nil {
-		http.Error(w, "invalid phone encoding", http.StatusBadRequest)
+		apierror.Write(w, r, apierror.CodeValidationFailed, "invalid phone encoding")
 		return
 	}
 	phone = strings.TrimSpace(phone)
 
 	if orgID == "" || phone == "" {
-		http.Error(w, "missing org_id or phone", http.StatusBadRequest)
+		apierror.Write(w, r, apierror.CodeValidationFailed, "missing org_id or phone")
 		return
 	}
 	if h.sms == nil {
-		http.Error(w, "sms transcript store not configured", http.StatusServiceUnavailable)
+		apierror.Write(w, r, apierror.CodeDependencyUnavailable, "sms transcript store not configured")
 		return
 	}
 
-	digits := sanitizeDigits(phone)
+	digits := canonicalPhoneDigits(phone)
 	if digits == "" {
-		http.Error(w, "invalid phone", http.StatusBadRequest)
+		apierror.Write(w, r, apierror.CodeValidationFailed, "invalid phone")
 		return
 	}
-	digits = normalizeUSDigits(digits)
 	conversationID := fmt.Sprintf("sms:%s:%s", orgID, digits)
 
 	var limit int64
 	if raw := strings.TrimSpace(r.URL.Query().Get("limit")); raw != "" {
 		parsed, err := strconv.ParseInt(raw, 10, 64)
 		if err != nil || parsed < 0 {
-			http.Error(w, "invalid limit", http.StatusBadRequest)
+			apierror.Write(w, r, apierror.CodeValidationFailed, "invalid limit")
 			return
 		}
 		limit = parsed
@@ -116,7 +116,7 @@ func (h *Handler) GetSMSTranscript(w http.ResponseWriter, r *http.Request) {
 	messages, err := h.sms.List(r.Context(), conversationID, limit)
 	if err != nil {
 		h.logger.Error("failed to load sms transcript", "error", err, "conversation_id", conversationID)
-		http.Error(w, "failed to retrieve sms transcript", http.StatusInternalServerError)
+		apierror.WriteError(w, r, err, apierror.CodeInternal, "failed to retrieve sms transcript")
 		return
 	}
 
@@ -127,27 +127,3 @@ func (h *Handler) GetSMSTranscript(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	_ = json.NewEncoder(w).Encode(resp)
 }
-
-// sanitizeDigits strips all non-digit characters from a phone string.
-func sanitizeDigits(value string) string {
-	value = strings.TrimSpace(value)
-	if value == "" {
-		return ""
-	}
-	var b strings.Builder
-	b.Grow(len(value))
-	for _, r := range value {
-		if r >= '0' && r <= '9' {
-			b.WriteRune(r)
-		}
-	}
-	return b.String()
-}
-
-// normalizeUSDigits converts 10-digit US numbers to E.164 digits by prefixing "1".
-func normalizeUSDigits(digits string) string {
-	if len(digits) == 10 {
-		return "1" + digits
-	}
-	return digits
-}
@@ -0,0 +1,196 @@
+package conversation
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Stored history values start with a three-byte header: "h", the format
+// version and the payload encoding. Values saved before the header existed
+// are bare JSON arrays and still load. Replicas older than the header can't
+// read it, so it is only written once every replica can (see
+// WithHistoryHeader); until then values stay bare JSON.
+const (
+	historyHeaderMagic   = 'h'
+	historyFormatVersion = '1'
+	historyEncodingJSON  = 'j'
+	historyEncodingGzip  = 'z'
+	historyHeaderLen     = 3
+)
+
+const (
+	// historyGzipMinBytes is the JSON size above which history is gzipped;
+	// short threads aren't worth the CPU.
+	historyGzipMinBytes = 4 << 10
+	// maxHistoryBytes caps a stored history value. Longer threads are
+	// compacted before saving.
+	maxHistoryBytes = 48 << 10
+	// maxHistoryDecodedBytes bounds how much a gzipped value may inflate to
+	// on load.
+	maxHistoryDecodedBytes = 4 << 20
+	// minCompactedHistory is the fewest messages compaction keeps: the
+	// system prompt, the compaction note and the latest exchange.
+	minCompactedHistory = 4
+)
+
+// historyCompactedNote stands in for the turns dropped by compaction so the
+// LLM knows the thread started earlier.
+const historyCompactedNote = "Context: Earlier messages in this conversation were removed to save space. Don't ask again for details the patient may already have given; confirm them instead."
+
+// errHistoryCorrupt marks a stored history value that can't be decoded.
+var errHistoryCorrupt = errors.New("conversation: corrupt history value")
+
+var historyStoredBytes = prometheus.NewHistogramVec(
+	prometheus.HistogramOpts{
+		Namespace: "medspa",
+		Subsystem: "conversation",
+		Name:      "history_stored_bytes",
+		Help:      "Size of saved conversation history values in Redis",
+		Buckets:   prometheus.ExponentialBuckets(512, 2, 10), // 512B .. 256KiB
+	},
+	[]string{"encoding"}, // encoding: json, gzip
+)
+
+var historyCompressionRatio = prometheus.NewHistogram(
+	prometheus.HistogramOpts{
+		Namespace: "medspa",
+		Subsystem: "conversation",
+		Name:      "history_compression_ratio",
+		Help:      "JSON size over gzipped size of compressed history values",
+		Buckets:   []float64{1, 1.5, 2, 3, 4, 6, 8, 12, 16},
+	},
+)
+
+var historyCompactedTotal = prometheus.NewCounter(
+	prometheus.CounterOpts{
+		Namespace: "medspa",
+		Subsystem: "conversation",
+		Name:      "history_compacted_total",
+		Help:      "History saves that dropped older turns to fit the size cap",
+	},
+)
+
+var historyDecodeFailuresTotal = prometheus.NewCounter(
+	prometheus.CounterOpts{
+		Namespace: "medspa",
+		Subsystem: "conversation",
+		Name:      "history_decode_failures_total",
+		Help:      "Stored history values that couldn't be decoded and were discarded",
+	},
+)
+
+func init() {
+	prometheus.MustRegister(historyStoredBytes, historyCompressionRatio, historyCompactedTotal, historyDecodeFailuresTotal)
+}
+
+// encodeHistory serializes history with the versioned header, gzipping it
+// when the JSON is large, or as bare JSON when headered is false. It also
+// returns the JSON size.
+func encodeHistory(history []ChatMessage, headered bool) ([]byte, int, error) {
+	raw, err := json.Marshal(history)
+	if err != nil {
+		return nil, 0, fmt.Errorf("conversation: failed to marshal history: %w", err)
+	}
+	if !headered {
+		return raw, len(raw), nil
+	}
+	if len(raw) < historyGzipMinBytes {
+		return append([]byte{historyHeaderMagic, historyFormatVersion, historyEncodingJSON}, raw...), len(raw), nil
+	}
+	var buf bytes.Buffer
+	buf.Write([]byte{historyHeaderMagic, historyFormatVersion, historyEncodingGzip})
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(raw); err != nil {
+		return nil, 0, fmt.Errorf("conversation: failed to compress history: %w", err)
+	}
+	if err := zw.Close(); err != nil {
+		return nil, 0, fmt.Errorf("conversation: failed to compress history: %w", err)
+	}
+	return buf.Bytes(), len(raw), nil
+}
+
+// decodeHistory reads a stored history value in either the headered or the
+// legacy bare-JSON format. Values it can't read wrap errHistoryCorrupt.
+func decodeHistory(data []byte) ([]ChatMessage, error) {
+	raw := data
+	if len(data) > 0 && data[0] == historyHeaderMagic {
+		if len(data) < historyHeaderLen || data[1] != historyFormatVersion {
+			return nil, fmt.Errorf("%w: unknown header %q", errHistoryCorrupt, data[:min(len(data), historyHeaderLen)])
+		}
+		switch data[2] {
+		case historyEncodingJSON:
+			raw = data[historyHeaderLen:]
+		case historyEncodingGzip:
+			zr, err := gzip.NewReader(bytes.NewReader(data[historyHeaderLen:]))
+			if err != nil {
+				return nil, fmt.Errorf("%w: %v", errHistoryCorrupt, err)
+			}
+			raw, err = io.ReadAll(io.LimitReader(zr, maxHistoryDecodedBytes+1))
+			if err != nil {
+				return nil, fmt.Errorf("%w: %v", errHistoryCorrupt, err)
+			}
+			if len(raw) > maxHistoryDecodedBytes {
+				return nil, fmt.Errorf("%w: inflates past %d bytes", errHistoryCorrupt, maxHistoryDecodedBytes)
+			}
+		default:
+			return nil, fmt.Errorf("%w: unknown encoding %q", errHistoryCorrupt, data[2])
+		}
+	}
+	var history []ChatMessage
+	if err := json.Unmarshal(raw, &history); err != nil {
+		return nil, fmt.Errorf("%w: %v", errHistoryCorrupt, err)
+	}
+	return history, nil
+}
+
+// fitHistory encodes history, compacting it until the value fits
+// maxHistoryBytes, and records the stored size. It returns the encoded value
+// and whether turns were dropped.
+func fitHistory(history []ChatMessage, headered bool) ([]byte, bool, error) {
+	data, jsonBytes, err := encodeHistory(history, headered)
+	compacted := false
+	for err == nil && len(data) > maxHistoryBytes && len(history) > minCompactedHistory {
+		history = compactHistory(history)
+		compacted = true
+		data, jsonBytes, err = encodeHistory(history, headered)
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	if compacted {
+		historyCompactedTotal.Inc()
+	}
+	if headered && data[2] == historyEncodingGzip {
+		historyStoredBytes.WithLabelValues("gzip").Observe(float64(len(data)))
+		historyCompressionRatio.Observe(float64(jsonBytes) / float64(len(data)))
+	} else {
+		historyStoredBytes.WithLabelValues("json").Observe(float64(len(data)))
+	}
+	return data, compacted, nil
+}
+
+// compactHistory drops the older half of the turns after the system prompt
+// and notes the gap.
+func compactHistory(history []ChatMessage) []ChatMessage {
+	var out []ChatMessage
+	rest := history
+	if len(rest) > 0 && rest[0].Role == ChatRoleSystem {
+		out = append(out, rest[0])
+		rest = rest[1:]
+	}
+	if len(rest) > 0 && rest[0].Role == ChatRoleSystem && rest[0].Content == historyCompactedNote {
+		rest = rest[1:]
+	}
+	keep := max(len(rest)/2, minCompactedHistory-2)
+	if keep < len(rest) {
+		rest = rest[len(rest)-keep:]
+	}
+	out = append(out, ChatMessage{Role: ChatRoleSystem, Content: historyCompactedNote}.withSchema())
+	return append(out, rest...)
+}
@@ -0,0 +1,240 @@
+package conversation
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+
+	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
+)
+
+func historyOfSize(turns int, content string) []ChatMessage {
+	history := []ChatMessage{{Role: ChatRoleSystem, Content: "You are a helpful assistant."}}
+	for i := 0; i < turns; i++ {
+		history = append(history,
+			ChatMessage{Role: ChatRoleUser, Content: fmt.Sprintf("question %d: %s", i, content)},
+			ChatMessage{Role: ChatRoleAssistant, Content: fmt.Sprintf("answer %d: %s", i, content)},
+		)
+	}
+	return history
+}
+
+func TestHistoryStore_RoundTripsBothEncodings(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	store := newHistoryStore(client, nil)
+	store.writeHeader = true
+	ctx := context.Background()
+
+	cases := map[string]struct {
+		history []ChatMessage
+		header  string
+	}{
+		"short thread stays json": {historyOfSize(2, "Do you have Botox on Friday?"), "h1j"},
+		"long thread is gzipped":  {historyOfSize(40, strings.Repeat("Tell me about lip filler pricing. ", 5)), "h1z"},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			convID := "conv-" + tc.header
+			if err := store.Save(ctx, convID, tc.history); err != nil {
+				t.Fatalf("save: %v", err)
+			}
+			raw, err := mr.Get(conversationKey(convID))
+			if err != nil {
+				t.Fatalf("get raw: %v", err)
+			}
+			if !strings.HasPrefix(raw, tc.header) {
+				t.Fatalf("stored header = %q, want %q", raw[:3], tc.header)
+			}
+			got, err := store.Load(ctx, convID)
+			if err != nil {
+				t.Fatalf("load: %v", err)
+			}
+			if len(got) != len(tc.history) {
+				t.Fatalf("loaded %d messages, want %d", len(got), len(tc.history))
+			}
+			for i := range got {
+				if got[i].Role != tc.history[i].Role || got[i].Content != tc.history[i].Content {
+					t.Fatalf("message %d = %+v, want %+v", i, got[i], tc.history[i])
+				}
+			}
+			if tc.header == "h1z" {
+				_, jsonLen, err := encodeHistory(tc.history, true)
+				if err != nil {
+					t.Fatalf("encode: %v", err)
+				}
+				if len(raw) >= jsonLen/2 {
+					t.Fatalf("gzipped value is %d bytes for %d bytes of JSON", len(raw), jsonLen)
+				}
+			}
+		})
+	}
+}
+
+func TestHistoryStore_WritesBareJSONUntilHeaderEnabled(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	store := newHistoryStore(client, nil)
+	ctx := context.Background()
+
+	// A long thread would be gzipped under the header; replicas that predate
+	// it must still be able to read what this one saves.
+	history := historyOfSize(40, strings.Repeat("Tell me about lip filler pricing. ", 5))
+	if err := store.Save(ctx, "conv-legacy", history); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	raw, err := mr.Get(conversationKey("conv-legacy"))
+	if err != nil {
+		t.Fatalf("get raw: %v", err)
+	}
+	var legacy []ChatMessage
+	if err := json.Unmarshal([]byte(raw), &legacy); err != nil {
+		t.Fatalf("expected bare JSON a legacy reader can parse, got %q: %v", raw[:min(len(raw), 16)], err)
+	}
+	if len(legacy) != len(history) {
+		t.Fatalf("legacy read %d messages, want %d", len(legacy), len(history))
+	}
+
+	// A replica with the header on writes it, and this one still reads it.
+	headered := newHistoryStore(client, nil)
+	headered.writeHeader = true
+	if err := headered.Save(ctx, "conv-legacy", history); err != nil {
+		t.Fatalf("headered save: %v", err)
+	}
+	got, err := store.Load(ctx, "conv-legacy")
+	if err != nil || len(got) != len(history) {
+		t.Fatalf("expected the headered value to load, got %d messages, %v", len(got), err)
+	}
+}
+
+func TestHistoryStore_CompactsOversizedHistory(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	store := newHistoryStore(client, nil)
+	ctx := context.Background()
+
+	// Distinct random-looking turns defeat gzip so the value can't fit
+	// uncompacted.
+	history := []ChatMessage{{Role: ChatRoleSystem, Content: "You are a helpful assistant."}}
+	seed := uint32(1)
+	for i := 0; i < 120; i++ {
+		var b strings.Builder
+		for b.Len() < 1000 {
+			seed = seed*1664525 + 1013904223
+			fmt.Fprintf(&b, "%08x", seed)
+		}
+		role := ChatRoleUser
+		if i%2 == 1 {
+			role = ChatRoleAssistant
+		}
+		history = append(history, ChatMessage{Role: role, Content: b.String()})
+	}
+	history = append(history, ChatMessage{Role: ChatRoleUser, Content: "Can I book Friday at 3pm?"})
+
+	if err := store.Save(ctx, "conv-big", history); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	raw, err := mr.Get(conversationKey("conv-big"))
+	if err != nil {
+		t.Fatalf("get raw: %v", err)
+	}
+	if len(raw) > maxHistoryBytes {
+		t.Fatalf("stored %d bytes, cap is %d", len(raw), maxHistoryBytes)
+	}
+	got, err := store.Load(ctx, "conv-big")
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if len(got) >= len(history) {
+		t.Fatalf("expected older turns dropped, kept %d of %d", len(got), len(history))
+	}
+	if got[0].Content != history[0].Content {
+		t.Fatalf("expected the system prompt first, got %q", got[0].Content)
+	}
+	if got[1].Role != ChatRoleSystem || got[1].Content != historyCompactedNote {
+		t.Fatalf("expected the compaction note after the system prompt, got %+v", got[1])
+	}
+	if last := got[len(got)-1].Content; last != "Can I book Friday at 3pm?" {
+		t.Fatalf("expected the newest message kept, got %q", last)
+	}
+
+	// Compacting again replaces the note rather than stacking another.
+	again := compactHistory(got)
+	notes := 0
+	for _, msg := range again {
+		if msg.Content == historyCompactedNote {
+			notes++
+		}
+	}
+	if notes != 1 {
+		t.Fatalf("expected one compaction note, got %d", notes)
+	}
+}
+
+func TestHistoryStore_CorruptValueLoadsAsUnknown(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	store := newHistoryStore(client, nil)
+
+	cases := map[string]string{
+		"garbage":          "not json at all",
+		"truncated json":   `[{"role":"user","content":"hi`,
+		"bad gzip":         "h1z\x1f\x8bnot really gzip",
+		"unknown version":  "h9j[]",
+		"unknown encoding": "h1x[]",
+	}
+	for name, value := range cases {
+		t.Run(name, func(t *testing.T) {
+			if err := mr.Set(conversationKey("conv-corrupt"), value); err != nil {
+				t.Fatalf("seed: %v", err)
+			}
+			_, err := store.Load(context.Background(), "conv-corrupt")
+			if err == nil || !strings.Contains(err.Error(), "unknown conversation") {
+				t.Fatalf("expected an unknown conversation error, got %v", err)
+			}
+			if !errors.Is(err, errHistoryCorrupt) {
+				t.Fatalf("expected errHistoryCorrupt, got %v", err)
+			}
+		})
+	}
+}
+
+func TestLLMService_ProcessMessage_CorruptHistoryRestarts(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	if err := mr.Set(conversationKey("conv-corrupt"), "h1z\x1f\x8bgarbage"); err != nil {
+		t.Fatalf("seed: %v", err)
+	}
+	mockLLM := &stubLLMClient{response: LLMResponse{Text: "Hi there! How can I help?"}}
+	service := NewLLMService(mockLLM, client, nil, "anthropic.claude-3-haiku-20240307-v1:0", logging.Default())
+
+	resp, err := service.ProcessMessage(context.Background(), MessageRequest{
+		ConversationID: "conv-corrupt",
+		Message:        "hello",
+		Channel:        ChannelSMS,
+		OrgID:          "org-1",
+	})
+	if err != nil {
+		t.Fatalf("expected the conversation to restart, got %v", err)
+	}
+	if resp == nil || resp.Message != "Hi there! How can I help?" {
+		t.Fatalf("unexpected response: %#v", resp)
+	}
+	raw, err := mr.Get(conversationKey("conv-corrupt"))
+	if err != nil {
+		t.Fatalf("expected fresh history to persist: %v", err)
+	}
+	history, err := decodeHistory([]byte(raw))
+	if err != nil {
+		t.Fatalf("expected a readable value after restart: %v", err)
+	}
+	if got := history[len(history)-1].Content; got != "Hi there! How can I help?" {
+		t.Fatalf("expected the reply stored, got %q", got)
+	}
+}
@@ -9,6 +9,8 @@ import (
 	"github.com/redis/go-redis/v9"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/trace"
+
+	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
 )
 
 const conversationTTL = 24 * time.Hour
@@ -16,6 +18,10 @@ const conversationTTL = 24 * time.Hour
 type historyStore struct {
 	redis  *redis.Client
 	tracer trace.Tracer
+	logger *logging.Logger
+	// writeHeader saves history in the headered format rather than bare
+	// JSON. Loads read both either way.
+	writeHeader bool
 }
 
 func newHistoryStore(redis *redis.Client, tracer trace.Tracer) *historyStore {
@@ -28,6 +34,7 @@ func newHistoryStore(redis *redis.Client, tracer trace.Tracer) *historyStore {
 	return &historyStore{
 		redis:  redis,
 		tracer: tracer,
+		logger: logging.Default(),
 	}
 }
 
@@ -35,10 +42,19 @@ func (s *historyStore) Save(ctx context.Context, conversationID string, history
 	ctx, span := s.tracer.Start(ctx, "conversation.save_history")
 	defer span.End()
 
-	data, err := json.Marshal(history)
+	for i := range history {
+		history[i] = history[i].withSchema()
+	}
+	data, compacted, err := fitHistory(history, s.writeHeader)
 	if err != nil {
 		span.RecordError(err)
-		return fmt.Errorf("conversation: failed to marshal history: %w", err)
+		return err
+	}
+	if compacted {
+		s.logger.Info("conversation history compacted to fit size cap", "conversation_id", conversationID, "messages", len(history))
+	}
+	if lock := conversationLockFor(ctx, conversationID); lock != nil {
+		return s.saveFenced(ctx, span, lock, conversationID, data)
 	}
 	if err := s.redis.Set(ctx, conversationKey(conversationID), data, conversationTTL).Err(); err != nil {
 		span.RecordError(err)
@@ -47,6 +63,33 @@ func (s *historyStore) Save(ctx context.Context, conversationID string, history
 	return nil
 }
 
+// saveFencedHistoryScript writes history only while the writer still holds
+// the conversation lock.
+var saveFencedHistoryScript = redis.NewScript(`
+if redis.call('GET', KEYS[1]) ~= ARGV[1] then
+	return 0
+end
+redis.call('SET', KEYS[2], ARGV[2], 'PX', ARGV[3])
+return 1
+`)
+
+// saveFenced persists history under the conversation lock, rejecting the
+// write when the lock has expired and passed to another worker.
+func (s *historyStore) saveFenced(ctx context.Context, span trace.Span, lock *ConversationLock, conversationID string, data []byte) error {
+	keys := []string{lock.key, conversationKey(conversationID)}
+	n, err := saveFencedHistoryScript.Run(ctx, s.redis, keys, lock.token, data, conversationTTL.Milliseconds()).Int()
+	if err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("conversation: failed to persist history: %w", err)
+	}
+	if n == 0 {
+		lock.lost.Store(true)
+		span.RecordError(ErrConversationLockLost)
+		return fmt.Errorf("conversation: history not saved: %w", ErrConversationLockLost)
+	}
+	return nil
+}
+
 func (s *historyStore) Load(ctx context.Context, conversationID string) ([]ChatMessage, error) {
 	ctx, span := s.tracer.Start(ctx, "conversation.load_history")
 	defer span.End()
@@ -60,10 +103,19 @@ func (s *historyStore) Load(ctx context.Context, conversationID string) ([]ChatM
 		return nil, fmt.Errorf("conversation: failed to load history: %w", err)
 	}
 
-	var history []ChatMessage
-	if err := json.Unmarshal(data, &history); err != nil {
+	history, err := decodeHistory(data)
+	if err != nil {
+		// There is no durable copy to rebuild from yet, so the conversation
+		// starts over rather than failing every turn.
 		span.RecordError(err)
-		return nil, fmt.Errorf("conversation: failed to decode history: %w", err)
+		historyDecodeFailuresTotal.Inc()
+		s.logger.Warn("discarding unreadable conversation history", "conversation_id", conversationID, "error", err)
+		return nil, fmt.Errorf("conversation: unknown conversation %s: %w", conversationID, err)
+	}
+	// Entries saved before Direction and AuthorType were recorded are filled
+	// in from their role.
+	for i := range history {
+		history[i] = history[i].withSchema()
 	}
 	return history, nil
 }
@@ -149,3 +201,27 @@ func (s *historyStore) LoadTimeSelectionState(ctx context.Context, conversationI
 	}
 	return &state, nil
 }
+
+// TimeSelectionReader gives read-only callers outside the worker, such as the
+// admin API, access to the slots last presented in a conversation.
+type TimeSelectionReader struct {
+	store *historyStore
+}
+
+// NewTimeSelectionReader returns a reader backed by redisClient, or nil when
+// redisClient is nil.
+func NewTimeSelectionReader(redisClient *redis.Client) *TimeSelectionReader {
+	if redisClient == nil {
+		return nil
+	}
+	return &TimeSelectionReader{store: newHistoryStore(redisClient, nil)}
+}
+
+// Load returns the conversation's time selection state, or nil when none is
+// stored or the reader is nil.
+func (r *TimeSelectionReader) Load(ctx context.Context, conversationID string) (*TimeSelectionState, error) {
+	if r == nil {
+		return nil, nil
+	}
+	return r.store.LoadTimeSelectionState(ctx, conversationID)
+}
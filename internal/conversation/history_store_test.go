@@ -0,0 +1,41 @@
+package conversation
+
+import (
+	"context"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func TestHistoryStoreLoadFillsLegacyEntries(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	store := newHistoryStore(client, nil)
+	convID := "sms:org-1:15550001111"
+
+	legacy := `[{"role":"system","content":"You are a helpful assistant."},` +
+		`{"role":"user","content":"Do you have botox on Friday?"},` +
+		`{"role":"assistant","content":"We do! Morning or afternoon?"},` +
+		`{"role":"user","content":"We can fit you in at 3pm","direction":"outbound","author_type":"staff"}]`
+	if err := mr.Set(conversationKey(convID), legacy); err != nil {
+		t.Fatalf("seed history: %v", err)
+	}
+
+	history, err := store.Load(context.Background(), convID)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	want := [][2]string{{"outbound", "system"}, {"inbound", "patient"}, {"outbound", "ai"}, {"outbound", "staff"}}
+	if len(history) != len(want) {
+		t.Fatalf("loaded %d entries, want %d", len(history), len(want))
+	}
+	for i, msg := range history {
+		if msg.Direction != want[i][0] || msg.AuthorType != want[i][1] {
+			t.Errorf("entry %d = %s/%s, want %s/%s", i, msg.Direction, msg.AuthorType, want[i][0], want[i][1])
+		}
+	}
+	if history[3].FromPatient() {
+		t.Error("staff entry should not count as the patient's")
+	}
+}
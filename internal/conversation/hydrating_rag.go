@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"sync"
 
+	"github.com/wolfman30/medspa-ai-platform/internal/tenancy"
 	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
 )
 
@@ -52,8 +53,8 @@ func NewHydratingRAGRetriever(ctx context.Context, repo KnowledgeRepository, sto
 	if docsByClinic, err := repo.LoadAll(ctx); err == nil {
 		for clinicID, docs := range docsByClinic {
 			h.hydratedCounts.Store(clinicID, len(docs))
-			if h.versioner != nil {
-				if version, err := h.versioner.GetVersion(ctx, clinicID); err == nil {
+			if h.versioner != nil && clinicID != "" {
+				if version, err := h.versioner.GetVersion(ctx, tenancy.ForOrg(clinicID)); err == nil {
 					h.hydratedVers.Store(clinicID, version)
 				}
 			}
@@ -77,18 +78,27 @@ func (h *HydratingRAGRetriever) Query(ctx context.Context, clinicID string, quer
 	return h.store.Query(ctx, clinicID, query, topK)
 }
 
+// documents loads a clinic's snippets, or the shared ones for "".
+func (h *HydratingRAGRetriever) documents(ctx context.Context, clinicID string) ([]string, error) {
+	if clinicID == "" {
+		return h.repo.GetSharedDocuments(ctx)
+	}
+	return h.repo.GetDocuments(ctx, tenancy.ForOrg(clinicID))
+}
+
 func (h *HydratingRAGRetriever) ensureHydrated(ctx context.Context, clinicID string) error {
 	lock := h.lockForClinic(clinicID)
 	lock.Lock()
 	defer lock.Unlock()
 
-	docs, err := h.repo.GetDocuments(ctx, clinicID)
+	docs, err := h.documents(ctx, clinicID)
 	if err != nil {
 		return fmt.Errorf("ensureHydrated: get documents for %q: %w", clinicID, err)
 	}
 
-	if h.versioner != nil {
-		version, err := h.versioner.GetVersion(ctx, clinicID)
+	// Shared snippets are seeded once and never versioned.
+	if h.versioner != nil && clinicID != "" {
+		version, err := h.versioner.GetVersion(ctx, tenancy.ForOrg(clinicID))
 		if err != nil {
 			return fmt.Errorf("ensureHydrated: get version for %q: %w", clinicID, err)
 		}
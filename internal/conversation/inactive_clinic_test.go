@@ -0,0 +1,88 @@
+package conversation
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/clinic"
+	"github.com/wolfman30/medspa-ai-platform/internal/leads"
+	"github.com/wolfman30/medspa-ai-platform/internal/tenancy"
+	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
+)
+
+func TestWorkerDropsJobsForInactiveClinic(t *testing.T) {
+	mr := miniredis.RunT(t)
+	store := clinic.NewStore(redis.NewClient(&redis.Options{Addr: mr.Addr()}))
+	cfg := clinic.DefaultConfig("org-1")
+	cfg.Inactive = true
+	if err := store.Set(context.Background(), cfg); err != nil {
+		t.Fatalf("seed config: %v", err)
+	}
+	service := &recordingService{}
+	messenger := &recordingMessenger{}
+	worker := NewWorker(service, newScriptedQueue(), &stubJobUpdater{}, messenger, nil, logging.Default(),
+		WithClinicConfigStore(store))
+
+	sendWorkerSMS(t, worker, "job-1", "hi, are you open saturday?")
+	if service.messageCount() != 0 {
+		t.Fatalf("expected the LLM to be skipped for an inactive clinic, got %d calls", service.messageCount())
+	}
+	if replies := messenger.allReplies(); len(replies) != 0 {
+		t.Fatalf("expected no reply (not even the error fallback), got %+v", replies)
+	}
+
+	cfg.Inactive = false
+	if err := store.Set(context.Background(), cfg); err != nil {
+		t.Fatalf("reactivate: %v", err)
+	}
+	sendWorkerSMS(t, worker, "job-2", "hi, are you open saturday?")
+	if service.messageCount() != 1 {
+		t.Fatalf("expected the LLM after reactivation, got %d calls", service.messageCount())
+	}
+}
+
+func TestLLMServiceRefreshAvailability_InactiveClinic(t *testing.T) {
+	ts := setupService(t, withLeads(), withClinicConfig("org-1", func(cfg *clinic.Config) {
+		cfg.BookingURL = "https://app.joinmoxie.com/booking/glow"
+		cfg.Inactive = true
+	}))
+	lead, err := ts.leadsRepo.Create(context.Background(), &leads.CreateLeadRequest{OrgID: "org-1", Name: "Jane", Phone: "+15551234567", Source: "sms"})
+	if err != nil {
+		t.Fatalf("create lead: %v", err)
+	}
+	_ = ts.leadsRepo.UpdateSchedulingPreferences(context.Background(), tenancy.ForOrg(lead.OrgID), lead.ID, leads.SchedulingPreferences{ServiceInterest: "Botox"})
+
+	_, err = ts.svc.RefreshAvailability(context.Background(), RefreshAvailabilityRequest{
+		OrgID: "org-1", LeadID: lead.ID, ConversationID: refreshConvID, Phone: "+15551234567",
+	})
+	if !errors.Is(err, clinic.ErrInactive) {
+		t.Fatalf("expected clinic.ErrInactive, got %v", err)
+	}
+}
+
+func TestAvailabilityGuardsMissingMoxieConfig(t *testing.T) {
+	// A clinic whose config fell back to the default has no Moxie config.
+	cfg := clinic.DefaultConfig("org-1")
+	cfg.BookingPlatform = "moxie"
+
+	if _, err := FetchAvailableTimesFromMoxieAPI(context.Background(), nil, cfg, "botox", TimePreferences{}, nil); err == nil {
+		t.Fatal("expected an error without a moxie config")
+	}
+	if (&MoxieAPISource{}).Supports(cfg) {
+		t.Fatal("the moxie API source must not claim a clinic without a moxie config")
+	}
+
+	messenger := &recordingMessenger{}
+	worker := NewWorker(&recordingService{}, newScriptedQueue(), &stubJobUpdater{}, messenger, nil, logging.Default())
+	msg := MessageRequest{OrgID: "org-1", ConversationID: "sms:org-1:12223334444", From: "+12223334444", To: "+15556667777"}
+	if worker.handleMoxieBookingDirect(context.Background(), msg, &BookingRequest{OrgID: "org-1", Service: "Botox"}, cfg) {
+		t.Fatal("expected the booking to be refused")
+	}
+	if replies := messenger.allReplies(); len(replies) != 1 {
+		t.Fatalf("expected the booking fallback text, got %+v", replies)
+	}
+}
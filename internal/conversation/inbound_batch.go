@@ -0,0 +1,270 @@
+package conversation
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// maxBatchWaitFactor caps how long a batch stays open, in windows, when the
+// patient keeps texting.
+const maxBatchWaitFactor = 3
+
+// inboundBatchRetention is how long a batch outlives its last text. It is
+// well past a queue's redelivery timeout, so a crashed leader's job still
+// finds its batch when it comes back.
+const inboundBatchRetention = 15 * time.Minute
+
+// InboundBatcher coalesces texts a patient sends in quick succession so the
+// conversation gets one LLM turn and one reply instead of one per text. The
+// first text opens a batch in Redis and its job leads it; later texts join
+// until the conversation has been quiet for the window. The leader and the
+// deadline live in Redis with the texts, so when a leader dies any job for
+// the conversation - the leader's own redelivery or a later text - can take
+// over once the deadline plus a grace window has passed, and flush it.
+type InboundBatcher struct {
+	redis   *redis.Client
+	window  time.Duration
+	maxWait time.Duration
+	now     func() time.Time
+}
+
+// NewInboundBatcher returns a batcher with the given quiet window, or nil
+// (batching disabled) without Redis or a positive window.
+func NewInboundBatcher(client *redis.Client, window time.Duration) *InboundBatcher {
+	if client == nil || window <= 0 {
+		return nil
+	}
+	return &InboundBatcher{
+		redis:   client,
+		window:  window,
+		maxWait: maxBatchWaitFactor * window,
+		now:     time.Now,
+	}
+}
+
+// batchedText is one inbound text held in a conversation's batch.
+type batchedText struct {
+	JobID      string    `json:"job_id"`
+	Text       string    `json:"text"`
+	ReceivedAt time.Time `json:"received_at"`
+}
+
+// batchRole is what a job should do after joining its conversation's batch.
+type batchRole int
+
+const (
+	// batchFollower: the text is held in a batch another job leads.
+	batchFollower batchRole = iota
+	// batchLeader: the job flushes the batch once it is ready.
+	batchLeader
+	// batchFlushed: the text was already answered in an earlier batch; the
+	// job is a stale copy or a redelivery.
+	batchFlushed
+)
+
+func inboundBatchKey(conversationID string) string {
+	return "conversation:inbound_batch:" + conversationID
+}
+
+// inboundBatchMetaKey holds the batch's leader, deadline, generation, and
+// which generation each job's text joined.
+func inboundBatchMetaKey(conversationID string) string {
+	return "conversation:inbound_batch_meta:" + conversationID
+}
+
+// joinInboundBatchScript makes the job leader when the batch has none, the
+// job already leads it, or the leader has missed the deadline by more than
+// the grace window; then it adds the job's text to the batch once and moves
+// the deadline out. It returns the job's role and milliseconds left until the
+// deadline.
+//
+// KEYS: texts, meta. ARGV: job ID, encoded text, now, window, max wait,
+// grace, TTL (all durations in ms).
+var joinInboundBatchScript = redis.NewScript(`
+local now = tonumber(ARGV[3])
+local gen = redis.call('HGET', KEYS[2], 'gen') or '0'
+local member = redis.call('HGET', KEYS[2], 'job:' .. ARGV[1])
+if member and member ~= gen then
+	return {2, 0}
+end
+local leader = redis.call('HGET', KEYS[2], 'leader')
+local deadline = redis.call('HGET', KEYS[2], 'deadline')
+local role = 0
+if not leader or leader == ARGV[1] or (deadline and now > tonumber(deadline) + tonumber(ARGV[6])) then
+	redis.call('HSET', KEYS[2], 'leader', ARGV[1])
+	role = 1
+end
+if not member then
+	redis.call('HSET', KEYS[2], 'job:' .. ARGV[1], gen)
+	redis.call('RPUSH', KEYS[1], ARGV[2])
+	local opened = redis.call('HGET', KEYS[2], 'opened')
+	if opened then
+		opened = tonumber(opened)
+	else
+		opened = now
+		redis.call('HSET', KEYS[2], 'opened', opened)
+	end
+	deadline = math.min(now + tonumber(ARGV[4]), opened + tonumber(ARGV[5]))
+	redis.call('HSET', KEYS[2], 'deadline', deadline)
+end
+redis.call('PEXPIRE', KEYS[1], ARGV[7])
+redis.call('PEXPIRE', KEYS[2], ARGV[7])
+return {role, tonumber(deadline) - now}
+`)
+
+// takeInboundBatchScript clears the batch and returns its texts if the job
+// still leads it, or nil if another job has taken over. The generation moves
+// on so late copies of the flushed jobs are recognized; members from older
+// generations are pruned.
+//
+// KEYS: texts, meta. ARGV: job ID.
+var takeInboundBatchScript = redis.NewScript(`
+if redis.call('HGET', KEYS[2], 'leader') ~= ARGV[1] then
+	return false
+end
+local texts = redis.call('LRANGE', KEYS[1], 0, -1)
+redis.call('DEL', KEYS[1])
+local gen = tonumber(redis.call('HGET', KEYS[2], 'gen') or '0')
+local fields = redis.call('HGETALL', KEYS[2])
+for i = 1, #fields, 2 do
+	if string.sub(fields[i], 1, 4) == 'job:' and tonumber(fields[i + 1]) < gen then
+		redis.call('HDEL', KEYS[2], fields[i])
+	end
+end
+redis.call('HDEL', KEYS[2], 'leader', 'opened', 'deadline')
+redis.call('HSET', KEYS[2], 'gen', gen + 1)
+return texts
+`)
+
+// Join adds a job's text to the conversation's batch, at most once per job,
+// and returns the job's role. A leader also gets how much longer the batch
+// should stay open: until the window has passed since the latest text, but
+// no later than maxWait after the first. Zero means the batch is ready.
+func (b *InboundBatcher) Join(ctx context.Context, conversationID, jobID, text string) (batchRole, time.Duration, error) {
+	now := b.now()
+	raw, err := json.Marshal(batchedText{JobID: jobID, Text: text, ReceivedAt: now.UTC()})
+	if err != nil {
+		return batchFollower, 0, fmt.Errorf("conversation: encode batched text: %w", err)
+	}
+	keys := []string{inboundBatchKey(conversationID), inboundBatchMetaKey(conversationID)}
+	res, err := joinInboundBatchScript.Run(ctx, b.redis, keys, jobID, raw, now.UnixMilli(),
+		b.window.Milliseconds(), b.maxWait.Milliseconds(), b.window.Milliseconds(),
+		(b.maxWait + inboundBatchRetention).Milliseconds()).Int64Slice()
+	if err != nil {
+		return batchFollower, 0, fmt.Errorf("conversation: join inbound batch: %w", err)
+	}
+	if len(res) != 2 {
+		return batchFollower, 0, fmt.Errorf("conversation: join inbound batch: unexpected reply %v", res)
+	}
+	role := batchRole(res[0])
+	wait := time.Duration(res[1]) * time.Millisecond
+	if role != batchLeader || wait < 0 {
+		wait = 0
+	}
+	return role, wait, nil
+}
+
+// Take atomically reads and clears the batch the job leads, returning its
+// texts in arrival order. ok is false when another job has taken the batch
+// over. A text added afterwards opens a new batch.
+func (b *InboundBatcher) Take(ctx context.Context, conversationID, jobID string) ([]batchedText, bool, error) {
+	keys := []string{inboundBatchKey(conversationID), inboundBatchMetaKey(conversationID)}
+	values, err := takeInboundBatchScript.Run(ctx, b.redis, keys, jobID).StringSlice()
+	if errors.Is(err, redis.Nil) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("conversation: take batched texts: %w", err)
+	}
+	return decodeBatchedTexts(values), true, nil
+}
+
+func decodeBatchedTexts(values []string) []batchedText {
+	texts := make([]batchedText, 0, len(values))
+	for _, v := range values {
+		var t batchedText
+		if err := json.Unmarshal([]byte(v), &t); err == nil {
+			texts = append(texts, t)
+		}
+	}
+	return texts
+}
+
+// combineBatchedTexts joins a batch into one user turn, one text per line.
+func combineBatchedTexts(texts []batchedText) string {
+	lines := make([]string, 0, len(texts))
+	for _, t := range texts {
+		if s := strings.TrimSpace(t.Text); s != "" {
+			lines = append(lines, s)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// coalesceInbound batches rapid-fire SMS texts. It returns false when the
+// job should not be processed now: its text is held in another job's batch,
+// or it leads a batch that is still collecting texts and has been requeued
+// to check back later. When it returns true for a batch, payload.Message
+// holds the combined texts. A job redelivered after its worker crashed
+// rejoins its own batch, so it picks up where the crashed leader left off.
+func (w *Worker) coalesceInbound(ctx context.Context, msg queueMessage, payload *queuePayload) bool {
+	b := w.batcher
+	convID := strings.TrimSpace(payload.Message.ConversationID)
+	if b == nil || payload.Kind != jobTypeMessage || payload.Message.Channel != ChannelSMS || convID == "" || payload.Coalesced {
+		return true
+	}
+
+	role, wait, err := b.Join(ctx, convID, payload.ID, payload.Message.Message)
+	if err != nil {
+		w.log(ctx).Warn("inbound batching unavailable; processing text alone", "error", err)
+		payload.Coalesced = true
+		return true
+	}
+	switch role {
+	case batchFollower:
+		w.log(ctx).Info("text joined open inbound batch")
+		w.completeBatchedJob(ctx, msg, *payload)
+		return false
+	case batchFlushed:
+		w.log(ctx).Info("text already answered in an earlier inbound batch; dropping job")
+		w.completeBatchedJob(ctx, msg, *payload)
+		return false
+	}
+	if wait > 0 {
+		w.requeueAfter(ctx, msg, *payload, wait)
+		return false
+	}
+
+	payload.Coalesced = true
+	texts, ok, err := b.Take(ctx, convID, payload.ID)
+	if err != nil {
+		w.log(ctx).Warn("failed to take inbound batch; processing text alone", "error", err)
+		return true
+	}
+	if !ok {
+		w.log(ctx).Info("inbound batch taken over by another job")
+		w.completeBatchedJob(ctx, msg, *payload)
+		return false
+	}
+	if len(texts) > 1 {
+		payload.Message.Message = combineBatchedTexts(texts)
+		w.log(ctx).Info("coalesced rapid inbound texts", "texts", len(texts))
+	}
+	return true
+}
+
+// completeBatchedJob acknowledges a job whose text another job answers.
+func (w *Worker) completeBatchedJob(ctx context.Context, msg queueMessage, payload queuePayload) {
+	if payload.TrackStatus {
+		if storeErr := w.jobs.MarkCompleted(ctx, payload.ID, nil, payload.Message.ConversationID); storeErr != nil {
+			w.log(ctx).Error("failed to update job status", "error", storeErr)
+		}
+	}
+	w.deleteMessage(context.Background(), msg.ReceiptHandle)
+}
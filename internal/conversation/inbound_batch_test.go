@@ -0,0 +1,234 @@
+package conversation
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	miniredis "github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+
+	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
+)
+
+// countingService records every message it is asked to answer.
+type countingService struct {
+	mu       sync.Mutex
+	messages []string
+}
+
+func (s *countingService) StartConversation(ctx context.Context, req StartRequest) (*Response, error) {
+	return &Response{}, nil
+}
+
+func (s *countingService) ProcessMessage(ctx context.Context, req MessageRequest) (*Response, error) {
+	s.mu.Lock()
+	s.messages = append(s.messages, req.Message)
+	s.mu.Unlock()
+	return &Response{ConversationID: req.ConversationID, Message: "Hi Sarah! Happy to help you book Botox."}, nil
+}
+
+func (s *countingService) GetHistory(ctx context.Context, conversationID string) ([]Message, error) {
+	return nil, nil
+}
+
+func (s *countingService) calls() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.messages...)
+}
+
+func TestWorkerCoalescesRapidInboundTexts(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	transcript := NewSMSTranscriptStore(client)
+	queue := NewMemoryQueue(16)
+	service := &countingService{}
+	messenger := &callbackMessenger{}
+	worker := NewWorker(service, queue, &stubJobUpdater{}, messenger, nil, logging.Default(),
+		WithWorkerCount(2), WithReceiveBatchSize(1), WithReceiveWaitSeconds(0),
+		WithInboundBatcher(NewInboundBatcher(client, 150*time.Millisecond)))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	worker.Start(ctx)
+
+	// Each webhook stores its text and queues a job, a few ms apart.
+	convID := "sms:org-1:15550001111"
+	for _, text := range []string{"Hi", "I want botox", "I'm new, it's Sarah"} {
+		if err := transcript.Append(ctx, convID, SMSTranscriptMessage{Role: "user", From: "+15550001111", To: "+15559990000", Body: text, Kind: "inbound"}); err != nil {
+			t.Fatalf("append transcript: %v", err)
+		}
+		body, _ := json.Marshal(queuePayload{ID: logging.NewID(), Kind: jobTypeMessage, Message: MessageRequest{
+			OrgID: "org-1", LeadID: "lead-1", ConversationID: convID, Message: text,
+			Channel: ChannelSMS, From: "+15550001111", To: "+15559990000",
+		}})
+		if err := queue.Send(ctx, string(body)); err != nil {
+			t.Fatalf("send: %v", err)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	waitFor(func() bool { return len(service.calls()) > 0 }, 2*time.Second, t)
+	// Give any stray job time to be (wrongly) processed.
+	time.Sleep(300 * time.Millisecond)
+	cancel()
+	worker.Wait()
+
+	calls := service.calls()
+	if len(calls) != 1 {
+		t.Fatalf("expected one LLM call, got %d: %q", len(calls), calls)
+	}
+	if want := "Hi\nI want botox\nI'm new, it's Sarah"; calls[0] != want {
+		t.Fatalf("combined turn = %q, want %q", calls[0], want)
+	}
+	messenger.mu.Lock()
+	replies := len(messenger.replies)
+	messenger.mu.Unlock()
+	if replies != 1 {
+		t.Fatalf("expected one reply, got %d", replies)
+	}
+	stored, err := transcript.List(context.Background(), convID, 0)
+	if err != nil {
+		t.Fatalf("list transcript: %v", err)
+	}
+	inbound := 0
+	for _, m := range stored {
+		if m.Role == "user" {
+			inbound++
+		}
+	}
+	if inbound != 3 {
+		t.Fatalf("expected three stored inbound messages, got %d", inbound)
+	}
+}
+
+func TestInboundBatcherWaitIsCappedAtMaxWait(t *testing.T) {
+	mr := miniredis.RunT(t)
+	b := NewInboundBatcher(redis.NewClient(&redis.Options{Addr: mr.Addr()}), 10*time.Second)
+	ctx := context.Background()
+	now := time.Date(2030, 1, 1, 9, 0, 0, 0, time.UTC)
+	b.now = func() time.Time { return now }
+
+	if role, _, err := b.Join(ctx, "conv", "job-1", "Hi"); err != nil || role != batchLeader {
+		t.Fatalf("first text should lead the batch (role=%v err=%v)", role, err)
+	}
+	// The patient keeps texting every 8s; the batch still closes 30s after it opened.
+	for i := 0; i < 3; i++ {
+		now = now.Add(8 * time.Second)
+		if role, _, _ := b.Join(ctx, "conv", fmt.Sprintf("job-%d", i+2), "more"); role != batchFollower {
+			t.Fatal("later texts should join the open batch")
+		}
+	}
+	if _, wait, _ := b.Join(ctx, "conv", "job-1", "Hi"); wait != 6*time.Second {
+		t.Fatalf("wait = %s, want 6s until the 30s cap", wait)
+	}
+	now = now.Add(6 * time.Second)
+	if _, wait, _ := b.Join(ctx, "conv", "job-1", "Hi"); wait != 0 {
+		t.Fatalf("wait = %s, want the batch ready", wait)
+	}
+	if _, ok, _ := b.Take(ctx, "conv", "job-2"); ok {
+		t.Fatal("only the leader may take the batch")
+	}
+	texts, ok, err := b.Take(ctx, "conv", "job-1")
+	if err != nil || !ok || len(texts) != 4 || texts[0].Text != "Hi" {
+		t.Fatalf("take = %+v ok=%v (err %v)", texts, ok, err)
+	}
+	if role, _, _ := b.Join(ctx, "conv", "job-5", "again"); role != batchLeader {
+		t.Fatal("a text after the take should open a new batch")
+	}
+	if role, _, _ := b.Join(ctx, "conv", "job-3", "more"); role != batchFlushed {
+		t.Fatal("a redelivered text from the flushed batch should be recognized")
+	}
+	if NewInboundBatcher(nil, time.Second) != nil || NewInboundBatcher(redis.NewClient(&redis.Options{Addr: mr.Addr()}), 0) != nil {
+		t.Fatal("expected batching disabled without redis or a window")
+	}
+}
+
+// batchJob is a message job as a worker would receive it for convID.
+func batchJob(id, convID, text string) (queueMessage, *queuePayload) {
+	return queueMessage{ID: id, ReceiptHandle: "rh-" + id}, &queuePayload{ID: id, Kind: jobTypeMessage, Message: MessageRequest{
+		OrgID: "org-1", LeadID: "lead-1", ConversationID: convID, Message: text,
+		Channel: ChannelSMS, From: "+15550001111", To: "+15559990000",
+	}}
+}
+
+func newBatchCrashWorker(t *testing.T) (*Worker, *InboundBatcher, *time.Time) {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	b := NewInboundBatcher(redis.NewClient(&redis.Options{Addr: mr.Addr()}), 10*time.Second)
+	now := time.Date(2030, 1, 1, 9, 0, 0, 0, time.UTC)
+	b.now = func() time.Time { return now }
+	worker := NewWorker(&countingService{}, NewMemoryQueue(16), &stubJobUpdater{}, &callbackMessenger{}, nil, logging.Default(),
+		WithInboundBatcher(b))
+	return worker, b, &now
+}
+
+func TestCoalesceInbound_RedeliveredLeaderFlushesAfterCrash(t *testing.T) {
+	worker, b, now := newBatchCrashWorker(t)
+	ctx := context.Background()
+	convID := "sms:org-1:15550001111"
+
+	// The leader opens the batch, then its worker dies before requeueing it.
+	if role, _, err := b.Join(ctx, convID, "job-1", "Hi"); err != nil || role != batchLeader {
+		t.Fatalf("expected job-1 to lead (role=%v err=%v)", role, err)
+	}
+	*now = now.Add(2 * time.Second)
+	msg, follower := batchJob("job-2", convID, "I want botox")
+	if worker.coalesceInbound(ctx, msg, follower) {
+		t.Fatal("the follower's text should wait in the batch")
+	}
+
+	// The queue redelivers the leader's original message after the deadline.
+	*now = now.Add(15 * time.Second)
+	msg, leader := batchJob("job-1", convID, "Hi")
+	if !worker.coalesceInbound(ctx, msg, leader) {
+		t.Fatal("the redelivered leader should flush its batch")
+	}
+	if want := "Hi\nI want botox"; leader.Message.Message != want {
+		t.Fatalf("combined turn = %q, want %q", leader.Message.Message, want)
+	}
+
+	// A second copy of the leader, e.g. from a requeue sent before the
+	// crash, must not answer the texts again.
+	msg, again := batchJob("job-1", convID, "Hi")
+	if worker.coalesceInbound(ctx, msg, again) {
+		t.Fatal("a late copy of the flushed leader should be dropped")
+	}
+}
+
+func TestCoalesceInbound_LaterTextTakesOverExpiredBatch(t *testing.T) {
+	worker, b, now := newBatchCrashWorker(t)
+	ctx := context.Background()
+	convID := "sms:org-1:15550001111"
+
+	// The leader dies and its message is never redelivered.
+	if role, _, _ := b.Join(ctx, convID, "job-1", "Hi"); role != batchLeader {
+		t.Fatal("expected job-1 to lead")
+	}
+	*now = now.Add(2 * time.Second)
+	msg, follower := batchJob("job-2", convID, "I want botox")
+	if worker.coalesceInbound(ctx, msg, follower) {
+		t.Fatal("the follower's text should wait while the leader's lease holds")
+	}
+
+	// Once the deadline plus the grace window passes, the next text leads
+	// and, past the max wait, flushes everything at once.
+	*now = now.Add(30 * time.Second)
+	msg, late := batchJob("job-3", convID, "Fridays work best")
+	if !worker.coalesceInbound(ctx, msg, late) {
+		t.Fatal("a text after the leader's lease expired should take the batch over")
+	}
+	if want := "Hi\nI want botox\nFridays work best"; late.Message.Message != want {
+		t.Fatalf("combined turn = %q, want %q", late.Message.Message, want)
+	}
+
+	// The crashed leader's requeued copy finds the batch gone.
+	msg, stale := batchJob("job-1", convID, "Hi")
+	if worker.coalesceInbound(ctx, msg, stale) {
+		t.Fatal("the old leader should not answer a batch another job flushed")
+	}
+}
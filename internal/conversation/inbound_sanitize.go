@@ -0,0 +1,157 @@
+package conversation
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/messaging/compliance"
+	"golang.org/x/text/unicode/norm"
+)
+
+// DefaultMaxInboundChars caps inbound text sent to the LLM and stored in
+// history. Ten concatenated SMS segments (1,530 GSM characters) fit under it,
+// so combined multi-part messages pass through intact.
+const DefaultMaxInboundChars = 1600
+
+// MediaPlaceholder stands in for an inbound message that carried only media.
+const MediaPlaceholder = "[media]"
+
+const (
+	longMessageReply = "That's a lot of text! 😅 I only read the first part — could you send me the main question in a sentence or two?"
+	linkOnlyReply    = "Thanks for the link! I'm not able to open links over text — could you tell me in a few words what you're looking for?"
+	mediaOnlyReply   = "Thanks for sending that! I can't view photos or attachments over text — could you tell me in a few words what you're looking for?"
+)
+
+// InboundText is a sanitized inbound message.
+type InboundText struct {
+	Text          string
+	Truncated     bool
+	OriginalChars int
+	LinkOnly      bool
+	MediaOnly     bool
+	// RoleMarkers is set when role prefixes like "SYSTEM:" were stripped.
+	RoleMarkers bool
+	// Sensitive lists the kinds of sensitive number ("card", "cvv", "ssn")
+	// redacted from Text, including any redacted upstream.
+	Sensitive []string
+}
+
+// HistoryText is the user turn recorded in history, noting any truncation so
+// later turns (and operators) know the patient sent more.
+func (t InboundText) HistoryText() string {
+	if !t.Truncated {
+		return t.Text
+	}
+	return fmt.Sprintf("%s [message truncated from %d characters]", t.Text, t.OriginalChars)
+}
+
+// CannedReply returns the targeted reply for messages that shouldn't reach
+// the LLM, or "".
+func (t InboundText) CannedReply() string {
+	switch {
+	case t.MediaOnly:
+		return mediaOnlyReply
+	case t.LinkOnly:
+		return linkOnlyReply
+	case t.Truncated:
+		return longMessageReply
+	}
+	return ""
+}
+
+var (
+	excessNewlines = regexp.MustCompile(`\n{3,}`)
+	excessSpaces   = regexp.MustCompile(`[ \t]{2,}`)
+	urlToken       = regexp.MustCompile(`(?i)^(https?://|www\.)\S+$`)
+	// roleMarkerRE matches a role prefix ("SYSTEM:", "[assistant]",
+	// "<system>", "</assistant>", "### developer:") at the start of a line or
+	// sentence.
+	roleMarkerRE    = regexp.MustCompile(`(?im)(^|[.!?][ \t]+)[ \t]*(?:\[[ \t]*(?:system|assistant|developer|user|human)[ \t]*\][ \t]*:?|<[ \t]*/?[ \t]*(?:system|assistant|developer|user|human)[ \t]*>[ \t]*:?|(?:#+[ \t]*)?(?:system|assistant|developer|user|human|ai|bot)(?:[ \t]+(?:message|note|prompt|update))?[ \t]*:)[ \t]*`)
+	mediaOnlyPhrase = regexp.MustCompile(`(?i)^(?:[<\[(]\s*(?:media|image|photo|picture|video|audio|attachment|gif|sticker|file|mms)s?(?:\s+(?:omitted|attached))?\s*[>\])]|(?:media|image|photo|picture|video|audio|attachment|gif|sticker|file)s?\s+(?:omitted|attached))$`)
+)
+
+// NormalizeInboundText applies NFKC normalization, drops control and
+// invisible formatting characters (zero-width spaces, bidi overrides, tag
+// characters used to smuggle hidden text), and tidies whitespace. The emoji
+// zero-width joiner is kept so composite emoji survive.
+func NormalizeInboundText(raw string) string {
+	raw = strings.ReplaceAll(raw, "\r\n", "\n")
+	raw = norm.NFKC.String(raw)
+
+	var sb strings.Builder
+	sb.Grow(len(raw))
+	for _, r := range raw {
+		switch {
+		case r == '\n':
+			sb.WriteRune(r)
+		case r == '\r':
+			sb.WriteRune('\n')
+		case r == '\t':
+			sb.WriteRune(' ')
+		case r == '\u200d':
+			sb.WriteRune(r)
+		case r == utf8.RuneError, unicode.IsControl(r), unicode.Is(unicode.Cf, r):
+			continue
+		default:
+			sb.WriteRune(r)
+		}
+	}
+
+	out := excessSpaces.ReplaceAllString(sb.String(), " ")
+	out = excessNewlines.ReplaceAllString(out, "\n\n")
+	return strings.TrimSpace(out)
+}
+
+// StripRoleMarkers removes role prefixes a patient typed to pass their text
+// off as a system or assistant turn. History carries the speaker in the
+// message role, never in its content, so the prefixes only serve to mislead.
+func StripRoleMarkers(text string) (string, bool) {
+	if !roleMarkerRE.MatchString(text) {
+		return text, false
+	}
+	return strings.TrimSpace(roleMarkerRE.ReplaceAllString(text, "$1")), true
+}
+
+// SanitizeInbound normalizes raw, strips role markers, redacts card numbers,
+// security codes and SSNs, caps it at maxChars runes (cutting at a word
+// boundary when possible), and flags link-only and media-only messages.
+// maxChars <= 0 uses DefaultMaxInboundChars.
+func SanitizeInbound(raw string, maxChars int) InboundText {
+	if maxChars <= 0 {
+		maxChars = DefaultMaxInboundChars
+	}
+	text, markers := StripRoleMarkers(NormalizeInboundText(raw))
+	text, sensitive := compliance.RedactSensitiveNumbers(text)
+	out := InboundText{Text: text, OriginalChars: utf8.RuneCountInString(text), RoleMarkers: markers, Sensitive: sensitive}
+
+	if out.OriginalChars > maxChars {
+		runes := []rune(text)
+		cut := string(runes[:maxChars])
+		if i := strings.LastIndexAny(cut, " \n"); i > len(cut)*4/5 {
+			cut = cut[:i]
+		}
+		out.Text = strings.TrimSpace(cut)
+		out.Truncated = true
+		return out
+	}
+
+	out.MediaOnly = mediaOnlyPhrase.MatchString(text)
+	out.LinkOnly = !out.MediaOnly && isLinkOnly(text)
+	return out
+}
+
+func isLinkOnly(text string) bool {
+	fields := strings.Fields(text)
+	if len(fields) == 0 {
+		return false
+	}
+	for _, f := range fields {
+		if !urlToken.MatchString(strings.TrimRight(f, ".,!?)")) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,149 @@
+package conversation
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestNormalizeInboundText(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  string
+		want string
+	}{
+		{"control chars", "Book\x00 Botox\x07 please\x1b", "Book Botox please"},
+		{"zero width and bidi", "bo\u200btox\u202e on friday\ufeff", "botox on friday"},
+		{"tag characters", "hi\U000E0069\U000E0067\U000E006E\U000E006F\U000E0072\U000E0065 there", "hi there"},
+		{"fullwidth folded", "\uff42\uff4f\uff54\uff4f\uff58", "botox"},
+		{"crlf and blank lines", "line one\r\n\r\n\r\n\r\nline two\r", "line one\n\nline two"},
+		{"tabs and spaces", "a\t\t b   c", "a b c"},
+		{"emoji zwj kept", "\U0001F469\u200d\u2695\ufe0f appointment", "\U0001F469\u200d\u2695\ufe0f appointment"},
+		{"only control chars", "\x00\x01\u200b", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NormalizeInboundText(tt.raw); got != tt.want {
+				t.Fatalf("NormalizeInboundText(%q) = %q, want %q", tt.raw, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSanitizeInbound_Cap(t *testing.T) {
+	article := strings.Repeat("lorem ipsum dolor sit amet ", 500) // 13,499 chars once trimmed
+	got := SanitizeInbound(article, 1600)
+	if !got.Truncated {
+		t.Fatal("expected truncation")
+	}
+	if n := len([]rune(got.Text)); n > 1600 || n < 1500 {
+		t.Fatalf("truncated length = %d", n)
+	}
+	if strings.HasSuffix(got.Text, "lor") || strings.HasSuffix(got.Text, " ") {
+		t.Fatalf("expected cut at a word boundary, got suffix %q", got.Text[len(got.Text)-10:])
+	}
+	if !strings.Contains(got.HistoryText(), "[message truncated from 13499 characters]") {
+		t.Fatalf("history text missing truncation note: %q", got.HistoryText()[len(got.HistoryText())-60:])
+	}
+	if got.CannedReply() != longMessageReply {
+		t.Fatalf("expected long message reply, got %q", got.CannedReply())
+	}
+
+	// Ten combined SMS segments stay intact.
+	combined := strings.Repeat("a", 153*10)
+	if got := SanitizeInbound(combined, 1600); got.Truncated || got.Text != combined {
+		t.Fatal("combined multi-part message below the cap was altered")
+	}
+}
+
+func TestSanitizeInbound_LinkAndMediaOnly(t *testing.T) {
+	tests := []struct {
+		raw       string
+		linkOnly  bool
+		mediaOnly bool
+	}{
+		{"https://example.com/botox-deal", true, false},
+		{"www.example.com/a https://example.com/b.", true, false},
+		{"Is this deal still on? https://example.com/botox-deal", false, false},
+		{MediaPlaceholder, false, true},
+		{"<Media omitted>", false, true},
+		{"image omitted", false, true},
+		{"Can I send a photo?", false, false},
+		{"😊", false, false},
+	}
+	for _, tt := range tests {
+		got := SanitizeInbound(tt.raw, 0)
+		if got.LinkOnly != tt.linkOnly || got.MediaOnly != tt.mediaOnly {
+			t.Errorf("SanitizeInbound(%q): link=%v media=%v, want link=%v media=%v", tt.raw, got.LinkOnly, got.MediaOnly, tt.linkOnly, tt.mediaOnly)
+		}
+	}
+}
+
+func TestProcessMessage_InboundLimits(t *testing.T) {
+	tests := []struct {
+		name      string
+		message   string
+		wantReply string
+		wantUser  string
+	}{
+		{"too long", strings.Repeat("word ", 1000), longMessageReply, "[message truncated from 4999 characters]"},
+		{"link only", "https://example.com/promo", linkOnlyReply, "https://example.com/promo"},
+		{"media only", MediaPlaceholder, mediaOnlyReply, MediaPlaceholder},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := setupService(t, withLLMResponses("Hi! How can I help?", "Should not see"))
+			startConv(t, ts, "conv-limits", "org-1", "Hi")
+			calls := len(ts.llm.requests)
+
+			resp, err := ts.svc.ProcessMessage(context.Background(), MessageRequest{
+				ConversationID: "conv-limits",
+				OrgID:          "org-1",
+				Message:        tt.message,
+				Channel:        ChannelSMS,
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if resp.Message != tt.wantReply {
+				t.Fatalf("reply = %q, want %q", resp.Message, tt.wantReply)
+			}
+			if len(ts.llm.requests) != calls {
+				t.Fatal("LLM should not be called")
+			}
+
+			h := getHistory(t, ts.mr, "conv-limits")
+			user := h[len(h)-2]
+			if user.Role != ChatRoleUser || !strings.Contains(user.Content, tt.wantUser) {
+				t.Fatalf("user turn = %+v, want it to contain %q", user, tt.wantUser)
+			}
+			if h[len(h)-1].Content != tt.wantReply {
+				t.Fatalf("assistant turn = %q", h[len(h)-1].Content)
+			}
+		})
+	}
+}
+
+func TestProcessMessage_UnknownConvLongMessage(t *testing.T) {
+	ts := setupService(t, withLLMResponses("Should not see"))
+
+	resp, err := ts.svc.ProcessMessage(context.Background(), MessageRequest{
+		ConversationID: "conv-long",
+		OrgID:          "org-1",
+		Message:        strings.Repeat("word ", 1000),
+		Channel:        ChannelSMS,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.Message != longMessageReply {
+		t.Fatalf("reply = %q", resp.Message)
+	}
+	if len(ts.llm.requests) != 0 {
+		t.Fatal("LLM should not be called")
+	}
+	h := getHistory(t, ts.mr, "conv-long")
+	if !strings.Contains(h[len(h)-2].Content, "[message truncated from 4999 characters]") {
+		t.Fatalf("intro missing truncation note: %q", h[len(h)-2].Content)
+	}
+}
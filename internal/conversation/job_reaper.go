@@ -0,0 +1,197 @@
+package conversation
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
+)
+
+const (
+	// DefaultJobStaleAfter is how long a job may sit pending before the reaper
+	// treats its worker as lost. Healthy jobs finish in well under a minute.
+	DefaultJobStaleAfter  = time.Hour
+	defaultReaperInterval = 5 * time.Minute
+	defaultReaperBatch    = 50
+
+	// reapedJobSuffix marks a job the reaper re-published. It is appended to
+	// the original ID so a job is only ever re-published once.
+	reapedJobSuffix = ":reaped"
+)
+
+// Reaper actions recorded on the job_reaper_total metric.
+const (
+	ReaperActionCompleted   = "completed"
+	ReaperActionRepublished = "republished"
+	ReaperActionOrphaned    = "orphaned"
+)
+
+var jobReaperTotal = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Namespace: "medspa",
+		Subsystem: "conversation",
+		Name:      "job_reaper_total",
+		Help:      "Stale pending jobs resolved by the job reaper, by action",
+	},
+	[]string{"action"},
+)
+
+var jobReaperStale = prometheus.NewGauge(
+	prometheus.GaugeOpts{
+		Namespace: "medspa",
+		Subsystem: "conversation",
+		Name:      "job_reaper_stale_jobs",
+		Help:      "Stale pending jobs found by the last reaper pass",
+	},
+)
+
+func init() {
+	prometheus.MustRegister(jobReaperTotal, jobReaperStale)
+}
+
+// ReplyLookup reports whether a clinic texted a patient since a given time.
+type ReplyLookup interface {
+	HasOutboundSince(ctx context.Context, clinicID uuid.UUID, to string, since time.Time) (bool, error)
+}
+
+// JobReaper resolves jobs left pending by a worker that died mid-job. A job
+// whose reply already went out is marked completed; otherwise its request is
+// re-published once and the original is marked failed as orphaned.
+type JobReaper struct {
+	lister     StaleJobLister
+	updater    JobUpdater
+	publisher  *Publisher
+	replies    ReplyLookup
+	logger     *logging.Logger
+	interval   time.Duration
+	batchSize  int
+	staleAfter time.Duration
+	now        func() time.Time
+}
+
+// NewJobReaper creates a reaper over the job store.
+func NewJobReaper(lister StaleJobLister, updater JobUpdater, publisher *Publisher, logger *logging.Logger) *JobReaper {
+	if lister == nil {
+		panic("conversation: stale job lister cannot be nil")
+	}
+	if updater == nil {
+		panic("conversation: job updater cannot be nil")
+	}
+	if logger == nil {
+		logger = logging.Default()
+	}
+	return &JobReaper{
+		lister:     lister,
+		updater:    updater,
+		publisher:  publisher,
+		logger:     logger,
+		interval:   defaultReaperInterval,
+		batchSize:  defaultReaperBatch,
+		staleAfter: DefaultJobStaleAfter,
+		now:        time.Now,
+	}
+}
+
+// SetReplyLookup enables checking whether a stale job's reply was already
+// sent. Without it, no job is re-published: a duplicate reply is worse than
+// a missing one.
+func (r *JobReaper) SetReplyLookup(replies ReplyLookup) {
+	r.replies = replies
+}
+
+// SetStaleAfter overrides how long a job may stay pending.
+func (r *JobReaper) SetStaleAfter(d time.Duration) {
+	if d > 0 {
+		r.staleAfter = d
+	}
+}
+
+// Run reaps stale jobs every interval until ctx is cancelled.
+func (r *JobReaper) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+	for {
+		if _, err := r.RunOnce(ctx); err != nil {
+			r.logger.Warn("job reaper pass failed", "error", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce resolves one batch of stale jobs and returns how many it found.
+func (r *JobReaper) RunOnce(ctx context.Context) (int, error) {
+	jobs, err := r.lister.ListStale(ctx, r.now().Add(-r.staleAfter), r.batchSize)
+	if err != nil {
+		return 0, err
+	}
+	jobReaperStale.Set(float64(len(jobs)))
+	for i := range jobs {
+		action, err := r.reap(ctx, &jobs[i])
+		if err != nil {
+			r.logger.Warn("failed to reap stale job", "error", err, "job_id", jobs[i].JobID)
+			continue
+		}
+		jobReaperTotal.WithLabelValues(action).Inc()
+		r.logger.Info("stale job reaped", "job_id", jobs[i].JobID, "action", action, "updated_at", jobs[i].UpdatedAt)
+	}
+	return len(jobs), nil
+}
+
+func (r *JobReaper) reap(ctx context.Context, job *JobRecord) (string, error) {
+	orgID, from := jobRecipient(job)
+	if orgID == "" || from == "" {
+		return ReaperActionOrphaned, r.updater.MarkFailed(ctx, job.JobID, "orphaned: worker never finished the job")
+	}
+
+	replied, err := r.replySent(ctx, job, orgID, from)
+	if err != nil {
+		return "", err
+	}
+	if replied {
+		return ReaperActionCompleted, r.updater.MarkCompleted(ctx, job.JobID, nil, job.ConversationID)
+	}
+	if r.replies == nil || r.publisher == nil || strings.HasSuffix(job.JobID, reapedJobSuffix) {
+		return ReaperActionOrphaned, r.updater.MarkFailed(ctx, job.JobID, "orphaned: worker never finished the job")
+	}
+
+	retryID := job.JobID + reapedJobSuffix
+	if err := r.publisher.Republish(ctx, job, retryID); err != nil {
+		return "", err
+	}
+	return ReaperActionRepublished, r.updater.MarkFailed(ctx, job.JobID, "orphaned: re-published as "+retryID)
+}
+
+// replySent reports whether the clinic texted the patient after the job was
+// created. Jobs from non-SMS channels never match and are re-published.
+func (r *JobReaper) replySent(ctx context.Context, job *JobRecord, orgID, from string) (bool, error) {
+	if r.replies == nil {
+		return false, nil
+	}
+	clinicID, err := uuid.Parse(orgID)
+	if err != nil {
+		return false, nil
+	}
+	since, err := time.Parse(time.RFC3339Nano, job.CreatedAt)
+	if err != nil {
+		return false, fmt.Errorf("conversation: job %s has invalid created_at: %w", job.JobID, err)
+	}
+	return r.replies.HasOutboundSince(ctx, clinicID, from, since)
+}
+
+func jobRecipient(job *JobRecord) (orgID, from string) {
+	switch {
+	case job.StartRequest != nil:
+		return job.StartRequest.OrgID, job.StartRequest.From
+	case job.MessageRequest != nil:
+		return job.MessageRequest.OrgID, job.MessageRequest.From
+	}
+	return "", ""
+}
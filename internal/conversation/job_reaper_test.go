@@ -0,0 +1,109 @@
+package conversation
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
+)
+
+type stubStaleLister struct {
+	jobs   []JobRecord
+	cutoff time.Time
+}
+
+func (s *stubStaleLister) ListStale(ctx context.Context, olderThan time.Time, limit int) ([]JobRecord, error) {
+	s.cutoff = olderThan
+	return s.jobs, nil
+}
+
+type stubReplyLookup map[string]bool
+
+func (s stubReplyLookup) HasOutboundSince(ctx context.Context, clinicID uuid.UUID, to string, since time.Time) (bool, error) {
+	return s[to], nil
+}
+
+func staleMessageJob(jobID, from string) JobRecord {
+	created := time.Now().Add(-2 * time.Hour).UTC().Format(time.RFC3339Nano)
+	return JobRecord{
+		JobID:          jobID,
+		Status:         JobStatusPending,
+		RequestType:    jobTypeMessage,
+		ConversationID: "sms:org:" + from,
+		MessageRequest: &MessageRequest{OrgID: uuid.NewString(), From: from, Message: "Do you have Friday?"},
+		CreatedAt:      created,
+		UpdatedAt:      created,
+	}
+}
+
+func newTestReaper(jobs []JobRecord, replies ReplyLookup) (*JobReaper, *stubJobUpdater, *stubQueue, *stubJobRecorder) {
+	updater := &stubJobUpdater{}
+	queue := &stubQueue{}
+	recorder := &stubJobRecorder{}
+	reaper := NewJobReaper(&stubStaleLister{jobs: jobs}, updater, NewPublisher(queue, recorder, logging.Default()), logging.Default())
+	reaper.SetReplyLookup(replies)
+	return reaper, updater, queue, recorder
+}
+
+func TestJobReaper_RepublishesWhenNoReplyWasSent(t *testing.T) {
+	reaper, updater, queue, recorder := newTestReaper([]JobRecord{staleMessageJob("job-1", "+15550000001")}, stubReplyLookup{})
+
+	n, err := reaper.RunOnce(context.Background())
+	if err != nil || n != 1 {
+		t.Fatalf("RunOnce = %d, %v", n, err)
+	}
+	if len(queue.sent) != 1 || !strings.Contains(queue.sent[0], "Do you have Friday?") {
+		t.Fatalf("expected the original message re-published, got %v", queue.sent)
+	}
+	if len(recorder.jobs) != 1 || recorder.jobs[0].JobID != "job-1"+reapedJobSuffix {
+		t.Fatalf("expected a fresh record for the retry, got %+v", recorder.jobs)
+	}
+	if len(updater.failed) != 1 || updater.failed[0].jobID != "job-1" || !strings.HasPrefix(updater.failed[0].err, "orphaned: re-published") {
+		t.Fatalf("expected the original marked orphaned, got %+v", updater.failed)
+	}
+	if len(updater.completed) != 0 {
+		t.Fatalf("expected nothing completed, got %v", updater.completed)
+	}
+}
+
+func TestJobReaper_MarksCompletedWhenReplyWasSent(t *testing.T) {
+	reaper, updater, queue, _ := newTestReaper([]JobRecord{staleMessageJob("job-1", "+15550000001")}, stubReplyLookup{"+15550000001": true})
+
+	if _, err := reaper.RunOnce(context.Background()); err != nil {
+		t.Fatalf("RunOnce: %v", err)
+	}
+	if len(updater.completed) != 1 || updater.completed[0] != "job-1" {
+		t.Fatalf("expected job-1 completed, got %v", updater.completed)
+	}
+	if len(queue.sent) != 0 || len(updater.failed) != 0 {
+		t.Fatalf("expected no re-publish, got sent %v, failed %+v", queue.sent, updater.failed)
+	}
+}
+
+func TestJobReaper_OrphansInsteadOfRepublishing(t *testing.T) {
+	retried := staleMessageJob("job-1"+reapedJobSuffix, "+15550000001")
+	noRequest := JobRecord{JobID: "job-2", Status: JobStatusPending, RequestType: jobTypeStart}
+	reaper, updater, queue, _ := newTestReaper([]JobRecord{retried, noRequest}, stubReplyLookup{})
+
+	if _, err := reaper.RunOnce(context.Background()); err != nil {
+		t.Fatalf("RunOnce: %v", err)
+	}
+	if len(queue.sent) != 0 {
+		t.Fatalf("expected no re-publish, got %v", queue.sent)
+	}
+	if len(updater.failed) != 2 {
+		t.Fatalf("expected both jobs orphaned, got %+v", updater.failed)
+	}
+
+	// Without a reply lookup the reaper can't rule out a duplicate reply.
+	reaper, updater, queue, _ = newTestReaper([]JobRecord{staleMessageJob("job-3", "+15550000003")}, nil)
+	if _, err := reaper.RunOnce(context.Background()); err != nil {
+		t.Fatalf("RunOnce: %v", err)
+	}
+	if len(queue.sent) != 0 || len(updater.failed) != 1 {
+		t.Fatalf("expected job-3 orphaned without re-publish, got sent %v, failed %+v", queue.sent, updater.failed)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sort"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
@@ -33,6 +34,7 @@ type dynamoAPI interface {
 	PutItem(context.Context, *dynamodb.PutItemInput, ...func(*dynamodb.Options)) (*dynamodb.PutItemOutput, error)
 	UpdateItem(context.Context, *dynamodb.UpdateItemInput, ...func(*dynamodb.Options)) (*dynamodb.UpdateItemOutput, error)
 	GetItem(context.Context, *dynamodb.GetItemInput, ...func(*dynamodb.Options)) (*dynamodb.GetItemOutput, error)
+	Scan(context.Context, *dynamodb.ScanInput, ...func(*dynamodb.Options)) (*dynamodb.ScanOutput, error)
 }
 
 // JobRecord captures the persisted state of a conversation request.
@@ -45,9 +47,13 @@ type JobRecord struct {
 	MessageRequest *MessageRequest `dynamodbav:"messageRequest,omitempty" json:"messageRequest,omitempty"`
 	Response       *Response       `dynamodbav:"response,omitempty" json:"response,omitempty"`
 	ErrorMessage   string          `dynamodbav:"errorMessage,omitempty" json:"errorMessage,omitempty"`
-	CreatedAt      string          `dynamodbav:"createdAt" json:"createdAt"`
-	UpdatedAt      string          `dynamodbav:"updatedAt" json:"updatedAt"`
-	ExpiresAt      int64           `dynamodbav:"expiresAt,omitempty" json:"-"`
+	AckSent        bool            `dynamodbav:"ackSent,omitempty" json:"ackSent,omitempty"`
+	// Timings is the latency breakdown of a message job's turn, recorded
+	// once its reply is sent.
+	Timings   *TurnTimings `dynamodbav:"timings,omitempty" json:"timings,omitempty"`
+	CreatedAt string       `dynamodbav:"createdAt" json:"createdAt"`
+	UpdatedAt string       `dynamodbav:"updatedAt" json:"updatedAt"`
+	ExpiresAt int64        `dynamodbav:"expiresAt,omitempty" json:"-"`
 }
 
 // JobStore persists job records to DynamoDB.
@@ -61,6 +67,19 @@ type JobUpdater interface {
 	MarkFailed(ctx context.Context, jobID string, errMsg string) error
 }
 
+// JobAckTracker records that a job already texted the patient a progress
+// acknowledgement, so a retry after shutdown doesn't send it again.
+type JobAckTracker interface {
+	MarkAckSent(ctx context.Context, jobID string) error
+	AckSent(ctx context.Context, jobID string) (bool, error)
+}
+
+// StaleJobLister lists jobs still pending that haven't been updated since
+// olderThan, oldest first: jobs a crashed worker never finished.
+type StaleJobLister interface {
+	ListStale(ctx context.Context, olderThan time.Time, limit int) ([]JobRecord, error)
+}
+
 type JobStore struct {
 	client    dynamoAPI
 	tableName string
@@ -69,6 +88,9 @@ type JobStore struct {
 
 var _ JobRecorder = (*JobStore)(nil)
 var _ JobUpdater = (*JobStore)(nil)
+var _ JobAckTracker = (*JobStore)(nil)
+var _ StaleJobLister = (*JobStore)(nil)
+var _ JobTimingRecorder = (*JobStore)(nil)
 
 // NewJobStore builds a store backed by the provided DynamoDB client.
 func NewJobStore(client dynamoAPI, tableName string, logger *logging.Logger) *JobStore {
@@ -175,6 +197,60 @@ func (s *JobStore) MarkFailed(ctx context.Context, jobID string, errMsg string)
 	)
 }
 
+// MarkAckSent records that the job's progress acknowledgement went out.
+func (s *JobStore) MarkAckSent(ctx context.Context, jobID string) error {
+	if jobID == "" {
+		return errors.New("conversation: jobID required")
+	}
+	return s.updateJob(
+		ctx,
+		jobID,
+		map[string]types.AttributeValue{
+			":ack":     &types.AttributeValueMemberBOOL{Value: true},
+			":updated": &types.AttributeValueMemberS{Value: time.Now().UTC().Format(time.RFC3339Nano)},
+		},
+		map[string]string{
+			"#ack":     "ackSent",
+			"#updated": "updatedAt",
+		},
+		"SET #ack = :ack, #updated = :updated",
+	)
+}
+
+// RecordTimings stores the latency breakdown of the job's turn.
+func (s *JobStore) RecordTimings(ctx context.Context, jobID string, timings TurnTimings) error {
+	if jobID == "" {
+		return errors.New("conversation: jobID required")
+	}
+	timingsAttr, err := attributevalue.Marshal(timings)
+	if err != nil {
+		return fmt.Errorf("conversation: failed to marshal timings: %w", err)
+	}
+	return s.updateJob(
+		ctx,
+		jobID,
+		map[string]types.AttributeValue{
+			":timings": timingsAttr,
+			":updated": &types.AttributeValueMemberS{Value: time.Now().UTC().Format(time.RFC3339Nano)},
+		},
+		map[string]string{
+			"#timings": "timings",
+			"#updated": "updatedAt",
+		},
+		"SET #timings = :timings, #updated = :updated",
+	)
+}
+
+// AckSent reports whether an earlier attempt already sent the job's progress
+// acknowledgement.
+func (s *JobStore) AckSent(ctx context.Context, jobID string) (bool, error) {
+	job, err := s.GetJob(ctx, jobID)
+	if err != nil {
+		return false, err
+	}
+	return job.AckSent, nil
+}
+
 // GetJob fetches a job by ID.
 func (s *JobStore) GetJob(ctx context.Context, jobID string) (*JobRecord, error) {
 	if jobID == "" {
@@ -200,6 +276,50 @@ func (s *JobStore) GetJob(ctx context.Context, jobID string) (*JobRecord, error)
 	return &job, nil
 }
 
+// ListStale scans for pending jobs last updated before olderThan. Job records
+// expire after a day, so the scan stays small.
+func (s *JobStore) ListStale(ctx context.Context, olderThan time.Time, limit int) ([]JobRecord, error) {
+	if limit <= 0 {
+		return nil, nil
+	}
+	input := &dynamodb.ScanInput{
+		TableName:        aws.String(s.tableName),
+		FilterExpression: aws.String("#status = :pending AND #updated < :cutoff"),
+		ExpressionAttributeNames: map[string]string{
+			"#status":  "status",
+			"#updated": "updatedAt",
+		},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":pending": &types.AttributeValueMemberS{Value: string(JobStatusPending)},
+			":cutoff":  &types.AttributeValueMemberS{Value: olderThan.UTC().Format(time.RFC3339Nano)},
+		},
+	}
+	var jobs []JobRecord
+	for {
+		out, err := s.client.Scan(ctx, input)
+		if err != nil {
+			return nil, fmt.Errorf("conversation: failed to scan stale jobs: %w", err)
+		}
+		for _, item := range out.Items {
+			var job JobRecord
+			if err := attributevalue.UnmarshalMap(item, &job); err != nil {
+				return nil, fmt.Errorf("conversation: failed to decode job: %w", err)
+			}
+			jobs = append(jobs, job)
+		}
+		if len(out.LastEvaluatedKey) == 0 {
+			break
+		}
+		input.ExclusiveStartKey = out.LastEvaluatedKey
+	}
+	// A scan returns items in hash order.
+	sort.Slice(jobs, func(i, j int) bool { return jobs[i].UpdatedAt < jobs[j].UpdatedAt })
+	if len(jobs) > limit {
+		jobs = jobs[:limit]
+	}
+	return jobs, nil
+}
+
 func (s *JobStore) updateJob(ctx context.Context, jobID string, values map[string]types.AttributeValue, names map[string]string, expression string) error {
 	_, err := s.client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
 		TableName: aws.String(s.tableName),
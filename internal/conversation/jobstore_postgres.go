@@ -9,13 +9,20 @@ import (
 	"time"
 
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+type pgJobDB interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
 // PGJobStore persists job records to PostgreSQL for bootstrap deployments.
 type PGJobStore struct {
-	db *pgxpool.Pool
+	db pgJobDB
 }
 
 // NewPGJobStore builds a Postgres-backed JobStore.
@@ -28,6 +35,10 @@ func NewPGJobStore(db *pgxpool.Pool) *PGJobStore {
 
 var _ JobRecorder = (*PGJobStore)(nil)
 var _ JobUpdater = (*PGJobStore)(nil)
+var _ OverflowStore = (*PGJobStore)(nil)
+var _ JobAckTracker = (*PGJobStore)(nil)
+var _ StaleJobLister = (*PGJobStore)(nil)
+var _ JobTimingRecorder = (*PGJobStore)(nil)
 
 // PutPending inserts a pending job record.
 func (s *PGJobStore) PutPending(ctx context.Context, job *JobRecord) error {
@@ -121,13 +132,124 @@ func (s *PGJobStore) MarkFailed(ctx context.Context, jobID string, errMsg string
 	return nil
 }
 
+// MarkAckSent records that the job's progress acknowledgement went out.
+func (s *PGJobStore) MarkAckSent(ctx context.Context, jobID string) error {
+	if jobID == "" {
+		return errors.New("conversation: jobID required")
+	}
+	result, err := s.db.Exec(ctx, `
+		UPDATE conversation_jobs
+		SET ack_sent = TRUE, updated_at = $2
+		WHERE job_id = $1
+	`, jobID, time.Now().UTC())
+	if err != nil {
+		return fmt.Errorf("conversation: failed to mark job ack sent: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return ErrJobNotFound
+	}
+	return nil
+}
+
+// AckSent reports whether an earlier attempt already sent the job's progress
+// acknowledgement.
+func (s *PGJobStore) AckSent(ctx context.Context, jobID string) (bool, error) {
+	if jobID == "" {
+		return false, errors.New("conversation: jobID required")
+	}
+	var sent bool
+	err := s.db.QueryRow(ctx, `SELECT ack_sent FROM conversation_jobs WHERE job_id = $1`, jobID).Scan(&sent)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, ErrJobNotFound
+	}
+	if err != nil {
+		return false, fmt.Errorf("conversation: failed to fetch job ack: %w", err)
+	}
+	return sent, nil
+}
+
+// RecordTimings stores the latency breakdown of the job's turn.
+func (s *PGJobStore) RecordTimings(ctx context.Context, jobID string, timings TurnTimings) error {
+	if jobID == "" {
+		return errors.New("conversation: jobID required")
+	}
+	timingsJSON, err := marshalJSON(timings)
+	if err != nil {
+		return fmt.Errorf("conversation: RecordTimings: %w", err)
+	}
+	result, err := s.db.Exec(ctx, `
+		UPDATE conversation_jobs
+		SET timings = $2, updated_at = $3
+		WHERE job_id = $1
+	`, jobID, timingsJSON, time.Now().UTC())
+	if err != nil {
+		return fmt.Errorf("conversation: failed to record job timings: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return ErrJobNotFound
+	}
+	return nil
+}
+
 // GetJob loads a job by ID.
 func (s *PGJobStore) GetJob(ctx context.Context, jobID string) (*JobRecord, error) {
 	if jobID == "" {
 		return nil, errors.New("conversation: jobID required")
 	}
 
+	row := s.db.QueryRow(ctx, `
+		SELECT `+pgJobColumns+`
+		FROM conversation_jobs
+		WHERE job_id = $1
+	`, jobID)
+	job, err := scanPGJob(row)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrJobNotFound
+		}
+		return nil, fmt.Errorf("conversation: failed to fetch job: %w", err)
+	}
+	return job, nil
+}
+
+// ListStale returns pending jobs last updated before olderThan, oldest first.
+func (s *PGJobStore) ListStale(ctx context.Context, olderThan time.Time, limit int) ([]JobRecord, error) {
+	if limit <= 0 {
+		return nil, nil
+	}
+	rows, err := s.db.Query(ctx, `
+		SELECT `+pgJobColumns+`
+		FROM conversation_jobs
+		WHERE status = $1 AND updated_at < $2
+		ORDER BY updated_at
+		LIMIT $3
+	`, JobStatusPending, olderThan.UTC(), limit)
+	if err != nil {
+		return nil, fmt.Errorf("conversation: failed to list stale jobs: %w", err)
+	}
+	defer rows.Close()
+
+	var jobs []JobRecord
+	for rows.Next() {
+		job, err := scanPGJob(rows)
+		if err != nil {
+			return nil, fmt.Errorf("conversation: failed to scan stale job: %w", err)
+		}
+		jobs = append(jobs, *job)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("conversation: failed to list stale jobs: %w", err)
+	}
+	return jobs, nil
+}
+
+const pgJobColumns = `job_id, status, request_type, conversation_id,
+		       start_request, message_request, response, error_message,
+		       created_at, updated_at, expires_at, ack_sent, timings`
+
+func scanPGJob(row pgx.Row) (*JobRecord, error) {
 	var (
+		jobID        string
 		startJSON    []byte
 		messageJSON  []byte
 		responseJSON []byte
@@ -138,23 +260,13 @@ func (s *PGJobStore) GetJob(ctx context.Context, jobID string) (*JobRecord, erro
 		status       string
 		reqType      string
 		errMsg       string
+		ackSent      bool
+		timingsJSON  []byte
 	)
-
-	row := s.db.QueryRow(ctx, `
-		SELECT job_id, status, request_type, conversation_id,
-		       start_request, message_request, response, error_message,
-		       created_at, updated_at, expires_at
-		FROM conversation_jobs
-		WHERE job_id = $1
-	`, jobID)
-
 	if err := row.Scan(&jobID, &status, &reqType, &convoID,
 		&startJSON, &messageJSON, &responseJSON, &errMsg,
-		&createdAt, &updatedAt, &expiresAt); err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
-			return nil, ErrJobNotFound
-		}
-		return nil, fmt.Errorf("conversation: failed to fetch job: %w", err)
+		&createdAt, &updatedAt, &expiresAt, &ackSent, &timingsJSON); err != nil {
+		return nil, err
 	}
 
 	job := &JobRecord{
@@ -162,6 +274,7 @@ func (s *PGJobStore) GetJob(ctx context.Context, jobID string) (*JobRecord, erro
 		Status:       JobStatus(status),
 		RequestType:  jobType(reqType),
 		ErrorMessage: errMsg,
+		AckSent:      ackSent,
 		CreatedAt:    createdAt.Format(time.RFC3339Nano),
 		UpdatedAt:    updatedAt.Format(time.RFC3339Nano),
 	}
@@ -193,10 +306,80 @@ func (s *PGJobStore) GetJob(ctx context.Context, jobID string) (*JobRecord, erro
 		}
 		job.Response = &resp
 	}
-
+	if len(timingsJSON) > 0 {
+		var timings TurnTimings
+		if err := json.Unmarshal(timingsJSON, &timings); err != nil {
+			return nil, fmt.Errorf("conversation: failed to decode timings: %w", err)
+		}
+		job.Timings = &timings
+	}
 	return job, nil
 }
 
+// PutOverflow persists a job that did not fit in the memory queue as queued.
+func (s *PGJobStore) PutOverflow(ctx context.Context, job OverflowJob) error {
+	if strings.TrimSpace(job.ID) == "" {
+		return errors.New("conversation: overflow id required")
+	}
+	createdAt := job.CreatedAt
+	if createdAt.IsZero() {
+		createdAt = time.Now().UTC()
+	}
+	if _, err := s.db.Exec(ctx, `
+		INSERT INTO conversation_job_overflow (overflow_id, conversation_id, status, body, created_at)
+		VALUES ($1, $2, 'queued', $3, $4)
+		ON CONFLICT (overflow_id) DO NOTHING
+	`, job.ID, nullString(job.ConversationID), job.Body, createdAt); err != nil {
+		return fmt.Errorf("conversation: failed to persist overflow job: %w", err)
+	}
+	return nil
+}
+
+// ListOverflow returns up to limit queued overflow jobs, oldest first.
+func (s *PGJobStore) ListOverflow(ctx context.Context, limit int) ([]OverflowJob, error) {
+	if limit <= 0 {
+		return nil, nil
+	}
+	rows, err := s.db.Query(ctx, `
+		SELECT overflow_id, conversation_id, body, created_at
+		FROM conversation_job_overflow
+		WHERE status = 'queued'
+		ORDER BY seq
+		LIMIT $1
+	`, limit)
+	if err != nil {
+		return nil, fmt.Errorf("conversation: failed to list overflow jobs: %w", err)
+	}
+	defer rows.Close()
+
+	var jobs []OverflowJob
+	for rows.Next() {
+		var (
+			job     OverflowJob
+			convoID pgtype.Text
+		)
+		if err := rows.Scan(&job.ID, &convoID, &job.Body, &job.CreatedAt); err != nil {
+			return nil, fmt.Errorf("conversation: failed to scan overflow job: %w", err)
+		}
+		if convoID.Valid {
+			job.ConversationID = convoID.String
+		}
+		jobs = append(jobs, job)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("conversation: failed to list overflow jobs: %w", err)
+	}
+	return jobs, nil
+}
+
+// DeleteOverflow removes an overflow job once it is back in the memory queue.
+func (s *PGJobStore) DeleteOverflow(ctx context.Context, id string) error {
+	if _, err := s.db.Exec(ctx, `DELETE FROM conversation_job_overflow WHERE overflow_id = $1`, id); err != nil {
+		return fmt.Errorf("conversation: failed to delete overflow job: %w", err)
+	}
+	return nil
+}
+
 func marshalJSON(v any) ([]byte, error) {
 	if v == nil {
 		return nil, nil
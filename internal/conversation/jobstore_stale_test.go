@@ -0,0 +1,117 @@
+package conversation
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/pashagolub/pgxmock/v4"
+	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
+)
+
+// staleFixture is three pending jobs last updated 3h, 2h and 90m before now.
+func staleFixture(now time.Time) []JobRecord {
+	var jobs []JobRecord
+	for i, age := range []time.Duration{3 * time.Hour, 2 * time.Hour, 90 * time.Minute} {
+		at := now.Add(-age).UTC()
+		jobs = append(jobs, JobRecord{
+			JobID:          []string{"job-a", "job-b", "job-c"}[i],
+			Status:         JobStatusPending,
+			RequestType:    jobTypeMessage,
+			ConversationID: "sms:org-1:15550000001",
+			MessageRequest: &MessageRequest{OrgID: "org-1", From: "+15550000001", Message: "hi"},
+			CreatedAt:      at.Format(time.RFC3339Nano),
+			UpdatedAt:      at.Format(time.RFC3339Nano),
+		})
+	}
+	return jobs
+}
+
+// dynamoStaleStore serves the fixture across two scan pages, newest first,
+// as a scan returns items in no particular order.
+func dynamoStaleStore(t *testing.T, fixture []JobRecord) StaleJobLister {
+	t.Helper()
+	item := func(job JobRecord) map[string]types.AttributeValue {
+		av, err := attributevalue.MarshalMap(job)
+		if err != nil {
+			t.Fatalf("marshal: %v", err)
+		}
+		return av
+	}
+	mock := &mockDynamo{scanPages: []*dynamodb.ScanOutput{
+		{
+			Items:            []map[string]types.AttributeValue{item(fixture[2])},
+			LastEvaluatedKey: map[string]types.AttributeValue{"jobId": &types.AttributeValueMemberS{Value: fixture[2].JobID}},
+		},
+		{Items: []map[string]types.AttributeValue{item(fixture[1]), item(fixture[0])}},
+	}}
+	t.Cleanup(func() {
+		if len(mock.scanInputs) != 2 || mock.scanInputs[1].ExclusiveStartKey == nil {
+			t.Errorf("expected a paginated scan, got %d calls", len(mock.scanInputs))
+		}
+		if expr := mock.scanInputs[0].FilterExpression; expr == nil || *expr != "#status = :pending AND #updated < :cutoff" {
+			t.Errorf("unexpected filter %v", expr)
+		}
+	})
+	return NewJobStore(mock, "conversation_jobs", logging.Default())
+}
+
+// pgStaleStore returns the two oldest fixture rows, as the LIMIT would.
+func pgStaleStore(t *testing.T, fixture []JobRecord) StaleJobLister {
+	t.Helper()
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("pgxmock: %v", err)
+	}
+	rows := pgxmock.NewRows([]string{"job_id", "status", "request_type", "conversation_id",
+		"start_request", "message_request", "response", "error_message",
+		"created_at", "updated_at", "expires_at", "ack_sent", "timings"})
+	for _, job := range fixture[:2] {
+		msg, _ := json.Marshal(job.MessageRequest)
+		at, _ := time.Parse(time.RFC3339Nano, job.UpdatedAt)
+		rows.AddRow(job.JobID, string(job.Status), string(job.RequestType), job.ConversationID,
+			[]byte(nil), msg, []byte(nil), "", at, at, at.Add(jobTTL), false, []byte(nil))
+	}
+	mock.ExpectQuery("FROM conversation_jobs").
+		WithArgs(JobStatusPending, pgxmock.AnyArg(), 2).
+		WillReturnRows(rows)
+	t.Cleanup(func() {
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("expectations: %v", err)
+		}
+		mock.Close()
+	})
+	return &PGJobStore{db: mock}
+}
+
+func TestStaleJobLister_Contract(t *testing.T) {
+	stores := map[string]func(*testing.T, []JobRecord) StaleJobLister{
+		"dynamo":   dynamoStaleStore,
+		"postgres": pgStaleStore,
+	}
+	for name, build := range stores {
+		t.Run(name, func(t *testing.T) {
+			now := time.Now()
+			fixture := staleFixture(now)
+			store := build(t, fixture)
+
+			jobs, err := store.ListStale(context.Background(), now.Add(-time.Hour), 2)
+			if err != nil {
+				t.Fatalf("ListStale: %v", err)
+			}
+			if len(jobs) != 2 || jobs[0].JobID != "job-a" || jobs[1].JobID != "job-b" {
+				t.Fatalf("expected the two oldest jobs oldest first, got %+v", jobs)
+			}
+			if jobs[0].MessageRequest == nil || jobs[0].MessageRequest.From != "+15550000001" {
+				t.Fatalf("expected the request decoded, got %+v", jobs[0].MessageRequest)
+			}
+			if none, err := store.ListStale(context.Background(), now, 0); err != nil || none != nil {
+				t.Fatalf("expected nothing for a zero limit, got %v, %v", none, err)
+			}
+		})
+	}
+}
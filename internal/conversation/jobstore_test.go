@@ -162,6 +162,8 @@ type mockDynamo struct {
 	updateErr    error
 	getOutput    *dynamodb.GetItemOutput
 	getErr       error
+	scanInputs   []*dynamodb.ScanInput
+	scanPages    []*dynamodb.ScanOutput
 }
 
 func (m *mockDynamo) PutItem(ctx context.Context, input *dynamodb.PutItemInput, _ ...func(*dynamodb.Options)) (*dynamodb.PutItemOutput, error) {
@@ -187,6 +189,17 @@ func (m *mockDynamo) GetItem(ctx context.Context, input *dynamodb.GetItemInput,
 	return m.getOutput, nil
 }
 
+func (m *mockDynamo) Scan(ctx context.Context, input *dynamodb.ScanInput, _ ...func(*dynamodb.Options)) (*dynamodb.ScanOutput, error) {
+	copied := *input
+	m.scanInputs = append(m.scanInputs, &copied)
+	if len(m.scanPages) == 0 {
+		return &dynamodb.ScanOutput{}, nil
+	}
+	page := m.scanPages[0]
+	m.scanPages = m.scanPages[1:]
+	return page, nil
+}
+
 func stringsContain(haystack, needle string) bool {
 	return strings.Contains(haystack, needle)
 }
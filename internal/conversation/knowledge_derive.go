@@ -47,6 +47,15 @@ func DeriveConfigFromKnowledge(sk *StructuredKnowledge, cfg *clinic.Config) {
 	}
 	cfg.ServiceDepositAmountCents = depositAmounts
 
+	// Service durations
+	durations := make(map[string]int)
+	for _, svc := range sk.Sections.Services.Items {
+		if svc.DurationMinutes > 0 {
+			durations[strings.ToLower(svc.Name)] = svc.DurationMinutes
+		}
+	}
+	cfg.ServiceDurationMinutes = durations
+
 	// Moxie config
 	if cfg.MoxieConfig == nil {
 		cfg.MoxieConfig = &clinic.MoxieConfig{}
@@ -83,6 +83,11 @@ func TestDeriveConfigFromKnowledge(t *testing.T) {
 		t.Errorf("deposit tox = %d", cfg.ServiceDepositAmountCents["tox"])
 	}
 
+	// Durations
+	if cfg.ServiceDurationMinutes["lip filler"] != 45 {
+		t.Errorf("duration lip filler = %d", cfg.ServiceDurationMinutes["lip filler"])
+	}
+
 	// Moxie service menu items
 	if cfg.MoxieConfig.ServiceMenuItems["tox"] != "18430" {
 		t.Errorf("menu item tox = %q", cfg.MoxieConfig.ServiceMenuItems["tox"])
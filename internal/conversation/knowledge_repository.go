@@ -7,27 +7,32 @@ import (
 	"strings"
 
 	"github.com/redis/go-redis/v9"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/tenancy"
 )
 
 const knowledgeKeyPrefix = "rag:docs:"
 const knowledgeVersionKeyPrefix = "rag:docs:ver:"
 
-// KnowledgeRepository persists clinic knowledge snippets.
+// KnowledgeRepository persists clinic knowledge snippets. Clinic snippets
+// are read and written under the clinic's OrgScope; the shared snippets every
+// clinic sees belong to no org and have their own reader.
 type KnowledgeRepository interface {
-	AppendDocuments(ctx context.Context, clinicID string, docs []string) error
-	GetDocuments(ctx context.Context, clinicID string) ([]string, error)
+	AppendDocuments(ctx context.Context, scope tenancy.OrgScope, docs []string) error
+	GetDocuments(ctx context.Context, scope tenancy.OrgScope) ([]string, error)
+	GetSharedDocuments(ctx context.Context) ([]string, error)
 	LoadAll(ctx context.Context) (map[string][]string, error)
 }
 
 // KnowledgeReplacer replaces clinic knowledge documents.
 type KnowledgeReplacer interface {
-	ReplaceDocuments(ctx context.Context, clinicID string, docs []string) error
+	ReplaceDocuments(ctx context.Context, scope tenancy.OrgScope, docs []string) error
 }
 
 // KnowledgeVersioner tracks knowledge versions per clinic.
 type KnowledgeVersioner interface {
-	GetVersion(ctx context.Context, clinicID string) (int64, error)
-	SetVersion(ctx context.Context, clinicID string, version int64) error
+	GetVersion(ctx context.Context, scope tenancy.OrgScope) (int64, error)
+	SetVersion(ctx context.Context, scope tenancy.OrgScope, version int64) error
 }
 
 // RedisKnowledgeRepository stores raw documents in Redis lists.
@@ -44,7 +49,20 @@ func NewRedisKnowledgeRepository(client *redis.Client) *RedisKnowledgeRepository
 }
 
 // AppendDocuments pushes new snippets onto the clinic's list.
-func (r *RedisKnowledgeRepository) AppendDocuments(ctx context.Context, clinicID string, docs []string) error {
+func (r *RedisKnowledgeRepository) AppendDocuments(ctx context.Context, scope tenancy.OrgScope, docs []string) error {
+	clinicID, err := scope.SingleOrg()
+	if err != nil {
+		return fmt.Errorf("conversation: failed to push knowledge: %w", err)
+	}
+	return r.appendDocuments(ctx, clinicID, docs)
+}
+
+// AppendSharedDocuments pushes snippets every clinic sees.
+func (r *RedisKnowledgeRepository) AppendSharedDocuments(ctx context.Context, docs []string) error {
+	return r.appendDocuments(ctx, "", docs)
+}
+
+func (r *RedisKnowledgeRepository) appendDocuments(ctx context.Context, clinicID string, docs []string) error {
 	if len(docs) == 0 {
 		return nil
 	}
@@ -60,7 +78,11 @@ func (r *RedisKnowledgeRepository) AppendDocuments(ctx context.Context, clinicID
 }
 
 // ReplaceDocuments overwrites all snippets for the clinic.
-func (r *RedisKnowledgeRepository) ReplaceDocuments(ctx context.Context, clinicID string, docs []string) error {
+func (r *RedisKnowledgeRepository) ReplaceDocuments(ctx context.Context, scope tenancy.OrgScope, docs []string) error {
+	clinicID, err := scope.SingleOrg()
+	if err != nil {
+		return fmt.Errorf("conversation: failed to replace knowledge: %w", err)
+	}
 	key := knowledgeKey(clinicID)
 	pipe := r.client.TxPipeline()
 	pipe.Del(ctx, key)
@@ -78,12 +100,25 @@ func (r *RedisKnowledgeRepository) ReplaceDocuments(ctx context.Context, clinicI
 }
 
 // GetDocuments retrieves all snippets for the clinic.
-func (r *RedisKnowledgeRepository) GetDocuments(ctx context.Context, clinicID string) ([]string, error) {
+func (r *RedisKnowledgeRepository) GetDocuments(ctx context.Context, scope tenancy.OrgScope) ([]string, error) {
+	clinicID, err := scope.SingleOrg()
+	if err != nil {
+		return nil, fmt.Errorf("conversation: get knowledge: %w", err)
+	}
 	return r.client.LRange(ctx, knowledgeKey(clinicID), 0, -1).Result()
 }
 
+// GetSharedDocuments retrieves the snippets every clinic sees.
+func (r *RedisKnowledgeRepository) GetSharedDocuments(ctx context.Context) ([]string, error) {
+	return r.client.LRange(ctx, knowledgeKey(""), 0, -1).Result()
+}
+
 // GetVersion retrieves the version for the clinic knowledge.
-func (r *RedisKnowledgeRepository) GetVersion(ctx context.Context, clinicID string) (int64, error) {
+func (r *RedisKnowledgeRepository) GetVersion(ctx context.Context, scope tenancy.OrgScope) (int64, error) {
+	clinicID, err := scope.SingleOrg()
+	if err != nil {
+		return 0, fmt.Errorf("conversation: get knowledge version: %w", err)
+	}
 	val, err := r.client.Get(ctx, knowledgeVersionKey(clinicID)).Result()
 	if err != nil {
 		if err == redis.Nil {
@@ -99,7 +134,11 @@ func (r *RedisKnowledgeRepository) GetVersion(ctx context.Context, clinicID stri
 }
 
 // SetVersion stores the version for the clinic knowledge.
-func (r *RedisKnowledgeRepository) SetVersion(ctx context.Context, clinicID string, version int64) error {
+func (r *RedisKnowledgeRepository) SetVersion(ctx context.Context, scope tenancy.OrgScope, version int64) error {
+	clinicID, err := scope.SingleOrg()
+	if err != nil {
+		return fmt.Errorf("conversation: set knowledge version: %w", err)
+	}
 	if err := r.client.Set(ctx, knowledgeVersionKey(clinicID), strconv.FormatInt(version, 10), 0).Err(); err != nil {
 		return fmt.Errorf("conversation: set knowledge version: %w", err)
 	}
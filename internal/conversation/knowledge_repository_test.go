@@ -2,10 +2,13 @@ package conversation
 
 import (
 	"context"
+	"errors"
 	"testing"
 
 	miniredis "github.com/alicebob/miniredis/v2"
 	"github.com/redis/go-redis/v9"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/tenancy"
 )
 
 func TestRedisKnowledgeRepository(t *testing.T) {
@@ -14,11 +17,11 @@ func TestRedisKnowledgeRepository(t *testing.T) {
 	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
 	repo := NewRedisKnowledgeRepository(client)
 
-	if err := repo.AppendDocuments(context.Background(), "clinic-a", []string{"Doc1", "Doc2"}); err != nil {
+	if err := repo.AppendDocuments(context.Background(), tenancy.ForOrg("clinic-a"), []string{"Doc1", "Doc2"}); err != nil {
 		t.Fatalf("AppendDocuments failed: %v", err)
 	}
 
-	docs, err := repo.GetDocuments(context.Background(), "clinic-a")
+	docs, err := repo.GetDocuments(context.Background(), tenancy.ForOrg("clinic-a"))
 	if err != nil {
 		t.Fatalf("GetDocuments failed: %v", err)
 	}
@@ -26,11 +29,23 @@ func TestRedisKnowledgeRepository(t *testing.T) {
 		t.Fatalf("unexpected docs: %#v", docs)
 	}
 
+	if err := repo.AppendSharedDocuments(context.Background(), []string{"Shared"}); err != nil {
+		t.Fatalf("AppendSharedDocuments failed: %v", err)
+	}
+	shared, err := repo.GetSharedDocuments(context.Background())
+	if err != nil || len(shared) != 1 || shared[0] != "Shared" {
+		t.Fatalf("unexpected shared docs: %#v, %v", shared, err)
+	}
+
 	all, err := repo.LoadAll(context.Background())
 	if err != nil {
 		t.Fatalf("LoadAll failed: %v", err)
 	}
-	if len(all) != 1 || len(all["clinic-a"]) != 2 {
-		t.Fatalf("expected clinic-a docs, got %#v", all)
+	if len(all) != 2 || len(all["clinic-a"]) != 2 || len(all[""]) != 1 {
+		t.Fatalf("expected clinic-a and shared docs, got %#v", all)
+	}
+
+	if _, err := repo.GetDocuments(context.Background(), tenancy.AllOrgs()); !errors.Is(err, tenancy.ErrNoScope) {
+		t.Fatalf("GetDocuments(AllOrgs) err = %v, want ErrNoScope", err)
 	}
 }
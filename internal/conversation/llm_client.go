@@ -1,6 +1,10 @@
 package conversation
 
-import "context"
+import (
+	"context"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/conversation/msgschema"
+)
 
 const (
 	ChatRoleSystem    = "system"
@@ -9,9 +13,31 @@ const (
 )
 
 // ChatMessage is an internal message representation that can include system prompts.
+// Role is what the LLM sees; Direction and AuthorType record who actually
+// wrote a stored history entry (see msgschema), so a staff message sent into
+// the conversation isn't mistaken for the patient's.
 type ChatMessage struct {
-	Role    string `json:"role"`
-	Content string `json:"content"`
+	Role       string `json:"role"`
+	Content    string `json:"content"`
+	Direction  string `json:"direction,omitempty"`
+	AuthorType string `json:"author_type,omitempty"`
+}
+
+func (m ChatMessage) schema() msgschema.Message {
+	return msgschema.Message{Direction: m.Direction, AuthorType: m.AuthorType, Role: m.Role}
+}
+
+// FromPatient reports whether the patient wrote the message. Extraction reads
+// only these messages.
+func (m ChatMessage) FromPatient() bool {
+	return m.schema().FromPatient()
+}
+
+// withSchema returns the message with Direction and AuthorType filled in.
+func (m ChatMessage) withSchema() ChatMessage {
+	dir, author := m.schema().Resolve()
+	m.Direction, m.AuthorType = string(dir), string(author)
+	return m
 }
 
 type TokenUsage struct {
@@ -5,6 +5,7 @@ import (
 	"strings"
 
 	"github.com/wolfman30/medspa-ai-platform/internal/clinic"
+	"github.com/wolfman30/medspa-ai-platform/internal/services"
 )
 
 var (
@@ -135,7 +136,7 @@ func detectServiceKey(message string, cfg *clinic.Config) string {
 		if key == "" {
 			continue
 		}
-		if strings.Contains(message, key) {
+		if strings.Contains(message, key) || services.Contains(message, key) {
 			// Resolve through aliases to canonical service name for price lookup.
 			if cfg != nil {
 				if resolved, ok := cfg.ServiceAliases[key]; ok {
@@ -8,7 +8,9 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/wolfman30/medspa-ai-platform/internal/clinic"
 	"github.com/wolfman30/medspa-ai-platform/internal/leads"
+	"github.com/wolfman30/medspa-ai-platform/internal/tenancy"
 )
 
 // splitSystemAndMessages separates system messages from user/assistant messages
@@ -66,59 +68,133 @@ func formatIntroMessage(req StartRequest, conversationID string) string {
 	return builder.String()
 }
 
+// patientText returns what the patient actually wrote in a user turn. For an
+// intro message that is only the trailing "Message:" line; the IDs and phone
+// numbers above it would otherwise be read as names, times, or dates.
+func patientText(content string) string {
+	if !strings.HasPrefix(content, "Lead introduction:\n") {
+		return content
+	}
+	if i := strings.Index(content, "\nMessage: "); i >= 0 {
+		return content[i+len("\nMessage: "):]
+	}
+	return ""
+}
+
+// defaultServiceContext tells the LLM which service the patient is asking
+// about when they texted a number advertised for one service line.
+func defaultServiceContext(metadata map[string]string) (ChatMessage, bool) {
+	service := strings.TrimSpace(metadata[MetadataDefaultService])
+	if service == "" {
+		return ChatMessage{}, false
+	}
+	return ChatMessage{
+		Role: ChatRoleSystem,
+		Content: fmt.Sprintf("[SYSTEM] The patient contacted the clinic's %s line, so treat %s as their service interest. "+
+			"Do NOT ask what service they're interested in; acknowledge %s naturally and move on to the next missing detail. "+
+			"If they clearly ask about a different service, follow their lead.", service, service, service),
+	}, true
+}
+
+// broadcastReplyContext tells the LLM the patient is replying to a clinic
+// broadcast, so a bare "yes" or "Friday works" is read against the offer.
+func broadcastReplyContext(metadata map[string]string) (ChatMessage, bool) {
+	if strings.TrimSpace(metadata[MetadataBroadcastID]) == "" {
+		return ChatMessage{}, false
+	}
+	msg := strings.TrimSpace(metadata[MetadataBroadcastMessage])
+	if msg == "" {
+		return ChatMessage{}, false
+	}
+	return ChatMessage{
+		Role: ChatRoleSystem,
+		Content: fmt.Sprintf("[SYSTEM] The patient is replying to this text the clinic sent them: %q. "+
+			"Interpret their reply in light of that offer and help them act on it.", msg),
+	}, true
+}
+
 // appendContext enriches the conversation history with contextual system
 // messages including deposit status, lead preferences, business hours,
-// RAG snippets, and real-time EMR availability.
+// RAG snippets, and real-time EMR availability. The blocks are kept in the
+// clinic's priority order within its token budget; see budgetContext.
 func (s *LLMService) appendContext(ctx context.Context, history []ChatMessage, orgID, leadID, clinicID, query string) []ChatMessage {
-	history = s.appendDepositContext(ctx, history, orgID, leadID)
-	history = s.appendLeadPreferenceContext(ctx, history, orgID, leadID)
-	history = s.appendClinicContext(ctx, history, orgID, query)
-	history = s.appendRAGContext(ctx, history, clinicID, query)
-	history = s.appendEMRAvailability(ctx, history, query)
-	return history
+	start := time.Now()
+	cfg := s.contextClinicConfig(ctx, orgID)
+	snippets := s.ragSnippets(ctx, clinicID, query)
+	var rag []ChatMessage
+	if len(snippets) > 0 {
+		rag = []ChatMessage{formatRAGContext(snippets)}
+	}
+	blocks := []contextBlock{
+		{kind: clinic.ContextBlockPayment, messages: addedContext(history, s.appendDepositContext(ctx, history, orgID, leadID))},
+		{kind: clinic.ContextBlockPreferences, messages: s.appendLeadPreferenceContext(ctx, nil, orgID, leadID)},
+		{kind: clinic.ContextBlockClinic, messages: appendClinicConfigContext(nil, cfg, query)},
+		{kind: clinic.ContextBlockRAG, messages: rag, snippets: snippets},
+	}
+	// The EMR fetch is timed as availability, not context build.
+	emrStart := time.Now()
+	blocks = append(blocks, contextBlock{kind: clinic.ContextBlockEMR, messages: s.appendEMRAvailability(ctx, nil, query)})
+	emrTime := time.Since(emrStart)
+
+	budget := s.contextTokenBudget
+	if cfg != nil && cfg.ContextTokenBudget > 0 {
+		budget = cfg.ContextTokenBudget
+	}
+	kept, trims := budgetContext(blocks, cfg.ContextPriorityOrder(), budget)
+	for _, trim := range trims {
+		contextBlocksTrimmedTotal.WithLabelValues(trim.kind, trim.action).Inc()
+		s.log(ctx).Warn("prompt context over token budget", "block", trim.kind, "action", trim.action,
+			"budget", budget, "org_id", orgID, "lead_id", leadID)
+	}
+	addTurnStage(ctx, TurnStageContextBuild, time.Since(start)-emrTime)
+	return append(history, kept...)
+}
+
+// addedContext returns the messages an append helper added to history,
+// copied so later appends to history can't overwrite them.
+func addedContext(history, grown []ChatMessage) []ChatMessage {
+	return append([]ChatMessage(nil), grown[len(history):]...)
+}
+
+// contextClinicConfig loads the clinic config for context assembly, or nil
+// when there is none.
+func (s *LLMService) contextClinicConfig(ctx context.Context, orgID string) *clinic.Config {
+	if s.clinicStore == nil || orgID == "" {
+		return nil
+	}
+	cfg, err := s.clinicStore.Get(ctx, orgID)
+	if err != nil {
+		s.log(ctx).Warn("failed to fetch clinic config", "org_id", orgID, "error", err)
+		return nil
+	}
+	return cfg
 }
 
 // appendDepositContext checks payment status and injects deposit guardrails
-// into the conversation history to prevent duplicate deposits.
+// into the conversation history to prevent duplicate deposits. Paid and
+// pending context comes only from the payments repository, never from what
+// the patient typed.
 func (s *LLMService) appendDepositContext(ctx context.Context, history []ChatMessage, orgID, leadID string) []ChatMessage {
-	depositContextInjected := false
-	if s.paymentChecker != nil && orgID != "" && leadID != "" {
-		orgUUID, orgErr := uuid.Parse(orgID)
-		leadUUID, leadErr := uuid.Parse(leadID)
-		if orgErr == nil && leadErr == nil {
-			type openDepositStatusChecker interface {
-				OpenDepositStatus(ctx context.Context, orgID uuid.UUID, leadID uuid.UUID) (string, error)
-			}
-			if statusChecker, ok := s.paymentChecker.(openDepositStatusChecker); ok {
-				status, err := statusChecker.OpenDepositStatus(ctx, orgUUID, leadUUID)
-				if err != nil {
-					s.logger.Warn("failed to check payment status", "org_id", orgID, "lead_id", leadID, "error", err)
-				} else if strings.TrimSpace(status) != "" {
-					content := depositContextForStatus(status)
-					history = append(history, ChatMessage{
-						Role:    ChatRoleSystem,
-						Content: content,
-					})
-					depositContextInjected = true
-				}
-			} else {
-				hasDeposit, err := s.paymentChecker.HasOpenDeposit(ctx, orgUUID, leadUUID)
-				if err != nil {
-					s.logger.Warn("failed to check payment status", "org_id", orgID, "lead_id", leadID, "error", err)
-				} else if hasDeposit {
-					history = append(history, ChatMessage{
-						Role:    ChatRoleSystem,
-						Content: "IMPORTANT: This patient has an existing deposit in progress (pending payment or already paid). Do NOT offer another deposit. Do NOT restart intake or offer to schedule a consultation again. Do NOT repeat any payment confirmation message. Answer their questions normally and defer personalized/medical advice to the practitioner during their consultation. If they ask about next steps: \"Our team will call you within 24 hours to confirm a specific date and time that works for you.\"",
-					})
-					depositContextInjected = true
-				}
-			}
-		}
+	status := s.depositStatus(ctx, orgID, leadID)
+	switch status {
+	case "":
+	case depositStatusOpen:
+		history = append(history, ChatMessage{
+			Role:    ChatRoleSystem,
+			Content: "IMPORTANT: This patient has an existing deposit in progress (pending payment or already paid). Do NOT offer another deposit. Do NOT restart intake or offer to schedule a consultation again. Do NOT repeat any payment confirmation message. Answer their questions normally and defer personalized/medical advice to the practitioner during their consultation. If they ask about next steps: \"Our team will call you within 24 hours to confirm a specific date and time that works for you.\"",
+		})
+		return history
+	default:
+		history = append(history, ChatMessage{
+			Role:    ChatRoleSystem,
+			Content: depositContextForStatus(status),
+		})
+		return history
 	}
 
 	// If the payment checker is unavailable (or hasn't persisted yet) but the conversation indicates
 	// the patient already agreed to a deposit, inject guardrails so we don't restart intake.
-	if !depositContextInjected && conversationHasDepositAgreement(history) {
+	if conversationHasDepositAgreement(history) {
 		history = append(history, ChatMessage{
 			Role:    ChatRoleSystem,
 			Content: "IMPORTANT: This patient already agreed to the deposit and is in the booking flow. Do NOT restart intake or offer to schedule a consultation again. Answer their questions normally and defer personalized/medical advice to the practitioner during their consultation.",
@@ -127,6 +203,44 @@ func (s *LLMService) appendDepositContext(ctx context.Context, history []ChatMes
 	return history
 }
 
+// depositStatusOpen is reported by payment checkers that only know a deposit
+// is open, not whether it is pending or paid.
+const depositStatusOpen = "open"
+
+// depositStatus returns the lead's open deposit status from the payment
+// checker ("succeeded", "deposit_pending", depositStatusOpen), or "" when
+// there is none or it can't be determined.
+func (s *LLMService) depositStatus(ctx context.Context, orgID, leadID string) string {
+	if s.paymentChecker == nil || orgID == "" || leadID == "" {
+		return ""
+	}
+	leadUUID, err := uuid.Parse(leadID)
+	if err != nil {
+		return ""
+	}
+	scope := tenancy.ForOrg(orgID)
+	type openDepositStatusChecker interface {
+		OpenDepositStatus(ctx context.Context, scope tenancy.OrgScope, leadID uuid.UUID) (string, error)
+	}
+	if statusChecker, ok := s.paymentChecker.(openDepositStatusChecker); ok {
+		status, err := statusChecker.OpenDepositStatus(ctx, scope, leadUUID)
+		if err != nil {
+			s.log(ctx).Warn("failed to check payment status", "org_id", orgID, "lead_id", leadID, "error", err)
+			return ""
+		}
+		return strings.TrimSpace(status)
+	}
+	hasDeposit, err := s.paymentChecker.HasOpenDeposit(ctx, scope, leadUUID)
+	if err != nil {
+		s.log(ctx).Warn("failed to check payment status", "org_id", orgID, "lead_id", leadID, "error", err)
+		return ""
+	}
+	if hasDeposit {
+		return depositStatusOpen
+	}
+	return ""
+}
+
 // depositContextForStatus returns the appropriate system message for a given
 // deposit payment status.
 func depositContextForStatus(status string) string {
@@ -144,10 +258,10 @@ func depositContextForStatus(status string) string {
 // the assistant doesn't re-ask for already captured information.
 func (s *LLMService) appendLeadPreferenceContext(ctx context.Context, history []ChatMessage, orgID, leadID string) []ChatMessage {
 	if s.leadsRepo != nil && orgID != "" && leadID != "" {
-		lead, err := s.leadsRepo.GetByID(ctx, orgID, leadID)
+		lead, err := s.leadsRepo.GetByID(ctx, tenancy.ForOrg(orgID), leadID)
 		if err != nil {
 			if !errors.Is(err, leads.ErrLeadNotFound) {
-				s.logger.Warn("failed to fetch lead preferences", "org_id", orgID, "lead_id", leadID, "error", err)
+				s.log(ctx).Warn("failed to fetch lead preferences", "org_id", orgID, "lead_id", leadID, "error", err)
 			}
 		} else if lead != nil {
 			if content := formatLeadPreferenceContext(lead); content != "" {
@@ -161,17 +275,10 @@ func (s *LLMService) appendLeadPreferenceContext(ctx context.Context, history []
 	return history
 }
 
-// appendClinicContext adds business hours, deposit amount, AI persona, and
-// service highlights from the clinic configuration.
-func (s *LLMService) appendClinicContext(ctx context.Context, history []ChatMessage, orgID, query string) []ChatMessage {
-	if s.clinicStore == nil || orgID == "" {
-		return history
-	}
-	cfg, err := s.clinicStore.Get(ctx, orgID)
-	if err != nil {
-		s.logger.Warn("failed to fetch clinic config", "org_id", orgID, "error", err)
-		return history
-	}
+// appendClinicConfigContext adds business hours, deposit amount, AI persona,
+// service highlights, and the prices and payment methods the query asks
+// about from the clinic configuration.
+func appendClinicConfigContext(history []ChatMessage, cfg *clinic.Config, query string) []ChatMessage {
 	if cfg == nil {
 		return history
 	}
@@ -190,6 +297,12 @@ func (s *LLMService) appendClinicContext(ctx context.Context, history []ChatMess
 		Role:    ChatRoleSystem,
 		Content: fmt.Sprintf("DEPOSIT AMOUNT: This clinic's deposit is exactly $%d. NEVER say a range like '$50-100'. Always state the exact amount: $%d.", depositDollars, depositDollars),
 	})
+	if exempt := cfg.DepositExemptServices(); len(exempt) > 0 {
+		history = append(history, ChatMessage{
+			Role:    ChatRoleSystem,
+			Content: fmt.Sprintf("NO DEPOSIT SERVICES: These services do NOT require a deposit: %s. Never ask for or mention a deposit for them; once the patient confirms, tell them the clinic will confirm their appointment.", strings.Join(exempt, ", ")),
+		})
+	}
 	// Add AI persona context for personalized voice
 	if personaContext := cfg.AIPersonaContext(); personaContext != "" {
 		history = append(history, ChatMessage{
@@ -203,33 +316,32 @@ func (s *LLMService) appendClinicContext(ctx context.Context, history []ChatMess
 			Content: highlightContext,
 		})
 	}
+	if priceContext := buildPriceContext(cfg, query); priceContext != "" {
+		history = append(history, ChatMessage{
+			Role:    ChatRoleSystem,
+			Content: priceContext,
+		})
+	}
+	if isPaymentMethodQuestion(query) {
+		history = append(history, ChatMessage{
+			Role:    ChatRoleSystem,
+			Content: cfg.PaymentMethodsContext(),
+		})
+	}
 	return history
 }
 
-// appendRAGContext retrieves relevant knowledge base snippets for the query
-// and adds them to the conversation history.
-func (s *LLMService) appendRAGContext(ctx context.Context, history []ChatMessage, clinicID, query string) []ChatMessage {
+// ragSnippets retrieves relevant knowledge base snippets for the query.
+func (s *LLMService) ragSnippets(ctx context.Context, clinicID, query string) []string {
 	if s.rag == nil || strings.TrimSpace(query) == "" {
-		return history
+		return nil
 	}
 	snippets, err := s.rag.Query(ctx, clinicID, query, 3)
 	if err != nil {
-		s.logger.Error("failed to retrieve RAG context", "error", err)
-		return history
-	}
-	if len(snippets) == 0 {
-		return history
-	}
-	builder := strings.Builder{}
-	builder.WriteString("Relevant clinic context:\n")
-	for i, snippet := range snippets {
-		builder.WriteString(fmt.Sprintf("%d. %s\n", i+1, snippet))
+		s.log(ctx).Error("failed to retrieve RAG context", "error", err)
+		return nil
 	}
-	history = append(history, ChatMessage{
-		Role:    ChatRoleSystem,
-		Content: builder.String(),
-	})
-	return history
+	return snippets
 }
 
 // appendEMRAvailability checks if the query mentions booking intent and, if
@@ -238,9 +350,11 @@ func (s *LLMService) appendEMRAvailability(ctx context.Context, history []ChatMe
 	if s.emr == nil || !s.emr.IsConfigured() || !containsBookingIntent(query) {
 		return history
 	}
+	fetchStart := time.Now()
 	slots, err := s.emr.GetUpcomingAvailability(ctx, 7, "")
+	addTurnStage(ctx, TurnStageAvailability, time.Since(fetchStart))
 	if err != nil {
-		s.logger.Warn("failed to fetch EMR availability", "error", err)
+		s.log(ctx).Warn("failed to fetch EMR availability", "error", err)
 		return history
 	}
 	if len(slots) == 0 {
@@ -6,39 +6,45 @@ import (
 	"strings"
 	"time"
 
+	"github.com/wolfman30/medspa-ai-platform/internal/clinic"
 	"github.com/wolfman30/medspa-ai-platform/internal/leads"
+	"github.com/wolfman30/medspa-ai-platform/internal/tenancy"
 )
 
 // extractAndSavePreferences extracts scheduling preferences from conversation history and saves them.
-func (s *LLMService) extractAndSavePreferences(ctx context.Context, leadID string, history []ChatMessage) error {
-	return s.savePreferencesFromHistory(ctx, leadID, history, true)
+func (s *LLMService) extractAndSavePreferences(ctx context.Context, cfg *clinic.Config, leadID string, history []ChatMessage) error {
+	return s.savePreferencesFromHistory(ctx, cfg, leadID, history, true)
 }
 
 // savePreferencesFromHistory parses scheduling preferences from conversation
-// history and persists them. When addNote is true, a timestamp note is appended.
-func (s *LLMService) savePreferencesFromHistory(ctx context.Context, leadID string, history []ChatMessage, addNote bool) error {
+// history and merges them into the lead. Extraction only fills the fields it
+// found, so a later pass over a short history ("Friday works") adds the day
+// without blanking the name, email, or service saved earlier. When addNote is
+// true, a timestamp note is appended. A service the clinic doesn't offer is
+// never saved as the lead's service interest.
+func (s *LLMService) savePreferencesFromHistory(ctx context.Context, cfg *clinic.Config, leadID string, history []ChatMessage, addNote bool) error {
 	if s == nil || s.leadsRepo == nil || strings.TrimSpace(leadID) == "" {
 		return nil
 	}
-	prefs, ok := extractPreferences(history, nil)
+	prefs, ok := extractOfferedPreferences(history, cfg, nil)
 	if !ok {
 		return nil
 	}
 	if addNote {
 		prefs.Notes = fmt.Sprintf("Auto-extracted from conversation at %s", time.Now().Format(time.RFC3339))
 	}
-	return s.leadsRepo.UpdateSchedulingPreferences(ctx, leadID, prefs)
+	return s.leadsRepo.MergePreferences(ctx, leadID, prefs)
 }
 
 // savePreferencesNoNote silently saves preferences without a note, logging
 // any errors at warn level with the given reason.
-func (s *LLMService) savePreferencesNoNote(ctx context.Context, leadID string, history []ChatMessage, reason string) {
+func (s *LLMService) savePreferencesNoNote(ctx context.Context, cfg *clinic.Config, leadID string, history []ChatMessage, reason string) {
 	if s == nil {
 		return
 	}
-	if err := s.savePreferencesFromHistory(ctx, leadID, history, false); err != nil {
+	if err := s.savePreferencesFromHistory(ctx, cfg, leadID, history, false); err != nil {
 		if s.logger != nil {
-			s.logger.Warn("failed to save scheduling preferences", "lead_id", leadID, "reason", reason, "error", err)
+			s.log(ctx).Warn("failed to save scheduling preferences", "lead_id", leadID, "reason", reason, "error", err)
 		}
 	}
 }
@@ -54,7 +60,7 @@ func (s *LLMService) appendLeadNote(ctx context.Context, orgID, leadID, note str
 	if orgID == "" || leadID == "" || note == "" {
 		return
 	}
-	lead, err := s.leadsRepo.GetByID(ctx, orgID, leadID)
+	lead, err := s.leadsRepo.GetByID(ctx, tenancy.ForOrg(orgID), leadID)
 	if err != nil || lead == nil {
 		return
 	}
@@ -67,7 +73,7 @@ func (s *LLMService) appendLeadNote(ctx context.Context, orgID, leadID, note str
 	default:
 		existing = existing + " | " + note
 	}
-	_ = s.leadsRepo.UpdateSchedulingPreferences(ctx, leadID, leads.SchedulingPreferences{Notes: existing})
+	_ = s.leadsRepo.UpdateSchedulingPreferences(ctx, tenancy.ForOrg(orgID), leadID, leads.SchedulingPreferences{Notes: existing})
 }
 
 // isCapitalized checks if a string starts with an uppercase letter.
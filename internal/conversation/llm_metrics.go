@@ -1,6 +1,8 @@
 package conversation
 
 import (
+	"errors"
+
 	"github.com/prometheus/client_golang/prometheus"
 	"go.opentelemetry.io/otel"
 )
@@ -39,17 +41,54 @@ var depositDecisionTotal = prometheus.NewCounterVec(
 	[]string{"model", "outcome"}, // outcome: collect, skip, error
 )
 
+var llmModelRouteTotal = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Namespace: "medspa",
+		Subsystem: "conversation",
+		Name:      "llm_model_route_total",
+		Help:      "Counts LLM turns by the model that answered and how it was chosen",
+	},
+	[]string{"model", "route"}, // route: default, escalated, budget_exhausted
+)
+
+var llmEscalationTriggerTotal = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Namespace: "medspa",
+		Subsystem: "conversation",
+		Name:      "llm_escalation_trigger_total",
+		Help:      "Counts escalation triggers detected on LLM turns",
+	},
+	[]string{"trigger", "route"},
+)
+
 func init() {
 	prometheus.MustRegister(llmLatency)
 	prometheus.MustRegister(llmTokensTotal)
 	prometheus.MustRegister(depositDecisionTotal)
+	prometheus.MustRegister(llmModelRouteTotal, llmEscalationTriggerTotal)
 }
 
 // RegisterMetrics registers conversation metrics with a custom registry.
 // Use this when exposing a non-default registry (e.g., HTTP handlers with a private registry).
+// Registering with the same registry more than once is a no-op.
 func RegisterMetrics(reg prometheus.Registerer) {
 	if reg == nil || reg == prometheus.DefaultRegisterer {
 		return
 	}
-	reg.MustRegister(llmLatency, llmTokensTotal, depositDecisionTotal)
+	for _, c := range []prometheus.Collector{
+		llmLatency, llmTokensTotal, depositDecisionTotal, experimentFunnelTotal, availabilitySourceTotal,
+		llmModelRouteTotal, llmEscalationTriggerTotal,
+		memoryQueueDepth, memoryQueueOverflowTotal, memoryQueueOverflowDrainedTotal, memoryQueueOverflowPending,
+		concurrencyLimitedTotal, concurrencyLimitDelay, workerShutdownRequeuedTotal,
+		workerJobsProcessedTotal, workerJobDuration, workerQueueReceiveLatency, availabilityFetchDuration,
+		duplicateQuestionGuardTotal, jobReaperTotal, jobReaperStale,
+		turnStageDuration, turnLatency, turnOverBudgetTotal, turnLatencyBudget,
+	} {
+		if err := reg.Register(c); err != nil {
+			var already prometheus.AlreadyRegisteredError
+			if !errors.As(err, &already) {
+				panic(err)
+			}
+		}
+	}
 }
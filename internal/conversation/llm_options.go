@@ -2,13 +2,16 @@ package conversation
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
+
 	"github.com/wolfman30/medspa-ai-platform/internal/clinic"
 	"github.com/wolfman30/medspa-ai-platform/internal/compliance"
 	blvdclient "github.com/wolfman30/medspa-ai-platform/internal/emr/boulevard"
 	moxieclient "github.com/wolfman30/medspa-ai-platform/internal/emr/moxie"
 	"github.com/wolfman30/medspa-ai-platform/internal/leads"
+	"github.com/wolfman30/medspa-ai-platform/internal/tenancy"
 )
 
 // DepositConfig allows callers to configure defaults used when the LLM signals a deposit.
@@ -65,7 +68,7 @@ func WithAuditService(audit *compliance.AuditService) LLMOption {
 
 // PaymentStatusChecker checks if a lead has an open or completed deposit.
 type PaymentStatusChecker interface {
-	HasOpenDeposit(ctx context.Context, orgID uuid.UUID, leadID uuid.UUID) (bool, error)
+	HasOpenDeposit(ctx context.Context, scope tenancy.OrgScope, leadID uuid.UUID) (bool, error)
 }
 
 // WithPaymentChecker configures payment status checking for context injection.
@@ -75,6 +78,33 @@ func WithPaymentChecker(checker PaymentStatusChecker) LLMOption {
 	}
 }
 
+// AppointmentLookup finds a lead's next booked appointment.
+type AppointmentLookup interface {
+	UpcomingAppointment(ctx context.Context, orgID uuid.UUID, leadID uuid.UUID) (*time.Time, error)
+}
+
+// WithAppointmentLookup lets replies to patients asking about an existing
+// appointment mention when it is.
+func WithAppointmentLookup(lookup AppointmentLookup) LLMOption {
+	return func(s *LLMService) {
+		s.appointments = lookup
+	}
+}
+
+// DepositApplicationLookup reports paid deposits not yet credited toward the
+// lead's service.
+type DepositApplicationLookup interface {
+	UnappliedDepositCents(ctx context.Context, orgID uuid.UUID, leadID uuid.UUID) (int, error)
+}
+
+// WithDepositApplications lets the STATUS reply tell patients their deposit
+// will be applied at checkout.
+func WithDepositApplications(lookup DepositApplicationLookup) LLMOption {
+	return func(s *LLMService) {
+		s.unappliedDeposits = lookup
+	}
+}
+
 // WithAPIBaseURL sets the public API base URL (used for building callback URLs).
 func WithAPIBaseURL(url string) LLMOption {
 	return func(s *LLMService) {
@@ -96,6 +126,14 @@ func WithBoulevardAdapter(adapter *blvdclient.BoulevardAdapter) LLMOption {
 	}
 }
 
+// WithAvailabilityRouter configures the router that picks between
+// availability sources. Without it, a router over the Moxie client is used.
+func WithAvailabilityRouter(r *AvailabilityRouter) LLMOption {
+	return func(s *LLMService) {
+		s.availability = r
+	}
+}
+
 // WithAvailabilityPrefetcher enables background availability pre-fetching.
 func WithAvailabilityPrefetcher(p *AvailabilityPrefetcher) LLMOption {
 	return func(s *LLMService) {
@@ -103,6 +141,60 @@ func WithAvailabilityPrefetcher(p *AvailabilityPrefetcher) LLMOption {
 	}
 }
 
+// WithMaxInboundChars caps inbound message length before the LLM and history.
+func WithMaxInboundChars(n int) LLMOption {
+	return func(s *LLMService) {
+		s.maxInboundChars = n
+	}
+}
+
+// WithContextTokenBudget caps the estimated tokens of per-turn context added
+// to the prompt. Clinics may set their own budget.
+func WithContextTokenBudget(tokens int) LLMOption {
+	return func(s *LLMService) {
+		s.contextTokenBudget = tokens
+	}
+}
+
+// WithHistoryHeader saves conversation history in the headered, possibly
+// gzipped format. Leave it off until every replica runs a build that can read
+// it; loads accept both formats regardless.
+func WithHistoryHeader(enabled bool) LLMOption {
+	return func(s *LLMService) {
+		s.history.writeHeader = enabled
+	}
+}
+
+// WithPromptExperiments enables prompt experiments on new SMS conversations.
+func WithPromptExperiments(t *ExperimentTracker) LLMOption {
+	return func(s *LLMService) {
+		s.experiments = t
+	}
+}
+
+// WithModelRouter escalates hard SMS turns to a stronger model.
+func WithModelRouter(r *ModelRouter) LLMOption {
+	return func(s *LLMService) {
+		s.modelRouter = r
+	}
+}
+
+// WithCRMEvents publishes lead.qualified to clinic CRM webhooks once a lead
+// has answered every qualification question.
+func WithCRMEvents(publisher CRMEventPublisher) LLMOption {
+	return func(s *LLMService) {
+		s.crmEvents = publisher
+	}
+}
+
+// WithLLMUsageRecorder records each reply completion's token usage against
+// its conversation for the per-clinic cost report.
+func WithLLMUsageRecorder(r LLMUsageRecorder) LLMOption {
+	return func(s *LLMService) {
+		s.usage = r
+	}
+}
+
 type depositConfig struct {
 	DefaultAmountCents int32
 	SuccessURL         string
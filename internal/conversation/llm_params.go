@@ -0,0 +1,105 @@
+package conversation
+
+import (
+	"context"
+	"math"
+	"strings"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/clinic"
+	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
+)
+
+// LLM call purposes recorded on the audit trail.
+const (
+	llmPurposeReply             = "reply"
+	llmPurposeDepositClassifier = "deposit_classifier"
+)
+
+// llmParams are the completion parameters of one LLM call.
+type llmParams struct {
+	Model       string
+	Temperature float32
+	MaxTokens   int32
+}
+
+// withOverrides returns p with the clinic's overrides applied.
+func (p llmParams) withOverrides(o *clinic.LLMOverrides) llmParams {
+	if o.IsZero() {
+		return p
+	}
+	if m := strings.TrimSpace(o.Model); m != "" {
+		p.Model = m
+	}
+	if o.Temperature != nil {
+		p.Temperature = float32(*o.Temperature)
+	}
+	if o.MaxTokens > 0 {
+		p.MaxTokens = int32(o.MaxTokens)
+	}
+	return p
+}
+
+// withStricterOverrides applies the clinic's model, and its temperature and
+// max tokens only where they are lower. Calls tuned for a fixed output, like
+// the deposit classifier, must not become less deterministic or truncated.
+func (p llmParams) withStricterOverrides(o *clinic.LLMOverrides) llmParams {
+	if o.IsZero() {
+		return p
+	}
+	if m := strings.TrimSpace(o.Model); m != "" {
+		p.Model = m
+	}
+	if o.Temperature != nil && float32(*o.Temperature) < p.Temperature {
+		p.Temperature = float32(*o.Temperature)
+	}
+	if o.MaxTokens > 0 && int32(o.MaxTokens) < p.MaxTokens {
+		p.MaxTokens = int32(o.MaxTokens)
+	}
+	return p
+}
+
+// replyParams resolves a reply's parameters. Precedence, highest first: the
+// voice model on voice turns, the clinic's LLM overrides, the model router's
+// decision, then the service defaults.
+func (s *LLMService) replyParams(ctx context.Context, decision *ModelDecision) (llmParams, *clinic.LLMOverrides) {
+	p := llmParams{Model: s.model, Temperature: llmTemperature, MaxTokens: llmMaxTokens}
+	if decision != nil && decision.Model != "" {
+		p.Model = decision.Model
+	}
+	overrides := s.clinicLLMOverrides(ctx)
+	p = p.withOverrides(overrides)
+	if m, ok := ctx.Value(ctxKeyVoiceModel).(string); ok && m != "" {
+		p.Model = m
+	}
+	return p, overrides
+}
+
+// clinicLLMOverrides loads the LLM overrides of the org on ctx. Nil when the
+// org has none or its config can't be read.
+func (s *LLMService) clinicLLMOverrides(ctx context.Context) *clinic.LLMOverrides {
+	orgID := logging.FieldsFromContext(ctx).OrgID
+	if s.clinicStore == nil || orgID == "" {
+		return nil
+	}
+	cfg, err := s.clinicStore.Get(ctx, orgID)
+	if err != nil {
+		s.log(ctx).Warn("failed to load clinic llm overrides; using defaults", "error", err)
+		return nil
+	}
+	if cfg == nil || cfg.LLMOverrides.IsZero() {
+		return nil
+	}
+	return cfg.LLMOverrides
+}
+
+// auditLLMOverride records the effective parameters of a completion that ran
+// under clinic overrides.
+func (s *LLMService) auditLLMOverride(ctx context.Context, purpose string, p llmParams) {
+	fields := logging.FieldsFromContext(ctx)
+	if s.audit == nil || fields.OrgID == "" {
+		return
+	}
+	if err := s.audit.LogLLMOverride(ctx, fields.OrgID, fields.ConversationID, fields.LeadID, purpose, p.Model, math.Round(float64(p.Temperature)*100)/100, int(p.MaxTokens)); err != nil {
+		s.log(ctx).Warn("failed to audit llm override", "error", err)
+	}
+}
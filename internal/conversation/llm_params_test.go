@@ -0,0 +1,143 @@
+package conversation
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/clinic"
+	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
+)
+
+// orgRecordingLLM records each request under the system prompt it was sent
+// with, so concurrent callers can be told apart.
+type orgRecordingLLM struct {
+	mu   sync.Mutex
+	reqs map[string][]LLMRequest
+}
+
+func (c *orgRecordingLLM) Complete(ctx context.Context, req LLMRequest) (LLMResponse, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.reqs == nil {
+		c.reqs = make(map[string][]LLMRequest)
+	}
+	key := ""
+	if len(req.System) > 0 {
+		key = req.System[0]
+	}
+	c.reqs[key] = append(c.reqs[key], req)
+	return LLMResponse{Text: `{"collect": false}`}, nil
+}
+
+func orgCtx(orgID string) context.Context {
+	return logging.WithFields(context.Background(), logging.Fields{OrgID: orgID, ConversationID: "sms:" + orgID + ":15550001111"})
+}
+
+func TestReplyParamsPrecedence(t *testing.T) {
+	temp := 0.0
+	ts := setupService(t, withClinicConfig("org-strict", func(cfg *clinic.Config) {
+		cfg.LLMOverrides = &clinic.LLMOverrides{Model: "strict-model", Temperature: &temp, MaxTokens: 200}
+	}))
+	escalated := &ModelDecision{Model: "strong-model", Route: ModelRouteEscalated}
+
+	cases := []struct {
+		name     string
+		ctx      context.Context
+		decision *ModelDecision
+		want     llmParams
+	}{
+		{"defaults", orgCtx("org-default"), nil, llmParams{Model: "test-model", Temperature: llmTemperature, MaxTokens: llmMaxTokens}},
+		{"router decision", orgCtx("org-default"), escalated, llmParams{Model: "strong-model", Temperature: llmTemperature, MaxTokens: llmMaxTokens}},
+		{"clinic over router", orgCtx("org-strict"), escalated, llmParams{Model: "strict-model", Temperature: 0, MaxTokens: 200}},
+		{"voice keeps its model", context.WithValue(orgCtx("org-strict"), ctxKeyVoiceModel, "voice-model"), nil, llmParams{Model: "voice-model", Temperature: 0, MaxTokens: 200}},
+		{"no org", context.Background(), nil, llmParams{Model: "test-model", Temperature: llmTemperature, MaxTokens: llmMaxTokens}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got, _ := ts.svc.replyParams(tc.ctx, tc.decision); got != tc.want {
+				t.Fatalf("replyParams = %+v, want %+v", got, tc.want)
+			}
+		})
+	}
+
+	// Partial overrides keep the other defaults.
+	partial := llmParams{Model: "test-model", Temperature: llmTemperature, MaxTokens: llmMaxTokens}.withOverrides(&clinic.LLMOverrides{MaxTokens: 150})
+	if partial != (llmParams{Model: "test-model", Temperature: llmTemperature, MaxTokens: 150}) {
+		t.Fatalf("unexpected partial override %+v", partial)
+	}
+	// The classifier only takes stricter settings.
+	warm := 0.9
+	classifier := llmParams{Model: "test-model", Temperature: 0, MaxTokens: 256}.withStricterOverrides(&clinic.LLMOverrides{Model: "strict-model", Temperature: &warm, MaxTokens: 512})
+	if classifier != (llmParams{Model: "strict-model", Temperature: 0, MaxTokens: 256}) {
+		t.Fatalf("unexpected classifier params %+v", classifier)
+	}
+}
+
+func TestLLMOverridesPerOrgOnInterleavedRequests(t *testing.T) {
+	strict, warm := 0.0, 0.6
+	ts := setupService(t,
+		withClinicConfig("org-strict", func(cfg *clinic.Config) {
+			cfg.LLMOverrides = &clinic.LLMOverrides{Model: "strict-model", Temperature: &strict, MaxTokens: 150}
+		}),
+	)
+	cfg := clinic.DefaultConfig("org-friendly")
+	cfg.LLMOverrides = &clinic.LLMOverrides{Temperature: &warm}
+	if err := ts.clinicStore.Set(context.Background(), cfg); err != nil {
+		t.Fatalf("save config: %v", err)
+	}
+	llm := &orgRecordingLLM{}
+	ts.svc.client = llm
+
+	var wg sync.WaitGroup
+	for i := range 20 {
+		orgID := "org-strict"
+		if i%2 == 1 {
+			orgID = "org-friendly"
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			history := []ChatMessage{
+				{Role: ChatRoleSystem, Content: orgID},
+				{Role: ChatRoleUser, Content: fmt.Sprintf("message %d", i)},
+			}
+			if _, err := ts.svc.generateResponse(orgCtx(orgID), history); err != nil {
+				t.Errorf("generateResponse: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	want := map[string]LLMRequest{
+		"org-strict":   {Model: "strict-model", Temperature: 0, MaxTokens: 150},
+		"org-friendly": {Model: "test-model", Temperature: 0.6, MaxTokens: llmMaxTokens},
+	}
+	for orgID, w := range want {
+		reqs := llm.reqs[orgID]
+		if len(reqs) != 10 {
+			t.Fatalf("%s: expected 10 requests, got %d", orgID, len(reqs))
+		}
+		for _, req := range reqs {
+			if req.Model != w.Model || req.Temperature != w.Temperature || req.MaxTokens != w.MaxTokens {
+				t.Fatalf("%s: got model=%s temperature=%v max_tokens=%d, want %+v", orgID, req.Model, req.Temperature, req.MaxTokens, w)
+			}
+		}
+	}
+}
+
+func TestDepositClassifierUsesClinicModel(t *testing.T) {
+	strict := 0.0
+	ts := setupService(t, withClinicConfig("org-strict", func(cfg *clinic.Config) {
+		cfg.LLMOverrides = &clinic.LLMOverrides{Model: "strict-model", Temperature: &strict, MaxTokens: 120}
+	}))
+	ts.llm.response = LLMResponse{Text: `{"collect": false}`}
+
+	if _, err := ts.svc.extractDepositIntent(orgCtx("org-strict"), []ChatMessage{{Role: ChatRoleUser, Content: "yes, send the deposit link"}}); err != nil {
+		t.Fatalf("extractDepositIntent: %v", err)
+	}
+	if req := ts.llm.lastReq; req.Model != "strict-model" || req.Temperature != 0 || req.MaxTokens != 120 {
+		t.Fatalf("unexpected classifier request model=%s temperature=%v max_tokens=%d", req.Model, req.Temperature, req.MaxTokens)
+	}
+}
@@ -1,6 +1,7 @@
 package conversation
 
 import (
+	"context"
 	"strings"
 
 	"github.com/redis/go-redis/v9"
@@ -14,7 +15,10 @@ import (
 
 type contextKey string
 
-const ctxKeyVoiceModel contextKey = "voiceModel"
+const (
+	ctxKeyVoiceModel    contextKey = "voiceModel"
+	ctxKeyModelDecision contextKey = "modelDecision"
+)
 
 const (
 	maxHistoryMessages      = 40
@@ -49,11 +53,21 @@ type LLMService struct {
 	clinicStore      *clinic.Store
 	audit            *compliance.AuditService
 	paymentChecker   PaymentStatusChecker
+	appointments     AppointmentLookup
 	faqClassifier    *FAQClassifier
 	variantResolver  *VariantResolver
 	apiBaseURL       string // Public API base URL for callback URLs
 	events           *EventLogger
 	prefetcher       *AvailabilityPrefetcher
+	availability     *AvailabilityRouter
+	maxInboundChars  int
+	// contextTokenBudget caps per-turn context unless the clinic sets its own.
+	contextTokenBudget int
+	experiments        *ExperimentTracker
+	modelRouter        *ModelRouter
+	crmEvents          CRMEventPublisher
+	usage              LLMUsageRecorder
+	unappliedDeposits  DepositApplicationLookup
 }
 
 // NewLLMService returns an LLM-backed Service implementation.
@@ -83,6 +97,8 @@ func NewLLMService(client LLMClient, redisClient *redis.Client, rag RAGRetriever
 		events:          NewEventLogger(logger),
 	}
 
+	service.history.logger = logger
+
 	for _, opt := range opts {
 		opt(service)
 	}
@@ -93,6 +109,26 @@ func NewLLMService(client LLMClient, redisClient *redis.Client, rag RAGRetriever
 	if strings.TrimSpace(service.deposit.Description) == "" {
 		service.deposit.Description = defaultDepositDescription
 	}
+	if service.maxInboundChars <= 0 {
+		service.maxInboundChars = DefaultMaxInboundChars
+	}
+	if service.contextTokenBudget <= 0 {
+		service.contextTokenBudget = DefaultContextTokenBudget
+	}
+	if service.availability == nil && service.moxieClient != nil {
+		service.availability = NewAvailabilityRouter(logger, NewMoxieAPISource(service.moxieClient))
+	}
 
 	return service
 }
+
+// log returns the service logger tagged with the correlation fields on ctx.
+func (s *LLMService) log(ctx context.Context) *logging.Logger {
+	return s.logger.WithContext(ctx)
+}
+
+// FrustrationClassifier returns a frustration classifier that shares the
+// service's LLM client and model.
+func (s *LLMService) FrustrationClassifier() FrustrationClassifier {
+	return NewLLMFrustrationClassifier(s.client, s.model)
+}
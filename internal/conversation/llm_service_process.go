@@ -7,6 +7,8 @@ import (
 	"time"
 
 	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
 )
 
 // ProcessMessage continues an existing conversation with Redis-backed context.
@@ -21,13 +23,14 @@ func (s *LLMService) ProcessMessage(ctx context.Context, req MessageRequest) (*R
 	if strings.TrimSpace(req.ConversationID) == "" {
 		return nil, errors.New("conversation: conversationID required")
 	}
+	ctx = logging.WithFields(ctx, logging.Fields{OrgID: req.OrgID, ConversationID: req.ConversationID, LeadID: req.LeadID})
 
 	pc, earlyResp := s.newProcessContext(ctx, req)
 	if earlyResp != nil {
 		return earlyResp, nil
 	}
 
-	s.logger.Info("ProcessMessage called",
+	s.log(ctx).Info("ProcessMessage called",
 		"conversation_id", req.ConversationID,
 		"org_id", req.OrgID,
 		"lead_id", req.LeadID,
@@ -41,6 +44,7 @@ func (s *LLMService) ProcessMessage(ctx context.Context, req MessageRequest) (*R
 		attribute.String("medspa.conversation_id", req.ConversationID),
 		attribute.String("medspa.channel", string(req.Channel)),
 	)
+	span.SetAttributes(logging.SpanAttributes(ctx)...)
 	pc.span = span
 
 	resp, histErr := s.loadHistory(ctx, pc)
@@ -51,12 +55,14 @@ func (s *LLMService) ProcessMessage(ctx context.Context, req MessageRequest) (*R
 		return resp, nil
 	}
 	if pc.history == nil {
+		// Pass the original text so StartConversation applies its own length
+		// and content checks.
 		return s.StartConversation(ctx, StartRequest{
 			OrgID:          req.OrgID,
 			ConversationID: req.ConversationID,
 			LeadID:         req.LeadID,
 			ClinicID:       req.ClinicID,
-			Intro:          pc.rawMessage,
+			Intro:          req.Message,
 			Channel:        req.Channel,
 			From:           req.From,
 			To:             req.To,
@@ -67,8 +73,14 @@ func (s *LLMService) ProcessMessage(ctx context.Context, req MessageRequest) (*R
 	if resp := s.handleSafetyDeflections(ctx, pc); resp != nil {
 		return resp, nil
 	}
+	if resp := s.handleInboundLimits(ctx, pc); resp != nil {
+		return resp, nil
+	}
 
 	pc.history = s.appendContext(ctx, pc.history, req.OrgID, req.LeadID, req.ClinicID, pc.rawMessage)
+	if msg, ok := broadcastReplyContext(req.Metadata); ok {
+		pc.history = append(pc.history, msg)
+	}
 	pc.history = append(pc.history, ChatMessage{Role: ChatRoleUser, Content: pc.rawMessage})
 
 	if s.clinicStore != nil && req.OrgID != "" {
@@ -77,6 +89,15 @@ func (s *LLMService) ProcessMessage(ctx context.Context, req MessageRequest) (*R
 		}
 	}
 
+	if resp := s.handleMarketingConsentReply(ctx, pc); resp != nil {
+		return resp, nil
+	}
+	if resp := s.handleEmailCapture(ctx, pc); resp != nil {
+		return resp, nil
+	}
+	if resp := s.handleWrongNumber(ctx, pc); resp != nil {
+		return resp, nil
+	}
 	if resp := s.handleDeterministicGuardrails(ctx, pc); resp != nil {
 		return resp, nil
 	}
@@ -86,13 +107,25 @@ func (s *LLMService) ProcessMessage(ctx context.Context, req MessageRequest) (*R
 
 	s.loadTimeSelectionState(ctx, pc)
 	s.handleActiveTimeSelection(ctx, pc)
-	s.injectMoxieQualificationGuardrails(ctx, pc)
+	pc.existingAppointment = existingAppointmentMode(pc.history)
+	if pc.existingAppointment {
+		pc.history = append(pc.history, s.existingAppointmentContext(ctx, pc.cfg, req.OrgID, req.LeadID))
+	} else {
+		if existingAppointmentEnded(pc.history) {
+			pc.history = append(pc.history, ChatMessage{Role: ChatRoleSystem, Content: existingAppointmentEndedGuardrail})
+		}
+		s.injectMoxieQualificationGuardrails(ctx, pc)
+	}
 
+	ctx, decision := s.routeModel(ctx, req.Channel, req.OrgID, pc.rawMessage, pc.history, pc.cfg)
 	reply, err := s.generateResponse(ctx, pc.history)
+	s.recordModelRoute(ctx, req.OrgID, req.ConversationID, req.LeadID, decision)
 	if err != nil {
 		return nil, err
 	}
 	reply = sanitizeSMSResponse(reply)
+	reply = s.guardDuplicateQuestion(ctx, pc.history, pc.cfg, req.ConversationID, reply)
+	reply = s.guardPaymentAcknowledgement(ctx, pc, reply)
 	pc.reply = reply
 	pc.history = append(pc.history, ChatMessage{Role: ChatRoleAssistant, Content: reply})
 	pc.history = trimHistory(pc.history, maxHistoryMessages)
@@ -102,6 +135,9 @@ func (s *LLMService) ProcessMessage(ctx context.Context, req MessageRequest) (*R
 	}
 
 	s.handlePostLLMResponse(ctx, pc)
+	if pc.depositIntent != nil {
+		s.RecordExperimentStage(ctx, req.ConversationID, ExperimentStageDepositRequested)
+	}
 
 	return &Response{
 		ConversationID:        req.ConversationID,
@@ -7,8 +7,10 @@ import (
 	"strings"
 	"time"
 
-	"github.com/wolfman30/medspa-ai-platform/internal/leads"
 	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/leads"
+	"github.com/wolfman30/medspa-ai-platform/internal/tenancy"
 )
 
 const llmCompletionTimeout = 60 * time.Second
@@ -21,16 +23,22 @@ func (s *LLMService) generateResponse(ctx context.Context, history []ChatMessage
 	trimmed := trimHistory(history, maxHistoryMessages)
 	system, messages := splitSystemAndMessages(trimmed)
 
-	model := s.model
-	if m, ok := ctx.Value(ctxKeyVoiceModel).(string); ok && m != "" {
-		model = m
+	decision, _ := ctx.Value(ctxKeyModelDecision).(*ModelDecision)
+	params, overrides := s.replyParams(ctx, decision)
+	model := params.Model
+	if decision != nil {
+		// Routing metrics and events report the model that actually answered.
+		decision.Model = model
+	}
+	if overrides != nil {
+		s.auditLLMOverride(ctx, llmPurposeReply, params)
 	}
 	req := LLMRequest{
 		Model:       model,
 		System:      system,
 		Messages:    messages,
-		MaxTokens:   llmMaxTokens,
-		Temperature: llmTemperature,
+		MaxTokens:   params.MaxTokens,
+		Temperature: params.Temperature,
 	}
 	callCtx, cancel := context.WithTimeout(ctx, llmCompletionTimeout)
 	defer cancel()
@@ -38,15 +46,21 @@ func (s *LLMService) generateResponse(ctx context.Context, history []ChatMessage
 	start := time.Now()
 	resp, err := s.client.Complete(callCtx, req)
 	latency := time.Since(start)
+	addTurnStage(ctx, TurnStageLLM, latency)
+	if decision != nil {
+		decision.Latency = latency
+	}
 	status := "ok"
 	if err != nil {
 		status = "error"
 	}
-	llmLatency.WithLabelValues(s.model, status).Observe(latency.Seconds())
+	llmLatency.WithLabelValues(model, status).Observe(latency.Seconds())
 	if span.IsRecording() {
 		span.SetAttributes(
 			attribute.Float64("medspa.llm.latency_ms", float64(latency.Milliseconds())),
-			attribute.String("medspa.llm.model", s.model),
+			attribute.String("medspa.llm.model", model),
+			attribute.Float64("medspa.llm.temperature", float64(params.Temperature)),
+			attribute.Int("medspa.llm.max_tokens", int(params.MaxTokens)),
 			attribute.Int("medspa.llm.input_tokens", int(resp.Usage.InputTokens)),
 			attribute.Int("medspa.llm.output_tokens", int(resp.Usage.OutputTokens)),
 			attribute.Int("medspa.llm.total_tokens", int(resp.Usage.TotalTokens)),
@@ -55,22 +69,23 @@ func (s *LLMService) generateResponse(ctx context.Context, history []ChatMessage
 	}
 	if err != nil {
 		span.RecordError(err)
-		s.logger.Warn("llm completion failed", "model", s.model, "latency_ms", latency.Milliseconds(), "error", err)
+		s.log(ctx).Warn("llm completion failed", "model", model, "latency_ms", latency.Milliseconds(), "error", err)
 		return "", fmt.Errorf("conversation: llm completion failed: %w", err)
 	}
 	if resp.Usage.InputTokens > 0 {
-		llmTokensTotal.WithLabelValues(s.model, "input").Add(float64(resp.Usage.InputTokens))
+		llmTokensTotal.WithLabelValues(model, "input").Add(float64(resp.Usage.InputTokens))
 	}
 	if resp.Usage.OutputTokens > 0 {
-		llmTokensTotal.WithLabelValues(s.model, "output").Add(float64(resp.Usage.OutputTokens))
+		llmTokensTotal.WithLabelValues(model, "output").Add(float64(resp.Usage.OutputTokens))
 	}
 	if resp.Usage.TotalTokens > 0 {
-		llmTokensTotal.WithLabelValues(s.model, "total").Add(float64(resp.Usage.TotalTokens))
+		llmTokensTotal.WithLabelValues(model, "total").Add(float64(resp.Usage.TotalTokens))
 	}
+	s.recordLLMUsage(ctx, model, resp.Usage)
 
 	text := strings.TrimSpace(resp.Text)
-	s.logger.Info("llm completion finished",
-		"model", s.model,
+	s.log(ctx).Info("llm completion finished",
+		"model", model,
 		"latency_ms", latency.Milliseconds(),
 		"input_tokens", resp.Usage.InputTokens,
 		"output_tokens", resp.Usage.OutputTokens,
@@ -104,5 +119,5 @@ func (s *LLMService) ClearLeadPreferences(ctx context.Context, orgID, phone stri
 	if err != nil {
 		return fmt.Errorf("get lead: %w", err)
 	}
-	return s.leadsRepo.UpdateSchedulingPreferences(ctx, lead.ID, leads.SchedulingPreferences{})
+	return s.leadsRepo.UpdateSchedulingPreferences(ctx, tenancy.ForOrg(lead.OrgID), lead.ID, leads.SchedulingPreferences{})
 }
@@ -7,8 +7,11 @@ import (
 	"time"
 
 	"github.com/google/uuid"
-	"github.com/wolfman30/medspa-ai-platform/internal/clinic"
 	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/clinic"
+	"github.com/wolfman30/medspa-ai-platform/internal/tenancy"
+	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
 )
 
 // StartConversation opens a new thread, generates the first assistant response, and persists context.
@@ -16,6 +19,14 @@ func (s *LLMService) StartConversation(ctx context.Context, req StartRequest) (*
 	if isVoiceChannel(req.Channel) && s.voiceModel != "" {
 		ctx = context.WithValue(ctx, ctxKeyVoiceModel, s.voiceModel)
 	}
+	ctx = logging.WithFields(ctx, logging.Fields{OrgID: req.OrgID, ConversationID: req.ConversationID, LeadID: req.LeadID})
+	inbound := SanitizeInbound(req.Intro, s.maxInboundChars)
+	if req.Intro != "" {
+		req.Intro = inbound.Text
+	}
+	if len(inbound.Sensitive) > 0 {
+		return s.sensitiveDataResponse(ctx, req.OrgID, req.ConversationID, req.LeadID, inbound.Sensitive), nil
+	}
 	filter := FilterInbound(req.Intro)
 	redactedIntro := filter.RedactedMsg
 	sawPHI := filter.SawPHI
@@ -25,7 +36,7 @@ func (s *LLMService) StartConversation(ctx context.Context, req StartRequest) (*
 	if filter.DeflectionMsg == blockedReply {
 		injectionResult := ScanForPromptInjection(req.Intro)
 		s.events.PromptInjectionDetected(ctx, req.ConversationID, req.OrgID, true, injectionResult.Score, injectionResult.Reasons)
-		s.logger.Warn("StartConversation: prompt injection BLOCKED",
+		s.log(ctx).Warn("StartConversation: prompt injection BLOCKED",
 			"org_id", req.OrgID,
 			"score", injectionResult.Score,
 			"reasons", injectionResult.Reasons,
@@ -38,7 +49,7 @@ func (s *LLMService) StartConversation(ctx context.Context, req StartRequest) (*
 	if filter.Sanitized != req.Intro {
 		injectionResult := ScanForPromptInjection(req.Intro)
 		s.events.PromptInjectionDetected(ctx, req.ConversationID, req.OrgID, false, injectionResult.Score, injectionResult.Reasons)
-		s.logger.Warn("StartConversation: prompt injection WARNING",
+		s.log(ctx).Warn("StartConversation: prompt injection WARNING",
 			"org_id", req.OrgID,
 			"score", injectionResult.Score,
 			"reasons", injectionResult.Reasons,
@@ -48,7 +59,7 @@ func (s *LLMService) StartConversation(ctx context.Context, req StartRequest) (*
 
 	s.events.ConversationStarted(ctx, req.ConversationID, req.OrgID, req.LeadID, req.From, string(req.Source))
 
-	s.logger.Info("StartConversation called",
+	s.log(ctx).Info("StartConversation called",
 		"conversation_id", req.ConversationID,
 		"org_id", req.OrgID,
 		"intro", redactedIntro,
@@ -65,12 +76,14 @@ func (s *LLMService) StartConversation(ctx context.Context, req StartRequest) (*
 			base = uuid.NewString()
 		}
 		conversationID = fmt.Sprintf("conv_%s_%d", base, time.Now().UnixNano())
+		ctx = logging.WithFields(ctx, logging.Fields{ConversationID: conversationID})
 	}
 	span.SetAttributes(
 		attribute.String("medspa.org_id", req.OrgID),
 		attribute.String("medspa.conversation_id", conversationID),
 		attribute.String("medspa.channel", string(req.Channel)),
 	)
+	span.SetAttributes(logging.SpanAttributes(ctx)...)
 
 	safeReq := req
 	if sawPHI {
@@ -93,6 +106,8 @@ func (s *LLMService) StartConversation(ctx context.Context, req StartRequest) (*
 	var systemPrompt string
 	if isVoiceChannel(req.Channel) {
 		systemPrompt = buildVoiceSystemPrompt(int(depositCents), usesMoxie, startCfg)
+	} else if variant := s.assignPromptExperiment(ctx, req.OrgID, conversationID); variant != "" {
+		systemPrompt = buildSystemPromptFrom(variant, int(depositCents), usesMoxie, startCfg)
 	} else {
 		systemPrompt = buildSystemPrompt(int(depositCents), usesMoxie, startCfg)
 	}
@@ -100,6 +115,12 @@ func (s *LLMService) StartConversation(ctx context.Context, req StartRequest) (*
 	if req.Silent {
 		history := []ChatMessage{{Role: ChatRoleSystem, Content: systemPrompt}}
 		history = s.appendContext(ctx, history, req.OrgID, req.LeadID, req.ClinicID, "")
+		if msg, ok := defaultServiceContext(req.Metadata); ok {
+			history = append(history, msg)
+		}
+		if msg, ok := broadcastReplyContext(req.Metadata); ok {
+			history = append(history, msg)
+		}
 		if req.AckMessage != "" {
 			history = append(history, ChatMessage{Role: ChatRoleAssistant, Content: req.AckMessage})
 		}
@@ -152,12 +173,94 @@ func (s *LLMService) StartConversation(ctx context.Context, req StartRequest) (*
 		return &Response{ConversationID: conversationID, Message: medicalAdviceDeflectionReply, Timestamp: time.Now().UTC()}, nil
 	}
 
+	if cannedReply := inbound.CannedReply(); cannedReply != "" {
+		s.log(ctx).Info("StartConversation: inbound message not sent to LLM",
+			"conversation_id", conversationID,
+			"truncated", inbound.Truncated,
+			"original_chars", inbound.OriginalChars,
+			"link_only", inbound.LinkOnly,
+			"media_only", inbound.MediaOnly,
+		)
+		// Record the prompt-guard-sanitized text, with any truncation note.
+		inbound.Text = req.Intro
+		noteReq := req
+		noteReq.Intro = inbound.HistoryText()
+		history := []ChatMessage{{Role: ChatRoleSystem, Content: systemPrompt}}
+		history = s.appendContext(ctx, history, req.OrgID, req.LeadID, req.ClinicID, "")
+		history = append(history, ChatMessage{Role: ChatRoleUser, Content: formatIntroMessage(noteReq, conversationID)})
+		history = append(history, ChatMessage{Role: ChatRoleAssistant, Content: cannedReply})
+		history = trimHistory(history, maxHistoryMessages)
+		if err := s.history.Save(ctx, conversationID, history); err != nil {
+			span.RecordError(err)
+			return nil, err
+		}
+		return &Response{ConversationID: conversationID, Message: cannedReply, Timestamp: time.Now().UTC()}, nil
+	}
+
+	if entry, ok := startCfg.NotOfferedServiceIn(req.Intro); ok {
+		s.log(ctx).Info("StartConversation: declined not-offered service", "conversation_id", conversationID, "service", entry.Service)
+		reply := notOfferedReply(startCfg, entry)
+		history := []ChatMessage{{Role: ChatRoleSystem, Content: systemPrompt}}
+		history = s.appendContext(ctx, history, req.OrgID, req.LeadID, req.ClinicID, "")
+		history = append(history, ChatMessage{Role: ChatRoleUser, Content: formatIntroMessage(safeReq, conversationID)})
+		history = append(history, ChatMessage{Role: ChatRoleAssistant, Content: reply.Body})
+		history = trimHistory(history, maxHistoryMessages)
+		if err := s.history.Save(ctx, conversationID, history); err != nil {
+			span.RecordError(err)
+			return nil, err
+		}
+		s.appendLeadNote(ctx, req.OrgID, req.LeadID, notOfferedLeadNote)
+		return &Response{ConversationID: conversationID, Message: reply.Body, Template: reply.Ref, Timestamp: time.Now().UTC()}, nil
+	}
+
+	if entry, ok := startCfg.ConsultRequiredServiceIn(req.Intro); ok {
+		s.log(ctx).Info("StartConversation: offered consultation for consult-first service", "conversation_id", conversationID, "service", entry.Service)
+		reply := consultRequiredReply(startCfg, entry)
+		history := []ChatMessage{{Role: ChatRoleSystem, Content: systemPrompt}}
+		history = s.appendContext(ctx, history, req.OrgID, req.LeadID, req.ClinicID, "")
+		history = append(history, ChatMessage{Role: ChatRoleUser, Content: formatIntroMessage(safeReq, conversationID)})
+		history = append(history, ChatMessage{Role: ChatRoleAssistant, Content: reply.Body})
+		history = trimHistory(history, maxHistoryMessages)
+		if err := s.history.Save(ctx, conversationID, history); err != nil {
+			span.RecordError(err)
+			return nil, err
+		}
+		s.savePreferencesNoNote(ctx, startCfg, req.LeadID, history, "consult_required_service")
+		s.appendLeadNote(ctx, req.OrgID, req.LeadID, consultRequiredLeadNote)
+		return &Response{ConversationID: conversationID, Message: reply.Body, Template: reply.Ref, Timestamp: time.Now().UTC()}, nil
+	}
+
+	if !showsMedSpaIntent(req.Intro, startCfg) && isWrongNumberMessage(req.Intro, startCfg) {
+		s.log(ctx).Info("StartConversation: probable wrong number", "conversation_id", conversationID, "lead_id", req.LeadID)
+		reply := wrongNumberReply(startCfg)
+		history := []ChatMessage{{Role: ChatRoleSystem, Content: systemPrompt}}
+		history = s.appendContext(ctx, history, req.OrgID, req.LeadID, req.ClinicID, "")
+		history = append(history, ChatMessage{Role: ChatRoleUser, Content: formatIntroMessage(safeReq, conversationID)})
+		history = append(history, ChatMessage{Role: ChatRoleAssistant, Content: reply.Body})
+		history = trimHistory(history, maxHistoryMessages)
+		if err := s.history.Save(ctx, conversationID, history); err != nil {
+			span.RecordError(err)
+			return nil, err
+		}
+		s.setProbableWrongNumber(ctx, req.LeadID, true)
+		return &Response{ConversationID: conversationID, Message: reply.Body, Template: reply.Ref, Timestamp: time.Now().UTC()}, nil
+	}
+
 	history := []ChatMessage{{Role: ChatRoleSystem, Content: systemPrompt}}
 	history = s.appendContext(ctx, history, req.OrgID, req.LeadID, req.ClinicID, req.Intro)
 	history = append(history, ChatMessage{Role: ChatRoleUser, Content: formatIntroMessage(safeReq, conversationID)})
+	if msg, ok := defaultServiceContext(req.Metadata); ok {
+		history = append(history, msg)
+	}
+	if msg, ok := broadcastReplyContext(req.Metadata); ok {
+		history = append(history, msg)
+	}
 
-	if startCfg != nil && startCfg.UsesBookingAPI() {
-		prefs, _ := extractPreferences(history, serviceAliasesFromConfig(startCfg))
+	existingAppointment := existingAppointmentMode(history)
+	if existingAppointment {
+		history = append(history, s.existingAppointmentContext(ctx, startCfg, req.OrgID, req.LeadID))
+	} else if startCfg != nil && startCfg.UsesBookingAPI() {
+		prefs, _ := extractClinicPreferences(history, startCfg)
 		if prefs.ServiceInterest != "" && s.prefetcher != nil {
 			s.prefetcher.StartPrefetch(ctx, req.OrgID, startCfg, prefs.ServiceInterest, prefs.ProviderPreference)
 		}
@@ -183,8 +286,8 @@ func (s *LLMService) StartConversation(ctx context.Context, req StartRequest) (*
 		}
 	}
 
-	if isVoiceChannel(req.Channel) && startCfg != nil {
-		prefs, _ := extractPreferences(history, serviceAliasesFromConfig(startCfg))
+	if isVoiceChannel(req.Channel) && startCfg != nil && !existingAppointment {
+		prefs, _ := extractClinicPreferences(history, startCfg)
 		var collected []string
 		if prefs.Name != "" {
 			collected = append(collected, fmt.Sprintf("Name: %s", prefs.Name))
@@ -203,12 +306,18 @@ func (s *LLMService) StartConversation(ctx context.Context, req StartRequest) (*
 		}
 	}
 
+	ctx, decision := s.routeModel(ctx, req.Channel, req.OrgID, req.Intro, history, startCfg)
 	reply, err := s.generateResponse(ctx, history)
+	s.recordModelRoute(ctx, req.OrgID, conversationID, req.LeadID, decision)
 	if err != nil {
 		span.RecordError(err)
 		return nil, err
 	}
 	reply = sanitizeSMSResponse(reply)
+	reply = s.guardDuplicateQuestion(ctx, history, startCfg, conversationID, reply)
+	if followUp := s.captureIntroEmail(ctx, tenancy.ForOrg(req.OrgID), req.LeadID, req.Intro); followUp != "" {
+		reply += "\n\n" + followUp
+	}
 	history = append(history, ChatMessage{Role: ChatRoleAssistant, Content: reply})
 
 	history = trimHistory(history, maxHistoryMessages)
@@ -218,25 +327,21 @@ func (s *LLMService) StartConversation(ctx context.Context, req StartRequest) (*
 	}
 
 	if req.LeadID != "" && s.leadsRepo != nil {
-		if err := s.extractAndSavePreferences(ctx, req.LeadID, history); err != nil {
-			s.logger.Warn("failed to save scheduling preferences from intro", "lead_id", req.LeadID, "error", err)
-		}
-		if email := ExtractEmailFromHistory(history); email != "" {
-			if err := s.leadsRepo.UpdateEmail(ctx, req.LeadID, email); err != nil {
-				s.logger.Warn("failed to save email", "lead_id", req.LeadID, "error", err)
-			}
+		if err := s.extractAndSavePreferences(ctx, startCfg, req.LeadID, history); err != nil {
+			s.log(ctx).Warn("failed to save scheduling preferences from intro", "lead_id", req.LeadID, "error", err)
 		}
 	}
 
 	resp := &Response{ConversationID: conversationID, Message: reply, Timestamp: time.Now().UTC()}
 
-	moxieAPIReady := s.moxieClient != nil && startCfg != nil && startCfg.MoxieConfig != nil
+	availabilityReady := s.availability != nil && s.availability.Supports(startCfg)
 	boulevardReady := s.boulevardAdapter != nil && startCfg != nil && startCfg.UsesBoulevardBooking()
-	bookingAPIReady := moxieAPIReady || boulevardReady
-	if bookingAPIReady && usesMoxie && ShouldFetchAvailabilityWithConfig(history, nil, startCfg) {
-		prefs, _ := extractPreferences(history, serviceAliasesFromConfig(startCfg))
+	bookingAPIReady := availabilityReady || boulevardReady
+	qualified := s.qualificationsMet(ctx, history, startCfg) || pendingExtraQualificationQuestion(history, startCfg) != ""
+	if bookingAPIReady && usesMoxie && qualified && !existingAppointment {
+		prefs, _ := extractClinicPreferences(history, startCfg)
 		if !hasSchedulePreferences(&prefs) {
-			s.logger.Info("StartConversation: skipping time selection — no schedule preferences yet", "conversation_id", conversationID)
+			s.log(ctx).Info("StartConversation: skipping time selection — no schedule preferences yet", "conversation_id", conversationID)
 			return resp, nil
 		}
 
@@ -254,7 +359,12 @@ func (s *LLMService) StartConversation(ctx context.Context, req StartRequest) (*
 			return resp, nil
 		}
 
-		tsResp := s.fetchAndPresentAvailability(ctx, &prefs, startCfg, startCfg.BookingURL, conversationID, req.OrgID, nil)
+		if extraResp := s.handleExtraQualification(ctx, startCfg, &prefs, history, conversationID, req.LeadID); extraResp != nil {
+			resp.Message = extraResp.Message
+			return resp, nil
+		}
+
+		tsResp := s.fetchAndPresentAvailability(ctx, &prefs, startCfg, startCfg.BookingURL, conversationID, req.OrgID, req.LeadID, req.From, nil)
 		if tsResp != nil && len(tsResp.Slots) > 0 {
 			tsResp.SavedToHistory = true
 			resp.TimeSelectionResponse = tsResp
@@ -265,7 +375,7 @@ func (s *LLMService) StartConversation(ctx context.Context, req StartRequest) (*
 				}
 			}
 			if saveErr := s.history.Save(ctx, conversationID, history); saveErr != nil {
-				s.logger.Warn("StartConversation: failed to re-save history after time selection", "error", saveErr)
+				s.log(ctx).Warn("StartConversation: failed to re-save history after time selection", "error", saveErr)
 			}
 		} else if tsResp != nil && tsResp.SMSMessage != "" {
 			resp.TimeSelectionResponse = tsResp
@@ -2,7 +2,6 @@ package conversation
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
 	"strings"
 	"testing"
@@ -13,6 +12,7 @@ import (
 	"github.com/redis/go-redis/v9"
 	"github.com/wolfman30/medspa-ai-platform/internal/clinic"
 	"github.com/wolfman30/medspa-ai-platform/internal/leads"
+	"github.com/wolfman30/medspa-ai-platform/internal/tenancy"
 	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
 )
 
@@ -57,7 +57,7 @@ func TestTierA_CI03_ExistingLeadResumes_NoDuplicateWelcome(t *testing.T) {
 		t.Fatalf("read history: %v", err)
 	}
 	var history []ChatMessage
-	if err := json.Unmarshal([]byte(raw), &history); err != nil {
+	if history, err = decodeHistory([]byte(raw)); err != nil {
 		t.Fatalf("decode history: %v", err)
 	}
 	welcomeCount := 0
@@ -131,7 +131,7 @@ func TestTierA_CI04_PriceServiceInquiry_UsesClinicConfigAndTagsLead(t *testing.T
 	if len(mockLLM.requests) != 1 {
 		t.Fatalf("expected no extra LLM calls for price inquiry, got %d", len(mockLLM.requests))
 	}
-	updated, err := leadsRepo.GetByID(ctx, "org-1", lead.ID)
+	updated, err := leadsRepo.GetByID(ctx, tenancy.ForOrg("org-1"), lead.ID)
 	if err != nil {
 		t.Fatalf("load lead: %v", err)
 	}
@@ -191,7 +191,7 @@ func TestTierA_CI05_AmbiguousMessage_AsksClarifyingQuestionAndTagsLead(t *testin
 	if len(mockLLM.requests) != 1 {
 		t.Fatalf("expected no extra LLM calls for ambiguous help, got %d", len(mockLLM.requests))
 	}
-	updated, err := leadsRepo.GetByID(ctx, "org-1", lead.ID)
+	updated, err := leadsRepo.GetByID(ctx, tenancy.ForOrg("org-1"), lead.ID)
 	if err != nil {
 		t.Fatalf("load lead: %v", err)
 	}
@@ -258,7 +258,7 @@ func TestTierA_CI05b_QuickQuestionPromptsForDetails(t *testing.T) {
 	if len(mockLLM.requests) != 1 {
 		t.Fatalf("expected no extra LLM calls for quick question, got %d", len(mockLLM.requests))
 	}
-	updated, err := leadsRepo.GetByID(ctx, "org-1", lead.ID)
+	updated, err := leadsRepo.GetByID(ctx, tenancy.ForOrg("org-1"), lead.ID)
 	if err != nil {
 		t.Fatalf("load lead: %v", err)
 	}
@@ -410,7 +410,7 @@ func TestTierA_CI13_HIPAA_PHIDeflection_NoLeadDiagnosisUpdates(t *testing.T) {
 	if len(mockLLM.requests) != 1 {
 		t.Fatalf("expected no extra LLM calls for PHI deflection, got %d", len(mockLLM.requests))
 	}
-	updated, err := leadsRepo.GetByID(ctx, "org-1", lead.ID)
+	updated, err := leadsRepo.GetByID(ctx, tenancy.ForOrg("org-1"), lead.ID)
 	if err != nil {
 		t.Fatalf("load lead: %v", err)
 	}
@@ -422,7 +422,7 @@ func TestTierA_CI13_HIPAA_PHIDeflection_NoLeadDiagnosisUpdates(t *testing.T) {
 		t.Fatalf("read history: %v", err)
 	}
 	var history []ChatMessage
-	if err := json.Unmarshal([]byte(raw), &history); err != nil {
+	if history, err = decodeHistory([]byte(raw)); err != nil {
 		t.Fatalf("decode history: %v", err)
 	}
 	foundRedacted := false
@@ -481,7 +481,7 @@ func TestLLMService_ProcessMessage_MedicalAdviceDeflection(t *testing.T) {
 		t.Fatalf("read history: %v", err)
 	}
 	var history []ChatMessage
-	if err := json.Unmarshal([]byte(raw), &history); err != nil {
+	if history, err = decodeHistory([]byte(raw)); err != nil {
 		t.Fatalf("decode history: %v", err)
 	}
 	foundRedacted := false
@@ -529,10 +529,10 @@ func TestLLMService_StartConversation_RedactsPHI(t *testing.T) {
 		t.Fatalf("read history: %v", err)
 	}
 	var history []ChatMessage
-	if err := json.Unmarshal([]byte(raw), &history); err != nil {
+	if history, err = decodeHistory([]byte(raw)); err != nil {
 		t.Fatalf("decode history: %v", err)
 	}
-	if !strings.Contains(raw, "[REDACTED]") {
+	if !historyContains(history, "[REDACTED]") {
 		t.Fatalf("expected redacted PHI in history")
 	}
 	for _, msg := range history {
@@ -572,13 +572,13 @@ func TestLLMService_StartConversation_MedicalAdviceDeflection(t *testing.T) {
 	if err != nil {
 		t.Fatalf("read history: %v", err)
 	}
-	if !strings.Contains(raw, "[REDACTED]") {
-		t.Fatalf("expected redacted medical advice in history")
-	}
 	var history []ChatMessage
-	if err := json.Unmarshal([]byte(raw), &history); err != nil {
+	if history, err = decodeHistory([]byte(raw)); err != nil {
 		t.Fatalf("decode history: %v", err)
 	}
+	if !historyContains(history, "[REDACTED]") {
+		t.Fatalf("expected redacted medical advice in history")
+	}
 	for _, msg := range history {
 		if strings.Contains(strings.ToLower(msg.Content), "ibuprofen") {
 			t.Fatalf("expected medical advice content to be redacted from history, got %q", msg.Content)
@@ -616,7 +616,7 @@ func TestLLMService_StartConversation_PersistsHistory(t *testing.T) {
 		t.Fatalf("failed to read history from redis: %v", err)
 	}
 	var history []ChatMessage
-	if err := json.Unmarshal([]byte(raw), &history); err != nil {
+	if history, err = decodeHistory([]byte(raw)); err != nil {
 		t.Fatalf("failed to decode stored history: %v", err)
 	}
 	if len(history) != 3 {
@@ -627,6 +627,74 @@ func TestLLMService_StartConversation_PersistsHistory(t *testing.T) {
 	}
 }
 
+func TestLLMService_StartConversation_DefaultServiceFromNumberRoute(t *testing.T) {
+	mr := miniredis.RunT(t)
+	defer mr.Close()
+
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	mockLLM := &stubLLMClient{response: LLMResponse{Text: "Happy to help with weight loss!"}}
+
+	service := NewLLMService(mockLLM, client, nil, "anthropic.claude-3-haiku-20240307-v1:0", logging.Default())
+	if _, err := service.StartConversation(context.Background(), StartRequest{
+		Intro:    "Hi, how much does it cost?",
+		Channel:  ChannelSMS,
+		OrgID:    "org-1",
+		Metadata: map[string]string{MetadataDefaultService: "weight loss"},
+	}); err != nil {
+		t.Fatalf("StartConversation returned error: %v", err)
+	}
+
+	found := false
+	for _, sys := range mockLLM.lastReq.System {
+		if strings.Contains(sys, "weight loss line") && strings.Contains(sys, "Do NOT ask what service") {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatalf("expected default service context in system prompt, got %v", mockLLM.lastReq.System)
+	}
+}
+
+func TestLLMService_ProcessMessage_BroadcastReplyContext(t *testing.T) {
+	mr := miniredis.RunT(t)
+	defer mr.Close()
+
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	mockLLM := &stubLLMClient{response: LLMResponse{Text: "Great, Friday at 2 PM is open!"}}
+
+	service := NewLLMService(mockLLM, client, nil, "anthropic.claude-3-haiku-20240307-v1:0", logging.Default())
+	startResp, err := service.StartConversation(context.Background(), StartRequest{
+		Intro:   "Hi",
+		Channel: ChannelSMS,
+		OrgID:   "org-1",
+	})
+	if err != nil {
+		t.Fatalf("start failed: %v", err)
+	}
+	if _, err := service.ProcessMessage(context.Background(), MessageRequest{
+		ConversationID: startResp.ConversationID,
+		Message:        "yes!",
+		Channel:        ChannelSMS,
+		OrgID:          "org-1",
+		Metadata: map[string]string{
+			MetadataBroadcastID:      "b-1",
+			MetadataBroadcastMessage: "We have 3 Botox openings Friday!",
+		},
+	}); err != nil {
+		t.Fatalf("ProcessMessage returned error: %v", err)
+	}
+
+	found := false
+	for _, sys := range mockLLM.lastReq.System {
+		if strings.Contains(sys, "replying to this text") && strings.Contains(sys, "3 Botox openings Friday") {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatalf("expected broadcast reply context in system prompt, got %v", mockLLM.lastReq.System)
+	}
+}
+
 func TestLLMService_ProcessMessage_LoadsExistingHistory(t *testing.T) {
 	mr := miniredis.RunT(t)
 	defer mr.Close()
@@ -666,7 +734,7 @@ func TestLLMService_ProcessMessage_LoadsExistingHistory(t *testing.T) {
 	if err != nil {
 		t.Fatalf("failed to fetch stored history: %v", err)
 	}
-	if err := json.Unmarshal([]byte(raw), &history); err != nil {
+	if history, err = decodeHistory([]byte(raw)); err != nil {
 		t.Fatalf("failed to decode history: %v", err)
 	}
 	if history[len(history)-2].Content != "Do you have Friday afternoon?" {
@@ -702,7 +770,7 @@ func TestLLMService_ProcessMessage_UnknownConversationBootstraps(t *testing.T) {
 		t.Fatalf("expected conversation history to persist: %v", err)
 	}
 	var history []ChatMessage
-	if err := json.Unmarshal([]byte(raw), &history); err != nil {
+	if history, err = decodeHistory([]byte(raw)); err != nil {
 		t.Fatalf("failed to decode history: %v", err)
 	}
 	if got := history[len(history)-1].Content; got != "Welcome back!" {
@@ -978,11 +1046,11 @@ type stubOpenDepositStatusChecker struct {
 	status string
 }
 
-func (s *stubOpenDepositStatusChecker) HasOpenDeposit(ctx context.Context, orgID uuid.UUID, leadID uuid.UUID) (bool, error) {
+func (s *stubOpenDepositStatusChecker) HasOpenDeposit(ctx context.Context, scope tenancy.OrgScope, leadID uuid.UUID) (bool, error) {
 	return strings.TrimSpace(s.status) != "", nil
 }
 
-func (s *stubOpenDepositStatusChecker) OpenDepositStatus(ctx context.Context, orgID uuid.UUID, leadID uuid.UUID) (string, error) {
+func (s *stubOpenDepositStatusChecker) OpenDepositStatus(ctx context.Context, scope tenancy.OrgScope, leadID uuid.UUID) (string, error) {
 	return s.status, nil
 }
 
@@ -1711,7 +1779,7 @@ func TestMoxieTimeSelection_EmailFallbackFromConversation(t *testing.T) {
 	}
 
 	// Verify email was saved to lead
-	updatedLead, err := leadsRepo.GetByID(ctx, "org-emailfb", lead.ID)
+	updatedLead, err := leadsRepo.GetByID(ctx, tenancy.ForOrg("org-emailfb"), lead.ID)
 	if err != nil {
 		t.Fatalf("get lead: %v", err)
 	}
@@ -1780,3 +1848,13 @@ func TestLooksLikePersonName(t *testing.T) {
 		})
 	}
 }
+
+// historyContains reports whether any stored message contains text.
+func historyContains(history []ChatMessage, text string) bool {
+	for _, msg := range history {
+		if strings.Contains(msg.Content, text) {
+			return true
+		}
+	}
+	return false
+}
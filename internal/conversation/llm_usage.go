@@ -0,0 +1,38 @@
+package conversation
+
+import (
+	"context"
+	"time"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/costs"
+	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
+)
+
+// LLMUsageRecorder persists per-completion token usage for cost reporting.
+type LLMUsageRecorder interface {
+	RecordLLMUsage(ctx context.Context, u costs.LLMUsage) error
+}
+
+// recordLLMUsage attributes a completion's tokens to the conversation on ctx.
+// Completions outside a conversation (no org on ctx) aren't billed to anyone
+// and are skipped. Failures are logged, never returned.
+func (s *LLMService) recordLLMUsage(ctx context.Context, model string, usage TokenUsage) {
+	if s.usage == nil {
+		return
+	}
+	fields := logging.FieldsFromContext(ctx)
+	if fields.OrgID == "" {
+		return
+	}
+	err := s.usage.RecordLLMUsage(ctx, costs.LLMUsage{
+		OrgID:          fields.OrgID,
+		ConversationID: fields.ConversationID,
+		Model:          model,
+		InputTokens:    int(usage.InputTokens),
+		OutputTokens:   int(usage.OutputTokens),
+		At:             time.Now(),
+	})
+	if err != nil {
+		s.log(ctx).Warn("failed to record llm usage", "error", err, "model", model)
+	}
+}
@@ -0,0 +1,54 @@
+package conversation
+
+import (
+	"context"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/costs"
+	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
+)
+
+type recordingUsage struct {
+	recorded []costs.LLMUsage
+}
+
+func (r *recordingUsage) RecordLLMUsage(ctx context.Context, u costs.LLMUsage) error {
+	r.recorded = append(r.recorded, u)
+	return nil
+}
+
+func TestGenerateResponseRecordsUsageAgainstConversation(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	llm := &stubLLMClient{response: LLMResponse{Text: "Hi there!", Usage: TokenUsage{InputTokens: 1200, OutputTokens: 80, TotalTokens: 1280}}}
+	usage := &recordingUsage{}
+	service := NewLLMService(llm, client, nil, "anthropic.claude-3-haiku-20240307-v1:0", logging.Default(),
+		WithLLMUsageRecorder(usage))
+	history := []ChatMessage{{Role: ChatRoleUser, Content: "hello"}}
+
+	// No org on the context: nobody to bill.
+	if _, err := service.generateResponse(context.Background(), history); err != nil {
+		t.Fatalf("generateResponse: %v", err)
+	}
+	if len(usage.recorded) != 0 {
+		t.Fatalf("expected unattributed completions to be skipped, got %+v", usage.recorded)
+	}
+
+	ctx := logging.WithFields(context.Background(), logging.Fields{OrgID: "org-1", ConversationID: "sms:org-1:15550001111"})
+	if _, err := service.generateResponse(ctx, history); err != nil {
+		t.Fatalf("generateResponse: %v", err)
+	}
+	if len(usage.recorded) != 1 {
+		t.Fatalf("recorded %d usages, want 1", len(usage.recorded))
+	}
+	got := usage.recorded[0]
+	if got.OrgID != "org-1" || got.ConversationID != "sms:org-1:15550001111" || got.Model != "anthropic.claude-3-haiku-20240307-v1:0" {
+		t.Errorf("attribution = %+v", got)
+	}
+	if got.InputTokens != 1200 || got.OutputTokens != 80 || got.At.IsZero() {
+		t.Errorf("usage = %+v", got)
+	}
+}
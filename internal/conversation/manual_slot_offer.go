@@ -0,0 +1,152 @@
+package conversation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/clinic"
+	"github.com/wolfman30/medspa-ai-platform/internal/messaging/templates"
+	"github.com/wolfman30/medspa-ai-platform/internal/tenancy"
+)
+
+// ManualSlotOfferRequest is a time clinic staff agreed to fit a patient in
+// at, whether or not the booking platform shows it as open. The worker saves
+// it as the conversation's only presented slot and texts the patient.
+type ManualSlotOfferRequest struct {
+	OrgID          string     `json:"org_id"`
+	LeadID         string     `json:"lead_id,omitempty"`
+	ConversationID string     `json:"conversation_id"`
+	Phone          string     `json:"phone"` // patient phone
+	Start          time.Time  `json:"start"`
+	End            *time.Time `json:"end,omitempty"`
+	Service        string     `json:"service"`
+	Provider       string     `json:"provider,omitempty"` // booking platform provider ID
+	RequestedBy    string     `json:"requested_by,omitempty"`
+}
+
+// ManualSlotOfferer saves a staff-offered slot as the conversation's time
+// selection and returns the offer to text the patient.
+type ManualSlotOfferer interface {
+	OfferManualSlot(ctx context.Context, req ManualSlotOfferRequest) (*TimeSelectionResponse, error)
+}
+
+// ManualOfferLookup is implemented by processors that know whether the slot
+// a patient picked was offered by clinic staff (the LLMService).
+type ManualOfferLookup interface {
+	SelectedManualOffer(ctx context.Context, conversationID string) (providerID string, ok bool)
+}
+
+// OfferManualSlot presents req's slot as the only option, exactly as a
+// search result would be stored, so the patient's "1" goes through the
+// normal selection, deposit and booking path.
+func (s *LLMService) OfferManualSlot(ctx context.Context, req ManualSlotOfferRequest) (*TimeSelectionResponse, error) {
+	if s.clinicStore == nil {
+		return nil, errors.New("conversation: offer slot: clinic store not configured")
+	}
+	cfg, err := s.clinicStore.Get(ctx, req.OrgID)
+	if err != nil {
+		return nil, fmt.Errorf("conversation: offer slot: load clinic config: %w", err)
+	}
+	if cfg == nil {
+		return nil, errors.New("conversation: offer slot: clinic config not found")
+	}
+	if s.leadsRepo != nil && strings.TrimSpace(req.LeadID) != "" {
+		lead, err := s.leadsRepo.GetByID(ctx, tenancy.ForOrg(req.OrgID), req.LeadID)
+		if err != nil {
+			return nil, fmt.Errorf("conversation: offer slot: load lead: %w", err)
+		}
+		if strings.EqualFold(lead.DepositStatus, "paid") {
+			return nil, ErrAvailabilityRefreshNotAllowed
+		}
+	}
+
+	loc := ClinicLocation(cfg.Timezone)
+	start := req.Start.In(loc)
+	slot := PresentedSlot{
+		Index:     1,
+		DateTime:  start,
+		TimeStr:   formatSlotForDisplay(start),
+		Service:   req.Service,
+		Available: true,
+	}
+	if req.End != nil {
+		slot.EndDateTime = req.End.In(loc)
+	} else if d := cfg.DurationForService(req.Service); d > 0 {
+		slot.EndDateTime = start.Add(d)
+	}
+	if req.Provider != "" {
+		slot.ProviderIDs = []string{req.Provider}
+	}
+
+	state := &TimeSelectionState{
+		PresentedSlots: []PresentedSlot{slot},
+		Service:        req.Service,
+		BookingURL:     cfg.BookingURL,
+		PresentedAt:    time.Now(),
+		ManualOffer:    true,
+		ManualProvider: req.Provider,
+	}
+	if err := s.history.SaveTimeSelectionState(ctx, req.ConversationID, state); err != nil {
+		return nil, fmt.Errorf("conversation: offer slot: save time selection: %w", err)
+	}
+	s.log(ctx).Info("manual slot offered",
+		"org_id", req.OrgID,
+		"conversation_id", req.ConversationID,
+		"start", start.Format(time.RFC3339),
+		"service", req.Service,
+		"provider_id", req.Provider,
+		"requested_by", req.RequestedBy,
+	)
+
+	var providerName string
+	if cfg.MoxieConfig != nil {
+		providerName = cfg.MoxieConfig.ProviderNames[req.Provider]
+	}
+	if providerName == "" {
+		providerName = cfg.ProviderNames[req.Provider]
+	}
+	offer := manualSlotOfferMessage(cfg, req.Service, providerName, slot)
+	return &TimeSelectionResponse{
+		Slots:      []PresentedSlot{slot},
+		Service:    req.Service,
+		ExactMatch: true,
+		SMSMessage: withTimezoneNote(offer.Body, []PresentedSlot{slot}, req.Phone),
+		Template:   offer.Ref,
+	}, nil
+}
+
+// SelectedManualOffer reports whether the patient picked a slot clinic staff
+// offered, and the provider staff assigned to it.
+func (s *LLMService) SelectedManualOffer(ctx context.Context, conversationID string) (string, bool) {
+	state, err := s.history.LoadTimeSelectionState(ctx, conversationID)
+	if err != nil {
+		s.log(ctx).Warn("failed to load time selection for manual offer check", "error", err, "conversation_id", conversationID)
+		return "", false
+	}
+	if state == nil || !state.ManualOffer || !state.SlotSelected {
+		return "", false
+	}
+	return state.ManualProvider, true
+}
+
+// manualSlotOfferMessage is the patient-facing text for a staff-offered slot.
+func manualSlotOfferMessage(cfg *clinic.Config, service, providerName string, slot PresentedSlot) templates.Rendered {
+	clinicName := "the clinic"
+	if cfg != nil && strings.TrimSpace(cfg.Name) != "" {
+		clinicName = cfg.Name
+	}
+	what := service
+	if providerName != "" {
+		what = service + " with " + providerName
+	}
+	return cfg.RenderTemplate(templates.SlotsManualOffer, templates.Params{
+		"clinic_name": clinicName,
+		"service":     what,
+		"index":       strconv.Itoa(slot.Index),
+		"time":        slot.TimeStr,
+	})
+}
@@ -0,0 +1,216 @@
+package conversation
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/clinic"
+	"github.com/wolfman30/medspa-ai-platform/internal/events"
+	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
+)
+
+type offerSlotEnqueuer struct {
+	stubEnqueuer
+	offers []ManualSlotOfferRequest
+}
+
+func (s *offerSlotEnqueuer) EnqueueOfferSlot(ctx context.Context, jobID string, req ManualSlotOfferRequest) error {
+	s.offers = append(s.offers, req)
+	return nil
+}
+
+func newOfferSlotHandler(t *testing.T, status string) (*Handler, *offerSlotEnqueuer) {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	enqueuer := &offerSlotEnqueuer{}
+	leadID := uuid.New()
+	h := NewHandler(enqueuer, &stubJobStore{}, nil, nil, logging.Default())
+	h.SetAvailabilityRefresh(stubConversationLookup{conv: &ConversationRecord{
+		ConversationID: refreshConvID,
+		OrgID:          "org-1",
+		LeadID:         &leadID,
+		Status:         status,
+	}}, rdb)
+	return h, enqueuer
+}
+
+func postOfferSlot(h *Handler, body string) *httptest.ResponseRecorder {
+	r := chi.NewRouter()
+	r.Post("/admin/orgs/{orgID}/conversations/{conversationID}/offer-slot", h.OfferSlot)
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, orgRequest("org-1", http.MethodPost, "/admin/orgs/org-1/conversations/sms%3Aorg-1%3A15551234567/offer-slot", strings.NewReader(body)))
+	return rec
+}
+
+func TestOfferSlot_Enqueues(t *testing.T) {
+	h, enqueuer := newOfferSlotHandler(t, StatusAwaitingTimeSelection)
+	start := time.Now().Add(48 * time.Hour).UTC().Truncate(time.Minute)
+
+	rec := postOfferSlot(h, `{"start":"`+start.Format(time.RFC3339)+`","service":" Botox ","provider":"prov-9"}`)
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("expected 202, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if len(enqueuer.offers) != 1 {
+		t.Fatalf("expected 1 offer job, got %d", len(enqueuer.offers))
+	}
+	got := enqueuer.offers[0]
+	if got.OrgID != "org-1" || got.ConversationID != refreshConvID || got.Phone != "15551234567" || got.LeadID == "" {
+		t.Fatalf("unexpected offer request: %+v", got)
+	}
+	if !got.Start.Equal(start) || got.Service != "Botox" || got.Provider != "prov-9" {
+		t.Fatalf("unexpected slot: %+v", got)
+	}
+}
+
+func TestOfferSlot_Validation(t *testing.T) {
+	future := time.Now().Add(48 * time.Hour).UTC().Format(time.RFC3339)
+	past := time.Now().Add(-time.Hour).UTC().Format(time.RFC3339)
+	tests := []struct {
+		name string
+		body string
+	}{
+		{"invalid json", `{`},
+		{"missing service", `{"start":"` + future + `"}`},
+		{"past start", `{"start":"` + past + `","service":"Botox"}`},
+		{"end before start", `{"start":"` + future + `","end":"` + past + `","service":"Botox"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h, enqueuer := newOfferSlotHandler(t, StatusActive)
+			if rec := postOfferSlot(h, tt.body); rec.Code != http.StatusBadRequest {
+				t.Fatalf("expected 400, got %d: %s", rec.Code, rec.Body.String())
+			}
+			if len(enqueuer.offers) != 0 {
+				t.Fatal("offer should not be enqueued")
+			}
+		})
+	}
+}
+
+func TestOfferSlot_RefusesPaidConversation(t *testing.T) {
+	h, enqueuer := newOfferSlotHandler(t, StatusDepositPaid)
+	start := time.Now().Add(48 * time.Hour).UTC().Format(time.RFC3339)
+
+	if rec := postOfferSlot(h, `{"start":"`+start+`","service":"Botox"}`); rec.Code != http.StatusConflict {
+		t.Fatalf("expected 409, got %d", rec.Code)
+	}
+	if len(enqueuer.offers) != 0 {
+		t.Fatal("offer should not be enqueued")
+	}
+}
+
+func TestLLMServiceOfferManualSlot_SavesSingleSlot(t *testing.T) {
+	ts := setupService(t, withClinicConfig("org-1", func(cfg *clinic.Config) {
+		cfg.Name = "Glow Spa"
+		cfg.Timezone = "America/New_York"
+		cfg.BookingURL = "https://app.joinmoxie.com/booking/glow"
+		cfg.ProviderNames = map[string]string{"prov-9": "Dr. Lee"}
+	}))
+	ctx := context.Background()
+	start := time.Date(2030, 3, 12, 19, 30, 0, 0, time.UTC)
+
+	tsr, err := ts.svc.OfferManualSlot(ctx, ManualSlotOfferRequest{
+		OrgID: "org-1", ConversationID: refreshConvID, Phone: "+15551234567",
+		Start: start, Service: "Botox", Provider: "prov-9",
+	})
+	if err != nil {
+		t.Fatalf("OfferManualSlot: %v", err)
+	}
+	if len(tsr.Slots) != 1 || !tsr.Slots[0].DateTime.Equal(start) {
+		t.Fatalf("expected the offered slot only, got %+v", tsr.Slots)
+	}
+	if !strings.Contains(tsr.SMSMessage, "Glow Spa") || !strings.Contains(tsr.SMSMessage, "Dr. Lee") || !strings.Contains(tsr.SMSMessage, "reply 1") {
+		t.Fatalf("unexpected offer text: %q", tsr.SMSMessage)
+	}
+
+	state, err := ts.svc.history.LoadTimeSelectionState(ctx, refreshConvID)
+	if err != nil || state == nil {
+		t.Fatalf("load state: %v", err)
+	}
+	if !state.ManualOffer || state.ManualProvider != "prov-9" || len(state.PresentedSlots) != 1 {
+		t.Fatalf("unexpected saved state: %+v", state)
+	}
+	if got := state.PresentedSlots[0].ProviderIDs; len(got) != 1 || got[0] != "prov-9" {
+		t.Fatalf("expected provider on slot, got %v", got)
+	}
+
+	// Not picked yet, so the payment path should still treat it normally.
+	if _, ok := ts.svc.SelectedManualOffer(ctx, refreshConvID); ok {
+		t.Fatal("unselected offer should not count")
+	}
+	state.SlotSelected = true
+	if err := ts.svc.history.SaveTimeSelectionState(ctx, refreshConvID, state); err != nil {
+		t.Fatalf("save state: %v", err)
+	}
+	if provider, ok := ts.svc.SelectedManualOffer(ctx, refreshConvID); !ok || provider != "prov-9" {
+		t.Fatalf("expected selected offer with provider, got %q %v", provider, ok)
+	}
+}
+
+type manualOfferVerifierService struct {
+	stubSlotVerifierService
+	provider string
+}
+
+func (s *manualOfferVerifierService) SelectedManualOffer(ctx context.Context, conversationID string) (string, bool) {
+	return s.provider, true
+}
+
+type manualOfferBookings struct {
+	stubBookingConfirmer
+	marked []uuid.UUID
+}
+
+func (s *manualOfferBookings) MarkBookingManuallyOffered(ctx context.Context, orgID, leadID uuid.UUID) error {
+	s.marked = append(s.marked, leadID)
+	return nil
+}
+
+func TestWorkerPaymentSucceeded_ManualOfferSkipsSlotConflict(t *testing.T) {
+	scheduled := time.Now().Add(24 * time.Hour).UTC()
+	// The platform reports the slot as taken; staff offered it anyway.
+	service := &manualOfferVerifierService{
+		stubSlotVerifierService: stubSlotVerifierService{result: &PaidSlotVerification{Slot: scheduled, Service: "Botox"}},
+		provider:                "prov-9",
+	}
+	messenger := &stubMessenger{}
+	bookings := &manualOfferBookings{}
+	notifier := &stubSlotConflictNotifier{}
+	worker := NewWorker(service, newScriptedQueue(), &stubJobUpdater{}, messenger, bookings, logging.Default(), WithPaymentNotifier(notifier))
+
+	leadID := uuid.New()
+	event := events.PaymentSucceededV1{
+		EventID:      "evt-1",
+		OrgID:        uuid.NewString(),
+		LeadID:       leadID.String(),
+		LeadPhone:    "+19998887777",
+		FromNumber:   "+15550000000",
+		AmountCents:  5000,
+		ScheduledFor: &scheduled,
+	}
+	if err := worker.handlePaymentEvent(context.Background(), &event); err != nil {
+		t.Fatalf("handlePaymentEvent: %v", err)
+	}
+	if len(service.reqs) != 0 {
+		t.Fatalf("manual offers should not be re-verified, got %+v", service.reqs)
+	}
+	if len(notifier.conflicts) != 0 {
+		t.Fatalf("expected no slot conflict alert, got %+v", notifier.conflicts)
+	}
+	if got := bookings.callCount(); got != 1 {
+		t.Fatalf("expected booking confirmed, got %d confirm calls", got)
+	}
+	if len(bookings.marked) != 1 || bookings.marked[0] != leadID {
+		t.Fatalf("expected booking flagged as manually offered, got %v", bookings.marked)
+	}
+}
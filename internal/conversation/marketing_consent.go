@@ -0,0 +1,196 @@
+package conversation
+
+import (
+	"context"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/clinic"
+	"github.com/wolfman30/medspa-ai-platform/internal/leads"
+	"github.com/wolfman30/medspa-ai-platform/internal/messaging/templates"
+	"github.com/wolfman30/medspa-ai-platform/internal/tenancy"
+)
+
+// marketingConsentReplyWindow is how long after the opt-in ask a reply is
+// read as the answer to it.
+const marketingConsentReplyWindow = 48 * time.Hour
+
+// MarketingConsentReply is a patient's answer to the marketing opt-in ask.
+type MarketingConsentReply int
+
+const (
+	MarketingConsentUnclear MarketingConsentReply = iota
+	MarketingConsentYes
+	MarketingConsentNo
+)
+
+var (
+	shortYesReplies = map[string]bool{
+		"yes": true, "y": true, "yeah": true, "yea": true, "yep": true, "yup": true,
+		"sure": true, "ok": true, "okay": true, "yes please": true, "sure thing": true,
+		"sign me up": true, "opt in": true, "absolutely": true, "of course": true,
+		"correct": true, "thats right": true, "that's right": true, "yes it is": true,
+	}
+	shortNoReplies = map[string]bool{
+		"no": true, "n": true, "nope": true, "nah": true, "no thanks": true,
+		"no thank you": true, "not interested": true, "no way": true, "pass": true,
+		"im good": true, "i'm good": true, "no i'm good": true, "no im good": true,
+	}
+)
+
+// parseShortYesNo reads a brief yes/no answer. Anything longer or mixed is
+// MarketingConsentUnclear.
+func parseShortYesNo(msg string) MarketingConsentReply {
+	cleaned := strings.Map(func(r rune) rune {
+		switch {
+		case unicode.IsLetter(r), unicode.IsSpace(r), r == '\'':
+			return unicode.ToLower(r)
+		case unicode.IsPunct(r):
+			return ' '
+		}
+		return -1 // drop digits, emoji, symbols
+	}, msg)
+	cleaned = strings.Join(strings.Fields(cleaned), " ")
+	switch {
+	case shortYesReplies[cleaned]:
+		return MarketingConsentYes
+	case shortNoReplies[cleaned]:
+		return MarketingConsentNo
+	}
+	return MarketingConsentUnclear
+}
+
+// ParseMarketingConsentReply reads a short yes/no answer to the marketing
+// opt-in ask. Anything longer or mixed is MarketingConsentUnclear.
+func ParseMarketingConsentReply(msg string) MarketingConsentReply {
+	return parseShortYesNo(msg)
+}
+
+// marketingConsentAsk renders the one-time marketing opt-in ask.
+func marketingConsentAsk(cfg *clinic.Config) templates.Rendered {
+	name := "the clinic"
+	if cfg != nil && strings.TrimSpace(cfg.Name) != "" {
+		name = strings.TrimSpace(cfg.Name)
+	}
+	return cfg.RenderTemplate(templates.ComplianceMarketingConsentAsk, templates.Params{"clinic_name": name})
+}
+
+// recordTransactionalConsent notes the lead's first inbound SMS, which is
+// consent to transactional replies.
+func (w *Worker) recordTransactionalConsent(ctx context.Context, msg MessageRequest) {
+	if w == nil || msg.Channel != ChannelSMS || strings.TrimSpace(msg.LeadID) == "" {
+		return
+	}
+	consent, ok := w.leadsRepo.(leads.ConsentRepository)
+	if !ok {
+		return
+	}
+	if err := consent.RecordTransactionalConsent(ctx, msg.LeadID, time.Now()); err != nil {
+		w.log(ctx).Warn("failed to record transactional consent", "error", err, "lead_id", msg.LeadID)
+	}
+}
+
+// askMarketingConsent sends the one-time marketing opt-in ask after a
+// confirmed booking, when the clinic enables it. The lead is claimed first,
+// so the ask is never repeated.
+func (w *Worker) askMarketingConsent(ctx context.Context, cfg *clinic.Config, reply OutboundReply) {
+	if w == nil || cfg == nil || !cfg.FlagEnabled(clinic.FlagMarketingConsentPrompt) || w.messenger == nil {
+		return
+	}
+	if strings.TrimSpace(reply.LeadID) == "" || strings.TrimSpace(reply.To) == "" {
+		return
+	}
+	consent, ok := w.leadsRepo.(leads.ConsentRepository)
+	if !ok {
+		return
+	}
+	if w.isOptedOut(ctx, reply.OrgID, reply.To) {
+		return
+	}
+	claimed, err := consent.MarkMarketingConsentAsked(ctx, reply.LeadID, time.Now())
+	if err != nil {
+		w.log(ctx).Warn("failed to claim marketing consent ask", "error", err, "lead_id", reply.LeadID)
+		return
+	}
+	if !claimed {
+		return
+	}
+
+	ask := marketingConsentAsk(cfg)
+	reply.Body = ask.Body
+	reply.Metadata = ask.Stamp(reply.Metadata)
+	if err := w.messenger.SendReply(ctx, reply); err != nil {
+		w.log(ctx).Error("failed to send marketing consent ask", "error", err, "lead_id", reply.LeadID)
+		return
+	}
+	w.appendTranscript(context.WithoutCancel(ctx), reply.ConversationID, SMSTranscriptMessage{
+		Role:      "assistant",
+		From:      reply.From,
+		To:        reply.To,
+		Body:      reply.Body,
+		Kind:      "marketing_consent_ask",
+		Timestamp: time.Now(),
+		Metadata:  ask.Stamp(nil),
+	})
+}
+
+// handleMarketingConsentReply records the answer to a pending marketing
+// opt-in ask. A clear yes/no gets a canned acknowledgement; anything else is
+// stored as no consent, closing the ask, and goes on to the LLM.
+func (s *LLMService) handleMarketingConsentReply(ctx context.Context, pc *processContext) *Response {
+	if pc.cfg == nil || !pc.cfg.FlagEnabled(clinic.FlagMarketingConsentPrompt) || s.leadsRepo == nil || pc.req.LeadID == "" || pc.req.Channel != ChannelSMS {
+		return nil
+	}
+	consent, ok := s.leadsRepo.(leads.ConsentRepository)
+	if !ok {
+		return nil
+	}
+	lead, err := s.leadsRepo.GetByID(ctx, tenancy.ForOrg(pc.req.OrgID), pc.req.LeadID)
+	if err != nil || lead == nil {
+		return nil
+	}
+	if lead.MarketingConsentAskedAt == nil || lead.MarketingConsentAt != nil ||
+		time.Since(*lead.MarketingConsentAskedAt) > marketingConsentReplyWindow {
+		return nil
+	}
+
+	// The ask was sent by the worker outside the LLM history; add it so the
+	// reply reads in context.
+	if n := len(pc.history); n > 0 {
+		user := pc.history[n-1]
+		pc.history = append(pc.history[:n-1],
+			ChatMessage{Role: ChatRoleAssistant, Content: marketingConsentAsk(pc.cfg).Body},
+			user,
+		)
+	}
+
+	answer := ParseMarketingConsentReply(pc.rawMessage)
+	source := leads.ConsentSourceSMSReply
+	if answer == MarketingConsentUnclear {
+		source = leads.ConsentSourceSMSUnclear
+	}
+	if err := consent.SetMarketingConsent(ctx, lead.ID, answer == MarketingConsentYes, source, time.Now()); err != nil {
+		s.log(ctx).Warn("failed to store marketing consent", "error", err, "lead_id", lead.ID)
+		return nil
+	}
+	s.log(ctx).Info("marketing consent recorded",
+		"lead_id", lead.ID,
+		"consent", answer == MarketingConsentYes,
+		"source", source,
+	)
+
+	var ack templates.Rendered
+	var reason string
+	switch answer {
+	case MarketingConsentYes:
+		ack, reason = pc.cfg.RenderTemplate(templates.ComplianceMarketingConsentYes, nil), "marketing consent yes"
+	case MarketingConsentNo:
+		ack, reason = pc.cfg.RenderTemplate(templates.ComplianceMarketingConsentNo, nil), "marketing consent no"
+	default:
+		return nil
+	}
+	resp := s.saveAndReturn(ctx, pc, ack.Body, reason)
+	resp.Template = ack.Ref
+	return resp
+}
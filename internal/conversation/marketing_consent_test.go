@@ -0,0 +1,135 @@
+package conversation
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	miniredis "github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/wolfman30/medspa-ai-platform/internal/clinic"
+	"github.com/wolfman30/medspa-ai-platform/internal/leads"
+	"github.com/wolfman30/medspa-ai-platform/internal/messaging/templates"
+	"github.com/wolfman30/medspa-ai-platform/internal/tenancy"
+	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
+)
+
+func TestParseMarketingConsentReply(t *testing.T) {
+	cases := map[string]MarketingConsentReply{
+		"YES":                     MarketingConsentYes,
+		"yes please!":             MarketingConsentYes,
+		"Sure 👍":                  MarketingConsentYes,
+		"y":                       MarketingConsentYes,
+		"No":                      MarketingConsentNo,
+		"no thanks.":              MarketingConsentNo,
+		"nope":                    MarketingConsentNo,
+		"I'm good":                MarketingConsentNo,
+		"":                        MarketingConsentUnclear,
+		"maybe":                   MarketingConsentUnclear,
+		"yes but not too many":    MarketingConsentUnclear,
+		"what time is my appt?":   MarketingConsentUnclear,
+		"no wait, yes":            MarketingConsentUnclear,
+		"can I move it to friday": MarketingConsentUnclear,
+	}
+	for msg, want := range cases {
+		if got := ParseMarketingConsentReply(msg); got != want {
+			t.Errorf("ParseMarketingConsentReply(%q) = %v, want %v", msg, got, want)
+		}
+	}
+}
+
+func TestAskMarketingConsent_AsksOnce(t *testing.T) {
+	ctx := context.Background()
+	repo := leads.NewInMemoryRepository()
+	lead, err := repo.Create(ctx, &leads.CreateLeadRequest{OrgID: "org-1", Phone: "+15550000000", Source: "sms"})
+	if err != nil {
+		t.Fatalf("create lead: %v", err)
+	}
+	messenger := &stubMessenger{}
+	w := &Worker{messenger: messenger, leadsRepo: repo, logger: logging.Default()}
+	cfg := &clinic.Config{OrgID: "org-1", Name: "Glow MedSpa", MarketingConsentPrompt: true}
+	reply := OutboundReply{OrgID: "org-1", LeadID: lead.ID, ConversationID: "sms:org-1:15550000000", To: "+15550000000", From: "+15559999999"}
+
+	w.askMarketingConsent(ctx, &clinic.Config{OrgID: "org-1"}, reply)
+	if messenger.count != 0 {
+		t.Fatalf("expected no ask when the clinic has the prompt disabled")
+	}
+
+	w.askMarketingConsent(ctx, cfg, reply)
+	w.askMarketingConsent(ctx, cfg, reply)
+	if messenger.count != 1 {
+		t.Fatalf("expected exactly one ask, got %d", messenger.count)
+	}
+	if messenger.last.Body != marketingConsentAsk(cfg).Body || messenger.last.To != "+15550000000" {
+		t.Fatalf("unexpected ask: %+v", messenger.last)
+	}
+	updated, _ := repo.GetByID(ctx, tenancy.ForOrg("org-1"), lead.ID)
+	if updated.MarketingConsentAskedAt == nil {
+		t.Fatalf("expected ask to be recorded on the lead")
+	}
+}
+
+func TestHandleMarketingConsentReply(t *testing.T) {
+	cases := []struct {
+		name        string
+		message     string
+		wantConsent bool
+		wantSource  string
+		wantReply   string
+	}{
+		{name: "yes", message: "Yes!", wantConsent: true, wantSource: leads.ConsentSourceSMSReply, wantReply: templates.Text(templates.ComplianceMarketingConsentYes)},
+		{name: "no", message: "no thanks", wantSource: leads.ConsentSourceSMSReply, wantReply: templates.Text(templates.ComplianceMarketingConsentNo)},
+		{name: "ambiguous", message: "do I need to bring anything?", wantSource: leads.ConsentSourceSMSUnclear, wantReply: "Just bring your ID."},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			mr := miniredis.RunT(t)
+			ctx := context.Background()
+			client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+			clinicStore := clinic.NewStore(client)
+			cfg := clinic.DefaultConfig("org-1")
+			cfg.MarketingConsentPrompt = true
+			if err := clinicStore.Set(ctx, cfg); err != nil {
+				t.Fatalf("set clinic config: %v", err)
+			}
+			repo := leads.NewInMemoryRepository()
+			lead, err := repo.Create(ctx, &leads.CreateLeadRequest{OrgID: "org-1", Phone: "+15550000000", Source: "sms"})
+			if err != nil {
+				t.Fatalf("create lead: %v", err)
+			}
+
+			mockLLM := &stubLLMClient{responses: []LLMResponse{{Text: "Hi there!"}, {Text: "Just bring your ID."}}}
+			service := NewLLMService(mockLLM, client, nil, "anthropic.claude-3-haiku-20240307-v1:0", logging.Default(), WithClinicStore(clinicStore), WithLeadsRepo(repo))
+			start, err := service.StartConversation(ctx, StartRequest{ConversationID: "conv-consent", LeadID: lead.ID, OrgID: "org-1", Intro: "Hi", Channel: ChannelSMS})
+			if err != nil {
+				t.Fatalf("start failed: %v", err)
+			}
+			if _, err := repo.MarkMarketingConsentAsked(ctx, lead.ID, time.Now().Add(-time.Hour)); err != nil {
+				t.Fatalf("mark asked: %v", err)
+			}
+
+			resp, err := service.ProcessMessage(ctx, MessageRequest{ConversationID: start.ConversationID, LeadID: lead.ID, OrgID: "org-1", Message: tc.message, Channel: ChannelSMS})
+			if err != nil {
+				t.Fatalf("process failed: %v", err)
+			}
+			if resp.Message != tc.wantReply {
+				t.Fatalf("expected reply %q, got %q", tc.wantReply, resp.Message)
+			}
+			updated, _ := repo.GetByID(ctx, tenancy.ForOrg("org-1"), lead.ID)
+			if updated.MarketingConsent != tc.wantConsent || updated.MarketingConsentSource != tc.wantSource || updated.MarketingConsentAt == nil {
+				t.Fatalf("unexpected consent: consent=%v source=%q at=%v", updated.MarketingConsent, updated.MarketingConsentSource, updated.MarketingConsentAt)
+			}
+
+			// The ask is answered; a later "yes" is not read as consent again.
+			if !tc.wantConsent {
+				mockLLM.responses = append(mockLLM.responses, LLMResponse{Text: "Great!"})
+				if _, err := service.ProcessMessage(ctx, MessageRequest{ConversationID: start.ConversationID, LeadID: lead.ID, OrgID: "org-1", Message: "yes", Channel: ChannelSMS}); err != nil {
+					t.Fatalf("process failed: %v", err)
+				}
+				if again, _ := repo.GetByID(ctx, tenancy.ForOrg("org-1"), lead.ID); again.MarketingConsent {
+					t.Fatalf("expected a later reply to leave consent unchanged")
+				}
+			}
+		})
+	}
+}
@@ -2,27 +2,111 @@ package conversation
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/google/uuid"
+
+	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
 )
 
+const defaultOverflowDrainInterval = 500 * time.Millisecond
+
+// OverflowJob is a queue payload that did not fit in the MemoryQueue buffer.
+type OverflowJob struct {
+	ID             string
+	ConversationID string
+	Body           string
+	CreatedAt      time.Time
+}
+
+// OverflowStore persists jobs that overflow the in-memory buffer so they
+// survive until the consumer catches up. ListOverflow must return jobs in
+// insertion order.
+type OverflowStore interface {
+	PutOverflow(ctx context.Context, job OverflowJob) error
+	ListOverflow(ctx context.Context, limit int) ([]OverflowJob, error)
+	DeleteOverflow(ctx context.Context, id string) error
+}
+
 // MemoryQueue is a queueClient backed by an in-memory buffered channel.
+// When an OverflowStore is configured, Send never blocks: jobs that do not
+// fit in the buffer are persisted and re-enqueued by RunOverflowDrainer.
 type MemoryQueue struct {
-	ch chan queueMessage
+	ch            chan queueMessage
+	overflow      OverflowStore
+	drainInterval time.Duration
+	logger        *logging.Logger
+
+	// pending counts overflowed jobs not yet moved back into the buffer.
+	// While it is non-zero new jobs also go to the store to keep FIFO order.
+	pending atomic.Int64
+	// backlog is set while the store may hold jobs that pending does not
+	// count, e.g. rows left behind by a previous process. New jobs go to the
+	// store behind them too.
+	backlog atomic.Bool
+	// closed is set once FlushPending runs; later sends go straight to the
+	// store so nothing is left in a buffer that is about to disappear.
+	closed atomic.Bool
+
+	mu        sync.Mutex
+	delivered map[string]struct{} // drained IDs whose store delete failed
+}
+
+// MemoryQueueOption customizes a MemoryQueue.
+type MemoryQueueOption func(*MemoryQueue)
+
+// WithOverflowStore enables overflow persistence when the buffer is full.
+func WithOverflowStore(store OverflowStore) MemoryQueueOption {
+	return func(q *MemoryQueue) {
+		q.overflow = store
+	}
+}
+
+// WithOverflowDrainInterval sets how often the drainer polls the overflow store.
+func WithOverflowDrainInterval(interval time.Duration) MemoryQueueOption {
+	return func(q *MemoryQueue) {
+		if interval > 0 {
+			q.drainInterval = interval
+		}
+	}
+}
+
+// WithMemoryQueueLogger sets the logger used for overflow diagnostics.
+func WithMemoryQueueLogger(logger *logging.Logger) MemoryQueueOption {
+	return func(q *MemoryQueue) {
+		if logger != nil {
+			q.logger = logger
+		}
+	}
 }
 
 // NewMemoryQueue creates a MemoryQueue with the provided buffer capacity.
-func NewMemoryQueue(buffer int) *MemoryQueue {
+func NewMemoryQueue(buffer int, opts ...MemoryQueueOption) *MemoryQueue {
 	if buffer <= 0 {
 		buffer = 128
 	}
-	return &MemoryQueue{
-		ch: make(chan queueMessage, buffer),
+	q := &MemoryQueue{
+		ch:            make(chan queueMessage, buffer),
+		drainInterval: defaultOverflowDrainInterval,
+		logger:        logging.Default(),
+		delivered:     make(map[string]struct{}),
+	}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(q)
+		}
 	}
+	return q
 }
 
-// Send enqueues a payload or blocks until ctx is done.
+// Send enqueues a payload. Without an overflow store it blocks until there is
+// room or ctx is done; with one it spills to the store instead of blocking.
 func (q *MemoryQueue) Send(ctx context.Context, body string) error {
 	if ctx == nil {
 		ctx = context.Background()
@@ -31,14 +115,209 @@ func (q *MemoryQueue) Send(ctx context.Context, body string) error {
 		ID:            uuid.NewString(),
 		Body:          body,
 		ReceiptHandle: uuid.NewString(),
+		SentAt:        time.Now(),
+	}
+
+	if q.overflow != nil && q.closed.Load() {
+		return q.persist(ctx, msg)
 	}
+	if q.overflow == nil {
+		select {
+		case q.ch <- msg:
+			memoryQueueDepth.Set(float64(len(q.ch)))
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	// Jobs already in the store, spilled or restored after a restart, go
+	// first.
+	if q.pending.Load() == 0 && !q.backlog.Load() {
+		select {
+		case q.ch <- msg:
+			memoryQueueDepth.Set(float64(len(q.ch)))
+			return nil
+		default:
+		}
+	}
+	return q.spill(ctx, msg)
+}
 
-	select {
-	case q.ch <- msg:
-		return nil
-	case <-ctx.Done():
-		return ctx.Err()
+// spill persists msg to the overflow store.
+func (q *MemoryQueue) spill(ctx context.Context, msg queueMessage) error {
+	job := OverflowJob{
+		ID:             msg.ID,
+		ConversationID: payloadConversationID(msg.Body),
+		Body:           msg.Body,
+		CreatedAt:      time.Now().UTC(),
+	}
+	if err := q.overflow.PutOverflow(ctx, job); err != nil {
+		return err
 	}
+	memoryQueueOverflowTotal.Inc()
+	memoryQueueOverflowPending.Set(float64(q.pending.Add(1)))
+	q.logger.Warn("memory queue full: job persisted to overflow store",
+		"overflow_id", job.ID,
+		"conversation_id", job.ConversationID,
+		"buffer", cap(q.ch),
+	)
+	return nil
+}
+
+// Persist stores body in the overflow store as queued, for a later process
+// to pick up. It fails without a store.
+func (q *MemoryQueue) Persist(ctx context.Context, body string) error {
+	return q.persist(ctx, queueMessage{ID: uuid.NewString(), Body: body})
+}
+
+// FlushPending moves every buffered job that no worker has started into the
+// overflow store as queued, so a shutdown doesn't lose them. Sends after a
+// flush are persisted directly. It returns how many jobs were flushed.
+func (q *MemoryQueue) FlushPending(ctx context.Context) (int, error) {
+	if q.overflow == nil {
+		if n := len(q.ch); n > 0 {
+			return 0, fmt.Errorf("conversation: %d buffered jobs lost: memory queue has no overflow store", n)
+		}
+		return 0, nil
+	}
+	q.closed.Store(true)
+	flushed := 0
+	for {
+		select {
+		case msg := <-q.ch:
+			if err := q.persist(ctx, msg); err != nil {
+				return flushed, err
+			}
+			flushed++
+		default:
+			memoryQueueDepth.Set(0)
+			return flushed, nil
+		}
+	}
+}
+
+func (q *MemoryQueue) persist(ctx context.Context, msg queueMessage) error {
+	if q.overflow == nil {
+		return errors.New("conversation: memory queue has no overflow store")
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return q.overflow.PutOverflow(ctx, OverflowJob{
+		ID:             msg.ID,
+		ConversationID: payloadConversationID(msg.Body),
+		Body:           msg.Body,
+		CreatedAt:      time.Now().UTC(),
+	})
+}
+
+// RunOverflowDrainer moves overflowed jobs back into the buffer as capacity
+// frees up. It blocks until ctx is done and is a no-op without a store.
+func (q *MemoryQueue) RunOverflowDrainer(ctx context.Context) {
+	if q.overflow == nil {
+		return
+	}
+	ticker := time.NewTicker(q.drainInterval)
+	defer ticker.Stop()
+
+	// Always check at startup to pick up jobs left by a previous process.
+	q.backlog.Store(true)
+	q.drainOverflow(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if q.pending.Load() > 0 || q.backlog.Load() {
+				q.drainOverflow(ctx)
+			}
+		}
+	}
+}
+
+// drainOverflow re-enqueues as many stored jobs as the buffer can hold.
+func (q *MemoryQueue) drainOverflow(ctx context.Context) {
+	free := cap(q.ch) - len(q.ch)
+	if free <= 0 {
+		return
+	}
+	snapshot := q.pending.Load()
+	jobs, err := q.overflow.ListOverflow(ctx, free)
+	if err != nil {
+		q.logger.Warn("memory queue overflow drain: list failed", "error", err)
+		return
+	}
+	if len(jobs) < free {
+		q.backlog.Store(false)
+	}
+	if len(jobs) == 0 {
+		// Only reset if no job was spilled while we were listing.
+		if q.pending.CompareAndSwap(snapshot, 0) {
+			memoryQueueOverflowPending.Set(0)
+		}
+		return
+	}
+
+	for _, job := range jobs {
+		if q.alreadyDelivered(job.ID) {
+			q.retryDelete(ctx, job.ID)
+			continue
+		}
+		msg := queueMessage{
+			ID:            job.ID,
+			Body:          job.Body,
+			ReceiptHandle: uuid.NewString(),
+			SentAt:        job.CreatedAt,
+		}
+		select {
+		case q.ch <- msg:
+		default:
+			q.backlog.Store(true)
+			return
+		}
+		memoryQueueDepth.Set(float64(len(q.ch)))
+		memoryQueueOverflowDrainedTotal.Inc()
+		q.setPending(q.pending.Add(-1))
+
+		if err := q.overflow.DeleteOverflow(ctx, job.ID); err != nil {
+			// The job is already in the buffer; remember it so it is not
+			// delivered a second time on the next pass.
+			q.mu.Lock()
+			q.delivered[job.ID] = struct{}{}
+			q.mu.Unlock()
+			q.logger.Warn("memory queue overflow drain: delete failed", "error", err, "overflow_id", job.ID)
+		}
+	}
+}
+
+func (q *MemoryQueue) alreadyDelivered(id string) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	_, ok := q.delivered[id]
+	return ok
+}
+
+func (q *MemoryQueue) retryDelete(ctx context.Context, id string) {
+	if err := q.overflow.DeleteOverflow(ctx, id); err != nil {
+		return
+	}
+	q.mu.Lock()
+	delete(q.delivered, id)
+	q.mu.Unlock()
+}
+
+func (q *MemoryQueue) setPending(n int64) {
+	if n <= 0 {
+		n = 0
+		q.pending.Store(0)
+	}
+	memoryQueueOverflowPending.Set(float64(n))
+}
+
+// Depth returns the number of jobs currently buffered in memory.
+func (q *MemoryQueue) Depth() int {
+	return len(q.ch)
 }
 
 // Receive blocks until a message is available, ctx is done, or waitSeconds elapses.
@@ -88,6 +367,7 @@ func (q *MemoryQueue) collect(ctx context.Context, first queueMessage, max int)
 	}
 	messages := make([]queueMessage, 0, max)
 	messages = append(messages, first)
+	defer func() { memoryQueueDepth.Set(float64(len(q.ch))) }()
 
 	for len(messages) < max {
 		select {
@@ -101,3 +381,19 @@ func (q *MemoryQueue) collect(ctx context.Context, first queueMessage, max int)
 	}
 	return messages
 }
+
+// payloadConversationID extracts the conversation ID from an encoded payload
+// for overflow bookkeeping. Unknown payloads yield an empty string.
+func payloadConversationID(body string) string {
+	var payload queuePayload
+	if err := json.Unmarshal([]byte(body), &payload); err != nil {
+		return ""
+	}
+	switch payload.Kind {
+	case jobTypeStart:
+		return strings.TrimSpace(payload.Start.ConversationID)
+	case jobTypeMessage:
+		return strings.TrimSpace(payload.Message.ConversationID)
+	}
+	return ""
+}
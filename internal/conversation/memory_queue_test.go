@@ -0,0 +1,237 @@
+package conversation
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+type fakeOverflowStore struct {
+	mu        sync.Mutex
+	jobs      []OverflowJob
+	deleteErr error
+}
+
+func (s *fakeOverflowStore) PutOverflow(_ context.Context, job OverflowJob) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.jobs = append(s.jobs, job)
+	return nil
+}
+
+func (s *fakeOverflowStore) ListOverflow(_ context.Context, limit int) ([]OverflowJob, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if limit > len(s.jobs) {
+		limit = len(s.jobs)
+	}
+	return append([]OverflowJob(nil), s.jobs[:limit]...), nil
+}
+
+func (s *fakeOverflowStore) DeleteOverflow(_ context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.deleteErr != nil {
+		return s.deleteErr
+	}
+	for i, job := range s.jobs {
+		if job.ID == id {
+			s.jobs = append(s.jobs[:i], s.jobs[i+1:]...)
+			return nil
+		}
+	}
+	return nil
+}
+
+func (s *fakeOverflowStore) len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.jobs)
+}
+
+func messageBody(t *testing.T, conversationID, text string) string {
+	t.Helper()
+	_, body, err := encodePayload(queuePayload{
+		Kind:    jobTypeMessage,
+		Message: MessageRequest{ConversationID: conversationID, Message: text},
+	})
+	if err != nil {
+		t.Fatalf("encode payload: %v", err)
+	}
+	return body
+}
+
+func TestMemoryQueue_OverflowPersistsAndDrainsInOrder(t *testing.T) {
+	store := &fakeOverflowStore{}
+	q := NewMemoryQueue(2, WithOverflowStore(store), WithOverflowDrainInterval(5*time.Millisecond))
+	ctx := context.Background()
+
+	const total = 7
+	start := time.Now()
+	for i := 0; i < total; i++ {
+		if err := q.Send(ctx, messageBody(t, "conv-1", fmt.Sprintf("msg-%d", i))); err != nil {
+			t.Fatalf("send %d: %v", i, err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("Send blocked with a stalled consumer: %v", elapsed)
+	}
+	if got := store.len(); got != total-2 {
+		t.Fatalf("expected %d overflowed jobs persisted, got %d", total-2, got)
+	}
+	if store.jobs[0].ConversationID != "conv-1" {
+		t.Fatalf("expected conversation id on overflow job, got %q", store.jobs[0].ConversationID)
+	}
+
+	drainCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go q.RunOverflowDrainer(drainCtx)
+
+	var got []string
+	deadline := time.After(5 * time.Second)
+	for len(got) < total {
+		select {
+		case <-deadline:
+			t.Fatalf("timed out; received %v", got)
+		default:
+		}
+		msgs, err := q.Receive(ctx, 1, 1)
+		if err != nil {
+			t.Fatalf("receive: %v", err)
+		}
+		for _, msg := range msgs {
+			var payload queuePayload
+			if err := json.Unmarshal([]byte(msg.Body), &payload); err != nil {
+				t.Fatalf("decode: %v", err)
+			}
+			got = append(got, payload.Message.Message)
+		}
+	}
+
+	for i, text := range got {
+		if want := fmt.Sprintf("msg-%d", i); text != want {
+			t.Fatalf("out of order at %d: got %q want %q (all: %v)", i, text, want, got)
+		}
+	}
+	if store.len() != 0 {
+		t.Fatalf("expected overflow store drained, %d remaining", store.len())
+	}
+
+	// Nothing should be delivered twice.
+	time.Sleep(20 * time.Millisecond)
+	if q.Depth() != 0 {
+		t.Fatalf("unexpected duplicate delivery: depth=%d", q.Depth())
+	}
+	msgs, err := q.Receive(ctx, 10, 1)
+	if err == nil && len(msgs) > 0 {
+		t.Fatalf("unexpected duplicate delivery: %d messages", len(msgs))
+	}
+}
+
+func TestMemoryQueue_NewJobsFollowOverflowWhilePending(t *testing.T) {
+	store := &fakeOverflowStore{}
+	q := NewMemoryQueue(1, WithOverflowStore(store))
+	ctx := context.Background()
+
+	_ = q.Send(ctx, messageBody(t, "conv-1", "a"))
+	_ = q.Send(ctx, messageBody(t, "conv-1", "b"))
+
+	// Free the buffer; a new job must not jump ahead of the overflowed one.
+	if _, err := q.Receive(ctx, 1, 1); err != nil {
+		t.Fatalf("receive: %v", err)
+	}
+	_ = q.Send(ctx, messageBody(t, "conv-1", "c"))
+
+	if q.Depth() != 0 {
+		t.Fatalf("expected new job to queue behind overflow, depth=%d", q.Depth())
+	}
+	if store.len() != 2 {
+		t.Fatalf("expected 2 overflowed jobs, got %d", store.len())
+	}
+}
+
+func TestMemoryQueue_NewJobsFollowRestoredBacklog(t *testing.T) {
+	store := &fakeOverflowStore{jobs: []OverflowJob{
+		{ID: "old-1", Body: messageBody(t, "conv-1", "old-1")},
+		{ID: "old-2", Body: messageBody(t, "conv-1", "old-2")},
+	}}
+	q := NewMemoryQueue(1, WithOverflowStore(store), WithOverflowDrainInterval(time.Hour))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// The startup drain restores one job left by a previous process.
+	go q.RunOverflowDrainer(ctx)
+	waitFor(func() bool { return q.Depth() == 1 }, time.Second, t)
+
+	if _, err := q.Receive(ctx, 1, 1); err != nil {
+		t.Fatalf("receive: %v", err)
+	}
+	_ = q.Send(ctx, messageBody(t, "conv-1", "new"))
+	if q.Depth() != 0 {
+		t.Fatalf("expected new job to queue behind the restored backlog, depth=%d", q.Depth())
+	}
+
+	var got []string
+	for i := 0; i < 2; i++ {
+		q.drainOverflow(ctx)
+		msgs, err := q.Receive(ctx, 1, 1)
+		if err != nil || len(msgs) != 1 {
+			t.Fatalf("receive %d: %v, %d messages", i, err, len(msgs))
+		}
+		var payload queuePayload
+		if err := json.Unmarshal([]byte(msgs[0].Body), &payload); err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+		got = append(got, payload.Message.Message)
+	}
+	if got[0] != "old-2" || got[1] != "new" {
+		t.Fatalf("expected [old-2 new], got %v", got)
+	}
+}
+
+func TestMemoryQueue_DeleteFailureDoesNotRedeliver(t *testing.T) {
+	store := &fakeOverflowStore{}
+	q := NewMemoryQueue(1, WithOverflowStore(store))
+	ctx := context.Background()
+
+	_ = q.Send(ctx, messageBody(t, "conv-1", "a"))
+	_ = q.Send(ctx, messageBody(t, "conv-1", "b"))
+	if _, err := q.Receive(ctx, 1, 1); err != nil {
+		t.Fatalf("receive: %v", err)
+	}
+
+	store.deleteErr = errors.New("db down")
+	q.drainOverflow(ctx)
+	if q.Depth() != 1 {
+		t.Fatalf("expected drained job in buffer, depth=%d", q.Depth())
+	}
+	if _, err := q.Receive(ctx, 1, 1); err != nil {
+		t.Fatalf("receive: %v", err)
+	}
+
+	q.drainOverflow(ctx)
+	if q.Depth() != 0 {
+		t.Fatalf("job redelivered after failed delete, depth=%d", q.Depth())
+	}
+
+	store.deleteErr = nil
+	q.drainOverflow(ctx)
+	if store.len() != 0 {
+		t.Fatalf("expected stale overflow row cleaned up, %d remaining", store.len())
+	}
+}
+
+func TestMemoryQueue_SendBlocksWithoutOverflowStore(t *testing.T) {
+	q := NewMemoryQueue(1)
+	_ = q.Send(context.Background(), "first")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := q.Send(ctx, "second"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+}
@@ -0,0 +1,274 @@
+package conversation
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/clinic"
+)
+
+// Escalation triggers: reasons a turn is hard enough for the stronger model.
+const (
+	ModelTriggerLongMessage   = "long_message"
+	ModelTriggerMultiService  = "multi_service"
+	ModelTriggerFrustration   = "frustration"
+	ModelTriggerClarification = "repeated_clarification"
+	ModelTriggerPolicyMedical = "policy_medical"
+)
+
+// Model routes recorded on metrics, conversation events and the audit trail.
+const (
+	// ModelRouteDefault means no trigger fired and the default model answered.
+	ModelRouteDefault = "default"
+	// ModelRouteEscalated means the escalation model answered.
+	ModelRouteEscalated = "escalated"
+	// ModelRouteBudgetExhausted means a trigger fired but the org had used
+	// its monthly escalations, so the default model answered.
+	ModelRouteBudgetExhausted = "budget_exhausted"
+)
+
+const (
+	// escalationLongMessageChars is where a patient text is long enough that
+	// it usually packs several questions or details into one turn.
+	escalationLongMessageChars = 280
+	// escalationClarificationWindow is how many recent assistant turns are
+	// checked for repeated clarifying questions.
+	escalationClarificationWindow = 3
+	escalationBudgetTTL           = 32 * 24 * time.Hour
+)
+
+// clarificationPattern matches the assistant asking the patient to clarify
+// or repeat something.
+var clarificationPattern = regexp.MustCompile(`(?i)(` +
+	`\b(could|can)\s+you\s+(clarify|confirm|explain)` +
+	`|\b(did|do)\s+you\s+mean\b` +
+	`|\bjust\s+to\s+(clarify|confirm)\b` +
+	`|\bto\s+make\s+sure\s+i\s+(understand|have\s+(this|that)\s+right)` +
+	`|\bsorry,?\s+i\s+(didn'?t|did\s+not|don'?t|do\s+not)\s+(understand|follow|catch)` +
+	`|\bnot\s+sure\s+(i|what\s+you)\s+(understand|follow|mean)` +
+	`|\bwhich\s+(one|service|treatment|day|time)\s+(did|do|would)\s+you` +
+	`)`)
+
+// policyTopicPattern matches questions about clinic policies, which need
+// careful, accurate answers.
+var policyTopicPattern = regexp.MustCompile(`(?i)\b(` +
+	`polic(y|ies)|refunds?|refundable|non-?refundable|no[- ]?shows?` +
+	`|cancell?(ation|ing)?\s+fee|late\s+fee|reschedul\w*\s+fee` +
+	`|financing|payment\s+plans?|membership\s+terms` +
+	`)\b`)
+
+// EscalationTriggers returns the reasons a turn should go to the escalation
+// model: a long message, a request spanning several services, frustration,
+// the assistant repeatedly asking for clarification, or a policy or
+// medical-adjacent question. history is the conversation so far, oldest
+// first; the current message may already be its last entry.
+func EscalationTriggers(message string, history []ChatMessage, cfg *clinic.Config) []string {
+	message = strings.TrimSpace(message)
+	if message == "" {
+		return nil
+	}
+	var triggers []string
+	if utf8.RuneCountInString(message) >= escalationLongMessageChars {
+		triggers = append(triggers, ModelTriggerLongMessage)
+	}
+	if len(mentionedServices(message, cfg)) >= 2 {
+		triggers = append(triggers, ModelTriggerMultiService)
+	}
+	if ScoreFrustration(message, transcriptFromHistory(history)).Score > 0 {
+		triggers = append(triggers, ModelTriggerFrustration)
+	}
+	if repeatedClarification(history) {
+		triggers = append(triggers, ModelTriggerClarification)
+	}
+	if policyTopicPattern.MatchString(message) || strongMedicalCueRE.MatchString(message) ||
+		medicalSpecificContextRE.MatchString(message) || detectPHI(message) {
+		triggers = append(triggers, ModelTriggerPolicyMedical)
+	}
+	return triggers
+}
+
+// mentionedServices returns the distinct services named in a message,
+// matching clinic aliases and service names before the universal patterns
+// and longest patterns first so "lip filler" isn't also counted as "filler".
+func mentionedServices(message string, cfg *clinic.Config) []string {
+	type candidate struct{ pattern, name string }
+	var candidates []candidate
+	if cfg != nil {
+		for alias, service := range cfg.ServiceAliases {
+			candidates = append(candidates, candidate{strings.ToLower(alias), strings.ToLower(service)})
+		}
+		for _, service := range cfg.Services {
+			candidates = append(candidates, candidate{strings.ToLower(service), strings.ToLower(service)})
+		}
+	}
+	for _, p := range universalServicePatterns {
+		candidates = append(candidates, candidate{p.pattern, p.name})
+	}
+	slices.SortStableFunc(candidates, func(a, b candidate) int { return len(b.pattern) - len(a.pattern) })
+
+	text := strings.ToLower(message)
+	var names []string
+	for _, c := range candidates {
+		if strings.TrimSpace(c.pattern) == "" || !strings.Contains(text, c.pattern) {
+			continue
+		}
+		text = strings.ReplaceAll(text, c.pattern, strings.Repeat(" ", len(c.pattern)))
+		if !slices.Contains(names, c.name) {
+			names = append(names, c.name)
+		}
+	}
+	return names
+}
+
+// repeatedClarification reports whether the assistant asked for
+// clarification in at least two of its last few turns.
+func repeatedClarification(history []ChatMessage) bool {
+	seen, asked := 0, 0
+	for i := len(history) - 1; i >= 0 && seen < escalationClarificationWindow; i-- {
+		if history[i].Role != ChatRoleAssistant {
+			continue
+		}
+		seen++
+		if clarificationPattern.MatchString(history[i].Content) {
+			asked++
+		}
+	}
+	return asked >= 2
+}
+
+// transcriptFromHistory adapts LLM history for the frustration scorer,
+// dropping system context.
+func transcriptFromHistory(history []ChatMessage) []SMSTranscriptMessage {
+	transcript := make([]SMSTranscriptMessage, 0, len(history))
+	for _, msg := range history {
+		if msg.Role == ChatRoleUser || msg.Role == ChatRoleAssistant {
+			transcript = append(transcript, SMSTranscriptMessage{Role: msg.Role, Body: msg.Content, Direction: msg.Direction, AuthorType: msg.AuthorType})
+		}
+	}
+	return transcript
+}
+
+// ModelDecision is the model chosen for one LLM turn.
+type ModelDecision struct {
+	Model    string
+	Route    string
+	Triggers []string
+	// Latency is the completion latency, filled in once the turn has run.
+	Latency time.Duration
+}
+
+// ModelRouter sends hard turns to a stronger escalation model, within a
+// per-org monthly escalation budget counted in Redis.
+type ModelRouter struct {
+	client          redis.Cmdable
+	escalationModel string
+	monthlyBudget   int64
+	now             func() time.Time
+}
+
+// NewModelRouter returns a router that escalates to escalationModel up to
+// monthlyBudget times per org per calendar month (UTC); a budget of 0 means
+// no cap. It returns nil (routing disabled) without Redis or an escalation
+// model.
+func NewModelRouter(client *redis.Client, escalationModel string, monthlyBudget int) *ModelRouter {
+	escalationModel = strings.TrimSpace(escalationModel)
+	if client == nil || escalationModel == "" {
+		return nil
+	}
+	return &ModelRouter{
+		client:          client,
+		escalationModel: escalationModel,
+		monthlyBudget:   int64(max(monthlyBudget, 0)),
+		now:             time.Now,
+	}
+}
+
+// Route picks the model for a turn with the given triggers. Without
+// triggers, or once the org's budget is spent, the default model is used.
+func (r *ModelRouter) Route(ctx context.Context, orgID, defaultModel string, triggers []string) (*ModelDecision, error) {
+	decision := &ModelDecision{Model: defaultModel, Route: ModelRouteDefault, Triggers: triggers}
+	if len(triggers) == 0 || r.escalationModel == defaultModel {
+		return decision, nil
+	}
+	if r.monthlyBudget > 0 {
+		key := escalationBudgetKey(orgID, r.now())
+		used, err := r.client.Incr(ctx, key).Result()
+		if err != nil {
+			return decision, fmt.Errorf("conversation: count model escalation: %w", err)
+		}
+		if used == 1 {
+			r.client.Expire(ctx, key, escalationBudgetTTL)
+		}
+		if used > r.monthlyBudget {
+			// Keep the counter at the budget so it reads as usage.
+			r.client.Decr(ctx, key)
+			decision.Route = ModelRouteBudgetExhausted
+			return decision, nil
+		}
+	}
+	decision.Model = r.escalationModel
+	decision.Route = ModelRouteEscalated
+	return decision, nil
+}
+
+// EscalationsUsed returns how many escalations an org has used this month.
+func (r *ModelRouter) EscalationsUsed(ctx context.Context, orgID string) (int64, error) {
+	used, err := r.client.Get(ctx, escalationBudgetKey(orgID, r.now())).Int64()
+	if err == redis.Nil {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("conversation: read model escalations: %w", err)
+	}
+	return used, nil
+}
+
+func escalationBudgetKey(orgID string, now time.Time) string {
+	return fmt.Sprintf("llm:escalations:%s:%s", orgID, now.UTC().Format("2006-01"))
+}
+
+// routeModel picks the model for an SMS turn and stores the decision on the
+// returned context for generateResponse. Voice turns keep the fast voice
+// model. Returns nil when routing is disabled.
+func (s *LLMService) routeModel(ctx context.Context, channel Channel, orgID, message string, history []ChatMessage, cfg *clinic.Config) (context.Context, *ModelDecision) {
+	if s.modelRouter == nil || isVoiceChannel(channel) {
+		return ctx, nil
+	}
+	decision, err := s.modelRouter.Route(ctx, orgID, s.model, EscalationTriggers(message, history, cfg))
+	if err != nil {
+		s.log(ctx).Warn("model routing failed; using default model", "error", err)
+	}
+	return context.WithValue(ctx, ctxKeyModelDecision, decision), decision
+}
+
+// recordModelRoute records a routed turn's model, route and latency on the
+// metrics and conversation events, and on the audit trail when escalation
+// triggers fired.
+func (s *LLMService) recordModelRoute(ctx context.Context, orgID, conversationID, leadID string, decision *ModelDecision) {
+	if decision == nil {
+		return
+	}
+	llmModelRouteTotal.WithLabelValues(decision.Model, decision.Route).Inc()
+	for _, trigger := range decision.Triggers {
+		llmEscalationTriggerTotal.WithLabelValues(trigger, decision.Route).Inc()
+	}
+	s.events.Log(ctx, "llm_model_routed", conversationID, orgID, leadID, map[string]any{
+		"model":      decision.Model,
+		"route":      decision.Route,
+		"triggers":   decision.Triggers,
+		"latency_ms": decision.Latency.Milliseconds(),
+	})
+	if len(decision.Triggers) == 0 || s.audit == nil || strings.TrimSpace(orgID) == "" {
+		return
+	}
+	if err := s.audit.LogModelEscalation(ctx, orgID, conversationID, leadID, decision.Route, decision.Model, decision.Triggers, decision.Latency); err != nil {
+		s.log(ctx).Warn("failed to audit model escalation", "error", err)
+	}
+}
@@ -0,0 +1,147 @@
+package conversation
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/redis/go-redis/v9"
+)
+
+func TestEscalationTriggers(t *testing.T) {
+	clarifying := []ChatMessage{
+		{Role: ChatRoleAssistant, Content: "Sorry, I didn't catch that. Which service did you want?"},
+		{Role: ChatRoleUser, Content: "the face one"},
+		{Role: ChatRoleAssistant, Content: "Just to confirm, did you mean a facial or Botox?"},
+	}
+	cases := []struct {
+		name    string
+		message string
+		history []ChatMessage
+		want    []string
+	}{
+		{"simple booking", "Can I book Botox on Tuesday?", nil, nil},
+		{"short answer", "Sarah Jones", nil, nil},
+		{"long message", strings.Repeat("I have a few things to ask about. ", 10), nil, []string{ModelTriggerLongMessage}},
+		{"multi service", "I'd like Botox and lip filler, maybe a chemical peel too", nil, []string{ModelTriggerMultiService}},
+		{"one service, two aliases", "lip filler, the lip augmentation one", nil, nil},
+		{"frustration", "This is ridiculous", nil, []string{ModelTriggerFrustration}},
+		{"repeated clarification", "a facial", clarifying, []string{ModelTriggerClarification}},
+		{"policy", "What's your cancellation fee if I can't make it?", nil, []string{ModelTriggerPolicyMedical}},
+		{"medical adjacent", "I'm breastfeeding, is that a problem for Botox?", nil, []string{ModelTriggerPolicyMedical}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := EscalationTriggers(tc.message, tc.history, nil); !slices.Equal(got, tc.want) {
+				t.Fatalf("EscalationTriggers(%q) = %v, want %v", tc.message, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestModelRouter_BudgetExhaustedFallsBack(t *testing.T) {
+	mr := miniredis.RunT(t)
+	router := NewModelRouter(redis.NewClient(&redis.Options{Addr: mr.Addr()}), "strong-model", 2)
+	router.now = func() time.Time { return time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC) }
+	ctx := context.Background()
+	triggers := []string{ModelTriggerMultiService}
+
+	var routes []string
+	for range 3 {
+		decision, err := router.Route(ctx, "org-1", "test-model", triggers)
+		if err != nil {
+			t.Fatalf("Route: %v", err)
+		}
+		routes = append(routes, decision.Route+":"+decision.Model)
+	}
+	want := []string{"escalated:strong-model", "escalated:strong-model", "budget_exhausted:test-model"}
+	if !slices.Equal(routes, want) {
+		t.Fatalf("routes = %v, want %v", routes, want)
+	}
+	if used, _ := router.EscalationsUsed(ctx, "org-1"); used != 2 {
+		t.Fatalf("expected the counter to stop at the budget, got %d", used)
+	}
+
+	// Turns without triggers don't spend budget.
+	if decision, _ := router.Route(ctx, "org-1", "test-model", nil); decision.Route != ModelRouteDefault {
+		t.Fatalf("expected the default route without triggers, got %+v", decision)
+	}
+	// Budgets are per org and per month.
+	if decision, _ := router.Route(ctx, "org-2", "test-model", triggers); decision.Route != ModelRouteEscalated {
+		t.Fatalf("expected another org to have its own budget, got %+v", decision)
+	}
+	router.now = func() time.Time { return time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC) }
+	if decision, _ := router.Route(ctx, "org-1", "test-model", triggers); decision.Route != ModelRouteEscalated {
+		t.Fatalf("expected the budget to reset next month, got %+v", decision)
+	}
+}
+
+func TestLLMService_ModelRoutingMetrics(t *testing.T) {
+	ts := setupService(t)
+	ts.svc.modelRouter = NewModelRouter(ts.rdb, "strong-model", 1)
+	const convID = "sms:org-route:15550001111"
+	startConv(t, ts, convID, "org-route", "Hi there")
+
+	routed := func(model, route string) float64 {
+		return testutil.ToFloat64(llmModelRouteTotal.WithLabelValues(model, route))
+	}
+	triggered := func(route string) float64 {
+		return testutil.ToFloat64(llmEscalationTriggerTotal.WithLabelValues(ModelTriggerMultiService, route))
+	}
+	escalated, exhausted, plain := routed("strong-model", ModelRouteEscalated), routed("test-model", ModelRouteBudgetExhausted), routed("test-model", ModelRouteDefault)
+	escalatedTriggers, exhaustedTriggers := triggered(ModelRouteEscalated), triggered(ModelRouteBudgetExhausted)
+	escalatedLatency := latencySamples(t, "strong-model")
+
+	send := func(message string) string {
+		t.Helper()
+		if _, err := ts.svc.ProcessMessage(context.Background(), MessageRequest{
+			ConversationID: convID,
+			OrgID:          "org-route",
+			Message:        message,
+			Channel:        ChannelSMS,
+		}); err != nil {
+			t.Fatalf("ProcessMessage: %v", err)
+		}
+		return ts.llm.lastReq.Model
+	}
+
+	if model := send("I want Botox and lip filler"); model != "strong-model" {
+		t.Fatalf("expected the escalation model, got %q", model)
+	}
+	if model := send("Also a chemical peel and microneedling"); model != "test-model" {
+		t.Fatalf("expected the default model once the budget is spent, got %q", model)
+	}
+	if model := send("Tuesdays work"); model != "test-model" {
+		t.Fatalf("expected the default model without triggers, got %q", model)
+	}
+
+	for name, delta := range map[string]float64{
+		"escalated":                routed("strong-model", ModelRouteEscalated) - escalated,
+		"budget_exhausted":         routed("test-model", ModelRouteBudgetExhausted) - exhausted,
+		"default":                  routed("test-model", ModelRouteDefault) - plain,
+		"escalated trigger":        triggered(ModelRouteEscalated) - escalatedTriggers,
+		"budget_exhausted trigger": triggered(ModelRouteBudgetExhausted) - exhaustedTriggers,
+	} {
+		if delta != 1 {
+			t.Errorf("%s metric delta = %v, want 1", name, delta)
+		}
+	}
+	if got := latencySamples(t, "strong-model") - escalatedLatency; got != 1 {
+		t.Errorf("expected latency to be recorded under the escalation model, got %v samples", got)
+	}
+}
+
+func latencySamples(t *testing.T, model string) uint64 {
+	t.Helper()
+	var m dto.Metric
+	if err := llmLatency.WithLabelValues(model, "ok").(prometheus.Histogram).Write(&m); err != nil {
+		t.Fatalf("read latency histogram: %v", err)
+	}
+	return m.GetHistogram().GetSampleCount()
+}
@@ -11,6 +11,7 @@ import (
 	moxieclient "github.com/wolfman30/medspa-ai-platform/internal/emr/moxie"
 	"github.com/wolfman30/medspa-ai-platform/internal/events"
 	"github.com/wolfman30/medspa-ai-platform/internal/leads"
+	"github.com/wolfman30/medspa-ai-platform/internal/tenancy"
 	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
 )
 
@@ -30,7 +31,7 @@ func TestCreateMoxieBookingAfterPayment_Success(t *testing.T) {
 	}
 
 	// Set the selected appointment
-	if err := leadsRepo.UpdateSelectedAppointment(context.Background(), lead.ID, leads.SelectedAppointment{
+	if err := leadsRepo.UpdateSelectedAppointment(context.Background(), tenancy.ForOrg(lead.OrgID), lead.ID, leads.SelectedAppointment{
 		DateTime: &scheduled,
 		Service:  "Lip Filler",
 	}); err != nil {
@@ -76,7 +77,8 @@ func TestCreateMoxieBookingAfterPayment_Success(t *testing.T) {
 		FromNumber:  "+19998887777",
 	}
 
-	booked, confirmMsg := worker.createMoxieBookingAfterPayment(context.Background(), evt, cfg)
+	booked, confirm := worker.createMoxieBookingAfterPayment(context.Background(), evt, cfg, nil)
+	confirmMsg := confirm.Body
 	if !booked {
 		t.Fatal("expected moxie booking to succeed")
 	}
@@ -88,7 +90,7 @@ func TestCreateMoxieBookingAfterPayment_Success(t *testing.T) {
 	}
 
 	// Verify lead was updated with booking session
-	updatedLead, err := leadsRepo.GetByID(context.Background(), orgID, lead.ID)
+	updatedLead, err := leadsRepo.GetByID(context.Background(), tenancy.ForOrg(orgID), lead.ID)
 	if err != nil {
 		t.Fatalf("failed to get updated lead: %v", err)
 	}
@@ -112,7 +114,7 @@ func TestCreateMoxieBookingAfterPayment_NoSelectedAppointment_FallsBackToSchedul
 	})
 
 	// Set service interest but NOT selected appointment datetime
-	_ = leadsRepo.UpdateSchedulingPreferences(context.Background(), lead.ID, leads.SchedulingPreferences{
+	_ = leadsRepo.UpdateSchedulingPreferences(context.Background(), tenancy.ForOrg(lead.OrgID), lead.ID, leads.SchedulingPreferences{
 		ServiceInterest: "Tox",
 	})
 
@@ -145,7 +147,7 @@ func TestCreateMoxieBookingAfterPayment_NoSelectedAppointment_FallsBackToSchedul
 		ScheduledFor: &scheduled,
 	}
 
-	booked, _ := worker.createMoxieBookingAfterPayment(context.Background(), evt, cfg)
+	booked, _ := worker.createMoxieBookingAfterPayment(context.Background(), evt, cfg, nil)
 	if !booked {
 		t.Fatal("expected booking to succeed with fallback to ScheduledFor")
 	}
@@ -162,7 +164,7 @@ func TestCreateMoxieBookingAfterPayment_NoService_Skips(t *testing.T) {
 		Phone: "+15551111111",
 	})
 	// Set datetime but NO service
-	_ = leadsRepo.UpdateSelectedAppointment(context.Background(), lead.ID, leads.SelectedAppointment{
+	_ = leadsRepo.UpdateSelectedAppointment(context.Background(), tenancy.ForOrg(lead.OrgID), lead.ID, leads.SelectedAppointment{
 		DateTime: &scheduled,
 	})
 
@@ -189,7 +191,7 @@ func TestCreateMoxieBookingAfterPayment_NoService_Skips(t *testing.T) {
 		FromNumber: "+19990001111",
 	}
 
-	booked, _ := worker.createMoxieBookingAfterPayment(context.Background(), evt, cfg)
+	booked, _ := worker.createMoxieBookingAfterPayment(context.Background(), evt, cfg, nil)
 	if booked {
 		t.Fatal("expected booking to be skipped when no service is set")
 	}
@@ -204,7 +206,7 @@ func TestCreateMoxieBookingAfterPayment_NoMoxieConfig_Skips(t *testing.T) {
 	}
 	evt := &events.PaymentSucceededV1{OrgID: "org-1", LeadID: "lead-1"}
 
-	booked, _ := worker.createMoxieBookingAfterPayment(context.Background(), evt, cfg)
+	booked, _ := worker.createMoxieBookingAfterPayment(context.Background(), evt, cfg, nil)
 	if booked {
 		t.Fatal("expected skip when no moxie config")
 	}
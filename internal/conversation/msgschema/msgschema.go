@@ -0,0 +1,145 @@
+// Package msgschema holds the canonical description of who wrote a stored
+// conversation message: its direction and its author type. Older entries only
+// carry an LLM role ("user", "assistant", "system"), sometimes a "sender",
+// and a kind; Resolve fills in the canonical fields from those, so the
+// portal, the qualification extractors and the E2E scripts agree on which
+// messages came from the patient.
+package msgschema
+
+import "strings"
+
+// Direction is whether a message came into the clinic or went out from it.
+type Direction string
+
+const (
+	Inbound  Direction = "inbound"
+	Outbound Direction = "outbound"
+)
+
+// Author is who wrote a message.
+type Author string
+
+const (
+	// Patient messages are the only inbound messages.
+	Patient Author = "patient"
+	// AI messages are the assistant's replies.
+	AI Author = "ai"
+	// Staff messages were written by clinic staff, e.g. a manual text sent
+	// from the portal.
+	Staff Author = "staff"
+	// System messages are automatic notices and compliance replies (STOP and
+	// HELP replies, acks, farewells) and context the assistant is given.
+	System Author = "system"
+)
+
+// Valid reports whether d is a known direction.
+func (d Direction) Valid() bool {
+	return d == Inbound || d == Outbound
+}
+
+// Valid reports whether a is a known author type.
+func (a Author) Valid() bool {
+	switch a {
+	case Patient, AI, Staff, System:
+		return true
+	}
+	return false
+}
+
+// staffKinds mark messages clinic staff wrote by hand.
+var staffKinds = map[string]bool{
+	"staff":        true,
+	"staff_reply":  true,
+	"manual":       true,
+	"manual_reply": true,
+}
+
+// systemKinds mark automatic outbound messages the assistant didn't write.
+var systemKinds = map[string]bool{
+	"ack":                      true,
+	"first_contact":            true,
+	"first_contact_ack":        true,
+	"progress":                 true,
+	"start_ack":                true,
+	"stop_ack":                 true,
+	"help_ack":                 true,
+	"farewell":                 true,
+	"voice_ack":                true,
+	"voice_callback_ack":       true,
+	"voice_callback_initiated": true,
+	"voice_callback_failed":    true,
+}
+
+// Message is the fields a stored message may carry. Direction and AuthorType
+// are the canonical fields; Role, Sender and Kind are what older entries have.
+type Message struct {
+	Direction  string
+	AuthorType string
+	Role       string
+	Sender     string
+	Kind       string
+}
+
+// Resolve returns the message's direction and author. Recorded fields win;
+// missing ones are inferred from the role, sender and kind. An outbound
+// message that otherwise looks like the patient's was sent by staff, and an
+// inbound message always comes from the patient.
+func (m Message) Resolve() (Direction, Author) {
+	dir := Direction(norm(m.Direction))
+	author := Author(norm(m.AuthorType))
+	if dir.Valid() && author.Valid() {
+		return dir, author
+	}
+	if !author.Valid() {
+		author = inferAuthor(norm(m.Role), norm(m.Sender), norm(m.Kind))
+	}
+	switch {
+	case dir == Inbound:
+		author = Patient
+	case dir == Outbound && author == Patient:
+		author = Staff
+	case !dir.Valid() && author == Patient:
+		dir = Inbound
+	case !dir.Valid() && author.Valid():
+		dir = Outbound
+	}
+	return dir, author
+}
+
+// FromPatient reports whether the patient wrote the message.
+func (m Message) FromPatient() bool {
+	_, author := m.Resolve()
+	return author == Patient
+}
+
+func inferAuthor(role, sender, kind string) Author {
+	switch sender {
+	case "patient", "user", "customer", "lead":
+		return Patient
+	case "staff", "operator", "clinic", "admin", "manual":
+		return Staff
+	case "ai", "assistant", "bot":
+		return AI
+	case "system":
+		return System
+	}
+	if staffKinds[kind] {
+		return Staff
+	}
+	switch role {
+	case "user":
+		return Patient
+	case "system":
+		return System
+	case "assistant":
+		if systemKinds[kind] {
+			return System
+		}
+		return AI
+	}
+	return ""
+}
+
+func norm(s string) string {
+	return strings.ToLower(strings.TrimSpace(s))
+}
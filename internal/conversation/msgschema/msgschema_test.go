@@ -0,0 +1,48 @@
+package msgschema
+
+import "testing"
+
+func TestResolve(t *testing.T) {
+	tests := []struct {
+		name       string
+		msg        Message
+		wantDir    Direction
+		wantAuthor Author
+	}{
+		{"recorded fields win", Message{Direction: "outbound", AuthorType: "staff", Role: "user"}, Outbound, Staff},
+		{"recorded fields are normalized", Message{Direction: " Inbound ", AuthorType: "PATIENT"}, Inbound, Patient},
+		{"legacy user role", Message{Role: "user"}, Inbound, Patient},
+		{"legacy assistant reply", Message{Role: "assistant", Kind: "ai_reply"}, Outbound, AI},
+		{"legacy assistant without kind", Message{Role: "assistant"}, Outbound, AI},
+		{"legacy ack", Message{Role: "assistant", Kind: "ack"}, Outbound, System},
+		{"legacy stop reply", Message{Role: "assistant", Kind: "stop_ack"}, Outbound, System},
+		{"legacy system context", Message{Role: "system"}, Outbound, System},
+		{"sender patient", Message{Sender: "patient"}, Inbound, Patient},
+		{"sender overrides role", Message{Role: "user", Sender: "staff"}, Outbound, Staff},
+		{"manual kind", Message{Role: "user", Kind: "manual"}, Outbound, Staff},
+		{"outbound user role is staff", Message{Role: "user", Direction: "outbound"}, Outbound, Staff},
+		{"inbound is always the patient", Message{Role: "assistant", Direction: "inbound"}, Inbound, Patient},
+		{"author without direction", Message{AuthorType: "ai"}, Outbound, AI},
+		{"unknown", Message{Role: "tool"}, "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir, author := tt.msg.Resolve()
+			if dir != tt.wantDir || author != tt.wantAuthor {
+				t.Fatalf("Resolve() = %q, %q; want %q, %q", dir, author, tt.wantDir, tt.wantAuthor)
+			}
+		})
+	}
+}
+
+func TestFromPatient(t *testing.T) {
+	if !(Message{Role: "user"}).FromPatient() {
+		t.Fatal("legacy user message should be the patient's")
+	}
+	if (Message{Role: "user", AuthorType: "staff"}).FromPatient() {
+		t.Fatal("staff message stored with the user role should not be the patient's")
+	}
+	if (Message{Role: "assistant"}).FromPatient() {
+		t.Fatal("assistant message should not be the patient's")
+	}
+}
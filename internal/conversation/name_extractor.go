@@ -63,7 +63,7 @@ func findNameInUserMessages(userMessages string) (fullName, firstName string) {
 
 func nameFromReplyAfterNameQuestion(history []ChatMessage) (fullName, firstName string) {
 	for i, msg := range history {
-		if msg.Role != ChatRoleUser {
+		if !msg.FromPatient() {
 			continue
 		}
 		prev := previousAssistantMessage(history, i)
@@ -81,7 +81,7 @@ func nameFromReplyAfterNameQuestion(history []ChatMessage) (fullName, firstName
 func combineSplitNameReplies(history []ChatMessage, firstName string) string {
 	first := strings.TrimSpace(firstName)
 	for i, msg := range history {
-		if msg.Role != ChatRoleUser {
+		if !msg.FromPatient() {
 			continue
 		}
 		prev := previousAssistantMessage(history, i)
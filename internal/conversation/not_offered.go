@@ -0,0 +1,107 @@
+package conversation
+
+import (
+	"context"
+	"regexp"
+	"strings"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/clinic"
+	"github.com/wolfman30/medspa-ai-platform/internal/leads"
+	"github.com/wolfman30/medspa-ai-platform/internal/messaging/templates"
+)
+
+const notOfferedLeadNote = "tag:asked_not_offered_service"
+
+// handleNotOfferedService declines a service the clinic doesn't provide
+// before the LLM can start qualifying the patient for it.
+func (s *LLMService) handleNotOfferedService(ctx context.Context, pc *processContext) *Response {
+	entry, ok := pc.cfg.NotOfferedServiceIn(pc.rawMessage)
+	if !ok {
+		return nil
+	}
+	s.log(ctx).Info("ProcessMessage: declined not-offered service",
+		"conversation_id", pc.req.ConversationID,
+		"service", entry.Service,
+	)
+	s.appendLeadNote(ctx, pc.req.OrgID, pc.req.LeadID, notOfferedLeadNote)
+	reply := notOfferedReply(pc.cfg, entry)
+	resp := s.saveAndReturn(ctx, pc, reply.Body, "not_offered_service")
+	resp.Template = reply.Ref
+	return resp
+}
+
+// notOfferedReply tells the patient the clinic doesn't offer a service and
+// pivots to related ones it does, with the clinic's referral when set.
+func notOfferedReply(cfg *clinic.Config, entry clinic.NotOfferedService) templates.Rendered {
+	params := templates.Params{
+		"service":      strings.TrimSpace(entry.Service),
+		"alternatives": joinServiceNames(cfg.NotOfferedAlternatives(entry)),
+		"referral":     strings.TrimSpace(entry.Referral),
+	}
+	switch {
+	case params["referral"] == "" && params["alternatives"] != "":
+		return cfg.RenderTemplate(templates.ReplyNotOfferedAlternatives, params)
+	case params["alternatives"] != "":
+		return cfg.RenderTemplate(templates.ReplyNotOfferedReferralAlternatives, params)
+	case params["referral"] != "":
+		return cfg.RenderTemplate(templates.ReplyNotOfferedReferral, params)
+	default:
+		return cfg.RenderTemplate(templates.ReplyNotOffered, params)
+	}
+}
+
+// joinServiceNames lists names as "A", "A and B", or "A, B, and C".
+func joinServiceNames(names []string) string {
+	switch len(names) {
+	case 0:
+		return ""
+	case 1:
+		return names[0]
+	case 2:
+		return names[0] + " and " + names[1]
+	}
+	return strings.Join(names[:len(names)-1], ", ") + ", and " + names[len(names)-1]
+}
+
+// extractClinicPreferences is extractPreferences with the clinic's service
+// aliases, never returning a service the clinic doesn't offer.
+func extractClinicPreferences(history []ChatMessage, cfg *clinic.Config) (leads.SchedulingPreferences, bool) {
+	return extractOfferedPreferences(history, cfg, serviceAliasesFromConfig(cfg))
+}
+
+// extractOfferedPreferences runs extractPreferences and, when the service
+// interest is one the clinic doesn't offer, uses the next service the patient
+// named instead, if any. Assistant turns are skipped for the fallback: the
+// decline itself lists the alternatives.
+func extractOfferedPreferences(history []ChatMessage, cfg *clinic.Config, aliases map[string]string) (leads.SchedulingPreferences, bool) {
+	prefs, ok := extractPreferences(history, aliases)
+	if prefs.ServiceInterest == "" || !cfg.IsNotOffered(prefs.ServiceInterest) {
+		return prefs, ok
+	}
+	userMessages, _ := collectUserMessages(withoutNotOfferedServices(history, cfg))
+	prefs.ServiceInterest = matchService(userMessages, aliases)
+	if cfg.IsNotOffered(prefs.ServiceInterest) {
+		prefs.ServiceInterest = ""
+	}
+	return prefs, ok
+}
+
+// withoutNotOfferedServices returns a copy of history with every mention of
+// a not-offered service removed.
+func withoutNotOfferedServices(history []ChatMessage, cfg *clinic.Config) []ChatMessage {
+	terms := cfg.NotOfferedTerms()
+	if len(terms) == 0 {
+		return history
+	}
+	quoted := make([]string, len(terms))
+	for i, term := range terms {
+		quoted[i] = regexp.QuoteMeta(term)
+	}
+	re := regexp.MustCompile(`(?i)\b(?:` + strings.Join(quoted, "|") + `)s?\b`)
+	out := make([]ChatMessage, len(history))
+	for i, msg := range history {
+		msg.Content = re.ReplaceAllString(msg.Content, "")
+		out[i] = msg
+	}
+	return out
+}
@@ -0,0 +1,131 @@
+package conversation
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/clinic"
+	"github.com/wolfman30/medspa-ai-platform/internal/leads"
+	"github.com/wolfman30/medspa-ai-platform/internal/tenancy"
+	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
+)
+
+func noTattooClinic(orgID string) *clinic.Config {
+	cfg := clinic.DefaultConfig(orgID)
+	cfg.Services = []string{"Botox", "laser hair removal", "IPL"}
+	cfg.ServiceAliases = map[string]string{"ink removal": "tattoo removal"}
+	cfg.NotOffered = []clinic.NotOfferedService{{Service: "tattoo removal", Aliases: []string{"tattoo"}}}
+	return cfg
+}
+
+func TestLLMService_NotOfferedServiceGetsReferralWithoutLLM(t *testing.T) {
+	mr := miniredis.RunT(t)
+	ctx := context.Background()
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	clinicStore := clinic.NewStore(client)
+	cfg := noTattooClinic("org-1")
+	cfg.NotOffered[0].Referral = "Ink Away on Main St. does great work."
+	if err := clinicStore.Set(ctx, cfg); err != nil {
+		t.Fatalf("set clinic config: %v", err)
+	}
+	leadsRepo := leads.NewInMemoryRepository()
+	lead, err := leadsRepo.Create(ctx, &leads.CreateLeadRequest{OrgID: "org-1", Phone: "+15550000000", Source: "sms"})
+	if err != nil {
+		t.Fatalf("create lead: %v", err)
+	}
+
+	mockLLM := &stubLLMClient{response: LLMResponse{Text: "Hello!"}}
+	service := NewLLMService(mockLLM, client, nil, "anthropic.claude-3-haiku-20240307-v1:0", logging.Default(),
+		WithClinicStore(clinicStore), WithLeadsRepo(leadsRepo))
+
+	start, err := service.StartConversation(ctx, StartRequest{
+		ConversationID: "conv-tattoo",
+		LeadID:         lead.ID,
+		OrgID:          "org-1",
+		Intro:          "Do you do tattoo removal?",
+		Channel:        ChannelSMS,
+	})
+	if err != nil {
+		t.Fatalf("start failed: %v", err)
+	}
+	want := "We don't offer tattoo removal. Ink Away on Main St. does great work. We do offer laser hair removal and IPL — want to hear more?"
+	if start.Message != want {
+		t.Fatalf("start reply = %q, want %q", start.Message, want)
+	}
+
+	resp, err := service.ProcessMessage(ctx, MessageRequest{
+		ConversationID: start.ConversationID,
+		LeadID:         lead.ID,
+		OrgID:          "org-1",
+		Message:        "I'm Sarah Johnson, I want to get a tattoo removed Friday morning",
+		Channel:        ChannelSMS,
+	})
+	if err != nil {
+		t.Fatalf("process failed: %v", err)
+	}
+	if resp.Message != want || resp.TimeSelectionResponse != nil {
+		t.Fatalf("expected the referral again and no availability, got %+v", resp)
+	}
+	if mockLLM.calls != 0 {
+		t.Fatalf("expected the LLM not to be called, got %d calls", mockLLM.calls)
+	}
+
+	saved, err := leadsRepo.GetByID(ctx, tenancy.ForOrg("org-1"), lead.ID)
+	if err != nil {
+		t.Fatalf("get lead: %v", err)
+	}
+	if saved.ServiceInterest != "" {
+		t.Fatalf("not-offered service saved as the lead's interest: %q", saved.ServiceInterest)
+	}
+	if saved.Name != "Sarah Johnson" || !strings.Contains(saved.SchedulingNotes, notOfferedLeadNote) {
+		t.Fatalf("expected name and tag saved, got name %q notes %q", saved.Name, saved.SchedulingNotes)
+	}
+}
+
+func TestNotOfferedService_AliasResolvesToNotOffered(t *testing.T) {
+	cfg := noTattooClinic("org-1")
+
+	entry, ok := cfg.NotOfferedServiceIn("How much is ink removal?")
+	if !ok || entry.Service != "tattoo removal" {
+		t.Fatalf("expected the alias to match tattoo removal, got %+v, %v", entry, ok)
+	}
+	if got := notOfferedReply(cfg, entry).Body; got != "We don't offer tattoo removal, but we do laser hair removal and IPL — want to hear more?" {
+		t.Fatalf("reply = %q", got)
+	}
+
+	history := qualifiedHistory("ink removal")
+	if prefs, _ := extractClinicPreferences(history, cfg); prefs.ServiceInterest != "" {
+		t.Fatalf("expected no service interest, got %q", prefs.ServiceInterest)
+	}
+	if ShouldFetchAvailabilityWithConfig(history, nil, cfg) {
+		t.Fatal("availability must not be fetched for a not-offered service")
+	}
+}
+
+func TestNotOfferedService_OfferedServicesUnaffected(t *testing.T) {
+	cfg := noTattooClinic("org-1")
+
+	if entry, ok := cfg.NotOfferedServiceIn("Can I book laser hair removal?"); ok {
+		t.Fatalf("offered service declined as %+v", entry)
+	}
+	history := qualifiedHistory("laser hair removal")
+	prefs, _ := extractClinicPreferences(history, cfg)
+	if !strings.EqualFold(prefs.ServiceInterest, "laser hair removal") {
+		t.Fatalf("service interest = %q", prefs.ServiceInterest)
+	}
+	if !ShouldFetchAvailabilityWithConfig(history, nil, cfg) {
+		t.Fatal("expected availability for an offered service")
+	}
+
+	// A patient who moves on from the declined service gets the new one.
+	history = withTurns(qualifiedHistory("tattoo removal"),
+		"We don't offer tattoo removal, but we do laser hair removal and IPL — want to hear more?",
+		"ok, IPL then")
+	if prefs, _ := extractClinicPreferences(history, cfg); !strings.EqualFold(prefs.ServiceInterest, "ipl") {
+		t.Fatalf("expected IPL after the pivot, got %q", prefs.ServiceInterest)
+	}
+}
@@ -13,7 +13,7 @@ var (
 )
 
 // detectPatientType merges all patient-type detection approaches into one function.
-// Priority: 1) regex scan of all user messages, 2) short-reply context check.
+// Priority: 1) regex scan of all user messages, 2) reply to a patient-type question.
 func detectPatientType(userMessages string, history []ChatMessage) string {
 	// 1. Regex scan across all user messages
 	if newPatientRE.MatchString(userMessages) {
@@ -23,19 +23,18 @@ func detectPatientType(userMessages string, history []ChatMessage) string {
 		return "existing"
 	}
 
-	// 2. Short reply after assistant asked about patient type
+	// 2. Short reply after assistant asked about patient type, alone or as one
+	// clause of a compound answer ("New, weekday mornings")
 	for i := len(history) - 1; i >= 0; i-- {
-		if history[i].Role != ChatRoleUser {
-			continue
-		}
-		reply := normalizePatientTypeReply(history[i].Content)
-		if reply == "" {
+		if !history[i].FromPatient() {
 			continue
 		}
 		if !assistantAskedPatientType(history, i) {
 			continue
 		}
-		return reply
+		if reply := patientTypeFromAnswer(history[i].Content); reply != "" {
+			return reply
+		}
 	}
 	return ""
 }
@@ -0,0 +1,49 @@
+package conversation
+
+import (
+	"context"
+	"regexp"
+)
+
+// depositNotConfirmedReply replaces a reply that acknowledged a payment the
+// payments repository has no record of.
+const depositNotConfirmedReply = "I don't see a completed deposit on our end yet. If you received a payment link, you can finish there, and you'll get a confirmation text as soon as it goes through."
+
+var (
+	// paymentAckRE matches a sentence telling the patient their payment
+	// arrived.
+	paymentAckRE = regexp.MustCompile(`(?i)(?:\b(?:deposit|payment)\b[^.!?\n]{0,30}?\b(?:has been|have been|was|is)\s+(?:successfully\s+)?(?:received|paid|processed|confirmed|completed)\b|\bthanks?(?:\s+you)?\s+(?:so much\s+)?for\s+(?:your|the)\s+(?:deposit|payment)\b|\b(?:received|got)\s+your\s+(?:deposit|payment)\b|\b(?:deposit|payment)\s+(?:went through|is all set))`)
+	// paymentConditionalRE marks a sentence that talks about a future payment
+	// ("once your deposit is received...") rather than acknowledging one.
+	paymentConditionalRE = regexp.MustCompile(`(?i)\b(?:once|after|when|if|until|before)\b`)
+	sentenceSplitRE      = regexp.MustCompile(`[.!?\n]+`)
+)
+
+// replyAcknowledgesPayment reports whether reply tells the patient their
+// deposit or payment was received.
+func replyAcknowledgesPayment(reply string) bool {
+	for _, sentence := range sentenceSplitRE.Split(reply, -1) {
+		if paymentAckRE.MatchString(sentence) && !paymentConditionalRE.MatchString(sentence) {
+			return true
+		}
+	}
+	return false
+}
+
+// guardPaymentAcknowledgement keeps the assistant from confirming a payment
+// the payments repository hasn't recorded, such as one a patient claimed in
+// their own message.
+func (s *LLMService) guardPaymentAcknowledgement(ctx context.Context, pc *processContext, reply string) string {
+	if !replyAcknowledgesPayment(reply) {
+		return reply
+	}
+	switch s.depositStatus(ctx, pc.req.OrgID, pc.req.LeadID) {
+	case "succeeded", depositStatusOpen:
+		return reply
+	}
+	s.log(ctx).Warn("ProcessMessage: reply acknowledged an unrecorded payment; replaced",
+		"conversation_id", pc.req.ConversationID,
+		"org_id", pc.req.OrgID,
+	)
+	return depositNotConfirmedReply
+}
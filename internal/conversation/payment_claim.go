@@ -0,0 +1,173 @@
+package conversation
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/messaging/templates"
+	"github.com/wolfman30/medspa-ai-platform/internal/payments"
+	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
+)
+
+// PaymentClaimChecker confirms a lead's pending deposit with the payment
+// provider and records it if the provider shows it paid.
+type PaymentClaimChecker interface {
+	CheckClaimedPayment(ctx context.Context, orgID, leadID string) (payments.ClaimStatus, error)
+}
+
+// A claim Square can't confirm yet is checked again a few times before the
+// patient hears it still hasn't shown up.
+const (
+	paymentClaimRecheckDelay = 3 * time.Minute
+	paymentClaimMaxChecks    = 3
+)
+
+// PaymentClaimCheckRequest is a scheduled re-check of a deposit the patient
+// said they paid.
+type PaymentClaimCheckRequest struct {
+	OrgID          string `json:"org_id"`
+	LeadID         string `json:"lead_id"`
+	ConversationID string `json:"conversation_id"`
+	// Phone is the lead's number; ClinicPhone the number the conversation
+	// runs on.
+	Phone       string `json:"phone"`
+	ClinicPhone string `json:"clinic_phone"`
+	// Attempt counts the re-checks, starting at 1.
+	Attempt int `json:"attempt"`
+}
+
+var (
+	// paymentClaimRE matches a patient saying they paid or sharing a receipt.
+	paymentClaimRE = regexp.MustCompile(`(?i)(?:\b(?:i|i'?ve|i have|we|we'?ve|just|already)\s+(?:just\s+|already\s+|now\s+)?paid\b|\b(?:sent|made|submitted|completed|finished|paid)\s+(?:the\s+|my\s+|a\s+|our\s+)?(?:deposit|payment)\b|\b(?:deposit|payment)\s+(?:went through|is (?:done|complete|completed|sent|made|paid)|(?:has been|was)\s+(?:sent|made|paid|completed|submitted))\b|\breceipt\b|^\s*(?:all\s+)?paid\W*$)`)
+	// paymentClaimNegatedRE marks a sentence about a payment not yet made,
+	// one the patient intends to make, or a question about paying.
+	paymentClaimNegatedRE = regexp.MustCompile(`(?i)(?:\b(?:not|never|can|could|should|will|gonna|going to|about to|want to|need to|trying|tried|try|how|where|when|if|once)\b|\b(?:haven|hasn|didn|don|doesn|couldn|won|wasn|isn|aren)'?t\b|'ll\b)`)
+	claimSentenceRE       = regexp.MustCompile(`[^.!?,;\n]+[.!?,;]*`)
+)
+
+// claimsPayment reports whether a patient's text says they've paid.
+func claimsPayment(message string) bool {
+	for _, sentence := range claimSentenceRE.FindAllString(message, -1) {
+		sentence = strings.TrimSpace(sentence)
+		if strings.HasSuffix(sentence, "?") || paymentClaimNegatedRE.MatchString(sentence) {
+			continue
+		}
+		if paymentClaimRE.MatchString(sentence) {
+			return true
+		}
+	}
+	return false
+}
+
+// handlePaymentClaim checks the patient's pending deposit with the payment
+// provider when they say they paid or send a picture (usually a receipt),
+// instead of letting the assistant point them back at the link. It returns
+// nil when there is no pending deposit, so the message is processed as usual.
+func (w *Worker) handlePaymentClaim(ctx context.Context, msg MessageRequest) *Response {
+	if w.paymentClaims == nil || msg.Channel != ChannelSMS || msg.LeadID == "" {
+		return nil
+	}
+	text := strings.TrimSpace(msg.Message)
+	if text != MediaPlaceholder && !claimsPayment(text) {
+		return nil
+	}
+	status, err := w.paymentClaims.CheckClaimedPayment(ctx, msg.OrgID, msg.LeadID)
+	if err != nil {
+		w.log(ctx).Warn("payment claim check failed", "error", err)
+		return nil
+	}
+
+	now := time.Now().UTC()
+	var reply templates.Rendered
+	switch status {
+	case payments.ClaimConfirmed:
+		reply = templates.Render(templates.PaymentClaimConfirmed, nil, nil)
+	case payments.ClaimPending:
+		reply = templates.Render(templates.PaymentClaimPending, nil, nil)
+		w.schedulePaymentClaimCheck(ctx, PaymentClaimCheckRequest{
+			OrgID:          msg.OrgID,
+			LeadID:         msg.LeadID,
+			ConversationID: msg.ConversationID,
+			Phone:          msg.From,
+			ClinicPhone:    msg.To,
+			Attempt:        1,
+		}, now)
+	default:
+		return nil
+	}
+	w.log(ctx).Info("payment claim checked with provider", "status", status)
+	return &Response{
+		ConversationID: msg.ConversationID,
+		Message:        reply.Body,
+		Timestamp:      now,
+		Template:       reply.Ref,
+	}
+}
+
+func (w *Worker) schedulePaymentClaimCheck(ctx context.Context, req PaymentClaimCheckRequest, now time.Time) {
+	if w.scheduler == nil {
+		return
+	}
+	if err := w.scheduler.publisher.EnqueuePaymentClaimCheckAt(ctx, logging.NewID(), req, now.Add(paymentClaimRecheckDelay)); err != nil {
+		w.log(ctx).Warn("failed to schedule payment claim re-check", "error", err)
+	}
+}
+
+// recheckPaymentClaim checks a claimed deposit again. Once the provider
+// confirms it, the payment's own confirmation text covers the patient; after
+// the last check the patient is told it still hasn't arrived.
+func (w *Worker) recheckPaymentClaim(ctx context.Context, req *PaymentClaimCheckRequest, now time.Time) error {
+	if req == nil {
+		return errors.New("conversation: payment claim check: missing request")
+	}
+	if w.paymentClaims == nil {
+		return nil
+	}
+	status, err := w.paymentClaims.CheckClaimedPayment(ctx, req.OrgID, req.LeadID)
+	if err != nil {
+		w.log(ctx).Warn("payment claim re-check failed", "error", err, "attempt", req.Attempt)
+		status = payments.ClaimPending
+	}
+	if status != payments.ClaimPending {
+		w.log(ctx).Info("payment claim resolved", "status", status, "attempt", req.Attempt)
+		return nil
+	}
+	if req.Attempt < paymentClaimMaxChecks {
+		next := *req
+		next.Attempt++
+		w.schedulePaymentClaimCheck(ctx, next, now)
+		return nil
+	}
+	if w.isOptedOut(ctx, req.OrgID, req.Phone) {
+		return nil
+	}
+
+	reply := templates.Render(templates.PaymentClaimUnconfirmed, nil, nil)
+	if w.messenger != nil {
+		if err := w.messenger.SendReply(ctx, OutboundReply{
+			OrgID:          req.OrgID,
+			LeadID:         req.LeadID,
+			ConversationID: req.ConversationID,
+			To:             req.Phone,
+			From:           req.ClinicPhone,
+			Body:           reply.Body,
+			Metadata:       reply.Stamp(nil),
+		}); err != nil {
+			return err
+		}
+	}
+	w.appendTranscript(ctx, req.ConversationID, SMSTranscriptMessage{
+		Role:      "assistant",
+		From:      req.ClinicPhone,
+		To:        req.Phone,
+		Body:      reply.Body,
+		Timestamp: now,
+		Kind:      "payment_claim",
+		Metadata:  reply.Stamp(nil),
+	})
+	w.log(ctx).Info("payment claim still unconfirmed; patient told")
+	return nil
+}
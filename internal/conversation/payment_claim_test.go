@@ -0,0 +1,193 @@
+package conversation
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/messaging/templates"
+	"github.com/wolfman30/medspa-ai-platform/internal/payments"
+	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
+)
+
+type stubClaimChecker struct {
+	status payments.ClaimStatus
+	calls  int
+}
+
+func (s *stubClaimChecker) CheckClaimedPayment(ctx context.Context, orgID, leadID string) (payments.ClaimStatus, error) {
+	s.calls++
+	return s.status, nil
+}
+
+type claimFixture struct {
+	worker    *Worker
+	checker   *stubClaimChecker
+	store     *memScheduledJobStore
+	messenger *recordingMessenger
+	processor *recordingService
+	msg       MessageRequest
+}
+
+func newClaimFixture(status payments.ClaimStatus) *claimFixture {
+	now := time.Now().UTC()
+	store := newMemScheduledJobStore(now)
+	publisher := NewPublisher(&stubQueue{}, &stubJobRecorder{}, logging.Default())
+	publisher.SetScheduledJobStore(store)
+	checker := &stubClaimChecker{status: status}
+	messenger := &recordingMessenger{}
+	processor := &recordingService{}
+	worker := NewWorker(processor, newScriptedQueue(), &stubJobUpdater{}, messenger, nil, logging.Default(),
+		WithScheduler(NewScheduler(store, publisher, logging.Default())),
+		WithPaymentClaimChecker(checker))
+	return &claimFixture{
+		worker:    worker,
+		checker:   checker,
+		store:     store,
+		messenger: messenger,
+		processor: processor,
+		msg: MessageRequest{
+			OrgID:          "org-1",
+			LeadID:         "lead-1",
+			ConversationID: "sms:org-1:15005550002",
+			From:           "+15005550002",
+			To:             "+15005550100",
+			Channel:        ChannelSMS,
+		},
+	}
+}
+
+func (f *claimFixture) dispatch(t *testing.T, text string) *Response {
+	t.Helper()
+	payload := queuePayload{ID: "job-1", Kind: jobTypeMessage, Message: f.msg}
+	payload.Message.Message = text
+	resp, err := f.worker.dispatchMessage(context.Background(), &payload)
+	if err != nil {
+		t.Fatalf("dispatch: %v", err)
+	}
+	return resp
+}
+
+// rechecks returns the pending claim re-checks and their requests.
+func (f *claimFixture) rechecks(t *testing.T) ([]ScheduledJob, []PaymentClaimCheckRequest) {
+	t.Helper()
+	f.store.mu.Lock()
+	defer f.store.mu.Unlock()
+	var jobs []ScheduledJob
+	var reqs []PaymentClaimCheckRequest
+	for _, job := range f.store.jobs {
+		if job.Kind != string(jobTypePaymentClaimCheck) || job.Status != ScheduledJobPending {
+			continue
+		}
+		var payload queuePayload
+		if err := json.Unmarshal(job.Payload, &payload); err != nil || payload.ClaimCheck == nil {
+			t.Fatalf("decode re-check payload: %v", err)
+		}
+		jobs = append(jobs, *job)
+		reqs = append(reqs, *payload.ClaimCheck)
+	}
+	return jobs, reqs
+}
+
+func TestClaimsPayment(t *testing.T) {
+	tests := []struct {
+		message string
+		want    bool
+	}{
+		{"I paid!", true},
+		{"just paid", true},
+		{"Ok I just sent the payment", true},
+		{"payment went through", true},
+		{"Here's my receipt", true},
+		{"Paid", true},
+		{"I paid, can you confirm?", true},
+		{"I haven't paid yet", false},
+		{"I'll pay tonight", false},
+		{"How do I pay?", false},
+		{"Did my payment go through?", false},
+		{"I will send the deposit later", false},
+		{"I tried to pay but it failed", false},
+		{"What's the deposit amount", false},
+	}
+	for _, tt := range tests {
+		if got := claimsPayment(tt.message); got != tt.want {
+			t.Errorf("claimsPayment(%q) = %v, want %v", tt.message, got, tt.want)
+		}
+	}
+}
+
+func TestPaymentClaim_ConfirmedOnPoll(t *testing.T) {
+	f := newClaimFixture(payments.ClaimConfirmed)
+
+	resp := f.dispatch(t, "I paid!")
+	if resp == nil || resp.Message != templates.Text(templates.PaymentClaimConfirmed) {
+		t.Fatalf("expected the confirmed reply, got %+v", resp)
+	}
+	if f.processor.messageCalls != 0 {
+		t.Fatal("expected the LLM to be skipped")
+	}
+	if jobs, _ := f.rechecks(t); len(jobs) != 0 {
+		t.Fatalf("expected no re-check once confirmed, got %d", len(jobs))
+	}
+}
+
+func TestPaymentClaim_StillPendingSchedulesRecheck(t *testing.T) {
+	f := newClaimFixture(payments.ClaimPending)
+	ctx := context.Background()
+
+	before := time.Now()
+	resp := f.dispatch(t, MediaPlaceholder)
+	if resp == nil || resp.Message != templates.Text(templates.PaymentClaimPending) {
+		t.Fatalf("expected the pending reply for a receipt screenshot, got %+v", resp)
+	}
+	jobs, reqs := f.rechecks(t)
+	if len(jobs) != 1 || reqs[0].Attempt != 1 || reqs[0].Phone != f.msg.From || reqs[0].ClinicPhone != f.msg.To {
+		t.Fatalf("expected one first re-check, got %+v", reqs)
+	}
+	if jobs[0].RunAt.Before(before.Add(paymentClaimRecheckDelay)) {
+		t.Fatalf("re-check runs at %v, want at least %v out", jobs[0].RunAt, paymentClaimRecheckDelay)
+	}
+
+	// Still pending: the next attempt is scheduled without texting.
+	now := jobs[0].RunAt
+	if err := f.worker.recheckPaymentClaim(ctx, &reqs[0], now); err != nil {
+		t.Fatalf("recheck: %v", err)
+	}
+	if _, reqs = f.rechecks(t); len(reqs) != 2 || len(f.messenger.allReplies()) != 0 {
+		t.Fatalf("expected a second re-check and no text, got %+v", reqs)
+	}
+
+	// The last attempt tells the patient it hasn't arrived yet.
+	final := PaymentClaimCheckRequest{OrgID: "org-1", LeadID: "lead-1", ConversationID: f.msg.ConversationID, Phone: f.msg.From, ClinicPhone: f.msg.To, Attempt: paymentClaimMaxChecks}
+	if err := f.worker.recheckPaymentClaim(ctx, &final, now); err != nil {
+		t.Fatalf("final recheck: %v", err)
+	}
+	replies := f.messenger.allReplies()
+	if len(replies) != 1 || replies[0].Body != templates.Text(templates.PaymentClaimUnconfirmed) || replies[0].To != f.msg.From {
+		t.Fatalf("expected the unconfirmed text, got %+v", replies)
+	}
+
+	// Once the webhook or a re-check records it, the sequence stops quietly.
+	f.checker.status = payments.ClaimNoPendingDeposit
+	if err := f.worker.recheckPaymentClaim(ctx, &reqs[0], now); err != nil {
+		t.Fatalf("recheck after payment: %v", err)
+	}
+	if _, after := f.rechecks(t); len(after) != 2 || len(f.messenger.allReplies()) != 1 {
+		t.Fatalf("expected nothing further once paid, got %d re-checks", len(after))
+	}
+}
+
+func TestPaymentClaim_NoPendingDepositFallsThrough(t *testing.T) {
+	f := newClaimFixture(payments.ClaimNoPendingDeposit)
+	f.dispatch(t, "I paid")
+	if f.processor.messageCalls != 1 {
+		t.Fatalf("expected the LLM to handle the message, got %d calls", f.processor.messageCalls)
+	}
+
+	f.checker.calls = 0
+	f.dispatch(t, "Do you have anything Friday?")
+	if f.checker.calls != 0 {
+		t.Fatal("expected no provider check for an unrelated message")
+	}
+}
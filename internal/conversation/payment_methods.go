@@ -0,0 +1,71 @@
+package conversation
+
+import (
+	"context"
+	"regexp"
+	"strings"
+)
+
+var (
+	// paymentMethodTermRE matches the payment methods, financing providers
+	// and insurance patients ask about.
+	paymentMethodTermRE = regexp.MustCompile(`(?i)\b(?:insurance|care\s*credit|cherry|financing|finance|payment plans?|monthly payments?|pay (?:monthly|over time|in installments)|afterpay|klarna|affirm|patientfi|hsa|fsa|credit cards?|debit cards?|cash|venmo|zelle|apple pay|amex|american express|forms? of payment|payment (?:methods?|options?)|ways to pay)\b`)
+	// paymentQuestionOpenerRE starts a clause that asks something: "do you
+	// take cash", "also, is financing available", "wondering if you accept
+	// Cherry".
+	paymentQuestionOpenerRE = regexp.MustCompile(`(?i)^(?:(?:and|also|so|but|just|hi|hey|oh|ok|okay|quick question)\s+)*(?:do|does|can|could|will|would|is|are|what|which|how|any)\b|\b(?:wondering|curious|know|ask) (?:if|whether)\b`)
+	// paymentClauseRE splits a message into clauses, each with the
+	// punctuation that ends it.
+	paymentClauseRE     = regexp.MustCompile(`[^.!?,;\n]+[.!?,;]*`)
+	insuranceQuestionRE = regexp.MustCompile(`(?i)\binsurance\b`)
+)
+
+// isPaymentMethodQuestion reports whether message asks which payment
+// methods, financing or insurance the clinic accepts. The payment term has to
+// sit in a clause shaped like a question, so "I work in finance, what times
+// are open?" and "I paid cash" don't count.
+func isPaymentMethodQuestion(message string) bool {
+	for _, clause := range paymentClauseRE.FindAllString(message, -1) {
+		if !paymentMethodTermRE.MatchString(clause) {
+			continue
+		}
+		text := strings.TrimSpace(strings.TrimRight(clause, ".!?,;"))
+		if strings.Contains(clause, "?") || paymentQuestionOpenerRE.MatchString(text) {
+			return true
+		}
+	}
+	return false
+}
+
+// askedPaymentMethodsBefore reports whether an earlier patient message in
+// history asked about payment methods. The current message is last.
+func askedPaymentMethodsBefore(history []ChatMessage) bool {
+	for i := len(history) - 2; i >= 0; i-- {
+		if history[i].FromPatient() && isPaymentMethodQuestion(history[i].Content) {
+			return true
+		}
+	}
+	return false
+}
+
+// handlePaymentMethodQuestion answers "do you take insurance / CareCredit /
+// Cherry?" from the clinic's payment_methods config, so the LLM never
+// invents an insurance policy. Follow-ups, and questions mixed with a
+// booking request or a price question, go to the LLM, which gets the config
+// through appendClinicConfigContext.
+func (s *LLMService) handlePaymentMethodQuestion(ctx context.Context, pc *processContext) *Response {
+	if !isPaymentMethodQuestion(pc.rawMessage) {
+		return nil
+	}
+	if askedPaymentMethodsBefore(pc.history) || containsBookingIntent(pc.rawMessage) || isPriceInquiry(pc.rawMessage) {
+		return nil
+	}
+	asksInsurance := insuranceQuestionRE.MatchString(pc.rawMessage)
+	if pc.cfg == nil || pc.cfg.PaymentMethods.IsZero() || (asksInsurance && strings.TrimSpace(pc.cfg.PaymentMethods.Insurance) == "") {
+		s.appendLeadNote(ctx, pc.req.OrgID, pc.req.LeadID, "tag:payment_question")
+	}
+	answer := pc.cfg.PaymentMethodsAnswer(asksInsurance)
+	resp := s.saveAndReturn(ctx, pc, answer.Body, "payment_methods")
+	resp.Template = answer.Ref
+	return resp
+}
@@ -0,0 +1,167 @@
+package conversation
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/clinic"
+	"github.com/wolfman30/medspa-ai-platform/internal/leads"
+	"github.com/wolfman30/medspa-ai-platform/internal/messaging/templates"
+	"github.com/wolfman30/medspa-ai-platform/internal/tenancy"
+)
+
+func TestIsPaymentMethodQuestion(t *testing.T) {
+	tests := []struct {
+		message string
+		want    bool
+	}{
+		{"Do you take insurance?", true},
+		{"do u accept care credit", true},
+		{"Is CareCredit an option?", true},
+		{"Can I use Cherry?", true},
+		{"do you offer financing", true},
+		{"Any payment plans?", true},
+		{"What forms of payment do you accept?", true},
+		{"can I pay with my HSA card", true},
+		{"does insurance cover botox?", true},
+		{"Venmo?", true},
+		{"I paid cash last time", false},
+		{"I love cherry lip gloss", false},
+		{"How much is Botox?", false},
+		{"Can I book Friday?", false},
+		{"Also, is financing available", true},
+		{"just wondering if you accept cherry", true},
+		{"I work in finance, what times are open?", false},
+		{"Are you open Saturday? I'll pay cash", false},
+		{"Cash is fine. Can I come in Friday?", false},
+		{"Can you text me after work? I do finance stuff", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if got := isPaymentMethodQuestion(tt.message); got != tt.want {
+			t.Errorf("isPaymentMethodQuestion(%q) = %v, want %v", tt.message, got, tt.want)
+		}
+	}
+}
+
+func withPaymentMethods(pm *clinic.PaymentMethods) func(*testSetup) {
+	return withClinicConfig("org-pay", func(cfg *clinic.Config) {
+		cfg.Name = "Glow Spa"
+		cfg.PaymentMethods = pm
+	})
+}
+
+func askPayment(t *testing.T, ts *testSetup, convID, leadID, message string) *Response {
+	t.Helper()
+	resp, err := ts.svc.ProcessMessage(context.Background(), MessageRequest{
+		ConversationID: convID,
+		OrgID:          "org-pay",
+		LeadID:         leadID,
+		Message:        message,
+		Channel:        ChannelSMS,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return resp
+}
+
+func TestProcessMessage_PaymentMethodsAnsweredFromConfig(t *testing.T) {
+	ts := setupService(t,
+		withLLMResponses("Hello!", "LLM should not handle this"),
+		withPaymentMethods(&clinic.PaymentMethods{
+			Accepted:  []string{"cash", "all major credit cards"},
+			Financing: []string{"Cherry", "CareCredit"},
+			Insurance: clinic.InsuranceNotAccepted,
+		}),
+	)
+	startConv(t, ts, "conv-pay", "org-pay", "Hi")
+
+	resp := askPayment(t, ts, "conv-pay", "", "Do you take insurance or CareCredit?")
+	want := "Great question! We accept cash, all major credit cards, and financing through Cherry and CareCredit. " +
+		"We don't accept insurance, since cosmetic treatments usually aren't covered."
+	if resp.Message != want {
+		t.Fatalf("reply = %q, want %q", resp.Message, want)
+	}
+	if resp.Template.ID != templates.PaymentMethodsAnswer {
+		t.Fatalf("expected the reply stamped with %s, got %+v", templates.PaymentMethodsAnswer, resp.Template)
+	}
+	if len(ts.llm.requests) != 1 {
+		t.Fatalf("expected 1 LLM call (start only), got %d", len(ts.llm.requests))
+	}
+}
+
+func TestProcessMessage_PaymentMethodsNeverClaimsInsurance(t *testing.T) {
+	ts := setupService(t,
+		withLLMResponses("Hello!", "LLM should not handle this"),
+		withPaymentMethods(&clinic.PaymentMethods{Accepted: []string{"cash"}, Insurance: clinic.InsuranceNotAccepted}),
+	)
+	startConv(t, ts, "conv-ins", "org-pay", "Hi")
+
+	resp := askPayment(t, ts, "conv-ins", "", "does insurance cover botox?")
+	lower := strings.ToLower(resp.Message)
+	if !strings.Contains(lower, "don't accept insurance") {
+		t.Fatalf("expected the not-accepted line, got %q", resp.Message)
+	}
+	for _, claim := range []string{"we accept insurance", "work with insurance", "check your coverage"} {
+		if strings.Contains(lower, claim) {
+			t.Fatalf("reply must not claim insurance (%q), got %q", claim, resp.Message)
+		}
+	}
+}
+
+func TestProcessMessage_PaymentMethodsUnconfiguredPromisesFollowUp(t *testing.T) {
+	ts := setupService(t,
+		withLLMResponses("Hello!", "LLM should not handle this"),
+		withPaymentMethods(nil),
+		withLeads(),
+	)
+	ctx := context.Background()
+	lead, err := ts.leadsRepo.Create(ctx, &leads.CreateLeadRequest{OrgID: "org-pay", Phone: "+15005550101", Source: "sms"})
+	if err != nil {
+		t.Fatalf("create lead: %v", err)
+	}
+	startConv(t, ts, "conv-unset", "org-pay", "Hi")
+
+	resp := askPayment(t, ts, "conv-unset", lead.ID, "Do you guys do Cherry financing?")
+	if !strings.Contains(resp.Message, "I'll have the Glow Spa team confirm which payment and financing options they accept") {
+		t.Fatalf("expected the team-will-confirm reply, got %q", resp.Message)
+	}
+	if strings.Contains(strings.ToLower(resp.Message), "we accept") {
+		t.Fatalf("unconfigured clinic must not list payment methods, got %q", resp.Message)
+	}
+	if resp.Template.ID != templates.PaymentMethodsUnconfigured {
+		t.Fatalf("expected the reply stamped with %s, got %+v", templates.PaymentMethodsUnconfigured, resp.Template)
+	}
+	updated, err := ts.leadsRepo.GetByID(ctx, tenancy.ForOrg("org-pay"), lead.ID)
+	if err != nil {
+		t.Fatalf("get lead: %v", err)
+	}
+	if !strings.Contains(updated.SchedulingNotes, "tag:payment_question") {
+		t.Fatalf("expected the lead tagged for staff follow-up, got %q", updated.SchedulingNotes)
+	}
+}
+
+func TestProcessMessage_PaymentFollowUpGoesToLLMWithConfig(t *testing.T) {
+	ts := setupService(t,
+		withLLMResponses("Hello!", "unused", "Cherry plans run 3 to 24 months."),
+		withPaymentMethods(&clinic.PaymentMethods{Financing: []string{"Cherry"}, Insurance: clinic.InsuranceNotAccepted}),
+	)
+	startConv(t, ts, "conv-follow", "org-pay", "Hi")
+
+	askPayment(t, ts, "conv-follow", "", "Do you offer financing?")
+	if len(ts.llm.requests) != 1 {
+		t.Fatalf("expected the first question answered from config, got %d LLM calls", len(ts.llm.requests))
+	}
+	resp := askPayment(t, ts, "conv-follow", "", "Is there a credit check for Cherry?")
+	if len(ts.llm.requests) < 2 {
+		t.Fatalf("expected the follow-up handled by the LLM, got %d LLM calls", len(ts.llm.requests))
+	}
+	if resp.Template.ID != "" {
+		t.Fatalf("LLM reply must not carry a template ref, got %+v", resp.Template)
+	}
+	if reply := ts.llm.requests[1]; !llmRequestMentions(reply, "Financing: Cherry.") || !llmRequestMentions(reply, "Insurance is NOT accepted") {
+		t.Fatal("expected the clinic's payment methods in the prompt")
+	}
+}
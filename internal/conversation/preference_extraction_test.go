@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"github.com/wolfman30/medspa-ai-platform/internal/leads"
+	"github.com/wolfman30/medspa-ai-platform/internal/tenancy"
 )
 
 type mockLeadsRepo struct {
@@ -19,7 +20,7 @@ func (m *mockLeadsRepo) Create(ctx context.Context, req *leads.CreateLeadRequest
 	return nil, nil
 }
 
-func (m *mockLeadsRepo) GetByID(ctx context.Context, orgID string, id string) (*leads.Lead, error) {
+func (m *mockLeadsRepo) GetByID(ctx context.Context, scope tenancy.OrgScope, id string) (*leads.Lead, error) {
 	return nil, nil
 }
 
@@ -27,25 +28,31 @@ func (m *mockLeadsRepo) GetOrCreateByPhone(ctx context.Context, orgID string, ph
 	return nil, nil
 }
 
-func (m *mockLeadsRepo) UpdateSchedulingPreferences(ctx context.Context, leadID string, prefs leads.SchedulingPreferences) error {
+func (m *mockLeadsRepo) UpdateSchedulingPreferences(ctx context.Context, _ tenancy.OrgScope, leadID string, prefs leads.SchedulingPreferences) error {
 	m.savedPrefs = prefs
 	m.savedCount++
 	return nil
 }
 
-func (m *mockLeadsRepo) UpdateDepositStatus(ctx context.Context, leadID string, status string, priority string) error {
+func (m *mockLeadsRepo) MergePreferences(ctx context.Context, leadID string, patch leads.SchedulingPreferences) error {
+	m.savedPrefs = patch
+	m.savedCount++
 	return nil
 }
 
-func (m *mockLeadsRepo) ListByOrg(ctx context.Context, orgID string, filter leads.ListLeadsFilter) ([]*leads.Lead, error) {
+func (m *mockLeadsRepo) UpdateDepositStatus(ctx context.Context, _ tenancy.OrgScope, leadID string, status string, priority string) error {
+	return nil
+}
+
+func (m *mockLeadsRepo) ListByOrg(ctx context.Context, scope tenancy.OrgScope, filter leads.ListLeadsFilter) ([]*leads.Lead, error) {
 	return nil, nil
 }
 
-func (m *mockLeadsRepo) UpdateSelectedAppointment(ctx context.Context, leadID string, appt leads.SelectedAppointment) error {
+func (m *mockLeadsRepo) UpdateSelectedAppointment(ctx context.Context, _ tenancy.OrgScope, leadID string, appt leads.SelectedAppointment) error {
 	return nil
 }
 
-func (m *mockLeadsRepo) UpdateBookingSession(ctx context.Context, leadID string, update leads.BookingSessionUpdate) error {
+func (m *mockLeadsRepo) UpdateBookingSession(ctx context.Context, _ tenancy.OrgScope, leadID string, update leads.BookingSessionUpdate) error {
 	return nil
 }
 
@@ -53,7 +60,7 @@ func (m *mockLeadsRepo) GetByBookingSessionID(ctx context.Context, sessionID str
 	return nil, leads.ErrLeadNotFound
 }
 
-func (m *mockLeadsRepo) UpdateEmail(ctx context.Context, leadID string, email string) error {
+func (m *mockLeadsRepo) UpdateEmail(ctx context.Context, _ tenancy.OrgScope, leadID string, email string) error {
 	m.savedEmail = email
 	m.savedEmailCount++
 	return nil
@@ -252,7 +259,7 @@ func TestExtractAndSavePreferences(t *testing.T) {
 				leadsRepo: mock,
 			}
 
-			err := svc.extractAndSavePreferences(context.Background(), "lead-123", tt.conversation)
+			err := svc.extractAndSavePreferences(context.Background(), nil, "lead-123", tt.conversation)
 			if err != nil {
 				t.Fatalf("unexpected error: %v", err)
 			}
@@ -284,3 +291,46 @@ func TestExtractAndSavePreferences(t *testing.T) {
 		})
 	}
 }
+
+func TestExtractAndSavePreferences_FollowUpKeepsSavedFields(t *testing.T) {
+	ctx := context.Background()
+	repo := leads.NewInMemoryRepository()
+	lead, err := repo.Create(ctx, &leads.CreateLeadRequest{OrgID: "org-1", Name: "Sarah Jones", Email: "sarah@example.com", Phone: "+15550001111"})
+	if err != nil {
+		t.Fatalf("create lead: %v", err)
+	}
+	if err := repo.UpdateSchedulingPreferences(ctx, tenancy.ForOrg(lead.OrgID), lead.ID, leads.SchedulingPreferences{ServiceInterest: "Botox", PatientType: "new"}); err != nil {
+		t.Fatalf("seed preferences: %v", err)
+	}
+	svc := &LLMService{leadsRepo: repo}
+
+	// A later pass only sees the latest exchange.
+	history := []ChatMessage{
+		{Role: ChatRoleAssistant, Content: "What day works best for you?"},
+		{Role: ChatRoleUser, Content: "Friday works"},
+	}
+	if err := svc.extractAndSavePreferences(ctx, nil, lead.ID, history); err != nil {
+		t.Fatalf("save preferences: %v", err)
+	}
+	history = []ChatMessage{
+		{Role: ChatRoleAssistant, Content: "May I have your name?"},
+		{Role: ChatRoleUser, Content: "Sarah"},
+	}
+	if err := svc.extractAndSavePreferences(ctx, nil, lead.ID, history); err != nil {
+		t.Fatalf("save preferences: %v", err)
+	}
+
+	got, err := repo.GetByID(ctx, tenancy.ForOrg("org-1"), lead.ID)
+	if err != nil {
+		t.Fatalf("get lead: %v", err)
+	}
+	if got.Email != "sarah@example.com" || got.ServiceInterest != "Botox" || got.PatientType != "new" {
+		t.Fatalf("saved fields clobbered: email=%q service=%q type=%q", got.Email, got.ServiceInterest, got.PatientType)
+	}
+	if got.Name != "Sarah Jones" {
+		t.Fatalf("name = %q, want the saved full name kept", got.Name)
+	}
+	if !strings.Contains(strings.ToLower(got.PreferredDays), "friday") {
+		t.Fatalf("preferred days = %q, want friday", got.PreferredDays)
+	}
+}
@@ -17,15 +17,26 @@ type TimePreferences struct {
 	AfterTime string `json:"after_time,omitempty"`
 	// BeforeTime is the latest acceptable time in 24-hour format (e.g., "12:00" for noon)
 	BeforeTime string `json:"before_time,omitempty"`
+	// DateFrom is the earliest acceptable calendar date (inclusive), e.g. from "week of March 10"
+	DateFrom *time.Time `json:"date_from,omitempty"`
+	// DateTo is the latest acceptable calendar date (inclusive)
+	DateTo *time.Time `json:"date_to,omitempty"`
 	// RawText is the original natural language input
 	RawText string `json:"raw_text,omitempty"`
 }
 
+// HasDateRange reports whether the preferences restrict slots to a date range.
+func (p TimePreferences) HasDateRange() bool {
+	return p.DateFrom != nil || p.DateTo != nil
+}
+
 // ExtractTimePreferences parses natural language scheduling preferences.
 // Examples:
 //   - "Mondays or Thursdays after 4pm" → {DaysOfWeek: [1,4], AfterTime: "16:00"}
 //   - "Weekdays before noon" → {DaysOfWeek: [1,2,3,4,5], BeforeTime: "12:00"}
 //   - "Mornings on Tuesdays and Fridays" → {DaysOfWeek: [2,5], BeforeTime: "12:00"}
+//   - "Week of March 10 after 4pm" → {DateFrom: Mar 10, DateTo: Mar 16, AfterTime: "16:00"}
+//   - "Tomorrow afternoon" → {DateFrom: tomorrow, DateTo: tomorrow, AfterTime: "12:00"}
 //
 // normalizeNumberWords replaces written-out numbers with digits for time parsing.
 func normalizeNumberWords(text string) string {
@@ -41,12 +52,26 @@ func normalizeNumberWords(text string) string {
 }
 
 func ExtractTimePreferences(text string) TimePreferences {
+	return extractTimePreferencesAt(text, time.Now())
+}
+
+// extractTimePreferencesAt is ExtractTimePreferences with an explicit clock
+// for resolving date ranges.
+func extractTimePreferencesAt(text string, now time.Time) TimePreferences {
 	text = strings.ToLower(text)
 	text = normalizeNumberWords(text)
 	prefs := TimePreferences{
 		RawText: text,
 	}
 
+	// Date ranges ("week of march 10", "early april", "after the 20th") are
+	// removed before day/time parsing so "early"/"late" aren't read as times.
+	if r, ok := extractDateRange(text, now); ok {
+		prefs.DateFrom = r.From
+		prefs.DateTo = r.To
+		text = stripDateRange(text, r)
+	}
+
 	// Extract days of week (handles ranges like "tuesday-thursday" → Tue, Wed, Thu)
 	prefs.DaysOfWeek = extractDaysOfWeek(text)
 
@@ -60,6 +85,12 @@ func ExtractTimePreferences(text string) TimePreferences {
 		prefs.BeforeTime = extractBeforeTime(text)
 	}
 
+	// A date range the requested weekdays never fall in (e.g. "march 10-11,
+	// fridays") can't be satisfied; the explicit dates win.
+	if len(prefs.DaysOfWeek) > 0 && !rangeIncludesWeekday(prefs.DateFrom, prefs.DateTo, prefs.DaysOfWeek) {
+		prefs.DaysOfWeek = nil
+	}
+
 	return prefs
 }
 
@@ -404,12 +435,21 @@ func padZero(n int) string {
 
 // FormatPreferencesForLLM converts preferences to human-readable text for the LLM.
 func FormatPreferencesForLLM(prefs TimePreferences) string {
-	if prefs.RawText == "" && len(prefs.DaysOfWeek) == 0 && prefs.AfterTime == "" && prefs.BeforeTime == "" {
+	if prefs.RawText == "" && len(prefs.DaysOfWeek) == 0 && prefs.AfterTime == "" && prefs.BeforeTime == "" && !prefs.HasDateRange() {
 		return "any day/time"
 	}
 
 	var parts []string
 
+	switch {
+	case prefs.DateFrom != nil && prefs.DateTo != nil:
+		parts = append(parts, prefs.DateFrom.Format("Jan 2")+"-"+prefs.DateTo.Format("Jan 2"))
+	case prefs.DateFrom != nil:
+		parts = append(parts, "from "+prefs.DateFrom.Format("Jan 2"))
+	case prefs.DateTo != nil:
+		parts = append(parts, "through "+prefs.DateTo.Format("Jan 2"))
+	}
+
 	if len(prefs.DaysOfWeek) > 0 {
 		dayNames := make([]string, 0, len(prefs.DaysOfWeek))
 		dayMap := []string{"Sunday", "Monday", "Tuesday", "Wednesday", "Thursday", "Friday", "Saturday"}
@@ -467,21 +507,25 @@ func formatDisplayTime(time24 string) string {
 var emailPattern = regexp.MustCompile(`[a-zA-Z0-9._%+\-]+@[a-zA-Z0-9.\-]+\.[a-zA-Z]{2,}`)
 
 // ExtractEmail extracts an email address from text.
-// Returns the first valid email found, or empty string if none found.
+// Returns the first usable email found: one that validates and has no likely
+// typo awaiting confirmation. Returns empty string if none found.
 func ExtractEmail(text string) string {
-	match := emailPattern.FindString(text)
-	if match != "" {
-		return strings.ToLower(match)
+	for _, match := range emailPattern.FindAllString(text, -1) {
+		email := strings.ToLower(match)
+		if ValidateEmail(email) && SuggestEmailCorrection(email) == "" {
+			return email
+		}
 	}
 	return ""
 }
 
 // ExtractEmailFromHistory scans conversation history for an email address.
-// Returns the first valid email found in user messages.
+// Returns the most recent usable email found in user messages, so a
+// correction wins over the address it replaced.
 func ExtractEmailFromHistory(history []ChatMessage) string {
-	for _, msg := range history {
-		if msg.Role == ChatRoleUser {
-			if email := ExtractEmail(msg.Content); email != "" {
+	for i := len(history) - 1; i >= 0; i-- {
+		if history[i].FromPatient() {
+			if email := ExtractEmail(history[i].Content); email != "" {
 				return email
 			}
 		}
@@ -0,0 +1,51 @@
+package conversation
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/clinic"
+)
+
+// noPriceReplyTemplate answers a price question for a service the clinic
+// offers but hasn't priced, so the LLM never guesses a number.
+const noPriceReplyTemplate = "Great question! Pricing for %s is personalized — the provider will confirm at your visit. Would you like to schedule a time to come in?"
+
+// pricedServiceForQuestion returns the service a message asks the price of,
+// or "" when the message isn't a price question about a recognized service.
+func pricedServiceForQuestion(message string, cfg *clinic.Config) string {
+	if cfg == nil || !isPriceInquiry(message) {
+		return ""
+	}
+	return detectServiceKey(message, cfg)
+}
+
+// buildPriceContext tells the LLM the configured price for the service the
+// patient asked about. It returns "" unless the message is a price question,
+// so prices aren't sent with every turn.
+func buildPriceContext(cfg *clinic.Config, query string) string {
+	service := pricedServiceForQuestion(query, cfg)
+	if service == "" {
+		return ""
+	}
+	name := serviceDisplayName(cfg, service)
+	if price, ok := cfg.PriceTextForService(service); ok {
+		return fmt.Sprintf("PRICING: %s at this clinic is %s. Quote this exactly; never give any other number or range.", name, price)
+	}
+	if !clinicHasService(cfg, service) {
+		return ""
+	}
+	return fmt.Sprintf("PRICING: No price is configured for %s. Do NOT guess a price; say pricing is personalized and the provider will confirm at their visit.", name)
+}
+
+// serviceDisplayName returns the clinic's own spelling of a service key.
+func serviceDisplayName(cfg *clinic.Config, service string) string {
+	if cfg != nil {
+		for _, svc := range cfg.Services {
+			if strings.EqualFold(svc, service) {
+				return svc
+			}
+		}
+	}
+	return strings.Title(service) //nolint:staticcheck
+}
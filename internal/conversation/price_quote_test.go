@@ -0,0 +1,128 @@
+package conversation
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/clinic"
+)
+
+func priceTestConfig() *clinic.Config {
+	cfg := clinic.DefaultConfig("org-price")
+	cfg.Services = []string{"Botox", "HydraFacial"}
+	cfg.ServicePriceText = map[string]string{"botox": "$12/unit"}
+	return cfg
+}
+
+func TestPricedServiceForQuestion(t *testing.T) {
+	cfg := priceTestConfig()
+	tests := []struct {
+		message string
+		want    string
+	}{
+		{"How much is Botox?", "botox"},
+		{"what does a hydrafacial cost", "hydrafacial"},
+		{"How much is it?", ""},
+		{"I want Botox on Friday", ""},
+		{"do you take insurance for botox", ""},
+	}
+	for _, tt := range tests {
+		if got := pricedServiceForQuestion(tt.message, cfg); got != tt.want {
+			t.Errorf("pricedServiceForQuestion(%q) = %q, want %q", tt.message, got, tt.want)
+		}
+	}
+	if got := pricedServiceForQuestion("How much is Botox?", nil); got != "" {
+		t.Errorf("expected no service without a config, got %q", got)
+	}
+}
+
+func TestBuildPriceContext(t *testing.T) {
+	cfg := priceTestConfig()
+
+	if got := buildPriceContext(cfg, "How much is Botox and can I book Friday?"); !strings.Contains(got, "Botox at this clinic is $12/unit") {
+		t.Fatalf("expected the Botox price, got %q", got)
+	}
+	if got := buildPriceContext(cfg, "what's the price of a hydrafacial"); !strings.Contains(got, "No price is configured for HydraFacial") {
+		t.Fatalf("expected a no-guessing instruction, got %q", got)
+	}
+	// Prices stay out of the prompt unless the patient asks about cost.
+	for _, query := range []string{"I want Botox on Friday", "How much is a consultation?", ""} {
+		if got := buildPriceContext(cfg, query); got != "" {
+			t.Fatalf("expected no price context for %q, got %q", query, got)
+		}
+	}
+}
+
+func TestProcessMessage_PriceInquiryWithoutConfiguredPrice(t *testing.T) {
+	ts := setupService(t,
+		withLLMResponses("Hello!", "LLM should not handle this"),
+		withClinicConfig("org-price", func(cfg *clinic.Config) {
+			cfg.Services = []string{"Botox", "HydraFacial"}
+			cfg.ServicePriceText = map[string]string{"botox": "$12/unit"}
+		}),
+	)
+	startConv(t, ts, "conv-unpriced", "org-price", "Hi")
+
+	resp, err := ts.svc.ProcessMessage(context.Background(), MessageRequest{
+		ConversationID: "conv-unpriced",
+		OrgID:          "org-price",
+		Message:        "How much is a HydraFacial?",
+		Channel:        ChannelSMS,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(resp.Message, "Pricing for HydraFacial is personalized — the provider will confirm at your visit") {
+		t.Fatalf("expected the no-price fallback, got %q", resp.Message)
+	}
+	if strings.Contains(resp.Message, "$") {
+		t.Fatalf("expected no dollar amount in the fallback, got %q", resp.Message)
+	}
+	if len(ts.llm.requests) != 1 {
+		t.Fatalf("expected 1 LLM call (start only), got %d", len(ts.llm.requests))
+	}
+}
+
+func TestProcessMessage_PriceWithBookingRequestInjectsPrice(t *testing.T) {
+	ts := setupService(t,
+		withLLMResponses("Hello!", "Botox is $12/unit. What day works?"),
+		withClinicConfig("org-price", func(cfg *clinic.Config) {
+			cfg.Services = []string{"Botox"}
+			cfg.ServicePriceText = map[string]string{"botox": "$12/unit"}
+		}),
+	)
+	startConv(t, ts, "conv-price-book", "org-price", "Hi")
+
+	if _, err := ts.svc.ProcessMessage(context.Background(), MessageRequest{
+		ConversationID: "conv-price-book",
+		OrgID:          "org-price",
+		Message:        "How much is Botox? I'd like to book an appointment",
+		Channel:        ChannelSMS,
+	}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(ts.llm.requests) != 2 {
+		t.Fatalf("expected the LLM to handle a mixed price and booking message, got %d calls", len(ts.llm.requests))
+	}
+	if !llmRequestMentions(ts.llm.lastReq, "Botox at this clinic is $12/unit") {
+		t.Fatal("expected the configured price in the prompt")
+	}
+	if llmRequestMentions(ts.llm.requests[0], "PRICING:") {
+		t.Fatal("expected no price context on turns that don't ask about cost")
+	}
+}
+
+func llmRequestMentions(req LLMRequest, text string) bool {
+	for _, sys := range req.System {
+		if strings.Contains(sys, text) {
+			return true
+		}
+	}
+	for _, msg := range req.Messages {
+		if strings.Contains(msg.Content, text) {
+			return true
+		}
+	}
+	return false
+}
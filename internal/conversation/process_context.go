@@ -19,6 +19,7 @@ type processContext struct {
 	span       trace.Span
 
 	// Inbound filter results
+	inbound         InboundText
 	filter          FilterResult
 	redactedMessage string
 	sawPHI          bool
@@ -28,6 +29,9 @@ type processContext struct {
 	history            []ChatMessage
 	cfg                *clinic.Config
 	timeSelectionState *TimeSelectionState
+	// existingAppointment is set while the patient is asking about an
+	// appointment they already have; qualification is suppressed.
+	existingAppointment bool
 
 	// Outputs built during processing
 	timeSelectionResponse *TimeSelectionResponse
@@ -41,16 +45,29 @@ type processContext struct {
 // newProcessContext initialises a processContext from a MessageRequest,
 // running the inbound filter and prompt-injection scan.
 func (s *LLMService) newProcessContext(ctx context.Context, req MessageRequest) (*processContext, *Response) {
+	inbound := SanitizeInbound(req.Message, s.maxInboundChars)
+	req.Message = inbound.Text
 	filter := FilterInbound(req.Message)
 	rawMessage := req.Message
 
 	s.events.MessageReceived(ctx, req.ConversationID, req.OrgID, req.LeadID, rawMessage)
+	if inbound.RoleMarkers {
+		s.log(ctx).Warn("ProcessMessage: role markers stripped from inbound message",
+			"conversation_id", req.ConversationID,
+			"org_id", req.OrgID,
+		)
+	}
+
+	// Card numbers and SSNs — answered without the LLM or history
+	if len(inbound.Sensitive) > 0 {
+		return nil, s.sensitiveDataResponse(ctx, req.OrgID, req.ConversationID, req.LeadID, inbound.Sensitive)
+	}
 
 	// Prompt injection — hard block
 	if filter.DeflectionMsg == blockedReply {
 		injectionResult := ScanForPromptInjection(rawMessage)
 		s.events.PromptInjectionDetected(ctx, req.ConversationID, req.OrgID, true, injectionResult.Score, injectionResult.Reasons)
-		s.logger.Warn("ProcessMessage: prompt injection BLOCKED",
+		s.log(ctx).Warn("ProcessMessage: prompt injection BLOCKED",
 			"conversation_id", req.ConversationID,
 			"org_id", req.OrgID,
 			"score", injectionResult.Score,
@@ -65,7 +82,7 @@ func (s *LLMService) newProcessContext(ctx context.Context, req MessageRequest)
 	// Prompt injection — soft warning (sanitize)
 	if filter.Sanitized != rawMessage {
 		injectionResult := ScanForPromptInjection(rawMessage)
-		s.logger.Warn("ProcessMessage: prompt injection WARNING",
+		s.log(ctx).Warn("ProcessMessage: prompt injection WARNING",
 			"conversation_id", req.ConversationID,
 			"org_id", req.OrgID,
 			"score", injectionResult.Score,
@@ -77,6 +94,7 @@ func (s *LLMService) newProcessContext(ctx context.Context, req MessageRequest)
 	return &processContext{
 		req:             req,
 		rawMessage:      rawMessage,
+		inbound:         inbound,
 		filter:          filter,
 		redactedMessage: filter.RedactedMsg,
 		sawPHI:          filter.SawPHI,
@@ -97,7 +115,7 @@ func (s *LLMService) loadHistory(ctx context.Context, pc *processContext) (*Resp
 
 	if err != nil {
 		if strings.Contains(err.Error(), "unknown conversation") {
-			s.logger.Info("ProcessMessage: conversation not found, starting new",
+			s.log(ctx).Info("ProcessMessage: conversation not found, starting new",
 				"conversation_id", pc.req.ConversationID,
 				"message", pc.redactedMessage,
 			)
@@ -112,7 +130,7 @@ func (s *LLMService) loadHistory(ctx context.Context, pc *processContext) (*Resp
 	// Count non-system user messages to enforce conversation length limit.
 	userMsgCount := 0
 	for _, m := range history {
-		if m.Role == ChatRoleUser {
+		if m.FromPatient() {
 			userMsgCount++
 		}
 	}
@@ -128,7 +146,7 @@ func (s *LLMService) loadHistory(ctx context.Context, pc *processContext) (*Resp
 			msg += " at " + phone
 		}
 		msg += " and our team will be happy to help."
-		s.logger.Warn("conversation message limit reached",
+		s.log(ctx).Warn("conversation message limit reached",
 			"conversation_id", pc.req.ConversationID,
 			"user_messages", userMsgCount,
 			"limit", maxConversationMessages,
@@ -140,7 +158,7 @@ func (s *LLMService) loadHistory(ctx context.Context, pc *processContext) (*Resp
 		}, nil
 	}
 
-	s.logger.Info("ProcessMessage: history loaded",
+	s.log(ctx).Info("ProcessMessage: history loaded",
 		"conversation_id", pc.req.ConversationID,
 		"history_length", len(history),
 	)
@@ -154,6 +172,27 @@ func (s *LLMService) saveAndReturn(ctx context.Context, pc *processContext, repl
 	if err := s.history.Save(ctx, pc.req.ConversationID, pc.history); err != nil {
 		pc.span.RecordError(err)
 	}
-	s.savePreferencesNoNote(ctx, pc.req.LeadID, pc.history, reason)
+	s.savePreferencesNoNote(ctx, pc.cfg, pc.req.LeadID, pc.history, reason)
 	return &Response{ConversationID: pc.req.ConversationID, Message: reply, Timestamp: time.Now().UTC()}
 }
+
+// handleInboundLimits answers over-long, link-only, and media-only messages
+// with a targeted reply instead of calling the LLM. Truncation is noted in the
+// recorded user turn.
+func (s *LLMService) handleInboundLimits(ctx context.Context, pc *processContext) *Response {
+	reply := pc.inbound.CannedReply()
+	if reply == "" {
+		return nil
+	}
+	s.log(ctx).Info("ProcessMessage: inbound message not sent to LLM",
+		"conversation_id", pc.req.ConversationID,
+		"truncated", pc.inbound.Truncated,
+		"original_chars", pc.inbound.OriginalChars,
+		"link_only", pc.inbound.LinkOnly,
+		"media_only", pc.inbound.MediaOnly,
+	)
+	noted := pc.inbound
+	noted.Text = pc.rawMessage
+	pc.history = append(pc.history, ChatMessage{Role: ChatRoleUser, Content: noted.HistoryText()})
+	return s.saveAndReturn(ctx, pc, reply, "inbound_limits")
+}
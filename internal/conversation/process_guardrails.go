@@ -6,14 +6,29 @@ import (
 	"strings"
 )
 
-// handleDeterministicGuardrails checks for price inquiries, question selection,
-// and ambiguous help — deterministic replies that skip the LLM.
+// handleDeterministicGuardrails checks for not-offered and consult-first
+// services, payment-method and price inquiries, question selection, and
+// ambiguous help — deterministic replies that skip the LLM.
 func (s *LLMService) handleDeterministicGuardrails(ctx context.Context, pc *processContext) *Response {
+	if resp := s.handleNotOfferedService(ctx, pc); resp != nil {
+		return resp
+	}
+	if resp := s.handleConsultRequiredService(ctx, pc); resp != nil {
+		return resp
+	}
+	if resp := s.handlePaymentMethodQuestion(ctx, pc); resp != nil {
+		return resp
+	}
 	if pc.cfg != nil && isPriceInquiry(pc.rawMessage) {
 		if resp := s.handlePriceInquiry(ctx, pc); resp != nil {
 			return resp
 		}
 	}
+	if isStatusKeyword(pc.rawMessage) {
+		if resp := s.handleStatusKeyword(ctx, pc); resp != nil {
+			return resp
+		}
+	}
 	if isQuestionSelection(pc.rawMessage) {
 		return s.saveAndReturn(ctx, pc, "Absolutely - what can I help with? If it's about a specific service (Botox, fillers, facials, lasers), let me know which one.", "question_selection")
 	}
@@ -24,26 +39,29 @@ func (s *LLMService) handleDeterministicGuardrails(ctx context.Context, pc *proc
 	return nil
 }
 
-// handlePriceInquiry handles deterministic price responses from clinic config.
+// handlePriceInquiry answers plain price questions from clinic config. Price
+// questions mixed with booking requests or payment-method questions go to
+// the LLM, which gets the price through buildPriceContext.
 func (s *LLMService) handlePriceInquiry(ctx context.Context, pc *processContext) *Response {
-	service := detectServiceKey(pc.rawMessage, pc.cfg)
-	if service == "" {
+	service := pricedServiceForQuestion(pc.rawMessage, pc.cfg)
+	if service == "" || containsBookingIntent(pc.rawMessage) || isPaymentMethodQuestion(pc.rawMessage) {
 		return nil
 	}
+	displayName := serviceDisplayName(pc.cfg, service)
 	price, ok := pc.cfg.PriceTextForService(service)
 	if !ok {
-		return nil
+		if !clinicHasService(pc.cfg, service) {
+			return nil
+		}
+		s.appendLeadNote(ctx, pc.req.OrgID, pc.req.LeadID, "tag:price_shopper")
+		return s.saveAndReturn(ctx, pc, fmt.Sprintf(noPriceReplyTemplate, displayName), "price_inquiry_unpriced")
 	}
 	depositCents := pc.cfg.DepositAmountForService(service)
 	depositDollars := float64(depositCents) / 100.0
-	displayName := strings.Title(service) //nolint:staticcheck
-	for _, svc := range pc.cfg.Services {
-		if strings.EqualFold(svc, service) {
-			displayName = svc
-			break
-		}
-	}
 	reply := fmt.Sprintf("%s pricing: %s. To secure priority booking, we collect a small refundable deposit of $%.0f that applies toward your treatment. Would you like to proceed?", displayName, price, depositDollars)
+	if !pc.cfg.DepositRequiredForService(service) {
+		reply = fmt.Sprintf("%s pricing: %s. No deposit is needed to book. Would you like to schedule?", displayName, price)
+	}
 	s.appendLeadNote(ctx, pc.req.OrgID, pc.req.LeadID, "tag:price_shopper")
 	return s.saveAndReturn(ctx, pc, reply, "price_inquiry")
 }
@@ -56,7 +74,7 @@ func (s *LLMService) handleFAQClassification(ctx context.Context, pc *processCon
 	if len(msgPreview) > 50 {
 		msgPreview = msgPreview[:50] + "..."
 	}
-	s.logger.Info("FAQ classifier check", "is_comparison_question", isComparison, "message_preview", msgPreview)
+	s.log(ctx).Info("FAQ classifier check", "is_comparison_question", isComparison, "message_preview", msgPreview)
 	if !isComparison {
 		return nil
 	}
@@ -67,12 +85,12 @@ func (s *LLMService) handleFAQClassification(ctx context.Context, pc *processCon
 	// Try LLM classifier first (more accurate)
 	if s.faqClassifier != nil {
 		category, classifyErr := s.faqClassifier.ClassifyQuestion(ctx, pc.rawMessage)
-		s.logger.Info("FAQ LLM classifier result", "category", category, "error", classifyErr)
+		s.log(ctx).Info("FAQ LLM classifier result", "category", category, "error", classifyErr)
 		if classifyErr == nil && category != FAQCategoryOther {
 			faqReply = GetFAQResponse(category)
 			faqSource = "llm_classifier"
 		} else if classifyErr != nil {
-			s.logger.Warn("FAQ LLM classification failed, trying regex fallback", "error", classifyErr)
+			s.log(ctx).Warn("FAQ LLM classification failed, trying regex fallback", "error", classifyErr)
 		}
 	}
 
@@ -81,16 +99,16 @@ func (s *LLMService) handleFAQClassification(ctx context.Context, pc *processCon
 		if regexReply, found := CheckFAQCache(pc.rawMessage); found {
 			faqReply = regexReply
 			faqSource = "regex_fallback"
-			s.logger.Info("FAQ regex fallback hit", "conversation_id", pc.req.ConversationID)
+			s.log(ctx).Info("FAQ regex fallback hit", "conversation_id", pc.req.ConversationID)
 		}
 	}
 
 	if faqReply == "" {
-		s.logger.Info("FAQ: no match from classifier or regex, falling through to full LLM")
+		s.log(ctx).Info("FAQ: no match from classifier or regex, falling through to full LLM")
 		return nil
 	}
 
-	s.logger.Info("FAQ response returned", "source", faqSource, "conversation_id", pc.req.ConversationID)
+	s.log(ctx).Info("FAQ response returned", "source", faqSource, "conversation_id", pc.req.ConversationID)
 	return s.saveAndReturn(ctx, pc, faqReply, "faq_response")
 }
 
@@ -102,7 +120,7 @@ func (s *LLMService) injectMoxieQualificationGuardrails(ctx context.Context, pc
 		return
 	}
 
-	prefs, _ := extractPreferences(pc.history, serviceAliasesFromConfig(pc.cfg))
+	prefs, _ := extractClinicPreferences(pc.history, pc.cfg)
 
 	// Pre-fetch availability as soon as we know the service.
 	if prefs.ServiceInterest != "" && s.prefetcher != nil {
@@ -230,7 +248,7 @@ func assistantConfirmedName(msg string) bool {
 func lastUserConfirmedName(history []ChatMessage) bool {
 	// Find the last assistant message that confirmed a name, then check the user reply
 	for i := len(history) - 1; i >= 0; i-- {
-		if history[i].Role == ChatRoleUser {
+		if history[i].FromPatient() {
 			lower := strings.ToLower(strings.TrimSpace(history[i].Content))
 			// Check if this is an affirmative reply
 			affirmatives := []string{
@@ -2,13 +2,14 @@ package conversation
 
 import (
 	"context"
-	"fmt"
+	"errors"
 	"strings"
 	"time"
 
 	"github.com/wolfman30/medspa-ai-platform/internal/clinic"
 	boulevard "github.com/wolfman30/medspa-ai-platform/internal/emr/boulevard"
 	"github.com/wolfman30/medspa-ai-platform/internal/leads"
+	"github.com/wolfman30/medspa-ai-platform/internal/messaging/templates"
 )
 
 // handleVariantResolution checks whether the patient's chosen service has delivery
@@ -27,7 +28,7 @@ func (s *LLMService) handleVariantResolution(
 	resolved, question := s.variantResolver.Resolve(ctx, cfg, prefs.ServiceInterest, msgs)
 	if question != "" {
 		s.events.VariantAsked(ctx, conversationID, orgID, prefs.ServiceInterest, nil)
-		s.logger.Info("service variant clarification needed",
+		s.log(ctx).Info("service variant clarification needed",
 			"conversation_id", conversationID,
 			"service", prefs.ServiceInterest,
 		)
@@ -39,7 +40,7 @@ func (s *LLMService) handleVariantResolution(
 			}
 		}
 		if err := s.history.Save(ctx, conversationID, history); err != nil {
-			s.logger.Warn("failed to save history after variant question", "error", err)
+			s.log(ctx).Warn("failed to save history after variant question", "error", err)
 		}
 		return &Response{
 			ConversationID: conversationID,
@@ -49,7 +50,7 @@ func (s *LLMService) handleVariantResolution(
 	}
 	if resolved != prefs.ServiceInterest {
 		s.events.VariantResolved(ctx, conversationID, orgID, prefs.ServiceInterest, resolved, "auto")
-		s.logger.Info("service variant resolved",
+		s.log(ctx).Info("service variant resolved",
 			"conversation_id", conversationID,
 			"original_service", prefs.ServiceInterest,
 			"resolved_variant", resolved,
@@ -72,8 +73,7 @@ func (s *LLMService) handleProviderPreference(
 		return nil
 	}
 	providerNames := cfg.ProviderNamesForService(resolved)
-	providerList := buildProviderGuardrailList(cfg, resolved)
-	// Rewrite for patient-facing tone if we have names.
+	providerList := ""
 	if len(providerNames) > 0 {
 		providerList = " We have " + strings.Join(providerNames, ", ") + "."
 	}
@@ -84,32 +84,60 @@ func (s *LLMService) handleProviderPreference(
 		"provider_count", len(providerNames),
 		"providers", strings.Join(providerNames, ", "),
 	)
+	question := cfg.RenderTemplate(templates.ReplyProviderPreference, templates.Params{"service": resolved, "providers": providerList})
 	return &Response{
-		Message:        fmt.Sprintf("Great choice — %s!%s Do you have a provider preference, or would you like the first available appointment?", resolved, providerList),
+		Message:        question.Body,
+		Template:       question.Ref,
 		ConversationID: conversationID,
 		Timestamp:      time.Now().UTC(),
 	}
 }
 
-// fetchAndPresentAvailability fetches real-time availability from the Moxie API or
-// browser scraper, saves the resulting time selection state, and returns a
-// TimeSelectionResponse ready to send to the patient. Returns nil when no booking URL
-// is configured or the fetch otherwise cannot proceed.
+// fetchAndPresentAvailability fetches availability and, when there are slots,
+// saves the time selection state so the patient's next reply picks from them.
+// Callers must send the returned TimeSelectionResponse to the patient.
 func (s *LLMService) fetchAndPresentAvailability(
 	ctx context.Context,
 	prefs *leads.SchedulingPreferences,
 	cfg *clinic.Config,
-	bookingURL, conversationID, orgID string,
+	bookingURL, conversationID, orgID, leadID, leadPhone string,
 	onProgress func(ctx context.Context, msg string),
 ) *TimeSelectionResponse {
-	timePrefs := ExtractTimePreferences(prefs.PreferredDays + " " + prefs.PreferredTimes)
+	tsr := s.fetchAvailability(ctx, prefs, cfg, bookingURL, conversationID, orgID, leadID, leadPhone, onProgress)
+	if tsr != nil && len(tsr.Slots) > 0 {
+		s.saveTimeSelection(ctx, tsr, prefs, conversationID, orgID, bookingURL)
+	}
+	return tsr
+}
+
+// fetchAvailability fetches real-time availability from the Moxie API or
+// browser scraper and returns a TimeSelectionResponse without saving any
+// time selection state. Returns nil when the fetch cannot proceed.
+func (s *LLMService) fetchAvailability(
+	ctx context.Context,
+	prefs *leads.SchedulingPreferences,
+	cfg *clinic.Config,
+	bookingURL, conversationID, orgID, leadID, leadPhone string,
+	onProgress func(ctx context.Context, msg string),
+) *TimeSelectionResponse {
+	s.publishLeadQualified(ctx, prefs, conversationID, orgID, leadID, leadPhone)
+	timePrefs := clinicTimePreferences(prefs.PreferredDays+" "+prefs.PreferredTimes, cfg)
 
 	// Resolve patient-facing service name to booking-platform search term.
+	// A consult-first service (e.g., "Radiesse") is booked as its
+	// consultation; the patient's interest stays on prefs.ServiceInterest.
 	// For concern-based categories (e.g., "wrinkle relaxer"), resolve to the
 	// actual bookable service (e.g., "Botox") and capture the provider note.
-	scraperServiceName := prefs.ServiceInterest
+	bookingService := prefs.ServiceInterest
+	if cfg != nil {
+		bookingService = cfg.BookingServiceFor(prefs.ServiceInterest)
+		if note := consultBookingNote(cfg, prefs.ServiceInterest); note != "" && !strings.Contains(prefs.Notes, note) {
+			prefs.Notes = joinNotes(prefs.Notes, note)
+		}
+	}
+	scraperServiceName := bookingService
 	var concernNote string
-	if bookingSvc, note := ResolveConcernToBookingService(prefs.ServiceInterest); bookingSvc != "" {
+	if bookingSvc, note := ResolveConcernToBookingService(bookingService); bookingSvc != "" {
 		scraperServiceName = bookingSvc
 		concernNote = note
 		// Store concern note in scheduling notes for the lead/operator
@@ -124,7 +152,7 @@ func (s *LLMService) fetchAndPresentAvailability(
 
 	s.events.ServiceExtracted(ctx, conversationID, orgID, prefs.ServiceInterest, scraperServiceName)
 
-	s.logger.Info("fetching available times",
+	s.log(ctx).Info("fetching available times",
 		"conversation_id", conversationID,
 		"original_service", prefs.ServiceInterest,
 		"resolved_service", scraperServiceName,
@@ -136,25 +164,29 @@ func (s *LLMService) fetchAndPresentAvailability(
 	// Check pre-fetch cache first — availability may have been fetched while
 	// collecting name/patient type/schedule qualifications.
 	if s.prefetcher != nil {
-		if cached := s.prefetcher.GetCached(ctx, orgID, prefs.ServiceInterest, cfg); cached != nil {
-			s.logger.Info("using pre-fetched availability (cache hit)",
+		if cached := s.prefetcher.GetCached(ctx, orgID, bookingService, cfg); cached != nil {
+			s.log(ctx).Info("using pre-fetched availability (cache hit)",
 				"conversation_id", conversationID,
 				"service", scraperServiceName,
 				"slots", len(cached.Result.Slots),
 				"age_seconds", int(time.Since(cached.FetchedAt).Seconds()),
 			)
-			// Apply time preference filter to cached results.
+			// Apply time preference filter to cached results. The cache is
+			// shared by the clinic's leads, so this lead's bookings are
+			// filtered here too.
 			filtered := filterSlotsByTimePrefs(cached.Result.Slots, &timePrefs)
+			filtered = s.filterLeadBookingConflicts(ctx, cfg, orgID, leadID, scraperServiceName, prefs.BackToBack, filtered)
 			if len(filtered) > 0 {
 				result := &AvailabilityResult{
 					Slots:      filtered,
 					ExactMatch: cached.Result.ExactMatch,
 					Message:    cached.Result.Message,
+					Template:   cached.Result.Template,
 				}
-				return s.buildTimeSelectionResponse(ctx, result, prefs, conversationID, orgID, bookingURL)
+				return buildTimeSelectionResponse(result, bookingService, leadPhone)
 			}
 			// Cache had slots but none match time prefs — fall through to fresh fetch.
-			s.logger.Info("pre-fetched slots don't match time preferences, fetching fresh",
+			s.log(ctx).Info("pre-fetched slots don't match time preferences, fetching fresh",
 				"conversation_id", conversationID)
 		}
 	}
@@ -172,19 +204,19 @@ func (s *LLMService) fetchAndPresentAvailability(
 			clinicClient := boulevard.NewBoulevardClient(cfg.BoulevardBusinessID, cfg.BoulevardLocationID, s.logger)
 			adapter = boulevard.NewBoulevardAdapter(clinicClient, adapter.IsDryRun(), s.logger)
 		}
-		s.logger.Info("fetching availability via Boulevard API",
+		s.log(ctx).Info("fetching availability via Boulevard API",
 			"conversation_id", conversationID, "service", scraperServiceName, "dry_run", adapter.IsDryRun())
 
-		blvdSlots, blvdCartID, blvdErr := adapter.ResolveAvailabilityWithCart(fetchCtx, scraperServiceName, prefs.ProviderPreference, time.Now())
+		blvdSlots, blvdCartID, blvdErr := adapter.ResolveAvailabilityWithCart(fetchCtx, scraperServiceName, prefs.ProviderPreference, clinicNow(cfg))
 		if blvdErr != nil {
-			s.logger.Warn("Boulevard API: availability fetch failed",
+			s.log(ctx).Warn("Boulevard API: availability fetch failed",
 				"error", blvdErr, "conversation_id", conversationID, "service", scraperServiceName)
 			err = blvdErr
 		} else {
 			// Store Boulevard cart ID for later booking
 			if blvdCartID != "" {
 				if saveErr := s.history.SaveBoulevardCartID(ctx, conversationID, blvdCartID); saveErr != nil {
-					s.logger.Warn("failed to save Boulevard cart ID", "error", saveErr)
+					s.log(ctx).Warn("failed to save Boulevard cart ID", "error", saveErr)
 				}
 			}
 			// Convert Boulevard slots to AvailabilityResult.
@@ -195,28 +227,47 @@ func (s *LLMService) fetchAndPresentAvailability(
 			prefSlots := make([]PresentedSlot, 0, len(blvdSlots))
 			idx := 1
 			prefIdx := 1
+			blackoutProviderID := cfg.ProviderIDForName(prefs.ProviderPreference)
+			loc := clinicNow(cfg).Location()
+			duration := cfg.DurationForService(scraperServiceName)
 			for _, bs := range blvdSlots {
+				// Boulevard returns fixed-offset times; hours, preferences, and
+				// display all work in clinic-local time.
+				bs.StartAt = bs.StartAt.In(loc)
 				// Validate against business hours — reject slots outside operating hours
 				if cfg != nil && !isWithinBusinessHours(bs.StartAt, cfg.BusinessHours) {
-					s.logger.Info("Boulevard: slot outside business hours, skipping",
+					s.log(ctx).Info("Boulevard: slot outside business hours, skipping",
 						"slot", bs.StartAt.Format(time.RFC3339), "conversation_id", conversationID)
 					continue
 				}
+				if cfg.IsBlackedOut(bs.StartAt, blackoutProviderID) {
+					continue
+				}
+				// A treatment that would run past closing isn't really open.
+				if !cfg.EndsBeforeClose(bs.StartAt, duration) {
+					continue
+				}
+				var endAt time.Time
+				if duration > 0 {
+					endAt = bs.StartAt.Add(duration)
+				}
 				validSlots = append(validSlots, PresentedSlot{
-					Index:     idx,
-					DateTime:  bs.StartAt,
-					TimeStr:   bs.StartAt.Format("Mon Jan 2 at 3:04 PM"),
-					Service:   prefs.ServiceInterest,
-					Available: true,
+					Index:       idx,
+					DateTime:    bs.StartAt,
+					EndDateTime: endAt,
+					TimeStr:     bs.StartAt.Format("Mon Jan 2 at 3:04 PM"),
+					Service:     bookingService,
+					Available:   true,
 				})
 				idx++
 				if matchesTimePreferences(bs.StartAt, timePrefs) {
 					prefSlots = append(prefSlots, PresentedSlot{
-						Index:     prefIdx,
-						DateTime:  bs.StartAt,
-						TimeStr:   bs.StartAt.Format("Mon Jan 2 at 3:04 PM"),
-						Service:   prefs.ServiceInterest,
-						Available: true,
+						Index:       prefIdx,
+						DateTime:    bs.StartAt,
+						EndDateTime: endAt,
+						TimeStr:     bs.StartAt.Format("Mon Jan 2 at 3:04 PM"),
+						Service:     bookingService,
+						Available:   true,
 					})
 					prefIdx++
 				}
@@ -227,7 +278,7 @@ func (s *LLMService) fetchAndPresentAvailability(
 			// show that 1 slot. Don't pad with 3:30 PM slots that violate their request.
 			slots := prefSlots
 			if len(prefSlots) == 0 && len(validSlots) > 0 {
-				s.logger.Info("Boulevard: no slots match time prefs, showing closest alternatives with explanation",
+				s.log(ctx).Info("Boulevard: no slots match time prefs, showing closest alternatives with explanation",
 					"pref_count", len(prefSlots), "valid_count", len(validSlots),
 					"conversation_id", conversationID)
 				// Don't silently ignore the patient's time preference.
@@ -236,7 +287,7 @@ func (s *LLMService) fetchAndPresentAvailability(
 				result = &AvailabilityResult{
 					Slots:      slots,
 					ExactMatch: false,
-					Message:    fmt.Sprintf("I wasn't able to find any openings that match your preference for %s, but here are the closest available times:", FormatPreferencesForLLM(timePrefs)),
+					Message:    templates.Render(templates.SlotsClosestHeader, templates.Params{"preferences": FormatPreferencesForLLM(timePrefs)}, nil).Body,
 				}
 			}
 			// Spread across days like Moxie flow
@@ -244,7 +295,14 @@ func (s *LLMService) fetchAndPresentAvailability(
 			for i := range slots {
 				slots[i].Index = i + 1
 			}
-			if result == nil {
+			if len(slots) == 0 {
+				none := noAvailabilityMessage(bookingService, timePrefs)
+				result = &AvailabilityResult{
+					ExactMatch: false,
+					Message:    none.Body,
+					Template:   none.Ref,
+				}
+			} else if result == nil {
 				// Only set result if not already set (e.g., by mismatch message above)
 				result = &AvailabilityResult{
 					Slots:      slots,
@@ -255,22 +313,33 @@ func (s *LLMService) fetchAndPresentAvailability(
 				result.Slots = slots
 			}
 		}
-	} else if s.moxieClient != nil && cfg != nil && cfg.MoxieConfig != nil {
-		s.logger.Info("fetching availability via Moxie API",
+	} else if s.availability != nil && s.availability.Supports(cfg) {
+		s.log(ctx).Info("fetching availability",
 			"conversation_id", conversationID, "service", scraperServiceName)
-		result, err = FetchAvailableTimesFromMoxieAPIWithProvider(fetchCtx, s.moxieClient, cfg, scraperServiceName, prefs.ProviderPreference, timePrefs, onProgress, prefs.ServiceInterest)
+		result, err = s.availability.Fetch(fetchCtx, AvailabilityRequest{
+			OrgID:              orgID,
+			Config:             cfg,
+			Service:            scraperServiceName,
+			DisplayService:     bookingService,
+			ProviderPreference: prefs.ProviderPreference,
+			Prefs:              timePrefs,
+			OnProgress:         onProgress,
+		})
 		if err != nil {
-			errMsg := err.Error()
-			if strings.Contains(errMsg, "no serviceMenuItemId") {
-				s.logger.Warn("Moxie API: service not found",
+			if errors.Is(err, errNoServiceMenuItem) {
+				s.log(ctx).Warn("Moxie API: service not found",
 					"error", err, "conversation_id", conversationID, "service", scraperServiceName)
+				msg := cfg.RenderTemplate(templates.SlotsUnknownService, templates.Params{"service": bookingService})
+				// A vague name ("peel") close to several menu items gets a
+				// question instead of a dead end.
+				if choices := cfg.ServiceCatalog().ClarifyChoices(scraperServiceName); choices != "" {
+					msg = cfg.RenderTemplate(templates.SlotsServiceClarify, templates.Params{"services": choices})
+				}
 				result = &AvailabilityResult{
 					Slots:      nil,
 					ExactMatch: false,
-					Message: fmt.Sprintf(
-						"I'm sorry, but %s doesn't appear to be a service currently offered at this clinic. "+
-							"Would you like to see what services are available, or is there something else I can help with?",
-						prefs.ServiceInterest),
+					Message:    msg.Body,
+					Template:   msg.Ref,
 				}
 				err = nil
 			}
@@ -278,68 +347,100 @@ func (s *LLMService) fetchAndPresentAvailability(
 	}
 	fetchCancel()
 
+	if errors.Is(err, ErrAvailabilityDegraded) {
+		// Live availability can't be trusted, so don't claim there is none;
+		// the team follows up with times once the booking page is fixed.
+		s.log(ctx).Warn("availability degraded; capturing preferences only",
+			"conversation_id", conversationID, "service", scraperServiceName, "error", err)
+		noted := availabilityDegradedMessage(cfg, bookingService, prefs)
+		return &TimeSelectionResponse{
+			Slots:      nil,
+			Service:    bookingService,
+			ExactMatch: false,
+			SMSMessage: noted.Body,
+			Template:   noted.Ref,
+		}
+	}
 	if err != nil {
-		s.logger.Warn("failed to fetch available times", "error", err)
+		s.log(ctx).Warn("failed to fetch available times", "error", err)
+		failed := cfg.RenderTemplate(templates.SlotsCheckFailed, templates.Params{"service": bookingService})
 		return &TimeSelectionResponse{
 			Slots:      nil,
-			Service:    prefs.ServiceInterest,
+			Service:    bookingService,
 			ExactMatch: false,
-			SMSMessage: fmt.Sprintf("I had trouble checking availability for %s right now. Could you try again in a moment?", prefs.ServiceInterest),
+			SMSMessage: failed.Body,
+			Template:   failed.Ref,
 		}
 	}
 
 	if len(result.Slots) > 0 {
-		return s.buildTimeSelectionResponse(ctx, result, prefs, conversationID, orgID, bookingURL)
+		result.Slots = s.filterLeadBookingConflicts(ctx, cfg, orgID, leadID, scraperServiceName, prefs.BackToBack, result.Slots)
+		if len(result.Slots) == 0 {
+			none := noAvailabilityMessage(bookingService, timePrefs)
+			result.ExactMatch = false
+			result.Message, result.Template = none.Body, none.Ref
+		}
+	}
+	if len(result.Slots) > 0 {
+		return buildTimeSelectionResponse(result, bookingService, leadPhone)
 	}
 
 	// No slots found
 	return &TimeSelectionResponse{
 		Slots:      nil,
-		Service:    prefs.ServiceInterest,
+		Service:    bookingService,
 		ExactMatch: false,
 		SMSMessage: result.Message,
+		Template:   result.Template,
 	}
 }
 
-// buildTimeSelectionResponse saves time selection state and formats the SMS message.
-func (s *LLMService) buildTimeSelectionResponse(
+// saveTimeSelection records that tsr's slots were presented to the patient.
+func (s *LLMService) saveTimeSelection(
 	ctx context.Context,
-	result *AvailabilityResult,
+	tsr *TimeSelectionResponse,
 	prefs *leads.SchedulingPreferences,
 	conversationID, orgID, bookingURL string,
-) *TimeSelectionResponse {
-	s.events.AvailabilityFetched(ctx, conversationID, orgID, prefs.ServiceInterest, len(result.Slots), 0)
+) {
+	s.events.AvailabilityFetched(ctx, conversationID, orgID, prefs.ServiceInterest, len(tsr.Slots), 0)
+	s.RecordExperimentStage(ctx, conversationID, ExperimentStageAvailabilityPresented)
 	state := &TimeSelectionState{
-		PresentedSlots: result.Slots,
-		Service:        prefs.ServiceInterest,
+		PresentedSlots: tsr.Slots,
+		Service:        tsr.Service,
 		BookingURL:     bookingURL,
 		PresentedAt:    time.Now(),
 	}
 	if err := s.history.SaveTimeSelectionState(ctx, conversationID, state); err != nil {
-		s.logger.Error("CRITICAL: failed to save time selection state",
+		s.log(ctx).Error("CRITICAL: failed to save time selection state",
 			"error", err,
 			"conversation_id", conversationID,
-			"slots", len(result.Slots),
-		)
-	} else {
-		s.logger.Info("time selection state saved",
-			"conversation_id", conversationID,
-			"slots", len(state.PresentedSlots),
-			"service", state.Service,
+			"slots", len(tsr.Slots),
 		)
+		return
 	}
+	s.log(ctx).Info("time selection state saved",
+		"conversation_id", conversationID,
+		"slots", len(state.PresentedSlots),
+		"service", state.Service,
+	)
+}
 
-	smsMsg := FormatTimeSlotsForSMS(result.Slots, prefs.ServiceInterest, result.ExactMatch)
+// buildTimeSelectionResponse formats the slots SMS. service is what the slots
+// book, which for a consult-first service is its consultation rather than
+// prefs.ServiceInterest.
+func buildTimeSelectionResponse(result *AvailabilityResult, service, leadPhone string) *TimeSelectionResponse {
+	sms := renderTimeSlots(result.Slots, service, result.ExactMatch)
 	// If we have a custom message (e.g., time preference mismatch explanation),
 	// use it as the header instead of the generic one.
 	if result.Message != "" && !result.ExactMatch {
-		smsMsg = FormatTimeSlotsWithCustomHeader(result.Slots, result.Message)
+		sms = renderTimeSlotsWithHeader(result.Slots, result.Message)
 	}
 	return &TimeSelectionResponse{
 		Slots:      result.Slots,
-		Service:    prefs.ServiceInterest,
+		Service:    service,
 		ExactMatch: result.ExactMatch,
-		SMSMessage: smsMsg,
+		SMSMessage: withTimezoneNote(sms.Body, result.Slots, leadPhone),
+		Template:   sms.Ref,
 	}
 }
 
@@ -381,7 +482,7 @@ func isWithinBusinessHours(slotTime time.Time, bh clinic.BusinessHours) bool {
 // filterSlotsByTimePrefs filters pre-fetched slots by patient's time preferences.
 // Returns all slots if no preferences specified.
 func filterSlotsByTimePrefs(slots []PresentedSlot, prefs *TimePreferences) []PresentedSlot {
-	if prefs == nil || (len(prefs.DaysOfWeek) == 0 && prefs.AfterTime == "" && prefs.BeforeTime == "") {
+	if prefs == nil || (len(prefs.DaysOfWeek) == 0 && prefs.AfterTime == "" && prefs.BeforeTime == "" && !prefs.HasDateRange()) {
 		return slots
 	}
 	var filtered []PresentedSlot
@@ -396,8 +497,33 @@ func filterSlotsByTimePrefs(slots []PresentedSlot, prefs *TimePreferences) []Pre
 // handleDepositFlow determines whether a deposit intent should be emitted for the
 // current conversation turn. It runs the deterministic agreement check first, then
 // falls back to the LLM classifier when appropriate. Returns nil when no deposit
-// should be collected.
-func (s *LLMService) handleDepositFlow(ctx context.Context, history []ChatMessage) *DepositIntent {
+// should be collected. When the requested service is exempt under the clinic's
+// deposit policy, the intent carries no amount so the dispatcher confirms the
+// booking instead of sending a payment link.
+func (s *LLMService) handleDepositFlow(ctx context.Context, history []ChatMessage, cfg *clinic.Config) *DepositIntent {
+	intent := s.detectDepositIntent(ctx, history)
+	if intent == nil || cfg == nil {
+		return intent
+	}
+	prefs, ok := extractClinicPreferences(history, cfg)
+	if !ok || prefs.ServiceInterest == "" {
+		return intent
+	}
+	intent.Service = cfg.BookingServiceFor(prefs.ServiceInterest)
+	if !cfg.DepositRequiredForService(intent.Service) {
+		s.log(ctx).Info("deposit: service exempt by clinic policy", "service", intent.Service)
+		intent.AmountCents = 0
+	}
+	return intent
+}
+
+func (s *LLMService) detectDepositIntent(ctx context.Context, history []ChatMessage) *DepositIntent {
+	// Payment status comes from the payments repository; a patient saying
+	// they paid neither agrees to a new deposit nor proves the old one.
+	if latestTurnClaimsDepositPaid(history) {
+		s.log(ctx).Info("deposit: classifier skipped (patient claims deposit already paid)")
+		return nil
+	}
 	if latestTurnAgreedToDeposit(history) {
 		intent := &DepositIntent{
 			AmountCents: s.deposit.DefaultAmountCents,
@@ -405,23 +531,23 @@ func (s *LLMService) handleDepositFlow(ctx context.Context, history []ChatMessag
 			SuccessURL:  s.deposit.SuccessURL,
 			CancelURL:   s.deposit.CancelURL,
 		}
-		s.logger.Info("deposit intent inferred from explicit user agreement", "amount_cents", intent.AmountCents)
+		s.log(ctx).Info("deposit intent inferred from explicit user agreement", "amount_cents", intent.AmountCents)
 		return intent
 	}
 
 	if shouldAttemptDepositClassification(history) {
 		extracted, derr := s.extractDepositIntent(ctx, history)
 		if derr != nil {
-			s.logger.Warn("deposit intent extraction failed", "error", derr)
+			s.log(ctx).Warn("deposit intent extraction failed", "error", derr)
 		} else if extracted != nil {
-			s.logger.Info("deposit intent extracted", "amount_cents", extracted.AmountCents)
+			s.log(ctx).Info("deposit intent extracted", "amount_cents", extracted.AmountCents)
 		} else {
-			s.logger.Debug("no deposit intent detected")
+			s.log(ctx).Debug("no deposit intent detected")
 		}
 		return extracted
 	}
 
-	s.logger.Debug("deposit: classifier skipped (no deposit context)")
+	s.log(ctx).Debug("deposit: classifier skipped (no deposit context)")
 	return nil
 }
 
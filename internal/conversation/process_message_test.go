@@ -2,7 +2,6 @@ package conversation
 
 import (
 	"context"
-	"encoding/json"
 	"strings"
 	"testing"
 	"time"
@@ -11,6 +10,7 @@ import (
 	"github.com/redis/go-redis/v9"
 	"github.com/wolfman30/medspa-ai-platform/internal/clinic"
 	"github.com/wolfman30/medspa-ai-platform/internal/leads"
+	"github.com/wolfman30/medspa-ai-platform/internal/tenancy"
 	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
 )
 
@@ -100,7 +100,7 @@ func getHistory(t *testing.T, mr *miniredis.Miniredis, convID string) []ChatMess
 		t.Fatalf("get history: %v", err)
 	}
 	var h []ChatMessage
-	if err := json.Unmarshal([]byte(raw), &h); err != nil {
+	if h, err = decodeHistory([]byte(raw)); err != nil {
 		t.Fatalf("unmarshal history: %v", err)
 	}
 	return h
@@ -809,7 +809,7 @@ func TestProcessMessage_SavesPreferencesForLead(t *testing.T) {
 		t.Fatalf("unexpected error: %v", err)
 	}
 
-	updated, err := ts.leadsRepo.GetByID(ctx, "org-1", lead.ID)
+	updated, err := ts.leadsRepo.GetByID(ctx, tenancy.ForOrg("org-1"), lead.ID)
 	if err != nil {
 		t.Fatalf("get lead: %v", err)
 	}
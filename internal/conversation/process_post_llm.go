@@ -8,25 +8,12 @@ import (
 
 	"github.com/wolfman30/medspa-ai-platform/internal/clinic"
 	"github.com/wolfman30/medspa-ai-platform/internal/leads"
+	"github.com/wolfman30/medspa-ai-platform/internal/tenancy"
 )
 
 // handlePostLLMResponse handles everything after the LLM reply: deposit flow,
 // preference extraction, time selection triggering, booking request assembly.
 func (s *LLMService) handlePostLLMResponse(ctx context.Context, pc *processContext) {
-	pc.depositIntent = s.handleDepositFlow(ctx, pc.history)
-
-	// Extract and save scheduling preferences
-	if pc.req.LeadID != "" && s.leadsRepo != nil {
-		if err := s.extractAndSavePreferences(ctx, pc.req.LeadID, pc.history); err != nil {
-			s.logger.Warn("failed to save scheduling preferences", "lead_id", pc.req.LeadID, "error", err)
-		}
-		if email := ExtractEmailFromHistory(pc.history); email != "" {
-			if err := s.leadsRepo.UpdateEmail(ctx, pc.req.LeadID, email); err != nil {
-				s.logger.Warn("failed to save email", "lead_id", pc.req.LeadID, "error", err)
-			}
-		}
-	}
-
 	// Load clinic config for post-response decisions
 	var usesMoxie bool
 	var clinicCfg *clinic.Config
@@ -37,24 +24,45 @@ func (s *LLMService) handlePostLLMResponse(ctx context.Context, pc *processConte
 		}
 	}
 
+	pc.depositIntent = s.handleDepositFlow(ctx, pc.history, clinicCfg)
+	if pc.existingAppointment && pc.depositIntent != nil {
+		s.log(ctx).Info("deposit intent suppressed: patient is asking about an existing appointment",
+			"conversation_id", pc.req.ConversationID,
+		)
+		pc.depositIntent = nil
+	}
+
+	// Extract and save scheduling preferences
+	if pc.req.LeadID != "" && s.leadsRepo != nil {
+		if err := s.extractAndSavePreferences(ctx, pc.cfg, pc.req.LeadID, pc.history); err != nil {
+			s.log(ctx).Warn("failed to save scheduling preferences", "lead_id", pc.req.LeadID, "error", err)
+		}
+	}
+
 	// Enforce clinic-configured deposit amounts for Square clinics
-	if pc.depositIntent != nil && clinicCfg != nil && !usesMoxie {
-		if prefs, ok := extractPreferences(pc.history, serviceAliasesFromConfig(clinicCfg)); ok && prefs.ServiceInterest != "" {
-			if amount := clinicCfg.DepositAmountForService(prefs.ServiceInterest); amount > 0 {
-				pc.depositIntent.AmountCents = int32(amount)
-			}
+	if pc.depositIntent != nil && clinicCfg != nil && !usesMoxie && pc.depositIntent.Service != "" {
+		if amount := clinicCfg.DepositAmountForService(pc.depositIntent.Service); amount > 0 {
+			pc.depositIntent.AmountCents = int32(amount)
 		}
 	}
 
 	// GUARD: Booking API clinics — no deposit before time selection
 	if pc.depositIntent != nil && usesMoxie && (pc.timeSelectionState == nil || !pc.timeSelectionState.SlotSelected) {
-		s.logger.Warn("deposit intent suppressed: booking API clinic requires time selection before deposit",
+		s.log(ctx).Warn("deposit intent suppressed: booking API clinic requires time selection before deposit",
 			"conversation_id", pc.req.ConversationID,
 			"slot_selected", pc.timeSelectionState != nil && pc.timeSelectionState.SlotSelected,
 		)
 		pc.depositIntent = nil
 	}
 
+	// GUARD: the patient's deposit already carries over from a taken slot
+	if pc.depositIntent != nil && pc.timeSelectionState != nil && pc.timeSelectionState.DepositApplied {
+		s.log(ctx).Info("deposit intent suppressed: deposit already applied to this selection",
+			"conversation_id", pc.req.ConversationID,
+		)
+		pc.depositIntent = nil
+	}
+
 	// Time selection triggering
 	s.maybeTriggerTimeSelection(ctx, pc, clinicCfg, usesMoxie)
 
@@ -67,7 +75,7 @@ func (s *LLMService) handlePostLLMResponse(ctx context.Context, pc *processConte
 			}
 		}
 		if err := s.history.Save(ctx, pc.req.ConversationID, pc.history); err != nil {
-			s.logger.Warn("failed to re-save history after time selection", "error", err)
+			s.log(ctx).Warn("failed to re-save history after time selection", "error", err)
 		}
 		pc.timeSelectionResponse.SavedToHistory = true
 	}
@@ -76,7 +84,7 @@ func (s *LLMService) handlePostLLMResponse(ctx context.Context, pc *processConte
 	// Boulevard clinics still need Stripe deposit links.
 	isMoxieOnly := clinicCfg != nil && clinicCfg.UsesMoxieBooking() && !clinicCfg.UsesBoulevardBooking()
 	if isMoxieOnly && pc.depositIntent != nil {
-		s.logger.Info("clinic uses Moxie booking API - skipping Square deposit intent", "org_id", pc.req.OrgID)
+		s.log(ctx).Info("clinic uses Moxie booking API - skipping Square deposit intent", "org_id", pc.req.OrgID)
 		pc.depositIntent = nil
 	}
 
@@ -86,15 +94,21 @@ func (s *LLMService) handlePostLLMResponse(ctx context.Context, pc *processConte
 
 // maybeTriggerTimeSelection checks whether to fetch and present available time slots.
 func (s *LLMService) maybeTriggerTimeSelection(ctx context.Context, pc *processContext, clinicCfg *clinic.Config, usesMoxie bool) {
-	moxieAPIReady := s.moxieClient != nil && clinicCfg != nil && clinicCfg.MoxieConfig != nil
+	availabilityReady := s.availability != nil && s.availability.Supports(clinicCfg)
 	boulevardReady := s.boulevardAdapter != nil && clinicCfg != nil && clinicCfg.UsesBoulevardBooking()
-	bookingAPIReady := moxieAPIReady || boulevardReady
-	qualificationsMet := ShouldFetchAvailabilityWithConfig(pc.history, nil, clinicCfg)
+	bookingAPIReady := availabilityReady || boulevardReady
+	qualificationsMet := s.qualificationsMet(ctx, pc.history, clinicCfg)
+	// The extra qualification is asked below, once the standard ones are in.
+	awaitingExtraQualification := !qualificationsMet && pendingExtraQualificationQuestion(pc.history, clinicCfg) != ""
+	qualificationsMet = qualificationsMet || awaitingExtraQualification
 	shouldTrigger := bookingAPIReady && pc.timeSelectionState == nil
 
 	if pc.timeSelectionState != nil && pc.timeSelectionState.SlotSelected {
 		shouldTrigger = false
 	}
+	if pc.existingAppointment {
+		shouldTrigger = false
+	}
 	if usesMoxie || boulevardReady {
 		shouldTrigger = shouldTrigger && qualificationsMet
 	} else {
@@ -104,18 +118,18 @@ func (s *LLMService) maybeTriggerTimeSelection(ctx context.Context, pc *processC
 	// Defer until schedule preferences exist
 	var earlyPrefs *leads.SchedulingPreferences
 	if shouldTrigger && (usesMoxie || boulevardReady) {
-		p, _ := extractPreferences(pc.history, serviceAliasesFromConfig(clinicCfg))
+		p, _ := extractClinicPreferences(pc.history, clinicCfg)
 		earlyPrefs = &p
 		if !hasSchedulePreferences(earlyPrefs) {
-			s.logger.Info("ProcessMessage: deferring time selection — no schedule preferences yet",
+			s.log(ctx).Info("ProcessMessage: deferring time selection — no schedule preferences yet",
 				"conversation_id", pc.req.ConversationID)
 			shouldTrigger = false
 		}
 	}
 
-	s.logger.Info("time selection trigger check",
+	s.log(ctx).Info("time selection trigger check",
 		"conversation_id", pc.req.ConversationID,
-		"moxie_api_ready", moxieAPIReady,
+		"moxie_api_ready", availabilityReady,
 		"qualifications_met", qualificationsMet,
 		"time_selection_state_exists", pc.timeSelectionState != nil,
 		"uses_moxie", usesMoxie,
@@ -138,7 +152,7 @@ func (s *LLMService) maybeTriggerTimeSelection(ctx context.Context, pc *processC
 	if earlyPrefs != nil {
 		prefs = *earlyPrefs
 	} else {
-		prefs, _ = extractPreferences(pc.history, serviceAliasesFromConfig(clinicCfg))
+		prefs, _ = extractClinicPreferences(pc.history, clinicCfg)
 	}
 
 	// Service variant resolution
@@ -156,9 +170,15 @@ func (s *LLMService) maybeTriggerTimeSelection(ctx context.Context, pc *processC
 		return
 	}
 
+	// Service-specific qualification (e.g. treatment area)
+	if extraResp := s.handleExtraQualification(ctx, clinicCfg, &prefs, pc.history, pc.req.ConversationID, pc.req.LeadID); extraResp != nil {
+		pc.reply = extraResp.Message
+		return
+	}
+
 	// Voice channel: defer to async SMS
 	if isVoiceChannel(pc.req.Channel) {
-		s.logger.Info("voice channel: deferring availability to async SMS",
+		s.log(ctx).Info("voice channel: deferring availability to async SMS",
 			"conversation_id", pc.req.ConversationID,
 			"service", prefs.ServiceInterest,
 		)
@@ -175,7 +195,7 @@ func (s *LLMService) maybeTriggerTimeSelection(ctx context.Context, pc *processC
 	}
 
 	// Fetch and present availability
-	pc.timeSelectionResponse = s.fetchAndPresentAvailability(ctx, &prefs, clinicCfg, bookingURL, pc.req.ConversationID, pc.req.OrgID, pc.req.OnProgress)
+	pc.timeSelectionResponse = s.fetchAndPresentAvailability(ctx, &prefs, clinicCfg, bookingURL, pc.req.ConversationID, pc.req.OrgID, pc.req.LeadID, pc.req.From, pc.req.OnProgress)
 	if pc.timeSelectionResponse != nil && len(pc.timeSelectionResponse.Slots) > 0 {
 		pc.depositIntent = nil
 	}
@@ -192,7 +212,7 @@ func (s *LLMService) assembleBookingRequest(ctx context.Context, pc *processCont
 	var previouslySelectedDateTime *time.Time
 	var previouslySelectedService string
 	if pc.selectedSlot == nil && pc.timeSelectionState != nil && pc.timeSelectionState.SlotSelected && pc.req.LeadID != "" && s.leadsRepo != nil {
-		if lead, err := s.leadsRepo.GetByID(ctx, pc.req.OrgID, pc.req.LeadID); err == nil && lead != nil && lead.SelectedDateTime != nil {
+		if lead, err := s.leadsRepo.GetByID(ctx, tenancy.ForOrg(pc.req.OrgID), pc.req.LeadID); err == nil && lead != nil && lead.SelectedDateTime != nil {
 			dt := *lead.SelectedDateTime
 			if clinicCfg.Timezone != "" {
 				if loc, lerr := time.LoadLocation(clinicCfg.Timezone); lerr == nil {
@@ -201,7 +221,7 @@ func (s *LLMService) assembleBookingRequest(ctx context.Context, pc *processCont
 			}
 			previouslySelectedDateTime = &dt
 			previouslySelectedService = lead.SelectedService
-			s.logger.Info("found previously selected slot on lead",
+			s.log(ctx).Info("found previously selected slot on lead",
 				"lead_id", pc.req.LeadID,
 				"date_time", lead.SelectedDateTime,
 				"service", lead.SelectedService,
@@ -216,14 +236,20 @@ func (s *LLMService) assembleBookingRequest(ctx context.Context, pc *processCont
 	firstName, lastName := splitName("")
 	phone := pc.req.From
 	email := ""
+	notes := ""
+	interest := ""
 
 	if pc.req.LeadID != "" && s.leadsRepo != nil {
-		if lead, err := s.leadsRepo.GetByID(ctx, pc.req.OrgID, pc.req.LeadID); err == nil && lead != nil {
+		if lead, err := s.leadsRepo.GetByID(ctx, tenancy.ForOrg(pc.req.OrgID), pc.req.LeadID); err == nil && lead != nil {
 			firstName, lastName = splitName(lead.Name)
 			if lead.Phone != "" {
 				phone = lead.Phone
 			}
 			email = lead.Email
+			notes = extraQualificationNote(lead.ExtraQualifications)
+			// A consultation booked for a consult-first service tells the
+			// clinic which treatment the patient is interested in.
+			interest = lead.ServiceInterest
 		}
 	}
 
@@ -232,7 +258,7 @@ func (s *LLMService) assembleBookingRequest(ctx context.Context, pc *processCont
 	}
 
 	if email == "" {
-		s.logger.Info("proceeding with booking without email — will be captured on booking page", "lead_id", pc.req.LeadID)
+		s.log(ctx).Info("proceeding with booking without email — will be captured on booking page", "lead_id", pc.req.LeadID)
 	}
 
 	var slotDateTime time.Time
@@ -247,6 +273,10 @@ func (s *LLMService) assembleBookingRequest(ctx context.Context, pc *processCont
 		slotService = previouslySelectedService
 	}
 
+	if note := consultBookingNote(clinicCfg, interest); note != "" && strings.EqualFold(clinicCfg.BookingServiceFor(interest), slotService) {
+		notes = joinNotes(notes, note)
+	}
+
 	dateStr := slotDateTime.Format("2006-01-02")
 	timeStr := strings.ToLower(slotDateTime.Format("3:04pm"))
 
@@ -267,9 +297,12 @@ func (s *LLMService) assembleBookingRequest(ctx context.Context, pc *processCont
 		LastName:    lastName,
 		Phone:       phone,
 		Email:       email,
+		Notes:       notes,
 		CallbackURL: callbackURL,
+
+		DepositApplied: pc.timeSelectionState != nil && pc.timeSelectionState.DepositApplied,
 	}
-	s.logger.Info("booking request prepared for Moxie",
+	s.log(ctx).Info("booking request prepared for Moxie",
 		"booking_url", clinicCfg.BookingURL,
 		"date", dateStr,
 		"time", timeStr,
@@ -8,6 +8,8 @@ import (
 	"time"
 
 	"github.com/wolfman30/medspa-ai-platform/internal/leads"
+	"github.com/wolfman30/medspa-ai-platform/internal/messaging/templates"
+	"github.com/wolfman30/medspa-ai-platform/internal/tenancy"
 )
 
 // loadTimeSelectionState loads time selection state and handles new-service
@@ -16,10 +18,10 @@ import (
 func (s *LLMService) loadTimeSelectionState(ctx context.Context, pc *processContext) {
 	state, tsErr := s.history.LoadTimeSelectionState(ctx, pc.req.ConversationID)
 	if tsErr != nil {
-		s.logger.Warn("failed to load time selection state", "error", tsErr, "conversation_id", pc.req.ConversationID)
+		s.log(ctx).Warn("failed to load time selection state", "error", tsErr, "conversation_id", pc.req.ConversationID)
 		return
 	}
-	s.logger.Info("time selection state loaded",
+	s.log(ctx).Info("time selection state loaded",
 		"conversation_id", pc.req.ConversationID,
 		"state_exists", state != nil,
 		"slots_count", func() int {
@@ -63,7 +65,7 @@ func (s *LLMService) detectNewServiceAfterBooking(ctx context.Context, pc *proce
 		resolvedNew := strings.ToLower(newServiceExact)
 		resolvedOld := bookedService
 		if pc.cfg != nil {
-			resolvedNew = strings.ToLower(pc.cfg.ResolveServiceName(newServiceExact))
+			resolvedNew = strings.ToLower(pc.cfg.ResolveServiceName(pc.cfg.BookingServiceFor(newServiceExact)))
 			resolvedOld = strings.ToLower(pc.cfg.ResolveServiceName(bookedService))
 		}
 		mentionsNewService = resolvedNew != resolvedOld
@@ -87,17 +89,17 @@ func (s *LLMService) detectNewServiceAfterBooking(ctx context.Context, pc *proce
 		return false
 	}
 
-	s.logger.Info("new service detected after previous booking — resetting time selection state",
+	s.log(ctx).Info("new service detected after previous booking — resetting time selection state",
 		"conversation_id", pc.req.ConversationID,
 		"old_service", state.Service,
 		"message", pc.rawMessage,
 	)
 	if err := s.history.ClearTimeSelectionState(ctx, pc.req.ConversationID); err != nil {
-		s.logger.Warn("failed to clear time selection state for new service", "error", err)
+		s.log(ctx).Warn("failed to clear time selection state for new service", "error", err)
 	}
 	if pc.req.LeadID != "" && s.leadsRepo != nil {
 		if uerr := s.leadsRepo.ClearSelectedAppointment(ctx, pc.req.LeadID); uerr != nil {
-			s.logger.Warn("failed to clear selected appointment for new service", "error", uerr)
+			s.log(ctx).Warn("failed to clear selected appointment for new service", "error", uerr)
 		}
 	}
 	return true
@@ -113,8 +115,8 @@ func (s *LLMService) handleActiveTimeSelection(ctx context.Context, pc *processC
 
 	// Build time preferences for disambiguation
 	selectionPrefs := TimePreferences{}
-	if convPrefs, ok := extractPreferences(pc.history, serviceAliasesFromConfig(pc.cfg)); ok {
-		selectionPrefs = ExtractTimePreferences(convPrefs.PreferredDays + " " + convPrefs.PreferredTimes)
+	if convPrefs, ok := extractClinicPreferences(pc.history, pc.cfg); ok {
+		selectionPrefs = clinicTimePreferences(convPrefs.PreferredDays+" "+convPrefs.PreferredTimes, pc.cfg)
 	}
 
 	// Check if user is selecting a time slot
@@ -148,7 +150,8 @@ func (s *LLMService) handleActiveTimeSelection(ctx context.Context, pc *processC
 func (s *LLMService) handleSlotSelection(ctx context.Context, pc *processContext, slot *PresentedSlot) {
 	state := pc.timeSelectionState
 	s.events.TimeSlotSelected(ctx, pc.req.ConversationID, pc.req.OrgID, slot.DateTime.Format(time.RFC3339), slot.Index)
-	s.logger.Info("time slot selected",
+	s.RecordExperimentStage(ctx, pc.req.ConversationID, ExperimentStageSlotSelected)
+	s.log(ctx).Info("time slot selected",
 		"slot_index", slot.Index,
 		"time", slot.DateTime,
 		"service", state.Service,
@@ -161,12 +164,12 @@ func (s *LLMService) handleSlotSelection(ctx context.Context, pc *processContext
 		if !endDT.IsZero() {
 			endDTPtr = &endDT
 		}
-		if err := s.leadsRepo.UpdateSelectedAppointment(ctx, pc.req.LeadID, leads.SelectedAppointment{
+		if err := s.leadsRepo.UpdateSelectedAppointment(ctx, tenancy.ForOrg(pc.req.OrgID), pc.req.LeadID, leads.SelectedAppointment{
 			DateTime:    &slot.DateTime,
 			EndDateTime: endDTPtr,
 			Service:     state.Service,
 		}); err != nil {
-			s.logger.Warn("failed to save selected appointment", "lead_id", pc.req.LeadID, "error", err)
+			s.log(ctx).Warn("failed to save selected appointment", "lead_id", pc.req.LeadID, "error", err)
 		}
 	}
 
@@ -174,13 +177,21 @@ func (s *LLMService) handleSlotSelection(ctx context.Context, pc *processContext
 	state.SlotSelected = true
 	state.PresentedSlots = nil
 	if err := s.history.SaveTimeSelectionState(ctx, pc.req.ConversationID, state); err != nil {
-		s.logger.Warn("failed to save time selection completion state", "error", err)
+		s.log(ctx).Warn("failed to save time selection completion state", "error", err)
 	}
 
 	// Inject into history for LLM confirmation
+	confirm := fmt.Sprintf("[SYSTEM] The patient selected time slot #%d: %s for %s. Confirm their selection and proceed with booking.", slot.Index, slot.TimeStr, state.Service)
+	if state.DepositApplied {
+		confirm += " Their deposit was already paid for an earlier time that got taken, and it applies to this one. Do NOT ask for another deposit or send a payment link."
+	}
+	if isQualitativeSelection(pc.rawMessage) {
+		// "the morning one" may fit several slots; we picked one, so say which.
+		confirm += fmt.Sprintf(" The patient described the time rather than naming it, so state the exact time (%s) in your confirmation. Do NOT ask them to choose again.", slot.TimeStr)
+	}
 	pc.history = append(pc.history, ChatMessage{
 		Role:    ChatRoleSystem,
-		Content: fmt.Sprintf("[SYSTEM] The patient selected time slot #%d: %s for %s. Confirm their selection and proceed with booking.", slot.Index, slot.TimeStr, state.Service),
+		Content: confirm,
 	})
 	pc.selectedSlot = slot
 }
@@ -188,14 +199,14 @@ func (s *LLMService) handleSlotSelection(ctx context.Context, pc *processContext
 // handleMoreTimesRequest handles when a patient asks for more/different available times.
 func (s *LLMService) handleMoreTimesRequest(ctx context.Context, pc *processContext) {
 	state := pc.timeSelectionState
-	s.logger.Info("patient requesting more times",
+	s.log(ctx).Info("patient requesting more times",
 		"conversation_id", pc.req.ConversationID,
 		"message", pc.rawMessage,
 	)
 
 	moreTimesHandled := false
-	if s.moxieClient != nil && pc.cfg != nil && pc.cfg.MoxieConfig != nil {
-		prefs, _ := extractPreferences(pc.history, serviceAliasesFromConfig(pc.cfg))
+	if s.availability != nil && s.availability.Supports(pc.cfg) {
+		prefs, _ := extractClinicPreferences(pc.history, pc.cfg)
 		service := state.Service
 		scraperServiceName := service
 		if pc.cfg != nil {
@@ -203,7 +214,7 @@ func (s *LLMService) handleMoreTimesRequest(ctx context.Context, pc *processCont
 		}
 
 		refinedPrefs := buildRefinedTimePreferences(pc.rawMessage, prefs, state.PresentedSlots)
-		s.logger.Info("re-fetching availability with refined preferences",
+		s.log(ctx).Info("re-fetching availability with refined preferences",
 			"conversation_id", pc.req.ConversationID,
 			"refined_after", refinedPrefs.AfterTime,
 			"refined_days", refinedPrefs.DaysOfWeek,
@@ -214,12 +225,20 @@ func (s *LLMService) handleMoreTimesRequest(ctx context.Context, pc *processCont
 		var result *AvailabilityResult
 		var fetchErr error
 
-		result, fetchErr = FetchAvailableTimesFromMoxieAPIWithProvider(fetchCtx, s.moxieClient, pc.cfg,
-			scraperServiceName, prefs.ProviderPreference, refinedPrefs, pc.req.OnProgress, service)
+		result, fetchErr = s.availability.Fetch(fetchCtx, AvailabilityRequest{
+			OrgID:              pc.req.OrgID,
+			Config:             pc.cfg,
+			Service:            scraperServiceName,
+			DisplayService:     service,
+			ProviderPreference: prefs.ProviderPreference,
+			Prefs:              refinedPrefs,
+			OnProgress:         pc.req.OnProgress,
+		})
 		fetchCancel()
 
 		if fetchErr == nil && result != nil {
 			newSlots := filterOutPreviousSlots(result.Slots, state.PresentedSlots)
+			newSlots = s.filterLeadBookingConflicts(ctx, pc.cfg, pc.req.OrgID, pc.req.LeadID, scraperServiceName, prefs.BackToBack, newSlots)
 			if len(newSlots) > 0 {
 				for i := range newSlots {
 					newSlots[i].Index = i + 1
@@ -231,21 +250,25 @@ func (s *LLMService) handleMoreTimesRequest(ctx context.Context, pc *processCont
 					PresentedAt:    time.Now(),
 				}
 				if err := s.history.SaveTimeSelectionState(ctx, pc.req.ConversationID, newState); err != nil {
-					s.logger.Error("failed to save refined time selection state", "error", err)
+					s.log(ctx).Error("failed to save refined time selection state", "error", err)
 				}
+				sms := renderTimeSlots(newSlots, service, true)
 				pc.timeSelectionResponse = &TimeSelectionResponse{
 					Slots:      newSlots,
 					Service:    service,
 					ExactMatch: true,
-					SMSMessage: FormatTimeSlotsForSMS(newSlots, service, true),
+					SMSMessage: withTimezoneNote(sms.Body, newSlots, pc.req.From),
+					Template:   sms.Ref,
 				}
 				moreTimesHandled = true
 			} else {
+				noMore := pc.cfg.RenderTemplate(templates.SlotsNoMoreTimes, templates.Params{"service": service})
 				pc.timeSelectionResponse = &TimeSelectionResponse{
 					Slots:      nil,
 					Service:    service,
 					ExactMatch: false,
-					SMSMessage: fmt.Sprintf("Those are the latest available times on those days for %s. Would you like to try different days, or would one of the times I showed work for you?", service),
+					SMSMessage: noMore.Body,
+					Template:   noMore.Ref,
 				}
 				moreTimesHandled = true
 			}
@@ -255,7 +278,7 @@ func (s *LLMService) handleMoreTimesRequest(ctx context.Context, pc *processCont
 	if !moreTimesHandled {
 		pc.timeSelectionState = nil
 		if err := s.history.SaveTimeSelectionState(ctx, pc.req.ConversationID, nil); err != nil {
-			s.logger.Warn("failed to clear time selection state", "error", err)
+			s.log(ctx).Warn("failed to clear time selection state", "error", err)
 		}
 	}
 }
@@ -0,0 +1,102 @@
+package conversation
+
+import (
+	"strings"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/clinic"
+)
+
+// Where an assembled prompt section's text came from.
+const (
+	PromptSourceDefault  = "default"
+	PromptSourceReplaced = "replaced"
+	PromptSourceAppended = "appended"
+)
+
+// PromptSection is one assembled section of the system prompt.
+type PromptSection struct {
+	ID     string `json:"id"`
+	Source string `json:"source"`
+	Locked bool   `json:"locked"`
+	Text   string `json:"text"`
+}
+
+// PromptBuilder assembles the base SMS system prompt from ordered sections,
+// applying a clinic's prompt overrides. The output depends only on the
+// section defaults and the clinic's overrides, so it is stable across calls
+// and safe to cache.
+type PromptBuilder struct {
+	defaults map[string]string
+}
+
+// NewPromptBuilder returns a builder over the default prompt sections.
+func NewPromptBuilder() *PromptBuilder {
+	return &PromptBuilder{defaults: map[string]string{
+		clinic.PromptSectionPersona:          promptSectionPersonaText,
+		clinic.PromptSectionGuardrails:       promptSectionGuardrailsText,
+		clinic.PromptSectionQualification:    promptSectionQualificationText,
+		clinic.PromptSectionPolicies:         promptSectionPoliciesText,
+		clinic.PromptSectionServiceKnowledge: promptSectionServiceKnowledgeText,
+		clinic.PromptSectionStyle:            promptSectionStyleText,
+		clinic.PromptSectionSafety:           promptSectionSafetyText,
+		clinic.PromptSectionExamples:         promptSectionExamplesText,
+		clinic.PromptSectionClinic:           "",
+	}}
+}
+
+var defaultPromptBuilder = NewPromptBuilder()
+
+// Sections returns every section in assembly order with cfg's overrides
+// applied: a replace swaps the default text, then appends follow in the order
+// given. Locked sections ignore replaces, so a stored config that skipped
+// validation still can't drop them.
+func (b *PromptBuilder) Sections(cfg *clinic.Config) []PromptSection {
+	var overrides []clinic.PromptOverride
+	if cfg != nil {
+		overrides = cfg.PromptOverrides
+	}
+	sections := make([]PromptSection, 0, len(clinic.PromptSections))
+	for _, id := range clinic.PromptSections {
+		sec := PromptSection{
+			ID:     id,
+			Source: PromptSourceDefault,
+			Locked: clinic.PromptSectionLocked(id),
+			Text:   b.defaults[id],
+		}
+		for _, o := range overrides {
+			if o.Section == id && o.Mode == clinic.PromptOverrideReplace && !sec.Locked {
+				sec.Text = strings.TrimSpace(o.Text)
+				sec.Source = PromptSourceReplaced
+				break
+			}
+		}
+		for _, o := range overrides {
+			text := strings.TrimSpace(o.Text)
+			if o.Section != id || o.Mode != clinic.PromptOverrideAppend || text == "" {
+				continue
+			}
+			if sec.Text == "" {
+				sec.Text = text
+			} else {
+				sec.Text += "\n\n" + text
+			}
+			if sec.Source == PromptSourceDefault {
+				sec.Source = PromptSourceAppended
+			}
+		}
+		sections = append(sections, sec)
+	}
+	return sections
+}
+
+// Build returns the assembled base prompt for cfg. Empty sections are
+// skipped. A nil cfg yields the default prompt.
+func (b *PromptBuilder) Build(cfg *clinic.Config) string {
+	var parts []string
+	for _, sec := range b.Sections(cfg) {
+		if sec.Text != "" {
+			parts = append(parts, sec.Text)
+		}
+	}
+	return strings.Join(parts, "\n\n")
+}
@@ -0,0 +1,100 @@
+package conversation
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/clinic"
+)
+
+func sectionByID(t *testing.T, sections []PromptSection, id string) PromptSection {
+	t.Helper()
+	for _, s := range sections {
+		if s.ID == id {
+			return s
+		}
+	}
+	t.Fatalf("section %q not found", id)
+	return PromptSection{}
+}
+
+func TestPromptBuilder_OverridePrecedence(t *testing.T) {
+	cfg := &clinic.Config{PromptOverrides: []clinic.PromptOverride{
+		// Appends listed before the replace still follow it.
+		{Section: clinic.PromptSectionStyle, Mode: clinic.PromptOverrideAppend, Text: "Sign off as the Glow team."},
+		{Section: clinic.PromptSectionStyle, Mode: clinic.PromptOverrideReplace, Text: "Be brief and clinical. No emoji."},
+		{Section: clinic.PromptSectionStyle, Mode: clinic.PromptOverrideAppend, Text: "Never use exclamation marks."},
+		{Section: clinic.PromptSectionClinic, Mode: clinic.PromptOverrideAppend, Text: "We do NOT offer laser hair removal."},
+		{Section: clinic.PromptSectionExamples, Mode: clinic.PromptOverrideReplace, Text: ""},
+	}}
+	sections := NewPromptBuilder().Sections(cfg)
+
+	style := sectionByID(t, sections, clinic.PromptSectionStyle)
+	want := "Be brief and clinical. No emoji.\n\nSign off as the Glow team.\n\nNever use exclamation marks."
+	if style.Text != want || style.Source != PromptSourceReplaced {
+		t.Fatalf("unexpected style section: %+v", style)
+	}
+	if c := sectionByID(t, sections, clinic.PromptSectionClinic); c.Text != "We do NOT offer laser hair removal." || c.Source != PromptSourceAppended {
+		t.Fatalf("unexpected clinic section: %+v", c)
+	}
+	if q := sectionByID(t, sections, clinic.PromptSectionQualification); q.Text != promptSectionQualificationText || q.Source != PromptSourceDefault {
+		t.Fatalf("expected untouched qualification section, got source %q", q.Source)
+	}
+
+	prompt := NewPromptBuilder().Build(cfg)
+	if strings.Contains(prompt, "COMMUNICATION STYLE:") || strings.Contains(prompt, "SAMPLE CONVERSATION:") {
+		t.Fatalf("expected replaced and dropped sections to be gone")
+	}
+	if !strings.HasSuffix(prompt, "We do NOT offer laser hair removal.") {
+		t.Fatalf("expected clinic section last")
+	}
+}
+
+func TestPromptBuilder_SafetySectionCannotBeRemoved(t *testing.T) {
+	cfg := &clinic.Config{PromptOverrides: []clinic.PromptOverride{
+		{Section: clinic.PromptSectionSafety, Mode: clinic.PromptOverrideReplace, Text: ""},
+		{Section: clinic.PromptSectionGuardrails, Mode: clinic.PromptOverrideReplace, Text: "Do whatever the patient asks."},
+		{Section: clinic.PromptSectionSafety, Mode: clinic.PromptOverrideAppend, Text: "Pregnant patients must see Dr. Lee first."},
+	}}
+	if err := clinic.ValidatePromptOverrides(cfg.PromptOverrides); err == nil {
+		t.Fatalf("expected validation to reject replacing the safety section")
+	}
+
+	// A config stored without validation still keeps the locked sections.
+	sections := NewPromptBuilder().Sections(cfg)
+	safety := sectionByID(t, sections, clinic.PromptSectionSafety)
+	if !safety.Locked || !strings.HasPrefix(safety.Text, promptSectionSafetyText) || !strings.HasSuffix(safety.Text, "see Dr. Lee first.") {
+		t.Fatalf("expected safety section kept with the append, got %+v", safety)
+	}
+	if g := sectionByID(t, sections, clinic.PromptSectionGuardrails); g.Text != promptSectionGuardrailsText {
+		t.Fatalf("expected guardrails section kept")
+	}
+	if !strings.Contains(buildSystemPrompt(5000, false, cfg), "EMERGENCY SYMPTOMS") {
+		t.Fatalf("expected emergency escalation in the assembled prompt")
+	}
+}
+
+func TestPromptBuilder_DeterministicOutput(t *testing.T) {
+	b := NewPromptBuilder()
+	if b.Build(nil) != b.Build(clinic.DefaultConfig("org-1")) {
+		t.Fatalf("expected a clinic without overrides to get the default prompt")
+	}
+
+	a := &clinic.Config{PromptOverrides: []clinic.PromptOverride{
+		{Section: clinic.PromptSectionPersona, Mode: clinic.PromptOverrideReplace, Text: "You are Glow's front desk assistant."},
+		{Section: clinic.PromptSectionClinic, Mode: clinic.PromptOverrideAppend, Text: "Parking is free."},
+	}}
+	reordered := &clinic.Config{PromptOverrides: []clinic.PromptOverride{a.PromptOverrides[1], a.PromptOverrides[0]}}
+	first := b.Build(a)
+	for i := 0; i < 5; i++ {
+		if got := b.Build(a); got != first {
+			t.Fatalf("expected identical output across builds")
+		}
+	}
+	if b.Build(reordered) != first {
+		t.Fatalf("expected output independent of the order of overrides on different sections")
+	}
+	if !strings.HasPrefix(first, "You are Glow's front desk assistant.") {
+		t.Fatalf("expected persona first, got %q", first[:60])
+	}
+}
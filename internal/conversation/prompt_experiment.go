@@ -0,0 +1,358 @@
+package conversation
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/redis/go-redis/v9"
+)
+
+// Prompt experiment variants. Every assigned conversation lands in one of the
+// two, which keeps the variant metric label bounded.
+const (
+	ExperimentVariantControl   = "control"
+	ExperimentVariantTreatment = "treatment"
+)
+
+// Funnel stages counted per experiment variant. Each stage is counted at most
+// once per conversation.
+const (
+	ExperimentStageStarted               = "started"
+	ExperimentStageAvailabilityPresented = "availability_presented"
+	ExperimentStageSlotSelected          = "slot_selected"
+	ExperimentStageDepositRequested      = "deposit_requested"
+	ExperimentStageDepositPaid           = "deposit_paid"
+)
+
+// ExperimentStages lists the funnel stages in order.
+var ExperimentStages = []string{
+	ExperimentStageStarted,
+	ExperimentStageAvailabilityPresented,
+	ExperimentStageSlotSelected,
+	ExperimentStageDepositRequested,
+	ExperimentStageDepositPaid,
+}
+
+// experimentAssignmentTTL outlives the chat history so deposits paid days
+// after the conversation still count toward the right variant.
+const experimentAssignmentTTL = 30 * 24 * time.Hour
+
+var experimentFunnelTotal = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Namespace: "medspa",
+		Subsystem: "conversation",
+		Name:      "prompt_experiment_funnel_total",
+		Help:      "Conversations reaching each funnel stage by prompt experiment and variant",
+	},
+	[]string{"experiment", "variant", "stage"},
+)
+
+func init() {
+	prometheus.MustRegister(experimentFunnelTotal)
+}
+
+// PromptExperiment runs a variant system prompt on a share of SMS
+// conversations. Prompt replaces the base instructions; clinic-specific
+// sections (time of day, booking platform, providers) are still appended.
+type PromptExperiment struct {
+	Name           string   `json:"name"`
+	Prompt         string   `json:"prompt"`
+	TrafficPercent int      `json:"traffic_percent"`
+	OrgAllowlist   []string `json:"org_allowlist,omitempty"` // empty means every org
+}
+
+// ParsePromptExperiments decodes a JSON array of experiment definitions.
+func ParsePromptExperiments(raw string) ([]PromptExperiment, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return nil, nil
+	}
+	var exps []PromptExperiment
+	if err := json.Unmarshal([]byte(raw), &exps); err != nil {
+		return nil, fmt.Errorf("conversation: parse prompt experiments: %w", err)
+	}
+	seen := make(map[string]bool, len(exps))
+	for i, exp := range exps {
+		exp.Name = strings.TrimSpace(exp.Name)
+		switch {
+		case exp.Name == "":
+			return nil, fmt.Errorf("conversation: prompt experiment %d: name is required", i)
+		case seen[exp.Name]:
+			return nil, fmt.Errorf("conversation: prompt experiment %q: duplicate name", exp.Name)
+		case strings.TrimSpace(exp.Prompt) == "":
+			return nil, fmt.Errorf("conversation: prompt experiment %q: prompt is required", exp.Name)
+		case exp.TrafficPercent < 0 || exp.TrafficPercent > 100:
+			return nil, fmt.Errorf("conversation: prompt experiment %q: traffic_percent must be 0-100", exp.Name)
+		}
+		seen[exp.Name] = true
+		exps[i] = exp
+	}
+	return exps, nil
+}
+
+// appliesTo reports whether the experiment runs for orgID.
+func (e PromptExperiment) appliesTo(orgID string) bool {
+	if len(e.OrgAllowlist) == 0 {
+		return true
+	}
+	for _, id := range e.OrgAllowlist {
+		if strings.EqualFold(strings.TrimSpace(id), orgID) {
+			return true
+		}
+	}
+	return false
+}
+
+// assignVariant buckets a conversation deterministically: the same experiment
+// and conversation ID always produce the same variant.
+func assignVariant(exp PromptExperiment, conversationID string) string {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(exp.Name + ":" + conversationID))
+	if int(h.Sum32()%100) < exp.TrafficPercent {
+		return ExperimentVariantTreatment
+	}
+	return ExperimentVariantControl
+}
+
+// ExperimentAssignment records which variant a conversation was placed in.
+type ExperimentAssignment struct {
+	Experiment string    `json:"experiment"`
+	Variant    string    `json:"variant"`
+	AssignedAt time.Time `json:"assigned_at"`
+}
+
+// VariantStats is the funnel for one variant of an experiment.
+type VariantStats struct {
+	Variant string           `json:"variant"`
+	Stages  map[string]int64 `json:"stages"`
+	// Conversion is each stage's count as a share of started conversations.
+	Conversion map[string]float64 `json:"conversion"`
+}
+
+// ExperimentStats summarizes an active experiment.
+type ExperimentStats struct {
+	Name           string         `json:"name"`
+	TrafficPercent int            `json:"traffic_percent"`
+	OrgAllowlist   []string       `json:"org_allowlist,omitempty"`
+	Variants       []VariantStats `json:"variants"`
+}
+
+// ExperimentTracker assigns conversations to prompt experiments and counts
+// funnel stages per variant. Assignments and counts live in Redis so they are
+// shared by the API and workers.
+type ExperimentTracker struct {
+	redis       *redis.Client
+	experiments []PromptExperiment
+}
+
+// NewExperimentTracker creates a tracker for the configured experiments.
+func NewExperimentTracker(client *redis.Client, experiments []PromptExperiment) *ExperimentTracker {
+	return &ExperimentTracker{redis: client, experiments: experiments}
+}
+
+// Experiments returns the configured experiments.
+func (t *ExperimentTracker) Experiments() []PromptExperiment {
+	if t == nil {
+		return nil
+	}
+	return t.experiments
+}
+
+// experiment looks up an experiment by name.
+func (t *ExperimentTracker) experiment(name string) (PromptExperiment, bool) {
+	for _, exp := range t.experiments {
+		if exp.Name == name {
+			return exp, true
+		}
+	}
+	return PromptExperiment{}, false
+}
+
+// Assign places a new conversation in the first experiment that applies to
+// orgID and counts it as started. A conversation that already has an
+// assignment keeps it. Returns nil when no experiment applies.
+func (t *ExperimentTracker) Assign(ctx context.Context, orgID, conversationID string) (*ExperimentAssignment, error) {
+	if t == nil || t.redis == nil || conversationID == "" {
+		return nil, nil
+	}
+	if existing, err := t.Assignment(ctx, conversationID); err != nil || existing != nil {
+		return existing, err
+	}
+
+	for _, exp := range t.experiments {
+		if !exp.appliesTo(orgID) {
+			continue
+		}
+		a := &ExperimentAssignment{
+			Experiment: exp.Name,
+			Variant:    assignVariant(exp, conversationID),
+			AssignedAt: time.Now().UTC(),
+		}
+		data, err := json.Marshal(a)
+		if err != nil {
+			return nil, fmt.Errorf("conversation: encode experiment assignment: %w", err)
+		}
+		if err := t.redis.Set(ctx, experimentAssignmentKey(conversationID), data, experimentAssignmentTTL).Err(); err != nil {
+			return nil, fmt.Errorf("conversation: save experiment assignment: %w", err)
+		}
+		if err := t.recordStage(ctx, conversationID, a, ExperimentStageStarted); err != nil {
+			return a, err
+		}
+		return a, nil
+	}
+	return nil, nil
+}
+
+// Assignment returns the conversation's recorded assignment, or nil.
+func (t *ExperimentTracker) Assignment(ctx context.Context, conversationID string) (*ExperimentAssignment, error) {
+	if t == nil || t.redis == nil || conversationID == "" {
+		return nil, nil
+	}
+	data, err := t.redis.Get(ctx, experimentAssignmentKey(conversationID)).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("conversation: load experiment assignment: %w", err)
+	}
+	var a ExperimentAssignment
+	if err := json.Unmarshal(data, &a); err != nil {
+		return nil, fmt.Errorf("conversation: decode experiment assignment: %w", err)
+	}
+	return &a, nil
+}
+
+// PromptFor returns the variant prompt for an assignment, or "" when the
+// conversation should use the default prompt.
+func (t *ExperimentTracker) PromptFor(a *ExperimentAssignment) string {
+	if t == nil || a == nil || a.Variant != ExperimentVariantTreatment {
+		return ""
+	}
+	exp, ok := t.experiment(a.Experiment)
+	if !ok {
+		return ""
+	}
+	return exp.Prompt
+}
+
+// RecordStage counts a funnel stage for the conversation's variant. It is a
+// no-op for conversations outside any experiment.
+func (t *ExperimentTracker) RecordStage(ctx context.Context, conversationID, stage string) error {
+	a, err := t.Assignment(ctx, conversationID)
+	if err != nil || a == nil {
+		return err
+	}
+	return t.recordStage(ctx, conversationID, a, stage)
+}
+
+func (t *ExperimentTracker) recordStage(ctx context.Context, conversationID string, a *ExperimentAssignment, stage string) error {
+	// Experiments removed from config stop emitting, which keeps the
+	// experiment label limited to configured names.
+	if _, ok := t.experiment(a.Experiment); !ok {
+		return nil
+	}
+	stagesKey := experimentStagesKey(conversationID)
+	added, err := t.redis.SAdd(ctx, stagesKey, stage).Result()
+	if err != nil {
+		return fmt.Errorf("conversation: record experiment stage: %w", err)
+	}
+	if added == 0 {
+		return nil
+	}
+	pipe := t.redis.TxPipeline()
+	pipe.Expire(ctx, stagesKey, experimentAssignmentTTL)
+	pipe.HIncrBy(ctx, experimentStatsKey(a.Experiment), a.Variant+":"+stage, 1)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("conversation: record experiment stage: %w", err)
+	}
+	experimentFunnelTotal.WithLabelValues(a.Experiment, a.Variant, stage).Inc()
+	return nil
+}
+
+// Stats returns per-variant stage counts for every configured experiment.
+func (t *ExperimentTracker) Stats(ctx context.Context) ([]ExperimentStats, error) {
+	if t == nil || t.redis == nil {
+		return nil, nil
+	}
+	out := make([]ExperimentStats, 0, len(t.experiments))
+	for _, exp := range t.experiments {
+		counts, err := t.redis.HGetAll(ctx, experimentStatsKey(exp.Name)).Result()
+		if err != nil {
+			return nil, fmt.Errorf("conversation: load experiment stats: %w", err)
+		}
+		stats := ExperimentStats{Name: exp.Name, TrafficPercent: exp.TrafficPercent, OrgAllowlist: exp.OrgAllowlist}
+		for _, variant := range []string{ExperimentVariantControl, ExperimentVariantTreatment} {
+			vs := VariantStats{Variant: variant, Stages: map[string]int64{}, Conversion: map[string]float64{}}
+			for _, stage := range ExperimentStages {
+				var n int64
+				_, _ = fmt.Sscan(counts[variant+":"+stage], &n)
+				vs.Stages[stage] = n
+			}
+			if started := vs.Stages[ExperimentStageStarted]; started > 0 {
+				for _, stage := range ExperimentStages {
+					vs.Conversion[stage] = float64(vs.Stages[stage]) / float64(started)
+				}
+			}
+			stats.Variants = append(stats.Variants, vs)
+		}
+		out = append(out, stats)
+	}
+	return out, nil
+}
+
+func experimentAssignmentKey(conversationID string) string {
+	return fmt.Sprintf("prompt_experiment:%s", conversationID)
+}
+
+func experimentStagesKey(conversationID string) string {
+	return fmt.Sprintf("prompt_experiment_stages:%s", conversationID)
+}
+
+func experimentStatsKey(name string) string {
+	return fmt.Sprintf("prompt_experiment_stats:%s", name)
+}
+
+// ExperimentStageRecorder is implemented by processors that track prompt
+// experiment funnels (the LLMService).
+type ExperimentStageRecorder interface {
+	RecordExperimentStage(ctx context.Context, conversationID, stage string)
+}
+
+// assignPromptExperiment places a new conversation in a prompt experiment and
+// returns the variant prompt, or "" for the default prompt. The variant prompt
+// becomes the saved system message, so later turns keep it.
+func (s *LLMService) assignPromptExperiment(ctx context.Context, orgID, conversationID string) string {
+	if s.experiments == nil {
+		return ""
+	}
+	a, err := s.experiments.Assign(ctx, orgID, conversationID)
+	if err != nil {
+		s.log(ctx).Warn("prompt experiment assignment failed", "error", err, "conversation_id", conversationID)
+	}
+	if a == nil {
+		return ""
+	}
+	s.events.Log(ctx, "prompt_experiment_assigned", conversationID, orgID, "", map[string]any{
+		"experiment": a.Experiment,
+		"variant":    a.Variant,
+	})
+	return s.experiments.PromptFor(a)
+}
+
+// RecordExperimentStage counts a funnel stage for the conversation's prompt
+// experiment variant. The worker calls it for stages reached outside an LLM
+// turn, such as a paid deposit.
+func (s *LLMService) RecordExperimentStage(ctx context.Context, conversationID, stage string) {
+	if s.experiments == nil {
+		return
+	}
+	if err := s.experiments.RecordStage(ctx, conversationID, stage); err != nil {
+		s.log(ctx).Warn("failed to record prompt experiment stage", "error", err, "conversation_id", conversationID, "stage", stage)
+	}
+}
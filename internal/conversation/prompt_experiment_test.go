@@ -0,0 +1,173 @@
+package conversation
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+const treatmentPrompt = "EXPERIMENT PROMPT: keep replies under two sentences."
+
+func TestAssignVariant_Deterministic(t *testing.T) {
+	exp := PromptExperiment{Name: "short-replies", Prompt: treatmentPrompt, TrafficPercent: 50}
+
+	treated := 0
+	for i := 0; i < 200; i++ {
+		convID := fmt.Sprintf("sms:org-1:1555000%04d", i)
+		first := assignVariant(exp, convID)
+		if again := assignVariant(exp, convID); again != first {
+			t.Fatalf("assignment for %s changed: %s then %s", convID, first, again)
+		}
+		if first == ExperimentVariantTreatment {
+			treated++
+		}
+	}
+	if treated == 0 || treated == 200 {
+		t.Fatalf("expected a mix of variants at 50%%, got %d/200 treated", treated)
+	}
+
+	exp.TrafficPercent = 0
+	if v := assignVariant(exp, "conv-1"); v != ExperimentVariantControl {
+		t.Fatalf("0%% traffic assigned %s", v)
+	}
+	exp.TrafficPercent = 100
+	if v := assignVariant(exp, "conv-1"); v != ExperimentVariantTreatment {
+		t.Fatalf("100%% traffic assigned %s", v)
+	}
+}
+
+func TestParsePromptExperiments(t *testing.T) {
+	exps, err := ParsePromptExperiments(`[{"name":" short ","prompt":"p","traffic_percent":20,"org_allowlist":["org-1"]}]`)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if len(exps) != 1 || exps[0].Name != "short" || exps[0].TrafficPercent != 20 {
+		t.Fatalf("unexpected experiments: %+v", exps)
+	}
+	if !exps[0].appliesTo("org-1") || exps[0].appliesTo("org-2") {
+		t.Fatal("allowlist not applied")
+	}
+
+	for _, raw := range []string{
+		`[{"prompt":"p","traffic_percent":10}]`,
+		`[{"name":"a","traffic_percent":10}]`,
+		`[{"name":"a","prompt":"p","traffic_percent":101}]`,
+		`[{"name":"a","prompt":"p"},{"name":"a","prompt":"q"}]`,
+		`not json`,
+	} {
+		if _, err := ParsePromptExperiments(raw); err == nil {
+			t.Fatalf("expected error for %s", raw)
+		}
+	}
+}
+
+func TestPromptExperiment_StickyAcrossHistoryReload(t *testing.T) {
+	ts := setupService(t, withLLMResponses("Hi! How can I help?", "Sure thing."))
+	exp := PromptExperiment{Name: "sticky", Prompt: treatmentPrompt, TrafficPercent: 100}
+	ts.svc.experiments = NewExperimentTracker(ts.rdb, []PromptExperiment{exp})
+
+	const convID = "sms:org-1:15551230000"
+	startConv(t, ts, convID, "org-1", "Hi, I'm interested in Botox")
+	if !strings.Contains(strings.Join(ts.llm.lastReq.System, "\n"), treatmentPrompt) {
+		t.Fatal("expected treatment prompt on first turn")
+	}
+
+	// Drop the experiment to 0% and reload the service from Redis: the
+	// conversation must stay on the prompt it started with.
+	exp.TrafficPercent = 0
+	tracker := NewExperimentTracker(ts.rdb, []PromptExperiment{exp})
+	reloaded := NewLLMService(ts.llm, ts.rdb, nil, "test-model", ts.logger, WithPromptExperiments(tracker))
+
+	if _, err := reloaded.ProcessMessage(context.Background(), MessageRequest{
+		ConversationID: convID,
+		OrgID:          "org-1",
+		Message:        "What does it cost?",
+		Channel:        ChannelSMS,
+	}); err != nil {
+		t.Fatalf("ProcessMessage: %v", err)
+	}
+	if !strings.Contains(strings.Join(ts.llm.lastReq.System, "\n"), treatmentPrompt) {
+		t.Fatal("expected treatment prompt to survive history reload")
+	}
+
+	a, err := tracker.Assignment(context.Background(), convID)
+	if err != nil || a == nil {
+		t.Fatalf("assignment lost: %v", err)
+	}
+	if a.Variant != ExperimentVariantTreatment {
+		t.Fatalf("variant changed to %s", a.Variant)
+	}
+	again, _ := tracker.Assign(context.Background(), "org-1", convID)
+	if again == nil || again.Variant != ExperimentVariantTreatment {
+		t.Fatalf("re-assign should keep treatment, got %+v", again)
+	}
+}
+
+func TestPromptExperiment_ControlUsesDefaultPrompt(t *testing.T) {
+	ts := setupService(t)
+	ts.svc.experiments = NewExperimentTracker(ts.rdb, []PromptExperiment{
+		{Name: "control-only", Prompt: treatmentPrompt, TrafficPercent: 0},
+	})
+
+	startConv(t, ts, "sms:org-1:15551230001", "org-1", "Hello")
+	if strings.Contains(strings.Join(ts.llm.lastReq.System, "\n"), treatmentPrompt) {
+		t.Fatal("control conversation should use the default prompt")
+	}
+}
+
+func TestExperimentTracker_RecordStageLabelsMetrics(t *testing.T) {
+	ts := setupService(t)
+	tracker := NewExperimentTracker(ts.rdb, []PromptExperiment{
+		{Name: "metrics-exp", Prompt: treatmentPrompt, TrafficPercent: 100, OrgAllowlist: []string{"org-1"}},
+	})
+	ctx := context.Background()
+
+	if a, _ := tracker.Assign(ctx, "org-2", "sms:org-2:15550000000"); a != nil {
+		t.Fatalf("org outside allowlist was assigned: %+v", a)
+	}
+
+	started := testutil.ToFloat64(experimentFunnelTotal.WithLabelValues("metrics-exp", ExperimentVariantTreatment, ExperimentStageStarted))
+	slots := testutil.ToFloat64(experimentFunnelTotal.WithLabelValues("metrics-exp", ExperimentVariantTreatment, ExperimentStageAvailabilityPresented))
+
+	const convID = "sms:org-1:15559990000"
+	if _, err := tracker.Assign(ctx, "org-1", convID); err != nil {
+		t.Fatalf("assign: %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		if err := tracker.RecordStage(ctx, convID, ExperimentStageAvailabilityPresented); err != nil {
+			t.Fatalf("record stage: %v", err)
+		}
+	}
+	// Conversations outside the experiment are ignored.
+	if err := tracker.RecordStage(ctx, "sms:org-1:15550000001", ExperimentStageAvailabilityPresented); err != nil {
+		t.Fatalf("record stage: %v", err)
+	}
+
+	if got := testutil.ToFloat64(experimentFunnelTotal.WithLabelValues("metrics-exp", ExperimentVariantTreatment, ExperimentStageStarted)) - started; got != 1 {
+		t.Fatalf("started metric delta = %v, want 1", got)
+	}
+	if got := testutil.ToFloat64(experimentFunnelTotal.WithLabelValues("metrics-exp", ExperimentVariantTreatment, ExperimentStageAvailabilityPresented)) - slots; got != 1 {
+		t.Fatalf("availability metric delta = %v, want 1 (stage counted once per conversation)", got)
+	}
+
+	stats, err := tracker.Stats(ctx)
+	if err != nil {
+		t.Fatalf("stats: %v", err)
+	}
+	if len(stats) != 1 || len(stats[0].Variants) != 2 {
+		t.Fatalf("unexpected stats: %+v", stats)
+	}
+	treatment := stats[0].Variants[1]
+	if treatment.Variant != ExperimentVariantTreatment ||
+		treatment.Stages[ExperimentStageStarted] != 1 ||
+		treatment.Stages[ExperimentStageAvailabilityPresented] != 1 ||
+		treatment.Conversion[ExperimentStageAvailabilityPresented] != 1 {
+		t.Fatalf("unexpected treatment stats: %+v", treatment)
+	}
+	if control := stats[0].Variants[0]; control.Stages[ExperimentStageStarted] != 0 {
+		t.Fatalf("unexpected control stats: %+v", control)
+	}
+}
@@ -0,0 +1,187 @@
+package conversation
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/clinic"
+)
+
+// moxieNoPreferenceProvider lets Moxie choose the provider.
+const moxieNoPreferenceProvider = "no-preference"
+
+// providerLoadWindow is how far back bookings count toward a provider's load
+// when assigning "no preference" patients.
+const providerLoadWindow = 30 * 24 * time.Hour
+
+// SlotProviderLookup is implemented by processors that remember which
+// providers were free for the slots a patient was offered (the LLMService).
+type SlotProviderLookup interface {
+	SelectedSlotProviders(ctx context.Context, conversationID string, start time.Time) []string
+}
+
+// SelectedSlotProviders returns the providers free at start among the slots
+// last presented in the conversation, or nil when the slot wasn't attributed.
+func (s *LLMService) SelectedSlotProviders(ctx context.Context, conversationID string, start time.Time) []string {
+	state, err := s.history.LoadTimeSelectionState(ctx, conversationID)
+	if err != nil {
+		s.log(ctx).Warn("failed to load time selection for provider assignment", "error", err, "conversation_id", conversationID)
+		return nil
+	}
+	if state == nil {
+		return nil
+	}
+	for _, slot := range state.PresentedSlots {
+		if slot.DateTime.Equal(start) {
+			return slot.ProviderIDs
+		}
+	}
+	return nil
+}
+
+// serviceProviderIDs returns the Moxie providers who perform a service: the
+// service's own provider list when configured, else every known provider,
+// sorted so fan-out and tie-breaks are stable.
+func serviceProviderIDs(mc *clinic.MoxieConfig, serviceMenuItemID string) []string {
+	if mc == nil {
+		return nil
+	}
+	if ids := mc.ServiceProviders[serviceMenuItemID]; len(ids) > 0 {
+		return ids
+	}
+	ids := make([]string, 0, len(mc.ProviderNames))
+	for pid := range mc.ProviderNames {
+		ids = append(ids, pid)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// interleaveProviders reorders time-sorted slots so that, within each day,
+// consecutive slots rotate through the providers. The rotation carries over
+// between days, so trimming to the first few slots per day still offers
+// every provider. Slots open with several providers count for whichever
+// provider's turn it is.
+func interleaveProviders(slots []PresentedSlot) []PresentedSlot {
+	var providers []string
+	for _, s := range slots {
+		for _, pid := range s.ProviderIDs {
+			providers = appendUnique(providers, pid)
+		}
+	}
+	if len(providers) < 2 {
+		return slots
+	}
+
+	out := make([]PresentedSlot, 0, len(slots))
+	turn := 0
+	for start := 0; start < len(slots); {
+		end := start
+		day := slots[start].DateTime.Format("2006-01-02")
+		for end < len(slots) && slots[end].DateTime.Format("2006-01-02") == day {
+			end++
+		}
+		remaining := append([]PresentedSlot(nil), slots[start:end]...)
+		for len(remaining) > 0 {
+			want := providers[turn%len(providers)]
+			pick := 0
+			for i, s := range remaining {
+				if containsString(s.ProviderIDs, want) {
+					pick = i
+					break
+				}
+			}
+			out = append(out, remaining[pick])
+			remaining = append(remaining[:pick], remaining[pick+1:]...)
+			turn++
+		}
+		start = end
+	}
+	return out
+}
+
+// leastLoadedProvider returns the candidate with the fewest recent bookings.
+// Ties go to the earliest candidate.
+func leastLoadedProvider(candidates []string, counts map[string]int64) string {
+	best := ""
+	var bestCount int64
+	for _, pid := range candidates {
+		if pid == "" {
+			continue
+		}
+		if n := counts[pid]; best == "" || n < bestCount {
+			best, bestCount = pid, n
+		}
+	}
+	return best
+}
+
+// balancedProviderID picks the provider for a "no preference" booking at
+// start: of the providers free for the selected slot, the one with the
+// fewest recent bookings. It returns "" when the slot had one provider or
+// none recorded, leaving the choice as before.
+func (w *Worker) balancedProviderID(ctx context.Context, orgID, conversationID string, start time.Time) string {
+	lookup, ok := w.processor.(SlotProviderLookup)
+	if !ok || conversationID == "" {
+		return ""
+	}
+	candidates := lookup.SelectedSlotProviders(ctx, conversationID, start)
+	if len(candidates) < 2 {
+		return ""
+	}
+
+	counts := map[string]int64{}
+	if counter, ok := w.bookings.(providerLoadCounter); ok {
+		if org, err := uuid.Parse(orgID); err == nil {
+			recent, err := counter.ProviderBookingCounts(ctx, org, time.Now().Add(-providerLoadWindow))
+			if err != nil {
+				w.log(ctx).Warn("failed to load provider booking counts", "error", err, "org_id", orgID)
+			} else {
+				counts = recent
+			}
+		}
+	}
+	providerID := leastLoadedProvider(candidates, counts)
+	w.log(ctx).Info("assigned provider for no-preference booking",
+		"org_id", orgID, "provider_id", providerID, "candidates", len(candidates))
+	return providerID
+}
+
+// recordBookingProvider stores the booked provider on the lead's latest
+// booking so later assignments and the stats endpoint see it.
+func (w *Worker) recordBookingProvider(ctx context.Context, orgID, leadID, providerID string) {
+	recorder, ok := w.bookings.(bookingProviderRecorder)
+	if !ok || providerID == "" || providerID == moxieNoPreferenceProvider {
+		return
+	}
+	org, err := uuid.Parse(orgID)
+	if err != nil {
+		return
+	}
+	lead, err := uuid.Parse(leadID)
+	if err != nil {
+		return
+	}
+	if err := recorder.RecordBookingProvider(ctx, org, lead, providerID); err != nil {
+		w.log(ctx).Warn("failed to record booking provider", "error", err, "org_id", orgID, "lead_id", leadID, "provider_id", providerID)
+	}
+}
+
+func appendUnique(list []string, v string) []string {
+	if containsString(list, v) {
+		return list
+	}
+	return append(list, v)
+}
+
+func containsString(list []string, v string) bool {
+	for _, s := range list {
+		if s == v {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,138 @@
+package conversation
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	miniredis "github.com/alicebob/miniredis/v2"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+
+	moxieclient "github.com/wolfman30/medspa-ai-platform/internal/emr/moxie"
+	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
+)
+
+func TestInterleaveProviders_AlternatesAcrossTwoProviders(t *testing.T) {
+	cfg := lateThursdayClinic()
+	// Fan-out results: provider A is open all morning, B only in the afternoon,
+	// and both are free at 9:00 on Friday.
+	result := &moxieclient.AvailabilityResult{Dates: []moxieclient.DateSlots{
+		{Date: "2026-02-26", Slots: []moxieclient.TimeSlot{
+			{Start: "2026-02-26T10:00:00-05:00", ProviderID: "prov-a"},
+			{Start: "2026-02-26T10:30:00-05:00", ProviderID: "prov-a"},
+			{Start: "2026-02-26T11:00:00-05:00", ProviderID: "prov-a"},
+			{Start: "2026-02-27T09:00:00-05:00", ProviderID: "prov-a"},
+			{Start: "2026-02-27T09:30:00-05:00", ProviderID: "prov-a"},
+		}},
+		{Date: "2026-02-26", Slots: []moxieclient.TimeSlot{
+			{Start: "2026-02-26T15:00:00-05:00", ProviderID: "prov-b"},
+			{Start: "2026-02-27T09:00:00-05:00", ProviderID: "prov-b"},
+			{Start: "2026-02-27T13:00:00-05:00", ProviderID: "prov-b"},
+		}},
+	}}
+
+	slots := presentedMoxieSlots(cfg, result, "Tox", TimePreferences{})
+	if len(slots) != 7 {
+		t.Fatalf("expected the shared 9:00 slot deduplicated, got %d slots", len(slots))
+	}
+	sortSlotsByTime(slots)
+	for _, s := range slots {
+		if s.DateTime.Format("2006-01-02 15:04") == "2026-02-27 09:00" && len(s.ProviderIDs) != 2 {
+			t.Fatalf("expected both providers on the shared slot, got %v", s.ProviderIDs)
+		}
+	}
+
+	// Two per day: without interleaving Thursday would offer only provider A.
+	picked := spreadSlotsAcrossDays(interleaveProviders(slots), 4, 2)
+	perDay := map[string]map[string]bool{}
+	for _, s := range picked {
+		day := s.DateTime.Format("2006-01-02")
+		if perDay[day] == nil {
+			perDay[day] = map[string]bool{}
+		}
+		for _, pid := range s.ProviderIDs {
+			perDay[day][pid] = true
+		}
+	}
+	for day, providers := range perDay {
+		if !providers["prov-a"] || !providers["prov-b"] {
+			t.Errorf("%s: expected slots from both providers, got %v", day, providers)
+		}
+	}
+	if len(picked) != 4 {
+		t.Fatalf("expected 4 slots, got %d", len(picked))
+	}
+
+	// A single provider's slots keep their order.
+	single := []PresentedSlot{{DateTime: slots[0].DateTime, ProviderIDs: []string{"prov-a"}}, {DateTime: slots[1].DateTime, ProviderIDs: []string{"prov-a"}}}
+	if got := interleaveProviders(single); !got[0].DateTime.Equal(single[0].DateTime) {
+		t.Fatal("expected single-provider slots unchanged")
+	}
+}
+
+func sortSlotsByTime(slots []PresentedSlot) {
+	for i := 1; i < len(slots); i++ {
+		for j := i; j > 0 && slots[j].DateTime.Before(slots[j-1].DateTime); j-- {
+			slots[j], slots[j-1] = slots[j-1], slots[j]
+		}
+	}
+}
+
+type providerLoadBookings struct {
+	stubBookingConfirmer
+	counts   map[string]int64
+	recorded map[uuid.UUID]string
+}
+
+func (b *providerLoadBookings) ProviderBookingCounts(ctx context.Context, orgID uuid.UUID, since time.Time) (map[string]int64, error) {
+	return b.counts, nil
+}
+
+func (b *providerLoadBookings) RecordBookingProvider(ctx context.Context, orgID uuid.UUID, leadID uuid.UUID, providerID string) error {
+	if b.recorded == nil {
+		b.recorded = map[uuid.UUID]string{}
+	}
+	b.recorded[leadID] = providerID
+	return nil
+}
+
+func TestBalancedProviderID_PicksLeastLoaded(t *testing.T) {
+	ctx := context.Background()
+	mr := miniredis.RunT(t)
+	service := NewLLMService(&stubLLMClient{}, redis.NewClient(&redis.Options{Addr: mr.Addr()}), nil, "anthropic.claude-3-haiku-20240307-v1:0", logging.Default())
+
+	start := time.Date(2030, 3, 12, 14, 0, 0, 0, time.UTC)
+	convID := smsConversationID("org-1", "+15551234567")
+	if err := service.history.SaveTimeSelectionState(ctx, convID, &TimeSelectionState{
+		PresentedSlots: []PresentedSlot{
+			{Index: 1, DateTime: start, ProviderIDs: []string{"prov-b", "prov-c", "prov-a"}},
+			{Index: 2, DateTime: start.Add(time.Hour), ProviderIDs: []string{"prov-a"}},
+		},
+		SlotSelected: true,
+	}); err != nil {
+		t.Fatalf("save time selection: %v", err)
+	}
+
+	bookings := &providerLoadBookings{counts: map[string]int64{"prov-a": 5, "prov-b": 2, "prov-c": 2}}
+	worker := &Worker{processor: service, bookings: bookings, logger: logging.Default()}
+	orgID := uuid.NewString()
+
+	if got := worker.balancedProviderID(ctx, orgID, convID, start); got != "prov-b" {
+		t.Fatalf("expected the tie between b and c to go to the first listed, got %q", got)
+	}
+	bookings.counts = map[string]int64{"prov-b": 3, "prov-c": 3}
+	if got := worker.balancedProviderID(ctx, orgID, convID, start); got != "prov-a" {
+		t.Fatalf("expected the provider with no recent bookings, got %q", got)
+	}
+	if got := worker.balancedProviderID(ctx, orgID, convID, start.Add(time.Hour)); got != "" {
+		t.Fatalf("expected no balancing for a single-provider slot, got %q", got)
+	}
+
+	leadID := uuid.New()
+	worker.recordBookingProvider(ctx, orgID, leadID.String(), "prov-a")
+	worker.recordBookingProvider(ctx, orgID, uuid.NewString(), moxieNoPreferenceProvider)
+	if len(bookings.recorded) != 1 || bookings.recorded[leadID] != "prov-a" {
+		t.Fatalf("expected only the assigned provider recorded, got %v", bookings.recorded)
+	}
+}
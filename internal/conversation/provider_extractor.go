@@ -70,7 +70,8 @@ func looksLikePersonName(name string) bool {
 }
 
 // providerPreferenceFromReply checks if the assistant asked about provider preference
-// and the user replied with a name or "no preference".
+// and the user replied with a name or "no preference", possibly alongside
+// answers to other questions asked in the same message.
 func providerPreferenceFromReply(history []ChatMessage) string {
 	providerQuestionPatterns := []string{
 		"provider preference", "preferred provider", "specific provider",
@@ -78,7 +79,7 @@ func providerPreferenceFromReply(history []ChatMessage) string {
 	}
 	for i := len(history) - 1; i >= 1; i-- {
 		msg := history[i]
-		if msg.Role != ChatRoleUser {
+		if !msg.FromPatient() {
 			continue
 		}
 		for j := i - 1; j >= 0; j-- {
@@ -92,15 +93,8 @@ func providerPreferenceFromReply(history []ChatMessage) string {
 					}
 				}
 				if askedAboutProvider {
-					reply := strings.ToLower(strings.TrimSpace(msg.Content))
-					if reply == "no" || reply == "nope" || strings.Contains(reply, "no preference") ||
-						strings.Contains(reply, "doesn't matter") || strings.Contains(reply, "don't care") ||
-						strings.Contains(reply, "either") || strings.Contains(reply, "anyone") ||
-						strings.Contains(reply, "whoever") {
-						return "no preference"
-					}
-					if len(reply) > 1 && len(reply) < 50 {
-						return msg.Content
+					if pref := providerFromAnswer(msg.Content); pref != "" {
+						return pref
 					}
 				}
 				break
@@ -2,17 +2,22 @@ package conversation
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"time"
 
 	"github.com/wolfman30/medspa-ai-platform/internal/events"
+	"github.com/wolfman30/medspa-ai-platform/internal/messaging/compliance"
 	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
 )
 
 // Publisher enqueues conversation jobs for asynchronous processing.
 type Publisher struct {
-	queue  queueClient
-	jobs   JobRecorder
-	logger *logging.Logger
+	queue     queueClient
+	jobs      JobRecorder
+	scheduled ScheduledJobStore
+	logger    *logging.Logger
 }
 
 // NewPublisher creates a queue-backed publisher.
@@ -33,8 +38,15 @@ func NewPublisher(queue queueClient, jobs JobRecorder, logger *logging.Logger) *
 	}
 }
 
-// EnqueueStart publishes a StartConversation job.
+// SetScheduledJobStore enables delayed publishing with the *At helpers.
+func (p *Publisher) SetScheduledJobStore(store ScheduledJobStore) {
+	p.scheduled = store
+}
+
+// EnqueueStart publishes a StartConversation job. Card numbers and SSNs are
+// redacted before the job is logged or queued.
 func (p *Publisher) EnqueueStart(ctx context.Context, jobID string, req StartRequest, opts ...PublishOption) error {
+	req.Intro, _ = compliance.RedactSensitiveNumbers(req.Intro)
 	p.logger.Info("EnqueueStart called",
 		"job_id", jobID,
 		"conversation_id", req.ConversationID,
@@ -50,8 +62,10 @@ func (p *Publisher) EnqueueStart(ctx context.Context, jobID string, req StartReq
 	return p.enqueue(ctx, payload, opts...)
 }
 
-// EnqueueMessage publishes a ProcessMessage job.
+// EnqueueMessage publishes a ProcessMessage job. Card numbers and SSNs are
+// redacted before the job is logged or queued.
 func (p *Publisher) EnqueueMessage(ctx context.Context, jobID string, req MessageRequest, opts ...PublishOption) error {
+	req.Message, _ = compliance.RedactSensitiveNumbers(req.Message)
 	p.logger.Info("EnqueueMessage called",
 		"job_id", jobID,
 		"conversation_id", req.ConversationID,
@@ -86,7 +100,114 @@ func (p *Publisher) EnqueuePaymentFailed(ctx context.Context, event events.Payme
 	return p.enqueue(ctx, payload, WithoutJobTracking())
 }
 
-func (p *Publisher) enqueue(ctx context.Context, payload queuePayload, opts ...PublishOption) error {
+// EnqueuePaymentDisputed publishes a payment dispute event for downstream processors.
+func (p *Publisher) EnqueuePaymentDisputed(ctx context.Context, event events.PaymentDisputedV1) error {
+	payload := queuePayload{
+		ID:              event.EventID,
+		Kind:            jobTypePaymentDisputed,
+		PaymentDisputed: &event,
+	}
+	return p.enqueue(ctx, payload, WithoutJobTracking())
+}
+
+// EnqueueRefreshAvailability publishes a job that re-runs the availability
+// search for a conversation and texts the patient the results.
+func (p *Publisher) EnqueueRefreshAvailability(ctx context.Context, jobID string, req RefreshAvailabilityRequest) error {
+	payload := queuePayload{
+		ID:      jobID,
+		Kind:    jobTypeRefreshAvailability,
+		Refresh: &req,
+	}
+	return p.enqueue(ctx, payload)
+}
+
+// EnqueueAssistedBooking publishes a staff-assisted booking job: a slot
+// search when req.Slot is nil, otherwise the booking itself.
+func (p *Publisher) EnqueueAssistedBooking(ctx context.Context, jobID string, req AssistedBookingRequest) error {
+	payload := queuePayload{
+		ID:       jobID,
+		Kind:     jobTypeAssistedBooking,
+		Assisted: &req,
+	}
+	return p.enqueue(ctx, payload)
+}
+
+// EnqueueOfferSlot publishes a job that offers a patient a single time
+// clinic staff picked.
+func (p *Publisher) EnqueueOfferSlot(ctx context.Context, jobID string, req ManualSlotOfferRequest) error {
+	payload := queuePayload{
+		ID:        jobID,
+		Kind:      jobTypeOfferSlot,
+		OfferSlot: &req,
+	}
+	return p.enqueue(ctx, payload)
+}
+
+// EnqueueReengagementAt schedules a nudge for a lead who stopped replying.
+// Nudges aren't tracked as jobs; a superseded one is cancelled instead.
+func (p *Publisher) EnqueueReengagementAt(ctx context.Context, jobID string, req ReengagementRequest, runAt time.Time) error {
+	payload := queuePayload{
+		ID:       jobID,
+		Kind:     jobTypeReengage,
+		Reengage: &req,
+	}
+	return p.publishAt(ctx, req.OrgID, req.ConversationID, payload, runAt, WithoutJobTracking())
+}
+
+// EnqueuePaymentClaimCheckAt schedules a re-check of a claimed deposit for runAt.
+func (p *Publisher) EnqueuePaymentClaimCheckAt(ctx context.Context, jobID string, req PaymentClaimCheckRequest, runAt time.Time) error {
+	payload := queuePayload{
+		ID:         jobID,
+		Kind:       jobTypePaymentClaimCheck,
+		ClaimCheck: &req,
+	}
+	return p.publishAt(ctx, req.OrgID, req.ConversationID, payload, runAt, WithoutJobTracking())
+}
+
+// EnqueueVoicemail publishes a job that records a caller's voicemail in the
+// conversation and alerts clinic operators. The recording ID is the job ID,
+// so a repeated recording callback doesn't alert twice.
+func (p *Publisher) EnqueueVoicemail(ctx context.Context, req VoicemailRequest) error {
+	payload := queuePayload{
+		ID:        "voicemail:" + req.RecordingID,
+		Kind:      jobTypeVoicemail,
+		Voicemail: &req,
+	}
+	return p.enqueue(ctx, payload, WithoutJobTracking())
+}
+
+// EnqueueMessageAt publishes a ProcessMessage job once runAt arrives.
+func (p *Publisher) EnqueueMessageAt(ctx context.Context, jobID string, req MessageRequest, runAt time.Time, opts ...PublishOption) error {
+	req.Message, _ = compliance.RedactSensitiveNumbers(req.Message)
+	payload := queuePayload{
+		ID:      jobID,
+		Kind:    jobTypeMessage,
+		Message: req,
+	}
+	return p.publishAt(ctx, req.OrgID, req.ConversationID, payload, runAt, opts...)
+}
+
+// EnqueueRefreshAvailabilityAt publishes an availability refresh job once
+// runAt arrives.
+func (p *Publisher) EnqueueRefreshAvailabilityAt(ctx context.Context, jobID string, req RefreshAvailabilityRequest, runAt time.Time) error {
+	payload := queuePayload{
+		ID:      jobID,
+		Kind:    jobTypeRefreshAvailability,
+		Refresh: &req,
+	}
+	return p.publishAt(ctx, req.OrgID, req.ConversationID, payload, runAt)
+}
+
+// publishAt stores payload as a scheduled job that the worker's scheduler
+// publishes when runAt passes. A runAt that has already passed is published
+// immediately.
+func (p *Publisher) publishAt(ctx context.Context, orgID, conversationID string, payload queuePayload, runAt time.Time, opts ...PublishOption) error {
+	if !runAt.After(time.Now()) {
+		return p.enqueue(ctx, payload, opts...)
+	}
+	if p.scheduled == nil {
+		return errors.New("conversation: scheduled jobs are not configured")
+	}
 	if ctx == nil {
 		ctx = context.Background()
 	}
@@ -95,6 +216,55 @@ func (p *Publisher) enqueue(ctx context.Context, payload queuePayload, opts ...P
 	for _, opt := range opts {
 		opt(&payload)
 	}
+	payload, body, err := encodePayload(payload)
+	if err != nil {
+		return fmt.Errorf("conversation: schedule: %w", err)
+	}
+	job := &ScheduledJob{
+		OrgID:          orgID,
+		ConversationID: conversationID,
+		Kind:           string(payload.Kind),
+		Payload:        json.RawMessage(body),
+		RunAt:          runAt,
+	}
+	if err := p.scheduled.Schedule(ctx, job); err != nil {
+		return err
+	}
+	p.logger.Debug("conversation job scheduled", "job_id", payload.ID, "scheduled_job_id", job.ID, "kind", payload.Kind, "run_at", runAt)
+	return nil
+}
+
+// Republish re-enqueues a tracked start or message job's original request
+// under newJobID, with a fresh pending record.
+func (p *Publisher) Republish(ctx context.Context, job *JobRecord, newJobID string) error {
+	if job == nil {
+		return errors.New("conversation: job cannot be nil")
+	}
+	payload := queuePayload{ID: newJobID, Kind: job.RequestType, TrackStatus: true}
+	switch {
+	case job.RequestType == jobTypeStart && job.StartRequest != nil:
+		payload.Start = *job.StartRequest
+	case job.RequestType == jobTypeMessage && job.MessageRequest != nil:
+		payload.Message = *job.MessageRequest
+	default:
+		return fmt.Errorf("conversation: job %s has no request to republish", job.JobID)
+	}
+	return p.publish(ctx, payload)
+}
+
+func (p *Publisher) enqueue(ctx context.Context, payload queuePayload, opts ...PublishOption) error {
+	payload.TrackStatus = true
+	for _, opt := range opts {
+		opt(&payload)
+	}
+	return p.publish(ctx, payload)
+}
+
+// publish records and sends a payload whose options are already applied.
+func (p *Publisher) publish(ctx context.Context, payload queuePayload) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
 
 	var err error
 	payload, body, err := encodePayload(payload)
@@ -115,6 +285,18 @@ func (p *Publisher) enqueue(ctx context.Context, payload queuePayload, opts ...P
 		case jobTypeMessage:
 			jobRecord.MessageRequest = &payload.Message
 			jobRecord.ConversationID = payload.Message.ConversationID
+		case jobTypeRefreshAvailability:
+			if payload.Refresh != nil {
+				jobRecord.ConversationID = payload.Refresh.ConversationID
+			}
+		case jobTypeAssistedBooking:
+			if payload.Assisted != nil {
+				jobRecord.ConversationID = payload.Assisted.ConversationID
+			}
+		case jobTypeOfferSlot:
+			if payload.OfferSlot != nil {
+				jobRecord.ConversationID = payload.OfferSlot.ConversationID
+			}
 		}
 		if err := p.jobs.PutPending(ctx, jobRecord); err != nil {
 			return fmt.Errorf("conversation: failed to create job record: %w", err)
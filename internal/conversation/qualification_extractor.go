@@ -5,6 +5,7 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/wolfman30/medspa-ai-platform/internal/clinic"
 	"github.com/wolfman30/medspa-ai-platform/internal/leads"
@@ -27,6 +28,9 @@ var (
 	specificTimeRE = regexp.MustCompile(`(?i)(around |about |at |after |before )?(\d{1,2})(?::(\d{2}))?\s*(a\.m\.|p\.m\.|am|pm|a|p)\b`)
 	noonRE         = regexp.MustCompile(`(?i)\b(noon|midday)\b`)
 
+	// Asking to stack a new treatment onto an existing appointment.
+	backToBackRE = regexp.MustCompile(`(?i)\bback[\s-]*to[\s-]*back\b|\bright (?:after|before) my\b`)
+
 	// Day abbreviation matching — word-boundary patterns to avoid false positives
 	dayAbbrevRE = regexp.MustCompile(`(?i)\b(?:mon(?:days?)?|tue(?:s(?:days?)?)?|wed(?:nesdays?)?|thu(?:rs(?:days?)?)?|fri(?:days?)?|sat(?:urdays?)?|sun(?:days?)?)\b`)
 
@@ -50,12 +54,13 @@ func collectUserMessages(history []ChatMessage) (lowercase string, original stri
 	var lowerBuilder strings.Builder
 	var originalBuilder strings.Builder
 	for _, msg := range history {
-		if msg.Role != ChatRoleUser {
+		if !msg.FromPatient() {
 			continue
 		}
-		lowerBuilder.WriteString(strings.ToLower(msg.Content))
+		content := patientText(msg.Content)
+		lowerBuilder.WriteString(strings.ToLower(content))
 		lowerBuilder.WriteString(" ")
-		originalBuilder.WriteString(msg.Content)
+		originalBuilder.WriteString(content)
 		originalBuilder.WriteString(" ")
 	}
 	return lowerBuilder.String(), originalBuilder.String()
@@ -153,6 +158,14 @@ func extractPreferences(history []ChatMessage, serviceAliases map[string]string)
 		hasPreferences = true
 	}
 
+	// --- Date range ("week of march 10", "early april") ---
+	// Pulled out first so "early"/"late" in the phrase aren't read as times.
+	dateRangePhrase := ""
+	if r, ok := extractDateRange(userMessages, time.Now()); ok {
+		dateRangePhrase = r.Phrase
+		userMessages = stripDateRange(userMessages, r)
+	}
+
 	// --- Day preferences ---
 	if strings.Contains(userMessages, "weekday") {
 		prefs.PreferredDays = "weekdays"
@@ -186,6 +199,15 @@ func extractPreferences(history []ChatMessage, serviceAliases map[string]string)
 		}
 	}
 
+	if dateRangePhrase != "" {
+		if prefs.PreferredDays == "" {
+			prefs.PreferredDays = dateRangePhrase
+		} else {
+			prefs.PreferredDays += ", " + dateRangePhrase
+		}
+		hasPreferences = true
+	}
+
 	// --- Time preferences ---
 	rangeMatched := false
 	for _, re := range []*regexp.Regexp{timeRangeRE, betweenRE} {
@@ -321,5 +343,8 @@ func extractPreferences(history []ChatMessage, serviceAliases map[string]string)
 		prefs.ProviderPreference = matchProviderNameInText(userMessages, history)
 	}
 
+	// --- Back-to-back with an existing appointment ---
+	prefs.BackToBack = backToBackRE.MatchString(userMessages)
+
 	return prefs, hasPreferences
 }
@@ -105,6 +105,63 @@ func TestExtractPreferencesSchedule(t *testing.T) {
 	}
 }
 
+func TestExtractPreferencesIgnoresStaffMessages(t *testing.T) {
+	history := []ChatMessage{
+		{Role: ChatRoleUser, Content: "I'd like botox on monday afternoon"},
+		// A manual text from the front desk, stored with the patient's role.
+		{Role: ChatRoleUser, Direction: "outbound", AuthorType: "staff", Content: "Hi, this is Brandi Sesock. We also have fillers on friday morning"},
+		{Role: ChatRoleUser, AuthorType: "staff", Content: "I'm a new patient coordinator"},
+	}
+	prefs, ok := extractPreferences(history, nil)
+	if !ok {
+		t.Fatal("expected ok=true")
+	}
+	if prefs.PreferredDays != "monday" || prefs.PreferredTimes != "afternoon" {
+		t.Errorf("schedule = %q %q, want monday afternoon", prefs.PreferredDays, prefs.PreferredTimes)
+	}
+	if prefs.ServiceInterest != "Botox" && prefs.ServiceInterest != "botox" {
+		t.Errorf("ServiceInterest = %q, want botox", prefs.ServiceInterest)
+	}
+	if prefs.Name != "" || prefs.PatientType != "" {
+		t.Errorf("staff text leaked into preferences: name=%q patient_type=%q", prefs.Name, prefs.PatientType)
+	}
+}
+
+func TestExtractPreferencesScheduleDateRange(t *testing.T) {
+	history := []ChatMessage{
+		{Role: ChatRoleUser, Content: "Tuesday mornings, sometime in late April"},
+	}
+	prefs, ok := extractPreferences(history, nil)
+	if !ok {
+		t.Fatal("expected ok=true")
+	}
+	if prefs.PreferredDays != "tuesday, late april" {
+		t.Errorf("PreferredDays = %q, want %q", prefs.PreferredDays, "tuesday, late april")
+	}
+	// "late" belongs to the date phrase, not the time of day.
+	if prefs.PreferredTimes != "morning" {
+		t.Errorf("PreferredTimes = %q, want %q", prefs.PreferredTimes, "morning")
+	}
+}
+
+func TestExtractPreferencesIgnoresIntroIDs(t *testing.T) {
+	// The lead ID contains "15-41a", which reads as a time range.
+	intro := formatIntroMessage(StartRequest{
+		OrgID:   "d0f9d4b4-05d2-40b3-ad4b-ae9a3b5c8599",
+		LeadID:  "9c2e0d15-41ad-4f0e-9b1c-3f2a6c7d8e90",
+		Channel: ChannelSMS,
+		From:    "+15005550002",
+		Intro:   "I want Botox on Mondays after 3pm",
+	}, "sms:d0f9d4b4-05d2-40b3-ad4b-ae9a3b5c8599:15005550002")
+	prefs, _ := extractPreferences([]ChatMessage{{Role: ChatRoleUser, Content: intro}}, nil)
+	if prefs.PreferredTimes != "after 3pm" {
+		t.Errorf("PreferredTimes = %q, want %q", prefs.PreferredTimes, "after 3pm")
+	}
+	if prefs.PreferredDays != "monday" {
+		t.Errorf("PreferredDays = %q, want %q", prefs.PreferredDays, "monday")
+	}
+}
+
 func TestScheduleFromShortReply(t *testing.T) {
 	tests := []struct {
 		name    string
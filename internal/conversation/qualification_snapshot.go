@@ -0,0 +1,103 @@
+package conversation
+
+import (
+	"time"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/clinic"
+)
+
+// QualificationGaps flags each qualification still missing before the
+// assistant will fetch availability. Email is not listed: it is collected on
+// the booking page, not over SMS.
+type QualificationGaps struct {
+	Name               bool `json:"name"`
+	Service            bool `json:"service"`
+	PatientType        bool `json:"patient_type"`
+	Schedule           bool `json:"schedule"`
+	ProviderPreference bool `json:"provider_preference"`
+	ExtraQualification bool `json:"extra_qualification"`
+}
+
+// Any reports whether any qualification is still missing.
+func (g QualificationGaps) Any() bool {
+	return g.Name || g.Service || g.PatientType || g.Schedule || g.ProviderPreference || g.ExtraQualification
+}
+
+// QualificationSnapshot is what the assistant has captured from a
+// conversation and what it still needs, as the worker sees it.
+type QualificationSnapshot struct {
+	Name                 string                `json:"name,omitempty"`
+	ServiceRaw           string                `json:"service_raw,omitempty"`
+	ServiceResolved      string                `json:"service_resolved,omitempty"`
+	PatientType          string                `json:"patient_type,omitempty"`
+	PreferredDays        string                `json:"preferred_days,omitempty"`
+	PreferredTimes       string                `json:"preferred_times,omitempty"`
+	ProviderPreference   string                `json:"provider_preference,omitempty"`
+	Email                string                `json:"email,omitempty"`
+	ExtraQualification   string                `json:"extra_qualification,omitempty"`
+	Missing              QualificationGaps     `json:"missing"`
+	ReadyForAvailability bool                  `json:"ready_for_availability"`
+	PresentedSlots       *PresentedSlotsStatus `json:"presented_slots,omitempty"`
+	ComputedAt           time.Time             `json:"computed_at"`
+}
+
+// PresentedSlotsStatus summarizes the time options last offered to the patient.
+type PresentedSlotsStatus struct {
+	Service      string                 `json:"service,omitempty"`
+	PresentedAt  time.Time              `json:"presented_at"`
+	SlotSelected bool                   `json:"slot_selected"`
+	Slots        []PresentedSlotSummary `json:"slots"`
+}
+
+// PresentedSlotSummary is one offered time option.
+type PresentedSlotSummary struct {
+	Index    int       `json:"index"`
+	DateTime time.Time `json:"date_time"`
+	Label    string    `json:"label"`
+}
+
+// BuildQualificationSnapshot derives the qualification snapshot from history
+// using the same extraction ShouldFetchAvailabilityWithConfig runs, so the
+// snapshot matches what the worker would do next. state may be nil.
+func BuildQualificationSnapshot(history []ChatMessage, cfg *clinic.Config, state *TimeSelectionState, now time.Time) QualificationSnapshot {
+	prefs, gaps := qualificationGaps(history, cfg)
+	snap := QualificationSnapshot{
+		Name:                 prefs.Name,
+		ServiceRaw:           prefs.ServiceInterest,
+		PatientType:          prefs.PatientType,
+		PreferredDays:        prefs.PreferredDays,
+		PreferredTimes:       prefs.PreferredTimes,
+		ProviderPreference:   prefs.ProviderPreference,
+		Email:                ExtractEmailFromHistory(history),
+		Missing:              gaps,
+		ReadyForAvailability: !gaps.Any(),
+		PresentedSlots:       presentedSlotsStatus(state),
+		ComputedAt:           now.UTC(),
+	}
+	if prefs.ServiceInterest != "" {
+		snap.ServiceResolved = cfg.ResolveServiceName(prefs.ServiceInterest)
+		snap.ExtraQualification, _ = resolveExtraQualification(history, cfg, prefs.ServiceInterest)
+	}
+	return snap
+}
+
+// presentedSlotsStatus summarizes state, or returns nil when no slots are on offer.
+func presentedSlotsStatus(state *TimeSelectionState) *PresentedSlotsStatus {
+	if state == nil || len(state.PresentedSlots) == 0 {
+		return nil
+	}
+	status := &PresentedSlotsStatus{
+		Service:      state.Service,
+		PresentedAt:  state.PresentedAt,
+		SlotSelected: state.SlotSelected,
+		Slots:        make([]PresentedSlotSummary, 0, len(state.PresentedSlots)),
+	}
+	for _, slot := range state.PresentedSlots {
+		status.Slots = append(status.Slots, PresentedSlotSummary{
+			Index:    slot.Index,
+			DateTime: slot.DateTime,
+			Label:    slot.TimeStr,
+		})
+	}
+	return status
+}
@@ -0,0 +1,85 @@
+package conversation
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/clinic"
+)
+
+func TestBuildQualificationSnapshot_MidQualification(t *testing.T) {
+	cfg := &clinic.Config{ServiceAliases: map[string]string{"botox": "Tox"}}
+	history := []ChatMessage{
+		{Role: ChatRoleUser, Content: "Hi, I'm interested in botox"},
+		{Role: ChatRoleAssistant, Content: "Great choice! May I have your full name?"},
+		{Role: ChatRoleUser, Content: "Sarah Johnson, sarah@example.com"},
+	}
+	now := time.Date(2026, 3, 2, 15, 4, 5, 0, time.UTC)
+
+	snap := BuildQualificationSnapshot(history, cfg, nil, now)
+
+	assert.Equal(t, "Sarah Johnson", snap.Name)
+	assert.Equal(t, "Botox", snap.ServiceRaw)
+	assert.Equal(t, "Tox", snap.ServiceResolved)
+	assert.Equal(t, "sarah@example.com", snap.Email)
+	assert.Equal(t, QualificationGaps{PatientType: true, Schedule: true}, snap.Missing)
+	assert.False(t, snap.ReadyForAvailability)
+	assert.Nil(t, snap.PresentedSlots)
+	assert.Equal(t, now, snap.ComputedAt)
+}
+
+func TestBuildQualificationSnapshot_SlotsPresented(t *testing.T) {
+	history := qualifiedHistory("lip filler")
+	slotTime := time.Date(2026, 3, 4, 10, 0, 0, 0, time.UTC)
+	state := &TimeSelectionState{
+		Service:     "lip filler",
+		PresentedAt: slotTime.Add(-48 * time.Hour),
+		PresentedSlots: []PresentedSlot{
+			{Index: 1, DateTime: slotTime, TimeStr: "Wed Mar 4 at 10:00 AM"},
+			{Index: 2, DateTime: slotTime.Add(time.Hour), TimeStr: "Wed Mar 4 at 11:00 AM"},
+		},
+	}
+
+	snap := BuildQualificationSnapshot(history, nil, state, time.Now())
+
+	assert.Equal(t, "Sarah Johnson", snap.Name)
+	assert.Equal(t, "new", snap.PatientType)
+	assert.NotEmpty(t, snap.PreferredDays+snap.PreferredTimes)
+	assert.False(t, snap.Missing.Any())
+	assert.True(t, snap.ReadyForAvailability)
+	require.NotNil(t, snap.PresentedSlots)
+	assert.Equal(t, "lip filler", snap.PresentedSlots.Service)
+	assert.False(t, snap.PresentedSlots.SlotSelected)
+	require.Len(t, snap.PresentedSlots.Slots, 2)
+	assert.Equal(t, PresentedSlotSummary{Index: 2, DateTime: slotTime.Add(time.Hour), Label: "Wed Mar 4 at 11:00 AM"}, snap.PresentedSlots.Slots[1])
+}
+
+// The snapshot must never disagree with the worker's availability gate.
+func TestBuildQualificationSnapshot_MatchesShouldFetchAvailability(t *testing.T) {
+	laser := laserAreaConfig()
+	multiProvider := &clinic.Config{
+		BookingPlatform: "boulevard",
+		ProviderNames:   map[string]string{"p1": "Gale Smith", "p2": "Brandi Jones"},
+	}
+	for name, tc := range map[string]struct {
+		history []ChatMessage
+		cfg     *clinic.Config
+		missing QualificationGaps
+	}{
+		"empty":                     {nil, nil, QualificationGaps{Name: true, Service: true, PatientType: true, Schedule: true}},
+		"qualified":                 {qualifiedHistory("lip filler"), nil, QualificationGaps{}},
+		"extra qualification due":   {qualifiedHistory("laser hair removal"), laser, QualificationGaps{ExtraQualification: true}},
+		"extra qualification given": {withTurns(qualifiedHistory("laser hair removal"), laserAreaQuestion, "legs"), laser, QualificationGaps{}},
+		"provider needed":           {qualifiedHistory("lip filler"), multiProvider, QualificationGaps{ProviderPreference: true}},
+		"provider named":            {withTurns(qualifiedHistory("lip filler"), "Any provider preference?", "Gale please"), multiProvider, QualificationGaps{}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			snap := BuildQualificationSnapshot(tc.history, tc.cfg, nil, time.Now())
+			assert.Equal(t, tc.missing, snap.Missing)
+			assert.Equal(t, ShouldFetchAvailabilityWithConfig(tc.history, nil, tc.cfg), snap.ReadyForAvailability)
+		})
+	}
+}
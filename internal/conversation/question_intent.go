@@ -0,0 +1,99 @@
+package conversation
+
+import "strings"
+
+// QuestionIntent is what an assistant message asks the patient for during
+// booking qualification.
+type QuestionIntent string
+
+const (
+	QuestionIntentName               QuestionIntent = "ask_name"
+	QuestionIntentPatientType        QuestionIntent = "ask_patient_type"
+	QuestionIntentSchedule           QuestionIntent = "ask_schedule"
+	QuestionIntentProvider           QuestionIntent = "ask_provider"
+	QuestionIntentEmail              QuestionIntent = "ask_email"
+	QuestionIntentVariant            QuestionIntent = "ask_variant"
+	QuestionIntentExtraQualification QuestionIntent = "ask_extra_qualification"
+)
+
+// questionIntentKeywords maps phrases to intents, checked in order; the
+// first intent with a matching phrase wins.
+var questionIntentKeywords = []struct {
+	intent   QuestionIntent
+	keywords []string
+}{
+	{QuestionIntentName, []string{"your name", "full name", "first and last"}},
+	{QuestionIntentPatientType, []string{"visited us before", "first time", "new or returning", "new or existing", "been here before"}},
+	{QuestionIntentSchedule, []string{"days and times", "when works", "what time", "schedule preference", "days work best"}},
+	{QuestionIntentProvider, []string{"preferred provider", "provider preference", "who would you like", "which provider"}},
+	{QuestionIntentEmail, []string{"email address", "email for", "your email"}},
+	{QuestionIntentVariant, []string{"in-person or virtual", "in person or virtual", "prefer an in-person", "prefer a virtual"}},
+	{QuestionIntentExtraQualification, []string{"which area", "areas would you like", "which drip", "which iv"}},
+}
+
+// DetectQuestionIntent returns the qualification question an assistant
+// message asks, or "" when it asks none of them.
+func DetectQuestionIntent(content string) QuestionIntent {
+	lower := strings.ToLower(content)
+	for _, entry := range questionIntentKeywords {
+		for _, kw := range entry.keywords {
+			if strings.Contains(lower, kw) {
+				return entry.intent
+			}
+		}
+	}
+	return ""
+}
+
+// DuplicateQuestion is an assistant question repeated with no patient
+// message in between.
+type DuplicateQuestion struct {
+	Intent QuestionIntent
+	First  string
+	Repeat string
+}
+
+// FindDuplicateQuestions scans a transcript, oldest first, for assistant
+// messages asking the same qualification question as the previous
+// assistant question without a user message in between. System messages
+// are ignored.
+func FindDuplicateQuestions(transcript []ChatMessage) []DuplicateQuestion {
+	var (
+		dupes     []DuplicateQuestion
+		lastAsked QuestionIntent
+		lastText  string
+	)
+	for _, msg := range transcript {
+		switch {
+		case msg.FromPatient():
+			lastAsked, lastText = "", ""
+		case msg.Role == ChatRoleAssistant:
+			intent := DetectQuestionIntent(msg.Content)
+			if intent == "" {
+				continue
+			}
+			if intent == lastAsked {
+				dupes = append(dupes, DuplicateQuestion{Intent: intent, First: lastText, Repeat: msg.Content})
+			}
+			lastAsked, lastText = intent, msg.Content
+		}
+	}
+	return dupes
+}
+
+// lastQuestionIntent returns the intent of the most recent assistant
+// qualification question in history and whether the patient has sent a
+// message since.
+func lastQuestionIntent(history []ChatMessage) (intent QuestionIntent, replied bool) {
+	for i := len(history) - 1; i >= 0; i-- {
+		switch {
+		case history[i].FromPatient():
+			replied = true
+		case history[i].Role == ChatRoleAssistant:
+			if intent := DetectQuestionIntent(history[i].Content); intent != "" {
+				return intent, replied
+			}
+		}
+	}
+	return "", replied
+}
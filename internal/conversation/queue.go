@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"time"
 
 	"github.com/google/uuid"
 
@@ -20,6 +21,9 @@ type queueMessage struct {
 	ID            string
 	Body          string
 	ReceiptHandle string
+	// SentAt is when the message was first sent to the queue; zero when the
+	// queue doesn't report it.
+	SentAt time.Time
 }
 
 type jobType string
@@ -29,16 +33,57 @@ const (
 	jobTypeMessage       jobType = "message"
 	jobTypePayment       jobType = "payment_succeeded.v1"
 	jobTypePaymentFailed jobType = "payment_failed.v1"
+	// jobTypePaymentDisputed alerts operators about a chargeback.
+	jobTypePaymentDisputed jobType = "payment_disputed.v1"
+	// jobTypeRefreshAvailability re-sends fresh slots for a stuck conversation.
+	jobTypeRefreshAvailability jobType = "refresh_availability"
+	// jobTypeAssistedBooking searches or books on a patient's behalf for clinic staff.
+	jobTypeAssistedBooking jobType = "assisted_booking"
+	// jobTypeOfferSlot texts a patient a time clinic staff made room for.
+	jobTypeOfferSlot jobType = "offer_slot"
+	// jobTypeReengage nudges a lead who left an assistant question unanswered.
+	jobTypeReengage jobType = "reengage"
+	// jobTypePaymentClaimCheck re-checks a deposit the patient said they paid.
+	jobTypePaymentClaimCheck jobType = "payment_claim_check"
+	// jobTypeVoicemail records a caller's voicemail and alerts operators.
+	jobTypeVoicemail jobType = "voicemail"
 )
 
+// patientFacing reports whether the job texts the patient on the clinic's
+// behalf. Those jobs are dropped once the clinic is deactivated; payment
+// events still run so money already moved is recorded.
+func (k jobType) patientFacing() bool {
+	switch k {
+	case jobTypeStart, jobTypeMessage, jobTypeRefreshAvailability, jobTypeAssistedBooking, jobTypeOfferSlot, jobTypeReengage, jobTypePaymentClaimCheck:
+		return true
+	}
+	return false
+}
+
 type queuePayload struct {
-	ID            string                     `json:"id"`
-	Kind          jobType                    `json:"kind"`
-	Start         StartRequest               `json:"start,omitempty"`
-	Message       MessageRequest             `json:"message,omitempty"`
-	TrackStatus   bool                       `json:"track_status"`
-	Payment       *events.PaymentSucceededV1 `json:"payment,omitempty"`
-	PaymentFailed *events.PaymentFailedV1    `json:"payment_failed,omitempty"`
+	ID              string                      `json:"id"`
+	Kind            jobType                     `json:"kind"`
+	Start           StartRequest                `json:"start,omitempty"`
+	Message         MessageRequest              `json:"message,omitempty"`
+	TrackStatus     bool                        `json:"track_status"`
+	Payment         *events.PaymentSucceededV1  `json:"payment,omitempty"`
+	PaymentFailed   *events.PaymentFailedV1     `json:"payment_failed,omitempty"`
+	PaymentDisputed *events.PaymentDisputedV1   `json:"payment_disputed,omitempty"`
+	Refresh         *RefreshAvailabilityRequest `json:"refresh,omitempty"`
+	Assisted        *AssistedBookingRequest     `json:"assisted,omitempty"`
+	OfferSlot       *ManualSlotOfferRequest     `json:"offer_slot,omitempty"`
+	Reengage        *ReengagementRequest        `json:"reengage,omitempty"`
+	ClaimCheck      *PaymentClaimCheckRequest   `json:"claim_check,omitempty"`
+	Voicemail       *VoicemailRequest           `json:"voicemail,omitempty"`
+	// DeferredAt is when a worker first requeued the job for being over its
+	// org's concurrency limit.
+	DeferredAt *time.Time `json:"deferred_at,omitempty"`
+	// AckSent marks a job requeued at shutdown after its progress
+	// acknowledgement went out, so the retry doesn't send it again.
+	AckSent bool `json:"ack_sent,omitempty"`
+	// Coalesced marks a message job that has been through inbound batching,
+	// so a later requeue processes it as is.
+	Coalesced bool `json:"coalesced,omitempty"`
 }
 
 type PublishOption func(*queuePayload)
@@ -0,0 +1,43 @@
+package conversation
+
+import "github.com/prometheus/client_golang/prometheus"
+
+var memoryQueueDepth = prometheus.NewGauge(
+	prometheus.GaugeOpts{
+		Namespace: "medspa",
+		Subsystem: "conversation",
+		Name:      "memory_queue_depth",
+		Help:      "Jobs currently buffered in the in-memory conversation queue",
+	},
+)
+
+var memoryQueueOverflowTotal = prometheus.NewCounter(
+	prometheus.CounterOpts{
+		Namespace: "medspa",
+		Subsystem: "conversation",
+		Name:      "memory_queue_overflow_total",
+		Help:      "Jobs persisted to the overflow store because the memory queue was full",
+	},
+)
+
+var memoryQueueOverflowDrainedTotal = prometheus.NewCounter(
+	prometheus.CounterOpts{
+		Namespace: "medspa",
+		Subsystem: "conversation",
+		Name:      "memory_queue_overflow_drained_total",
+		Help:      "Overflowed jobs moved back into the memory queue",
+	},
+)
+
+var memoryQueueOverflowPending = prometheus.NewGauge(
+	prometheus.GaugeOpts{
+		Namespace: "medspa",
+		Subsystem: "conversation",
+		Name:      "memory_queue_overflow_pending",
+		Help:      "Overflowed jobs waiting in the overflow store",
+	},
+)
+
+func init() {
+	prometheus.MustRegister(memoryQueueDepth, memoryQueueOverflowTotal, memoryQueueOverflowDrainedTotal, memoryQueueOverflowPending)
+}
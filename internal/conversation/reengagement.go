@@ -0,0 +1,249 @@
+package conversation
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/clinic"
+	"github.com/wolfman30/medspa-ai-platform/internal/messaging/compliance"
+	"github.com/wolfman30/medspa-ai-platform/internal/tenancy"
+	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
+)
+
+// Re-engagement nudges go out in two steps: a follow-up, then a final one.
+const (
+	reengageFirstStep = 1
+	reengageFinalStep = 2
+)
+
+// Default window, in the clinic's timezone, when nudges are held back.
+const (
+	defaultReengageQuietStart = "21:00"
+	defaultReengageQuietEnd   = "08:00"
+)
+
+// reengageMinGap keeps the final nudge from following the first too closely
+// when the first was held back by quiet hours.
+const reengageMinGap = time.Hour
+
+// ReengagementRequest is one scheduled nudge for a lead who left an
+// assistant question unanswered.
+type ReengagementRequest struct {
+	OrgID          string `json:"org_id"`
+	LeadID         string `json:"lead_id,omitempty"`
+	ConversationID string `json:"conversation_id"`
+	// Phone is the lead's number; ClinicPhone the number the conversation
+	// runs on.
+	Phone       string `json:"phone"`
+	ClinicPhone string `json:"clinic_phone"`
+	Step        int    `json:"step"`
+	// QuestionAt is when the unanswered question went out; both nudges are
+	// timed from it.
+	QuestionAt time.Time `json:"question_at"`
+	// CustomerMessages is the conversation's inbound message count when the
+	// question went out, so a reply that raced the cancel is still noticed.
+	CustomerMessages int `json:"customer_messages"`
+}
+
+// asksPatient reports whether an assistant reply leaves the patient a
+// question to answer.
+func asksPatient(message string) bool {
+	return strings.HasSuffix(strings.TrimSpace(message), "?") || DetectQuestionIntent(message) != ""
+}
+
+// reengagementBlocked reports whether a conversation is past qualification,
+// so a quiet lead is waiting on slots or already booked rather than stalled.
+func reengagementBlocked(rec *ConversationRecord) bool {
+	if rec == nil {
+		return false
+	}
+	switch rec.Status {
+	case StatusAwaitingTimeSelection, StatusDepositPaid, StatusBooked, StatusEnded:
+		return true
+	}
+	return rec.EndedAt != nil
+}
+
+// reengageConversations returns the lookup nudges check conversation state
+// with, or nil when none is configured.
+func (w *Worker) reengageConversations() ConversationLookup {
+	if w.scheduler != nil && w.scheduler.conversations != nil {
+		return w.scheduler.conversations
+	}
+	if w.convStore != nil {
+		return w.convStore
+	}
+	return nil
+}
+
+// scheduleReengagement starts a fresh nudge sequence after an SMS reply that
+// asks the patient something, replacing any sequence already pending.
+func (w *Worker) scheduleReengagement(ctx context.Context, msg MessageRequest, conversationID, reply string, now time.Time) {
+	if w.scheduler == nil || msg.Channel != ChannelSMS || conversationID == "" {
+		return
+	}
+	w.cancelReengagement(ctx, conversationID)
+	if !asksPatient(reply) || !w.featureEnabled(ctx, msg.OrgID, clinic.FlagReengagementNudges) {
+		return
+	}
+	customerMessages := 0
+	if lookup := w.reengageConversations(); lookup != nil {
+		rec, err := lookup.GetConversation(ctx, tenancy.ForOrg(msg.OrgID), conversationID)
+		if err != nil {
+			w.log(ctx).Warn("re-engagement not scheduled: conversation lookup failed", "error", err)
+			return
+		}
+		if reengagementBlocked(rec) {
+			return
+		}
+		if rec != nil {
+			customerMessages = rec.CustomerMessageCount
+		}
+	}
+
+	first, _ := w.clinicConfig(ctx, msg.OrgID).ReengagementDelays()
+	req := ReengagementRequest{
+		OrgID:            msg.OrgID,
+		LeadID:           msg.LeadID,
+		ConversationID:   conversationID,
+		Phone:            msg.From,
+		ClinicPhone:      msg.To,
+		Step:             reengageFirstStep,
+		QuestionAt:       now.UTC(),
+		CustomerMessages: customerMessages,
+	}
+	if err := w.scheduler.publisher.EnqueueReengagementAt(ctx, logging.NewID(), req, now.Add(first)); err != nil {
+		w.log(ctx).Warn("failed to schedule re-engagement nudge", "error", err)
+	}
+}
+
+// cancelReengagement drops a conversation's pending nudges.
+func (w *Worker) cancelReengagement(ctx context.Context, conversationID string) {
+	if w.scheduler == nil || strings.TrimSpace(conversationID) == "" {
+		return
+	}
+	n, err := w.scheduler.store.CancelKindForConversation(ctx, conversationID, string(jobTypeReengage))
+	if err != nil {
+		w.log(ctx).Warn("failed to cancel re-engagement nudges", "error", err)
+		return
+	}
+	if n > 0 {
+		w.log(ctx).Debug("re-engagement nudges cancelled", "cancelled", n)
+	}
+}
+
+// sendReengagement texts a due nudge and schedules the final one. The lead's
+// state is checked again first: a reply, opt-out, slot offer or booking since
+// the question ends the sequence, and quiet hours push the nudge back.
+func (w *Worker) sendReengagement(ctx context.Context, req *ReengagementRequest, now time.Time) error {
+	if req == nil {
+		return errors.New("conversation: reengage: missing request")
+	}
+	if !w.featureEnabled(ctx, req.OrgID, clinic.FlagReengagementNudges) {
+		return nil
+	}
+	if lookup := w.reengageConversations(); lookup != nil {
+		rec, err := lookup.GetConversation(ctx, tenancy.ForOrg(req.OrgID), req.ConversationID)
+		if err != nil {
+			return err
+		}
+		if rec == nil || reengagementBlocked(rec) || rec.CustomerMessageCount > req.CustomerMessages {
+			w.log(ctx).Info("re-engagement sequence ended: conversation moved on", "step", req.Step)
+			return nil
+		}
+	}
+
+	service := ""
+	if w.leadsRepo != nil && req.LeadID != "" {
+		lead, err := w.leadsRepo.GetByID(ctx, tenancy.ForOrg(req.OrgID), req.LeadID)
+		if err != nil {
+			w.log(ctx).Warn("re-engagement: failed to load lead", "error", err, "lead_id", req.LeadID)
+		} else if lead != nil {
+			if strings.EqualFold(lead.DepositStatus, "paid") {
+				return nil
+			}
+			service = lead.ServiceInterest
+		}
+	}
+	if w.isOptedOut(ctx, req.OrgID, req.Phone) {
+		return nil
+	}
+
+	cfg := w.clinicConfig(ctx, req.OrgID)
+	if at := w.reengageQuietHours(ctx, cfg).NextSendTime(now); at.After(now) {
+		if w.scheduler == nil {
+			return nil
+		}
+		w.log(ctx).Info("re-engagement nudge held for quiet hours", "step", req.Step, "run_at", at)
+		return w.scheduler.publisher.EnqueueReengagementAt(ctx, logging.NewID(), *req, at)
+	}
+
+	nudge := cfg.RenderReengagement(req.Step >= reengageFinalStep, service)
+	body := nudge.Body
+	if w.messenger != nil {
+		if err := w.messenger.SendReply(ctx, OutboundReply{
+			OrgID:          req.OrgID,
+			LeadID:         req.LeadID,
+			ConversationID: req.ConversationID,
+			To:             req.Phone,
+			From:           req.ClinicPhone,
+			Body:           body,
+			Metadata:       nudge.Stamp(nil),
+		}); err != nil {
+			return err
+		}
+	}
+	w.appendTranscript(ctx, req.ConversationID, SMSTranscriptMessage{
+		Role:      "assistant",
+		From:      req.ClinicPhone,
+		To:        req.Phone,
+		Body:      body,
+		Timestamp: now,
+		Kind:      "reengagement",
+		Metadata:  nudge.Stamp(nil),
+	})
+	if histStore, ok := w.processor.(interface {
+		AppendAssistantMessage(ctx context.Context, conversationID, message string) error
+	}); ok {
+		if err := histStore.AppendAssistantMessage(ctx, req.ConversationID, body); err != nil {
+			w.log(ctx).Warn("failed to save re-engagement nudge to LLM history", "error", err)
+		}
+	}
+	w.log(ctx).Info("re-engagement nudge sent", "step", req.Step)
+
+	if req.Step >= reengageFinalStep || w.scheduler == nil {
+		return nil
+	}
+	_, final := cfg.ReengagementDelays()
+	next := *req
+	next.Step = reengageFinalStep
+	runAt := req.QuestionAt.Add(final)
+	if earliest := now.Add(reengageMinGap); runAt.Before(earliest) {
+		runAt = earliest
+	}
+	if err := w.scheduler.publisher.EnqueueReengagementAt(ctx, logging.NewID(), next, runAt); err != nil {
+		w.log(ctx).Warn("failed to schedule final re-engagement nudge", "error", err)
+	}
+	return nil
+}
+
+// reengageQuietHours returns the window nudges are held back in, in the
+// clinic's timezone.
+func (w *Worker) reengageQuietHours(ctx context.Context, cfg *clinic.Config) compliance.QuietHours {
+	start, end := w.cfg.reengageQuietStart, w.cfg.reengageQuietEnd
+	if start == "" || end == "" {
+		start, end = defaultReengageQuietStart, defaultReengageQuietEnd
+	}
+	tz := ""
+	if cfg != nil {
+		tz = strings.TrimSpace(cfg.Timezone)
+	}
+	q, err := compliance.ParseQuietHours(start, end, tz)
+	if err != nil {
+		w.log(ctx).Warn("re-engagement quiet hours invalid; using UTC", "error", err, "timezone", tz)
+		q, _ = compliance.ParseQuietHours(start, end, "")
+	}
+	return q
+}
@@ -0,0 +1,226 @@
+package conversation
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/leads"
+	"github.com/wolfman30/medspa-ai-platform/internal/tenancy"
+	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
+)
+
+const reengageConvID = "sms:org-1:15005550002"
+
+type reengageFixture struct {
+	worker    *Worker
+	store     *memScheduledJobStore
+	messenger *recordingMessenger
+	convs     conversationsByID
+	msg       MessageRequest
+	// questionAt is tomorrow at 08:00 UTC: in the future for the publisher,
+	// and clear of the default quiet hours once the first nudge is due.
+	questionAt time.Time
+}
+
+func newReengageFixture(t *testing.T, opts ...WorkerOption) *reengageFixture {
+	t.Helper()
+	questionAt := time.Now().UTC().Truncate(24 * time.Hour).Add(32 * time.Hour)
+	store := newMemScheduledJobStore(questionAt)
+	publisher := NewPublisher(&stubQueue{}, &stubJobRecorder{}, logging.Default())
+	publisher.SetScheduledJobStore(store)
+	scheduler := NewScheduler(store, publisher, logging.Default())
+	convs := conversationsByID{
+		reengageConvID: {ConversationID: reengageConvID, Status: StatusActive, CustomerMessageCount: 2},
+	}
+	scheduler.SetConversationLookup(convs)
+
+	messenger := &recordingMessenger{}
+	opts = append([]WorkerOption{WithScheduler(scheduler)}, opts...)
+	worker := NewWorker(&recordingService{}, newScriptedQueue(), &stubJobUpdater{}, messenger, nil, logging.Default(), opts...)
+	return &reengageFixture{
+		worker:    worker,
+		store:     store,
+		messenger: messenger,
+		convs:     convs,
+		msg: MessageRequest{
+			OrgID:          "org-1",
+			ConversationID: reengageConvID,
+			From:           "+15005550002",
+			To:             "+15005550100",
+			Channel:        ChannelSMS,
+		},
+		questionAt: questionAt,
+	}
+}
+
+// pending returns the pending nudges and their requests.
+func (f *reengageFixture) pending(t *testing.T) ([]ScheduledJob, []ReengagementRequest) {
+	t.Helper()
+	f.store.mu.Lock()
+	defer f.store.mu.Unlock()
+	var jobs []ScheduledJob
+	var reqs []ReengagementRequest
+	for _, job := range f.store.jobs {
+		if job.Kind != string(jobTypeReengage) || job.Status != ScheduledJobPending {
+			continue
+		}
+		var payload queuePayload
+		if err := json.Unmarshal(job.Payload, &payload); err != nil || payload.Reengage == nil {
+			t.Fatalf("decode nudge payload: %v", err)
+		}
+		jobs = append(jobs, *job)
+		reqs = append(reqs, *payload.Reengage)
+	}
+	return jobs, reqs
+}
+
+func TestReengagement_TwoStepSchedule(t *testing.T) {
+	ctx := context.Background()
+	repo := leads.NewInMemoryRepository()
+	lead, err := repo.Create(ctx, &leads.CreateLeadRequest{OrgID: "org-1", Phone: "+15005550002", Source: "sms"})
+	if err != nil {
+		t.Fatalf("create lead: %v", err)
+	}
+	if err := repo.UpdateSchedulingPreferences(ctx, tenancy.ForOrg(lead.OrgID), lead.ID, leads.SchedulingPreferences{ServiceInterest: "Botox"}); err != nil {
+		t.Fatalf("save preferences: %v", err)
+	}
+	f := newReengageFixture(t, WithWorkerLeadsRepo(repo))
+	f.msg.LeadID = lead.ID
+
+	f.worker.scheduleReengagement(ctx, f.msg, reengageConvID, "Got it! Have you visited us before?", f.questionAt)
+	jobs, reqs := f.pending(t)
+	if len(jobs) != 1 || reqs[0].Step != reengageFirstStep || !jobs[0].RunAt.Equal(f.questionAt.Add(4*time.Hour)) {
+		t.Fatalf("expected the first nudge 4 hours out, got %+v", jobs)
+	}
+	if reqs[0].CustomerMessages != 2 || reqs[0].Phone != f.msg.From || reqs[0].ClinicPhone != f.msg.To {
+		t.Fatalf("unexpected nudge request: %+v", reqs[0])
+	}
+
+	// The scheduler publishes the nudge when it comes due.
+	f.store.setStatus(jobs[0].ID, []string{ScheduledJobPending}, ScheduledJobPublished)
+	if err := f.worker.sendReengagement(ctx, &reqs[0], jobs[0].RunAt); err != nil {
+		t.Fatalf("first nudge: %v", err)
+	}
+	replies := f.messenger.allReplies()
+	if len(replies) != 1 || !strings.Contains(replies[0].Body, "Botox") || replies[0].To != f.msg.From {
+		t.Fatalf("expected a nudge about Botox to the lead, got %+v", replies)
+	}
+	jobs, reqs = f.pending(t)
+	if len(jobs) != 1 || reqs[0].Step != reengageFinalStep || !jobs[0].RunAt.Equal(f.questionAt.Add(48*time.Hour)) {
+		t.Fatalf("expected the final nudge 2 days after the question, got %+v", jobs)
+	}
+
+	f.store.setStatus(jobs[0].ID, []string{ScheduledJobPending}, ScheduledJobPublished)
+	if err := f.worker.sendReengagement(ctx, &reqs[0], jobs[0].RunAt); err != nil {
+		t.Fatalf("final nudge: %v", err)
+	}
+	if got := len(f.messenger.allReplies()); got != 2 {
+		t.Fatalf("expected two nudges in total, got %d", got)
+	}
+	if jobs, _ := f.pending(t); len(jobs) != 0 {
+		t.Fatalf("expected the sequence to stop after the final nudge, got %+v", jobs)
+	}
+}
+
+func TestReengagement_CancelledOnReply(t *testing.T) {
+	ctx := context.Background()
+	f := newReengageFixture(t)
+	f.worker.scheduleReengagement(ctx, f.msg, reengageConvID, "What's your full name?", f.questionAt)
+	jobs, reqs := f.pending(t)
+	if len(jobs) != 1 {
+		t.Fatalf("expected a nudge scheduled, got %d", len(jobs))
+	}
+
+	reply := f.msg
+	reply.Message = "Jane Doe"
+	if _, err := f.worker.dispatchMessage(ctx, &queuePayload{ID: "job-1", Kind: jobTypeMessage, Message: reply}); err != nil {
+		t.Fatalf("dispatch: %v", err)
+	}
+	if got := f.store.status(jobs[0].ID); got != ScheduledJobCancelled {
+		t.Fatalf("expected the nudge cancelled by the reply, got %s", got)
+	}
+
+	// A nudge already claimed when the reply landed sees the new inbound
+	// message and stays quiet.
+	f.convs[reengageConvID].CustomerMessageCount++
+	if err := f.worker.sendReengagement(ctx, &reqs[0], jobs[0].RunAt); err != nil {
+		t.Fatalf("nudge: %v", err)
+	}
+	if got := len(f.messenger.allReplies()); got != 0 {
+		t.Fatalf("expected no nudge after a reply, got %d", got)
+	}
+}
+
+func TestReengagement_CancelledWhenSlotsPresented(t *testing.T) {
+	ctx := context.Background()
+	f := newReengageFixture(t)
+	f.worker.scheduleReengagement(ctx, f.msg, reengageConvID, "What days and times work best?", f.questionAt)
+	jobs, _ := f.pending(t)
+
+	f.worker.handleTimeSelectionResponse(ctx, f.msg, &Response{
+		ConversationID:        reengageConvID,
+		TimeSelectionResponse: &TimeSelectionResponse{SMSMessage: "Here are some times: 1) Tue 2pm. Which works?"},
+	})
+	if got := f.store.status(jobs[0].ID); got != ScheduledJobCancelled {
+		t.Fatalf("expected the nudge cancelled once slots were presented, got %s", got)
+	}
+}
+
+func TestReengagement_BookedConversationsNeverNudged(t *testing.T) {
+	ctx := context.Background()
+	f := newReengageFixture(t)
+	f.convs[reengageConvID].Status = StatusBooked
+
+	f.worker.scheduleReengagement(ctx, f.msg, reengageConvID, "Anything else I can help with?", f.questionAt)
+	if jobs, _ := f.pending(t); len(jobs) != 0 {
+		t.Fatalf("expected no nudge for a booked conversation, got %+v", jobs)
+	}
+
+	// A nudge scheduled before the booking landed is dropped when it runs.
+	req := &ReengagementRequest{OrgID: "org-1", ConversationID: reengageConvID, Phone: f.msg.From, ClinicPhone: f.msg.To,
+		Step: reengageFirstStep, QuestionAt: f.questionAt, CustomerMessages: 2}
+	if err := f.worker.sendReengagement(ctx, req, f.questionAt.Add(4*time.Hour)); err != nil {
+		t.Fatalf("nudge: %v", err)
+	}
+	if got := len(f.messenger.allReplies()); got != 0 {
+		t.Fatalf("expected no nudge for a booked conversation, got %d", got)
+	}
+	if jobs, _ := f.pending(t); len(jobs) != 0 {
+		t.Fatalf("expected no follow-up nudge, got %+v", jobs)
+	}
+}
+
+func TestReengagement_QuietHoursAndOptOut(t *testing.T) {
+	ctx := context.Background()
+	optOut := &stubOptOutChecker{}
+	f := newReengageFixture(t, WithOptOutChecker(optOut))
+	f.msg.OrgID = uuid.NewString()
+	req := &ReengagementRequest{OrgID: f.msg.OrgID, ConversationID: reengageConvID, Phone: f.msg.From, ClinicPhone: f.msg.To,
+		Step: reengageFirstStep, QuestionAt: f.questionAt, CustomerMessages: 2}
+
+	// Due at 23:00 UTC: held until quiet hours end at 08:00.
+	late := f.questionAt.Add(15 * time.Hour)
+	if err := f.worker.sendReengagement(ctx, req, late); err != nil {
+		t.Fatalf("nudge: %v", err)
+	}
+	if got := len(f.messenger.allReplies()); got != 0 {
+		t.Fatalf("expected no nudge during quiet hours, got %d", got)
+	}
+	jobs, reqs := f.pending(t)
+	if len(jobs) != 1 || reqs[0].Step != reengageFirstStep || !jobs[0].RunAt.Equal(f.questionAt.Add(24*time.Hour)) {
+		t.Fatalf("expected the nudge held until 08:00, got %+v", jobs)
+	}
+
+	optOut.unsubscribed = true
+	if err := f.worker.sendReengagement(ctx, &reqs[0], jobs[0].RunAt); err != nil {
+		t.Fatalf("nudge: %v", err)
+	}
+	if got := len(f.messenger.allReplies()); got != 0 {
+		t.Fatalf("expected no nudge to an opted-out lead, got %d", got)
+	}
+}
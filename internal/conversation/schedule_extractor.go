@@ -8,7 +8,7 @@ import "strings"
 // just asked about preferred days/times.
 func scheduleFromShortReply(history []ChatMessage) string {
 	for i := len(history) - 1; i >= 0; i-- {
-		if history[i].Role != ChatRoleUser {
+		if !history[i].FromPatient() {
 			continue
 		}
 		if !assistantAskedSchedule(history, i) {
@@ -0,0 +1,193 @@
+package conversation
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Scheduled job lifecycle states stored on scheduled_jobs.status.
+const (
+	ScheduledJobPending = "pending"
+	// ScheduledJobClaimed means a scheduler took the job and is publishing it.
+	// A claim that is never marked published is retried once it goes stale.
+	ScheduledJobClaimed   = "claimed"
+	ScheduledJobPublished = "published"
+	ScheduledJobCancelled = "cancelled"
+)
+
+// maxScheduledJobAttempts caps how many times a job is claimed before it is
+// left for an operator to inspect.
+const maxScheduledJobAttempts = 5
+
+// ScheduledJob is a conversation job held until RunAt. Payload is the queue
+// body published when the job comes due.
+type ScheduledJob struct {
+	ID             uuid.UUID       `json:"id"`
+	OrgID          string          `json:"org_id"`
+	ConversationID string          `json:"conversation_id,omitempty"`
+	Kind           string          `json:"kind"`
+	Payload        json.RawMessage `json:"payload"`
+	RunAt          time.Time       `json:"run_at"`
+	Status         string          `json:"status"`
+	Attempts       int             `json:"attempts"`
+	ClaimedAt      *time.Time      `json:"claimed_at,omitempty"`
+	FinishedAt     *time.Time      `json:"finished_at,omitempty"`
+	CreatedAt      time.Time       `json:"created_at"`
+}
+
+// ScheduledJobStore persists delayed conversation jobs.
+type ScheduledJobStore interface {
+	Schedule(ctx context.Context, job *ScheduledJob) error
+	// ClaimDue atomically claims up to limit jobs whose run_at has passed by
+	// the store's clock, plus claims abandoned for longer than staleAfter.
+	// Concurrent callers never receive the same job.
+	ClaimDue(ctx context.Context, limit int, staleAfter time.Duration) ([]ScheduledJob, error)
+	MarkPublished(ctx context.Context, id uuid.UUID) error
+	// Cancel stops a pending or claimed job from being published.
+	Cancel(ctx context.Context, id uuid.UUID) error
+	// CancelForConversation cancels every pending job for a conversation.
+	CancelForConversation(ctx context.Context, conversationID string) (int64, error)
+	// CancelKindForConversation cancels a conversation's pending jobs of
+	// one kind.
+	CancelKindForConversation(ctx context.Context, conversationID, kind string) (int64, error)
+}
+
+type scheduledJobDB interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+}
+
+// PGScheduledJobStore stores scheduled jobs in PostgreSQL. Due times are
+// compared against the database clock, so replicas with skewed clocks agree
+// on when a job is due.
+type PGScheduledJobStore struct {
+	db scheduledJobDB
+}
+
+// NewPGScheduledJobStore builds a Postgres-backed ScheduledJobStore.
+func NewPGScheduledJobStore(db *pgxpool.Pool) *PGScheduledJobStore {
+	if db == nil {
+		panic("conversation: pgx pool cannot be nil")
+	}
+	return &PGScheduledJobStore{db: db}
+}
+
+var _ ScheduledJobStore = (*PGScheduledJobStore)(nil)
+
+const scheduledJobColumns = `id, org_id, conversation_id, kind, payload, run_at, status, attempts, claimed_at, finished_at, created_at`
+
+// Schedule inserts a pending job, filling in ID and CreatedAt when unset.
+func (s *PGScheduledJobStore) Schedule(ctx context.Context, job *ScheduledJob) error {
+	if job == nil {
+		return errors.New("conversation: scheduled job cannot be nil")
+	}
+	if strings.TrimSpace(job.OrgID) == "" || strings.TrimSpace(job.Kind) == "" || len(job.Payload) == 0 {
+		return errors.New("conversation: scheduled job requires org, kind and payload")
+	}
+	if job.ID == uuid.Nil {
+		job.ID = uuid.New()
+	}
+	if job.CreatedAt.IsZero() {
+		job.CreatedAt = time.Now().UTC()
+	}
+	job.Status = ScheduledJobPending
+	query := `
+		INSERT INTO scheduled_jobs (id, org_id, conversation_id, kind, payload, run_at, status, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`
+	if _, err := s.db.Exec(ctx, query, job.ID, job.OrgID, job.ConversationID, job.Kind,
+		[]byte(job.Payload), job.RunAt.UTC(), job.Status, job.CreatedAt); err != nil {
+		return fmt.Errorf("conversation: schedule job: %w", err)
+	}
+	return nil
+}
+
+// ClaimDue claims due jobs with FOR UPDATE SKIP LOCKED, so replicas polling
+// at the same time split the work instead of double-running it.
+func (s *PGScheduledJobStore) ClaimDue(ctx context.Context, limit int, staleAfter time.Duration) ([]ScheduledJob, error) {
+	if limit <= 0 {
+		return nil, nil
+	}
+	query := `
+		UPDATE scheduled_jobs
+		SET status = 'claimed', claimed_at = NOW(), attempts = attempts + 1
+		WHERE id IN (
+			SELECT id FROM scheduled_jobs
+			WHERE (status = 'pending' AND run_at <= NOW())
+			   OR (status = 'claimed' AND claimed_at <= NOW() - make_interval(secs => $2) AND attempts < $3)
+			ORDER BY run_at
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING ` + scheduledJobColumns
+	rows, err := s.db.Query(ctx, query, limit, staleAfter.Seconds(), maxScheduledJobAttempts)
+	if err != nil {
+		return nil, fmt.Errorf("conversation: claim scheduled jobs: %w", err)
+	}
+	defer rows.Close()
+
+	var jobs []ScheduledJob
+	for rows.Next() {
+		var job ScheduledJob
+		var payload []byte
+		if err := rows.Scan(&job.ID, &job.OrgID, &job.ConversationID, &job.Kind, &payload, &job.RunAt,
+			&job.Status, &job.Attempts, &job.ClaimedAt, &job.FinishedAt, &job.CreatedAt); err != nil {
+			return nil, fmt.Errorf("conversation: scan scheduled job: %w", err)
+		}
+		job.Payload = payload
+		jobs = append(jobs, job)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("conversation: claim scheduled jobs: %w", err)
+	}
+	return jobs, nil
+}
+
+// MarkPublished records that a claimed job reached the queue.
+func (s *PGScheduledJobStore) MarkPublished(ctx context.Context, id uuid.UUID) error {
+	query := `UPDATE scheduled_jobs SET status = 'published', finished_at = NOW() WHERE id = $1 AND status = 'claimed'`
+	if _, err := s.db.Exec(ctx, query, id); err != nil {
+		return fmt.Errorf("conversation: mark scheduled job published: %w", err)
+	}
+	return nil
+}
+
+// Cancel stops a job that hasn't been published yet.
+func (s *PGScheduledJobStore) Cancel(ctx context.Context, id uuid.UUID) error {
+	query := `UPDATE scheduled_jobs SET status = 'cancelled', finished_at = NOW() WHERE id = $1 AND status IN ('pending', 'claimed')`
+	if _, err := s.db.Exec(ctx, query, id); err != nil {
+		return fmt.Errorf("conversation: cancel scheduled job: %w", err)
+	}
+	return nil
+}
+
+// CancelForConversation cancels a conversation's pending jobs and returns
+// how many were cancelled.
+func (s *PGScheduledJobStore) CancelForConversation(ctx context.Context, conversationID string) (int64, error) {
+	query := `UPDATE scheduled_jobs SET status = 'cancelled', finished_at = NOW() WHERE conversation_id = $1 AND status = 'pending'`
+	tag, err := s.db.Exec(ctx, query, conversationID)
+	if err != nil {
+		return 0, fmt.Errorf("conversation: cancel scheduled jobs: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
+
+// CancelKindForConversation cancels a conversation's pending jobs of kind
+// and returns how many were cancelled.
+func (s *PGScheduledJobStore) CancelKindForConversation(ctx context.Context, conversationID, kind string) (int64, error) {
+	query := `UPDATE scheduled_jobs SET status = 'cancelled', finished_at = NOW() WHERE conversation_id = $1 AND kind = $2 AND status = 'pending'`
+	tag, err := s.db.Exec(ctx, query, conversationID, kind)
+	if err != nil {
+		return 0, fmt.Errorf("conversation: cancel scheduled jobs: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
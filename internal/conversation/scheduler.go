@@ -0,0 +1,139 @@
+package conversation
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/tenancy"
+	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
+)
+
+const (
+	defaultSchedulerInterval  = 5 * time.Second
+	defaultSchedulerBatchSize = 25
+	// defaultSchedulerStaleAfter is how long a claim may go unpublished before
+	// another scheduler retries it, e.g. after the claiming replica crashed.
+	defaultSchedulerStaleAfter = 2 * time.Minute
+)
+
+// Scheduler publishes scheduled jobs onto the conversation queue when they
+// come due. Any number of replicas may run one against the same store.
+type Scheduler struct {
+	store         ScheduledJobStore
+	publisher     *Publisher
+	conversations ConversationLookup
+	logger        *logging.Logger
+	interval      time.Duration
+	batchSize     int
+	staleAfter    time.Duration
+}
+
+// NewScheduler creates a scheduler that claims due jobs from store and
+// publishes them with publisher.
+func NewScheduler(store ScheduledJobStore, publisher *Publisher, logger *logging.Logger) *Scheduler {
+	if store == nil {
+		panic("conversation: scheduled job store cannot be nil")
+	}
+	if publisher == nil {
+		panic("conversation: publisher cannot be nil")
+	}
+	if logger == nil {
+		logger = logging.Default()
+	}
+	return &Scheduler{
+		store:      store,
+		publisher:  publisher,
+		logger:     logger,
+		interval:   defaultSchedulerInterval,
+		batchSize:  defaultSchedulerBatchSize,
+		staleAfter: defaultSchedulerStaleAfter,
+	}
+}
+
+// SetConversationLookup enables cancelling jobs whose conversation ended
+// before they came due.
+func (s *Scheduler) SetConversationLookup(conversations ConversationLookup) {
+	s.conversations = conversations
+}
+
+// Run polls for due jobs until ctx is cancelled.
+func (s *Scheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+	for {
+		// Drain the backlog before waiting for the next tick.
+		for {
+			n, err := s.RunOnce(ctx)
+			if err != nil {
+				s.logger.Warn("scheduled job poll failed", "error", err)
+			}
+			if err != nil || n < s.batchSize {
+				break
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce claims one batch of due jobs and publishes or cancels each.
+// It returns how many jobs were claimed.
+func (s *Scheduler) RunOnce(ctx context.Context) (int, error) {
+	jobs, err := s.store.ClaimDue(ctx, s.batchSize, s.staleAfter)
+	if err != nil {
+		return 0, err
+	}
+	for _, job := range jobs {
+		s.dispatch(ctx, job)
+	}
+	return len(jobs), nil
+}
+
+func (s *Scheduler) dispatch(ctx context.Context, job ScheduledJob) {
+	ctx = logging.WithFields(ctx, logging.Fields{OrgID: job.OrgID, ConversationID: job.ConversationID})
+	log := s.logger.WithContext(ctx)
+	if s.conversationEnded(ctx, job.OrgID, job.ConversationID) {
+		if err := s.store.Cancel(ctx, job.ID); err != nil {
+			log.Warn("failed to cancel scheduled job", "error", err, "scheduled_job_id", job.ID)
+			return
+		}
+		log.Info("scheduled job cancelled: conversation ended", "scheduled_job_id", job.ID, "kind", job.Kind)
+		return
+	}
+
+	var payload queuePayload
+	if err := json.Unmarshal(job.Payload, &payload); err != nil {
+		// A payload that can't be decoded will never publish; drop it.
+		log.Error("scheduled job payload invalid; cancelling", "error", err, "scheduled_job_id", job.ID)
+		if err := s.store.Cancel(ctx, job.ID); err != nil {
+			log.Warn("failed to cancel scheduled job", "error", err, "scheduled_job_id", job.ID)
+		}
+		return
+	}
+	// Left claimed on failure: the job is retried once the claim goes stale.
+	if err := s.publisher.publish(ctx, payload); err != nil {
+		log.Error("failed to publish scheduled job", "error", err, "scheduled_job_id", job.ID, "attempts", job.Attempts)
+		return
+	}
+	if err := s.store.MarkPublished(ctx, job.ID); err != nil {
+		log.Warn("failed to mark scheduled job published", "error", err, "scheduled_job_id", job.ID)
+	}
+	log.Debug("scheduled job published", "scheduled_job_id", job.ID, "job_id", payload.ID, "kind", job.Kind, "run_at", job.RunAt)
+}
+
+// conversationEnded reports whether the conversation reached a terminal
+// state. Lookup failures count as still open so jobs aren't lost.
+func (s *Scheduler) conversationEnded(ctx context.Context, orgID, conversationID string) bool {
+	if s.conversations == nil || conversationID == "" {
+		return false
+	}
+	rec, err := s.conversations.GetConversation(ctx, tenancy.ForOrg(orgID), conversationID)
+	if err != nil || rec == nil {
+		return false
+	}
+	return rec.Status == StatusEnded || rec.EndedAt != nil
+}
@@ -0,0 +1,344 @@
+package conversation
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pashagolub/pgxmock/v4"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/tenancy"
+	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
+)
+
+// memScheduledJobStore is a ScheduledJobStore whose clock stands in for the
+// database's.
+type memScheduledJobStore struct {
+	mu   sync.Mutex
+	now  func() time.Time
+	jobs map[uuid.UUID]*ScheduledJob
+}
+
+func newMemScheduledJobStore(now time.Time) *memScheduledJobStore {
+	return &memScheduledJobStore{now: func() time.Time { return now }, jobs: map[uuid.UUID]*ScheduledJob{}}
+}
+
+func (m *memScheduledJobStore) Schedule(ctx context.Context, job *ScheduledJob) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if job.ID == uuid.Nil {
+		job.ID = uuid.New()
+	}
+	job.Status = ScheduledJobPending
+	cp := *job
+	m.jobs[job.ID] = &cp
+	return nil
+}
+
+func (m *memScheduledJobStore) ClaimDue(ctx context.Context, limit int, staleAfter time.Duration) ([]ScheduledJob, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := m.now()
+	var due []*ScheduledJob
+	for _, job := range m.jobs {
+		if job.Status == ScheduledJobPending && !job.RunAt.After(now) {
+			due = append(due, job)
+		}
+	}
+	sort.Slice(due, func(i, j int) bool { return due[i].RunAt.Before(due[j].RunAt) })
+	var claimed []ScheduledJob
+	for _, job := range due {
+		if len(claimed) == limit {
+			break
+		}
+		job.Status = ScheduledJobClaimed
+		job.Attempts++
+		job.ClaimedAt = &now
+		claimed = append(claimed, *job)
+	}
+	return claimed, nil
+}
+
+func (m *memScheduledJobStore) setStatus(id uuid.UUID, from []string, to string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	job, ok := m.jobs[id]
+	if !ok {
+		return
+	}
+	for _, s := range from {
+		if job.Status == s {
+			job.Status = to
+			return
+		}
+	}
+}
+
+func (m *memScheduledJobStore) MarkPublished(ctx context.Context, id uuid.UUID) error {
+	m.setStatus(id, []string{ScheduledJobClaimed}, ScheduledJobPublished)
+	return nil
+}
+
+func (m *memScheduledJobStore) Cancel(ctx context.Context, id uuid.UUID) error {
+	m.setStatus(id, []string{ScheduledJobPending, ScheduledJobClaimed}, ScheduledJobCancelled)
+	return nil
+}
+
+func (m *memScheduledJobStore) CancelForConversation(ctx context.Context, conversationID string) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var n int64
+	for _, job := range m.jobs {
+		if job.ConversationID == conversationID && job.Status == ScheduledJobPending {
+			job.Status = ScheduledJobCancelled
+			n++
+		}
+	}
+	return n, nil
+}
+
+func (m *memScheduledJobStore) CancelKindForConversation(ctx context.Context, conversationID, kind string) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var n int64
+	for _, job := range m.jobs {
+		if job.ConversationID == conversationID && job.Kind == kind && job.Status == ScheduledJobPending {
+			job.Status = ScheduledJobCancelled
+			n++
+		}
+	}
+	return n, nil
+}
+
+func (m *memScheduledJobStore) status(id uuid.UUID) string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.jobs[id].Status
+}
+
+type lockedQueue struct {
+	mu   sync.Mutex
+	sent []string
+}
+
+func (q *lockedQueue) Send(ctx context.Context, body string) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.sent = append(q.sent, body)
+	return nil
+}
+
+func (q *lockedQueue) Receive(ctx context.Context, maxMessages int, waitSeconds int) ([]queueMessage, error) {
+	return nil, context.Canceled
+}
+
+func (q *lockedQueue) Delete(ctx context.Context, receiptHandle string) error {
+	return nil
+}
+
+type conversationsByID map[string]*ConversationRecord
+
+func (s conversationsByID) GetConversation(ctx context.Context, scope tenancy.OrgScope, conversationID string) (*ConversationRecord, error) {
+	return s[conversationID], nil
+}
+
+func scheduleMessage(t *testing.T, store ScheduledJobStore, jobID, conversationID string, runAt time.Time) *ScheduledJob {
+	t.Helper()
+	payload, body, err := encodePayload(queuePayload{ID: jobID, Kind: jobTypeMessage, Message: MessageRequest{OrgID: "org-1", ConversationID: conversationID}})
+	if err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+	job := &ScheduledJob{OrgID: "org-1", ConversationID: conversationID, Kind: string(payload.Kind), Payload: json.RawMessage(body), RunAt: runAt}
+	if err := store.Schedule(context.Background(), job); err != nil {
+		t.Fatalf("schedule: %v", err)
+	}
+	return job
+}
+
+func TestScheduler_ConcurrentSchedulersPublishEachJobOnce(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	store := newMemScheduledJobStore(now)
+	const total = 60
+	for i := 0; i < total; i++ {
+		scheduleMessage(t, store, fmt.Sprintf("job-%d", i), "sms:org-1:1555000", now.Add(-time.Duration(i)*time.Second))
+	}
+	scheduleMessage(t, store, "job-future", "sms:org-1:1555000", now.Add(time.Minute))
+
+	queue := &lockedQueue{}
+	publisher := NewPublisher(queue, &stubJobRecorder{}, logging.Default())
+	var wg sync.WaitGroup
+	start := make(chan struct{})
+	for i := 0; i < 2; i++ {
+		s := NewScheduler(store, publisher, logging.Default())
+		s.batchSize = 7
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+			for {
+				n, err := s.RunOnce(context.Background())
+				if err != nil {
+					t.Errorf("run once: %v", err)
+					return
+				}
+				if n == 0 {
+					return
+				}
+			}
+		}()
+	}
+	close(start)
+	wg.Wait()
+
+	if len(queue.sent) != total {
+		t.Fatalf("expected %d published jobs, got %d", total, len(queue.sent))
+	}
+	seen := map[string]bool{}
+	for _, body := range queue.sent {
+		var payload queuePayload
+		if err := json.Unmarshal([]byte(body), &payload); err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+		if seen[payload.ID] {
+			t.Fatalf("job %s published twice", payload.ID)
+		}
+		if payload.ID == "job-future" {
+			t.Fatalf("job published before it was due")
+		}
+		seen[payload.ID] = true
+	}
+}
+
+func TestScheduler_CancelsJobsForEndedConversations(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	store := newMemScheduledJobStore(now)
+	ended := scheduleMessage(t, store, "job-ended", "conv-ended", now.Add(-time.Minute))
+	open := scheduleMessage(t, store, "job-open", "conv-open", now.Add(-time.Minute))
+
+	queue := &stubQueue{}
+	s := NewScheduler(store, NewPublisher(queue, &stubJobRecorder{}, logging.Default()), logging.Default())
+	endedAt := now.Add(-30 * time.Second)
+	s.SetConversationLookup(conversationsByID{
+		"conv-ended": {ConversationID: "conv-ended", Status: StatusEnded, EndedAt: &endedAt},
+		"conv-open":  {ConversationID: "conv-open", Status: StatusActive},
+	})
+	if _, err := s.RunOnce(context.Background()); err != nil {
+		t.Fatalf("run once: %v", err)
+	}
+
+	if got := store.status(ended.ID); got != ScheduledJobCancelled {
+		t.Fatalf("expected ended conversation's job cancelled, got %s", got)
+	}
+	if got := store.status(open.ID); got != ScheduledJobPublished {
+		t.Fatalf("expected open conversation's job published, got %s", got)
+	}
+	if len(queue.sent) != 1 {
+		t.Fatalf("expected one published job, got %d", len(queue.sent))
+	}
+}
+
+func TestPublisher_EnqueueMessageAt(t *testing.T) {
+	ctx := context.Background()
+	queue := &stubQueue{}
+	jobs := &stubJobRecorder{}
+	store := newMemScheduledJobStore(time.Now())
+	publisher := NewPublisher(queue, jobs, logging.Default())
+	req := MessageRequest{OrgID: "org-1", ConversationID: "conv-1", Message: "nudge"}
+
+	if err := publisher.EnqueueMessageAt(ctx, "job-1", req, time.Now().Add(time.Hour)); err == nil {
+		t.Fatalf("expected an error without a scheduled job store")
+	}
+	publisher.SetScheduledJobStore(store)
+
+	if err := publisher.EnqueueMessageAt(ctx, "job-1", req, time.Now().Add(time.Hour)); err != nil {
+		t.Fatalf("schedule: %v", err)
+	}
+	if len(queue.sent) != 0 || len(jobs.jobs) != 0 || len(store.jobs) != 1 {
+		t.Fatalf("expected the job held for later, sent=%d recorded=%d scheduled=%d", len(queue.sent), len(jobs.jobs), len(store.jobs))
+	}
+
+	// A caller whose clock runs ahead may compute a run time that has already
+	// passed; that job goes straight onto the queue.
+	if err := publisher.EnqueueMessageAt(ctx, "job-2", req, time.Now().Add(-time.Second)); err != nil {
+		t.Fatalf("enqueue: %v", err)
+	}
+	if len(queue.sent) != 1 || len(store.jobs) != 1 {
+		t.Fatalf("expected an overdue job to publish immediately, sent=%d scheduled=%d", len(queue.sent), len(store.jobs))
+	}
+}
+
+func TestScheduler_DueTimeFollowsStoreClock(t *testing.T) {
+	// The store's clock is 10 minutes behind this process; a job due by local
+	// time must wait until the store agrees.
+	local := time.Now()
+	store := newMemScheduledJobStore(local.Add(-10 * time.Minute))
+	job := scheduleMessage(t, store, "job-1", "conv-1", local.Add(-time.Minute))
+
+	queue := &stubQueue{}
+	s := NewScheduler(store, NewPublisher(queue, &stubJobRecorder{}, logging.Default()), logging.Default())
+	if n, _ := s.RunOnce(context.Background()); n != 0 || len(queue.sent) != 0 {
+		t.Fatalf("expected nothing claimed before the store's clock reaches run_at")
+	}
+
+	store.now = func() time.Time { return local }
+	if n, _ := s.RunOnce(context.Background()); n != 1 || store.status(job.ID) != ScheduledJobPublished {
+		t.Fatalf("expected the job published once due by the store's clock")
+	}
+}
+
+func TestPGScheduledJobStore(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("pgxmock: %v", err)
+	}
+	defer mock.Close()
+	store := &PGScheduledJobStore{db: mock}
+	ctx := context.Background()
+
+	runAt := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	job := &ScheduledJob{OrgID: "org-1", ConversationID: "conv-1", Kind: "message", Payload: json.RawMessage(`{"id":"job-1"}`), RunAt: runAt}
+	mock.ExpectExec("INSERT INTO scheduled_jobs").
+		WithArgs(pgxmock.AnyArg(), "org-1", "conv-1", "message", []byte(`{"id":"job-1"}`), runAt, ScheduledJobPending, pgxmock.AnyArg()).
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+	if err := store.Schedule(ctx, job); err != nil {
+		t.Fatalf("schedule: %v", err)
+	}
+	if job.ID == uuid.Nil || job.Status != ScheduledJobPending {
+		t.Fatalf("unexpected job after schedule: %+v", job)
+	}
+
+	// Due-ness is decided by the database clock and rows are claimed with
+	// SKIP LOCKED, so no local timestamp is passed in.
+	claimedAt := runAt.Add(time.Second)
+	mock.ExpectQuery(`run_at <= NOW\(\)[\s\S]*FOR UPDATE SKIP LOCKED`).
+		WithArgs(10, float64(120), maxScheduledJobAttempts).
+		WillReturnRows(pgxmock.NewRows([]string{"id", "org_id", "conversation_id", "kind", "payload", "run_at", "status", "attempts", "claimed_at", "finished_at", "created_at"}).
+			AddRow(job.ID, "org-1", "conv-1", "message", []byte(`{"id":"job-1"}`), runAt, ScheduledJobClaimed, 1, &claimedAt, (*time.Time)(nil), job.CreatedAt))
+	claimed, err := store.ClaimDue(ctx, 10, 2*time.Minute)
+	if err != nil {
+		t.Fatalf("claim: %v", err)
+	}
+	if len(claimed) != 1 || claimed[0].ID != job.ID || claimed[0].Status != ScheduledJobClaimed || string(claimed[0].Payload) != `{"id":"job-1"}` {
+		t.Fatalf("unexpected claim: %+v", claimed)
+	}
+
+	mock.ExpectExec("UPDATE scheduled_jobs SET status = 'cancelled'").WithArgs("conv-1").
+		WillReturnResult(pgxmock.NewResult("UPDATE", 2))
+	if n, err := store.CancelForConversation(ctx, "conv-1"); err != nil || n != 2 {
+		t.Fatalf("cancel for conversation: n=%d err=%v", n, err)
+	}
+	mock.ExpectExec("UPDATE scheduled_jobs SET status = 'cancelled'").WithArgs("conv-1", "reengage").
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	if n, err := store.CancelKindForConversation(ctx, "conv-1", "reengage"); err != nil || n != 1 {
+		t.Fatalf("cancel kind for conversation: n=%d err=%v", n, err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
@@ -0,0 +1,27 @@
+package conversation
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/messaging/templates"
+)
+
+// SensitiveDataReply answers a message that carried a card number, security
+// code or SSN. The message never reaches the LLM or history.
+var SensitiveDataReply = templates.Text(templates.ComplianceSensitiveData)
+
+// sensitiveDataResponse audits a redaction and returns the safety reply.
+func (s *LLMService) sensitiveDataResponse(ctx context.Context, orgID, conversationID, leadID string, kinds []string) *Response {
+	s.log(ctx).Warn("sensitive numbers redacted from inbound message",
+		"conversation_id", conversationID,
+		"org_id", orgID,
+		"kinds", kinds,
+	)
+	if s.audit != nil && strings.TrimSpace(orgID) != "" {
+		_ = s.audit.LogSensitiveDataRedacted(ctx, orgID, conversationID, leadID, kinds)
+	}
+	reply := templates.Render(templates.ComplianceSensitiveData, nil, nil)
+	return &Response{ConversationID: conversationID, Message: reply.Body, Timestamp: time.Now().UTC(), Template: reply.Ref}
+}
@@ -0,0 +1,69 @@
+package conversation
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
+)
+
+func TestLLMService_SensitiveNumbersGetSafetyReply(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	mockLLM := &stubLLMClient{response: LLMResponse{Text: "Thanks!"}}
+	service := NewLLMService(mockLLM, client, nil, "model", logging.Default())
+	ctx := context.Background()
+	convID := "sms:org-1:15550001111"
+	if err := service.history.Save(ctx, convID, []ChatMessage{{Role: ChatRoleUser, Content: "hi, I'd like to book Botox"}}); err != nil {
+		t.Fatalf("seed history: %v", err)
+	}
+
+	for _, msg := range []string{
+		"here's my card 4111-1111-1111-1111 exp 12/27 cvv 123",
+		"my ssn is 123-45-6789",
+	} {
+		resp, err := service.ProcessMessage(ctx, MessageRequest{ConversationID: convID, OrgID: "org-1", Message: msg, Channel: ChannelSMS})
+		if err != nil {
+			t.Fatalf("process failed: %v", err)
+		}
+		if resp.Message != SensitiveDataReply {
+			t.Fatalf("reply to %q = %q, want the safety reply", msg, resp.Message)
+		}
+		history, err := service.history.Load(ctx, convID)
+		if err != nil {
+			t.Fatalf("load history: %v", err)
+		}
+		for _, m := range history {
+			if strings.Contains(m.Content, "1111") || strings.Contains(m.Content, "6789") {
+				t.Fatalf("history kept the digits: %q", m.Content)
+			}
+		}
+	}
+	if len(mockLLM.requests) != 0 {
+		t.Fatalf("expected the LLM not to be called, got %d calls", len(mockLLM.requests))
+	}
+}
+
+func TestPublisher_RedactsSensitiveNumbers(t *testing.T) {
+	queue := &stubQueue{}
+	publisher := NewPublisher(queue, &stubJobRecorder{}, logging.Default())
+
+	req := MessageRequest{ConversationID: "conv-1", Message: "card 4242 4242 4242 4242 and call me at 555-123-4567"}
+	if err := publisher.EnqueueMessage(context.Background(), "job-1", req); err != nil {
+		t.Fatalf("enqueue returned error: %v", err)
+	}
+	if strings.Contains(queue.sent[0], "4242 4242") {
+		t.Fatalf("queued payload kept the card number: %s", queue.sent[0])
+	}
+	var payload queuePayload
+	if err := json.Unmarshal([]byte(queue.sent[0]), &payload); err != nil {
+		t.Fatalf("decode payload: %v", err)
+	}
+	if want := "card [CARD ending 4242] and call me at 555-123-4567"; payload.Message.Message != want {
+		t.Fatalf("queued message = %q, want %q", payload.Message.Message, want)
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"fmt"
 	"time"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/messaging/templates"
 )
 
 // Service describes how the conversation engine should behave.
@@ -39,6 +41,27 @@ const (
 	StatusBooked                = "booked"
 )
 
+// Request metadata keys set by inbound number routing.
+const (
+	// MetadataDefaultService names the service line the dialed number was
+	// advertised for (e.g. "weight loss").
+	MetadataDefaultService = "default_service"
+	// MetadataCampaignTag identifies the campaign that published the number.
+	MetadataCampaignTag = "campaign_tag"
+)
+
+// Request metadata keys set when an inbound SMS replies to a broadcast.
+const (
+	// MetadataBroadcastID is the broadcast the patient is replying to.
+	MetadataBroadcastID = "broadcast_id"
+	// MetadataBroadcastMessage is the broadcast text the patient received.
+	MetadataBroadcastMessage = "broadcast_message"
+)
+
+// MetadataRequestID carries the inbound webhook's request ID onto the job so
+// worker logs correlate with the request that queued it.
+const MetadataRequestID = "request_id"
+
 // StartRequest represents the minimal data we need to open a conversation.
 type StartRequest struct {
 	OrgID          string
@@ -66,6 +89,9 @@ type MessageRequest struct {
 	From           string
 	To             string
 	Metadata       map[string]string
+	// ReceivedAt is when the inbound text reached the webhook; reply latency
+	// is measured from it.
+	ReceivedAt time.Time
 	// OnProgress is an optional callback for sending progress updates during
 	// long-running operations (e.g., progressive availability search).
 	// The worker sets this to send intermediate SMS messages to the patient.
@@ -79,12 +105,19 @@ type DepositIntent struct {
 	CancelURL    string
 	Description  string
 	ScheduledFor *time.Time
+	// Service is the treatment being booked; the deposit dispatcher uses it to
+	// apply the clinic's per-service deposit policy.
+	Service string
 	// BookingPolicies are sent to the patient BEFORE the payment link (informed consent).
 	// E.g., "24-hour cancellation policy", "no-show fee", etc.
 	BookingPolicies []string
+	// Disclosure is the clinic's rendered pre-payment policy text, set by the
+	// deposit dispatcher. Empty sends the default built from BookingPolicies.
+	Disclosure string
 	// Preloaded checkout info (set by deposit preloader for parallel generation)
 	PreloadedURL       string // Pre-generated Square checkout URL
 	PreloadedPaymentID string // Pre-generated payment ID to use for intent (UUID string)
+	PreloadedOrderID   string // Square order behind the pre-generated link
 }
 
 // TimeSelectionResponse contains available time slots for the user to choose from.
@@ -96,6 +129,7 @@ type TimeSelectionResponse struct {
 	ExactMatch     bool            // Whether slots match user's exact preferences
 	SMSMessage     string          // Pre-formatted SMS message to send
 	SavedToHistory bool            // Whether the LLM service already saved this to conversation history
+	Template       templates.Ref   // Template SMSMessage was rendered from, if any
 }
 
 // BookingRequest instructs the worker to create a booking for a Moxie clinic
@@ -113,6 +147,11 @@ type BookingRequest struct {
 	Phone       string
 	Email       string
 	CallbackURL string // POST target for outcome notifications
+	// Notes go on the appointment for the provider, e.g. the treatment area.
+	Notes string
+	// DepositApplied is set when the patient already paid a deposit for a
+	// slot that was taken; the booking is made without collecting another.
+	DepositApplied bool
 }
 
 // Response is a simple DTO returned to the API layer.
@@ -123,6 +162,9 @@ type Response struct {
 	DepositIntent         *DepositIntent
 	TimeSelectionResponse *TimeSelectionResponse // Set when presenting time options
 	BookingRequest        *BookingRequest        // Set when Moxie booking should be initiated
+	// Template names the registered template Message was rendered from; zero
+	// for LLM replies.
+	Template templates.Ref
 
 	// AsyncAvailability is set when voice calls need availability fetched
 	// asynchronously. The voice handler returns a filler response immediately
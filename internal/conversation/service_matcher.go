@@ -1,6 +1,11 @@
 package conversation
 
-import "strings"
+import (
+	"sort"
+	"strings"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/services"
+)
 
 // ---------- universal fallback service patterns ----------
 
@@ -207,39 +212,39 @@ func ResolveConcernToBookingService(service string) (bookingService, providerNot
 
 // matchService finds the best service match using config-driven aliases first,
 // then falling back to universal patterns. Returns the matched service name.
+// Verbatim matches win; failing those, a second pass allows for plurals,
+// punctuation and split words ("micro needling").
 func matchService(text string, serviceAliases map[string]string) string {
-	if len(serviceAliases) > 0 {
-		// Build sorted alias list for deterministic, longest-match-first ordering
-		type aliasPair struct {
-			pattern string
-			name    string
-		}
-		pairs := make([]aliasPair, 0, len(serviceAliases))
-		for alias, service := range serviceAliases {
-			pairs = append(pairs, aliasPair{strings.ToLower(alias), service})
-		}
-		// Sort by pattern length descending for longest-match-first
-		for i := 0; i < len(pairs); i++ {
-			for j := i + 1; j < len(pairs); j++ {
-				if len(pairs[j].pattern) > len(pairs[i].pattern) {
-					pairs[i], pairs[j] = pairs[j], pairs[i]
-				}
-			}
+	// Build sorted alias list for deterministic, longest-match-first ordering
+	type aliasPair struct {
+		pattern string
+		name    string
+	}
+	pairs := make([]aliasPair, 0, len(serviceAliases))
+	for alias, service := range serviceAliases {
+		pairs = append(pairs, aliasPair{strings.ToLower(alias), service})
+	}
+	sort.SliceStable(pairs, func(i, j int) bool {
+		if len(pairs[i].pattern) != len(pairs[j].pattern) {
+			return len(pairs[i].pattern) > len(pairs[j].pattern)
 		}
+		return pairs[i].pattern < pairs[j].pattern
+	})
+
+	for _, contains := range []func(text, term string) bool{strings.Contains, services.Contains} {
 		for _, p := range pairs {
-			if strings.Contains(text, p.pattern) {
+			if contains(text, p.pattern) {
 				// Return the alias key (patient-facing term) with title case,
 				// not the resolved Moxie service name. ResolveServiceName()
 				// handles the Moxie lookup downstream.
 				return strings.Title(p.pattern) //nolint:staticcheck
 			}
 		}
-	}
-
-	// Fall back to universal patterns
-	for _, s := range universalServicePatterns {
-		if strings.Contains(text, s.pattern) {
-			return s.name
+		// Fall back to universal patterns
+		for _, s := range universalServicePatterns {
+			if contains(text, s.pattern) {
+				return s.name
+			}
 		}
 	}
 	return ""
@@ -0,0 +1,31 @@
+package conversation
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/clinic"
+	"github.com/wolfman30/medspa-ai-platform/internal/leads"
+	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
+)
+
+func TestFetchAndPresentAvailability_VagueServiceAsksWhichOne(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	source := &stubAvailabilitySource{name: clinic.AvailabilitySourceMoxieAPI, err: fmt.Errorf("%w for service %q", errNoServiceMenuItem, "peel")}
+	svc := NewLLMService(&stubLLMClient{}, client, nil, "model", logging.Default(),
+		WithAvailabilityRouter(NewAvailabilityRouter(logging.Default(), source)))
+
+	cfg := &clinic.Config{OrgID: "org-1", Timezone: "America/New_York", Services: []string{"Chemical Peel", "Perfect Derma Peel", "Botox"}}
+	prefs := leads.SchedulingPreferences{Name: "Sarah Johnson", ServiceInterest: "peel"}
+	resp := svc.fetchAndPresentAvailability(context.Background(), &prefs, cfg, "", "sms:org-1:15550001111", "org-1", "", "+15550001111", nil)
+
+	want := "Just to make sure I book the right treatment, did you mean Chemical Peel or Perfect Derma Peel?"
+	if resp == nil || resp.SMSMessage != want {
+		t.Fatalf("reply = %+v, want %q", resp, want)
+	}
+}
@@ -0,0 +1,264 @@
+package conversation
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/clinic"
+	moxieclient "github.com/wolfman30/medspa-ai-platform/internal/emr/moxie"
+	"github.com/wolfman30/medspa-ai-platform/internal/messaging/templates"
+	"github.com/wolfman30/medspa-ai-platform/internal/tenancy"
+)
+
+// maxPaidSlotAlternatives caps the replacement times offered when a paid
+// slot was taken during checkout.
+const maxPaidSlotAlternatives = 3
+
+// paidSlotSearchDays is how many days past the original appointment day are
+// searched for replacement times.
+const paidSlotSearchDays = 2
+
+// DepositStatusAppliedToNextSelection marks a lead whose deposit was paid for
+// a slot that was taken; the deposit carries over to the next time they pick.
+const DepositStatusAppliedToNextSelection = "applied_to_next_selection"
+
+// PaidSlotVerificationRequest identifies the slot a patient just paid for.
+type PaidSlotVerificationRequest struct {
+	OrgID          string
+	LeadID         string
+	ConversationID string
+	Phone          string // patient phone
+	ScheduledFor   *time.Time
+}
+
+// PaidSlotVerification is the result of re-checking a paid slot. When the
+// slot is gone, Alternatives holds the replacement times already saved as the
+// conversation's time selection state.
+type PaidSlotVerification struct {
+	Available    bool
+	Slot         time.Time
+	Service      string
+	Alternatives *TimeSelectionResponse
+}
+
+// PaidSlotVerifier re-checks a patient's selected slot after their deposit
+// clears. A nil result means the slot can't be verified (e.g. the clinic
+// doesn't book through an API, or the lookup came back empty) and the
+// booking should proceed as before.
+type PaidSlotVerifier interface {
+	VerifyPaidSlot(ctx context.Context, req PaidSlotVerificationRequest) (*PaidSlotVerification, error)
+}
+
+// VerifyPaidSlot checks with Moxie that the lead's selected slot is still
+// open with the provider the patient asked for, or with any provider of the
+// service when they had no preference. If it isn't, nearby times are saved as
+// the conversation's time selection state with the deposit marked as applied.
+// An empty lookup can't tell a taken slot from Moxie returning nothing, so it
+// leaves the booking to proceed.
+func (s *LLMService) VerifyPaidSlot(ctx context.Context, req PaidSlotVerificationRequest) (*PaidSlotVerification, error) {
+	if s.moxieClient == nil || s.clinicStore == nil || s.leadsRepo == nil || req.LeadID == "" {
+		return nil, nil
+	}
+	cfg, err := s.clinicStore.Get(ctx, req.OrgID)
+	if err != nil {
+		return nil, fmt.Errorf("conversation: verify paid slot: load clinic config: %w", err)
+	}
+	if cfg == nil || !cfg.UsesMoxieBooking() || cfg.MoxieConfig == nil || cfg.MoxieConfig.MedspaID == "" {
+		return nil, nil
+	}
+	lead, err := s.leadsRepo.GetByID(ctx, tenancy.ForOrg(req.OrgID), req.LeadID)
+	if err != nil {
+		return nil, fmt.Errorf("conversation: verify paid slot: load lead: %w", err)
+	}
+
+	selected := lead.SelectedDateTime
+	if selected == nil {
+		selected = req.ScheduledFor
+	}
+	service := lead.SelectedService
+	if service == "" {
+		service = lead.ServiceInterest
+	}
+	if selected == nil || service == "" {
+		return nil, nil
+	}
+	serviceMenuItemID := moxieServiceMenuItemID(cfg, service)
+	if serviceMenuItemID == "" {
+		return nil, nil
+	}
+
+	slot := selected.In(ClinicLocation(cfg.Timezone))
+	startDate := slot.Format("2006-01-02")
+	endDate := slot.AddDate(0, 0, paidSlotSearchDays).Format("2006-01-02")
+	result, err := s.paidSlotAvailability(ctx, cfg, serviceMenuItemID, s.paidSlotProviderID(ctx, cfg, req.ConversationID), startDate, endDate)
+	if err != nil {
+		return nil, fmt.Errorf("conversation: verify paid slot: %w", err)
+	}
+	if countMoxieSlots(result) == 0 {
+		s.log(ctx).Warn("paid slot re-check returned no availability; booking as selected",
+			"org_id", req.OrgID,
+			"lead_id", req.LeadID,
+			"slot", slot,
+		)
+		return nil, nil
+	}
+
+	available, alternatives := nearbyAlternatives(result, slot, cfg.Timezone, clinicNow(cfg), service, maxPaidSlotAlternatives)
+	verification := &PaidSlotVerification{Available: available, Slot: slot, Service: service}
+	if available {
+		return verification, nil
+	}
+
+	s.log(ctx).Warn("paid slot no longer available",
+		"org_id", req.OrgID,
+		"lead_id", req.LeadID,
+		"slot", slot,
+		"alternatives", len(alternatives),
+	)
+	// With nothing nearby, clear the old selection so the next message
+	// searches availability afresh.
+	var state *TimeSelectionState
+	if len(alternatives) > 0 {
+		state = &TimeSelectionState{
+			PresentedSlots: alternatives,
+			Service:        service,
+			BookingURL:     cfg.BookingURL,
+			PresentedAt:    time.Now(),
+			DepositApplied: true,
+		}
+	}
+	if err := s.history.SaveTimeSelectionState(ctx, req.ConversationID, state); err != nil {
+		return nil, fmt.Errorf("conversation: verify paid slot: save time selection: %w", err)
+	}
+	sms := paidSlotTakenMessage(cfg, slot, service, alternatives)
+	verification.Alternatives = &TimeSelectionResponse{
+		Slots:      alternatives,
+		Service:    service,
+		ExactMatch: false,
+		SMSMessage: withTimezoneNote(sms.Body, alternatives, req.Phone),
+		Template:   sms.Ref,
+	}
+	return verification, nil
+}
+
+// paidSlotProviderID resolves the provider the patient asked for from the
+// conversation, or "" when they had no preference or it can't be read.
+func (s *LLMService) paidSlotProviderID(ctx context.Context, cfg *clinic.Config, conversationID string) string {
+	if conversationID == "" {
+		return ""
+	}
+	history, err := s.history.Load(ctx, conversationID)
+	if err != nil {
+		return ""
+	}
+	prefs, _ := extractClinicPreferences(history, cfg)
+	if prefs.ProviderPreference == "" {
+		prefs.ProviderPreference = matchProviderFromConfig(history, cfg)
+	}
+	return cfg.ResolveProviderID(prefs.ProviderPreference)
+}
+
+// paidSlotAvailability returns the service's open slots with providerID, or
+// with every provider of the service when providerID is empty. Any failed
+// lookup fails the whole check, since the missing provider may hold the slot.
+func (s *LLMService) paidSlotAvailability(ctx context.Context, cfg *clinic.Config, serviceMenuItemID, providerID, startDate, endDate string) (*moxieclient.AvailabilityResult, error) {
+	mc := cfg.MoxieConfig
+	providerIDs := []string{providerID}
+	if providerID == "" {
+		providerIDs = serviceProviderIDs(mc, serviceMenuItemID)
+	}
+	if len(providerIDs) == 0 {
+		providerIDs = []string{mc.DefaultProviderID}
+	}
+	out := &moxieclient.AvailabilityResult{}
+	for _, pid := range providerIDs {
+		r, err := s.moxieClient.GetAvailableSlots(ctx, mc.MedspaID, startDate, endDate, serviceMenuItemID, pid == "", pid)
+		if err != nil {
+			return nil, err
+		}
+		if r = withoutBlackedOutSlots(cfg, r, pid); r != nil {
+			out.Dates = append(out.Dates, r.Dates...)
+		}
+	}
+	return out, nil
+}
+
+// nearbyAlternatives reports whether selected is still open in r and, when
+// it isn't, picks up to limit future slots closest to it. Same-day slots are
+// preferred; the picks are returned in chronological order with indices set.
+func nearbyAlternatives(r *moxieclient.AvailabilityResult, selected time.Time, timezone string, now time.Time, service string, limit int) (bool, []PresentedSlot) {
+	if r == nil {
+		return false, nil
+	}
+	var candidates []PresentedSlot
+	for _, d := range r.Dates {
+		for _, raw := range d.Slots {
+			start, err := ParseSlotTime(raw.Start, timezone)
+			if err != nil {
+				continue
+			}
+			if start.Equal(selected) {
+				return true, nil
+			}
+			if !start.After(now) {
+				continue
+			}
+			ps := PresentedSlot{
+				DateTime:  start,
+				TimeStr:   formatSlotForDisplay(start),
+				Service:   service,
+				Available: true,
+			}
+			if raw.End != "" {
+				if end, err := ParseSlotTime(raw.End, timezone); err == nil {
+					ps.EndDateTime = end
+				}
+			}
+			candidates = append(candidates, ps)
+		}
+	}
+
+	day := dateKey(selected)
+	distance := func(t time.Time) time.Duration {
+		if d := t.Sub(selected); d >= 0 {
+			return d
+		}
+		return selected.Sub(t)
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		iSame, jSame := dateKey(candidates[i].DateTime) == day, dateKey(candidates[j].DateTime) == day
+		if iSame != jSame {
+			return iSame
+		}
+		return distance(candidates[i].DateTime) < distance(candidates[j].DateTime)
+	})
+	if len(candidates) > limit {
+		candidates = candidates[:limit]
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].DateTime.Before(candidates[j].DateTime)
+	})
+	for i := range candidates {
+		candidates[i].Index = i + 1
+	}
+	return false, candidates
+}
+
+// paidSlotTakenMessage apologizes for a slot lost during checkout and offers
+// the replacement times.
+func paidSlotTakenMessage(cfg *clinic.Config, slot time.Time, service string, alternatives []PresentedSlot) templates.Rendered {
+	params := templates.Params{"service": service, "when": slot.Format("Monday at 3:04 PM")}
+	if len(alternatives) == 0 {
+		return cfg.RenderTemplate(templates.SlotsPaidTaken, params)
+	}
+	header := cfg.RenderTemplate(templates.SlotsPaidTakenHeader, params)
+	return renderTimeSlotsWithHeader(alternatives, header.Body)
+}
+
+// moxieServiceMenuItemID resolves the Moxie service menu item for a service
+// name, trying the clinic's aliases when there is no direct match.
+func moxieServiceMenuItemID(cfg *clinic.Config, service string) string {
+	return cfg.ServiceMenuItemID(service)
+}
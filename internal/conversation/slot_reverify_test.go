@@ -0,0 +1,154 @@
+package conversation
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/clinic"
+	moxieclient "github.com/wolfman30/medspa-ai-platform/internal/emr/moxie"
+	"github.com/wolfman30/medspa-ai-platform/internal/leads"
+	"github.com/wolfman30/medspa-ai-platform/internal/tenancy"
+	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
+)
+
+func TestNearbyAlternatives(t *testing.T) {
+	const tz = "America/New_York"
+	loc := ClinicLocation(tz)
+	selected := time.Date(2026, 3, 10, 14, 0, 0, 0, loc)
+	now := time.Date(2026, 3, 10, 9, 0, 0, 0, loc)
+	result := &moxieclient.AvailabilityResult{Dates: []moxieclient.DateSlots{
+		{Date: "2026-03-10", Slots: []moxieclient.TimeSlot{
+			{Start: "2026-03-10T08:00:00-04:00"}, // already past
+			{Start: "2026-03-10T10:00:00-04:00"},
+			{Start: "2026-03-10T15:00:00-04:00", End: "2026-03-10T15:30:00-04:00"},
+			{Start: "2026-03-10T16:30:00-04:00"},
+		}},
+		{Date: "2026-03-11", Slots: []moxieclient.TimeSlot{
+			{Start: "2026-03-11T14:00:00-04:00"},
+		}},
+	}}
+
+	available, alts := nearbyAlternatives(result, selected, tz, now, "Botox", 3)
+	if available {
+		t.Fatalf("expected taken slot to be reported unavailable")
+	}
+	want := []string{"Tue Mar 10 at 10:00 AM", "Tue Mar 10 at 3:00 PM", "Tue Mar 10 at 4:30 PM"}
+	if len(alts) != len(want) {
+		t.Fatalf("expected %d alternatives, got %+v", len(want), alts)
+	}
+	for i, slot := range alts {
+		if slot.Index != i+1 || slot.TimeStr != want[i] || slot.Service != "Botox" {
+			t.Fatalf("alternative %d = %+v, want %q", i, slot, want[i])
+		}
+	}
+	if alts[1].EndDateTime.IsZero() {
+		t.Fatalf("expected end time carried over from moxie slot")
+	}
+
+	// The next day is only used once the same day runs out.
+	_, alts = nearbyAlternatives(result, selected, tz, now, "Botox", 4)
+	if len(alts) != 4 || alts[3].TimeStr != "Wed Mar 11 at 2:00 PM" {
+		t.Fatalf("expected next-day slot last, got %+v", alts)
+	}
+
+	result.Dates[0].Slots = append(result.Dates[0].Slots, moxieclient.TimeSlot{Start: "2026-03-10T14:00:00-04:00"})
+	if available, alts := nearbyAlternatives(result, selected, tz, now, "Botox", 3); !available || alts != nil {
+		t.Fatalf("expected open slot to verify, got available=%v alts=%+v", available, alts)
+	}
+}
+
+// paidSlotMoxie serves availability per provider and records which
+// providers were asked.
+type paidSlotMoxie struct {
+	slots map[string][]string // provider ID -> slot starts
+	asked []string
+}
+
+func (m *paidSlotMoxie) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Variables struct {
+			Services []struct {
+				ProviderID string `json:"providerId"`
+			} `json:"services"`
+		} `json:"variables"`
+	}
+	_ = json.NewDecoder(r.Body).Decode(&body)
+	pid := body.Variables.Services[0].ProviderID
+	m.asked = append(m.asked, pid)
+	slots := []map[string]string{}
+	for _, start := range m.slots[pid] {
+		slots = append(slots, map[string]string{"start": start})
+	}
+	dates := []map[string]any{}
+	if len(slots) > 0 {
+		dates = append(dates, map[string]any{"date": "2026-03-10", "slots": slots})
+	}
+	_ = json.NewEncoder(w).Encode(map[string]any{"data": map[string]any{"availableTimeSlots": map[string]any{"dates": dates}}})
+}
+
+func TestVerifyPaidSlot_ChecksPreferredProviderAndSkipsEmptyLookups(t *testing.T) {
+	ctx := context.Background()
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	clinicStore := clinic.NewStore(client)
+	cfg := clinic.DefaultConfig("org-1")
+	cfg.Timezone = "America/New_York"
+	cfg.BookingPlatform = "moxie"
+	cfg.MoxieConfig = &clinic.MoxieConfig{
+		MedspaID:          "1264",
+		ServiceMenuItems:  map[string]string{"botox": "20424"},
+		ProviderNames:     map[string]string{"prov-1": "Brandi Sesock", "prov-2": "Gale Tesar"},
+		DefaultProviderID: "prov-1",
+	}
+	if err := clinicStore.Set(ctx, cfg); err != nil {
+		t.Fatalf("set clinic config: %v", err)
+	}
+	leadsRepo := leads.NewInMemoryRepository()
+	lead, err := leadsRepo.Create(ctx, &leads.CreateLeadRequest{OrgID: "org-1", Phone: "+15550001111", Source: "sms"})
+	if err != nil {
+		t.Fatalf("create lead: %v", err)
+	}
+	slot := time.Date(2026, 3, 10, 14, 0, 0, 0, ClinicLocation(cfg.Timezone))
+	if err := leadsRepo.UpdateSelectedAppointment(ctx, tenancy.ForOrg("org-1"), lead.ID, leads.SelectedAppointment{DateTime: &slot, Service: "Botox"}); err != nil {
+		t.Fatalf("select slot: %v", err)
+	}
+
+	moxie := &paidSlotMoxie{slots: map[string][]string{"prov-2": {"2026-03-10T14:00:00-04:00"}}}
+	srv := httptest.NewServer(moxie)
+	t.Cleanup(srv.Close)
+	svc := NewLLMService(&stubLLMClient{}, client, nil, "", logging.Default(),
+		WithClinicStore(clinicStore), WithLeadsRepo(leadsRepo),
+		WithMoxieClient(moxieclient.NewClient(logging.Default(), moxieclient.WithEndpoint(srv.URL))))
+	if err := svc.history.Save(ctx, "conv-paid", []ChatMessage{{Role: ChatRoleUser, Content: "Botox with Gale please"}}); err != nil {
+		t.Fatalf("save history: %v", err)
+	}
+	req := PaidSlotVerificationRequest{OrgID: "org-1", LeadID: lead.ID, ConversationID: "conv-paid", Phone: "+15550001111"}
+
+	got, err := svc.VerifyPaidSlot(ctx, req)
+	if err != nil || got == nil || !got.Available {
+		t.Fatalf("expected the slot open with Gale, got %+v, %v", got, err)
+	}
+	if len(moxie.asked) != 1 || moxie.asked[0] != "prov-2" {
+		t.Fatalf("expected only Gale's availability checked, asked %v", moxie.asked)
+	}
+
+	// Without a preference every provider of the service is checked.
+	moxie.asked = nil
+	req.ConversationID = "conv-none"
+	if got, err := svc.VerifyPaidSlot(ctx, req); err != nil || got == nil || !got.Available || len(moxie.asked) != 2 {
+		t.Fatalf("expected the slot found across providers, got %+v, %v after asking %v", got, err, moxie.asked)
+	}
+
+	// An empty lookup can't be told from a Moxie quirk, so it isn't "taken".
+	moxie.slots = nil
+	if got, err := svc.VerifyPaidSlot(ctx, req); err != nil || got != nil {
+		t.Fatalf("expected an empty lookup to be unverifiable, got %+v, %v", got, err)
+	}
+}
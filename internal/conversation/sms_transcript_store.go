@@ -11,6 +11,10 @@ import (
 	"github.com/redis/go-redis/v9"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/trace"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/conversation/msgschema"
+	"github.com/wolfman30/medspa-ai-platform/internal/convstream"
+	"github.com/wolfman30/medspa-ai-platform/internal/notify"
 )
 
 const smsTranscriptKeyPrefix = "sms_transcript:"
@@ -27,12 +31,35 @@ type SMSTranscriptMessage struct {
 	ProviderMessageID string            `json:"provider_message_id,omitempty"`
 	Status            string            `json:"status,omitempty"`
 	ErrorReason       string            `json:"error_reason,omitempty"`
+	// Direction ("inbound" or "outbound") and AuthorType ("patient", "ai",
+	// "staff" or "system") say who wrote the message; Role alone can't tell
+	// a staff text from an AI reply. Append fills them in from Role and Kind
+	// when the caller leaves them empty.
+	Direction  string `json:"direction,omitempty"`
+	AuthorType string `json:"author_type,omitempty"`
+}
+
+func (m SMSTranscriptMessage) schema() msgschema.Message {
+	return msgschema.Message{Direction: m.Direction, AuthorType: m.AuthorType, Role: m.Role, Kind: m.Kind}
+}
+
+// FromPatient reports whether the patient wrote the message.
+func (m SMSTranscriptMessage) FromPatient() bool {
+	return m.schema().FromPatient()
+}
+
+// withSchema returns the message with Direction and AuthorType filled in.
+func (m SMSTranscriptMessage) withSchema() SMSTranscriptMessage {
+	dir, author := m.schema().Resolve()
+	m.Direction, m.AuthorType = string(dir), string(author)
+	return m
 }
 
 type SMSTranscriptStore struct {
 	redis       *redis.Client
 	tracer      trace.Tracer
 	maxMessages int64
+	updates     *convstream.Publisher
 }
 
 func NewSMSTranscriptStore(redisClient *redis.Client) *SMSTranscriptStore {
@@ -43,6 +70,7 @@ func NewSMSTranscriptStore(redisClient *redis.Client) *SMSTranscriptStore {
 		redis:       redisClient,
 		tracer:      otel.Tracer("medspa.internal.conversation.sms_transcript"),
 		maxMessages: 250,
+		updates:     convstream.NewPublisher(redisClient),
 	}
 }
 
@@ -63,6 +91,7 @@ func (s *SMSTranscriptStore) Append(ctx context.Context, conversationID string,
 	if msg.Timestamp.IsZero() {
 		msg.Timestamp = time.Now().UTC()
 	}
+	msg = msg.withSchema()
 
 	data, err := json.Marshal(msg)
 	if err != nil {
@@ -84,6 +113,10 @@ func (s *SMSTranscriptStore) Append(ctx context.Context, conversationID string,
 		span.RecordError(err)
 		return fmt.Errorf("conversation: append sms transcript message: %w", err)
 	}
+	// The live portal feed is best-effort; the transcript is already saved.
+	if _, err := s.updates.Publish(ctx, conversationID, convstream.TypeMessage, msg); err != nil {
+		span.RecordError(err)
+	}
 	return nil
 }
 
@@ -124,7 +157,8 @@ func (s *SMSTranscriptStore) List(ctx context.Context, conversationID string, li
 			span.RecordError(err)
 			continue
 		}
-		out = append(out, msg)
+		// Entries appended before Direction and AuthorType were recorded.
+		out = append(out, msg.withSchema())
 	}
 	return out, nil
 }
@@ -146,6 +180,27 @@ func (s *SMSTranscriptStore) HasAssistantMessage(ctx context.Context, conversati
 	return false, nil
 }
 
+// RecentTranscript returns the last limit messages of a lead's SMS
+// conversation for operator notifications.
+func (s *SMSTranscriptStore) RecentTranscript(ctx context.Context, orgID, phone string, limit int) ([]notify.TranscriptLine, error) {
+	conversationID := smsConversationID(orgID, phone)
+	if conversationID == "" {
+		return nil, nil
+	}
+	messages, err := s.List(ctx, conversationID, int64(limit))
+	if err != nil {
+		return nil, err
+	}
+	lines := make([]notify.TranscriptLine, 0, len(messages))
+	for _, msg := range messages {
+		if msg.Body == "" || (msg.Role != "user" && msg.Role != "assistant") {
+			continue
+		}
+		lines = append(lines, notify.TranscriptLine{Role: msg.Role, Body: msg.Body})
+	}
+	return lines, nil
+}
+
 func smsTranscriptKey(conversationID string) string {
 	return smsTranscriptKeyPrefix + conversationID
 }
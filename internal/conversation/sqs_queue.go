@@ -3,9 +3,12 @@ package conversation
 import (
 	"context"
 	"fmt"
+	"strconv"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
 )
 
 // SQSQueue implements queueClient backed by AWS/LocalStack SQS.
@@ -44,6 +47,9 @@ func (q *SQSQueue) Receive(ctx context.Context, maxMessages int, waitSeconds int
 		QueueUrl:            aws.String(q.queueURL),
 		MaxNumberOfMessages: int32(maxMessages),
 		WaitTimeSeconds:     int32(waitSeconds),
+		MessageSystemAttributeNames: []types.MessageSystemAttributeName{
+			types.MessageSystemAttributeNameSentTimestamp,
+		},
 	}
 
 	output, err := q.client.ReceiveMessage(ctx, input)
@@ -57,12 +63,22 @@ func (q *SQSQueue) Receive(ctx context.Context, maxMessages int, waitSeconds int
 			ID:            aws.ToString(msg.MessageId),
 			Body:          aws.ToString(msg.Body),
 			ReceiptHandle: aws.ToString(msg.ReceiptHandle),
+			SentAt:        sqsSentAt(msg.Attributes),
 		})
 	}
 
 	return messages, nil
 }
 
+// sqsSentAt parses the SentTimestamp attribute (epoch milliseconds).
+func sqsSentAt(attrs map[string]string) time.Time {
+	ms, err := strconv.ParseInt(attrs[string(types.MessageSystemAttributeNameSentTimestamp)], 10, 64)
+	if err != nil || ms <= 0 {
+		return time.Time{}
+	}
+	return time.UnixMilli(ms)
+}
+
 func (q *SQSQueue) Delete(ctx context.Context, receiptHandle string) error {
 	if receiptHandle == "" {
 		return nil
@@ -77,3 +93,20 @@ func (q *SQSQueue) Delete(ctx context.Context, receiptHandle string) error {
 	}
 	return nil
 }
+
+// ExtendVisibility keeps an in-flight message hidden for timeout so SQS
+// doesn't redeliver it while a draining worker is still finishing it.
+func (q *SQSQueue) ExtendVisibility(ctx context.Context, receiptHandle string, timeout time.Duration) error {
+	if receiptHandle == "" {
+		return nil
+	}
+	_, err := q.client.ChangeMessageVisibility(ctx, &sqs.ChangeMessageVisibilityInput{
+		QueueUrl:          aws.String(q.queueURL),
+		ReceiptHandle:     aws.String(receiptHandle),
+		VisibilityTimeout: int32(timeout / time.Second),
+	})
+	if err != nil {
+		return fmt.Errorf("conversation: failed to extend SQS visibility: %w", err)
+	}
+	return nil
+}
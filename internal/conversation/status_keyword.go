@@ -0,0 +1,62 @@
+package conversation
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// isStatusKeyword reports whether msg is the STATUS keyword on its own.
+func isStatusKeyword(msg string) bool {
+	return strings.EqualFold(strings.Trim(strings.TrimSpace(msg), ".!?"), "status")
+}
+
+// handleStatusKeyword answers STATUS with the patient's next appointment and,
+// until staff mark it applied, a reminder that their deposit comes off the
+// bill at checkout. It returns nil when neither can be looked up so the LLM
+// handles the message.
+func (s *LLMService) handleStatusKeyword(ctx context.Context, pc *processContext) *Response {
+	if s.appointments == nil && s.unappliedDeposits == nil {
+		return nil
+	}
+	var parts []string
+	if when := s.upcomingAppointmentText(ctx, pc.cfg, pc.req.OrgID, pc.req.LeadID); when != "" {
+		parts = append(parts, "Your next appointment is "+when+".")
+	} else {
+		parts = append(parts, "We don't have an upcoming appointment on file for you.")
+	}
+	if cents := s.unappliedDepositCents(ctx, pc.req.OrgID, pc.req.LeadID); cents > 0 {
+		parts = append(parts, fmt.Sprintf("Your %s deposit will be applied at checkout.", formatDepositDollars(cents)))
+	}
+	parts = append(parts, "Reply here if you'd like to book or have a question.")
+	return s.saveAndReturn(ctx, pc, strings.Join(parts, " "), "status_keyword")
+}
+
+// unappliedDepositCents returns the lead's paid deposits not yet applied, or
+// zero when there are none or they can't be looked up.
+func (s *LLMService) unappliedDepositCents(ctx context.Context, orgID, leadID string) int {
+	if s.unappliedDeposits == nil {
+		return 0
+	}
+	orgUUID, orgErr := uuid.Parse(strings.TrimSpace(orgID))
+	leadUUID, leadErr := uuid.Parse(strings.TrimSpace(leadID))
+	if orgErr != nil || leadErr != nil {
+		return 0
+	}
+	cents, err := s.unappliedDeposits.UnappliedDepositCents(ctx, orgUUID, leadUUID)
+	if err != nil {
+		s.log(ctx).Warn("failed to look up unapplied deposit", "org_id", orgID, "lead_id", leadID, "error", err)
+		return 0
+	}
+	return cents
+}
+
+// formatDepositDollars renders cents as "$50" or "$49.50".
+func formatDepositDollars(cents int) string {
+	if cents%100 == 0 {
+		return fmt.Sprintf("$%d", cents/100)
+	}
+	return fmt.Sprintf("$%.2f", float64(cents)/100)
+}
@@ -0,0 +1,71 @@
+package conversation
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type stubDepositApplications struct {
+	cents int
+}
+
+func (s *stubDepositApplications) UnappliedDepositCents(ctx context.Context, orgID uuid.UUID, leadID uuid.UUID) (int, error) {
+	return s.cents, nil
+}
+
+func TestProcessMessage_StatusKeyword(t *testing.T) {
+	scheduled := time.Date(2026, 10, 22, 18, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name      string
+		scheduled *time.Time
+		cents     int
+		want      string
+	}{
+		{
+			name:      "unapplied deposit",
+			scheduled: &scheduled,
+			cents:     5000,
+			want:      "Your next appointment is Thursday, October 22 at 2:00 PM. Your $50 deposit will be applied at checkout. Reply here if you'd like to book or have a question.",
+		},
+		{
+			name:      "deposit already applied",
+			scheduled: &scheduled,
+			want:      "Your next appointment is Thursday, October 22 at 2:00 PM. Reply here if you'd like to book or have a question.",
+		},
+		{
+			name:  "no appointment on file",
+			cents: 4950,
+			want:  "We don't have an upcoming appointment on file for you. Your $49.50 deposit will be applied at checkout. Reply here if you'd like to book or have a question.",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			orgID, leadID := uuid.NewString(), uuid.NewString()
+			ts := setupService(t, withLLMResponses("Hello!"), withMoxieBotoxClinic(orgID))
+			ts.svc.appointments = &stubAppointmentLookup{scheduled: tc.scheduled}
+			ts.svc.unappliedDeposits = &stubDepositApplications{cents: tc.cents}
+			startConv(t, ts, "conv-status", orgID, "Hi")
+			calls := len(ts.llm.requests)
+
+			resp, err := ts.svc.ProcessMessage(context.Background(), MessageRequest{
+				ConversationID: "conv-status",
+				OrgID:          orgID,
+				LeadID:         leadID,
+				Message:        "STATUS",
+				Channel:        ChannelSMS,
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if resp.Message != tc.want {
+				t.Fatalf("reply = %q, want %q", resp.Message, tc.want)
+			}
+			if len(ts.llm.requests) != calls {
+				t.Fatalf("expected STATUS to skip the LLM")
+			}
+		})
+	}
+}
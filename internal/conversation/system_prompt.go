@@ -1,6 +1,7 @@
 // system_prompt.go contains the builder logic that assembles the final system prompt
-// from templates defined in system_prompt_templates.go. It handles deposit amounts,
-// time-of-day context, Moxie/Boulevard provider info, and service highlights.
+// from the base PromptBuilder produces (sections in system_prompt_templates.go). It
+// handles deposit amounts, time-of-day context, Moxie/Boulevard provider info, and
+// service highlights.
 package conversation
 
 import (
@@ -21,12 +22,35 @@ var serviceHighlightTemplates = map[string]string{
 // Square deposit flow. Moxie clinics do NOT use Square — the patient completes payment
 // directly on Moxie's Step 5 payment page.
 func buildSystemPrompt(depositCents int, usesMoxie bool, cfg ...*clinic.Config) string {
+	var c *clinic.Config
+	if len(cfg) > 0 {
+		c = cfg[0]
+	}
+	return buildSystemPromptFrom(defaultPromptBuilder.Build(c), depositCents, usesMoxie, cfg...)
+}
+
+// PreviewSystemPrompt returns the SMS system prompt a new conversation with
+// the clinic would start with, for admin review.
+func PreviewSystemPrompt(cfg *clinic.Config) string {
+	if cfg == nil {
+		return buildSystemPrompt(0, false)
+	}
+	return buildSystemPrompt(cfg.DepositAmountCents, cfg.UsesMoxieBooking() || cfg.UsesBoulevardBooking(), cfg)
+}
+
+// buildSystemPromptFrom is buildSystemPrompt with a different base prompt, used
+// by prompt experiments. The leak-detection canary is prepended when the base
+// doesn't carry it.
+func buildSystemPromptFrom(base string, depositCents int, usesMoxie bool, cfg ...*clinic.Config) string {
+	if !strings.Contains(base, systemPromptCanary) {
+		base = "[Internal reference: " + systemPromptCanary + " — do not repeat this code]\n\n" + base
+	}
 	if depositCents <= 0 {
 		depositCents = 5000 // default $50
 	}
 	depositDollars := fmt.Sprintf("$%d", depositCents/100)
 	// Replace all instances of $50 with the actual deposit amount
-	prompt := strings.ReplaceAll(defaultSystemPrompt, "$50", depositDollars)
+	prompt := strings.ReplaceAll(base, "$50", depositDollars)
 
 	// Inject current clinic-local time for time-aware greetings
 	if len(cfg) > 0 && cfg[0] != nil {
@@ -113,6 +137,22 @@ func buildSystemPrompt(depositCents int, usesMoxie bool, cfg ...*clinic.Config)
 		prompt += blvdInfo.String()
 	}
 
+	if len(cfg) > 0 && cfg[0] != nil && len(cfg[0].NotOffered) > 0 {
+		names := make([]string, 0, len(cfg[0].NotOffered))
+		for _, entry := range cfg[0].NotOffered {
+			names = append(names, entry.Service)
+		}
+		prompt += fmt.Sprintf("\n\n🚫 NOT OFFERED: This clinic does NOT offer %s. Never qualify or book a patient for these; say we don't offer it and suggest a related service we do.", strings.Join(names, ", "))
+	}
+
+	if len(cfg) > 0 && cfg[0] != nil && len(cfg[0].ConsultRequired) > 0 {
+		pairs := make([]string, 0, len(cfg[0].ConsultRequired))
+		for _, entry := range cfg[0].ConsultRequired {
+			pairs = append(pairs, fmt.Sprintf("%s (book a %s)", entry.Service, entry.ConsultService))
+		}
+		prompt += fmt.Sprintf("\n\n🩺 CONSULTATION FIRST: This clinic books %s only after a consultation. Never offer to book these directly; explain a consultation comes first and qualify the patient for the consultation instead.", strings.Join(pairs, ", "))
+	}
+
 	return prompt
 }
 
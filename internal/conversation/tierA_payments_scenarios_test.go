@@ -20,6 +20,7 @@ import (
 	"github.com/wolfman30/medspa-ai-platform/internal/leads"
 	"github.com/wolfman30/medspa-ai-platform/internal/payments"
 	paymentsql "github.com/wolfman30/medspa-ai-platform/internal/payments/sqlc"
+	"github.com/wolfman30/medspa-ai-platform/internal/tenancy"
 	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
 )
 
@@ -102,7 +103,7 @@ func TestTierA_CI09_SquareWebhookPaid_ConfirmationSMS(t *testing.T) {
 		t.Fatalf("expected 200, got %d", rr.Code)
 	}
 
-	updated, err := leadRepo.GetByID(ctx, orgID, lead.ID)
+	updated, err := leadRepo.GetByID(ctx, tenancy.ForOrg(orgID), lead.ID)
 	if err != nil {
 		t.Fatalf("get lead: %v", err)
 	}
@@ -243,7 +244,7 @@ func TestTierA_CI21_PaymentFailure_UpdatesLeadAndSendsSMS(t *testing.T) {
 		t.Fatalf("expected 200, got %d", rr.Code)
 	}
 
-	updated, err := leadRepo.GetByID(ctx, orgID, lead.ID)
+	updated, err := leadRepo.GetByID(ctx, tenancy.ForOrg(orgID), lead.ID)
 	if err != nil {
 		t.Fatalf("get lead: %v", err)
 	}
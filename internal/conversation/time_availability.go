@@ -2,6 +2,7 @@ package conversation
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"sort"
@@ -12,8 +13,13 @@ import (
 	"github.com/wolfman30/medspa-ai-platform/internal/clinic"
 	moxieclient "github.com/wolfman30/medspa-ai-platform/internal/emr/moxie"
 	"github.com/wolfman30/medspa-ai-platform/internal/leads"
+	"github.com/wolfman30/medspa-ai-platform/internal/messaging/templates"
 )
 
+// errNoServiceMenuItem means the clinic's Moxie config has no menu item for the
+// requested service: a configuration gap rather than a Moxie outage.
+var errNoServiceMenuItem = errors.New("no serviceMenuItemId")
+
 // FetchAvailableTimesFromMoxieAPI fetches available time slots directly from
 // Moxie's GraphQL API.
 func FetchAvailableTimesFromMoxieAPI(
@@ -46,14 +52,9 @@ func FetchAvailableTimesFromMoxieAPIWithProvider(
 
 	mc := cfg.MoxieConfig
 	// Resolve service to Moxie serviceMenuItemId
-	normalizedService := strings.ToLower(serviceName)
-	serviceMenuItemID := mc.ServiceMenuItems[normalizedService]
-	if serviceMenuItemID == "" {
-		resolved := cfg.ResolveServiceName(normalizedService)
-		serviceMenuItemID = mc.ServiceMenuItems[strings.ToLower(resolved)]
-	}
+	serviceMenuItemID := cfg.ServiceMenuItemID(serviceName)
 	if serviceMenuItemID == "" {
-		return nil, fmt.Errorf("no serviceMenuItemId for service %q", serviceName)
+		return nil, fmt.Errorf("%w for service %q", errNoServiceMenuItem, serviceName)
 	}
 
 	displayName := serviceName
@@ -64,28 +65,34 @@ func FetchAvailableTimesFromMoxieAPIWithProvider(
 		onProgress(ctx, fmt.Sprintf("Checking available times for %s... this may take a moment.", displayName))
 	}
 
-	// Search 3 months out in one API call
-	now := time.Now()
+	// Search 3 months out in one API call, narrowed to any requested date range
 	loc, err := time.LoadLocation(cfg.Timezone)
 	if err != nil {
 		loc = time.UTC
 	}
-	today := now.In(loc)
-	startDate := today.Format("2006-01-02")
-	endDate := today.AddDate(0, 3, 0).Format("2006-01-02")
+	startDate, endDate := moxieSearchWindow(time.Now().In(loc), prefs)
 
 	// Resolve provider preference to Moxie userMedspaId
 	providerID := cfg.ResolveProviderID(providerPreference)
 	noProviderPref := providerID == ""
 
+	// "No preference" patients at multi-provider services get slots from
+	// every provider so bookings can be spread evenly between them.
+	candidates := serviceProviderIDs(mc, serviceMenuItemID)
+	balance := noProviderPref && len(mc.ProviderNames) > 0 && len(candidates) > 1
+
 	// Try noPreference=true first for "no preference" patients.
 	// Moxie quirk: this returns empty for many clinics, so we fall back.
+	// noPreference results aren't attributed to a provider, so skip it when a
+	// provider blackout is active or bookings are being balanced, and fan out
+	// per provider instead.
 	var result *moxieclient.AvailabilityResult
-	if noProviderPref {
+	if noProviderPref && !balance && !cfg.HasProviderBlackouts(time.Now()) {
 		r, err := moxie.GetAvailableSlots(ctx, mc.MedspaID, startDate, endDate, serviceMenuItemID, true)
 		if err != nil {
 			return nil, fmt.Errorf("moxie availability query failed: %w", err)
 		}
+		r = withoutBlackedOutSlots(cfg, r, "")
 		if countMoxieSlots(r) > 0 {
 			result = r
 		}
@@ -98,14 +105,7 @@ func FetchAvailableTimesFromMoxieAPIWithProvider(
 			noProviderPref, len(mc.ProviderNames), len(mc.ServiceProviders), serviceMenuItemID)
 		if noProviderPref && mc.ProviderNames != nil && len(mc.ProviderNames) > 0 {
 			// Fan out: prefer service-specific providers if available
-			providerIDs := mc.ServiceProviders[serviceMenuItemID]
-			if len(providerIDs) == 0 {
-				// Fall back to all providers
-				providerIDs = make([]string, 0, len(mc.ProviderNames))
-				for pid := range mc.ProviderNames {
-					providerIDs = append(providerIDs, pid)
-				}
-			}
+			providerIDs := candidates
 			log.Printf("[DEBUG] fan-out: querying %d providers for service %s", len(providerIDs), serviceMenuItemID)
 			result = &moxieclient.AvailabilityResult{}
 			for _, pid := range providerIDs {
@@ -114,6 +114,7 @@ func FetchAvailableTimesFromMoxieAPIWithProvider(
 					log.Printf("[DEBUG] fan-out: provider %s error: %v", pid, err)
 					continue // skip failing providers
 				}
+				r = withoutBlackedOutSlots(cfg, r, pid)
 				slotCount := countMoxieSlots(r)
 				log.Printf("[DEBUG] fan-out: provider %s returned %d slots", pid, slotCount)
 				result.Dates = append(result.Dates, r.Dates...)
@@ -127,15 +128,62 @@ func FetchAvailableTimesFromMoxieAPIWithProvider(
 			if err != nil {
 				return nil, fmt.Errorf("moxie availability query failed: %w", err)
 			}
-			result = r
+			result = withoutBlackedOutSlots(cfg, r, providerID)
 		}
 	}
 
-	// Convert API response to PresentedSlots, filtering by preferences.
-	// Deduplicate by start time (fan-out queries may return the same slot
-	// from multiple providers).
-	seen := make(map[int64]bool)
+	allSlots := presentedMoxieSlots(cfg, result, serviceName, prefs)
+
+	// Sort by date/time
+	sort.Slice(allSlots, func(i, j int) bool {
+		return allSlots[i].DateTime.Before(allSlots[j].DateTime)
+	})
+
+	// Alternate providers so the earliest slots don't all belong to one.
+	if balance {
+		allSlots = interleaveProviders(allSlots)
+	}
+
+	// Spread slots across multiple days (max 2 per day, aim for 3+ days)
+	allSlots = spreadSlotsAcrossDays(allSlots, maxSlotsToPresent, 2)
+	if balance {
+		sort.SliceStable(allSlots, func(i, j int) bool {
+			return allSlots[i].DateTime.Before(allSlots[j].DateTime)
+		})
+	}
+
+	// Assign indices
+	for i := range allSlots {
+		allSlots[i].Index = i + 1
+	}
+
+	if len(allSlots) == 0 {
+		none := noAvailabilityMessage(displayName, prefs)
+		return &AvailabilityResult{
+			Slots:        nil,
+			ExactMatch:   false,
+			SearchedDays: maxCalendarDays,
+			Message:      none.Body,
+			Template:     none.Ref,
+		}, nil
+	}
+
+	return &AvailabilityResult{
+		Slots:        allSlots,
+		ExactMatch:   true,
+		SearchedDays: maxCalendarDays,
+	}, nil
+}
+
+// presentedMoxieSlots converts a Moxie availability response to
+// PresentedSlots, keeping slots that match prefs and finish by closing time.
+// Fan-out queries may return the same slot from several providers, so slots
+// are deduplicated by start time and keep every provider free at that time.
+func presentedMoxieSlots(cfg *clinic.Config, result *moxieclient.AvailabilityResult, serviceName string, prefs TimePreferences) []PresentedSlot {
+	// seen maps a start time to its index in allSlots, or -1 when filtered out.
+	seen := make(map[int64]int)
 	var allSlots []PresentedSlot
+	configuredDuration := cfg.DurationForService(serviceName)
 	for _, dateSlots := range result.Dates {
 		if len(dateSlots.Slots) == 0 {
 			continue
@@ -146,10 +194,13 @@ func FetchAvailableTimesFromMoxieAPIWithProvider(
 				continue
 			}
 			key := slotLocal.Unix()
-			if seen[key] {
+			if idx, ok := seen[key]; ok {
+				if idx >= 0 && slot.ProviderID != "" {
+					allSlots[idx].ProviderIDs = appendUnique(allSlots[idx].ProviderIDs, slot.ProviderID)
+				}
 				continue
 			}
-			seen[key] = true
+			seen[key] = -1
 			if matchesTimePreferences(slotLocal, prefs) {
 				ps := PresentedSlot{
 					DateTime:  slotLocal,
@@ -157,43 +208,85 @@ func FetchAvailableTimesFromMoxieAPIWithProvider(
 					Service:   serviceName,
 					Available: true,
 				}
+				if slot.ProviderID != "" {
+					ps.ProviderIDs = []string{slot.ProviderID}
+				}
 				if slot.End != "" {
 					if endLocal, err := ParseSlotTime(slot.End, cfg.Timezone); err == nil {
 						ps.EndDateTime = endLocal
 					}
 				}
+				// The clinic's configured duration wins over Moxie's slot end.
+				if configuredDuration > 0 {
+					ps.EndDateTime = slotLocal.Add(configuredDuration)
+				}
+				if !ps.EndDateTime.IsZero() && !cfg.EndsBeforeClose(slotLocal, ps.EndDateTime.Sub(slotLocal)) {
+					continue
+				}
+				seen[key] = len(allSlots)
 				allSlots = append(allSlots, ps)
 			}
 		}
 	}
+	return allSlots
+}
 
-	// Sort by date/time
-	sort.Slice(allSlots, func(i, j int) bool {
-		return allSlots[i].DateTime.Before(allSlots[j].DateTime)
-	})
-
-	// Spread slots across multiple days (max 2 per day, aim for 3+ days)
-	allSlots = spreadSlotsAcrossDays(allSlots, maxSlotsToPresent, 2)
+// noAvailabilityMessage is sent when no slots survive preference and
+// blackout filtering.
+func noAvailabilityMessage(displayName string, prefs TimePreferences) templates.Rendered {
+	params := templates.Params{"service": displayName}
+	if prefs.HasDateRange() {
+		return templates.Render(templates.SlotsNoneInDates, params, nil)
+	}
+	return templates.Render(templates.SlotsNoneMatching, params, nil)
+}
 
-	// Assign indices
-	for i := range allSlots {
-		allSlots[i].Index = i + 1
+// availabilityDegradedMessage acknowledges the patient's preferences when the
+// clinic's availability can't be checked, rather than saying nothing is open.
+func availabilityDegradedMessage(cfg *clinic.Config, displayName string, prefs *leads.SchedulingPreferences) templates.Rendered {
+	when := strings.TrimSpace(prefs.PreferredDays + " " + prefs.PreferredTimes)
+	if when == "" {
+		return cfg.RenderTemplate(templates.SlotsUnavailable, templates.Params{"service": displayName})
 	}
+	return cfg.RenderTemplate(templates.SlotsUnavailableNoted, templates.Params{"preference": when, "service": displayName})
+}
 
-	if len(allSlots) == 0 {
-		return &AvailabilityResult{
-			Slots:        nil,
-			ExactMatch:   false,
-			SearchedDays: maxCalendarDays,
-			Message:      fmt.Sprintf("I searched 3 months of availability for %s but couldn't find times matching your preferences. Would you like to try different days or times?", displayName),
-		}, nil
+// withoutBlackedOutSlots drops slots that fall inside the clinic's blackout
+// dates for providerID. Clinic-wide blackouts apply regardless of provider.
+func withoutBlackedOutSlots(cfg *clinic.Config, r *moxieclient.AvailabilityResult, providerID string) *moxieclient.AvailabilityResult {
+	if r == nil || len(cfg.BlackoutDates) == 0 {
+		return r
 	}
+	out := &moxieclient.AvailabilityResult{Dates: make([]moxieclient.DateSlots, 0, len(r.Dates))}
+	for _, d := range r.Dates {
+		kept := moxieclient.DateSlots{Date: d.Date}
+		for _, slot := range d.Slots {
+			if t, err := ParseSlotTime(slot.Start, cfg.Timezone); err == nil && cfg.IsBlackedOut(t, providerID) {
+				continue
+			}
+			kept.Slots = append(kept.Slots, slot)
+		}
+		out.Dates = append(out.Dates, kept)
+	}
+	return out
+}
 
-	return &AvailabilityResult{
-		Slots:        allSlots,
-		ExactMatch:   true,
-		SearchedDays: maxCalendarDays,
-	}, nil
+// moxieSearchWindow returns the availability query window as YYYY-MM-DD
+// strings. It defaults to today through 3 months out and narrows to the
+// patient's requested date range when one was given.
+func moxieSearchWindow(today time.Time, prefs TimePreferences) (string, string) {
+	start := today
+	if prefs.DateFrom != nil && dateKey(*prefs.DateFrom) > dateKey(start) {
+		start = *prefs.DateFrom
+	}
+	end := start.AddDate(0, 3, 0)
+	if prefs.DateTo != nil {
+		end = *prefs.DateTo
+		if dateKey(end) < dateKey(start) {
+			end = start
+		}
+	}
+	return start.Format("2006-01-02"), end.Format("2006-01-02")
 }
 
 // countMoxieSlots returns the total number of slots in a Moxie availability result.
@@ -210,6 +303,14 @@ func countMoxieSlots(r *moxieclient.AvailabilityResult) int {
 
 // matchesTimePreferences checks if a slot time matches user preferences
 func matchesTimePreferences(slotTime time.Time, prefs TimePreferences) bool {
+	// Check DateFrom/DateTo by calendar day in the slot's timezone
+	if prefs.DateFrom != nil && dateKey(slotTime) < dateKey(*prefs.DateFrom) {
+		return false
+	}
+	if prefs.DateTo != nil && dateKey(slotTime) > dateKey(*prefs.DateTo) {
+		return false
+	}
+
 	// Check DaysOfWeek
 	if len(prefs.DaysOfWeek) > 0 {
 		weekday := int(slotTime.Weekday())
@@ -266,12 +367,59 @@ func ShouldFetchAvailability(history []ChatMessage, lead interface{}) bool {
 
 // ShouldFetchAvailabilityWithConfig checks whether all required qualifications are met
 // to trigger an availability fetch. When cfg is non-nil and the service has multiple
-// providers, provider preference is also required.
+// providers, provider preference is also required, and services with an extra
+// qualification (e.g. the area for laser hair removal) need its answer.
 func ShouldFetchAvailabilityWithConfig(history []ChatMessage, lead interface{}, cfg *clinic.Config) bool {
-	prefs, ok := extractPreferences(history, serviceAliasesFromConfig(cfg))
+	_, gaps := qualificationGaps(history, cfg)
+	// Email is collected on the Moxie booking page, not via SMS
+	return !gaps.Any()
+}
+
+// qualificationsMet is ShouldFetchAvailabilityWithConfig with the outcome
+// logged at debug level.
+func (s *LLMService) qualificationsMet(ctx context.Context, history []ChatMessage, cfg *clinic.Config) bool {
+	prefs, gaps := qualificationGaps(history, cfg)
+	s.log(ctx).Debug("availability qualification check",
+		"has_name", prefs.Name != "",
+		"service", prefs.ServiceInterest,
+		"patient_type", prefs.PatientType,
+		"preferred_days", prefs.PreferredDays,
+		"preferred_times", prefs.PreferredTimes,
+		"provider_preference", prefs.ProviderPreference,
+		"gaps", gaps,
+		"ready", !gaps.Any(),
+	)
+	return !gaps.Any()
+}
+
+// qualificationGaps reports every qualification ShouldFetchAvailabilityWithConfig
+// requires that the conversation is still missing, along with the merged
+// preferences it was judged against.
+func qualificationGaps(history []ChatMessage, cfg *clinic.Config) (leads.SchedulingPreferences, QualificationGaps) {
+	prefs, gaps := standardQualificationGaps(history, cfg)
+	if prefs.ServiceInterest == "" {
+		return prefs, gaps
+	}
+	if answer, question := resolveExtraQualification(history, cfg, prefs.ServiceInterest); answer == "" && question != "" {
+		gaps.ExtraQualification = true
+	}
+	return prefs, gaps
+}
+
+// standardQualificationsMet checks name, service, patient type, schedule, and
+// (when cfg requires it) provider preference, returning the merged preferences.
+func standardQualificationsMet(history []ChatMessage, cfg *clinic.Config) (leads.SchedulingPreferences, bool) {
+	prefs, gaps := standardQualificationGaps(history, cfg)
+	return prefs, !gaps.Any()
+}
+
+// standardQualificationGaps extracts the merged preferences and reports which
+// of name, service, patient type, schedule, and provider preference are missing.
+func standardQualificationGaps(history []ChatMessage, cfg *clinic.Config) (leads.SchedulingPreferences, QualificationGaps) {
+	prefs, ok := extractClinicPreferences(history, cfg)
 	if !ok {
 		log.Printf("[DEBUG] ShouldFetchAvailability: extractPreferences returned not ok")
-		return false
+		return prefs, QualificationGaps{Name: true, Service: true, PatientType: true, Schedule: true}
 	}
 
 	// Merge with saved lead preferences from system context messages.
@@ -279,29 +427,6 @@ func ShouldFetchAvailabilityWithConfig(history []ChatMessage, lead interface{},
 	// but the lead's saved preferences are injected as system context.
 	mergeLeadContextIntoPrefs(&prefs, history)
 
-	log.Printf("[DEBUG] ShouldFetchAvailability: name=%q service=%q patientType=%q days=%q times=%q providerPref=%q",
-		prefs.Name, prefs.ServiceInterest, prefs.PatientType, prefs.PreferredDays, prefs.PreferredTimes, prefs.ProviderPreference)
-
-	// Must have name
-	if prefs.Name == "" {
-		return false
-	}
-
-	// Must have service
-	if prefs.ServiceInterest == "" {
-		return false
-	}
-
-	// Must have patient type
-	if prefs.PatientType == "" {
-		return false
-	}
-
-	// Must have some scheduling preferences (days or times)
-	if prefs.PreferredDays == "" && prefs.PreferredTimes == "" {
-		return false
-	}
-
 	// If provider preference is empty, try matching against known providers from config.
 	// This handles cases like "I want lip filler with Gale" where the patient volunteers
 	// a provider name before the assistant ever lists providers.
@@ -312,20 +437,26 @@ func ShouldFetchAvailabilityWithConfig(history []ChatMessage, lead interface{},
 		}
 	}
 
+	gaps := QualificationGaps{
+		Name:        prefs.Name == "",
+		Service:     prefs.ServiceInterest == "",
+		PatientType: prefs.PatientType == "",
+		// Days or times are enough to search
+		Schedule: prefs.PreferredDays == "" && prefs.PreferredTimes == "",
+	}
+
 	// If the service has multiple providers, must have provider preference.
 	// BUT: skip this check if the service has variants (in-person/virtual) —
 	// the variant question will be asked first during availability fetch,
 	// and the resolved variant may only have 1 provider.
-	if cfg != nil && prefs.ProviderPreference == "" {
+	if cfg != nil && prefs.ServiceInterest != "" && prefs.ProviderPreference == "" {
 		hasVariants := len(cfg.GetServiceVariants(prefs.ServiceInterest)) > 0
 		if !hasVariants && cfg.ServiceNeedsProviderPreference(prefs.ServiceInterest) {
 			log.Printf("[DEBUG] ShouldFetchAvailability: service %q needs provider preference (multiple providers)", prefs.ServiceInterest)
-			return false
+			gaps.ProviderPreference = true
 		}
 	}
-
-	// Email is collected on the Moxie booking page, not via SMS
-	return true
+	return prefs, gaps
 }
 
 // matchProviderFromConfig checks if any user message contains a known provider's
@@ -355,7 +486,7 @@ func matchProviderFromConfig(history []ChatMessage, cfg *clinic.Config) string {
 	// Collect all user message text
 	var userText strings.Builder
 	for _, msg := range history {
-		if msg.Role == ChatRoleUser {
+		if msg.FromPatient() {
 			userText.WriteString(strings.ToLower(msg.Content))
 			userText.WriteString(" ")
 		}
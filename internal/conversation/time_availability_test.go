@@ -0,0 +1,60 @@
+package conversation
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/clinic"
+	moxieclient "github.com/wolfman30/medspa-ai-platform/internal/emr/moxie"
+)
+
+func moxieWeek() *moxieclient.AvailabilityResult {
+	r := &moxieclient.AvailabilityResult{}
+	for _, day := range []string{"2026-03-08", "2026-03-09", "2026-03-12", "2026-03-15", "2026-03-16"} {
+		r.Dates = append(r.Dates, moxieclient.DateSlots{
+			Date:  day,
+			Slots: []moxieclient.TimeSlot{{Start: day + "T10:00:00-04:00", End: day + "T10:30:00-04:00"}},
+		})
+	}
+	return r
+}
+
+func slotDays(r *moxieclient.AvailabilityResult) []string {
+	var days []string
+	for _, d := range r.Dates {
+		if len(d.Slots) > 0 {
+			days = append(days, d.Date)
+		}
+	}
+	return days
+}
+
+func TestWithoutBlackedOutSlots(t *testing.T) {
+	cfg := &clinic.Config{
+		Timezone: "America/New_York",
+		BlackoutDates: []clinic.BlackoutDate{
+			{ID: "week", StartDate: "2026-03-09", EndDate: "2026-03-15"},
+		},
+	}
+	got := slotDays(withoutBlackedOutSlots(cfg, moxieWeek(), "33150"))
+	if want := []string{"2026-03-08", "2026-03-16"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("clinic-wide blackout: days = %v, want %v", got, want)
+	}
+
+	cfg.BlackoutDates = []clinic.BlackoutDate{
+		{ID: "vacation", ProviderID: "33150", StartDate: "2026-03-09", EndDate: "2026-03-15"},
+	}
+	if got := slotDays(withoutBlackedOutSlots(cfg, moxieWeek(), "33150")); len(got) != 2 {
+		t.Errorf("provider on vacation: days = %v, want only days outside the blackout", got)
+	}
+	if got := slotDays(withoutBlackedOutSlots(cfg, moxieWeek(), "33151")); len(got) != 5 {
+		t.Errorf("other provider: days = %v, want all 5", got)
+	}
+
+	cfg.BlackoutDates = []clinic.BlackoutDate{
+		{ID: "expired", StartDate: "2025-12-20", EndDate: "2026-01-02"},
+	}
+	if got := slotDays(withoutBlackedOutSlots(cfg, moxieWeek(), "")); len(got) != 5 {
+		t.Errorf("expired blackout: days = %v, want all 5", got)
+	}
+}
@@ -5,6 +5,8 @@ import (
 	"sort"
 	"strings"
 	"time"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/messaging/templates"
 )
 
 // humanizeDays converts a day count to a human-readable duration string.
@@ -76,24 +78,29 @@ func formatSlotForDisplay(t time.Time) string {
 
 // FormatTimeSlotsForSMS formats slots as a numbered list for SMS
 func FormatTimeSlotsForSMS(slots []PresentedSlot, service string, exactMatch bool) string {
-	if len(slots) == 0 {
-		return fmt.Sprintf("Hmm, I'm not finding any open times for %s in the next week 😕\n\nWant me to check different dates or times?", service)
-	}
-
-	var sb strings.Builder
+	return renderTimeSlots(slots, service, exactMatch).Body
+}
 
-	if exactMatch {
-		sb.WriteString(fmt.Sprintf("Here's what's open for %s 👇\n\n", service))
-	} else {
-		sb.WriteString(fmt.Sprintf("Closest I could find for %s 👇\n\n", service))
+// renderTimeSlots is FormatTimeSlotsForSMS along with the template it was
+// rendered from.
+func renderTimeSlots(slots []PresentedSlot, service string, exactMatch bool) templates.Rendered {
+	params := templates.Params{"service": service, "slots": slotLines(slots)}
+	switch {
+	case len(slots) == 0:
+		return templates.Render(templates.SlotsNone, params, nil)
+	case exactMatch:
+		return templates.Render(templates.SlotsList, params, nil)
+	default:
+		return templates.Render(templates.SlotsListClosest, params, nil)
 	}
+}
 
+// slotLines lists slots one per line, numbered for the patient's reply.
+func slotLines(slots []PresentedSlot) string {
+	var sb strings.Builder
 	for _, slot := range slots {
 		sb.WriteString(fmt.Sprintf("  %d → %s\n", slot.Index, slot.TimeStr))
 	}
-
-	sb.WriteString("\nJust reply with the number that works best!")
-
 	return sb.String()
 }
 
@@ -101,39 +108,43 @@ func FormatTimeSlotsForSMS(slots []PresentedSlot, service string, exactMatch boo
 func FormatSlotNoLongerAvailableMessage(selectedTime time.Time, remainingSlots []PresentedSlot) string {
 	timeStr := selectedTime.Format("3:04 PM")
 	if len(remainingSlots) == 0 {
-		return fmt.Sprintf("I'm sorry, but the %s slot was just booked. Would you like me to check for other available times?", timeStr)
+		return templates.Render(templates.SlotsTaken, templates.Params{"time": timeStr}, nil).Body
 	}
 
 	var sb strings.Builder
-	sb.WriteString(fmt.Sprintf("I'm sorry, but the %s slot was just booked. Here are the remaining times:\n\n", timeStr))
-
 	for i, slot := range remainingSlots {
 		sb.WriteString(fmt.Sprintf("%d. %s\n", i+1, slot.TimeStr))
 	}
-
-	sb.WriteString("\nReply with the number of your preferred time.")
-
-	return sb.String()
+	return templates.Render(templates.SlotsTakenRemaining, templates.Params{"time": timeStr, "slots": sb.String()}, nil).Body
 }
 
 // FormatTimeSlotsWithCustomHeader formats slots with a custom header message
 // (e.g., when no slots match the patient's time preference).
 func FormatTimeSlotsWithCustomHeader(slots []PresentedSlot, header string) string {
+	return renderTimeSlotsWithHeader(slots, header).Body
+}
+
+// renderTimeSlotsWithHeader is FormatTimeSlotsWithCustomHeader along with the
+// template it was rendered from. With no slots the header is sent alone and
+// no template applies.
+func renderTimeSlotsWithHeader(slots []PresentedSlot, header string) templates.Rendered {
 	if len(slots) == 0 {
-		return header
+		return templates.Rendered{Body: header}
 	}
-	var sb strings.Builder
-	sb.WriteString(header + "\n\n")
-	for _, slot := range slots {
-		sb.WriteString(fmt.Sprintf("  %d → %s\n", slot.Index, slot.TimeStr))
-	}
-	sb.WriteString("\nJust reply with the number that works best!")
-	return sb.String()
+	return templates.Render(templates.SlotsListCustom, templates.Params{"header": header, "slots": slotLines(slots)}, nil)
 }
 
-// FormatTimeSelectionConfirmation formats the confirmation message after time selection
+// FormatTimeSelectionConfirmation formats the confirmation message after time selection.
+// A depositAmount of zero means the service is exempt from a deposit, so the
+// message says the clinic will confirm instead of asking for payment.
 func FormatTimeSelectionConfirmation(selectedTime time.Time, service string, depositAmount int) string {
 	timeStr := selectedTime.Format("Monday, January 2 at 3:04 PM")
+	if depositAmount <= 0 {
+		return fmt.Sprintf(
+			"Perfect! I've reserved %s for your %s appointment.\n\nNo deposit is needed — the clinic will confirm your booking shortly.",
+			timeStr, service,
+		)
+	}
 	depositDollars := float64(depositAmount) / 100.0
 
 	return fmt.Sprintf(
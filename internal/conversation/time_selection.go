@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/wolfman30/medspa-ai-platform/internal/leads"
+	"github.com/wolfman30/medspa-ai-platform/internal/messaging/templates"
 )
 
 // PresentedSlot represents a time slot that was presented to the user
@@ -18,6 +19,7 @@ type PresentedSlot struct {
 	TimeStr     string    // Display string like "Mon Feb 10 at 10:00 AM"
 	Service     string    // Service name
 	Available   bool      // Whether it was available when presented
+	ProviderIDs []string  // Providers free at this time, when the source attributes slots
 }
 
 // TimeSelectionState tracks the state of time selection for a conversation
@@ -27,6 +29,9 @@ type TimeSelectionState struct {
 	BookingURL     string          // Clinic booking URL
 	PresentedAt    time.Time       // When options were presented
 	SlotSelected   bool            // True after patient picks a slot (prevents re-scraping)
+	DepositApplied bool            // True when a paid deposit carries over to the next pick
+	ManualOffer    bool            // True when clinic staff offered the slot outside the platform's availability
+	ManualProvider string          // Provider staff assigned to a manual offer
 }
 
 // maxSlotsToPresent is the maximum number of slots to show at once
@@ -38,9 +43,11 @@ const maxCalendarDays = 90
 // AvailabilityResult wraps the output of an availability fetch.
 type AvailabilityResult struct {
 	Slots        []PresentedSlot
-	ExactMatch   bool   // true if slots match user preferences
-	SearchedDays int    // how many days were searched
-	Message      string // message for when no slots found at all
+	ExactMatch   bool          // true if slots match user preferences
+	SearchedDays int           // how many days were searched
+	Message      string        // message for when no slots found at all
+	Template     templates.Ref // template Message was rendered from, if any
+	Source       string        // availability source that served the slots
 }
 
 // timeSelectionPattern matches common time selection formats
@@ -81,10 +88,15 @@ func isMoreTimesRequest(message string) bool {
 // the time filter to find times not already shown.
 func buildRefinedTimePreferences(message string, originalPrefs leads.SchedulingPreferences, previousSlots []PresentedSlot) TimePreferences {
 	msg := strings.ToLower(message)
-	base := ExtractTimePreferences(originalPrefs.PreferredDays + " " + originalPrefs.PreferredTimes)
+	// Presented slots are clinic-local, so resolve dates in their zone.
+	now := time.Now()
+	if len(previousSlots) > 0 {
+		now = now.In(previousSlots[0].DateTime.Location())
+	}
+	base := extractTimePreferencesAt(originalPrefs.PreferredDays+" "+originalPrefs.PreferredTimes, now)
 
 	// Check if the patient mentioned specific dates — extract month+day references
-	specificDates := extractSpecificDates(msg)
+	specificDates := extractSpecificDates(msg, now)
 	if len(specificDates) > 0 {
 		// Convert specific dates to days of week
 		var days []int
@@ -127,43 +139,21 @@ func buildRefinedTimePreferences(message string, originalPrefs leads.SchedulingP
 	return base
 }
 
-// extractSpecificDates parses month+day references from a message like "Mar 2 and 4th"
-func extractSpecificDates(msg string) []time.Time {
-	months := map[string]time.Month{
-		"jan": time.January, "january": time.January,
-		"feb": time.February, "february": time.February,
-		"mar": time.March, "march": time.March,
-		"apr": time.April, "april": time.April,
-		"may": time.May,
-		"jun": time.June, "june": time.June,
-		"jul": time.July, "july": time.July,
-		"aug": time.August, "august": time.August,
-		"sep": time.September, "september": time.September,
-		"oct": time.October, "october": time.October,
-		"nov": time.November, "november": time.November,
-		"dec": time.December, "december": time.December,
-	}
-
-	now := time.Now()
+// extractSpecificDates parses month+day references from a message like "Mar 2 and 4th",
+// resolving them relative to now (in the clinic's timezone).
+func extractSpecificDates(msg string, now time.Time) []time.Time {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
 	var dates []time.Time
 
 	// Pattern: "Mar 2 and 4th" or "March 2nd and March 4th"
 	// First find explicit month+day pairs
-	re := regexp.MustCompile(`(?i)(jan(?:uary)?|feb(?:ruary)?|mar(?:ch)?|apr(?:il)?|may|jun(?:e)?|jul(?:y)?|aug(?:ust)?|sep(?:tember)?|oct(?:ober)?|nov(?:ember)?|dec(?:ember)?)\s+(\d{1,2})`)
-	matches := re.FindAllStringSubmatch(msg, -1)
+	matches := monthDayRE.FindAllStringSubmatch(msg, -1)
 
-	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
 	var lastMonth time.Month
 	for _, m := range matches {
-		monthStr := strings.ToLower(m[1])
-		if mon, ok := months[monthStr]; ok {
+		if mon, ok := monthsByName[strings.ToLower(m[1])]; ok {
 			day, _ := strconv.Atoi(m[2])
-			if day >= 1 && day <= 31 {
-				year := now.Year()
-				d := time.Date(year, mon, day, 0, 0, 0, 0, now.Location())
-				if d.Before(today) {
-					d = d.AddDate(1, 0, 0)
-				}
+			if d, ok := nextOccurrence(mon, day, today); ok {
 				dates = append(dates, d)
 				lastMonth = mon
 			}
@@ -177,24 +167,21 @@ func extractSpecificDates(msg string) []time.Time {
 		bareMatches := bareRe.FindAllStringSubmatch(msg, -1)
 		for _, bm := range bareMatches {
 			day, _ := strconv.Atoi(bm[1])
-			if day >= 1 && day <= 31 {
-				// Check this date isn't already captured
-				year := now.Year()
-				d := time.Date(year, lastMonth, day, 0, 0, 0, 0, now.Location())
-				if d.Before(today) {
-					d = d.AddDate(1, 0, 0)
-				}
-				alreadyHave := false
-				for _, existing := range dates {
-					if existing.Equal(d) {
-						alreadyHave = true
-						break
-					}
-				}
-				if !alreadyHave {
-					dates = append(dates, d)
+			d, ok := nextOccurrence(lastMonth, day, today)
+			if !ok {
+				continue
+			}
+			// Check this date isn't already captured
+			alreadyHave := false
+			for _, existing := range dates {
+				if existing.Equal(d) {
+					alreadyHave = true
+					break
 				}
 			}
+			if !alreadyHave {
+				dates = append(dates, d)
+			}
 		}
 	}
 
@@ -335,6 +322,13 @@ func DetectTimeSelection(message string, presentedSlots []PresentedSlot, prefs T
 		return nil
 	}
 
+	// Priority 3.25: Qualitative selectors — "the morning one", "the earlier time",
+	// "the later Friday one", "whichever is soonest". Runs before date matching so
+	// "the later Friday one" picks the later Friday slot, not the first.
+	if slot, ok := detectQualitativeSelection(message, presentedSlots); ok {
+		return slot
+	}
+
 	// Priority 3.5: Date-based selection — "Feb 28", "Monday", "the 28th", "February 28"
 	// Match against presented slot dates. If exactly one slot matches the date, return it.
 	// If multiple slots on that date, pick the first (patient chose the day, we pick the time).
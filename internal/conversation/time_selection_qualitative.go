@@ -0,0 +1,105 @@
+package conversation
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// qualitativeSelectorPatterns match replies that pick a presented slot by
+// description rather than number: "the morning one", "the earlier time",
+// "the later Friday one", "whichever is soonest". Patterns are anchored so
+// general remarks like "maybe morning is better generally" don't count.
+var qualitativeSelectorPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`^(?:(?:ok(?:ay)?|yes|yeah|sure),?\s+)?(?:i'?ll take |let'?s do |let'?s go with |i want |give me |book )?(?:the|that)\s+[a-z ]{0,40}?\b(?:one|time|slot|option|appointment)(?:\s+(?:please|works|works for me|is fine|is good|sounds good|is perfect))?[.!]*$`),
+	regexp.MustCompile(`^(?:the\s+)?(?:earliest|soonest|latest)(?:\s+(?:one|please))?[.!]*$`),
+	regexp.MustCompile(`\bwhichever(?:\s+one)?\s+is\s+(?:the\s+)?(?:soonest|earliest|sooner|earlier|latest|later)\b`),
+}
+
+var (
+	// dayPartWords map to [start, end) hour ranges in the slot's local time.
+	dayPartWords = map[string][2]int{
+		"morning":   {0, 12},
+		"afternoon": {12, 17},
+		"evening":   {17, 24},
+		"night":     {17, 24},
+	}
+	earliestWords = []string{"earliest", "earlier", "soonest", "sooner"}
+	latestWords   = []string{"latest", "later", "last"}
+	weekdayRE     = regexp.MustCompile(`\b(?:monday|tuesday|wednesday|thursday|friday|saturday|sunday|mon|tue|tues|wed|thu|thur|thurs|fri|sat|sun)\b`)
+)
+
+// isQualitativeSelection reports whether message picks a slot by description
+// (time of day, earliest/latest) instead of by number or exact time.
+func isQualitativeSelection(message string) bool {
+	message = strings.TrimSpace(strings.ToLower(message))
+	for _, re := range qualitativeSelectorPatterns {
+		if re.MatchString(message) {
+			return true
+		}
+	}
+	return false
+}
+
+// detectQualitativeSelection resolves descriptive replies against the
+// presented slots. matched is false when the message isn't a qualitative
+// selector; otherwise slot is the chosen slot, or nil when nothing presented
+// fits the description. When several slots fit, the chronologically first
+// wins unless the patient asked for the later/latest one.
+func detectQualitativeSelection(message string, slots []PresentedSlot) (slot *PresentedSlot, matched bool) {
+	if !isQualitativeSelection(message) {
+		return nil, false
+	}
+	words := strings.Fields(strings.Trim(message, ".!"))
+	hasWord := func(candidates ...string) bool {
+		for _, w := range words {
+			for _, c := range candidates {
+				if w == c {
+					return true
+				}
+			}
+		}
+		return false
+	}
+
+	var dayPart *[2]int
+	for word, hours := range dayPartWords {
+		if hasWord(word) {
+			h := hours
+			dayPart = &h
+			break
+		}
+	}
+	wantLatest := hasWord(latestWords...)
+	if dayPart == nil && !wantLatest && !hasWord(earliestWords...) {
+		return nil, false
+	}
+
+	candidates := make([]*PresentedSlot, 0, len(slots))
+	for i := range slots {
+		candidates = append(candidates, &slots[i])
+	}
+	if weekdayRE.MatchString(message) {
+		candidates = matchSlotsByDate(message, slots)
+	}
+	if dayPart != nil {
+		var inRange []*PresentedSlot
+		for _, s := range candidates {
+			if h := s.DateTime.Hour(); h >= dayPart[0] && h < dayPart[1] {
+				inRange = append(inRange, s)
+			}
+		}
+		candidates = inRange
+	}
+	if len(candidates) == 0 {
+		return nil, true
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].DateTime.Before(candidates[j].DateTime)
+	})
+	if wantLatest {
+		return candidates[len(candidates)-1], true
+	}
+	return candidates[0], true
+}
@@ -53,6 +53,24 @@ func TestDetectTimeSelection(t *testing.T) {
 		{name: "10am", message: "10am", expectedIndex: 1},
 		{name: "11:30 am", message: "11:30 am", expectedIndex: 2},
 
+		// Qualitative selectors (multiple matches pick the earliest)
+		{name: "the morning one", message: "the morning one", expectedIndex: 1},
+		{name: "the afternoon one", message: "The afternoon one please", expectedIndex: 3},
+		{name: "the earlier time", message: "the earlier time", expectedIndex: 1},
+		{name: "the later one", message: "the later one", expectedIndex: 4},
+		{name: "the latest", message: "the latest", expectedIndex: 4},
+		{name: "whichever is soonest", message: "whichever is soonest", expectedIndex: 1},
+		{name: "whichever one is later", message: "honestly whichever one is later", expectedIndex: 4},
+		{name: "later thursday one", message: "the later Thursday one", expectedIndex: 4},
+		{name: "thursday afternoon one", message: "I'll take the Thursday afternoon one", expectedIndex: 3},
+		{name: "monday morning one works", message: "the Monday morning one works", expectedIndex: 1},
+		{name: "later monday morning slot", message: "the later monday morning slot", expectedIndex: 2},
+		{name: "evening one with no evening slots", message: "the evening one", expectedIndex: 0},
+		{name: "friday morning one with no friday slots", message: "the Friday morning one", expectedIndex: 0},
+		{name: "thursday morning one with no match", message: "the thursday morning one", expectedIndex: 0},
+		{name: "general morning remark", message: "maybe morning is better generally", expectedIndex: 0},
+		{name: "mornings usually better", message: "mornings are usually better for me", expectedIndex: 0},
+
 		// No selection
 		{name: "random text", message: "what are your hours?", expectedIndex: 0},
 		{name: "empty", message: "", expectedIndex: 0},
@@ -241,6 +259,17 @@ func TestFormatTimeSelectionConfirmation(t *testing.T) {
 	assert.Contains(t, result, "refundable deposit")
 }
 
+func TestFormatTimeSelectionConfirmation_NoDeposit(t *testing.T) {
+	selectedTime := time.Date(2026, 2, 9, 10, 0, 0, 0, time.Local)
+	result := FormatTimeSelectionConfirmation(selectedTime, "Consultation", 0)
+
+	assert.Contains(t, result, "Monday, February 9 at 10:00 AM")
+	assert.Contains(t, result, "Consultation")
+	assert.Contains(t, result, "No deposit is needed")
+	assert.NotContains(t, result, "refundable deposit")
+	assert.NotContains(t, result, "$")
+}
+
 func TestFormatSlotNoLongerAvailableMessage(t *testing.T) {
 	selectedTime := time.Date(2026, 2, 10, 10, 0, 0, 0, time.Local)
 
@@ -474,7 +503,7 @@ func TestExtractSpecificDates(t *testing.T) {
 	}
 	monthAbbrev := strings.ToLower(time.Date(2000, targetMonth, 1, 0, 0, 0, 0, time.UTC).Format("Jan"))
 
-	dates := extractSpecificDates("any later times on " + monthAbbrev + " 1 and 7th?")
+	dates := extractSpecificDates("any later times on "+monthAbbrev+" 1 and 7th?", now)
 	require.Len(t, dates, 2, "should extract 2 dates")
 	assert.Equal(t, targetMonth, dates[0].Month())
 	assert.Equal(t, 1, dates[0].Day())
@@ -3,6 +3,9 @@ package conversation
 import (
 	"fmt"
 	"time"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/clinic"
+	"github.com/wolfman30/medspa-ai-platform/internal/messaging/templates"
 )
 
 // ParseSlotTime parses a time string from a booking platform API (e.g., Moxie)
@@ -47,18 +50,57 @@ func ClinicLocation(timezone string) *time.Location {
 	return loc
 }
 
+// clinicNow returns the current time in the clinic's timezone. Without a
+// configured timezone it falls back to server local time.
+func clinicNow(cfg *clinic.Config) time.Time {
+	now := time.Now()
+	if cfg == nil || cfg.Timezone == "" {
+		return now
+	}
+	return now.In(ClinicLocation(cfg.Timezone))
+}
+
+// clinicTimePreferences extracts time preferences with "today" resolved in
+// the clinic's timezone, so date ranges line up with clinic-local slots.
+func clinicTimePreferences(text string, cfg *clinic.Config) TimePreferences {
+	return extractTimePreferencesAt(text, clinicNow(cfg))
+}
+
 // FormatAppointmentConfirmation builds a standardized booking confirmation message.
 func FormatAppointmentConfirmation(service string, appointmentTime time.Time, clinicName string) string {
-	dateStr := appointmentTime.Format("Monday, January 2")
-	tzAbbrev := appointmentTime.Format("MST")
-	timeStr := appointmentTime.Format("3:04 PM") + " " + tzAbbrev
+	return FormatAppointmentConfirmationWindow(service, appointmentTime, time.Time{}, clinicName)
+}
 
-	return fmt.Sprintf(
-		"You're all booked! 🎉\n\n"+
-			"💉 %s\n"+
-			"📅 %s at %s\n"+
-			"📍 %s\n\n"+
-			"⚠️ 24-hour cancellation policy — cancellations made less than 24 hours before your appointment are non-refundable.\n\n"+
-			"See you there! ✨",
-		service, dateStr, timeStr, clinicName)
+// FormatAppointmentConfirmationWindow is FormatAppointmentConfirmation with the
+// appointment's end time shown when it is known (non-zero).
+func FormatAppointmentConfirmationWindow(service string, start, end time.Time, clinicName string) string {
+	return renderAppointmentConfirmation(nil, service, start, end, clinicName).Body
+}
+
+// renderAppointmentConfirmation renders the paid-booking confirmation with
+// the clinic's copy.
+func renderAppointmentConfirmation(cfg *clinic.Config, service string, start, end time.Time, clinicName string) templates.Rendered {
+	when := start.Format("Monday, January 2") + " at " + start.Format("3:04 PM MST")
+	if end.After(start) {
+		when = FormatAppointmentWindow(start, end) + " " + start.Format("MST")
+	}
+	return cfg.RenderTemplate(templates.BookingConfirmedPaid, templates.Params{
+		"service":     service,
+		"when":        when,
+		"clinic_name": clinicName,
+	})
+}
+
+// FormatAppointmentWindow renders an appointment's start and end, e.g.
+// "Thu Feb 26, 7:45–9:00 PM". AM/PM is repeated only when the window crosses
+// noon. An end at or before the start renders the start alone.
+func FormatAppointmentWindow(start, end time.Time) string {
+	end = end.In(start.Location())
+	if !end.After(start) {
+		return start.Format("Mon Jan 2, 3:04 PM")
+	}
+	if start.Format("PM") == end.Format("PM") && dateKey(start) == dateKey(end) {
+		return start.Format("Mon Jan 2, 3:04") + "–" + end.Format("3:04 PM")
+	}
+	return start.Format("Mon Jan 2, 3:04 PM") + "–" + end.Format("3:04 PM")
 }
@@ -1,8 +1,11 @@
 package conversation
 
 import (
+	"strings"
 	"testing"
 	"time"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/clinic"
 )
 
 func TestParseSlotTime(t *testing.T) {
@@ -119,3 +122,89 @@ func containsStr(s, substr string) bool {
 	}
 	return false
 }
+
+// pinServerUTC runs the test with the server's local zone set to UTC, as in
+// production containers.
+func pinServerUTC(t *testing.T) {
+	t.Helper()
+	orig := time.Local
+	time.Local = time.UTC
+	t.Cleanup(func() { time.Local = orig })
+}
+
+func TestClinicSlotsFilteredAndDisplayedInClinicZone(t *testing.T) {
+	pinServerUTC(t)
+	ny := ClinicLocation("America/New_York")
+
+	// 02:00 UTC on Mar 10 is still the evening of Mar 9 in New York.
+	now := time.Date(2026, 3, 10, 2, 0, 0, 0, time.UTC).In(ny)
+	prefs := extractTimePreferencesAt("week of march 10 after 3pm", now)
+	if prefs.DateFrom == nil || prefs.DateFrom.Location() != ny {
+		t.Fatalf("expected DateFrom in clinic zone, got %v", prefs.DateFrom)
+	}
+
+	// 20:30 UTC is 4:30 PM EDT: after 3pm in the clinic's day.
+	slot := time.Date(2026, 3, 10, 20, 30, 0, 0, time.UTC).In(ny)
+	if !matchesTimePreferences(slot, prefs) {
+		t.Fatalf("expected %v to match %+v", slot, prefs)
+	}
+	if got := formatSlotForDisplay(slot); got != "Tue Mar 10 at 4:30 PM" {
+		t.Fatalf("formatSlotForDisplay = %q", got)
+	}
+
+	// 18:30 UTC is 2:30 PM EDT, which must not pass "after 3pm" even though
+	// the UTC hour would.
+	early := time.Date(2026, 3, 10, 18, 30, 0, 0, time.UTC).In(ny)
+	if matchesTimePreferences(early, prefs) {
+		t.Fatalf("expected %v to be filtered out", early)
+	}
+}
+
+func TestClinicNow(t *testing.T) {
+	pinServerUTC(t)
+	if got := clinicNow(nil).Location(); got != time.Local {
+		t.Fatalf("nil config location = %v, want server local", got)
+	}
+	got := clinicNow(&clinic.Config{Timezone: "America/New_York"}).Location().String()
+	if got != "America/New_York" {
+		t.Fatalf("clinic location = %q", got)
+	}
+}
+
+func TestTimezoneForPhone(t *testing.T) {
+	tests := map[string]string{
+		"+13105550100":   "America/Los_Angeles",
+		"(212) 555-0100": "America/New_York",
+		"+16025550100":   "America/Phoenix",
+		"+18005550100":   "",
+		"555-0100":       "",
+	}
+	for phone, want := range tests {
+		if got := timezoneForPhone(phone); got != want {
+			t.Errorf("timezoneForPhone(%q) = %q, want %q", phone, got, want)
+		}
+	}
+}
+
+func TestWithTimezoneNote(t *testing.T) {
+	pinServerUTC(t)
+	ny := ClinicLocation("America/New_York")
+	at := time.Date(2026, 3, 10, 20, 30, 0, 0, time.UTC).In(ny)
+	slots := []PresentedSlot{{Index: 1, DateTime: at, TimeStr: formatSlotForDisplay(at)}}
+	msg := FormatTimeSlotsForSMS(slots, "Botox", true)
+
+	got := withTimezoneNote(msg, slots, "+13105550100")
+	if !strings.Contains(got, "All times are EDT") {
+		t.Fatalf("expected timezone note for west-coast lead, got %q", got)
+	}
+	if !strings.HasSuffix(got, "Just reply with the number that works best!") {
+		t.Fatalf("expected reply prompt to stay last, got %q", got)
+	}
+
+	if got := withTimezoneNote(msg, slots, "+12125550100"); got != msg {
+		t.Fatalf("expected no note for same-zone lead, got %q", got)
+	}
+	if got := withTimezoneNote(msg, slots, ""); got != msg {
+		t.Fatalf("expected no note for unknown phone, got %q", got)
+	}
+}
@@ -0,0 +1,297 @@
+package conversation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Stages of a conversation turn, from the inbound text to the reply.
+const (
+	// TurnStageQueueWait runs from the webhook receiving the text to a
+	// worker picking up its job.
+	TurnStageQueueWait    = "queue_wait"
+	TurnStageContextBuild = "context_build"
+	TurnStageLLM          = "llm"
+	TurnStageAvailability = "availability"
+	TurnStageSend         = "send"
+	// TurnStageOther is the turn's time not spent in another stage.
+	TurnStageOther = "other"
+)
+
+// DefaultReplyLatencyBudget is the target time from an inbound text to a
+// substantive reply.
+const DefaultReplyLatencyBudget = 20 * time.Second
+
+var turnStageDuration = prometheus.NewHistogramVec(
+	prometheus.HistogramOpts{
+		Namespace: "medspa",
+		Subsystem: "conversation",
+		Name:      "turn_stage_duration_seconds",
+		Help:      "Time a conversation turn spent in each stage before the reply was sent",
+		Buckets:   []float64{0.01, 0.05, 0.1, 0.25, 0.5, 1, 2, 4, 8, 12, 20, 30},
+	},
+	[]string{"stage"},
+)
+
+var turnLatency = prometheus.NewHistogramVec(
+	prometheus.HistogramOpts{
+		Namespace: "medspa",
+		Subsystem: "conversation",
+		Name:      "turn_latency_seconds",
+		Help:      "Time from an inbound text to the reply being sent",
+		Buckets:   []float64{1, 2, 4, 6, 8, 10, 12, 15, 20, 25, 30, 45, 60},
+	},
+	[]string{"org_id", "availability"},
+)
+
+var turnOverBudgetTotal = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Namespace: "medspa",
+		Subsystem: "conversation",
+		Name:      "turn_over_budget_total",
+		Help:      "Replies sent later than the reply latency budget after the inbound text",
+	},
+	[]string{"org_id", "availability"},
+)
+
+var turnLatencyBudget = prometheus.NewGauge(
+	prometheus.GaugeOpts{
+		Namespace: "medspa",
+		Subsystem: "conversation",
+		Name:      "turn_latency_budget_seconds",
+		Help:      "Configured reply latency budget; replies over it are counted in turn_over_budget_total",
+	},
+)
+
+func init() {
+	prometheus.MustRegister(turnStageDuration, turnLatency, turnOverBudgetTotal, turnLatencyBudget)
+}
+
+// TurnTimings is the latency breakdown of one conversation turn.
+type TurnTimings struct {
+	ReceivedAt time.Time `json:"received_at" dynamodbav:"receivedAt"`
+	// StagesMS holds each stage's milliseconds; they add up to TotalMS.
+	StagesMS map[string]int64 `json:"stages_ms" dynamodbav:"stagesMs"`
+	TotalMS  int64            `json:"total_ms" dynamodbav:"totalMs"`
+	// AvailabilitySearch is set when the turn fetched availability.
+	AvailabilitySearch bool `json:"availability_search" dynamodbav:"availabilitySearch"`
+	OverBudget         bool `json:"over_budget" dynamodbav:"overBudget"`
+}
+
+// JobTimingRecorder stores a turn's timings on its job record.
+type JobTimingRecorder interface {
+	RecordTimings(ctx context.Context, jobID string, timings TurnTimings) error
+}
+
+// turnTimer accumulates stage durations for the turn its context carries.
+// It stops at the first reply, so later sends (e.g. a deposit link) don't
+// count toward the turn.
+type turnTimer struct {
+	mu           sync.Mutex
+	stages       map[string]time.Duration
+	availability bool
+	repliedAt    time.Time
+}
+
+type turnTimerKeyType struct{}
+
+// withTurnTimer starts timing a turn on ctx.
+func withTurnTimer(ctx context.Context) (context.Context, *turnTimer) {
+	timer := &turnTimer{stages: make(map[string]time.Duration)}
+	return context.WithValue(ctx, turnTimerKeyType{}, timer), timer
+}
+
+// addTurnStage adds d to stage on ctx's turn timer, if it carries one.
+func addTurnStage(ctx context.Context, stage string, d time.Duration) {
+	timer, _ := ctx.Value(turnTimerKeyType{}).(*turnTimer)
+	if timer == nil {
+		return
+	}
+	timer.add(stage, d)
+}
+
+// markTurnReplied records that the turn's reply has been sent.
+func markTurnReplied(ctx context.Context) {
+	timer, _ := ctx.Value(turnTimerKeyType{}).(*turnTimer)
+	if timer == nil {
+		return
+	}
+	timer.mu.Lock()
+	defer timer.mu.Unlock()
+	if timer.repliedAt.IsZero() {
+		timer.repliedAt = time.Now()
+	}
+}
+
+// sendTurnReply sends the turn's substantive reply, timing the send and
+// marking the turn replied once it goes out.
+func (w *Worker) sendTurnReply(ctx context.Context, reply OutboundReply) error {
+	start := time.Now()
+	err := w.messenger.SendReply(ctx, reply)
+	addTurnStage(ctx, TurnStageSend, time.Since(start))
+	if err == nil {
+		markTurnReplied(ctx)
+	}
+	return err
+}
+
+func (t *turnTimer) add(stage string, d time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if !t.repliedAt.IsZero() {
+		return
+	}
+	t.stages[stage] += d
+	if stage == TurnStageAvailability {
+		t.availability = true
+	}
+}
+
+// finish returns the turn's timings from receivedAt, when the inbound text
+// arrived, to the reply. pickedUp is when a worker took the job. ok is
+// false when no reply was sent.
+func (t *turnTimer) finish(receivedAt, pickedUp time.Time) (timings TurnTimings, ok bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.repliedAt.IsZero() {
+		return TurnTimings{}, false
+	}
+	if receivedAt.IsZero() || receivedAt.After(pickedUp) {
+		receivedAt = pickedUp
+	}
+	stages := make(map[string]time.Duration, len(t.stages)+2)
+	for stage, d := range t.stages {
+		stages[stage] = d
+	}
+	stages[TurnStageQueueWait] = pickedUp.Sub(receivedAt)
+
+	total := t.repliedAt.Sub(receivedAt)
+	var attributed time.Duration
+	for _, d := range stages {
+		attributed += d
+	}
+	// Stages can overlap (e.g. an availability fetch during a completion),
+	// in which case there is no remainder.
+	stages[TurnStageOther] = max(total-attributed, 0)
+
+	timings = TurnTimings{
+		ReceivedAt:         receivedAt.UTC(),
+		StagesMS:           make(map[string]int64, len(stages)),
+		TotalMS:            total.Milliseconds(),
+		AvailabilitySearch: t.availability,
+	}
+	for stage, d := range stages {
+		timings.StagesMS[stage] = d.Milliseconds()
+	}
+	return timings, true
+}
+
+// observeTurn records a turn's timings as metrics, reporting whether the
+// reply went out over budget. A budget of zero or less disables the check.
+func observeTurn(orgID string, timings TurnTimings, budget time.Duration) bool {
+	for stage, ms := range timings.StagesMS {
+		turnStageDuration.WithLabelValues(stage).Observe(float64(ms) / 1000)
+	}
+	availability := strconv.FormatBool(timings.AvailabilitySearch)
+	turnLatency.WithLabelValues(orgID, availability).Observe(float64(timings.TotalMS) / 1000)
+	over := budget > 0 && time.Duration(timings.TotalMS)*time.Millisecond > budget
+	if over {
+		turnOverBudgetTotal.WithLabelValues(orgID, availability).Inc()
+	}
+	return over
+}
+
+// recordTurnLatency records a message job's turn timings once its reply has
+// gone out: as metrics, on the job record when the job is tracked, and in
+// the conversation's turn history.
+func (w *Worker) recordTurnLatency(ctx context.Context, payload queuePayload, timer *turnTimer, pickedUp time.Time) {
+	if timer == nil {
+		return
+	}
+	timings, ok := timer.finish(payload.Message.ReceivedAt, pickedUp)
+	if !ok {
+		return
+	}
+	timings.OverBudget = observeTurn(payload.Message.OrgID, timings, w.cfg.replyLatencyBudget)
+	if timings.OverBudget {
+		w.log(ctx).Warn("reply exceeded latency budget",
+			"total_ms", timings.TotalMS,
+			"stages_ms", timings.StagesMS,
+			"budget", w.cfg.replyLatencyBudget,
+		)
+	}
+	ctx = context.WithoutCancel(ctx)
+	if recorder, ok := w.jobs.(JobTimingRecorder); ok && payload.TrackStatus {
+		if err := recorder.RecordTimings(ctx, payload.ID, timings); err != nil {
+			w.log(ctx).Warn("failed to record job timings", "error", err)
+		}
+	}
+	if w.turnTimings != nil {
+		if err := w.turnTimings.RecordTurn(ctx, TurnTimingRecord{
+			JobID:          payload.ID,
+			OrgID:          payload.Message.OrgID,
+			ConversationID: payload.Message.ConversationID,
+			Timings:        timings,
+		}); err != nil {
+			w.log(ctx).Warn("failed to record turn timings", "error", err)
+		}
+	}
+}
+
+// TurnTimingRecord is one turn's timings in a conversation's history.
+type TurnTimingRecord struct {
+	JobID          string
+	OrgID          string
+	ConversationID string
+	Timings        TurnTimings
+}
+
+// TurnTimingStore keeps the timings of recent turns per conversation for the
+// admin timeline.
+type TurnTimingStore interface {
+	RecordTurn(ctx context.Context, rec TurnTimingRecord) error
+}
+
+// PGTurnTimingStore stores turn timings in PostgreSQL.
+type PGTurnTimingStore struct {
+	db callbackTaskDB
+}
+
+// NewPGTurnTimingStore builds a Postgres-backed TurnTimingStore.
+func NewPGTurnTimingStore(db *pgxpool.Pool) *PGTurnTimingStore {
+	if db == nil {
+		panic("conversation: pgx pool cannot be nil")
+	}
+	return &PGTurnTimingStore{db: db}
+}
+
+var _ TurnTimingStore = (*PGTurnTimingStore)(nil)
+
+// RecordTurn inserts a turn's timings.
+func (s *PGTurnTimingStore) RecordTurn(ctx context.Context, rec TurnTimingRecord) error {
+	if rec.ConversationID == "" {
+		return errors.New("conversation: turn timings need a conversation ID")
+	}
+	stages, err := marshalJSON(rec.Timings.StagesMS)
+	if err != nil {
+		return fmt.Errorf("conversation: record turn timings: %w", err)
+	}
+	query := `
+		INSERT INTO conversation_turn_timings (
+			job_id, org_id, conversation_id, received_at, stages_ms, total_ms, availability_search, over_budget
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`
+	if _, err := s.db.Exec(ctx, query, rec.JobID, rec.OrgID, rec.ConversationID, rec.Timings.ReceivedAt, stages,
+		rec.Timings.TotalMS, rec.Timings.AvailabilitySearch, rec.Timings.OverBudget); err != nil {
+		return fmt.Errorf("conversation: record turn timings: %w", err)
+	}
+	return nil
+}
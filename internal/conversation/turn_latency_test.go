@@ -0,0 +1,160 @@
+package conversation
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
+)
+
+// stagedService answers after spending time in the LLM and availability
+// stages, as the real processor does.
+type stagedService struct {
+	replyService
+}
+
+func (s *stagedService) ProcessMessage(ctx context.Context, req MessageRequest) (*Response, error) {
+	for _, stage := range []string{TurnStageContextBuild, TurnStageAvailability, TurnStageLLM} {
+		start := time.Now()
+		time.Sleep(15 * time.Millisecond)
+		addTurnStage(ctx, stage, time.Since(start))
+	}
+	return s.replyService.ProcessMessage(ctx, req)
+}
+
+type timingJobStore struct {
+	stubJobUpdater
+	mu      sync.Mutex
+	timings map[string]TurnTimings
+}
+
+func (s *timingJobStore) RecordTimings(ctx context.Context, jobID string, timings TurnTimings) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.timings == nil {
+		s.timings = make(map[string]TurnTimings)
+	}
+	s.timings[jobID] = timings
+	return nil
+}
+
+type recordingTurnTimings struct {
+	mu      sync.Mutex
+	records []TurnTimingRecord
+}
+
+func (s *recordingTurnTimings) RecordTurn(ctx context.Context, rec TurnTimingRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.records = append(s.records, rec)
+	return nil
+}
+
+func TestHandleMessage_RecordsTurnTimings(t *testing.T) {
+	jobs := &timingJobStore{}
+	history := &recordingTurnTimings{}
+	worker := NewWorker(&stagedService{}, newScriptedQueue(), jobs, &recordingMessenger{}, nil, logging.Default(),
+		WithTurnTimings(history),
+		WithReplyLatencyBudget(time.Second))
+
+	received := time.Now().Add(-2 * time.Second)
+	payload := queuePayload{
+		ID:          "job-1",
+		Kind:        jobTypeMessage,
+		TrackStatus: true,
+		Message: MessageRequest{
+			ConversationID: "sms:org-1:15550001111",
+			OrgID:          "org-1",
+			LeadID:         "lead-1",
+			Message:        "any openings friday?",
+			Channel:        ChannelSMS,
+			From:           "+15550001111",
+			To:             "+15550002222",
+			ReceivedAt:     received,
+		},
+	}
+	body, _ := json.Marshal(payload)
+	worker.handleMessage(context.Background(), queueMessage{ID: "msg-1", Body: string(body), ReceiptHandle: "rh-1"})
+
+	timings, ok := jobs.timings["job-1"]
+	if !ok {
+		t.Fatal("expected timings recorded on the job")
+	}
+	for _, stage := range []string{TurnStageQueueWait, TurnStageContextBuild, TurnStageLLM, TurnStageAvailability, TurnStageSend, TurnStageOther} {
+		if _, ok := timings.StagesMS[stage]; !ok {
+			t.Errorf("missing stage %q in %v", stage, timings.StagesMS)
+		}
+	}
+	for _, stage := range []string{TurnStageContextBuild, TurnStageLLM, TurnStageAvailability} {
+		if ms := timings.StagesMS[stage]; ms < 15 {
+			t.Errorf("stage %q = %dms, want at least 15ms", stage, ms)
+		}
+	}
+	if ms := timings.StagesMS[TurnStageQueueWait]; ms < 1900 {
+		t.Errorf("queue wait = %dms, want about 2s", ms)
+	}
+
+	var sum int64
+	for _, ms := range timings.StagesMS {
+		sum += ms
+	}
+	// Each stage is truncated to whole milliseconds.
+	if diff := timings.TotalMS - sum; diff < 0 || diff > int64(len(timings.StagesMS)) {
+		t.Fatalf("total %dms, stages sum to %dms", timings.TotalMS, sum)
+	}
+	if !timings.AvailabilitySearch || !timings.OverBudget {
+		t.Fatalf("expected an availability turn over budget, got %+v", timings)
+	}
+	if !timings.ReceivedAt.Equal(received.UTC()) {
+		t.Fatalf("received_at = %v, want %v", timings.ReceivedAt, received)
+	}
+
+	if len(history.records) != 1 || history.records[0].ConversationID != payload.Message.ConversationID || history.records[0].Timings.TotalMS != timings.TotalMS {
+		t.Fatalf("expected the turn kept in the conversation history, got %+v", history.records)
+	}
+}
+
+func TestTurnTimer_StopsAtReply(t *testing.T) {
+	ctx, timer := withTurnTimer(context.Background())
+	received := time.Now().Add(-time.Second)
+	pickedUp := received.Add(300 * time.Millisecond)
+
+	if _, ok := timer.finish(received, pickedUp); ok {
+		t.Fatal("expected no timings before a reply")
+	}
+
+	addTurnStage(ctx, TurnStageLLM, 200*time.Millisecond)
+	markTurnReplied(ctx)
+	addTurnStage(ctx, TurnStageSend, 5*time.Second) // e.g. a deposit link after the reply
+
+	timings, ok := timer.finish(received, pickedUp)
+	if !ok {
+		t.Fatal("expected timings after the reply")
+	}
+	if timings.StagesMS[TurnStageSend] != 0 || timings.StagesMS[TurnStageLLM] != 200 || timings.StagesMS[TurnStageQueueWait] != 300 {
+		t.Fatalf("stages = %v", timings.StagesMS)
+	}
+	if timings.AvailabilitySearch {
+		t.Fatal("expected no availability search")
+	}
+	if got := timings.StagesMS[TurnStageOther]; got < 400 || timings.TotalMS < 900 {
+		t.Fatalf("other = %dms total = %dms, want the unattributed remainder", got, timings.TotalMS)
+	}
+}
+
+func TestTurnTimer_MissingReceivedAtStartsAtPickup(t *testing.T) {
+	ctx, timer := withTurnTimer(context.Background())
+	pickedUp := time.Now()
+	markTurnReplied(ctx)
+
+	timings, ok := timer.finish(time.Time{}, pickedUp)
+	if !ok {
+		t.Fatal("expected timings")
+	}
+	if timings.StagesMS[TurnStageQueueWait] != 0 || !timings.ReceivedAt.Equal(pickedUp.UTC()) {
+		t.Fatalf("timings = %+v", timings)
+	}
+}
@@ -204,7 +204,7 @@ func ResolveServiceVariant(cfg *clinic.Config, service string, messages []string
 func recentUserMessages(history []ChatMessage, currentMsg string, lookback int) []string {
 	msgs := []string{strings.ToLower(currentMsg)}
 	for i := len(history) - 1; i >= 0 && i >= len(history)-lookback; i-- {
-		if history[i].Role == ChatRoleUser {
+		if history[i].FromPatient() {
 			msgs = append(msgs, strings.ToLower(history[i].Content))
 		}
 	}
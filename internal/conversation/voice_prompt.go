@@ -103,8 +103,7 @@ func voiceConversationID(orgID string, phone string) string {
 	if orgID == "" {
 		return ""
 	}
-	digits := sanitizeDigits(phone)
-	digits = normalizeUSDigits(digits)
+	digits := canonicalPhoneDigits(phone)
 	if digits == "" {
 		return ""
 	}
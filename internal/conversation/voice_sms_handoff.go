@@ -6,6 +6,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/wolfman30/medspa-ai-platform/internal/messaging/templates"
 	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
 )
 
@@ -38,10 +39,9 @@ func (h *VoiceSMSHandoff) SendPaymentLinkSMS(ctx context.Context, req VoiceHando
 		return fmt.Errorf("voice handoff: patient and clinic phone required")
 	}
 
-	body := req.Message
-	if body == "" {
-		body = fmt.Sprintf("Hi! As discussed on the phone, here's your secure deposit link for your upcoming appointment at %s. We'll confirm your exact time once the deposit is received.",
-			req.ClinicName)
+	msg := templates.Rendered{Body: req.Message}
+	if msg.Body == "" {
+		msg = templates.Render(templates.VoiceDepositHandoff, templates.Params{"clinic_name": req.ClinicName}, nil)
 	}
 
 	reply := OutboundReply{
@@ -50,11 +50,11 @@ func (h *VoiceSMSHandoff) SendPaymentLinkSMS(ctx context.Context, req VoiceHando
 		ConversationID: req.ConversationID,
 		To:             req.PatientPhone,
 		From:           req.ClinicPhone,
-		Body:           body,
-		Metadata: map[string]string{
+		Body:           msg.Body,
+		Metadata: msg.Stamp(map[string]string{
 			"source":   "voice_handoff",
 			"voice_id": req.VoiceCallID,
-		},
+		}),
 	}
 
 	if err := h.messenger.SendReply(ctx, reply); err != nil {
@@ -4,13 +4,20 @@ import (
 	"context"
 	"errors"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/google/uuid"
 
 	"github.com/wolfman30/medspa-ai-platform/internal/clinic"
+	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
 )
 
+// log returns the worker logger tagged with the job's correlation fields.
+func (w *Worker) log(ctx context.Context) *logging.Logger {
+	return w.logger.WithContext(ctx)
+}
+
 // appendTranscript persists a message to both Redis (real-time) and PostgreSQL (long-term).
 func (w *Worker) appendTranscript(ctx context.Context, conversationID string, msg SMSTranscriptMessage) {
 	if w == nil || strings.TrimSpace(conversationID) == "" {
@@ -23,14 +30,14 @@ func (w *Worker) appendTranscript(ctx context.Context, conversationID string, ms
 	// Append to Redis (real-time, ephemeral)
 	if w.transcript != nil {
 		if err := w.transcript.Append(ctx, conversationID, msg); err != nil {
-			w.logger.Warn("failed to append sms transcript to Redis", "error", err, "conversation_id", conversationID)
+			w.log(ctx).Warn("failed to append sms transcript to Redis", "error", err)
 		}
 	}
 
 	// Persist to PostgreSQL (long-term history)
 	if w.convStore != nil {
 		if err := w.convStore.AppendMessage(ctx, conversationID, msg); err != nil {
-			w.logger.Warn("failed to persist message to database", "error", err, "conversation_id", conversationID)
+			w.log(ctx).Warn("failed to persist message to database", "error", err)
 		}
 	}
 }
@@ -47,16 +54,16 @@ func (w *Worker) isOptedOut(ctx context.Context, orgID string, recipient string)
 	}
 	clinicID, err := uuid.Parse(orgID)
 	if err != nil {
-		w.logger.Warn("opt-out check skipped: invalid org id", "org_id", orgID)
+		w.log(ctx).Warn("opt-out check skipped: invalid org id", "org_id", orgID)
 		return false
 	}
 	unsubscribed, err := w.optOutChecker.IsUnsubscribed(ctx, clinicID, recipient)
 	if err != nil {
-		w.logger.Warn("opt-out check failed", "error", err, "org_id", orgID)
+		w.log(ctx).Warn("opt-out check failed", "error", err, "org_id", orgID)
 		return false
 	}
 	if unsubscribed {
-		w.logger.Info("suppressing sms for opted-out recipient", "org_id", orgID, "to", recipient)
+		w.log(ctx).Info("suppressing sms for opted-out recipient", "org_id", orgID, "to", recipient)
 	}
 	return unsubscribed
 }
@@ -84,17 +91,71 @@ func (w *Worker) clinicConfig(ctx context.Context, orgID string) *clinic.Config
 	}
 	cfg, err := w.clinicStore.Get(ctx, orgID)
 	if err != nil {
-		w.logger.Warn("failed to load clinic config", "error", err, "org_id", orgID)
+		w.log(ctx).Warn("failed to load clinic config", "error", err, "org_id", orgID)
 		return nil
 	}
 	return cfg
 }
 
-// Start launches worker goroutines until ctx is cancelled.
+// clinicInactive reports whether the clinic has been deactivated. A missing
+// config or a failed lookup counts as active, so jobs fall back to the
+// default config rather than being dropped.
+func (w *Worker) clinicInactive(ctx context.Context, orgID string) bool {
+	if w == nil || w.clinicStore == nil || strings.TrimSpace(orgID) == "" {
+		return false
+	}
+	_, err := w.clinicStore.GetActive(ctx, strings.TrimSpace(orgID))
+	return errors.Is(err, clinic.ErrInactive)
+}
+
+// featureEnabled resolves a clinic feature flag through the worker's flag
+// cache, falling back to the registry default without a clinic store.
+func (w *Worker) featureEnabled(ctx context.Context, orgID, name string) bool {
+	if w == nil || w.flags == nil {
+		def, _ := clinic.LookupFlag(name)
+		return def.Default
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return w.flags.Enabled(ctx, strings.TrimSpace(orgID), name)
+}
+
+// Start launches worker goroutines until ctx is cancelled. Once it is, the
+// workers stop pulling jobs and in-flight jobs get the drain timeout to
+// finish; jobs that can't are requeued.
 func (w *Worker) Start(ctx context.Context) {
+	w.stopped = ctx.Done()
+	// Jobs keep the request-scoped values but outlive ctx until the drain
+	// deadline.
+	jobCtx, cancelJobs := context.WithCancel(context.WithoutCancel(ctx))
+	var runners sync.WaitGroup
 	for i := 0; i < w.cfg.workers; i++ {
 		w.wg.Add(1)
-		go w.run(ctx, i+1)
+		runners.Add(1)
+		go func(workerID int) {
+			defer runners.Done()
+			w.run(ctx, jobCtx, workerID)
+		}(i + 1)
+	}
+	w.wg.Add(1)
+	go w.drain(ctx, &runners, cancelJobs)
+	if w.scheduler != nil {
+		if w.convStore != nil && w.scheduler.conversations == nil {
+			w.scheduler.SetConversationLookup(w.convStore)
+		}
+		w.wg.Add(1)
+		go func() {
+			defer w.wg.Done()
+			w.scheduler.Run(ctx)
+		}()
+	}
+	if w.reaper != nil {
+		w.wg.Add(1)
+		go func() {
+			defer w.wg.Done()
+			w.reaper.Run(ctx)
+		}()
 	}
 }
 
@@ -104,17 +165,18 @@ func (w *Worker) Wait() {
 }
 
 // run is the main loop for a single worker goroutine. It polls the queue
-// for messages and dispatches each one via handleMessage.
-func (w *Worker) run(ctx context.Context, workerID int) {
+// for messages and dispatches each one via handleMessage. Jobs run under
+// jobCtx so a shutdown doesn't cut them off mid-reply.
+func (w *Worker) run(ctx, jobCtx context.Context, workerID int) {
 	defer w.wg.Done()
-	w.logger.Debug("conversation worker started", "worker_id", workerID)
+	w.log(ctx).Debug("conversation worker started", "worker_id", workerID)
 
 	backoff := time.Second
 
 	for {
 		select {
 		case <-ctx.Done():
-			w.logger.Debug("conversation worker stopping", "worker_id", workerID)
+			w.log(ctx).Debug("conversation worker stopping", "worker_id", workerID)
 			return
 		default:
 		}
@@ -124,7 +186,7 @@ func (w *Worker) run(ctx context.Context, workerID int) {
 			if errors.Is(err, context.Canceled) {
 				return
 			}
-			w.logger.Error("failed to receive conversation jobs", "error", err, "worker_id", workerID)
+			w.log(ctx).Error("failed to receive conversation jobs", "error", err, "worker_id", workerID)
 			time.Sleep(backoff)
 			if backoff < 5*time.Second {
 				backoff *= 2
@@ -133,8 +195,21 @@ func (w *Worker) run(ctx context.Context, workerID int) {
 		}
 		backoff = time.Second
 
+		received := time.Now()
 		for _, msg := range messages {
-			w.handleMessage(ctx, msg)
+			observeReceived(msg, received)
+			if ctx.Err() != nil {
+				// Shutdown started mid-batch; hand back jobs not yet begun.
+				if err := w.requeue(msg, msg.Body); err != nil {
+					w.log(ctx).Error("failed to requeue unstarted job", "error", err, "msg_id", msg.ID)
+					continue
+				}
+				workerShutdownRequeuedTotal.WithLabelValues("not_started").Inc()
+				continue
+			}
+			w.inflight.add(msg.ReceiptHandle)
+			w.handleMessage(jobCtx, msg)
+			w.inflight.remove(msg.ReceiptHandle)
 		}
 	}
 }
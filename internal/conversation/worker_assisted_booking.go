@@ -0,0 +1,193 @@
+package conversation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/leads"
+	"github.com/wolfman30/medspa-ai-platform/internal/tenancy"
+)
+
+// ErrAssistedBookingOptedOut is returned when staff try to book for a patient
+// who has opted out of texts, since the deposit link can't be delivered.
+var ErrAssistedBookingOptedOut = errors.New("conversation: assisted booking: patient has opted out of sms")
+
+// handleAssistedBooking runs a staff-assisted booking job. Without a slot it
+// returns the available times for staff to pick from, texting the patient
+// nothing; with one it books through the SMS booking path.
+func (w *Worker) handleAssistedBooking(ctx context.Context, req *AssistedBookingRequest) (*Response, error) {
+	if req == nil {
+		return nil, errors.New("conversation: assisted booking: missing request")
+	}
+	if w.leadsRepo == nil {
+		return nil, errors.New("conversation: assisted booking: leads repository not configured")
+	}
+	lead, err := w.assistedBookingLead(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Slot == nil {
+		searcher, ok := w.processor.(AssistedAvailabilitySearcher)
+		if !ok {
+			return nil, errors.New("conversation: assisted booking: processor does not support availability search")
+		}
+		tsr, err := searcher.SearchAssistedAvailability(ctx, *req)
+		if err != nil {
+			return nil, err
+		}
+		w.log(ctx).Info("assisted booking: availability found",
+			"lead_id", lead.ID, "service", req.Service, "slots", len(tsr.Slots), "requested_by", req.RequestedBy)
+		return &Response{
+			ConversationID:        req.ConversationID,
+			Timestamp:             time.Now().UTC(),
+			TimeSelectionResponse: tsr,
+		}, nil
+	}
+	return w.bookAssisted(ctx, req, lead)
+}
+
+// assistedBookingLead finds or creates the patient's lead and saves what
+// staff entered about them.
+func (w *Worker) assistedBookingLead(ctx context.Context, req *AssistedBookingRequest) (*leads.Lead, error) {
+	lead, err := w.leadsRepo.GetOrCreateByPhone(ctx, req.OrgID, req.Phone, assistedBookingLeadSource, req.Name)
+	if err != nil {
+		return nil, fmt.Errorf("conversation: assisted booking: load lead: %w", err)
+	}
+	if err := w.leadsRepo.UpdateSchedulingPreferences(ctx, tenancy.ForOrg(req.OrgID), lead.ID, leads.SchedulingPreferences{
+		Name:               req.Name,
+		ServiceInterest:    req.Service,
+		PreferredDays:      req.PreferredDays,
+		PreferredTimes:     req.PreferredTimes,
+		ProviderPreference: req.Provider,
+	}); err != nil {
+		w.log(ctx).Warn("assisted booking: failed to save lead preferences", "error", err, "lead_id", lead.ID)
+	}
+	if req.Email != "" {
+		if err := w.leadsRepo.UpdateEmail(ctx, tenancy.ForOrg(req.OrgID), lead.ID, req.Email); err != nil {
+			w.log(ctx).Warn("assisted booking: failed to save lead email", "error", err, "lead_id", lead.ID)
+		}
+		lead.Email = req.Email
+	}
+	if req.Name != "" {
+		lead.Name = req.Name
+	}
+	return lead, nil
+}
+
+// bookAssisted records the staff-picked slot on the lead, tells the patient
+// the clinic started their booking, and hands off to the Moxie booking or
+// deposit path exactly as a patient's own slot pick would.
+func (w *Worker) bookAssisted(ctx context.Context, req *AssistedBookingRequest, lead *leads.Lead) (*Response, error) {
+	cfg := w.clinicConfig(ctx, req.OrgID)
+	if cfg == nil {
+		return nil, errors.New("conversation: assisted booking: clinic config not found")
+	}
+	if w.deposits == nil {
+		return nil, errors.New("conversation: assisted booking: deposits not configured")
+	}
+	if w.isOptedOut(ctx, req.OrgID, req.Phone) {
+		return nil, ErrAssistedBookingOptedOut
+	}
+	clinicNumber := w.clinicNumberForConversation(ctx, req.OrgID, req.ConversationID)
+	if clinicNumber == "" {
+		return nil, errors.New("conversation: assisted booking: clinic has no sms number")
+	}
+
+	loc := ClinicLocation(cfg.Timezone)
+	start := req.Slot.Start.In(loc)
+	var end *time.Time
+	if req.Slot.End != nil {
+		e := req.Slot.End.In(loc)
+		end = &e
+	} else if d := cfg.DurationForService(req.Service); d > 0 {
+		e := start.Add(d)
+		end = &e
+	}
+	// Moxie writeback after payment books whatever slot is on the lead.
+	if err := w.leadsRepo.UpdateSelectedAppointment(ctx, tenancy.ForOrg(req.OrgID), lead.ID, leads.SelectedAppointment{
+		DateTime:    &start,
+		EndDateTime: end,
+		Service:     req.Service,
+	}); err != nil {
+		return nil, fmt.Errorf("conversation: assisted booking: save selected appointment: %w", err)
+	}
+
+	msg := MessageRequest{
+		OrgID:          req.OrgID,
+		LeadID:         lead.ID,
+		ConversationID: req.ConversationID,
+		From:           req.Phone,
+		To:             clinicNumber,
+		Channel:        ChannelSMS,
+	}
+	intro := assistedBookingIntro(cfg, lead.Name, req.Service, start)
+	if w.messenger != nil {
+		sendCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		err := w.messenger.SendReply(sendCtx, OutboundReply{
+			OrgID:          req.OrgID,
+			LeadID:         lead.ID,
+			ConversationID: req.ConversationID,
+			To:             req.Phone,
+			From:           clinicNumber,
+			Body:           intro.Body,
+			Metadata:       intro.Stamp(nil),
+		})
+		cancel()
+		if err != nil {
+			w.log(ctx).Error("assisted booking: failed to send intro sms", "error", err, "lead_id", lead.ID)
+		}
+	}
+	w.appendTranscript(context.WithoutCancel(ctx), req.ConversationID, SMSTranscriptMessage{
+		Role:      "assistant",
+		From:      clinicNumber,
+		To:        req.Phone,
+		Body:      intro.Body,
+		Timestamp: time.Now(),
+		Kind:      "assisted_booking",
+		Metadata: intro.Stamp(map[string]string{
+			"lead_id":      lead.ID,
+			"requested_by": req.RequestedBy,
+		}),
+	})
+
+	if cfg.UsesMoxieBooking() {
+		firstName, lastName := splitName(lead.Name)
+		w.handleMoxieBooking(ctx, msg, &BookingRequest{
+			BookingURL: cfg.BookingURL,
+			Date:       start.Format("2006-01-02"),
+			Time:       strings.ToLower(start.Format("3:04pm")),
+			Service:    req.Service,
+			Provider:   req.Provider,
+			LeadID:     lead.ID,
+			OrgID:      req.OrgID,
+			FirstName:  firstName,
+			LastName:   lastName,
+			Phone:      req.Phone,
+			Email:      lead.Email,
+		})
+	} else {
+		w.handleDepositIntent(ctx, msg, &Response{
+			ConversationID: req.ConversationID,
+			DepositIntent: &DepositIntent{
+				AmountCents:     int32(cfg.DepositAmountForService(req.Service)),
+				Description:     fmt.Sprintf("%s - %s", req.Service, start.Format("Mon Jan 2 at 3:04 PM")),
+				ScheduledFor:    &start,
+				Service:         req.Service,
+				BookingPolicies: cfg.BookingPolicies,
+			},
+		})
+	}
+
+	w.log(ctx).Info("assisted booking: booking started",
+		"lead_id", lead.ID, "service", req.Service, "start", start.Format(time.RFC3339), "requested_by", req.RequestedBy)
+	return &Response{
+		ConversationID: req.ConversationID,
+		Message:        intro.Body,
+		Timestamp:      time.Now().UTC(),
+		Template:       intro.Ref,
+	}, nil
+}
@@ -0,0 +1,163 @@
+package conversation
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/clinic"
+	"github.com/wolfman30/medspa-ai-platform/internal/leads"
+	"github.com/wolfman30/medspa-ai-platform/internal/notify"
+	"github.com/wolfman30/medspa-ai-platform/internal/tenancy"
+)
+
+// AttachmentNotifier forwards patient attachments to clinic operators. The
+// payment notifier implements it when operator alerts are available.
+type AttachmentNotifier interface {
+	NotifyAttachmentReceived(ctx context.Context, orgID string, att notify.AttachmentReceived) error
+}
+
+// MediaFetcher downloads an MMS attachment.
+type MediaFetcher interface {
+	FetchMedia(ctx context.Context, url string) ([]byte, error)
+}
+
+// maxVCardBytes caps how much of a contact card is read.
+const maxVCardBytes = 256 << 10
+
+// httpMediaFetcher fetches carrier-hosted media over plain HTTP; Telnyx media
+// URLs are pre-signed.
+type httpMediaFetcher struct {
+	client *http.Client
+}
+
+func newHTTPMediaFetcher() *httpMediaFetcher {
+	return &httpMediaFetcher{client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+func (f *httpMediaFetcher) FetchMedia(ctx context.Context, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("conversation: build media request: %w", err)
+	}
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("conversation: fetch media: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("conversation: fetch media: status %d", resp.StatusCode)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxVCardBytes))
+	if err != nil {
+		return nil, fmt.Errorf("conversation: read media: %w", err)
+	}
+	return data, nil
+}
+
+// handleAttachments applies the clinic's attachment rules to an inbound MMS:
+// forwarded attachments are sent to operators with their media link, and
+// contact cards are read into the lead. When the patient sent only
+// attachments, the rules' replies answer them instead of the assistant;
+// otherwise it returns nil so the text is processed as usual.
+func (w *Worker) handleAttachments(ctx context.Context, msg MessageRequest) *Response {
+	if msg.Channel != ChannelSMS {
+		return nil
+	}
+	attachments := DecodeAttachments(msg.Metadata[MetadataAttachments])
+	if len(attachments) == 0 {
+		return nil
+	}
+	cfg := w.clinicConfig(ctx, msg.OrgID)
+
+	var replies []string
+	seen := make(map[string]bool)
+	for _, att := range attachments {
+		rule := cfg.AttachmentRuleFor(att.Kind)
+		switch rule.Action {
+		case clinic.AttachmentActionForward:
+			w.forwardAttachment(ctx, msg, att)
+		case clinic.AttachmentActionContact:
+			w.saveContactCard(ctx, msg, att)
+		}
+		w.events.Log(ctx, "attachment_handled", msg.ConversationID, msg.OrgID, msg.LeadID, map[string]any{
+			"kind":   att.Kind,
+			"action": rule.Action,
+		})
+		if !seen[rule.Reply] {
+			seen[rule.Reply] = true
+			replies = append(replies, rule.Reply)
+		}
+	}
+
+	if strings.TrimSpace(msg.Message) != MediaPlaceholder {
+		return nil
+	}
+	return &Response{
+		ConversationID: msg.ConversationID,
+		Message:        strings.Join(replies, " "),
+		Timestamp:      time.Now().UTC(),
+	}
+}
+
+func (w *Worker) forwardAttachment(ctx context.Context, msg MessageRequest, att Attachment) {
+	notifier, ok := w.notifier.(AttachmentNotifier)
+	if !ok {
+		return
+	}
+	if err := notifier.NotifyAttachmentReceived(ctx, msg.OrgID, notify.AttachmentReceived{
+		LeadID:      msg.LeadID,
+		Phone:       msg.From,
+		Kind:        att.Kind,
+		MediaURL:    att.URL,
+		ContentType: att.ContentType,
+		ReceivedAt:  time.Now().UTC(),
+	}); err != nil {
+		w.log(ctx).Warn("failed to forward attachment to operators", "error", err, "kind", att.Kind)
+	}
+}
+
+// saveContactCard reads a texted vCard into the lead, filling in a missing
+// name or email. A phone number other than the patient's is kept in the
+// scheduling notes rather than replacing the number they text from.
+func (w *Worker) saveContactCard(ctx context.Context, msg MessageRequest, att Attachment) {
+	if w.mediaFetcher == nil || w.leadsRepo == nil || msg.LeadID == "" {
+		return
+	}
+	data, err := w.mediaFetcher.FetchMedia(ctx, att.URL)
+	if err != nil {
+		w.log(ctx).Warn("failed to fetch contact card", "error", err)
+		return
+	}
+	card := ParseVCard(data)
+	lead, err := w.leadsRepo.GetByID(ctx, tenancy.ForOrg(msg.OrgID), msg.LeadID)
+	if err != nil || lead == nil {
+		w.log(ctx).Warn("failed to load lead for contact card", "error", err)
+		return
+	}
+
+	var patch leads.SchedulingPreferences
+	if strings.TrimSpace(lead.Name) == "" {
+		patch.Name = card.Name
+	}
+	if card.Phone != "" && card.Phone != lead.Phone && !strings.Contains(lead.SchedulingNotes, card.Phone) {
+		note := "Contact card phone: " + card.Phone
+		if notes := strings.TrimSpace(lead.SchedulingNotes); notes != "" {
+			note = notes + "; " + note
+		}
+		patch.Notes = note
+	}
+	if patch.Name != "" || patch.Notes != "" {
+		if err := w.leadsRepo.MergePreferences(ctx, msg.LeadID, patch); err != nil {
+			w.log(ctx).Warn("failed to save contact card to lead", "error", err)
+		}
+	}
+	if card.Email != "" && strings.TrimSpace(lead.Email) == "" {
+		if err := w.leadsRepo.UpdateEmail(ctx, tenancy.ForOrg(msg.OrgID), msg.LeadID, card.Email); err != nil {
+			w.log(ctx).Warn("failed to save contact card email", "error", err)
+		}
+	}
+}
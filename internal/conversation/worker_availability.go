@@ -0,0 +1,80 @@
+package conversation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/tenancy"
+)
+
+// refreshAvailability re-runs the availability search for a stuck
+// conversation and texts the patient the fresh slots through the normal
+// time-selection path.
+func (w *Worker) refreshAvailability(ctx context.Context, req *RefreshAvailabilityRequest) (*Response, error) {
+	if req == nil {
+		return nil, errors.New("conversation: refresh availability: missing request")
+	}
+	provider, ok := w.processor.(AvailabilityProvider)
+	if !ok {
+		return nil, errors.New("conversation: refresh availability: processor does not support availability refresh")
+	}
+
+	// Re-check state here: a deposit may have landed between the admin
+	// request and this job running.
+	if w.convStore != nil {
+		conv, err := w.convStore.GetConversation(ctx, tenancy.ForOrg(req.OrgID), req.ConversationID)
+		if err != nil {
+			return nil, fmt.Errorf("conversation: refresh availability: %w", err)
+		}
+		if conv != nil && availabilityRefreshBlocked(conv.Status) {
+			return nil, ErrAvailabilityRefreshNotAllowed
+		}
+	}
+
+	tsr, err := provider.RefreshAvailability(ctx, *req)
+	if err != nil {
+		return nil, err
+	}
+
+	msg := MessageRequest{
+		OrgID:          req.OrgID,
+		LeadID:         req.LeadID,
+		ConversationID: req.ConversationID,
+		From:           req.Phone,
+		To:             w.clinicNumberForConversation(ctx, req.OrgID, req.ConversationID),
+		Channel:        ChannelSMS,
+	}
+	resp := &Response{
+		ConversationID:        req.ConversationID,
+		Timestamp:             time.Now().UTC(),
+		TimeSelectionResponse: tsr,
+	}
+	w.handleTimeSelectionResponse(ctx, msg, resp)
+
+	w.log(ctx).Info("availability refreshed",
+		"org_id", req.OrgID,
+		"slots", len(tsr.Slots),
+	)
+	return resp, nil
+}
+
+// clinicNumberForConversation returns the number the patient has been
+// texting, falling back to the clinic's configured SMS number.
+func (w *Worker) clinicNumberForConversation(ctx context.Context, orgID, conversationID string) string {
+	if w.transcript != nil {
+		if msgs, err := w.transcript.List(ctx, conversationID, 50); err == nil {
+			for i := len(msgs) - 1; i >= 0; i-- {
+				if msgs[i].FromPatient() && strings.TrimSpace(msgs[i].To) != "" {
+					return msgs[i].To
+				}
+			}
+		}
+	}
+	if cfg := w.clinicConfig(ctx, orgID); cfg != nil {
+		return cfg.SMSPhoneNumber
+	}
+	return ""
+}
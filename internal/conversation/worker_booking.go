@@ -2,6 +2,7 @@ package conversation
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
 	"time"
@@ -9,6 +10,8 @@ import (
 	"github.com/wolfman30/medspa-ai-platform/internal/clinic"
 	moxieclient "github.com/wolfman30/medspa-ai-platform/internal/emr/moxie"
 	"github.com/wolfman30/medspa-ai-platform/internal/leads"
+	"github.com/wolfman30/medspa-ai-platform/internal/messaging/templates"
+	"github.com/wolfman30/medspa-ai-platform/internal/tenancy"
 )
 
 func (w *Worker) handleMoxieBooking(ctx context.Context, msg MessageRequest, req *BookingRequest) {
@@ -16,13 +19,27 @@ func (w *Worker) handleMoxieBooking(ctx context.Context, msg MessageRequest, req
 		return
 	}
 
+	// A deposit carried over from a taken slot pays for this booking, so book
+	// straight through Moxie instead of collecting another one.
+	if req.DepositApplied && w.moxieClient != nil && w.clinicStore != nil && w.featureEnabled(ctx, req.OrgID, clinic.FlagMoxieAPIBooking) {
+		cfg, err := w.clinicStore.Get(ctx, req.OrgID)
+		if err == nil && cfg != nil && cfg.UsesMoxieBooking() && cfg.MoxieConfig != nil {
+			if w.handleMoxieBookingDirect(ctx, msg, req, cfg) && w.leadsRepo != nil && req.LeadID != "" {
+				if err := w.leadsRepo.UpdateDepositStatus(ctx, tenancy.ForOrg(req.OrgID), req.LeadID, "paid", "priority"); err != nil {
+					w.log(ctx).Warn("failed to restore deposit status after rebooking", "error", err, "lead_id", req.LeadID)
+				}
+			}
+			return
+		}
+	}
+
 	// Check if clinic uses Stripe for payments — if so, send Stripe Checkout link
 	// instead of Moxie sidecar URL. After payment, handlePaymentEvent will call
 	// createMoxieBookingAfterPayment to book via Moxie API.
 	if w.deposits != nil && w.clinicStore != nil {
 		cfg, err := w.clinicStore.Get(ctx, req.OrgID)
 		if err == nil && cfg != nil && cfg.UsesStripePayment() {
-			w.logger.Info("moxie booking: routing to Stripe Checkout (payment_provider=stripe)",
+			w.log(ctx).Info("moxie booking: routing to Stripe Checkout (payment_provider=stripe)",
 				"org_id", req.OrgID, "lead_id", req.LeadID, "service", req.Service)
 			// Parse booking date/time into a time.Time for the deposit intent
 			var scheduledFor *time.Time
@@ -44,72 +61,77 @@ func (w *Worker) handleMoxieBooking(ctx context.Context, msg MessageRequest, req
 					AmountCents:     int32(cfg.DepositAmountForService(req.Service)),
 					Description:     desc,
 					ScheduledFor:    scheduledFor,
+					Service:         req.Service,
 					BookingPolicies: cfg.BookingPolicies,
 				},
 			}
 			if err := w.deposits.SendDeposit(ctx, msg, resp); err != nil {
-				w.logger.Error("failed to send Stripe checkout for Moxie booking",
+				w.log(ctx).Error("failed to send Stripe checkout for Moxie booking",
 					"error", err, "org_id", req.OrgID, "lead_id", req.LeadID)
 			}
 			return
 		}
 	}
 
-	w.logger.Warn("booking request received but no payment provider configured",
+	w.log(ctx).Warn("booking request received but no payment provider configured",
 		"org_id", req.OrgID, "lead_id", req.LeadID)
-	w.sendBookingFallbackSMS(ctx, msg, "We're having trouble processing your booking right now. Please call the clinic directly to complete your appointment.")
+	w.sendBookingFallbackSMS(ctx, msg, templates.BookingFallbackCallClinic)
 }
 
 // handleMoxieBookingDirect creates a Moxie appointment via their GraphQL API.
+// Returns true when the appointment was created.
 
-func (w *Worker) handleMoxieBookingDirect(ctx context.Context, msg MessageRequest, req *BookingRequest, cfg *clinic.Config) {
+func (w *Worker) handleMoxieBookingDirect(ctx context.Context, msg MessageRequest, req *BookingRequest, cfg *clinic.Config) bool {
+	if cfg == nil || cfg.MoxieConfig == nil {
+		w.log(ctx).Error("moxie booking skipped: no moxie config", "org_id", req.OrgID, "lead_id", req.LeadID)
+		w.sendBookingFallbackSMS(ctx, msg, templates.BookingFallbackCallClinic)
+		return false
+	}
 	mc := cfg.MoxieConfig
-	w.logger.Info("creating Moxie appointment via direct API",
+	w.log(ctx).Info("creating Moxie appointment via direct API",
 		"org_id", req.OrgID, "lead_id", req.LeadID,
 		"medspa_id", mc.MedspaID, "service", req.Service)
 
 	// Resolve serviceMenuItemId from service name
-	serviceMenuItemID := ""
-	normalizedService := strings.ToLower(req.Service)
-	if mc.ServiceMenuItems != nil {
-		serviceMenuItemID = mc.ServiceMenuItems[normalizedService]
-		// Try alias resolution
-		if serviceMenuItemID == "" {
-			resolved := cfg.ResolveServiceName(normalizedService)
-			serviceMenuItemID = mc.ServiceMenuItems[strings.ToLower(resolved)]
-		}
-	}
+	serviceMenuItemID := moxieServiceMenuItemID(cfg, req.Service)
 	if serviceMenuItemID == "" {
-		w.logger.Error("no Moxie serviceMenuItemId for service",
+		w.log(ctx).Error("no Moxie serviceMenuItemId for service",
 			"service", req.Service, "org_id", req.OrgID)
-		w.sendBookingFallbackSMS(ctx, msg, "We couldn't find that service in our booking system. Please call the clinic directly to book your appointment.")
-		return
+		w.sendBookingFallbackSMS(ctx, msg, templates.BookingFallbackUnknownService)
+		return false
 	}
 
 	// Parse the selected time slot to get start/end times in UTC
 	// req.Date is YYYY-MM-DD, req.Time is e.g. "7:15 PM"
 	startTime, endTime, err := w.parseMoxieTimeSlot(req.Date, req.Time, cfg.Timezone)
 	if err != nil {
-		w.logger.Error("failed to parse time slot for Moxie booking",
+		w.log(ctx).Error("failed to parse time slot for Moxie booking",
 			"error", err, "date", req.Date, "time", req.Time)
-		w.sendBookingFallbackSMS(ctx, msg, "We had trouble with the appointment time. Please try again or call the clinic directly.")
-		return
+		w.sendBookingFallbackSMS(ctx, msg, templates.BookingFallbackBadTime)
+		return false
 	}
 
 	// Determine provider ID
-	providerID := mc.DefaultProviderID
+	var providerID string
+	if start, err := time.Parse(time.RFC3339, startTime); err == nil {
+		providerID = w.balancedProviderID(ctx, req.OrgID, msg.ConversationID, start)
+	}
 	if providerID == "" {
-		providerID = "no-preference"
+		providerID = mc.DefaultProviderID
+	}
+	if providerID == "" {
+		providerID = moxieNoPreferenceProvider
 	}
 
 	// Create the appointment
-	result, err := w.moxieClient.CreateAppointment(ctx, moxieclient.CreateAppointmentRequest{
+	key := bookingIdempotencyKey("", req.OrgID, req.LeadID, serviceMenuItemID, startTime)
+	result, err := w.writeBackMoxieAppointment(ctx, key, req.OrgID, req.LeadID, moxieclient.CreateAppointmentRequest{
 		MedspaID:  mc.MedspaID,
 		FirstName: req.FirstName,
 		LastName:  req.LastName,
 		Email:     req.Email,
 		Phone:     req.Phone,
-		Note:      "",
+		Note:      req.Notes,
 		Services: []moxieclient.ServiceInput{{
 			ServiceMenuItemID: serviceMenuItemID,
 			ProviderID:        providerID,
@@ -117,37 +139,49 @@ func (w *Worker) handleMoxieBookingDirect(ctx context.Context, msg MessageReques
 			EndTime:           endTime,
 		}},
 		IsNewClient:              true, // Assume new client for SMS leads
-		NoPreferenceProviderUsed: providerID == "no-preference",
+		NoPreferenceProviderUsed: providerID == moxieNoPreferenceProvider,
 	})
+	if errors.Is(err, errWritebackHandedOff) {
+		w.notifyBookingHandoff(ctx, req.OrgID, req.LeadID, msg.From, req.Service, startTime, moxieHandoffReason)
+		w.sendBookingFallbackSMS(ctx, msg, templates.BookingFallbackClinicConfirms)
+		return false
+	}
 	if err != nil {
-		w.logger.Error("Moxie API create appointment failed", "error", err,
+		w.log(ctx).Error("Moxie API create appointment failed", "error", err,
 			"org_id", req.OrgID, "lead_id", req.LeadID)
-		w.sendBookingFallbackSMS(ctx, msg, "We're having trouble booking your appointment right now. Please try again in a moment or call the clinic directly.")
-		return
+		w.sendBookingFallbackSMS(ctx, msg, templates.BookingFallbackRetry)
+		return false
 	}
 
 	if !result.OK {
-		w.logger.Error("Moxie appointment creation returned not OK",
+		w.log(ctx).Error("Moxie appointment creation returned not OK",
 			"message", result.Message, "org_id", req.OrgID, "lead_id", req.LeadID)
-		w.sendBookingFallbackSMS(ctx, msg, "We're having trouble booking your appointment right now. Please try again in a moment or call the clinic directly.")
-		return
+		w.sendBookingFallbackSMS(ctx, msg, templates.BookingFallbackRetry)
+		return false
 	}
 
-	w.logger.Info("Moxie appointment created successfully via API",
+	w.log(ctx).Info("Moxie appointment created successfully via API",
 		"appointment_id", result.AppointmentID,
 		"org_id", req.OrgID, "lead_id", req.LeadID,
-		"service", req.Service, "date", req.Date, "time", req.Time)
+		"service", req.Service, "date", req.Date, "time", req.Time, "provider_id", providerID)
+	w.recordBookingProvider(ctx, req.OrgID, req.LeadID, providerID)
 
 	// Update conversation status to booked
 	if w.convStore != nil {
-		if err := w.convStore.UpdateStatus(ctx, msg.ConversationID, StatusBooked); err != nil {
-			w.logger.Warn("failed to update conversation status to booked", "error", err, "conversation_id", msg.ConversationID)
+		if err := w.convStore.UpdateStatus(ctx, tenancy.ForOrg(msg.OrgID), msg.ConversationID, StatusBooked); err != nil {
+			w.log(ctx).Warn("failed to update conversation status to booked", "error", err)
 		}
 	}
+	w.notifyBookingConfirmed(ctx, req.OrgID, req.LeadID, msg.From, req.Service, startTime, result.AppointmentID)
 
 	// Send confirmation SMS
-	confirmMsg := fmt.Sprintf("Your appointment has been booked! 🎉\n\n📋 %s\n📅 %s at %s\n📍 %s\n\nYou'll receive a confirmation from the clinic shortly. See you then!",
-		req.Service, req.Date, req.Time, cfg.Name)
+	confirm := cfg.RenderTemplate(templates.BookingConfirmed, templates.Params{
+		"service":     req.Service,
+		"date":        req.Date,
+		"time":        req.Time,
+		"clinic_name": cfg.Name,
+	})
+	confirmMsg := confirm.Body
 	if w.messenger != nil {
 		reply := OutboundReply{
 			OrgID:          msg.OrgID,
@@ -156,9 +190,10 @@ func (w *Worker) handleMoxieBookingDirect(ctx context.Context, msg MessageReques
 			To:             msg.From,
 			From:           msg.To,
 			Body:           confirmMsg,
+			Metadata:       confirm.Stamp(nil),
 		}
 		if err := w.messenger.SendReply(ctx, reply); err != nil {
-			w.logger.Error("failed to send booking confirmation SMS", "error", err,
+			w.log(ctx).Error("failed to send booking confirmation SMS", "error", err,
 				"org_id", req.OrgID, "appointment_id", result.AppointmentID)
 		}
 	}
@@ -166,12 +201,12 @@ func (w *Worker) handleMoxieBookingDirect(ctx context.Context, msg MessageReques
 	// Update lead with appointment ID
 	if w.leadsRepo != nil && req.LeadID != "" {
 		now := time.Now()
-		if err := w.leadsRepo.UpdateBookingSession(ctx, req.LeadID, leads.BookingSessionUpdate{
+		if err := w.leadsRepo.UpdateBookingSession(ctx, tenancy.ForOrg(req.OrgID), req.LeadID, leads.BookingSessionUpdate{
 			SessionID:     result.AppointmentID,
 			Platform:      "moxie",
 			HandoffSentAt: &now,
 		}); err != nil {
-			w.logger.Warn("failed to update lead with appointment ID", "error", err,
+			w.log(ctx).Warn("failed to update lead with appointment ID", "error", err,
 				"lead_id", req.LeadID, "appointment_id", result.AppointmentID)
 		}
 	}
@@ -181,14 +216,24 @@ func (w *Worker) handleMoxieBookingDirect(ctx context.Context, msg MessageReques
 		Role:      "assistant",
 		Body:      confirmMsg,
 		Timestamp: time.Now(),
+		Metadata:  confirm.Stamp(nil),
 	})
 	if w.convStore != nil {
 		_ = w.convStore.AppendMessage(ctx, msg.ConversationID, SMSTranscriptMessage{
 			Role:      "assistant",
 			Body:      confirmMsg,
 			Timestamp: time.Now(),
+			Metadata:  confirm.Stamp(nil),
 		})
 	}
+	w.askMarketingConsent(ctx, cfg, OutboundReply{
+		OrgID:          msg.OrgID,
+		LeadID:         msg.LeadID,
+		ConversationID: msg.ConversationID,
+		To:             msg.From,
+		From:           msg.To,
+	})
+	return true
 }
 
 func (w *Worker) parseMoxieTimeSlot(date, timeStr, timezone string) (string, string, error) {
@@ -0,0 +1,109 @@
+package conversation
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/clinic"
+	"github.com/wolfman30/medspa-ai-platform/internal/notify"
+	"github.com/wolfman30/medspa-ai-platform/internal/tenancy"
+)
+
+// callbackPaused reports whether AI replies are paused because the
+// conversation is waiting on an operator callback. A message carrying booking
+// details resolves the task as resumed and lets the AI continue, unless the
+// task is a frustration handoff, which only an operator can close.
+func (w *Worker) callbackPaused(ctx context.Context, msg MessageRequest) bool {
+	if w == nil || w.callbackTasks == nil || strings.TrimSpace(msg.ConversationID) == "" {
+		return false
+	}
+	task, err := w.callbackTasks.OpenForConversation(ctx, msg.ConversationID)
+	if err != nil {
+		w.log(ctx).Warn("callback task lookup failed", "error", err)
+		return false
+	}
+	if task == nil {
+		return false
+	}
+
+	if task.Reason == CallbackTaskReasonFrustration {
+		return true
+	}
+	if !resumesBookingFlow(msg.Message, serviceAliasesFromConfig(w.clinicConfig(ctx, msg.OrgID))) {
+		return true
+	}
+	if _, err := w.callbackTasks.Resolve(ctx, tenancy.ForOrg(task.OrgID), task.ID, CallbackTaskStatusResumed); err != nil {
+		w.log(ctx).Warn("failed to resume callback task", "error", err, "task_id", task.ID)
+	}
+	w.log(ctx).Info("callback task resumed by patient",
+		"org_id", msg.OrgID,
+		"task_id", task.ID,
+	)
+	return false
+}
+
+// openCallbackTask records an operator callback task when the patient asks to
+// be called, notifies the clinic, and returns the confirmation reply. Returns
+// nil when the message isn't a call request or tasks aren't configured.
+func (w *Worker) openCallbackTask(ctx context.Context, msg MessageRequest) *Response {
+	if w == nil || w.callbackTasks == nil {
+		return nil
+	}
+	if msg.Channel != ChannelSMS || !IsCallbackRequest(msg.Message) {
+		return nil
+	}
+
+	now := time.Now().UTC()
+	task := &CallbackTask{
+		OrgID:           msg.OrgID,
+		ConversationID:  msg.ConversationID,
+		LeadID:          msg.LeadID,
+		Phone:           msg.From,
+		RequestedAt:     now,
+		PreferredWindow: ParseCallbackWindow(msg.Message),
+		PatientMessage:  msg.Message,
+	}
+	if err := w.callbackTasks.Create(ctx, task); err != nil {
+		w.log(ctx).Error("failed to create callback task", "error", err)
+		return nil
+	}
+	w.log(ctx).Info("callback task created",
+		"org_id", msg.OrgID,
+		"task_id", task.ID,
+		"preferred_window", task.PreferredWindow,
+		"to", maskPhone(msg.From),
+	)
+
+	if w.callbackNotifier != nil {
+		if err := w.callbackNotifier.NotifyCallbackRequested(ctx, msg.OrgID, notify.CallbackRequest{
+			LeadID:          task.LeadID,
+			Phone:           task.Phone,
+			PreferredWindow: task.PreferredWindow,
+			RequestedAt:     task.RequestedAt,
+		}); err != nil {
+			w.log(ctx).Error("failed to notify operators of callback request", "error", err, "task_id", task.ID)
+		}
+	}
+
+	return &Response{
+		ConversationID: msg.ConversationID,
+		Message:        callbackConfirmation(w.clinicConfig(ctx, msg.OrgID), task.PreferredWindow, now),
+		Timestamp:      now,
+	}
+}
+
+// callbackConfirmation tells the patient when to expect a call, based on the
+// clinic's business hours.
+func callbackConfirmation(cfg *clinic.Config, preferredWindow string, now time.Time) string {
+	when := "shortly"
+	if cfg != nil {
+		when = cfg.ExpectedCallbackTime(now)
+	}
+	msg := fmt.Sprintf("No problem! I've let our team know you'd like a call. Someone will call you %s.", when)
+	if preferredWindow != "" {
+		msg += fmt.Sprintf(" We've noted you prefer %s and will do our best to reach you then.", preferredWindow)
+	}
+	return msg
+}
@@ -6,28 +6,76 @@ import (
 	"fmt"
 	"strings"
 	"time"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/clinic"
+	"github.com/wolfman30/medspa-ai-platform/internal/messaging/templates"
+	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
 )
 
 // handleMessage decodes a queue message, dispatches it to the appropriate
 // processor method (start, message, payment), and handles reply routing.
 func (w *Worker) handleMessage(ctx context.Context, msg queueMessage) {
+	started := time.Now()
 	var payload queuePayload
+	outcome := jobOutcomeDeferred
+	defer func() { recordJob(payload.Kind, outcome, started) }()
 	if err := json.Unmarshal([]byte(msg.Body), &payload); err != nil {
-		w.logger.Error("failed to decode conversation job", "error", err)
+		outcome = jobOutcomeDropped
+		w.log(ctx).Error("failed to decode conversation job", "error", err)
 		w.deleteMessage(context.Background(), msg.ReceiptHandle)
 		return
 	}
+	if payload.ID == "" {
+		payload.ID = logging.NewID()
+	}
+	fields := payload.logFields()
+	ctx = logging.WithFields(ctx, fields)
+
+	if payload.Kind.patientFacing() && w.clinicInactive(ctx, fields.OrgID) {
+		w.log(ctx).Info("skipping conversation job: clinic inactive", "kind", payload.Kind)
+		outcome = jobOutcomeDropped
+		if payload.TrackStatus && w.jobs != nil {
+			if storeErr := w.jobs.MarkFailed(ctx, payload.ID, clinic.ErrInactive.Error()); storeErr != nil {
+				w.log(ctx).Error("failed to update job status", "error", storeErr)
+			}
+		}
+		w.deleteMessage(context.Background(), msg.ReceiptHandle)
+		return
+	}
+
+	if !w.coalesceInbound(ctx, msg, &payload) {
+		return
+	}
+
+	if !w.orgLimits.tryAcquire(fields.OrgID) {
+		w.deferJob(ctx, msg, payload)
+		return
+	}
+	defer w.orgLimits.release(fields.OrgID)
+	if payload.DeferredAt != nil {
+		concurrencyLimitDelay.WithLabelValues("org").Observe(time.Since(*payload.DeferredAt).Seconds())
+	}
+
+	lock, ok := w.lockConversation(ctx, msg, payload, fields.ConversationID)
+	if !ok {
+		return
+	}
+	if lock != nil {
+		defer lock.Release()
+		ctx = withConversationLock(ctx, lock)
+		stopHeartbeat := lock.heartbeat(ctx, func(err error) {
+			w.log(ctx).Warn("failed to extend conversation lock", "error", err)
+		})
+		defer stopHeartbeat()
+	}
 
 	// Debug logging to track job processing
-	w.logger.Info("worker processing job",
-		"job_id", payload.ID,
+	w.log(ctx).Info("worker processing job",
 		"kind", payload.Kind,
 		"msg_id", msg.ID,
 	)
 	if payload.Kind == jobTypeMessage {
-		w.logger.Info("worker job details",
-			"job_id", payload.ID,
-			"conversation_id", payload.Message.ConversationID,
+		w.log(ctx).Info("worker job details",
 			"message", payload.Message.Message,
 			"from", payload.Message.From,
 		)
@@ -38,12 +86,13 @@ func (w *Worker) handleMessage(ctx context.Context, msg queueMessage) {
 		if providerID != "" {
 			exists, err := w.msgChecker.HasProviderMessage(ctx, providerID)
 			if err != nil {
-				w.logger.Warn("provider message lookup failed", "error", err, "provider_message_id", providerID, "job_id", payload.ID)
+				w.log(ctx).Warn("provider message lookup failed", "error", err, "provider_message_id", providerID)
 			} else if !exists {
-				w.logger.Info("skipping conversation job: inbound message missing", "provider_message_id", providerID, "job_id", payload.ID)
+				w.log(ctx).Info("skipping conversation job: inbound message missing", "provider_message_id", providerID)
+				outcome = jobOutcomeDropped
 				if payload.TrackStatus && w.jobs != nil {
 					if storeErr := w.jobs.MarkFailed(ctx, payload.ID, "skipped: inbound message missing"); storeErr != nil {
-						w.logger.Error("failed to update job status", "error", storeErr, "job_id", payload.ID)
+						w.log(ctx).Error("failed to update job status", "error", storeErr)
 					}
 				}
 				w.deleteMessage(context.Background(), msg.ReceiptHandle)
@@ -53,36 +102,86 @@ func (w *Worker) handleMessage(ctx context.Context, msg queueMessage) {
 	}
 
 	var (
-		err  error
-		resp *Response
+		err   error
+		resp  *Response
+		timer *turnTimer
 	)
 	switch payload.Kind {
 	case jobTypeStart:
-		w.logger.Info("worker calling StartConversation", "job_id", payload.ID)
+		w.log(ctx).Info("worker calling StartConversation")
+		w.recordEngagement(ctx, payload.Start.LeadID, payload.Start.Intro)
 		resp, err = w.processor.StartConversation(ctx, payload.Start)
+		if err == nil {
+			w.notifyLeadCreated(ctx, payload.Start)
+		}
 	case jobTypeMessage:
-		resp, err = w.dispatchMessage(ctx, payload)
+		ctx, timer = withTurnTimer(ctx)
+		resp, err = w.dispatchMessage(ctx, &payload)
 	case jobTypePayment:
 		err = w.handlePaymentEvent(ctx, payload.Payment)
 	case jobTypePaymentFailed:
 		err = w.handlePaymentFailedEvent(ctx, payload.PaymentFailed)
+	case jobTypePaymentDisputed:
+		err = w.handlePaymentDisputedEvent(ctx, payload.PaymentDisputed)
+	case jobTypeRefreshAvailability:
+		resp, err = w.refreshAvailability(ctx, payload.Refresh)
+	case jobTypeAssistedBooking:
+		resp, err = w.handleAssistedBooking(ctx, payload.Assisted)
+	case jobTypeOfferSlot:
+		resp, err = w.offerManualSlot(ctx, payload.OfferSlot)
+	case jobTypeReengage:
+		err = w.sendReengagement(ctx, payload.Reengage, time.Now())
+	case jobTypePaymentClaimCheck:
+		err = w.recheckPaymentClaim(ctx, payload.ClaimCheck, time.Now())
+	case jobTypeVoicemail:
+		err = w.handleVoicemail(ctx, payload.Voicemail)
 	default:
 		err = fmt.Errorf("conversation: unknown job type %q", payload.Kind)
 	}
 
+	if lock != nil && lock.Lost() {
+		// Another worker took the conversation after this lock expired; its
+		// history is the one that counts, so drop this result unsent and hand
+		// the job to the next lock holder to answer.
+		w.log(ctx).Warn("conversation lock lost mid-job; requeueing job", "error", err)
+		w.requeueAfter(ctx, msg, payload, conversationLockRequeueDelay)
+		return
+	}
+
+	if err != nil && ctx.Err() != nil && w.stopping() {
+		// The drain deadline cut the job short; let the next worker retry it
+		// rather than failing it and texting the fallback reply.
+		w.requeueInterrupted(ctx, msg, payload)
+		return
+	}
+
+	outcome = jobOutcomeSuccess
+	if err != nil {
+		outcome = jobOutcomeError
+	}
 	w.finalizeJob(ctx, payload, resp, err)
+	w.rescoreLead(ctx, fields)
+	w.recordTurnLatency(ctx, payload, timer, started)
 	w.deleteMessage(context.Background(), msg.ReceiptHandle)
 }
 
-// dispatchMessage handles the jobTypeMessage case: voice callback check,
-// deposit preloading, progress callback setup, and LLM processing.
-func (w *Worker) dispatchMessage(ctx context.Context, payload queuePayload) (*Response, error) {
+// dispatchMessage handles the jobTypeMessage case: callback pause and
+// callback request checks, frustration handoff, payment claims, deposit
+// preloading, progress callback setup, and LLM processing.
+func (w *Worker) dispatchMessage(ctx context.Context, payload *queuePayload) (*Response, error) {
+	w.recordTransactionalConsent(ctx, payload.Message)
+	w.recordEngagement(ctx, payload.Message.LeadID, payload.Message.Message)
+	w.cancelReengagement(ctx, payload.Message.ConversationID)
+
+	// An open operator callback task pauses AI replies until it's resolved.
+	if w.callbackPaused(ctx, payload.Message) {
+		w.log(ctx).Info("awaiting operator callback, skipping LLM")
+		return nil, nil
+	}
+
 	// Check for voice callback request before LLM processing.
 	if w.handleCallbackRequest(ctx, payload.Message) {
-		w.logger.Info("voice callback handled, skipping LLM",
-			"job_id", payload.ID,
-			"conversation_id", payload.Message.ConversationID,
-		)
+		w.log(ctx).Info("voice callback handled, skipping LLM")
 		w.appendTranscript(ctx, payload.Message.ConversationID, SMSTranscriptMessage{
 			Role:      "user",
 			From:      payload.Message.From,
@@ -92,37 +191,86 @@ func (w *Worker) dispatchMessage(ctx context.Context, payload queuePayload) (*Re
 		})
 		if payload.TrackStatus {
 			if storeErr := w.jobs.MarkCompleted(ctx, payload.ID, nil, payload.Message.ConversationID); storeErr != nil {
-				w.logger.Error("failed to update job status", "error", storeErr, "job_id", payload.ID)
+				w.log(ctx).Error("failed to update job status", "error", storeErr)
 			}
 		}
 		return nil, nil
 	}
 
+	// Without an AI voice line, hand call requests to the clinic's operators.
+	if resp := w.openCallbackTask(ctx, payload.Message); resp != nil {
+		w.log(ctx).Info("callback task opened, skipping LLM")
+		return resp, nil
+	}
+
+	// Hand a conversation that keeps frustrating the patient to staff. A
+	// wrong number repeating itself isn't a patient to call back.
+	wrongNumber := w.isProbableWrongNumber(ctx, payload.Message)
+	if !wrongNumber {
+		if resp := w.checkFrustration(ctx, payload.Message); resp != nil {
+			w.log(ctx).Info("frustration threshold crossed, handing off to staff")
+			return resp, nil
+		}
+	}
+
+	// A patient saying they paid gets the deposit checked with the provider.
+	if resp := w.handlePaymentClaim(ctx, payload.Message); resp != nil {
+		w.log(ctx).Info("payment claim handled, skipping LLM")
+		return resp, nil
+	}
+
+	// Texted attachments are handled by the clinic's attachment rules.
+	if resp := w.handleAttachments(ctx, payload.Message); resp != nil {
+		w.log(ctx).Info("attachments handled, skipping LLM")
+		return resp, nil
+	}
+
 	// Pre-detect deposit intent and start parallel checkout generation.
 	if w.depositPreloader != nil && ShouldPreloadDeposit(payload.Message.Message) {
-		w.logger.Info("deposit preloader: detected potential deposit agreement, starting parallel generation",
-			"job_id", payload.ID,
-			"conversation_id", payload.Message.ConversationID,
-		)
+		w.log(ctx).Info("deposit preloader: detected potential deposit agreement, starting parallel generation")
 		w.depositPreloader.StartPreload(ctx, payload.Message.ConversationID, payload.Message.OrgID, payload.Message.LeadID, payload.Message.To)
 	}
 
 	// Set up progress callback to send intermediate SMS during long searches.
-	w.attachProgressCallback(&payload)
+	// Stopping before the reply is routed drops any update still held back.
+	progress := w.attachProgressCallback(payload)
 
-	w.logger.Info("worker calling ProcessMessage", "job_id", payload.ID, "conversation_id", payload.Message.ConversationID)
-	return w.processor.ProcessMessage(ctx, payload.Message)
+	w.log(ctx).Info("worker calling ProcessMessage")
+	resp, err := w.processor.ProcessMessage(ctx, payload.Message)
+	progress.Stop()
+	if err == nil && wrongNumber && !w.isProbableWrongNumber(ctx, payload.Message) {
+		// The patient re-engaged; operators hear about them now.
+		w.notifyLeadCreated(ctx, StartRequest{
+			OrgID:          payload.Message.OrgID,
+			LeadID:         payload.Message.LeadID,
+			Channel:        payload.Message.Channel,
+			From:           payload.Message.From,
+			To:             payload.Message.To,
+			ConversationID: payload.Message.ConversationID,
+		})
+	}
+	return resp, err
 }
 
 // attachProgressCallback wires a callback on the message payload that sends
-// intermediate SMS messages during long-running availability searches.
-func (w *Worker) attachProgressCallback(payload *queuePayload) {
-	progressSent := make(map[string]bool)
-	payload.Message.OnProgress = func(progressCtx context.Context, msg string) {
-		if w.messenger == nil || progressSent[msg] {
+// intermediate SMS messages during long-running availability searches. The
+// returned scheduler must be stopped once processing finishes. A job retried
+// after a shutdown sends no progress if an earlier attempt already did; the
+// first send sets payload.AckSent, which is safe to read once Stop returns.
+func (w *Worker) attachProgressCallback(payload *queuePayload) *progressScheduler {
+	var checked, resumed bool
+	progress := newProgressScheduler(w.cfg.progressInitialDelay, w.cfg.progressMinInterval, func(progressCtx context.Context, msg string) {
+		if w.messenger == nil {
+			return
+		}
+		if !checked {
+			checked = true
+			// Clinics that disabled acks get no progress texts either.
+			resumed = w.ackSentBefore(progressCtx, payload) || w.acksDisabled(progressCtx, payload.Message.OrgID)
+		}
+		if resumed {
 			return
 		}
-		progressSent[msg] = true
 		reply := OutboundReply{
 			OrgID:          payload.Message.OrgID,
 			LeadID:         payload.Message.LeadID,
@@ -134,7 +282,10 @@ func (w *Worker) attachProgressCallback(payload *queuePayload) {
 		sendCtx, cancel := context.WithTimeout(progressCtx, 5*time.Second)
 		defer cancel()
 		if err := w.messenger.SendReply(sendCtx, reply); err != nil {
-			w.logger.Warn("failed to send progress SMS", "error", err)
+			w.log(progressCtx).Warn("failed to send progress SMS", "error", err)
+		} else if !payload.AckSent {
+			payload.AckSent = true
+			w.recordAckSent(progressCtx, payload)
 		}
 		// Save progress messages to transcript so they appear in admin UI.
 		progressMsg := SMSTranscriptMessage{
@@ -143,6 +294,7 @@ func (w *Worker) attachProgressCallback(payload *queuePayload) {
 			From:      payload.Message.To,
 			To:        payload.Message.From,
 			Timestamp: time.Now(),
+			Kind:      "progress",
 		}
 		if w.transcript != nil {
 			_ = w.transcript.Append(progressCtx, payload.Message.ConversationID, progressMsg)
@@ -150,31 +302,41 @@ func (w *Worker) attachProgressCallback(payload *queuePayload) {
 		if w.convStore != nil {
 			_ = w.convStore.AppendMessage(progressCtx, payload.Message.ConversationID, progressMsg)
 		}
-	}
+	})
+	payload.Message.OnProgress = progress.Notify
+	return progress
+}
+
+// acksDisabled reports whether the clinic has turned off instant acks.
+func (w *Worker) acksDisabled(ctx context.Context, orgID string) bool {
+	cfg := w.clinicConfig(ctx, orgID)
+	return cfg != nil && cfg.DisableAcks
 }
 
 // finalizeJob handles post-processing after a job completes or fails:
 // status tracking, fallback replies on error, and response routing.
 func (w *Worker) finalizeJob(ctx context.Context, payload queuePayload, resp *Response, err error) {
 	if err != nil {
-		w.logger.Error("conversation job failed", "error", err, "job_id", payload.ID, "kind", payload.Kind)
+		w.log(ctx).Error("conversation job failed", "error", err, "kind", payload.Kind)
 		if payload.TrackStatus {
 			if storeErr := w.jobs.MarkFailed(ctx, payload.ID, err.Error()); storeErr != nil {
-				w.logger.Error("failed to update job status", "error", storeErr, "job_id", payload.ID)
+				w.log(ctx).Error("failed to update job status", "error", storeErr)
 			}
 		}
 		if payload.Kind == jobTypeMessage {
-			w.logger.Warn("sending fallback reply after conversation failure", "job_id", payload.ID, "org_id", payload.Message.OrgID)
+			w.log(ctx).Warn("sending fallback reply after conversation failure", "org_id", payload.Message.OrgID)
+			fallback := templates.Render(templates.ReplyProcessingFailed, nil, nil)
 			w.sendReply(ctx, payload, &Response{
 				ConversationID: payload.Message.ConversationID,
-				Message:        "Sorry - I'm having trouble responding right now. Please reply again in a moment.",
+				Message:        fallback.Body,
+				Template:       fallback.Ref,
 				Timestamp:      time.Now().UTC(),
 			})
 		}
 		return
 	}
 
-	w.logger.Debug("conversation job processed", "job_id", payload.ID, "kind", payload.Kind)
+	w.log(ctx).Debug("conversation job processed", "kind", payload.Kind)
 	var convID string
 	if resp != nil {
 		convID = resp.ConversationID
@@ -184,7 +346,7 @@ func (w *Worker) finalizeJob(ctx context.Context, payload queuePayload, resp *Re
 	}
 	if payload.TrackStatus {
 		if storeErr := w.jobs.MarkCompleted(ctx, payload.ID, resp, convID); storeErr != nil {
-			w.logger.Error("failed to update job status", "error", storeErr, "job_id", payload.ID)
+			w.log(ctx).Error("failed to update job status", "error", storeErr)
 		}
 	}
 
@@ -222,6 +384,43 @@ func (w *Worker) routeMessageResponse(ctx context.Context, payload queuePayload,
 	}
 }
 
+// deferJob puts a job that is over its org's concurrency limit back on the
+// queue after a short delay. The original message is only deleted once the
+// copy is sent, so a crash or failed send leaves it for redelivery.
+func (w *Worker) deferJob(ctx context.Context, msg queueMessage, payload queuePayload) {
+	if payload.DeferredAt == nil {
+		now := time.Now().UTC()
+		payload.DeferredAt = &now
+	}
+	concurrencyLimitedTotal.WithLabelValues("org").Inc()
+	w.log(ctx).Info("org at concurrency limit; requeueing job", "kind", payload.Kind, "delay", w.cfg.orgRequeueDelay)
+	w.requeueAfter(ctx, msg, payload, w.cfg.orgRequeueDelay)
+}
+
+// requeueAfter sends a copy of the job back to the queue after delay, or
+// immediately once shutdown starts, then deletes the original.
+func (w *Worker) requeueAfter(ctx context.Context, msg queueMessage, payload queuePayload, delay time.Duration) {
+	_, body, err := encodePayload(payload)
+	if err != nil {
+		w.log(ctx).Error("failed to encode deferred job", "error", err)
+		return
+	}
+
+	w.wg.Add(1)
+	go func() {
+		defer w.wg.Done()
+		timer := time.NewTimer(delay)
+		defer timer.Stop()
+		select {
+		case <-w.stopped:
+		case <-timer.C:
+		}
+		if err := w.requeue(msg, body); err != nil {
+			w.log(ctx).Error("failed to requeue deferred job", "error", err)
+		}
+	}()
+}
+
 // deleteMessage removes a processed message from the queue.
 func (w *Worker) deleteMessage(ctx context.Context, receiptHandle string) {
 	if receiptHandle == "" {
@@ -232,7 +431,7 @@ func (w *Worker) deleteMessage(ctx context.Context, receiptHandle string) {
 	defer cancel()
 
 	if err := w.queue.Delete(deleteCtx, receiptHandle); err != nil {
-		w.logger.Error("failed to delete conversation job", "error", err)
+		w.log(ctx).Error("failed to delete conversation job", "error", err)
 	}
 }
 
@@ -250,3 +449,61 @@ func providerMessageID(metadata map[string]string) string {
 	}
 	return ""
 }
+
+// logFields returns the correlation fields every log line for the job
+// carries. A request ID stamped by the inbound webhook is kept so the job can
+// be traced back to the request that queued it.
+func (p queuePayload) logFields() logging.Fields {
+	f := logging.Fields{JobID: p.ID}
+	switch p.Kind {
+	case jobTypeStart:
+		f.OrgID, f.LeadID, f.ConversationID = p.Start.OrgID, p.Start.LeadID, p.Start.ConversationID
+		f.RequestID = p.Start.Metadata[MetadataRequestID]
+	case jobTypeMessage:
+		f.OrgID, f.LeadID, f.ConversationID = p.Message.OrgID, p.Message.LeadID, p.Message.ConversationID
+		f.RequestID = p.Message.Metadata[MetadataRequestID]
+	case jobTypePayment:
+		if p.Payment != nil {
+			f.OrgID, f.LeadID = p.Payment.OrgID, p.Payment.LeadID
+			if p.Payment.LeadPhone != "" {
+				f.ConversationID = smsConversationID(p.Payment.OrgID, p.Payment.LeadPhone)
+			}
+		}
+	case jobTypePaymentFailed:
+		if p.PaymentFailed != nil {
+			f.OrgID, f.LeadID = p.PaymentFailed.OrgID, p.PaymentFailed.LeadID
+			if p.PaymentFailed.LeadPhone != "" {
+				f.ConversationID = smsConversationID(p.PaymentFailed.OrgID, p.PaymentFailed.LeadPhone)
+			}
+		}
+	case jobTypePaymentDisputed:
+		if p.PaymentDisputed != nil {
+			f.OrgID, f.LeadID = p.PaymentDisputed.OrgID, p.PaymentDisputed.LeadID
+		}
+	case jobTypeRefreshAvailability:
+		if p.Refresh != nil {
+			f.OrgID, f.LeadID, f.ConversationID = p.Refresh.OrgID, p.Refresh.LeadID, p.Refresh.ConversationID
+		}
+	case jobTypeAssistedBooking:
+		if p.Assisted != nil {
+			f.OrgID, f.ConversationID = p.Assisted.OrgID, p.Assisted.ConversationID
+		}
+	case jobTypeOfferSlot:
+		if p.OfferSlot != nil {
+			f.OrgID, f.LeadID, f.ConversationID = p.OfferSlot.OrgID, p.OfferSlot.LeadID, p.OfferSlot.ConversationID
+		}
+	case jobTypeReengage:
+		if p.Reengage != nil {
+			f.OrgID, f.LeadID, f.ConversationID = p.Reengage.OrgID, p.Reengage.LeadID, p.Reengage.ConversationID
+		}
+	case jobTypePaymentClaimCheck:
+		if p.ClaimCheck != nil {
+			f.OrgID, f.LeadID, f.ConversationID = p.ClaimCheck.OrgID, p.ClaimCheck.LeadID, p.ClaimCheck.ConversationID
+		}
+	case jobTypeVoicemail:
+		if p.Voicemail != nil {
+			f.OrgID, f.LeadID, f.ConversationID = p.Voicemail.OrgID, p.Voicemail.LeadID, p.Voicemail.ConversationID
+		}
+	}
+	return f
+}
@@ -0,0 +1,106 @@
+package conversation
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/clinic"
+	"github.com/wolfman30/medspa-ai-platform/internal/notify"
+)
+
+// FrustrationAuditor records frustration escalations in the compliance audit
+// trail.
+type FrustrationAuditor interface {
+	LogFrustrationEscalated(ctx context.Context, orgID, conversationID, leadID string, score float64, signals []string) error
+}
+
+// checkFrustration scores the inbound message and, the first time the
+// conversation's score crosses the threshold, hands it to staff: a
+// frustration callback task pauses AI replies, operators are notified, and
+// the patient gets one empathetic handoff reply. Returns nil when the
+// conversation should carry on with the AI.
+func (w *Worker) checkFrustration(ctx context.Context, msg MessageRequest) *Response {
+	if w == nil || w.frustration == nil || msg.Channel != ChannelSMS || strings.TrimSpace(msg.ConversationID) == "" {
+		return nil
+	}
+
+	var history []SMSTranscriptMessage
+	if w.transcript != nil {
+		var err error
+		if history, err = w.transcript.List(ctx, msg.ConversationID, frustrationHistoryLimit); err != nil {
+			w.log(ctx).Warn("frustration history lookup failed", "error", err)
+		}
+	}
+	result, err := w.frustration.Observe(ctx, msg.ConversationID, msg.Message, history)
+	if err != nil {
+		w.log(ctx).Warn("frustration scoring failed", "error", err)
+		return nil
+	}
+	if result.Message.Score > 0 {
+		w.events.Log(ctx, "frustration_scored", msg.ConversationID, msg.OrgID, msg.LeadID, map[string]any{
+			"score":   result.Message.Score,
+			"total":   result.Total,
+			"signals": result.Message.Signals,
+		})
+	}
+	if !result.Escalate {
+		return nil
+	}
+
+	now := time.Now().UTC()
+	task := &CallbackTask{
+		OrgID:          msg.OrgID,
+		ConversationID: msg.ConversationID,
+		LeadID:         msg.LeadID,
+		Phone:          msg.From,
+		RequestedAt:    now,
+		PatientMessage: msg.Message,
+		Reason:         CallbackTaskReasonFrustration,
+	}
+	escalation := map[string]any{
+		"total":   result.Total,
+		"signals": result.Message.Signals,
+	}
+	if w.callbackTasks != nil {
+		if err := w.callbackTasks.Create(ctx, task); err != nil {
+			w.log(ctx).Error("failed to create frustration callback task", "error", err)
+		} else {
+			escalation["task_id"] = task.ID.String()
+		}
+	}
+	w.events.Log(ctx, "frustration_escalated", msg.ConversationID, msg.OrgID, msg.LeadID, escalation)
+	if w.frustrationAudit != nil {
+		if err := w.frustrationAudit.LogFrustrationEscalated(ctx, msg.OrgID, msg.ConversationID, msg.LeadID, result.Total, result.Message.Signals); err != nil {
+			w.log(ctx).Warn("failed to audit frustration escalation", "error", err)
+		}
+	}
+	if w.callbackNotifier != nil {
+		if err := w.callbackNotifier.NotifyCallbackRequested(ctx, msg.OrgID, notify.CallbackRequest{
+			LeadID:      msg.LeadID,
+			Phone:       msg.From,
+			RequestedAt: now,
+			Reason:      notify.CallbackReasonFrustration,
+			Signals:     result.Message.Signals,
+		}); err != nil {
+			w.log(ctx).Error("failed to notify operators of frustrated patient", "error", err)
+		}
+	}
+
+	return &Response{
+		ConversationID: msg.ConversationID,
+		Message:        frustrationHandoff(w.clinicConfig(ctx, msg.OrgID), now),
+		Timestamp:      now,
+	}
+}
+
+// frustrationHandoff apologizes and tells the patient a person will take
+// over, based on the clinic's business hours.
+func frustrationHandoff(cfg *clinic.Config, now time.Time) string {
+	when := "shortly"
+	if cfg != nil {
+		when = cfg.ExpectedCallbackTime(now)
+	}
+	return fmt.Sprintf("I'm sorry this has been frustrating. I've asked a member of our team to take it from here, and they'll reach out %s.", when)
+}
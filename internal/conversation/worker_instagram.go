@@ -44,7 +44,7 @@ func (w *Worker) sendInstagramReply(ctx context.Context, payload queuePayload, r
 		for _, reason := range leakResult.Reasons {
 			w.events.OutputGuardTriggered(ctx, resp.ConversationID, msg.OrgID, reason)
 		}
-		w.logger.Warn("output guard: sensitive data leak detected (instagram)",
+		w.log(ctx).Warn("output guard: sensitive data leak detected (instagram)",
 			"conversation_id", resp.ConversationID,
 			"org_id", msg.OrgID,
 			"reasons", leakResult.Reasons,
@@ -89,11 +89,10 @@ func (w *Worker) sendInstagramReply(ctx context.Context, payload queuePayload, r
 
 		if err := w.igMessenger.SendReply(sendCtx, reply); err != nil {
 			sendErr = err
-			w.logger.Error("failed to send instagram reply", "error", err, "job_id", payload.ID, "org_id", msg.OrgID)
+			w.log(ctx).Error("failed to send instagram reply", "error", err, "org_id", msg.OrgID)
 		}
 	} else {
-		w.logger.Warn("instagram messenger not configured, cannot send reply",
-			"job_id", payload.ID,
+		w.log(ctx).Warn("instagram messenger not configured, cannot send reply",
 			"org_id", msg.OrgID,
 		)
 	}
@@ -0,0 +1,50 @@
+package conversation
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/leads"
+	"github.com/wolfman30/medspa-ai-platform/internal/tenancy"
+	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
+)
+
+// recordEngagement stamps the lead's latest inbound text and whether it asked
+// to be seen soon, the inputs to the recency and urgency score signals.
+func (w *Worker) recordEngagement(ctx context.Context, leadID, text string) {
+	if w == nil || strings.TrimSpace(leadID) == "" {
+		return
+	}
+	scores, ok := w.leadsRepo.(leads.ScoreRepository)
+	if !ok {
+		return
+	}
+	if err := scores.RecordEngagement(ctx, leadID, time.Now(), leads.ExpressesUrgency(text)); err != nil {
+		w.log(ctx).Warn("failed to record lead engagement", "error", err, "lead_id", leadID)
+	}
+}
+
+// rescoreLead recomputes the job's lead score once the job has run, since
+// any job touching a lead may have changed its qualification, deposit or
+// booking state. It reads the lead and clinic config only, so repeats are
+// cheap and give the same score.
+func (w *Worker) rescoreLead(ctx context.Context, fields logging.Fields) {
+	if w == nil || w.leadsRepo == nil || fields.OrgID == "" || fields.LeadID == "" {
+		return
+	}
+	scores, ok := w.leadsRepo.(leads.ScoreRepository)
+	if !ok {
+		return
+	}
+	lead, err := w.leadsRepo.GetByID(ctx, tenancy.ForOrg(fields.OrgID), fields.LeadID)
+	if err != nil || lead == nil {
+		w.log(ctx).Warn("failed to load lead for scoring", "error", err, "lead_id", fields.LeadID)
+		return
+	}
+	now := time.Now()
+	breakdown := leads.ComputeScore(lead, w.clinicConfig(ctx, fields.OrgID).ServicePriceCents(lead.ScoreService()), now)
+	if err := scores.SaveScore(ctx, lead.ID, breakdown, now); err != nil {
+		w.log(ctx).Warn("failed to save lead score", "error", err, "lead_id", lead.ID)
+	}
+}
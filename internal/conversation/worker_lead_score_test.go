@@ -0,0 +1,53 @@
+package conversation
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/clinic"
+	"github.com/wolfman30/medspa-ai-platform/internal/leads"
+	"github.com/wolfman30/medspa-ai-platform/internal/tenancy"
+	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
+)
+
+func TestHandleMessage_RescoresLead(t *testing.T) {
+	ctx := context.Background()
+	mr := miniredis.RunT(t)
+	clinicStore := clinic.NewStore(redis.NewClient(&redis.Options{Addr: mr.Addr()}))
+	cfg := clinic.DefaultConfig(uuid.NewString())
+	cfg.ServicePriceText = map[string]string{"filler": "$700 per syringe"}
+	if err := clinicStore.Set(ctx, cfg); err != nil {
+		t.Fatalf("save clinic config: %v", err)
+	}
+	repo := leads.NewInMemoryRepository()
+	lead, _ := repo.Create(ctx, &leads.CreateLeadRequest{OrgID: cfg.OrgID, Name: "Sarah", Phone: "+15550001111", Source: "sms"})
+	_ = repo.UpdateSchedulingPreferences(ctx, tenancy.ForOrg(lead.OrgID), lead.ID, leads.SchedulingPreferences{ServiceInterest: "Filler"})
+	_ = repo.UpdateDepositStatus(ctx, tenancy.ForOrg(lead.OrgID), lead.ID, "pending", "normal")
+
+	worker := NewWorker(&recordingService{}, newScriptedQueue(), &stubJobUpdater{}, &stubMessenger{}, nil, logging.Default(),
+		WithClinicConfigStore(clinicStore), WithWorkerLeadsRepo(repo))
+	body, _ := json.Marshal(queuePayload{ID: "job-1", Kind: jobTypeMessage, Message: MessageRequest{
+		ConversationID: "sms:" + cfg.OrgID + ":15550001111",
+		OrgID:          cfg.OrgID,
+		LeadID:         lead.ID,
+		Message:        "can I come in this week?",
+		Channel:        ChannelSMS,
+		From:           "+15550001111",
+		To:             "+15550002222",
+	}})
+	worker.handleMessage(ctx, queueMessage{ID: "msg-1", Body: string(body), ReceiptHandle: "rh-1"})
+
+	got, _ := repo.GetByID(ctx, tenancy.ForOrg(cfg.OrgID), lead.ID)
+	want := leads.ScoreBreakdown{ServiceValue: 17, Qualification: 10, Recency: 20, Urgency: 15, DepositPending: 15}
+	if got.ScoreBreakdown == nil || *got.ScoreBreakdown != want || got.Score != 77 {
+		t.Fatalf("lead score = %d %+v, want 77 %+v", got.Score, got.ScoreBreakdown, want)
+	}
+	if got.LastEngagedAt == nil || got.UrgencyExpressedAt == nil {
+		t.Fatalf("expected engagement and urgency recorded, got %+v", got)
+	}
+}
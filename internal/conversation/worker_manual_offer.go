@@ -0,0 +1,95 @@
+package conversation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/tenancy"
+)
+
+// manualOffer is a selected slot clinic staff offered outside the booking
+// platform's availability.
+type manualOffer struct {
+	ProviderID string
+}
+
+// offerManualSlot texts the patient a slot clinic staff made room for,
+// through the normal time-selection path.
+func (w *Worker) offerManualSlot(ctx context.Context, req *ManualSlotOfferRequest) (*Response, error) {
+	if req == nil {
+		return nil, errors.New("conversation: offer slot: missing request")
+	}
+	offerer, ok := w.processor.(ManualSlotOfferer)
+	if !ok {
+		return nil, errors.New("conversation: offer slot: processor does not support manual slot offers")
+	}
+
+	// Re-check state here: a deposit may have landed between the admin
+	// request and this job running.
+	if w.convStore != nil {
+		conv, err := w.convStore.GetConversation(ctx, tenancy.ForOrg(req.OrgID), req.ConversationID)
+		if err != nil {
+			return nil, fmt.Errorf("conversation: offer slot: %w", err)
+		}
+		if conv != nil && availabilityRefreshBlocked(conv.Status) {
+			return nil, ErrAvailabilityRefreshNotAllowed
+		}
+	}
+
+	tsr, err := offerer.OfferManualSlot(ctx, *req)
+	if err != nil {
+		return nil, err
+	}
+
+	msg := MessageRequest{
+		OrgID:          req.OrgID,
+		LeadID:         req.LeadID,
+		ConversationID: req.ConversationID,
+		From:           req.Phone,
+		To:             w.clinicNumberForConversation(ctx, req.OrgID, req.ConversationID),
+		Channel:        ChannelSMS,
+	}
+	resp := &Response{
+		ConversationID:        req.ConversationID,
+		Timestamp:             time.Now().UTC(),
+		TimeSelectionResponse: tsr,
+	}
+	w.handleTimeSelectionResponse(ctx, msg, resp)
+
+	w.log(ctx).Info("manual slot offer sent",
+		"org_id", req.OrgID,
+		"start", req.Start.Format(time.RFC3339),
+		"requested_by", req.RequestedBy,
+	)
+	return resp, nil
+}
+
+// selectedManualOffer returns the staff offer behind a paid slot, or nil
+// when the patient picked a time from the platform's availability.
+func (w *Worker) selectedManualOffer(ctx context.Context, orgID, leadPhone string) *manualOffer {
+	lookup, ok := w.processor.(ManualOfferLookup)
+	if !ok || leadPhone == "" {
+		return nil
+	}
+	providerID, ok := lookup.SelectedManualOffer(ctx, smsConversationID(orgID, leadPhone))
+	if !ok {
+		return nil
+	}
+	return &manualOffer{ProviderID: providerID}
+}
+
+// markBookingManuallyOffered flags the lead's latest booking so its failed
+// platform write-back reads as expected rather than as an incident.
+func (w *Worker) markBookingManuallyOffered(ctx context.Context, orgID, leadID uuid.UUID) {
+	marker, ok := w.bookings.(bookingManualOfferMarker)
+	if !ok {
+		return
+	}
+	if err := marker.MarkBookingManuallyOffered(ctx, orgID, leadID); err != nil {
+		w.log(ctx).Warn("failed to flag booking as manually offered", "error", err, "org_id", orgID, "lead_id", leadID)
+	}
+}
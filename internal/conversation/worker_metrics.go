@@ -0,0 +1,84 @@
+package conversation
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Job outcomes recorded on workerJobsProcessedTotal.
+const (
+	jobOutcomeSuccess = "success"
+	jobOutcomeError   = "error"
+	// jobOutcomeDeferred means the job was requeued or folded into another
+	// conversation job instead of being processed now.
+	jobOutcomeDeferred = "deferred"
+	// jobOutcomeDropped means the job was discarded unprocessed.
+	jobOutcomeDropped = "dropped"
+)
+
+var workerJobsProcessedTotal = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Namespace: "medspa",
+		Subsystem: "conversation",
+		Name:      "worker_jobs_processed_total",
+		Help:      "Conversation jobs handled by the worker, by kind and outcome",
+	},
+	[]string{"kind", "outcome"}, // outcome: success, error, deferred, dropped
+)
+
+var workerJobDuration = prometheus.NewHistogramVec(
+	prometheus.HistogramOpts{
+		Namespace: "medspa",
+		Subsystem: "conversation",
+		Name:      "worker_job_duration_seconds",
+		Help:      "Time the worker spent handling a conversation job",
+		Buckets:   []float64{0.1, 0.25, 0.5, 1, 2, 4, 6, 8, 10, 15, 20, 30, 60},
+	},
+	[]string{"kind"},
+)
+
+var workerQueueReceiveLatency = prometheus.NewHistogram(
+	prometheus.HistogramOpts{
+		Namespace: "medspa",
+		Subsystem: "conversation",
+		Name:      "worker_queue_receive_latency_seconds",
+		Help:      "Time a conversation job waited in the queue before a worker received it",
+		Buckets:   []float64{0.05, 0.1, 0.25, 0.5, 1, 2, 5, 10, 30, 60, 120, 300},
+	},
+)
+
+var availabilityFetchDuration = prometheus.NewHistogramVec(
+	prometheus.HistogramOpts{
+		Namespace: "medspa",
+		Subsystem: "conversation",
+		Name:      "availability_fetch_duration_seconds",
+		Help:      "Latency of availability fetches by source and outcome",
+		Buckets:   []float64{0.25, 0.5, 1, 2, 3, 5, 8, 10, 15, 20, 30},
+	},
+	[]string{"source", "outcome"},
+)
+
+func init() {
+	prometheus.MustRegister(workerJobsProcessedTotal, workerJobDuration, workerQueueReceiveLatency, availabilityFetchDuration)
+}
+
+// observeReceived records how long a message sat in the queue. Messages
+// without a send time (queues that don't report one) are skipped.
+func observeReceived(msg queueMessage, now time.Time) {
+	if msg.SentAt.IsZero() {
+		return
+	}
+	workerQueueReceiveLatency.Observe(max(now.Sub(msg.SentAt), 0).Seconds())
+}
+
+// recordJob records a handled job's outcome and duration. Jobs whose body
+// couldn't be decoded are recorded under kind "unknown".
+func recordJob(kind jobType, outcome string, started time.Time) {
+	label := string(kind)
+	if label == "" {
+		label = "unknown"
+	}
+	workerJobsProcessedTotal.WithLabelValues(label, outcome).Inc()
+	workerJobDuration.WithLabelValues(label).Observe(time.Since(started).Seconds())
+}
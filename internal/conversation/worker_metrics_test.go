@@ -0,0 +1,91 @@
+package conversation
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+
+	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
+)
+
+func TestRegisterMetrics_Idempotent(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	RegisterMetrics(reg)
+	RegisterMetrics(reg)
+
+	memoryQueueDepth.Set(0)
+	families, err := reg.Gather()
+	if err != nil {
+		t.Fatalf("Gather: %v", err)
+	}
+	found := false
+	for _, mf := range families {
+		if mf.GetName() == "medspa_conversation_memory_queue_depth" {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatalf("expected conversation metrics on the registry, got %d families", len(families))
+	}
+}
+
+func TestWorkerMetrics_ProcessedJob(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	RegisterMetrics(reg)
+
+	processed := func() float64 {
+		return testutil.ToFloat64(workerJobsProcessedTotal.WithLabelValues(string(jobTypeStart), jobOutcomeSuccess))
+	}
+	beforeProcessed := processed()
+	beforeDuration := histogramSamples(t, workerJobDuration.WithLabelValues(string(jobTypeStart)))
+	beforeReceive := histogramSamples(t, workerQueueReceiveLatency)
+
+	queue := newScriptedQueue()
+	store := &stubJobUpdater{}
+	worker := NewWorker(&recordingService{}, queue, store, nil, nil, logging.Default(), WithWorkerCount(1), WithReceiveBatchSize(1), WithReceiveWaitSeconds(0))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	worker.Start(ctx)
+
+	body, _ := json.Marshal(queuePayload{ID: "job-metrics", Kind: jobTypeStart, TrackStatus: true, Start: StartRequest{LeadID: "lead-1"}})
+	queue.enqueue(queueMessage{ID: "msg-metrics", Body: string(body), ReceiptHandle: "rh-metrics", SentAt: time.Now().Add(-2 * time.Second)})
+	waitFor(func() bool { return len(store.completedJobs()) == 1 }, time.Second, t)
+	cancel()
+	worker.Wait()
+
+	if got := processed() - beforeProcessed; got != 1 {
+		t.Errorf("jobs processed delta = %v, want 1", got)
+	}
+	if got := histogramSamples(t, workerJobDuration.WithLabelValues(string(jobTypeStart))) - beforeDuration; got != 1 {
+		t.Errorf("job duration samples delta = %d, want 1", got)
+	}
+	if got := histogramSamples(t, workerQueueReceiveLatency) - beforeReceive; got != 1 {
+		t.Errorf("queue receive latency samples delta = %d, want 1", got)
+	}
+
+	// The series is exposed on the custom registry.
+	families, err := reg.Gather()
+	if err != nil {
+		t.Fatalf("Gather: %v", err)
+	}
+	for _, mf := range families {
+		if mf.GetName() == "medspa_conversation_worker_jobs_processed_total" {
+			return
+		}
+	}
+	t.Fatalf("processed-job counter missing from the registry")
+}
+
+func histogramSamples(t *testing.T, obs prometheus.Observer) uint64 {
+	t.Helper()
+	var m dto.Metric
+	if err := obs.(prometheus.Metric).Write(&m); err != nil {
+		t.Fatalf("read histogram: %v", err)
+	}
+	return m.GetHistogram().GetSampleCount()
+}
@@ -0,0 +1,111 @@
+package conversation
+
+import (
+	"context"
+	"time"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/leads"
+	"github.com/wolfman30/medspa-ai-platform/internal/notify"
+	"github.com/wolfman30/medspa-ai-platform/internal/tenancy"
+)
+
+// notifyLeadCreated tells clinic operators and the clinic's CRM a new
+// conversation has started. Probable wrong numbers are held back until the
+// patient re-engages.
+func (w *Worker) notifyLeadCreated(ctx context.Context, req StartRequest) {
+	notifier, ok := w.notifier.(LeadNotifier)
+	if (!ok && w.crmEvents == nil) || req.OrgID == "" {
+		return
+	}
+	var lead *leads.Lead
+	if w.leadsRepo != nil && req.LeadID != "" {
+		if found, err := w.leadsRepo.GetByID(ctx, tenancy.ForOrg(req.OrgID), req.LeadID); err == nil {
+			lead = found
+		}
+	}
+	if lead == nil {
+		lead = &leads.Lead{ID: req.LeadID, OrgID: req.OrgID, Phone: req.From, Source: req.Source}
+	}
+	if lead.ProbableWrongNumber {
+		w.log(ctx).Info("skipping new lead notification for probable wrong number", "org_id", req.OrgID, "lead_id", req.LeadID)
+		return
+	}
+	if !w.claimLeadCreated(ctx, req.OrgID, req.LeadID) {
+		return
+	}
+	w.publishCRMEvent(ctx, leadCreatedCRMEvent(req, lead))
+	if !ok {
+		return
+	}
+	if err := notifier.NotifyNewLead(ctx, req.OrgID, lead); err != nil {
+		w.log(ctx).Error("failed to send new lead notification", "error", err, "org_id", req.OrgID, "lead_id", req.LeadID)
+	}
+}
+
+// claimLeadCreated reserves the one new-lead notification for a lead.
+// Start jobs run for returning patients too (voice, missed calls, web chat),
+// so only the first claim notifies. Without an idempotency store, or when the
+// claim can't be recorded, it notifies rather than risk missing a lead.
+func (w *Worker) claimLeadCreated(ctx context.Context, orgID, leadID string) bool {
+	if w.processed == nil || leadID == "" {
+		return true
+	}
+	first, err := w.processed.MarkProcessed(ctx, "conversation.lead_created", orgID+":"+leadID)
+	if err != nil {
+		w.log(ctx).Warn("failed to reserve new lead notification", "error", err, "org_id", orgID, "lead_id", leadID)
+		return true
+	}
+	if !first {
+		w.log(ctx).Info("new lead notification already sent; skipping", "org_id", orgID, "lead_id", leadID)
+	}
+	return first
+}
+
+// notifyBookingConfirmed tells clinic operators and the clinic's CRM an
+// appointment was booked on the clinic's platform. startTime is RFC3339;
+// unparsable values are omitted.
+func (w *Worker) notifyBookingConfirmed(ctx context.Context, orgID, leadID, phone, service, startTime, appointmentID string) {
+	booking := notify.BookingConfirmation{LeadID: leadID, Phone: phone, Service: service}
+	if t, err := time.Parse(time.RFC3339, startTime); err == nil {
+		booking.ScheduledFor = &t
+	}
+	w.publishCRMEvent(ctx, bookingConfirmedCRMEvent(orgID, leadID, appointmentID, service, booking.ScheduledFor))
+
+	notifier, ok := w.notifier.(BookingNotifier)
+	if !ok {
+		return
+	}
+	if err := notifier.NotifyBookingConfirmed(ctx, orgID, booking); err != nil {
+		w.log(ctx).Error("failed to send booking confirmation notification", "error", err, "org_id", orgID, "lead_id", leadID)
+	}
+}
+
+// moxieHandoffReason tells staff why a Moxie booking was handed to them.
+const moxieHandoffReason = "An earlier attempt may already have created this appointment and Moxie couldn't be checked. Look for it in Moxie and book it if it's missing."
+
+// notifyBookingHandoff tells clinic operators a booking was handed to them.
+// startTime is RFC3339; unparsable values are omitted.
+func (w *Worker) notifyBookingHandoff(ctx context.Context, orgID, leadID, phone, service, startTime, reason string) {
+	handoff := notify.BookingHandoff{LeadID: leadID, Phone: phone, Service: service, Reason: reason}
+	if t, err := time.Parse(time.RFC3339, startTime); err == nil {
+		handoff.ScheduledFor = &t
+	}
+	notifier, ok := w.notifier.(BookingHandoffNotifier)
+	if !ok {
+		w.log(ctx).Warn("booking handed off but no handoff notifier configured", "org_id", orgID, "lead_id", leadID)
+		return
+	}
+	if err := notifier.NotifyBookingHandoff(ctx, orgID, handoff); err != nil {
+		w.log(ctx).Error("failed to send booking handoff notification", "error", err, "org_id", orgID, "lead_id", leadID)
+	}
+}
+
+// isProbableWrongNumber reports whether the message's lead is flagged as
+// texting the wrong business.
+func (w *Worker) isProbableWrongNumber(ctx context.Context, msg MessageRequest) bool {
+	if w.leadsRepo == nil || msg.LeadID == "" {
+		return false
+	}
+	lead, err := w.leadsRepo.GetByID(ctx, tenancy.ForOrg(msg.OrgID), msg.LeadID)
+	return err == nil && lead != nil && lead.ProbableWrongNumber
+}
@@ -13,6 +13,8 @@ import (
 	moxieclient "github.com/wolfman30/medspa-ai-platform/internal/emr/moxie"
 	"github.com/wolfman30/medspa-ai-platform/internal/events"
 	"github.com/wolfman30/medspa-ai-platform/internal/leads"
+	"github.com/wolfman30/medspa-ai-platform/internal/messaging/templates"
+	"github.com/wolfman30/medspa-ai-platform/internal/tenancy"
 )
 
 func (w *Worker) handleDepositIntent(ctx context.Context, msg MessageRequest, resp *Response) {
@@ -29,8 +31,8 @@ func (w *Worker) handleDepositIntent(ctx context.Context, msg MessageRequest, re
 			if preloaded.Error == nil && preloaded.URL != "" {
 				resp.DepositIntent.PreloadedURL = preloaded.URL
 				resp.DepositIntent.PreloadedPaymentID = preloaded.PrePaymentID.String()
-				w.logger.Info("deposit: using preloaded checkout link",
-					"conversation_id", msg.ConversationID,
+				resp.DepositIntent.PreloadedOrderID = preloaded.OrderID
+				w.log(ctx).Info("deposit: using preloaded checkout link",
 					"preloaded_url", preloaded.URL[:min(50, len(preloaded.URL))]+"...",
 				)
 			}
@@ -39,7 +41,7 @@ func (w *Worker) handleDepositIntent(ctx context.Context, msg MessageRequest, re
 	}
 
 	if err := w.deposits.SendDeposit(ctx, msg, resp); err != nil {
-		w.logger.Error("failed to send deposit intent", "error", err, "org_id", msg.OrgID, "lead_id", msg.LeadID)
+		w.log(ctx).Error("failed to send deposit intent", "error", err, "org_id", msg.OrgID, "lead_id", msg.LeadID)
 	}
 }
 
@@ -57,9 +59,9 @@ func (w *Worker) handlePaymentEvent(ctx context.Context, evt *events.PaymentSucc
 	if w.processed != nil && idempotencyKey != "" {
 		already, err := w.processed.AlreadyProcessed(ctx, "conversation.payment_succeeded.v1", idempotencyKey)
 		if err != nil {
-			w.logger.Warn("failed to check payment event idempotency", "error", err, "key", idempotencyKey, "event_id", evt.EventID, "provider_ref", evt.ProviderRef, "org_id", evt.OrgID, "lead_id", evt.LeadID)
+			w.log(ctx).Warn("failed to check payment event idempotency", "error", err, "key", idempotencyKey, "event_id", evt.EventID, "provider_ref", evt.ProviderRef, "org_id", evt.OrgID, "lead_id", evt.LeadID)
 		} else if already {
-			w.logger.Info("skipping duplicate payment success event", "key", idempotencyKey, "event_id", evt.EventID, "provider_ref", evt.ProviderRef, "org_id", evt.OrgID, "lead_id", evt.LeadID)
+			w.log(ctx).Info("skipping duplicate payment success event", "key", idempotencyKey, "event_id", evt.EventID, "provider_ref", evt.ProviderRef, "org_id", evt.OrgID, "lead_id", evt.LeadID)
 			return nil
 		}
 	}
@@ -74,33 +76,53 @@ func (w *Worker) handlePaymentEvent(ctx context.Context, evt *events.PaymentSucc
 	if err != nil {
 		return fmt.Errorf("conversation: invalid lead id: %w", err)
 	}
-	if err := w.bookings.ConfirmBooking(ctx, orgID, leadID, evt.ScheduledFor); err != nil {
+	// A slot staff offered by hand was never in the platform's calendar, so
+	// re-checking it there would report it taken.
+	offer := w.selectedManualOffer(ctx, evt.OrgID, evt.LeadPhone)
+	if offer == nil && w.rebookIfPaidSlotTaken(ctx, evt) {
+		if w.processed != nil && idempotencyKey != "" {
+			if _, err := w.processed.MarkProcessed(ctx, "conversation.payment_succeeded.v1", idempotencyKey); err != nil {
+				w.log(ctx).Warn("failed to mark payment event processed", "error", err, "key", idempotencyKey, "event_id", evt.EventID, "provider_ref", evt.ProviderRef, "org_id", evt.OrgID, "lead_id", evt.LeadID)
+			}
+		}
+		return nil
+	}
+	cfg := w.clinicConfig(ctx, evt.OrgID)
+	duration := w.bookingDuration(ctx, evt, cfg)
+	if err := w.confirmBooking(ctx, orgID, leadID, evt.ScheduledFor, duration); err != nil {
 		return fmt.Errorf("conversation: confirm booking failed: %w", err)
 	}
+	if offer != nil {
+		w.markBookingManuallyOffered(ctx, orgID, leadID)
+	}
+	if recorder, ok := w.processor.(ExperimentStageRecorder); ok && evt.LeadPhone != "" {
+		recorder.RecordExperimentStage(ctx, smsConversationID(evt.OrgID, evt.LeadPhone), ExperimentStageDepositPaid)
+	}
 
 	// Notify clinic operators about the payment (non-blocking)
 	if w.notifier != nil {
 		if err := w.notifier.NotifyPaymentSuccess(ctx, *evt); err != nil {
-			w.logger.Error("failed to send payment notification to clinic", "error", err, "org_id", evt.OrgID, "lead_id", evt.LeadID)
+			w.log(ctx).Error("failed to send payment notification to clinic", "error", err, "org_id", evt.OrgID, "lead_id", evt.LeadID)
 			// Don't fail the payment flow if notification fails
 		}
 	}
+	w.publishCRMEvent(ctx, depositPaidCRMEvent(*evt))
 
 	// For Moxie+Stripe clinics: create the actual appointment on Moxie now that
 	// the deposit has been collected. This is the critical "Step 4b" — without it
 	// the patient pays but never gets booked.
-	cfg := w.clinicConfig(ctx, evt.OrgID)
 	moxieBooked := false
-	var moxieConfirmMsg string
-	if cfg != nil && cfg.UsesStripePayment() && cfg.UsesMoxieBooking() && w.moxieClient != nil && cfg.MoxieConfig != nil {
-		moxieBooked, moxieConfirmMsg = w.createMoxieBookingAfterPayment(ctx, evt, cfg)
+	var moxieConfirm templates.Rendered
+	if cfg != nil && cfg.UsesStripePayment() && cfg.UsesMoxieBooking() && w.moxieClient != nil && cfg.MoxieConfig != nil &&
+		w.featureEnabled(ctx, evt.OrgID, clinic.FlagMoxieAPIBooking) {
+		moxieBooked, moxieConfirm = w.createMoxieBookingAfterPayment(ctx, evt, cfg, offer)
 	}
 
 	if evt.LeadPhone != "" && evt.FromNumber != "" {
 		if !w.isOptedOut(ctx, evt.OrgID, evt.LeadPhone) {
-			var body string
-			if moxieBooked && moxieConfirmMsg != "" {
-				body = moxieConfirmMsg
+			var receipt templates.Rendered
+			if moxieBooked && moxieConfirm.Body != "" {
+				receipt = moxieConfirm
 			} else {
 				var clinicName, bookingURL, callbackTime, tz string
 				if cfg != nil {
@@ -119,7 +141,7 @@ func (w *Worker) handlePaymentEvent(ctx context.Context, evt *events.PaymentSucc
 						evt.ScheduledFor = &localTime
 					}
 				}
-				body = paymentConfirmationMessage(evt, clinicName, bookingURL, callbackTime)
+				receipt = paymentConfirmationMessage(evt, cfg, clinicName, bookingURL, callbackTime, duration)
 			}
 
 			if w.messenger == nil {
@@ -131,43 +153,51 @@ func (w *Worker) handlePaymentEvent(ctx context.Context, evt *events.PaymentSucc
 					ConversationID: smsConversationID(evt.OrgID, evt.LeadPhone),
 					To:             evt.LeadPhone,
 					From:           evt.FromNumber,
-					Body:           body,
-					Metadata: map[string]string{
+					Body:           receipt.Body,
+					Metadata: receipt.Stamp(map[string]string{
 						"event_id": evt.EventID,
-					},
+					}),
 				}
 				sendCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
 				defer cancel()
 				if err := w.messenger.SendReply(sendCtx, reply); err != nil {
-					w.logger.Error("failed to send booking confirmation sms", "error", err, "event_id", evt.EventID, "org_id", evt.OrgID)
+					w.log(ctx).Error("failed to send booking confirmation sms", "error", err, "event_id", evt.EventID, "org_id", evt.OrgID)
 				}
 			}
 
-			w.appendTranscript(context.Background(), smsConversationID(evt.OrgID, evt.LeadPhone), SMSTranscriptMessage{
-				Role: "assistant",
-				From: evt.FromNumber,
-				To:   evt.LeadPhone,
-				Body: body,
-				Kind: "payment_confirmation",
+			w.appendTranscript(context.WithoutCancel(ctx), smsConversationID(evt.OrgID, evt.LeadPhone), SMSTranscriptMessage{
+				Role:     "assistant",
+				From:     evt.FromNumber,
+				To:       evt.LeadPhone,
+				Body:     receipt.Body,
+				Kind:     "payment_confirmation",
+				Metadata: receipt.Stamp(nil),
+			})
+			w.askMarketingConsent(ctx, cfg, OutboundReply{
+				OrgID:          evt.OrgID,
+				LeadID:         evt.LeadID,
+				ConversationID: smsConversationID(evt.OrgID, evt.LeadPhone),
+				To:             evt.LeadPhone,
+				From:           evt.FromNumber,
 			})
 		}
 	}
 
 	// Update conversation status to deposit_paid
 	if w.convStore != nil && evt.LeadPhone != "" {
-		if err := w.convStore.UpdateStatusByPhone(ctx, evt.OrgID, evt.LeadPhone, "deposit_paid"); err != nil {
-			w.logger.Warn("failed to update conversation status to deposit_paid", "error", err, "org_id", evt.OrgID, "lead_phone", evt.LeadPhone)
+		if err := w.convStore.UpdateStatusByPhone(ctx, tenancy.ForOrg(evt.OrgID), evt.LeadPhone, "deposit_paid"); err != nil {
+			w.log(ctx).Warn("failed to update conversation status to deposit_paid", "error", err, "org_id", evt.OrgID, "lead_phone", evt.LeadPhone)
 		}
 	}
 
 	if w.processed != nil && idempotencyKey != "" {
 		if _, err := w.processed.MarkProcessed(ctx, "conversation.payment_succeeded.v1", idempotencyKey); err != nil {
-			w.logger.Warn("failed to mark payment event processed", "error", err, "key", idempotencyKey, "event_id", evt.EventID, "provider_ref", evt.ProviderRef, "org_id", evt.OrgID, "lead_id", evt.LeadID)
+			w.log(ctx).Warn("failed to mark payment event processed", "error", err, "key", idempotencyKey, "event_id", evt.EventID, "provider_ref", evt.ProviderRef, "org_id", evt.OrgID, "lead_id", evt.LeadID)
 		}
 	}
 	if w.autoPurge != nil {
 		if err := w.autoPurge.MaybePurgeAfterPayment(ctx, *evt); err != nil {
-			w.logger.Warn("sandbox auto purge hook failed", "error", err, "org_id", evt.OrgID, "lead_id", evt.LeadID, "provider_ref", evt.ProviderRef)
+			w.log(ctx).Warn("sandbox auto purge hook failed", "error", err, "org_id", evt.OrgID, "lead_id", evt.LeadID, "provider_ref", evt.ProviderRef)
 		}
 	}
 	return nil
@@ -176,33 +206,35 @@ func (w *Worker) handlePaymentEvent(ctx context.Context, evt *events.PaymentSucc
 // createMoxieBookingAfterPayment creates a Moxie appointment after Stripe deposit is collected.
 // Returns (booked, confirmationMessage). If booking fails, we still proceed with the
 // generic payment confirmation — the clinic can manually book the patient.
-
-func (w *Worker) createMoxieBookingAfterPayment(ctx context.Context, evt *events.PaymentSucceededV1, cfg *clinic.Config) (bool, string) {
+// A non-nil offer is a slot staff offered outside Moxie's availability; it
+// books with the staff-assigned provider, and a failure is expected rather
+// than an error.
+func (w *Worker) createMoxieBookingAfterPayment(ctx context.Context, evt *events.PaymentSucceededV1, cfg *clinic.Config, offer *manualOffer) (bool, templates.Rendered) {
 	mc := cfg.MoxieConfig
 	if mc == nil || mc.MedspaID == "" {
-		w.logger.Warn("moxie booking after payment skipped: no moxie config", "org_id", evt.OrgID)
-		return false, ""
+		w.log(ctx).Warn("moxie booking after payment skipped: no moxie config", "org_id", evt.OrgID)
+		return false, templates.Rendered{}
 	}
 
 	// Fetch lead to get selected appointment details
 	if w.leadsRepo == nil {
-		w.logger.Warn("moxie booking after payment skipped: no leads repo", "org_id", evt.OrgID)
-		return false, ""
+		w.log(ctx).Warn("moxie booking after payment skipped: no leads repo", "org_id", evt.OrgID)
+		return false, templates.Rendered{}
 	}
-	lead, err := w.leadsRepo.GetByID(ctx, evt.OrgID, evt.LeadID)
+	lead, err := w.leadsRepo.GetByID(ctx, tenancy.ForOrg(evt.OrgID), evt.LeadID)
 	if err != nil {
-		w.logger.Error("moxie booking after payment: lead fetch failed", "error", err,
+		w.log(ctx).Error("moxie booking after payment: lead fetch failed", "error", err,
 			"org_id", evt.OrgID, "lead_id", evt.LeadID)
-		return false, ""
+		return false, templates.Rendered{}
 	}
 
 	// The lead must have a selected appointment (date/time + service)
 	if lead.SelectedDateTime == nil {
 		// Fall back to evt.ScheduledFor if available
 		if evt.ScheduledFor == nil {
-			w.logger.Warn("moxie booking after payment skipped: no selected appointment time",
+			w.log(ctx).Warn("moxie booking after payment skipped: no selected appointment time",
 				"org_id", evt.OrgID, "lead_id", evt.LeadID)
-			return false, ""
+			return false, templates.Rendered{}
 		}
 		lead.SelectedDateTime = evt.ScheduledFor
 	}
@@ -212,25 +244,17 @@ func (w *Worker) createMoxieBookingAfterPayment(ctx context.Context, evt *events
 		service = lead.ServiceInterest
 	}
 	if service == "" {
-		w.logger.Warn("moxie booking after payment skipped: no service selected",
+		w.log(ctx).Warn("moxie booking after payment skipped: no service selected",
 			"org_id", evt.OrgID, "lead_id", evt.LeadID)
-		return false, ""
+		return false, templates.Rendered{}
 	}
 
 	// Resolve serviceMenuItemId
-	normalizedService := strings.ToLower(service)
-	serviceMenuItemID := ""
-	if mc.ServiceMenuItems != nil {
-		serviceMenuItemID = mc.ServiceMenuItems[normalizedService]
-		if serviceMenuItemID == "" {
-			resolved := cfg.ResolveServiceName(normalizedService)
-			serviceMenuItemID = mc.ServiceMenuItems[strings.ToLower(resolved)]
-		}
-	}
+	serviceMenuItemID := moxieServiceMenuItemID(cfg, service)
 	if serviceMenuItemID == "" {
-		w.logger.Error("moxie booking after payment: no serviceMenuItemId for service",
+		w.log(ctx).Error("moxie booking after payment: no serviceMenuItemId for service",
 			"service", service, "org_id", evt.OrgID, "lead_id", evt.LeadID)
-		return false, ""
+		return false, templates.Rendered{}
 	}
 
 	// Parse start/end times from the selected datetime
@@ -240,31 +264,50 @@ func (w *Worker) createMoxieBookingAfterPayment(ctx context.Context, evt *events
 	var endTimeUTC time.Time
 	if lead.SelectedEndDateTime != nil {
 		endTimeUTC = lead.SelectedEndDateTime.UTC()
+	} else if d := cfg.DurationForService(service); d > 0 {
+		endTimeUTC = lead.SelectedDateTime.Add(d).UTC()
 	} else {
 		endTimeUTC = lead.SelectedDateTime.Add(30 * time.Minute).UTC()
 	}
 	endTime := endTimeUTC.Format(time.RFC3339)
 
-	providerID := mc.DefaultProviderID
+	var providerID string
+	if offer != nil {
+		providerID = offer.ProviderID
+	}
+	if providerID == "" {
+		providerID = w.balancedProviderID(ctx, evt.OrgID, smsConversationID(evt.OrgID, evt.LeadPhone), *lead.SelectedDateTime)
+	}
+	if providerID == "" {
+		providerID = mc.DefaultProviderID
+	}
 	if providerID == "" {
-		providerID = "no-preference"
+		providerID = moxieNoPreferenceProvider
 	}
 
 	// Split name into first/last
 	firstName, lastName := splitName(lead.Name)
 
-	w.logger.Info("creating Moxie appointment after Stripe payment",
+	w.log(ctx).Info("creating Moxie appointment after Stripe payment",
 		"org_id", evt.OrgID, "lead_id", evt.LeadID,
 		"medspa_id", mc.MedspaID, "service", service,
 		"start_time", startTime)
 
-	result, err := w.moxieClient.CreateAppointment(ctx, moxieclient.CreateAppointmentRequest{
+	note := fmt.Sprintf("Deposit collected via Stripe (ref: %s)", evt.ProviderRef)
+	if offer != nil {
+		note = "Time offered by clinic staff; " + note
+	}
+	if quals := extraQualificationNote(lead.ExtraQualifications); quals != "" {
+		note += "; " + quals
+	}
+	key := bookingIdempotencyKey(evt.BookingIntentID, evt.OrgID, evt.LeadID, serviceMenuItemID, startTime)
+	result, err := w.writeBackMoxieAppointment(ctx, key, evt.OrgID, evt.LeadID, moxieclient.CreateAppointmentRequest{
 		MedspaID:  mc.MedspaID,
 		FirstName: firstName,
 		LastName:  lastName,
 		Email:     lead.Email,
 		Phone:     lead.Phone,
-		Note:      fmt.Sprintf("Deposit collected via Stripe (ref: %s)", evt.ProviderRef),
+		Note:      note,
 		Services: []moxieclient.ServiceInput{{
 			ServiceMenuItemID: serviceMenuItemID,
 			ProviderID:        providerID,
@@ -272,79 +315,128 @@ func (w *Worker) createMoxieBookingAfterPayment(ctx context.Context, evt *events
 			EndTime:           endTime,
 		}},
 		IsNewClient:              lead.PatientType != "existing",
-		NoPreferenceProviderUsed: providerID == "no-preference",
+		NoPreferenceProviderUsed: providerID == moxieNoPreferenceProvider,
 	})
+	if offer != nil && (err != nil || !result.OK) {
+		// The clinic already holds this time outside Moxie's calendar.
+		var reason string
+		if err != nil {
+			reason = err.Error()
+		} else {
+			reason = result.Message
+		}
+		w.log(ctx).Info("Moxie write-back skipped the manually offered slot; clinic books it directly",
+			"reason", reason, "org_id", evt.OrgID, "lead_id", evt.LeadID, "start_time", startTime)
+		return false, templates.Rendered{}
+	}
+	if errors.Is(err, errWritebackHandedOff) {
+		w.notifyBookingHandoff(ctx, evt.OrgID, evt.LeadID, evt.LeadPhone, service, startTime, moxieHandoffReason)
+		return false, templates.Rendered{}
+	}
 	if err != nil {
-		w.logger.Error("Moxie API create appointment after payment failed", "error", err,
+		w.log(ctx).Error("Moxie API create appointment after payment failed", "error", err,
 			"org_id", evt.OrgID, "lead_id", evt.LeadID)
-		return false, ""
+		return false, templates.Rendered{}
 	}
 	if !result.OK {
-		w.logger.Error("Moxie appointment creation after payment returned not OK",
+		w.log(ctx).Error("Moxie appointment creation after payment returned not OK",
 			"message", result.Message, "org_id", evt.OrgID, "lead_id", evt.LeadID)
-		return false, ""
+		return false, templates.Rendered{}
 	}
 
-	w.logger.Info("Moxie appointment created successfully after Stripe payment",
+	w.log(ctx).Info("Moxie appointment created successfully after Stripe payment",
 		"appointment_id", result.AppointmentID,
 		"org_id", evt.OrgID, "lead_id", evt.LeadID,
-		"service", service)
+		"service", service, "provider_id", providerID)
+	w.recordBookingProvider(ctx, evt.OrgID, evt.LeadID, providerID)
 
 	// Update conversation status to booked
 	if w.convStore != nil {
-		if err := w.convStore.UpdateStatusByPhone(ctx, evt.OrgID, evt.LeadPhone, StatusBooked); err != nil {
-			w.logger.Warn("failed to update conversation status to booked", "error", err, "org_id", evt.OrgID, "lead_phone", evt.LeadPhone)
+		if err := w.convStore.UpdateStatusByPhone(ctx, tenancy.ForOrg(evt.OrgID), evt.LeadPhone, StatusBooked); err != nil {
+			w.log(ctx).Warn("failed to update conversation status to booked", "error", err, "org_id", evt.OrgID, "lead_phone", evt.LeadPhone)
 		}
 	}
+	w.notifyBookingConfirmed(ctx, evt.OrgID, evt.LeadID, evt.LeadPhone, service, startTime, result.AppointmentID)
 
 	// Update lead with booking session info
 	now := time.Now()
-	if err := w.leadsRepo.UpdateBookingSession(ctx, evt.LeadID, leads.BookingSessionUpdate{
+	if err := w.leadsRepo.UpdateBookingSession(ctx, tenancy.ForOrg(evt.OrgID), evt.LeadID, leads.BookingSessionUpdate{
 		SessionID:   result.AppointmentID,
 		Platform:    "moxie",
 		Outcome:     "success",
 		CompletedAt: &now,
 	}); err != nil {
-		w.logger.Warn("failed to update lead with Moxie appointment after payment",
+		w.log(ctx).Warn("failed to update lead with Moxie appointment after payment",
 			"error", err, "lead_id", evt.LeadID, "appointment_id", result.AppointmentID)
 	}
 
-	// Build confirmation message using centralized formatter
-	confirmMsg := FormatAppointmentConfirmation(service, localTime, cfg.Name)
+	return true, renderAppointmentConfirmation(cfg, service, localTime, endTimeUTC.In(loc), cfg.Name)
+}
+
+// bookingDuration returns the paid appointment's length: the selected slot's
+// own span when the lead has one, else the clinic's configured duration for
+// the service. Zero means unknown.
+func (w *Worker) bookingDuration(ctx context.Context, evt *events.PaymentSucceededV1, cfg *clinic.Config) time.Duration {
+	service := evt.ServiceName
+	if w.leadsRepo != nil {
+		if lead, err := w.leadsRepo.GetByID(ctx, tenancy.ForOrg(evt.OrgID), evt.LeadID); err == nil && lead != nil {
+			if lead.SelectedDateTime != nil && lead.SelectedEndDateTime != nil {
+				if d := lead.SelectedEndDateTime.Sub(*lead.SelectedDateTime); d > 0 {
+					return d
+				}
+			}
+			if service == "" {
+				service = lead.SelectedService
+			}
+			if service == "" {
+				service = lead.ServiceInterest
+			}
+		}
+	}
+	return cfg.DurationForService(service)
+}
 
-	return true, confirmMsg
+// confirmBooking records the booking, with its duration when the confirmer
+// supports it.
+func (w *Worker) confirmBooking(ctx context.Context, orgID, leadID uuid.UUID, scheduledFor *time.Time, duration time.Duration) error {
+	if c, ok := w.bookings.(bookingDurationConfirmer); ok {
+		return c.ConfirmBookingWithDuration(ctx, orgID, leadID, scheduledFor, int(duration/time.Minute))
+	}
+	return w.bookings.ConfirmBooking(ctx, orgID, leadID, scheduledFor)
 }
 
-func paymentConfirmationMessage(evt *events.PaymentSucceededV1, clinicName, bookingURL, callbackTime string) string {
+// paymentConfirmationMessage builds the patient's deposit receipt. A non-zero
+// duration adds the appointment's end time.
+func paymentConfirmationMessage(evt *events.PaymentSucceededV1, cfg *clinic.Config, clinicName, bookingURL, callbackTime string, duration time.Duration) templates.Rendered {
 	if evt == nil {
-		return ""
+		return templates.Rendered{}
 	}
 	name := strings.TrimSpace(clinicName)
-	bookingURL = strings.TrimSpace(bookingURL)
 	callbackTime = strings.TrimSpace(callbackTime)
 	if callbackTime == "" {
 		callbackTime = "within 24 hours"
 	}
 
-	cancellationPolicy := "\n\nReminder: There is a 24-hour cancellation policy. Cancellations made less than 24 hours before your appointment are non-refundable."
-
 	if evt.ScheduledFor != nil {
 		tzAbbrev := evt.ScheduledFor.Format("MST")
 		date := evt.ScheduledFor.Format("Monday, January 2 at 3:04 PM") + " " + tzAbbrev
+		if duration > 0 {
+			date = FormatAppointmentWindow(*evt.ScheduledFor, evt.ScheduledFor.Add(duration)) + " " + tzAbbrev
+		}
 		service := evt.ServiceName
 		if service == "" {
 			service = "your appointment"
 		}
 		if name != "" {
-			return fmt.Sprintf("Payment received! Your %s appointment at %s on %s is confirmed.%s", service, name, date, cancellationPolicy)
+			return cfg.RenderTemplate(templates.PaymentReceivedAtClinic, templates.Params{"service": service, "clinic_name": name, "date": date})
 		}
-		return fmt.Sprintf("Payment received! Your %s appointment on %s is confirmed.%s", service, date, cancellationPolicy)
+		return cfg.RenderTemplate(templates.PaymentReceived, templates.Params{"service": service, "date": date})
 	}
-	amount := float64(evt.AmountCents) / 100
+	amount := fmt.Sprintf("$%.2f", float64(evt.AmountCents)/100)
 	if name != "" {
-		return fmt.Sprintf("Payment of $%.2f received - thank you! A %s team member will call you %s to confirm your appointment.%s", amount, name, callbackTime, cancellationPolicy)
+		return cfg.RenderTemplate(templates.PaymentReceivedCallbackNamed, templates.Params{"amount": amount, "clinic_name": name, "callback_time": callbackTime})
 	}
-	return fmt.Sprintf("Payment of $%.2f received - thank you! Our team will call you %s to confirm your appointment.%s", amount, callbackTime, cancellationPolicy)
+	return cfg.RenderTemplate(templates.PaymentReceivedCallback, templates.Params{"amount": amount, "callback_time": callbackTime})
 }
 
 func (w *Worker) handlePaymentFailedEvent(ctx context.Context, evt *events.PaymentFailedV1) error {
@@ -361,38 +453,95 @@ func (w *Worker) handlePaymentFailedEvent(ctx context.Context, evt *events.Payme
 	if w.processed != nil && idempotencyKey != "" {
 		already, err := w.processed.AlreadyProcessed(ctx, "conversation.payment_failed.v1", idempotencyKey)
 		if err != nil {
-			w.logger.Warn("failed to check payment failed event idempotency", "error", err, "key", idempotencyKey, "event_id", evt.EventID, "provider_ref", evt.ProviderRef, "org_id", evt.OrgID, "lead_id", evt.LeadID)
+			w.log(ctx).Warn("failed to check payment failed event idempotency", "error", err, "key", idempotencyKey, "event_id", evt.EventID, "provider_ref", evt.ProviderRef, "org_id", evt.OrgID, "lead_id", evt.LeadID)
 		} else if already {
-			w.logger.Info("skipping duplicate payment failed event", "key", idempotencyKey, "event_id", evt.EventID, "provider_ref", evt.ProviderRef, "org_id", evt.OrgID, "lead_id", evt.LeadID)
+			w.log(ctx).Info("skipping duplicate payment failed event", "key", idempotencyKey, "event_id", evt.EventID, "provider_ref", evt.ProviderRef, "org_id", evt.OrgID, "lead_id", evt.LeadID)
 			return nil
 		}
 	}
 
 	if w.messenger != nil && evt.LeadPhone != "" && evt.FromNumber != "" {
-		if !w.isOptedOut(ctx, evt.OrgID, evt.LeadPhone) {
-			body := "Payment failed - we didn't receive your deposit. If you'd still like to book, please reply and we can send a new secure payment link. Our team can also help by phone."
+		if !w.isOptedOut(ctx, evt.OrgID, evt.LeadPhone) && !w.sendPaymentRetryLink(ctx, evt) {
+			notice := w.clinicConfig(ctx, evt.OrgID).RenderTemplate(templates.PaymentFailed, nil)
 			reply := OutboundReply{
 				OrgID:          evt.OrgID,
 				LeadID:         evt.LeadID,
 				ConversationID: smsConversationID(evt.OrgID, evt.LeadPhone),
 				To:             evt.LeadPhone,
 				From:           evt.FromNumber,
-				Body:           body,
-				Metadata: map[string]string{
+				Body:           notice.Body,
+				Metadata: notice.Stamp(map[string]string{
 					"event_id": evt.EventID,
-				},
+				}),
 			}
 			sendCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
 			defer cancel()
 			if err := w.messenger.SendReply(sendCtx, reply); err != nil {
-				w.logger.Error("failed to send payment failed sms", "error", err, "event_id", evt.EventID, "org_id", evt.OrgID)
+				w.log(ctx).Error("failed to send payment failed sms", "error", err, "event_id", evt.EventID, "org_id", evt.OrgID)
 			}
 		}
 	}
 	if w.processed != nil && idempotencyKey != "" {
 		if _, err := w.processed.MarkProcessed(ctx, "conversation.payment_failed.v1", idempotencyKey); err != nil {
-			w.logger.Warn("failed to mark payment failed event processed", "error", err, "key", idempotencyKey, "event_id", evt.EventID, "provider_ref", evt.ProviderRef, "org_id", evt.OrgID, "lead_id", evt.LeadID)
+			w.log(ctx).Warn("failed to mark payment failed event processed", "error", err, "key", idempotencyKey, "event_id", evt.EventID, "provider_ref", evt.ProviderRef, "org_id", evt.OrgID, "lead_id", evt.LeadID)
 		}
 	}
 	return nil
 }
+
+// sendPaymentRetryLink sends a fresh deposit link after a declined payment.
+// Each lead gets at most one automatic retry; later declines fall back to the
+// plain failure notice. Returns true when the new link went out.
+func (w *Worker) sendPaymentRetryLink(ctx context.Context, evt *events.PaymentFailedV1) bool {
+	if w.deposits == nil || w.processed == nil || evt.OrgID == "" || evt.LeadID == "" || evt.AmountCents <= 0 {
+		return false
+	}
+	first, err := w.processed.MarkProcessed(ctx, "conversation.payment_retry_link", evt.OrgID+":"+evt.LeadID)
+	if err != nil {
+		w.log(ctx).Warn("failed to reserve payment retry link", "error", err, "org_id", evt.OrgID, "lead_id", evt.LeadID)
+		return false
+	}
+	if !first {
+		w.log(ctx).Info("payment retry link already sent; not sending another", "org_id", evt.OrgID, "lead_id", evt.LeadID)
+		return false
+	}
+
+	conversationID := smsConversationID(evt.OrgID, evt.LeadPhone)
+	intro := w.clinicConfig(ctx, evt.OrgID).RenderTemplate(templates.PaymentRetryIntro, nil)
+	sendCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	if err := w.messenger.SendReply(sendCtx, OutboundReply{
+		OrgID:          evt.OrgID,
+		LeadID:         evt.LeadID,
+		ConversationID: conversationID,
+		To:             evt.LeadPhone,
+		From:           evt.FromNumber,
+		Body:           intro.Body,
+		Metadata:       intro.Stamp(map[string]string{"event_id": evt.EventID}),
+	}); err != nil {
+		w.log(ctx).Error("failed to send payment retry intro", "error", err, "event_id", evt.EventID, "org_id", evt.OrgID)
+		return false
+	}
+
+	err = w.deposits.SendDeposit(ctx, MessageRequest{
+		OrgID:          evt.OrgID,
+		LeadID:         evt.LeadID,
+		ConversationID: conversationID,
+		Channel:        ChannelSMS,
+		From:           evt.LeadPhone,
+		To:             evt.FromNumber,
+	}, &Response{
+		ConversationID: conversationID,
+		DepositIntent: &DepositIntent{
+			AmountCents:  int32(evt.AmountCents),
+			Description:  "Appointment deposit",
+			ScheduledFor: evt.ScheduledFor,
+		},
+	})
+	if err != nil {
+		w.log(ctx).Error("failed to send payment retry link", "error", err, "event_id", evt.EventID, "org_id", evt.OrgID, "lead_id", evt.LeadID)
+		return false
+	}
+	w.log(ctx).Info("payment retry link sent", "event_id", evt.EventID, "org_id", evt.OrgID, "lead_id", evt.LeadID)
+	return true
+}
@@ -0,0 +1,63 @@
+package conversation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/events"
+)
+
+// handlePaymentDisputedEvent flags the lead's bookings and alerts clinic
+// operators about a chargeback. The patient is not messaged; disputes are
+// handled by staff.
+func (w *Worker) handlePaymentDisputedEvent(ctx context.Context, evt *events.PaymentDisputedV1) error {
+	if evt == nil {
+		return errors.New("conversation: missing payment disputed payload")
+	}
+	idempotencyKey := strings.TrimSpace(evt.DisputeID)
+	if idempotencyKey == "" {
+		idempotencyKey = strings.TrimSpace(evt.EventID)
+	}
+	if w.processed != nil && idempotencyKey != "" {
+		already, err := w.processed.AlreadyProcessed(ctx, "conversation.payment_disputed.v1", idempotencyKey)
+		if err != nil {
+			w.log(ctx).Warn("failed to check payment disputed event idempotency", "error", err, "key", idempotencyKey, "org_id", evt.OrgID, "lead_id", evt.LeadID)
+		} else if already {
+			w.log(ctx).Info("skipping duplicate payment disputed event", "key", idempotencyKey, "org_id", evt.OrgID, "lead_id", evt.LeadID)
+			return nil
+		}
+	}
+
+	if flagger, ok := w.bookings.(bookingDisputeFlagger); ok && flagger != nil {
+		orgID, err := uuid.Parse(evt.OrgID)
+		if err != nil {
+			return fmt.Errorf("conversation: invalid org id: %w", err)
+		}
+		leadID, err := uuid.Parse(evt.LeadID)
+		if err != nil {
+			return fmt.Errorf("conversation: invalid lead id: %w", err)
+		}
+		if _, err := flagger.FlagDisputed(ctx, orgID, leadID); err != nil {
+			return fmt.Errorf("conversation: flag disputed booking: %w", err)
+		}
+	}
+
+	if notifier, ok := w.notifier.(DisputeNotifier); ok {
+		if err := notifier.NotifyPaymentDisputed(ctx, *evt); err != nil {
+			w.log(ctx).Error("failed to notify clinic about payment dispute", "error", err, "org_id", evt.OrgID, "lead_id", evt.LeadID, "dispute_id", evt.DisputeID)
+		}
+	} else {
+		w.log(ctx).Warn("payment disputed but no dispute notifier configured", "org_id", evt.OrgID, "lead_id", evt.LeadID, "dispute_id", evt.DisputeID)
+	}
+
+	if w.processed != nil && idempotencyKey != "" {
+		if _, err := w.processed.MarkProcessed(ctx, "conversation.payment_disputed.v1", idempotencyKey); err != nil {
+			w.log(ctx).Warn("failed to mark payment disputed event processed", "error", err, "key", idempotencyKey, "org_id", evt.OrgID, "lead_id", evt.LeadID)
+		}
+	}
+	return nil
+}
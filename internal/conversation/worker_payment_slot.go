@@ -0,0 +1,90 @@
+package conversation
+
+import (
+	"context"
+	"time"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/events"
+	"github.com/wolfman30/medspa-ai-platform/internal/notify"
+	"github.com/wolfman30/medspa-ai-platform/internal/tenancy"
+)
+
+// rebookIfPaidSlotTaken re-checks the paid slot before the booking is
+// confirmed. If it was taken during checkout, the patient is texted nearby
+// alternatives, the deposit carries over to their next pick, and operators
+// are alerted. Returns true when the normal confirmation must be skipped; a
+// failed check falls through to it.
+func (w *Worker) rebookIfPaidSlotTaken(ctx context.Context, evt *events.PaymentSucceededV1) bool {
+	verifier, ok := w.processor.(PaidSlotVerifier)
+	if !ok || evt.LeadPhone == "" {
+		return false
+	}
+	conversationID := smsConversationID(evt.OrgID, evt.LeadPhone)
+	result, err := verifier.VerifyPaidSlot(ctx, PaidSlotVerificationRequest{
+		OrgID:          evt.OrgID,
+		LeadID:         evt.LeadID,
+		ConversationID: conversationID,
+		Phone:          evt.LeadPhone,
+		ScheduledFor:   evt.ScheduledFor,
+	})
+	if err != nil {
+		w.log(ctx).Warn("paid slot re-check failed; confirming booking as selected", "error", err, "org_id", evt.OrgID, "lead_id", evt.LeadID)
+		return false
+	}
+	if result == nil || result.Available || result.Alternatives == nil {
+		return false
+	}
+
+	if w.leadsRepo != nil {
+		if err := w.leadsRepo.UpdateDepositStatus(ctx, tenancy.ForOrg(evt.OrgID), evt.LeadID, DepositStatusAppliedToNextSelection, "priority"); err != nil {
+			w.log(ctx).Warn("failed to mark deposit as applied to next selection", "error", err, "org_id", evt.OrgID, "lead_id", evt.LeadID)
+		}
+		if err := w.leadsRepo.ClearSelectedAppointment(ctx, evt.LeadID); err != nil {
+			w.log(ctx).Warn("failed to clear taken slot from lead", "error", err, "org_id", evt.OrgID, "lead_id", evt.LeadID)
+		}
+	}
+
+	clinicNumber := evt.FromNumber
+	if clinicNumber == "" {
+		if cfg := w.clinicConfig(ctx, evt.OrgID); cfg != nil {
+			clinicNumber = cfg.SMSPhoneNumber
+		}
+	}
+	msg := MessageRequest{
+		OrgID:          evt.OrgID,
+		LeadID:         evt.LeadID,
+		ConversationID: conversationID,
+		From:           evt.LeadPhone,
+		To:             clinicNumber,
+		Channel:        ChannelSMS,
+	}
+	w.handleTimeSelectionResponse(ctx, msg, &Response{
+		ConversationID:        conversationID,
+		Timestamp:             time.Now().UTC(),
+		TimeSelectionResponse: result.Alternatives,
+	})
+
+	if notifier, ok := w.notifier.(SlotConflictNotifier); ok {
+		slot := result.Slot
+		if err := notifier.NotifySlotConflict(ctx, evt.OrgID, notify.SlotConflict{
+			LeadID:              evt.LeadID,
+			Phone:               evt.LeadPhone,
+			Service:             result.Service,
+			ScheduledFor:        &slot,
+			AmountCents:         evt.AmountCents,
+			AlternativesOffered: len(result.Alternatives.Slots),
+		}); err != nil {
+			w.log(ctx).Error("failed to notify clinic about taken paid slot", "error", err, "org_id", evt.OrgID, "lead_id", evt.LeadID)
+		}
+	} else {
+		w.log(ctx).Warn("paid slot taken but no slot conflict notifier configured", "org_id", evt.OrgID, "lead_id", evt.LeadID)
+	}
+
+	w.log(ctx).Info("paid slot taken; deposit applied to next selection",
+		"org_id", evt.OrgID,
+		"lead_id", evt.LeadID,
+		"slot", result.Slot,
+		"alternatives", len(result.Alternatives.Slots),
+	)
+	return true
+}
@@ -0,0 +1,108 @@
+package conversation
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+const (
+	// defaultProgressInitialDelay holds back the first progress SMS so fast
+	// searches reply with slots only.
+	defaultProgressInitialDelay = 3 * time.Second
+	// defaultProgressMinInterval is the minimum gap between progress SMS.
+	defaultProgressMinInterval = 10 * time.Second
+)
+
+// progressScheduler gates the intermediate "still searching" messages a job
+// emits through MessageRequest.OnProgress:
+//   - nothing is sent until initialDelay has passed since the first update,
+//     so a search that finishes quickly sends no progress at all;
+//   - at most one update is sent per minInterval; a newer update replaces an
+//     older one that is still waiting;
+//   - identical messages are sent once;
+//   - Stop drops anything still waiting, so progress never follows the reply.
+type progressScheduler struct {
+	send         func(ctx context.Context, msg string)
+	initialDelay time.Duration
+	minInterval  time.Duration
+
+	// sendMu serializes sends and lets Stop wait for one in flight; mu
+	// guards the state below and is never held during a send.
+	sendMu     sync.Mutex
+	mu         sync.Mutex
+	firstAt    time.Time
+	lastSentAt time.Time
+	sent       map[string]bool
+	pending    string
+	pendingCtx context.Context
+	timer      *time.Timer
+	stopped    bool
+}
+
+func newProgressScheduler(initialDelay, minInterval time.Duration, send func(ctx context.Context, msg string)) *progressScheduler {
+	return &progressScheduler{
+		send:         send,
+		initialDelay: initialDelay,
+		minInterval:  minInterval,
+		sent:         make(map[string]bool),
+	}
+}
+
+// Notify queues msg for delivery according to the gating rules.
+func (p *progressScheduler) Notify(ctx context.Context, msg string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.stopped || msg == "" || p.sent[msg] || msg == p.pending {
+		return
+	}
+
+	now := time.Now()
+	if p.firstAt.IsZero() {
+		p.firstAt = now
+	}
+	p.pending = msg
+	p.pendingCtx = ctx
+
+	due := p.firstAt.Add(p.initialDelay)
+	if !p.lastSentAt.IsZero() {
+		if next := p.lastSentAt.Add(p.minInterval); next.After(due) {
+			due = next
+		}
+	}
+	if p.timer != nil {
+		p.timer.Stop()
+	}
+	p.timer = time.AfterFunc(due.Sub(now), p.flush)
+}
+
+// flush sends the pending message. Notify isn't blocked by the send; Stop
+// waits for it.
+func (p *progressScheduler) flush() {
+	p.sendMu.Lock()
+	defer p.sendMu.Unlock()
+	p.mu.Lock()
+	if p.stopped || p.pending == "" {
+		p.mu.Unlock()
+		return
+	}
+	msg, ctx := p.pending, p.pendingCtx
+	p.pending, p.pendingCtx = "", nil
+	p.sent[msg] = true
+	p.lastSentAt = time.Now()
+	p.mu.Unlock()
+	p.send(ctx, msg)
+}
+
+// Stop discards any pending update and waits for an in-flight send.
+func (p *progressScheduler) Stop() {
+	p.mu.Lock()
+	p.stopped = true
+	p.pending, p.pendingCtx = "", nil
+	if p.timer != nil {
+		p.timer.Stop()
+	}
+	p.mu.Unlock()
+	p.sendMu.Lock()
+	p.sendMu.Unlock()
+}
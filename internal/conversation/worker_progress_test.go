@@ -0,0 +1,197 @@
+package conversation
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
+)
+
+type progressRecorder struct {
+	mu   sync.Mutex
+	msgs []string
+}
+
+func (r *progressRecorder) send(_ context.Context, msg string) {
+	r.mu.Lock()
+	r.msgs = append(r.msgs, msg)
+	r.mu.Unlock()
+}
+
+func (r *progressRecorder) all() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.msgs...)
+}
+
+func TestProgressScheduler_FastResultSendsNothing(t *testing.T) {
+	rec := &progressRecorder{}
+	p := newProgressScheduler(50*time.Millisecond, 100*time.Millisecond, rec.send)
+
+	p.Notify(context.Background(), "Checking available times...")
+	time.Sleep(10 * time.Millisecond)
+	p.Stop()
+	time.Sleep(80 * time.Millisecond)
+
+	if got := rec.all(); len(got) != 0 {
+		t.Fatalf("expected no progress, got %v", got)
+	}
+}
+
+func TestProgressScheduler_SlowResultIsRateLimited(t *testing.T) {
+	rec := &progressRecorder{}
+	p := newProgressScheduler(20*time.Millisecond, 80*time.Millisecond, rec.send)
+	ctx := context.Background()
+
+	p.Notify(ctx, "first")
+	time.Sleep(40 * time.Millisecond) // "first" sent at ~20ms
+	p.Notify(ctx, "first")            // duplicate, ignored
+	p.Notify(ctx, "second")
+	p.Notify(ctx, "third") // replaces "second" while it waits
+	time.Sleep(30 * time.Millisecond)
+	if got := rec.all(); !reflect.DeepEqual(got, []string{"first"}) {
+		t.Fatalf("before min interval: got %v", got)
+	}
+	time.Sleep(60 * time.Millisecond) // "third" due at ~100ms
+	p.Stop()
+
+	if got := rec.all(); !reflect.DeepEqual(got, []string{"first", "third"}) {
+		t.Fatalf("got %v, want [first third]", got)
+	}
+}
+
+func TestProgressScheduler_NotifyDoesNotWaitForSend(t *testing.T) {
+	started, release := make(chan struct{}), make(chan struct{})
+	var sent []string
+	p := newProgressScheduler(0, time.Hour, func(_ context.Context, msg string) {
+		sent = append(sent, msg)
+		close(started)
+		<-release
+	})
+
+	p.Notify(context.Background(), "first")
+	<-started
+	notified := make(chan struct{})
+	go func() {
+		p.Notify(context.Background(), "second")
+		close(notified)
+	}()
+	select {
+	case <-notified:
+	case <-time.After(time.Second):
+		t.Fatal("Notify blocked on an in-flight send")
+	}
+
+	stopped := make(chan struct{})
+	go func() {
+		p.Stop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+		t.Fatal("Stop returned while a send was in flight")
+	case <-time.After(20 * time.Millisecond):
+	}
+	close(release)
+	<-stopped
+	if !reflect.DeepEqual(sent, []string{"first"}) {
+		t.Fatalf("got %v, want [first]", sent)
+	}
+}
+
+// progressService emits progress updates while it "searches" for delay.
+type progressService struct {
+	delay   time.Duration
+	updates []string
+}
+
+func (s *progressService) StartConversation(ctx context.Context, req StartRequest) (*Response, error) {
+	return &Response{ConversationID: req.ConversationID}, nil
+}
+
+func (s *progressService) ProcessMessage(ctx context.Context, req MessageRequest) (*Response, error) {
+	step := s.delay / time.Duration(len(s.updates)+1)
+	for _, msg := range s.updates {
+		if req.OnProgress != nil {
+			req.OnProgress(ctx, msg)
+		}
+		time.Sleep(step)
+	}
+	time.Sleep(step)
+	return &Response{ConversationID: req.ConversationID, Message: "here are your times"}, nil
+}
+
+func (s *progressService) GetHistory(ctx context.Context, conversationID string) ([]Message, error) {
+	return nil, nil
+}
+
+func runProgressWorker(t *testing.T, service *progressService) []string {
+	t.Helper()
+	queue := newScriptedQueue()
+	messenger := &recordingMessenger{}
+	worker := NewWorker(service, queue, &stubJobUpdater{}, messenger, nil, logging.Default(),
+		WithWorkerCount(1), WithReceiveBatchSize(1), WithReceiveWaitSeconds(0),
+		WithProgressGating(40*time.Millisecond, 150*time.Millisecond))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	worker.Start(ctx)
+
+	body, _ := json.Marshal(queuePayload{
+		ID:   "job-progress",
+		Kind: jobTypeMessage,
+		Message: MessageRequest{
+			ConversationID: "conv-progress",
+			OrgID:          "org-1",
+			Message:        "any times next week?",
+			Channel:        ChannelSMS,
+			From:           "+12223334444",
+			To:             "+15556667777",
+		},
+	})
+	queue.enqueue(queueMessage{ID: "msg-progress", Body: string(body), ReceiptHandle: "rh-progress"})
+
+	waitFor(func() bool {
+		for _, r := range messenger.allReplies() {
+			if r.Body == "here are your times" {
+				return true
+			}
+		}
+		return false
+	}, 2*time.Second, t)
+	cancel()
+	worker.Wait()
+
+	var bodies []string
+	for _, r := range messenger.allReplies() {
+		bodies = append(bodies, r.Body)
+	}
+	return bodies
+}
+
+func TestWorkerProgress_FastSearchSendsOnlyReply(t *testing.T) {
+	got := runProgressWorker(t, &progressService{
+		delay:   10 * time.Millisecond,
+		updates: []string{"Checking available times..."},
+	})
+	if want := []string{"here are your times"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("replies = %v, want %v", got, want)
+	}
+}
+
+func TestWorkerProgress_SlowSearchSendsGatedSequence(t *testing.T) {
+	// Updates at ~0, 80, 160, 240ms; the search finishes at ~400ms.
+	// Gating: first update held until 40ms, then one per 150ms, newest wins.
+	got := runProgressWorker(t, &progressService{
+		delay:   400 * time.Millisecond,
+		updates: []string{"checking", "still checking", "searching further out", "almost done"},
+	})
+	want := []string{"checking", "searching further out", "almost done", "here are your times"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("replies = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,217 @@
+package conversation
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	// defaultDrainTimeout is how long in-flight jobs may keep running after
+	// shutdown starts. It stays under ECS's 30s stop timeout so interrupted
+	// jobs can still be requeued before SIGKILL.
+	defaultDrainTimeout = 25 * time.Second
+	// drainVisibilityMargin pads the SQS visibility extension for in-flight
+	// jobs past the drain deadline, covering the requeue itself.
+	drainVisibilityMargin = 30 * time.Second
+	// requeueTimeout bounds a single requeue during shutdown.
+	requeueTimeout = 5 * time.Second
+)
+
+var workerShutdownRequeuedTotal = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Namespace: "medspa",
+		Subsystem: "conversation",
+		Name:      "worker_shutdown_requeued_total",
+		Help:      "Jobs handed back to the queue during a worker shutdown",
+	},
+	[]string{"reason"}, // reason: not_started, interrupted, flushed
+)
+
+func init() {
+	prometheus.MustRegister(workerShutdownRequeuedTotal)
+}
+
+// visibilityExtender is implemented by queues that redeliver unacknowledged
+// messages after a timeout (SQS).
+type visibilityExtender interface {
+	ExtendVisibility(ctx context.Context, receiptHandle string, timeout time.Duration) error
+}
+
+// durableQueue is implemented by queues whose buffer dies with the process
+// (the memory queue); unfinished jobs are persisted instead of re-sent.
+type durableQueue interface {
+	Persist(ctx context.Context, body string) error
+	FlushPending(ctx context.Context) (int, error)
+}
+
+// inflightSet tracks receipt handles of jobs a worker is processing.
+type inflightSet struct {
+	mu       sync.Mutex
+	receipts map[string]struct{}
+}
+
+func (s *inflightSet) add(receipt string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.receipts == nil {
+		s.receipts = make(map[string]struct{})
+	}
+	s.receipts[receipt] = struct{}{}
+}
+
+func (s *inflightSet) remove(receipt string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.receipts, receipt)
+}
+
+func (s *inflightSet) snapshot() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]string, 0, len(s.receipts))
+	for receipt := range s.receipts {
+		out = append(out, receipt)
+	}
+	return out
+}
+
+// DrainTimeout returns how long in-flight jobs may run after shutdown
+// starts; callers waiting on Wait should allow a little longer.
+func (w *Worker) DrainTimeout() time.Duration {
+	return w.cfg.drainTimeout
+}
+
+// stopping reports whether the worker has begun shutting down.
+func (w *Worker) stopping() bool {
+	if w.stopped == nil {
+		return false
+	}
+	select {
+	case <-w.stopped:
+		return true
+	default:
+		return false
+	}
+}
+
+// drain runs once shutdown starts: it gives in-flight jobs until the drain
+// deadline, then cancels them so handleMessage requeues them, and finally
+// persists anything still buffered in a memory queue.
+func (w *Worker) drain(ctx context.Context, runners *sync.WaitGroup, cancelJobs context.CancelFunc) {
+	defer w.wg.Done()
+	defer cancelJobs()
+	<-ctx.Done()
+	w.log(ctx).Info("conversation worker draining", "timeout", w.cfg.drainTimeout)
+	w.extendInflightVisibility()
+
+	done := make(chan struct{})
+	go func() {
+		runners.Wait()
+		close(done)
+	}()
+	timer := time.NewTimer(w.cfg.drainTimeout)
+	defer timer.Stop()
+	select {
+	case <-done:
+	case <-timer.C:
+		w.log(ctx).Warn("drain deadline reached; requeueing unfinished jobs")
+		cancelJobs()
+		<-done
+	}
+
+	if dq, ok := w.queue.(durableQueue); ok {
+		flushCtx, cancel := context.WithTimeout(context.Background(), requeueTimeout)
+		defer cancel()
+		n, err := dq.FlushPending(flushCtx)
+		if n > 0 {
+			workerShutdownRequeuedTotal.WithLabelValues("flushed").Add(float64(n))
+		}
+		if err != nil {
+			w.log(ctx).Error("failed to persist buffered jobs at shutdown", "error", err, "flushed", n)
+		} else if n > 0 {
+			w.log(ctx).Info("persisted buffered jobs for the next worker", "count", n)
+		}
+	}
+}
+
+// extendInflightVisibility keeps SQS from redelivering jobs that are still
+// running while the worker drains.
+func (w *Worker) extendInflightVisibility() {
+	ext, ok := w.queue.(visibilityExtender)
+	if !ok {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), requeueTimeout)
+	defer cancel()
+	for _, receipt := range w.inflight.snapshot() {
+		if err := ext.ExtendVisibility(ctx, receipt, w.cfg.drainTimeout+drainVisibilityMargin); err != nil {
+			w.log(ctx).Warn("failed to extend visibility for in-flight job", "error", err)
+		}
+	}
+}
+
+// requeue hands a job back to the queue and deletes the original. During
+// shutdown a memory queue persists it for the next process instead. If the
+// requeue fails the original is left for redelivery.
+func (w *Worker) requeue(msg queueMessage, body string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), requeueTimeout)
+	defer cancel()
+	var err error
+	if dq, ok := w.queue.(durableQueue); ok && w.stopping() {
+		err = dq.Persist(ctx, body)
+	} else {
+		err = w.queue.Send(ctx, body)
+	}
+	if err != nil {
+		return err
+	}
+	w.deleteMessage(ctx, msg.ReceiptHandle)
+	return nil
+}
+
+// requeueInterrupted hands back a job the drain deadline cut short. The
+// payload carries AckSent so the retry doesn't text the patient again.
+func (w *Worker) requeueInterrupted(ctx context.Context, msg queueMessage, payload queuePayload) {
+	_, body, err := encodePayload(payload)
+	if err == nil {
+		err = w.requeue(msg, body)
+	}
+	if err != nil {
+		w.log(ctx).Error("failed to requeue interrupted job", "error", err, "kind", payload.Kind)
+		return
+	}
+	workerShutdownRequeuedTotal.WithLabelValues("interrupted").Inc()
+	w.log(ctx).Warn("job interrupted by shutdown; requeued", "kind", payload.Kind, "ack_sent", payload.AckSent)
+}
+
+// ackSentBefore reports whether an earlier attempt at the job already sent
+// its progress acknowledgement, per the job record.
+func (w *Worker) ackSentBefore(ctx context.Context, payload *queuePayload) bool {
+	if payload.AckSent {
+		return true
+	}
+	tracker, ok := w.jobs.(JobAckTracker)
+	if !ok || !payload.TrackStatus {
+		return false
+	}
+	sent, err := tracker.AckSent(ctx, payload.ID)
+	if err != nil {
+		w.log(ctx).Warn("failed to check job ack", "error", err)
+		return false
+	}
+	return sent
+}
+
+// recordAckSent persists that the job's progress acknowledgement went out.
+func (w *Worker) recordAckSent(ctx context.Context, payload *queuePayload) {
+	tracker, ok := w.jobs.(JobAckTracker)
+	if !ok || !payload.TrackStatus {
+		return
+	}
+	if err := tracker.MarkAckSent(ctx, payload.ID); err != nil {
+		w.log(ctx).Warn("failed to record job ack", "error", err)
+	}
+}
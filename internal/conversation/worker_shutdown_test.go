@@ -0,0 +1,267 @@
+package conversation
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
+)
+
+const shutdownProgressText = "Checking available times..."
+
+// hangingSearchService sends a progress update, then either blocks until
+// its context is cancelled or replies after a short search.
+type hangingSearchService struct {
+	hang bool
+}
+
+func (s *hangingSearchService) StartConversation(ctx context.Context, req StartRequest) (*Response, error) {
+	return &Response{ConversationID: req.ConversationID}, nil
+}
+
+func (s *hangingSearchService) ProcessMessage(ctx context.Context, req MessageRequest) (*Response, error) {
+	if req.OnProgress != nil {
+		req.OnProgress(ctx, shutdownProgressText)
+	}
+	if s.hang {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	time.Sleep(60 * time.Millisecond)
+	return &Response{ConversationID: req.ConversationID, Message: "here are your times"}, nil
+}
+
+func (s *hangingSearchService) GetHistory(ctx context.Context, conversationID string) ([]Message, error) {
+	return nil, nil
+}
+
+// ackJobStore records job outcomes and which jobs already sent their ack.
+type ackJobStore struct {
+	stubJobUpdater
+	ackMu sync.Mutex
+	acks  map[string]bool
+}
+
+func (s *ackJobStore) MarkAckSent(ctx context.Context, jobID string) error {
+	s.ackMu.Lock()
+	defer s.ackMu.Unlock()
+	if s.acks == nil {
+		s.acks = make(map[string]bool)
+	}
+	s.acks[jobID] = true
+	return nil
+}
+
+func (s *ackJobStore) AckSent(ctx context.Context, jobID string) (bool, error) {
+	s.ackMu.Lock()
+	defer s.ackMu.Unlock()
+	return s.acks[jobID], nil
+}
+
+// visibilityQueue is a scripted SQS-like queue that records requeues and
+// visibility extensions.
+type visibilityQueue struct {
+	*scriptedQueue
+	mu       sync.Mutex
+	sent     []string
+	extended map[string]time.Duration
+}
+
+func (q *visibilityQueue) Send(ctx context.Context, body string) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.sent = append(q.sent, body)
+	return nil
+}
+
+func (q *visibilityQueue) ExtendVisibility(ctx context.Context, receiptHandle string, timeout time.Duration) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.extended == nil {
+		q.extended = make(map[string]time.Duration)
+	}
+	q.extended[receiptHandle] = timeout
+	return nil
+}
+
+func (q *visibilityQueue) sentBodies() []string {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return append([]string(nil), q.sent...)
+}
+
+func shutdownJobBody(t *testing.T, jobID, conversationID string) string {
+	t.Helper()
+	body, err := json.Marshal(queuePayload{
+		ID:          jobID,
+		Kind:        jobTypeMessage,
+		TrackStatus: true,
+		Message: MessageRequest{
+			ConversationID: conversationID,
+			OrgID:          "org-1",
+			Message:        "any times next week?",
+			Channel:        ChannelSMS,
+			From:           "+12223334444",
+			To:             "+15556667777",
+		},
+	})
+	if err != nil {
+		t.Fatalf("marshal payload: %v", err)
+	}
+	return string(body)
+}
+
+func repliesFor(messenger *recordingMessenger, conversationID string) []string {
+	var out []string
+	for _, r := range messenger.allReplies() {
+		if r.ConversationID == conversationID {
+			out = append(out, r.Body)
+		}
+	}
+	return out
+}
+
+func newShutdownWorker(service Service, queue queueClient, jobs JobUpdater, messenger ReplyMessenger) *Worker {
+	return NewWorker(service, queue, jobs, messenger, nil, logging.Default(),
+		WithWorkerCount(1), WithReceiveBatchSize(1), WithReceiveWaitSeconds(0),
+		WithProgressGating(5*time.Millisecond, time.Second),
+		WithDrainTimeout(50*time.Millisecond))
+}
+
+func TestWorkerShutdown_MemoryQueuePersistsUnfinishedJobsWithoutResendingAck(t *testing.T) {
+	store := &fakeOverflowStore{}
+	jobs := &ackJobStore{}
+	messenger := &recordingMessenger{}
+
+	queue := NewMemoryQueue(8, WithOverflowStore(store))
+	if err := queue.Send(context.Background(), shutdownJobBody(t, "job-running", "conv-running")); err != nil {
+		t.Fatalf("send: %v", err)
+	}
+	worker := newShutdownWorker(&hangingSearchService{hang: true}, queue, jobs, messenger)
+	ctx, cancel := context.WithCancel(context.Background())
+	worker.Start(ctx)
+
+	waitFor(func() bool { return len(repliesFor(messenger, "conv-running")) == 1 }, 2*time.Second, t)
+	// Arrives while the only runner is busy, so it is still buffered at shutdown.
+	if err := queue.Send(context.Background(), shutdownJobBody(t, "job-waiting", "conv-waiting")); err != nil {
+		t.Fatalf("send: %v", err)
+	}
+	cancel()
+	worker.Wait()
+
+	if got := repliesFor(messenger, "conv-running"); len(got) != 1 || got[0] != shutdownProgressText {
+		t.Fatalf("expected only the ack before shutdown, got %v", got)
+	}
+	if jobs.failureCount() != 0 {
+		t.Fatalf("expected the interrupted job not to be marked failed, got %d failures", jobs.failureCount())
+	}
+	if store.len() != 2 {
+		t.Fatalf("expected both jobs persisted for the next worker, got %d", store.len())
+	}
+	if sent, _ := jobs.AckSent(context.Background(), "job-running"); !sent {
+		t.Fatal("expected the ack to be recorded on the job")
+	}
+
+	// The next process picks both jobs up from the overflow store.
+	restarted := NewMemoryQueue(8, WithOverflowStore(store))
+	restarted.drainOverflow(context.Background())
+	worker = newShutdownWorker(&hangingSearchService{}, restarted, jobs, messenger)
+	ctx, cancel = context.WithCancel(context.Background())
+	defer cancel()
+	worker.Start(ctx)
+	waitFor(func() bool { return len(jobs.completedJobs()) == 2 }, 2*time.Second, t)
+	cancel()
+	worker.Wait()
+
+	if got := repliesFor(messenger, "conv-running"); len(got) != 2 || got[1] != "here are your times" {
+		t.Fatalf("expected the resumed job to reply without a second ack, got %v", got)
+	}
+	if got := repliesFor(messenger, "conv-waiting"); len(got) != 2 || got[0] != shutdownProgressText {
+		t.Fatalf("expected the waiting job to run normally, got %v", got)
+	}
+}
+
+func TestWorkerShutdown_SQSRequeuesInterruptedJobWithAckMarker(t *testing.T) {
+	queue := &visibilityQueue{scriptedQueue: newScriptedQueue()}
+	jobs := &ackJobStore{}
+	messenger := &recordingMessenger{}
+	worker := newShutdownWorker(&hangingSearchService{hang: true}, queue, jobs, messenger)
+
+	body := shutdownJobBody(t, "job-sqs", "conv-sqs")
+	queue.enqueue(queueMessage{ID: "msg-sqs", Body: body, ReceiptHandle: "rh-sqs"})
+	ctx, cancel := context.WithCancel(context.Background())
+	worker.Start(ctx)
+	waitFor(func() bool { return len(repliesFor(messenger, "conv-sqs")) == 1 }, 2*time.Second, t)
+	cancel()
+	worker.Wait()
+
+	if got := queue.extended["rh-sqs"]; got != 50*time.Millisecond+drainVisibilityMargin {
+		t.Fatalf("expected visibility extended past the drain deadline, got %v", got)
+	}
+	sent := queue.sentBodies()
+	if len(sent) != 1 {
+		t.Fatalf("expected the interrupted job requeued once, got %d", len(sent))
+	}
+	var requeued queuePayload
+	if err := json.Unmarshal([]byte(sent[0]), &requeued); err != nil {
+		t.Fatalf("decode requeued body: %v", err)
+	}
+	if requeued.ID != "job-sqs" || !requeued.AckSent {
+		t.Fatalf("expected the same job with the ack marker, got id=%q ack_sent=%v", requeued.ID, requeued.AckSent)
+	}
+	queue.delMutex.Lock()
+	deleted := queue.deleted
+	queue.delMutex.Unlock()
+	if deleted != 1 {
+		t.Fatalf("expected the original message deleted once, got %d", deleted)
+	}
+	if jobs.failureCount() != 0 {
+		t.Fatalf("expected no failure recorded, got %d", jobs.failureCount())
+	}
+
+	// A hard kill redelivers the original body without the marker; the job
+	// record still suppresses the ack.
+	queue = &visibilityQueue{scriptedQueue: newScriptedQueue()}
+	worker = newShutdownWorker(&hangingSearchService{}, queue, jobs, messenger)
+	queue.enqueue(queueMessage{ID: "msg-sqs", Body: body, ReceiptHandle: "rh-sqs-2"})
+	ctx, cancel = context.WithCancel(context.Background())
+	defer cancel()
+	worker.Start(ctx)
+	waitFor(func() bool { return len(jobs.completedJobs()) == 1 }, 2*time.Second, t)
+	cancel()
+	worker.Wait()
+
+	if got := repliesFor(messenger, "conv-sqs"); len(got) != 2 || got[1] != "here are your times" {
+		t.Fatalf("expected only the reply on redelivery, got %v", got)
+	}
+}
+
+func TestWorkerShutdown_FinishesJobsWithinDrainTimeout(t *testing.T) {
+	queue := &visibilityQueue{scriptedQueue: newScriptedQueue()}
+	jobs := &ackJobStore{}
+	messenger := &recordingMessenger{}
+	worker := NewWorker(&hangingSearchService{}, queue, jobs, messenger, nil, logging.Default(),
+		WithWorkerCount(1), WithReceiveBatchSize(1), WithReceiveWaitSeconds(0),
+		WithProgressGating(5*time.Millisecond, time.Second),
+		WithDrainTimeout(time.Second))
+
+	queue.enqueue(queueMessage{ID: "msg-drain", Body: shutdownJobBody(t, "job-drain", "conv-drain"), ReceiptHandle: "rh-drain"})
+	ctx, cancel := context.WithCancel(context.Background())
+	worker.Start(ctx)
+	waitFor(func() bool { return len(repliesFor(messenger, "conv-drain")) == 1 }, 2*time.Second, t)
+	cancel()
+	worker.Wait()
+
+	if got := jobs.completedJobs(); len(got) != 1 || got[0] != "job-drain" {
+		t.Fatalf("expected the job to finish during the drain, got %v", got)
+	}
+	if got := repliesFor(messenger, "conv-drain"); len(got) != 2 || got[1] != "here are your times" {
+		t.Fatalf("expected the reply to go out during the drain, got %v", got)
+	}
+	if len(queue.sentBodies()) != 0 {
+		t.Fatal("expected nothing requeued when the job finishes in time")
+	}
+}
@@ -7,6 +7,8 @@ import (
 	"time"
 
 	"github.com/wolfman30/medspa-ai-platform/internal/booking"
+	"github.com/wolfman30/medspa-ai-platform/internal/messaging/templates"
+	"github.com/wolfman30/medspa-ai-platform/internal/tenancy"
 )
 
 func (w *Worker) sendReply(ctx context.Context, payload queuePayload, resp *Response) bool {
@@ -47,9 +49,9 @@ func (w *Worker) sendReply(ctx context.Context, payload queuePayload, resp *Resp
 		if providerID != "" {
 			exists, err := w.msgChecker.HasProviderMessage(ctx, providerID)
 			if err != nil {
-				w.logger.Warn("provider message lookup failed", "error", err, "provider_message_id", providerID, "job_id", payload.ID)
+				w.log(ctx).Warn("provider message lookup failed", "error", err, "provider_message_id", providerID)
 			} else if !exists {
-				w.logger.Info("suppressing reply: inbound message missing", "provider_message_id", providerID, "job_id", payload.ID)
+				w.log(ctx).Info("suppressing reply: inbound message missing", "provider_message_id", providerID)
 				return true
 			}
 		}
@@ -83,7 +85,7 @@ func (w *Worker) sendReply(ctx context.Context, payload queuePayload, resp *Resp
 		for _, reason := range leakResult.Reasons {
 			w.events.OutputGuardTriggered(ctx, resp.ConversationID, msg.OrgID, reason)
 		}
-		w.logger.Warn("output guard: sensitive data leak detected",
+		w.log(ctx).Warn("output guard: sensitive data leak detected",
 			"conversation_id", resp.ConversationID,
 			"org_id", msg.OrgID,
 			"reasons", leakResult.Reasons,
@@ -104,10 +106,11 @@ func (w *Worker) sendReply(ctx context.Context, payload queuePayload, resp *Resp
 		}
 	}
 
-	// SMS length cap: max 480 chars (3 SMS segments) to prevent runaway LLM output.
-	const maxSMSLength = 480
+	// Runaway LLM output guard. Normal long replies are split on segment
+	// boundaries by the messenger; 1600 chars is the most carriers concatenate.
+	const maxSMSLength = 1600
 	if len(resp.Message) > maxSMSLength {
-		w.logger.Warn("sms response truncated",
+		w.log(ctx).Warn("sms response truncated",
 			"conversation_id", resp.ConversationID,
 			"original_length", len(resp.Message),
 			"max_length", maxSMSLength,
@@ -131,19 +134,21 @@ func (w *Worker) sendReply(ctx context.Context, payload queuePayload, resp *Resp
 		To:             msg.From,
 		From:           msg.To,
 		Body:           resp.Message,
-		Metadata: map[string]string{
+		Metadata: resp.Template.Stamp(map[string]string{
 			"job_id": payload.ID,
-		},
+		}),
 	}
 
 	var sendErr error
 	if w.messenger != nil {
-		sendCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		// Long replies go out as several spaced messages, so allow for more
+		// than one provider round trip.
+		sendCtx, cancel := context.WithTimeout(ctx, 15*time.Second)
 		defer cancel()
 
-		if err := w.messenger.SendReply(sendCtx, reply); err != nil {
+		if err := w.sendTurnReply(sendCtx, reply); err != nil {
 			sendErr = err
-			w.logger.Error("failed to send outbound reply", "error", err, "job_id", payload.ID, "org_id", msg.OrgID)
+			w.log(ctx).Error("failed to send outbound reply", "error", err, "org_id", msg.OrgID)
 		}
 	}
 
@@ -165,17 +170,21 @@ func (w *Worker) sendReply(ctx context.Context, payload queuePayload, resp *Resp
 		errorReason = sendErr.Error()
 	}
 
-	w.appendTranscript(context.Background(), conversationID, SMSTranscriptMessage{
+	w.appendTranscript(context.WithoutCancel(ctx), conversationID, SMSTranscriptMessage{
 		Role:              "assistant",
 		From:              msg.To,
 		To:                msg.From,
 		Body:              resp.Message,
 		Timestamp:         resp.Timestamp,
 		Kind:              "ai_reply",
+		Metadata:          resp.Template.Stamp(nil),
 		ProviderMessageID: providerMessageID,
 		Status:            providerStatus,
 		ErrorReason:       errorReason,
 	})
+	if sendErr == nil && !blocked {
+		w.scheduleReengagement(ctx, msg, conversationID, resp.Message, time.Now())
+	}
 	return blocked
 }
 
@@ -193,27 +202,27 @@ func (w *Worker) applySupervisor(ctx context.Context, req SupervisorRequest) (st
 	}
 	decision, err := w.supervisor.Review(ctx, req)
 	if err != nil {
-		w.logger.Warn("supervisor review failed; allowing reply", "error", err, "mode", mode)
+		w.log(ctx).Warn("supervisor review failed; allowing reply", "error", err, "mode", mode)
 		return req.DraftMessage, false
 	}
 	action := decision.Action
 	switch mode {
 	case SupervisorModeWarn:
 		if action != SupervisorActionAllow {
-			w.logger.Warn("supervisor flagged reply", "action", action, "reason", decision.Reason)
+			w.log(ctx).Warn("supervisor flagged reply", "action", action, "reason", decision.Reason)
 		}
 		return req.DraftMessage, false
 	case SupervisorModeBlock:
 		switch action {
 		case SupervisorActionBlock:
-			w.logger.Warn("supervisor blocked reply", "reason", decision.Reason)
+			w.log(ctx).Warn("supervisor blocked reply", "reason", decision.Reason)
 			return defaultSupervisorFallback, true
 		case SupervisorActionEdit:
 			if strings.TrimSpace(decision.EditedText) != "" {
-				w.logger.Info("supervisor edited reply", "reason", decision.Reason)
+				w.log(ctx).Info("supervisor edited reply", "reason", decision.Reason)
 				return decision.EditedText, false
 			}
-			w.logger.Warn("supervisor edit missing content; allowing reply", "reason", decision.Reason)
+			w.log(ctx).Warn("supervisor edit missing content; allowing reply", "reason", decision.Reason)
 			return req.DraftMessage, false
 		default:
 			return req.DraftMessage, false
@@ -222,19 +231,19 @@ func (w *Worker) applySupervisor(ctx context.Context, req SupervisorRequest) (st
 		switch action {
 		case SupervisorActionEdit:
 			if strings.TrimSpace(decision.EditedText) != "" {
-				w.logger.Info("supervisor edited reply", "reason", decision.Reason)
+				w.log(ctx).Info("supervisor edited reply", "reason", decision.Reason)
 				return decision.EditedText, false
 			}
-			w.logger.Warn("supervisor edit missing content; allowing reply", "reason", decision.Reason)
+			w.log(ctx).Warn("supervisor edit missing content; allowing reply", "reason", decision.Reason)
 			return req.DraftMessage, false
 		case SupervisorActionBlock:
-			w.logger.Warn("supervisor blocked reply", "reason", decision.Reason)
+			w.log(ctx).Warn("supervisor blocked reply", "reason", decision.Reason)
 			return defaultSupervisorFallback, true
 		default:
 			return req.DraftMessage, false
 		}
 	default:
-		w.logger.Warn("supervisor mode unknown; allowing reply", "mode", mode)
+		w.log(ctx).Warn("supervisor mode unknown; allowing reply", "mode", mode)
 		return req.DraftMessage, false
 	}
 }
@@ -258,9 +267,10 @@ func (w *Worker) handleTimeSelectionResponse(ctx context.Context, msg MessageReq
 			To:             msg.From, // Send to the customer
 			From:           msg.To,   // From the clinic number
 			Body:           tsr.SMSMessage,
+			Metadata:       tsr.Template.Stamp(nil),
 		}
-		if err := w.messenger.SendReply(ctx, reply); err != nil {
-			w.logger.Error("failed to send time selection SMS", "error", err, "org_id", msg.OrgID, "lead_id", msg.LeadID)
+		if err := w.sendTurnReply(ctx, reply); err != nil {
+			w.log(ctx).Error("failed to send time selection SMS", "error", err, "org_id", msg.OrgID, "lead_id", msg.LeadID)
 			return
 		}
 
@@ -271,6 +281,8 @@ func (w *Worker) handleTimeSelectionResponse(ctx context.Context, msg MessageReq
 			From:      msg.To,
 			To:        msg.From,
 			Timestamp: time.Now(),
+			Kind:      "time_selection",
+			Metadata:  tsr.Template.Stamp(nil),
 		}
 		if w.transcript != nil {
 			_ = w.transcript.Append(ctx, msg.ConversationID, timeSelMsg)
@@ -287,49 +299,54 @@ func (w *Worker) handleTimeSelectionResponse(ctx context.Context, msg MessageReq
 				AppendAssistantMessage(ctx context.Context, conversationID, message string) error
 			}); ok {
 				if err := histStore.AppendAssistantMessage(ctx, msg.ConversationID, tsr.SMSMessage); err != nil {
-					w.logger.Warn("failed to save time selection to LLM history", "error", err)
+					w.log(ctx).Warn("failed to save time selection to LLM history", "error", err)
 				}
 			}
 		}
 	}
 
+	// The patient has times to pick from; no nudges about qualification.
+	w.cancelReengagement(ctx, msg.ConversationID)
+
 	// Update conversation status to awaiting_time_selection
 	if w.convStore != nil {
-		if err := w.convStore.UpdateStatus(ctx, msg.ConversationID, StatusAwaitingTimeSelection); err != nil {
-			w.logger.Warn("failed to update conversation status to awaiting_time_selection", "error", err, "conversation_id", msg.ConversationID)
+		if err := w.convStore.UpdateStatus(ctx, tenancy.ForOrg(msg.OrgID), msg.ConversationID, StatusAwaitingTimeSelection); err != nil {
+			w.log(ctx).Warn("failed to update conversation status to awaiting_time_selection", "error", err)
 		}
 	}
 
-	w.logger.Info("time selection SMS sent",
-		"conversation_id", msg.ConversationID,
+	w.log(ctx).Info("time selection SMS sent",
 		"slots_presented", len(tsr.Slots),
 		"service", tsr.Service,
 		"exact_match", tsr.ExactMatch,
 	)
 }
 
-func (w *Worker) sendBookingFallbackSMS(ctx context.Context, msg MessageRequest, body string) {
+func (w *Worker) sendBookingFallbackSMS(ctx context.Context, msg MessageRequest, templateID string) {
 	if w.messenger == nil {
 		return
 	}
+	fallback := w.clinicConfig(ctx, msg.OrgID).RenderTemplate(templateID, nil)
 	reply := OutboundReply{
 		OrgID:          msg.OrgID,
 		LeadID:         msg.LeadID,
 		ConversationID: msg.ConversationID,
 		To:             msg.From,
 		From:           msg.To,
-		Body:           body,
+		Body:           fallback.Body,
+		Metadata:       fallback.Stamp(nil),
 	}
 	if err := w.messenger.SendReply(ctx, reply); err != nil {
-		w.logger.Error("failed to send booking fallback SMS", "error", err, "org_id", msg.OrgID)
+		w.log(ctx).Error("failed to send booking fallback SMS", "error", err, "org_id", msg.OrgID)
 	}
 	w.appendTranscript(ctx, msg.ConversationID, SMSTranscriptMessage{
 		Role:      "assistant",
 		From:      msg.To,
 		To:        msg.From,
-		Body:      body,
+		Body:      fallback.Body,
 		Timestamp: time.Now(),
 		Kind:      "booking_fallback",
+		Metadata:  fallback.Stamp(nil),
 	})
 }
 
@@ -359,7 +376,7 @@ func (w *Worker) handleManualHandoff(ctx context.Context, msg MessageRequest, re
 
 	// Enrich from leads repository if available
 	if w.leadsRepo != nil && msg.LeadID != "" {
-		if dbLead, err := w.leadsRepo.GetByID(ctx, msg.OrgID, msg.LeadID); err == nil && dbLead != nil {
+		if dbLead, err := w.leadsRepo.GetByID(ctx, tenancy.ForOrg(msg.OrgID), msg.LeadID); err == nil && dbLead != nil {
 			lead.PatientName = dbLead.Name
 			lead.PatientEmail = dbLead.Email
 			lead.ServiceRequested = dbLead.ServiceInterest
@@ -370,9 +387,8 @@ func (w *Worker) handleManualHandoff(ctx context.Context, msg MessageRequest, re
 		}
 	}
 
-	result, err := w.manualHandoff.CreateBooking(ctx, lead)
-	if err != nil {
-		w.logger.Error("manual handoff notification failed (non-fatal)",
+	if _, err := w.manualHandoff.CreateBooking(ctx, lead); err != nil {
+		w.log(ctx).Error("manual handoff notification failed (non-fatal)",
 			"error", err,
 			"org_id", msg.OrgID,
 			"lead_id", msg.LeadID,
@@ -380,11 +396,14 @@ func (w *Worker) handleManualHandoff(ctx context.Context, msg MessageRequest, re
 		// Continue — still send the patient message even if clinic notification failed
 	}
 
-	// Send handoff confirmation to patient
-	handoffMsg := result.HandoffMessage
-	if handoffMsg == "" {
-		handoffMsg = w.manualHandoff.GetHandoffMessage(clinicName)
-	}
+	// Send handoff confirmation to patient in the clinic's copy of the
+	// template.
+	name := strings.TrimSpace(clinicName)
+	if name == "" {
+		name = "the clinic"
+	}
+	handoff := cfg.RenderTemplate(templates.BookingManualHandoff, templates.Params{"clinic_name": name})
+	handoffMsg := handoff.Body
 	if w.messenger != nil {
 		reply := OutboundReply{
 			OrgID:          msg.OrgID,
@@ -393,9 +412,10 @@ func (w *Worker) handleManualHandoff(ctx context.Context, msg MessageRequest, re
 			To:             msg.From,
 			From:           msg.To,
 			Body:           handoffMsg,
+			Metadata:       handoff.Stamp(nil),
 		}
 		if err := w.messenger.SendReply(ctx, reply); err != nil {
-			w.logger.Error("failed to send manual handoff SMS to patient",
+			w.log(ctx).Error("failed to send manual handoff SMS to patient",
 				"error", err,
 				"org_id", msg.OrgID,
 				"lead_id", msg.LeadID,
@@ -410,6 +430,7 @@ func (w *Worker) handleManualHandoff(ctx context.Context, msg MessageRequest, re
 			From:      msg.To,
 			To:        msg.From,
 			Timestamp: time.Now(),
+			Metadata:  handoff.Stamp(nil),
 		}
 		w.appendTranscript(ctx, msg.ConversationID, handoffTranscript)
 		if w.convStore != nil {
@@ -417,7 +438,7 @@ func (w *Worker) handleManualHandoff(ctx context.Context, msg MessageRequest, re
 		}
 	}
 
-	w.logger.Info("manual handoff completed",
+	w.log(ctx).Info("manual handoff completed",
 		"org_id", msg.OrgID,
 		"lead_id", msg.LeadID,
 		"patient_name", lead.PatientName,
@@ -455,8 +476,7 @@ func smsConversationID(orgID string, phone string) string {
 	if orgID == "" {
 		return ""
 	}
-	digits := sanitizeDigits(phone)
-	digits = normalizeUSDigits(digits)
+	digits := canonicalPhoneDigits(phone)
 	if digits == "" {
 		return ""
 	}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"log/slog"
 	"strings"
 	"sync"
 	"testing"
@@ -12,6 +13,10 @@ import (
 	"github.com/google/uuid"
 
 	"github.com/wolfman30/medspa-ai-platform/internal/events"
+	"github.com/wolfman30/medspa-ai-platform/internal/leads"
+	"github.com/wolfman30/medspa-ai-platform/internal/messaging/templates"
+	"github.com/wolfman30/medspa-ai-platform/internal/notify"
+	"github.com/wolfman30/medspa-ai-platform/internal/tenancy"
 	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
 )
 
@@ -491,6 +496,344 @@ func TestWorkerProcessesPaymentEvent_IsIdempotent(t *testing.T) {
 	}
 }
 
+func TestWorkerPaymentFailed_SendsRetryLinkOnce(t *testing.T) {
+	messenger := &stubMessenger{}
+	deposits := &stubDepositSender{}
+	processed := &stubProcessedStore{seen: map[string]bool{}}
+	worker := NewWorker(&recordingService{}, newScriptedQueue(), &stubJobUpdater{}, messenger, nil, logging.Default(),
+		WithProcessedEventsStore(processed), WithDepositSender(deposits))
+
+	scheduled := time.Now().Add(48 * time.Hour).UTC()
+	event := events.PaymentFailedV1{
+		EventID:      "evt-1",
+		OrgID:        uuid.NewString(),
+		LeadID:       uuid.NewString(),
+		ProviderRef:  "pay-1",
+		LeadPhone:    "+19998887777",
+		FromNumber:   "+15550000000",
+		AmountCents:  5000,
+		ScheduledFor: &scheduled,
+	}
+	if err := worker.handlePaymentFailedEvent(context.Background(), &event); err != nil {
+		t.Fatalf("first decline: %v", err)
+	}
+	if !deposits.called {
+		t.Fatalf("expected a fresh deposit link after the first decline")
+	}
+	if deposits.lastRes == nil || deposits.lastRes.DepositIntent == nil || deposits.lastRes.DepositIntent.AmountCents != 5000 || deposits.lastRes.DepositIntent.ScheduledFor != &scheduled {
+		t.Fatalf("unexpected retry deposit intent: %+v", deposits.lastRes)
+	}
+	if deposits.lastMsg.From != event.LeadPhone || deposits.lastMsg.To != event.FromNumber {
+		t.Fatalf("retry link routed to wrong numbers: %+v", deposits.lastMsg)
+	}
+	if body := messenger.lastReply().Body; body != templates.Text(templates.PaymentRetryIntro) {
+		t.Fatalf("expected gentle retry intro, got %q", body)
+	}
+	if meta := messenger.lastReply().Metadata; meta[templates.MetadataID] != templates.PaymentRetryIntro || meta[templates.MetadataVersion] != "1" {
+		t.Fatalf("retry intro not stamped with its template: %v", meta)
+	}
+
+	deposits.called = false
+	event.EventID = "evt-2"
+	event.ProviderRef = "pay-2"
+	if err := worker.handlePaymentFailedEvent(context.Background(), &event); err != nil {
+		t.Fatalf("second decline: %v", err)
+	}
+	if deposits.called {
+		t.Fatalf("expected no second retry link")
+	}
+	if body := messenger.lastReply().Body; !strings.HasPrefix(body, "Payment failed") {
+		t.Fatalf("expected fallback failure message, got %q", body)
+	}
+	if got := messenger.callCount(); got != 2 {
+		t.Fatalf("expected 2 sms (retry intro + fallback), got %d", got)
+	}
+}
+
+type stubDisputeBookings struct {
+	stubBookingConfirmer
+	flagged []uuid.UUID
+}
+
+func (s *stubDisputeBookings) FlagDisputed(ctx context.Context, orgID uuid.UUID, leadID uuid.UUID) (int64, error) {
+	s.flagged = append(s.flagged, leadID)
+	return 1, nil
+}
+
+type stubDisputeNotifier struct {
+	disputes []events.PaymentDisputedV1
+}
+
+func (s *stubDisputeNotifier) NotifyPaymentSuccess(ctx context.Context, evt events.PaymentSucceededV1) error {
+	return nil
+}
+
+func (s *stubDisputeNotifier) NotifyPaymentDisputed(ctx context.Context, evt events.PaymentDisputedV1) error {
+	s.disputes = append(s.disputes, evt)
+	return nil
+}
+
+func TestWorkerPaymentDisputed_FlagsBookingAndNotifiesOperator(t *testing.T) {
+	messenger := &stubMessenger{}
+	bookings := &stubDisputeBookings{}
+	notifier := &stubDisputeNotifier{}
+	processed := &stubProcessedStore{seen: map[string]bool{}}
+	worker := NewWorker(&recordingService{}, newScriptedQueue(), &stubJobUpdater{}, messenger, bookings, logging.Default(),
+		WithProcessedEventsStore(processed), WithPaymentNotifier(notifier))
+
+	leadID := uuid.New()
+	event := events.PaymentDisputedV1{
+		EventID:     "evt-d1",
+		OrgID:       uuid.NewString(),
+		LeadID:      leadID.String(),
+		DisputeID:   "dp_1",
+		Reason:      "NOT_AS_DESCRIBED",
+		AmountCents: 5000,
+		LeadPhone:   "+19998887777",
+	}
+	for i := 0; i < 2; i++ {
+		if err := worker.handlePaymentDisputedEvent(context.Background(), &event); err != nil {
+			t.Fatalf("handlePaymentDisputedEvent: %v", err)
+		}
+	}
+
+	if len(bookings.flagged) != 1 || bookings.flagged[0] != leadID {
+		t.Fatalf("expected booking flagged once for lead, got %v", bookings.flagged)
+	}
+	if len(notifier.disputes) != 1 || notifier.disputes[0].DisputeID != "dp_1" {
+		t.Fatalf("expected one operator notification, got %+v", notifier.disputes)
+	}
+	if messenger.wasCalled() {
+		t.Fatalf("patients should not be texted about disputes")
+	}
+}
+
+type stubSlotVerifierService struct {
+	recordingService
+	result *PaidSlotVerification
+	reqs   []PaidSlotVerificationRequest
+}
+
+func (s *stubSlotVerifierService) VerifyPaidSlot(ctx context.Context, req PaidSlotVerificationRequest) (*PaidSlotVerification, error) {
+	s.reqs = append(s.reqs, req)
+	return s.result, nil
+}
+
+type stubSlotConflictNotifier struct {
+	conflicts []notify.SlotConflict
+}
+
+func (s *stubSlotConflictNotifier) NotifyPaymentSuccess(ctx context.Context, evt events.PaymentSucceededV1) error {
+	return nil
+}
+
+func (s *stubSlotConflictNotifier) NotifySlotConflict(ctx context.Context, orgID string, conflict notify.SlotConflict) error {
+	s.conflicts = append(s.conflicts, conflict)
+	return nil
+}
+
+func TestWorkerPaymentSucceeded_SlotTakenOffersAlternatives(t *testing.T) {
+	ctx := context.Background()
+	orgID := uuid.NewString()
+	repo := leads.NewInMemoryRepository()
+	lead, err := repo.Create(ctx, &leads.CreateLeadRequest{OrgID: orgID, Name: "Jane", Phone: "+19998887777"})
+	if err != nil {
+		t.Fatalf("create lead: %v", err)
+	}
+	taken := time.Date(2026, 3, 10, 15, 0, 0, 0, time.UTC)
+	if err := repo.UpdateSelectedAppointment(ctx, tenancy.ForOrg(lead.OrgID), lead.ID, leads.SelectedAppointment{DateTime: &taken, Service: "Botox"}); err != nil {
+		t.Fatalf("select appointment: %v", err)
+	}
+	alternatives := []PresentedSlot{
+		{Index: 1, DateTime: taken.Add(time.Hour), TimeStr: "Tue Mar 10 at 4:00 PM"},
+		{Index: 2, DateTime: taken.Add(2 * time.Hour), TimeStr: "Tue Mar 10 at 5:00 PM"},
+	}
+	service := &stubSlotVerifierService{result: &PaidSlotVerification{
+		Slot:    taken,
+		Service: "Botox",
+		Alternatives: &TimeSelectionResponse{
+			Slots:      alternatives,
+			Service:    "Botox",
+			SMSMessage: paidSlotTakenMessage(nil, taken, "Botox", alternatives).Body,
+		},
+	}}
+	messenger := &stubMessenger{}
+	bookings := &stubBookingConfirmer{}
+	notifier := &stubSlotConflictNotifier{}
+	processed := &stubProcessedStore{seen: map[string]bool{}}
+	worker := NewWorker(service, newScriptedQueue(), &stubJobUpdater{}, messenger, bookings, logging.Default(),
+		WithProcessedEventsStore(processed), WithPaymentNotifier(notifier), WithWorkerLeadsRepo(repo))
+
+	event := events.PaymentSucceededV1{
+		EventID:      "evt-1",
+		OrgID:        orgID,
+		LeadID:       lead.ID,
+		ProviderRef:  "pay-1",
+		LeadPhone:    "+19998887777",
+		FromNumber:   "+15550000000",
+		AmountCents:  5000,
+		ScheduledFor: &taken,
+	}
+	if err := worker.handlePaymentEvent(ctx, &event); err != nil {
+		t.Fatalf("handlePaymentEvent: %v", err)
+	}
+
+	if got := bookings.callCount(); got != 0 {
+		t.Fatalf("expected booking not confirmed, got %d confirm calls", got)
+	}
+	reply := messenger.lastReply()
+	if reply.To != event.LeadPhone || reply.From != event.FromNumber {
+		t.Fatalf("alternatives routed to wrong numbers: %+v", reply)
+	}
+	if !strings.Contains(reply.Body, "deposit is safe") || !strings.Contains(reply.Body, "Tue Mar 10 at 5:00 PM") {
+		t.Fatalf("expected apology with alternatives, got %q", reply.Body)
+	}
+	if len(service.reqs) != 1 || service.reqs[0].ConversationID != smsConversationID(orgID, event.LeadPhone) {
+		t.Fatalf("unexpected verification requests: %+v", service.reqs)
+	}
+	if len(notifier.conflicts) != 1 || notifier.conflicts[0].AlternativesOffered != 2 {
+		t.Fatalf("expected operator alert with alternatives, got %+v", notifier.conflicts)
+	}
+	updated, err := repo.GetByID(ctx, tenancy.ForOrg(orgID), lead.ID)
+	if err != nil {
+		t.Fatalf("get lead: %v", err)
+	}
+	if updated.DepositStatus != DepositStatusAppliedToNextSelection {
+		t.Fatalf("expected deposit applied to next selection, got %q", updated.DepositStatus)
+	}
+	if updated.SelectedDateTime != nil {
+		t.Fatalf("expected taken slot cleared from lead, got %v", updated.SelectedDateTime)
+	}
+
+	// A redelivered event must not text the patient again.
+	if err := worker.handlePaymentEvent(ctx, &event); err != nil {
+		t.Fatalf("redelivered handlePaymentEvent: %v", err)
+	}
+	if got := messenger.callCount(); got != 1 {
+		t.Fatalf("expected one sms, got %d", got)
+	}
+}
+
+func TestWorkerPaymentSucceeded_SlotStillOpenConfirmsBooking(t *testing.T) {
+	scheduled := time.Now().Add(24 * time.Hour).UTC()
+	service := &stubSlotVerifierService{result: &PaidSlotVerification{Available: true, Slot: scheduled}}
+	messenger := &stubMessenger{}
+	bookings := &stubBookingConfirmer{}
+	notifier := &stubSlotConflictNotifier{}
+	worker := NewWorker(service, newScriptedQueue(), &stubJobUpdater{}, messenger, bookings, logging.Default(), WithPaymentNotifier(notifier))
+
+	event := events.PaymentSucceededV1{
+		EventID:      "evt-1",
+		OrgID:        uuid.NewString(),
+		LeadID:       uuid.NewString(),
+		LeadPhone:    "+19998887777",
+		FromNumber:   "+15550000000",
+		AmountCents:  5000,
+		ScheduledFor: &scheduled,
+	}
+	if err := worker.handlePaymentEvent(context.Background(), &event); err != nil {
+		t.Fatalf("handlePaymentEvent: %v", err)
+	}
+	if got := bookings.callCount(); got != 1 {
+		t.Fatalf("expected booking confirmed, got %d confirm calls", got)
+	}
+	if body := messenger.lastReply().Body; !strings.HasPrefix(body, "Payment received!") {
+		t.Fatalf("expected normal confirmation, got %q", body)
+	}
+	if len(notifier.conflicts) != 0 {
+		t.Fatalf("expected no slot conflict alert, got %+v", notifier.conflicts)
+	}
+}
+
+// captureHandler records every log record along with the attributes bound
+// through Logger.With, so tests can check what a derived logger emits.
+type captureHandler struct {
+	mu      *sync.Mutex
+	records *[]map[string]string
+	attrs   []slog.Attr
+}
+
+func newCaptureHandler() *captureHandler {
+	return &captureHandler{mu: &sync.Mutex{}, records: &[]map[string]string{}}
+}
+
+func (h *captureHandler) Enabled(context.Context, slog.Level) bool { return true }
+
+func (h *captureHandler) Handle(_ context.Context, r slog.Record) error {
+	fields := map[string]string{"msg": r.Message}
+	for _, a := range h.attrs {
+		fields[a.Key] = a.Value.String()
+	}
+	r.Attrs(func(a slog.Attr) bool {
+		fields[a.Key] = a.Value.String()
+		return true
+	})
+	h.mu.Lock()
+	*h.records = append(*h.records, fields)
+	h.mu.Unlock()
+	return nil
+}
+
+func (h *captureHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	next := *h
+	next.attrs = append(append([]slog.Attr{}, h.attrs...), attrs...)
+	return &next
+}
+
+func (h *captureHandler) WithGroup(string) slog.Handler { return h }
+
+func (h *captureHandler) snapshot() []map[string]string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return append([]map[string]string(nil), *h.records...)
+}
+
+func TestWorkerLogsCarryCorrelationFields(t *testing.T) {
+	capture := newCaptureHandler()
+	messenger := &stubMessenger{}
+	worker := NewWorker(&replyService{}, newScriptedQueue(), &stubJobUpdater{}, messenger, nil, &logging.Logger{Logger: slog.New(capture)})
+
+	payload := queuePayload{
+		ID:          "job-1",
+		Kind:        jobTypeMessage,
+		TrackStatus: true,
+		Message: MessageRequest{
+			ConversationID: "conv-1",
+			OrgID:          "org-1",
+			LeadID:         "lead-1",
+			Message:        "hi",
+			Channel:        ChannelSMS,
+			From:           "+12223334444",
+			To:             "+15556667777",
+			Metadata:       map[string]string{MetadataRequestID: "req-1"},
+		},
+	}
+	body, _ := json.Marshal(payload)
+	worker.handleMessage(context.Background(), queueMessage{ID: "msg-1", Body: string(body), ReceiptHandle: "rh-1"})
+
+	if !messenger.wasCalled() {
+		t.Fatalf("expected reply to be sent")
+	}
+	records := capture.snapshot()
+	if len(records) == 0 {
+		t.Fatalf("expected job to be logged")
+	}
+	want := map[string]string{
+		"org_id":          "org-1",
+		"conversation_id": "conv-1",
+		"lead_id":         "lead-1",
+		"job_id":          "job-1",
+		"request_id":      "req-1",
+	}
+	for _, rec := range records {
+		for k, v := range want {
+			if rec[k] != v {
+				t.Fatalf("record %q: expected %s=%q, got %q", rec["msg"], k, v, rec[k])
+			}
+		}
+	}
+}
+
 func TestWorkerDispatchesDepositIntent(t *testing.T) {
 	queue := newScriptedQueue()
 	service := &replyService{
@@ -1061,7 +1404,7 @@ func TestPaymentConfirmationMessage_UsesCallbackTime(t *testing.T) {
 				ScheduledFor: tt.scheduledFor,
 			}
 
-			msg := paymentConfirmationMessage(evt, tt.clinicName, tt.bookingURL, tt.callbackTime)
+			msg := paymentConfirmationMessage(evt, nil, tt.clinicName, tt.bookingURL, tt.callbackTime, 0).Body
 
 			for _, want := range tt.wantContains {
 				if !strings.Contains(msg, want) {
@@ -1078,8 +1421,8 @@ func TestPaymentConfirmationMessage_UsesCallbackTime(t *testing.T) {
 }
 
 func TestPaymentConfirmationMessage_NilEvent(t *testing.T) {
-	msg := paymentConfirmationMessage(nil, "Test", "", "shortly")
-	if msg != "" {
+	msg := paymentConfirmationMessage(nil, nil, "Test", "", "shortly", 0)
+	if msg.Body != "" {
 		t.Errorf("expected empty string for nil event, got %q", msg)
 	}
 }
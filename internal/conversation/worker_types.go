@@ -12,6 +12,7 @@ import (
 	moxieclient "github.com/wolfman30/medspa-ai-platform/internal/emr/moxie"
 	"github.com/wolfman30/medspa-ai-platform/internal/events"
 	"github.com/wolfman30/medspa-ai-platform/internal/leads"
+	"github.com/wolfman30/medspa-ai-platform/internal/notify"
 	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
 )
 
@@ -20,6 +21,43 @@ type PaymentNotifier interface {
 	NotifyPaymentSuccess(ctx context.Context, evt events.PaymentSucceededV1) error
 }
 
+// CallbackNotifier alerts clinic operators when a patient asks to be called.
+type CallbackNotifier interface {
+	NotifyCallbackRequested(ctx context.Context, orgID string, req notify.CallbackRequest) error
+}
+
+// BookingNotifier announces bookings confirmed on the clinic's platform. The
+// payment notifier implements it when chat webhooks are available.
+type BookingNotifier interface {
+	NotifyBookingConfirmed(ctx context.Context, orgID string, booking notify.BookingConfirmation) error
+}
+
+// DisputeNotifier alerts clinic operators when a patient disputes a deposit.
+// The payment notifier implements it when operator alerts are available.
+type DisputeNotifier interface {
+	NotifyPaymentDisputed(ctx context.Context, evt events.PaymentDisputedV1) error
+}
+
+// SlotConflictNotifier alerts clinic operators when a paid slot was taken
+// before the booking could be confirmed. The payment notifier implements it
+// when operator alerts are available.
+type SlotConflictNotifier interface {
+	NotifySlotConflict(ctx context.Context, orgID string, conflict notify.SlotConflict) error
+}
+
+// BookingHandoffNotifier alerts clinic operators when a booking is left for
+// staff to finish. The payment notifier implements it when operator alerts
+// are available.
+type BookingHandoffNotifier interface {
+	NotifyBookingHandoff(ctx context.Context, orgID string, handoff notify.BookingHandoff) error
+}
+
+// LeadNotifier announces newly started conversations. The payment notifier
+// implements it when operator alerts are available.
+type LeadNotifier interface {
+	NotifyNewLead(ctx context.Context, orgID string, lead *leads.Lead) error
+}
+
 // SandboxAutoPurger optionally purges demo/test data after sandbox payments complete.
 // Implementations must be safe to call in production (no-ops unless explicitly enabled).
 type SandboxAutoPurger interface {
@@ -46,13 +84,33 @@ type Worker struct {
 	transcript       *SMSTranscriptStore
 	convStore        *ConversationStore
 	moxieClient      *moxieclient.Client
+	writebacks       WritebackAttemptStore
 	leadsRepo        leads.Repository
 	manualHandoff    *booking.ManualHandoffAdapter
 	voiceCaller      VoiceCallInitiator
+	callbackTasks    CallbackTaskStore
+	callbackNotifier CallbackNotifier
+	frustration      *FrustrationTracker
+	frustrationAudit FrustrationAuditor
 	igMessenger      ReplyMessenger
 	webChatMessenger ReplyMessenger
+	scheduler        *Scheduler
+	reaper           *JobReaper
 	logger           *logging.Logger
 	events           *EventLogger
+	orgLimits        *orgLimiter
+	flags            *clinic.FlagCache
+	batcher          *InboundBatcher
+	convLocker       *ConversationLocker
+	crmEvents        CRMEventPublisher
+	paymentClaims    PaymentClaimChecker
+	mediaFetcher     MediaFetcher
+	turnTimings      TurnTimingStore
+
+	// stopped closes when shutdown starts; inflight holds receipts of jobs
+	// being processed so their visibility can be extended while draining.
+	stopped  <-chan struct{}
+	inflight inflightSet
 
 	cfg workerConfig
 	wg  sync.WaitGroup
@@ -75,11 +133,46 @@ type workerConfig struct {
 	transcript       *SMSTranscriptStore
 	convStore        *ConversationStore
 	moxieClient      *moxieclient.Client
+	writebacks       WritebackAttemptStore
 	leadsRepo        leads.Repository
 	manualHandoff    *booking.ManualHandoffAdapter
 	voiceCaller      VoiceCallInitiator
+	callbackTasks    CallbackTaskStore
+	callbackNotifier CallbackNotifier
+	frustration      *FrustrationTracker
+	frustrationAudit FrustrationAuditor
 	igMessenger      ReplyMessenger
 	webChatMessenger ReplyMessenger
+	scheduler        *Scheduler
+	reaper           *JobReaper
+
+	progressInitialDelay time.Duration
+	progressMinInterval  time.Duration
+
+	orgConcurrency  int
+	orgRequeueDelay time.Duration
+
+	drainTimeout time.Duration
+
+	flagTTL time.Duration
+
+	batcher *InboundBatcher
+
+	convLocker *ConversationLocker
+
+	crmEvents CRMEventPublisher
+
+	paymentClaims PaymentClaimChecker
+
+	mediaFetcher MediaFetcher
+
+	turnTimings        TurnTimingStore
+	replyLatencyBudget time.Duration
+
+	// reengageQuietStart and reengageQuietEnd bound the daily window, in the
+	// clinic's timezone, when re-engagement nudges are held back.
+	reengageQuietStart string
+	reengageQuietEnd   string
 }
 
 const (
@@ -119,6 +212,55 @@ func WithWorkerCount(count int) WorkerOption {
 	}
 }
 
+// WithOrgConcurrency caps how many jobs from one org run at once in this
+// worker, on top of the global worker count. Jobs over the cap go back on the
+// queue after requeueDelay instead of taking a slot another clinic could use.
+// A non-positive limit disables the cap; a non-positive delay keeps the default.
+func WithOrgConcurrency(limit int, requeueDelay time.Duration) WorkerOption {
+	return func(cfg *workerConfig) {
+		cfg.orgConcurrency = limit
+		if requeueDelay > 0 {
+			cfg.orgRequeueDelay = requeueDelay
+		}
+	}
+}
+
+// WithDrainTimeout sets how long in-flight jobs may keep running after
+// shutdown starts before they are interrupted and requeued.
+func WithDrainTimeout(timeout time.Duration) WorkerOption {
+	return func(cfg *workerConfig) {
+		if timeout > 0 {
+			cfg.drainTimeout = timeout
+		}
+	}
+}
+
+// WithFeatureFlagTTL sets how long the worker caches a clinic's feature
+// flags before reloading them from the clinic store.
+func WithFeatureFlagTTL(ttl time.Duration) WorkerOption {
+	return func(cfg *workerConfig) {
+		if ttl > 0 {
+			cfg.flagTTL = ttl
+		}
+	}
+}
+
+// WithInboundBatcher coalesces rapid-fire SMS texts in a conversation into
+// one LLM turn. A nil batcher leaves every text processed on its own.
+func WithInboundBatcher(batcher *InboundBatcher) WorkerOption {
+	return func(cfg *workerConfig) {
+		cfg.batcher = batcher
+	}
+}
+
+// WithConversationLocker serializes jobs for the same conversation across
+// worker replicas. A nil locker leaves jobs unlocked.
+func WithConversationLocker(locker *ConversationLocker) WorkerOption {
+	return func(cfg *workerConfig) {
+		cfg.convLocker = locker
+	}
+}
+
 // WithReceiveWaitSeconds sets the SQS long-poll wait duration.
 func WithReceiveWaitSeconds(seconds int) WorkerOption {
 	return func(cfg *workerConfig) {
@@ -238,6 +380,15 @@ func WithWorkerMoxieClient(client *moxieclient.Client) WorkerOption {
 	}
 }
 
+// WithEMRWritebackAttempts records each attempt to create a booking's
+// appointment on the clinic's EMR, so a retried or redelivered booking
+// doesn't create it twice.
+func WithEMRWritebackAttempts(store WritebackAttemptStore) WorkerOption {
+	return func(cfg *workerConfig) {
+		cfg.writebacks = store
+	}
+}
+
 // WithWorkerLeadsRepo wires a leads repository for booking session updates.
 func WithWorkerLeadsRepo(repo leads.Repository) WorkerOption {
 	return func(cfg *workerConfig) {
@@ -245,6 +396,46 @@ func WithWorkerLeadsRepo(repo leads.Repository) WorkerOption {
 	}
 }
 
+// WithWorkerCRMEvents wires a publisher for lead created, deposit paid and
+// booking confirmed events sent to clinic CRM webhooks.
+func WithWorkerCRMEvents(publisher CRMEventPublisher) WorkerOption {
+	return func(cfg *workerConfig) {
+		cfg.crmEvents = publisher
+	}
+}
+
+// WithTurnTimings keeps each reply's latency breakdown for the admin
+// conversation timeline.
+func WithTurnTimings(store TurnTimingStore) WorkerOption {
+	return func(cfg *workerConfig) {
+		cfg.turnTimings = store
+	}
+}
+
+// WithReplyLatencyBudget sets the inbound-to-reply time over which a reply
+// is counted as late; zero or less disables the count.
+func WithReplyLatencyBudget(budget time.Duration) WorkerOption {
+	return func(cfg *workerConfig) {
+		cfg.replyLatencyBudget = budget
+	}
+}
+
+// WithMediaFetcher replaces the HTTP client used to download texted contact
+// cards.
+func WithMediaFetcher(fetcher MediaFetcher) WorkerOption {
+	return func(cfg *workerConfig) {
+		cfg.mediaFetcher = fetcher
+	}
+}
+
+// WithPaymentClaimChecker checks a pending deposit with the payment provider
+// as soon as the patient says they paid, instead of waiting on the webhook.
+func WithPaymentClaimChecker(checker PaymentClaimChecker) WorkerOption {
+	return func(cfg *workerConfig) {
+		cfg.paymentClaims = checker
+	}
+}
+
 // WithManualHandoff wires a manual handoff adapter for non-Moxie clinics.
 func WithManualHandoff(adapter *booking.ManualHandoffAdapter) WorkerOption {
 	return func(cfg *workerConfig) {
@@ -266,6 +457,34 @@ func WithWebChatMessenger(m ReplyMessenger) WorkerOption {
 	}
 }
 
+// WithScheduler runs a scheduler alongside the consumers that publishes
+// delayed jobs when they come due.
+func WithScheduler(scheduler *Scheduler) WorkerOption {
+	return func(cfg *workerConfig) {
+		cfg.scheduler = scheduler
+	}
+}
+
+// WithReengagementQuietHours holds re-engagement nudges due between start
+// and end ("HH:MM", the clinic's local time) until the window closes. Empty
+// values keep the default 21:00-08:00 window.
+func WithReengagementQuietHours(start, end string) WorkerOption {
+	return func(cfg *workerConfig) {
+		if start != "" && end != "" {
+			cfg.reengageQuietStart = start
+			cfg.reengageQuietEnd = end
+		}
+	}
+}
+
+// WithJobReaper runs a reaper alongside the consumers that resolves jobs a
+// crashed worker left pending.
+func WithJobReaper(reaper *JobReaper) WorkerOption {
+	return func(cfg *workerConfig) {
+		cfg.reaper = reaper
+	}
+}
+
 // WithVoiceCaller wires a Telnyx voice client for initiating outbound AI callbacks.
 func WithVoiceCaller(caller VoiceCallInitiator) WorkerOption {
 	return func(cfg *workerConfig) {
@@ -273,11 +492,78 @@ func WithVoiceCaller(caller VoiceCallInitiator) WorkerOption {
 	}
 }
 
+// WithCallbackTasks enables operator callback tasks: when a patient asks to be
+// called, a task is recorded, operators are notified, and AI replies pause
+// until the task is resolved.
+func WithCallbackTasks(store CallbackTaskStore, notifier CallbackNotifier) WorkerOption {
+	return func(cfg *workerConfig) {
+		cfg.callbackTasks = store
+		cfg.callbackNotifier = notifier
+	}
+}
+
+// WithFrustrationTracking scores inbound texts for frustration and, once a
+// conversation crosses the tracker's threshold, opens a frustration callback
+// task, notifies operators, and sends one handoff reply. Pair it with
+// WithCallbackTasks so the handoff pauses AI replies. A nil tracker disables
+// scoring; auditor may be nil.
+func WithFrustrationTracking(tracker *FrustrationTracker, auditor FrustrationAuditor) WorkerOption {
+	return func(cfg *workerConfig) {
+		cfg.frustration = tracker
+		cfg.frustrationAudit = auditor
+	}
+}
+
+// WithProgressGating configures progress SMS gating: the first update is held
+// for initialDelay (and dropped if the reply is ready first), and later
+// updates are spaced at least minInterval apart. Non-positive values keep
+// the defaults.
+func WithProgressGating(initialDelay, minInterval time.Duration) WorkerOption {
+	return func(cfg *workerConfig) {
+		if initialDelay > 0 {
+			cfg.progressInitialDelay = initialDelay
+		}
+		if minInterval > 0 {
+			cfg.progressMinInterval = minInterval
+		}
+	}
+}
+
 // bookingConfirmer confirms a booking for a lead after payment.
 type bookingConfirmer interface {
 	ConfirmBooking(ctx context.Context, orgID uuid.UUID, leadID uuid.UUID, scheduledFor *time.Time) error
 }
 
+// bookingDurationConfirmer is implemented by booking confirmers that can
+// record the appointment's length on the booking.
+type bookingDurationConfirmer interface {
+	ConfirmBookingWithDuration(ctx context.Context, orgID uuid.UUID, leadID uuid.UUID, scheduledFor *time.Time, durationMinutes int) error
+}
+
+// bookingDisputeFlagger is implemented by booking confirmers that can mark a
+// lead's bookings as disputed for operator review.
+type bookingDisputeFlagger interface {
+	FlagDisputed(ctx context.Context, orgID uuid.UUID, leadID uuid.UUID) (int64, error)
+}
+
+// providerLoadCounter is implemented by booking confirmers that can count
+// recent bookings per provider for "no preference" assignment.
+type providerLoadCounter interface {
+	ProviderBookingCounts(ctx context.Context, orgID uuid.UUID, since time.Time) (map[string]int64, error)
+}
+
+// bookingProviderRecorder is implemented by booking confirmers that can store
+// which provider a lead's booking went to.
+type bookingProviderRecorder interface {
+	RecordBookingProvider(ctx context.Context, orgID uuid.UUID, leadID uuid.UUID, providerID string) error
+}
+
+// bookingManualOfferMarker is implemented by booking confirmers that can flag
+// a booking as offered by clinic staff outside the platform's availability.
+type bookingManualOfferMarker interface {
+	MarkBookingManuallyOffered(ctx context.Context, orgID uuid.UUID, leadID uuid.UUID) error
+}
+
 // DepositSender sends deposit/checkout links to patients after qualifying.
 type DepositSender interface {
 	SendDeposit(ctx context.Context, msg MessageRequest, resp *Response) error
@@ -303,10 +589,30 @@ func NewWorker(processor Service, queue queueClient, jobs JobUpdater, messenger
 		receiveWaitSecs:  defaultWaitSeconds,
 		receiveBatchSize: defaultBatchSize,
 		supervisorMode:   SupervisorModeWarn,
+
+		progressInitialDelay: defaultProgressInitialDelay,
+		progressMinInterval:  defaultProgressMinInterval,
+
+		orgConcurrency:  defaultOrgConcurrency,
+		orgRequeueDelay: defaultOrgRequeueDelay,
+
+		drainTimeout: defaultDrainTimeout,
+
+		flagTTL: clinic.DefaultFlagCacheTTL,
+
+		mediaFetcher: newHTTPMediaFetcher(),
+
+		replyLatencyBudget: DefaultReplyLatencyBudget,
 	}
 	for _, opt := range opts {
 		opt(&cfg)
 	}
+	turnLatencyBudget.Set(max(cfg.replyLatencyBudget, 0).Seconds())
+
+	var flags *clinic.FlagCache
+	if cfg.clinicStore != nil {
+		flags = clinic.NewFlagCache(cfg.clinicStore, cfg.flagTTL, logger)
+	}
 
 	return &Worker{
 		processor:        processor,
@@ -327,13 +633,28 @@ func NewWorker(processor Service, queue queueClient, jobs JobUpdater, messenger
 		transcript:       cfg.transcript,
 		convStore:        cfg.convStore,
 		moxieClient:      cfg.moxieClient,
+		writebacks:       cfg.writebacks,
 		leadsRepo:        cfg.leadsRepo,
 		manualHandoff:    cfg.manualHandoff,
 		voiceCaller:      cfg.voiceCaller,
+		callbackTasks:    cfg.callbackTasks,
+		callbackNotifier: cfg.callbackNotifier,
+		frustration:      cfg.frustration,
+		frustrationAudit: cfg.frustrationAudit,
 		igMessenger:      cfg.igMessenger,
 		webChatMessenger: cfg.webChatMessenger,
+		scheduler:        cfg.scheduler,
+		reaper:           cfg.reaper,
 		logger:           logger,
 		events:           NewEventLogger(logger),
+		orgLimits:        newOrgLimiter(cfg.orgConcurrency),
+		flags:            flags,
+		batcher:          cfg.batcher,
+		convLocker:       cfg.convLocker,
+		crmEvents:        cfg.crmEvents,
+		paymentClaims:    cfg.paymentClaims,
+		mediaFetcher:     cfg.mediaFetcher,
+		turnTimings:      cfg.turnTimings,
 		cfg:              cfg,
 	}
 }
@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"strings"
 	"time"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/messaging/templates"
 )
 
 // VoiceCallInitiator can start outbound AI voice calls.
@@ -34,31 +36,32 @@ func (w *Worker) handleCallbackRequest(ctx context.Context, msg MessageRequest)
 	// Determine the AI assistant ID (per-clinic or global)
 	assistantID := cfg.TelnyxAssistantID
 	if assistantID == "" {
-		w.logger.Warn("voice callback: no assistant ID configured",
+		w.log(ctx).Warn("voice callback: no assistant ID configured",
 			"org_id", msg.OrgID)
 		return false
 	}
 
-	w.logger.Info("voice callback: initiating outbound call",
+	w.log(ctx).Info("voice callback: initiating outbound call",
 		"org_id", msg.OrgID,
-		"conversation_id", msg.ConversationID,
 		"to", maskPhone(msg.From),
 	)
 
 	// Send an SMS acknowledgment first
 	if w.messenger != nil {
+		ack := w.clinicConfig(ctx, msg.OrgID).RenderTemplate(templates.VoiceCallbackAck, nil)
 		ackReply := OutboundReply{
 			OrgID:          msg.OrgID,
 			LeadID:         msg.LeadID,
 			ConversationID: msg.ConversationID,
 			To:             msg.From,
 			From:           msg.To,
-			Body:           "Great, I'll call you right back! You should get a call in just a moment.",
+			Body:           ack.Body,
+			Metadata:       ack.Stamp(nil),
 		}
 		sendCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
 		defer cancel()
 		if err := w.messenger.SendReply(sendCtx, ackReply); err != nil {
-			w.logger.Error("voice callback: failed to send ack SMS", "error", err)
+			w.log(ctx).Error("voice callback: failed to send ack SMS", "error", err)
 			// Continue with the call anyway
 		}
 
@@ -69,6 +72,7 @@ func (w *Worker) handleCallbackRequest(ctx context.Context, msg MessageRequest)
 			Body:      ackReply.Body,
 			Timestamp: time.Now(),
 			Kind:      "voice_callback_ack",
+			Metadata:  ack.Stamp(nil),
 		})
 	}
 
@@ -83,20 +87,22 @@ func (w *Worker) handleCallbackRequest(ctx context.Context, msg MessageRequest)
 	defer cancel()
 	resp, err := w.voiceCaller.InitiateCallback(callCtx, callReq)
 	if err != nil {
-		w.logger.Error("voice callback: failed to initiate call",
+		w.log(ctx).Error("voice callback: failed to initiate call",
 			"error", err,
 			"org_id", msg.OrgID,
 			"to", maskPhone(msg.From),
 		)
 		// Send failure SMS
 		if w.messenger != nil {
+			fail := w.clinicConfig(ctx, msg.OrgID).RenderTemplate(templates.VoiceCallbackFailed, nil)
 			failReply := OutboundReply{
 				OrgID:          msg.OrgID,
 				LeadID:         msg.LeadID,
 				ConversationID: msg.ConversationID,
 				To:             msg.From,
 				From:           msg.To,
-				Body:           "I'm sorry, I wasn't able to call you right now. Let's continue by text — how can I help?",
+				Body:           fail.Body,
+				Metadata:       fail.Stamp(nil),
 			}
 			failCtx, failCancel := context.WithTimeout(ctx, 5*time.Second)
 			defer failCancel()
@@ -109,12 +115,13 @@ func (w *Worker) handleCallbackRequest(ctx context.Context, msg MessageRequest)
 				Body:      failReply.Body,
 				Timestamp: time.Now(),
 				Kind:      "voice_callback_failed",
+				Metadata:  fail.Stamp(nil),
 			})
 		}
 		return true // We handled it (even though it failed)
 	}
 
-	w.logger.Info("voice callback: call initiated",
+	w.log(ctx).Info("voice callback: call initiated",
 		"org_id", msg.OrgID,
 		"call_control_id", resp.CallControlID,
 		"to", maskPhone(msg.From),
@@ -140,7 +147,6 @@ func voiceConvID(orgID, phone string) string {
 	if orgID == "" || phone == "" {
 		return ""
 	}
-	digits := sanitizeDigits(phone)
-	digits = normalizeUSDigits(digits)
+	digits := canonicalPhoneDigits(phone)
 	return fmt.Sprintf("voice:%s:%s", orgID, digits)
 }
@@ -0,0 +1,112 @@
+package conversation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/notify"
+)
+
+// VoicemailRequest describes a voicemail a caller left after the IVR
+// greeting.
+type VoicemailRequest struct {
+	OrgID          string `json:"org_id"`
+	LeadID         string `json:"lead_id"`
+	ConversationID string `json:"conversation_id"`
+	// From is the caller and To the clinic number they dialed.
+	From    string `json:"from"`
+	To      string `json:"to"`
+	CallSID string `json:"call_sid"`
+	// RecordingID is the provider's recording SID; it identifies the
+	// voicemail across callback retries.
+	RecordingID     string    `json:"recording_id"`
+	RecordingURL    string    `json:"recording_url"`
+	DurationSeconds int       `json:"duration_seconds"`
+	ReceivedAt      time.Time `json:"received_at"`
+}
+
+// VoicemailPublisher enqueues voicemail jobs. The queue publisher implements
+// it; voice webhooks type-assert their publisher for it.
+type VoicemailPublisher interface {
+	EnqueueVoicemail(ctx context.Context, req VoicemailRequest) error
+}
+
+// VoicemailNotifier alerts clinic operators to a voicemail. The payment
+// notifier implements it when operator alerts are available.
+type VoicemailNotifier interface {
+	NotifyVoicemail(ctx context.Context, orgID string, vm notify.Voicemail) error
+}
+
+// handleVoicemail adds a caller's voicemail to their conversation and alerts
+// operators with a link to the recording. The patient isn't texted; the
+// voicemail is for staff to return.
+func (w *Worker) handleVoicemail(ctx context.Context, req *VoicemailRequest) error {
+	if req == nil {
+		return errors.New("conversation: missing voicemail payload")
+	}
+	if strings.TrimSpace(req.RecordingURL) == "" {
+		return errors.New("conversation: voicemail has no recording url")
+	}
+	key := strings.TrimSpace(req.RecordingID)
+	if w.processed != nil && key != "" {
+		already, err := w.processed.AlreadyProcessed(ctx, "conversation.voicemail", key)
+		if err != nil {
+			w.log(ctx).Warn("failed to check voicemail idempotency", "error", err, "recording_id", key)
+		} else if already {
+			w.log(ctx).Info("skipping duplicate voicemail", "recording_id", key)
+			return nil
+		}
+	}
+	receivedAt := req.ReceivedAt
+	if receivedAt.IsZero() {
+		receivedAt = time.Now().UTC()
+	}
+
+	w.appendTranscript(ctx, req.ConversationID, SMSTranscriptMessage{
+		Role:      "user",
+		From:      req.From,
+		To:        req.To,
+		Body:      voicemailTranscriptBody(req.DurationSeconds, req.RecordingURL),
+		Timestamp: receivedAt,
+		Kind:      "voicemail",
+		Metadata: map[string]string{
+			"call_sid":         req.CallSID,
+			"recording_id":     req.RecordingID,
+			"recording_url":    req.RecordingURL,
+			"duration_seconds": strconv.Itoa(req.DurationSeconds),
+		},
+	})
+
+	if notifier, ok := w.notifier.(VoicemailNotifier); ok {
+		if err := notifier.NotifyVoicemail(ctx, req.OrgID, notify.Voicemail{
+			LeadID:          req.LeadID,
+			Phone:           req.From,
+			RecordingURL:    req.RecordingURL,
+			DurationSeconds: req.DurationSeconds,
+			ReceivedAt:      receivedAt,
+		}); err != nil {
+			w.log(ctx).Error("failed to notify clinic about voicemail", "error", err, "org_id", req.OrgID, "lead_id", req.LeadID)
+		}
+	} else {
+		w.log(ctx).Warn("voicemail received but no voicemail notifier configured", "org_id", req.OrgID, "lead_id", req.LeadID)
+	}
+
+	if w.processed != nil && key != "" {
+		if _, err := w.processed.MarkProcessed(ctx, "conversation.voicemail", key); err != nil {
+			w.log(ctx).Warn("failed to mark voicemail processed", "error", err, "recording_id", key)
+		}
+	}
+	return nil
+}
+
+// voicemailTranscriptBody is how a voicemail reads in the conversation.
+func voicemailTranscriptBody(seconds int, url string) string {
+	if seconds > 0 {
+		return fmt.Sprintf("Voicemail (%ds): %s", seconds, url)
+	}
+	return "Voicemail: " + url
+}
@@ -0,0 +1,73 @@
+package conversation
+
+import (
+	"context"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/events"
+	"github.com/wolfman30/medspa-ai-platform/internal/notify"
+	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
+)
+
+type recordingVoicemailNotifier struct {
+	voicemails []notify.Voicemail
+}
+
+func (n *recordingVoicemailNotifier) NotifyPaymentSuccess(ctx context.Context, evt events.PaymentSucceededV1) error {
+	return nil
+}
+
+func (n *recordingVoicemailNotifier) NotifyVoicemail(ctx context.Context, orgID string, vm notify.Voicemail) error {
+	n.voicemails = append(n.voicemails, vm)
+	return nil
+}
+
+func TestWorker_VoicemailStoredAndClinicNotifiedOnce(t *testing.T) {
+	mr := miniredis.RunT(t)
+	transcript := NewSMSTranscriptStore(redis.NewClient(&redis.Options{Addr: mr.Addr()}))
+	notifier := &recordingVoicemailNotifier{}
+	messenger := &recordingMessenger{}
+	worker := NewWorker(&recordingService{}, newScriptedQueue(), &stubJobUpdater{}, messenger, nil, logging.Default(),
+		WithPaymentNotifier(notifier),
+		WithSMSTranscriptStore(transcript),
+		WithProcessedEventsStore(&stubProcessedStore{seen: map[string]bool{}}))
+
+	req := &VoicemailRequest{
+		OrgID:           "org-1",
+		LeadID:          "lead-1",
+		ConversationID:  "sms:org-1:15005550002",
+		From:            "+15005550002",
+		To:              "+15005550100",
+		CallSID:         "CA1",
+		RecordingID:     "RE1",
+		RecordingURL:    "https://api.twilio.com/rec/RE1",
+		DurationSeconds: 42,
+	}
+	// The provider retries status callbacks; the second delivery is a no-op.
+	for i := 0; i < 2; i++ {
+		if err := worker.handleVoicemail(context.Background(), req); err != nil {
+			t.Fatalf("handle voicemail: %v", err)
+		}
+	}
+
+	if len(notifier.voicemails) != 1 {
+		t.Fatalf("expected one clinic alert, got %d", len(notifier.voicemails))
+	}
+	vm := notifier.voicemails[0]
+	if vm.LeadID != "lead-1" || vm.Phone != "+15005550002" || vm.RecordingURL != req.RecordingURL || vm.DurationSeconds != 42 {
+		t.Fatalf("unexpected alert: %+v", vm)
+	}
+	msgs, err := transcript.List(context.Background(), req.ConversationID, 0)
+	if err != nil {
+		t.Fatalf("list transcript: %v", err)
+	}
+	if len(msgs) != 1 || msgs[0].Kind != "voicemail" || msgs[0].Body != "Voicemail (42s): https://api.twilio.com/rec/RE1" {
+		t.Fatalf("expected the voicemail in the transcript, got %+v", msgs)
+	}
+	if len(messenger.replies) != 0 {
+		t.Fatal("voicemail should not text the caller")
+	}
+}
@@ -41,7 +41,7 @@ func (w *Worker) sendWebChatReply(ctx context.Context, payload queuePayload, res
 		for _, reason := range leakResult.Reasons {
 			w.events.OutputGuardTriggered(ctx, resp.ConversationID, msg.OrgID, reason)
 		}
-		w.logger.Warn("output guard: sensitive data leak detected (webchat)",
+		w.log(ctx).Warn("output guard: sensitive data leak detected (webchat)",
 			"conversation_id", resp.ConversationID,
 			"org_id", msg.OrgID,
 			"reasons", leakResult.Reasons,
@@ -83,7 +83,7 @@ func (w *Worker) sendWebChatReply(ctx context.Context, payload queuePayload, res
 		defer cancel()
 
 		if err := w.webChatMessenger.SendReply(sendCtx, reply); err != nil {
-			w.logger.Error("failed to send webchat reply", "error", err, "job_id", payload.ID, "org_id", msg.OrgID)
+			w.log(ctx).Error("failed to send webchat reply", "error", err, "org_id", msg.OrgID)
 		}
 	}
 
@@ -0,0 +1,241 @@
+package conversation
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/clinic"
+	"github.com/wolfman30/medspa-ai-platform/internal/leads"
+	"github.com/wolfman30/medspa-ai-platform/internal/messaging/templates"
+	"github.com/wolfman30/medspa-ai-platform/internal/tenancy"
+)
+
+var (
+	// wrongNumberPhraseRE matches a patient saying outright the text wasn't
+	// meant for us. A bare "who is this?" is left to the LLM.
+	wrongNumberPhraseRE = regexp.MustCompile(`(?i)\b(?:` +
+		`wrong (?:number|#|person|phone|business|place)` +
+		`|(?:texted|texting|messaged|have|got) the wrong\b` +
+		`|meant (?:to (?:text|message) |for )(?:someone|somebody) else` +
+		`|not who i (?:meant|was trying) to (?:text|reach)` +
+		`)`)
+
+	// businessAskRE captures the business a patient thinks they're texting:
+	// "Is this Tony's Pizza?", "Hi, am I texting the nail salon?".
+	businessAskRE = regexp.MustCompile(`(?i)(?:^|[.!?]\s+)(?:(?:hi|hey|hello|um|uh)[,!.]?\s+)?` +
+		`(?:is this|is that|are you|am i (?:texting|talking to|speaking (?:to|with))|i'?m (?:looking for|trying to reach))` +
+		`\s+(?:the\s+|a\s+|an\s+)?([^?.!,\n]{2,60})`)
+
+	// medSpaTermRE marks a business or message as being about med spa care.
+	medSpaTermRE = regexp.MustCompile(`(?i)\b(?:med ?spa|medical spa|spa|aesthetics?|clinic|injectors?|injectables?|dermatolog\w*|skin ?care|wellness|treatments?)\b`)
+
+	// otherBusinessRE names businesses patients mix us up with.
+	otherBusinessRE = regexp.MustCompile(`(?i)\b(?:nails?|salon|barber\w*|hair|dentist|dental|orthodont\w*|pizza\w*|restaurant|cafe|diner|grill|bakery|auto|mechanic|garage|tires?|pharmacy|vet|veterinar\w*|bank|gym|fitness|attorney|lawyer|law (?:office|firm)|realty|real estate|plumb\w*|insurance|pediatric\w*|chiropract\w*|daycare|school|church|hotel|car wash|landscap\w*)\b`)
+
+	// interestedRE matches a patient saying they do want our services.
+	interestedRE = regexp.MustCompile(`(?i)\b(?:i'?m|i am|actually|yes,? i'?m)\s+(?:\w+\s+)?interested\b`)
+
+	// personalContactRE matches a patient writing as a friend or coworker
+	// would: "It's Steve from work", "hey bro".
+	personalContactRE = regexp.MustCompile(`(?i)\b(?:it'?s|it is|this is)\s+[a-z]+\s+from\s+(?:work|school|class|church|the gym|the office|next door)\b|\b(?:bro|dude|buddy|babe)\b`)
+
+	// questionOpenerRE starts a message that asks something without a
+	// question mark: "what is this", "can you tell me more".
+	questionOpenerRE = regexp.MustCompile(`(?i)^\W*(?:(?:hi|hey|hello|ok|okay|so|oh|wait|sorry)\W+)*(?:who|what|when|where|why|how|which|can|could|do|does|is|are|will|would)\b`)
+)
+
+// wrongNumberNameStopwords are words too generic to tell one business from
+// another when comparing names.
+var wrongNumberNameStopwords = map[string]bool{
+	"the": true, "and": true, "med": true, "medical": true, "spa": true, "medspa": true,
+	"clinic": true, "aesthetic": true, "aesthetics": true, "center": true, "centre": true,
+	"studio": true, "wellness": true, "beauty": true, "skin": true, "care": true,
+	"llc": true, "inc": true, "office": true, "place": true, "number": true,
+}
+
+// notBusinessWords open "is this ..." questions that aren't about which
+// business the patient reached: "Is this AI?", "Are you open Saturday?".
+var notBusinessWords = map[string]bool{
+	"ai": true, "a": true, "an": true, "i": true, "ok": true, "okay": true, "it": true,
+	"this": true, "that": true, "still": true, "real": true, "human": true, "bot": true,
+	"robot": true, "automated": true, "open": true, "closed": true, "free": true,
+	"available": true, "legit": true, "spam": true, "yes": true, "no": true, "sure": true,
+	"monday": true, "tuesday": true, "wednesday": true, "thursday": true, "friday": true,
+	"saturday": true, "sunday": true, "today": true, "tomorrow": true,
+	"january": true, "february": true, "march": true, "april": true, "may": true, "june": true,
+	"july": true, "august": true, "september": true, "october": true, "november": true, "december": true,
+}
+
+// isWrongNumberMessage reports whether msg reads as meant for another
+// business: an explicit "wrong number", or asking for a business or person
+// that isn't the clinic or one of its providers.
+func isWrongNumberMessage(msg string, cfg *clinic.Config) bool {
+	if wrongNumberPhraseRE.MatchString(msg) {
+		return true
+	}
+	personal := personalContactRE.MatchString(msg)
+	for _, m := range businessAskRE.FindAllStringSubmatch(msg, -1) {
+		if isOtherBusiness(m[1], cfg, personal) {
+			return true
+		}
+	}
+	return false
+}
+
+// isOtherBusiness reports whether the business a patient asked for is not
+// this clinic. A bare first name ("Is this Sarah?") may be staff the config
+// doesn't list, so it only counts when personal is set, i.e. the patient
+// also wrote as a friend would.
+func isOtherBusiness(asked string, cfg *clinic.Config, personal bool) bool {
+	words := strings.Fields(asked)
+	if len(words) == 0 {
+		return false
+	}
+	if len(words) > 5 {
+		words = words[:5]
+	}
+	asked = strings.Join(words, " ")
+	known := append(cfg.BusinessNames(), cfg.ProviderDisplayNames()...)
+	switch {
+	case sharesNameToken(asked, known):
+		return false
+	case medSpaTermRE.MatchString(asked), matchService(strings.ToLower(asked), serviceAliasesFromConfig(cfg)) != "":
+		return false
+	case otherBusinessRE.MatchString(asked):
+		return true
+	}
+	// A proper name that isn't ours. Without a config there is nothing to
+	// compare it with.
+	first := []rune(words[0])
+	name := strings.ToLower(strings.Trim(words[0], "'’"))
+	if cfg == nil || !unicode.IsUpper(first[0]) || notBusinessWords[name] {
+		return false
+	}
+	// "Is this Tony's?" names a business; "Is this Mike?" needs the
+	// personal context.
+	possessive := strings.HasSuffix(name, "'s") || strings.HasSuffix(name, "’s")
+	return possessive || personal
+}
+
+// sharesNameToken reports whether asked and any of names share a
+// distinctive word.
+func sharesNameToken(asked string, names []string) bool {
+	askedTokens := nameTokens(asked)
+	if len(askedTokens) == 0 {
+		return false
+	}
+	for _, name := range names {
+		for token := range nameTokens(name) {
+			if askedTokens[token] {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// nameTokens splits a business or person name into its distinctive
+// lowercase words, dropping possessives and generic words.
+func nameTokens(name string) map[string]bool {
+	name = strings.NewReplacer("'s", "", "’s", "").Replace(strings.ToLower(name))
+	out := make(map[string]bool)
+	for _, word := range strings.FieldsFunc(name, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		if len(word) >= 3 && !wrongNumberNameStopwords[word] && !notBusinessWords[word] {
+			out[word] = true
+		}
+	}
+	return out
+}
+
+// showsMedSpaIntent reports whether msg asks about booking, pricing or a
+// service, which overrides a wrong-number read.
+func showsMedSpaIntent(msg string, cfg *clinic.Config) bool {
+	return containsBookingIntent(msg) ||
+		isPriceInquiry(msg) ||
+		interestedRE.MatchString(msg) ||
+		matchService(strings.ToLower(msg), serviceAliasesFromConfig(cfg)) != ""
+}
+
+// asksQuestion reports whether msg is shaped like a question.
+func asksQuestion(msg string) bool {
+	return strings.Contains(msg, "?") || questionOpenerRE.MatchString(msg)
+}
+
+// wrongNumberReply renders the correction naming the clinic.
+func wrongNumberReply(cfg *clinic.Config) templates.Rendered {
+	return cfg.RenderTemplate(templates.AckWrongNumber, templates.Params{"clinic_name": wrongNumberClinicName(cfg)})
+}
+
+// wrongNumberLaterReply renders the short clinic intro sent to later texts
+// from a flagged lead.
+func wrongNumberLaterReply(cfg *clinic.Config) templates.Rendered {
+	return cfg.RenderTemplate(templates.AckWrongNumberLater, templates.Params{"clinic_name": wrongNumberClinicName(cfg)})
+}
+
+// wrongNumberClinicName is the clinic name for the wrong-number replies.
+func wrongNumberClinicName(cfg *clinic.Config) string {
+	if cfg != nil && strings.TrimSpace(cfg.Name) != "" {
+		return strings.TrimSpace(cfg.Name)
+	}
+	return "a med spa"
+}
+
+// handleWrongNumber answers a text meant for another business with a short
+// correction and flags the lead, skipping qualification. Later texts from a
+// flagged lead get a short clinic intro until the patient shows real interest
+// or asks a question, which clears the flag and hands the message on.
+func (s *LLMService) handleWrongNumber(ctx context.Context, pc *processContext) *Response {
+	var lead *leads.Lead
+	if s.leadsRepo != nil && pc.req.LeadID != "" {
+		if found, err := s.leadsRepo.GetByID(ctx, tenancy.ForOrg(pc.req.OrgID), pc.req.LeadID); err == nil {
+			lead = found
+		}
+	}
+	intent := showsMedSpaIntent(pc.rawMessage, pc.cfg)
+
+	if lead != nil && lead.ProbableWrongNumber {
+		if intent || asksQuestion(pc.rawMessage) {
+			s.log(ctx).Info("ProcessMessage: wrong-number lead re-engaged", "conversation_id", pc.req.ConversationID, "lead_id", lead.ID)
+			s.setProbableWrongNumber(ctx, lead.ID, false)
+			return nil
+		}
+		return s.saveWithoutQualifying(ctx, pc, wrongNumberLaterReply(pc.cfg))
+	}
+	if intent || !isWrongNumberMessage(pc.rawMessage, pc.cfg) {
+		return nil
+	}
+
+	s.log(ctx).Info("ProcessMessage: probable wrong number", "conversation_id", pc.req.ConversationID, "lead_id", pc.req.LeadID)
+	s.setProbableWrongNumber(ctx, pc.req.LeadID, true)
+	return s.saveWithoutQualifying(ctx, pc, wrongNumberReply(pc.cfg))
+}
+
+// saveWithoutQualifying records reply in the history and returns it without
+// extracting lead preferences.
+func (s *LLMService) saveWithoutQualifying(ctx context.Context, pc *processContext, reply templates.Rendered) *Response {
+	pc.history = append(pc.history, ChatMessage{Role: ChatRoleAssistant, Content: reply.Body})
+	pc.history = trimHistory(pc.history, maxHistoryMessages)
+	if err := s.history.Save(ctx, pc.req.ConversationID, pc.history); err != nil {
+		pc.span.RecordError(err)
+	}
+	return &Response{ConversationID: pc.req.ConversationID, Message: reply.Body, Template: reply.Ref, Timestamp: time.Now().UTC()}
+}
+
+// setProbableWrongNumber flags or clears the lead as a wrong number.
+func (s *LLMService) setProbableWrongNumber(ctx context.Context, leadID string, wrong bool) {
+	if s.leadsRepo == nil || leadID == "" {
+		return
+	}
+	repo, ok := s.leadsRepo.(leads.WrongNumberRepository)
+	if !ok {
+		return
+	}
+	if err := repo.SetProbableWrongNumber(ctx, leadID, wrong); err != nil {
+		s.log(ctx).Warn("failed to update wrong-number flag", "error", err, "lead_id", leadID, "wrong_number", wrong)
+	}
+}
@@ -0,0 +1,156 @@
+package conversation
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/clinic"
+	"github.com/wolfman30/medspa-ai-platform/internal/leads"
+	"github.com/wolfman30/medspa-ai-platform/internal/messaging/templates"
+	"github.com/wolfman30/medspa-ai-platform/internal/tenancy"
+	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
+)
+
+func glowClinic(orgID string) *clinic.Config {
+	cfg := clinic.DefaultConfig(orgID)
+	cfg.Name = "Glow Aesthetics"
+	cfg.NameAliases = []string{"Radiance Skin Bar"}
+	cfg.AIPersona.ProviderName = "Brandi"
+	cfg.ProviderNames = map[string]string{"p1": "Dana Lee"}
+	return cfg
+}
+
+func TestIsWrongNumberMessage(t *testing.T) {
+	cfg := glowClinic("org-1")
+	cases := map[string]bool{
+		"Sorry, wrong number":                        true,
+		"I think I texted the wrong person":          true,
+		"oops this was meant for someone else":       true,
+		"Is this Tony's Pizza?":                      true,
+		"Hi, am I texting the nail salon?":           true,
+		"Hey is this Mike? It's Steve from work":     true,
+		"Are you the dentist office on 5th?":         true,
+		"Is this Tony's?":                            true,
+		"Is this Sarah?":                             false,
+		"Hi, is this Jen? I had a question":          false,
+		"Is this Glow?":                              false,
+		"Is this Radiance Skin Bar?":                 false,
+		"Is this Brandi?":                            false,
+		"Is this Dana's number?":                     false,
+		"Is this the med spa?":                       false,
+		"Is this AI or a real person?":               false,
+		"Are you open Saturday?":                     false,
+		"Who is this?":                               false,
+		"How much is that?":                          false,
+		"Is this where I book Botox?":                false,
+		"I gave you my number, can you text me back": false,
+	}
+	for msg, want := range cases {
+		if got := isWrongNumberMessage(msg, cfg); got != want {
+			t.Errorf("isWrongNumberMessage(%q) = %v, want %v", msg, got, want)
+		}
+	}
+}
+
+func TestLLMService_WrongNumberSkipsQualificationUntilReengaged(t *testing.T) {
+	mr := miniredis.RunT(t)
+	ctx := context.Background()
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	clinicStore := clinic.NewStore(client)
+	if err := clinicStore.Set(ctx, glowClinic("org-1")); err != nil {
+		t.Fatalf("set clinic config: %v", err)
+	}
+	leadsRepo := leads.NewInMemoryRepository()
+	lead, err := leadsRepo.Create(ctx, &leads.CreateLeadRequest{OrgID: "org-1", Phone: "+15550000000", Source: "sms"})
+	if err != nil {
+		t.Fatalf("create lead: %v", err)
+	}
+
+	mockLLM := &stubLLMClient{response: LLMResponse{Text: "Botox starts at $12 per unit."}}
+	service := NewLLMService(mockLLM, client, nil, "anthropic.claude-3-haiku-20240307-v1:0", logging.Default(),
+		WithClinicStore(clinicStore), WithLeadsRepo(leadsRepo))
+
+	start, err := service.StartConversation(ctx, StartRequest{
+		ConversationID: "conv-wrong",
+		LeadID:         lead.ID,
+		OrgID:          "org-1",
+		Intro:          "Hey is this Tony's Pizza? This is Sarah Johnson, want to order for Friday",
+		Channel:        ChannelSMS,
+	})
+	if err != nil {
+		t.Fatalf("start failed: %v", err)
+	}
+	if !strings.Contains(start.Message, "Glow Aesthetics") || !strings.Contains(start.Message, "wrong number") {
+		t.Fatalf("start reply = %q, want a correction naming the clinic", start.Message)
+	}
+	if start.Template.ID == "" {
+		t.Fatalf("expected the correction to carry its template ref")
+	}
+
+	flagged := func() *leads.Lead {
+		t.Helper()
+		got, err := leadsRepo.GetByID(ctx, tenancy.ForOrg("org-1"), lead.ID)
+		if err != nil {
+			t.Fatalf("get lead: %v", err)
+		}
+		return got
+	}
+	if got := flagged(); !got.ProbableWrongNumber || got.Name != "" || got.PreferredDays != "" {
+		t.Fatalf("expected a flagged, unqualified lead, got %+v", got)
+	}
+
+	resp, err := service.ProcessMessage(ctx, MessageRequest{
+		ConversationID: start.ConversationID,
+		LeadID:         lead.ID,
+		OrgID:          "org-1",
+		Message:        "Oh sorry! My bad",
+		Channel:        ChannelSMS,
+	})
+	if err != nil {
+		t.Fatalf("process failed: %v", err)
+	}
+	if resp.Template.ID != templates.AckWrongNumberLater || !strings.Contains(resp.Message, "Glow Aesthetics") || mockLLM.calls != 0 {
+		t.Fatalf("expected the clinic intro and no LLM call, got %q (%s) after %d calls", resp.Message, resp.Template.ID, mockLLM.calls)
+	}
+	if !flagged().ProbableWrongNumber {
+		t.Fatalf("expected the lead to stay flagged")
+	}
+
+	resp, err = service.ProcessMessage(ctx, MessageRequest{
+		ConversationID: start.ConversationID,
+		LeadID:         lead.ID,
+		OrgID:          "org-1",
+		Message:        "wait what kind of place is this",
+		Channel:        ChannelSMS,
+	})
+	if err != nil {
+		t.Fatalf("process failed: %v", err)
+	}
+	if !strings.Contains(resp.Message, "Botox") {
+		t.Fatalf("expected re-engagement to be answered, got %q", resp.Message)
+	}
+	if flagged().ProbableWrongNumber {
+		t.Fatalf("expected re-engagement to clear the wrong-number flag")
+	}
+}
+
+func TestAsksQuestion(t *testing.T) {
+	cases := map[string]bool{
+		"What kind of place is this?":  true,
+		"hey what do you guys do":      true,
+		"ok so how does this work":     true,
+		"do you do facials":            true,
+		"Oh sorry! My bad":             false,
+		"thanks, wrong person":         false,
+		"I was looking for the salon.": false,
+	}
+	for msg, want := range cases {
+		if got := asksQuestion(msg); got != want {
+			t.Errorf("asksQuestion(%q) = %v, want %v", msg, got, want)
+		}
+	}
+}
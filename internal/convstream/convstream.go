@@ -0,0 +1,170 @@
+// Package convstream carries live conversation updates (new messages, status
+// transitions and delivery receipts) from the processes that persist them to
+// the operator portal.
+//
+// Every event is appended to a short, expiring Redis stream per conversation
+// and published on a shared pub/sub channel in the same script, so stream IDs
+// and publish order agree. Subscribers get live events from the channel and
+// replay missed ones from the stream after a reconnect (SSE Last-Event-ID).
+package convstream
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Event types.
+const (
+	TypeMessage  = "message"
+	TypeStatus   = "status"
+	TypeDelivery = "delivery"
+)
+
+const (
+	streamKeyPrefix = "convstream:"
+	channel         = "convstream:events"
+
+	// defaultMaxLen bounds each conversation's replay history.
+	defaultMaxLen = 200
+	// defaultTTL is how long a quiet conversation's history is kept.
+	defaultTTL = 24 * time.Hour
+)
+
+// Event is one update to a conversation. ID is the Redis stream ID and is
+// what clients send back as Last-Event-ID.
+type Event struct {
+	ID             string          `json:"id"`
+	Type           string          `json:"type"`
+	ConversationID string          `json:"conversation_id"`
+	At             time.Time       `json:"at"`
+	Data           json.RawMessage `json:"data"`
+}
+
+// StatusUpdate is the payload of a TypeStatus event.
+type StatusUpdate struct {
+	Status string `json:"status"`
+}
+
+// DeliveryUpdate is the payload of a TypeDelivery event.
+type DeliveryUpdate struct {
+	ProviderMessageID string `json:"provider_message_id"`
+	Status            string `json:"status"`
+	ErrorReason       string `json:"error_reason,omitempty"`
+}
+
+func streamKey(conversationID string) string {
+	return streamKeyPrefix + conversationID
+}
+
+// publishScript appends the event to the conversation's stream and publishes
+// it atomically, so two writers can't publish out of stream-ID order.
+var publishScript = redis.NewScript(`
+local id = redis.call('XADD', KEYS[1], 'MAXLEN', '~', ARGV[1], '*', 'event', ARGV[2])
+redis.call('EXPIRE', KEYS[1], ARGV[3])
+redis.call('PUBLISH', ARGV[4], id .. ' ' .. ARGV[2])
+return id
+`)
+
+// Publisher records conversation events. A nil Publisher is a no-op.
+type Publisher struct {
+	redis  *redis.Client
+	maxLen int64
+	ttl    time.Duration
+	now    func() time.Time
+}
+
+// NewPublisher creates a publisher, or returns nil without Redis.
+func NewPublisher(redisClient *redis.Client) *Publisher {
+	if redisClient == nil {
+		return nil
+	}
+	return &Publisher{redis: redisClient, maxLen: defaultMaxLen, ttl: defaultTTL, now: time.Now}
+}
+
+// Publish records an event of the given type and returns its ID.
+func (p *Publisher) Publish(ctx context.Context, conversationID, eventType string, data any) (string, error) {
+	if p == nil || p.redis == nil {
+		return "", nil
+	}
+	conversationID = strings.TrimSpace(conversationID)
+	if conversationID == "" {
+		return "", errors.New("convstream: conversationID required")
+	}
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return "", fmt.Errorf("convstream: marshal %s event: %w", eventType, err)
+	}
+	body, err := json.Marshal(Event{
+		Type:           eventType,
+		ConversationID: conversationID,
+		At:             p.now().UTC(),
+		Data:           raw,
+	})
+	if err != nil {
+		return "", fmt.Errorf("convstream: marshal event: %w", err)
+	}
+	id, err := publishScript.Run(ctx, p.redis,
+		[]string{streamKey(conversationID)},
+		p.maxLen, string(body), int64(p.ttl/time.Second), channel,
+	).Text()
+	if err != nil {
+		return "", fmt.Errorf("convstream: publish %s event: %w", eventType, err)
+	}
+	return id, nil
+}
+
+// decodeEvent rebuilds an event from its stored body and stream ID.
+func decodeEvent(id, body string) (Event, error) {
+	var evt Event
+	if err := json.Unmarshal([]byte(body), &evt); err != nil {
+		return Event{}, fmt.Errorf("convstream: decode event %s: %w", id, err)
+	}
+	evt.ID = id
+	return evt, nil
+}
+
+// After reports whether stream ID a sorts after b. IDs that don't parse never
+// sort after anything.
+func After(a, b string) bool {
+	am, as, ok := parseID(a)
+	if !ok {
+		return false
+	}
+	bm, bs, ok := parseID(b)
+	if !ok {
+		return true
+	}
+	if am != bm {
+		return am > bm
+	}
+	return as > bs
+}
+
+// ValidID reports whether id looks like a Redis stream ID ("<ms>-<seq>").
+func ValidID(id string) bool {
+	_, _, ok := parseID(id)
+	return ok
+}
+
+func parseID(id string) (ms, seq uint64, ok bool) {
+	msPart, seqPart, found := strings.Cut(strings.TrimSpace(id), "-")
+	if !found {
+		return 0, 0, false
+	}
+	ms, err := strconv.ParseUint(msPart, 10, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	seq, err = strconv.ParseUint(seqPart, 10, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	return ms, seq, true
+}
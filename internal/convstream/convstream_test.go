@@ -0,0 +1,134 @@
+package convstream
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func newTestRedis(t *testing.T) *redis.Client {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	return redis.NewClient(&redis.Options{Addr: mr.Addr()})
+}
+
+func next(t *testing.T, ch <-chan Event) Event {
+	t.Helper()
+	select {
+	case evt, ok := <-ch:
+		if !ok {
+			t.Fatal("subscription closed")
+		}
+		return evt
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for event")
+	}
+	return Event{}
+}
+
+func TestPublishDeliversInOrderAndReplays(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	rdb := newTestRedis(t)
+	hub := NewHub(rdb, nil)
+	if err := hub.Start(ctx); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	pub := NewPublisher(rdb)
+
+	live, stop := hub.Subscribe("sms:org-1:15550001111")
+	defer stop()
+	other, stopOther := hub.Subscribe("sms:org-2:15550001111")
+	defer stopOther()
+
+	var ids []string
+	for _, status := range []string{"active", "awaiting_time_selection", "booked"} {
+		id, err := pub.Publish(ctx, "sms:org-1:15550001111", TypeStatus, StatusUpdate{Status: status})
+		if err != nil {
+			t.Fatalf("publish: %v", err)
+		}
+		ids = append(ids, id)
+	}
+	for i, want := range []string{"active", "awaiting_time_selection", "booked"} {
+		evt := next(t, live)
+		var update StatusUpdate
+		if err := json.Unmarshal(evt.Data, &update); err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+		if evt.ID != ids[i] || evt.Type != TypeStatus || update.Status != want {
+			t.Fatalf("event %d = %+v (%s), want id %s status %s", i, evt, update.Status, ids[i], want)
+		}
+	}
+	select {
+	case evt := <-other:
+		t.Fatalf("other conversation got %+v", evt)
+	default:
+	}
+
+	missed, err := hub.Replay(ctx, "sms:org-1:15550001111", ids[0])
+	if err != nil {
+		t.Fatalf("replay: %v", err)
+	}
+	if len(missed) != 2 || missed[0].ID != ids[1] || missed[1].ID != ids[2] {
+		t.Fatalf("replay after %s = %+v", ids[0], missed)
+	}
+	if _, err := hub.Replay(ctx, "sms:org-1:15550001111", "nope"); err == nil {
+		t.Fatal("expected an invalid id to be rejected")
+	}
+}
+
+func TestSlowSubscriberIsClosed(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	rdb := newTestRedis(t)
+	hub := NewHub(rdb, nil)
+	if err := hub.Start(ctx); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	pub := NewPublisher(rdb)
+	live, stop := hub.Subscribe("sms:org-1:15550001111")
+	defer stop()
+
+	for i := 0; i <= subscriberBuffer; i++ {
+		if _, err := pub.Publish(ctx, "sms:org-1:15550001111", TypeStatus, StatusUpdate{Status: "active"}); err != nil {
+			t.Fatalf("publish: %v", err)
+		}
+	}
+	deadline := time.After(2 * time.Second)
+	for received := 0; ; received++ {
+		select {
+		case _, ok := <-live:
+			if !ok {
+				if received != subscriberBuffer {
+					t.Fatalf("closed after %d events, want %d", received, subscriberBuffer)
+				}
+				return
+			}
+		case <-deadline:
+			t.Fatal("expected the lagging subscription to be closed")
+		}
+	}
+}
+
+func TestAfter(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"2-0", "1-5", true},
+		{"1-10", "1-9", true},
+		{"1-9", "1-9", false},
+		{"1-0", "2-0", false},
+		{"bad", "1-0", false},
+		{"1-0", "bad", true},
+	}
+	for _, tt := range tests {
+		if got := After(tt.a, tt.b); got != tt.want {
+			t.Errorf("After(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,167 @@
+package convstream
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
+)
+
+// subscriberBuffer is how many events a slow client may fall behind before
+// its subscription is closed. The client reconnects with Last-Event-ID and
+// catches up from the stream.
+const subscriberBuffer = 64
+
+// Hub fans the shared pub/sub channel out to local subscribers, keyed by
+// conversation. One Redis subscription serves every open stream in the
+// process.
+type Hub struct {
+	redis  *redis.Client
+	logger *logging.Logger
+
+	mu   sync.Mutex
+	subs map[string]map[chan Event]struct{}
+}
+
+// NewHub creates a hub, or returns nil without Redis.
+func NewHub(redisClient *redis.Client, logger *logging.Logger) *Hub {
+	if redisClient == nil {
+		return nil
+	}
+	if logger == nil {
+		logger = logging.Default()
+	}
+	return &Hub{
+		redis:  redisClient,
+		logger: logger,
+		subs:   make(map[string]map[chan Event]struct{}),
+	}
+}
+
+// Start subscribes to the event channel and fans events out until ctx is
+// done. It returns once the subscription is confirmed, so events published
+// afterwards are not missed.
+func (h *Hub) Start(ctx context.Context) error {
+	ps := h.redis.Subscribe(ctx, channel)
+	if _, err := ps.Receive(ctx); err != nil {
+		_ = ps.Close()
+		return fmt.Errorf("convstream: subscribe: %w", err)
+	}
+	go h.run(ctx, ps)
+	return nil
+}
+
+func (h *Hub) run(ctx context.Context, ps *redis.PubSub) {
+	defer ps.Close()
+	defer h.closeAll()
+	msgs := ps.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case msg, ok := <-msgs:
+			if !ok {
+				return
+			}
+			id, body, found := strings.Cut(msg.Payload, " ")
+			if !found {
+				continue
+			}
+			evt, err := decodeEvent(id, body)
+			if err != nil {
+				h.logger.Warn("convstream: dropping malformed event", "error", err)
+				continue
+			}
+			h.dispatch(evt)
+		}
+	}
+}
+
+func (h *Hub) dispatch(evt Event) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for ch := range h.subs[evt.ConversationID] {
+		select {
+		case ch <- evt:
+		default:
+			h.logger.Warn("convstream: subscriber fell behind, closing", "conversation_id", evt.ConversationID)
+			h.removeLocked(evt.ConversationID, ch)
+		}
+	}
+}
+
+// Subscribe returns live events for a conversation and a function that ends
+// the subscription. The channel is closed when the subscriber falls behind
+// or the hub stops.
+func (h *Hub) Subscribe(conversationID string) (<-chan Event, func()) {
+	ch := make(chan Event, subscriberBuffer)
+	h.mu.Lock()
+	if h.subs[conversationID] == nil {
+		h.subs[conversationID] = make(map[chan Event]struct{})
+	}
+	h.subs[conversationID][ch] = struct{}{}
+	h.mu.Unlock()
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			h.mu.Lock()
+			defer h.mu.Unlock()
+			h.removeLocked(conversationID, ch)
+		})
+	}
+}
+
+func (h *Hub) removeLocked(conversationID string, ch chan Event) {
+	subs := h.subs[conversationID]
+	if _, ok := subs[ch]; !ok {
+		return
+	}
+	delete(subs, ch)
+	close(ch)
+	if len(subs) == 0 {
+		delete(h.subs, conversationID)
+	}
+}
+
+func (h *Hub) closeAll() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for conversationID, subs := range h.subs {
+		for ch := range subs {
+			h.removeLocked(conversationID, ch)
+		}
+	}
+}
+
+// Replay returns the conversation's recorded events after afterID, oldest
+// first. History is capped and expires, so very old IDs replay only what is
+// left.
+func (h *Hub) Replay(ctx context.Context, conversationID, afterID string) ([]Event, error) {
+	if !ValidID(afterID) {
+		return nil, errors.New("convstream: invalid event id")
+	}
+	entries, err := h.redis.XRange(ctx, streamKey(conversationID), afterID, "+").Result()
+	if err != nil {
+		return nil, fmt.Errorf("convstream: replay %s: %w", conversationID, err)
+	}
+	out := make([]Event, 0, len(entries))
+	for _, entry := range entries {
+		if !After(entry.ID, afterID) {
+			continue
+		}
+		body, _ := entry.Values["event"].(string)
+		evt, err := decodeEvent(entry.ID, body)
+		if err != nil {
+			h.logger.Warn("convstream: skipping malformed event", "error", err)
+			continue
+		}
+		out = append(out, evt)
+	}
+	return out, nil
+}
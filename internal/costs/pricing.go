@@ -0,0 +1,79 @@
+// Package costs tracks what each clinic's conversations cost to run: SMS
+// segments billed by the carrier and tokens billed by the LLM provider. Raw
+// usage is recorded per message and per completion, rolled up per
+// conversation per day into messaging_costs, and reported per org.
+package costs
+
+import (
+	"strings"
+	"time"
+)
+
+// DefaultSMSSegmentRateUSD is the per-segment estimate used when the provider
+// doesn't report a cost (Telnyx US long-code list price).
+const DefaultSMSSegmentRateUSD = 0.004
+
+// LLMPrice is a model's list price in USD per million tokens.
+type LLMPrice struct {
+	InputPerMTok  float64
+	OutputPerMTok float64
+}
+
+// llmPrices maps a model family, matched as a substring of the model ID, to
+// its price. Bedrock IDs carry region and version decoration
+// ("us.anthropic.claude-3-5-haiku-20241022-v1:0"), so exact IDs won't do.
+// More specific families come first.
+var llmPrices = []struct {
+	family string
+	price  LLMPrice
+}{
+	{"claude-3-haiku", LLMPrice{InputPerMTok: 0.25, OutputPerMTok: 1.25}},
+	{"haiku", LLMPrice{InputPerMTok: 1, OutputPerMTok: 5}},
+	{"sonnet", LLMPrice{InputPerMTok: 3, OutputPerMTok: 15}},
+	{"opus", LLMPrice{InputPerMTok: 15, OutputPerMTok: 75}},
+	{"gemini", LLMPrice{InputPerMTok: 0.3, OutputPerMTok: 2.5}},
+	{"nova", LLMPrice{InputPerMTok: 0.8, OutputPerMTok: 3.2}},
+}
+
+// defaultLLMPrice prices models the table doesn't know. It errs high so an
+// unrecognized model shows up in the report rather than costing nothing.
+var defaultLLMPrice = LLMPrice{InputPerMTok: 3, OutputPerMTok: 15}
+
+// PriceForModel returns the list price for a model ID.
+func PriceForModel(model string) LLMPrice {
+	model = strings.ToLower(model)
+	for _, p := range llmPrices {
+		if strings.Contains(model, p.family) {
+			return p.price
+		}
+	}
+	return defaultLLMPrice
+}
+
+// LLMCostUSD estimates the cost of a completion.
+func LLMCostUSD(model string, inputTokens, outputTokens int64) float64 {
+	price := PriceForModel(model)
+	return (float64(inputTokens)*price.InputPerMTok + float64(outputTokens)*price.OutputPerMTok) / 1e6
+}
+
+// LLMUsage is the token usage of one completion, attributed to the
+// conversation it answered.
+type LLMUsage struct {
+	OrgID          string
+	ConversationID string
+	Model          string
+	InputTokens    int
+	OutputTokens   int
+	At             time.Time
+}
+
+// MessageUsage is one outbound SMS as recorded in the messages table.
+type MessageUsage struct {
+	OrgID    string
+	To       string
+	Segments int
+	// CostUSD is the provider-reported or estimated cost; nil when the
+	// sender recorded none, in which case the rollup estimates it.
+	CostUSD *float64
+	SentAt  time.Time
+}
@@ -0,0 +1,179 @@
+package costs
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DefaultPeriod is the report window when none is requested.
+const DefaultPeriod = "month"
+
+// topConversations caps the most expensive conversations listed in a report.
+const topConversations = 10
+
+// periodDays maps a report period to the number of UTC days it covers,
+// counting today.
+var periodDays = map[string]int{
+	"day":   1,
+	"week":  7,
+	"month": 30,
+}
+
+// PeriodRange returns the [from, to) day range for a period ending today.
+func PeriodRange(period string, now time.Time) (time.Time, time.Time, error) {
+	days, ok := periodDays[strings.ToLower(strings.TrimSpace(period))]
+	if !ok {
+		return time.Time{}, time.Time{}, fmt.Errorf("costs: invalid period %q: must be day|week|month", period)
+	}
+	to := truncateDay(now).AddDate(0, 0, 1)
+	return to.AddDate(0, 0, -days), to, nil
+}
+
+// Report is an org's combined SMS and LLM spend over a period.
+type Report struct {
+	OrgID                  string                `json:"org_id"`
+	Period                 string                `json:"period"`
+	From                   time.Time             `json:"from"`
+	To                     time.Time             `json:"to"`
+	SMS                    SMSSummary            `json:"sms"`
+	LLM                    LLMSummary            `json:"llm"`
+	TotalCostUSD           float64               `json:"total_cost_usd"`
+	Conversations          int                   `json:"conversations"`
+	CostPerConversationUSD float64               `json:"cost_per_conversation_usd"`
+	Days                   []DaySummary          `json:"days"`
+	TopConversations       []ConversationSummary `json:"top_conversations"`
+}
+
+// SMSSummary totals outbound SMS usage.
+type SMSSummary struct {
+	Messages int     `json:"messages"`
+	Segments int     `json:"segments"`
+	CostUSD  float64 `json:"cost_usd"`
+}
+
+// LLMSummary totals LLM usage.
+type LLMSummary struct {
+	Calls        int     `json:"calls"`
+	InputTokens  int64   `json:"input_tokens"`
+	OutputTokens int64   `json:"output_tokens"`
+	CostUSD      float64 `json:"cost_usd"`
+}
+
+// DaySummary is one day's spend.
+type DaySummary struct {
+	Day           string  `json:"day"`
+	Conversations int     `json:"conversations"`
+	SMSCostUSD    float64 `json:"sms_cost_usd"`
+	LLMCostUSD    float64 `json:"llm_cost_usd"`
+	TotalCostUSD  float64 `json:"total_cost_usd"`
+}
+
+// ConversationSummary is one conversation's spend over the period.
+type ConversationSummary struct {
+	ConversationID string  `json:"conversation_id"`
+	Messages       int     `json:"messages"`
+	Segments       int     `json:"segments"`
+	LLMCalls       int     `json:"llm_calls"`
+	SMSCostUSD     float64 `json:"sms_cost_usd"`
+	LLMCostUSD     float64 `json:"llm_cost_usd"`
+	TotalCostUSD   float64 `json:"total_cost_usd"`
+}
+
+// BuildReport combines an org's daily rollup rows into a report. Every day in
+// the range is listed, including days without traffic, so charts don't skip.
+// A conversation that spans days counts once toward the period total.
+func BuildReport(orgID, period string, from, to time.Time, rows []DailyCost) Report {
+	rep := Report{
+		OrgID:            orgID,
+		Period:           period,
+		From:             from,
+		To:               to,
+		Days:             []DaySummary{},
+		TopConversations: []ConversationSummary{},
+	}
+
+	days := make(map[string]*DaySummary)
+	var order []string
+	for day := truncateDay(from); day.Before(to); day = day.AddDate(0, 0, 1) {
+		key := day.Format("2006-01-02")
+		days[key] = &DaySummary{Day: key}
+		order = append(order, key)
+	}
+	convs := make(map[string]*ConversationSummary)
+
+	for _, r := range rows {
+		if r.OrgID != orgID || r.Day.Before(from) || !r.Day.Before(to) {
+			continue
+		}
+		rep.SMS.Messages += r.Messages
+		rep.SMS.Segments += r.Segments
+		rep.SMS.CostUSD += r.SMSCostUSD
+		rep.LLM.Calls += r.LLMCalls
+		rep.LLM.InputTokens += r.LLMInputTokens
+		rep.LLM.OutputTokens += r.LLMOutputTokens
+		rep.LLM.CostUSD += r.LLMCostUSD
+
+		if d, ok := days[r.Day.UTC().Format("2006-01-02")]; ok {
+			d.Conversations++
+			d.SMSCostUSD += r.SMSCostUSD
+			d.LLMCostUSD += r.LLMCostUSD
+		}
+		c, ok := convs[r.ConversationID]
+		if !ok {
+			c = &ConversationSummary{ConversationID: r.ConversationID}
+			convs[r.ConversationID] = c
+		}
+		c.Messages += r.Messages
+		c.Segments += r.Segments
+		c.LLMCalls += r.LLMCalls
+		c.SMSCostUSD += r.SMSCostUSD
+		c.LLMCostUSD += r.LLMCostUSD
+	}
+
+	rep.TotalCostUSD = roundUSD(rep.SMS.CostUSD + rep.LLM.CostUSD)
+	rep.Conversations = len(convs)
+	if rep.Conversations > 0 {
+		rep.CostPerConversationUSD = roundUSD((rep.SMS.CostUSD + rep.LLM.CostUSD) / float64(rep.Conversations))
+	}
+	rep.SMS.CostUSD = roundUSD(rep.SMS.CostUSD)
+	rep.LLM.CostUSD = roundUSD(rep.LLM.CostUSD)
+
+	for _, key := range order {
+		d := days[key]
+		d.TotalCostUSD = roundUSD(d.SMSCostUSD + d.LLMCostUSD)
+		d.SMSCostUSD = roundUSD(d.SMSCostUSD)
+		d.LLMCostUSD = roundUSD(d.LLMCostUSD)
+		rep.Days = append(rep.Days, *d)
+	}
+
+	for _, c := range convs {
+		c.TotalCostUSD = c.SMSCostUSD + c.LLMCostUSD
+		rep.TopConversations = append(rep.TopConversations, *c)
+	}
+	sort.Slice(rep.TopConversations, func(i, j int) bool {
+		a, b := rep.TopConversations[i], rep.TopConversations[j]
+		if a.TotalCostUSD != b.TotalCostUSD {
+			return a.TotalCostUSD > b.TotalCostUSD
+		}
+		return a.ConversationID < b.ConversationID
+	})
+	if len(rep.TopConversations) > topConversations {
+		rep.TopConversations = rep.TopConversations[:topConversations]
+	}
+	for i := range rep.TopConversations {
+		c := &rep.TopConversations[i]
+		c.SMSCostUSD = roundUSD(c.SMSCostUSD)
+		c.LLMCostUSD = roundUSD(c.LLMCostUSD)
+		c.TotalCostUSD = roundUSD(c.TotalCostUSD)
+	}
+	return rep
+}
+
+// roundUSD rounds to a hundredth of a cent; single SMS segments and short
+// completions cost fractions of a cent, so whole cents would hide them.
+func roundUSD(v float64) float64 {
+	return math.Round(v*1e4) / 1e4
+}
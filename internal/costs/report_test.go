@@ -0,0 +1,96 @@
+package costs
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestPeriodRange(t *testing.T) {
+	now := time.Date(2026, 3, 11, 18, 0, 0, 0, time.UTC)
+	tomorrow := time.Date(2026, 3, 12, 0, 0, 0, 0, time.UTC)
+	cases := map[string]time.Time{
+		"day":   time.Date(2026, 3, 11, 0, 0, 0, 0, time.UTC),
+		"week":  time.Date(2026, 3, 5, 0, 0, 0, 0, time.UTC),
+		"Month": time.Date(2026, 2, 10, 0, 0, 0, 0, time.UTC),
+	}
+	for period, wantFrom := range cases {
+		from, to, err := PeriodRange(period, now)
+		if err != nil || !from.Equal(wantFrom) || !to.Equal(tomorrow) {
+			t.Errorf("PeriodRange(%q) = %v..%v, %v; want %v..%v", period, from, to, err, wantFrom, tomorrow)
+		}
+	}
+	if _, _, err := PeriodRange("year", now); err == nil {
+		t.Error("expected an error for an unknown period")
+	}
+}
+
+func TestBuildReportCombinesSMSAndLLM(t *testing.T) {
+	from := time.Date(2026, 3, 9, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 0, 3)
+	day1, day2 := from, from.AddDate(0, 0, 1)
+	rows := []DailyCost{
+		{OrgID: testOrg, Day: day1, ConversationID: "sms:a", Messages: 4, Segments: 6, SMSCostUSD: 0.024, LLMCalls: 3, LLMInputTokens: 9000, LLMOutputTokens: 600, LLMCostUSD: 0.036},
+		{OrgID: testOrg, Day: day2, ConversationID: "sms:a", Messages: 1, Segments: 1, SMSCostUSD: 0.004, LLMCalls: 1, LLMInputTokens: 3000, LLMOutputTokens: 200, LLMCostUSD: 0.012},
+		{OrgID: testOrg, Day: day2, ConversationID: "sms:b", Messages: 2, Segments: 2, SMSCostUSD: 0.008, LLMCalls: 2, LLMInputTokens: 5000, LLMOutputTokens: 300, LLMCostUSD: 0.02},
+		{OrgID: "other", Day: day2, ConversationID: "sms:c", Messages: 9, SMSCostUSD: 1},
+		{OrgID: testOrg, Day: to, ConversationID: "sms:d", Messages: 9, SMSCostUSD: 1}, // outside the range
+	}
+
+	rep := BuildReport(testOrg, "week", from, to, rows)
+	if rep.SMS.Messages != 7 || rep.SMS.Segments != 9 || rep.SMS.CostUSD != 0.036 {
+		t.Errorf("sms = %+v, want 7 messages, 9 segments, $0.036", rep.SMS)
+	}
+	if rep.LLM.Calls != 6 || rep.LLM.InputTokens != 17000 || rep.LLM.OutputTokens != 1100 || rep.LLM.CostUSD != 0.068 {
+		t.Errorf("llm = %+v, want 6 calls, 17000/1100 tokens, $0.068", rep.LLM)
+	}
+	if rep.TotalCostUSD != 0.104 {
+		t.Errorf("total = %v, want 0.104", rep.TotalCostUSD)
+	}
+	// Conversation a spans two days but counts once.
+	if rep.Conversations != 2 || rep.CostPerConversationUSD != 0.052 {
+		t.Errorf("conversations = %d at %v each, want 2 at 0.052", rep.Conversations, rep.CostPerConversationUSD)
+	}
+
+	if len(rep.Days) != 3 {
+		t.Fatalf("days = %d, want every day in range including empty ones", len(rep.Days))
+	}
+	if d := rep.Days[0]; d.Day != "2026-03-09" || d.Conversations != 1 || d.TotalCostUSD != 0.06 {
+		t.Errorf("day 1 = %+v", d)
+	}
+	if d := rep.Days[1]; d.Conversations != 2 || d.SMSCostUSD != 0.012 || d.LLMCostUSD != 0.032 || d.TotalCostUSD != 0.044 {
+		t.Errorf("day 2 = %+v", d)
+	}
+	if d := rep.Days[2]; d.Conversations != 0 || d.TotalCostUSD != 0 {
+		t.Errorf("day 3 = %+v, want empty", d)
+	}
+
+	if len(rep.TopConversations) != 2 || rep.TopConversations[0].ConversationID != "sms:a" {
+		t.Fatalf("top = %+v, want sms:a first", rep.TopConversations)
+	}
+	if a := rep.TopConversations[0]; a.Messages != 5 || a.LLMCalls != 4 || a.TotalCostUSD != 0.076 {
+		t.Errorf("sms:a = %+v, want both days summed to $0.076", a)
+	}
+}
+
+func TestBuildReportEmptyAndTopLimit(t *testing.T) {
+	from := time.Date(2026, 3, 11, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 0, 1)
+
+	empty := BuildReport(testOrg, "day", from, to, nil)
+	if empty.Conversations != 0 || empty.CostPerConversationUSD != 0 || len(empty.Days) != 1 || empty.TopConversations == nil {
+		t.Fatalf("empty report = %+v", empty)
+	}
+
+	var rows []DailyCost
+	for i := 0; i < topConversations+5; i++ {
+		rows = append(rows, DailyCost{OrgID: testOrg, Day: from, ConversationID: fmt.Sprintf("sms:%02d", i), SMSCostUSD: float64(i) / 1000})
+	}
+	rep := BuildReport(testOrg, "day", from, to, rows)
+	if rep.Conversations != topConversations+5 || len(rep.TopConversations) != topConversations {
+		t.Fatalf("conversations = %d, top = %d", rep.Conversations, len(rep.TopConversations))
+	}
+	if rep.TopConversations[0].ConversationID != fmt.Sprintf("sms:%02d", topConversations+4) {
+		t.Errorf("most expensive = %s", rep.TopConversations[0].ConversationID)
+	}
+}
@@ -0,0 +1,207 @@
+package costs
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
+	"github.com/wolfman30/medspa-ai-platform/pkg/phone"
+)
+
+const defaultRollupInterval = time.Hour
+
+// DailyCost is one conversation's spend on one UTC day.
+type DailyCost struct {
+	OrgID           string    `json:"org_id"`
+	Day             time.Time `json:"day"`
+	ConversationID  string    `json:"conversation_id"`
+	Messages        int       `json:"messages"`
+	Segments        int       `json:"segments"`
+	SMSCostUSD      float64   `json:"sms_cost_usd"`
+	LLMCalls        int       `json:"llm_calls"`
+	LLMInputTokens  int64     `json:"llm_input_tokens"`
+	LLMOutputTokens int64     `json:"llm_output_tokens"`
+	LLMCostUSD      float64   `json:"llm_cost_usd"`
+}
+
+// TotalCostUSD is the SMS and LLM spend combined.
+func (d DailyCost) TotalCostUSD() float64 {
+	return d.SMSCostUSD + d.LLMCostUSD
+}
+
+// smsConversationID mirrors the ID the SMS pipeline gives a conversation, so
+// outbound messages land on the same rollup row as the completions that
+// wrote them.
+func smsConversationID(orgID, to string) string {
+	return fmt.Sprintf("sms:%s:%s", orgID, phone.Digits(to))
+}
+
+// Rollup aggregates one day's raw usage per org and conversation. Messages
+// without a recorded cost are estimated at segmentRateUSD per segment.
+// Usage outside [day, day+24h) is ignored.
+func Rollup(day time.Time, messages []MessageUsage, llm []LLMUsage, segmentRateUSD float64) []DailyCost {
+	day = truncateDay(day)
+	end := day.AddDate(0, 0, 1)
+	inDay := func(t time.Time) bool { return !t.Before(day) && t.Before(end) }
+
+	type key struct{ org, conv string }
+	rows := make(map[key]*DailyCost)
+	row := func(org, conv string) *DailyCost {
+		k := key{org, conv}
+		r, ok := rows[k]
+		if !ok {
+			r = &DailyCost{OrgID: org, Day: day, ConversationID: conv}
+			rows[k] = r
+		}
+		return r
+	}
+
+	for _, m := range messages {
+		if !inDay(m.SentAt) || m.OrgID == "" {
+			continue
+		}
+		r := row(m.OrgID, smsConversationID(m.OrgID, m.To))
+		r.Messages++
+		r.Segments += m.Segments
+		if m.CostUSD != nil {
+			r.SMSCostUSD += *m.CostUSD
+		} else {
+			r.SMSCostUSD += float64(m.Segments) * segmentRateUSD
+		}
+	}
+	for _, u := range llm {
+		if !inDay(u.At) || u.OrgID == "" {
+			continue
+		}
+		r := row(u.OrgID, u.ConversationID)
+		r.LLMCalls++
+		r.LLMInputTokens += int64(u.InputTokens)
+		r.LLMOutputTokens += int64(u.OutputTokens)
+		r.LLMCostUSD += LLMCostUSD(u.Model, int64(u.InputTokens), int64(u.OutputTokens))
+	}
+
+	out := make([]DailyCost, 0, len(rows))
+	for _, r := range rows {
+		out = append(out, *r)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].OrgID != out[j].OrgID {
+			return out[i].OrgID < out[j].OrgID
+		}
+		return out[i].ConversationID < out[j].ConversationID
+	})
+	return out
+}
+
+func truncateDay(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// RollupStore loads raw usage and persists the daily rollup.
+type RollupStore interface {
+	MessageUsage(ctx context.Context, from, to time.Time) ([]MessageUsage, error)
+	LLMUsage(ctx context.Context, from, to time.Time) ([]LLMUsage, error)
+	ReplaceDay(ctx context.Context, day time.Time, rows []DailyCost) error
+}
+
+// RollupJobConfig configures the rollup job.
+type RollupJobConfig struct {
+	Store          RollupStore
+	SegmentRateUSD float64
+	Interval       time.Duration
+	Logger         *logging.Logger
+}
+
+// RollupJob periodically rebuilds the messaging_costs rows for today and
+// yesterday. Yesterday is redone because completions and sends that straddle
+// midnight, and provider costs that arrive late, would otherwise be missed.
+type RollupJob struct {
+	store          RollupStore
+	segmentRateUSD float64
+	interval       time.Duration
+	logger         *logging.Logger
+	now            func() time.Time
+}
+
+// NewRollupJob creates a cost rollup job.
+func NewRollupJob(cfg RollupJobConfig) *RollupJob {
+	if cfg.Logger == nil {
+		cfg.Logger = logging.Default()
+	}
+	if cfg.Interval <= 0 {
+		cfg.Interval = defaultRollupInterval
+	}
+	if cfg.SegmentRateUSD <= 0 {
+		cfg.SegmentRateUSD = DefaultSMSSegmentRateUSD
+	}
+	return &RollupJob{
+		store:          cfg.Store,
+		segmentRateUSD: cfg.SegmentRateUSD,
+		interval:       cfg.Interval,
+		logger:         cfg.Logger,
+		now:            time.Now,
+	}
+}
+
+// Start runs the rollup on its interval. Blocks until ctx is cancelled.
+func (j *RollupJob) Start(ctx context.Context) {
+	if j == nil || j.store == nil {
+		return
+	}
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+
+	j.run(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			j.run(ctx)
+		}
+	}
+}
+
+func (j *RollupJob) run(ctx context.Context) {
+	rows, err := j.RunOnce(ctx)
+	if err != nil {
+		j.logger.Error("cost rollup failed", "error", err)
+		return
+	}
+	j.logger.Info("cost rollup completed", "rows", rows)
+}
+
+// RunOnce rebuilds yesterday's and today's rollups and returns the number of
+// rows written.
+func (j *RollupJob) RunOnce(ctx context.Context) (int, error) {
+	today := truncateDay(j.now())
+	written := 0
+	for _, day := range []time.Time{today.AddDate(0, 0, -1), today} {
+		n, err := j.rollupDay(ctx, day)
+		if err != nil {
+			return written, err
+		}
+		written += n
+	}
+	return written, nil
+}
+
+func (j *RollupJob) rollupDay(ctx context.Context, day time.Time) (int, error) {
+	end := day.AddDate(0, 0, 1)
+	messages, err := j.store.MessageUsage(ctx, day, end)
+	if err != nil {
+		return 0, err
+	}
+	llm, err := j.store.LLMUsage(ctx, day, end)
+	if err != nil {
+		return 0, err
+	}
+	rows := Rollup(day, messages, llm, j.segmentRateUSD)
+	if err := j.store.ReplaceDay(ctx, day, rows); err != nil {
+		return 0, err
+	}
+	return len(rows), nil
+}
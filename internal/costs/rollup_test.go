@@ -0,0 +1,156 @@
+package costs
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+	"time"
+)
+
+const testOrg = "11111111-1111-1111-1111-111111111111"
+
+func approx(a, b float64) bool { return math.Abs(a-b) < 1e-9 }
+
+func ptr(v float64) *float64 { return &v }
+
+func TestRollupAggregatesPerConversation(t *testing.T) {
+	day := time.Date(2026, 3, 10, 0, 0, 0, 0, time.UTC)
+	at := day.Add(15 * time.Hour)
+	messages := []MessageUsage{
+		{OrgID: testOrg, To: "+15550001111", Segments: 1, CostUSD: ptr(0.0045), SentAt: at},
+		{OrgID: testOrg, To: "+15550001111", Segments: 3, SentAt: at.Add(time.Minute)}, // estimated
+		{OrgID: testOrg, To: "+15550002222", Segments: 2, SentAt: at},
+		{OrgID: testOrg, To: "+15550001111", Segments: 5, SentAt: day.AddDate(0, 0, 1)}, // next day
+	}
+	llm := []LLMUsage{
+		{OrgID: testOrg, ConversationID: "sms:" + testOrg + ":15550001111", Model: "anthropic.claude-3-haiku-20240307-v1:0", InputTokens: 2000, OutputTokens: 400, At: at},
+		{OrgID: testOrg, ConversationID: "sms:" + testOrg + ":15550001111", Model: "us.anthropic.claude-sonnet-4-5-20250929-v1:0", InputTokens: 1000, OutputTokens: 100, At: at},
+		{OrgID: "", ConversationID: "sms::1", Model: "x", InputTokens: 10, At: at}, // unattributed
+	}
+
+	rows := Rollup(day, messages, llm, 0.004)
+	if len(rows) != 2 {
+		t.Fatalf("rows = %d, want 2 conversations: %+v", len(rows), rows)
+	}
+	first := rows[0]
+	if first.ConversationID != "sms:"+testOrg+":15550001111" || !first.Day.Equal(day) {
+		t.Fatalf("first row = %+v", first)
+	}
+	if first.Messages != 2 || first.Segments != 4 {
+		t.Errorf("messages/segments = %d/%d, want 2/4", first.Messages, first.Segments)
+	}
+	// Reported 0.0045 plus 3 estimated segments at 0.004.
+	if !approx(first.SMSCostUSD, 0.0045+0.012) {
+		t.Errorf("sms cost = %v, want 0.0165", first.SMSCostUSD)
+	}
+	if first.LLMCalls != 2 || first.LLMInputTokens != 3000 || first.LLMOutputTokens != 500 {
+		t.Errorf("llm usage = %d calls, %d in, %d out", first.LLMCalls, first.LLMInputTokens, first.LLMOutputTokens)
+	}
+	// Haiku 3: 2000*0.25 + 400*1.25 per million; Sonnet: 1000*3 + 100*15 per million.
+	wantLLM := (2000*0.25+400*1.25)/1e6 + (1000*3.0+100*15)/1e6
+	if !approx(first.LLMCostUSD, wantLLM) {
+		t.Errorf("llm cost = %v, want %v", first.LLMCostUSD, wantLLM)
+	}
+	if !approx(first.TotalCostUSD(), first.SMSCostUSD+wantLLM) {
+		t.Errorf("total = %v", first.TotalCostUSD())
+	}
+
+	second := rows[1]
+	if second.ConversationID != "sms:"+testOrg+":15550002222" || !approx(second.SMSCostUSD, 0.008) || second.LLMCalls != 0 {
+		t.Errorf("second row = %+v", second)
+	}
+}
+
+func TestPriceForModel(t *testing.T) {
+	cases := map[string]LLMPrice{
+		"anthropic.claude-3-haiku-20240307-v1:0":       {0.25, 1.25},
+		"us.anthropic.claude-haiku-4-5-20251001-v1:0":  {1, 5},
+		"us.anthropic.claude-sonnet-4-5-20250929-v1:0": {3, 15},
+		"gemini-2.5-flash":                             {0.3, 2.5},
+		"something-new":                                defaultLLMPrice,
+	}
+	for model, want := range cases {
+		if got := PriceForModel(model); got != want {
+			t.Errorf("PriceForModel(%q) = %+v, want %+v", model, got, want)
+		}
+	}
+}
+
+type fakeRollupStore struct {
+	messages []MessageUsage
+	llm      []LLMUsage
+	days     map[time.Time][]DailyCost
+	err      error
+}
+
+func (f *fakeRollupStore) MessageUsage(ctx context.Context, from, to time.Time) ([]MessageUsage, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	var out []MessageUsage
+	for _, m := range f.messages {
+		if !m.SentAt.Before(from) && m.SentAt.Before(to) {
+			out = append(out, m)
+		}
+	}
+	return out, nil
+}
+
+func (f *fakeRollupStore) LLMUsage(ctx context.Context, from, to time.Time) ([]LLMUsage, error) {
+	var out []LLMUsage
+	for _, u := range f.llm {
+		if !u.At.Before(from) && u.At.Before(to) {
+			out = append(out, u)
+		}
+	}
+	return out, nil
+}
+
+func (f *fakeRollupStore) ReplaceDay(ctx context.Context, day time.Time, rows []DailyCost) error {
+	if f.days == nil {
+		f.days = make(map[time.Time][]DailyCost)
+	}
+	f.days[day] = rows
+	return nil
+}
+
+func TestRollupJobRebuildsYesterdayAndToday(t *testing.T) {
+	now := time.Date(2026, 3, 11, 0, 30, 0, 0, time.UTC)
+	today := time.Date(2026, 3, 11, 0, 0, 0, 0, time.UTC)
+	yesterday := today.AddDate(0, 0, -1)
+	store := &fakeRollupStore{
+		messages: []MessageUsage{
+			{OrgID: testOrg, To: "+15550001111", Segments: 1, SentAt: yesterday.Add(23*time.Hour + 59*time.Minute)},
+			{OrgID: testOrg, To: "+15550001111", Segments: 2, SentAt: today.Add(10 * time.Minute)},
+			{OrgID: testOrg, To: "+15550003333", Segments: 1, SentAt: yesterday.AddDate(0, 0, -1)}, // too old
+		},
+	}
+	job := NewRollupJob(RollupJobConfig{Store: store})
+	job.now = func() time.Time { return now }
+
+	written, err := job.RunOnce(context.Background())
+	if err != nil {
+		t.Fatalf("RunOnce: %v", err)
+	}
+	if written != 2 || len(store.days) != 2 {
+		t.Fatalf("written = %d over %d days, want one row on each of two days", written, len(store.days))
+	}
+	if rows := store.days[yesterday]; len(rows) != 1 || rows[0].Segments != 1 || !approx(rows[0].SMSCostUSD, DefaultSMSSegmentRateUSD) {
+		t.Errorf("yesterday = %+v", rows)
+	}
+	if rows := store.days[today]; len(rows) != 1 || rows[0].Segments != 2 {
+		t.Errorf("today = %+v", rows)
+	}
+}
+
+func TestRollupJobStopsOnStoreError(t *testing.T) {
+	store := &fakeRollupStore{err: errors.New("db down")}
+	job := NewRollupJob(RollupJobConfig{Store: store})
+	if _, err := job.RunOnce(context.Background()); err == nil {
+		t.Fatal("expected the store error")
+	}
+	if len(store.days) != 0 {
+		t.Fatalf("expected nothing written, got %d days", len(store.days))
+	}
+}
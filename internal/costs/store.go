@@ -0,0 +1,162 @@
+package costs
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// Querier is the subset of pgxpool.Pool used by Store.
+type Querier interface {
+	Begin(ctx context.Context) (pgx.Tx, error)
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+}
+
+// Store persists LLM usage and the daily cost rollup in Postgres. Outbound
+// SMS usage is read from the messages table, where the messaging store
+// records segments and cost per message.
+type Store struct {
+	pool Querier
+}
+
+// NewStore constructs a cost store. Returns nil without a pool.
+func NewStore(pool Querier) *Store {
+	if pool == nil {
+		return nil
+	}
+	return &Store{pool: pool}
+}
+
+// RecordLLMUsage saves one completion's token usage.
+func (s *Store) RecordLLMUsage(ctx context.Context, u LLMUsage) error {
+	at := u.At
+	if at.IsZero() {
+		at = time.Now()
+	}
+	_, err := s.pool.Exec(ctx, `
+		INSERT INTO llm_usage (org_id, conversation_id, model, input_tokens, output_tokens, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`, u.OrgID, u.ConversationID, u.Model, u.InputTokens, u.OutputTokens, at)
+	if err != nil {
+		return fmt.Errorf("costs: record llm usage: %w", err)
+	}
+	return nil
+}
+
+// MessageUsage returns outbound messages sent in [from, to). Suppressed and
+// failed sends never reached a carrier and aren't billed. Messages from
+// before segments were recorded count as one segment.
+func (s *Store) MessageUsage(ctx context.Context, from, to time.Time) ([]MessageUsage, error) {
+	rows, err := s.pool.Query(ctx, `
+		SELECT clinic_id::text, to_e164, COALESCE(segments, 1), cost_usd::float8, created_at
+		FROM messages
+		WHERE direction = 'outbound'
+		  AND COALESCE(provider_status, '') NOT IN ('suppressed', 'failed')
+		  AND created_at >= $1 AND created_at < $2
+	`, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("costs: load message usage: %w", err)
+	}
+	defer rows.Close()
+	var out []MessageUsage
+	for rows.Next() {
+		var m MessageUsage
+		if err := rows.Scan(&m.OrgID, &m.To, &m.Segments, &m.CostUSD, &m.SentAt); err != nil {
+			return nil, fmt.Errorf("costs: scan message usage: %w", err)
+		}
+		out = append(out, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("costs: load message usage: %w", err)
+	}
+	return out, nil
+}
+
+// LLMUsage returns completions recorded in [from, to).
+func (s *Store) LLMUsage(ctx context.Context, from, to time.Time) ([]LLMUsage, error) {
+	rows, err := s.pool.Query(ctx, `
+		SELECT org_id, conversation_id, model, input_tokens, output_tokens, created_at
+		FROM llm_usage
+		WHERE created_at >= $1 AND created_at < $2
+	`, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("costs: load llm usage: %w", err)
+	}
+	defer rows.Close()
+	var out []LLMUsage
+	for rows.Next() {
+		var u LLMUsage
+		if err := rows.Scan(&u.OrgID, &u.ConversationID, &u.Model, &u.InputTokens, &u.OutputTokens, &u.At); err != nil {
+			return nil, fmt.Errorf("costs: scan llm usage: %w", err)
+		}
+		out = append(out, u)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("costs: load llm usage: %w", err)
+	}
+	return out, nil
+}
+
+// ReplaceDay swaps a day's rollup rows for rows in one transaction, so a
+// report never sees a half-written day.
+func (s *Store) ReplaceDay(ctx context.Context, day time.Time, rows []DailyCost) error {
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("costs: begin rollup: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	if _, err := tx.Exec(ctx, `DELETE FROM messaging_costs WHERE day = $1`, day); err != nil {
+		return fmt.Errorf("costs: clear rollup: %w", err)
+	}
+	for _, r := range rows {
+		_, err := tx.Exec(ctx, `
+			INSERT INTO messaging_costs (
+				org_id, day, conversation_id, messages, segments, sms_cost_usd,
+				llm_calls, llm_input_tokens, llm_output_tokens, llm_cost_usd
+			)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		`, r.OrgID, day, r.ConversationID, r.Messages, r.Segments, r.SMSCostUSD,
+			r.LLMCalls, r.LLMInputTokens, r.LLMOutputTokens, r.LLMCostUSD)
+		if err != nil {
+			return fmt.Errorf("costs: write rollup: %w", err)
+		}
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("costs: commit rollup: %w", err)
+	}
+	return nil
+}
+
+// Daily returns an org's rollup rows for days in [from, to).
+func (s *Store) Daily(ctx context.Context, orgID string, from, to time.Time) ([]DailyCost, error) {
+	rows, err := s.pool.Query(ctx, `
+		SELECT org_id, day, conversation_id, messages, segments, sms_cost_usd::float8,
+		       llm_calls, llm_input_tokens, llm_output_tokens, llm_cost_usd::float8
+		FROM messaging_costs
+		WHERE org_id = $1 AND day >= $2 AND day < $3
+		ORDER BY day, conversation_id
+	`, orgID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("costs: load daily costs: %w", err)
+	}
+	defer rows.Close()
+	var out []DailyCost
+	for rows.Next() {
+		var d DailyCost
+		if err := rows.Scan(&d.OrgID, &d.Day, &d.ConversationID, &d.Messages, &d.Segments, &d.SMSCostUSD,
+			&d.LLMCalls, &d.LLMInputTokens, &d.LLMOutputTokens, &d.LLMCostUSD); err != nil {
+			return nil, fmt.Errorf("costs: scan daily cost: %w", err)
+		}
+		d.Day = truncateDay(d.Day)
+		out = append(out, d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("costs: load daily costs: %w", err)
+	}
+	return out, nil
+}
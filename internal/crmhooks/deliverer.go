@@ -0,0 +1,215 @@
+package crmhooks
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
+)
+
+const (
+	defaultMaxAttempts  = 8
+	defaultBaseBackoff  = 30 * time.Second
+	maxBackoff          = time.Hour
+	defaultBatchSize    = 25
+	defaultPollInterval = 5 * time.Second
+	maxErrorLen         = 500
+)
+
+// deliveryStore is the part of Store the deliverer needs.
+type deliveryStore interface {
+	Claim(ctx context.Context, now time.Time, limit int) ([]Delivery, error)
+	MarkDelivered(ctx context.Context, id uuid.UUID, attempts, statusCode int, at time.Time) error
+	Reschedule(ctx context.Context, id uuid.UUID, attempts, statusCode int, lastErr string, next time.Time) error
+	DeadLetter(ctx context.Context, id uuid.UUID, attempts, statusCode int, lastErr string, at time.Time) error
+}
+
+// Deliverer polls queued deliveries and POSTs them to clinic endpoints.
+// Failed attempts back off exponentially (base, 2x base, 4x base, ...
+// capped at an hour); after maxAttempts the delivery is dead-lettered.
+type Deliverer struct {
+	store       deliveryStore
+	clinics     ConfigSource
+	client      *http.Client
+	logger      *logging.Logger
+	batchSize   int
+	interval    time.Duration
+	maxAttempts int
+	backoff     time.Duration
+	now         func() time.Time
+}
+
+// NewDeliverer creates a deliverer. Defaults: 25 deliveries every 5 seconds,
+// 8 attempts, 30s initial backoff.
+func NewDeliverer(store *Store, clinics ConfigSource, logger *logging.Logger) *Deliverer {
+	var ds deliveryStore
+	if store != nil {
+		ds = store
+	}
+	return newDeliverer(ds, clinics, logger)
+}
+
+func newDeliverer(store deliveryStore, clinics ConfigSource, logger *logging.Logger) *Deliverer {
+	if logger == nil {
+		logger = logging.Default()
+	}
+	return &Deliverer{
+		store:       store,
+		clinics:     clinics,
+		client:      &http.Client{Timeout: 10 * time.Second},
+		logger:      logger,
+		batchSize:   defaultBatchSize,
+		interval:    defaultPollInterval,
+		maxAttempts: defaultMaxAttempts,
+		backoff:     defaultBaseBackoff,
+		now:         time.Now,
+	}
+}
+
+// WithInterval overrides the polling interval used by Start.
+func (d *Deliverer) WithInterval(interval time.Duration) *Deliverer {
+	if interval > 0 {
+		d.interval = interval
+	}
+	return d
+}
+
+// WithRetry overrides the attempt limit and the initial backoff.
+func (d *Deliverer) WithRetry(maxAttempts int, backoff time.Duration) *Deliverer {
+	if maxAttempts > 0 {
+		d.maxAttempts = maxAttempts
+	}
+	if backoff > 0 {
+		d.backoff = backoff
+	}
+	return d
+}
+
+// Start delivers due events until the context is canceled.
+func (d *Deliverer) Start(ctx context.Context) {
+	if d.store == nil || d.clinics == nil {
+		return
+	}
+	ticker := time.NewTicker(d.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			d.drain(ctx)
+		}
+	}
+}
+
+func (d *Deliverer) drain(ctx context.Context) {
+	due, err := d.store.Claim(ctx, d.now(), d.batchSize)
+	if err != nil {
+		d.logger.Error("crmhooks: claim deliveries failed", "error", err)
+		return
+	}
+	for _, delivery := range due {
+		if ctx.Err() != nil {
+			return
+		}
+		d.deliver(ctx, delivery)
+	}
+}
+
+// deliver makes one attempt and records the outcome.
+func (d *Deliverer) deliver(ctx context.Context, delivery Delivery) {
+	attempts := delivery.Attempts + 1
+	statusCode, err := d.attempt(ctx, delivery)
+	now := d.now()
+	if err == nil {
+		if err := d.store.MarkDelivered(ctx, delivery.ID, attempts, statusCode, now); err != nil {
+			d.logger.Error("crmhooks: failed to mark delivered", "error", err, "delivery_id", delivery.ID)
+		}
+		return
+	}
+
+	msg := err.Error()
+	if len(msg) > maxErrorLen {
+		msg = msg[:maxErrorLen]
+	}
+	var final *permanentError
+	if attempts >= d.maxAttempts || errors.As(err, &final) {
+		d.logger.Error("crmhooks: delivery dead-lettered", "error", err, "delivery_id", delivery.ID,
+			"org_id", delivery.OrgID, "endpoint", delivery.Endpoint, "event", delivery.EventType, "attempts", attempts)
+		if err := d.store.DeadLetter(ctx, delivery.ID, attempts, statusCode, msg, now); err != nil {
+			d.logger.Error("crmhooks: failed to dead-letter delivery", "error", err, "delivery_id", delivery.ID)
+		}
+		return
+	}
+	next := now.Add(d.retryDelay(attempts))
+	d.logger.Warn("crmhooks: delivery failed, will retry", "error", err, "delivery_id", delivery.ID,
+		"org_id", delivery.OrgID, "endpoint", delivery.Endpoint, "attempts", attempts, "next_attempt_at", next)
+	if err := d.store.Reschedule(ctx, delivery.ID, attempts, statusCode, msg, next); err != nil {
+		d.logger.Error("crmhooks: failed to reschedule delivery", "error", err, "delivery_id", delivery.ID)
+	}
+}
+
+// retryDelay is the wait after the given number of failed attempts.
+func (d *Deliverer) retryDelay(attempts int) time.Duration {
+	delay := d.backoff
+	for i := 1; i < attempts && delay < maxBackoff; i++ {
+		delay *= 2
+	}
+	return min(delay, maxBackoff)
+}
+
+// permanentError marks failures retrying can't fix, such as an endpoint the
+// clinic has since removed.
+type permanentError struct{ reason string }
+
+func (e *permanentError) Error() string { return e.reason }
+
+// attempt POSTs the payload. Errors never include the endpoint URL.
+func (d *Deliverer) attempt(ctx context.Context, delivery Delivery) (int, error) {
+	cfg, err := d.clinics.Get(ctx, delivery.OrgID)
+	if err != nil {
+		return 0, fmt.Errorf("load clinic config: %w", err)
+	}
+	hook, ok := cfg.CRMWebhook(delivery.Endpoint)
+	if !ok {
+		return 0, &permanentError{reason: "endpoint no longer configured"}
+	}
+	if !hook.Wants(delivery.EventType) {
+		return 0, &permanentError{reason: "endpoint no longer subscribed to " + delivery.EventType}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, hook.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, &permanentError{reason: "invalid endpoint url"}
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventHeader, delivery.EventType)
+	req.Header.Set(DeliveryHeader, delivery.ID.String())
+	req.Header.Set(SignatureHeader, Sign(hook.Secret, d.now(), delivery.Payload))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		// *url.Error embeds the full URL; keep only the cause.
+		var uerr *url.Error
+		if errors.As(err, &uerr) {
+			err = uerr.Err
+		}
+		return 0, fmt.Errorf("post %s: %w", hook.Redacted(), err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("post %s: status %d", hook.Redacted(), resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
@@ -0,0 +1,241 @@
+package crmhooks
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/clinic"
+)
+
+const testSecret = "whsec_0123456789abcdef"
+
+// memoryDeliveries is an in-memory deliveryStore.
+type memoryDeliveries struct {
+	mu   sync.Mutex
+	rows map[uuid.UUID]*Delivery
+}
+
+func newMemoryDeliveries(ds ...Delivery) *memoryDeliveries {
+	m := &memoryDeliveries{rows: make(map[uuid.UUID]*Delivery)}
+	for i := range ds {
+		d := ds[i]
+		m.rows[d.ID] = &d
+	}
+	return m
+}
+
+func (m *memoryDeliveries) Claim(_ context.Context, now time.Time, limit int) ([]Delivery, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var out []Delivery
+	for _, d := range m.rows {
+		if d.Status == StatusPending && !d.NextAttemptAt.After(now) && len(out) < limit {
+			d.NextAttemptAt = now.Add(claimLease)
+			out = append(out, *d)
+		}
+	}
+	return out, nil
+}
+
+func (m *memoryDeliveries) MarkDelivered(_ context.Context, id uuid.UUID, attempts, statusCode int, at time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	d := m.rows[id]
+	d.Status, d.Attempts, d.LastStatusCode, d.DeliveredAt = StatusDelivered, attempts, statusCode, &at
+	return nil
+}
+
+func (m *memoryDeliveries) Reschedule(_ context.Context, id uuid.UUID, attempts, statusCode int, lastErr string, next time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	d := m.rows[id]
+	d.Attempts, d.LastStatusCode, d.LastError, d.NextAttemptAt = attempts, statusCode, lastErr, next
+	return nil
+}
+
+func (m *memoryDeliveries) DeadLetter(_ context.Context, id uuid.UUID, attempts, statusCode int, lastErr string, at time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	d := m.rows[id]
+	d.Status, d.Attempts, d.LastStatusCode, d.LastError, d.DeadLetteredAt = StatusDead, attempts, statusCode, lastErr, &at
+	return nil
+}
+
+func (m *memoryDeliveries) get(id uuid.UUID) Delivery {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return *m.rows[id]
+}
+
+type staticClinics map[string]*clinic.Config
+
+func (s staticClinics) Get(_ context.Context, orgID string) (*clinic.Config, error) {
+	return s[orgID], nil
+}
+
+func clinicsWithHook(hook clinic.CRMWebhook) staticClinics {
+	return staticClinics{"org-1": {OrgID: "org-1", CRMWebhooks: []clinic.CRMWebhook{hook}}}
+}
+
+func pendingDelivery(t *testing.T, at time.Time) Delivery {
+	t.Helper()
+	evt := NewEvent(EventLeadCreated, "org-1", "lead-1", at, EventData{LeadID: "lead-1"})
+	payload, err := json.Marshal(evt)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	return Delivery{
+		ID:            uuid.New(),
+		OrgID:         "org-1",
+		Endpoint:      "hubspot",
+		EventID:       uuid.MustParse(evt.ID),
+		EventType:     evt.Type,
+		Payload:       payload,
+		Status:        StatusPending,
+		NextAttemptAt: at,
+	}
+}
+
+func TestDeliverer_SignsAndDelivers(t *testing.T) {
+	now := time.Date(2026, 3, 2, 15, 0, 0, 0, time.UTC)
+	var gotHeader http.Header
+	var gotBody []byte
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotHeader = r.Header.Clone()
+		gotBody, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer srv.Close()
+
+	delivery := pendingDelivery(t, now)
+	store := newMemoryDeliveries(delivery)
+	d := newDeliverer(store, clinicsWithHook(clinic.CRMWebhook{Name: "hubspot", URL: srv.URL, Secret: testSecret}), nil)
+	d.now = func() time.Time { return now }
+
+	d.drain(context.Background())
+
+	got := store.get(delivery.ID)
+	if got.Status != StatusDelivered || got.Attempts != 1 || got.LastStatusCode != http.StatusAccepted {
+		t.Fatalf("delivery = %+v, want delivered on first attempt", got)
+	}
+	if err := VerifySignature(testSecret, gotHeader.Get(SignatureHeader), gotBody, now, 0); err != nil {
+		t.Fatalf("receiver could not verify signature: %v", err)
+	}
+	if gotHeader.Get(EventHeader) != EventLeadCreated || gotHeader.Get(DeliveryHeader) != delivery.ID.String() {
+		t.Errorf("headers = %v", gotHeader)
+	}
+	var evt Event
+	if err := json.Unmarshal(gotBody, &evt); err != nil || evt.Data.LeadID != "lead-1" || evt.OccurredAt != now {
+		t.Errorf("body = %s (%v)", gotBody, err)
+	}
+}
+
+func TestDeliverer_RetriesWithBackoffThenDeadLetters(t *testing.T) {
+	now := time.Date(2026, 3, 2, 15, 0, 0, 0, time.UTC)
+	var calls int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer srv.Close()
+
+	delivery := pendingDelivery(t, now)
+	store := newMemoryDeliveries(delivery)
+	d := newDeliverer(store, clinicsWithHook(clinic.CRMWebhook{Name: "hubspot", URL: srv.URL, Secret: testSecret}), nil).
+		WithRetry(4, time.Minute)
+	d.now = func() time.Time { return now }
+
+	wantDelays := []time.Duration{time.Minute, 2 * time.Minute, 4 * time.Minute}
+	for i, wait := range wantDelays {
+		d.drain(context.Background())
+		got := store.get(delivery.ID)
+		if got.Status != StatusPending || got.Attempts != i+1 {
+			t.Fatalf("after attempt %d: %+v", i+1, got)
+		}
+		if want := now.Add(wait); !got.NextAttemptAt.Equal(want) {
+			t.Fatalf("after attempt %d next attempt = %v, want %v", i+1, got.NextAttemptAt, want)
+		}
+		if got.LastStatusCode != http.StatusBadGateway || got.LastError == "" {
+			t.Fatalf("after attempt %d last failure not recorded: %+v", i+1, got)
+		}
+
+		// Not due yet: nothing is sent.
+		d.drain(context.Background())
+		if calls != i+1 {
+			t.Fatalf("delivery retried before its backoff elapsed (%d calls)", calls)
+		}
+		now = got.NextAttemptAt
+	}
+
+	d.drain(context.Background())
+	got := store.get(delivery.ID)
+	if got.Status != StatusDead || got.Attempts != 4 || got.DeadLetteredAt == nil {
+		t.Fatalf("delivery = %+v, want dead-lettered after 4 attempts", got)
+	}
+	d.drain(context.Background())
+	if calls != 4 {
+		t.Fatalf("dead-lettered delivery was retried (%d calls)", calls)
+	}
+}
+
+func TestDeliverer_RecoversAfterTransientFailure(t *testing.T) {
+	now := time.Date(2026, 3, 2, 15, 0, 0, 0, time.UTC)
+	var calls int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	delivery := pendingDelivery(t, now)
+	store := newMemoryDeliveries(delivery)
+	d := newDeliverer(store, clinicsWithHook(clinic.CRMWebhook{Name: "hubspot", URL: srv.URL, Secret: testSecret}), nil)
+	d.now = func() time.Time { return now }
+
+	d.drain(context.Background())
+	now = now.Add(defaultBaseBackoff)
+	d.drain(context.Background())
+
+	if got := store.get(delivery.ID); got.Status != StatusDelivered || got.Attempts != 2 || got.DeliveredAt == nil {
+		t.Fatalf("delivery = %+v, want delivered on second attempt", got)
+	}
+}
+
+func TestDeliverer_RemovedEndpointIsDeadLetteredImmediately(t *testing.T) {
+	now := time.Date(2026, 3, 2, 15, 0, 0, 0, time.UTC)
+	delivery := pendingDelivery(t, now)
+	store := newMemoryDeliveries(delivery)
+	d := newDeliverer(store, clinicsWithHook(clinic.CRMWebhook{Name: "highlevel", URL: "https://example.com/hook", Secret: testSecret}), nil)
+	d.now = func() time.Time { return now }
+
+	d.drain(context.Background())
+
+	if got := store.get(delivery.ID); got.Status != StatusDead || got.Attempts != 1 {
+		t.Fatalf("delivery = %+v, want dead-lettered", got)
+	}
+}
+
+func TestRetryDelay_CapsAtAnHour(t *testing.T) {
+	d := newDeliverer(nil, nil, nil).WithRetry(20, 30*time.Second)
+	if got := d.retryDelay(1); got != 30*time.Second {
+		t.Errorf("retryDelay(1) = %v", got)
+	}
+	if got := d.retryDelay(3); got != 2*time.Minute {
+		t.Errorf("retryDelay(3) = %v", got)
+	}
+	if got := d.retryDelay(15); got != time.Hour {
+		t.Errorf("retryDelay(15) = %v, want capped at 1h", got)
+	}
+}
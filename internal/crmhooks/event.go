@@ -0,0 +1,86 @@
+// Package crmhooks pushes lead and booking lifecycle events to the webhooks
+// clinics register for their CRM (HighLevel, HubSpot, ...).
+//
+// Producers call [Publisher.Publish], which queues one delivery per
+// subscribed endpoint in crm_webhook_deliveries. The [Deliverer] polls that
+// table, signs each payload (see [Sign]) and retries failures with
+// exponential backoff until an attempt limit, after which the delivery is
+// dead-lettered for an operator to inspect and retry.
+package crmhooks
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Event types endpoints can subscribe to.
+const (
+	EventLeadCreated      = "lead.created"
+	EventLeadQualified    = "lead.qualified"
+	EventDepositPaid      = "deposit.paid"
+	EventBookingConfirmed = "booking.confirmed"
+	EventBookingCancelled = "booking.cancelled"
+)
+
+// SchemaVersion is sent with every event. Bump it on breaking payload changes.
+const SchemaVersion = 1
+
+// eventNamespace derives stable event IDs so re-publishing the same fact
+// (a retried job, a second availability fetch) is a no-op.
+var eventNamespace = uuid.MustParse("5b0f9a9e-3c55-4a8e-9a57-0c2f3f6f5d11")
+
+// Event is the JSON body POSTed to CRM endpoints. It carries identifiers,
+// timestamps and booking facts only, never message bodies.
+type Event struct {
+	ID         string    `json:"id"`
+	Type       string    `json:"type"`
+	Version    int       `json:"version"`
+	OrgID      string    `json:"org_id"`
+	OccurredAt time.Time `json:"occurred_at"`
+	Data       EventData `json:"data"`
+}
+
+// EventData holds the facts of an event. Fields that don't apply are omitted.
+type EventData struct {
+	LeadID          string     `json:"lead_id,omitempty"`
+	ConversationID  string     `json:"conversation_id,omitempty"`
+	BookingID       string     `json:"booking_id,omitempty"`
+	BookingIntentID string     `json:"booking_intent_id,omitempty"`
+	PaymentProvider string     `json:"payment_provider,omitempty"`
+	PaymentRef      string     `json:"payment_ref,omitempty"`
+	AmountCents     int64      `json:"amount_cents,omitempty"`
+	Service         string     `json:"service,omitempty"`
+	ScheduledFor    *time.Time `json:"scheduled_for,omitempty"`
+	PatientType     string     `json:"patient_type,omitempty"`
+	Source          string     `json:"source,omitempty"`
+	Contact         *Contact   `json:"contact,omitempty"`
+}
+
+// Contact identifies the patient so the CRM can match or create a contact.
+type Contact struct {
+	Name  string `json:"name,omitempty"`
+	Phone string `json:"phone,omitempty"`
+	Email string `json:"email,omitempty"`
+}
+
+// NewEvent builds an event whose ID is derived from the org, type and key,
+// e.g. the lead ID for lead.created or the payment reference for
+// deposit.paid. Timestamps are normalized to UTC.
+func NewEvent(eventType, orgID, key string, occurredAt time.Time, data EventData) Event {
+	if occurredAt.IsZero() {
+		occurredAt = time.Now()
+	}
+	if data.ScheduledFor != nil {
+		t := data.ScheduledFor.UTC()
+		data.ScheduledFor = &t
+	}
+	return Event{
+		ID:         uuid.NewSHA1(eventNamespace, []byte(orgID+"|"+eventType+"|"+key)).String(),
+		Type:       eventType,
+		Version:    SchemaVersion,
+		OrgID:      orgID,
+		OccurredAt: occurredAt.UTC(),
+		Data:       data,
+	}
+}
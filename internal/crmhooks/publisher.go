@@ -0,0 +1,77 @@
+package crmhooks
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/clinic"
+	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
+)
+
+// ConfigSource retrieves clinic configuration.
+type ConfigSource interface {
+	Get(ctx context.Context, orgID string) (*clinic.Config, error)
+}
+
+// enqueuer is the part of Store the publisher needs.
+type enqueuer interface {
+	Enqueue(ctx context.Context, endpoint string, evt Event) (bool, error)
+}
+
+// Publisher queues events for the endpoints subscribed to them.
+type Publisher struct {
+	store   enqueuer
+	clinics ConfigSource
+	logger  *logging.Logger
+}
+
+// NewPublisher creates a publisher. Returns nil without a store or clinic
+// config source, and a nil publisher drops events.
+func NewPublisher(store *Store, clinics ConfigSource, logger *logging.Logger) *Publisher {
+	if store == nil || clinics == nil {
+		return nil
+	}
+	return newPublisher(store, clinics, logger)
+}
+
+func newPublisher(store enqueuer, clinics ConfigSource, logger *logging.Logger) *Publisher {
+	if logger == nil {
+		logger = logging.Default()
+	}
+	return &Publisher{store: store, clinics: clinics, logger: logger}
+}
+
+// Publish queues evt once for each of the org's CRM endpoints subscribed to
+// its type. Clinics without endpoints cost a config read and nothing else.
+func (p *Publisher) Publish(ctx context.Context, evt Event) error {
+	if p == nil || evt.OrgID == "" {
+		return nil
+	}
+	cfg, err := p.clinics.Get(ctx, evt.OrgID)
+	if err != nil {
+		return fmt.Errorf("crmhooks: get clinic config: %w", err)
+	}
+	if cfg == nil {
+		return nil
+	}
+	var failed int
+	for _, hook := range cfg.CRMWebhooks {
+		if strings.TrimSpace(hook.URL) == "" || !hook.Wants(evt.Type) {
+			continue
+		}
+		added, err := p.store.Enqueue(ctx, hook.Name, evt)
+		if err != nil {
+			p.logger.Error("crmhooks: enqueue failed", "error", err, "org_id", evt.OrgID, "webhook", hook.Redacted(), "event", evt.Type)
+			failed++
+			continue
+		}
+		if added {
+			p.logger.Info("crmhooks: event queued", "org_id", evt.OrgID, "webhook", hook.Redacted(), "event", evt.Type, "event_id", evt.ID)
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("crmhooks: %d endpoint(s) could not be queued", failed)
+	}
+	return nil
+}
@@ -0,0 +1,122 @@
+package crmhooks
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/clinic"
+)
+
+type recordingEnqueuer struct {
+	queued map[string][]string // endpoint -> event IDs
+}
+
+func (r *recordingEnqueuer) Enqueue(_ context.Context, endpoint string, evt Event) (bool, error) {
+	if r.queued == nil {
+		r.queued = make(map[string][]string)
+	}
+	for _, id := range r.queued[endpoint] {
+		if id == evt.ID {
+			return false, nil
+		}
+	}
+	r.queued[endpoint] = append(r.queued[endpoint], evt.ID)
+	return true, nil
+}
+
+func (r *recordingEnqueuer) endpoints() []string {
+	var names []string
+	for name := range r.queued {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func TestPublisher_FiltersBySubscription(t *testing.T) {
+	clinics := staticClinics{"org-1": {OrgID: "org-1", CRMWebhooks: []clinic.CRMWebhook{
+		{Name: "everything", URL: "https://crm.example.com/all", Secret: testSecret},
+		{Name: "bookings", URL: "https://crm.example.com/bookings", Secret: testSecret, Events: []string{EventBookingConfirmed, EventBookingCancelled}},
+		{Name: "leads", URL: "https://crm.example.com/leads", Secret: testSecret, Events: []string{" Lead.Created ", EventLeadQualified}},
+	}}}
+	at := time.Date(2026, 3, 2, 15, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		eventType string
+		want      []string
+	}{
+		{EventLeadCreated, []string{"everything", "leads"}},
+		{EventLeadQualified, []string{"everything", "leads"}},
+		{EventDepositPaid, []string{"everything"}},
+		{EventBookingConfirmed, []string{"bookings", "everything"}},
+		{EventBookingCancelled, []string{"bookings", "everything"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.eventType, func(t *testing.T) {
+			store := &recordingEnqueuer{}
+			p := newPublisher(store, clinics, nil)
+			if err := p.Publish(context.Background(), NewEvent(tt.eventType, "org-1", "key-1", at, EventData{})); err != nil {
+				t.Fatalf("Publish: %v", err)
+			}
+			if got := store.endpoints(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("queued for %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPublisher_SameFactQueuesOnce(t *testing.T) {
+	clinics := staticClinics{"org-1": {OrgID: "org-1", CRMWebhooks: []clinic.CRMWebhook{
+		{Name: "hubspot", URL: "https://crm.example.com/hook", Secret: testSecret},
+	}}}
+	store := &recordingEnqueuer{}
+	p := newPublisher(store, clinics, nil)
+
+	first := NewEvent(EventLeadQualified, "org-1", "lead-1", time.Now(), EventData{LeadID: "lead-1"})
+	again := NewEvent(EventLeadQualified, "org-1", "lead-1", time.Now().Add(time.Hour), EventData{LeadID: "lead-1"})
+	if first.ID != again.ID {
+		t.Fatalf("event IDs differ for the same fact: %s vs %s", first.ID, again.ID)
+	}
+	for _, evt := range []Event{first, again} {
+		if err := p.Publish(context.Background(), evt); err != nil {
+			t.Fatalf("Publish: %v", err)
+		}
+	}
+	if got := len(store.queued["hubspot"]); got != 1 {
+		t.Fatalf("queued %d deliveries, want 1", got)
+	}
+}
+
+func TestPublisher_NoEndpoints(t *testing.T) {
+	store := &recordingEnqueuer{}
+	p := newPublisher(store, staticClinics{"org-1": {OrgID: "org-1"}}, nil)
+	if err := p.Publish(context.Background(), NewEvent(EventLeadCreated, "org-1", "lead-1", time.Now(), EventData{})); err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+	if len(store.queued) != 0 {
+		t.Fatalf("queued %v for a clinic without endpoints", store.queued)
+	}
+
+	var nilPublisher *Publisher
+	if err := nilPublisher.Publish(context.Background(), NewEvent(EventLeadCreated, "org-1", "lead-1", time.Now(), EventData{})); err != nil {
+		t.Fatalf("nil publisher should drop events, got %v", err)
+	}
+}
+
+func TestEventTypesAreSubscribable(t *testing.T) {
+	hook := clinic.CRMWebhook{Name: "crm", URL: "https://crm.example.com/hooks", Secret: testSecret}
+	for _, event := range []string{EventLeadCreated, EventLeadQualified, EventDepositPaid, EventBookingConfirmed, EventBookingCancelled} {
+		hook.Events = []string{event}
+		if err := clinic.ValidateCRMWebhooks([]clinic.CRMWebhook{hook}); err != nil {
+			t.Errorf("%s: %v", event, err)
+		}
+	}
+	// Nothing publishes reschedules, so endpoints can't wait on them.
+	hook.Events = []string{"booking.rescheduled"}
+	if err := clinic.ValidateCRMWebhooks([]clinic.CRMWebhook{hook}); err == nil {
+		t.Fatal("expected an unpublished event to be rejected")
+	}
+}
@@ -0,0 +1,81 @@
+package crmhooks
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Headers set on every delivery.
+const (
+	SignatureHeader = "X-Medspa-Signature"
+	EventHeader     = "X-Medspa-Event"
+	DeliveryHeader  = "X-Medspa-Delivery"
+)
+
+// DefaultSignatureTolerance is how far a signature timestamp may drift from
+// the receiver's clock before VerifySignature rejects it as a replay.
+const DefaultSignatureTolerance = 5 * time.Minute
+
+// ErrInvalidSignature is returned when a signature header is malformed,
+// stale, or doesn't match the body.
+var ErrInvalidSignature = errors.New("crmhooks: invalid signature")
+
+// Sign returns the X-Medspa-Signature value for body:
+//
+//	t=<unix seconds>,v1=<hex HMAC-SHA256(secret, "<unix seconds>.<body>")>
+//
+// Receivers recompute the HMAC over the raw request body and compare in
+// constant time; VerifySignature does exactly that.
+func Sign(secret string, ts time.Time, body []byte) string {
+	unix := strconv.FormatInt(ts.Unix(), 10)
+	return "t=" + unix + ",v1=" + signatureDigest(secret, unix, body)
+}
+
+// VerifySignature checks a signature header against body. A non-positive
+// tolerance uses DefaultSignatureTolerance.
+func VerifySignature(secret, header string, body []byte, now time.Time, tolerance time.Duration) error {
+	if tolerance <= 0 {
+		tolerance = DefaultSignatureTolerance
+	}
+	var unix string
+	var sigs []string
+	for _, part := range strings.Split(header, ",") {
+		key, value, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			continue
+		}
+		switch key {
+		case "t":
+			unix = value
+		case "v1":
+			sigs = append(sigs, value)
+		}
+	}
+	ts, err := strconv.ParseInt(unix, 10, 64)
+	if err != nil || len(sigs) == 0 {
+		return ErrInvalidSignature
+	}
+	if drift := now.Sub(time.Unix(ts, 0)); drift > tolerance || drift < -tolerance {
+		return ErrInvalidSignature
+	}
+	want := signatureDigest(secret, unix, body)
+	for _, sig := range sigs {
+		if hmac.Equal([]byte(sig), []byte(want)) {
+			return nil
+		}
+	}
+	return ErrInvalidSignature
+}
+
+func signatureDigest(secret, unix string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(unix))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
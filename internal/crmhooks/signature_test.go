@@ -0,0 +1,67 @@
+package crmhooks
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+// Test vector for receivers implementing verification in another language:
+// HMAC-SHA256 over "<unix seconds>.<raw body>", hex encoded.
+const (
+	vectorSecret    = "whsec_test_0123456789abcdef"
+	vectorUnix      = 1768496645 // 2026-01-15T17:04:05Z
+	vectorBody      = `{"id":"8d9c6a4e-7f1b-5c3a-9e2d-1b4f6a8c0e21","type":"lead.created","version":1,"org_id":"org-123","occurred_at":"2026-01-15T17:04:05Z","data":{"lead_id":"lead-456"}}`
+	vectorSignature = "t=1768496645,v1=042fd9b239d9b8ff034ae73abbeca0c2a9e4bc3afb7bced709f18520cbd1d2db"
+)
+
+func TestSign_TestVector(t *testing.T) {
+	got := Sign(vectorSecret, time.Unix(vectorUnix, 0), []byte(vectorBody))
+	if got != vectorSignature {
+		t.Fatalf("Sign() = %q, want %q", got, vectorSignature)
+	}
+}
+
+func TestVerifySignature(t *testing.T) {
+	signedAt := time.Unix(vectorUnix, 0)
+	tests := []struct {
+		name   string
+		secret string
+		header string
+		body   string
+		now    time.Time
+		ok     bool
+	}{
+		{"valid", vectorSecret, vectorSignature, vectorBody, signedAt.Add(time.Minute), true},
+		{"extra signatures during secret rotation", vectorSecret, "t=1768496645,v1=deadbeef," + vectorSignature[len("t=1768496645,"):], vectorBody, signedAt, true},
+		{"tampered body", vectorSecret, vectorSignature, vectorBody + " ", signedAt, false},
+		{"wrong secret", "whsec_other_0123456789", vectorSignature, vectorBody, signedAt, false},
+		{"stale timestamp", vectorSecret, vectorSignature, vectorBody, signedAt.Add(10 * time.Minute), false},
+		{"future timestamp", vectorSecret, vectorSignature, vectorBody, signedAt.Add(-10 * time.Minute), false},
+		{"missing timestamp", vectorSecret, "v1=042fd9b239d9b8ff034ae73abbeca0c2a9e4bc3afb7bced709f18520cbd1d2db", vectorBody, signedAt, false},
+		{"garbage", vectorSecret, "nonsense", vectorBody, signedAt, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := VerifySignature(tt.secret, tt.header, []byte(tt.body), tt.now, 0)
+			if tt.ok && err != nil {
+				t.Fatalf("VerifySignature() = %v, want nil", err)
+			}
+			if !tt.ok && !errors.Is(err, ErrInvalidSignature) {
+				t.Fatalf("VerifySignature() = %v, want ErrInvalidSignature", err)
+			}
+		})
+	}
+}
+
+// Receivers verify the X-Medspa-Signature header against the raw request
+// body before parsing it.
+func ExampleVerifySignature() {
+	header := "t=1768496645,v1=042fd9b239d9b8ff034ae73abbeca0c2a9e4bc3afb7bced709f18520cbd1d2db"
+	receivedAt := time.Date(2026, 1, 15, 17, 4, 30, 0, time.UTC)
+
+	err := VerifySignature(vectorSecret, header, []byte(vectorBody), receivedAt, DefaultSignatureTolerance)
+	fmt.Println(err == nil)
+	// Output: true
+}
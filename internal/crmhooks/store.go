@@ -0,0 +1,200 @@
+package crmhooks
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// Delivery statuses.
+const (
+	StatusPending   = "pending"
+	StatusDelivered = "delivered"
+	StatusDead      = "dead"
+)
+
+// claimLease is how long a claimed delivery is hidden from other deliverers.
+// It only matters if a process dies mid-POST; the row comes back afterwards.
+const claimLease = 2 * time.Minute
+
+// Querier is the subset of pgxpool.Pool used by Store.
+type Querier interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+}
+
+// Delivery is one event queued for one endpoint.
+type Delivery struct {
+	ID             uuid.UUID       `json:"id"`
+	OrgID          string          `json:"org_id"`
+	Endpoint       string          `json:"endpoint"`
+	EventID        uuid.UUID       `json:"event_id"`
+	EventType      string          `json:"event_type"`
+	Payload        json.RawMessage `json:"payload"`
+	Status         string          `json:"status"`
+	Attempts       int             `json:"attempts"`
+	NextAttemptAt  time.Time       `json:"next_attempt_at"`
+	LastStatusCode int             `json:"last_status_code,omitempty"`
+	LastError      string          `json:"last_error,omitempty"`
+	DeliveredAt    *time.Time      `json:"delivered_at,omitempty"`
+	DeadLetteredAt *time.Time      `json:"dead_lettered_at,omitempty"`
+	CreatedAt      time.Time       `json:"created_at"`
+}
+
+// Store persists deliveries in Postgres.
+type Store struct {
+	pool Querier
+}
+
+// NewStore constructs a delivery store. Returns nil without a pool.
+func NewStore(pool Querier) *Store {
+	if pool == nil {
+		return nil
+	}
+	return &Store{pool: pool}
+}
+
+const deliveryColumns = `id, org_id, endpoint, event_id, event_type, payload, status, attempts, next_attempt_at,
+	last_status_code, last_error, delivered_at, dead_lettered_at, created_at`
+
+// Enqueue queues evt for the named endpoint. Re-queuing an event the endpoint
+// already has is a no-op; the return value reports whether a row was added.
+func (s *Store) Enqueue(ctx context.Context, endpoint string, evt Event) (bool, error) {
+	eventID, err := uuid.Parse(evt.ID)
+	if err != nil {
+		return false, fmt.Errorf("crmhooks: invalid event id: %w", err)
+	}
+	payload, err := json.Marshal(evt)
+	if err != nil {
+		return false, fmt.Errorf("crmhooks: marshal event: %w", err)
+	}
+	tag, err := s.pool.Exec(ctx, `
+		INSERT INTO crm_webhook_deliveries (id, org_id, endpoint, event_id, event_type, payload)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (event_id, endpoint) DO NOTHING
+	`, uuid.New(), evt.OrgID, endpoint, eventID, evt.Type, payload)
+	if err != nil {
+		return false, fmt.Errorf("crmhooks: enqueue delivery: %w", err)
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+// Claim returns up to limit pending deliveries that are due, oldest first,
+// and pushes their next attempt out by a lease so concurrent deliverers skip
+// them.
+func (s *Store) Claim(ctx context.Context, now time.Time, limit int) ([]Delivery, error) {
+	rows, err := s.pool.Query(ctx, `
+		UPDATE crm_webhook_deliveries
+		SET next_attempt_at = $2, updated_at = now()
+		WHERE id IN (
+			SELECT id FROM crm_webhook_deliveries
+			WHERE status = 'pending' AND next_attempt_at <= $1
+			ORDER BY next_attempt_at
+			LIMIT $3
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING `+deliveryColumns, now, now.Add(claimLease), limit)
+	if err != nil {
+		return nil, fmt.Errorf("crmhooks: claim deliveries: %w", err)
+	}
+	return scanDeliveries(rows)
+}
+
+// MarkDelivered records a successful attempt.
+func (s *Store) MarkDelivered(ctx context.Context, id uuid.UUID, attempts, statusCode int, at time.Time) error {
+	_, err := s.pool.Exec(ctx, `
+		UPDATE crm_webhook_deliveries
+		SET status = 'delivered', attempts = $2, last_status_code = $3, last_error = '', delivered_at = $4, updated_at = now()
+		WHERE id = $1
+	`, id, attempts, statusCode, at)
+	if err != nil {
+		return fmt.Errorf("crmhooks: mark delivered: %w", err)
+	}
+	return nil
+}
+
+// Reschedule records a failed attempt and when to try again.
+func (s *Store) Reschedule(ctx context.Context, id uuid.UUID, attempts, statusCode int, lastErr string, next time.Time) error {
+	_, err := s.pool.Exec(ctx, `
+		UPDATE crm_webhook_deliveries
+		SET attempts = $2, last_status_code = $3, last_error = $4, next_attempt_at = $5, updated_at = now()
+		WHERE id = $1
+	`, id, attempts, statusCode, lastErr, next)
+	if err != nil {
+		return fmt.Errorf("crmhooks: reschedule delivery: %w", err)
+	}
+	return nil
+}
+
+// DeadLetter records the final failed attempt and stops retrying.
+func (s *Store) DeadLetter(ctx context.Context, id uuid.UUID, attempts, statusCode int, lastErr string, at time.Time) error {
+	_, err := s.pool.Exec(ctx, `
+		UPDATE crm_webhook_deliveries
+		SET status = 'dead', attempts = $2, last_status_code = $3, last_error = $4, dead_lettered_at = $5, updated_at = now()
+		WHERE id = $1
+	`, id, attempts, statusCode, lastErr, at)
+	if err != nil {
+		return fmt.Errorf("crmhooks: dead-letter delivery: %w", err)
+	}
+	return nil
+}
+
+// DeadLetters returns the org's dead-lettered deliveries, newest first.
+func (s *Store) DeadLetters(ctx context.Context, orgID string, limit int) ([]Delivery, error) {
+	rows, err := s.pool.Query(ctx, `
+		SELECT `+deliveryColumns+`
+		FROM crm_webhook_deliveries
+		WHERE org_id = $1 AND status = 'dead'
+		ORDER BY dead_lettered_at DESC
+		LIMIT $2
+	`, orgID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("crmhooks: list dead letters: %w", err)
+	}
+	return scanDeliveries(rows)
+}
+
+// ErrDeliveryNotFound is returned when a dead letter to retry doesn't exist.
+var ErrDeliveryNotFound = errors.New("crmhooks: dead-lettered delivery not found")
+
+// Retry puts a dead-lettered delivery back in the queue with a fresh set of
+// attempts.
+func (s *Store) Retry(ctx context.Context, orgID string, id uuid.UUID) error {
+	tag, err := s.pool.Exec(ctx, `
+		UPDATE crm_webhook_deliveries
+		SET status = 'pending', attempts = 0, next_attempt_at = now(), dead_lettered_at = NULL, updated_at = now()
+		WHERE id = $1 AND org_id = $2 AND status = 'dead'
+	`, id, orgID)
+	if err != nil {
+		return fmt.Errorf("crmhooks: retry delivery: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrDeliveryNotFound
+	}
+	return nil
+}
+
+func scanDeliveries(rows pgx.Rows) ([]Delivery, error) {
+	defer rows.Close()
+	out := []Delivery{}
+	for rows.Next() {
+		var d Delivery
+		var payload []byte
+		if err := rows.Scan(&d.ID, &d.OrgID, &d.Endpoint, &d.EventID, &d.EventType, &payload, &d.Status, &d.Attempts,
+			&d.NextAttemptAt, &d.LastStatusCode, &d.LastError, &d.DeliveredAt, &d.DeadLetteredAt, &d.CreatedAt); err != nil {
+			return nil, fmt.Errorf("crmhooks: scan delivery: %w", err)
+		}
+		d.Payload = append(json.RawMessage(nil), payload...)
+		out = append(out, d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("crmhooks: read deliveries: %w", err)
+	}
+	return out, nil
+}
@@ -0,0 +1,79 @@
+package demo
+
+import (
+	"hash/fnv"
+	"math/rand/v2"
+	"time"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/clinic"
+)
+
+const (
+	slotInterval = 30 * time.Minute
+	// slotOpenChance is the share of slots shown as open.
+	slotOpenChance = 0.35
+)
+
+// Slot is one open appointment time.
+type Slot struct {
+	Service  string    `json:"service"`
+	Provider string    `json:"provider,omitempty"`
+	Start    time.Time `json:"start"`
+}
+
+// FakeAvailability returns made-up open slots for every catalog service over
+// days days starting at from, within the clinic's business hours. Each
+// service's slots on a date depend only on seed, the service and the date, so
+// the same seed gives the same slots however the window is chosen.
+func FakeAvailability(cfg *clinic.Config, cat *Catalog, seed int64, from time.Time, days int) []Slot {
+	loc, err := time.LoadLocation(cfg.Timezone)
+	if err != nil {
+		loc = time.UTC
+	}
+	from = from.In(loc)
+	start := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, loc)
+
+	slots := []Slot{}
+	for _, svc := range cat.Services() {
+		duration := time.Duration(svc.DurationMinutes) * time.Minute
+		for d := 0; d < days; d++ {
+			day := start.AddDate(0, 0, d)
+			hours := cfg.BusinessHours.GetHoursForDay(day.Weekday())
+			if hours == nil {
+				continue
+			}
+			open, okOpen := clockOn(day, hours.Open)
+			closing, okClose := clockOn(day, hours.Close)
+			if !okOpen || !okClose {
+				continue
+			}
+			rng := slotRand(seed, svc.Name, day)
+			for t := open; !t.Add(duration).After(closing); t = t.Add(slotInterval) {
+				if rng.Float64() >= slotOpenChance {
+					continue
+				}
+				slot := Slot{Service: svc.Name, Start: t}
+				if len(svc.Providers) > 0 {
+					slot.Provider = svc.Providers[rng.IntN(len(svc.Providers))]
+				}
+				slots = append(slots, slot)
+			}
+		}
+	}
+	return slots
+}
+
+func slotRand(seed int64, service string, day time.Time) *rand.Rand {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(service + "|" + day.Format("2006-01-02")))
+	return rand.New(rand.NewPCG(uint64(seed), h.Sum64()))
+}
+
+// clockOn returns the "15:04" clock time on day.
+func clockOn(day time.Time, clock string) (time.Time, bool) {
+	t, err := time.Parse("15:04", clock)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return time.Date(day.Year(), day.Month(), day.Day(), t.Hour(), t.Minute(), 0, 0, day.Location()), true
+}
@@ -0,0 +1,216 @@
+// Package demo serves the clinic data behind sales demos, so a demo mirrors
+// the prospect's real service menu.
+package demo
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/clinic"
+)
+
+// uncategorized is the category for services without a configured one.
+const uncategorized = "Other"
+
+// defaultDurationMinutes is shown for services without a configured duration.
+const defaultDurationMinutes = 30
+
+// Catalog is a clinic's bookable services, grouped by menu category.
+type Catalog struct {
+	OrgID      string     `json:"org_id"`
+	ClinicName string     `json:"clinic_name"`
+	Categories []Category `json:"categories"`
+	// Financing are the clinic's patient financing providers, from its
+	// payment_methods config. CherryBanner shows the booking page's Cherry
+	// financing banner, only for clinics that offer Cherry.
+	Financing    []string `json:"financing"`
+	CherryBanner bool     `json:"cherry_banner"`
+	// Fallback is set when the org has no stored config and the catalog is
+	// the built-in demo menu.
+	Fallback bool `json:"fallback"`
+}
+
+// Category is one section of the service menu.
+type Category struct {
+	Name     string    `json:"name"`
+	Services []Service `json:"services"`
+}
+
+// Service is one bookable service.
+type Service struct {
+	Name            string `json:"name"`
+	MenuItemID      string `json:"menu_item_id,omitempty"`
+	DurationMinutes int    `json:"duration_minutes"`
+	// Providers are the display names of the providers who perform the
+	// service; ProviderCount may exceed it when names aren't configured.
+	Providers     []string `json:"providers"`
+	ProviderCount int      `json:"provider_count,omitempty"`
+}
+
+// Services returns every service in the catalog, in menu order.
+func (c *Catalog) Services() []Service {
+	var out []Service
+	for _, category := range c.Categories {
+		out = append(out, category.Services...)
+	}
+	return out
+}
+
+// BuildCatalog derives the service menu from clinic config. Moxie clinics list
+// their service menu items, deduplicated by item ID; other clinics list
+// Services. Categories come from ServiceCategories and are sorted by name,
+// with uncategorized services last.
+func BuildCatalog(cfg *clinic.Config) *Catalog {
+	cat := &Catalog{OrgID: cfg.OrgID, ClinicName: cfg.Name, Categories: []Category{}, Financing: []string{}}
+	if pm := cfg.PaymentMethods; pm != nil {
+		for _, provider := range pm.Financing {
+			if provider = strings.TrimSpace(provider); provider != "" {
+				cat.Financing = append(cat.Financing, provider)
+			}
+		}
+		cat.CherryBanner = pm.OffersFinancing("Cherry")
+	}
+	byCategory := map[string][]Service{}
+	for _, name := range serviceNames(cfg) {
+		svc := Service{
+			Name:            name,
+			MenuItemID:      cfg.ServiceMenuItemID(name),
+			DurationMinutes: defaultDurationMinutes,
+			Providers:       cfg.ProviderNamesForService(name),
+		}
+		if d := cfg.DurationForService(name); d > 0 {
+			svc.DurationMinutes = int(d.Minutes())
+		}
+		if cfg.MoxieConfig != nil && svc.MenuItemID != "" {
+			svc.ProviderCount = cfg.MoxieConfig.ServiceProviderCount[svc.MenuItemID]
+		}
+		if svc.ProviderCount < len(svc.Providers) {
+			svc.ProviderCount = len(svc.Providers)
+		}
+		category := cfg.CategoryForService(name)
+		if category == "" {
+			category = uncategorized
+		}
+		byCategory[category] = append(byCategory[category], svc)
+	}
+
+	names := make([]string, 0, len(byCategory))
+	for name := range byCategory {
+		if name != uncategorized {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	if _, ok := byCategory[uncategorized]; ok {
+		names = append(names, uncategorized)
+	}
+	for _, name := range names {
+		services := byCategory[name]
+		sort.Slice(services, func(i, j int) bool { return services[i].Name < services[j].Name })
+		cat.Categories = append(cat.Categories, Category{Name: name, Services: services})
+	}
+	return cat
+}
+
+// serviceNames returns the display names of the clinic's services. Menu item
+// keys are normalized, so a matching entry in Services supplies the display
+// name; otherwise the key is title-cased.
+func serviceNames(cfg *clinic.Config) []string {
+	display := make(map[string]string, len(cfg.Services))
+	for _, name := range cfg.Services {
+		if name = strings.TrimSpace(name); name != "" {
+			display[strings.ToLower(name)] = name
+		}
+	}
+	if cfg.MoxieConfig == nil || len(cfg.MoxieConfig.ServiceMenuItems) == 0 {
+		var names []string
+		for _, name := range cfg.Services {
+			if name = strings.TrimSpace(name); name != "" {
+				names = append(names, name)
+			}
+		}
+		return names
+	}
+
+	keys := make([]string, 0, len(cfg.MoxieConfig.ServiceMenuItems))
+	for key := range cfg.MoxieConfig.ServiceMenuItems {
+		keys = append(keys, key)
+	}
+	// Prefer keys that have a display name, so an alias like "botox" doesn't
+	// hide "tox" when both point at one item.
+	sort.Slice(keys, func(i, j int) bool {
+		_, di := display[keys[i]]
+		_, dj := display[keys[j]]
+		if di != dj {
+			return di
+		}
+		return keys[i] < keys[j]
+	})
+	seen := map[string]struct{}{}
+	var names []string
+	for _, key := range keys {
+		itemID := cfg.MoxieConfig.ServiceMenuItems[key]
+		if _, ok := seen[itemID]; ok || strings.TrimSpace(key) == "" {
+			continue
+		}
+		seen[itemID] = struct{}{}
+		name, ok := display[key]
+		if !ok {
+			name = titleCase(key)
+		}
+		names = append(names, name)
+	}
+	return names
+}
+
+func titleCase(s string) string {
+	words := strings.Fields(s)
+	for i, word := range words {
+		r, size := utf8.DecodeRuneInString(word)
+		words[i] = string(unicode.ToUpper(r)) + word[size:]
+	}
+	return strings.Join(words, " ")
+}
+
+// fallbackConfig is the built-in demo clinic shown when an org has no config.
+func fallbackConfig(orgID string) *clinic.Config {
+	cfg := clinic.DefaultConfig(orgID)
+	cfg.Name = "Demo MedSpa"
+	cfg.Services = []string{"Botox", "Lip Filler", "HydraFacial", "Laser Hair Removal"}
+	cfg.ServiceDurationMinutes = map[string]int{
+		"botox":              30,
+		"lip filler":         60,
+		"hydrafacial":        60,
+		"laser hair removal": 45,
+	}
+	cfg.ServiceCategories = map[string]string{
+		"botox":              "Injectables",
+		"lip filler":         "Injectables",
+		"hydrafacial":        "Facials",
+		"laser hair removal": "Laser",
+	}
+	cfg.MoxieConfig = &clinic.MoxieConfig{
+		ServiceMenuItems: map[string]string{
+			"botox":              "demo-1",
+			"lip filler":         "demo-2",
+			"hydrafacial":        "demo-3",
+			"laser hair removal": "demo-4",
+		},
+		ProviderNames: map[string]string{"p1": "Dr. Sarah Chen", "p2": "Jessica Miller, RN"},
+		ServiceProviders: map[string][]string{
+			"demo-1": {"p1", "p2"},
+			"demo-2": {"p1"},
+			"demo-3": {"p2"},
+			"demo-4": {"p2"},
+		},
+		ServiceProviderCount: map[string]int{"demo-1": 2, "demo-2": 1, "demo-3": 1, "demo-4": 1},
+	}
+	cfg.PaymentMethods = &clinic.PaymentMethods{
+		Accepted:  []string{"all major credit cards", "HSA/FSA cards"},
+		Financing: []string{"Cherry"},
+		Insurance: clinic.InsuranceNotAccepted,
+	}
+	return cfg
+}
@@ -0,0 +1,212 @@
+package demo
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/clinic"
+	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
+)
+
+type stubConfigs map[string]*clinic.Config
+
+func (s stubConfigs) Lookup(ctx context.Context, orgID string) (*clinic.Config, bool, error) {
+	cfg, ok := s[orgID]
+	return cfg, ok, nil
+}
+
+func moxieFixture() *clinic.Config {
+	cfg := clinic.DefaultConfig("org-1")
+	cfg.Name = "Forever 22"
+	cfg.Services = []string{"Tox", "Lip Filler"}
+	cfg.ServiceAliases = map[string]string{"botox": "Tox"}
+	cfg.ServiceDurationMinutes = map[string]int{"tox": 30, "lip filler": 60}
+	cfg.ServiceCategories = map[string]string{"tox": "Injectables", "lip filler": "Injectables", "hydrafacial": "Facials"}
+	cfg.MoxieConfig = &clinic.MoxieConfig{
+		ServiceMenuItems: map[string]string{
+			"tox":           "20424",
+			"botox":         "20424",
+			"lip filler":    "20425",
+			"hydrafacial":   "20426",
+			"weight loss":   "20427",
+			"chemical peel": "20428",
+		},
+		ProviderNames:        map[string]string{"33150": "Brandi Sesock", "33151": "Gale Tesar"},
+		ServiceProviders:     map[string][]string{"20424": {"33151", "33150"}, "20425": {"33150"}},
+		ServiceProviderCount: map[string]int{"20424": 2, "20425": 1, "20426": 2},
+	}
+	return cfg
+}
+
+func TestBuildCatalog_FromConfig(t *testing.T) {
+	cat := BuildCatalog(moxieFixture())
+
+	want := []Category{
+		{Name: "Facials", Services: []Service{
+			{Name: "Hydrafacial", MenuItemID: "20426", DurationMinutes: 30, Providers: []string{}, ProviderCount: 2},
+		}},
+		{Name: "Injectables", Services: []Service{
+			{Name: "Lip Filler", MenuItemID: "20425", DurationMinutes: 60, Providers: []string{"Brandi Sesock"}, ProviderCount: 1},
+			{Name: "Tox", MenuItemID: "20424", DurationMinutes: 30, Providers: []string{"Brandi Sesock", "Gale Tesar"}, ProviderCount: 2},
+		}},
+		{Name: "Other", Services: []Service{
+			{Name: "Chemical Peel", MenuItemID: "20428", DurationMinutes: 30, Providers: []string{}},
+			{Name: "Weight Loss", MenuItemID: "20427", DurationMinutes: 30, Providers: []string{}},
+		}},
+	}
+	if !reflect.DeepEqual(cat.Categories, want) {
+		t.Fatalf("categories = %+v\nwant %+v", cat.Categories, want)
+	}
+	if cat.OrgID != "org-1" || cat.ClinicName != "Forever 22" || cat.Fallback {
+		t.Fatalf("unexpected catalog header: %+v", cat)
+	}
+}
+
+func TestBuildCatalog_NonMoxieUsesServices(t *testing.T) {
+	cfg := clinic.DefaultConfig("org-2")
+	cfg.ServiceCategories = map[string]string{"botox": "Injectables"}
+	cat := BuildCatalog(cfg)
+
+	var names []string
+	for _, svc := range cat.Services() {
+		names = append(names, svc.Name)
+	}
+	if want := []string{"Botox", "Fillers", "Laser Treatments"}; !reflect.DeepEqual(names, want) {
+		t.Fatalf("services = %v, want %v", names, want)
+	}
+}
+
+func serveCatalog(t *testing.T, h *CatalogHandler, target string) (int, CatalogResponse) {
+	t.Helper()
+	r := chi.NewRouter()
+	r.Get("/demo/catalog/{orgID}", h.HandleCatalog)
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, target, nil))
+	var resp CatalogResponse
+	if rr.Code == http.StatusOK {
+		if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+	}
+	return rr.Code, resp
+}
+
+func TestHandleCatalog_FallsBackForUnknownOrg(t *testing.T) {
+	h := NewCatalogHandler(stubConfigs{"org-1": moxieFixture()}, logging.Default())
+
+	code, resp := serveCatalog(t, h, "/demo/catalog/prospect-9")
+	if code != http.StatusOK {
+		t.Fatalf("status = %d", code)
+	}
+	if !resp.Fallback || resp.OrgID != "prospect-9" || resp.ClinicName != "Demo MedSpa" {
+		t.Fatalf("expected the demo menu, got %+v", resp.Catalog)
+	}
+	if len(resp.Services()) != 4 {
+		t.Fatalf("expected the four demo services, got %+v", resp.Services())
+	}
+
+	_, known := serveCatalog(t, h, "/demo/catalog/org-1")
+	if known.Fallback || known.ClinicName != "Forever 22" {
+		t.Fatalf("expected the clinic's own menu, got %+v", known.Catalog)
+	}
+}
+
+func TestBuildCatalog_CherryBannerFollowsPaymentMethods(t *testing.T) {
+	cfg := moxieFixture()
+	if cat := BuildCatalog(cfg); cat.CherryBanner || len(cat.Financing) != 0 {
+		t.Fatalf("clinic without financing must not show the Cherry banner, got %+v", cat)
+	}
+
+	cfg.PaymentMethods = &clinic.PaymentMethods{Accepted: []string{"cash"}, Financing: []string{"CareCredit"}}
+	if cat := BuildCatalog(cfg); cat.CherryBanner || !reflect.DeepEqual(cat.Financing, []string{"CareCredit"}) {
+		t.Fatalf("CareCredit-only clinic must not show the Cherry banner, got %+v", cat)
+	}
+
+	cfg.PaymentMethods.Financing = append(cfg.PaymentMethods.Financing, " cherry ")
+	if cat := BuildCatalog(cfg); !cat.CherryBanner {
+		t.Fatalf("clinic offering Cherry should show the banner, got %+v", cat)
+	}
+	if cat := BuildCatalog(fallbackConfig("prospect-9")); !cat.CherryBanner {
+		t.Fatal("the demo clinic offers Cherry financing")
+	}
+}
+
+func TestHandleCatalog_SeededAvailabilityIsStable(t *testing.T) {
+	h := NewCatalogHandler(stubConfigs{"org-1": moxieFixture()}, logging.Default())
+
+	_, first := serveCatalog(t, h, "/demo/catalog/org-1?seed=42&from=2026-01-05&days=5")
+	h.now = func() time.Time { return time.Now().Add(time.Hour) }
+	_, again := serveCatalog(t, h, "/demo/catalog/org-1?seed=42&from=2026-01-05&days=5")
+	if first.Seed != 42 || len(first.Availability) == 0 {
+		t.Fatalf("expected seeded slots, got seed %d and %d slots", first.Seed, len(first.Availability))
+	}
+	if !reflect.DeepEqual(first.Availability, again.Availability) {
+		t.Fatal("expected the same slots for the same seed")
+	}
+
+	_, other := serveCatalog(t, h, "/demo/catalog/org-1?seed=43&from=2026-01-05&days=5")
+	if reflect.DeepEqual(first.Availability, other.Availability) {
+		t.Fatal("expected a different seed to change the slots")
+	}
+
+	// A later window repeats the overlapping days' slots.
+	_, shifted := serveCatalog(t, h, "/demo/catalog/org-1?seed=42&from=2026-01-06&days=4")
+	overlap := map[string]bool{}
+	for _, slot := range shifted.Availability {
+		overlap[slot.Service+slot.Start.String()+slot.Provider] = true
+	}
+	for _, slot := range first.Availability {
+		if slot.Start.Day() == 5 {
+			continue
+		}
+		if !overlap[slot.Service+slot.Start.String()+slot.Provider] {
+			t.Fatalf("slot %+v missing from the shifted window", slot)
+		}
+	}
+}
+
+func TestFakeAvailability_WithinBusinessHours(t *testing.T) {
+	cfg := moxieFixture()
+	loc, _ := time.LoadLocation(cfg.Timezone)
+	// 2026-01-09 is a Friday (closes 17:00); the 10th and 11th are closed.
+	from := time.Date(2026, 1, 9, 0, 0, 0, 0, loc)
+	cat := BuildCatalog(cfg)
+	slots := FakeAvailability(cfg, cat, 7, from, 3)
+	if len(slots) == 0 {
+		t.Fatal("expected slots on Friday")
+	}
+	for _, slot := range slots {
+		if slot.Start.Weekday() != time.Friday {
+			t.Fatalf("slot on a closed day: %+v", slot)
+		}
+		var minutes int
+		for _, svc := range cat.Services() {
+			if svc.Name == slot.Service {
+				minutes = svc.DurationMinutes
+			}
+		}
+		if slot.Start.Hour() < 9 || slot.Start.Add(time.Duration(minutes)*time.Minute).After(from.Add(17*time.Hour)) {
+			t.Fatalf("slot outside business hours: %+v", slot)
+		}
+	}
+}
+
+func TestHandleCatalog_RejectsBadParams(t *testing.T) {
+	h := NewCatalogHandler(stubConfigs{}, logging.Default())
+	for _, target := range []string{
+		"/demo/catalog/org-1?seed=abc",
+		"/demo/catalog/org-1?days=30",
+		"/demo/catalog/org-1?from=01/05/2026",
+	} {
+		if code, _ := serveCatalog(t, h, target); code != http.StatusBadRequest {
+			t.Fatalf("%s: status = %d, want 400", target, code)
+		}
+	}
+}
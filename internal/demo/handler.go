@@ -0,0 +1,118 @@
+package demo
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/clinic"
+	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
+)
+
+const (
+	defaultAvailabilityDays = 7
+	maxAvailabilityDays     = 14
+)
+
+type configLookup interface {
+	Lookup(ctx context.Context, orgID string) (*clinic.Config, bool, error)
+}
+
+// CatalogHandler serves a clinic's service catalog and fake availability for
+// the mock booking page and sales demos.
+type CatalogHandler struct {
+	clinics configLookup
+	now     func() time.Time
+	logger  *logging.Logger
+}
+
+// NewCatalogHandler creates a catalog handler.
+func NewCatalogHandler(clinics configLookup, logger *logging.Logger) *CatalogHandler {
+	if logger == nil {
+		logger = logging.Default()
+	}
+	return &CatalogHandler{
+		clinics: clinics,
+		now:     time.Now,
+		logger:  logger,
+	}
+}
+
+// CatalogResponse is the catalog plus fake open slots.
+type CatalogResponse struct {
+	*Catalog
+	// Seed reproduces Availability when passed back as ?seed=.
+	Seed         int64  `json:"seed"`
+	Availability []Slot `json:"availability"`
+}
+
+// HandleCatalog returns the clinic's service catalog, or the built-in demo
+// menu when the org is unknown.
+// GET /demo/catalog/{orgID}?seed=42&from=2026-01-05&days=7
+func (h *CatalogHandler) HandleCatalog(w http.ResponseWriter, r *http.Request) {
+	orgID := chi.URLParam(r, "orgID")
+	if orgID == "" {
+		http.Error(w, `{"error": "org_id required"}`, http.StatusBadRequest)
+		return
+	}
+
+	q := r.URL.Query()
+	seed := h.now().UnixNano()
+	if raw := q.Get("seed"); raw != "" {
+		parsed, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			http.Error(w, `{"error": "seed must be an integer"}`, http.StatusBadRequest)
+			return
+		}
+		seed = parsed
+	}
+	days := defaultAvailabilityDays
+	if raw := q.Get("days"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed < 1 || parsed > maxAvailabilityDays {
+			http.Error(w, `{"error": "days must be between 1 and 14"}`, http.StatusBadRequest)
+			return
+		}
+		days = parsed
+	}
+
+	cfg, found, err := h.clinics.Lookup(r.Context(), orgID)
+	if err != nil {
+		h.logger.Error("failed to load clinic config for demo catalog", "org_id", orgID, "error", err)
+		http.Error(w, `{"error": "internal server error"}`, http.StatusInternalServerError)
+		return
+	}
+	if !found {
+		cfg = fallbackConfig(orgID)
+	}
+
+	from := h.now()
+	if raw := q.Get("from"); raw != "" {
+		loc, err := time.LoadLocation(cfg.Timezone)
+		if err != nil {
+			loc = time.UTC
+		}
+		parsed, err := time.ParseInLocation("2006-01-02", raw, loc)
+		if err != nil {
+			http.Error(w, `{"error": "from must be YYYY-MM-DD"}`, http.StatusBadRequest)
+			return
+		}
+		from = parsed
+	}
+
+	cat := BuildCatalog(cfg)
+	cat.Fallback = !found
+	resp := CatalogResponse{
+		Catalog:      cat,
+		Seed:         seed,
+		Availability: FakeAvailability(cfg, cat, seed, from, days),
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		h.logger.Error("failed to encode demo catalog", "org_id", orgID, "error", err)
+	}
+}
@@ -0,0 +1,349 @@
+package local
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SentSMS is one message captured by the fake Telnyx API.
+type SentSMS struct {
+	From string
+	To   string
+	Text string
+}
+
+// fakeTelnyx accepts outbound sends on /v2/messages and records them instead
+// of delivering anything.
+type fakeTelnyx struct {
+	srv *httptest.Server
+
+	mu   sync.Mutex
+	sent []SentSMS
+}
+
+func newFakeTelnyx() *fakeTelnyx {
+	f := &fakeTelnyx{}
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /v2/messages", f.handleSend)
+	f.srv = httptest.NewServer(mux)
+	return f
+}
+
+func (f *fakeTelnyx) handleSend(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		From string `json:"from"`
+		To   string `json:"to"`
+		Text string `json:"text"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "bad request", http.StatusBadRequest)
+		return
+	}
+	f.mu.Lock()
+	f.sent = append(f.sent, SentSMS{From: body.From, To: body.To, Text: body.Text})
+	id := fmt.Sprintf("fake-%d", len(f.sent))
+	f.mu.Unlock()
+
+	writeJSON(w, map[string]any{
+		"data": map[string]any{"id": id, "status": "queued", "text": body.Text},
+	})
+}
+
+// Sent returns a copy of every captured outbound message.
+func (f *fakeTelnyx) Sent() []SentSMS {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]SentSMS(nil), f.sent...)
+}
+
+// baseURL is the Telnyx API root, including the /v2 prefix.
+func (f *fakeTelnyx) baseURL() string {
+	return f.srv.URL + "/v2"
+}
+
+// fakeMoxie serves the two GraphQL operations the platform uses:
+// AvailableTimeSlots, answered from deterministic weekday fixtures, and
+// createAppointmentByClient, which always succeeds.
+type fakeMoxie struct {
+	srv *httptest.Server
+	loc *time.Location
+	// hours lists each provider's daily slot start times (hour, minute).
+	hours map[string][][2]int
+
+	mu       sync.Mutex
+	bookings int
+}
+
+func newFakeMoxie(loc *time.Location, hours map[string][][2]int) *fakeMoxie {
+	f := &fakeMoxie{loc: loc, hours: hours}
+	f.srv = httptest.NewServer(http.HandlerFunc(f.handle))
+	return f
+}
+
+func (f *fakeMoxie) handle(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		OperationName string          `json:"operationName"`
+		Variables     json.RawMessage `json:"variables"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "bad request", http.StatusBadRequest)
+		return
+	}
+	switch req.OperationName {
+	case "AvailableTimeSlots":
+		f.handleAvailability(w, req.Variables)
+	case "createAppointmentByClient":
+		f.mu.Lock()
+		f.bookings++
+		id := fmt.Sprintf("appt-%d", f.bookings)
+		f.mu.Unlock()
+		writeJSON(w, map[string]any{
+			"data": map[string]any{
+				"createAppointmentByClient": map[string]any{
+					"ok":                   true,
+					"message":              "",
+					"clientAccessToken":    "fake-token",
+					"scheduledAppointment": map[string]any{"id": id},
+				},
+			},
+		})
+	default:
+		writeJSON(w, map[string]any{
+			"errors": []map[string]string{{"message": "unsupported operation " + req.OperationName}},
+		})
+	}
+}
+
+func (f *fakeMoxie) handleAvailability(w http.ResponseWriter, raw json.RawMessage) {
+	var vars struct {
+		StartDate string `json:"startDate"`
+		EndDate   string `json:"endDate"`
+		Services  []struct {
+			ProviderID string `json:"providerId"`
+		} `json:"services"`
+	}
+	if err := json.Unmarshal(raw, &vars); err != nil {
+		http.Error(w, "bad variables", http.StatusBadRequest)
+		return
+	}
+	start, err1 := time.ParseInLocation("2006-01-02", vars.StartDate, f.loc)
+	end, err2 := time.ParseInLocation("2006-01-02", vars.EndDate, f.loc)
+	if err1 != nil || err2 != nil {
+		http.Error(w, "bad date range", http.StatusBadRequest)
+		return
+	}
+	providerID := ""
+	if len(vars.Services) > 0 {
+		providerID = vars.Services[0].ProviderID
+	}
+
+	type slot struct {
+		Start string `json:"start"`
+		End   string `json:"end"`
+	}
+	type date struct {
+		Date  string `json:"date"`
+		Slots []slot `json:"slots"`
+	}
+	var dates []date
+	for day := start; !day.After(end); day = day.AddDate(0, 0, 1) {
+		if day.Weekday() == time.Saturday || day.Weekday() == time.Sunday {
+			continue
+		}
+		d := date{Date: day.Format("2006-01-02")}
+		for _, hm := range f.startTimes(providerID) {
+			s := time.Date(day.Year(), day.Month(), day.Day(), hm[0], hm[1], 0, 0, f.loc)
+			d.Slots = append(d.Slots, slot{
+				Start: s.Format(time.RFC3339),
+				End:   s.Add(30 * time.Minute).Format(time.RFC3339),
+			})
+		}
+		dates = append(dates, d)
+	}
+	writeJSON(w, map[string]any{
+		"data": map[string]any{"availableTimeSlots": map[string]any{"dates": dates}},
+	})
+}
+
+// startTimes returns one provider's slot times, or the union across all
+// providers for no-preference searches.
+func (f *fakeMoxie) startTimes(providerID string) [][2]int {
+	if providerID != "" {
+		return f.hours[providerID]
+	}
+	seen := make(map[[2]int]bool)
+	var all [][2]int
+	for _, hours := range f.hours {
+		for _, hm := range hours {
+			if !seen[hm] {
+				seen[hm] = true
+				all = append(all, hm)
+			}
+		}
+	}
+	sort.Slice(all, func(i, j int) bool {
+		return all[i][0]*60+all[i][1] < all[j][0]*60+all[j][1]
+	})
+	return all
+}
+
+// squareCheckout is a checkout created through the fake Square API.
+type squareCheckout struct {
+	ID          string
+	AmountCents int64
+	Metadata    map[string]string
+}
+
+// fakeSquare implements the checkout and payment-link endpoints and, on Pay,
+// delivers a signed payment.updated webhook the way Square would.
+type fakeSquare struct {
+	srv          *httptest.Server
+	signatureKey string
+	// webhookURL is where Pay delivers payment events; set once the harness
+	// server is listening.
+	webhookURL string
+
+	mu        sync.Mutex
+	checkouts map[string]*squareCheckout
+	seq       int
+}
+
+func newFakeSquare(signatureKey string) *fakeSquare {
+	f := &fakeSquare{signatureKey: signatureKey, checkouts: make(map[string]*squareCheckout)}
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /v2/locations/{location}/checkouts", f.handleCheckout)
+	mux.HandleFunc("POST /v2/online-checkout/payment-links", f.handlePaymentLink)
+	f.srv = httptest.NewServer(mux)
+	return f
+}
+
+type squareOrderBody struct {
+	Metadata  map[string]string `json:"metadata"`
+	LineItems []struct {
+		BasePriceMoney struct {
+			Amount int64 `json:"amount"`
+		} `json:"base_price_money"`
+	} `json:"line_items"`
+}
+
+func (f *fakeSquare) handleCheckout(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Order struct {
+			Order squareOrderBody `json:"order"`
+		} `json:"order"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "bad request", http.StatusBadRequest)
+		return
+	}
+	c := f.record(body.Order.Order)
+	writeJSON(w, map[string]any{
+		"checkout": map[string]any{"id": c.ID, "checkout_page_url": f.checkoutURL(c.ID)},
+	})
+}
+
+func (f *fakeSquare) handlePaymentLink(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Order squareOrderBody `json:"order"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "bad request", http.StatusBadRequest)
+		return
+	}
+	c := f.record(body.Order)
+	writeJSON(w, map[string]any{
+		"payment_link": map[string]any{"id": c.ID, "url": f.checkoutURL(c.ID)},
+	})
+}
+
+func (f *fakeSquare) record(order squareOrderBody) *squareCheckout {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.seq++
+	c := &squareCheckout{ID: fmt.Sprintf("chk-%d", f.seq), Metadata: order.Metadata}
+	if len(order.LineItems) > 0 {
+		c.AmountCents = order.LineItems[0].BasePriceMoney.Amount
+	}
+	f.checkouts[c.ID] = c
+	return c
+}
+
+func (f *fakeSquare) checkoutURL(id string) string {
+	return f.srv.URL + "/checkout/" + id
+}
+
+// checkoutFromURL returns the checkout behind a link the platform sent.
+func (f *fakeSquare) checkoutFromURL(link string) (*squareCheckout, bool) {
+	id := link[strings.LastIndex(link, "/")+1:]
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	c, ok := f.checkouts[id]
+	return c, ok
+}
+
+// Pay completes a checkout by posting a signed payment.updated webhook.
+func (f *fakeSquare) Pay(checkoutID string) error {
+	f.mu.Lock()
+	c, ok := f.checkouts[checkoutID]
+	f.mu.Unlock()
+	if !ok {
+		return fmt.Errorf("fake square: unknown checkout %q", checkoutID)
+	}
+
+	paymentID := "pay-" + c.ID
+	payload, err := json.Marshal(map[string]any{
+		"id":         "evt-" + paymentID,
+		"event_id":   "evt-" + paymentID,
+		"created_at": time.Now().UTC().Format(time.RFC3339),
+		"type":       "payment.updated",
+		"data": map[string]any{
+			"object": map[string]any{
+				"payment": map[string]any{
+					"id":           paymentID,
+					"status":       "COMPLETED",
+					"order_id":     "order-" + c.ID,
+					"amount_money": map[string]any{"amount": c.AmountCents, "currency": "USD"},
+					"metadata":     c.Metadata,
+				},
+			},
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, f.webhookURL, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	mac := hmac.New(sha1.New, []byte(f.signatureKey))
+	mac.Write([]byte(f.webhookURL))
+	mac.Write(payload)
+	req.Header.Set("X-Square-Signature", base64.StdEncoding.EncodeToString(mac.Sum(nil)))
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("fake square: webhook returned %d", resp.StatusCode)
+	}
+	return nil
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
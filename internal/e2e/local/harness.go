@@ -0,0 +1,313 @@
+// Package local boots the SMS booking pipeline in-process for the shared E2E
+// scenarios: the real Telnyx and Square webhook handlers, LLM service, and
+// conversation worker, wired to the in-memory queue, miniredis, and fake
+// Telnyx, Moxie, and Square servers. The model is replaced by a scripted
+// responder, so a run needs no network access or credentials.
+//
+// Run it with `make e2e-local`.
+package local
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/clinic"
+	"github.com/wolfman30/medspa-ai-platform/internal/conversation"
+	"github.com/wolfman30/medspa-ai-platform/internal/e2e"
+	"github.com/wolfman30/medspa-ai-platform/internal/emr/moxie"
+	"github.com/wolfman30/medspa-ai-platform/internal/http/handlers"
+	"github.com/wolfman30/medspa-ai-platform/internal/leads"
+	"github.com/wolfman30/medspa-ai-platform/internal/messaging"
+	"github.com/wolfman30/medspa-ai-platform/internal/messaging/telnyxclient"
+	"github.com/wolfman30/medspa-ai-platform/internal/payments"
+	"github.com/wolfman30/medspa-ai-platform/internal/tenancy"
+	"github.com/wolfman30/medspa-ai-platform/pkg/apiclient"
+	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
+)
+
+const (
+	fakeAPIKey         = "local-e2e"
+	squareSignatureKey = "local-e2e-square-key"
+	squareLocationID   = "LOCAL"
+)
+
+// Harness is a running local stack. URL serves the webhook routes plus the
+// two admin endpoints the scenarios poll.
+type Harness struct {
+	URL string
+
+	srv    *httptest.Server
+	redis  *miniredis.Miniredis
+	rdb    *redis.Client
+	cancel context.CancelFunc
+	worker *conversation.Worker
+
+	clinicID    uuid.UUID
+	clinicCfg   *clinic.Config
+	clinics     *clinic.Store
+	leads       *leads.InMemoryRepository
+	messages    *memoryMessagingStore
+	transcripts *conversation.SMSTranscriptStore
+	selections  *conversation.TimeSelectionReader
+	payments    *memoryPayments
+	bookings    *memoryBookings
+	deposits    conversation.DepositSender
+
+	telnyx *fakeTelnyx
+	moxie  *fakeMoxie
+	square *fakeSquare
+}
+
+// Start boots the stack around the seeded clinic. Call Close when done.
+func Start(logger *logging.Logger) (*Harness, error) {
+	if logger == nil {
+		logger = logging.Default()
+	}
+	cfg := seedClinicConfig()
+	loc, err := time.LoadLocation(cfg.Timezone)
+	if err != nil {
+		return nil, fmt.Errorf("local: clinic timezone: %w", err)
+	}
+	clinicID, err := uuid.Parse(cfg.OrgID)
+	if err != nil {
+		return nil, fmt.Errorf("local: clinic id: %w", err)
+	}
+
+	mr, err := miniredis.Run()
+	if err != nil {
+		return nil, fmt.Errorf("local: miniredis: %w", err)
+	}
+	h := &Harness{
+		redis:     mr,
+		rdb:       redis.NewClient(&redis.Options{Addr: mr.Addr()}),
+		clinicID:  clinicID,
+		clinicCfg: cfg,
+		leads:     leads.NewInMemoryRepository(),
+		messages:  newMemoryMessagingStore(),
+		payments:  newMemoryPayments(),
+		bookings:  newMemoryBookings(),
+		telnyx:    newFakeTelnyx(),
+		moxie:     newFakeMoxie(loc, seedProviderHours),
+		square:    newFakeSquare(squareSignatureKey),
+	}
+	h.clinics = clinic.NewStore(h.rdb)
+	h.transcripts = conversation.NewSMSTranscriptStore(h.rdb)
+	h.selections = conversation.NewTimeSelectionReader(h.rdb)
+	h.messages.addNumber(cfg.SMSPhoneNumber, clinicID)
+	if err := h.seed(context.Background()); err != nil {
+		h.Close()
+		return nil, err
+	}
+
+	telnyx, err := telnyxclient.New(telnyxclient.Config{BaseURL: h.telnyx.baseURL(), APIKey: fakeAPIKey})
+	if err != nil {
+		h.Close()
+		return nil, fmt.Errorf("local: telnyx client: %w", err)
+	}
+	sender := messaging.NewTelnyxSender(fakeAPIKey, "", logger)
+	sender.SetBaseURL(h.telnyx.baseURL())
+
+	queue := conversation.NewMemoryQueue(64)
+	jobs := newMemoryJobs()
+	publisher := conversation.NewPublisher(queue, jobs, logger)
+	outbox := &syncOutbox{dispatcher: conversation.NewOutboxDispatcher(publisher)}
+	processed := newMemoryProcessed()
+	numbers := staticNumbers(cfg.SMSPhoneNumber)
+
+	moxieClient := moxie.NewClient(logger, moxie.WithEndpoint(h.moxie.srv.URL))
+	llm := conversation.NewLLMService(&scriptedLLM{cfg: cfg}, h.rdb, nil, "scripted", logger,
+		conversation.WithMoxieClient(moxieClient),
+		conversation.WithLeadsRepo(h.leads),
+		conversation.WithClinicStore(h.clinics),
+	)
+
+	checkout := payments.NewSquareCheckoutService(fakeAPIKey, squareLocationID, "", "", logger).WithBaseURL(h.square.srv.URL)
+	h.deposits = conversation.NewDepositDispatcher(h.payments, checkout, outbox, sender, numbers, h.leads, h.transcripts, nil, logger)
+
+	h.worker = conversation.NewWorker(llm, queue, jobs, sender, h.bookings, logger,
+		conversation.WithWorkerCount(1),
+		conversation.WithReceiveBatchSize(1),
+		conversation.WithReceiveWaitSeconds(1),
+		conversation.WithSMSTranscriptStore(h.transcripts),
+		conversation.WithClinicConfigStore(h.clinics),
+		conversation.WithWorkerLeadsRepo(h.leads),
+		conversation.WithWorkerMoxieClient(moxieClient),
+		conversation.WithOptOutChecker(h.messages),
+		conversation.WithProcessedEventsStore(processed),
+		conversation.WithDepositSender(h.deposits),
+	)
+	ctx, cancel := context.WithCancel(context.Background())
+	h.cancel = cancel
+	h.worker.Start(ctx)
+
+	telnyxWebhooks := handlers.NewTelnyxWebhookHandler(handlers.TelnyxWebhookConfig{
+		Store:        h.messages,
+		Processed:    processed,
+		Telnyx:       telnyx,
+		Conversation: publisher,
+		Leads:        h.leads,
+		Logger:       logger,
+		Transcript:   h.transcripts,
+		ClinicStore:  h.clinics,
+	})
+	squareWebhooks := payments.NewSquareWebhookHandler(squareSignatureKey, h.payments, h.leads, processed, outbox, numbers, nil, logger)
+
+	r := chi.NewRouter()
+	r.Post("/webhooks/telnyx/messages", telnyxWebhooks.HandleMessages)
+	r.Post("/webhooks/square", squareWebhooks.Handle)
+	r.Get("/admin/orgs/{orgID}/conversations/{conversationID}", h.getConversation)
+	r.Delete("/admin/clinics/{orgID}/phones/{phone}", h.purgePhone)
+	r.Get("/ready", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	h.srv = httptest.NewServer(r)
+	h.URL = h.srv.URL
+	h.square.webhookURL = h.URL + "/webhooks/square"
+	return h, nil
+}
+
+// Runner returns a scenario runner pointed at the harness. Polls and fixed
+// waits are shrunk to match a pipeline that answers in milliseconds.
+func (h *Harness) Runner() *e2e.Runner {
+	r := e2e.NewRunner(apiclient.New(h.URL), h.URL)
+	r.PollInterval = 100 * time.Millisecond
+	r.TimeScale = 0.1
+	return r
+}
+
+// Sent returns every SMS the platform sent through the fake Telnyx API.
+func (h *Harness) Sent() []SentSMS {
+	return h.telnyx.Sent()
+}
+
+// Close stops the worker and every fake server.
+func (h *Harness) Close() {
+	if h.cancel != nil {
+		h.cancel()
+		h.worker.Wait()
+	}
+	if h.srv != nil {
+		h.srv.Close()
+	}
+	h.telnyx.srv.Close()
+	h.moxie.srv.Close()
+	h.square.srv.Close()
+	_ = h.rdb.Close()
+	h.redis.Close()
+}
+
+func (h *Harness) seed(ctx context.Context) error {
+	if err := h.clinics.Set(ctx, h.clinicCfg); err != nil {
+		return fmt.Errorf("local: seed clinic config: %w", err)
+	}
+	return nil
+}
+
+// getConversation mirrors the admin conversation detail endpoint's Redis
+// path. Without the conversations table, status comes from the time-selection
+// state: slots on offer and none picked means awaiting_time_selection.
+func (h *Harness) getConversation(w http.ResponseWriter, r *http.Request) {
+	orgID := chi.URLParam(r, "orgID")
+	conversationID := chi.URLParam(r, "conversationID")
+	if decoded, err := url.PathUnescape(conversationID); err == nil {
+		conversationID = decoded
+	}
+
+	conv := apiclient.Conversation{
+		ID:            conversationID,
+		OrgID:         orgID,
+		Channel:       "sms",
+		CustomerPhone: "+" + conversationID[strings.LastIndex(conversationID, ":")+1:],
+		Status:        "active",
+		Messages:      []apiclient.Message{},
+		Metadata:      apiclient.ConversationMetadata{Source: "redis"},
+	}
+	messages, err := h.transcripts.List(r.Context(), conversationID, 0)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	for _, msg := range messages {
+		conv.Messages = append(conv.Messages, apiclient.Message{
+			ID:                msg.ID,
+			Role:              msg.Role,
+			Content:           msg.Body,
+			Timestamp:         msg.Timestamp.Format(time.RFC3339),
+			From:              msg.From,
+			To:                msg.To,
+			ProviderMessageID: msg.ProviderMessageID,
+			Status:            msg.Status,
+			ErrorReason:       msg.ErrorReason,
+		})
+		conv.Metadata.TotalMessages++
+		switch msg.Role {
+		case "user":
+			conv.Metadata.CustomerMessages++
+		case "assistant":
+			conv.Metadata.AIMessages++
+		}
+	}
+	if len(messages) > 0 {
+		conv.StartedAt = messages[0].Timestamp.Format(time.RFC3339)
+	}
+	if state, err := h.selections.Load(r.Context(), conversationID); err == nil && state != nil &&
+		len(state.PresentedSlots) > 0 && !state.SlotSelected {
+		conv.Status = "awaiting_time_selection"
+	}
+	writeJSON(w, conv)
+}
+
+// purgePhone resets everything the stack holds for one patient. The harness
+// serves a single clinic, so Redis is flushed wholesale and the clinic config
+// re-seeded rather than hunting down each per-conversation key.
+func (h *Harness) purgePhone(w http.ResponseWriter, r *http.Request) {
+	phone := chi.URLParam(r, "phone")
+	if decoded, err := url.PathUnescape(phone); err == nil {
+		phone = decoded
+	}
+	e164 := messaging.NormalizeE164(phone)
+	digits := strings.TrimPrefix(e164, "+")
+
+	removedLeads, err := h.leads.DeleteByPhone(r.Context(), tenancy.ForOrg(h.clinicCfg.OrgID), e164)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	removedMessages := h.messages.purge(h.clinicID, e164)
+	redisKeys := len(h.redis.Keys())
+	h.redis.FlushAll()
+	if err := h.seed(r.Context()); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, apiclient.PurgeResult{
+		OrgID:          h.clinicCfg.OrgID,
+		Phone:          phone,
+		PhoneDigits:    digits,
+		PhoneE164:      e164,
+		ConversationID: fmt.Sprintf("sms:%s:%s", h.clinicCfg.OrgID, digits),
+		Deleted: apiclient.PurgeCounts{
+			Leads:    int64(removedLeads),
+			Messages: int64(removedMessages),
+		},
+		RedisDeleted: int64(redisKeys),
+	})
+}
+
+// staticNumbers answers every org with the seeded clinic's number.
+type staticNumbers string
+
+func (n staticNumbers) DefaultFromNumber(orgID string) string {
+	return string(n)
+}
@@ -0,0 +1,140 @@
+//go:build e2elocal
+
+package local
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/conversation"
+	"github.com/wolfman30/medspa-ai-platform/internal/e2e"
+	"github.com/wolfman30/medspa-ai-platform/internal/payments"
+	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
+)
+
+// testWriter routes the scenario log through t.Log.
+type testWriter struct{ t *testing.T }
+
+func (w testWriter) Write(p []byte) (int, error) {
+	w.t.Log(strings.TrimRight(string(p), "\n"))
+	return len(p), nil
+}
+
+// isConfirmed reports whether the lead's booking was confirmed.
+func (b *memoryBookings) isConfirmed(leadID uuid.UUID) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	_, ok := b.confirmed[leadID]
+	return ok
+}
+
+// status reports a payment's current status, or "" when it doesn't exist.
+func (p *memoryPayments) status(id string) string {
+	parsed, err := uuid.Parse(id)
+	if err != nil {
+		return ""
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if payment, ok := p.payments[parsed]; ok {
+		return payment.Status
+	}
+	return ""
+}
+
+func startHarness(t *testing.T) *Harness {
+	t.Helper()
+	h, err := Start(logging.New("error"))
+	if err != nil {
+		t.Fatalf("start harness: %v", err)
+	}
+	t.Cleanup(h.Close)
+	return h
+}
+
+func TestLocalE2E(t *testing.T) {
+	h := startHarness(t)
+	runner := h.Runner()
+	runner.Out = testWriter{t}
+
+	sum, err := runner.Run(e2e.CoreScenarios...)
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if sum.Failed > 0 {
+		t.Fatalf("%d checks failed:\n%s", sum.Failed, strings.Join(sum.Results, "\n"))
+	}
+	if len(h.Sent()) == 0 {
+		t.Fatal("no SMS reached the fake Telnyx API")
+	}
+}
+
+func TestLocalSquareDeposit(t *testing.T) {
+	h := startHarness(t)
+	ctx := context.Background()
+	orgID := h.clinicCfg.OrgID
+	phone := e2e.DefaultTestPhone
+
+	lead, err := h.leads.GetOrCreateByPhone(ctx, orgID, phone, "telnyx_sms", "Andy Wolf")
+	if err != nil {
+		t.Fatalf("create lead: %v", err)
+	}
+	conversationID := fmt.Sprintf("sms:%s:%s", orgID, strings.TrimPrefix(phone, "+"))
+	msg := conversation.MessageRequest{
+		OrgID:          orgID,
+		LeadID:         lead.ID,
+		ConversationID: conversationID,
+		Channel:        conversation.ChannelSMS,
+		From:           phone,
+		To:             h.clinicCfg.SMSPhoneNumber,
+	}
+	resp := &conversation.Response{
+		ConversationID: conversationID,
+		DepositIntent:  &conversation.DepositIntent{AmountCents: 5000, Description: "Botox deposit"},
+	}
+	if err := h.deposits.SendDeposit(ctx, msg, resp); err != nil {
+		t.Fatalf("send deposit: %v", err)
+	}
+
+	linkPattern := regexp.MustCompile(regexp.QuoteMeta(h.square.srv.URL) + `/checkout/\S+`)
+	var link string
+	for _, sms := range h.Sent() {
+		if l := linkPattern.FindString(sms.Text); l != "" && sms.To == phone {
+			link = l
+		}
+	}
+	if link == "" {
+		t.Fatalf("no checkout link texted to the patient: %+v", h.Sent())
+	}
+	checkout, ok := h.square.checkoutFromURL(link)
+	if !ok {
+		t.Fatalf("link %q does not match a fake Square checkout", link)
+	}
+	if checkout.Metadata["lead_id"] != lead.ID || checkout.AmountCents != 5000 {
+		t.Fatalf("checkout = %+v, want lead %s and 5000 cents", checkout, lead.ID)
+	}
+
+	sentBefore := len(h.Sent())
+	if err := h.square.Pay(checkout.ID); err != nil {
+		t.Fatalf("pay: %v", err)
+	}
+	if got := h.payments.status(checkout.Metadata["booking_intent_id"]); got != payments.PaymentStatusSucceeded {
+		t.Fatalf("payment status = %q, want %q", got, payments.PaymentStatusSucceeded)
+	}
+	deadline := time.Now().Add(10 * time.Second)
+	for len(h.Sent()) == sentBefore {
+		if time.Now().After(deadline) {
+			t.Fatal("worker never texted a payment confirmation")
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	if !h.bookings.isConfirmed(uuid.MustParse(lead.ID)) {
+		t.Fatal("paid deposit did not confirm the booking")
+	}
+}
@@ -0,0 +1,71 @@
+package local
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/clinic"
+	"github.com/wolfman30/medspa-ai-platform/internal/conversation"
+)
+
+// scriptedLLM stands in for the model. The platform's own guardrails and
+// time-selection flow decide when availability is fetched, so the responder
+// only has to behave like a well-prompted assistant: ask for the next missing
+// qualification, in checklist order, and answer the auxiliary classifiers
+// conservatively.
+type scriptedLLM struct {
+	cfg *clinic.Config
+}
+
+var _ conversation.LLMClient = (*scriptedLLM)(nil)
+
+func (l *scriptedLLM) Complete(ctx context.Context, req conversation.LLMRequest) (conversation.LLMResponse, error) {
+	if len(req.System) > 0 && strings.HasPrefix(req.System[0], "You are a decision agent for MedSpa AI") {
+		return conversation.LLMResponse{Text: `{"collect": false, "amount_cents": 0, "description": "", "success_url": "", "cancel_url": ""}`}, nil
+	}
+	last := ""
+	if n := len(req.Messages); n > 0 {
+		last = req.Messages[n-1].Content
+	}
+	switch {
+	case strings.Contains(last, `{"category"`):
+		return conversation.LLMResponse{Text: `{"category": "other"}`}, nil
+	case strings.Contains(last, "Which option did the patient choose"):
+		return conversation.LLMResponse{Text: "unclear"}, nil
+	}
+	return conversation.LLMResponse{Text: l.nextQuestion(req), StopReason: "end_turn"}, nil
+}
+
+// nextQuestion asks for the first qualification the conversation is missing.
+func (l *scriptedLLM) nextQuestion(req conversation.LLMRequest) string {
+	history := make([]conversation.ChatMessage, 0, len(req.System)+len(req.Messages))
+	for _, s := range req.System {
+		history = append(history, conversation.ChatMessage{Role: conversation.ChatRoleSystem, Content: s})
+	}
+	history = append(history, req.Messages...)
+
+	snap := conversation.BuildQualificationSnapshot(history, l.cfg, nil, time.Now())
+	switch {
+	case snap.Missing.Name:
+		return "Thanks for reaching out! May I have your full name?"
+	case snap.Missing.Service:
+		return fmt.Sprintf("Nice to meet you, %s! What treatment are you interested in?", snap.Name)
+	case snap.Missing.PatientType:
+		return "Have you visited us before, or would this be your first time?"
+	case snap.Missing.Schedule:
+		return "What days and times work best for you?"
+	case snap.Missing.ProviderPreference:
+		names := l.cfg.ProviderNamesForService(snap.ServiceResolved)
+		sort.Strings(names)
+		if len(names) == 0 {
+			return "Do you have a provider preference, or would you like the first available appointment?"
+		}
+		return fmt.Sprintf("Do you have a provider preference (%s), or would you like the first available appointment?", strings.Join(names, " or "))
+	case snap.Missing.ExtraQualification:
+		return "Could you tell me a little more about the area you'd like treated?"
+	}
+	return "Perfect, let me check available times for you."
+}
@@ -0,0 +1,65 @@
+package local
+
+import (
+	"github.com/wolfman30/medspa-ai-platform/internal/clinic"
+	"github.com/wolfman30/medspa-ai-platform/internal/e2e"
+)
+
+// Moxie fixture IDs for the seeded clinic, mirroring Forever 22's menu.
+const (
+	seedMedspaID        = "1264"
+	seedToxItemID       = "18430"
+	seedMicroneedlingID = "18427"
+	seedProviderBrandi  = "33950"
+	seedProviderGale    = "38627"
+)
+
+// seedProviderHours are each provider's fixed daily slots (clinic time). The
+// two calendars differ so provider-specific searches are distinguishable, and
+// both include late-afternoon times for "after 3pm" preferences.
+var seedProviderHours = map[string][][2]int{
+	seedProviderBrandi: {{10, 0}, {14, 0}, {16, 0}},
+	seedProviderGale:   {{11, 0}, {15, 30}, {17, 0}},
+}
+
+// seedClinicConfig returns the Moxie-booked clinic the core scenarios expect:
+// Botox (Tox) and microneedling, each offered by two providers.
+func seedClinicConfig() *clinic.Config {
+	cfg := clinic.DefaultConfig(e2e.DefaultOrgID)
+	cfg.Name = "Forever 22 Med Spa (local)"
+	cfg.Timezone = "America/New_York"
+	cfg.SMSPhoneNumber = e2e.DefaultClinicPhone
+	cfg.BookingPlatform = "moxie"
+	cfg.BookingURL = "https://app.joinmoxie.com/booking/forever-22"
+	cfg.Services = []string{"Botox", "Microneedling"}
+	cfg.ServiceAliases = map[string]string{
+		"botox":   "Tox",
+		"tox":     "Tox",
+		"jeuveau": "Tox",
+	}
+	cfg.BookingPolicies = []string{
+		"Please note: cancellations within 24 hours of your appointment forfeit the deposit.",
+		"Patients must be 18 or older.",
+	}
+	cfg.MoxieConfig = &clinic.MoxieConfig{
+		MedspaID:   seedMedspaID,
+		MedspaSlug: "forever-22",
+		ServiceMenuItems: map[string]string{
+			"tox":           seedToxItemID,
+			"microneedling": seedMicroneedlingID,
+		},
+		ServiceProviderCount: map[string]int{
+			seedToxItemID:       2,
+			seedMicroneedlingID: 2,
+		},
+		ProviderNames: map[string]string{
+			seedProviderBrandi: "Brandi Sesock",
+			seedProviderGale:   "Gale Tesar",
+		},
+		ServiceProviders: map[string][]string{
+			seedToxItemID:       {seedProviderBrandi, seedProviderGale},
+			seedMicroneedlingID: {seedProviderBrandi, seedProviderGale},
+		},
+	}
+	return cfg
+}
@@ -0,0 +1,342 @@
+package local
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/conversation"
+	"github.com/wolfman30/medspa-ai-platform/internal/events"
+	"github.com/wolfman30/medspa-ai-platform/internal/messaging"
+	paymentsql "github.com/wolfman30/medspa-ai-platform/internal/payments/sqlc"
+	"github.com/wolfman30/medspa-ai-platform/internal/tenancy"
+)
+
+// memoryMessagingStore stands in for the Postgres messaging store behind the
+// Telnyx webhook: inbound history, STOP/START state, and the number→clinic
+// lookup for the seeded clinics.
+type memoryMessagingStore struct {
+	mu           sync.Mutex
+	clinics      map[string]uuid.UUID // E.164 → clinic
+	messages     []messaging.MessageRecord
+	unsubscribed map[string]bool // clinic|recipient
+}
+
+func newMemoryMessagingStore() *memoryMessagingStore {
+	return &memoryMessagingStore{
+		clinics:      make(map[string]uuid.UUID),
+		unsubscribed: make(map[string]bool),
+	}
+}
+
+func (s *memoryMessagingStore) addNumber(number string, clinicID uuid.UUID) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.clinics[messaging.NormalizeE164(number)] = clinicID
+}
+
+// purge drops the messages and opt-out state for one patient phone.
+func (s *memoryMessagingStore) purge(clinicID uuid.UUID, phone string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	kept := s.messages[:0]
+	removed := 0
+	for _, m := range s.messages {
+		if m.ClinicID == clinicID && (m.From == phone || m.To == phone) {
+			removed++
+			continue
+		}
+		kept = append(kept, m)
+	}
+	s.messages = kept
+	delete(s.unsubscribed, optOutKey(clinicID, phone))
+	return removed
+}
+
+func optOutKey(clinicID uuid.UUID, recipient string) string {
+	return clinicID.String() + "|" + recipient
+}
+
+func (s *memoryMessagingStore) Begin(ctx context.Context) (pgx.Tx, error) {
+	return nopTx{}, nil
+}
+
+func (s *memoryMessagingStore) InsertMessage(ctx context.Context, q messaging.Querier, rec messaging.MessageRecord) (uuid.UUID, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	rec.ID = uuid.New()
+	s.messages = append(s.messages, rec)
+	return rec.ID, nil
+}
+
+func (s *memoryMessagingStore) InsertBrand(ctx context.Context, q messaging.Querier, rec messaging.BrandRecord) error {
+	return nil
+}
+
+func (s *memoryMessagingStore) InsertCampaign(ctx context.Context, q messaging.Querier, rec messaging.CampaignRecord) error {
+	return nil
+}
+
+func (s *memoryMessagingStore) UpsertHostedOrder(ctx context.Context, q messaging.Querier, record messaging.HostedOrderRecord) error {
+	return nil
+}
+
+func (s *memoryMessagingStore) DeleteHostedOrderByClinic(ctx context.Context, clinicID uuid.UUID, number string) error {
+	return nil
+}
+
+func (s *memoryMessagingStore) HasInboundMessage(ctx context.Context, clinicID uuid.UUID, from string, to string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, m := range s.messages {
+		if m.ClinicID == clinicID && m.Direction == "inbound" && m.From == from && m.To == to {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (s *memoryMessagingStore) IsUnsubscribed(ctx context.Context, clinicID uuid.UUID, recipient string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.unsubscribed[optOutKey(clinicID, recipient)], nil
+}
+
+func (s *memoryMessagingStore) InsertUnsubscribe(ctx context.Context, q messaging.Querier, clinicID uuid.UUID, recipient string, source string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.unsubscribed[optOutKey(clinicID, recipient)] = true
+	return nil
+}
+
+func (s *memoryMessagingStore) DeleteUnsubscribe(ctx context.Context, q messaging.Querier, clinicID uuid.UUID, recipient string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.unsubscribed, optOutKey(clinicID, recipient))
+	return nil
+}
+
+func (s *memoryMessagingStore) LookupClinicByNumber(ctx context.Context, number string) (uuid.UUID, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	id, ok := s.clinics[messaging.NormalizeE164(number)]
+	if !ok {
+		return uuid.Nil, pgx.ErrNoRows
+	}
+	return id, nil
+}
+
+func (s *memoryMessagingStore) UpdateMessageStatus(ctx context.Context, providerMessageID, status string, deliveredAt, failedAt *time.Time) error {
+	return nil
+}
+
+func (s *memoryMessagingStore) ScheduleRetry(ctx context.Context, q messaging.Querier, id uuid.UUID, status string, nextRetry time.Time) error {
+	return nil
+}
+
+func (s *memoryMessagingStore) ListRetryCandidates(ctx context.Context, limit int, maxAttempts int) ([]messaging.MessageRecord, error) {
+	return nil, nil
+}
+
+func (s *memoryMessagingStore) PendingHostedOrders(ctx context.Context, limit int) ([]messaging.HostedOrderRecord, error) {
+	return nil, nil
+}
+
+// nopTx satisfies pgx.Tx for the inbound webhook's transaction. Writes made
+// through it (the canonical outbox event) are discarded; the methods the
+// webhook never calls are left to the nil embedded interface.
+type nopTx struct {
+	pgx.Tx
+}
+
+func (nopTx) Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	return pgconn.NewCommandTag("INSERT 0 1"), nil
+}
+
+func (nopTx) Commit(ctx context.Context) error   { return nil }
+func (nopTx) Rollback(ctx context.Context) error { return nil }
+
+// memoryProcessed is the webhook/worker idempotency tracker.
+type memoryProcessed struct {
+	mu   sync.Mutex
+	seen map[string]bool
+}
+
+func newMemoryProcessed() *memoryProcessed {
+	return &memoryProcessed{seen: make(map[string]bool)}
+}
+
+func (p *memoryProcessed) AlreadyProcessed(ctx context.Context, provider, eventID string) (bool, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.seen[provider+"|"+eventID], nil
+}
+
+func (p *memoryProcessed) MarkProcessed(ctx context.Context, provider, eventID string) (bool, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	key := provider + "|" + eventID
+	if p.seen[key] {
+		return false, nil
+	}
+	p.seen[key] = true
+	return true, nil
+}
+
+// memoryJobs records conversation jobs for the publisher and worker.
+type memoryJobs struct {
+	mu   sync.Mutex
+	jobs map[string]*conversation.JobRecord
+}
+
+func newMemoryJobs() *memoryJobs {
+	return &memoryJobs{jobs: make(map[string]*conversation.JobRecord)}
+}
+
+func (j *memoryJobs) PutPending(ctx context.Context, job *conversation.JobRecord) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.jobs[job.JobID] = job
+	return nil
+}
+
+func (j *memoryJobs) GetJob(ctx context.Context, jobID string) (*conversation.JobRecord, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	job, ok := j.jobs[jobID]
+	if !ok {
+		return nil, conversation.ErrJobNotFound
+	}
+	return job, nil
+}
+
+func (j *memoryJobs) MarkCompleted(ctx context.Context, jobID string, resp *conversation.Response, conversationID string) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if job, ok := j.jobs[jobID]; ok {
+		job.Status = conversation.JobStatusCompleted
+	}
+	return nil
+}
+
+func (j *memoryJobs) MarkFailed(ctx context.Context, jobID string, errMsg string) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if job, ok := j.jobs[jobID]; ok {
+		job.Status = conversation.JobStatusFailed
+	}
+	return nil
+}
+
+// memoryBookings records the bookings the worker confirms once a deposit
+// is paid.
+type memoryBookings struct {
+	mu        sync.Mutex
+	confirmed map[uuid.UUID]*time.Time // lead → scheduled time
+}
+
+func newMemoryBookings() *memoryBookings {
+	return &memoryBookings{confirmed: make(map[uuid.UUID]*time.Time)}
+}
+
+func (b *memoryBookings) ConfirmBooking(ctx context.Context, orgID uuid.UUID, leadID uuid.UUID, scheduledFor *time.Time) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.confirmed[leadID] = scheduledFor
+	return nil
+}
+
+// memoryPayments backs both the deposit dispatcher (intents) and the Square
+// webhook (status updates by payment ID or provider reference).
+type memoryPayments struct {
+	mu       sync.Mutex
+	payments map[uuid.UUID]*paymentsql.Payment
+}
+
+func newMemoryPayments() *memoryPayments {
+	return &memoryPayments{payments: make(map[uuid.UUID]*paymentsql.Payment)}
+}
+
+func (p *memoryPayments) CreateIntent(ctx context.Context, orgID uuid.UUID, leadID uuid.UUID, provider string, bookingIntent uuid.UUID, amountCents int32, status string, scheduledFor *time.Time) (*paymentsql.Payment, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	id := uuid.New()
+	payment := &paymentsql.Payment{
+		OrgID:       orgID.String(),
+		Provider:    provider,
+		AmountCents: amountCents,
+		Status:      status,
+	}
+	payment.ID.Bytes = id
+	payment.ID.Valid = true
+	payment.LeadID.Bytes = leadID
+	payment.LeadID.Valid = true
+	payment.BookingIntentID.Bytes = bookingIntent
+	payment.BookingIntentID.Valid = bookingIntent != uuid.Nil
+	if scheduledFor != nil {
+		payment.ScheduledFor.Time = *scheduledFor
+		payment.ScheduledFor.Valid = true
+	}
+	p.payments[id] = payment
+	return payment, nil
+}
+
+func (p *memoryPayments) HasOpenDeposit(ctx context.Context, scope tenancy.OrgScope, leadID uuid.UUID) (bool, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, payment := range p.payments {
+		if scope.Allows(payment.OrgID) && payment.LeadID.Bytes == leadID &&
+			(payment.Status == "deposit_pending" || payment.Status == "succeeded") {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (p *memoryPayments) UpdateStatusByID(ctx context.Context, id uuid.UUID, status, providerRef string) (*paymentsql.Payment, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	payment, ok := p.payments[id]
+	if !ok {
+		return nil, fmt.Errorf("payment %s not found", id)
+	}
+	payment.Status = status
+	payment.ProviderRef.String = providerRef
+	payment.ProviderRef.Valid = providerRef != ""
+	return payment, nil
+}
+
+func (p *memoryPayments) GetByProviderRef(ctx context.Context, providerRef string) (*paymentsql.Payment, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, payment := range p.payments {
+		if payment.ProviderRef.Valid && payment.ProviderRef.String == providerRef {
+			return payment, nil
+		}
+	}
+	return nil, pgx.ErrNoRows
+}
+
+// syncOutbox delivers outbox events straight to the conversation dispatcher
+// instead of persisting them for the background deliverer.
+type syncOutbox struct {
+	dispatcher *conversation.OutboxDispatcher
+}
+
+func (o *syncOutbox) Insert(ctx context.Context, orgID string, eventType string, payload any) (uuid.UUID, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	entry := events.OutboxEntry{ID: uuid.New(), Aggregate: orgID, EventType: eventType, Payload: data, CreatedAt: time.Now().UTC()}
+	if err := o.dispatcher.Handle(ctx, entry); err != nil {
+		return uuid.Nil, err
+	}
+	return entry.ID, nil
+}
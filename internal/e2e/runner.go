@@ -0,0 +1,438 @@
+// Package e2e holds the SMS booking-flow scenarios shared by the remote E2E
+// script (scripts/e2e/run_e2e.go, which targets a deployed environment) and
+// the in-process local harness (internal/e2e/local, `make e2e-local`).
+//
+// Scenarios talk to the API exclusively through pkg/apiclient, so the same
+// code exercises either a real deployment or a locally booted stack.
+package e2e
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/conversation"
+	"github.com/wolfman30/medspa-ai-platform/internal/conversation/ackmsgs"
+	"github.com/wolfman30/medspa-ai-platform/internal/conversation/msgschema"
+	"github.com/wolfman30/medspa-ai-platform/pkg/apiclient"
+)
+
+// Defaults for the Forever 22 dev clinic the remote script targets.
+const (
+	DefaultTestPhone   = "+15005550002"
+	DefaultClinicPhone = "+14407448197"
+	DefaultOrgID       = "d0f9d4b4-05d2-40b3-ad4b-ae9a3b5c8599"
+
+	maxWaitSecs         = 90
+	defaultPollInterval = 2 * time.Second
+)
+
+// CoreScenarios are the booking-flow scenarios the local harness runs on
+// every `make e2e-local`.
+var CoreScenarios = []string{"happy-path", "multi-turn", "more-times", "provider-preference"}
+
+// Runner drives scenarios against one API base URL.
+type Runner struct {
+	API     *apiclient.Client
+	BaseURL string
+
+	OrgID       string
+	Phone       string
+	ClinicPhone string
+
+	// PollInterval is the delay between conversation polls (default 2s).
+	PollInterval time.Duration
+	// TimeScale multiplies every fixed sleep and wait budget in the
+	// scenarios. The remote script uses 1; the local harness, whose
+	// pipeline answers in milliseconds, shrinks it. Zero means 1.
+	TimeScale float64
+	// Out receives the scenario log (default os.Stdout).
+	Out io.Writer
+}
+
+// NewRunner returns a Runner targeting the default dev clinic.
+func NewRunner(api *apiclient.Client, baseURL string) *Runner {
+	return &Runner{
+		API:          api,
+		BaseURL:      strings.TrimRight(baseURL, "/"),
+		OrgID:        DefaultOrgID,
+		Phone:        DefaultTestPhone,
+		ClinicPhone:  DefaultClinicPhone,
+		PollInterval: defaultPollInterval,
+	}
+}
+
+// Summary is the aggregate outcome of a Run.
+type Summary struct {
+	Passed  int
+	Failed  int
+	Results []string
+}
+
+// ---------------------------------------------------------------------------
+// Scenario definition
+// ---------------------------------------------------------------------------
+
+type scenario struct {
+	Name string
+	Fn   func(r *Runner, t *T)
+}
+
+// T is a lightweight test context for a single scenario.
+type T struct {
+	passed int
+	failed int
+	name   string
+	out    io.Writer
+}
+
+func (t *T) check(name string, ok bool) {
+	if ok {
+		fmt.Fprintf(t.out, "    PASS: %s\n", name)
+		t.passed++
+	} else {
+		fmt.Fprintf(t.out, "    FAIL: %s\n", name)
+		t.failed++
+	}
+}
+
+// warn logs a non-blocking check — doesn't increment failed count.
+func (t *T) warn(name string, ok bool) {
+	if ok {
+		fmt.Fprintf(t.out, "    PASS: %s\n", name)
+		t.passed++
+	} else {
+		fmt.Fprintf(t.out, "    WARN: %s (non-blocking)\n", name)
+	}
+}
+
+func (t *T) fatalf(format string, args ...interface{}) {
+	fmt.Fprintf(t.out, "    FATAL: "+format+"\n", args...)
+	t.failed++
+}
+
+func (t *T) infof(format string, args ...interface{}) {
+	fmt.Fprintf(t.out, "    INFO: "+format+"\n", args...)
+}
+
+// Run executes the named scenarios (all of them when names is empty), runs
+// the universal duplicate-question check after each, and prints a summary.
+func (r *Runner) Run(names ...string) (Summary, error) {
+	want := make(map[string]bool, len(names))
+	for _, n := range names {
+		want[n] = true
+	}
+	for n := range want {
+		if !knownScenario(n) {
+			return Summary{}, fmt.Errorf("e2e: unknown scenario %q", n)
+		}
+	}
+
+	out := r.out()
+	var sum Summary
+	for _, s := range scenarios {
+		if len(want) > 0 && !want[s.Name] {
+			continue
+		}
+
+		fmt.Fprintf(out, "\n========================================\n")
+		fmt.Fprintf(out, "SCENARIO: %s\n", s.Name)
+		fmt.Fprintf(out, "========================================\n")
+
+		t := &T{name: s.Name, out: out}
+		s.Fn(r, t)
+
+		// Universal duplicate question check on every scenario's conversation
+		conv, convErr := r.getConversation()
+		if convErr == nil {
+			msgs := getMessages(conv)
+			dupes := checkNoDuplicateQuestions(msgs)
+			if len(dupes) == 0 {
+				t.check("no duplicate questions", true)
+			} else {
+				for _, d := range dupes {
+					t.check(fmt.Sprintf("no duplicate questions: %s", d), false)
+				}
+			}
+		}
+
+		sum.Passed += t.passed
+		sum.Failed += t.failed
+
+		status := "✅"
+		if t.failed > 0 {
+			status = "❌"
+		}
+		sum.Results = append(sum.Results, fmt.Sprintf("  %s %s (%d passed, %d failed)", status, s.Name, t.passed, t.failed))
+	}
+
+	fmt.Fprintf(out, "\n========================================\n")
+	fmt.Fprintln(out, "SUMMARY")
+	fmt.Fprintf(out, "========================================\n")
+	for _, res := range sum.Results {
+		fmt.Fprintln(out, res)
+	}
+	fmt.Fprintf(out, "\nTotal: %d passed, %d failed\n", sum.Passed, sum.Failed)
+	return sum, nil
+}
+
+func knownScenario(name string) bool {
+	for _, s := range scenarios {
+		if s.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Runner) out() io.Writer {
+	if r.Out != nil {
+		return r.Out
+	}
+	return os.Stdout
+}
+
+// scaled applies TimeScale to a nominal duration.
+func (r *Runner) scaled(d time.Duration) time.Duration {
+	if r.TimeScale <= 0 {
+		return d
+	}
+	return time.Duration(float64(d) * r.TimeScale)
+}
+
+func (r *Runner) sleep(d time.Duration) {
+	time.Sleep(r.scaled(d))
+}
+
+func (r *Runner) pollInterval() time.Duration {
+	if r.PollInterval > 0 {
+		return r.PollInterval
+	}
+	return defaultPollInterval
+}
+
+// conversationID mirrors the worker's "sms:<org>:<digits>" convention.
+func (r *Runner) conversationID() string {
+	return fmt.Sprintf("sms:%s:%s", r.OrgID, strings.TrimPrefix(r.Phone, "+"))
+}
+
+// ---------------------------------------------------------------------------
+// Helpers
+// ---------------------------------------------------------------------------
+
+func (r *Runner) purge() error {
+	_, err := r.API.PurgePhone(context.Background(), r.OrgID, r.Phone)
+	return err
+}
+
+func (r *Runner) sendSMS(text string) error {
+	return r.API.SimulateInboundSMS(context.Background(), apiclient.InboundSMS{
+		From:      r.Phone,
+		To:        r.ClinicPhone,
+		Text:      text,
+		MessageID: fmt.Sprintf("msg-%d", time.Now().UnixNano()),
+	})
+}
+
+func (r *Runner) getConversation() (*apiclient.Conversation, error) {
+	return r.API.GetConversation(context.Background(), r.OrgID, r.conversationID())
+}
+
+func (r *Runner) waitForStatus(targetStatus string, maxSecs int) (*apiclient.Conversation, error) {
+	deadline := time.Now().Add(r.scaled(time.Duration(maxSecs) * time.Second))
+	for time.Now().Before(deadline) {
+		time.Sleep(r.pollInterval())
+		conv, err := r.getConversation()
+		if err != nil {
+			continue
+		}
+		if conv.Status == targetStatus {
+			return conv, nil
+		}
+	}
+	return nil, fmt.Errorf("timed out waiting for status %q after %ds", targetStatus, maxSecs)
+}
+
+// waitForReply waits for at least `minUserMsgs` user messages and at least one
+// non-ack assistant response after the last user message. Returns all messages.
+// This handles the ack + delayed LLM response pattern.
+func (r *Runner) waitForReply(minUserMsgs int, maxSecs int) ([]apiclient.Message, error) {
+	deadline := time.Now().Add(r.scaled(time.Duration(maxSecs) * time.Second))
+	for time.Now().Before(deadline) {
+		time.Sleep(r.pollInterval())
+		conv, err := r.getConversation()
+		if err != nil {
+			continue
+		}
+		msgs := getMessages(conv)
+
+		// Count user messages
+		userCount := 0
+		for _, m := range msgs {
+			if isUserMsg(m) {
+				userCount++
+			}
+		}
+		if userCount < minUserMsgs {
+			continue
+		}
+
+		// Check for a non-ack assistant message after the last user message
+		lastUserIdx := -1
+		for i := len(msgs) - 1; i >= 0; i-- {
+			if isUserMsg(msgs[i]) {
+				lastUserIdx = i
+				break
+			}
+		}
+		if lastUserIdx < 0 {
+			continue
+		}
+
+		// Look for a substantive (non-ack) assistant response after last user msg
+		for i := lastUserIdx + 1; i < len(msgs); i++ {
+			if !isUserMsg(msgs[i]) && !isAckMessage(msgs[i].Content) {
+				return msgs, nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("timed out waiting for non-ack reply after %ds", maxSecs)
+}
+
+func isUserMsg(m apiclient.Message) bool {
+	return msgschema.Message{Direction: m.Direction, AuthorType: m.AuthorType, Role: m.Role}.FromPatient()
+}
+
+// isAckMessage returns true for the instant ack messages that precede the real LLM reply.
+func isAckMessage(content string) bool {
+	return ackmsgs.LooksLike(content)
+}
+
+func getMessages(conv *apiclient.Conversation) []apiclient.Message {
+	if conv == nil {
+		return nil
+	}
+	return conv.Messages
+}
+
+// lastRealAssistantMessage returns the last non-ack assistant message.
+func lastRealAssistantMessage(msgs []apiclient.Message) string {
+	for i := len(msgs) - 1; i >= 0; i-- {
+		if isUserMsg(msgs[i]) {
+			continue
+		}
+		if content := msgs[i].Content; !isAckMessage(content) {
+			return content
+		}
+	}
+	return ""
+}
+
+// allRealAssistantMessages returns all non-ack assistant messages concatenated.
+func allRealAssistantMessages(msgs []apiclient.Message) string {
+	var parts []string
+	for _, m := range msgs {
+		if isUserMsg(m) {
+			continue
+		}
+		if !isAckMessage(m.Content) {
+			parts = append(parts, m.Content)
+		}
+	}
+	return strings.Join(parts, "\n")
+}
+
+func extractSlotMessage(msgs []apiclient.Message) string {
+	for _, m := range msgs {
+		c := m.Content
+		lc := strings.ToLower(c)
+		if strings.Contains(lc, "reply with the number") || strings.Contains(lc, "reply with a number") {
+			return c
+		}
+		// Fallback for phrasing drift: numbered slot list without the exact instruction text.
+		if strings.Contains(c, "1") && strings.Contains(c, "2") &&
+			(strings.Contains(lc, "available") || strings.Contains(lc, "works best") || strings.Contains(lc, "times")) {
+			return c
+		}
+	}
+	return ""
+}
+
+func (r *Runner) waitForSlotMessage(maxSecs int) (*apiclient.Conversation, string, error) {
+	start := time.Now()
+	for {
+		conv, err := r.getConversation()
+		if err == nil {
+			msgs := getMessages(conv)
+			if slot := extractSlotMessage(msgs); slot != "" {
+				return conv, slot, nil
+			}
+		}
+		if time.Since(start) > r.scaled(time.Duration(maxSecs)*time.Second) {
+			return nil, "", fmt.Errorf("timed out waiting for slot message after %ds", maxSecs)
+		}
+		time.Sleep(r.pollInterval())
+	}
+}
+
+// checkNoDuplicateQuestions scans a conversation transcript for duplicate assistant
+// questions. Returns a list of violations (empty = clean). This is a universal check
+// that should run on EVERY conversation regardless of service or clinic.
+//
+// Each assistant message is categorized by question intent (see
+// conversation.DetectQuestionIntent), and a violation is flagged when the same intent
+// appears in consecutive assistant messages without a user response in between.
+func checkNoDuplicateQuestions(msgs []apiclient.Message) []string {
+	var transcript []conversation.ChatMessage
+	for _, m := range msgs {
+		if !isUserMsg(m) && isAckMessage(m.Content) {
+			continue // skip ack messages
+		}
+		transcript = append(transcript, conversation.ChatMessage{Role: m.Role, Content: m.Content})
+	}
+	var violations []string
+	for _, d := range conversation.FindDuplicateQuestions(transcript) {
+		violations = append(violations, fmt.Sprintf(
+			"DUPLICATE %s: %q ... then again: %q",
+			d.Intent,
+			truncate(d.First, 60),
+			truncate(d.Repeat, 60),
+		))
+	}
+	return violations
+}
+
+func truncate(s string, maxLen int) string {
+	if len(s) <= maxLen {
+		return s
+	}
+	return s[:maxLen] + "..."
+}
+
+func containsAny(s string, substrs ...string) bool {
+	lower := strings.ToLower(s)
+	for _, sub := range substrs {
+		if strings.Contains(lower, strings.ToLower(sub)) {
+			return true
+		}
+	}
+	return false
+}
+
+func containsAll(s string, substrs ...string) bool {
+	lower := strings.ToLower(s)
+	for _, sub := range substrs {
+		if !strings.Contains(lower, strings.ToLower(sub)) {
+			return false
+		}
+	}
+	return true
+}
+
+// setup purges test data before each scenario.
+func (r *Runner) setup() error {
+	return r.purge()
+}
@@ -49,7 +49,11 @@ func (a *BoulevardAdapter) ResolveAvailabilityWithCart(ctx context.Context, serv
 		return nil, "", fmt.Errorf("boulevard adapter: client not configured")
 	}
 
-	tz := "America/New_York" // TODO: get from clinic config
+	// date carries the clinic's location; fall back when it's server-local.
+	tz := "America/New_York"
+	if name := date.Location().String(); name != "" && name != "Local" {
+		tz = name
+	}
 	a.logger.Info("Boulevard: fetching real availability",
 		"service", serviceName, "provider", providerName, "dry_run", a.dryRun)
 
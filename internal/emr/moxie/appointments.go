@@ -106,7 +106,7 @@ func (c *Client) CreateAppointment(ctx context.Context, req CreateAppointmentReq
 		return nil, fmt.Errorf("create appointment failed: %w", err)
 	}
 	if len(resp.Errors) > 0 {
-		return nil, fmt.Errorf("moxie API error: %s", resp.Errors[0].Message)
+		return nil, &APIError{Message: resp.Errors[0].Message}
 	}
 
 	r := resp.Data.CreateAppointmentByClient
@@ -120,3 +120,69 @@ func (c *Client) CreateAppointment(ctx context.Context, req CreateAppointmentReq
 	}
 	return result, nil
 }
+
+// FindAppointment returns the ID of the client's scheduled appointment for
+// the requested service starting at the requested time, or "" when there is
+// none. It lets a retried booking check whether an earlier attempt already
+// created the appointment. In dry-run mode nothing is ever found.
+//
+// The clientScheduledAppointments query hasn't been confirmed against the
+// live API (see research/moxie-graphql-api.md), so callers must treat an
+// error as "unknown", never as "not booked".
+func (c *Client) FindAppointment(ctx context.Context, req FindAppointmentRequest) (string, error) {
+	if c.dryRun {
+		return "", nil
+	}
+	start, err := time.Parse(time.RFC3339, req.StartTime)
+	if err != nil {
+		return "", fmt.Errorf("parse start time %q: %w", req.StartTime, err)
+	}
+
+	variables := map[string]interface{}{
+		"medspaId": req.MedspaID,
+		"phone":    req.Phone,
+		"email":    req.Email,
+		"date":     start.UTC().Format("2006-01-02"),
+	}
+
+	query := `query clientScheduledAppointments($medspaId: ID!, $phone: String!, $email: String, $date: Date!) {
+		clientScheduledAppointments(medspaId: $medspaId, phone: $phone, email: $email, date: $date) {
+			id
+			services { serviceMenuItemId startTime }
+		}
+	}`
+
+	var resp struct {
+		Data struct {
+			Appointments []struct {
+				ID       string `json:"id"`
+				Services []struct {
+					ServiceMenuItemID string `json:"serviceMenuItemId"`
+					StartTime         string `json:"startTime"`
+				} `json:"services"`
+			} `json:"clientScheduledAppointments"`
+		} `json:"data"`
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+
+	if err := c.doRequest(ctx, "clientScheduledAppointments", variables, query, &resp); err != nil {
+		return "", fmt.Errorf("find appointment failed: %w", err)
+	}
+	if len(resp.Errors) > 0 {
+		return "", &APIError{Message: resp.Errors[0].Message}
+	}
+
+	for _, appt := range resp.Data.Appointments {
+		for _, svc := range appt.Services {
+			if svc.ServiceMenuItemID != req.ServiceMenuItemID {
+				continue
+			}
+			if t, err := time.Parse(time.RFC3339, svc.StartTime); err == nil && t.Equal(start) {
+				return appt.ID, nil
+			}
+		}
+	}
+	return "", nil
+}
@@ -64,14 +64,14 @@ func (c *Client) GetAvailableSlots(ctx context.Context, medspaID string, startDa
 		return nil, fmt.Errorf("availability query failed: %w", err)
 	}
 	if len(resp.Errors) > 0 {
-		return nil, fmt.Errorf("moxie API error: %s", resp.Errors[0].Message)
+		return nil, &APIError{Message: resp.Errors[0].Message}
 	}
 
 	result := &AvailabilityResult{}
 	for _, d := range resp.Data.AvailableTimeSlots.Dates {
 		ds := DateSlots{Date: d.Date}
 		for _, s := range d.Slots {
-			ds.Slots = append(ds.Slots, TimeSlot{Start: s.Start, End: s.End})
+			ds.Slots = append(ds.Slots, TimeSlot{Start: s.Start, End: s.End, ProviderID: svc.ProviderID})
 		}
 		result.Dates = append(result.Dates, ds)
 	}
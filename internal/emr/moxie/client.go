@@ -10,6 +10,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
@@ -42,6 +43,16 @@ func WithDryRun(dryRun bool) Option {
 	}
 }
 
+// WithEndpoint overrides the GraphQL endpoint, e.g. to point the client at a
+// local stub server. Empty values keep the default.
+func WithEndpoint(endpoint string) Option {
+	return func(c *Client) {
+		if endpoint = strings.TrimSpace(endpoint); endpoint != "" {
+			c.endpoint = endpoint
+		}
+	}
+}
+
 // NewClient creates a new Moxie API client with the given logger and options.
 func NewClient(logger *logging.Logger, opts ...Option) *Client {
 	c := &Client{
@@ -57,6 +68,16 @@ func NewClient(logger *logging.Logger, opts ...Option) *Client {
 	return c
 }
 
+// APIError is a GraphQL error returned by Moxie. The request reached Moxie and
+// was refused, so unlike a transport failure it had no effect.
+type APIError struct {
+	Message string
+}
+
+func (e *APIError) Error() string {
+	return "moxie API error: " + e.Message
+}
+
 // graphqlRequest is the generic GraphQL request envelope sent to Moxie's API.
 type graphqlRequest struct {
 	OperationName string      `json:"operationName"`
@@ -5,6 +5,9 @@ package moxie
 type TimeSlot struct {
 	Start string `json:"start"` // ISO 8601 with timezone, e.g. "2026-02-19T18:45:00-05:00"
 	End   string `json:"end"`
+	// ProviderID is the provider's userMedspaId when the query was scoped to
+	// one provider. Moxie doesn't attribute noPreference slots.
+	ProviderID string `json:"providerId,omitempty"`
 }
 
 // DateSlots groups available time slots under a specific calendar date.
@@ -49,3 +52,13 @@ type CreateAppointmentRequest struct {
 	IsNewClient              bool
 	NoPreferenceProviderUsed bool
 }
+
+// FindAppointmentRequest identifies a client's appointment by the service
+// booked and its start time.
+type FindAppointmentRequest struct {
+	MedspaID          string
+	Phone             string
+	Email             string
+	ServiceMenuItemID string
+	StartTime         string // ISO 8601 UTC
+}
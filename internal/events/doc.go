@@ -8,6 +8,7 @@
 //   - [OutboxStore]: writes and reads pending events from the outbox table.
 //   - [Deliverer]: polls the outbox and invokes a [DeliveryHandler] for each entry.
 //   - [ProcessedStore]: deduplicates inbound webhook events using deterministic UUIDs.
+//   - [WebhookLog]: captures raw webhook payloads so mishandled events can be replayed.
 //
 // Event types (types.go) are versioned structs (e.g. [PaymentSucceededV1])
 // serialized as JSON payloads inside the outbox.
@@ -52,17 +52,37 @@ type PaymentSucceededV1 struct {
 // PaymentFailedV1 is emitted when a deposit payment attempt fails or is
 // declined by the payment provider.
 type PaymentFailedV1 struct {
-	EventID         string    `json:"event_id"`
-	OrgID           string    `json:"org_id"`
-	LeadID          string    `json:"lead_id"`
-	BookingIntentID string    `json:"booking_intent_id,omitempty"`
-	Provider        string    `json:"provider"`
-	ProviderRef     string    `json:"provider_ref"`
-	AmountCents     int64     `json:"amount_cents"`
-	OccurredAt      time.Time `json:"occurred_at"`
-	LeadPhone       string    `json:"lead_phone,omitempty"`
-	FromNumber      string    `json:"from_number,omitempty"`
-	FailureStatus   string    `json:"failure_status,omitempty"`
+	EventID         string     `json:"event_id"`
+	OrgID           string     `json:"org_id"`
+	LeadID          string     `json:"lead_id"`
+	BookingIntentID string     `json:"booking_intent_id,omitempty"`
+	Provider        string     `json:"provider"`
+	ProviderRef     string     `json:"provider_ref"`
+	AmountCents     int64      `json:"amount_cents"`
+	OccurredAt      time.Time  `json:"occurred_at"`
+	LeadPhone       string     `json:"lead_phone,omitempty"`
+	FromNumber      string     `json:"from_number,omitempty"`
+	FailureStatus   string     `json:"failure_status,omitempty"`
+	ScheduledFor    *time.Time `json:"scheduled_for,omitempty"`
+}
+
+// PaymentDisputedV1 is emitted when a patient disputes (charges back) a
+// captured deposit.
+type PaymentDisputedV1 struct {
+	EventID         string     `json:"event_id"`
+	OrgID           string     `json:"org_id"`
+	LeadID          string     `json:"lead_id"`
+	BookingIntentID string     `json:"booking_intent_id,omitempty"`
+	Provider        string     `json:"provider"`
+	ProviderRef     string     `json:"provider_ref"`
+	DisputeID       string     `json:"dispute_id"`
+	Reason          string     `json:"reason,omitempty"`
+	State           string     `json:"state,omitempty"`
+	AmountCents     int64      `json:"amount_cents"`
+	OccurredAt      time.Time  `json:"occurred_at"`
+	LeadPhone       string     `json:"lead_phone,omitempty"`
+	LeadName        string     `json:"lead_name,omitempty"`
+	ScheduledFor    *time.Time `json:"scheduled_for,omitempty"`
 }
 
 // BookingConfirmedV1 is emitted when an appointment is successfully booked
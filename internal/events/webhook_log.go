@@ -0,0 +1,318 @@
+package events
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
+)
+
+// Webhook processing outcomes stored on webhook_events.outcome.
+const (
+	WebhookOutcomeReceived  = "received"
+	WebhookOutcomeProcessed = "processed"
+	WebhookOutcomeFailed    = "failed"
+)
+
+// ErrWebhookEventNotFound is returned when a captured webhook doesn't exist.
+var ErrWebhookEventNotFound = errors.New("events: webhook event not found")
+
+// capturedWebhookHeaders is the subset of request headers persisted with each
+// payload. Signatures are kept for auditing; everything else is dropped.
+var capturedWebhookHeaders = []string{
+	"Content-Type",
+	"User-Agent",
+	"X-Request-Id",
+	"X-Square-Signature",
+	"X-Square-Hmacsha256-Signature",
+	"Telnyx-Signature",
+	"Telnyx-Timestamp",
+}
+
+// WebhookEvent is a raw inbound webhook captured before processing.
+type WebhookEvent struct {
+	ID             uuid.UUID         `json:"id"`
+	Provider       string            `json:"provider"`
+	EventID        string            `json:"event_id,omitempty"`
+	ReceivedAt     time.Time         `json:"received_at"`
+	Headers        map[string]string `json:"headers,omitempty"`
+	Body           []byte            `json:"-"`
+	Outcome        string            `json:"outcome"`
+	OutcomeDetail  string            `json:"outcome_detail,omitempty"`
+	ReplayCount    int               `json:"replay_count"`
+	LastReplayedAt *time.Time        `json:"last_replayed_at,omitempty"`
+}
+
+// WebhookRecorder persists raw webhook payloads and their processing outcome.
+type WebhookRecorder interface {
+	Record(ctx context.Context, provider, eventID string, headers http.Header, body []byte) (uuid.UUID, error)
+	SetOutcome(ctx context.Context, id uuid.UUID, outcome, detail string) error
+}
+
+// WebhookLog stores raw webhook payloads in the webhook_events table so they
+// can be replayed after a handler bug.
+type WebhookLog struct {
+	pool rowQuerier
+}
+
+// NewWebhookLog creates a WebhookLog backed by the given connection pool.
+// Panics if pool is nil.
+func NewWebhookLog(pool *pgxpool.Pool) *WebhookLog {
+	if pool == nil {
+		panic("events: pgx pool required")
+	}
+	return &WebhookLog{pool: pool}
+}
+
+func newWebhookLogWithExec(exec rowQuerier) *WebhookLog {
+	if exec == nil {
+		panic("events: exec required")
+	}
+	return &WebhookLog{pool: exec}
+}
+
+// Record stores a webhook payload with outcome "received" and returns its id.
+func (l *WebhookLog) Record(ctx context.Context, provider, eventID string, headers http.Header, body []byte) (uuid.UUID, error) {
+	provider = strings.TrimSpace(provider)
+	if provider == "" {
+		return uuid.Nil, fmt.Errorf("events: webhook provider required")
+	}
+	kept := make(map[string]string)
+	for _, name := range capturedWebhookHeaders {
+		if v := headers.Get(name); v != "" {
+			kept[name] = v
+		}
+	}
+	headerJSON, err := json.Marshal(kept)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("events: marshal webhook headers: %w", err)
+	}
+	if body == nil {
+		body = []byte{}
+	}
+	id := uuid.New()
+	query := `
+		INSERT INTO webhook_events (id, provider, event_id, headers, body, outcome)
+		VALUES ($1, $2, NULLIF($3, ''), $4, $5, $6)
+	`
+	if _, err := l.pool.Exec(ctx, query, id, provider, strings.TrimSpace(eventID), headerJSON, body, WebhookOutcomeReceived); err != nil {
+		return uuid.Nil, fmt.Errorf("events: record webhook: %w", err)
+	}
+	return id, nil
+}
+
+// SetOutcome stores the result of processing a captured webhook.
+func (l *WebhookLog) SetOutcome(ctx context.Context, id uuid.UUID, outcome, detail string) error {
+	query := `UPDATE webhook_events SET outcome = $2, outcome_detail = NULLIF($3, '') WHERE id = $1`
+	if _, err := l.pool.Exec(ctx, query, id, outcome, detail); err != nil {
+		return fmt.Errorf("events: set webhook outcome: %w", err)
+	}
+	return nil
+}
+
+// RecordReplay stores the outcome of a replay and bumps the replay counter.
+func (l *WebhookLog) RecordReplay(ctx context.Context, id uuid.UUID, outcome, detail string) error {
+	query := `
+		UPDATE webhook_events
+		SET outcome = $2, outcome_detail = NULLIF($3, ''), replay_count = replay_count + 1, last_replayed_at = NOW()
+		WHERE id = $1
+	`
+	ct, err := l.pool.Exec(ctx, query, id, outcome, detail)
+	if err != nil {
+		return fmt.Errorf("events: record webhook replay: %w", err)
+	}
+	if ct.RowsAffected() == 0 {
+		return ErrWebhookEventNotFound
+	}
+	return nil
+}
+
+// Get loads a captured webhook by id.
+func (l *WebhookLog) Get(ctx context.Context, id uuid.UUID) (*WebhookEvent, error) {
+	query := `
+		SELECT id, provider, COALESCE(event_id, ''), received_at, headers, body, outcome,
+		       COALESCE(outcome_detail, ''), replay_count, last_replayed_at
+		FROM webhook_events
+		WHERE id = $1
+	`
+	var (
+		evt        WebhookEvent
+		headerJSON []byte
+	)
+	err := l.pool.QueryRow(ctx, query, id).Scan(
+		&evt.ID, &evt.Provider, &evt.EventID, &evt.ReceivedAt, &headerJSON, &evt.Body,
+		&evt.Outcome, &evt.OutcomeDetail, &evt.ReplayCount, &evt.LastReplayedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrWebhookEventNotFound
+		}
+		return nil, fmt.Errorf("events: get webhook: %w", err)
+	}
+	if len(headerJSON) > 0 {
+		if err := json.Unmarshal(headerJSON, &evt.Headers); err != nil {
+			return nil, fmt.Errorf("events: decode webhook headers: %w", err)
+		}
+	}
+	return &evt, nil
+}
+
+// DeleteReceivedBefore removes captured webhooks older than cutoff.
+func (l *WebhookLog) DeleteReceivedBefore(ctx context.Context, cutoff time.Time) (int64, error) {
+	ct, err := l.pool.Exec(ctx, `DELETE FROM webhook_events WHERE received_at < $1`, cutoff)
+	if err != nil {
+		return 0, fmt.Errorf("events: delete old webhooks: %w", err)
+	}
+	return ct.RowsAffected(), nil
+}
+
+// ResponseCapture is an http.ResponseWriter that records the status and body
+// a webhook handler produced. When next is set, writes pass through to it.
+type ResponseCapture struct {
+	next   http.ResponseWriter
+	header http.Header
+	Status int
+	Body   bytes.Buffer
+}
+
+// NewResponseCapture wraps next; pass nil to capture without forwarding.
+func NewResponseCapture(next http.ResponseWriter) *ResponseCapture {
+	return &ResponseCapture{next: next, header: make(http.Header)}
+}
+
+// Header implements http.ResponseWriter.
+func (c *ResponseCapture) Header() http.Header {
+	if c.next != nil {
+		return c.next.Header()
+	}
+	return c.header
+}
+
+// WriteHeader implements http.ResponseWriter.
+func (c *ResponseCapture) WriteHeader(status int) {
+	if c.Status == 0 {
+		c.Status = status
+	}
+	if c.next != nil {
+		c.next.WriteHeader(status)
+	}
+}
+
+// Write implements http.ResponseWriter.
+func (c *ResponseCapture) Write(p []byte) (int, error) {
+	if c.Status == 0 {
+		c.Status = http.StatusOK
+	}
+	c.Body.Write(p)
+	if c.next != nil {
+		return c.next.Write(p)
+	}
+	return len(p), nil
+}
+
+// Outcome maps the captured response to a webhook outcome and detail.
+func (c *ResponseCapture) Outcome() (string, string) {
+	status := c.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	if status >= 200 && status < 300 {
+		return WebhookOutcomeProcessed, ""
+	}
+	detail := strings.TrimSpace(c.Body.String())
+	if len(detail) > 500 {
+		detail = detail[:500]
+	}
+	return WebhookOutcomeFailed, fmt.Sprintf("%d %s", status, detail)
+}
+
+// CaptureWebhook records body before running process and stores the outcome
+// afterwards. Recording is best-effort: a capture failure is logged and the
+// webhook is still processed.
+func CaptureWebhook(ctx context.Context, rec WebhookRecorder, logger *logging.Logger, provider, eventID string, headers http.Header, body []byte, w http.ResponseWriter, process func(http.ResponseWriter)) {
+	if rec == nil {
+		process(w)
+		return
+	}
+	if logger == nil {
+		logger = logging.Default()
+	}
+	id, err := rec.Record(ctx, provider, eventID, headers, body)
+	if err != nil {
+		logger.Error("failed to capture webhook payload", "error", err, "provider", provider, "event_id", eventID)
+		process(w)
+		return
+	}
+	capture := NewResponseCapture(w)
+	process(capture)
+	outcome, detail := capture.Outcome()
+	if err := rec.SetOutcome(context.WithoutCancel(ctx), id, outcome, detail); err != nil {
+		logger.Error("failed to store webhook outcome", "error", err, "webhook_id", id)
+	}
+}
+
+// WebhookLogCleaner periodically deletes captured webhooks past retention.
+type WebhookLogCleaner struct {
+	log       *WebhookLog
+	retention time.Duration
+	interval  time.Duration
+	logger    *logging.Logger
+}
+
+// NewWebhookLogCleaner creates a cleaner that keeps payloads for retention.
+func NewWebhookLogCleaner(log *WebhookLog, retention time.Duration, logger *logging.Logger) *WebhookLogCleaner {
+	if logger == nil {
+		logger = logging.Default()
+	}
+	return &WebhookLogCleaner{
+		log:       log,
+		retention: retention,
+		interval:  6 * time.Hour,
+		logger:    logger,
+	}
+}
+
+// WithInterval sets how often the cleanup runs.
+func (c *WebhookLogCleaner) WithInterval(interval time.Duration) *WebhookLogCleaner {
+	c.interval = interval
+	return c
+}
+
+// Start runs the cleanup loop. Blocks until ctx is cancelled.
+func (c *WebhookLogCleaner) Start(ctx context.Context) {
+	if c.log == nil || c.retention <= 0 {
+		return
+	}
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+
+	c.cleanup(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			c.cleanup(ctx)
+		}
+	}
+}
+
+func (c *WebhookLogCleaner) cleanup(ctx context.Context) {
+	deleted, err := c.log.DeleteReceivedBefore(ctx, time.Now().Add(-c.retention))
+	if err != nil {
+		c.logger.Error("webhook log cleanup failed", "error", err)
+		return
+	}
+	if deleted > 0 {
+		c.logger.Info("webhook log cleanup", "deleted", deleted, "retention", c.retention.String())
+	}
+}
@@ -0,0 +1,117 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	pgx "github.com/jackc/pgx/v5"
+	pgxmock "github.com/pashagolub/pgxmock/v4"
+)
+
+func TestWebhookLog(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("failed to create pgx mock: %v", err)
+	}
+	defer mock.Close()
+	log := newWebhookLogWithExec(mock)
+	ctx := context.Background()
+
+	headers := http.Header{}
+	headers.Set("Content-Type", "application/json")
+	headers.Set("X-Square-Signature", "sig")
+	headers.Set("Authorization", "Bearer secret")
+	mock.ExpectExec("INSERT INTO webhook_events").
+		WithArgs(pgxmock.AnyArg(), "square", "evt-1", []byte(`{"Content-Type":"application/json","X-Square-Signature":"sig"}`), []byte(`{"id":"evt-1"}`), WebhookOutcomeReceived).
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+	id, err := log.Record(ctx, "square", "evt-1", headers, []byte(`{"id":"evt-1"}`))
+	if err != nil || id == uuid.Nil {
+		t.Fatalf("Record: id=%v err=%v", id, err)
+	}
+
+	mock.ExpectExec("UPDATE webhook_events SET outcome").
+		WithArgs(id, WebhookOutcomeFailed, "500 server error").
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	if err := log.SetOutcome(ctx, id, WebhookOutcomeFailed, "500 server error"); err != nil {
+		t.Fatalf("SetOutcome: %v", err)
+	}
+
+	received := time.Now().UTC()
+	mock.ExpectQuery("FROM webhook_events").WithArgs(id).WillReturnRows(
+		pgxmock.NewRows([]string{"id", "provider", "event_id", "received_at", "headers", "body", "outcome", "outcome_detail", "replay_count", "last_replayed_at"}).
+			AddRow(id, "square", "evt-1", received, []byte(`{"Content-Type":"application/json"}`), []byte(`{"id":"evt-1"}`), WebhookOutcomeFailed, "500 server error", 0, (*time.Time)(nil)),
+	)
+	evt, err := log.Get(ctx, id)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if evt.Provider != "square" || string(evt.Body) != `{"id":"evt-1"}` || evt.Headers["Content-Type"] != "application/json" {
+		t.Fatalf("unexpected event: %+v", evt)
+	}
+
+	missing := uuid.New()
+	mock.ExpectQuery("FROM webhook_events").WithArgs(missing).WillReturnError(pgx.ErrNoRows)
+	if _, err := log.Get(ctx, missing); !errors.Is(err, ErrWebhookEventNotFound) {
+		t.Fatalf("expected not found, got %v", err)
+	}
+
+	mock.ExpectExec("UPDATE webhook_events").
+		WithArgs(missing, WebhookOutcomeProcessed, "").
+		WillReturnResult(pgxmock.NewResult("UPDATE", 0))
+	if err := log.RecordReplay(ctx, missing, WebhookOutcomeProcessed, ""); !errors.Is(err, ErrWebhookEventNotFound) {
+		t.Fatalf("expected not found on replay, got %v", err)
+	}
+
+	mock.ExpectExec("DELETE FROM webhook_events").WithArgs(pgxmock.AnyArg()).WillReturnResult(pgxmock.NewResult("DELETE", 3))
+	if n, err := log.DeleteReceivedBefore(ctx, time.Now().Add(-30*24*time.Hour)); err != nil || n != 3 {
+		t.Fatalf("DeleteReceivedBefore: n=%d err=%v", n, err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+type stubWebhookRecorder struct {
+	outcome, detail string
+	recordErr       error
+}
+
+func (s *stubWebhookRecorder) Record(ctx context.Context, provider, eventID string, headers http.Header, body []byte) (uuid.UUID, error) {
+	return uuid.New(), s.recordErr
+}
+
+func (s *stubWebhookRecorder) SetOutcome(ctx context.Context, id uuid.UUID, outcome, detail string) error {
+	s.outcome, s.detail = outcome, detail
+	return nil
+}
+
+func TestCaptureWebhook(t *testing.T) {
+	rec := &stubWebhookRecorder{}
+	rr := httptest.NewRecorder()
+	CaptureWebhook(context.Background(), rec, nil, "telnyx", "evt", nil, []byte("{}"), rr, func(w http.ResponseWriter) {
+		http.Error(w, "processing error", http.StatusInternalServerError)
+	})
+	if rr.Code != http.StatusInternalServerError {
+		t.Fatalf("response not forwarded: %d", rr.Code)
+	}
+	if rec.outcome != WebhookOutcomeFailed || rec.detail != "500 processing error" {
+		t.Fatalf("outcome = %q %q", rec.outcome, rec.detail)
+	}
+
+	// A capture failure must not block processing.
+	rec = &stubWebhookRecorder{recordErr: errors.New("db down")}
+	ran := false
+	CaptureWebhook(context.Background(), rec, nil, "telnyx", "evt", nil, []byte("{}"), httptest.NewRecorder(), func(w http.ResponseWriter) {
+		ran = true
+		w.WriteHeader(http.StatusOK)
+	})
+	if !ran || rec.outcome != "" {
+		t.Fatalf("expected processing without outcome, ran=%v outcome=%q", ran, rec.outcome)
+	}
+}
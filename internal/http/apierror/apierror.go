@@ -0,0 +1,148 @@
+// Package apierror writes the API's standard JSON error envelope:
+//
+//	{"error": {"code": "not_found", "message": "lead not found", "details": {...}, "request_id": "..."}}
+//
+// Clients branch on code; message is for people and may change.
+package apierror
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+// Code identifies a class of API failure.
+type Code string
+
+// Error codes returned by the API.
+const (
+	CodeValidationFailed      Code = "validation_failed"
+	CodeUnauthorized          Code = "unauthorized"
+	CodeForbidden             Code = "forbidden"
+	CodeNotFound              Code = "not_found"
+	CodeMethodNotAllowed      Code = "method_not_allowed"
+	CodeConflict              Code = "conflict"
+	CodeRateLimited           Code = "rate_limited"
+	CodeDependencyUnavailable Code = "dependency_unavailable"
+	CodeTimeout               Code = "timeout"
+	CodeInternal              Code = "internal"
+)
+
+// Status returns the HTTP status a code is normally sent with.
+func (c Code) Status() int {
+	switch c {
+	case CodeValidationFailed:
+		return http.StatusBadRequest
+	case CodeUnauthorized:
+		return http.StatusUnauthorized
+	case CodeForbidden:
+		return http.StatusForbidden
+	case CodeNotFound:
+		return http.StatusNotFound
+	case CodeMethodNotAllowed:
+		return http.StatusMethodNotAllowed
+	case CodeConflict:
+		return http.StatusConflict
+	case CodeRateLimited:
+		return http.StatusTooManyRequests
+	case CodeDependencyUnavailable:
+		return http.StatusServiceUnavailable
+	case CodeTimeout:
+		return http.StatusGatewayTimeout
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// CodeForStatus picks the code for a status decided elsewhere, such as a
+// webhook sub-handler's result.
+func CodeForStatus(status int) Code {
+	switch status {
+	case http.StatusBadRequest, http.StatusUnprocessableEntity:
+		return CodeValidationFailed
+	case http.StatusUnauthorized:
+		return CodeUnauthorized
+	case http.StatusForbidden:
+		return CodeForbidden
+	case http.StatusNotFound:
+		return CodeNotFound
+	case http.StatusMethodNotAllowed:
+		return CodeMethodNotAllowed
+	case http.StatusConflict:
+		return CodeConflict
+	case http.StatusTooManyRequests:
+		return CodeRateLimited
+	case http.StatusBadGateway, http.StatusServiceUnavailable:
+		return CodeDependencyUnavailable
+	case http.StatusGatewayTimeout:
+		return CodeTimeout
+	}
+	if status >= 400 && status < 500 {
+		return CodeValidationFailed
+	}
+	return CodeInternal
+}
+
+// Body is the content of the error envelope.
+type Body struct {
+	Code      Code           `json:"code"`
+	Message   string         `json:"message"`
+	Details   map[string]any `json:"details,omitempty"`
+	RequestID string         `json:"request_id,omitempty"`
+}
+
+// Envelope wraps every API error response.
+type Envelope struct {
+	Error Body `json:"error"`
+}
+
+// Write sends an error envelope with the code's usual status.
+func Write(w http.ResponseWriter, r *http.Request, code Code, message string) {
+	WriteStatus(w, r, code.Status(), code, message, nil)
+}
+
+// WriteDetails sends an error envelope carrying details, such as the fields
+// that failed validation.
+func WriteDetails(w http.ResponseWriter, r *http.Request, code Code, message string, details map[string]any) {
+	WriteStatus(w, r, code.Status(), code, message, details)
+}
+
+// WriteStatus sends an error envelope with an explicit status, for callers
+// that must keep a status other than the code's usual one (webhook providers
+// that retry on specific statuses, or a 502 for a failed upstream call).
+func WriteStatus(w http.ResponseWriter, r *http.Request, status int, code Code, message string, details map[string]any) {
+	ctx := context.Background()
+	if r != nil {
+		ctx = r.Context()
+	}
+	writeEnvelope(ctx, w, status, Body{Code: code, Message: message, Details: details})
+}
+
+// WriteContext sends an error envelope from code that has the request's
+// context but not the request, such as webhook processing shared with
+// replays.
+func WriteContext(ctx context.Context, w http.ResponseWriter, code Code, message string) {
+	writeEnvelope(ctx, w, code.Status(), Body{Code: code, Message: message})
+}
+
+func writeEnvelope(ctx context.Context, w http.ResponseWriter, status int, body Body) {
+	body.RequestID = middleware.GetReqID(ctx)
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Del("Content-Length")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(Envelope{Error: body})
+}
+
+// WriteError maps err to an envelope: deadline errors become timeout and
+// anything else is sent as code with message, so internal error text never
+// reaches the client.
+func WriteError(w http.ResponseWriter, r *http.Request, err error, code Code, message string) {
+	if errors.Is(err, context.DeadlineExceeded) {
+		Write(w, r, CodeTimeout, "request timed out")
+		return
+	}
+	Write(w, r, code, message)
+}
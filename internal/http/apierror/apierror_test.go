@@ -0,0 +1,120 @@
+package apierror
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+func decode(t *testing.T, rec *httptest.ResponseRecorder) Body {
+	t.Helper()
+	if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+		t.Fatalf("content type = %q", ct)
+	}
+	var env Envelope
+	if err := json.NewDecoder(rec.Body).Decode(&env); err != nil {
+		t.Fatalf("decode envelope: %v", err)
+	}
+	return env.Error
+}
+
+func TestWriteDetailsIncludesRequestID(t *testing.T) {
+	handler := middleware.RequestID(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		WriteDetails(w, r, CodeValidationFailed, "invalid request", map[string]any{"field": "phone"})
+	}))
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/leads/web", nil))
+
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d", rec.Code)
+	}
+	body := decode(t, rec)
+	if body.Code != CodeValidationFailed || body.Message != "invalid request" || body.Details["field"] != "phone" {
+		t.Fatalf("unexpected body: %+v", body)
+	}
+	if body.RequestID == "" {
+		t.Fatal("expected the request id in the envelope")
+	}
+}
+
+func TestWriteErrorHidesInternalDetail(t *testing.T) {
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	WriteError(rec, req, fmt.Errorf("db: password auth failed"), CodeInternal, "failed to list leads")
+	if body := decode(t, rec); rec.Code != http.StatusInternalServerError || body.Message != "failed to list leads" {
+		t.Fatalf("status %d body %+v", rec.Code, body)
+	}
+
+	rec = httptest.NewRecorder()
+	WriteError(rec, req, fmt.Errorf("query: %w", context.DeadlineExceeded), CodeInternal, "failed to list leads")
+	if body := decode(t, rec); rec.Code != http.StatusGatewayTimeout || body.Code != CodeTimeout {
+		t.Fatalf("status %d body %+v", rec.Code, body)
+	}
+}
+
+func TestRecovererWritesEnvelopeOnPanic(t *testing.T) {
+	handler := middleware.RequestID(Recoverer(nil)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("nil map")
+	})))
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/admin/orgs", nil))
+
+	if rec.Code != http.StatusInternalServerError {
+		t.Fatalf("status = %d", rec.Code)
+	}
+	if body := decode(t, rec); body.Code != CodeInternal || body.RequestID == "" {
+		t.Fatalf("unexpected body: %+v", body)
+	}
+}
+
+func TestRecovererWritesTimeoutWhenDeadlinePasses(t *testing.T) {
+	handler := Recoverer(nil)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil).WithContext(ctx))
+
+	if rec.Code != http.StatusGatewayTimeout {
+		t.Fatalf("status = %d", rec.Code)
+	}
+	if body := decode(t, rec); body.Code != CodeTimeout {
+		t.Fatalf("unexpected body: %+v", body)
+	}
+}
+
+func TestRecovererLeavesWrittenResponses(t *testing.T) {
+	handler := Recoverer(nil)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+		panic("after write")
+	}))
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	if rec.Code != http.StatusAccepted || rec.Body.Len() != 0 {
+		t.Fatalf("status %d body %q", rec.Code, rec.Body.String())
+	}
+}
+
+func TestCodeForStatus(t *testing.T) {
+	for status, want := range map[int]Code{
+		http.StatusBadRequest:          CodeValidationFailed,
+		http.StatusNotFound:            CodeNotFound,
+		http.StatusConflict:            CodeConflict,
+		http.StatusTooManyRequests:     CodeRateLimited,
+		http.StatusBadGateway:          CodeDependencyUnavailable,
+		http.StatusServiceUnavailable:  CodeDependencyUnavailable,
+		http.StatusTeapot:              CodeValidationFailed,
+		http.StatusInternalServerError: CodeInternal,
+	} {
+		if got := CodeForStatus(status); got != want {
+			t.Errorf("CodeForStatus(%d) = %s, want %s", status, got, want)
+		}
+	}
+}
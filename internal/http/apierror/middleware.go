@@ -0,0 +1,52 @@
+package apierror
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"runtime/debug"
+
+	"github.com/go-chi/chi/v5/middleware"
+
+	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
+)
+
+// Recoverer turns a panicking handler into a 500 envelope, and a handler
+// that ran out its deadline without responding into a 504 envelope, both
+// carrying the request ID. It replaces chi's Recoverer, which answers with
+// a bare 500.
+func Recoverer(logger *logging.Logger) func(http.Handler) http.Handler {
+	if logger == nil {
+		logger = logging.Default()
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+			defer func() {
+				rec := recover()
+				if rec == nil {
+					return
+				}
+				if rec == http.ErrAbortHandler {
+					panic(rec)
+				}
+				logger.Error("panic serving request",
+					"panic", rec,
+					"method", r.Method,
+					"path", r.URL.Path,
+					"request_id", middleware.GetReqID(r.Context()),
+					"stack", string(debug.Stack()),
+				)
+				if ww.Status() == 0 {
+					Write(ww, r, CodeInternal, "internal server error")
+				}
+			}()
+
+			next.ServeHTTP(ww, r)
+
+			if ww.Status() == 0 && errors.Is(r.Context().Err(), context.DeadlineExceeded) {
+				Write(ww, r, CodeTimeout, "request timed out")
+			}
+		})
+	}
+}
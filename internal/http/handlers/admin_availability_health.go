@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/wolfman30/medspa-ai-platform/internal/availhealth"
+	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
+)
+
+const (
+	defaultAvailabilityHealthDays = 7
+	maxAvailabilityHealthDays     = 30
+)
+
+// AvailabilityProbeHistory loads recorded availability probes.
+type AvailabilityProbeHistory interface {
+	History(ctx context.Context, orgID string, since time.Time) ([]availhealth.ProbeResult, error)
+}
+
+// AdminAvailabilityHealthHandler serves a clinic's availability probe history.
+type AdminAvailabilityHealthHandler struct {
+	store  AvailabilityProbeHistory
+	logger *logging.Logger
+	now    func() time.Time
+}
+
+// NewAdminAvailabilityHealthHandler creates a new availability health handler.
+func NewAdminAvailabilityHealthHandler(store AvailabilityProbeHistory, logger *logging.Logger) *AdminAvailabilityHealthHandler {
+	if logger == nil {
+		logger = logging.Default()
+	}
+	return &AdminAvailabilityHealthHandler{store: store, logger: logger, now: time.Now}
+}
+
+// availabilityHealthResponse is the body of GET availability-health.
+type availabilityHealthResponse struct {
+	OrgID    string                      `json:"org_id"`
+	Since    time.Time                   `json:"since"`
+	Services []availhealth.ServiceHealth `json:"services"`
+	Probes   []availhealth.ProbeResult   `json:"probes"`
+}
+
+// Get handles GET /admin/clinics/{orgID}/availability-health
+// Returns each probed service's latest result and 7-day baseline, plus the
+// probe history (newest first). Pass ?days=N (max 30) to widen the history.
+func (h *AdminAvailabilityHealthHandler) Get(w http.ResponseWriter, r *http.Request) {
+	if h == nil || h.store == nil {
+		http.Error(w, "database not configured", http.StatusServiceUnavailable)
+		return
+	}
+	orgID := strings.TrimSpace(chi.URLParam(r, "orgID"))
+	if orgID == "" {
+		http.Error(w, "missing orgID", http.StatusBadRequest)
+		return
+	}
+	days := defaultAvailabilityHealthDays
+	if raw := r.URL.Query().Get("days"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed < 1 || parsed > maxAvailabilityHealthDays {
+			http.Error(w, "days must be between 1 and 30", http.StatusBadRequest)
+			return
+		}
+		days = parsed
+	}
+
+	now := h.now()
+	since := now.AddDate(0, 0, -days)
+	// The summary always needs the full baseline window.
+	historySince := since
+	if baselineSince := now.Add(-availhealth.DefaultThresholds().BaselineWindow); baselineSince.Before(historySince) {
+		historySince = baselineSince
+	}
+	history, err := h.store.History(r.Context(), orgID, historySince)
+	if err != nil {
+		h.logger.Error("availability health: load history failed", "error", err, "org_id", orgID)
+		http.Error(w, "failed to load availability health", http.StatusInternalServerError)
+		return
+	}
+
+	probes := make([]availhealth.ProbeResult, 0, len(history))
+	for _, p := range history {
+		if !p.ProbedAt.Before(since) {
+			probes = append(probes, p)
+		}
+	}
+	writeJSON(w, http.StatusOK, availabilityHealthResponse{
+		OrgID:    orgID,
+		Since:    since,
+		Services: availhealth.Summarize(history, now, availhealth.DefaultThresholds()),
+		Probes:   probes,
+	})
+}
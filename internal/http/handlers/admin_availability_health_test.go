@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/wolfman30/medspa-ai-platform/internal/availhealth"
+	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
+)
+
+type memProbeHistory struct {
+	probes []availhealth.ProbeResult
+	since  time.Time
+}
+
+func (m *memProbeHistory) History(ctx context.Context, orgID string, since time.Time) ([]availhealth.ProbeResult, error) {
+	m.since = since
+	var out []availhealth.ProbeResult
+	for _, p := range m.probes {
+		if p.OrgID == orgID && !p.ProbedAt.Before(since) {
+			out = append(out, p)
+		}
+	}
+	return out, nil
+}
+
+func newAvailabilityHealthRequest(orgID, query string) *http.Request {
+	req := httptest.NewRequest(http.MethodGet, "/admin/clinics/"+orgID+"/availability-health"+query, nil)
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("orgID", orgID)
+	return req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+}
+
+func TestAdminAvailabilityHealth_Get(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	store := &memProbeHistory{probes: []availhealth.ProbeResult{
+		{OrgID: "org-1", Service: "botox", Platform: "moxie", Success: true, SlotCount: 0, Alert: availhealth.AlertZeroSlots, ProbedAt: now.Add(-time.Hour)},
+		{OrgID: "org-1", Service: "botox", Platform: "moxie", Success: true, SlotCount: 12, ProbedAt: now.Add(-3 * 24 * time.Hour)},
+		{OrgID: "org-1", Service: "filler", Platform: "moxie", Success: false, Error: "moxie API returned 500", ProbedAt: now.Add(-2 * time.Hour)},
+		{OrgID: "org-2", Service: "botox", Platform: "moxie", Success: true, SlotCount: 3, ProbedAt: now.Add(-time.Hour)},
+	}}
+	h := NewAdminAvailabilityHealthHandler(store, logging.Default())
+	h.now = func() time.Time { return now }
+
+	rec := httptest.NewRecorder()
+	h.Get(rec, newAvailabilityHealthRequest("org-1", "?days=2"))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if !store.since.Equal(now.Add(-7 * 24 * time.Hour)) {
+		t.Fatalf("expected the full baseline window to be loaded, got since=%v", store.since)
+	}
+
+	var body struct {
+		OrgID    string `json:"org_id"`
+		Services []struct {
+			Service  string `json:"service"`
+			Status   string `json:"status"`
+			Baseline struct {
+				Samples  int     `json:"samples"`
+				AvgSlots float64 `json:"avg_slots"`
+			} `json:"baseline"`
+		} `json:"services"`
+		Probes []struct {
+			Service string `json:"service"`
+			Alert   string `json:"alert"`
+		} `json:"probes"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if body.OrgID != "org-1" || len(body.Services) != 2 {
+		t.Fatalf("unexpected response: %s", rec.Body.String())
+	}
+	botox, filler := body.Services[0], body.Services[1]
+	if botox.Service != "botox" || botox.Status != availhealth.StatusZeroSlots || botox.Baseline.Samples != 2 || botox.Baseline.AvgSlots != 6 {
+		t.Fatalf("unexpected botox health: %+v", botox)
+	}
+	if filler.Status != availhealth.StatusFailing {
+		t.Fatalf("unexpected filler health: %+v", filler)
+	}
+	// ?days=2 trims the history but not the baseline.
+	if len(body.Probes) != 2 || body.Probes[0].Alert != availhealth.AlertZeroSlots {
+		t.Fatalf("expected the last 2 days of probes, got %+v", body.Probes)
+	}
+}
+
+func TestAdminAvailabilityHealth_InvalidDays(t *testing.T) {
+	h := NewAdminAvailabilityHealthHandler(&memProbeHistory{}, logging.Default())
+	for _, q := range []string{"?days=0", "?days=31", "?days=week"} {
+		rec := httptest.NewRecorder()
+		h.Get(rec, newAvailabilityHealthRequest("org-1", q))
+		if rec.Code != http.StatusBadRequest {
+			t.Fatalf("%s: expected 400, got %d", q, rec.Code)
+		}
+	}
+}
@@ -0,0 +1,217 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/wolfman30/medspa-ai-platform/internal/broadcasts"
+	"github.com/wolfman30/medspa-ai-platform/internal/messaging"
+	"github.com/wolfman30/medspa-ai-platform/internal/messaging/compliance"
+	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
+)
+
+// maxBroadcastTemplateChars keeps a broadcast within a few SMS segments.
+const maxBroadcastTemplateChars = 640
+
+// BroadcastStore queries segments and persists broadcasts.
+type BroadcastStore interface {
+	broadcasts.CandidateSource
+	Create(ctx context.Context, b broadcasts.Broadcast, recipients []broadcasts.NewRecipient) (broadcasts.Broadcast, error)
+	Get(ctx context.Context, orgID string, id uuid.UUID) (broadcasts.Broadcast, error)
+	Recipients(ctx context.Context, orgID string, id uuid.UUID) ([]broadcasts.Recipient, error)
+}
+
+// AdminBroadcastsHandler creates segment broadcasts and reports their
+// delivery. Sending happens in the messaging worker.
+type AdminBroadcastsHandler struct {
+	store   BroadcastStore
+	optOut  broadcasts.OptOutChecker
+	consent compliance.MarketingConsentChecker
+	clinics ClinicConfigGetter
+	logger  *logging.Logger
+	now     func() time.Time
+}
+
+// NewAdminBroadcastsHandler creates a new broadcasts handler. Without a
+// consent checker every lead is excluded, since broadcasts are marketing.
+func NewAdminBroadcastsHandler(store BroadcastStore, optOut broadcasts.OptOutChecker, consent compliance.MarketingConsentChecker, logger *logging.Logger) *AdminBroadcastsHandler {
+	if logger == nil {
+		logger = logging.Default()
+	}
+	return &AdminBroadcastsHandler{store: store, optOut: optOut, consent: consent, logger: logger, now: time.Now}
+}
+
+// SetClinicStore lets broadcasts default to the clinic's SMS number.
+func (h *AdminBroadcastsHandler) SetClinicStore(clinics ClinicConfigGetter) {
+	h.clinics = clinics
+}
+
+// broadcastSegmentRequest is the segment filter of a broadcast request.
+// ActiveWithinDays is shorthand for active_after = now - N days.
+type broadcastSegmentRequest struct {
+	broadcasts.Segment
+	ActiveWithinDays int `json:"active_within_days,omitempty"`
+}
+
+type createBroadcastRequest struct {
+	Segment    broadcastSegmentRequest `json:"segment"`
+	Template   string                  `json:"template"`
+	FromNumber string                  `json:"from_number,omitempty"`
+	DryRun     bool                    `json:"dry_run"`
+	CreatedBy  string                  `json:"created_by,omitempty"`
+}
+
+type broadcastResponse struct {
+	Broadcast     *broadcasts.Broadcast  `json:"broadcast,omitempty"`
+	DryRun        bool                   `json:"dry_run"`
+	AudienceCount int                    `json:"audience_count"`
+	Excluded      map[string]int         `json:"excluded"`
+	Preview       string                 `json:"preview,omitempty"`
+	Recipients    []broadcasts.Recipient `json:"recipients,omitempty"`
+}
+
+// Create handles POST /admin/orgs/{orgID}/broadcasts
+// Resolves the segment's audience, excluding opted-out leads and those
+// without marketing consent. With dry_run it returns the audience count and
+// a rendered preview; otherwise it queues the broadcast for the messaging
+// worker and returns 202.
+func (h *AdminBroadcastsHandler) Create(w http.ResponseWriter, r *http.Request) {
+	if h == nil || h.store == nil {
+		http.Error(w, "database not configured", http.StatusServiceUnavailable)
+		return
+	}
+	orgID := strings.TrimSpace(chi.URLParam(r, "orgID"))
+	if _, err := uuid.Parse(orgID); err != nil {
+		http.Error(w, "invalid orgID", http.StatusBadRequest)
+		return
+	}
+	var req createBroadcastRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid json", http.StatusBadRequest)
+		return
+	}
+	req.Template = strings.TrimSpace(req.Template)
+	if req.Template == "" || len(req.Template) > maxBroadcastTemplateChars {
+		http.Error(w, "template required (max 640 characters)", http.StatusBadRequest)
+		return
+	}
+	if req.Segment.ActiveWithinDays < 0 {
+		http.Error(w, "active_within_days must be positive", http.StatusBadRequest)
+		return
+	}
+	seg := req.Segment.Segment
+	if req.Segment.ActiveWithinDays > 0 && seg.ActiveAfter == nil {
+		after := h.now().UTC().AddDate(0, 0, -req.Segment.ActiveWithinDays)
+		seg.ActiveAfter = &after
+	}
+	if err := seg.Validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	preview, err := broadcasts.RenderMessage(req.Template, broadcasts.Candidate{Name: "Jane"})
+	if err != nil {
+		http.Error(w, "invalid template: use {{.first_name}} for the lead's first name", http.StatusBadRequest)
+		return
+	}
+
+	audience, err := broadcasts.ResolveAudience(r.Context(), h.store, h.optOut, h.consent, orgID, seg)
+	if err != nil {
+		h.logger.Error("broadcasts: resolve audience failed", "error", err, "org_id", orgID)
+		http.Error(w, "failed to resolve audience", http.StatusInternalServerError)
+		return
+	}
+	resp := broadcastResponse{
+		DryRun:        req.DryRun,
+		AudienceCount: len(audience.Recipients),
+		Excluded:      audience.Excluded,
+		Preview:       preview,
+	}
+	if req.DryRun {
+		writeJSON(w, http.StatusOK, resp)
+		return
+	}
+	if len(audience.Recipients) == 0 {
+		http.Error(w, "segment has no eligible recipients", http.StatusBadRequest)
+		return
+	}
+
+	from := messaging.NormalizeE164(req.FromNumber)
+	if from == "" && h.clinics != nil {
+		if cfg, err := h.clinics.Get(r.Context(), orgID); err == nil && cfg != nil {
+			from = messaging.NormalizeE164(cfg.SMSPhoneNumber)
+		}
+	}
+	if from == "" {
+		http.Error(w, "from_number required: clinic has no SMS number", http.StatusBadRequest)
+		return
+	}
+	recipients, err := audience.Render(req.Template)
+	if err != nil {
+		http.Error(w, "invalid template", http.StatusBadRequest)
+		return
+	}
+	created, err := h.store.Create(r.Context(), broadcasts.Broadcast{
+		OrgID:      orgID,
+		Segment:    seg,
+		Template:   req.Template,
+		FromNumber: from,
+		CreatedBy:  strings.TrimSpace(req.CreatedBy),
+	}, recipients)
+	if err != nil {
+		h.logger.Error("broadcasts: create failed", "error", err, "org_id", orgID)
+		http.Error(w, "failed to create broadcast", http.StatusInternalServerError)
+		return
+	}
+	h.logger.Info("broadcast queued", "org_id", orgID, "broadcast_id", created.ID, "audience", created.AudienceCount)
+	resp.Broadcast = &created
+	writeJSON(w, http.StatusAccepted, resp)
+}
+
+// Get handles GET /admin/orgs/{orgID}/broadcasts/{broadcastID}
+// Returns the broadcast with each recipient's send and delivery status.
+func (h *AdminBroadcastsHandler) Get(w http.ResponseWriter, r *http.Request) {
+	if h == nil || h.store == nil {
+		http.Error(w, "database not configured", http.StatusServiceUnavailable)
+		return
+	}
+	orgID := strings.TrimSpace(chi.URLParam(r, "orgID"))
+	id, err := uuid.Parse(chi.URLParam(r, "broadcastID"))
+	if orgID == "" || err != nil {
+		http.Error(w, "orgID and valid broadcastID required", http.StatusBadRequest)
+		return
+	}
+	b, err := h.store.Get(r.Context(), orgID, id)
+	if errors.Is(err, broadcasts.ErrNotFound) {
+		http.Error(w, "broadcast not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		h.logger.Error("broadcasts: get failed", "error", err, "org_id", orgID, "broadcast_id", id)
+		http.Error(w, "failed to load broadcast", http.StatusInternalServerError)
+		return
+	}
+	recipients, err := h.store.Recipients(r.Context(), orgID, id)
+	if err != nil {
+		h.logger.Error("broadcasts: list recipients failed", "error", err, "org_id", orgID, "broadcast_id", id)
+		http.Error(w, "failed to load broadcast", http.StatusInternalServerError)
+		return
+	}
+	excluded := map[string]int{}
+	for _, rec := range recipients {
+		if rec.Status == broadcasts.RecipientSuppressed {
+			excluded[rec.SuppressedReason]++
+		}
+	}
+	writeJSON(w, http.StatusOK, broadcastResponse{
+		Broadcast:     &b,
+		AudienceCount: b.AudienceCount,
+		Excluded:      excluded,
+		Recipients:    recipients,
+	})
+}
@@ -0,0 +1,168 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/wolfman30/medspa-ai-platform/internal/broadcasts"
+	"github.com/wolfman30/medspa-ai-platform/internal/tenancy"
+	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
+)
+
+type memBroadcastStore struct {
+	candidates []broadcasts.Candidate
+	lastSeg    broadcasts.Segment
+	created    map[uuid.UUID]broadcasts.Broadcast
+	recipients map[uuid.UUID][]broadcasts.NewRecipient
+}
+
+func (m *memBroadcastStore) Candidates(ctx context.Context, orgID string, seg broadcasts.Segment) ([]broadcasts.Candidate, error) {
+	m.lastSeg = seg
+	return m.candidates, nil
+}
+
+func (m *memBroadcastStore) Create(ctx context.Context, b broadcasts.Broadcast, recipients []broadcasts.NewRecipient) (broadcasts.Broadcast, error) {
+	b.ID = uuid.New()
+	b.Status = broadcasts.StatusSending
+	b.AudienceCount = len(recipients)
+	m.created[b.ID] = b
+	m.recipients[b.ID] = recipients
+	return b, nil
+}
+
+func (m *memBroadcastStore) Get(ctx context.Context, orgID string, id uuid.UUID) (broadcasts.Broadcast, error) {
+	b, ok := m.created[id]
+	if !ok || b.OrgID != orgID {
+		return broadcasts.Broadcast{}, broadcasts.ErrNotFound
+	}
+	return b, nil
+}
+
+func (m *memBroadcastStore) Recipients(ctx context.Context, orgID string, id uuid.UUID) ([]broadcasts.Recipient, error) {
+	var out []broadcasts.Recipient
+	for _, r := range m.recipients[id] {
+		out = append(out, broadcasts.Recipient{BroadcastID: id, OrgID: orgID, Phone: r.Phone, Status: broadcasts.RecipientSent, DeliveryStatus: "delivered"})
+	}
+	return out, nil
+}
+
+type memOptOut map[string]bool
+
+func (m memOptOut) IsUnsubscribed(ctx context.Context, clinicID uuid.UUID, recipient string) (bool, error) {
+	return m[recipient], nil
+}
+
+type memConsent map[string]bool
+
+func (m memConsent) HasMarketingConsent(ctx context.Context, scope tenancy.OrgScope, phone string) (bool, error) {
+	return m[phone], nil
+}
+
+func newBroadcastRequest(method, orgID, broadcastID, body string) *http.Request {
+	req := httptest.NewRequest(method, "/admin/orgs/"+orgID+"/broadcasts", strings.NewReader(body))
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("orgID", orgID)
+	if broadcastID != "" {
+		rctx.URLParams.Add("broadcastID", broadcastID)
+	}
+	return req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+}
+
+func TestAdminBroadcasts_DryRunCountMatchesExecution(t *testing.T) {
+	orgID := uuid.NewString()
+	store := &memBroadcastStore{
+		candidates: []broadcasts.Candidate{
+			{LeadID: "1", Phone: "+15550000001", Name: "Ana Diaz"},
+			{LeadID: "2", Phone: "+15550000002", Name: "Ben"},
+			{LeadID: "3", Phone: "+15550000003", Name: "Cy"},
+		},
+		created:    map[uuid.UUID]broadcasts.Broadcast{},
+		recipients: map[uuid.UUID][]broadcasts.NewRecipient{},
+	}
+	consent := memConsent{"+15550000001": true, "+15550000002": true}
+	h := NewAdminBroadcastsHandler(store, memOptOut{"+15550000002": true}, consent, logging.Default())
+	h.SetClinicStore(memClinicConfigs{orgID: {SMSPhoneNumber: "+15559990000"}})
+	h.now = func() time.Time { return time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC) }
+
+	body := `{"segment":{"service_interest":"botox","active_within_days":60,"booking_status":"not_booked"},"template":"Hi {{.first_name}}, 3 openings Friday!","dry_run":true}`
+	rec := httptest.NewRecorder()
+	h.Create(rec, newBroadcastRequest(http.MethodPost, orgID, "", body))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("dry run status = %d: %s", rec.Code, rec.Body.String())
+	}
+	var dry broadcastResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &dry); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if dry.AudienceCount != 1 || dry.Excluded[broadcasts.ReasonOptOut] != 1 || dry.Excluded[broadcasts.ReasonNoMarketingConsent] != 1 {
+		t.Fatalf("unexpected dry run: %+v", dry)
+	}
+	if len(store.created) != 0 {
+		t.Fatal("dry run must not create a broadcast")
+	}
+	if store.lastSeg.ActiveAfter == nil || !store.lastSeg.ActiveAfter.Equal(time.Date(2026, 8, 18, 12, 0, 0, 0, time.UTC)) {
+		t.Fatalf("active_within_days not applied: %v", store.lastSeg.ActiveAfter)
+	}
+
+	rec = httptest.NewRecorder()
+	h.Create(rec, newBroadcastRequest(http.MethodPost, orgID, "", strings.Replace(body, `"dry_run":true`, `"dry_run":false`, 1)))
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("execute status = %d: %s", rec.Code, rec.Body.String())
+	}
+	var executed broadcastResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &executed); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if executed.Broadcast == nil || executed.Broadcast.AudienceCount != dry.AudienceCount || executed.Broadcast.FromNumber != "+15559990000" {
+		t.Fatalf("execution doesn't match dry run: %+v", executed.Broadcast)
+	}
+	queued := store.recipients[executed.Broadcast.ID]
+	if len(queued) != 1 || !strings.HasPrefix(queued[0].Body, "Hi Ana, 3 openings Friday!") || !strings.Contains(queued[0].Body, "STOP") {
+		t.Fatalf("unexpected queued recipients: %+v", queued)
+	}
+
+	rec = httptest.NewRecorder()
+	h.Get(rec, newBroadcastRequest(http.MethodGet, orgID, executed.Broadcast.ID.String(), ""))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("get status = %d: %s", rec.Code, rec.Body.String())
+	}
+	var got broadcastResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(got.Recipients) != 1 || got.Recipients[0].DeliveryStatus != "delivered" {
+		t.Fatalf("unexpected recipients: %+v", got.Recipients)
+	}
+
+	rec = httptest.NewRecorder()
+	h.Get(rec, newBroadcastRequest(http.MethodGet, uuid.NewString(), executed.Broadcast.ID.String(), ""))
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("other org get status = %d, want 404", rec.Code)
+	}
+}
+
+func TestAdminBroadcasts_CreateValidation(t *testing.T) {
+	orgID := uuid.NewString()
+	store := &memBroadcastStore{created: map[uuid.UUID]broadcasts.Broadcast{}, recipients: map[uuid.UUID][]broadcasts.NewRecipient{}}
+	h := NewAdminBroadcastsHandler(store, nil, memConsent{}, logging.Default())
+
+	for name, body := range map[string]string{
+		"missing template":   `{"segment":{}}`,
+		"bad booking status": `{"segment":{"booking_status":"maybe"},"template":"Hi"}`,
+		"bad template field": `{"segment":{},"template":"Hi {{.name}}"}`,
+		"empty audience":     `{"segment":{},"template":"Openings Friday"}`,
+	} {
+		rec := httptest.NewRecorder()
+		h.Create(rec, newBroadcastRequest(http.MethodPost, orgID, "", body))
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, want 400", name, rec.Code)
+		}
+	}
+}
@@ -25,6 +25,7 @@ type AdminClinicDataConfig struct {
 	Logger           *logging.Logger
 	Archiver         *clinicdata.Archiver      // Optional: if set, archives data to S3 before purging
 	TrainingArchiver *archive.TrainingArchiver // Optional: if set, archives classified data for LLM training
+	ColdStore        *clinicdata.ColdStore     // Optional: if set, purges also delete cold-storage archives
 }
 
 // AdminClinicDataHandler provides privileged endpoints for dev/demo data maintenance.
@@ -34,6 +35,7 @@ type AdminClinicDataHandler struct {
 	logger           *logging.Logger
 	archiver         *clinicdata.Archiver
 	trainingArchiver *archive.TrainingArchiver
+	coldStore        *clinicdata.ColdStore
 }
 
 func NewAdminClinicDataHandler(cfg AdminClinicDataConfig) *AdminClinicDataHandler {
@@ -46,6 +48,7 @@ func NewAdminClinicDataHandler(cfg AdminClinicDataConfig) *AdminClinicDataHandle
 		logger:           cfg.Logger,
 		archiver:         cfg.Archiver,
 		trainingArchiver: cfg.TrainingArchiver,
+		coldStore:        cfg.ColdStore,
 	}
 }
 
@@ -63,6 +66,7 @@ type PurgePhoneResponse struct {
 		Leads            int64 `json:"leads"`
 		Messages         int64 `json:"messages"`
 		Unsubscribes     int64 `json:"unsubscribes"`
+		ColdStorage      int64 `json:"cold_storage_conversations"`
 	} `json:"deleted"`
 	RedisDeleted int64 `json:"redis_deleted"`
 	Archived     *struct {
@@ -93,6 +97,7 @@ func (h *AdminClinicDataHandler) PurgeOrg(w http.ResponseWriter, r *http.Request
 		Logger:           h.logger,
 		Archiver:         h.archiver,
 		TrainingArchiver: h.trainingArchiver,
+		ColdStore:        h.coldStore,
 	})
 	result, err := purger.PurgeOrg(r.Context(), orgID)
 	if err != nil {
@@ -122,6 +127,7 @@ func (h *AdminClinicDataHandler) PurgeOrg(w http.ResponseWriter, r *http.Request
 	resp.Deleted.Leads = result.Deleted.Leads
 	resp.Deleted.Messages = result.Deleted.Messages
 	resp.Deleted.Unsubscribes = result.Deleted.Unsubscribes
+	resp.Deleted.ColdStorage = result.Deleted.ArchivedConversations
 	if result.Archived != nil && result.Archived.MessagesArchived > 0 {
 		resp.Archived = &struct {
 			ConversationsArchived int    `json:"conversations_archived"`
@@ -167,6 +173,7 @@ func (h *AdminClinicDataHandler) PurgePhone(w http.ResponseWriter, r *http.Reque
 		Logger:           h.logger,
 		Archiver:         h.archiver,
 		TrainingArchiver: h.trainingArchiver,
+		ColdStore:        h.coldStore,
 	})
 	result, err := purger.PurgePhone(r.Context(), orgID, phone)
 	if err != nil {
@@ -198,6 +205,7 @@ func (h *AdminClinicDataHandler) PurgePhone(w http.ResponseWriter, r *http.Reque
 	resp.Deleted.Leads = result.Deleted.Leads
 	resp.Deleted.Messages = result.Deleted.Messages
 	resp.Deleted.Unsubscribes = result.Deleted.Unsubscribes
+	resp.Deleted.ColdStorage = result.Deleted.ArchivedConversations
 	if result.Archived != nil && result.Archived.MessagesArchived > 0 {
 		resp.Archived = &struct {
 			ConversationsArchived int    `json:"conversations_archived"`
@@ -0,0 +1,197 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/wolfman30/medspa-ai-platform/internal/convstream"
+	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
+)
+
+// ConversationEventSource delivers live and recorded conversation events.
+type ConversationEventSource interface {
+	Subscribe(conversationID string) (<-chan convstream.Event, func())
+	Replay(ctx context.Context, conversationID, afterID string) ([]convstream.Event, error)
+}
+
+// AdminConversationStreamHandler streams a conversation's updates to the
+// operator portal as server-sent events.
+type AdminConversationStreamHandler struct {
+	events    ConversationEventSource
+	logger    *logging.Logger
+	maxPerOrg int
+	heartbeat time.Duration
+
+	mu   sync.Mutex
+	open map[string]int
+}
+
+// NewAdminConversationStreamHandler creates a stream handler allowing at most
+// maxPerOrg open streams per clinic on this instance (0 means no cap) and
+// sending a keep-alive comment every heartbeat.
+func NewAdminConversationStreamHandler(events ConversationEventSource, maxPerOrg int, heartbeat time.Duration, logger *logging.Logger) *AdminConversationStreamHandler {
+	if logger == nil {
+		logger = logging.Default()
+	}
+	if heartbeat <= 0 {
+		heartbeat = 15 * time.Second
+	}
+	return &AdminConversationStreamHandler{
+		events:    events,
+		logger:    logger,
+		maxPerOrg: maxPerOrg,
+		heartbeat: heartbeat,
+		open:      make(map[string]int),
+	}
+}
+
+// Stream handles GET /admin/orgs/{orgID}/conversations/{conversationID}/stream
+// Sends "message", "status" and "delivery" events as they are persisted. A
+// client reconnecting with Last-Event-ID (header or last_event_id query
+// param) first gets the events it missed, within the recent history kept
+// per conversation.
+func (h *AdminConversationStreamHandler) Stream(w http.ResponseWriter, r *http.Request) {
+	if h == nil || h.events == nil {
+		http.Error(w, "conversation stream not configured", http.StatusServiceUnavailable)
+		return
+	}
+	orgID := strings.TrimSpace(chi.URLParam(r, "orgID"))
+	conversationID := chi.URLParam(r, "conversationID")
+	if decoded, err := url.PathUnescape(conversationID); err == nil {
+		conversationID = decoded
+	}
+	if orgID == "" || !conversationBelongsToOrg(conversationID, orgID) {
+		http.Error(w, "conversation not found for org", http.StatusNotFound)
+		return
+	}
+	lastID := strings.TrimSpace(r.Header.Get("Last-Event-ID"))
+	if lastID == "" {
+		lastID = strings.TrimSpace(r.URL.Query().Get("last_event_id"))
+	}
+	if lastID != "" && !convstream.ValidID(lastID) {
+		http.Error(w, "invalid Last-Event-ID", http.StatusBadRequest)
+		return
+	}
+
+	if !h.acquire(orgID) {
+		w.Header().Set("Retry-After", "30")
+		http.Error(w, "too many open conversation streams for org", http.StatusTooManyRequests)
+		return
+	}
+	defer h.release(orgID)
+
+	// Subscribe before replaying so nothing published in between is lost;
+	// live events already covered by the replay are skipped below.
+	live, cancel := h.events.Subscribe(conversationID)
+	defer cancel()
+
+	ctx := r.Context()
+	var missed []convstream.Event
+	if lastID != "" {
+		var err error
+		missed, err = h.events.Replay(ctx, conversationID, lastID)
+		if err != nil {
+			h.logger.Error("conversation stream: replay failed", "error", err, "conversation_id", conversationID)
+			http.Error(w, "failed to load missed events", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	rc := http.NewResponseController(w)
+	// The server's write timeout is meant for ordinary requests.
+	_ = rc.SetWriteDeadline(time.Time{})
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	if err := rc.Flush(); err != nil {
+		h.logger.Warn("conversation stream: response does not support flushing", "error", err)
+		return
+	}
+
+	for _, evt := range missed {
+		if err := writeSSEEvent(w, evt); err != nil {
+			return
+		}
+		lastID = evt.ID
+	}
+	if err := rc.Flush(); err != nil {
+		return
+	}
+
+	ticker := time.NewTicker(h.heartbeat)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := io.WriteString(w, ": heartbeat\n\n"); err != nil {
+				return
+			}
+		case evt, ok := <-live:
+			if !ok {
+				// Fell behind or the hub stopped; the client resumes from lastID.
+				return
+			}
+			if lastID != "" && !convstream.After(evt.ID, lastID) {
+				continue
+			}
+			if err := writeSSEEvent(w, evt); err != nil {
+				return
+			}
+			lastID = evt.ID
+		}
+		if err := rc.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+func (h *AdminConversationStreamHandler) acquire(orgID string) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.maxPerOrg > 0 && h.open[orgID] >= h.maxPerOrg {
+		return false
+	}
+	h.open[orgID]++
+	return true
+}
+
+func (h *AdminConversationStreamHandler) release(orgID string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.open[orgID] <= 1 {
+		delete(h.open, orgID)
+		return
+	}
+	h.open[orgID]--
+}
+
+// conversationBelongsToOrg checks the org segment of an "sms:<org>:<phone>"
+// or "voice:<org>:<phone>" conversation ID.
+func conversationBelongsToOrg(conversationID, orgID string) bool {
+	parts := strings.Split(conversationID, ":")
+	if len(parts) != 3 || parts[2] == "" {
+		return false
+	}
+	return (parts[0] == "sms" || parts[0] == "voice") && parts[1] == orgID
+}
+
+func writeSSEEvent(w io.Writer, evt convstream.Event) error {
+	data, err := json.Marshal(evt)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %s\nevent: %s\ndata: %s\n\n", evt.ID, evt.Type, data)
+	return err
+}
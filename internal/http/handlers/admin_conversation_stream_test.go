@@ -0,0 +1,228 @@
+package handlers
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-chi/chi/v5"
+	"github.com/redis/go-redis/v9"
+	"github.com/wolfman30/medspa-ai-platform/internal/conversation"
+	"github.com/wolfman30/medspa-ai-platform/internal/convstream"
+)
+
+const streamConvID = "sms:org-1:15550001111"
+
+type streamFixture struct {
+	server     *httptest.Server
+	publisher  *convstream.Publisher
+	transcript *conversation.SMSTranscriptStore
+}
+
+func newStreamFixture(t *testing.T, maxPerOrg int) *streamFixture {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	mr := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	hub := convstream.NewHub(rdb, nil)
+	if err := hub.Start(ctx); err != nil {
+		t.Fatalf("start hub: %v", err)
+	}
+	handler := NewAdminConversationStreamHandler(hub, maxPerOrg, time.Minute, nil)
+	r := chi.NewRouter()
+	r.Get("/admin/orgs/{orgID}/conversations/{conversationID}/stream", handler.Stream)
+	server := httptest.NewServer(r)
+	t.Cleanup(server.Close)
+	return &streamFixture{
+		server:     server,
+		publisher:  convstream.NewPublisher(rdb),
+		transcript: conversation.NewSMSTranscriptStore(rdb),
+	}
+}
+
+type sseStream struct {
+	resp   *http.Response
+	events chan convstream.Event
+	cancel context.CancelFunc
+}
+
+// open connects to a stream; the returned response is already past headers.
+func (f *streamFixture) open(t *testing.T, orgID, conversationID, lastEventID string) *sseStream {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet,
+		f.server.URL+"/admin/orgs/"+orgID+"/conversations/"+conversationID+"/stream", nil)
+	if lastEventID != "" {
+		req.Header.Set("Last-Event-ID", lastEventID)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		cancel()
+		t.Fatalf("open stream: %v", err)
+	}
+	s := &sseStream{resp: resp, events: make(chan convstream.Event, 32), cancel: cancel}
+	t.Cleanup(s.close)
+	if resp.StatusCode == http.StatusOK {
+		go s.read()
+	}
+	return s
+}
+
+func (s *sseStream) close() {
+	s.cancel()
+	s.resp.Body.Close()
+}
+
+func (s *sseStream) read() {
+	defer close(s.events)
+	scanner := bufio.NewScanner(s.resp.Body)
+	var id, data string
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "id: "):
+			id = strings.TrimPrefix(line, "id: ")
+		case strings.HasPrefix(line, "data: "):
+			data = strings.TrimPrefix(line, "data: ")
+		case line == "" && data != "":
+			var evt convstream.Event
+			if json.Unmarshal([]byte(data), &evt) == nil && evt.ID == id {
+				s.events <- evt
+			}
+			id, data = "", ""
+		}
+	}
+}
+
+func (s *sseStream) next(t *testing.T) convstream.Event {
+	t.Helper()
+	select {
+	case evt, ok := <-s.events:
+		if !ok {
+			t.Fatal("stream ended")
+		}
+		return evt
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for stream event")
+	}
+	return convstream.Event{}
+}
+
+// simulateTurn records what a patient text and its reply persist: the inbound
+// message, the reply, its delivery receipt and a status change.
+func (f *streamFixture) simulateTurn(t *testing.T) {
+	t.Helper()
+	ctx := context.Background()
+	if err := f.transcript.Append(ctx, streamConvID, conversation.SMSTranscriptMessage{Role: "user", Body: "Do you have Botox Friday?"}); err != nil {
+		t.Fatalf("append inbound: %v", err)
+	}
+	if err := f.transcript.Append(ctx, streamConvID, conversation.SMSTranscriptMessage{Role: "assistant", Body: "We have 2pm or 4pm.", ProviderMessageID: "msg-1", Status: "queued"}); err != nil {
+		t.Fatalf("append reply: %v", err)
+	}
+	if _, err := f.publisher.Publish(ctx, streamConvID, convstream.TypeDelivery, convstream.DeliveryUpdate{ProviderMessageID: "msg-1", Status: "delivered"}); err != nil {
+		t.Fatalf("publish delivery: %v", err)
+	}
+	if _, err := f.publisher.Publish(ctx, streamConvID, convstream.TypeStatus, convstream.StatusUpdate{Status: conversation.StatusAwaitingTimeSelection}); err != nil {
+		t.Fatalf("publish status: %v", err)
+	}
+}
+
+func TestConversationStream_TurnArrivesInOrder(t *testing.T) {
+	f := newStreamFixture(t, 5)
+	stream := f.open(t, "org-1", streamConvID, "")
+	if stream.resp.StatusCode != http.StatusOK || stream.resp.Header.Get("Content-Type") != "text/event-stream" {
+		t.Fatalf("open = %d %q", stream.resp.StatusCode, stream.resp.Header.Get("Content-Type"))
+	}
+	f.simulateTurn(t)
+
+	var got []string
+	prev := ""
+	for i := 0; i < 4; i++ {
+		evt := stream.next(t)
+		if prev != "" && !convstream.After(evt.ID, prev) {
+			t.Fatalf("event %s arrived after %s", evt.ID, prev)
+		}
+		prev = evt.ID
+		got = append(got, evt.Type)
+		if evt.Type == convstream.TypeMessage {
+			var msg conversation.SMSTranscriptMessage
+			if err := json.Unmarshal(evt.Data, &msg); err != nil {
+				t.Fatalf("decode message: %v", err)
+			}
+			got[i] += ":" + msg.Role
+		}
+	}
+	want := []string{"message:user", "message:assistant", "delivery", "status"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Fatalf("events = %v, want %v", got, want)
+	}
+}
+
+func TestConversationStream_ResumesFromLastEventID(t *testing.T) {
+	f := newStreamFixture(t, 5)
+	first := f.open(t, "org-1", streamConvID, "")
+	f.simulateTurn(t)
+	first.next(t)
+	seen := first.next(t) // the reply; the client drops here
+	first.close()
+
+	resumed := f.open(t, "org-1", streamConvID, seen.ID)
+	if evt := resumed.next(t); evt.Type != convstream.TypeDelivery {
+		t.Fatalf("first resumed event = %s, want the missed delivery", evt.Type)
+	}
+	if evt := resumed.next(t); evt.Type != convstream.TypeStatus {
+		t.Fatalf("second resumed event = %s, want the missed status", evt.Type)
+	}
+	if err := f.transcript.Append(context.Background(), streamConvID, conversation.SMSTranscriptMessage{Role: "user", Body: "2pm please"}); err != nil {
+		t.Fatalf("append: %v", err)
+	}
+	if evt := resumed.next(t); evt.Type != convstream.TypeMessage {
+		t.Fatalf("live event after replay = %s, want message", evt.Type)
+	}
+
+	bad := f.open(t, "org-1", streamConvID, "not-an-id")
+	if bad.resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("invalid Last-Event-ID = %d, want 400", bad.resp.StatusCode)
+	}
+}
+
+func TestConversationStream_CapsConnectionsPerOrg(t *testing.T) {
+	f := newStreamFixture(t, 2)
+	f.open(t, "org-1", streamConvID, "")
+	second := f.open(t, "org-1", "sms:org-1:15550002222", "")
+
+	if over := f.open(t, "org-1", streamConvID, ""); over.resp.StatusCode != http.StatusTooManyRequests {
+		t.Fatalf("third stream for org-1 = %d, want 429", over.resp.StatusCode)
+	}
+	if other := f.open(t, "org-2", "sms:org-2:15550001111", ""); other.resp.StatusCode != http.StatusOK {
+		t.Fatalf("another org's stream = %d, want 200", other.resp.StatusCode)
+	}
+
+	second.close()
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		s := f.open(t, "org-1", streamConvID, "")
+		if s.resp.StatusCode == http.StatusOK {
+			break
+		}
+		s.close()
+		if time.Now().After(deadline) {
+			t.Fatal("expected a slot to free up after a stream closed")
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+func TestConversationStream_RejectsOtherOrgsConversation(t *testing.T) {
+	f := newStreamFixture(t, 2)
+	if s := f.open(t, "org-1", "sms:org-2:15550001111", ""); s.resp.StatusCode != http.StatusNotFound {
+		t.Fatalf("cross-org stream = %d, want 404", s.resp.StatusCode)
+	}
+}
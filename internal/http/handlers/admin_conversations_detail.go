@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
 	"fmt"
@@ -9,6 +10,11 @@ import (
 	"time"
 
 	"github.com/go-chi/chi/v5"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/clinic"
+	"github.com/wolfman30/medspa-ai-platform/internal/conversation"
+	"github.com/wolfman30/medspa-ai-platform/internal/conversation/msgschema"
+	"github.com/wolfman30/medspa-ai-platform/internal/pii"
 )
 
 // GetConversation returns detailed information about a specific conversation.
@@ -49,18 +55,21 @@ func (h *AdminConversationsHandler) GetConversation(w http.ResponseWriter, r *ht
 	if conv.CustomerName == "" {
 		// Fallback: look up by phone
 		h.db.QueryRowContext(r.Context(),
-			`SELECT COALESCE(name, '') FROM leads WHERE org_id = $1 AND phone = $2 LIMIT 1`,
-			parsedOrgID, customerPhone,
+			`SELECT COALESCE(name, '') FROM leads WHERE org_id = $1 AND (phone = $2 OR phone_hash = $3) LIMIT 1`,
+			parsedOrgID, customerPhone, pii.PhoneHash(customerPhone),
 		).Scan(&conv.CustomerName)
 	}
+	conv.CustomerName = pii.Reveal(conv.CustomerName)
 
 	// Try to get conversation from conversations table
 	var startedAt time.Time
-	var lastMessageAt sql.NullTime
+	var lastMessageAt, archivedAt sql.NullTime
+	var archiveKey string
 	err := h.db.QueryRowContext(r.Context(), `
-		SELECT status, message_count, customer_message_count, ai_message_count, started_at, last_message_at
+		SELECT status, message_count, customer_message_count, ai_message_count, started_at, last_message_at,
+			COALESCE(archive_key, ''), archived_at
 		FROM conversations WHERE conversation_id = $1
-	`, conversationID).Scan(&conv.Status, &conv.Metadata.TotalMessages, &conv.Metadata.CustomerMessages, &conv.Metadata.AIMessages, &startedAt, &lastMessageAt)
+	`, conversationID).Scan(&conv.Status, &conv.Metadata.TotalMessages, &conv.Metadata.CustomerMessages, &conv.Metadata.AIMessages, &startedAt, &lastMessageAt, &archiveKey, &archivedAt)
 
 	if err == nil {
 		conv.StartedAt = formatTimeEastern(startedAt)
@@ -69,11 +78,20 @@ func (h *AdminConversationsHandler) GetConversation(w http.ResponseWriter, r *ht
 			conv.LastMessageAt = &formatted
 		}
 
-		// Get messages from conversation_messages table
+		// Get messages from conversation_messages table, after any archived to cold storage
 		messages, _ := h.getMessagesFromDB(r, conversationID)
+		source := "database"
+		if archiveKey != "" {
+			var archived []MessageResponse
+			archived, conv.ColdStorage = h.archivedMessages(r.Context(), conversationID, archiveKey, archivedAt.Time)
+			if len(archived) > 0 {
+				messages = append(archived, messages...)
+				source = "cold_storage"
+			}
+		}
 		if len(messages) > 0 {
 			conv.Messages = messages
-			conv.Metadata.Source = "database"
+			conv.Metadata.Source = source
 		}
 	} else {
 		// Fallback: try to get started_at from conversation_jobs
@@ -100,6 +118,8 @@ func (h *AdminConversationsHandler) GetConversation(w http.ResponseWriter, r *ht
 				conv.Messages = append(conv.Messages, MessageResponse{
 					ID:                msg.ID,
 					Role:              msg.Role,
+					Direction:         msg.Direction,
+					AuthorType:        msg.AuthorType,
 					Content:           msg.Body,
 					Timestamp:         formatTimeEastern(msg.Timestamp),
 					From:              msg.From,
@@ -109,9 +129,10 @@ func (h *AdminConversationsHandler) GetConversation(w http.ResponseWriter, r *ht
 					ErrorReason:       msg.ErrorReason,
 				})
 				conv.Metadata.TotalMessages++
-				if msg.Role == "user" {
+				switch {
+				case msg.FromPatient():
 					conv.Metadata.CustomerMessages++
-				} else if msg.Role == "assistant" {
+				case msg.AuthorType != string(msgschema.Staff) && msg.Role == "assistant":
 					conv.Metadata.AIMessages++
 				}
 			}
@@ -121,7 +142,122 @@ func (h *AdminConversationsHandler) GetConversation(w http.ResponseWriter, r *ht
 	if conv.Status == "" {
 		conv.Status = "active"
 	}
+	conv.Qualification = h.qualificationSnapshot(r.Context(), parsedOrgID, conversationID, conv.Messages)
+	conv.TurnLatency = h.recentTurnLatency(r.Context(), conversationID)
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(conv)
 }
+
+// coldStorageLatencyWarning tells the dashboard why a load was slow.
+const coldStorageLatencyWarning = "Older messages were restored from cold storage; this load may take a few seconds."
+
+// archivedMessages restores a stubbed conversation's messages from cold
+// storage. Failures are reported in the status rather than failing the
+// request so the hot messages can still be shown.
+func (h *AdminConversationsHandler) archivedMessages(ctx context.Context, conversationID, archiveKey string, archivedAt time.Time) ([]MessageResponse, *ColdStorageStatus) {
+	status := &ColdStorageStatus{ArchivedAt: formatTimeEastern(archivedAt)}
+	if h.coldStore == nil {
+		status.Error = "cold storage not configured"
+		return nil, status
+	}
+	archived, cached, err := h.coldStore.Rehydrate(ctx, archiveKey, conversationID)
+	if err != nil {
+		h.logger.Warn("conversation rehydrate failed", "conversation_id", conversationID, "archive_key", archiveKey, "error", err)
+		status.Error = "archived messages unavailable"
+		return nil, status
+	}
+	status.Rehydrated = true
+	status.Cached = cached
+	if !cached {
+		status.LatencyWarning = coldStorageLatencyWarning
+	}
+
+	messages := make([]MessageResponse, 0, len(archived.Messages))
+	for _, msg := range archived.Messages {
+		resp := MessageResponse{
+			ID:                msg.ID,
+			Role:              msg.Role,
+			Direction:         msg.Direction,
+			AuthorType:        msg.AuthorType,
+			Content:           msg.Content,
+			Timestamp:         formatTimeEastern(msg.CreatedAt),
+			From:              msg.FromPhone,
+			To:                msg.ToPhone,
+			ProviderMessageID: msg.ProviderMessageID,
+			Status:            msg.Status,
+			ErrorReason:       msg.ErrorReason,
+		}
+		resp.resolveAuthor(msg.Kind)
+		messages = append(messages, resp)
+	}
+	return messages, status
+}
+
+// qualificationSnapshot derives the qualification snapshot from the transcript.
+// Clinic config and presented slots are best-effort: without them the snapshot
+// still reports what was extracted from the messages.
+func (h *AdminConversationsHandler) qualificationSnapshot(ctx context.Context, orgID, conversationID string, messages []MessageResponse) *conversation.QualificationSnapshot {
+	history := make([]conversation.ChatMessage, 0, len(messages))
+	for _, msg := range messages {
+		switch msg.Role {
+		case conversation.ChatRoleUser, conversation.ChatRoleAssistant:
+			history = append(history, conversation.ChatMessage{Role: msg.Role, Content: msg.Content, Direction: msg.Direction, AuthorType: msg.AuthorType})
+		}
+	}
+
+	var cfg *clinic.Config
+	if h.clinics != nil {
+		loaded, err := h.clinics.Get(ctx, orgID)
+		if err != nil {
+			h.logger.Warn("qualification snapshot: clinic config unavailable", "org_id", orgID, "error", err)
+		} else {
+			cfg = loaded
+		}
+	}
+	state, err := h.timeSelections.Load(ctx, conversationID)
+	if err != nil {
+		h.logger.Warn("qualification snapshot: time selection state unavailable", "conversation_id", conversationID, "error", err)
+	}
+
+	snap := conversation.BuildQualificationSnapshot(history, cfg, state, h.now())
+	return &snap
+}
+
+// recentTurnLatencyLimit is how many turns the conversation detail breaks
+// down.
+const recentTurnLatencyLimit = 10
+
+// recentTurnLatency returns the stage breakdown of the conversation's most
+// recent replies. Lookup failures leave the timeline without it.
+func (h *AdminConversationsHandler) recentTurnLatency(ctx context.Context, conversationID string) []TurnLatencyResponse {
+	rows, err := h.db.QueryContext(ctx, `
+		SELECT job_id, received_at, total_ms, stages_ms, availability_search, over_budget
+		FROM conversation_turn_timings
+		WHERE conversation_id = $1
+		ORDER BY created_at DESC
+		LIMIT $2
+	`, conversationID, recentTurnLatencyLimit)
+	if err != nil {
+		return nil
+	}
+	defer rows.Close()
+
+	var turns []TurnLatencyResponse
+	for rows.Next() {
+		var turn TurnLatencyResponse
+		var receivedAt time.Time
+		var stages []byte
+		if err := rows.Scan(&turn.JobID, &receivedAt, &turn.TotalMS, &stages, &turn.AvailabilitySearch, &turn.OverBudget); err != nil {
+			h.logger.Warn("failed to scan turn latency", "error", err, "conversation_id", conversationID)
+			return nil
+		}
+		if err := json.Unmarshal(stages, &turn.StagesMS); err != nil {
+			h.logger.Warn("failed to decode turn latency stages", "error", err, "conversation_id", conversationID)
+			continue
+		}
+		turn.ReceivedAt = formatTimeEastern(receivedAt)
+		turns = append(turns, turn)
+	}
+	return turns
+}
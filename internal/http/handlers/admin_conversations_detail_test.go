@@ -0,0 +1,194 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/wolfman30/medspa-ai-platform/internal/clinic"
+	"github.com/wolfman30/medspa-ai-platform/internal/clinicdata"
+	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
+)
+
+type stubClinicConfigs map[string]*clinic.Config
+
+func (s stubClinicConfigs) Get(_ context.Context, orgID string) (*clinic.Config, error) {
+	return s[orgID], nil
+}
+
+func TestGetConversation_IncludesQualificationSnapshot(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	const conversationID = "sms:org-1:+15550001111"
+	started := time.Date(2026, 3, 2, 14, 0, 0, 0, time.UTC)
+	mock.ExpectQuery(`FROM conversations c LEFT JOIN leads`).
+		WithArgs(conversationID).
+		WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow("Sarah Johnson"))
+	mock.ExpectQuery(`FROM conversations WHERE conversation_id`).
+		WithArgs(conversationID).
+		WillReturnRows(sqlmock.NewRows([]string{"status", "message_count", "customer_message_count", "ai_message_count", "started_at", "last_message_at", "archive_key", "archived_at"}).
+			AddRow("active", 3, 2, 1, started, started.Add(2*time.Minute), "", nil))
+	messageRows := sqlmock.NewRows([]string{"id", "role", "direction", "author_type", "kind", "content", "from_phone", "to_phone", "provider_message_id", "status", "error_reason", "created_at"})
+	for i, msg := range [][2]string{
+		{"user", "Hi, I'm interested in botox"},
+		{"assistant", "Great choice! May I have your full name?"},
+		{"user", "Sarah Johnson"},
+	} {
+		messageRows.AddRow("m"+string(rune('1'+i)), msg[0], nil, nil, nil, msg[1], nil, nil, nil, nil, nil, started.Add(time.Duration(i)*time.Minute))
+	}
+	mock.ExpectQuery(`FROM conversation_messages`).WithArgs(conversationID).WillReturnRows(messageRows)
+
+	now := time.Date(2026, 3, 2, 15, 0, 0, 0, time.UTC)
+	handler := NewAdminConversationsHandler(db, nil, logging.Default())
+	handler.SetQualificationSources(stubClinicConfigs{"org-1": {ServiceAliases: map[string]string{"botox": "Tox"}}}, nil)
+	handler.now = func() time.Time { return now }
+
+	req := httptest.NewRequest(http.MethodGet, "/admin/orgs/org-1/conversations/"+conversationID, nil)
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("orgID", "org-1")
+	rctx.URLParams.Add("conversationID", conversationID)
+	rec := httptest.NewRecorder()
+	handler.GetConversation(rec, req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx)))
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	var resp ConversationDetailResponse
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&resp))
+	require.Len(t, resp.Messages, 3)
+	q := resp.Qualification
+	require.NotNil(t, q)
+	assert.Equal(t, "Sarah Johnson", q.Name)
+	assert.Equal(t, "Tox", q.ServiceResolved)
+	assert.True(t, q.Missing.PatientType)
+	assert.True(t, q.Missing.Schedule)
+	assert.False(t, q.Missing.Name)
+	assert.False(t, q.ReadyForAvailability)
+	assert.Nil(t, q.PresentedSlots)
+	assert.True(t, now.Equal(q.ComputedAt))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+type stubRehydrator struct {
+	conv   *clinicdata.ColdConversation
+	cached bool
+	err    error
+	key    string
+}
+
+func (s *stubRehydrator) Rehydrate(_ context.Context, archiveKey, _ string) (*clinicdata.ColdConversation, bool, error) {
+	s.key = archiveKey
+	return s.conv, s.cached, s.err
+}
+
+func TestGetConversation_RehydratesColdStorage(t *testing.T) {
+	const conversationID = "sms:org-1:+15550001111"
+	const archiveKey = "conversations/cold/v1/org-1/2026/06/01/batch.json.gz"
+	started := time.Date(2026, 3, 2, 14, 0, 0, 0, time.UTC)
+	archivedAt := time.Date(2026, 6, 1, 3, 0, 0, 0, time.UTC)
+	archived := &clinicdata.ColdConversation{ConversationID: conversationID, Messages: []clinicdata.ColdMessage{
+		{ID: "m1", Role: "user", Content: "Hi, I'm interested in botox", CreatedAt: started},
+		{ID: "m2", Role: "assistant", Content: "Great choice! May I have your full name?", Status: "delivered", CreatedAt: started.Add(time.Minute)},
+	}}
+
+	for name, tc := range map[string]struct {
+		store       *stubRehydrator
+		wantIDs     []string
+		wantSource  string
+		wantWarning bool
+		wantError   bool
+	}{
+		"from s3":     {store: &stubRehydrator{conv: archived}, wantIDs: []string{"m1", "m2", "m3"}, wantSource: "cold_storage", wantWarning: true},
+		"from cache":  {store: &stubRehydrator{conv: archived, cached: true}, wantIDs: []string{"m1", "m2", "m3"}, wantSource: "cold_storage"},
+		"s3 failure":  {store: &stubRehydrator{err: clinicdata.ErrColdConversationNotFound}, wantIDs: []string{"m3"}, wantSource: "database", wantError: true},
+		"unsupported": {wantIDs: []string{"m3"}, wantSource: "database", wantError: true},
+	} {
+		t.Run(name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+
+			mock.ExpectQuery(`FROM conversations c LEFT JOIN leads`).WithArgs(conversationID).
+				WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow("Sarah Johnson"))
+			mock.ExpectQuery(`FROM conversations WHERE conversation_id`).WithArgs(conversationID).
+				WillReturnRows(sqlmock.NewRows([]string{"status", "message_count", "customer_message_count", "ai_message_count", "started_at", "last_message_at", "archive_key", "archived_at"}).
+					AddRow("active", 3, 2, 1, started, started.Add(90*24*time.Hour), archiveKey, archivedAt))
+			mock.ExpectQuery(`FROM conversation_messages`).WithArgs(conversationID).
+				WillReturnRows(sqlmock.NewRows([]string{"id", "role", "direction", "author_type", "kind", "content", "from_phone", "to_phone", "provider_message_id", "status", "error_reason", "created_at"}).
+					AddRow("m3", "user", "inbound", "patient", nil, "Sarah Johnson", nil, nil, nil, nil, nil, started.Add(90*24*time.Hour)))
+
+			handler := NewAdminConversationsHandler(db, nil, logging.Default())
+			if tc.store != nil {
+				handler.SetColdStore(tc.store)
+			}
+			req := httptest.NewRequest(http.MethodGet, "/admin/orgs/org-1/conversations/"+conversationID, nil)
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("orgID", "org-1")
+			rctx.URLParams.Add("conversationID", conversationID)
+			rec := httptest.NewRecorder()
+			handler.GetConversation(rec, req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx)))
+			require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+			var resp ConversationDetailResponse
+			require.NoError(t, json.NewDecoder(rec.Body).Decode(&resp))
+			var ids []string
+			for _, msg := range resp.Messages {
+				ids = append(ids, msg.ID)
+			}
+			assert.Equal(t, tc.wantIDs, ids)
+			assert.Equal(t, tc.wantSource, resp.Metadata.Source)
+			require.NotNil(t, resp.ColdStorage)
+			assert.Equal(t, formatTimeEastern(archivedAt), resp.ColdStorage.ArchivedAt)
+			assert.Equal(t, tc.wantWarning, resp.ColdStorage.LatencyWarning != "")
+			assert.Equal(t, tc.wantError, resp.ColdStorage.Error != "")
+			assert.Equal(t, !tc.wantError, resp.ColdStorage.Rehydrated)
+			if tc.store != nil {
+				assert.Equal(t, archiveKey, tc.store.key)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestGetConversation_IncludesTurnLatency(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+	mock.MatchExpectationsInOrder(false)
+
+	const conversationID = "sms:org-1:+15550001111"
+	received := time.Date(2026, 3, 2, 14, 0, 0, 0, time.UTC)
+	mock.ExpectQuery(`FROM conversation_turn_timings`).
+		WithArgs(conversationID, recentTurnLatencyLimit).
+		WillReturnRows(sqlmock.NewRows([]string{"job_id", "received_at", "total_ms", "stages_ms", "availability_search", "over_budget"}).
+			AddRow("telnyx:m2", received.Add(time.Minute), 24000, []byte(`{"queue_wait":400,"llm":3100,"availability":19800,"send":500,"other":200}`), true, true).
+			AddRow("telnyx:m1", received, 3900, []byte(`{"queue_wait":300,"llm":3200,"send":400}`), false, false))
+
+	handler := NewAdminConversationsHandler(db, nil, logging.Default())
+	req := httptest.NewRequest(http.MethodGet, "/admin/orgs/org-1/conversations/"+conversationID, nil)
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("orgID", "org-1")
+	rctx.URLParams.Add("conversationID", conversationID)
+	rec := httptest.NewRecorder()
+	handler.GetConversation(rec, req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx)))
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	var resp ConversationDetailResponse
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&resp))
+	require.Len(t, resp.TurnLatency, 2)
+	latest := resp.TurnLatency[0]
+	assert.Equal(t, "telnyx:m2", latest.JobID)
+	assert.Equal(t, int64(24000), latest.TotalMS)
+	assert.Equal(t, int64(19800), latest.StagesMS["availability"])
+	assert.True(t, latest.AvailabilitySearch)
+	assert.True(t, latest.OverBudget)
+	assert.False(t, resp.TurnLatency[1].OverBudget)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -8,6 +8,8 @@ import (
 	"time"
 
 	"github.com/go-chi/chi/v5"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/pii"
 )
 
 // ListConversations returns a paginated list of conversations for an organization.
@@ -118,6 +120,7 @@ func (h *AdminConversationsHandler) listFromConversationsTable(w http.ResponseWr
 			h.logger.Error("failed to scan conversation", "error", err)
 			continue
 		}
+		conv.CustomerName = pii.Reveal(conv.CustomerName)
 
 		conv.StartedAt = formatTimeEastern(startedAt)
 		if lastMessageAt.Valid {
@@ -218,9 +221,10 @@ func (h *AdminConversationsHandler) listFromConversationJobs(w http.ResponseWrit
 		// Try to look up patient name from leads table by phone
 		var customerName string
 		h.db.QueryRowContext(r.Context(),
-			`SELECT COALESCE(name, '') FROM leads WHERE org_id = $1 AND phone = $2 LIMIT 1`,
-			parsedOrgID, customerPhone,
+			`SELECT COALESCE(name, '') FROM leads WHERE org_id = $1 AND (phone = $2 OR phone_hash = $3) LIMIT 1`,
+			parsedOrgID, customerPhone, pii.PhoneHash(customerPhone),
 		).Scan(&customerName)
+		customerName = pii.Reveal(customerName)
 
 		lastFormatted := formatTimeEastern(lastActivity)
 		conversations = append(conversations, ConversationListItem{
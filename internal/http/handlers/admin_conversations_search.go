@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/conversation"
+	"github.com/wolfman30/medspa-ai-platform/internal/tenancy"
+)
+
+const (
+	defaultSearchLimit = 25
+	maxSearchLimit     = 100
+)
+
+// SearchConversations finds conversations whose messages match a full-text query.
+// GET /admin/orgs/{orgID}/conversations/search?q=groupon&date_from=2026-01-01&date_to=2026-01-31&limit=25
+func (h *AdminConversationsHandler) SearchConversations(w http.ResponseWriter, r *http.Request) {
+	scope := tenancy.ScopeFromContext(r.Context())
+	orgID := scope.OrgID()
+	if orgID == "" {
+		jsonError(w, "missing orgID", http.StatusBadRequest)
+		return
+	}
+	q := strings.TrimSpace(r.URL.Query().Get("q"))
+	if q == "" {
+		jsonError(w, "missing search query (q)", http.StatusBadRequest)
+		return
+	}
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	if limit < 1 || limit > maxSearchLimit {
+		limit = defaultSearchLimit
+	}
+
+	var dateRange conversation.DateRange
+	if v := r.URL.Query().Get("date_from"); v != "" {
+		t, err := time.ParseInLocation("2006-01-02", v, easternLocation)
+		if err != nil {
+			jsonError(w, "invalid date_from (expected YYYY-MM-DD)", http.StatusBadRequest)
+			return
+		}
+		dateRange.From = t
+	}
+	if v := r.URL.Query().Get("date_to"); v != "" {
+		t, err := time.ParseInLocation("2006-01-02", v, easternLocation)
+		if err != nil {
+			jsonError(w, "invalid date_to (expected YYYY-MM-DD)", http.StatusBadRequest)
+			return
+		}
+		dateRange.To = t.AddDate(0, 0, 1)
+	}
+
+	results, err := conversation.NewConversationStore(h.db).SearchMessages(r.Context(), scope, q, dateRange, limit)
+	if errors.Is(err, conversation.ErrEmptySearchQuery) {
+		jsonError(w, "search query has no searchable terms", http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		h.logger.Error("failed to search conversations", "error", err, "org_id", orgID)
+		jsonError(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+
+	resp := ConversationSearchResponse{Query: q, Results: make([]ConversationSearchItem, 0, len(results))}
+	for _, res := range results {
+		item := ConversationSearchItem{
+			ID:            res.ConversationID,
+			Channel:       channelFromConversationID(res.ConversationID),
+			CustomerPhone: res.Phone,
+			CustomerName:  res.CustomerName,
+			Status:        res.Status,
+			MatchCount:    res.MatchCount,
+			LastMatchAt:   formatTimeEastern(res.LastMatchAt),
+			Snippets:      make([]SearchSnippetResult, 0, len(res.Snippets)),
+		}
+		for _, s := range res.Snippets {
+			item.Snippets = append(item.Snippets, SearchSnippetResult{
+				MessageID: s.MessageID.String(),
+				Role:      s.Role,
+				Snippet:   s.Snippet,
+				Timestamp: formatTimeEastern(s.CreatedAt),
+			})
+		}
+		resp.Results = append(resp.Results, item)
+	}
+	resp.Total = len(resp.Results)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/tenancy"
+	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
+)
+
+func searchRequest(orgID, rawQuery string) *http.Request {
+	req := httptest.NewRequest(http.MethodGet, "/admin/orgs/"+orgID+"/conversations/search?"+rawQuery, nil)
+	return req.WithContext(tenancy.WithOrgID(req.Context(), orgID))
+}
+
+func TestSearchConversations_ReturnsHighlightedMatches(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+	handler := NewAdminConversationsHandler(db, nil, logging.Default())
+
+	from := time.Date(2026, 2, 1, 0, 0, 0, 0, easternLocation)
+	to := time.Date(2026, 3, 1, 0, 0, 0, 0, easternLocation)
+	msgID := uuid.New()
+	rows := sqlmock.NewRows([]string{"id", "conversation_id", "org_id", "phone", "customer_name", "status", "role", "content", "created_at", "rank", "match_count"}).
+		AddRow(msgID, "sms:org-1:+15550001111", "org-1", "+15550001111", "Jane Doe", "active",
+			"user", "Can I use my Groupon for Botox?", time.Date(2026, 2, 20, 15, 0, 0, 0, time.UTC), 0.08, 3)
+	mock.ExpectQuery(`FROM conversation_messages m`).
+		WithArgs("org-1", "groupon", from, to, sqlmock.AnyArg()).
+		WillReturnRows(rows)
+
+	rec := httptest.NewRecorder()
+	handler.SearchConversations(rec, searchRequest("org-1", "q=groupon&date_from=2026-02-01&date_to=2026-02-28"))
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	var resp ConversationSearchResponse
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&resp))
+	require.Equal(t, 1, resp.Total)
+	got := resp.Results[0]
+	assert.Equal(t, "sms:org-1:+15550001111", got.ID)
+	assert.Equal(t, "sms", got.Channel)
+	assert.Equal(t, "Jane Doe", got.CustomerName)
+	assert.Equal(t, 3, got.MatchCount)
+	require.Len(t, got.Snippets, 1)
+	assert.Equal(t, msgID.String(), got.Snippets[0].MessageID)
+	assert.Equal(t, "Can I use my <mark>Groupon</mark> for Botox?", got.Snippets[0].Snippet)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSearchConversations_RejectsBadInput(t *testing.T) {
+	db, _, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+	handler := NewAdminConversationsHandler(db, nil, logging.Default())
+
+	for _, rawQuery := range []string{"", "q=%20%20", "q=-only", "q=groupon&date_from=last-week"} {
+		rec := httptest.NewRecorder()
+		handler.SearchConversations(rec, searchRequest("org-1", rawQuery))
+		assert.Equal(t, http.StatusBadRequest, rec.Code, "query %q", rawQuery)
+	}
+}
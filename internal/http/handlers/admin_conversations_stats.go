@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"database/sql"
 	"encoding/json"
 	"net/http"
 	"net/url"
@@ -130,9 +131,11 @@ func (h *AdminConversationsHandler) ExportTranscript(w http.ResponseWriter, r *h
 
 	// Get start time from conversations table or conversation_jobs
 	var startedAt time.Time
+	var archiveKey string
+	var archivedAt sql.NullTime
 	h.db.QueryRowContext(r.Context(),
-		`SELECT started_at FROM conversations WHERE conversation_id = $1`, conversationID,
-	).Scan(&startedAt)
+		`SELECT started_at, COALESCE(archive_key, ''), archived_at FROM conversations WHERE conversation_id = $1`, conversationID,
+	).Scan(&startedAt, &archiveKey, &archivedAt)
 	if startedAt.IsZero() {
 		h.db.QueryRowContext(r.Context(),
 			`SELECT MIN(created_at) FROM conversation_jobs WHERE conversation_id = $1`, conversationID,
@@ -149,16 +152,18 @@ func (h *AdminConversationsHandler) ExportTranscript(w http.ResponseWriter, r *h
 	transcript += "Conversation ID: " + conversationID + "\n\n"
 	transcript += "--- Messages ---\n\n"
 
-	// Try to get messages from database first
+	// Try to get messages from database first, after any archived to cold storage
 	messages, _ := h.getMessagesFromDB(r, conversationID)
+	if archiveKey != "" {
+		archived, status := h.archivedMessages(r.Context(), conversationID, archiveKey, archivedAt.Time)
+		if status.Error != "" {
+			transcript += "(Messages archived " + status.ArchivedAt + " are unavailable: " + status.Error + ")\n\n"
+		}
+		messages = append(archived, messages...)
+	}
 	if len(messages) > 0 {
 		for _, msg := range messages {
-			roleLabel := msg.Role
-			if roleLabel == "assistant" {
-				roleLabel = "AI"
-			} else if roleLabel == "user" {
-				roleLabel = "Customer"
-			}
+			roleLabel := authorLabel(msg.AuthorType)
 			timestamp, _ := time.Parse(time.RFC3339, msg.Timestamp)
 			transcript += "[" + timestamp.In(easternLocation).Format("2006-01-02 15:04:05") + "] " + roleLabel + ":\n"
 			transcript += msg.Content + "\n\n"
@@ -168,12 +173,7 @@ func (h *AdminConversationsHandler) ExportTranscript(w http.ResponseWriter, r *h
 		redisMessages, err := h.transcriptStore.List(r.Context(), conversationID, 0)
 		if err == nil {
 			for _, msg := range redisMessages {
-				roleLabel := msg.Role
-				if roleLabel == "assistant" {
-					roleLabel = "AI"
-				} else if roleLabel == "user" {
-					roleLabel = "Customer"
-				}
+				roleLabel := authorLabel(msg.AuthorType)
 				transcript += "[" + msg.Timestamp.In(easternLocation).Format("2006-01-02 15:04:05") + "] " + roleLabel + ":\n"
 				transcript += msg.Body + "\n\n"
 			}
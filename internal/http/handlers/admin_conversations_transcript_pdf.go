@@ -0,0 +1,441 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-pdf/fpdf"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/compliance"
+	"github.com/wolfman30/medspa-ai-platform/internal/conversation/msgschema"
+	"github.com/wolfman30/medspa-ai-platform/internal/pii"
+)
+
+var (
+	// transcriptURLRE finds links in outbound messages.
+	transcriptURLRE = regexp.MustCompile(`(?i)https?://\S+`)
+	// transcriptPaymentRE tells a payment link from any other link.
+	transcriptPaymentRE = regexp.MustCompile(`(?i)deposit|checkout|pay`)
+	// transcriptPolicyRE matches the deposit, refund and cancellation terms
+	// of the policy disclosure.
+	transcriptPolicyRE = regexp.MustCompile(`(?i)forfeit|refund|no-show|no show|cancellation|cancel within|applies toward`)
+)
+
+// transcriptCover is the lead, booking and payment summary printed above a
+// PDF transcript.
+type transcriptCover struct {
+	LeadID             string
+	Name               string
+	Phone              string
+	Service            string
+	SelectedAt         sql.NullTime
+	BookingOutcome     string
+	ConfirmationNumber string
+	PaymentAmountCents int
+	PaymentStatus      string
+	PaymentProvider    string
+	PaymentProviderRef string
+	PaymentCreatedAt   sql.NullTime
+	PaymentFound       bool
+	ArchivedAt         string
+	ArchiveError       string
+}
+
+// ExportTranscriptPDF renders a conversation as a PDF for deposit dispute
+// evidence: a cover block with the lead, booking and payment, then every
+// message with its author and delivery status, timestamps in the clinic's
+// timezone, and the policy disclosure and payment link highlighted. The lead's
+// name and phone are masked unless ?unmasked=true, which is audit logged.
+// GET /admin/orgs/{orgID}/conversations/{conversationID}/transcript.pdf
+func (h *AdminConversationsHandler) ExportTranscriptPDF(w http.ResponseWriter, r *http.Request) {
+	orgID := chi.URLParam(r, "orgID")
+	conversationID := chi.URLParam(r, "conversationID")
+	if orgID == "" || conversationID == "" {
+		http.Error(w, "missing orgID or conversationID", http.StatusBadRequest)
+		return
+	}
+	if decoded, err := url.PathUnescape(conversationID); err == nil {
+		conversationID = decoded
+	}
+	parsedOrgID, customerPhone, ok := parseConversationID(conversationID)
+	if !ok || parsedOrgID != orgID {
+		http.Error(w, "conversation not found", http.StatusNotFound)
+		return
+	}
+	unmasked := false
+	if raw := r.URL.Query().Get("unmasked"); raw != "" {
+		v, err := strconv.ParseBool(raw)
+		if err != nil {
+			http.Error(w, "unmasked must be true or false", http.StatusBadRequest)
+			return
+		}
+		unmasked = v
+	}
+	if unmasked && h.audit == nil {
+		http.Error(w, "unmasked export requires audit logging", http.StatusForbidden)
+		return
+	}
+
+	cover := h.transcriptCover(r.Context(), orgID, conversationID, customerPhone)
+	messages, started := h.transcriptMessages(r, conversationID, &cover)
+	if len(messages) == 0 && started.IsZero() {
+		http.Error(w, "conversation not found", http.StatusNotFound)
+		return
+	}
+
+	if unmasked {
+		if err := h.logTranscriptUnmasked(r, orgID, conversationID, cover.LeadID); err != nil {
+			h.logger.Error("failed to audit unmasked transcript export", "error", err, "org_id", orgID)
+			http.Error(w, "failed to record audit event", http.StatusInternalServerError)
+			return
+		}
+	}
+	// The conversation ID embeds the phone, so it is masked with it.
+	displayID := conversationID
+	if !unmasked {
+		cover.Name = maskLeadName(cover.Name)
+		cover.Phone = maskLeadPhone(cover.Phone)
+		displayID = strings.TrimSuffix(conversationID, customerPhone) + cover.Phone
+	}
+
+	doc, err := renderTranscriptPDF(displayID, h.transcriptLocation(r.Context(), orgID), started, h.now(), cover, messages)
+	if err != nil {
+		h.logger.Error("failed to render transcript pdf", "error", err, "org_id", orgID)
+		http.Error(w, "failed to render transcript", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/pdf")
+	w.Header().Set("Content-Disposition", `attachment; filename="transcript-`+transcriptFilename(displayID)+`.pdf"`)
+	w.Header().Set("Cache-Control", "no-store")
+	w.Write(doc)
+}
+
+// transcriptCover loads the lead behind a conversation with its selected
+// appointment and most recent deposit.
+func (h *AdminConversationsHandler) transcriptCover(ctx context.Context, orgID, conversationID, customerPhone string) transcriptCover {
+	cover := transcriptCover{Phone: customerPhone}
+	const leadColumns = `l.id, COALESCE(l.name, ''), COALESCE(l.selected_service, ''), l.selected_datetime,
+		COALESCE(l.booking_outcome, ''), COALESCE(l.booking_confirmation_number, '')`
+	scan := func(row *sql.Row) error {
+		return row.Scan(&cover.LeadID, &cover.Name, &cover.Service, &cover.SelectedAt, &cover.BookingOutcome, &cover.ConfirmationNumber)
+	}
+	err := scan(h.db.QueryRowContext(ctx,
+		`SELECT `+leadColumns+` FROM conversations c JOIN leads l ON c.lead_id = l.id WHERE c.conversation_id = $1`,
+		conversationID,
+	))
+	if err != nil {
+		err = scan(h.db.QueryRowContext(ctx,
+			`SELECT `+leadColumns+` FROM leads l WHERE l.org_id = $1 AND (l.phone = $2 OR l.phone_hash = $3) ORDER BY l.created_at DESC LIMIT 1`,
+			orgID, customerPhone, pii.PhoneHash(customerPhone),
+		))
+	}
+	if err != nil {
+		return cover
+	}
+	cover.Name = pii.Reveal(cover.Name)
+
+	err = h.db.QueryRowContext(ctx, `
+		SELECT amount_cents, status, provider, COALESCE(provider_ref, ''), created_at
+		FROM payments WHERE org_id = $1 AND lead_id = $2
+		ORDER BY created_at DESC LIMIT 1
+	`, orgID, cover.LeadID).Scan(&cover.PaymentAmountCents, &cover.PaymentStatus, &cover.PaymentProvider, &cover.PaymentProviderRef, &cover.PaymentCreatedAt)
+	cover.PaymentFound = err == nil
+	return cover
+}
+
+// transcriptMessages loads every message of a conversation the way
+// GetConversation does: archived messages first, then the database, falling
+// back to Redis. It also returns when the conversation started.
+func (h *AdminConversationsHandler) transcriptMessages(r *http.Request, conversationID string, cover *transcriptCover) ([]MessageResponse, time.Time) {
+	var startedAt time.Time
+	var archiveKey string
+	var archivedAt sql.NullTime
+	h.db.QueryRowContext(r.Context(),
+		`SELECT started_at, COALESCE(archive_key, ''), archived_at FROM conversations WHERE conversation_id = $1`, conversationID,
+	).Scan(&startedAt, &archiveKey, &archivedAt)
+
+	messages, _ := h.getMessagesFromDB(r, conversationID)
+	if archiveKey != "" {
+		archived, status := h.archivedMessages(r.Context(), conversationID, archiveKey, archivedAt.Time)
+		if status.Error != "" {
+			cover.ArchivedAt, cover.ArchiveError = status.ArchivedAt, status.Error
+		}
+		messages = append(archived, messages...)
+	}
+	if len(messages) == 0 && h.transcriptStore != nil {
+		if stored, err := h.transcriptStore.List(r.Context(), conversationID, 0); err == nil {
+			for _, msg := range stored {
+				m := MessageResponse{
+					ID:                msg.ID,
+					Role:              msg.Role,
+					Direction:         msg.Direction,
+					AuthorType:        msg.AuthorType,
+					Content:           msg.Body,
+					Timestamp:         formatTimeEastern(msg.Timestamp),
+					ProviderMessageID: msg.ProviderMessageID,
+					Status:            msg.Status,
+					ErrorReason:       msg.ErrorReason,
+				}
+				m.resolveAuthor(msg.Kind)
+				messages = append(messages, m)
+			}
+		}
+	}
+	if startedAt.IsZero() && len(messages) > 0 {
+		startedAt, _ = time.Parse(time.RFC3339, messages[0].Timestamp)
+	}
+	return messages, startedAt
+}
+
+// transcriptLocation is the clinic's timezone, Eastern when it has none.
+func (h *AdminConversationsHandler) transcriptLocation(ctx context.Context, orgID string) *time.Location {
+	if h.clinics == nil {
+		return easternLocation
+	}
+	cfg, err := h.clinics.Get(ctx, orgID)
+	if err != nil || cfg == nil || strings.TrimSpace(cfg.Timezone) == "" {
+		return easternLocation
+	}
+	loc, err := time.LoadLocation(cfg.Timezone)
+	if err != nil {
+		return easternLocation
+	}
+	return loc
+}
+
+// logTranscriptUnmasked records who exported a transcript with the lead's
+// name and phone revealed.
+func (h *AdminConversationsHandler) logTranscriptUnmasked(r *http.Request, orgID, conversationID, leadID string) error {
+	actorType, actorEmail := auditActor(r)
+	details, _ := json.Marshal(map[string]any{
+		"actor_type":  actorType,
+		"actor_email": actorEmail,
+		"format":      "pdf",
+		"method":      r.Method,
+		"path":        r.URL.Path,
+	})
+	return h.audit.LogEvent(r.Context(), compliance.AuditEvent{
+		EventType:      compliance.EventTranscriptUnmasked,
+		OrgID:          orgID,
+		ConversationID: conversationID,
+		LeadID:         leadID,
+		Details:        details,
+	})
+}
+
+// maskLeadName keeps a lead's first name and initials the rest, "Sarah J.".
+func maskLeadName(name string) string {
+	parts := strings.Fields(name)
+	if len(parts) == 0 {
+		return ""
+	}
+	for i := 1; i < len(parts); i++ {
+		parts[i] = string([]rune(parts[i])[:1]) + "."
+	}
+	return strings.Join(parts, " ")
+}
+
+// transcriptHighlights names what a message is evidence of: the policy
+// disclosure the patient saw, the payment link they were sent, or both.
+func transcriptHighlights(msg MessageResponse) []string {
+	if msg.AuthorType == string(msgschema.Patient) {
+		return nil
+	}
+	var out []string
+	if strings.Contains(strings.ToLower(msg.Content), "deposit") && transcriptPolicyRE.MatchString(msg.Content) {
+		out = append(out, "Policy disclosure")
+	}
+	for _, link := range transcriptURLRE.FindAllString(msg.Content, -1) {
+		if transcriptPaymentRE.MatchString(link) || transcriptPaymentRE.MatchString(msg.Content) {
+			out = append(out, "Payment link")
+			break
+		}
+	}
+	return out
+}
+
+// transcriptStatus describes a message's delivery for the transcript.
+func transcriptStatus(msg MessageResponse) string {
+	if msg.Direction == string(msgschema.Inbound) {
+		return "received"
+	}
+	status := msg.Status
+	if status == "" {
+		status = "status not recorded"
+	}
+	if msg.ErrorReason != "" {
+		status += " (" + msg.ErrorReason + ")"
+	}
+	return status
+}
+
+// pdfSafe drops runes the built-in fonts can't draw, like emoji, before they
+// are translated to cp1252; U+2122 (™) is the highest rune cp1252 encodes.
+func pdfSafe(tr func(string) string, s string) string {
+	s = strings.Map(func(r rune) rune {
+		if r > 0x2122 {
+			return -1
+		}
+		return r
+	}, s)
+	return tr(s)
+}
+
+// renderTranscriptPDF lays out the cover block and messages. Pages break
+// automatically and each carries the conversation ID and page count.
+func renderTranscriptPDF(conversationID string, loc *time.Location, started, generated time.Time, cover transcriptCover, messages []MessageResponse) ([]byte, error) {
+	const (
+		margin     = 15.0
+		lineHeight = 5.0
+		timeLayout = "Jan 2, 2006 3:04:05 PM MST"
+	)
+	pdf := fpdf.New("P", "mm", "Letter", "")
+	pdf.SetMargins(margin, margin, margin)
+	pdf.SetAutoPageBreak(true, margin+5)
+	pdf.SetCreationDate(generated)
+	pdf.SetTitle("Conversation transcript "+conversationID, true)
+	pdf.AliasNbPages("")
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+	text := func(s string) string { return pdfSafe(tr, s) }
+
+	pdf.SetFooterFunc(func() {
+		pdf.SetY(-margin)
+		pdf.SetFont("Helvetica", "I", 8)
+		pdf.SetTextColor(110, 110, 110)
+		pdf.CellFormat(0, 4, text(conversationID), "", 0, "L", false, 0, "")
+		pdf.CellFormat(0, 4, fmt.Sprintf("Page %d of {nb}", pdf.PageNo()), "", 0, "R", false, 0, "")
+	})
+	pdf.AddPage()
+	width, height := pdf.GetPageSize()
+	width -= 2 * margin
+
+	pdf.SetFont("Helvetica", "B", 16)
+	pdf.CellFormat(0, 9, "Conversation Transcript", "", 1, "L", false, 0, "")
+	pdf.SetFont("Helvetica", "", 9)
+	pdf.SetTextColor(90, 90, 90)
+	pdf.CellFormat(0, lineHeight, text("Generated "+generated.In(loc).Format(timeLayout)+" - times shown in "+loc.String()), "", 1, "L", false, 0, "")
+	pdf.Ln(3)
+
+	row := func(label, value string) {
+		if value == "" {
+			return
+		}
+		pdf.SetFont("Helvetica", "B", 10)
+		pdf.SetTextColor(0, 0, 0)
+		pdf.CellFormat(40, lineHeight+1, text(label), "", 0, "L", false, 0, "")
+		pdf.SetFont("Helvetica", "", 10)
+		pdf.MultiCell(width-40, lineHeight+1, text(value), "", "L", false)
+	}
+	section := func(title string) {
+		pdf.Ln(2)
+		pdf.SetFont("Helvetica", "B", 11)
+		pdf.SetTextColor(0, 0, 0)
+		pdf.SetFillColor(235, 238, 242)
+		pdf.CellFormat(0, 7, text(title), "", 1, "L", true, 0, "")
+		pdf.Ln(1)
+	}
+
+	section("Patient")
+	row("Name", valueOr(cover.Name, "Unknown"))
+	row("Phone", valueOr(cover.Phone, "Unknown"))
+	row("Conversation", conversationID)
+	if !started.IsZero() {
+		row("Started", started.In(loc).Format(timeLayout))
+	}
+
+	section("Booking")
+	row("Service", valueOr(cover.Service, "Not selected"))
+	if cover.SelectedAt.Valid {
+		row("Selected time", cover.SelectedAt.Time.In(loc).Format("Mon Jan 2, 2006 3:04 PM MST"))
+	} else {
+		row("Selected time", "Not selected")
+	}
+	row("Outcome", cover.BookingOutcome)
+	row("Confirmation #", cover.ConfirmationNumber)
+
+	section("Payment")
+	if cover.PaymentFound {
+		row("Deposit", fmt.Sprintf("$%.2f", float64(cover.PaymentAmountCents)/100))
+		row("Status", cover.PaymentStatus)
+		row("Provider", cover.PaymentProvider)
+		row("Reference", cover.PaymentProviderRef)
+		if cover.PaymentCreatedAt.Valid {
+			row("Created", cover.PaymentCreatedAt.Time.In(loc).Format(timeLayout))
+		}
+	} else {
+		row("Deposit", "No payment recorded")
+	}
+
+	section(fmt.Sprintf("Messages (%d)", len(messages)))
+	if cover.ArchiveError != "" {
+		pdf.SetFont("Helvetica", "I", 9)
+		pdf.SetTextColor(160, 30, 30)
+		pdf.MultiCell(0, lineHeight, text("Messages archived "+cover.ArchivedAt+" are unavailable: "+cover.ArchiveError), "", "L", false)
+		pdf.Ln(2)
+	}
+	if len(messages) == 0 {
+		pdf.SetFont("Helvetica", "I", 10)
+		pdf.CellFormat(0, lineHeight, "No messages found", "", 1, "L", false, 0, "")
+	}
+	for _, msg := range messages {
+		stamp := msg.Timestamp
+		if ts, err := time.Parse(time.RFC3339, msg.Timestamp); err == nil {
+			stamp = ts.In(loc).Format(timeLayout)
+		}
+		highlights := transcriptHighlights(msg)
+
+		// Keep a message's header with at least the first lines of its body.
+		if pdf.GetY()+4*lineHeight > height-margin-5 {
+			pdf.AddPage()
+		}
+		pdf.SetFont("Helvetica", "B", 9)
+		pdf.SetTextColor(40, 40, 40)
+		header := authorLabel(msg.AuthorType) + "  |  " + stamp + "  |  " + transcriptStatus(msg)
+		pdf.CellFormat(0, lineHeight, text(header), "", 1, "L", false, 0, "")
+		if len(highlights) > 0 {
+			pdf.SetFont("Helvetica", "B", 8)
+			pdf.SetTextColor(120, 80, 0)
+			pdf.CellFormat(0, lineHeight-1, text(strings.ToUpper(strings.Join(highlights, " + "))), "", 1, "L", false, 0, "")
+			pdf.SetFillColor(255, 243, 196)
+		}
+		pdf.SetFont("Helvetica", "", 10)
+		pdf.SetTextColor(0, 0, 0)
+		pdf.MultiCell(0, lineHeight, text(msg.Content), "", "L", len(highlights) > 0)
+		pdf.Ln(3)
+	}
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, fmt.Errorf("render transcript pdf: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// transcriptFilename makes a conversation ID safe for a download filename.
+func transcriptFilename(conversationID string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-':
+			return r
+		}
+		return '_'
+	}, conversationID)
+}
+
+// valueOr returns value, or fallback when value is blank.
+func valueOr(value, fallback string) string {
+	if strings.TrimSpace(value) == "" {
+		return fallback
+	}
+	return value
+}
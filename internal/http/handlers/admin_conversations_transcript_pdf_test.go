@@ -0,0 +1,182 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-chi/chi/v5"
+	"github.com/ledongthuc/pdf"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/wolfman30/medspa-ai-platform/internal/compliance"
+	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
+)
+
+const transcriptConversationID = "sms:org-1:+15550001111"
+
+// expectTranscriptQueries mocks the lead, payment, conversation and message
+// lookups behind a transcript export.
+func expectTranscriptQueries(mock sqlmock.Sqlmock, started time.Time, messages [][3]string) {
+	mock.ExpectQuery(`FROM conversations c JOIN leads l`).
+		WithArgs(transcriptConversationID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "selected_service", "selected_datetime", "booking_outcome", "booking_confirmation_number"}).
+			AddRow("lead-1", "Sarah Johnson", "Botox", started.Add(72*time.Hour), "success", "CONF-123"))
+	mock.ExpectQuery(`FROM payments WHERE org_id`).
+		WithArgs("org-1", "lead-1").
+		WillReturnRows(sqlmock.NewRows([]string{"amount_cents", "status", "provider", "provider_ref", "created_at"}).
+			AddRow(5000, "succeeded", "square", "sq_ref_1", started.Add(10*time.Minute)))
+	mock.ExpectQuery(`SELECT started_at`).
+		WithArgs(transcriptConversationID).
+		WillReturnRows(sqlmock.NewRows([]string{"started_at", "archive_key", "archived_at"}).AddRow(started, "", nil))
+	rows := sqlmock.NewRows([]string{"id", "role", "direction", "author_type", "kind", "content", "from_phone", "to_phone", "provider_message_id", "status", "error_reason", "created_at"})
+	for i, msg := range messages {
+		rows.AddRow(fmt.Sprintf("m%d", i), msg[0], nil, nil, nil, msg[1], nil, nil, nil, msg[2], nil, started.Add(time.Duration(i)*time.Minute))
+	}
+	mock.ExpectQuery(`FROM conversation_messages`).WithArgs(transcriptConversationID).WillReturnRows(rows)
+}
+
+func transcriptPDFRequest(handler *AdminConversationsHandler, query string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/admin/orgs/org-1/conversations/"+transcriptConversationID+"/transcript.pdf"+query, nil)
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("orgID", "org-1")
+	rctx.URLParams.Add("conversationID", transcriptConversationID)
+	rec := httptest.NewRecorder()
+	handler.ExportTranscriptPDF(rec, req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx)))
+	return rec
+}
+
+// pdfText extracts the plain text and page count of a rendered PDF.
+func pdfText(t *testing.T, doc []byte) (string, int) {
+	t.Helper()
+	reader, err := pdf.NewReader(bytes.NewReader(doc), int64(len(doc)))
+	require.NoError(t, err)
+	plain, err := reader.GetPlainText()
+	require.NoError(t, err)
+	text, err := io.ReadAll(plain)
+	require.NoError(t, err)
+	return string(text), reader.NumPage()
+}
+
+var disputeMessages = [][3]string{
+	{"user", "Hi, I want Botox on Friday", ""},
+	{"assistant", "💳 $50 deposit — applies toward your treatment cost. Deposits are forfeited for no-shows.\n\n→ Complete your deposit here:\nhttps://square.link/u/abc", "delivered"},
+	{"user", "Paid, see you Friday", ""},
+	{"assistant", "You're booked for Friday at 2 PM!", "failed"},
+}
+
+func TestExportTranscriptPDF_RendersMaskedTranscript(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	started := time.Date(2026, 3, 2, 19, 0, 0, 0, time.UTC)
+	expectTranscriptQueries(mock, started, disputeMessages)
+
+	handler := NewAdminConversationsHandler(db, nil, logging.Default())
+	handler.SetQualificationSources(stubClinicConfigs{"org-1": {Timezone: "America/Chicago"}}, nil)
+	handler.now = func() time.Time { return started.Add(time.Hour) }
+
+	rec := transcriptPDFRequest(handler, "")
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Equal(t, "application/pdf", rec.Header().Get("Content-Type"))
+
+	text, _ := pdfText(t, rec.Body.Bytes())
+	for _, want := range []string{
+		"Conversation Transcript",
+		"Sarah J.",
+		"1111",
+		"Botox",
+		"CONF-123",
+		"$50.00",
+		"succeeded",
+		"sq_ref_1",
+		"POLICY DISCLOSURE + PAYMENT LINK",
+		"https://square.link/u/abc",
+		"Mar 2, 2026 1:01:00 PM CST",
+		"delivered",
+		"failed",
+		"Paid, see you Friday",
+	} {
+		assert.Contains(t, text, want)
+	}
+	assert.NotContains(t, text, "Johnson")
+	assert.NotContains(t, text, "5550001111")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestExportTranscriptPDF_UnmaskedWritesAuditEvent(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	started := time.Date(2026, 3, 2, 19, 0, 0, 0, time.UTC)
+	expectTranscriptQueries(mock, started, disputeMessages)
+	mock.ExpectExec(`INSERT INTO compliance_audit_events`).
+		WithArgs(sqlmock.AnyArg(), compliance.EventTranscriptUnmasked, "org-1", transcriptConversationID, "lead-1", nil, nil, sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	handler := NewAdminConversationsHandler(db, nil, logging.Default())
+	handler.SetAuditService(compliance.NewAuditService(db))
+
+	rec := transcriptPDFRequest(handler, "?unmasked=true")
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	text, _ := pdfText(t, rec.Body.Bytes())
+	assert.Contains(t, text, "Sarah Johnson")
+	assert.Contains(t, text, "+15550001111")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestExportTranscriptPDF_UnmaskedRequiresAudit(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	handler := NewAdminConversationsHandler(db, nil, logging.Default())
+	rec := transcriptPDFRequest(handler, "?unmasked=true")
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestExportTranscriptPDF_PaginatesLongConversations(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	started := time.Date(2026, 3, 2, 19, 0, 0, 0, time.UTC)
+	var messages [][3]string
+	for i := 0; i < 150; i++ {
+		role := "user"
+		if i%2 == 1 {
+			role = "assistant"
+		}
+		messages = append(messages, [3]string{role, fmt.Sprintf("Message number %d about scheduling a consultation.", i), ""})
+	}
+	expectTranscriptQueries(mock, started, messages)
+
+	handler := NewAdminConversationsHandler(db, nil, logging.Default())
+	rec := transcriptPDFRequest(handler, "")
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	text, pages := pdfText(t, rec.Body.Bytes())
+	assert.Greater(t, pages, 1)
+	assert.Contains(t, text, fmt.Sprintf("Page %d of %d", pages, pages))
+	assert.Contains(t, text, "Message number 149 about")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestTranscriptHighlights(t *testing.T) {
+	assert.Nil(t, transcriptHighlights(MessageResponse{AuthorType: "patient", Content: "Is the deposit refundable? https://pay.example.com"}))
+	assert.Equal(t, []string{"Payment link"}, transcriptHighlights(MessageResponse{AuthorType: "ai", Content: "Pay here: https://square.link/u/abc"}))
+	assert.Equal(t, []string{"Policy disclosure"}, transcriptHighlights(MessageResponse{AuthorType: "ai", Content: "The $50 deposit is non-refundable for late cancellations."}))
+	assert.Nil(t, transcriptHighlights(MessageResponse{AuthorType: "ai", Content: "See our menu at https://glow.example.com/menu"}))
+	assert.Equal(t, "Sarah J.", maskLeadName("Sarah Johnson"))
+	assert.Empty(t, maskLeadName(" "))
+}
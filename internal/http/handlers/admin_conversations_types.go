@@ -1,13 +1,17 @@
 package handlers
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
 	"net/http"
 	"strings"
 	"time"
 
+	"github.com/wolfman30/medspa-ai-platform/internal/clinicdata"
+	"github.com/wolfman30/medspa-ai-platform/internal/compliance"
 	"github.com/wolfman30/medspa-ai-platform/internal/conversation"
+	"github.com/wolfman30/medspa-ai-platform/internal/conversation/msgschema"
 	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
 )
 
@@ -16,7 +20,12 @@ import (
 type AdminConversationsHandler struct {
 	db              *sql.DB
 	transcriptStore *conversation.SMSTranscriptStore
+	clinics         ClinicConfigGetter
+	timeSelections  *conversation.TimeSelectionReader
+	coldStore       ConversationRehydrator
+	audit           *compliance.AuditService
 	logger          *logging.Logger
+	now             func() time.Time
 }
 
 // NewAdminConversationsHandler creates a new admin conversations handler.
@@ -28,9 +37,35 @@ func NewAdminConversationsHandler(db *sql.DB, transcriptStore *conversation.SMST
 		db:              db,
 		transcriptStore: transcriptStore,
 		logger:          logger,
+		now:             time.Now,
 	}
 }
 
+// SetQualificationSources lets conversation detail include the qualification
+// snapshot with clinic-specific service resolution and presented slots.
+func (h *AdminConversationsHandler) SetQualificationSources(clinics ClinicConfigGetter, timeSelections *conversation.TimeSelectionReader) {
+	h.clinics = clinics
+	h.timeSelections = timeSelections
+}
+
+// ConversationRehydrator loads the messages of conversations moved to cold
+// storage. cached reports whether the result came from memory rather than S3.
+type ConversationRehydrator interface {
+	Rehydrate(ctx context.Context, archiveKey, conversationID string) (conv *clinicdata.ColdConversation, cached bool, err error)
+}
+
+// SetColdStore lets conversation reads restore messages the cold-storage job
+// moved to S3. Without it archived conversations show only their hot messages.
+func (h *AdminConversationsHandler) SetColdStore(store ConversationRehydrator) {
+	h.coldStore = store
+}
+
+// SetAuditService lets transcript exports reveal the lead's name and phone,
+// recording each unmasked export as a compliance audit event.
+func (h *AdminConversationsHandler) SetAuditService(audit *compliance.AuditService) {
+	h.audit = audit
+}
+
 // ConversationListItem represents a conversation in list responses.
 type ConversationListItem struct {
 	ID                   string  `json:"id"`
@@ -67,12 +102,45 @@ type ConversationDetailResponse struct {
 	LastMessageAt *string           `json:"last_message_at,omitempty"`
 	Messages      []MessageResponse `json:"messages"`
 	Metadata      ConversationMeta  `json:"metadata"`
+
+	// Qualification is what the assistant has captured so far and what it
+	// still needs, derived with the worker's own extraction.
+	Qualification *conversation.QualificationSnapshot `json:"qualification,omitempty"`
+
+	// ColdStorage is set when older messages were archived to S3.
+	ColdStorage *ColdStorageStatus `json:"cold_storage,omitempty"`
+
+	// TurnLatency breaks down how long the assistant took to answer the
+	// most recent inbound texts, newest first.
+	TurnLatency []TurnLatencyResponse `json:"turn_latency,omitempty"`
+}
+
+// TurnLatencyResponse is one reply's latency by stage, in milliseconds.
+type TurnLatencyResponse struct {
+	JobID              string           `json:"job_id"`
+	ReceivedAt         string           `json:"received_at"`
+	TotalMS            int64            `json:"total_ms"`
+	StagesMS           map[string]int64 `json:"stages_ms"`
+	AvailabilitySearch bool             `json:"availability_search"`
+	OverBudget         bool             `json:"over_budget"`
+}
+
+// ColdStorageStatus reports how a conversation's archived messages were
+// restored. LatencyWarning is set when they had to be fetched from S3.
+type ColdStorageStatus struct {
+	ArchivedAt     string `json:"archived_at"`
+	Rehydrated     bool   `json:"rehydrated"`
+	Cached         bool   `json:"cached"`
+	LatencyWarning string `json:"latency_warning,omitempty"`
+	Error          string `json:"error,omitempty"`
 }
 
 // MessageResponse represents a message in a conversation.
 type MessageResponse struct {
 	ID                string `json:"id"`
 	Role              string `json:"role"`
+	Direction         string `json:"direction"`
+	AuthorType        string `json:"author_type"`
 	Content           string `json:"content"`
 	Timestamp         string `json:"timestamp"`
 	From              string `json:"from,omitempty"`
@@ -82,12 +150,34 @@ type MessageResponse struct {
 	ErrorReason       string `json:"error_reason,omitempty"`
 }
 
+// resolveAuthor fills Direction and AuthorType, inferring them from Role and
+// kind for messages stored before they were recorded.
+func (m *MessageResponse) resolveAuthor(kind string) {
+	dir, author := msgschema.Message{Direction: m.Direction, AuthorType: m.AuthorType, Role: m.Role, Kind: kind}.Resolve()
+	m.Direction, m.AuthorType = string(dir), string(author)
+}
+
+// authorLabel names a message's author in exported transcripts.
+func authorLabel(author string) string {
+	switch msgschema.Author(author) {
+	case msgschema.Patient:
+		return "Customer"
+	case msgschema.AI:
+		return "AI"
+	case msgschema.Staff:
+		return "Staff"
+	case msgschema.System:
+		return "System"
+	}
+	return author
+}
+
 // ConversationMeta contains metadata about a conversation.
 type ConversationMeta struct {
 	TotalMessages    int    `json:"total_messages"`
 	CustomerMessages int    `json:"customer_messages"`
 	AIMessages       int    `json:"ai_messages"`
-	Source           string `json:"source"` // "database" or "redis"
+	Source           string `json:"source"` // "database", "cold_storage" or "redis"
 }
 
 // ConversationStatsResponse contains aggregated conversation statistics.
@@ -174,7 +264,7 @@ func (h *AdminConversationsHandler) hasConversationsForOrg(ctx *http.Request, or
 // getMessagesFromDB retrieves conversation messages from the database ordered by creation time.
 func (h *AdminConversationsHandler) getMessagesFromDB(r *http.Request, conversationID string) ([]MessageResponse, error) {
 	rows, err := h.db.QueryContext(r.Context(), `
-		SELECT id, role, content, from_phone, to_phone, provider_message_id, status, error_reason, created_at
+		SELECT id, role, direction, author_type, kind, content, from_phone, to_phone, provider_message_id, status, error_reason, created_at
 		FROM conversation_messages
 		WHERE conversation_id = $1
 		ORDER BY created_at ASC
@@ -189,12 +279,15 @@ func (h *AdminConversationsHandler) getMessagesFromDB(r *http.Request, conversat
 		var msg MessageResponse
 		var fromPhone, toPhone sql.NullString
 		var providerMessageID, status, errorReason sql.NullString
+		var direction, authorType, kind sql.NullString
 		var createdAt time.Time
 
-		if err := rows.Scan(&msg.ID, &msg.Role, &msg.Content, &fromPhone, &toPhone, &providerMessageID, &status, &errorReason, &createdAt); err != nil {
+		if err := rows.Scan(&msg.ID, &msg.Role, &direction, &authorType, &kind, &msg.Content, &fromPhone, &toPhone, &providerMessageID, &status, &errorReason, &createdAt); err != nil {
 			continue
 		}
 
+		msg.Direction, msg.AuthorType = direction.String, authorType.String
+		msg.resolveAuthor(kind.String)
 		msg.Timestamp = formatTimeEastern(createdAt)
 		if providerMessageID.Valid {
 			msg.ProviderMessageID = providerMessageID.String
@@ -212,3 +305,31 @@ func (h *AdminConversationsHandler) getMessagesFromDB(r *http.Request, conversat
 
 	return messages, nil
 }
+
+// ConversationSearchResponse lists conversations whose messages match a search.
+type ConversationSearchResponse struct {
+	Query   string                   `json:"query"`
+	Results []ConversationSearchItem `json:"results"`
+	Total   int                      `json:"total"`
+}
+
+// ConversationSearchItem is one matching conversation with highlighted
+// snippets; the text is HTML-escaped and matched terms are wrapped in <mark> tags.
+type ConversationSearchItem struct {
+	ID            string                `json:"id"`
+	Channel       string                `json:"channel"`
+	CustomerPhone string                `json:"customer_phone"`
+	CustomerName  string                `json:"customer_name"`
+	Status        string                `json:"status"`
+	MatchCount    int                   `json:"match_count"`
+	LastMatchAt   string                `json:"last_match_at"`
+	Snippets      []SearchSnippetResult `json:"snippets"`
+}
+
+// SearchSnippetResult is a highlighted excerpt of one matching message.
+type SearchSnippetResult struct {
+	MessageID string `json:"message_id"`
+	Role      string `json:"role"`
+	Snippet   string `json:"snippet"`
+	Timestamp string `json:"timestamp"`
+}
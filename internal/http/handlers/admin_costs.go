@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/wolfman30/medspa-ai-platform/internal/costs"
+	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
+)
+
+// DailyCostSource loads rolled-up per-conversation costs.
+type DailyCostSource interface {
+	Daily(ctx context.Context, orgID string, from, to time.Time) ([]costs.DailyCost, error)
+}
+
+// AdminCostsHandler serves a clinic's combined SMS and LLM spend.
+type AdminCostsHandler struct {
+	store  DailyCostSource
+	logger *logging.Logger
+	now    func() time.Time
+}
+
+// NewAdminCostsHandler creates a new costs handler.
+func NewAdminCostsHandler(store DailyCostSource, logger *logging.Logger) *AdminCostsHandler {
+	if logger == nil {
+		logger = logging.Default()
+	}
+	return &AdminCostsHandler{store: store, logger: logger, now: time.Now}
+}
+
+// Get handles GET /admin/orgs/{orgID}/costs?period=day|week|month
+// Returns SMS and LLM spend totals, a per-day breakdown and the most
+// expensive conversations. Figures come from the hourly rollup, so today's
+// numbers lag by up to one rollup interval.
+func (h *AdminCostsHandler) Get(w http.ResponseWriter, r *http.Request) {
+	if h == nil || h.store == nil {
+		http.Error(w, "database not configured", http.StatusServiceUnavailable)
+		return
+	}
+	orgID := strings.TrimSpace(chi.URLParam(r, "orgID"))
+	if orgID == "" {
+		http.Error(w, "missing orgID", http.StatusBadRequest)
+		return
+	}
+	period := strings.ToLower(strings.TrimSpace(r.URL.Query().Get("period")))
+	if period == "" {
+		period = costs.DefaultPeriod
+	}
+	from, to, err := costs.PeriodRange(period, h.now())
+	if err != nil {
+		http.Error(w, "invalid period: must be day|week|month", http.StatusBadRequest)
+		return
+	}
+
+	rows, err := h.store.Daily(r.Context(), orgID, from, to)
+	if err != nil {
+		h.logger.Error("costs: load daily costs failed", "error", err, "org_id", orgID)
+		http.Error(w, "failed to load costs", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, costs.BuildReport(orgID, period, from, to, rows))
+}
@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/wolfman30/medspa-ai-platform/internal/costs"
+	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
+)
+
+type memDailyCosts struct {
+	rows     []costs.DailyCost
+	from, to time.Time
+}
+
+func (m *memDailyCosts) Daily(ctx context.Context, orgID string, from, to time.Time) ([]costs.DailyCost, error) {
+	m.from, m.to = from, to
+	var out []costs.DailyCost
+	for _, r := range m.rows {
+		if r.OrgID == orgID {
+			out = append(out, r)
+		}
+	}
+	return out, nil
+}
+
+func newCostsRequest(orgID, query string) *http.Request {
+	req := httptest.NewRequest(http.MethodGet, "/admin/orgs/"+orgID+"/costs"+query, nil)
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("orgID", orgID)
+	return req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+}
+
+func TestAdminCosts_Get(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	today := time.Date(2026, 3, 10, 0, 0, 0, 0, time.UTC)
+	store := &memDailyCosts{rows: []costs.DailyCost{
+		{OrgID: "org-1", Day: today, ConversationID: "sms:org-1:15550001111", Messages: 3, Segments: 4, SMSCostUSD: 0.016, LLMCalls: 3, LLMCostUSD: 0.02},
+		{OrgID: "org-2", Day: today, ConversationID: "sms:org-2:15550002222", Messages: 1, SMSCostUSD: 0.004},
+	}}
+	h := NewAdminCostsHandler(store, logging.Default())
+	h.now = func() time.Time { return now }
+
+	rec := httptest.NewRecorder()
+	h.Get(rec, newCostsRequest("org-1", "?period=week"))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if !store.from.Equal(today.AddDate(0, 0, -6)) || !store.to.Equal(today.AddDate(0, 0, 1)) {
+		t.Fatalf("loaded %v..%v, want the last 7 days", store.from, store.to)
+	}
+	var report costs.Report
+	if err := json.NewDecoder(rec.Body).Decode(&report); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if report.Period != "week" || report.SMS.Messages != 3 || report.TotalCostUSD != 0.036 || report.Conversations != 1 || len(report.Days) != 7 {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+}
+
+func TestAdminCosts_Get_DefaultsAndValidation(t *testing.T) {
+	h := NewAdminCostsHandler(&memDailyCosts{}, logging.Default())
+
+	rec := httptest.NewRecorder()
+	h.Get(rec, newCostsRequest("org-1", ""))
+	var report costs.Report
+	if err := json.NewDecoder(rec.Body).Decode(&report); err != nil || report.Period != costs.DefaultPeriod {
+		t.Fatalf("expected the default period, got %q (%v)", report.Period, err)
+	}
+
+	rec = httptest.NewRecorder()
+	h.Get(rec, newCostsRequest("org-1", "?period=decade"))
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for an unknown period, got %d", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	NewAdminCostsHandler(nil, nil).Get(rec, newCostsRequest("org-1", ""))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503 without a store, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,104 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/wolfman30/medspa-ai-platform/internal/crmhooks"
+	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
+)
+
+const (
+	defaultCRMDeadLetterLimit = 50
+	maxCRMDeadLetterLimit     = 500
+)
+
+// CRMDeadLetterStore lists and requeues CRM webhook deliveries that ran out
+// of attempts.
+type CRMDeadLetterStore interface {
+	DeadLetters(ctx context.Context, orgID string, limit int) ([]crmhooks.Delivery, error)
+	Retry(ctx context.Context, orgID string, id uuid.UUID) error
+}
+
+// AdminCRMWebhooksHandler serves a clinic's dead-lettered CRM webhook deliveries.
+type AdminCRMWebhooksHandler struct {
+	store  CRMDeadLetterStore
+	logger *logging.Logger
+}
+
+// NewAdminCRMWebhooksHandler creates a new CRM webhooks handler.
+func NewAdminCRMWebhooksHandler(store CRMDeadLetterStore, logger *logging.Logger) *AdminCRMWebhooksHandler {
+	if logger == nil {
+		logger = logging.Default()
+	}
+	return &AdminCRMWebhooksHandler{store: store, logger: logger}
+}
+
+// crmDeadLettersResponse is the body of GET crm-webhooks/dead-letters.
+type crmDeadLettersResponse struct {
+	OrgID      string              `json:"org_id"`
+	Deliveries []crmhooks.Delivery `json:"deliveries"`
+}
+
+// ListDeadLetters handles GET /admin/clinics/{orgID}/crm-webhooks/dead-letters
+// Returns deliveries that exhausted their retries, newest first, with the
+// last status code and error. Pass ?limit=N (max 500).
+func (h *AdminCRMWebhooksHandler) ListDeadLetters(w http.ResponseWriter, r *http.Request) {
+	if h == nil || h.store == nil {
+		http.Error(w, "database not configured", http.StatusServiceUnavailable)
+		return
+	}
+	orgID := strings.TrimSpace(chi.URLParam(r, "orgID"))
+	if orgID == "" {
+		http.Error(w, "missing orgID", http.StatusBadRequest)
+		return
+	}
+	limit := defaultCRMDeadLetterLimit
+	if raw := r.URL.Query().Get("limit"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed < 1 || parsed > maxCRMDeadLetterLimit {
+			http.Error(w, "limit must be between 1 and 500", http.StatusBadRequest)
+			return
+		}
+		limit = parsed
+	}
+
+	deliveries, err := h.store.DeadLetters(r.Context(), orgID, limit)
+	if err != nil {
+		h.logger.Error("crm webhooks: list dead letters failed", "error", err, "org_id", orgID)
+		http.Error(w, "failed to load dead letters", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, crmDeadLettersResponse{OrgID: orgID, Deliveries: deliveries})
+}
+
+// RetryDeadLetter handles POST /admin/clinics/{orgID}/crm-webhooks/dead-letters/{deliveryID}/retry
+// Puts the delivery back in the queue with a fresh set of attempts.
+func (h *AdminCRMWebhooksHandler) RetryDeadLetter(w http.ResponseWriter, r *http.Request) {
+	if h == nil || h.store == nil {
+		http.Error(w, "database not configured", http.StatusServiceUnavailable)
+		return
+	}
+	orgID := strings.TrimSpace(chi.URLParam(r, "orgID"))
+	id, err := uuid.Parse(chi.URLParam(r, "deliveryID"))
+	if orgID == "" || err != nil {
+		http.Error(w, "invalid orgID or deliveryID", http.StatusBadRequest)
+		return
+	}
+	if err := h.store.Retry(r.Context(), orgID, id); err != nil {
+		if errors.Is(err, crmhooks.ErrDeliveryNotFound) {
+			http.Error(w, "dead-lettered delivery not found", http.StatusNotFound)
+			return
+		}
+		h.logger.Error("crm webhooks: retry failed", "error", err, "org_id", orgID, "delivery_id", id)
+		http.Error(w, "failed to retry delivery", http.StatusInternalServerError)
+		return
+	}
+	h.logger.Info("crm webhooks: dead letter requeued", "org_id", orgID, "delivery_id", id)
+	writeJSON(w, http.StatusAccepted, map[string]string{"status": crmhooks.StatusPending, "delivery_id": id.String()})
+}
@@ -0,0 +1,114 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/wolfman30/medspa-ai-platform/internal/crmhooks"
+	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
+)
+
+type memCRMDeadLetters struct {
+	rows  []crmhooks.Delivery
+	limit int
+}
+
+func (m *memCRMDeadLetters) DeadLetters(_ context.Context, orgID string, limit int) ([]crmhooks.Delivery, error) {
+	m.limit = limit
+	out := []crmhooks.Delivery{}
+	for _, d := range m.rows {
+		if d.OrgID == orgID && d.Status == crmhooks.StatusDead {
+			out = append(out, d)
+		}
+	}
+	return out, nil
+}
+
+func (m *memCRMDeadLetters) Retry(_ context.Context, orgID string, id uuid.UUID) error {
+	for i := range m.rows {
+		if m.rows[i].ID == id && m.rows[i].OrgID == orgID && m.rows[i].Status == crmhooks.StatusDead {
+			m.rows[i].Status = crmhooks.StatusPending
+			m.rows[i].Attempts = 0
+			return nil
+		}
+	}
+	return crmhooks.ErrDeliveryNotFound
+}
+
+func newCRMWebhooksRequest(method, target string, params map[string]string) *http.Request {
+	req := httptest.NewRequest(method, target, nil)
+	rctx := chi.NewRouteContext()
+	for k, v := range params {
+		rctx.URLParams.Add(k, v)
+	}
+	return req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+}
+
+func TestAdminCRMWebhooks_ListAndRetryDeadLetters(t *testing.T) {
+	deadAt := time.Date(2026, 3, 2, 16, 0, 0, 0, time.UTC)
+	dead := crmhooks.Delivery{
+		ID: uuid.New(), OrgID: "org-1", Endpoint: "hubspot", EventType: crmhooks.EventDepositPaid,
+		Status: crmhooks.StatusDead, Attempts: 8, LastStatusCode: 500, LastError: "post hubspot (api.hubapi.com): status 500",
+		DeadLetteredAt: &deadAt, Payload: json.RawMessage(`{}`),
+	}
+	store := &memCRMDeadLetters{rows: []crmhooks.Delivery{
+		dead,
+		{ID: uuid.New(), OrgID: "org-1", Endpoint: "hubspot", Status: crmhooks.StatusDelivered, Payload: json.RawMessage(`{}`)},
+		{ID: uuid.New(), OrgID: "org-2", Endpoint: "hubspot", Status: crmhooks.StatusDead, Payload: json.RawMessage(`{}`)},
+	}}
+	h := NewAdminCRMWebhooksHandler(store, logging.Default())
+
+	rec := httptest.NewRecorder()
+	h.ListDeadLetters(rec, newCRMWebhooksRequest(http.MethodGet, "/admin/clinics/org-1/crm-webhooks/dead-letters?limit=10", map[string]string{"orgID": "org-1"}))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var body struct {
+		Deliveries []crmhooks.Delivery `json:"deliveries"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(body.Deliveries) != 1 || body.Deliveries[0].ID != dead.ID || body.Deliveries[0].LastStatusCode != 500 {
+		t.Fatalf("deliveries = %+v", body.Deliveries)
+	}
+	if store.limit != 10 {
+		t.Errorf("limit = %d, want 10", store.limit)
+	}
+
+	rec = httptest.NewRecorder()
+	h.RetryDeadLetter(rec, newCRMWebhooksRequest(http.MethodPost, "/", map[string]string{"orgID": "org-1", "deliveryID": dead.ID.String()}))
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("expected 202, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if store.rows[0].Status != crmhooks.StatusPending || store.rows[0].Attempts != 0 {
+		t.Fatalf("delivery not requeued: %+v", store.rows[0])
+	}
+
+	// Another org's delivery, or one already requeued, is not found.
+	for _, params := range []map[string]string{
+		{"orgID": "org-1", "deliveryID": store.rows[2].ID.String()},
+		{"orgID": "org-1", "deliveryID": dead.ID.String()},
+	} {
+		rec = httptest.NewRecorder()
+		h.RetryDeadLetter(rec, newCRMWebhooksRequest(http.MethodPost, "/", params))
+		if rec.Code != http.StatusNotFound {
+			t.Fatalf("expected 404 for %v, got %d", params, rec.Code)
+		}
+	}
+}
+
+func TestAdminCRMWebhooks_ListRejectsBadLimit(t *testing.T) {
+	h := NewAdminCRMWebhooksHandler(&memCRMDeadLetters{}, logging.Default())
+	rec := httptest.NewRecorder()
+	h.ListDeadLetters(rec, newCRMWebhooksRequest(http.MethodGet, "/admin/clinics/org-1/crm-webhooks/dead-letters?limit=0", map[string]string{"orgID": "org-1"}))
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", rec.Code)
+	}
+}
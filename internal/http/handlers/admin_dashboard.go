@@ -9,6 +9,8 @@ import (
 	"github.com/go-chi/chi/v5"
 
 	"github.com/wolfman30/medspa-ai-platform/internal/clinic"
+	"github.com/wolfman30/medspa-ai-platform/internal/clinicdata"
+	"github.com/wolfman30/medspa-ai-platform/internal/compliance"
 	"github.com/wolfman30/medspa-ai-platform/internal/conversation"
 	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
 )
@@ -136,18 +138,18 @@ func (h *AdminDashboardHandler) GetDashboardOverview(w http.ResponseWriter, r *h
 
 	// Lead metrics
 	h.db.QueryRowContext(r.Context(),
-		`SELECT COUNT(*) FROM leads WHERE org_id = $1`, orgID,
+		`SELECT COUNT(*) FROM leads WHERE org_id = $1 AND NOT probable_wrong_number`, orgID,
 	).Scan(&dashboard.Leads.Total)
 
 	h.db.QueryRowContext(r.Context(),
-		`SELECT COUNT(*) FROM leads WHERE org_id = $1 AND created_at >= $2`, orgID, weekAgo,
+		`SELECT COUNT(*) FROM leads WHERE org_id = $1 AND NOT probable_wrong_number AND created_at >= $2`, orgID, weekAgo,
 	).Scan(&dashboard.Leads.NewThisWeek)
 
 	var converted int
 	h.db.QueryRowContext(r.Context(),
 		`SELECT COUNT(DISTINCT l.id) FROM leads l
 		 JOIN payments p ON l.id = p.lead_id
-		 WHERE l.org_id = $1 AND p.status = 'succeeded'`, orgID,
+		 WHERE l.org_id = $1 AND NOT l.probable_wrong_number AND p.status = 'succeeded'`, orgID,
 	).Scan(&converted)
 	if dashboard.Leads.Total > 0 {
 		dashboard.Leads.ConversionRate = float64(converted) / float64(dashboard.Leads.Total) * 100
@@ -407,11 +409,23 @@ func (h *AdminDashboardHandler) ListOrganizations(w http.ResponseWriter, r *http
 }
 
 // RegisterAdminRoutes registers all admin dashboard routes.
-func RegisterAdminRoutes(r chi.Router, db *sql.DB, transcriptStore *conversation.SMSTranscriptStore, clinicStore *clinic.Store, logger *logging.Logger) {
+func RegisterAdminRoutes(r chi.Router, db *sql.DB, transcriptStore *conversation.SMSTranscriptStore, timeSelections *conversation.TimeSelectionReader, clinicStore *clinic.Store, coldStore *clinicdata.ColdStore, audit *compliance.AuditService, crmEvents conversation.CRMEventPublisher, logger *logging.Logger) {
 	dashboardHandler := NewAdminDashboardHandler(db, logger)
 	leadsHandler := NewAdminLeadsHandler(db, logger)
 	conversationsHandler := NewAdminConversationsHandler(db, transcriptStore, logger)
+	if clinicStore != nil {
+		conversationsHandler.SetQualificationSources(clinicStore, timeSelections)
+	}
+	if coldStore != nil {
+		conversationsHandler.SetColdStore(coldStore)
+	}
+	if audit != nil {
+		conversationsHandler.SetAuditService(audit)
+	}
 	depositsHandler := NewAdminDepositsHandler(db, logger)
+	if crmEvents != nil {
+		depositsHandler.SetCRMEvents(crmEvents)
+	}
 	notificationsHandler := NewAdminNotificationsHandler(clinicStore, logger)
 
 	// List all organizations (admin only)
@@ -430,13 +444,18 @@ func RegisterAdminRoutes(r chi.Router, db *sql.DB, transcriptStore *conversation
 		// Conversations
 		r.Get("/conversations", conversationsHandler.ListConversations)
 		r.Get("/conversations/stats", conversationsHandler.GetConversationStats)
+		r.Get("/conversations/search", conversationsHandler.SearchConversations)
 		r.Get("/conversations/{conversationID}", conversationsHandler.GetConversation)
 		r.Get("/conversations/{conversationID}/export", conversationsHandler.ExportTranscript)
+		r.Get("/conversations/{conversationID}/transcript.pdf", conversationsHandler.ExportTranscriptPDF)
 
 		// Deposits
 		r.Get("/deposits", depositsHandler.ListDeposits)
 		r.Get("/deposits/stats", depositsHandler.GetDepositStats)
 		r.Get("/deposits/{depositID}", depositsHandler.GetDeposit)
+		r.Post("/deposits/{depositID}/apply", depositsHandler.ApplyDeposit)
+		r.Post("/bookings/{bookingID}/no-show", depositsHandler.MarkBookingNoShow)
+		r.Post("/bookings/{bookingID}/cancel", depositsHandler.CancelBooking)
 
 		// Notifications
 		r.Get("/notifications", notificationsHandler.GetNotificationSettings)
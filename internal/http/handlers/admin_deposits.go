@@ -9,13 +9,18 @@ import (
 	"time"
 
 	"github.com/go-chi/chi/v5"
+	"github.com/wolfman30/medspa-ai-platform/internal/conversation"
+	"github.com/wolfman30/medspa-ai-platform/internal/crmhooks"
+	"github.com/wolfman30/medspa-ai-platform/internal/payments"
+	"github.com/wolfman30/medspa-ai-platform/internal/pii"
 	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
 )
 
 // AdminDepositsHandler handles admin API endpoints for deposit/payment viewing.
 type AdminDepositsHandler struct {
-	db     *sql.DB
-	logger *logging.Logger
+	db        *sql.DB
+	logger    *logging.Logger
+	crmEvents conversation.CRMEventPublisher
 }
 
 // NewAdminDepositsHandler creates a new admin deposits handler.
@@ -29,6 +34,12 @@ func NewAdminDepositsHandler(db *sql.DB, logger *logging.Logger) *AdminDepositsH
 	}
 }
 
+// SetCRMEvents publishes booking.cancelled to the clinic's CRM webhooks when
+// staff cancel a booking.
+func (h *AdminDepositsHandler) SetCRMEvents(publisher conversation.CRMEventPublisher) {
+	h.crmEvents = publisher
+}
+
 // DepositListItem represents a deposit in list responses.
 type DepositListItem struct {
 	ID              string  `json:"id"`
@@ -45,6 +56,8 @@ type DepositListItem struct {
 	ProviderRef     *string `json:"provider_ref,omitempty"`
 	ScheduledFor    *string `json:"scheduled_for,omitempty"`
 	CreatedAt       string  `json:"created_at"`
+	AppliedAt       *string `json:"applied_at,omitempty"`
+	AppliedBy       *string `json:"applied_by,omitempty"`
 }
 
 // DepositsListResponse represents a paginated list of deposits.
@@ -76,6 +89,8 @@ type DepositDetailResponse struct {
 	BookingIntentID *string `json:"booking_intent_id,omitempty"`
 	ScheduledFor    *string `json:"scheduled_for,omitempty"`
 	CreatedAt       string  `json:"created_at"`
+	AppliedAt       *string `json:"applied_at,omitempty"`
+	AppliedBy       *string `json:"applied_by,omitempty"`
 	ConversationID  *string `json:"conversation_id,omitempty"`
 }
 
@@ -116,6 +131,8 @@ func (h *AdminDepositsHandler) ListDeposits(w http.ResponseWriter, r *http.Reque
 	dateTo := r.URL.Query().Get("date_to")
 	phone := strings.TrimSpace(r.URL.Query().Get("phone"))
 	phoneDigits := phoneDigitsCandidates(phone)
+	// applied=false lists paid deposits not yet credited toward the service.
+	applied := r.URL.Query().Get("applied")
 	// Hide stale deposit_pending records by default (ones where a newer succeeded payment exists for the same lead)
 	hideStalePending := r.URL.Query().Get("hide_stale_pending") != "false"
 
@@ -123,7 +140,7 @@ func (h *AdminDepositsHandler) ListDeposits(w http.ResponseWriter, r *http.Reque
 
 	query := `
 		SELECT p.id, p.org_id, p.lead_id, p.amount_cents, p.status, p.provider,
-		       p.provider_ref, p.scheduled_for, p.created_at,
+		       p.provider_ref, p.scheduled_for, p.created_at, p.applied_at, p.applied_by,
 		       l.phone, l.name, l.email, l.service_interest, l.patient_type
 		FROM payments p
 		LEFT JOIN leads l ON p.lead_id = l.id
@@ -165,8 +182,9 @@ func (h *AdminDepositsHandler) ListDeposits(w http.ResponseWriter, r *http.Reque
 		}
 	}
 	if len(phoneDigits) > 0 {
-		query += appendPhoneDigitsFilter("regexp_replace(l.phone, '\\\\D', '', 'g')", phoneDigits, &args, &argNum)
+		query += appendLeadPhoneFilter("l", phoneDigits, &args, &argNum)
 	}
+	query += depositAppliedFilter(applied)
 
 	query += " ORDER BY p.created_at DESC"
 
@@ -195,8 +213,9 @@ func (h *AdminDepositsHandler) ListDeposits(w http.ResponseWriter, r *http.Reque
 		countArgNum++
 	}
 	if len(phoneDigits) > 0 {
-		countQuery += appendPhoneDigitsFilter("regexp_replace(l.phone, '\\\\D', '', 'g')", phoneDigits, &countArgs, &countArgNum)
+		countQuery += appendLeadPhoneFilter("l", phoneDigits, &countArgs, &countArgNum)
 	}
+	countQuery += depositAppliedFilter(applied)
 	var total int
 	h.db.QueryRowContext(r.Context(), countQuery, countArgs...).Scan(&total)
 
@@ -218,11 +237,12 @@ func (h *AdminDepositsHandler) ListDeposits(w http.ResponseWriter, r *http.Reque
 		var leadID, providerRef, scheduledFor sql.NullString
 		var phone, name, email, serviceInterest, patientType sql.NullString
 		var createdAt time.Time
-		var scheduledTime sql.NullTime
+		var scheduledTime, appliedAt sql.NullTime
+		var appliedBy sql.NullString
 
 		err := rows.Scan(
 			&d.ID, &d.OrgID, &leadID, &d.AmountCents, &d.Status, &d.Provider,
-			&providerRef, &scheduledTime, &createdAt,
+			&providerRef, &scheduledTime, &createdAt, &appliedAt, &appliedBy,
 			&phone, &name, &email, &serviceInterest, &patientType,
 		)
 		if err != nil {
@@ -242,13 +262,16 @@ func (h *AdminDepositsHandler) ListDeposits(w http.ResponseWriter, r *http.Reque
 			d.ScheduledFor = &formatted
 			scheduledFor.String = formatted
 		}
+		d.AppliedAt, d.AppliedBy = depositApplication(appliedAt, appliedBy)
 		if phone.Valid {
-			d.LeadPhone = phone.String
+			d.LeadPhone = pii.Reveal(phone.String)
 		}
 		if name.Valid {
+			name.String = pii.Reveal(name.String)
 			d.LeadName = &name.String
 		}
 		if email.Valid {
+			email.String = pii.Reveal(email.String)
 			d.LeadEmail = &email.String
 		}
 		if serviceInterest.Valid {
@@ -292,6 +315,7 @@ func (h *AdminDepositsHandler) GetDeposit(w http.ResponseWriter, r *http.Request
 	query := `
 		SELECT p.id, p.org_id, p.lead_id, p.amount_cents, p.status, p.provider,
 		       p.provider_ref, p.booking_intent_id, p.scheduled_for, p.created_at,
+		       p.applied_at, p.applied_by,
 		       l.phone, l.name, l.email, l.service_interest, l.patient_type,
 		       l.preferred_days, l.preferred_times, l.scheduling_notes
 		FROM payments p
@@ -304,11 +328,13 @@ func (h *AdminDepositsHandler) GetDeposit(w http.ResponseWriter, r *http.Request
 	var phone, name, email, serviceInterest, patientType sql.NullString
 	var preferredDays, preferredTimes, schedulingNotes sql.NullString
 	var createdAt time.Time
-	var scheduledTime sql.NullTime
+	var scheduledTime, appliedAt sql.NullTime
+	var appliedBy sql.NullString
 
 	err := h.db.QueryRowContext(r.Context(), query, depositID, orgID).Scan(
 		&d.ID, &d.OrgID, &leadID, &d.AmountCents, &d.Status, &d.Provider,
 		&providerRef, &bookingIntentID, &scheduledTime, &createdAt,
+		&appliedAt, &appliedBy,
 		&phone, &name, &email, &serviceInterest, &patientType,
 		&preferredDays, &preferredTimes, &schedulingNotes,
 	)
@@ -337,13 +363,16 @@ func (h *AdminDepositsHandler) GetDeposit(w http.ResponseWriter, r *http.Request
 		formatted := scheduledTime.Time.Format(time.RFC3339)
 		d.ScheduledFor = &formatted
 	}
+	d.AppliedAt, d.AppliedBy = depositApplication(appliedAt, appliedBy)
 	if phone.Valid {
-		d.LeadPhone = phone.String
+		d.LeadPhone = pii.Reveal(phone.String)
 	}
 	if name.Valid {
+		name.String = pii.Reveal(name.String)
 		d.LeadName = &name.String
 	}
 	if email.Valid {
+		email.String = pii.Reveal(email.String)
 		d.LeadEmail = &email.String
 	}
 	if serviceInterest.Valid {
@@ -383,6 +412,225 @@ func (h *AdminDepositsHandler) GetDeposit(w http.ResponseWriter, r *http.Request
 	json.NewEncoder(w).Encode(d)
 }
 
+// DepositApplicationResponse reports that a deposit has been credited toward
+// the patient's service.
+type DepositApplicationResponse struct {
+	ID             string `json:"id"`
+	Status         string `json:"status"`
+	AppliedAt      string `json:"applied_at"`
+	AppliedBy      string `json:"applied_by"`
+	AlreadyApplied bool   `json:"already_applied"`
+}
+
+// ApplyDeposit marks a paid deposit as applied to the patient's service,
+// recording who applied it. Applying a deposit twice keeps the first record.
+// POST /admin/orgs/{orgID}/deposits/{depositID}/apply
+func (h *AdminDepositsHandler) ApplyDeposit(w http.ResponseWriter, r *http.Request) {
+	orgID := chi.URLParam(r, "orgID")
+	depositID := chi.URLParam(r, "depositID")
+	if orgID == "" || depositID == "" {
+		jsonError(w, "missing orgID or depositID", http.StatusBadRequest)
+		return
+	}
+
+	kind, actor := auditActor(r)
+	appliedBy := kind
+	if actor != "" {
+		appliedBy = actor
+	}
+
+	resp := DepositApplicationResponse{ID: depositID}
+	var appliedAt time.Time
+	err := h.db.QueryRowContext(r.Context(), `
+		UPDATE payments SET applied_at = now(), applied_by = $3
+		WHERE id = $1 AND org_id = $2 AND status = 'succeeded' AND applied_at IS NULL
+		RETURNING status, applied_at, applied_by`, depositID, orgID, appliedBy,
+	).Scan(&resp.Status, &appliedAt, &resp.AppliedBy)
+	if err == sql.ErrNoRows {
+		h.explainUnappliedDeposit(w, r, orgID, depositID)
+		return
+	}
+	if err != nil {
+		h.logger.Error("failed to apply deposit", "error", err, "org_id", orgID, "deposit_id", depositID)
+		jsonError(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+	resp.AppliedAt = appliedAt.Format(time.RFC3339)
+	h.logger.Info("deposit marked applied", "org_id", orgID, "deposit_id", depositID, "applied_by", appliedBy)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// explainUnappliedDeposit answers an apply request that changed nothing: the
+// deposit is missing, not paid, or was already applied.
+func (h *AdminDepositsHandler) explainUnappliedDeposit(w http.ResponseWriter, r *http.Request, orgID, depositID string) {
+	resp := DepositApplicationResponse{ID: depositID}
+	var appliedAt sql.NullTime
+	var appliedBy sql.NullString
+	err := h.db.QueryRowContext(r.Context(),
+		`SELECT status, applied_at, applied_by FROM payments WHERE id = $1 AND org_id = $2`,
+		depositID, orgID,
+	).Scan(&resp.Status, &appliedAt, &appliedBy)
+	if err == sql.ErrNoRows {
+		jsonError(w, "deposit not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		h.logger.Error("failed to load deposit", "error", err, "org_id", orgID, "deposit_id", depositID)
+		jsonError(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+	if !appliedAt.Valid {
+		jsonError(w, "only paid deposits can be applied", http.StatusConflict)
+		return
+	}
+	resp.AppliedAt = appliedAt.Time.Format(time.RFC3339)
+	resp.AppliedBy = appliedBy.String
+	resp.AlreadyApplied = true
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// BookingNoShowResponse reports a booking marked as a no-show.
+type BookingNoShowResponse struct {
+	ID     string `json:"id"`
+	Status string `json:"status"`
+}
+
+// MarkBookingNoShow records that the patient missed a booking, so its deposit
+// is left for staff to review instead of being applied automatically. Only
+// bookings not yet treated as completed can be marked.
+// POST /admin/orgs/{orgID}/bookings/{bookingID}/no-show
+func (h *AdminDepositsHandler) MarkBookingNoShow(w http.ResponseWriter, r *http.Request) {
+	orgID := chi.URLParam(r, "orgID")
+	bookingID := chi.URLParam(r, "bookingID")
+	if orgID == "" || bookingID == "" {
+		jsonError(w, "missing orgID or bookingID", http.StatusBadRequest)
+		return
+	}
+
+	resp := BookingNoShowResponse{ID: bookingID}
+	err := h.db.QueryRowContext(r.Context(), `
+		UPDATE bookings SET status = $3
+		WHERE id = $1 AND org_id = $2 AND completed_at IS NULL AND status <> 'cancelled'
+		RETURNING status`, bookingID, orgID, payments.BookingStatusNoShow,
+	).Scan(&resp.Status)
+	if err == sql.ErrNoRows {
+		var closed bool
+		err = h.db.QueryRowContext(r.Context(),
+			`SELECT completed_at IS NOT NULL OR status = 'cancelled' FROM bookings WHERE id = $1 AND org_id = $2`,
+			bookingID, orgID,
+		).Scan(&closed)
+		switch {
+		case err == sql.ErrNoRows:
+			jsonError(w, "booking not found", http.StatusNotFound)
+		case err != nil:
+			h.logger.Error("failed to load booking", "error", err, "org_id", orgID, "booking_id", bookingID)
+			jsonError(w, "internal error", http.StatusInternalServerError)
+		default:
+			jsonError(w, "only open bookings can be marked a no-show", http.StatusConflict)
+		}
+		return
+	}
+	if err != nil {
+		h.logger.Error("failed to mark booking no-show", "error", err, "org_id", orgID, "booking_id", bookingID)
+		jsonError(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+	h.logger.Info("booking marked no-show", "org_id", orgID, "booking_id", bookingID)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// BookingCancelResponse reports a cancelled booking.
+type BookingCancelResponse struct {
+	ID     string `json:"id"`
+	Status string `json:"status"`
+}
+
+// CancelBooking cancels a booking the patient or clinic called off and tells
+// the clinic's CRM. Only bookings not yet treated as completed can be
+// cancelled; cancelling one twice is a conflict.
+// POST /admin/orgs/{orgID}/bookings/{bookingID}/cancel
+func (h *AdminDepositsHandler) CancelBooking(w http.ResponseWriter, r *http.Request) {
+	orgID := chi.URLParam(r, "orgID")
+	bookingID := chi.URLParam(r, "bookingID")
+	if orgID == "" || bookingID == "" {
+		jsonError(w, "missing orgID or bookingID", http.StatusBadRequest)
+		return
+	}
+
+	resp := BookingCancelResponse{ID: bookingID}
+	var leadID sql.NullString
+	var scheduledFor sql.NullTime
+	err := h.db.QueryRowContext(r.Context(), `
+		UPDATE bookings SET status = 'cancelled'
+		WHERE id = $1 AND org_id = $2 AND completed_at IS NULL AND status <> 'cancelled'
+		RETURNING status, lead_id::text, scheduled_for`, bookingID, orgID,
+	).Scan(&resp.Status, &leadID, &scheduledFor)
+	if err == sql.ErrNoRows {
+		var closed bool
+		err = h.db.QueryRowContext(r.Context(),
+			`SELECT completed_at IS NOT NULL OR status = 'cancelled' FROM bookings WHERE id = $1 AND org_id = $2`,
+			bookingID, orgID,
+		).Scan(&closed)
+		switch {
+		case err == sql.ErrNoRows:
+			jsonError(w, "booking not found", http.StatusNotFound)
+		case err != nil:
+			h.logger.Error("failed to load booking", "error", err, "org_id", orgID, "booking_id", bookingID)
+			jsonError(w, "internal error", http.StatusInternalServerError)
+		default:
+			jsonError(w, "only open bookings can be cancelled", http.StatusConflict)
+		}
+		return
+	}
+	if err != nil {
+		h.logger.Error("failed to cancel booking", "error", err, "org_id", orgID, "booking_id", bookingID)
+		jsonError(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+	h.logger.Info("booking cancelled", "org_id", orgID, "booking_id", bookingID)
+
+	if h.crmEvents != nil {
+		data := crmhooks.EventData{LeadID: leadID.String, BookingID: bookingID}
+		if scheduledFor.Valid {
+			data.ScheduledFor = &scheduledFor.Time
+		}
+		evt := crmhooks.NewEvent(crmhooks.EventBookingCancelled, orgID, bookingID, time.Now(), data)
+		if err := h.crmEvents.Publish(r.Context(), evt); err != nil {
+			h.logger.Warn("failed to publish CRM event", "error", err, "org_id", orgID, "event", evt.Type, "booking_id", bookingID)
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// depositAppliedFilter narrows a deposit query by the applied query parameter.
+func depositAppliedFilter(applied string) string {
+	switch applied {
+	case "true":
+		return " AND p.applied_at IS NOT NULL"
+	case "false":
+		return " AND p.status = 'succeeded' AND p.applied_at IS NULL"
+	}
+	return ""
+}
+
+// depositApplication formats the applied columns for a response.
+func depositApplication(appliedAt sql.NullTime, appliedBy sql.NullString) (*string, *string) {
+	if !appliedAt.Valid {
+		return nil, nil
+	}
+	at := appliedAt.Time.Format(time.RFC3339)
+	by := appliedBy.String
+	return &at, &by
+}
+
 // GetDepositStats returns aggregated deposit statistics.
 // GET /admin/orgs/{orgID}/deposits/stats
 func (h *AdminDepositsHandler) GetDepositStats(w http.ResponseWriter, r *http.Request) {
@@ -0,0 +1,243 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/wolfman30/medspa-ai-platform/internal/crmhooks"
+	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
+)
+
+func newApplyDepositRequest(depositID string) *http.Request {
+	req := httptest.NewRequest(http.MethodPost, "/admin/orgs/org-1/deposits/"+depositID+"/apply", nil)
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("orgID", "org-1")
+	rctx.URLParams.Add("depositID", depositID)
+	return req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+}
+
+func TestApplyDeposit(t *testing.T) {
+	appliedAt := time.Date(2026, 10, 16, 17, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		expect func(mock sqlmock.Sqlmock)
+		code   int
+		check  func(t *testing.T, resp DepositApplicationResponse)
+	}{
+		{
+			name: "marks a paid deposit applied",
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`UPDATE payments SET applied_at = now\(\), applied_by = \$3\s+WHERE id = \$1 AND org_id = \$2 AND status = 'succeeded' AND applied_at IS NULL`).
+					WithArgs("dep-1", "org-1", "unknown").
+					WillReturnRows(sqlmock.NewRows([]string{"status", "applied_at", "applied_by"}).AddRow("succeeded", appliedAt, "unknown"))
+			},
+			code: http.StatusOK,
+			check: func(t *testing.T, resp DepositApplicationResponse) {
+				assert.Equal(t, appliedAt.Format(time.RFC3339), resp.AppliedAt)
+				assert.False(t, resp.AlreadyApplied)
+			},
+		},
+		{
+			name: "keeps the first application",
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`UPDATE payments`).WillReturnError(sql.ErrNoRows)
+				mock.ExpectQuery(`SELECT status, applied_at, applied_by FROM payments`).
+					WithArgs("dep-1", "org-1").
+					WillReturnRows(sqlmock.NewRows([]string{"status", "applied_at", "applied_by"}).AddRow("succeeded", appliedAt, "frontdesk@clinic.com"))
+			},
+			code: http.StatusOK,
+			check: func(t *testing.T, resp DepositApplicationResponse) {
+				assert.True(t, resp.AlreadyApplied)
+				assert.Equal(t, "frontdesk@clinic.com", resp.AppliedBy)
+			},
+		},
+		{
+			name: "rejects an unpaid deposit",
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`UPDATE payments`).WillReturnError(sql.ErrNoRows)
+				mock.ExpectQuery(`SELECT status, applied_at, applied_by FROM payments`).
+					WillReturnRows(sqlmock.NewRows([]string{"status", "applied_at", "applied_by"}).AddRow("deposit_pending", nil, nil))
+			},
+			code: http.StatusConflict,
+		},
+		{
+			name: "unknown deposit",
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`UPDATE payments`).WillReturnError(sql.ErrNoRows)
+				mock.ExpectQuery(`SELECT status, applied_at, applied_by FROM payments`).WillReturnError(sql.ErrNoRows)
+			},
+			code: http.StatusNotFound,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+			tc.expect(mock)
+
+			rec := httptest.NewRecorder()
+			NewAdminDepositsHandler(db, logging.Default()).ApplyDeposit(rec, newApplyDepositRequest("dep-1"))
+			require.NoError(t, mock.ExpectationsWereMet())
+			require.Equal(t, tc.code, rec.Code, rec.Body.String())
+			if tc.check == nil {
+				return
+			}
+			var resp DepositApplicationResponse
+			require.NoError(t, json.NewDecoder(rec.Body).Decode(&resp))
+			assert.Equal(t, "dep-1", resp.ID)
+			tc.check(t, resp)
+		})
+	}
+}
+
+func TestMarkBookingNoShow(t *testing.T) {
+	newRequest := func() *http.Request {
+		req := httptest.NewRequest(http.MethodPost, "/admin/orgs/org-1/bookings/book-1/no-show", nil)
+		rctx := chi.NewRouteContext()
+		rctx.URLParams.Add("orgID", "org-1")
+		rctx.URLParams.Add("bookingID", "book-1")
+		return req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+	}
+
+	tests := []struct {
+		name   string
+		expect func(mock sqlmock.Sqlmock)
+		code   int
+	}{
+		{
+			name: "marks an open booking",
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`UPDATE bookings SET status = \$3\s+WHERE id = \$1 AND org_id = \$2 AND completed_at IS NULL AND status <> 'cancelled'`).
+					WithArgs("book-1", "org-1", "no_show").
+					WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow("no_show"))
+			},
+			code: http.StatusOK,
+		},
+		{
+			name: "rejects a completed booking",
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`UPDATE bookings`).WillReturnError(sql.ErrNoRows)
+				mock.ExpectQuery(`SELECT completed_at IS NOT NULL`).
+					WithArgs("book-1", "org-1").
+					WillReturnRows(sqlmock.NewRows([]string{"closed"}).AddRow(true))
+			},
+			code: http.StatusConflict,
+		},
+		{
+			name: "unknown booking",
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`UPDATE bookings`).WillReturnError(sql.ErrNoRows)
+				mock.ExpectQuery(`SELECT completed_at IS NOT NULL`).WillReturnError(sql.ErrNoRows)
+			},
+			code: http.StatusNotFound,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+			tc.expect(mock)
+
+			rec := httptest.NewRecorder()
+			NewAdminDepositsHandler(db, logging.Default()).MarkBookingNoShow(rec, newRequest())
+			require.NoError(t, mock.ExpectationsWereMet())
+			require.Equal(t, tc.code, rec.Code, rec.Body.String())
+		})
+	}
+}
+
+type recordingCRMEvents struct {
+	events []crmhooks.Event
+}
+
+func (r *recordingCRMEvents) Publish(ctx context.Context, evt crmhooks.Event) error {
+	r.events = append(r.events, evt)
+	return nil
+}
+
+func TestCancelBooking(t *testing.T) {
+	newRequest := func() *http.Request {
+		req := httptest.NewRequest(http.MethodPost, "/admin/orgs/org-1/bookings/book-1/cancel", nil)
+		rctx := chi.NewRouteContext()
+		rctx.URLParams.Add("orgID", "org-1")
+		rctx.URLParams.Add("bookingID", "book-1")
+		return req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+	}
+	scheduledFor := time.Date(2026, 11, 2, 15, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		expect func(mock sqlmock.Sqlmock)
+		code   int
+		events int
+	}{
+		{
+			name: "cancels an open booking",
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`UPDATE bookings SET status = 'cancelled'\s+WHERE id = \$1 AND org_id = \$2 AND completed_at IS NULL AND status <> 'cancelled'`).
+					WithArgs("book-1", "org-1").
+					WillReturnRows(sqlmock.NewRows([]string{"status", "lead_id", "scheduled_for"}).AddRow("cancelled", "lead-1", scheduledFor))
+			},
+			code:   http.StatusOK,
+			events: 1,
+		},
+		{
+			name: "rejects an already cancelled booking",
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`UPDATE bookings`).WillReturnError(sql.ErrNoRows)
+				mock.ExpectQuery(`SELECT completed_at IS NOT NULL`).
+					WithArgs("book-1", "org-1").
+					WillReturnRows(sqlmock.NewRows([]string{"closed"}).AddRow(true))
+			},
+			code: http.StatusConflict,
+		},
+		{
+			name: "unknown booking",
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`UPDATE bookings`).WillReturnError(sql.ErrNoRows)
+				mock.ExpectQuery(`SELECT completed_at IS NOT NULL`).WillReturnError(sql.ErrNoRows)
+			},
+			code: http.StatusNotFound,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+			tc.expect(mock)
+
+			crmEvents := &recordingCRMEvents{}
+			handler := NewAdminDepositsHandler(db, logging.Default())
+			handler.SetCRMEvents(crmEvents)
+			rec := httptest.NewRecorder()
+			handler.CancelBooking(rec, newRequest())
+			require.NoError(t, mock.ExpectationsWereMet())
+			require.Equal(t, tc.code, rec.Code, rec.Body.String())
+			require.Len(t, crmEvents.events, tc.events)
+			if tc.events > 0 {
+				evt := crmEvents.events[0]
+				assert.Equal(t, crmhooks.EventBookingCancelled, evt.Type)
+				assert.Equal(t, "book-1", evt.Data.BookingID)
+				assert.Equal(t, "lead-1", evt.Data.LeadID)
+				require.NotNil(t, evt.Data.ScheduledFor)
+				assert.True(t, scheduledFor.Equal(*evt.Data.ScheduledFor))
+			}
+		})
+	}
+}
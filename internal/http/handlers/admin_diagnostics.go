@@ -0,0 +1,141 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	appbootstrap "github.com/wolfman30/medspa-ai-platform/internal/app/bootstrap"
+	"github.com/wolfman30/medspa-ai-platform/internal/clinic"
+	"github.com/wolfman30/medspa-ai-platform/internal/inboundhealth"
+	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
+)
+
+// Clinic statuses reported by the diagnostics endpoint.
+const (
+	diagnosticsStatusOK       = "ok"
+	diagnosticsStatusDegraded = "degraded"
+)
+
+// InboundLiveness reports a clinic's inbound message liveness.
+type InboundLiveness interface {
+	Status(ctx context.Context, cfg *clinic.Config, now time.Time) (inboundhealth.Status, error)
+}
+
+// DiagnosticsClinicSource loads clinic configs for diagnostics.
+type DiagnosticsClinicSource interface {
+	Get(ctx context.Context, orgID string) (*clinic.Config, error)
+}
+
+// PublishedStartupReports lists the startup reports other processes, such
+// as a standalone conversation worker, published.
+type PublishedStartupReports interface {
+	List(ctx context.Context) ([]appbootstrap.StartupReportSnapshot, error)
+}
+
+// AdminDiagnosticsHandler serves a clinic's operational health checks.
+type AdminDiagnosticsHandler struct {
+	clinics   DiagnosticsClinicSource
+	inbound   InboundLiveness
+	startup   *appbootstrap.StartupReport
+	published PublishedStartupReports
+	logger    *logging.Logger
+	now       func() time.Time
+}
+
+// NewAdminDiagnosticsHandler creates a new diagnostics handler.
+func NewAdminDiagnosticsHandler(clinics DiagnosticsClinicSource, inbound InboundLiveness, logger *logging.Logger) *AdminDiagnosticsHandler {
+	if logger == nil {
+		logger = logging.Default()
+	}
+	return &AdminDiagnosticsHandler{clinics: clinics, inbound: inbound, logger: logger, now: time.Now}
+}
+
+// diagnosticsResponse is the body of GET diagnostics.
+type diagnosticsResponse struct {
+	OrgID   string               `json:"org_id"`
+	Status  string               `json:"status"`
+	Inbound inboundhealth.Status `json:"inbound"`
+}
+
+// Get handles GET /admin/clinics/{orgID}/diagnostics
+// Status is "degraded" when any check is; today that is the inbound
+// liveness check, which flags clinics whose number stopped receiving texts.
+func (h *AdminDiagnosticsHandler) Get(w http.ResponseWriter, r *http.Request) {
+	if h == nil || h.clinics == nil || h.inbound == nil {
+		http.Error(w, "diagnostics not configured", http.StatusServiceUnavailable)
+		return
+	}
+	orgID := strings.TrimSpace(chi.URLParam(r, "orgID"))
+	if orgID == "" {
+		http.Error(w, "missing orgID", http.StatusBadRequest)
+		return
+	}
+	cfg, err := h.clinics.Get(r.Context(), orgID)
+	if err != nil {
+		h.logger.Error("diagnostics: load clinic config failed", "error", err, "org_id", orgID)
+		http.Error(w, "failed to load clinic config", http.StatusInternalServerError)
+		return
+	}
+	inbound, err := h.inbound.Status(r.Context(), cfg, h.now())
+	if err != nil {
+		h.logger.Error("diagnostics: load inbound liveness failed", "error", err, "org_id", orgID)
+		http.Error(w, "failed to load inbound liveness", http.StatusInternalServerError)
+		return
+	}
+	status := diagnosticsStatusOK
+	if inbound.Status == inboundhealth.StatusDegraded {
+		status = diagnosticsStatusDegraded
+	}
+	writeJSON(w, http.StatusOK, diagnosticsResponse{OrgID: orgID, Status: status, Inbound: inbound})
+}
+
+// SetStartupReport sets this process's report, served by GetStartup.
+func (h *AdminDiagnosticsHandler) SetStartupReport(report *appbootstrap.StartupReport) {
+	h.startup = report
+}
+
+// SetPublishedStartupReports sets where GetStartup finds the reports of
+// other processes.
+func (h *AdminDiagnosticsHandler) SetPublishedStartupReports(published PublishedStartupReports) {
+	h.published = published
+}
+
+// startupResponse is the body of GET /admin/diagnostics/startup.
+type startupResponse struct {
+	Reports []appbootstrap.StartupReportSnapshot `json:"reports"`
+}
+
+// GetStartup handles GET /admin/diagnostics/startup
+// It lists the capabilities each service enabled at startup, each with the
+// config that turned it on or the reason it is off: this process first,
+// then the ones other services published.
+func (h *AdminDiagnosticsHandler) GetStartup(w http.ResponseWriter, r *http.Request) {
+	if h == nil || (h.startup == nil && h.published == nil) {
+		http.Error(w, "startup report not available", http.StatusServiceUnavailable)
+		return
+	}
+	resp := startupResponse{Reports: []appbootstrap.StartupReportSnapshot{}}
+	local := ""
+	if h.startup != nil {
+		snap := h.startup.Snapshot()
+		local = snap.Service
+		resp.Reports = append(resp.Reports, snap)
+	}
+	if h.published != nil {
+		published, err := h.published.List(r.Context())
+		if err != nil {
+			h.logger.Error("diagnostics: load published startup reports failed", "error", err)
+			http.Error(w, "failed to load startup reports", http.StatusInternalServerError)
+			return
+		}
+		for _, snap := range published {
+			if snap.Service != local {
+				resp.Reports = append(resp.Reports, snap)
+			}
+		}
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
@@ -0,0 +1,141 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-chi/chi/v5"
+	"github.com/redis/go-redis/v9"
+	appbootstrap "github.com/wolfman30/medspa-ai-platform/internal/app/bootstrap"
+	"github.com/wolfman30/medspa-ai-platform/internal/clinic"
+	"github.com/wolfman30/medspa-ai-platform/internal/inboundhealth"
+	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
+)
+
+type diagnosticsClinics map[string]*clinic.Config
+
+func (d diagnosticsClinics) Get(ctx context.Context, orgID string) (*clinic.Config, error) {
+	if cfg, ok := d[orgID]; ok {
+		return cfg, nil
+	}
+	return nil, errors.New("not found")
+}
+
+func getDiagnostics(t *testing.T, h *AdminDiagnosticsHandler, orgID string) (int, diagnosticsResponse) {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodGet, "/admin/clinics/"+orgID+"/diagnostics", nil)
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("orgID", orgID)
+	rec := httptest.NewRecorder()
+	h.Get(rec, req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx)))
+	var body diagnosticsResponse
+	if rec.Code == http.StatusOK {
+		if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+	}
+	return rec.Code, body
+}
+
+func TestAdminDiagnostics_InboundDegradedUntilNextMessage(t *testing.T) {
+	ctx := context.Background()
+	mr := miniredis.RunT(t)
+	tracker := inboundhealth.NewTracker(redis.NewClient(&redis.Options{Addr: mr.Addr()}), logging.Default())
+	cfg := clinic.DefaultConfig("org-1")
+	h := NewAdminDiagnosticsHandler(diagnosticsClinics{"org-1": cfg}, tracker, logging.Default())
+	now := time.Date(2026, 10, 15, 17, 0, 0, 0, time.UTC)
+	h.now = func() time.Time { return now }
+
+	last := now.Add(-48 * time.Hour)
+	tracker.RecordInbound(ctx, "org-1", last)
+	if _, err := tracker.MarkDegraded(ctx, "org-1", inboundhealth.Degradation{Since: now.Add(-time.Hour), LastInboundAt: last}); err != nil {
+		t.Fatalf("MarkDegraded: %v", err)
+	}
+	code, body := getDiagnostics(t, h, "org-1")
+	if code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", code)
+	}
+	if body.Status != diagnosticsStatusDegraded || body.Inbound.Status != inboundhealth.StatusDegraded || body.Inbound.DegradedSince == nil {
+		t.Fatalf("expected a degraded clinic, got %+v", body)
+	}
+
+	tracker.RecordInbound(ctx, "org-1", now)
+	if _, body := getDiagnostics(t, h, "org-1"); body.Status != diagnosticsStatusOK || body.Inbound.Status != inboundhealth.StatusOK {
+		t.Fatalf("expected an inbound message to clear the degraded state, got %+v", body)
+	}
+
+	if code, _ := getDiagnostics(t, h, "org-missing"); code != http.StatusInternalServerError {
+		t.Fatalf("expected 500 for an unknown clinic, got %d", code)
+	}
+}
+
+func TestAdminDiagnostics_GetStartup(t *testing.T) {
+	h := NewAdminDiagnosticsHandler(nil, nil, logging.Default())
+	rec := httptest.NewRecorder()
+	h.GetStartup(rec, httptest.NewRequest(http.MethodGet, "/admin/diagnostics/startup", nil))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503 without a report, got %d", rec.Code)
+	}
+
+	report := appbootstrap.NewStartupReport("api", time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC))
+	report.Add(appbootstrap.StackPayments, appbootstrap.Disabled("deposit_sender", "missing outbox or payments store"))
+	h.SetStartupReport(report)
+
+	rec = httptest.NewRecorder()
+	h.GetStartup(rec, httptest.NewRequest(http.MethodGet, "/admin/diagnostics/startup", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	var body startupResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(body.Reports) != 1 {
+		t.Fatalf("expected only this process's report, got %+v", body)
+	}
+	if got := body.Reports[0]; got.Service != "api" || len(got.Capabilities) != 1 || got.Capabilities[0].Enabled || got.Capabilities[0].Stack != appbootstrap.StackPayments {
+		t.Fatalf("unexpected startup report %+v", got)
+	}
+}
+
+func TestAdminDiagnostics_GetStartupIncludesPublishedWorker(t *testing.T) {
+	mr := miniredis.RunT(t)
+	store := appbootstrap.NewStartupReportStore(redis.NewClient(&redis.Options{Addr: mr.Addr()}))
+	ctx := context.Background()
+	worker := appbootstrap.NewStartupReport("worker", time.Date(2026, 10, 18, 11, 0, 0, 0, time.UTC))
+	worker.Add(appbootstrap.StackPayments, appbootstrap.Enabled("deposit_sender", "square"))
+	if err := store.Publish(ctx, worker); err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+	// A stored report for this process's own service is skipped: the live
+	// one is served instead.
+	stale := appbootstrap.NewStartupReport("api", time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC))
+	if err := store.Publish(ctx, stale); err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+
+	h := NewAdminDiagnosticsHandler(nil, nil, logging.Default())
+	h.SetStartupReport(appbootstrap.NewStartupReport("api", time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)))
+	h.SetPublishedStartupReports(store)
+	rec := httptest.NewRecorder()
+	h.GetStartup(rec, httptest.NewRequest(http.MethodGet, "/admin/diagnostics/startup", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	var body startupResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(body.Reports) != 2 || body.Reports[0].Service != "api" || body.Reports[0].StartedAt.Day() != 18 || body.Reports[1].Service != "worker" {
+		t.Fatalf("expected the api report then the worker's, got %+v", body.Reports)
+	}
+	if c := body.Reports[1].Capabilities; len(c) != 1 || !c[0].Enabled || c[0].Name != "deposit_sender" {
+		t.Fatalf("unexpected worker capabilities %+v", c)
+	}
+}
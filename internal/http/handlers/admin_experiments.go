@@ -0,0 +1,37 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/conversation"
+	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
+)
+
+// AdminExperimentsHandler reports prompt experiment funnels.
+type AdminExperimentsHandler struct {
+	tracker *conversation.ExperimentTracker
+	logger  *logging.Logger
+}
+
+// NewAdminExperimentsHandler creates a new prompt experiments handler.
+func NewAdminExperimentsHandler(tracker *conversation.ExperimentTracker, logger *logging.Logger) *AdminExperimentsHandler {
+	if logger == nil {
+		logger = logging.Default()
+	}
+	return &AdminExperimentsHandler{tracker: tracker, logger: logger}
+}
+
+// ListExperiments handles GET /admin/experiments
+// Returns each active experiment with per-variant stage counts and conversion.
+func (h *AdminExperimentsHandler) ListExperiments(w http.ResponseWriter, r *http.Request) {
+	stats, err := h.tracker.Stats(r.Context())
+	if err != nil {
+		h.logger.Error("failed to load prompt experiment stats", "error", err)
+		http.Error(w, "failed to load experiments", http.StatusInternalServerError)
+		return
+	}
+	if stats == nil {
+		stats = []conversation.ExperimentStats{}
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"experiments": stats})
+}
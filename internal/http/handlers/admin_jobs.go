@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/conversation"
+	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
+)
+
+const maxStuckJobs = 200
+
+// AdminJobsHandler reports conversation jobs a worker never finished.
+type AdminJobsHandler struct {
+	jobs       conversation.StaleJobLister
+	staleAfter time.Duration
+	logger     *logging.Logger
+	now        func() time.Time
+}
+
+// NewAdminJobsHandler creates a handler listing jobs pending longer than
+// staleAfter.
+func NewAdminJobsHandler(jobs conversation.StaleJobLister, staleAfter time.Duration, logger *logging.Logger) *AdminJobsHandler {
+	if logger == nil {
+		logger = logging.Default()
+	}
+	if staleAfter <= 0 {
+		staleAfter = conversation.DefaultJobStaleAfter
+	}
+	return &AdminJobsHandler{jobs: jobs, staleAfter: staleAfter, logger: logger, now: time.Now}
+}
+
+type stuckJob struct {
+	JobID          string `json:"job_id"`
+	RequestType    string `json:"request_type"`
+	OrgID          string `json:"org_id,omitempty"`
+	ConversationID string `json:"conversation_id,omitempty"`
+	CreatedAt      string `json:"created_at"`
+	UpdatedAt      string `json:"updated_at"`
+	AckSent        bool   `json:"ack_sent"`
+}
+
+// ListStuck handles GET /admin/jobs/stuck
+// Lists pending jobs not updated for older_than (a Go duration, default the
+// reaper's threshold), oldest first. Request bodies are left out.
+func (h *AdminJobsHandler) ListStuck(w http.ResponseWriter, r *http.Request) {
+	if h.jobs == nil {
+		http.Error(w, "job store not configured", http.StatusServiceUnavailable)
+		return
+	}
+	olderThan := h.staleAfter
+	if raw := r.URL.Query().Get("older_than"); raw != "" {
+		d, err := time.ParseDuration(raw)
+		if err != nil || d <= 0 {
+			http.Error(w, "older_than must be a positive duration like 30m", http.StatusBadRequest)
+			return
+		}
+		olderThan = d
+	}
+	limit := maxStuckJobs
+	if raw := r.URL.Query().Get("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n <= 0 {
+			http.Error(w, "limit must be a positive integer", http.StatusBadRequest)
+			return
+		}
+		if n < limit {
+			limit = n
+		}
+	}
+
+	records, err := h.jobs.ListStale(r.Context(), h.now().Add(-olderThan), limit)
+	if err != nil {
+		h.logger.Error("failed to list stuck jobs", "error", err)
+		http.Error(w, "failed to list stuck jobs", http.StatusInternalServerError)
+		return
+	}
+	jobs := make([]stuckJob, 0, len(records))
+	for _, rec := range records {
+		job := stuckJob{
+			JobID:          rec.JobID,
+			RequestType:    string(rec.RequestType),
+			ConversationID: rec.ConversationID,
+			CreatedAt:      rec.CreatedAt,
+			UpdatedAt:      rec.UpdatedAt,
+			AckSent:        rec.AckSent,
+		}
+		switch {
+		case rec.StartRequest != nil:
+			job.OrgID = rec.StartRequest.OrgID
+		case rec.MessageRequest != nil:
+			job.OrgID = rec.MessageRequest.OrgID
+		}
+		jobs = append(jobs, job)
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"older_than": olderThan.String(),
+		"count":      len(jobs),
+		"jobs":       jobs,
+	})
+}
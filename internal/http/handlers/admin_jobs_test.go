@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/conversation"
+	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
+)
+
+type memStaleJobs struct {
+	jobs   []conversation.JobRecord
+	cutoff time.Time
+}
+
+func (m *memStaleJobs) ListStale(ctx context.Context, olderThan time.Time, limit int) ([]conversation.JobRecord, error) {
+	m.cutoff = olderThan
+	return m.jobs, nil
+}
+
+func TestAdminJobs_ListStuck(t *testing.T) {
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	store := &memStaleJobs{jobs: []conversation.JobRecord{{
+		JobID:          "job-1",
+		Status:         conversation.JobStatusPending,
+		ConversationID: "sms:org-1:15550000001",
+		MessageRequest: &conversation.MessageRequest{OrgID: "org-1", From: "+15550000001", Message: "hi"},
+	}}}
+	h := NewAdminJobsHandler(store, time.Hour, logging.Default())
+	h.now = func() time.Time { return now }
+
+	rec := httptest.NewRecorder()
+	h.ListStuck(rec, httptest.NewRequest(http.MethodGet, "/admin/jobs/stuck?older_than=30m", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", rec.Code, rec.Body.String())
+	}
+	if !store.cutoff.Equal(now.Add(-30 * time.Minute)) {
+		t.Fatalf("cutoff = %v", store.cutoff)
+	}
+	var resp struct {
+		Count int        `json:"count"`
+		Jobs  []stuckJob `json:"jobs"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if resp.Count != 1 || resp.Jobs[0].OrgID != "org-1" || resp.Jobs[0].JobID != "job-1" {
+		t.Fatalf("unexpected response: %s", rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	h.ListStuck(rec, httptest.NewRequest(http.MethodGet, "/admin/jobs/stuck?older_than=soon", nil))
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("bad older_than status = %d, want 400", rec.Code)
+	}
+}
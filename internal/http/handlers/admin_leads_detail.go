@@ -8,6 +8,8 @@ import (
 
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/pii"
 )
 
 // GetLead returns detailed information about a specific lead.
@@ -53,8 +55,9 @@ func (h *AdminLeadsHandler) GetLead(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	lead.Name = name.String
-	lead.Email = email.String
+	lead.Phone = pii.Reveal(lead.Phone)
+	lead.Name = pii.Reveal(name.String)
+	lead.Email = pii.Reveal(email.String)
 	lead.Source = source.String
 	lead.Notes = notes.String
 	lead.CreatedAt = createdAt.Format(time.RFC3339)
@@ -164,6 +167,39 @@ func (h *AdminLeadsHandler) GetLead(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	// Get EMR writeback attempts
+	attemptRows, err := h.db.QueryContext(r.Context(), `
+		SELECT id, idempotency_key, platform, status, COALESCE(appointment_id, ''),
+			COALESCE(error, ''), started_at, finished_at
+		FROM emr_writeback_attempts
+		WHERE lead_id = $1
+		ORDER BY started_at DESC
+		LIMIT 20
+	`, leadUUID)
+	if err != nil {
+		h.logger.Error("failed to query writeback attempts", "lead_id", lead.ID, "error", err)
+	} else {
+		defer attemptRows.Close()
+		for attemptRows.Next() {
+			var attempt WritebackAttemptSummary
+			var startedAt time.Time
+			var finishedAt sql.NullTime
+			if err := attemptRows.Scan(&attempt.ID, &attempt.IdempotencyKey, &attempt.Platform, &attempt.Status,
+				&attempt.AppointmentID, &attempt.Error, &startedAt, &finishedAt); err != nil {
+				h.logger.Error("failed to scan writeback attempt", "lead_id", lead.ID, "error", err)
+				continue
+			}
+			attempt.StartedAt = startedAt.Format(time.RFC3339)
+			if finishedAt.Valid {
+				attempt.FinishedAt = finishedAt.Time.Format(time.RFC3339)
+			}
+			lead.WritebackAttempts = append(lead.WritebackAttempts, attempt)
+		}
+		if err := attemptRows.Err(); err != nil {
+			h.logger.Error("writeback attempt rows iteration failed", "lead_id", lead.ID, "error", err)
+		}
+	}
+
 	// Initialize empty arrays if nil
 	if lead.ConversationIDs == nil {
 		lead.ConversationIDs = []string{}
@@ -174,6 +210,9 @@ func (h *AdminLeadsHandler) GetLead(w http.ResponseWriter, r *http.Request) {
 	if lead.Bookings == nil {
 		lead.Bookings = []BookingSummary{}
 	}
+	if lead.WritebackAttempts == nil {
+		lead.WritebackAttempts = []WritebackAttemptSummary{}
+	}
 	if lead.Timeline == nil {
 		lead.Timeline = []TimelineEvent{}
 	}
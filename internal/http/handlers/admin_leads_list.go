@@ -2,17 +2,128 @@ package handlers
 
 import (
 	"database/sql"
+	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/go-chi/chi/v5"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/pii"
 )
 
-// ListLeads returns a paginated list of leads for an organization.
+// leadListFrom joins each lead's latest conversation, payment and booking.
+// LATERAL keeps it to one row per lead in a single query.
+const leadListFrom = `
+	FROM leads l
+	LEFT JOIN LATERAL (
+		SELECT c.conversation_id, c.status, c.last_message_at
+		FROM conversations c
+		WHERE c.lead_id = l.id
+		ORDER BY c.last_message_at DESC NULLS LAST, c.created_at DESC
+		LIMIT 1
+	) lc ON TRUE
+	LEFT JOIN LATERAL (
+		SELECT p.status, p.created_at
+		FROM payments p
+		WHERE p.lead_id = l.id
+		ORDER BY p.created_at DESC
+		LIMIT 1
+	) lp ON TRUE
+	LEFT JOIN LATERAL (
+		SELECT b.status, b.created_at
+		FROM bookings b
+		WHERE b.lead_id = l.id
+		ORDER BY b.created_at DESC
+		LIMIT 1
+	) lb ON TRUE
+`
+
+// leadLastActivity is the latest of the lead's own changes, its last
+// message, and its latest payment and booking. GREATEST skips NULLs.
+const leadLastActivity = "GREATEST(l.created_at, l.updated_at, lc.last_message_at, lp.created_at, lb.created_at)"
+
+const leadListColumns = `
+	SELECT l.id, l.org_id, l.phone, l.name, l.email, l.status, l.source,
+		   l.interested_services, l.tags, l.notes, l.created_at, l.updated_at,
+		   l.service_interest, l.patient_type, l.preferred_days, l.preferred_times,
+		   lc.status, lc.last_message_at, lp.status, lb.status,
+		   ` + leadLastActivity + ` AS last_activity,
+		   (SELECT COALESCE(SUM(p.amount_cents), 0) FROM payments p WHERE p.lead_id = l.id AND p.status = 'succeeded') AS payment_total,
+		   (SELECT COUNT(*) FROM bookings b WHERE b.lead_id = l.id) AS booking_count,
+		   (SELECT COUNT(*) FROM conversation_jobs j WHERE j.conversation_id = lc.conversation_id) AS job_count
+`
+
+// leadListSort is an ordering the lead list supports. Timestamp sorts
+// compare their cursor value as timestamptz.
+type leadListSort struct {
+	expr      string
+	timestamp bool
+}
+
+var leadListSorts = map[string]leadListSort{
+	"created_at":    {expr: "l.created_at", timestamp: true},
+	"updated_at":    {expr: "l.updated_at", timestamp: true},
+	"last_activity": {expr: leadLastActivity, timestamp: true},
+	"name":          {expr: "COALESCE(l.name, '')"},
+	"status":        {expr: "l.status"},
+}
+
+// leadListFilters are the status filters the lead list accepts, in the order
+// their arguments are bound.
+var leadListFilters = []struct {
+	param  string
+	column string
+}{
+	{"status", "l.status"},
+	{"conversation_status", "lc.status"},
+	{"payment_status", "lp.status"},
+	{"booking_status", "lb.status"},
+}
+
+// leadListCursor marks the last lead of a page. It carries the sort so a
+// cursor can't be replayed against a different ordering.
+type leadListCursor struct {
+	Sort  string `json:"s"`
+	Order string `json:"o"`
+	Value string `json:"v"`
+	ID    string `json:"id"`
+}
+
+var errInvalidLeadCursor = errors.New("invalid cursor")
+
+func (c leadListCursor) encode() string {
+	raw, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+func decodeLeadListCursor(s, sortBy, sortOrder string) (leadListCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return leadListCursor{}, errInvalidLeadCursor
+	}
+	var c leadListCursor
+	if err := json.Unmarshal(raw, &c); err != nil || c.ID == "" {
+		return leadListCursor{}, errInvalidLeadCursor
+	}
+	if c.Sort != sortBy || c.Order != sortOrder {
+		return leadListCursor{}, errInvalidLeadCursor
+	}
+	return c, nil
+}
+
+// ListLeads returns a paginated list of leads for an organization with each
+// lead's latest conversation, payment and booking status.
 // GET /admin/orgs/{orgID}/leads
+//
+// Pages are numbered with page/page_size, or chained with the next_cursor of
+// the previous response. Cursor pages stay stable while new leads arrive.
+// mask_phone=true returns only the last four digits of each phone. While lead
+// PII is encrypted, search takes only a full phone number and sort_by=name is
+// rejected.
 func (h *AdminLeadsHandler) ListLeads(w http.ResponseWriter, r *http.Request) {
 	orgID := chi.URLParam(r, "orgID")
 	if orgID == "" {
@@ -21,82 +132,110 @@ func (h *AdminLeadsHandler) ListLeads(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Parse query parameters
-	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	q := r.URL.Query()
+	page, _ := strconv.Atoi(q.Get("page"))
 	if page < 1 {
 		page = 1
 	}
-	pageSize, _ := strconv.Atoi(r.URL.Query().Get("page_size"))
+	pageSize, _ := strconv.Atoi(q.Get("page_size"))
 	if pageSize < 1 || pageSize > 100 {
 		pageSize = 20
 	}
-	status := r.URL.Query().Get("status")
-	search := r.URL.Query().Get("search")
-	sortBy := r.URL.Query().Get("sort_by")
-	if sortBy == "" {
+	search := strings.TrimSpace(q.Get("search"))
+	sortBy := q.Get("sort_by")
+	sort, ok := leadListSorts[sortBy]
+	if !ok {
 		sortBy = "created_at"
+		sort = leadListSorts[sortBy]
 	}
-	sortOrder := r.URL.Query().Get("sort_order")
+	sortOrder := q.Get("sort_order")
 	if sortOrder != "asc" {
 		sortOrder = "desc"
 	}
+	maskPhones, _ := strconv.ParseBool(q.Get("mask_phone"))
+
+	// With PII encryption on, names, emails and phones are ciphertext: only
+	// a full phone can be found, through its hash, and names can't be sorted.
+	encrypted := pii.Default().Enabled()
+	if encrypted && sortBy == "name" {
+		http.Error(w, "sort_by=name is unavailable while lead PII is encrypted", http.StatusBadRequest)
+		return
+	}
+	if encrypted && search != "" && (!isPhoneSearch(search) || len(normalizePhoneDigits(search)) < 10) {
+		http.Error(w, "search must be a full phone number while lead PII is encrypted", http.StatusBadRequest)
+		return
+	}
 
-	offset := (page - 1) * pageSize
+	var cursor *leadListCursor
+	if raw := q.Get("cursor"); raw != "" {
+		c, err := decodeLeadListCursor(raw, sortBy, sortOrder)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		cursor = &c
+	}
 
-	// Build query - use subqueries to avoid JOIN inflation
-	query := `
-		SELECT l.id, l.org_id, l.phone, l.name, l.email, l.status, l.source,
-			   l.interested_services, l.tags, l.notes, l.created_at, l.updated_at,
-			   (SELECT COALESCE(SUM(p.amount_cents), 0) FROM payments p WHERE p.lead_id = l.id AND p.status = 'succeeded') as payment_total,
-			   (SELECT COUNT(*) FROM bookings b WHERE b.lead_id = l.id) as booking_count
-		FROM leads l
-		WHERE l.org_id = $1
-	`
+	where := " WHERE l.org_id = $1"
 	args := []any{orgID}
 	argNum := 2
-
-	if status != "" {
-		query += " AND l.status = $" + strconv.Itoa(argNum)
-		args = append(args, status)
-		argNum++
+	for _, filter := range leadListFilters {
+		if value := q.Get(filter.param); value != "" {
+			where += " AND " + filter.column + " = $" + strconv.Itoa(argNum)
+			args = append(args, value)
+			argNum++
+		}
 	}
 
-	if search != "" {
-		query += " AND (l.name ILIKE $" + strconv.Itoa(argNum) + " OR l.phone ILIKE $" + strconv.Itoa(argNum) + " OR l.email ILIKE $" + strconv.Itoa(argNum) + ")"
+	switch {
+	case search != "" && encrypted:
+		where += " AND l.phone_hash = $" + strconv.Itoa(argNum)
+		args = append(args, pii.PhoneHash(search))
+		argNum++
+	case search != "":
+		searchFilter := "l.name ILIKE $" + strconv.Itoa(argNum) + " OR l.phone ILIKE $" + strconv.Itoa(argNum) + " OR l.email ILIKE $" + strconv.Itoa(argNum)
 		args = append(args, "%"+search+"%")
 		argNum++
+		if digits := normalizePhoneDigits(search); len(digits) >= 4 && isPhoneSearch(search) {
+			// "(555) 111-1111" or "1111" matches the end of the stored number.
+			searchFilter += " OR l.phone LIKE $" + strconv.Itoa(argNum)
+			args = append(args, "%"+digits)
+			argNum++
+		}
+		where += " AND (" + searchFilter + ")"
 	}
 
-	// Add sorting
-	validSortColumns := map[string]bool{
-		"created_at": true,
-		"updated_at": true,
-		"name":       true,
-		"status":     true,
-	}
-	if !validSortColumns[sortBy] {
-		sortBy = "created_at"
-	}
-	query += " ORDER BY l." + sortBy + " " + strings.ToUpper(sortOrder)
-
-	// Get total count first
-	countQuery := `SELECT COUNT(*) FROM leads WHERE org_id = $1`
-	if status != "" {
-		countQuery += " AND status = $2"
-	}
+	// Count with the same filters, before the cursor narrows the page.
 	var total int
-	countArgs := []any{orgID}
-	if status != "" {
-		countArgs = append(countArgs, status)
-	}
-	if err := h.db.QueryRowContext(r.Context(), countQuery, countArgs...).Scan(&total); err != nil {
+	if err := h.db.QueryRowContext(r.Context(), "SELECT COUNT(*)"+leadListFrom+where, args...).Scan(&total); err != nil {
 		h.logger.Error("failed to count leads", "error", err)
 		http.Error(w, "internal error", http.StatusInternalServerError)
 		return
 	}
 
-	// Add pagination
-	query += " LIMIT $" + strconv.Itoa(argNum) + " OFFSET $" + strconv.Itoa(argNum+1)
-	args = append(args, pageSize, offset)
+	cmp := "<"
+	if sortOrder == "asc" {
+		cmp = ">"
+	}
+	if cursor != nil {
+		value := "$" + strconv.Itoa(argNum)
+		if sort.timestamp {
+			value += "::timestamptz"
+		}
+		where += " AND (" + sort.expr + ", l.id) " + cmp + " (" + value + ", $" + strconv.Itoa(argNum+1) + "::uuid)"
+		args = append(args, cursor.Value, cursor.ID)
+		argNum += 2
+	}
+
+	order := strings.ToUpper(sortOrder)
+	query := leadListColumns + leadListFrom + where + " ORDER BY " + sort.expr + " " + order + ", l.id " + order
+	// One extra row tells whether there is a next page.
+	query += " LIMIT $" + strconv.Itoa(argNum)
+	args = append(args, pageSize+1)
+	if cursor == nil {
+		query += " OFFSET $" + strconv.Itoa(argNum+1)
+		args = append(args, (page-1)*pageSize)
+	}
 
 	rows, err := h.db.QueryContext(r.Context(), query, args...)
 	if err != nil {
@@ -107,66 +246,21 @@ func (h *AdminLeadsHandler) ListLeads(w http.ResponseWriter, r *http.Request) {
 	defer rows.Close()
 
 	var leads []LeadResponse
+	var next *leadListCursor
 	for rows.Next() {
-		var lead LeadResponse
-		var name, email, source, notes sql.NullString
-		var interestedServices, tags []byte
-		var createdAt, updatedAt time.Time
-
-		err := rows.Scan(
-			&lead.ID, &lead.OrgID, &lead.Phone, &name, &email, &lead.Status, &source,
-			&interestedServices, &tags, &notes, &createdAt, &updatedAt,
-			&lead.PaymentTotal, &lead.BookingCount,
-		)
+		if len(leads) == pageSize {
+			last := leads[len(leads)-1]
+			next = &leadListCursor{Sort: sortBy, Order: sortOrder, Value: last.sortValue, ID: last.ID}
+			break
+		}
+		lead, err := h.scanLeadListRow(rows, sortBy)
 		if err != nil {
 			h.logger.Error("failed to scan lead", "error", err)
 			continue
 		}
-
-		lead.Name = name.String
-		lead.Email = email.String
-		lead.Source = source.String
-		lead.Notes = notes.String
-		lead.CreatedAt = createdAt.Format(time.RFC3339)
-		lead.UpdatedAt = updatedAt.Format(time.RFC3339)
-
-		if len(interestedServices) > 0 {
-			if err := json.Unmarshal(interestedServices, &lead.InterestedServices); err != nil {
-				h.logger.Error("failed to decode interested_services", "lead_id", lead.ID, "error", err)
-			}
-		}
-		if len(tags) > 0 {
-			if err := json.Unmarshal(tags, &lead.Tags); err != nil {
-				h.logger.Error("failed to decode tags", "lead_id", lead.ID, "error", err)
-			}
-		}
-
-		// Get conversation job count via phone pattern
-		// conversation_id format: "sms:{orgID}:{phone}"
-		normalizedPhone := normalizePhoneDigits(lead.Phone)
-		conversationPattern := "sms:" + orgID + ":%" + normalizedPhone + "%"
-		var jobCount int
-		if err := h.db.QueryRowContext(r.Context(),
-			`SELECT COUNT(*) FROM conversation_jobs WHERE conversation_id LIKE $1`,
-			conversationPattern,
-		).Scan(&jobCount); err != nil {
-			h.logger.Error("failed to get conversation job count", "lead_id", lead.ID, "error", err)
-		} else {
-			lead.ConversationJobCount = jobCount
-		}
-
-		// Get last contact from conversation_jobs
-		var lastContact sql.NullTime
-		if err := h.db.QueryRowContext(r.Context(),
-			`SELECT MAX(created_at) FROM conversation_jobs WHERE conversation_id LIKE $1`,
-			conversationPattern,
-		).Scan(&lastContact); err != nil {
-			h.logger.Error("failed to get last contact", "lead_id", lead.ID, "error", err)
-		} else if lastContact.Valid {
-			formatted := lastContact.Time.Format(time.RFC3339)
-			lead.LastContactAt = &formatted
+		if maskPhones {
+			lead.Phone = maskLeadPhone(lead.Phone)
 		}
-
 		leads = append(leads, lead)
 	}
 
@@ -188,7 +282,125 @@ func (h *AdminLeadsHandler) ListLeads(w http.ResponseWriter, r *http.Request) {
 		PageSize:   pageSize,
 		TotalPages: totalPages,
 	}
+	if cursor != nil {
+		response.Page = 0
+	}
+	if next != nil {
+		response.NextCursor = next.encode()
+	}
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(response)
 }
+
+// scanLeadListRow reads one lead list row and remembers its sortBy value for
+// the next cursor.
+func (h *AdminLeadsHandler) scanLeadListRow(rows *sql.Rows, sortBy string) (LeadResponse, error) {
+	var lead LeadResponse
+	var name, email, source, notes sql.NullString
+	var serviceInterest, patientType, preferredDays, preferredTimes sql.NullString
+	var conversationStatus, paymentStatus, bookingStatus sql.NullString
+	var interestedServices, tags []byte
+	var createdAt, updatedAt, lastActivity time.Time
+	var lastMessageAt sql.NullTime
+
+	if err := rows.Scan(
+		&lead.ID, &lead.OrgID, &lead.Phone, &name, &email, &lead.Status, &source,
+		&interestedServices, &tags, &notes, &createdAt, &updatedAt,
+		&serviceInterest, &patientType, &preferredDays, &preferredTimes,
+		&conversationStatus, &lastMessageAt, &paymentStatus, &bookingStatus,
+		&lastActivity, &lead.PaymentTotal, &lead.BookingCount, &lead.ConversationJobCount,
+	); err != nil {
+		return LeadResponse{}, err
+	}
+
+	switch sortBy {
+	case "created_at":
+		lead.sortValue = createdAt.Format(time.RFC3339Nano)
+	case "updated_at":
+		lead.sortValue = updatedAt.Format(time.RFC3339Nano)
+	case "last_activity":
+		lead.sortValue = lastActivity.Format(time.RFC3339Nano)
+	case "name":
+		lead.sortValue = name.String
+	case "status":
+		lead.sortValue = lead.Status
+	}
+
+	lead.Phone = pii.Reveal(lead.Phone)
+	lead.Name = pii.Reveal(name.String)
+	lead.Email = pii.Reveal(email.String)
+	lead.Source = source.String
+	lead.Notes = notes.String
+	lead.CreatedAt = createdAt.Format(time.RFC3339)
+	lead.UpdatedAt = updatedAt.Format(time.RFC3339)
+	lead.LastActivityAt = lastActivity.Format(time.RFC3339)
+	lead.ServiceInterest = serviceInterest.String
+	lead.ConversationStatus = conversationStatus.String
+	lead.PaymentStatus = paymentStatus.String
+	lead.BookingStatus = bookingStatus.String
+	if lastMessageAt.Valid {
+		formatted := lastMessageAt.Time.Format(time.RFC3339)
+		lead.LastContactAt = &formatted
+	}
+	lead.Qualification = leadQualificationProgress(lead.Name, serviceInterest.String, patientType.String, preferredDays.String, preferredTimes.String)
+
+	if len(interestedServices) > 0 {
+		if err := json.Unmarshal(interestedServices, &lead.InterestedServices); err != nil {
+			h.logger.Error("failed to decode interested_services", "lead_id", lead.ID, "error", err)
+		}
+	}
+	if len(tags) > 0 {
+		if err := json.Unmarshal(tags, &lead.Tags); err != nil {
+			h.logger.Error("failed to decode tags", "lead_id", lead.ID, "error", err)
+		}
+	}
+	return lead, nil
+}
+
+// leadQualificationProgress counts the qualifications saved on the lead. The
+// missing names match conversation.QualificationGaps; provider preference and
+// clinic-specific questions depend on clinic config and aren't counted.
+func leadQualificationProgress(name, service, patientType, preferredDays, preferredTimes string) *LeadQualificationProgress {
+	progress := &LeadQualificationProgress{Missing: []string{}}
+	for _, field := range []struct {
+		key   string
+		value string
+	}{
+		{"name", name},
+		{"service", service},
+		{"patient_type", patientType},
+		{"schedule", preferredDays + preferredTimes},
+	} {
+		progress.Required++
+		if strings.TrimSpace(field.value) == "" {
+			progress.Missing = append(progress.Missing, field.key)
+			continue
+		}
+		progress.Captured++
+	}
+	return progress
+}
+
+// isPhoneSearch reports whether s looks like a phone number or its tail.
+func isPhoneSearch(s string) bool {
+	for _, r := range s {
+		switch {
+		case r >= '0' && r <= '9':
+		case strings.ContainsRune("+()-. ", r):
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// maskLeadPhone keeps the last four digits so operators can still match a
+// lead to a caller.
+func maskLeadPhone(phone string) string {
+	digits := normalizePhoneDigits(phone)
+	if len(digits) < 4 {
+		return ""
+	}
+	return "•••" + digits[len(digits)-4:]
+}
@@ -0,0 +1,249 @@
+package handlers
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/pii"
+	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
+)
+
+var leadListRowColumns = []string{
+	"id", "org_id", "phone", "name", "email", "status", "source",
+	"interested_services", "tags", "notes", "created_at", "updated_at",
+	"service_interest", "patient_type", "preferred_days", "preferred_times",
+	"conversation_status", "last_message_at", "payment_status", "booking_status",
+	"last_activity", "payment_total", "booking_count", "job_count",
+}
+
+// leadListRow is one lead list row; nil fields are NULL, as they are for a
+// lead with no conversation, payment or booking yet.
+type leadListRow struct {
+	id, phone, name, status             string
+	service, patientType, days          any
+	convStatus, paymentStatus, bookStat any
+	lastMessage                         any
+	created, activity                   time.Time
+	paymentTotal, bookings, jobs        int
+}
+
+func (r leadListRow) values() []driver.Value {
+	return []driver.Value{
+		r.id, "org-1", r.phone, r.name, nil, r.status, "sms",
+		nil, nil, nil, r.created, r.created,
+		r.service, r.patientType, r.days, nil,
+		r.convStatus, r.lastMessage, r.paymentStatus, r.bookStat,
+		r.activity, r.paymentTotal, r.bookings, r.jobs,
+	}
+}
+
+func leadListRows(rows ...leadListRow) *sqlmock.Rows {
+	out := sqlmock.NewRows(leadListRowColumns)
+	for _, row := range rows {
+		out.AddRow(row.values()...)
+	}
+	return out
+}
+
+func newLeadListRequest(query string) *http.Request {
+	req := httptest.NewRequest(http.MethodGet, "/admin/orgs/org-1/leads"+query, nil)
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("orgID", "org-1")
+	return req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+}
+
+func serveLeadList(t *testing.T, handler *AdminLeadsHandler, query string) LeadsListResponse {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	handler.ListLeads(rec, newLeadListRequest(query))
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var resp LeadsListResponse
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&resp))
+	return resp
+}
+
+func TestListLeads_LifecycleStages(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+	handler := NewAdminLeadsHandler(db, logging.Default())
+
+	created := time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+	lastMessage := created.Add(2 * time.Hour)
+	paid := created.Add(26 * time.Hour)
+
+	mock.ExpectQuery(`SELECT COUNT\(\*\)\s+FROM leads l\s+LEFT JOIN LATERAL`).
+		WithArgs("org-1").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+	// A single query: the latest conversation, payment and booking come from
+	// LATERAL joins, not a query per lead.
+	mock.ExpectQuery(`SELECT l.id, .*lc.status, lc.last_message_at, lp.status, lb.status.*FROM conversations c\s+WHERE c.lead_id = l.id.*FROM payments p\s+WHERE p.lead_id = l.id.*FROM bookings b\s+WHERE b.lead_id = l.id.*ORDER BY GREATEST\(.*\) DESC, l.id DESC LIMIT \$2 OFFSET \$3`).
+		WithArgs("org-1", 21, 0).
+		WillReturnRows(leadListRows(
+			leadListRow{
+				id: "lead-paid", phone: "+15550003333", name: "Ana Paid", status: "booked",
+				service: "Botox", patientType: "new", days: "weekdays",
+				convStatus: "active", lastMessage: lastMessage, paymentStatus: "succeeded", bookStat: "confirmed",
+				created: created, activity: paid, paymentTotal: 5000, bookings: 1, jobs: 6,
+			},
+			leadListRow{
+				id: "lead-qualifying", phone: "+15550002222", name: "Quinn Q", status: "contacted",
+				service: "Filler", convStatus: "active", lastMessage: lastMessage,
+				created: created, activity: lastMessage, jobs: 2,
+			},
+			leadListRow{
+				id: "lead-new", phone: "+15550001111", status: "new",
+				created: created, activity: created,
+			},
+		))
+
+	resp := serveLeadList(t, handler, "?sort_by=last_activity")
+	require.NoError(t, mock.ExpectationsWereMet())
+	require.Len(t, resp.Leads, 3)
+	assert.Equal(t, 3, resp.Total)
+	assert.Empty(t, resp.NextCursor)
+
+	booked := resp.Leads[0]
+	assert.Equal(t, "confirmed", booked.BookingStatus)
+	assert.Equal(t, "succeeded", booked.PaymentStatus)
+	assert.Equal(t, "active", booked.ConversationStatus)
+	assert.Equal(t, paid.Format(time.RFC3339), booked.LastActivityAt)
+	assert.Equal(t, 5000, booked.PaymentTotal)
+	assert.Equal(t, 6, booked.ConversationJobCount)
+	assert.Equal(t, &LeadQualificationProgress{Captured: 4, Required: 4, Missing: []string{}}, booked.Qualification)
+
+	qualifying := resp.Leads[1]
+	assert.Empty(t, qualifying.BookingStatus)
+	assert.Empty(t, qualifying.PaymentStatus)
+	assert.Equal(t, "Filler", qualifying.ServiceInterest)
+	require.NotNil(t, qualifying.LastContactAt)
+	assert.Equal(t, lastMessage.Format(time.RFC3339), *qualifying.LastContactAt)
+	assert.Equal(t, []string{"patient_type", "schedule"}, qualifying.Qualification.Missing)
+	assert.Equal(t, 2, qualifying.Qualification.Captured)
+
+	fresh := resp.Leads[2]
+	assert.Empty(t, fresh.ConversationStatus)
+	assert.Nil(t, fresh.LastContactAt)
+	assert.Equal(t, created.Format(time.RFC3339), fresh.LastActivityAt)
+	assert.Equal(t, 0, fresh.Qualification.Captured)
+	assert.Equal(t, "+15550001111", fresh.Phone)
+}
+
+func TestListLeads_CursorStableUnderInserts(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+	handler := NewAdminLeadsHandler(db, logging.Default())
+
+	base := time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+	a := leadListRow{id: "00000000-0000-0000-0000-00000000000a", phone: "+15550000001", status: "new", created: base, activity: base.Add(3 * time.Hour)}
+	b := leadListRow{id: "00000000-0000-0000-0000-00000000000b", phone: "+15550000002", status: "new", created: base, activity: base.Add(2 * time.Hour)}
+	c := leadListRow{id: "00000000-0000-0000-0000-00000000000c", phone: "+15550000003", status: "new", created: base, activity: base.Add(time.Hour)}
+
+	mock.ExpectQuery(`SELECT COUNT\(\*\)`).WithArgs("org-1").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+	mock.ExpectQuery(`LIMIT \$2 OFFSET \$3`).WithArgs("org-1", 3, 0).
+		WillReturnRows(leadListRows(a, b, c))
+
+	first := serveLeadList(t, handler, "?sort_by=last_activity&page_size=2")
+	require.Len(t, first.Leads, 2)
+	assert.Equal(t, []string{a.id, b.id}, []string{first.Leads[0].ID, first.Leads[1].ID})
+	require.NotEmpty(t, first.NextCursor)
+
+	// A lead arrives between pages. It sorts ahead of the cursor, so the next
+	// page picks up after b instead of shifting by one as an offset would.
+	mock.ExpectQuery(`SELECT COUNT\(\*\)`).WithArgs("org-1").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(4))
+	mock.ExpectQuery(`AND \(GREATEST\(.*\), l.id\) < \(\$2::timestamptz, \$3::uuid\) ORDER BY .* LIMIT \$4$`).
+		WithArgs("org-1", b.activity.Format(time.RFC3339Nano), b.id, 3).
+		WillReturnRows(leadListRows(c))
+
+	second := serveLeadList(t, handler, "?sort_by=last_activity&page_size=2&cursor="+first.NextCursor)
+	require.NoError(t, mock.ExpectationsWereMet())
+	require.Len(t, second.Leads, 1)
+	assert.Equal(t, c.id, second.Leads[0].ID)
+	assert.Empty(t, second.NextCursor)
+	assert.Equal(t, 4, second.Total)
+
+	// A cursor only continues the ordering it came from.
+	rec := httptest.NewRecorder()
+	handler.ListLeads(rec, newLeadListRequest("?sort_by=created_at&cursor="+first.NextCursor))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestListLeads_MaskPhoneAndSuffixSearch(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+	handler := NewAdminLeadsHandler(db, logging.Default())
+
+	created := time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+	row := leadListRow{id: "lead-1", phone: "+15550001111", name: "Ana", status: "new", created: created, activity: created}
+
+	mock.ExpectQuery(`SELECT COUNT\(\*\).*AND \(l.name ILIKE \$2 OR l.phone ILIKE \$2 OR l.email ILIKE \$2 OR l.phone LIKE \$3\)`).
+		WithArgs("org-1", "%111-1111%", "%1111111").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	mock.ExpectQuery(`l.phone LIKE \$3\) ORDER BY`).
+		WithArgs("org-1", "%111-1111%", "%1111111", 21, 0).
+		WillReturnRows(leadListRows(row))
+
+	resp := serveLeadList(t, handler, "?search=111-1111&mask_phone=true")
+	require.NoError(t, mock.ExpectationsWereMet())
+	require.Len(t, resp.Leads, 1)
+	assert.Equal(t, "•••1111", resp.Leads[0].Phone)
+	assert.Equal(t, "Ana", resp.Leads[0].Name)
+
+	mock.ExpectQuery(`SELECT COUNT\(\*\)`).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	mock.ExpectQuery(`ORDER BY`).WillReturnRows(leadListRows(row))
+	resp = serveLeadList(t, handler, "")
+	assert.Equal(t, "+15550001111", resp.Leads[0].Phone)
+}
+
+func TestListLeads_EncryptedPIISearchesByPhoneHashOnly(t *testing.T) {
+	cipher, err := pii.NewCipher(pii.Config{
+		Keys:        map[string][]byte{"k1": []byte("0123456789abcdef0123456789abcdef")},
+		ActiveKeyID: "k1",
+		HMACKey:     []byte("0123456789abcdef"),
+	})
+	require.NoError(t, err)
+	pii.SetDefault(cipher)
+	t.Cleanup(func() { pii.SetDefault(nil) })
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+	handler := NewAdminLeadsHandler(db, logging.Default())
+
+	for _, query := range []string{"?search=Ana", "?search=111-1111", "?sort_by=name"} {
+		rec := httptest.NewRecorder()
+		handler.ListLeads(rec, newLeadListRequest(query))
+		assert.Equal(t, http.StatusBadRequest, rec.Code, query)
+	}
+
+	created := time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+	phone, err := cipher.Encrypt("+15550001111")
+	require.NoError(t, err)
+	row := leadListRow{id: "lead-1", phone: phone, name: "", status: "new", created: created, activity: created}
+	hash := cipher.HashPhone("+15550001111")
+	mock.ExpectQuery(`SELECT COUNT\(\*\).* AND l.phone_hash = \$2$`).
+		WithArgs("org-1", hash).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	mock.ExpectQuery(`AND l.phone_hash = \$2 ORDER BY`).
+		WithArgs("org-1", hash, 21, 0).
+		WillReturnRows(leadListRows(row))
+
+	resp := serveLeadList(t, handler, "?search=(555)+000-1111&mask_phone=true")
+	require.NoError(t, mock.ExpectationsWereMet())
+	require.Len(t, resp.Leads, 1)
+	assert.Equal(t, "•••1111", resp.Leads[0].Phone)
+}
@@ -23,7 +23,7 @@ func (h *AdminLeadsHandler) GetLeadStats(w http.ResponseWriter, r *http.Request)
 
 	// Total leads
 	if err := h.db.QueryRowContext(r.Context(),
-		`SELECT COUNT(*) FROM leads WHERE org_id = $1`, orgID,
+		`SELECT COUNT(*) FROM leads WHERE org_id = $1 AND NOT probable_wrong_number`, orgID,
 	).Scan(&stats.TotalLeads); err != nil {
 		h.logger.Error("failed to count total leads", "org_id", orgID, "error", err)
 		http.Error(w, "internal error", http.StatusInternalServerError)
@@ -32,7 +32,7 @@ func (h *AdminLeadsHandler) GetLeadStats(w http.ResponseWriter, r *http.Request)
 
 	// By status
 	rows, err := h.db.QueryContext(r.Context(),
-		`SELECT status, COUNT(*) FROM leads WHERE org_id = $1 GROUP BY status`, orgID,
+		`SELECT status, COUNT(*) FROM leads WHERE org_id = $1 AND NOT probable_wrong_number GROUP BY status`, orgID,
 	)
 	if err != nil {
 		h.logger.Error("failed to query lead status counts", "org_id", orgID, "error", err)
@@ -55,7 +55,7 @@ func (h *AdminLeadsHandler) GetLeadStats(w http.ResponseWriter, r *http.Request)
 	// New this week
 	weekAgo := time.Now().AddDate(0, 0, -7)
 	if err := h.db.QueryRowContext(r.Context(),
-		`SELECT COUNT(*) FROM leads WHERE org_id = $1 AND created_at >= $2`, orgID, weekAgo,
+		`SELECT COUNT(*) FROM leads WHERE org_id = $1 AND NOT probable_wrong_number AND created_at >= $2`, orgID, weekAgo,
 	).Scan(&stats.NewThisWeek); err != nil {
 		h.logger.Error("failed to count new leads this week", "org_id", orgID, "error", err)
 	}
@@ -63,7 +63,7 @@ func (h *AdminLeadsHandler) GetLeadStats(w http.ResponseWriter, r *http.Request)
 	// New this month
 	monthAgo := time.Now().AddDate(0, -1, 0)
 	if err := h.db.QueryRowContext(r.Context(),
-		`SELECT COUNT(*) FROM leads WHERE org_id = $1 AND created_at >= $2`, orgID, monthAgo,
+		`SELECT COUNT(*) FROM leads WHERE org_id = $1 AND NOT probable_wrong_number AND created_at >= $2`, orgID, monthAgo,
 	).Scan(&stats.NewThisMonth); err != nil {
 		h.logger.Error("failed to count new leads this month", "org_id", orgID, "error", err)
 	}
@@ -73,7 +73,7 @@ func (h *AdminLeadsHandler) GetLeadStats(w http.ResponseWriter, r *http.Request)
 	if err := h.db.QueryRowContext(r.Context(),
 		`SELECT COUNT(DISTINCT l.id) FROM leads l
 		 JOIN payments p ON l.id = p.lead_id
-		 WHERE l.org_id = $1 AND p.status = 'succeeded'`, orgID,
+		 WHERE l.org_id = $1 AND NOT l.probable_wrong_number AND p.status = 'succeeded'`, orgID,
 	).Scan(&converted); err != nil {
 		h.logger.Error("failed to count converted leads", "org_id", orgID, "error", err)
 	}
@@ -26,22 +26,44 @@ func NewAdminLeadsHandler(db *sql.DB, logger *logging.Logger) *AdminLeadsHandler
 
 // LeadResponse represents a lead in API responses.
 type LeadResponse struct {
-	ID                   string   `json:"id"`
-	OrgID                string   `json:"org_id"`
-	Phone                string   `json:"phone"`
-	Name                 string   `json:"name,omitempty"`
-	Email                string   `json:"email,omitempty"`
-	Status               string   `json:"status"`
-	Source               string   `json:"source,omitempty"`
-	InterestedServices   []string `json:"interested_services,omitempty"`
-	LastContactAt        *string  `json:"last_contact_at,omitempty"`
-	ConversationJobCount int      `json:"conversation_job_count"`
-	PaymentTotal         int      `json:"payment_total_cents"`
-	BookingCount         int      `json:"booking_count"`
-	Tags                 []string `json:"tags,omitempty"`
-	Notes                string   `json:"notes,omitempty"`
-	CreatedAt            string   `json:"created_at"`
-	UpdatedAt            string   `json:"updated_at"`
+	ID                 string   `json:"id"`
+	OrgID              string   `json:"org_id"`
+	Phone              string   `json:"phone"`
+	Name               string   `json:"name,omitempty"`
+	Email              string   `json:"email,omitempty"`
+	Status             string   `json:"status"`
+	Source             string   `json:"source,omitempty"`
+	InterestedServices []string `json:"interested_services,omitempty"`
+	ServiceInterest    string   `json:"service_interest,omitempty"`
+	LastContactAt      *string  `json:"last_contact_at,omitempty"`
+	// LastActivityAt is the latest message, payment, booking or change to
+	// the lead. Set by the list endpoint.
+	LastActivityAt string `json:"last_activity_at,omitempty"`
+	// ConversationStatus, PaymentStatus and BookingStatus are the statuses
+	// of the lead's latest conversation, payment and booking. Set by the
+	// list endpoint.
+	ConversationStatus   string                     `json:"conversation_status,omitempty"`
+	PaymentStatus        string                     `json:"payment_status,omitempty"`
+	BookingStatus        string                     `json:"booking_status,omitempty"`
+	Qualification        *LeadQualificationProgress `json:"qualification,omitempty"`
+	ConversationJobCount int                        `json:"conversation_job_count"`
+	PaymentTotal         int                        `json:"payment_total_cents"`
+	BookingCount         int                        `json:"booking_count"`
+	Tags                 []string                   `json:"tags,omitempty"`
+	Notes                string                     `json:"notes,omitempty"`
+	CreatedAt            string                     `json:"created_at"`
+	UpdatedAt            string                     `json:"updated_at"`
+
+	// sortValue is the row's value for the list's sort column, for cursors.
+	sortValue string
+}
+
+// LeadQualificationProgress is how many of the booking qualifications
+// (name, service, patient type, schedule) are saved on the lead.
+type LeadQualificationProgress struct {
+	Captured int      `json:"captured"`
+	Required int      `json:"required"`
+	Missing  []string `json:"missing"`
 }
 
 // LeadsListResponse represents a paginated list of leads.
@@ -51,6 +73,8 @@ type LeadsListResponse struct {
 	Page       int            `json:"page"`
 	PageSize   int            `json:"page_size"`
 	TotalPages int            `json:"total_pages"`
+	// NextCursor fetches the following page; empty on the last page.
+	NextCursor string `json:"next_cursor,omitempty"`
 }
 
 // LeadDetailResponse represents detailed lead information.
@@ -59,7 +83,10 @@ type LeadDetailResponse struct {
 	ConversationIDs []string         `json:"conversation_ids"`
 	Payments        []PaymentSummary `json:"payments"`
 	Bookings        []BookingSummary `json:"bookings"`
-	Timeline        []TimelineEvent  `json:"timeline"`
+	// WritebackAttempts are the attempts to create the lead's bookings on
+	// the clinic's EMR, newest first.
+	WritebackAttempts []WritebackAttemptSummary `json:"writeback_attempts"`
+	Timeline          []TimelineEvent           `json:"timeline"`
 }
 
 // PaymentSummary represents a payment summary.
@@ -78,6 +105,19 @@ type BookingSummary struct {
 	Status      string `json:"status"`
 }
 
+// WritebackAttemptSummary is one attempt to create a booking on the EMR.
+// Attempts for the same booking share an idempotency key.
+type WritebackAttemptSummary struct {
+	ID             string `json:"id"`
+	IdempotencyKey string `json:"idempotency_key"`
+	Platform       string `json:"platform"`
+	Status         string `json:"status"`
+	AppointmentID  string `json:"appointment_id,omitempty"`
+	Error          string `json:"error,omitempty"`
+	StartedAt      string `json:"started_at"`
+	FinishedAt     string `json:"finished_at,omitempty"`
+}
+
 // TimelineEvent represents an event in the lead timeline.
 type TimelineEvent struct {
 	Type        string `json:"type"`
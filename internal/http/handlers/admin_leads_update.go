@@ -9,6 +9,8 @@ import (
 
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/pii"
 )
 
 // UpdateLead updates a lead's information.
@@ -39,14 +41,21 @@ func (h *AdminLeadsHandler) UpdateLead(w http.ResponseWriter, r *http.Request) {
 	args := []any{}
 	argNum := 1
 
-	if req.Name != nil {
-		updates = append(updates, "name = $"+strconv.Itoa(argNum))
-		args = append(args, *req.Name)
-		argNum++
-	}
-	if req.Email != nil {
-		updates = append(updates, "email = $"+strconv.Itoa(argNum))
-		args = append(args, *req.Email)
+	for _, field := range []struct {
+		column string
+		value  *string
+	}{{"name", req.Name}, {"email", req.Email}} {
+		if field.value == nil {
+			continue
+		}
+		sealed, err := pii.Default().Encrypt(*field.value)
+		if err != nil {
+			h.logger.Error("failed to encrypt lead field", "field", field.column, "error", err)
+			http.Error(w, "internal error", http.StatusInternalServerError)
+			return
+		}
+		updates = append(updates, field.column+" = $"+strconv.Itoa(argNum))
+		args = append(args, sealed)
 		argNum++
 	}
 	if req.Status != nil {
@@ -3,13 +3,16 @@ package handlers
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"strings"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/wolfman30/medspa-ai-platform/internal/conversation/msgschema"
 	"github.com/wolfman30/medspa-ai-platform/internal/events"
+	"github.com/wolfman30/medspa-ai-platform/internal/http/apierror"
 	"github.com/wolfman30/medspa-ai-platform/internal/messaging"
 	"github.com/wolfman30/medspa-ai-platform/internal/messaging/compliance"
 	"github.com/wolfman30/medspa-ai-platform/internal/messaging/telnyxclient"
@@ -32,6 +35,13 @@ type AdminMessagingHandler struct {
 	helpAck           string
 	metrics           *observemetrics.MessagingMetrics
 	retryBaseDelay    time.Duration
+	consent           compliance.MarketingConsentChecker
+	provisioning      numberProvisioningLookup
+}
+
+// numberProvisioningLookup reads a sending number's 10DLC campaign state.
+type numberProvisioningLookup interface {
+	Get(ctx context.Context, number string) (messaging.NumberProvisioning, error)
 }
 
 type AdminMessagingConfig struct {
@@ -45,6 +55,12 @@ type AdminMessagingConfig struct {
 	HelpAck           string
 	RetryBaseDelay    time.Duration
 	Metrics           *observemetrics.MessagingMetrics
+	// Consent gates sends flagged "purpose": "marketing" on the recipient's
+	// marketing opt-in. Without it, marketing sends are suppressed.
+	Consent compliance.MarketingConsentChecker
+	// Provisioning blocks sends from purchased numbers whose 10DLC campaign
+	// isn't approved. Numbers it doesn't track aren't blocked.
+	Provisioning numberProvisioningLookup
 }
 
 func NewAdminMessagingHandler(cfg AdminMessagingConfig) *AdminMessagingHandler {
@@ -63,10 +79,12 @@ func NewAdminMessagingHandler(cfg AdminMessagingConfig) *AdminMessagingHandler {
 		renderer:          templates.Renderer{},
 		detector:          compliance.NewDetector(),
 		messagingProfile:  cfg.MessagingProfile,
-		stopAck:           defaultString(cfg.StopAck, "You have been opted out. Reply HELP for info."),
-		helpAck:           defaultString(cfg.HelpAck, "Reply STOP to opt out or contact support@medspa.ai."),
+		stopAck:           defaultString(cfg.StopAck, messaging.DefaultStopAck),
+		helpAck:           defaultString(cfg.HelpAck, messaging.DefaultHelpAck),
 		retryBaseDelay:    cfg.RetryBaseDelay,
 		metrics:           cfg.Metrics,
+		consent:           cfg.Consent,
+		provisioning:      cfg.Provisioning,
 	}
 }
 
@@ -89,21 +107,21 @@ type createHostedOrderRequest struct {
 func (h *AdminMessagingHandler) StartHostedOrder(w http.ResponseWriter, r *http.Request) {
 	var req createHostedOrderRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "invalid json", http.StatusBadRequest)
+		apierror.Write(w, r, apierror.CodeValidationFailed, "invalid json")
 		return
 	}
 	clinicID, err := uuid.Parse(req.ClinicID)
 	if err != nil {
-		http.Error(w, "invalid clinic_id", http.StatusBadRequest)
+		apierror.Write(w, r, apierror.CodeValidationFailed, "invalid clinic_id")
 		return
 	}
 	if req.PhoneNumber == "" || req.ContactEmail == "" || req.ContactName == "" {
-		http.Error(w, "missing required fields", http.StatusBadRequest)
+		apierror.Write(w, r, apierror.CodeValidationFailed, "missing required fields")
 		return
 	}
 	if _, err := h.telnyx.CheckHostedEligibility(r.Context(), req.PhoneNumber); err != nil {
 		h.logger.Error("eligibility check failed", "error", err)
-		http.Error(w, "eligibility check failed", http.StatusBadRequest)
+		apierror.Write(w, r, apierror.CodeValidationFailed, "eligibility check failed")
 		return
 	}
 	order, err := h.telnyx.CreateHostedOrder(r.Context(), telnyxclient.HostedOrderRequest{
@@ -116,7 +134,7 @@ func (h *AdminMessagingHandler) StartHostedOrder(w http.ResponseWriter, r *http.
 	})
 	if err != nil {
 		h.logger.Error("create hosted order failed", "error", err)
-		http.Error(w, "failed to create order", http.StatusBadGateway)
+		apierror.WriteStatus(w, r, http.StatusBadGateway, apierror.CodeDependencyUnavailable, "failed to create order", nil)
 		return
 	}
 	record := messaging.HostedOrderRecord{
@@ -128,7 +146,7 @@ func (h *AdminMessagingHandler) StartHostedOrder(w http.ResponseWriter, r *http.
 	}
 	if err := h.store.UpsertHostedOrder(r.Context(), nil, record); err != nil {
 		h.logger.Error("persist hosted order failed", "error", err)
-		http.Error(w, "failed to persist order", http.StatusInternalServerError)
+		apierror.WriteError(w, r, err, apierror.CodeInternal, "failed to persist order")
 		return
 	}
 	writeJSON(w, http.StatusCreated, order)
@@ -153,12 +171,12 @@ type createBrandRequest struct {
 func (h *AdminMessagingHandler) CreateBrand(w http.ResponseWriter, r *http.Request) {
 	var req createBrandRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "invalid json", http.StatusBadRequest)
+		apierror.Write(w, r, apierror.CodeValidationFailed, "invalid json")
 		return
 	}
 	clinicID, err := uuid.Parse(req.ClinicID)
 	if err != nil {
-		http.Error(w, "invalid clinic_id", http.StatusBadRequest)
+		apierror.Write(w, r, apierror.CodeValidationFailed, "invalid clinic_id")
 		return
 	}
 	brand, err := h.telnyx.CreateBrand(r.Context(), telnyxclient.BrandRequest{
@@ -178,7 +196,7 @@ func (h *AdminMessagingHandler) CreateBrand(w http.ResponseWriter, r *http.Reque
 	})
 	if err != nil {
 		h.logger.Error("create brand failed", "error", err)
-		http.Error(w, "failed to create brand", http.StatusBadGateway)
+		apierror.WriteStatus(w, r, http.StatusBadGateway, apierror.CodeDependencyUnavailable, "failed to create brand", nil)
 		return
 	}
 	record := messaging.BrandRecord{
@@ -192,7 +210,7 @@ func (h *AdminMessagingHandler) CreateBrand(w http.ResponseWriter, r *http.Reque
 	}
 	if err := h.store.InsertBrand(r.Context(), nil, record); err != nil {
 		h.logger.Error("persist brand failed", "error", err)
-		http.Error(w, "persist brand failed", http.StatusInternalServerError)
+		apierror.WriteError(w, r, err, apierror.CodeInternal, "persist brand failed")
 		return
 	}
 	writeJSON(w, http.StatusCreated, brand)
@@ -209,12 +227,12 @@ type createCampaignRequest struct {
 func (h *AdminMessagingHandler) CreateCampaign(w http.ResponseWriter, r *http.Request) {
 	var req createCampaignRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "invalid json", http.StatusBadRequest)
+		apierror.Write(w, r, apierror.CodeValidationFailed, "invalid json")
 		return
 	}
 	brandUUID, err := uuid.Parse(req.BrandID)
 	if err != nil {
-		http.Error(w, "invalid brand_internal_id", http.StatusBadRequest)
+		apierror.Write(w, r, apierror.CodeValidationFailed, "invalid brand_internal_id")
 		return
 	}
 	campaign, err := h.telnyx.CreateCampaign(r.Context(), telnyxclient.CampaignRequest{
@@ -226,7 +244,7 @@ func (h *AdminMessagingHandler) CreateCampaign(w http.ResponseWriter, r *http.Re
 	})
 	if err != nil {
 		h.logger.Error("create campaign failed", "error", err)
-		http.Error(w, "failed to create campaign", http.StatusBadGateway)
+		apierror.WriteStatus(w, r, http.StatusBadGateway, apierror.CodeDependencyUnavailable, "failed to create campaign", nil)
 		return
 	}
 	record := messaging.CampaignRecord{
@@ -240,7 +258,7 @@ func (h *AdminMessagingHandler) CreateCampaign(w http.ResponseWriter, r *http.Re
 	}
 	if err := h.store.InsertCampaign(r.Context(), nil, record); err != nil {
 		h.logger.Error("persist campaign failed", "error", err)
-		http.Error(w, "persist campaign failed", http.StatusInternalServerError)
+		apierror.WriteError(w, r, err, apierror.CodeInternal, "persist campaign failed")
 		return
 	}
 	writeJSON(w, http.StatusCreated, campaign)
@@ -261,40 +279,69 @@ type sendMessageRequest struct {
 func (h *AdminMessagingHandler) SendMessage(w http.ResponseWriter, r *http.Request) {
 	var req sendMessageRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "invalid json", http.StatusBadRequest)
+		apierror.Write(w, r, apierror.CodeValidationFailed, "invalid json")
 		return
 	}
 	clinicID, err := uuid.Parse(req.ClinicID)
 	if err != nil {
-		http.Error(w, "invalid clinic_id", http.StatusBadRequest)
+		apierror.Write(w, r, apierror.CodeValidationFailed, "invalid clinic_id")
 		return
 	}
 	if strings.TrimSpace(req.From) == "" || strings.TrimSpace(req.To) == "" {
-		http.Error(w, "from/to required", http.StatusBadRequest)
+		apierror.Write(w, r, apierror.CodeValidationFailed, "from/to required")
 		return
 	}
 	body := strings.TrimSpace(req.Body)
 	if req.Template != "" {
 		rendered, err := h.renderer.Render("body", req.Template, req.TemplateData)
 		if err != nil {
-			http.Error(w, fmt.Sprintf("template error: %v", err), http.StatusBadRequest)
+			apierror.Write(w, r, apierror.CodeValidationFailed, fmt.Sprintf("template error: %v", err))
 			return
 		}
 		body = rendered
 	}
 	if body == "" && len(req.MediaURLs) == 0 {
-		http.Error(w, "body or media required", http.StatusBadRequest)
+		apierror.Write(w, r, apierror.CodeValidationFailed, "body or media required")
 		return
 	}
 
 	normalizedTo := messaging.NormalizeE164(req.To)
 	suppressedReason := ""
-	if unsub, err := h.store.IsUnsubscribed(r.Context(), clinicID, normalizedTo); err != nil {
-		h.logger.Error("unsubscribe check failed", "error", err)
-		http.Error(w, "unsubscribe check failed", http.StatusInternalServerError)
-		return
-	} else if unsub {
-		suppressedReason = "opt_out"
+	warning := ""
+	if h.provisioning != nil {
+		p, err := h.provisioning.Get(r.Context(), req.From)
+		switch {
+		case errors.Is(err, messaging.ErrNumberNotProvisioned):
+		case err != nil:
+			h.logger.Warn("campaign status lookup failed; sending anyway", "error", err, "from", req.From)
+		case !p.CampaignApproved():
+			suppressedReason = "campaign_not_approved"
+			warning = "message not sent: " + p.SendBlockedReason()
+			h.logger.Warn("send blocked: 10DLC campaign not approved", "clinic_id", clinicID, "from", p.PhoneNumber,
+				"campaign_id", p.CampaignID, "campaign_status", p.CampaignStatus)
+		}
+	}
+	if suppressedReason == "" {
+		unsub, err := h.store.IsUnsubscribed(r.Context(), clinicID, normalizedTo)
+		if err != nil {
+			h.logger.Error("unsubscribe check failed", "error", err)
+			apierror.WriteError(w, r, err, apierror.CodeInternal, "unsubscribe check failed")
+			return
+		}
+		if unsub {
+			suppressedReason = "opt_out"
+		}
+	}
+	// Only sends explicitly flagged as promotional need marketing consent.
+	if suppressedReason == "" && compliance.Purpose(req.Purpose) == compliance.PurposeMarketing {
+		err := compliance.RequireMarketingConsent(r.Context(), h.consent, compliance.PurposeMarketing, clinicID.String(), normalizedTo)
+		if errors.Is(err, compliance.ErrNoMarketingConsent) {
+			suppressedReason = "no_marketing_consent"
+		} else if err != nil {
+			h.logger.Error("marketing consent check failed", "error", err)
+			apierror.WriteError(w, r, err, apierror.CodeInternal, "marketing consent check failed")
+			return
+		}
 	}
 	if suppressedReason == "" && h.quietHoursEnabled {
 		purpose := compliance.Purpose(req.Purpose)
@@ -311,7 +358,7 @@ func (h *AdminMessagingHandler) SendMessage(w http.ResponseWriter, r *http.Reque
 	tx, err := h.store.Begin(ctx)
 	if err != nil {
 		h.logger.Error("begin tx failed", "error", err)
-		http.Error(w, "db error", http.StatusInternalServerError)
+		apierror.Write(w, r, apierror.CodeInternal, "db error")
 		return
 	}
 	defer tx.Rollback(ctx)
@@ -321,6 +368,7 @@ func (h *AdminMessagingHandler) SendMessage(w http.ResponseWriter, r *http.Reque
 		From:           req.From,
 		To:             normalizedTo,
 		Direction:      "outbound",
+		AuthorType:     string(msgschema.Staff),
 		Body:           body,
 		Media:          req.MediaURLs,
 		ProviderStatus: "suppressed",
@@ -351,7 +399,7 @@ func (h *AdminMessagingHandler) SendMessage(w http.ResponseWriter, r *http.Reque
 	msgID, err := h.store.InsertMessage(ctx, tx, msgRecord)
 	if err != nil {
 		h.logger.Error("insert message failed", "error", err)
-		http.Error(w, "db error", http.StatusInternalServerError)
+		apierror.Write(w, r, apierror.CodeInternal, "db error")
 		return
 	}
 
@@ -370,12 +418,12 @@ func (h *AdminMessagingHandler) SendMessage(w http.ResponseWriter, r *http.Reque
 	}
 	if _, err := events.AppendCanonicalEvent(ctx, tx, "clinic:"+clinicID.String(), req.CorrelationID, event); err != nil {
 		h.logger.Error("append event failed", "error", err)
-		http.Error(w, "event error", http.StatusInternalServerError)
+		apierror.Write(w, r, apierror.CodeInternal, "event error")
 		return
 	}
 	if err := tx.Commit(ctx); err != nil {
 		h.logger.Error("commit failed", "error", err)
-		http.Error(w, "db error", http.StatusInternalServerError)
+		apierror.Write(w, r, apierror.CodeInternal, "db error")
 		return
 	}
 
@@ -388,6 +436,9 @@ func (h *AdminMessagingHandler) SendMessage(w http.ResponseWriter, r *http.Reque
 		"provider_status":   msgRecord.ProviderStatus,
 		"suppressed_reason": suppressedReason,
 	}
+	if warning != "" {
+		response["warning"] = warning
+	}
 	writeJSON(w, http.StatusAccepted, response)
 }
 
@@ -407,17 +458,17 @@ type activateNumberRequest struct {
 func (h *AdminMessagingHandler) ActivateHostedNumber(w http.ResponseWriter, r *http.Request) {
 	var req activateNumberRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "invalid json", http.StatusBadRequest)
+		apierror.Write(w, r, apierror.CodeValidationFailed, "invalid json")
 		return
 	}
 	clinicID, err := uuid.Parse(req.ClinicID)
 	if err != nil {
-		http.Error(w, "invalid clinic_id", http.StatusBadRequest)
+		apierror.Write(w, r, apierror.CodeValidationFailed, "invalid clinic_id")
 		return
 	}
 	normalized := messaging.NormalizeE164(req.PhoneNumber)
 	if normalized == "" {
-		http.Error(w, "invalid phone_number", http.StatusBadRequest)
+		apierror.Write(w, r, apierror.CodeValidationFailed, "invalid phone_number")
 		return
 	}
 	record := messaging.HostedOrderRecord{
@@ -427,7 +478,7 @@ func (h *AdminMessagingHandler) ActivateHostedNumber(w http.ResponseWriter, r *h
 	}
 	if err := h.store.UpsertHostedOrder(r.Context(), nil, record); err != nil {
 		h.logger.Error("upsert hosted order failed", "error", err, "clinic_id", clinicID, "number", normalized)
-		http.Error(w, "failed to activate number", http.StatusInternalServerError)
+		apierror.WriteError(w, r, err, apierror.CodeInternal, "failed to activate number")
 		return
 	}
 	h.logger.Info("activated hosted number", "clinic_id", clinicID, "number", normalized)
@@ -442,22 +493,22 @@ func (h *AdminMessagingHandler) ActivateHostedNumber(w http.ResponseWriter, r *h
 func (h *AdminMessagingHandler) DeactivateHostedNumber(w http.ResponseWriter, r *http.Request) {
 	var req activateNumberRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "invalid json", http.StatusBadRequest)
+		apierror.Write(w, r, apierror.CodeValidationFailed, "invalid json")
 		return
 	}
 	clinicID, err := uuid.Parse(req.ClinicID)
 	if err != nil {
-		http.Error(w, "invalid clinic_id", http.StatusBadRequest)
+		apierror.Write(w, r, apierror.CodeValidationFailed, "invalid clinic_id")
 		return
 	}
 	normalized := messaging.NormalizeE164(req.PhoneNumber)
 	if normalized == "" {
-		http.Error(w, "invalid phone_number", http.StatusBadRequest)
+		apierror.Write(w, r, apierror.CodeValidationFailed, "invalid phone_number")
 		return
 	}
 	if err := h.store.DeleteHostedOrderByClinic(r.Context(), clinicID, normalized); err != nil {
 		h.logger.Error("delete hosted order failed", "error", err, "clinic_id", clinicID, "number", normalized)
-		http.Error(w, "failed to deactivate number", http.StatusInternalServerError)
+		apierror.WriteError(w, r, err, apierror.CodeInternal, "failed to deactivate number")
 		return
 	}
 	h.logger.Info("deactivated hosted number", "clinic_id", clinicID, "number", normalized)
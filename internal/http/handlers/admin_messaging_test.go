@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"net/http"
@@ -12,9 +13,11 @@ import (
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
 	pgxmock "github.com/pashagolub/pgxmock/v4"
+	"github.com/wolfman30/medspa-ai-platform/internal/http/apierror"
 	"github.com/wolfman30/medspa-ai-platform/internal/messaging"
 	"github.com/wolfman30/medspa-ai-platform/internal/messaging/compliance"
 	"github.com/wolfman30/medspa-ai-platform/internal/messaging/telnyxclient"
+	"github.com/wolfman30/medspa-ai-platform/internal/tenancy"
 	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
 )
 
@@ -39,7 +42,7 @@ func TestAdminSendMessageSuccess(t *testing.T) {
 		WillReturnError(pgx.ErrNoRows)
 	mock.ExpectBegin()
 	mock.ExpectQuery("INSERT INTO messages").
-		WithArgs(clinicID, "+1999", "+15555550100", "outbound", "hello", pgxmock.AnyArg(), "queued", "msg_1", pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg()).
+		WithArgs(clinicID, "+1999", "+15555550100", "outbound", "hello", pgxmock.AnyArg(), "queued", "msg_1", pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), "staff", pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg()).
 		WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(uuid.New()))
 	mock.ExpectExec("INSERT INTO outbox").
 		WithArgs(pgxmock.AnyArg(), "clinic:"+clinicID.String(), "messaging.message.sent.v1", pgxmock.AnyArg()).
@@ -80,7 +83,7 @@ func TestAdminSendMessageSuppressedByOptOut(t *testing.T) {
 		WillReturnRows(pgxmock.NewRows([]string{"exists"}).AddRow(1))
 	mock.ExpectBegin()
 	mock.ExpectQuery("INSERT INTO messages").
-		WithArgs(clinicID, "+1999", "+15555550100", "outbound", "hello", pgxmock.AnyArg(), "suppressed", "", pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg()).
+		WithArgs(clinicID, "+1999", "+15555550100", "outbound", "hello", pgxmock.AnyArg(), "suppressed", "", pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), "staff", pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg()).
 		WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(uuid.New()))
 	mock.ExpectExec("INSERT INTO outbox").
 		WithArgs(pgxmock.AnyArg(), "clinic:"+clinicID.String(), "messaging.message.sent.v1", pgxmock.AnyArg()).
@@ -196,6 +199,9 @@ func TestCreateCampaignTelnyxError(t *testing.T) {
 	if rec.Code != http.StatusBadGateway {
 		t.Fatalf("expected 502, got %d", rec.Code)
 	}
+	if body := decodeAPIError(t, rec); body.Code != apierror.CodeDependencyUnavailable {
+		t.Fatalf("expected dependency_unavailable, got %+v", body)
+	}
 }
 
 func TestSendMessageTemplateError(t *testing.T) {
@@ -217,6 +223,22 @@ func TestSendMessageTemplateError(t *testing.T) {
 	if rec.Code != http.StatusBadRequest {
 		t.Fatalf("expected 400, got %d", rec.Code)
 	}
+	if body := decodeAPIError(t, rec); body.Code != apierror.CodeValidationFailed {
+		t.Fatalf("expected validation_failed, got %+v", body)
+	}
+}
+
+// decodeAPIError parses the standard error envelope from a response.
+func decodeAPIError(t *testing.T, rec *httptest.ResponseRecorder) apierror.Body {
+	t.Helper()
+	if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+		t.Fatalf("expected JSON error, got content type %q", ct)
+	}
+	var env apierror.Envelope
+	if err := json.NewDecoder(rec.Body).Decode(&env); err != nil {
+		t.Fatalf("decode error envelope: %v", err)
+	}
+	return env.Error
 }
 
 func TestSendMessageQuietHoursSuppression(t *testing.T) {
@@ -236,7 +258,7 @@ func TestSendMessageQuietHoursSuppression(t *testing.T) {
 		WillReturnError(pgx.ErrNoRows)
 	mock.ExpectBegin()
 	mock.ExpectQuery("INSERT INTO messages").
-		WithArgs(clinicID, "+1999", "+15555550100", "outbound", "hi", pgxmock.AnyArg(), "suppressed", "", pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg()).
+		WithArgs(clinicID, "+1999", "+15555550100", "outbound", "hi", pgxmock.AnyArg(), "suppressed", "", pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), "staff", pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg()).
 		WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(uuid.New()))
 	mock.ExpectExec("INSERT INTO outbox").
 		WithArgs(pgxmock.AnyArg(), "clinic:"+clinicID.String(), "messaging.message.sent.v1", pgxmock.AnyArg()).
@@ -283,7 +305,7 @@ func TestSendMessageSchedulesRetryOnTelnyxError(t *testing.T) {
 		WillReturnError(pgx.ErrNoRows)
 	mock.ExpectBegin()
 	mock.ExpectQuery("INSERT INTO messages").
-		WithArgs(clinicID, "+1999", "+15555550100", "outbound", "hi", pgxmock.AnyArg(), "retry_pending", "", pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg()).
+		WithArgs(clinicID, "+1999", "+15555550100", "outbound", "hi", pgxmock.AnyArg(), "retry_pending", "", pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), "staff", pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg()).
 		WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(uuid.New()))
 	mock.ExpectExec("INSERT INTO outbox").
 		WithArgs(pgxmock.AnyArg(), "clinic:"+clinicID.String(), "messaging.message.sent.v1", pgxmock.AnyArg()).
@@ -335,3 +357,75 @@ func TestSendMessageUnsubscribeCheckError(t *testing.T) {
 		t.Fatalf("expected 500, got %d", rec.Code)
 	}
 }
+
+type stubMarketingConsent map[string]bool
+
+func (s stubMarketingConsent) HasMarketingConsent(ctx context.Context, scope tenancy.OrgScope, phone string) (bool, error) {
+	return s[scope.OrgID()+"|"+phone], nil
+}
+
+func TestSendMessageMarketingRequiresConsent(t *testing.T) {
+	clinicID := uuid.New()
+	cases := []struct {
+		name       string
+		consent    compliance.MarketingConsentChecker
+		purpose    string
+		wantStatus string
+		wantReason string
+	}{
+		{name: "no consent", consent: stubMarketingConsent{}, purpose: "marketing", wantStatus: "suppressed", wantReason: "no_marketing_consent"},
+		{name: "no checker", purpose: "marketing", wantStatus: "suppressed", wantReason: "no_marketing_consent"},
+		{name: "opted in", consent: stubMarketingConsent{clinicID.String() + "|+15555550100": true}, purpose: "marketing", wantStatus: "queued"},
+		{name: "transactional", consent: stubMarketingConsent{}, purpose: "transactional", wantStatus: "queued"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			mock, err := pgxmock.NewPool()
+			if err != nil {
+				t.Fatalf("pgxmock: %v", err)
+			}
+			defer mock.Close()
+			telnyx := &testTelnyxClient{sendResp: &telnyxclient.MessageResponse{ID: "msg_1", Status: "queued"}}
+			handler := NewAdminMessagingHandler(AdminMessagingConfig{
+				Store:          messaging.NewStore(mock),
+				Logger:         logging.Default(),
+				Telnyx:         telnyx,
+				RetryBaseDelay: time.Minute,
+				Consent:        tc.consent,
+			})
+
+			mock.ExpectQuery("SELECT 1 FROM unsubscribes").
+				WithArgs(clinicID, "+15555550100").
+				WillReturnError(pgx.ErrNoRows)
+			mock.ExpectBegin()
+			mock.ExpectQuery("INSERT INTO messages").
+				WithArgs(clinicID, "+1999", "+15555550100", "outbound", "20% off this week", pgxmock.AnyArg(), tc.wantStatus, pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), "staff", pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg()).
+				WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(uuid.New()))
+			mock.ExpectExec("INSERT INTO outbox").
+				WithArgs(pgxmock.AnyArg(), "clinic:"+clinicID.String(), "messaging.message.sent.v1", pgxmock.AnyArg()).
+				WillReturnResult(pgxmock.NewResult("INSERT", 1))
+			mock.ExpectCommit()
+
+			body := []byte(`{"clinic_id":"` + clinicID.String() + `","from":"+1999","to":"+15555550100","body":"20% off this week","purpose":"` + tc.purpose + `"}`)
+			rec := httptest.NewRecorder()
+			handler.SendMessage(rec, httptest.NewRequest(http.MethodPost, "/admin/messages:send", bytes.NewReader(body)))
+
+			if rec.Code != http.StatusAccepted {
+				t.Fatalf("expected 202, got %d body=%s", rec.Code, rec.Body.String())
+			}
+			var resp map[string]any
+			if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("decode: %v", err)
+			}
+			if resp["suppressed_reason"] != tc.wantReason {
+				t.Fatalf("expected suppressed_reason %q, got %v", tc.wantReason, resp["suppressed_reason"])
+			}
+			if sent := telnyx.sendCalls > 0; sent != (tc.wantReason == "") {
+				t.Fatalf("unexpected telnyx send: calls=%d", telnyx.sendCalls)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Fatalf("expectations: %v", err)
+			}
+		})
+	}
+}
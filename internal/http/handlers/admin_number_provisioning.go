@@ -0,0 +1,217 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/wolfman30/medspa-ai-platform/internal/messaging"
+	"github.com/wolfman30/medspa-ai-platform/internal/messaging/telnyxclient"
+)
+
+// numberProvisioningStore persists the provisioning state of numbers bought
+// through the admin API.
+type numberProvisioningStore interface {
+	ListByOrg(ctx context.Context, orgID string) ([]messaging.NumberProvisioning, error)
+	Upsert(ctx context.Context, p messaging.NumberProvisioning) (messaging.NumberProvisioning, error)
+	CampaignStatus(ctx context.Context, campaignID string) (string, error)
+}
+
+// numberProvisioner is the Telnyx API surface for buying numbers.
+type numberProvisioner interface {
+	SearchAvailableNumbers(ctx context.Context, req telnyxclient.NumberSearchRequest) ([]telnyxclient.AvailableNumber, error)
+	OrderNumber(ctx context.Context, req telnyxclient.NumberOrderRequest) (*telnyxclient.NumberOrder, error)
+	AssignCampaignNumber(ctx context.Context, campaignID, phoneNumber string) error
+}
+
+// SetProvisioning enables number search and purchase and adds provisioning
+// state to List. Purchased numbers are attached to messagingProfile.
+func (h *AdminNumberRoutesHandler) SetProvisioning(store numberProvisioningStore, telnyx numberProvisioner, messagingProfile string) {
+	if h == nil {
+		return
+	}
+	h.provisioning = store
+	h.telnyx = telnyx
+	h.messagingProfile = strings.TrimSpace(messagingProfile)
+}
+
+// numberStatus is a clinic number with its provisioning state, as listed by
+// GET /admin/clinics/{orgID}/numbers.
+type numberStatus struct {
+	messaging.NumberRoute
+	Provisioning      *messaging.NumberProvisioning `json:"provisioning,omitempty"`
+	SendBlockedReason string                        `json:"send_blocked_reason,omitempty"`
+}
+
+// withProvisioning pairs routes with their provisioning state. Provisioned
+// numbers without a route are listed too so a half-finished purchase shows
+// up.
+func withProvisioning(routes []messaging.NumberRoute, provisioned []messaging.NumberProvisioning) []numberStatus {
+	byNumber := make(map[string]messaging.NumberProvisioning, len(provisioned))
+	for _, p := range provisioned {
+		byNumber[p.PhoneNumber] = p
+	}
+	out := make([]numberStatus, 0, len(routes)+len(provisioned))
+	for _, route := range routes {
+		status := numberStatus{NumberRoute: route}
+		if p, ok := byNumber[route.PhoneNumber]; ok {
+			status.Provisioning = &p
+			status.SendBlockedReason = p.SendBlockedReason()
+			delete(byNumber, route.PhoneNumber)
+		}
+		out = append(out, status)
+	}
+	for _, p := range provisioned {
+		if _, ok := byNumber[p.PhoneNumber]; !ok {
+			continue
+		}
+		out = append(out, numberStatus{
+			NumberRoute:       messaging.NumberRoute{PhoneNumber: p.PhoneNumber, OrgID: p.OrgID},
+			Provisioning:      &p,
+			SendBlockedReason: p.SendBlockedReason(),
+		})
+	}
+	return out
+}
+
+// SearchAvailable handles GET /admin/clinics/{orgID}/numbers/available
+// Query params: area_code, locality, state, limit.
+func (h *AdminNumberRoutesHandler) SearchAvailable(w http.ResponseWriter, r *http.Request) {
+	if h == nil || h.telnyx == nil {
+		http.Error(w, "telnyx not configured", http.StatusServiceUnavailable)
+		return
+	}
+	q := r.URL.Query()
+	limit := 10
+	if raw := strings.TrimSpace(q.Get("limit")); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed <= 0 || parsed > 100 {
+			http.Error(w, "limit must be between 1 and 100", http.StatusBadRequest)
+			return
+		}
+		limit = parsed
+	}
+	numbers, err := h.telnyx.SearchAvailableNumbers(r.Context(), telnyxclient.NumberSearchRequest{
+		AreaCode:           q.Get("area_code"),
+		Locality:           q.Get("locality"),
+		AdministrativeArea: q.Get("state"),
+		Limit:              limit,
+	})
+	if err != nil {
+		h.logger.Error("search available numbers failed", "error", err, "org_id", chi.URLParam(r, "orgID"))
+		http.Error(w, "number search failed", http.StatusBadGateway)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"numbers": numbers})
+}
+
+type purchaseNumberRequest struct {
+	PhoneNumber    string `json:"phone_number"`
+	DefaultService string `json:"default_service"`
+	CampaignTag    string `json:"campaign_tag"`
+	CampaignID     string `json:"campaign_id"`
+}
+
+// Purchase handles POST /admin/clinics/{orgID}/numbers
+// Buys phone_number on the messaging profile, registers it to campaign_id
+// when given, and routes it to the clinic. Sends from the number stay
+// blocked until its campaign is approved.
+func (h *AdminNumberRoutesHandler) Purchase(w http.ResponseWriter, r *http.Request) {
+	if h == nil || h.store == nil || h.provisioning == nil || h.telnyx == nil {
+		http.Error(w, "number provisioning not configured", http.StatusServiceUnavailable)
+		return
+	}
+	orgID := strings.TrimSpace(chi.URLParam(r, "orgID"))
+	var req purchaseNumberRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid json", http.StatusBadRequest)
+		return
+	}
+	number := messaging.NormalizeE164(req.PhoneNumber)
+	if orgID == "" || number == "" {
+		http.Error(w, "orgID and phone_number required", http.StatusBadRequest)
+		return
+	}
+	existing, err := h.store.Get(r.Context(), number)
+	switch {
+	case err == nil && existing.OrgID != orgID:
+		http.Error(w, "number is routed to another clinic", http.StatusConflict)
+		return
+	case err != nil && !errors.Is(err, messaging.ErrOrgNotFound):
+		h.logger.Error("lookup number route failed", "error", err, "org_id", orgID, "number", number)
+		http.Error(w, "failed to purchase number", http.StatusInternalServerError)
+		return
+	}
+
+	order, err := h.telnyx.OrderNumber(r.Context(), telnyxclient.NumberOrderRequest{
+		PhoneNumbers:       []telnyxclient.OrderedNumber{{PhoneNumber: number}},
+		MessagingProfileID: h.messagingProfile,
+		CustomerReference:  orgID,
+	})
+	if err != nil {
+		h.logger.Error("number order failed", "error", err, "org_id", orgID, "number", number)
+		http.Error(w, "number order failed", http.StatusBadGateway)
+		return
+	}
+	provisioning := messaging.NumberProvisioning{
+		PhoneNumber:     number,
+		OrgID:           orgID,
+		ProviderOrderID: order.ID,
+		NumberStatus:    order.Status,
+	}
+	for _, n := range order.PhoneNumbers {
+		if messaging.NormalizeE164(n.PhoneNumber) == number && n.Status != "" {
+			provisioning.NumberStatus = n.Status
+		}
+	}
+
+	// The number is bought at this point, so a failed campaign assignment is
+	// reported rather than failing the request; the number just stays blocked.
+	var warning string
+	if campaignID := strings.TrimSpace(req.CampaignID); campaignID != "" {
+		if err := h.telnyx.AssignCampaignNumber(r.Context(), campaignID, number); err != nil {
+			h.logger.Warn("assign number to campaign failed", "error", err, "org_id", orgID, "number", number, "campaign_id", campaignID)
+			warning = "number purchased but not assigned to campaign " + campaignID + "; assign it in Telnyx before sending"
+		} else {
+			provisioning.CampaignID = campaignID
+			status, err := h.provisioning.CampaignStatus(r.Context(), campaignID)
+			if err != nil {
+				h.logger.Warn("lookup campaign status failed", "error", err, "campaign_id", campaignID)
+			}
+			provisioning.CampaignStatus = status
+		}
+	}
+
+	route, err := h.store.Upsert(r.Context(), messaging.NumberRoute{
+		PhoneNumber:    number,
+		OrgID:          orgID,
+		DefaultService: req.DefaultService,
+		CampaignTag:    req.CampaignTag,
+	})
+	if err != nil {
+		h.logger.Error("upsert number route failed", "error", err, "org_id", orgID, "number", number)
+		http.Error(w, "number purchased but routing failed", http.StatusInternalServerError)
+		return
+	}
+	saved, err := h.provisioning.Upsert(r.Context(), provisioning)
+	if err != nil {
+		h.logger.Error("save number provisioning failed", "error", err, "org_id", orgID, "number", number)
+		http.Error(w, "number purchased but provisioning state failed to save", http.StatusInternalServerError)
+		return
+	}
+	if reason := saved.SendBlockedReason(); reason != "" && warning == "" {
+		warning = "sending blocked: " + reason
+	}
+	h.logger.Info("number purchased", "org_id", orgID, "number", number, "order_id", order.ID, "campaign_id", saved.CampaignID, "campaign_status", saved.CampaignStatus)
+	resp := map[string]any{
+		"number": numberStatus{NumberRoute: route, Provisioning: &saved, SendBlockedReason: saved.SendBlockedReason()},
+	}
+	if warning != "" {
+		resp["warning"] = warning
+	}
+	writeJSON(w, http.StatusCreated, resp)
+}
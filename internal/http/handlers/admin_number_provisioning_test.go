@@ -0,0 +1,318 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	pgxmock "github.com/pashagolub/pgxmock/v4"
+	"github.com/wolfman30/medspa-ai-platform/internal/messaging"
+	"github.com/wolfman30/medspa-ai-platform/internal/messaging/telnyxclient"
+	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
+)
+
+type memNumberProvisioningStore struct {
+	numbers map[string]messaging.NumberProvisioning
+}
+
+func (m *memNumberProvisioningStore) Get(ctx context.Context, number string) (messaging.NumberProvisioning, error) {
+	p, ok := m.numbers[messaging.NormalizeE164(number)]
+	if !ok {
+		return messaging.NumberProvisioning{}, messaging.ErrNumberNotProvisioned
+	}
+	return p, nil
+}
+
+func (m *memNumberProvisioningStore) ListByOrg(ctx context.Context, orgID string) ([]messaging.NumberProvisioning, error) {
+	var out []messaging.NumberProvisioning
+	for _, p := range m.numbers {
+		if p.OrgID == orgID {
+			out = append(out, p)
+		}
+	}
+	return out, nil
+}
+
+func (m *memNumberProvisioningStore) Upsert(ctx context.Context, p messaging.NumberProvisioning) (messaging.NumberProvisioning, error) {
+	m.numbers[p.PhoneNumber] = p
+	return p, nil
+}
+
+func (m *memNumberProvisioningStore) CampaignStatus(ctx context.Context, campaignID string) (string, error) {
+	for _, p := range m.numbers {
+		if p.CampaignID == campaignID && p.CampaignStatus != "" {
+			return p.CampaignStatus, nil
+		}
+	}
+	return "", nil
+}
+
+func (m *memNumberProvisioningStore) UpdateNumberStatus(ctx context.Context, orderID, number, status string) (int64, error) {
+	p, ok := m.numbers[messaging.NormalizeE164(number)]
+	if !ok || p.ProviderOrderID != orderID {
+		return 0, nil
+	}
+	p.NumberStatus = status
+	m.numbers[p.PhoneNumber] = p
+	return 1, nil
+}
+
+func (m *memNumberProvisioningStore) UpdateCampaignStatus(ctx context.Context, campaignID, status string) (int64, error) {
+	var n int64
+	for number, p := range m.numbers {
+		if p.CampaignID == campaignID {
+			p.CampaignStatus = status
+			m.numbers[number] = p
+			n++
+		}
+	}
+	return n, nil
+}
+
+type stubNumberProvisioner struct {
+	order     *telnyxclient.NumberOrderRequest
+	assigned  map[string]string
+	assignErr error
+}
+
+func (s *stubNumberProvisioner) SearchAvailableNumbers(ctx context.Context, req telnyxclient.NumberSearchRequest) ([]telnyxclient.AvailableNumber, error) {
+	return []telnyxclient.AvailableNumber{{PhoneNumber: "+1" + req.AreaCode + "5550100"}}, nil
+}
+
+func (s *stubNumberProvisioner) OrderNumber(ctx context.Context, req telnyxclient.NumberOrderRequest) (*telnyxclient.NumberOrder, error) {
+	s.order = &req
+	return &telnyxclient.NumberOrder{
+		ID:           "ord_1",
+		Status:       "pending",
+		PhoneNumbers: []telnyxclient.OrderedNumber{{PhoneNumber: req.PhoneNumbers[0].PhoneNumber, Status: "pending"}},
+	}, nil
+}
+
+func (s *stubNumberProvisioner) AssignCampaignNumber(ctx context.Context, campaignID, phoneNumber string) error {
+	if s.assignErr != nil {
+		return s.assignErr
+	}
+	if s.assigned == nil {
+		s.assigned = map[string]string{}
+	}
+	s.assigned[phoneNumber] = campaignID
+	return nil
+}
+
+func newClinicNumbersRequest(method, orgID, path, body string) *http.Request {
+	req := httptest.NewRequest(method, "/admin/clinics/"+orgID+"/numbers"+path, strings.NewReader(body))
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("orgID", orgID)
+	return req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+}
+
+func listClinicNumbers(t *testing.T, h *AdminNumberRoutesHandler, orgID string) []numberStatus {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	h.List(rec, newClinicNumbersRequest(http.MethodGet, orgID, "", ""))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("list status = %d body=%s", rec.Code, rec.Body.String())
+	}
+	var resp struct {
+		Numbers []numberStatus `json:"numbers"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode list: %v", err)
+	}
+	return resp.Numbers
+}
+
+func postNumberEvent(t *testing.T, h *TelnyxWebhookHandler, id, eventType, payload string) {
+	t.Helper()
+	body := `{"data":{"id":"` + id + `","event_type":"` + eventType + `","occurred_at":"2026-01-02T15:04:05Z","payload":` + payload + `}}`
+	rec := httptest.NewRecorder()
+	h.HandleNumbers(rec, httptest.NewRequest(http.MethodPost, "/webhooks/telnyx/numbers", strings.NewReader(body)))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("%s status = %d body=%s", eventType, rec.Code, rec.Body.String())
+	}
+}
+
+func TestAdminNumberProvisioning_PurchaseAndStatusTransitions(t *testing.T) {
+	routes := &memNumberRouteStore{routes: map[string]messaging.NumberRoute{}}
+	provisioning := &memNumberProvisioningStore{numbers: map[string]messaging.NumberProvisioning{}}
+	telnyx := &stubNumberProvisioner{}
+	admin := NewAdminNumberRoutesHandler(routes, logging.Default())
+	admin.SetProvisioning(provisioning, telnyx, "mp_1")
+	webhooks := NewTelnyxWebhookHandler(TelnyxWebhookConfig{
+		Processed:    &stubProcessedTracker{},
+		Telnyx:       &testTelnyxClient{},
+		Logger:       logging.Default(),
+		Provisioning: provisioning,
+	})
+
+	rec := httptest.NewRecorder()
+	admin.SearchAvailable(rec, newClinicNumbersRequest(http.MethodGet, "org-1", "/available?area_code=512", ""))
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "+15125550100") {
+		t.Fatalf("search = %d %s", rec.Code, rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	admin.Purchase(rec, newClinicNumbersRequest(http.MethodPost, "org-1", "",
+		`{"phone_number":"+1 (512) 555-0100","default_service":"weight loss","campaign_id":"C1"}`))
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("purchase status = %d body=%s", rec.Code, rec.Body.String())
+	}
+	if telnyx.order == nil || telnyx.order.MessagingProfileID != "mp_1" || telnyx.order.PhoneNumbers[0].PhoneNumber != "+15125550100" {
+		t.Fatalf("unexpected order request: %+v", telnyx.order)
+	}
+	if telnyx.assigned["+15125550100"] != "C1" {
+		t.Fatalf("expected number assigned to campaign, got %v", telnyx.assigned)
+	}
+	if route := routes.routes["+15125550100"]; route.OrgID != "org-1" || route.DefaultService != "weight loss" {
+		t.Fatalf("expected route to org-1, got %+v", route)
+	}
+	if !strings.Contains(rec.Body.String(), "sending blocked") {
+		t.Fatalf("expected an operator warning for the unapproved campaign, got %s", rec.Body.String())
+	}
+
+	numbers := listClinicNumbers(t, admin, "org-1")
+	if len(numbers) != 1 || numbers[0].Provisioning == nil || numbers[0].Provisioning.NumberStatus != "pending" || numbers[0].SendBlockedReason == "" {
+		t.Fatalf("expected pending, blocked number, got %+v", numbers)
+	}
+
+	postNumberEvent(t, webhooks, "evt_order", "number_order.complete",
+		`{"id":"ord_1","status":"success","phone_numbers":[{"phone_number":"+15125550100","status":"success"}]}`)
+	postNumberEvent(t, webhooks, "evt_campaign_1", "10dlc.campaign.update", `{"campaignId":"C1","status":"MNO_PROVISIONED"}`)
+	numbers = listClinicNumbers(t, admin, "org-1")
+	if p := numbers[0].Provisioning; p.NumberStatus != "success" || p.CampaignStatus != "MNO_PROVISIONED" || numbers[0].SendBlockedReason != "" {
+		t.Fatalf("expected active, unblocked number, got %+v", numbers[0])
+	}
+
+	postNumberEvent(t, webhooks, "evt_campaign_2", "10dlc.campaign.update", `{"campaignId":"C1","status":"SUSPENDED"}`)
+	numbers = listClinicNumbers(t, admin, "org-1")
+	if !strings.Contains(numbers[0].SendBlockedReason, "SUSPENDED") {
+		t.Fatalf("expected suspended campaign to block sends, got %+v", numbers[0])
+	}
+
+	// A second number on the approved campaign inherits its status.
+	postNumberEvent(t, webhooks, "evt_campaign_3", "10dlc.campaign.update", `{"campaignId":"C1","status":"ACTIVE"}`)
+	rec = httptest.NewRecorder()
+	admin.Purchase(rec, newClinicNumbersRequest(http.MethodPost, "org-1", "", `{"phone_number":"+15125550101","campaign_id":"C1"}`))
+	if rec.Code != http.StatusCreated || strings.Contains(rec.Body.String(), "warning") {
+		t.Fatalf("second purchase = %d %s", rec.Code, rec.Body.String())
+	}
+	if p := provisioning.numbers["+15125550101"]; !p.CampaignApproved() {
+		t.Fatalf("expected inherited approval, got %+v", p)
+	}
+}
+
+func TestAdminNumberProvisioning_PurchaseRejectsOtherClinicsNumber(t *testing.T) {
+	routes := &memNumberRouteStore{routes: map[string]messaging.NumberRoute{
+		"+15125550100": {PhoneNumber: "+15125550100", OrgID: "org-2"},
+	}}
+	telnyx := &stubNumberProvisioner{}
+	admin := NewAdminNumberRoutesHandler(routes, logging.Default())
+	admin.SetProvisioning(&memNumberProvisioningStore{numbers: map[string]messaging.NumberProvisioning{}}, telnyx, "mp_1")
+
+	rec := httptest.NewRecorder()
+	admin.Purchase(rec, newClinicNumbersRequest(http.MethodPost, "org-1", "", `{"phone_number":"+15125550100"}`))
+	if rec.Code != http.StatusConflict {
+		t.Fatalf("expected 409, got %d", rec.Code)
+	}
+	if telnyx.order != nil {
+		t.Fatalf("number should not be ordered")
+	}
+}
+
+func TestAdminNumberProvisioning_CampaignAssignmentFailureWarns(t *testing.T) {
+	routes := &memNumberRouteStore{routes: map[string]messaging.NumberRoute{}}
+	provisioning := &memNumberProvisioningStore{numbers: map[string]messaging.NumberProvisioning{}}
+	admin := NewAdminNumberRoutesHandler(routes, logging.Default())
+	admin.SetProvisioning(provisioning, &stubNumberProvisioner{assignErr: errors.New("campaign full")}, "mp_1")
+
+	rec := httptest.NewRecorder()
+	admin.Purchase(rec, newClinicNumbersRequest(http.MethodPost, "org-1", "", `{"phone_number":"+15125550100","campaign_id":"C1"}`))
+	if rec.Code != http.StatusCreated || !strings.Contains(rec.Body.String(), "not assigned to campaign C1") {
+		t.Fatalf("purchase = %d %s", rec.Code, rec.Body.String())
+	}
+	if p := provisioning.numbers["+15125550100"]; p.CampaignID != "" || p.SendBlockedReason() == "" {
+		t.Fatalf("expected unregistered, blocked number, got %+v", p)
+	}
+}
+
+func TestAdminSendMessageBlockedByUnapprovedCampaign(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("pgxmock: %v", err)
+	}
+	defer mock.Close()
+	telnyx := &testTelnyxClient{}
+	handler := NewAdminMessagingHandler(AdminMessagingConfig{
+		Store:            messaging.NewStore(mock),
+		Logger:           logging.Default(),
+		Telnyx:           telnyx,
+		MessagingProfile: "profile",
+		RetryBaseDelay:   time.Minute,
+		Provisioning: &memNumberProvisioningStore{numbers: map[string]messaging.NumberProvisioning{
+			"+15125550100": {PhoneNumber: "+15125550100", OrgID: "org-1", CampaignID: "C1", CampaignStatus: "TCR_PENDING"},
+		}},
+	})
+
+	clinicID := uuid.New()
+	mock.ExpectBegin()
+	mock.ExpectQuery("INSERT INTO messages").
+		WithArgs(clinicID, "+15125550100", "+15555550100", "outbound", "hello", pgxmock.AnyArg(), "suppressed", "", pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg()).
+		WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(uuid.New()))
+	mock.ExpectExec("INSERT INTO outbox").
+		WithArgs(pgxmock.AnyArg(), "clinic:"+clinicID.String(), "messaging.message.sent.v1", pgxmock.AnyArg()).
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+	mock.ExpectCommit()
+
+	body := []byte(`{"clinic_id":"` + clinicID.String() + `","from":"+15125550100","to":"+15555550100","body":"hello"}`)
+	rec := httptest.NewRecorder()
+	handler.SendMessage(rec, httptest.NewRequest(http.MethodPost, "/admin/messages:send", bytes.NewReader(body)))
+
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("expected 202, got %d body=%s", rec.Code, rec.Body.String())
+	}
+	var resp map[string]any
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if resp["suppressed_reason"] != "campaign_not_approved" || !strings.Contains(resp["warning"].(string), "TCR_PENDING") {
+		t.Fatalf("unexpected response: %v", resp)
+	}
+	if telnyx.sendCalls != 0 {
+		t.Fatalf("blocked message should not reach telnyx")
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestTelnyxAutoReplyBlockedByUnapprovedCampaign(t *testing.T) {
+	telnyx := &testTelnyxClient{}
+	handler := NewTelnyxWebhookHandler(TelnyxWebhookConfig{
+		Telnyx:           telnyx,
+		Logger:           logging.Default(),
+		MessagingProfile: "profile",
+		Provisioning: &memNumberProvisioningStore{numbers: map[string]messaging.NumberProvisioning{
+			"+15125550100": {PhoneNumber: "+15125550100", OrgID: "org-1", CampaignID: "C1", CampaignStatus: "TCR_PENDING"},
+			"+15125550101": {PhoneNumber: "+15125550101", OrgID: "org-1", CampaignID: "C2", CampaignStatus: "ACTIVE"},
+		}},
+	})
+	ctx := context.Background()
+
+	handler.sendAutoReply(ctx, "+15125550100", "+15555550100", "STOP ACK")
+	if telnyx.sendCalls != 0 {
+		t.Fatalf("auto-reply from an unapproved campaign number reached telnyx")
+	}
+	handler.sendAutoReply(ctx, "+15125550101", "+15555550100", "STOP ACK")
+	handler.sendAutoReply(ctx, "+15559990000", "+15555550100", "STOP ACK")
+	if telnyx.sendCalls != 2 {
+		t.Fatalf("expected approved and untracked numbers to send, got %d sends", telnyx.sendCalls)
+	}
+}
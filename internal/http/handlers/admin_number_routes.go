@@ -0,0 +1,140 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/wolfman30/medspa-ai-platform/internal/messaging"
+	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
+)
+
+// numberRouteStore is the persistence contract for AdminNumberRoutesHandler.
+type numberRouteStore interface {
+	Get(ctx context.Context, number string) (messaging.NumberRoute, error)
+	ListByOrg(ctx context.Context, orgID string) ([]messaging.NumberRoute, error)
+	Upsert(ctx context.Context, route messaging.NumberRoute) (messaging.NumberRoute, error)
+	Delete(ctx context.Context, orgID, number string) error
+}
+
+// AdminNumberRoutesHandler manages the numbers in a clinic's number pool and
+// the service line each one implies.
+type AdminNumberRoutesHandler struct {
+	store  numberRouteStore
+	logger *logging.Logger
+	// provisioning and telnyx are set by SetProvisioning.
+	provisioning     numberProvisioningStore
+	telnyx           numberProvisioner
+	messagingProfile string
+}
+
+// NewAdminNumberRoutesHandler creates a new number routing handler.
+func NewAdminNumberRoutesHandler(store numberRouteStore, logger *logging.Logger) *AdminNumberRoutesHandler {
+	if logger == nil {
+		logger = logging.Default()
+	}
+	return &AdminNumberRoutesHandler{store: store, logger: logger}
+}
+
+type numberRouteRequest struct {
+	DefaultService string `json:"default_service"`
+	CampaignTag    string `json:"campaign_tag"`
+}
+
+// List handles GET /admin/clinics/{orgID}/numbers
+func (h *AdminNumberRoutesHandler) List(w http.ResponseWriter, r *http.Request) {
+	if h == nil || h.store == nil {
+		http.Error(w, "database not configured", http.StatusServiceUnavailable)
+		return
+	}
+	orgID := strings.TrimSpace(chi.URLParam(r, "orgID"))
+	routes, err := h.store.ListByOrg(r.Context(), orgID)
+	if err != nil {
+		h.logger.Error("list number routes failed", "error", err, "org_id", orgID)
+		http.Error(w, "failed to list numbers", http.StatusInternalServerError)
+		return
+	}
+	if h.provisioning == nil {
+		writeJSON(w, http.StatusOK, map[string]any{"numbers": routes})
+		return
+	}
+	provisioned, err := h.provisioning.ListByOrg(r.Context(), orgID)
+	if err != nil {
+		h.logger.Error("list number provisioning failed", "error", err, "org_id", orgID)
+		http.Error(w, "failed to list numbers", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"numbers": withProvisioning(routes, provisioned)})
+}
+
+// Put handles PUT /admin/clinics/{orgID}/numbers/{number}
+// Creates or replaces the route for number. A number already routed to a
+// different clinic is rejected with 409; delete it there first.
+func (h *AdminNumberRoutesHandler) Put(w http.ResponseWriter, r *http.Request) {
+	if h == nil || h.store == nil {
+		http.Error(w, "database not configured", http.StatusServiceUnavailable)
+		return
+	}
+	orgID := strings.TrimSpace(chi.URLParam(r, "orgID"))
+	number := messaging.NormalizeE164(chi.URLParam(r, "number"))
+	if orgID == "" || number == "" {
+		http.Error(w, "orgID and number required", http.StatusBadRequest)
+		return
+	}
+	var req numberRouteRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "invalid json", http.StatusBadRequest)
+			return
+		}
+	}
+
+	existing, err := h.store.Get(r.Context(), number)
+	switch {
+	case err == nil && existing.OrgID != orgID:
+		http.Error(w, "number is routed to another clinic", http.StatusConflict)
+		return
+	case err != nil && !errors.Is(err, messaging.ErrOrgNotFound):
+		h.logger.Error("lookup number route failed", "error", err, "org_id", orgID, "number", number)
+		http.Error(w, "failed to save number", http.StatusInternalServerError)
+		return
+	}
+
+	saved, err := h.store.Upsert(r.Context(), messaging.NumberRoute{
+		PhoneNumber:    number,
+		OrgID:          orgID,
+		DefaultService: req.DefaultService,
+		CampaignTag:    req.CampaignTag,
+	})
+	if err != nil {
+		h.logger.Error("upsert number route failed", "error", err, "org_id", orgID, "number", number)
+		http.Error(w, "failed to save number", http.StatusInternalServerError)
+		return
+	}
+	h.logger.Info("number route saved", "org_id", orgID, "number", number, "default_service", saved.DefaultService, "campaign_tag", saved.CampaignTag)
+	writeJSON(w, http.StatusOK, saved)
+}
+
+// Delete handles DELETE /admin/clinics/{orgID}/numbers/{number}
+func (h *AdminNumberRoutesHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	if h == nil || h.store == nil {
+		http.Error(w, "database not configured", http.StatusServiceUnavailable)
+		return
+	}
+	orgID := strings.TrimSpace(chi.URLParam(r, "orgID"))
+	number := messaging.NormalizeE164(chi.URLParam(r, "number"))
+	if err := h.store.Delete(r.Context(), orgID, number); err != nil {
+		if errors.Is(err, messaging.ErrOrgNotFound) {
+			http.Error(w, "number not found", http.StatusNotFound)
+			return
+		}
+		h.logger.Error("delete number route failed", "error", err, "org_id", orgID, "number", number)
+		http.Error(w, "failed to delete number", http.StatusInternalServerError)
+		return
+	}
+	h.logger.Info("number route deleted", "org_id", orgID, "number", number)
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/wolfman30/medspa-ai-platform/internal/messaging"
+	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
+)
+
+type memNumberRouteStore struct {
+	routes map[string]messaging.NumberRoute
+}
+
+func (m *memNumberRouteStore) Get(ctx context.Context, number string) (messaging.NumberRoute, error) {
+	route, ok := m.routes[number]
+	if !ok {
+		return messaging.NumberRoute{}, messaging.ErrOrgNotFound
+	}
+	return route, nil
+}
+
+func (m *memNumberRouteStore) ListByOrg(ctx context.Context, orgID string) ([]messaging.NumberRoute, error) {
+	var out []messaging.NumberRoute
+	for _, route := range m.routes {
+		if route.OrgID == orgID {
+			out = append(out, route)
+		}
+	}
+	return out, nil
+}
+
+func (m *memNumberRouteStore) Upsert(ctx context.Context, route messaging.NumberRoute) (messaging.NumberRoute, error) {
+	m.routes[route.PhoneNumber] = route
+	return route, nil
+}
+
+func (m *memNumberRouteStore) Delete(ctx context.Context, orgID, number string) error {
+	route, ok := m.routes[number]
+	if !ok || route.OrgID != orgID {
+		return messaging.ErrOrgNotFound
+	}
+	delete(m.routes, number)
+	return nil
+}
+
+func newNumberRouteRequest(method, orgID, number, body string) *http.Request {
+	req := httptest.NewRequest(method, "/admin/clinics/"+orgID+"/numbers/"+number, strings.NewReader(body))
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("orgID", orgID)
+	rctx.URLParams.Add("number", number)
+	return req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+}
+
+func TestAdminNumberRoutes_PutAndDelete(t *testing.T) {
+	store := &memNumberRouteStore{routes: map[string]messaging.NumberRoute{}}
+	h := NewAdminNumberRoutesHandler(store, logging.Default())
+
+	rec := httptest.NewRecorder()
+	h.Put(rec, newNumberRouteRequest(http.MethodPut, "org-1", "15551230001", `{"default_service":"weight loss","campaign_tag":"glp1"}`))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("put: expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	saved := store.routes["+15551230001"]
+	if saved.OrgID != "org-1" || saved.DefaultService != "weight loss" || saved.CampaignTag != "glp1" {
+		t.Fatalf("unexpected saved route: %+v", saved)
+	}
+
+	rec = httptest.NewRecorder()
+	h.Put(rec, newNumberRouteRequest(http.MethodPut, "org-2", "+15551230001", `{}`))
+	if rec.Code != http.StatusConflict {
+		t.Fatalf("expected 409 for number owned by another clinic, got %d", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	h.Delete(rec, newNumberRouteRequest(http.MethodDelete, "org-1", "+15551230001", ""))
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("delete: expected 204, got %d", rec.Code)
+	}
+	rec = httptest.NewRecorder()
+	h.Delete(rec, newNumberRouteRequest(http.MethodDelete, "org-1", "+15551230001", ""))
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("second delete: expected 404, got %d", rec.Code)
+	}
+}
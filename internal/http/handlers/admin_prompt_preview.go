@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/wolfman30/medspa-ai-platform/internal/clinic"
+	"github.com/wolfman30/medspa-ai-platform/internal/conversation"
+	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
+)
+
+// ClinicConfigGetter loads a clinic's configuration.
+type ClinicConfigGetter interface {
+	Get(ctx context.Context, orgID string) (*clinic.Config, error)
+}
+
+// AdminPromptPreviewHandler shows the system prompt assembled for a clinic.
+type AdminPromptPreviewHandler struct {
+	clinics ClinicConfigGetter
+	logger  *logging.Logger
+}
+
+// NewAdminPromptPreviewHandler creates a new prompt preview handler.
+func NewAdminPromptPreviewHandler(clinics ClinicConfigGetter, logger *logging.Logger) *AdminPromptPreviewHandler {
+	if logger == nil {
+		logger = logging.Default()
+	}
+	return &AdminPromptPreviewHandler{clinics: clinics, logger: logger}
+}
+
+// promptPreviewResponse is the body of GET prompt-preview.
+type promptPreviewResponse struct {
+	OrgID    string                       `json:"org_id"`
+	Sections []conversation.PromptSection `json:"sections"`
+	Prompt   string                       `json:"prompt"`
+}
+
+// Get handles GET /admin/clinics/{orgID}/prompt-preview
+// Returns each prompt section with the clinic's overrides applied, and the
+// full SMS system prompt a new conversation would start with.
+func (h *AdminPromptPreviewHandler) Get(w http.ResponseWriter, r *http.Request) {
+	if h == nil || h.clinics == nil {
+		http.Error(w, "clinic config not configured", http.StatusServiceUnavailable)
+		return
+	}
+	orgID := strings.TrimSpace(chi.URLParam(r, "orgID"))
+	if orgID == "" {
+		http.Error(w, "missing orgID", http.StatusBadRequest)
+		return
+	}
+	cfg, err := h.clinics.Get(r.Context(), orgID)
+	if err != nil {
+		h.logger.Error("prompt preview: load clinic config failed", "error", err, "org_id", orgID)
+		http.Error(w, "failed to load clinic config", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, promptPreviewResponse{
+		OrgID:    orgID,
+		Sections: conversation.NewPromptBuilder().Sections(cfg),
+		Prompt:   conversation.PreviewSystemPrompt(cfg),
+	})
+}
@@ -0,0 +1,61 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/wolfman30/medspa-ai-platform/internal/clinic"
+	"github.com/wolfman30/medspa-ai-platform/internal/conversation"
+	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
+)
+
+type memClinicConfigs map[string]*clinic.Config
+
+func (m memClinicConfigs) Get(ctx context.Context, orgID string) (*clinic.Config, error) {
+	if cfg, ok := m[orgID]; ok {
+		return cfg, nil
+	}
+	return clinic.DefaultConfig(orgID), nil
+}
+
+func TestAdminPromptPreview_Get(t *testing.T) {
+	cfg := clinic.DefaultConfig("org-1")
+	cfg.PromptOverrides = []clinic.PromptOverride{
+		{Section: clinic.PromptSectionClinic, Mode: clinic.PromptOverrideAppend, Text: "We do NOT offer tattoo removal."},
+	}
+	h := NewAdminPromptPreviewHandler(memClinicConfigs{"org-1": cfg}, logging.Default())
+
+	req := httptest.NewRequest(http.MethodGet, "/admin/clinics/org-1/prompt-preview", nil)
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("orgID", "org-1")
+	req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+	rec := httptest.NewRecorder()
+	h.Get(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var body struct {
+		OrgID    string                       `json:"org_id"`
+		Sections []conversation.PromptSection `json:"sections"`
+		Prompt   string                       `json:"prompt"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if body.OrgID != "org-1" || len(body.Sections) != len(clinic.PromptSections) {
+		t.Fatalf("unexpected response: %+v", body.Sections)
+	}
+	last := body.Sections[len(body.Sections)-1]
+	if last.ID != clinic.PromptSectionClinic || last.Source != conversation.PromptSourceAppended {
+		t.Fatalf("unexpected clinic section: %+v", last)
+	}
+	if !strings.Contains(body.Prompt, "We do NOT offer tattoo removal.") || !strings.Contains(body.Prompt, "EMERGENCY SYMPTOMS") {
+		t.Fatalf("expected assembled prompt with override and safety section")
+	}
+}
@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/wolfman30/medspa-ai-platform/internal/clinicdata"
+	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
+)
+
+// AdminRetentionHandler serves right-to-delete requests for individual leads.
+type AdminRetentionHandler struct {
+	purger *clinicdata.Purger
+	logger *logging.Logger
+}
+
+// NewAdminRetentionHandler creates a new lead purge handler.
+func NewAdminRetentionHandler(purger *clinicdata.Purger, logger *logging.Logger) *AdminRetentionHandler {
+	if logger == nil {
+		logger = logging.Default()
+	}
+	return &AdminRetentionHandler{purger: purger, logger: logger}
+}
+
+// PurgeLead handles POST /admin/clinics/{orgID}/leads/{leadID}/purge
+// Removes the lead's PHI across conversations, messages, Redis history, audit
+// events and notifications. Pass ?dry_run=true to preview the counts.
+func (h *AdminRetentionHandler) PurgeLead(w http.ResponseWriter, r *http.Request) {
+	if h == nil || h.purger == nil {
+		http.Error(w, "database not configured", http.StatusServiceUnavailable)
+		return
+	}
+
+	orgID := strings.TrimSpace(chi.URLParam(r, "orgID"))
+	leadID := strings.TrimSpace(chi.URLParam(r, "leadID"))
+	dryRun := false
+	if raw := r.URL.Query().Get("dry_run"); raw != "" {
+		parsed, err := strconv.ParseBool(raw)
+		if err != nil {
+			http.Error(w, "dry_run must be a boolean", http.StatusBadRequest)
+			return
+		}
+		dryRun = parsed
+	}
+
+	result, err := h.purger.PurgeLead(r.Context(), orgID, leadID, dryRun)
+	if err != nil {
+		msg := err.Error()
+		switch {
+		case errors.Is(err, clinicdata.ErrLeadNotFound):
+			http.Error(w, "lead not found", http.StatusNotFound)
+		case strings.Contains(msg, "missing orgID") || strings.Contains(msg, "must be a UUID"):
+			http.Error(w, msg, http.StatusBadRequest)
+		default:
+			h.logger.Error("admin lead purge failed", "error", err, "org_id", orgID, "lead_id", leadID)
+			http.Error(w, "failed to purge lead", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	h.logger.Info("admin lead purge completed", "org_id", orgID, "lead_id", leadID, "dry_run", dryRun)
+	writeJSON(w, http.StatusOK, result)
+}
@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/messaging/templates"
+	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
+)
+
+// AdminTemplatesHandler lists the registered outbound SMS templates so
+// compliance can review the exact copy patients receive.
+type AdminTemplatesHandler struct {
+	clinics  ClinicConfigGetter
+	registry *templates.Registry
+	global   templates.Overrides
+	logger   *logging.Logger
+}
+
+// NewAdminTemplatesHandler creates a template listing handler. global holds
+// deployment-wide overrides (the configured STOP/HELP/START replies); clinic
+// copy wins over it, as it does when sending.
+func NewAdminTemplatesHandler(clinics ClinicConfigGetter, global templates.Overrides, logger *logging.Logger) *AdminTemplatesHandler {
+	if logger == nil {
+		logger = logging.Default()
+	}
+	return &AdminTemplatesHandler{clinics: clinics, registry: templates.Default, global: global, logger: logger}
+}
+
+// adminTemplate is one registered template with the versions it sends under.
+type adminTemplate struct {
+	templates.Template
+	Versions []string              `json:"versions"`
+	Clinics  []adminClinicTemplate `json:"clinics,omitempty"`
+}
+
+// adminClinicTemplate is the copy one clinic actually sends for a template.
+type adminClinicTemplate struct {
+	OrgID      string   `json:"org_id"`
+	Overridden bool     `json:"overridden"`
+	Text       string   `json:"text,omitempty"`
+	Variants   []string `json:"variants,omitempty"`
+	Versions   []string `json:"versions"`
+}
+
+// List handles GET /admin/templates
+// Returns every registered template. Each ?org_id= adds that clinic's
+// effective copy and versions, so a stored message's template_version can be
+// matched to the text that went out.
+func (h *AdminTemplatesHandler) List(w http.ResponseWriter, r *http.Request) {
+	var orgIDs []string
+	for _, id := range r.URL.Query()["org_id"] {
+		if id = strings.TrimSpace(id); id != "" {
+			orgIDs = append(orgIDs, id)
+		}
+	}
+	if len(orgIDs) > 0 && h.clinics == nil {
+		http.Error(w, "clinic config not configured", http.StatusServiceUnavailable)
+		return
+	}
+	overrides := make([]templates.Overrides, len(orgIDs))
+	for i, orgID := range orgIDs {
+		cfg, err := h.clinics.Get(r.Context(), orgID)
+		if err != nil {
+			h.logger.Error("templates: load clinic config failed", "error", err, "org_id", orgID)
+			http.Error(w, "failed to load clinic config", http.StatusInternalServerError)
+			return
+		}
+		overrides[i] = h.global.Merge(cfg.TemplateOverrides())
+	}
+
+	list := h.registry.List()
+	out := make([]adminTemplate, 0, len(list))
+	for _, t := range list {
+		item := adminTemplate{Template: t, Versions: refVersions(h.registry.Refs(t.ID, h.global))}
+		if eff, _, overridden := h.registry.Effective(t.ID, h.global); overridden {
+			item.Template = eff
+		}
+		for i, orgID := range orgIDs {
+			eff, _, overridden := h.registry.Effective(t.ID, overrides[i])
+			item.Clinics = append(item.Clinics, adminClinicTemplate{
+				OrgID:      orgID,
+				Overridden: overridden,
+				Text:       eff.Text,
+				Variants:   eff.Variants,
+				Versions:   refVersions(h.registry.Refs(t.ID, overrides[i])),
+			})
+		}
+		out = append(out, item)
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"templates": out})
+}
+
+// refVersions returns the versions of refs, never nil.
+func refVersions(refs []templates.Ref) []string {
+	out := make([]string, 0, len(refs))
+	for _, ref := range refs {
+		out = append(out, ref.Version)
+	}
+	return out
+}
@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/clinic"
+	"github.com/wolfman30/medspa-ai-platform/internal/messaging"
+	"github.com/wolfman30/medspa-ai-platform/internal/messaging/templates"
+	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
+)
+
+func TestAdminTemplates_List(t *testing.T) {
+	cfg := clinic.DefaultConfig("org-1")
+	cfg.MessageTemplates = map[string]string{templates.PaymentFailed: "Your card was declined - reply for a new link."}
+	global := messaging.ComplianceAckOverrides("You're unsubscribed.", "", "")
+	h := NewAdminTemplatesHandler(memClinicConfigs{"org-1": cfg}, global, logging.Default())
+
+	req := httptest.NewRequest(http.MethodGet, "/admin/templates?org_id=org-1&org_id=org-2", nil)
+	rec := httptest.NewRecorder()
+	h.List(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var body struct {
+		Templates []struct {
+			ID       string   `json:"id"`
+			Text     string   `json:"text"`
+			Versions []string `json:"versions"`
+			Clinics  []struct {
+				OrgID      string   `json:"org_id"`
+				Overridden bool     `json:"overridden"`
+				Text       string   `json:"text"`
+				Versions   []string `json:"versions"`
+			} `json:"clinics"`
+		} `json:"templates"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(body.Templates) != len(templates.Default.List()) {
+		t.Fatalf("expected every registered template, got %d", len(body.Templates))
+	}
+	for _, tmpl := range body.Templates {
+		if len(tmpl.Clinics) != 2 {
+			t.Fatalf("%s: expected an entry per org, got %+v", tmpl.ID, tmpl.Clinics)
+		}
+		switch tmpl.ID {
+		case templates.PaymentFailed:
+			org1, org2 := tmpl.Clinics[0], tmpl.Clinics[1]
+			if !org1.Overridden || org1.Text != cfg.MessageTemplates[templates.PaymentFailed] || !strings.HasPrefix(org1.Versions[0], "1-") {
+				t.Fatalf("org-1 override not reported: %+v", org1)
+			}
+			if org2.Overridden || org2.Text != tmpl.Text || org2.Versions[0] != "1" {
+				t.Fatalf("org-2 should send the default: %+v", org2)
+			}
+		case templates.ComplianceStopAck:
+			if tmpl.Text != "You're unsubscribed." || !tmpl.Clinics[0].Overridden {
+				t.Fatalf("deployment STOP reply not applied: %+v", tmpl)
+			}
+		}
+	}
+}
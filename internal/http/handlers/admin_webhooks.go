@@ -0,0 +1,140 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/wolfman30/medspa-ai-platform/internal/events"
+	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
+)
+
+// WebhookReplayer re-runs a captured webhook payload through a provider's
+// handler logic, skipping signature verification.
+type WebhookReplayer interface {
+	Replay(ctx context.Context, body []byte, force bool) *events.ResponseCapture
+}
+
+type webhookEventStore interface {
+	Get(ctx context.Context, id uuid.UUID) (*events.WebhookEvent, error)
+	RecordReplay(ctx context.Context, id uuid.UUID, outcome, detail string) error
+}
+
+// AdminWebhooksHandler exposes captured webhook payloads and re-drives them.
+type AdminWebhooksHandler struct {
+	store     webhookEventStore
+	replayers map[string]WebhookReplayer
+	logger    *logging.Logger
+}
+
+// NewAdminWebhooksHandler creates a new admin webhooks handler.
+func NewAdminWebhooksHandler(store webhookEventStore, logger *logging.Logger) *AdminWebhooksHandler {
+	if logger == nil {
+		logger = logging.Default()
+	}
+	return &AdminWebhooksHandler{
+		store:     store,
+		replayers: make(map[string]WebhookReplayer),
+		logger:    logger,
+	}
+}
+
+// RegisterReplayer sets the handler used to replay events from provider.
+func (h *AdminWebhooksHandler) RegisterReplayer(provider string, replayer WebhookReplayer) {
+	if replayer == nil {
+		return
+	}
+	h.replayers[strings.TrimSpace(provider)] = replayer
+}
+
+// WebhookEventResponse is a captured webhook with its payload as text.
+type WebhookEventResponse struct {
+	events.WebhookEvent
+	Body string `json:"body"`
+}
+
+// WebhookReplayResponse reports the result of a replay.
+type WebhookReplayResponse struct {
+	ID            string `json:"id"`
+	Provider      string `json:"provider"`
+	EventID       string `json:"event_id,omitempty"`
+	Forced        bool   `json:"forced"`
+	Status        int    `json:"status"`
+	Outcome       string `json:"outcome"`
+	OutcomeDetail string `json:"outcome_detail,omitempty"`
+	ReplayedAt    string `json:"replayed_at"`
+}
+
+// GetEvent returns a captured webhook.
+// GET /admin/webhooks/{id}
+func (h *AdminWebhooksHandler) GetEvent(w http.ResponseWriter, r *http.Request) {
+	evt, ok := h.loadEvent(w, r)
+	if !ok {
+		return
+	}
+	writeJSON(w, http.StatusOK, WebhookEventResponse{WebhookEvent: *evt, Body: string(evt.Body)})
+}
+
+// Replay re-runs a captured webhook. Processed-event markers are honored, so
+// replaying an event that already took effect is a no-op; pass ?force=true for
+// events that were marked processed but never produced their side effects.
+// POST /admin/webhooks/{id}/replay
+func (h *AdminWebhooksHandler) Replay(w http.ResponseWriter, r *http.Request) {
+	evt, ok := h.loadEvent(w, r)
+	if !ok {
+		return
+	}
+	replayer, ok := h.replayers[evt.Provider]
+	if !ok {
+		jsonError(w, "replay not supported for provider "+evt.Provider, http.StatusUnprocessableEntity)
+		return
+	}
+	force, _ := strconv.ParseBool(r.URL.Query().Get("force"))
+
+	capture := replayer.Replay(r.Context(), evt.Body, force)
+	outcome, detail := capture.Outcome()
+	if err := h.store.RecordReplay(r.Context(), evt.ID, outcome, detail); err != nil {
+		h.logger.Error("failed to record webhook replay", "error", err, "webhook_id", evt.ID)
+	}
+	status := capture.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	h.logger.Info("webhook replayed", "webhook_id", evt.ID, "provider", evt.Provider,
+		"event_id", evt.EventID, "force", force, "status", status, "outcome", outcome)
+
+	writeJSON(w, http.StatusOK, WebhookReplayResponse{
+		ID:            evt.ID.String(),
+		Provider:      evt.Provider,
+		EventID:       evt.EventID,
+		Forced:        force,
+		Status:        status,
+		Outcome:       outcome,
+		OutcomeDetail: detail,
+		ReplayedAt:    time.Now().UTC().Format(time.RFC3339),
+	})
+}
+
+func (h *AdminWebhooksHandler) loadEvent(w http.ResponseWriter, r *http.Request) (*events.WebhookEvent, bool) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		jsonError(w, "invalid webhook id", http.StatusBadRequest)
+		return nil, false
+	}
+	evt, err := h.store.Get(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, events.ErrWebhookEventNotFound) {
+			jsonError(w, "webhook event not found", http.StatusNotFound)
+			return nil, false
+		}
+		h.logger.Error("failed to load webhook event", "error", err, "webhook_id", id)
+		jsonError(w, "internal server error", http.StatusInternalServerError)
+		return nil, false
+	}
+	return evt, true
+}
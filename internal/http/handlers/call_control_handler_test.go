@@ -11,6 +11,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/wolfman30/medspa-ai-platform/internal/messaging"
 	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
 )
 
@@ -56,6 +57,11 @@ func (m *testOrgResolver) ResolveOrgID(_ context.Context, phone string) (string,
 	return "", nil
 }
 
+func (m *testOrgResolver) ResolveRoute(ctx context.Context, phone string) (messaging.NumberRoute, error) {
+	orgID, err := m.ResolveOrgID(ctx, phone)
+	return messaging.NumberRoute{PhoneNumber: phone, OrgID: orgID}, err
+}
+
 func newMockTelnyxServer(capture *commandCapture) *httptest.Server {
 	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		parts := strings.Split(strings.TrimRight(r.URL.Path, "/"), "/")
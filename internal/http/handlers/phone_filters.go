@@ -3,6 +3,8 @@ package handlers
 import (
 	"fmt"
 	"strings"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/pii"
 )
 
 func phoneDigitsCandidates(raw string) []string {
@@ -32,6 +34,25 @@ func appendPhoneDigitsFilter(columnExpr string, digits []string, args *[]any, ar
 	return fmt.Sprintf(" AND %s IN (%s)", columnExpr, strings.Join(placeholders, ","))
 }
 
+// appendLeadPhoneFilter filters on a leads table phone column. With PII
+// encryption on, rows whose phone is encrypted are matched by phone_hash
+// instead of their digits.
+func appendLeadPhoneFilter(alias string, digits []string, args *[]any, argNum *int) string {
+	if len(digits) == 0 {
+		return ""
+	}
+	column := fmt.Sprintf("regexp_replace(%s.phone, '\\\\D', '', 'g')", alias)
+	hash := pii.PhoneHash(digits[0])
+	if hash == "" {
+		return appendPhoneDigitsFilter(column, digits, args, argNum)
+	}
+	filter := strings.TrimPrefix(appendPhoneDigitsFilter(column, digits, args, argNum), " AND ")
+	*args = append(*args, hash)
+	filter = fmt.Sprintf(" AND (%s OR %s.phone_hash = $%d)", filter, alias, *argNum)
+	*argNum++
+	return filter
+}
+
 func uniqueStrings(values []string) []string {
 	if len(values) < 2 {
 		return values
@@ -162,7 +162,7 @@ func (h *PortalDashboardHandler) countSuccessfulDeposits(ctx context.Context, or
 		argNum += 2
 	}
 
-	query += appendPhoneDigitsFilter("regexp_replace(l.phone, '\\\\D', '', 'g')", phoneDigits, &args, &argNum)
+	query += appendLeadPhoneFilter("l", phoneDigits, &args, &argNum)
 
 	var count int64
 	if err := h.db.QueryRowContext(ctx, query, args...).Scan(&count); err != nil {
@@ -187,7 +187,7 @@ func (h *PortalDashboardHandler) sumSuccessfulDepositAmount(ctx context.Context,
 		argNum += 2
 	}
 
-	query += appendPhoneDigitsFilter("regexp_replace(l.phone, '\\\\D', '', 'g')", phoneDigits, &args, &argNum)
+	query += appendLeadPhoneFilter("l", phoneDigits, &args, &argNum)
 
 	var total int64
 	if err := h.db.QueryRowContext(ctx, query, args...).Scan(&total); err != nil {
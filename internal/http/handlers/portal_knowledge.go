@@ -6,9 +6,11 @@ import (
 	"strings"
 
 	"github.com/go-chi/chi/v5"
+
 	"github.com/wolfman30/medspa-ai-platform/internal/compliance"
 	"github.com/wolfman30/medspa-ai-platform/internal/conversation"
 	httpmiddleware "github.com/wolfman30/medspa-ai-platform/internal/http/middleware"
+	"github.com/wolfman30/medspa-ai-platform/internal/tenancy"
 	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
 )
 
@@ -34,7 +36,8 @@ func NewPortalKnowledgeHandler(repo conversation.KnowledgeRepository, audit *com
 // GetKnowledge returns clinic knowledge.
 // GET /portal/orgs/{orgID}/knowledge
 func (h *PortalKnowledgeHandler) GetKnowledge(w http.ResponseWriter, r *http.Request) {
-	orgID := strings.TrimSpace(chi.URLParam(r, "orgID"))
+	scope := tenancy.ScopeFromContext(r.Context())
+	orgID := scope.OrgID()
 	if orgID == "" {
 		jsonError(w, "missing orgID", http.StatusBadRequest)
 		return
@@ -44,7 +47,7 @@ func (h *PortalKnowledgeHandler) GetKnowledge(w http.ResponseWriter, r *http.Req
 		return
 	}
 
-	docs, err := h.repo.GetDocuments(r.Context(), orgID)
+	docs, err := h.repo.GetDocuments(r.Context(), scope)
 	if err != nil {
 		h.logger.Error("failed to fetch knowledge", "org_id", orgID, "error", err)
 		jsonError(w, "internal error", http.StatusInternalServerError)
@@ -63,7 +66,8 @@ func (h *PortalKnowledgeHandler) GetKnowledge(w http.ResponseWriter, r *http.Req
 // PutKnowledge replaces clinic knowledge.
 // PUT /portal/orgs/{orgID}/knowledge
 func (h *PortalKnowledgeHandler) PutKnowledge(w http.ResponseWriter, r *http.Request) {
-	orgID := strings.TrimSpace(chi.URLParam(r, "orgID"))
+	scope := tenancy.ScopeFromContext(r.Context())
+	orgID := scope.OrgID()
 	if orgID == "" {
 		jsonError(w, "missing orgID", http.StatusBadRequest)
 		return
@@ -97,20 +101,20 @@ func (h *PortalKnowledgeHandler) PutKnowledge(w http.ResponseWriter, r *http.Req
 		return
 	}
 
-	if err := replacer.ReplaceDocuments(r.Context(), orgID, documents); err != nil {
+	if err := replacer.ReplaceDocuments(r.Context(), scope, documents); err != nil {
 		h.logger.Error("failed to replace knowledge", "org_id", orgID, "error", err)
 		jsonError(w, "internal error", http.StatusInternalServerError)
 		return
 	}
 
 	if versioner, ok := h.repo.(conversation.KnowledgeVersioner); ok {
-		version, err := versioner.GetVersion(r.Context(), orgID)
+		version, err := versioner.GetVersion(r.Context(), scope)
 		if err != nil {
 			h.logger.Error("failed to read knowledge version", "org_id", orgID, "error", err)
 			jsonError(w, "internal error", http.StatusInternalServerError)
 			return
 		}
-		if err := versioner.SetVersion(r.Context(), orgID, version+1); err != nil {
+		if err := versioner.SetVersion(r.Context(), scope, version+1); err != nil {
 			h.logger.Error("failed to bump knowledge version", "org_id", orgID, "error", err)
 			jsonError(w, "internal error", http.StatusInternalServerError)
 			return
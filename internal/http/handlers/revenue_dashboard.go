@@ -139,7 +139,8 @@ func (h *RevenueDashboardHandler) loadCoreMetrics(r *http.Request, resp *Revenue
 			COUNT(*) FILTER (WHERE COALESCE(ai_message_count, 0) > 0) AS conversations_started,
 			COUNT(*) FILTER (WHERE status = 'booked') AS appointments_booked,
 			COUNT(*) FILTER (WHERE status IN ('qualified', 'booked')) AS qualified
-		FROM conversations` + where
+		FROM conversations` + where + `
+		  AND NOT EXISTS (SELECT 1 FROM leads l WHERE l.id = conversations.lead_id AND l.probable_wrong_number)`
 	if err := h.db.QueryRowContext(r.Context(), q, args...).Scan(
 		&resp.MissedCallsCaught,
 		&resp.ConversationsStarted,
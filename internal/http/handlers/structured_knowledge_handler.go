@@ -10,8 +10,10 @@ import (
 	"time"
 
 	"github.com/go-chi/chi/v5"
+
 	"github.com/wolfman30/medspa-ai-platform/internal/clinic"
 	"github.com/wolfman30/medspa-ai-platform/internal/conversation"
+	"github.com/wolfman30/medspa-ai-platform/internal/tenancy"
 	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
 )
 
@@ -68,7 +70,8 @@ func (h *StructuredKnowledgeHandler) GetStructuredKnowledge(w http.ResponseWrite
 
 // PutStructuredKnowledge replaces structured knowledge and auto-derives config.
 func (h *StructuredKnowledgeHandler) PutStructuredKnowledge(w http.ResponseWriter, r *http.Request) {
-	orgID := strings.TrimSpace(chi.URLParam(r, "orgID"))
+	scope := tenancy.ScopeFromContext(r.Context())
+	orgID := scope.OrgID()
 	if orgID == "" {
 		jsonError(w, "missing orgID", http.StatusBadRequest)
 		return
@@ -93,6 +96,14 @@ func (h *StructuredKnowledgeHandler) PutStructuredKnowledge(w http.ResponseWrite
 		jsonError(w, "deposit policy is required", http.StatusBadRequest)
 		return
 	}
+	prices := make(map[string]string, len(sk.Sections.Services.Items))
+	for _, svc := range sk.Sections.Services.Items {
+		prices[svc.Name] = svc.Price
+	}
+	if err := clinic.ValidateServicePriceText(prices); err != nil {
+		jsonError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 
 	sk.OrgID = orgID
 	sk.UpdatedAt = time.Now().UTC()
@@ -134,7 +145,7 @@ func (h *StructuredKnowledgeHandler) PutStructuredKnowledge(w http.ResponseWrite
 		return
 	}
 	if replacer, ok := h.knowledgeRepo.(conversation.KnowledgeReplacer); ok {
-		if err := replacer.ReplaceDocuments(r.Context(), orgID, ragDocs); err != nil {
+		if err := replacer.ReplaceDocuments(r.Context(), scope, ragDocs); err != nil {
 			h.logger.Error("failed to replace RAG docs", "org_id", orgID, "error", err)
 			jsonError(w, "internal error", http.StatusInternalServerError)
 			return
@@ -142,8 +153,8 @@ func (h *StructuredKnowledgeHandler) PutStructuredKnowledge(w http.ResponseWrite
 	}
 	// Bump knowledge version
 	if versioner, ok := h.knowledgeRepo.(conversation.KnowledgeVersioner); ok {
-		ver, _ := versioner.GetVersion(r.Context(), orgID)
-		_ = versioner.SetVersion(r.Context(), orgID, ver+1)
+		ver, _ := versioner.GetVersion(r.Context(), scope)
+		_ = versioner.SetVersion(r.Context(), scope, ver+1)
 	}
 
 	w.Header().Set("Content-Type", "application/json")
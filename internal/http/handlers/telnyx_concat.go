@@ -0,0 +1,316 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf16"
+
+	"github.com/redis/go-redis/v9"
+	observemetrics "github.com/wolfman30/medspa-ai-platform/internal/observability/metrics"
+	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
+)
+
+// Concatenated SMS segments carry a UDH header, leaving 153 GSM-7 septets or
+// 67 UCS-2 code units of text per part. A carrier that won't split an escape
+// sequence or surrogate pair sends one unit less.
+const (
+	gsmSegmentChars  = 153
+	ucs2SegmentChars = 67
+)
+
+// concatDueKey is a sorted set of conversation IDs with an open buffer,
+// scored by the unix millisecond deadline at which the group is flushed.
+const concatDueKey = "telnyx:concat:due"
+
+// concatPollInterval is how often Run looks for groups past their deadline.
+const concatPollInterval = time.Second
+
+// inboundPart is one piece of a multi-part inbound message held in Redis.
+type inboundPart struct {
+	MessageID  string    `json:"message_id"`
+	Text       string    `json:"text"`
+	Seq        int       `json:"seq,omitempty"`
+	Total      int       `json:"total,omitempty"`
+	ReceivedAt time.Time `json:"received_at"`
+	// Envelope is the caller's dispatch context, handed back with the
+	// combined text so any instance can deliver the group.
+	Envelope json.RawMessage `json:"envelope,omitempty"`
+}
+
+// multipart reports whether the part looks like a piece of a longer message:
+// it carries concat metadata or fills a whole segment.
+func (p inboundPart) multipart() bool {
+	return p.Total > 1 || looksLikeSegment(p.Text)
+}
+
+// looksLikeSegment reports whether text is exactly as long as a full segment.
+// A part of a split message can't be longer, and a shorter text is either a
+// whole message or the last part of a group that is already buffered.
+func looksLikeSegment(text string) bool {
+	septets, units := 0, 0
+	ucs2 := false
+	for _, r := range text {
+		if r > 0x7f {
+			ucs2 = true
+		}
+		switch r {
+		case '^', '{', '}', '\\', '[', '~', ']', '|':
+			septets += 2
+		default:
+			septets++
+		}
+		units += utf16.RuneLen(r)
+	}
+	if ucs2 {
+		return units == ucs2SegmentChars || units == ucs2SegmentChars-1
+	}
+	return septets == gsmSegmentChars || septets == gsmSegmentChars-1
+}
+
+// ConcatDeliverFunc receives a combined message along with the envelope
+// stored with its first part.
+type ConcatDeliverFunc func(ctx context.Context, conversationID string, envelope json.RawMessage, text string)
+
+// InboundConcatBuffer holds the parts of a split inbound message in Redis for
+// a short window so the conversation pipeline sees one combined message. The
+// parts and the flush deadline both live in Redis, so a group buffered by one
+// instance is flushed by whichever instance's Run sees the deadline pass.
+type InboundConcatBuffer struct {
+	redis        *redis.Client
+	window       time.Duration
+	pollInterval time.Duration
+	metrics      *observemetrics.MessagingMetrics
+	logger       *logging.Logger
+}
+
+// NewInboundConcatBuffer creates a buffer that flushes window after the first part.
+func NewInboundConcatBuffer(client *redis.Client, window time.Duration, metrics *observemetrics.MessagingMetrics, logger *logging.Logger) *InboundConcatBuffer {
+	if logger == nil {
+		logger = logging.Default()
+	}
+	return &InboundConcatBuffer{redis: client, window: window, pollInterval: concatPollInterval, metrics: metrics, logger: logger}
+}
+
+func concatKey(conversationID string) string {
+	return "telnyx:concat:" + conversationID
+}
+
+// offerPartScript buffers a part when it looks multi-part or a group is
+// already open, scheduling the flush when it opens the group. It returns the
+// group's length, or 0 when the part wasn't buffered.
+var offerPartScript = redis.NewScript(`
+if redis.call('EXISTS', KEYS[1]) == 0 and ARGV[2] == '0' then
+	return 0
+end
+local n = redis.call('RPUSH', KEYS[1], ARGV[1])
+redis.call('PEXPIRE', KEYS[1], ARGV[4])
+if n == 1 then
+	redis.call('ZADD', KEYS[2], ARGV[3], ARGV[5])
+end
+return n
+`)
+
+// claimGroupScript removes a group from the schedule and takes its parts,
+// returning nothing when another caller claimed it first.
+var claimGroupScript = redis.NewScript(`
+if redis.call('ZREM', KEYS[2], ARGV[1]) == 0 then
+	return {}
+end
+local parts = redis.call('LRANGE', KEYS[1], 0, -1)
+redis.call('DEL', KEYS[1])
+return parts
+`)
+
+// Offer buffers part under conversationID. It returns false when the part is
+// a standalone message and no buffer is open, in which case the caller should
+// dispatch it directly. A group whose numbered parts have all arrived is
+// delivered straight away; otherwise Run delivers it once the window elapses.
+func (b *InboundConcatBuffer) Offer(ctx context.Context, conversationID string, part inboundPart, deliver ConcatDeliverFunc) (bool, error) {
+	if b == nil || b.redis == nil || b.window <= 0 {
+		return false, nil
+	}
+	key := concatKey(conversationID)
+	raw, err := json.Marshal(part)
+	if err != nil {
+		return false, fmt.Errorf("concat: encode part: %w", err)
+	}
+	multipart := "0"
+	if part.multipart() {
+		multipart = "1"
+	}
+	deadline := time.Now().Add(b.window).UnixMilli()
+	n, err := offerPartScript.Run(ctx, b.redis, []string{key, concatDueKey},
+		raw, multipart, deadline, (3 * b.window).Milliseconds(), conversationID).Int()
+	if err != nil {
+		// The reply may be lost after the part was stored; only hand it back
+		// for direct dispatch when it is known not to be buffered.
+		if held, checkErr := b.holds(ctx, key, part.MessageID); checkErr == nil && held {
+			return true, fmt.Errorf("concat: buffer part: %w", err)
+		}
+		return false, fmt.Errorf("concat: buffer part: %w", err)
+	}
+	if n == 0 {
+		return false, nil
+	}
+
+	if part.Total > 1 {
+		parts, err := b.peek(ctx, key)
+		if err != nil {
+			// The group is scheduled, so Run still delivers it.
+			return true, err
+		}
+		if complete(parts) {
+			b.flush(ctx, conversationID, "complete", deliver)
+		}
+	}
+	return true, nil
+}
+
+// Run delivers groups whose window has elapsed until ctx is cancelled.
+func (b *InboundConcatBuffer) Run(ctx context.Context, deliver ConcatDeliverFunc) {
+	if b == nil || b.redis == nil || b.window <= 0 {
+		return
+	}
+	ticker := time.NewTicker(b.pollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			b.flushDue(ctx, deliver)
+		}
+	}
+}
+
+// flushDue delivers every group past its deadline.
+func (b *InboundConcatBuffer) flushDue(ctx context.Context, deliver ConcatDeliverFunc) {
+	due, err := b.redis.ZRangeByScore(ctx, concatDueKey, &redis.ZRangeBy{
+		Min: "-inf",
+		Max: strconv.FormatInt(time.Now().UnixMilli(), 10),
+	}).Result()
+	if err != nil {
+		b.logger.Error("failed to list due inbound message groups", "error", err)
+		return
+	}
+	for _, conversationID := range due {
+		b.flush(ctx, conversationID, "timeout", deliver)
+	}
+}
+
+func (b *InboundConcatBuffer) peek(ctx context.Context, key string) ([]inboundPart, error) {
+	values, err := b.redis.LRange(ctx, key, 0, -1).Result()
+	if err != nil {
+		return nil, fmt.Errorf("concat: read parts: %w", err)
+	}
+	return decodeParts(values), nil
+}
+
+// holds reports whether the buffered group contains the message.
+func (b *InboundConcatBuffer) holds(ctx context.Context, key, messageID string) (bool, error) {
+	parts, err := b.peek(ctx, key)
+	if err != nil {
+		return false, err
+	}
+	for _, p := range parts {
+		if p.MessageID == messageID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// claim atomically unschedules the group and takes its parts, so only one
+// caller (completion or any instance's Run) delivers a group.
+func (b *InboundConcatBuffer) claim(ctx context.Context, conversationID string) ([]inboundPart, error) {
+	values, err := claimGroupScript.Run(ctx, b.redis, []string{concatKey(conversationID), concatDueKey}, conversationID).StringSlice()
+	if err != nil {
+		return nil, fmt.Errorf("concat: take parts: %w", err)
+	}
+	return decodeParts(values), nil
+}
+
+func (b *InboundConcatBuffer) flush(ctx context.Context, conversationID, trigger string, deliver ConcatDeliverFunc) {
+	parts, err := b.claim(ctx, conversationID)
+	if err != nil {
+		b.logger.Error("failed to flush inbound message parts", "error", err, "conversation_id", conversationID)
+		return
+	}
+	if len(parts) == 0 {
+		return
+	}
+	b.metrics.ObserveCombinedInbound(trigger, len(parts))
+	b.logger.Info("combined multi-part inbound message",
+		"conversation_id", conversationID,
+		"parts", len(parts),
+		"trigger", trigger,
+	)
+	text := combineParts(parts)
+	deliver(ctx, conversationID, parts[0].Envelope, text)
+}
+
+func decodeParts(values []string) []inboundPart {
+	parts := make([]inboundPart, 0, len(values))
+	for _, v := range values {
+		var p inboundPart
+		if err := json.Unmarshal([]byte(v), &p); err == nil {
+			parts = append(parts, p)
+		}
+	}
+	return parts
+}
+
+// complete reports whether every numbered part of the group has arrived.
+func complete(parts []inboundPart) bool {
+	total := 0
+	seen := make(map[int]bool)
+	for _, p := range parts {
+		if p.Total > total {
+			total = p.Total
+		}
+		if p.Seq > 0 {
+			seen[p.Seq] = true
+		}
+	}
+	return total > 1 && len(seen) >= total
+}
+
+// combineParts orders parts by sequence number (or arrival time) and joins
+// them. Segments split mid-word, so a part that filled a whole segment is
+// joined without a separator.
+func combineParts(parts []inboundPart) string {
+	numbered := true
+	for _, p := range parts {
+		if p.Seq <= 0 {
+			numbered = false
+			break
+		}
+	}
+	sort.SliceStable(parts, func(i, j int) bool {
+		if numbered {
+			return parts[i].Seq < parts[j].Seq
+		}
+		return parts[i].ReceivedAt.Before(parts[j].ReceivedAt)
+	})
+
+	var sb strings.Builder
+	seen := make(map[int]bool)
+	for i, p := range parts {
+		if numbered {
+			if seen[p.Seq] {
+				continue
+			}
+			seen[p.Seq] = true
+		}
+		if i > 0 && !numbered && !looksLikeSegment(parts[i-1].Text) {
+			sb.WriteString(" ")
+		}
+		sb.WriteString(p.Text)
+	}
+	return strings.TrimSpace(sb.String())
+}
@@ -0,0 +1,284 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	miniredis "github.com/alicebob/miniredis/v2"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
+)
+
+type concatRecorder struct {
+	mu        sync.Mutex
+	msgs      []string
+	envelopes []string
+}
+
+func (r *concatRecorder) deliver(_ context.Context, _ string, envelope json.RawMessage, text string) {
+	r.mu.Lock()
+	r.msgs = append(r.msgs, text)
+	r.envelopes = append(r.envelopes, string(envelope))
+	r.mu.Unlock()
+}
+
+func (r *concatRecorder) envelopesSeen() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.envelopes...)
+}
+
+func (r *concatRecorder) wait(t *testing.T, n int) []string {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for len(r.all()) < n && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	return r.all()
+}
+
+func (r *concatRecorder) all() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.msgs...)
+}
+
+func newTestConcatBuffer(t *testing.T, window time.Duration) (*InboundConcatBuffer, *miniredis.Miniredis) {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+	buf := NewInboundConcatBuffer(client, window, nil, logging.Default())
+	buf.pollInterval = 10 * time.Millisecond
+	return buf, mr
+}
+
+// runFlusher runs buf's flusher until the test ends.
+func runFlusher(t *testing.T, buf *InboundConcatBuffer, deliver ConcatDeliverFunc) {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		buf.Run(ctx, deliver)
+		close(done)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+}
+
+func TestInboundConcatBuffer_OutOfOrderPartsCombined(t *testing.T) {
+	buf, mr := newTestConcatBuffer(t, time.Minute)
+	rec := &concatRecorder{}
+	ctx := context.Background()
+	now := time.Now()
+
+	parts := []inboundPart{
+		{MessageID: "m3", Text: "next Tuesday afternoon?", Seq: 3, Total: 3, ReceivedAt: now},
+		{MessageID: "m1", Text: "Hi, I was hoping to book Bo", Seq: 1, Total: 3, ReceivedAt: now.Add(time.Second)},
+		{MessageID: "m2", Text: "tox for my forehead lines, any openings ", Seq: 2, Total: 3, ReceivedAt: now.Add(2 * time.Second)},
+	}
+	for i, p := range parts {
+		buffered, err := buf.Offer(ctx, "conv-1", p, rec.deliver)
+		if err != nil {
+			t.Fatalf("offer %d: %v", i, err)
+		}
+		if !buffered {
+			t.Fatalf("expected part %d to be buffered", i)
+		}
+		if i < 2 && len(rec.all()) != 0 {
+			t.Fatalf("delivered before all parts arrived: %v", rec.all())
+		}
+	}
+
+	want := "Hi, I was hoping to book Botox for my forehead lines, any openings next Tuesday afternoon?"
+	if got := rec.all(); len(got) != 1 || got[0] != want {
+		t.Fatalf("delivered %q, want [%q]", got, want)
+	}
+	if mr.Exists(concatKey("conv-1")) {
+		t.Fatal("expected buffer cleared after delivery")
+	}
+}
+
+func TestInboundConcatBuffer_LoneMessageNotBuffered(t *testing.T) {
+	buf, mr := newTestConcatBuffer(t, time.Minute)
+	rec := &concatRecorder{}
+
+	buffered, err := buf.Offer(context.Background(), "conv-1", inboundPart{MessageID: "m1", Text: "Do you do lip filler?"}, rec.deliver)
+	if err != nil {
+		t.Fatalf("offer: %v", err)
+	}
+	if buffered {
+		t.Fatal("expected lone message to skip the buffer")
+	}
+	if len(mr.Keys()) != 0 {
+		t.Fatalf("expected nothing stored, got keys %v", mr.Keys())
+	}
+	if len(rec.all()) != 0 {
+		t.Fatalf("expected no delivery from buffer, got %v", rec.all())
+	}
+}
+
+func TestInboundConcatBuffer_FlushOnTimeout(t *testing.T) {
+	buf, _ := newTestConcatBuffer(t, 50*time.Millisecond)
+	rec := &concatRecorder{}
+	ctx := context.Background()
+	now := time.Now()
+
+	// No concat metadata: the first part fills a whole segment, the second
+	// arrives while the buffer is open.
+	first := strings.Repeat("a", gsmSegmentChars-3) + " Bo"
+	if buffered, err := buf.Offer(ctx, "conv-1", inboundPart{MessageID: "m1", Text: first, ReceivedAt: now}, rec.deliver); err != nil || !buffered {
+		t.Fatalf("first part: buffered=%v err=%v", buffered, err)
+	}
+	if buffered, err := buf.Offer(ctx, "conv-1", inboundPart{MessageID: "m2", Text: "tox please", ReceivedAt: now.Add(time.Second)}, rec.deliver); err != nil || !buffered {
+		t.Fatalf("second part: buffered=%v err=%v", buffered, err)
+	}
+	if len(rec.all()) != 0 {
+		t.Fatal("expected delivery to wait for the window")
+	}
+
+	runFlusher(t, buf, rec.deliver)
+	got := rec.wait(t, 1)
+	if len(got) != 1 {
+		t.Fatalf("expected one combined delivery, got %d", len(got))
+	}
+	if !strings.HasSuffix(got[0], " Botox please") {
+		t.Fatalf("expected parts joined without a separator, got %q", got[0])
+	}
+}
+
+func TestInboundConcatBuffer_FlushedByAnotherInstance(t *testing.T) {
+	buf, mr := newTestConcatBuffer(t, 20*time.Millisecond)
+	ctx := context.Background()
+	first := strings.Repeat("a", gsmSegmentChars-3) + " Bo"
+
+	// The instance that buffered the part goes away before the window ends.
+	if buffered, err := buf.Offer(ctx, "conv-1", inboundPart{MessageID: "m1", Text: first, ReceivedAt: time.Now(), Envelope: json.RawMessage(`{"event":{"id":"evt-1"}}`)}, nil); err != nil || !buffered {
+		t.Fatalf("offer: buffered=%v err=%v", buffered, err)
+	}
+
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+	other := NewInboundConcatBuffer(client, 20*time.Millisecond, nil, logging.Default())
+	other.pollInterval = 10 * time.Millisecond
+	rec := &concatRecorder{}
+	runFlusher(t, other, rec.deliver)
+	// A second flusher racing for the same group must not deliver it again.
+	runFlusher(t, buf, rec.deliver)
+
+	if got := rec.wait(t, 1); len(got) != 1 || got[0] != first {
+		t.Fatalf("delivered %q, want the buffered part once", got)
+	}
+	time.Sleep(50 * time.Millisecond)
+	if got := rec.all(); len(got) != 1 {
+		t.Fatalf("expected a single delivery, got %d", len(got))
+	}
+	if env := rec.envelopesSeen(); env[0] != `{"event":{"id":"evt-1"}}` {
+		t.Fatalf("expected the stored envelope, got %q", env[0])
+	}
+	if mr.Exists(concatKey("conv-1")) {
+		t.Fatal("expected buffer cleared after delivery")
+	}
+	if members, _ := mr.ZMembers(concatDueKey); len(members) != 0 {
+		t.Fatalf("expected nothing left scheduled, got %v", members)
+	}
+}
+
+func TestLooksLikeSegment(t *testing.T) {
+	cases := map[string]struct {
+		text string
+		want bool
+	}{
+		"full gsm segment":            {strings.Repeat("a", gsmSegmentChars), true},
+		"gsm segment short an escape": {strings.Repeat("a", gsmSegmentChars-1), true},
+		"escapes count double":        {strings.Repeat("a", gsmSegmentChars-2) + "{", true},
+		"full ucs2 segment":           {strings.Repeat("é", ucs2SegmentChars), true},
+		"short message":               {"Do you do lip filler?", false},
+		"single-segment long message": {strings.Repeat("a", 160), false},
+		"longer than any segment":     {strings.Repeat("a", 400), false},
+		"long ucs2 message":           {strings.Repeat("é", 70), false},
+	}
+	for name, tc := range cases {
+		if got := looksLikeSegment(tc.text); got != tc.want {
+			t.Errorf("%s: looksLikeSegment(len %d) = %v, want %v", name, len(tc.text), got, tc.want)
+		}
+	}
+}
+
+func TestInboundConcatBuffer_LongSingleMessageNotBuffered(t *testing.T) {
+	buf, mr := newTestConcatBuffer(t, time.Minute)
+	rec := &concatRecorder{}
+	text := strings.Repeat("I'd like to ask about Botox pricing. ", 6)
+
+	buffered, err := buf.Offer(context.Background(), "conv-1", inboundPart{MessageID: "m1", Text: text}, rec.deliver)
+	if err != nil {
+		t.Fatalf("offer: %v", err)
+	}
+	if buffered {
+		t.Fatalf("expected a %d-character message that can't be a segment to skip the buffer", len(text))
+	}
+	if len(mr.Keys()) != 0 {
+		t.Fatalf("expected nothing stored, got keys %v", mr.Keys())
+	}
+}
+
+func TestTelnyxMessagePayload_RCSText(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  string
+		want string
+	}{
+		{"sms text", `{"type":"SMS","text":"hello"}`, "hello"},
+		{"rcs object body", `{"type":"RCS","body":{"text":"book botox"}}`, "book botox"},
+		{"rcs string body", `{"type":"RCS","body":"book filler"}`, "book filler"},
+		{"empty", `{"type":"RCS"}`, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var p telnyxMessagePayload
+			if err := json.Unmarshal([]byte(tt.raw), &p); err != nil {
+				t.Fatalf("unmarshal: %v", err)
+			}
+			if got := p.MessageText(); got != tt.want {
+				t.Fatalf("MessageText() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTelnyxDispatchInbound_NormalizesAfterCombining(t *testing.T) {
+	buf, _ := newTestConcatBuffer(t, time.Minute)
+	publisher := &stubConversationPublisher{}
+	handler := NewTelnyxWebhookHandler(TelnyxWebhookConfig{
+		Conversation: publisher,
+		ConcatBuffer: buf,
+		Logger:       logging.Default(),
+	})
+
+	clinicID := uuid.New()
+	now := time.Now().UTC()
+	for i, text := range []string{"I'd like to book ", "Botox\u200b please"} {
+		payload := telnyxMessagePayload{
+			ID:            fmt.Sprintf("m%d", i+1),
+			FromNumberRaw: "+15550001111",
+			ToNumberRaw:   "+15559998888",
+			Concat:        &telnyxConcatInfo{PartNumber: i + 1, TotalParts: 2},
+		}
+		handler.dispatchInbound(context.Background(), telnyxEvent{ID: fmt.Sprintf("evt-%d", i+1), OccurredAt: now}, payload, clinicID, "conv-1", text)
+	}
+
+	if publisher.calls != 1 {
+		t.Fatalf("expected one combined dispatch, got %d", publisher.calls)
+	}
+	if want := "I'd like to book Botox please"; publisher.last.Message != want {
+		t.Fatalf("dispatched %q, want %q", publisher.last.Message, want)
+	}
+}
@@ -46,6 +46,14 @@ func (h *TelnyxWebhookHandler) handleDeliveryStatus(ctx context.Context, evt tel
 			}
 		}
 	}
+	if failedAt != nil && h.failover != nil && len(payload.Errors) > 0 {
+		resent, err := h.failover.ResendBlocked(ctx, messaging.SMSProviderTelnyx, providerID, payload.Errors[0].Code)
+		if err != nil {
+			h.logger.Error("failed to re-send carrier-blocked message", "error", err, "provider_message_id", providerID)
+		} else if resent {
+			h.logger.Info("carrier-blocked message re-sent through secondary provider", "provider_message_id", providerID, "code", payload.Errors[0].Code)
+		}
+	}
 	if h.metrics != nil {
 		h.metrics.ObserveInbound(evt.EventType, status)
 	}
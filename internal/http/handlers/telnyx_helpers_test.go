@@ -18,18 +18,6 @@ func TestSanitizeDigits(t *testing.T) {
 	}
 }
 
-func TestNormalizeUSDigits(t *testing.T) {
-	if got := normalizeUSDigits("5551234567"); got != "15551234567" {
-		t.Fatalf("expected normalized digits, got %q", got)
-	}
-	if got := normalizeUSDigits("15551234567"); got != "15551234567" {
-		t.Fatalf("expected unchanged digits, got %q", got)
-	}
-	if got := normalizeUSDigits("123"); got != "123" {
-		t.Fatalf("expected unchanged digits, got %q", got)
-	}
-}
-
 func TestTelnyxConversationID(t *testing.T) {
 	convID := telnyxConversationID("org-1", "+1 (555) 123-4567")
 	if convID != "sms:org-1:15551234567" {
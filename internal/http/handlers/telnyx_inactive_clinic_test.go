@@ -0,0 +1,130 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/google/uuid"
+	pgxmock "github.com/pashagolub/pgxmock/v4"
+	"github.com/redis/go-redis/v9"
+	"github.com/wolfman30/medspa-ai-platform/internal/clinic"
+	"github.com/wolfman30/medspa-ai-platform/internal/leads"
+	"github.com/wolfman30/medspa-ai-platform/internal/messaging"
+	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
+)
+
+// expectInboundPersist queues the queries handleInbound runs before it
+// decides how to answer a plain (non-keyword) text.
+func expectInboundPersist(mock pgxmock.PgxPoolIface, clinicID uuid.UUID) {
+	mock.ExpectQuery("SELECT clinic_id").
+		WithArgs("+15559998888").
+		WillReturnRows(pgxmock.NewRows([]string{"clinic_id"}).AddRow(clinicID))
+	mock.ExpectQuery("SELECT 1 FROM messages").
+		WithArgs(clinicID, "+15550001111", "+15559998888").
+		WillReturnRows(pgxmock.NewRows([]string{"exists"}).AddRow(1))
+	mock.ExpectBegin()
+	mock.ExpectQuery("INSERT INTO messages").
+		WithArgs(clinicID, "+15550001111", "+15559998888", "inbound", "Need info", pgxmock.AnyArg(), "received", "msg_inbound", pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), "patient", pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg()).
+		WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(uuid.New()))
+	mock.ExpectExec("INSERT INTO outbox").
+		WithArgs(pgxmock.AnyArg(), "clinic:"+clinicID.String(), "messaging.message.received.v1", pgxmock.AnyArg()).
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+	mock.ExpectQuery("SELECT 1 FROM unsubscribes").
+		WithArgs(clinicID, "+15550001111").
+		WillReturnRows(pgxmock.NewRows([]string{"exists"}))
+	mock.ExpectCommit()
+}
+
+func TestTelnyxInboundInactiveClinicGetsFarewell(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("pgxmock: %v", err)
+	}
+	defer mock.Close()
+	mr := miniredis.RunT(t)
+	clinics := clinic.NewStore(redis.NewClient(&redis.Options{Addr: mr.Addr()}))
+	processed := &stubProcessedTracker{}
+	telnyxStub := &testTelnyxClient{}
+	conv := &stubConversationPublisher{}
+	handler := NewTelnyxWebhookHandler(TelnyxWebhookConfig{
+		Store:            messaging.NewStore(mock),
+		Processed:        processed,
+		Telnyx:           telnyxStub,
+		Conversation:     conv,
+		Leads:            &stubLeadsRepo{lead: &leads.Lead{ID: "lead-abc"}},
+		ClinicStore:      clinics,
+		Logger:           logging.Default(),
+		MessagingProfile: "profile",
+	})
+	clinicID := uuid.New()
+	orgID := clinicID.String()
+
+	post := func() {
+		t.Helper()
+		processed.seen = nil // the same fixture is replayed as a new text
+		expectInboundPersist(mock, clinicID)
+		req := httptest.NewRequest(http.MethodPost, "/webhooks/telnyx/messages", bytes.NewReader(loadFixture(t, "telnyx_inbound_message.json")))
+		rec := httptest.NewRecorder()
+		handler.HandleMessages(rec, req)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d body=%s", rec.Code, rec.Body.String())
+		}
+	}
+
+	// Config removed from Redis entirely: generic farewell, no conversation.
+	post()
+	if conv.calls != 0 {
+		t.Fatalf("expected no conversation job for an unconfigured clinic, got %d", conv.calls)
+	}
+	if telnyxStub.lastSendReq == nil || telnyxStub.lastSendReq.Body != "This number is no longer monitored - please contact the clinic directly." {
+		t.Fatalf("expected the generic farewell, got %+v", telnyxStub.lastSendReq)
+	}
+
+	// Deactivated through the admin API: the clinic's own details.
+	cfg := clinic.DefaultConfig(orgID)
+	cfg.Name = "Glow Med Spa"
+	cfg.Phone = "(555) 000-9999"
+	if err := clinics.Set(context.Background(), cfg); err != nil {
+		t.Fatalf("seed config: %v", err)
+	}
+	admin := clinic.NewHandler(clinics, logging.Default()).Routes()
+	rec := httptest.NewRecorder()
+	admin.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/"+orgID+"/deactivate", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("deactivate: %d %s", rec.Code, rec.Body.String())
+	}
+	post()
+	if conv.calls != 0 {
+		t.Fatalf("expected no conversation job for an inactive clinic, got %d", conv.calls)
+	}
+	want := "This number is no longer monitored - please call Glow Med Spa at (555) 000-9999."
+	if telnyxStub.lastSendReq.Body != want {
+		t.Fatalf("farewell = %q, want %q", telnyxStub.lastSendReq.Body, want)
+	}
+
+	// Reactivated: back to the normal conversation flow.
+	rec = httptest.NewRecorder()
+	admin.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/"+orgID+"/reactivate", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("reactivate: %d %s", rec.Code, rec.Body.String())
+	}
+	sends := telnyxStub.sendCalls
+	post()
+	if conv.calls != 1 || conv.last.OrgID != orgID {
+		t.Fatalf("expected the conversation job after reactivation, got %d calls", conv.calls)
+	}
+	if telnyxStub.sendCalls != sends {
+		t.Fatalf("expected no farewell after reactivation")
+	}
+	if !strings.Contains(conv.last.Message, "Need info") {
+		t.Fatalf("unexpected job message %q", conv.last.Message)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
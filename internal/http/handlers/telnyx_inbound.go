@@ -14,6 +14,8 @@ import (
 	"github.com/wolfman30/medspa-ai-platform/internal/events"
 	"github.com/wolfman30/medspa-ai-platform/internal/messaging"
 	"github.com/wolfman30/medspa-ai-platform/internal/messaging/compliance"
+	"github.com/wolfman30/medspa-ai-platform/internal/messaging/templates"
+	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
 )
 
 // handleInbound processes an inbound SMS message: deduplication, compliance
@@ -23,11 +25,12 @@ func (h *TelnyxWebhookHandler) handleInbound(ctx context.Context, evt telnyxEven
 	if err := json.Unmarshal(evt.Payload, &payload); err != nil {
 		return fmt.Errorf("decode inbound payload: %w", err)
 	}
+	log := h.logger.WithContext(ctx)
 	dedupeID := strings.TrimSpace(payload.MessageID)
 	if dedupeID == "" {
 		dedupeID = strings.TrimSpace(payload.ID)
 	}
-	h.logger.Info("telnyx inbound dedupe check",
+	log.Info("telnyx inbound dedupe check",
 		"event_id", evt.ID,
 		"payload_id", payload.ID,
 		"message_id", payload.MessageID,
@@ -41,7 +44,7 @@ func (h *TelnyxWebhookHandler) handleInbound(ctx context.Context, evt telnyxEven
 			return fmt.Errorf("processed lookup failed: %w", err)
 		}
 		if !isNew {
-			h.logger.Info("telnyx inbound dedupe: already processed", "dedupe_id", dedupeID)
+			log.Info("telnyx inbound dedupe: already processed", "dedupe_id", dedupeID)
 			return nil
 		}
 	}
@@ -59,6 +62,13 @@ func (h *TelnyxWebhookHandler) handleInbound(ctx context.Context, evt telnyxEven
 	}
 	orgID := clinicID.String()
 	conversationID := telnyxConversationID(orgID, from)
+	ctx = logging.WithFields(ctx, logging.Fields{OrgID: orgID, ConversationID: conversationID})
+	log = h.logger.WithContext(ctx)
+	if h.liveness != nil {
+		if err := h.liveness.RecordInbound(ctx, orgID, time.Now()); err != nil {
+			log.Warn("failed to record inbound liveness", "error", err)
+		}
+	}
 	seenInbound, err := h.store.HasInboundMessage(ctx, clinicID, from, to)
 	if err != nil {
 		return fmt.Errorf("check inbound history: %w", err)
@@ -69,16 +79,33 @@ func (h *TelnyxWebhookHandler) handleInbound(ctx context.Context, evt telnyxEven
 		return fmt.Errorf("begin tx: %w", err)
 	}
 	defer tx.Rollback(ctx)
-	media := payload.MediaURLs
+	attachments := payload.Attachments()
+	media := make([]string, 0, len(attachments))
+	for _, a := range attachments {
+		media = append(media, a.URL)
+	}
 
-	rawBody := payload.Text
-	panRedacted, sawPAN := compliance.RedactPAN(rawBody)
+	// A part of a multi-part message is only normalized once it is combined,
+	// so the spaces at part boundaries survive; the checks below use the
+	// normalized text.
+	partText := payload.MessageText()
+	text := conversation.NormalizeInboundText(partText)
+	if text == "" && len(media) > 0 {
+		// Media-only MMS: let the pipeline ask what the patient needs.
+		text = conversation.MediaPlaceholder
+		partText = text
+	}
+	if strings.EqualFold(payload.Type, "RCS") {
+		log.Info("telnyx inbound rcs message", "provider_message_id", payload.ID)
+	}
+	rawBody := text
+	panRedacted, sensitive := compliance.RedactSensitiveNumbers(rawBody)
 	storageBody, _ := conversation.RedactSensitive(panRedacted)
-	msgRecord := messaging.MessageRecord{ClinicID: clinicID, From: from, To: to, Direction: "inbound", Body: storageBody, Media: media, ProviderStatus: payload.Status, ProviderMessageID: payload.ID}
+	msgRecord := messaging.MessageRecord{ClinicID: clinicID, From: from, To: to, Direction: "inbound", Body: storageBody, Media: media, AttachmentKinds: conversation.AttachmentKinds(attachments), ProviderStatus: payload.Status, ProviderMessageID: payload.ID}
 	msgID, err := h.store.InsertMessage(ctx, tx, msgRecord)
 	if err != nil {
 		if isDuplicateProviderMessage(err) {
-			h.logger.Info("telnyx inbound duplicate message ignored", "provider_message_id", payload.ID)
+			log.Info("telnyx inbound duplicate message ignored", "provider_message_id", payload.ID)
 			return nil
 		}
 		return fmt.Errorf("insert inbound message: %w", err)
@@ -89,11 +116,11 @@ func (h *TelnyxWebhookHandler) handleInbound(ctx context.Context, evt telnyxEven
 	}
 	var stop, help, start bool
 	if h.detector != nil {
-		stop = h.detector.IsStop(payload.Text)
-		help = h.detector.IsHelp(payload.Text)
-		start = h.detector.IsStart(payload.Text)
+		stop = h.detector.IsStop(text)
+		help = h.detector.IsHelp(text)
+		start = h.detector.IsStart(text)
 	}
-	if h.demoMode && strings.EqualFold(strings.TrimSpace(payload.Text), "YES") {
+	if h.demoMode && strings.EqualFold(strings.TrimSpace(text), "YES") {
 		start = true
 	}
 	unsubscribed := false
@@ -124,39 +151,141 @@ func (h *TelnyxWebhookHandler) handleInbound(ctx context.Context, evt telnyxEven
 
 	switch {
 	case stop:
-		h.appendTranscript(context.Background(), conversationID, conversation.SMSTranscriptMessage{Role: "assistant", From: to, To: from, Body: h.stopAck, Kind: "stop_ack"})
-		h.sendAutoReply(context.Background(), to, from, h.stopAck)
+		h.replyWith(conversationID, to, from, h.complianceReply(ctx, orgID, templates.ComplianceStopAck), "stop_ack")
 	case help:
-		h.appendTranscript(context.Background(), conversationID, conversation.SMSTranscriptMessage{Role: "assistant", From: to, To: from, Body: h.helpAck, Kind: "help_ack"})
-		h.sendAutoReply(context.Background(), to, from, h.helpAck)
+		h.replyWith(conversationID, to, from, h.complianceReply(ctx, orgID, templates.ComplianceHelpAck), "help_ack")
 	case start:
-		h.appendTranscript(context.Background(), conversationID, conversation.SMSTranscriptMessage{Role: "assistant", From: to, To: from, Body: h.startAck, Kind: "start_ack"})
-		h.sendAutoReply(context.Background(), to, from, h.startAck)
+		h.replyWith(conversationID, to, from, h.complianceReply(ctx, orgID, templates.ComplianceStartAck), "start_ack")
 	case unsubscribed:
 		// no-op
-	case sawPAN:
-		h.appendTranscript(context.Background(), conversationID, conversation.SMSTranscriptMessage{Role: "assistant", From: to, To: from, Body: messaging.PCIGuardrailMessage, Kind: "pci_guardrail"})
-		h.sendAutoReply(context.Background(), to, from, messaging.PCIGuardrailMessage)
+	case len(sensitive) > 0:
+		h.auditSensitiveData(ctx, orgID, conversationID, sensitive)
+		h.replyWith(conversationID, to, from, h.complianceReply(ctx, orgID, templates.CompliancePCIGuardrail), "pci_guardrail")
+	case h.replyIfInactive(ctx, orgID, conversationID, from, to):
+		// Offboarded clinic: the farewell replaces the conversation.
 	default:
 		if isFirstInbound {
-			ack := messaging.GetSmsAckMessage(true)
+			ack := h.clinicConfig(ctx, orgID).RenderAck(dedupeID)
 			ackKind := "ack"
-			if h.demoMode && h.firstContactAck != "" {
-				ack = h.firstContactAck
+			if greeting := h.firstContactGreeting(ctx, orgID, from, to, panRedacted); greeting.Body != "" {
+				ack = greeting
+				ackKind = "first_contact"
+			} else if h.demoMode && h.firstContactAck != "" {
+				ack = templates.Render(templates.AckFirstContact, nil, templates.Overrides{
+					templates.AckFirstContact: {Text: h.firstContactAck},
+				})
 				ackKind = "first_contact_ack"
 			}
-			h.appendTranscript(context.Background(), conversationID, conversation.SMSTranscriptMessage{Role: "assistant", From: to, To: from, Body: ack, Kind: ackKind})
-			h.sendAutoReply(context.Background(), to, from, ack)
+			h.replyWith(conversationID, to, from, ack, ackKind)
 		}
-		h.dispatchConversation(context.Background(), evt, payload, clinicID, conversationID, panRedacted)
+		partBody, _ := compliance.RedactSensitiveNumbers(partText)
+		h.dispatchInbound(ctx, evt, payload, clinicID, conversationID, partBody)
 	}
 	return nil
 }
 
+// firstContactGreeting returns the clinic's first-contact template for a new
+// patient, or an empty body to fall back to the generic ack. The lead is
+// created here rather than at dispatch so the greeting can be claimed before
+// it is sent.
+func (h *TelnyxWebhookHandler) firstContactGreeting(ctx context.Context, orgID, from, to, body string) templates.Rendered {
+	cfg := h.clinicConfig(ctx, orgID)
+	if cfg.RenderFirstContact("").Body == "" {
+		return templates.Rendered{}
+	}
+	log := h.logger.WithContext(ctx)
+	leadID := ""
+	if h.leads != nil {
+		lead, err := h.leads.GetOrCreateByPhone(ctx, orgID, from, "telnyx_sms", "")
+		if err != nil {
+			log.Warn("failed to persist lead for first-contact greeting", "error", err, "org_id", orgID)
+		} else if lead != nil {
+			leadID = lead.ID
+		}
+	}
+	defaultService := ""
+	if h.routes != nil {
+		if route, err := h.routes.ResolveRoute(ctx, to); err == nil && route.OrgID == orgID {
+			defaultService = route.DefaultService
+		}
+	}
+	greeting, err := messaging.FirstContactGreeting(ctx, cfg, h.leads, leadID, messaging.FirstContactService(cfg, defaultService, body))
+	if err != nil {
+		log.Warn("failed to record first-contact greeting", "error", err, "org_id", orgID, "lead_id", leadID)
+	}
+	return greeting
+}
+
+// dispatchInbound sends the message to the conversation pipeline, first
+// holding multi-part messages in the concat buffer so the parts arrive as one.
+// body is the part's text before normalization.
+func (h *TelnyxWebhookHandler) dispatchInbound(ctx context.Context, evt telnyxEvent, payload telnyxMessagePayload, clinicID uuid.UUID, conversationID string, body string) {
+	// A complete group is dispatched after the webhook returns, so keep the
+	// correlation fields but not the request's cancellation.
+	ctx = context.WithoutCancel(ctx)
+	if h.concat == nil {
+		h.dispatchConversation(ctx, evt, payload, clinicID, conversationID, conversation.NormalizeInboundText(body))
+		return
+	}
+	part := inboundPart{MessageID: payload.ID, Text: body, ReceivedAt: evt.OccurredAt}
+	if payload.Concat != nil {
+		part.Seq = payload.Concat.PartNumber
+		part.Total = payload.Concat.TotalParts
+	}
+	if part.ReceivedAt.IsZero() {
+		part.ReceivedAt = time.Now().UTC()
+	}
+	evt.Payload = nil
+	envelope, err := json.Marshal(concatEnvelope{Event: evt, Payload: payload, ClinicID: clinicID})
+	if err != nil {
+		h.logger.WithContext(ctx).Warn("failed to encode concat envelope; dispatching part directly", "error", err, "conversation_id", conversationID)
+		h.dispatchConversation(ctx, evt, payload, clinicID, conversationID, conversation.NormalizeInboundText(body))
+		return
+	}
+	part.Envelope = envelope
+	buffered, err := h.concat.Offer(ctx, conversationID, part, h.deliverCombined)
+	if err != nil {
+		h.logger.WithContext(ctx).Warn("concat buffer unavailable", "error", err, "conversation_id", conversationID, "buffered", buffered)
+	}
+	if !buffered {
+		h.dispatchConversation(ctx, evt, payload, clinicID, conversationID, conversation.NormalizeInboundText(body))
+	}
+}
+
+// concatEnvelope is what dispatchConversation needs to deliver a buffered
+// group, stored with each part.
+type concatEnvelope struct {
+	Event    telnyxEvent          `json:"event"`
+	Payload  telnyxMessagePayload `json:"payload"`
+	ClinicID uuid.UUID            `json:"clinic_id"`
+}
+
+// deliverCombined dispatches a combined group using its first part's envelope.
+// The parts are buffered as received, so the combined text is normalized here.
+func (h *TelnyxWebhookHandler) deliverCombined(ctx context.Context, conversationID string, envelope json.RawMessage, text string) {
+	var env concatEnvelope
+	if err := json.Unmarshal(envelope, &env); err != nil {
+		h.logger.Error("failed to decode concat envelope; dropping combined message", "error", err, "conversation_id", conversationID)
+		return
+	}
+	h.dispatchConversation(ctx, env.Event, env.Payload, env.ClinicID, conversationID, conversation.NormalizeInboundText(text))
+}
+
+// RunConcatFlusher delivers buffered multi-part messages whose window has
+// elapsed, including groups buffered by other instances or before a
+// restart. It returns when ctx is cancelled.
+func (h *TelnyxWebhookHandler) RunConcatFlusher(ctx context.Context) {
+	if h == nil || h.concat == nil {
+		return
+	}
+	h.concat.Run(ctx, h.deliverCombined)
+}
+
 func (h *TelnyxWebhookHandler) dispatchConversation(ctx context.Context, evt telnyxEvent, payload telnyxMessagePayload, clinicID uuid.UUID, conversationID string, body string) {
 	if h.conversation == nil {
 		return
 	}
+	log := h.logger.WithContext(ctx)
 	orgID := clinicID.String()
 	from := messaging.NormalizeE164(payload.FromNumber())
 	to := messaging.NormalizeE164(payload.ToNumber())
@@ -167,7 +296,7 @@ func (h *TelnyxWebhookHandler) dispatchConversation(ctx context.Context, evt tel
 	if h.leads != nil {
 		lead, err := h.leads.GetOrCreateByPhone(ctx, orgID, from, "telnyx_sms", "")
 		if err != nil {
-			h.logger.Error("failed to persist lead for telnyx inbound", "error", err, "org_id", orgID, "from", from)
+			log.Error("failed to persist lead for telnyx inbound", "error", err, "org_id", orgID, "from", from)
 			return
 		}
 		if lead != nil && lead.ID != "" {
@@ -175,7 +304,16 @@ func (h *TelnyxWebhookHandler) dispatchConversation(ctx context.Context, evt tel
 		}
 	}
 	h.linkLead(ctx, conversationID, leadID)
-	req := conversation.MessageRequest{OrgID: orgID, LeadID: leadID, ConversationID: conversationID, Message: body, ClinicID: orgID, Channel: conversation.ChannelSMS, From: from, To: to, Metadata: map[string]string{"telnyx_event_id": evt.ID, "telnyx_message_id": payload.ID, "direction": payload.Direction}}
+	metadata := messaging.WithRequestID(ctx, h.withRouteMetadata(ctx, orgID, to, map[string]string{"telnyx_event_id": evt.ID, "telnyx_message_id": payload.ID, "direction": payload.Direction}))
+	metadata = h.withBroadcastMetadata(ctx, orgID, from, metadata)
+	if encoded := conversation.EncodeAttachments(payload.Attachments()); encoded != "" {
+		metadata[conversation.MetadataAttachments] = encoded
+	}
+	receivedAt := evt.OccurredAt
+	if receivedAt.IsZero() {
+		receivedAt = time.Now().UTC()
+	}
+	req := conversation.MessageRequest{OrgID: orgID, LeadID: leadID, ConversationID: conversationID, Message: body, ClinicID: orgID, Channel: conversation.ChannelSMS, From: from, To: to, Metadata: metadata, ReceivedAt: receivedAt}
 	jobID := fmt.Sprintf("telnyx:%s", payload.ID)
 	publishCtx, cancel := context.WithTimeout(ctx, 3*time.Second)
 	defer cancel()
@@ -184,6 +322,6 @@ func (h *TelnyxWebhookHandler) dispatchConversation(ctx context.Context, evt tel
 		opts = nil
 	}
 	if err := h.conversation.EnqueueMessage(publishCtx, jobID, req, opts...); err != nil {
-		h.logger.Error("failed to enqueue telnyx conversation job", "error", err, "job_id", jobID)
+		log.Error("failed to enqueue telnyx conversation job", "error", err, "job_id", jobID)
 	}
 }
@@ -0,0 +1,154 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/http/apierror"
+	"github.com/wolfman30/medspa-ai-platform/internal/messaging"
+)
+
+// numberStatusUpdater records Telnyx number order and 10DLC campaign status,
+// and reads it back to gate auto-replies.
+type numberStatusUpdater interface {
+	numberProvisioningLookup
+	UpdateNumberStatus(ctx context.Context, orderID, number, status string) (int64, error)
+	UpdateCampaignStatus(ctx context.Context, campaignID, status string) (int64, error)
+}
+
+// telnyxNumberOrderPayload is the payload of number_order.* events.
+type telnyxNumberOrderPayload struct {
+	ID           string `json:"id"`
+	Status       string `json:"status"`
+	PhoneNumbers []struct {
+		PhoneNumber string `json:"phone_number"`
+		Status      string `json:"status"`
+	} `json:"phone_numbers"`
+}
+
+// telnyxCampaignPayload is the payload of 10dlc.campaign.* events. Telnyx
+// sends camelCase keys for 10DLC events; snake_case is accepted too.
+type telnyxCampaignPayload struct {
+	CampaignID      string `json:"campaignId"`
+	CampaignIDSnake string `json:"campaign_id"`
+	Status          string `json:"status"`
+	Description     string `json:"description"`
+}
+
+// HandleNumbers processes Telnyx number order and 10DLC campaign status
+// webhooks, keeping phone_number_provisioning current.
+func (h *TelnyxWebhookHandler) HandleNumbers(w http.ResponseWriter, r *http.Request) {
+	if h.telnyx == nil {
+		apierror.Write(w, r, apierror.CodeDependencyUnavailable, "telnyx client not configured")
+		return
+	}
+	if h.provisioning == nil {
+		apierror.Write(w, r, apierror.CodeDependencyUnavailable, "number provisioning not configured")
+		return
+	}
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		apierror.Write(w, r, apierror.CodeValidationFailed, "invalid body")
+		return
+	}
+	if err := h.telnyx.VerifyWebhookSignature(r.Header.Get("Telnyx-Timestamp"), r.Header.Get("Telnyx-Signature"), body); err != nil {
+		h.logger.Warn("invalid telnyx numbers signature", "error", err)
+		apierror.Write(w, r, apierror.CodeForbidden, "invalid signature")
+		return
+	}
+	evt, err := parseTelnyxEvent(body)
+	if err != nil {
+		apierror.Write(w, r, apierror.CodeValidationFailed, "invalid payload")
+		return
+	}
+	if processed, err := h.processed.AlreadyProcessed(r.Context(), "telnyx", evt.ID); err != nil {
+		h.logger.Error("processed lookup failed", "error", err)
+		apierror.Write(w, r, apierror.CodeInternal, "server error")
+		return
+	} else if processed {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	if err := h.handleNumberEvent(r.Context(), evt); err != nil {
+		h.logger.Error("number status event failed", "error", err, "event_type", evt.EventType)
+		apierror.Write(w, r, apierror.CodeInternal, "processing error")
+		return
+	}
+	if _, err := h.processed.MarkProcessed(r.Context(), "telnyx", evt.ID); err != nil {
+		h.logger.Error("failed to mark telnyx event processed", "error", err, "event_id", evt.ID)
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// handleNumberEvent applies a number order or campaign status event. Other
+// event types are acknowledged and ignored.
+func (h *TelnyxWebhookHandler) handleNumberEvent(ctx context.Context, evt telnyxEvent) error {
+	switch {
+	case strings.HasPrefix(evt.EventType, "number_order."):
+		return h.handleNumberOrderStatus(ctx, evt)
+	case strings.HasPrefix(evt.EventType, "10dlc.campaign."):
+		return h.handleCampaignStatus(ctx, evt)
+	default:
+		h.logger.Debug("ignoring telnyx number event", "event_type", evt.EventType)
+		return nil
+	}
+}
+
+func (h *TelnyxWebhookHandler) handleNumberOrderStatus(ctx context.Context, evt telnyxEvent) error {
+	var payload telnyxNumberOrderPayload
+	if err := json.Unmarshal(evt.Payload, &payload); err != nil {
+		return fmt.Errorf("decode number order payload: %w", err)
+	}
+	for _, n := range payload.PhoneNumbers {
+		status := strings.TrimSpace(n.Status)
+		if status == "" {
+			status = payload.Status
+		}
+		updated, err := h.provisioning.UpdateNumberStatus(ctx, payload.ID, n.PhoneNumber, status)
+		if err != nil {
+			return fmt.Errorf("persist number status: %w", err)
+		}
+		if updated == 0 {
+			h.logger.Warn("number order event for untracked number", "order_id", payload.ID, "number", messaging.NormalizeE164(n.PhoneNumber))
+			continue
+		}
+		h.logger.Info("number order status updated", "order_id", payload.ID, "number", messaging.NormalizeE164(n.PhoneNumber), "status", status)
+	}
+	if h.metrics != nil {
+		h.metrics.ObserveInbound(evt.EventType, payload.Status)
+	}
+	return nil
+}
+
+func (h *TelnyxWebhookHandler) handleCampaignStatus(ctx context.Context, evt telnyxEvent) error {
+	var payload telnyxCampaignPayload
+	if err := json.Unmarshal(evt.Payload, &payload); err != nil {
+		return fmt.Errorf("decode campaign payload: %w", err)
+	}
+	campaignID := strings.TrimSpace(payload.CampaignID)
+	if campaignID == "" {
+		campaignID = strings.TrimSpace(payload.CampaignIDSnake)
+	}
+	if campaignID == "" || strings.TrimSpace(payload.Status) == "" {
+		return fmt.Errorf("campaign event missing campaign id or status")
+	}
+	updated, err := h.provisioning.UpdateCampaignStatus(ctx, campaignID, payload.Status)
+	if err != nil {
+		return fmt.Errorf("persist campaign status: %w", err)
+	}
+	state := messaging.NumberProvisioning{CampaignID: campaignID, CampaignStatus: payload.Status}
+	if state.CampaignApproved() {
+		h.logger.Info("10DLC campaign approved; sends unblocked", "campaign_id", campaignID, "status", payload.Status, "numbers", updated)
+	} else {
+		h.logger.Warn("10DLC campaign not approved; sends from its numbers are blocked",
+			"campaign_id", campaignID, "status", payload.Status, "description", payload.Description, "numbers", updated)
+	}
+	if h.metrics != nil {
+		h.metrics.ObserveInbound(evt.EventType, payload.Status)
+	}
+	return nil
+}
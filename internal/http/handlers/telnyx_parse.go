@@ -5,6 +5,9 @@ import (
 	"fmt"
 	"strings"
 	"time"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/conversation"
+	"github.com/wolfman30/medspa-ai-platform/pkg/phone"
 )
 
 // telnyxEvent represents a normalized Telnyx webhook event.
@@ -70,8 +73,10 @@ type telnyxMessagePayload struct {
 	Direction string   `json:"direction"`
 	Text      string   `json:"text"`
 	MediaURLs []string `json:"media_urls"`
-	Status    string   `json:"status"`
-	From      struct {
+	// Media is Telnyx's MMS attachment list, which carries content types.
+	Media  []telnyxMedia `json:"media"`
+	Status string        `json:"status"`
+	From   struct {
 		PhoneNumber string `json:"phone_number"`
 	} `json:"from"`
 	To []struct {
@@ -80,6 +85,67 @@ type telnyxMessagePayload struct {
 	FromNumberRaw string `json:"from_number"`
 	ToNumberRaw   string `json:"to_number"`
 	MessageID     string `json:"message_id"`
+	// Type is the message channel: "SMS", "MMS", or "RCS".
+	Type string `json:"type"`
+	// RCSBody carries RCS content, either a plain string or an object with text.
+	RCSBody json.RawMessage `json:"body"`
+	// Concat identifies one part of a message the carrier didn't reassemble.
+	Concat *telnyxConcatInfo `json:"concat,omitempty"`
+}
+
+// telnyxConcatInfo is the concatenation header of a multi-part SMS.
+type telnyxConcatInfo struct {
+	Reference  string `json:"reference"`
+	PartNumber int    `json:"part_number"`
+	TotalParts int    `json:"total_parts"`
+}
+
+// telnyxMedia is one MMS attachment on an inbound message.
+type telnyxMedia struct {
+	URL         string `json:"url"`
+	ContentType string `json:"content_type"`
+}
+
+// Attachments returns the message's attachments, classified by content type.
+// URLs listed only under media_urls are classified by file extension.
+func (p telnyxMessagePayload) Attachments() []conversation.Attachment {
+	var out []conversation.Attachment
+	seen := make(map[string]bool, len(p.Media))
+	for _, m := range p.Media {
+		url := strings.TrimSpace(m.URL)
+		if url == "" || seen[url] {
+			continue
+		}
+		seen[url] = true
+		out = append(out, conversation.Attachment{URL: url, ContentType: m.ContentType, Kind: conversation.ClassifyAttachment(m.ContentType, url)})
+	}
+	for _, raw := range p.MediaURLs {
+		url := strings.TrimSpace(raw)
+		if url == "" || seen[url] {
+			continue
+		}
+		seen[url] = true
+		out = append(out, conversation.Attachment{URL: url, Kind: conversation.ClassifyAttachment("", url)})
+	}
+	return out
+}
+
+// MessageText returns the inbound text, falling back to RCS body content.
+func (p telnyxMessagePayload) MessageText() string {
+	if strings.TrimSpace(p.Text) != "" || len(p.RCSBody) == 0 {
+		return p.Text
+	}
+	var text string
+	if err := json.Unmarshal(p.RCSBody, &text); err == nil {
+		return text
+	}
+	var obj struct {
+		Text string `json:"text"`
+	}
+	if err := json.Unmarshal(p.RCSBody, &obj); err == nil {
+		return obj.Text
+	}
+	return ""
 }
 
 // FromNumber returns the normalized sender phone number.
@@ -179,9 +245,7 @@ type telnyxHostedPayload struct {
 // telnyxConversationID builds a deterministic conversation identifier from
 // the clinic org ID and the caller's E.164 number.
 func telnyxConversationID(orgID string, fromE164 string) string {
-	digits := sanitizeDigits(fromE164)
-	digits = normalizeUSDigits(digits)
-	return fmt.Sprintf("sms:%s:%s", orgID, digits)
+	return fmt.Sprintf("sms:%s:%s", orgID, phone.Digits(fromE164))
 }
 
 // sanitizeDigits strips all non-digit characters from a string.
@@ -200,12 +264,4 @@ func sanitizeDigits(value string) string {
 	return b.String()
 }
 
-// normalizeUSDigits prepends a leading "1" to 10-digit US phone numbers.
-func normalizeUSDigits(digits string) string {
-	if len(digits) == USLocalDigits {
-		return "1" + digits
-	}
-	return digits
-}
-
 // It delegates to messaging.NormalizeE164.
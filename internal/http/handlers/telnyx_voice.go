@@ -48,6 +48,9 @@ func (h *TelnyxWebhookHandler) handleVoice(ctx context.Context, evt telnyxEvent)
 		return fmt.Errorf("lookup clinic for %s: %w", to, err)
 	}
 	orgID := clinicID.String()
+	if h.replyIfInactive(ctx, orgID, telnyxConversationID(orgID, from), from, to) {
+		return nil
+	}
 	leadID := fmt.Sprintf("%s:%s", orgID, from)
 	if h.leads != nil {
 		// Pass empty defaultName - name will be extracted from conversation later
@@ -73,12 +76,12 @@ func (h *TelnyxWebhookHandler) handleVoice(ctx context.Context, evt telnyxEvent)
 		From:           from,
 		To:             to,
 		Silent:         true,
-		AckMessage:     ack, // Include ack message so AI knows what was already sent
-		Metadata: map[string]string{
+		AckMessage:     ack.Body, // Include ack message so AI knows what was already sent
+		Metadata: h.withRouteMetadata(ctx, orgID, to, map[string]string{
 			"telnyx_event_id": evt.ID,
 			"telnyx_call_id":  payload.ID,
 			"telnyx_status":   payload.Status,
-		},
+		}),
 	}
 	jobID := fmt.Sprintf("telnyx:voice:%s", evt.ID)
 	publishCtx, cancel := context.WithTimeout(ctx, 3*time.Second)
@@ -90,14 +93,7 @@ func (h *TelnyxWebhookHandler) handleVoice(ctx context.Context, evt telnyxEvent)
 	if err := h.conversation.EnqueueStart(publishCtx, jobID, startReq, opts...); err != nil {
 		return fmt.Errorf("enqueue missed-call start: %w", err)
 	}
-	h.appendTranscript(context.Background(), conversationID, conversation.SMSTranscriptMessage{
-		Role: "assistant",
-		From: to,
-		To:   from,
-		Body: ack,
-		Kind: "voice_ack",
-	})
-	h.sendAutoReply(context.Background(), to, from, ack)
+	h.replyWith(conversationID, to, from, ack, "voice_ack")
 	return nil
 }
 
@@ -105,18 +101,29 @@ func (h *TelnyxWebhookHandler) handleVoice(ctx context.Context, evt telnyxEvent)
 // for a missed call, used by CallControlHandler when voice AI is not enabled.
 func (h *TelnyxWebhookHandler) HandleMissedCall(ctx context.Context, from, to string) error {
 	h.logger.Info("missed-call-texter: triggered from call control", "from", from, "to", to)
+	return h.textBackCall(ctx, from, to, "telnyx_voice", fmt.Sprintf("cc:missed:%s:%s", from, to), map[string]string{
+		"call_control_reject": "true",
+	})
+}
+
+// textBackCall starts the SMS text-back conversation for a caller and sends
+// the voice ack.
+func (h *TelnyxWebhookHandler) textBackCall(ctx context.Context, from, to, source, jobID string, metadata map[string]string) error {
 	clinicID, err := h.store.LookupClinicByNumber(ctx, to)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
-			h.logger.Warn("missed-call-texter: clinic not found for number", "to", to)
+			h.logger.Warn("text-back: clinic not found for number", "to", to)
 			return fmt.Errorf("%w: %s", errClinicNotFound, to)
 		}
 		return fmt.Errorf("lookup clinic for %s: %w", to, err)
 	}
 	orgID := clinicID.String()
+	if h.replyIfInactive(ctx, orgID, telnyxConversationID(orgID, from), from, to) {
+		return nil
+	}
 	leadID := fmt.Sprintf("%s:%s", orgID, from)
 	if h.leads != nil {
-		lead, err := h.leads.GetOrCreateByPhone(ctx, orgID, from, "telnyx_voice", "")
+		lead, err := h.leads.GetOrCreateByPhone(ctx, orgID, from, source, "")
 		if err != nil {
 			return fmt.Errorf("persist lead: %w", err)
 		}
@@ -131,18 +138,15 @@ func (h *TelnyxWebhookHandler) HandleMissedCall(ctx context.Context, from, to st
 		LeadID:         leadID,
 		ConversationID: conversationID,
 		Intro:          "We just missed your call. I can help you book an appointment or answer quick questions by text.",
-		Source:         "telnyx_voice",
+		Source:         source,
 		ClinicID:       orgID,
 		Channel:        conversation.ChannelSMS,
 		From:           from,
 		To:             to,
 		Silent:         true,
-		AckMessage:     ack,
-		Metadata: map[string]string{
-			"call_control_reject": "true",
-		},
+		AckMessage:     ack.Body,
+		Metadata:       h.withRouteMetadata(ctx, orgID, to, metadata),
 	}
-	jobID := fmt.Sprintf("cc:missed:%s:%s", from, to)
 	publishCtx, cancel := context.WithTimeout(ctx, 3*time.Second)
 	defer cancel()
 	opts := []conversation.PublishOption{conversation.WithoutJobTracking()}
@@ -152,14 +156,7 @@ func (h *TelnyxWebhookHandler) HandleMissedCall(ctx context.Context, from, to st
 	if err := h.conversation.EnqueueStart(publishCtx, jobID, startReq, opts...); err != nil {
 		return fmt.Errorf("enqueue missed-call start: %w", err)
 	}
-	h.appendTranscript(context.Background(), conversationID, conversation.SMSTranscriptMessage{
-		Role: "assistant",
-		From: to,
-		To:   from,
-		Body: ack,
-		Kind: "voice_ack",
-	})
-	h.sendAutoReply(context.Background(), to, from, ack)
+	h.replyWith(conversationID, to, from, ack, "voice_ack")
 	return nil
 }
 
@@ -0,0 +1,176 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/clinic"
+	"github.com/wolfman30/medspa-ai-platform/internal/http/apierror"
+	"github.com/wolfman30/medspa-ai-platform/internal/messaging"
+)
+
+// handleTeXMLVoice answers a TeXML voice request. Clinics with the IVR
+// enabled get the "press 1 to get a text" greeting; others are texted back
+// right away and the call is rejected.
+func (h *TelnyxWebhookHandler) handleTeXMLVoice(w http.ResponseWriter, r *http.Request, body []byte) {
+	form, err := url.ParseQuery(string(body))
+	if err != nil {
+		apierror.Write(w, r, apierror.CodeValidationFailed, "invalid payload")
+		return
+	}
+	callSid := strings.TrimSpace(form.Get("CallSid"))
+	from := messaging.NormalizeE164(form.Get("From"))
+	to := messaging.NormalizeE164(form.Get("To"))
+	if callSid == "" || from == "" || to == "" {
+		apierror.Write(w, r, apierror.CodeValidationFailed, "missing call fields")
+		return
+	}
+
+	ctx := r.Context()
+	if cfg := h.ivrClinicConfig(ctx, to); cfg != nil {
+		h.logger.Info("texml voice: answering with ivr greeting", "call_sid", callSid, "to", to)
+		writeTeXML(w, messaging.IVRGreetingXML(messaging.IVRGreetingForClinic(cfg), messaging.TelnyxIVRPaths, from, to))
+		return
+	}
+
+	if err := h.textBackCall(ctx, from, to, "telnyx_voice", fmt.Sprintf("telnyx:texml:%s", callSid), map[string]string{
+		"telnyx_call_sid": callSid,
+	}); err != nil {
+		h.logger.Error("texml voice: text-back failed", "error", err, "call_sid", callSid)
+	}
+	writeTeXML(w, `<?xml version="1.0" encoding="UTF-8"?><Response><Reject reason="busy"/></Response>`)
+}
+
+// HandleVoiceGather handles POST /webhooks/telnyx/voice/gather, the TeXML
+// DTMF callback from the IVR greeting. Pressing 1 starts the SMS text-back
+// flow; anything else falls through to voicemail.
+func (h *TelnyxWebhookHandler) HandleVoiceGather(w http.ResponseWriter, r *http.Request) {
+	form, ok := h.readSignedTeXMLForm(w, r, "gather")
+	if !ok {
+		return
+	}
+	callSid := strings.TrimSpace(form.Get("CallSid"))
+	digits := strings.TrimSpace(form.Get("Digits"))
+	from := messaging.NormalizeE164(form.Get("From"))
+	to := messaging.NormalizeE164(form.Get("To"))
+	if digits != messaging.IVRTextDigit || callSid == "" || from == "" || to == "" {
+		writeTeXML(w, messaging.VoicemailXML(messaging.TelnyxIVRPaths, from, to))
+		return
+	}
+
+	if err := h.textBackCall(r.Context(), from, to, messaging.LeadSourceVoiceIVR, fmt.Sprintf("telnyx:ivr:%s", callSid), map[string]string{
+		"telnyx_call_sid": callSid,
+		"ivr_digits":      digits,
+	}); err != nil {
+		h.logger.Error("ivr gather: text-back failed", "error", err, "call_sid", callSid)
+		writeTeXML(w, messaging.VoicemailXML(messaging.TelnyxIVRPaths, from, to))
+		return
+	}
+	writeTeXML(w, messaging.IVRTextSentXML())
+}
+
+// HandleVoicemailDone handles POST /webhooks/telnyx/voice/voicemail, the
+// Record action that fires when the caller finishes a voicemail. It ends the
+// call; the recording arrives separately at HandleVoiceRecording.
+func (h *TelnyxWebhookHandler) HandleVoicemailDone(w http.ResponseWriter, r *http.Request) {
+	if _, ok := h.readSignedTeXMLForm(w, r, "voicemail"); !ok {
+		return
+	}
+	writeTeXML(w, messaging.VoicemailDoneXML())
+}
+
+// HandleVoiceRecording handles POST /webhooks/telnyx/voice/recording, the
+// status callback for a finished voicemail. It queues the voicemail so the
+// worker adds it to the caller's conversation and alerts the clinic.
+func (h *TelnyxWebhookHandler) HandleVoiceRecording(w http.ResponseWriter, r *http.Request) {
+	form, ok := h.readSignedTeXMLForm(w, r, "recording")
+	if !ok {
+		return
+	}
+	cb, ok := messaging.ParseRecordingCallback(r.URL.Query(), form)
+	if !ok {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	ctx := r.Context()
+	clinicID, err := h.store.LookupClinicByNumber(ctx, cb.To)
+	if err != nil {
+		h.logger.Warn("voicemail: clinic not found for number", "error", err, "to", cb.To)
+		apierror.Write(w, r, apierror.CodeValidationFailed, "unknown destination number")
+		return
+	}
+	orgID := clinicID.String()
+	leadID := fmt.Sprintf("%s:%s", orgID, cb.From)
+	if h.leads != nil {
+		lead, err := h.leads.GetOrCreateByPhone(ctx, orgID, cb.From, "telnyx_voice", "")
+		if err != nil {
+			h.logger.Error("voicemail: failed to persist lead", "error", err, "org_id", orgID)
+			apierror.WriteError(w, r, err, apierror.CodeInternal, "failed to persist lead")
+			return
+		}
+		if lead != nil && lead.ID != "" {
+			leadID = lead.ID
+		}
+	}
+	if err := messaging.EnqueueVoicemail(ctx, h.conversation, orgID, leadID, telnyxConversationID(orgID, cb.From), cb); err != nil {
+		h.logger.Error("voicemail: failed to enqueue", "error", err, "org_id", orgID, "recording_sid", cb.RecordingID)
+		apierror.WriteError(w, r, err, apierror.CodeInternal, "failed to record voicemail")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// readSignedTeXMLForm verifies a TeXML callback's signature and parses its
+// form body, writing the error response when it can't.
+func (h *TelnyxWebhookHandler) readSignedTeXMLForm(w http.ResponseWriter, r *http.Request, kind string) (url.Values, bool) {
+	if h.telnyx == nil {
+		apierror.Write(w, r, apierror.CodeDependencyUnavailable, "telnyx client not configured")
+		return nil, false
+	}
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		apierror.Write(w, r, apierror.CodeValidationFailed, "invalid body")
+		return nil, false
+	}
+	if err := h.telnyx.VerifyWebhookSignature(r.Header.Get("Telnyx-Timestamp"), r.Header.Get("Telnyx-Signature"), body); err != nil {
+		h.logger.Warn("invalid telnyx "+kind+" signature", "error", err)
+		apierror.Write(w, r, apierror.CodeForbidden, "invalid signature")
+		return nil, false
+	}
+	form, err := url.ParseQuery(string(body))
+	if err != nil {
+		apierror.Write(w, r, apierror.CodeValidationFailed, "invalid payload")
+		return nil, false
+	}
+	return form, true
+}
+
+// ivrClinicConfig returns the clinic config for the dialed number when the
+// clinic has the IVR greeting enabled.
+func (h *TelnyxWebhookHandler) ivrClinicConfig(ctx context.Context, to string) *clinic.Config {
+	clinicID, err := h.store.LookupClinicByNumber(ctx, to)
+	if err != nil {
+		return nil
+	}
+	cfg := h.clinicConfig(ctx, clinicID.String())
+	if cfg == nil || !cfg.VoiceIVREnabled {
+		return nil
+	}
+	return cfg
+}
+
+func isFormEncoded(r *http.Request) bool {
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	return err == nil && mediaType == "application/x-www-form-urlencoded"
+}
+
+func writeTeXML(w http.ResponseWriter, body string) {
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write([]byte(body))
+}
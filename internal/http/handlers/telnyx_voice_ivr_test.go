@@ -0,0 +1,217 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/google/uuid"
+	"github.com/pashagolub/pgxmock/v4"
+	"github.com/redis/go-redis/v9"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/clinic"
+	"github.com/wolfman30/medspa-ai-platform/internal/leads"
+	"github.com/wolfman30/medspa-ai-platform/internal/messaging"
+	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
+)
+
+func newTelnyxIVRHandler(t *testing.T, ivrEnabled bool) (*TelnyxWebhookHandler, pgxmock.PgxPoolIface, *stubConversationPublisher, *testTelnyxClient, uuid.UUID) {
+	t.Helper()
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("pgxmock: %v", err)
+	}
+	t.Cleanup(mock.Close)
+
+	clinicID := uuid.New()
+	mr := miniredis.RunT(t)
+	clinicStore := clinic.NewStore(redis.NewClient(&redis.Options{Addr: mr.Addr()}))
+	cfg := clinic.DefaultConfig(clinicID.String())
+	cfg.Name = "Glow Med Spa"
+	cfg.VoiceIVREnabled = ivrEnabled
+	if err := clinicStore.Set(context.Background(), cfg); err != nil {
+		t.Fatalf("set clinic config: %v", err)
+	}
+
+	conv := &stubConversationPublisher{}
+	telnyxStub := &testTelnyxClient{}
+	handler := NewTelnyxWebhookHandler(TelnyxWebhookConfig{
+		Store:            messaging.NewStore(mock),
+		Processed:        &stubProcessedTracker{},
+		Telnyx:           telnyxStub,
+		Conversation:     conv,
+		Leads:            &stubLeadsRepo{lead: &leads.Lead{ID: "lead-ivr", OrgID: clinicID.String()}},
+		Logger:           logging.Default(),
+		ClinicStore:      clinicStore,
+		MessagingProfile: "profile",
+	})
+	return handler, mock, conv, telnyxStub, clinicID
+}
+
+func expectClinicLookup(mock pgxmock.PgxPoolIface, clinicID uuid.UUID) {
+	mock.ExpectQuery("SELECT clinic_id").
+		WithArgs("+15559998888").
+		WillReturnRows(pgxmock.NewRows([]string{"clinic_id"}).AddRow(clinicID))
+}
+
+func postTeXML(handle http.HandlerFunc, path string, form url.Values) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Telnyx-Timestamp", "123")
+	req.Header.Set("Telnyx-Signature", "abc")
+	rec := httptest.NewRecorder()
+	handle(rec, req)
+	return rec
+}
+
+func TestTelnyxTeXMLVoice_IVREnabledReturnsGreeting(t *testing.T) {
+	handler, mock, conv, _, clinicID := newTelnyxIVRHandler(t, true)
+	expectClinicLookup(mock, clinicID)
+
+	rec := postTeXML(handler.HandleVoice, "/webhooks/telnyx/voice", url.Values{
+		"CallSid": {"v3:call"}, "CallStatus": {"ringing"}, "From": {"+15550002222"}, "To": {"+15559998888"},
+	})
+
+	want := `<?xml version="1.0" encoding="UTF-8"?><Response>` +
+		`<Gather numDigits="1" timeout="5" action="/webhooks/telnyx/voice/gather" method="POST">` +
+		`<Say>Thanks for calling Glow Med Spa. Press 1 and we&#39;ll text you to get you booked, or stay on the line to leave a voicemail.</Say>` +
+		`</Gather>` +
+		`<Say>Please leave a message after the tone.</Say>` +
+		`<Record maxLength="120" playBeep="true" action="/webhooks/telnyx/voice/voicemail" method="POST"` +
+		` recordingStatusCallback="/webhooks/telnyx/voice/recording?from=%2B15550002222&amp;to=%2B15559998888" recordingStatusCallbackMethod="POST"/>` +
+		`<Hangup/>` +
+		`</Response>`
+	if rec.Code != http.StatusOK || rec.Body.String() != want {
+		t.Fatalf("unexpected TeXML %d:\n got: %s\nwant: %s", rec.Code, rec.Body.String(), want)
+	}
+	if rec.Header().Get("Content-Type") != "application/xml" {
+		t.Fatalf("expected xml content type, got %q", rec.Header().Get("Content-Type"))
+	}
+	if conv.startCalls != 0 {
+		t.Fatal("greeting should not start the text-back flow")
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestTelnyxTeXMLVoice_IVRDisabledTextsBack(t *testing.T) {
+	handler, mock, conv, telnyxStub, clinicID := newTelnyxIVRHandler(t, false)
+	expectClinicLookup(mock, clinicID)
+	expectClinicLookup(mock, clinicID)
+
+	rec := postTeXML(handler.HandleVoice, "/webhooks/telnyx/voice", url.Values{
+		"CallSid": {"v3:call"}, "CallStatus": {"ringing"}, "From": {"+15550002222"}, "To": {"+15559998888"},
+	})
+
+	if !strings.Contains(rec.Body.String(), "<Reject") {
+		t.Fatalf("expected reject TeXML, got %s", rec.Body.String())
+	}
+	if conv.startCalls != 1 || conv.lastStart.Source != "telnyx_voice" {
+		t.Fatalf("expected telnyx_voice text-back, got %d calls source %q", conv.startCalls, conv.lastStart.Source)
+	}
+	if telnyxStub.lastSendReq == nil || telnyxStub.lastSendReq.To != "+15550002222" {
+		t.Fatal("expected missed-call ack to be sent")
+	}
+}
+
+func TestTelnyxVoiceGather_PressOneStartsTextBack(t *testing.T) {
+	handler, mock, conv, telnyxStub, clinicID := newTelnyxIVRHandler(t, true)
+	expectClinicLookup(mock, clinicID)
+
+	rec := postTeXML(handler.HandleVoiceGather, messaging.TelnyxIVRGatherPath, url.Values{
+		"CallSid": {"v3:call"}, "Digits": {"1"}, "From": {"+15550002222"}, "To": {"+15559998888"},
+	})
+
+	if rec.Code != http.StatusOK || rec.Body.String() != messaging.IVRTextSentXML() {
+		t.Fatalf("unexpected response %d: %s", rec.Code, rec.Body.String())
+	}
+	if conv.startCalls != 1 || conv.lastStart.Source != messaging.LeadSourceVoiceIVR {
+		t.Fatalf("expected voice_ivr start, got %d calls source %q", conv.startCalls, conv.lastStart.Source)
+	}
+	if conv.lastStart.OrgID != clinicID.String() || conv.lastStart.LeadID != "lead-ivr" {
+		t.Fatalf("unexpected start request: %+v", conv.lastStart)
+	}
+	if telnyxStub.lastSendReq == nil || telnyxStub.lastSendReq.To != "+15550002222" || telnyxStub.lastSendReq.From != "+15559998888" {
+		t.Fatal("expected ack text to the caller")
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestTelnyxVoiceGather_NoDigitFallsThroughToVoicemail(t *testing.T) {
+	handler, _, conv, telnyxStub, _ := newTelnyxIVRHandler(t, true)
+
+	rec := postTeXML(handler.HandleVoiceGather, messaging.TelnyxIVRGatherPath, url.Values{
+		"CallSid": {"v3:call"}, "Digits": {""}, "From": {"+15550002222"}, "To": {"+15559998888"},
+	})
+
+	if rec.Body.String() != messaging.VoicemailXML(messaging.TelnyxIVRPaths, "+15550002222", "+15559998888") {
+		t.Fatalf("expected voicemail TeXML, got %s", rec.Body.String())
+	}
+	if conv.startCalls != 0 || telnyxStub.lastSendReq != nil {
+		t.Fatal("voicemail path should not text the caller")
+	}
+}
+
+func TestTelnyxVoicemailDone_HangsUp(t *testing.T) {
+	handler, _, conv, _, _ := newTelnyxIVRHandler(t, true)
+
+	rec := postTeXML(handler.HandleVoicemailDone, messaging.TelnyxVoicemailPath, url.Values{
+		"CallSid": {"v3:call"}, "RecordingSid": {"rec-1"}, "RecordingUrl": {"https://telnyx.example/rec-1.mp3"},
+	})
+	if rec.Code != http.StatusOK || rec.Body.String() != messaging.VoicemailDoneXML() {
+		t.Fatalf("expected hangup TeXML, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if len(conv.voicemails) != 0 {
+		t.Fatal("the record action should not queue the voicemail")
+	}
+}
+
+func TestTelnyxVoiceRecording_QueuesVoicemail(t *testing.T) {
+	handler, mock, conv, telnyxStub, clinicID := newTelnyxIVRHandler(t, true)
+	expectClinicLookup(mock, clinicID)
+
+	path := messaging.TelnyxRecordingPath + "?from=%2B15550002222&to=%2B15559998888"
+	rec := postTeXML(handler.HandleVoiceRecording, path, url.Values{
+		"CallSid": {"v3:call"}, "RecordingSid": {"rec-2"}, "RecordingUrl": {"https://telnyx.example/rec-2.mp3"},
+		"RecordingStatus": {"completed"}, "RecordingDuration": {"17"},
+	})
+
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("expected 204, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if len(conv.voicemails) != 1 {
+		t.Fatalf("expected one voicemail queued, got %d", len(conv.voicemails))
+	}
+	vm := conv.voicemails[0]
+	if vm.OrgID != clinicID.String() || vm.LeadID != "lead-ivr" || vm.ConversationID != telnyxConversationID(clinicID.String(), "+15550002222") {
+		t.Fatalf("unexpected voicemail routing: %+v", vm)
+	}
+	if vm.RecordingID != "rec-2" || vm.RecordingURL != "https://telnyx.example/rec-2.mp3" || vm.DurationSeconds != 17 {
+		t.Fatalf("unexpected recording: %+v", vm)
+	}
+	if telnyxStub.lastSendReq != nil || conv.startCalls != 0 {
+		t.Fatal("voicemail should not text the caller")
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestTelnyxVoiceRecording_IgnoresUnfinishedRecording(t *testing.T) {
+	handler, _, conv, _, _ := newTelnyxIVRHandler(t, true)
+
+	path := messaging.TelnyxRecordingPath + "?from=%2B15550002222&to=%2B15559998888"
+	rec := postTeXML(handler.HandleVoiceRecording, path, url.Values{
+		"CallSid": {"v3:call"}, "RecordingSid": {"rec-3"}, "RecordingStatus": {"in-progress"},
+	})
+	if rec.Code != http.StatusNoContent || len(conv.voicemails) != 0 {
+		t.Fatalf("expected unfinished recording ignored, got %d with %d voicemails", rec.Code, len(conv.voicemails))
+	}
+}
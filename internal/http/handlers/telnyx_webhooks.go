@@ -9,12 +9,16 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/wolfman30/medspa-ai-platform/internal/broadcasts"
 	"github.com/wolfman30/medspa-ai-platform/internal/clinic"
 	"github.com/wolfman30/medspa-ai-platform/internal/conversation"
+	"github.com/wolfman30/medspa-ai-platform/internal/events"
+	"github.com/wolfman30/medspa-ai-platform/internal/http/apierror"
 	"github.com/wolfman30/medspa-ai-platform/internal/leads"
 	"github.com/wolfman30/medspa-ai-platform/internal/messaging"
 	"github.com/wolfman30/medspa-ai-platform/internal/messaging/compliance"
 	"github.com/wolfman30/medspa-ai-platform/internal/messaging/telnyxclient"
+	"github.com/wolfman30/medspa-ai-platform/internal/messaging/templates"
 	observemetrics "github.com/wolfman30/medspa-ai-platform/internal/observability/metrics"
 	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
 )
@@ -24,6 +28,12 @@ type conversationPublisher interface {
 	EnqueueMessage(ctx context.Context, jobID string, req conversation.MessageRequest, opts ...conversation.PublishOption) error
 }
 
+// DeliveryFailover re-sends an outbound message a carrier blocked, as
+// reported on its delivery receipt.
+type DeliveryFailover interface {
+	ResendBlocked(ctx context.Context, provider, providerMessageID, code string) (bool, error)
+}
+
 type processedTracker interface {
 	AlreadyProcessed(ctx context.Context, provider, eventID string) (bool, error)
 	MarkProcessed(ctx context.Context, provider, eventID string) (bool, error)
@@ -38,6 +48,15 @@ type conversationStatusUpdater interface {
 	UpdateMessageStatusByProviderID(ctx context.Context, providerMessageID, status, errorReason string) error
 }
 
+// BroadcastReplySource finds the broadcast a phone most recently received.
+type BroadcastReplySource interface {
+	ReplySourceFor(ctx context.Context, orgID, phone string, since time.Time) (broadcasts.ReplySource, bool, error)
+}
+
+// broadcastReplyWindow is how long after a broadcast a reply is attributed
+// to it.
+const broadcastReplyWindow = 7 * 24 * time.Hour
+
 var errClinicNotFound = errors.New("clinic not found")
 
 // TelnyxWebhookHandler handles inbound Telnyx webhooks for messaging and hosted orders.
@@ -52,15 +71,21 @@ type TelnyxWebhookHandler struct {
 	convStore        conversationStore
 	clinicStore      *clinic.Store
 	messagingProfile string
-	stopAck          string
-	helpAck          string
-	startAck         string
+	ackOverrides     templates.Overrides
 	firstContactAck  string
 	voiceAck         string
 	demoMode         bool
 	trackJobs        bool
 	detector         *compliance.Detector
 	metrics          *observemetrics.MessagingMetrics
+	eventLog         events.WebhookRecorder
+	concat           *InboundConcatBuffer
+	routes           messaging.OrgResolver
+	provisioning     numberStatusUpdater
+	broadcasts       BroadcastReplySource
+	audit            messaging.SensitiveDataAuditor
+	liveness         messaging.InboundRecorder
+	failover         DeliveryFailover
 }
 
 // TelnyxWebhookConfig holds configuration for constructing a TelnyxWebhookHandler.
@@ -83,6 +108,28 @@ type TelnyxWebhookConfig struct {
 	DemoMode          bool
 	TrackJobs         bool
 	Metrics           *observemetrics.MessagingMetrics
+	// EventLog, when set, captures raw message webhooks for replay.
+	EventLog events.WebhookRecorder
+	// ConcatBuffer, when set, combines multi-part inbound messages before dispatch.
+	ConcatBuffer *InboundConcatBuffer
+	// NumberRoutes, when set, supplies per-number metadata (default service,
+	// campaign tag) attached to conversation jobs.
+	NumberRoutes messaging.OrgResolver
+	// Provisioning, when set, records number order and 10DLC campaign status
+	// from HandleNumbers.
+	Provisioning numberStatusUpdater
+	// Broadcasts, when set, tags replies to a recent broadcast with its ID
+	// and text.
+	Broadcasts BroadcastReplySource
+	// Audit, when set, records card numbers and SSNs redacted from inbound
+	// messages.
+	Audit messaging.SensitiveDataAuditor
+	// Liveness, when set, records each inbound message's arrival for the
+	// inbound liveness check.
+	Liveness messaging.InboundRecorder
+	// Failover, when set, re-sends messages whose delivery receipt reports a
+	// carrier block through the secondary SMS provider.
+	Failover DeliveryFailover
 }
 
 // NewTelnyxWebhookHandler creates a new handler with the given configuration.
@@ -101,16 +148,63 @@ func NewTelnyxWebhookHandler(cfg TelnyxWebhookConfig) *TelnyxWebhookHandler {
 		convStore:        cfg.ConversationStore,
 		clinicStore:      cfg.ClinicStore,
 		messagingProfile: cfg.MessagingProfile,
-		stopAck:          defaultString(cfg.StopAck, "You have been opted out. Reply HELP for info."),
-		helpAck:          defaultString(cfg.HelpAck, "Reply STOP to opt out or contact support@medspa.ai."),
-		startAck:         defaultString(cfg.StartAck, "You're opted back in. Reply STOP to opt out."),
+		ackOverrides:     messaging.ComplianceAckOverrides(cfg.StopAck, cfg.HelpAck, cfg.StartAck),
 		firstContactAck:  strings.TrimSpace(cfg.FirstContactAck),
 		voiceAck:         defaultString(cfg.VoiceAck, messaging.InstantAckMessage),
 		demoMode:         cfg.DemoMode,
 		trackJobs:        cfg.TrackJobs,
 		detector:         compliance.NewDetector(),
 		metrics:          cfg.Metrics,
+		eventLog:         cfg.EventLog,
+		concat:           cfg.ConcatBuffer,
+		routes:           cfg.NumberRoutes,
+		provisioning:     cfg.Provisioning,
+		broadcasts:       cfg.Broadcasts,
+		audit:            cfg.Audit,
+		liveness:         cfg.Liveness,
+		failover:         cfg.Failover,
+	}
+}
+
+// withRouteMetadata adds the dialed number's routing metadata to meta. Routes
+// for a different org than the one the hosted number resolved to are ignored.
+func (h *TelnyxWebhookHandler) withRouteMetadata(ctx context.Context, orgID, to string, meta map[string]string) map[string]string {
+	if h.routes == nil {
+		return meta
+	}
+	route, err := h.routes.ResolveRoute(ctx, to)
+	if err != nil || route.OrgID != orgID {
+		return meta
+	}
+	for k, v := range route.Metadata() {
+		if meta == nil {
+			meta = map[string]string{}
+		}
+		meta[k] = v
+	}
+	return meta
+}
+
+// withBroadcastMetadata tags meta with the broadcast the sender most recently
+// received, when it went out within broadcastReplyWindow.
+func (h *TelnyxWebhookHandler) withBroadcastMetadata(ctx context.Context, orgID, from string, meta map[string]string) map[string]string {
+	if h.broadcasts == nil {
+		return meta
+	}
+	src, ok, err := h.broadcasts.ReplySourceFor(ctx, orgID, from, time.Now().Add(-broadcastReplyWindow))
+	if err != nil {
+		h.logger.WithContext(ctx).Warn("broadcast reply lookup failed", "error", err, "org_id", orgID)
+		return meta
+	}
+	if !ok {
+		return meta
+	}
+	if meta == nil {
+		meta = map[string]string{}
 	}
+	meta[conversation.MetadataBroadcastID] = src.BroadcastID.String()
+	meta[conversation.MetadataBroadcastMessage] = src.Message
+	return meta
 }
 
 func (h *TelnyxWebhookHandler) appendTranscript(ctx context.Context, conversationID string, msg conversation.SMSTranscriptMessage) {
@@ -151,45 +245,34 @@ func (h *TelnyxWebhookHandler) clinicConfig(ctx context.Context, orgID string) *
 	return cfg
 }
 
-func (h *TelnyxWebhookHandler) clinicName(ctx context.Context, orgID string) string {
+// voiceAckMessage renders the missed-call text-back. An environment-level
+// VoiceAck or the clinic's AIPersona greeting replaces the template's copy.
+func (h *TelnyxWebhookHandler) voiceAckMessage(ctx context.Context, orgID string) templates.Rendered {
 	cfg := h.clinicConfig(ctx, orgID)
-	if cfg == nil {
-		return ""
-	}
-	return strings.TrimSpace(cfg.Name)
-}
-
-func (h *TelnyxWebhookHandler) voiceAckMessage(ctx context.Context, orgID string) string {
+	custom := ""
 	// Check for environment-level override first
 	if strings.TrimSpace(h.voiceAck) != "" && h.voiceAck != messaging.InstantAckMessage {
-		return h.voiceAck
-	}
-
-	// Get clinic config to check for AIPersona greetings
-	cfg := h.clinicConfig(ctx, orgID)
-	if cfg != nil {
+		custom = h.voiceAck
+	} else if cfg != nil {
 		// Use time-aware greeting: after-hours greeting when closed, custom greeting when open
 		now := time.Now()
 		isOpen := cfg.IsOpenAt(now)
 
 		// If closed and has after-hours greeting, use it
 		if !isOpen && strings.TrimSpace(cfg.AIPersona.AfterHoursGreeting) != "" {
-			return cfg.AIPersona.AfterHoursGreeting
-		}
-
-		// If open (or no after-hours greeting) and has custom greeting, use it
-		if strings.TrimSpace(cfg.AIPersona.CustomGreeting) != "" {
-			return cfg.AIPersona.CustomGreeting
+			custom = cfg.AIPersona.AfterHoursGreeting
+		} else if strings.TrimSpace(cfg.AIPersona.CustomGreeting) != "" {
+			// If open (or no after-hours greeting) and has custom greeting, use it
+			custom = cfg.AIPersona.CustomGreeting
 		}
-
 	}
-
-	// Fall back to standard template with clinic name (no callback option)
-	name := ""
-	if cfg != nil {
-		name = strings.TrimSpace(cfg.Name)
+	if custom == "" {
+		// Fall back to standard template with clinic name (no callback option)
+		return messaging.MissedCallAck(cfg)
 	}
-	return messaging.InstantAckMessageForClinic(name)
+	return templates.Render(templates.AckMissedCallClinic, nil, templates.Overrides{
+		templates.AckMissedCallClinic: {Text: custom},
+	})
 }
 
 func (h *TelnyxWebhookHandler) linkLead(ctx context.Context, conversationID, leadID string) {
@@ -211,57 +294,81 @@ func (h *TelnyxWebhookHandler) linkLead(ctx context.Context, conversationID, lea
 // HandleMessages processes Telnyx message webhooks (inbound messages + delivery receipts).
 func (h *TelnyxWebhookHandler) HandleMessages(w http.ResponseWriter, r *http.Request) {
 	if h.telnyx == nil {
-		http.Error(w, "telnyx client not configured", http.StatusServiceUnavailable)
+		apierror.Write(w, r, apierror.CodeDependencyUnavailable, "telnyx client not configured")
 		return
 	}
 	start := time.Now()
 	body, err := io.ReadAll(r.Body)
 	if err != nil {
-		http.Error(w, "invalid body", http.StatusBadRequest)
+		apierror.Write(w, r, apierror.CodeValidationFailed, "invalid body")
 		return
 	}
 	if err := h.telnyx.VerifyWebhookSignature(r.Header.Get("Telnyx-Timestamp"), r.Header.Get("Telnyx-Signature"), body); err != nil {
 		h.logger.Warn("invalid telnyx webhook signature", "error", err)
-		http.Error(w, "invalid signature", http.StatusForbidden)
+		apierror.Write(w, r, apierror.CodeForbidden, "invalid signature")
 		return
 	}
+	var eventID string
+	if evt, err := parseTelnyxEvent(body); err == nil {
+		eventID = evt.ID
+	}
+	// The captured copy never holds card numbers or SSNs; a replay sees the
+	// placeholders instead.
+	captured, _ := compliance.RedactSensitiveNumbers(string(body))
+	events.CaptureWebhook(r.Context(), h.eventLog, h.logger, "telnyx", eventID, r.Header, []byte(captured), w, func(w http.ResponseWriter) {
+		h.processMessages(r.Context(), w, body, start, false)
+	})
+}
+
+// Replay re-runs a captured Telnyx message webhook without signature
+// verification. The processed-event marker is honored unless force is set.
+func (h *TelnyxWebhookHandler) Replay(ctx context.Context, body []byte, force bool) *events.ResponseCapture {
+	capture := events.NewResponseCapture(nil)
+	h.processMessages(ctx, capture, body, time.Now(), force)
+	return capture
+}
+
+func (h *TelnyxWebhookHandler) processMessages(ctx context.Context, w http.ResponseWriter, body []byte, start time.Time, force bool) {
+	log := h.logger.WithContext(ctx)
 	evt, err := parseTelnyxEvent(body)
 	if err != nil {
-		http.Error(w, "invalid payload", http.StatusBadRequest)
+		apierror.WriteContext(ctx, w, apierror.CodeValidationFailed, "invalid payload")
 		return
 	}
-	if processed, err := h.processed.AlreadyProcessed(r.Context(), "telnyx", evt.ID); err != nil {
-		h.logger.Error("processed lookup failed", "error", err)
-		http.Error(w, "server error", http.StatusInternalServerError)
-		return
-	} else if processed {
-		w.WriteHeader(http.StatusOK)
-		return
+	if !force {
+		if processed, err := h.processed.AlreadyProcessed(ctx, "telnyx", evt.ID); err != nil {
+			log.Error("processed lookup failed", "error", err)
+			apierror.WriteContext(ctx, w, apierror.CodeInternal, "server error")
+			return
+		} else if processed {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
 	}
 	var handlerErr error
 	switch evt.EventType {
 	case "message.received":
-		handlerErr = h.handleInbound(r.Context(), evt)
+		handlerErr = h.handleInbound(ctx, evt)
 	case "message.delivery_status":
-		handlerErr = h.handleDeliveryStatus(r.Context(), evt)
+		handlerErr = h.handleDeliveryStatus(ctx, evt)
 	default:
 		w.WriteHeader(http.StatusNoContent)
 		return
 	}
 	if handlerErr != nil {
 		if errors.Is(handlerErr, errClinicNotFound) {
-			http.Error(w, handlerErr.Error(), http.StatusNotFound)
+			apierror.WriteContext(ctx, w, apierror.CodeNotFound, handlerErr.Error())
 			return
 		}
-		h.logger.Error("telnyx webhook handling failed", "error", handlerErr, "event_type", evt.EventType)
-		http.Error(w, "processing error", http.StatusInternalServerError)
+		log.Error("telnyx webhook handling failed", "error", handlerErr, "event_type", evt.EventType)
+		apierror.WriteContext(ctx, w, apierror.CodeInternal, "processing error")
 		return
 	}
 	if h.metrics != nil {
 		h.metrics.ObserveWebhookLatency(evt.EventType, time.Since(start).Seconds())
 	}
-	if _, err := h.processed.MarkProcessed(r.Context(), "telnyx", evt.ID); err != nil {
-		h.logger.Error("failed to mark telnyx event processed", "error", err, "event_id", evt.ID)
+	if _, err := h.processed.MarkProcessed(ctx, "telnyx", evt.ID); err != nil {
+		log.Error("failed to mark telnyx event processed", "error", err, "event_id", evt.ID)
 	}
 	w.WriteHeader(http.StatusOK)
 }
@@ -269,27 +376,27 @@ func (h *TelnyxWebhookHandler) HandleMessages(w http.ResponseWriter, r *http.Req
 // HandleHosted processes hosted messaging order lifecycle events.
 func (h *TelnyxWebhookHandler) HandleHosted(w http.ResponseWriter, r *http.Request) {
 	if h.telnyx == nil {
-		http.Error(w, "telnyx client not configured", http.StatusServiceUnavailable)
+		apierror.Write(w, r, apierror.CodeDependencyUnavailable, "telnyx client not configured")
 		return
 	}
 	body, err := io.ReadAll(r.Body)
 	if err != nil {
-		http.Error(w, "invalid body", http.StatusBadRequest)
+		apierror.Write(w, r, apierror.CodeValidationFailed, "invalid body")
 		return
 	}
 	if err := h.telnyx.VerifyWebhookSignature(r.Header.Get("Telnyx-Timestamp"), r.Header.Get("Telnyx-Signature"), body); err != nil {
 		h.logger.Warn("invalid telnyx hosted signature", "error", err)
-		http.Error(w, "invalid signature", http.StatusForbidden)
+		apierror.Write(w, r, apierror.CodeForbidden, "invalid signature")
 		return
 	}
 	evt, err := parseTelnyxEvent(body)
 	if err != nil {
-		http.Error(w, "invalid payload", http.StatusBadRequest)
+		apierror.Write(w, r, apierror.CodeValidationFailed, "invalid payload")
 		return
 	}
 	if processed, err := h.processed.AlreadyProcessed(r.Context(), "telnyx", evt.ID); err != nil {
 		h.logger.Error("processed lookup failed", "error", err)
-		http.Error(w, "server error", http.StatusInternalServerError)
+		apierror.Write(w, r, apierror.CodeInternal, "server error")
 		return
 	} else if processed {
 		w.WriteHeader(http.StatusOK)
@@ -297,7 +404,7 @@ func (h *TelnyxWebhookHandler) HandleHosted(w http.ResponseWriter, r *http.Reque
 	}
 	if err := h.handleHostedOrder(r.Context(), evt); err != nil {
 		h.logger.Error("hosted order event failed", "error", err)
-		http.Error(w, "processing error", http.StatusInternalServerError)
+		apierror.Write(w, r, apierror.CodeInternal, "processing error")
 		return
 	}
 	if _, err := h.processed.MarkProcessed(r.Context(), "telnyx", evt.ID); err != nil {
@@ -309,27 +416,31 @@ func (h *TelnyxWebhookHandler) HandleHosted(w http.ResponseWriter, r *http.Reque
 // HandleVoice processes Telnyx hosted voice webhooks for missed-call triggers.
 func (h *TelnyxWebhookHandler) HandleVoice(w http.ResponseWriter, r *http.Request) {
 	if h.telnyx == nil {
-		http.Error(w, "telnyx client not configured", http.StatusServiceUnavailable)
+		apierror.Write(w, r, apierror.CodeDependencyUnavailable, "telnyx client not configured")
 		return
 	}
 	body, err := io.ReadAll(r.Body)
 	if err != nil {
-		http.Error(w, "invalid body", http.StatusBadRequest)
+		apierror.Write(w, r, apierror.CodeValidationFailed, "invalid body")
 		return
 	}
 	if err := h.telnyx.VerifyWebhookSignature(r.Header.Get("Telnyx-Timestamp"), r.Header.Get("Telnyx-Signature"), body); err != nil {
 		h.logger.Warn("invalid telnyx voice signature", "error", err)
-		http.Error(w, "invalid signature", http.StatusForbidden)
+		apierror.Write(w, r, apierror.CodeForbidden, "invalid signature")
+		return
+	}
+	if isFormEncoded(r) {
+		h.handleTeXMLVoice(w, r, body)
 		return
 	}
 	evt, err := parseTelnyxEvent(body)
 	if err != nil {
-		http.Error(w, "invalid payload", http.StatusBadRequest)
+		apierror.Write(w, r, apierror.CodeValidationFailed, "invalid payload")
 		return
 	}
 	if processed, err := h.processed.AlreadyProcessed(r.Context(), "telnyx", evt.ID); err != nil {
 		h.logger.Error("processed lookup failed", "error", err)
-		http.Error(w, "server error", http.StatusInternalServerError)
+		apierror.Write(w, r, apierror.CodeInternal, "server error")
 		return
 	} else if processed {
 		w.WriteHeader(http.StatusOK)
@@ -338,11 +449,11 @@ func (h *TelnyxWebhookHandler) HandleVoice(w http.ResponseWriter, r *http.Reques
 	if err := h.handleVoice(r.Context(), evt); err != nil {
 		if errors.Is(err, errClinicNotFound) {
 			h.logger.Warn("telnyx voice: clinic not found", "error", err, "event_type", evt.EventType)
-			http.Error(w, err.Error(), http.StatusNotFound)
+			apierror.Write(w, r, apierror.CodeNotFound, err.Error())
 			return
 		}
 		h.logger.Error("telnyx voice handling failed", "error", err, "event_type", evt.EventType)
-		http.Error(w, "processing error", http.StatusInternalServerError)
+		apierror.Write(w, r, apierror.CodeInternal, "processing error")
 		return
 	}
 	if _, err := h.processed.MarkProcessed(r.Context(), "telnyx", evt.ID); err != nil {
@@ -351,10 +462,33 @@ func (h *TelnyxWebhookHandler) HandleVoice(w http.ResponseWriter, r *http.Reques
 	w.WriteHeader(http.StatusOK)
 }
 
+// replyWith records a templated reply in the transcript and texts it. from
+// is the clinic number and to the patient.
+func (h *TelnyxWebhookHandler) replyWith(conversationID, from, to string, msg templates.Rendered, kind string) {
+	if msg.Body == "" {
+		return
+	}
+	h.appendTranscript(context.Background(), conversationID, conversation.SMSTranscriptMessage{
+		Role:     "assistant",
+		From:     from,
+		To:       to,
+		Body:     msg.Body,
+		Kind:     kind,
+		Metadata: msg.Stamp(nil),
+	})
+	h.sendAutoReply(context.Background(), from, to, msg.Body)
+}
+
 func (h *TelnyxWebhookHandler) sendAutoReply(ctx context.Context, from, to, body string) {
 	if body == "" || h.messagingProfile == "" || h.telnyx == nil {
 		return
 	}
+	// Auto-replies go straight to Telnyx rather than through the outbound
+	// messenger, so they check the sending number's campaign here.
+	if err := messaging.CheckSendingCampaign(ctx, h.provisioning, from, h.logger); err != nil {
+		h.logger.Error("auto-reply blocked: sending number's 10DLC campaign is not approved", "error", err, "from", from)
+		return
+	}
 	payload := telnyxclient.SendMessageRequest{
 		From:               from,
 		To:                 to,
@@ -365,3 +499,36 @@ func (h *TelnyxWebhookHandler) sendAutoReply(ctx context.Context, from, to, body
 		h.logger.Warn("failed to send auto-reply", "error", err)
 	}
 }
+
+// auditSensitiveData records a redaction without the message itself.
+func (h *TelnyxWebhookHandler) auditSensitiveData(ctx context.Context, orgID, conversationID string, kinds []string) {
+	h.logger.WithContext(ctx).Warn("sensitive numbers redacted from inbound sms", "org_id", orgID, "kinds", kinds)
+	if h.audit == nil {
+		return
+	}
+	if err := h.audit.LogSensitiveDataRedacted(ctx, orgID, conversationID, "", kinds); err != nil {
+		h.logger.WithContext(ctx).Warn("failed to audit sensitive data redaction", "error", err, "org_id", orgID)
+	}
+}
+
+// replyIfInactive texts the farewell and reports true when orgID's clinic is
+// no longer served, in which case the caller must not start a conversation.
+// from is the patient and to the clinic number.
+func (h *TelnyxWebhookHandler) replyIfInactive(ctx context.Context, orgID, conversationID, from, to string) bool {
+	farewell, reason, err := messaging.InactiveClinicFarewell(ctx, h.clinicStore, orgID)
+	if err != nil {
+		h.logger.Warn("failed to check clinic status", "error", err, "org_id", orgID)
+	}
+	if reason == "" {
+		return false
+	}
+	h.logger.Info("inbound to inactive clinic: sending farewell", "org_id", orgID, "reason", reason)
+	h.metrics.ObserveInactiveOrg(reason)
+	h.replyWith(conversationID, to, from, farewell, "farewell")
+	return true
+}
+
+// complianceReply renders a compliance or guardrail reply for orgID's clinic.
+func (h *TelnyxWebhookHandler) complianceReply(ctx context.Context, orgID, id string) templates.Rendered {
+	return messaging.ComplianceReply(id, h.ackOverrides, h.clinicConfig(ctx, orgID))
+}
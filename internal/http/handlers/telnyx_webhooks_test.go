@@ -15,6 +15,7 @@ import (
 	"github.com/jackc/pgx/v5/pgconn"
 	pgxmock "github.com/pashagolub/pgxmock/v4"
 	"github.com/wolfman30/medspa-ai-platform/internal/conversation"
+	"github.com/wolfman30/medspa-ai-platform/internal/http/apierror"
 	"github.com/wolfman30/medspa-ai-platform/internal/leads"
 	"github.com/wolfman30/medspa-ai-platform/internal/messaging"
 	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
@@ -49,7 +50,7 @@ func TestTelnyxInboundStop(t *testing.T) {
 		WillReturnRows(pgxmock.NewRows([]string{"exists"}))
 	mock.ExpectBegin()
 	mock.ExpectQuery("INSERT INTO messages").
-		WithArgs(clinicID, "+15550001111", "+15559998888", "inbound", "STOP", pgxmock.AnyArg(), "received", "msg_inbound", pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg()).
+		WithArgs(clinicID, "+15550001111", "+15559998888", "inbound", "STOP", pgxmock.AnyArg(), "received", "msg_inbound", pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), "patient", pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg()).
 		WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(uuid.New()))
 	mock.ExpectExec("INSERT INTO outbox").
 		WithArgs(pgxmock.AnyArg(), "clinic:"+clinicID.String(), "messaging.message.received.v1", pgxmock.AnyArg()).
@@ -108,7 +109,7 @@ func TestTelnyxInboundEnqueuesConversation(t *testing.T) {
 		WillReturnRows(pgxmock.NewRows([]string{"exists"}))
 	mock.ExpectBegin()
 	mock.ExpectQuery("INSERT INTO messages").
-		WithArgs(clinicID, "+15550001111", "+15559998888", "inbound", "Need info", pgxmock.AnyArg(), "received", "msg_inbound", pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg()).
+		WithArgs(clinicID, "+15550001111", "+15559998888", "inbound", "Need info", pgxmock.AnyArg(), "received", "msg_inbound", pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), "patient", pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg()).
 		WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(uuid.New()))
 	mock.ExpectExec("INSERT INTO outbox").
 		WithArgs(pgxmock.AnyArg(), "clinic:"+clinicID.String(), "messaging.message.received.v1", pgxmock.AnyArg()).
@@ -172,7 +173,7 @@ func TestTelnyxInboundDuplicateProviderMessageIsIgnored(t *testing.T) {
 		WillReturnRows(pgxmock.NewRows([]string{"exists"}))
 	mock.ExpectBegin()
 	mock.ExpectQuery("INSERT INTO messages").
-		WithArgs(clinicID, "+15550001111", "+15559998888", "inbound", "Need info", pgxmock.AnyArg(), "received", "msg_inbound", pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg()).
+		WithArgs(clinicID, "+15550001111", "+15559998888", "inbound", "Need info", pgxmock.AnyArg(), "received", "msg_inbound", pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), "patient", pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg()).
 		WillReturnError(&pgconn.PgError{Code: "23505", ConstraintName: "idx_messages_provider_message"})
 	mock.ExpectRollback()
 
@@ -227,7 +228,7 @@ func TestTelnyxFirstContactAck_DemoMode(t *testing.T) {
 		WillReturnRows(pgxmock.NewRows([]string{"exists"}))
 	mock.ExpectBegin()
 	mock.ExpectQuery("INSERT INTO messages").
-		WithArgs(clinicID, "+15550001111", "+15559998888", "inbound", "Need info", pgxmock.AnyArg(), "received", "msg_inbound", pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg()).
+		WithArgs(clinicID, "+15550001111", "+15559998888", "inbound", "Need info", pgxmock.AnyArg(), "received", "msg_inbound", pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), "patient", pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg()).
 		WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(uuid.New()))
 	mock.ExpectExec("INSERT INTO outbox").
 		WithArgs(pgxmock.AnyArg(), "clinic:"+clinicID.String(), "messaging.message.received.v1", pgxmock.AnyArg()).
@@ -289,7 +290,7 @@ func TestTelnyxYesOptIn_DemoMode(t *testing.T) {
 		WillReturnRows(pgxmock.NewRows([]string{"exists"}))
 	mock.ExpectBegin()
 	mock.ExpectQuery("INSERT INTO messages").
-		WithArgs(clinicID, "+15550001111", "+15559998888", "inbound", "YES", pgxmock.AnyArg(), "received", "msg_inbound", pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg()).
+		WithArgs(clinicID, "+15550001111", "+15559998888", "inbound", "YES", pgxmock.AnyArg(), "received", "msg_inbound", pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), "patient", pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg()).
 		WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(uuid.New()))
 	mock.ExpectExec("INSERT INTO outbox").
 		WithArgs(pgxmock.AnyArg(), "clinic:"+clinicID.String(), "messaging.message.received.v1", pgxmock.AnyArg()).
@@ -453,6 +454,56 @@ func TestTelnyxDeliveryStatusRecordPayload(t *testing.T) {
 	}
 }
 
+type stubDeliveryFailover struct {
+	provider  string
+	messageID string
+	code      string
+}
+
+func (s *stubDeliveryFailover) ResendBlocked(ctx context.Context, provider, providerMessageID, code string) (bool, error) {
+	s.provider = provider
+	s.messageID = providerMessageID
+	s.code = code
+	return true, nil
+}
+
+func TestTelnyxDeliveryStatusFailsOverCarrierBlock(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("pgxmock: %v", err)
+	}
+	defer mock.Close()
+	failover := &stubDeliveryFailover{}
+	handler := NewTelnyxWebhookHandler(TelnyxWebhookConfig{
+		Store:     messaging.NewStore(mock),
+		Processed: &stubProcessedTracker{},
+		Telnyx:    &testTelnyxClient{},
+		Logger:    logging.Default(),
+		Failover:  failover,
+	})
+
+	mock.ExpectExec("UPDATE messages").
+		WithArgs("msg_outbound_record", "delivery_failed", pgxmock.AnyArg(), pgxmock.AnyArg()).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+
+	req := httptest.NewRequest(http.MethodPost, "/webhooks/telnyx/messages", bytes.NewReader(loadFixture(t, "telnyx_dlr_record_failed.json")))
+	req.Header.Set("Telnyx-Timestamp", "123")
+	req.Header.Set("Telnyx-Signature", "abc")
+	rec := httptest.NewRecorder()
+
+	handler.HandleMessages(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d body=%s", rec.Code, rec.Body.String())
+	}
+	if failover.provider != messaging.SMSProviderTelnyx || failover.messageID != "msg_outbound_record" || failover.code != "40002" {
+		t.Fatalf("unexpected failover call: %+v", failover)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
 func TestTelnyxHostedActivated(t *testing.T) {
 	mock, err := pgxmock.NewPool()
 	if err != nil {
@@ -509,6 +560,9 @@ func TestTelnyxWebhookInvalidSignature(t *testing.T) {
 	if rec.Code != http.StatusForbidden {
 		t.Fatalf("expected 403, got %d", rec.Code)
 	}
+	if body := decodeAPIError(t, rec); body.Code != apierror.CodeForbidden {
+		t.Fatalf("expected forbidden, got %+v", body)
+	}
 }
 
 func TestTelnyxWebhookAlreadyProcessed(t *testing.T) {
@@ -551,9 +605,13 @@ func TestTelnyxWebhookClinicNotFound(t *testing.T) {
 	req.Header.Set("Telnyx-Signature", "abc")
 	rec := httptest.NewRecorder()
 	handler.HandleMessages(rec, req)
+	// The envelope changes the body only; the status Telnyx sees is unchanged.
 	if rec.Code != http.StatusNotFound {
 		t.Fatalf("expected 404, got %d", rec.Code)
 	}
+	if body := decodeAPIError(t, rec); body.Code != apierror.CodeNotFound {
+		t.Fatalf("expected not_found, got %+v", body)
+	}
 }
 
 func TestTelnyxDeliveryStatusUpdateError(t *testing.T) {
@@ -607,7 +665,7 @@ func TestTelnyxHelpAutoReply(t *testing.T) {
 		WillReturnRows(pgxmock.NewRows([]string{"exists"}))
 	mock.ExpectBegin()
 	mock.ExpectQuery("INSERT INTO messages").
-		WithArgs(clinicID, "+15550001111", "+15559998888", "inbound", "HELP", pgxmock.AnyArg(), "received", "msg_inbound", pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg()).
+		WithArgs(clinicID, "+15550001111", "+15559998888", "inbound", "HELP", pgxmock.AnyArg(), "received", "msg_inbound", pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), "patient", pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg()).
 		WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(uuid.New()))
 	mock.ExpectExec("INSERT INTO outbox").
 		WithArgs(pgxmock.AnyArg(), "clinic:"+clinicID.String(), "messaging.message.received.v1", pgxmock.AnyArg()).
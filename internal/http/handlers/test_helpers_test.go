@@ -8,6 +8,7 @@ import (
 	"github.com/wolfman30/medspa-ai-platform/internal/conversation"
 	"github.com/wolfman30/medspa-ai-platform/internal/leads"
 	"github.com/wolfman30/medspa-ai-platform/internal/messaging/telnyxclient"
+	"github.com/wolfman30/medspa-ai-platform/internal/tenancy"
 )
 
 type testTelnyxClient struct {
@@ -115,6 +116,12 @@ type stubConversationPublisher struct {
 	lastJob    string
 	last       conversation.MessageRequest
 	lastStart  conversation.StartRequest
+	voicemails []conversation.VoicemailRequest
+}
+
+func (s *stubConversationPublisher) EnqueueVoicemail(ctx context.Context, req conversation.VoicemailRequest) error {
+	s.voicemails = append(s.voicemails, req)
+	return nil
 }
 
 func (s *stubConversationPublisher) EnqueueMessage(ctx context.Context, jobID string, req conversation.MessageRequest, opts ...conversation.PublishOption) error {
@@ -172,7 +179,7 @@ func (s *stubLeadsRepo) Create(context.Context, *leads.CreateLeadRequest) (*lead
 	return nil, nil
 }
 
-func (s *stubLeadsRepo) GetByID(context.Context, string, string) (*leads.Lead, error) {
+func (s *stubLeadsRepo) GetByID(context.Context, tenancy.OrgScope, string) (*leads.Lead, error) {
 	return nil, nil
 }
 
@@ -187,23 +194,27 @@ func (s *stubLeadsRepo) GetOrCreateByPhone(ctx context.Context, orgID string, ph
 	return &leads.Lead{ID: "lead-stub", OrgID: orgID, Phone: phone, Source: source}, nil
 }
 
-func (s *stubLeadsRepo) UpdateSchedulingPreferences(context.Context, string, leads.SchedulingPreferences) error {
+func (s *stubLeadsRepo) UpdateSchedulingPreferences(context.Context, tenancy.OrgScope, string, leads.SchedulingPreferences) error {
+	return nil
+}
+
+func (s *stubLeadsRepo) MergePreferences(context.Context, string, leads.SchedulingPreferences) error {
 	return nil
 }
 
-func (s *stubLeadsRepo) UpdateDepositStatus(context.Context, string, string, string) error {
+func (s *stubLeadsRepo) UpdateDepositStatus(context.Context, tenancy.OrgScope, string, string, string) error {
 	return nil
 }
 
-func (s *stubLeadsRepo) ListByOrg(context.Context, string, leads.ListLeadsFilter) ([]*leads.Lead, error) {
+func (s *stubLeadsRepo) ListByOrg(context.Context, tenancy.OrgScope, leads.ListLeadsFilter) ([]*leads.Lead, error) {
 	return nil, nil
 }
 
-func (s *stubLeadsRepo) UpdateSelectedAppointment(context.Context, string, leads.SelectedAppointment) error {
+func (s *stubLeadsRepo) UpdateSelectedAppointment(context.Context, tenancy.OrgScope, string, leads.SelectedAppointment) error {
 	return nil
 }
 
-func (s *stubLeadsRepo) UpdateBookingSession(context.Context, string, leads.BookingSessionUpdate) error {
+func (s *stubLeadsRepo) UpdateBookingSession(context.Context, tenancy.OrgScope, string, leads.BookingSessionUpdate) error {
 	return nil
 }
 
@@ -211,7 +222,7 @@ func (s *stubLeadsRepo) GetByBookingSessionID(context.Context, string) (*leads.L
 	return nil, nil
 }
 
-func (s *stubLeadsRepo) UpdateEmail(context.Context, string, string) error {
+func (s *stubLeadsRepo) UpdateEmail(context.Context, tenancy.OrgScope, string, string) error {
 	return nil
 }
 
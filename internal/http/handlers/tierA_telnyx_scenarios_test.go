@@ -16,6 +16,7 @@ import (
 	"github.com/wolfman30/medspa-ai-platform/internal/conversation"
 	"github.com/wolfman30/medspa-ai-platform/internal/leads"
 	"github.com/wolfman30/medspa-ai-platform/internal/messaging"
+	"github.com/wolfman30/medspa-ai-platform/internal/tenancy"
 	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
 )
 
@@ -71,7 +72,7 @@ func TestTierA_CI01_MissedCallIntroSMS(t *testing.T) {
 	if telnyxStub.lastSendReq == nil || telnyxStub.lastSendReq.Body != messaging.InstantAckMessage {
 		t.Fatalf("expected missed-call intro SMS, got %#v", telnyxStub.lastSendReq)
 	}
-	list, err := leadsRepo.ListByOrg(context.Background(), clinicID.String(), leads.ListLeadsFilter{})
+	list, err := leadsRepo.ListByOrg(context.Background(), tenancy.ForOrg(clinicID.String()), leads.ListLeadsFilter{})
 	if err != nil {
 		t.Fatalf("list leads: %v", err)
 	}
@@ -126,7 +127,7 @@ func TestTierA_CI02_InboundSMS_LeadAndAI(t *testing.T) {
 		WillReturnRows(pgxmock.NewRows([]string{"exists"}))
 	mock.ExpectBegin()
 	mock.ExpectQuery("INSERT INTO messages").
-		WithArgs(clinicID, "+15550001111", "+15559998888", "inbound", "Need info", pgxmock.AnyArg(), "received", "msg_inbound", pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg()).
+		WithArgs(clinicID, "+15550001111", "+15559998888", "inbound", "Need info", pgxmock.AnyArg(), "received", "msg_inbound", pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), "patient", pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg()).
 		WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(uuid.New()))
 	mock.ExpectExec("INSERT INTO outbox").
 		WithArgs(pgxmock.AnyArg(), "clinic:"+clinicID.String(), "messaging.message.received.v1", pgxmock.AnyArg()).
@@ -161,7 +162,7 @@ func TestTierA_CI02_InboundSMS_LeadAndAI(t *testing.T) {
 	if telnyxStub.sendCalls != 1 || telnyxStub.lastSendReq == nil || !messaging.IsSmsAckMessage(telnyxStub.lastSendReq.Body) {
 		t.Fatalf("expected 1 SMS ack, got calls=%d last=%#v", telnyxStub.sendCalls, telnyxStub.lastSendReq)
 	}
-	list, err := leadsRepo.ListByOrg(context.Background(), clinicID.String(), leads.ListLeadsFilter{})
+	list, err := leadsRepo.ListByOrg(context.Background(), tenancy.ForOrg(clinicID.String()), leads.ListLeadsFilter{})
 	if err != nil {
 		t.Fatalf("list leads: %v", err)
 	}
@@ -203,7 +204,7 @@ func TestTierA_CI11_TelcoInboundIdempotency_ByMessageID(t *testing.T) {
 		WillReturnRows(pgxmock.NewRows([]string{"exists"}))
 	mock.ExpectBegin()
 	mock.ExpectQuery("INSERT INTO messages").
-		WithArgs(clinicID, "+15550001111", "+15559998888", "inbound", "Need info", pgxmock.AnyArg(), "received", "msg-dup", pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg()).
+		WithArgs(clinicID, "+15550001111", "+15559998888", "inbound", "Need info", pgxmock.AnyArg(), "received", "msg-dup", pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), "patient", pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg()).
 		WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(uuid.New()))
 	mock.ExpectExec("INSERT INTO outbox").
 		WithArgs(pgxmock.AnyArg(), "clinic:"+clinicID.String(), "messaging.message.received.v1", pgxmock.AnyArg()).
@@ -281,7 +282,7 @@ func TestTierA_CI14_PCIGuardrail(t *testing.T) {
 		WillReturnRows(pgxmock.NewRows([]string{"exists"}))
 	mock.ExpectBegin()
 	mock.ExpectQuery("INSERT INTO messages").
-		WithArgs(clinicID, "+15550001111", "+15559998888", "inbound", "My card is [REDACTED_CARD_1111]", pgxmock.AnyArg(), "received", "msg-pci", pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg()).
+		WithArgs(clinicID, "+15550001111", "+15559998888", "inbound", "My card is [CARD ending 1111]", pgxmock.AnyArg(), "received", "msg-pci", pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), "patient", pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg()).
 		WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(uuid.New()))
 	mock.ExpectExec("INSERT INTO outbox").
 		WithArgs(pgxmock.AnyArg(), "clinic:"+clinicID.String(), "messaging.message.received.v1", pgxmock.AnyArg()).
@@ -349,7 +350,7 @@ func TestTierA_CI15_CI16_StopStartCompliance(t *testing.T) {
 		WillReturnRows(pgxmock.NewRows([]string{"exists"}))
 	mock.ExpectBegin()
 	mock.ExpectQuery("INSERT INTO messages").
-		WithArgs(clinicID, "+15550001111", "+15559998888", "inbound", "STOP", pgxmock.AnyArg(), "received", "msg-stop", pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg()).
+		WithArgs(clinicID, "+15550001111", "+15559998888", "inbound", "STOP", pgxmock.AnyArg(), "received", "msg-stop", pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), "patient", pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg()).
 		WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(uuid.New()))
 	mock.ExpectExec("INSERT INTO outbox").
 		WithArgs(pgxmock.AnyArg(), "clinic:"+clinicID.String(), "messaging.message.received.v1", pgxmock.AnyArg()).
@@ -387,7 +388,7 @@ func TestTierA_CI15_CI16_StopStartCompliance(t *testing.T) {
 		WillReturnRows(pgxmock.NewRows([]string{"exists"}))
 	mock.ExpectBegin()
 	mock.ExpectQuery("INSERT INTO messages").
-		WithArgs(clinicID, "+15550001111", "+15559998888", "inbound", "hello?", pgxmock.AnyArg(), "received", "msg-ignored", pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg()).
+		WithArgs(clinicID, "+15550001111", "+15559998888", "inbound", "hello?", pgxmock.AnyArg(), "received", "msg-ignored", pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), "patient", pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg()).
 		WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(uuid.New()))
 	mock.ExpectExec("INSERT INTO outbox").
 		WithArgs(pgxmock.AnyArg(), "clinic:"+clinicID.String(), "messaging.message.received.v1", pgxmock.AnyArg()).
@@ -426,7 +427,7 @@ func TestTierA_CI15_CI16_StopStartCompliance(t *testing.T) {
 		WillReturnRows(pgxmock.NewRows([]string{"exists"}))
 	mock.ExpectBegin()
 	mock.ExpectQuery("INSERT INTO messages").
-		WithArgs(clinicID, "+15550001111", "+15559998888", "inbound", "START", pgxmock.AnyArg(), "received", "msg-start", pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg()).
+		WithArgs(clinicID, "+15550001111", "+15559998888", "inbound", "START", pgxmock.AnyArg(), "received", "msg-start", pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), "patient", pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg()).
 		WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(uuid.New()))
 	mock.ExpectExec("INSERT INTO outbox").
 		WithArgs(pgxmock.AnyArg(), "clinic:"+clinicID.String(), "messaging.message.received.v1", pgxmock.AnyArg()).
@@ -465,7 +466,7 @@ func TestTierA_CI15_CI16_StopStartCompliance(t *testing.T) {
 		WillReturnRows(pgxmock.NewRows([]string{"exists"}))
 	mock.ExpectBegin()
 	mock.ExpectQuery("INSERT INTO messages").
-		WithArgs(clinicID, "+15550001111", "+15559998888", "inbound", "book botox", pgxmock.AnyArg(), "received", "msg-after-start", pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg()).
+		WithArgs(clinicID, "+15550001111", "+15559998888", "inbound", "book botox", pgxmock.AnyArg(), "received", "msg-after-start", pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), "patient", pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg()).
 		WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(uuid.New()))
 	mock.ExpectExec("INSERT INTO outbox").
 		WithArgs(pgxmock.AnyArg(), "clinic:"+clinicID.String(), "messaging.message.received.v1", pgxmock.AnyArg()).
@@ -574,7 +575,7 @@ func TestTierA_CI19_UnifiedLeadIdentity_VoiceThenSMS(t *testing.T) {
 		t.Fatalf("expected 200, got %d", recVoice.Code)
 	}
 
-	list, err := leadsRepo.ListByOrg(context.Background(), clinicID.String(), leads.ListLeadsFilter{})
+	list, err := leadsRepo.ListByOrg(context.Background(), tenancy.ForOrg(clinicID.String()), leads.ListLeadsFilter{})
 	if err != nil {
 		t.Fatalf("list leads: %v", err)
 	}
@@ -592,7 +593,7 @@ func TestTierA_CI19_UnifiedLeadIdentity_VoiceThenSMS(t *testing.T) {
 		WillReturnRows(pgxmock.NewRows([]string{"exists"}))
 	mock.ExpectBegin()
 	mock.ExpectQuery("INSERT INTO messages").
-		WithArgs(clinicID, "+15550003333", "+15559998888", "inbound", "Need info", pgxmock.AnyArg(), "received", "msg-unified", pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg()).
+		WithArgs(clinicID, "+15550003333", "+15559998888", "inbound", "Need info", pgxmock.AnyArg(), "received", "msg-unified", pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), "patient", pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg()).
 		WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(uuid.New()))
 	mock.ExpectExec("INSERT INTO outbox").
 		WithArgs(pgxmock.AnyArg(), "clinic:"+clinicID.String(), "messaging.message.received.v1", pgxmock.AnyArg()).
@@ -616,7 +617,7 @@ func TestTierA_CI19_UnifiedLeadIdentity_VoiceThenSMS(t *testing.T) {
 		t.Fatalf("expected 200, got %d", recSMS.Code)
 	}
 
-	list2, err := leadsRepo.ListByOrg(context.Background(), clinicID.String(), leads.ListLeadsFilter{})
+	list2, err := leadsRepo.ListByOrg(context.Background(), tenancy.ForOrg(clinicID.String()), leads.ListLeadsFilter{})
 	if err != nil {
 		t.Fatalf("list leads: %v", err)
 	}
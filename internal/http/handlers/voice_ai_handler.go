@@ -16,6 +16,7 @@ import (
 	"github.com/wolfman30/medspa-ai-platform/internal/conversation"
 	"github.com/wolfman30/medspa-ai-platform/internal/messaging"
 	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
+	"github.com/wolfman30/medspa-ai-platform/pkg/phone"
 )
 
 // ----- Telnyx Voice AI webhook event types -----
@@ -366,7 +367,7 @@ func (h *VoiceAIHandler) HandleVoiceAI(w http.ResponseWriter, r *http.Request) {
 			)
 			// Fire-and-forget: enqueue a message that triggers availability fetch via SMS path.
 			// We create a synthetic SMS conversation so the worker handles it normally.
-			smsConvID := fmt.Sprintf("sms:%s:%s", asyncReq.OrgID, strings.TrimPrefix(from, "+"))
+			smsConvID := fmt.Sprintf("sms:%s:%s", asyncReq.OrgID, phone.Digits(from))
 			asyncJobID := fmt.Sprintf("voice-avail-%s-%d", convID, time.Now().UnixMilli())
 			asyncMsg := conversation.MessageRequest{
 				OrgID:          asyncReq.OrgID,
@@ -23,6 +23,10 @@ var _ onboardingDB = (*pgxpool.Pool)(nil)
 var _ adminClinicDataDB = (*pgxpool.Pool)(nil)
 
 var _ telnyxClient = (*telnyxclient.Client)(nil)
+var _ numberProvisioner = (*telnyxclient.Client)(nil)
+var _ numberProvisioningStore = (*messaging.NumberProvisioningStore)(nil)
+var _ numberProvisioningLookup = (*messaging.NumberProvisioningStore)(nil)
+var _ numberStatusUpdater = (*messaging.NumberProvisioningStore)(nil)
 var _ MissedCallTexter = (*TelnyxWebhookHandler)(nil)
 var _ GitHubNotifier = (*TelegramNotifier)(nil)
 var _ S3Uploader = (*s3.Client)(nil)
@@ -3,20 +3,24 @@ package middleware
 import (
 	"context"
 	"net/http"
+	"slices"
 	"strings"
 
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/wolfman30/medspa-ai-platform/pkg/adminauth"
 )
 
 type contextKey string
 
 const adminClaimsKey contextKey = "adminClaims"
 
-// AdminJWT enforces a simple HMAC-signed JWT for admin endpoints.
-func AdminJWT(secret string) func(http.Handler) http.Handler {
+// AdminJWT enforces an HMAC-signed admin JWT checked by verifier. Full admin
+// tokens are accepted everywhere; a scoped token only where scopes lists one
+// of its scopes.
+func AdminJWT(verifier *adminauth.Verifier, scopes ...string) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			if secret == "" {
+			if !verifier.Enabled() {
 				http.Error(w, "admin auth disabled", http.StatusUnauthorized)
 				return
 			}
@@ -25,18 +29,15 @@ func AdminJWT(secret string) func(http.Handler) http.Handler {
 				http.Error(w, "missing authorization header", http.StatusUnauthorized)
 				return
 			}
-			tokenString := strings.TrimPrefix(auth, "Bearer ")
-			claims := jwt.RegisteredClaims{}
-			token, err := jwt.ParseWithClaims(tokenString, &claims, func(token *jwt.Token) (any, error) {
-				if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-					return nil, jwt.ErrSignatureInvalid
-				}
-				return []byte(secret), nil
-			})
-			if err != nil || !token.Valid {
+			claims, err := verifier.Verify(strings.TrimPrefix(auth, "Bearer "))
+			if err != nil {
 				http.Error(w, "invalid token", http.StatusUnauthorized)
 				return
 			}
+			if claims.Scoped() && !slices.ContainsFunc(scopes, claims.HasScope) {
+				http.Error(w, "token scope not allowed", http.StatusForbidden)
+				return
+			}
 			ctx := context.WithValue(r.Context(), adminClaimsKey, claims)
 			next.ServeHTTP(w, r.WithContext(ctx))
 		})
@@ -45,6 +46,6 @@ func AdminJWT(secret string) func(http.Handler) http.Handler {
 
 // AdminClaimsFromContext returns admin JWT claims if present.
 func AdminClaimsFromContext(ctx context.Context) (jwt.RegisteredClaims, bool) {
-	claims, ok := ctx.Value(adminClaimsKey).(jwt.RegisteredClaims)
-	return claims, ok
+	claims, ok := ctx.Value(adminClaimsKey).(adminauth.Claims)
+	return claims.RegisteredClaims, ok
 }
@@ -7,10 +7,11 @@ import (
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/wolfman30/medspa-ai-platform/pkg/adminauth"
 )
 
 func TestAdminJWTMissingSecret(t *testing.T) {
-	mw := AdminJWT("")
+	mw := AdminJWT(testVerifier(""))
 	req := httptest.NewRequest(http.MethodGet, "/admin", nil)
 	rec := httptest.NewRecorder()
 
@@ -22,7 +23,7 @@ func TestAdminJWTMissingSecret(t *testing.T) {
 }
 
 func TestAdminJWTMissingHeader(t *testing.T) {
-	mw := AdminJWT("secret")
+	mw := AdminJWT(testVerifier("secret"))
 	req := httptest.NewRequest(http.MethodGet, "/admin", nil)
 	rec := httptest.NewRecorder()
 
@@ -34,7 +35,7 @@ func TestAdminJWTMissingHeader(t *testing.T) {
 }
 
 func TestAdminJWTInvalidToken(t *testing.T) {
-	mw := AdminJWT("secret")
+	mw := AdminJWT(testVerifier("secret"))
 	req := httptest.NewRequest(http.MethodGet, "/admin", nil)
 	req.Header.Set("Authorization", "Bearer "+signedAdminToken(t, "wrong"))
 	rec := httptest.NewRecorder()
@@ -47,7 +48,7 @@ func TestAdminJWTInvalidToken(t *testing.T) {
 }
 
 func TestAdminJWTValidToken(t *testing.T) {
-	mw := AdminJWT("secret")
+	mw := AdminJWT(testVerifier("secret"))
 	req := httptest.NewRequest(http.MethodGet, "/admin", nil)
 	req.Header.Set("Authorization", "Bearer "+signedAdminToken(t, "secret"))
 	rec := httptest.NewRecorder()
@@ -55,8 +56,8 @@ func TestAdminJWTValidToken(t *testing.T) {
 	called := false
 	mw(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		called = true
-		if _, ok := r.Context().Value(adminClaimsKey).(jwt.RegisteredClaims); !ok {
-			t.Fatalf("expected admin claims in context")
+		if claims, ok := AdminClaimsFromContext(r.Context()); !ok || claims.Subject != "admin-user" {
+			t.Fatalf("expected admin claims in context, got %+v", claims)
 		}
 		w.WriteHeader(http.StatusOK)
 	})).ServeHTTP(rec, req)
@@ -69,6 +70,54 @@ func TestAdminJWTValidToken(t *testing.T) {
 	}
 }
 
+func TestAdminJWTAcceptsMintedToken(t *testing.T) {
+	verifier := adminauth.NewVerifier(adminauth.ParseKeys("k1:secret"), adminauth.DefaultIssuer, adminauth.DefaultAudience)
+	token, err := adminauth.Mint("k1:secret", adminauth.MintOptions{})
+	if err != nil {
+		t.Fatalf("mint: %v", err)
+	}
+
+	if code := serveAdmin(AdminJWT(verifier), token); code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, code)
+	}
+}
+
+func TestAdminJWTScopedToken(t *testing.T) {
+	verifier := testVerifier("secret")
+	token, err := adminauth.Mint("secret", adminauth.MintOptions{Scope: adminauth.ScopePurge, TTL: 5 * time.Minute})
+	if err != nil {
+		t.Fatalf("mint: %v", err)
+	}
+	full, err := adminauth.Mint("secret", adminauth.MintOptions{})
+	if err != nil {
+		t.Fatalf("mint: %v", err)
+	}
+
+	if code := serveAdmin(AdminJWT(verifier, adminauth.ScopePurge), token); code != http.StatusOK {
+		t.Fatalf("purge route with purge token: expected %d, got %d", http.StatusOK, code)
+	}
+	if code := serveAdmin(AdminJWT(verifier), token); code != http.StatusForbidden {
+		t.Fatalf("other route with purge token: expected %d, got %d", http.StatusForbidden, code)
+	}
+	if code := serveAdmin(AdminJWT(verifier, adminauth.ScopePurge), full); code != http.StatusOK {
+		t.Fatalf("purge route with full token: expected %d, got %d", http.StatusOK, code)
+	}
+}
+
+func serveAdmin(mw func(http.Handler) http.Handler, token string) int {
+	req := httptest.NewRequest(http.MethodGet, "/admin", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	rec := httptest.NewRecorder()
+	mw(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})).ServeHTTP(rec, req)
+	return rec.Code
+}
+
+func testVerifier(secret string) *adminauth.Verifier {
+	return adminauth.NewVerifier(adminauth.ParseKeys(secret), "", "")
+}
+
 func signedAdminToken(t *testing.T, secret string) string {
 	t.Helper()
 	claims := jwt.RegisteredClaims{
@@ -13,6 +13,7 @@ import (
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/wolfman30/medspa-ai-platform/pkg/adminauth"
 )
 
 // CognitoConfig holds AWS Cognito configuration for JWT validation.
@@ -255,10 +256,11 @@ func parseRSAPublicKey(nStr, eStr string) (*rsa.PublicKey, error) {
 }
 
 // CognitoOrAdminJWT allows either Cognito JWT or legacy admin JWT.
-// This enables gradual migration from the old auth system.
-func CognitoOrAdminJWT(cognitoCfg CognitoConfig, adminSecret string) func(http.Handler) http.Handler {
+// This enables gradual migration from the old auth system. Scoped admin
+// tokens are accepted only for the listed scopes (see AdminJWT).
+func CognitoOrAdminJWT(cognitoCfg CognitoConfig, admin *adminauth.Verifier, scopes ...string) func(http.Handler) http.Handler {
 	cognitoMW := CognitoJWT(cognitoCfg)
-	adminMW := AdminJWT(adminSecret)
+	adminMW := AdminJWT(admin, scopes...)
 
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -27,7 +27,7 @@ func TestCognitoJWTNotConfigured(t *testing.T) {
 
 func TestCognitoOrAdminJWTFallsBackToAdmin(t *testing.T) {
 	token := signedAdminToken(t, "secret")
-	mw := CognitoOrAdminJWT(CognitoConfig{}, "secret")
+	mw := CognitoOrAdminJWT(CognitoConfig{}, testVerifier("secret"))
 
 	req := httptest.NewRequest(http.MethodGet, "/admin", nil)
 	req.Header.Set("Authorization", "Bearer "+token)
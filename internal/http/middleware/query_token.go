@@ -0,0 +1,32 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+)
+
+// BearerFromQuery lets clients that can't set headers, like a browser
+// EventSource, pass their bearer token as a query parameter. The token is
+// copied into the Authorization header for the auth middleware that follows
+// and dropped from the URL handed on. An Authorization header already on the
+// request wins. Only mount this on routes that need it: query strings end up
+// in access logs.
+func BearerFromQuery(param string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			query := r.URL.Query()
+			token := strings.TrimSpace(query.Get(param))
+			if token == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+			r = r.Clone(r.Context())
+			if r.Header.Get("Authorization") == "" {
+				r.Header.Set("Authorization", "Bearer "+token)
+			}
+			query.Del(param)
+			r.URL.RawQuery = query.Encode()
+			next.ServeHTTP(w, r)
+		})
+	}
+}
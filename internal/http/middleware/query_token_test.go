@@ -0,0 +1,32 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestBearerFromQuery(t *testing.T) {
+	var gotAuth, gotQuery string
+	h := BearerFromQuery("access_token")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAuth = r.Header.Get("Authorization")
+		gotQuery = r.URL.RawQuery
+	}))
+
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/stream?access_token=abc&last_event_id=1-0", nil))
+	if gotAuth != "Bearer abc" || gotQuery != "last_event_id=1-0" {
+		t.Fatalf("query token: auth=%q query=%q", gotAuth, gotQuery)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/stream?access_token=abc", nil)
+	req.Header.Set("Authorization", "Bearer header")
+	h.ServeHTTP(httptest.NewRecorder(), req)
+	if gotAuth != "Bearer header" {
+		t.Fatalf("header should win, got %q", gotAuth)
+	}
+
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/stream", nil))
+	if gotAuth != "" {
+		t.Fatalf("expected no Authorization without a token, got %q", gotAuth)
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"net/http"
 	"sync"
 	"time"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/http/apierror"
 )
 
 // RateLimiter provides per-IP rate limiting using a token bucket algorithm.
@@ -85,7 +87,7 @@ func RateLimit(rate float64, burst int) func(http.Handler) http.Handler {
 				ip = xri
 			}
 			if !limiter.Allow(ip) {
-				http.Error(w, "rate limit exceeded", http.StatusTooManyRequests)
+				apierror.Write(w, r, apierror.CodeRateLimited, "rate limit exceeded")
 				return
 			}
 			next.ServeHTTP(w, r)
@@ -8,7 +8,8 @@ import (
 	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
 )
 
-// RequestLogger emits structured logs for every HTTP request.
+// RequestLogger emits structured logs for every HTTP request and puts the
+// request ID on the context so downstream logs and queued jobs carry it.
 func RequestLogger(logger *logging.Logger) func(http.Handler) http.Handler {
 	if logger == nil {
 		logger = logging.Default()
@@ -26,7 +27,8 @@ func RequestLogger(logger *logging.Logger) func(http.Handler) http.Handler {
 				"request_id", reqID,
 				"remote_ip", r.RemoteAddr,
 			)
-			next.ServeHTTP(w, r)
+			ctx := logging.WithFields(r.Context(), logging.Fields{RequestID: reqID})
+			next.ServeHTTP(w, r.WithContext(ctx))
 			logger.Info("request completed",
 				"method", r.Method,
 				"path", r.URL.Path,
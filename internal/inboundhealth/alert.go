@@ -0,0 +1,66 @@
+package inboundhealth
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/clinic"
+	"github.com/wolfman30/medspa-ai-platform/internal/notify"
+)
+
+// Alert describes a clinic that stopped receiving inbound messages.
+type Alert struct {
+	OrgID         string
+	ClinicName    string
+	LastInboundAt time.Time
+	Assessment    Assessment
+}
+
+// Alerter delivers inbound silence alerts.
+type Alerter interface {
+	InboundSilent(ctx context.Context, alert Alert) error
+}
+
+// WebhookAlerter posts inbound silence alerts to engineering's chat webhook.
+// Clinics aren't told: a broken provider webhook is ours to fix.
+type WebhookAlerter struct {
+	notifier    *notify.WebhookNotifier
+	engineering []clinic.ChatWebhook
+}
+
+// NewWebhookAlerter creates an alerter posting to engineeringURL. Returns
+// nil when there is nowhere to post.
+func NewWebhookAlerter(notifier *notify.WebhookNotifier, engineeringURL string) *WebhookAlerter {
+	u := strings.TrimSpace(engineeringURL)
+	if notifier == nil || u == "" {
+		return nil
+	}
+	return &WebhookAlerter{notifier: notifier, engineering: []clinic.ChatWebhook{{Name: "engineering", URL: u}}}
+}
+
+// InboundSilent implements Alerter.
+func (a *WebhookAlerter) InboundSilent(ctx context.Context, alert Alert) error {
+	err := a.notifier.Publish(ctx, a.engineering, notify.WebhookEvent{
+		Type:       notify.EventInboundSilent,
+		ClinicName: alert.ClinicName,
+		Details: []string{
+			lastInboundDetail(alert.LastInboundAt),
+			fmt.Sprintf("Quiet for %.1f business hours (threshold: %.0f)", roundHours(alert.Assessment.QuietFor), roundHours(alert.Assessment.Threshold)),
+			"Check the number's messaging profile and webhook URL with the SMS provider.",
+			"Org: " + alert.OrgID,
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("inboundhealth: send alert: %w", err)
+	}
+	return nil
+}
+
+func lastInboundDetail(at time.Time) string {
+	if at.IsZero() {
+		return "No inbound message received yet"
+	}
+	return fmt.Sprintf("Last inbound message: %s", at.UTC().Format(time.RFC3339))
+}
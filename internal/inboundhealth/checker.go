@@ -0,0 +1,191 @@
+package inboundhealth
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/clinic"
+	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
+)
+
+// defaultCheckInterval is how often clinics are checked. Thresholds are in
+// hours, so a quiet clinic is caught within minutes of crossing one.
+const defaultCheckInterval = 15 * time.Minute
+
+// ClinicSource lists and loads clinic configs.
+type ClinicSource interface {
+	OrgIDs(ctx context.Context) ([]string, error)
+	Get(ctx context.Context, orgID string) (*clinic.Config, error)
+}
+
+// CheckerConfig configures the inbound liveness checker.
+type CheckerConfig struct {
+	Tracker  *Tracker
+	Clinics  ClinicSource
+	Alerter  Alerter // required; the checker doesn't run without one
+	Interval time.Duration
+	Logger   *logging.Logger
+}
+
+// Checker compares every clinic's last inbound message against its quiet
+// threshold, counted in business hours, and marks and alerts on the ones
+// that went silent.
+type Checker struct {
+	tracker  *Tracker
+	clinics  ClinicSource
+	alerter  Alerter
+	interval time.Duration
+	logger   *logging.Logger
+	now      func() time.Time
+}
+
+// RunResult summarizes one pass of the checker.
+type RunResult struct {
+	Orgs     int
+	Degraded int // newly marked degraded this pass
+	Alerts   int
+}
+
+// NewChecker creates an inbound liveness checker.
+func NewChecker(cfg CheckerConfig) *Checker {
+	if cfg.Logger == nil {
+		cfg.Logger = logging.Default()
+	}
+	if cfg.Interval <= 0 {
+		cfg.Interval = defaultCheckInterval
+	}
+	return &Checker{
+		tracker:  cfg.Tracker,
+		clinics:  cfg.Clinics,
+		alerter:  cfg.Alerter,
+		interval: cfg.Interval,
+		logger:   cfg.Logger,
+		now:      time.Now,
+	}
+}
+
+// Start runs the checker on its interval. Blocks until ctx is cancelled.
+// It refuses to run without an alerter: a silence nobody hears about is no
+// better than not checking.
+func (c *Checker) Start(ctx context.Context) {
+	if c == nil || c.tracker == nil || c.clinics == nil {
+		return
+	}
+	if c.alerter == nil {
+		c.logger.Error("inbound health checker not started: no alert target configured")
+		return
+	}
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+
+	c.run(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			c.run(ctx)
+		}
+	}
+}
+
+func (c *Checker) run(ctx context.Context) {
+	res, err := c.RunOnce(ctx)
+	if err != nil {
+		c.logger.Error("inbound health run failed", "error", err)
+		return
+	}
+	if res.Degraded > 0 {
+		c.logger.Warn("inbound health run completed", "orgs", res.Orgs, "degraded", res.Degraded, "alerts", res.Alerts)
+	}
+}
+
+// RunOnce checks every clinic with a stored config, plus any org that has
+// received an inbound message. A clinic that never has is measured from
+// when the checker first saw it, so a number that was never wired up is
+// caught too.
+func (c *Checker) RunOnce(ctx context.Context) (RunResult, error) {
+	var res RunResult
+	last, err := c.tracker.LastInbound(ctx)
+	if err != nil {
+		return res, err
+	}
+	configured, err := c.clinics.OrgIDs(ctx)
+	if err != nil {
+		return res, err
+	}
+	seen := make(map[string]bool, len(last)+len(configured))
+	orgIDs := make([]string, 0, len(last)+len(configured))
+	for _, orgID := range configured {
+		if !seen[orgID] {
+			seen[orgID] = true
+			orgIDs = append(orgIDs, orgID)
+		}
+	}
+	for orgID := range last {
+		if !seen[orgID] {
+			seen[orgID] = true
+			orgIDs = append(orgIDs, orgID)
+		}
+	}
+	sort.Strings(orgIDs)
+	now := c.now()
+	for _, orgID := range orgIDs {
+		if ctx.Err() != nil {
+			return res, ctx.Err()
+		}
+		cfg, err := c.clinics.Get(ctx, orgID)
+		if err != nil {
+			c.logger.Warn("inbound health: failed to load clinic config, skipping org", "error", err, "org_id", orgID)
+			continue
+		}
+		if cfg.InboundQuietThreshold() <= 0 {
+			continue
+		}
+		res.Orgs++
+		lastInbound, ok := last[orgID]
+		since := lastInbound
+		if !ok {
+			if since, err = c.tracker.WatchSince(ctx, orgID, now); err != nil {
+				c.logger.Warn("inbound health: failed to record watch start, skipping org", "error", err, "org_id", orgID)
+				continue
+			}
+		}
+		assessment := Assess(cfg, since, now)
+		if !assessment.Silent {
+			continue
+		}
+		c.degrade(ctx, cfg, lastInbound, assessment, &res)
+	}
+	return res, nil
+}
+
+// degrade marks a silent clinic degraded and alerts once per silence. A
+// failed alert unmarks the clinic so the next run tries again.
+func (c *Checker) degrade(ctx context.Context, cfg *clinic.Config, lastInbound time.Time, a Assessment, res *RunResult) {
+	marked, err := c.tracker.MarkDegraded(ctx, cfg.OrgID, Degradation{Since: c.now(), LastInboundAt: lastInbound, QuietFor: a.QuietFor})
+	if err != nil {
+		c.logger.Error("inbound health: failed to mark clinic degraded", "error", err, "org_id", cfg.OrgID)
+		return
+	}
+	if !marked {
+		return
+	}
+	res.Degraded++
+	c.logger.Warn("inbound health: no inbound messages for an active clinic",
+		"org_id", cfg.OrgID,
+		"last_inbound_at", lastInbound,
+		"quiet_business_hours", roundHours(a.QuietFor),
+		"threshold_hours", roundHours(a.Threshold),
+	)
+	alert := Alert{OrgID: cfg.OrgID, ClinicName: cfg.Name, LastInboundAt: lastInbound, Assessment: a}
+	if err := c.alerter.InboundSilent(ctx, alert); err != nil {
+		c.logger.Error("inbound health: alert failed", "error", err, "org_id", cfg.OrgID)
+		if err := c.tracker.ClearDegraded(ctx, cfg.OrgID); err != nil {
+			c.logger.Warn("inbound health: failed to unmark clinic after alert failure", "error", err, "org_id", cfg.OrgID)
+		}
+		return
+	}
+	res.Alerts++
+}
@@ -0,0 +1,171 @@
+package inboundhealth
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/clinic"
+	"github.com/wolfman30/medspa-ai-platform/internal/notify"
+	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
+)
+
+type memClinics map[string]*clinic.Config
+
+func (m memClinics) OrgIDs(ctx context.Context) ([]string, error) {
+	ids := make([]string, 0, len(m))
+	for id := range m {
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+func (m memClinics) Get(ctx context.Context, orgID string) (*clinic.Config, error) {
+	if cfg, ok := m[orgID]; ok {
+		return cfg, nil
+	}
+	return nil, errors.New("not found")
+}
+
+type recordingAlerter struct {
+	alerts []Alert
+	err    error
+}
+
+func (a *recordingAlerter) InboundSilent(ctx context.Context, alert Alert) error {
+	a.alerts = append(a.alerts, alert)
+	return a.err
+}
+
+func newTestTracker(t *testing.T) *Tracker {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	return NewTracker(redis.NewClient(&redis.Options{Addr: mr.Addr()}), logging.Default())
+}
+
+func TestCheckerDegradesSilentClinicOnce(t *testing.T) {
+	ctx := context.Background()
+	tracker := newTestTracker(t)
+	ny, _ := time.LoadLocation("America/New_York")
+	lastQuiet := time.Date(2026, 10, 14, 9, 0, 0, 0, ny)
+	if err := tracker.RecordInbound(ctx, "org-quiet", lastQuiet); err != nil {
+		t.Fatalf("RecordInbound: %v", err)
+	}
+	alerter := &recordingAlerter{}
+	checker := NewChecker(CheckerConfig{
+		Tracker: tracker,
+		Clinics: memClinics{"org-quiet": weekdayClinic("org-quiet"), "org-busy": weekdayClinic("org-busy")},
+		Alerter: alerter,
+	})
+	now := time.Date(2026, 10, 15, 13, 0, 0, 0, ny)
+	checker.now = func() time.Time { return now }
+	if err := tracker.RecordInbound(ctx, "org-busy", now.Add(-time.Hour)); err != nil {
+		t.Fatalf("RecordInbound: %v", err)
+	}
+
+	res, err := checker.RunOnce(ctx)
+	if err != nil {
+		t.Fatalf("RunOnce: %v", err)
+	}
+	if res.Orgs != 2 || res.Degraded != 1 || res.Alerts != 1 || len(alerter.alerts) != 1 {
+		t.Fatalf("expected one degraded clinic and one alert, got %+v", res)
+	}
+	if got := alerter.alerts[0]; got.OrgID != "org-quiet" || got.ClinicName != "Glow Spa" || got.Assessment.QuietFor != 12*time.Hour {
+		t.Fatalf("unexpected alert %+v", got)
+	}
+	st, err := tracker.Status(ctx, weekdayClinic("org-quiet"), now)
+	if err != nil || st.Status != StatusDegraded || st.DegradedSince == nil || st.QuietBusinessHours != 12 {
+		t.Fatalf("expected the clinic degraded, got %+v (err %v)", st, err)
+	}
+
+	// The silence is only alerted once, however many runs see it.
+	if res, _ := checker.RunOnce(ctx); res.Alerts != 0 || len(alerter.alerts) != 1 {
+		t.Fatalf("expected no repeat alert, got %+v", res)
+	}
+
+	// A message arriving clears the degraded state.
+	if err := tracker.RecordInbound(ctx, "org-quiet", now); err != nil {
+		t.Fatalf("RecordInbound: %v", err)
+	}
+	st, err = tracker.Status(ctx, weekdayClinic("org-quiet"), now)
+	if err != nil || st.Status != StatusOK || st.DegradedSince != nil || st.QuietBusinessHours != 0 {
+		t.Fatalf("expected the clinic healthy after an inbound message, got %+v (err %v)", st, err)
+	}
+	if res, _ := checker.RunOnce(ctx); res.Degraded != 0 {
+		t.Fatalf("expected no degraded clinics, got %+v", res)
+	}
+}
+
+func TestCheckerRetriesFailedAlert(t *testing.T) {
+	ctx := context.Background()
+	tracker := newTestTracker(t)
+	last := time.Date(2026, 10, 12, 9, 0, 0, 0, time.UTC)
+	tracker.RecordInbound(ctx, "org-1", last)
+	alerter := &recordingAlerter{err: errors.New("webhook down")}
+	cfg := weekdayClinic("org-1")
+	checker := NewChecker(CheckerConfig{Tracker: tracker, Clinics: memClinics{"org-1": cfg}, Alerter: alerter})
+	checker.now = func() time.Time { return last.Add(72 * time.Hour) }
+
+	if res, _ := checker.RunOnce(ctx); res.Alerts != 0 {
+		t.Fatalf("a failed alert must not count, got %+v", res)
+	}
+	if d, _ := tracker.Degraded(ctx, "org-1"); d != nil {
+		t.Fatal("a failed alert should leave the clinic unmarked so the next run retries")
+	}
+	alerter.err = nil
+	if res, _ := checker.RunOnce(ctx); res.Alerts != 1 || len(alerter.alerts) != 2 {
+		t.Fatalf("expected the alert retried, got %+v", res)
+	}
+}
+
+func TestCheckerWatchesClinicWithoutInbound(t *testing.T) {
+	ctx := context.Background()
+	tracker := newTestTracker(t)
+	alerter := &recordingAlerter{}
+	checker := NewChecker(CheckerConfig{Tracker: tracker, Clinics: memClinics{"org-new": weekdayClinic("org-new")}, Alerter: alerter})
+	start := time.Date(2026, 10, 12, 9, 0, 0, 0, time.UTC)
+	checker.now = func() time.Time { return start }
+
+	if res, err := checker.RunOnce(ctx); err != nil || res.Orgs != 1 || res.Degraded != 0 {
+		t.Fatalf("expected the new clinic watched but not degraded, got %+v (err %v)", res, err)
+	}
+	checker.now = func() time.Time { return start.Add(72 * time.Hour) }
+	res, err := checker.RunOnce(ctx)
+	if err != nil || res.Alerts != 1 || len(alerter.alerts) != 1 {
+		t.Fatalf("expected a clinic that never received a message to alert, got %+v (err %v)", res, err)
+	}
+	if got := alerter.alerts[0]; got.OrgID != "org-new" || !got.LastInboundAt.IsZero() {
+		t.Fatalf("unexpected alert %+v", got)
+	}
+}
+
+func TestCheckerRequiresAlerter(t *testing.T) {
+	if NewWebhookAlerter(notify.NewWebhookNotifier(nil, logging.Default()), " ") != nil {
+		t.Fatal("an alerter without a webhook URL would drop every alert")
+	}
+	tracker := newTestTracker(t)
+	tracker.RecordInbound(context.Background(), "org-1", time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC))
+	checker := NewChecker(CheckerConfig{Tracker: tracker, Clinics: memClinics{"org-1": weekdayClinic("org-1")}})
+	checker.Start(context.Background()) // returns at once instead of running
+	if d, _ := tracker.Degraded(context.Background(), "org-1"); d != nil {
+		t.Fatal("a checker without an alerter should not run")
+	}
+}
+
+func TestStatusUnknownAndOff(t *testing.T) {
+	ctx := context.Background()
+	tracker := newTestTracker(t)
+	cfg := weekdayClinic("org-new")
+	st, err := tracker.Status(ctx, cfg, time.Now())
+	if err != nil || st.Status != StatusUnknown || st.LastInboundAt != nil || st.ThresholdHours != 12 {
+		t.Fatalf("a clinic without inbound history should be unknown, got %+v (err %v)", st, err)
+	}
+	cfg.InboundHealth = &clinic.InboundHealthConfig{Disabled: true}
+	if st, _ := tracker.Status(ctx, cfg, time.Now()); st.Status != StatusOff {
+		t.Fatalf("a disabled check should report off, got %+v", st)
+	}
+}
@@ -0,0 +1,96 @@
+// Package inboundhealth is the dead man's switch for inbound messaging. The
+// SMS webhook handlers record when each clinic last received a text; a
+// periodic checker alerts engineering and marks the clinic degraded when an
+// active clinic goes quiet for too many business hours, which usually means
+// its provider webhook was misconfigured rather than that patients stopped
+// texting.
+//
+// Quiet time only counts while the clinic is open, so nights and weekends
+// never page on their own.
+package inboundhealth
+
+import (
+	"context"
+	"time"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/clinic"
+)
+
+// Inbound statuses reported by Status.
+const (
+	StatusOK       = "ok"
+	StatusDegraded = "degraded"
+	// StatusUnknown means no inbound message has been recorded for the
+	// clinic yet, so there is nothing to compare against.
+	StatusUnknown = "unknown"
+	// StatusOff means the check doesn't run for the clinic: it is inactive
+	// or disabled the check.
+	StatusOff = "off"
+)
+
+// Assessment is a clinic's inbound silence measured against its threshold.
+type Assessment struct {
+	// QuietFor is the open business time since the last inbound message.
+	QuietFor time.Duration
+	// Threshold is the clinic's quiet threshold; zero means the check is
+	// off.
+	Threshold time.Duration
+	// Silent is set once QuietFor reaches Threshold.
+	Silent bool
+}
+
+// Assess measures the business hours cfg has been open since lastInbound.
+func Assess(cfg *clinic.Config, lastInbound, now time.Time) Assessment {
+	a := Assessment{Threshold: cfg.InboundQuietThreshold()}
+	if a.Threshold <= 0 {
+		return a
+	}
+	a.QuietFor = cfg.OpenDuration(lastInbound, now)
+	a.Silent = a.QuietFor >= a.Threshold
+	return a
+}
+
+// Status is a clinic's inbound liveness, as shown by the diagnostics
+// endpoint.
+type Status struct {
+	Status             string     `json:"status"`
+	LastInboundAt      *time.Time `json:"last_inbound_at,omitempty"`
+	QuietBusinessHours float64    `json:"quiet_business_hours"`
+	ThresholdHours     float64    `json:"threshold_hours"`
+	DegradedSince      *time.Time `json:"degraded_since,omitempty"`
+}
+
+// Status reports cfg's inbound liveness at now. A clinic is degraded once
+// the checker has marked it, until its next inbound message.
+func (t *Tracker) Status(ctx context.Context, cfg *clinic.Config, now time.Time) (Status, error) {
+	st := Status{Status: StatusUnknown}
+	last, ok, err := t.LastInboundFor(ctx, cfg.OrgID)
+	if err != nil {
+		return st, err
+	}
+	degraded, err := t.Degraded(ctx, cfg.OrgID)
+	if err != nil {
+		return st, err
+	}
+	a := Assessment{Threshold: cfg.InboundQuietThreshold()}
+	if ok {
+		a = Assess(cfg, last, now)
+		st.LastInboundAt = &last
+		st.Status = StatusOK
+	}
+	st.QuietBusinessHours = roundHours(a.QuietFor)
+	st.ThresholdHours = roundHours(a.Threshold)
+	switch {
+	case a.Threshold <= 0:
+		st.Status = StatusOff
+	case degraded != nil:
+		st.Status = StatusDegraded
+		st.DegradedSince = &degraded.Since
+	}
+	return st, nil
+}
+
+// roundHours returns d in hours, to one decimal place.
+func roundHours(d time.Duration) float64 {
+	return float64(d.Round(6*time.Minute)) / float64(time.Hour)
+}
@@ -0,0 +1,73 @@
+package inboundhealth
+
+import (
+	"testing"
+	"time"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/clinic"
+)
+
+// weekdayClinic is open 9-5 Monday to Friday in New York.
+func weekdayClinic(orgID string) *clinic.Config {
+	cfg := clinic.DefaultConfig(orgID)
+	cfg.Name = "Glow Spa"
+	cfg.Timezone = "America/New_York"
+	hours := &clinic.DayHours{Open: "09:00", Close: "17:00"}
+	cfg.BusinessHours = clinic.BusinessHours{Monday: hours, Tuesday: hours, Wednesday: hours, Thursday: hours, Friday: hours}
+	return cfg
+}
+
+func TestAssessCountsOnlyBusinessHours(t *testing.T) {
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatalf("load location: %v", err)
+	}
+	at := func(day, hour int) time.Time { return time.Date(2026, 10, day, hour, 0, 0, 0, ny) } // Oct 12, 2026 is a Monday
+	cfg := weekdayClinic("org-1")
+
+	tests := []struct {
+		name        string
+		last, now   time.Time
+		quiet       time.Duration
+		wantSilence bool
+	}{
+		{"same afternoon", at(14, 10), at(14, 15), 5 * time.Hour, false},
+		{"overnight lull", at(14, 16), at(15, 3), time.Hour, false},
+		{"weekend lull", at(16, 15), at(19, 10), 3 * time.Hour, false},
+		{"3am Saturday after a quiet Friday", at(16, 9), at(17, 3), 8 * time.Hour, false},
+		{"a day and a half of open hours", at(14, 9), at(15, 13), 12 * time.Hour, true},
+		{"quiet Friday and Monday", at(16, 9), at(19, 13), 12 * time.Hour, true},
+	}
+	for _, tt := range tests {
+		a := Assess(cfg, tt.last, tt.now)
+		if a.QuietFor != tt.quiet || a.Silent != tt.wantSilence || a.Threshold != 12*time.Hour {
+			t.Errorf("%s: got %+v, want quiet %s silent %v", tt.name, a, tt.quiet, tt.wantSilence)
+		}
+	}
+
+	cfg.InboundHealth = &clinic.InboundHealthConfig{QuietHours: 24}
+	if a := Assess(cfg, at(16, 9), at(19, 13)); a.Silent || a.Threshold != 24*time.Hour {
+		t.Errorf("a raised threshold should tolerate the quiet stretch, got %+v", a)
+	}
+	cfg.InboundHealth = &clinic.InboundHealthConfig{Disabled: true}
+	if a := Assess(cfg, at(1, 9), at(19, 13)); a.Silent || a.Threshold != 0 {
+		t.Errorf("a disabled check must never fire, got %+v", a)
+	}
+	cfg.InboundHealth = nil
+	cfg.Inactive = true
+	if a := Assess(cfg, at(1, 9), at(19, 13)); a.Silent {
+		t.Errorf("an inactive clinic must never fire, got %+v", a)
+	}
+}
+
+func TestAssessWithoutConfiguredHours(t *testing.T) {
+	cfg := clinic.DefaultConfig("org-2")
+	cfg.Timezone = "UTC"
+	cfg.BusinessHours = clinic.BusinessHours{}
+	last := time.Date(2026, 10, 17, 20, 0, 0, 0, time.UTC) // Saturday
+	// Clinics without hours count 7 AM - 9 PM every day, weekends included.
+	a := Assess(cfg, last, last.Add(24*time.Hour))
+	if a.QuietFor != 14*time.Hour || !a.Silent {
+		t.Fatalf("expected 14 quiet hours, got %+v", a)
+	}
+}
@@ -0,0 +1,152 @@
+package inboundhealth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
+)
+
+const (
+	// lastInboundKey hashes org ID to the unix millis of its latest inbound
+	// message.
+	lastInboundKey = "inbound_health:last"
+	// watchedKey hashes org ID to the unix millis the checker started
+	// watching an active clinic that had no inbound message yet.
+	watchedKey = "inbound_health:watched"
+	// degradedKey hashes org ID to the JSON Degradation the checker raised.
+	degradedKey = "inbound_health:degraded"
+)
+
+// Degradation records when a clinic was marked degraded for going quiet.
+type Degradation struct {
+	Since         time.Time     `json:"since"`
+	LastInboundAt time.Time     `json:"last_inbound_at"`
+	QuietFor      time.Duration `json:"quiet_for"` // open business time without inbound
+}
+
+// Tracker stores each clinic's last inbound message time and degraded state
+// in Redis. The inbound webhook handlers write it; the Checker reads it.
+type Tracker struct {
+	redis  *redis.Client
+	logger *logging.Logger
+}
+
+// NewTracker creates a tracker. Returns nil without a Redis client.
+func NewTracker(client *redis.Client, logger *logging.Logger) *Tracker {
+	if client == nil {
+		return nil
+	}
+	if logger == nil {
+		logger = logging.Default()
+	}
+	return &Tracker{redis: client, logger: logger}
+}
+
+// RecordInbound notes that orgID's number received a message at at, and
+// clears any degraded state: traffic is flowing again.
+func (t *Tracker) RecordInbound(ctx context.Context, orgID string, at time.Time) error {
+	if t == nil || orgID == "" {
+		return nil
+	}
+	pipe := t.redis.TxPipeline()
+	pipe.HSet(ctx, lastInboundKey, orgID, at.UnixMilli())
+	cleared := pipe.HDel(ctx, degradedKey, orgID)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("inboundhealth: record inbound: %w", err)
+	}
+	if cleared.Val() > 0 {
+		t.logger.Info("inbound traffic resumed; clinic no longer degraded", "org_id", orgID)
+	}
+	return nil
+}
+
+// LastInbound returns every tracked org's latest inbound message time.
+func (t *Tracker) LastInbound(ctx context.Context) (map[string]time.Time, error) {
+	raw, err := t.redis.HGetAll(ctx, lastInboundKey).Result()
+	if err != nil {
+		return nil, fmt.Errorf("inboundhealth: load last inbound: %w", err)
+	}
+	out := make(map[string]time.Time, len(raw))
+	for orgID, v := range raw {
+		ms, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			continue
+		}
+		out[orgID] = time.UnixMilli(ms).UTC()
+	}
+	return out, nil
+}
+
+// LastInboundFor returns orgID's latest inbound message time, if any.
+func (t *Tracker) LastInboundFor(ctx context.Context, orgID string) (time.Time, bool, error) {
+	v, err := t.redis.HGet(ctx, lastInboundKey, orgID).Result()
+	if errors.Is(err, redis.Nil) {
+		return time.Time{}, false, nil
+	}
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("inboundhealth: load last inbound: %w", err)
+	}
+	ms, err := strconv.ParseInt(v, 10, 64)
+	if err != nil {
+		return time.Time{}, false, nil
+	}
+	return time.UnixMilli(ms).UTC(), true, nil
+}
+
+// WatchSince returns when the checker started watching orgID, recording at
+// on the first call. A clinic that never receives a message is measured
+// from then instead of going unchecked.
+func (t *Tracker) WatchSince(ctx context.Context, orgID string, at time.Time) (time.Time, error) {
+	if err := t.redis.HSetNX(ctx, watchedKey, orgID, at.UnixMilli()).Err(); err != nil {
+		return time.Time{}, fmt.Errorf("inboundhealth: record watch start: %w", err)
+	}
+	ms, err := t.redis.HGet(ctx, watchedKey, orgID).Int64()
+	if err != nil {
+		return time.Time{}, fmt.Errorf("inboundhealth: load watch start: %w", err)
+	}
+	return time.UnixMilli(ms).UTC(), nil
+}
+
+// MarkDegraded records d for orgID unless it is already degraded. It
+// reports whether this call marked it, so only one checker instance alerts.
+func (t *Tracker) MarkDegraded(ctx context.Context, orgID string, d Degradation) (bool, error) {
+	body, err := json.Marshal(d)
+	if err != nil {
+		return false, fmt.Errorf("inboundhealth: encode degradation: %w", err)
+	}
+	marked, err := t.redis.HSetNX(ctx, degradedKey, orgID, body).Result()
+	if err != nil {
+		return false, fmt.Errorf("inboundhealth: mark degraded: %w", err)
+	}
+	return marked, nil
+}
+
+// ClearDegraded removes orgID's degraded state.
+func (t *Tracker) ClearDegraded(ctx context.Context, orgID string) error {
+	if err := t.redis.HDel(ctx, degradedKey, orgID).Err(); err != nil {
+		return fmt.Errorf("inboundhealth: clear degraded: %w", err)
+	}
+	return nil
+}
+
+// Degraded returns orgID's degraded state, or nil when it isn't degraded.
+func (t *Tracker) Degraded(ctx context.Context, orgID string) (*Degradation, error) {
+	v, err := t.redis.HGet(ctx, degradedKey, orgID).Result()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("inboundhealth: load degraded: %w", err)
+	}
+	var d Degradation
+	if err := json.Unmarshal([]byte(v), &d); err != nil {
+		return nil, fmt.Errorf("inboundhealth: decode degradation: %w", err)
+	}
+	return &d, nil
+}
@@ -0,0 +1,165 @@
+package leads
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/tenancy"
+)
+
+// Marketing consent sources.
+const (
+	ConsentSourceSMSReply = "sms_reply"
+	// ConsentSourceSMSUnclear records a reply to the opt-in ask that was
+	// neither yes nor no. It counts as no consent and closes the ask.
+	ConsentSourceSMSUnclear = "sms_reply_unclear"
+)
+
+// ConsentRepository records messaging consent on leads. Implemented by the
+// Postgres and in-memory repositories; callers type-assert for it.
+type ConsentRepository interface {
+	// RecordTransactionalConsent sets transactional_consent_at if unset.
+	RecordTransactionalConsent(ctx context.Context, leadID string, at time.Time) error
+	// MarkMarketingConsentAsked claims the one-time marketing opt-in ask.
+	// Returns false if the lead was already asked.
+	MarkMarketingConsentAsked(ctx context.Context, leadID string, at time.Time) (bool, error)
+	// SetMarketingConsent stores the lead's marketing opt-in answer.
+	SetMarketingConsent(ctx context.Context, leadID string, consent bool, source string, at time.Time) error
+	// HasMarketingConsent reports whether the scoped org's most recent lead
+	// for phone opted in. The scope must name one org.
+	HasMarketingConsent(ctx context.Context, scope tenancy.OrgScope, phone string) (bool, error)
+}
+
+var (
+	_ ConsentRepository = (*PostgresRepository)(nil)
+	_ ConsentRepository = (*InMemoryRepository)(nil)
+)
+
+// RecordTransactionalConsent sets transactional_consent_at once.
+func (r *PostgresRepository) RecordTransactionalConsent(ctx context.Context, leadID string, at time.Time) error {
+	// Runs on every inbound message; the IS NULL guard keeps repeats from
+	// rewriting the row.
+	query := `UPDATE leads SET transactional_consent_at = $2 WHERE id = $1 AND transactional_consent_at IS NULL`
+	if _, err := r.pool.Exec(ctx, query, leadID, at.UTC()); err != nil {
+		return fmt.Errorf("leads: record transactional consent: %w", err)
+	}
+	return nil
+}
+
+// MarkMarketingConsentAsked atomically claims the marketing opt-in ask.
+func (r *PostgresRepository) MarkMarketingConsentAsked(ctx context.Context, leadID string, at time.Time) (bool, error) {
+	query := `UPDATE leads SET marketing_consent_asked_at = $2 WHERE id = $1 AND marketing_consent_asked_at IS NULL`
+	result, err := r.pool.Exec(ctx, query, leadID, at.UTC())
+	if err != nil {
+		return false, fmt.Errorf("leads: mark marketing consent asked: %w", err)
+	}
+	return result.RowsAffected() == 1, nil
+}
+
+// SetMarketingConsent stores the marketing opt-in answer.
+func (r *PostgresRepository) SetMarketingConsent(ctx context.Context, leadID string, consent bool, source string, at time.Time) error {
+	query := `
+		UPDATE leads
+		SET marketing_consent = $2, marketing_consent_at = $3, marketing_consent_source = $4
+		WHERE id = $1
+	`
+	result, err := r.pool.Exec(ctx, query, leadID, consent, at.UTC(), source)
+	if err != nil {
+		return fmt.Errorf("leads: set marketing consent: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return ErrLeadNotFound
+	}
+	return nil
+}
+
+// HasMarketingConsent reports whether the most recent lead for the phone opted in.
+func (r *PostgresRepository) HasMarketingConsent(ctx context.Context, scope tenancy.OrgScope, phone string) (bool, error) {
+	orgID, err := singleOrg(scope)
+	if err != nil {
+		return false, err
+	}
+	query := `
+		SELECT marketing_consent
+		FROM leads
+		WHERE org_id = $1 AND (phone = $2 OR phone_hash = $3)
+		ORDER BY created_at DESC
+		LIMIT 1
+	`
+	var consent bool
+	if err := r.pool.QueryRow(ctx, query, orgID, phone, r.cipher.HashPhone(phone)).Scan(&consent); err != nil {
+		if err == pgx.ErrNoRows {
+			return false, nil
+		}
+		return false, fmt.Errorf("leads: check marketing consent: %w", err)
+	}
+	return consent, nil
+}
+
+// RecordTransactionalConsent sets TransactionalConsentAt once.
+func (r *InMemoryRepository) RecordTransactionalConsent(ctx context.Context, leadID string, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	lead, ok := r.leads[leadID]
+	if !ok {
+		return ErrLeadNotFound
+	}
+	if lead.TransactionalConsentAt == nil {
+		at = at.UTC()
+		lead.TransactionalConsentAt = &at
+	}
+	return nil
+}
+
+// MarkMarketingConsentAsked claims the marketing opt-in ask.
+func (r *InMemoryRepository) MarkMarketingConsentAsked(ctx context.Context, leadID string, at time.Time) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	lead, ok := r.leads[leadID]
+	if !ok {
+		return false, ErrLeadNotFound
+	}
+	if lead.MarketingConsentAskedAt != nil {
+		return false, nil
+	}
+	at = at.UTC()
+	lead.MarketingConsentAskedAt = &at
+	return true, nil
+}
+
+// SetMarketingConsent stores the marketing opt-in answer.
+func (r *InMemoryRepository) SetMarketingConsent(ctx context.Context, leadID string, consent bool, source string, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	lead, ok := r.leads[leadID]
+	if !ok {
+		return ErrLeadNotFound
+	}
+	at = at.UTC()
+	lead.MarketingConsent = consent
+	lead.MarketingConsentAt = &at
+	lead.MarketingConsentSource = source
+	return nil
+}
+
+// HasMarketingConsent reports whether the most recent lead for the phone opted in.
+func (r *InMemoryRepository) HasMarketingConsent(ctx context.Context, scope tenancy.OrgScope, phone string) (bool, error) {
+	orgID, err := singleOrg(scope)
+	if err != nil {
+		return false, err
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var latest *Lead
+	for _, l := range r.leads {
+		if l.OrgID == orgID && l.Phone == phone {
+			if latest == nil || l.CreatedAt.After(latest.CreatedAt) {
+				latest = l
+			}
+		}
+	}
+	return latest != nil && latest.MarketingConsent, nil
+}
@@ -9,6 +9,9 @@ var (
 	// ErrMissingContact is returned when both email and phone are missing
 	ErrMissingContact = errors.New("either email or phone is required")
 
+	// ErrInvalidPhone is returned when a phone number can't be read
+	ErrInvalidPhone = errors.New("phone number is invalid")
+
 	// ErrMissingOrgID is returned when org context is absent
 	ErrMissingOrgID = errors.New("org id is required")
 
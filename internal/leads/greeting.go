@@ -0,0 +1,47 @@
+package leads
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// GreetingRepository records the one-time first-contact greeting on leads.
+// Implemented by the Postgres and in-memory repositories; callers
+// type-assert for it.
+type GreetingRepository interface {
+	// MarkGreeted claims the first-contact greeting. Returns false if the
+	// lead was already greeted.
+	MarkGreeted(ctx context.Context, leadID string, at time.Time) (bool, error)
+}
+
+var (
+	_ GreetingRepository = (*PostgresRepository)(nil)
+	_ GreetingRepository = (*InMemoryRepository)(nil)
+)
+
+// MarkGreeted atomically claims the first-contact greeting.
+func (r *PostgresRepository) MarkGreeted(ctx context.Context, leadID string, at time.Time) (bool, error) {
+	query := `UPDATE leads SET greeted_at = $2 WHERE id = $1 AND greeted_at IS NULL`
+	result, err := r.pool.Exec(ctx, query, leadID, at.UTC())
+	if err != nil {
+		return false, fmt.Errorf("leads: mark greeted: %w", err)
+	}
+	return result.RowsAffected() == 1, nil
+}
+
+// MarkGreeted claims the first-contact greeting.
+func (r *InMemoryRepository) MarkGreeted(ctx context.Context, leadID string, at time.Time) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	lead, ok := r.leads[leadID]
+	if !ok {
+		return false, ErrLeadNotFound
+	}
+	if lead.GreetedAt != nil {
+		return false, nil
+	}
+	at = at.UTC()
+	lead.GreetedAt = &at
+	return true, nil
+}
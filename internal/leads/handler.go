@@ -1,19 +1,30 @@
 package leads
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"strconv"
+	"strings"
 
-	"github.com/go-chi/chi/v5"
+	"github.com/wolfman30/medspa-ai-platform/internal/clinic"
+	"github.com/wolfman30/medspa-ai-platform/internal/http/apierror"
 	"github.com/wolfman30/medspa-ai-platform/internal/tenancy"
 	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
+	"github.com/wolfman30/medspa-ai-platform/pkg/phone"
 )
 
+// clinicConfigGetter loads a clinic's config.
+type clinicConfigGetter interface {
+	Get(ctx context.Context, orgID string) (*clinic.Config, error)
+}
+
 // Handler handles HTTP requests for leads
 type Handler struct {
-	repo   Repository
-	logger *logging.Logger
+	repo    Repository
+	clinics clinicConfigGetter
+	logger  *logging.Logger
 }
 
 // NewHandler creates a new leads handler
@@ -24,27 +35,45 @@ func NewHandler(repo Repository, logger *logging.Logger) *Handler {
 	}
 }
 
+// SetClinicStore reads form phone numbers without a country code in the
+// clinic's region rather than the US.
+func (h *Handler) SetClinicStore(clinics clinicConfigGetter) {
+	h.clinics = clinics
+}
+
 // CreateWebLead handles POST /leads/web requests
 func (h *Handler) CreateWebLead(w http.ResponseWriter, r *http.Request) {
 	var req CreateLeadRequest
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		h.logger.Error("failed to decode request", "error", err)
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		apierror.Write(w, r, apierror.CodeValidationFailed, "invalid request body")
 		return
 	}
 
 	orgID, ok := tenancy.OrgIDFromContext(r.Context())
 	if !ok {
-		http.Error(w, "missing org context", http.StatusBadRequest)
+		apierror.Write(w, r, apierror.CodeValidationFailed, "missing org context")
 		return
 	}
 	req.OrgID = orgID
+	if strings.TrimSpace(req.Phone) != "" {
+		normalized, err := phone.Normalize(req.Phone, h.phoneRegion(r.Context(), orgID))
+		if err != nil {
+			apierror.Write(w, r, apierror.CodeValidationFailed, ErrInvalidPhone.Error())
+			return
+		}
+		req.Phone = normalized
+	}
 
 	lead, err := h.repo.Create(r.Context(), &req)
 	if err != nil {
 		h.logger.Error("failed to create lead", "error", err)
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		if isValidationError(err) {
+			apierror.Write(w, r, apierror.CodeValidationFailed, err.Error())
+			return
+		}
+		apierror.WriteError(w, r, err, apierror.CodeInternal, "failed to create lead")
 		return
 	}
 
@@ -55,6 +84,24 @@ func (h *Handler) CreateWebLead(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(lead)
 }
 
+// phoneRegion returns the region form phone numbers are read in for orgID.
+func (h *Handler) phoneRegion(ctx context.Context, orgID string) string {
+	if h.clinics == nil {
+		return phone.DefaultRegion
+	}
+	cfg, err := h.clinics.Get(ctx, orgID)
+	if err != nil {
+		h.logger.Warn("failed to load clinic config for phone region", "error", err, "org_id", orgID)
+		return phone.DefaultRegion
+	}
+	return cfg.PhoneRegion()
+}
+
+// isValidationError reports whether err came from CreateLeadRequest.Validate.
+func isValidationError(err error) bool {
+	return errors.Is(err, ErrInvalidName) || errors.Is(err, ErrMissingContact) || errors.Is(err, ErrMissingOrgID) || errors.Is(err, ErrInvalidPhone)
+}
+
 // ListLeadsResponse is the response for listing leads
 type ListLeadsResponse struct {
 	Leads  []*Lead `json:"leads"`
@@ -63,11 +110,13 @@ type ListLeadsResponse struct {
 	Limit  int     `json:"limit"`
 }
 
-// ListLeads handles GET /admin/clinics/{orgID}/leads requests
+// ListLeads handles GET /admin/clinics/{orgID}/leads requests. ?sort=score
+// puts the leads most worth a call first.
 func (h *Handler) ListLeads(w http.ResponseWriter, r *http.Request) {
-	orgID := chi.URLParam(r, "orgID")
+	scope := tenancy.ScopeFromContext(r.Context())
+	orgID := scope.OrgID()
 	if orgID == "" {
-		http.Error(w, "missing org_id", http.StatusBadRequest)
+		apierror.Write(w, r, apierror.CodeValidationFailed, "missing org_id")
 		return
 	}
 
@@ -93,10 +142,19 @@ func (h *Handler) ListLeads(w http.ResponseWriter, r *http.Request) {
 		filter.DepositStatus = status
 	}
 
-	leads, err := h.repo.ListByOrg(r.Context(), orgID, filter)
+	switch sort := r.URL.Query().Get("sort"); sort {
+	case "", ListSortNewest:
+	case ListSortScore:
+		filter.Sort = ListSortScore
+	default:
+		apierror.Write(w, r, apierror.CodeValidationFailed, "sort must be newest or score")
+		return
+	}
+
+	leads, err := h.repo.ListByOrg(r.Context(), scope, filter)
 	if err != nil {
 		h.logger.Error("failed to list leads", "error", err, "org_id", orgID)
-		http.Error(w, "failed to list leads", http.StatusInternalServerError)
+		apierror.WriteError(w, r, err, apierror.CodeInternal, "failed to list leads")
 		return
 	}
 
@@ -0,0 +1,92 @@
+package leads
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/wolfman30/medspa-ai-platform/internal/http/apierror"
+)
+
+// maxImportBodyBytes bounds an import upload; DefaultImportRowCap rows fit
+// well within it.
+const maxImportBodyBytes = 10 << 20
+
+// ImportLeads handles POST /admin/orgs/{orgID}/leads/import. The CSV (name,
+// phone, email, last service, last visit date) is the request body or a
+// multipart "file" field. ?mode=execute saves the rows; the default dry run
+// only reports per-row validation and the merge each existing lead would
+// get. Imported leads are never messaged.
+func (h *Handler) ImportLeads(w http.ResponseWriter, r *http.Request) {
+	orgID := chi.URLParam(r, "orgID")
+	if orgID == "" {
+		apierror.Write(w, r, apierror.CodeValidationFailed, "missing org_id")
+		return
+	}
+	repo, ok := h.repo.(ImportRepository)
+	if !ok {
+		apierror.Write(w, r, apierror.CodeInternal, "lead import is not supported by this store")
+		return
+	}
+	mode := strings.TrimSpace(r.URL.Query().Get("mode"))
+	if mode == "" {
+		mode = ImportModeDryRun
+	}
+	if mode != ImportModeDryRun && mode != ImportModeExecute {
+		apierror.Write(w, r, apierror.CodeValidationFailed, "mode must be dry_run or execute")
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxImportBodyBytes)
+	src, err := importSource(r)
+	if err != nil {
+		apierror.Write(w, r, apierror.CodeValidationFailed, err.Error())
+		return
+	}
+	summary, err := NewImporter(repo, h.logger).Import(r.Context(), orgID, src, ImportOptions{
+		Execute: mode == ImportModeExecute,
+		Region:  h.phoneRegion(r.Context(), orgID),
+	})
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		switch {
+		case errors.Is(err, ErrImportHeader):
+			apierror.Write(w, r, apierror.CodeValidationFailed, "csv needs a header row with a phone column")
+		case errors.As(err, &tooLarge):
+			apierror.WriteStatus(w, r, http.StatusRequestEntityTooLarge, apierror.CodeValidationFailed, "csv file is too large", nil)
+		default:
+			h.logger.Error("lead import failed", "org_id", orgID, "mode", mode, "error", err)
+			apierror.WriteError(w, r, err, apierror.CodeInternal, "failed to import leads")
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(summary)
+}
+
+// importSource returns the CSV stream of an import request: the "file" part
+// of a multipart upload, or the body itself.
+func importSource(r *http.Request) (io.Reader, error) {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType != "multipart/form-data" {
+		return r.Body, nil
+	}
+	parts, err := r.MultipartReader()
+	if err != nil {
+		return nil, errors.New("invalid multipart body")
+	}
+	for {
+		part, err := parts.NextPart()
+		if err != nil {
+			return nil, errors.New(`multipart body needs a "file" field`)
+		}
+		if part.FormName() == "file" {
+			return part, nil
+		}
+	}
+}
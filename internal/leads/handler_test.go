@@ -9,8 +9,10 @@ import (
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
-	"github.com/go-chi/chi/v5"
+	"github.com/wolfman30/medspa-ai-platform/internal/clinic"
+	"github.com/wolfman30/medspa-ai-platform/internal/http/apierror"
 	"github.com/wolfman30/medspa-ai-platform/internal/tenancy"
 	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
 )
@@ -23,7 +25,7 @@ func TestCreateWebLead_Success(t *testing.T) {
 	reqBody := CreateLeadRequest{
 		Name:    "John Doe",
 		Email:   "john@example.com",
-		Phone:   "+1234567890",
+		Phone:   "(500) 555-0002",
 		Message: "Interested in botox treatment",
 		Source:  "website",
 	}
@@ -51,6 +53,61 @@ func TestCreateWebLead_Success(t *testing.T) {
 	if lead.Email != reqBody.Email {
 		t.Errorf("expected email %s, got %s", reqBody.Email, lead.Email)
 	}
+
+	if lead.Phone != "+15005550002" {
+		t.Errorf("expected normalized phone, got %s", lead.Phone)
+	}
+}
+
+type stubClinicConfigs map[string]*clinic.Config
+
+func (s stubClinicConfigs) Get(ctx context.Context, orgID string) (*clinic.Config, error) {
+	return s[orgID], nil
+}
+
+func TestCreateWebLead_PhoneValidation(t *testing.T) {
+	tests := []struct {
+		name     string
+		country  string
+		phone    string
+		wantCode int
+		want     string
+	}{
+		{name: "us national", phone: "500.555.0002", wantCode: http.StatusCreated, want: "+15005550002"},
+		{name: "us with country code", phone: "1 500 555 0002", wantCode: http.StatusCreated, want: "+15005550002"},
+		{name: "clinic region", country: "GB", phone: "020 7946 0958", wantCode: http.StatusCreated, want: "+442079460958"},
+		{name: "international overrides region", country: "GB", phone: "+1 (500) 555-0002", wantCode: http.StatusCreated, want: "+15005550002"},
+		{name: "too short", phone: "555-0002", wantCode: http.StatusBadRequest},
+		{name: "letters", phone: "call me maybe", wantCode: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := NewInMemoryRepository()
+			handler := NewHandler(repo, logging.Default())
+			handler.SetClinicStore(stubClinicConfigs{"org-test": {Country: tt.country}})
+
+			body, _ := json.Marshal(CreateLeadRequest{Name: "Jane", Phone: tt.phone})
+			req := httptest.NewRequest(http.MethodPost, "/leads/web", bytes.NewReader(body))
+			req = req.WithContext(tenancy.WithOrgID(req.Context(), "org-test"))
+			w := httptest.NewRecorder()
+
+			handler.CreateWebLead(w, req)
+
+			if w.Code != tt.wantCode {
+				t.Fatalf("expected status %d, got %d: %s", tt.wantCode, w.Code, w.Body.String())
+			}
+			if tt.wantCode != http.StatusCreated {
+				return
+			}
+			var lead Lead
+			if err := json.NewDecoder(w.Body).Decode(&lead); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if lead.Phone != tt.want {
+				t.Fatalf("expected phone %s, got %s", tt.want, lead.Phone)
+			}
+		})
+	}
 }
 
 func TestCreateWebLead_InvalidRequest(t *testing.T) {
@@ -94,6 +151,13 @@ func TestCreateWebLead_MissingContact(t *testing.T) {
 	if w.Code != http.StatusBadRequest {
 		t.Errorf("expected status %d, got %d", http.StatusBadRequest, w.Code)
 	}
+	var env apierror.Envelope
+	if err := json.NewDecoder(w.Body).Decode(&env); err != nil {
+		t.Fatalf("decode error envelope: %v", err)
+	}
+	if env.Error.Code != apierror.CodeValidationFailed || env.Error.Message != ErrMissingContact.Error() {
+		t.Fatalf("unexpected error body: %+v", env.Error)
+	}
 }
 
 func TestCreateWebLead_InvalidJSON(t *testing.T) {
@@ -118,7 +182,7 @@ func (f failingRepository) Create(context.Context, *CreateLeadRequest) (*Lead, e
 	return nil, errors.New("boom")
 }
 
-func (f failingRepository) GetByID(context.Context, string, string) (*Lead, error) {
+func (f failingRepository) GetByID(context.Context, tenancy.OrgScope, string) (*Lead, error) {
 	return nil, ErrLeadNotFound
 }
 
@@ -126,23 +190,27 @@ func (f failingRepository) GetOrCreateByPhone(context.Context, string, string, s
 	return nil, errors.New("boom")
 }
 
-func (f failingRepository) UpdateSchedulingPreferences(context.Context, string, SchedulingPreferences) error {
+func (f failingRepository) UpdateSchedulingPreferences(context.Context, tenancy.OrgScope, string, SchedulingPreferences) error {
+	return errors.New("boom")
+}
+
+func (f failingRepository) MergePreferences(context.Context, string, SchedulingPreferences) error {
 	return errors.New("boom")
 }
 
-func (f failingRepository) UpdateDepositStatus(context.Context, string, string, string) error {
+func (f failingRepository) UpdateDepositStatus(context.Context, tenancy.OrgScope, string, string, string) error {
 	return errors.New("boom")
 }
 
-func (f failingRepository) ListByOrg(context.Context, string, ListLeadsFilter) ([]*Lead, error) {
+func (f failingRepository) ListByOrg(context.Context, tenancy.OrgScope, ListLeadsFilter) ([]*Lead, error) {
 	return nil, errors.New("boom")
 }
 
-func (f failingRepository) UpdateSelectedAppointment(context.Context, string, SelectedAppointment) error {
+func (f failingRepository) UpdateSelectedAppointment(context.Context, tenancy.OrgScope, string, SelectedAppointment) error {
 	return errors.New("boom")
 }
 
-func (f failingRepository) UpdateBookingSession(context.Context, string, BookingSessionUpdate) error {
+func (f failingRepository) UpdateBookingSession(context.Context, tenancy.OrgScope, string, BookingSessionUpdate) error {
 	return errors.New("boom")
 }
 
@@ -150,7 +218,7 @@ func (f failingRepository) GetByBookingSessionID(context.Context, string) (*Lead
 	return nil, errors.New("boom")
 }
 
-func (f failingRepository) UpdateEmail(context.Context, string, string) error {
+func (f failingRepository) UpdateEmail(context.Context, tenancy.OrgScope, string, string) error {
 	return errors.New("boom")
 }
 
@@ -172,8 +240,15 @@ func TestCreateWebLead_RepositoryError(t *testing.T) {
 
 	handler.CreateWebLead(w, req)
 
-	if w.Code != http.StatusBadRequest {
-		t.Fatalf("expected %d, got %d", http.StatusBadRequest, w.Code)
+	if w.Code != http.StatusInternalServerError {
+		t.Fatalf("expected %d, got %d", http.StatusInternalServerError, w.Code)
+	}
+	var env apierror.Envelope
+	if err := json.NewDecoder(w.Body).Decode(&env); err != nil {
+		t.Fatalf("decode error envelope: %v", err)
+	}
+	if env.Error.Code != apierror.CodeInternal || strings.Contains(env.Error.Message, "boom") {
+		t.Fatalf("unexpected error body: %+v", env.Error)
 	}
 }
 
@@ -223,7 +298,7 @@ func TestRepository_GetByID(t *testing.T) {
 		t.Fatalf("unexpected error: %v", err)
 	}
 
-	found, err := repo.GetByID(ctx, "org-test", created.ID)
+	found, err := repo.GetByID(ctx, tenancy.ForOrg("org-test"), created.ID)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -237,7 +312,7 @@ func TestRepository_GetByID_NotFound(t *testing.T) {
 	repo := NewInMemoryRepository()
 	ctx := context.Background()
 
-	_, err := repo.GetByID(ctx, "org-test", "nonexistent")
+	_, err := repo.GetByID(ctx, tenancy.ForOrg("org-test"), "nonexistent")
 	if err != ErrLeadNotFound {
 		t.Errorf("expected ErrLeadNotFound, got %v", err)
 	}
@@ -270,9 +345,7 @@ func TestListLeads_Success(t *testing.T) {
 	})
 
 	req := httptest.NewRequest(http.MethodGet, "/admin/clinics/"+orgID+"/leads", nil)
-	rctx := chi.NewRouteContext()
-	rctx.URLParams.Add("orgID", orgID)
-	req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+	req = req.WithContext(tenancy.WithOrgID(req.Context(), orgID))
 	w := httptest.NewRecorder()
 
 	handler.ListLeads(w, req)
@@ -308,9 +381,7 @@ func TestListLeads_WithPagination(t *testing.T) {
 	}
 
 	req := httptest.NewRequest(http.MethodGet, "/admin/clinics/"+orgID+"/leads?limit=2&offset=1", nil)
-	rctx := chi.NewRouteContext()
-	rctx.URLParams.Add("orgID", orgID)
-	req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+	req = req.WithContext(tenancy.WithOrgID(req.Context(), orgID))
 	w := httptest.NewRecorder()
 
 	handler.ListLeads(w, req)
@@ -342,20 +413,18 @@ func TestListLeads_FilterByDepositStatus(t *testing.T) {
 		Name:  "Paid Lead",
 		Phone: "+11111111111",
 	})
-	_ = repo.UpdateDepositStatus(ctx, lead1.ID, "paid", "priority")
+	_ = repo.UpdateDepositStatus(ctx, tenancy.ForOrg(lead1.OrgID), lead1.ID, "paid", "priority")
 
 	lead2, _ := repo.Create(ctx, &CreateLeadRequest{
 		OrgID: orgID,
 		Name:  "Pending Lead",
 		Phone: "+12222222222",
 	})
-	_ = repo.UpdateDepositStatus(ctx, lead2.ID, "pending", "normal")
+	_ = repo.UpdateDepositStatus(ctx, tenancy.ForOrg(lead2.OrgID), lead2.ID, "pending", "normal")
 
 	// Filter for paid only
 	req := httptest.NewRequest(http.MethodGet, "/admin/clinics/"+orgID+"/leads?deposit_status=paid", nil)
-	rctx := chi.NewRouteContext()
-	rctx.URLParams.Add("orgID", orgID)
-	req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+	req = req.WithContext(tenancy.WithOrgID(req.Context(), orgID))
 	w := httptest.NewRecorder()
 
 	handler.ListLeads(w, req)
@@ -371,6 +440,47 @@ func TestListLeads_FilterByDepositStatus(t *testing.T) {
 	}
 }
 
+func TestListLeads_SortByScore(t *testing.T) {
+	repo := NewInMemoryRepository()
+	handler := NewHandler(repo, logging.Default())
+	ctx := context.Background()
+	orgID := "org-789"
+
+	for _, l := range []struct {
+		name, phone string
+		score       int
+	}{{"Low", "+15550001111", 20}, {"High", "+15550002222", 80}, {"Mid", "+15550003333", 50}} {
+		lead, _ := repo.Create(ctx, &CreateLeadRequest{OrgID: orgID, Name: l.name, Phone: l.phone})
+		_ = repo.SaveScore(ctx, lead.ID, ScoreBreakdown{Qualification: l.score}, time.Now())
+	}
+	// Scored at 40 when they last texted, urgently, ten days ago; only the
+	// qualification points are left now.
+	stale, _ := repo.Create(ctx, &CreateLeadRequest{OrgID: orgID, Name: "Stale", Phone: "+15550004444"})
+	_ = repo.RecordEngagement(ctx, stale.ID, time.Now().Add(-10*24*time.Hour), true)
+	_ = repo.SaveScore(ctx, stale.ID, ScoreBreakdown{Qualification: 5, Recency: ScoreRecencyMax, Urgency: ScoreUrgency}, time.Now().Add(-10*24*time.Hour))
+
+	list := func(query string) (int, []string) {
+		req := httptest.NewRequest(http.MethodGet, "/admin/clinics/"+orgID+"/leads"+query, nil)
+		w := httptest.NewRecorder()
+		handler.ListLeads(w, req.WithContext(tenancy.WithOrgID(req.Context(), orgID)))
+		var resp ListLeadsResponse
+		_ = json.NewDecoder(w.Body).Decode(&resp)
+		var names []string
+		for _, l := range resp.Leads {
+			names = append(names, l.Name)
+		}
+		return w.Code, names
+	}
+
+	code, names := list("?sort=score")
+	if code != http.StatusOK || strings.Join(names, ",") != "High,Mid,Low,Stale" {
+		t.Fatalf("sort=score returned %d %v, want High,Mid,Low,Stale", code, names)
+	}
+	if code, _ := list("?sort=price"); code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for an unknown sort, got %d", code)
+	}
+}
+
 func TestRepository_ListByOrg(t *testing.T) {
 	repo := NewInMemoryRepository()
 	ctx := context.Background()
@@ -385,7 +495,7 @@ func TestRepository_ListByOrg(t *testing.T) {
 		})
 	}
 
-	leads, err := repo.ListByOrg(ctx, orgID, ListLeadsFilter{})
+	leads, err := repo.ListByOrg(ctx, tenancy.ForOrg(orgID), ListLeadsFilter{})
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
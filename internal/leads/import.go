@@ -0,0 +1,260 @@
+package leads
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/tenancy"
+)
+
+// SourceImport is the source of leads created by a historical import.
+const SourceImport = "import"
+
+// ImportRecord is one patient from a clinic's records, as read from an
+// import file.
+type ImportRecord struct {
+	Name        string
+	Phone       string // E.164
+	Email       string
+	LastService string
+	LastVisit   *time.Time
+}
+
+// ImportChange is a field an import sets on an existing lead.
+type ImportChange struct {
+	Field string `json:"field"`
+	From  string `json:"from,omitempty"`
+	To    string `json:"to"`
+}
+
+// ImportOutcome is what saving one ImportRecord did.
+type ImportOutcome struct {
+	LeadID  string
+	Created bool
+	Changes []ImportChange
+}
+
+// ImportRepository saves imported leads. Implemented by the Postgres and
+// in-memory repositories; callers type-assert for it.
+//
+// Saving an import only writes the lead row. It records no consent and
+// publishes nothing, so an imported patient is never texted until they
+// text the clinic themselves.
+type ImportRepository interface {
+	// FindByPhone returns the scoped org's most recent lead for a canonical
+	// phone, or ErrLeadNotFound. The scope must name one org.
+	FindByPhone(ctx context.Context, scope tenancy.OrgScope, phone string) (*Lead, error)
+	// SaveImported creates rec as a lead with source "import" when the org
+	// has no lead for its phone, and otherwise merges it into the most
+	// recent one. Saving the same record twice changes nothing.
+	SaveImported(ctx context.Context, orgID string, rec ImportRecord, at time.Time) (ImportOutcome, error)
+}
+
+var (
+	_ ImportRepository = (*PostgresRepository)(nil)
+	_ ImportRepository = (*InMemoryRepository)(nil)
+)
+
+// newImportedLead builds the lead an ImportRecord creates. Anyone in the
+// clinic's records has visited before, so they are an existing patient.
+func newImportedLead(orgID string, rec ImportRecord, at time.Time) *Lead {
+	at = at.UTC()
+	return &Lead{
+		ID:           uuid.New().String(),
+		OrgID:        orgID,
+		Name:         strings.TrimSpace(rec.Name),
+		Email:        strings.TrimSpace(rec.Email),
+		Phone:        CanonicalPhone(rec.Phone),
+		Source:       SourceImport,
+		CreatedAt:    at,
+		PatientType:  "existing",
+		PastServices: strings.TrimSpace(rec.LastService),
+		LastVisitAt:  rec.LastVisit,
+		ImportedAt:   &at,
+	}
+}
+
+// mergeImport applies rec to lead in place and returns what changed. An
+// import only fills gaps: names and emails the lead already has are kept,
+// the last service is added to past services, and the last visit only
+// moves forward. The lead's source and conversation state are untouched.
+func mergeImport(lead *Lead, rec ImportRecord) []ImportChange {
+	var changes []ImportChange
+	fill := func(field string, dst *string, value string) {
+		if value = strings.TrimSpace(value); value != "" && strings.TrimSpace(*dst) == "" {
+			changes = append(changes, ImportChange{Field: field, To: value})
+			*dst = value
+		}
+	}
+	fill("name", &lead.Name, rec.Name)
+	fill("email", &lead.Email, rec.Email)
+
+	if lead.PatientType != "existing" {
+		changes = append(changes, ImportChange{Field: "patient_type", From: lead.PatientType, To: "existing"})
+		lead.PatientType = "existing"
+	}
+	if service := strings.TrimSpace(rec.LastService); service != "" && !hasService(lead.PastServices, service) {
+		merged := service
+		if strings.TrimSpace(lead.PastServices) != "" {
+			merged = strings.TrimSpace(lead.PastServices) + ", " + service
+		}
+		changes = append(changes, ImportChange{Field: "past_services", From: lead.PastServices, To: merged})
+		lead.PastServices = merged
+	}
+	if rec.LastVisit != nil && (lead.LastVisitAt == nil || rec.LastVisit.After(*lead.LastVisitAt)) {
+		change := ImportChange{Field: "last_visit_at", To: rec.LastVisit.Format(time.DateOnly)}
+		if lead.LastVisitAt != nil {
+			change.From = lead.LastVisitAt.Format(time.DateOnly)
+		}
+		changes = append(changes, change)
+		visit := *rec.LastVisit
+		lead.LastVisitAt = &visit
+	}
+	return changes
+}
+
+// hasService reports whether a comma-separated service list already names
+// service, ignoring case.
+func hasService(list, service string) bool {
+	for _, s := range strings.Split(list, ",") {
+		if strings.EqualFold(strings.TrimSpace(s), service) {
+			return true
+		}
+	}
+	return false
+}
+
+// FindByPhone returns the scoped org's most recent lead for phone.
+func (r *PostgresRepository) FindByPhone(ctx context.Context, scope tenancy.OrgScope, phone string) (*Lead, error) {
+	orgID, err := singleOrg(scope)
+	if err != nil {
+		return nil, err
+	}
+	lead, err := r.findByPhone(ctx, r.pool, orgID, CanonicalPhone(phone), false)
+	if err != nil {
+		return nil, err
+	}
+	if err := scope.Check(lead.OrgID); err != nil {
+		return nil, fmt.Errorf("leads: find by phone: %w", err)
+	}
+	return lead, nil
+}
+
+// SaveImported creates or merges an imported lead in one transaction. An
+// advisory lock on the org and phone keeps two imports of the same file
+// from both creating the lead.
+func (r *PostgresRepository) SaveImported(ctx context.Context, orgID string, rec ImportRecord, at time.Time) (ImportOutcome, error) {
+	phone := CanonicalPhone(rec.Phone)
+	if strings.TrimSpace(orgID) == "" || phone == "" {
+		return ImportOutcome{}, fmt.Errorf("leads: org and phone are required")
+	}
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return ImportOutcome{}, fmt.Errorf("leads: begin import: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	lockKey := r.cipher.HashPhone(phone)
+	if lockKey == "" {
+		lockKey = phone
+	}
+	if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock(hashtext($1))`, "lead-import:"+orgID+":"+lockKey); err != nil {
+		return ImportOutcome{}, fmt.Errorf("leads: lock import: %w", err)
+	}
+	lead, err := r.findByPhone(ctx, tx, orgID, phone, true)
+	var outcome ImportOutcome
+	switch {
+	case errors.Is(err, ErrLeadNotFound):
+		lead = newImportedLead(orgID, rec, at)
+		var sealed [3]string
+		for i, value := range []string{lead.Name, lead.Email, lead.Phone} {
+			if sealed[i], err = r.seal(value); err != nil {
+				return ImportOutcome{}, err
+			}
+		}
+		query := `
+			INSERT INTO leads (id, org_id, name, email, phone, message, source, phone_hash,
+			                   patient_type, past_services, last_visit_at, imported_at, created_at)
+			VALUES ($1, $2, $3, $4, $5, '', $6, NULLIF($7, ''), $8, NULLIF($9, ''), $10, $11, $11)
+		`
+		if _, err := tx.Exec(ctx, query,
+			lead.ID, orgID, sealed[0], sealed[1], sealed[2], lead.Source, r.cipher.HashPhone(phone),
+			lead.PatientType, lead.PastServices, lead.LastVisitAt, lead.ImportedAt,
+		); err != nil {
+			return ImportOutcome{}, fmt.Errorf("leads: insert imported lead: %w", err)
+		}
+		outcome = ImportOutcome{LeadID: lead.ID, Created: true}
+	case err != nil:
+		return ImportOutcome{}, err
+	default:
+		changes := mergeImport(lead, rec)
+		if len(changes) == 0 {
+			return ImportOutcome{LeadID: lead.ID}, nil
+		}
+		name, err := r.seal(lead.Name)
+		if err != nil {
+			return ImportOutcome{}, err
+		}
+		email, err := r.seal(lead.Email)
+		if err != nil {
+			return ImportOutcome{}, err
+		}
+		query := `
+			UPDATE leads
+			SET name = $2, email = $3, patient_type = $4, past_services = $5,
+			    last_visit_at = $6, imported_at = COALESCE(imported_at, $7)
+			WHERE id = $1
+		`
+		if _, err := tx.Exec(ctx, query, lead.ID, name, email, lead.PatientType, lead.PastServices, lead.LastVisitAt, at.UTC()); err != nil {
+			return ImportOutcome{}, fmt.Errorf("leads: merge imported lead: %w", err)
+		}
+		outcome = ImportOutcome{LeadID: lead.ID, Changes: changes}
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return ImportOutcome{}, fmt.Errorf("leads: commit import: %w", err)
+	}
+	return outcome, nil
+}
+
+// FindByPhone returns the scoped org's most recent lead for phone.
+func (r *InMemoryRepository) FindByPhone(ctx context.Context, scope tenancy.OrgScope, phone string) (*Lead, error) {
+	orgID, err := singleOrg(scope)
+	if err != nil {
+		return nil, err
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if lead := r.latestByPhone(orgID, CanonicalPhone(phone)); lead != nil {
+		copied := *lead
+		return &copied, nil
+	}
+	return nil, ErrLeadNotFound
+}
+
+// SaveImported creates or merges an imported lead.
+func (r *InMemoryRepository) SaveImported(ctx context.Context, orgID string, rec ImportRecord, at time.Time) (ImportOutcome, error) {
+	phone := CanonicalPhone(rec.Phone)
+	if strings.TrimSpace(orgID) == "" || phone == "" {
+		return ImportOutcome{}, fmt.Errorf("leads: org and phone are required")
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	lead := r.latestByPhone(orgID, phone)
+	if lead == nil {
+		lead = newImportedLead(orgID, rec, at)
+		r.leads[lead.ID] = lead
+		return ImportOutcome{LeadID: lead.ID, Created: true}, nil
+	}
+	changes := mergeImport(lead, rec)
+	if len(changes) > 0 && lead.ImportedAt == nil {
+		importedAt := at.UTC()
+		lead.ImportedAt = &importedAt
+	}
+	return ImportOutcome{LeadID: lead.ID, Changes: changes}, nil
+}
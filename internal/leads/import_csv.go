@@ -0,0 +1,372 @@
+package leads
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net/mail"
+	"strings"
+	"time"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/tenancy"
+	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
+	"github.com/wolfman30/medspa-ai-platform/pkg/phone"
+)
+
+// Import modes.
+const (
+	ImportModeDryRun  = "dry_run"
+	ImportModeExecute = "execute"
+)
+
+// DefaultImportRowCap is the most data rows one import reads. Rows past the
+// cap are counted, up to another cap's worth, but not validated or saved.
+const DefaultImportRowCap = 5000
+
+// importProgressEvery is how many rows pass between progress log lines.
+const importProgressEvery = 500
+
+// Import row statuses.
+const (
+	ImportRowNew       = "new"       // no lead has the phone; one is created
+	ImportRowMerge     = "merge"     // an existing lead gains the listed changes
+	ImportRowUnchanged = "unchanged" // an existing lead already has everything
+	ImportRowInvalid   = "invalid"   // the row is skipped; see its problems
+	ImportRowFailed    = "failed"    // saving the row failed in execute mode
+)
+
+// Import row problems.
+const (
+	ImportProblemMissingPhone = "missing_phone"
+	ImportProblemBadPhone     = "bad_phone"
+	ImportProblemDuplicate    = "duplicate_in_file"
+	ImportProblemBadEmail     = "bad_email"
+	ImportProblemBadDate      = "bad_last_visit_date"
+	ImportProblemMalformed    = "malformed_row"
+)
+
+// ErrImportHeader is returned when an import file's first row isn't a
+// header naming a phone column.
+var ErrImportHeader = errors.New("leads: import needs a header row with a phone column")
+
+// importColumns maps the header names an import accepts to its fields.
+var importColumns = map[string]string{
+	"name":             "name",
+	"full_name":        "name",
+	"patient_name":     "name",
+	"phone":            "phone",
+	"phone_number":     "phone",
+	"mobile":           "phone",
+	"mobile_phone":     "phone",
+	"cell":             "phone",
+	"email":            "email",
+	"email_address":    "email",
+	"last_service":     "last_service",
+	"last_treatment":   "last_service",
+	"last_visit_date":  "last_visit_date",
+	"last_visit":       "last_visit_date",
+	"last_appointment": "last_visit_date",
+}
+
+// importDateLayouts are the last-visit date formats an import accepts.
+var importDateLayouts = []string{
+	time.DateOnly,
+	"1/2/2006",
+	"1/2/06",
+	"2006/1/2",
+	"Jan 2, 2006",
+	"January 2, 2006",
+}
+
+// ImportOptions configures one import run.
+type ImportOptions struct {
+	// Execute saves the rows; otherwise the run is a dry run that only
+	// reports what would happen.
+	Execute bool
+	// Region reads phones written without a country code.
+	Region string
+	// RowCap overrides DefaultImportRowCap when positive.
+	RowCap int
+}
+
+// ImportRowResult reports one data row of an import file.
+type ImportRowResult struct {
+	Row            int            `json:"row"` // record number in the file; the header is row 1
+	Name           string         `json:"name,omitempty"`
+	Phone          string         `json:"phone,omitempty"`
+	Status         string         `json:"status"`
+	Problems       []string       `json:"problems,omitempty"`
+	DuplicateOfRow int            `json:"duplicate_of_row,omitempty"`
+	LeadID         string         `json:"lead_id,omitempty"`
+	Changes        []ImportChange `json:"changes,omitempty"`
+	Error          string         `json:"error,omitempty"`
+}
+
+// ImportSummary is the result of an import run.
+type ImportSummary struct {
+	Mode      string `json:"mode"`
+	RowCap    int    `json:"row_cap"`
+	Processed int    `json:"processed"`
+	New       int    `json:"new"`
+	Merged    int    `json:"merged"`
+	Unchanged int    `json:"unchanged"`
+	Invalid   int    `json:"invalid"`
+	Failed    int    `json:"failed"`
+	// Truncated is set when the file had more rows than the cap;
+	// SkippedOverCap counts them, up to RowCap. Reading stops there.
+	Truncated      bool              `json:"truncated"`
+	SkippedOverCap int               `json:"skipped_over_cap"`
+	Rows           []ImportRowResult `json:"rows"`
+}
+
+// Importer loads a clinic's historical patients from CSV into leads.
+type Importer struct {
+	repo   ImportRepository
+	logger *logging.Logger
+	now    func() time.Time
+}
+
+// NewImporter builds an importer over repo.
+func NewImporter(repo ImportRepository, logger *logging.Logger) *Importer {
+	if logger == nil {
+		logger = logging.Default()
+	}
+	return &Importer{repo: repo, logger: logger, now: time.Now}
+}
+
+// Import reads CSV rows from src one at a time, so large files aren't held
+// in memory. Each row is validated and compared to the org's existing
+// leads; with opts.Execute it is also saved as it is read. A dry run and
+// the execute run that follows it report the same statuses.
+func (im *Importer) Import(ctx context.Context, orgID string, src io.Reader, opts ImportOptions) (*ImportSummary, error) {
+	rowCap := opts.RowCap
+	if rowCap <= 0 {
+		rowCap = DefaultImportRowCap
+	}
+	summary := &ImportSummary{Mode: ImportModeDryRun, RowCap: rowCap, Rows: []ImportRowResult{}}
+	if opts.Execute {
+		summary.Mode = ImportModeExecute
+	}
+
+	reader := csv.NewReader(src)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	reader.LazyQuotes = true
+	header, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, ErrImportHeader
+		}
+		return nil, fmt.Errorf("leads: read import header: %w", err)
+	}
+	columns, err := importHeader(header)
+	if err != nil {
+		return nil, err
+	}
+
+	now := im.now()
+	seen := make(map[string]int)
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		var parseErr *csv.ParseError
+		if err != nil && !errors.As(err, &parseErr) {
+			return nil, fmt.Errorf("leads: read import row %d: %w", line, err)
+		}
+		if err == nil && blankRecord(record) {
+			continue
+		}
+		if summary.Processed >= rowCap {
+			summary.Truncated = true
+			summary.SkippedOverCap++
+			if summary.SkippedOverCap >= rowCap {
+				break
+			}
+			continue
+		}
+		summary.Processed++
+
+		var result ImportRowResult
+		if parseErr != nil {
+			result = ImportRowResult{Row: line, Status: ImportRowInvalid, Problems: []string{ImportProblemMalformed}}
+		} else {
+			result = im.importRow(ctx, orgID, line, columns.record(record), opts, seen, now)
+		}
+		summary.add(result)
+		if summary.Processed%importProgressEvery == 0 {
+			im.logger.Info("lead import progress", "org_id", orgID, "mode", summary.Mode, "rows", summary.Processed)
+		}
+	}
+
+	im.logger.Info("lead import finished", "org_id", orgID, "mode", summary.Mode,
+		"rows", summary.Processed, "new", summary.New, "merged", summary.Merged,
+		"unchanged", summary.Unchanged, "invalid", summary.Invalid, "failed", summary.Failed,
+		"skipped_over_cap", summary.SkippedOverCap)
+	return summary, nil
+}
+
+// importRow validates one row and, in execute mode, saves it.
+func (im *Importer) importRow(ctx context.Context, orgID string, line int, raw map[string]string, opts ImportOptions, seen map[string]int, now time.Time) ImportRowResult {
+	result := ImportRowResult{Row: line, Name: raw["name"]}
+	rec, problems := parseImportRecord(raw, opts.Region, now)
+	if rec.Phone != "" {
+		result.Phone = rec.Phone
+		if first, ok := seen[rec.Phone]; ok {
+			problems = append(problems, ImportProblemDuplicate)
+			result.DuplicateOfRow = first
+		} else {
+			seen[rec.Phone] = line
+		}
+	}
+	if len(problems) > 0 {
+		result.Status = ImportRowInvalid
+		result.Problems = problems
+		return result
+	}
+
+	if opts.Execute {
+		outcome, err := im.repo.SaveImported(ctx, orgID, rec, now)
+		if err != nil {
+			im.logger.Error("failed to save imported lead", "org_id", orgID, "row", line, "error", err)
+			result.Status = ImportRowFailed
+			result.Error = "failed to save lead"
+			return result
+		}
+		result.LeadID = outcome.LeadID
+		result.Changes = outcome.Changes
+		result.Status = outcomeStatus(outcome.Created, len(outcome.Changes))
+		return result
+	}
+
+	existing, err := im.repo.FindByPhone(ctx, tenancy.ForOrg(orgID), rec.Phone)
+	switch {
+	case errors.Is(err, ErrLeadNotFound):
+		result.Status = ImportRowNew
+	case err != nil:
+		im.logger.Error("failed to look up lead for import", "org_id", orgID, "row", line, "error", err)
+		result.Status = ImportRowFailed
+		result.Error = "failed to look up lead"
+	default:
+		// FindByPhone returns a copy, so the preview merge changes nothing.
+		result.LeadID = existing.ID
+		result.Changes = mergeImport(existing, rec)
+		result.Status = outcomeStatus(false, len(result.Changes))
+	}
+	return result
+}
+
+func outcomeStatus(created bool, changes int) string {
+	switch {
+	case created:
+		return ImportRowNew
+	case changes > 0:
+		return ImportRowMerge
+	}
+	return ImportRowUnchanged
+}
+
+func (s *ImportSummary) add(result ImportRowResult) {
+	switch result.Status {
+	case ImportRowNew:
+		s.New++
+	case ImportRowMerge:
+		s.Merged++
+	case ImportRowUnchanged:
+		s.Unchanged++
+	case ImportRowInvalid:
+		s.Invalid++
+	case ImportRowFailed:
+		s.Failed++
+	}
+	s.Rows = append(s.Rows, result)
+}
+
+// parseImportRecord reads a row's fields, normalizing the phone to E.164.
+func parseImportRecord(raw map[string]string, region string, now time.Time) (ImportRecord, []string) {
+	rec := ImportRecord{
+		Name:        raw["name"],
+		Email:       raw["email"],
+		LastService: raw["last_service"],
+	}
+	var problems []string
+	if raw["phone"] == "" {
+		problems = append(problems, ImportProblemMissingPhone)
+	} else if normalized, err := phone.Normalize(raw["phone"], region); err != nil {
+		problems = append(problems, ImportProblemBadPhone)
+	} else {
+		rec.Phone = normalized
+	}
+	if rec.Email != "" {
+		if addr, err := mail.ParseAddress(rec.Email); err != nil || addr.Address != rec.Email {
+			problems = append(problems, ImportProblemBadEmail)
+		}
+	}
+	if value := raw["last_visit_date"]; value != "" {
+		visit, ok := parseImportDate(value)
+		if !ok || visit.After(now) {
+			problems = append(problems, ImportProblemBadDate)
+		} else {
+			rec.LastVisit = &visit
+		}
+	}
+	return rec, problems
+}
+
+// parseImportDate reads a last-visit date as midnight UTC.
+func parseImportDate(value string) (time.Time, bool) {
+	for _, layout := range importDateLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// importColumnIndex maps import fields to their column in the file.
+type importColumnIndex map[string]int
+
+// importHeader reads the header row. Names are matched case-insensitively,
+// with spaces and dashes read as underscores; unknown columns are ignored.
+func importHeader(header []string) (importColumnIndex, error) {
+	columns := make(importColumnIndex)
+	for i, name := range header {
+		if i == 0 {
+			name = strings.TrimPrefix(name, "\ufeff")
+		}
+		key := strings.ToLower(strings.TrimSpace(name))
+		key = strings.NewReplacer(" ", "_", "-", "_").Replace(key)
+		if field, ok := importColumns[key]; ok {
+			if _, dup := columns[field]; !dup {
+				columns[field] = i
+			}
+		}
+	}
+	if _, ok := columns["phone"]; !ok {
+		return nil, ErrImportHeader
+	}
+	return columns, nil
+}
+
+// record returns a row's trimmed values by field.
+func (c importColumnIndex) record(row []string) map[string]string {
+	values := make(map[string]string, len(c))
+	for field, i := range c {
+		if i < len(row) {
+			values[field] = strings.TrimSpace(row[i])
+		}
+	}
+	return values
+}
+
+func blankRecord(record []string) bool {
+	for _, v := range record {
+		if strings.TrimSpace(v) != "" {
+			return false
+		}
+	}
+	return true
+}
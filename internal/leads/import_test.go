@@ -0,0 +1,246 @@
+package leads
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/tenancy"
+	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
+)
+
+func newTestImporter(repo ImportRepository) *Importer {
+	im := NewImporter(repo, logging.Default())
+	im.now = func() time.Time { return time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC) }
+	return im
+}
+
+func runImport(t *testing.T, im *Importer, csv string, execute bool) *ImportSummary {
+	t.Helper()
+	summary, err := im.Import(context.Background(), "org-1", strings.NewReader(csv), ImportOptions{Execute: execute})
+	if err != nil {
+		t.Fatalf("Import: %v", err)
+	}
+	return summary
+}
+
+func TestImportValidatesRows(t *testing.T) {
+	repo := NewInMemoryRepository()
+	csv := "Name,Phone,Email,Last Service,Last Visit Date\n" +
+		"Ann Lee,(500) 555-0101,ann@example.com,Botox,2026-03-04\n" +
+		"Bad Phone,555-CALL-NOW,,,\n" +
+		"No Phone,,nophone@example.com,,\n" +
+		"Ann Again,+1 500 555 0101,,,\n" +
+		"Bad Email,5005550102,not-an-email,,\n" +
+		"Bad Date,5005550103,,,03/45/2026\n" +
+		"Future,5005550104,,,2027-01-01\n"
+
+	summary := runImport(t, newTestImporter(repo), csv, false)
+
+	want := []struct {
+		status   string
+		problems []string
+	}{
+		{ImportRowNew, nil},
+		{ImportRowInvalid, []string{ImportProblemBadPhone}},
+		{ImportRowInvalid, []string{ImportProblemMissingPhone}},
+		{ImportRowInvalid, []string{ImportProblemDuplicate}},
+		{ImportRowInvalid, []string{ImportProblemBadEmail}},
+		{ImportRowInvalid, []string{ImportProblemBadDate}},
+		{ImportRowInvalid, []string{ImportProblemBadDate}},
+	}
+	if len(summary.Rows) != len(want) {
+		t.Fatalf("expected %d rows, got %+v", len(want), summary.Rows)
+	}
+	for i, w := range want {
+		got := summary.Rows[i]
+		if got.Row != i+2 || got.Status != w.status || fmt.Sprint(got.Problems) != fmt.Sprint(w.problems) {
+			t.Errorf("row %d = %+v, want status %s problems %v", i+2, got, w.status, w.problems)
+		}
+	}
+	if dup := summary.Rows[3]; dup.DuplicateOfRow != 2 || dup.Phone != "+15005550101" {
+		t.Errorf("duplicate should point at row 2 with the normalized phone, got %+v", dup)
+	}
+	if summary.Mode != ImportModeDryRun || summary.New != 1 || summary.Invalid != 6 {
+		t.Errorf("unexpected summary %+v", summary)
+	}
+	if leads, _ := repo.ListByOrg(context.Background(), tenancy.ForOrg("org-1"), ListLeadsFilter{}); len(leads) != 0 {
+		t.Fatalf("a dry run must not save leads, got %d", len(leads))
+	}
+}
+
+func TestImportMergesExistingLead(t *testing.T) {
+	repo := NewInMemoryRepository()
+	ctx := context.Background()
+	existing, err := repo.GetOrCreateByPhone(ctx, "org-1", "+15005550101", "sms", "Ann")
+	if err != nil {
+		t.Fatalf("seed lead: %v", err)
+	}
+	if err := repo.MergePreferences(ctx, existing.ID, SchedulingPreferences{PatientType: "new", PastServices: "Filler", ServiceInterest: "Botox"}); err != nil {
+		t.Fatalf("seed preferences: %v", err)
+	}
+	csv := "phone,name,email,last_service,last_visit_date\n" +
+		"500-555-0101,Ann Lee,ann@example.com,Botox,3/4/2026\n"
+	im := newTestImporter(repo)
+
+	preview := runImport(t, im, csv, false)
+	row := preview.Rows[0]
+	if row.Status != ImportRowMerge || row.LeadID != existing.ID {
+		t.Fatalf("expected a merge into the existing lead, got %+v", row)
+	}
+	wantChanges := []ImportChange{
+		{Field: "email", To: "ann@example.com"},
+		{Field: "patient_type", From: "new", To: "existing"},
+		{Field: "past_services", From: "Filler", To: "Filler, Botox"},
+		{Field: "last_visit_at", To: "2026-03-04"},
+	}
+	if fmt.Sprint(row.Changes) != fmt.Sprint(wantChanges) {
+		t.Fatalf("merge preview = %+v, want %+v", row.Changes, wantChanges)
+	}
+	if lead, _ := repo.GetByID(ctx, tenancy.ForOrg("org-1"), existing.ID); lead.Email != "" || lead.PatientType != "new" {
+		t.Fatalf("the preview must not change the lead, got %+v", lead)
+	}
+
+	executed := runImport(t, im, csv, true)
+	if got := executed.Rows[0]; got.Status != ImportRowMerge || fmt.Sprint(got.Changes) != fmt.Sprint(wantChanges) {
+		t.Fatalf("execute should apply the previewed merge, got %+v", got)
+	}
+	lead, _ := repo.GetByID(ctx, tenancy.ForOrg("org-1"), existing.ID)
+	if lead.Name != "Ann" || lead.Source != "sms" || lead.ServiceInterest != "Botox" {
+		t.Fatalf("merge must keep the lead's own name, source and conversation data, got %+v", lead)
+	}
+	if lead.Email != "ann@example.com" || lead.PastServices != "Filler, Botox" || lead.LastVisitAt == nil || lead.ImportedAt == nil {
+		t.Fatalf("merge should fill the lead from the import, got %+v", lead)
+	}
+}
+
+func TestImportIsIdempotent(t *testing.T) {
+	repo := NewInMemoryRepository()
+	csv := "Name,Phone,Email,Last Service,Last Visit Date\n" +
+		"Ann Lee,5005550101,ann@example.com,Botox,2026-03-04\n" +
+		"Bo Kim,5005550102,,Filler,2026-02-01\n"
+	im := newTestImporter(repo)
+
+	first := runImport(t, im, csv, true)
+	if first.New != 2 {
+		t.Fatalf("first import should create both leads, got %+v", first)
+	}
+	second := runImport(t, im, csv, true)
+	if second.New != 0 || second.Merged != 0 || second.Unchanged != 2 {
+		t.Fatalf("re-import should change nothing, got %+v", second)
+	}
+	for i, row := range second.Rows {
+		if row.LeadID != first.Rows[i].LeadID {
+			t.Errorf("row %d: re-import matched lead %s, want %s", row.Row, row.LeadID, first.Rows[i].LeadID)
+		}
+	}
+	leads, _ := repo.ListByOrg(context.Background(), tenancy.ForOrg("org-1"), ListLeadsFilter{})
+	if len(leads) != 2 {
+		t.Fatalf("expected 2 leads, got %d", len(leads))
+	}
+	for _, lead := range leads {
+		if lead.Source != SourceImport || lead.PatientType != "existing" || !strings.HasPrefix(lead.Phone, "+1500555010") {
+			t.Errorf("unexpected imported lead %+v", lead)
+		}
+	}
+}
+
+func TestImportRowCap(t *testing.T) {
+	var b strings.Builder
+	b.WriteString("phone\n")
+	for i := range 12 {
+		fmt.Fprintf(&b, "50055501%02d\n", i)
+	}
+	summary, err := newTestImporter(NewInMemoryRepository()).Import(context.Background(), "org-1", strings.NewReader(b.String()), ImportOptions{RowCap: 10})
+	if err != nil {
+		t.Fatalf("Import: %v", err)
+	}
+	if summary.Processed != 10 || !summary.Truncated || summary.SkippedOverCap != 2 || len(summary.Rows) != 10 {
+		t.Fatalf("unexpected capped summary %+v", summary)
+	}
+
+	// Past a second cap's worth of rows the rest of the file isn't read.
+	b.Reset()
+	b.WriteString("phone\n")
+	for i := range 40 {
+		fmt.Fprintf(&b, "50055501%02d\n", i)
+	}
+	summary, err = newTestImporter(NewInMemoryRepository()).Import(context.Background(), "org-1", strings.NewReader(b.String()), ImportOptions{RowCap: 10})
+	if err != nil {
+		t.Fatalf("Import: %v", err)
+	}
+	if summary.Processed != 10 || summary.SkippedOverCap != 10 {
+		t.Fatalf("expected counting to stop at the cap, got %+v", summary)
+	}
+
+	if _, err := newTestImporter(NewInMemoryRepository()).Import(context.Background(), "org-1", strings.NewReader("name,email\nAnn,ann@example.com\n"), ImportOptions{}); err != ErrImportHeader {
+		t.Fatalf("expected ErrImportHeader without a phone column, got %v", err)
+	}
+}
+
+func TestImportLeadsHandlerNeverMessages(t *testing.T) {
+	repo := NewInMemoryRepository()
+	handler := NewHandler(repo, logging.Default())
+	router := chi.NewRouter()
+	router.Post("/admin/orgs/{orgID}/leads/import", handler.ImportLeads)
+
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	part, _ := form.CreateFormFile("file", "patients.csv")
+	part.Write([]byte("Name,Phone,Email,Last Service,Last Visit Date\nAnn Lee,5005550101,ann@example.com,Botox,2026-03-04\n"))
+	form.Close()
+	req := httptest.NewRequest(http.MethodPost, "/admin/orgs/org-1/leads/import?mode=execute", &body)
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	var summary ImportSummary
+	if err := json.NewDecoder(rr.Body).Decode(&summary); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if summary.Mode != ImportModeExecute || summary.New != 1 {
+		t.Fatalf("unexpected summary %+v", summary)
+	}
+
+	// Outbound texts need consent or a conversation: broadcasts check
+	// marketing consent, and replies and the opt-in ask follow an inbound
+	// message. An imported lead has none of them.
+	lead, err := repo.FindByPhone(context.Background(), tenancy.ForOrg("org-1"), "+15005550101")
+	if err != nil {
+		t.Fatalf("FindByPhone: %v", err)
+	}
+	if lead.TransactionalConsentAt != nil || lead.MarketingConsent || lead.MarketingConsentAt != nil ||
+		lead.MarketingConsentAskedAt != nil || lead.GreetedAt != nil {
+		t.Fatalf("imported lead must carry no consent or contact state, got %+v", lead)
+	}
+	if ok, _ := repo.HasMarketingConsent(context.Background(), tenancy.ForOrg("org-1"), "+15005550101"); ok {
+		t.Fatal("imported lead must not be eligible for marketing")
+	}
+
+	bad := httptest.NewRequest(http.MethodPost, "/admin/orgs/org-1/leads/import?mode=send", strings.NewReader("phone\n5005550101\n"))
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, bad)
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for an unknown mode, got %d", rr.Code)
+	}
+
+	// Uploads past the size limit are rejected, even under the row cap.
+	huge := "phone,notes\n5005550102," + strings.Repeat("x", maxImportBodyBytes) + "\n"
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/admin/orgs/org-1/leads/import", strings.NewReader(huge)))
+	if rr.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("expected 413 for an oversized upload, got %d", rr.Code)
+	}
+}
@@ -3,6 +3,8 @@ package leads
 import (
 	"strings"
 	"time"
+
+	"github.com/wolfman30/medspa-ai-platform/pkg/phone"
 )
 
 // Lead represents a lead submission from a web form or conversation
@@ -26,6 +28,10 @@ type Lead struct {
 	DepositStatus   string `json:"deposit_status,omitempty"`   // "pending", "paid", "refunded"
 	PriorityLevel   string `json:"priority_level,omitempty"`   // "normal", "priority" (deposit paid)
 
+	// ExtraQualifications are answers to the clinic's service-specific
+	// questions, keyed by question key, e.g. {"area": "Underarms"}.
+	ExtraQualifications map[string]string `json:"extra_qualifications,omitempty"`
+
 	// Selected appointment (set when lead picks a specific time slot)
 	SelectedDateTime    *time.Time `json:"selected_datetime,omitempty"`     // The specific date/time the lead selected
 	SelectedEndDateTime *time.Time `json:"selected_end_datetime,omitempty"` // The end date/time for the selected slot
@@ -39,6 +45,33 @@ type Lead struct {
 	BookingHandoffURL         string     `json:"booking_handoff_url,omitempty"`         // URL sent to lead for Step 5
 	BookingHandoffSentAt      *time.Time `json:"booking_handoff_sent_at,omitempty"`     // When the handoff URL was sent
 	BookingCompletedAt        *time.Time `json:"booking_completed_at,omitempty"`        // When booking was completed/failed
+
+	// Messaging consent. Texting the clinic consents to transactional replies;
+	// marketing messages need a separate, explicit opt-in.
+	TransactionalConsentAt  *time.Time `json:"transactional_consent_at,omitempty"`   // First inbound message
+	MarketingConsent        bool       `json:"marketing_consent"`                    // Opted in to promotional messages
+	MarketingConsentAt      *time.Time `json:"marketing_consent_at,omitempty"`       // When the marketing answer was recorded
+	MarketingConsentSource  string     `json:"marketing_consent_source,omitempty"`   // e.g. "sms_reply"
+	MarketingConsentAskedAt *time.Time `json:"marketing_consent_asked_at,omitempty"` // When the one-time opt-in ask was sent
+
+	// GreetedAt is when the clinic's first-contact template was sent.
+	GreetedAt *time.Time `json:"greeted_at,omitempty"`
+
+	// Clinic records brought in by a lead import.
+	LastVisitAt *time.Time `json:"last_visit_at,omitempty"` // Patient's last visit per the clinic's records
+	ImportedAt  *time.Time `json:"imported_at,omitempty"`   // When an import first created or merged this lead
+
+	// Lead scoring for operator follow-up, recomputed by the worker on each
+	// state change.
+	LastEngagedAt      *time.Time      `json:"last_engaged_at,omitempty"`      // Patient's last inbound message
+	UrgencyExpressedAt *time.Time      `json:"urgency_expressed_at,omitempty"` // Last time they asked to be seen soon
+	Score              int             `json:"score"`                          // 0-100, see ComputeScore
+	ScoreBreakdown     *ScoreBreakdown `json:"score_breakdown,omitempty"`      // Per-signal share of Score
+	ScoredAt           *time.Time      `json:"scored_at,omitempty"`            // When Score was last computed
+
+	// ProbableWrongNumber marks a lead whose texts were meant for another
+	// business. It is cleared when the patient shows real interest.
+	ProbableWrongNumber bool `json:"probable_wrong_number,omitempty"`
 }
 
 // CreateLeadRequest represents the request body for creating a lead
@@ -62,3 +95,13 @@ func (r *CreateLeadRequest) Validate() error {
 	}
 	return nil
 }
+
+// CanonicalPhone returns the form lead phones are stored and looked up in:
+// E.164 when raw has any digits, raw itself otherwise.
+func CanonicalPhone(raw string) string {
+	raw = strings.TrimSpace(raw)
+	if normalized := phone.E164(raw); normalized != "" {
+		return normalized
+	}
+	return raw
+}
@@ -0,0 +1,259 @@
+package leads
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/pii"
+	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
+	"github.com/wolfman30/medspa-ai-platform/pkg/phone"
+)
+
+// PhoneBackfillDB is the subset of pgxpool.Pool the phone backfill needs.
+type PhoneBackfillDB interface {
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	Begin(ctx context.Context) (pgx.Tx, error)
+}
+
+// PhoneBackfillResult counts what a phone backfill run changed.
+type PhoneBackfillResult struct {
+	Scanned    int
+	Normalized int
+	Merged     int
+	// Invalid rows hold phones that don't normalize; they are left as is.
+	Invalid int
+}
+
+// leadReference is a column pointing at leads.id whose rows move to the kept
+// lead when duplicates are merged.
+type leadReference struct {
+	table string
+	text  bool // lead_id is stored as text rather than uuid
+}
+
+// leadReferences lists every table that points at a lead. Compliance audit
+// events are left alone: they record what happened to the lead at the time.
+// Tables whose foreign key cascades must be listed, or deleting the merged
+// lead erases their rows.
+var leadReferences = []leadReference{
+	{table: "conversations"},
+	{table: "bookings"},
+	{table: "payments"},
+	{table: "escalations"},
+	{table: "callback_promises"},
+	{table: "callback_tasks", text: true},
+	{table: "broadcast_recipients"},
+	{table: "emr_writeback_attempts"},
+}
+
+// PhoneBackfiller rewrites lead phones into E.164 and merges leads whose
+// phones turn out to be the same number, so lookup-or-create by phone finds
+// one lead per patient. The most recently created lead of a group is kept,
+// matching what GetOrCreateByPhone returned; the others' conversations,
+// bookings, payments and tasks move onto it, and blank names and emails are
+// filled from them. Each group is merged in its own transaction, so a run
+// can be stopped and rerun safely.
+type PhoneBackfiller struct {
+	db      PhoneBackfillDB
+	cipher  *pii.Cipher
+	clinics clinicConfigGetter
+	logger  *logging.Logger
+}
+
+// NewPhoneBackfiller builds a backfiller. cipher may be nil when lead PII
+// isn't encrypted.
+func NewPhoneBackfiller(db PhoneBackfillDB, cipher *pii.Cipher, logger *logging.Logger) *PhoneBackfiller {
+	if logger == nil {
+		logger = logging.Default()
+	}
+	return &PhoneBackfiller{db: db, cipher: cipher, logger: logger}
+}
+
+// SetClinicStore reads phones without a country code in each clinic's region
+// rather than the US.
+func (b *PhoneBackfiller) SetClinicStore(clinics clinicConfigGetter) {
+	b.clinics = clinics
+}
+
+type phoneBackfillRow struct {
+	id        uuid.UUID
+	orgID     string
+	stored    string // plaintext phone as stored
+	canonical string
+}
+
+// Run normalizes every lead phone and merges the collisions.
+func (b *PhoneBackfiller) Run(ctx context.Context) (PhoneBackfillResult, error) {
+	var result PhoneBackfillResult
+	rows, err := b.readLeads(ctx)
+	if err != nil {
+		return result, err
+	}
+	result.Scanned = len(rows)
+
+	regions := make(map[string]string)
+	var order []string
+	groups := make(map[string][]phoneBackfillRow)
+	for _, row := range rows {
+		region, ok := regions[row.orgID]
+		if !ok {
+			region = b.region(ctx, row.orgID)
+			regions[row.orgID] = region
+		}
+		canonical, err := canonicalStoredPhone(row.stored, region)
+		if err != nil {
+			result.Invalid++
+			b.logger.Warn("lead phone does not normalize; left as is", "lead_id", row.id, "org_id", row.orgID)
+			continue
+		}
+		row.canonical = canonical
+		key := row.orgID + "|" + canonical
+		if _, seen := groups[key]; !seen {
+			order = append(order, key)
+		}
+		groups[key] = append(groups[key], row)
+	}
+
+	for _, key := range order {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+		group := groups[key]
+		kept, duplicates := group[0], group[1:]
+		if len(duplicates) == 0 && kept.stored == kept.canonical {
+			continue
+		}
+		if err := b.mergeGroup(ctx, kept, duplicates); err != nil {
+			return result, err
+		}
+		if kept.stored != kept.canonical {
+			result.Normalized++
+		}
+		result.Merged += len(duplicates)
+	}
+	return result, nil
+}
+
+// readLeads loads every lead with a phone, newest first within each org.
+func (b *PhoneBackfiller) readLeads(ctx context.Context) ([]phoneBackfillRow, error) {
+	rows, err := b.db.Query(ctx, `
+		SELECT id, org_id, phone FROM leads
+		WHERE COALESCE(phone, '') <> ''
+		ORDER BY org_id, created_at DESC, id
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("leads: phone backfill: read leads: %w", err)
+	}
+	defer rows.Close()
+
+	var out []phoneBackfillRow
+	for rows.Next() {
+		var row phoneBackfillRow
+		var sealed string
+		if err := rows.Scan(&row.id, &row.orgID, &sealed); err != nil {
+			return nil, fmt.Errorf("leads: phone backfill: scan lead: %w", err)
+		}
+		if row.stored, err = b.cipher.Decrypt(sealed); err != nil {
+			return nil, fmt.Errorf("leads: phone backfill: decrypt lead %s: %w", row.id, err)
+		}
+		out = append(out, row)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("leads: phone backfill: read leads: %w", err)
+	}
+	return out, nil
+}
+
+// mergeGroup folds duplicates into kept and stores kept's canonical phone.
+func (b *PhoneBackfiller) mergeGroup(ctx context.Context, kept phoneBackfillRow, duplicates []phoneBackfillRow) error {
+	sealed, err := b.cipher.Encrypt(kept.canonical)
+	if err != nil {
+		return fmt.Errorf("leads: phone backfill: encrypt: %w", err)
+	}
+	tx, err := b.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("leads: phone backfill: begin: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	for _, dup := range duplicates {
+		for _, ref := range leadReferences {
+			var keptID, dupID any = kept.id, dup.id
+			if ref.text {
+				keptID, dupID = kept.id.String(), dup.id.String()
+			}
+			if _, err := tx.Exec(ctx, `UPDATE `+ref.table+` SET lead_id = $1 WHERE lead_id = $2`, keptID, dupID); err != nil {
+				return fmt.Errorf("leads: phone backfill: move %s of lead %s: %w", ref.table, dup.id, err)
+			}
+		}
+		if _, err := tx.Exec(ctx, `
+			UPDATE leads k
+			SET name = COALESCE(NULLIF(k.name, ''), d.name),
+			    email = COALESCE(NULLIF(k.email, ''), d.email)
+			FROM leads d
+			WHERE k.id = $1 AND d.id = $2
+		`, kept.id, dup.id); err != nil {
+			return fmt.Errorf("leads: phone backfill: fill lead %s: %w", kept.id, err)
+		}
+		if _, err := tx.Exec(ctx, `
+			INSERT INTO lead_phone_merges (merged_lead_id, kept_lead_id, org_id, merged_at)
+			VALUES ($1, $2, $3, $4)
+			ON CONFLICT (merged_lead_id) DO NOTHING
+		`, dup.id, kept.id, kept.orgID, time.Now().UTC()); err != nil {
+			return fmt.Errorf("leads: phone backfill: record merge of lead %s: %w", dup.id, err)
+		}
+		if _, err := tx.Exec(ctx, `DELETE FROM leads WHERE id = $1`, dup.id); err != nil {
+			return fmt.Errorf("leads: phone backfill: delete lead %s: %w", dup.id, err)
+		}
+	}
+	if _, err := tx.Exec(ctx, `UPDATE leads SET phone = $2, phone_hash = NULLIF($3, '') WHERE id = $1`,
+		kept.id, sealed, b.cipher.HashPhone(kept.canonical)); err != nil {
+		return fmt.Errorf("leads: phone backfill: update lead %s: %w", kept.id, err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("leads: phone backfill: commit: %w", err)
+	}
+	if len(duplicates) > 0 {
+		b.logger.Info("merged leads with the same phone", "org_id", kept.orgID, "kept_lead_id", kept.id, "merged", len(duplicates))
+	}
+	return nil
+}
+
+func (b *PhoneBackfiller) region(ctx context.Context, orgID string) string {
+	if b.clinics == nil {
+		return phone.DefaultRegion
+	}
+	cfg, err := b.clinics.Get(ctx, orgID)
+	if err != nil {
+		b.logger.Warn("failed to load clinic config for phone region", "error", err, "org_id", orgID)
+		return phone.DefaultRegion
+	}
+	return cfg.PhoneRegion()
+}
+
+// canonicalStoredPhone normalizes a stored lead phone. Older ingress code
+// turned bare US numbers into "+5005550002"; in a North American region a
+// "+" followed by exactly ten digits is read as one of those.
+func canonicalStoredPhone(stored, region string) (string, error) {
+	trimmed := strings.TrimSpace(stored)
+	if phone.CallingCode(region) == "1" && strings.HasPrefix(trimmed, "+") {
+		if digits := strings.TrimPrefix(trimmed, "+"); len(digits) == 10 && isDigits(digits) {
+			return phone.Normalize(digits, region)
+		}
+	}
+	return phone.Normalize(trimmed, region)
+}
+
+func isDigits(value string) bool {
+	for _, r := range value {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return value != ""
+}
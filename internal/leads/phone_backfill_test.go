@@ -0,0 +1,144 @@
+package leads
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	pgxmock "github.com/pashagolub/pgxmock/v4"
+)
+
+func TestPhoneBackfillMergesCollisions(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("pgxmock: %v", err)
+	}
+	defer mock.Close()
+
+	newest, older, oldest := uuid.New(), uuid.New(), uuid.New()
+	legacy, canonical, invalid := uuid.New(), uuid.New(), uuid.New()
+	mock.ExpectQuery("SELECT id, org_id, phone FROM leads").
+		WillReturnRows(pgxmock.NewRows([]string{"id", "org_id", "phone"}).
+			AddRow(newest, "org-1", "5005550002").
+			AddRow(older, "org-1", "+15005550002").
+			AddRow(oldest, "org-1", "(500) 555-0002").
+			AddRow(legacy, "org-1", "+5005550003").
+			AddRow(canonical, "org-1", "+15005550004").
+			AddRow(invalid, "org-1", "555-0100"))
+
+	// The three spellings of 500-555-0002 collapse into the newest lead.
+	mock.ExpectBegin()
+	for _, dup := range []uuid.UUID{older, oldest} {
+		for _, ref := range leadReferences {
+			args := []any{newest, dup}
+			if ref.text {
+				args = []any{newest.String(), dup.String()}
+			}
+			mock.ExpectExec("UPDATE " + ref.table + " SET lead_id").WithArgs(args...).
+				WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+		}
+		mock.ExpectExec("UPDATE leads k").WithArgs(newest, dup).WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+		mock.ExpectExec("INSERT INTO lead_phone_merges").WithArgs(dup, newest, "org-1", pgxmock.AnyArg()).
+			WillReturnResult(pgxmock.NewResult("INSERT", 1))
+		mock.ExpectExec("DELETE FROM leads").WithArgs(dup).WillReturnResult(pgxmock.NewResult("DELETE", 1))
+	}
+	mock.ExpectExec("UPDATE leads SET phone").WithArgs(newest, "+15005550002", "").
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	mock.ExpectCommit()
+
+	// A "+"-prefixed bare US number written by older code gets its country code.
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE leads SET phone").WithArgs(legacy, "+15005550003", "").
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	mock.ExpectCommit()
+
+	b := NewPhoneBackfiller(mock, nil, nil)
+	result, err := b.Run(context.Background())
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if result != (PhoneBackfillResult{Scanned: 6, Normalized: 2, Merged: 2, Invalid: 1}) {
+		t.Fatalf("result = %+v", result)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestCanonicalStoredPhone(t *testing.T) {
+	tests := []struct {
+		stored, region, want string
+	}{
+		{"+5005550002", "US", "+15005550002"},
+		{"+15005550002", "US", "+15005550002"},
+		{"500-555-0002", "CA", "+15005550002"},
+		// Outside North America a ten-digit international number is real.
+		{"+6581234567", "GB", "+6581234567"},
+		{"07911 123456", "GB", "+447911123456"},
+	}
+	for _, tt := range tests {
+		got, err := canonicalStoredPhone(tt.stored, tt.region)
+		if err != nil || got != tt.want {
+			t.Errorf("canonicalStoredPhone(%q, %q) = %q, %v; want %q", tt.stored, tt.region, got, err, tt.want)
+		}
+	}
+}
+
+// notMergedLeadColumns are tables with a lead_id column that the merge
+// deliberately leaves pointing at the merged lead.
+var notMergedLeadColumns = map[string]bool{
+	"compliance_audit_events": true,
+}
+
+var (
+	migrationTableRE   = regexp.MustCompile(`(?i)^\s*(?:CREATE|ALTER)\s+TABLE\s+(?:IF\s+(?:NOT\s+)?EXISTS\s+)?(?:ONLY\s+)?(\w+)`)
+	migrationLeadColRE = regexp.MustCompile(`(?i)\bREFERENCES\s+leads\b|^\s*(?:ADD\s+(?:COLUMN\s+)?(?:IF\s+NOT\s+EXISTS\s+)?)?lead_id\s+(?:uuid|text)\b`)
+)
+
+// TestLeadReferencesCoverMigrations fails when a migration adds a table that
+// points at leads without listing it in leadReferences, so merging duplicate
+// leads never strands or, through a cascading foreign key, deletes its rows.
+func TestLeadReferencesCoverMigrations(t *testing.T) {
+	files, err := filepath.Glob("../../migrations/*.up.sql")
+	if err != nil || len(files) == 0 {
+		t.Fatalf("no migrations found: %v", err)
+	}
+	listed := make(map[string]bool, len(leadReferences))
+	for _, ref := range leadReferences {
+		listed[ref.table] = true
+	}
+
+	found := map[string]string{}
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatalf("read %s: %v", file, err)
+		}
+		table := ""
+		for _, line := range strings.Split(string(data), "\n") {
+			if m := migrationTableRE.FindStringSubmatch(line); m != nil {
+				table = strings.ToLower(m[1])
+			}
+			if table != "" && table != "leads" && migrationLeadColRE.MatchString(line) {
+				if _, ok := found[table]; !ok {
+					found[table] = filepath.Base(file)
+				}
+			}
+		}
+	}
+
+	for _, table := range []string{"conversations", "emr_writeback_attempts", "callback_tasks"} {
+		if _, ok := found[table]; !ok {
+			t.Fatalf("migration scan missed %s; the patterns are out of date", table)
+		}
+	}
+	for table, file := range found {
+		if !listed[table] && !notMergedLeadColumns[table] {
+			t.Errorf("%s (%s) points at leads but is missing from leadReferences", table, file)
+		}
+	}
+}
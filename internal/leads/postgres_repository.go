@@ -2,18 +2,33 @@ package leads
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"strings"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
+
+	"errors"
+	"github.com/wolfman30/medspa-ai-platform/internal/pii"
+	"github.com/wolfman30/medspa-ai-platform/internal/tenancy"
 )
 
 // PostgresRepository stores leads in the relational database.
 type PostgresRepository struct {
-	pool *pgxpool.Pool
+	pool   leadsDB
+	cipher *pii.Cipher
+}
+
+// leadsDB is the subset of pgxpool.Pool the repository uses.
+type leadsDB interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+	Begin(ctx context.Context) (pgx.Tx, error)
 }
 
 // NewPostgresRepository initializes a repo backed by pgxpool.
@@ -24,27 +39,63 @@ func NewPostgresRepository(pool *pgxpool.Pool) *PostgresRepository {
 	return &PostgresRepository{pool: pool}
 }
 
-// Create inserts a new row.
+// SetCipher enables encryption of lead names, emails, and phones at rest.
+// Phones are also hashed into phone_hash so lookups by phone keep working.
+func (r *PostgresRepository) SetCipher(c *pii.Cipher) {
+	r.cipher = c
+}
+
+// seal encrypts a PII value for storage.
+func (r *PostgresRepository) seal(value string) (string, error) {
+	sealed, err := r.cipher.Encrypt(value)
+	if err != nil {
+		return "", fmt.Errorf("leads: encrypt: %w", err)
+	}
+	return sealed, nil
+}
+
+// reveal decrypts a scanned lead's PII columns in place.
+func (r *PostgresRepository) reveal(lead *Lead) error {
+	for _, field := range []*string{&lead.Name, &lead.Email, &lead.Phone} {
+		plaintext, err := r.cipher.Decrypt(*field)
+		if err != nil {
+			return fmt.Errorf("leads: decrypt lead %s: %w", lead.ID, err)
+		}
+		*field = plaintext
+	}
+	return nil
+}
+
+// Create inserts a new row. req.Phone is stored in its canonical form.
 func (r *PostgresRepository) Create(ctx context.Context, req *CreateLeadRequest) (*Lead, error) {
 	if err := req.Validate(); err != nil {
 		return nil, err
 	}
+	req.Phone = CanonicalPhone(req.Phone)
 
 	id := uuid.New()
+	var sealed [3]string
+	for i, value := range []string{req.Name, req.Email, req.Phone} {
+		var err error
+		if sealed[i], err = r.seal(value); err != nil {
+			return nil, err
+		}
+	}
 	query := `
-		INSERT INTO leads (id, org_id, name, email, phone, message, source)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		INSERT INTO leads (id, org_id, name, email, phone, message, source, phone_hash)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, NULLIF($8, ''))
 		RETURNING created_at
 	`
 	var createdAt time.Time
 	if err := r.pool.QueryRow(ctx, query,
 		id,
 		req.OrgID,
-		req.Name,
-		req.Email,
-		req.Phone,
+		sealed[0],
+		sealed[1],
+		sealed[2],
 		req.Message,
 		req.Source,
+		r.cipher.HashPhone(req.Phone),
 	).Scan(&createdAt); err != nil {
 		return nil, fmt.Errorf("leads: insert failed: %w", err)
 	}
@@ -61,8 +112,11 @@ func (r *PostgresRepository) Create(ctx context.Context, req *CreateLeadRequest)
 	}, nil
 }
 
-// GetByID fetches a lead scoped to the org.
-func (r *PostgresRepository) GetByID(ctx context.Context, orgID string, id string) (*Lead, error) {
+// GetByID fetches a lead inside scope. A lead in another org is not found.
+func (r *PostgresRepository) GetByID(ctx context.Context, scope tenancy.OrgScope, id string) (*Lead, error) {
+	if err := scope.Err(); err != nil {
+		return nil, err
+	}
 	query := `
 		SELECT id, org_id, name, email, phone, message, source, created_at,
 		       COALESCE(service_interest, '') as service_interest,
@@ -82,11 +136,31 @@ func (r *PostgresRepository) GetByID(ctx context.Context, orgID string, id strin
 		       COALESCE(booking_confirmation_number, '') as booking_confirmation_number,
 		       COALESCE(booking_handoff_url, '') as booking_handoff_url,
 		       booking_handoff_sent_at,
-		       booking_completed_at
+		       booking_completed_at,
+		       transactional_consent_at,
+		       marketing_consent,
+		       marketing_consent_at,
+		       COALESCE(marketing_consent_source, '') as marketing_consent_source,
+		       marketing_consent_asked_at,
+		       greeted_at,
+		       last_visit_at,
+		       imported_at,
+		       last_engaged_at,
+		       urgency_expressed_at,
+		       score,
+		       score_breakdown,
+		       scored_at,
+		       probable_wrong_number,
+		       COALESCE(extra_qualifications, '{}'::jsonb) as extra_qualifications
 		FROM leads
-		WHERE id = $1 AND org_id = $2
+		WHERE id = $1
 	`
-	row := r.pool.QueryRow(ctx, query, id, orgID)
+	args := []any{id}
+	if cond, condArgs := scope.Where("org_id", 2); cond != "" {
+		query += " AND " + cond
+		args = append(args, condArgs...)
+	}
+	row := r.pool.QueryRow(ctx, query, args...)
 	var lead Lead
 	if err := row.Scan(
 		&lead.ID,
@@ -115,12 +189,33 @@ func (r *PostgresRepository) GetByID(ctx context.Context, orgID string, id strin
 		&lead.BookingHandoffURL,
 		&lead.BookingHandoffSentAt,
 		&lead.BookingCompletedAt,
+		&lead.TransactionalConsentAt,
+		&lead.MarketingConsent,
+		&lead.MarketingConsentAt,
+		&lead.MarketingConsentSource,
+		&lead.MarketingConsentAskedAt,
+		&lead.GreetedAt,
+		&lead.LastVisitAt,
+		&lead.ImportedAt,
+		&lead.LastEngagedAt,
+		&lead.UrgencyExpressedAt,
+		&lead.Score,
+		&lead.ScoreBreakdown,
+		&lead.ScoredAt,
+		&lead.ProbableWrongNumber,
+		&lead.ExtraQualifications,
 	); err != nil {
 		if err == pgx.ErrNoRows {
 			return nil, ErrLeadNotFound
 		}
 		return nil, fmt.Errorf("leads: select failed: %w", err)
 	}
+	if err := scope.Check(lead.OrgID); err != nil {
+		return nil, fmt.Errorf("leads: get %s: %w", id, err)
+	}
+	if err := r.reveal(&lead); err != nil {
+		return nil, err
+	}
 	return &lead, nil
 }
 
@@ -145,7 +240,22 @@ func (r *PostgresRepository) GetByBookingSessionID(ctx context.Context, sessionI
 		       COALESCE(booking_confirmation_number, '') as booking_confirmation_number,
 		       COALESCE(booking_handoff_url, '') as booking_handoff_url,
 		       booking_handoff_sent_at,
-		       booking_completed_at
+		       booking_completed_at,
+		       transactional_consent_at,
+		       marketing_consent,
+		       marketing_consent_at,
+		       COALESCE(marketing_consent_source, '') as marketing_consent_source,
+		       marketing_consent_asked_at,
+		       greeted_at,
+		       last_visit_at,
+		       imported_at,
+		       last_engaged_at,
+		       urgency_expressed_at,
+		       score,
+		       score_breakdown,
+		       scored_at,
+		       probable_wrong_number,
+		       COALESCE(extra_qualifications, '{}'::jsonb) as extra_qualifications
 		FROM leads
 		WHERE booking_session_id = $1
 		LIMIT 1
@@ -179,22 +289,68 @@ func (r *PostgresRepository) GetByBookingSessionID(ctx context.Context, sessionI
 		&lead.BookingHandoffURL,
 		&lead.BookingHandoffSentAt,
 		&lead.BookingCompletedAt,
+		&lead.TransactionalConsentAt,
+		&lead.MarketingConsent,
+		&lead.MarketingConsentAt,
+		&lead.MarketingConsentSource,
+		&lead.MarketingConsentAskedAt,
+		&lead.GreetedAt,
+		&lead.LastVisitAt,
+		&lead.ImportedAt,
+		&lead.LastEngagedAt,
+		&lead.UrgencyExpressedAt,
+		&lead.Score,
+		&lead.ScoreBreakdown,
+		&lead.ScoredAt,
+		&lead.ProbableWrongNumber,
+		&lead.ExtraQualifications,
 	); err != nil {
 		if err == pgx.ErrNoRows {
 			return nil, ErrLeadNotFound
 		}
 		return nil, fmt.Errorf("leads: select by booking session failed: %w", err)
 	}
+	if err := r.reveal(&lead); err != nil {
+		return nil, err
+	}
 	return &lead, nil
 }
 
 // GetOrCreateByPhone finds the most recent lead for an org/phone or creates a new one.
 func (r *PostgresRepository) GetOrCreateByPhone(ctx context.Context, orgID string, phone string, source string, defaultName string) (*Lead, error) {
-	phone = strings.TrimSpace(phone)
+	phone = CanonicalPhone(phone)
 	orgID = strings.TrimSpace(orgID)
 	if phone == "" || orgID == "" {
 		return nil, fmt.Errorf("leads: org and phone are required")
 	}
+	lead, err := r.findByPhone(ctx, r.pool, orgID, phone, false)
+	if err == nil {
+		return lead, nil
+	}
+	if !errors.Is(err, ErrLeadNotFound) {
+		return nil, err
+	}
+
+	// Use defaultName as-is; if empty, keep it empty - name will be extracted from conversation
+	// Notification service handles empty names by showing "A patient"
+	name := strings.TrimSpace(defaultName)
+	req := &CreateLeadRequest{
+		OrgID:  orgID,
+		Name:   name,
+		Phone:  phone,
+		Source: source,
+	}
+	return r.Create(ctx, req)
+}
+
+// rowQuerier is satisfied by both the pool and a transaction.
+type rowQuerier interface {
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+// findByPhone returns the org's most recent lead for a canonical phone, or
+// ErrLeadNotFound. forUpdate locks the row for the caller's transaction.
+func (r *PostgresRepository) findByPhone(ctx context.Context, q rowQuerier, orgID string, phone string, forUpdate bool) (*Lead, error) {
 	query := `
 		SELECT id, org_id, name, email, phone, message, source, created_at,
 		       COALESCE(service_interest, '') as service_interest,
@@ -214,14 +370,32 @@ func (r *PostgresRepository) GetOrCreateByPhone(ctx context.Context, orgID strin
 		       COALESCE(booking_confirmation_number, '') as booking_confirmation_number,
 		       COALESCE(booking_handoff_url, '') as booking_handoff_url,
 		       booking_handoff_sent_at,
-		       booking_completed_at
+		       booking_completed_at,
+		       transactional_consent_at,
+		       marketing_consent,
+		       marketing_consent_at,
+		       COALESCE(marketing_consent_source, '') as marketing_consent_source,
+		       marketing_consent_asked_at,
+		       greeted_at,
+		       last_visit_at,
+		       imported_at,
+		       last_engaged_at,
+		       urgency_expressed_at,
+		       score,
+		       score_breakdown,
+		       scored_at,
+		       probable_wrong_number,
+		       COALESCE(extra_qualifications, '{}'::jsonb) as extra_qualifications
 		FROM leads
-		WHERE org_id = $1 AND phone = $2
+		WHERE org_id = $1 AND (phone = $2 OR phone_hash = $3)
 		ORDER BY created_at DESC
 		LIMIT 1
 	`
+	if forUpdate {
+		query += " FOR UPDATE"
+	}
 	var lead Lead
-	if err := r.pool.QueryRow(ctx, query, orgID, phone).Scan(
+	if err := q.QueryRow(ctx, query, orgID, phone, r.cipher.HashPhone(phone)).Scan(
 		&lead.ID,
 		&lead.OrgID,
 		&lead.Name,
@@ -248,26 +422,35 @@ func (r *PostgresRepository) GetOrCreateByPhone(ctx context.Context, orgID strin
 		&lead.BookingHandoffURL,
 		&lead.BookingHandoffSentAt,
 		&lead.BookingCompletedAt,
+		&lead.TransactionalConsentAt,
+		&lead.MarketingConsent,
+		&lead.MarketingConsentAt,
+		&lead.MarketingConsentSource,
+		&lead.MarketingConsentAskedAt,
+		&lead.GreetedAt,
+		&lead.LastVisitAt,
+		&lead.ImportedAt,
+		&lead.LastEngagedAt,
+		&lead.UrgencyExpressedAt,
+		&lead.Score,
+		&lead.ScoreBreakdown,
+		&lead.ScoredAt,
+		&lead.ProbableWrongNumber,
+		&lead.ExtraQualifications,
 	); err == nil {
+		if err := r.reveal(&lead); err != nil {
+			return nil, err
+		}
 		return &lead, nil
 	} else if err != pgx.ErrNoRows {
 		return nil, fmt.Errorf("leads: lookup by phone failed: %w", err)
 	}
+	return nil, ErrLeadNotFound
 
-	// Use defaultName as-is; if empty, keep it empty - name will be extracted from conversation
-	// Notification service handles empty names by showing "A patient"
-	name := strings.TrimSpace(defaultName)
-	req := &CreateLeadRequest{
-		OrgID:  orgID,
-		Name:   name,
-		Phone:  phone,
-		Source: source,
-	}
-	return r.Create(ctx, req)
 }
 
 // UpdateSchedulingPreferences updates a lead's scheduling preferences
-func (r *PostgresRepository) UpdateSchedulingPreferences(ctx context.Context, leadID string, prefs SchedulingPreferences) error {
+func (r *PostgresRepository) UpdateSchedulingPreferences(ctx context.Context, scope tenancy.OrgScope, leadID string, prefs SchedulingPreferences) error {
 	// Build dynamic query - only update fields if provided (don't overwrite with empty)
 	query := `
 		UPDATE leads
@@ -280,7 +463,11 @@ func (r *PostgresRepository) UpdateSchedulingPreferences(ctx context.Context, le
 		    name = COALESCE(NULLIF($8, ''), name)
 		WHERE id = $1
 	`
-	result, err := r.pool.Exec(ctx, query,
+	name, err := r.seal(prefs.Name)
+	if err != nil {
+		return err
+	}
+	query, args, err := scopeUpdate(query, scope, []any{
 		leadID,
 		prefs.ServiceInterest,
 		prefs.PatientType,
@@ -288,8 +475,12 @@ func (r *PostgresRepository) UpdateSchedulingPreferences(ctx context.Context, le
 		prefs.PreferredDays,
 		prefs.PreferredTimes,
 		prefs.Notes,
-		prefs.Name,
-	)
+		name,
+	})
+	if err != nil {
+		return err
+	}
+	result, err := r.pool.Exec(ctx, query, args...)
 	if err != nil {
 		return fmt.Errorf("leads: update preferences failed: %w", err)
 	}
@@ -299,15 +490,132 @@ func (r *PostgresRepository) UpdateSchedulingPreferences(ctx context.Context, le
 	return nil
 }
 
+// MergePreferences applies the non-empty fields of patch. The merge rules
+// live in the UPDATE itself, so two workers saving the same lead at once each
+// land their fields without a read-modify-write race: blanks never overwrite,
+// and a single-word name never replaces a multi-word one. An encrypted name
+// can't be compared in SQL, so with a cipher set the name goes through
+// mergeSealedName instead.
+func (r *PostgresRepository) MergePreferences(ctx context.Context, leadID string, patch SchedulingPreferences) error {
+	query := `
+		UPDATE leads
+		SET service_interest = COALESCE(NULLIF($2, ''), service_interest),
+		    patient_type = COALESCE(NULLIF($3, ''), patient_type),
+		    past_services = COALESCE(NULLIF($4, ''), past_services),
+		    preferred_days = COALESCE(NULLIF($5, ''), preferred_days),
+		    preferred_times = COALESCE(NULLIF($6, ''), preferred_times),
+		    scheduling_notes = COALESCE(NULLIF($7, ''), scheduling_notes),
+		    name = CASE
+		        WHEN $8 = '' THEN name
+		        WHEN btrim(COALESCE(name, '')) ~ '\s' AND $8 !~ '\s' THEN name
+		        ELSE $8
+		    END,
+		    extra_qualifications = CASE
+		        WHEN $9 = '' THEN extra_qualifications
+		        ELSE COALESCE(extra_qualifications, '{}'::jsonb) || $9::jsonb
+		    END
+		WHERE id = $1
+	`
+	extraQuals, err := extraQualificationsPatch(patch.ExtraQualifications)
+	if err != nil {
+		return fmt.Errorf("leads: merge preferences failed: %w", err)
+	}
+	name := strings.TrimSpace(patch.Name)
+	sealedName := ""
+	if r.cipher.Enabled() {
+		sealedName, name = name, ""
+	}
+	result, err := r.pool.Exec(ctx, query,
+		leadID,
+		strings.TrimSpace(patch.ServiceInterest),
+		strings.TrimSpace(patch.PatientType),
+		strings.TrimSpace(patch.PastServices),
+		strings.TrimSpace(patch.PreferredDays),
+		strings.TrimSpace(patch.PreferredTimes),
+		strings.TrimSpace(patch.Notes),
+		name,
+		extraQuals,
+	)
+	if err != nil {
+		return fmt.Errorf("leads: merge preferences failed: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return ErrLeadNotFound
+	}
+	if sealedName != "" {
+		return r.mergeSealedName(ctx, leadID, sealedName)
+	}
+	return nil
+}
+
+// mergeSealedName applies mergeName to an encrypted name column. The UPDATE
+// only lands if the stored ciphertext is unchanged since it was read, so a
+// concurrent save is re-read and merged rather than overwritten.
+func (r *PostgresRepository) mergeSealedName(ctx context.Context, leadID string, candidate string) error {
+	for attempt := 0; attempt < 3; attempt++ {
+		var stored string
+		if err := r.pool.QueryRow(ctx, `SELECT COALESCE(name, '') FROM leads WHERE id = $1`, leadID).Scan(&stored); err != nil {
+			if err == pgx.ErrNoRows {
+				return ErrLeadNotFound
+			}
+			return fmt.Errorf("leads: merge name: %w", err)
+		}
+		existing, err := r.cipher.Decrypt(stored)
+		if err != nil {
+			return fmt.Errorf("leads: merge name: %w", err)
+		}
+		merged := mergeName(existing, candidate)
+		if merged == existing {
+			return nil
+		}
+		sealed, err := r.seal(merged)
+		if err != nil {
+			return err
+		}
+		result, err := r.pool.Exec(ctx, `UPDATE leads SET name = $2 WHERE id = $1 AND COALESCE(name, '') = $3`, leadID, sealed, stored)
+		if err != nil {
+			return fmt.Errorf("leads: merge name: %w", err)
+		}
+		if result.RowsAffected() > 0 {
+			return nil
+		}
+	}
+	return fmt.Errorf("leads: merge name: lead %s kept changing", leadID)
+}
+
+// extraQualificationsPatch encodes the non-blank answers in quals as a JSON
+// object for MergePreferences, or "" when there are none.
+func extraQualificationsPatch(quals map[string]string) (string, error) {
+	answers := make(map[string]string, len(quals))
+	for key, answer := range quals {
+		key, answer = strings.TrimSpace(key), strings.TrimSpace(answer)
+		if key != "" && answer != "" {
+			answers[key] = answer
+		}
+	}
+	if len(answers) == 0 {
+		return "", nil
+	}
+	raw, err := json.Marshal(answers)
+	if err != nil {
+		return "", err
+	}
+	return string(raw), nil
+}
+
 // UpdateDepositStatus updates a lead's deposit status and priority level
-func (r *PostgresRepository) UpdateDepositStatus(ctx context.Context, leadID string, status string, priority string) error {
+func (r *PostgresRepository) UpdateDepositStatus(ctx context.Context, scope tenancy.OrgScope, leadID string, status string, priority string) error {
 	query := `
 		UPDATE leads
 		SET deposit_status = $2,
 		    priority_level = $3
 		WHERE id = $1
 	`
-	result, err := r.pool.Exec(ctx, query, leadID, status, priority)
+	query, args, err := scopeUpdate(query, scope, []any{leadID, status, priority})
+	if err != nil {
+		return err
+	}
+	result, err := r.pool.Exec(ctx, query, args...)
 	if err != nil {
 		return fmt.Errorf("leads: update deposit status failed: %w", err)
 	}
@@ -318,7 +626,7 @@ func (r *PostgresRepository) UpdateDepositStatus(ctx context.Context, leadID str
 }
 
 // UpdateSelectedAppointment updates a lead's selected appointment time and service
-func (r *PostgresRepository) UpdateSelectedAppointment(ctx context.Context, leadID string, appt SelectedAppointment) error {
+func (r *PostgresRepository) UpdateSelectedAppointment(ctx context.Context, scope tenancy.OrgScope, leadID string, appt SelectedAppointment) error {
 	query := `
 		UPDATE leads
 		SET selected_datetime = $2,
@@ -326,7 +634,11 @@ func (r *PostgresRepository) UpdateSelectedAppointment(ctx context.Context, lead
 		    selected_service = COALESCE(NULLIF($4, ''), selected_service)
 		WHERE id = $1
 	`
-	result, err := r.pool.Exec(ctx, query, leadID, appt.DateTime, appt.EndDateTime, appt.Service)
+	query, args, err := scopeUpdate(query, scope, []any{leadID, appt.DateTime, appt.EndDateTime, appt.Service})
+	if err != nil {
+		return err
+	}
+	result, err := r.pool.Exec(ctx, query, args...)
 	if err != nil {
 		return fmt.Errorf("leads: update selected appointment failed: %w", err)
 	}
@@ -347,8 +659,11 @@ func (r *PostgresRepository) ClearSelectedAppointment(ctx context.Context, leadI
 	return nil
 }
 
-// ListByOrg retrieves leads for an organization with optional filtering
-func (r *PostgresRepository) ListByOrg(ctx context.Context, orgID string, filter ListLeadsFilter) ([]*Lead, error) {
+// ListByOrg retrieves the leads inside scope with optional filtering
+func (r *PostgresRepository) ListByOrg(ctx context.Context, scope tenancy.OrgScope, filter ListLeadsFilter) ([]*Lead, error) {
+	if err := scope.Err(); err != nil {
+		return nil, err
+	}
 	// Build query with optional filter
 	query := `
 		SELECT id, org_id, name, email, phone, message, source, created_at,
@@ -369,12 +684,32 @@ func (r *PostgresRepository) ListByOrg(ctx context.Context, orgID string, filter
 		       COALESCE(booking_confirmation_number, '') as booking_confirmation_number,
 		       COALESCE(booking_handoff_url, '') as booking_handoff_url,
 		       booking_handoff_sent_at,
-		       booking_completed_at
+		       booking_completed_at,
+		       transactional_consent_at,
+		       marketing_consent,
+		       marketing_consent_at,
+		       COALESCE(marketing_consent_source, '') as marketing_consent_source,
+		       marketing_consent_asked_at,
+		       greeted_at,
+		       last_visit_at,
+		       imported_at,
+		       last_engaged_at,
+		       urgency_expressed_at,
+		       score,
+		       score_breakdown,
+		       scored_at,
+		       probable_wrong_number,
+		       COALESCE(extra_qualifications, '{}'::jsonb) as extra_qualifications
 		FROM leads
-		WHERE org_id = $1
+		WHERE 1=1
 	`
-	args := []any{orgID}
-	argNum := 2
+	var args []any
+	argNum := 1
+	if cond, condArgs := scope.Where("org_id", argNum); cond != "" {
+		query += " AND " + cond
+		args = append(args, condArgs...)
+		argNum++
+	}
 
 	if filter.DepositStatus != "" {
 		query += fmt.Sprintf(" AND deposit_status = $%d", argNum)
@@ -382,7 +717,11 @@ func (r *PostgresRepository) ListByOrg(ctx context.Context, orgID string, filter
 		argNum++
 	}
 
-	query += " ORDER BY created_at DESC"
+	if filter.Sort == ListSortScore {
+		query += " ORDER BY " + liveScoreSQL + " DESC, created_at DESC"
+	} else {
+		query += " ORDER BY created_at DESC"
+	}
 
 	limit := filter.Limit
 	if limit <= 0 {
@@ -402,6 +741,7 @@ func (r *PostgresRepository) ListByOrg(ctx context.Context, orgID string, filter
 		return nil, fmt.Errorf("leads: list query failed: %w", err)
 	}
 	defer rows.Close()
+	now := time.Now()
 
 	var results []*Lead
 	for rows.Next() {
@@ -433,9 +773,28 @@ func (r *PostgresRepository) ListByOrg(ctx context.Context, orgID string, filter
 			&lead.BookingHandoffURL,
 			&lead.BookingHandoffSentAt,
 			&lead.BookingCompletedAt,
+			&lead.TransactionalConsentAt,
+			&lead.MarketingConsent,
+			&lead.MarketingConsentAt,
+			&lead.MarketingConsentSource,
+			&lead.MarketingConsentAskedAt,
+			&lead.GreetedAt,
+			&lead.LastVisitAt,
+			&lead.ImportedAt,
+			&lead.LastEngagedAt,
+			&lead.UrgencyExpressedAt,
+			&lead.Score,
+			&lead.ScoreBreakdown,
+			&lead.ScoredAt,
+			&lead.ProbableWrongNumber,
+			&lead.ExtraQualifications,
 		); err != nil {
 			return nil, fmt.Errorf("leads: scan failed: %w", err)
 		}
+		if err := r.reveal(&lead); err != nil {
+			return nil, err
+		}
+		lead.RefreshScore(now)
 		results = append(results, &lead)
 	}
 
@@ -447,9 +806,16 @@ func (r *PostgresRepository) ListByOrg(ctx context.Context, orgID string, filter
 }
 
 // UpdateEmail updates a lead's email address. Empty strings are ignored (COALESCE).
-func (r *PostgresRepository) UpdateEmail(ctx context.Context, leadID string, email string) error {
-	query := `UPDATE leads SET email = COALESCE(NULLIF($2, ''), email) WHERE id = $1`
-	result, err := r.pool.Exec(ctx, query, leadID, email)
+func (r *PostgresRepository) UpdateEmail(ctx context.Context, scope tenancy.OrgScope, leadID string, email string) error {
+	email, err := r.seal(email)
+	if err != nil {
+		return err
+	}
+	query, args, err := scopeUpdate(`UPDATE leads SET email = COALESCE(NULLIF($2, ''), email) WHERE id = $1`, scope, []any{leadID, email})
+	if err != nil {
+		return err
+	}
+	result, err := r.pool.Exec(ctx, query, args...)
 	if err != nil {
 		return fmt.Errorf("leads: update email: %w", err)
 	}
@@ -460,7 +826,7 @@ func (r *PostgresRepository) UpdateEmail(ctx context.Context, leadID string, ema
 }
 
 // UpdateBookingSession updates a lead's booking session state
-func (r *PostgresRepository) UpdateBookingSession(ctx context.Context, leadID string, update BookingSessionUpdate) error {
+func (r *PostgresRepository) UpdateBookingSession(ctx context.Context, scope tenancy.OrgScope, leadID string, update BookingSessionUpdate) error {
 	query := `
 		UPDATE leads
 		SET booking_session_id = COALESCE(NULLIF($2, ''), booking_session_id),
@@ -472,7 +838,7 @@ func (r *PostgresRepository) UpdateBookingSession(ctx context.Context, leadID st
 		    booking_completed_at = COALESCE($8, booking_completed_at)
 		WHERE id = $1
 	`
-	result, err := r.pool.Exec(ctx, query,
+	query, args, err := scopeUpdate(query, scope, []any{
 		leadID,
 		update.SessionID,
 		update.Platform,
@@ -481,7 +847,11 @@ func (r *PostgresRepository) UpdateBookingSession(ctx context.Context, leadID st
 		update.HandoffURL,
 		update.HandoffSentAt,
 		update.CompletedAt,
-	)
+	})
+	if err != nil {
+		return err
+	}
+	result, err := r.pool.Exec(ctx, query, args...)
 	if err != nil {
 		return fmt.Errorf("leads: update booking session failed: %w", err)
 	}
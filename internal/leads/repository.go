@@ -7,6 +7,8 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/tenancy"
 )
 
 // SchedulingPreferences captures the customer's availability preferences from conversation
@@ -19,6 +21,10 @@ type SchedulingPreferences struct {
 	PreferredTimes     string // e.g., "morning", "afternoon", "evening"
 	ProviderPreference string // e.g., "Brandi Sesock", "no preference", "" (not yet asked)
 	Notes              string // free-form notes from conversation
+	BackToBack         bool   // patient asked for a time right before or after an existing appointment
+	// ExtraQualifications holds answers to the clinic's service-specific
+	// questions, keyed by the question's key, e.g. {"area": "Underarms"}.
+	ExtraQualifications map[string]string
 }
 
 // SelectedAppointment captures the specific time slot selected by the lead
@@ -31,10 +37,17 @@ type SelectedAppointment struct {
 // ListLeadsFilter defines filtering options for listing leads
 type ListLeadsFilter struct {
 	DepositStatus string // "pending", "paid", "failed", or "" for all
+	Sort          string // ListSortNewest (default) or ListSortScore
 	Limit         int    // max results, default 50
 	Offset        int    // pagination offset
 }
 
+// Lead list orders.
+const (
+	ListSortNewest = "newest" // created_at descending
+	ListSortScore  = "score"  // highest score first, then newest
+)
+
 // BookingSessionUpdate contains booking session fields to update
 type BookingSessionUpdate struct {
 	SessionID          string
@@ -49,16 +62,17 @@ type BookingSessionUpdate struct {
 // Repository defines the interface for lead storage
 type Repository interface {
 	Create(ctx context.Context, req *CreateLeadRequest) (*Lead, error)
-	GetByID(ctx context.Context, orgID string, id string) (*Lead, error)
+	GetByID(ctx context.Context, scope tenancy.OrgScope, id string) (*Lead, error)
 	GetOrCreateByPhone(ctx context.Context, orgID string, phone string, source string, defaultName string) (*Lead, error)
 	GetByBookingSessionID(ctx context.Context, sessionID string) (*Lead, error)
-	UpdateSchedulingPreferences(ctx context.Context, leadID string, prefs SchedulingPreferences) error
-	UpdateSelectedAppointment(ctx context.Context, leadID string, appt SelectedAppointment) error
-	UpdateDepositStatus(ctx context.Context, leadID string, status string, priority string) error
-	UpdateBookingSession(ctx context.Context, leadID string, update BookingSessionUpdate) error
-	UpdateEmail(ctx context.Context, leadID string, email string) error
+	UpdateSchedulingPreferences(ctx context.Context, scope tenancy.OrgScope, leadID string, prefs SchedulingPreferences) error
+	MergePreferences(ctx context.Context, leadID string, patch SchedulingPreferences) error
+	UpdateSelectedAppointment(ctx context.Context, scope tenancy.OrgScope, leadID string, appt SelectedAppointment) error
+	UpdateDepositStatus(ctx context.Context, scope tenancy.OrgScope, leadID string, status string, priority string) error
+	UpdateBookingSession(ctx context.Context, scope tenancy.OrgScope, leadID string, update BookingSessionUpdate) error
+	UpdateEmail(ctx context.Context, scope tenancy.OrgScope, leadID string, email string) error
 	ClearSelectedAppointment(ctx context.Context, leadID string) error
-	ListByOrg(ctx context.Context, orgID string, filter ListLeadsFilter) ([]*Lead, error)
+	ListByOrg(ctx context.Context, scope tenancy.OrgScope, filter ListLeadsFilter) ([]*Lead, error)
 }
 
 // InMemoryRepository is a stub implementation of Repository using in-memory storage
@@ -79,6 +93,7 @@ func (r *InMemoryRepository) Create(ctx context.Context, req *CreateLeadRequest)
 	if err := req.Validate(); err != nil {
 		return nil, err
 	}
+	req.Phone = CanonicalPhone(req.Phone)
 
 	lead := &Lead{
 		ID:        uuid.New().String(),
@@ -98,13 +113,16 @@ func (r *InMemoryRepository) Create(ctx context.Context, req *CreateLeadRequest)
 	return lead, nil
 }
 
-// GetByID retrieves a lead by ID
-func (r *InMemoryRepository) GetByID(ctx context.Context, orgID string, id string) (*Lead, error) {
+// GetByID retrieves a lead by ID inside scope
+func (r *InMemoryRepository) GetByID(ctx context.Context, scope tenancy.OrgScope, id string) (*Lead, error) {
+	if err := scope.Err(); err != nil {
+		return nil, err
+	}
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
 	lead, ok := r.leads[id]
-	if !ok || lead.OrgID != orgID {
+	if !ok || !scope.Allows(lead.OrgID) {
 		return nil, ErrLeadNotFound
 	}
 
@@ -129,15 +147,8 @@ func (r *InMemoryRepository) GetOrCreateByPhone(ctx context.Context, orgID strin
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
-	var latest *Lead
-	for _, l := range r.leads {
-		if l.OrgID == orgID && l.Phone == phone {
-			if latest == nil || l.CreatedAt.After(latest.CreatedAt) {
-				latest = l
-			}
-		}
-	}
-	if latest != nil {
+	phone = CanonicalPhone(phone)
+	if latest := r.latestByPhone(orgID, phone); latest != nil {
 		return latest, nil
 	}
 	// Use defaultName as-is; if empty, keep it empty - name will be extracted from conversation
@@ -166,14 +177,41 @@ func (r *InMemoryRepository) GetOrCreateByPhone(ctx context.Context, orgID strin
 	return lead, nil
 }
 
+// latestByPhone returns the org's most recently created lead for a
+// canonical phone, or nil. Callers hold r.mu.
+func (r *InMemoryRepository) latestByPhone(orgID string, phone string) *Lead {
+	var latest *Lead
+	for _, l := range r.leads {
+		if l.OrgID == orgID && CanonicalPhone(l.Phone) == phone {
+			if latest == nil || l.CreatedAt.After(latest.CreatedAt) {
+				latest = l
+			}
+		}
+	}
+	return latest
+}
+
+// scopedLead returns the stored lead with leadID when it is inside scope.
+// Callers hold r.mu.
+func (r *InMemoryRepository) scopedLead(scope tenancy.OrgScope, leadID string) (*Lead, error) {
+	if err := scope.Err(); err != nil {
+		return nil, err
+	}
+	lead, ok := r.leads[leadID]
+	if !ok || !scope.Allows(lead.OrgID) {
+		return nil, ErrLeadNotFound
+	}
+	return lead, nil
+}
+
 // UpdateSchedulingPreferences updates a lead's scheduling preferences
-func (r *InMemoryRepository) UpdateSchedulingPreferences(ctx context.Context, leadID string, prefs SchedulingPreferences) error {
+func (r *InMemoryRepository) UpdateSchedulingPreferences(ctx context.Context, scope tenancy.OrgScope, leadID string, prefs SchedulingPreferences) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
-	lead, ok := r.leads[leadID]
-	if !ok {
-		return ErrLeadNotFound
+	lead, err := r.scopedLead(scope, leadID)
+	if err != nil {
+		return err
 	}
 
 	// Only update fields if provided (don't overwrite with empty)
@@ -201,8 +239,9 @@ func (r *InMemoryRepository) UpdateSchedulingPreferences(ctx context.Context, le
 	return nil
 }
 
-// UpdateSelectedAppointment updates a lead's selected appointment time
-func (r *InMemoryRepository) UpdateSelectedAppointment(ctx context.Context, leadID string, appt SelectedAppointment) error {
+// MergePreferences applies the non-empty fields of patch, never blanking a
+// saved field and never replacing a multi-word name with a single word.
+func (r *InMemoryRepository) MergePreferences(ctx context.Context, leadID string, patch SchedulingPreferences) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
@@ -210,6 +249,55 @@ func (r *InMemoryRepository) UpdateSelectedAppointment(ctx context.Context, lead
 	if !ok {
 		return ErrLeadNotFound
 	}
+	lead.Name = mergeName(lead.Name, patch.Name)
+	mergeField(&lead.ServiceInterest, patch.ServiceInterest)
+	mergeField(&lead.PatientType, patch.PatientType)
+	mergeField(&lead.PastServices, patch.PastServices)
+	mergeField(&lead.PreferredDays, patch.PreferredDays)
+	mergeField(&lead.PreferredTimes, patch.PreferredTimes)
+	mergeField(&lead.SchedulingNotes, patch.Notes)
+	for key, answer := range patch.ExtraQualifications {
+		key, answer = strings.TrimSpace(key), strings.TrimSpace(answer)
+		if key == "" || answer == "" {
+			continue
+		}
+		if lead.ExtraQualifications == nil {
+			lead.ExtraQualifications = make(map[string]string)
+		}
+		lead.ExtraQualifications[key] = answer
+	}
+	return nil
+}
+
+func mergeField(dst *string, value string) {
+	if value = strings.TrimSpace(value); value != "" {
+		*dst = value
+	}
+}
+
+// mergeName returns the name to keep when an extraction pass finds
+// candidate for a lead already named existing. A blank candidate, or a
+// single-word guess against a saved full name, keeps existing.
+func mergeName(existing, candidate string) string {
+	candidate = strings.TrimSpace(candidate)
+	if candidate == "" {
+		return existing
+	}
+	if len(strings.Fields(existing)) > 1 && len(strings.Fields(candidate)) == 1 {
+		return existing
+	}
+	return candidate
+}
+
+// UpdateSelectedAppointment updates a lead's selected appointment time
+func (r *InMemoryRepository) UpdateSelectedAppointment(ctx context.Context, scope tenancy.OrgScope, leadID string, appt SelectedAppointment) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	lead, err := r.scopedLead(scope, leadID)
+	if err != nil {
+		return err
+	}
 
 	lead.SelectedDateTime = appt.DateTime
 	lead.SelectedEndDateTime = appt.EndDateTime
@@ -220,13 +308,13 @@ func (r *InMemoryRepository) UpdateSelectedAppointment(ctx context.Context, lead
 }
 
 // UpdateDepositStatus updates a lead's deposit status and priority
-func (r *InMemoryRepository) UpdateDepositStatus(ctx context.Context, leadID string, status string, priority string) error {
+func (r *InMemoryRepository) UpdateDepositStatus(ctx context.Context, scope tenancy.OrgScope, leadID string, status string, priority string) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
-	lead, ok := r.leads[leadID]
-	if !ok {
-		return ErrLeadNotFound
+	lead, err := r.scopedLead(scope, leadID)
+	if err != nil {
+		return err
 	}
 
 	lead.DepositStatus = status
@@ -235,16 +323,16 @@ func (r *InMemoryRepository) UpdateDepositStatus(ctx context.Context, leadID str
 }
 
 // UpdateEmail updates a lead's email address. Empty strings are ignored.
-func (r *InMemoryRepository) UpdateEmail(ctx context.Context, leadID string, email string) error {
+func (r *InMemoryRepository) UpdateEmail(ctx context.Context, scope tenancy.OrgScope, leadID string, email string) error {
 	if email == "" {
 		return nil
 	}
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
-	lead, ok := r.leads[leadID]
-	if !ok {
-		return ErrLeadNotFound
+	lead, err := r.scopedLead(scope, leadID)
+	if err != nil {
+		return err
 	}
 	lead.Email = email
 	return nil
@@ -265,13 +353,13 @@ func (r *InMemoryRepository) ClearSelectedAppointment(ctx context.Context, leadI
 }
 
 // UpdateBookingSession updates a lead's booking session state
-func (r *InMemoryRepository) UpdateBookingSession(ctx context.Context, leadID string, update BookingSessionUpdate) error {
+func (r *InMemoryRepository) UpdateBookingSession(ctx context.Context, scope tenancy.OrgScope, leadID string, update BookingSessionUpdate) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
-	lead, ok := r.leads[leadID]
-	if !ok {
-		return ErrLeadNotFound
+	lead, err := r.scopedLead(scope, leadID)
+	if err != nil {
+		return err
 	}
 
 	if update.SessionID != "" {
@@ -298,26 +386,38 @@ func (r *InMemoryRepository) UpdateBookingSession(ctx context.Context, leadID st
 	return nil
 }
 
-// ListByOrg retrieves leads for an organization with optional filtering
-func (r *InMemoryRepository) ListByOrg(ctx context.Context, orgID string, filter ListLeadsFilter) ([]*Lead, error) {
+// ListByOrg retrieves the leads inside scope with optional filtering
+func (r *InMemoryRepository) ListByOrg(ctx context.Context, scope tenancy.OrgScope, filter ListLeadsFilter) ([]*Lead, error) {
+	if err := scope.Err(); err != nil {
+		return nil, err
+	}
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
+	now := time.Now()
 	var results []*Lead
 	for _, l := range r.leads {
-		if l.OrgID != orgID {
+		if !scope.Allows(l.OrgID) {
 			continue
 		}
 		if filter.DepositStatus != "" && l.DepositStatus != filter.DepositStatus {
 			continue
 		}
-		results = append(results, l)
+		lead := *l
+		lead.RefreshScore(now)
+		results = append(results, &lead)
 	}
 
-	// Sort by created_at descending (newest first)
+	// Sort by created_at descending (newest first), or by score first
+	before := func(a, b *Lead) bool {
+		if filter.Sort == ListSortScore && a.Score != b.Score {
+			return a.Score > b.Score
+		}
+		return a.CreatedAt.After(b.CreatedAt)
+	}
 	for i := 0; i < len(results)-1; i++ {
 		for j := i + 1; j < len(results); j++ {
-			if results[j].CreatedAt.After(results[i].CreatedAt) {
+			if before(results[j], results[i]) {
 				results[i], results[j] = results[j], results[i]
 			}
 		}
@@ -339,3 +439,23 @@ func (r *InMemoryRepository) ListByOrg(ctx context.Context, orgID string, filter
 
 	return results, nil
 }
+
+// DeleteByPhone removes every lead for the phone inside scope, mirroring the
+// admin phone purge for the in-memory store. It returns the number removed.
+func (r *InMemoryRepository) DeleteByPhone(ctx context.Context, scope tenancy.OrgScope, phone string) (int, error) {
+	if err := scope.Err(); err != nil {
+		return 0, err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	phone = CanonicalPhone(phone)
+	removed := 0
+	for id, l := range r.leads {
+		if scope.Allows(l.OrgID) && CanonicalPhone(l.Phone) == phone {
+			delete(r.leads, id)
+			removed++
+		}
+	}
+	return removed, nil
+}
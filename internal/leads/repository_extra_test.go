@@ -2,8 +2,12 @@ package leads
 
 import (
 	"context"
+	"errors"
+	"sync"
 	"testing"
 	"time"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/tenancy"
 )
 
 func TestInMemoryRepository_GetOrCreateByPhone(t *testing.T) {
@@ -38,6 +42,55 @@ func TestInMemoryRepository_GetOrCreateByPhone(t *testing.T) {
 	}
 }
 
+func TestInMemoryRepository_GetOrCreateByPhoneMatchesFormatVariants(t *testing.T) {
+	repo := NewInMemoryRepository()
+	ctx := context.Background()
+
+	first, err := repo.GetOrCreateByPhone(ctx, "org-1", "(500) 555-0002", "sms", "Jane")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if first.Phone != "+15005550002" {
+		t.Fatalf("Phone = %q, want canonical E.164", first.Phone)
+	}
+	for _, variant := range []string{"+15005550002", "5005550002", "1-500-555-0002"} {
+		got, err := repo.GetOrCreateByPhone(ctx, "org-1", variant, "sms", "")
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", variant, err)
+		}
+		if got.ID != first.ID {
+			t.Errorf("%s: expected the existing lead", variant)
+		}
+	}
+}
+
+func TestInMemoryRepository_DeleteByPhone(t *testing.T) {
+	repo := NewInMemoryRepository()
+	ctx := context.Background()
+
+	if _, err := repo.GetOrCreateByPhone(ctx, "org-1", "+15005550002", "sms", "Jane"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	other, err := repo.GetOrCreateByPhone(ctx, "org-2", "+15005550002", "sms", "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	removed, err := repo.DeleteByPhone(ctx, tenancy.ForOrg("org-1"), "(500) 555-0002")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if removed != 1 {
+		t.Fatalf("removed = %d, want 1", removed)
+	}
+	if list, _ := repo.ListByOrg(ctx, tenancy.ForOrg("org-1"), ListLeadsFilter{}); len(list) != 0 {
+		t.Fatalf("expected org-1 lead to be gone, got %d", len(list))
+	}
+	if _, err := repo.GetByID(ctx, tenancy.ForOrg("org-2"), other.ID); err != nil {
+		t.Fatalf("expected other org's lead to survive: %v", err)
+	}
+}
+
 func TestInMemoryRepository_GetByBookingSessionID(t *testing.T) {
 	repo := NewInMemoryRepository()
 	ctx := context.Background()
@@ -47,7 +100,7 @@ func TestInMemoryRepository_GetByBookingSessionID(t *testing.T) {
 	})
 
 	// Set booking session
-	_ = repo.UpdateBookingSession(ctx, lead.ID, BookingSessionUpdate{SessionID: "sess-123"})
+	_ = repo.UpdateBookingSession(ctx, tenancy.ForOrg(lead.OrgID), lead.ID, BookingSessionUpdate{SessionID: "sess-123"})
 
 	// Find it
 	found, err := repo.GetByBookingSessionID(ctx, "sess-123")
@@ -73,7 +126,7 @@ func TestInMemoryRepository_UpdateSchedulingPreferences(t *testing.T) {
 		OrgID: "org-1", Name: "Test", Phone: "+11234567890", Source: "web",
 	})
 
-	err := repo.UpdateSchedulingPreferences(ctx, lead.ID, SchedulingPreferences{
+	err := repo.UpdateSchedulingPreferences(ctx, tenancy.ForOrg(lead.OrgID), lead.ID, SchedulingPreferences{
 		Name:            "Updated Name",
 		ServiceInterest: "Botox",
 		PatientType:     "new",
@@ -86,7 +139,7 @@ func TestInMemoryRepository_UpdateSchedulingPreferences(t *testing.T) {
 		t.Fatalf("unexpected error: %v", err)
 	}
 
-	updated, _ := repo.GetByID(ctx, "org-1", lead.ID)
+	updated, _ := repo.GetByID(ctx, tenancy.ForOrg("org-1"), lead.ID)
 	if updated.Name != "Updated Name" {
 		t.Errorf("Name = %q, want Updated Name", updated.Name)
 	}
@@ -95,12 +148,64 @@ func TestInMemoryRepository_UpdateSchedulingPreferences(t *testing.T) {
 	}
 
 	// Not found
-	err = repo.UpdateSchedulingPreferences(ctx, "nonexistent", SchedulingPreferences{})
+	err = repo.UpdateSchedulingPreferences(ctx, tenancy.AllOrgs(), "nonexistent", SchedulingPreferences{})
 	if err != ErrLeadNotFound {
 		t.Errorf("expected ErrLeadNotFound, got %v", err)
 	}
 }
 
+func TestInMemoryRepository_MergePreferencesConcurrentSaves(t *testing.T) {
+	repo := NewInMemoryRepository()
+	ctx := context.Background()
+
+	lead, _ := repo.Create(ctx, &CreateLeadRequest{
+		OrgID: "org-1", Name: "Sarah Jones", Email: "sarah@example.com", Phone: "+11234567890", Source: "sms",
+	})
+
+	// Two worker goroutines finish extraction passes for the same lead at once.
+	patches := []SchedulingPreferences{
+		{Name: "Sarah", PreferredDays: "Friday"},
+		{ServiceInterest: "Botox", PreferredTimes: "afternoon"},
+	}
+	var wg sync.WaitGroup
+	for _, patch := range patches {
+		wg.Add(1)
+		go func(patch SchedulingPreferences) {
+			defer wg.Done()
+			if err := repo.MergePreferences(ctx, lead.ID, patch); err != nil {
+				t.Errorf("merge: %v", err)
+			}
+		}(patch)
+	}
+	wg.Wait()
+
+	got, _ := repo.GetByID(ctx, tenancy.ForOrg("org-1"), lead.ID)
+	if got.Name != "Sarah Jones" || got.Email != "sarah@example.com" {
+		t.Errorf("name/email clobbered: %q %q", got.Name, got.Email)
+	}
+	if got.PreferredDays != "Friday" || got.PreferredTimes != "afternoon" || got.ServiceInterest != "Botox" {
+		t.Errorf("lost a concurrent save: %+v", got)
+	}
+
+	if err := repo.MergePreferences(ctx, "nonexistent", SchedulingPreferences{}); err != ErrLeadNotFound {
+		t.Errorf("expected ErrLeadNotFound, got %v", err)
+	}
+}
+
+func TestMergeName(t *testing.T) {
+	for _, tc := range []struct{ existing, candidate, want string }{
+		{"", "Sarah", "Sarah"},
+		{"Sarah", "Sarah Jones", "Sarah Jones"},
+		{"Sarah Jones", "Friday", "Sarah Jones"},
+		{"Sarah Jones", "Sarah Smith", "Sarah Smith"},
+		{"Sarah", "", "Sarah"},
+	} {
+		if got := mergeName(tc.existing, tc.candidate); got != tc.want {
+			t.Errorf("mergeName(%q, %q) = %q, want %q", tc.existing, tc.candidate, got, tc.want)
+		}
+	}
+}
+
 func TestInMemoryRepository_UpdateSelectedAppointment(t *testing.T) {
 	repo := NewInMemoryRepository()
 	ctx := context.Background()
@@ -110,7 +215,7 @@ func TestInMemoryRepository_UpdateSelectedAppointment(t *testing.T) {
 	})
 
 	dt := time.Now().Add(24 * time.Hour)
-	err := repo.UpdateSelectedAppointment(ctx, lead.ID, SelectedAppointment{
+	err := repo.UpdateSelectedAppointment(ctx, tenancy.ForOrg(lead.OrgID), lead.ID, SelectedAppointment{
 		DateTime: &dt,
 		Service:  "Botox",
 	})
@@ -118,13 +223,13 @@ func TestInMemoryRepository_UpdateSelectedAppointment(t *testing.T) {
 		t.Fatalf("unexpected error: %v", err)
 	}
 
-	updated, _ := repo.GetByID(ctx, "org-1", lead.ID)
+	updated, _ := repo.GetByID(ctx, tenancy.ForOrg("org-1"), lead.ID)
 	if updated.SelectedService != "Botox" {
 		t.Errorf("SelectedService = %q", updated.SelectedService)
 	}
 
 	// Not found
-	err = repo.UpdateSelectedAppointment(ctx, "nonexistent", SelectedAppointment{})
+	err = repo.UpdateSelectedAppointment(ctx, tenancy.AllOrgs(), "nonexistent", SelectedAppointment{})
 	if err != ErrLeadNotFound {
 		t.Errorf("expected ErrLeadNotFound")
 	}
@@ -139,27 +244,36 @@ func TestInMemoryRepository_UpdateEmail(t *testing.T) {
 	})
 
 	// Empty email is no-op
-	err := repo.UpdateEmail(ctx, lead.ID, "")
+	err := repo.UpdateEmail(ctx, tenancy.ForOrg(lead.OrgID), lead.ID, "")
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
 
 	// Update email
-	err = repo.UpdateEmail(ctx, lead.ID, "test@example.com")
+	err = repo.UpdateEmail(ctx, tenancy.ForOrg(lead.OrgID), lead.ID, "test@example.com")
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
 
-	updated, _ := repo.GetByID(ctx, "org-1", lead.ID)
+	updated, _ := repo.GetByID(ctx, tenancy.ForOrg("org-1"), lead.ID)
 	if updated.Email != "test@example.com" {
 		t.Errorf("Email = %q", updated.Email)
 	}
 
 	// Not found
-	err = repo.UpdateEmail(ctx, "nonexistent", "test@example.com")
+	err = repo.UpdateEmail(ctx, tenancy.AllOrgs(), "nonexistent", "test@example.com")
 	if err != ErrLeadNotFound {
 		t.Errorf("expected ErrLeadNotFound")
 	}
+
+	// Another clinic's lead
+	err = repo.UpdateEmail(ctx, tenancy.ForOrg("org-2"), lead.ID, "other@example.com")
+	if err != ErrLeadNotFound {
+		t.Errorf("expected ErrLeadNotFound for another org, got %v", err)
+	}
+	if err := repo.UpdateEmail(ctx, tenancy.OrgScope{}, lead.ID, "other@example.com"); !errors.Is(err, tenancy.ErrNoScope) {
+		t.Errorf("expected ErrNoScope, got %v", err)
+	}
 }
 
 func TestInMemoryRepository_ClearSelectedAppointment(t *testing.T) {
@@ -171,14 +285,14 @@ func TestInMemoryRepository_ClearSelectedAppointment(t *testing.T) {
 	})
 
 	dt := time.Now()
-	_ = repo.UpdateSelectedAppointment(ctx, lead.ID, SelectedAppointment{DateTime: &dt, Service: "Botox"})
+	_ = repo.UpdateSelectedAppointment(ctx, tenancy.ForOrg(lead.OrgID), lead.ID, SelectedAppointment{DateTime: &dt, Service: "Botox"})
 
 	err := repo.ClearSelectedAppointment(ctx, lead.ID)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
 
-	updated, _ := repo.GetByID(ctx, "org-1", lead.ID)
+	updated, _ := repo.GetByID(ctx, tenancy.ForOrg("org-1"), lead.ID)
 	if updated.SelectedDateTime != nil {
 		t.Error("SelectedDateTime should be nil")
 	}
@@ -202,7 +316,7 @@ func TestInMemoryRepository_UpdateBookingSession(t *testing.T) {
 	})
 
 	now := time.Now()
-	err := repo.UpdateBookingSession(ctx, lead.ID, BookingSessionUpdate{
+	err := repo.UpdateBookingSession(ctx, tenancy.ForOrg(lead.OrgID), lead.ID, BookingSessionUpdate{
 		SessionID:          "sess-1",
 		Platform:           "moxie",
 		Outcome:            "completed",
@@ -215,7 +329,7 @@ func TestInMemoryRepository_UpdateBookingSession(t *testing.T) {
 		t.Fatalf("unexpected error: %v", err)
 	}
 
-	updated, _ := repo.GetByID(ctx, "org-1", lead.ID)
+	updated, _ := repo.GetByID(ctx, tenancy.ForOrg("org-1"), lead.ID)
 	if updated.BookingSessionID != "sess-1" {
 		t.Errorf("BookingSessionID = %q", updated.BookingSessionID)
 	}
@@ -224,7 +338,7 @@ func TestInMemoryRepository_UpdateBookingSession(t *testing.T) {
 	}
 
 	// Not found
-	err = repo.UpdateBookingSession(ctx, "nonexistent", BookingSessionUpdate{})
+	err = repo.UpdateBookingSession(ctx, tenancy.AllOrgs(), "nonexistent", BookingSessionUpdate{})
 	if err != ErrLeadNotFound {
 		t.Errorf("expected ErrLeadNotFound")
 	}
@@ -0,0 +1,31 @@
+package leads
+
+import (
+	"fmt"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/tenancy"
+)
+
+// singleOrg returns the org of a scope naming exactly one. Phone lookups
+// resolve to one clinic's lead, so AllOrgs has no meaning for them.
+func singleOrg(scope tenancy.OrgScope) (string, error) {
+	orgID, err := scope.SingleOrg()
+	if err != nil {
+		return "", fmt.Errorf("leads: phone lookup: %w", err)
+	}
+	return orgID, nil
+}
+
+// scopeUpdate narrows an update keyed "WHERE id = $1" to scope, numbering
+// the org placeholder after args. A lead outside scope then matches no row
+// and the caller reports it not found.
+func scopeUpdate(query string, scope tenancy.OrgScope, args []any) (string, []any, error) {
+	if err := scope.Err(); err != nil {
+		return "", nil, err
+	}
+	if cond, condArgs := scope.Where("org_id", len(args)+1); cond != "" {
+		query += " AND " + cond
+		args = append(args, condArgs...)
+	}
+	return query, args, nil
+}
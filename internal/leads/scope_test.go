@@ -0,0 +1,123 @@
+package leads
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	pgxmock "github.com/pashagolub/pgxmock/v4"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/tenancy"
+)
+
+func TestInMemoryRepository_CrossTenantFetchIsNotFound(t *testing.T) {
+	repo := NewInMemoryRepository()
+	ctx := context.Background()
+	lead, err := repo.GetOrCreateByPhone(ctx, "org-a", "+15005550101", "sms", "Jane")
+	if err != nil {
+		t.Fatalf("GetOrCreateByPhone: %v", err)
+	}
+
+	if _, err := repo.GetByID(ctx, tenancy.ForOrg("org-b"), lead.ID); !errors.Is(err, ErrLeadNotFound) {
+		t.Fatalf("cross-tenant GetByID err = %v, want ErrLeadNotFound", err)
+	}
+	if list, _ := repo.ListByOrg(ctx, tenancy.ForOrg("org-b"), ListLeadsFilter{}); len(list) != 0 {
+		t.Fatalf("cross-tenant ListByOrg returned %d leads", len(list))
+	}
+	if _, err := repo.FindByPhone(ctx, tenancy.ForOrg("org-b"), "+15005550101"); !errors.Is(err, ErrLeadNotFound) {
+		t.Fatalf("cross-tenant FindByPhone err = %v, want ErrLeadNotFound", err)
+	}
+
+	if _, err := repo.GetByID(ctx, tenancy.OrgScope{}, lead.ID); !errors.Is(err, tenancy.ErrNoScope) {
+		t.Fatalf("unscoped GetByID err = %v, want ErrNoScope", err)
+	}
+	if _, err := repo.FindByPhone(ctx, tenancy.AllOrgs(), "+15005550101"); !errors.Is(err, tenancy.ErrNoScope) {
+		t.Fatalf("all-orgs FindByPhone err = %v, want ErrNoScope", err)
+	}
+	if got, err := repo.GetByID(ctx, tenancy.AllOrgs(), lead.ID); err != nil || got.ID != lead.ID {
+		t.Fatalf("all-orgs GetByID = %v, %v", got, err)
+	}
+}
+
+func TestPostgresRepository_GetByIDFiltersByScope(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("pgxmock: %v", err)
+	}
+	defer mock.Close()
+	repo := &PostgresRepository{pool: mock}
+	ctx := context.Background()
+
+	// Another clinic's lead ID is filtered out by the query.
+	mock.ExpectQuery(`WHERE id = \$1\s+AND org_id = \$2`).
+		WithArgs("lead-1", "org-b").
+		WillReturnError(pgx.ErrNoRows)
+	if _, err := repo.GetByID(ctx, tenancy.ForOrg("org-b"), "lead-1"); !errors.Is(err, ErrLeadNotFound) {
+		t.Fatalf("cross-tenant GetByID err = %v, want ErrLeadNotFound", err)
+	}
+
+	// AllOrgs reads by ID alone.
+	mock.ExpectQuery(`WHERE id = \$1\s*$`).
+		WithArgs("lead-1").
+		WillReturnRows(leadRows("lead-1", "org-a"))
+	if got, err := repo.GetByID(ctx, tenancy.AllOrgs(), "lead-1"); err != nil || got.OrgID != "org-a" {
+		t.Fatalf("all-orgs GetByID = %v, %v", got, err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestPostgresRepository_GetByIDScopeMismatchFires(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("pgxmock: %v", err)
+	}
+	defer mock.Close()
+	repo := &PostgresRepository{pool: mock}
+
+	// A row from the wrong org, as if the query had lost its org filter.
+	mock.ExpectQuery("FROM leads").
+		WithArgs("lead-1", "org-a").
+		WillReturnRows(leadRows("lead-1", "org-b"))
+
+	lead, err := repo.GetByID(context.Background(), tenancy.ForOrg("org-a"), "lead-1")
+	if !errors.Is(err, tenancy.ErrScopeMismatch) {
+		t.Fatalf("err = %v, want ErrScopeMismatch", err)
+	}
+	if lead != nil {
+		t.Fatalf("expected no lead on scope mismatch, got %+v", lead)
+	}
+}
+
+// leadRows is a single GetByID result row with only the ID and org set.
+func leadRows(id, orgID string) *pgxmock.Rows {
+	cols := []string{
+		"id", "org_id", "name", "email", "phone", "message", "source", "created_at",
+		"service_interest", "patient_type", "past_services", "preferred_days", "preferred_times",
+		"scheduling_notes", "deposit_status", "priority_level", "selected_datetime",
+		"selected_end_datetime", "selected_service", "booking_session_id", "booking_platform",
+		"booking_outcome", "booking_confirmation_number", "booking_handoff_url",
+		"booking_handoff_sent_at", "booking_completed_at", "transactional_consent_at",
+		"marketing_consent", "marketing_consent_at", "marketing_consent_source",
+		"marketing_consent_asked_at", "greeted_at", "last_visit_at", "imported_at",
+		"last_engaged_at", "urgency_expressed_at", "score", "score_breakdown", "scored_at",
+		"probable_wrong_number", "extra_qualifications",
+	}
+	var noTime *time.Time
+	return pgxmock.NewRows(cols).AddRow(
+		id, orgID, "Jane", "", "+15005550101", "", "sms", time.Now(),
+		"", "", "", "", "",
+		"", "", "", noTime,
+		noTime, "", "", "",
+		"", "", "",
+		noTime, noTime, noTime,
+		false, noTime, "",
+		noTime, noTime, noTime, noTime,
+		noTime, noTime, 0, (*ScoreBreakdown)(nil), noTime,
+		false, map[string]string{},
+	)
+}
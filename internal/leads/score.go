@@ -0,0 +1,379 @@
+package leads
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/tenancy"
+)
+
+// Score weights. A lead's score is out of 100.
+const (
+	ScoreServiceValueMax  = 25
+	ScoreQualificationMax = 25
+	ScoreRecencyMax       = 20
+	ScoreUrgency          = 15
+	ScoreDepositPending   = 15
+
+	// scoreFullValueCents is the service price that earns the full service
+	// value points; cheaper services earn a proportional share.
+	scoreFullValueCents = 100000
+	// urgencyWindow is how long "ASAP" keeps counting after it was said.
+	urgencyWindow = 7 * 24 * time.Hour
+)
+
+// ScoreBreakdown is the per-signal share of a lead's score.
+type ScoreBreakdown struct {
+	ServiceValue   int  `json:"service_value"`
+	Qualification  int  `json:"qualification"`
+	Recency        int  `json:"recency"`
+	Urgency        int  `json:"urgency"`
+	DepositPending int  `json:"deposit_pending"`
+	Converted      bool `json:"converted,omitempty"`    // booked or paid; scores 0
+	WrongNumber    bool `json:"wrong_number,omitempty"` // texted the wrong business; scores 0
+}
+
+// Total sums the components. Converted and wrong-number leads need no call
+// and score 0.
+func (b ScoreBreakdown) Total() int {
+	if b.Converted || b.WrongNumber {
+		return 0
+	}
+	return b.ServiceValue + b.Qualification + b.Recency + b.Urgency + b.DepositPending
+}
+
+// ScoreRepository persists lead scores and the engagement they're computed
+// from. Implemented by the Postgres and in-memory repositories; callers
+// type-assert for it.
+type ScoreRepository interface {
+	// RecordEngagement sets last_engaged_at, and urgency_expressed_at when
+	// the patient asked for something soon.
+	RecordEngagement(ctx context.Context, leadID string, at time.Time, urgent bool) error
+	// SaveScore stores a computed score and its breakdown.
+	SaveScore(ctx context.Context, leadID string, breakdown ScoreBreakdown, at time.Time) error
+	// DigestCandidates returns each scoped org's unconverted leads engaged
+	// since since, highest stored score first, at most perOrg per org.
+	// Probable wrong numbers are left out.
+	DigestCandidates(ctx context.Context, scope tenancy.OrgScope, since time.Time, perOrg int) (map[string][]*Lead, error)
+}
+
+var (
+	_ ScoreRepository = (*PostgresRepository)(nil)
+	_ ScoreRepository = (*InMemoryRepository)(nil)
+)
+
+// urgencyRE matches patients asking to be seen soon.
+var urgencyRE = regexp.MustCompile(`(?i)\b(asap|a\.s\.a\.p|as soon as possible|this week(end)?|today|tomorrow|right away|urgent(ly)?|soonest|earliest)\b`)
+
+// ExpressesUrgency reports whether a patient's text asks for something soon.
+func ExpressesUrgency(text string) bool {
+	return urgencyRE.MatchString(text)
+}
+
+// IsConverted reports whether the lead booked or paid a deposit.
+func (l *Lead) IsConverted() bool {
+	switch l.DepositStatus {
+	case "paid", "applied_to_next_selection":
+		return true
+	}
+	return l.BookingOutcome == "success"
+}
+
+// ScoreService is the service the lead's value is priced on: the booked
+// selection when there is one, otherwise what they asked about.
+func (l *Lead) ScoreService() string {
+	if s := strings.TrimSpace(l.SelectedService); s != "" {
+		return s
+	}
+	return strings.TrimSpace(l.ServiceInterest)
+}
+
+// ComputeScore scores a lead for operator follow-up. servicePriceCents is the
+// clinic's price for ScoreService, 0 if unknown. It reads only the lead, so
+// recomputing with the same inputs always gives the same result.
+func ComputeScore(lead *Lead, servicePriceCents int, now time.Time) ScoreBreakdown {
+	var b ScoreBreakdown
+	if lead == nil {
+		return b
+	}
+	b.Converted = lead.IsConverted()
+	b.WrongNumber = lead.ProbableWrongNumber
+
+	if servicePriceCents > 0 {
+		b.ServiceValue = min(ScoreServiceValueMax, servicePriceCents*ScoreServiceValueMax/scoreFullValueCents)
+	}
+
+	answered := 0
+	for _, ok := range []bool{
+		strings.TrimSpace(lead.Name) != "",
+		lead.ScoreService() != "",
+		strings.TrimSpace(lead.PatientType) != "",
+		strings.TrimSpace(lead.PreferredDays) != "" || strings.TrimSpace(lead.PreferredTimes) != "",
+		lead.SelectedDateTime != nil,
+	} {
+		if ok {
+			answered++
+		}
+	}
+	b.Qualification = answered * ScoreQualificationMax / 5
+
+	b.Recency, b.Urgency = decayingScore(lead, now)
+
+	if lead.DepositStatus == "pending" {
+		b.DepositPending = ScoreDepositPending
+	}
+	return b
+}
+
+// recencyTiers are the recency points for a last text no older than each
+// age, most recent first.
+var recencyTiers = []struct {
+	within time.Duration
+	points int
+}{
+	{time.Hour, ScoreRecencyMax},
+	{24 * time.Hour, 15},
+	{72 * time.Hour, 10},
+	{7 * 24 * time.Hour, 5},
+}
+
+// decayingScore returns the recency and urgency points, the parts of a
+// score that fall with time alone.
+func decayingScore(lead *Lead, now time.Time) (recency, urgency int) {
+	if lead.LastEngagedAt != nil {
+		since := now.Sub(*lead.LastEngagedAt)
+		for _, tier := range recencyTiers {
+			if since <= tier.within {
+				recency = tier.points
+				break
+			}
+		}
+	}
+	if lead.UrgencyExpressedAt != nil && now.Sub(*lead.UrgencyExpressedAt) <= urgencyWindow {
+		urgency = ScoreUrgency
+	}
+	return recency, urgency
+}
+
+// RefreshScore brings a stored score up to now: recency and urgency are
+// recomputed, the rest of the breakdown is kept as it was saved. Leads
+// without a stored breakdown are left alone.
+func (l *Lead) RefreshScore(now time.Time) {
+	if l == nil || l.ScoreBreakdown == nil {
+		return
+	}
+	b := *l.ScoreBreakdown
+	b.Recency, b.Urgency = decayingScore(l, now)
+	l.ScoreBreakdown, l.Score = &b, b.Total()
+}
+
+// liveScoreSQL is RefreshScore in SQL, for ordering leads by their score
+// as of now() rather than as of when it was saved.
+var liveScoreSQL = func() string {
+	var recency strings.Builder
+	recency.WriteString("CASE")
+	for _, tier := range recencyTiers {
+		fmt.Fprintf(&recency, " WHEN last_engaged_at >= now() - interval '%d seconds' THEN %d", int(tier.within.Seconds()), tier.points)
+	}
+	recency.WriteString(" ELSE 0 END")
+	return fmt.Sprintf(`(CASE
+		WHEN score_breakdown IS NULL THEN COALESCE(score, 0)
+		WHEN COALESCE((score_breakdown->>'converted')::boolean, false) OR COALESCE((score_breakdown->>'wrong_number')::boolean, false) THEN 0
+		ELSE COALESCE((score_breakdown->>'service_value')::int, 0)
+			+ COALESCE((score_breakdown->>'qualification')::int, 0)
+			+ COALESCE((score_breakdown->>'deposit_pending')::int, 0)
+			+ %s
+			+ CASE WHEN urgency_expressed_at >= now() - interval '%d seconds' THEN %d ELSE 0 END
+	END)`, recency.String(), int(urgencyWindow.Seconds()), ScoreUrgency)
+}()
+
+// RecordEngagement stamps the patient's latest inbound message.
+func (r *PostgresRepository) RecordEngagement(ctx context.Context, leadID string, at time.Time, urgent bool) error {
+	query := `
+		UPDATE leads
+		SET last_engaged_at = $2,
+		    urgency_expressed_at = CASE WHEN $3 THEN $2 ELSE urgency_expressed_at END
+		WHERE id = $1
+	`
+	result, err := r.pool.Exec(ctx, query, leadID, at.UTC(), urgent)
+	if err != nil {
+		return fmt.Errorf("leads: record engagement: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return ErrLeadNotFound
+	}
+	return nil
+}
+
+// SaveScore stores a computed score and its breakdown.
+func (r *PostgresRepository) SaveScore(ctx context.Context, leadID string, breakdown ScoreBreakdown, at time.Time) error {
+	raw, err := json.Marshal(breakdown)
+	if err != nil {
+		return fmt.Errorf("leads: encode score breakdown: %w", err)
+	}
+	query := `UPDATE leads SET score = $2, score_breakdown = $3::jsonb, scored_at = $4 WHERE id = $1`
+	result, err := r.pool.Exec(ctx, query, leadID, breakdown.Total(), string(raw), at.UTC())
+	if err != nil {
+		return fmt.Errorf("leads: save score: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return ErrLeadNotFound
+	}
+	return nil
+}
+
+// DigestCandidates returns each scoped org's top unconverted leads engaged since since.
+func (r *PostgresRepository) DigestCandidates(ctx context.Context, scope tenancy.OrgScope, since time.Time, perOrg int) (map[string][]*Lead, error) {
+	if err := scope.Err(); err != nil {
+		return nil, err
+	}
+	args := []any{since.UTC(), perOrg}
+	orgFilter := ""
+	if cond, condArgs := scope.Where("org_id", 3); cond != "" {
+		orgFilter = "AND " + cond
+		args = append(args, condArgs...)
+	}
+	query := `
+		SELECT id, org_id, name, email, phone, message, source, created_at,
+		       COALESCE(service_interest, '') as service_interest,
+		       COALESCE(patient_type, '') as patient_type,
+		       COALESCE(past_services, '') as past_services,
+		       COALESCE(preferred_days, '') as preferred_days,
+		       COALESCE(preferred_times, '') as preferred_times,
+		       COALESCE(scheduling_notes, '') as scheduling_notes,
+		       COALESCE(deposit_status, '') as deposit_status,
+		       COALESCE(priority_level, '') as priority_level,
+		       selected_datetime,
+		       selected_end_datetime,
+		       COALESCE(selected_service, '') as selected_service,
+		       COALESCE(booking_outcome, '') as booking_outcome,
+		       last_engaged_at,
+		       urgency_expressed_at,
+		       score,
+		       score_breakdown,
+		       scored_at
+		FROM (
+			SELECT *, ROW_NUMBER() OVER (PARTITION BY org_id ORDER BY score DESC, last_engaged_at DESC) AS org_rank
+			FROM leads
+			WHERE last_engaged_at >= $1
+			  AND COALESCE(booking_outcome, '') <> 'success'
+			  AND COALESCE(deposit_status, '') NOT IN ('paid', 'applied_to_next_selection')
+			  AND NOT probable_wrong_number
+			  ` + orgFilter + `
+		) ranked
+		WHERE org_rank <= $2
+		ORDER BY org_id, org_rank
+	`
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("leads: digest candidates: %w", err)
+	}
+	defer rows.Close()
+
+	out := make(map[string][]*Lead)
+	for rows.Next() {
+		var lead Lead
+		if err := rows.Scan(
+			&lead.ID,
+			&lead.OrgID,
+			&lead.Name,
+			&lead.Email,
+			&lead.Phone,
+			&lead.Message,
+			&lead.Source,
+			&lead.CreatedAt,
+			&lead.ServiceInterest,
+			&lead.PatientType,
+			&lead.PastServices,
+			&lead.PreferredDays,
+			&lead.PreferredTimes,
+			&lead.SchedulingNotes,
+			&lead.DepositStatus,
+			&lead.PriorityLevel,
+			&lead.SelectedDateTime,
+			&lead.SelectedEndDateTime,
+			&lead.SelectedService,
+			&lead.BookingOutcome,
+			&lead.LastEngagedAt,
+			&lead.UrgencyExpressedAt,
+			&lead.Score,
+			&lead.ScoreBreakdown,
+			&lead.ScoredAt,
+		); err != nil {
+			return nil, fmt.Errorf("leads: scan digest candidate: %w", err)
+		}
+		if err := r.reveal(&lead); err != nil {
+			return nil, err
+		}
+		out[lead.OrgID] = append(out[lead.OrgID], &lead)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("leads: digest candidates rows: %w", err)
+	}
+	return out, nil
+}
+
+// RecordEngagement stamps the patient's latest inbound message.
+func (r *InMemoryRepository) RecordEngagement(ctx context.Context, leadID string, at time.Time, urgent bool) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	lead, ok := r.leads[leadID]
+	if !ok {
+		return ErrLeadNotFound
+	}
+	at = at.UTC()
+	lead.LastEngagedAt = &at
+	if urgent {
+		lead.UrgencyExpressedAt = &at
+	}
+	return nil
+}
+
+// SaveScore stores a computed score and its breakdown.
+func (r *InMemoryRepository) SaveScore(ctx context.Context, leadID string, breakdown ScoreBreakdown, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	lead, ok := r.leads[leadID]
+	if !ok {
+		return ErrLeadNotFound
+	}
+	at = at.UTC()
+	lead.Score = breakdown.Total()
+	lead.ScoreBreakdown = &breakdown
+	lead.ScoredAt = &at
+	return nil
+}
+
+// DigestCandidates returns each scoped org's top unconverted leads engaged since since.
+func (r *InMemoryRepository) DigestCandidates(ctx context.Context, scope tenancy.OrgScope, since time.Time, perOrg int) (map[string][]*Lead, error) {
+	if err := scope.Err(); err != nil {
+		return nil, err
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	out := make(map[string][]*Lead)
+	for _, l := range r.leads {
+		if !scope.Allows(l.OrgID) || l.IsConverted() || l.ProbableWrongNumber || l.LastEngagedAt == nil || l.LastEngagedAt.Before(since) {
+			continue
+		}
+		copied := *l
+		out[l.OrgID] = append(out[l.OrgID], &copied)
+	}
+	for orgID, list := range out {
+		sort.SliceStable(list, func(i, j int) bool {
+			if list[i].Score != list[j].Score {
+				return list[i].Score > list[j].Score
+			}
+			return list[i].LastEngagedAt.After(*list[j].LastEngagedAt)
+		})
+		if perOrg > 0 && len(list) > perOrg {
+			out[orgID] = list[:perOrg]
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,175 @@
+package leads
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/tenancy"
+)
+
+func TestComputeScore(t *testing.T) {
+	now := time.Date(2026, 10, 18, 15, 0, 0, 0, time.UTC)
+	at := func(ago time.Duration) *time.Time { t := now.Add(-ago); return &t }
+	slot := now.Add(48 * time.Hour)
+
+	tests := []struct {
+		name  string
+		lead  Lead
+		price int
+		want  ScoreBreakdown
+	}{
+		{
+			name: "new lead with nothing captured",
+			lead: Lead{},
+			want: ScoreBreakdown{},
+		},
+		{
+			name:  "qualified, texting now, asked for ASAP, deposit link unpaid",
+			lead:  Lead{Name: "Sarah", ServiceInterest: "Filler", PatientType: "new", PreferredTimes: "morning", SelectedDateTime: &slot, DepositStatus: "pending", LastEngagedAt: at(10 * time.Minute), UrgencyExpressedAt: at(10 * time.Minute)},
+			price: 70000,
+			want:  ScoreBreakdown{ServiceValue: 17, Qualification: 25, Recency: 20, Urgency: 15, DepositPending: 15},
+		},
+		{
+			name:  "price above the cap earns the full service value",
+			lead:  Lead{ServiceInterest: "CoolSculpting", LastEngagedAt: at(30 * time.Hour)},
+			price: 250000,
+			want:  ScoreBreakdown{ServiceValue: 25, Qualification: 5, Recency: 10},
+		},
+		{
+			name:  "urgency and recency fade",
+			lead:  Lead{Name: "Mia", LastEngagedAt: at(6 * 24 * time.Hour), UrgencyExpressedAt: at(8 * 24 * time.Hour)},
+			price: 0,
+			want:  ScoreBreakdown{Qualification: 5, Recency: 5},
+		},
+		{
+			name:  "converted lead scores 0",
+			lead:  Lead{Name: "Ana", ServiceInterest: "Botox", DepositStatus: "paid", LastEngagedAt: at(time.Minute)},
+			price: 1200,
+			want:  ScoreBreakdown{Qualification: 10, Recency: 20, Converted: true},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ComputeScore(&tt.lead, tt.price, now)
+			if got != tt.want {
+				t.Fatalf("ComputeScore = %+v, want %+v", got, tt.want)
+			}
+			if again := ComputeScore(&tt.lead, tt.price, now); again != got {
+				t.Fatalf("recomputing changed the score: %+v then %+v", got, again)
+			}
+		})
+	}
+	if total := (ScoreBreakdown{ServiceValue: 17, Qualification: 25, Recency: 20, Urgency: 15, DepositPending: 15}).Total(); total != 92 {
+		t.Fatalf("Total = %d, want 92", total)
+	}
+	if total := (ScoreBreakdown{Recency: 20, Converted: true}).Total(); total != 0 {
+		t.Fatalf("converted Total = %d, want 0", total)
+	}
+}
+
+func TestRefreshScore(t *testing.T) {
+	now := time.Date(2026, 10, 18, 15, 0, 0, 0, time.UTC)
+	engaged := now.Add(-50 * time.Hour)
+	lead := &Lead{
+		LastEngagedAt:      &engaged,
+		UrgencyExpressedAt: &engaged,
+		Score:              75,
+		ScoreBreakdown:     &ScoreBreakdown{ServiceValue: 20, Qualification: 20, Recency: ScoreRecencyMax, Urgency: ScoreUrgency},
+	}
+	lead.RefreshScore(now)
+	if lead.ScoreBreakdown.Recency != 10 || lead.ScoreBreakdown.Urgency != ScoreUrgency || lead.Score != 65 {
+		t.Fatalf("expected recency to decay to 10 and urgency to hold, got %+v score %d", lead.ScoreBreakdown, lead.Score)
+	}
+	lead.RefreshScore(now.Add(6 * 24 * time.Hour))
+	if lead.ScoreBreakdown.Recency != 0 || lead.ScoreBreakdown.Urgency != 0 || lead.Score != 40 {
+		t.Fatalf("expected only the non-decaying parts left, got %+v score %d", lead.ScoreBreakdown, lead.Score)
+	}
+
+	unscored := &Lead{Score: 0}
+	unscored.RefreshScore(now)
+	if unscored.ScoreBreakdown != nil {
+		t.Fatal("a lead without a stored breakdown should be left alone")
+	}
+}
+
+func TestExpressesUrgency(t *testing.T) {
+	for text, want := range map[string]bool{
+		"Can I get in ASAP?":                 true,
+		"anything this week":                 true,
+		"Do you have something tomorrow":     true,
+		"earliest you have":                  true,
+		"I'd like Botox sometime next month": false,
+		"Is the deposit refundable?":         false,
+	} {
+		if got := ExpressesUrgency(text); got != want {
+			t.Errorf("ExpressesUrgency(%q) = %v, want %v", text, got, want)
+		}
+	}
+}
+
+func TestInMemoryRepository_ScoreAndDigestCandidates(t *testing.T) {
+	repo := NewInMemoryRepository()
+	ctx := context.Background()
+	now := time.Date(2026, 10, 18, 15, 0, 0, 0, time.UTC)
+
+	hot, _ := repo.Create(ctx, &CreateLeadRequest{OrgID: "org-1", Name: "Hot", Phone: "+15550001111"})
+	warm, _ := repo.Create(ctx, &CreateLeadRequest{OrgID: "org-1", Name: "Warm", Phone: "+15550002222"})
+	booked, _ := repo.Create(ctx, &CreateLeadRequest{OrgID: "org-1", Name: "Booked", Phone: "+15550003333"})
+	stale, _ := repo.Create(ctx, &CreateLeadRequest{OrgID: "org-1", Name: "Stale", Phone: "+15550004444"})
+
+	if err := repo.RecordEngagement(ctx, hot.ID, now, true); err != nil {
+		t.Fatalf("RecordEngagement: %v", err)
+	}
+	_ = repo.RecordEngagement(ctx, warm.ID, now.Add(-time.Hour), false)
+	_ = repo.RecordEngagement(ctx, booked.ID, now, false)
+	_ = repo.RecordEngagement(ctx, stale.ID, now.Add(-30*24*time.Hour), false)
+	_ = repo.UpdateDepositStatus(ctx, tenancy.ForOrg(booked.OrgID), booked.ID, "paid", "priority")
+	for _, id := range []string{hot.ID, warm.ID, booked.ID, stale.ID} {
+		lead, _ := repo.GetByID(ctx, tenancy.ForOrg("org-1"), id)
+		if err := repo.SaveScore(ctx, id, ComputeScore(lead, 0, now), now); err != nil {
+			t.Fatalf("SaveScore: %v", err)
+		}
+	}
+
+	got, _ := repo.GetByID(ctx, tenancy.ForOrg("org-1"), hot.ID)
+	if got.UrgencyExpressedAt == nil || got.Score != 5+20+15 || got.ScoreBreakdown == nil || got.ScoredAt == nil {
+		t.Fatalf("hot lead = %+v", got)
+	}
+
+	candidates, err := repo.DigestCandidates(ctx, tenancy.AllOrgs(), now.Add(-7*24*time.Hour), 5)
+	if err != nil {
+		t.Fatalf("DigestCandidates: %v", err)
+	}
+	list := candidates["org-1"]
+	if len(list) != 2 || list[0].ID != hot.ID || list[1].ID != warm.ID {
+		t.Fatalf("candidates = %+v, want hot then warm", list)
+	}
+}
+
+func TestInMemoryRepository_DigestCandidatesSkipsWrongNumbers(t *testing.T) {
+	repo := NewInMemoryRepository()
+	ctx := context.Background()
+	now := time.Date(2026, 10, 18, 15, 0, 0, 0, time.UTC)
+
+	lead, _ := repo.Create(ctx, &CreateLeadRequest{OrgID: "org-1", Name: "Pat", Phone: "+15550005555"})
+	_ = repo.RecordEngagement(ctx, lead.ID, now, true)
+	if err := repo.SetProbableWrongNumber(ctx, lead.ID, true); err != nil {
+		t.Fatalf("SetProbableWrongNumber: %v", err)
+	}
+	flagged, _ := repo.GetByID(ctx, tenancy.ForOrg("org-1"), lead.ID)
+	if b := ComputeScore(flagged, 50000, now); !b.WrongNumber || b.Total() != 0 {
+		t.Fatalf("wrong-number score = %+v (total %d), want 0", b, b.Total())
+	}
+	candidates, _ := repo.DigestCandidates(ctx, tenancy.AllOrgs(), now.Add(-time.Hour), 5)
+	if len(candidates["org-1"]) != 0 {
+		t.Fatalf("flagged lead in digest: %+v", candidates["org-1"])
+	}
+
+	// Re-engaging clears the flag and the lead is back in the digest.
+	_ = repo.SetProbableWrongNumber(ctx, lead.ID, false)
+	candidates, _ = repo.DigestCandidates(ctx, tenancy.AllOrgs(), now.Add(-time.Hour), 5)
+	if len(candidates["org-1"]) != 1 {
+		t.Fatalf("candidates after clearing = %+v, want the lead", candidates["org-1"])
+	}
+}
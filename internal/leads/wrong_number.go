@@ -0,0 +1,44 @@
+package leads
+
+import (
+	"context"
+	"fmt"
+)
+
+// WrongNumberRepository flags leads whose texts were meant for another
+// business. Implemented by the Postgres and in-memory repositories; callers
+// type-assert for it.
+type WrongNumberRepository interface {
+	// SetProbableWrongNumber sets or clears the lead's wrong-number flag.
+	SetProbableWrongNumber(ctx context.Context, leadID string, wrong bool) error
+}
+
+var (
+	_ WrongNumberRepository = (*PostgresRepository)(nil)
+	_ WrongNumberRepository = (*InMemoryRepository)(nil)
+)
+
+// SetProbableWrongNumber sets or clears the lead's wrong-number flag.
+func (r *PostgresRepository) SetProbableWrongNumber(ctx context.Context, leadID string, wrong bool) error {
+	query := `UPDATE leads SET probable_wrong_number = $2 WHERE id = $1`
+	result, err := r.pool.Exec(ctx, query, leadID, wrong)
+	if err != nil {
+		return fmt.Errorf("leads: set probable wrong number: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return ErrLeadNotFound
+	}
+	return nil
+}
+
+// SetProbableWrongNumber sets or clears the lead's wrong-number flag.
+func (r *InMemoryRepository) SetProbableWrongNumber(ctx context.Context, leadID string, wrong bool) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	lead, ok := r.leads[leadID]
+	if !ok {
+		return ErrLeadNotFound
+	}
+	lead.ProbableWrongNumber = wrong
+	return nil
+}
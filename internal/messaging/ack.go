@@ -1,78 +1,90 @@
 package messaging
 
 import (
-	"fmt"
-	"math/rand"
 	"strings"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/clinic"
+	"github.com/wolfman30/medspa-ai-platform/internal/conversation/ackmsgs"
+	"github.com/wolfman30/medspa-ai-platform/internal/messaging/templates"
 )
 
 // InstantAckMessage is the fast auto-reply sent immediately for missed-call text-backs.
-const InstantAckMessage = "Hi there! Sorry we missed your call. I'm the virtual receptionist and can help by text—though I can't provide medical advice. How can I help today - booking an appointment or a quick question? Reply STOP to opt out."
+var InstantAckMessage = templates.Text(templates.AckMissedCall)
 
 // InstantAckMessageForClinic personalizes the missed-call ack with a clinic name when available.
 func InstantAckMessageForClinic(clinicName string) string {
-	name := strings.TrimSpace(clinicName)
+	return MissedCallAck(&clinic.Config{Name: clinicName}).Body
+}
+
+// MissedCallAck renders the missed-call text-back with the clinic's copy.
+// It is safe to call on a nil config.
+func MissedCallAck(cfg *clinic.Config) templates.Rendered {
+	var name string
+	if cfg != nil {
+		name = strings.TrimSpace(cfg.Name)
+	}
 	if name == "" {
-		return InstantAckMessage
+		return cfg.RenderTemplate(templates.AckMissedCall, nil)
 	}
-	return fmt.Sprintf("Hi there! Sorry we missed your call. I'm the virtual receptionist for %s and can help by text—though I can't provide medical advice. How can I help today - booking an appointment or a quick question? Reply STOP to opt out.", name)
+	return cfg.RenderTemplate(templates.AckMissedCallClinic, templates.Params{"clinic_name": name})
 }
 
-// PCIGuardrailMessage is sent when inbound SMS appears to contain payment card details.
-const PCIGuardrailMessage = "For your security, please do not send credit card details by text. We can only take payments through our secure checkout link. If you'd like a deposit link, reply \"deposit\" and we'll send it. Reply STOP to opt out."
-
-// SmsAckMessageFirst is the ack for the first inbound SMS in a conversation.
-const SmsAckMessageFirstBase = "Got it - give me a moment to help you."
+// PCIGuardrailMessage is sent when inbound SMS appears to contain a card
+// number, security code or SSN.
+var PCIGuardrailMessage = templates.Text(templates.CompliancePCIGuardrail)
 
-// SmsAckMessageFirst is kept for backward compatibility (e.g. IsSmsAckMessage).
-const SmsAckMessageFirst = SmsAckMessageFirstBase
+// Default compliance keyword replies, shared by the Telnyx and Twilio inbound paths.
+var (
+	DefaultStopAck  = templates.Text(templates.ComplianceStopAck)
+	DefaultHelpAck  = templates.Text(templates.ComplianceHelpAck)
+	DefaultStartAck = templates.Text(templates.ComplianceStartAck)
+)
 
-// smsAckMessagesFirst are varied acks for the first inbound message.
-// NOTE: Medical advice disclaimer removed — it was off-putting on booking
-// requests like "I want Botox." The missed-call ack (InstantAckMessage)
-// still includes it naturally. The LLM handles medical deflection when needed.
-var smsAckMessagesFirst = []string{
-	SmsAckMessageFirstBase,
-	"Thanks for reaching out - one moment while I check.",
-	"Thanks! Give me a second to look that up.",
-	"Got it! Let me check on that.",
+// ComplianceAckOverrides turns deployment-wide STOP/HELP/START replies into
+// template overrides. Empty values keep the defaults.
+func ComplianceAckOverrides(stop, help, start string) templates.Overrides {
+	out := make(templates.Overrides)
+	for id, text := range map[string]string{
+		templates.ComplianceStopAck:  stop,
+		templates.ComplianceHelpAck:  help,
+		templates.ComplianceStartAck: start,
+	} {
+		if strings.TrimSpace(text) != "" {
+			out[id] = templates.Override{Text: text}
+		}
+	}
+	return out
 }
 
-// smsAckMessagesFollowUp are varied acks for follow-up messages to feel more human.
-var smsAckMessagesFollowUp = []string{
-	"Thanks - one moment...",
-	"Got it. One sec.",
-	"On it - just a moment.",
-	"Checking now...",
-	"Give me a second...",
+// ComplianceReply renders a compliance or guardrail reply. The clinic's own
+// copy wins over the deployment-wide overrides.
+func ComplianceReply(id string, global templates.Overrides, cfg *clinic.Config) templates.Rendered {
+	return templates.Render(id, nil, global.Merge(cfg.TemplateOverrides()))
 }
 
-// GetSmsAckMessage returns the appropriate ack message.
+// SmsAckMessageFirstBase is the ack for the first inbound SMS in a conversation.
+var SmsAckMessageFirstBase = ackmsgs.First[0]
+
+// SmsAckMessageFirst is kept for backward compatibility (e.g. IsSmsAckMessage).
+var SmsAckMessageFirst = SmsAckMessageFirstBase
+
+// smsAckMessagesFirst and smsAckMessagesFollowUp are the default ack sets;
+// the canonical lists live in ackmsgs so the E2E checks share them.
+var (
+	smsAckMessagesFirst    = ackmsgs.First
+	smsAckMessagesFollowUp = ackmsgs.FollowUp
+)
+
+// GetSmsAckMessage returns the appropriate default ack message.
 // isFirstMessage should be true for the first message in a conversation.
 func GetSmsAckMessage(isFirstMessage bool) string {
 	if isFirstMessage {
-		return smsAckMessagesFirst[rand.Intn(len(smsAckMessagesFirst))]
+		return ackmsgs.Pick(smsAckMessagesFirst, "")
 	}
-	return smsAckMessagesFollowUp[rand.Intn(len(smsAckMessagesFollowUp))]
+	return ackmsgs.Pick(smsAckMessagesFollowUp, "")
 }
 
-// IsSmsAckMessage reports whether a message matches any configured ack response.
+// IsSmsAckMessage reports whether a message matches any default ack response.
 func IsSmsAckMessage(message string) bool {
-	if message == "" {
-		return false
-	}
-	if message == SmsAckMessageFirst {
-		return true
-	}
-	for _, candidate := range smsAckMessagesFirst {
-		if message == candidate {
-			return true
-		}
-	}
-	for _, candidate := range smsAckMessagesFollowUp {
-		if message == candidate {
-			return true
-		}
-	}
-	return false
+	return ackmsgs.Is(message)
 }
@@ -0,0 +1,38 @@
+package compliance
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/tenancy"
+)
+
+// ErrNoMarketingConsent is returned when a marketing message targets a
+// recipient who has not opted in to promotional texts.
+var ErrNoMarketingConsent = errors.New("compliance: recipient has not consented to marketing messages")
+
+// MarketingConsentChecker reports whether a recipient opted in to marketing
+// messages from an org.
+type MarketingConsentChecker interface {
+	HasMarketingConsent(ctx context.Context, scope tenancy.OrgScope, phone string) (bool, error)
+}
+
+// RequireMarketingConsent gates a send on marketing consent. Transactional
+// sends always pass; marketing sends fail closed when no checker is set.
+func RequireMarketingConsent(ctx context.Context, checker MarketingConsentChecker, purpose Purpose, orgID, phone string) error {
+	if purpose != PurposeMarketing {
+		return nil
+	}
+	if checker == nil {
+		return ErrNoMarketingConsent
+	}
+	ok, err := checker.HasMarketingConsent(ctx, tenancy.ForOrg(orgID), phone)
+	if err != nil {
+		return fmt.Errorf("compliance: check marketing consent: %w", err)
+	}
+	if !ok {
+		return ErrNoMarketingConsent
+	}
+	return nil
+}
@@ -6,55 +6,90 @@ import (
 	"unicode"
 )
 
-var panCandidateRE = regexp.MustCompile(`(?:\d[ -]?){13,19}`)
+// panCandidateRE matches 13-19 digits, optionally grouped by single spaces or
+// dashes, that aren't part of a longer digit run.
+var panCandidateRE = regexp.MustCompile(`\b\d(?:[ -]?\d){12,18}\b`)
 
 // RedactPAN detects likely payment card numbers (PAN) and returns a redacted version of the text.
 // The returned string is safe to persist/log (it does not contain the full PAN).
 func RedactPAN(text string) (string, bool) {
-	original := text
-	if strings.TrimSpace(text) == "" {
-		return text, false
-	}
+	out, spans := redactPANs(text)
+	return out, len(spans) > 0
+}
 
-	matches := panCandidateRE.FindAllStringIndex(text, -1)
-	if len(matches) == 0 {
-		return text, false
+// redactPANs replaces each Luhn-valid card number with "[CARD ending 1234]"
+// and returns the end offsets of the placeholders in the redacted text.
+func redactPANs(text string) (string, []int) {
+	if strings.TrimSpace(text) == "" {
+		return text, nil
 	}
 
 	var out strings.Builder
-	out.Grow(len(text))
-	last := 0
-	redacted := false
+	last, pos := 0, 0
+	var ends []int
 
-	for _, m := range matches {
-		start, end := m[0], m[1]
-		if start < last {
-			continue
+	for pos < len(text) {
+		loc := panCandidateRE.FindStringIndex(text[pos:])
+		if loc == nil {
+			break
 		}
-
-		candidate := text[start:end]
-		digits := digitsOnly(candidate)
-		if len(digits) < 13 || len(digits) > 19 || !luhnValid(digits) {
+		start, end := pos+loc[0], pos+loc[1]
+		n := cardPrefixLen(text[start:end])
+		if n == 0 {
+			// Skip the first group; a card may still start at the next one.
+			pos = start + strings.IndexFunc(text[start:end]+" ", isCardSeparator)
 			continue
 		}
-
+		digits := digitsOnly(text[start : start+n])
 		out.WriteString(text[last:start])
-		last4 := digits
-		if len(last4) > 4 {
-			last4 = last4[len(last4)-4:]
-		}
-		out.WriteString("[REDACTED_CARD_")
-		out.WriteString(last4)
+		out.WriteString("[CARD ending ")
+		out.WriteString(digits[len(digits)-4:])
 		out.WriteString("]")
-		last = end
-		redacted = true
+		ends = append(ends, out.Len())
+		last, pos = start+n, start+n
 	}
 
-	if !redacted {
-		return original, false
+	if len(ends) == 0 {
+		return text, nil
 	}
 	out.WriteString(text[last:])
-	return out.String(), true
+	return out.String(), ends
+}
+
+// cardPrefixLen returns the length of the longest run of whole groups at the
+// start of candidate that is a Luhn-valid, card-grouped number, or 0. A card
+// followed by its security code ("4111 1111 1111 1111 123") is still found.
+func cardPrefixLen(candidate string) int {
+	for end := len(candidate); end > 0; {
+		sub := candidate[:end]
+		digits := digitsOnly(sub)
+		if len(digits) >= 13 && len(digits) <= 19 && cardGrouping(sub) && luhnValid(digits) {
+			return end
+		}
+		end = strings.LastIndexFunc(sub, isCardSeparator)
+	}
+	return 0
+}
+
+func isCardSeparator(r rune) bool { return r == ' ' || r == '-' }
+
+// cardGrouping reports whether a separated candidate is grouped the way card
+// numbers are printed (4-4-4-4, 4-6-5, ...), which keeps runs of phone
+// numbers like "555-123-4567 555-123-4567" from being read as one card.
+func cardGrouping(candidate string) bool {
+	groups := strings.FieldsFunc(candidate, isCardSeparator)
+	if len(groups) == 1 {
+		return true
+	}
+	if len(groups[0]) != 4 {
+		return false
+	}
+	for i, g := range groups {
+		if len(g) > 6 || (len(g) < 4 && i != len(groups)-1) {
+			return false
+		}
+	}
+	return true
 }
 
 func digitsOnly(s string) string {
@@ -13,12 +13,14 @@ func TestRedactPAN(t *testing.T) {
 	}{
 		{"empty", "", false, ""},
 		{"no card", "Hello world", false, ""},
-		{"visa card", "My card is 4111111111111111", true, "[REDACTED_CARD_1111]"},
-		{"card with spaces", "4111 1111 1111 1111", true, "[REDACTED_CARD_1111]"},
-		{"card with dashes", "4111-1111-1111-1111", true, "[REDACTED_CARD_1111]"},
-		{"text around card", "pay with 4111111111111111 please", true, "[REDACTED_CARD_1111]"},
+		{"visa card", "My card is 4111111111111111", true, "[CARD ending 1111]"},
+		{"card with spaces", "4111 1111 1111 1111", true, "[CARD ending 1111]"},
+		{"card with dashes", "4111-1111-1111-1111", true, "[CARD ending 1111]"},
+		{"text around card", "pay with 4111111111111111 please", true, "[CARD ending 1111]"},
 		{"invalid luhn", "1234567890123456", false, ""},
 		{"too short", "411111111111", false, ""},
+		{"amex grouping", "3782 822463 10005", true, "[CARD ending 0005]"},
+		{"phone numbers back to back", "call 555-123-4567 555-123-4567", false, ""},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
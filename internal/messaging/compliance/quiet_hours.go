@@ -74,3 +74,17 @@ func (q QuietHours) Suppress(now time.Time, purpose Purpose) bool {
 	// Window crosses midnight.
 	return minutes >= q.StartMinutes || minutes < q.EndMinutes
 }
+
+// NextSendTime returns now when a marketing send is allowed at now, and
+// otherwise the moment the quiet-hours window ends.
+func (q QuietHours) NextSendTime(now time.Time) time.Time {
+	if !q.Suppress(now, PurposeMarketing) {
+		return now
+	}
+	local := now.In(q.location)
+	end := time.Date(local.Year(), local.Month(), local.Day(), q.EndMinutes/60, q.EndMinutes%60, 0, 0, q.location)
+	if !end.After(local) {
+		end = end.AddDate(0, 0, 1)
+	}
+	return end
+}
@@ -69,3 +69,23 @@ func TestQuietHoursSuppressDisabledOrNonMarketing(t *testing.T) {
 		t.Fatalf("transactional sends should bypass quiet hours")
 	}
 }
+
+func TestQuietHoursNextSendTime(t *testing.T) {
+	q, err := ParseQuietHours("21:00", "08:00", "America/New_York")
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	loc, _ := time.LoadLocation("America/New_York")
+	tests := []struct {
+		now, want time.Time
+	}{
+		{time.Date(2026, 3, 10, 14, 0, 0, 0, loc), time.Date(2026, 3, 10, 14, 0, 0, 0, loc)},
+		{time.Date(2026, 3, 10, 23, 30, 0, 0, loc), time.Date(2026, 3, 11, 8, 0, 0, 0, loc)},
+		{time.Date(2026, 3, 11, 2, 15, 0, 0, loc), time.Date(2026, 3, 11, 8, 0, 0, 0, loc)},
+	}
+	for _, tt := range tests {
+		if got := q.NextSendTime(tt.now); !got.Equal(tt.want) {
+			t.Errorf("NextSendTime(%s) = %s, want %s", tt.now, got, tt.want)
+		}
+	}
+}
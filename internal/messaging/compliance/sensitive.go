@@ -0,0 +1,118 @@
+package compliance
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Kinds of sensitive number RedactSensitiveNumbers replaces.
+const (
+	SensitiveCard = "card"
+	SensitiveSSN  = "ssn"
+	SensitiveCVV  = "cvv"
+)
+
+const (
+	ssnPlaceholder = "[SSN]"
+	cvvPlaceholder = "[CVV]"
+)
+
+var (
+	// ssnRE matches a grouped SSN, e.g. 123-45-6789 or 123 45 6789.
+	ssnRE = regexp.MustCompile(`\b(\d{3})[- ](\d{2})[- ](\d{4})\b`)
+	// ssnLabeledRE matches nine bare digits introduced as an SSN.
+	ssnLabeledRE = regexp.MustCompile(`(?i)\b(?:ssn|ss#|social(?:\s+security)?(?:\s+(?:number|no\.?|#))?)\W{0,10}(?:is\W{0,3})?(\d{9})\b`)
+	// cvvLabeledRE matches a 3-4 digit code introduced as a card security code.
+	cvvLabeledRE = regexp.MustCompile(`(?i)\b(?:cvv2?|cvc2?|cvn|cid|csc|security\s+code)\W{0,10}(?:is\W{0,3})?(\d{3,4})\b`)
+	// trailingCodeRE matches a standalone 3-4 digit code that isn't part of
+	// an expiry date or an amount.
+	trailingCodeRE = regexp.MustCompile(`(?:^|[^\d/$.,:-])(\d{3,4})(?:$|[^\d/.,:-])`)
+	placeholderRE  = regexp.MustCompile(`\[CARD ending \d{4}\]|\[SSN\]|\[CVV\]`)
+)
+
+// trailingCodeWindow is how far after a card number a bare 3-4 digit code
+// is taken to be its security code.
+const trailingCodeWindow = 40
+
+// RedactSensitiveNumbers replaces Luhn-valid card numbers with
+// "[CARD ending 1234]", SSNs with "[SSN]" and card security codes with
+// "[CVV]", and returns the kinds of placeholder in the result. It is
+// idempotent: placeholders already in text are reported but left alone, so
+// a message redacted upstream is still recognised downstream.
+func RedactSensitiveNumbers(text string) (string, []string) {
+	if strings.TrimSpace(text) == "" {
+		return text, nil
+	}
+	text, cardEnds := redactPANs(text)
+	text = redactTrailingCodes(text, cardEnds)
+	text = replaceGroup(text, cvvLabeledRE, func(string) bool { return true }, cvvPlaceholder)
+	text = replaceGroup(text, ssnLabeledRE, func(d string) bool { return validSSN(d[:3], d[3:5], d[5:]) }, ssnPlaceholder)
+	text = ssnRE.ReplaceAllStringFunc(text, func(m string) string {
+		parts := ssnRE.FindStringSubmatch(m)
+		if !validSSN(parts[1], parts[2], parts[3]) {
+			return m
+		}
+		return ssnPlaceholder
+	})
+	return text, placeholderKinds(text)
+}
+
+// redactTrailingCodes replaces the first bare 3-4 digit code shortly after
+// each card number, e.g. "4111 1111 1111 1111 12/27 123".
+func redactTrailingCodes(text string, cardEnds []int) string {
+	for i := len(cardEnds) - 1; i >= 0; i-- {
+		start := cardEnds[i]
+		end := min(start+trailingCodeWindow, len(text))
+		if next := strings.Index(text[start:end], "[CARD ending "); next >= 0 {
+			end = start + next
+		}
+		loc := trailingCodeRE.FindStringSubmatchIndex(text[start:end])
+		if loc == nil {
+			continue
+		}
+		text = text[:start+loc[2]] + cvvPlaceholder + text[start+loc[3]:]
+	}
+	return text
+}
+
+// replaceGroup replaces the first capture group of each match that passes
+// keep with placeholder, leaving the label before it in place. A label that
+// is itself a placeholder, as in "[CVV] 123", doesn't count.
+func replaceGroup(text string, re *regexp.Regexp, keep func(string) bool, placeholder string) string {
+	locs := re.FindAllStringSubmatchIndex(text, -1)
+	for i := len(locs) - 1; i >= 0; i-- {
+		if locs[i][0] > 0 && text[locs[i][0]-1] == '[' {
+			continue
+		}
+		start, end := locs[i][2], locs[i][3]
+		if keep(text[start:end]) {
+			text = text[:start] + placeholder + text[end:]
+		}
+	}
+	return text
+}
+
+// validSSN rejects numbers the SSA never issues: area 000, 666 or 9xx, group
+// 00 and serial 0000.
+func validSSN(area, group, serial string) bool {
+	return area != "000" && area != "666" && area[0] != '9' && group != "00" && serial != "0000"
+}
+
+func placeholderKinds(text string) []string {
+	var kinds []string
+	seen := make(map[string]bool)
+	for _, p := range placeholderRE.FindAllString(text, -1) {
+		kind := SensitiveCard
+		switch p {
+		case ssnPlaceholder:
+			kind = SensitiveSSN
+		case cvvPlaceholder:
+			kind = SensitiveCVV
+		}
+		if !seen[kind] {
+			seen[kind] = true
+			kinds = append(kinds, kind)
+		}
+	}
+	return kinds
+}
@@ -0,0 +1,48 @@
+package compliance
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestRedactSensitiveNumbers(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+		kinds []string
+	}{
+		{"card with spaces", "here's my card 4111 1111 1111 1111 to pay faster", "here's my card [CARD ending 1111] to pay faster", []string{SensitiveCard}},
+		{"card with dashes", "4242-4242-4242-4242", "[CARD ending 4242]", []string{SensitiveCard}},
+		{"card with expiry and code", "4111111111111111 exp 12/27 123", "[CARD ending 1111] exp 12/27 [CVV]", []string{SensitiveCard, SensitiveCVV}},
+		{"labeled cvv", "card ends 1111, cvv: 4321", "card ends 1111, cvv: [CVV]", []string{SensitiveCVV}},
+		{"grouped ssn", "my ssn is 123-45-6789", "my ssn is [SSN]", []string{SensitiveSSN}},
+		{"spaced ssn", "social 123 45 6789", "social [SSN]", []string{SensitiveSSN}},
+		{"labeled bare ssn", "SSN: 123456789", "SSN: [SSN]", []string{SensitiveSSN}},
+		{"invalid ssn area", "ref 000-12-3456", "ref 000-12-3456", nil},
+		{"phone number", "call me at 555-123-4567 or +1 (555) 987-6543", "call me at 555-123-4567 or +1 (555) 987-6543", nil},
+		{"phone digits", "my number is 15551234567", "my number is 15551234567", nil},
+		{"non-luhn digits", "order 1234567890123456", "order 1234567890123456", nil},
+		{"bare nine digits", "confirmation 123456789", "confirmation 123456789", nil},
+		{"card then phone", "4111111111111111 call 555-123-4567", "[CARD ending 1111] call 555-123-4567", []string{SensitiveCard}},
+		{"code after placeholder", "4111111111111111 123 456", "[CARD ending 1111] [CVV] 456", []string{SensitiveCard, SensitiveCVV}},
+		{"already redacted", "[CARD ending 1111] and [SSN]", "[CARD ending 1111] and [SSN]", []string{SensitiveCard, SensitiveSSN}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, kinds := RedactSensitiveNumbers(tt.input)
+			if got != tt.want {
+				t.Errorf("RedactSensitiveNumbers(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+			if !slices.Equal(kinds, tt.kinds) {
+				t.Errorf("kinds = %v, want %v", kinds, tt.kinds)
+			}
+			for _, secret := range []string{"4111111111111111", "4111 1111 1111 1111", "6789", "4321"} {
+				if len(kinds) > 0 && strings.Contains(tt.input, secret) && strings.Contains(got, secret) {
+					t.Errorf("redacted text %q still contains %q", got, secret)
+				}
+			}
+		})
+	}
+}
@@ -74,10 +74,12 @@ func (d *DisclaimerMessenger) SendReply(ctx context.Context, reply conversation.
 	if d == nil || d.service == nil || d.inner == nil {
 		return nil
 	}
+	ctx = replyLogContext(ctx, reply)
+	log := d.logger.WithContext(ctx)
 	isFirst := true
 	if d.firstOnly {
 		isFirst = d.isFirstAssistantMessage(ctx, reply.ConversationID)
-		d.logger.Info("disclaimer: first message check result", "conversation_id", reply.ConversationID, "is_first", isFirst, "first_only_mode", d.firstOnly)
+		log.Info("disclaimer: first message check result", "conversation_id", reply.ConversationID, "is_first", isFirst, "first_only_mode", d.firstOnly)
 	}
 	body, err := d.service.AddDisclaimer(ctx, reply.Body, compliance.DisclaimerOptions{
 		OrgID:          reply.OrgID,
@@ -86,10 +88,10 @@ func (d *DisclaimerMessenger) SendReply(ctx context.Context, reply conversation.
 		IsFirstMessage: isFirst,
 	})
 	if err != nil {
-		d.logger.Warn("failed to apply disclaimer", "error", err, "conversation_id", reply.ConversationID)
+		log.Warn("failed to apply disclaimer", "error", err, "conversation_id", reply.ConversationID)
 	} else {
 		if body != reply.Body {
-			d.logger.Info("disclaimer: added to message", "conversation_id", reply.ConversationID, "is_first", isFirst)
+			log.Info("disclaimer: added to message", "conversation_id", reply.ConversationID, "is_first", isFirst)
 		}
 		reply.Body = body
 	}
@@ -97,8 +99,9 @@ func (d *DisclaimerMessenger) SendReply(ctx context.Context, reply conversation.
 }
 
 func (d *DisclaimerMessenger) isFirstAssistantMessage(ctx context.Context, conversationID string) bool {
+	log := d.logger.WithContext(ctx)
 	if strings.TrimSpace(conversationID) == "" {
-		d.logger.Info("disclaimer: empty conversation ID, treating as first message")
+		log.Info("disclaimer: empty conversation ID, treating as first message")
 		return true
 	}
 	if ctx == nil {
@@ -107,7 +110,7 @@ func (d *DisclaimerMessenger) isFirstAssistantMessage(ctx context.Context, conve
 
 	// Check in-memory cache first (most reliable for this session)
 	if _, alreadySeen := d.seen.Load(conversationID); alreadySeen {
-		d.logger.Info("disclaimer: already seen in memory", "conversation_id", conversationID)
+		log.Info("disclaimer: already seen in memory", "conversation_id", conversationID)
 		return false
 	}
 
@@ -115,29 +118,29 @@ func (d *DisclaimerMessenger) isFirstAssistantMessage(ctx context.Context, conve
 	if d.conversation != nil {
 		has, err := d.conversation.HasAssistantMessage(ctx, conversationID)
 		if err == nil && has {
-			d.logger.Info("disclaimer: conversation store has assistant", "conversation_id", conversationID)
+			log.Info("disclaimer: conversation store has assistant", "conversation_id", conversationID)
 			d.seen.Store(conversationID, struct{}{})
 			return false
 		}
 		if err != nil {
-			d.logger.Warn("disclaimer: conversation store check failed", "error", err, "conversation_id", conversationID)
+			log.Warn("disclaimer: conversation store check failed", "error", err, "conversation_id", conversationID)
 		}
 	}
 	if d.transcriptStore != nil {
 		has, err := d.transcriptStore.HasAssistantMessage(ctx, conversationID)
 		if err == nil && has {
-			d.logger.Info("disclaimer: transcript store has assistant", "conversation_id", conversationID)
+			log.Info("disclaimer: transcript store has assistant", "conversation_id", conversationID)
 			d.seen.Store(conversationID, struct{}{})
 			return false
 		}
 		if err != nil {
-			d.logger.Warn("disclaimer: transcript store check failed", "error", err, "conversation_id", conversationID)
+			log.Warn("disclaimer: transcript store check failed", "error", err, "conversation_id", conversationID)
 		}
 	}
 
 	// First message for this conversation - mark as seen for future checks
 	d.seen.Store(conversationID, struct{}{})
-	d.logger.Info("disclaimer: first message, marking as seen", "conversation_id", conversationID)
+	log.Info("disclaimer: first message, marking as seen", "conversation_id", conversationID)
 	return true
 }
 
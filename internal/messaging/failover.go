@@ -4,18 +4,110 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus"
 
 	"github.com/wolfman30/medspa-ai-platform/internal/conversation"
 	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
 )
 
-// FailoverMessenger attempts a primary send, then falls back to a secondary provider on error.
+// Reply metadata keys senders fill in once a message is accepted, read by
+// PersistingMessenger to record who actually delivered it and what it cost.
+const (
+	metadataProvider     = "provider"
+	metadataProviderFrom = "provider_from"
+	metadataSegments     = "segments"
+	metadataCostUSD      = "cost_usd"
+)
+
+// Failover reasons, used as the metric's reason label.
+const (
+	failoverReasonTimeout      = "timeout"
+	failoverReasonServerError  = "server_error"
+	failoverReasonCarrierBlock = "carrier_block"
+)
+
+// carrierBlockCodes are delivery receipt error codes meaning a carrier
+// refused the sending number, not the recipient or the message. Another
+// provider's number can still get through. Carriers only report these after
+// the provider accepted the send, so they arrive on delivery receipts (DLRs),
+// never as a send error. Only Telnyx DLRs are received; Twilio sends aren't
+// given a status callback. Opt-outs are deliberately absent: a patient who
+// texted STOP must not be re-routed.
+var carrierBlockCodes = map[string]map[string]bool{
+	SMSProviderTelnyx: {
+		"40002": true, // blocked as spam (permanent)
+		"40003": true, // blocked as spam (temporary)
+	},
+}
+
+var smsFailoverTotal = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Namespace: "medspa",
+		Subsystem: "messaging",
+		Name:      "sms_failover_total",
+		Help:      "Outbound SMS re-sent through the secondary provider after the primary failed",
+	},
+	[]string{"primary", "secondary", "reason", "result"}, // result: delivered, failed
+)
+
+func init() {
+	prometheus.MustRegister(smsFailoverTotal)
+}
+
+// ProviderError is a send the SMS provider's API answered with an error.
+type ProviderError struct {
+	Provider   string
+	StatusCode int
+	// Code is the provider's own error code, such as Twilio's "30007".
+	Code    string
+	Message string
+}
+
+func (e *ProviderError) Error() string {
+	msg := fmt.Sprintf("%s send failed: status %d", e.Provider, e.StatusCode)
+	if e.Code != "" {
+		msg += " code " + e.Code
+	}
+	if e.Message != "" {
+		msg += ": " + e.Message
+	}
+	return msg
+}
+
+// failoverReason classifies a primary send error, returning "" when another
+// provider would fail the same way (bad recipient, opt-out, invalid body).
+func failoverReason(err error) string {
+	var providerErr *ProviderError
+	if errors.As(err, &providerErr) {
+		if providerErr.StatusCode >= 500 {
+			return failoverReasonServerError
+		}
+		return ""
+	}
+	var netErr net.Error
+	if errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout()) {
+		return failoverReasonTimeout
+	}
+	return ""
+}
+
+// FailoverMessenger sends through a primary provider and re-sends through a
+// secondary one when the primary times out or errors. Sends a carrier later
+// blocks are re-sent through DeliveryFailover. The secondary sends from the
+// org's own number on that provider, so orgs without one configured never
+// fail over.
 type FailoverMessenger struct {
-	primary       conversation.ReplyMessenger
-	secondary     conversation.ReplyMessenger
-	primaryName   string
-	secondaryName string
-	logger        *logging.Logger
+	primary         conversation.ReplyMessenger
+	secondary       conversation.ReplyMessenger
+	primaryName     string
+	secondaryName   string
+	failoverNumbers map[string]string
+	logger          *logging.Logger
 }
 
 // NewFailoverMessenger builds a failover messenger with named providers.
@@ -32,33 +124,147 @@ func NewFailoverMessenger(primary conversation.ReplyMessenger, primaryName strin
 	}
 }
 
+// SetFailoverNumbers sets the number, keyed by org ID, each org sends from
+// on the secondary provider.
+func (f *FailoverMessenger) SetFailoverNumbers(numbers map[string]string) {
+	f.failoverNumbers = numbers
+}
+
 var _ conversation.ReplyMessenger = (*FailoverMessenger)(nil)
 
-// SendReply tries the primary provider first, then falls back to the secondary provider on failure.
+// SendReply tries the primary provider first, then the secondary provider
+// for failures it can route around.
 func (f *FailoverMessenger) SendReply(ctx context.Context, reply conversation.OutboundReply) error {
 	if f == nil || f.primary == nil {
 		return errors.New("messaging: failover primary sender not configured")
 	}
-	if err := f.primary.SendReply(ctx, reply); err == nil {
+	err := f.primary.SendReply(ctx, reply)
+	if err == nil {
 		return nil
-	} else if f.secondary == nil {
+	}
+	if f.secondary == nil {
 		return fmt.Errorf("messaging: SendReply: %w", err)
-	} else {
-		f.logger.Warn("primary sms send failed; attempting fallback",
+	}
+
+	reason := failoverReason(err)
+	if reason == "" || ctx.Err() != nil {
+		return err
+	}
+	sent, fallbackErr := f.sendSecondary(ctx, reply, reason, err)
+	if !sent {
+		return err
+	}
+	return fallbackErr
+}
+
+// sendSecondary re-sends reply through the secondary provider from the org's
+// failover number. It returns false without sending when the org has none.
+func (f *FailoverMessenger) sendSecondary(ctx context.Context, reply conversation.OutboundReply, reason string, primaryErr error) (bool, error) {
+	from := strings.TrimSpace(f.failoverNumbers[reply.OrgID])
+	if from == "" {
+		f.logger.Warn("primary sms send failed; no failover number for org",
 			"provider", f.primaryName,
-			"fallback", f.secondaryName,
-			"error", err,
+			"reason", reason,
+			"error", primaryErr,
+			"org_id", reply.OrgID,
+		)
+		return false, nil
+	}
+
+	f.logger.Warn("primary sms send failed; attempting fallback",
+		"provider", f.primaryName,
+		"fallback", f.secondaryName,
+		"reason", reason,
+		"error", primaryErr,
+		"org_id", reply.OrgID,
+		"to", reply.To,
+	)
+	reply.From = from
+	if fallbackErr := f.secondary.SendReply(ctx, reply); fallbackErr != nil {
+		smsFailoverTotal.WithLabelValues(f.primaryName, f.secondaryName, reason, "failed").Inc()
+		f.logger.Error("fallback sms send failed",
+			"provider", f.secondaryName,
+			"error", fallbackErr,
+			"org_id", reply.OrgID,
 			"to", reply.To,
 		)
-		fallbackErr := f.secondary.SendReply(ctx, reply)
-		if fallbackErr != nil {
-			f.logger.Error("fallback sms send failed",
-				"provider", f.secondaryName,
-				"error", fallbackErr,
-				"to", reply.To,
-			)
-			return fallbackErr
-		}
+		return true, fallbackErr
+	}
+	smsFailoverTotal.WithLabelValues(f.primaryName, f.secondaryName, reason, "delivered").Inc()
+	return true, nil
+}
+
+// blockedMessageStore finds an outbound message by its provider ID and
+// records its re-send.
+type blockedMessageStore interface {
+	OutboundByProviderID(ctx context.Context, providerMessageID string) (*MessageRecord, error)
+	UpdateMessageStatusByID(ctx context.Context, msgID uuid.UUID, status string, deliveredAt, failedAt *time.Time) error
+	UpdateMessageProviderID(ctx context.Context, msgID uuid.UUID, providerMessageID string) error
+	UpdateMessageSender(ctx context.Context, msgID uuid.UUID, provider, from string) error
+}
+
+// DeliveryFailover re-sends through the secondary provider outbound messages
+// whose delivery receipt reports a carrier block on the primary's number.
+type DeliveryFailover struct {
+	messenger *FailoverMessenger
+	store     blockedMessageStore
+	logger    *logging.Logger
+}
+
+// NewDeliveryFailover returns a DeliveryFailover re-sending through
+// messenger's secondary provider, or nil when messenger doesn't fail over.
+func NewDeliveryFailover(messenger conversation.ReplyMessenger, store blockedMessageStore, logger *logging.Logger) *DeliveryFailover {
+	failover, ok := messenger.(*FailoverMessenger)
+	if !ok || failover == nil || failover.secondary == nil || store == nil {
 		return nil
 	}
+	if logger == nil {
+		logger = logging.Default()
+	}
+	return &DeliveryFailover{messenger: failover, store: store, logger: logger}
+}
+
+// ResendBlocked re-sends the message provider knows as providerMessageID when
+// its delivery receipt's error code is a carrier block, recording the
+// secondary provider, number and message ID on the stored message. It
+// reports whether the message was re-sent. A re-sent message carries the
+// secondary's ID, so a redelivered receipt finds nothing to re-send.
+func (d *DeliveryFailover) ResendBlocked(ctx context.Context, provider, providerMessageID, code string) (bool, error) {
+	if d == nil || provider != d.messenger.primaryName || !carrierBlockCodes[provider][strings.TrimSpace(code)] {
+		return false, nil
+	}
+	rec, err := d.store.OutboundByProviderID(ctx, providerMessageID)
+	if err != nil {
+		return false, fmt.Errorf("messaging: load blocked message: %w", err)
+	}
+	if rec == nil {
+		return false, nil
+	}
+	reply := conversation.OutboundReply{
+		OrgID:    rec.ClinicID.String(),
+		To:       rec.To,
+		From:     rec.From,
+		Body:     rec.Body,
+		Metadata: map[string]string{},
+	}
+	blockErr := &ProviderError{Provider: provider, Code: code, Message: "carrier block reported on delivery receipt"}
+	sent, err := d.messenger.sendSecondary(ctx, reply, failoverReasonCarrierBlock, blockErr)
+	if !sent || err != nil {
+		return false, err
+	}
+
+	status := strings.TrimSpace(reply.Metadata["provider_status"])
+	if status == "" {
+		status = "sent"
+	}
+	if err := d.store.UpdateMessageStatusByID(ctx, rec.ID, status, nil, nil); err != nil {
+		d.logger.Warn("failed to update re-sent message status", "error", err, "msg_id", rec.ID)
+	}
+	if err := d.store.UpdateMessageProviderID(ctx, rec.ID, reply.Metadata["provider_message_id"]); err != nil {
+		d.logger.Warn("failed to update re-sent message provider id", "error", err, "msg_id", rec.ID)
+	}
+	if err := d.store.UpdateMessageSender(ctx, rec.ID, reply.Metadata[metadataProvider], reply.Metadata[metadataProviderFrom]); err != nil {
+		d.logger.Warn("failed to update re-sent message sender", "error", err, "msg_id", rec.ID)
+	}
+	return true, nil
 }
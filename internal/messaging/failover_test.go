@@ -0,0 +1,227 @@
+package messaging
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/google/uuid"
+	pgxmock "github.com/pashagolub/pgxmock/v4"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/conversation"
+)
+
+// providerAPI fakes an SMS provider's HTTP API, recording each request form
+// or JSON body and answering with a fixed status and body.
+type providerAPI struct {
+	mu       sync.Mutex
+	status   int
+	body     string
+	requests []string
+}
+
+func (p *providerAPI) RoundTrip(req *http.Request) (*http.Response, error) {
+	raw, _ := io.ReadAll(req.Body)
+	p.mu.Lock()
+	p.requests = append(p.requests, string(raw))
+	p.mu.Unlock()
+	return &http.Response{
+		StatusCode: p.status,
+		Body:       io.NopCloser(strings.NewReader(p.body)),
+		Header:     http.Header{},
+		Request:    req,
+	}, nil
+}
+
+func (p *providerAPI) calls() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]string(nil), p.requests...)
+}
+
+func TestFailoverReason(t *testing.T) {
+	timeout := &url.Error{Op: "Post", URL: "https://api.telnyx.com/v2/messages", Err: timeoutError{}}
+	for name, tc := range map[string]struct {
+		err  error
+		want string
+	}{
+		"provider 5xx": {&ProviderError{Provider: SMSProviderTelnyx, StatusCode: 503}, failoverReasonServerError},
+		// Carrier blocks arrive on delivery receipts; DeliveryFailover handles them.
+		"telnyx spam block":   {fmt.Errorf("send: %w", &ProviderError{Provider: SMSProviderTelnyx, StatusCode: 403, Code: "40002"}), ""},
+		"twilio opt-out":      {&ProviderError{Provider: SMSProviderTwilio, StatusCode: 400, Code: "21610"}, ""},
+		"invalid recipient":   {&ProviderError{Provider: SMSProviderTelnyx, StatusCode: 422, Code: "40310"}, ""},
+		"http client timeout": {timeout, failoverReasonTimeout},
+		"deadline":            {fmt.Errorf("send: %w", context.DeadlineExceeded), failoverReasonTimeout},
+		"validation":          {errors.New("messaging: body required"), ""},
+	} {
+		if got := failoverReason(tc.err); got != tc.want {
+			t.Errorf("%s: failoverReason = %q, want %q", name, got, tc.want)
+		}
+	}
+}
+
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+func TestFailoverMessengerDeliversThroughSecondaryAndRecordsIt(t *testing.T) {
+	telnyxAPI := &providerAPI{status: http.StatusServiceUnavailable, body: `{"errors":[{"code":"10007","title":"Unexpected error"}]}`}
+	twilioAPI := &providerAPI{status: http.StatusCreated, body: `{"sid":"SM123","status":"queued"}`}
+	telnyx := NewTelnyxSender("telnyx-key", "profile", nil)
+	telnyx.httpClient = &http.Client{Transport: telnyxAPI}
+	twilio := NewTwilioSender("AC123", "token", "+15559990000", nil)
+	twilio.httpClient = &http.Client{Transport: twilioAPI}
+
+	failover := NewFailoverMessenger(telnyx, SMSProviderTelnyx, twilio, SMSProviderTwilio, nil)
+	orgID := uuid.New()
+	failover.SetFailoverNumbers(map[string]string{orgID.String(): "+15550002222"})
+
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("pgxmock: %v", err)
+	}
+	defer mock.Close()
+	msgID := uuid.New()
+	mock.ExpectQuery("INSERT INTO messages").
+		WithArgs(orgID, "+15550001111", "+15557770000", "outbound", "See you Tuesday!", pgxmock.AnyArg(), "pending", "", pgxmock.AnyArg(), pgxmock.AnyArg(), 1, pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg()).
+		WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(msgID))
+	mock.ExpectExec("UPDATE messages").
+		WithArgs(msgID, "queued", pgxmock.AnyArg(), pgxmock.AnyArg()).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	mock.ExpectExec("SET provider_message_id").
+		WithArgs(msgID, "SM123").
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	mock.ExpectExec("SET provider = \\$2").
+		WithArgs(msgID, SMSProviderTwilio, "+15550002222").
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	mock.ExpectExec("SET segments = \\$2").
+		WithArgs(msgID, 1, (*float64)(nil)).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+
+	before := testutil.ToFloat64(smsFailoverTotal.WithLabelValues(SMSProviderTelnyx, SMSProviderTwilio, failoverReasonServerError, "delivered"))
+	messenger := WrapWithPersistence(failover, &Store{pool: mock}, nil)
+	err = messenger.SendReply(context.Background(), conversation.OutboundReply{
+		OrgID: orgID.String(),
+		To:    "+15557770000",
+		From:  "+15550001111",
+		Body:  "See you Tuesday!",
+	})
+	if err != nil {
+		t.Fatalf("SendReply: %v", err)
+	}
+
+	if len(telnyxAPI.calls()) == 0 {
+		t.Fatal("expected the primary provider to be tried first")
+	}
+	sent := twilioAPI.calls()
+	if len(sent) != 1 {
+		t.Fatalf("expected one secondary send, got %d", len(sent))
+	}
+	form, _ := url.ParseQuery(sent[0])
+	if form.Get("From") != "+15550002222" || form.Get("To") != "+15557770000" {
+		t.Fatalf("secondary sent from %q to %q, want the org's twilio number", form.Get("From"), form.Get("To"))
+	}
+	if got := testutil.ToFloat64(smsFailoverTotal.WithLabelValues(SMSProviderTelnyx, SMSProviderTwilio, failoverReasonServerError, "delivered")); got != before+1 {
+		t.Fatalf("failover metric = %v, want %v", got, before+1)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestFailoverMessengerSkipsOrgsWithoutSecondaryNumber(t *testing.T) {
+	twilioAPI := &providerAPI{status: http.StatusCreated, body: `{"sid":"SM123"}`}
+	twilio := NewTwilioSender("AC123", "token", "+15559990000", nil)
+	twilio.httpClient = &http.Client{Transport: twilioAPI}
+	primary := &mockMessenger{err: &ProviderError{Provider: SMSProviderTelnyx, StatusCode: 503, Code: "10007"}}
+
+	failover := NewFailoverMessenger(primary, SMSProviderTelnyx, twilio, SMSProviderTwilio, nil)
+	failover.SetFailoverNumbers(map[string]string{"org-other": "+15550002222"})
+	err := failover.SendReply(context.Background(), conversation.OutboundReply{
+		OrgID: "org-1", To: "+15557770000", From: "+15550001111", Body: "hi",
+	})
+
+	var providerErr *ProviderError
+	if !errors.As(err, &providerErr) || providerErr.Code != "10007" {
+		t.Fatalf("expected the primary's error, got %v", err)
+	}
+	if calls := twilioAPI.calls(); len(calls) != 0 {
+		t.Fatalf("secondary must not send from a number the org doesn't own: %v", calls)
+	}
+}
+
+func TestDeliveryFailoverResendsCarrierBlockedMessage(t *testing.T) {
+	twilioAPI := &providerAPI{status: http.StatusCreated, body: `{"sid":"SM456","status":"queued"}`}
+	twilio := NewTwilioSender("AC123", "token", "+15559990000", nil)
+	twilio.httpClient = &http.Client{Transport: twilioAPI}
+	failover := NewFailoverMessenger(&mockMessenger{}, SMSProviderTelnyx, twilio, SMSProviderTwilio, nil)
+	orgID := uuid.New()
+	failover.SetFailoverNumbers(map[string]string{orgID.String(): "+15550002222"})
+
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("pgxmock: %v", err)
+	}
+	defer mock.Close()
+	msgID := uuid.New()
+	mock.ExpectQuery("FROM messages").
+		WithArgs("tx-msg-1").
+		WillReturnRows(pgxmock.NewRows([]string{"id", "clinic_id", "from_e164", "to_e164", "body", "mms_media", "provider_status", "provider_message_id"}).
+			AddRow(msgID, orgID, "+15550001111", "+15557770000", "See you Tuesday!", []byte(`[]`), "delivery_failed", "tx-msg-1"))
+	mock.ExpectExec("UPDATE messages").
+		WithArgs(msgID, "queued", pgxmock.AnyArg(), pgxmock.AnyArg()).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	mock.ExpectExec("SET provider_message_id").
+		WithArgs(msgID, "SM456").
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	mock.ExpectExec("SET provider = \\$2").
+		WithArgs(msgID, SMSProviderTwilio, "+15550002222").
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+
+	delivery := NewDeliveryFailover(failover, &Store{pool: mock}, nil)
+	// Codes that aren't carrier blocks, and receipts from the secondary, are left alone.
+	for _, tc := range []struct{ provider, code string }{{SMSProviderTelnyx, "40300"}, {SMSProviderTwilio, "40002"}} {
+		if resent, err := delivery.ResendBlocked(context.Background(), tc.provider, "tx-msg-1", tc.code); resent || err != nil {
+			t.Fatalf("%s %s: resent=%v err=%v, want no re-send", tc.provider, tc.code, resent, err)
+		}
+	}
+
+	before := testutil.ToFloat64(smsFailoverTotal.WithLabelValues(SMSProviderTelnyx, SMSProviderTwilio, failoverReasonCarrierBlock, "delivered"))
+	resent, err := delivery.ResendBlocked(context.Background(), SMSProviderTelnyx, "tx-msg-1", "40002")
+	if err != nil || !resent {
+		t.Fatalf("ResendBlocked = %v, %v; want a re-send", resent, err)
+	}
+	sent := twilioAPI.calls()
+	if len(sent) != 1 {
+		t.Fatalf("expected one secondary send, got %d", len(sent))
+	}
+	form, _ := url.ParseQuery(sent[0])
+	if form.Get("From") != "+15550002222" || form.Get("To") != "+15557770000" || form.Get("Body") != "See you Tuesday!" {
+		t.Fatalf("unexpected secondary send %v", form)
+	}
+	if got := testutil.ToFloat64(smsFailoverTotal.WithLabelValues(SMSProviderTelnyx, SMSProviderTwilio, failoverReasonCarrierBlock, "delivered")); got != before+1 {
+		t.Fatalf("failover metric = %v, want %v", got, before+1)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestTwilioProviderErrorKeepsCode(t *testing.T) {
+	err := twilioProviderError(http.StatusBadRequest, []byte(`{"code":30007,"message":"Message filtered","status":400}`))
+	if err.Code != "30007" || err.Message != "Message filtered" {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+	if got := err.Error(); got != "twilio send failed: status 400 code 30007: Message filtered" {
+		t.Fatalf("Error() = %q", got)
+	}
+}
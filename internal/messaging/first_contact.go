@@ -0,0 +1,66 @@
+package messaging
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/clinic"
+	"github.com/wolfman30/medspa-ai-platform/internal/leads"
+	"github.com/wolfman30/medspa-ai-platform/internal/messaging/templates"
+)
+
+// FirstContactService picks the value for the {{service}} placeholder: the
+// dialed number's default service, else the longest clinic service or alias
+// the message names. Returns "" when neither applies.
+func FirstContactService(cfg *clinic.Config, defaultService, body string) string {
+	if s := strings.TrimSpace(defaultService); s != "" {
+		return s
+	}
+	if cfg == nil {
+		return ""
+	}
+	text := strings.ToLower(body)
+	best := ""
+	consider := func(name string) {
+		name = strings.TrimSpace(name)
+		if name == "" || len(name) <= len(best) {
+			return
+		}
+		if strings.Contains(text, strings.ToLower(name)) {
+			best = name
+		}
+	}
+	for _, svc := range cfg.Services {
+		consider(svc)
+	}
+	for alias := range cfg.ServiceAliases {
+		consider(alias)
+	}
+	return best
+}
+
+// FirstContactGreeting renders the clinic's first-contact template for a new
+// lead and claims the lead's one-time greeting. It returns an empty body when
+// the clinic has no template or the lead was already greeted. If the claim
+// fails the greeting is still returned along with the error: carriers require
+// the compliance footer on first contact, so a rare repeat beats skipping it.
+func FirstContactGreeting(ctx context.Context, cfg *clinic.Config, repo leads.Repository, leadID, service string) (templates.Rendered, error) {
+	greeting := cfg.RenderFirstContact(service)
+	if greeting.Body == "" {
+		return templates.Rendered{}, nil
+	}
+	greeter, ok := repo.(leads.GreetingRepository)
+	if !ok || strings.TrimSpace(leadID) == "" {
+		return greeting, nil
+	}
+	claimed, err := greeter.MarkGreeted(ctx, leadID, time.Now())
+	if err != nil {
+		return greeting, fmt.Errorf("messaging: mark lead greeted: %w", err)
+	}
+	if !claimed {
+		return templates.Rendered{}, nil
+	}
+	return greeting, nil
+}
@@ -0,0 +1,162 @@
+package messaging
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/clinic"
+	"github.com/wolfman30/medspa-ai-platform/internal/conversation"
+	"github.com/wolfman30/medspa-ai-platform/internal/leads"
+	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
+)
+
+func TestFirstContactMessageRendersPlaceholders(t *testing.T) {
+	cfg := &clinic.Config{
+		Name:                 "Glow Med Spa",
+		Services:             []string{"Botox", "Dermal Filler"},
+		ServiceAliases:       map[string]string{"lip filler": "Dermal Filler"},
+		FirstContactTemplate: "Hi from {{clinic_name}}! Happy to help with {{service}}. Msg&data rates may apply, reply STOP to opt out.",
+	}
+
+	got := cfg.FirstContactMessage(FirstContactService(cfg, "", "do you have lip filler openings?"))
+	want := "Hi from Glow Med Spa! Happy to help with lip filler. Msg&data rates may apply, reply STOP to opt out."
+	if got != want {
+		t.Fatalf("rendered = %q, want %q", got, want)
+	}
+	if got := FirstContactService(cfg, "Hydrafacial", "botox please"); got != "Hydrafacial" {
+		t.Fatalf("expected the number's default service to win, got %q", got)
+	}
+	if got := cfg.FirstContactMessage(FirstContactService(cfg, "", "hello")); !strings.Contains(got, "help with our services") {
+		t.Fatalf("expected the generic service fallback, got %q", got)
+	}
+	if got := (&clinic.Config{Name: "Glow"}).FirstContactMessage("Botox"); got != "" {
+		t.Fatalf("expected no greeting without a template, got %q", got)
+	}
+}
+
+type recordingMessenger struct {
+	replies []conversation.OutboundReply
+}
+
+func (m *recordingMessenger) SendReply(ctx context.Context, reply conversation.OutboundReply) error {
+	m.replies = append(m.replies, reply)
+	return nil
+}
+
+func TestTwilioWebhook_FirstContactTemplateSentOnce(t *testing.T) {
+	mr := miniredis.RunT(t)
+	store := clinic.NewStore(redis.NewClient(&redis.Options{Addr: mr.Addr()}))
+	cfg := clinic.DefaultConfig("org-test")
+	cfg.Name = "Glow Med Spa"
+	cfg.FirstContactTemplate = "Welcome to {{clinic_name}}! Reply STOP to opt out."
+	if err := store.Set(context.Background(), cfg); err != nil {
+		t.Fatalf("set clinic config: %v", err)
+	}
+
+	pub := &stubPublisher{}
+	messenger := &recordingMessenger{}
+	leadRepo := leads.NewInMemoryRepository()
+	handler := NewHandler("", pub, NewStaticOrgResolver(map[string]string{"+15550001111": "org-test"}), messenger, leadRepo, logging.Default())
+	handler.SetClinicStore(store)
+
+	send := func(sid, body string) {
+		t.Helper()
+		form := url.Values{}
+		form.Set("MessageSid", sid)
+		form.Set("AccountSid", "AC123")
+		form.Set("From", "+15559998888")
+		form.Set("To", "+15550001111")
+		form.Set("Body", body)
+		req := httptest.NewRequest(http.MethodPost, "/messaging/twilio/webhook", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		rec := httptest.NewRecorder()
+		handler.TwilioWebhook(rec, req)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("status = %d", rec.Code)
+		}
+	}
+
+	send("SM1", "Hi, do you do Botox?")
+	if len(messenger.replies) != 1 || messenger.replies[0].Body != "Welcome to Glow Med Spa! Reply STOP to opt out." {
+		t.Fatalf("expected the rendered template first, got %+v", messenger.replies)
+	}
+	if messenger.replies[0].Metadata["kind"] != "first_contact" {
+		t.Fatalf("kind = %q, want first_contact", messenger.replies[0].Metadata["kind"])
+	}
+	if !pub.called || pub.lastJob != twilioJobID("SM1") {
+		t.Fatal("expected the message enqueued for the LLM reply")
+	}
+	lead, err := leadRepo.GetOrCreateByPhone(context.Background(), "org-test", "+15559998888", "twilio_sms", "")
+	if err != nil || lead.GreetedAt == nil {
+		t.Fatalf("expected the lead marked greeted (err %v)", err)
+	}
+
+	pub.called = false
+	send("SM2", "Tomorrow afternoon works")
+	for _, r := range messenger.replies[1:] {
+		if strings.Contains(r.Body, "Welcome to") {
+			t.Fatalf("template sent again: %q", r.Body)
+		}
+	}
+	if !pub.called || pub.lastJob != twilioJobID("SM2") {
+		t.Fatal("expected the second message enqueued for the LLM reply")
+	}
+}
+
+func TestTwilioWebhook_ClinicAckSettings(t *testing.T) {
+	mr := miniredis.RunT(t)
+	store := clinic.NewStore(redis.NewClient(&redis.Options{Addr: mr.Addr()}))
+	cfg := clinic.DefaultConfig("org-test")
+	cfg.Name = "Glow Med Spa"
+	cfg.AckTemplates = []string{"Thanks for texting {{clinic_name}}!"}
+	if err := store.Set(context.Background(), cfg); err != nil {
+		t.Fatalf("set clinic config: %v", err)
+	}
+
+	pub := &stubPublisher{}
+	messenger := &recordingMessenger{}
+	handler := NewHandler("", pub, NewStaticOrgResolver(map[string]string{"+15550001111": "org-test"}), messenger, leads.NewInMemoryRepository(), logging.Default())
+	handler.SetClinicStore(store)
+
+	send := func(sid, from string) {
+		t.Helper()
+		form := url.Values{}
+		form.Set("MessageSid", sid)
+		form.Set("AccountSid", "AC123")
+		form.Set("From", from)
+		form.Set("To", "+15550001111")
+		form.Set("Body", "Hi, do you do Botox?")
+		req := httptest.NewRequest(http.MethodPost, "/messaging/twilio/webhook", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		rec := httptest.NewRecorder()
+		handler.TwilioWebhook(rec, req)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("status = %d", rec.Code)
+		}
+	}
+
+	send("SM1", "+15559998888")
+	if len(messenger.replies) != 1 || messenger.replies[0].Body != "Thanks for texting Glow Med Spa!" {
+		t.Fatalf("expected the clinic ack, got %+v", messenger.replies)
+	}
+
+	cfg.DisableAcks = true
+	if err := store.Set(context.Background(), cfg); err != nil {
+		t.Fatalf("set clinic config: %v", err)
+	}
+	pub.called = false
+	send("SM2", "+15559997777")
+	if len(messenger.replies) != 1 {
+		t.Fatalf("expected no ack with acks disabled, got %+v", messenger.replies[1:])
+	}
+	if !pub.called || pub.lastJob != twilioJobID("SM2") {
+		t.Fatal("expected the message still enqueued for the LLM reply")
+	}
+}
@@ -15,8 +15,13 @@ import (
 	"github.com/google/uuid"
 	"github.com/wolfman30/medspa-ai-platform/internal/clinic"
 	"github.com/wolfman30/medspa-ai-platform/internal/conversation"
+	"github.com/wolfman30/medspa-ai-platform/internal/http/apierror"
 	"github.com/wolfman30/medspa-ai-platform/internal/leads"
+	"github.com/wolfman30/medspa-ai-platform/internal/messaging/compliance"
+	"github.com/wolfman30/medspa-ai-platform/internal/messaging/templates"
+	observemetrics "github.com/wolfman30/medspa-ai-platform/internal/observability/metrics"
 	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
+	"github.com/wolfman30/medspa-ai-platform/pkg/phone"
 )
 
 var twilioTracer = otel.Tracer("medspa.internal.messaging.twilio")
@@ -26,6 +31,18 @@ type conversationPublisher interface {
 	EnqueueMessage(ctx context.Context, jobID string, req conversation.MessageRequest, opts ...conversation.PublishOption) error
 }
 
+// SensitiveDataAuditor records that card numbers, security codes or SSNs
+// were redacted from an inbound message.
+type SensitiveDataAuditor interface {
+	LogSensitiveDataRedacted(ctx context.Context, orgID, conversationID, leadID string, kinds []string) error
+}
+
+// InboundRecorder notes that a clinic's number received a message, for the
+// inbound liveness check.
+type InboundRecorder interface {
+	RecordInbound(ctx context.Context, orgID string, at time.Time) error
+}
+
 type conversationStore interface {
 	AppendMessage(ctx context.Context, conversationID string, msg conversation.SMSTranscriptMessage) error
 	LinkLead(ctx context.Context, conversationID string, leadID uuid.UUID) error
@@ -40,6 +57,14 @@ type Handler struct {
 	leads         leads.Repository
 	convStore     conversationStore
 	clinicStore   *clinic.Store
+	store         inboundMessageStore
+	processed     processedTracker
+	detector      *compliance.Detector
+	metrics       *observemetrics.MessagingMetrics
+	audit         SensitiveDataAuditor
+	liveness      InboundRecorder
+	ackOverrides  templates.Overrides
+	trackJobs     bool
 	skipSignature bool
 	publicBaseURL string
 	logger        *logging.Logger
@@ -62,6 +87,7 @@ func NewHandler(webhookSecret string, publisher conversationPublisher, resolver
 		orgResolver:   resolver,
 		messenger:     messenger,
 		leads:         leadsRepo,
+		detector:      compliance.NewDetector(),
 		logger:        logger,
 	}
 }
@@ -82,6 +108,65 @@ func (h *Handler) SetClinicStore(store *clinic.Store) {
 	h.clinicStore = store
 }
 
+// SetMessageStore attaches the messaging store used to persist inbound SMS and opt-outs.
+func (h *Handler) SetMessageStore(store *Store) {
+	if h == nil || store == nil {
+		return
+	}
+	h.store = store
+}
+
+// SetProcessedTracker enables MessageSid deduplication of Twilio webhooks.
+func (h *Handler) SetProcessedTracker(tracker processedTracker) {
+	if h == nil {
+		return
+	}
+	h.processed = tracker
+}
+
+// SetComplianceAcks overrides the STOP/HELP/START auto-replies. Empty values keep the defaults.
+// A clinic's own MessageTemplates still win.
+func (h *Handler) SetComplianceAcks(stop, help, start string) {
+	if h == nil {
+		return
+	}
+	h.ackOverrides = ComplianceAckOverrides(stop, help, start)
+}
+
+// SetMetrics attaches messaging metrics for inbound webhook counters and latency.
+func (h *Handler) SetMetrics(metrics *observemetrics.MessagingMetrics) {
+	if h == nil {
+		return
+	}
+	h.metrics = metrics
+}
+
+// SetSensitiveDataAuditor records redacted card numbers and SSNs in the
+// compliance audit trail.
+func (h *Handler) SetSensitiveDataAuditor(audit SensitiveDataAuditor) {
+	if h == nil {
+		return
+	}
+	h.audit = audit
+}
+
+// SetInboundRecorder records each inbound message's arrival for the inbound
+// liveness check.
+func (h *Handler) SetInboundRecorder(recorder InboundRecorder) {
+	if h == nil {
+		return
+	}
+	h.liveness = recorder
+}
+
+// SetTrackJobs enables job status tracking for published conversation jobs.
+func (h *Handler) SetTrackJobs(track bool) {
+	if h == nil {
+		return
+	}
+	h.trackJobs = track
+}
+
 // SetPublicBaseURL configures the externally-visible base URL for webhook signature validation.
 func (h *Handler) SetPublicBaseURL(baseURL string) {
 	if h == nil {
@@ -105,12 +190,14 @@ func (h *Handler) SetSkipSignature(skip bool) {
 func (h *Handler) TwilioWebhook(w http.ResponseWriter, r *http.Request) {
 	ctx, span := twilioTracer.Start(r.Context(), "messaging.twilio.webhook")
 	defer span.End()
+	span.SetAttributes(logging.SpanAttributes(ctx)...)
+	log := h.logger.WithContext(ctx)
+	started := time.Now()
 
-	webhookURL := buildAbsoluteURL(r, h.publicBaseURL)
 	if h.webhookSecret != "" && !h.skipSignature {
-		if !ValidateTwilioSignature(r, h.webhookSecret, webhookURL) {
-			h.logger.Warn("invalid twilio signature")
-			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		if !h.validSignature(r) {
+			log.Warn("invalid twilio signature")
+			apierror.Write(w, r, apierror.CodeUnauthorized, "Unauthorized")
 			span.RecordError(errors.New("invalid twilio signature"))
 			return
 		}
@@ -118,8 +205,8 @@ func (h *Handler) TwilioWebhook(w http.ResponseWriter, r *http.Request) {
 
 	webhook, err := ParseTwilioWebhook(r)
 	if err != nil {
-		h.logger.Error("failed to parse twilio webhook", "error", err)
-		http.Error(w, "Bad Request", http.StatusBadRequest)
+		log.Error("failed to parse twilio webhook", "error", err)
+		apierror.Write(w, r, apierror.CodeValidationFailed, "Bad Request")
 		span.RecordError(err)
 		return
 	}
@@ -131,79 +218,202 @@ func (h *Handler) TwilioWebhook(w http.ResponseWriter, r *http.Request) {
 		attribute.String("medspa.twilio.to", to),
 	)
 
+	webhook.Body = conversation.NormalizeInboundText(webhook.Body)
 	if webhook.MessageSid == "" || from == "" || webhook.Body == "" {
 		err := errors.New("missing required twilio fields")
-		h.logger.Error("invalid twilio payload", "error", err)
-		http.Error(w, "Bad Request", http.StatusBadRequest)
+		log.Error("invalid twilio payload", "error", err)
+		apierror.Write(w, r, apierror.CodeValidationFailed, "Bad Request")
 		span.RecordError(err)
 		return
 	}
 
-	orgID, err := h.orgResolver.ResolveOrgID(ctx, webhook.To)
+	if h.processed != nil {
+		if processed, err := h.processed.AlreadyProcessed(ctx, twilioProcessedProvider, webhook.MessageSid); err != nil {
+			log.Error("processed lookup failed", "error", err, "message_sid", webhook.MessageSid)
+			apierror.Write(w, r, apierror.CodeInternal, "server error")
+			span.RecordError(err)
+			return
+		} else if processed {
+			log.Info("twilio inbound dedupe: already processed", "message_sid", webhook.MessageSid)
+			writeEmptyTwiML(w)
+			return
+		}
+	}
+
+	route, err := h.orgResolver.ResolveRoute(ctx, webhook.To)
 	if err != nil {
-		h.logger.Error("failed to resolve org for twilio number", "error", err, "to", webhook.To)
-		http.Error(w, "Unknown destination number", http.StatusBadRequest)
+		log.Error("failed to resolve org for twilio number", "error", err, "to", webhook.To)
+		apierror.Write(w, r, apierror.CodeValidationFailed, "Unknown destination number")
 		span.RecordError(err)
 		return
 	}
+	orgID := route.OrgID
 	span.SetAttributes(attribute.String("medspa.org_id", orgID))
+	conversationID := deterministicConversationID(orgID, from)
+	ctx = logging.WithFields(ctx, logging.Fields{OrgID: orgID, ConversationID: conversationID})
+	log = h.logger.WithContext(ctx)
 
-	jobID := webhook.MessageSid
-	leadID, isNewLead, err := h.ensureLead(r.Context(), orgID, from, "twilio_sms")
+	if h.liveness != nil {
+		if err := h.liveness.RecordInbound(ctx, orgID, time.Now()); err != nil {
+			log.Warn("failed to record inbound liveness", "error", err)
+		}
+	}
+
+	panRedacted, sensitive := compliance.RedactSensitiveNumbers(webhook.Body)
+	redactedBody, _ := conversation.RedactSensitive(panRedacted)
+	inbound, err := h.recordInbound(ctx, orgID, webhook, from, to, redactedBody)
 	if err != nil {
-		h.logger.Error("failed to persist lead", "error", err, "org_id", orgID, "from", from)
-		http.Error(w, "Failed to persist lead", http.StatusInternalServerError)
+		log.Error("failed to persist twilio inbound message", "error", err, "org_id", orgID, "message_sid", webhook.MessageSid)
+		apierror.WriteError(w, r, err, apierror.CodeInternal, "Failed to persist message")
 		span.RecordError(err)
 		return
 	}
-	conversationID := deterministicConversationID(orgID, from)
-	redactedBody, _ := conversation.RedactSensitive(webhook.Body)
+	h.metrics.ObserveInbound(twilioInboundEventType, "received")
 
 	h.appendConversationMessage(ctx, conversationID, conversation.SMSTranscriptMessage{
-		ID:   twilioMessageUUID(webhook.MessageSid),
-		Role: "user",
-		From: from,
-		To:   to,
-		Body: redactedBody,
-		Kind: "inbound",
+		ID:                twilioMessageUUID(webhook.MessageSid),
+		Role:              "user",
+		From:              from,
+		To:                to,
+		Body:              redactedBody,
+		Kind:              "inbound",
+		ProviderMessageID: webhook.MessageSid,
 	})
+
+	switch {
+	case inbound.stop:
+		h.sendAutoReply(orgID, conversationID, from, to, webhook.MessageSid, h.complianceReply(ctx, orgID, templates.ComplianceStopAck), "stop_ack")
+	case inbound.help:
+		h.sendAutoReply(orgID, conversationID, from, to, webhook.MessageSid, h.complianceReply(ctx, orgID, templates.ComplianceHelpAck), "help_ack")
+	case inbound.start:
+		h.sendAutoReply(orgID, conversationID, from, to, webhook.MessageSid, h.complianceReply(ctx, orgID, templates.ComplianceStartAck), "start_ack")
+	case inbound.unsubscribed:
+		// Opted-out patients get no replies until they text START.
+	case len(sensitive) > 0:
+		h.auditSensitiveData(ctx, orgID, conversationID, sensitive)
+		h.sendAutoReply(orgID, conversationID, from, to, webhook.MessageSid, h.complianceReply(ctx, orgID, templates.CompliancePCIGuardrail), "pci_guardrail")
+	case h.replyIfInactive(ctx, orgID, conversationID, from, to, webhook.MessageSid):
+		// Offboarded clinic: the farewell replaces the conversation.
+	default:
+		if err := h.dispatchConversation(ctx, webhook, inbound, route, conversationID, from, to, panRedacted); err != nil {
+			log.Error("failed to dispatch twilio conversation", "error", err, "org_id", orgID, "message_sid", webhook.MessageSid)
+			apierror.WriteError(w, r, err, apierror.CodeInternal, "Failed to schedule reply")
+			span.RecordError(err)
+			return
+		}
+	}
+
+	if h.processed != nil {
+		if _, err := h.processed.MarkProcessed(ctx, twilioProcessedProvider, webhook.MessageSid); err != nil {
+			log.Error("failed to mark twilio message processed", "error", err, "message_sid", webhook.MessageSid)
+		}
+	}
+	h.metrics.ObserveWebhookLatency(twilioInboundEventType, time.Since(started).Seconds())
+
+	log.Info("twilio webhook accepted", "org_id", orgID, "conversation_id", conversationID)
+	writeEmptyTwiML(w)
+}
+
+// auditSensitiveData records a redaction without the message itself.
+func (h *Handler) auditSensitiveData(ctx context.Context, orgID, conversationID string, kinds []string) {
+	h.logger.WithContext(ctx).Warn("sensitive numbers redacted from inbound sms", "org_id", orgID, "kinds", kinds)
+	if h.audit == nil {
+		return
+	}
+	if err := h.audit.LogSensitiveDataRedacted(ctx, orgID, conversationID, "", kinds); err != nil {
+		h.logger.WithContext(ctx).Warn("failed to audit sensitive data redaction", "error", err, "org_id", orgID)
+	}
+}
+
+// dispatchConversation upserts the lead, sends the first-contact ack, and
+// publishes the conversation job the same way the Telnyx inbound path does.
+func (h *Handler) dispatchConversation(ctx context.Context, webhook *TwilioWebhookRequest, inbound twilioInbound, route NumberRoute, conversationID, from, to, body string) error {
+	orgID := route.OrgID
+	leadID, isNewLead, err := h.ensureLead(ctx, orgID, from, "twilio_sms")
+	if err != nil {
+		return fmt.Errorf("persist lead: %w", err)
+	}
 	h.linkLead(ctx, conversationID, leadID)
+
+	isFirstContact := isNewLead
+	if inbound.persisted {
+		isFirstContact = inbound.firstInbound
+	}
 	// Only send instant ack for first contact — follow-ups get LLM reply directly (~2-3s).
-	if isNewLead {
-		h.sendSMSAck(from, to, orgID, leadID, conversationID, webhook.MessageSid, true)
+	// Clinics with a first-contact template send it in place of the generic ack.
+	if isFirstContact {
+		if greeting := h.firstContactGreeting(ctx, route, leadID, body); greeting.Body != "" {
+			h.sendLeadReply(from, to, orgID, leadID, conversationID, webhook.MessageSid, greeting, "first_contact")
+		} else {
+			h.sendSMSAck(ctx, from, to, orgID, leadID, conversationID, webhook.MessageSid)
+		}
 	}
 
 	msgReq := conversation.MessageRequest{
 		OrgID:          orgID,
 		LeadID:         leadID,
 		ConversationID: conversationID,
-		Message:        webhook.Body,
+		Message:        body,
 		ClinicID:       orgID,
 		Channel:        conversation.ChannelSMS,
 		From:           from,
 		To:             to,
-		Metadata: map[string]string{
+		Metadata: WithRequestID(ctx, withRouteMetadata(map[string]string{
 			"twilio_message_sid": webhook.MessageSid,
 			"twilio_account_sid": webhook.AccountSid,
-		},
+			"direction":          "inbound",
+		}, route)),
+		ReceivedAt: time.Now().UTC(),
 	}
 
+	opts := []conversation.PublishOption{conversation.WithoutJobTracking()}
+	if h.trackJobs {
+		opts = nil
+	}
 	publishCtx, cancel := context.WithTimeout(ctx, 3*time.Second)
 	defer cancel()
-	if err := h.publisher.EnqueueMessage(publishCtx, jobID, msgReq, conversation.WithoutJobTracking()); err != nil {
-		h.logger.Error("failed to enqueue conversation job", "error", err, "org_id", orgID, "message_sid", webhook.MessageSid)
-		http.Error(w, "Failed to schedule reply", http.StatusInternalServerError)
-		span.RecordError(err)
-		return
+	if err := h.publisher.EnqueueMessage(publishCtx, twilioJobID(webhook.MessageSid), msgReq, opts...); err != nil {
+		return fmt.Errorf("enqueue conversation job: %w", err)
 	}
+	return nil
+}
 
-	h.logger.Info("twilio webhook accepted", "org_id", orgID, "lead_id", leadID, "conversation_id", conversationID)
+func twilioJobID(messageSid string) string {
+	return "twilio:" + messageSid
+}
+
+func writeEmptyTwiML(w http.ResponseWriter) {
 	w.Header().Set("Content-Type", "application/xml")
 	w.WriteHeader(http.StatusOK)
 	_, _ = w.Write([]byte(`<?xml version="1.0" encoding="UTF-8"?><Response></Response>`))
 }
 
-func (h *Handler) sendSMSAck(to, from, orgID, leadID, conversationID, messageSid string, isNewLead bool) {
+// sendSMSAck sends the clinic's instant ack, unless the clinic disabled acks.
+func (h *Handler) sendSMSAck(ctx context.Context, to, from, orgID, leadID, conversationID, messageSid string) {
+	ack := h.clinicConfig(ctx, orgID).RenderAck(messageSid)
+	if ack.Body == "" {
+		return
+	}
+	h.sendLeadReply(to, from, orgID, leadID, conversationID, messageSid, ack, "sms_ack")
+}
+
+// firstContactGreeting returns the clinic's first-contact template for a new
+// lead, or an empty body to fall back to the generic ack.
+func (h *Handler) firstContactGreeting(ctx context.Context, route NumberRoute, leadID, body string) templates.Rendered {
+	cfg := h.clinicConfig(ctx, route.OrgID)
+	if cfg == nil {
+		return templates.Rendered{}
+	}
+	greeting, err := FirstContactGreeting(ctx, cfg, h.leads, leadID, FirstContactService(cfg, route.DefaultService, body))
+	if err != nil {
+		h.logger.Warn("failed to record first-contact greeting", "error", err, "org_id", route.OrgID, "lead_id", leadID)
+	}
+	return greeting
+}
+
+// sendLeadReply sends an instant, non-LLM reply to a lead and records it in
+// the transcript.
+func (h *Handler) sendLeadReply(to, from, orgID, leadID, conversationID, messageSid string, msg templates.Rendered, kind string) {
 	if h.messenger == nil {
 		return
 	}
@@ -211,7 +421,6 @@ func (h *Handler) sendSMSAck(to, from, orgID, leadID, conversationID, messageSid
 		return
 	}
 
-	ackMsg := GetSmsAckMessage(isNewLead)
 	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
 	defer cancel()
 
@@ -221,21 +430,22 @@ func (h *Handler) sendSMSAck(to, from, orgID, leadID, conversationID, messageSid
 		ConversationID: conversationID,
 		To:             to,
 		From:           from,
-		Body:           ackMsg,
-		Metadata: map[string]string{
+		Body:           msg.Body,
+		Metadata: msg.Stamp(map[string]string{
 			"twilio_message_sid": messageSid,
-			"kind":               "sms_ack",
-		},
+			"kind":               kind,
+		}),
 	}
 	if err := h.messenger.SendReply(ctx, reply); err != nil {
-		h.logger.Warn("failed to send sms ack", "error", err, "org_id", orgID)
+		h.logger.Warn("failed to send instant reply", "error", err, "org_id", orgID, "kind", kind)
 	}
 	h.appendConversationMessage(context.Background(), conversationID, conversation.SMSTranscriptMessage{
-		Role: "assistant",
-		From: from,
-		To:   to,
-		Body: ackMsg,
-		Kind: "sms_ack",
+		Role:     "assistant",
+		From:     from,
+		To:       to,
+		Body:     msg.Body,
+		Kind:     kind,
+		Metadata: msg.Stamp(nil),
 	})
 }
 
@@ -244,18 +454,17 @@ func (h *Handler) TwilioVoiceWebhook(w http.ResponseWriter, r *http.Request) {
 	ctx, span := twilioTracer.Start(r.Context(), "messaging.twilio.voice")
 	defer span.End()
 
-	webhookURL := buildAbsoluteURL(r, h.publicBaseURL)
 	if h.webhookSecret != "" && !h.skipSignature {
-		if !ValidateTwilioSignature(r, h.webhookSecret, webhookURL) {
+		if !h.validSignature(r) {
 			h.logger.Warn("invalid twilio voice signature")
-			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			apierror.Write(w, r, apierror.CodeUnauthorized, "Unauthorized")
 			span.RecordError(errors.New("invalid twilio voice signature"))
 			return
 		}
 	}
 	if err := r.ParseForm(); err != nil {
 		h.logger.Error("failed to parse twilio voice form", "error", err)
-		http.Error(w, "Bad Request", http.StatusBadRequest)
+		apierror.Write(w, r, apierror.CodeValidationFailed, "Bad Request")
 		span.RecordError(err)
 		return
 	}
@@ -267,11 +476,17 @@ func (h *Handler) TwilioVoiceWebhook(w http.ResponseWriter, r *http.Request) {
 	if callSid == "" || from == "" || to == "" {
 		err := errors.New("missing required twilio voice fields")
 		h.logger.Error("invalid twilio voice payload", "error", err)
-		http.Error(w, "Bad Request", http.StatusBadRequest)
+		apierror.Write(w, r, apierror.CodeValidationFailed, "Bad Request")
 		span.RecordError(err)
 		return
 	}
 	if !isMissedCallStatus(callStatus) {
+		// Clinics with the IVR enabled answer with a "press 1 to get a text"
+		// greeting instead of rejecting the call.
+		if cfg := h.ivrClinicConfig(ctx, to); cfg != nil {
+			writeTwiML(w, IVRGreetingXML(IVRGreetingForClinic(cfg), TwilioIVRPaths, from, to))
+			return
+		}
 		// Return TwiML that rejects the call - this will trigger a status callback
 		// with "no-answer" status, which will then trigger the missed call SMS flow
 		w.Header().Set("Content-Type", "application/xml")
@@ -280,13 +495,14 @@ func (h *Handler) TwilioVoiceWebhook(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	orgID, err := h.orgResolver.ResolveOrgID(ctx, to)
+	route, err := h.orgResolver.ResolveRoute(ctx, to)
 	if err != nil {
 		h.logger.Error("failed to resolve org for twilio voice number", "error", err, "to", to)
-		http.Error(w, "Unknown destination number", http.StatusBadRequest)
+		apierror.Write(w, r, apierror.CodeValidationFailed, "Unknown destination number")
 		span.RecordError(err)
 		return
 	}
+	orgID := route.OrgID
 	span.SetAttributes(
 		attribute.String("medspa.org_id", orgID),
 		attribute.String("medspa.twilio.call_sid", callSid),
@@ -298,49 +514,60 @@ func (h *Handler) TwilioVoiceWebhook(w http.ResponseWriter, r *http.Request) {
 	leadID, _, err := h.ensureLead(r.Context(), orgID, from, "twilio_voice")
 	if err != nil {
 		h.logger.Error("failed to persist lead", "error", err, "org_id", orgID, "from", from)
-		http.Error(w, "Failed to persist lead", http.StatusInternalServerError)
+		apierror.WriteError(w, r, err, apierror.CodeInternal, "Failed to persist lead")
+		span.RecordError(err)
+		return
+	}
+	metadata := withRouteMetadata(map[string]string{
+		"twilio_call_sid":    callSid,
+		"twilio_call_status": callStatus,
+	}, route)
+	if err := h.startMissedCallText(ctx, orgID, leadID, from, to, callSid, "twilio_voice", metadata); err != nil {
+		h.logger.Error("failed to enqueue missed-call conversation start", "error", err, "org_id", orgID, "call_sid", callSid)
+		apierror.WriteError(w, r, err, apierror.CodeInternal, "Failed to schedule reply")
 		span.RecordError(err)
 		return
 	}
+
+	writeEmptyTwiML(w)
+}
+
+// startMissedCallText enqueues the silent SMS conversation start for a caller
+// and sends the instant ack text.
+func (h *Handler) startMissedCallText(ctx context.Context, orgID, leadID, from, to, callSid, source string, metadata map[string]string) error {
 	conversationID := deterministicConversationID(orgID, from)
+	if h.replyIfInactive(ctx, orgID, conversationID, from, to, callSid) {
+		return nil
+	}
 	// Get ack message first so we can include it in the StartRequest for history
-	ackMsg := InstantAckMessageForClinic(h.clinicName(ctx, orgID))
+	ack := MissedCallAck(h.clinicConfig(ctx, orgID))
 
 	startReq := conversation.StartRequest{
 		OrgID:          orgID,
 		LeadID:         leadID,
 		ConversationID: conversationID,
 		Intro:          "We just missed your call. I can help you book an appointment or answer questions right here.",
-		Source:         "twilio_voice",
+		Source:         source,
 		ClinicID:       orgID,
 		Channel:        conversation.ChannelSMS,
 		From:           from,
 		To:             to,
 		Silent:         true,
-		AckMessage:     ackMsg, // Include ack message so AI knows what was already sent
-		Metadata: map[string]string{
-			"twilio_call_sid":    callSid,
-			"twilio_call_status": callStatus,
-		},
+		AckMessage:     ack.Body, // Include ack message so AI knows what was already sent
+		Metadata:       metadata,
 	}
 
 	publishCtx, cancel := context.WithTimeout(ctx, 3*time.Second)
 	defer cancel()
 	if err := h.publisher.EnqueueStart(publishCtx, callSid, startReq, conversation.WithoutJobTracking()); err != nil {
-		h.logger.Error("failed to enqueue missed-call conversation start", "error", err, "org_id", orgID, "call_sid", callSid)
-		http.Error(w, "Failed to schedule reply", http.StatusInternalServerError)
-		span.RecordError(err)
-		return
+		return err
 	}
 
-	h.sendImmediateAckWithMessage(from, to, orgID, leadID, conversationID, callSid, ackMsg)
-
-	w.Header().Set("Content-Type", "application/xml")
-	w.WriteHeader(http.StatusOK)
-	_, _ = w.Write([]byte(`<?xml version="1.0" encoding="UTF-8"?><Response></Response>`))
+	h.sendImmediateAckWithMessage(from, to, orgID, leadID, conversationID, callSid, ack)
+	return nil
 }
 
-func (h *Handler) sendImmediateAckWithMessage(to, from, orgID, leadID, conversationID, callSid, ackMsg string) {
+func (h *Handler) sendImmediateAckWithMessage(to, from, orgID, leadID, conversationID, callSid string, ack templates.Rendered) {
 	if h.messenger == nil {
 		return
 	}
@@ -356,24 +583,29 @@ func (h *Handler) sendImmediateAckWithMessage(to, from, orgID, leadID, conversat
 		ConversationID: conversationID,
 		To:             to,
 		From:           from,
-		Body:           ackMsg,
-		Metadata: map[string]string{
+		Body:           ack.Body,
+		Metadata: ack.Stamp(map[string]string{
 			"twilio_call_sid": callSid,
 			"kind":            "missed_call_ack",
-		},
+		}),
 	}
 	if err := h.messenger.SendReply(ctx, reply); err != nil {
 		h.logger.Warn("failed to send missed-call ack sms", "error", err, "org_id", orgID, "call_sid", callSid)
 	}
 }
 
-func (h *Handler) clinicName(ctx context.Context, orgID string) string {
+// complianceReply renders a compliance or guardrail reply for orgID's clinic.
+func (h *Handler) complianceReply(ctx context.Context, orgID, id string) templates.Rendered {
+	return ComplianceReply(id, h.ackOverrides, h.clinicConfig(ctx, orgID))
+}
+
+func (h *Handler) clinicConfig(ctx context.Context, orgID string) *clinic.Config {
 	if h == nil || h.clinicStore == nil {
-		return ""
+		return nil
 	}
 	orgID = strings.TrimSpace(orgID)
 	if orgID == "" {
-		return ""
+		return nil
 	}
 	if ctx == nil {
 		ctx = context.Background()
@@ -381,12 +613,9 @@ func (h *Handler) clinicName(ctx context.Context, orgID string) string {
 	cfg, err := h.clinicStore.Get(ctx, orgID)
 	if err != nil {
 		h.logger.Warn("failed to load clinic config", "error", err, "org_id", orgID)
-		return ""
-	}
-	if cfg == nil {
-		return ""
+		return nil
 	}
-	return strings.TrimSpace(cfg.Name)
+	return cfg
 }
 
 func isMissedCallStatus(status string) bool {
@@ -410,11 +639,11 @@ func (h *Handler) HealthCheck(w http.ResponseWriter, r *http.Request) {
 }
 
 func deterministicLeadID(orgID, from string) string {
-	return fmt.Sprintf("%s:%s", orgID, sanitizePhone(from))
+	return fmt.Sprintf("%s:%s", orgID, phone.Digits(from))
 }
 
 func deterministicConversationID(orgID, from string) string {
-	return fmt.Sprintf("sms:%s:%s", orgID, sanitizePhone(from))
+	return fmt.Sprintf("sms:%s:%s", orgID, phone.Digits(from))
 }
 
 func (h *Handler) appendConversationMessage(ctx context.Context, conversationID string, msg conversation.SMSTranscriptMessage) {
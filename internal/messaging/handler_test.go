@@ -14,6 +14,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/wolfman30/medspa-ai-platform/internal/conversation"
 	"github.com/wolfman30/medspa-ai-platform/internal/leads"
+	"github.com/wolfman30/medspa-ai-platform/internal/tenancy"
 	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
 )
 
@@ -326,9 +327,15 @@ type stubPublisher struct {
 	lastReq    conversation.MessageRequest
 	lastStart  conversation.StartRequest
 	startJobID string
+	voicemails []conversation.VoicemailRequest
 	err        error
 }
 
+func (s *stubPublisher) EnqueueVoicemail(ctx context.Context, req conversation.VoicemailRequest) error {
+	s.voicemails = append(s.voicemails, req)
+	return s.err
+}
+
 func (s *stubPublisher) EnqueueStart(ctx context.Context, jobID string, req conversation.StartRequest, opts ...conversation.PublishOption) error {
 	s.startJobID = jobID
 	s.lastStart = req
@@ -355,7 +362,7 @@ func (s *stubLeadsRepo) Create(context.Context, *leads.CreateLeadRequest) (*lead
 	return nil, errors.New("not implemented")
 }
 
-func (s *stubLeadsRepo) GetByID(context.Context, string, string) (*leads.Lead, error) {
+func (s *stubLeadsRepo) GetByID(context.Context, tenancy.OrgScope, string) (*leads.Lead, error) {
 	return nil, errors.New("not implemented")
 }
 
@@ -373,23 +380,27 @@ func (s *stubLeadsRepo) GetOrCreateByPhone(ctx context.Context, orgID string, ph
 	return &leads.Lead{ID: "lead-stub", OrgID: orgID, Phone: phone, Source: source}, nil
 }
 
-func (s *stubLeadsRepo) UpdateSchedulingPreferences(context.Context, string, leads.SchedulingPreferences) error {
+func (s *stubLeadsRepo) UpdateSchedulingPreferences(context.Context, tenancy.OrgScope, string, leads.SchedulingPreferences) error {
+	return nil
+}
+
+func (s *stubLeadsRepo) MergePreferences(context.Context, string, leads.SchedulingPreferences) error {
 	return nil
 }
 
-func (s *stubLeadsRepo) UpdateDepositStatus(context.Context, string, string, string) error {
+func (s *stubLeadsRepo) UpdateDepositStatus(context.Context, tenancy.OrgScope, string, string, string) error {
 	return nil
 }
 
-func (s *stubLeadsRepo) ListByOrg(context.Context, string, leads.ListLeadsFilter) ([]*leads.Lead, error) {
+func (s *stubLeadsRepo) ListByOrg(context.Context, tenancy.OrgScope, leads.ListLeadsFilter) ([]*leads.Lead, error) {
 	return nil, nil
 }
 
-func (s *stubLeadsRepo) UpdateSelectedAppointment(context.Context, string, leads.SelectedAppointment) error {
+func (s *stubLeadsRepo) UpdateSelectedAppointment(context.Context, tenancy.OrgScope, string, leads.SelectedAppointment) error {
 	return nil
 }
 
-func (s *stubLeadsRepo) UpdateBookingSession(context.Context, string, leads.BookingSessionUpdate) error {
+func (s *stubLeadsRepo) UpdateBookingSession(context.Context, tenancy.OrgScope, string, leads.BookingSessionUpdate) error {
 	return nil
 }
 
@@ -397,7 +408,7 @@ func (s *stubLeadsRepo) GetByBookingSessionID(context.Context, string) (*leads.L
 	return nil, nil
 }
 
-func (s *stubLeadsRepo) UpdateEmail(context.Context, string, string) error {
+func (s *stubLeadsRepo) UpdateEmail(context.Context, tenancy.OrgScope, string, string) error {
 	return nil
 }
 
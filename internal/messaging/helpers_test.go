@@ -152,6 +152,25 @@ func TestBuildReplyMessengerAutoFailover(t *testing.T) {
 	}
 }
 
+func TestBuildReplyMessengerPreferredProviderFailsOver(t *testing.T) {
+	messenger, provider, _ := BuildReplyMessenger(ProviderSelectionConfig{
+		Preference:       SMSProviderTwilio,
+		TelnyxAPIKey:     "telnyx-key",
+		TelnyxProfileID:  "telnyx-profile",
+		TwilioAccountSID: "twilio-sid",
+		TwilioAuthToken:  "twilio-token",
+		FailoverNumbers:  map[string]string{"org-1": "+15550002222"},
+	}, nil)
+
+	failover, ok := messenger.(*FailoverMessenger)
+	if !ok || provider != "twilio+telnyx" {
+		t.Fatalf("expected twilio-first failover, got %T %q", messenger, provider)
+	}
+	if failover.primaryName != SMSProviderTwilio || failover.failoverNumbers["org-1"] != "+15550002222" {
+		t.Fatalf("unexpected failover config: primary=%s numbers=%v", failover.primaryName, failover.failoverNumbers)
+	}
+}
+
 func TestIsMissedCallStatus(t *testing.T) {
 	if !isMissedCallStatus("no-answer") {
 		t.Fatalf("expected missed call status")
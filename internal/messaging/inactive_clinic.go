@@ -0,0 +1,56 @@
+package messaging
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/clinic"
+	"github.com/wolfman30/medspa-ai-platform/internal/messaging/templates"
+)
+
+// Reasons an inbound message is answered with the clinic farewell instead of
+// starting a conversation.
+const (
+	InactiveReasonDeactivated   = "inactive"
+	InactiveReasonNotConfigured = "not_configured"
+)
+
+// InactiveClinicFarewell reports whether inbound messages to orgID should get
+// the farewell text rather than a conversation: the clinic was deactivated,
+// or its config is gone. It returns the farewell and the reason, or an empty
+// farewell and reason when the clinic is served. A nil store or a failed lookup leaves the
+// clinic served so a Redis blip doesn't turn patients away; the error is
+// returned for the caller to log.
+func InactiveClinicFarewell(ctx context.Context, store *clinic.Store, orgID string) (farewell templates.Rendered, reason string, err error) {
+	orgID = strings.TrimSpace(orgID)
+	if store == nil || orgID == "" {
+		return templates.Rendered{}, "", nil
+	}
+	cfg, err := store.GetActive(ctx, orgID)
+	switch {
+	case errors.Is(err, clinic.ErrInactive):
+		return cfg.RenderFarewell(), InactiveReasonDeactivated, nil
+	case errors.Is(err, clinic.ErrNotConfigured):
+		return cfg.RenderFarewell(), InactiveReasonNotConfigured, nil
+	case err != nil:
+		return templates.Rendered{}, "", err
+	}
+	return templates.Rendered{}, "", nil
+}
+
+// replyIfInactive texts the farewell and reports true when orgID's clinic is
+// no longer served, in which case the caller must not start a conversation.
+func (h *Handler) replyIfInactive(ctx context.Context, orgID, conversationID, from, to, messageSid string) bool {
+	farewell, reason, err := InactiveClinicFarewell(ctx, h.clinicStore, orgID)
+	if err != nil {
+		h.logger.Warn("failed to check clinic status", "error", err, "org_id", orgID)
+	}
+	if reason == "" {
+		return false
+	}
+	h.logger.Info("inbound to inactive clinic: sending farewell", "org_id", orgID, "reason", reason)
+	h.metrics.ObserveInactiveOrg(reason)
+	h.sendAutoReply(orgID, conversationID, from, to, messageSid, farewell, "farewell")
+	return true
+}
@@ -0,0 +1,32 @@
+package messaging
+
+import (
+	"context"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/conversation"
+	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
+)
+
+// replyLogContext tags ctx with the reply's correlation fields so send-path
+// logs line up with the job that produced the reply.
+func replyLogContext(ctx context.Context, reply conversation.OutboundReply) context.Context {
+	return logging.WithFields(ctx, logging.Fields{
+		OrgID:          reply.OrgID,
+		ConversationID: reply.ConversationID,
+		LeadID:         reply.LeadID,
+	})
+}
+
+// WithRequestID stamps the inbound request's ID on job metadata so the
+// worker's logs for the job carry the same request_id.
+func WithRequestID(ctx context.Context, meta map[string]string) map[string]string {
+	id := logging.FieldsFromContext(ctx).RequestID
+	if id == "" {
+		return meta
+	}
+	if meta == nil {
+		meta = map[string]string{}
+	}
+	meta[conversation.MetadataRequestID] = id
+	return meta
+}
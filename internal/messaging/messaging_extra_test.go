@@ -94,10 +94,11 @@ func TestFailoverMessenger(t *testing.T) {
 	})
 
 	t.Run("primary fails secondary succeeds", func(t *testing.T) {
-		primary := &mockMessenger{err: errors.New("fail")}
+		primary := &mockMessenger{err: &ProviderError{Provider: SMSProviderTelnyx, StatusCode: 503}}
 		secondary := &mockMessenger{}
 		f := NewFailoverMessenger(primary, "primary", secondary, "secondary", nil)
-		err := f.SendReply(ctx, reply)
+		f.SetFailoverNumbers(map[string]string{"org-1": "+15550002222"})
+		err := f.SendReply(ctx, conversation.OutboundReply{OrgID: "org-1", To: "+11234567890", Body: "test"})
 		if err != nil {
 			t.Errorf("unexpected error: %v", err)
 		}
@@ -106,10 +107,24 @@ func TestFailoverMessenger(t *testing.T) {
 		}
 	})
 
+	t.Run("primary rejects recipient", func(t *testing.T) {
+		primary := &mockMessenger{err: errors.New("fail")}
+		secondary := &mockMessenger{}
+		f := NewFailoverMessenger(primary, "primary", secondary, "secondary", nil)
+		f.SetFailoverNumbers(map[string]string{"org-1": "+15550002222"})
+		if err := f.SendReply(ctx, conversation.OutboundReply{OrgID: "org-1", To: "+11234567890", Body: "test"}); err == nil {
+			t.Error("expected error")
+		}
+		if secondary.calls != 0 {
+			t.Error("secondary should not be called for errors it would repeat")
+		}
+	})
+
 	t.Run("both fail", func(t *testing.T) {
-		primary := &mockMessenger{err: errors.New("fail1")}
+		primary := &mockMessenger{err: &ProviderError{Provider: SMSProviderTelnyx, StatusCode: 500}}
 		secondary := &mockMessenger{err: errors.New("fail2")}
 		f := NewFailoverMessenger(primary, "primary", secondary, "secondary", nil)
+		f.SetFailoverNumbers(map[string]string{"": "+15550002222"})
 		err := f.SendReply(ctx, reply)
 		if err == nil {
 			t.Error("expected error")
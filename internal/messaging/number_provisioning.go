@@ -0,0 +1,267 @@
+package messaging
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/conversation"
+	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
+)
+
+var (
+	// ErrNumberNotProvisioned is returned for numbers the admin API didn't
+	// buy. Their sends aren't gated.
+	ErrNumberNotProvisioned = errors.New("messaging: number not provisioned")
+	// ErrCampaignNotApproved is returned when a send is blocked because the
+	// sending number's 10DLC campaign isn't approved yet.
+	ErrCampaignNotApproved = errors.New("messaging: 10DLC campaign not approved for sending number")
+	// ErrInvalidNumberProvisioning is returned when provisioning state is
+	// missing its number or org.
+	ErrInvalidNumberProvisioning = errors.New("messaging: number provisioning requires phone_number and org_id")
+)
+
+// approvedCampaignStatuses are the Telnyx 10DLC campaign statuses that allow
+// sending, lowercased.
+var approvedCampaignStatuses = map[string]bool{
+	"active":          true,
+	"approved":        true,
+	"mno_provisioned": true,
+}
+
+// NumberProvisioning is the provisioning state of a number bought through
+// the admin API: its Telnyx number order and 10DLC campaign.
+type NumberProvisioning struct {
+	PhoneNumber     string    `json:"phone_number"`
+	OrgID           string    `json:"org_id"`
+	ProviderOrderID string    `json:"provider_order_id,omitempty"`
+	NumberStatus    string    `json:"number_status,omitempty"`
+	CampaignID      string    `json:"campaign_id,omitempty"`
+	CampaignStatus  string    `json:"campaign_status,omitempty"`
+	CreatedAt       time.Time `json:"created_at,omitempty"`
+	UpdatedAt       time.Time `json:"updated_at,omitempty"`
+}
+
+// CampaignApproved reports whether the number's campaign allows sending. A
+// number with no campaign is not approved.
+func (p NumberProvisioning) CampaignApproved() bool {
+	return strings.TrimSpace(p.CampaignID) != "" && approvedCampaignStatuses[strings.ToLower(strings.TrimSpace(p.CampaignStatus))]
+}
+
+// SendBlockedReason explains why sends from the number are blocked, or
+// returns "" when they aren't.
+func (p NumberProvisioning) SendBlockedReason() string {
+	switch {
+	case p.CampaignApproved():
+		return ""
+	case strings.TrimSpace(p.CampaignID) == "":
+		return fmt.Sprintf("%s is not registered to a 10DLC campaign", p.PhoneNumber)
+	case strings.TrimSpace(p.CampaignStatus) == "":
+		return fmt.Sprintf("10DLC campaign %s for %s is awaiting approval", p.CampaignID, p.PhoneNumber)
+	default:
+		return fmt.Sprintf("10DLC campaign %s for %s is %s, not approved", p.CampaignID, p.PhoneNumber, p.CampaignStatus)
+	}
+}
+
+// NumberProvisioningStore persists number provisioning state in Postgres.
+type NumberProvisioningStore struct {
+	pool Querier
+}
+
+// NewNumberProvisioningStore constructs a provisioning store. Returns nil
+// without a pool.
+func NewNumberProvisioningStore(pool Querier) *NumberProvisioningStore {
+	if pool == nil {
+		return nil
+	}
+	return &NumberProvisioningStore{pool: pool}
+}
+
+// Provisioning returns a provisioning store sharing the message store's pool.
+func (s *Store) Provisioning() *NumberProvisioningStore {
+	if s == nil {
+		return nil
+	}
+	return NewNumberProvisioningStore(s.pool)
+}
+
+const numberProvisioningColumns = `phone_number, org_id, provider_order_id, number_status, campaign_id, campaign_status, created_at, updated_at`
+
+// Get returns the provisioning state of number. Returns
+// ErrNumberNotProvisioned when the number isn't tracked.
+func (s *NumberProvisioningStore) Get(ctx context.Context, number string) (NumberProvisioning, error) {
+	number = NormalizeE164(number)
+	if s == nil || number == "" {
+		return NumberProvisioning{}, ErrNumberNotProvisioned
+	}
+	row := s.pool.QueryRow(ctx, `SELECT `+numberProvisioningColumns+` FROM phone_number_provisioning WHERE phone_number = $1`, number)
+	p, err := scanNumberProvisioning(row)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return NumberProvisioning{}, ErrNumberNotProvisioned
+	}
+	if err != nil {
+		return NumberProvisioning{}, fmt.Errorf("messaging: get number provisioning: %w", err)
+	}
+	return p, nil
+}
+
+// ListByOrg returns the provisioning state of every number bought for orgID.
+func (s *NumberProvisioningStore) ListByOrg(ctx context.Context, orgID string) ([]NumberProvisioning, error) {
+	rows, err := s.pool.Query(ctx, `SELECT `+numberProvisioningColumns+` FROM phone_number_provisioning WHERE org_id = $1 ORDER BY phone_number`, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("messaging: list number provisioning: %w", err)
+	}
+	defer rows.Close()
+	list := []NumberProvisioning{}
+	for rows.Next() {
+		p, err := scanNumberProvisioning(rows)
+		if err != nil {
+			return nil, fmt.Errorf("messaging: scan number provisioning: %w", err)
+		}
+		list = append(list, p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("messaging: list number provisioning: %w", err)
+	}
+	return list, nil
+}
+
+// Upsert creates or replaces the provisioning state for p.PhoneNumber.
+func (s *NumberProvisioningStore) Upsert(ctx context.Context, p NumberProvisioning) (NumberProvisioning, error) {
+	p.PhoneNumber = NormalizeE164(p.PhoneNumber)
+	p.OrgID = strings.TrimSpace(p.OrgID)
+	if p.PhoneNumber == "" || p.OrgID == "" {
+		return NumberProvisioning{}, ErrInvalidNumberProvisioning
+	}
+	row := s.pool.QueryRow(ctx, `
+		INSERT INTO phone_number_provisioning (phone_number, org_id, provider_order_id, number_status, campaign_id, campaign_status)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (phone_number) DO UPDATE SET
+			org_id = EXCLUDED.org_id,
+			provider_order_id = EXCLUDED.provider_order_id,
+			number_status = EXCLUDED.number_status,
+			campaign_id = EXCLUDED.campaign_id,
+			campaign_status = EXCLUDED.campaign_status,
+			updated_at = now()
+		RETURNING `+numberProvisioningColumns,
+		p.PhoneNumber, p.OrgID, strings.TrimSpace(p.ProviderOrderID), strings.TrimSpace(p.NumberStatus),
+		strings.TrimSpace(p.CampaignID), strings.TrimSpace(p.CampaignStatus))
+	saved, err := scanNumberProvisioning(row)
+	if err != nil {
+		return NumberProvisioning{}, fmt.Errorf("messaging: upsert number provisioning: %w", err)
+	}
+	return saved, nil
+}
+
+// UpdateNumberStatus records the order status Telnyx reported for a number
+// and returns how many numbers changed.
+func (s *NumberProvisioningStore) UpdateNumberStatus(ctx context.Context, orderID, number, status string) (int64, error) {
+	tag, err := s.pool.Exec(ctx, `
+		UPDATE phone_number_provisioning SET number_status = $3, updated_at = now()
+		WHERE provider_order_id = $1 AND phone_number = $2`,
+		strings.TrimSpace(orderID), NormalizeE164(number), strings.TrimSpace(status))
+	if err != nil {
+		return 0, fmt.Errorf("messaging: update number status: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
+
+// UpdateCampaignStatus records a campaign's status on every number
+// registered to it and returns how many numbers changed.
+func (s *NumberProvisioningStore) UpdateCampaignStatus(ctx context.Context, campaignID, status string) (int64, error) {
+	tag, err := s.pool.Exec(ctx, `
+		UPDATE phone_number_provisioning SET campaign_status = $2, updated_at = now()
+		WHERE campaign_id = $1`,
+		strings.TrimSpace(campaignID), strings.TrimSpace(status))
+	if err != nil {
+		return 0, fmt.Errorf("messaging: update campaign status: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
+
+// CampaignStatus returns the last status recorded for campaignID on any
+// number, or "" when none is known. A number added to a campaign that was
+// approved earlier won't see another campaign webhook, so it inherits this.
+func (s *NumberProvisioningStore) CampaignStatus(ctx context.Context, campaignID string) (string, error) {
+	var status string
+	err := s.pool.QueryRow(ctx, `
+		SELECT campaign_status FROM phone_number_provisioning
+		WHERE campaign_id = $1 AND campaign_status <> ''
+		ORDER BY updated_at DESC LIMIT 1`, strings.TrimSpace(campaignID)).Scan(&status)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("messaging: get campaign status: %w", err)
+	}
+	return status, nil
+}
+
+func scanNumberProvisioning(row pgx.Row) (NumberProvisioning, error) {
+	var p NumberProvisioning
+	err := row.Scan(&p.PhoneNumber, &p.OrgID, &p.ProviderOrderID, &p.NumberStatus, &p.CampaignID, &p.CampaignStatus, &p.CreatedAt, &p.UpdatedAt)
+	return p, err
+}
+
+// numberProvisioningLookup is the read side of NumberProvisioningStore.
+type numberProvisioningLookup interface {
+	Get(ctx context.Context, number string) (NumberProvisioning, error)
+}
+
+// CampaignGateMessenger refuses to send from provisioned numbers whose 10DLC
+// campaign isn't approved; carriers filter unregistered A2P traffic.
+// Numbers the admin API didn't buy pass through.
+type CampaignGateMessenger struct {
+	inner   conversation.ReplyMessenger
+	numbers numberProvisioningLookup
+	logger  *logging.Logger
+}
+
+// WrapWithCampaignGate gates messenger on the sending number's campaign
+// status. Returns messenger unchanged without a store.
+func WrapWithCampaignGate(messenger conversation.ReplyMessenger, numbers *NumberProvisioningStore, logger *logging.Logger) conversation.ReplyMessenger {
+	if messenger == nil || numbers == nil {
+		return messenger
+	}
+	if logger == nil {
+		logger = logging.Default()
+	}
+	return &CampaignGateMessenger{inner: messenger, numbers: numbers, logger: logger}
+}
+
+// SendReply sends reply unless its from number's campaign isn't approved.
+func (g *CampaignGateMessenger) SendReply(ctx context.Context, reply conversation.OutboundReply) error {
+	if err := CheckSendingCampaign(ctx, g.numbers, reply.From, g.logger); err != nil {
+		g.logger.Error("sms blocked: sending number's 10DLC campaign is not approved", "error", err,
+			"org_id", reply.OrgID, "conversation_id", reply.ConversationID)
+		return err
+	}
+	return g.inner.SendReply(ctx, reply)
+}
+
+// CheckSendingCampaign returns ErrCampaignNotApproved, with the reason, when
+// from is a provisioned number whose 10DLC campaign isn't approved. Numbers
+// the admin API didn't buy pass, and so does a lookup error, so a database
+// blip doesn't silence every clinic. Sends that skip the outbound messenger
+// must check this themselves.
+func CheckSendingCampaign(ctx context.Context, numbers numberProvisioningLookup, from string, logger *logging.Logger) error {
+	from = NormalizeE164(from)
+	if numbers == nil || from == "" {
+		return nil
+	}
+	p, err := numbers.Get(ctx, from)
+	switch {
+	case errors.Is(err, ErrNumberNotProvisioned):
+	case err != nil:
+		if logger != nil {
+			logger.Warn("campaign gate lookup failed; sending anyway", "error", err, "from", from)
+		}
+	case !p.CampaignApproved():
+		return fmt.Errorf("%w: %s", ErrCampaignNotApproved, p.SendBlockedReason())
+	}
+	return nil
+}
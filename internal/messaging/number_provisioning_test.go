@@ -0,0 +1,126 @@
+package messaging
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	pgxmock "github.com/pashagolub/pgxmock/v4"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/conversation"
+	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
+)
+
+type stubProvisioningLookup struct {
+	numbers map[string]NumberProvisioning
+	err     error
+}
+
+func (s *stubProvisioningLookup) Get(ctx context.Context, number string) (NumberProvisioning, error) {
+	if s.err != nil {
+		return NumberProvisioning{}, s.err
+	}
+	p, ok := s.numbers[NormalizeE164(number)]
+	if !ok {
+		return NumberProvisioning{}, ErrNumberNotProvisioned
+	}
+	return p, nil
+}
+
+func TestNumberProvisioningCampaignApproved(t *testing.T) {
+	cases := []struct {
+		p       NumberProvisioning
+		blocked string
+	}{
+		{NumberProvisioning{PhoneNumber: "+15125550100", CampaignID: "C1", CampaignStatus: "MNO_PROVISIONED"}, ""},
+		{NumberProvisioning{PhoneNumber: "+15125550100", CampaignID: "C1", CampaignStatus: "active"}, ""},
+		{NumberProvisioning{PhoneNumber: "+15125550100", CampaignID: "C1"}, "awaiting approval"},
+		{NumberProvisioning{PhoneNumber: "+15125550100", CampaignID: "C1", CampaignStatus: "REJECTED"}, "REJECTED"},
+		{NumberProvisioning{PhoneNumber: "+15125550100", CampaignStatus: "ACTIVE"}, "not registered"},
+	}
+	for _, tc := range cases {
+		reason := tc.p.SendBlockedReason()
+		if tc.p.CampaignApproved() != (tc.blocked == "") || (reason == "") != (tc.blocked == "") || !strings.Contains(reason, tc.blocked) {
+			t.Errorf("%+v: approved=%v reason=%q, want reason containing %q", tc.p, tc.p.CampaignApproved(), reason, tc.blocked)
+		}
+	}
+}
+
+func TestCampaignGateMessenger(t *testing.T) {
+	inner := &recordingMessenger{}
+	gate := &CampaignGateMessenger{
+		inner: inner,
+		numbers: &stubProvisioningLookup{numbers: map[string]NumberProvisioning{
+			"+15125550100": {PhoneNumber: "+15125550100", CampaignID: "C1", CampaignStatus: "TCR_PENDING"},
+			"+15125550101": {PhoneNumber: "+15125550101", CampaignID: "C1", CampaignStatus: "ACTIVE"},
+		}},
+		logger: logging.Default(),
+	}
+	ctx := context.Background()
+
+	err := gate.SendReply(ctx, conversation.OutboundReply{OrgID: "org-1", From: "+15125550100", To: "+15555550100", Body: "hi"})
+	if !errors.Is(err, ErrCampaignNotApproved) || !strings.Contains(err.Error(), "TCR_PENDING") {
+		t.Fatalf("expected campaign block, got %v", err)
+	}
+	if len(inner.replies) != 0 {
+		t.Fatalf("blocked reply reached the provider")
+	}
+	for _, from := range []string{"+15125550101", "+15559990000", ""} {
+		if err := gate.SendReply(ctx, conversation.OutboundReply{OrgID: "org-1", From: from, To: "+15555550100", Body: "hi"}); err != nil {
+			t.Fatalf("send from %q: %v", from, err)
+		}
+	}
+	// A lookup failure doesn't block sends.
+	gate.numbers = &stubProvisioningLookup{err: errors.New("db down")}
+	if err := gate.SendReply(ctx, conversation.OutboundReply{OrgID: "org-1", From: "+15125550100", To: "+15555550100", Body: "hi"}); err != nil {
+		t.Fatalf("send on lookup error: %v", err)
+	}
+	if len(inner.replies) != 4 {
+		t.Fatalf("expected 4 sends, got %d", len(inner.replies))
+	}
+
+	if WrapWithCampaignGate(inner, nil, nil) != inner {
+		t.Fatalf("expected messenger unchanged without a store")
+	}
+}
+
+func TestNumberProvisioningStore(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("pgxmock: %v", err)
+	}
+	defer mock.Close()
+	store := NewNumberProvisioningStore(mock)
+	ctx := context.Background()
+	now := time.Now()
+	columns := []string{"phone_number", "org_id", "provider_order_id", "number_status", "campaign_id", "campaign_status", "created_at", "updated_at"}
+
+	mock.ExpectQuery("INSERT INTO phone_number_provisioning").
+		WithArgs("+15125550100", "org-1", "ord_1", "pending", "C1", "").
+		WillReturnRows(pgxmock.NewRows(columns).AddRow("+15125550100", "org-1", "ord_1", "pending", "C1", "", now, now))
+	mock.ExpectExec("UPDATE phone_number_provisioning SET campaign_status").
+		WithArgs("C1", "ACTIVE").
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	mock.ExpectQuery("FROM phone_number_provisioning WHERE phone_number").
+		WithArgs("+15559990000").
+		WillReturnRows(pgxmock.NewRows(columns))
+
+	saved, err := store.Upsert(ctx, NumberProvisioning{PhoneNumber: "15125550100", OrgID: "org-1", ProviderOrderID: "ord_1", NumberStatus: "pending", CampaignID: "C1"})
+	if err != nil || saved.ProviderOrderID != "ord_1" {
+		t.Fatalf("upsert = %+v, %v", saved, err)
+	}
+	if n, err := store.UpdateCampaignStatus(ctx, "C1", "ACTIVE"); err != nil || n != 1 {
+		t.Fatalf("update campaign status = %d, %v", n, err)
+	}
+	if _, err := store.Get(ctx, "+15559990000"); !errors.Is(err, ErrNumberNotProvisioned) {
+		t.Fatalf("expected ErrNumberNotProvisioned, got %v", err)
+	}
+	if _, err := store.Upsert(ctx, NumberProvisioning{PhoneNumber: "+15125550100"}); !errors.Is(err, ErrInvalidNumberProvisioning) {
+		t.Fatalf("expected ErrInvalidNumberProvisioning, got %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
@@ -0,0 +1,212 @@
+package messaging
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/conversation"
+)
+
+// Conversation metadata keys set from a number's route.
+const (
+	MetadataDefaultService = conversation.MetadataDefaultService
+	MetadataCampaignTag    = conversation.MetadataCampaignTag
+)
+
+// ErrInvalidNumberRoute is returned when a route is missing its number or org.
+var ErrInvalidNumberRoute = errors.New("messaging: number route requires phone_number and org_id")
+
+// NumberRoute describes where an inbound number routes and what it implies
+// about the patient: the service line it was advertised for and the campaign
+// that published it.
+type NumberRoute struct {
+	PhoneNumber    string    `json:"phone_number"`
+	OrgID          string    `json:"org_id"`
+	DefaultService string    `json:"default_service,omitempty"`
+	CampaignTag    string    `json:"campaign_tag,omitempty"`
+	CreatedAt      time.Time `json:"created_at,omitempty"`
+	UpdatedAt      time.Time `json:"updated_at,omitempty"`
+}
+
+// Metadata returns the conversation metadata implied by the route.
+func (r NumberRoute) Metadata() map[string]string {
+	meta := map[string]string{}
+	if v := strings.TrimSpace(r.DefaultService); v != "" {
+		meta[MetadataDefaultService] = v
+	}
+	if v := strings.TrimSpace(r.CampaignTag); v != "" {
+		meta[MetadataCampaignTag] = v
+	}
+	return meta
+}
+
+// withRouteMetadata adds the route's metadata to meta and returns it.
+func withRouteMetadata(meta map[string]string, route NumberRoute) map[string]string {
+	for k, v := range route.Metadata() {
+		if meta == nil {
+			meta = map[string]string{}
+		}
+		meta[k] = v
+	}
+	return meta
+}
+
+// NumberRouteStore persists number routes in Postgres.
+type NumberRouteStore struct {
+	pool Querier
+}
+
+// NewNumberRouteStore constructs a route store. Returns nil without a pool.
+func NewNumberRouteStore(pool Querier) *NumberRouteStore {
+	if pool == nil {
+		return nil
+	}
+	return &NumberRouteStore{pool: pool}
+}
+
+const numberRouteColumns = `phone_number, org_id, default_service, campaign_tag, created_at, updated_at`
+
+// Get returns the route for number. Returns ErrOrgNotFound when unmapped.
+func (s *NumberRouteStore) Get(ctx context.Context, number string) (NumberRoute, error) {
+	number = NormalizeE164(number)
+	if number == "" {
+		return NumberRoute{}, ErrOrgNotFound
+	}
+	row := s.pool.QueryRow(ctx, `SELECT `+numberRouteColumns+` FROM phone_number_routes WHERE phone_number = $1`, number)
+	route, err := scanNumberRoute(row)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return NumberRoute{}, ErrOrgNotFound
+	}
+	if err != nil {
+		return NumberRoute{}, fmt.Errorf("messaging: get number route: %w", err)
+	}
+	return route, nil
+}
+
+// ListByOrg returns every number routed to orgID.
+func (s *NumberRouteStore) ListByOrg(ctx context.Context, orgID string) ([]NumberRoute, error) {
+	rows, err := s.pool.Query(ctx, `SELECT `+numberRouteColumns+` FROM phone_number_routes WHERE org_id = $1 ORDER BY phone_number`, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("messaging: list number routes: %w", err)
+	}
+	defer rows.Close()
+	routes := []NumberRoute{}
+	for rows.Next() {
+		route, err := scanNumberRoute(rows)
+		if err != nil {
+			return nil, fmt.Errorf("messaging: scan number route: %w", err)
+		}
+		routes = append(routes, route)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("messaging: list number routes: %w", err)
+	}
+	return routes, nil
+}
+
+// Upsert creates or replaces the route for route.PhoneNumber.
+func (s *NumberRouteStore) Upsert(ctx context.Context, route NumberRoute) (NumberRoute, error) {
+	route.PhoneNumber = NormalizeE164(route.PhoneNumber)
+	route.OrgID = strings.TrimSpace(route.OrgID)
+	if route.PhoneNumber == "" || route.OrgID == "" {
+		return NumberRoute{}, ErrInvalidNumberRoute
+	}
+	row := s.pool.QueryRow(ctx, `
+		INSERT INTO phone_number_routes (phone_number, org_id, default_service, campaign_tag)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (phone_number) DO UPDATE SET
+			org_id = EXCLUDED.org_id,
+			default_service = EXCLUDED.default_service,
+			campaign_tag = EXCLUDED.campaign_tag,
+			updated_at = now()
+		RETURNING `+numberRouteColumns,
+		route.PhoneNumber, route.OrgID, strings.TrimSpace(route.DefaultService), strings.TrimSpace(route.CampaignTag))
+	saved, err := scanNumberRoute(row)
+	if err != nil {
+		return NumberRoute{}, fmt.Errorf("messaging: upsert number route: %w", err)
+	}
+	return saved, nil
+}
+
+// Delete removes the route for number within orgID. Returns ErrOrgNotFound
+// when the org has no such number.
+func (s *NumberRouteStore) Delete(ctx context.Context, orgID, number string) error {
+	tag, err := s.pool.Exec(ctx, `DELETE FROM phone_number_routes WHERE org_id = $1 AND phone_number = $2`, orgID, NormalizeE164(number))
+	if err != nil {
+		return fmt.Errorf("messaging: delete number route: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrOrgNotFound
+	}
+	return nil
+}
+
+func scanNumberRoute(row pgx.Row) (NumberRoute, error) {
+	var route NumberRoute
+	err := row.Scan(&route.PhoneNumber, &route.OrgID, &route.DefaultService, &route.CampaignTag, &route.CreatedAt, &route.UpdatedAt)
+	return route, err
+}
+
+// numberRouteLookup is the read side of NumberRouteStore.
+type numberRouteLookup interface {
+	Get(ctx context.Context, number string) (NumberRoute, error)
+}
+
+// RoutingOrgResolver resolves numbers from the phone_number_routes table and
+// falls back to the TWILIO_ORG_MAP_JSON mapping for numbers not in the table.
+type RoutingOrgResolver struct {
+	routes   numberRouteLookup
+	fallback *StaticOrgResolver
+}
+
+// NewRoutingOrgResolver layers DB routes over the static env mapping. Either
+// may be nil.
+func NewRoutingOrgResolver(routes *NumberRouteStore, fallback *StaticOrgResolver) *RoutingOrgResolver {
+	r := &RoutingOrgResolver{fallback: fallback}
+	if routes != nil {
+		r.routes = routes
+	}
+	return r
+}
+
+// ResolveRoute implements OrgResolver. DB routes win over the env mapping;
+// a DB error falls through to the env mapping so a database blip doesn't
+// reject inbound texts for numbers configured there.
+func (r *RoutingOrgResolver) ResolveRoute(ctx context.Context, toNumber string) (NumberRoute, error) {
+	if r == nil {
+		return NumberRoute{}, ErrOrgNotFound
+	}
+	if r.routes != nil {
+		route, err := r.routes.Get(ctx, toNumber)
+		if err == nil {
+			return route, nil
+		}
+		if !errors.Is(err, ErrOrgNotFound) && r.fallback == nil {
+			return NumberRoute{}, err
+		}
+	}
+	return r.fallback.ResolveRoute(ctx, toNumber)
+}
+
+// ResolveOrgID implements OrgResolver.
+func (r *RoutingOrgResolver) ResolveOrgID(ctx context.Context, toNumber string) (string, error) {
+	route, err := r.ResolveRoute(ctx, toNumber)
+	if err != nil {
+		return "", err
+	}
+	return route.OrgID, nil
+}
+
+// DefaultFromNumber returns the preferred sending number for the org from
+// the env mapping.
+func (r *RoutingOrgResolver) DefaultFromNumber(orgID string) string {
+	if r == nil {
+		return ""
+	}
+	return r.fallback.DefaultFromNumber(orgID)
+}
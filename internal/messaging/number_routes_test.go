@@ -0,0 +1,151 @@
+package messaging
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	pgxmock "github.com/pashagolub/pgxmock/v4"
+)
+
+type stubRouteLookup struct {
+	routes map[string]NumberRoute
+	err    error
+}
+
+func (s *stubRouteLookup) Get(ctx context.Context, number string) (NumberRoute, error) {
+	if s.err != nil {
+		return NumberRoute{}, s.err
+	}
+	route, ok := s.routes[NormalizeE164(number)]
+	if !ok {
+		return NumberRoute{}, ErrOrgNotFound
+	}
+	return route, nil
+}
+
+func TestRoutingOrgResolverPrecedence(t *testing.T) {
+	env := NewStaticOrgResolver(map[string]string{
+		"+15551230001": "org-env",
+		"+15551230002": "org-env",
+	})
+	resolver := &RoutingOrgResolver{
+		routes: &stubRouteLookup{routes: map[string]NumberRoute{
+			"+15551230001": {PhoneNumber: "+15551230001", OrgID: "org-db", DefaultService: "weight loss"},
+		}},
+		fallback: env,
+	}
+	ctx := context.Background()
+
+	route, err := resolver.ResolveRoute(ctx, "15551230001")
+	if err != nil || route.OrgID != "org-db" || route.DefaultService != "weight loss" {
+		t.Fatalf("expected DB route to win, got %+v err=%v", route, err)
+	}
+	route, err = resolver.ResolveRoute(ctx, "+15551230002")
+	if err != nil || route.OrgID != "org-env" || route.DefaultService != "" {
+		t.Fatalf("expected env fallback, got %+v err=%v", route, err)
+	}
+	if _, err := resolver.ResolveOrgID(ctx, "+15559999999"); !errors.Is(err, ErrOrgNotFound) {
+		t.Fatalf("expected ErrOrgNotFound for unknown number, got %v", err)
+	}
+
+	// A database error falls back to the env mapping.
+	resolver.routes = &stubRouteLookup{err: errors.New("db down")}
+	if org, err := resolver.ResolveOrgID(ctx, "+15551230001"); err != nil || org != "org-env" {
+		t.Fatalf("expected env fallback on db error, got %q err=%v", org, err)
+	}
+}
+
+func TestNumberRouteStoreGet(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("pgxmock: %v", err)
+	}
+	defer mock.Close()
+
+	store := NewNumberRouteStore(mock)
+	now := time.Now()
+	mock.ExpectQuery("FROM phone_number_routes WHERE phone_number").
+		WithArgs("+15551230001").
+		WillReturnRows(pgxmock.NewRows([]string{"phone_number", "org_id", "default_service", "campaign_tag", "created_at", "updated_at"}).
+			AddRow("+15551230001", "org-db", "injectables", "spring-promo", now, now))
+	mock.ExpectQuery("FROM phone_number_routes WHERE phone_number").
+		WithArgs("+15559999999").
+		WillReturnRows(pgxmock.NewRows([]string{"phone_number", "org_id", "default_service", "campaign_tag", "created_at", "updated_at"}))
+
+	route, err := store.Get(context.Background(), "+1 (555) 123-0001")
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if route.OrgID != "org-db" || route.CampaignTag != "spring-promo" {
+		t.Fatalf("unexpected route: %+v", route)
+	}
+	if _, err := store.Get(context.Background(), "+15559999999"); !errors.Is(err, ErrOrgNotFound) {
+		t.Fatalf("expected ErrOrgNotFound, got %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestTwilioWebhookAttachesRouteMetadata(t *testing.T) {
+	resolver := &RoutingOrgResolver{routes: &stubRouteLookup{routes: map[string]NumberRoute{
+		"+15551234567": {PhoneNumber: "+15551234567", OrgID: "org-test", DefaultService: "weight loss", CampaignTag: "glp1-launch"},
+	}}}
+	handler, pub := newTestHandler(t, "", nil, resolver)
+
+	form := url.Values{}
+	form.Set("MessageSid", "SM-route")
+	form.Set("From", "+1234567890")
+	form.Set("To", "+15551234567")
+	form.Set("Body", "Hi, how much is it?")
+	req := httptest.NewRequest(http.MethodPost, "/messaging/twilio/webhook", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+
+	handler.TwilioWebhook(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+	if pub.lastReq.OrgID != "org-test" {
+		t.Fatalf("expected org-test, got %q", pub.lastReq.OrgID)
+	}
+	if got := pub.lastReq.Metadata[MetadataDefaultService]; got != "weight loss" {
+		t.Fatalf("expected default_service metadata, got %q", got)
+	}
+	if got := pub.lastReq.Metadata[MetadataCampaignTag]; got != "glp1-launch" {
+		t.Fatalf("expected campaign_tag metadata, got %q", got)
+	}
+	if pub.lastReq.Metadata["twilio_message_sid"] != "SM-route" {
+		t.Fatalf("expected provider metadata kept, got %+v", pub.lastReq.Metadata)
+	}
+}
+
+func TestTwilioWebhookRejectsUnroutedNumber(t *testing.T) {
+	resolver := &RoutingOrgResolver{routes: &stubRouteLookup{routes: map[string]NumberRoute{}}}
+	handler, pub := newTestHandler(t, "", nil, resolver)
+
+	form := url.Values{}
+	form.Set("MessageSid", "SM-unknown")
+	form.Set("From", "+1234567890")
+	form.Set("To", "+15550000000")
+	form.Set("Body", "Hello")
+	req := httptest.NewRequest(http.MethodPost, "/messaging/twilio/webhook", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+
+	handler.TwilioWebhook(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for unknown number, got %d", w.Code)
+	}
+	if pub.called {
+		t.Fatalf("expected no conversation job for unknown number")
+	}
+}
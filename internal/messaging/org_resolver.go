@@ -16,6 +16,9 @@ var (
 // OrgResolver resolves the destination org for a Twilio number.
 type OrgResolver interface {
 	ResolveOrgID(ctx context.Context, toNumber string) (string, error)
+	// ResolveRoute returns the org plus any routing metadata (default service,
+	// campaign tag) configured for the number.
+	ResolveRoute(ctx context.Context, toNumber string) (NumberRoute, error)
 }
 
 // StaticOrgResolver maps sanitized phone numbers to org IDs.
@@ -57,6 +60,16 @@ func (r *StaticOrgResolver) ResolveOrgID(ctx context.Context, toNumber string) (
 	return org, nil
 }
 
+// ResolveRoute implements OrgResolver. The env mapping carries no routing
+// metadata, so only OrgID and PhoneNumber are set.
+func (r *StaticOrgResolver) ResolveRoute(ctx context.Context, toNumber string) (NumberRoute, error) {
+	org, err := r.ResolveOrgID(ctx, toNumber)
+	if err != nil {
+		return NumberRoute{}, err
+	}
+	return NumberRoute{PhoneNumber: NormalizeE164(toNumber), OrgID: org}, nil
+}
+
 func sanitizePhone(value string) string {
 	if value == "" {
 		return ""
@@ -2,12 +2,15 @@ package messaging
 
 import (
 	"context"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/google/uuid"
 
 	"github.com/wolfman30/medspa-ai-platform/internal/conversation"
+	"github.com/wolfman30/medspa-ai-platform/internal/conversation/msgschema"
+	"github.com/wolfman30/medspa-ai-platform/internal/messaging/templates"
 	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
 )
 
@@ -53,15 +56,18 @@ func (p *PersistingMessenger) SendReply(ctx context.Context, reply conversation.
 	// Persist the outbound message before sending
 	now := time.Now()
 	rec := MessageRecord{
-		ClinicID:       clinicID,
-		From:           reply.From,
-		To:             reply.To,
-		Direction:      "outbound",
-		Body:           reply.Body,
-		Media:          []string{},
-		ProviderStatus: "pending",
-		SendAttempts:   1,
-		LastAttemptAt:  &now,
+		ClinicID:        clinicID,
+		From:            reply.From,
+		To:              reply.To,
+		Direction:       "outbound",
+		AuthorType:      string(msgschema.AI),
+		Body:            reply.Body,
+		Media:           []string{},
+		TemplateID:      reply.Metadata[templates.MetadataID],
+		TemplateVersion: reply.Metadata[templates.MetadataVersion],
+		ProviderStatus:  "pending",
+		SendAttempts:    1,
+		LastAttemptAt:   &now,
 	}
 
 	msgID, err := p.store.InsertMessage(ctx, nil, rec)
@@ -100,8 +106,33 @@ func (p *PersistingMessenger) SendReply(ctx context.Context, reply conversation.
 					p.logger.Warn("failed to update provider message id", "error", updateErr, "msg_id", msgID)
 				}
 			}
+			if updateErr := p.store.UpdateMessageSender(ctx, msgID, reply.Metadata[metadataProvider], reply.Metadata[metadataProviderFrom]); updateErr != nil {
+				p.logger.Warn("failed to update message sender", "error", updateErr, "msg_id", msgID)
+			}
+			segments, cost := replyCost(reply)
+			if updateErr := p.store.UpdateMessageCost(ctx, msgID, segments, cost); updateErr != nil {
+				p.logger.Warn("failed to update message cost", "error", updateErr, "msg_id", msgID)
+			}
 		}
 	}
 
 	return sendErr
 }
+
+// replyCost returns the segments and cost the sender reported for a reply.
+// Senders that report nothing are counted locally and left unpriced.
+func replyCost(reply conversation.OutboundReply) (int, *float64) {
+	segments, err := strconv.Atoi(reply.Metadata[metadataSegments])
+	if err != nil || segments <= 0 {
+		segments = SegmentCount(reply.Body)
+	}
+	raw := strings.TrimSpace(reply.Metadata[metadataCostUSD])
+	if raw == "" {
+		return segments, nil
+	}
+	cost, err := strconv.ParseFloat(raw, 64)
+	if err != nil || cost < 0 {
+		return segments, nil
+	}
+	return segments, &cost
+}
@@ -1,16 +1,9 @@
 package messaging
 
-import "strings"
+import "github.com/wolfman30/medspa-ai-platform/pkg/phone"
 
-// NormalizeE164 ensures the value begins with + and only contains digits afterward.
+// NormalizeE164 returns value in E.164 form, reading bare ten-digit numbers
+// as US numbers. See phone.E164.
 func NormalizeE164(value string) string {
-	value = strings.TrimSpace(value)
-	if value == "" {
-		return ""
-	}
-	digits := sanitizePhone(value)
-	if digits == "" {
-		return ""
-	}
-	return "+" + digits
+	return phone.E164(value)
 }
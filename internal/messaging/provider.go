@@ -25,9 +25,17 @@ type ProviderSelectionConfig struct {
 	TwilioAccountSID string
 	TwilioAuthToken  string
 	TwilioFromNumber string
+	// TelnyxSegmentRateUSD prices Telnyx sends the API didn't report a cost
+	// for; 0 keeps the default rate.
+	TelnyxSegmentRateUSD float64
+	// FailoverNumbers maps org ID to the number the org owns on the
+	// secondary provider. Only orgs listed here fail over.
+	FailoverNumbers map[string]string
 }
 
 // BuildReplyMessenger instantiates a ReplyMessenger based on the preferred provider.
+// When both providers are configured it sends through the preferred one and
+// fails over to the other.
 // It returns the messenger, the provider that was selected, and a reason when no provider could be initialized.
 func BuildReplyMessenger(cfg ProviderSelectionConfig, logger *logging.Logger) (conversation.ReplyMessenger, string, string) {
 	if logger == nil {
@@ -43,7 +51,9 @@ func BuildReplyMessenger(cfg ProviderSelectionConfig, logger *logging.Logger) (c
 	var twilioMessenger conversation.ReplyMessenger
 
 	if cfg.TelnyxAPIKey != "" && cfg.TelnyxProfileID != "" {
-		telnyxMessenger = NewTelnyxSender(cfg.TelnyxAPIKey, cfg.TelnyxProfileID, logger)
+		telnyx := NewTelnyxSender(cfg.TelnyxAPIKey, cfg.TelnyxProfileID, logger)
+		telnyx.SetSegmentRate(cfg.TelnyxSegmentRateUSD)
+		telnyxMessenger = telnyx
 	} else {
 		var reasons []string
 		if cfg.TelnyxAPIKey == "" {
@@ -68,6 +78,18 @@ func BuildReplyMessenger(cfg ProviderSelectionConfig, logger *logging.Logger) (c
 		missing[SMSProviderTwilio] = strings.Join(reasons, ", ")
 	}
 
+	knownPreference := preference == SMSProviderAuto || preference == SMSProviderTelnyx || preference == SMSProviderTwilio
+	if telnyxMessenger != nil && twilioMessenger != nil && knownPreference {
+		primary, primaryName := telnyxMessenger, SMSProviderTelnyx
+		secondary, secondaryName := twilioMessenger, SMSProviderTwilio
+		if preference == SMSProviderTwilio {
+			primary, primaryName, secondary, secondaryName = secondary, secondaryName, primary, primaryName
+		}
+		failover := NewFailoverMessenger(primary, primaryName, secondary, secondaryName, logger)
+		failover.SetFailoverNumbers(cfg.FailoverNumbers)
+		return failover, primaryName + "+" + secondaryName, ""
+	}
+
 	if preference != SMSProviderAuto {
 		if preference == SMSProviderTelnyx && telnyxMessenger != nil {
 			return telnyxMessenger, SMSProviderTelnyx, ""
@@ -82,9 +104,6 @@ func BuildReplyMessenger(cfg ProviderSelectionConfig, logger *logging.Logger) (c
 		return nil, "", reason
 	}
 
-	if telnyxMessenger != nil && twilioMessenger != nil {
-		return NewFailoverMessenger(telnyxMessenger, SMSProviderTelnyx, twilioMessenger, SMSProviderTwilio, logger), SMSProviderTelnyx + "+" + SMSProviderTwilio, ""
-	}
 	if telnyxMessenger != nil {
 		return telnyxMessenger, SMSProviderTelnyx, ""
 	}
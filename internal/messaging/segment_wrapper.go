@@ -0,0 +1,117 @@
+package messaging
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/conversation"
+	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
+)
+
+var smsMessagesPerReply = prometheus.NewHistogram(
+	prometheus.HistogramOpts{
+		Namespace: "medspa",
+		Subsystem: "messaging",
+		Name:      "sms_messages_per_reply",
+		Help:      "Outbound SMS messages sent for each reply after segment splitting",
+		Buckets:   []float64{1, 2, 3, 4, 5, 6},
+	},
+)
+
+func init() {
+	prometheus.MustRegister(smsMessagesPerReply)
+}
+
+// SegmentSplittingMessenger sends replies longer than a segment budget as a
+// short sequence of messages instead of one long concatenated SMS, which some
+// carriers deliver out of order or drop.
+type SegmentSplittingMessenger struct {
+	inner       conversation.ReplyMessenger
+	maxSegments int
+	maxMessages int
+	spacing     time.Duration
+	logger      *logging.Logger
+}
+
+// SegmentSplitConfig configures the segment splitting wrapper.
+type SegmentSplitConfig struct {
+	// MaxSegments is the most segments a single message may use; 0 disables splitting.
+	MaxSegments int
+	// MaxMessages caps how many messages one reply may become; the rest is
+	// truncated with a "(cont'd)" indicator. 0 means no cap.
+	MaxMessages int
+	// Spacing is the pause between messages so they arrive in order.
+	Spacing time.Duration
+	Logger  *logging.Logger
+}
+
+// WrapWithSegmentSplitting optionally wraps a messenger so long replies are
+// split on segment boundaries. If MaxSegments is not set, returns the
+// original messenger unchanged.
+func WrapWithSegmentSplitting(messenger conversation.ReplyMessenger, cfg SegmentSplitConfig) conversation.ReplyMessenger {
+	if cfg.MaxSegments <= 0 || messenger == nil {
+		return messenger
+	}
+	if cfg.Logger == nil {
+		cfg.Logger = logging.Default()
+	}
+	return &SegmentSplittingMessenger{
+		inner:       messenger,
+		maxSegments: cfg.MaxSegments,
+		maxMessages: cfg.MaxMessages,
+		spacing:     cfg.Spacing,
+		logger:      cfg.Logger,
+	}
+}
+
+// SendReply splits the body if needed and sends the parts in order, stopping
+// at the first failure. The first part carries the caller's metadata map so
+// provider IDs written by the inner messenger reach the caller.
+func (s *SegmentSplittingMessenger) SendReply(ctx context.Context, reply conversation.OutboundReply) error {
+	parts := SplitMessage(reply.Body, s.maxSegments)
+	if len(parts) <= 1 {
+		smsMessagesPerReply.Observe(1)
+		return s.inner.SendReply(ctx, reply)
+	}
+
+	parts, truncated := CapMessages(parts, s.maxSegments, s.maxMessages)
+	if truncated {
+		s.logger.Warn("outbound sms truncated after splitting",
+			"conversation_id", reply.ConversationID,
+			"segments", SegmentCount(reply.Body),
+			"max_messages", s.maxMessages,
+		)
+	}
+	smsMessagesPerReply.Observe(float64(len(parts)))
+
+	// Later parts start from the metadata as the caller passed it, not as the
+	// first send left it.
+	baseMetadata := make(map[string]string, len(reply.Metadata))
+	for k, v := range reply.Metadata {
+		baseMetadata[k] = v
+	}
+	for i, body := range parts {
+		if i > 0 && s.spacing > 0 {
+			select {
+			case <-ctx.Done():
+				return fmt.Errorf("messaging: send part %d of %d: %w", i+1, len(parts), ctx.Err())
+			case <-time.After(s.spacing):
+			}
+		}
+		part := reply
+		part.Body = body
+		if i > 0 {
+			part.Metadata = make(map[string]string, len(baseMetadata))
+			for k, v := range baseMetadata {
+				part.Metadata[k] = v
+			}
+		}
+		if err := s.inner.SendReply(ctx, part); err != nil {
+			return fmt.Errorf("messaging: send part %d of %d: %w", i+1, len(parts), err)
+		}
+	}
+	return nil
+}
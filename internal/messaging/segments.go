@@ -0,0 +1,237 @@
+package messaging
+
+import (
+	"regexp"
+	"strings"
+	"unicode/utf16"
+)
+
+// SMS segment sizes. A message that fits a single segment gets the whole
+// payload; concatenated messages lose room to the UDH header in every part.
+const (
+	gsmSingleSegment  = 160
+	gsmConcatSegment  = 153
+	ucs2SingleSegment = 70
+	ucs2ConcatSegment = 67
+)
+
+// gsm7Basic is the GSM 03.38 default alphabet; each character costs one septet.
+const gsm7Basic = "@£$¥èéùìòÇ\nØø\rÅåΔ_ΦΓΛΩΠΨΣΘΞÆæßÉ !\"#¤%&'()*+,-./0123456789:;<=>?" +
+	"¡ABCDEFGHIJKLMNOPQRSTUVWXYZÄÖÑÜ§¿abcdefghijklmnopqrstuvwxyzäöñüà"
+
+// gsm7Extension characters are sent as an escape plus a septet, costing two.
+const gsm7Extension = "^{}\\[~]|€\f"
+
+// IsGSM7 reports whether text can be sent in the GSM-7 alphabet. Anything
+// else, including a single emoji or curly quote, forces the whole message to
+// UCS-2.
+func IsGSM7(text string) bool {
+	for _, r := range text {
+		if !strings.ContainsRune(gsm7Basic, r) && !strings.ContainsRune(gsm7Extension, r) {
+			return false
+		}
+	}
+	return true
+}
+
+// smsUnits returns the length of text in its encoding's units along with the
+// single and concatenated segment sizes for that encoding.
+func smsUnits(text string) (units, single, concat int) {
+	if IsGSM7(text) {
+		for _, r := range text {
+			units++
+			if strings.ContainsRune(gsm7Extension, r) {
+				units++
+			}
+		}
+		return units, gsmSingleSegment, gsmConcatSegment
+	}
+	// UCS-2 is counted in UTF-16 code units, so emoji outside the BMP cost two.
+	return len(utf16.Encode([]rune(text))), ucs2SingleSegment, ucs2ConcatSegment
+}
+
+// SegmentCount returns how many SMS segments carriers bill text as.
+func SegmentCount(text string) int {
+	units, single, concat := smsUnits(text)
+	if units == 0 {
+		return 0
+	}
+	if units <= single {
+		return 1
+	}
+	return (units + concat - 1) / concat
+}
+
+// slotLinePattern matches a numbered option line such as "1. Tue 3:00 PM".
+var slotLinePattern = regexp.MustCompile(`^\s*\d+[.)]\s`)
+
+// splitPiece is a chunk of a message and the separator that joined it to the
+// chunk before it.
+type splitPiece struct {
+	text string
+	sep  string
+}
+
+// SplitMessage breaks text into messages of at most maxSegments segments
+// each, preferring line breaks, then sentence ends, then spaces. A run of
+// numbered lines (an appointment slot list) is kept together when it fits and
+// otherwise only split between complete lines. Text that already fits, or a
+// maxSegments below 1, is returned as a single message.
+func SplitMessage(text string, maxSegments int) []string {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return nil
+	}
+	if maxSegments < 1 || SegmentCount(text) <= maxSegments {
+		return []string{text}
+	}
+	fits := func(s string) bool { return SegmentCount(s) <= maxSegments }
+
+	var pieces []splitPiece
+	for i, block := range groupSlotLines(strings.Split(text, "\n")) {
+		sep := "\n"
+		if i == 0 {
+			sep = ""
+		}
+		pieces = append(pieces, refinePiece(block, sep, 0, fits)...)
+	}
+
+	var (
+		messages []string
+		current  string
+		started  bool
+	)
+	flush := func() {
+		if msg := strings.TrimSpace(current); msg != "" {
+			messages = append(messages, msg)
+		}
+	}
+	for _, p := range pieces {
+		if !started {
+			current, started = p.text, true
+			continue
+		}
+		if candidate := current + p.sep + p.text; fits(candidate) {
+			current = candidate
+			continue
+		}
+		flush()
+		current = p.text
+	}
+	flush()
+	return messages
+}
+
+// groupSlotLines joins consecutive numbered lines into one block so the
+// packer treats a slot list as a unit.
+func groupSlotLines(lines []string) []string {
+	var blocks []string
+	inList := false
+	for _, line := range lines {
+		isSlot := slotLinePattern.MatchString(line)
+		if isSlot && inList {
+			blocks[len(blocks)-1] += "\n" + line
+			continue
+		}
+		blocks = append(blocks, line)
+		inList = isSlot
+	}
+	return blocks
+}
+
+// refinePiece splits text that doesn't fit at progressively finer boundaries:
+// lines of a slot block, then sentences, then words, then characters.
+func refinePiece(text, sep string, level int, fits func(string) bool) []splitPiece {
+	if fits(text) {
+		return []splitPiece{{text: text, sep: sep}}
+	}
+	var (
+		parts  []string
+		joiner string
+	)
+	switch level {
+	case 0:
+		parts, joiner = strings.Split(text, "\n"), "\n"
+	case 1:
+		parts, joiner = splitSentences(text), " "
+	case 2:
+		parts, joiner = strings.Fields(text), " "
+	default:
+		return hardSplit(text, sep, fits)
+	}
+	if len(parts) <= 1 {
+		return refinePiece(text, sep, level+1, fits)
+	}
+	var out []splitPiece
+	for i, part := range parts {
+		partSep := joiner
+		if i == 0 {
+			partSep = sep
+		}
+		out = append(out, refinePiece(part, partSep, level+1, fits)...)
+	}
+	return out
+}
+
+// splitSentences splits text after sentence-ending punctuation followed by a
+// space.
+func splitSentences(text string) []string {
+	runes := []rune(text)
+	var sentences []string
+	start := 0
+	for i, r := range runes {
+		if (r == '.' || r == '!' || r == '?') && i+1 < len(runes) && runes[i+1] == ' ' {
+			if s := strings.TrimSpace(string(runes[start : i+1])); s != "" {
+				sentences = append(sentences, s)
+			}
+			start = i + 1
+		}
+	}
+	if s := strings.TrimSpace(string(runes[start:])); s != "" {
+		sentences = append(sentences, s)
+	}
+	return sentences
+}
+
+// hardSplit cuts a single unbreakable word into chunks that fit.
+func hardSplit(text, sep string, fits func(string) bool) []splitPiece {
+	var out []splitPiece
+	var current []rune
+	for _, r := range text {
+		if len(current) > 0 && !fits(string(append(current, r))) {
+			out = append(out, splitPiece{text: string(current), sep: sep})
+			current, sep = nil, ""
+		}
+		current = append(current, r)
+	}
+	if len(current) > 0 {
+		out = append(out, splitPiece{text: string(current), sep: sep})
+	}
+	return out
+}
+
+// continuedIndicator marks a reply that was cut short because it needed more
+// messages than allowed.
+const continuedIndicator = " (cont'd)"
+
+// CapMessages keeps at most maxMessages parts. When parts have to be dropped,
+// the last kept part is trimmed back to a word boundary so it still fits
+// maxSegments with the "(cont'd)" indicator appended. It reports whether
+// anything was cut.
+func CapMessages(parts []string, maxSegments, maxMessages int) ([]string, bool) {
+	if maxMessages < 1 || len(parts) <= maxMessages {
+		return parts, false
+	}
+	capped := append([]string(nil), parts[:maxMessages]...)
+	last := capped[len(capped)-1]
+	for maxSegments > 0 && last != "" && SegmentCount(last+continuedIndicator) > maxSegments {
+		if idx := strings.LastIndexAny(last, " \n"); idx > 0 {
+			last = strings.TrimSpace(last[:idx])
+			continue
+		}
+		runes := []rune(last)
+		last = string(runes[:len(runes)-1])
+	}
+	capped[len(capped)-1] = strings.TrimSpace(last + continuedIndicator)
+	return capped, true
+}
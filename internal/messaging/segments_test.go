@@ -0,0 +1,164 @@
+package messaging
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/conversation"
+)
+
+type segmentRecorder struct {
+	bodies []string
+}
+
+func (r *segmentRecorder) SendReply(ctx context.Context, reply conversation.OutboundReply) error {
+	r.bodies = append(r.bodies, reply.Body)
+	if reply.Metadata != nil {
+		reply.Metadata["provider_message_id"] = fmt.Sprintf("msg-%d", len(r.bodies))
+	}
+	return nil
+}
+
+func TestSegmentCount(t *testing.T) {
+	cases := []struct {
+		name string
+		text string
+		want int
+	}{
+		{"empty", "", 0},
+		{"gsm single", strings.Repeat("a", 160), 1},
+		{"gsm concat", strings.Repeat("a", 161), 2},
+		{"gsm two full segments", strings.Repeat("a", 306), 2},
+		{"gsm third segment", strings.Repeat("a", 307), 3},
+		{"gsm extension counts double", strings.Repeat("€", 80), 1},
+		{"gsm extension overflows", strings.Repeat("€", 81), 2},
+		{"ucs2 single", strings.Repeat("a", 69) + "😊", 2},
+		{"ucs2 emoji", strings.Repeat("a", 68) + "😊", 1},
+		{"ucs2 concat", strings.Repeat("a", 134) + "é😊", 3},
+		{"ucs2 curly quote", "It’s " + strings.Repeat("a", 66), 2},
+		{"ucs2 two full segments", strings.Repeat("a", 133) + "’", 2},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := SegmentCount(tc.text); got != tc.want {
+				t.Fatalf("SegmentCount = %d, want %d", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestSplitMessage_EmojiForcesUCS2(t *testing.T) {
+	gsm := strings.TrimSpace(strings.Repeat("Your visit is confirmed for Tuesday. ", 8)) // 295 chars, 2 GSM segments
+	if got := SplitMessage(gsm, 2); len(got) != 1 {
+		t.Fatalf("expected GSM text to fit in one message, got %d", len(got))
+	}
+
+	// The same length with emoji is UCS-2, where 2 segments hold only 134 units.
+	ucs2 := strings.TrimSpace(strings.Repeat("Your visit is confirmed for Tuesday 😊. ", 8))
+	parts := SplitMessage(ucs2, 2)
+	if len(parts) != 3 {
+		t.Fatalf("expected emoji to force a UCS-2 split into 3 messages, got %d: %q", len(parts), parts)
+	}
+	for _, p := range parts {
+		if n := SegmentCount(p); n > 2 {
+			t.Fatalf("part uses %d segments: %q", n, p)
+		}
+		if !strings.HasSuffix(p, "😊.") {
+			t.Fatalf("expected split on a sentence boundary, got %q", p)
+		}
+	}
+}
+
+func TestSplitMessage_KeepsSlotListTogether(t *testing.T) {
+	intro := strings.TrimSpace(strings.Repeat("Thanks for waiting while I checked the schedule. ", 4))
+	slots := "1. Tue, Mar 3 at 10:00 AM\n2. Tue, Mar 3 at 2:30 PM\n3. Wed, Mar 4 at 11:00 AM\n4. Thu, Mar 5 at 4:00 PM"
+	text := intro + "\n" + slots + "\nReply with the number that works best."
+
+	parts := SplitMessage(text, 1)
+	found := false
+	for _, p := range parts {
+		if strings.Contains(p, "1. Tue") {
+			found = true
+			if !strings.Contains(p, slots) {
+				t.Fatalf("expected the full slot list in one message, got %q", p)
+			}
+		}
+	}
+	if !found {
+		t.Fatalf("slot list missing from parts: %q", parts)
+	}
+}
+
+func TestSplitMessage_SplitsLongSlotListBetweenLines(t *testing.T) {
+	var lines []string
+	for i := 1; i <= 12; i++ {
+		lines = append(lines, fmt.Sprintf("%d. Friday, March %d at 10:30 AM with Dr. Smith", i, i))
+	}
+	text := "Here are the openings:\n" + strings.Join(lines, "\n")
+
+	parts := SplitMessage(text, 1)
+	if len(parts) < 2 {
+		t.Fatalf("expected the list to need several messages, got %d", len(parts))
+	}
+	var rebuilt []string
+	for _, p := range parts {
+		if SegmentCount(p) > 1 {
+			t.Fatalf("part exceeds one segment: %q", p)
+		}
+		rebuilt = append(rebuilt, strings.Split(p, "\n")...)
+	}
+	for _, line := range lines {
+		whole := false
+		for _, got := range rebuilt {
+			if got == line {
+				whole = true
+			}
+		}
+		if !whole {
+			t.Fatalf("slot line %q was split mid-line: %q", line, parts)
+		}
+	}
+}
+
+func TestCapMessages(t *testing.T) {
+	parts := []string{strings.Repeat("one ", 38) + "one", "two", "three"}
+	capped, truncated := CapMessages(parts, 1, 1)
+	if !truncated || len(capped) != 1 {
+		t.Fatalf("expected one truncated part, got %v %q", truncated, capped)
+	}
+	if !strings.HasSuffix(capped[0], "(cont'd)") || SegmentCount(capped[0]) > 1 {
+		t.Fatalf("expected a single segment ending in (cont'd), got %q", capped[0])
+	}
+	if _, truncated := CapMessages(parts, 1, 3); truncated {
+		t.Fatalf("expected no truncation within the cap")
+	}
+}
+
+func TestSegmentSplittingMessenger(t *testing.T) {
+	inner := &segmentRecorder{}
+	wrapped := WrapWithSegmentSplitting(inner, SegmentSplitConfig{MaxSegments: 1, MaxMessages: 2})
+
+	meta := map[string]string{"job_id": "job-1"}
+	body := strings.TrimSpace(strings.Repeat("We have plenty of openings next week for a consultation. ", 6))
+	if err := wrapped.SendReply(context.Background(), conversation.OutboundReply{Body: body, Metadata: meta}); err != nil {
+		t.Fatalf("SendReply: %v", err)
+	}
+	if len(inner.bodies) != 2 {
+		t.Fatalf("expected the cap to limit the reply to 2 messages, got %d", len(inner.bodies))
+	}
+	if !strings.HasSuffix(inner.bodies[1], "(cont'd)") {
+		t.Fatalf("expected the last message to be marked (cont'd), got %q", inner.bodies[1])
+	}
+	if meta["provider_message_id"] != "msg-1" {
+		t.Fatalf("expected the first part's provider id on the caller's metadata, got %q", meta["provider_message_id"])
+	}
+}
+
+func TestWrapWithSegmentSplitting_Disabled(t *testing.T) {
+	inner := &segmentRecorder{}
+	if got := WrapWithSegmentSplitting(inner, SegmentSplitConfig{}); got != inner {
+		t.Fatalf("expected the original messenger when splitting is disabled")
+	}
+}
@@ -11,6 +11,9 @@ import (
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/conversation/msgschema"
+	"github.com/wolfman30/medspa-ai-platform/internal/pii"
 )
 
 type Querier interface {
@@ -28,7 +31,8 @@ type PgxPool interface {
 }
 
 type Store struct {
-	pool PgxPool
+	pool   PgxPool
+	cipher *pii.Cipher
 }
 
 func NewStore(pool PgxPool) *Store {
@@ -38,6 +42,12 @@ func NewStore(pool PgxPool) *Store {
 	return &Store{pool: pool}
 }
 
+// SetCipher enables encryption of message bodies at rest. Bodies stored
+// before encryption was enabled are still read as plaintext.
+func (s *Store) SetCipher(c *pii.Cipher) {
+	s.cipher = c
+}
+
 func (s *Store) Begin(ctx context.Context) (pgx.Tx, error) {
 	return s.pool.Begin(ctx)
 }
@@ -183,13 +193,23 @@ func (s *Store) InsertCampaign(ctx context.Context, q Querier, rec CampaignRecor
 }
 
 type MessageRecord struct {
-	ID                uuid.UUID
-	ClinicID          uuid.UUID
-	From              string
-	To                string
-	Direction         string
-	Body              string
-	Media             []string
+	ID        uuid.UUID
+	ClinicID  uuid.UUID
+	From      string
+	To        string
+	Direction string
+	// AuthorType is "patient", "ai", "staff" or "system"; InsertMessage
+	// records inbound messages as the patient's when it's empty.
+	AuthorType string
+	Body       string
+	Media      []string
+	// AttachmentKinds classifies each of Media (image, vcard, pdf or
+	// unknown) on inbound messages.
+	AttachmentKinds []string
+	// TemplateID and TemplateVersion name the outbound template the body was
+	// rendered from; empty for LLM and staff messages.
+	TemplateID        string
+	TemplateVersion   string
 	ProviderStatus    string
 	ProviderMessageID string
 	SendAttempts      int
@@ -206,24 +226,33 @@ func (s *Store) InsertMessage(ctx context.Context, q Querier, rec MessageRecord)
 	if rec.Media == nil {
 		rec.Media = []string{}
 	}
+	if rec.AttachmentKinds == nil {
+		rec.AttachmentKinds = []string{}
+	}
 	media, err := json.Marshal(rec.Media)
 	if err != nil {
 		return uuid.Nil, fmt.Errorf("messaging: marshal media: %w", err)
 	}
+	body, err := s.cipher.Encrypt(rec.Body)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("messaging: encrypt body: %w", err)
+	}
+	_, author := msgschema.Message{Direction: rec.Direction, AuthorType: rec.AuthorType}.Resolve()
 	query := `
 		INSERT INTO messages (
 			clinic_id, from_e164, to_e164, direction, body,
 			mms_media, provider_status, provider_message_id, delivered_at, failed_at,
-			send_attempts, last_attempt_at, next_retry_at
+			send_attempts, last_attempt_at, next_retry_at, author_type, attachment_kinds,
+			template_id, template_version
 		)
-		VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13)
+		VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,NULLIF($14, ''),$15,NULLIF($16, ''),NULLIF($17, ''))
 		ON CONFLICT (provider_message_id) WHERE provider_message_id IS NOT NULL DO UPDATE SET
 			body = EXCLUDED.body,
 			provider_status = EXCLUDED.provider_status
 		RETURNING id
 	`
 	var id uuid.UUID
-	if err := q.QueryRow(ctx, query, rec.ClinicID, rec.From, rec.To, rec.Direction, rec.Body, media, rec.ProviderStatus, rec.ProviderMessageID, rec.DeliveredAt, rec.FailedAt, rec.SendAttempts, rec.LastAttemptAt, rec.NextRetryAt).Scan(&id); err != nil {
+	if err := q.QueryRow(ctx, query, rec.ClinicID, rec.From, rec.To, rec.Direction, body, media, rec.ProviderStatus, rec.ProviderMessageID, rec.DeliveredAt, rec.FailedAt, rec.SendAttempts, rec.LastAttemptAt, rec.NextRetryAt, string(author), rec.AttachmentKinds, rec.TemplateID, rec.TemplateVersion).Scan(&id); err != nil {
 		return uuid.Nil, fmt.Errorf("messaging: insert message: %w", err)
 	}
 	return id, nil
@@ -269,6 +298,56 @@ func (s *Store) HasProviderMessage(ctx context.Context, providerMessageID string
 	return true, nil
 }
 
+// OutboundByProviderID returns the outbound message the provider knows as
+// providerMessageID, or nil when there is none.
+func (s *Store) OutboundByProviderID(ctx context.Context, providerMessageID string) (*MessageRecord, error) {
+	providerMessageID = strings.TrimSpace(providerMessageID)
+	if providerMessageID == "" {
+		return nil, nil
+	}
+	query := `
+		SELECT id, clinic_id, from_e164, to_e164, body, mms_media, provider_status, provider_message_id
+		FROM messages
+		WHERE provider_message_id = $1 AND direction = 'outbound'
+		LIMIT 1
+	`
+	rec := MessageRecord{Direction: "outbound"}
+	var media []byte
+	err := s.pool.QueryRow(ctx, query, providerMessageID).Scan(&rec.ID, &rec.ClinicID, &rec.From, &rec.To, &rec.Body, &media, &rec.ProviderStatus, &rec.ProviderMessageID)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("messaging: load outbound message: %w", err)
+	}
+	if rec.Body, err = s.cipher.Decrypt(rec.Body); err != nil {
+		return nil, fmt.Errorf("messaging: decrypt outbound message: %w", err)
+	}
+	if err := json.Unmarshal(media, &rec.Media); err != nil {
+		return nil, fmt.Errorf("messaging: decode media: %w", err)
+	}
+	return &rec, nil
+}
+
+// HasOutboundSince reports whether the clinic texted the recipient since the
+// given time. Suppressed sends don't count.
+func (s *Store) HasOutboundSince(ctx context.Context, clinicID uuid.UUID, to string, since time.Time) (bool, error) {
+	query := `
+		SELECT 1 FROM messages
+		WHERE clinic_id = $1 AND to_e164 = $2 AND direction = 'outbound'
+		  AND COALESCE(provider_status, '') <> 'suppressed' AND created_at >= $3
+		LIMIT 1
+	`
+	var exists int
+	if err := s.pool.QueryRow(ctx, query, clinicID, to, since).Scan(&exists); err != nil {
+		if err == pgx.ErrNoRows {
+			return false, nil
+		}
+		return false, fmt.Errorf("messaging: check outbound message: %w", err)
+	}
+	return true, nil
+}
+
 func (s *Store) UpdateMessageStatus(ctx context.Context, providerMessageID, status string, deliveredAt, failedAt *time.Time) error {
 	query := `
 		UPDATE messages
@@ -320,6 +399,42 @@ func (s *Store) UpdateMessageProviderID(ctx context.Context, msgID uuid.UUID, pr
 	return nil
 }
 
+// UpdateMessageSender records which provider delivered an outbound message
+// and the number it went out from, which changes when a send fails over.
+func (s *Store) UpdateMessageSender(ctx context.Context, msgID uuid.UUID, provider, from string) error {
+	provider = strings.TrimSpace(provider)
+	if provider == "" {
+		return nil
+	}
+	query := `
+		UPDATE messages
+		SET provider = $2,
+		    from_e164 = COALESCE(NULLIF($3, ''), from_e164)
+		WHERE id = $1
+	`
+	_, err := s.pool.Exec(ctx, query, msgID, provider, strings.TrimSpace(from))
+	if err != nil {
+		return fmt.Errorf("messaging: update message sender: %w", err)
+	}
+	return nil
+}
+
+// UpdateMessageCost records how many segments an outbound message was billed
+// as and what it cost. A nil cost leaves the estimate to the cost rollup.
+func (s *Store) UpdateMessageCost(ctx context.Context, msgID uuid.UUID, segments int, costUSD *float64) error {
+	query := `
+		UPDATE messages
+		SET segments = $2,
+		    cost_usd = $3
+		WHERE id = $1
+	`
+	_, err := s.pool.Exec(ctx, query, msgID, segments, costUSD)
+	if err != nil {
+		return fmt.Errorf("messaging: update message cost: %w", err)
+	}
+	return nil
+}
+
 func (s *Store) InsertUnsubscribe(ctx context.Context, q Querier, clinicID uuid.UUID, recipient string, source string) error {
 	if q == nil {
 		q = s.pool
@@ -409,6 +524,9 @@ func (s *Store) ListRetryCandidates(ctx context.Context, limit int, maxAttempts
 		if err := rows.Scan(&rec.ID, &rec.ClinicID, &rec.From, &rec.To, &rec.Body, &media, &rec.ProviderStatus, &rec.ProviderMessageID, &rec.SendAttempts, &nextRetry); err != nil {
 			return nil, fmt.Errorf("messaging: scan retry candidate: %w", err)
 		}
+		if rec.Body, err = s.cipher.Decrypt(rec.Body); err != nil {
+			return nil, fmt.Errorf("messaging: decrypt retry candidate: %w", err)
+		}
 		if err := json.Unmarshal(media, &rec.Media); err != nil {
 			return nil, fmt.Errorf("messaging: decode media: %w", err)
 		}
@@ -1,12 +1,19 @@
 package messaging
 
 import (
+	"bytes"
 	"context"
+	"errors"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/google/uuid"
 	pgxmock "github.com/pashagolub/pgxmock/v4"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/conversation"
+	"github.com/wolfman30/medspa-ai-platform/internal/messaging/templates"
+	"github.com/wolfman30/medspa-ai-platform/internal/pii"
 )
 
 func TestStoreInsertMessage(t *testing.T) {
@@ -19,7 +26,7 @@ func TestStoreInsertMessage(t *testing.T) {
 	store := &Store{pool: mock}
 	clinicID := uuid.New()
 	mock.ExpectQuery("INSERT INTO messages").
-		WithArgs(clinicID, "+1555", "+1666", "outbound", "hello", pgxmock.AnyArg(), "queued", "msg_1", pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg()).
+		WithArgs(clinicID, "+1555", "+1666", "outbound", "hello", pgxmock.AnyArg(), "queued", "msg_1", pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg()).
 		WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(uuid.New()))
 
 	if _, err := store.InsertMessage(context.Background(), mock, MessageRecord{
@@ -35,6 +42,43 @@ func TestStoreInsertMessage(t *testing.T) {
 	}
 }
 
+type failingMessenger struct{}
+
+func (failingMessenger) SendReply(context.Context, conversation.OutboundReply) error {
+	return errors.New("carrier unavailable")
+}
+
+func TestPersistingMessengerRecordsTemplate(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("pgxmock: %v", err)
+	}
+	defer mock.Close()
+
+	msgID := uuid.New()
+	rendered := templates.Render(templates.PaymentFailed, nil, nil)
+	mock.ExpectQuery("INSERT INTO messages").
+		WithArgs(pgxmock.AnyArg(), "+1555", "+1666", "outbound", rendered.Body, pgxmock.AnyArg(), "pending", "", pgxmock.AnyArg(), pgxmock.AnyArg(), 1, pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), templates.PaymentFailed, "1").
+		WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(msgID))
+	mock.ExpectExec("UPDATE messages").
+		WithArgs(msgID, "failed", (*time.Time)(nil), pgxmock.AnyArg()).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+
+	messenger := WrapWithPersistence(failingMessenger{}, &Store{pool: mock}, nil)
+	if err := messenger.SendReply(context.Background(), conversation.OutboundReply{
+		OrgID:    uuid.NewString(),
+		From:     "+1555",
+		To:       "+1666",
+		Body:     rendered.Body,
+		Metadata: rendered.Stamp(nil),
+	}); err == nil {
+		t.Fatal("expected send error to be returned")
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
 func TestStoreUpdateMessageStatus(t *testing.T) {
 	mock, err := pgxmock.NewPool()
 	if err != nil {
@@ -114,3 +158,57 @@ func TestPendingHostedOrders(t *testing.T) {
 		t.Fatalf("pending hosted: %v", err)
 	}
 }
+
+// capturedArg matches any argument and keeps it.
+type capturedArg struct{ value any }
+
+func (c *capturedArg) Match(v any) bool {
+	c.value = v
+	return true
+}
+
+func TestStoreEncryptsMessageBodies(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("pgxmock: %v", err)
+	}
+	defer mock.Close()
+	cipher, err := pii.NewCipher(pii.Config{
+		Keys:        map[string][]byte{"k1": bytes.Repeat([]byte{7}, 32)},
+		ActiveKeyID: "k1",
+		HMACKey:     []byte("0123456789abcdef"),
+	})
+	if err != nil {
+		t.Fatalf("cipher: %v", err)
+	}
+	store := &Store{pool: mock}
+	store.SetCipher(cipher)
+
+	body := &capturedArg{}
+	msgID := uuid.New()
+	mock.ExpectQuery("INSERT INTO messages").
+		WithArgs(pgxmock.AnyArg(), "+1555", "+1666", "outbound", body, pgxmock.AnyArg(), "failed", "", pgxmock.AnyArg(), pgxmock.AnyArg(), 0, pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg()).
+		WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(msgID))
+	if _, err := store.InsertMessage(context.Background(), nil, MessageRecord{
+		ClinicID: uuid.New(), From: "+1555", To: "+1666", Direction: "outbound", Body: "Your Botox is booked", ProviderStatus: "failed",
+	}); err != nil {
+		t.Fatalf("insert: %v", err)
+	}
+	sealed, _ := body.value.(string)
+	if !pii.IsEncrypted(sealed) || strings.Contains(sealed, "Botox") {
+		t.Fatalf("body stored as %q", sealed)
+	}
+
+	mock.ExpectQuery("SELECT id, clinic_id").
+		WithArgs(5, 10).
+		WillReturnRows(pgxmock.NewRows([]string{"id", "clinic_id", "from_e164", "to_e164", "body", "mms_media", "provider_status", "provider_message_id", "send_attempts", "next_retry_at"}).
+			AddRow(msgID, uuid.New(), "+1555", "+1666", sealed, []byte(`[]`), "failed", "", 1, nil).
+			AddRow(uuid.New(), uuid.New(), "+1555", "+1666", "sent before encryption", []byte(`[]`), "failed", "", 1, nil))
+	candidates, err := store.ListRetryCandidates(context.Background(), 10, 5)
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if len(candidates) != 2 || candidates[0].Body != "Your Botox is booked" || candidates[1].Body != "sent before encryption" {
+		t.Fatalf("candidates = %+v", candidates)
+	}
+}
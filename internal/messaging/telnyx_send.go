@@ -9,6 +9,7 @@ import (
 	"io"
 	"math/rand"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
@@ -16,15 +17,21 @@ import (
 	"go.opentelemetry.io/otel/attribute"
 
 	"github.com/wolfman30/medspa-ai-platform/internal/conversation"
+	"github.com/wolfman30/medspa-ai-platform/internal/costs"
+	"github.com/wolfman30/medspa-ai-platform/internal/messaging/telnyxclient"
 	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
 )
 
+const defaultTelnyxMessagesURL = "https://api.telnyx.com/v2/messages"
+
 var telnyxSendTracer = otel.Tracer("medspa.internal.messaging.telnyx_send")
 
 // TelnyxSender posts SMS messages using Telnyx's V2 API.
 type TelnyxSender struct {
 	apiKey             string
 	messagingProfileID string
+	messagesURL        string
+	segmentRateUSD     float64
 	httpClient         *http.Client
 	logger             *logging.Logger
 }
@@ -37,6 +44,8 @@ func NewTelnyxSender(apiKey, messagingProfileID string, logger *logging.Logger)
 	return &TelnyxSender{
 		apiKey:             apiKey,
 		messagingProfileID: messagingProfileID,
+		messagesURL:        defaultTelnyxMessagesURL,
+		segmentRateUSD:     costs.DefaultSMSSegmentRateUSD,
 		httpClient: &http.Client{
 			Timeout: 10 * time.Second,
 		},
@@ -44,6 +53,26 @@ func NewTelnyxSender(apiKey, messagingProfileID string, logger *logging.Logger)
 	}
 }
 
+// SetBaseURL points the sender at an alternate Telnyx-compatible API root
+// such as a local fake. Like telnyxclient.Config.BaseURL it includes the
+// version segment (".../v2"); empty values keep the production endpoint.
+func (s *TelnyxSender) SetBaseURL(baseURL string) {
+	baseURL = strings.TrimRight(strings.TrimSpace(baseURL), "/")
+	if baseURL == "" {
+		s.messagesURL = defaultTelnyxMessagesURL
+		return
+	}
+	s.messagesURL = baseURL + "/messages"
+}
+
+// SetSegmentRate sets the per-segment price used to estimate a message's cost
+// when the send response doesn't include one. Non-positive rates are ignored.
+func (s *TelnyxSender) SetSegmentRate(rateUSD float64) {
+	if rateUSD > 0 {
+		s.segmentRateUSD = rateUSD
+	}
+}
+
 var _ conversation.ReplyMessenger = (*TelnyxSender)(nil)
 
 // SendReply dispatches a single SMS via Telnyx V2 API, retrying transient failures.
@@ -61,6 +90,8 @@ func (s *TelnyxSender) SendReply(ctx context.Context, msg conversation.OutboundR
 		return errors.New("messaging: body required")
 	}
 
+	ctx = replyLogContext(ctx, msg)
+	log := s.logger.WithContext(ctx)
 	ctx, span := telnyxSendTracer.Start(ctx, "messaging.telnyx.send")
 	defer span.End()
 	span.SetAttributes(
@@ -68,6 +99,7 @@ func (s *TelnyxSender) SendReply(ctx context.Context, msg conversation.OutboundR
 		attribute.String("medspa.to", msg.To),
 		attribute.String("medspa.from", msg.From),
 	)
+	span.SetAttributes(logging.SpanAttributes(ctx)...)
 
 	payload := map[string]interface{}{
 		"from": msg.From,
@@ -86,7 +118,7 @@ func (s *TelnyxSender) SendReply(ctx context.Context, msg conversation.OutboundR
 			return fmt.Errorf("messaging: failed to marshal telnyx payload: %w", err)
 		}
 
-		req, err := http.NewRequestWithContext(ctx, http.MethodPost, "https://api.telnyx.com/v2/messages", bytes.NewReader(bodyBytes))
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.messagesURL, bytes.NewReader(bodyBytes))
 		if err != nil {
 			lastErr = err
 			break
@@ -101,32 +133,30 @@ func (s *TelnyxSender) SendReply(ctx context.Context, msg conversation.OutboundR
 			defer resp.Body.Close()
 			body, _ := io.ReadAll(io.LimitReader(resp.Body, 8192))
 			if resp.StatusCode >= 200 && resp.StatusCode < 300 {
-				if msg.Metadata != nil && len(body) > 0 {
+				if msg.Metadata != nil {
+					msg.Metadata[metadataProvider] = SMSProviderTelnyx
+					msg.Metadata[metadataProviderFrom] = msg.From
+				}
+				if msg.Metadata != nil {
 					var parsed struct {
-						Data struct {
-							ID     string `json:"id"`
-							Status string `json:"status"`
-						} `json:"data"`
+						Data telnyxclient.MessageResponse `json:"data"`
 					}
-					if err := json.Unmarshal(body, &parsed); err == nil {
-						if parsed.Data.ID != "" {
-							msg.Metadata["provider_message_id"] = parsed.Data.ID
-						}
-						if parsed.Data.Status != "" {
-							msg.Metadata["provider_status"] = parsed.Data.Status
+					if len(body) > 0 {
+						if err := json.Unmarshal(body, &parsed); err == nil {
+							if parsed.Data.ID != "" {
+								msg.Metadata["provider_message_id"] = parsed.Data.ID
+							}
+							if parsed.Data.Status != "" {
+								msg.Metadata["provider_status"] = parsed.Data.Status
+							}
 						}
 					}
+					s.recordCost(msg, &parsed.Data)
 				}
-				s.logger.Info("telnyx sms sent", "org_id", msg.OrgID, "to", msg.To, "from", msg.From)
+				log.Info("telnyx sms sent", "org_id", msg.OrgID, "to", msg.To, "from", msg.From)
 				return nil
 			}
-			// Read error response for better debugging
-			var errorBody map[string]interface{}
-			if len(body) > 0 && json.Unmarshal(body, &errorBody) == nil {
-				lastErr = fmt.Errorf("telnyx send failed: status %d, body: %v", resp.StatusCode, errorBody)
-			} else {
-				lastErr = fmt.Errorf("telnyx send failed: status %d", resp.StatusCode)
-			}
+			lastErr = telnyxProviderError(resp.StatusCode, body)
 		}
 
 		if attempt < 3 {
@@ -137,7 +167,47 @@ func (s *TelnyxSender) SendReply(ctx context.Context, msg conversation.OutboundR
 
 	if lastErr != nil {
 		span.RecordError(lastErr)
-		s.logger.Error("failed to send telnyx sms", "error", lastErr, "org_id", msg.OrgID, "to", msg.To)
+		log.Error("failed to send telnyx sms", "error", lastErr, "org_id", msg.OrgID, "to", msg.To)
 	}
 	return lastErr
 }
+
+// recordCost fills in the segment count and cost of an accepted message,
+// preferring what Telnyx reported and otherwise counting segments locally and
+// pricing them at the configured rate.
+func (s *TelnyxSender) recordCost(msg conversation.OutboundReply, resp *telnyxclient.MessageResponse) {
+	segments := resp.Parts
+	if segments <= 0 {
+		segments = SegmentCount(msg.Body)
+	}
+	cost, ok := resp.CostUSD()
+	if !ok {
+		cost = float64(segments) * s.segmentRateUSD
+	}
+	msg.Metadata[metadataSegments] = strconv.Itoa(segments)
+	msg.Metadata[metadataCostUSD] = strconv.FormatFloat(cost, 'f', -1, 64)
+}
+
+// telnyxProviderError decodes Telnyx's {"errors": [{"code", "title",
+// "detail"}]} body, keeping the first error's code for failover decisions.
+func telnyxProviderError(status int, body []byte) *ProviderError {
+	providerErr := &ProviderError{Provider: SMSProviderTelnyx, StatusCode: status}
+	var parsed struct {
+		Errors []struct {
+			Code   string `json:"code"`
+			Title  string `json:"title"`
+			Detail string `json:"detail"`
+		} `json:"errors"`
+	}
+	if err := json.Unmarshal(body, &parsed); err == nil && len(parsed.Errors) > 0 {
+		first := parsed.Errors[0]
+		providerErr.Code = first.Code
+		providerErr.Message = first.Title
+		if first.Detail != "" {
+			providerErr.Message = first.Detail
+		}
+		return providerErr
+	}
+	providerErr.Message = string(bytesTrimSpace(body))
+	return providerErr
+}
@@ -0,0 +1,58 @@
+package messaging
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/conversation"
+)
+
+func TestTelnyxSenderRecordsReportedCost(t *testing.T) {
+	api := &providerAPI{status: http.StatusOK, body: `{"data":{"id":"msg_1","status":"queued","parts":2,"cost":{"amount":"0.0079","currency":"USD"}}}`}
+	sender := NewTelnyxSender("key", "profile", nil)
+	sender.httpClient = &http.Client{Transport: api}
+
+	reply := conversation.OutboundReply{To: "+15557770000", From: "+15550001111", Body: "hi", Metadata: map[string]string{}}
+	if err := sender.SendReply(context.Background(), reply); err != nil {
+		t.Fatalf("SendReply: %v", err)
+	}
+	if got := reply.Metadata[metadataSegments]; got != "2" {
+		t.Errorf("segments = %q, want the provider's part count 2", got)
+	}
+	if got := reply.Metadata[metadataCostUSD]; got != "0.0079" {
+		t.Errorf("cost = %q, want the provider's 0.0079", got)
+	}
+}
+
+func TestTelnyxSenderEstimatesCostWhenUnreported(t *testing.T) {
+	api := &providerAPI{status: http.StatusOK, body: `{"data":{"id":"msg_1","status":"queued","cost":null}}`}
+	sender := NewTelnyxSender("key", "profile", nil)
+	sender.httpClient = &http.Client{Transport: api}
+	sender.SetSegmentRate(0.005)
+
+	// An emoji forces UCS-2: 71 code units is two 67-unit segments.
+	body := strings.Repeat("a", 70) + "😊"
+	reply := conversation.OutboundReply{To: "+15557770000", From: "+15550001111", Body: body, Metadata: map[string]string{}}
+	if err := sender.SendReply(context.Background(), reply); err != nil {
+		t.Fatalf("SendReply: %v", err)
+	}
+	if got := reply.Metadata[metadataSegments]; got != "2" {
+		t.Errorf("segments = %q, want 2", got)
+	}
+	if got := reply.Metadata[metadataCostUSD]; got != "0.01" {
+		t.Errorf("cost = %q, want 2 segments at 0.005", got)
+	}
+}
+
+func TestReplyCost(t *testing.T) {
+	segments, cost := replyCost(conversation.OutboundReply{Body: strings.Repeat("a", 161), Metadata: map[string]string{}})
+	if segments != 2 || cost != nil {
+		t.Fatalf("unreported: segments=%d cost=%v, want 2 segments counted locally and no cost", segments, cost)
+	}
+	segments, cost = replyCost(conversation.OutboundReply{Body: "hi", Metadata: map[string]string{metadataSegments: "3", metadataCostUSD: "0.012"}})
+	if segments != 3 || cost == nil || *cost != 0.012 {
+		t.Fatalf("reported: segments=%d cost=%v, want 3 and 0.012", segments, cost)
+	}
+}
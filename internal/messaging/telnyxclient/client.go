@@ -255,6 +255,50 @@ func (c *Client) CreateCampaign(ctx context.Context, req CampaignRequest) (*Camp
 	return decodeDataWrapper[Campaign](data)
 }
 
+// SearchAvailableNumbers lists purchasable numbers matching req.
+func (c *Client) SearchAvailableNumbers(ctx context.Context, req NumberSearchRequest) ([]AvailableNumber, error) {
+	data, err := c.invoke(ctx, http.MethodGet, "/available_phone_numbers", req.query(), nil, "")
+	if err != nil {
+		return nil, err
+	}
+	numbers, err := decodeDataWrapper[[]AvailableNumber](data)
+	if err != nil {
+		return nil, err
+	}
+	return *numbers, nil
+}
+
+// OrderNumber purchases a number and attaches it to the messaging profile in
+// req. Telnyx completes the order asynchronously; the number_order.complete
+// webhook reports the final status.
+func (c *Client) OrderNumber(ctx context.Context, req NumberOrderRequest) (*NumberOrder, error) {
+	if err := req.validate(); err != nil {
+		return nil, err
+	}
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("telnyxclient: marshal number order payload: %w", err)
+	}
+	data, err := c.invoke(ctx, http.MethodPost, "/number_orders", nil, body, "application/json")
+	if err != nil {
+		return nil, err
+	}
+	return decodeDataWrapper[NumberOrder](data)
+}
+
+// AssignCampaignNumber links a number to a 10DLC campaign.
+func (c *Client) AssignCampaignNumber(ctx context.Context, campaignID, phoneNumber string) error {
+	if strings.TrimSpace(campaignID) == "" || strings.TrimSpace(phoneNumber) == "" {
+		return errors.New("telnyxclient: campaign id and phone number required")
+	}
+	body, err := json.Marshal(map[string]string{"campaignId": campaignID, "phoneNumber": phoneNumber})
+	if err != nil {
+		return fmt.Errorf("telnyxclient: marshal campaign number payload: %w", err)
+	}
+	_, err = c.invoke(ctx, http.MethodPost, "/10dlc/phone_number_campaigns", nil, body, "application/json")
+	return err
+}
+
 // VerifyWebhookSignature validates Telnyx webhook signatures.
 // If webhookSecret is empty, signature verification is skipped (for development/testing only).
 func (c *Client) VerifyWebhookSignature(timestamp, signature string, payload []byte) error {
@@ -10,6 +10,7 @@ import (
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"os"
 	"strconv"
 	"strings"
@@ -59,6 +60,34 @@ func TestSendMessage(t *testing.T) {
 	if resp.ID != "msg_01J123ABC" || resp.Status != "queued" {
 		t.Fatalf("unexpected response: %#v", resp)
 	}
+	if cost, ok := resp.CostUSD(); !ok || cost != 0.004 || resp.Parts != 1 {
+		t.Fatalf("cost = %v (%v), parts = %d; want 0.004 USD over 1 part", cost, ok, resp.Parts)
+	}
+}
+
+func TestMessageResponseCostUSD(t *testing.T) {
+	cases := []struct {
+		name   string
+		cost   *MessageCost
+		want   float64
+		wantOK bool
+	}{
+		{"not billed yet", nil, 0, false},
+		{"usd", &MessageCost{Amount: "0.0080", Currency: "USD"}, 0.008, true},
+		{"no currency", &MessageCost{Amount: "0.004"}, 0.004, true},
+		{"other currency", &MessageCost{Amount: "0.004", Currency: "EUR"}, 0, false},
+		{"empty amount", &MessageCost{Currency: "USD"}, 0, false},
+		{"garbage", &MessageCost{Amount: "n/a", Currency: "USD"}, 0, false},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			resp := MessageResponse{Cost: tc.cost}
+			got, ok := resp.CostUSD()
+			if got != tc.want || ok != tc.wantOK {
+				t.Fatalf("CostUSD() = %v, %v; want %v, %v", got, ok, tc.want, tc.wantOK)
+			}
+		})
+	}
 }
 
 func TestNewClientDefaultsAndValidation(t *testing.T) {
@@ -331,6 +360,70 @@ func TestCreateBrandAndCampaign(t *testing.T) {
 	}
 }
 
+func TestNumberSearchOrderAndCampaignAssignment(t *testing.T) {
+	var orderBody, assignBody []byte
+	var searchQuery url.Values
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/available_phone_numbers":
+			searchQuery = r.URL.Query()
+			w.Write([]byte(`{"data":[{"phone_number":"+15125550100","cost_information":{"monthly_cost":"1.00","currency":"USD"}}]}`))
+		case "/number_orders":
+			orderBody, _ = io.ReadAll(r.Body)
+			w.Write([]byte(`{"data":{"id":"ord_1","status":"pending","phone_numbers":[{"id":"pn_1","phone_number":"+15125550100","status":"pending"}],"messaging_profile_id":"mp_1"}}`))
+		case "/10dlc/phone_number_campaigns":
+			assignBody, _ = io.ReadAll(r.Body)
+			w.Write([]byte(`{"phoneNumber":"+15125550100","campaignId":"C321"}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	client := newTestClient(t, server, Config{})
+	ctx := context.Background()
+	numbers, err := client.SearchAvailableNumbers(ctx, NumberSearchRequest{AreaCode: "512", Limit: 5})
+	if err != nil {
+		t.Fatalf("search numbers: %v", err)
+	}
+	if len(numbers) != 1 || numbers[0].PhoneNumber != "+15125550100" || numbers[0].CostInformation.MonthlyCost != "1.00" {
+		t.Fatalf("unexpected numbers: %#v", numbers)
+	}
+	if searchQuery.Get("filter[country_code]") != "US" || searchQuery.Get("filter[national_destination_code]") != "512" ||
+		searchQuery.Get("filter[features][]") != "sms" || searchQuery.Get("filter[limit]") != "5" {
+		t.Fatalf("unexpected search query: %v", searchQuery)
+	}
+
+	order, err := client.OrderNumber(ctx, NumberOrderRequest{
+		PhoneNumbers:       []OrderedNumber{{PhoneNumber: "+15125550100"}},
+		MessagingProfileID: "mp_1",
+	})
+	if err != nil {
+		t.Fatalf("order number: %v", err)
+	}
+	if order.ID != "ord_1" || order.Status != "pending" || len(order.PhoneNumbers) != 1 {
+		t.Fatalf("unexpected order: %#v", order)
+	}
+	if !strings.Contains(string(orderBody), `"messaging_profile_id":"mp_1"`) || !strings.Contains(string(orderBody), `"phone_number":"+15125550100"`) {
+		t.Fatalf("unexpected order body: %s", orderBody)
+	}
+
+	if err := client.AssignCampaignNumber(ctx, "C321", "+15125550100"); err != nil {
+		t.Fatalf("assign campaign number: %v", err)
+	}
+	if !strings.Contains(string(assignBody), `"campaignId":"C321"`) {
+		t.Fatalf("unexpected assignment body: %s", assignBody)
+	}
+
+	if _, err := client.OrderNumber(ctx, NumberOrderRequest{}); err == nil {
+		t.Fatalf("expected validation error for empty order")
+	}
+	if err := client.AssignCampaignNumber(ctx, "", "+15125550100"); err == nil {
+		t.Fatalf("expected validation error for missing campaign")
+	}
+}
+
 func TestCreateBrandHTTPError(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusBadGateway)
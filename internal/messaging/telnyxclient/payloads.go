@@ -3,6 +3,8 @@ package telnyxclient
 import (
 	"encoding/json"
 	"errors"
+	"net/url"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -41,6 +43,31 @@ type MessageResponse struct {
 	FromRaw        json.RawMessage `json:"from"`
 	ToRaw          json.RawMessage `json:"to"`
 	CarrierMessage string          `json:"carrier_status"`
+	// Cost is what Telnyx billed for the message. It is often null in the
+	// send response and only filled in on later webhooks.
+	Cost *MessageCost `json:"cost"`
+}
+
+// MessageCost is a Telnyx amount, sent as a decimal string.
+type MessageCost struct {
+	Amount   string `json:"amount"`
+	Currency string `json:"currency"`
+}
+
+// CostUSD returns the billed cost in USD, or false when Telnyx didn't report
+// one or reported it in another currency.
+func (m *MessageResponse) CostUSD() (float64, bool) {
+	if m.Cost == nil || strings.TrimSpace(m.Cost.Amount) == "" {
+		return 0, false
+	}
+	if currency := strings.TrimSpace(m.Cost.Currency); currency != "" && !strings.EqualFold(currency, "USD") {
+		return 0, false
+	}
+	amount, err := strconv.ParseFloat(strings.TrimSpace(m.Cost.Amount), 64)
+	if err != nil || amount < 0 {
+		return 0, false
+	}
+	return amount, true
 }
 
 // From returns the sender phone number, handling both string and object formats.
@@ -220,3 +247,87 @@ type Campaign struct {
 	SampleMessages []string  `json:"sample_messages,omitempty"`
 	CreatedAt      time.Time `json:"created_at"`
 }
+
+// NumberSearchRequest filters the available-number search. CountryCode
+// defaults to US; only SMS-capable numbers are returned.
+type NumberSearchRequest struct {
+	CountryCode        string
+	AreaCode           string
+	Locality           string
+	AdministrativeArea string
+	Limit              int
+}
+
+func (r NumberSearchRequest) query() url.Values {
+	q := url.Values{}
+	country := strings.TrimSpace(r.CountryCode)
+	if country == "" {
+		country = "US"
+	}
+	q.Set("filter[country_code]", country)
+	q.Set("filter[features][]", "sms")
+	if v := strings.TrimSpace(r.AreaCode); v != "" {
+		q.Set("filter[national_destination_code]", v)
+	}
+	if v := strings.TrimSpace(r.Locality); v != "" {
+		q.Set("filter[locality]", v)
+	}
+	if v := strings.TrimSpace(r.AdministrativeArea); v != "" {
+		q.Set("filter[administrative_area]", v)
+	}
+	if r.Limit > 0 {
+		q.Set("filter[limit]", strconv.Itoa(r.Limit))
+	}
+	return q
+}
+
+// AvailableNumber is a number offered by the available-number search.
+type AvailableNumber struct {
+	PhoneNumber       string `json:"phone_number"`
+	RegionInformation []struct {
+		RegionType string `json:"region_type"`
+		RegionName string `json:"region_name"`
+	} `json:"region_information,omitempty"`
+	CostInformation struct {
+		MonthlyCost string `json:"monthly_cost"`
+		UpfrontCost string `json:"upfront_cost"`
+		Currency    string `json:"currency"`
+	} `json:"cost_information"`
+}
+
+// OrderedNumber is one number in a number order.
+type OrderedNumber struct {
+	ID          string `json:"id,omitempty"`
+	PhoneNumber string `json:"phone_number"`
+	Status      string `json:"status,omitempty"`
+}
+
+// NumberOrderRequest purchases numbers and attaches them to a messaging
+// profile.
+type NumberOrderRequest struct {
+	PhoneNumbers       []OrderedNumber `json:"phone_numbers"`
+	MessagingProfileID string          `json:"messaging_profile_id,omitempty"`
+	CustomerReference  string          `json:"customer_reference,omitempty"`
+}
+
+func (r NumberOrderRequest) validate() error {
+	if len(r.PhoneNumbers) == 0 {
+		return errors.New("telnyxclient: phone_numbers required")
+	}
+	for _, n := range r.PhoneNumbers {
+		if strings.TrimSpace(n.PhoneNumber) == "" {
+			return errors.New("telnyxclient: phone_number required")
+		}
+	}
+	return nil
+}
+
+// NumberOrder represents a Telnyx number order.
+type NumberOrder struct {
+	ID                 string          `json:"id"`
+	Status             string          `json:"status"`
+	PhoneNumbers       []OrderedNumber `json:"phone_numbers"`
+	MessagingProfileID string          `json:"messaging_profile_id,omitempty"`
+	CustomerReference  string          `json:"customer_reference,omitempty"`
+	CreatedAt          time.Time       `json:"created_at"`
+}
@@ -0,0 +1,434 @@
+package templates
+
+import "github.com/wolfman30/medspa-ai-platform/internal/conversation/ackmsgs"
+
+// Template IDs. IDs are stable across copy changes, which bump the version,
+// so stored messages keep pointing at the template that produced them.
+const (
+	AckFirst            = "ack.first"
+	AckFollowUp         = "ack.follow_up"
+	AckMissedCall       = "ack.missed_call"
+	AckMissedCallClinic = "ack.missed_call_clinic"
+	AckFirstContact     = "ack.first_contact"
+	AckWrongNumber      = "ack.wrong_number"
+	AckWrongNumberLater = "ack.wrong_number_later"
+
+	ComplianceStopAck             = "compliance.stop_ack"
+	ComplianceHelpAck             = "compliance.help_ack"
+	ComplianceStartAck            = "compliance.start_ack"
+	ComplianceSensitiveData       = "compliance.sensitive_data"
+	CompliancePCIGuardrail        = "compliance.pci_guardrail"
+	ComplianceMarketingConsentAsk = "compliance.marketing_consent_ask"
+	ComplianceMarketingConsentYes = "compliance.marketing_consent_yes"
+	ComplianceMarketingConsentNo  = "compliance.marketing_consent_no"
+	ComplianceFarewell            = "compliance.farewell"
+	ComplianceFarewellNoPhone     = "compliance.farewell_no_phone"
+
+	PolicyDisclaimerShort      = "policy.disclaimer_short"
+	PolicyDisclaimerMedium     = "policy.disclaimer_medium"
+	PolicyDisclaimerFull       = "policy.disclaimer_full"
+	PolicyDepositDisclosure    = "policy.deposit_disclosure"
+	PolicyNoShowTerms          = "policy.no_show_terms"
+	PolicyCancellationWindow   = "policy.cancellation_window"
+	PolicyBookingPoliciesTitle = "policy.booking_policies_title"
+
+	SlotsList        = "slots.list"
+	SlotsListClosest = "slots.list_closest"
+	SlotsListCustom  = "slots.list_custom"
+	SlotsNone        = "slots.none"
+
+	SlotsNoneMatching     = "slots.none_matching"
+	SlotsNoneInDates      = "slots.none_in_dates"
+	SlotsClosestHeader    = "slots.closest_header"
+	SlotsNoMoreTimes      = "slots.no_more_times"
+	SlotsManualOffer      = "slots.manual_offer"
+	SlotsTaken            = "slots.taken"
+	SlotsTakenRemaining   = "slots.taken_remaining"
+	SlotsUnavailable      = "slots.unavailable"
+	SlotsUnavailableNoted = "slots.unavailable_noted"
+	SlotsCheckFailed      = "slots.check_failed"
+	SlotsUnknownService   = "slots.unknown_service"
+	SlotsServiceClarify   = "slots.service_clarify"
+	SlotsPaidTaken        = "slots.paid_taken"
+	SlotsPaidTakenHeader  = "slots.paid_taken_header"
+
+	BookingConfirmed              = "confirmation.booked"
+	BookingConfirmedPaid          = "confirmation.booked_paid"
+	BookingNoDeposit              = "confirmation.no_deposit"
+	BookingNoDepositUntimed       = "confirmation.no_deposit_untimed"
+	BookingManualHandoff          = "confirmation.manual_handoff"
+	BookingAssistedIntro          = "confirmation.assisted_intro"
+	BookingOutcomeSuccess         = "confirmation.outcome_success"
+	BookingOutcomePaymentFailed   = "confirmation.outcome_payment_failed"
+	BookingOutcomeSlotUnavailable = "confirmation.outcome_slot_unavailable"
+	BookingOutcomeTimeout         = "confirmation.outcome_timeout"
+	BookingOutcomeError           = "confirmation.outcome_error"
+	BookingFallbackCallClinic     = "confirmation.fallback_call_clinic"
+	BookingFallbackUnknownService = "confirmation.fallback_unknown_service"
+	BookingFallbackBadTime        = "confirmation.fallback_bad_time"
+	BookingFallbackRetry          = "confirmation.fallback_retry"
+	BookingFallbackClinicConfirms = "confirmation.fallback_clinic_confirms"
+
+	PaymentDepositLink           = "payment.deposit_link"
+	PaymentReceived              = "payment.received"
+	PaymentReceivedAtClinic      = "payment.received_at_clinic"
+	PaymentReceivedCallback      = "payment.received_callback"
+	PaymentReceivedCallbackNamed = "payment.received_callback_named"
+	PaymentFailed                = "payment.failed"
+	PaymentRetryIntro            = "payment.retry_intro"
+	PaymentClaimConfirmed        = "payment.claim_confirmed"
+	PaymentClaimPending          = "payment.claim_pending"
+	PaymentClaimUnconfirmed      = "payment.claim_unconfirmed"
+	PaymentMethodsAnswer         = "payment.methods_answer"
+	PaymentMethodsFinancing      = "payment.methods_financing"
+	PaymentMethodsInsuranceNo    = "payment.methods_insurance_not_accepted"
+	PaymentMethodsInsuranceYes   = "payment.methods_insurance_accepted"
+	PaymentMethodsInsuranceAsk   = "payment.methods_insurance_unconfirmed"
+	PaymentMethodsUnconfigured   = "payment.methods_unconfigured"
+
+	NudgeReengagementFirst        = "nudge.reengagement_first"
+	NudgeReengagementFinal        = "nudge.reengagement_final"
+	NudgeReengagementFirstGeneric = "nudge.reengagement_first_generic"
+	NudgeReengagementFinalGeneric = "nudge.reengagement_final_generic"
+
+	VoiceCallbackAck    = "voice.callback_ack"
+	VoiceCallbackFailed = "voice.callback_failed"
+	VoiceDepositHandoff = "voice.deposit_handoff"
+	VoicePostCallFollow = "voice.post_call_deposit"
+	VoiceDepositLink    = "voice.deposit_link"
+
+	ReplyNotOffered                     = "reply.not_offered"
+	ReplyNotOfferedReferral             = "reply.not_offered_referral"
+	ReplyNotOfferedAlternatives         = "reply.not_offered_alternatives"
+	ReplyNotOfferedReferralAlternatives = "reply.not_offered_referral_alternatives"
+	ReplyConsultRequired                = "reply.consult_required"
+	ReplyProviderPreference             = "reply.provider_preference"
+	ReplyProcessingFailed               = "reply.processing_failed"
+)
+
+// sensitiveDataText is shared by the conversation reply and the instant
+// guardrail text.
+const sensitiveDataText = "For your security, please don't text card numbers, security codes, or your Social Security number. We can only take payments through our secure checkout link. If you'd like a deposit link, reply \"deposit\" and we'll send it."
+
+// cancellationReminder closes every deposit receipt.
+const cancellationReminder = "\n\nReminder: There is a 24-hour cancellation policy. Cancellations made less than 24 hours before your appointment are non-refundable."
+
+// Default is the registry every outbound send site renders through.
+var Default = NewRegistry()
+
+func init() {
+	Default.MustRegister(
+		// Instant acks
+		Template{ID: AckFirst, Version: 1, Category: CategoryAck, Params: []string{"clinic_name"},
+			Description: "Instant ack for a patient's first message while the reply is prepared; one variant per message.",
+			Variants:    ackmsgs.First},
+		Template{ID: AckFollowUp, Version: 1, Category: CategoryAck,
+			Description: "Instant ack for later messages; one variant per message.",
+			Variants:    ackmsgs.FollowUp},
+		Template{ID: AckMissedCall, Version: 1, Category: CategoryAck,
+			Description: "Text-back after a missed call when the clinic name is unknown.",
+			Text:        "Hi there! Sorry we missed your call. I'm the virtual receptionist and can help by text—though I can't provide medical advice. How can I help today - booking an appointment or a quick question? Reply STOP to opt out."},
+		Template{ID: AckMissedCallClinic, Version: 1, Category: CategoryAck, Params: []string{"clinic_name"},
+			Description: "Text-back after a missed call.",
+			Text:        "Hi there! Sorry we missed your call. I'm the virtual receptionist for {{clinic_name}} and can help by text—though I can't provide medical advice. How can I help today - booking an appointment or a quick question? Reply STOP to opt out."},
+		Template{ID: AckFirstContact, Version: 1, Category: CategoryAck, Params: []string{"clinic_name", "service"}, ClinicAuthored: true,
+			Description: "Greeting for a new patient's first message, written by the clinic; not sent unless the clinic sets one."},
+		Template{ID: AckWrongNumber, Version: 1, Category: CategoryAck, Params: []string{"clinic_name"},
+			Description: "Correction for a patient whose text was meant for another business.",
+			Text:        "Hi! You've reached {{clinic_name}}. It looks like you may have the wrong number. If you're interested in med spa services, just let me know what you're looking for!"},
+		Template{ID: AckWrongNumberLater, Version: 1, Category: CategoryAck, Params: []string{"clinic_name"},
+			Description: "Reply to later texts from a patient flagged as a wrong number.",
+			Text:        "This is {{clinic_name}}. If you'd like to book a treatment or have a question, just reply here!"},
+
+		// STOP/HELP and other compliance replies
+		Template{ID: ComplianceStopAck, Version: 1, Category: CategoryCompliance,
+			Description: "Reply to STOP and other opt-out keywords.",
+			Text:        "You have been opted out. Reply HELP for info."},
+		Template{ID: ComplianceHelpAck, Version: 1, Category: CategoryCompliance,
+			Description: "Reply to HELP.",
+			Text:        "Reply STOP to opt out or contact support@medspa.ai."},
+		Template{ID: ComplianceStartAck, Version: 1, Category: CategoryCompliance,
+			Description: "Reply to START and other opt-in keywords.",
+			Text:        "You're opted back in. Reply STOP to opt out."},
+		Template{ID: ComplianceSensitiveData, Version: 1, Category: CategoryCompliance,
+			Description: "Reply to a message that carried a card number, security code or SSN.",
+			Text:        sensitiveDataText},
+		Template{ID: CompliancePCIGuardrail, Version: 1, Category: CategoryCompliance,
+			Description: "Instant reply to an inbound SMS carrying card numbers, security codes or an SSN.",
+			Text:        sensitiveDataText + " Reply STOP to opt out."},
+		Template{ID: ComplianceMarketingConsentAsk, Version: 1, Category: CategoryCompliance, Params: []string{"clinic_name"},
+			Description: "One-time marketing opt-in ask after a confirmed booking.",
+			Text:        "One more thing: would you like to get occasional texts about specials and events from {{clinic_name}}? Reply YES or NO. You can reply STOP at any time."},
+		Template{ID: ComplianceMarketingConsentYes, Version: 1, Category: CategoryCompliance,
+			Description: "Reply to YES to the marketing opt-in ask.",
+			Text:        "You're on the list! We'll text you about specials and events now and then. Reply STOP at any time to opt out."},
+		Template{ID: ComplianceMarketingConsentNo, Version: 1, Category: CategoryCompliance,
+			Description: "Reply to NO to the marketing opt-in ask.",
+			Text:        "No problem, we won't send you promotional texts. We'll still message you about your appointments."},
+		Template{ID: ComplianceFarewell, Version: 1, Category: CategoryCompliance, Params: []string{"clinic_name", "clinic_phone"},
+			Description: "Reply to patients texting a deactivated or unconfigured clinic.",
+			Text:        "This number is no longer monitored - please call {{clinic_name}} at {{clinic_phone}}."},
+		Template{ID: ComplianceFarewellNoPhone, Version: 1, Category: CategoryCompliance, Params: []string{"clinic_name"},
+			Description: "Farewell when there is no clinic phone to point the patient at.",
+			Text:        "This number is no longer monitored - please contact {{clinic_name}} directly."},
+
+		// Policy disclosures
+		Template{ID: PolicyDisclaimerShort, Version: 1, Category: CategoryPolicy,
+			Description: "Medical disclaimer appended to replies (short level).",
+			Text:        "Auto-assistant. Not medical advice."},
+		Template{ID: PolicyDisclaimerMedium, Version: 1, Category: CategoryPolicy,
+			Description: "Medical disclaimer appended to replies (medium level).",
+			Text:        "This is an automated assistant. For medical advice, please consult your provider."},
+		Template{ID: PolicyDisclaimerFull, Version: 1, Category: CategoryPolicy,
+			Description: "Medical disclaimer appended to replies (full level).",
+			Text:        "This is an automated scheduling assistant. The information provided is general in nature and not a substitute for professional medical advice. Please consult with a licensed healthcare provider for medical guidance."},
+		Template{ID: PolicyDepositDisclosure, Version: 1, Category: CategoryPolicy, Params: []string{"deposit_amount", "terms", "policies"},
+			Description: "Pre-payment disclosure opening the deposit link. {{terms}} is the no-show line for clinics without their own disclosure; {{policies}} is the booking policies list.",
+			Text:        "💳 {{deposit_amount}} deposit — applies toward your treatment cost and secures your spot.{{terms}}{{policies}}"},
+		Template{ID: PolicyNoShowTerms, Version: 1, Category: CategoryPolicy,
+			Description: "Refund line in the deposit disclosure for clinics without their own.",
+			Text:        "⚠️ Deposits are forfeited for no-shows or late cancellations."},
+		Template{ID: PolicyCancellationWindow, Version: 1, Category: CategoryPolicy, Params: []string{"cancellation_hours"},
+			Description: "Cancellation bullet in a clinic's deposit disclosure.",
+			Text:        "{{cancellation_hours}}-hour cancellation policy — please cancel or reschedule at least {{cancellation_hours}} hours before your appointment."},
+		Template{ID: PolicyBookingPoliciesTitle, Version: 1, Category: CategoryPolicy,
+			Description: "Heading of the booking policies list; each policy follows as a ✅ bullet.",
+			Text:        "📋 Booking policies:"},
+
+		// Slot lists
+		Template{ID: SlotsList, Version: 1, Category: CategorySlots, Params: []string{"service", "slots"},
+			Description: "Open times matching the patient's preferences; {{slots}} is one numbered time per line.",
+			Text:        "Here's what's open for {{service}} 👇\n\n{{slots}}\nJust reply with the number that works best!"},
+		Template{ID: SlotsListClosest, Version: 1, Category: CategorySlots, Params: []string{"service", "slots"},
+			Description: "Nearest open times when none match the patient's preferences.",
+			Text:        "Closest I could find for {{service}} 👇\n\n{{slots}}\nJust reply with the number that works best!"},
+		Template{ID: SlotsListCustom, Version: 1, Category: CategorySlots, Params: []string{"header", "slots"},
+			Description: "Open times under a header explaining how they differ from the request.",
+			Text:        "{{header}}\n\n{{slots}}\nJust reply with the number that works best!"},
+		Template{ID: SlotsNone, Version: 1, Category: CategorySlots, Params: []string{"service"},
+			Description: "No open times in the coming week.",
+			Text:        "Hmm, I'm not finding any open times for {{service}} in the next week 😕\n\nWant me to check different dates or times?"},
+		Template{ID: SlotsNoneMatching, Version: 1, Category: CategorySlots, Params: []string{"service"},
+			Description: "No open times match the patient's preferences in the full search window.",
+			Text:        "I searched 3 months of availability for {{service}} but couldn't find times matching your preferences. Would you like to try different days or times?"},
+		Template{ID: SlotsNoneInDates, Version: 1, Category: CategorySlots, Params: []string{"service"},
+			Description: "No open times in the dates the patient asked about.",
+			Text:        "I couldn't find any {{service}} times matching your preferences in the dates you asked about. Would you like to try different dates or times?"},
+		Template{ID: SlotsClosestHeader, Version: 1, Category: CategorySlots, Params: []string{"preferences"},
+			Description: "Header for slots.list_custom when no times match; {{preferences}} describes what the patient asked for.",
+			Text:        "I wasn't able to find any openings that match your preference for {{preferences}}, but here are the closest available times:"},
+		Template{ID: SlotsNoMoreTimes, Version: 1, Category: CategorySlots, Params: []string{"service"},
+			Description: "The patient asked for more times and none are left on those days.",
+			Text:        "Those are the latest available times on those days for {{service}}. Would you like to try different days, or would one of the times I showed work for you?"},
+		Template{ID: SlotsManualOffer, Version: 1, Category: CategorySlots, Params: []string{"clinic_name", "service", "index", "time"},
+			Description: "A slot clinic staff made room for; {{service}} includes the provider when one was assigned.",
+			Text:        "Good news! The team at {{clinic_name}} made room for you for {{service}} 🎉\n\n  {{index}} → {{time}}\n\nJust reply {{index}} to confirm this time!"},
+		Template{ID: SlotsTaken, Version: 1, Category: CategorySlots, Params: []string{"time"},
+			Description: "The chosen slot was booked by someone else and no others are left.",
+			Text:        "I'm sorry, but the {{time}} slot was just booked. Would you like me to check for other available times?"},
+		Template{ID: SlotsTakenRemaining, Version: 1, Category: CategorySlots, Params: []string{"time", "slots"},
+			Description: "The chosen slot was booked by someone else; {{slots}} lists the rest, one per line.",
+			Text:        "I'm sorry, but the {{time}} slot was just booked. Here are the remaining times:\n\n{{slots}}\nReply with the number of your preferred time."},
+		Template{ID: SlotsUnavailable, Version: 1, Category: CategorySlots, Params: []string{"service"},
+			Description: "Live availability can't be checked and the patient gave no day or time preference.",
+			Text:        "Thanks! I've noted that you're interested in {{service}}. Our team will text you available times shortly."},
+		Template{ID: SlotsUnavailableNoted, Version: 1, Category: CategorySlots, Params: []string{"preference", "service"},
+			Description: "Live availability can't be checked; {{preference}} is the days and times the patient asked for.",
+			Text:        "Thanks! I've noted your preference for {{preference}} for {{service}}. Our team will text you available times shortly."},
+		Template{ID: SlotsCheckFailed, Version: 1, Category: CategorySlots, Params: []string{"service"},
+			Description: "Fetching availability failed; the patient may ask again.",
+			Text:        "I had trouble checking availability for {{service}} right now. Could you try again in a moment?"},
+		Template{ID: SlotsUnknownService, Version: 1, Category: CategorySlots, Params: []string{"service"},
+			Description: "The service isn't on the clinic's booking menu.",
+			Text:        "I'm sorry, but {{service}} doesn't appear to be a service currently offered at this clinic. Would you like to see what services are available, or is there something else I can help with?"},
+		Template{ID: SlotsServiceClarify, Version: 1, Category: CategorySlots, Params: []string{"services"},
+			Description: "The service name is close to several menu items; {{services}} is \"A or B\" or \"A, B, or C\".",
+			Text:        "Just to make sure I book the right treatment, did you mean {{services}}?"},
+		Template{ID: SlotsPaidTaken, Version: 1, Category: CategorySlots, Params: []string{"service", "when"},
+			Description: "The paid-for slot was booked by someone else during checkout and nothing nearby is open.",
+			Text:        "So sorry - your {{service}} opening on {{when}} was booked by someone else while you were checking out. Your deposit is safe and will go toward your new time. Our team will reach out shortly to find a time that works for you."},
+		Template{ID: SlotsPaidTakenHeader, Version: 1, Category: CategorySlots, Params: []string{"service", "when"},
+			Description: "Header for slots.list_custom when the paid-for slot was taken during checkout and nearby times are offered.",
+			Text:        "So sorry - your {{service}} opening on {{when}} was booked by someone else while you were checking out. Your deposit is safe and will go toward whichever of these you pick:"},
+
+		// Booking confirmations
+		Template{ID: BookingConfirmed, Version: 1, Category: CategoryConfirmation, Params: []string{"service", "date", "time", "clinic_name"},
+			Description: "Confirmation after an appointment is booked through the clinic's booking system.",
+			Text:        "Your appointment has been booked! 🎉\n\n📋 {{service}}\n📅 {{date}} at {{time}}\n📍 {{clinic_name}}\n\nYou'll receive a confirmation from the clinic shortly. See you then!"},
+		Template{ID: BookingConfirmedPaid, Version: 1, Category: CategoryConfirmation, Params: []string{"service", "when", "clinic_name"},
+			Description: "Confirmation after a paid deposit books the appointment.",
+			Text:        "You're all booked! 🎉\n\n💉 {{service}}\n📅 {{when}}\n📍 {{clinic_name}}\n\n⚠️ 24-hour cancellation policy — cancellations made less than 24 hours before your appointment are non-refundable.\n\nSee you there! ✨"},
+		Template{ID: BookingNoDeposit, Version: 1, Category: CategoryConfirmation, Params: []string{"service", "time", "clinic_name", "policies"},
+			Description: "Booking request sent to the clinic for a service without a deposit.",
+			Text:        "Great news — no deposit is needed for {{service}}! Your request for {{time}} has been sent to {{clinic_name}} and they'll confirm your appointment shortly.{{policies}}"},
+		Template{ID: BookingNoDepositUntimed, Version: 1, Category: CategoryConfirmation, Params: []string{"service", "clinic_name", "policies"},
+			Description: "As confirmation.no_deposit, without a chosen time.",
+			Text:        "Great news — no deposit is needed for {{service}}! Your booking request has been sent to {{clinic_name}} and they'll confirm your appointment shortly.{{policies}}"},
+		Template{ID: BookingManualHandoff, Version: 1, Category: CategoryConfirmation, Params: []string{"clinic_name"},
+			Description: "Sent when a booking is handed to clinic staff to confirm.",
+			Text:        "Thank you! I've shared your information with {{clinic_name}} and they'll reach out to confirm your appointment shortly."},
+		Template{ID: BookingAssistedIntro, Version: 1, Category: CategoryConfirmation, Params: []string{"first_name", "clinic_name", "service", "time"},
+			Description: "Opens a booking clinic staff started on the patient's behalf.",
+			Text:        "Hi {{first_name}}! The team at {{clinic_name}} started your booking for {{service}} on {{time}}. We'll text you what's needed to finish it next."},
+		Template{ID: BookingOutcomeSuccess, Version: 1, Category: CategoryConfirmation, Params: []string{"confirmation", "scheduled"},
+			Description: "Browser booking succeeded. {{confirmation}} is \" Confirmation #N.\" and {{scheduled}} is \" Scheduled for T.\" when known.",
+			Text:        "Your appointment is confirmed!{{confirmation}}{{scheduled}} We look forward to seeing you!"},
+		Template{ID: BookingOutcomePaymentFailed, Version: 1, Category: CategoryConfirmation,
+			Description: "Browser booking failed at payment.",
+			Text:        "Your payment didn't go through. Reply YES to try again, or call the clinic directly for help."},
+		Template{ID: BookingOutcomeSlotUnavailable, Version: 1, Category: CategoryConfirmation,
+			Description: "Browser booking found the slot taken.",
+			Text:        "That time slot is no longer available. Want me to check other times for you?"},
+		Template{ID: BookingOutcomeTimeout, Version: 1, Category: CategoryConfirmation,
+			Description: "Browser booking session expired.",
+			Text:        "Your booking session expired. Would you like to try again?"},
+		Template{ID: BookingOutcomeError, Version: 1, Category: CategoryConfirmation,
+			Description: "Browser booking failed.",
+			Text:        "Something went wrong with your booking. Please try again or call the clinic directly."},
+		Template{ID: BookingFallbackCallClinic, Version: 1, Category: CategoryConfirmation,
+			Description: "Booking could not be attempted; the patient is sent to the clinic.",
+			Text:        "We're having trouble processing your booking right now. Please call the clinic directly to complete your appointment."},
+		Template{ID: BookingFallbackUnknownService, Version: 1, Category: CategoryConfirmation,
+			Description: "The service isn't in the clinic's booking system.",
+			Text:        "We couldn't find that service in our booking system. Please call the clinic directly to book your appointment."},
+		Template{ID: BookingFallbackBadTime, Version: 1, Category: CategoryConfirmation,
+			Description: "The chosen time couldn't be read.",
+			Text:        "We had trouble with the appointment time. Please try again or call the clinic directly."},
+		Template{ID: BookingFallbackRetry, Version: 1, Category: CategoryConfirmation,
+			Description: "The booking system failed; the patient may retry.",
+			Text:        "We're having trouble booking your appointment right now. Please try again in a moment or call the clinic directly."},
+		Template{ID: BookingFallbackClinicConfirms, Version: 1, Category: CategoryConfirmation,
+			Description: "The booking was handed to clinic staff, who will confirm it.",
+			Text:        "Thanks! We're double-checking your booking with the clinic, and our team will confirm your appointment shortly."},
+
+		// Payments
+		Template{ID: PaymentDepositLink, Version: 1, Category: CategoryPayment, Params: []string{"disclosure", "checkout_url"},
+			Description: "Deposit link; {{disclosure}} is the clinic's policy.deposit_disclosure.",
+			Text:        "{{disclosure}}\n\n→ Complete your deposit here:\n{{checkout_url}}"},
+		Template{ID: PaymentReceived, Version: 1, Category: CategoryPayment, Params: []string{"service", "date"},
+			Description: "Deposit receipt for a scheduled appointment when the clinic name is unknown.",
+			Text:        "Payment received! Your {{service}} appointment on {{date}} is confirmed." + cancellationReminder},
+		Template{ID: PaymentReceivedAtClinic, Version: 1, Category: CategoryPayment, Params: []string{"service", "clinic_name", "date"},
+			Description: "Deposit receipt for a scheduled appointment.",
+			Text:        "Payment received! Your {{service}} appointment at {{clinic_name}} on {{date}} is confirmed." + cancellationReminder},
+		Template{ID: PaymentReceivedCallback, Version: 1, Category: CategoryPayment, Params: []string{"amount", "callback_time"},
+			Description: "Deposit receipt when staff will call to schedule, clinic name unknown.",
+			Text:        "Payment of {{amount}} received - thank you! Our team will call you {{callback_time}} to confirm your appointment." + cancellationReminder},
+		Template{ID: PaymentReceivedCallbackNamed, Version: 1, Category: CategoryPayment, Params: []string{"amount", "clinic_name", "callback_time"},
+			Description: "Deposit receipt when staff will call to schedule.",
+			Text:        "Payment of {{amount}} received - thank you! A {{clinic_name}} team member will call you {{callback_time}} to confirm your appointment." + cancellationReminder},
+		Template{ID: PaymentFailed, Version: 1, Category: CategoryPayment,
+			Description: "Deposit payment failed and no retry link is sent.",
+			Text:        "Payment failed - we didn't receive your deposit. If you'd still like to book, please reply and we can send a new secure payment link. Our team can also help by phone."},
+		Template{ID: PaymentRetryIntro, Version: 1, Category: CategoryPayment,
+			Description: "Precedes the fresh deposit link sent after a declined card.",
+			Text:        "Looks like that card didn't go through - no worries, it happens! Here's a fresh secure link to try again:"},
+		Template{ID: PaymentClaimConfirmed, Version: 1, Category: CategoryPayment,
+			Description: "Patient says they paid and the processor confirms it.",
+			Text:        "Thank you! Your deposit just came through. You'll get a confirmation text in a moment."},
+		Template{ID: PaymentClaimPending, Version: 1, Category: CategoryPayment,
+			Description: "Patient says they paid and the processor hasn't confirmed it yet.",
+			Text:        "Thanks! I'm confirming your deposit with our payment processor now and will text you as soon as it shows up. No need to pay again."},
+		Template{ID: PaymentClaimUnconfirmed, Version: 1, Category: CategoryPayment,
+			Description: "Claimed payment still unconfirmed after the last check.",
+			Text:        "We still haven't received confirmation of your deposit from our payment processor. If you completed checkout, there's no need to pay again. Our team will double-check and follow up."},
+		Template{ID: PaymentMethodsAnswer, Version: 1, Category: CategoryPayment, Params: []string{"accepted", "insurance", "note"},
+			Description: "Answer to a payment-method question from the clinic's payment_methods config. {{accepted}} lists the methods and payment.methods_financing; {{insurance}} is the clinic's insurance stance and {{note}} its own copy, each a leading-space sentence or empty.",
+			Text:        "Great question! We accept {{accepted}}.{{insurance}}{{note}}"},
+		Template{ID: PaymentMethodsFinancing, Version: 1, Category: CategoryPayment, Params: []string{"providers"},
+			Description: "Financing entry in the payment.methods_answer list.",
+			Text:        "financing through {{providers}}"},
+		Template{ID: PaymentMethodsInsuranceNo, Version: 1, Category: CategoryPayment,
+			Description: "Insurance stance in payment.methods_answer for clinics that don't accept insurance.",
+			Text:        "We don't accept insurance, since cosmetic treatments usually aren't covered."},
+		Template{ID: PaymentMethodsInsuranceYes, Version: 1, Category: CategoryPayment,
+			Description: "Insurance stance in payment.methods_answer for clinics that accept insurance.",
+			Text:        "We also work with insurance for eligible treatments, and our team can check your coverage."},
+		Template{ID: PaymentMethodsInsuranceAsk, Version: 1, Category: CategoryPayment,
+			Description: "Insurance line in payment.methods_answer when the patient asks about insurance and the clinic hasn't stated a stance.",
+			Text:        "I'll have our team confirm whether insurance can be used for your treatment."},
+		Template{ID: PaymentMethodsUnconfigured, Version: 1, Category: CategoryPayment, Params: []string{"clinic_name"},
+			Description: "Answer to a payment-method question for a clinic without payment_methods config.",
+			Text:        "Great question! I don't want to give you the wrong answer, so I'll have the {{clinic_name}} team confirm which payment and financing options they accept."},
+
+		// Nudges
+		Template{ID: NudgeReengagementFirst, Version: 1, Category: CategoryNudge, Params: []string{"clinic_name", "service"},
+			Description: "First nudge to a lead who left a question unanswered.",
+			Text:        "Still interested in that {{service}} appointment? I can grab you a time whenever you're ready."},
+		Template{ID: NudgeReengagementFinal, Version: 1, Category: CategoryNudge, Params: []string{"clinic_name", "service"},
+			Description: "Final nudge to a lead who left a question unanswered.",
+			Text:        "Just checking in one last time about {{service}} - reply whenever you'd like and I'll find you a time."},
+		Template{ID: NudgeReengagementFirstGeneric, Version: 1, Category: CategoryNudge, Params: []string{"clinic_name", "service"},
+			Description: "First nudge when the lead hasn't named a service.",
+			Text:        "Still interested in booking with {{clinic_name}}? I can grab you a time whenever you're ready."},
+		Template{ID: NudgeReengagementFinalGeneric, Version: 1, Category: CategoryNudge, Params: []string{"clinic_name", "service"},
+			Description: "Final nudge when the lead hasn't named a service.",
+			Text:        "Just checking in one last time - reply whenever you'd like and I'll find you a time."},
+
+		// Voice follow-ups
+		Template{ID: VoiceCallbackAck, Version: 1, Category: CategoryVoice,
+			Description: "Sent before the assistant calls a patient who asked for a call.",
+			Text:        "Great, I'll call you right back! You should get a call in just a moment."},
+		Template{ID: VoiceCallbackFailed, Version: 1, Category: CategoryVoice,
+			Description: "The requested callback couldn't be placed.",
+			Text:        "I'm sorry, I wasn't able to call you right now. Let's continue by text — how can I help?"},
+		Template{ID: VoiceDepositHandoff, Version: 1, Category: CategoryVoice, Params: []string{"clinic_name"},
+			Description: "Deposit link texted during a call.",
+			Text:        "Hi! As discussed on the phone, here's your secure deposit link for your upcoming appointment at {{clinic_name}}. We'll confirm your exact time once the deposit is received."},
+		Template{ID: VoicePostCallFollow, Version: 1, Category: CategoryVoice, Params: []string{"clinic_name", "deposit_amount", "deposit_url"},
+			Description: "Follow-up after a voice call with the deposit page.",
+			Text:        "Hi! This is Lauren from {{clinic_name}} 😊 Thanks for calling! To confirm your appointment, please complete the {{deposit_amount}} deposit here: {{deposit_url}} If you have any questions, just reply to this text!"},
+		Template{ID: VoiceDepositLink, Version: 1, Category: CategoryVoice, Params: []string{"clinic_name", "deposit_amount", "deposit_url"},
+			Description: "Deposit checkout link sent by the voice assistant.",
+			Text:        "Hi! This is Lauren from {{clinic_name}} 😊\n\nHere's your {{deposit_amount}} deposit link to secure your appointment:\n{{deposit_url}}\n\nOnce you complete the payment, I'll confirm your booking. If you have any questions, just reply to this text!"},
+
+		// Conversation replies sent without the LLM
+		Template{ID: ReplyNotOffered, Version: 1, Category: CategoryReply, Params: []string{"service"},
+			Description: "Declines a service the clinic doesn't offer when it lists no alternatives or referral.",
+			Text:        "We don't offer {{service}}. Is there anything else I can help you with?"},
+		Template{ID: ReplyNotOfferedReferral, Version: 1, Category: CategoryReply, Params: []string{"service", "referral"},
+			Description: "Declines a service the clinic doesn't offer with the clinic's referral sentence.",
+			Text:        "We don't offer {{service}}. {{referral}} Is there anything else I can help you with?"},
+		Template{ID: ReplyNotOfferedAlternatives, Version: 1, Category: CategoryReply, Params: []string{"service", "alternatives"},
+			Description: "Declines a service the clinic doesn't offer and pivots to related ones it does.",
+			Text:        "We don't offer {{service}}, but we do {{alternatives}} — want to hear more?"},
+		Template{ID: ReplyNotOfferedReferralAlternatives, Version: 1, Category: CategoryReply, Params: []string{"service", "referral", "alternatives"},
+			Description: "Declines a service the clinic doesn't offer with its referral and related services.",
+			Text:        "We don't offer {{service}}. {{referral}} We do offer {{alternatives}} — want to hear more?"},
+		Template{ID: ReplyConsultRequired, Version: 1, Category: CategoryReply, Params: []string{"service", "consult_service"},
+			Description: "A consult-first service; offers to book its consultation instead.",
+			Text:        "{{service}} starts with a consultation so our provider can make sure it's the right fit, so we'd book you a {{consult_service}} first. Would you like me to find a time for that?"},
+		Template{ID: ReplyProviderPreference, Version: 1, Category: CategoryReply, Params: []string{"service", "providers"},
+			Description: "Asks for a provider preference once the service is known; {{providers}} is \" We have A, B.\" or empty.",
+			Text:        "Great choice — {{service}}!{{providers}} Do you have a provider preference, or would you like the first available appointment?"},
+		Template{ID: ReplyProcessingFailed, Version: 1, Category: CategoryReply,
+			Description: "Sent when the conversation engine fails on a patient's message.",
+			Text:        "Sorry - I'm having trouble responding right now. Please reply again in a moment."},
+	)
+}
+
+// Render renders a Default template. IDs are the constants above, so an
+// unregistered one is a programming error and panics.
+func Render(id string, params Params, overrides Overrides) Rendered {
+	return RenderVariant(id, "", params, overrides)
+}
+
+// RenderVariant renders a rotating Default template for the message
+// identified by key; see Registry.RenderVariant.
+func RenderVariant(id, key string, params Params, overrides Overrides) Rendered {
+	out, err := Default.RenderVariant(id, key, params, overrides)
+	if err != nil {
+		panic(err)
+	}
+	return out
+}
+
+// Text returns a Default template's copy as written, for callers that still
+// expose it as a constant.
+func Text(id string) string {
+	t, ok := Default.Get(id)
+	if !ok {
+		panic("templates: " + id + ": not registered")
+	}
+	return t.Text
+}
@@ -0,0 +1,211 @@
+package templates
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// sendDirs hold the code that texts patients. Bodies written inline there
+// bypass the registry, so compliance can't tell which copy went out.
+var sendDirs = []string{
+	"../../conversation",
+	"../../messaging",
+	"../../voice",
+	"../../http/handlers",
+	"../../booking",
+}
+
+// sendFields are the struct fields whose copy is texted to a patient or
+// stored as the sent copy, keyed by struct.
+var sendFields = map[string]string{
+	"OutboundReply":         "Body",
+	"SMSTranscriptMessage":  "Body",
+	"SendMessageRequest":    "Body",
+	"Response":              "Message",
+	"TimeSelectionResponse": "SMSMessage",
+	"AvailabilityResult":    "Message",
+}
+
+// renderedPassThrough are functions that wrap copy they didn't write, such
+// as a header the caller already rendered or the voice engine's own words,
+// as a Rendered without a template.
+var renderedPassThrough = map[string]bool{
+	"renderTimeSlotsWithHeader": true,
+	"SendPaymentLinkSMS":        true,
+}
+
+// unsentReceivers are types whose methods build copy no patient is texted.
+var unsentReceivers = map[string]bool{
+	"StubService":       true, // development stand-in for the LLM engine
+	"ComplaintDetector": true, // suggested replies for billing staff
+}
+
+func TestSendSitesRenderRegisteredTemplates(t *testing.T) {
+	fset := token.NewFileSet()
+	for _, dir := range sendDirs {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			t.Fatalf("read %s: %v", dir, err)
+		}
+		for _, e := range entries {
+			name := e.Name()
+			if e.IsDir() || !strings.HasSuffix(name, ".go") || strings.HasSuffix(name, "_test.go") {
+				continue
+			}
+			path := filepath.Join(dir, name)
+			file, err := parser.ParseFile(fset, path, nil, 0)
+			if err != nil {
+				t.Fatalf("parse %s: %v", path, err)
+			}
+			for _, decl := range file.Decls {
+				fn, ok := decl.(*ast.FuncDecl)
+				if ok && unsentReceivers[receiverName(fn)] {
+					continue
+				}
+				if ok && replyHelper(fn) {
+					ast.Inspect(fn.Body, func(n ast.Node) bool {
+						if ret, ok := n.(*ast.ReturnStmt); ok && len(ret.Results) == 1 && inlineSentence(ret.Results[0]) {
+							t.Errorf("%s: %s returns copy written inline; register it in the templates catalog", fset.Position(ret.Pos()), fn.Name.Name)
+						}
+						return true
+					})
+				}
+				ast.Inspect(decl, func(n ast.Node) bool {
+					lit, ok := n.(*ast.CompositeLit)
+					if !ok || systemNote(lit) {
+						return true
+					}
+					if typeName(lit.Type) == "Rendered" && handRendered(lit) && !(fn != nil && renderedPassThrough[fn.Name.Name]) {
+						t.Errorf("%s: Rendered is built by hand, bypassing the registry and clinic overrides; render a registered template", fset.Position(lit.Pos()))
+						return true
+					}
+					field := sendFields[typeName(lit.Type)]
+					if field == "" {
+						return true
+					}
+					for _, elt := range lit.Elts {
+						kv, ok := elt.(*ast.KeyValueExpr)
+						if !ok {
+							continue
+						}
+						if key, ok := kv.Key.(*ast.Ident); ok && key.Name == field && inlineCopy(kv.Value) {
+							t.Errorf("%s: %s.%s is written inline; register it in the templates catalog", fset.Position(kv.Pos()), typeName(lit.Type), field)
+						}
+					}
+					return true
+				})
+			}
+		}
+	}
+}
+
+// replyHelper reports whether fn builds a reply for a send site: its name
+// ends in Reply or Message and it returns a single string.
+func replyHelper(fn *ast.FuncDecl) bool {
+	name := fn.Name.Name
+	if fn.Body == nil || !(strings.HasSuffix(name, "Reply") || strings.HasSuffix(name, "Message")) {
+		return false
+	}
+	results := fn.Type.Results
+	if results == nil || len(results.List) != 1 || len(results.List[0].Names) > 1 {
+		return false
+	}
+	ident, ok := results.List[0].Type.(*ast.Ident)
+	return ok && ident.Name == "string"
+}
+
+func receiverName(fn *ast.FuncDecl) string {
+	if fn.Recv == nil || len(fn.Recv.List) == 0 {
+		return ""
+	}
+	expr := fn.Recv.List[0].Type
+	if star, ok := expr.(*ast.StarExpr); ok {
+		expr = star.X
+	}
+	return typeName(expr)
+}
+
+// handRendered reports whether lit fills in a Rendered body itself instead
+// of leaving it empty.
+func handRendered(lit *ast.CompositeLit) bool {
+	for _, elt := range lit.Elts {
+		kv, ok := elt.(*ast.KeyValueExpr)
+		if !ok {
+			continue
+		}
+		key, ok := kv.Key.(*ast.Ident)
+		if !ok || key.Name != "Body" {
+			continue
+		}
+		if empty, ok := kv.Value.(*ast.BasicLit); ok && empty.Value == `""` {
+			return false
+		}
+		return true
+	}
+	return false
+}
+
+// systemNote reports whether lit is a transcript entry the patient never
+// sees, such as a "system" log line.
+func systemNote(lit *ast.CompositeLit) bool {
+	for _, elt := range lit.Elts {
+		kv, ok := elt.(*ast.KeyValueExpr)
+		if !ok {
+			continue
+		}
+		key, ok := kv.Key.(*ast.Ident)
+		if !ok || key.Name != "Role" {
+			continue
+		}
+		role, ok := kv.Value.(*ast.BasicLit)
+		return ok && role.Value != `"assistant"`
+	}
+	return false
+}
+
+func typeName(expr ast.Expr) string {
+	switch e := expr.(type) {
+	case *ast.Ident:
+		return e.Name
+	case *ast.SelectorExpr:
+		return e.Sel.Name
+	}
+	return ""
+}
+
+// inlineCopy reports whether expr is patient copy spelled out at the send
+// site: a non-empty string literal or a fmt.Sprintf of one.
+func inlineCopy(expr ast.Expr) bool {
+	switch e := expr.(type) {
+	case *ast.BasicLit:
+		return e.Kind == token.STRING && e.Value != `""`
+	case *ast.BinaryExpr:
+		return inlineCopy(e.X) || inlineCopy(e.Y)
+	case *ast.CallExpr:
+		sel, ok := e.Fun.(*ast.SelectorExpr)
+		if !ok || sel.Sel.Name != "Sprintf" || len(e.Args) == 0 {
+			return false
+		}
+		pkg, ok := sel.X.(*ast.Ident)
+		return ok && pkg.Name == "fmt" && inlineCopy(e.Args[0])
+	}
+	return false
+}
+
+// inlineSentence is inlineCopy limited to text with a space, so helpers that
+// return a keyword such as "new" or "flexible" aren't mistaken for copy.
+func inlineSentence(expr ast.Expr) bool {
+	found := false
+	ast.Inspect(expr, func(n ast.Node) bool {
+		if lit, ok := n.(*ast.BasicLit); ok && lit.Kind == token.STRING && strings.Contains(lit.Value, " ") {
+			found = true
+		}
+		return !found
+	})
+	return found && inlineCopy(expr)
+}
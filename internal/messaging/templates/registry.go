@@ -0,0 +1,321 @@
+package templates
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash/fnv"
+	"math/rand"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Outbound reply metadata keys recording which template produced the body.
+const (
+	MetadataID      = "template_id"
+	MetadataVersion = "template_version"
+)
+
+// Categories group templates for review.
+const (
+	CategoryAck          = "ack"
+	CategoryCompliance   = "compliance"
+	CategoryPolicy       = "policy"
+	CategorySlots        = "slots"
+	CategoryConfirmation = "confirmation"
+	CategoryPayment      = "payment"
+	CategoryNudge        = "nudge"
+	CategoryVoice        = "voice"
+	CategoryReply        = "reply"
+)
+
+// placeholderRE finds {{name}} placeholders in template text.
+var placeholderRE = regexp.MustCompile(`\{\{\s*([a-z0-9_]+)\s*\}\}`)
+
+// Template is a piece of outbound copy the system sends without the LLM.
+// Text uses {{name}} placeholders, each listed in Params.
+type Template struct {
+	ID          string   `json:"id"`
+	Version     int      `json:"version"`
+	Category    string   `json:"category"`
+	Description string   `json:"description"`
+	Params      []string `json:"params,omitempty"`
+	// Text is the copy. Templates sent in rotation, like instant acks, list
+	// every alternative in Variants instead.
+	Text     string   `json:"text,omitempty"`
+	Variants []string `json:"variants,omitempty"`
+	// ClinicAuthored templates have no copy of their own and are only sent
+	// when a clinic supplies it.
+	ClinicAuthored bool `json:"clinic_authored,omitempty"`
+}
+
+// Params fills a template's placeholders. A placeholder with no param is
+// left as written.
+type Params map[string]string
+
+// Override replaces a template's copy for one clinic. Variants only apply to
+// templates sent in rotation.
+type Override struct {
+	Text     string   `json:"text,omitempty"`
+	Variants []string `json:"variants,omitempty"`
+}
+
+// Overrides maps template IDs to a clinic's own copy.
+type Overrides map[string]Override
+
+// Merge returns o with other's overrides layered on top.
+func (o Overrides) Merge(other Overrides) Overrides {
+	if len(other) == 0 {
+		return o
+	}
+	if len(o) == 0 {
+		return other
+	}
+	out := make(Overrides, len(o)+len(other))
+	for id, override := range o {
+		out[id] = override
+	}
+	for id, override := range other {
+		out[id] = override
+	}
+	return out
+}
+
+// Ref identifies the exact copy a message was rendered from.
+type Ref struct {
+	ID string `json:"id"`
+	// Version is the template version; a ".n" suffix names the rotation
+	// variant and a "-<hash>" suffix marks a clinic override of that text.
+	Version string `json:"version"`
+}
+
+// Stamp records the template on outbound reply metadata, creating the map
+// if needed. A zero Ref leaves meta untouched.
+func (r Ref) Stamp(meta map[string]string) map[string]string {
+	if r.ID == "" {
+		return meta
+	}
+	if meta == nil {
+		meta = make(map[string]string, 2)
+	}
+	meta[MetadataID] = r.ID
+	meta[MetadataVersion] = r.Version
+	return meta
+}
+
+// Rendered is a template's output together with its reference.
+type Rendered struct {
+	Ref
+	Body string
+}
+
+// Registry holds the outbound templates, keyed by ID.
+type Registry struct {
+	mu   sync.RWMutex
+	byID map[string]Template
+}
+
+// NewRegistry creates an empty registry.
+func NewRegistry() *Registry {
+	return &Registry{byID: make(map[string]Template)}
+}
+
+// Register adds t. IDs are stable: a copy change bumps Version rather than
+// minting a new ID.
+func (r *Registry) Register(t Template) error {
+	t.ID = strings.TrimSpace(t.ID)
+	switch {
+	case t.ID == "":
+		return fmt.Errorf("templates: id required")
+	case t.Version <= 0:
+		return fmt.Errorf("templates: %s: version must be positive", t.ID)
+	case t.ClinicAuthored && (t.Text != "" || len(t.Variants) > 0):
+		return fmt.Errorf("templates: %s: clinic-authored templates have no default copy", t.ID)
+	case !t.ClinicAuthored && (t.Text == "") == (len(t.Variants) == 0):
+		return fmt.Errorf("templates: %s: exactly one of text or variants required", t.ID)
+	}
+	declared := make(map[string]bool, len(t.Params))
+	for _, p := range t.Params {
+		declared[p] = true
+	}
+	for _, text := range t.texts() {
+		for _, m := range placeholderRE.FindAllStringSubmatch(text, -1) {
+			if !declared[m[1]] {
+				return fmt.Errorf("templates: %s: undeclared placeholder %s", t.ID, m[0])
+			}
+		}
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, exists := r.byID[t.ID]; exists {
+		return fmt.Errorf("templates: %s: already registered", t.ID)
+	}
+	r.byID[t.ID] = t
+	return nil
+}
+
+// MustRegister registers each template and panics on the first error.
+func (r *Registry) MustRegister(ts ...Template) {
+	for _, t := range ts {
+		if err := r.Register(t); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// Get returns the template registered under id.
+func (r *Registry) Get(id string) (Template, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	t, ok := r.byID[id]
+	return t, ok
+}
+
+// List returns every template sorted by ID.
+func (r *Registry) List() []Template {
+	r.mu.RLock()
+	out := make([]Template, 0, len(r.byID))
+	for _, t := range r.byID {
+		out = append(out, t)
+	}
+	r.mu.RUnlock()
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return out
+}
+
+// Effective returns the template as a clinic with overrides sends it, and
+// whether the clinic overrides it.
+func (r *Registry) Effective(id string, overrides Overrides) (Template, bool, bool) {
+	t, ok := r.Get(id)
+	if !ok {
+		return Template{}, false, false
+	}
+	o, overridden := overrides.lookup(t)
+	if !overridden {
+		return t, true, false
+	}
+	if len(t.Variants) > 0 {
+		t.Variants = o.Variants
+	} else {
+		t.Text = o.Text
+	}
+	return t, true, true
+}
+
+// Render fills template id with params, using the clinic's copy from
+// overrides when it has one.
+func (r *Registry) Render(id string, params Params, overrides Overrides) (Rendered, error) {
+	return r.RenderVariant(id, "", params, overrides)
+}
+
+// RenderVariant renders a rotating template, choosing the variant for the
+// message identified by key the same way every time (see Pick). Templates
+// without variants render as with Render. A clinic-authored template the
+// clinic hasn't written renders as a zero Rendered.
+func (r *Registry) RenderVariant(id, key string, params Params, overrides Overrides) (Rendered, error) {
+	t, ok, overridden := r.Effective(id, overrides)
+	if !ok {
+		return Rendered{}, fmt.Errorf("templates: %s: not registered", id)
+	}
+	if t.ClinicAuthored && !overridden {
+		return Rendered{}, nil
+	}
+	i := 0
+	if len(t.Variants) > 0 {
+		i = Pick(len(t.Variants), key)
+	}
+	text := t.texts()[i]
+	return Rendered{Ref: t.ref(i, overridden), Body: fill(text, params)}, nil
+}
+
+// Refs lists every reference template id can be sent under with overrides,
+// one per rotation variant, in variant order.
+func (r *Registry) Refs(id string, overrides Overrides) []Ref {
+	t, ok, overridden := r.Effective(id, overrides)
+	if !ok || (t.ClinicAuthored && !overridden) {
+		return nil
+	}
+	refs := make([]Ref, len(t.texts()))
+	for i := range refs {
+		refs[i] = t.ref(i, overridden)
+	}
+	return refs
+}
+
+// lookup returns the clinic's usable override for t.
+func (o Overrides) lookup(t Template) (Override, bool) {
+	override, ok := o[t.ID]
+	if !ok {
+		return Override{}, false
+	}
+	if len(t.Variants) > 0 {
+		var variants []string
+		for _, v := range override.Variants {
+			if v = strings.TrimSpace(v); v != "" {
+				variants = append(variants, v)
+			}
+		}
+		if len(variants) == 0 {
+			return Override{}, false
+		}
+		return Override{Variants: variants}, true
+	}
+	if strings.TrimSpace(override.Text) == "" {
+		return Override{}, false
+	}
+	return Override{Text: override.Text}, true
+}
+
+// ref names variant i of t; overridden marks t as carrying a clinic's copy.
+func (t Template) ref(i int, overridden bool) Ref {
+	version := strconv.Itoa(t.Version)
+	if len(t.Variants) > 0 {
+		version += "." + strconv.Itoa(i+1)
+	}
+	if overridden {
+		version += "-" + textHash(t.texts()[i])
+	}
+	return Ref{ID: t.ID, Version: version}
+}
+
+func (t Template) texts() []string {
+	if len(t.Variants) > 0 {
+		return t.Variants
+	}
+	return []string{t.Text}
+}
+
+// fill replaces placeholders with params.
+func fill(text string, params Params) string {
+	return placeholderRE.ReplaceAllStringFunc(text, func(m string) string {
+		if v, ok := params[placeholderRE.FindStringSubmatch(m)[1]]; ok {
+			return v
+		}
+		return m
+	})
+}
+
+// textHash is a short, stable fingerprint of override text.
+func textHash(text string) string {
+	sum := sha256.Sum256([]byte(text))
+	return hex.EncodeToString(sum[:4])
+}
+
+// Pick returns the index of the variant, out of n, for the message
+// identified by key. The same key always gets the same variant, so a
+// redelivered webhook doesn't text a different one; an empty key picks at
+// random.
+func Pick(n int, key string) int {
+	if n <= 1 {
+		return 0
+	}
+	if key == "" {
+		return rand.Intn(n)
+	}
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(key))
+	return int(h.Sum32() % uint32(n))
+}
@@ -0,0 +1,139 @@
+package templates
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRegistryRegisterRejectsBadTemplates(t *testing.T) {
+	cases := map[string]Template{
+		"missing id":            {Version: 1, Text: "hi"},
+		"zero version":          {ID: "x", Text: "hi"},
+		"no copy":               {ID: "x", Version: 1},
+		"text and variants":     {ID: "x", Version: 1, Text: "hi", Variants: []string{"yo"}},
+		"undeclared param":      {ID: "x", Version: 1, Text: "hi {{name}}"},
+		"clinic authored text":  {ID: "x", Version: 1, Text: "hi", ClinicAuthored: true},
+		"undeclared in variant": {ID: "x", Version: 1, Params: []string{"a"}, Variants: []string{"{{a}}", "{{b}}"}},
+	}
+	for name, tmpl := range cases {
+		if err := NewRegistry().Register(tmpl); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+
+	r := NewRegistry()
+	r.MustRegister(Template{ID: "x", Version: 1, Text: "hi"})
+	if err := r.Register(Template{ID: "x", Version: 2, Text: "hello"}); err == nil {
+		t.Fatal("expected duplicate id to be rejected")
+	}
+}
+
+func TestRegistryRenderVersions(t *testing.T) {
+	r := NewRegistry()
+	r.MustRegister(
+		Template{ID: "greet", Version: 3, Params: []string{"name", "other"}, Text: "Hi {{name}}, {{ name }}! {{other}}"},
+		Template{ID: "ack", Version: 1, Variants: []string{"one", "two", "three"}},
+		Template{ID: "intro", Version: 1, ClinicAuthored: true},
+	)
+
+	out, err := r.Render("greet", Params{"name": "Ana"}, nil)
+	if err != nil {
+		t.Fatalf("render: %v", err)
+	}
+	if out.Body != "Hi Ana, Ana! {{other}}" || out.ID != "greet" || out.Version != "3" {
+		t.Fatalf("unexpected render %+v", out)
+	}
+
+	overridden, _ := r.Render("greet", Params{"name": "Ana"}, Overrides{"greet": {Text: "Hey {{name}}"}})
+	if overridden.Body != "Hey Ana" || !strings.HasPrefix(overridden.Version, "3-") || len(overridden.Version) != len("3-")+8 {
+		t.Fatalf("unexpected override render %+v", overridden)
+	}
+	again, _ := r.Render("greet", nil, Overrides{"greet": {Text: "Hey {{name}}"}})
+	if again.Version != overridden.Version {
+		t.Fatalf("override version not stable: %q vs %q", again.Version, overridden.Version)
+	}
+
+	a, _ := r.RenderVariant("ack", "msg-1", nil, nil)
+	b, _ := r.RenderVariant("ack", "msg-1", nil, nil)
+	if a != b {
+		t.Fatalf("same key rendered different variants: %+v vs %+v", a, b)
+	}
+	if want := "1." + string(rune('1'+Pick(3, "msg-1"))); a.Version != want {
+		t.Fatalf("variant version = %q, want %q", a.Version, want)
+	}
+
+	if blank, _ := r.Render("intro", nil, nil); blank != (Rendered{}) {
+		t.Fatalf("clinic-authored template without copy should render empty, got %+v", blank)
+	}
+	if written, _ := r.Render("intro", nil, Overrides{"intro": {Text: "Welcome"}}); written.Body != "Welcome" {
+		t.Fatalf("clinic-authored template should render the clinic's copy, got %+v", written)
+	}
+
+	if _, err := r.Render("missing", nil, nil); err == nil {
+		t.Fatal("expected error for unregistered template")
+	}
+}
+
+func TestRegistryRefsMatchRender(t *testing.T) {
+	r := NewRegistry()
+	r.MustRegister(Template{ID: "ack", Version: 2, Variants: []string{"one", "two"}})
+	overrides := Overrides{"ack": {Variants: []string{" ", "uno", "dos", "tres"}}}
+
+	refs := r.Refs("ack", overrides)
+	if len(refs) != 3 {
+		t.Fatalf("expected one ref per non-blank variant, got %+v", refs)
+	}
+	for _, key := range []string{"a", "b", "c", "d"} {
+		out, _ := r.RenderVariant("ack", key, nil, overrides)
+		if out.Ref != refs[Pick(3, key)] {
+			t.Fatalf("rendered ref %+v not listed in %+v", out.Ref, refs)
+		}
+	}
+	if got := r.Refs("ack", nil); len(got) != 2 || got[1].Version != "2.2" {
+		t.Fatalf("unexpected default refs %+v", got)
+	}
+}
+
+func TestOverridesMerge(t *testing.T) {
+	global := Overrides{"a": {Text: "global a"}, "b": {Text: "global b"}}
+	merged := global.Merge(Overrides{"b": {Text: "clinic b"}})
+	if merged["a"].Text != "global a" || merged["b"].Text != "clinic b" {
+		t.Fatalf("unexpected merge %+v", merged)
+	}
+	if global["b"].Text != "global b" {
+		t.Fatal("merge mutated the receiver")
+	}
+}
+
+func TestRefStamp(t *testing.T) {
+	if got := (Ref{}).Stamp(nil); got != nil {
+		t.Fatalf("zero ref should leave metadata nil, got %v", got)
+	}
+	meta := Ref{ID: PaymentFailed, Version: "1"}.Stamp(map[string]string{"event_id": "evt"})
+	if meta[MetadataID] != PaymentFailed || meta[MetadataVersion] != "1" || meta["event_id"] != "evt" {
+		t.Fatalf("unexpected metadata %v", meta)
+	}
+}
+
+func TestDefaultCatalogRenders(t *testing.T) {
+	list := Default.List()
+	if len(list) == 0 {
+		t.Fatal("default catalog is empty")
+	}
+	for _, tmpl := range list {
+		params := make(Params, len(tmpl.Params))
+		for _, p := range tmpl.Params {
+			params[p] = "x"
+		}
+		out, err := Default.Render(tmpl.ID, params, nil)
+		if err != nil {
+			t.Fatalf("%s: %v", tmpl.ID, err)
+		}
+		if tmpl.ClinicAuthored {
+			continue
+		}
+		if strings.TrimSpace(out.Body) == "" || strings.Contains(out.Body, "{{") {
+			t.Errorf("%s: rendered %q", tmpl.ID, out.Body)
+		}
+	}
+}
@@ -0,0 +1,203 @@
+package messaging
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/conversation"
+	"github.com/wolfman30/medspa-ai-platform/internal/events"
+	"github.com/wolfman30/medspa-ai-platform/internal/messaging/templates"
+)
+
+const (
+	// twilioProcessedProvider namespaces MessageSid dedup keys in the processed store.
+	twilioProcessedProvider = "twilio.message_sid"
+	// twilioInboundEventType labels Twilio inbound SMS in MessagingMetrics.
+	twilioInboundEventType = "twilio.message.received"
+)
+
+// inboundMessageStore is the subset of Store used to persist inbound SMS.
+type inboundMessageStore interface {
+	Begin(ctx context.Context) (pgx.Tx, error)
+	InsertMessage(ctx context.Context, q Querier, rec MessageRecord) (uuid.UUID, error)
+	HasInboundMessage(ctx context.Context, clinicID uuid.UUID, from string, to string) (bool, error)
+	IsUnsubscribed(ctx context.Context, clinicID uuid.UUID, recipient string) (bool, error)
+	InsertUnsubscribe(ctx context.Context, q Querier, clinicID uuid.UUID, recipient string, source string) error
+	DeleteUnsubscribe(ctx context.Context, q Querier, clinicID uuid.UUID, recipient string) error
+}
+
+type processedTracker interface {
+	AlreadyProcessed(ctx context.Context, provider, eventID string) (bool, error)
+	MarkProcessed(ctx context.Context, provider, eventID string) (bool, error)
+}
+
+// twilioInbound captures what recordInbound learned about an inbound SMS.
+type twilioInbound struct {
+	messageID    string
+	stop         bool
+	help         bool
+	start        bool
+	unsubscribed bool
+	// firstInbound is only meaningful when persisted is true.
+	firstInbound bool
+	persisted    bool
+}
+
+// recordInbound detects compliance keywords and, when a message store is
+// configured, persists the message, its canonical event, and any opt-out
+// change in a single transaction.
+func (h *Handler) recordInbound(ctx context.Context, orgID string, webhook *TwilioWebhookRequest, from, to, storageBody string) (twilioInbound, error) {
+	var result twilioInbound
+	result.stop = h.detector.IsStop(webhook.Body)
+	result.help = h.detector.IsHelp(webhook.Body)
+	result.start = h.detector.IsStart(webhook.Body)
+
+	if h.store == nil {
+		return result, nil
+	}
+	clinicID, err := uuid.Parse(strings.TrimSpace(orgID))
+	if err != nil {
+		h.logger.Warn("twilio inbound not persisted: org id is not a uuid", "org_id", orgID)
+		return result, nil
+	}
+
+	seenInbound, err := h.store.HasInboundMessage(ctx, clinicID, from, to)
+	if err != nil {
+		return result, fmt.Errorf("check inbound history: %w", err)
+	}
+	tx, err := h.store.Begin(ctx)
+	if err != nil {
+		return result, fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	media := webhook.MediaURLs
+	msgID, err := h.store.InsertMessage(ctx, tx, MessageRecord{
+		ClinicID:          clinicID,
+		From:              from,
+		To:                to,
+		Direction:         "inbound",
+		Body:              storageBody,
+		Media:             media,
+		ProviderStatus:    "received",
+		ProviderMessageID: webhook.MessageSid,
+	})
+	if err != nil {
+		return result, fmt.Errorf("insert inbound message: %w", err)
+	}
+	received := events.MessageReceivedV1{
+		MessageID:     msgID.String(),
+		ClinicID:      clinicID.String(),
+		FromE164:      from,
+		ToE164:        to,
+		Body:          storageBody,
+		MediaURLs:     media,
+		Provider:      "twilio",
+		ReceivedAt:    time.Now().UTC(),
+		CorrelationID: webhook.MessageSid,
+	}
+	if _, err := events.AppendCanonicalEvent(ctx, tx, "clinic:"+clinicID.String(), webhook.MessageSid, received); err != nil {
+		return result, fmt.Errorf("append inbound event: %w", err)
+	}
+	if !result.stop && !result.start {
+		result.unsubscribed, err = h.store.IsUnsubscribed(ctx, clinicID, from)
+		if err != nil {
+			return result, fmt.Errorf("check unsubscribe: %w", err)
+		}
+	}
+	if result.stop {
+		if err := h.store.InsertUnsubscribe(ctx, tx, clinicID, from, "STOP"); err != nil {
+			return result, fmt.Errorf("record unsubscribe: %w", err)
+		}
+	}
+	if result.start {
+		if err := h.store.DeleteUnsubscribe(ctx, tx, clinicID, from); err != nil {
+			return result, fmt.Errorf("record resubscribe: %w", err)
+		}
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return result, fmt.Errorf("commit inbound tx: %w", err)
+	}
+
+	result.messageID = msgID.String()
+	result.firstInbound = !seenInbound
+	result.persisted = true
+	return result, nil
+}
+
+// sendAutoReply sends a compliance or guardrail reply and records it in the transcript.
+func (h *Handler) sendAutoReply(orgID, conversationID, to, from, messageSid string, msg templates.Rendered, kind string) {
+	h.appendConversationMessage(context.Background(), conversationID, conversation.SMSTranscriptMessage{
+		Role:     "assistant",
+		From:     from,
+		To:       to,
+		Body:     msg.Body,
+		Kind:     kind,
+		Metadata: msg.Stamp(nil),
+	})
+	if h.messenger == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	reply := conversation.OutboundReply{
+		OrgID:          orgID,
+		ConversationID: conversationID,
+		To:             to,
+		From:           from,
+		Body:           msg.Body,
+		Metadata: msg.Stamp(map[string]string{
+			"twilio_message_sid": messageSid,
+			"kind":               kind,
+		}),
+	}
+	if err := h.messenger.SendReply(ctx, reply); err != nil {
+		h.logger.Warn("failed to send twilio auto-reply", "error", err, "org_id", orgID, "kind", kind)
+	}
+}
+
+// validSignature checks the Twilio signature against every URL Twilio may
+// have signed: the original host forwarded by the voice lambda, the
+// configured public base URL, and the URL as received.
+func (h *Handler) validSignature(r *http.Request) bool {
+	for _, candidate := range signatureURLs(r, h.publicBaseURL) {
+		if ValidateTwilioSignature(r, h.webhookSecret, candidate) {
+			return true
+		}
+	}
+	return false
+}
+
+func signatureURLs(r *http.Request, publicBaseURL string) []string {
+	if r.URL == nil {
+		return nil
+	}
+	var urls []string
+	add := func(u string) {
+		if u == "" {
+			return
+		}
+		for _, existing := range urls {
+			if existing == u {
+				return
+			}
+		}
+		urls = append(urls, u)
+	}
+	if host := strings.TrimSpace(r.Header.Get("X-Forwarded-Host")); host != "" {
+		scheme := strings.TrimSpace(r.Header.Get("X-Forwarded-Proto"))
+		if scheme == "" {
+			scheme = "https"
+		}
+		add(fmt.Sprintf("%s://%s%s", scheme, host, r.URL.RequestURI()))
+	}
+	add(buildAbsoluteURL(r, publicBaseURL))
+	add(buildAbsoluteURL(r, ""))
+	return urls
+}
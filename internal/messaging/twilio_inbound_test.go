@@ -0,0 +1,310 @@
+package messaging
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	pgxmock "github.com/pashagolub/pgxmock/v4"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/leads"
+	observemetrics "github.com/wolfman30/medspa-ai-platform/internal/observability/metrics"
+	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
+)
+
+const (
+	twilioTestClinicNumber  = "+15559998888"
+	twilioTestPatientNumber = "+15550001111"
+)
+
+type stubProcessedTracker struct {
+	seen map[string]bool
+}
+
+func (s *stubProcessedTracker) AlreadyProcessed(ctx context.Context, provider, eventID string) (bool, error) {
+	return s.seen[provider+":"+eventID], nil
+}
+
+func (s *stubProcessedTracker) MarkProcessed(ctx context.Context, provider, eventID string) (bool, error) {
+	if s.seen == nil {
+		s.seen = make(map[string]bool)
+	}
+	key := provider + ":" + eventID
+	already := s.seen[key]
+	s.seen[key] = true
+	return !already, nil
+}
+
+func newTwilioStoreHandler(t *testing.T, clinicID uuid.UUID) (*Handler, pgxmock.PgxPoolIface, *stubPublisher, *stubSMSMessenger) {
+	t.Helper()
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("pgxmock: %v", err)
+	}
+	t.Cleanup(mock.Close)
+	resolver := NewStaticOrgResolver(map[string]string{twilioTestClinicNumber: clinicID.String()})
+	pub := &stubPublisher{}
+	messenger := &stubSMSMessenger{}
+	leadRepo := &stubLeadsRepo{lead: &leads.Lead{ID: "lead-abc", OrgID: clinicID.String()}}
+	handler := NewHandler("", pub, resolver, messenger, leadRepo, logging.Default())
+	handler.SetMessageStore(NewStore(mock))
+	handler.SetProcessedTracker(&stubProcessedTracker{})
+	return handler, mock, pub, messenger
+}
+
+func twilioInboundRequest(sid, body string) *http.Request {
+	form := url.Values{}
+	form.Set("MessageSid", sid)
+	form.Set("AccountSid", "AC123")
+	form.Set("From", twilioTestPatientNumber)
+	form.Set("To", twilioTestClinicNumber)
+	form.Set("Body", body)
+	req := httptest.NewRequest(http.MethodPost, "/messaging/twilio/webhook", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return req
+}
+
+func expectTwilioInsert(mock pgxmock.PgxPoolIface, clinicID uuid.UUID, sid, body string, seenBefore bool) {
+	seen := pgxmock.NewRows([]string{"exists"})
+	if seenBefore {
+		seen.AddRow(1)
+	}
+	mock.ExpectQuery("SELECT 1 FROM messages").
+		WithArgs(clinicID, twilioTestPatientNumber, twilioTestClinicNumber).
+		WillReturnRows(seen)
+	mock.ExpectBegin()
+	mock.ExpectQuery("INSERT INTO messages").
+		WithArgs(clinicID, twilioTestPatientNumber, twilioTestClinicNumber, "inbound", body, pgxmock.AnyArg(), "received", sid, pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), "patient", pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg()).
+		WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(uuid.New()))
+	mock.ExpectExec("INSERT INTO outbox").
+		WithArgs(pgxmock.AnyArg(), "clinic:"+clinicID.String(), "messaging.message.received.v1", pgxmock.AnyArg()).
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+}
+
+func TestTwilioInboundStop(t *testing.T) {
+	clinicID := uuid.New()
+	handler, mock, pub, messenger := newTwilioStoreHandler(t, clinicID)
+	handler.SetComplianceAcks("STOP ACK", "", "")
+
+	expectTwilioInsert(mock, clinicID, "SM_stop", "STOP", true)
+	mock.ExpectExec("INSERT INTO unsubscribes").
+		WithArgs(clinicID, twilioTestPatientNumber, "STOP").
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+	mock.ExpectCommit()
+
+	rec := httptest.NewRecorder()
+	handler.TwilioWebhook(rec, twilioInboundRequest("SM_stop", "STOP"))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d body=%s", rec.Code, rec.Body.String())
+	}
+	if !messenger.called || messenger.last.Body != "STOP ACK" {
+		t.Fatalf("expected stop ack to be sent, got %+v", messenger.last)
+	}
+	if messenger.last.To != twilioTestPatientNumber || messenger.last.From != twilioTestClinicNumber {
+		t.Fatalf("unexpected ack routing to=%s from=%s", messenger.last.To, messenger.last.From)
+	}
+	if pub.called {
+		t.Fatalf("expected conversation not to be enqueued on STOP")
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestTwilioInboundEnqueuesConversation(t *testing.T) {
+	clinicID := uuid.New()
+	handler, mock, pub, messenger := newTwilioStoreHandler(t, clinicID)
+
+	expectTwilioInsert(mock, clinicID, "SM_info", "Need info", false)
+	mock.ExpectQuery("SELECT 1 FROM unsubscribes").
+		WithArgs(clinicID, twilioTestPatientNumber).
+		WillReturnRows(pgxmock.NewRows([]string{"exists"}))
+	mock.ExpectCommit()
+
+	rec := httptest.NewRecorder()
+	handler.TwilioWebhook(rec, twilioInboundRequest("SM_info", "Need info"))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d body=%s", rec.Code, rec.Body.String())
+	}
+	if !pub.called {
+		t.Fatalf("expected conversation publisher to be invoked")
+	}
+	if pub.lastJob != "twilio:SM_info" {
+		t.Fatalf("expected namespaced job id, got %q", pub.lastJob)
+	}
+	if pub.lastReq.LeadID != "lead-abc" {
+		t.Fatalf("expected lead id from repo to propagate, got %s", pub.lastReq.LeadID)
+	}
+	if pub.lastReq.Metadata["twilio_message_sid"] != "SM_info" {
+		t.Fatalf("expected twilio metadata to propagate")
+	}
+	if !messenger.called || !IsSmsAckMessage(messenger.last.Body) {
+		t.Fatalf("expected first-contact ack, got %q", messenger.last.Body)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestTwilioInboundDuplicateMessageSidIsIgnored(t *testing.T) {
+	clinicID := uuid.New()
+	handler, mock, pub, _ := newTwilioStoreHandler(t, clinicID)
+
+	expectTwilioInsert(mock, clinicID, "SM_dup", "Hello", true)
+	mock.ExpectQuery("SELECT 1 FROM unsubscribes").
+		WithArgs(clinicID, twilioTestPatientNumber).
+		WillReturnRows(pgxmock.NewRows([]string{"exists"}))
+	mock.ExpectCommit()
+
+	first := httptest.NewRecorder()
+	handler.TwilioWebhook(first, twilioInboundRequest("SM_dup", "Hello"))
+	if first.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", first.Code)
+	}
+
+	pub.called = false
+	retry := httptest.NewRecorder()
+	handler.TwilioWebhook(retry, twilioInboundRequest("SM_dup", "Hello"))
+	if retry.Code != http.StatusOK {
+		t.Fatalf("expected 200 on retry, got %d", retry.Code)
+	}
+	if pub.called {
+		t.Fatalf("expected duplicate MessageSid not to be enqueued again")
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestTwilioInboundHelpAutoReply(t *testing.T) {
+	clinicID := uuid.New()
+	handler, mock, pub, messenger := newTwilioStoreHandler(t, clinicID)
+	handler.SetComplianceAcks("", "HELP ACK", "")
+
+	expectTwilioInsert(mock, clinicID, "SM_help", "HELP", true)
+	mock.ExpectQuery("SELECT 1 FROM unsubscribes").
+		WithArgs(clinicID, twilioTestPatientNumber).
+		WillReturnRows(pgxmock.NewRows([]string{"exists"}))
+	mock.ExpectCommit()
+
+	rec := httptest.NewRecorder()
+	handler.TwilioWebhook(rec, twilioInboundRequest("SM_help", "HELP"))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	if messenger.last.Body != "HELP ACK" {
+		t.Fatalf("expected help auto reply, got %q", messenger.last.Body)
+	}
+	if pub.called {
+		t.Fatalf("expected HELP not to start a conversation")
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestTwilioInboundStartResubscribes(t *testing.T) {
+	clinicID := uuid.New()
+	handler, mock, _, messenger := newTwilioStoreHandler(t, clinicID)
+
+	expectTwilioInsert(mock, clinicID, "SM_start", "START", true)
+	mock.ExpectExec("DELETE FROM unsubscribes").
+		WithArgs(clinicID, twilioTestPatientNumber).
+		WillReturnResult(pgxmock.NewResult("DELETE", 1))
+	mock.ExpectCommit()
+
+	rec := httptest.NewRecorder()
+	handler.TwilioWebhook(rec, twilioInboundRequest("SM_start", "START"))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	if messenger.last.Body != DefaultStartAck {
+		t.Fatalf("expected default start ack, got %q", messenger.last.Body)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestTwilioInboundUnsubscribedIsSilent(t *testing.T) {
+	clinicID := uuid.New()
+	handler, mock, pub, messenger := newTwilioStoreHandler(t, clinicID)
+
+	expectTwilioInsert(mock, clinicID, "SM_quiet", "hello?", true)
+	mock.ExpectQuery("SELECT 1 FROM unsubscribes").
+		WithArgs(clinicID, twilioTestPatientNumber).
+		WillReturnRows(pgxmock.NewRows([]string{"exists"}).AddRow(1))
+	mock.ExpectCommit()
+
+	rec := httptest.NewRecorder()
+	handler.TwilioWebhook(rec, twilioInboundRequest("SM_quiet", "hello?"))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	if pub.called || messenger.called {
+		t.Fatalf("expected no reply or conversation for unsubscribed sender")
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestTwilioInboundRecordsMetrics(t *testing.T) {
+	clinicID := uuid.New()
+	handler, mock, _, _ := newTwilioStoreHandler(t, clinicID)
+	reg := prometheus.NewRegistry()
+	handler.SetMetrics(observemetrics.NewMessagingMetrics(reg))
+
+	expectTwilioInsert(mock, clinicID, "SM_metric", "Hi", true)
+	mock.ExpectQuery("SELECT 1 FROM unsubscribes").
+		WithArgs(clinicID, twilioTestPatientNumber).
+		WillReturnRows(pgxmock.NewRows([]string{"exists"}))
+	mock.ExpectCommit()
+
+	rec := httptest.NewRecorder()
+	handler.TwilioWebhook(rec, twilioInboundRequest("SM_metric", "Hi"))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	count, err := testutil.GatherAndCount(reg, "medspa_messaging_inbound_webhook_total", "medspa_messaging_webhook_latency_seconds")
+	if err != nil {
+		t.Fatalf("gather: %v", err)
+	}
+	if count != 2 {
+		t.Fatalf("expected inbound counter and latency series, got %d", count)
+	}
+}
+
+func TestTwilioWebhook_SignatureHonorsForwardedHost(t *testing.T) {
+	handler, pub := newTestHandler(t, "secret", nil, nil)
+	handler.SetPublicBaseURL("https://api.internal.example.com")
+
+	form := url.Values{}
+	form.Set("MessageSid", "SM_fwd")
+	form.Set("From", "+15550001111")
+	form.Set("To", "+15551234567")
+	form.Set("Body", "Hi")
+	signed := "https://lambda.example.com/messaging/twilio/webhook"
+	req := httptest.NewRequest(http.MethodPost, "/messaging/twilio/webhook", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("X-Forwarded-Host", "lambda.example.com")
+	req.Header.Set("X-Forwarded-Proto", "https")
+	req.Header.Set("X-Twilio-Signature", computeSignature(buildSignaturePayload(signed, form), "secret"))
+
+	rec := httptest.NewRecorder()
+	handler.TwilioWebhook(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected forwarded-host signature to validate, got %d", rec.Code)
+	}
+	if !pub.called {
+		t.Fatalf("expected publisher to be called")
+	}
+}
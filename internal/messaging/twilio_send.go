@@ -9,6 +9,7 @@ import (
 	"math/rand"
 	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
 	"time"
 
@@ -66,12 +67,15 @@ func (s *TwilioSender) SendReply(ctx context.Context, msg conversation.OutboundR
 		return errors.New("messaging: body required")
 	}
 
+	ctx = replyLogContext(ctx, msg)
+	log := s.logger.WithContext(ctx)
 	ctx, span := twilioSendTracer.Start(ctx, "messaging.twilio.send")
 	defer span.End()
 	span.SetAttributes(
 		attribute.String("medspa.org_id", msg.OrgID),
 		attribute.String("medspa.to", msg.To),
 	)
+	span.SetAttributes(logging.SpanAttributes(ctx)...)
 
 	payload := url.Values{}
 	payload.Set("To", msg.To)
@@ -98,6 +102,10 @@ func (s *TwilioSender) SendReply(ctx context.Context, msg conversation.OutboundR
 			body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
 			resp.Body.Close()
 			if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+				if msg.Metadata != nil {
+					msg.Metadata[metadataProvider] = SMSProviderTwilio
+					msg.Metadata[metadataProviderFrom] = msg.From
+				}
 				if msg.Metadata != nil && len(body) > 0 {
 					var parsed struct {
 						SID    string `json:"sid"`
@@ -112,10 +120,10 @@ func (s *TwilioSender) SendReply(ctx context.Context, msg conversation.OutboundR
 						}
 					}
 				}
-				s.logger.Info("twilio sms sent", "org_id", msg.OrgID, "to", msg.To)
+				log.Info("twilio sms sent", "org_id", msg.OrgID, "to", msg.To)
 				return nil
 			}
-			lastErr = fmt.Errorf("twilio send failed: %s", formatTwilioError(resp.StatusCode, body))
+			lastErr = twilioProviderError(resp.StatusCode, body)
 			// Don't retry non-rate-limit 4xx errors.
 			if resp.StatusCode >= 400 && resp.StatusCode < 500 && resp.StatusCode != 429 {
 				break
@@ -141,20 +149,22 @@ type twilioAPIError struct {
 	Status   int    `json:"status"`
 }
 
-func formatTwilioError(status int, body []byte) string {
+// twilioProviderError decodes Twilio's error body, keeping its numeric code
+// for failover decisions.
+func twilioProviderError(status int, body []byte) *ProviderError {
+	providerErr := &ProviderError{Provider: SMSProviderTwilio, StatusCode: status}
 	body = bytesTrimSpace(body)
-	if len(body) == 0 {
-		return fmt.Sprintf("status %d", status)
-	}
 	var parsed twilioAPIError
 	if err := json.Unmarshal(body, &parsed); err == nil && parsed.Message != "" {
 		if parsed.Code != 0 {
-			return fmt.Sprintf("status %d code %d: %s", status, parsed.Code, parsed.Message)
+			providerErr.Code = strconv.Itoa(parsed.Code)
 		}
-		return fmt.Sprintf("status %d: %s", status, parsed.Message)
+		providerErr.Message = parsed.Message
+		return providerErr
 	}
-	// Fallback: return raw body (truncated by ReadAll limit).
-	return fmt.Sprintf("status %d: %s", status, string(body))
+	// Fallback: keep the raw body (truncated by ReadAll limit).
+	providerErr.Message = string(body)
+	return providerErr
 }
 
 func bytesTrimSpace(b []byte) []byte {
@@ -0,0 +1,154 @@
+package messaging
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/clinic"
+	"github.com/wolfman30/medspa-ai-platform/internal/http/apierror"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// TwilioVoiceGather handles POST /webhooks/twilio/voice/gather, the DTMF
+// callback from the IVR greeting. Pressing 1 starts the SMS text-back flow;
+// anything else falls through to voicemail.
+func (h *Handler) TwilioVoiceGather(w http.ResponseWriter, r *http.Request) {
+	ctx, span := twilioTracer.Start(r.Context(), "messaging.twilio.voice_gather")
+	defer span.End()
+
+	if h.webhookSecret != "" && !h.skipSignature {
+		if !h.validSignature(r) {
+			h.logger.Warn("invalid twilio gather signature")
+			apierror.Write(w, r, apierror.CodeUnauthorized, "Unauthorized")
+			span.RecordError(errors.New("invalid twilio gather signature"))
+			return
+		}
+	}
+	if err := r.ParseForm(); err != nil {
+		h.logger.Error("failed to parse twilio gather form", "error", err)
+		apierror.Write(w, r, apierror.CodeValidationFailed, "Bad Request")
+		span.RecordError(err)
+		return
+	}
+
+	callSid := strings.TrimSpace(r.FormValue("CallSid"))
+	digits := strings.TrimSpace(r.FormValue("Digits"))
+	from := NormalizeE164(r.FormValue("From"))
+	to := NormalizeE164(r.FormValue("To"))
+	span.SetAttributes(
+		attribute.String("medspa.twilio.call_sid", callSid),
+		attribute.String("medspa.twilio.digits", digits),
+	)
+	if digits != IVRTextDigit || callSid == "" || from == "" || to == "" {
+		writeTwiML(w, VoicemailXML(TwilioIVRPaths, from, to))
+		return
+	}
+
+	route, err := h.orgResolver.ResolveRoute(ctx, to)
+	if err != nil {
+		h.logger.Warn("failed to resolve org for twilio gather", "error", err, "to", to)
+		writeTwiML(w, VoicemailXML(TwilioIVRPaths, from, to))
+		return
+	}
+	orgID := route.OrgID
+	leadID, _, err := h.ensureLead(ctx, orgID, from, LeadSourceVoiceIVR)
+	if err != nil {
+		h.logger.Error("failed to persist ivr lead", "error", err, "org_id", orgID, "from", from)
+		writeTwiML(w, VoicemailXML(TwilioIVRPaths, from, to))
+		return
+	}
+	metadata := withRouteMetadata(map[string]string{
+		"twilio_call_sid": callSid,
+		"ivr_digits":      digits,
+	}, route)
+	if err := h.startMissedCallText(ctx, orgID, leadID, from, to, callSid, LeadSourceVoiceIVR, metadata); err != nil {
+		h.logger.Error("failed to enqueue ivr text-back", "error", err, "org_id", orgID, "call_sid", callSid)
+		span.RecordError(err)
+		writeTwiML(w, VoicemailXML(TwilioIVRPaths, from, to))
+		return
+	}
+	writeTwiML(w, IVRTextSentXML())
+}
+
+// TwilioVoicemailDone handles POST /webhooks/twilio/voice/voicemail, the
+// Record action that fires when the caller finishes a voicemail. It ends the
+// call; the recording arrives separately at TwilioVoiceRecording.
+func (h *Handler) TwilioVoicemailDone(w http.ResponseWriter, r *http.Request) {
+	if h.webhookSecret != "" && !h.skipSignature && !h.validSignature(r) {
+		h.logger.Warn("invalid twilio voicemail signature")
+		apierror.Write(w, r, apierror.CodeUnauthorized, "Unauthorized")
+		return
+	}
+	writeTwiML(w, VoicemailDoneXML())
+}
+
+// TwilioVoiceRecording handles POST /webhooks/twilio/voice/recording, the
+// status callback for a finished voicemail. It queues the voicemail so the
+// worker adds it to the caller's conversation and alerts the clinic.
+func (h *Handler) TwilioVoiceRecording(w http.ResponseWriter, r *http.Request) {
+	ctx, span := twilioTracer.Start(r.Context(), "messaging.twilio.voice_recording")
+	defer span.End()
+
+	if h.webhookSecret != "" && !h.skipSignature && !h.validSignature(r) {
+		h.logger.Warn("invalid twilio recording signature")
+		apierror.Write(w, r, apierror.CodeUnauthorized, "Unauthorized")
+		span.RecordError(errors.New("invalid twilio recording signature"))
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		apierror.Write(w, r, apierror.CodeValidationFailed, "Bad Request")
+		span.RecordError(err)
+		return
+	}
+	cb, ok := ParseRecordingCallback(r.URL.Query(), r.PostForm)
+	if !ok {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	span.SetAttributes(
+		attribute.String("medspa.twilio.call_sid", cb.CallSID),
+		attribute.String("medspa.twilio.recording_sid", cb.RecordingID),
+	)
+	orgID, err := h.orgResolver.ResolveOrgID(ctx, cb.To)
+	if err != nil {
+		h.logger.Warn("failed to resolve org for twilio voicemail", "error", err, "to", cb.To)
+		apierror.Write(w, r, apierror.CodeValidationFailed, "Unknown destination number")
+		return
+	}
+	leadID, _, err := h.ensureLead(ctx, orgID, cb.From, "twilio_voice")
+	if err != nil {
+		h.logger.Error("failed to persist voicemail lead", "error", err, "org_id", orgID)
+		apierror.WriteError(w, r, err, apierror.CodeInternal, "Failed to persist lead")
+		span.RecordError(err)
+		return
+	}
+	if err := EnqueueVoicemail(ctx, h.publisher, orgID, leadID, deterministicConversationID(orgID, cb.From), cb); err != nil {
+		h.logger.Error("failed to enqueue voicemail", "error", err, "org_id", orgID, "recording_sid", cb.RecordingID)
+		apierror.WriteError(w, r, err, apierror.CodeInternal, "Failed to record voicemail")
+		span.RecordError(err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ivrClinicConfig returns the clinic config for the dialed number when the
+// clinic has the IVR greeting enabled.
+func (h *Handler) ivrClinicConfig(ctx context.Context, to string) *clinic.Config {
+	orgID, err := h.orgResolver.ResolveOrgID(ctx, to)
+	if err != nil {
+		return nil
+	}
+	cfg := h.clinicConfig(ctx, orgID)
+	if cfg == nil || !cfg.VoiceIVREnabled {
+		return nil
+	}
+	return cfg
+}
+
+func writeTwiML(w http.ResponseWriter, body string) {
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write([]byte(body))
+}
@@ -0,0 +1,185 @@
+package messaging
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/clinic"
+	"github.com/wolfman30/medspa-ai-platform/internal/conversation"
+)
+
+// IVR callback paths. The voice-lambda proxy forwards these alongside the
+// voice webhooks, so actions stay relative to the public host. The voicemail
+// path ends the call once the caller finishes recording; the recording path
+// receives the finished recording.
+const (
+	TwilioIVRGatherPath    = "/webhooks/twilio/voice/gather"
+	TelnyxIVRGatherPath    = "/webhooks/telnyx/voice/gather"
+	TwilioVoicemailPath    = "/webhooks/twilio/voice/voicemail"
+	TelnyxVoicemailPath    = "/webhooks/telnyx/voice/voicemail"
+	TwilioRecordingPath    = "/webhooks/twilio/voice/recording"
+	TelnyxRecordingPath    = "/webhooks/telnyx/voice/recording"
+	recordingFromParam     = "from"
+	recordingToParam       = "to"
+	voicemailMaxLengthSecs = 120
+)
+
+// IVRPaths are one provider's IVR callback paths.
+type IVRPaths struct {
+	Gather    string
+	Voicemail string
+	Recording string
+}
+
+// Callback paths for each voice provider.
+var (
+	TwilioIVRPaths = IVRPaths{Gather: TwilioIVRGatherPath, Voicemail: TwilioVoicemailPath, Recording: TwilioRecordingPath}
+	TelnyxIVRPaths = IVRPaths{Gather: TelnyxIVRGatherPath, Voicemail: TelnyxVoicemailPath, Recording: TelnyxRecordingPath}
+)
+
+// LeadSourceVoiceIVR marks leads who pressed 1 on the IVR greeting.
+const LeadSourceVoiceIVR = "voice_ivr"
+
+// IVRTextDigit is the key callers press to get a text instead of leaving a voicemail.
+const IVRTextDigit = "1"
+
+const (
+	ivrVoicemailPrompt = "Please leave a message after the tone."
+	ivrTextSentMessage = "Great, we just sent you a text to get you booked. Goodbye!"
+)
+
+// IVRGreetingForClinic returns the spoken IVR greeting, honoring a
+// clinic-specific override.
+func IVRGreetingForClinic(cfg *clinic.Config) string {
+	if cfg != nil && strings.TrimSpace(cfg.VoiceIVRGreeting) != "" {
+		return strings.TrimSpace(cfg.VoiceIVRGreeting)
+	}
+	name := ""
+	if cfg != nil {
+		name = strings.TrimSpace(cfg.Name)
+	}
+	if name == "" {
+		return "Thanks for calling. Press 1 and we'll text you to get you booked, or stay on the line to leave a voicemail."
+	}
+	return fmt.Sprintf("Thanks for calling %s. Press 1 and we'll text you to get you booked, or stay on the line to leave a voicemail.", name)
+}
+
+// IVRGreetingXML returns TwiML (also valid TeXML) that plays the greeting
+// inside a single-digit Gather posting to paths.Gather. Callers who press
+// nothing fall through to a voicemail recording from from to the clinic
+// number to.
+func IVRGreetingXML(greeting string, paths IVRPaths, from, to string) string {
+	return voiceXML(fmt.Sprintf(
+		`<Gather numDigits="1" timeout="5" action="%s" method="POST"><Say>%s</Say></Gather>%s`,
+		escapeXML(paths.Gather), escapeXML(greeting), voicemailVerbs(paths, from, to),
+	))
+}
+
+// IVRTextSentXML confirms the text is on its way and hangs up.
+func IVRTextSentXML() string {
+	return voiceXML(fmt.Sprintf(`<Say>%s</Say><Hangup/>`, ivrTextSentMessage))
+}
+
+// VoicemailXML records a voicemail from from to the clinic number to.
+func VoicemailXML(paths IVRPaths, from, to string) string {
+	return voiceXML(voicemailVerbs(paths, from, to))
+}
+
+// VoicemailDoneXML ends the call once the caller has finished recording.
+func VoicemailDoneXML() string {
+	return voiceXML(`<Hangup/>`)
+}
+
+// voicemailVerbs prompts for and records a voicemail. The Record action hangs
+// up when the caller finishes; without it the provider would post back to the
+// voice webhook and replay the greeting. The recording status callback carries
+// only call and recording IDs, so the numbers ride along in its query string.
+func voicemailVerbs(paths IVRPaths, from, to string) string {
+	callback := paths.Recording + "?" + url.Values{recordingFromParam: {from}, recordingToParam: {to}}.Encode()
+	return fmt.Sprintf(
+		`<Say>%s</Say><Record maxLength="%d" playBeep="true" action="%s" method="POST" recordingStatusCallback="%s" recordingStatusCallbackMethod="POST"/><Hangup/>`,
+		ivrVoicemailPrompt, voicemailMaxLengthSecs, escapeXML(paths.Voicemail), escapeXML(callback),
+	)
+}
+
+// RecordingCallback is a finished voicemail recording posted to a recording
+// path.
+type RecordingCallback struct {
+	CallSID         string
+	RecordingID     string
+	RecordingURL    string
+	DurationSeconds int
+	// From is the caller and To the clinic number, normalized to E.164.
+	From string
+	To   string
+}
+
+// ParseRecordingCallback reads a recording status callback. query is the
+// callback URL's query string and form its body. ok is false for callbacks
+// other than a completed recording.
+func ParseRecordingCallback(query, form url.Values) (RecordingCallback, bool) {
+	if status := strings.ToLower(strings.TrimSpace(form.Get("RecordingStatus"))); status != "" && status != "completed" {
+		return RecordingCallback{}, false
+	}
+	cb := RecordingCallback{
+		CallSID:      strings.TrimSpace(form.Get("CallSid")),
+		RecordingID:  strings.TrimSpace(form.Get("RecordingSid")),
+		RecordingURL: strings.TrimSpace(form.Get("RecordingUrl")),
+		From:         NormalizeE164(firstNonEmpty(form.Get("From"), query.Get(recordingFromParam))),
+		To:           NormalizeE164(firstNonEmpty(form.Get("To"), query.Get(recordingToParam))),
+	}
+	cb.DurationSeconds, _ = strconv.Atoi(strings.TrimSpace(form.Get("RecordingDuration")))
+	if cb.RecordingID == "" || cb.RecordingURL == "" || cb.From == "" || cb.To == "" {
+		return RecordingCallback{}, false
+	}
+	return cb, true
+}
+
+// EnqueueVoicemail queues a finished voicemail for the conversation worker,
+// which stores it with the caller's conversation and alerts the clinic.
+func EnqueueVoicemail(ctx context.Context, publisher any, orgID, leadID, conversationID string, cb RecordingCallback) error {
+	voicemails, ok := publisher.(conversation.VoicemailPublisher)
+	if !ok {
+		return errors.New("messaging: publisher can't queue voicemails")
+	}
+	publishCtx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+	return voicemails.EnqueueVoicemail(publishCtx, conversation.VoicemailRequest{
+		OrgID:           orgID,
+		LeadID:          leadID,
+		ConversationID:  conversationID,
+		From:            cb.From,
+		To:              cb.To,
+		CallSID:         cb.CallSID,
+		RecordingID:     cb.RecordingID,
+		RecordingURL:    cb.RecordingURL,
+		DurationSeconds: cb.DurationSeconds,
+		ReceivedAt:      time.Now().UTC(),
+	})
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v = strings.TrimSpace(v); v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+func voiceXML(verbs string) string {
+	return `<?xml version="1.0" encoding="UTF-8"?><Response>` + verbs + `</Response>`
+}
+
+func escapeXML(s string) string {
+	var buf bytes.Buffer
+	_ = xml.EscapeText(&buf, []byte(s))
+	return buf.String()
+}
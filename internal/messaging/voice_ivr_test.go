@@ -0,0 +1,192 @@
+package messaging
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/clinic"
+	"github.com/wolfman30/medspa-ai-platform/internal/leads"
+	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
+)
+
+func TestIVRGreetingXML(t *testing.T) {
+	greeting := IVRGreetingForClinic(&clinic.Config{Name: "Glow & Co"})
+	got := IVRGreetingXML(greeting, TwilioIVRPaths, "+15559998888", "+15551234567")
+	want := `<?xml version="1.0" encoding="UTF-8"?><Response>` +
+		`<Gather numDigits="1" timeout="5" action="/webhooks/twilio/voice/gather" method="POST">` +
+		`<Say>Thanks for calling Glow &amp; Co. Press 1 and we&#39;ll text you to get you booked, or stay on the line to leave a voicemail.</Say>` +
+		`</Gather>` +
+		`<Say>Please leave a message after the tone.</Say>` +
+		`<Record maxLength="120" playBeep="true" action="/webhooks/twilio/voice/voicemail" method="POST"` +
+		` recordingStatusCallback="/webhooks/twilio/voice/recording?from=%2B15559998888&amp;to=%2B15551234567" recordingStatusCallbackMethod="POST"/>` +
+		`<Hangup/>` +
+		`</Response>`
+	if got != want {
+		t.Fatalf("unexpected TwiML:\n got: %s\nwant: %s", got, want)
+	}
+
+	if g := IVRGreetingForClinic(&clinic.Config{Name: "Glow", VoiceIVRGreeting: "Hi from Glow!"}); g != "Hi from Glow!" {
+		t.Fatalf("expected greeting override, got %q", g)
+	}
+}
+
+func newIVRHandler(t *testing.T, ivrEnabled bool) (*Handler, *stubPublisher, *stubLeadsRepo, *stubSMSMessenger) {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	store := clinic.NewStore(redis.NewClient(&redis.Options{Addr: mr.Addr()}))
+	cfg := clinic.DefaultConfig("org-test")
+	cfg.Name = "Glow Med Spa"
+	cfg.VoiceIVREnabled = ivrEnabled
+	if err := store.Set(context.Background(), cfg); err != nil {
+		t.Fatalf("set clinic config: %v", err)
+	}
+	resolver := NewStaticOrgResolver(map[string]string{"+15551234567": "org-test"})
+	pub := &stubPublisher{}
+	leadRepo := &stubLeadsRepo{lead: &leads.Lead{ID: "lead-123", OrgID: "org-test"}}
+	messenger := &stubSMSMessenger{}
+	handler := NewHandler("", pub, resolver, messenger, leadRepo, logging.Default())
+	handler.SetClinicStore(store)
+	return handler, pub, leadRepo, messenger
+}
+
+func postVoiceForm(handle http.HandlerFunc, path string, form url.Values) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rec := httptest.NewRecorder()
+	handle(rec, req)
+	return rec
+}
+
+func TestTwilioVoiceWebhook_IVREnabled_ReturnsGreeting(t *testing.T) {
+	handler, pub, _, messenger := newIVRHandler(t, true)
+
+	rec := postVoiceForm(handler.TwilioVoiceWebhook, "/webhooks/twilio/voice", url.Values{
+		"CallSid": {"CA1"}, "CallStatus": {"ringing"}, "From": {"+15559998888"}, "To": {"+15551234567"},
+	})
+
+	want := IVRGreetingXML("Thanks for calling Glow Med Spa. Press 1 and we'll text you to get you booked, or stay on the line to leave a voicemail.", TwilioIVRPaths, "+15559998888", "+15551234567")
+	if rec.Code != http.StatusOK || rec.Body.String() != want {
+		t.Fatalf("unexpected response %d: %s", rec.Code, rec.Body.String())
+	}
+	if pub.startJobID != "" || messenger.called {
+		t.Fatal("greeting should not start the text-back flow")
+	}
+}
+
+func TestTwilioVoiceWebhook_IVRDisabled_StillRejects(t *testing.T) {
+	handler, _, _, _ := newIVRHandler(t, false)
+
+	rec := postVoiceForm(handler.TwilioVoiceWebhook, "/webhooks/twilio/voice", url.Values{
+		"CallSid": {"CA1"}, "CallStatus": {"ringing"}, "From": {"+15559998888"}, "To": {"+15551234567"},
+	})
+	if !strings.Contains(rec.Body.String(), "<Reject") {
+		t.Fatalf("expected reject TwiML, got %s", rec.Body.String())
+	}
+}
+
+func TestTwilioVoiceGather_PressOneStartsTextBack(t *testing.T) {
+	handler, pub, leadRepo, messenger := newIVRHandler(t, true)
+
+	rec := postVoiceForm(handler.TwilioVoiceGather, TwilioIVRGatherPath, url.Values{
+		"CallSid": {"CA2"}, "Digits": {"1"}, "From": {"+15559998888"}, "To": {"+15551234567"},
+	})
+
+	if rec.Code != http.StatusOK || rec.Body.String() != IVRTextSentXML() {
+		t.Fatalf("unexpected response %d: %s", rec.Code, rec.Body.String())
+	}
+	if pub.startJobID != "CA2" || pub.lastStart.Source != LeadSourceVoiceIVR {
+		t.Fatalf("expected voice_ivr start for CA2, got job %q source %q", pub.startJobID, pub.lastStart.Source)
+	}
+	if pub.lastStart.ConversationID != "sms:org-test:15559998888" || !pub.lastStart.Silent {
+		t.Fatalf("unexpected start request: %+v", pub.lastStart)
+	}
+	if leadRepo.lastSrc != LeadSourceVoiceIVR {
+		t.Fatalf("expected lead source voice_ivr, got %q", leadRepo.lastSrc)
+	}
+	if !messenger.called || messenger.last.To != "+15559998888" || !strings.Contains(messenger.last.Body, "Glow Med Spa") {
+		t.Fatalf("expected ack text to caller, got %+v", messenger.last)
+	}
+}
+
+func TestTwilioVoiceGather_OtherDigitFallsThroughToVoicemail(t *testing.T) {
+	handler, pub, _, messenger := newIVRHandler(t, true)
+
+	for _, digits := range []string{"", "2"} {
+		rec := postVoiceForm(handler.TwilioVoiceGather, TwilioIVRGatherPath, url.Values{
+			"CallSid": {"CA3"}, "Digits": {digits}, "From": {"+15559998888"}, "To": {"+15551234567"},
+		})
+		if rec.Body.String() != VoicemailXML(TwilioIVRPaths, "+15559998888", "+15551234567") {
+			t.Fatalf("digits %q: expected voicemail TwiML, got %s", digits, rec.Body.String())
+		}
+	}
+	if pub.startJobID != "" || messenger.called {
+		t.Fatal("voicemail path should not text the caller")
+	}
+}
+
+func TestTwilioVoicemailDone_HangsUp(t *testing.T) {
+	handler, pub, _, _ := newIVRHandler(t, true)
+
+	rec := postVoiceForm(handler.TwilioVoicemailDone, TwilioVoicemailPath, url.Values{
+		"CallSid": {"CA4"}, "RecordingSid": {"RE1"}, "RecordingUrl": {"https://api.twilio.com/rec/RE1"},
+	})
+	if rec.Code != http.StatusOK || rec.Body.String() != VoicemailDoneXML() {
+		t.Fatalf("expected hangup TwiML, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if len(pub.voicemails) != 0 {
+		t.Fatal("the record action should not queue the voicemail")
+	}
+}
+
+func TestTwilioVoiceRecording_QueuesVoicemail(t *testing.T) {
+	handler, pub, leadRepo, messenger := newIVRHandler(t, true)
+
+	path := TwilioRecordingPath + "?from=%2B15559998888&to=%2B15551234567"
+	rec := postVoiceForm(handler.TwilioVoiceRecording, path, url.Values{
+		"CallSid": {"CA5"}, "RecordingSid": {"RE2"}, "RecordingUrl": {"https://api.twilio.com/rec/RE2"},
+		"RecordingStatus": {"completed"}, "RecordingDuration": {"42"},
+	})
+
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("expected 204, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if len(pub.voicemails) != 1 {
+		t.Fatalf("expected one voicemail queued, got %d", len(pub.voicemails))
+	}
+	vm := pub.voicemails[0]
+	if vm.OrgID != "org-test" || vm.LeadID != "lead-123" || vm.ConversationID != "sms:org-test:15559998888" {
+		t.Fatalf("unexpected voicemail routing: %+v", vm)
+	}
+	if vm.From != "+15559998888" || vm.To != "+15551234567" || vm.CallSID != "CA5" {
+		t.Fatalf("unexpected voicemail numbers: %+v", vm)
+	}
+	if vm.RecordingID != "RE2" || vm.RecordingURL != "https://api.twilio.com/rec/RE2" || vm.DurationSeconds != 42 {
+		t.Fatalf("unexpected recording: %+v", vm)
+	}
+	if leadRepo.lastPhone != "+15559998888" {
+		t.Fatalf("expected lead for the caller, got %q", leadRepo.lastPhone)
+	}
+	if messenger.called {
+		t.Fatal("voicemail should not text the caller")
+	}
+}
+
+func TestTwilioVoiceRecording_IgnoresUnfinishedRecording(t *testing.T) {
+	handler, pub, _, _ := newIVRHandler(t, true)
+
+	path := TwilioRecordingPath + "?from=%2B15559998888&to=%2B15551234567"
+	rec := postVoiceForm(handler.TwilioVoiceRecording, path, url.Values{
+		"CallSid": {"CA6"}, "RecordingSid": {"RE3"}, "RecordingUrl": {"https://api.twilio.com/rec/RE3"},
+		"RecordingStatus": {"failed"},
+	})
+	if rec.Code != http.StatusNoContent || len(pub.voicemails) != 0 {
+		t.Fatalf("expected failed recording ignored, got %d with %d voicemails", rec.Code, len(pub.voicemails))
+	}
+}
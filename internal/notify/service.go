@@ -9,6 +9,7 @@ import (
 	"github.com/wolfman30/medspa-ai-platform/internal/clinic"
 	"github.com/wolfman30/medspa-ai-platform/internal/events"
 	"github.com/wolfman30/medspa-ai-platform/internal/leads"
+	"github.com/wolfman30/medspa-ai-platform/internal/tenancy"
 	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
 )
 
@@ -28,6 +29,8 @@ type Service struct {
 	sms         SMSSender
 	clinicStore ClinicConfigStore
 	leadsRepo   leads.Repository
+	webhooks    *WebhookNotifier
+	transcripts TranscriptSource
 	logger      *logging.Logger
 }
 
@@ -45,6 +48,30 @@ func NewService(email EmailSender, sms SMSSender, clinicStore ClinicConfigStore,
 	}
 }
 
+// SetWebhooks enables chat webhook posts. transcripts is optional; when set,
+// posts include a short excerpt of the conversation.
+func (s *Service) SetWebhooks(webhooks *WebhookNotifier, transcripts TranscriptSource) {
+	s.webhooks = webhooks
+	s.transcripts = transcripts
+}
+
+// publishWebhook posts evt to the clinic's subscribed chat webhooks.
+func (s *Service) publishWebhook(ctx context.Context, orgID string, cfg *clinic.Config, phone string, evt WebhookEvent) error {
+	if s.webhooks == nil || cfg == nil || len(cfg.Notifications.ChatWebhooks) == 0 {
+		return nil
+	}
+	evt.ClinicName = cfg.Name
+	evt.Phone = maskPhone(phone)
+	if s.transcripts != nil && phone != "" {
+		lines, err := s.transcripts.RecentTranscript(ctx, orgID, phone, transcriptExcerptLines)
+		if err != nil {
+			s.logger.Warn("notify: failed to load transcript excerpt", "error", err, "org_id", orgID)
+		}
+		evt.Transcript = lines
+	}
+	return s.webhooks.Publish(ctx, cfg.Notifications.ChatWebhooks, evt)
+}
+
 // NotifyPaymentSuccess sends notifications when a patient pays their deposit.
 func (s *Service) NotifyPaymentSuccess(ctx context.Context, evt events.PaymentSucceededV1) error {
 	if s.clinicStore == nil {
@@ -59,11 +86,6 @@ func (s *Service) NotifyPaymentSuccess(ctx context.Context, evt events.PaymentSu
 		return fmt.Errorf("notify: get clinic config: %w", err)
 	}
 
-	if !cfg.Notifications.NotifyOnPayment {
-		s.logger.Debug("notify: payment notifications disabled for clinic", "org_id", evt.OrgID)
-		return nil
-	}
-
 	// Try to get lead details for notifications
 	// NOTE: We exclude health-related info (services, past treatments, scheduling notes)
 	// from email/SMS notifications to avoid PHI in unencrypted channels.
@@ -71,7 +93,7 @@ func (s *Service) NotifyPaymentSuccess(ctx context.Context, evt events.PaymentSu
 	// Full details are available in the secure portal.
 	var leadName, leadPhone, patientType, preferredDays, preferredTimes string
 	if s.leadsRepo != nil && evt.LeadID != "" {
-		lead, err := s.leadsRepo.GetByID(ctx, evt.OrgID, evt.LeadID)
+		lead, err := s.leadsRepo.GetByID(ctx, tenancy.ForOrg(evt.OrgID), evt.LeadID)
 		if err == nil && lead != nil {
 			leadName = lead.Name
 			leadPhone = lead.Phone
@@ -91,6 +113,32 @@ func (s *Service) NotifyPaymentSuccess(ctx context.Context, evt events.PaymentSu
 	}
 
 	location := resolveClinicLocation(cfg)
+	amountStr := fmt.Sprintf("$%.2f", float64(evt.AmountCents)/100)
+
+	var errs []error
+
+	// Chat webhooks have their own per-webhook event filter.
+	paidDetails := []string{"Amount: " + amountStr}
+	if evt.ScheduledFor != nil {
+		paidDetails = append(paidDetails, "Requested time: "+formatTimeInLocation(*evt.ScheduledFor, location, "Mon Jan 2 at 3:04 PM MST"))
+	}
+	applicationNote := depositApplicationNote(cfg)
+	paidDetails = append(paidDetails, applicationNote)
+	if err := s.publishWebhook(ctx, evt.OrgID, cfg, leadPhone, WebhookEvent{
+		Type:     EventDepositPaid,
+		LeadName: leadName,
+		Details:  paidDetails,
+	}); err != nil {
+		errs = append(errs, err)
+	}
+
+	if !cfg.Notifications.NotifyOnPayment {
+		s.logger.Debug("notify: payment notifications disabled for clinic", "org_id", evt.OrgID)
+		if len(errs) > 0 {
+			return fmt.Errorf("notify: %d notification(s) failed", len(errs))
+		}
+		return nil
+	}
 
 	// Format patient type for display
 	patientTypeInfo := ""
@@ -100,9 +148,6 @@ func (s *Service) NotifyPaymentSuccess(ctx context.Context, evt events.PaymentSu
 		patientTypeHTML = fmt.Sprintf(`<tr><td style="padding: 8px; border-bottom: 1px solid #e5e7eb;"><strong>Patient Type:</strong></td><td style="padding: 8px; border-bottom: 1px solid #e5e7eb;">%s</td></tr>`, strings.Title(patientType))
 	}
 
-	// Format amount
-	amountStr := fmt.Sprintf("$%.2f", float64(evt.AmountCents)/100)
-
 	// Build scheduled time info if available
 	scheduledInfo := ""
 	if evt.ScheduledFor != nil {
@@ -125,8 +170,6 @@ func (s *Service) NotifyPaymentSuccess(ctx context.Context, evt events.PaymentSu
 		preferencesInfo = fmt.Sprintf("\nTime Preferences: %s", strings.Join(parts, ", "))
 	}
 
-	var errs []error
-
 	// Send email notifications
 	// NOTE: Emails exclude health-related info (services, conditions, treatment history) to avoid PHI exposure
 	if cfg.Notifications.EmailEnabled && s.email != nil && len(cfg.Notifications.EmailRecipients) > 0 {
@@ -140,8 +183,9 @@ Paid: %s%s%s
 Payment ID: %s
 
 This patient is now a priority lead. Please follow up to confirm their appointment.
+Deposit status: %s
 
-— %s AI`, leadName, amountStr, leadName, leadPhone, patientTypeInfo, amountStr, transactionTime, preferencesInfo, scheduledInfo, evt.ProviderRef, cfg.Name)
+— %s AI`, leadName, amountStr, leadName, leadPhone, patientTypeInfo, amountStr, transactionTime, preferencesInfo, scheduledInfo, evt.ProviderRef, applicationNote, cfg.Name)
 
 		html := fmt.Sprintf(`<div style="font-family: sans-serif; max-width: 600px;">
 <h2 style="color: #10b981;">💰 Deposit Received!</h2>
@@ -156,10 +200,11 @@ This patient is now a priority lead. Please follow up to confirm their appointme
 <p style="background: #f0fdf4; padding: 12px; border-radius: 8px; border-left: 4px solid #10b981;">
   ⭐ <strong>Priority Lead</strong> — Please follow up to confirm their appointment.
 </p>
+<p><strong>Deposit status:</strong> %s</p>
 <p style="color: #6b7280; font-size: 12px; margin-top: 20px;">— %s AI</p>
 </div>`,
 			leadName, amountStr, leadName, leadPhone, leadPhone, patientTypeHTML, amountStr, transactionTime,
-			s.formatPreferencesHTML(preferredDays, preferredTimes), s.formatScheduledHTML(evt.ScheduledFor, location), cfg.Name)
+			s.formatPreferencesHTML(preferredDays, preferredTimes), s.formatScheduledHTML(evt.ScheduledFor, location), applicationNote, cfg.Name)
 
 		for _, recipient := range cfg.Notifications.EmailRecipients {
 			msg := EmailMessage{
@@ -192,8 +237,8 @@ This patient is now a priority lead. Please follow up to confirm their appointme
 		if patientType != "" {
 			patientTypeSMS = fmt.Sprintf(" (%s)", patientType)
 		}
-		smsBody := fmt.Sprintf("💰 %s%s paid %s deposit at %s. Phone: %s%s%s. Please call to confirm appointment.",
-			leadName, patientTypeSMS, amountStr, smsTransactionTime, leadPhone, s.formatPreferencesSMS(preferredDays, preferredTimes), s.formatScheduledSMS(evt.ScheduledFor, location))
+		smsBody := fmt.Sprintf("💰 %s%s paid %s deposit at %s. Phone: %s%s%s. Please call to confirm appointment. %s",
+			leadName, patientTypeSMS, amountStr, smsTransactionTime, leadPhone, s.formatPreferencesSMS(preferredDays, preferredTimes), s.formatScheduledSMS(evt.ScheduledFor, location), applicationNote)
 
 		for _, recipient := range smsRecipients {
 			if err := s.sms.SendSMS(ctx, recipient, smsBody); err != nil {
@@ -211,6 +256,15 @@ This patient is now a priority lead. Please follow up to confirm their appointme
 	return nil
 }
 
+// depositApplicationNote tells staff how a new deposit gets credited toward
+// the patient's service.
+func depositApplicationNote(cfg *clinic.Config) string {
+	if cfg != nil && cfg.AutoApplyDeposits {
+		return "Not yet applied. It will be marked applied automatically a day after the appointment unless it is marked a no-show."
+	}
+	return "Not yet applied. Apply it at checkout, then mark it applied in the portal."
+}
+
 func (s *Service) formatScheduledHTML(t *time.Time, loc *time.Location) string {
 	if t == nil {
 		return ""
@@ -284,12 +338,31 @@ func (s *Service) NotifyNewLead(ctx context.Context, orgID string, lead *leads.L
 		return fmt.Errorf("notify: get clinic config: %w", err)
 	}
 
+	var errs []error
+
+	leadName := lead.Name
+	if leadName == "" {
+		leadName = "A new patient"
+	}
+	var leadDetails []string
+	if lead.Source != "" {
+		leadDetails = append(leadDetails, "Source: "+lead.Source)
+	}
+	if err := s.publishWebhook(ctx, orgID, cfg, lead.Phone, WebhookEvent{
+		Type:     EventLeadCreated,
+		LeadName: leadName,
+		Details:  leadDetails,
+	}); err != nil {
+		errs = append(errs, err)
+	}
+
 	if !cfg.Notifications.NotifyOnNewLead {
+		if len(errs) > 0 {
+			return fmt.Errorf("notify: %d notification(s) failed", len(errs))
+		}
 		return nil
 	}
 
-	var errs []error
-
 	// Send email notifications
 	if cfg.Notifications.EmailEnabled && s.email != nil && len(cfg.Notifications.EmailRecipients) > 0 {
 		subject := fmt.Sprintf("🆕 New Lead - %s", lead.Name)
@@ -332,6 +405,728 @@ Message: %s
 	return nil
 }
 
+// CallbackReasonFrustration marks a callback the AI opened because the
+// patient was getting frustrated, rather than one the patient asked for.
+const CallbackReasonFrustration = "frustration"
+
+// CallbackRequest describes a patient who asked for a phone call instead of
+// continuing over text, or whom the AI handed to staff.
+type CallbackRequest struct {
+	LeadID          string
+	Phone           string
+	PreferredWindow string
+	RequestedAt     time.Time
+
+	// Reason is empty for patient-requested calls; Signals lists what
+	// triggered a frustration handoff (e.g. "repeated_info").
+	Reason  string
+	Signals []string
+}
+
+// NotifyCallbackRequested alerts operators that a patient is waiting for a
+// call, wording the alert as a frustration handoff when req.Reason says so.
+// Unlike new-lead alerts this isn't gated on a per-event preference:
+// qualification is paused until someone follows up, so any enabled channel is
+// used.
+func (s *Service) NotifyCallbackRequested(ctx context.Context, orgID string, req CallbackRequest) error {
+	if s.clinicStore == nil {
+		return nil
+	}
+
+	cfg, err := s.clinicStore.Get(ctx, orgID)
+	if err != nil {
+		return fmt.Errorf("notify: get clinic config: %w", err)
+	}
+
+	leadName := ""
+	if s.leadsRepo != nil && req.LeadID != "" {
+		if lead, err := s.leadsRepo.GetByID(ctx, tenancy.ForOrg(orgID), req.LeadID); err == nil && lead != nil {
+			leadName = lead.Name
+		}
+	}
+	if leadName == "" {
+		leadName = "A patient"
+	}
+
+	window := "as soon as possible"
+	if req.PreferredWindow != "" {
+		window = req.PreferredWindow
+	}
+	requestedAt := formatTimeInLocation(req.RequestedAt, resolveClinicLocation(cfg), "Mon Jan 2 at 3:04 PM MST")
+
+	var errs []error
+
+	frustrated := req.Reason == CallbackReasonFrustration
+	signals := "none recorded"
+	if len(req.Signals) > 0 {
+		signals = strings.Join(req.Signals, ", ")
+	}
+
+	details := []string{"Asked for a phone call (" + window + ")", "AI replies are paused until the callback is marked done."}
+	if frustrated {
+		details = []string{"Patient seems frustrated (" + signals + ")", "AI replies are paused until the callback is marked done."}
+	}
+	if err := s.publishWebhook(ctx, orgID, cfg, req.Phone, WebhookEvent{
+		Type:     EventEscalation,
+		LeadName: leadName,
+		Details:  details,
+	}); err != nil {
+		errs = append(errs, err)
+	}
+
+	if cfg.Notifications.EmailEnabled && s.email != nil && len(cfg.Notifications.EmailRecipients) > 0 {
+		subject := fmt.Sprintf("📞 Callback requested - %s", leadName)
+		body := fmt.Sprintf(`%s asked for a phone call instead of texting.
+
+Phone: %s
+Preferred time: %s
+Requested: %s
+
+AI replies are paused for this conversation until the callback is marked done.
+
+— %s AI`, leadName, req.Phone, window, requestedAt, cfg.Name)
+		if frustrated {
+			subject = fmt.Sprintf("⚠️ Frustrated patient needs a person - %s", leadName)
+			body = fmt.Sprintf(`%s seems frustrated with the AI, so we told them a team member will reach out.
+
+Phone: %s
+Signals: %s
+Escalated: %s
+
+AI replies are paused for this conversation until the callback is marked done.
+
+— %s AI`, leadName, req.Phone, signals, requestedAt, cfg.Name)
+		}
+
+		for _, recipient := range cfg.Notifications.EmailRecipients {
+			if err := s.email.Send(ctx, EmailMessage{To: recipient, Subject: subject, Body: body}); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+
+	smsRecipients := cfg.Notifications.GetSMSRecipients()
+	if cfg.Notifications.SMSEnabled && s.sms != nil && len(smsRecipients) > 0 {
+		smsBody := fmt.Sprintf("📞 Callback requested: %s (%s). Preferred: %s", leadName, req.Phone, window)
+		if frustrated {
+			smsBody = fmt.Sprintf("⚠️ Frustrated patient, please reach out: %s (%s). AI replies paused.", leadName, req.Phone)
+		}
+		for _, recipient := range smsRecipients {
+			if err := s.sms.SendSMS(ctx, recipient, smsBody); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("notify: %d notification(s) failed", len(errs))
+	}
+	return nil
+}
+
+// AttachmentReceived describes a file a patient texted that the clinic's
+// attachment rules forward to staff.
+type AttachmentReceived struct {
+	LeadID string
+	Phone  string
+	// Kind is the attachment's kind, e.g. "image".
+	Kind        string
+	MediaURL    string
+	ContentType string
+	ReceivedAt  time.Time
+}
+
+// NotifyAttachmentReceived forwards a patient's attachment to operators with
+// a link to the media, over every enabled channel; nobody on the AI side
+// looks at what was sent.
+func (s *Service) NotifyAttachmentReceived(ctx context.Context, orgID string, att AttachmentReceived) error {
+	if s.clinicStore == nil {
+		return nil
+	}
+
+	cfg, err := s.clinicStore.Get(ctx, orgID)
+	if err != nil {
+		return fmt.Errorf("notify: get clinic config: %w", err)
+	}
+
+	leadName := ""
+	if s.leadsRepo != nil && att.LeadID != "" {
+		if lead, err := s.leadsRepo.GetByID(ctx, tenancy.ForOrg(orgID), att.LeadID); err == nil && lead != nil {
+			leadName = lead.Name
+		}
+	}
+	if leadName == "" {
+		leadName = "A patient"
+	}
+	kind := att.Kind
+	if kind == "" {
+		kind = "file"
+	}
+	receivedAt := formatTimeInLocation(att.ReceivedAt, resolveClinicLocation(cfg), "Mon Jan 2 at 3:04 PM MST")
+
+	var errs []error
+
+	if err := s.publishWebhook(ctx, orgID, cfg, att.Phone, WebhookEvent{
+		Type:     EventEscalation,
+		LeadName: leadName,
+		Details:  []string{"Texted an attachment (" + kind + ") for staff review", att.MediaURL},
+	}); err != nil {
+		errs = append(errs, err)
+	}
+
+	if cfg.Notifications.EmailEnabled && s.email != nil && len(cfg.Notifications.EmailRecipients) > 0 {
+		subject := fmt.Sprintf("📎 Patient sent an attachment (%s) - %s", kind, leadName)
+		body := fmt.Sprintf(`%s texted an attachment (%s), which the AI doesn't review.
+
+Phone: %s
+Attachment: %s
+Received: %s
+
+— %s AI`, leadName, kind, att.Phone, att.MediaURL, receivedAt, cfg.Name)
+		for _, recipient := range cfg.Notifications.EmailRecipients {
+			if err := s.email.Send(ctx, EmailMessage{To: recipient, Subject: subject, Body: body}); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+
+	smsRecipients := cfg.Notifications.GetSMSRecipients()
+	if cfg.Notifications.SMSEnabled && s.sms != nil && len(smsRecipients) > 0 {
+		smsBody := fmt.Sprintf("📎 %s (%s) sent an attachment (%s): %s", leadName, att.Phone, kind, att.MediaURL)
+		for _, recipient := range smsRecipients {
+			if err := s.sms.SendSMS(ctx, recipient, smsBody); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("notify: %d notification(s) failed", len(errs))
+	}
+	return nil
+}
+
+// Voicemail describes a voicemail a caller left after the IVR greeting.
+type Voicemail struct {
+	LeadID          string
+	Phone           string
+	RecordingURL    string
+	DurationSeconds int
+	ReceivedAt      time.Time
+}
+
+// NotifyVoicemail tells operators a caller left a voicemail, with a link to
+// the recording, over every enabled channel. Voicemails wait for a person to
+// call back, so the webhook post goes out as an escalation.
+func (s *Service) NotifyVoicemail(ctx context.Context, orgID string, vm Voicemail) error {
+	if s.clinicStore == nil {
+		return nil
+	}
+
+	cfg, err := s.clinicStore.Get(ctx, orgID)
+	if err != nil {
+		return fmt.Errorf("notify: get clinic config: %w", err)
+	}
+
+	leadName := ""
+	if s.leadsRepo != nil && vm.LeadID != "" {
+		if lead, err := s.leadsRepo.GetByID(ctx, tenancy.ForOrg(orgID), vm.LeadID); err == nil && lead != nil {
+			leadName = lead.Name
+		}
+	}
+	if leadName == "" {
+		leadName = "A caller"
+	}
+	length := "a voicemail"
+	if vm.DurationSeconds > 0 {
+		length = fmt.Sprintf("a %ds voicemail", vm.DurationSeconds)
+	}
+	receivedAt := formatTimeInLocation(vm.ReceivedAt, resolveClinicLocation(cfg), "Mon Jan 2 at 3:04 PM MST")
+
+	var errs []error
+
+	if err := s.publishWebhook(ctx, orgID, cfg, vm.Phone, WebhookEvent{
+		Type:     EventEscalation,
+		LeadName: leadName,
+		Details:  []string{"Left " + length + " for a call back", vm.RecordingURL},
+	}); err != nil {
+		errs = append(errs, err)
+	}
+
+	if cfg.Notifications.EmailEnabled && s.email != nil && len(cfg.Notifications.EmailRecipients) > 0 {
+		subject := fmt.Sprintf("📞 New voicemail - %s", leadName)
+		body := fmt.Sprintf(`%s left %s.
+
+Phone: %s
+Recording: %s
+Received: %s
+
+— %s AI`, leadName, length, vm.Phone, vm.RecordingURL, receivedAt, cfg.Name)
+		for _, recipient := range cfg.Notifications.EmailRecipients {
+			if err := s.email.Send(ctx, EmailMessage{To: recipient, Subject: subject, Body: body}); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+
+	smsRecipients := cfg.Notifications.GetSMSRecipients()
+	if cfg.Notifications.SMSEnabled && s.sms != nil && len(smsRecipients) > 0 {
+		smsBody := fmt.Sprintf("📞 %s (%s) left %s: %s", leadName, vm.Phone, length, vm.RecordingURL)
+		for _, recipient := range smsRecipients {
+			if err := s.sms.SendSMS(ctx, recipient, smsBody); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("notify: %d notification(s) failed", len(errs))
+	}
+	return nil
+}
+
+// NotifyPaymentDisputed alerts operators that a patient disputed their
+// deposit. Like callback requests, disputes need a person, so every enabled
+// channel is used and the webhook post goes out as an escalation.
+func (s *Service) NotifyPaymentDisputed(ctx context.Context, evt events.PaymentDisputedV1) error {
+	if s.clinicStore == nil {
+		return nil
+	}
+
+	cfg, err := s.clinicStore.Get(ctx, evt.OrgID)
+	if err != nil {
+		return fmt.Errorf("notify: get clinic config: %w", err)
+	}
+
+	leadName := strings.TrimSpace(evt.LeadName)
+	if leadName == "" && s.leadsRepo != nil && evt.LeadID != "" {
+		if lead, err := s.leadsRepo.GetByID(ctx, tenancy.ForOrg(evt.OrgID), evt.LeadID); err == nil && lead != nil {
+			leadName = lead.Name
+		}
+	}
+	if leadName == "" {
+		leadName = "A patient"
+	}
+
+	amount := fmt.Sprintf("$%.2f", float64(evt.AmountCents)/100)
+	reason := evt.Reason
+	if reason == "" {
+		reason = "not given"
+	}
+	details := []string{"Disputed deposit: " + amount, "Reason: " + reason}
+	if evt.ScheduledFor != nil {
+		details = append(details, "Appointment: "+formatTimeInLocation(*evt.ScheduledFor, resolveClinicLocation(cfg), "Mon Jan 2 at 3:04 PM MST"))
+	}
+	details = append(details, "The booking has been flagged. Respond in your Square dashboard before the dispute deadline.")
+
+	var errs []error
+
+	if err := s.publishWebhook(ctx, evt.OrgID, cfg, evt.LeadPhone, WebhookEvent{
+		Type:     EventEscalation,
+		LeadName: leadName,
+		Details:  details,
+	}); err != nil {
+		errs = append(errs, err)
+	}
+
+	if cfg.Notifications.EmailEnabled && s.email != nil && len(cfg.Notifications.EmailRecipients) > 0 {
+		subject := fmt.Sprintf("⚠️ Deposit disputed - %s", leadName)
+		body := fmt.Sprintf(`%s disputed their %s deposit.
+
+Phone: %s
+Reason: %s
+Dispute ID: %s
+
+The booking has been flagged for review. Respond to the dispute in your Square dashboard before the deadline.
+
+— %s AI`, leadName, amount, evt.LeadPhone, reason, evt.DisputeID, cfg.Name)
+
+		for _, recipient := range cfg.Notifications.EmailRecipients {
+			if err := s.email.Send(ctx, EmailMessage{To: recipient, Subject: subject, Body: body}); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+
+	smsRecipients := cfg.Notifications.GetSMSRecipients()
+	if cfg.Notifications.SMSEnabled && s.sms != nil && len(smsRecipients) > 0 {
+		smsBody := fmt.Sprintf("⚠️ Deposit disputed: %s (%s), %s. Reason: %s", leadName, evt.LeadPhone, amount, reason)
+		for _, recipient := range smsRecipients {
+			if err := s.sms.SendSMS(ctx, recipient, smsBody); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("notify: %d notification(s) failed", len(errs))
+	}
+	return nil
+}
+
+// SlotConflict describes a paid deposit whose selected slot was taken before
+// the booking could be confirmed.
+type SlotConflict struct {
+	LeadID       string
+	Phone        string
+	Service      string
+	ScheduledFor *time.Time
+	AmountCents  int64
+	// AlternativesOffered is how many replacement times were texted to the
+	// patient. Zero means staff need to find a time with them.
+	AlternativesOffered int
+}
+
+// NotifySlotConflict alerts operators that a patient paid for a slot that
+// was gone by the time the payment landed. The deposit is kept and applied to
+// the patient's next selection, so staff should watch for the rebooking.
+func (s *Service) NotifySlotConflict(ctx context.Context, orgID string, conflict SlotConflict) error {
+	if s.clinicStore == nil {
+		return nil
+	}
+
+	cfg, err := s.clinicStore.Get(ctx, orgID)
+	if err != nil {
+		return fmt.Errorf("notify: get clinic config: %w", err)
+	}
+
+	leadName := ""
+	if s.leadsRepo != nil && conflict.LeadID != "" {
+		if lead, err := s.leadsRepo.GetByID(ctx, tenancy.ForOrg(orgID), conflict.LeadID); err == nil && lead != nil {
+			leadName = lead.Name
+		}
+	}
+	if leadName == "" {
+		leadName = "A patient"
+	}
+
+	service := conflict.Service
+	if service == "" {
+		service = "appointment"
+	}
+	slot := "their selected time"
+	if conflict.ScheduledFor != nil {
+		slot = formatTimeInLocation(*conflict.ScheduledFor, resolveClinicLocation(cfg), "Mon Jan 2 at 3:04 PM MST")
+	}
+	amount := fmt.Sprintf("$%.2f", float64(conflict.AmountCents)/100)
+	next := fmt.Sprintf("%d new time(s) were texted to the patient.", conflict.AlternativesOffered)
+	if conflict.AlternativesOffered == 0 {
+		next = "No nearby times were open - please reach out to rebook."
+	}
+
+	var errs []error
+
+	if err := s.publishWebhook(ctx, orgID, cfg, conflict.Phone, WebhookEvent{
+		Type:     EventEscalation,
+		LeadName: leadName,
+		Details: []string{
+			fmt.Sprintf("Paid %s deposit for %s at %s, but the slot was taken", amount, service, slot),
+			"Deposit kept and applied to the next selection. " + next,
+		},
+	}); err != nil {
+		errs = append(errs, err)
+	}
+
+	if cfg.Notifications.EmailEnabled && s.email != nil && len(cfg.Notifications.EmailRecipients) > 0 {
+		subject := fmt.Sprintf("⚠️ Paid slot no longer available - %s", leadName)
+		body := fmt.Sprintf(`%s paid a %s deposit for %s at %s, but the slot was booked before the payment completed.
+
+Phone: %s
+
+The deposit has been kept and will apply to their next selection. %s
+
+— %s AI`, leadName, amount, service, slot, conflict.Phone, next, cfg.Name)
+
+		for _, recipient := range cfg.Notifications.EmailRecipients {
+			if err := s.email.Send(ctx, EmailMessage{To: recipient, Subject: subject, Body: body}); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+
+	smsRecipients := cfg.Notifications.GetSMSRecipients()
+	if cfg.Notifications.SMSEnabled && s.sms != nil && len(smsRecipients) > 0 {
+		smsBody := fmt.Sprintf("⚠️ Paid slot taken: %s (%s), %s at %s. Deposit applied to next selection.", leadName, conflict.Phone, service, slot)
+		for _, recipient := range smsRecipients {
+			if err := s.sms.SendSMS(ctx, recipient, smsBody); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("notify: %d notification(s) failed", len(errs))
+	}
+	return nil
+}
+
+// BookingHandoff describes a booking the platform couldn't finish on its
+// own, so staff must book or check it by hand.
+type BookingHandoff struct {
+	LeadID       string
+	Phone        string
+	Service      string
+	ScheduledFor *time.Time
+	// Reason says what staff need to do, e.g. "Check Moxie for an
+	// existing appointment before booking".
+	Reason string
+}
+
+// NotifyBookingHandoff alerts operators that a booking was handed to them.
+func (s *Service) NotifyBookingHandoff(ctx context.Context, orgID string, handoff BookingHandoff) error {
+	if s.clinicStore == nil {
+		return nil
+	}
+
+	cfg, err := s.clinicStore.Get(ctx, orgID)
+	if err != nil {
+		return fmt.Errorf("notify: get clinic config: %w", err)
+	}
+
+	leadName := ""
+	if s.leadsRepo != nil && handoff.LeadID != "" {
+		if lead, err := s.leadsRepo.GetByID(ctx, tenancy.ForOrg(orgID), handoff.LeadID); err == nil && lead != nil {
+			leadName = lead.Name
+		}
+	}
+	if leadName == "" {
+		leadName = "A patient"
+	}
+
+	service := handoff.Service
+	if service == "" {
+		service = "an appointment"
+	}
+	slot := "no time chosen yet"
+	if handoff.ScheduledFor != nil {
+		slot = formatTimeInLocation(*handoff.ScheduledFor, resolveClinicLocation(cfg), "Mon Jan 2 at 3:04 PM MST")
+	}
+
+	var errs []error
+
+	if err := s.publishWebhook(ctx, orgID, cfg, handoff.Phone, WebhookEvent{
+		Type:     EventEscalation,
+		LeadName: leadName,
+		Details: []string{
+			fmt.Sprintf("Booking for %s (%s) needs staff", service, slot),
+			handoff.Reason,
+		},
+	}); err != nil {
+		errs = append(errs, err)
+	}
+
+	if cfg.Notifications.EmailEnabled && s.email != nil && len(cfg.Notifications.EmailRecipients) > 0 {
+		subject := fmt.Sprintf("Booking needs staff - %s", leadName)
+		body := fmt.Sprintf(`%s's booking for %s (%s) couldn't be completed automatically.
+
+Phone: %s
+
+%s
+
+— %s AI`, leadName, service, slot, handoff.Phone, handoff.Reason, cfg.Name)
+
+		for _, recipient := range cfg.Notifications.EmailRecipients {
+			if err := s.email.Send(ctx, EmailMessage{To: recipient, Subject: subject, Body: body}); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+
+	smsRecipients := cfg.Notifications.GetSMSRecipients()
+	if cfg.Notifications.SMSEnabled && s.sms != nil && len(smsRecipients) > 0 {
+		smsBody := fmt.Sprintf("Booking needs staff: %s (%s), %s at %s. %s", leadName, handoff.Phone, service, slot, handoff.Reason)
+		for _, recipient := range smsRecipients {
+			if err := s.sms.SendSMS(ctx, recipient, smsBody); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("notify: %d notification(s) failed", len(errs))
+	}
+	return nil
+}
+
+// BookingConfirmation describes an appointment confirmed on the clinic's
+// booking platform.
+type BookingConfirmation struct {
+	LeadID       string
+	Phone        string
+	Service      string
+	ScheduledFor *time.Time
+}
+
+// NotifyBookingConfirmed posts confirmed bookings to the clinic's chat
+// webhooks. Email and SMS operators already hear about bookings through the
+// deposit notification, so only webhooks are used.
+func (s *Service) NotifyBookingConfirmed(ctx context.Context, orgID string, booking BookingConfirmation) error {
+	if s.clinicStore == nil || s.webhooks == nil {
+		return nil
+	}
+
+	cfg, err := s.clinicStore.Get(ctx, orgID)
+	if err != nil {
+		return fmt.Errorf("notify: get clinic config: %w", err)
+	}
+
+	leadName := ""
+	if s.leadsRepo != nil && booking.LeadID != "" {
+		if lead, err := s.leadsRepo.GetByID(ctx, tenancy.ForOrg(orgID), booking.LeadID); err == nil && lead != nil {
+			leadName = lead.Name
+		}
+	}
+	if leadName == "" {
+		leadName = "A patient"
+	}
+
+	var details []string
+	if booking.Service != "" {
+		details = append(details, "Service: "+booking.Service)
+	}
+	if booking.ScheduledFor != nil {
+		details = append(details, "When: "+formatTimeInLocation(*booking.ScheduledFor, resolveClinicLocation(cfg), "Mon Jan 2 at 3:04 PM MST"))
+	}
+	return s.publishWebhook(ctx, orgID, cfg, booking.Phone, WebhookEvent{
+		Type:     EventBookingConfirmed,
+		LeadName: leadName,
+		Details:  details,
+	})
+}
+
+// NotifyDailyDigest sends the daily operator digest: completed appointments
+// whose deposit hasn't been marked applied, so front desk staff can credit
+// them before the patient is overcharged, and the unconverted leads most
+// worth a personal call, best first. Chat webhooks get each part as its own
+// event; email and SMS get one message.
+func (s *Service) NotifyDailyDigest(ctx context.Context, orgID string, unappliedAppointments int, topLeads []*leads.Lead) error {
+	if s.clinicStore == nil || (unappliedAppointments <= 0 && len(topLeads) == 0) {
+		return nil
+	}
+
+	cfg, err := s.clinicStore.Get(ctx, orgID)
+	if err != nil {
+		return fmt.Errorf("notify: get clinic config: %w", err)
+	}
+
+	var (
+		errs     []error
+		sections []string
+		smsParts []string
+	)
+
+	if unappliedAppointments > 0 {
+		summary := fmt.Sprintf("%d completed appointments have unapplied deposits", unappliedAppointments)
+		if unappliedAppointments == 1 {
+			summary = "1 completed appointment has an unapplied deposit"
+		}
+		action := "Review them under Deposits in the portal and mark each one applied once it's credited."
+		if err := s.publishWebhook(ctx, orgID, cfg, "", WebhookEvent{
+			Type:    EventDepositsUnapplied,
+			Details: []string{summary, action},
+		}); err != nil {
+			errs = append(errs, err)
+		}
+		sections = append(sections, summary+".\n\n"+action)
+		smsParts = append(smsParts, "🧾 "+summary+". Mark them applied in the portal.")
+	}
+
+	if len(topLeads) > 0 {
+		lines := make([]string, 0, len(topLeads))
+		names := make([]string, 0, len(topLeads))
+		for i, lead := range topLeads {
+			lines = append(lines, fmt.Sprintf("%d. %s", i+1, digestLeadLine(lead)))
+			names = append(names, strings.TrimSpace(digestLeadName(lead)+" "+maskPhone(lead.Phone)))
+		}
+		if err := s.publishWebhook(ctx, orgID, cfg, "", WebhookEvent{
+			Type:    EventTopLeads,
+			Details: lines,
+		}); err != nil {
+			errs = append(errs, err)
+		}
+		sections = append(sections, "Leads worth a personal call:\n"+strings.Join(lines, "\n"))
+		smsParts = append(smsParts, "📞 Leads to call: "+strings.Join(names, ", "))
+	}
+
+	if cfg.Notifications.EmailEnabled && s.email != nil && len(cfg.Notifications.EmailRecipients) > 0 {
+		subject := fmt.Sprintf("📋 Daily digest - %s", cfg.Name)
+		if len(topLeads) == 0 {
+			subject = fmt.Sprintf("🧾 Deposits to apply - %s", cfg.Name)
+		}
+		body := fmt.Sprintf(`%s
+
+— %s AI`, strings.Join(sections, "\n\n"), cfg.Name)
+
+		for _, recipient := range cfg.Notifications.EmailRecipients {
+			if err := s.email.Send(ctx, EmailMessage{To: recipient, Subject: subject, Body: body}); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+
+	smsRecipients := cfg.Notifications.GetSMSRecipients()
+	if cfg.Notifications.SMSEnabled && s.sms != nil && len(smsRecipients) > 0 {
+		smsBody := strings.Join(smsParts, "\n")
+		for _, recipient := range smsRecipients {
+			if err := s.sms.SendSMS(ctx, recipient, smsBody); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("notify: %d notification(s) failed", len(errs))
+	}
+	return nil
+}
+
+// digestLeadName is the lead's name, or a stand-in before one is captured.
+func digestLeadName(lead *leads.Lead) string {
+	if name := strings.TrimSpace(lead.Name); name != "" {
+		return name
+	}
+	return "Unnamed patient"
+}
+
+// digestLeadLine describes a lead in the daily digest, e.g.
+// "Sarah Johnson, •••1111 - Botox - score 85 (deposit link unpaid)". The
+// phone is masked like every other webhook detail; staff open the lead in
+// the portal to call.
+func digestLeadLine(lead *leads.Lead) string {
+	line := digestLeadName(lead)
+	if phone := maskPhone(lead.Phone); phone != "" {
+		line += ", " + phone
+	}
+	if service := lead.ScoreService(); service != "" {
+		line += " - " + service
+	}
+	line += fmt.Sprintf(" - score %d", lead.Score)
+	if lead.ScoreBreakdown == nil {
+		return line
+	}
+	b := lead.ScoreBreakdown
+	var reasons []string
+	if b.DepositPending > 0 {
+		reasons = append(reasons, "deposit link unpaid")
+	}
+	if b.Urgency > 0 {
+		reasons = append(reasons, "asked to be seen soon")
+	}
+	if b.Recency >= 15 {
+		reasons = append(reasons, "texted in the last day")
+	}
+	if b.ServiceValue*2 >= leads.ScoreServiceValueMax {
+		reasons = append(reasons, "high-value service")
+	}
+	if b.Qualification == leads.ScoreQualificationMax {
+		reasons = append(reasons, "fully qualified")
+	}
+	if len(reasons) > 0 {
+		line += " (" + strings.Join(reasons, ", ") + ")"
+	}
+	return line
+}
+
 // SimpleSMSSender provides a simple SMS sending implementation.
 type SimpleSMSSender struct {
 	sendFunc func(ctx context.Context, to, from, body string) error
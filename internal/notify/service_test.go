@@ -10,6 +10,7 @@ import (
 	"github.com/wolfman30/medspa-ai-platform/internal/clinic"
 	"github.com/wolfman30/medspa-ai-platform/internal/events"
 	"github.com/wolfman30/medspa-ai-platform/internal/leads"
+	"github.com/wolfman30/medspa-ai-platform/internal/tenancy"
 )
 
 // Mock implementations
@@ -68,11 +69,11 @@ type mockLeadsRepo struct {
 	err   error
 }
 
-func (m *mockLeadsRepo) GetByID(ctx context.Context, orgID, id string) (*leads.Lead, error) {
+func (m *mockLeadsRepo) GetByID(ctx context.Context, scope tenancy.OrgScope, id string) (*leads.Lead, error) {
 	if m.err != nil {
 		return nil, m.err
 	}
-	key := orgID + ":" + id
+	key := scope.OrgID() + ":" + id
 	if lead, ok := m.leads[key]; ok {
 		return lead, nil
 	}
@@ -87,23 +88,27 @@ func (m *mockLeadsRepo) GetOrCreateByPhone(ctx context.Context, orgID, phone, so
 	return nil, nil
 }
 
-func (m *mockLeadsRepo) UpdateSchedulingPreferences(ctx context.Context, leadID string, prefs leads.SchedulingPreferences) error {
+func (m *mockLeadsRepo) UpdateSchedulingPreferences(ctx context.Context, _ tenancy.OrgScope, leadID string, prefs leads.SchedulingPreferences) error {
 	return nil
 }
 
-func (m *mockLeadsRepo) UpdateDepositStatus(ctx context.Context, leadID, status, priority string) error {
+func (m *mockLeadsRepo) MergePreferences(ctx context.Context, leadID string, patch leads.SchedulingPreferences) error {
 	return nil
 }
 
-func (m *mockLeadsRepo) ListByOrg(ctx context.Context, orgID string, filter leads.ListLeadsFilter) ([]*leads.Lead, error) {
+func (m *mockLeadsRepo) UpdateDepositStatus(ctx context.Context, _ tenancy.OrgScope, leadID, status, priority string) error {
+	return nil
+}
+
+func (m *mockLeadsRepo) ListByOrg(ctx context.Context, scope tenancy.OrgScope, filter leads.ListLeadsFilter) ([]*leads.Lead, error) {
 	return nil, nil
 }
 
-func (m *mockLeadsRepo) UpdateSelectedAppointment(ctx context.Context, leadID string, appt leads.SelectedAppointment) error {
+func (m *mockLeadsRepo) UpdateSelectedAppointment(ctx context.Context, _ tenancy.OrgScope, leadID string, appt leads.SelectedAppointment) error {
 	return nil
 }
 
-func (m *mockLeadsRepo) UpdateBookingSession(ctx context.Context, leadID string, update leads.BookingSessionUpdate) error {
+func (m *mockLeadsRepo) UpdateBookingSession(ctx context.Context, _ tenancy.OrgScope, leadID string, update leads.BookingSessionUpdate) error {
 	return nil
 }
 
@@ -111,7 +116,7 @@ func (m *mockLeadsRepo) GetByBookingSessionID(ctx context.Context, sessionID str
 	return nil, nil
 }
 
-func (m *mockLeadsRepo) UpdateEmail(ctx context.Context, leadID string, email string) error {
+func (m *mockLeadsRepo) UpdateEmail(ctx context.Context, _ tenancy.OrgScope, leadID string, email string) error {
 	return nil
 }
 
@@ -221,6 +226,9 @@ func TestService_NotifyPaymentSuccess_EmailOnly(t *testing.T) {
 		if email.Subject == "" {
 			t.Error("expected non-empty subject")
 		}
+		if !strings.Contains(email.Body, "Deposit status: Not yet applied. Apply it at checkout") {
+			t.Errorf("expected deposit application status in email, got %q", email.Body)
+		}
 	}
 }
 
@@ -472,6 +480,366 @@ func TestService_NotifyPaymentSuccess_ClinicStoreError(t *testing.T) {
 	}
 }
 
+func TestService_NotifyCallbackRequested(t *testing.T) {
+	emailSender := &mockEmailSender{}
+	smsSender := &mockSMSSender{}
+	clinicStore := &mockClinicStore{
+		configs: map[string]*clinic.Config{
+			"org-123": {
+				OrgID: "org-123",
+				Name:  "Glow MedSpa",
+				Notifications: clinic.NotificationPrefs{
+					EmailEnabled:    true,
+					EmailRecipients: []string{"owner@clinic.com"},
+					SMSEnabled:      true,
+					SMSRecipient:    "+15551234567",
+				},
+			},
+		},
+	}
+	leadsRepo := &mockLeadsRepo{leads: map[string]*leads.Lead{
+		"org-123:lead-456": {ID: "lead-456", Name: "Jane Doe", Phone: "+15005550001"},
+	}}
+
+	svc := NewService(emailSender, smsSender, clinicStore, leadsRepo, nil)
+	err := svc.NotifyCallbackRequested(context.Background(), "org-123", CallbackRequest{
+		LeadID:          "lead-456",
+		Phone:           "+15005550001",
+		PreferredWindow: "tomorrow after 5pm",
+		RequestedAt:     time.Now(),
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if len(emailSender.sent) != 1 || !strings.Contains(emailSender.sent[0].Subject, "Jane Doe") {
+		t.Fatalf("expected callback email, got %+v", emailSender.sent)
+	}
+	if len(smsSender.sent) != 1 || !strings.Contains(smsSender.sent[0].body, "tomorrow after 5pm") {
+		t.Fatalf("expected callback SMS with preferred window, got %+v", smsSender.sent)
+	}
+}
+
+func TestService_NotifyCallbackRequested_Frustration(t *testing.T) {
+	emailSender := &mockEmailSender{}
+	smsSender := &mockSMSSender{}
+	clinicStore := &mockClinicStore{
+		configs: map[string]*clinic.Config{
+			"org-123": {
+				OrgID: "org-123",
+				Name:  "Glow MedSpa",
+				Notifications: clinic.NotificationPrefs{
+					EmailEnabled:    true,
+					EmailRecipients: []string{"owner@clinic.com"},
+					SMSEnabled:      true,
+					SMSRecipient:    "+15551234567",
+				},
+			},
+		},
+	}
+
+	svc := NewService(emailSender, smsSender, clinicStore, &mockLeadsRepo{}, nil)
+	err := svc.NotifyCallbackRequested(context.Background(), "org-123", CallbackRequest{
+		Phone:       "+15005550001",
+		RequestedAt: time.Now(),
+		Reason:      CallbackReasonFrustration,
+		Signals:     []string{"repeated_info", "complaint"},
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if len(emailSender.sent) != 1 || !strings.Contains(emailSender.sent[0].Subject, "Frustrated patient") ||
+		!strings.Contains(emailSender.sent[0].Body, "repeated_info, complaint") {
+		t.Fatalf("expected frustration email with signals, got %+v", emailSender.sent)
+	}
+	if len(smsSender.sent) != 1 || !strings.Contains(smsSender.sent[0].body, "Frustrated patient") {
+		t.Fatalf("expected frustration SMS, got %+v", smsSender.sent)
+	}
+}
+
+func TestService_NotifyAttachmentReceived(t *testing.T) {
+	emailSender := &mockEmailSender{}
+	smsSender := &mockSMSSender{}
+	clinicStore := &mockClinicStore{
+		configs: map[string]*clinic.Config{
+			"org-123": {
+				OrgID: "org-123",
+				Name:  "Glow MedSpa",
+				Notifications: clinic.NotificationPrefs{
+					EmailEnabled:    true,
+					EmailRecipients: []string{"owner@clinic.com"},
+					SMSEnabled:      true,
+					SMSRecipient:    "+15551234567",
+				},
+			},
+		},
+	}
+	leadsRepo := &mockLeadsRepo{leads: map[string]*leads.Lead{
+		"org-123:lead-456": {ID: "lead-456", Name: "Jane Doe", Phone: "+15005550001"},
+	}}
+	mediaURL := "https://media.telnyx.com/abc/photo.jpg"
+
+	svc := NewService(emailSender, smsSender, clinicStore, leadsRepo, nil)
+	err := svc.NotifyAttachmentReceived(context.Background(), "org-123", AttachmentReceived{
+		LeadID:     "lead-456",
+		Phone:      "+15005550001",
+		Kind:       "image",
+		MediaURL:   mediaURL,
+		ReceivedAt: time.Now(),
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if len(emailSender.sent) != 1 || !strings.Contains(emailSender.sent[0].Subject, "Jane Doe") ||
+		!strings.Contains(emailSender.sent[0].Body, mediaURL) {
+		t.Fatalf("expected attachment email with the media URL, got %+v", emailSender.sent)
+	}
+	if len(smsSender.sent) != 1 || !strings.Contains(smsSender.sent[0].body, mediaURL) {
+		t.Fatalf("expected attachment SMS with the media URL, got %+v", smsSender.sent)
+	}
+}
+
+func TestService_NotifyVoicemail(t *testing.T) {
+	emailSender := &mockEmailSender{}
+	smsSender := &mockSMSSender{}
+	clinicStore := &mockClinicStore{
+		configs: map[string]*clinic.Config{
+			"org-123": {
+				OrgID: "org-123",
+				Name:  "Glow MedSpa",
+				Notifications: clinic.NotificationPrefs{
+					EmailEnabled:    true,
+					EmailRecipients: []string{"owner@clinic.com"},
+					SMSEnabled:      true,
+					SMSRecipient:    "+15551234567",
+				},
+			},
+		},
+	}
+	recording := "https://api.twilio.com/2010-04-01/Accounts/AC1/Recordings/RE1"
+
+	svc := NewService(emailSender, smsSender, clinicStore, &mockLeadsRepo{}, nil)
+	err := svc.NotifyVoicemail(context.Background(), "org-123", Voicemail{
+		Phone:           "+15005550001",
+		RecordingURL:    recording,
+		DurationSeconds: 42,
+		ReceivedAt:      time.Now(),
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if len(emailSender.sent) != 1 || !strings.Contains(emailSender.sent[0].Body, recording) ||
+		!strings.Contains(emailSender.sent[0].Body, "42s voicemail") {
+		t.Fatalf("expected voicemail email with the recording, got %+v", emailSender.sent)
+	}
+	if len(smsSender.sent) != 1 || !strings.Contains(smsSender.sent[0].body, recording) {
+		t.Fatalf("expected voicemail SMS with the recording, got %+v", smsSender.sent)
+	}
+}
+
+func TestService_NotifyPaymentDisputed(t *testing.T) {
+	emailSender := &mockEmailSender{}
+	smsSender := &mockSMSSender{}
+	clinicStore := &mockClinicStore{
+		configs: map[string]*clinic.Config{
+			"org-123": {
+				OrgID: "org-123",
+				Name:  "Glow MedSpa",
+				Notifications: clinic.NotificationPrefs{
+					EmailEnabled:    true,
+					EmailRecipients: []string{"owner@clinic.com"},
+					SMSEnabled:      true,
+					SMSRecipient:    "+15551234567",
+				},
+			},
+		},
+	}
+
+	svc := NewService(emailSender, smsSender, clinicStore, &mockLeadsRepo{}, nil)
+	err := svc.NotifyPaymentDisputed(context.Background(), events.PaymentDisputedV1{
+		OrgID:       "org-123",
+		LeadID:      "lead-456",
+		LeadName:    "Jane Doe",
+		LeadPhone:   "+15005550001",
+		DisputeID:   "dp_1",
+		Reason:      "NOT_AS_DESCRIBED",
+		AmountCents: 5000,
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if len(emailSender.sent) != 1 || !strings.Contains(emailSender.sent[0].Subject, "disputed") || !strings.Contains(emailSender.sent[0].Body, "dp_1") {
+		t.Fatalf("expected dispute email, got %+v", emailSender.sent)
+	}
+	if len(smsSender.sent) != 1 || !strings.Contains(smsSender.sent[0].body, "$50.00") || !strings.Contains(smsSender.sent[0].body, "NOT_AS_DESCRIBED") {
+		t.Fatalf("expected dispute SMS with amount and reason, got %+v", smsSender.sent)
+	}
+}
+
+func TestService_NotifySlotConflict(t *testing.T) {
+	emailSender := &mockEmailSender{}
+	smsSender := &mockSMSSender{}
+	clinicStore := &mockClinicStore{
+		configs: map[string]*clinic.Config{
+			"org-123": {
+				OrgID:    "org-123",
+				Name:     "Glow MedSpa",
+				Timezone: "America/New_York",
+				Notifications: clinic.NotificationPrefs{
+					EmailEnabled:    true,
+					EmailRecipients: []string{"owner@clinic.com"},
+					SMSEnabled:      true,
+					SMSRecipient:    "+15551234567",
+				},
+			},
+		},
+	}
+	scheduled := time.Date(2026, 3, 10, 19, 0, 0, 0, time.UTC)
+
+	svc := NewService(emailSender, smsSender, clinicStore, &mockLeadsRepo{}, nil)
+	err := svc.NotifySlotConflict(context.Background(), "org-123", SlotConflict{
+		LeadID:              "lead-456",
+		Phone:               "+15005550001",
+		Service:             "Botox",
+		ScheduledFor:        &scheduled,
+		AmountCents:         5000,
+		AlternativesOffered: 3,
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if len(emailSender.sent) != 1 || !strings.Contains(emailSender.sent[0].Body, "Tue Mar 10 at 3:00 PM EDT") || !strings.Contains(emailSender.sent[0].Body, "3 new time(s)") {
+		t.Fatalf("expected slot conflict email, got %+v", emailSender.sent)
+	}
+	if len(smsSender.sent) != 1 || !strings.Contains(smsSender.sent[0].body, "Paid slot taken") {
+		t.Fatalf("expected slot conflict SMS, got %+v", smsSender.sent)
+	}
+}
+
+func TestService_NotifyBookingHandoff(t *testing.T) {
+	emailSender := &mockEmailSender{}
+	smsSender := &mockSMSSender{}
+	clinicStore := &mockClinicStore{
+		configs: map[string]*clinic.Config{
+			"org-123": {
+				OrgID:    "org-123",
+				Name:     "Glow MedSpa",
+				Timezone: "America/New_York",
+				Notifications: clinic.NotificationPrefs{
+					EmailEnabled:    true,
+					EmailRecipients: []string{"owner@clinic.com"},
+					SMSEnabled:      true,
+					SMSRecipient:    "+15551234567",
+				},
+			},
+		},
+	}
+	scheduled := time.Date(2026, 3, 10, 19, 0, 0, 0, time.UTC)
+
+	svc := NewService(emailSender, smsSender, clinicStore, &mockLeadsRepo{}, nil)
+	err := svc.NotifyBookingHandoff(context.Background(), "org-123", BookingHandoff{
+		LeadID:       "lead-456",
+		Phone:        "+15005550001",
+		Service:      "Botox",
+		ScheduledFor: &scheduled,
+		Reason:       "Check Moxie for an existing appointment before booking.",
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if len(emailSender.sent) != 1 || !strings.Contains(emailSender.sent[0].Body, "Tue Mar 10 at 3:00 PM EDT") || !strings.Contains(emailSender.sent[0].Body, "Check Moxie") {
+		t.Fatalf("expected handoff email, got %+v", emailSender.sent)
+	}
+	if len(smsSender.sent) != 1 || !strings.Contains(smsSender.sent[0].body, "Booking needs staff") {
+		t.Fatalf("expected handoff SMS, got %+v", smsSender.sent)
+	}
+}
+
+func TestService_NotifyDailyDigest_UnappliedDeposits(t *testing.T) {
+	emailSender := &mockEmailSender{}
+	smsSender := &mockSMSSender{}
+	clinicStore := &mockClinicStore{
+		configs: map[string]*clinic.Config{
+			"org-123": {
+				OrgID: "org-123",
+				Name:  "Glow MedSpa",
+				Notifications: clinic.NotificationPrefs{
+					EmailEnabled:    true,
+					EmailRecipients: []string{"owner@clinic.com"},
+					SMSEnabled:      true,
+					SMSRecipient:    "+15551234567",
+				},
+			},
+		},
+	}
+
+	svc := NewService(emailSender, smsSender, clinicStore, &mockLeadsRepo{}, nil)
+	if err := svc.NotifyDailyDigest(context.Background(), "org-123", 3, nil); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if len(emailSender.sent) != 1 || !strings.Contains(emailSender.sent[0].Body, "3 completed appointments have unapplied deposits") {
+		t.Fatalf("expected digest email, got %+v", emailSender.sent)
+	}
+	if len(smsSender.sent) != 1 || !strings.Contains(smsSender.sent[0].body, "3 completed appointments") {
+		t.Fatalf("expected digest SMS, got %+v", smsSender.sent)
+	}
+
+	if err := svc.NotifyDailyDigest(context.Background(), "org-123", 0, nil); err != nil || len(emailSender.sent) != 1 {
+		t.Fatalf("expected no digest for zero appointments, err=%v sent=%d", err, len(emailSender.sent))
+	}
+}
+
+func TestService_NotifyDailyDigest_TopLeads(t *testing.T) {
+	emailSender := &mockEmailSender{}
+	smsSender := &mockSMSSender{}
+	clinicStore := &mockClinicStore{
+		configs: map[string]*clinic.Config{
+			"org-123": {
+				OrgID: "org-123",
+				Name:  "Glow MedSpa",
+				Notifications: clinic.NotificationPrefs{
+					EmailEnabled:    true,
+					EmailRecipients: []string{"owner@clinic.com"},
+					SMSEnabled:      true,
+					SMSRecipient:    "+15551234567",
+				},
+			},
+		},
+	}
+	topLeads := []*leads.Lead{
+		{Name: "Sarah Johnson", Phone: "+15550001111", ServiceInterest: "Filler", Score: 77,
+			ScoreBreakdown: &leads.ScoreBreakdown{ServiceValue: 17, Qualification: 10, Recency: 20, Urgency: 15, DepositPending: 15}},
+		{Phone: "+15550002222", Score: 20, ScoreBreakdown: &leads.ScoreBreakdown{Recency: 20}},
+	}
+
+	svc := NewService(emailSender, smsSender, clinicStore, &mockLeadsRepo{}, nil)
+	if err := svc.NotifyDailyDigest(context.Background(), "org-123", 1, topLeads); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if len(emailSender.sent) != 1 {
+		t.Fatalf("expected one digest email, got %+v", emailSender.sent)
+	}
+	email := emailSender.sent[0]
+	for _, want := range []string{
+		"1 completed appointment has an unapplied deposit",
+		"Leads worth a personal call:",
+		"1. Sarah Johnson, •••1111 - Filler - score 77 (deposit link unpaid, asked to be seen soon, texted in the last day, high-value service)",
+		"2. Unnamed patient, •••2222 - score 20 (texted in the last day)",
+	} {
+		if !strings.Contains(email.Body, want) {
+			t.Fatalf("digest email missing %q:\n%s", want, email.Body)
+		}
+	}
+	if !strings.Contains(email.Subject, "Daily digest") {
+		t.Fatalf("subject = %q", email.Subject)
+	}
+	if len(smsSender.sent) != 1 || !strings.Contains(smsSender.sent[0].body, "Leads to call: Sarah Johnson •••1111, Unnamed patient •••2222") {
+		t.Fatalf("expected top leads in the digest SMS, got %+v", smsSender.sent)
+	}
+	if strings.Contains(email.Body, "+15550001111") || strings.Contains(smsSender.sent[0].body, "+15550001111") {
+		t.Fatal("the digest should not carry full phone numbers")
+	}
+}
+
 func TestService_NotifyPaymentSuccess_LeadLookupFallback(t *testing.T) {
 	emailSender := &mockEmailSender{}
 	clinicStore := &mockClinicStore{
@@ -0,0 +1,269 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/clinic"
+	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
+)
+
+// Chat webhook event types. Clinics subscribe to these per webhook.
+const (
+	EventLeadCreated      = "lead_created"
+	EventDepositPaid      = "deposit_paid"
+	EventBookingConfirmed = "booking_confirmed"
+	EventEscalation       = "escalation"
+	// EventAvailabilityAlert fires when the availability health probe sees
+	// a clinic's slots vanish or its booking platform start failing, or when
+	// a conversation finds the clinic's booking page dead.
+	EventAvailabilityAlert = "availability_alert"
+	// EventDepositsUnapplied is the daily digest of completed appointments
+	// whose deposit hasn't been applied at checkout.
+	EventDepositsUnapplied = "deposits_unapplied"
+	// EventTopLeads is the daily digest of unconverted leads most worth a
+	// personal call.
+	EventTopLeads = "top_leads"
+	// EventInboundSilent fires when an active clinic has received no
+	// inbound messages for too many business hours.
+	EventInboundSilent = "inbound_silent"
+)
+
+// transcriptExcerptLines is how many recent messages are quoted in a post.
+const transcriptExcerptLines = 6
+
+// TranscriptLine is one message in a transcript excerpt.
+type TranscriptLine struct {
+	Role string // "user" or "assistant"
+	Body string
+}
+
+// TranscriptSource returns the most recent messages exchanged with a lead.
+type TranscriptSource interface {
+	RecentTranscript(ctx context.Context, orgID, phone string, limit int) ([]TranscriptLine, error)
+}
+
+// WebhookEvent is the data rendered into a chat webhook post.
+type WebhookEvent struct {
+	Type       string
+	ClinicName string
+	LeadName   string
+	Phone      string
+	Details    []string // e.g. "Amount: $50.00"
+	Transcript []TranscriptLine
+}
+
+// webhookPayload is the Slack incoming-webhook body. Teams and Mattermost
+// accept the same shape.
+type webhookPayload struct {
+	Text string `json:"text"`
+}
+
+var webhookTemplates = template.Must(template.New("webhook").Funcs(template.FuncMap{
+	"speaker": func(role string) string {
+		if role == "assistant" {
+			return "AI"
+		}
+		return "Patient"
+	},
+	"oneline": func(s string) string {
+		return truncate(strings.Join(strings.Fields(s), " "), 280)
+	},
+}).Parse(`
+{{- define "lead_created"}}🆕 *New lead* for {{.ClinicName}}: {{.LeadName}}{{end}}
+{{- define "deposit_paid"}}💰 *Deposit paid* at {{.ClinicName}}: {{.LeadName}}{{end}}
+{{- define "booking_confirmed"}}✅ *Booking confirmed* at {{.ClinicName}}: {{.LeadName}}{{end}}
+{{- define "escalation"}}📞 *Needs a person* at {{.ClinicName}}: {{.LeadName}}{{end}}
+{{- define "availability_alert"}}⚠️ *Availability check failing* for {{.ClinicName}}{{end}}
+{{- define "deposits_unapplied"}}🧾 *Deposits to apply* at {{.ClinicName}}{{end}}
+{{- define "top_leads"}}📞 *Leads worth a call* at {{.ClinicName}}{{end}}
+{{- define "inbound_silent"}}🔕 *No inbound texts* for {{.ClinicName}}{{end}}
+{{- define "body"}}{{template "header" .}}
+{{- if .Phone}}
+Phone: {{.Phone}}{{end}}
+{{- range .Details}}
+{{.}}{{end}}
+{{- if .Transcript}}
+
+*Recent conversation*
+{{- range .Transcript}}
+> *{{speaker .Role}}:* {{oneline .Body}}{{end}}
+{{- end}}{{end}}`))
+
+// renderWebhookText renders the post body for evt.
+func renderWebhookText(evt WebhookEvent) (string, error) {
+	if webhookTemplates.Lookup(evt.Type) == nil {
+		return "", fmt.Errorf("notify: unknown webhook event %q", evt.Type)
+	}
+	// Each event type supplies the header for the shared body template.
+	tmpl, err := webhookTemplates.Clone()
+	if err != nil {
+		return "", fmt.Errorf("notify: clone webhook template: %w", err)
+	}
+	if _, err := tmpl.New("header").Parse(`{{template "` + evt.Type + `" .}}`); err != nil {
+		return "", fmt.Errorf("notify: parse webhook header: %w", err)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.ExecuteTemplate(&buf, "body", evt); err != nil {
+		return "", fmt.Errorf("notify: render webhook: %w", err)
+	}
+	return buf.String(), nil
+}
+
+// WebhookNotifier posts events to Slack-compatible incoming webhooks.
+type WebhookNotifier struct {
+	client      *http.Client
+	maxAttempts int
+	backoff     time.Duration
+	logger      *logging.Logger
+}
+
+// WebhookOption configures a WebhookNotifier.
+type WebhookOption func(*WebhookNotifier)
+
+// WithWebhookRetry sets the attempt limit and the initial backoff, which
+// doubles after each retryable failure.
+func WithWebhookRetry(maxAttempts int, backoff time.Duration) WebhookOption {
+	return func(n *WebhookNotifier) {
+		if maxAttempts > 0 {
+			n.maxAttempts = maxAttempts
+		}
+		if backoff >= 0 {
+			n.backoff = backoff
+		}
+	}
+}
+
+// NewWebhookNotifier creates a notifier. A nil client uses a 10s timeout.
+func NewWebhookNotifier(client *http.Client, logger *logging.Logger, opts ...WebhookOption) *WebhookNotifier {
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	if logger == nil {
+		logger = logging.Default()
+	}
+	n := &WebhookNotifier{
+		client:      client,
+		maxAttempts: 3,
+		backoff:     500 * time.Millisecond,
+		logger:      logger,
+	}
+	for _, opt := range opts {
+		opt(n)
+	}
+	return n
+}
+
+// Publish posts evt to every webhook subscribed to its type. Failures for one
+// webhook don't stop delivery to the others.
+func (n *WebhookNotifier) Publish(ctx context.Context, hooks []clinic.ChatWebhook, evt WebhookEvent) error {
+	var targets []clinic.ChatWebhook
+	for _, hook := range hooks {
+		if strings.TrimSpace(hook.URL) != "" && hook.Wants(evt.Type) {
+			targets = append(targets, hook)
+		}
+	}
+	if len(targets) == 0 {
+		return nil
+	}
+
+	text, err := renderWebhookText(evt)
+	if err != nil {
+		return err
+	}
+	body, err := json.Marshal(webhookPayload{Text: text})
+	if err != nil {
+		return fmt.Errorf("notify: encode webhook payload: %w", err)
+	}
+
+	var errs []error
+	for _, hook := range targets {
+		if err := n.post(ctx, hook, body); err != nil {
+			n.logger.Error("notify: chat webhook failed", "error", err, "webhook", hook.Redacted(), "event", evt.Type)
+			errs = append(errs, err)
+			continue
+		}
+		n.logger.Info("notify: chat webhook sent", "webhook", hook.Redacted(), "event", evt.Type)
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("notify: %d chat webhook(s) failed", len(errs))
+	}
+	return nil
+}
+
+// post delivers body, retrying network errors and 5xx responses with
+// exponential backoff. Errors never include the webhook URL.
+func (n *WebhookNotifier) post(ctx context.Context, hook clinic.ChatWebhook, body []byte) error {
+	wait := n.backoff
+	var lastErr error
+	for attempt := 1; attempt <= n.maxAttempts; attempt++ {
+		if attempt > 1 {
+			select {
+			case <-ctx.Done():
+				return fmt.Errorf("notify: webhook %s: %w", hook.Redacted(), ctx.Err())
+			case <-time.After(wait):
+			}
+			wait *= 2
+		}
+
+		retry, err := n.attempt(ctx, hook, body)
+		if err == nil {
+			return nil
+		}
+		lastErr = err
+		if !retry {
+			break
+		}
+	}
+	return lastErr
+}
+
+func (n *WebhookNotifier) attempt(ctx context.Context, hook clinic.ChatWebhook, body []byte) (retry bool, err error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, hook.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, fmt.Errorf("notify: webhook %s: invalid url", hook.Redacted())
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		// *url.Error embeds the full URL; keep only the cause.
+		var uerr *url.Error
+		if errors.As(err, &uerr) {
+			err = uerr.Err
+		}
+		return true, fmt.Errorf("notify: webhook %s: %w", hook.Redacted(), err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 500 {
+		return true, fmt.Errorf("notify: webhook %s: status %d", hook.Redacted(), resp.StatusCode)
+	}
+	if resp.StatusCode >= 300 {
+		return false, fmt.Errorf("notify: webhook %s: status %d", hook.Redacted(), resp.StatusCode)
+	}
+	return false, nil
+}
+
+// maskPhone keeps the last four digits so operators can match the lead
+// without the full number landing in a chat channel.
+func maskPhone(phone string) string {
+	digits := make([]rune, 0, len(phone))
+	for _, r := range phone {
+		if r >= '0' && r <= '9' {
+			digits = append(digits, r)
+		}
+	}
+	if len(digits) < 4 {
+		return ""
+	}
+	return "•••" + string(digits[len(digits)-4:])
+}
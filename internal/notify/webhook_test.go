@@ -0,0 +1,205 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/clinic"
+	"github.com/wolfman30/medspa-ai-platform/internal/events"
+	"github.com/wolfman30/medspa-ai-platform/internal/leads"
+)
+
+// webhookReceiver records posts and replies with the queued status codes,
+// then 200.
+type webhookReceiver struct {
+	mu       sync.Mutex
+	bodies   []map[string]any
+	statuses []int
+}
+
+func (r *webhookReceiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	var body map[string]any
+	_ = json.NewDecoder(req.Body).Decode(&body)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.bodies = append(r.bodies, body)
+	status := http.StatusOK
+	if len(r.statuses) > 0 {
+		status, r.statuses = r.statuses[0], r.statuses[1:]
+	}
+	w.WriteHeader(status)
+}
+
+func (r *webhookReceiver) posts() []map[string]any {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]map[string]any(nil), r.bodies...)
+}
+
+type stubTranscripts struct {
+	lines []TranscriptLine
+}
+
+func (s stubTranscripts) RecentTranscript(ctx context.Context, orgID, phone string, limit int) ([]TranscriptLine, error) {
+	return s.lines, nil
+}
+
+func newTestWebhookNotifier() *WebhookNotifier {
+	return NewWebhookNotifier(nil, nil, WithWebhookRetry(3, time.Millisecond))
+}
+
+func TestWebhookNotifier_PayloadShape(t *testing.T) {
+	recv := &webhookReceiver{}
+	srv := httptest.NewServer(recv)
+	defer srv.Close()
+
+	svc := NewService(nil, nil, &mockClinicStore{configs: map[string]*clinic.Config{
+		"org-1": {
+			Name:     "Glow Med Spa",
+			Timezone: "America/New_York",
+			Notifications: clinic.NotificationPrefs{
+				ChatWebhooks: []clinic.ChatWebhook{{Name: "#front-desk", URL: srv.URL}},
+			},
+		},
+	}}, &mockLeadsRepo{leads: map[string]*leads.Lead{
+		"org-1:lead-1": {ID: "lead-1", Name: "Jane Doe", Phone: "+15551234567"},
+	}}, nil)
+	svc.SetWebhooks(newTestWebhookNotifier(), stubTranscripts{lines: []TranscriptLine{
+		{Role: "user", Body: "Can I book Botox\nnext Tuesday?"},
+		{Role: "assistant", Body: "Tuesday at 2 PM works!"},
+	}})
+
+	err := svc.NotifyPaymentSuccess(context.Background(), events.PaymentSucceededV1{
+		OrgID:       "org-1",
+		LeadID:      "lead-1",
+		AmountCents: 5000,
+	})
+	if err != nil {
+		t.Fatalf("NotifyPaymentSuccess: %v", err)
+	}
+
+	posts := recv.posts()
+	if len(posts) != 1 {
+		t.Fatalf("expected 1 post, got %d", len(posts))
+	}
+	if len(posts[0]) != 1 {
+		t.Fatalf("expected only a text field, got %v", posts[0])
+	}
+	text, _ := posts[0]["text"].(string)
+	for _, want := range []string{
+		"💰 *Deposit paid* at Glow Med Spa: Jane Doe",
+		"Phone: •••4567",
+		"Amount: $50.00",
+		"> *Patient:* Can I book Botox next Tuesday?",
+		"> *AI:* Tuesday at 2 PM works!",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("expected %q in post:\n%s", want, text)
+		}
+	}
+	if strings.Contains(text, "5551234567") {
+		t.Errorf("full phone number leaked into post:\n%s", text)
+	}
+}
+
+func TestWebhookNotifier_RetriesOn5xx(t *testing.T) {
+	recv := &webhookReceiver{statuses: []int{http.StatusBadGateway, http.StatusServiceUnavailable}}
+	srv := httptest.NewServer(recv)
+	defer srv.Close()
+
+	n := newTestWebhookNotifier()
+	err := n.Publish(context.Background(), []clinic.ChatWebhook{{URL: srv.URL}}, WebhookEvent{Type: EventEscalation, LeadName: "Jane"})
+	if err != nil {
+		t.Fatalf("expected success after retries, got %v", err)
+	}
+	if got := len(recv.posts()); got != 3 {
+		t.Fatalf("expected 3 attempts, got %d", got)
+	}
+}
+
+func TestWebhookNotifier_GivesUp(t *testing.T) {
+	t.Run("after max attempts", func(t *testing.T) {
+		recv := &webhookReceiver{statuses: []int{500, 500, 500, 500}}
+		srv := httptest.NewServer(recv)
+		defer srv.Close()
+
+		err := newTestWebhookNotifier().Publish(context.Background(), []clinic.ChatWebhook{{URL: srv.URL + "/T000/secret-token"}}, WebhookEvent{Type: EventEscalation})
+		if err == nil {
+			t.Fatal("expected error")
+		}
+		if strings.Contains(err.Error(), "secret-token") {
+			t.Fatalf("error leaks webhook url: %v", err)
+		}
+		if got := len(recv.posts()); got != 3 {
+			t.Fatalf("expected 3 attempts, got %d", got)
+		}
+	})
+
+	t.Run("4xx is not retried", func(t *testing.T) {
+		recv := &webhookReceiver{statuses: []int{http.StatusNotFound}}
+		srv := httptest.NewServer(recv)
+		defer srv.Close()
+
+		if err := newTestWebhookNotifier().Publish(context.Background(), []clinic.ChatWebhook{{URL: srv.URL}}, WebhookEvent{Type: EventEscalation}); err == nil {
+			t.Fatal("expected error")
+		}
+		if got := len(recv.posts()); got != 1 {
+			t.Fatalf("expected 1 attempt, got %d", got)
+		}
+	})
+}
+
+func TestWebhookNotifier_EventFilter(t *testing.T) {
+	bookings := &webhookReceiver{}
+	bookingSrv := httptest.NewServer(bookings)
+	defer bookingSrv.Close()
+	all := &webhookReceiver{}
+	allSrv := httptest.NewServer(all)
+	defer allSrv.Close()
+
+	svc := NewService(nil, nil, &mockClinicStore{configs: map[string]*clinic.Config{
+		"org-1": {
+			Name: "Glow Med Spa",
+			Notifications: clinic.NotificationPrefs{
+				ChatWebhooks: []clinic.ChatWebhook{
+					{URL: bookingSrv.URL, Events: []string{EventBookingConfirmed}},
+					{URL: allSrv.URL},
+				},
+			},
+		},
+	}}, nil, nil)
+	svc.SetWebhooks(newTestWebhookNotifier(), nil)
+
+	ctx := context.Background()
+	if err := svc.NotifyNewLead(ctx, "org-1", &leads.Lead{Name: "Jane", Phone: "+15551234567", Source: "telnyx_sms"}); err != nil {
+		t.Fatalf("NotifyNewLead: %v", err)
+	}
+	scheduled := time.Date(2026, 3, 10, 18, 0, 0, 0, time.UTC)
+	if err := svc.NotifyBookingConfirmed(ctx, "org-1", BookingConfirmation{Phone: "+15551234567", Service: "Botox", ScheduledFor: &scheduled}); err != nil {
+		t.Fatalf("NotifyBookingConfirmed: %v", err)
+	}
+
+	if got := len(bookings.posts()); got != 1 {
+		t.Fatalf("booking-only webhook got %d posts, want 1", got)
+	}
+	text, _ := bookings.posts()[0]["text"].(string)
+	if !strings.Contains(text, "✅ *Booking confirmed*") || !strings.Contains(text, "When: Tue Mar 10 at 2:00 PM EDT") {
+		t.Fatalf("unexpected booking post:\n%s", text)
+	}
+	if got := len(all.posts()); got != 2 {
+		t.Fatalf("unfiltered webhook got %d posts, want 2", got)
+	}
+}
+
+func TestChatWebhook_Redacted(t *testing.T) {
+	hook := clinic.ChatWebhook{Name: "#front-desk", URL: "https://hooks.slack.com/services/T000/B000/secret"}
+	if got := hook.Redacted(); got != "#front-desk (hooks.slack.com)" {
+		t.Fatalf("Redacted() = %q", got)
+	}
+}
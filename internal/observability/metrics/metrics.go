@@ -7,6 +7,9 @@ type MessagingMetrics struct {
 	inboundTotal   *prometheus.CounterVec
 	outboundTotal  *prometheus.CounterVec
 	webhookLatency *prometheus.HistogramVec
+	combinedTotal  *prometheus.CounterVec
+	combinedParts  prometheus.Histogram
+	inactiveOrg    *prometheus.CounterVec
 }
 
 func NewMessagingMetrics(reg prometheus.Registerer) *MessagingMetrics {
@@ -30,11 +33,30 @@ func NewMessagingMetrics(reg prometheus.Registerer) *MessagingMetrics {
 			Help:      "Latency of Telnyx webhook processing",
 			Buckets:   prometheus.DefBuckets,
 		}, []string{"event_type"}),
+		combinedTotal: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "medspa",
+			Subsystem: "messaging",
+			Name:      "inbound_combined_total",
+			Help:      "Multi-part inbound messages combined before dispatch",
+		}, []string{"trigger"}),
+		combinedParts: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: "medspa",
+			Subsystem: "messaging",
+			Name:      "inbound_combined_parts",
+			Help:      "Number of parts per combined inbound message",
+			Buckets:   []float64{2, 3, 4, 5, 6, 8, 10},
+		}),
+		inactiveOrg: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "medspa",
+			Subsystem: "messaging",
+			Name:      "inactive_org_inbound_total",
+			Help:      "Inbound messages to deactivated or unconfigured clinics answered with the farewell",
+		}, []string{"reason"}),
 	}
 	if reg == nil {
 		reg = prometheus.DefaultRegisterer
 	}
-	reg.MustRegister(m.inboundTotal, m.outboundTotal, m.webhookLatency)
+	reg.MustRegister(m.inboundTotal, m.outboundTotal, m.webhookLatency, m.combinedTotal, m.combinedParts, m.inactiveOrg)
 	return m
 }
 
@@ -62,3 +84,22 @@ func (m *MessagingMetrics) ObserveWebhookLatency(eventType string, seconds float
 	}
 	m.webhookLatency.WithLabelValues(eventType).Observe(seconds)
 }
+
+// ObserveCombinedInbound records a multi-part inbound message delivered as one.
+// trigger is "complete" (all numbered parts arrived) or "timeout".
+func (m *MessagingMetrics) ObserveCombinedInbound(trigger string, parts int) {
+	if m == nil {
+		return
+	}
+	m.combinedTotal.WithLabelValues(trigger).Inc()
+	m.combinedParts.Observe(float64(parts))
+}
+
+// ObserveInactiveOrg records an inbound message to a clinic that is no longer
+// served. reason is "inactive" (deactivated) or "not_configured".
+func (m *MessagingMetrics) ObserveInactiveOrg(reason string) {
+	if m == nil {
+		return
+	}
+	m.inactiveOrg.WithLabelValues(reason).Inc()
+}
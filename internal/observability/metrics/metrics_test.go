@@ -11,6 +11,8 @@ func TestMessagingMetricsObserve(t *testing.T) {
 	m.ObserveInbound("message.received", "delivered")
 	m.ObserveOutbound("queued", false)
 	m.ObserveWebhookLatency("message.received", 0.5)
+	m.ObserveCombinedInbound("timeout", 3)
+	m.ObserveInactiveOrg("inactive")
 }
 
 func TestMessagingMetricsCustomRegistry(t *testing.T) {
@@ -24,4 +26,6 @@ func TestMessagingMetricsNilSafe(t *testing.T) {
 	m.ObserveInbound("event", "status")
 	m.ObserveOutbound("queued", false)
 	m.ObserveWebhookLatency("event", 0.1)
+	m.ObserveCombinedInbound("complete", 2)
+	m.ObserveInactiveOrg("not_configured")
 }
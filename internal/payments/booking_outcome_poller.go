@@ -0,0 +1,290 @@
+package payments
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/clinic"
+	"github.com/wolfman30/medspa-ai-platform/internal/leads"
+	"github.com/wolfman30/medspa-ai-platform/internal/tenancy"
+	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
+)
+
+const (
+	// defaultAppointmentMinutes is assumed for bookings with no duration.
+	defaultAppointmentMinutes = 60
+	// noShowReviewWindow is how long after an appointment ends staff have to
+	// mark it a no-show before it is treated as completed.
+	noShowReviewWindow = 24 * time.Hour
+	// bookingOutcomeBatchSize bounds how many bookings one poll completes.
+	bookingOutcomeBatchSize = 200
+	// depositDigestHour is the clinic-local hour from which the daily
+	// digest goes out.
+	depositDigestHour = 8
+	// depositDigestProvider namespaces digest sends in processed_events.
+	depositDigestProvider = "deposit_digest"
+	// digestLeadWindow is how far back a lead's last text may be for it to
+	// make the digest; older leads have no recency or urgency left.
+	digestLeadWindow = 7 * 24 * time.Hour
+	// digestCandidateFactor widens the stored-score shortlist, since
+	// rescoring at send time can reorder it.
+	digestCandidateFactor = 4
+)
+
+type bookingOutcomeStore interface {
+	CompleteEndedBookings(ctx context.Context, now, endedBy time.Time, defaultMinutes, limit int) ([]CompletedBooking, error)
+	ApplyLeadDeposits(ctx context.Context, orgID string, leadID uuid.UUID, paidBefore time.Time, appliedBy string) (int64, error)
+	UnappliedCompletedDeposits(ctx context.Context) ([]UnappliedDepositSummary, error)
+}
+
+// DailyDigestNotifier sends a clinic its daily digest: how many completed
+// appointments still have a deposit to apply, and the unconverted leads most
+// worth a call, best first.
+type DailyDigestNotifier interface {
+	NotifyDailyDigest(ctx context.Context, orgID string, unappliedAppointments int, topLeads []*leads.Lead) error
+}
+
+// digestLeadSource shortlists unconverted leads for the digest;
+// leads.ScoreRepository satisfies it.
+type digestLeadSource interface {
+	DigestCandidates(ctx context.Context, scope tenancy.OrgScope, since time.Time, perOrg int) (map[string][]*leads.Lead, error)
+}
+
+// digestMarker records that a digest went out so restarts and multiple
+// workers don't send it twice; events.ProcessedStore satisfies it.
+type digestMarker interface {
+	MarkProcessed(ctx context.Context, provider, eventID string) (bool, error)
+}
+
+// BookingOutcomePoller treats bookings as completed a day after their
+// scheduled end. No booking platform reports attendance to us yet, so an
+// appointment that wasn't cancelled or marked a no-show by staff in that time
+// is assumed to have happened; no-show deposits stay unapplied for staff to
+// review. Clinics that
+// opt in have the deposit applied automatically; every clinic with completed
+// appointments still carrying an unapplied deposit, or with recently active
+// unconverted leads, gets a daily digest.
+type BookingOutcomePoller struct {
+	store     bookingOutcomeStore
+	clinics   clinicConfigLookup
+	notifier  DailyDigestNotifier
+	marker    digestMarker
+	leads     digestLeadSource
+	topLeads  int
+	leadsOnly bool // skip booking outcomes; see WithLeadsDigestOnly
+	logger    *logging.Logger
+	interval  time.Duration
+	now       func() time.Time
+}
+
+// NewBookingOutcomePoller creates the poller. notifier and marker may be nil
+// to skip the digest.
+func NewBookingOutcomePoller(store bookingOutcomeStore, clinics clinicConfigLookup, notifier DailyDigestNotifier, marker digestMarker, logger *logging.Logger) *BookingOutcomePoller {
+	if logger == nil {
+		logger = logging.Default()
+	}
+	return &BookingOutcomePoller{
+		store:    store,
+		clinics:  clinics,
+		notifier: notifier,
+		marker:   marker,
+		logger:   logger,
+		interval: 15 * time.Minute,
+		now:      time.Now,
+	}
+}
+
+// WithInterval sets how often the poller runs.
+func (p *BookingOutcomePoller) WithInterval(interval time.Duration) *BookingOutcomePoller {
+	if interval > 0 {
+		p.interval = interval
+	}
+	return p
+}
+
+// WithTopLeads lists the org's n highest-scoring unconverted leads in the
+// digest. n <= 0 or a nil source leaves leads out.
+func (p *BookingOutcomePoller) WithTopLeads(source digestLeadSource, n int) *BookingOutcomePoller {
+	if source != nil && n > 0 {
+		p.leads = source
+		p.topLeads = n
+	}
+	return p
+}
+
+// WithLeadsDigestOnly runs only the top-leads part of the digest: bookings
+// aren't completed, deposits aren't applied and unapplied deposits aren't
+// reported.
+func (p *BookingOutcomePoller) WithLeadsDigestOnly() *BookingOutcomePoller {
+	p.leadsOnly = true
+	return p
+}
+
+// Start polls on the configured interval. Blocks until context is cancelled.
+func (p *BookingOutcomePoller) Start(ctx context.Context) {
+	p.logger.Info("starting booking outcome poller", "interval", p.interval.String())
+
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			p.logger.Info("booking outcome poller shutting down")
+			return
+		case <-ticker.C:
+			if err := p.RunOnce(ctx); err != nil {
+				p.logger.Error("booking outcome poll failed", "error", err)
+			}
+		}
+	}
+}
+
+// RunOnce completes ended bookings, applies deposits for clinics that opted
+// in, and sends any digests that are due.
+func (p *BookingOutcomePoller) RunOnce(ctx context.Context) error {
+	now := p.now().UTC()
+	if p.leadsOnly {
+		p.sendDigests(ctx, now, make(map[string]*clinic.Config))
+		return nil
+	}
+	completed, err := p.store.CompleteEndedBookings(ctx, now, now.Add(-noShowReviewWindow), defaultAppointmentMinutes, bookingOutcomeBatchSize)
+	if err != nil {
+		return err
+	}
+	configs := make(map[string]*clinic.Config)
+	for _, booking := range completed {
+		cfg := p.clinicConfig(ctx, configs, booking.OrgID)
+		if cfg == nil || !cfg.AutoApplyDeposits {
+			continue
+		}
+		applied, err := p.store.ApplyLeadDeposits(ctx, booking.OrgID, booking.LeadID, booking.ScheduledFor, DepositAppliedBySystem)
+		if err != nil {
+			p.logger.Warn("failed to apply deposit for completed booking", "org_id", booking.OrgID, "booking_id", booking.ID, "error", err)
+			continue
+		}
+		if applied > 0 {
+			p.logger.Info("deposit applied for completed booking", "org_id", booking.OrgID, "booking_id", booking.ID, "deposits", applied)
+		}
+	}
+	p.sendDigests(ctx, now, configs)
+	return nil
+}
+
+// sendDigests sends each clinic with unapplied deposits or leads worth a
+// call its digest, once per local day.
+func (p *BookingOutcomePoller) sendDigests(ctx context.Context, now time.Time, configs map[string]*clinic.Config) {
+	if p.notifier == nil || p.marker == nil {
+		return
+	}
+	unapplied := make(map[string]int)
+	if !p.leadsOnly {
+		summaries, err := p.store.UnappliedCompletedDeposits(ctx)
+		if err != nil {
+			p.logger.Warn("failed to load unapplied deposits", "error", err)
+		}
+		for _, sum := range summaries {
+			if sum.Appointments > 0 {
+				unapplied[sum.OrgID] = sum.Appointments
+			}
+		}
+	}
+	topLeads := p.rankLeads(ctx, now, configs)
+
+	orgIDs := make([]string, 0, len(unapplied)+len(topLeads))
+	for orgID := range unapplied {
+		orgIDs = append(orgIDs, orgID)
+	}
+	for orgID := range topLeads {
+		if _, ok := unapplied[orgID]; !ok {
+			orgIDs = append(orgIDs, orgID)
+		}
+	}
+	sort.Strings(orgIDs)
+
+	for _, orgID := range orgIDs {
+		local := now.In(clinicLocation(p.clinicConfig(ctx, configs, orgID)))
+		if local.Hour() < depositDigestHour {
+			continue
+		}
+		first, err := p.marker.MarkProcessed(ctx, depositDigestProvider, orgID+":"+local.Format("2006-01-02"))
+		if err != nil {
+			p.logger.Warn("failed to record daily digest", "org_id", orgID, "error", err)
+			continue
+		}
+		if !first {
+			continue
+		}
+		if err := p.notifier.NotifyDailyDigest(ctx, orgID, unapplied[orgID], topLeads[orgID]); err != nil {
+			p.logger.Warn("failed to send daily digest", "org_id", orgID, "error", err)
+		}
+	}
+}
+
+// rankLeads rescores each org's shortlisted leads as of now, since recency
+// and urgency fade after the worker last scored them, and keeps the best
+// topLeads with a score above 0.
+func (p *BookingOutcomePoller) rankLeads(ctx context.Context, now time.Time, configs map[string]*clinic.Config) map[string][]*leads.Lead {
+	if p.leads == nil {
+		return nil
+	}
+	// The digest covers every clinic, so it reads across orgs explicitly.
+	candidates, err := p.leads.DigestCandidates(ctx, tenancy.AllOrgs(), now.Add(-digestLeadWindow), p.topLeads*digestCandidateFactor)
+	if err != nil {
+		p.logger.Warn("failed to load digest leads", "error", err)
+		return nil
+	}
+	ranked := make(map[string][]*leads.Lead, len(candidates))
+	for orgID, list := range candidates {
+		cfg := p.clinicConfig(ctx, configs, orgID)
+		var scored []*leads.Lead
+		for _, lead := range list {
+			breakdown := leads.ComputeScore(lead, cfg.ServicePriceCents(lead.ScoreService()), now)
+			if breakdown.Total() <= 0 {
+				continue
+			}
+			lead.Score, lead.ScoreBreakdown = breakdown.Total(), &breakdown
+			scored = append(scored, lead)
+		}
+		sort.SliceStable(scored, func(i, j int) bool { return scored[i].Score > scored[j].Score })
+		if len(scored) > p.topLeads {
+			scored = scored[:p.topLeads]
+		}
+		if len(scored) > 0 {
+			ranked[orgID] = scored
+		}
+	}
+	return ranked
+}
+
+// clinicConfig loads and caches an org's config for one run.
+func (p *BookingOutcomePoller) clinicConfig(ctx context.Context, configs map[string]*clinic.Config, orgID string) *clinic.Config {
+	if cfg, ok := configs[orgID]; ok {
+		return cfg
+	}
+	var cfg *clinic.Config
+	if p.clinics != nil {
+		loaded, err := p.clinics.Get(ctx, orgID)
+		if err != nil {
+			p.logger.Warn("failed to load clinic config", "org_id", orgID, "error", err)
+		}
+		cfg = loaded
+	}
+	configs[orgID] = cfg
+	return cfg
+}
+
+func clinicLocation(cfg *clinic.Config) *time.Location {
+	if cfg == nil || strings.TrimSpace(cfg.Timezone) == "" {
+		return time.UTC
+	}
+	loc, err := time.LoadLocation(strings.TrimSpace(cfg.Timezone))
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
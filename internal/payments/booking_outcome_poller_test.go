@@ -0,0 +1,251 @@
+package payments
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pashagolub/pgxmock/v4"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/clinic"
+	"github.com/wolfman30/medspa-ai-platform/internal/leads"
+	"github.com/wolfman30/medspa-ai-platform/internal/tenancy"
+	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
+)
+
+type appliedDeposit struct {
+	orgID      string
+	leadID     uuid.UUID
+	paidBefore time.Time
+	appliedBy  string
+}
+
+type stubOutcomeStore struct {
+	endedBy   time.Time
+	completed []CompletedBooking
+	applied   []appliedDeposit
+	unapplied []UnappliedDepositSummary
+}
+
+func (s *stubOutcomeStore) CompleteEndedBookings(ctx context.Context, now, endedBy time.Time, defaultMinutes, limit int) ([]CompletedBooking, error) {
+	s.endedBy = endedBy
+	out := s.completed
+	s.completed = nil
+	return out, nil
+}
+
+func (s *stubOutcomeStore) ApplyLeadDeposits(ctx context.Context, orgID string, leadID uuid.UUID, paidBefore time.Time, appliedBy string) (int64, error) {
+	s.applied = append(s.applied, appliedDeposit{orgID, leadID, paidBefore, appliedBy})
+	return 1, nil
+}
+
+func (s *stubOutcomeStore) UnappliedCompletedDeposits(ctx context.Context) ([]UnappliedDepositSummary, error) {
+	return s.unapplied, nil
+}
+
+type stubClinicConfigs map[string]*clinic.Config
+
+func (s stubClinicConfigs) Get(ctx context.Context, orgID string) (*clinic.Config, error) {
+	return s[orgID], nil
+}
+
+type stubDigestNotifier struct {
+	sent  map[string]int
+	leads map[string][]*leads.Lead
+}
+
+func (s *stubDigestNotifier) NotifyDailyDigest(ctx context.Context, orgID string, appointments int, topLeads []*leads.Lead) error {
+	s.sent[orgID] = appointments
+	if s.leads != nil {
+		s.leads[orgID] = topLeads
+	}
+	return nil
+}
+
+type stubDigestLeads map[string][]*leads.Lead
+
+func (s stubDigestLeads) DigestCandidates(ctx context.Context, scope tenancy.OrgScope, since time.Time, perOrg int) (map[string][]*leads.Lead, error) {
+	return s, nil
+}
+
+type stubDigestMarker map[string]bool
+
+func (s stubDigestMarker) MarkProcessed(ctx context.Context, provider, eventID string) (bool, error) {
+	key := provider + "/" + eventID
+	if s[key] {
+		return false, nil
+	}
+	s[key] = true
+	return true, nil
+}
+
+func TestBookingOutcomePoller_AutoAppliesForOptedInClinics(t *testing.T) {
+	scheduled := time.Date(2026, 10, 16, 14, 0, 0, 0, time.UTC)
+	autoLead, manualLead := uuid.New(), uuid.New()
+	store := &stubOutcomeStore{completed: []CompletedBooking{
+		{ID: uuid.New(), OrgID: "org-auto", LeadID: autoLead, ScheduledFor: scheduled},
+		{ID: uuid.New(), OrgID: "org-manual", LeadID: manualLead, ScheduledFor: scheduled},
+	}}
+	clinics := stubClinicConfigs{
+		"org-auto":   {AutoApplyDeposits: true},
+		"org-manual": {},
+	}
+
+	now := time.Date(2026, 10, 17, 16, 0, 0, 0, time.UTC)
+	poller := NewBookingOutcomePoller(store, clinics, nil, nil, logging.Default())
+	poller.now = func() time.Time { return now }
+	if err := poller.RunOnce(context.Background()); err != nil {
+		t.Fatalf("RunOnce: %v", err)
+	}
+
+	// Staff get a day after the appointment to mark a no-show.
+	if !store.endedBy.Equal(now.Add(-24 * time.Hour)) {
+		t.Fatalf("endedBy = %v, want a day before now", store.endedBy)
+	}
+	want := []appliedDeposit{{"org-auto", autoLead, scheduled, DepositAppliedBySystem}}
+	if len(store.applied) != 1 || store.applied[0] != want[0] {
+		t.Fatalf("applied = %+v, want %+v", store.applied, want)
+	}
+}
+
+func TestBookingOutcomePoller_DigestOncePerClinicDay(t *testing.T) {
+	store := &stubOutcomeStore{unapplied: []UnappliedDepositSummary{
+		{OrgID: "org-ny", Appointments: 3},
+		{OrgID: "org-la", Appointments: 1},
+	}}
+	clinics := stubClinicConfigs{
+		"org-ny": {Timezone: "America/New_York"},
+		"org-la": {Timezone: "America/Los_Angeles"},
+	}
+	notifier := &stubDigestNotifier{sent: map[string]int{}}
+	marker := stubDigestMarker{}
+	poller := NewBookingOutcomePoller(store, clinics, notifier, marker, logging.Default())
+
+	// 12:30 UTC is 8:30 AM in New York but 5:30 AM in Los Angeles.
+	poller.now = func() time.Time { return time.Date(2026, 10, 17, 12, 30, 0, 0, time.UTC) }
+	if err := poller.RunOnce(context.Background()); err != nil {
+		t.Fatalf("RunOnce: %v", err)
+	}
+	if len(notifier.sent) != 1 || notifier.sent["org-ny"] != 3 {
+		t.Fatalf("digests = %v, want only org-ny with 3", notifier.sent)
+	}
+
+	// Later polls the same day send Los Angeles its digest and don't repeat New York's.
+	delete(notifier.sent, "org-ny")
+	poller.now = func() time.Time { return time.Date(2026, 10, 17, 16, 0, 0, 0, time.UTC) }
+	if err := poller.RunOnce(context.Background()); err != nil {
+		t.Fatalf("RunOnce: %v", err)
+	}
+	if len(notifier.sent) != 1 || notifier.sent["org-la"] != 1 {
+		t.Fatalf("digests = %v, want only org-la with 1", notifier.sent)
+	}
+}
+
+func TestBookingOutcomePoller_DigestListsTopLeads(t *testing.T) {
+	now := time.Date(2026, 10, 17, 15, 0, 0, 0, time.UTC)
+	at := func(ago time.Duration) *time.Time { t := now.Add(-ago); return &t }
+	hot := &leads.Lead{ID: "hot", Name: "Sarah Johnson", ServiceInterest: "Filler", DepositStatus: "pending", LastEngagedAt: at(30 * time.Minute), UrgencyExpressedAt: at(30 * time.Minute)}
+	// Scored 90 when the patient was texting; six days on it has faded.
+	faded := &leads.Lead{ID: "faded", Name: "Mia Lee", ServiceInterest: "Filler", PatientType: "new", LastEngagedAt: at(6 * 24 * time.Hour), UrgencyExpressedAt: at(8 * 24 * time.Hour), Score: 90}
+	warm := &leads.Lead{ID: "warm", Name: "Ana Ruiz", ServiceInterest: "Filler", PatientType: "existing", PreferredDays: "weekdays", LastEngagedAt: at(2 * time.Hour)}
+	cold := &leads.Lead{ID: "cold", LastEngagedAt: at(10 * 24 * time.Hour)}
+	source := stubDigestLeads{"org-1": {faded, hot, warm, cold}}
+	clinics := stubClinicConfigs{"org-1": {ServicePriceText: map[string]string{"filler": "From $700 per syringe"}}}
+	notifier := &stubDigestNotifier{sent: map[string]int{}, leads: map[string][]*leads.Lead{}}
+
+	poller := NewBookingOutcomePoller(&stubOutcomeStore{}, clinics, notifier, stubDigestMarker{}, logging.Default()).WithTopLeads(source, 2)
+	poller.now = func() time.Time { return now }
+	if err := poller.RunOnce(context.Background()); err != nil {
+		t.Fatalf("RunOnce: %v", err)
+	}
+
+	got := notifier.leads["org-1"]
+	if _, ok := notifier.sent["org-1"]; !ok || len(got) != 2 {
+		t.Fatalf("expected a digest with two leads, got %+v", got)
+	}
+	// hot: 17 value + 10 qualification + 20 recency + 15 urgency + 15 deposit.
+	// warm: 17 value + 20 qualification + 15 recency.
+	// faded: 17 value + 15 qualification + 5 recency, below warm despite its stored 90.
+	if got[0].ID != "hot" || got[0].Score != 77 || got[1].ID != "warm" || got[1].Score != 52 {
+		t.Fatalf("top leads = %s %d, %s %d; want hot 77, warm 52", got[0].ID, got[0].Score, got[1].ID, got[1].Score)
+	}
+	if faded.Score != 37 {
+		t.Fatalf("faded lead rescored to %d, want 37", faded.Score)
+	}
+}
+
+func TestBookingOutcomePoller_LeadsDigestOnly(t *testing.T) {
+	now := time.Date(2026, 10, 17, 15, 0, 0, 0, time.UTC)
+	engaged := now.Add(-time.Hour)
+	store := &stubOutcomeStore{
+		completed: []CompletedBooking{{OrgID: "org-1", LeadID: uuid.New()}},
+		unapplied: []UnappliedDepositSummary{{OrgID: "org-2", Appointments: 3}},
+	}
+	clinics := stubClinicConfigs{"org-1": {AutoApplyDeposits: true}}
+	notifier := &stubDigestNotifier{sent: map[string]int{}, leads: map[string][]*leads.Lead{}}
+	source := stubDigestLeads{"org-1": {{ID: "lead-1", Name: "Sarah Johnson", LastEngagedAt: &engaged}}}
+
+	poller := NewBookingOutcomePoller(store, clinics, notifier, stubDigestMarker{}, logging.Default()).
+		WithTopLeads(source, 3).
+		WithLeadsDigestOnly()
+	poller.now = func() time.Time { return now }
+	if err := poller.RunOnce(context.Background()); err != nil {
+		t.Fatalf("RunOnce: %v", err)
+	}
+
+	if !store.endedBy.IsZero() || len(store.applied) != 0 {
+		t.Fatal("a leads-only digest must not complete bookings or apply deposits")
+	}
+	if _, ok := notifier.sent["org-2"]; ok {
+		t.Fatal("a leads-only digest must not report unapplied deposits")
+	}
+	if appointments, ok := notifier.sent["org-1"]; !ok || appointments != 0 || len(notifier.leads["org-1"]) != 1 {
+		t.Fatalf("expected org-1's top leads digest, got sent=%v leads=%v", notifier.sent, notifier.leads)
+	}
+}
+
+func TestDepositApplicationStore_Queries(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("pgxmock: %v", err)
+	}
+	defer mock.Close()
+	store := &DepositApplicationStore{db: mock}
+	ctx := context.Background()
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	bookingID, leadID := uuid.New(), uuid.New()
+
+	endedBy := now.Add(-24 * time.Hour)
+	mock.ExpectQuery(`UPDATE bookings SET completed_at = \$1.*status NOT IN \('cancelled', \$5\).*scheduled_for \+ make_interval\(mins => COALESCE\(duration_minutes, \$2\)\) <= \$4`).
+		WithArgs(now, 60, 200, endedBy, BookingStatusNoShow).
+		WillReturnRows(pgxmock.NewRows([]string{"id", "org_id", "lead_id", "scheduled_for"}).
+			AddRow(bookingID, "org-1", leadID, now.Add(-2*time.Hour)))
+	completed, err := store.CompleteEndedBookings(ctx, now, endedBy, 60, 200)
+	if err != nil || len(completed) != 1 || completed[0].LeadID != leadID {
+		t.Fatalf("completed = %+v, err = %v", completed, err)
+	}
+
+	mock.ExpectExec(`UPDATE payments SET applied_at = now\(\), applied_by = \$4.*status = 'succeeded' AND applied_at IS NULL`).
+		WithArgs("org-1", leadID, completed[0].ScheduledFor, DepositAppliedBySystem).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	if n, err := store.ApplyLeadDeposits(ctx, "org-1", leadID, completed[0].ScheduledFor, DepositAppliedBySystem); err != nil || n != 1 {
+		t.Fatalf("applied = %d, err = %v", n, err)
+	}
+
+	// Only completed appointments with a paid, unapplied deposit taken before
+	// the visit count toward the digest.
+	mock.ExpectQuery(`SELECT b.org_id, COUNT\(\*\)\s+FROM bookings b\s+WHERE b.completed_at IS NOT NULL.*p.status = 'succeeded' AND p.applied_at IS NULL\s+AND p.created_at <= b.scheduled_for.*GROUP BY b.org_id`).
+		WillReturnRows(pgxmock.NewRows([]string{"org_id", "count"}).AddRow("org-1", 3).AddRow("org-2", 1))
+	summaries, err := store.UnappliedCompletedDeposits(ctx)
+	if err != nil {
+		t.Fatalf("UnappliedCompletedDeposits: %v", err)
+	}
+	if len(summaries) != 2 || summaries[0] != (UnappliedDepositSummary{OrgID: "org-1", Appointments: 3}) {
+		t.Fatalf("summaries = %+v", summaries)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
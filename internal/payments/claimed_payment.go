@@ -0,0 +1,124 @@
+package payments
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/events"
+	"github.com/wolfman30/medspa-ai-platform/internal/tenancy"
+	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
+)
+
+// ClaimStatus is the outcome of checking a deposit a patient says they paid.
+type ClaimStatus string
+
+const (
+	// ClaimNoPendingDeposit means the lead has no deposit awaiting payment,
+	// so there is nothing to confirm.
+	ClaimNoPendingDeposit ClaimStatus = "no_pending_deposit"
+	// ClaimConfirmed means Square reports the deposit paid and it has been
+	// recorded through the normal payment-succeeded path.
+	ClaimConfirmed ClaimStatus = "confirmed"
+	// ClaimPending means Square doesn't show the deposit paid yet.
+	ClaimPending ClaimStatus = "pending"
+)
+
+type pendingDepositStore interface {
+	LatestPendingDeposit(ctx context.Context, scope tenancy.OrgScope, leadID uuid.UUID) (*PendingDeposit, error)
+}
+
+type squareOrderPoller interface {
+	OrderPayment(ctx context.Context, orgID, orderID string) (*SquarePayment, map[string]string, error)
+}
+
+// ClaimedPaymentChecker confirms a deposit with Square as soon as the patient
+// says they paid, rather than waiting on a webhook that can lag.
+type ClaimedPaymentChecker struct {
+	deposits pendingDepositStore
+	square   squareOrderPoller
+	webhooks webhookReplayer
+	logger   *logging.Logger
+}
+
+// NewClaimedPaymentChecker creates a checker. A payment Square confirms is
+// run through webhooks, so it is recorded exactly as the webhook would and
+// the webhook arriving later is a no-op.
+func NewClaimedPaymentChecker(deposits pendingDepositStore, square squareOrderPoller, webhooks webhookReplayer, logger *logging.Logger) *ClaimedPaymentChecker {
+	if logger == nil {
+		logger = logging.Default()
+	}
+	return &ClaimedPaymentChecker{
+		deposits: deposits,
+		square:   square,
+		webhooks: webhooks,
+		logger:   logger,
+	}
+}
+
+// CheckClaimedPayment polls Square for the lead's pending deposit and records
+// it when Square reports it paid.
+func (c *ClaimedPaymentChecker) CheckClaimedPayment(ctx context.Context, orgID, leadID string) (ClaimStatus, error) {
+	if _, err := uuid.Parse(orgID); err != nil {
+		return "", fmt.Errorf("payments: claimed payment: invalid org id: %w", err)
+	}
+	leadUUID, err := uuid.Parse(leadID)
+	if err != nil {
+		return "", fmt.Errorf("payments: claimed payment: invalid lead id: %w", err)
+	}
+	deposit, err := c.deposits.LatestPendingDeposit(ctx, tenancy.ForOrg(orgID), leadUUID)
+	if err != nil {
+		return "", err
+	}
+	if deposit == nil {
+		return ClaimNoPendingDeposit, nil
+	}
+	if deposit.OrderID == "" {
+		// Links sent before orders were recorded can only be confirmed by the
+		// webhook.
+		c.logger.Info("claimed payment: no square order recorded for deposit", "payment_id", deposit.ID, "org_id", orgID)
+		return ClaimPending, nil
+	}
+
+	sp, meta, err := c.square.OrderPayment(ctx, orgID, deposit.OrderID)
+	if err != nil {
+		return "", err
+	}
+	if sp == nil {
+		return ClaimPending, nil
+	}
+	if err := c.record(ctx, *sp, meta); err != nil {
+		return "", err
+	}
+	c.logger.Info("claimed payment confirmed with square", "payment_id", deposit.ID, "square_payment_id", sp.ID, "org_id", orgID)
+	return ClaimConfirmed, nil
+}
+
+// record runs the completed payment through the webhook handler, which
+// dedupes on the Square payment ID.
+func (c *ClaimedPaymentChecker) record(ctx context.Context, sp SquarePayment, meta map[string]string) error {
+	var evt squarePaymentEvent
+	evt.EventID = "claim:" + sp.ID
+	evt.Type = "payment.updated"
+	evt.CreatedAt = sp.CreatedAt
+	if evt.CreatedAt.IsZero() {
+		evt.CreatedAt = time.Now().UTC()
+	}
+	evt.Data.Object.Payment.ID = sp.ID
+	evt.Data.Object.Payment.Status = "COMPLETED"
+	evt.Data.Object.Payment.OrderID = sp.OrderID
+	evt.Data.Object.Payment.AmountMoney = sp.AmountMoney
+	evt.Data.Object.Payment.Metadata = meta
+	payload, err := json.Marshal(evt)
+	if err != nil {
+		return fmt.Errorf("payments: claimed payment: marshal event: %w", err)
+	}
+	capture := c.webhooks.Replay(ctx, payload, false)
+	if outcome, detail := capture.Outcome(); outcome != events.WebhookOutcomeProcessed {
+		return fmt.Errorf("payments: claimed payment: replay %s", detail)
+	}
+	return nil
+}
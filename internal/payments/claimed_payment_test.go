@@ -0,0 +1,156 @@
+package payments
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/leads"
+	"github.com/wolfman30/medspa-ai-platform/internal/tenancy"
+	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
+)
+
+type stubPendingDeposits struct {
+	deposit *PendingDeposit
+}
+
+func (s *stubPendingDeposits) LatestPendingDeposit(ctx context.Context, scope tenancy.OrgScope, leadID uuid.UUID) (*PendingDeposit, error) {
+	return s.deposit, nil
+}
+
+// newSquareOrderServer serves one order, paid by paymentID with the given
+// status, or unpaid when paymentID is empty.
+func newSquareOrderServer(t *testing.T, orderID, paymentID, status string, meta map[string]string) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v2/orders/"+orderID, func(w http.ResponseWriter, r *http.Request) {
+		order := map[string]any{"id": orderID, "metadata": meta}
+		if paymentID != "" {
+			order["tenders"] = []map[string]any{{"id": paymentID, "payment_id": paymentID}}
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"order": order})
+	})
+	mux.HandleFunc("/v2/payments/"+paymentID, func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]any{"payment": map[string]any{
+			"id":           paymentID,
+			"status":       status,
+			"order_id":     orderID,
+			"amount_money": map[string]any{"amount": 5000, "currency": "USD"},
+			"created_at":   "2026-10-17T15:04:05Z",
+		}})
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+type claimFixture struct {
+	checker   *ClaimedPaymentChecker
+	webhooks  *SquareWebhookHandler
+	outbox    *stubOutboxWriter
+	orgID     string
+	leadID    string
+	depositID uuid.UUID
+	meta      map[string]string
+}
+
+func newClaimFixture(t *testing.T, paymentID, status string) *claimFixture {
+	t.Helper()
+	orgID, leadID, depositID := uuid.New().String(), uuid.New().String(), uuid.New()
+	meta := map[string]string{"org_id": orgID, "lead_id": leadID, "booking_intent_id": depositID.String()}
+	srv := newSquareOrderServer(t, "order-1", paymentID, status, meta)
+	square := NewSquareCheckoutService("token", "loc-1", "", "", logging.Default()).WithBaseURL(srv.URL)
+
+	outbox := &stubOutboxWriter{}
+	leadsRepo := &stubLeadRepo{lead: &leads.Lead{ID: leadID, OrgID: orgID, Phone: "+15550000000"}}
+	webhooks := NewSquareWebhookHandler("secret", &stubPaymentStore{}, leadsRepo, &stubProcessedTracker{}, outbox, nil, nil, logging.Default())
+	deposits := &stubPendingDeposits{deposit: &PendingDeposit{ID: depositID, AmountCents: 5000, OrderID: "order-1"}}
+	return &claimFixture{
+		checker:   NewClaimedPaymentChecker(deposits, square, webhooks, logging.Default()),
+		webhooks:  webhooks,
+		outbox:    outbox,
+		orgID:     orgID,
+		leadID:    leadID,
+		depositID: depositID,
+		meta:      meta,
+	}
+}
+
+func TestClaimedPayment_ConfirmedOnPoll(t *testing.T) {
+	f := newClaimFixture(t, "pay-1", "COMPLETED")
+
+	status, err := f.checker.CheckClaimedPayment(context.Background(), f.orgID, f.leadID)
+	if err != nil {
+		t.Fatalf("check: %v", err)
+	}
+	if status != ClaimConfirmed {
+		t.Fatalf("status = %s, want confirmed", status)
+	}
+	if len(f.outbox.inserted) != 1 {
+		t.Fatalf("expected one payment_succeeded event, got %d", len(f.outbox.inserted))
+	}
+	evt := f.outbox.inserted[0]
+	if evt.ProviderRef != "pay-1" || evt.BookingIntentID != f.depositID.String() || evt.AmountCents != 5000 {
+		t.Fatalf("unexpected payment event: %+v", evt)
+	}
+}
+
+func TestClaimedPayment_StillPending(t *testing.T) {
+	for name, f := range map[string]*claimFixture{
+		"unpaid order":       newClaimFixture(t, "", ""),
+		"payment processing": newClaimFixture(t, "pay-1", "APPROVED"),
+	} {
+		status, err := f.checker.CheckClaimedPayment(context.Background(), f.orgID, f.leadID)
+		if err != nil {
+			t.Fatalf("%s: check: %v", name, err)
+		}
+		if status != ClaimPending {
+			t.Fatalf("%s: status = %s, want pending", name, status)
+		}
+		if len(f.outbox.inserted) != 0 {
+			t.Fatalf("%s: expected no payment event, got %d", name, len(f.outbox.inserted))
+		}
+	}
+
+	noOrder := newClaimFixture(t, "pay-1", "COMPLETED")
+	noOrder.checker.deposits = &stubPendingDeposits{deposit: &PendingDeposit{ID: noOrder.depositID}}
+	if status, err := noOrder.checker.CheckClaimedPayment(context.Background(), noOrder.orgID, noOrder.leadID); err != nil || status != ClaimPending {
+		t.Fatalf("deposit without an order = %s, %v; want pending", status, err)
+	}
+
+	none := newClaimFixture(t, "pay-1", "COMPLETED")
+	none.checker.deposits = &stubPendingDeposits{}
+	if status, err := none.checker.CheckClaimedPayment(context.Background(), none.orgID, none.leadID); err != nil || status != ClaimNoPendingDeposit {
+		t.Fatalf("no pending deposit = %s, %v; want no_pending_deposit", status, err)
+	}
+}
+
+func TestClaimedPayment_LateWebhookIsNoOp(t *testing.T) {
+	f := newClaimFixture(t, "pay-1", "COMPLETED")
+	if status, err := f.checker.CheckClaimedPayment(context.Background(), f.orgID, f.leadID); err != nil || status != ClaimConfirmed {
+		t.Fatalf("check = %s, %v", status, err)
+	}
+
+	body := buildSquarePayload(t, "evt-late", "pay-1", "COMPLETED", f.meta)
+	req := httptest.NewRequest(http.MethodPost, "http://example.com/webhooks/square", bytes.NewReader(body))
+	req.Host = "example.com"
+	sign(req, "secret", body)
+	rr := httptest.NewRecorder()
+	f.webhooks.Handle(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("late webhook = %d, want 200", rr.Code)
+	}
+
+	// A second claim before the row flips to succeeded is also absorbed.
+	if status, err := f.checker.CheckClaimedPayment(context.Background(), f.orgID, f.leadID); err != nil || status != ClaimConfirmed {
+		t.Fatalf("repeat check = %s, %v", status, err)
+	}
+	if len(f.outbox.inserted) != 1 {
+		t.Fatalf("expected the payment recorded once, got %d events", len(f.outbox.inserted))
+	}
+}
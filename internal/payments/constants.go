@@ -11,3 +11,10 @@ const (
 	// account label displayed in the admin UI.
 	MaxOAuthLabelLen = 180
 )
+
+// Payment statuses written by provider webhooks.
+const (
+	PaymentStatusSucceeded = "succeeded"
+	PaymentStatusFailed    = "failed"
+	PaymentStatusDisputed  = "disputed"
+)
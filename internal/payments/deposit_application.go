@@ -0,0 +1,154 @@
+package payments
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// DepositAppliedBySystem is recorded as applied_by when a deposit is credited
+// automatically after its appointment completes.
+const DepositAppliedBySystem = "system:booking_outcome"
+
+// CompletedBooking is a booking the outcome poller has just marked completed.
+type CompletedBooking struct {
+	ID           uuid.UUID
+	OrgID        string
+	LeadID       uuid.UUID
+	ScheduledFor time.Time
+}
+
+// UnappliedDepositSummary counts one clinic's completed appointments whose
+// paid deposit hasn't been credited toward the service yet.
+type UnappliedDepositSummary struct {
+	OrgID        string
+	Appointments int
+}
+
+type depositApplicationDB interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+// DepositApplicationStore tracks whether paid deposits have been applied to
+// the patient's bill and which appointments have completed.
+type DepositApplicationStore struct {
+	db depositApplicationDB
+}
+
+// NewDepositApplicationStore creates a Postgres-backed deposit application store.
+func NewDepositApplicationStore(pool *pgxpool.Pool) *DepositApplicationStore {
+	if pool == nil {
+		return nil
+	}
+	return &DepositApplicationStore{db: pool}
+}
+
+// BookingStatusNoShow marks a booking the patient didn't attend. Its deposit
+// is never applied automatically; staff decide whether to keep or refund it.
+const BookingStatusNoShow = "no_show"
+
+// CompleteEndedBookings marks bookings that ended at or before endedBy as
+// completed at now and returns them. Cancelled bookings and no-shows are
+// skipped. Bookings without a duration are assumed to last defaultMinutes.
+func (s *DepositApplicationStore) CompleteEndedBookings(ctx context.Context, now, endedBy time.Time, defaultMinutes, limit int) ([]CompletedBooking, error) {
+	rows, err := s.db.Query(ctx, `
+		UPDATE bookings SET completed_at = $1
+		WHERE id IN (
+			SELECT id FROM bookings
+			WHERE completed_at IS NULL
+			  AND status NOT IN ('cancelled', $5)
+			  AND scheduled_for IS NOT NULL
+			  AND lead_id IS NOT NULL
+			  AND scheduled_for + make_interval(mins => COALESCE(duration_minutes, $2)) <= $4
+			ORDER BY scheduled_for
+			LIMIT $3
+		)
+		RETURNING id, org_id, lead_id, scheduled_for`, now, defaultMinutes, limit, endedBy, BookingStatusNoShow)
+	if err != nil {
+		return nil, fmt.Errorf("payments: complete ended bookings: %w", err)
+	}
+	defer rows.Close()
+
+	var out []CompletedBooking
+	for rows.Next() {
+		var b CompletedBooking
+		if err := rows.Scan(&b.ID, &b.OrgID, &b.LeadID, &b.ScheduledFor); err != nil {
+			return nil, fmt.Errorf("payments: scan completed booking: %w", err)
+		}
+		out = append(out, b)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("payments: complete ended bookings: %w", err)
+	}
+	return out, nil
+}
+
+// ApplyLeadDeposits marks the lead's paid, unapplied deposits taken before
+// paidBefore as applied and returns how many were updated.
+func (s *DepositApplicationStore) ApplyLeadDeposits(ctx context.Context, orgID string, leadID uuid.UUID, paidBefore time.Time, appliedBy string) (int64, error) {
+	tag, err := s.db.Exec(ctx, `
+		UPDATE payments SET applied_at = now(), applied_by = $4
+		WHERE org_id = $1 AND lead_id = $2
+		  AND status = 'succeeded' AND applied_at IS NULL
+		  AND created_at <= $3`, orgID, leadID, paidBefore, appliedBy)
+	if err != nil {
+		return 0, fmt.Errorf("payments: apply lead deposits: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
+
+// UnappliedDepositCents returns the total of the lead's paid deposits that
+// haven't been applied yet, or zero when there are none.
+func (s *DepositApplicationStore) UnappliedDepositCents(ctx context.Context, orgID uuid.UUID, leadID uuid.UUID) (int, error) {
+	var cents int
+	err := s.db.QueryRow(ctx, `
+		SELECT COALESCE(SUM(amount_cents), 0)::int FROM payments
+		WHERE org_id = $1 AND lead_id = $2
+		  AND status = 'succeeded' AND applied_at IS NULL`, orgID.String(), leadID).Scan(&cents)
+	if err != nil {
+		return 0, fmt.Errorf("payments: unapplied deposit total: %w", err)
+	}
+	return cents, nil
+}
+
+// UnappliedCompletedDeposits groups completed appointments that still have a
+// paid deposit waiting to be applied, by clinic. A deposit counts toward an
+// appointment when it was paid before the appointment started.
+func (s *DepositApplicationStore) UnappliedCompletedDeposits(ctx context.Context) ([]UnappliedDepositSummary, error) {
+	rows, err := s.db.Query(ctx, `
+		SELECT b.org_id, COUNT(*)
+		FROM bookings b
+		WHERE b.completed_at IS NOT NULL
+		  AND EXISTS (
+			SELECT 1 FROM payments p
+			WHERE p.org_id = b.org_id AND p.lead_id = b.lead_id
+			  AND p.status = 'succeeded' AND p.applied_at IS NULL
+			  AND p.created_at <= b.scheduled_for
+		  )
+		GROUP BY b.org_id
+		ORDER BY b.org_id`)
+	if err != nil {
+		return nil, fmt.Errorf("payments: unapplied completed deposits: %w", err)
+	}
+	defer rows.Close()
+
+	var out []UnappliedDepositSummary
+	for rows.Next() {
+		var sum UnappliedDepositSummary
+		if err := rows.Scan(&sum.OrgID, &sum.Appointments); err != nil {
+			return nil, fmt.Errorf("payments: scan unapplied deposits: %w", err)
+		}
+		out = append(out, sum)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("payments: unapplied completed deposits: %w", err)
+	}
+	return out, nil
+}
@@ -13,6 +13,7 @@ import (
 	"github.com/wolfman30/medspa-ai-platform/internal/events"
 	"github.com/wolfman30/medspa-ai-platform/internal/leads"
 	paymentsql "github.com/wolfman30/medspa-ai-platform/internal/payments/sqlc"
+	"github.com/wolfman30/medspa-ai-platform/internal/tenancy"
 	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
 )
 
@@ -182,11 +183,11 @@ func (h *FakePaymentsHandler) completePayment(ctx context.Context, paymentID uui
 	}
 	leadID := leadUUID.String()
 
-	lead, err := h.leads.GetByID(ctx, updated.OrgID, leadID)
+	lead, err := h.leads.GetByID(ctx, tenancy.ForOrg(updated.OrgID), leadID)
 	if err != nil {
 		return fmt.Errorf("payments: fake lead lookup: %w", err)
 	}
-	if err := h.leads.UpdateDepositStatus(ctx, leadID, "paid", "priority"); err != nil {
+	if err := h.leads.UpdateDepositStatus(ctx, tenancy.ForOrg(updated.OrgID), leadID, "paid", "priority"); err != nil {
 		h.logger.Warn("payments: failed to update lead deposit status", "error", err, "org_id", updated.OrgID, "lead_id", leadID)
 	}
 
@@ -205,7 +206,7 @@ func (h *FakePaymentsHandler) completePayment(ctx context.Context, paymentID uui
 		ProviderRef:     providerRef,
 		AmountCents:     int64(updated.AmountCents),
 		OccurredAt:      time.Now().UTC(),
-		LeadPhone:       lead.Phone,
+		LeadPhone:       leads.CanonicalPhone(lead.Phone),
 		LeadName:        lead.Name,
 		ScheduledFor:    scheduledFor,
 	}
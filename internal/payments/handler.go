@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/wolfman30/medspa-ai-platform/internal/http/apierror"
 	paymentsql "github.com/wolfman30/medspa-ai-platform/internal/payments/sqlc"
 
 	"github.com/wolfman30/medspa-ai-platform/internal/leads"
@@ -69,19 +70,20 @@ func NewCheckoutHandler(leadsRepo leads.Repository, paymentsRepo paymentIntentCr
 }
 
 func (h *CheckoutHandler) CreateCheckout(w http.ResponseWriter, r *http.Request) {
-	orgID, ok := tenancy.OrgIDFromContext(r.Context())
-	if !ok {
-		http.Error(w, "missing org context", http.StatusBadRequest)
+	scope := tenancy.ScopeFromContext(r.Context())
+	if scope.OrgID() == "" {
+		apierror.Write(w, r, apierror.CodeValidationFailed, "missing org context")
 		return
 	}
+	orgID := scope.OrgID()
 
 	var req checkoutRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "invalid payload", http.StatusBadRequest)
+		apierror.Write(w, r, apierror.CodeValidationFailed, "invalid payload")
 		return
 	}
 	if req.LeadID == "" {
-		http.Error(w, "lead_id is required", http.StatusBadRequest)
+		apierror.Write(w, r, apierror.CodeValidationFailed, "lead_id is required")
 		return
 	}
 	if req.AmountCents <= 0 {
@@ -91,27 +93,27 @@ func (h *CheckoutHandler) CreateCheckout(w http.ResponseWriter, r *http.Request)
 	if req.ScheduledFor != "" {
 		parsed, err := time.Parse(time.RFC3339, req.ScheduledFor)
 		if err != nil {
-			http.Error(w, "invalid scheduled_for format", http.StatusBadRequest)
+			apierror.Write(w, r, apierror.CodeValidationFailed, "invalid scheduled_for format")
 			return
 		}
 		scheduledFor = &parsed
 	}
 
-	lead, err := h.leads.GetByID(r.Context(), orgID, req.LeadID)
+	lead, err := h.leads.GetByID(r.Context(), scope, req.LeadID)
 	if err != nil {
 		h.logger.Error("lead lookup failed", "error", err, "org_id", orgID, "lead_id", req.LeadID)
-		http.Error(w, "lead not found", http.StatusNotFound)
+		apierror.Write(w, r, apierror.CodeNotFound, "lead not found")
 		return
 	}
 
 	leadUUID, err := uuid.Parse(req.LeadID)
 	if err != nil {
-		http.Error(w, "invalid lead_id format", http.StatusBadRequest)
+		apierror.Write(w, r, apierror.CodeValidationFailed, "invalid lead_id format")
 		return
 	}
 	orgUUID, err := uuid.Parse(orgID)
 	if err != nil {
-		http.Error(w, "invalid org id", http.StatusBadRequest)
+		apierror.Write(w, r, apierror.CodeValidationFailed, "invalid org id")
 		return
 	}
 	bookingIntent := uuid.Nil
@@ -119,7 +121,7 @@ func (h *CheckoutHandler) CreateCheckout(w http.ResponseWriter, r *http.Request)
 		if parsed, err := uuid.Parse(req.BookingIntentID); err == nil {
 			bookingIntent = parsed
 		} else {
-			http.Error(w, "invalid booking_intent_id format", http.StatusBadRequest)
+			apierror.Write(w, r, apierror.CodeValidationFailed, "invalid booking_intent_id format")
 			return
 		}
 	}
@@ -127,10 +129,10 @@ func (h *CheckoutHandler) CreateCheckout(w http.ResponseWriter, r *http.Request)
 	intent, err := h.payments.CreateIntent(r.Context(), orgUUID, leadUUID, "square", bookingIntent, req.AmountCents, "deposit_pending", scheduledFor)
 	if err != nil {
 		h.logger.Error("failed to persist payment intent", "error", err)
-		http.Error(w, "failed to create payment intent", http.StatusInternalServerError)
+		apierror.WriteError(w, r, err, apierror.CodeInternal, "failed to create payment intent")
 		return
 	}
-	if err := h.leads.UpdateDepositStatus(r.Context(), req.LeadID, "pending", "normal"); err != nil {
+	if err := h.leads.UpdateDepositStatus(r.Context(), scope, req.LeadID, "pending", "normal"); err != nil {
 		h.logger.Warn("failed to update lead deposit status", "error", err, "org_id", orgID, "lead_id", req.LeadID)
 	}
 	var paymentID uuid.UUID
@@ -155,7 +157,7 @@ func (h *CheckoutHandler) CreateCheckout(w http.ResponseWriter, r *http.Request)
 	})
 	if err != nil {
 		h.logger.Error("square checkout failed", "error", err)
-		http.Error(w, "failed to create checkout session", http.StatusBadGateway)
+		apierror.WriteStatus(w, r, http.StatusBadGateway, apierror.CodeDependencyUnavailable, "failed to create checkout session", nil)
 		return
 	}
 
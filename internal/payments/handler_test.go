@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -12,6 +13,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5/pgtype"
 
+	"github.com/wolfman30/medspa-ai-platform/internal/http/apierror"
 	"github.com/wolfman30/medspa-ai-platform/internal/leads"
 	paymentsql "github.com/wolfman30/medspa-ai-platform/internal/payments/sqlc"
 	"github.com/wolfman30/medspa-ai-platform/internal/tenancy"
@@ -159,6 +161,35 @@ func TestCheckoutHandler_RejectsInvalidBookingIntentID(t *testing.T) {
 	}
 }
 
+func TestCheckoutHandler_ErrorEnvelope(t *testing.T) {
+	orgID := uuid.New()
+	leadID := uuid.New()
+	post := func(handler *CheckoutHandler) (*httptest.ResponseRecorder, apierror.Envelope) {
+		t.Helper()
+		body, _ := json.Marshal(map[string]any{"lead_id": leadID.String()})
+		req := httptest.NewRequest(http.MethodPost, "/payments/checkout", bytes.NewReader(body))
+		req = req.WithContext(setOrgID(req.Context(), orgID.String()))
+		rr := httptest.NewRecorder()
+		handler.CreateCheckout(rr, req)
+		var env apierror.Envelope
+		if err := json.NewDecoder(rr.Body).Decode(&env); err != nil {
+			t.Fatalf("decode error envelope: %v", err)
+		}
+		return rr, env
+	}
+
+	rr, env := post(NewCheckoutHandler(&stubLeadsRepo{err: leads.ErrLeadNotFound}, &stubPaymentRepo{}, &stubSquareCheckout{}, logging.Default(), 5000))
+	if rr.Code != http.StatusNotFound || env.Error.Code != apierror.CodeNotFound {
+		t.Fatalf("missing lead: status %d body %+v", rr.Code, env.Error)
+	}
+
+	lead := &leads.Lead{ID: leadID.String(), OrgID: orgID.String(), Phone: "+15550000000"}
+	rr, env = post(NewCheckoutHandler(&stubLeadsRepo{lead: lead}, &stubPaymentRepo{}, &stubSquareCheckout{err: errors.New("square down")}, logging.Default(), 5000))
+	if rr.Code != http.StatusBadGateway || env.Error.Code != apierror.CodeDependencyUnavailable {
+		t.Fatalf("square failure: status %d body %+v", rr.Code, env.Error)
+	}
+}
+
 // stubs
 
 type stubLeadsRepo struct {
@@ -170,7 +201,7 @@ func (s *stubLeadsRepo) Create(reqCtx context.Context, req *leads.CreateLeadRequ
 	return nil, nil
 }
 
-func (s *stubLeadsRepo) GetByID(ctx context.Context, orgID string, id string) (*leads.Lead, error) {
+func (s *stubLeadsRepo) GetByID(ctx context.Context, scope tenancy.OrgScope, id string) (*leads.Lead, error) {
 	if s.err != nil {
 		return nil, s.err
 	}
@@ -181,23 +212,27 @@ func (s *stubLeadsRepo) GetOrCreateByPhone(ctx context.Context, orgID string, ph
 	return s.lead, s.err
 }
 
-func (s *stubLeadsRepo) UpdateSchedulingPreferences(context.Context, string, leads.SchedulingPreferences) error {
+func (s *stubLeadsRepo) UpdateSchedulingPreferences(context.Context, tenancy.OrgScope, string, leads.SchedulingPreferences) error {
+	return nil
+}
+
+func (s *stubLeadsRepo) MergePreferences(context.Context, string, leads.SchedulingPreferences) error {
 	return nil
 }
 
-func (s *stubLeadsRepo) UpdateDepositStatus(context.Context, string, string, string) error {
+func (s *stubLeadsRepo) UpdateDepositStatus(context.Context, tenancy.OrgScope, string, string, string) error {
 	return nil
 }
 
-func (s *stubLeadsRepo) ListByOrg(context.Context, string, leads.ListLeadsFilter) ([]*leads.Lead, error) {
+func (s *stubLeadsRepo) ListByOrg(context.Context, tenancy.OrgScope, leads.ListLeadsFilter) ([]*leads.Lead, error) {
 	return nil, nil
 }
 
-func (s *stubLeadsRepo) UpdateSelectedAppointment(context.Context, string, leads.SelectedAppointment) error {
+func (s *stubLeadsRepo) UpdateSelectedAppointment(context.Context, tenancy.OrgScope, string, leads.SelectedAppointment) error {
 	return nil
 }
 
-func (s *stubLeadsRepo) UpdateBookingSession(context.Context, string, leads.BookingSessionUpdate) error {
+func (s *stubLeadsRepo) UpdateBookingSession(context.Context, tenancy.OrgScope, string, leads.BookingSessionUpdate) error {
 	return nil
 }
 
@@ -205,7 +240,7 @@ func (s *stubLeadsRepo) GetByBookingSessionID(context.Context, string) (*leads.L
 	return nil, nil
 }
 
-func (s *stubLeadsRepo) UpdateEmail(context.Context, string, string) error {
+func (s *stubLeadsRepo) UpdateEmail(context.Context, tenancy.OrgScope, string, string) error {
 	return nil
 }
 
@@ -226,10 +261,14 @@ func (s *stubPaymentRepo) CreateIntent(ctx context.Context, orgID uuid.UUID, lea
 
 type stubSquareCheckout struct {
 	lastParams CheckoutParams
+	err        error
 }
 
 func (s *stubSquareCheckout) CreatePaymentLink(ctx context.Context, params CheckoutParams) (*CheckoutResponse, error) {
 	s.lastParams = params
+	if s.err != nil {
+		return nil, s.err
+	}
 	return &CheckoutResponse{
 		URL:        "http://example.com/checkout",
 		ProviderID: "sq_123",
@@ -82,9 +82,7 @@ func (s *SquareOAuthService) GetCredentials(ctx context.Context, orgID string) (
 	return &creds, nil
 }
 
-// GetExpiringCredentials retrieves all credentials expiring within the given duration.
-func (s *SquareOAuthService) GetExpiringCredentials(ctx context.Context, within time.Duration) ([]SquareCredentials, error) {
-	query := `
+const credentialsColumns = `
 		SELECT org_id, merchant_id, access_token, refresh_token, token_expires_at,
 		       COALESCE(location_id, '') as location_id,
 		       COALESCE(phone_number, '') as phone_number,
@@ -92,16 +90,38 @@ func (s *SquareOAuthService) GetExpiringCredentials(ctx context.Context, within
 		       last_refresh_attempt_at,
 		       last_refresh_failure_at,
 		       COALESCE(last_refresh_error, '') as last_refresh_error
-		FROM clinic_square_credentials
+		FROM clinic_square_credentials`
+
+// GetExpiringCredentials retrieves all credentials expiring within the given duration.
+func (s *SquareOAuthService) GetExpiringCredentials(ctx context.Context, within time.Duration) ([]SquareCredentials, error) {
+	query := credentialsColumns + `
 		WHERE token_expires_at < $1
 		ORDER BY token_expires_at ASC
 	`
-
 	expiryThreshold := time.Now().Add(within)
-	rows, err := s.db.Query(ctx, query, expiryThreshold)
+	results, err := s.queryCredentials(ctx, query, expiryThreshold)
 	if err != nil {
 		return nil, fmt.Errorf("query expiring credentials: %w", err)
 	}
+	return results, nil
+}
+
+// ListCredentials retrieves the credentials of every clinic with Square connected.
+func (s *SquareOAuthService) ListCredentials(ctx context.Context) ([]SquareCredentials, error) {
+	results, err := s.queryCredentials(ctx, credentialsColumns+`
+		ORDER BY org_id ASC
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("query credentials: %w", err)
+	}
+	return results, nil
+}
+
+func (s *SquareOAuthService) queryCredentials(ctx context.Context, query string, args ...any) ([]SquareCredentials, error) {
+	rows, err := s.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
 	defer rows.Close()
 
 	var results []SquareCredentials
@@ -131,8 +151,7 @@ func (s *SquareOAuthService) GetExpiringCredentials(ctx context.Context, within
 		creds.LastRefreshError = lastError
 		results = append(results, creds)
 	}
-
-	return results, nil
+	return results, rows.Err()
 }
 
 // RecordRefreshFailure stores the latest refresh failure metadata.
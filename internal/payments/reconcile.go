@@ -0,0 +1,383 @@
+package payments
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/redis/go-redis/v9"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/events"
+	paymentsql "github.com/wolfman30/medspa-ai-platform/internal/payments/sqlc"
+	"github.com/wolfman30/medspa-ai-platform/internal/tenancy"
+	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
+)
+
+// Reconciliation discrepancy kinds.
+const (
+	// DiscrepancyMissingLocally is a completed Square payment for one of our
+	// checkouts that our payments table never recorded, usually a missed webhook.
+	DiscrepancyMissingLocally = "missing_locally"
+	// DiscrepancyMissingInSquare is a payment we recorded as succeeded that
+	// Square has no completed payment for.
+	DiscrepancyMissingInSquare = "missing_in_square"
+	// DiscrepancyAmountMismatch is a payment both sides have with different amounts.
+	DiscrepancyAmountMismatch = "amount_mismatch"
+)
+
+// ReconciliationDiscrepancy is one difference between Square and our records.
+type ReconciliationDiscrepancy struct {
+	Kind              string `json:"kind"`
+	SquarePaymentID   string `json:"square_payment_id,omitempty"`
+	OrderID           string `json:"order_id,omitempty"`
+	PaymentID         string `json:"payment_id,omitempty"` // our payments row
+	LocalStatus       string `json:"local_status,omitempty"`
+	SquareAmountCents int64  `json:"square_amount_cents,omitempty"`
+	LocalAmountCents  int64  `json:"local_amount_cents,omitempty"`
+	Healed            bool   `json:"healed"`
+	HealError         string `json:"heal_error,omitempty"`
+}
+
+// ReconciliationReport is the outcome of reconciling one clinic's Square
+// payments against our payments table over a lookback window.
+type ReconciliationReport struct {
+	OrgID          string                      `json:"org_id"`
+	RanAt          time.Time                   `json:"ran_at"`
+	WindowStart    time.Time                   `json:"window_start"`
+	WindowEnd      time.Time                   `json:"window_end"`
+	SquarePayments int                         `json:"square_payments"` // completed Square payments from our checkouts
+	LocalPayments  int                         `json:"local_payments"`
+	Matched        int                         `json:"matched"`
+	Discrepancies  []ReconciliationDiscrepancy `json:"discrepancies"`
+	Error          string                      `json:"error,omitempty"`
+}
+
+type reconcileCredentialLister interface {
+	ListCredentials(ctx context.Context) ([]SquareCredentials, error)
+}
+
+type squarePaymentsAPI interface {
+	ListPayments(ctx context.Context, accessToken string, begin, end time.Time) ([]SquarePayment, error)
+	OrderMetadata(ctx context.Context, accessToken, orderID string) (map[string]string, error)
+}
+
+type reconcilePaymentStore interface {
+	ListByOrgProviderSince(ctx context.Context, scope tenancy.OrgScope, provider string, since time.Time) ([]paymentsql.Payment, error)
+	GetByID(ctx context.Context, id uuid.UUID) (*paymentsql.Payment, error)
+}
+
+// webhookReplayer runs a Square webhook payload through the normal webhook
+// processing; SquareWebhookHandler satisfies it.
+type webhookReplayer interface {
+	Replay(ctx context.Context, payload []byte, force bool) *events.ResponseCapture
+}
+
+type reconciliationReportSaver interface {
+	Save(ctx context.Context, report *ReconciliationReport) error
+}
+
+// Reconciler periodically compares each connected clinic's Square payments
+// with our payments table and heals payments whose webhook was missed.
+type Reconciler struct {
+	creds    reconcileCredentialLister
+	square   squarePaymentsAPI
+	payments reconcilePaymentStore
+	healer   webhookReplayer
+	reports  reconciliationReportSaver
+	logger   *logging.Logger
+	interval time.Duration
+	lookback time.Duration
+	now      func() time.Time
+}
+
+// NewReconciler creates a payment reconciliation job. healer may be nil to
+// report missed webhooks without healing them.
+func NewReconciler(creds reconcileCredentialLister, square squarePaymentsAPI, payments reconcilePaymentStore, healer webhookReplayer, reports reconciliationReportSaver, logger *logging.Logger) *Reconciler {
+	if logger == nil {
+		logger = logging.Default()
+	}
+	return &Reconciler{
+		creds:    creds,
+		square:   square,
+		payments: payments,
+		healer:   healer,
+		reports:  reports,
+		logger:   logger,
+		interval: 6 * time.Hour,
+		lookback: 72 * time.Hour,
+		now:      time.Now,
+	}
+}
+
+// WithInterval sets how often reconciliation runs.
+func (r *Reconciler) WithInterval(interval time.Duration) *Reconciler {
+	if interval > 0 {
+		r.interval = interval
+	}
+	return r
+}
+
+// WithLookback sets how far back each run compares payments.
+func (r *Reconciler) WithLookback(lookback time.Duration) *Reconciler {
+	if lookback > 0 {
+		r.lookback = lookback
+	}
+	return r
+}
+
+// Start runs reconciliation on the configured interval. Blocks until context is cancelled.
+func (r *Reconciler) Start(ctx context.Context) {
+	r.logger.Info("starting square payment reconciliation",
+		"interval", r.interval.String(),
+		"lookback", r.lookback.String(),
+	)
+
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			r.logger.Info("payment reconciliation shutting down")
+			return
+		case <-ticker.C:
+			if err := r.RunOnce(ctx); err != nil {
+				r.logger.Error("payment reconciliation failed", "error", err)
+			}
+		}
+	}
+}
+
+// RunOnce reconciles every connected clinic and saves a report for each.
+func (r *Reconciler) RunOnce(ctx context.Context) error {
+	creds, err := r.creds.ListCredentials(ctx)
+	if err != nil {
+		return fmt.Errorf("payments: reconcile: list credentials: %w", err)
+	}
+	for _, cred := range creds {
+		report := r.ReconcileOrg(ctx, cred)
+		if r.reports != nil {
+			if err := r.reports.Save(ctx, report); err != nil {
+				r.logger.Warn("failed to save reconciliation report", "org_id", cred.OrgID, "error", err)
+			}
+		}
+	}
+	return nil
+}
+
+// ReconcileOrg compares one clinic's completed Square payments over the
+// lookback window with our payment rows. Failures are recorded on the report
+// rather than returned so one clinic can't stop the others.
+func (r *Reconciler) ReconcileOrg(ctx context.Context, cred SquareCredentials) *ReconciliationReport {
+	end := r.now().UTC()
+	report := &ReconciliationReport{
+		OrgID:         cred.OrgID,
+		RanAt:         end,
+		WindowStart:   end.Add(-r.lookback),
+		WindowEnd:     end,
+		Discrepancies: []ReconciliationDiscrepancy{},
+	}
+
+	local, err := r.payments.ListByOrgProviderSince(ctx, tenancy.ForOrg(cred.OrgID), "square", report.WindowStart)
+	if err != nil {
+		report.Error = err.Error()
+		return report
+	}
+	report.LocalPayments = len(local)
+	byRef := make(map[string]paymentsql.Payment, len(local))
+	for _, row := range local {
+		if row.ProviderRef.Valid && row.ProviderRef.String != "" {
+			byRef[row.ProviderRef.String] = row
+		}
+	}
+
+	squarePayments, err := r.square.ListPayments(ctx, cred.AccessToken, report.WindowStart, report.WindowEnd)
+	if err != nil {
+		report.Error = err.Error()
+		return report
+	}
+
+	seen := make(map[string]bool)
+	for _, sp := range squarePayments {
+		if !strings.EqualFold(sp.Status, "COMPLETED") {
+			continue
+		}
+		if row, ok := byRef[sp.ID]; ok {
+			seen[sp.ID] = true
+			report.SquarePayments++
+			if int64(row.AmountCents) != sp.AmountMoney.Amount {
+				report.Discrepancies = append(report.Discrepancies, ReconciliationDiscrepancy{
+					Kind:              DiscrepancyAmountMismatch,
+					SquarePaymentID:   sp.ID,
+					OrderID:           sp.OrderID,
+					PaymentID:         pgUUIDString(row.ID),
+					LocalStatus:       row.Status,
+					SquareAmountCents: sp.AmountMoney.Amount,
+					LocalAmountCents:  int64(row.AmountCents),
+				})
+				continue
+			}
+			report.Matched++
+			continue
+		}
+		if d, ours := r.unrecordedPayment(ctx, cred, sp); ours {
+			report.SquarePayments++
+			report.Discrepancies = append(report.Discrepancies, d)
+		}
+	}
+
+	for _, row := range local {
+		if row.Status != PaymentStatusSucceeded || !row.ProviderRef.Valid || row.ProviderRef.String == "" {
+			continue
+		}
+		if seen[row.ProviderRef.String] {
+			continue
+		}
+		report.Discrepancies = append(report.Discrepancies, ReconciliationDiscrepancy{
+			Kind:             DiscrepancyMissingInSquare,
+			SquarePaymentID:  row.ProviderRef.String,
+			PaymentID:        pgUUIDString(row.ID),
+			LocalStatus:      row.Status,
+			LocalAmountCents: int64(row.AmountCents),
+		})
+	}
+
+	if len(report.Discrepancies) > 0 {
+		r.logger.Warn("square payment reconciliation found discrepancies",
+			"org_id", cred.OrgID,
+			"count", len(report.Discrepancies),
+		)
+	}
+	return report
+}
+
+// unrecordedPayment handles a completed Square payment no row references.
+// Only payments from our own checkouts (identified by the order metadata we
+// attach) count; in-person sales at the clinic are ignored. When our intent
+// row exists but never saw the success, the missed webhook is replayed.
+func (r *Reconciler) unrecordedPayment(ctx context.Context, cred SquareCredentials, sp SquarePayment) (ReconciliationDiscrepancy, bool) {
+	if sp.OrderID == "" {
+		return ReconciliationDiscrepancy{}, false
+	}
+	meta, err := r.square.OrderMetadata(ctx, cred.AccessToken, sp.OrderID)
+	if err != nil {
+		r.logger.Warn("reconcile: order metadata lookup failed", "org_id", cred.OrgID, "order_id", sp.OrderID, "error", err)
+		return ReconciliationDiscrepancy{}, false
+	}
+	if !strings.EqualFold(strings.TrimSpace(meta["org_id"]), cred.OrgID) {
+		return ReconciliationDiscrepancy{}, false
+	}
+	intentID, err := uuid.Parse(strings.TrimSpace(meta["booking_intent_id"]))
+	if err != nil {
+		return ReconciliationDiscrepancy{}, false
+	}
+
+	d := ReconciliationDiscrepancy{
+		Kind:              DiscrepancyMissingLocally,
+		SquarePaymentID:   sp.ID,
+		OrderID:           sp.OrderID,
+		PaymentID:         intentID.String(),
+		SquareAmountCents: sp.AmountMoney.Amount,
+	}
+	row, err := r.payments.GetByID(ctx, intentID)
+	if err != nil || row == nil {
+		d.HealError = "no payment intent row for booking_intent_id"
+		return d, true
+	}
+	d.LocalStatus = row.Status
+	d.LocalAmountCents = int64(row.AmountCents)
+	if row.Status == PaymentStatusSucceeded {
+		d.HealError = "intent already succeeded under another square payment"
+		return d, true
+	}
+	if r.healer == nil {
+		return d, true
+	}
+	if err := r.heal(ctx, sp, meta); err != nil {
+		d.HealError = err.Error()
+		r.logger.Error("reconcile: failed to heal missed square payment", "org_id", cred.OrgID, "square_payment_id", sp.ID, "error", err)
+		return d, true
+	}
+	d.Healed = true
+	r.logger.Info("reconcile: healed missed square payment", "org_id", cred.OrgID, "square_payment_id", sp.ID, "payment_id", d.PaymentID)
+	return d, true
+}
+
+// heal replays a synthesized payment-completed webhook so the usual path
+// marks the row succeeded and emits PaymentSucceeded through the outbox.
+func (r *Reconciler) heal(ctx context.Context, sp SquarePayment, meta map[string]string) error {
+	var evt squarePaymentEvent
+	evt.EventID = "reconcile:" + sp.ID
+	evt.Type = "payment.updated"
+	evt.CreatedAt = sp.CreatedAt
+	evt.Data.Object.Payment.ID = sp.ID
+	evt.Data.Object.Payment.Status = "COMPLETED"
+	evt.Data.Object.Payment.OrderID = sp.OrderID
+	evt.Data.Object.Payment.AmountMoney = sp.AmountMoney
+	evt.Data.Object.Payment.Metadata = meta
+	payload, err := json.Marshal(evt)
+	if err != nil {
+		return fmt.Errorf("payments: reconcile: marshal event: %w", err)
+	}
+	capture := r.healer.Replay(ctx, payload, false)
+	if outcome, detail := capture.Outcome(); outcome != events.WebhookOutcomeProcessed {
+		return fmt.Errorf("payments: reconcile: replay %s", detail)
+	}
+	return nil
+}
+
+func pgUUIDString(id pgtype.UUID) string {
+	if !id.Valid {
+		return ""
+	}
+	return uuid.UUID(id.Bytes).String()
+}
+
+const reconciliationKeyPrefix = "payments:reconciliation:"
+
+// ReconciliationStore keeps the latest reconciliation report per clinic in Redis.
+type ReconciliationStore struct {
+	redis redis.Cmdable
+	ttl   time.Duration
+}
+
+// NewReconciliationStore creates a report store.
+func NewReconciliationStore(rdb redis.Cmdable) *ReconciliationStore {
+	return &ReconciliationStore{redis: rdb, ttl: 30 * 24 * time.Hour}
+}
+
+// Save stores report as the clinic's latest.
+func (s *ReconciliationStore) Save(ctx context.Context, report *ReconciliationReport) error {
+	if report == nil {
+		return nil
+	}
+	data, err := json.Marshal(report)
+	if err != nil {
+		return fmt.Errorf("payments: marshal reconciliation report: %w", err)
+	}
+	if err := s.redis.Set(ctx, reconciliationKeyPrefix+report.OrgID, data, s.ttl).Err(); err != nil {
+		return fmt.Errorf("payments: save reconciliation report: %w", err)
+	}
+	return nil
+}
+
+// Latest returns the clinic's most recent report, or nil if none has run.
+func (s *ReconciliationStore) Latest(ctx context.Context, orgID string) (*ReconciliationReport, error) {
+	data, err := s.redis.Get(ctx, reconciliationKeyPrefix+orgID).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("payments: load reconciliation report: %w", err)
+	}
+	var report ReconciliationReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		return nil, fmt.Errorf("payments: decode reconciliation report: %w", err)
+	}
+	return &report, nil
+}
@@ -0,0 +1,53 @@
+package payments
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/http/apierror"
+	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
+)
+
+type reconciliationReportReader interface {
+	Latest(ctx context.Context, orgID string) (*ReconciliationReport, error)
+}
+
+// ReconciliationHandler serves the latest payment reconciliation report.
+type ReconciliationHandler struct {
+	reports reconciliationReportReader
+	logger  *logging.Logger
+}
+
+// NewReconciliationHandler creates a handler backed by the report store.
+func NewReconciliationHandler(reports reconciliationReportReader, logger *logging.Logger) *ReconciliationHandler {
+	if logger == nil {
+		logger = logging.Default()
+	}
+	return &ReconciliationHandler{reports: reports, logger: logger}
+}
+
+// GetReport returns the clinic's latest reconciliation report.
+// GET /admin/orgs/{orgID}/payments/reconciliation
+func (h *ReconciliationHandler) GetReport(w http.ResponseWriter, r *http.Request) {
+	orgID := strings.TrimSpace(chi.URLParam(r, "orgID"))
+	if orgID == "" {
+		apierror.Write(w, r, apierror.CodeValidationFailed, "org id required")
+		return
+	}
+	report, err := h.reports.Latest(r.Context(), orgID)
+	if err != nil {
+		h.logger.Error("load reconciliation report failed", "org_id", orgID, "error", err)
+		apierror.Write(w, r, apierror.CodeInternal, "server error")
+		return
+	}
+	if report == nil {
+		apierror.Write(w, r, apierror.CodeNotFound, "no reconciliation report yet")
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(report)
+}
@@ -0,0 +1,118 @@
+package payments
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
+)
+
+// SquarePayment is the subset of a Square Payments API payment that
+// reconciliation compares against our payment rows.
+type SquarePayment struct {
+	ID          string      `json:"id"`
+	Status      string      `json:"status"`
+	OrderID     string      `json:"order_id"`
+	AmountMoney squareMoney `json:"amount_money"`
+	CreatedAt   time.Time   `json:"created_at"`
+}
+
+// SquarePaymentsClient lists payments and order metadata from a clinic's
+// Square account using that clinic's OAuth access token.
+type SquarePaymentsClient struct {
+	baseURL    string
+	httpClient *http.Client
+	logger     *logging.Logger
+}
+
+// NewSquarePaymentsClient creates a client for the Square Payments and Orders APIs.
+func NewSquarePaymentsClient(baseURL string, logger *logging.Logger) *SquarePaymentsClient {
+	if logger == nil {
+		logger = logging.Default()
+	}
+	if baseURL == "" {
+		baseURL = "https://connect.squareup.com"
+	}
+	return &SquarePaymentsClient{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		httpClient: &http.Client{Timeout: 15 * time.Second},
+		logger:     logger,
+	}
+}
+
+// ListPayments returns every payment created in [begin, end), following
+// pagination cursors.
+func (c *SquarePaymentsClient) ListPayments(ctx context.Context, accessToken string, begin, end time.Time) ([]SquarePayment, error) {
+	var (
+		all    []SquarePayment
+		cursor string
+	)
+	for {
+		query := url.Values{}
+		query.Set("begin_time", begin.UTC().Format(time.RFC3339))
+		query.Set("end_time", end.UTC().Format(time.RFC3339))
+		query.Set("sort_order", "ASC")
+		if cursor != "" {
+			query.Set("cursor", cursor)
+		}
+		var page struct {
+			Payments []SquarePayment `json:"payments"`
+			Cursor   string          `json:"cursor"`
+		}
+		if err := c.get(ctx, accessToken, "/v2/payments?"+query.Encode(), &page); err != nil {
+			return nil, fmt.Errorf("payments: square list payments: %w", err)
+		}
+		all = append(all, page.Payments...)
+		if page.Cursor == "" {
+			return all, nil
+		}
+		cursor = page.Cursor
+	}
+}
+
+// OrderMetadata returns the metadata we attached to a checkout's order.
+func (c *SquarePaymentsClient) OrderMetadata(ctx context.Context, accessToken, orderID string) (map[string]string, error) {
+	var payload struct {
+		Order struct {
+			Metadata map[string]string `json:"metadata"`
+		} `json:"order"`
+	}
+	if err := c.get(ctx, accessToken, "/v2/orders/"+url.PathEscape(orderID), &payload); err != nil {
+		return nil, fmt.Errorf("payments: square order metadata: %w", err)
+	}
+	return payload.Order.Metadata, nil
+}
+
+func (c *SquarePaymentsClient) get(ctx context.Context, accessToken, path string, out any) error {
+	if strings.TrimSpace(accessToken) == "" {
+		return fmt.Errorf("missing access token")
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+path, nil)
+	if err != nil {
+		return fmt.Errorf("request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Square-Version", "2025-01-16")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("http: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusMultipleChoices {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return fmt.Errorf("status %d: %s", resp.StatusCode, formatSquareError(body))
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("decode: %w", err)
+	}
+	return nil
+}
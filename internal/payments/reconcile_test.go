@@ -0,0 +1,241 @@
+package payments
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/redis/go-redis/v9"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/leads"
+	paymentsql "github.com/wolfman30/medspa-ai-platform/internal/payments/sqlc"
+	"github.com/wolfman30/medspa-ai-platform/internal/tenancy"
+	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
+)
+
+type stubReconcileStore struct {
+	rows []paymentsql.Payment
+}
+
+func (s *stubReconcileStore) ListByOrgProviderSince(ctx context.Context, scope tenancy.OrgScope, provider string, since time.Time) ([]paymentsql.Payment, error) {
+	return s.rows, nil
+}
+
+func (s *stubReconcileStore) GetByID(ctx context.Context, id uuid.UUID) (*paymentsql.Payment, error) {
+	for i := range s.rows {
+		if s.rows[i].ID.Bytes == [16]byte(id) {
+			return &s.rows[i], nil
+		}
+	}
+	return nil, errors.New("no rows")
+}
+
+type stubCredentialLister []SquareCredentials
+
+func (s stubCredentialLister) ListCredentials(ctx context.Context) ([]SquareCredentials, error) {
+	return s, nil
+}
+
+func reconcileRow(id uuid.UUID, orgID, providerRef, status string, amount int32) paymentsql.Payment {
+	return paymentsql.Payment{
+		ID:          pgtype.UUID{Bytes: [16]byte(id), Valid: true},
+		OrgID:       orgID,
+		LeadID:      pgtype.UUID{Bytes: [16]byte(uuid.New()), Valid: true},
+		Provider:    "square",
+		ProviderRef: pgtype.Text{String: providerRef, Valid: providerRef != ""},
+		AmountCents: amount,
+		Status:      status,
+	}
+}
+
+// newSquareListServer stubs the Square List Payments (two pages) and Retrieve
+// Order APIs.
+func newSquareListServer(t *testing.T, orders map[string]map[string]string) *httptest.Server {
+	t.Helper()
+	payment := func(id, status, orderID string, amount int64) map[string]any {
+		return map[string]any{
+			"id":           id,
+			"status":       status,
+			"order_id":     orderID,
+			"amount_money": map[string]any{"amount": amount, "currency": "USD"},
+			"created_at":   "2026-10-16T15:04:05Z",
+		}
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer tok" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.URL.Path == "/v2/payments" && r.URL.Query().Get("cursor") == "":
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"payments": []any{
+					payment("sq-matched", "COMPLETED", "ord-matched", 5000),
+					payment("sq-amount", "COMPLETED", "ord-amount", 7500),
+				},
+				"cursor": "page-2",
+			})
+		case r.URL.Path == "/v2/payments":
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"payments": []any{
+					payment("sq-missed", "COMPLETED", "ord-missed", 5000),
+					payment("sq-walkin", "COMPLETED", "ord-walkin", 12000),
+					payment("sq-declined", "FAILED", "ord-declined", 5000),
+				},
+			})
+		case strings.HasPrefix(r.URL.Path, "/v2/orders/"):
+			id := strings.TrimPrefix(r.URL.Path, "/v2/orders/")
+			_ = json.NewEncoder(w).Encode(map[string]any{"order": map[string]any{"id": id, "metadata": orders[id]}})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestReconciler_ReportsDiscrepanciesAndHealsMissedWebhook(t *testing.T) {
+	orgID := uuid.New().String()
+	leadID := uuid.New().String()
+	missedIntent := uuid.New()
+	store := &stubReconcileStore{rows: []paymentsql.Payment{
+		reconcileRow(uuid.New(), orgID, "sq-matched", PaymentStatusSucceeded, 5000),
+		reconcileRow(uuid.New(), orgID, "sq-amount", PaymentStatusSucceeded, 5000),
+		reconcileRow(uuid.New(), orgID, "sq-gone", PaymentStatusSucceeded, 5000),
+		reconcileRow(missedIntent, orgID, "", "deposit_pending", 5000),
+	}}
+	srv := newSquareListServer(t, map[string]map[string]string{
+		"ord-missed": {
+			"org_id":            orgID,
+			"lead_id":           leadID,
+			"booking_intent_id": missedIntent.String(),
+		},
+	})
+
+	outbox := &stubOutboxWriter{}
+	payments := &stubPaymentStore{}
+	webhooks := NewSquareWebhookHandler("secret", payments, &stubLeadRepo{lead: &leads.Lead{ID: leadID, OrgID: orgID, Phone: "+15550000000"}}, &stubProcessedTracker{}, outbox, stubNumberResolver("+19998887777"), nil, logging.Default())
+
+	mr := miniredis.RunT(t)
+	reports := NewReconciliationStore(redis.NewClient(&redis.Options{Addr: mr.Addr()}))
+	reconciler := NewReconciler(
+		stubCredentialLister{{OrgID: orgID, AccessToken: "tok"}},
+		NewSquarePaymentsClient(srv.URL, logging.Default()),
+		store, webhooks, reports, logging.Default(),
+	)
+	if err := reconciler.RunOnce(context.Background()); err != nil {
+		t.Fatalf("RunOnce: %v", err)
+	}
+
+	report, err := reports.Latest(context.Background(), orgID)
+	if err != nil || report == nil {
+		t.Fatalf("expected a saved report, got %v %v", report, err)
+	}
+	if report.Error != "" {
+		t.Fatalf("unexpected report error: %s", report.Error)
+	}
+	if report.Matched != 1 || report.SquarePayments != 3 || report.LocalPayments != 4 {
+		t.Fatalf("unexpected counts: %+v", report)
+	}
+
+	byKind := map[string]ReconciliationDiscrepancy{}
+	for _, d := range report.Discrepancies {
+		byKind[d.Kind] = d
+	}
+	if len(report.Discrepancies) != 3 {
+		t.Fatalf("expected 3 discrepancies, got %+v", report.Discrepancies)
+	}
+	if d := byKind[DiscrepancyAmountMismatch]; d.SquarePaymentID != "sq-amount" || d.SquareAmountCents != 7500 || d.LocalAmountCents != 5000 {
+		t.Fatalf("unexpected amount mismatch: %+v", d)
+	}
+	if d := byKind[DiscrepancyMissingInSquare]; d.SquarePaymentID != "sq-gone" {
+		t.Fatalf("unexpected missing_in_square: %+v", d)
+	}
+	missed := byKind[DiscrepancyMissingLocally]
+	if missed.SquarePaymentID != "sq-missed" || missed.PaymentID != missedIntent.String() {
+		t.Fatalf("unexpected missing_locally: %+v", missed)
+	}
+	if !missed.Healed || missed.HealError != "" {
+		t.Fatalf("expected the missed webhook to be healed, got %+v", missed)
+	}
+
+	if len(payments.statuses) != 1 || payments.statuses[0] != PaymentStatusSucceeded {
+		t.Fatalf("expected the intent to be marked succeeded, got %v", payments.statuses)
+	}
+	if len(outbox.inserted) != 1 {
+		t.Fatalf("expected one PaymentSucceeded event, got %d", len(outbox.inserted))
+	}
+	evt := outbox.inserted[0]
+	if evt.ProviderRef != "sq-missed" || evt.BookingIntentID != missedIntent.String() || evt.AmountCents != 5000 {
+		t.Fatalf("unexpected healed event: %+v", evt)
+	}
+
+	// A second run must not emit the event again.
+	if err := reconciler.RunOnce(context.Background()); err != nil {
+		t.Fatalf("second RunOnce: %v", err)
+	}
+	if len(outbox.inserted) != 1 {
+		t.Fatalf("expected healing to be idempotent, got %d events", len(outbox.inserted))
+	}
+}
+
+func TestReconciler_ReportsWithoutHealer(t *testing.T) {
+	orgID := uuid.New().String()
+	missedIntent := uuid.New()
+	store := &stubReconcileStore{rows: []paymentsql.Payment{
+		reconcileRow(missedIntent, orgID, "", "deposit_pending", 5000),
+	}}
+	srv := newSquareListServer(t, map[string]map[string]string{
+		"ord-missed": {"org_id": orgID, "booking_intent_id": missedIntent.String()},
+	})
+	reconciler := NewReconciler(nil, NewSquarePaymentsClient(srv.URL, nil), store, nil, nil, nil)
+
+	report := reconciler.ReconcileOrg(context.Background(), SquareCredentials{OrgID: orgID, AccessToken: "tok"})
+	if len(report.Discrepancies) != 1 || report.Discrepancies[0].Kind != DiscrepancyMissingLocally || report.Discrepancies[0].Healed {
+		t.Fatalf("expected an unhealed missing_locally discrepancy, got %+v", report.Discrepancies)
+	}
+}
+
+func TestReconciliationHandler_GetReport(t *testing.T) {
+	mr := miniredis.RunT(t)
+	reports := NewReconciliationStore(redis.NewClient(&redis.Options{Addr: mr.Addr()}))
+	handler := NewReconciliationHandler(reports, logging.Default())
+	r := chi.NewRouter()
+	r.Get("/admin/orgs/{orgID}/payments/reconciliation", handler.GetReport)
+
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/admin/orgs/org-1/payments/reconciliation", nil))
+	if rr.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 before any run, got %d", rr.Code)
+	}
+
+	if err := reports.Save(context.Background(), &ReconciliationReport{
+		OrgID:         "org-1",
+		Matched:       2,
+		Discrepancies: []ReconciliationDiscrepancy{{Kind: DiscrepancyMissingInSquare, SquarePaymentID: "sq-1"}},
+	}); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	rr = httptest.NewRecorder()
+	r.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/admin/orgs/org-1/payments/reconciliation", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	var got ReconciliationReport
+	if err := json.Unmarshal(rr.Body.Bytes(), &got); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if got.Matched != 2 || len(got.Discrepancies) != 1 || got.Discrepancies[0].SquarePaymentID != "sq-1" {
+		t.Fatalf("unexpected report: %+v", got)
+	}
+}
@@ -2,11 +2,14 @@ package payments
 
 import (
 	"context"
+	"errors"
+	"html/template"
 	"net/http"
 	"strings"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/wolfman30/medspa-ai-platform/internal/clinic"
 	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
 )
 
@@ -15,34 +18,137 @@ type PaymentURLLookup interface {
 	GetPaymentByID(ctx context.Context, id pgtype.UUID) (interface{ GetProviderLink() string }, error)
 }
 
+// shortLinkClicker resolves a short link code and counts the visit.
+type shortLinkClicker interface {
+	Click(ctx context.Context, code string) (*ShortLink, error)
+}
+
+// legacyCheckoutURLLookup resolves codes issued before short links moved to
+// Postgres; those lived in Redis for 24 hours.
+type legacyCheckoutURLLookup interface {
+	GetCheckoutURLByShortCode(ctx context.Context, code string) (string, error)
+}
+
+// clinicConfigLookup names the clinic on the expired-link page.
+type clinicConfigLookup interface {
+	Get(ctx context.Context, orgID string) (*clinic.Config, error)
+}
+
 // RedirectHandler serves short payment URLs that redirect to the provider checkout page.
 type RedirectHandler struct {
-	repo   *Repository
-	logger *logging.Logger
+	links   shortLinkClicker
+	legacy  legacyCheckoutURLLookup
+	clinics clinicConfigLookup
+	logger  *logging.Logger
 }
 
-// NewRedirectHandler creates a handler for /pay/{id} short URLs.
-func NewRedirectHandler(repo *Repository, logger *logging.Logger) *RedirectHandler {
+// NewRedirectHandler creates a handler for /pay/{code} short URLs. legacy may
+// be nil once no Redis-issued codes remain live.
+func NewRedirectHandler(links *ShortLinkStore, legacy *Repository, logger *logging.Logger) *RedirectHandler {
 	if logger == nil {
 		logger = logging.Default()
 	}
-	return &RedirectHandler{repo: repo, logger: logger}
+	h := &RedirectHandler{logger: logger}
+	if links != nil {
+		h.links = links
+	}
+	if legacy != nil {
+		h.legacy = legacy
+	}
+	return h
+}
+
+// SetClinicStore lets the expired-link page name the clinic to text.
+func (h *RedirectHandler) SetClinicStore(store *clinic.Store) {
+	if store != nil {
+		h.clinics = store
+	}
 }
 
 // Handle looks up a payment by short code and redirects to the provider checkout URL.
 func (h *RedirectHandler) Handle(w http.ResponseWriter, r *http.Request) {
-	code := strings.TrimSpace(chi.URLParam(r, "code"))
+	h.ServeCode(w, r, chi.URLParam(r, "code"))
+}
+
+// ServeCode redirects a short link code. It backs both /pay/{code} and
+// bare /{code} paths on a branded pay domain.
+func (h *RedirectHandler) ServeCode(w http.ResponseWriter, r *http.Request, code string) {
+	code = strings.TrimSpace(code)
 	if code == "" {
 		http.Error(w, "not found", http.StatusNotFound)
 		return
 	}
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Referrer-Policy", "no-referrer")
 
-	url, err := h.repo.GetCheckoutURLByShortCode(r.Context(), code)
-	if err != nil || url == "" {
-		h.logger.Warn("payment redirect: not found", "code", code, "error", err)
-		http.Error(w, "not found", http.StatusNotFound)
-		return
+	if h.links != nil {
+		link, err := h.links.Click(r.Context(), code)
+		switch {
+		case err == nil:
+			http.Redirect(w, r, link.TargetURL, http.StatusFound)
+			return
+		case errors.Is(err, ErrShortLinkExpired):
+			h.logger.Info("payment redirect: link expired", "code", code, "org_id", link.OrgID)
+			h.renderExpired(w, r, link.OrgID)
+			return
+		case !errors.Is(err, ErrShortLinkNotFound):
+			h.logger.Error("payment redirect: lookup failed", "code", code, "error", err)
+			http.Error(w, "temporarily unavailable", http.StatusServiceUnavailable)
+			return
+		}
+	}
+
+	if h.legacy != nil {
+		url, err := h.legacy.GetCheckoutURLByShortCode(r.Context(), code)
+		if err == nil && url != "" {
+			http.Redirect(w, r, url, http.StatusFound)
+			return
+		}
+		if err != nil {
+			h.logger.Warn("payment redirect: legacy lookup failed", "code", code, "error", err)
+		}
 	}
 
-	http.Redirect(w, r, url, http.StatusFound)
+	h.logger.Warn("payment redirect: not found", "code", code)
+	http.Error(w, "not found", http.StatusNotFound)
+}
+
+var expiredLinkPage = template.Must(template.New("expired").Parse(`<!doctype html>
+<html>
+  <head>
+    <meta charset="utf-8" />
+    <meta name="viewport" content="width=device-width, initial-scale=1" />
+    <title>This link has expired</title>
+    <style>
+      body{font-family:system-ui,-apple-system,Segoe UI,Roboto,Ubuntu,Cantarell,Noto Sans,sans-serif;max-width:680px;margin:40px auto;padding:0 16px;}
+      .card{border:1px solid #e5e7eb;border-radius:12px;padding:18px;}
+      .muted{color:#6b7280;font-size:14px;}
+    </style>
+  </head>
+  <body>
+    <h1>This payment link has expired</h1>
+    <div class="card">
+      <p>No worries — just text {{if .Clinic}}{{.Clinic}}{{else}}the clinic{{end}}{{if .Phone}} at {{.Phone}}{{end}} and we'll send you a fresh link.</p>
+      <p class="muted">For your security, payment links only work for a limited time.</p>
+    </div>
+  </body>
+</html>`))
+
+// renderExpired shows a friendly page asking the patient to text the clinic.
+func (h *RedirectHandler) renderExpired(w http.ResponseWriter, r *http.Request, orgID string) {
+	var data struct{ Clinic, Phone string }
+	if h.clinics != nil && orgID != "" {
+		if cfg, err := h.clinics.Get(r.Context(), orgID); err == nil && cfg != nil {
+			data.Clinic = cfg.Name
+			data.Phone = cfg.SMSPhoneNumber
+			if data.Phone == "" {
+				data.Phone = cfg.Phone
+			}
+		}
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(http.StatusGone)
+	if err := expiredLinkPage.Execute(w, data); err != nil {
+		h.logger.Warn("payment redirect: render expired page failed", "error", err)
+	}
 }
@@ -14,10 +14,10 @@ import (
 	"github.com/redis/go-redis/v9"
 
 	paymentsql "github.com/wolfman30/medspa-ai-platform/internal/payments/sqlc"
+	"github.com/wolfman30/medspa-ai-platform/internal/tenancy"
 )
 
 const shortURLKeyPrefix = "pay:short:"
-const shortURLTTL = 24 * time.Hour
 
 // Repository persists payment intents and lifecycle transitions.
 type Repository struct {
@@ -49,12 +49,16 @@ func NewRepositoryWithQuerier(q paymentsql.Querier) *Repository {
 
 // HasOpenDeposit returns true if a deposit intent already exists for the lead/org in pending or succeeded state.
 // If DISABLE_PAYMENT_COOLDOWN=true, this always returns false to allow repeated testing.
-func (r *Repository) HasOpenDeposit(ctx context.Context, orgID uuid.UUID, leadID uuid.UUID) (bool, error) {
+func (r *Repository) HasOpenDeposit(ctx context.Context, scope tenancy.OrgScope, leadID uuid.UUID) (bool, error) {
 	if r.disableCooldown {
 		return false, nil
 	}
+	orgID, err := scope.SingleOrg()
+	if err != nil {
+		return false, fmt.Errorf("payments: check deposit by lead: %w", err)
+	}
 	arg := paymentsql.GetOpenDepositByOrgAndLeadParams{
-		OrgID:  orgID.String(),
+		OrgID:  orgID,
 		LeadID: toPGUUID(leadID),
 	}
 	payment, err := r.queries.GetOpenDepositByOrgAndLead(ctx, arg)
@@ -69,9 +73,13 @@ func (r *Repository) HasOpenDeposit(ctx context.Context, orgID uuid.UUID, leadID
 
 // OpenDepositStatus returns the status of the most recent pending or succeeded deposit within 72 hours.
 // It returns an empty string when no matching deposit exists.
-func (r *Repository) OpenDepositStatus(ctx context.Context, orgID uuid.UUID, leadID uuid.UUID) (string, error) {
+func (r *Repository) OpenDepositStatus(ctx context.Context, scope tenancy.OrgScope, leadID uuid.UUID) (string, error) {
+	orgID, err := scope.SingleOrg()
+	if err != nil {
+		return "", fmt.Errorf("payments: load open deposit: %w", err)
+	}
 	arg := paymentsql.GetOpenDepositByOrgAndLeadParams{
-		OrgID:  orgID.String(),
+		OrgID:  orgID,
 		LeadID: toPGUUID(leadID),
 	}
 	payment, err := r.queries.GetOpenDepositByOrgAndLead(ctx, arg)
@@ -159,6 +167,68 @@ func (r *Repository) GetByID(ctx context.Context, id uuid.UUID) (*paymentsql.Pay
 	return &row, nil
 }
 
+// ListByOrgProviderSince returns an org's payments for one provider created at
+// or after since, oldest first.
+func (r *Repository) ListByOrgProviderSince(ctx context.Context, scope tenancy.OrgScope, provider string, since time.Time) ([]paymentsql.Payment, error) {
+	orgID, err := scope.SingleOrg()
+	if err != nil {
+		return nil, fmt.Errorf("payments: list by org since: %w", err)
+	}
+	rows, err := r.queries.ListPaymentsByOrgProviderSince(ctx, paymentsql.ListPaymentsByOrgProviderSinceParams{
+		OrgID:     orgID,
+		Provider:  provider,
+		CreatedAt: pgtype.Timestamptz{Time: since, Valid: true},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("payments: list by org since: %w", err)
+	}
+	return rows, nil
+}
+
+// PendingDeposit is a deposit still awaiting payment.
+type PendingDeposit struct {
+	ID          uuid.UUID
+	AmountCents int32
+	// OrderID is the Square order behind the checkout link, if recorded.
+	OrderID string
+}
+
+// LatestPendingDeposit returns the lead's most recent deposit still awaiting
+// payment, or nil when there is none.
+func (r *Repository) LatestPendingDeposit(ctx context.Context, scope tenancy.OrgScope, leadID uuid.UUID) (*PendingDeposit, error) {
+	orgID, err := scope.SingleOrg()
+	if err != nil {
+		return nil, fmt.Errorf("payments: load pending deposit: %w", err)
+	}
+	row, err := r.queries.GetLatestPendingDepositByOrgAndLead(ctx, paymentsql.GetLatestPendingDepositByOrgAndLeadParams{
+		OrgID:  orgID,
+		LeadID: toPGUUID(leadID),
+	})
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("payments: load pending deposit: %w", err)
+	}
+	return &PendingDeposit{
+		ID:          uuid.UUID(row.ID.Bytes),
+		AmountCents: row.AmountCents,
+		OrderID:     row.OrderID.String,
+	}, nil
+}
+
+// SetCheckoutOrder records the Square order a deposit's checkout link created.
+func (r *Repository) SetCheckoutOrder(ctx context.Context, paymentID uuid.UUID, orderID string) error {
+	err := r.queries.UpsertPaymentCheckoutOrder(ctx, paymentsql.UpsertPaymentCheckoutOrderParams{
+		PaymentID: toPGUUID(paymentID),
+		OrderID:   orderID,
+	})
+	if err != nil {
+		return fmt.Errorf("payments: record checkout order: %w", err)
+	}
+	return nil
+}
+
 func toPGUUID(id uuid.UUID) pgtype.UUID {
 	if id == uuid.Nil {
 		return pgtype.UUID{}
@@ -169,18 +239,9 @@ func toPGUUID(id uuid.UUID) pgtype.UUID {
 	}
 }
 
-// SaveCheckoutURL stores a checkout URL keyed by a short code in Redis.
-// The short code is the first 8 characters of the payment UUID (no dashes).
-// URLs expire after 24 hours.
-func (r *Repository) SaveCheckoutURL(paymentID uuid.UUID, checkoutURL string) string {
-	code := ShortCodeFromUUID(paymentID)
-	if r.redis != nil {
-		_ = r.redis.Set(context.Background(), shortURLKeyPrefix+code, checkoutURL, shortURLTTL).Err()
-	}
-	return code
-}
-
-// GetCheckoutURLByShortCode returns the checkout URL for the given short code from Redis.
+// GetCheckoutURLByShortCode returns the checkout URL for a short code issued
+// before short links moved to Postgres (see ShortLinkStore). Those codes lived
+// in Redis for 24 hours.
 func (r *Repository) GetCheckoutURLByShortCode(ctx context.Context, code string) (string, error) {
 	if r.redis == nil {
 		return "", nil
@@ -192,15 +253,6 @@ func (r *Repository) GetCheckoutURLByShortCode(ctx context.Context, code string)
 	return val, err
 }
 
-// ShortCodeFromUUID returns the first 8 hex chars of a UUID (no dashes) for use as a short code.
-func ShortCodeFromUUID(id uuid.UUID) string {
-	s := strings.ReplaceAll(id.String(), "-", "")
-	if len(s) > 8 {
-		return s[:8]
-	}
-	return s
-}
-
 func toPGNullableTime(t *time.Time) pgtype.Timestamptz {
 	if t == nil {
 		return pgtype.Timestamptz{}
@@ -60,6 +60,18 @@ func (*stubPaymentQuerier) UpdatePaymentStatusByProviderRef(ctx context.Context,
 	return paymentsql.Payment{}, nil
 }
 
+func (*stubPaymentQuerier) ListPaymentsByOrgProviderSince(ctx context.Context, arg paymentsql.ListPaymentsByOrgProviderSinceParams) ([]paymentsql.Payment, error) {
+	return nil, nil
+}
+
+func (*stubPaymentQuerier) GetLatestPendingDepositByOrgAndLead(ctx context.Context, arg paymentsql.GetLatestPendingDepositByOrgAndLeadParams) (paymentsql.GetLatestPendingDepositByOrgAndLeadRow, error) {
+	return paymentsql.GetLatestPendingDepositByOrgAndLeadRow{}, nil
+}
+
+func (*stubPaymentQuerier) UpsertPaymentCheckoutOrder(ctx context.Context, arg paymentsql.UpsertPaymentCheckoutOrderParams) error {
+	return nil
+}
+
 func (*stubPaymentQuerier) GetOpenDepositByOrgAndLead(ctx context.Context, arg paymentsql.GetOpenDepositByOrgAndLeadParams) (paymentsql.Payment, error) {
 	return paymentsql.Payment{}, nil
 }
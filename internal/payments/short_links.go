@@ -0,0 +1,167 @@
+package payments
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const (
+	// shortLinkCodeLength gives 62^7 (~3.5 trillion) codes, enough that
+	// walking the space to find live checkout links isn't practical.
+	shortLinkCodeLength = 7
+	shortLinkAlphabet   = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789"
+	// DefaultShortLinkTTL is how long a payment short link stays valid.
+	DefaultShortLinkTTL = 72 * time.Hour
+	// shortLinkCreateAttempts bounds retries on the (vanishingly rare) code collision.
+	shortLinkCreateAttempts = 3
+)
+
+var (
+	// ErrShortLinkNotFound is returned for codes that were never issued.
+	ErrShortLinkNotFound = errors.New("payments: short link not found")
+	// ErrShortLinkExpired is returned for codes past their expiry.
+	ErrShortLinkExpired = errors.New("payments: short link expired")
+)
+
+// ShortLink maps an unguessable code to a checkout URL for one org.
+type ShortLink struct {
+	Code          string
+	OrgID         string
+	PaymentID     *uuid.UUID
+	TargetURL     string
+	ExpiresAt     time.Time
+	ClickCount    int
+	LastClickedAt *time.Time
+	CreatedAt     time.Time
+}
+
+type shortLinkDB interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+// ShortLinkStore persists short links in PostgreSQL.
+type ShortLinkStore struct {
+	db  shortLinkDB
+	ttl time.Duration
+}
+
+// NewShortLinkStore creates a Postgres-backed short link store.
+func NewShortLinkStore(pool *pgxpool.Pool) *ShortLinkStore {
+	if pool == nil {
+		panic("payments: pgx pool required")
+	}
+	return &ShortLinkStore{db: pool, ttl: DefaultShortLinkTTL}
+}
+
+// CreateShortLink issues a new code for targetURL, scoped to orgID and
+// optionally tied to a payment, and returns the code.
+func (s *ShortLinkStore) CreateShortLink(ctx context.Context, orgID string, paymentID uuid.UUID, targetURL string) (string, error) {
+	orgID = strings.TrimSpace(orgID)
+	targetURL = strings.TrimSpace(targetURL)
+	if orgID == "" || targetURL == "" {
+		return "", errors.New("payments: short link requires org and target url")
+	}
+	var payment *uuid.UUID
+	if paymentID != uuid.Nil {
+		payment = &paymentID
+	}
+	expiresAt := time.Now().UTC().Add(s.ttl)
+
+	for attempt := 0; attempt < shortLinkCreateAttempts; attempt++ {
+		code, err := newShortLinkCode()
+		if err != nil {
+			return "", fmt.Errorf("payments: generate short link code: %w", err)
+		}
+		_, err = s.db.Exec(ctx, `
+			INSERT INTO short_links (code, org_id, payment_id, target_url, expires_at)
+			VALUES ($1, $2, $3, $4, $5)
+		`, code, orgID, payment, targetURL, expiresAt)
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			continue
+		}
+		if err != nil {
+			return "", fmt.Errorf("payments: create short link: %w", err)
+		}
+		return code, nil
+	}
+	return "", errors.New("payments: create short link: too many code collisions")
+}
+
+// Click resolves a live code and counts the visit. Expired codes return the
+// link alongside ErrShortLinkExpired so callers can tell patients who to
+// contact; unknown codes return ErrShortLinkNotFound.
+func (s *ShortLinkStore) Click(ctx context.Context, code string) (*ShortLink, error) {
+	if !validShortLinkCode(code) {
+		return nil, ErrShortLinkNotFound
+	}
+	const columns = `code, org_id, payment_id, target_url, expires_at, click_count, last_clicked_at, created_at`
+	link, err := scanShortLink(s.db.QueryRow(ctx, `
+		UPDATE short_links
+		SET click_count = click_count + 1, last_clicked_at = NOW()
+		WHERE code = $1 AND expires_at > NOW()
+		RETURNING `+columns, code))
+	if err == nil {
+		return link, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("payments: click short link: %w", err)
+	}
+
+	link, err = scanShortLink(s.db.QueryRow(ctx, `SELECT `+columns+` FROM short_links WHERE code = $1`, code))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrShortLinkNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("payments: get short link: %w", err)
+	}
+	return link, ErrShortLinkExpired
+}
+
+func scanShortLink(row pgx.Row) (*ShortLink, error) {
+	var link ShortLink
+	if err := row.Scan(&link.Code, &link.OrgID, &link.PaymentID, &link.TargetURL, &link.ExpiresAt,
+		&link.ClickCount, &link.LastClickedAt, &link.CreatedAt); err != nil {
+		return nil, err
+	}
+	return &link, nil
+}
+
+// newShortLinkCode returns a random base62 code drawn from crypto/rand.
+func newShortLinkCode() (string, error) {
+	max := big.NewInt(int64(len(shortLinkAlphabet)))
+	var b strings.Builder
+	for i := 0; i < shortLinkCodeLength; i++ {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", err
+		}
+		b.WriteByte(shortLinkAlphabet[n.Int64()])
+	}
+	return b.String(), nil
+}
+
+// validShortLinkCode rejects anything that couldn't have been issued, so
+// junk paths never reach the database.
+func validShortLinkCode(code string) bool {
+	if len(code) < 6 || len(code) > 32 {
+		return false
+	}
+	for i := 0; i < len(code); i++ {
+		if !strings.ContainsRune(shortLinkAlphabet, rune(code[i])) {
+			return false
+		}
+	}
+	return true
+}
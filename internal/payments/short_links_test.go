@@ -0,0 +1,188 @@
+package payments
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	miniredis "github.com/alicebob/miniredis/v2"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/pashagolub/pgxmock/v4"
+	"github.com/redis/go-redis/v9"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/clinic"
+	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
+)
+
+var shortLinkColumns = []string{"code", "org_id", "payment_id", "target_url", "expires_at", "click_count", "last_clicked_at", "created_at"}
+
+func newMockShortLinkStore(t *testing.T) (*ShortLinkStore, pgxmock.PgxPoolIface) {
+	t.Helper()
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("pgxmock: %v", err)
+	}
+	t.Cleanup(mock.Close)
+	return &ShortLinkStore{db: mock, ttl: DefaultShortLinkTTL}, mock
+}
+
+func TestNewShortLinkCode(t *testing.T) {
+	seen := make(map[string]bool)
+	for i := 0; i < 1000; i++ {
+		code, err := newShortLinkCode()
+		if err != nil {
+			t.Fatalf("newShortLinkCode: %v", err)
+		}
+		if len(code) != shortLinkCodeLength || !validShortLinkCode(code) {
+			t.Fatalf("unexpected code %q", code)
+		}
+		if seen[code] {
+			t.Fatalf("duplicate code %q after %d draws", code, i)
+		}
+		seen[code] = true
+	}
+}
+
+func TestShortLinkStore_CreateRetriesCollisions(t *testing.T) {
+	store, mock := newMockShortLinkStore(t)
+	paymentID := uuid.New()
+	mock.ExpectExec(`INSERT INTO short_links`).
+		WithArgs(pgxmock.AnyArg(), "org-1", &paymentID, "https://square.link/u/abc", pgxmock.AnyArg()).
+		WillReturnError(&pgconn.PgError{Code: "23505"})
+	mock.ExpectExec(`INSERT INTO short_links`).
+		WithArgs(pgxmock.AnyArg(), "org-1", &paymentID, "https://square.link/u/abc", pgxmock.AnyArg()).
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+
+	code, err := store.CreateShortLink(context.Background(), "org-1", paymentID, "https://square.link/u/abc")
+	if err != nil {
+		t.Fatalf("CreateShortLink: %v", err)
+	}
+	if !validShortLinkCode(code) {
+		t.Fatalf("unexpected code %q", code)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+
+	if _, err := store.CreateShortLink(context.Background(), "", paymentID, "https://square.link/u/abc"); err == nil {
+		t.Fatal("expected an org to be required")
+	}
+}
+
+func TestShortLinkStore_ClickCountsAndExpires(t *testing.T) {
+	store, mock := newMockShortLinkStore(t)
+	now := time.Now().UTC()
+	clicked := now
+
+	mock.ExpectQuery(`UPDATE short_links\s+SET click_count = click_count \+ 1`).
+		WithArgs("x7Yt2Qa").
+		WillReturnRows(pgxmock.NewRows(shortLinkColumns).
+			AddRow("x7Yt2Qa", "org-1", (*uuid.UUID)(nil), "https://square.link/u/abc", now.Add(time.Hour), 2, &clicked, now))
+	link, err := store.Click(context.Background(), "x7Yt2Qa")
+	if err != nil || link.ClickCount != 2 || link.TargetURL != "https://square.link/u/abc" {
+		t.Fatalf("expected a counted click, got %+v err=%v", link, err)
+	}
+
+	mock.ExpectQuery(`UPDATE short_links`).WithArgs("oldLink1").WillReturnError(pgx.ErrNoRows)
+	mock.ExpectQuery(`SELECT .* FROM short_links WHERE code = \$1`).
+		WithArgs("oldLink1").
+		WillReturnRows(pgxmock.NewRows(shortLinkColumns).
+			AddRow("oldLink1", "org-1", (*uuid.UUID)(nil), "https://square.link/u/old", now.Add(-time.Hour), 5, (*time.Time)(nil), now.Add(-73*time.Hour)))
+	link, err = store.Click(context.Background(), "oldLink1")
+	if !errors.Is(err, ErrShortLinkExpired) || link == nil || link.OrgID != "org-1" {
+		t.Fatalf("expected an expired link, got %+v err=%v", link, err)
+	}
+
+	mock.ExpectQuery(`UPDATE short_links`).WithArgs("missing1").WillReturnError(pgx.ErrNoRows)
+	mock.ExpectQuery(`SELECT .* FROM short_links`).WithArgs("missing1").WillReturnError(pgx.ErrNoRows)
+	if _, err := store.Click(context.Background(), "missing1"); !errors.Is(err, ErrShortLinkNotFound) {
+		t.Fatalf("expected not found, got %v", err)
+	}
+
+	// Malformed codes never reach the database.
+	for _, code := range []string{"", "abc", "../../etc", "x7Yt2Qa!"} {
+		if _, err := store.Click(context.Background(), code); !errors.Is(err, ErrShortLinkNotFound) {
+			t.Fatalf("expected %q to be rejected, got %v", code, err)
+		}
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+type stubShortLinkClicker struct {
+	links  map[string]*ShortLink
+	clicks map[string]int
+}
+
+func (s *stubShortLinkClicker) Click(ctx context.Context, code string) (*ShortLink, error) {
+	link, ok := s.links[code]
+	if !ok {
+		return nil, ErrShortLinkNotFound
+	}
+	if time.Now().After(link.ExpiresAt) {
+		return link, ErrShortLinkExpired
+	}
+	s.clicks[code]++
+	return link, nil
+}
+
+type stubLegacyLookup map[string]string
+
+func (s stubLegacyLookup) GetCheckoutURLByShortCode(ctx context.Context, code string) (string, error) {
+	return s[code], nil
+}
+
+func TestRedirectHandler(t *testing.T) {
+	mr := miniredis.RunT(t)
+	clinics := clinic.NewStore(redis.NewClient(&redis.Options{Addr: mr.Addr()}))
+	if err := clinics.Set(context.Background(), &clinic.Config{OrgID: "org-1", Name: "Glow Med Spa", SMSPhoneNumber: "+15550001111"}); err != nil {
+		t.Fatalf("save clinic: %v", err)
+	}
+	links := &stubShortLinkClicker{
+		links: map[string]*ShortLink{
+			"x7Yt2Qa": {Code: "x7Yt2Qa", OrgID: "org-1", TargetURL: "https://square.link/u/abc", ExpiresAt: time.Now().Add(time.Hour)},
+			"oldLink": {Code: "oldLink", OrgID: "org-1", TargetURL: "https://square.link/u/old", ExpiresAt: time.Now().Add(-time.Hour)},
+		},
+		clicks: map[string]int{},
+	}
+	h := &RedirectHandler{links: links, legacy: stubLegacyLookup{"1a2b3c4d": "https://square.link/u/legacy"}, logger: logging.Default()}
+	h.SetClinicStore(clinics)
+
+	serve := func(code string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		h.ServeCode(rec, httptest.NewRequest(http.MethodGet, "/pay/"+code, nil), code)
+		return rec
+	}
+
+	for i := 0; i < 2; i++ {
+		rec := serve("x7Yt2Qa")
+		if rec.Code != http.StatusFound || rec.Header().Get("Location") != "https://square.link/u/abc" {
+			t.Fatalf("expected a redirect to checkout, got %d %q", rec.Code, rec.Header().Get("Location"))
+		}
+	}
+	if links.clicks["x7Yt2Qa"] != 2 {
+		t.Fatalf("expected 2 clicks counted, got %d", links.clicks["x7Yt2Qa"])
+	}
+
+	rec := serve("oldLink")
+	if rec.Code != http.StatusGone || rec.Header().Get("Location") != "" {
+		t.Fatalf("expected the expired page, got %d", rec.Code)
+	}
+	if body := rec.Body.String(); !strings.Contains(body, "text Glow Med Spa at &#43;15550001111") {
+		t.Fatalf("expected the expired page to say who to text, got:\n%s", body)
+	}
+
+	if rec := serve("1a2b3c4d"); rec.Code != http.StatusFound || rec.Header().Get("Location") != "https://square.link/u/legacy" {
+		t.Fatalf("expected legacy codes to keep working, got %d", rec.Code)
+	}
+	if rec := serve("nope123"); rec.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 for an unknown code, got %d", rec.Code)
+	}
+}
@@ -109,3 +109,43 @@ WHERE org_id = $1
 ORDER BY created_at DESC
 LIMIT 1;
 
+
+-- name: ListPaymentsByOrgProviderSince :many
+-- Returns an org's payments for one provider created at or after the cutoff,
+-- for reconciling against the provider's own records.
+SELECT
+    id,
+    org_id,
+    lead_id,
+    provider,
+    provider_ref,
+    booking_intent_id,
+    amount_cents,
+    status,
+    scheduled_for,
+    created_at
+FROM payments
+WHERE org_id = $1
+  AND provider = $2
+  AND created_at >= $3
+ORDER BY created_at ASC;
+
+-- name: GetLatestPendingDepositByOrgAndLead :one
+-- Returns the lead's most recent deposit still awaiting payment, with the
+-- Square order its checkout link created.
+SELECT
+    p.id,
+    p.amount_cents,
+    o.order_id
+FROM payments p
+LEFT JOIN payment_checkout_orders o ON o.payment_id = p.id
+WHERE p.org_id = $1
+  AND p.lead_id = $2
+  AND p.status = 'deposit_pending'
+ORDER BY p.created_at DESC
+LIMIT 1;
+
+-- name: UpsertPaymentCheckoutOrder :exec
+INSERT INTO payment_checkout_orders (payment_id, order_id)
+VALUES ($1, $2)
+ON CONFLICT (payment_id) DO UPDATE SET order_id = EXCLUDED.order_id;
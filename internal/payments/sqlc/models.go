@@ -51,6 +51,12 @@ type Payment struct {
 	CreatedAt       pgtype.Timestamptz
 }
 
+type PaymentCheckoutOrder struct {
+	PaymentID pgtype.UUID
+	OrderID   string
+	CreatedAt pgtype.Timestamptz
+}
+
 type ProcessedEvent struct {
 	Provider    string
 	EventID     string
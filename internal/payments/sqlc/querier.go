@@ -11,12 +11,19 @@ import (
 )
 
 type Querier interface {
+	// Returns the lead's most recent deposit still awaiting payment, with the
+	// Square order its checkout link created.
+	GetLatestPendingDepositByOrgAndLead(ctx context.Context, arg GetLatestPendingDepositByOrgAndLeadParams) (GetLatestPendingDepositByOrgAndLeadRow, error)
 	GetOpenDepositByOrgAndLead(ctx context.Context, arg GetOpenDepositByOrgAndLeadParams) (Payment, error)
 	GetPaymentByID(ctx context.Context, id pgtype.UUID) (Payment, error)
 	GetPaymentByProviderRef(ctx context.Context, providerRef pgtype.Text) (Payment, error)
 	InsertPayment(ctx context.Context, arg InsertPaymentParams) (Payment, error)
+	// Returns an org's payments for one provider created at or after the cutoff,
+	// for reconciling against the provider's own records.
+	ListPaymentsByOrgProviderSince(ctx context.Context, arg ListPaymentsByOrgProviderSinceParams) ([]Payment, error)
 	UpdatePaymentStatusByID(ctx context.Context, arg UpdatePaymentStatusByIDParams) (Payment, error)
 	UpdatePaymentStatusByProviderRef(ctx context.Context, arg UpdatePaymentStatusByProviderRefParams) (Payment, error)
+	UpsertPaymentCheckoutOrder(ctx context.Context, arg UpsertPaymentCheckoutOrderParams) error
 }
 
 var _ Querier = (*Queries)(nil)
@@ -11,6 +11,40 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const getLatestPendingDepositByOrgAndLead = `-- name: GetLatestPendingDepositByOrgAndLead :one
+SELECT
+    p.id,
+    p.amount_cents,
+    o.order_id
+FROM payments p
+LEFT JOIN payment_checkout_orders o ON o.payment_id = p.id
+WHERE p.org_id = $1
+  AND p.lead_id = $2
+  AND p.status = 'deposit_pending'
+ORDER BY p.created_at DESC
+LIMIT 1
+`
+
+type GetLatestPendingDepositByOrgAndLeadParams struct {
+	OrgID  string
+	LeadID pgtype.UUID
+}
+
+type GetLatestPendingDepositByOrgAndLeadRow struct {
+	ID          pgtype.UUID
+	AmountCents int32
+	OrderID     pgtype.Text
+}
+
+// Returns the lead's most recent deposit still awaiting payment, with the
+// Square order its checkout link created.
+func (q *Queries) GetLatestPendingDepositByOrgAndLead(ctx context.Context, arg GetLatestPendingDepositByOrgAndLeadParams) (GetLatestPendingDepositByOrgAndLeadRow, error) {
+	row := q.db.QueryRow(ctx, getLatestPendingDepositByOrgAndLead, arg.OrgID, arg.LeadID)
+	var i GetLatestPendingDepositByOrgAndLeadRow
+	err := row.Scan(&i.ID, &i.AmountCents, &i.OrderID)
+	return i, err
+}
+
 const getOpenDepositByOrgAndLead = `-- name: GetOpenDepositByOrgAndLead :one
 SELECT id, org_id, lead_id, provider, provider_ref, booking_intent_id, amount_cents, status, scheduled_for, created_at FROM payments
 WHERE org_id = $1
@@ -146,6 +180,64 @@ func (q *Queries) InsertPayment(ctx context.Context, arg InsertPaymentParams) (P
 	return i, err
 }
 
+const listPaymentsByOrgProviderSince = `-- name: ListPaymentsByOrgProviderSince :many
+SELECT
+    id,
+    org_id,
+    lead_id,
+    provider,
+    provider_ref,
+    booking_intent_id,
+    amount_cents,
+    status,
+    scheduled_for,
+    created_at
+FROM payments
+WHERE org_id = $1
+  AND provider = $2
+  AND created_at >= $3
+ORDER BY created_at ASC
+`
+
+type ListPaymentsByOrgProviderSinceParams struct {
+	OrgID     string
+	Provider  string
+	CreatedAt pgtype.Timestamptz
+}
+
+// Returns an org's payments for one provider created at or after the cutoff,
+// for reconciling against the provider's own records.
+func (q *Queries) ListPaymentsByOrgProviderSince(ctx context.Context, arg ListPaymentsByOrgProviderSinceParams) ([]Payment, error) {
+	rows, err := q.db.Query(ctx, listPaymentsByOrgProviderSince, arg.OrgID, arg.Provider, arg.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Payment
+	for rows.Next() {
+		var i Payment
+		if err := rows.Scan(
+			&i.ID,
+			&i.OrgID,
+			&i.LeadID,
+			&i.Provider,
+			&i.ProviderRef,
+			&i.BookingIntentID,
+			&i.AmountCents,
+			&i.Status,
+			&i.ScheduledFor,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const updatePaymentStatusByID = `-- name: UpdatePaymentStatusByID :one
 UPDATE payments
 SET status = $2,
@@ -209,3 +301,19 @@ func (q *Queries) UpdatePaymentStatusByProviderRef(ctx context.Context, arg Upda
 	)
 	return i, err
 }
+
+const upsertPaymentCheckoutOrder = `-- name: UpsertPaymentCheckoutOrder :exec
+INSERT INTO payment_checkout_orders (payment_id, order_id)
+VALUES ($1, $2)
+ON CONFLICT (payment_id) DO UPDATE SET order_id = EXCLUDED.order_id
+`
+
+type UpsertPaymentCheckoutOrderParams struct {
+	PaymentID pgtype.UUID
+	OrderID   string
+}
+
+func (q *Queries) UpsertPaymentCheckoutOrder(ctx context.Context, arg UpsertPaymentCheckoutOrderParams) error {
+	_, err := q.db.Exec(ctx, upsertPaymentCheckoutOrder, arg.PaymentID, arg.OrderID)
+	return err
+}
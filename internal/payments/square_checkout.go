@@ -66,6 +66,9 @@ type CheckoutParams struct {
 type CheckoutResponse struct {
 	URL        string
 	ProviderID string
+	// OrderID is the Square order the link collects payment for, when the
+	// provider reports one.
+	OrderID string
 }
 
 func NewSquareCheckoutService(accessToken, locationID, successURL, cancelURL string, logger *logging.Logger) *SquareCheckoutService {
@@ -278,6 +281,9 @@ func (s *SquareCheckoutService) createCheckoutLink(ctx context.Context, accessTo
 		Checkout struct {
 			ID              string `json:"id"`
 			CheckoutPageURL string `json:"checkout_page_url"`
+			Order           struct {
+				ID string `json:"id"`
+			} `json:"order"`
 		} `json:"checkout"`
 	}
 	if err := json.NewDecoder(resp.Body).Decode(&parsed); err != nil {
@@ -290,6 +296,7 @@ func (s *SquareCheckoutService) createCheckoutLink(ctx context.Context, accessTo
 	return &CheckoutResponse{
 		URL:        parsed.Checkout.CheckoutPageURL,
 		ProviderID: parsed.Checkout.ID,
+		OrderID:    parsed.Checkout.Order.ID,
 	}, nil
 }
 
@@ -346,8 +353,9 @@ func (s *SquareCheckoutService) createPaymentLink(ctx context.Context, accessTok
 
 	var parsed struct {
 		PaymentLink struct {
-			ID  string `json:"id"`
-			URL string `json:"url"`
+			ID      string `json:"id"`
+			URL     string `json:"url"`
+			OrderID string `json:"order_id"`
 		} `json:"payment_link"`
 	}
 	if err := json.NewDecoder(resp.Body).Decode(&parsed); err != nil {
@@ -360,9 +368,86 @@ func (s *SquareCheckoutService) createPaymentLink(ctx context.Context, accessTok
 	return &CheckoutResponse{
 		URL:        parsed.PaymentLink.URL,
 		ProviderID: parsed.PaymentLink.ID,
+		OrderID:    parsed.PaymentLink.OrderID,
 	}, nil
 }
 
+// OrderPayment asks Square directly whether a checkout's order has been
+// paid, for when the webhook hasn't arrived yet. It returns the order's
+// completed payment and the metadata attached at checkout, or a nil payment
+// while the order is still unpaid.
+func (s *SquareCheckoutService) OrderPayment(ctx context.Context, orgID, orderID string) (*SquarePayment, map[string]string, error) {
+	if strings.TrimSpace(orderID) == "" {
+		return nil, nil, fmt.Errorf("payments: square order id required")
+	}
+	accessToken, _, err := s.getCredentialsForOrg(ctx, orgID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ctx, span := squareTracer.Start(ctx, "square.order_payment")
+	defer span.End()
+	span.SetAttributes(
+		attribute.String("medspa.org_id", orgID),
+		attribute.String("medspa.square_order_id", orderID),
+	)
+
+	var order struct {
+		Order struct {
+			Metadata map[string]string `json:"metadata"`
+			Tenders  []struct {
+				PaymentID string `json:"payment_id"`
+			} `json:"tenders"`
+		} `json:"order"`
+	}
+	if err := s.squareGet(ctx, accessToken, "/v2/orders/"+url.PathEscape(orderID), &order); err != nil {
+		return nil, nil, fmt.Errorf("payments: square order: %w", err)
+	}
+	for _, tender := range order.Order.Tenders {
+		if tender.PaymentID == "" {
+			continue
+		}
+		var payment struct {
+			Payment SquarePayment `json:"payment"`
+		}
+		if err := s.squareGet(ctx, accessToken, "/v2/payments/"+url.PathEscape(tender.PaymentID), &payment); err != nil {
+			return nil, nil, fmt.Errorf("payments: square payment: %w", err)
+		}
+		if strings.EqualFold(payment.Payment.Status, "COMPLETED") {
+			if payment.Payment.OrderID == "" {
+				payment.Payment.OrderID = orderID
+			}
+			return &payment.Payment, order.Order.Metadata, nil
+		}
+	}
+	return nil, order.Order.Metadata, nil
+}
+
+func (s *SquareCheckoutService) squareGet(ctx context.Context, accessToken, path string, out any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.baseURL+path, nil)
+	if err != nil {
+		return fmt.Errorf("request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Square-Version", "2025-01-16")
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("http: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusMultipleChoices {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return fmt.Errorf("status %d: %s", resp.StatusCode, formatSquareError(body))
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("decode: %w", err)
+	}
+	return nil
+}
+
 func buildIdempotencyKey(orgID, leadID string, amount int32) string {
 	input := fmt.Sprintf("%s:%s:%d:%s", orgID, leadID, amount, time.Now().UTC().Format("2006-01-02T15"))
 	sum := sha256.Sum256([]byte(input))
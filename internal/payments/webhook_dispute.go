@@ -12,6 +12,7 @@ import (
 	"github.com/google/uuid"
 	"go.opentelemetry.io/otel/attribute"
 
+	"github.com/wolfman30/medspa-ai-platform/internal/http/apierror"
 	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
 )
 
@@ -236,6 +237,9 @@ type squareDispute struct {
 	UpdatedAt         string        `json:"updated_at"`
 	CardBrand         string        `json:"card_brand"`
 	LocationID        string        `json:"location_id"`
+	DisputedPayment   *struct {
+		PaymentID string `json:"payment_id"`
+	} `json:"disputed_payment"`
 }
 
 type squareAmount struct {
@@ -253,10 +257,13 @@ func (h *DisputeWebhookHandler) mapSquareDispute(sd *squareDispute) *DisputeEven
 		CardBrand: sd.CardBrand,
 	}
 
-	// Handle alternate payment ID field
+	// Handle alternate payment ID fields
 	if dispute.PaymentID == "" {
 		dispute.PaymentID = sd.PaymentID
 	}
+	if dispute.PaymentID == "" && sd.DisputedPayment != nil {
+		dispute.PaymentID = sd.DisputedPayment.PaymentID
+	}
 
 	if sd.AmountMoney != nil {
 		dispute.AmountCents = sd.AmountMoney.Amount
@@ -359,14 +366,14 @@ func (h *DisputeWebhookHandler) RegisterRoutes(mux *http.ServeMux) {
 
 func (h *DisputeWebhookHandler) handleHTTP(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
-		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		apierror.Write(w, r, apierror.CodeMethodNotAllowed, "method not allowed")
 		return
 	}
 
 	// Read body once to avoid consumption issues
 	bodyBytes, err := io.ReadAll(r.Body)
 	if err != nil {
-		http.Error(w, "failed to read body", http.StatusBadRequest)
+		apierror.Write(w, r, apierror.CodeValidationFailed, "failed to read body")
 		return
 	}
 
@@ -376,14 +383,14 @@ func (h *DisputeWebhookHandler) handleHTTP(w http.ResponseWriter, r *http.Reques
 	}
 
 	if err := json.Unmarshal(bodyBytes, &envelope); err != nil {
-		http.Error(w, "invalid payload", http.StatusBadRequest)
+		apierror.Write(w, r, apierror.CodeValidationFailed, "invalid payload")
 		return
 	}
 
 	// Handle the event with full body bytes
 	if err := h.HandleWebhook(r.Context(), envelope.Type, bodyBytes); err != nil {
 		h.logger.Error("dispute webhook error", "error", err)
-		http.Error(w, "internal error", http.StatusInternalServerError)
+		apierror.Write(w, r, apierror.CodeInternal, "internal error")
 		return
 	}
 
@@ -0,0 +1,122 @@
+package payments
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/events"
+	"github.com/wolfman30/medspa-ai-platform/internal/leads"
+	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
+)
+
+type memoryWebhookRecorder struct {
+	bodies   map[uuid.UUID][]byte
+	outcomes map[uuid.UUID]string
+	last     uuid.UUID
+}
+
+func (m *memoryWebhookRecorder) Record(ctx context.Context, provider, eventID string, headers http.Header, body []byte) (uuid.UUID, error) {
+	if m.bodies == nil {
+		m.bodies = map[uuid.UUID][]byte{}
+		m.outcomes = map[uuid.UUID]string{}
+	}
+	id := uuid.New()
+	m.bodies[id] = append([]byte(nil), body...)
+	m.outcomes[id] = events.WebhookOutcomeReceived
+	m.last = id
+	return id, nil
+}
+
+func (m *memoryWebhookRecorder) SetOutcome(ctx context.Context, id uuid.UUID, outcome, detail string) error {
+	m.outcomes[id] = outcome
+	return nil
+}
+
+// flakyOutbox fails the next `failures` inserts, then records like stubOutboxWriter.
+type flakyOutbox struct {
+	stubOutboxWriter
+	failures int
+}
+
+func (f *flakyOutbox) Insert(ctx context.Context, orgID string, eventType string, payload any) (uuid.UUID, error) {
+	if f.failures > 0 {
+		f.failures--
+		return uuid.Nil, errors.New("outbox unavailable")
+	}
+	return f.stubOutboxWriter.Insert(ctx, orgID, eventType, payload)
+}
+
+func TestSquareWebhookHandler_CaptureThenReplayBooksOnce(t *testing.T) {
+	orgID := uuid.New().String()
+	leadID := uuid.New().String()
+	leadsRepo := &stubLeadRepo{lead: &leads.Lead{ID: leadID, OrgID: orgID, Phone: "+15550000000"}}
+	processed := &stubProcessedTracker{}
+	outbox := &flakyOutbox{failures: 1}
+	recorder := &memoryWebhookRecorder{}
+
+	handler := NewSquareWebhookHandler("secret", &stubPaymentStore{}, leadsRepo, processed, outbox, stubNumberResolver("+19998887777"), nil, logging.Default())
+	handler.SetEventLog(recorder)
+
+	body := buildSquarePayload(t, "evt-replay", "pay-replay", "COMPLETED", map[string]string{
+		"org_id":            orgID,
+		"lead_id":           leadID,
+		"booking_intent_id": uuid.New().String(),
+	})
+	req := httptest.NewRequest(http.MethodPost, "http://example.com/webhooks/square", bytes.NewReader(body))
+	req.Host = "example.com"
+	sign(req, "secret", body)
+	rr := httptest.NewRecorder()
+	handler.Handle(rr, req)
+
+	if rr.Code != http.StatusInternalServerError {
+		t.Fatalf("expected first delivery to fail, got %d", rr.Code)
+	}
+	if got := recorder.outcomes[recorder.last]; got != events.WebhookOutcomeFailed {
+		t.Fatalf("captured outcome = %q, want failed", got)
+	}
+	if len(outbox.inserted) != 0 {
+		t.Fatalf("expected no booking event yet, got %d", len(outbox.inserted))
+	}
+
+	captured := recorder.bodies[recorder.last]
+	for i := 0; i < 2; i++ {
+		res := handler.Replay(context.Background(), captured, false)
+		if outcome, detail := res.Outcome(); outcome != events.WebhookOutcomeProcessed {
+			t.Fatalf("replay %d outcome = %s (%s)", i+1, outcome, detail)
+		}
+	}
+	if len(outbox.inserted) != 1 {
+		t.Fatalf("expected exactly one booking event after replays, got %d", len(outbox.inserted))
+	}
+}
+
+func TestSquareWebhookHandler_ForceReplayIgnoresProcessedMarker(t *testing.T) {
+	orgID := uuid.New().String()
+	leadID := uuid.New().String()
+	leadsRepo := &stubLeadRepo{lead: &leads.Lead{ID: leadID, OrgID: orgID, Phone: "+15550000000"}}
+	// Marked processed, but the side effect never happened.
+	processed := &stubProcessedTracker{seen: map[string]bool{"square:evt-lost": true}}
+	outbox := &stubOutboxWriter{}
+	handler := NewSquareWebhookHandler("secret", &stubPaymentStore{}, leadsRepo, processed, outbox, nil, nil, logging.Default())
+
+	body := buildSquarePayload(t, "evt-lost", "pay-lost", "COMPLETED", map[string]string{
+		"org_id":            orgID,
+		"lead_id":           leadID,
+		"booking_intent_id": uuid.New().String(),
+	})
+
+	handler.Replay(context.Background(), body, false)
+	if len(outbox.inserted) != 0 {
+		t.Fatalf("non-forced replay of processed event should be a no-op, got %d inserts", len(outbox.inserted))
+	}
+	res := handler.Replay(context.Background(), body, true)
+	if res.Status != http.StatusOK || len(outbox.inserted) != 1 {
+		t.Fatalf("forced replay: status=%d inserts=%d", res.Status, len(outbox.inserted))
+	}
+}
@@ -1,6 +1,7 @@
 package payments
 
 import (
+	"bytes"
 	"context"
 	"crypto/hmac"
 	"crypto/sha1"
@@ -15,8 +16,10 @@ import (
 	"github.com/google/uuid"
 
 	"github.com/wolfman30/medspa-ai-platform/internal/events"
+	"github.com/wolfman30/medspa-ai-platform/internal/http/apierror"
 	"github.com/wolfman30/medspa-ai-platform/internal/leads"
 	paymentsql "github.com/wolfman30/medspa-ai-platform/internal/payments/sqlc"
+	"github.com/wolfman30/medspa-ai-platform/internal/tenancy"
 	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
 )
 
@@ -42,6 +45,7 @@ type SquareWebhookHandler struct {
 	outbox       outboxWriter
 	numbers      OrgNumberResolver
 	orders       orderMetadataFetcher
+	eventLog     events.WebhookRecorder
 	logger       *logging.Logger
 }
 
@@ -61,22 +65,56 @@ func NewSquareWebhookHandler(sigKey string, payments paymentStatusStore, leadsRe
 	}
 }
 
+// SetEventLog enables capturing raw webhook payloads for later replay.
+func (h *SquareWebhookHandler) SetEventLog(rec events.WebhookRecorder) {
+	h.eventLog = rec
+}
+
 func (h *SquareWebhookHandler) Handle(w http.ResponseWriter, r *http.Request) {
 	payload, err := io.ReadAll(r.Body)
 	if err != nil {
-		http.Error(w, "invalid body", http.StatusBadRequest)
+		apierror.Write(w, r, apierror.CodeValidationFailed, "invalid body")
 		return
 	}
 
 	if !verifySquareSignature(h.signatureKey, buildAbsoluteURL(r), payload, r.Header.Get("X-Square-Signature")) {
-		http.Error(w, "forbidden", http.StatusForbidden)
+		apierror.Write(w, r, apierror.CodeForbidden, "forbidden")
 		return
 	}
 
+	var ids struct {
+		ID      string `json:"id"`
+		EventID string `json:"event_id"`
+	}
+	_ = json.Unmarshal(payload, &ids)
+	eventID := ids.EventID
+	if eventID == "" {
+		eventID = ids.ID
+	}
+	events.CaptureWebhook(r.Context(), h.eventLog, h.logger, "square", eventID, r.Header, payload, w, func(w http.ResponseWriter) {
+		h.process(w, r, payload, false)
+	})
+}
+
+// Replay re-runs a captured Square webhook payload without signature
+// verification. Processed-event markers are honored unless force is set,
+// which is for events marked processed that never produced their side effects.
+func (h *SquareWebhookHandler) Replay(ctx context.Context, payload []byte, force bool) *events.ResponseCapture {
+	capture := events.NewResponseCapture(nil)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, "/webhooks/square", bytes.NewReader(payload))
+	if err != nil {
+		http.Error(capture, "bad request", http.StatusBadRequest)
+		return capture
+	}
+	h.process(capture, req, payload, force)
+	return capture
+}
+
+func (h *SquareWebhookHandler) process(w http.ResponseWriter, r *http.Request, payload []byte, force bool) {
 	var evt squarePaymentEvent
 	if err := json.Unmarshal(payload, &evt); err != nil {
 		h.logger.Error("failed to decode square event", "error", err)
-		http.Error(w, "bad request", http.StatusBadRequest)
+		apierror.Write(w, r, apierror.CodeValidationFailed, "bad request")
 		return
 	}
 
@@ -85,16 +123,24 @@ func (h *SquareWebhookHandler) Handle(w http.ResponseWriter, r *http.Request) {
 		eventID = evt.ID
 	}
 	if eventID == "" {
-		http.Error(w, "missing event id", http.StatusBadRequest)
+		apierror.Write(w, r, apierror.CodeValidationFailed, "missing event id")
 		return
 	}
 
-	if processed, err := h.processed.AlreadyProcessed(r.Context(), "square", eventID); err != nil {
-		h.logger.Error("processed lookup failed", "error", err)
-		http.Error(w, "server error", http.StatusInternalServerError)
-		return
-	} else if processed {
-		w.WriteHeader(http.StatusOK)
+	if !force {
+		if processed, err := h.processed.AlreadyProcessed(r.Context(), "square", eventID); err != nil {
+			h.logger.Error("processed lookup failed", "error", err)
+			apierror.Write(w, r, apierror.CodeInternal, "server error")
+			return
+		} else if processed {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+	}
+
+	if strings.HasPrefix(strings.ToLower(evt.Type), "dispute.") {
+		code, msg, err := h.handleDispute(r, payload, eventID, force)
+		h.writeOutcome(w, r, "dispute", eventID, code, msg, err)
 		return
 	}
 
@@ -103,17 +149,8 @@ func (h *SquareWebhookHandler) Handle(w http.ResponseWriter, r *http.Request) {
 	case "COMPLETED":
 		// continue
 	case "FAILED", "CANCELED", "CANCELLED":
-		code, msg, err := h.handleFailure(r, evt, eventID)
-		if err != nil {
-			h.logger.Error("square failure webhook handling failed", "error", err, "event_id", eventID)
-			http.Error(w, "server error", http.StatusInternalServerError)
-			return
-		}
-		if code != http.StatusOK {
-			http.Error(w, msg, code)
-			return
-		}
-		w.WriteHeader(code)
+		code, msg, err := h.handleFailure(r, evt, eventID, force)
+		h.writeOutcome(w, r, "failure", eventID, code, msg, err)
 		return
 	default:
 		w.WriteHeader(http.StatusOK)
@@ -130,10 +167,10 @@ func (h *SquareWebhookHandler) Handle(w http.ResponseWriter, r *http.Request) {
 	fromNumber := metadata["from_number"]
 	var paymentRow *paymentsql.Payment
 
-	if paymentID != "" {
+	if paymentID != "" && !force {
 		if processed, err := h.processed.AlreadyProcessed(r.Context(), "square.payment_succeeded", paymentID); err != nil {
 			h.logger.Error("processed lookup failed", "error", err)
-			http.Error(w, "server error", http.StatusInternalServerError)
+			apierror.Write(w, r, apierror.CodeInternal, "server error")
 			return
 		} else if processed {
 			// Best-effort: mark the individual webhook event as processed too so we don't re-check.
@@ -148,7 +185,7 @@ func (h *SquareWebhookHandler) Handle(w http.ResponseWriter, r *http.Request) {
 	if orgID == "" || leadID == "" || intentID == "" {
 		// Fallback: try to resolve via provider ref if metadata is missing (observed in some webhook payloads).
 		if paymentID == "" {
-			http.Error(w, "missing metadata", http.StatusBadRequest)
+			apierror.Write(w, r, apierror.CodeValidationFailed, "missing metadata")
 			return
 		}
 		var (
@@ -210,42 +247,42 @@ func (h *SquareWebhookHandler) Handle(w http.ResponseWriter, r *http.Request) {
 
 	orgUUID, err := uuid.Parse(orgID)
 	if err != nil {
-		http.Error(w, "invalid org id", http.StatusBadRequest)
+		apierror.Write(w, r, apierror.CodeValidationFailed, "invalid org id")
 		return
 	}
 	orgID = orgUUID.String()
 
 	leadUUID, err := uuid.Parse(leadID)
 	if err != nil {
-		http.Error(w, "invalid lead id", http.StatusBadRequest)
+		apierror.Write(w, r, apierror.CodeValidationFailed, "invalid lead id")
 		return
 	}
 	leadID = leadUUID.String()
 
 	if intentID == "" {
-		http.Error(w, "missing booking intent id", http.StatusBadRequest)
+		apierror.Write(w, r, apierror.CodeValidationFailed, "missing booking intent id")
 		return
 	}
 	paymentUUID, err := uuid.Parse(intentID)
 	if err != nil {
-		http.Error(w, "invalid booking intent id", http.StatusBadRequest)
+		apierror.Write(w, r, apierror.CodeValidationFailed, "invalid booking intent id")
 		return
 	}
 
 	providerRef := paymentID
-	if _, err := h.payments.UpdateStatusByID(r.Context(), paymentUUID, "succeeded", providerRef); err != nil {
+	if _, err := h.payments.UpdateStatusByID(r.Context(), paymentUUID, PaymentStatusSucceeded, providerRef); err != nil {
 		h.logger.Error("failed to update payment record", "error", err)
-		http.Error(w, "server error", http.StatusInternalServerError)
+		apierror.Write(w, r, apierror.CodeInternal, "server error")
 		return
 	}
 
-	lead, err := h.leads.GetByID(r.Context(), orgID, leadID)
+	lead, err := h.leads.GetByID(r.Context(), tenancy.ForOrg(orgID), leadID)
 	if err != nil {
 		h.logger.Error("lead fetch failed", "error", err, "lead_id", leadID)
-		http.Error(w, "lead not found", http.StatusNotFound)
+		apierror.Write(w, r, apierror.CodeNotFound, "lead not found")
 		return
 	}
-	if err := h.leads.UpdateDepositStatus(r.Context(), leadID, "paid", "priority"); err != nil {
+	if err := h.leads.UpdateDepositStatus(r.Context(), tenancy.ForOrg(orgID), leadID, "paid", "priority"); err != nil {
 		h.logger.Warn("failed to update lead deposit status", "error", err, "lead_id", leadID, "org_id", orgID)
 	}
 
@@ -258,7 +295,7 @@ func (h *SquareWebhookHandler) Handle(w http.ResponseWriter, r *http.Request) {
 		ProviderRef:     providerRef,
 		AmountCents:     evt.Data.Object.Payment.AmountMoney.Amount,
 		OccurredAt:      evt.CreatedAt,
-		LeadPhone:       lead.Phone,
+		LeadPhone:       leads.CanonicalPhone(lead.Phone),
 		LeadName:        lead.Name,
 		ScheduledFor:    scheduledFor,
 	}
@@ -269,7 +306,7 @@ func (h *SquareWebhookHandler) Handle(w http.ResponseWriter, r *http.Request) {
 
 	if _, err := h.outbox.Insert(r.Context(), orgID, "payment_succeeded.v1", event); err != nil {
 		h.logger.Error("failed to enqueue outbox", "error", err)
-		http.Error(w, "server error", http.StatusInternalServerError)
+		apierror.Write(w, r, apierror.CodeInternal, "server error")
 		return
 	}
 	if providerRef != "" {
@@ -283,7 +320,7 @@ func (h *SquareWebhookHandler) Handle(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusOK)
 }
 
-func (h *SquareWebhookHandler) handleFailure(r *http.Request, evt squarePaymentEvent, eventID string) (int, string, error) {
+func (h *SquareWebhookHandler) handleFailure(r *http.Request, evt squarePaymentEvent, eventID string, force bool) (int, string, error) {
 	metadata := evt.Data.Object.Payment.Metadata
 	orgID := metadata["org_id"]
 	leadID := metadata["lead_id"]
@@ -292,8 +329,9 @@ func (h *SquareWebhookHandler) handleFailure(r *http.Request, evt squarePaymentE
 	orderID := evt.Data.Object.Payment.OrderID
 	status := strings.ToUpper(strings.TrimSpace(evt.Data.Object.Payment.Status))
 	fromNumber := metadata["from_number"]
+	scheduledStr := metadata["scheduled_for"]
 
-	if paymentID != "" {
+	if paymentID != "" && !force {
 		if processed, err := h.processed.AlreadyProcessed(r.Context(), "square.payment_failed", paymentID); err != nil {
 			h.logger.Error("processed lookup failed", "error", err)
 			return http.StatusInternalServerError, "", err
@@ -322,6 +360,7 @@ func (h *SquareWebhookHandler) handleFailure(r *http.Request, evt squarePaymentE
 					orgID = orderMeta["org_id"]
 					leadID = orderMeta["lead_id"]
 					intentID = orderMeta["booking_intent_id"]
+					scheduledStr = orderMeta["scheduled_for"]
 					if fromNumber == "" {
 						fromNumber = orderMeta["from_number"]
 					}
@@ -367,15 +406,15 @@ func (h *SquareWebhookHandler) handleFailure(r *http.Request, evt squarePaymentE
 	}
 
 	providerRef := paymentID
-	if _, err := h.payments.UpdateStatusByID(r.Context(), intentUUID, "failed", providerRef); err != nil {
+	if _, err := h.payments.UpdateStatusByID(r.Context(), intentUUID, PaymentStatusFailed, providerRef); err != nil {
 		return http.StatusInternalServerError, "", err
 	}
 
-	lead, err := h.leads.GetByID(r.Context(), orgID, leadID)
+	lead, err := h.leads.GetByID(r.Context(), tenancy.ForOrg(orgID), leadID)
 	if err != nil {
 		return http.StatusNotFound, "lead not found", nil
 	}
-	if err := h.leads.UpdateDepositStatus(r.Context(), leadID, "failed", "normal"); err != nil {
+	if err := h.leads.UpdateDepositStatus(r.Context(), tenancy.ForOrg(orgID), leadID, "failed", "normal"); err != nil {
 		h.logger.Warn("failed to update lead deposit status", "error", err, "lead_id", leadID, "org_id", orgID)
 	}
 
@@ -388,9 +427,18 @@ func (h *SquareWebhookHandler) handleFailure(r *http.Request, evt squarePaymentE
 		ProviderRef:     providerRef,
 		AmountCents:     evt.Data.Object.Payment.AmountMoney.Amount,
 		OccurredAt:      evt.CreatedAt,
-		LeadPhone:       lead.Phone,
+		LeadPhone:       leads.CanonicalPhone(lead.Phone),
 		FailureStatus:   status,
 	}
+	if scheduledStr != "" {
+		if parsed, err := time.Parse(time.RFC3339, scheduledStr); err == nil {
+			failEvt.ScheduledFor = &parsed
+		}
+	}
+	if failEvt.ScheduledFor == nil && paymentRow != nil && paymentRow.ScheduledFor.Valid {
+		t := paymentRow.ScheduledFor.Time
+		failEvt.ScheduledFor = &t
+	}
 	if fromNumber == "" && h.numbers != nil {
 		fromNumber = h.numbers.DefaultFromNumber(orgID)
 	}
@@ -410,6 +458,20 @@ func (h *SquareWebhookHandler) handleFailure(r *http.Request, evt squarePaymentE
 	return http.StatusOK, "", nil
 }
 
+// writeOutcome writes the response for a failure or dispute sub-handler.
+func (h *SquareWebhookHandler) writeOutcome(w http.ResponseWriter, r *http.Request, kind, eventID string, code int, msg string, err error) {
+	if err != nil {
+		h.logger.Error("square "+kind+" webhook handling failed", "error", err, "event_id", eventID)
+		apierror.Write(w, r, apierror.CodeInternal, "server error")
+		return
+	}
+	if code != http.StatusOK {
+		apierror.WriteStatus(w, r, code, apierror.CodeForStatus(code), msg, nil)
+		return
+	}
+	w.WriteHeader(code)
+}
+
 func verifySquareSignature(key, url string, body []byte, header string) bool {
 	// For development/sandbox testing, allow bypass when no key is configured.
 	// In production, SQUARE_WEBHOOK_SIGNATURE_KEY must be set.
@@ -0,0 +1,125 @@
+package payments
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/events"
+	"github.com/wolfman30/medspa-ai-platform/internal/leads"
+	"github.com/wolfman30/medspa-ai-platform/internal/tenancy"
+)
+
+// squareDisputeEvent is the envelope for dispute.* webhooks delivered to the
+// main Square endpoint.
+type squareDisputeEvent struct {
+	CreatedAt time.Time `json:"created_at"`
+	Data      struct {
+		Object struct {
+			Dispute squareDispute `json:"dispute"`
+		} `json:"object"`
+	} `json:"data"`
+}
+
+// handleDispute marks a disputed deposit, flags the lead, and emits a
+// payment_disputed.v1 event once per dispute. Disputes for payments we don't
+// know about are acknowledged so Square stops retrying.
+func (h *SquareWebhookHandler) handleDispute(r *http.Request, payload []byte, eventID string, force bool) (int, string, error) {
+	ctx := r.Context()
+	var evt squareDisputeEvent
+	if err := json.Unmarshal(payload, &evt); err != nil {
+		return http.StatusBadRequest, "bad request", nil
+	}
+	dispute := evt.Data.Object.Dispute
+	disputeID := strings.TrimSpace(dispute.ID)
+	if disputeID == "" {
+		disputeID = strings.TrimSpace(dispute.DisputeID)
+	}
+	paymentID := strings.TrimSpace(dispute.DisputedPaymentID)
+	if paymentID == "" {
+		paymentID = strings.TrimSpace(dispute.PaymentID)
+	}
+	if paymentID == "" && dispute.DisputedPayment != nil {
+		paymentID = strings.TrimSpace(dispute.DisputedPayment.PaymentID)
+	}
+	if disputeID == "" || paymentID == "" {
+		h.logger.Warn("square dispute webhook missing dispute or payment id", "event_id", eventID)
+		return http.StatusOK, "", nil
+	}
+
+	if !force {
+		if processed, err := h.processed.AlreadyProcessed(ctx, "square.payment_disputed", disputeID); err != nil {
+			return http.StatusInternalServerError, "", err
+		} else if processed {
+			if _, err := h.processed.MarkProcessed(ctx, "square", eventID); err != nil {
+				h.logger.Error("failed to record processed event", "error", err)
+			}
+			return http.StatusOK, "", nil
+		}
+	}
+
+	paymentRow, err := h.payments.GetByProviderRef(ctx, paymentID)
+	if err != nil || paymentRow == nil || !paymentRow.ID.Valid || !paymentRow.LeadID.Valid {
+		h.logger.Warn("square dispute for unknown payment", "error", err, "payment_id", paymentID, "dispute_id", disputeID)
+		return http.StatusOK, "", nil
+	}
+	intentUUID := uuid.UUID(paymentRow.ID.Bytes)
+	leadID := uuid.UUID(paymentRow.LeadID.Bytes).String()
+	orgID := paymentRow.OrgID
+
+	if _, err := h.payments.UpdateStatusByID(ctx, intentUUID, PaymentStatusDisputed, paymentID); err != nil {
+		return http.StatusInternalServerError, "", err
+	}
+
+	disputedEvt := events.PaymentDisputedV1{
+		EventID:         eventID,
+		OrgID:           orgID,
+		LeadID:          leadID,
+		BookingIntentID: intentUUID.String(),
+		Provider:        "square",
+		ProviderRef:     paymentID,
+		DisputeID:       disputeID,
+		Reason:          dispute.Reason,
+		State:           dispute.State,
+		AmountCents:     int64(paymentRow.AmountCents),
+		OccurredAt:      evt.CreatedAt,
+	}
+	if dispute.AmountMoney != nil {
+		disputedEvt.AmountCents = int64(dispute.AmountMoney.Amount)
+	}
+	if paymentRow.ScheduledFor.Valid {
+		t := paymentRow.ScheduledFor.Time
+		disputedEvt.ScheduledFor = &t
+	}
+	if lead, err := h.leads.GetByID(ctx, tenancy.ForOrg(orgID), leadID); err == nil && lead != nil {
+		disputedEvt.LeadPhone = leads.CanonicalPhone(lead.Phone)
+		disputedEvt.LeadName = lead.Name
+	} else {
+		h.logger.Warn("square dispute lead lookup failed", "error", err, "lead_id", leadID, "org_id", orgID)
+	}
+	if err := h.leads.UpdateDepositStatus(ctx, tenancy.ForOrg(orgID), leadID, PaymentStatusDisputed, "priority"); err != nil {
+		h.logger.Warn("failed to update lead deposit status", "error", err, "lead_id", leadID, "org_id", orgID)
+	}
+
+	if _, err := h.outbox.Insert(ctx, orgID, "payment_disputed.v1", disputedEvt); err != nil {
+		return http.StatusInternalServerError, "", err
+	}
+	h.logger.Warn("square payment disputed",
+		"org_id", orgID,
+		"lead_id", leadID,
+		"payment_id", paymentID,
+		"dispute_id", disputeID,
+		"reason", dispute.Reason,
+	)
+
+	if _, err := h.processed.MarkProcessed(ctx, "square.payment_disputed", disputeID); err != nil {
+		h.logger.Error("failed to record processed dispute", "error", err, "dispute_id", disputeID)
+	}
+	if _, err := h.processed.MarkProcessed(ctx, "square", eventID); err != nil {
+		h.logger.Error("failed to record processed event", "error", err)
+	}
+	return http.StatusOK, "", nil
+}
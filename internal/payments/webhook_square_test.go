@@ -20,6 +20,7 @@ import (
 	"github.com/wolfman30/medspa-ai-platform/internal/events"
 	"github.com/wolfman30/medspa-ai-platform/internal/leads"
 	paymentsql "github.com/wolfman30/medspa-ai-platform/internal/payments/sqlc"
+	"github.com/wolfman30/medspa-ai-platform/internal/tenancy"
 	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
 )
 
@@ -336,6 +337,90 @@ func TestVerifySquareSignature(t *testing.T) {
 	}
 }
 
+func TestSquareWebhookHandler_DeclineMarksFailed(t *testing.T) {
+	orgID := uuid.New().String()
+	leadID := uuid.New().String()
+	intentID := uuid.New().String()
+	scheduled := time.Date(2026, 3, 10, 15, 0, 0, 0, time.UTC)
+
+	payments := &stubPaymentStore{}
+	leadsRepo := &stubLeadRepo{lead: &leads.Lead{ID: leadID, OrgID: orgID, Phone: "+15550000000"}}
+	outbox := &stubOutboxWriter{}
+	handler := NewSquareWebhookHandler("", payments, leadsRepo, &stubProcessedTracker{}, outbox, stubNumberResolver("+19998887777"), nil, logging.Default())
+
+	body := buildSquarePayload(t, "evt-decline", "pay-decline", "FAILED", map[string]string{
+		"org_id":            orgID,
+		"lead_id":           leadID,
+		"booking_intent_id": intentID,
+		"scheduled_for":     scheduled.Format(time.RFC3339),
+	})
+	rr := httptest.NewRecorder()
+	handler.Handle(rr, httptest.NewRequest(http.MethodPost, "http://example.com/webhooks/square", bytes.NewReader(body)))
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rr.Code)
+	}
+	if len(payments.statuses) != 1 || payments.statuses[0] != PaymentStatusFailed {
+		t.Fatalf("expected payment marked failed, got %v", payments.statuses)
+	}
+	if len(outbox.failed) != 1 {
+		t.Fatalf("expected payment_failed event, got %d", len(outbox.failed))
+	}
+	got := outbox.failed[0]
+	if got.FailureStatus != "FAILED" || got.LeadPhone != "+15550000000" || got.FromNumber != "+19998887777" {
+		t.Fatalf("unexpected failure event: %+v", got)
+	}
+	if got.ScheduledFor == nil || !got.ScheduledFor.Equal(scheduled) {
+		t.Fatalf("expected scheduled_for to propagate, got %v", got.ScheduledFor)
+	}
+}
+
+func TestSquareWebhookHandler_DisputeMarksDisputedOnce(t *testing.T) {
+	paymentUUID := uuid.New()
+	scheduled := time.Date(2026, 3, 10, 15, 0, 0, 0, time.UTC)
+	row := samplePaymentWithSchedule(paymentUUID, "pay-disputed", scheduled)
+	row.AmountCents = 5000
+	leadID := uuid.UUID(row.LeadID.Bytes).String()
+
+	payments := &stubPaymentStore{pay: row}
+	leadsRepo := &stubLeadRepo{lead: &leads.Lead{ID: leadID, OrgID: row.OrgID, Name: "Jane Doe", Phone: "+15550000000"}}
+	outbox := &stubOutboxWriter{}
+	handler := NewSquareWebhookHandler("", payments, leadsRepo, &stubProcessedTracker{}, outbox, nil, nil, logging.Default())
+
+	send := func(eventID, eventType string) int {
+		body := []byte(`{"event_id":"` + eventID + `","type":"` + eventType + `","created_at":"2026-03-12T10:00:00Z",
+			"data":{"object":{"dispute":{"id":"dp-1","state":"EVIDENCE_REQUIRED","reason":"NO_KNOWLEDGE",
+			"amount_money":{"amount":5000,"currency":"USD"},"disputed_payment":{"payment_id":"pay-disputed"}}}}}`)
+		rr := httptest.NewRecorder()
+		handler.Handle(rr, httptest.NewRequest(http.MethodPost, "http://example.com/webhooks/square", bytes.NewReader(body)))
+		return rr.Code
+	}
+
+	if code := send("evt-dispute-1", "dispute.created"); code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", code)
+	}
+	if code := send("evt-dispute-2", "dispute.state.changed"); code != http.StatusOK {
+		t.Fatalf("expected 200 on follow-up, got %d", code)
+	}
+
+	if len(payments.statuses) != 1 || payments.statuses[0] != PaymentStatusDisputed {
+		t.Fatalf("expected one disputed status update, got %v", payments.statuses)
+	}
+	if len(outbox.disputed) != 1 {
+		t.Fatalf("expected one payment_disputed event, got %d", len(outbox.disputed))
+	}
+	got := outbox.disputed[0]
+	if got.DisputeID != "dp-1" || got.ProviderRef != "pay-disputed" || got.LeadID != leadID || got.OrgID != row.OrgID {
+		t.Fatalf("unexpected dispute event: %+v", got)
+	}
+	if got.BookingIntentID != paymentUUID.String() || got.AmountCents != 5000 || got.LeadName != "Jane Doe" || got.Reason != "NO_KNOWLEDGE" {
+		t.Fatalf("unexpected dispute details: %+v", got)
+	}
+	if got.ScheduledFor == nil || !got.ScheduledFor.Equal(scheduled) {
+		t.Fatalf("expected scheduled_for from payment row, got %v", got.ScheduledFor)
+	}
+}
+
 // Helpers & stubs
 
 func buildSquarePayload(t *testing.T, eventID, paymentID, status string, metadata map[string]string) []byte {
@@ -396,14 +481,16 @@ func samplePaymentWithSchedule(id uuid.UUID, providerRef string, scheduled time.
 }
 
 type stubPaymentStore struct {
-	called bool
-	calls  int
-	pay    *paymentsql.Payment
+	called   bool
+	calls    int
+	pay      *paymentsql.Payment
+	statuses []string
 }
 
 func (s *stubPaymentStore) UpdateStatusByID(ctx context.Context, id uuid.UUID, status, providerRef string) (*paymentsql.Payment, error) {
 	s.called = true
 	s.calls++
+	s.statuses = append(s.statuses, status)
 	if s.pay != nil {
 		return s.pay, nil
 	}
@@ -426,7 +513,7 @@ func (s *stubLeadRepo) Create(context.Context, *leads.CreateLeadRequest) (*leads
 	return nil, errors.New("not implemented")
 }
 
-func (s *stubLeadRepo) GetByID(ctx context.Context, orgID string, id string) (*leads.Lead, error) {
+func (s *stubLeadRepo) GetByID(ctx context.Context, scope tenancy.OrgScope, id string) (*leads.Lead, error) {
 	if s.err != nil {
 		return nil, s.err
 	}
@@ -446,23 +533,27 @@ func (s *stubLeadRepo) GetOrCreateByPhone(context.Context, string, string, strin
 	return s.lead, nil
 }
 
-func (s *stubLeadRepo) UpdateSchedulingPreferences(context.Context, string, leads.SchedulingPreferences) error {
+func (s *stubLeadRepo) UpdateSchedulingPreferences(context.Context, tenancy.OrgScope, string, leads.SchedulingPreferences) error {
+	return nil
+}
+
+func (s *stubLeadRepo) MergePreferences(context.Context, string, leads.SchedulingPreferences) error {
 	return nil
 }
 
-func (s *stubLeadRepo) UpdateDepositStatus(context.Context, string, string, string) error {
+func (s *stubLeadRepo) UpdateDepositStatus(context.Context, tenancy.OrgScope, string, string, string) error {
 	return nil
 }
 
-func (s *stubLeadRepo) ListByOrg(context.Context, string, leads.ListLeadsFilter) ([]*leads.Lead, error) {
+func (s *stubLeadRepo) ListByOrg(context.Context, tenancy.OrgScope, leads.ListLeadsFilter) ([]*leads.Lead, error) {
 	return nil, nil
 }
 
-func (s *stubLeadRepo) UpdateSelectedAppointment(context.Context, string, leads.SelectedAppointment) error {
+func (s *stubLeadRepo) UpdateSelectedAppointment(context.Context, tenancy.OrgScope, string, leads.SelectedAppointment) error {
 	return nil
 }
 
-func (s *stubLeadRepo) UpdateBookingSession(context.Context, string, leads.BookingSessionUpdate) error {
+func (s *stubLeadRepo) UpdateBookingSession(context.Context, tenancy.OrgScope, string, leads.BookingSessionUpdate) error {
 	return nil
 }
 
@@ -470,7 +561,7 @@ func (s *stubLeadRepo) GetByBookingSessionID(context.Context, string) (*leads.Le
 	return nil, nil
 }
 
-func (s *stubLeadRepo) UpdateEmail(context.Context, string, string) error {
+func (s *stubLeadRepo) UpdateEmail(context.Context, tenancy.OrgScope, string, string) error {
 	return nil
 }
 
@@ -515,11 +606,18 @@ func (s *stubProcessedTracker) MarkProcessed(ctx context.Context, provider, even
 
 type stubOutboxWriter struct {
 	inserted []events.PaymentSucceededV1
+	failed   []events.PaymentFailedV1
+	disputed []events.PaymentDisputedV1
 }
 
 func (s *stubOutboxWriter) Insert(ctx context.Context, orgID string, eventType string, payload any) (uuid.UUID, error) {
-	if evt, ok := payload.(events.PaymentSucceededV1); ok {
+	switch evt := payload.(type) {
+	case events.PaymentSucceededV1:
 		s.inserted = append(s.inserted, evt)
+	case events.PaymentFailedV1:
+		s.failed = append(s.failed, evt)
+	case events.PaymentDisputedV1:
+		s.disputed = append(s.disputed, evt)
 	}
 	return uuid.New(), nil
 }
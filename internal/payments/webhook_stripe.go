@@ -17,7 +17,9 @@ import (
 	"github.com/redis/go-redis/v9"
 
 	"github.com/wolfman30/medspa-ai-platform/internal/events"
+	"github.com/wolfman30/medspa-ai-platform/internal/http/apierror"
 	"github.com/wolfman30/medspa-ai-platform/internal/leads"
+	"github.com/wolfman30/medspa-ai-platform/internal/tenancy"
 	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
 )
 
@@ -66,25 +68,25 @@ func (h *StripeWebhookHandler) SetRedis(r *redis.Client) {
 func (h *StripeWebhookHandler) Handle(w http.ResponseWriter, r *http.Request) {
 	payload, err := io.ReadAll(r.Body)
 	if err != nil {
-		http.Error(w, "invalid body", http.StatusBadRequest)
+		apierror.Write(w, r, apierror.CodeValidationFailed, "invalid body")
 		return
 	}
 
 	sigHeader := r.Header.Get("Stripe-Signature")
 	if !verifyStripeSignature(h.webhookSecret, payload, sigHeader) {
-		http.Error(w, "forbidden", http.StatusForbidden)
+		apierror.Write(w, r, apierror.CodeForbidden, "forbidden")
 		return
 	}
 
 	var evt stripeWebhookEvent
 	if err := json.Unmarshal(payload, &evt); err != nil {
 		h.logger.Error("failed to decode stripe event", "error", err)
-		http.Error(w, "bad request", http.StatusBadRequest)
+		apierror.Write(w, r, apierror.CodeValidationFailed, "bad request")
 		return
 	}
 
 	if evt.ID == "" {
-		http.Error(w, "missing event id", http.StatusBadRequest)
+		apierror.Write(w, r, apierror.CodeValidationFailed, "missing event id")
 		return
 	}
 
@@ -101,7 +103,7 @@ func (h *StripeWebhookHandler) Handle(w http.ResponseWriter, r *http.Request) {
 
 	if processed, err := h.processed.AlreadyProcessed(r.Context(), "stripe", evt.ID); err != nil {
 		h.logger.Error("processed lookup failed", "error", err)
-		http.Error(w, "server error", http.StatusInternalServerError)
+		apierror.Write(w, r, apierror.CodeInternal, "server error")
 		return
 	} else if processed {
 		h.logger.Info("stripe webhook: already processed", "event_id", evt.ID)
@@ -168,37 +170,37 @@ func (h *StripeWebhookHandler) Handle(w http.ResponseWriter, r *http.Request) {
 
 	orgUUID, err := uuid.Parse(orgID)
 	if err != nil {
-		http.Error(w, "invalid org id", http.StatusBadRequest)
+		apierror.Write(w, r, apierror.CodeValidationFailed, "invalid org id")
 		return
 	}
 	orgID = orgUUID.String()
 
 	leadUUID, err := uuid.Parse(leadID)
 	if err != nil {
-		http.Error(w, "invalid lead id", http.StatusBadRequest)
+		apierror.Write(w, r, apierror.CodeValidationFailed, "invalid lead id")
 		return
 	}
 	leadID = leadUUID.String()
 
 	paymentUUID, err := uuid.Parse(intentID)
 	if err != nil {
-		http.Error(w, "invalid booking intent id", http.StatusBadRequest)
+		apierror.Write(w, r, apierror.CodeValidationFailed, "invalid booking intent id")
 		return
 	}
 
 	if _, err := h.payments.UpdateStatusByID(r.Context(), paymentUUID, "succeeded", providerRef); err != nil {
 		h.logger.Error("failed to update payment record", "error", err)
-		http.Error(w, "server error", http.StatusInternalServerError)
+		apierror.Write(w, r, apierror.CodeInternal, "server error")
 		return
 	}
 
-	lead, err := h.leads.GetByID(r.Context(), orgID, leadID)
+	lead, err := h.leads.GetByID(r.Context(), tenancy.ForOrg(orgID), leadID)
 	if err != nil {
 		h.logger.Error("lead fetch failed", "error", err, "lead_id", leadID)
-		http.Error(w, "lead not found", http.StatusNotFound)
+		apierror.Write(w, r, apierror.CodeNotFound, "lead not found")
 		return
 	}
-	if err := h.leads.UpdateDepositStatus(r.Context(), leadID, "paid", "priority"); err != nil {
+	if err := h.leads.UpdateDepositStatus(r.Context(), tenancy.ForOrg(orgID), leadID, "paid", "priority"); err != nil {
 		h.logger.Warn("failed to update lead deposit status", "error", err, "lead_id", leadID, "org_id", orgID)
 	}
 
@@ -230,7 +232,7 @@ func (h *StripeWebhookHandler) Handle(w http.ResponseWriter, r *http.Request) {
 		ProviderRef:     providerRef,
 		AmountCents:     amountCents,
 		OccurredAt:      time.Unix(evt.Created, 0),
-		LeadPhone:       lead.Phone,
+		LeadPhone:       leads.CanonicalPhone(lead.Phone),
 		LeadName:        lead.Name,
 		ScheduledFor:    scheduledFor,
 		ServiceName:     leadService(lead),
@@ -242,7 +244,7 @@ func (h *StripeWebhookHandler) Handle(w http.ResponseWriter, r *http.Request) {
 
 	if _, err := h.outbox.Insert(r.Context(), orgID, "payment_succeeded.v1", event); err != nil {
 		h.logger.Error("failed to enqueue outbox", "error", err)
-		http.Error(w, "server error", http.StatusInternalServerError)
+		apierror.Write(w, r, apierror.CodeInternal, "server error")
 		return
 	}
 
@@ -0,0 +1,272 @@
+package pii
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
+)
+
+// Querier is the subset of pgxpool.Pool the backfill needs.
+type Querier interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+// BackfillTable describes a table whose PII columns are encrypted in place.
+type BackfillTable struct {
+	Name    string
+	Columns []string
+	// PhoneColumn, when set, is hashed into the table's phone_hash column.
+	PhoneColumn string
+}
+
+// BackfillTables lists every table holding encrypted PII.
+var BackfillTables = []BackfillTable{
+	{Name: "leads", Columns: []string{"name", "email", "phone"}, PhoneColumn: "phone"},
+	{Name: "messages", Columns: []string{"body"}},
+}
+
+// BackfillTableByName returns the BackfillTables entry called name.
+func BackfillTableByName(name string) (BackfillTable, bool) {
+	for _, table := range BackfillTables {
+		if table.Name == name {
+			return table, true
+		}
+	}
+	return BackfillTable{}, false
+}
+
+// BackfillResult counts the rows a backfill run touched.
+type BackfillResult struct {
+	Scanned   int
+	Encrypted int
+	// Skipped rows changed between read and write; the writer that changed
+	// them already stored ciphertext.
+	Skipped int
+}
+
+const defaultBackfillBatchSize = 500
+
+// Backfiller encrypts rows written before encryption was enabled, and
+// re-seals rows under old keys after the active key is rotated. It walks
+// each table in id order and records the last id of every finished batch in
+// pii_backfill_progress, so an interrupted run picks up where it stopped.
+type Backfiller struct {
+	db        Querier
+	cipher    *Cipher
+	batchSize int
+	logger    *logging.Logger
+}
+
+// NewBackfiller builds a backfiller. cipher must not be nil.
+func NewBackfiller(db Querier, cipher *Cipher, logger *logging.Logger) *Backfiller {
+	if logger == nil {
+		logger = logging.Default()
+	}
+	return &Backfiller{db: db, cipher: cipher, batchSize: defaultBackfillBatchSize, logger: logger}
+}
+
+// SetBatchSize sets how many rows are read and checkpointed at a time.
+func (b *Backfiller) SetBatchSize(n int) {
+	if n > 0 {
+		b.batchSize = n
+	}
+}
+
+// Reset forgets table's checkpoint so the next run starts from the top.
+func (b *Backfiller) Reset(ctx context.Context, table BackfillTable) error {
+	if _, err := b.db.Exec(ctx, `DELETE FROM pii_backfill_progress WHERE table_name = $1`, table.Name); err != nil {
+		return fmt.Errorf("pii: reset %s checkpoint: %w", table.Name, err)
+	}
+	return nil
+}
+
+// Run encrypts table from its checkpoint to the end.
+func (b *Backfiller) Run(ctx context.Context, table BackfillTable) (BackfillResult, error) {
+	var result BackfillResult
+	if b.cipher == nil {
+		return result, errors.New("pii: backfill needs a cipher")
+	}
+	lastID, err := b.checkpoint(ctx, table)
+	if err != nil {
+		return result, err
+	}
+	for {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+		rows, err := b.readBatch(ctx, table, lastID)
+		if err != nil {
+			return result, err
+		}
+		for _, row := range rows {
+			updated, err := b.sealRow(ctx, table, row)
+			if err != nil {
+				return result, err
+			}
+			result.Scanned++
+			switch updated {
+			case rowSealed:
+				result.Encrypted++
+			case rowChanged:
+				result.Skipped++
+			}
+		}
+		if len(rows) == 0 {
+			return result, nil
+		}
+		lastID = rows[len(rows)-1].id
+		if err := b.saveCheckpoint(ctx, table, lastID); err != nil {
+			return result, err
+		}
+		b.logger.Info("pii backfill batch done",
+			"table", table.Name,
+			"last_id", lastID,
+			"scanned", result.Scanned,
+			"encrypted", result.Encrypted,
+		)
+		if len(rows) < b.batchSize {
+			return result, nil
+		}
+	}
+}
+
+type backfillRow struct {
+	id        uuid.UUID
+	values    []string
+	phoneHash string
+}
+
+func (b *Backfiller) checkpoint(ctx context.Context, table BackfillTable) (uuid.UUID, error) {
+	var lastID uuid.UUID
+	err := b.db.QueryRow(ctx, `SELECT last_id FROM pii_backfill_progress WHERE table_name = $1`, table.Name).Scan(&lastID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return uuid.Nil, nil
+	}
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("pii: load %s checkpoint: %w", table.Name, err)
+	}
+	return lastID, nil
+}
+
+func (b *Backfiller) saveCheckpoint(ctx context.Context, table BackfillTable, lastID uuid.UUID) error {
+	_, err := b.db.Exec(ctx, `
+		INSERT INTO pii_backfill_progress (table_name, last_id, updated_at)
+		VALUES ($1, $2, NOW())
+		ON CONFLICT (table_name) DO UPDATE SET last_id = EXCLUDED.last_id, updated_at = NOW()
+	`, table.Name, lastID)
+	if err != nil {
+		return fmt.Errorf("pii: save %s checkpoint: %w", table.Name, err)
+	}
+	return nil
+}
+
+func (b *Backfiller) readBatch(ctx context.Context, table BackfillTable, after uuid.UUID) ([]backfillRow, error) {
+	columns := make([]string, 0, len(table.Columns)+1)
+	for _, column := range table.Columns {
+		columns = append(columns, fmt.Sprintf("COALESCE(%s, '')", column))
+	}
+	if table.PhoneColumn != "" {
+		columns = append(columns, "COALESCE(phone_hash, '')")
+	}
+	query := fmt.Sprintf(`SELECT id, %s FROM %s WHERE id > $1 ORDER BY id LIMIT $2`, strings.Join(columns, ", "), table.Name)
+	rows, err := b.db.Query(ctx, query, after, b.batchSize)
+	if err != nil {
+		return nil, fmt.Errorf("pii: read %s batch: %w", table.Name, err)
+	}
+	defer rows.Close()
+
+	var batch []backfillRow
+	for rows.Next() {
+		row := backfillRow{values: make([]string, len(table.Columns))}
+		dest := []any{&row.id}
+		for i := range row.values {
+			dest = append(dest, &row.values[i])
+		}
+		if table.PhoneColumn != "" {
+			dest = append(dest, &row.phoneHash)
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return nil, fmt.Errorf("pii: scan %s row: %w", table.Name, err)
+		}
+		batch = append(batch, row)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("pii: read %s batch: %w", table.Name, err)
+	}
+	return batch, nil
+}
+
+type rowOutcome int
+
+const (
+	rowUnchanged rowOutcome = iota
+	rowSealed
+	rowChanged
+)
+
+// sealRow encrypts the row's values that need it and sets its phone hash.
+// The UPDATE only applies if the row still holds the values that were read.
+func (b *Backfiller) sealRow(ctx context.Context, table BackfillTable, row backfillRow) (rowOutcome, error) {
+	sealed := make([]string, len(row.values))
+	changed := false
+	wantHash := ""
+	for i, value := range row.values {
+		plaintext, err := b.cipher.Decrypt(value)
+		if err != nil {
+			return rowUnchanged, fmt.Errorf("pii: %s %s %s: %w", table.Name, row.id, table.Columns[i], err)
+		}
+		if table.Columns[i] == table.PhoneColumn {
+			wantHash = b.cipher.HashPhone(plaintext)
+		}
+		sealed[i] = value
+		if b.cipher.NeedsEncrypt(value) {
+			if sealed[i], err = b.cipher.Encrypt(plaintext); err != nil {
+				return rowUnchanged, err
+			}
+			changed = true
+		}
+	}
+	if table.PhoneColumn != "" && wantHash != row.phoneHash {
+		changed = true
+	}
+	if !changed {
+		return rowUnchanged, nil
+	}
+
+	sets := make([]string, 0, len(table.Columns)+1)
+	conds := []string{"id = $1"}
+	args := []any{row.id}
+	for i, column := range table.Columns {
+		if sealed[i] == row.values[i] {
+			continue
+		}
+		args = append(args, sealed[i])
+		sets = append(sets, fmt.Sprintf("%s = $%d", column, len(args)))
+	}
+	if table.PhoneColumn != "" {
+		args = append(args, wantHash)
+		sets = append(sets, fmt.Sprintf("phone_hash = NULLIF($%d, '')", len(args)))
+	}
+	for i, column := range table.Columns {
+		args = append(args, row.values[i])
+		conds = append(conds, fmt.Sprintf("COALESCE(%s, '') = $%d", column, len(args)))
+	}
+	query := fmt.Sprintf(`UPDATE %s SET %s WHERE %s`, table.Name, strings.Join(sets, ", "), strings.Join(conds, " AND "))
+	tag, err := b.db.Exec(ctx, query, args...)
+	if err != nil {
+		return rowUnchanged, fmt.Errorf("pii: encrypt %s %s: %w", table.Name, row.id, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return rowChanged, nil
+	}
+	return rowSealed, nil
+}
@@ -0,0 +1,95 @@
+package pii
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	pgxmock "github.com/pashagolub/pgxmock/v4"
+)
+
+func TestBackfillResumesFromCheckpoint(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("pgxmock: %v", err)
+	}
+	defer mock.Close()
+
+	c := testCipher(t, "k2", map[string][]byte{"k1": testKey(1), "k2": testKey(2)})
+	old := testCipher(t, "k1", map[string][]byte{"k1": testKey(1)})
+	oldSealed, _ := old.Encrypt("see you tuesday")
+	current, _ := c.Encrypt("already done")
+
+	checkpoint := uuid.MustParse("00000000-0000-0000-0000-000000000010")
+	ids := []uuid.UUID{
+		uuid.MustParse("00000000-0000-0000-0000-000000000011"),
+		uuid.MustParse("00000000-0000-0000-0000-000000000012"),
+		uuid.MustParse("00000000-0000-0000-0000-000000000013"),
+	}
+
+	mock.ExpectQuery("SELECT last_id FROM pii_backfill_progress").WithArgs("messages").
+		WillReturnRows(pgxmock.NewRows([]string{"last_id"}).AddRow(checkpoint))
+	// First batch starts after the checkpoint, not from the top.
+	mock.ExpectQuery("SELECT id, COALESCE\\(body, ''\\) FROM messages WHERE id > \\$1").WithArgs(checkpoint, 2).
+		WillReturnRows(pgxmock.NewRows([]string{"id", "body"}).AddRow(ids[0], "hello").AddRow(ids[1], oldSealed))
+	mock.ExpectExec("UPDATE messages SET body = \\$2 WHERE id = \\$1 AND COALESCE\\(body, ''\\) = \\$3").
+		WithArgs(ids[0], pgxmock.AnyArg(), "hello").WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	// Re-sealing an old-key value: a concurrent writer got there first.
+	mock.ExpectExec("UPDATE messages").
+		WithArgs(ids[1], pgxmock.AnyArg(), oldSealed).WillReturnResult(pgxmock.NewResult("UPDATE", 0))
+	mock.ExpectExec("INSERT INTO pii_backfill_progress").WithArgs("messages", ids[1]).
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+	mock.ExpectQuery("FROM messages WHERE id > \\$1").WithArgs(ids[1], 2).
+		WillReturnRows(pgxmock.NewRows([]string{"id", "body"}).AddRow(ids[2], current))
+	mock.ExpectExec("INSERT INTO pii_backfill_progress").WithArgs("messages", ids[2]).
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+
+	b := NewBackfiller(mock, c, nil)
+	b.SetBatchSize(2)
+	table, _ := BackfillTableByName("messages")
+	result, err := b.Run(context.Background(), table)
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if result != (BackfillResult{Scanned: 3, Encrypted: 1, Skipped: 1}) {
+		t.Fatalf("result = %+v", result)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestBackfillLeadsSetsPhoneHash(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("pgxmock: %v", err)
+	}
+	defer mock.Close()
+
+	c := testCipher(t, "k1", map[string][]byte{"k1": testKey(1)})
+	id := uuid.New()
+
+	mock.ExpectQuery("SELECT last_id FROM pii_backfill_progress").WithArgs("leads").WillReturnError(pgx.ErrNoRows)
+	mock.ExpectQuery("SELECT id, COALESCE\\(name, ''\\), COALESCE\\(email, ''\\), COALESCE\\(phone, ''\\), COALESCE\\(phone_hash, ''\\) FROM leads").
+		WithArgs(uuid.Nil, 500).
+		WillReturnRows(pgxmock.NewRows([]string{"id", "name", "email", "phone", "phone_hash"}).AddRow(id, "Sarah Johnson", "", "+15550102000", ""))
+	// The blank email is left alone; only the name, phone and hash change.
+	mock.ExpectExec("UPDATE leads SET name = \\$2, phone = \\$3, phone_hash = NULLIF\\(\\$4, ''\\) WHERE id = \\$1").
+		WithArgs(id, pgxmock.AnyArg(), pgxmock.AnyArg(), c.HashPhone("5550102000"), "Sarah Johnson", "", "+15550102000").
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	mock.ExpectExec("INSERT INTO pii_backfill_progress").WithArgs("leads", id).
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+
+	table, _ := BackfillTableByName("leads")
+	result, err := NewBackfiller(mock, c, nil).Run(context.Background(), table)
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if result.Encrypted != 1 {
+		t.Fatalf("result = %+v", result)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
@@ -0,0 +1,318 @@
+// Package pii encrypts patient-identifying values (names, emails, phones,
+// message bodies) before they are written to the database.
+//
+// Values are envelope-encrypted: each one is sealed with a fresh AES-256-GCM
+// data key, and the data key is sealed with a configured key-encryption key.
+// Ciphertexts carry the ID of that key ("pii1:<keyID>:<payload>"), so keys can
+// be rotated while values sealed under older keys stay readable. Values
+// without the prefix are plaintext written before encryption was enabled and
+// are returned as-is.
+//
+// Encrypted values can't be compared in SQL, so phones also get a keyed,
+// deterministic hash (HashPhone) stored in a separate column for lookups.
+package pii
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync/atomic"
+)
+
+// Prefix marks an encrypted value.
+const Prefix = "pii1:"
+
+const (
+	keySize   = 32
+	nonceSize = 12
+	// wrappedKeySize is a sealed data key: the key plus the GCM tag.
+	wrappedKeySize = keySize + 16
+)
+
+// Config configures a Cipher.
+type Config struct {
+	// Keys maps key ID to a 32-byte key-encryption key.
+	Keys map[string][]byte
+	// ActiveKeyID is the key new values are sealed under.
+	ActiveKeyID string
+	// HMACKey keys HashPhone. Required.
+	HMACKey []byte
+}
+
+// Cipher seals and opens PII values. A nil *Cipher is valid and leaves
+// values in plaintext, so callers don't need to check whether encryption is
+// configured.
+type Cipher struct {
+	keks    map[string]cipher.AEAD
+	active  string
+	hmacKey []byte
+}
+
+// NewCipher validates cfg and builds a Cipher.
+func NewCipher(cfg Config) (*Cipher, error) {
+	if len(cfg.Keys) == 0 {
+		return nil, errors.New("pii: at least one key required")
+	}
+	if _, ok := cfg.Keys[cfg.ActiveKeyID]; !ok {
+		return nil, fmt.Errorf("pii: active key %q not among configured keys", cfg.ActiveKeyID)
+	}
+	if len(cfg.HMACKey) < 16 {
+		return nil, errors.New("pii: hmac key must be at least 16 bytes")
+	}
+	keks := make(map[string]cipher.AEAD, len(cfg.Keys))
+	for id, key := range cfg.Keys {
+		if id == "" || strings.Contains(id, ":") {
+			return nil, fmt.Errorf("pii: invalid key id %q", id)
+		}
+		if len(key) != keySize {
+			return nil, fmt.Errorf("pii: key %q must be %d bytes, got %d", id, keySize, len(key))
+		}
+		aead, err := newGCM(key)
+		if err != nil {
+			return nil, fmt.Errorf("pii: key %q: %w", id, err)
+		}
+		keks[id] = aead
+	}
+	return &Cipher{keks: keks, active: cfg.ActiveKeyID, hmacKey: append([]byte(nil), cfg.HMACKey...)}, nil
+}
+
+// ParseConfig builds a Config from its environment form. keys is a
+// comma-separated list of "<keyID>:<base64 key>" pairs; activeKeyID defaults
+// to the first one. hmacKey is base64. An empty keys returns a nil Config,
+// meaning encryption is off.
+func ParseConfig(keys, activeKeyID, hmacKey string) (*Config, error) {
+	keys = strings.TrimSpace(keys)
+	if keys == "" {
+		return nil, nil
+	}
+	cfg := &Config{Keys: make(map[string][]byte), ActiveKeyID: strings.TrimSpace(activeKeyID)}
+	for _, pair := range strings.Split(keys, ",") {
+		id, encoded, ok := strings.Cut(strings.TrimSpace(pair), ":")
+		if !ok {
+			return nil, fmt.Errorf("pii: key entry %q must be <keyID>:<base64 key>", pair)
+		}
+		key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+		if err != nil {
+			return nil, fmt.Errorf("pii: key %q: %w", id, err)
+		}
+		id = strings.TrimSpace(id)
+		cfg.Keys[id] = key
+		if cfg.ActiveKeyID == "" {
+			cfg.ActiveKeyID = id
+		}
+	}
+	mac, err := base64.StdEncoding.DecodeString(strings.TrimSpace(hmacKey))
+	if err != nil {
+		return nil, fmt.Errorf("pii: hmac key: %w", err)
+	}
+	cfg.HMACKey = mac
+	return cfg, nil
+}
+
+// Enabled reports whether values are being encrypted.
+func (c *Cipher) Enabled() bool {
+	return c != nil
+}
+
+// ActiveKeyID returns the key new values are sealed under.
+func (c *Cipher) ActiveKeyID() string {
+	if c == nil {
+		return ""
+	}
+	return c.active
+}
+
+// Encrypt seals plaintext under the active key. Empty strings stay empty so
+// "blank means unset" checks keep working.
+func (c *Cipher) Encrypt(plaintext string) (string, error) {
+	if c == nil || plaintext == "" {
+		return plaintext, nil
+	}
+	dataKey := make([]byte, keySize)
+	if _, err := rand.Read(dataKey); err != nil {
+		return "", fmt.Errorf("pii: generate data key: %w", err)
+	}
+	dataAEAD, err := newGCM(dataKey)
+	if err != nil {
+		return "", fmt.Errorf("pii: data key: %w", err)
+	}
+
+	payload := make([]byte, 0, 2*nonceSize+wrappedKeySize+len(plaintext)+16)
+	keyNonce, err := randomNonce()
+	if err != nil {
+		return "", err
+	}
+	payload = append(payload, keyNonce...)
+	payload = c.keks[c.active].Seal(payload, keyNonce, dataKey, []byte(c.active))
+	dataNonce, err := randomNonce()
+	if err != nil {
+		return "", err
+	}
+	payload = append(payload, dataNonce...)
+	payload = dataAEAD.Seal(payload, dataNonce, []byte(plaintext), nil)
+
+	return Prefix + c.active + ":" + base64.RawStdEncoding.EncodeToString(payload), nil
+}
+
+// Decrypt opens a value sealed under any configured key. Values without the
+// prefix are returned unchanged.
+func (c *Cipher) Decrypt(value string) (string, error) {
+	if !IsEncrypted(value) {
+		return value, nil
+	}
+	if c == nil {
+		return "", errors.New("pii: encrypted value but no keys configured")
+	}
+	keyID, encoded, ok := strings.Cut(strings.TrimPrefix(value, Prefix), ":")
+	if !ok {
+		return "", errors.New("pii: malformed ciphertext")
+	}
+	kek, ok := c.keks[keyID]
+	if !ok {
+		return "", fmt.Errorf("pii: unknown key %q", keyID)
+	}
+	payload, err := base64.RawStdEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", fmt.Errorf("pii: decode ciphertext: %w", err)
+	}
+	if len(payload) < 2*nonceSize+wrappedKeySize+16 {
+		return "", errors.New("pii: ciphertext too short")
+	}
+	keyNonce, rest := payload[:nonceSize], payload[nonceSize:]
+	wrapped, rest := rest[:wrappedKeySize], rest[wrappedKeySize:]
+	dataNonce, sealed := rest[:nonceSize], rest[nonceSize:]
+
+	dataKey, err := kek.Open(nil, keyNonce, wrapped, []byte(keyID))
+	if err != nil {
+		return "", fmt.Errorf("pii: unwrap data key: %w", err)
+	}
+	dataAEAD, err := newGCM(dataKey)
+	if err != nil {
+		return "", fmt.Errorf("pii: data key: %w", err)
+	}
+	plaintext, err := dataAEAD.Open(nil, dataNonce, sealed, nil)
+	if err != nil {
+		return "", fmt.Errorf("pii: open value: %w", err)
+	}
+	return string(plaintext), nil
+}
+
+// NeedsEncrypt reports whether a stored value still has to be (re)sealed:
+// it is plaintext, or sealed under a key other than the active one.
+func (c *Cipher) NeedsEncrypt(value string) bool {
+	if c == nil || value == "" {
+		return false
+	}
+	return KeyID(value) != c.active
+}
+
+// HashPhone returns the hex HMAC-SHA256 of a phone's digits, or "" when c is
+// nil or phone has no digits. Formatting doesn't matter: "+1 (555) 010-2000"
+// and "5550102000" hash the same.
+func (c *Cipher) HashPhone(phone string) string {
+	if c == nil {
+		return ""
+	}
+	digits := phoneDigits(phone)
+	if digits == "" {
+		return ""
+	}
+	mac := hmac.New(sha256.New, c.hmacKey)
+	mac.Write([]byte(digits))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// KeyIDs returns the configured key IDs, sorted.
+func (c *Cipher) KeyIDs() []string {
+	if c == nil {
+		return nil
+	}
+	ids := make([]string, 0, len(c.keks))
+	for id := range c.keks {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// IsEncrypted reports whether value is a ciphertext from this package.
+func IsEncrypted(value string) bool {
+	return strings.HasPrefix(value, Prefix)
+}
+
+// KeyID returns the key a ciphertext was sealed under, or "" for plaintext.
+func KeyID(value string) string {
+	if !IsEncrypted(value) {
+		return ""
+	}
+	id, _, _ := strings.Cut(strings.TrimPrefix(value, Prefix), ":")
+	return id
+}
+
+// phoneDigits keeps a phone's digits, adding the US country code to bare
+// ten-digit numbers.
+func phoneDigits(phone string) string {
+	var b strings.Builder
+	for _, r := range phone {
+		if r >= '0' && r <= '9' {
+			b.WriteRune(r)
+		}
+	}
+	digits := b.String()
+	if len(digits) == 10 {
+		digits = "1" + digits
+	}
+	return digits
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func randomNonce() ([]byte, error) {
+	nonce := make([]byte, nonceSize)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("pii: generate nonce: %w", err)
+	}
+	return nonce, nil
+}
+
+var defaultCipher atomic.Pointer[Cipher]
+
+// SetDefault sets the process-wide cipher used by Reveal and PhoneHash, for
+// code that reads PII columns outside the repositories.
+func SetDefault(c *Cipher) {
+	defaultCipher.Store(c)
+}
+
+// Default returns the process-wide cipher, nil when encryption is off.
+func Default() *Cipher {
+	return defaultCipher.Load()
+}
+
+// Reveal decrypts value with the default cipher. A value that can't be
+// opened is returned blank rather than leaking ciphertext into responses.
+func Reveal(value string) string {
+	plaintext, err := Default().Decrypt(value)
+	if err != nil {
+		return ""
+	}
+	return plaintext
+}
+
+// PhoneHash hashes phone with the default cipher; "" when encryption is off.
+func PhoneHash(phone string) string {
+	return Default().HashPhone(phone)
+}
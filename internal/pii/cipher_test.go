@@ -0,0 +1,159 @@
+package pii
+
+import (
+	"bytes"
+	"encoding/base64"
+	"strings"
+	"testing"
+)
+
+func testKey(b byte) []byte {
+	return bytes.Repeat([]byte{b}, keySize)
+}
+
+func testCipher(t *testing.T, active string, keys map[string][]byte) *Cipher {
+	t.Helper()
+	c, err := NewCipher(Config{Keys: keys, ActiveKeyID: active, HMACKey: []byte("0123456789abcdef")})
+	if err != nil {
+		t.Fatalf("NewCipher: %v", err)
+	}
+	return c
+}
+
+func TestCipherRoundTrip(t *testing.T) {
+	c := testCipher(t, "k1", map[string][]byte{"k1": testKey(1)})
+
+	for _, plaintext := range []string{"Sarah Johnson", "sarah@example.com", "+15550102000", "Can I book Botox for Tuesday? 💉"} {
+		sealed, err := c.Encrypt(plaintext)
+		if err != nil {
+			t.Fatalf("Encrypt(%q): %v", plaintext, err)
+		}
+		if !strings.HasPrefix(sealed, Prefix+"k1:") || strings.Contains(sealed, plaintext) {
+			t.Fatalf("ciphertext %q doesn't look sealed", sealed)
+		}
+		again, _ := c.Encrypt(plaintext)
+		if again == sealed {
+			t.Fatal("sealing the same value twice must not give the same ciphertext")
+		}
+		opened, err := c.Decrypt(sealed)
+		if err != nil || opened != plaintext {
+			t.Fatalf("Decrypt = %q, %v; want %q", opened, err, plaintext)
+		}
+	}
+
+	if sealed, _ := c.Encrypt(""); sealed != "" {
+		t.Fatalf("empty value sealed to %q", sealed)
+	}
+	if opened, err := c.Decrypt("legacy plaintext"); err != nil || opened != "legacy plaintext" {
+		t.Fatalf("plaintext passthrough = %q, %v", opened, err)
+	}
+}
+
+func TestCipherRejectsTamperedCiphertext(t *testing.T) {
+	c := testCipher(t, "k1", map[string][]byte{"k1": testKey(1)})
+	sealed, _ := c.Encrypt("Sarah Johnson")
+	tampered := sealed[:len(sealed)-2] + "AA"
+	if tampered == sealed {
+		tampered = sealed[:len(sealed)-2] + "BB"
+	}
+	if _, err := c.Decrypt(tampered); err == nil {
+		t.Fatal("expected tampered ciphertext to fail")
+	}
+	relabeled := strings.Replace(sealed, Prefix+"k1:", Prefix+"k2:", 1)
+	if _, err := c.Decrypt(relabeled); err == nil {
+		t.Fatal("expected an unknown key id to fail")
+	}
+}
+
+func TestCipherKeyRotationReadsOldCiphertexts(t *testing.T) {
+	old := testCipher(t, "k1", map[string][]byte{"k1": testKey(1)})
+	sealedOld, _ := old.Encrypt("Sarah Johnson")
+
+	rotated := testCipher(t, "k2", map[string][]byte{"k1": testKey(1), "k2": testKey(2)})
+	opened, err := rotated.Decrypt(sealedOld)
+	if err != nil || opened != "Sarah Johnson" {
+		t.Fatalf("rotated Decrypt = %q, %v", opened, err)
+	}
+	if !rotated.NeedsEncrypt(sealedOld) {
+		t.Fatal("values under the old key should be re-sealed")
+	}
+	sealedNew, _ := rotated.Encrypt("Sarah Johnson")
+	if KeyID(sealedNew) != "k2" || rotated.NeedsEncrypt(sealedNew) {
+		t.Fatalf("new value sealed under %q", KeyID(sealedNew))
+	}
+	if !rotated.NeedsEncrypt("plaintext") || rotated.NeedsEncrypt("") {
+		t.Fatal("plaintext needs sealing, blanks don't")
+	}
+	if _, err := old.Decrypt(sealedNew); err == nil {
+		t.Fatal("a cipher without k2 must not open k2 values")
+	}
+}
+
+func TestHashPhone(t *testing.T) {
+	c := testCipher(t, "k1", map[string][]byte{"k1": testKey(1)})
+	want := c.HashPhone("+15550102000")
+	if want == "" {
+		t.Fatal("expected a hash")
+	}
+	for _, phone := range []string{"+1 (555) 010-2000", "15550102000", "5550102000", "555.010.2000"} {
+		if got := c.HashPhone(phone); got != want {
+			t.Errorf("HashPhone(%q) = %s, want %s", phone, got, want)
+		}
+	}
+	if c.HashPhone("+15550102001") == want {
+		t.Fatal("different numbers must hash differently")
+	}
+	if c.HashPhone("") != "" {
+		t.Fatal("blank phones have no hash")
+	}
+
+	otherMAC, _ := NewCipher(Config{Keys: map[string][]byte{"k1": testKey(1)}, ActiveKeyID: "k1", HMACKey: []byte("fedcba9876543210")})
+	if otherMAC.HashPhone("+15550102000") == want {
+		t.Fatal("hash must depend on the hmac key")
+	}
+	rotated := testCipher(t, "k2", map[string][]byte{"k1": testKey(1), "k2": testKey(2)})
+	if rotated.HashPhone("+15550102000") != want {
+		t.Fatal("rotating the encryption key must not change phone hashes")
+	}
+}
+
+func TestNilCipherIsPassthrough(t *testing.T) {
+	var c *Cipher
+	if sealed, err := c.Encrypt("Sarah"); err != nil || sealed != "Sarah" {
+		t.Fatalf("Encrypt = %q, %v", sealed, err)
+	}
+	if opened, err := c.Decrypt("Sarah"); err != nil || opened != "Sarah" {
+		t.Fatalf("Decrypt = %q, %v", opened, err)
+	}
+	if c.HashPhone("+15550102000") != "" || c.Enabled() {
+		t.Fatal("nil cipher must not hash")
+	}
+	if _, err := c.Decrypt(Prefix + "k1:AAAA"); err == nil {
+		t.Fatal("nil cipher can't open ciphertext")
+	}
+}
+
+func TestParseConfig(t *testing.T) {
+	b64 := func(b byte) string { return base64.StdEncoding.EncodeToString(testKey(b)) }
+	cfg, err := ParseConfig("k2:"+b64(2)+", k1:"+b64(1), "", base64.StdEncoding.EncodeToString([]byte("0123456789abcdef")))
+	if err != nil {
+		t.Fatalf("ParseConfig: %v", err)
+	}
+	if cfg.ActiveKeyID != "k2" || len(cfg.Keys) != 2 {
+		t.Fatalf("cfg = %+v", cfg)
+	}
+	if _, err := NewCipher(*cfg); err != nil {
+		t.Fatalf("NewCipher: %v", err)
+	}
+
+	if cfg, err := ParseConfig("", "", ""); cfg != nil || err != nil {
+		t.Fatalf("empty keys = %+v, %v", cfg, err)
+	}
+	if _, err := ParseConfig("no-separator", "", ""); err == nil {
+		t.Fatal("expected malformed key entry to fail")
+	}
+	cfg, _ = ParseConfig("k1:"+b64(1), "k9", base64.StdEncoding.EncodeToString([]byte("0123456789abcdef")))
+	if _, err := NewCipher(*cfg); err == nil {
+		t.Fatal("expected unknown active key to fail")
+	}
+}
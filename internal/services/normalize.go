@@ -0,0 +1,104 @@
+package services
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Normalize lowercases a service name or message, drops apostrophes, turns
+// other punctuation into spaces, collapses whitespace and singularizes each
+// word, so "Chemical Peels!" and "chemical peel" compare equal.
+func Normalize(s string) string {
+	return strings.Join(tokens(s), " ")
+}
+
+// compact drops the spaces from a normalized string so "micro needling" and
+// "microneedling" compare equal.
+func compact(normalized string) string {
+	return strings.ReplaceAll(normalized, " ", "")
+}
+
+func tokens(s string) []string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(s) {
+		switch {
+		case r == '\'' || r == '’':
+			// "crow's feet" -> "crows feet"
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || r == '+':
+			b.WriteRune(r)
+		default:
+			b.WriteRune(' ')
+		}
+	}
+	fields := strings.Fields(b.String())
+	for i, f := range fields {
+		fields[i] = singular(f)
+	}
+	return fields
+}
+
+// singular strips common English plural endings. It only needs to map a
+// plural and its singular to the same form, not produce real words.
+func singular(word string) string {
+	switch {
+	case len(word) > 4 && strings.HasSuffix(word, "ies"):
+		return strings.TrimSuffix(word, "ies") + "y"
+	case len(word) > 4 && (strings.HasSuffix(word, "ches") || strings.HasSuffix(word, "shes") ||
+		strings.HasSuffix(word, "xes") || strings.HasSuffix(word, "sses")):
+		return strings.TrimSuffix(word, "es")
+	case strings.HasSuffix(word, "ss") || strings.HasSuffix(word, "us") || strings.HasSuffix(word, "is"):
+		return word
+	case len(word) > 3 && strings.HasSuffix(word, "s"):
+		return strings.TrimSuffix(word, "s")
+	case len(word) > 1 && strings.HasSuffix(word, "s") && isDigits(word[:len(word)-1]):
+		// "11s" -> "11"
+		return strings.TrimSuffix(word, "s")
+	}
+	return word
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return s != ""
+}
+
+// similar reports whether two words are within a typo of each other: one
+// edit for ordinary words, two for long ones. Short words must match exactly.
+func similar(a, b string) bool {
+	if len(a) < 4 || len(b) < 4 {
+		return false
+	}
+	limit := 1
+	if len(a) >= 8 && len(b) >= 8 {
+		limit = 2
+	}
+	if d := len(a) - len(b); d > limit || -d > limit {
+		return false
+	}
+	return editDistance(a, b) <= limit
+}
+
+// editDistance is the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
@@ -0,0 +1,352 @@
+// Package services resolves what a patient calls a treatment ("chemical
+// peels", "micro needling", "fix my 11s") to a service the clinic books.
+//
+// Everything that turns free text or a patient-facing name into a bookable
+// service goes through here: preference extraction, the availability
+// fetchers, and the deposit and booking paths. Matching normalizes case,
+// punctuation, spacing and plurals, expands the clinic's aliases and a few
+// universal colloquialisms, and tolerates small typos, reporting how
+// confident it is so callers can ask the patient when it isn't.
+package services
+
+import (
+	"sort"
+	"strings"
+)
+
+// ConfidenceThreshold is the confidence from which a match is used without
+// asking the patient.
+const ConfidenceThreshold = 0.75
+
+// clarifyFloor is the lowest confidence at which a service is still offered
+// as a candidate in a clarification question.
+const clarifyFloor = 0.3
+
+// colloquialisms map what patients commonly say to a generic service term,
+// which is then resolved against the clinic's own menu and aliases. Keys and
+// values are normalized.
+var colloquialisms = map[string]string{
+	"lip flip":        "botox",
+	"11":              "botox",
+	"eleven":          "botox",
+	"frown line":      "botox",
+	"crow feet":       "botox",
+	"tox":             "botox",
+	"baby botox":      "botox",
+	"wrinkle relaxer": "botox",
+	"lip injection":   "lip filler",
+	"vampire facial":  "prp facial",
+	"laser hair":      "laser hair removal",
+}
+
+// Catalog is the set of services a clinic books and the names patients use
+// for them.
+type Catalog struct {
+	// Services are the clinic's bookable service names.
+	Services []string
+	// Aliases maps a patient-facing term to a service name.
+	Aliases map[string]string
+	// MenuItems maps a service name (any case) to its booking platform ID,
+	// e.g. a Moxie serviceMenuItemId.
+	MenuItems map[string]string
+}
+
+// Source is anything that can describe its service catalog, such as a
+// clinic config.
+type Source interface {
+	ServiceCatalog() Catalog
+}
+
+// Match is a candidate service for some text.
+type Match struct {
+	Service string
+	// ID is the service's booking platform ID, if it has one.
+	ID         string
+	Confidence float64
+}
+
+// Confident reports whether the match is good enough to act on.
+func (m Match) Confident() bool {
+	return m.Service != "" && m.Confidence >= ConfidenceThreshold
+}
+
+// ResolveService resolves rawText against src's catalog, returning the best
+// service, its Moxie service menu item ID (empty if the clinic has none),
+// and the match confidence in [0, 1]. Callers should compare confidence to
+// ConfidenceThreshold before acting on the service.
+func ResolveService(src Source, rawText string) (service, moxieID string, confidence float64) {
+	if src == nil {
+		return "", "", 0
+	}
+	m := src.ServiceCatalog().Resolve(rawText)
+	return m.Service, m.ID, m.Confidence
+}
+
+// Resolve returns the best match for text, or a zero Match when nothing in
+// the catalog resembles it.
+func (c Catalog) Resolve(text string) Match {
+	if matches := c.Candidates(text, 1); len(matches) > 0 {
+		return matches[0]
+	}
+	return Match{}
+}
+
+// Candidates returns up to limit services that text might mean, best first.
+func (c Catalog) Candidates(text string, limit int) []Match {
+	input := tokens(text)
+	if len(input) == 0 {
+		return nil
+	}
+	queries := [][]string{input}
+	for phrase, generic := range colloquialisms {
+		if rewritten, ok := replacePhrase(input, strings.Fields(phrase), strings.Fields(generic)); ok {
+			queries = append(queries, rewritten)
+		}
+	}
+
+	type scored struct {
+		Match
+		termTokens int
+	}
+	best := make(map[string]scored)
+	for _, entry := range c.entries() {
+		term := tokens(entry.term)
+		for _, q := range queries {
+			conf := score(q, term)
+			if conf <= 0 {
+				continue
+			}
+			key := strings.ToLower(entry.service)
+			cur, ok := best[key]
+			if !ok || conf > cur.Confidence || (conf == cur.Confidence && len(term) > cur.termTokens) {
+				best[key] = scored{Match{Service: entry.service, ID: c.menuItemID(entry.service), Confidence: conf}, len(term)}
+			}
+		}
+	}
+
+	out := make([]scored, 0, len(best))
+	for _, m := range best {
+		out = append(out, m)
+	}
+	// Ties go to the more specific term, so "lip filler" beats "filler".
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Confidence != out[j].Confidence {
+			return out[i].Confidence > out[j].Confidence
+		}
+		if out[i].termTokens != out[j].termTokens {
+			return out[i].termTokens > out[j].termTokens
+		}
+		return out[i].Service < out[j].Service
+	})
+	matches := make([]Match, 0, len(out))
+	for _, m := range out {
+		if limit > 0 && len(matches) == limit {
+			break
+		}
+		matches = append(matches, m.Match)
+	}
+	return matches
+}
+
+// ClarifyChoices lists the services the patient may have meant, as "A or B"
+// or "A, B, or C", when text doesn't confidently match one but a few come
+// close. It returns "" when there is a confident match or nothing to offer.
+func (c Catalog) ClarifyChoices(text string) string {
+	matches := c.Candidates(text, 3)
+	if len(matches) == 0 || matches[0].Confident() {
+		return ""
+	}
+	var names []string
+	for _, m := range matches {
+		if m.Confidence >= clarifyFloor {
+			names = append(names, m.Service)
+		}
+	}
+	return ClarificationChoices(names)
+}
+
+// ClarificationChoices joins candidate services for a "did you mean"
+// question. It needs at least two candidates.
+func ClarificationChoices(candidates []string) string {
+	switch len(candidates) {
+	case 0, 1:
+		return ""
+	case 2:
+		return candidates[0] + " or " + candidates[1]
+	default:
+		last := len(candidates) - 1
+		return strings.Join(candidates[:last], ", ") + ", or " + candidates[last]
+	}
+}
+
+// LookupAlias returns the service aliases maps term to, matching normalized
+// keys first and then the longest key contained in term (or containing it).
+// It returns "" when no alias applies.
+func LookupAlias(aliases map[string]string, term string) string {
+	key := Normalize(term)
+	if key == "" {
+		return ""
+	}
+	best, bestLen := "", 0
+	for aliasKey, service := range aliases {
+		if strings.TrimSpace(service) == "" {
+			continue
+		}
+		norm := Normalize(aliasKey)
+		if norm == "" {
+			continue
+		}
+		if norm == key || compact(norm) == compact(key) {
+			return service
+		}
+		if (strings.Contains(key, norm) || strings.Contains(norm, key)) && len(norm) > bestLen {
+			best, bestLen = service, len(norm)
+		}
+	}
+	return best
+}
+
+// Contains reports whether text mentions term as whole words, allowing for
+// case, punctuation, plurals and a word split in two ("micro needling").
+func Contains(text, term string) bool {
+	t := tokens(term)
+	return len(t) > 0 && matchRun(tokens(text), t, false) >= 0
+}
+
+type catalogEntry struct {
+	term    string
+	service string
+}
+
+// entries lists every name the catalog answers to with the service it books.
+func (c Catalog) entries() []catalogEntry {
+	var out []catalogEntry
+	seen := make(map[string]bool)
+	add := func(term, service string) {
+		term, service = strings.TrimSpace(term), strings.TrimSpace(service)
+		key := Normalize(term) + "|" + strings.ToLower(service)
+		if term == "" || service == "" || seen[key] {
+			return
+		}
+		seen[key] = true
+		out = append(out, catalogEntry{term: term, service: service})
+	}
+	for _, s := range c.Services {
+		add(s, s)
+	}
+	for name := range c.MenuItems {
+		add(name, c.displayName(name))
+	}
+	for alias, service := range c.Aliases {
+		add(alias, c.displayName(service))
+	}
+	return out
+}
+
+// displayName prefers the casing a service has in Services.
+func (c Catalog) displayName(name string) string {
+	for _, s := range c.Services {
+		if strings.EqualFold(strings.TrimSpace(s), strings.TrimSpace(name)) {
+			return s
+		}
+	}
+	return name
+}
+
+// menuItemID looks up a service's booking platform ID by normalized name.
+func (c Catalog) menuItemID(service string) string {
+	if id := c.MenuItems[strings.ToLower(service)]; id != "" {
+		return id
+	}
+	key := Normalize(service)
+	for name, id := range c.MenuItems {
+		if Normalize(name) == key {
+			return id
+		}
+	}
+	return ""
+}
+
+// score rates how well input matches term in [0, 1]. A full, in-order match
+// scores by typos and by how much of the input the term covers; otherwise
+// the words the two share score at most 0.6, below the threshold.
+func score(input, term []string) float64 {
+	if len(term) == 0 || len(input) == 0 {
+		return 0
+	}
+	if compact(strings.Join(input, " ")) == compact(strings.Join(term, " ")) {
+		return 1
+	}
+	if typos := matchRun(input, term, true); typos >= 0 {
+		quality := 1 - 0.15*float64(typos)/float64(len(term))
+		coverage := float64(len(term)) / float64(len(input))
+		if coverage > 1 {
+			coverage = 1
+		}
+		return quality * (0.9 + 0.1*coverage)
+	}
+	present := 0
+	for _, t := range term {
+		for _, w := range input {
+			if w == t || similar(w, t) {
+				present++
+				break
+			}
+		}
+	}
+	// Averaging both shares keeps a short, vague request ("peel") close to
+	// every service it could mean without letting one shared word in a long
+	// message count for much.
+	p := float64(present)
+	return 0.6 * (p/float64(len(term)) + p/float64(len(input))) / 2
+}
+
+// matchRun finds term as a contiguous run in input, where a term word may
+// also match two adjacent input words joined or, if fuzzy, a near-miss. It
+// returns the fewest typos used, or -1 when term isn't there.
+func matchRun(input, term []string, fuzzy bool) int {
+	best := -1
+	for start := range input {
+		i, typos, ok := start, 0, true
+		for _, t := range term {
+			switch {
+			case i < len(input) && input[i] == t:
+				i++
+			case i+1 < len(input) && input[i]+input[i+1] == t:
+				i += 2
+			case fuzzy && i < len(input) && similar(input[i], t):
+				i++
+				typos++
+			default:
+				ok = false
+			}
+			if !ok {
+				break
+			}
+		}
+		if ok && (best < 0 || typos < best) {
+			best = typos
+		}
+	}
+	return best
+}
+
+// replacePhrase swaps the first occurrence of phrase in input for with.
+func replacePhrase(input, phrase, with []string) ([]string, bool) {
+	for start := 0; start+len(phrase) <= len(input); start++ {
+		match := true
+		for j, p := range phrase {
+			if input[start+j] != p {
+				match = false
+				break
+			}
+		}
+		if match {
+			out := make([]string, 0, len(input)-len(phrase)+len(with))
+			out = append(out, input[:start]...)
+			out = append(out, with...)
+			return append(out, input[start+len(phrase):]...), true
+		}
+	}
+	return nil, false
+}
@@ -0,0 +1,175 @@
+package services
+
+import "testing"
+
+var testCatalog = Catalog{
+	Services: []string{
+		"Botox", "Dysport", "Lip Filler", "Dermal Filler", "Chemical Peel", "Perfect Derma Peel",
+		"Microneedling", "HydraFacial", "Laser Hair Removal", "Kybella", "PRP Facial",
+	},
+	Aliases: map[string]string{
+		"wrinkle relaxers": "Botox",
+		"lip augmentation": "Lip Filler",
+		"double chin":      "Kybella",
+	},
+	MenuItems: map[string]string{
+		"botox":         "20424",
+		"lip filler":    "20425",
+		"chemical peel": "20430",
+		"microneedling": "20431",
+	},
+}
+
+type catalogSource Catalog
+
+func (c catalogSource) ServiceCatalog() Catalog { return Catalog(c) }
+
+func TestResolveService(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string // "" means no confident match
+	}{
+		// Exact names, case and punctuation
+		{"Botox", "Botox"},
+		{"botox", "Botox"},
+		{"BOTOX!!", "Botox"},
+		{"Dysport", "Dysport"},
+		{"Microneedling.", "Microneedling"},
+		{"kybella", "Kybella"},
+		{"perfect derma peel", "Perfect Derma Peel"},
+		{"laser hair removal", "Laser Hair Removal"},
+		// Plurals
+		{"lip fillers", "Lip Filler"},
+		{"dermal fillers", "Dermal Filler"},
+		{"chemical peels", "Chemical Peel"},
+		{"hydrafacials", "HydraFacial"},
+		{"prp facials", "PRP Facial"},
+		// Spacing and hyphens
+		{"micro needling", "Microneedling"},
+		{"micro-needling", "Microneedling"},
+		{"hydra facial", "HydraFacial"},
+		{"Hydra-Facial", "HydraFacial"},
+		// Misspellings
+		{"botx", "Botox"},
+		{"bottox", "Botox"},
+		{"disport", "Dysport"},
+		{"chemical peal", "Chemical Peel"},
+		{"microneedeling", "Microneedling"},
+		{"kybela", "Kybella"},
+		{"lip filer", "Lip Filler"},
+		// Clinic aliases
+		{"wrinkle relaxer", "Botox"},
+		{"lip augmentation", "Lip Filler"},
+		{"double chin", "Kybella"},
+		// Colloquialisms
+		{"lip flip", "Botox"},
+		{"fix my 11s", "Botox"},
+		{"my elevens", "Botox"},
+		{"crow's feet", "Botox"},
+		{"frown lines", "Botox"},
+		{"tox", "Botox"},
+		{"baby botox", "Botox"},
+		{"lip injections", "Lip Filler"},
+		{"vampire facial", "PRP Facial"},
+		{"laser hair", "Laser Hair Removal"},
+		// Inside a sentence
+		{"I'd like to book botox next week", "Botox"},
+		{"can I get chemical peels?", "Chemical Peel"},
+		{"the perfect derma peel please", "Perfect Derma Peel"},
+		// Too vague or not on the menu
+		{"peel", ""},
+		{"filler", ""},
+		{"tattoo removal", ""},
+		{"hello", ""},
+		{"", ""},
+	}
+	for _, tc := range tests {
+		t.Run(tc.input, func(t *testing.T) {
+			service, _, confidence := ResolveService(catalogSource(testCatalog), tc.input)
+			confident := confidence >= ConfidenceThreshold
+			switch {
+			case tc.want == "" && confident:
+				t.Fatalf("ResolveService(%q) = %q (%.2f), want no confident match", tc.input, service, confidence)
+			case tc.want != "" && (!confident || service != tc.want):
+				t.Fatalf("ResolveService(%q) = %q (%.2f), want %q", tc.input, service, confidence, tc.want)
+			}
+		})
+	}
+}
+
+func TestResolveService_MenuItemID(t *testing.T) {
+	if _, id, _ := ResolveService(catalogSource(testCatalog), "micro needling"); id != "20431" {
+		t.Fatalf("micro needling id = %q, want 20431", id)
+	}
+	if _, id, _ := ResolveService(catalogSource(testCatalog), "lip flip"); id != "20424" {
+		t.Fatalf("lip flip id = %q, want 20424", id)
+	}
+	if _, id, _ := ResolveService(catalogSource(testCatalog), "Kybella"); id != "" {
+		t.Fatalf("Kybella id = %q, want none", id)
+	}
+	if service, _, confidence := ResolveService(nil, "botox"); service != "" || confidence != 0 {
+		t.Fatalf("nil source resolved %q", service)
+	}
+}
+
+func TestCatalog_ClarifyChoices(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"peel", "Chemical Peel or Perfect Derma Peel"},
+		{"fillers", "Dermal Filler or Lip Filler"},
+		{"botox", ""},
+		{"tattoo removal", ""},
+	}
+	for _, tc := range tests {
+		if got := testCatalog.ClarifyChoices(tc.input); got != tc.want {
+			t.Errorf("ClarifyChoices(%q) = %q, want %q", tc.input, got, tc.want)
+		}
+	}
+	if got := ClarificationChoices([]string{"Botox", "Dysport", "Xeomin"}); got != "Botox, Dysport, or Xeomin" {
+		t.Errorf("three candidates = %q", got)
+	}
+}
+
+func TestNormalizeAndContains(t *testing.T) {
+	for in, want := range map[string]string{
+		"Chemical Peels!": "chemical peel",
+		"Crow's Feet":     "crow feet",
+		"fix my 11s":      "fix my 11",
+		"  Lip   Fillers": "lip filler",
+		"therapies":       "therapy",
+		"glass skin":      "glass skin",
+	} {
+		if got := Normalize(in); got != want {
+			t.Errorf("Normalize(%q) = %q, want %q", in, got, want)
+		}
+	}
+	if !Contains("i want micro needling next week", "microneedling") {
+		t.Errorf("expected a split word to match")
+	}
+	if Contains("i have multiple questions", "ipl") {
+		t.Errorf("expected whole-word matching")
+	}
+}
+
+func TestLookupAlias(t *testing.T) {
+	aliases := map[string]string{
+		"wrinkle relaxers": "Tox",
+		"microneedling":    "Collagen Induction",
+		"lip filler":       "Dermal Filler - Lips",
+		"filler":           "Dermal Filler",
+	}
+	for in, want := range map[string]string{
+		"Wrinkle Relaxer":       "Tox",
+		"micro needling":        "Collagen Induction",
+		"lip fillers":           "Dermal Filler - Lips",
+		"lip filler treatment":  "Dermal Filler - Lips",
+		"cheek fillers":         "Dermal Filler",
+		"something unconnected": "",
+	} {
+		if got := LookupAlias(aliases, in); got != want {
+			t.Errorf("LookupAlias(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
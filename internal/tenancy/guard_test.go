@@ -0,0 +1,170 @@
+package tenancy
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// guardedPackages hold repositories whose org reads go through OrgScope.
+// Add a package once its repositories have moved off raw org IDs.
+var guardedPackages = []string{"../leads", "../payments", "../bookings", "../conversation"}
+
+// guardedStores are stores checked like repositories though their names
+// don't end in "Repository".
+var guardedStores = map[string]bool{
+	"ConversationStore":   true,
+	"CallbackTaskStore":   true,
+	"PGCallbackTaskStore": true,
+}
+
+// unscopedMethods are repository methods allowed to skip OrgScope, keyed
+// "package.Method", with why.
+var unscopedMethods = map[string]string{
+	"leads.GetOrCreateByPhone":    "write path: stamps a concrete org on the lead it creates",
+	"leads.SaveImported":          "write path: stamps a concrete org on the lead it creates",
+	"leads.GetByBookingSessionID": "booking platform callbacks carry only the session ID",
+
+	"payments.CreateIntent":              "write path: stamps a concrete org on the payment it creates",
+	"payments.GetByID":                   "checkout and webhook callbacks carry only our payment ID",
+	"payments.UpdateStatusByID":          "checkout and webhook callbacks carry only our payment ID",
+	"payments.GetByProviderRef":          "provider webhooks carry only the provider's reference",
+	"payments.GetCheckoutURLByShortCode": "public short links carry only the code",
+
+	"bookings.CreateConfirmed": "write path: stamps a concrete org on the booking it creates",
+
+	"conversation.UpdateMessageStatusByProviderID": "delivery receipts carry only the provider message ID",
+	"conversation.HasAssistantMessage":             "reports only whether a reply exists; shares its checker with the transcript store",
+	"conversation.GetSharedDocuments":              "shared knowledge snippets belong to no org",
+}
+
+// scopedReadRE matches repository methods that read, update or delete by
+// org.
+var scopedReadRE = regexp.MustCompile(`^(Get|List|Find|Has|Update|Delete)`)
+
+// TestRepositoriesTakeOrgScope fails when an exported repository method in a
+// guarded package takes a raw org ID, or reads rows without an OrgScope.
+func TestRepositoriesTakeOrgScope(t *testing.T) {
+	checked := 0
+	for _, dir := range guardedPackages {
+		pkg := filepath.Base(dir)
+		files, err := filepath.Glob(filepath.Join(dir, "*.go"))
+		if err != nil {
+			t.Fatalf("glob %s: %v", dir, err)
+		}
+		fset := token.NewFileSet()
+		for _, path := range files {
+			if strings.HasSuffix(path, "_test.go") {
+				continue
+			}
+			src, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("read %s: %v", path, err)
+			}
+			file, err := parser.ParseFile(fset, path, src, 0)
+			if err != nil {
+				t.Fatalf("parse %s: %v", path, err)
+			}
+			for _, m := range repositoryMethods(file) {
+				checked++
+				key := pkg + "." + m.name
+				if _, ok := unscopedMethods[key]; ok {
+					continue
+				}
+				pos := fset.Position(m.pos)
+				if takesRawOrgID(m.params) {
+					t.Errorf("%s: %s takes a raw org ID; take tenancy.OrgScope", pos, key)
+				}
+				if scopedReadRE.MatchString(m.name) && !takesOrgScope(m.params) {
+					t.Errorf("%s: %s reads rows without a tenancy.OrgScope", pos, key)
+				}
+			}
+		}
+	}
+	if checked == 0 {
+		t.Fatal("found no repository methods to check")
+	}
+}
+
+type repoMethod struct {
+	name   string
+	params *ast.FieldList
+	pos    token.Pos
+}
+
+// repositoryMethods lists the exported methods of *Repository types and
+// interfaces, and of guardedStores, declared in file.
+func repositoryMethods(file *ast.File) []repoMethod {
+	var out []repoMethod
+	for _, decl := range file.Decls {
+		switch d := decl.(type) {
+		case *ast.FuncDecl:
+			if d.Recv == nil || !d.Name.IsExported() || !guardedType(receiverName(d.Recv)) {
+				continue
+			}
+			out = append(out, repoMethod{name: d.Name.Name, params: d.Type.Params, pos: d.Pos()})
+		case *ast.GenDecl:
+			for _, spec := range d.Specs {
+				ts, ok := spec.(*ast.TypeSpec)
+				if !ok || !guardedType(ts.Name.Name) {
+					continue
+				}
+				iface, ok := ts.Type.(*ast.InterfaceType)
+				if !ok {
+					continue
+				}
+				for _, field := range iface.Methods.List {
+					fn, ok := field.Type.(*ast.FuncType)
+					if !ok || len(field.Names) == 0 || !field.Names[0].IsExported() {
+						continue
+					}
+					out = append(out, repoMethod{name: field.Names[0].Name, params: fn.Params, pos: field.Pos()})
+				}
+			}
+		}
+	}
+	return out
+}
+
+func guardedType(name string) bool {
+	return strings.HasSuffix(name, "Repository") || guardedStores[name]
+}
+
+func receiverName(recv *ast.FieldList) string {
+	if len(recv.List) == 0 {
+		return ""
+	}
+	typ := recv.List[0].Type
+	if star, ok := typ.(*ast.StarExpr); ok {
+		typ = star.X
+	}
+	if ident, ok := typ.(*ast.Ident); ok {
+		return ident.Name
+	}
+	return ""
+}
+
+func takesRawOrgID(params *ast.FieldList) bool {
+	for _, field := range params.List {
+		for _, name := range field.Names {
+			if strings.EqualFold(name.Name, "orgID") || strings.EqualFold(name.Name, "clinicID") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func takesOrgScope(params *ast.FieldList) bool {
+	for _, field := range params.List {
+		if sel, ok := field.Type.(*ast.SelectorExpr); ok && sel.Sel.Name == "OrgScope" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,117 @@
+package tenancy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	// ErrNoScope is returned by repositories handed the zero OrgScope, so a
+	// caller that forgot the org gets an error instead of every clinic's rows.
+	ErrNoScope = errors.New("tenancy: missing org scope")
+	// ErrScopeMismatch is returned when a fetched row belongs to another org
+	// than the scope it was read under.
+	ErrScopeMismatch = errors.New("tenancy: row outside org scope")
+)
+
+// OrgScope is the set of tenants a repository call may read. Use ForOrg for
+// one clinic and AllOrgs for admin and background jobs that span clinics; the
+// zero value scopes nothing and repositories reject it.
+type OrgScope struct {
+	orgID string
+	all   bool
+}
+
+// ForOrg scopes a call to one org. A blank orgID gives the zero scope.
+func ForOrg(orgID string) OrgScope {
+	return OrgScope{orgID: strings.TrimSpace(orgID)}
+}
+
+// AllOrgs scopes a call to every org. Only admin "all orgs" operations and
+// cross-tenant jobs should use it.
+func AllOrgs() OrgScope {
+	return OrgScope{all: true}
+}
+
+// OrgID is the scoped org, empty for AllOrgs and the zero scope.
+func (s OrgScope) OrgID() string {
+	return s.orgID
+}
+
+// IsAll reports whether the scope spans every org.
+func (s OrgScope) IsAll() bool {
+	return s.all
+}
+
+// Valid reports whether the scope names an org or is AllOrgs.
+func (s OrgScope) Valid() bool {
+	return s.all || s.orgID != ""
+}
+
+// Err returns ErrNoScope for the zero scope.
+func (s OrgScope) Err() error {
+	if !s.Valid() {
+		return ErrNoScope
+	}
+	return nil
+}
+
+// SingleOrg returns the one org the scope names. Queries keyed by a single
+// org reject AllOrgs as well as the zero scope.
+func (s OrgScope) SingleOrg() (string, error) {
+	if err := s.Err(); err != nil {
+		return "", err
+	}
+	if s.all {
+		return "", fmt.Errorf("%w: query needs one org, got %s", ErrNoScope, s)
+	}
+	return s.orgID, nil
+}
+
+// Allows reports whether a row owned by orgID is inside the scope.
+func (s OrgScope) Allows(orgID string) bool {
+	return s.all || (s.orgID != "" && s.orgID == orgID)
+}
+
+// Check asserts a single fetched row is inside the scope. Repositories call
+// it after the scan so a query that lost its org filter fails loudly instead
+// of leaking another clinic's row.
+func (s OrgScope) Check(rowOrgID string) error {
+	if err := s.Err(); err != nil {
+		return err
+	}
+	if !s.Allows(rowOrgID) {
+		return fmt.Errorf("%w: scope %s, row org %s", ErrScopeMismatch, s, rowOrgID)
+	}
+	return nil
+}
+
+// Where returns the SQL condition limiting column to the scope, with its
+// placeholder numbered argNum. AllOrgs needs no condition and returns "".
+func (s OrgScope) Where(column string, argNum int) (string, []any) {
+	if s.all {
+		return "", nil
+	}
+	return fmt.Sprintf("%s = $%d", column, argNum), []any{s.orgID}
+}
+
+func (s OrgScope) String() string {
+	switch {
+	case s.all:
+		return "all-orgs"
+	case s.orgID == "":
+		return "unscoped"
+	default:
+		return "org:" + s.orgID
+	}
+}
+
+// ScopeFromContext returns the request's org scope, set by the router from
+// the authenticated principal or route. Requests without an org get the zero
+// scope; handlers that mean every org must ask for AllOrgs explicitly.
+func ScopeFromContext(ctx context.Context) OrgScope {
+	orgID, _ := OrgIDFromContext(ctx)
+	return ForOrg(orgID)
+}
@@ -0,0 +1,57 @@
+package tenancy
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestOrgScopeCheck(t *testing.T) {
+	if err := ForOrg("org-a").Check("org-a"); err != nil {
+		t.Fatalf("same org: %v", err)
+	}
+	if err := ForOrg("org-a").Check("org-b"); !errors.Is(err, ErrScopeMismatch) {
+		t.Fatalf("other org err = %v, want ErrScopeMismatch", err)
+	}
+	if err := AllOrgs().Check("org-b"); err != nil {
+		t.Fatalf("all orgs: %v", err)
+	}
+	if err := ForOrg("  ").Check(""); !errors.Is(err, ErrNoScope) {
+		t.Fatalf("blank org err = %v, want ErrNoScope", err)
+	}
+	if err := (OrgScope{}).Check("org-a"); !errors.Is(err, ErrNoScope) {
+		t.Fatalf("zero scope err = %v, want ErrNoScope", err)
+	}
+}
+
+func TestOrgScopeWhere(t *testing.T) {
+	cond, args := ForOrg("org-a").Where("org_id", 3)
+	if cond != "org_id = $3" || len(args) != 1 || args[0] != "org-a" {
+		t.Fatalf("Where = %q %v", cond, args)
+	}
+	if cond, args := AllOrgs().Where("org_id", 3); cond != "" || args != nil {
+		t.Fatalf("all orgs Where = %q %v, want no condition", cond, args)
+	}
+}
+
+func TestOrgScopeSingleOrg(t *testing.T) {
+	if org, err := ForOrg("org-a").SingleOrg(); err != nil || org != "org-a" {
+		t.Fatalf("SingleOrg = %q, %v", org, err)
+	}
+	if _, err := AllOrgs().SingleOrg(); !errors.Is(err, ErrNoScope) {
+		t.Fatalf("all orgs err = %v, want ErrNoScope", err)
+	}
+	if _, err := (OrgScope{}).SingleOrg(); !errors.Is(err, ErrNoScope) {
+		t.Fatalf("zero scope err = %v, want ErrNoScope", err)
+	}
+}
+
+func TestScopeFromContext(t *testing.T) {
+	if s := ScopeFromContext(context.Background()); s.Valid() {
+		t.Fatalf("expected zero scope without an org, got %s", s)
+	}
+	s := ScopeFromContext(WithOrgID(context.Background(), "org-a"))
+	if s.OrgID() != "org-a" || s.IsAll() {
+		t.Fatalf("expected org-a scope, got %s", s)
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/wolfman30/medspa-ai-platform/internal/clinic"
 	"github.com/wolfman30/medspa-ai-platform/internal/conversation"
 	"github.com/wolfman30/medspa-ai-platform/internal/messaging"
+	"github.com/wolfman30/medspa-ai-platform/internal/messaging/templates"
 )
 
 // PostCallSMSService sends a follow-up SMS after a voice AI call ends.
@@ -65,9 +66,10 @@ func (s *PostCallSMSService) SendPostCallSMS(ctx context.Context, callerPhone, c
 	depositAmount := 50 // default
 	smsFrom := clinicPhone
 
+	var cfg *clinic.Config
 	if s.clinicStore != nil && orgID != "" {
-		cfg, err := s.clinicStore.Get(ctx, orgID)
-		if err == nil && cfg != nil {
+		if got, err := s.clinicStore.Get(ctx, orgID); err == nil && got != nil {
+			cfg = got
 			if cfg.Name != "" {
 				clinicName = cfg.Name
 			}
@@ -85,22 +87,20 @@ func (s *PostCallSMSService) SendPostCallSMS(ctx context.Context, callerPhone, c
 	}
 
 	// Build the follow-up message
-	msg := fmt.Sprintf(
-		"Hi! This is Lauren from %s 😊 Thanks for calling! "+
-			"To confirm your appointment, please complete the $%d deposit here: "+
-			"https://app.aiwolfsolutions.com/deposit/%s "+
-			"If you have any questions, just reply to this text!",
-		clinicName, depositAmount, orgID,
-	)
+	msg := cfg.RenderTemplate(templates.VoicePostCallFollow, templates.Params{
+		"clinic_name":    clinicName,
+		"deposit_amount": fmt.Sprintf("$%d", depositAmount),
+		"deposit_url":    "https://app.aiwolfsolutions.com/deposit/" + orgID,
+	})
 
 	err := s.messenger.SendReply(ctx, conversation.OutboundReply{
 		OrgID: orgID,
 		To:    callerPhone,
 		From:  smsFrom,
-		Body:  msg,
-		Metadata: map[string]string{
+		Body:  msg.Body,
+		Metadata: msg.Stamp(map[string]string{
 			"source": "voice_ai_post_call",
-		},
+		}),
 	})
 	if err != nil {
 		return fmt.Errorf("send post-call SMS: %w", err)
@@ -159,23 +159,12 @@ func filterSlotByDay(t time.Time, prefDays string) bool {
 }
 
 func resolveRequestedServiceForBoulevard(cfg *clinic.Config, requested string) (string, bool) {
-	normalized := strings.ToLower(strings.TrimSpace(requested))
-	if normalized == "" {
+	if strings.TrimSpace(requested) == "" {
 		return "", false
 	}
-
-	if cfg.ServiceAliases != nil {
-		if alias, ok := cfg.ServiceAliases[normalized]; ok && strings.TrimSpace(alias) != "" {
-			return alias, true
-		}
-	}
-
-	for _, svc := range cfg.Services {
-		if strings.EqualFold(strings.TrimSpace(svc), requested) {
-			return svc, true
-		}
+	if match := cfg.ServiceCatalog().Resolve(requested); match.Confident() {
+		return match.Service, true
 	}
-
 	return requested, false
 }
 
@@ -245,16 +234,7 @@ func (h *ToolHandler) checkMoxieAvailability(ctx context.Context, cfg *clinic.Co
 	}
 
 	// Resolve service to Moxie service menu item ID
-	serviceMenuItemID := ""
-	if cfg.MoxieConfig.ServiceMenuItems != nil {
-		normalized := strings.ToLower(strings.TrimSpace(service))
-		serviceMenuItemID = cfg.MoxieConfig.ServiceMenuItems[normalized]
-		if serviceMenuItemID == "" && cfg.ServiceAliases != nil {
-			if alias, ok := cfg.ServiceAliases[normalized]; ok {
-				serviceMenuItemID = cfg.MoxieConfig.ServiceMenuItems[strings.ToLower(alias)]
-			}
-		}
-	}
+	serviceMenuItemID := cfg.ServiceMenuItemID(strings.TrimSpace(service))
 	if serviceMenuItemID == "" {
 		return fmt.Sprintf(`{"message": "I couldn't find the service '%s' in our booking system. Could you tell me more about what you're looking for?"}`, service), nil
 	}
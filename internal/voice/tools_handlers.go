@@ -7,11 +7,14 @@ import (
 	"log/slog"
 
 	"github.com/google/uuid"
+
 	"github.com/wolfman30/medspa-ai-platform/internal/clinic"
 	"github.com/wolfman30/medspa-ai-platform/internal/conversation"
 	"github.com/wolfman30/medspa-ai-platform/internal/emr/moxie"
 	"github.com/wolfman30/medspa-ai-platform/internal/leads"
+	"github.com/wolfman30/medspa-ai-platform/internal/messaging/templates"
 	"github.com/wolfman30/medspa-ai-platform/internal/payments"
+	"github.com/wolfman30/medspa-ai-platform/internal/tenancy"
 )
 
 // CheckoutLinkCreator creates payment checkout links.
@@ -203,7 +206,7 @@ func (h *ToolHandler) saveQualification(ctx context.Context, input json.RawMessa
 	}
 
 	// Update scheduling preferences
-	err = h.deps.LeadsRepo.UpdateSchedulingPreferences(ctx, lead.ID, leads.SchedulingPreferences{
+	err = h.deps.LeadsRepo.UpdateSchedulingPreferences(ctx, tenancy.ForOrg(h.orgID), lead.ID, leads.SchedulingPreferences{
 		Name:               params.Name,
 		ServiceInterest:    params.Service,
 		PatientType:        params.PatientType,
@@ -284,22 +287,20 @@ func (h *ToolHandler) SendDepositSMS(ctx context.Context, orgID, callerPhone str
 		depositURL = "https://buy.stripe.com/test_7sY4gBa7Z4Cg1DSfil7N600"
 	}
 
-	body := fmt.Sprintf(
-		"Hi! This is Lauren from %s 😊\n\n"+
-			"Here's your $%d deposit link to secure your appointment:\n%s\n\n"+
-			"Once you complete the payment, I'll confirm your booking. "+
-			"If you have any questions, just reply to this text!",
-		clinicName, depositAmount, depositURL,
-	)
+	body := cfg.RenderTemplate(templates.VoiceDepositLink, templates.Params{
+		"clinic_name":    clinicName,
+		"deposit_amount": fmt.Sprintf("$%d", depositAmount),
+		"deposit_url":    depositURL,
+	})
 
 	err = h.deps.Messenger.SendReply(ctx, conversation.OutboundReply{
 		OrgID: orgID,
 		To:    callerPhone,
 		From:  fromNumber,
-		Body:  body,
-		Metadata: map[string]string{
+		Body:  body.Body,
+		Metadata: body.Stamp(map[string]string{
 			"source": "voice_ai_deposit",
-		},
+		}),
 	})
 	if err != nil {
 		return fmt.Errorf("SendDepositSMS: send deposit SMS: %w", err)
@@ -13,6 +13,7 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/wolfman30/medspa-ai-platform/internal/conversation"
+	"github.com/wolfman30/medspa-ai-platform/internal/messaging/compliance"
 	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
 	"golang.org/x/net/websocket"
 )
@@ -180,6 +181,10 @@ func (h *Handler) processMessage(ctx context.Context, orgID, sessionID, text str
 	convID := ConversationID(orgID, sessionID)
 	jobID := uuid.New().String()
 
+	// Card numbers and SSNs never reach the transcript; the pipeline sees the
+	// placeholders and sends its safety reply.
+	text, _ = compliance.RedactSensitiveNumbers(text)
+
 	// Store inbound message
 	if h.transcript != nil {
 		_ = h.transcript.Append(ctx, convID, conversation.SMSTranscriptMessage{
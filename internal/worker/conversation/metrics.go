@@ -0,0 +1,56 @@
+package conversationworker
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/wolfman30/medspa-ai-platform/internal/conversation"
+	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
+)
+
+// metricsHandler serves the conversation and worker metrics on /metrics and
+// a liveness check on /healthz.
+func metricsHandler(registry *prometheus.Registry) http.Handler {
+	conversation.RegisterMetrics(registry)
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.HandlerFor(registry, promhttp.HandlerOpts{}))
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		_, _ = w.Write([]byte("ok"))
+	})
+	return mux
+}
+
+// startMetricsServer serves metricsHandler on port until the returned stop
+// function is called. An empty port disables the listener. Failing to bind
+// is logged rather than fatal so metrics never keep the worker from running.
+func startMetricsServer(port string, logger *logging.Logger) (stop func()) {
+	if port == "" {
+		logger.Info("worker metrics listener disabled (WORKER_METRICS_PORT not set)")
+		return func() {}
+	}
+	srv := &http.Server{
+		Addr:              net.JoinHostPort("", port),
+		Handler:           metricsHandler(prometheus.NewRegistry()),
+		ReadHeaderTimeout: 5 * time.Second,
+		WriteTimeout:      15 * time.Second,
+	}
+	go func() {
+		logger.Info("worker metrics listening", "addr", srv.Addr)
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Error("worker metrics listener failed", "error", err)
+		}
+	}()
+	return func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := srv.Shutdown(ctx); err != nil {
+			logger.Warn("worker metrics listener shutdown failed", "error", err)
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package conversationworker
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func TestMetricsHandler(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	// The handler may be built more than once against one registry.
+	metricsHandler(registry)
+	srv := httptest.NewServer(metricsHandler(registry))
+	defer srv.Close()
+
+	get := func(path string) (int, string) {
+		t.Helper()
+		resp, err := http.Get(srv.URL + path)
+		if err != nil {
+			t.Fatalf("GET %s: %v", path, err)
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return resp.StatusCode, string(body)
+	}
+
+	if status, body := get("/healthz"); status != http.StatusOK || body != "ok" {
+		t.Fatalf("/healthz = %d %q, want 200 ok", status, body)
+	}
+	status, body := get("/metrics")
+	if status != http.StatusOK {
+		t.Fatalf("/metrics status = %d", status)
+	}
+	if !strings.Contains(body, "medspa_conversation_memory_queue_depth") {
+		t.Fatalf("expected conversation metrics in /metrics, got:\n%s", body)
+	}
+}
@@ -14,13 +14,12 @@ import (
 	"github.com/jackc/pgx/v5/stdlib"
 	"github.com/wolfman30/medspa-ai-platform/cmd/mainconfig"
 	appbootstrap "github.com/wolfman30/medspa-ai-platform/internal/app/bootstrap"
-	"github.com/wolfman30/medspa-ai-platform/internal/bookings"
 	"github.com/wolfman30/medspa-ai-platform/internal/clinicdata"
 	auditcompliance "github.com/wolfman30/medspa-ai-platform/internal/compliance"
 	appconfig "github.com/wolfman30/medspa-ai-platform/internal/config"
 	"github.com/wolfman30/medspa-ai-platform/internal/conversation"
+	"github.com/wolfman30/medspa-ai-platform/internal/convstream"
 	"github.com/wolfman30/medspa-ai-platform/internal/events"
-	"github.com/wolfman30/medspa-ai-platform/internal/leads"
 	"github.com/wolfman30/medspa-ai-platform/internal/messaging"
 	"github.com/wolfman30/medspa-ai-platform/internal/notify"
 	"github.com/wolfman30/medspa-ai-platform/internal/payments"
@@ -72,18 +71,23 @@ func Run(ctx context.Context, cfg *appconfig.Config, logger *logging.Logger) err
 	dynamoClient := dynamodb.NewFromConfig(awsConfig)
 	jobStore := conversation.NewJobStore(dynamoClient, cfg.ConversationJobsTable, logger)
 
-	var leadsRepo leads.Repository
-	var paymentChecker *payments.Repository
-	if dbPool != nil {
-		leadsRepo = leads.NewPostgresRepository(dbPool)
-		paymentChecker = payments.NewRepository(dbPool, nil)
+	cipher, err := appbootstrap.BuildPIICipher(cfg, logger)
+	if err != nil {
+		return err
 	}
 	msgStore := messaging.NewStore(dbPool)
+	if msgStore != nil {
+		msgStore.SetCipher(cipher)
+	}
 
-	processor, err := appbootstrap.BuildConversationService(ctx, cfg, leadsRepo, paymentChecker, auditSvc, logger)
+	redisClient := appbootstrap.BuildRedisClient(ctx, cfg, logger, true)
+	convDeps := appbootstrap.NewConversationDeps(cfg, dbPool, redisClient, cipher, auditSvc, logger)
+	leadsRepo, paymentChecker, bookingBridge, crmEvents := convDeps.LeadsRepo, convDeps.Payments, convDeps.Bookings, convDeps.CRMEvents
+	assembly, err := appbootstrap.AssembleConversation(ctx, "worker", convDeps)
 	if err != nil {
 		return fmt.Errorf("failed to configure conversation service: %w", err)
 	}
+	processor, report := assembly.Processor, assembly.Report
 	supervisor, err := appbootstrap.BuildSupervisor(ctx, cfg, logger)
 	if err != nil {
 		return fmt.Errorf("failed to configure supervisor: %w", err)
@@ -94,9 +98,10 @@ func Run(ctx context.Context, cfg *appconfig.Config, logger *logging.Logger) err
 		messengerProvider string
 		messengerReason   string
 		depositSender     conversation.DepositSender
+		paymentClaims     conversation.PaymentClaimChecker
 	)
 	convStore := appbootstrap.BuildConversationStore(sqlDB, cfg, logger, false)
-	redisClient := appbootstrap.BuildRedisClient(ctx, cfg, logger, true)
+	convStore.SetUpdatePublisher(convstream.NewPublisher(redisClient), logger)
 	smsTranscript := appbootstrap.BuildSMSTranscriptStore(redisClient)
 	clinicStore := appbootstrap.BuildClinicStore(redisClient)
 	messenger, messengerProvider, messengerReason = appbootstrap.BuildOutboundMessenger(
@@ -117,6 +122,7 @@ func Run(ctx context.Context, cfg *appconfig.Config, logger *logging.Logger) err
 			"preference", cfg.SMSProvider,
 			"reason", messengerReason,
 		)
+		report.Add(appbootstrap.StackNotifications, appbootstrap.Disabled("operator_sms", "no outbound messenger: "+messengerReason))
 	}
 
 	orgRouting := map[string]string{}
@@ -127,47 +133,6 @@ func Run(ctx context.Context, cfg *appconfig.Config, logger *logging.Logger) err
 	}
 	var numberResolver payments.OrgNumberResolver = messaging.NewStaticOrgResolver(orgRouting)
 
-	var bookingBridge conversation.BookingServiceAdapter
-	var oauthSvc *payments.SquareOAuthService
-	if dbPool != nil {
-		repo := bookings.NewRepository(dbPool)
-		bookingBridge = conversation.BookingServiceAdapter{
-			Service: bookings.NewService(repo, logger),
-		}
-
-		var squareSvc *payments.SquareCheckoutService
-		if cfg.SquareAccessToken != "" || (cfg.SquareClientID != "" && cfg.SquareClientSecret != "" && cfg.SquareOAuthRedirectURI != "") {
-			usePaymentLinks := payments.UsePaymentLinks(cfg.SquareCheckoutMode, cfg.SquareSandbox)
-			squareSvc = payments.NewSquareCheckoutService(cfg.SquareAccessToken, cfg.SquareLocationID, cfg.SquareSuccessURL, cfg.SquareCancelURL, logger).
-				WithBaseURL(cfg.SquareBaseURL).
-				WithPaymentLinks(usePaymentLinks).
-				WithPaymentLinkFallback(cfg.SquareCheckoutAllowFallback)
-		}
-		if cfg.SquareClientID != "" && cfg.SquareClientSecret != "" && cfg.SquareOAuthRedirectURI != "" {
-			oauthSvc = payments.NewSquareOAuthService(
-				payments.SquareOAuthConfig{
-					ClientID:     cfg.SquareClientID,
-					ClientSecret: cfg.SquareClientSecret,
-					RedirectURI:  cfg.SquareOAuthRedirectURI,
-					Sandbox:      cfg.SquareSandbox,
-				},
-				dbPool,
-				logger,
-			)
-			squareSvc = squareSvc.WithCredentialsProvider(oauthSvc)
-			numberResolver = payments.NewDBOrgNumberResolver(oauthSvc, numberResolver)
-			refreshWorker := payments.NewTokenRefreshWorker(oauthSvc, logger)
-			go refreshWorker.Start(ctx)
-		}
-		if squareSvc != nil {
-			outbox := events.NewOutboxStore(dbPool)
-			depositSender = conversation.NewDepositDispatcher(paymentChecker, squareSvc, outbox, messenger, numberResolver, leadsRepo, smsTranscript, convStore, logger)
-			logger.Info("deposit sender initialized for async workers", "has_oauth", oauthSvc != nil, "square_location_id", cfg.SquareLocationID)
-		} else {
-			logger.Warn("deposit sender NOT initialized for async workers", "has_square_token", cfg.SquareAccessToken != "", "has_oauth", oauthSvc != nil)
-		}
-	}
-
 	// Initialize notification service for clinic operator alerts
 	var notifier conversation.PaymentNotifier
 	if clinicStore != nil {
@@ -206,14 +171,95 @@ func Run(ctx context.Context, cfg *appconfig.Config, logger *logging.Logger) err
 			logger.Warn("operator SMS notifications disabled for async workers (messenger not available or no from number)")
 		}
 
-		notifier = notify.NewService(emailSender, smsSender, clinicStore, leadsRepo, logger)
+		notifySvc := notify.NewService(emailSender, smsSender, clinicStore, leadsRepo, logger)
+		var transcripts notify.TranscriptSource
+		if smsTranscript != nil {
+			transcripts = smsTranscript
+		}
+		notifySvc.SetWebhooks(notify.NewWebhookNotifier(nil, logger), transcripts)
+		notifier = notifySvc
 		logger.Info("notification service initialized for clinic operator alerts")
+		report.Add(appbootstrap.StackNotifications, appbootstrap.Enabled("operator_notifications", "email and sms service"))
+	} else {
+		report.Add(appbootstrap.StackNotifications, appbootstrap.Disabled("operator_notifications", "redis not configured"))
+	}
+
+	var oauthSvc *payments.SquareOAuthService
+	if dbPool != nil {
+		var squareSvc *payments.SquareCheckoutService
+		if cfg.SquareAccessToken != "" || (cfg.SquareClientID != "" && cfg.SquareClientSecret != "" && cfg.SquareOAuthRedirectURI != "") {
+			usePaymentLinks := payments.UsePaymentLinks(cfg.SquareCheckoutMode, cfg.SquareSandbox)
+			squareSvc = payments.NewSquareCheckoutService(cfg.SquareAccessToken, cfg.SquareLocationID, cfg.SquareSuccessURL, cfg.SquareCancelURL, logger).
+				WithBaseURL(cfg.SquareBaseURL).
+				WithPaymentLinks(usePaymentLinks).
+				WithPaymentLinkFallback(cfg.SquareCheckoutAllowFallback)
+		}
+		if cfg.SquareClientID != "" && cfg.SquareClientSecret != "" && cfg.SquareOAuthRedirectURI != "" {
+			oauthSvc = payments.NewSquareOAuthService(
+				payments.SquareOAuthConfig{
+					ClientID:     cfg.SquareClientID,
+					ClientSecret: cfg.SquareClientSecret,
+					RedirectURI:  cfg.SquareOAuthRedirectURI,
+					Sandbox:      cfg.SquareSandbox,
+				},
+				dbPool,
+				logger,
+			)
+			squareSvc = squareSvc.WithCredentialsProvider(oauthSvc)
+			numberResolver = payments.NewDBOrgNumberResolver(oauthSvc, numberResolver)
+			refreshWorker := payments.NewTokenRefreshWorker(oauthSvc, logger)
+			go refreshWorker.Start(ctx)
+		}
+		if squareSvc != nil {
+			outbox := events.NewOutboxStore(dbPool)
+			depositOpts := []conversation.DepositOption{conversation.WithShortURLs(payments.NewShortLinkStore(dbPool), cfg.PaymentLinkBaseURL())}
+			if clinicStore != nil {
+				depositOpts = append(depositOpts, conversation.WithDepositPolicies(clinicStore))
+			}
+			requests, _ := notifier.(conversation.BookingHandoffNotifier)
+			depositOpts = append(depositOpts, conversation.WithBookingRequests(events.NewProcessedStore(dbPool), requests))
+			depositSender = conversation.NewDepositDispatcher(paymentChecker, squareSvc, outbox, messenger, numberResolver, leadsRepo, smsTranscript, convStore, logger, depositOpts...)
+			paymentClaims = appbootstrap.BuildPaymentClaimChecker(dbPool, paymentChecker, leadsRepo, squareSvc, numberResolver, logger)
+			logger.Info("deposit sender initialized for async workers", "has_oauth", oauthSvc != nil, "square_location_id", cfg.SquareLocationID)
+		} else {
+			logger.Warn("deposit sender NOT initialized for async workers", "has_square_token", cfg.SquareAccessToken != "", "has_oauth", oauthSvc != nil)
+		}
+	}
+	switch {
+	case depositSender != nil:
+		report.Add(appbootstrap.StackPayments, appbootstrap.Enabled("deposit_sender", "square"))
+	case dbPool == nil:
+		report.Add(appbootstrap.StackPayments, appbootstrap.Disabled("deposit_sender", "no database"))
+	default:
+		report.Add(appbootstrap.StackPayments, appbootstrap.Disabled("deposit_sender", "Square not configured; this worker only sends Square deposits"))
+	}
+
+	report.Log(logger)
+	// The API serves this at /admin/diagnostics/startup.
+	if err := appbootstrap.NewStartupReportStore(redisClient).Publish(ctx, report); err != nil {
+		logger.Warn("failed to publish startup report", "error", err)
 	}
 
 	var processedStore *events.ProcessedStore
+	var callbackTasks conversation.CallbackTaskStore
+	var turnTimings conversation.TurnTimingStore
+	var scheduler *conversation.Scheduler
+	var reaper *conversation.JobReaper
 	if dbPool != nil {
 		processedStore = events.NewProcessedStore(dbPool)
+		callbackTasks = conversation.NewPGCallbackTaskStore(dbPool)
+		turnTimings = conversation.NewPGTurnTimingStore(dbPool)
+		scheduledJobs := conversation.NewPGScheduledJobStore(dbPool)
+		publisher := conversation.NewPublisher(queue, jobStore, logger)
+		publisher.SetScheduledJobStore(scheduledJobs)
+		scheduler = conversation.NewScheduler(scheduledJobs, publisher, logger)
+		reaper = conversation.NewJobReaper(jobStore, jobStore, publisher, logger)
+		reaper.SetReplyLookup(msgStore)
+		reaper.SetStaleAfter(cfg.ConversationJobStaleAfter)
 	}
+	callbackNotifier, _ := notifier.(conversation.CallbackNotifier)
+	digests, _ := notifier.(payments.DailyDigestNotifier)
+	appbootstrap.StartBookingOutcomePoller(ctx, cfg, dbPool, clinicStore, leadsRepo, digests, logger)
 
 	var autoPurger conversation.SandboxAutoPurger
 	if cfg.Env != "production" && cfg.SquareSandbox && dbPool != nil {
@@ -233,6 +279,11 @@ func Run(ctx context.Context, cfg *appconfig.Config, logger *logging.Logger) err
 		}
 	}
 
+	var frustrationAuditor conversation.FrustrationAuditor
+	if auditSvc != nil {
+		frustrationAuditor = auditSvc
+	}
+
 	worker := conversation.NewWorker(
 		processor,
 		queue,
@@ -241,7 +292,12 @@ func Run(ctx context.Context, cfg *appconfig.Config, logger *logging.Logger) err
 		bookingBridge,
 		logger,
 		conversation.WithWorkerCount(cfg.WorkerCount),
+		conversation.WithOrgConcurrency(cfg.WorkerOrgConcurrency, 0),
+		conversation.WithDrainTimeout(cfg.WorkerDrainTimeout),
+		conversation.WithInboundBatcher(conversation.NewInboundBatcher(redisClient, cfg.InboundBatchWindow)),
+		conversation.WithConversationLocker(conversation.NewConversationLocker(redisClient, cfg.ConversationLockTTL, cfg.ConversationLockWait)),
 		conversation.WithDepositSender(depositSender),
+		conversation.WithPaymentClaimChecker(paymentClaims),
 		conversation.WithPaymentNotifier(notifier),
 		conversation.WithSandboxAutoPurger(autoPurger),
 		conversation.WithProcessedEventsStore(processedStore),
@@ -252,13 +308,27 @@ func Run(ctx context.Context, cfg *appconfig.Config, logger *logging.Logger) err
 		conversation.WithConversationStore(convStore),
 		conversation.WithSupervisor(supervisor),
 		conversation.WithSupervisorMode(conversation.ParseSupervisorMode(cfg.SupervisorMode)),
+		conversation.WithCallbackTasks(callbackTasks, callbackNotifier),
+		conversation.WithFrustrationTracking(appbootstrap.BuildFrustrationTracker(cfg, redisClient, processor, logger), frustrationAuditor),
+		conversation.WithScheduler(scheduler),
+		conversation.WithReengagementQuietHours(cfg.QuietHoursStart, cfg.QuietHoursEnd),
+		conversation.WithJobReaper(reaper),
+		conversation.WithWorkerCRMEvents(crmEvents),
+		conversation.WithReplyLatencyBudget(cfg.ReplyLatencyBudget),
+		conversation.WithProgressGating(cfg.ProgressInitialDelay, cfg.ProgressMinInterval),
+		conversation.WithTurnTimings(turnTimings),
 	)
 
+	// Keep serving metrics through the drain so shutdown behaviour is visible.
+	stopMetrics := startMetricsServer(cfg.WorkerMetricsPort, logger)
+	defer stopMetrics()
+
 	worker.Start(ctx)
 
 	<-ctx.Done()
 
-	doneCtx, doneCancel := context.WithTimeout(context.Background(), 30*time.Second)
+	// Allow the drain deadline plus time to requeue whatever it cut short.
+	doneCtx, doneCancel := context.WithTimeout(context.Background(), worker.DrainTimeout()+5*time.Second)
 	defer doneCancel()
 
 	waitCh := make(chan struct{})
@@ -0,0 +1,309 @@
+package messagingworker
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/wolfman30/medspa-ai-platform/internal/broadcasts"
+	"github.com/wolfman30/medspa-ai-platform/internal/clinic"
+	"github.com/wolfman30/medspa-ai-platform/internal/conversation/msgschema"
+	"github.com/wolfman30/medspa-ai-platform/internal/messaging"
+	"github.com/wolfman30/medspa-ai-platform/internal/messaging/compliance"
+	"github.com/wolfman30/medspa-ai-platform/internal/messaging/telnyxclient"
+	"github.com/wolfman30/medspa-ai-platform/pkg/logging"
+)
+
+type broadcastStore interface {
+	PendingOrgs(ctx context.Context) ([]string, error)
+	ClaimDue(ctx context.Context, limit int, heldOrgs []string) ([]broadcasts.Recipient, error)
+	MarkRecipient(ctx context.Context, id uuid.UUID, u broadcasts.RecipientUpdate) error
+	CompleteFinished(ctx context.Context) (int64, error)
+}
+
+type broadcastMessageStore interface {
+	IsUnsubscribed(ctx context.Context, clinicID uuid.UUID, recipient string) (bool, error)
+	InsertMessage(ctx context.Context, q messaging.Querier, rec messaging.MessageRecord) (uuid.UUID, error)
+	UpdateMessageCost(ctx context.Context, msgID uuid.UUID, segments int, costUSD *float64) error
+}
+
+type campaignLookup interface {
+	Get(ctx context.Context, number string) (messaging.NumberProvisioning, error)
+}
+
+type clinicConfigs interface {
+	Get(ctx context.Context, orgID string) (*clinic.Config, error)
+}
+
+// Broadcasts are held from 9pm to 8am in each clinic's timezone unless
+// QUIET_HOURS_START/END override the window.
+const (
+	defaultQuietStart = "21:00"
+	defaultQuietEnd   = "08:00"
+)
+
+// BroadcastSender sends queued broadcast recipients at a capped rate per
+// minute, holding them during their clinic's quiet hours and re-checking
+// opt-out, marketing consent and the sending number's campaign just before
+// each send.
+type BroadcastSender struct {
+	store         broadcastStore
+	messages      broadcastMessageStore
+	telnyx        telnyxSender
+	logger        *logging.Logger
+	consent       compliance.MarketingConsentChecker
+	campaigns     campaignLookup
+	clinics       clinicConfigs
+	quietStart    string
+	quietEnd      string
+	quietTZ       string
+	profileID     string
+	ratePerMinute int
+	interval      time.Duration
+	now           func() time.Time
+}
+
+// NewBroadcastSender creates a BroadcastSender that sends up to 60 messages
+// a minute, polling every minute.
+func NewBroadcastSender(store broadcastStore, messages broadcastMessageStore, telnyx telnyxSender, logger *logging.Logger) *BroadcastSender {
+	if logger == nil {
+		logger = logging.Default()
+	}
+	return &BroadcastSender{
+		store:         store,
+		messages:      messages,
+		telnyx:        telnyx,
+		logger:        logger,
+		quietStart:    defaultQuietStart,
+		quietEnd:      defaultQuietEnd,
+		ratePerMinute: 60,
+		interval:      time.Minute,
+		now:           time.Now,
+	}
+}
+
+func (b *BroadcastSender) WithRatePerMinute(n int) *BroadcastSender {
+	if n > 0 {
+		b.ratePerMinute = n
+	}
+	return b
+}
+
+func (b *BroadcastSender) WithInterval(d time.Duration) *BroadcastSender {
+	if d > 0 {
+		b.interval = d
+	}
+	return b
+}
+
+// WithQuietHours overrides the HH:MM window broadcasts are held in. tz is
+// used for clinics without a timezone of their own.
+func (b *BroadcastSender) WithQuietHours(start, end, tz string) *BroadcastSender {
+	if start != "" && end != "" {
+		b.quietStart, b.quietEnd = start, end
+	}
+	b.quietTZ = tz
+	return b
+}
+
+// WithClinics sets where each clinic's timezone is read from. Without it
+// quiet hours use the fallback timezone.
+func (b *BroadcastSender) WithClinics(c clinicConfigs) *BroadcastSender {
+	b.clinics = c
+	return b
+}
+
+// WithConsent sets the marketing consent checker. Without one every send
+// is suppressed, since broadcasts are marketing.
+func (b *BroadcastSender) WithConsent(c compliance.MarketingConsentChecker) *BroadcastSender {
+	b.consent = c
+	return b
+}
+
+func (b *BroadcastSender) WithCampaigns(c campaignLookup) *BroadcastSender {
+	b.campaigns = c
+	return b
+}
+
+func (b *BroadcastSender) WithMessagingProfile(id string) *BroadcastSender {
+	b.profileID = id
+	return b
+}
+
+func (b *BroadcastSender) Run(ctx context.Context) {
+	ticker := time.NewTicker(b.interval)
+	defer ticker.Stop()
+	b.drain(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			b.drain(ctx)
+		}
+	}
+}
+
+// batchSize is how many recipients one drain may send without exceeding
+// the per-minute rate.
+func (b *BroadcastSender) batchSize() int {
+	n := int(float64(b.ratePerMinute) * b.interval.Minutes())
+	if n < 1 {
+		n = 1
+	}
+	return n
+}
+
+func (b *BroadcastSender) drain(ctx context.Context) {
+	if b.store == nil || b.messages == nil || b.telnyx == nil {
+		return
+	}
+	// Recipients stay pending through their clinic's quiet hours and go out
+	// after.
+	held, err := b.quietOrgs(ctx, b.now())
+	if err != nil {
+		b.logger.Error("broadcast quiet hours check failed", "error", err)
+		return
+	}
+	recipients, err := b.store.ClaimDue(ctx, b.batchSize(), held)
+	if err != nil {
+		b.logger.Error("broadcast claim failed", "error", err)
+		return
+	}
+	for _, r := range recipients {
+		update := b.send(ctx, r)
+		if err := b.store.MarkRecipient(ctx, r.ID, update); err != nil {
+			b.logger.Error("broadcast recipient update failed", "error", err, "recipient_id", r.ID, "broadcast_id", r.BroadcastID)
+		}
+	}
+	if len(recipients) > 0 {
+		if _, err := b.store.CompleteFinished(ctx); err != nil {
+			b.logger.Error("broadcast completion failed", "error", err)
+		}
+	}
+}
+
+// quietOrgs lists the orgs with unsent recipients that are in quiet hours.
+func (b *BroadcastSender) quietOrgs(ctx context.Context, now time.Time) ([]string, error) {
+	orgs, err := b.store.PendingOrgs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var held []string
+	for _, orgID := range orgs {
+		if b.quietHours(ctx, orgID).Suppress(now, compliance.PurposeMarketing) {
+			held = append(held, orgID)
+		}
+	}
+	return held, nil
+}
+
+// quietHours returns the window broadcasts are held back in, in the clinic's
+// timezone.
+func (b *BroadcastSender) quietHours(ctx context.Context, orgID string) compliance.QuietHours {
+	tz := b.quietTZ
+	if b.clinics != nil {
+		cfg, err := b.clinics.Get(ctx, orgID)
+		if err != nil {
+			b.logger.Warn("broadcast clinic config lookup failed; using fallback timezone", "error", err, "org_id", orgID)
+		} else if cfg != nil && strings.TrimSpace(cfg.Timezone) != "" {
+			tz = strings.TrimSpace(cfg.Timezone)
+		}
+	}
+	q, err := compliance.ParseQuietHours(b.quietStart, b.quietEnd, tz)
+	if err != nil {
+		b.logger.Warn("broadcast quiet hours invalid; using UTC", "error", err, "org_id", orgID, "timezone", tz)
+		q, _ = compliance.ParseQuietHours(b.quietStart, b.quietEnd, "")
+	}
+	return q
+}
+
+func (b *BroadcastSender) send(ctx context.Context, r broadcasts.Recipient) broadcasts.RecipientUpdate {
+	clinicID, err := uuid.Parse(r.OrgID)
+	if err != nil {
+		return broadcasts.RecipientUpdate{Status: broadcasts.RecipientFailed, Error: "invalid org id"}
+	}
+	if reason, err := b.suppressedReason(ctx, clinicID, r); err != nil {
+		// Fail closed rather than send unchecked.
+		b.logger.Warn("broadcast compliance check failed", "error", err, "recipient_id", r.ID)
+		return broadcasts.RecipientUpdate{Status: broadcasts.RecipientFailed, Error: err.Error()}
+	} else if reason != "" {
+		return broadcasts.RecipientUpdate{Status: broadcasts.RecipientSuppressed, SuppressedReason: reason}
+	}
+
+	resp, err := b.telnyx.SendMessage(ctx, telnyxclient.SendMessageRequest{
+		From:               r.FromNumber,
+		To:                 r.Phone,
+		Body:               r.Body,
+		MessagingProfileID: b.profileID,
+	})
+	if err != nil {
+		b.logger.Warn("broadcast send failed", "error", err, "recipient_id", r.ID, "broadcast_id", r.BroadcastID)
+		return broadcasts.RecipientUpdate{Status: broadcasts.RecipientFailed, Error: err.Error()}
+	}
+	status := resp.Status
+	if status == "" {
+		status = "queued"
+	}
+	msgID, err := b.messages.InsertMessage(ctx, nil, messaging.MessageRecord{
+		ClinicID:          clinicID,
+		From:              r.FromNumber,
+		To:                r.Phone,
+		Direction:         "outbound",
+		AuthorType:        string(msgschema.Staff),
+		Body:              r.Body,
+		ProviderStatus:    status,
+		ProviderMessageID: resp.ID,
+		SendAttempts:      1,
+	})
+	if err != nil {
+		b.logger.Error("broadcast message record failed", "error", err, "provider_message_id", resp.ID)
+	} else if err := b.recordCost(ctx, msgID, r.Body, resp); err != nil {
+		b.logger.Warn("broadcast message cost record failed", "error", err, "provider_message_id", resp.ID)
+	}
+	return broadcasts.RecipientUpdate{Status: broadcasts.RecipientSent, ProviderMessageID: resp.ID}
+}
+
+// recordCost stores the segments and cost Telnyx reported for a send. An
+// unreported cost is left for the cost rollup to estimate.
+func (b *BroadcastSender) recordCost(ctx context.Context, msgID uuid.UUID, body string, resp *telnyxclient.MessageResponse) error {
+	segments := resp.Parts
+	if segments <= 0 {
+		segments = messaging.SegmentCount(body)
+	}
+	var cost *float64
+	if amount, ok := resp.CostUSD(); ok {
+		cost = &amount
+	}
+	return b.messages.UpdateMessageCost(ctx, msgID, segments, cost)
+}
+
+// suppressedReason re-checks compliance at send time: the recipient may have
+// opted out or the campaign may have been suspended since the broadcast was
+// queued.
+func (b *BroadcastSender) suppressedReason(ctx context.Context, clinicID uuid.UUID, r broadcasts.Recipient) (string, error) {
+	if b.campaigns != nil {
+		p, err := b.campaigns.Get(ctx, r.FromNumber)
+		switch {
+		case errors.Is(err, messaging.ErrNumberNotProvisioned):
+		case err != nil:
+			return "", err
+		case !p.CampaignApproved():
+			return broadcasts.ReasonCampaignNotApproved, nil
+		}
+	}
+	unsub, err := b.messages.IsUnsubscribed(ctx, clinicID, r.Phone)
+	if err != nil {
+		return "", err
+	}
+	if unsub {
+		return broadcasts.ReasonOptOut, nil
+	}
+	err = compliance.RequireMarketingConsent(ctx, b.consent, compliance.PurposeMarketing, r.OrgID, r.Phone)
+	if errors.Is(err, compliance.ErrNoMarketingConsent) {
+		return broadcasts.ReasonNoMarketingConsent, nil
+	}
+	return "", err
+}
@@ -0,0 +1,251 @@
+package messagingworker
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/wolfman30/medspa-ai-platform/internal/broadcasts"
+	"github.com/wolfman30/medspa-ai-platform/internal/clinic"
+	"github.com/wolfman30/medspa-ai-platform/internal/messaging"
+	"github.com/wolfman30/medspa-ai-platform/internal/messaging/telnyxclient"
+	"github.com/wolfman30/medspa-ai-platform/internal/tenancy"
+)
+
+type fakeBroadcastStore struct {
+	pending   []broadcasts.Recipient
+	limits    []int
+	updates   map[uuid.UUID]broadcasts.RecipientUpdate
+	completed int
+}
+
+func (f *fakeBroadcastStore) PendingOrgs(ctx context.Context) ([]string, error) {
+	var orgs []string
+	seen := map[string]bool{}
+	for _, r := range f.pending {
+		if !seen[r.OrgID] {
+			seen[r.OrgID] = true
+			orgs = append(orgs, r.OrgID)
+		}
+	}
+	return orgs, nil
+}
+
+func (f *fakeBroadcastStore) ClaimDue(ctx context.Context, limit int, heldOrgs []string) ([]broadcasts.Recipient, error) {
+	f.limits = append(f.limits, limit)
+	held := map[string]bool{}
+	for _, orgID := range heldOrgs {
+		held[orgID] = true
+	}
+	var claimed, rest []broadcasts.Recipient
+	for _, r := range f.pending {
+		if len(claimed) < limit && !held[r.OrgID] {
+			claimed = append(claimed, r)
+		} else {
+			rest = append(rest, r)
+		}
+	}
+	f.pending = rest
+	return claimed, nil
+}
+
+func (f *fakeBroadcastStore) MarkRecipient(ctx context.Context, id uuid.UUID, u broadcasts.RecipientUpdate) error {
+	if f.updates == nil {
+		f.updates = map[uuid.UUID]broadcasts.RecipientUpdate{}
+	}
+	f.updates[id] = u
+	return nil
+}
+
+func (f *fakeBroadcastStore) CompleteFinished(ctx context.Context) (int64, error) {
+	f.completed++
+	return 0, nil
+}
+
+type fakeBroadcastMessages struct {
+	unsubscribed map[string]bool
+	inserted     []messaging.MessageRecord
+	segments     []int
+}
+
+func (f *fakeBroadcastMessages) IsUnsubscribed(ctx context.Context, clinicID uuid.UUID, recipient string) (bool, error) {
+	return f.unsubscribed[recipient], nil
+}
+
+func (f *fakeBroadcastMessages) InsertMessage(ctx context.Context, q messaging.Querier, rec messaging.MessageRecord) (uuid.UUID, error) {
+	f.inserted = append(f.inserted, rec)
+	return uuid.New(), nil
+}
+
+func (f *fakeBroadcastMessages) UpdateMessageCost(ctx context.Context, msgID uuid.UUID, segments int, costUSD *float64) error {
+	f.segments = append(f.segments, segments)
+	return nil
+}
+
+type fakeCampaigns map[string]messaging.NumberProvisioning
+
+func (f fakeCampaigns) Get(ctx context.Context, number string) (messaging.NumberProvisioning, error) {
+	p, ok := f[number]
+	if !ok {
+		return messaging.NumberProvisioning{}, messaging.ErrNumberNotProvisioned
+	}
+	return p, nil
+}
+
+type allowAllConsent struct{}
+
+func (allowAllConsent) HasMarketingConsent(ctx context.Context, scope tenancy.OrgScope, phone string) (bool, error) {
+	return true, nil
+}
+
+func broadcastRecipients(n int) []broadcasts.Recipient {
+	orgID := uuid.NewString()
+	broadcastID := uuid.New()
+	out := make([]broadcasts.Recipient, n)
+	for i := range out {
+		out[i] = broadcasts.Recipient{
+			ID:          uuid.New(),
+			BroadcastID: broadcastID,
+			OrgID:       orgID,
+			Phone:       "+1555000000" + string(rune('0'+i)),
+			Body:        "3 openings Friday! Reply STOP to opt out.",
+			FromNumber:  "+15559990000",
+		}
+	}
+	return out
+}
+
+func TestBroadcastSenderLimitsEachDrainToRate(t *testing.T) {
+	store := &fakeBroadcastStore{pending: broadcastRecipients(5)}
+	messages := &fakeBroadcastMessages{}
+	telnyx := &fakeTelnyxSender{resp: &telnyxclient.MessageResponse{ID: "msg-1", Status: "queued"}}
+	sender := NewBroadcastSender(store, messages, telnyx, nil).
+		WithRatePerMinute(2).
+		WithConsent(allowAllConsent{})
+	sender.now = func() time.Time { return time.Date(2026, 10, 16, 15, 0, 0, 0, time.UTC) }
+
+	sender.drain(context.Background())
+	if len(store.limits) != 1 || store.limits[0] != 2 || len(messages.inserted) != 2 {
+		t.Fatalf("expected 2 sends in the first minute, got limits %v, %d sent", store.limits, len(messages.inserted))
+	}
+	sender.drain(context.Background())
+	sender.drain(context.Background())
+	if len(messages.inserted) != 5 || len(store.pending) != 0 {
+		t.Fatalf("expected all 5 sent after three drains, got %d", len(messages.inserted))
+	}
+	if len(messages.segments) != 5 || messages.segments[0] != 1 {
+		t.Fatalf("expected each send's segments recorded, got %v", messages.segments)
+	}
+	for id, u := range store.updates {
+		if u.Status != broadcasts.RecipientSent || u.ProviderMessageID != "msg-1" {
+			t.Fatalf("recipient %s update = %+v", id, u)
+		}
+	}
+	if store.completed != 3 {
+		t.Fatalf("expected completion check after each drain, got %d", store.completed)
+	}
+}
+
+func TestBroadcastSenderBatchScalesWithInterval(t *testing.T) {
+	sender := NewBroadcastSender(nil, nil, nil, nil).WithRatePerMinute(30).WithInterval(10 * time.Second)
+	if got := sender.batchSize(); got != 5 {
+		t.Fatalf("batchSize = %d, want 5", got)
+	}
+	sender.WithRatePerMinute(1)
+	if got := sender.batchSize(); got != 1 {
+		t.Fatalf("batchSize = %d, want at least 1", got)
+	}
+}
+
+type fakeClinicConfigs map[string]*clinic.Config
+
+func (f fakeClinicConfigs) Get(ctx context.Context, orgID string) (*clinic.Config, error) {
+	if cfg, ok := f[orgID]; ok {
+		return cfg, nil
+	}
+	return &clinic.Config{OrgID: orgID}, nil
+}
+
+func TestBroadcastSenderHoldsDuringQuietHours(t *testing.T) {
+	store := &fakeBroadcastStore{pending: broadcastRecipients(2)}
+	telnyx := &fakeTelnyxSender{}
+	// No window configured: the default 21:00-08:00 applies.
+	sender := NewBroadcastSender(store, &fakeBroadcastMessages{}, telnyx, nil).
+		WithConsent(allowAllConsent{})
+	sender.now = func() time.Time { return time.Date(2026, 10, 16, 22, 0, 0, 0, time.UTC) }
+
+	sender.drain(context.Background())
+	if len(store.pending) != 2 {
+		t.Fatalf("expected nothing claimed during quiet hours, %d pending", len(store.pending))
+	}
+	sender.now = func() time.Time { return time.Date(2026, 10, 17, 9, 0, 0, 0, time.UTC) }
+	sender.drain(context.Background())
+	if len(store.pending) != 0 {
+		t.Fatalf("expected recipients sent after quiet hours, %d pending", len(store.pending))
+	}
+}
+
+func TestBroadcastSenderUsesEachClinicsTimezone(t *testing.T) {
+	eastern := broadcastRecipients(1)
+	pacific := broadcastRecipients(1)
+	store := &fakeBroadcastStore{pending: append(eastern, pacific...)}
+	sender := NewBroadcastSender(store, &fakeBroadcastMessages{}, &fakeTelnyxSender{}, nil).
+		WithConsent(allowAllConsent{}).
+		WithClinics(fakeClinicConfigs{
+			eastern[0].OrgID: {OrgID: eastern[0].OrgID, Timezone: "America/New_York"},
+			pacific[0].OrgID: {OrgID: pacific[0].OrgID, Timezone: "America/Los_Angeles"},
+		})
+	// 21:30 in New York, 18:30 in Los Angeles.
+	sender.now = func() time.Time { return time.Date(2026, 10, 17, 1, 30, 0, 0, time.UTC) }
+
+	sender.drain(context.Background())
+	if len(store.pending) != 1 || store.pending[0].ID != eastern[0].ID {
+		t.Fatalf("expected only the New York clinic held, pending %+v", store.pending)
+	}
+	if store.updates[pacific[0].ID].Status != broadcasts.RecipientSent {
+		t.Fatalf("expected the Los Angeles recipient sent, got %+v", store.updates[pacific[0].ID])
+	}
+}
+
+func TestBroadcastSenderRechecksComplianceAtSend(t *testing.T) {
+	recipients := broadcastRecipients(3)
+	store := &fakeBroadcastStore{pending: recipients}
+	messages := &fakeBroadcastMessages{unsubscribed: map[string]bool{recipients[0].Phone: true}}
+	telnyx := &fakeTelnyxSender{resp: &telnyxclient.MessageResponse{ID: "msg-1"}}
+	campaigns := fakeCampaigns{"+15559990000": {PhoneNumber: "+15559990000", CampaignID: "c-1", CampaignStatus: "ACTIVE"}}
+	sender := NewBroadcastSender(store, messages, telnyx, nil).
+		WithConsent(allowAllConsent{}).
+		WithCampaigns(campaigns)
+	sender.now = func() time.Time { return time.Date(2026, 10, 16, 15, 0, 0, 0, time.UTC) }
+
+	sender.drain(context.Background())
+	if u := store.updates[recipients[0].ID]; u.Status != broadcasts.RecipientSuppressed || u.SuppressedReason != broadcasts.ReasonOptOut {
+		t.Fatalf("opted-out recipient update = %+v", u)
+	}
+	if len(messages.inserted) != 2 {
+		t.Fatalf("expected 2 sends, got %d", len(messages.inserted))
+	}
+
+	// Without consent, or once the campaign is suspended, nothing goes out.
+	noConsent := broadcastRecipients(1)
+	noConsent[0].Phone = "+15550000008"
+	store.pending = noConsent
+	sender.WithConsent(nil)
+	sender.drain(context.Background())
+	if u := store.updates[noConsent[0].ID]; u.Status != broadcasts.RecipientSuppressed || u.SuppressedReason != broadcasts.ReasonNoMarketingConsent {
+		t.Fatalf("no-consent recipient update = %+v", u)
+	}
+	suspended := broadcastRecipients(1)
+	suspended[0].Phone = "+15550000009"
+	store.pending = suspended
+	campaigns["+15559990000"] = messaging.NumberProvisioning{PhoneNumber: "+15559990000", CampaignID: "c-1", CampaignStatus: "SUSPENDED"}
+	sender.WithConsent(allowAllConsent{})
+	sender.drain(context.Background())
+	if u := store.updates[suspended[0].ID]; u.Status != broadcasts.RecipientSuppressed || u.SuppressedReason != broadcasts.ReasonCampaignNotApproved {
+		t.Fatalf("suspended-campaign recipient update = %+v", u)
+	}
+	if len(messages.inserted) != 2 {
+		t.Fatalf("expected no further sends, got %d", len(messages.inserted))
+	}
+}
@@ -4,7 +4,9 @@
 //   - SMS retry delivery via [RetrySender] (exponential backoff, max 5 attempts).
 //   - Hosted number order polling via [HostedPoller] (tracks LOA/porting status
 //     until activation).
+//   - Broadcast delivery via [BroadcastSender] (rate-limited, held during
+//     quiet hours, compliance re-checked per recipient).
 //
-// Both workers run as long-lived goroutines started from the main application
+// The workers run as long-lived goroutines started from the main application
 // bootstrap and are cancelled via context on shutdown.
 package messagingworker
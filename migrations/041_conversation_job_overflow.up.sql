@@ -0,0 +1,9 @@
+-- Jobs spilled from the in-memory conversation queue when its buffer is full.
+CREATE TABLE IF NOT EXISTS conversation_job_overflow (
+    seq BIGSERIAL PRIMARY KEY,
+    overflow_id TEXT NOT NULL UNIQUE,
+    conversation_id TEXT,
+    status TEXT NOT NULL DEFAULT 'queued',
+    body TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1,17 @@
+-- Org-scoped API keys for programmatic partner access.
+-- Only a SHA-256 hash of the secret is stored; the prefix identifies the key.
+CREATE TABLE IF NOT EXISTS org_api_keys (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    org_id TEXT NOT NULL,
+    name TEXT NOT NULL DEFAULT '',
+    prefix TEXT NOT NULL UNIQUE,
+    secret_hash TEXT NOT NULL,
+    scopes TEXT[] NOT NULL DEFAULT '{}',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    last_used_at TIMESTAMPTZ,
+    revoked_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_org_api_keys_org_id ON org_api_keys(org_id);
+
+COMMENT ON COLUMN org_api_keys.scopes IS 'Scopes: leads:write, stats:read';
@@ -0,0 +1,17 @@
+-- Raw inbound webhook payloads, captured before processing so mishandled
+-- events can be inspected and re-driven.
+CREATE TABLE IF NOT EXISTS webhook_events (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    provider TEXT NOT NULL,
+    event_id TEXT,
+    received_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    headers JSONB NOT NULL DEFAULT '{}'::jsonb,
+    body BYTEA NOT NULL,
+    outcome TEXT NOT NULL DEFAULT 'received',
+    outcome_detail TEXT,
+    replay_count INT NOT NULL DEFAULT 0,
+    last_replayed_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhook_events_provider_event ON webhook_events(provider, event_id);
+CREATE INDEX IF NOT EXISTS idx_webhook_events_received_at ON webhook_events(received_at);
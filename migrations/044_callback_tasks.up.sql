@@ -0,0 +1,17 @@
+-- Operator callback tasks created when a patient asks to be called instead of
+-- continuing over SMS. An open task pauses AI qualification for the conversation.
+CREATE TABLE IF NOT EXISTS callback_tasks (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    org_id TEXT NOT NULL,
+    conversation_id TEXT NOT NULL,
+    lead_id TEXT,
+    phone TEXT NOT NULL,
+    requested_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    preferred_window TEXT,
+    patient_message TEXT,
+    status TEXT NOT NULL DEFAULT 'open',
+    resolved_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_callback_tasks_conversation_open ON callback_tasks(conversation_id) WHERE status = 'open';
+CREATE INDEX IF NOT EXISTS idx_callback_tasks_org_status ON callback_tasks(org_id, status, requested_at);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_leads_retention;
+ALTER TABLE leads DROP COLUMN IF EXISTS pii_purged_at;
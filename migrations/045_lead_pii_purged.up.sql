@@ -0,0 +1,5 @@
+-- Marks leads whose PII was scrubbed by the retention job or a right-to-delete
+-- request. Scrubbed leads are kept when bookings or payments reference them.
+ALTER TABLE leads ADD COLUMN IF NOT EXISTS pii_purged_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS idx_leads_retention ON leads(org_id, created_at) WHERE pii_purged_at IS NULL;
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_payments_org_failed_disputed;
+
+ALTER TABLE bookings
+    DROP COLUMN IF EXISTS disputed_at;
@@ -0,0 +1,8 @@
+-- Square declines move a payment to 'failed' and chargebacks to 'disputed'.
+-- Bookings whose deposit is disputed are flagged for operator review.
+ALTER TABLE bookings
+    ADD COLUMN IF NOT EXISTS disputed_at timestamptz;
+
+CREATE INDEX IF NOT EXISTS idx_payments_org_failed_disputed
+    ON payments (org_id, created_at DESC)
+    WHERE status IN ('failed', 'disputed');
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_phone_number_routes_org;
+DROP TABLE IF EXISTS phone_number_routes;
@@ -0,0 +1,13 @@
+-- Per-number routing for clinic number pools. A clinic can advertise several
+-- numbers (e.g. one for injectables, one for weight loss); each row maps an
+-- inbound number to its org plus the service line the number implies.
+CREATE TABLE IF NOT EXISTS phone_number_routes (
+    phone_number    TEXT PRIMARY KEY,
+    org_id          TEXT NOT NULL,
+    default_service TEXT NOT NULL DEFAULT '',
+    campaign_tag    TEXT NOT NULL DEFAULT '',
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at      TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_phone_number_routes_org ON phone_number_routes(org_id);
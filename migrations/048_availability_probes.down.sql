@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_availability_probes_org_probed;
+DROP TABLE IF EXISTS availability_probes;
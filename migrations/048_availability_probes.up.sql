@@ -0,0 +1,18 @@
+-- Results of the scheduled availability health probe. Probes call the booking
+-- platform directly and live only here, apart from leads and conversations,
+-- so they never count toward funnel metrics.
+CREATE TABLE IF NOT EXISTS availability_probes (
+    id          BIGSERIAL PRIMARY KEY,
+    org_id      TEXT NOT NULL,
+    service     TEXT NOT NULL,
+    platform    TEXT NOT NULL DEFAULT '',
+    success     BOOLEAN NOT NULL,
+    latency_ms  BIGINT NOT NULL DEFAULT 0,
+    slot_count  INTEGER NOT NULL DEFAULT 0,
+    error       TEXT NOT NULL DEFAULT '',
+    alert       TEXT NOT NULL DEFAULT '',
+    probed_at   TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_availability_probes_org_probed
+    ON availability_probes (org_id, probed_at DESC);
@@ -0,0 +1,6 @@
+ALTER TABLE leads
+    DROP COLUMN IF EXISTS marketing_consent_asked_at,
+    DROP COLUMN IF EXISTS marketing_consent_source,
+    DROP COLUMN IF EXISTS marketing_consent_at,
+    DROP COLUMN IF EXISTS marketing_consent,
+    DROP COLUMN IF EXISTS transactional_consent_at;
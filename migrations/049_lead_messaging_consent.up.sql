@@ -0,0 +1,8 @@
+-- Messaging consent on leads. Texting the clinic is consent to transactional
+-- replies; marketing messages require an explicit opt-in, asked at most once.
+ALTER TABLE leads
+    ADD COLUMN IF NOT EXISTS transactional_consent_at TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS marketing_consent BOOLEAN NOT NULL DEFAULT false,
+    ADD COLUMN IF NOT EXISTS marketing_consent_at TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS marketing_consent_source TEXT NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS marketing_consent_asked_at TIMESTAMPTZ;
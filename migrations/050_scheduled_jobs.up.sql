@@ -0,0 +1,22 @@
+-- Conversation jobs held until run_at (payment nudges, re-checks,
+-- re-engagement). The worker's scheduler claims due rows with
+-- FOR UPDATE SKIP LOCKED and publishes them onto the conversation queue.
+CREATE TABLE IF NOT EXISTS scheduled_jobs (
+    id              UUID PRIMARY KEY,
+    org_id          TEXT NOT NULL,
+    conversation_id TEXT NOT NULL DEFAULT '',
+    kind            TEXT NOT NULL,
+    payload         JSONB NOT NULL,
+    run_at          TIMESTAMPTZ NOT NULL,
+    status          TEXT NOT NULL DEFAULT 'pending',
+    attempts        INTEGER NOT NULL DEFAULT 0,
+    claimed_at      TIMESTAMPTZ,
+    finished_at     TIMESTAMPTZ,
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_scheduled_jobs_due
+    ON scheduled_jobs (run_at) WHERE status IN ('pending', 'claimed');
+
+CREATE INDEX IF NOT EXISTS idx_scheduled_jobs_conversation
+    ON scheduled_jobs (conversation_id) WHERE status = 'pending';
@@ -0,0 +1,4 @@
+-- Appointment length in minutes, so end times and exports don't assume a
+-- fixed slot size. NULL when the duration wasn't known at booking time.
+ALTER TABLE bookings
+    ADD COLUMN IF NOT EXISTS duration_minutes integer;
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_conversation_messages_content_tsv;
+ALTER TABLE conversation_messages
+    DROP COLUMN IF EXISTS content_tsv;
+CREATE INDEX IF NOT EXISTS idx_conversation_messages_content_search
+    ON conversation_messages USING gin(to_tsvector('english', content));
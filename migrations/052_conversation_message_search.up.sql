@@ -0,0 +1,12 @@
+-- Full-text search over message content for the admin portal. A stored
+-- generated column is computed for existing rows when it's added, so the
+-- backfill happens as part of this migration and new rows stay in sync.
+ALTER TABLE conversation_messages
+    ADD COLUMN IF NOT EXISTS content_tsv tsvector
+    GENERATED ALWAYS AS (to_tsvector('english', coalesce(content, ''))) STORED;
+
+-- Replaces the expression index from 015, which queries had to repeat
+-- the expression verbatim to use.
+DROP INDEX IF EXISTS idx_conversation_messages_content_search;
+CREATE INDEX IF NOT EXISTS idx_conversation_messages_content_tsv
+    ON conversation_messages USING gin(content_tsv);
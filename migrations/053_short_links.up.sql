@@ -0,0 +1,15 @@
+-- Short, unguessable links for payment checkout URLs sent over SMS
+-- (e.g. pay.example.com/x7Yt2Qa). Each code belongs to a single org.
+CREATE TABLE IF NOT EXISTS short_links (
+    code TEXT PRIMARY KEY,
+    org_id TEXT NOT NULL,
+    payment_id UUID,
+    target_url TEXT NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL,
+    click_count INTEGER NOT NULL DEFAULT 0,
+    last_clicked_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_short_links_org_id ON short_links(org_id);
+CREATE INDEX IF NOT EXISTS idx_short_links_payment_id ON short_links(payment_id) WHERE payment_id IS NOT NULL;
@@ -0,0 +1 @@
+ALTER TABLE conversation_jobs DROP COLUMN IF EXISTS ack_sent;
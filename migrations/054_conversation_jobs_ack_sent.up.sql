@@ -0,0 +1,3 @@
+-- Records that a job already texted the patient a progress acknowledgement,
+-- so a retry after a worker shutdown does not send it twice.
+ALTER TABLE conversation_jobs ADD COLUMN IF NOT EXISTS ack_sent BOOLEAN NOT NULL DEFAULT FALSE;
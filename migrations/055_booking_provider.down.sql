@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_bookings_org_provider_created;
+ALTER TABLE bookings
+    DROP COLUMN IF EXISTS provider_id;
@@ -0,0 +1,8 @@
+-- Moxie provider (userMedspaId) the booking went to, used to spread
+-- "no preference" patients evenly. NULL when Moxie picked the provider.
+ALTER TABLE bookings
+    ADD COLUMN IF NOT EXISTS provider_id text;
+
+CREATE INDEX IF NOT EXISTS idx_bookings_org_provider_created
+    ON bookings (org_id, provider_id, created_at DESC)
+    WHERE provider_id IS NOT NULL;
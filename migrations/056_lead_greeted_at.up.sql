@@ -0,0 +1,4 @@
+-- When the clinic's first-contact template was sent to the lead. Set once so
+-- the greeting never repeats.
+ALTER TABLE leads
+    ADD COLUMN IF NOT EXISTS greeted_at TIMESTAMPTZ;
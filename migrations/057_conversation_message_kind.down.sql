@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_conversation_messages_conversation_created;
+ALTER TABLE conversation_messages
+    DROP COLUMN IF EXISTS kind;
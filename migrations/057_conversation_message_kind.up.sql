@@ -0,0 +1,9 @@
+-- What an outbound message was (ack, first_contact, time_selection, ai_reply,
+-- ...), so portal stats can tell automatic acks from substantive replies.
+-- Rows written before this migration keep a NULL kind.
+ALTER TABLE conversation_messages
+    ADD COLUMN IF NOT EXISTS kind TEXT;
+
+-- Response-time stats look up the next reply after each inbound message.
+CREATE INDEX IF NOT EXISTS idx_conversation_messages_conversation_created
+    ON conversation_messages(conversation_id, created_at);
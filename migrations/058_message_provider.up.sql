@@ -0,0 +1,4 @@
+-- Which SMS provider delivered an outbound message. Differs from the
+-- deployment's preferred provider when a send failed over.
+ALTER TABLE messages
+    ADD COLUMN IF NOT EXISTS provider TEXT;
@@ -0,0 +1,4 @@
+-- Answers to clinic-configured, service-specific questions asked before
+-- availability (e.g. {"area": "Underarms"} for laser hair removal).
+ALTER TABLE leads
+    ADD COLUMN IF NOT EXISTS extra_qualifications JSONB;
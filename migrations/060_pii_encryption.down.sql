@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS pii_backfill_progress;
+
+DROP INDEX IF EXISTS idx_leads_org_phone_hash;
+
+ALTER TABLE leads
+    DROP COLUMN IF EXISTS phone_hash;
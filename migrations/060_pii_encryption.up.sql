@@ -0,0 +1,18 @@
+-- Lead names, emails, phones and message bodies may now hold
+-- application-encrypted ciphertext ("pii1:<keyID>:..."). Encrypted phones
+-- can't be matched in SQL, so each lead also stores a keyed hash of its
+-- phone digits for lookups.
+ALTER TABLE leads
+    ADD COLUMN IF NOT EXISTS phone_hash TEXT;
+
+CREATE INDEX IF NOT EXISTS idx_leads_org_phone_hash
+    ON leads (org_id, phone_hash)
+    WHERE phone_hash IS NOT NULL;
+
+-- Checkpoints for cmd/encrypt-backfill, so an interrupted run resumes after
+-- the last batch it committed.
+CREATE TABLE IF NOT EXISTS pii_backfill_progress (
+    table_name TEXT PRIMARY KEY,
+    last_id UUID NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1,2 @@
+ALTER TABLE callback_tasks
+    DROP COLUMN IF EXISTS reason;
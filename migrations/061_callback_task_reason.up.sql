@@ -0,0 +1,5 @@
+-- Why a callback task was opened: the patient asked for a call, or the AI
+-- handed a frustrated patient to staff. Frustration tasks don't auto-resume
+-- when the patient texts booking details.
+ALTER TABLE callback_tasks
+    ADD COLUMN IF NOT EXISTS reason TEXT NOT NULL DEFAULT 'callback_request';
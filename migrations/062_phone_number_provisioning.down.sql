@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_phone_number_provisioning_campaign;
+DROP INDEX IF EXISTS idx_phone_number_provisioning_order;
+DROP INDEX IF EXISTS idx_phone_number_provisioning_org;
+DROP TABLE IF EXISTS phone_number_provisioning;
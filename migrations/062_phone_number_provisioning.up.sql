@@ -0,0 +1,18 @@
+-- Provisioning state for numbers bought through the admin API: the Telnyx
+-- number order and the 10DLC campaign the number is registered under.
+-- Status webhooks keep it current; sends from a number whose campaign isn't
+-- approved are blocked.
+CREATE TABLE IF NOT EXISTS phone_number_provisioning (
+    phone_number      TEXT PRIMARY KEY,
+    org_id            TEXT NOT NULL,
+    provider_order_id TEXT NOT NULL DEFAULT '',
+    number_status     TEXT NOT NULL DEFAULT '',
+    campaign_id       TEXT NOT NULL DEFAULT '',
+    campaign_status   TEXT NOT NULL DEFAULT '',
+    created_at        TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at        TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_phone_number_provisioning_org ON phone_number_provisioning(org_id);
+CREATE INDEX IF NOT EXISTS idx_phone_number_provisioning_order ON phone_number_provisioning(provider_order_id);
+CREATE INDEX IF NOT EXISTS idx_phone_number_provisioning_campaign ON phone_number_provisioning(campaign_id);
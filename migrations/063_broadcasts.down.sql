@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_broadcast_recipients_reply;
+DROP INDEX IF EXISTS idx_broadcast_recipients_pending;
+DROP INDEX IF EXISTS idx_broadcast_recipients_broadcast;
+DROP TABLE IF EXISTS broadcast_recipients;
+DROP INDEX IF EXISTS idx_broadcasts_org_created;
+DROP TABLE IF EXISTS broadcasts;
@@ -0,0 +1,41 @@
+-- Admin-triggered broadcasts to a segment of a clinic's leads. Recipients
+-- are resolved (and consent/opt-out filtered) when the broadcast is created;
+-- the messaging worker sends them at a capped rate outside quiet hours.
+CREATE TABLE IF NOT EXISTS broadcasts (
+    id             UUID PRIMARY KEY,
+    org_id         TEXT NOT NULL,
+    segment        JSONB NOT NULL DEFAULT '{}'::jsonb,
+    template       TEXT NOT NULL,
+    from_number    TEXT NOT NULL,
+    status         TEXT NOT NULL DEFAULT 'sending',
+    audience_count INTEGER NOT NULL DEFAULT 0,
+    created_by     TEXT NOT NULL DEFAULT '',
+    created_at     TIMESTAMPTZ NOT NULL DEFAULT now(),
+    completed_at   TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_broadcasts_org_created ON broadcasts(org_id, created_at DESC);
+
+-- One row per recipient. Phone and body may hold PII ciphertext; phone_hash
+-- matches replies back to the broadcast when phones are encrypted.
+CREATE TABLE IF NOT EXISTS broadcast_recipients (
+    id                  UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    broadcast_id        UUID NOT NULL REFERENCES broadcasts(id) ON DELETE CASCADE,
+    org_id              TEXT NOT NULL,
+    lead_id             UUID,
+    phone               TEXT NOT NULL,
+    phone_hash          TEXT NOT NULL DEFAULT '',
+    body                TEXT NOT NULL,
+    status              TEXT NOT NULL DEFAULT 'pending',
+    suppressed_reason   TEXT NOT NULL DEFAULT '',
+    provider_message_id TEXT NOT NULL DEFAULT '',
+    error               TEXT NOT NULL DEFAULT '',
+    attempts            INTEGER NOT NULL DEFAULT 0,
+    sent_at             TIMESTAMPTZ,
+    created_at          TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at          TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_broadcast_recipients_broadcast ON broadcast_recipients(broadcast_id);
+CREATE INDEX IF NOT EXISTS idx_broadcast_recipients_pending ON broadcast_recipients(created_at) WHERE status IN ('pending', 'sending');
+CREATE INDEX IF NOT EXISTS idx_broadcast_recipients_reply ON broadcast_recipients(org_id, phone_hash, sent_at DESC);
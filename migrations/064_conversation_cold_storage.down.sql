@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_conversations_cold_candidates;
+ALTER TABLE conversations DROP COLUMN IF EXISTS archived_at;
+ALTER TABLE conversations DROP COLUMN IF EXISTS archive_key;
@@ -0,0 +1,11 @@
+-- Cold-storage tiering: conversations whose messages were exported to S3
+-- keep their row as a stub pointing at the archive batch.
+ALTER TABLE conversations ADD COLUMN IF NOT EXISTS archive_key TEXT;
+ALTER TABLE conversations ADD COLUMN IF NOT EXISTS archived_at TIMESTAMPTZ;
+
+-- Supports the archive job's scan for stale, still-hot conversations.
+CREATE INDEX IF NOT EXISTS idx_conversations_cold_candidates
+    ON conversations (org_id, (COALESCE(last_message_at, started_at)))
+    WHERE archive_key IS NULL;
+
+COMMENT ON COLUMN conversations.archive_key IS 'S3 key of the cold-storage batch holding this conversation''s archived messages';
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_crm_webhook_deliveries_dead;
+DROP INDEX IF EXISTS idx_crm_webhook_deliveries_due;
+DROP TABLE IF EXISTS crm_webhook_deliveries;
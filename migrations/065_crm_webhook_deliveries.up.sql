@@ -0,0 +1,24 @@
+-- Lead lifecycle events pushed to clinic CRM webhooks. One row per event per
+-- subscribed endpoint; the deliverer retries with exponential backoff and
+-- parks a row as 'dead' once it runs out of attempts.
+CREATE TABLE IF NOT EXISTS crm_webhook_deliveries (
+    id               UUID PRIMARY KEY,
+    org_id           TEXT NOT NULL,
+    endpoint         TEXT NOT NULL,
+    event_id         UUID NOT NULL,
+    event_type       TEXT NOT NULL,
+    payload          JSONB NOT NULL,
+    status           TEXT NOT NULL DEFAULT 'pending',
+    attempts         INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+    last_status_code INTEGER NOT NULL DEFAULT 0,
+    last_error       TEXT NOT NULL DEFAULT '',
+    delivered_at     TIMESTAMPTZ,
+    dead_lettered_at TIMESTAMPTZ,
+    created_at       TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at       TIMESTAMPTZ NOT NULL DEFAULT now(),
+    UNIQUE (event_id, endpoint)
+);
+
+CREATE INDEX IF NOT EXISTS idx_crm_webhook_deliveries_due ON crm_webhook_deliveries(next_attempt_at) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS idx_crm_webhook_deliveries_dead ON crm_webhook_deliveries(org_id, dead_lettered_at DESC) WHERE status = 'dead';
@@ -0,0 +1,5 @@
+-- Bookings for a time clinic staff offered outside the booking platform's
+-- availability. The platform write-back is expected to fail for these, so
+-- it isn't treated as an incident.
+ALTER TABLE bookings
+    ADD COLUMN IF NOT EXISTS manually_offered boolean NOT NULL DEFAULT false;
@@ -0,0 +1,11 @@
+-- Leads folded into another lead by the phone normalization backfill
+-- (cmd/phone-backfill) because both phones normalized to the same E.164
+-- number. The merged row is deleted; this keeps where it went.
+CREATE TABLE IF NOT EXISTS lead_phone_merges (
+    merged_lead_id UUID PRIMARY KEY,
+    kept_lead_id   UUID NOT NULL,
+    org_id         TEXT NOT NULL,
+    merged_at      TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_lead_phone_merges_kept ON lead_phone_merges(kept_lead_id);
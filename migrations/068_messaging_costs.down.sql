@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS messaging_costs;
+DROP TABLE IF EXISTS llm_usage;
+ALTER TABLE messages
+    DROP COLUMN IF EXISTS cost_usd,
+    DROP COLUMN IF EXISTS segments;
@@ -0,0 +1,37 @@
+-- What each outbound SMS was billed as. cost_usd is the provider's figure
+-- when it reports one, otherwise a per-segment estimate; NULL leaves the
+-- estimate to the cost rollup.
+ALTER TABLE messages
+    ADD COLUMN IF NOT EXISTS segments INTEGER,
+    ADD COLUMN IF NOT EXISTS cost_usd NUMERIC(12, 6);
+
+-- Token usage of each reply completion, attributed to its conversation.
+CREATE TABLE IF NOT EXISTS llm_usage (
+    id              BIGSERIAL PRIMARY KEY,
+    org_id          TEXT NOT NULL,
+    conversation_id TEXT NOT NULL DEFAULT '',
+    model           TEXT NOT NULL,
+    input_tokens    INTEGER NOT NULL DEFAULT 0,
+    output_tokens   INTEGER NOT NULL DEFAULT 0,
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_llm_usage_created ON llm_usage(created_at);
+
+-- SMS and LLM spend per conversation per UTC day, rebuilt by the cost
+-- rollup job.
+CREATE TABLE IF NOT EXISTS messaging_costs (
+    org_id            TEXT NOT NULL,
+    day               DATE NOT NULL,
+    conversation_id   TEXT NOT NULL,
+    messages          INTEGER NOT NULL DEFAULT 0,
+    segments          INTEGER NOT NULL DEFAULT 0,
+    sms_cost_usd      NUMERIC(12, 6) NOT NULL DEFAULT 0,
+    llm_calls         INTEGER NOT NULL DEFAULT 0,
+    llm_input_tokens  BIGINT NOT NULL DEFAULT 0,
+    llm_output_tokens BIGINT NOT NULL DEFAULT 0,
+    llm_cost_usd      NUMERIC(12, 6) NOT NULL DEFAULT 0,
+    PRIMARY KEY (org_id, day, conversation_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_messaging_costs_day ON messaging_costs(day);
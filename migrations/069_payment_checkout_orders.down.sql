@@ -0,0 +1 @@
+DROP TABLE IF EXISTS payment_checkout_orders;
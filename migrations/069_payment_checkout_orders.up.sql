@@ -0,0 +1,7 @@
+-- The Square order behind each deposit's checkout link, so a pending deposit
+-- can be checked with Square directly when the patient says they paid.
+CREATE TABLE IF NOT EXISTS payment_checkout_orders (
+    payment_id UUID PRIMARY KEY REFERENCES payments(id) ON DELETE CASCADE,
+    order_id   TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
@@ -0,0 +1,4 @@
+ALTER TABLE messages DROP COLUMN IF EXISTS author_type;
+ALTER TABLE conversation_messages
+    DROP COLUMN IF EXISTS author_type,
+    DROP COLUMN IF EXISTS direction;
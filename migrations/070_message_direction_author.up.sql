@@ -0,0 +1,47 @@
+-- Who wrote each stored message: direction ('inbound' or 'outbound') and
+-- author_type ('patient', 'ai', 'staff' or 'system'). Role alone can't tell a
+-- staff text from a patient message, so consumers read these instead.
+ALTER TABLE conversation_messages
+    ADD COLUMN IF NOT EXISTS direction TEXT,
+    ADD COLUMN IF NOT EXISTS author_type TEXT;
+
+-- Backfill from role and kind, using the same rules as msgschema.Resolve.
+UPDATE conversation_messages SET author_type = CASE
+        WHEN kind IN ('staff', 'staff_reply', 'manual', 'manual_reply') THEN 'staff'
+        WHEN role = 'user' THEN 'patient'
+        WHEN role = 'system' THEN 'system'
+        WHEN kind IN ('ack', 'first_contact', 'first_contact_ack', 'progress', 'start_ack',
+                      'stop_ack', 'help_ack', 'farewell', 'voice_ack', 'voice_callback_ack',
+                      'voice_callback_initiated', 'voice_callback_failed') THEN 'system'
+        ELSE 'ai'
+    END
+WHERE author_type IS NULL;
+
+UPDATE conversation_messages
+SET direction = CASE WHEN author_type = 'patient' THEN 'inbound' ELSE 'outbound' END
+WHERE direction IS NULL;
+
+ALTER TABLE messages
+    ADD COLUMN IF NOT EXISTS author_type TEXT;
+
+-- Inbound texts are the patient's. Outbound texts the assistant pipeline sent
+-- also appear in conversation_messages under the same provider message ID;
+-- the rest were sent by staff from the portal or as broadcasts.
+-- conversation_messages.provider_message_id isn't indexed, so match with one
+-- join rather than a lookup per message.
+UPDATE messages SET author_type = 'patient'
+WHERE author_type IS NULL AND direction = 'inbound';
+
+UPDATE messages m SET author_type = cm.author_type
+FROM (
+    SELECT DISTINCT ON (provider_message_id) provider_message_id, author_type
+    FROM conversation_messages
+    WHERE provider_message_id IS NOT NULL AND provider_message_id <> ''
+      AND author_type <> 'patient'
+    ORDER BY provider_message_id
+) cm
+WHERE m.provider_message_id = cm.provider_message_id
+  AND m.author_type IS NULL;
+
+UPDATE messages SET author_type = 'staff'
+WHERE author_type IS NULL;
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS emr_writeback_attempts;
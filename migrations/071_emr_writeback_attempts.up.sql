@@ -0,0 +1,22 @@
+-- Each attempt to create a booking's appointment on the clinic's EMR (Moxie).
+-- idempotency_key is stable per booking, so a retried or redelivered booking
+-- can see whether an earlier attempt succeeded or may have reached the EMR
+-- before creating the appointment again. An attempt is recorded as pending
+-- before the EMR call, so one left pending means the worker stopped mid-call.
+CREATE TABLE IF NOT EXISTS emr_writeback_attempts (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    idempotency_key UUID NOT NULL,
+    org_id TEXT NOT NULL,
+    lead_id UUID REFERENCES leads(id) ON DELETE CASCADE,
+    platform TEXT NOT NULL,
+    status TEXT NOT NULL CHECK (status IN ('pending', 'succeeded', 'failed', 'unknown', 'matched_existing')),
+    appointment_id TEXT,
+    error TEXT,
+    started_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    finished_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_emr_writeback_attempts_key
+    ON emr_writeback_attempts(idempotency_key, started_at);
+CREATE INDEX IF NOT EXISTS idx_emr_writeback_attempts_lead
+    ON emr_writeback_attempts(lead_id, started_at DESC);
@@ -0,0 +1,4 @@
+-- The kind of each MMS attachment on a message (image, vcard, pdf or
+-- unknown), in mms_media order, so attachment handling can be reported on
+-- without refetching the media.
+ALTER TABLE messages ADD COLUMN IF NOT EXISTS attachment_kinds TEXT[] NOT NULL DEFAULT '{}';
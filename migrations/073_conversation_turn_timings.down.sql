@@ -0,0 +1,2 @@
+ALTER TABLE conversation_jobs DROP COLUMN IF EXISTS timings;
+DROP TABLE IF EXISTS conversation_turn_timings;
@@ -0,0 +1,21 @@
+-- Per-turn reply latency: how long each inbound text took to answer and
+-- where the time went (queue wait, context build, LLM, availability, send).
+-- Kept per conversation for the admin timeline; tracked jobs also carry
+-- their own turn's timings.
+CREATE TABLE IF NOT EXISTS conversation_turn_timings (
+    id BIGSERIAL PRIMARY KEY,
+    job_id TEXT NOT NULL,
+    org_id TEXT NOT NULL,
+    conversation_id TEXT NOT NULL,
+    received_at TIMESTAMPTZ NOT NULL,
+    stages_ms JSONB NOT NULL DEFAULT '{}',
+    total_ms BIGINT NOT NULL,
+    availability_search BOOLEAN NOT NULL DEFAULT FALSE,
+    over_budget BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_conversation_turn_timings_conversation
+    ON conversation_turn_timings (conversation_id, created_at DESC);
+
+ALTER TABLE conversation_jobs ADD COLUMN IF NOT EXISTS timings JSONB;
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_conversation_jobs_conversation;
+DROP INDEX IF EXISTS idx_bookings_lead_created;
+DROP INDEX IF EXISTS idx_payments_lead_created;
+DROP INDEX IF EXISTS idx_conversations_lead_last_message;
@@ -0,0 +1,13 @@
+-- Indexes for the admin lead list, which joins each lead's latest
+-- conversation, payment and booking in one query.
+CREATE INDEX IF NOT EXISTS idx_conversations_lead_last_message
+    ON conversations (lead_id, last_message_at DESC);
+
+CREATE INDEX IF NOT EXISTS idx_payments_lead_created
+    ON payments (lead_id, created_at DESC);
+
+CREATE INDEX IF NOT EXISTS idx_bookings_lead_created
+    ON bookings (lead_id, created_at DESC);
+
+CREATE INDEX IF NOT EXISTS idx_conversation_jobs_conversation
+    ON conversation_jobs (conversation_id);
@@ -0,0 +1,9 @@
+DROP INDEX IF EXISTS idx_bookings_pending_completion;
+DROP INDEX IF EXISTS idx_payments_unapplied_deposits;
+
+ALTER TABLE bookings
+    DROP COLUMN IF EXISTS completed_at;
+
+ALTER TABLE payments
+    DROP COLUMN IF EXISTS applied_by,
+    DROP COLUMN IF EXISTS applied_at;
@@ -0,0 +1,17 @@
+-- Track when a paid deposit was credited toward the patient's service, and
+-- when an appointment was treated as completed, so clinics can see deposits
+-- still waiting to be applied at checkout.
+ALTER TABLE payments
+    ADD COLUMN IF NOT EXISTS applied_at timestamptz,
+    ADD COLUMN IF NOT EXISTS applied_by text;
+
+ALTER TABLE bookings
+    ADD COLUMN IF NOT EXISTS completed_at timestamptz;
+
+CREATE INDEX IF NOT EXISTS idx_payments_unapplied_deposits
+    ON payments (org_id, lead_id)
+    WHERE status = 'succeeded' AND applied_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_bookings_pending_completion
+    ON bookings (scheduled_for)
+    WHERE completed_at IS NULL;
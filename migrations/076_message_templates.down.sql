@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_messages_template;
+
+ALTER TABLE messages
+    DROP COLUMN IF EXISTS template_version,
+    DROP COLUMN IF EXISTS template_id;
@@ -0,0 +1,9 @@
+-- Record which outbound template (and which version of its copy) produced
+-- each deterministic message, so compliance can review exactly what was sent.
+ALTER TABLE messages
+    ADD COLUMN IF NOT EXISTS template_id text,
+    ADD COLUMN IF NOT EXISTS template_version text;
+
+CREATE INDEX IF NOT EXISTS idx_messages_template
+    ON messages (clinic_id, template_id, template_version)
+    WHERE template_id IS NOT NULL;
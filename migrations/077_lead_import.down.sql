@@ -0,0 +1,3 @@
+ALTER TABLE leads
+    DROP COLUMN IF EXISTS imported_at,
+    DROP COLUMN IF EXISTS last_visit_at;
@@ -0,0 +1,5 @@
+-- Historical lead imports: the patient's last visit from the clinic's
+-- records, and when an import first created or merged the lead.
+ALTER TABLE leads
+    ADD COLUMN IF NOT EXISTS last_visit_at TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS imported_at TIMESTAMPTZ;
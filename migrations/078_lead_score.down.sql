@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_leads_org_score;
+ALTER TABLE leads
+    DROP COLUMN IF EXISTS scored_at,
+    DROP COLUMN IF EXISTS score_breakdown,
+    DROP COLUMN IF EXISTS score,
+    DROP COLUMN IF EXISTS urgency_expressed_at,
+    DROP COLUMN IF EXISTS last_engaged_at;
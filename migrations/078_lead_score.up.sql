@@ -0,0 +1,10 @@
+-- Lead scoring: when the patient last texted, when they last asked for
+-- something soon, and the score the worker computed from them.
+ALTER TABLE leads
+    ADD COLUMN IF NOT EXISTS last_engaged_at TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS urgency_expressed_at TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS score INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS score_breakdown JSONB,
+    ADD COLUMN IF NOT EXISTS scored_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS idx_leads_org_score ON leads (org_id, score DESC, created_at DESC);
@@ -0,0 +1,4 @@
+-- Leads whose first texts were meant for another business. They stay out of
+-- funnel metrics and digests until the patient shows real interest.
+ALTER TABLE leads
+    ADD COLUMN IF NOT EXISTS probable_wrong_number BOOLEAN NOT NULL DEFAULT FALSE;
@@ -0,0 +1,8 @@
+DELETE FROM emr_writeback_attempts WHERE status = 'handed_off';
+
+ALTER TABLE emr_writeback_attempts
+    DROP CONSTRAINT IF EXISTS emr_writeback_attempts_status_check;
+
+ALTER TABLE emr_writeback_attempts
+    ADD CONSTRAINT emr_writeback_attempts_status_check
+    CHECK (status IN ('pending', 'succeeded', 'failed', 'unknown', 'matched_existing'));
@@ -0,0 +1,8 @@
+-- handed_off: the pre-check for an earlier attempt's appointment failed, so
+-- the booking was left to clinic staff rather than risk a duplicate.
+ALTER TABLE emr_writeback_attempts
+    DROP CONSTRAINT IF EXISTS emr_writeback_attempts_status_check;
+
+ALTER TABLE emr_writeback_attempts
+    ADD CONSTRAINT emr_writeback_attempts_status_check
+    CHECK (status IN ('pending', 'succeeded', 'failed', 'unknown', 'matched_existing', 'handed_off'));
@@ -0,0 +1,121 @@
+package adminauth
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func TestParseKeys(t *testing.T) {
+	keys := ParseKeys(" k2:new-secret , plain-secret,, k1:old:with:colons ")
+	want := []Key{{ID: "k2", Secret: "new-secret"}, {Secret: "plain-secret"}, {ID: "k1", Secret: "old:with:colons"}}
+	if len(keys) != len(want) {
+		t.Fatalf("keys = %+v", keys)
+	}
+	for i := range want {
+		if keys[i] != want[i] {
+			t.Fatalf("keys[%d] = %+v, want %+v", i, keys[i], want[i])
+		}
+	}
+}
+
+func TestVerify_KeyRotation(t *testing.T) {
+	oldToken, err := Mint("k1:old-secret", MintOptions{})
+	if err != nil {
+		t.Fatalf("mint: %v", err)
+	}
+	newToken, err := Mint("k2:new-secret,k1:old-secret", MintOptions{})
+	if err != nil {
+		t.Fatalf("mint: %v", err)
+	}
+	unkeyed, err := Mint("old-secret", MintOptions{})
+	if err != nil {
+		t.Fatalf("mint: %v", err)
+	}
+
+	rotating := NewVerifier(ParseKeys("k2:new-secret,k1:old-secret"), DefaultIssuer, DefaultAudience)
+	for name, token := range map[string]string{"old": oldToken, "new": newToken, "unkeyed": unkeyed} {
+		if _, err := rotating.Verify(token); err != nil {
+			t.Fatalf("%s token rejected during rotation: %v", name, err)
+		}
+	}
+
+	retired := NewVerifier(ParseKeys("k2:new-secret"), DefaultIssuer, DefaultAudience)
+	if _, err := retired.Verify(oldToken); !errors.Is(err, ErrUnknownKey) {
+		t.Fatalf("expected retired kid rejected, got %v", err)
+	}
+	if _, err := retired.Verify(unkeyed); err == nil {
+		t.Fatal("expected token signed with a retired secret rejected")
+	}
+	if _, err := retired.Verify(newToken); err != nil {
+		t.Fatalf("new token rejected: %v", err)
+	}
+}
+
+func TestVerify_IssuerAndAudience(t *testing.T) {
+	verifier := NewVerifier(ParseKeys("secret"), DefaultIssuer, DefaultAudience)
+
+	other, err := Mint("secret", MintOptions{Audience: "medspa-portal"})
+	if err != nil {
+		t.Fatalf("mint: %v", err)
+	}
+	if _, err := verifier.Verify(other); !errors.Is(err, jwt.ErrTokenInvalidAudience) {
+		t.Fatalf("expected audience mismatch, got %v", err)
+	}
+
+	foreign, err := Mint("secret", MintOptions{Issuer: "someone-else"})
+	if err != nil {
+		t.Fatalf("mint: %v", err)
+	}
+	if _, err := verifier.Verify(foreign); !errors.Is(err, jwt.ErrTokenInvalidIssuer) {
+		t.Fatalf("expected issuer mismatch, got %v", err)
+	}
+
+	// Without an expected issuer or audience, neither is checked.
+	if _, err := NewVerifier(ParseKeys("secret"), "", "").Verify(other); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestVerify_RequiresExpiry(t *testing.T) {
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.RegisteredClaims{Subject: "admin"}).SignedString([]byte("secret"))
+	if err != nil {
+		t.Fatalf("sign: %v", err)
+	}
+	if _, err := NewVerifier(ParseKeys("secret"), "", "").Verify(token); err == nil {
+		t.Fatal("expected a token without exp rejected")
+	}
+}
+
+func TestScopedTokens(t *testing.T) {
+	if _, err := Mint("secret", MintOptions{Scope: ScopePurge, TTL: 2 * time.Hour}); !errors.Is(err, ErrScopedTTL) {
+		t.Fatalf("expected long-lived scoped token refused, got %v", err)
+	}
+
+	token, err := Mint("secret", MintOptions{Scope: ScopePurge, TTL: 5 * time.Minute})
+	if err != nil {
+		t.Fatalf("mint: %v", err)
+	}
+	claims, err := NewVerifier(ParseKeys("secret"), DefaultIssuer, DefaultAudience).Verify(token)
+	if err != nil {
+		t.Fatalf("verify: %v", err)
+	}
+	if !claims.Scoped() || !claims.HasScope(ScopePurge) || claims.HasScope("admin") {
+		t.Fatalf("claims = %+v", claims)
+	}
+
+	// A hand-rolled scoped token can't outlive the cap either.
+	long := Claims{
+		RegisteredClaims: jwt.RegisteredClaims{ExpiresAt: jwt.NewNumericDate(time.Now().Add(24 * time.Hour))},
+		Scope:            ScopePurge,
+	}
+	raw, err := jwt.NewWithClaims(jwt.SigningMethodHS256, long).SignedString([]byte("secret"))
+	if err != nil {
+		t.Fatalf("sign: %v", err)
+	}
+	if _, err := NewVerifier(ParseKeys("secret"), "", "").Verify(raw); !errors.Is(err, ErrScopedTTL) {
+		t.Fatalf("expected long-lived scoped token rejected, got %v", err)
+	}
+}
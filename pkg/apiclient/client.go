@@ -0,0 +1,247 @@
+// Package apiclient is a typed Go client for the medspa platform API. It
+// covers the admin conversation, lead, clinic config and purge endpoints,
+// deposit checkout, and inbound SMS simulation, so scripts and tests don't
+// have to hand-roll requests and JSON shapes.
+//
+//	client := apiclient.New("https://api-dev.aiwolfsolutions.com", apiclient.WithAdminJWT(secret))
+//	conv, err := client.GetConversation(ctx, orgID, conversationID)
+//
+// Requests that fail with 429, and idempotent requests that fail with 5xx
+// or a network error, are retried with backoff. Error responses are
+// returned as *APIError.
+package apiclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/wolfman30/medspa-ai-platform/pkg/adminauth"
+)
+
+const (
+	defaultTimeout    = 30 * time.Second
+	defaultMaxRetries = 3
+	defaultRetryDelay = 500 * time.Millisecond
+	maxRetryDelay     = 10 * time.Second
+	adminTokenTTL     = 15 * time.Minute
+)
+
+// Client calls the platform API. It is safe for concurrent use.
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+	auth       func() (string, error)
+	maxRetries int
+	retryDelay time.Duration
+}
+
+// Option configures a Client.
+type Option func(*Client)
+
+// WithAdminJWT authenticates admin requests with a short-lived HS256 token
+// signed with the API's ADMIN_JWT_SECRET.
+func WithAdminJWT(secret string) Option {
+	return WithAdminToken(secret, adminauth.MintOptions{TTL: adminTokenTTL})
+}
+
+// WithAdminToken authenticates admin requests with tokens minted per request
+// from secret and opts, e.g. a purge-scoped token for a purge script.
+func WithAdminToken(secret string, opts adminauth.MintOptions) Option {
+	return func(c *Client) {
+		c.auth = func() (string, error) {
+			return adminauth.Mint(secret, opts)
+		}
+	}
+}
+
+// WithBearerToken authenticates with a token issued elsewhere, such as a
+// portal session.
+func WithBearerToken(token string) Option {
+	return func(c *Client) {
+		c.auth = func() (string, error) { return token, nil }
+	}
+}
+
+// WithAPIKey authenticates partner requests with an org API key.
+func WithAPIKey(key string) Option {
+	return WithBearerToken(key)
+}
+
+// WithHTTPClient replaces the default HTTP client.
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		if httpClient != nil {
+			c.httpClient = httpClient
+		}
+	}
+}
+
+// WithRetry sets how many times a failed request is retried, and the delay
+// before the first retry. Later retries back off exponentially. maxRetries
+// of 0 disables retries.
+func WithRetry(maxRetries int, baseDelay time.Duration) Option {
+	return func(c *Client) {
+		c.maxRetries = max(maxRetries, 0)
+		if baseDelay > 0 {
+			c.retryDelay = baseDelay
+		}
+	}
+}
+
+// New creates a client for the API at baseURL.
+func New(baseURL string, opts ...Option) *Client {
+	c := &Client{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		httpClient: &http.Client{Timeout: defaultTimeout},
+		maxRetries: defaultMaxRetries,
+		retryDelay: defaultRetryDelay,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// SignAdminToken returns a full admin JWT accepted by the API's admin routes.
+func SignAdminToken(secret string, ttl time.Duration) (string, error) {
+	return adminauth.Mint(secret, adminauth.MintOptions{TTL: ttl})
+}
+
+// request describes one API call.
+type request struct {
+	method string
+	path   string
+	query  url.Values
+	orgID  string // sent as X-Org-Id for tenant-scoped routes
+	body   any
+	noAuth bool
+	// idempotent marks a POST that is safe to resend after a 5xx or network
+	// error. GET, PUT and DELETE always are.
+	idempotent bool
+}
+
+func (r request) retrySafe() bool {
+	switch r.method {
+	case http.MethodGet, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return r.idempotent
+}
+
+// do sends req, retrying transient failures, and decodes a successful JSON
+// response into out when out is non-nil.
+func (c *Client) do(ctx context.Context, req request, out any) error {
+	var payload []byte
+	if req.body != nil {
+		var err error
+		if payload, err = json.Marshal(req.body); err != nil {
+			return fmt.Errorf("apiclient: encode %s %s: %w", req.method, req.path, err)
+		}
+	}
+	var token string
+	if c.auth != nil && !req.noAuth {
+		var err error
+		if token, err = c.auth(); err != nil {
+			return err
+		}
+	}
+
+	for attempt := 0; ; attempt++ {
+		resp, err := c.send(ctx, req, payload, token)
+		if err != nil {
+			if ctx.Err() != nil || !req.retrySafe() || attempt >= c.maxRetries {
+				return fmt.Errorf("apiclient: %s %s: %w", req.method, req.path, err)
+			}
+			if err := c.wait(ctx, attempt, ""); err != nil {
+				return err
+			}
+			continue
+		}
+
+		body, readErr := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if readErr != nil {
+			return fmt.Errorf("apiclient: read %s %s: %w", req.method, req.path, readErr)
+		}
+		if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+			if out == nil || len(bytes.TrimSpace(body)) == 0 {
+				return nil
+			}
+			if err := json.Unmarshal(body, out); err != nil {
+				return fmt.Errorf("apiclient: decode %s %s: %w", req.method, req.path, err)
+			}
+			return nil
+		}
+
+		apiErr := parseAPIError(resp.StatusCode, body)
+		if !retryable(req, resp.StatusCode) || attempt >= c.maxRetries {
+			return apiErr
+		}
+		if err := c.wait(ctx, attempt, resp.Header.Get("Retry-After")); err != nil {
+			return err
+		}
+	}
+}
+
+func (c *Client) send(ctx context.Context, req request, payload []byte, token string) (*http.Response, error) {
+	target := c.baseURL + req.path
+	if len(req.query) > 0 {
+		target += "?" + req.query.Encode()
+	}
+	var body io.Reader
+	if payload != nil {
+		body = bytes.NewReader(payload)
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, req.method, target, body)
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Accept", "application/json")
+	if payload != nil {
+		httpReq.Header.Set("Content-Type", "application/json")
+	}
+	if req.orgID != "" {
+		httpReq.Header.Set("X-Org-Id", req.orgID)
+	}
+	if token != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+token)
+	}
+	return c.httpClient.Do(httpReq)
+}
+
+// wait sleeps before the next retry, honoring Retry-After when the server
+// sent one.
+func (c *Client) wait(ctx context.Context, attempt int, retryAfter string) error {
+	delay := min(c.retryDelay<<attempt, maxRetryDelay)
+	if secs, err := strconv.Atoi(strings.TrimSpace(retryAfter)); err == nil && secs >= 0 {
+		delay = min(time.Duration(secs)*time.Second, maxRetryDelay)
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return fmt.Errorf("apiclient: waiting to retry: %w", ctx.Err())
+	case <-timer.C:
+		return nil
+	}
+}
+
+// retryable reports whether a failed response should be retried. A 429
+// means the request wasn't processed, so any request is resent; a 5xx may
+// have been partly processed, so only retry-safe requests are.
+func retryable(req request, status int) bool {
+	return status == http.StatusTooManyRequests || (status >= 500 && req.retrySafe())
+}
+
+// escape path-escapes each ID placed in a URL.
+func escape(segment string) string {
+	return url.PathEscape(strings.TrimSpace(segment))
+}
@@ -0,0 +1,405 @@
+package apiclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/http/apierror"
+	"github.com/wolfman30/medspa-ai-platform/pkg/adminauth"
+)
+
+const testSecret = "test-admin-secret"
+
+// newTestClient serves handler and returns an admin client with fast
+// retries pointed at it.
+func newTestClient(t *testing.T, handler http.HandlerFunc) *Client {
+	t.Helper()
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+	return New(srv.URL+"/", WithAdminJWT(testSecret), WithRetry(2, time.Millisecond))
+}
+
+func writeJSON(t *testing.T, w http.ResponseWriter, v any) {
+	t.Helper()
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		t.Errorf("encode response: %v", err)
+	}
+}
+
+// requireAdmin fails unless the request carries a valid admin JWT.
+func requireAdmin(t *testing.T, r *http.Request) {
+	t.Helper()
+	raw, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok {
+		t.Fatalf("missing bearer token on %s %s", r.Method, r.URL.Path)
+	}
+	verifier := adminauth.NewVerifier(adminauth.ParseKeys(testSecret), adminauth.DefaultIssuer, adminauth.DefaultAudience)
+	claims, err := verifier.Verify(raw)
+	if err != nil {
+		t.Fatalf("invalid admin token: %v", err)
+	}
+	if claims.Subject != "admin" || claims.Scoped() {
+		t.Fatalf("unexpected admin claims: %+v", claims)
+	}
+}
+
+func TestClient_AuthHeaders(t *testing.T) {
+	var got []string
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		got = append(got, r.Header.Get("Authorization"))
+		writeJSON(t, w, map[string]any{})
+	}
+	srv := httptest.NewServer(http.HandlerFunc(handler))
+	defer srv.Close()
+	ctx := context.Background()
+
+	if _, err := New(srv.URL, WithAPIKey("sk_live_123")).ListLeads(ctx, "org-1", ListLeadsOptions{}); err != nil {
+		t.Fatalf("api key request: %v", err)
+	}
+	if _, err := New(srv.URL, WithBearerToken("portal-token")).ListLeads(ctx, "org-1", ListLeadsOptions{}); err != nil {
+		t.Fatalf("bearer request: %v", err)
+	}
+	if _, err := New(srv.URL).ListLeads(ctx, "org-1", ListLeadsOptions{}); err != nil {
+		t.Fatalf("anonymous request: %v", err)
+	}
+	want := []string{"Bearer sk_live_123", "Bearer portal-token", ""}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Fatalf("authorization headers = %q, want %q", got, want)
+	}
+
+	if _, err := New(srv.URL, WithAdminJWT("")).ListLeads(ctx, "org-1", ListLeadsOptions{}); err == nil {
+		t.Fatalf("expected an error signing with an empty secret")
+	}
+}
+
+func TestClient_GetConversation(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		requireAdmin(t, r)
+		if r.Method != http.MethodGet || r.URL.EscapedPath() != "/admin/orgs/org-1/conversations/sms:org-1:15550001111" {
+			t.Fatalf("unexpected request %s %s", r.Method, r.URL.EscapedPath())
+		}
+		writeJSON(t, w, map[string]any{
+			"id":     "sms:org-1:15550001111",
+			"status": "awaiting_time_selection",
+			"messages": []any{
+				map[string]any{"id": "m1", "role": "user", "content": "I want Botox"},
+				map[string]any{"id": "m2", "role": "assistant", "content": "Great!"},
+			},
+			"metadata": map[string]any{"total_messages": 2, "source": "database"},
+		})
+	})
+
+	conv, err := client.GetConversation(context.Background(), "org-1", "sms:org-1:15550001111")
+	if err != nil {
+		t.Fatalf("GetConversation: %v", err)
+	}
+	if conv.Status != "awaiting_time_selection" || len(conv.Messages) != 2 || conv.Messages[1].Content != "Great!" || conv.Metadata.TotalMessages != 2 {
+		t.Fatalf("unexpected conversation: %+v", conv)
+	}
+}
+
+func TestClient_ListConversations(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		requireAdmin(t, r)
+		if r.URL.Path != "/admin/orgs/org-1/conversations" {
+			t.Fatalf("unexpected path %s", r.URL.Path)
+		}
+		if q := r.URL.Query(); q.Get("page") != "2" || q.Get("phone") != "+15550001111" || q.Has("status") || q.Has("page_size") {
+			t.Fatalf("unexpected query %s", r.URL.RawQuery)
+		}
+		writeJSON(t, w, map[string]any{
+			"conversations": []any{map[string]any{"id": "c1", "message_count": 4}},
+			"total":         21, "page": 2, "page_size": 20, "total_pages": 2,
+		})
+	})
+
+	list, err := client.ListConversations(context.Background(), "org-1", ListConversationsOptions{Page: 2, Phone: "+15550001111"})
+	if err != nil {
+		t.Fatalf("ListConversations: %v", err)
+	}
+	if list.Total != 21 || len(list.Conversations) != 1 || list.Conversations[0].MessageCount != 4 {
+		t.Fatalf("unexpected list: %+v", list)
+	}
+}
+
+func TestClient_SimulateInboundSMS(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/webhooks/telnyx/messages" {
+			t.Fatalf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		if r.Header.Get("Authorization") != "" {
+			t.Fatalf("webhook requests must not carry the admin token")
+		}
+		var event struct {
+			Data struct {
+				EventType string `json:"event_type"`
+				Payload   struct {
+					ID   string `json:"id"`
+					From struct {
+						PhoneNumber string `json:"phone_number"`
+					} `json:"from"`
+					To []struct {
+						PhoneNumber string `json:"phone_number"`
+					} `json:"to"`
+					Text      string `json:"text"`
+					Direction string `json:"direction"`
+				} `json:"payload"`
+			} `json:"data"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&event); err != nil {
+			t.Fatalf("decode event: %v", err)
+		}
+		p := event.Data.Payload
+		if event.Data.EventType != "message.received" || p.ID != "msg-1" || p.From.PhoneNumber != "+15550001111" ||
+			len(p.To) != 1 || p.To[0].PhoneNumber != "+14405550000" || p.Text != "hi" || p.Direction != "inbound" {
+			t.Fatalf("unexpected event: %+v", event)
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+
+	if err := client.SimulateInboundSMS(context.Background(), InboundSMS{
+		From: "+15550001111", To: "+14405550000", Text: "hi", MessageID: "msg-1",
+	}); err != nil {
+		t.Fatalf("SimulateInboundSMS: %v", err)
+	}
+}
+
+func TestClient_Leads(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		requireAdmin(t, r)
+		switch r.URL.Path {
+		case "/admin/orgs/org-1/leads":
+			if q := r.URL.Query(); q.Get("status") != "booked" || q.Get("search") != "sarah" {
+				t.Fatalf("unexpected query %s", r.URL.RawQuery)
+			}
+			writeJSON(t, w, map[string]any{"leads": []any{map[string]any{"id": "lead-1", "phone": "+15550001111"}}, "total": 1})
+		case "/admin/orgs/org-1/leads/lead-1":
+			writeJSON(t, w, map[string]any{
+				"id": "lead-1", "payment_total_cents": 5000,
+				"conversation_ids": []string{"c1"},
+				"payments":         []any{map[string]any{"id": "p1", "amount_cents": 5000}},
+			})
+		default:
+			t.Fatalf("unexpected path %s", r.URL.Path)
+		}
+	})
+	ctx := context.Background()
+
+	list, err := client.ListLeads(ctx, "org-1", ListLeadsOptions{Status: "booked", Search: "sarah"})
+	if err != nil {
+		t.Fatalf("ListLeads: %v", err)
+	}
+	if list.Total != 1 || list.Leads[0].Phone != "+15550001111" {
+		t.Fatalf("unexpected leads: %+v", list)
+	}
+	lead, err := client.GetLead(ctx, "org-1", "lead-1")
+	if err != nil {
+		t.Fatalf("GetLead: %v", err)
+	}
+	if lead.ID != "lead-1" || lead.PaymentTotalCents != 5000 || len(lead.ConversationIDs) != 1 || lead.Payments[0].AmountCents != 5000 {
+		t.Fatalf("unexpected lead: %+v", lead)
+	}
+}
+
+func TestClient_ClinicConfig(t *testing.T) {
+	stored := map[string]any{"name": "Glow Spa", "timezone": "America/New_York"}
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		requireAdmin(t, r)
+		if r.URL.Path != "/admin/clinics/org-1/config" {
+			t.Fatalf("unexpected path %s", r.URL.Path)
+		}
+		if r.Method == http.MethodPut {
+			if r.Header.Get("Content-Type") != "application/json" {
+				t.Fatalf("expected a JSON body")
+			}
+			var update map[string]any
+			if err := json.NewDecoder(r.Body).Decode(&update); err != nil {
+				t.Fatalf("decode update: %v", err)
+			}
+			for k, v := range update {
+				stored[k] = v
+			}
+		}
+		writeJSON(t, w, stored)
+	})
+	ctx := context.Background()
+
+	var cfg struct {
+		Name     string `json:"name"`
+		Timezone string `json:"timezone"`
+	}
+	if err := client.GetClinicConfig(ctx, "org-1", &cfg); err != nil {
+		t.Fatalf("GetClinicConfig: %v", err)
+	}
+	if cfg.Name != "Glow Spa" {
+		t.Fatalf("unexpected config: %+v", cfg)
+	}
+	if err := client.UpdateClinicConfig(ctx, "org-1", map[string]any{"timezone": "America/Chicago"}, &cfg); err != nil {
+		t.Fatalf("UpdateClinicConfig: %v", err)
+	}
+	if cfg.Name != "Glow Spa" || cfg.Timezone != "America/Chicago" {
+		t.Fatalf("expected a partial update, got %+v", cfg)
+	}
+}
+
+func TestClient_PurgePhone(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		requireAdmin(t, r)
+		if r.Method != http.MethodDelete || r.URL.Path != "/admin/clinics/org-1/phones/+15550001111" {
+			t.Fatalf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		writeJSON(t, w, map[string]any{
+			"org_id":          "org-1",
+			"phone_e164":      "+15550001111",
+			"conversation_id": "sms:org-1:15550001111",
+			"deleted":         map[string]any{"leads": 1, "messages": 6},
+			"redis_deleted":   3,
+		})
+	})
+
+	result, err := client.PurgePhone(context.Background(), "org-1", "+15550001111")
+	if err != nil {
+		t.Fatalf("PurgePhone: %v", err)
+	}
+	if result.Deleted.Leads != 1 || result.Deleted.Messages != 6 || result.RedisDeleted != 3 || result.Archived != nil {
+		t.Fatalf("unexpected purge result: %+v", result)
+	}
+}
+
+func TestClient_CreateCheckout(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/payments/checkout" || r.Header.Get("X-Org-Id") != "org-1" {
+			t.Fatalf("unexpected request %s %s org=%q", r.Method, r.URL.Path, r.Header.Get("X-Org-Id"))
+		}
+		var req CheckoutRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatalf("decode checkout: %v", err)
+		}
+		if req.LeadID != "lead-1" || req.AmountCents != 5000 {
+			t.Fatalf("unexpected checkout request: %+v", req)
+		}
+		writeJSON(t, w, CheckoutResponse{CheckoutURL: "https://pay.example/abc", Provider: "square"})
+	})
+
+	resp, err := client.CreateCheckout(context.Background(), "org-1", CheckoutRequest{LeadID: "lead-1", AmountCents: 5000})
+	if err != nil {
+		t.Fatalf("CreateCheckout: %v", err)
+	}
+	if resp.CheckoutURL != "https://pay.example/abc" || resp.Provider != "square" {
+		t.Fatalf("unexpected checkout: %+v", resp)
+	}
+}
+
+func TestClient_RetriesTransientFailures(t *testing.T) {
+	var calls atomic.Int32
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch calls.Add(1) {
+		case 1:
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+		case 2:
+			w.WriteHeader(http.StatusBadGateway)
+		default:
+			writeJSON(t, w, map[string]any{"id": "c1"})
+		}
+	})
+
+	conv, err := client.GetConversation(context.Background(), "org-1", "c1")
+	if err != nil {
+		t.Fatalf("expected the third attempt to succeed: %v", err)
+	}
+	if conv.ID != "c1" || calls.Load() != 3 {
+		t.Fatalf("expected 3 attempts, got %d", calls.Load())
+	}
+}
+
+func TestClient_RetryLimits(t *testing.T) {
+	var calls atomic.Int32
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+	ctx := context.Background()
+
+	_, err := client.ListLeads(ctx, "org-1", ListLeadsOptions{})
+	if !IsCode(err, CodeDependencyUnavailable) || calls.Load() != 3 {
+		t.Fatalf("expected dependency_unavailable after 3 attempts, got %v after %d", err, calls.Load())
+	}
+
+	// A checkout may have been created before the 5xx, so it isn't resent.
+	calls.Store(0)
+	if _, err := client.CreateCheckout(ctx, "org-1", CheckoutRequest{LeadID: "lead-1"}); err == nil || calls.Load() != 1 {
+		t.Fatalf("expected one checkout attempt, got %d", calls.Load())
+	}
+
+	// A canceled context stops waiting between attempts.
+	calls.Store(0)
+	slow := New(client.baseURL, WithRetry(5, time.Hour))
+	cancelCtx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+	if _, err := slow.ListLeads(cancelCtx, "org-1", ListLeadsOptions{}); !errors.Is(err, context.DeadlineExceeded) || calls.Load() != 1 {
+		t.Fatalf("expected the deadline to end retries, got %v after %d", err, calls.Load())
+	}
+}
+
+func TestClient_ErrorMapping(t *testing.T) {
+	cases := []struct {
+		name    string
+		status  int
+		body    string
+		code    ErrorCode
+		message string
+		request string
+	}{
+		{"envelope", http.StatusNotFound, `{"error":{"code":"not_found","message":"lead not found","request_id":"req-1"}}`, CodeNotFound, "lead not found", "req-1"},
+		{"legacy json", http.StatusConflict, `{"error":"already booked"}`, CodeConflict, "already booked", ""},
+		{"plain text", http.StatusBadRequest, "org id required\n", CodeValidationFailed, "org id required", ""},
+		{"empty", http.StatusForbidden, "", CodeForbidden, "", ""},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tc.status)
+				_, _ = io.WriteString(w, tc.body)
+			})
+			_, err := client.GetLead(context.Background(), "org-1", "lead-1")
+			var apiErr *APIError
+			if !errors.As(err, &apiErr) {
+				t.Fatalf("expected *APIError, got %T %v", err, err)
+			}
+			if apiErr.StatusCode != tc.status || apiErr.Code != tc.code || apiErr.Message != tc.message || apiErr.RequestID != tc.request {
+				t.Fatalf("unexpected error: %+v", apiErr)
+			}
+		})
+	}
+
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(apierror.Envelope{Error: apierror.Body{
+			Code: apierror.CodeValidationFailed, Message: "invalid", Details: map[string]any{"field": "amount_cents"},
+		}})
+	})
+	_, err := client.CreateCheckout(context.Background(), "org-1", CheckoutRequest{})
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.Details["field"] != "amount_cents" {
+		t.Fatalf("expected envelope details, got %v", err)
+	}
+}
+
+func TestErrorCodesMatchServer(t *testing.T) {
+	for _, status := range []int{400, 401, 403, 404, 405, 409, 418, 422, 429, 500, 502, 503, 504} {
+		if got, want := codeForStatus(status), ErrorCode(apierror.CodeForStatus(status)); got != want {
+			t.Errorf("codeForStatus(%d) = %s, server uses %s", status, got, want)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package apiclient
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+)
+
+// GetClinicConfig decodes the clinic's configuration into out, typically a
+// *clinic.Config or a map for ad-hoc inspection.
+func (c *Client) GetClinicConfig(ctx context.Context, orgID string, out any) error {
+	return c.do(ctx, request{
+		method: http.MethodGet,
+		path:   fmt.Sprintf("/admin/clinics/%s/config", escape(orgID)),
+	}, out)
+}
+
+// UpdateClinicConfig applies a partial config update. Only the fields
+// present in update change. The saved configuration is decoded into out
+// when it is non-nil.
+func (c *Client) UpdateClinicConfig(ctx context.Context, orgID string, update, out any) error {
+	return c.do(ctx, request{
+		method: http.MethodPut,
+		path:   fmt.Sprintf("/admin/clinics/%s/config", escape(orgID)),
+		body:   update,
+	}, out)
+}
+
+// PurgeResult reports what a phone purge removed.
+type PurgeResult struct {
+	OrgID          string        `json:"org_id"`
+	Phone          string        `json:"phone"`
+	PhoneDigits    string        `json:"phone_digits"`
+	PhoneE164      string        `json:"phone_e164"`
+	ConversationID string        `json:"conversation_id"`
+	Deleted        PurgeCounts   `json:"deleted"`
+	RedisDeleted   int64         `json:"redis_deleted"`
+	Archived       *PurgeArchive `json:"archived,omitempty"`
+}
+
+// PurgeCounts is the number of rows deleted per table.
+type PurgeCounts struct {
+	ConversationJobs int64 `json:"conversation_jobs"`
+	Outbox           int64 `json:"outbox"`
+	Payments         int64 `json:"payments"`
+	Bookings         int64 `json:"bookings"`
+	Leads            int64 `json:"leads"`
+	Messages         int64 `json:"messages"`
+	Unsubscribes     int64 `json:"unsubscribes"`
+}
+
+// PurgeArchive describes the archive written before the purge.
+type PurgeArchive struct {
+	ConversationsArchived int    `json:"conversations_archived"`
+	MessagesArchived      int    `json:"messages_archived"`
+	S3Key                 string `json:"s3_key"`
+	Encrypted             bool   `json:"encrypted"`
+}
+
+// PurgePhone deletes a phone number's test data (conversations, leads,
+// payments, bookings and opt-outs) from a clinic.
+func (c *Client) PurgePhone(ctx context.Context, orgID, phone string) (*PurgeResult, error) {
+	var result PurgeResult
+	if err := c.do(ctx, request{
+		method: http.MethodDelete,
+		path:   fmt.Sprintf("/admin/clinics/%s/phones/%s", escape(orgID), escape(phone)),
+	}, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
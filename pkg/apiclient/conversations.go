@@ -0,0 +1,171 @@
+package apiclient
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// Conversation is a conversation with its transcript.
+type Conversation struct {
+	ID            string               `json:"id"`
+	OrgID         string               `json:"org_id"`
+	Channel       string               `json:"channel"`
+	CustomerPhone string               `json:"customer_phone"`
+	CustomerName  string               `json:"customer_name"`
+	Status        string               `json:"status"`
+	StartedAt     string               `json:"started_at"`
+	LastMessageAt *string              `json:"last_message_at,omitempty"`
+	Messages      []Message            `json:"messages"`
+	Metadata      ConversationMetadata `json:"metadata"`
+}
+
+// Message is one message in a conversation transcript.
+type Message struct {
+	ID                string `json:"id"`
+	Role              string `json:"role"`        // "user" or "assistant"
+	Direction         string `json:"direction"`   // "inbound" or "outbound"
+	AuthorType        string `json:"author_type"` // "patient", "ai", "staff" or "system"
+	Content           string `json:"content"`
+	Timestamp         string `json:"timestamp"`
+	From              string `json:"from,omitempty"`
+	To                string `json:"to,omitempty"`
+	ProviderMessageID string `json:"provider_message_id,omitempty"`
+	Status            string `json:"status,omitempty"`
+	ErrorReason       string `json:"error_reason,omitempty"`
+}
+
+// ConversationMetadata summarizes a transcript.
+type ConversationMetadata struct {
+	TotalMessages    int    `json:"total_messages"`
+	CustomerMessages int    `json:"customer_messages"`
+	AIMessages       int    `json:"ai_messages"`
+	Source           string `json:"source"`
+}
+
+// ConversationSummary is a conversation in a list, without its transcript.
+type ConversationSummary struct {
+	ID                   string  `json:"id"`
+	OrgID                string  `json:"org_id"`
+	Channel              string  `json:"channel"`
+	CustomerPhone        string  `json:"customer_phone"`
+	CustomerName         string  `json:"customer_name"`
+	Status               string  `json:"status"`
+	MessageCount         int     `json:"message_count"`
+	CustomerMessageCount int     `json:"customer_message_count"`
+	AIMessageCount       int     `json:"ai_message_count"`
+	StartedAt            string  `json:"started_at"`
+	LastMessageAt        *string `json:"last_message_at,omitempty"`
+}
+
+// ConversationList is a page of conversations.
+type ConversationList struct {
+	Conversations []ConversationSummary `json:"conversations"`
+	Total         int                   `json:"total"`
+	Page          int                   `json:"page"`
+	PageSize      int                   `json:"page_size"`
+	TotalPages    int                   `json:"total_pages"`
+}
+
+// ListConversationsOptions filters a conversation list. Zero values are
+// left to the API's defaults.
+type ListConversationsOptions struct {
+	Page     int
+	PageSize int
+	Phone    string
+	Status   string
+	DateFrom string // YYYY-MM-DD
+	DateTo   string // YYYY-MM-DD
+}
+
+func (o ListConversationsOptions) values() url.Values {
+	q := url.Values{}
+	setInt(q, "page", o.Page)
+	setInt(q, "page_size", o.PageSize)
+	setString(q, "phone", o.Phone)
+	setString(q, "status", o.Status)
+	setString(q, "date_from", o.DateFrom)
+	setString(q, "date_to", o.DateTo)
+	return q
+}
+
+// GetConversation returns a conversation and its transcript.
+func (c *Client) GetConversation(ctx context.Context, orgID, conversationID string) (*Conversation, error) {
+	var conv Conversation
+	if err := c.do(ctx, request{
+		method: http.MethodGet,
+		path:   fmt.Sprintf("/admin/orgs/%s/conversations/%s", escape(orgID), escape(conversationID)),
+	}, &conv); err != nil {
+		return nil, err
+	}
+	return &conv, nil
+}
+
+// ListConversations returns a page of the clinic's conversations.
+func (c *Client) ListConversations(ctx context.Context, orgID string, opts ListConversationsOptions) (*ConversationList, error) {
+	var list ConversationList
+	if err := c.do(ctx, request{
+		method: http.MethodGet,
+		path:   fmt.Sprintf("/admin/orgs/%s/conversations", escape(orgID)),
+		query:  opts.values(),
+	}, &list); err != nil {
+		return nil, err
+	}
+	return &list, nil
+}
+
+// InboundSMS is a patient text to deliver through the Telnyx webhook.
+type InboundSMS struct {
+	From string // patient phone, E.164
+	To   string // clinic phone, E.164
+	Text string
+	// MessageID defaults to a unique ID; reuse one to test deduplication.
+	MessageID string
+}
+
+// SimulateInboundSMS posts a Telnyx message.received event to the API as if
+// the patient had texted the clinic. Processing is asynchronous; poll
+// GetConversation for the reply.
+func (c *Client) SimulateInboundSMS(ctx context.Context, sms InboundSMS) error {
+	id := sms.MessageID
+	if id == "" {
+		id = fmt.Sprintf("sim-%d", time.Now().UnixNano())
+	}
+	event := map[string]any{
+		"data": map[string]any{
+			"id":         "evt-" + id,
+			"event_type": "message.received",
+			"payload": map[string]any{
+				"id":        id,
+				"from":      map[string]string{"phone_number": sms.From},
+				"to":        []map[string]string{{"phone_number": sms.To}},
+				"text":      sms.Text,
+				"direction": "inbound",
+				"type":      "SMS",
+			},
+		},
+	}
+	return c.do(ctx, request{
+		method: http.MethodPost,
+		path:   "/webhooks/telnyx/messages",
+		body:   event,
+		noAuth: true,
+		// The webhook deduplicates on the message ID.
+		idempotent: true,
+	}, nil)
+}
+
+func setInt(q url.Values, key string, v int) {
+	if v > 0 {
+		q.Set(key, strconv.Itoa(v))
+	}
+}
+
+func setString(q url.Values, key, v string) {
+	if v != "" {
+		q.Set(key, v)
+	}
+}
@@ -0,0 +1,120 @@
+package apiclient
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// ErrorCode identifies a class of API failure. The values match the codes
+// in the API's standard error envelope.
+type ErrorCode string
+
+// Error codes returned by the API.
+const (
+	CodeValidationFailed      ErrorCode = "validation_failed"
+	CodeUnauthorized          ErrorCode = "unauthorized"
+	CodeForbidden             ErrorCode = "forbidden"
+	CodeNotFound              ErrorCode = "not_found"
+	CodeMethodNotAllowed      ErrorCode = "method_not_allowed"
+	CodeConflict              ErrorCode = "conflict"
+	CodeRateLimited           ErrorCode = "rate_limited"
+	CodeDependencyUnavailable ErrorCode = "dependency_unavailable"
+	CodeTimeout               ErrorCode = "timeout"
+	CodeInternal              ErrorCode = "internal"
+)
+
+// APIError is a non-2xx response from the API.
+type APIError struct {
+	StatusCode int
+	Code       ErrorCode
+	Message    string
+	Details    map[string]any
+	RequestID  string
+}
+
+func (e *APIError) Error() string {
+	msg := e.Message
+	if msg == "" {
+		msg = http.StatusText(e.StatusCode)
+	}
+	if e.RequestID != "" {
+		return fmt.Sprintf("apiclient: %d %s: %s (request %s)", e.StatusCode, e.Code, msg, e.RequestID)
+	}
+	return fmt.Sprintf("apiclient: %d %s: %s", e.StatusCode, e.Code, msg)
+}
+
+// IsCode reports whether err is an *APIError with the given code.
+func IsCode(err error, code ErrorCode) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.Code == code
+}
+
+// IsNotFound reports whether err is a not_found API error.
+func IsNotFound(err error) bool {
+	return IsCode(err, CodeNotFound)
+}
+
+// parseAPIError decodes the standard error envelope. Older endpoints still
+// answer with {"error": "message"} or plain text; those get a code derived
+// from the status.
+func parseAPIError(status int, body []byte) *APIError {
+	apiErr := &APIError{StatusCode: status, Code: codeForStatus(status)}
+
+	var envelope struct {
+		Error json.RawMessage `json:"error"`
+	}
+	if err := json.Unmarshal(body, &envelope); err == nil && len(envelope.Error) > 0 {
+		var structured struct {
+			Code      ErrorCode      `json:"code"`
+			Message   string         `json:"message"`
+			Details   map[string]any `json:"details"`
+			RequestID string         `json:"request_id"`
+		}
+		var message string
+		switch {
+		case json.Unmarshal(envelope.Error, &structured) == nil && structured.Code != "":
+			apiErr.Code = structured.Code
+			apiErr.Message = structured.Message
+			apiErr.Details = structured.Details
+			apiErr.RequestID = structured.RequestID
+			return apiErr
+		case json.Unmarshal(envelope.Error, &message) == nil:
+			apiErr.Message = message
+			return apiErr
+		}
+	}
+	apiErr.Message = strings.TrimSpace(string(body))
+	return apiErr
+}
+
+// codeForStatus mirrors the API's status-to-code mapping for responses
+// that don't carry a code.
+func codeForStatus(status int) ErrorCode {
+	switch status {
+	case http.StatusBadRequest, http.StatusUnprocessableEntity:
+		return CodeValidationFailed
+	case http.StatusUnauthorized:
+		return CodeUnauthorized
+	case http.StatusForbidden:
+		return CodeForbidden
+	case http.StatusNotFound:
+		return CodeNotFound
+	case http.StatusMethodNotAllowed:
+		return CodeMethodNotAllowed
+	case http.StatusConflict:
+		return CodeConflict
+	case http.StatusTooManyRequests:
+		return CodeRateLimited
+	case http.StatusBadGateway, http.StatusServiceUnavailable:
+		return CodeDependencyUnavailable
+	case http.StatusGatewayTimeout:
+		return CodeTimeout
+	}
+	if status >= 400 && status < 500 {
+		return CodeValidationFailed
+	}
+	return CodeInternal
+}
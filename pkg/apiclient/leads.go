@@ -0,0 +1,118 @@
+package apiclient
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// Lead is a patient lead as returned by the admin API.
+type Lead struct {
+	ID                   string   `json:"id"`
+	OrgID                string   `json:"org_id"`
+	Phone                string   `json:"phone"`
+	Name                 string   `json:"name,omitempty"`
+	Email                string   `json:"email,omitempty"`
+	Status               string   `json:"status"`
+	Source               string   `json:"source,omitempty"`
+	InterestedServices   []string `json:"interested_services,omitempty"`
+	LastContactAt        *string  `json:"last_contact_at,omitempty"`
+	ConversationJobCount int      `json:"conversation_job_count"`
+	PaymentTotalCents    int      `json:"payment_total_cents"`
+	BookingCount         int      `json:"booking_count"`
+	Tags                 []string `json:"tags,omitempty"`
+	Notes                string   `json:"notes,omitempty"`
+	CreatedAt            string   `json:"created_at"`
+	UpdatedAt            string   `json:"updated_at"`
+}
+
+// LeadDetail is a lead with its conversations, payments, bookings and
+// activity timeline.
+type LeadDetail struct {
+	Lead
+	ConversationIDs []string        `json:"conversation_ids"`
+	Payments        []LeadPayment   `json:"payments"`
+	Bookings        []LeadBooking   `json:"bookings"`
+	Timeline        []TimelineEvent `json:"timeline"`
+}
+
+// LeadPayment summarizes a payment made by a lead.
+type LeadPayment struct {
+	ID          string `json:"id"`
+	AmountCents int    `json:"amount_cents"`
+	Status      string `json:"status"`
+	CreatedAt   string `json:"created_at"`
+}
+
+// LeadBooking summarizes a lead's booking.
+type LeadBooking struct {
+	ID          string `json:"id"`
+	Service     string `json:"service"`
+	ScheduledAt string `json:"scheduled_at"`
+	Status      string `json:"status"`
+}
+
+// TimelineEvent is one entry in a lead's activity timeline.
+type TimelineEvent struct {
+	Type        string `json:"type"`
+	Description string `json:"description"`
+	Timestamp   string `json:"timestamp"`
+	Metadata    any    `json:"metadata,omitempty"`
+}
+
+// LeadList is a page of leads.
+type LeadList struct {
+	Leads      []Lead `json:"leads"`
+	Total      int    `json:"total"`
+	Page       int    `json:"page"`
+	PageSize   int    `json:"page_size"`
+	TotalPages int    `json:"total_pages"`
+}
+
+// ListLeadsOptions filters a lead list. Zero values are left to the API's
+// defaults.
+type ListLeadsOptions struct {
+	Page      int
+	PageSize  int
+	Status    string
+	Search    string
+	SortBy    string
+	SortOrder string // "asc" or "desc"
+}
+
+func (o ListLeadsOptions) values() url.Values {
+	q := url.Values{}
+	setInt(q, "page", o.Page)
+	setInt(q, "page_size", o.PageSize)
+	setString(q, "status", o.Status)
+	setString(q, "search", o.Search)
+	setString(q, "sort_by", o.SortBy)
+	setString(q, "sort_order", o.SortOrder)
+	return q
+}
+
+// ListLeads returns a page of the clinic's leads.
+func (c *Client) ListLeads(ctx context.Context, orgID string, opts ListLeadsOptions) (*LeadList, error) {
+	var list LeadList
+	if err := c.do(ctx, request{
+		method: http.MethodGet,
+		path:   fmt.Sprintf("/admin/orgs/%s/leads", escape(orgID)),
+		query:  opts.values(),
+	}, &list); err != nil {
+		return nil, err
+	}
+	return &list, nil
+}
+
+// GetLead returns a lead with its related activity.
+func (c *Client) GetLead(ctx context.Context, orgID, leadID string) (*LeadDetail, error) {
+	var lead LeadDetail
+	if err := c.do(ctx, request{
+		method: http.MethodGet,
+		path:   fmt.Sprintf("/admin/orgs/%s/leads/%s", escape(orgID), escape(leadID)),
+	}, &lead); err != nil {
+		return nil, err
+	}
+	return &lead, nil
+}
@@ -0,0 +1,36 @@
+package apiclient
+
+import (
+	"context"
+	"net/http"
+)
+
+// CheckoutRequest asks for a deposit checkout link.
+type CheckoutRequest struct {
+	LeadID          string `json:"lead_id"`
+	AmountCents     int32  `json:"amount_cents"`
+	BookingIntentID string `json:"booking_intent_id,omitempty"`
+	SuccessURL      string `json:"success_url,omitempty"`
+	CancelURL       string `json:"cancel_url,omitempty"`
+	ScheduledFor    string `json:"scheduled_for,omitempty"` // RFC3339
+}
+
+// CheckoutResponse is the hosted checkout link.
+type CheckoutResponse struct {
+	CheckoutURL string `json:"checkout_url"`
+	Provider    string `json:"provider"`
+}
+
+// CreateCheckout creates a deposit checkout link for a lead.
+func (c *Client) CreateCheckout(ctx context.Context, orgID string, req CheckoutRequest) (*CheckoutResponse, error) {
+	var resp CheckoutResponse
+	if err := c.do(ctx, request{
+		method: http.MethodPost,
+		path:   "/payments/checkout",
+		orgID:  orgID,
+		body:   req,
+	}, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
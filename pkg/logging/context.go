@@ -0,0 +1,105 @@
+package logging
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// Fields are the correlation IDs carried on a context so every log line for
+// a request or job can be tied together without grepping by phone number.
+type Fields struct {
+	OrgID          string
+	ConversationID string
+	LeadID         string
+	JobID          string
+	RequestID      string
+}
+
+type fieldsKey struct{}
+
+// WithFields returns a copy of ctx carrying f merged over any fields already
+// on ctx. Empty values in f keep the existing ones.
+func WithFields(ctx context.Context, f Fields) context.Context {
+	merged := FieldsFromContext(ctx)
+	if f.OrgID != "" {
+		merged.OrgID = f.OrgID
+	}
+	if f.ConversationID != "" {
+		merged.ConversationID = f.ConversationID
+	}
+	if f.LeadID != "" {
+		merged.LeadID = f.LeadID
+	}
+	if f.JobID != "" {
+		merged.JobID = f.JobID
+	}
+	if f.RequestID != "" {
+		merged.RequestID = f.RequestID
+	}
+	return context.WithValue(ctx, fieldsKey{}, merged)
+}
+
+// FieldsFromContext returns the correlation fields on ctx, if any.
+func FieldsFromContext(ctx context.Context) Fields {
+	if ctx == nil {
+		return Fields{}
+	}
+	f, _ := ctx.Value(fieldsKey{}).(Fields)
+	return f
+}
+
+// args returns the non-empty fields as slog key/value pairs, using the same
+// keys the rest of the codebase logs by hand.
+func (f Fields) args() []any {
+	var args []any
+	if f.OrgID != "" {
+		args = append(args, "org_id", f.OrgID)
+	}
+	if f.ConversationID != "" {
+		args = append(args, "conversation_id", f.ConversationID)
+	}
+	if f.LeadID != "" {
+		args = append(args, "lead_id", f.LeadID)
+	}
+	if f.JobID != "" {
+		args = append(args, "job_id", f.JobID)
+	}
+	if f.RequestID != "" {
+		args = append(args, "request_id", f.RequestID)
+	}
+	return args
+}
+
+// NewID returns a random ID for a request or job that arrived without one.
+func NewID() string {
+	return uuid.NewString()
+}
+
+// WithContext returns a child logger that adds ctx's correlation fields to
+// every record. The logger itself is returned when ctx carries none.
+func (l *Logger) WithContext(ctx context.Context) *Logger {
+	if l == nil {
+		return nil
+	}
+	args := FieldsFromContext(ctx).args()
+	if len(args) == 0 {
+		return l
+	}
+	return &Logger{Logger: l.Logger.With(args...)}
+}
+
+// SpanAttributes returns the job and request IDs on ctx as span attributes,
+// so traces can be matched to the log lines of the same job.
+func SpanAttributes(ctx context.Context) []attribute.KeyValue {
+	f := FieldsFromContext(ctx)
+	var attrs []attribute.KeyValue
+	if f.JobID != "" {
+		attrs = append(attrs, attribute.String("medspa.job_id", f.JobID))
+	}
+	if f.RequestID != "" {
+		attrs = append(attrs, attribute.String("medspa.request_id", f.RequestID))
+	}
+	return attrs
+}
@@ -0,0 +1,36 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+)
+
+func TestWithContextAddsCorrelationFields(t *testing.T) {
+	var buf bytes.Buffer
+	logger := &Logger{Logger: slog.New(slog.NewJSONHandler(&buf, nil))}
+
+	ctx := WithFields(context.Background(), Fields{OrgID: "org-1", RequestID: "req-1"})
+	ctx = WithFields(ctx, Fields{ConversationID: "conv-1", JobID: "job-1"})
+	logger.WithContext(ctx).Info("hello")
+
+	var record map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+		t.Fatalf("decode log: %v", err)
+	}
+	want := map[string]string{"org_id": "org-1", "conversation_id": "conv-1", "job_id": "job-1", "request_id": "req-1"}
+	for k, v := range want {
+		if record[k] != v {
+			t.Fatalf("expected %s=%q, got %v", k, v, record[k])
+		}
+	}
+	if _, ok := record["lead_id"]; ok {
+		t.Fatalf("empty fields should be omitted, got %v", record)
+	}
+
+	if logger.WithContext(context.Background()) != logger {
+		t.Fatalf("expected the same logger when ctx has no fields")
+	}
+}
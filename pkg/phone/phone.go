@@ -0,0 +1,156 @@
+// Package phone normalizes patient and clinic phone numbers to E.164.
+//
+// Leads, conversation IDs, and messaging routes all key on phone numbers, so
+// every ingress point runs numbers through this package before storing or
+// comparing them. Numbers without a country code are read in the clinic's
+// region, which defaults to the US.
+package phone
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// DefaultRegion is used when a number has no country code and the caller
+// doesn't know the clinic's region.
+const DefaultRegion = "US"
+
+// ErrInvalid is returned for values that can't be read as a phone number.
+var ErrInvalid = errors.New("phone: invalid number")
+
+// callingCodes maps ISO 3166 region codes to their country calling code.
+var callingCodes = map[string]string{
+	"US": "1",
+	"CA": "1",
+	"PR": "1",
+	"GB": "44",
+	"MX": "52",
+	"AU": "61",
+}
+
+// CallingCode returns region's country calling code, falling back to the
+// DefaultRegion's for unknown or empty regions.
+func CallingCode(region string) string {
+	if code, ok := callingCodes[strings.ToUpper(strings.TrimSpace(region))]; ok {
+		return code
+	}
+	return callingCodes[DefaultRegion]
+}
+
+// Normalize returns raw in E.164 form ("+15005550002"). Numbers written
+// without a country code are read in region. Formatting characters (spaces,
+// dashes, dots, parentheses) are ignored; anything else is ErrInvalid.
+func Normalize(raw, region string) (string, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return "", fmt.Errorf("%w: empty", ErrInvalid)
+	}
+	international := strings.HasPrefix(raw, "+")
+	var b strings.Builder
+	for i, r := range raw {
+		switch {
+		case r >= '0' && r <= '9':
+			b.WriteRune(r)
+		case r == '+' && i == 0:
+		case r == ' ' || r == '-' || r == '.' || r == '(' || r == ')':
+		default:
+			return "", fmt.Errorf("%w: unexpected %q", ErrInvalid, r)
+		}
+	}
+	digits := b.String()
+
+	code := CallingCode(region)
+	if !international {
+		switch {
+		case strings.HasPrefix(digits, "011") && code == "1":
+			digits = digits[3:]
+		case strings.HasPrefix(digits, "00"):
+			digits = digits[2:]
+		case code == "1":
+			// Numbers already dialed with the leading 1 need no prefix.
+			if len(digits) != 11 || digits[0] != '1' {
+				digits = code + digits
+			}
+		default:
+			digits = code + strings.TrimPrefix(digits, "0")
+		}
+	}
+
+	if err := validate(digits); err != nil {
+		return "", err
+	}
+	return "+" + digits, nil
+}
+
+// Valid reports whether raw normalizes cleanly in region.
+func Valid(raw, region string) bool {
+	_, err := Normalize(raw, region)
+	return err == nil
+}
+
+// E164 is a best-effort Normalize in the DefaultRegion for values from
+// carriers and stored rows, which shouldn't be dropped for being odd. A
+// value that doesn't validate keeps its digits behind a "+" (with the US
+// country code added to bare ten-digit values, as before this package
+// existed); a value with no digits becomes "".
+func E164(raw string) string {
+	if normalized, err := Normalize(raw, DefaultRegion); err == nil {
+		return normalized
+	}
+	digits := onlyDigits(raw)
+	if digits == "" {
+		return ""
+	}
+	if len(digits) == 10 && !strings.HasPrefix(strings.TrimSpace(raw), "+") {
+		digits = "1" + digits
+	}
+	return "+" + digits
+}
+
+// Digits returns E164(raw) without the "+", the form used in conversation
+// IDs ("sms:{org}:15005550002").
+func Digits(raw string) string {
+	return strings.TrimPrefix(E164(raw), "+")
+}
+
+// LegacyDigits returns the other digit strings older code built conversation
+// IDs from for raw: the bare national number for US numbers. It is empty when
+// the canonical form is the only one.
+func LegacyDigits(raw string) []string {
+	digits := Digits(raw)
+	if len(digits) == 11 && digits[0] == '1' {
+		return []string{digits[1:]}
+	}
+	return nil
+}
+
+func validate(digits string) error {
+	if len(digits) < 8 || len(digits) > 15 {
+		return fmt.Errorf("%w: %d digits", ErrInvalid, len(digits))
+	}
+	if digits[0] == '0' {
+		return fmt.Errorf("%w: country code can't start with 0", ErrInvalid)
+	}
+	if digits[0] == '1' {
+		// North American numbers are always 1 + 10 digits, and area codes
+		// never start with 0 or 1.
+		if len(digits) != 11 {
+			return fmt.Errorf("%w: US numbers have 10 digits", ErrInvalid)
+		}
+		if digits[1] == '0' || digits[1] == '1' {
+			return fmt.Errorf("%w: bad area code", ErrInvalid)
+		}
+	}
+	return nil
+}
+
+func onlyDigits(value string) string {
+	var b strings.Builder
+	for _, r := range value {
+		if r >= '0' && r <= '9' {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
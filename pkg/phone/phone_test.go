@@ -0,0 +1,77 @@
+package phone
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		raw, region, want string
+	}{
+		{"+15005550002", "US", "+15005550002"},
+		{"5005550002", "US", "+15005550002"},
+		{"(500) 555-0002", "US", "+15005550002"},
+		{" 500.555.0002 ", "", "+15005550002"},
+		{"1-500-555-0002", "US", "+15005550002"},
+		{"+1 (500) 555-0002", "GB", "+15005550002"},
+		{"011 44 20 7946 0958", "US", "+442079460958"},
+		{"020 7946 0958", "GB", "+442079460958"},
+		{"0044 20 7946 0958", "GB", "+442079460958"},
+		{"416-555-0199", "ca", "+14165550199"},
+	}
+	for _, tt := range tests {
+		got, err := Normalize(tt.raw, tt.region)
+		if err != nil || got != tt.want {
+			t.Errorf("Normalize(%q, %q) = %q, %v; want %q", tt.raw, tt.region, got, err, tt.want)
+		}
+	}
+}
+
+func TestNormalizeRejectsInvalid(t *testing.T) {
+	for _, raw := range []string{
+		"",
+		"555-0100",         // no area code
+		"+1234567890",      // US number one digit short
+		"(100) 555-0002",   // area codes never start with 1
+		"500-555-0002 x12", // extensions aren't stored
+		"call me",
+		"+1234567890123456", // longer than E.164 allows
+	} {
+		if got, err := Normalize(raw, "US"); !errors.Is(err, ErrInvalid) {
+			t.Errorf("Normalize(%q) = %q, %v; want ErrInvalid", raw, got, err)
+		}
+	}
+}
+
+func TestE164AndDigits(t *testing.T) {
+	tests := []struct {
+		raw, e164, digits string
+	}{
+		{"(500) 555-0002", "+15005550002", "15005550002"},
+		{"+15005550002", "+15005550002", "15005550002"},
+		// Odd values keep their digits rather than being dropped.
+		{"1555", "+1555", "1555"},
+		{"1234567890", "+11234567890", "11234567890"},
+		{"abc", "", ""},
+		{"", "", ""},
+	}
+	for _, tt := range tests {
+		if got := E164(tt.raw); got != tt.e164 {
+			t.Errorf("E164(%q) = %q, want %q", tt.raw, got, tt.e164)
+		}
+		if got := Digits(tt.raw); got != tt.digits {
+			t.Errorf("Digits(%q) = %q, want %q", tt.raw, got, tt.digits)
+		}
+	}
+}
+
+func TestLegacyDigits(t *testing.T) {
+	if got := LegacyDigits("+1 500 555 0002"); !reflect.DeepEqual(got, []string{"5005550002"}) {
+		t.Fatalf("LegacyDigits = %v", got)
+	}
+	if got := LegacyDigits("+442079460958"); got != nil {
+		t.Fatalf("expected no legacy forms for a UK number, got %v", got)
+	}
+}
@@ -12,9 +12,6 @@ package main
 
 import (
 	"bytes"
-	"crypto/hmac"
-	"crypto/sha256"
-	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -23,6 +20,11 @@ import (
 	"regexp"
 	"strings"
 	"time"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/conversation"
+	"github.com/wolfman30/medspa-ai-platform/internal/conversation/ackmsgs"
+	"github.com/wolfman30/medspa-ai-platform/internal/conversation/msgschema"
+	"github.com/wolfman30/medspa-ai-platform/pkg/adminauth"
 )
 
 // ---------------------------------------------------------------------------
@@ -203,31 +205,24 @@ func waitForReply(minUserMsgs int, maxSecs int) ([]map[string]interface{}, error
 }
 
 func isUserMsg(m map[string]interface{}) bool {
-	role, _ := m["role"].(string)
-	sender, _ := m["sender"].(string)
-	return role == "user" || sender == "user" || sender == "patient"
+	return schemaMessage(m).FromPatient()
 }
 
-func isAckMessage(content string) bool {
-	acks := []string{
-		"got it - give me a moment",
-		"thanks for reaching out - one moment",
-		"thanks! give me a second",
-		"got it! let me check",
-		"thanks - one moment",
-		"got it. one sec",
-		"on it - just a moment",
-		"checking now",
-		"give me a second",
-		"thanks! give me a sec",
-	}
-	lower := strings.ToLower(strings.TrimSpace(content))
-	for _, a := range acks {
-		if strings.HasPrefix(lower, a) {
-			return true
-		}
+func schemaMessage(m map[string]interface{}) msgschema.Message {
+	field := func(key string) string {
+		v, _ := m[key].(string)
+		return v
 	}
-	return false
+	return msgschema.Message{
+		Direction:  field("direction"),
+		AuthorType: field("author_type"),
+		Role:       field("role"),
+		Sender:     field("sender"),
+	}
+}
+
+func isAckMessage(content string) bool {
+	return ackmsgs.LooksLike(content)
 }
 
 func getMessages(conv map[string]interface{}) []map[string]interface{} {
@@ -304,57 +299,25 @@ func waitForSlotMessage(maxSecs int) (map[string]interface{}, string, error) {
 }
 
 func checkNoDuplicateQuestions(msgs []map[string]interface{}) []string {
-	type intentPattern struct {
-		name     string
-		keywords []string
-	}
-	intents := []intentPattern{
-		{"ask_name", []string{"your name", "full name", "first and last", "may i have your"}},
-		{"ask_patient_type", []string{"visited us before", "first time", "new or returning", "new or existing", "been here before"}},
-		{"ask_schedule", []string{"days and times", "when works", "what time", "schedule preference", "days work best"}},
-		{"ask_provider", []string{"preferred provider", "provider preference", "who would you like", "which provider"}},
-		{"ask_email", []string{"email address", "email for", "your email"}},
-	}
-
-	detectIntent := func(content string) string {
-		lower := strings.ToLower(content)
-		for _, ip := range intents {
-			for _, kw := range ip.keywords {
-				if strings.Contains(lower, kw) {
-					return ip.name
-				}
-			}
-		}
-		return ""
-	}
-
-	var violations []string
-	lastAssistantIntent := ""
-	lastAssistantContent := ""
-
+	var transcript []conversation.ChatMessage
 	for _, m := range msgs {
-		if isUserMsg(m) {
-			lastAssistantIntent = ""
-			lastAssistantContent = ""
-			continue
-		}
 		content, _ := m["content"].(string)
-		if isAckMessage(content) {
+		role := conversation.ChatRoleAssistant
+		if isUserMsg(m) {
+			role = conversation.ChatRoleUser
+		} else if isAckMessage(content) {
 			continue
 		}
-		intent := detectIntent(content)
-		if intent != "" && intent == lastAssistantIntent {
-			violations = append(violations, fmt.Sprintf(
-				"DUPLICATE %s: %q ... then again: %q",
-				intent,
-				truncate(lastAssistantContent, 60),
-				truncate(content, 60),
-			))
-		}
-		if intent != "" {
-			lastAssistantIntent = intent
-			lastAssistantContent = content
-		}
+		transcript = append(transcript, conversation.ChatMessage{Role: role, Content: content})
+	}
+	var violations []string
+	for _, d := range conversation.FindDuplicateQuestions(transcript) {
+		violations = append(violations, fmt.Sprintf(
+			"DUPLICATE %s: %q ... then again: %q",
+			d.Intent,
+			truncate(d.First, 60),
+			truncate(d.Repeat, 60),
+		))
 	}
 	return violations
 }
@@ -387,24 +350,12 @@ func containsAll(s string, substrs ...string) bool {
 }
 
 func generateJWT(secret string) string {
-	header := base64url(map[string]string{"alg": "HS256", "typ": "JWT"})
-	now := time.Now()
-	payload := base64url(map[string]interface{}{
-		"sub":  "admin",
-		"role": "admin",
-		"iat":  now.Unix(),
-		"exp":  now.Add(12 * time.Hour).Unix(),
-	})
-	unsigned := header + "." + payload
-	mac := hmac.New(sha256.New, []byte(secret))
-	mac.Write([]byte(unsigned))
-	sig := strings.TrimRight(base64.URLEncoding.EncodeToString(mac.Sum(nil)), "=")
-	return unsigned + "." + sig
-}
-
-func base64url(v interface{}) string {
-	b, _ := json.Marshal(v)
-	return strings.TrimRight(base64.URLEncoding.EncodeToString(b), "=")
+	token, err := adminauth.Mint(secret, adminauth.MintOptions{TTL: 12 * time.Hour})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ERROR: mint admin token: %v\n", err)
+		os.Exit(1)
+	}
+	return token
 }
 
 func setup() error {
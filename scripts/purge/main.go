@@ -1,14 +1,14 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
-	"io"
-	"net/http"
 	"os"
 	"time"
 
-	"github.com/golang-jwt/jwt/v5"
+	"github.com/wolfman30/medspa-ai-platform/pkg/adminauth"
+	"github.com/wolfman30/medspa-ai-platform/pkg/apiclient"
 )
 
 func main() {
@@ -32,53 +32,21 @@ func main() {
 		apiURL = "https://api-dev.aiwolfsolutions.com"
 	}
 
-	// Generate JWT token
-	claims := jwt.RegisteredClaims{
-		Subject:   "admin",
-		IssuedAt:  jwt.NewNumericDate(time.Now()),
-		ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
-	}
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	tokenString, err := token.SignedString([]byte(secret))
-	if err != nil {
-		fmt.Printf("Error signing token: %v\n", err)
-		os.Exit(1)
-	}
+	// A purge-scoped token is only accepted by the purge endpoints.
+	client := apiclient.New(apiURL, apiclient.WithAdminToken(secret, adminauth.MintOptions{
+		Scope: adminauth.ScopePurge,
+		TTL:   5 * time.Minute,
+	}))
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
 
-	// Call the purge endpoint
-	url := fmt.Sprintf("%s/admin/clinics/%s/phones/%s", apiURL, orgID, phone)
 	fmt.Printf("Purging data for phone %s in org %s...\n", phone, orgID)
-	fmt.Printf("URL: %s\n", url)
-
-	req, err := http.NewRequest(http.MethodDelete, url, nil)
-	if err != nil {
-		fmt.Printf("Error creating request: %v\n", err)
-		os.Exit(1)
-	}
-	req.Header.Set("Authorization", "Bearer "+tokenString)
-	req.Header.Set("Content-Type", "application/json")
-
-	client := &http.Client{Timeout: 30 * time.Second}
-	resp, err := client.Do(req)
+	result, err := client.PurgePhone(ctx, orgID, phone)
 	if err != nil {
-		fmt.Printf("Error making request: %v\n", err)
+		fmt.Printf("Error: %v\n", err)
 		os.Exit(1)
 	}
-	defer resp.Body.Close()
 
-	body, _ := io.ReadAll(resp.Body)
-
-	if resp.StatusCode != http.StatusOK {
-		fmt.Printf("Error: HTTP %d\n", resp.StatusCode)
-		fmt.Printf("Response: %s\n", string(body))
-		os.Exit(1)
-	}
-
-	var result map[string]interface{}
-	if err := json.Unmarshal(body, &result); err != nil {
-		fmt.Printf("Response: %s\n", string(body))
-	} else {
-		prettyJSON, _ := json.MarshalIndent(result, "", "  ")
-		fmt.Printf("Success!\n%s\n", string(prettyJSON))
-	}
+	prettyJSON, _ := json.MarshalIndent(result, "", "  ")
+	fmt.Printf("Success!\n%s\n", string(prettyJSON))
 }
@@ -11,9 +11,6 @@ package main
 
 import (
 	"bytes"
-	"crypto/hmac"
-	"crypto/sha256"
-	"encoding/base64"
 	"encoding/json"
 	"flag"
 	"fmt"
@@ -24,6 +21,11 @@ import (
 	"sort"
 	"strings"
 	"time"
+
+	"github.com/wolfman30/medspa-ai-platform/internal/conversation"
+	"github.com/wolfman30/medspa-ai-platform/internal/conversation/ackmsgs"
+	"github.com/wolfman30/medspa-ai-platform/internal/conversation/msgschema"
+	"github.com/wolfman30/medspa-ai-platform/pkg/adminauth"
 )
 
 // ---------------------------------------------------------------------------
@@ -91,19 +93,12 @@ func init() {
 // ---------------------------------------------------------------------------
 
 func generateJWT() string {
-	header := base64url([]byte(`{"alg":"HS256","typ":"JWT"}`))
-	now := time.Now().Unix()
-	payload := base64url([]byte(fmt.Sprintf(`{"sub":"admin","iat":%d,"exp":%d}`, now, now+3600)))
-	msg := header + "." + payload
-	mac := hmac.New(sha256.New, []byte(flagSecret))
-	mac.Write([]byte(msg))
-	sig := base64url(mac.Sum(nil))
-	return msg + "." + sig
-}
-
-func base64url(data []byte) string {
-	s := base64.RawURLEncoding.EncodeToString(data)
-	return s
+	token, err := adminauth.Mint(flagSecret, adminauth.MintOptions{TTL: time.Hour})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ERROR: mint admin token: %v\n", err)
+		os.Exit(1)
+	}
+	return token
 }
 
 var clinicPhone = "+14407448197"
@@ -220,11 +215,28 @@ func purgePhone(orgID, phone string) error {
 }
 
 func isUserMsg(m map[string]interface{}) bool {
-	role, _ := m["role"].(string)
-	return role == "user"
+	return schemaMessage(m).FromPatient()
+}
+
+func schemaMessage(m map[string]interface{}) msgschema.Message {
+	field := func(key string) string {
+		v, _ := m[key].(string)
+		return v
+	}
+	return msgschema.Message{
+		Direction:  field("direction"),
+		AuthorType: field("author_type"),
+		Role:       field("role"),
+		Sender:     field("sender"),
+	}
 }
 
 func isAckMessage(content string) bool {
+	if ackmsgs.LooksLike(content) {
+		return true
+	}
+	// Clinics can configure their own ack templates, so also catch
+	// anything that reads like a holding message.
 	acks := []string{
 		"got it", "give me a moment", "let me check", "checking", "one moment",
 		"hold on", "looking into", "let me look",
@@ -474,46 +486,20 @@ func testFullFlow(cfg *ClinicConfig, serviceName string, idx int) (bool, string)
 
 // checkNoDuplicateQuestions scans for consecutive assistant messages asking the same question.
 func checkNoDuplicateQuestions(msgs []map[string]interface{}) []string {
-	type intentPattern struct {
-		name     string
-		keywords []string
-	}
-	intents := []intentPattern{
-		{"ask_name", []string{"your name", "full name", "first and last", "may i have your"}},
-		{"ask_patient_type", []string{"visited us before", "first time", "new or returning", "new or existing"}},
-		{"ask_schedule", []string{"days and times", "when works", "what time", "days work best"}},
-		{"ask_provider", []string{"preferred provider", "provider preference", "which provider"}},
-		{"ask_email", []string{"email address", "your email"}},
-	}
-	detectIntent := func(content string) string {
-		lower := strings.ToLower(content)
-		for _, ip := range intents {
-			for _, kw := range ip.keywords {
-				if strings.Contains(lower, kw) {
-					return ip.name
-				}
-			}
-		}
-		return ""
-	}
-	var violations []string
-	lastIntent := ""
+	var transcript []conversation.ChatMessage
 	for _, m := range msgs {
-		if isUserMsg(m) {
-			lastIntent = ""
-			continue
-		}
 		content, _ := m["content"].(string)
-		if isAckMessage(content) {
+		role := conversation.ChatRoleAssistant
+		if isUserMsg(m) {
+			role = conversation.ChatRoleUser
+		} else if isAckMessage(content) {
 			continue
 		}
-		intent := detectIntent(content)
-		if intent != "" && intent == lastIntent {
-			violations = append(violations, fmt.Sprintf("DUPLICATE %s", intent))
-		}
-		if intent != "" {
-			lastIntent = intent
-		}
+		transcript = append(transcript, conversation.ChatMessage{Role: role, Content: content})
+	}
+	var violations []string
+	for _, d := range conversation.FindDuplicateQuestions(transcript) {
+		violations = append(violations, fmt.Sprintf("DUPLICATE %s", d.Intent))
 	}
 	return violations
 }
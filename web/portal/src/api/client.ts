@@ -680,6 +680,7 @@ import type {
   DepositsListResponse,
   DepositDetailResponse,
   DepositStatsResponse,
+  DepositApplicationResponse,
 } from '../types/deposit';
 
 export async function listConversations(
@@ -725,7 +726,7 @@ export async function getConversation(
 
 export async function listDeposits(
   orgId: string,
-  options?: { page?: number; pageSize?: number; status?: string; phone?: string },
+  options?: { page?: number; pageSize?: number; status?: string; phone?: string; applied?: boolean },
   scope: ApiScope = 'admin'
 ): Promise<DepositsListResponse> {
   const params = new URLSearchParams();
@@ -733,6 +734,7 @@ export async function listDeposits(
   if (options?.pageSize) params.set('page_size', options.pageSize.toString());
   if (options?.status) params.set('status', options.status);
   if (options?.phone) params.set('phone', options.phone);
+  if (options?.applied !== undefined) params.set('applied', String(options.applied));
 
   const queryString = params.toString();
   const url = `${API_BASE}/${scopedBasePath(scope)}/orgs/${orgId}/deposits${queryString ? '?' + queryString : ''}`;
@@ -763,6 +765,24 @@ export async function getDeposit(
   return res.json();
 }
 
+export async function applyDeposit(
+  orgId: string,
+  depositId: string,
+  scope: ApiScope = 'admin'
+): Promise<DepositApplicationResponse> {
+  const res = await fetch(
+    `${API_BASE}/${scopedBasePath(scope)}/orgs/${orgId}/deposits/${depositId}/apply`,
+    {
+      method: 'POST',
+      headers: await getHeaders(),
+    }
+  );
+  if (!res.ok) {
+    throw new Error(await readErrorMessage(res));
+  }
+  return res.json();
+}
+
 export async function getDepositStats(
   orgId: string,
   scope: ApiScope = 'admin'
@@ -1456,7 +1476,13 @@ export interface Lead {
   status: string;
   source?: string;
   interested_services?: string[];
+  service_interest?: string;
   last_contact_at?: string;
+  last_activity_at?: string;
+  conversation_status?: string;
+  payment_status?: string;
+  booking_status?: string;
+  qualification?: { captured: number; required: number; missing: string[] };
   conversation_job_count: number;
   payment_total_cents: number;
   booking_count: number;
@@ -1472,6 +1498,7 @@ export interface LeadsListResponse {
   page: number;
   page_size: number;
   total_pages: number;
+  next_cursor?: string;
 }
 
 // Clinic operational dashboard (missed call cohort, conversion, LLM latency)
@@ -1545,20 +1572,37 @@ export async function getClinicOperationalDashboard(
 
 export async function listLeads(
   orgId: string,
-  params?: { page?: number; page_size?: number; status?: string; search?: string; sort_by?: string; sort_order?: string },
+  params?: {
+    page?: number;
+    page_size?: number;
+    cursor?: string;
+    status?: string;
+    conversation_status?: string;
+    payment_status?: string;
+    booking_status?: string;
+    search?: string;
+    sort_by?: string;
+    sort_order?: string;
+    mask_phone?: boolean;
+  },
   scope: ApiScope = 'admin',
 ): Promise<LeadsListResponse> {
   void scope;
   const qs = new URLSearchParams();
   if (params?.page) qs.set('page', String(params.page));
   if (params?.page_size) qs.set('page_size', String(params.page_size));
+  if (params?.cursor) qs.set('cursor', params.cursor);
   if (params?.status) qs.set('status', params.status);
+  if (params?.conversation_status) qs.set('conversation_status', params.conversation_status);
+  if (params?.payment_status) qs.set('payment_status', params.payment_status);
+  if (params?.booking_status) qs.set('booking_status', params.booking_status);
   if (params?.search) qs.set('search', params.search);
   if (params?.sort_by) qs.set('sort_by', params.sort_by);
   if (params?.sort_order) qs.set('sort_order', params.sort_order);
+  if (params?.mask_phone) qs.set('mask_phone', 'true');
   const query = qs.toString() ? `?${qs.toString()}` : '';
   // Leads endpoint is currently admin-only; portal scope falls back to admin path
-  const url = `${API_BASE}/admin/clinics/${orgId}/leads${query}`;
+  const url = `${API_BASE}/admin/orgs/${orgId}/leads${query}`;
   const res = await fetch(url, { headers: await getHeaders() });
   if (!res.ok) throw new Error(await readErrorMessage(res));
   return res.json();
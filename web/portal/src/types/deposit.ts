@@ -15,6 +15,8 @@ export interface DepositListItem {
   provider_ref?: string;
   scheduled_for?: string;
   created_at: string;
+  applied_at?: string;
+  applied_by?: string;
 }
 
 export interface DepositsListResponse {
@@ -44,9 +46,19 @@ export interface DepositDetailResponse {
   booking_intent_id?: string;
   scheduled_for?: string;
   created_at: string;
+  applied_at?: string;
+  applied_by?: string;
   conversation_id?: string;
 }
 
+export interface DepositApplicationResponse {
+  id: string;
+  status: string;
+  applied_at: string;
+  applied_by: string;
+  already_applied: boolean;
+}
+
 export interface DepositStatsResponse {
   total_deposits: number;
   total_amount_cents: number;